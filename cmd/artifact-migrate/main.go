@@ -0,0 +1,82 @@
+// Command artifact-migrate moves stored AI artifacts to the store named by
+// ai_artifacts.storage, repointing each summary at its new copy. Artifacts
+// that fail are reported and left in place, so the command can be rerun.
+// The service does the same in the background while
+// ai_artifacts.migrate_from is set.
+//
+//	artifact-migrate -from 0g
+//	artifact-migrate -from 0g,local -batch-size 50 -delete-source
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+func main() {
+	from := flag.String("from", "", "comma-separated stores to move artifacts from (default ai_artifacts.migrate_from)")
+	batchSize := flag.Int("batch-size", 0, "artifacts read per query (default from config)")
+	deleteSource := flag.Bool("delete-source", false, "delete each old copy once its artifact has moved")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+	if *batchSize > 0 {
+		cfg.AIArtifacts.MigrationBatchSize = *batchSize
+	}
+	if *deleteSource {
+		cfg.AIArtifacts.DeleteMigratedSource = true
+	}
+
+	var sources []string
+	for _, name := range strings.Split(*from, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sources = append(sources, name)
+		}
+	}
+	if len(sources) == 0 && len(cfg.AIArtifacts.MigrateFrom) == 0 {
+		fmt.Fprintln(os.Stderr, "-from is required when ai_artifacts.migrate_from is empty")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	log := logger.New(cfg.LogLevel, cfg.Environment)
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	migrator, err := di.NewArtifactMigrator(cfg, db, log.Zap(), sources...)
+	if err != nil {
+		log.Fatal("Invalid artifact migration", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := migrator.Run(ctx)
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	if err != nil {
+		log.Fatal("Artifact migration stopped", "error", err)
+	}
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,68 @@
+// Command audit-verify recomputes the audit log hash chain and exits non-zero
+// when any entry or anchor, or the copy of an anchor in external storage, no
+// longer matches, so it can run as a scheduled compliance check.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+func main() {
+	anchor := flag.Bool("anchor", false, "anchor the current chain head after a successful verification")
+	timeout := flag.Duration("timeout", 10*time.Minute, "maximum time allowed for verification")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+
+	log := logger.New(cfg.LogLevel, cfg.Environment)
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	auditService := adapters.NewAuditService(db, log.Zap())
+	anchorStore, err := di.NewAuditAnchorStore(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up audit anchor storage", "error", err)
+	}
+	if anchorStore != nil {
+		auditService.SetAnchorStore(anchorStore)
+	}
+	report, err := auditService.VerifyChain(ctx)
+	if err != nil {
+		log.Fatal("Audit chain verification failed to run", "error", err)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if !report.Valid {
+		os.Exit(1)
+	}
+
+	if *anchor {
+		if _, err := auditService.AnchorChainHead(ctx); err != nil {
+			log.Fatal("Failed to anchor audit chain head", "error", err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Command entity-secret generates a new Circle entity secret and prints the
+// ciphertext to register in the Circle console. The secret itself is written
+// to a file sealed with the field encryption keyring from the secrets manager
+// and is only printed in plaintext when explicitly requested.
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	entitysecret "github.com/stack-service/stack_service/internal/domain/services/entity_secret"
+	"github.com/stack-service/stack_service/internal/infrastructure/circle"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+const (
+	outputEncryptedFile = "encrypted-file"
+	outputPlaintext     = "plaintext"
+)
+
+type result struct {
+	Ciphertext   string `json:"entity_secret_ciphertext"`
+	File         string `json:"file,omitempty"`
+	KeyVersion   int    `json:"key_version,omitempty"`
+	EntitySecret string `json:"entity_secret,omitempty"`
+}
+
+func main() {
+	publicKeyPath := flag.String("public-key", "", "path to the Circle entity public key PEM; fetched from the Circle API when empty")
+	output := flag.String("output", outputEncryptedFile, "where the secret goes: encrypted-file or plaintext")
+	file := flag.String("file", "entity-secret.sealed", "sealed secret path for encrypted-file output; never overwritten")
+	timeout := flag.Duration("timeout", 30*time.Second, "maximum time allowed for fetching the public key")
+	flag.Parse()
+
+	if *output != outputEncryptedFile && *output != outputPlaintext {
+		fmt.Fprintf(os.Stderr, "unknown output %q; use %s or %s\n", *output, outputEncryptedFile, outputPlaintext)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+
+	log := logger.New(cfg.LogLevel, cfg.Environment)
+
+	var publicKeyPEM string
+	if *publicKeyPath != "" {
+		data, err := os.ReadFile(*publicKeyPath)
+		if err != nil {
+			log.Fatal("Failed to read public key", "error", err)
+		}
+		publicKeyPEM = string(data)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		client := circle.NewClient(circle.Config{
+			APIKey:      cfg.Circle.APIKey,
+			Environment: cfg.Circle.Environment,
+			BaseURL:     cfg.Circle.BaseURL,
+		}, log.Zap())
+		publicKeyPEM, err = client.GetEntityPublicKey(ctx)
+		if err != nil {
+			log.Fatal("Failed to fetch entity public key", "error", err)
+		}
+	}
+
+	publicKey, err := entitysecret.ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		log.Fatal("Invalid entity public key", "error", err)
+	}
+	secret, err := entitysecret.GenerateSecret()
+	if err != nil {
+		log.Fatal("Failed to generate entity secret", "error", err)
+	}
+	ciphertext, err := entitysecret.EncryptSecret(publicKey, secret)
+	if err != nil {
+		log.Fatal("Failed to encrypt entity secret", "error", err)
+	}
+
+	res := result{Ciphertext: ciphertext}
+	if *output == outputPlaintext {
+		fmt.Fprintln(os.Stderr, "WARNING: printing the entity secret in plaintext; store it in the secrets manager and clear your terminal history")
+		res.EntitySecret = hex.EncodeToString(secret)
+	} else {
+		sealer, err := di.NewFieldEncryptor(cfg)
+		if err != nil {
+			log.Fatal("Failed to initialize field encryption", "error", err)
+		}
+		if err := entitysecret.WriteSealedFile(*file, secret, sealer); err != nil {
+			log.Fatal("Failed to write sealed entity secret", "error", err)
+		}
+		res.File = *file
+		res.KeyVersion = sealer.ActiveVersion()
+	}
+
+	out, _ := json.MarshalIndent(res, "", "  ")
+	fmt.Println(string(out))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
@@ -16,10 +17,16 @@ import (
 	"github.com/stack-service/stack_service/internal/infrastructure/database"
 	"github.com/stack-service/stack_service/internal/infrastructure/di"
 	"github.com/stack-service/stack_service/internal/workers/funding_webhook"
+	"github.com/stack-service/stack_service/internal/workers/inactivity_monitor"
+	"github.com/stack-service/stack_service/internal/workers/job_janitor"
+	onboardingprocessor "github.com/stack-service/stack_service/internal/workers/onboarding_processor"
+	"github.com/stack-service/stack_service/internal/workers/ops_digest"
 	walletprovisioning "github.com/stack-service/stack_service/internal/workers/wallet_provisioning"
 	"github.com/stack-service/stack_service/pkg/logger"
 	"github.com/stack-service/stack_service/pkg/metrics"
+	"github.com/stack-service/stack_service/pkg/startup"
 	"github.com/stack-service/stack_service/pkg/tracing"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,13 +44,23 @@ import (
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 
 // @host localhost:8080
-// @BasePath /api/v1
+// @BasePath /
 
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey RequestSignature
+// @in header
+// @name X-Signature
+// @description HMAC-SHA256 request signature for internal service calls, sent with the X-Signing-Key-Id, X-Signing-Timestamp and X-Signing-Nonce headers.
+
+// @securityDefinitions.apikey DelegateAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the delegate session token from /api/v1/delegate/login.
+
 // userRepositoryAdapter adapts infrastructure UserRepository to wallet provisioning UserRepository
 type userRepositoryAdapter struct {
 	repo interface {
@@ -85,6 +102,20 @@ func main() {
 	// Initialize logger
 	log := logger.New(cfg.LogLevel, cfg.Environment)
 
+	// Apply module log levels from the config file, again whenever it changes
+	config.WatchLogLevels(func(base string, modules map[string]string) {
+		if level, err := logger.ParseLevel(base); err == nil {
+			logger.ModuleLevels().SetBase(level)
+		} else {
+			log.Warn("Ignoring invalid log level", "error", err)
+		}
+		levels, err := logger.ParseModuleLevels(modules)
+		if err != nil {
+			log.Warn("Ignoring invalid module log levels", "error", err)
+		}
+		logger.ModuleLevels().Configure(levels)
+	})
+
 	// Initialize OpenTelemetry tracing
 	tracingConfig := tracing.Config{
 		Enabled:      cfg.Environment != "test",
@@ -100,10 +131,32 @@ func main() {
 	defer tracingShutdown(context.Background())
 	log.Info("OpenTelemetry tracing initialized", "collector_url", tracingConfig.CollectorURL)
 
+	// Critical dependencies abort startup; optional ones start degraded and
+	// reconnect in the background until shutdown
+	supervisorCtx, stopSupervisor := context.WithCancel(context.Background())
+	defer stopSupervisor()
+	supervisor := startup.NewSupervisor(startup.Config{
+		RetryInterval:    time.Duration(cfg.Startup.RetryIntervalSeconds) * time.Second,
+		MaxRetryInterval: time.Duration(cfg.Startup.MaxRetryIntervalSeconds) * time.Second,
+		Required:         cfg.Startup.Required,
+	}, log.Zap())
+
 	// Initialize database with enhanced configuration
-	db, err := database.NewConnection(cfg.Database)
-	if err != nil {
-		log.Fatal("Failed to connect to database", "error", err)
+	var db *sql.DB
+	if err := supervisor.Start(supervisorCtx, startup.Component{
+		Name:     "database",
+		Critical: true,
+		Start: func(ctx context.Context) error {
+			var err error
+			db, err = database.NewConnection(cfg.Database)
+			if err != nil {
+				return err
+			}
+			// Run migrations
+			return database.RunMigrations(cfg.Database.URL)
+		},
+	}); err != nil {
+		log.Fatal("Failed to initialize database", "error", err)
 	}
 	defer func() {
 		if err := db.Close(); err != nil {
@@ -111,11 +164,6 @@ func main() {
 		}
 	}()
 
-	// Run migrations
-	if err := database.RunMigrations(cfg.Database.URL); err != nil {
-		log.Fatal("Failed to run migrations", "error", err)
-	}
-
 	// Set Gin mode
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -126,18 +174,37 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to create DI container", "error", err)
 	}
+	container.Startup = supervisor
+
+	// Redis and the email provider are optional at boot unless listed in
+	// startup.required: features that need them fail individually until
+	// they reconnect
+	optional := []startup.Component{{
+		Name: "redis",
+		Start: func(ctx context.Context) error {
+			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			return container.RedisClient.Ping(pingCtx)
+		},
+	}}
+	if container.EmailService != nil {
+		optional = append(optional, startup.Component{Name: "email", Start: container.EmailService.Ping})
+	}
+	for _, component := range optional {
+		if err := supervisor.Start(supervisorCtx, component); err != nil {
+			log.Fatal("Failed to start required component", "error", err)
+		}
+	}
 
-	// Initialize router with DI container
+	// Initialize router with DI container, including the security routes
 	router := routes.SetupRoutes(container)
 
-	// Setup security routes
-	routes.SetupSecurityRoutes(router, cfg, db, log.Zap())
-
 	// Initialize wallet provisioning worker and scheduler
 	workerConfig := walletprovisioning.DefaultConfig()
 	workerConfig.WalletSetNamePrefix = cfg.Circle.DefaultWalletSetName
 	workerConfig.ChainsToProvision = container.WalletService.SupportedChains()
 	workerConfig.DefaultWalletSetID = cfg.Circle.DefaultWalletSetID
+	workerConfig.LeaseDuration = time.Duration(cfg.JobJanitor.WalletLeaseSeconds) * time.Second
 
 	// Create user repository adapter for wallet provisioning
 	userRepoAdapter := &userRepositoryAdapter{repo: container.UserRepo}
@@ -162,6 +229,10 @@ func main() {
 		log.Zap(),
 	)
 
+	// Report polls and queue depth to the admin worker dashboard
+	scheduler.SetTracker(container.WorkerRegistry.Register("wallet_provisioning",
+		schedulerConfig.PollInterval, container.WalletProvisioningJobRepo.CountRetryableJobs))
+
 	// Start the scheduler
 	if err := scheduler.Start(); err != nil {
 		log.Fatal("Failed to start wallet provisioning scheduler", "error", err)
@@ -171,8 +242,32 @@ func main() {
 	// Store scheduler in container for access by handlers
 	container.WalletProvisioningScheduler = scheduler
 
+	// Run queued onboarding jobs as sagas; jobs that fail for good are
+	// compensated and listed for admins
+	if cfg.OnboardingJobs.Enabled {
+		onboardingConfig := onboardingprocessor.DefaultConfig()
+		onboardingConfig.PollInterval = time.Duration(cfg.OnboardingJobs.PollIntervalSeconds) * time.Second
+		onboardingConfig.MaxConcurrentJobs = cfg.OnboardingJobs.MaxConcurrentJobs
+		onboardingConfig.MaxAttempts = cfg.OnboardingJobs.MaxAttempts
+
+		onboardingCtx, stopOnboarding := context.WithCancel(context.Background())
+		defer stopOnboarding()
+		onboardingWorker := onboardingprocessor.NewWorker(
+			container.OnboardingJobRepo,
+			container.WalletService,
+			container.UserRepo,
+			onboardingConfig,
+			log.Zap(),
+		)
+		onboardingWorker.SetTracker(container.WorkerRegistry.Register("onboarding_jobs",
+			onboardingConfig.PollInterval, container.OnboardingJobRepo.CountBacklog))
+		onboardingWorker.Start(onboardingCtx)
+		log.Info("Onboarding job worker started", "max_concurrent", onboardingConfig.MaxConcurrentJobs)
+	}
+
 	// Initialize funding webhook workers
 	processorConfig := funding_webhook.DefaultProcessorConfig()
+	processorConfig.LeaseDuration = time.Duration(cfg.JobJanitor.FundingLeaseSeconds) * time.Second
 	reconciliationConfig := funding_webhook.DefaultReconciliationConfig()
 
 	webhookManager, err := funding_webhook.NewManager(
@@ -188,6 +283,14 @@ func main() {
 		log.Fatal("Failed to create webhook manager", "error", err)
 	}
 
+	processorTracker := container.WorkerRegistry.Register("funding_webhook_processor",
+		processorConfig.PollInterval, container.FundingEventJobRepo.CountBacklog)
+	var reconcilerTracker *workerstatus.Tracker
+	if reconciliationConfig.Enabled {
+		reconcilerTracker = container.WorkerRegistry.Register("funding_webhook_reconciler", reconciliationConfig.Interval, nil)
+	}
+	webhookManager.SetTrackers(processorTracker, reconcilerTracker)
+
 	// Start the webhook manager
 	if err := webhookManager.Start(context.Background()); err != nil {
 		log.Fatal("Failed to start webhook manager", "error", err)
@@ -197,11 +300,26 @@ func main() {
 	// Store webhook manager in container for access by handlers
 	container.FundingWebhookManager = webhookManager
 
+	// Requeue wallet provisioning and funding jobs whose worker died holding them
+	if cfg.JobJanitor.Enabled {
+		janitorCtx, stopJanitor := context.WithCancel(context.Background())
+		defer stopJanitor()
+		janitor := job_janitor.NewWorker(container.WalletProvisioningJobRepo, container.FundingEventJobRepo, job_janitor.Config{
+			Interval:       time.Duration(cfg.JobJanitor.IntervalSeconds) * time.Second,
+			BatchSize:      cfg.JobJanitor.BatchSize,
+			AlertThreshold: int64(cfg.JobJanitor.AlertThreshold),
+		}, log.Zap())
+		janitor.SetTracker(container.WorkerRegistry.Register("job_janitor", janitor.Interval(), janitor.CountStuck))
+		janitor.Start(janitorCtx)
+		log.Info("Job janitor started", "interval_seconds", cfg.JobJanitor.IntervalSeconds)
+	}
+
 	// Initialize and start reconciliation scheduler
 	if cfg.Reconciliation.Enabled {
 		log.Info("Starting reconciliation scheduler", 
 			"auto_correct", cfg.Reconciliation.AutoCorrectLowSeverity,
 		)
+		container.ReconciliationScheduler.SetTracker(container.WorkerRegistry.Register("reconciliation", time.Hour, nil))
 		if err := container.ReconciliationScheduler.Start(context.Background()); err != nil {
 			log.Fatal("Failed to start reconciliation scheduler", "error", err)
 		}
@@ -210,6 +328,276 @@ func main() {
 		log.Info("Reconciliation scheduler disabled in configuration")
 	}
 
+	// Periodically anchor the audit hash chain head
+	if cfg.Audit.AnchorEnabled {
+		anchorCtx, stopAnchoring := context.WithCancel(context.Background())
+		defer stopAnchoring()
+		container.AuditService.StartAnchoring(anchorCtx, time.Duration(cfg.Audit.AnchorIntervalMinutes)*time.Minute)
+		log.Info("Audit chain anchoring started", "interval_minutes", cfg.Audit.AnchorIntervalMinutes)
+	}
+
+	// Enforce data retention policies
+	if cfg.Retention.Enabled {
+		retentionCtx, stopRetention := context.WithCancel(context.Background())
+		defer stopRetention()
+		container.RetentionService.SetTracker(container.WorkerRegistry.Register("retention",
+			time.Duration(cfg.Retention.IntervalHours)*time.Hour, nil))
+		container.RetentionService.Start(retentionCtx)
+		log.Info("Data retention engine started",
+			"interval_hours", cfg.Retention.IntervalHours,
+			"dry_run", cfg.Retention.DryRun,
+		)
+	}
+
+	// Monitor dormant funded accounts
+	if cfg.Inactivity.Enabled {
+		inactivityCtx, stopInactivity := context.WithCancel(context.Background())
+		defer stopInactivity()
+		inactivityWorker := inactivity_monitor.NewWorker(container.InactivityService, log.Zap())
+		inactivityWorker.SetTracker(container.WorkerRegistry.Register("inactivity_monitor", container.InactivityService.Interval(), nil))
+		inactivityWorker.Start(inactivityCtx)
+		log.Info("Inactivity monitor started", "interval_hours", cfg.Inactivity.IntervalHours)
+	}
+
+	// Send the daily ops digest to admins
+	if cfg.OpsDigest.Enabled {
+		digestCtx, stopDigest := context.WithCancel(context.Background())
+		defer stopDigest()
+		digestWorker := ops_digest.NewWorker(container.OpsDigestService, log.Zap())
+		digestWorker.SetTracker(container.WorkerRegistry.Register("ops_digest", container.OpsDigestService.Interval(), nil))
+		digestWorker.Start(digestCtx)
+		log.Info("Ops digest worker started",
+			"send_hour_utc", cfg.OpsDigest.SendHourUTC,
+			"recipients", len(cfg.OpsDigest.Recipients),
+		)
+	}
+
+	// Scan for records that disagree across aggregates each night
+	if cfg.Integrity.Enabled {
+		integrityCtx, stopIntegrity := context.WithCancel(context.Background())
+		defer stopIntegrity()
+		container.IntegrityService.SetTracker(container.WorkerRegistry.Register("integrity_checker", container.IntegrityService.Interval(), nil))
+		container.IntegrityService.Start(integrityCtx)
+		log.Info("Integrity checker started",
+			"run_hour_utc", cfg.Integrity.RunHourUTC,
+			"open_cases", cfg.Integrity.OpenCases,
+		)
+	}
+
+	// Write sampled and admin-targeted request captures
+	if cfg.HTTPCapture.Enabled {
+		captureCtx, stopCapture := context.WithCancel(context.Background())
+		defer stopCapture()
+		container.HTTPCaptureService.Start(captureCtx)
+		log.Info("HTTP capture started", "sample_percent", cfg.HTTPCapture.SamplePercent)
+	}
+
+	// Replay sampled traffic against shadowed candidate implementations
+	if cfg.Shadow.Enabled {
+		shadowCtx, stopShadow := context.WithCancel(context.Background())
+		defer stopShadow()
+		container.ShadowService.Start(shadowCtx)
+		log.Info("Traffic shadowing started", "routes", len(cfg.Shadow.Routes))
+	}
+
+	// Apply fault rules to provider calls in non-production environments
+	if container.FaultInjectionService.Enabled() {
+		faultCtx, stopFaults := context.WithCancel(context.Background())
+		defer stopFaults()
+		container.FaultInjectionService.Start(faultCtx)
+		log.Warn("Fault injection enabled for provider calls")
+	}
+
+	// Roll up API usage per user and endpoint
+	if cfg.APIUsage.Enabled {
+		usageCtx, stopUsage := context.WithCancel(context.Background())
+		defer stopUsage()
+		container.APIUsageService.Start(usageCtx)
+		log.Info("API usage analytics started", "retention_days", cfg.APIUsage.RetentionDays)
+	}
+
+	// Vest and forfeit promotional credit
+	if cfg.Promotions.Enabled {
+		promotionsCtx, stopPromotions := context.WithCancel(context.Background())
+		defer stopPromotions()
+		container.PromotionService.SetTracker(container.WorkerRegistry.Register("promotions",
+			time.Duration(cfg.Promotions.IntervalMinutes)*time.Minute, nil))
+		container.PromotionService.Start(promotionsCtx)
+		log.Info("Promotional credit vesting started", "interval_minutes", cfg.Promotions.IntervalMinutes)
+	}
+
+	// Report suspense items that have waited too long for an admin
+	if cfg.Suspense.Enabled {
+		suspenseCtx, stopSuspense := context.WithCancel(context.Background())
+		defer stopSuspense()
+		container.SuspenseService.SetTracker(container.WorkerRegistry.Register("suspense_aging",
+			container.SuspenseService.Interval(), nil))
+		container.SuspenseService.Start(suspenseCtx)
+		log.Info("Suspense aging check started", "aging_alert_days", cfg.Suspense.AgingAlertDays)
+	}
+
+	// Fill Redis for recently active users and popular baskets so the first
+	// requests after a deploy do not all miss
+	if cfg.CacheWarm.OnStartup {
+		if err := container.HotCacheService.WarmInBackground(context.Background(), entities.CacheWarmTriggerStartup); err != nil {
+			log.Warn("Startup cache warm-up not started", "error", err)
+		} else {
+			log.Info("Startup cache warm-up started", "max_users", cfg.CacheWarm.MaxUsers, "max_baskets", cfg.CacheWarm.MaxBaskets)
+		}
+	}
+
+	// Trigger and expire resting basket limit orders
+	if cfg.LimitOrders.Enabled {
+		limitCtx, stopLimitOrders := context.WithCancel(context.Background())
+		defer stopLimitOrders()
+		container.InvestingService.SetLimitOrderTracker(container.WorkerRegistry.Register("limit_orders", container.InvestingService.LimitOrderInterval(), nil))
+		container.InvestingService.StartLimitOrderMonitor(limitCtx)
+		if container.PaperInvestingService != nil {
+			container.PaperInvestingService.SetLimitOrderTracker(container.WorkerRegistry.Register("paper_limit_orders", container.PaperInvestingService.LimitOrderInterval(), nil))
+			container.PaperInvestingService.StartLimitOrderMonitor(limitCtx)
+		}
+		log.Info("Limit order monitor started", "interval_seconds", cfg.LimitOrders.IntervalSeconds)
+	}
+
+	// Renew subscriptions and retry failed payments
+	if cfg.Billing.Enabled {
+		billingCtx, stopBilling := context.WithCancel(context.Background())
+		defer stopBilling()
+		container.SubscriptionService.SetTracker(container.WorkerRegistry.Register("billing", container.SubscriptionService.Interval(), nil))
+		container.SubscriptionService.Start(billingCtx)
+		log.Info("Subscription billing started", "interval_minutes", cfg.Billing.IntervalMinutes)
+	}
+
+	// Deliver queued partner webhooks
+	if cfg.Webhooks.Enabled {
+		webhookCtx, stopWebhooks := context.WithCancel(context.Background())
+		defer stopWebhooks()
+		container.OutboundWebhookService.SetTracker(container.WorkerRegistry.Register("outbound_webhooks",
+			time.Duration(cfg.Webhooks.PollIntervalSeconds)*time.Second, nil))
+		container.OutboundWebhookService.Start(webhookCtx)
+		log.Info("Outbound webhook delivery started", "poll_interval_seconds", cfg.Webhooks.PollIntervalSeconds)
+	}
+
+	// Publish onboarding status changes recorded in the outbox
+	if cfg.OnboardingEvents.Enabled {
+		outboxCtx, stopOutbox := context.WithCancel(context.Background())
+		defer stopOutbox()
+		container.OnboardingEventRelay.SetTracker(container.WorkerRegistry.Register("onboarding_events",
+			container.OnboardingEventRelay.Interval(), container.OnboardingOutboxRepo.CountBacklog))
+		container.OnboardingEventRelay.Start(outboxCtx)
+		log.Info("Onboarding event relay started", "poll_interval_seconds", cfg.OnboardingEvents.PollIntervalSeconds)
+	}
+
+	// Stream live quotes to connected clients during market hours
+	if container.MarketDataService != nil {
+		marketDataCtx, stopMarketData := context.WithCancel(context.Background())
+		defer stopMarketData()
+		container.MarketDataService.Start(marketDataCtx)
+		log.Info("Market data streaming started", "throttle_millis", cfg.MarketData.ThrottleMillis)
+	}
+
+	// Track investment goals and nudge users who fall behind plan
+	if cfg.Goals.Enabled {
+		goalsCtx, stopGoals := context.WithCancel(context.Background())
+		defer stopGoals()
+		container.GoalService.SetTracker(container.WorkerRegistry.Register("goals", container.GoalService.Interval(), nil))
+		container.GoalService.Start(goalsCtx)
+		log.Info("Goal progress sweep started", "interval_hours", cfg.Goals.IntervalHours)
+	}
+
+	// Invest idle buying power for users who turned on auto-sweep
+	if cfg.Sweep.Enabled {
+		sweepCtx, stopSweep := context.WithCancel(context.Background())
+		defer stopSweep()
+		container.SweepService.SetTracker(container.WorkerRegistry.Register("auto_sweep", container.SweepService.Interval(), nil))
+		container.SweepService.Start(sweepCtx)
+		log.Info("Auto-sweep worker started", "interval_minutes", cfg.Sweep.IntervalMinutes)
+	}
+
+	// Apply admin bulk user operations in the background
+	if cfg.BulkOps.Enabled {
+		bulkOpsCtx, stopBulkOps := context.WithCancel(context.Background())
+		defer stopBulkOps()
+		container.BulkOpsService.SetTracker(container.WorkerRegistry.Register("bulk_operations", container.BulkOpsService.Interval(), nil))
+		container.BulkOpsService.Start(bulkOpsCtx)
+		log.Info("Bulk operation worker started", "poll_interval_seconds", cfg.BulkOps.PollIntervalSeconds)
+	}
+
+	// Export each settled day's ledger journal to the accounting system
+	if cfg.Accounting.Enabled {
+		accountingCtx, stopAccounting := context.WithCancel(context.Background())
+		defer stopAccounting()
+		container.AccountingService.SetTracker(container.WorkerRegistry.Register("accounting_export", container.AccountingService.Interval(), nil))
+		container.AccountingService.Start(accountingCtx)
+		log.Info("Accounting export started", "destination", cfg.Accounting.Destination)
+	}
+
+	// Stream business events to the data warehouse for analytics
+	if cfg.Warehouse.Enabled {
+		warehouseCtx, stopWarehouse := context.WithCancel(context.Background())
+		defer stopWarehouse()
+		container.WarehouseService.SetTracker(container.WorkerRegistry.Register("warehouse_export", container.WarehouseService.Interval(), nil))
+		container.WarehouseService.Start(warehouseCtx)
+		log.Info("Warehouse export started", "destination", cfg.Warehouse.Destination)
+	}
+
+	// Generate the daily order blotter of record for regulatory reporting
+	if cfg.OrderBlotter.Enabled {
+		blotterCtx, stopBlotter := context.WithCancel(context.Background())
+		defer stopBlotter()
+		container.BlotterService.SetTracker(container.WorkerRegistry.Register("order_blotter", container.BlotterService.Interval(), nil))
+		container.BlotterService.Start(blotterCtx)
+		log.Info("Order blotter started", "format", cfg.OrderBlotter.Format)
+	}
+
+	// Purge uploads whose pre-signed URL expired before they were completed
+	if cfg.Uploads.Storage != "" {
+		uploadCtx, stopUploads := context.WithCancel(context.Background())
+		defer stopUploads()
+		container.UploadService.SetTracker(container.WorkerRegistry.Register("upload_purge", container.UploadService.Interval(), nil))
+		container.UploadService.Start(uploadCtx)
+		log.Info("Upload purge started", "storage", cfg.Uploads.Storage)
+	}
+
+	// Move AI artifacts off the stores being retired
+	if container.AIArtifactMigrator != nil {
+		artifactCtx, stopArtifacts := context.WithCancel(context.Background())
+		defer stopArtifacts()
+		container.AIArtifactMigrator.SetTracker(container.WorkerRegistry.Register("ai_artifact_migration", container.AIArtifactMigrator.Interval(), nil))
+		container.AIArtifactMigrator.Start(artifactCtx)
+		log.Info("AI artifact migration started", "to", cfg.AIArtifacts.Storage, "from", cfg.AIArtifacts.MigrateFrom)
+	}
+
+	// Send notifications held for quiet hours once they end, and daily digests
+	notificationCtx, stopNotifications := context.WithCancel(context.Background())
+	defer stopNotifications()
+	container.NotificationService.SetTracker(container.WorkerRegistry.Register("notification_release", container.NotificationService.Interval(), nil))
+	container.NotificationService.Start(notificationCtx)
+	log.Info("Held notification release started", "interval", container.NotificationService.Interval().String())
+
+	// Keep cached wallet balances fresh
+	balanceCacheCtx, stopBalanceCache := context.WithCancel(context.Background())
+	defer stopBalanceCache()
+	container.BalanceCacheService.SetTracker(container.WorkerRegistry.Register("balance_cache",
+		time.Duration(cfg.BalanceCache.RefreshIntervalSeconds)*time.Second, nil))
+	container.BalanceCacheService.Start(balanceCacheCtx)
+	log.Info("Wallet balance cache refresh started",
+		"interval_seconds", cfg.BalanceCache.RefreshIntervalSeconds,
+		"max_age_seconds", cfg.BalanceCache.MaxAgeSeconds,
+	)
+
+	// Register or verify the Circle notification subscription; a changed
+	// callback URL gets a new subscription and the old one is removed
+	if cfg.Circle.NotificationCallbackURL != "" {
+		circleSubCtx, stopCircleSub := context.WithCancel(context.Background())
+		defer stopCircleSub()
+		container.CircleSubscriptions.Start(circleSubCtx)
+		log.Info("Circle subscription management started",
+			"callback_url", cfg.Circle.NotificationCallbackURL,
+			"check_interval_minutes", cfg.Circle.SubscriptionCheckMinutes,
+		)
+	}
+
 	// Create server with enhanced configuration
 	server := &http.Server{
 		Addr:           fmt.Sprintf(":%d", cfg.Server.Port),
@@ -261,6 +649,11 @@ func main() {
 		log.Warn("Error stopping scheduler", "error", err)
 	}
 
+	// Stop enqueueing wallet backfill batches; a new backfill skips users already handled
+	if container.WalletBackfillService != nil {
+		container.WalletBackfillService.Stop()
+	}
+
 	// Stop the funding webhook manager
 	log.Info("Stopping funding webhook manager...")
 	if webhookMgr, ok := container.FundingWebhookManager.(*funding_webhook.Manager); ok {
@@ -285,5 +678,13 @@ func main() {
 		log.Fatal("Server forced to shutdown", "error", err)
 	}
 
+	// Drain the event bus once no request can publish to it
+	if container.EventBus != nil {
+		log.Info("Closing event bus...")
+		if err := container.EventBus.Close(); err != nil {
+			log.Warn("Error closing event bus", "error", err)
+		}
+	}
+
 	log.Info("Server exited gracefully")
 }
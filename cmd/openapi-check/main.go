@@ -0,0 +1,35 @@
+// Command openapi-check fails when a route served by the API has no @Router
+// annotation, so the generated OpenAPI spec and clients cannot drift from
+// the router. It runs from the repository root as part of `make build`.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/stack-service/stack_service/internal/api/apidocs"
+)
+
+func main() {
+	routesDir := flag.String("routes", "internal/api/routes", "directory containing the route setup")
+	handlersDir := flag.String("handlers", "internal/api/handlers", "directory containing the annotated handlers")
+	flag.Parse()
+
+	report, err := apidocs.Check(*routesDir, *handlersDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check routes: %v\n", err)
+		os.Exit(2)
+	}
+
+	for _, route := range report.Undocumented {
+		fmt.Printf("undocumented route: %s (add an @Router annotation to its handler)\n", route)
+	}
+	for _, route := range report.Stale {
+		fmt.Printf("stale baseline entry: %s (remove it from internal/api/apidocs/undocumented_routes.txt)\n", route)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+	fmt.Println("all served routes are documented")
+}
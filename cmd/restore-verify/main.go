@@ -0,0 +1,52 @@
+// Command restore-verify restores the latest database backup into the scratch
+// database, checks ledger invariants and row counts against production, and exits
+// non-zero when the backup is stale, fails to restore, or has drifted.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", 2*time.Hour, "maximum time allowed for restore and verification")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+
+	log := logger.New(cfg.LogLevel, cfg.Environment)
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := di.NewRestoreDrillService(cfg, db, log).Run(ctx)
+	if err != nil {
+		log.Fatal("Restore drill could not run", "error", err)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
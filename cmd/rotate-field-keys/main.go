@@ -0,0 +1,79 @@
+// Command rotate-field-keys re-encrypts PII columns under the active field
+// encryption key version. Run it after activating a new key and before retiring
+// the old one; it exits non-zero if any row could not be decrypted.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"github.com/stack-service/stack_service/internal/workers/field_rotation"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 500, "rows re-encrypted per batch")
+	timeout := flag.Duration("timeout", time.Hour, "maximum time allowed for rotation")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+
+	log := logger.New(cfg.LogLevel, cfg.Environment)
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	fieldEncryptor, err := di.NewFieldEncryptor(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize field encryption", "error", err)
+	}
+
+	userRepo := repositories.NewUserRepository(db, log.Zap())
+	userRepo.SetFieldEncryptor(fieldEncryptor)
+	virtualAccountRepo := repositories.NewVirtualAccountRepository(sqlx.NewDb(db, "postgres"))
+	virtualAccountRepo.SetFieldEncryptor(fieldEncryptor)
+	trustedContactRepo := repositories.NewTrustedContactRepository(db, log.Zap())
+	trustedContactRepo.SetFieldEncryptor(fieldEncryptor)
+	outboundWebhookRepo := repositories.NewOutboundWebhookRepository(db, log.Zap())
+	outboundWebhookRepo.SetFieldEncryptor(fieldEncryptor)
+	recipientRepo := repositories.NewRecipientRepository(db, log.Zap())
+	recipientRepo.SetFieldEncryptor(fieldEncryptor)
+	kybRepo := repositories.NewKYBRepository(db, log.Zap())
+	kybRepo.SetFieldEncryptor(fieldEncryptor)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	log.Info("Rotating field encryption", "active_version", fieldEncryptor.ActiveVersion())
+	worker := field_rotation.NewWorker(*batchSize, log.Zap(), userRepo, virtualAccountRepo, trustedContactRepo, outboundWebhookRepo, recipientRepo, kybRepo, kybRepo.OwnerFields())
+	results, err := worker.Run(ctx)
+
+	out, _ := json.MarshalIndent(results, "", "  ")
+	fmt.Println(string(out))
+
+	if err != nil {
+		log.Fatal("Field encryption rotation failed", "error", err)
+	}
+	for _, result := range results {
+		if result.Failed > 0 {
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Command wallet-backfill enqueues wallet provisioning jobs for existing users
+// missing a wallet on the given chains, in rate-limited batches, and waits
+// until every eligible user has a job. The running service's provisioning
+// scheduler creates the wallets; progress is also visible through
+// GET /api/v1/admin/wallet/backfills/{id}.
+//
+//	wallet-backfill -chains SOL-DEVNET -batch-size 25 -interval 30s
+//	wallet-backfill -chains SOL-DEVNET -dry-run
+//	wallet-backfill -status <backfill-id>
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+func main() {
+	chains := flag.String("chains", "", "comma-separated chains to provision, e.g. SOL-DEVNET")
+	batchSize := flag.Int("batch-size", 0, "jobs enqueued per batch (default from config)")
+	interval := flag.Duration("interval", 0, "pause between batches (default from config)")
+	maxInFlight := flag.Int("max-in-flight", 0, "outstanding jobs before enqueueing pauses (default from config)")
+	dryRun := flag.Bool("dry-run", false, "count eligible users without enqueueing jobs")
+	status := flag.String("status", "", "print the progress of an existing backfill and exit")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+
+	log := logger.New(cfg.LogLevel, cfg.Environment)
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	service := di.NewWalletBackfillService(cfg, db, log.Zap())
+
+	if *status != "" {
+		id, err := uuid.Parse(*status)
+		if err != nil {
+			log.Fatal("Invalid backfill ID", "error", err)
+		}
+		backfill, err := service.Get(context.Background(), id)
+		if err != nil {
+			log.Fatal("Failed to get backfill", "error", err)
+		}
+		printJSON(backfill)
+		return
+	}
+
+	if strings.TrimSpace(*chains) == "" {
+		fmt.Fprintln(os.Stderr, "-chains is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	req := &entities.StartWalletBackfillRequest{
+		BatchSize:            *batchSize,
+		BatchIntervalSeconds: int(*interval / time.Second),
+		MaxInFlight:          *maxInFlight,
+		DryRun:               *dryRun,
+	}
+	for _, chain := range strings.Split(*chains, ",") {
+		if chain = strings.TrimSpace(chain); chain != "" {
+			req.Chains = append(req.Chains, chain)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backfill, err := service.Run(ctx, req, nil)
+	if err != nil {
+		log.Fatal("Wallet backfill could not run", "error", err)
+	}
+	printJSON(backfill)
+
+	if backfill.Status != entities.WalletBackfillCompleted {
+		os.Exit(1)
+	}
+}
+
+func printJSON(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
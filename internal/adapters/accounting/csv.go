@@ -0,0 +1,102 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// FileStore receives rendered journal files
+type FileStore interface {
+	// Put writes the file, replacing any file of the same name, and returns
+	// where it was written
+	Put(ctx context.Context, name string, content []byte) (string, error)
+}
+
+// CSVExporter renders journals in the general journal import layout that
+// QuickBooks Desktop and NetSuite's CSV import both accept, one file per day.
+// The file name is derived from the journal reference, so a re-export
+// overwrites the earlier file.
+type CSVExporter struct {
+	store FileStore
+}
+
+// NewCSVExporter creates a CSV exporter writing to store
+func NewCSVExporter(store FileStore) *CSVExporter {
+	return &CSVExporter{store: store}
+}
+
+// Name identifies the destination in export records
+func (e *CSVExporter) Name() string {
+	return "csv"
+}
+
+// ExportJournal writes the journal file and returns its location
+func (e *CSVExporter) ExportJournal(ctx context.Context, journal *entities.AccountingJournal, previousRef string) (string, error) {
+	content, err := RenderJournalCSV(journal)
+	if err != nil {
+		return "", err
+	}
+	return e.store.Put(ctx, journal.Reference+".csv", content)
+}
+
+// RenderJournalCSV renders a journal with one row per line
+func RenderJournalCSV(journal *entities.AccountingJournal) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"Journal No", "Date", "Account", "Debit", "Credit", "Currency", "Memo"}); err != nil {
+		return nil, err
+	}
+
+	date := journal.Date.Format("2006-01-02")
+	for _, line := range journal.Lines {
+		debit, credit := "", ""
+		if line.PostingType == entities.EntryTypeDebit {
+			debit = line.Amount.StringFixed(2)
+		} else {
+			credit = line.Amount.StringFixed(2)
+		}
+		if err := w.Write([]string{journal.Reference, date, line.GLAccount, debit, credit, line.Currency, line.Description}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render journal csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// LocalDirectory stores journal files in a directory, e.g. a mounted outbox
+// the finance team's SFTP server collects from
+type LocalDirectory struct {
+	dir string
+}
+
+// NewLocalDirectory creates a file store rooted at dir
+func NewLocalDirectory(dir string) *LocalDirectory {
+	return &LocalDirectory{dir: dir}
+}
+
+// Put writes the file atomically so a collector never sees a partial file
+func (d *LocalDirectory) Put(ctx context.Context, name string, content []byte) (string, error) {
+	if err := os.MkdirAll(d.dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	path := filepath.Join(d.dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o640); err != nil {
+		return "", fmt.Errorf("failed to write journal file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to move journal file into place: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,299 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultQuickBooksBaseURL        = "https://quickbooks.api.intuit.com"
+	defaultQuickBooksSandboxBaseURL = "https://sandbox-quickbooks.api.intuit.com"
+	defaultQuickBooksTokenURL       = "https://oauth.platform.intuit.com/oauth2/v1/tokens/bearer"
+	quickBooksMinorVersion          = "65"
+)
+
+// QuickBooksConfig holds the QuickBooks Online app credentials and company
+type QuickBooksConfig struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string // Seeds the OAuth session; Intuit rotates it on refresh
+	RealmID      string // QuickBooks company ID
+	Environment  string // sandbox or production, used to pick the default URL
+	BaseURL      string
+	TokenURL     string
+	Timeout      time.Duration
+}
+
+// QuickBooksExporter posts each day's journal as a QuickBooks Online
+// JournalEntry. Creates carry a request ID derived from the journal so a
+// retried request is not booked twice; a re-export updates the entry
+// created earlier.
+type QuickBooksExporter struct {
+	config     QuickBooksConfig
+	httpClient *http.Client
+	logger     *zap.Logger
+
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	expiresAt    time.Time
+}
+
+// NewQuickBooksExporter creates a QuickBooks Online exporter
+func NewQuickBooksExporter(config QuickBooksConfig, logger *zap.Logger) *QuickBooksExporter {
+	if config.BaseURL == "" {
+		config.BaseURL = defaultQuickBooksBaseURL
+		if config.Environment != "production" {
+			config.BaseURL = defaultQuickBooksSandboxBaseURL
+		}
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = defaultQuickBooksTokenURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &QuickBooksExporter{
+		config:       config,
+		httpClient:   &http.Client{Timeout: config.Timeout},
+		logger:       logger,
+		refreshToken: config.RefreshToken,
+	}
+}
+
+// Name identifies the destination in export records
+func (q *QuickBooksExporter) Name() string {
+	return "quickbooks"
+}
+
+type qbRef struct {
+	Value string `json:"value"`
+}
+
+type qbJournalLineDetail struct {
+	PostingType string `json:"PostingType"`
+	AccountRef  qbRef  `json:"AccountRef"`
+}
+
+type qbLine struct {
+	DetailType             string              `json:"DetailType"`
+	Amount                 json.Number         `json:"Amount"`
+	Description            string              `json:"Description,omitempty"`
+	JournalEntryLineDetail qbJournalLineDetail `json:"JournalEntryLineDetail"`
+}
+
+type qbJournalEntry struct {
+	ID          string   `json:"Id,omitempty"`
+	SyncToken   string   `json:"SyncToken,omitempty"`
+	DocNumber   string   `json:"DocNumber"`
+	TxnDate     string   `json:"TxnDate"`
+	PrivateNote string   `json:"PrivateNote,omitempty"`
+	Line        []qbLine `json:"Line"`
+}
+
+type qbJournalEntryResponse struct {
+	JournalEntry qbJournalEntry `json:"JournalEntry"`
+}
+
+type qbFaultResponse struct {
+	Fault struct {
+		Error []struct {
+			Message string `json:"Message"`
+			Detail  string `json:"Detail"`
+			Code    string `json:"code"`
+		} `json:"Error"`
+	} `json:"Fault"`
+}
+
+// ExportJournal creates the day's journal entry, or replaces the one created
+// by an earlier export, and returns its QuickBooks ID
+func (q *QuickBooksExporter) ExportJournal(ctx context.Context, journal *entities.AccountingJournal, previousRef string) (string, error) {
+	entry := qbJournalEntry{
+		DocNumber:   journal.Reference,
+		TxnDate:     journal.Date.Format("2006-01-02"),
+		PrivateNote: "Daily summary of the Stack platform ledger",
+		Line:        make([]qbLine, 0, len(journal.Lines)),
+	}
+	for _, line := range journal.Lines {
+		postingType := "Credit"
+		if line.PostingType == entities.EntryTypeDebit {
+			postingType = "Debit"
+		}
+		entry.Line = append(entry.Line, qbLine{
+			DetailType:  "JournalEntryLineDetail",
+			Amount:      json.Number(line.Amount.StringFixed(2)),
+			Description: line.Description,
+			JournalEntryLineDetail: qbJournalLineDetail{
+				PostingType: postingType,
+				AccountRef:  qbRef{Value: line.GLAccount},
+			},
+		})
+	}
+
+	query := url.Values{"minorversion": {quickBooksMinorVersion}}
+	if previousRef != "" {
+		existing, err := q.getJournalEntry(ctx, previousRef)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			entry.ID = existing.ID
+			entry.SyncToken = existing.SyncToken
+		}
+	}
+	if entry.ID == "" {
+		// QuickBooks answers a repeated request ID with the original result
+		requestID := journal.Reference + "-" + journal.Checksum
+		if len(requestID) > 50 {
+			requestID = requestID[:50]
+		}
+		query.Set("requestid", requestID)
+	}
+
+	var resp qbJournalEntryResponse
+	if err := q.do(ctx, http.MethodPost, "/journalentry?"+query.Encode(), entry, &resp); err != nil {
+		return "", err
+	}
+	if resp.JournalEntry.ID == "" {
+		return "", fmt.Errorf("quickbooks returned no journal entry id")
+	}
+	return resp.JournalEntry.ID, nil
+}
+
+// getJournalEntry reads an entry for its sync token, returning nil when it
+// no longer exists
+func (q *QuickBooksExporter) getJournalEntry(ctx context.Context, id string) (*qbJournalEntry, error) {
+	var resp qbJournalEntryResponse
+	err := q.do(ctx, http.MethodGet, "/journalentry/"+url.PathEscape(id)+"?minorversion="+quickBooksMinorVersion, nil, &resp)
+	if err != nil {
+		if apiErr, ok := err.(*quickBooksError); ok && (apiErr.Status == http.StatusNotFound || apiErr.Code == "610") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &resp.JournalEntry, nil
+}
+
+// quickBooksError is an error response from the accounting API
+type quickBooksError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *quickBooksError) Error() string {
+	return fmt.Sprintf("quickbooks error (status %d, code %s): %s", e.Status, e.Code, e.Message)
+}
+
+func (q *QuickBooksExporter) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := q.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode quickbooks request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/company/%s%s", strings.TrimRight(q.config.BaseURL, "/"), url.PathEscape(q.config.RealmID), path)
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("quickbooks request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read quickbooks response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		q.mu.Lock()
+		q.accessToken = ""
+		q.mu.Unlock()
+	}
+	var fault qbFaultResponse
+	if resp.StatusCode >= 300 || (json.Unmarshal(respBody, &fault) == nil && len(fault.Fault.Error) > 0) {
+		apiErr := &quickBooksError{Status: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+		if len(fault.Fault.Error) > 0 {
+			apiErr.Code = fault.Fault.Error[0].Code
+			apiErr.Message = fault.Fault.Error[0].Message + ": " + fault.Fault.Error[0].Detail
+		}
+		return apiErr
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode quickbooks response: %w", err)
+	}
+	return nil
+}
+
+// token returns a valid access token, refreshing the OAuth session when the
+// current token is about to expire
+func (q *QuickBooksExporter) token(ctx context.Context) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.accessToken != "" && time.Now().Before(q.expiresAt.Add(-time.Minute)) {
+		return q.accessToken, nil
+	}
+
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {q.refreshToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, q.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(q.config.ClientID, q.config.ClientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("quickbooks token refresh failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("quickbooks token refresh failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", fmt.Errorf("failed to decode quickbooks token: %w", err)
+	}
+	if tokens.RefreshToken != "" && tokens.RefreshToken != q.refreshToken {
+		q.refreshToken = tokens.RefreshToken
+		q.logger.Info("QuickBooks refresh token rotated; update accounting.quickbooks.refresh_token before the configured one expires")
+	}
+	q.accessToken = tokens.AccessToken
+	q.expiresAt = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+	return q.accessToken, nil
+}
@@ -0,0 +1,296 @@
+package accounting
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP v3 packet types and flags used for uploads (draft-ietf-secsh-filexfer-02)
+const (
+	sftpInit    = 1
+	sftpVersion = 2
+	sftpOpen    = 3
+	sftpClose   = 4
+	sftpWrite   = 6
+	sftpRemove  = 13
+	sftpRename  = 18
+	sftpStatus  = 101
+	sftpHandle  = 102
+
+	sftpFlagWrite    = 0x02
+	sftpFlagCreate   = 0x08
+	sftpFlagTruncate = 0x10
+
+	sftpStatusOK = 0
+
+	sftpChunkSize = 32 * 1024
+)
+
+// SFTPConfig locates the finance team's SFTP drop
+type SFTPConfig struct {
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	PrivateKey string // PEM; used instead of Password when set
+	HostKey    string // Server public key in authorized_keys format; required
+	Dir        string
+	Timeout    time.Duration
+}
+
+// SFTPStore uploads journal files over SFTP. Files are written under a
+// temporary name and renamed, so a collector never picks up a partial file.
+type SFTPStore struct {
+	config       SFTPConfig
+	clientConfig *ssh.ClientConfig
+}
+
+// NewSFTPStore validates the credentials and host key and creates the store
+func NewSFTPStore(config SFTPConfig) (*SFTPStore, error) {
+	if config.Port == 0 {
+		config.Port = 22
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sftp host key: %w", err)
+	}
+
+	var auth ssh.AuthMethod
+	switch {
+	case config.PrivateKey != "":
+		signer, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sftp private key: %w", err)
+		}
+		auth = ssh.PublicKeys(signer)
+	case config.Password != "":
+		auth = ssh.Password(config.Password)
+	default:
+		return nil, errors.New("sftp requires a password or private key")
+	}
+
+	return &SFTPStore{
+		config: config,
+		clientConfig: &ssh.ClientConfig{
+			User:            config.User,
+			Auth:            []ssh.AuthMethod{auth},
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			Timeout:         config.Timeout,
+		},
+	}, nil
+}
+
+// Put uploads the file into the configured directory
+func (s *SFTPStore) Put(ctx context.Context, name string, content []byte) (string, error) {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	dialer := net.Dialer{Timeout: s.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to sftp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(2 * s.config.Timeout))
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, s.clientConfig)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("sftp handshake failed: %w", err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("failed to open sftp session: %w", err)
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return "", err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return "", fmt.Errorf("sftp subsystem unavailable: %w", err)
+	}
+
+	sc := &sftpConn{w: stdin, r: stdout}
+	if err := sc.init(); err != nil {
+		return "", err
+	}
+
+	target := path.Join(s.config.Dir, name)
+	tmp := target + ".tmp"
+	if err := sc.upload(tmp, content); err != nil {
+		return "", err
+	}
+	// SFTP v3 rename does not overwrite, so clear a previous export first
+	sc.remove(target)
+	if err := sc.rename(tmp, target); err != nil {
+		return "", err
+	}
+	return "sftp://" + addr + target, nil
+}
+
+// sftpConn speaks the handful of SFTP v3 requests an upload needs
+type sftpConn struct {
+	w      io.Writer
+	r      io.Reader
+	nextID uint32
+}
+
+func (c *sftpConn) init() error {
+	if err := c.send(sftpInit, uint32Bytes(3)); err != nil {
+		return err
+	}
+	typ, _, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if typ != sftpVersion {
+		return fmt.Errorf("unexpected sftp packet %d during init", typ)
+	}
+	return nil
+}
+
+func (c *sftpConn) upload(name string, content []byte) error {
+	payload := appendString(nil, name)
+	payload = binary.BigEndian.AppendUint32(payload, sftpFlagWrite|sftpFlagCreate|sftpFlagTruncate)
+	payload = binary.BigEndian.AppendUint32(payload, 0) // no attributes
+	handle, err := c.request(sftpOpen, payload)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+
+	for offset := 0; offset < len(content); offset += sftpChunkSize {
+		end := offset + sftpChunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+		payload := appendString(nil, string(handle))
+		payload = binary.BigEndian.AppendUint64(payload, uint64(offset))
+		payload = appendString(payload, string(content[offset:end]))
+		if _, err := c.request(sftpWrite, payload); err != nil {
+			c.request(sftpClose, appendString(nil, string(handle)))
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if _, err := c.request(sftpClose, appendString(nil, string(handle))); err != nil {
+		return fmt.Errorf("failed to close %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *sftpConn) remove(name string) error {
+	_, err := c.request(sftpRemove, appendString(nil, name))
+	return err
+}
+
+func (c *sftpConn) rename(from, to string) error {
+	if _, err := c.request(sftpRename, appendString(appendString(nil, from), to)); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", from, err)
+	}
+	return nil
+}
+
+// request sends a packet with a fresh request ID and waits for its reply.
+// It returns the handle of a HANDLE reply, or an error for a failed STATUS.
+func (c *sftpConn) request(typ byte, payload []byte) ([]byte, error) {
+	c.nextID++
+	id := c.nextID
+	if err := c.send(typ, append(uint32Bytes(id), payload...)); err != nil {
+		return nil, err
+	}
+
+	replyType, body, err := c.recv()
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < 4 || binary.BigEndian.Uint32(body) != id {
+		return nil, errors.New("sftp reply does not match request")
+	}
+	body = body[4:]
+
+	switch replyType {
+	case sftpHandle:
+		handle, _, ok := readString(body)
+		if !ok {
+			return nil, errors.New("malformed sftp handle")
+		}
+		return handle, nil
+	case sftpStatus:
+		if len(body) < 4 {
+			return nil, errors.New("malformed sftp status")
+		}
+		if code := binary.BigEndian.Uint32(body); code != sftpStatusOK {
+			message, _, _ := readString(body[4:])
+			return nil, fmt.Errorf("sftp error %d: %s", code, message)
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected sftp packet %d", replyType)
+}
+
+func (c *sftpConn) send(typ byte, payload []byte) error {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, typ)
+	packet = append(packet, payload...)
+	_, err := c.w.Write(packet)
+	return err
+}
+
+func (c *sftpConn) recv() (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("failed to read sftp reply: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > 256*1024 {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %d", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(c.r, packet); err != nil {
+		return 0, nil, fmt.Errorf("failed to read sftp reply: %w", err)
+	}
+	return packet[0], packet[1:], nil
+}
+
+func uint32Bytes(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func readString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
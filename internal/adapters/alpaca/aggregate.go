@@ -0,0 +1,54 @@
+package alpaca
+
+import (
+	"context"
+	"sync"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// SumBuyingPower reads the accounts with at most concurrency requests in
+// flight and totals their buying power. The Broker API has no bulk account
+// endpoint, so each account is one request; accounts that fail are reported
+// as skipped rather than aborting the sum.
+func (s *Service) SumBuyingPower(ctx context.Context, accountIDs []string, concurrency int) *entities.BalanceAggregate {
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	aggregate := &entities.BalanceAggregate{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			mu.Lock()
+			aggregate.Skip(accountID)
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(accountID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			account, err := s.client.GetAccount(ctx, accountID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				s.logger.Warn("Skipping Alpaca account in buying power total",
+					zap.String("account_id", accountID),
+					zap.Error(err))
+				aggregate.Skip(accountID)
+				return
+			}
+			aggregate.Total = aggregate.Total.Add(account.BuyingPower)
+			aggregate.Counted++
+		}(accountID)
+	}
+	wg.Wait()
+	return aggregate
+}
@@ -15,6 +15,8 @@ import (
 	
 	"github.com/sony/gobreaker"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
 	"go.uber.org/zap"
 )
 
@@ -34,6 +36,9 @@ const (
 	assetsEndpoint    = "/v1/assets"
 	positionsEndpoint = "/v1/trading/accounts/%s/positions" // account_id parameter
 	newsEndpoint      = "/v1beta1/news"
+	quotesEndpoint    = "/v2/stocks/quotes/latest"
+	barsEndpoint      = "/v2/stocks/bars"
+	calendarEndpoint  = "/v1/calendar"
 )
 
 // Config represents Alpaca API configuration
@@ -76,14 +81,14 @@ func NewClient(config Config, logger *zap.Logger) *Client {
 
 	httpClient := &http.Client{
 		Timeout: config.Timeout,
-		Transport: &http.Transport{
+		Transport: metrics.InstrumentProvider(metrics.ProviderAlpaca, &http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
 			},
 			MaxIdleConns:        100,
 			MaxIdleConnsPerHost: 10,
 			IdleConnTimeout:     90 * time.Second,
-		},
+		}),
 	}
 
 	st := gobreaker.Settings{
@@ -97,12 +102,14 @@ func NewClient(config Config, logger *zap.Logger) *Client {
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Info("Circuit breaker state changed",
 				zap.String("name", name),
-				zap.String("from", from.String()),
-				zap.String("to", to.String()))
+				zap.String("from_state", from.String()),
+				zap.String("to_state", to.String()))
+			metrics.SetProviderCircuitState(metrics.ProviderAlpaca, name, to.String())
 		},
 	}
 
 	circuitBreaker := gobreaker.NewCircuitBreaker(st)
+	metrics.SetProviderCircuitState(metrics.ProviderAlpaca, st.Name, circuitBreaker.State().String())
 
 	return &Client{
 		config:         config,
@@ -112,12 +119,18 @@ func NewClient(config Config, logger *zap.Logger) *Client {
 	}
 }
 
+// WrapTransport routes the client's calls through wrap, which receives the
+// current transport, e.g. to inject faults in test environments
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // Account Management Methods
 
 // CreateAccount creates a new brokerage account
 func (c *Client) CreateAccount(ctx context.Context, req *entities.AlpacaCreateAccountRequest) (*entities.AlpacaAccountResponse, error) {
 	c.logger.Info("Creating Alpaca brokerage account",
-		zap.String("email", req.Contact.EmailAddress))
+		logger.Email(req.Contact.EmailAddress))
 
 	var response entities.AlpacaAccountResponse
 	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
@@ -126,7 +139,7 @@ func (c *Client) CreateAccount(ctx context.Context, req *entities.AlpacaCreateAc
 
 	if err != nil {
 		c.logger.Error("Failed to create Alpaca account",
-			zap.String("email", req.Contact.EmailAddress),
+			logger.Email(req.Contact.EmailAddress),
 			zap.Error(err))
 		return nil, fmt.Errorf("create account failed: %w", err)
 	}
@@ -428,6 +441,80 @@ func (c *Client) GetNews(ctx context.Context, req *entities.AlpacaNewsRequest) (
 	return &response, nil
 }
 
+// GetLatestQuotes fetches the latest NBBO quote for each symbol from the
+// market data API
+func (c *Client) GetLatestQuotes(ctx context.Context, symbols []string) (map[string]entities.AlpacaLatestQuote, error) {
+	endpoint := fmt.Sprintf("%s?%s", quotesEndpoint, url.Values{"symbols": {strings.Join(symbols, ",")}}.Encode())
+
+	var response entities.AlpacaLatestQuotesResponse
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return &response, c.doRequestWithRetry(ctx, "GET", endpoint, nil, &response, true)
+	})
+	if err != nil {
+		c.logger.Error("Failed to fetch Alpaca quotes", zap.Strings("symbols", symbols), zap.Error(err))
+		return nil, fmt.Errorf("get latest quotes failed: %w", err)
+	}
+
+	return response.Quotes, nil
+}
+
+// GetDailyBars fetches daily bars for each symbol between start and end from
+// the market data API, following pagination until every bar is loaded
+func (c *Client) GetDailyBars(ctx context.Context, symbols []string, start, end time.Time) (map[string][]entities.AlpacaBar, error) {
+	bars := make(map[string][]entities.AlpacaBar, len(symbols))
+	pageToken := ""
+	for {
+		query := url.Values{
+			"symbols":   {strings.Join(symbols, ",")},
+			"timeframe": {"1Day"},
+			"start":     {start.UTC().Format(time.RFC3339)},
+			"end":       {end.UTC().Format(time.RFC3339)},
+			"limit":     {"10000"},
+		}
+		if pageToken != "" {
+			query.Set("page_token", pageToken)
+		}
+		endpoint := fmt.Sprintf("%s?%s", barsEndpoint, query.Encode())
+
+		var response entities.AlpacaBarsResponse
+		_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+			return &response, c.doRequestWithRetry(ctx, "GET", endpoint, nil, &response, true)
+		})
+		if err != nil {
+			c.logger.Error("Failed to fetch Alpaca bars", zap.Strings("symbols", symbols), zap.Error(err))
+			return nil, fmt.Errorf("get daily bars failed: %w", err)
+		}
+
+		for symbol, page := range response.Bars {
+			bars[symbol] = append(bars[symbol], page...)
+		}
+		if response.NextPageToken == "" {
+			return bars, nil
+		}
+		pageToken = response.NextPageToken
+	}
+}
+
+// GetCalendar fetches the trading sessions between start and end, inclusive.
+// Days the market is closed are not listed.
+func (c *Client) GetCalendar(ctx context.Context, start, end time.Time) ([]entities.AlpacaCalendarDay, error) {
+	endpoint := fmt.Sprintf("%s?%s", calendarEndpoint, url.Values{
+		"start": {start.Format("2006-01-02")},
+		"end":   {end.Format("2006-01-02")},
+	}.Encode())
+
+	var response []entities.AlpacaCalendarDay
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return &response, c.doRequestWithRetry(ctx, "GET", endpoint, nil, &response, false)
+	})
+	if err != nil {
+		c.logger.Error("Failed to fetch Alpaca calendar", zap.Error(err))
+		return nil, fmt.Errorf("get calendar failed: %w", err)
+	}
+
+	return response, nil
+}
+
 // HTTP helper methods
 
 // doRequestWithRetry performs an HTTP request with exponential backoff retry
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -33,7 +34,7 @@ func NewFundingAdapter(client *Client, logger *zap.Logger) *FundingAdapter {
 func (a *FundingAdapter) InitiateInstantFunding(ctx context.Context, req *entities.AlpacaInstantFundingRequest) (*entities.AlpacaInstantFundingResponse, error) {
 	a.logger.Info("Initiating Alpaca instant funding",
 		zap.String("account_no", req.AccountNo),
-		zap.String("amount", req.Amount.String()))
+		logger.Amount("amount", req.Amount))
 
 	var response entities.AlpacaInstantFundingResponse
 	_, err := a.client.circuitBreaker.Execute(func() (interface{}, error) {
@@ -94,7 +95,7 @@ func (a *FundingAdapter) CreateJournal(ctx context.Context, req *entities.Alpaca
 	a.logger.Info("Creating Alpaca journal",
 		zap.String("from_account", req.FromAccount),
 		zap.String("to_account", req.ToAccount),
-		zap.String("amount", req.Amount.String()))
+		logger.Amount("amount", req.Amount))
 
 	var response entities.AlpacaJournalResponse
 	_, err := a.client.circuitBreaker.Execute(func() (interface{}, error) {
@@ -0,0 +1,304 @@
+package alpaca
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultStreamURL        = "wss://stream.data.alpaca.markets/v2/iex"
+	defaultSandboxStreamURL = "wss://stream.data.sandbox.alpaca.markets/v2/iex"
+
+	streamHandshakeTimeout = 10 * time.Second
+	streamWriteTimeout     = 10 * time.Second
+	streamPingInterval     = 30 * time.Second
+	streamReadTimeout      = 3 * streamPingInterval
+	streamMaxBackoff       = 30 * time.Second
+
+	// Alpaca closes the connection with these error codes; retrying won't help
+	streamErrAuthFailed      = 402
+	streamErrConnectionLimit = 406
+)
+
+// StreamConfig configures the real-time market data stream
+type StreamConfig struct {
+	URL         string // Market data stream URL including the feed, e.g. .../v2/iex
+	ClientID    string
+	SecretKey   string
+	Environment string // sandbox or production, used to pick the default URL
+}
+
+// streamMessage is any message on the market data stream. Quotes ("q") fill
+// the quote fields; control messages fill Msg and Code.
+type streamMessage struct {
+	Type      string          `json:"T"`
+	Symbol    string          `json:"S"`
+	BidPrice  decimal.Decimal `json:"bp"`
+	BidSize   decimal.Decimal `json:"bs"`
+	AskPrice  decimal.Decimal `json:"ap"`
+	AskSize   decimal.Decimal `json:"as"`
+	Timestamp time.Time       `json:"t"`
+	Msg       string          `json:"msg"`
+	Code      int             `json:"code"`
+}
+
+type streamAction struct {
+	Action string   `json:"action"`
+	Key    string   `json:"key,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	Quotes []string `json:"quotes,omitempty"`
+}
+
+// streamError is an error reported by the stream that retrying won't fix
+type streamError struct {
+	code int
+	msg  string
+}
+
+func (e *streamError) Error() string {
+	return fmt.Sprintf("market data stream error %d: %s", e.code, e.msg)
+}
+
+// QuoteStream consumes Alpaca's real-time quote stream over a WebSocket. The
+// subscribed symbols can change at any time and are restored on reconnect.
+type QuoteStream struct {
+	config StreamConfig
+	logger *zap.Logger
+	dialer *websocket.Dialer
+
+	mu      sync.Mutex
+	symbols []string
+	changed chan struct{}
+}
+
+// NewQuoteStream creates a new quote stream
+func NewQuoteStream(config StreamConfig, logger *zap.Logger) *QuoteStream {
+	if strings.TrimSpace(config.URL) == "" {
+		config.URL = defaultStreamURL
+		if config.Environment == "sandbox" {
+			config.URL = defaultSandboxStreamURL
+		}
+	}
+	return &QuoteStream{
+		config:  config,
+		logger:  logger,
+		dialer:  &websocket.Dialer{HandshakeTimeout: streamHandshakeTimeout},
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// SetSymbols replaces the symbols quotes are streamed for
+func (q *QuoteStream) SetSymbols(symbols []string) {
+	q.mu.Lock()
+	q.symbols = append([]string(nil), symbols...)
+	q.mu.Unlock()
+
+	select {
+	case q.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Run streams quotes to onQuote until ctx is done, reconnecting with backoff
+// when the connection drops. It returns an error only when Alpaca rejects
+// the credentials or the connection limit is reached.
+func (q *QuoteStream) Run(ctx context.Context, onQuote func(*entities.MarketQuote)) error {
+	backoff := baseBackoff
+	for ctx.Err() == nil {
+		authenticated, err := q.session(ctx, onQuote)
+		if ctx.Err() != nil {
+			return nil
+		}
+		var fatal *streamError
+		if errors.As(err, &fatal) && (fatal.code == streamErrAuthFailed || fatal.code == streamErrConnectionLimit) {
+			return err
+		}
+		if authenticated {
+			backoff = baseBackoff
+		}
+
+		q.logger.Warn("Market data stream disconnected, reconnecting",
+			zap.Error(err),
+			zap.Duration("backoff", backoff))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		backoff = time.Duration(math.Min(float64(backoff*2), float64(streamMaxBackoff)))
+	}
+	return nil
+}
+
+// session runs one connection and reports whether it got past authentication
+func (q *QuoteStream) session(ctx context.Context, onQuote func(*entities.MarketQuote)) (bool, error) {
+	conn, _, err := q.dialer.DialContext(ctx, q.config.URL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to market data stream: %w", err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+	})
+
+	if err := q.expect(conn, "connected"); err != nil {
+		return false, err
+	}
+	conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if err := conn.WriteJSON(streamAction{Action: "auth", Key: q.config.ClientID, Secret: q.config.SecretKey}); err != nil {
+		return false, fmt.Errorf("failed to authenticate market data stream: %w", err)
+	}
+	if err := q.expect(conn, "authenticated"); err != nil {
+		return false, err
+	}
+	q.logger.Info("Market data stream connected", zap.String("url", q.config.URL))
+
+	go q.maintain(ctx, conn, done)
+
+	for {
+		messages, err := q.read(conn)
+		if err != nil {
+			return true, err
+		}
+		for _, msg := range messages {
+			switch msg.Type {
+			case "q":
+				onQuote(&entities.MarketQuote{
+					Symbol:    msg.Symbol,
+					BidPrice:  msg.BidPrice,
+					BidSize:   msg.BidSize,
+					AskPrice:  msg.AskPrice,
+					AskSize:   msg.AskSize,
+					Timestamp: msg.Timestamp,
+				})
+			case "error":
+				if msg.Code == streamErrAuthFailed || msg.Code == streamErrConnectionLimit {
+					return true, &streamError{code: msg.Code, msg: msg.Msg}
+				}
+				q.logger.Warn("Market data stream error", zap.Int("code", msg.Code), zap.String("message", msg.Msg))
+			}
+		}
+	}
+}
+
+// maintain keeps the connection's subscriptions in line with the requested
+// symbols and pings the server. It is the only writer once authenticated.
+func (q *QuoteStream) maintain(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	subscribed := make(map[string]bool)
+	resync := func() error {
+		q.mu.Lock()
+		wanted := make(map[string]bool, len(q.symbols))
+		for _, symbol := range q.symbols {
+			wanted[symbol] = true
+		}
+		q.mu.Unlock()
+
+		var add, remove []string
+		for symbol := range wanted {
+			if !subscribed[symbol] {
+				add = append(add, symbol)
+			}
+		}
+		for symbol := range subscribed {
+			if !wanted[symbol] {
+				remove = append(remove, symbol)
+			}
+		}
+		if len(add) > 0 {
+			sort.Strings(add)
+			if err := q.write(conn, streamAction{Action: "subscribe", Quotes: add}); err != nil {
+				return err
+			}
+		}
+		if len(remove) > 0 {
+			sort.Strings(remove)
+			if err := q.write(conn, streamAction{Action: "unsubscribe", Quotes: remove}); err != nil {
+				return err
+			}
+		}
+		subscribed = wanted
+		return nil
+	}
+
+	err := resync()
+	for err == nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-q.changed:
+			err = resync()
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+		}
+	}
+	q.logger.Warn("Failed to write to market data stream", zap.Error(err))
+	conn.Close()
+}
+
+func (q *QuoteStream) write(conn *websocket.Conn, action streamAction) error {
+	conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return conn.WriteJSON(action)
+}
+
+func (q *QuoteStream) read(conn *websocket.Conn) ([]streamMessage, error) {
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+
+	var messages []streamMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode market data message: %w", err)
+	}
+	return messages, nil
+}
+
+// expect reads control messages until a success message with msg arrives
+func (q *QuoteStream) expect(conn *websocket.Conn, msg string) error {
+	for {
+		messages, err := q.read(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range messages {
+			switch {
+			case m.Type == "success" && m.Msg == msg:
+				return nil
+			case m.Type == "error":
+				return &streamError{code: m.Code, msg: m.Msg}
+			}
+		}
+	}
+}
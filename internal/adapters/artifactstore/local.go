@@ -0,0 +1,109 @@
+package artifactstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+)
+
+// LocalStore keeps artifacts as files under a directory on the server. It
+// suits development and single-instance deployments; instances behind a
+// load balancer need a shared store such as S3.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store rooted at dir, creating it if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, errors.New("local artifact directory is required")
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local artifact directory: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create local artifact directory: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Scheme returns "local"
+func (s *LocalStore) Scheme() string {
+	return aiartifacts.StorageLocal
+}
+
+// Put writes body to a temporary file and renames it into place, so readers
+// never see a partial artifact
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, body []byte) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".artifact-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to store artifact: %w", err)
+	}
+	return s.Scheme() + "://" + strings.TrimLeft(filepath.ToSlash(key), "/"), nil
+}
+
+// Retrieve reads the artifact at uri
+func (s *LocalStore) Retrieve(ctx context.Context, uri string) ([]byte, error) {
+	path, err := s.uriPath(uri)
+	if err != nil {
+		return nil, err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	return body, nil
+}
+
+// Delete removes the artifact at uri; a missing file is not an error
+func (s *LocalStore) Delete(ctx context.Context, uri string) error {
+	path, err := s.uriPath(uri)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete artifact: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) uriPath(uri string) (string, error) {
+	key, ok := strings.CutPrefix(uri, s.Scheme()+"://")
+	if !ok {
+		return "", fmt.Errorf("not a local artifact URI: %q", uri)
+	}
+	return s.path(key)
+}
+
+// path maps key to a file under the root, refusing keys that escape it
+func (s *LocalStore) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if path == s.root || !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid artifact key %q", key)
+	}
+	return path, nil
+}
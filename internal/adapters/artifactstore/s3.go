@@ -0,0 +1,106 @@
+package artifactstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	uploadstore "github.com/stack-service/stack_service/internal/adapters/upload"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+)
+
+// putURLTTL bounds the pre-signed URLs the store writes artifacts through
+const putURLTTL = 5 * time.Minute
+
+// S3Store keeps artifacts in an S3 bucket, signing its requests the same way
+// the document upload store does. URIs have the form s3://bucket/key.
+type S3Store struct {
+	objects    *uploadstore.S3Store
+	bucket     string
+	httpClient *http.Client
+}
+
+// NewS3Store creates an artifact store on the configured bucket
+func NewS3Store(config uploadstore.S3Config) (*S3Store, error) {
+	objects, err := uploadstore.NewS3Store(config)
+	if err != nil {
+		return nil, err
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &S3Store{
+		objects:    objects,
+		bucket:     config.Bucket,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Scheme returns "s3"
+func (s *S3Store) Scheme() string {
+	return aiartifacts.StorageS3
+}
+
+// Put uploads body under key
+func (s *S3Store) Put(ctx context.Context, key, contentType string, body []byte) (string, error) {
+	key = strings.TrimLeft(key, "/")
+	headers := map[string]string{"Content-Type": contentType}
+	signed, err := s.objects.Presign(http.MethodPut, key, headers, putURLTTL)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, signed, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("s3 PUT failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("s3 PUT returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return s.Scheme() + "://" + s.bucket + "/" + key, nil
+}
+
+// Retrieve downloads the artifact at uri
+func (s *S3Store) Retrieve(ctx context.Context, uri string) ([]byte, error) {
+	key, err := s.key(uri)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.objects.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+// Delete removes the artifact at uri
+func (s *S3Store) Delete(ctx context.Context, uri string) error {
+	key, err := s.key(uri)
+	if err != nil {
+		return err
+	}
+	return s.objects.Delete(ctx, key)
+}
+
+// key returns the object key of a URI in this store's bucket
+func (s *S3Store) key(uri string) (string, error) {
+	key, ok := strings.CutPrefix(uri, s.Scheme()+"://"+s.bucket+"/")
+	if !ok || key == "" {
+		return "", fmt.Errorf("not an artifact URI in bucket %s: %q", s.bucket, uri)
+	}
+	return key, nil
+}
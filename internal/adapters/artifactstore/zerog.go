@@ -0,0 +1,96 @@
+package artifactstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+)
+
+// maxArtifactSize bounds what is read back for a single artifact
+const maxArtifactSize = 16 << 20
+
+// ZeroGStore reads artifacts from 0G storage through an indexer's download
+// endpoint. The URI's last path segment is the file's root hash, as in
+// 0g://ai-summaries/<root>. Writing to 0G means submitting the file to the
+// flow contract with a funded key, which this service does not do, so the
+// store is read-only: it is where existing artifacts are served and migrated
+// from.
+type ZeroGStore struct {
+	indexerURL *url.URL
+	httpClient *http.Client
+}
+
+// NewZeroGStore creates a store reading through the indexer at indexerRPC
+func NewZeroGStore(indexerRPC string, timeout time.Duration) (*ZeroGStore, error) {
+	indexer, err := url.Parse(strings.TrimRight(indexerRPC, "/"))
+	if err != nil || indexer.Host == "" {
+		return nil, fmt.Errorf("invalid 0G indexer URL %q", indexerRPC)
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &ZeroGStore{
+		indexerURL: indexer,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Scheme returns "0g"
+func (s *ZeroGStore) Scheme() string {
+	return aiartifacts.StorageZeroG
+}
+
+// Put is not supported; see ZeroGStore
+func (s *ZeroGStore) Put(ctx context.Context, key, contentType string, body []byte) (string, error) {
+	return "", aiartifacts.ErrStoreReadOnly
+}
+
+// Retrieve downloads the file whose root hash ends uri
+func (s *ZeroGStore) Retrieve(ctx context.Context, uri string) ([]byte, error) {
+	root := path.Base(strings.TrimPrefix(uri, s.Scheme()+"://"))
+	if root == "" || root == "." || root == "/" {
+		return nil, fmt.Errorf("not a 0G artifact URI: %q", uri)
+	}
+	download := *s.indexerURL
+	download.Path += "/file"
+	download.RawQuery = url.Values{"root": {root}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, download.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("0G download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.New("object not found on storage nodes")
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("0G download returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxArtifactSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read 0G download: %w", err)
+	}
+	if len(body) > maxArtifactSize {
+		return nil, fmt.Errorf("0G artifact exceeds %d bytes", maxArtifactSize)
+	}
+	return body, nil
+}
+
+// Delete does nothing: files on 0G cannot be removed once submitted. A
+// migrated artifact simply stops being referenced.
+func (s *ZeroGStore) Delete(ctx context.Context, uri string) error {
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package auditanchor mirrors audit chain head anchors to storage outside the
+// primary database, so that rewriting the database alone cannot hide
+// tampering with the audit log.
+package auditanchor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/adapters/artifactstore"
+)
+
+// rootPattern finds the file's merkle root in the upload command's log output
+var rootPattern = regexp.MustCompile(`root[\s=:"]+(0x[0-9a-fA-F]{64})`)
+
+// ZeroGConfig configures anchoring to 0G storage
+type ZeroGConfig struct {
+	UploadCommand string        // Path of the 0g-storage-client binary
+	RPCEndpoint   string        // Chain RPC the flow contract submission is sent to
+	IndexerRPC    string        // Indexer that selects storage nodes and serves downloads
+	PrivateKey    string        // Funded key that signs the submission
+	Namespace     string        // Prefix of anchor URIs, e.g. audit-anchors/
+	Timeout       time.Duration // Limit on one upload or download
+}
+
+// ZeroGStore writes anchors to 0G storage with the 0g-storage-client upload
+// command, which submits the file to the flow contract and its segments to
+// the storage nodes, and reads them back through the indexer. Files on 0G are
+// addressed by their merkle root and cannot be changed or removed, so an
+// anchor read back by its URI is the one that was written.
+type ZeroGStore struct {
+	config ZeroGConfig
+	reader *artifactstore.ZeroGStore
+	run    func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// NewZeroGStore creates a 0G anchor store
+func NewZeroGStore(config ZeroGConfig) (*ZeroGStore, error) {
+	if config.UploadCommand == "" {
+		return nil, errors.New("0G anchor upload command is not configured")
+	}
+	if config.RPCEndpoint == "" || config.PrivateKey == "" {
+		return nil, errors.New("0G anchoring needs zerog.storage.rpc_endpoint and private_key")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Minute
+	}
+	reader, err := artifactstore.NewZeroGStore(config.IndexerRPC, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &ZeroGStore{
+		config: config,
+		reader: reader,
+		run:    runCommand,
+	}, nil
+}
+
+// SetRunner replaces how the upload command is run, for tests
+func (s *ZeroGStore) SetRunner(run func(ctx context.Context, name string, args ...string) ([]byte, error)) {
+	s.run = run
+}
+
+// StoreAnchor uploads the anchor and returns its 0g:// URI
+func (s *ZeroGStore) StoreAnchor(ctx context.Context, key string, payload []byte) (string, error) {
+	file, err := os.CreateTemp("", "audit-anchor-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage anchor: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(payload); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to stage anchor: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to stage anchor: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	output, err := s.run(ctx, s.config.UploadCommand, "upload",
+		"--url", s.config.RPCEndpoint,
+		"--indexer", s.config.IndexerRPC,
+		"--key", s.config.PrivateKey,
+		"--file", file.Name())
+	if err != nil {
+		return "", fmt.Errorf("0G upload of %s failed: %w: %s", key, err, lastLine(output))
+	}
+	match := rootPattern.FindSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("0G upload of %s reported no root hash: %s", key, lastLine(output))
+	}
+	return "0g://" + strings.TrimSuffix(s.config.Namespace, "/") + "/" + string(match[1]), nil
+}
+
+// LoadAnchor downloads the anchor at uri from 0G
+func (s *ZeroGStore) LoadAnchor(ctx context.Context, uri string) ([]byte, error) {
+	if !strings.HasPrefix(uri, s.reader.Scheme()+"://") {
+		return nil, fmt.Errorf("not a 0G anchor URI: %q", uri)
+	}
+	return s.reader.Retrieve(ctx, uri)
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// lastLine returns the final line of command output for error messages; the
+// command line itself, which holds the key, is never included
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return lines[len(lines)-1]
+}
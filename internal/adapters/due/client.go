@@ -14,6 +14,7 @@ import (
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
 	"github.com/stack-service/stack_service/pkg/retry"
 )
 
@@ -28,9 +29,10 @@ type Config struct {
 
 // Client represents a Due API client
 type Client struct {
-	config     Config
-	httpClient *http.Client
-	logger     *logger.Logger
+	config      Config
+	httpClient  *http.Client
+	logger      *logger.Logger
+	retryBudget *retry.Budget
 }
 
 // NewClient creates a new Due API client
@@ -48,7 +50,8 @@ func NewClient(config Config, logger *logger.Logger) *Client {
 	}
 
 	httpClient := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: metrics.InstrumentProvider(metrics.ProviderDue, nil),
 	}
 
 	return &Client{
@@ -58,6 +61,18 @@ func NewClient(config Config, logger *logger.Logger) *Client {
 	}
 }
 
+// SetRetryBudget makes retries of failed calls draw on a budget shared with
+// the other provider clients, so a Due outage is not amplified by retries
+func (c *Client) SetRetryBudget(budget *retry.Budget) {
+	c.retryBudget = budget
+}
+
+// WrapTransport routes the client's calls through wrap, which receives the
+// current transport, e.g. to inject faults in test environments
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // CreateVirtualAccountRequest represents a request to create a virtual account via Due API
 type CreateVirtualAccountRequest struct {
 	Destination  string `json:"destination"`  // Crypto address or recipient ID for settlement
@@ -389,6 +404,34 @@ func (c *Client) GetWallet(ctx context.Context, walletID string) (*LinkWalletRes
 	return &response, nil
 }
 
+// ListAccountWallets retrieves the wallets linked to a user's Due account
+func (c *Client) ListAccountWallets(ctx context.Context, accountID string) (*ListWalletsResponse, error) {
+	var response ListWalletsResponse
+	if err := c.doRequestWithAccountID(ctx, "GET", "wallets", accountID, nil, &response); err != nil {
+		return nil, fmt.Errorf("list account wallets failed: %w", err)
+	}
+	return &response, nil
+}
+
+// LinkAccountWallet links a wallet to a user's Due account
+func (c *Client) LinkAccountWallet(ctx context.Context, accountID string, req *LinkWalletRequest) (*LinkWalletResponse, error) {
+	var response LinkWalletResponse
+	if err := c.doRequestWithAccountID(ctx, "POST", "wallets", accountID, req, &response); err != nil {
+		return nil, fmt.Errorf("link account wallet failed: %w", err)
+	}
+	c.logger.Info("Linked wallet to account", "account_id", accountID, "wallet_id", response.ID)
+	return &response, nil
+}
+
+// UnlinkAccountWallet removes a wallet from a user's Due account
+func (c *Client) UnlinkAccountWallet(ctx context.Context, accountID, walletID string) error {
+	endpoint := fmt.Sprintf("wallets/%s", walletID)
+	if err := c.doRequestWithAccountID(ctx, "DELETE", endpoint, accountID, nil, nil); err != nil {
+		return fmt.Errorf("unlink account wallet failed: %w", err)
+	}
+	return nil
+}
+
 // GetWalletBalance retrieves wallet balances
 func (c *Client) GetWalletBalance(ctx context.Context, walletID string) (*WalletBalanceResponse, error) {
 	endpoint := fmt.Sprintf("wallets/%s/balance", walletID)
@@ -459,10 +502,6 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, endpoint string
 		Multiplier:  2.0,
 	}
 
-	retryableFunc := func() error {
-		return c.doRequest(ctx, method, endpoint, body, response)
-	}
-
 	isRetryable := func(err error) bool {
 		if err == nil {
 			return false
@@ -477,7 +516,27 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, endpoint string
 			strings.Contains(errStr, "status 5")
 	}
 
-	return retry.WithExponentialBackoff(ctx, retryConfig, retryableFunc, isRetryable)
+	attempts := 0
+	retryableFunc := func() error {
+		attempts++
+		err := c.doRequest(ctx, method, endpoint, body, response)
+		c.retryBudget.Record(metrics.ProviderDue, !isRetryable(err))
+		return err
+	}
+
+	// The last attempt's error is not retried, so it does not draw on the budget
+	withinBudget := func(err error) bool {
+		if !isRetryable(err) {
+			return false
+		}
+		if attempts >= retryConfig.MaxAttempts || c.retryBudget.AllowRetry(metrics.ProviderDue) {
+			return true
+		}
+		c.logger.Warn("Due API retry suppressed by retry budget", "method", method, "endpoint", endpoint, "attempt", attempts)
+		return false
+	}
+
+	return retry.WithExponentialBackoff(ctx, retryConfig, retryableFunc, withinBudget)
 }
 
 // GetKYCStatus retrieves current KYC status
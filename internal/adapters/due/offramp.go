@@ -27,7 +27,7 @@ func (a *OffRampAdapter) InitiateOffRamp(ctx context.Context, req *OffRampReques
 	a.logger.Info("Initiating off-ramp transfer",
 		"virtual_account_id", req.VirtualAccountID,
 		"recipient_id", req.RecipientID,
-		"amount", req.Amount.String())
+		"amount", logger.RedactAmount(req.Amount))
 
 	// Create transfer request
 	transferReq := &CreateTransferRequest{
@@ -6,6 +6,8 @@ import (
 
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/chainaddr"
+	"github.com/stack-service/stack_service/pkg/logger"
 )
 
 // CreateRecipient creates a recipient for blockchain transfer
@@ -15,9 +17,17 @@ func (a *Adapter) CreateRecipient(ctx context.Context, name, address, chain stri
 		"address", address,
 		"chain", chain)
 
-	schema := "evm"
-	if chain == "solana" || chain == "sol_devnet" {
-		schema = "solana"
+	family := entities.ChainFamilyEVM
+	if info, ok := entities.Chains().Lookup(chain); ok {
+		family = info.Family
+	}
+	schema := family.DueSchema()
+	if schema == "" {
+		return nil, fmt.Errorf("Due does not support %s addresses", family)
+	}
+	// Due accepts malformed addresses and fails the transfer later
+	if _, err := chainaddr.Validate(family.AddressFormat(), address); err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
 	}
 
 	req := &CreateRecipientRequest{
@@ -42,11 +52,11 @@ func (a *Adapter) CreateRecipient(ctx context.Context, name, address, chain stri
 // CreateOnRampQuote generates a quote for USD to USDC conversion
 func (a *Adapter) CreateOnRampQuote(ctx context.Context, usdAmount decimal.Decimal, chain string) (*OnRampQuoteResponse, error) {
 	a.logger.Info("Creating on-ramp quote",
-		"usd_amount", usdAmount.String(),
+		"usd_amount", logger.RedactAmount(usdAmount),
 		"chain", chain)
 
 	rail := "ethereum"
-	if chain == "solana" || chain == "sol_devnet" {
+	if info, ok := entities.Chains().Lookup(chain); ok && info.Family == entities.ChainFamilySolana {
 		rail = "solana"
 	}
 
@@ -136,7 +146,7 @@ func (a *Adapter) GetTransferStatus(ctx context.Context, transferID string) (*On
 func (a *Adapter) ProcessWithdrawal(ctx context.Context, req *entities.InitiateWithdrawalRequest) (*ProcessWithdrawalResponse, error) {
 	a.logger.Info("Processing withdrawal",
 		"user_id", req.UserID.String(),
-		"amount", req.Amount.String(),
+		"amount", logger.RedactAmount(req.Amount),
 		"chain", req.DestinationChain,
 		"address", req.DestinationAddress)
 
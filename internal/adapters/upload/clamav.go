@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	uploadservice "github.com/stack-service/stack_service/internal/domain/services/upload"
+)
+
+// clamdChunkSize is how much content goes in each INSTREAM chunk; clamd's
+// StreamMaxLength limits the total, not the chunk
+const clamdChunkSize = 64 << 10
+
+// ClamAVScanner scans content with a clamd daemon over its INSTREAM command
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates a scanner for the clamd daemon at address
+// (host:port)
+func NewClamAVScanner(address string, timeout time.Duration) (*ClamAVScanner, error) {
+	if address == "" {
+		return nil, fmt.Errorf("clamav address is required")
+	}
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &ClamAVScanner{address: address, timeout: timeout}, nil
+}
+
+// Scan streams content to clamd and reports its verdict
+func (s *ClamAVScanner) Scan(ctx context.Context, content io.Reader) (*uploadservice.ScanResult, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to start clamd scan: %w", err)
+	}
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := content.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, fmt.Errorf("failed to send content to clamd: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, fmt.Errorf("failed to send content to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read content to scan: %w", readErr)
+		}
+	}
+	// A zero-length chunk ends the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to finish clamd scan: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return ParseClamdReply(reply)
+}
+
+// ParseClamdReply reads a clamd INSTREAM reply such as "stream: OK" or
+// "stream: Eicar-Signature FOUND"
+func ParseClamdReply(reply string) (*uploadservice.ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return &uploadservice.ScanResult{Clean: true}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &uploadservice.ScanResult{Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	}
+	return nil, fmt.Errorf("clamd scan failed: %s", reply)
+}
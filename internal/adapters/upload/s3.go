@@ -0,0 +1,267 @@
+package upload
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	uploadservice "github.com/stack-service/stack_service/internal/domain/services/upload"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// maxPresignTTL is the longest validity SigV4 allows for a signed URL
+	maxPresignTTL = 7 * 24 * time.Hour
+	// serverURLTTL bounds the URLs the store signs for its own requests
+	serverURLTTL = 5 * time.Minute
+)
+
+// S3Config locates the bucket and the credentials that sign its URLs.
+// Endpoint points the store at an S3-compatible service such as MinIO or
+// R2; leave it empty for AWS.
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Put the bucket in the path instead of the host name
+	Timeout         time.Duration
+}
+
+// S3Store keeps uploads in an S3 bucket. Every request, including the
+// store's own reads, uses a SigV4 pre-signed URL, so clients never see
+// credentials and the bucket can stay private.
+type S3Store struct {
+	config     S3Config
+	baseURL    *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3Store creates an S3 store
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, fmt.Errorf("s3 bucket and region are required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 access key id and secret access key are required")
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	base, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", endpoint)
+	}
+	if !config.PathStyle {
+		base.Host = config.Bucket + "." + base.Host
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &S3Store{
+		config:     config,
+		baseURL:    base,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		now:        time.Now,
+	}, nil
+}
+
+// SetClock overrides the time source, for tests
+func (s *S3Store) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// PresignPut signs an upload of exactly size bytes of contentType. Both
+// headers are part of the signature, so S3 refuses any other file.
+func (s *S3Store) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, map[string]string, error) {
+	headers := map[string]string{
+		"Content-Type":   contentType,
+		"Content-Length": strconv.FormatInt(size, 10),
+	}
+	signed, err := s.Presign(http.MethodPut, key, headers, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, headers, nil
+}
+
+// PresignGet signs a download of key
+func (s *S3Store) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.Presign(http.MethodGet, key, nil, ttl)
+}
+
+// Stat returns the object's size and type
+func (s *S3Store) Stat(ctx context.Context, key string) (*uploadservice.ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return &uploadservice.ObjectInfo{
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// Open streams the object's content
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object; deleting a missing object succeeds
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key)
+	if errors.Is(err, uploadservice.ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string) (*http.Response, error) {
+	signed, err := s.Presign(method, key, nil, serverURLTTL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, signed, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s failed: %w", method, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, uploadservice.ErrObjectNotFound
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s returned %d: %s", method, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// Presign returns a SigV4 query-string signed URL for method on key. The
+// headers given must be sent unchanged with the request.
+func (s *S3Store) Presign(method, key string, headers map[string]string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > maxPresignTTL {
+		return "", fmt.Errorf("s3 presigned URLs must be valid for between 1 second and 7 days")
+	}
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", now.Format("20060102"), s.config.Region)
+
+	canonicalHeaders := map[string]string{"host": s.baseURL.Host}
+	for name, value := range headers {
+		canonicalHeaders[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names := make([]string, 0, len(canonicalHeaders))
+	for name := range canonicalHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var headerBlock strings.Builder
+	for _, name := range names {
+		headerBlock.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.config.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	canonicalQuery := canonicalQueryString(query)
+
+	objectPath := s.baseURL.Path
+	if s.config.PathStyle {
+		objectPath += "/" + s.config.Bucket
+	}
+	objectPath += "/" + strings.TrimLeft(key, "/")
+	canonicalURI := uriEncode(objectPath, false)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		headerBlock.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), now.Format("20060102"))
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return fmt.Sprintf("%s://%s%s?%s&X-Amz-Signature=%s", s.baseURL.Scheme, s.baseURL.Host, canonicalURI, canonicalQuery, signature), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQueryString sorts parameters by name and encodes them as SigV4
+// requires
+func canonicalQueryString(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and the
+// slash unless encodeSlash is set, with upper-case hex as SigV4 requires
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,223 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+const (
+	defaultBigQueryBaseURL  = "https://bigquery.googleapis.com/bigquery/v2"
+	defaultGoogleTokenURL   = "https://oauth2.googleapis.com/token"
+	bigQueryInsertDataScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+)
+
+// BigQueryConfig locates the events table and the service account that
+// writes to it
+type BigQueryConfig struct {
+	ProjectID string
+	Dataset   string
+	Table     string
+	// CredentialsJSON is a service account key file's contents
+	CredentialsJSON string
+	BaseURL         string
+	Timeout         time.Duration
+}
+
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// BigQuerySink streams events into a BigQuery table with insertAll. Each row
+// carries the event ID as its insert ID, which BigQuery uses to drop rows
+// retried within its deduplication window; backfilled duplicates outside it
+// are removed by the data team's models on event_id.
+type BigQuerySink struct {
+	config     BigQueryConfig
+	key        serviceAccountKey
+	signer     *rsa.PrivateKey
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewBigQuerySink creates a BigQuery sink from a service account key
+func NewBigQuerySink(config BigQueryConfig) (*BigQuerySink, error) {
+	if config.ProjectID == "" || config.Dataset == "" || config.Table == "" {
+		return nil, fmt.Errorf("bigquery project, dataset and table are required")
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal([]byte(config.CredentialsJSON), &key); err != nil {
+		return nil, fmt.Errorf("invalid bigquery service account key: %w", err)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("bigquery service account key has no client_email or private_key")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = defaultGoogleTokenURL
+	}
+	signer, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bigquery service account private key: %w", err)
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultBigQueryBaseURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &BigQuerySink{
+		config:     config,
+		key:        key,
+		signer:     signer,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Name identifies the warehouse in export status
+func (b *BigQuerySink) Name() string {
+	return "bigquery"
+}
+
+type bqInsertRow struct {
+	InsertID string                 `json:"insertId"`
+	JSON     map[string]interface{} `json:"json"`
+}
+
+type bqInsertAllRequest struct {
+	SkipInvalidRows     bool          `json:"skipInvalidRows"`
+	IgnoreUnknownValues bool          `json:"ignoreUnknownValues"`
+	Rows                []bqInsertRow `json:"rows"`
+}
+
+type bqInsertAllResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+// Write inserts the batch. A row BigQuery rejects fails the whole batch so
+// the cursor does not move past it.
+func (b *BigQuerySink) Write(ctx context.Context, events []*entities.WarehouseEvent) error {
+	req := bqInsertAllRequest{Rows: make([]bqInsertRow, 0, len(events))}
+	for _, event := range events {
+		row, err := eventRow(event)
+		if err != nil {
+			return err
+		}
+		req.Rows = append(req.Rows, bqInsertRow{InsertID: event.ID, JSON: row})
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode bigquery rows: %w", err)
+	}
+
+	token, err := b.token(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/datasets/%s/tables/%s/insertAll", b.config.BaseURL,
+		url.PathEscape(b.config.ProjectID), url.PathEscape(b.config.Dataset), url.PathEscape(b.config.Table))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build bigquery request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("bigquery insert failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("bigquery insert failed with status %d: %s", resp.StatusCode, truncate(respBody))
+	}
+
+	var result bqInsertAllResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to decode bigquery response: %w", err)
+	}
+	if len(result.InsertErrors) > 0 {
+		first := result.InsertErrors[0]
+		reason := "unknown"
+		if len(first.Errors) > 0 {
+			reason = first.Errors[0].Reason + ": " + first.Errors[0].Message
+		}
+		return fmt.Errorf("bigquery rejected %d rows, first %s (%s)", len(result.InsertErrors), events[first.Index].ID, reason)
+	}
+	return nil
+}
+
+// token returns a cached access token, exchanging a signed service account
+// assertion for a new one when it is about to expire
+func (b *BigQuerySink) token(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.accessToken != "" && time.Now().Before(b.expiresAt.Add(-time.Minute)) {
+		return b.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   b.key.ClientEmail,
+		"scope": bigQueryInsertDataScope,
+		"aud":   b.key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(b.signer)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign bigquery token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("bigquery token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("bigquery token request failed with status %d: %s", resp.StatusCode, truncate(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("bigquery token response had no access token")
+	}
+	b.accessToken = token.AccessToken
+	b.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return b.accessToken, nil
+}
@@ -0,0 +1,39 @@
+package warehouse
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// eventRow flattens an event into the columns every sink's events table
+// has: event_id, event_type, schema_version, user_id, occurred_at and
+// properties as a JSON document
+func eventRow(event *entities.WarehouseEvent) (map[string]interface{}, error) {
+	properties, err := json.Marshal(event.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode properties of event %s: %w", event.ID, err)
+	}
+	row := map[string]interface{}{
+		"event_id":       event.ID,
+		"event_type":     event.Type,
+		"schema_version": event.SchemaVersion,
+		"occurred_at":    event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		"properties":     string(properties),
+	}
+	if event.UserID != nil {
+		row["user_id"] = event.UserID.String()
+	}
+	return row, nil
+}
+
+// truncate shortens a response body for an error message
+func truncate(body []byte) string {
+	const max = 500
+	if len(body) > max {
+		return string(body[:max]) + "..."
+	}
+	return string(body)
+}
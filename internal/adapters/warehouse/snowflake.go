@@ -0,0 +1,232 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// snowflakeIdentifier matches a plain or database.schema.table name, which is
+// interpolated into the statement
+var snowflakeIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*){0,2}$`)
+
+// SnowflakeConfig locates the events table and the key-pair user that
+// writes to it
+type SnowflakeConfig struct {
+	Account    string // Account identifier, e.g. myorg-myaccount
+	User       string
+	PrivateKey string // PEM; its public key must be set on the user
+	Warehouse  string
+	Database   string
+	Schema     string
+	Role       string
+	Table      string
+	BaseURL    string
+	Timeout    time.Duration
+}
+
+// SnowflakeSink writes events through the Snowflake SQL API. Each batch is a
+// MERGE on event_id, so events exported again during a backfill are not
+// stored twice.
+type SnowflakeSink struct {
+	config     SnowflakeConfig
+	key        *rsa.PrivateKey
+	issuer     string
+	subject    string
+	httpClient *http.Client
+}
+
+// NewSnowflakeSink creates a Snowflake sink using key-pair authentication
+func NewSnowflakeSink(config SnowflakeConfig) (*SnowflakeSink, error) {
+	if config.Account == "" || config.User == "" || config.Table == "" {
+		return nil, fmt.Errorf("snowflake account, user and table are required")
+	}
+	if !snowflakeIdentifier.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid snowflake table name %q", config.Table)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(config.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid snowflake private key: %w", err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snowflake public key: %w", err)
+	}
+	fingerprint := sha256.Sum256(publicKey)
+
+	// The JWT names the account without its region or cloud suffix
+	account := strings.ToUpper(strings.SplitN(config.Account, ".", 2)[0])
+	subject := account + "." + strings.ToUpper(config.User)
+
+	if config.BaseURL == "" {
+		config.BaseURL = fmt.Sprintf("https://%s.snowflakecomputing.com", strings.ToLower(config.Account))
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	return &SnowflakeSink{
+		config:     config,
+		key:        key,
+		issuer:     subject + ".SHA256:" + base64.StdEncoding.EncodeToString(fingerprint[:]),
+		subject:    subject,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Name identifies the warehouse in export status
+func (s *SnowflakeSink) Name() string {
+	return "snowflake"
+}
+
+type sfBinding struct {
+	Type  string  `json:"type"`
+	Value *string `json:"value"`
+}
+
+type sfStatementRequest struct {
+	Statement string               `json:"statement"`
+	Timeout   int                  `json:"timeout"`
+	Warehouse string               `json:"warehouse,omitempty"`
+	Database  string               `json:"database,omitempty"`
+	Schema    string               `json:"schema,omitempty"`
+	Role      string               `json:"role,omitempty"`
+	Bindings  map[string]sfBinding `json:"bindings"`
+}
+
+type sfStatementResponse struct {
+	Code            string `json:"code"`
+	Message         string `json:"message"`
+	StatementHandle string `json:"statementHandle"`
+}
+
+var snowflakeColumns = []string{"event_id", "event_type", "schema_version", "user_id", "occurred_at", "properties"}
+
+// Write merges the batch into the events table
+func (s *SnowflakeSink) Write(ctx context.Context, events []*entities.WarehouseEvent) error {
+	bindings := make(map[string]sfBinding, len(events)*len(snowflakeColumns))
+	values := make([]string, 0, len(events))
+	for i, event := range events {
+		row, err := eventRow(event)
+		if err != nil {
+			return err
+		}
+		placeholders := make([]string, len(snowflakeColumns))
+		for j, column := range snowflakeColumns {
+			placeholders[j] = "?"
+			binding := sfBinding{Type: "TEXT"}
+			if value, ok := row[column]; ok {
+				text := fmt.Sprint(value)
+				binding.Value = &text
+			}
+			bindings[strconv.Itoa(i*len(snowflakeColumns)+j+1)] = binding
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	statement := fmt.Sprintf(`MERGE INTO %s t
+USING (SELECT column1 AS event_id, column2 AS event_type, column3::INTEGER AS schema_version,
+	column4 AS user_id, TO_TIMESTAMP_TZ(column5) AS occurred_at, PARSE_JSON(column6) AS properties
+	FROM VALUES %s) s
+ON t.event_id = s.event_id
+WHEN NOT MATCHED THEN INSERT (event_id, event_type, schema_version, user_id, occurred_at, properties)
+VALUES (s.event_id, s.event_type, s.schema_version, s.user_id, s.occurred_at, s.properties)`,
+		s.config.Table, strings.Join(values, ", "))
+
+	return s.execute(ctx, sfStatementRequest{
+		Statement: statement,
+		Timeout:   int(s.config.Timeout / time.Second),
+		Warehouse: s.config.Warehouse,
+		Database:  s.config.Database,
+		Schema:    s.config.Schema,
+		Role:      s.config.Role,
+		Bindings:  bindings,
+	})
+}
+
+// execute runs a statement, waiting for it when Snowflake answers that it is
+// still running
+func (s *SnowflakeSink) execute(ctx context.Context, statement sfStatementRequest) error {
+	body, err := json.Marshal(statement)
+	if err != nil {
+		return fmt.Errorf("failed to encode snowflake statement: %w", err)
+	}
+	status, resp, err := s.do(ctx, http.MethodPost, "/api/v2/statements", body)
+	for err == nil && status == http.StatusAccepted {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		status, resp, err = s.do(ctx, http.MethodGet, "/api/v2/statements/"+resp.StatementHandle, nil)
+	}
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("snowflake statement failed with status %d (code %s): %s", status, resp.Code, resp.Message)
+	}
+	return nil
+}
+
+func (s *SnowflakeSink) do(ctx context.Context, method, path string, body []byte) (int, *sfStatementResponse, error) {
+	token, err := s.token()
+	if err != nil {
+		return 0, nil, err
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.config.BaseURL+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build snowflake request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Snowflake-Authorization-Token-Type", "KEYPAIR_JWT")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	httpResp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("snowflake request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+
+	var resp sfStatementResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return httpResp.StatusCode, nil, fmt.Errorf("snowflake returned status %d: %s", httpResp.StatusCode, truncate(respBody))
+	}
+	return httpResp.StatusCode, &resp, nil
+}
+
+// token signs a short-lived key-pair JWT. Snowflake accepts them for up to
+// an hour; a fresh one per request avoids tracking expiry.
+func (s *SnowflakeSink) token() (string, error) {
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": s.issuer,
+		"sub": s.subject,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign snowflake token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,319 @@
+// Package apidocs compares the routes registered with gin against the
+// @Router annotations on the handlers that generate the OpenAPI spec.
+// Both sides are read from source, so the check runs without a database
+// or a running server.
+package apidocs
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ServedRouteFuncs are the route setup functions that build the
+// public router
+var ServedRouteFuncs = []string{"SetupRoutes", "SetupSecurityRoutes"}
+
+// IgnoredPrefixes are served paths that are not part of the API contract
+var IgnoredPrefixes = []string{"/swagger/"}
+
+//go:embed undocumented_routes.txt
+var baseline string
+
+// Route is an HTTP method and a path in OpenAPI form (/users/{id})
+type Route struct {
+	Method string
+	Path   string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Report is the result of comparing registered routes with annotations
+type Report struct {
+	// Undocumented are served routes with no annotation and no baseline entry
+	Undocumented []Route
+	// Stale are baseline entries that are now documented or no longer served
+	Stale []Route
+}
+
+// OK reports whether the spec covers every served route
+func (r *Report) OK() bool {
+	return len(r.Undocumented) == 0 && len(r.Stale) == 0
+}
+
+// Check compares the routes registered in routesDir with the annotations
+// in handlersDir, allowing the routes listed in the baseline
+func Check(routesDir, handlersDir string) (*Report, error) {
+	registered, err := RegisteredRoutes(routesDir, ServedRouteFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	documented, err := DocumentedRoutes(handlersDir)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(registered, documented, Baseline()), nil
+}
+
+// Compare reports registered routes missing from documented and the
+// allowed list, and allowed entries that no longer need to be there
+func Compare(registered, documented, allowed []Route) *Report {
+	docSet := toSet(documented)
+	regSet := toSet(registered)
+	allowSet := toSet(allowed)
+
+	report := &Report{}
+	for route := range regSet {
+		if !docSet[route] && !allowSet[route] {
+			report.Undocumented = append(report.Undocumented, route)
+		}
+	}
+	for route := range allowSet {
+		if docSet[route] || !regSet[route] {
+			report.Stale = append(report.Stale, route)
+		}
+	}
+	sortRoutes(report.Undocumented)
+	sortRoutes(report.Stale)
+	return report
+}
+
+// Baseline returns the routes that were served without annotations when
+// the check was introduced. Entries are removed as handlers are documented.
+func Baseline() []Route {
+	var routes []Route
+	scanner := bufio.NewScanner(strings.NewReader(baseline))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		routes = append(routes, Route{Method: strings.ToUpper(fields[0]), Path: fields[1]})
+	}
+	return routes
+}
+
+// RegisteredRoutes parses the Go files in dir and returns the routes
+// registered inside the named functions. Route groups are followed through
+// `x := y.Group("/prefix")` assignments; paths that are not string literals
+// are skipped.
+func RegisteredRoutes(dir string, funcs ...string) ([]Route, error) {
+	files, err := parseDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(funcs))
+	for _, name := range funcs {
+		wanted[name] = true
+	}
+
+	var routes []Route
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || !wanted[fn.Name.Name] {
+				continue
+			}
+			routes = append(routes, routesInFunc(fn)...)
+		}
+	}
+	sortRoutes(routes)
+	return routes, nil
+}
+
+func routesInFunc(fn *ast.FuncDecl) []Route {
+	prefixes := map[string]string{}
+	var routes []Route
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			name, ok := node.Lhs[0].(*ast.Ident)
+			if !ok {
+				return true
+			}
+			receiver, method, args := selectorCall(node.Rhs[0])
+			if method != "Group" || len(args) == 0 {
+				return true
+			}
+			if segment, ok := stringLit(args[0]); ok {
+				prefixes[name.Name] = joinPath(prefixes[receiver], segment)
+			}
+		case *ast.CallExpr:
+			receiver, method, args := selectorCall(node)
+			switch method {
+			case "GET", "POST", "PUT", "PATCH", "DELETE":
+			default:
+				return true
+			}
+			if len(args) == 0 {
+				return true
+			}
+			segment, ok := stringLit(args[0])
+			if !ok {
+				return true
+			}
+			path := joinPath(prefixes[receiver], segment)
+			if ignored(path) {
+				return true
+			}
+			routes = append(routes, Route{Method: method, Path: openAPIPath(path)})
+		}
+		return true
+	})
+	return routes
+}
+
+// DocumentedRoutes returns the @Router annotations in the Go files in dir
+func DocumentedRoutes(dir string) ([]Route, error) {
+	files, err := parseDir(dir, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []Route
+	for _, file := range files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				if route, ok := parseRouterAnnotation(comment.Text); ok {
+					routes = append(routes, route)
+				}
+			}
+		}
+	}
+	sortRoutes(routes)
+	return routes, nil
+}
+
+// parseRouterAnnotation reads `// @Router /path [method]`
+func parseRouterAnnotation(text string) (Route, bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	if !strings.HasPrefix(text, "@Router") {
+		return Route{}, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(text, "@Router"))
+	if len(fields) < 2 {
+		return Route{}, false
+	}
+	method := strings.ToUpper(strings.Trim(fields[1], "[]"))
+	return Route{Method: method, Path: openAPIPath(fields[0])}, true
+}
+
+func parseDir(dir string, mode parser.Mode) ([]*ast.File, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, path, src, mode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// selectorCall splits `recv.Method(args...)` where recv is an identifier
+func selectorCall(expr ast.Expr) (string, string, []ast.Expr) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return "", "", nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", nil
+	}
+	receiver := ""
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		receiver = ident.Name
+	}
+	return receiver, sel.Sel.Name, call.Args
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+func joinPath(prefix, segment string) string {
+	joined := strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(segment, "/")
+	if joined != "/" {
+		joined = strings.TrimRight(joined, "/")
+	}
+	return joined
+}
+
+// openAPIPath rewrites gin parameters (:id, *path) to OpenAPI form ({id})
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func ignored(path string) bool {
+	for _, prefix := range IgnoredPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func toSet(routes []Route) map[Route]bool {
+	set := make(map[Route]bool, len(routes))
+	for _, route := range routes {
+		set[route] = true
+	}
+	return set
+}
+
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/accounting"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// AccountingHandlers let finance admins preview daily journals and manage
+// their export to the general ledger
+type AccountingHandlers struct {
+	service      *accounting.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewAccountingHandlers creates a new accounting handlers instance
+func NewAccountingHandlers(service *accounting.Service, auditService *adapters.AuditService, logger *zap.Logger) *AccountingHandlers {
+	return &AccountingHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// AccountingExportListResponse lists journal export records
+type AccountingExportListResponse struct {
+	Exports []*entities.AccountingExport `json:"exports"`
+}
+
+// GetJournal handles GET /api/v1/admin/accounting/journal
+// @Summary Preview a day's journal
+// @Description Builds the summary journal entry for a business day from the ledger using the configured account mapping, without exporting it
+// @Tags admin
+// @Produce json
+// @Param date query string true "Business day (YYYY-MM-DD)"
+// @Success 200 {object} entities.AccountingJournal
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 422 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/accounting/journal [get]
+func (h *AccountingHandlers) GetJournal(c *gin.Context) {
+	day, err := h.service.ParseDay(c.Query("date"))
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	journal, err := h.service.Journal(c.Request.Context(), day)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to build journal")
+		return
+	}
+	c.JSON(http.StatusOK, journal)
+}
+
+// ListExports handles GET /api/v1/admin/accounting/exports
+// @Summary List journal exports
+// @Description Shows, per business day, whether the journal reached the accounting system
+// @Tags admin
+// @Produce json
+// @Param from query string true "First day (YYYY-MM-DD)"
+// @Param to query string true "Last day (YYYY-MM-DD)"
+// @Success 200 {object} handlers.AccountingExportListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/accounting/exports [get]
+func (h *AccountingHandlers) ListExports(c *gin.Context) {
+	from, err := h.service.ParseDay(c.Query("from"))
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	to, err := h.service.ParseDay(c.Query("to"))
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	exports, err := h.service.ListExports(c.Request.Context(), from, to)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list accounting exports")
+		return
+	}
+	c.JSON(http.StatusOK, AccountingExportListResponse{Exports: exports})
+}
+
+// Reexport handles POST /api/v1/admin/accounting/exports/reexport
+// @Summary Re-export a date range
+// @Description Exports each day in the range again, replacing the journal the accounting system holds for it. Days another export is working on are listed under in_progress.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.ReexportAccountingRequest true "Date range"
+// @Success 200 {object} accounting.ReexportResult
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/accounting/exports/reexport [post]
+func (h *AccountingHandlers) Reexport(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.ReexportAccountingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	from, err := h.service.ParseDay(req.From)
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	to, err := h.service.ParseDay(req.To)
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	result, err := h.service.Reexport(c.Request.Context(), from, to)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to re-export journals")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "reexport_accounting_journals", "accounting_export", nil, map[string]interface{}{
+		"from":        req.From,
+		"to":          req.To,
+		"reason":      req.Reason,
+		"in_progress": result.InProgress,
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AccountingHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrInvalidAccountingDateRange):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrUnmappedLedgerAccount):
+		respondError(c, http.StatusUnprocessableEntity, "UNMAPPED_ACCOUNT", err.Error(), nil)
+	case errors.Is(err, entities.ErrAccountingExportUnavailable):
+		respondError(c, http.StatusServiceUnavailable, "EXPORT_UNAVAILABLE", "Accounting export is not configured", nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/cases"
+	"github.com/stack-service/stack_service/internal/domain/services/inactivity"
+	"go.uber.org/zap"
+)
+
+// AdminCaseHandlers exposes the admin review queue and the dormant account monitor
+type AdminCaseHandlers struct {
+	caseService       *cases.Service
+	inactivityService *inactivity.Service
+	logger            *zap.Logger
+}
+
+// NewAdminCaseHandlers creates a new admin case handlers instance
+func NewAdminCaseHandlers(caseService *cases.Service, inactivityService *inactivity.Service, logger *zap.Logger) *AdminCaseHandlers {
+	return &AdminCaseHandlers{
+		caseService:       caseService,
+		inactivityService: inactivityService,
+		logger:            logger,
+	}
+}
+
+// ListCases handles GET /api/v1/admin/cases
+// @Summary List admin cases
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status (open, in_review, resolved)"
+// @Param type query string false "Filter by case type"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.AdminCaseListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cases [get]
+func (h *AdminCaseHandlers) ListCases(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.caseService.List(c.Request.Context(),
+		entities.AdminCaseStatus(c.Query("status")),
+		entities.AdminCaseType(c.Query("type")),
+		limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list admin cases", zap.Error(err))
+		respondInternalError(c, "Failed to list cases")
+		return
+	}
+	c.JSON(http.StatusOK, AdminCaseListResponse{Cases: list})
+}
+
+// GetCase handles GET /api/v1/admin/cases/:id
+// @Summary Get an admin case
+// @Tags admin
+// @Produce json
+// @Param id path string true "Case ID"
+// @Success 200 {object} entities.AdminCase
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cases/{id} [get]
+func (h *AdminCaseHandlers) GetCase(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid case ID", nil)
+		return
+	}
+
+	adminCase, err := h.caseService.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, entities.ErrAdminCaseNotFound) {
+			respondNotFound(c, "Case not found")
+			return
+		}
+		h.logger.Error("Failed to get admin case", zap.Error(err))
+		respondInternalError(c, "Failed to get case")
+		return
+	}
+	c.JSON(http.StatusOK, adminCase)
+}
+
+// UpdateCase handles PATCH /api/v1/admin/cases/:id
+// @Summary Update an admin case
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Case ID"
+// @Param request body entities.UpdateAdminCaseRequest true "Case update"
+// @Success 200 {object} entities.AdminCase
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cases/{id} [patch]
+func (h *AdminCaseHandlers) UpdateCase(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid case ID", nil)
+		return
+	}
+
+	var req entities.UpdateAdminCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	adminCase, err := h.caseService.Update(c.Request.Context(), id, &req, adminID)
+	if err != nil {
+		if errors.Is(err, entities.ErrAdminCaseNotFound) {
+			respondNotFound(c, "Case not found")
+			return
+		}
+		h.logger.Error("Failed to update admin case", zap.Error(err))
+		respondInternalError(c, "Failed to update case")
+		return
+	}
+	c.JSON(http.StatusOK, adminCase)
+}
+
+// RunInactivityScan handles POST /api/v1/admin/inactivity/scan
+// @Summary Run the dormant account monitor
+// @Description Defaults to a dry run that reports reminders and cases without sending or opening them.
+// @Tags admin
+// @Produce json
+// @Param dry_run query bool false "Report without acting (default true)"
+// @Success 200 {object} inactivity.Report
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/inactivity/scan [post]
+func (h *AdminCaseHandlers) RunInactivityScan(c *gin.Context) {
+	dryRun := true
+	if raw := c.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondBadRequest(c, "dry_run must be a boolean", nil)
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.inactivityService.Run(c.Request.Context(), dryRun)
+	if err != nil {
+		if errors.Is(err, inactivity.ErrScanInProgress) {
+			respondError(c, http.StatusConflict, "SCAN_IN_PROGRESS", err.Error(), nil)
+			return
+		}
+		h.logger.Error("Inactivity scan failed", zap.Error(err))
+		respondInternalError(c, "Inactivity scan failed")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+	"go.uber.org/zap"
+)
+
+// AIArtifactHandlers lets users see and download the AI summaries and
+// analyses stored about them
+type AIArtifactHandlers struct {
+	service *aiartifacts.Service
+	logger  *zap.Logger
+}
+
+// NewAIArtifactHandlers creates a new AI artifact handlers instance
+func NewAIArtifactHandlers(service *aiartifacts.Service, logger *zap.Logger) *AIArtifactHandlers {
+	return &AIArtifactHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListArtifacts handles GET /api/v1/aicfo/artifacts
+// @Summary List stored AI artifacts
+// @Description Returns the AI-generated summaries stored about the user, newest first, with short-lived signed download links.
+// @Tags aicfo
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.AIArtifactListResponse
+// @Security BearerAuth
+// @Router /api/v1/aicfo/artifacts [get]
+func (h *AIArtifactHandlers) ListArtifacts(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	artifacts, err := h.service.List(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list AI artifacts", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to list AI artifacts")
+		return
+	}
+	c.JSON(http.StatusOK, AIArtifactListResponse{Artifacts: artifacts})
+}
+
+// DownloadArtifact handles GET /api/v1/aicfo/artifacts/download
+// @Summary Download an AI artifact
+// @Description Serves an artifact through a signed link from the artifact list. The token authorizes the download, so no bearer token is needed.
+// @Tags aicfo
+// @Produce text/markdown
+// @Param token query string true "Signed download token"
+// @Success 200 {string} string
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Router /api/v1/aicfo/artifacts/download [get]
+func (h *AIArtifactHandlers) DownloadArtifact(c *gin.Context) {
+	content, err := h.service.Open(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, aiartifacts.ErrInvalidLink), errors.Is(err, aiartifacts.ErrLinkExpired):
+			respondError(c, http.StatusForbidden, "INVALID_LINK", err.Error(), nil)
+		case errors.Is(err, aiartifacts.ErrArtifactNotFound):
+			respondNotFound(c, "Artifact not found")
+		default:
+			h.logger.Error("Failed to download AI artifact", zap.Error(err))
+			respondInternalError(c, "Failed to download AI artifact")
+		}
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.md", content.Type, content.WeekStart.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, content.ContentType+"; charset=utf-8", content.Body)
+}
+
+// ExportArtifacts handles GET /api/v1/aicfo/artifacts/export
+// @Summary Export all AI artifacts
+// @Description Downloads every AI artifact stored about the user with its content, as the AI section of the personal data export.
+// @Tags aicfo
+// @Produce json
+// @Success 200 {object} aiartifacts.Export
+// @Security BearerAuth
+// @Router /api/v1/aicfo/artifacts/export [get]
+func (h *AIArtifactHandlers) ExportArtifacts(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	export, err := h.service.Export(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to export AI artifacts", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to export AI artifacts")
+		return
+	}
+
+	filename := fmt.Sprintf("ai-artifacts-%s.json", export.GeneratedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, export)
+}
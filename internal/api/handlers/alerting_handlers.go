@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/alerting"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// AlertingHandlers let admins see which alerts reached on-call channels and
+// check routing with a test alert
+type AlertingHandlers struct {
+	service      *alerting.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewAlertingHandlers creates new alert routing handlers
+func NewAlertingHandlers(service *alerting.Service, auditService *adapters.AuditService, logger *zap.Logger) *AlertingHandlers {
+	return &AlertingHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListAlertDeliveries handles GET /api/v1/admin/alerts/deliveries
+// @Summary List alert deliveries
+// @Description Alerts sent to PagerDuty, Slack or the fallback webhook, newest first, with whether the channel confirmed them
+// @Tags admin
+// @Produce json
+// @Param status query string false "delivered or failed"
+// @Param channel query string false "pagerduty, slack or webhook"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.AlertDeliveryListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/alerts/deliveries [get]
+func (h *AlertingHandlers) ListAlertDeliveries(c *gin.Context) {
+	status := entities.AlertDeliveryStatus(c.Query("status"))
+	channel := entities.AlertChannel(c.Query("channel"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), status, channel, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list alert deliveries", zap.Error(err))
+		respondInternalError(c, "Failed to list alert deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*entities.AlertDelivery{}
+	}
+	c.JSON(http.StatusOK, AlertDeliveryListResponse{Deliveries: deliveries})
+}
+
+// SendTestAlert handles POST /api/v1/admin/alerts/test
+// @Summary Send a test alert
+// @Description Routes a test alert of the given severity as if it were raised now, so the channels it reaches and their confirmations can be checked. A critical test alert pages whoever is on call.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.SendTestAlertRequest true "Severity and optional summary"
+// @Success 200 {object} handlers.AlertDeliveryListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/alerts/test [post]
+func (h *AlertingHandlers) SendTestAlert(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.SendTestAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	summary := req.Summary
+	if summary == "" {
+		summary = "Test alert"
+	}
+
+	// A fresh key per test, so earlier tests do not suppress it
+	deliveries, err := h.service.Notify(c.Request.Context(), &entities.Alert{
+		Source:   "admin_test",
+		Severity: req.Severity,
+		DedupKey: "admin_test:" + uuid.New().String(),
+		Summary:  summary,
+		Details:  map[string]string{"requested_by": adminID.String()},
+	})
+	if err != nil {
+		if errors.Is(err, alerting.ErrUnknownSeverity) {
+			respondBadRequest(c, "severity must be critical, warning or info", nil)
+			return
+		}
+		h.logger.Error("Failed to send test alert", zap.Error(err))
+		respondInternalError(c, "Failed to send test alert")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*entities.AlertDelivery{}
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "send_test_alert", "alert", nil, map[string]interface{}{
+		"severity":   string(req.Severity),
+		"deliveries": len(deliveries),
+	})
+	c.JSON(http.StatusOK, AlertDeliveryListResponse{Deliveries: deliveries})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/apiusage"
+	"go.uber.org/zap"
+)
+
+// APIUsageHandlers expose request analytics per user and endpoint
+type APIUsageHandlers struct {
+	service *apiusage.Service
+	logger  *zap.Logger
+}
+
+// NewAPIUsageHandlers creates a new API usage handlers instance
+func NewAPIUsageHandlers(service *apiusage.Service, logger *zap.Logger) *APIUsageHandlers {
+	return &APIUsageHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetAPIUsage handles GET /api/v1/admin/analytics/api-usage
+// @Summary Get API usage analytics
+// @Description Returns request counts, error rates and p95 latency per endpoint, and per user unless grouped by endpoint, busiest first. Usage is rolled up hourly and written about once a minute.
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Filter by user ID"
+// @Param route query string false "Filter by registered route, e.g. /api/v1/portfolio/overview"
+// @Param since query string false "Start of the window, RFC 3339 (default 24 hours before until)"
+// @Param until query string false "End of the window, RFC 3339 (default now)"
+// @Param group_by query string false "user_endpoint (default) or endpoint"
+// @Param limit query int false "Maximum rows (default 50, max 200)"
+// @Success 200 {object} entities.APIUsageReport
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/analytics/api-usage [get]
+func (h *APIUsageHandlers) GetAPIUsage(c *gin.Context) {
+	filter := entities.APIUsageFilter{
+		Route:   c.Query("route"),
+		GroupBy: entities.APIUsageGrouping(c.Query("group_by")),
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid user ID", nil)
+			return
+		}
+		filter.UserID = &userID
+	}
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid "+param+" time, expected RFC 3339", nil)
+			return
+		}
+		*target = parsed
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	report, err := h.service.Report(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidAPIUsageFilter) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to get API usage", zap.Error(err))
+		respondInternalError(c, "Failed to get API usage")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
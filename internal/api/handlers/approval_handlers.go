@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/approvals"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// ApprovalHandlers expose the maker-checker approval queue and the admin
+// actions that go through it
+type ApprovalHandlers struct {
+	service        *approvals.Service
+	basketDeletion *investing.BasketDeletion
+	auditService   *adapters.AuditService
+	logger         *zap.Logger
+}
+
+// NewApprovalHandlers creates a new approval handlers instance
+func NewApprovalHandlers(service *approvals.Service, basketDeletion *investing.BasketDeletion, auditService *adapters.AuditService, logger *zap.Logger) *ApprovalHandlers {
+	return &ApprovalHandlers{
+		service:        service,
+		basketDeletion: basketDeletion,
+		auditService:   auditService,
+		logger:         logger,
+	}
+}
+
+// ApprovalRequestListResponse is a page of approval requests
+type ApprovalRequestListResponse struct {
+	Requests []*entities.ApprovalRequest `json:"requests"`
+}
+
+// ListApprovals handles GET /api/v1/admin/approvals
+// @Summary List approval requests
+// @Description Oldest first, so the pending queue is worked in order
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status (pending, approved, rejected, failed)"
+// @Param action query string false "Filter by action (withdrawal, basket_delete, ledger_adjustment)"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.ApprovalRequestListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/approvals [get]
+func (h *ApprovalHandlers) ListApprovals(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.service.List(c.Request.Context(),
+		entities.ApprovalStatus(c.Query("status")), entities.ApprovalAction(c.Query("action")), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list approval requests", zap.Error(err))
+		respondInternalError(c, "Failed to list approval requests")
+		return
+	}
+	c.JSON(http.StatusOK, ApprovalRequestListResponse{Requests: list})
+}
+
+// GetApproval handles GET /api/v1/admin/approvals/:id
+// @Summary Get an approval request with its decisions
+// @Tags admin
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Success 200 {object} entities.ApprovalRequest
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/approvals/{id} [get]
+func (h *ApprovalHandlers) GetApproval(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid approval request ID", nil)
+		return
+	}
+
+	request, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondReviewError(c, err, "Failed to get approval request")
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveRequest handles POST /api/v1/admin/approvals/:id/approve
+// @Summary Approve a pending request
+// @Description Records the admin's approval. The action is carried out once the request has the approvals its policy requires; if it then fails the request is marked failed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Param request body entities.ReviewApprovalRequest false "Review notes"
+// @Success 200 {object} entities.ApprovalRequest
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/approvals/{id}/approve [post]
+func (h *ApprovalHandlers) ApproveRequest(c *gin.Context) {
+	h.review(c, h.service.Approve, "approval_approve", "Failed to approve request")
+}
+
+// RejectRequest handles POST /api/v1/admin/approvals/:id/reject
+// @Summary Reject a pending request
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval request ID"
+// @Param request body entities.ReviewApprovalRequest false "Review notes"
+// @Success 200 {object} entities.ApprovalRequest
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/approvals/{id}/reject [post]
+func (h *ApprovalHandlers) RejectRequest(c *gin.Context) {
+	h.review(c, h.service.Reject, "approval_reject", "Failed to reject request")
+}
+
+// RequestBasketDeletion handles DELETE /api/v1/admin/baskets/:id
+// @Summary Request deletion of a basket
+// @Description Queues the deletion for approval by other admins. Baskets with orders or open positions cannot be deleted.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Basket ID"
+// @Success 202 {object} entities.ApprovalRequest
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/baskets/{id} [delete]
+func (h *ApprovalHandlers) RequestBasketDeletion(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	basketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid basket ID", nil)
+		return
+	}
+
+	basket, err := h.basketDeletion.Check(c.Request.Context(), basketID)
+	if err != nil {
+		switch {
+		case errors.Is(err, investing.ErrBasketNotFound):
+			respondNotFound(c, "Basket not found")
+		case errors.Is(err, investing.ErrBasketInUse):
+			respondError(c, http.StatusConflict, "BASKET_IN_USE", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to check basket deletion", zap.String("basket_id", basketID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to request basket deletion")
+		}
+		return
+	}
+
+	request, err := h.service.Submit(c.Request.Context(), &entities.SubmitApprovalRequest{
+		Action:      entities.ApprovalActionBasketDelete,
+		ResourceID:  basket.ID.String(),
+		RequestedBy: &adminID,
+		Summary:     fmt.Sprintf("Delete basket %q", basket.Name),
+		Payload:     map[string]interface{}{"name": basket.Name},
+	})
+	if err != nil {
+		h.logger.Error("Failed to request basket deletion", zap.String("basket_id", basketID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to request basket deletion")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "basket_delete_requested", "basket", nil, map[string]interface{}{
+		"basket_id":  basket.ID.String(),
+		"request_id": request.ID.String(),
+	})
+	c.JSON(http.StatusAccepted, request)
+}
+
+func (h *ApprovalHandlers) review(c *gin.Context, decide func(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.ApprovalRequest, error), action, failure string) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid approval request ID", nil)
+		return
+	}
+
+	var req entities.ReviewApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
+	request, err := decide(c.Request.Context(), id, adminID, req.Notes)
+	if err != nil {
+		h.respondReviewError(c, err, failure)
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, action, "approval_request", nil, map[string]interface{}{
+		"request_id":  request.ID.String(),
+		"action":      string(request.Action),
+		"resource_id": request.ResourceID,
+		"status":      string(request.Status),
+	})
+	c.JSON(http.StatusOK, request)
+}
+
+func (h *ApprovalHandlers) respondReviewError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, entities.ErrApprovalNotFound):
+		respondNotFound(c, "Approval request not found")
+	case errors.Is(err, entities.ErrApprovalNotPending), errors.Is(err, entities.ErrApprovalAlreadyDecided):
+		respondError(c, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+	case errors.Is(err, entities.ErrApprovalSelfReview), errors.Is(err, entities.ErrApprovalNotApprover):
+		respondError(c, http.StatusForbidden, "NOT_ALLOWED", err.Error(), nil)
+	default:
+		h.logger.Error(failure, zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/attribution"
+	"go.uber.org/zap"
+)
+
+// AttributionHandlers explains where a user's portfolio return came from
+type AttributionHandlers struct {
+	attributionService *attribution.Service
+	logger             *zap.Logger
+}
+
+// NewAttributionHandlers creates a new attribution handlers instance
+func NewAttributionHandlers(attributionService *attribution.Service, logger *zap.Logger) *AttributionHandlers {
+	return &AttributionHandlers{
+		attributionService: attributionService,
+		logger:             logger,
+	}
+}
+
+// GetAttribution handles GET /api/v1/portfolio/attribution
+// @Summary Get performance attribution
+// @Description Breaks the user's return over the range down by basket and by each basket's components, with market and selection effects measured against a benchmark and short plain-language highlights.
+// @Tags portfolio
+// @Produce json
+// @Param range query string false "Look-back window: 1W, 1M, 3M, 6M, 1Y or YTD" default(1M)
+// @Success 200 {object} entities.PerformanceAttribution
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/attribution [get]
+func (h *AttributionHandlers) GetAttribution(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	r := entities.AttributionRange(c.DefaultQuery("range", string(entities.AttributionRange1M)))
+	report, err := h.attributionService.Attribute(c.Request.Context(), userID, r)
+	if err != nil {
+		if errors.Is(err, attribution.ErrInvalidRange) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to attribute portfolio performance", zap.Error(err), zap.String("user_id", userID.String()))
+		respondInternalError(c, "Failed to get performance attribution")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// AuditHandlers exposes audit chain verification for compliance evidence
+type AuditHandlers struct {
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewAuditHandlers creates a new audit handlers instance
+func NewAuditHandlers(auditService *adapters.AuditService, logger *zap.Logger) *AuditHandlers {
+	return &AuditHandlers{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// VerifyAuditChain handles GET /api/v1/admin/audit/verify
+// @Summary Verify audit log hash chain
+// @Description Recomputes every audit entry hash and checks recorded anchors to detect retroactive modification
+// @Tags admin
+// @Produce json
+// @Success 200 {object} adapters.AuditChainReport
+// @Failure 409 {object} adapters.AuditChainReport
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/audit/verify [get]
+func (h *AuditHandlers) VerifyAuditChain(c *gin.Context) {
+	report, err := h.auditService.VerifyChain(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to verify audit chain", zap.Error(err))
+		respondInternalError(c, "Failed to verify audit chain")
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Valid {
+		status = http.StatusConflict
+	}
+	c.JSON(status, report)
+}
+
+// AnchorAuditChain handles POST /api/v1/admin/audit/anchor
+// @Summary Anchor the audit chain head
+// @Description Records the current chain head and mirrors it to external storage
+// @Tags admin
+// @Produce json
+// @Success 201 {object} adapters.AuditChainAnchor
+// @Success 204 "Audit chain is empty"
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/audit/anchor [post]
+func (h *AuditHandlers) AnchorAuditChain(c *gin.Context) {
+	anchor, err := h.auditService.AnchorChainHead(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to anchor audit chain", zap.Error(err))
+		respondInternalError(c, "Failed to anchor audit chain")
+		return
+	}
+	if anchor == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusCreated, anchor)
+}
+
+// ListAuditAnchors handles GET /api/v1/admin/audit/anchors
+// @Summary List audit chain anchors
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.AuditAnchorListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/audit/anchors [get]
+func (h *AuditHandlers) ListAuditAnchors(c *gin.Context) {
+	anchors, err := h.auditService.ListChainAnchors(c.Request.Context(), 100)
+	if err != nil {
+		h.logger.Error("Failed to list audit anchors", zap.Error(err))
+		respondInternalError(c, "Failed to list audit anchors")
+		return
+	}
+	c.JSON(http.StatusOK, AuditAnchorListResponse{Anchors: anchors})
+}
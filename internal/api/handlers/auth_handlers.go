@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -15,6 +16,7 @@ import (
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
 	"github.com/stack-service/stack_service/pkg/auth"
 	"github.com/stack-service/stack_service/pkg/crypto"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 	"net/http"
 	"strings"
@@ -33,6 +35,31 @@ type AuthHandlers struct {
 	emailService         *adapters.EmailService
 	kycProvider          *adapters.KYCProvider
 	validator            *validator.Validate
+	cookieSessions       CookieSessionIssuer
+	geoAccess            GeoAccessChecker
+	passwordPolicy       PasswordPolicy
+}
+
+// CookieSessionIssuer sets and clears the session cookies used by browser clients
+type CookieSessionIssuer interface {
+	CookieEnabled() bool
+	BearerEnabled() bool
+	Issue(c *gin.Context, pair *auth.TokenPair) string
+	Clear(c *gin.Context)
+	RefreshToken(c *gin.Context) string
+}
+
+// GeoAccessChecker geolocates signups and logins
+type GeoAccessChecker interface {
+	CheckSignup(ctx context.Context, ip string) (*entities.GeoAccessEvent, error)
+	RecordSignup(ctx context.Context, userID uuid.UUID, event *entities.GeoAccessEvent) error
+	RecordLogin(ctx context.Context, userID uuid.UUID, ip string) (*entities.GeoAccessEvent, error)
+}
+
+// PasswordPolicy checks new passwords and reviews the ones users sign in with
+type PasswordPolicy interface {
+	Check(ctx context.Context, password string, personal ...string) error
+	Review(ctx context.Context, userID uuid.UUID, password string, personal ...string) *entities.PasswordNotice
 }
 
 // NewAuthHandlers creates a new instance of AuthHandlers
@@ -61,6 +88,75 @@ func NewAuthHandlers(
 	}
 }
 
+// SetCookieSessions enables cookie sessions for browser clients
+func (h *AuthHandlers) SetCookieSessions(issuer CookieSessionIssuer) {
+	h.cookieSessions = issuer
+}
+
+// SetGeoAccess enables signup country checks and login VPN flagging
+func (h *AuthHandlers) SetGeoAccess(geoAccess GeoAccessChecker) {
+	h.geoAccess = geoAccess
+}
+
+// SetPasswordPolicy enforces the password policy at signup, change and
+// reset, and prompts users signing in with weak passwords to change them
+func (h *AuthHandlers) SetPasswordPolicy(policy PasswordPolicy) {
+	h.passwordPolicy = policy
+}
+
+// acceptablePassword responds with the rules a new password breaks and
+// returns false when it does not meet the policy
+func (h *AuthHandlers) acceptablePassword(c *gin.Context, password string, personal ...string) bool {
+	if h.passwordPolicy == nil {
+		return true
+	}
+	err := h.passwordPolicy.Check(c.Request.Context(), password, personal...)
+	if err == nil {
+		return true
+	}
+	var policyErr *entities.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+			Code:    "WEAK_PASSWORD",
+			Message: "Password does not meet the password policy",
+			Details: map[string]interface{}{"violations": policyErr.Violations},
+		})
+		return false
+	}
+	h.logger.Error("Failed to check password policy", zap.Error(err))
+	respondInternalError(c, "Failed to check password")
+	return false
+}
+
+// personalInfo is what a user's password may not be built from
+func personalInfo(email string, phone *string) []string {
+	personal := []string{email}
+	if phone != nil {
+		personal = append(personal, *phone)
+	}
+	return personal
+}
+
+// wantsCookieSession reports whether to sign the client in with cookies. Web
+// clients ask with X-Auth-Mode: cookie; once bearer tokens are switched off
+// every client gets cookies.
+func (h *AuthHandlers) wantsCookieSession(c *gin.Context) bool {
+	if h.cookieSessions == nil || !h.cookieSessions.CookieEnabled() {
+		return false
+	}
+	return strings.EqualFold(c.GetHeader("X-Auth-Mode"), "cookie") || !h.cookieSessions.BearerEnabled()
+}
+
+// sessionTokens returns the tokens to put in an auth response. Cookie clients
+// get their tokens as httpOnly cookies and only the session's CSRF token in
+// the body.
+func (h *AuthHandlers) sessionTokens(c *gin.Context, pair *auth.TokenPair) (accessToken, refreshToken, csrfToken string) {
+	if !h.wantsCookieSession(c) {
+		return pair.AccessToken, pair.RefreshToken, ""
+	}
+	return "", "", h.cookieSessions.Issue(c, pair)
+}
+
 // SignUp handles user registration and sends a verification code
 // @Summary Register a new user and send verification code
 // @Description Create a new user account and initiate email/phone verification
@@ -69,7 +165,8 @@ func NewAuthHandlers(
 // @Produce json
 // @Param request body entities.SignUpRequest true "Signup data (email or phone, and password)"
 // @Success 202 {object} entities.SignUpResponse "Verification code sent"
-// @Failure 400 {object} entities.ErrorResponse
+// @Failure 400 {object} entities.ErrorResponse "Invalid request, or WEAK_PASSWORD with the broken password policy rules"
+// @Failure 403 {object} entities.ErrorResponse "Signups are not available from the caller's country"
 // @Failure 409 {object} entities.ErrorResponse
 // @Failure 500 {object} entities.ErrorResponse
 // @Router /api/v1/auth/signup [post]
@@ -114,7 +211,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 		existingUser, err = h.userRepo.GetByEmail(ctx, identifier)
 		if err != nil {
 			if !isUserNotFoundError(err) {
-				h.logger.Error("Failed to check existing user by email", zap.Error(err), zap.String("email", identifier))
+				h.logger.Error("Failed to check existing user by email", zap.Error(err), logger.Email(identifier))
 				c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
 					Code:    "INTERNAL_ERROR",
 					Message: "Internal server error",
@@ -129,7 +226,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 
 		exists, err := h.userRepo.PhoneExists(ctx, identifier)
 		if err != nil {
-			h.logger.Error("Failed to check phone existence", zap.Error(err), zap.String("phone", identifier))
+			h.logger.Error("Failed to check phone existence", zap.Error(err), logger.Phone(identifier))
 			c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
 				Code:    "INTERNAL_ERROR",
 				Message: "Internal server error",
@@ -154,6 +251,9 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 		})
 		return
 	}
+	if !h.acceptablePassword(c, req.Password, identifier) {
+		return
+	}
 
 	if existingUser != nil {
 		if existingUser.EmailVerified {
@@ -165,6 +265,12 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 			return
 		}
 
+		geoEvent, ok := h.checkSignupLocation(c)
+		if !ok {
+			return
+		}
+		h.recordSignupLocation(ctx, existingUser.ID, geoEvent)
+
 		passwordHash, err := crypto.HashPassword(req.Password)
 		if err != nil {
 			h.logger.Error("Failed to hash password for existing unverified user", zap.Error(err), zap.String("identifier", identifier))
@@ -209,6 +315,11 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 		return
 	}
 
+	geoEvent, ok := h.checkSignupLocation(c)
+	if !ok {
+		return
+	}
+
 	email := ""
 	if identifierType == "email" {
 		email = identifier
@@ -230,6 +341,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 	}
 
 	h.bootstrapKYCApplicant(ctx, registeredUser)
+	h.recordSignupLocation(ctx, registeredUser.ID, geoEvent)
 
 	// Send verification code
 	_, err = h.verificationService.GenerateAndSendCode(ctx, identifierType, identifier)
@@ -244,7 +356,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 
 	if registeredUser.Email != "" && h.emailService != nil {
 		if err := h.emailService.SendWelcomeEmail(ctx, registeredUser.Email); err != nil {
-			h.logger.Warn("Failed to send welcome email", zap.Error(err), zap.String("email", registeredUser.Email))
+			h.logger.Warn("Failed to send welcome email", zap.Error(err), logger.Email(registeredUser.Email))
 		}
 	}
 
@@ -255,6 +367,43 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 	})
 }
 
+// checkSignupLocation refuses signups from countries where onboarding is not
+// allowed. It responds and returns false when the signup must stop; the
+// returned event is nil when geo-IP lookups are disabled.
+func (h *AuthHandlers) checkSignupLocation(c *gin.Context) (*entities.GeoAccessEvent, bool) {
+	if h.geoAccess == nil {
+		return nil, true
+	}
+
+	event, err := h.geoAccess.CheckSignup(c.Request.Context(), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, entities.ErrSignupLocationBlocked) {
+			c.JSON(http.StatusForbidden, entities.ErrorResponse{
+				Code:    "JURISDICTION_NOT_SUPPORTED",
+				Message: "We are not able to offer accounts in your country yet",
+				Details: map[string]interface{}{"country": event.CountryCode},
+			})
+			return nil, false
+		}
+		h.logger.Error("Failed to check signup location", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Internal server error",
+		})
+		return nil, false
+	}
+	return event, true
+}
+
+func (h *AuthHandlers) recordSignupLocation(ctx context.Context, userID uuid.UUID, event *entities.GeoAccessEvent) {
+	if event == nil {
+		return
+	}
+	if err := h.geoAccess.RecordSignup(ctx, userID, event); err != nil {
+		h.logger.Warn("Failed to record signup location", zap.Error(err), zap.String("user_id", userID.String()))
+	}
+}
+
 func (h *AuthHandlers) bootstrapKYCApplicant(ctx context.Context, user *entities.User) {
 	if h.kycProvider == nil || user == nil {
 		return
@@ -422,6 +571,7 @@ func (h *AuthHandlers) VerifyCode(c *gin.Context) {
 		return
 	}
 
+	accessToken, refreshToken, csrfToken := h.sessionTokens(c, tokens)
 	h.logger.Info("Account verified and tokens issued", zap.String("user_id", userProfile.ID.String()), zap.String("identifier", identifier))
 	c.JSON(http.StatusOK, entities.VerifyCodeResponse{
 		User: &entities.UserInfo{
@@ -434,9 +584,10 @@ func (h *AuthHandlers) VerifyCode(c *gin.Context) {
 			KYCStatus:        userProfile.KYCStatus,
 			CreatedAt:        userProfile.CreatedAt,
 		},
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 		ExpiresAt:    tokens.ExpiresAt,
+		CSRFToken:    csrfToken,
 	})
 }
 
@@ -601,7 +752,7 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 	// Get user by email
 	user, err := h.userRepo.GetUserByEmailForLogin(ctx, req.Email)
 	if err != nil {
-		h.logger.Warn("Login attempt failed - user not found", zap.String("email", req.Email), zap.Error(err))
+		h.logger.Warn("Login attempt failed - user not found", logger.Email(req.Email), zap.Error(err))
 		c.JSON(http.StatusUnauthorized, entities.ErrorResponse{
 			Code:    "INVALID_CREDENTIALS",
 			Message: "Invalid email or password",
@@ -611,7 +762,7 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 
 	// Validate password
 	if !h.userRepo.ValidatePassword(req.Password, user.PasswordHash) {
-		h.logger.Warn("Login attempt failed - invalid password", zap.String("email", req.Email))
+		h.logger.Warn("Login attempt failed - invalid password", logger.Email(req.Email))
 		c.JSON(http.StatusUnauthorized, entities.ErrorResponse{
 			Code:    "INVALID_CREDENTIALS",
 			Message: "Invalid email or password",
@@ -621,10 +772,11 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 
 	// Check if user is active
 	if !user.IsActive {
-		h.logger.Warn("Login attempt failed - user account inactive", zap.String("email", req.Email))
+		h.logger.Warn("Login attempt failed - user account inactive", logger.Email(req.Email))
 		c.JSON(http.StatusUnauthorized, entities.ErrorResponse{
 			Code:    "ACCOUNT_INACTIVE",
-			Message: "Account is inactive. Please contact support.",
+			Message: "Account is inactive. Request reactivation or contact support.",
+			Details: map[string]interface{}{"reactivation_path": "/api/v1/auth/reactivate"},
 		})
 		return
 	}
@@ -653,6 +805,13 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 		// Don't fail login for this
 	}
 
+	// Flagged logins still succeed; the flag raises the user's fraud score
+	if h.geoAccess != nil {
+		if _, err := h.geoAccess.RecordLogin(ctx, user.ID, c.ClientIP()); err != nil {
+			h.logger.Warn("Failed to record login location", zap.Error(err), zap.String("user_id", user.ID.String()))
+		}
+	}
+
 	if h.emailService != nil && user.Email != "" {
 		alertDetails := adapters.LoginAlertDetails{
 			IP:        c.ClientIP(),
@@ -679,27 +838,41 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 	}
 
 	// Return success response
+	accessToken, refreshToken, csrfToken := h.sessionTokens(c, tokens)
 	response := entities.AuthResponse{
 		User:         user.ToUserInfo(),
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 		ExpiresAt:    tokens.ExpiresAt,
+		CSRFToken:    csrfToken,
+	}
+	if h.passwordPolicy != nil {
+		response.PasswordNotice = h.passwordPolicy.Review(ctx, user.ID, req.Password, personalInfo(user.Email, user.Phone)...)
 	}
 
-	h.logger.Info("User logged in successfully", zap.String("user_id", user.ID.String()), zap.String("email", user.Email))
+	h.logger.Info("User logged in successfully", zap.String("user_id", user.ID.String()), logger.Email(user.Email))
 	c.JSON(http.StatusOK, response)
 }
 
-// RefreshToken handles JWT token refresh
+// RefreshToken handles JWT token refresh. Cookie sessions refresh from the
+// refresh cookie and get a new access cookie.
 func (h *AuthHandlers) RefreshToken(c *gin.Context) {
-	var req entities.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondBadRequest(c, "Invalid request payload", nil)
-		return
+	refreshToken := ""
+	if h.cookieSessions != nil && h.cookieSessions.CookieEnabled() {
+		refreshToken = h.cookieSessions.RefreshToken(c)
+	}
+	fromCookie := refreshToken != ""
+	if !fromCookie {
+		var req entities.RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request payload", nil)
+			return
+		}
+		refreshToken = req.RefreshToken
 	}
 
 	// Validate refresh token format before processing
-	refreshToken := strings.TrimSpace(req.RefreshToken)
+	refreshToken = strings.TrimSpace(refreshToken)
 	if refreshToken == "" {
 		h.logger.Warn("Empty refresh token provided")
 		c.JSON(http.StatusUnauthorized, entities.ErrorResponse{Code: "INVALID_TOKEN", Message: "Invalid refresh token"})
@@ -721,12 +894,21 @@ func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, entities.ErrorResponse{Code: "INVALID_TOKEN", Message: "Invalid refresh token"})
 		return
 	}
+	if fromCookie {
+		pair.RefreshToken = ""
+		csrfToken := h.cookieSessions.Issue(c, pair)
+		c.JSON(http.StatusOK, gin.H{"expires_at": pair.ExpiresAt, "csrf_token": csrfToken})
+		return
+	}
 	c.JSON(http.StatusOK, pair)
 }
 
 // Logout handles user logout
 func (h *AuthHandlers) Logout(c *gin.Context) {
 	// For now, client can simply drop tokens. Optionally implement session invalidation.
+	if h.cookieSessions != nil && h.cookieSessions.CookieEnabled() {
+		h.cookieSessions.Clear(c)
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
 }
 
@@ -766,6 +948,12 @@ func (h *AuthHandlers) ResetPassword(c *gin.Context) {
 		respondBadRequest(c, "Invalid request payload", nil)
 		return
 	}
+	// Personal information is checked once the token identifies the user;
+	// everything else is checked first so a weak password does not use up
+	// the token
+	if !h.acceptablePassword(c, req.Password) {
+		return
+	}
 	ctx := c.Request.Context()
 	tokenHash, err := crypto.HashPassword(req.Token)
 	if err != nil {
@@ -779,6 +967,17 @@ func (h *AuthHandlers) ResetPassword(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, entities.ErrorResponse{Code: "INVALID_TOKEN", Message: "Invalid or expired reset token"})
 		return
 	}
+	if h.passwordPolicy != nil {
+		user, err := h.userRepo.GetUserEntityByID(ctx, userID)
+		if err != nil {
+			h.logger.Error("Failed to load user for password reset", zap.Error(err), zap.String("user_id", userID.String()))
+			respondInternalError(c, "Failed to reset password")
+			return
+		}
+		if !h.acceptablePassword(c, req.Password, personalInfo(user.Email, user.Phone)...) {
+			return
+		}
+	}
 	newHash, err := crypto.HashPassword(req.Password)
 	if err != nil {
 		h.logger.Error("Failed to hash new password", zap.Error(err))
@@ -871,10 +1070,22 @@ func (h *AuthHandlers) UpdateProfile(c *gin.Context) {
 	if payload.LastName != nil {
 		user.LastName = payload.LastName
 	}
+	if payload.Timezone != "" {
+		if err := entities.ValidateTimezone(payload.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, entities.ErrorResponse{Code: "INVALID_TIMEZONE", Message: err.Error()})
+			return
+		}
+	}
 	if err := h.userRepo.Update(ctx, user); err != nil {
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{Code: "UPDATE_FAILED", Message: "Failed to update profile"})
 		return
 	}
+	if payload.Timezone != "" && payload.Timezone != user.Timezone {
+		if err := h.userRepo.UpdateTimezone(ctx, userID, payload.Timezone); err != nil {
+			c.JSON(http.StatusInternalServerError, entities.ErrorResponse{Code: "UPDATE_FAILED", Message: "Failed to update profile"})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Profile updated"})
 }
 
@@ -904,6 +1115,9 @@ func (h *AuthHandlers) ChangePassword(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, entities.ErrorResponse{Code: "INVALID_CREDENTIALS", Message: "Current password is incorrect"})
 		return
 	}
+	if !h.acceptablePassword(c, req.NewPassword, personalInfo(user.Email, user.Phone)...) {
+		return
+	}
 	newHash, err := crypto.HashPassword(req.NewPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{Code: "HASH_FAILED", Message: "Failed to hash new password"})
@@ -991,7 +1205,7 @@ func (h *AuthHandlers) StartOnboarding(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed to start onboarding",
 			zap.Error(err),
-			zap.String("email", req.Email))
+			logger.Email(req.Email))
 
 		// Check for specific error types
 		if isUserAlreadyExistsError(err) {
@@ -1013,7 +1227,7 @@ func (h *AuthHandlers) StartOnboarding(c *gin.Context) {
 
 	h.logger.Info("Onboarding started successfully",
 		zap.String("user_id", response.UserID.String()),
-		zap.String("email", req.Email))
+		logger.Email(req.Email))
 
 	c.JSON(http.StatusCreated, response)
 }
@@ -1192,6 +1406,10 @@ func (h *AuthHandlers) SubmitKYC(c *gin.Context) {
 			zap.Error(err),
 			zap.String("user_id", userID.String()))
 
+		if respondAgeCheckError(c, err) || respondUploadReferenceError(c, err) {
+			return
+		}
+
 		if isKYCNotEligibleError(err) {
 			c.JSON(http.StatusForbidden, entities.ErrorResponse{
 				Code:    "KYC_NOT_ELIGIBLE",
@@ -1226,7 +1444,7 @@ func (h *AuthHandlers) SubmitKYC(c *gin.Context) {
 
 // ProcessKYCCallback handles KYC provider callbacks
 // @Summary Process KYC callback
-// @Description Handles callbacks from KYC providers with verification results
+// @Description Handles callbacks from KYC providers with verification results and intermediate review steps (documents requested, on hold, resubmission required)
 // @Tags onboarding
 // @Accept json
 // @Produce json
@@ -1264,74 +1482,12 @@ func (h *AuthHandlers) ProcessKYCCallback(c *gin.Context) {
 		return
 	}
 
-	// Extract status and rejection reasons from callback
-	// This would depend on the specific KYC provider's callback format
-	status := entities.KYCStatusProcessing
-	var rejectionReasons []string
-
-	var reviewResult map[string]interface{}
-	if raw, ok := callbackData["reviewResult"]; ok {
-		if rr, ok := raw.(map[string]interface{}); ok {
-			reviewResult = rr
-		}
-	}
-	if reviewResult == nil {
-		if payloadRaw, ok := callbackData["payload"].(map[string]interface{}); ok {
-			if rr, ok := payloadRaw["reviewResult"].(map[string]interface{}); ok {
-				reviewResult = rr
-			}
-		}
-	}
-
-	if reviewResult != nil {
-		if answer, ok := reviewResult["reviewAnswer"].(string); ok {
-			switch strings.ToUpper(strings.TrimSpace(answer)) {
-			case "GREEN":
-				status = entities.KYCStatusApproved
-			case "RED":
-				status = entities.KYCStatusRejected
-			}
-		}
-		if labels, ok := reviewResult["rejectLabels"].([]interface{}); ok {
-			for _, label := range labels {
-				switch v := label.(type) {
-				case map[string]interface{}:
-					if desc, ok := v["description"].(string); ok && desc != "" {
-						rejectionReasons = append(rejectionReasons, desc)
-					} else if code, ok := v["code"].(string); ok && code != "" {
-						rejectionReasons = append(rejectionReasons, code)
-					}
-				case string:
-					if strings.TrimSpace(v) != "" {
-						rejectionReasons = append(rejectionReasons, strings.TrimSpace(v))
-					}
-				}
-			}
-		}
-	}
-
-	if status == entities.KYCStatusProcessing {
-		if statusStr, ok := callbackData["status"].(string); ok {
-			switch strings.ToLower(statusStr) {
-			case "approved", "passed":
-				status = entities.KYCStatusApproved
-			case "rejected", "failed":
-				status = entities.KYCStatusRejected
-				if reasons, ok := callbackData["rejection_reasons"].([]interface{}); ok {
-					for _, reason := range reasons {
-						if reasonStr, ok := reason.(string); ok {
-							rejectionReasons = append(rejectionReasons, reasonStr)
-						}
-					}
-				}
-			case "processing", "pending":
-				status = entities.KYCStatusProcessing
-			}
-		}
-	}
+	update := adapters.MapKYCWebhook(callbackData)
+	status := update.Status
+	markWebhookVerified(c, "kyc_"+string(status), providerRef)
 
 	// Process the callback
-	err := h.onboardingService.ProcessKYCCallback(ctx, providerRef, status, rejectionReasons)
+	err := h.onboardingService.ProcessKYCCallback(ctx, providerRef, update)
 	if err != nil {
 		h.logger.Error("Failed to process KYC callback",
 			zap.Error(err),
@@ -1348,12 +1504,14 @@ func (h *AuthHandlers) ProcessKYCCallback(c *gin.Context) {
 
 	h.logger.Info("KYC callback processed successfully",
 		zap.String("provider_ref", providerRef),
-		zap.String("status", string(status)))
+		zap.String("status", string(status)),
+		zap.String("sub_status", string(update.SubStatus)))
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":      "Callback processed successfully",
 		"provider_ref": providerRef,
 		"status":       string(status),
+		"sub_status":   string(update.SubStatus),
 	})
 }
 
@@ -1416,6 +1574,22 @@ func (h *AuthHandlers) CompleteOnboarding(c *gin.Context) {
 	// Complete onboarding
 	response, err := h.onboardingService.CompleteOnboarding(ctx, &req)
 	if err != nil {
+		if errors.Is(err, entities.ErrJurisdictionNotSupported) {
+			h.logger.Warn("Onboarding rejected for unsupported jurisdiction",
+				zap.String("user_id", req.UserID.String()),
+				zap.String("country", req.Country),
+				zap.String("request_id", getRequestID(c)))
+			c.JSON(http.StatusForbidden, entities.ErrorResponse{
+				Code:    "JURISDICTION_NOT_SUPPORTED",
+				Message: "We are not able to offer accounts in your country yet",
+				Details: map[string]interface{}{"country": entities.NormalizeCountryCode(req.Country)},
+			})
+			return
+		}
+		if respondAgeCheckError(c, err) {
+			return
+		}
+
 		h.logger.Error("Failed to complete onboarding",
 			zap.Error(err),
 			zap.String("user_id", req.UserID.String()),
@@ -1482,6 +1656,26 @@ func isUserAlreadyExistsError(err error) bool {
 		contains(err.Error(), "conflict"))
 }
 
+// respondAgeCheckError writes the response for age verification failures and
+// reports whether err was one
+func respondAgeCheckError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, entities.ErrUnderage):
+		c.JSON(http.StatusForbidden, entities.ErrorResponse{
+			Code:    "UNDERAGE",
+			Message: "You must be at least 18 to open a self-directed account. A parent or guardian can open a custodial account instead.",
+		})
+		return true
+	case errors.Is(err, entities.ErrDateOfBirthRequired):
+		c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+			Code:    "DATE_OF_BIRTH_REQUIRED",
+			Message: "Date of birth is required",
+		})
+		return true
+	}
+	return false
+}
+
 func isKYCNotEligibleError(err error) bool {
 	return err != nil && (contains(err.Error(), "cannot start KYC") ||
 		contains(err.Error(), "not eligible"))
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/holds"
+	"go.uber.org/zap"
+)
+
+// BalanceHoldHandlers expose the holds on a user's buying power
+type BalanceHoldHandlers struct {
+	service *holds.Service
+	logger  *zap.Logger
+}
+
+// NewBalanceHoldHandlers creates a new balance hold handlers instance
+func NewBalanceHoldHandlers(service *holds.Service, logger *zap.Logger) *BalanceHoldHandlers {
+	return &BalanceHoldHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListActiveHolds handles GET /api/v1/balances/holds
+// @Summary List active balance holds
+// @Description Returns the funds held against buying power for orders and withdrawals that have not settled yet, with their total
+// @Tags balances
+// @Produce json
+// @Success 200 {object} entities.ActiveHolds
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/balances/holds [get]
+func (h *BalanceHoldHandlers) ListActiveHolds(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	active, err := h.service.ListActive(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list balance holds", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to list balance holds")
+		return
+	}
+	c.JSON(http.StatusOK, active)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/projection"
+	"go.uber.org/zap"
+)
+
+// BalanceProjectionHandlers expose a user's projected buying power
+type BalanceProjectionHandlers struct {
+	service *projection.Service
+	logger  *zap.Logger
+}
+
+// NewBalanceProjectionHandlers creates a new balance projection handlers instance
+func NewBalanceProjectionHandlers(service *projection.Service, logger *zap.Logger) *BalanceProjectionHandlers {
+	return &BalanceProjectionHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetBalanceProjection handles GET /api/v1/balance/projection
+// @Summary Project buying power
+// @Description Returns buying power at the end of each of the next 30 days, starting from settled cash and applying active holds, pending deposits at their expected settlement dates and scheduled auto-sweeps. Flows marked estimated may land on a different day or for a different amount.
+// @Tags balances
+// @Produce json
+// @Success 200 {object} entities.BuyingPowerProjection
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/balance/projection [get]
+func (h *BalanceProjectionHandlers) GetBalanceProjection(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	projected, err := h.service.Project(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to project buying power", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to project buying power")
+		return
+	}
+	c.JSON(http.StatusOK, projected)
+}
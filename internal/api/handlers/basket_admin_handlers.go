@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// BasketAdminHandlers manage regional variants of curated baskets
+type BasketAdminHandlers struct {
+	localization *investing.BasketLocalization
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewBasketAdminHandlers creates a new basket admin handlers instance
+func NewBasketAdminHandlers(localization *investing.BasketLocalization, auditService *adapters.AuditService, logger *zap.Logger) *BasketAdminHandlers {
+	return &BasketAdminHandlers{
+		localization: localization,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// CloneBasket handles POST /api/v1/admin/baskets/:id/clone
+// @Summary Clone a basket for a region
+// @Description Creates a regional variant of a curated basket, starting from its composition with the given symbol replacements or a full replacement composition. The clone links to the original basket so analytics cover both.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Basket ID"
+// @Param request body entities.CloneBasketRequest true "Clone"
+// @Success 201 {object} entities.Basket
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/baskets/{id}/clone [post]
+func (h *BasketAdminHandlers) CloneBasket(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	basketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid basket ID", nil)
+		return
+	}
+	var req entities.CloneBasketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	clone, err := h.localization.Clone(c.Request.Context(), basketID, &req)
+	if err != nil {
+		h.respondError(c, basketID, err, "Failed to clone basket")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "basket_cloned", "basket", nil, map[string]interface{}{
+		"basket_id":        clone.ID.String(),
+		"source_basket_id": basketID.String(),
+		"regions":          clone.Regions,
+	})
+	c.JSON(http.StatusCreated, clone)
+}
+
+// SetBasketRegions handles PUT /api/v1/admin/baskets/:id/regions
+// @Summary Set the regions a basket is offered in
+// @Description Takes ISO country codes or region groups such as EU. Users only see baskets tagged with their country; an empty list offers the basket everywhere.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Basket ID"
+// @Param request body entities.SetBasketRegionsRequest true "Regions"
+// @Success 200 {object} entities.Basket
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/baskets/{id}/regions [put]
+func (h *BasketAdminHandlers) SetBasketRegions(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	basketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid basket ID", nil)
+		return
+	}
+	var req entities.SetBasketRegionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	basket, err := h.localization.SetRegions(c.Request.Context(), basketID, req.Regions)
+	if err != nil {
+		h.respondError(c, basketID, err, "Failed to update basket regions")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "basket_regions_updated", "basket", nil, map[string]interface{}{
+		"basket_id": basketID.String(),
+		"regions":   basket.Regions,
+	})
+	c.JSON(http.StatusOK, basket)
+}
+
+// GetBasketAnalytics handles GET /api/v1/admin/baskets/:id/analytics
+// @Summary Get analytics for a basket family
+// @Description Orders, amount ordered and holders across the original basket and all of its regional variants, with a breakdown per variant. Either the original or a variant ID may be given.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Basket ID"
+// @Success 200 {object} entities.BasketFamilyAnalytics
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/baskets/{id}/analytics [get]
+func (h *BasketAdminHandlers) GetBasketAnalytics(c *gin.Context) {
+	basketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid basket ID", nil)
+		return
+	}
+
+	analytics, err := h.localization.FamilyAnalytics(c.Request.Context(), basketID)
+	if err != nil {
+		h.respondError(c, basketID, err, "Failed to get basket analytics")
+		return
+	}
+	c.JSON(http.StatusOK, analytics)
+}
+
+func (h *BasketAdminHandlers) respondError(c *gin.Context, basketID uuid.UUID, err error, failure string) {
+	switch {
+	case errors.Is(err, investing.ErrBasketNotFound):
+		respondNotFound(c, "Basket not found")
+	case errors.Is(err, entities.ErrInvalidRegion):
+		respondBadRequest(c, "Regions must be ISO country codes or a region group such as EU", nil)
+	case errors.Is(err, entities.ErrInvalidComposition):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(failure, zap.String("basket_id", basketID.String()), zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/blotter"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// BlotterHandlers let compliance retrieve the daily order blotter of record
+// and generate corrected versions
+type BlotterHandlers struct {
+	service      *blotter.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewBlotterHandlers creates a new order blotter handlers instance
+func NewBlotterHandlers(service *blotter.Service, auditService *adapters.AuditService, logger *zap.Logger) *BlotterHandlers {
+	return &BlotterHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// OrderBlotterListResponse lists stored blotter versions
+type OrderBlotterListResponse struct {
+	Blotters []*entities.OrderBlotter `json:"blotters"`
+}
+
+// ListBlotters handles GET /api/v1/admin/regulatory/blotters
+// @Summary List order blotters
+// @Description Lists every stored version of the daily order blotter for business days in the range, newest version of each day first
+// @Tags admin
+// @Produce json
+// @Param from query string true "First day (YYYY-MM-DD)"
+// @Param to query string true "Last day (YYYY-MM-DD)"
+// @Success 200 {object} handlers.OrderBlotterListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/regulatory/blotters [get]
+func (h *BlotterHandlers) ListBlotters(c *gin.Context) {
+	from, err := h.service.ParseDay(c.Query("from"))
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	to, err := h.service.ParseDay(c.Query("to"))
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	blotters, err := h.service.List(c.Request.Context(), from, to)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list order blotters")
+		return
+	}
+	c.JSON(http.StatusOK, OrderBlotterListResponse{Blotters: blotters})
+}
+
+// DownloadBlotter handles GET /api/v1/admin/regulatory/blotters/{id}
+// @Summary Download an order blotter
+// @Description Returns the stored blotter file exactly as generated. X-Checksum-SHA256 carries the checksum recorded when it was stored.
+// @Tags admin
+// @Produce text/csv,text/plain
+// @Param id path string true "Blotter ID"
+// @Success 200 {file} file
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/regulatory/blotters/{id} [get]
+func (h *BlotterHandlers) DownloadBlotter(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid blotter ID", nil)
+		return
+	}
+
+	stored, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get order blotter")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "download_order_blotter", "order_blotter", nil, map[string]interface{}{
+		"blotter_id":    stored.ID,
+		"business_date": stored.BusinessDate.Format("2006-01-02"),
+		"version":       stored.Version,
+	})
+	contentType := "text/csv; charset=utf-8"
+	if stored.Format == entities.BlotterFormatFIX {
+		contentType = "text/plain; charset=utf-8"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", blotter.FileName(stored)))
+	c.Header("X-Checksum-SHA256", stored.Checksum)
+	c.Data(http.StatusOK, contentType, stored.Content)
+}
+
+// GenerateBlotter handles POST /api/v1/admin/regulatory/blotters
+// @Summary Generate an order blotter version
+// @Description Generates a settled day's blotter again and stores it as that day's next version, for example after an order was corrected. Earlier versions are kept.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.GenerateBlotterRequest true "Day, format and reason"
+// @Success 201 {object} entities.OrderBlotter
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/regulatory/blotters [post]
+func (h *BlotterHandlers) GenerateBlotter(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.GenerateBlotterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	day, err := h.service.ParseDay(req.Date)
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	stored, err := h.service.Generate(c.Request.Context(), day, req.Format, req.Reason, adminID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to generate order blotter")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "generate_order_blotter", "order_blotter", nil, stored)
+	c.JSON(http.StatusCreated, stored)
+}
+
+func (h *BlotterHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrInvalidBlotterDateRange), errors.Is(err, entities.ErrInvalidBlotterFormat):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrBlotterNotFound):
+		respondNotFound(c, "Order blotter not found")
+	case errors.Is(err, entities.ErrBlotterVersionConflict):
+		respondError(c, http.StatusConflict, "VERSION_CONFLICT", "Another version of this day's blotter was stored at the same time; try again", nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/bulkops"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// BulkOperationHandlers let admins deactivate, reactivate, re-verify or
+// re-invite many users at once
+type BulkOperationHandlers struct {
+	service      *bulkops.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewBulkOperationHandlers creates a new bulk operation handlers instance
+func NewBulkOperationHandlers(service *bulkops.Service, auditService *adapters.AuditService, logger *zap.Logger) *BulkOperationHandlers {
+	return &BulkOperationHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// BulkOperationListResponse lists bulk operations
+type BulkOperationListResponse struct {
+	Operations []*entities.BulkOperation `json:"operations"`
+}
+
+// BulkOperationItemListResponse lists the per-user outcomes of a bulk operation
+type BulkOperationItemListResponse struct {
+	Items []*entities.BulkOperationItem `json:"items"`
+}
+
+// CreateBulkOperation handles POST /api/v1/admin/bulk-operations
+// @Summary Start a bulk user operation
+// @Description Queues deactivate_users, reactivate_users, kyc_recheck or resend_verification_email for a list of users. Users are processed in the background; poll the operation or download its results.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateBulkOperationRequest true "Operation"
+// @Success 202 {object} entities.BulkOperation
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/bulk-operations [post]
+func (h *BulkOperationHandlers) CreateBulkOperation(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateBulkOperationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	op, err := h.service.Create(c.Request.Context(), &req, adminID)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidBulkOperation) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to create bulk operation", zap.Error(err))
+		respondInternalError(c, "Failed to create bulk operation")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "create_bulk_operation", "bulk_operation", nil, map[string]interface{}{
+		"operation_id": op.ID.String(),
+		"type":         string(op.Type),
+		"users":        op.Total,
+		"reason":       op.Reason,
+	})
+	c.JSON(http.StatusAccepted, op)
+}
+
+// ListBulkOperations handles GET /api/v1/admin/bulk-operations
+// @Summary List bulk user operations
+// @Description Returns bulk operations newest first with their outcome counts
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Offset"
+// @Success 200 {object} handlers.BulkOperationListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/bulk-operations [get]
+func (h *BulkOperationHandlers) ListBulkOperations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	ops, err := h.service.List(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list bulk operations", zap.Error(err))
+		respondInternalError(c, "Failed to list bulk operations")
+		return
+	}
+	c.JSON(http.StatusOK, BulkOperationListResponse{Operations: ops})
+}
+
+// GetBulkOperation handles GET /api/v1/admin/bulk-operations/:id
+// @Summary Get a bulk user operation
+// @Tags admin
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} entities.BulkOperation
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/bulk-operations/{id} [get]
+func (h *BulkOperationHandlers) GetBulkOperation(c *gin.Context) {
+	id, ok := h.operationID(c)
+	if !ok {
+		return
+	}
+
+	op, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get bulk operation")
+		return
+	}
+	c.JSON(http.StatusOK, op)
+}
+
+// ListBulkOperationItems handles GET /api/v1/admin/bulk-operations/:id/items
+// @Summary List per-user outcomes of a bulk operation
+// @Tags admin
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Param status query string false "Filter by outcome (pending, succeeded, skipped, failed)"
+// @Success 200 {object} handlers.BulkOperationItemListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/bulk-operations/{id}/items [get]
+func (h *BulkOperationHandlers) ListBulkOperationItems(c *gin.Context) {
+	id, ok := h.operationID(c)
+	if !ok {
+		return
+	}
+
+	status := entities.BulkItemStatus(c.Query("status"))
+	switch status {
+	case "", entities.BulkItemPending, entities.BulkItemSucceeded, entities.BulkItemSkipped, entities.BulkItemFailed:
+	default:
+		respondBadRequest(c, "Status must be pending, succeeded, skipped or failed", nil)
+		return
+	}
+
+	items, err := h.service.Items(c.Request.Context(), id, status)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list bulk operation items")
+		return
+	}
+	c.JSON(http.StatusOK, BulkOperationItemListResponse{Items: items})
+}
+
+// DownloadBulkOperationResults handles GET /api/v1/admin/bulk-operations/:id/results.csv
+// @Summary Download bulk operation results
+// @Description Downloads a CSV with one row per user: user_id, status, message, attempts, processed_at
+// @Tags admin
+// @Produce text/csv
+// @Param id path string true "Operation ID"
+// @Success 200 {string} string
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/bulk-operations/{id}/results.csv [get]
+func (h *BulkOperationHandlers) DownloadBulkOperationResults(c *gin.Context) {
+	id, ok := h.operationID(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.WriteResultsCSV(c.Request.Context(), id, &buf); err != nil {
+		h.respondServiceError(c, err, "Failed to export bulk operation results")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "bulk-operation-"+id.String()+".csv"))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// CancelBulkOperation handles POST /api/v1/admin/bulk-operations/:id/cancel
+// @Summary Cancel a bulk user operation
+// @Description Users not yet processed are skipped; users already processed keep their outcome
+// @Tags admin
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} entities.BulkOperation
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/bulk-operations/{id}/cancel [post]
+func (h *BulkOperationHandlers) CancelBulkOperation(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, ok := h.operationID(c)
+	if !ok {
+		return
+	}
+
+	op, err := h.service.Cancel(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to cancel bulk operation")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "cancel_bulk_operation", "bulk_operation", nil, map[string]interface{}{
+		"operation_id": op.ID.String(),
+		"type":         string(op.Type),
+		"succeeded":    op.Succeeded,
+		"skipped":      op.Skipped,
+		"failed":       op.Failed,
+	})
+	c.JSON(http.StatusOK, op)
+}
+
+func (h *BulkOperationHandlers) operationID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid operation ID", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *BulkOperationHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	if errors.Is(err, entities.ErrBulkOperationNotFound) {
+		respondNotFound(c, "Bulk operation not found")
+		return
+	}
+	h.logger.Error(message, zap.Error(err))
+	respondInternalError(c, message)
+}
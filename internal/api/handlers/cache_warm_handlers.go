@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/hotcache"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// CacheWarmHandlers let admins warm the hot endpoint cache on demand and see
+// how the last warm-up went
+type CacheWarmHandlers struct {
+	service      *hotcache.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewCacheWarmHandlers creates new cache warm-up handlers
+func NewCacheWarmHandlers(service *hotcache.Service, auditService *adapters.AuditService, logger *zap.Logger) *CacheWarmHandlers {
+	return &CacheWarmHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// WarmCache handles POST /api/v1/admin/cache/warm
+// @Summary Warm the hot endpoint cache
+// @Description Loads balances and portfolios for recently active users and the most viewed baskets into Redis in the background. Poll GET /api/v1/admin/cache/warm for the result.
+// @Tags admin
+// @Produce json
+// @Success 202 {object} entities.CacheWarmReport
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cache/warm [post]
+func (h *CacheWarmHandlers) WarmCache(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	if err := h.service.WarmInBackground(c.Request.Context(), entities.CacheWarmTriggerAdmin); err != nil {
+		// The only failure is a warm-up already in progress
+		respondError(c, http.StatusConflict, "CACHE_WARM_RUNNING", err.Error(), nil)
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "warm_cache", "cache", nil, nil)
+	c.JSON(http.StatusAccepted, h.service.LastWarm())
+}
+
+// GetCacheWarm handles GET /api/v1/admin/cache/warm
+// @Summary Get the last cache warm-up
+// @Description The running or most recent warm-up, whether started at boot or by an admin
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entities.CacheWarmReport
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cache/warm [get]
+func (h *CacheWarmHandlers) GetCacheWarm(c *gin.Context) {
+	report := h.service.LastWarm()
+	if report == nil {
+		respondNotFound(c, "No cache warm-up has run since the server started")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/chainaddr"
+)
+
+// ChainHandlers serves the chain registry to clients
+type ChainHandlers struct {
+	registry *entities.ChainRegistry
+}
+
+// NewChainHandlers creates a new chain handlers instance
+func NewChainHandlers(registry *entities.ChainRegistry) *ChainHandlers {
+	return &ChainHandlers{registry: registry}
+}
+
+// ListChains handles GET /api/v1/chains
+// @Summary List supported chains
+// @Description Returns the chains USDC can be held on with their native currency, USDC token address, explorer link template, deposit confirmation threshold and address format. Disabled chains are included with all=true.
+// @Tags chains
+// @Produce json
+// @Param all query bool false "Include chains that are not enabled"
+// @Success 200 {object} handlers.ChainListResponse
+// @Router /api/v1/chains [get]
+func (h *ChainHandlers) ListChains(c *gin.Context) {
+	chains := h.registry.Enabled()
+	if c.Query("all") == "true" {
+		chains = h.registry.All()
+	}
+	if chains == nil {
+		chains = []entities.ChainInfo{}
+	}
+	c.JSON(http.StatusOK, ChainListResponse{Chains: chains})
+}
+
+// ValidateChainAddress handles GET /api/v1/chains/:chain/validate-address
+// @Summary Validate an address
+// @Description Checks an address the way withdrawals and recipients do, so clients can flag a mistyped address before submitting it. EVM addresses are checked against their EIP-55 checksum when written in mixed case. The canonical form is returned for valid addresses.
+// @Tags chains
+// @Produce json
+// @Param chain path string true "Chain ID or alias"
+// @Param address query string true "Address to check"
+// @Success 200 {object} handlers.ChainAddressValidationResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Router /api/v1/chains/{chain}/validate-address [get]
+func (h *ChainHandlers) ValidateChainAddress(c *gin.Context) {
+	chain, ok := h.registry.Lookup(c.Param("chain"))
+	if !ok {
+		respondNotFound(c, "Chain not found")
+		return
+	}
+
+	address := c.Query("address")
+	response := ChainAddressValidationResponse{Chain: string(chain.ID), Address: address}
+	normalized, err := chain.CheckAddress(address)
+	switch {
+	case err == nil:
+		response.Valid = true
+		response.Normalized = normalized
+	case errors.Is(err, chainaddr.ErrChecksum):
+		response.Reason = "checksum"
+	case errors.Is(err, chainaddr.ErrEmpty):
+		response.Reason = "empty"
+	default:
+		response.Reason = "malformed"
+	}
+	if err != nil {
+		response.Message = err.Error()
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/circlesubscription"
+	"go.uber.org/zap"
+)
+
+// CircleSubscriptionHandlers lets administrators inspect and repair the Circle notification subscription
+type CircleSubscriptionHandlers struct {
+	service *circlesubscription.Service
+	logger  *zap.Logger
+}
+
+// NewCircleSubscriptionHandlers creates a new Circle subscription handlers instance
+func NewCircleSubscriptionHandlers(service *circlesubscription.Service, logger *zap.Logger) *CircleSubscriptionHandlers {
+	return &CircleSubscriptionHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListSubscriptions handles GET /api/v1/admin/circle/subscriptions
+// @Summary List Circle notification subscriptions
+// @Description Returns every subscription registered on the Circle account
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.CircleSubscriptionListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/circle/subscriptions [get]
+func (h *CircleSubscriptionHandlers) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list Circle subscriptions", zap.Error(err))
+		respondError(c, http.StatusBadGateway, "CIRCLE_ERROR", "Failed to list Circle subscriptions", nil)
+		return
+	}
+	c.JSON(http.StatusOK, CircleSubscriptionListResponse{Subscriptions: subscriptions})
+}
+
+// GetStatus handles GET /api/v1/admin/circle/subscriptions/status
+// @Summary Get Circle subscription status
+// @Description Returns the outcome of the latest subscription check
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entities.CircleSubscriptionStatus
+// @Security BearerAuth
+// @Router /api/v1/admin/circle/subscriptions/status [get]
+func (h *CircleSubscriptionHandlers) GetStatus(c *gin.Context) {
+	status := h.service.Status()
+	if status == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": h.service.Enabled(), "checked": false})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// SyncSubscription handles POST /api/v1/admin/circle/subscriptions/sync
+// @Summary Register or verify the Circle subscription
+// @Description Ensures an enabled subscription exists for the configured callback URL and removes ones registered for a previous URL
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entities.CircleSubscriptionStatus
+// @Failure 409 {object} entities.ErrorResponse
+// @Failure 502 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/circle/subscriptions/sync [post]
+func (h *CircleSubscriptionHandlers) SyncSubscription(c *gin.Context) {
+	status, err := h.service.Sync(c.Request.Context())
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to sync Circle subscription")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// DeleteSubscription handles DELETE /api/v1/admin/circle/subscriptions/:id
+// @Summary Delete a Circle notification subscription
+// @Tags admin
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 502 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/circle/subscriptions/{id} [delete]
+func (h *CircleSubscriptionHandlers) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		respondBadRequest(c, "Subscription ID is required", nil)
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		h.respondServiceError(c, err, "Failed to delete Circle subscription")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CircleSubscriptionHandlers) respondServiceError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, circlesubscription.ErrNotConfigured):
+		respondError(c, http.StatusConflict, "NOT_CONFIGURED", err.Error(), nil)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		respondError(c, http.StatusBadGateway, "CIRCLE_ERROR", msg, map[string]interface{}{"error": err.Error()})
+	}
+}
@@ -12,25 +12,31 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/domain/services/onchain"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/webhook"
 )
 
+// CircleSignatureVerifier checks a notification against the Circle public key it names
+type CircleSignatureVerifier interface {
+	VerifySignature(ctx context.Context, keyID, signature string, body []byte) error
+}
+
 // CircleWebhookHandler handles Circle API webhook notifications
 type CircleWebhookHandler struct {
 	onchainEngine *onchain.Engine
 	logger        *logger.Logger
-	webhookSecret string // For signature verification
+	verifier      CircleSignatureVerifier // For signature verification
 }
 
 // NewCircleWebhookHandler creates a new Circle webhook handler
 func NewCircleWebhookHandler(
 	onchainEngine *onchain.Engine,
 	logger *logger.Logger,
-	webhookSecret string,
+	verifier CircleSignatureVerifier,
 ) *CircleWebhookHandler {
 	return &CircleWebhookHandler{
 		onchainEngine: onchainEngine,
 		logger:        logger,
-		webhookSecret: webhookSecret,
+		verifier:      verifier,
 	}
 }
 
@@ -40,7 +46,7 @@ func (h *CircleWebhookHandler) HandleTransferNotification(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Verify webhook signature
-	signature := c.GetHeader("X-Circle-Signature")
+	signature := c.GetHeader(webhook.CircleSignatureHeader)
 	if signature == "" {
 		h.logger.Warn("Missing Circle webhook signature")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing signature"})
@@ -53,32 +59,31 @@ func (h *CircleWebhookHandler) HandleTransferNotification(c *gin.Context) {
 		rawBody, _ = c.GetRawData()
 	}
 
-	// Verify signature (implement actual verification based on Circle docs)
-	if !h.verifySignature(signature, rawBody) {
+	if !h.verifySignature(ctx, c.GetHeader(webhook.CircleKeyIDHeader), signature, rawBody) {
 		h.logger.Error("Invalid Circle webhook signature")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
 		return
 	}
 
 	// Parse webhook payload
-	var webhook CircleTransferWebhook
-	if err := json.Unmarshal(rawBody, &webhook); err != nil {
+	var notification CircleTransferWebhook
+	if err := json.Unmarshal(rawBody, &notification); err != nil {
 		h.logger.Error("Failed to parse Circle webhook", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
 
 	h.logger.Info("Received Circle transfer webhook",
-		"notification_type", webhook.NotificationType,
-		"transfer_id", webhook.TransferID,
-		"status", webhook.Transfer.Status)
+		"notification_type", notification.NotificationType,
+		"transfer_id", notification.TransferID,
+		"status", notification.Transfer.Status)
 
 	// Process based on notification type
-	switch webhook.NotificationType {
+	switch notification.NotificationType {
 	case "transfers.created", "transfers.completed":
-		if err := h.processIncomingTransfer(ctx, &webhook); err != nil {
+		if err := h.processIncomingTransfer(ctx, &notification); err != nil {
 			h.logger.Error("Failed to process incoming transfer",
-				"transfer_id", webhook.TransferID,
+				"transfer_id", notification.TransferID,
 				"error", err)
 			// Return 200 to prevent retries for processing errors
 			// Store failure for manual review
@@ -88,13 +93,13 @@ func (h *CircleWebhookHandler) HandleTransferNotification(c *gin.Context) {
 
 	case "transfers.failed":
 		h.logger.Warn("Circle transfer failed",
-			"transfer_id", webhook.TransferID,
-			"error", webhook.Transfer.ErrorCode)
+			"transfer_id", notification.TransferID,
+			"error", notification.Transfer.ErrorCode)
 		// Handle failed transfers (e.g., notify user, reverse ledger entries)
 
 	default:
 		h.logger.Info("Unhandled Circle notification type",
-			"type", webhook.NotificationType)
+			"type", notification.NotificationType)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
@@ -151,25 +156,19 @@ func (h *CircleWebhookHandler) processIncomingTransfer(ctx context.Context, webh
 	return nil
 }
 
-// verifySignature verifies the Circle webhook signature
-func (h *CircleWebhookHandler) verifySignature(signature string, body []byte) bool {
-	// Implement Circle's signature verification algorithm
-	// This typically involves HMAC-SHA256 with the webhook secret
-	// See Circle documentation for exact algorithm
-	
-	// For now, return true if secret is not configured (dev mode)
-	if h.webhookSecret == "" {
-		h.logger.Warn("Webhook secret not configured - skipping signature verification")
+// verifySignature verifies the Circle webhook signature. Circle signs each
+// notification with ECDSA and names the public key in X-Circle-Key-Id.
+func (h *CircleWebhookHandler) verifySignature(ctx context.Context, keyID, signature string, body []byte) bool {
+	// Skip verification if no verifier is configured (dev mode)
+	if h.verifier == nil {
+		h.logger.Warn("Circle signature verifier not configured - skipping signature verification")
 		return true
 	}
 
-	// TODO: Implement actual signature verification
-	// Example (pseudo-code):
-	// expectedSignature := hmac.New(sha256.New, []byte(h.webhookSecret))
-	// expectedSignature.Write(body)
-	// expected := hex.EncodeToString(expectedSignature.Sum(nil))
-	// return subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1
-
+	if err := h.verifier.VerifySignature(ctx, keyID, signature, body); err != nil {
+		h.logger.Warn("Circle signature verification failed", "key_id", keyID, "error", err)
+		return false
+	}
 	return true
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/api/middleware"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 )
 
@@ -28,6 +29,13 @@ func getUserID(c *gin.Context) (uuid.UUID, error) {
 	}
 }
 
+// markWebhookVerified tells the webhook archive that the request passed
+// verification, and what it is about
+func markWebhookVerified(c *gin.Context, eventType, reference string) {
+	c.Set(middleware.WebhookEventTypeKey, eventType)
+	c.Set(middleware.WebhookReferenceKey, reference)
+}
+
 // getRequestID extracts request ID from context
 func getRequestID(c *gin.Context) string {
 	if reqID, exists := c.Get("request_id"); exists {
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/consents"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// ConsentHandlers let users accept agreement versions and admins publish them
+type ConsentHandlers struct {
+	service      *consents.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewConsentHandlers creates a new consent handlers instance
+func NewConsentHandlers(service *consents.Service, auditService *adapters.AuditService, logger *zap.Logger) *ConsentHandlers {
+	return &ConsentHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ConsentStatusResponse lists the user's standing on each agreement
+type ConsentStatusResponse struct {
+	Agreements []*entities.ConsentStatus `json:"agreements"`
+	// TradingBlocked is set while a mandatory version is outstanding
+	TradingBlocked bool `json:"trading_blocked"`
+}
+
+// AgreementVersionListResponse lists published agreement versions
+type AgreementVersionListResponse struct {
+	Versions []*entities.AgreementVersion `json:"versions"`
+}
+
+// GetConsents handles GET /api/v1/consents
+// @Summary Get the user's agreement acceptance status
+// @Description Lists the current version of each agreement and the version the user last accepted
+// @Tags consents
+// @Produce json
+// @Success 200 {object} handlers.ConsentStatusResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/consents [get]
+func (h *ConsentHandlers) GetConsents(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	statuses, err := h.service.Status(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get consent status", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get consent status")
+		return
+	}
+
+	resp := ConsentStatusResponse{Agreements: statuses}
+	for _, status := range statuses {
+		if status.RequiresAcceptance {
+			resp.TradingBlocked = true
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AcceptAgreement handles POST /api/v1/consents/:id/accept
+// @Summary Accept an agreement version
+// @Description Only the current version of an agreement can be accepted
+// @Tags consents
+// @Produce json
+// @Param id path string true "Agreement version ID"
+// @Success 200 {object} entities.UserConsent
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/consents/{id}/accept [post]
+func (h *ConsentHandlers) AcceptAgreement(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	versionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid agreement version ID", nil)
+		return
+	}
+
+	consent, err := h.service.Accept(c.Request.Context(), userID, versionID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrAgreementNotFound):
+			respondNotFound(c, err.Error())
+		case errors.Is(err, entities.ErrAgreementSuperseded):
+			respondError(c, http.StatusConflict, "AGREEMENT_SUPERSEDED", "A newer version of this agreement has been published", nil)
+		default:
+			h.logger.Error("Failed to accept agreement", zap.String("user_id", userID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to accept agreement")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, consent)
+}
+
+// ListAgreements handles GET /api/v1/admin/agreements
+// @Summary List published agreement versions
+// @Tags admin
+// @Produce json
+// @Param type query string false "Filter by agreement type (terms_of_service, privacy_policy, brokerage_agreement)"
+// @Success 200 {object} handlers.AgreementVersionListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/agreements [get]
+func (h *ConsentHandlers) ListAgreements(c *gin.Context) {
+	agreementType := entities.AgreementType(c.Query("type"))
+	if agreementType != "" && !agreementType.IsValid() {
+		respondBadRequest(c, "Unknown agreement type", nil)
+		return
+	}
+
+	versions, err := h.service.ListVersions(c.Request.Context(), agreementType)
+	if err != nil {
+		h.logger.Error("Failed to list agreement versions", zap.Error(err))
+		respondInternalError(c, "Failed to list agreement versions")
+		return
+	}
+	c.JSON(http.StatusOK, AgreementVersionListResponse{Versions: versions})
+}
+
+// PublishAgreement handles POST /api/v1/admin/agreements
+// @Summary Publish a new agreement version
+// @Description A mandatory version blocks trading for every user until they accept it
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.PublishAgreementRequest true "Agreement version"
+// @Success 201 {object} entities.AgreementVersion
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/agreements [post]
+func (h *ConsentHandlers) PublishAgreement(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.PublishAgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	version, err := h.service.Publish(c.Request.Context(), &req, adminID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidAgreement):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, entities.ErrAgreementVersionExists):
+			respondError(c, http.StatusConflict, "VERSION_EXISTS", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to publish agreement version", zap.Error(err))
+			respondInternalError(c, "Failed to publish agreement version")
+		}
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "agreement_publish", "agreement_versions", nil, map[string]interface{}{
+		"id":             version.ID,
+		"agreement_type": version.AgreementType,
+		"version":        version.Version,
+		"mandatory":      version.Mandatory,
+	})
+	c.JSON(http.StatusCreated, version)
+}
@@ -8,14 +8,16 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stack-service/stack_service/pkg/health"
 	"github.com/stack-service/stack_service/pkg/logger"
 	"github.com/stack-service/stack_service/pkg/version"
 )
 
 // CoreHandlers contains health, version, and metrics handlers
 type CoreHandlers struct {
-	db     *sql.DB
-	logger *logger.Logger
+	db              *sql.DB
+	logger          *logger.Logger
+	readinessChecks []health.Checker
 }
 
 // NewCoreHandlers creates a new core handlers instance
@@ -26,6 +28,12 @@ func NewCoreHandlers(db *sql.DB, logger *logger.Logger) *CoreHandlers {
 	}
 }
 
+// AddReadinessCheck reports an extra component in /ready. Only an unhealthy
+// result marks the service not ready; degraded results are reported as-is.
+func (h *CoreHandlers) AddReadinessCheck(checker health.Checker) {
+	h.readinessChecks = append(h.readinessChecks, checker)
+}
+
 var startTime = time.Now()
 
 // HealthCheck represents a health check result
@@ -48,8 +56,7 @@ type HealthResponse struct {
 
 // Health performs comprehensive health checks
 func (h *CoreHandlers) Health(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	checks := make(map[string]HealthCheck)
 	overallStatus := "healthy"
@@ -78,8 +85,7 @@ func (h *CoreHandlers) Health(c *gin.Context) {
 
 // Ready checks if the application is ready to serve traffic
 func (h *CoreHandlers) Ready(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	dbCheck := h.checkDatabase(ctx)
 	ready := dbCheck.Status == "healthy"
@@ -88,12 +94,22 @@ func (h *CoreHandlers) Ready(c *gin.Context) {
 		status = "not_ready"
 	}
 
+	checks := map[string]interface{}{
+		"database": dbCheck,
+	}
+	for _, checker := range h.readinessChecks {
+		result := checker.Check(ctx)
+		checks[checker.Name()] = result
+		if result.Status == health.StatusUnhealthy {
+			ready = false
+			status = "not_ready"
+		}
+	}
+
 	response := map[string]interface{}{
 		"status":    status,
 		"timestamp": time.Now(),
-		"checks": map[string]interface{}{
-			"database": dbCheck,
-		},
+		"checks":    checks,
 	}
 
 	statusCode := http.StatusOK
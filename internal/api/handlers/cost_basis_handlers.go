@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/costbasis"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// CostBasisHandlers import the cost basis of positions transferred in from
+// another broker and let admins correct the resulting tax lots
+type CostBasisHandlers struct {
+	service      *costbasis.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewCostBasisHandlers creates a new cost basis handlers instance
+func NewCostBasisHandlers(service *costbasis.Service, auditService *adapters.AuditService, logger *zap.Logger) *CostBasisHandlers {
+	return &CostBasisHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ImportCostBasis handles POST /api/v1/portfolio/cost-basis/imports
+// @Summary Import cost basis from a broker export
+// @Description Reads tax lots from the CSV export of the broker positions are being transferred from. The file needs symbol, quantity, acquired date and total or per-share cost columns, under the names brokers commonly use. The lots are held for review and nothing is recorded until the import is confirmed. Rows that could not be read are listed with the reason.
+// @Tags portfolio
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Broker CSV export"
+// @Success 201 {object} entities.CostBasisImport
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/cost-basis/imports [post]
+func (h *CostBasisHandlers) ImportCostBasis(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		respondBadRequest(c, "A CSV file is required in the file field", nil)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondBadRequest(c, "Failed to read the uploaded file", nil)
+		return
+	}
+	defer file.Close()
+
+	imp, err := h.service.Import(c.Request.Context(), userID, filepath.Base(header.Filename), file)
+	if err != nil {
+		h.respondCostBasisError(c, err, "Failed to import cost basis")
+		return
+	}
+	c.JSON(http.StatusCreated, imp)
+}
+
+// GetCostBasisImport handles GET /api/v1/portfolio/cost-basis/imports/:id
+// @Summary Get a cost basis import
+// @Description Returns the lots read from an import and the rows that could not be read, for review before confirming
+// @Tags portfolio
+// @Produce json
+// @Param id path string true "Import ID"
+// @Success 200 {object} entities.CostBasisImport
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/cost-basis/imports/{id} [get]
+func (h *CostBasisHandlers) GetCostBasisImport(c *gin.Context) {
+	userID, id, ok := h.importParams(c)
+	if !ok {
+		return
+	}
+	imp, err := h.service.GetImport(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondCostBasisError(c, err, "Failed to get cost basis import")
+		return
+	}
+	c.JSON(http.StatusOK, imp)
+}
+
+// ConfirmCostBasisImport handles POST /api/v1/portfolio/cost-basis/imports/:id/confirm
+// @Summary Confirm a cost basis import
+// @Description Records the import's lots as tax lots. An import with rows that could not be read cannot be confirmed; correct the file and import it again.
+// @Tags portfolio
+// @Produce json
+// @Param id path string true "Import ID"
+// @Success 201 {object} handlers.TaxLotListResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse "Import already confirmed, cancelled or expired"
+// @Failure 422 {object} entities.ErrorResponse "Import has rows with errors or no lots"
+// @Security BearerAuth
+// @Router /api/v1/portfolio/cost-basis/imports/{id}/confirm [post]
+func (h *CostBasisHandlers) ConfirmCostBasisImport(c *gin.Context) {
+	userID, id, ok := h.importParams(c)
+	if !ok {
+		return
+	}
+	lots, err := h.service.Confirm(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondCostBasisError(c, err, "Failed to confirm cost basis import")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "confirm_cost_basis_import", "cost_basis_import", nil, map[string]interface{}{
+		"import_id": id.String(),
+		"lots":      len(lots),
+	})
+	c.JSON(http.StatusCreated, TaxLotListResponse{Lots: lots})
+}
+
+// CancelCostBasisImport handles DELETE /api/v1/portfolio/cost-basis/imports/:id
+// @Summary Cancel a cost basis import
+// @Description Discards an import awaiting review
+// @Tags portfolio
+// @Param id path string true "Import ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/cost-basis/imports/{id} [delete]
+func (h *CostBasisHandlers) CancelCostBasisImport(c *gin.Context) {
+	userID, id, ok := h.importParams(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Cancel(c.Request.Context(), userID, id); err != nil {
+		h.respondCostBasisError(c, err, "Failed to cancel cost basis import")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListTaxLots handles GET /api/v1/portfolio/cost-basis/lots
+// @Summary List tax lots
+// @Description Returns the user's tax lots with their cost basis, by symbol and acquired date
+// @Tags portfolio
+// @Produce json
+// @Success 200 {object} handlers.TaxLotListResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/cost-basis/lots [get]
+func (h *CostBasisHandlers) ListTaxLots(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	h.listLots(c, userID)
+}
+
+// AdminListTaxLots handles GET /api/v1/admin/users/:id/tax-lots
+// @Summary List a user's tax lots
+// @Description Returns a user's tax lots, marking those already corrected
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} handlers.TaxLotListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/tax-lots [get]
+func (h *CostBasisHandlers) AdminListTaxLots(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid user ID", nil)
+		return
+	}
+	h.listLots(c, userID)
+}
+
+// AdjustTaxLot handles PATCH /api/v1/admin/tax-lots/:id
+// @Summary Adjust a tax lot
+// @Description Corrects a lot's quantity, cost basis or acquired date, typically to the custodian's final figures once a transfer settles. The lot before and after is kept with the reason.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tax lot ID"
+// @Param request body entities.AdjustTaxLotRequest true "Corrected values"
+// @Success 200 {object} entities.TaxLot
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/tax-lots/{id} [patch]
+func (h *CostBasisHandlers) AdjustTaxLot(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	lotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid tax lot ID", nil)
+		return
+	}
+	var req entities.AdjustTaxLotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	lot, err := h.service.AdjustLot(c.Request.Context(), adminID, lotID, &req)
+	if err != nil {
+		h.respondCostBasisError(c, err, "Failed to adjust tax lot")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "adjust_tax_lot", "tax_lot", nil, map[string]interface{}{
+		"lot_id":  lot.ID.String(),
+		"user_id": lot.UserID.String(),
+		"reason":  req.Reason,
+	})
+	c.JSON(http.StatusOK, lot)
+}
+
+// ListTaxLotAdjustments handles GET /api/v1/admin/tax-lots/:id/adjustments
+// @Summary List a tax lot's adjustments
+// @Description Returns each correction made to a lot with the lot before and after, oldest first
+// @Tags admin
+// @Produce json
+// @Param id path string true "Tax lot ID"
+// @Success 200 {object} handlers.TaxLotAdjustmentListResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/tax-lots/{id}/adjustments [get]
+func (h *CostBasisHandlers) ListTaxLotAdjustments(c *gin.Context) {
+	lotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid tax lot ID", nil)
+		return
+	}
+	adjustments, err := h.service.ListAdjustments(c.Request.Context(), lotID)
+	if err != nil {
+		h.respondCostBasisError(c, err, "Failed to list tax lot adjustments")
+		return
+	}
+	if adjustments == nil {
+		adjustments = []*entities.TaxLotAdjustment{}
+	}
+	c.JSON(http.StatusOK, TaxLotAdjustmentListResponse{Adjustments: adjustments})
+}
+
+func (h *CostBasisHandlers) listLots(c *gin.Context, userID uuid.UUID) {
+	lots, err := h.service.ListLots(c.Request.Context(), userID)
+	if err != nil {
+		h.respondCostBasisError(c, err, "Failed to list tax lots")
+		return
+	}
+	if lots == nil {
+		lots = []*entities.TaxLot{}
+	}
+	c.JSON(http.StatusOK, TaxLotListResponse{Lots: lots})
+}
+
+func (h *CostBasisHandlers) importParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid import ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+func (h *CostBasisHandlers) respondCostBasisError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrCostBasisImportNotFound):
+		respondNotFound(c, "Cost basis import not found")
+	case errors.Is(err, entities.ErrTaxLotNotFound):
+		respondNotFound(c, "Tax lot not found")
+	case errors.Is(err, costbasis.ErrInvalidFile), errors.Is(err, costbasis.ErrInvalidAdjustment):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, costbasis.ErrImportNotPending):
+		respondError(c, http.StatusConflict, "IMPORT_NOT_PENDING", err.Error(), nil)
+	case errors.Is(err, costbasis.ErrImportHasErrors):
+		respondError(c, http.StatusUnprocessableEntity, "IMPORT_HAS_ERRORS", err.Error(), nil)
+	case errors.Is(err, costbasis.ErrNoLots):
+		respondError(c, http.StatusUnprocessableEntity, "IMPORT_HAS_NO_LOTS", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
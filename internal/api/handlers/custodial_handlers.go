@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/custodial"
+	"go.uber.org/zap"
+)
+
+// CustodialHandlers exposes guardian-managed accounts for minors
+type CustodialHandlers struct {
+	custodialService *custodial.Service
+	logger           *zap.Logger
+}
+
+// NewCustodialHandlers creates a new custodial handlers instance
+func NewCustodialHandlers(custodialService *custodial.Service, logger *zap.Logger) *CustodialHandlers {
+	return &CustodialHandlers{
+		custodialService: custodialService,
+		logger:           logger,
+	}
+}
+
+// CreateCustodialAccount handles POST /api/v1/custodial-accounts
+// @Summary Open a custodial account for a minor
+// @Description The authenticated user must be an adult with approved KYC and becomes the guardian.
+// @Tags custodial
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateCustodialAccountRequest true "Beneficiary details"
+// @Success 201 {object} entities.CustodialAccount
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/custodial-accounts [post]
+func (h *CustodialHandlers) CreateCustodialAccount(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateCustodialAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	account, err := h.custodialService.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondCustodialError(c, err, "Failed to open custodial account")
+		return
+	}
+	c.JSON(http.StatusCreated, account)
+}
+
+// ListCustodialAccounts handles GET /api/v1/custodial-accounts
+// @Summary List custodial accounts managed by the user
+// @Tags custodial
+// @Produce json
+// @Success 200 {object} handlers.CustodialAccountListResponse
+// @Security BearerAuth
+// @Router /api/v1/custodial-accounts [get]
+func (h *CustodialHandlers) ListCustodialAccounts(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	accounts, err := h.custodialService.ListForGuardian(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list custodial accounts", zap.Error(err))
+		respondInternalError(c, "Failed to list custodial accounts")
+		return
+	}
+	c.JSON(http.StatusOK, CustodialAccountListResponse{Accounts: accounts})
+}
+
+// GetCustodialAccount handles GET /api/v1/custodial-accounts/:id
+// @Summary Get a custodial account
+// @Tags custodial
+// @Produce json
+// @Param id path string true "Custodial account ID"
+// @Success 200 {object} entities.CustodialAccount
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/custodial-accounts/{id} [get]
+func (h *CustodialHandlers) GetCustodialAccount(c *gin.Context) {
+	userID, accountID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.custodialService.Get(c.Request.Context(), userID, accountID)
+	if err != nil {
+		h.respondCustodialError(c, err, "Failed to get custodial account")
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// InitiateTransfer handles POST /api/v1/custodial-accounts/:id/transfer
+// @Summary Start transfer of ownership to the beneficiary
+// @Description Available once the beneficiary reaches majority. The beneficiary claims the account from their own login.
+// @Tags custodial
+// @Accept json
+// @Produce json
+// @Param id path string true "Custodial account ID"
+// @Param request body entities.InitiateCustodialTransferRequest true "Beneficiary email"
+// @Success 200 {object} entities.CustodialAccount
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/custodial-accounts/{id}/transfer [post]
+func (h *CustodialHandlers) InitiateTransfer(c *gin.Context) {
+	userID, accountID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req entities.InitiateCustodialTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	account, err := h.custodialService.InitiateTransfer(c.Request.Context(), userID, accountID, req.BeneficiaryEmail)
+	if err != nil {
+		h.respondCustodialError(c, err, "Failed to initiate transfer")
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// ListPendingClaims handles GET /api/v1/custodial-accounts/claims
+// @Summary List custodial accounts awaiting the user's claim
+// @Tags custodial
+// @Produce json
+// @Success 200 {object} handlers.CustodialAccountListResponse
+// @Security BearerAuth
+// @Router /api/v1/custodial-accounts/claims [get]
+func (h *CustodialHandlers) ListPendingClaims(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	accounts, err := h.custodialService.PendingClaims(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list custodial claims", zap.Error(err))
+		respondInternalError(c, "Failed to list custodial claims")
+		return
+	}
+	c.JSON(http.StatusOK, CustodialAccountListResponse{Accounts: accounts})
+}
+
+// ClaimCustodialAccount handles POST /api/v1/custodial-accounts/:id/claim
+// @Summary Claim a custodial account at majority
+// @Tags custodial
+// @Produce json
+// @Param id path string true "Custodial account ID"
+// @Success 200 {object} entities.CustodialAccount
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 422 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/custodial-accounts/{id}/claim [post]
+func (h *CustodialHandlers) ClaimCustodialAccount(c *gin.Context) {
+	userID, accountID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.custodialService.CompleteTransfer(c.Request.Context(), userID, accountID)
+	if err != nil {
+		h.respondCustodialError(c, err, "Failed to claim custodial account")
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *CustodialHandlers) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid custodial account ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, accountID, true
+}
+
+func (h *CustodialHandlers) respondCustodialError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrCustodialNotFound):
+		respondNotFound(c, "Custodial account not found")
+	case errors.Is(err, custodial.ErrNotPermitted):
+		respondError(c, http.StatusForbidden, "FORBIDDEN", err.Error(), nil)
+	case errors.Is(err, entities.ErrUnderage), errors.Is(err, entities.ErrDateOfBirthRequired):
+		respondError(c, http.StatusForbidden, "NOT_ELIGIBLE", err.Error(), nil)
+	case errors.Is(err, entities.ErrNotYetMajority):
+		respondError(c, http.StatusConflict, "NOT_YET_MAJORITY", err.Error(), nil)
+	case errors.Is(err, custodial.ErrIneligible):
+		respondError(c, http.StatusUnprocessableEntity, "NOT_ELIGIBLE", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
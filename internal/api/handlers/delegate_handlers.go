@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/api/middleware"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/delegates"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// DelegateHandlers serve delegated account access: the account holder's
+// grants, and the separate read-only surface delegates sign in to
+type DelegateHandlers struct {
+	service      *delegates.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewDelegateHandlers creates a new delegate handlers instance
+func NewDelegateHandlers(service *delegates.Service, auditService *adapters.AuditService, logger *zap.Logger) *DelegateHandlers {
+	return &DelegateHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// CreateDelegateGrant handles POST /api/v1/delegates
+// @Summary Grant read-only access to a delegate
+// @Description Lets an advisor or family member view the portfolio and statements, never profile details or withdrawals. The token in the response is shown once; give it to the delegate with the email it was issued to so they can sign in.
+// @Tags delegates
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateDelegateGrantRequest true "Delegate and scopes"
+// @Success 201 {object} entities.CreateDelegateGrantResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/delegates [post]
+func (h *DelegateHandlers) CreateDelegateGrant(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateDelegateGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	created, err := h.service.Grant(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to grant delegate access")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &userID, "delegate_access_granted", "delegate_grant", nil, map[string]interface{}{
+		"grant_id":     created.Grant.ID.String(),
+		"relationship": string(created.Grant.Relationship),
+		"scopes":       created.Grant.Scopes,
+		"expires_at":   created.Grant.ExpiresAt,
+	})
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListDelegateGrants handles GET /api/v1/delegates
+// @Summary List delegate access grants
+// @Tags delegates
+// @Produce json
+// @Success 200 {object} handlers.DelegateGrantListResponse
+// @Security BearerAuth
+// @Router /api/v1/delegates [get]
+func (h *DelegateHandlers) ListDelegateGrants(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	grants, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to list delegate access")
+		return
+	}
+	c.JSON(http.StatusOK, DelegateGrantListResponse{Grants: grants})
+}
+
+// RevokeDelegateGrant handles DELETE /api/v1/delegates/:id
+// @Summary Revoke delegate access
+// @Description Ends the grant and signs the delegate out immediately.
+// @Tags delegates
+// @Produce json
+// @Param id path string true "Grant ID"
+// @Success 200 {object} entities.DelegateGrant
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/delegates/{id} [delete]
+func (h *DelegateHandlers) RevokeDelegateGrant(c *gin.Context) {
+	userID, id, ok := h.userAndGrantID(c)
+	if !ok {
+		return
+	}
+
+	grant, err := h.service.Revoke(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to revoke delegate access")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &userID, "delegate_access_revoked", "delegate_grant", nil, map[string]interface{}{
+		"grant_id": grant.ID.String(),
+	})
+	c.JSON(http.StatusOK, grant)
+}
+
+// GetDelegateAccessLog handles GET /api/v1/delegates/:id/access-log
+// @Summary List what a delegate viewed
+// @Description Every sign-in and every view of the portfolio or a statement, newest first.
+// @Tags delegates
+// @Produce json
+// @Param id path string true "Grant ID"
+// @Param limit query int false "Maximum events (default 100, max 500)"
+// @Success 200 {object} handlers.DelegateAccessLogResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/delegates/{id}/access-log [get]
+func (h *DelegateHandlers) GetDelegateAccessLog(c *gin.Context) {
+	userID, id, ok := h.userAndGrantID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	events, err := h.service.AccessLog(c.Request.Context(), userID, id, limit)
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to list delegate access")
+		return
+	}
+	c.JSON(http.StatusOK, DelegateAccessLogResponse{Events: events})
+}
+
+// DelegateLogin handles POST /api/v1/delegate/login
+// @Summary Sign in as a delegate
+// @Description Exchanges a grant token and the email it was issued to for a short-lived session token. The session token only works on the /api/v1/delegate endpoints.
+// @Tags delegates
+// @Accept json
+// @Produce json
+// @Param request body entities.DelegateLoginRequest true "Delegate credentials"
+// @Success 200 {object} entities.DelegateLoginResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Router /api/v1/delegate/login [post]
+func (h *DelegateHandlers) DelegateLogin(c *gin.Context) {
+	var req entities.DelegateLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.Login(c.Request.Context(), &req, viewerOf(c))
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to sign in")
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// GetDelegatePortfolio handles GET /api/v1/delegate/portfolio
+// @Summary View the shared portfolio
+// @Tags delegates
+// @Produce json
+// @Success 200 {object} entities.Portfolio
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse
+// @Security DelegateAuth
+// @Router /api/v1/delegate/portfolio [get]
+func (h *DelegateHandlers) GetDelegatePortfolio(c *gin.Context) {
+	grant, ok := delegateGrant(c)
+	if !ok {
+		return
+	}
+
+	portfolio, err := h.service.Portfolio(c.Request.Context(), grant, viewerOf(c))
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to get portfolio")
+		return
+	}
+	c.JSON(http.StatusOK, portfolio)
+}
+
+// ListDelegateStatements handles GET /api/v1/delegate/statements
+// @Summary List the shared statements
+// @Description Trade confirmations of the account holder, newest first.
+// @Tags delegates
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.DocumentListResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse
+// @Security DelegateAuth
+// @Router /api/v1/delegate/statements [get]
+func (h *DelegateHandlers) ListDelegateStatements(c *gin.Context) {
+	grant, ok := delegateGrant(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	statements, err := h.service.Statements(c.Request.Context(), grant, limit, offset, viewerOf(c))
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to list statements")
+		return
+	}
+	c.JSON(http.StatusOK, DocumentListResponse{Documents: statements})
+}
+
+// GetDelegateStatement handles GET /api/v1/delegate/statements/:id
+// @Summary View a shared statement
+// @Tags delegates
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} entities.Document
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security DelegateAuth
+// @Router /api/v1/delegate/statements/{id} [get]
+func (h *DelegateHandlers) GetDelegateStatement(c *gin.Context) {
+	grant, ok := delegateGrant(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid statement ID", nil)
+		return
+	}
+
+	statement, err := h.service.Statement(c.Request.Context(), grant, id, viewerOf(c))
+	if err != nil {
+		h.respondDelegateError(c, err, "Failed to get statement")
+		return
+	}
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *DelegateHandlers) userAndGrantID(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid grant ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+func (h *DelegateHandlers) respondDelegateError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrDelegateGrantNotFound):
+		respondNotFound(c, "Delegate access grant not found")
+	case errors.Is(err, entities.ErrDocumentNotFound):
+		respondNotFound(c, "Statement not found")
+	case errors.Is(err, delegates.ErrInvalidScope):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, delegates.ErrTooManyGrants):
+		respondError(c, http.StatusConflict, "TOO_MANY_DELEGATES", err.Error(), nil)
+	case errors.Is(err, delegates.ErrGrantNotActive):
+		respondError(c, http.StatusConflict, "DELEGATE_GRANT_INACTIVE", err.Error(), nil)
+	case errors.Is(err, delegates.ErrInvalidCredentials):
+		respondUnauthorized(c, err.Error())
+	case errors.Is(err, delegates.ErrScopeNotGranted):
+		respondError(c, http.StatusForbidden, "SCOPE_NOT_GRANTED", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
+
+func delegateGrant(c *gin.Context) (*entities.DelegateGrant, bool) {
+	grant, ok := c.Get(middleware.DelegateGrantKey)
+	if !ok {
+		respondUnauthorized(c, "Delegate session required")
+		return nil, false
+	}
+	return grant.(*entities.DelegateGrant), true
+}
+
+func viewerOf(c *gin.Context) delegates.Viewer {
+	return delegates.Viewer{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
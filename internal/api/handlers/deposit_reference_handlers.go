@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/depositref"
+	"github.com/stack-service/stack_service/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+// bankCreditSignatureHeader carries the hex HMAC-SHA256 of a bank credit
+// notification's body
+const bankCreditSignatureHeader = "X-Webhook-Signature"
+
+// DepositReferenceHandlers serve funding instructions for shared deposit
+// accounts and receive their credits
+type DepositReferenceHandlers struct {
+	service       *depositref.Service
+	webhookSecret string
+	logger        *zap.Logger
+}
+
+// NewDepositReferenceHandlers creates new deposit reference handlers.
+// Credit notifications must be signed with webhookSecret when it is set.
+func NewDepositReferenceHandlers(service *depositref.Service, webhookSecret string, logger *zap.Logger) *DepositReferenceHandlers {
+	return &DepositReferenceHandlers{
+		service:       service,
+		webhookSecret: webhookSecret,
+		logger:        logger,
+	}
+}
+
+// GetFundingInstructions handles GET /api/v1/funding/instructions
+// @Summary Get bank transfer instructions
+// @Description Returns the account to send a bank transfer to in a currency. The account is shared, so the user's reference must be included in the transfer memo for the deposit to be credited automatically.
+// @Tags funding
+// @Produce json
+// @Param currency query string false "Currency of the transfer (default USD)"
+// @Success 200 {object} entities.FundingInstructions
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/funding/instructions [get]
+func (h *DepositReferenceHandlers) GetFundingInstructions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	instructions, err := h.service.GetFundingInstructions(c.Request.Context(), userID, c.DefaultQuery("currency", "USD"))
+	if err != nil {
+		h.respondDepositReferenceError(c, err, "Failed to get funding instructions")
+		return
+	}
+	c.JSON(http.StatusOK, instructions)
+}
+
+// BankCreditWebhook handles POST /api/v1/webhooks/bank-credit
+// @Summary Shared account credit webhook
+// @Description Notifies a transfer received into a shared deposit account. The credit is attributed by the reference in its memo or held for manual matching. Redeliveries are idempotent on provider_ref.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param X-Webhook-Signature header string false "Hex HMAC-SHA256 of the body, when a secret is configured"
+// @Param request body entities.BankCreditWebhook true "Bank credit notification"
+// @Success 200 {object} entities.BankCredit
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Router /api/v1/webhooks/bank-credit [post]
+func (h *DepositReferenceHandlers) BankCreditWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondBadRequest(c, "Invalid webhook payload", nil)
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if h.webhookSecret != "" && !webhook.VerifySignature(body, c.GetHeader(bankCreditSignatureHeader), h.webhookSecret) {
+		respondUnauthorized(c, "Invalid webhook signature")
+		return
+	}
+
+	var notice entities.BankCreditWebhook
+	if err := c.ShouldBindJSON(&notice); err != nil {
+		respondBadRequest(c, "Invalid webhook payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	markWebhookVerified(c, "bank_credit", notice.ProviderRef)
+
+	credit, err := h.service.ReceiveCredit(c.Request.Context(), &notice)
+	if err != nil {
+		h.respondDepositReferenceError(c, err, "Failed to process bank credit")
+		return
+	}
+	c.JSON(http.StatusOK, credit)
+}
+
+func (h *DepositReferenceHandlers) respondDepositReferenceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrNoSharedDepositAccount):
+		respondNotFound(c, "Bank transfers are not available in "+strings.ToUpper(c.DefaultQuery("currency", "USD")))
+	case errors.Is(err, depositref.ErrInvalidAmount):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/apikey"
+	"github.com/stack-service/stack_service/internal/domain/services/developer"
+	"github.com/stack-service/stack_service/internal/domain/services/outboundwebhook"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// DeveloperHandlers serve the developer portal: a partner's sandbox tenant,
+// the API keys and webhook endpoints they manage for it, and key usage
+type DeveloperHandlers struct {
+	service      *developer.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewDeveloperHandlers creates a new developer handlers instance
+func NewDeveloperHandlers(service *developer.Service, auditService *adapters.AuditService, logger *zap.Logger) *DeveloperHandlers {
+	return &DeveloperHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// CreateDeveloperTenant handles POST /api/v1/developer/tenant
+// @Summary Join the developer program
+// @Description Creates the user's sandbox tenant. Keys and webhook endpoints are created under it.
+// @Tags developer
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateDeveloperTenantRequest true "Tenant"
+// @Success 201 {object} entities.DeveloperTenant
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse "The user already has a tenant"
+// @Security BearerAuth
+// @Router /api/v1/developer/tenant [post]
+func (h *DeveloperHandlers) CreateDeveloperTenant(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateDeveloperTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	tenant, err := h.service.CreateTenant(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to create developer tenant")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "create_developer_tenant", "developer_tenant", nil, map[string]interface{}{
+		"tenant_id": tenant.ID.String(),
+	})
+	c.JSON(http.StatusCreated, tenant)
+}
+
+// GetDeveloperTenant handles GET /api/v1/developer/tenant
+// @Summary Get the developer tenant
+// @Tags developer
+// @Produce json
+// @Success 200 {object} entities.DeveloperTenant
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/developer/tenant [get]
+func (h *DeveloperHandlers) GetDeveloperTenant(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	tenant, err := h.service.GetTenant(c.Request.Context(), userID)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to get developer tenant")
+		return
+	}
+	c.JSON(http.StatusOK, tenant)
+}
+
+// ListDeveloperScopes handles GET /api/v1/developer/scopes
+// @Summary List developer key scopes
+// @Description Returns the scopes sandbox keys can be issued with
+// @Tags developer
+// @Produce json
+// @Success 200 {object} handlers.DeveloperScopeListResponse
+// @Security BearerAuth
+// @Router /api/v1/developer/scopes [get]
+func (h *DeveloperHandlers) ListDeveloperScopes(c *gin.Context) {
+	c.JSON(http.StatusOK, DeveloperScopeListResponse{Scopes: developer.Scopes})
+}
+
+// IssueDeveloperKey handles POST /api/v1/developer/keys
+// @Summary Issue a sandbox API key
+// @Description Issues an API key for the tenant's sandbox with the requested scopes. The key is only returned in this response. Sandbox keys start with sk_test_ and are rejected by the live API.
+// @Tags developer
+// @Accept json
+// @Produce json
+// @Param request body entities.IssueDeveloperKeyRequest true "Key"
+// @Success 201 {object} apikey.CreateAPIKeyResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse "No developer tenant"
+// @Failure 409 {object} entities.ErrorResponse "Key limit reached"
+// @Security BearerAuth
+// @Router /api/v1/developer/keys [post]
+func (h *DeveloperHandlers) IssueDeveloperKey(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.IssueDeveloperKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	issued, err := h.service.IssueKey(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to issue developer key")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "issue_developer_key", "api_key", nil, map[string]interface{}{
+		"key_id": issued.APIKey.ID.String(),
+		"scopes": issued.APIKey.Scopes,
+	})
+	c.JSON(http.StatusCreated, issued)
+}
+
+// ListDeveloperKeys handles GET /api/v1/developer/keys
+// @Summary List sandbox API keys
+// @Tags developer
+// @Produce json
+// @Success 200 {object} handlers.DeveloperKeyListResponse
+// @Failure 404 {object} entities.ErrorResponse "No developer tenant"
+// @Security BearerAuth
+// @Router /api/v1/developer/keys [get]
+func (h *DeveloperHandlers) ListDeveloperKeys(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	keys, err := h.service.ListKeys(c.Request.Context(), userID)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to list developer keys")
+		return
+	}
+	if keys == nil {
+		keys = []*apikey.APIKey{}
+	}
+	c.JSON(http.StatusOK, DeveloperKeyListResponse{Keys: keys})
+}
+
+// RevokeDeveloperKey handles DELETE /api/v1/developer/keys/:id
+// @Summary Revoke a sandbox API key
+// @Tags developer
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/developer/keys/{id} [delete]
+func (h *DeveloperHandlers) RevokeDeveloperKey(c *gin.Context) {
+	userID, keyID, ok := h.idParams(c, "Invalid API key ID")
+	if !ok {
+		return
+	}
+	if err := h.service.RevokeKey(c.Request.Context(), userID, keyID); err != nil {
+		h.respondDeveloperError(c, err, "Failed to revoke developer key")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "revoke_developer_key", "api_key", nil, map[string]interface{}{
+		"key_id": keyID.String(),
+	})
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeveloperKeyUsage handles GET /api/v1/developer/keys/:id/usage
+// @Summary Get a sandbox API key's usage
+// @Description Returns the key's request counts, error rates and latency per endpoint, busiest first. Usage is rolled up hourly and written about once a minute.
+// @Tags developer
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param since query string false "Start of the window, RFC 3339 (default 24 hours before until)"
+// @Param until query string false "End of the window, RFC 3339 (default now)"
+// @Success 200 {object} entities.APIUsageReport
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/developer/keys/{id}/usage [get]
+func (h *DeveloperHandlers) GetDeveloperKeyUsage(c *gin.Context) {
+	userID, keyID, ok := h.idParams(c, "Invalid API key ID")
+	if !ok {
+		return
+	}
+	var since, until time.Time
+	for param, target := range map[string]*time.Time{"since": &since, "until": &until} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid "+param+" time, expected RFC 3339", nil)
+			return
+		}
+		*target = parsed
+	}
+
+	report, err := h.service.KeyUsage(c.Request.Context(), userID, keyID, since, until)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to get developer key usage")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterDeveloperWebhook handles POST /api/v1/developer/webhooks
+// @Summary Register a sandbox webhook endpoint
+// @Description Registers an endpoint for the tenant's sandbox and returns its signing secret once. Sandbox endpoints receive test events only, never production events.
+// @Tags developer
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateWebhookEndpointRequest true "Endpoint"
+// @Success 201 {object} entities.WebhookEndpointWithSecret
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse "No developer tenant"
+// @Failure 409 {object} entities.ErrorResponse "Endpoint limit reached"
+// @Security BearerAuth
+// @Router /api/v1/developer/webhooks [post]
+func (h *DeveloperHandlers) RegisterDeveloperWebhook(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.service.RegisterWebhook(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to register developer webhook")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "register_developer_webhook", "webhook_endpoint", nil, map[string]interface{}{
+		"endpoint_id": endpoint.ID.String(),
+		"url":         endpoint.URL,
+	})
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// ListDeveloperWebhooks handles GET /api/v1/developer/webhooks
+// @Summary List sandbox webhook endpoints
+// @Tags developer
+// @Produce json
+// @Success 200 {object} handlers.WebhookEndpointListResponse
+// @Failure 404 {object} entities.ErrorResponse "No developer tenant"
+// @Security BearerAuth
+// @Router /api/v1/developer/webhooks [get]
+func (h *DeveloperHandlers) ListDeveloperWebhooks(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	endpoints, err := h.service.ListWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to list developer webhooks")
+		return
+	}
+	if endpoints == nil {
+		endpoints = []*entities.WebhookEndpoint{}
+	}
+	c.JSON(http.StatusOK, WebhookEndpointListResponse{Endpoints: endpoints})
+}
+
+// DeleteDeveloperWebhook handles DELETE /api/v1/developer/webhooks/:id
+// @Summary Delete a sandbox webhook endpoint
+// @Tags developer
+// @Param id path string true "Endpoint ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/developer/webhooks/{id} [delete]
+func (h *DeveloperHandlers) DeleteDeveloperWebhook(c *gin.Context) {
+	userID, endpointID, ok := h.idParams(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteWebhook(c.Request.Context(), userID, endpointID); err != nil {
+		h.respondDeveloperError(c, err, "Failed to delete developer webhook")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "delete_developer_webhook", "webhook_endpoint", nil, map[string]interface{}{
+		"endpoint_id": endpointID.String(),
+	})
+	c.Status(http.StatusNoContent)
+}
+
+// TestDeveloperWebhook handles POST /api/v1/developer/webhooks/:id/test
+// @Summary Send a test event to a sandbox webhook endpoint
+// @Description Queues a signed webhook.ping event for the endpoint
+// @Tags developer
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Success 202 {object} entities.WebhookDelivery
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/developer/webhooks/{id}/test [post]
+func (h *DeveloperHandlers) TestDeveloperWebhook(c *gin.Context) {
+	userID, endpointID, ok := h.idParams(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+	delivery, err := h.service.TestWebhook(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		h.respondDeveloperError(c, err, "Failed to send test event")
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// GetSandboxIdentity handles GET /api/v1/developer/sandbox/whoami
+// @Summary Check a sandbox API key
+// @Description Authenticated with a sandbox key in the X-API-Key header. Returns the key, tenant and scopes the request was made with, so integrations can confirm their credentials.
+// @Tags developer
+// @Produce json
+// @Param X-API-Key header string true "Sandbox API key"
+// @Success 200 {object} handlers.SandboxIdentityResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse "Not a sandbox key"
+// @Router /api/v1/developer/sandbox/whoami [get]
+func (h *DeveloperHandlers) GetSandboxIdentity(c *gin.Context) {
+	keyID, _ := c.Value("api_key_id").(uuid.UUID)
+	tenantID, _ := c.Value("developer_tenant_id").(uuid.UUID)
+	scopes := c.GetStringSlice("api_key_scopes")
+	if scopes == nil {
+		scopes = []string{}
+	}
+	c.JSON(http.StatusOK, SandboxIdentityResponse{
+		APIKeyID:    keyID,
+		TenantID:    tenantID,
+		Environment: c.GetString("api_key_environment"),
+		Scopes:      scopes,
+	})
+}
+
+func (h *DeveloperHandlers) idParams(c *gin.Context, invalid string) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, invalid, nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+func (h *DeveloperHandlers) respondDeveloperError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrDeveloperTenantNotFound):
+		respondError(c, http.StatusNotFound, "DEVELOPER_TENANT_NOT_FOUND", "Join the developer program before creating keys or webhooks", nil)
+	case errors.Is(err, entities.ErrDeveloperTenantExists):
+		respondError(c, http.StatusConflict, "DEVELOPER_TENANT_EXISTS", err.Error(), nil)
+	case errors.Is(err, developer.ErrKeyNotFound):
+		respondNotFound(c, "API key not found")
+	case errors.Is(err, entities.ErrWebhookEndpointNotFound):
+		respondNotFound(c, "Webhook endpoint not found")
+	case errors.Is(err, developer.ErrScopeNotAllowed), errors.Is(err, developer.ErrInvalidKeyRequest),
+		errors.Is(err, entities.ErrInvalidAPIUsageFilter),
+		errors.Is(err, outboundwebhook.ErrInvalidEndpoint), errors.Is(err, outboundwebhook.ErrUnknownEventType):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, developer.ErrKeyLimitReached):
+		respondError(c, http.StatusConflict, "DEVELOPER_KEY_LIMIT", err.Error(), nil)
+	case errors.Is(err, developer.ErrWebhookLimitReached):
+		respondError(c, http.StatusConflict, "DEVELOPER_WEBHOOK_LIMIT", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
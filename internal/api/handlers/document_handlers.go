@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/documents"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// DocumentHandlers serve the in-app document center and let admins publish
+// disclosures to it
+type DocumentHandlers struct {
+	service      *documents.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewDocumentHandlers creates a new document handlers instance
+func NewDocumentHandlers(service *documents.Service, auditService *adapters.AuditService, logger *zap.Logger) *DocumentHandlers {
+	return &DocumentHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// DocumentListResponse lists document center entries
+type DocumentListResponse struct {
+	Documents []*entities.Document `json:"documents"`
+}
+
+// DocumentReceiptListResponse lists who has opened a document
+type DocumentReceiptListResponse struct {
+	Receipts []*entities.DocumentReceipt `json:"receipts"`
+}
+
+// ListDocuments handles GET /api/v1/documents
+// @Summary List the user's documents
+// @Description Lists trade confirmations, prospectus links, fee disclosures and agreements, newest first. Bodies are left out; fetch a document to read it.
+// @Tags documents
+// @Produce json
+// @Param category query string false "Filter by category (trade_confirmation, prospectus, fee_disclosure, agreement, disclosure)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} handlers.DocumentListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/documents [get]
+func (h *DocumentHandlers) ListDocuments(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.service.List(c.Request.Context(), userID, entities.DocumentFilter{
+		Category: entities.DocumentCategory(c.Query("category")),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list documents")
+		return
+	}
+	c.JSON(http.StatusOK, DocumentListResponse{Documents: list})
+}
+
+// ListPendingDocuments handles GET /api/v1/documents/pending
+// @Summary List documents awaiting the user
+// @Description Mandatory documents the user has not opened, and agreement versions they have not accepted
+// @Tags documents
+// @Produce json
+// @Success 200 {object} handlers.DocumentListResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/documents/pending [get]
+func (h *DocumentHandlers) ListPendingDocuments(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	pending, err := h.service.Pending(c.Request.Context(), userID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list pending documents")
+		return
+	}
+	c.JSON(http.StatusOK, DocumentListResponse{Documents: pending})
+}
+
+// GetDocument handles GET /api/v1/documents/{id}
+// @Summary Get a document
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} entities.Document
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/documents/{id} [get]
+func (h *DocumentHandlers) GetDocument(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid document ID", nil)
+		return
+	}
+
+	document, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get document")
+		return
+	}
+	c.JSON(http.StatusOK, document)
+}
+
+// MarkDocumentRead handles POST /api/v1/documents/{id}/read
+// @Summary Record that the user opened a document
+// @Description Stores a read receipt with the client's IP address and user agent. Repeated reads return the first receipt. Agreements are acknowledged by accepting them under /consents.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID"
+// @Success 200 {object} entities.DocumentReceipt
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/read [post]
+func (h *DocumentHandlers) MarkDocumentRead(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid document ID", nil)
+		return
+	}
+
+	receipt, err := h.service.MarkRead(c.Request.Context(), userID, id, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to record read receipt")
+		return
+	}
+	c.JSON(http.StatusOK, receipt)
+}
+
+// ListPublishedDocuments handles GET /api/v1/admin/documents
+// @Summary List documents published to every user
+// @Tags admin
+// @Produce json
+// @Param category query string false "Filter by category"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} handlers.DocumentListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/documents [get]
+func (h *DocumentHandlers) ListPublishedDocuments(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.service.ListPublished(c.Request.Context(), entities.DocumentFilter{
+		Category: entities.DocumentCategory(c.Query("category")),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list documents")
+		return
+	}
+	c.JSON(http.StatusOK, DocumentListResponse{Documents: list})
+}
+
+// PublishDocument handles POST /api/v1/admin/documents
+// @Summary Publish a document
+// @Description Publishes a prospectus, fee disclosure or disclosure link to every user, or to one user when user_id is set. Mandatory documents stay pending until opened.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.PublishDocumentRequest true "Document"
+// @Success 201 {object} entities.Document
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/documents [post]
+func (h *DocumentHandlers) PublishDocument(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.PublishDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	document, err := h.service.Publish(c.Request.Context(), &req, adminID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to publish document")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "document_publish", "documents", nil, map[string]interface{}{
+		"id":        document.ID,
+		"category":  document.Category,
+		"title":     document.Title,
+		"mandatory": document.Mandatory,
+		"user_id":   document.UserID,
+	})
+	c.JSON(http.StatusCreated, document)
+}
+
+// ListDocumentReceipts handles GET /api/v1/admin/documents/{id}/receipts
+// @Summary List a document's read receipts
+// @Tags admin
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} handlers.DocumentReceiptListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/documents/{id}/receipts [get]
+func (h *DocumentHandlers) ListDocumentReceipts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid document ID", nil)
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	receipts, err := h.service.ListReceipts(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list read receipts")
+		return
+	}
+	c.JSON(http.StatusOK, DocumentReceiptListResponse{Receipts: receipts})
+}
+
+func (h *DocumentHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrDocumentNotFound):
+		respondNotFound(c, "Document not found")
+	case errors.Is(err, entities.ErrInvalidDocument):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrOutOfRegion):
+		respondError(c, http.StatusConflict, "OUT_OF_REGION", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
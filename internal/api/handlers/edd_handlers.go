@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/edd"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// EDDHandlers expose enhanced due diligence for higher limits and its
+// compliance review queue
+type EDDHandlers struct {
+	service      *edd.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewEDDHandlers creates a new enhanced due diligence handlers instance
+func NewEDDHandlers(service *edd.Service, auditService *adapters.AuditService, logger *zap.Logger) *EDDHandlers {
+	return &EDDHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// EDDSubmissionListResponse is a page of enhanced due diligence submissions
+type EDDSubmissionListResponse struct {
+	Submissions []*entities.EDDSubmission `json:"submissions"`
+}
+
+// GetEDDStatus handles GET /api/v1/kyc/edd
+// @Summary Get higher limit eligibility
+// @Description Returns whether the user can apply for higher limits, their current KYC tier and their latest enhanced due diligence submission
+// @Tags onboarding
+// @Produce json
+// @Success 200 {object} entities.EDDOverview
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kyc/edd [get]
+func (h *EDDHandlers) GetEDDStatus(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	overview, err := h.service.Overview(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get EDD status", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get higher limit status")
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// SubmitEDD handles POST /api/v1/kyc/edd
+// @Summary Apply for higher limits
+// @Description Submits proof-of-funds documents for enhanced due diligence. At least one proof_of_funds document is required; bank_statement and source_of_wealth documents are also accepted. Returns the submission awaiting review if one exists.
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param request body entities.SubmitEDDRequest true "Source of funds and documents"
+// @Success 202 {object} entities.EDDSubmission
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse "Not available for this account"
+// @Failure 409 {object} entities.ErrorResponse "Already on the enhanced tier"
+// @Security BearerAuth
+// @Router /api/v1/kyc/edd [post]
+func (h *EDDHandlers) SubmitEDD(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.SubmitEDDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	submission, err := h.service.Submit(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidEDDDocument), errors.Is(err, entities.ErrEDDProofOfFunds):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, entities.ErrEDDUnavailable), errors.Is(err, entities.ErrEDDRequiresKYC):
+			respondError(c, http.StatusForbidden, "EDD_UNAVAILABLE", err.Error(), nil)
+		case errors.Is(err, entities.ErrEDDAlreadyEnhanced):
+			respondError(c, http.StatusConflict, "ALREADY_ENHANCED", err.Error(), nil)
+		default:
+			if respondUploadReferenceError(c, err) {
+				return
+			}
+			h.logger.Error("Failed to submit EDD", zap.String("user_id", userID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to submit documents")
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, submission)
+}
+
+// ListEDDSubmissions handles GET /api/v1/admin/edd
+// @Summary List enhanced due diligence submissions
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status (pending_review, approved, rejected)"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.EDDSubmissionListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/edd [get]
+func (h *EDDHandlers) ListEDDSubmissions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.service.List(c.Request.Context(), entities.EDDStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list EDD submissions", zap.Error(err))
+		respondInternalError(c, "Failed to list submissions")
+		return
+	}
+	c.JSON(http.StatusOK, EDDSubmissionListResponse{Submissions: list})
+}
+
+// GetEDDSubmission handles GET /api/v1/admin/edd/:id
+// @Summary Get an enhanced due diligence submission
+// @Tags admin
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 200 {object} entities.EDDSubmission
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/edd/{id} [get]
+func (h *EDDHandlers) GetEDDSubmission(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid submission ID", nil)
+		return
+	}
+
+	submission, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondReviewError(c, err, "Failed to get submission")
+		return
+	}
+	c.JSON(http.StatusOK, submission)
+}
+
+// ApproveEDD handles POST /api/v1/admin/edd/:id/approve
+// @Summary Approve higher limits
+// @Description Moves the user to the enhanced KYC tier, raises their transaction limits and resolves the compliance case
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param request body entities.ReviewEDDRequest false "Review notes"
+// @Success 200 {object} entities.EDDSubmission
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/edd/{id}/approve [post]
+func (h *EDDHandlers) ApproveEDD(c *gin.Context) {
+	h.review(c, h.service.Approve, "edd_approve", "Failed to approve submission")
+}
+
+// RejectEDD handles POST /api/v1/admin/edd/:id/reject
+// @Summary Reject higher limits
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param request body entities.ReviewEDDRequest false "Review notes"
+// @Success 200 {object} entities.EDDSubmission
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/edd/{id}/reject [post]
+func (h *EDDHandlers) RejectEDD(c *gin.Context) {
+	h.review(c, h.service.Reject, "edd_reject", "Failed to reject submission")
+}
+
+func (h *EDDHandlers) review(c *gin.Context, decide func(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.EDDSubmission, error), action, failure string) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid submission ID", nil)
+		return
+	}
+
+	var req entities.ReviewEDDRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
+	submission, err := decide(c.Request.Context(), id, adminID, req.Notes)
+	if err != nil {
+		h.respondReviewError(c, err, failure)
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, action, "edd_submission", nil, map[string]interface{}{
+		"submission_id": submission.ID.String(),
+		"user_id":       submission.UserID.String(),
+		"status":        string(submission.Status),
+	})
+	c.JSON(http.StatusOK, submission)
+}
+
+func (h *EDDHandlers) respondReviewError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, entities.ErrEDDSubmissionNotFound):
+		respondNotFound(c, "Submission not found")
+	case errors.Is(err, entities.ErrEDDNotPending):
+		respondError(c, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+	default:
+		h.logger.Error(failure, zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
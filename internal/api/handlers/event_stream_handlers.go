@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/eventstream"
+	"go.uber.org/zap"
+)
+
+// EventStreamHandlers serves live order and deposit progress over server-sent events
+type EventStreamHandlers struct {
+	service   *eventstream.Service
+	heartbeat time.Duration
+	logger    *zap.Logger
+}
+
+// NewEventStreamHandlers creates a new event stream handlers instance
+func NewEventStreamHandlers(service *eventstream.Service, heartbeat time.Duration, logger *zap.Logger) *EventStreamHandlers {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &EventStreamHandlers{
+		service:   service,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// Stream handles GET /api/v1/events/stream
+// @Summary Stream order and deposit progress
+// @Description Server-sent events for clients that cannot hold a WebSocket. Send the
+// @Description last received event id as Last-Event-ID (or last_event_id) to resume.
+// @Tags events
+// @Produce text/event-stream
+// @Param types query string false "Comma-separated event types (order.updated, deposit.updated)"
+// @Param last_event_id query string false "Resume after this event id"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/events/stream [get]
+func (h *EventStreamHandlers) Stream(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	types := make(map[entities.StreamEventType]bool)
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types[entities.StreamEventType(t)] = true
+			}
+		}
+	}
+
+	cursor := c.GetHeader("Last-Event-ID")
+	if cursor == "" {
+		cursor = c.Query("last_event_id")
+	}
+	if cursor == "" {
+		cursor = eventstream.StartCursor(time.Now())
+	} else if !eventstream.ValidCursor(cursor) {
+		respondBadRequest(c, "Invalid Last-Event-ID", nil)
+		return
+	}
+
+	// The connection outlives the server write timeout by design
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	for {
+		events, next, err := h.service.Read(ctx, userID, cursor, types, h.heartbeat)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if !errors.Is(err, eventstream.ErrInvalidCursor) {
+				h.logger.Warn("Event stream read failed", zap.Error(err), zap.String("user_id", userID.String()))
+			}
+			fmt.Fprint(c.Writer, "event: error\ndata: {\"message\":\"stream unavailable\"}\n\n")
+			c.Writer.Flush()
+			return
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		}
+		if len(events) == 0 && next == cursor {
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		cursor = next
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/experiments"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// ExperimentHandlers let product run and conclude onboarding experiments
+type ExperimentHandlers struct {
+	service      *experiments.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewExperimentHandlers creates a new experiment handlers instance
+func NewExperimentHandlers(service *experiments.Service, auditService *adapters.AuditService, logger *zap.Logger) *ExperimentHandlers {
+	return &ExperimentHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListExperiments handles GET /api/v1/admin/experiments
+// @Summary List onboarding experiments
+// @Description Returns every onboarding experiment with the users assigned to, exposed to and converting in each variant.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.ExperimentListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/experiments [get]
+func (h *ExperimentHandlers) ListExperiments(c *gin.Context) {
+	list, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.respondExperimentError(c, err, "Failed to list experiments")
+		return
+	}
+	if list == nil {
+		list = []*entities.Experiment{}
+	}
+	c.JSON(http.StatusOK, ExperimentListResponse{Experiments: list})
+}
+
+// CreateExperiment handles POST /api/v1/admin/experiments
+// @Summary Start an onboarding experiment
+// @Description Starts bucketing users still onboarding across the variants by weight. Clients read the user's variant from the onboarding status.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateExperimentRequest true "Experiment"
+// @Success 201 {object} entities.Experiment
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/experiments [post]
+func (h *ExperimentHandlers) CreateExperiment(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	experiment, err := h.service.Create(c.Request.Context(), adminID, &req)
+	if err != nil {
+		h.respondExperimentError(c, err, "Failed to create experiment")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "create_experiment", "experiment", nil, experiment)
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// GetExperiment handles GET /api/v1/admin/experiments/:id
+// @Summary Get an onboarding experiment
+// @Tags admin
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Success 200 {object} entities.Experiment
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/experiments/{id} [get]
+func (h *ExperimentHandlers) GetExperiment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid experiment ID", nil)
+		return
+	}
+
+	experiment, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondExperimentError(c, err, "Failed to get experiment")
+		return
+	}
+	c.JSON(http.StatusOK, experiment)
+}
+
+// ConcludeExperiment handles POST /api/v1/admin/experiments/:id/conclude
+// @Summary Conclude an onboarding experiment
+// @Description Freezes assignment: users already in the experiment keep their variant, new users get the winning variant, and results stop changing.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Param request body entities.ConcludeExperimentRequest true "Winning variant"
+// @Success 200 {object} entities.Experiment
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/experiments/{id}/conclude [post]
+func (h *ExperimentHandlers) ConcludeExperiment(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid experiment ID", nil)
+		return
+	}
+	var req entities.ConcludeExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	experiment, err := h.service.Conclude(c.Request.Context(), adminID, id, &req)
+	if err != nil {
+		h.respondExperimentError(c, err, "Failed to conclude experiment")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "conclude_experiment", "experiment",
+		map[string]interface{}{"experiment_id": id.String(), "status": string(entities.ExperimentStatusRunning)},
+		map[string]interface{}{
+			"status":          string(experiment.Status),
+			"winning_variant": req.WinningVariant,
+			"reason":          req.Reason,
+			"results":         experiment.Results,
+		})
+	c.JSON(http.StatusOK, experiment)
+}
+
+func (h *ExperimentHandlers) respondExperimentError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrExperimentNotFound):
+		respondNotFound(c, "Experiment not found")
+	case errors.Is(err, entities.ErrExperimentExists):
+		respondError(c, http.StatusConflict, "EXPERIMENT_EXISTS", err.Error(), nil)
+	case errors.Is(err, entities.ErrExperimentConcluded):
+		respondError(c, http.StatusConflict, "EXPERIMENT_CONCLUDED", err.Error(), nil)
+	case errors.Is(err, experiments.ErrInvalidExperiment):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/faultinject"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// FaultInjectionHandlers let admins inject faults into calls to Circle, Due
+// and Alpaca outside production, to rehearse incidents
+type FaultInjectionHandlers struct {
+	service      *faultinject.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewFaultInjectionHandlers creates a new fault injection handlers instance
+func NewFaultInjectionHandlers(service *faultinject.Service, auditService *adapters.AuditService, logger *zap.Logger) *FaultInjectionHandlers {
+	return &FaultInjectionHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// FaultRuleListResponse lists active fault rules
+type FaultRuleListResponse struct {
+	Rules []*entities.FaultRule `json:"rules"`
+}
+
+// ListFaultRules handles GET /api/v1/admin/faults
+// @Summary List fault rules
+// @Description Returns the faults currently injected into outbound provider calls. Unavailable in production.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.FaultRuleListResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/faults [get]
+func (h *FaultInjectionHandlers) ListFaultRules(c *gin.Context) {
+	rules, err := h.service.ListRules(c.Request.Context())
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to list fault rules")
+		return
+	}
+	if rules == nil {
+		rules = []*entities.FaultRule{}
+	}
+	c.JSON(http.StatusOK, FaultRuleListResponse{Rules: rules})
+}
+
+// CreateFaultRule handles POST /api/v1/admin/faults
+// @Summary Inject a fault
+// @Description Adds latency, errors or malformed responses to a share of calls to a provider, optionally narrowed to a method and path prefix, until the rule expires (default 30 minutes). Unavailable in production.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateFaultRuleRequest true "Fault rule"
+// @Success 201 {object} entities.FaultRule
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/faults [post]
+func (h *FaultInjectionHandlers) CreateFaultRule(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateFaultRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.AddRule(c.Request.Context(), adminID, &req)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to create fault rule")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "create_fault_rule", "fault_rule", nil, rule)
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteFaultRule handles DELETE /api/v1/admin/faults/:id
+// @Summary Stop injecting a fault
+// @Tags admin
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/faults/{id} [delete]
+func (h *FaultInjectionHandlers) DeleteFaultRule(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid rule ID", nil)
+		return
+	}
+
+	if err := h.service.RemoveRule(c.Request.Context(), id); err != nil {
+		h.respondServiceError(c, err, "Failed to delete fault rule")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "delete_fault_rule", "fault_rule", map[string]interface{}{
+		"rule_id": id.String(),
+	}, nil)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *FaultInjectionHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, faultinject.ErrDisabled):
+		respondError(c, http.StatusNotFound, "FAULT_INJECTION_DISABLED", "Fault injection is not enabled in this environment", nil)
+	case errors.Is(err, faultinject.ErrRuleNotFound):
+		respondNotFound(c, "Fault rule not found")
+	case errors.Is(err, faultinject.ErrInvalidRule):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
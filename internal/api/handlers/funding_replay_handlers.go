@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/funding"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// FundingReplayHandlers lets support re-drive a user's deposits through the
+// current funding pipeline, typically after a crediting bug is fixed
+type FundingReplayHandlers struct {
+	fundingService *funding.Service
+	auditService   *adapters.AuditService
+	logger         *zap.Logger
+}
+
+// NewFundingReplayHandlers creates a new funding replay handlers instance
+func NewFundingReplayHandlers(fundingService *funding.Service, auditService *adapters.AuditService, logger *zap.Logger) *FundingReplayHandlers {
+	return &FundingReplayHandlers{
+		fundingService: fundingService,
+		auditService:   auditService,
+		logger:         logger,
+	}
+}
+
+// ReplayUserFunding handles POST /api/v1/admin/users/:id/funding/replay
+// @Summary Replay a user's funding events
+// @Description Runs the user's recorded chain deposits through the current confirmation and crediting rules and returns the resulting balance changes. dry_run only reports; apply credits confirmed deposits that were never credited. Credited deposits the current rules would price differently are flagged for manual review and never re-posted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body entities.FundingReplayRequest true "Replay mode"
+// @Success 200 {object} entities.FundingReplayReport
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/funding/replay [post]
+func (h *FundingReplayHandlers) ReplayUserFunding(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	var req entities.FundingReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	report, err := h.fundingService.ReplayUserDeposits(c.Request.Context(), userID, req.Mode)
+	if err != nil {
+		if errors.Is(err, funding.ErrInvalidReplayMode) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to replay user funding", zap.Error(err), zap.String("user_id", userID.String()))
+		respondInternalError(c, "Failed to replay user funding")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "replay_user_funding", "user", nil, map[string]interface{}{
+		"user_id":    userID.String(),
+		"mode":       string(report.Mode),
+		"deposits":   len(report.Entries),
+		"net_change": report.NetChange.String(),
+	})
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/geoip"
+	"go.uber.org/zap"
+)
+
+// GeoAccessHandlers lets administrators review signup and login locations and
+// manage the geo override list
+type GeoAccessHandlers struct {
+	geoService *geoip.Service
+	logger     *zap.Logger
+}
+
+// NewGeoAccessHandlers creates a new geo access handlers instance
+func NewGeoAccessHandlers(geoService *geoip.Service, logger *zap.Logger) *GeoAccessHandlers {
+	return &GeoAccessHandlers{
+		geoService: geoService,
+		logger:     logger,
+	}
+}
+
+// ListGeoOverrides handles GET /api/v1/admin/geo/overrides
+// @Summary List geo overrides
+// @Description Addresses, CIDR ranges and users exempt from signup country blocking and VPN flagging.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.GeoOverrideListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/geo/overrides [get]
+func (h *GeoAccessHandlers) ListGeoOverrides(c *gin.Context) {
+	overrides, err := h.geoService.ListOverrides(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list geo overrides", zap.Error(err))
+		respondInternalError(c, "Failed to list geo overrides")
+		return
+	}
+	c.JSON(http.StatusOK, GeoOverrideListResponse{Overrides: overrides})
+}
+
+// CreateGeoOverride handles POST /api/v1/admin/geo/overrides
+// @Summary Add a geo override
+// @Description Saving an override for an address or user that already has one replaces its reason and expiry.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateGeoOverrideRequest true "Override"
+// @Success 201 {object} entities.GeoOverride
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/geo/overrides [post]
+func (h *GeoAccessHandlers) CreateGeoOverride(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateGeoOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	override, err := h.geoService.CreateOverride(c.Request.Context(), &req, adminID)
+	if err != nil {
+		if errors.Is(err, geoip.ErrInvalidOverride) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to save geo override", zap.Error(err))
+		respondInternalError(c, "Failed to save geo override")
+		return
+	}
+	c.JSON(http.StatusCreated, override)
+}
+
+// DeleteGeoOverride handles DELETE /api/v1/admin/geo/overrides/:id
+// @Summary Delete a geo override
+// @Tags admin
+// @Param id path string true "Override ID"
+// @Success 204
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/geo/overrides/{id} [delete]
+func (h *GeoAccessHandlers) DeleteGeoOverride(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid override ID", nil)
+		return
+	}
+
+	if err := h.geoService.DeleteOverride(c.Request.Context(), id); err != nil {
+		if errors.Is(err, entities.ErrGeoOverrideNotFound) {
+			respondNotFound(c, "Geo override not found")
+			return
+		}
+		h.logger.Error("Failed to delete geo override", zap.Error(err))
+		respondInternalError(c, "Failed to delete geo override")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserGeoEvents handles GET /api/v1/admin/geo/users/:id/events
+// @Summary List a user's signup and login locations
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param limit query int false "Maximum events to return (default 50, max 100)"
+// @Success 200 {object} handlers.GeoAccessEventListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/geo/users/{id}/events [get]
+func (h *GeoAccessHandlers) ListUserGeoEvents(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid user ID", nil)
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	events, err := h.geoService.UserEvents(c.Request.Context(), userID, limit)
+	if err != nil {
+		h.logger.Error("Failed to list geo access events", zap.Error(err))
+		respondInternalError(c, "Failed to list geo access events")
+		return
+	}
+	c.JSON(http.StatusOK, GeoAccessEventListResponse{Events: events})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/goals"
+	"go.uber.org/zap"
+)
+
+// GoalHandlers manage a user's investment goals
+type GoalHandlers struct {
+	service *goals.Service
+	logger  *zap.Logger
+}
+
+// NewGoalHandlers creates a new goal handlers instance
+func NewGoalHandlers(service *goals.Service, logger *zap.Logger) *GoalHandlers {
+	return &GoalHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GoalListResponse lists a user's goals with their progress
+type GoalListResponse struct {
+	Goals []*entities.GoalProgress `json:"goals"`
+}
+
+// CreateGoal handles POST /api/v1/goals
+// @Summary Set an investment goal
+// @Description Plans the monthly contribution needed to reach the target by the target date at the expected return for the risk level
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateGoalRequest true "Goal"
+// @Success 201 {object} entities.GoalProgress
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/goals [post]
+func (h *GoalHandlers) CreateGoal(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	progress, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, userID, "Failed to create goal", err)
+		return
+	}
+	c.JSON(http.StatusCreated, progress)
+}
+
+// ListGoals handles GET /api/v1/goals
+// @Summary List investment goals
+// @Tags goals
+// @Produce json
+// @Success 200 {object} handlers.GoalListResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/goals [get]
+func (h *GoalHandlers) ListGoals(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	progress, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, userID, "Failed to list goals", err)
+		return
+	}
+	c.JSON(http.StatusOK, GoalListResponse{Goals: progress})
+}
+
+// GetGoal handles GET /api/v1/goals/:id
+// @Summary Get an investment goal with its progress
+// @Tags goals
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Success 200 {object} entities.GoalProgress
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/goals/{id} [get]
+func (h *GoalHandlers) GetGoal(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid goal ID", nil)
+		return
+	}
+
+	progress, err := h.service.Get(c.Request.Context(), userID, goalID)
+	if err != nil {
+		h.handleError(c, userID, "Failed to get goal", err)
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+// UpdateGoal handles PUT /api/v1/goals/:id
+// @Summary Change an investment goal
+// @Description A new target, date or risk level re-plans the goal from the current portfolio value
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Param request body entities.UpdateGoalRequest true "Changes"
+// @Success 200 {object} entities.GoalProgress
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/goals/{id} [put]
+func (h *GoalHandlers) UpdateGoal(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid goal ID", nil)
+		return
+	}
+
+	var req entities.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	progress, err := h.service.Update(c.Request.Context(), userID, goalID, &req)
+	if err != nil {
+		h.handleError(c, userID, "Failed to update goal", err)
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+// CancelGoal handles DELETE /api/v1/goals/:id
+// @Summary Cancel an investment goal
+// @Tags goals
+// @Param id path string true "Goal ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/goals/{id} [delete]
+func (h *GoalHandlers) CancelGoal(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	goalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid goal ID", nil)
+		return
+	}
+
+	if err := h.service.Cancel(c.Request.Context(), userID, goalID); err != nil {
+		h.handleError(c, userID, "Failed to cancel goal", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *GoalHandlers) handleError(c *gin.Context, userID uuid.UUID, message string, err error) {
+	switch {
+	case errors.Is(err, entities.ErrGoalNotFound):
+		respondNotFound(c, "Goal not found")
+	case errors.Is(err, entities.ErrInvalidGoal):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/httpcapture"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// HTTPCaptureHandlers lets admins flag users and routes for request capture
+// and read back what was captured
+type HTTPCaptureHandlers struct {
+	service      *httpcapture.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewHTTPCaptureHandlers creates a new HTTP capture handlers instance
+func NewHTTPCaptureHandlers(service *httpcapture.Service, auditService *adapters.AuditService, logger *zap.Logger) *HTTPCaptureHandlers {
+	return &HTTPCaptureHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListCaptures handles GET /api/v1/admin/debug/captures
+// @Summary List HTTP captures
+// @Description Returns unexpired sanitized request/response captures, newest first. Bodies are omitted from the listing.
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Filter by user ID"
+// @Param route query string false "Filter by registered route, e.g. /api/v1/funding/deposits/:id"
+// @Param request_id query string false "Filter by X-Request-ID"
+// @Param status_code query int false "Filter by response status"
+// @Param limit query int false "Maximum captures (default 50, max 200)"
+// @Success 200 {object} handlers.HTTPCaptureListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/debug/captures [get]
+func (h *HTTPCaptureHandlers) ListCaptures(c *gin.Context) {
+	filter := entities.HTTPCaptureFilter{
+		Route:     c.Query("route"),
+		RequestID: c.Query("request_id"),
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid user ID", nil)
+			return
+		}
+		filter.UserID = &userID
+	}
+	if raw := c.Query("status_code"); raw != "" {
+		status, err := strconv.Atoi(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid status code", nil)
+			return
+		}
+		filter.StatusCode = status
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid limit", nil)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	captures, err := h.service.ListCaptures(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list HTTP captures", zap.Error(err))
+		respondInternalError(c, "Failed to list captures")
+		return
+	}
+	if captures == nil {
+		captures = []*entities.HTTPCapture{}
+	}
+	for _, capture := range captures {
+		capture.RequestBody = ""
+		capture.ResponseBody = ""
+	}
+	c.JSON(http.StatusOK, HTTPCaptureListResponse{Captures: captures})
+}
+
+// GetCapture handles GET /api/v1/admin/debug/captures/:id
+// @Summary Get an HTTP capture
+// @Description Returns one capture with its sanitized headers and bodies. Each read is audited.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Capture ID"
+// @Success 200 {object} entities.HTTPCapture
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/debug/captures/{id} [get]
+func (h *HTTPCaptureHandlers) GetCapture(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid capture ID", nil)
+		return
+	}
+
+	capture, err := h.service.GetCapture(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, httpcapture.ErrCaptureNotFound) {
+			respondNotFound(c, "Capture not found")
+			return
+		}
+		h.logger.Error("Failed to get HTTP capture", zap.Error(err), zap.String("capture_id", id.String()))
+		respondInternalError(c, "Failed to get capture")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "view_http_capture", "http_capture", nil, map[string]interface{}{
+		"capture_id": id.String(),
+		"route":      capture.Route,
+	})
+	c.JSON(http.StatusOK, capture)
+}
+
+// ListCaptureTargets handles GET /api/v1/admin/debug/capture-targets
+// @Summary List capture targets
+// @Description Returns the users and routes currently flagged for full capture.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.HTTPCaptureTargetListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/debug/capture-targets [get]
+func (h *HTTPCaptureHandlers) ListCaptureTargets(c *gin.Context) {
+	targets, err := h.service.ListTargets(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list HTTP capture targets", zap.Error(err))
+		respondInternalError(c, "Failed to list capture targets")
+		return
+	}
+	if targets == nil {
+		targets = []*entities.HTTPCaptureTarget{}
+	}
+	c.JSON(http.StatusOK, HTTPCaptureTargetListResponse{Targets: targets})
+}
+
+// CreateCaptureTarget handles POST /api/v1/admin/debug/capture-targets
+// @Summary Flag a user or route for capture
+// @Description Captures every request from the user, or to the route, until the target expires (default 24 hours).
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateHTTPCaptureTargetRequest true "Capture target"
+// @Success 201 {object} entities.HTTPCaptureTarget
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/debug/capture-targets [post]
+func (h *HTTPCaptureHandlers) CreateCaptureTarget(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateHTTPCaptureTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	target, err := h.service.AddTarget(c.Request.Context(), adminID, &req)
+	if err != nil {
+		if errors.Is(err, httpcapture.ErrInvalidTarget) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to create HTTP capture target", zap.Error(err))
+		respondInternalError(c, "Failed to create capture target")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "create_http_capture_target", "http_capture_target", nil, target)
+	c.JSON(http.StatusCreated, target)
+}
+
+// DeleteCaptureTarget handles DELETE /api/v1/admin/debug/capture-targets/:id
+// @Summary Stop capturing for a target
+// @Tags admin
+// @Param id path string true "Target ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/debug/capture-targets/{id} [delete]
+func (h *HTTPCaptureHandlers) DeleteCaptureTarget(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid target ID", nil)
+		return
+	}
+
+	if err := h.service.RemoveTarget(c.Request.Context(), id); err != nil {
+		if errors.Is(err, httpcapture.ErrTargetNotFound) {
+			respondNotFound(c, "Capture target not found")
+			return
+		}
+		h.logger.Error("Failed to delete HTTP capture target", zap.Error(err), zap.String("target_id", id.String()))
+		respondInternalError(c, "Failed to delete capture target")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "delete_http_capture_target", "http_capture_target", map[string]interface{}{
+		"target_id": id.String(),
+	}, nil)
+	c.Status(http.StatusNoContent)
+}
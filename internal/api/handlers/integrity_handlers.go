@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/integrity"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// IntegrityHandlers serve the data integrity checker's reports and let admins
+// run it on demand
+type IntegrityHandlers struct {
+	service      *integrity.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewIntegrityHandlers creates a new integrity handlers instance
+func NewIntegrityHandlers(service *integrity.Service, auditService *adapters.AuditService, logger *zap.Logger) *IntegrityHandlers {
+	return &IntegrityHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListIntegrityRuns handles GET /api/v1/admin/integrity/runs
+// @Summary List integrity runs
+// @Description Returns recent runs of the data integrity checker with the number of anomalies of each class. Fetch a run for the anomalies themselves.
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Runs to return (default 30, max 100)"
+// @Success 200 {object} handlers.IntegrityRunListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/integrity/runs [get]
+func (h *IntegrityHandlers) ListIntegrityRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	runs, err := h.service.List(c.Request.Context(), limit)
+	if err != nil {
+		h.respondIntegrityError(c, err, "Failed to list integrity runs")
+		return
+	}
+	if runs == nil {
+		runs = []*entities.IntegrityReport{}
+	}
+	c.JSON(http.StatusOK, IntegrityRunListResponse{Runs: runs})
+}
+
+// GetIntegrityRun handles GET /api/v1/admin/integrity/runs/:id
+// @Summary Get an integrity run
+// @Description Returns a run's report, listing the users, orders and wallets that failed each check.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Run ID"
+// @Success 200 {object} entities.IntegrityReport
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/integrity/runs/{id} [get]
+func (h *IntegrityHandlers) GetIntegrityRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid run ID", nil)
+		return
+	}
+
+	report, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondIntegrityError(c, err, "Failed to get integrity run")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RunIntegrityCheck handles POST /api/v1/admin/integrity/runs
+// @Summary Run the integrity checker
+// @Description Runs every check now and opens cases for the anomalies found, as the nightly run does.
+// @Tags admin
+// @Produce json
+// @Success 201 {object} entities.IntegrityReport
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/integrity/runs [post]
+func (h *IntegrityHandlers) RunIntegrityCheck(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	report, err := h.service.RunNow(c.Request.Context(), adminID)
+	if report == nil {
+		h.respondIntegrityError(c, err, "Failed to run integrity checks")
+		return
+	}
+	if err != nil {
+		// Some checks failed; the report says which
+		h.logger.Warn("Integrity run finished with errors", zap.Error(err))
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "run_integrity_check", "integrity_run", nil, map[string]interface{}{
+		"run_id":    report.ID.String(),
+		"anomalies": report.Anomalies,
+	})
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *IntegrityHandlers) respondIntegrityError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrIntegrityRunNotFound):
+		respondNotFound(c, "Integrity run not found")
+	case errors.Is(err, integrity.ErrRunInProgress):
+		respondError(c, http.StatusConflict, "INTEGRITY_RUN_IN_PROGRESS", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+)
+
+// GetCart returns the user's investing cart
+// @Summary Get investing cart
+// @Description Lists the basket buys queued for checkout with their total
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.Cart
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/investing/cart [get]
+func (h *WalletFundingHandlers) GetCart(c *gin.Context) {
+	userID, service, ok := h.cartUser(c)
+	if !ok {
+		return
+	}
+	cart, err := service.GetCart(c.Request.Context(), userID)
+	if err != nil {
+		h.respondCartError(c, userID, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// PutCartItem queues a basket buy in the cart
+// @Summary Add basket to cart
+// @Description Queues a buy of the given amount in a basket, replacing the amount if the basket is already in the cart
+// @Tags investing
+// @Accept json
+// @Produce json
+// @Param basketId path string true "Basket ID"
+// @Param request body entities.CartItemRequest true "Amount in USD"
+// @Success 200 {object} entities.Cart
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/investing/cart/items/{basketId} [put]
+func (h *WalletFundingHandlers) PutCartItem(c *gin.Context) {
+	basketID, err := uuid.Parse(c.Param("basketId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid basket ID", nil)
+		return
+	}
+	var req entities.CartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	userID, service, ok := h.cartUser(c)
+	if !ok {
+		return
+	}
+
+	cart, err := service.AddToCart(c.Request.Context(), userID, basketID, req.Amount)
+	if err != nil {
+		h.respondCartError(c, userID, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// DeleteCartItem removes a basket from the cart
+// @Summary Remove basket from cart
+// @Tags investing
+// @Produce json
+// @Param basketId path string true "Basket ID"
+// @Success 200 {object} entities.Cart
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/investing/cart/items/{basketId} [delete]
+func (h *WalletFundingHandlers) DeleteCartItem(c *gin.Context) {
+	basketID, err := uuid.Parse(c.Param("basketId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid basket ID", nil)
+		return
+	}
+	userID, service, ok := h.cartUser(c)
+	if !ok {
+		return
+	}
+
+	cart, err := service.RemoveFromCart(c.Request.Context(), userID, basketID)
+	if err != nil {
+		h.respondCartError(c, userID, err)
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// PreviewCart estimates checking out the whole cart
+// @Summary Preview cart checkout
+// @Description Previews each queued buy at live prices and totals cost, fees and the buying power left. ready is false when an item cannot be placed or the cart exceeds buying power or the spending limit; the item or cart error says why.
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.CartPreview
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/investing/cart/preview [post]
+func (h *WalletFundingHandlers) PreviewCart(c *gin.Context) {
+	userID, service, ok := h.cartUser(c)
+	if !ok {
+		return
+	}
+	preview, err := service.PreviewCart(c.Request.Context(), userID)
+	if err != nil {
+		h.respondCartError(c, userID, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// CheckoutCart places every queued buy behind one step-up
+// @Summary Check out cart
+// @Description Places each basket in the cart as a market buy. Needs a passcodeSessionToken from passcode verification, which is spent, or a 2FA code. The cart is validated as a whole first and nothing is placed if a basket is gone or the total exceeds buying power or the spending limit. Items are then placed one by one: 201 when all were placed, 207 when some failed, 422 when none were placed. Items that were not placed stay in the cart.
+// @Tags investing
+// @Accept json
+// @Produce json
+// @Param request body entities.CartCheckoutRequest true "Step-up"
+// @Success 201 {object} entities.CartCheckoutResult
+// @Success 207 {object} entities.CartCheckoutResult
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 422 {object} entities.CartCheckoutResult
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/investing/cart/checkout [post]
+func (h *WalletFundingHandlers) CheckoutCart(c *gin.Context) {
+	var req entities.CartCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	userID, service, ok := h.cartUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := service.CheckoutCart(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondCartError(c, userID, err)
+		return
+	}
+
+	status := http.StatusCreated
+	switch result.Status {
+	case entities.CartCheckoutPartial:
+		status = http.StatusMultiStatus
+	case entities.CartCheckoutFailed:
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, result)
+}
+
+// cartUser resolves the caller and the investing service for their trading mode
+func (h *WalletFundingHandlers) cartUser(c *gin.Context) (uuid.UUID, *investing.Service, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "User not authenticated")
+		return uuid.Nil, nil, false
+	}
+	service, ok := h.investingFor(c, userID)
+	return userID, service, ok
+}
+
+func (h *WalletFundingHandlers) respondCartError(c *gin.Context, userID uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, entities.ErrCartEmpty):
+		respondError(c, http.StatusBadRequest, "CART_EMPTY", "Cart is empty", nil)
+	case errors.Is(err, entities.ErrCartFull):
+		respondError(c, http.StatusBadRequest, "CART_FULL", "Cart already holds the maximum number of baskets", nil)
+	case errors.Is(err, entities.ErrCartItemNotFound):
+		respondNotFound(c, "Basket is not in the cart")
+	case errors.Is(err, entities.ErrStepUpRequired):
+		respondError(c, http.StatusForbidden, "STEP_UP_REQUIRED", "Verify your passcode or enter a 2FA code to check out", nil)
+	case errors.Is(err, entities.ErrStepUpFailed):
+		respondError(c, http.StatusUnauthorized, "STEP_UP_FAILED", "Passcode session or 2FA code is invalid or expired", nil)
+	case errors.Is(err, investing.ErrBasketNotFound):
+		respondError(c, http.StatusBadRequest, "BASKET_NOT_FOUND", "Specified basket does not exist", nil)
+	case errors.Is(err, investing.ErrInvalidAmount):
+		respondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Invalid order amount", nil)
+	case errors.Is(err, investing.ErrInsufficientFunds):
+		respondError(c, http.StatusForbidden, "INSUFFICIENT_FUNDS", "Insufficient buying power for the cart", nil)
+	case errors.Is(err, entities.ErrSpendingLimitReached):
+		respondError(c, http.StatusForbidden, "SPENDING_LIMIT_REACHED", err.Error(), nil)
+	case errors.Is(err, investing.ErrCartUnavailable):
+		respondError(c, http.StatusServiceUnavailable, "CART_UNAVAILABLE", "Investing cart is not available", nil)
+	default:
+		h.logger.Error("Investing cart request failed", "error", err, "user_id", userID)
+		respondInternalError(c, "Failed to process cart")
+	}
+}
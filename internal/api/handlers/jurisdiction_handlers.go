@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/jurisdiction"
+	"go.uber.org/zap"
+)
+
+// JurisdictionHandlers lets administrators manage per-country product rules
+type JurisdictionHandlers struct {
+	jurisdictionService *jurisdiction.Service
+	logger              *zap.Logger
+}
+
+// NewJurisdictionHandlers creates a new jurisdiction handlers instance
+func NewJurisdictionHandlers(jurisdictionService *jurisdiction.Service, logger *zap.Logger) *JurisdictionHandlers {
+	return &JurisdictionHandlers{
+		jurisdictionService: jurisdictionService,
+		logger:              logger,
+	}
+}
+
+// ListCountryRules handles GET /api/v1/admin/jurisdictions
+// @Summary List country rules
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.JurisdictionRulesResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/jurisdictions [get]
+func (h *JurisdictionHandlers) ListCountryRules(c *gin.Context) {
+	rules, err := h.jurisdictionService.ListRules(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list country rules", zap.Error(err))
+		respondInternalError(c, "Failed to list country rules")
+		return
+	}
+	c.JSON(http.StatusOK, JurisdictionRulesResponse{
+		Rules:    rules,
+		Features: entities.KnownJurisdictionFeatures,
+	})
+}
+
+// GetCountryRule handles GET /api/v1/admin/jurisdictions/:country
+// @Summary Get the effective rule for a country
+// @Description Returns the country's own rule or the default rule when none exists.
+// @Tags admin
+// @Produce json
+// @Param country path string true "ISO 3166-1 alpha-2 country code"
+// @Success 200 {object} entities.CountryRule
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/jurisdictions/{country} [get]
+func (h *JurisdictionHandlers) GetCountryRule(c *gin.Context) {
+	rule, err := h.jurisdictionService.Rule(c.Request.Context(), c.Param("country"))
+	if err != nil {
+		h.logger.Error("Failed to get country rule", zap.Error(err))
+		respondInternalError(c, "Failed to get country rule")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpsertCountryRule handles PUT /api/v1/admin/jurisdictions/:country
+// @Summary Create or update a country rule
+// @Description Changes take effect on every instance within the rule cache TTL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param country path string true "ISO 3166-1 alpha-2 country code, or * for the default rule"
+// @Param request body entities.UpsertCountryRuleRequest true "Country rule"
+// @Success 200 {object} entities.CountryRule
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/jurisdictions/{country} [put]
+func (h *JurisdictionHandlers) UpsertCountryRule(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.UpsertCountryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.jurisdictionService.UpsertRule(c.Request.Context(), c.Param("country"), &req, adminID)
+	if err != nil {
+		if errors.Is(err, jurisdiction.ErrInvalidRule) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to update country rule", zap.Error(err))
+		respondInternalError(c, "Failed to update country rule")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteCountryRule handles DELETE /api/v1/admin/jurisdictions/:country
+// @Summary Delete a country rule
+// @Description Removes a country's own rule so the default rule applies again.
+// @Tags admin
+// @Param country path string true "ISO 3166-1 alpha-2 country code"
+// @Success 204
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/jurisdictions/{country} [delete]
+func (h *JurisdictionHandlers) DeleteCountryRule(c *gin.Context) {
+	deleted, err := h.jurisdictionService.DeleteRule(c.Request.Context(), c.Param("country"))
+	if err != nil {
+		if errors.Is(err, jurisdiction.ErrInvalidRule) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to delete country rule", zap.Error(err))
+		respondInternalError(c, "Failed to delete country rule")
+		return
+	}
+	if !deleted {
+		respondNotFound(c, "Country rule not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
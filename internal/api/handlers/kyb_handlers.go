@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/kyb"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// KYBHandlers expose business account onboarding and its compliance review
+// queue
+type KYBHandlers struct {
+	service      *kyb.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewKYBHandlers creates a new business onboarding handlers instance
+func NewKYBHandlers(service *kyb.Service, auditService *adapters.AuditService, logger *zap.Logger) *KYBHandlers {
+	return &KYBHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// KYBApplicationListResponse is a page of business verification applications
+type KYBApplicationListResponse struct {
+	Applications []*entities.KYBApplication `json:"applications"`
+}
+
+// GetKYBStatus handles GET /api/v1/kyb
+// @Summary Get business account status
+// @Description Returns whether the user can open a business account, their account type and their latest business application with each beneficial owner's verification status
+// @Tags onboarding
+// @Produce json
+// @Success 200 {object} entities.KYBOverview
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kyb [get]
+func (h *KYBHandlers) GetKYBStatus(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	overview, err := h.service.Overview(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get KYB status", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get business account status")
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// SaveCompany handles PUT /api/v1/kyb/company
+// @Summary Save company details
+// @Description Records the company's legal details, starting a draft business application if there is none
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param request body entities.SaveKYBCompanyRequest true "Company details"
+// @Success 200 {object} entities.KYBApplication
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse "Not available"
+// @Failure 409 {object} entities.ErrorResponse "Application already submitted"
+// @Security BearerAuth
+// @Router /api/v1/kyb/company [put]
+func (h *KYBHandlers) SaveCompany(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.SaveKYBCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	application, err := h.service.SaveCompany(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondApplicantError(c, err, userID, "Failed to save company details")
+		return
+	}
+	c.JSON(http.StatusOK, application)
+}
+
+// AddDocuments handles POST /api/v1/kyb/documents
+// @Summary Attach company documents
+// @Description Attaches certificate_of_incorporation, proof_of_address, ownership_structure, operating_agreement or tax_registration documents to the draft application. A document replaces one of the same type.
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param request body entities.AddKYBDocumentsRequest true "Documents"
+// @Success 200 {object} entities.KYBApplication
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kyb/documents [post]
+func (h *KYBHandlers) AddDocuments(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.AddKYBDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	application, err := h.service.AddDocuments(c.Request.Context(), userID, req.Documents)
+	if err != nil {
+		h.respondApplicantError(c, err, userID, "Failed to attach documents")
+		return
+	}
+	c.JSON(http.StatusOK, application)
+}
+
+// AddOwner handles POST /api/v1/kyb/owners
+// @Summary Add a beneficial owner
+// @Description Declares a person owning 25% or more of the business, or controlling it, and submits their identity documents for individual verification
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param request body entities.AddBeneficialOwnerRequest true "Owner and identity documents"
+// @Success 201 {object} entities.BeneficialOwner
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kyb/owners [post]
+func (h *KYBHandlers) AddOwner(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.AddBeneficialOwnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	owner, err := h.service.AddOwner(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondApplicantError(c, err, userID, "Failed to add beneficial owner")
+		return
+	}
+	c.JSON(http.StatusCreated, owner)
+}
+
+// RemoveOwner handles DELETE /api/v1/kyb/owners/:ownerId
+// @Summary Remove a beneficial owner
+// @Tags onboarding
+// @Param ownerId path string true "Owner ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kyb/owners/{ownerId} [delete]
+func (h *KYBHandlers) RemoveOwner(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	ownerID, err := uuid.Parse(c.Param("ownerId"))
+	if err != nil {
+		respondBadRequest(c, "Invalid owner ID", nil)
+		return
+	}
+
+	if err := h.service.RemoveOwner(c.Request.Context(), userID, ownerID); err != nil {
+		h.respondApplicantError(c, err, userID, "Failed to remove beneficial owner")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SubmitKYB handles POST /api/v1/kyb/submit
+// @Summary Submit the business application
+// @Description Sends the draft to compliance. Requires company details, a certificate of incorporation and at least one owner named as controlling the business; no owner may have failed identity verification.
+// @Tags onboarding
+// @Produce json
+// @Success 202 {object} entities.KYBApplication
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Failure 422 {object} entities.ErrorResponse "Application incomplete"
+// @Security BearerAuth
+// @Router /api/v1/kyb/submit [post]
+func (h *KYBHandlers) SubmitKYB(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	application, err := h.service.Submit(c.Request.Context(), userID)
+	if err != nil {
+		h.respondApplicantError(c, err, userID, "Failed to submit business application")
+		return
+	}
+	c.JSON(http.StatusAccepted, application)
+}
+
+// ListKYBApplications handles GET /api/v1/admin/kyb
+// @Summary List business verification applications
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status (draft, pending_review, approved, rejected); drafts are hidden by default"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.KYBApplicationListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/kyb [get]
+func (h *KYBHandlers) ListKYBApplications(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.service.List(c.Request.Context(), entities.KYBStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list KYB applications", zap.Error(err))
+		respondInternalError(c, "Failed to list applications")
+		return
+	}
+	c.JSON(http.StatusOK, KYBApplicationListResponse{Applications: list})
+}
+
+// GetKYBApplication handles GET /api/v1/admin/kyb/:id
+// @Summary Get a business verification application
+// @Description Returns the application with its documents and beneficial owners, refreshing owner verification results from the KYC provider
+// @Tags admin
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} entities.KYBApplication
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/kyb/{id} [get]
+func (h *KYBHandlers) GetKYBApplication(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid application ID", nil)
+		return
+	}
+
+	application, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondReviewError(c, err, "Failed to get application")
+		return
+	}
+	c.JSON(http.StatusOK, application)
+}
+
+// ApproveKYB handles POST /api/v1/admin/kyb/:id/approve
+// @Summary Approve a business account
+// @Description Turns the account into a business account with business limits and resolves the compliance case. Owners verified through the KYC provider must all have passed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Application ID"
+// @Param request body entities.ReviewKYBRequest false "Review notes"
+// @Success 200 {object} entities.KYBApplication
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/kyb/{id}/approve [post]
+func (h *KYBHandlers) ApproveKYB(c *gin.Context) {
+	h.review(c, h.service.Approve, "kyb_approve", "Failed to approve application")
+}
+
+// RejectKYB handles POST /api/v1/admin/kyb/:id/reject
+// @Summary Reject a business account
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Application ID"
+// @Param request body entities.ReviewKYBRequest false "Review notes"
+// @Success 200 {object} entities.KYBApplication
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/kyb/{id}/reject [post]
+func (h *KYBHandlers) RejectKYB(c *gin.Context) {
+	h.review(c, h.service.Reject, "kyb_reject", "Failed to reject application")
+}
+
+func (h *KYBHandlers) review(c *gin.Context, decide func(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.KYBApplication, error), action, failure string) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid application ID", nil)
+		return
+	}
+
+	var req entities.ReviewKYBRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
+	application, err := decide(c.Request.Context(), id, adminID, req.Notes)
+	if err != nil {
+		h.respondReviewError(c, err, failure)
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, action, "kyb_application", nil, map[string]interface{}{
+		"application_id": application.ID.String(),
+		"user_id":        application.UserID.String(),
+		"legal_name":     application.LegalName,
+		"status":         string(application.Status),
+	})
+	c.JSON(http.StatusOK, application)
+}
+
+func (h *KYBHandlers) respondApplicantError(c *gin.Context, err error, userID uuid.UUID, failure string) {
+	switch {
+	case errors.Is(err, entities.ErrInvalidKYBDocument), errors.Is(err, entities.ErrInvalidBeneficialOwner):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrKYBIncomplete):
+		respondError(c, http.StatusUnprocessableEntity, "KYB_INCOMPLETE", err.Error(), nil)
+	case errors.Is(err, entities.ErrKYBUnavailable):
+		respondError(c, http.StatusForbidden, "KYB_UNAVAILABLE", err.Error(), nil)
+	case errors.Is(err, entities.ErrKYBApplicationNotFound):
+		respondNotFound(c, "No business application in progress")
+	case errors.Is(err, entities.ErrKYBOwnerNotFound):
+		respondNotFound(c, "Beneficial owner not found")
+	case errors.Is(err, entities.ErrKYBNotEditable), errors.Is(err, entities.ErrKYBAlreadyBusiness):
+		respondError(c, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+	default:
+		if respondUploadReferenceError(c, err) {
+			return
+		}
+		h.logger.Error(failure, zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
+
+func (h *KYBHandlers) respondReviewError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, entities.ErrKYBApplicationNotFound):
+		respondNotFound(c, "Application not found")
+	case errors.Is(err, entities.ErrKYBNotPending), errors.Is(err, entities.ErrKYBOwnersUnverified):
+		respondError(c, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+	default:
+		h.logger.Error(failure, zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/onboarding"
+	"go.uber.org/zap"
+)
+
+// KYCDocumentHandlers let users see which KYC documents were rejected and
+// replace them without redoing the whole submission
+type KYCDocumentHandlers struct {
+	onboardingService *onboarding.Service
+	logger            *zap.Logger
+}
+
+// NewKYCDocumentHandlers creates a new KYC document handlers instance
+func NewKYCDocumentHandlers(onboardingService *onboarding.Service, logger *zap.Logger) *KYCDocumentHandlers {
+	return &KYCDocumentHandlers{
+		onboardingService: onboardingService,
+		logger:            logger,
+	}
+}
+
+// ReplaceKYCDocumentRequest is a new upload for a rejected document, either
+// a completed upload or a file URL
+type ReplaceKYCDocumentRequest struct {
+	UploadID    *uuid.UUID `json:"uploadId,omitempty"`
+	FileURL     string     `json:"fileUrl,omitempty" binding:"required_without=UploadID,omitempty,url"`
+	ContentType string     `json:"contentType,omitempty" binding:"required_without=UploadID"`
+}
+
+// ListKYCDocuments handles GET /api/v1/kyc/documents
+// @Summary List KYC documents
+// @Description Returns the documents of the latest KYC submission with their review status and the types that must be replaced before review resumes
+// @Tags onboarding
+// @Produce json
+// @Success 200 {object} handlers.KYCDocumentListResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/kyc/documents [get]
+func (h *KYCDocumentHandlers) ListKYCDocuments(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	submission, err := h.onboardingService.GetKYCDocuments(c.Request.Context(), userID)
+	if err != nil {
+		h.respondKYCDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, kycDocumentListResponse(submission))
+}
+
+// DeleteKYCDocument handles DELETE /api/v1/kyc/documents/:type
+// @Summary Delete a rejected KYC document
+// @Description Removes a rejected document from the latest submission. It must be replaced before the submission is reviewed again.
+// @Tags onboarding
+// @Produce json
+// @Param type path string true "Document type, e.g. passport"
+// @Success 200 {object} handlers.KYCDocumentListResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse "Document is not rejected"
+// @Security BearerAuth
+// @Router /api/v1/kyc/documents/{type} [delete]
+func (h *KYCDocumentHandlers) DeleteKYCDocument(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	submission, err := h.onboardingService.DeleteKYCDocument(c.Request.Context(), userID, c.Param("type"))
+	if err != nil {
+		h.respondKYCDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, kycDocumentListResponse(submission))
+}
+
+// ReplaceKYCDocument handles PUT /api/v1/kyc/documents/:type
+// @Summary Replace a rejected KYC document
+// @Description Uploads a new version of a rejected document. When it was the last rejected document the submission goes back for review, sending only the replaced documents if the KYC provider supports it.
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param type path string true "Document type, e.g. passport"
+// @Param request body handlers.ReplaceKYCDocumentRequest true "Replacement document"
+// @Success 200 {object} handlers.KYCDocumentListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse "Document is not rejected"
+// @Security BearerAuth
+// @Router /api/v1/kyc/documents/{type} [put]
+func (h *KYCDocumentHandlers) ReplaceKYCDocument(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req ReplaceKYCDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	submission, err := h.onboardingService.ReplaceKYCDocument(c.Request.Context(), userID, entities.KYCDocumentUpload{
+		Type:        c.Param("type"),
+		UploadID:    req.UploadID,
+		FileURL:     req.FileURL,
+		ContentType: req.ContentType,
+	})
+	if err != nil {
+		h.respondKYCDocumentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, kycDocumentListResponse(submission))
+}
+
+func (h *KYCDocumentHandlers) respondKYCDocumentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, entities.ErrKYCSubmissionNotFound):
+		respondNotFound(c, "No KYC submission found")
+	case errors.Is(err, entities.ErrKYCDocumentNotFound):
+		respondNotFound(c, "KYC document not found")
+	case errors.Is(err, entities.ErrKYCDocumentNotRejected):
+		respondError(c, http.StatusConflict, "DOCUMENT_NOT_REJECTED", err.Error(), nil)
+	default:
+		if respondUploadReferenceError(c, err) {
+			return
+		}
+		h.logger.Error("KYC document request failed", zap.Error(err))
+		respondInternalError(c, "Failed to process KYC document request")
+	}
+}
+
+func kycDocumentListResponse(submission *entities.KYCSubmission) KYCDocumentListResponse {
+	documents := submission.Documents
+	if documents == nil {
+		documents = []entities.KYCDocument{}
+	}
+	pending := submission.PendingReplacements()
+	if pending == nil {
+		pending = []string{}
+	}
+	return KYCDocumentListResponse{
+		SubmissionID:        submission.ID.String(),
+		Status:              submission.Status,
+		Documents:           documents,
+		PendingReplacements: pending,
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/adjustments"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+)
+
+// LedgerAdjustmentHandlers expose manual ledger adjustments to ops, and the
+// posted ones to the users they affect
+type LedgerAdjustmentHandlers struct {
+	service      *adjustments.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewLedgerAdjustmentHandlers creates new ledger adjustment handlers
+func NewLedgerAdjustmentHandlers(service *adjustments.Service, auditService *adapters.AuditService, logger *zap.Logger) *LedgerAdjustmentHandlers {
+	return &LedgerAdjustmentHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// LedgerAdjustmentListResponse is a page of ledger adjustments
+type LedgerAdjustmentListResponse struct {
+	Adjustments []*entities.LedgerAdjustment `json:"adjustments"`
+}
+
+// AdjustmentStatementResponse lists the adjustments on a user's statement
+type AdjustmentStatementResponse struct {
+	Adjustments []entities.AdjustmentStatementLine `json:"adjustments"`
+}
+
+// RequestLedgerAdjustment handles POST /api/v1/admin/ledger/adjustments
+// @Summary Request a manual ledger adjustment
+// @Description Records a correcting entry between a user's account and a system account and queues it for approval. It is posted as one balanced transaction only after an admin other than the requester approves it through the approvals queue.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateLedgerAdjustmentRequest true "Adjustment"
+// @Success 202 {object} entities.LedgerAdjustment
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/ledger/adjustments [post]
+func (h *LedgerAdjustmentHandlers) RequestLedgerAdjustment(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateLedgerAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	adjustment, err := h.service.Request(c.Request.Context(), adminID, &req)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidLedgerAdjustment) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to request ledger adjustment", zap.String("user_id", req.UserID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to request ledger adjustment")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "ledger_adjustment_requested", "ledger_adjustment", nil, map[string]interface{}{
+		"adjustment_id": adjustment.ID.String(),
+		"user_id":       adjustment.UserID.String(),
+		"direction":     string(adjustment.Direction),
+		"amount":        adjustment.Amount.String(),
+		"currency":      adjustment.Currency,
+		"reason_code":   string(adjustment.ReasonCode),
+	})
+	c.JSON(http.StatusAccepted, adjustment)
+}
+
+// ListLedgerAdjustments handles GET /api/v1/admin/ledger/adjustments
+// @Summary List ledger adjustments
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Filter by user"
+// @Param status query string false "Filter by status (pending_approval, posted, rejected, failed)"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.LedgerAdjustmentListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/ledger/adjustments [get]
+func (h *LedgerAdjustmentHandlers) ListLedgerAdjustments(c *gin.Context) {
+	filter := entities.LedgerAdjustmentFilter{Status: entities.LedgerAdjustmentStatus(c.Query("status"))}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid user ID", nil)
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	list, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list ledger adjustments", zap.Error(err))
+		respondInternalError(c, "Failed to list ledger adjustments")
+		return
+	}
+	c.JSON(http.StatusOK, LedgerAdjustmentListResponse{Adjustments: list})
+}
+
+// GetLedgerAdjustment handles GET /api/v1/admin/ledger/adjustments/:id
+// @Summary Get a ledger adjustment
+// @Tags admin
+// @Produce json
+// @Param id path string true "Adjustment ID"
+// @Success 200 {object} entities.LedgerAdjustment
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/ledger/adjustments/{id} [get]
+func (h *LedgerAdjustmentHandlers) GetLedgerAdjustment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid adjustment ID", nil)
+		return
+	}
+
+	adjustment, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, entities.ErrLedgerAdjustmentNotFound) {
+			respondNotFound(c, "Ledger adjustment not found")
+			return
+		}
+		h.logger.Error("Failed to get ledger adjustment", zap.String("adjustment_id", id.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get ledger adjustment")
+		return
+	}
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// ListMyAdjustments handles GET /api/v1/balances/adjustments
+// @Summary List balance adjustments
+// @Description Returns the manual corrections posted to the user's balances, newest first, for their statement
+// @Tags balances
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.AdjustmentStatementResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/balances/adjustments [get]
+func (h *LedgerAdjustmentHandlers) ListMyAdjustments(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	lines, err := h.service.Statement(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list balance adjustments", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to list balance adjustments")
+		return
+	}
+	c.JSON(http.StatusOK, AdjustmentStatementResponse{Adjustments: lines})
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/linkedwallets"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// LinkedWalletHandlers serve the wallets linked to the user's Due account
+type LinkedWalletHandlers struct {
+	service      *linkedwallets.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewLinkedWalletHandlers creates a new linked wallet handlers instance
+func NewLinkedWalletHandlers(service *linkedwallets.Service, auditService *adapters.AuditService, logger *zap.Logger) *LinkedWalletHandlers {
+	return &LinkedWalletHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListLinkedWallets handles GET /api/v1/wallets/linked
+// @Summary List linked wallets
+// @Description Returns the wallets linked to the user's Due account: the wallets we created for them and self-custody addresses they linked.
+// @Tags wallets
+// @Produce json
+// @Success 200 {object} handlers.LinkedWalletListResponse
+// @Security BearerAuth
+// @Router /api/v1/wallets/linked [get]
+func (h *LinkedWalletHandlers) ListLinkedWallets(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	wallets, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list linked wallets", zap.Error(err), zap.String("user_id", userID.String()))
+		respondInternalError(c, "Failed to list linked wallets")
+		return
+	}
+	if wallets == nil {
+		wallets = []*entities.LinkedWallet{}
+	}
+	c.JSON(http.StatusOK, LinkedWalletListResponse{Wallets: wallets})
+}
+
+// CreateWalletChallenge handles POST /api/v1/wallets/linked/challenge
+// @Summary Get a wallet ownership challenge
+// @Description Returns a message to sign with the self-custody wallet being linked. The signed message must be submitted before it expires.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param request body entities.WalletChallengeRequest true "Wallet to link"
+// @Success 200 {object} entities.WalletChallenge
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/wallets/linked/challenge [post]
+func (h *LinkedWalletHandlers) CreateWalletChallenge(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.WalletChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	challenge, err := h.service.Challenge(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondLinkedWalletError(c, err, "Failed to create wallet challenge")
+		return
+	}
+	c.JSON(http.StatusOK, challenge)
+}
+
+// LinkWallet handles POST /api/v1/wallets/linked
+// @Summary Link a self-custody wallet
+// @Description Links an address the user controls to their Due account. The request carries the challenge message and the wallet's signature of it.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param request body entities.LinkExternalWalletRequest true "Signed challenge"
+// @Success 201 {object} entities.LinkedWallet
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/wallets/linked [post]
+func (h *LinkedWalletHandlers) LinkWallet(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.LinkExternalWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	wallet, err := h.service.Link(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondLinkedWalletError(c, err, "Failed to link wallet")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &userID, "wallet_linked", "linked_wallet", nil, map[string]interface{}{
+		"linked_wallet_id": wallet.ID.String(),
+		"chain":            string(wallet.Chain),
+		"address":          wallet.Address,
+		"due_wallet_id":    wallet.DueWalletID,
+	})
+	c.JSON(http.StatusCreated, wallet)
+}
+
+// UnlinkWallet handles DELETE /api/v1/wallets/linked/:id
+// @Summary Unlink a self-custody wallet
+// @Description Removes a self-custody wallet from the user's Due account. Wallets created by the platform cannot be unlinked.
+// @Tags wallets
+// @Param id path string true "Linked wallet ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/wallets/linked/{id} [delete]
+func (h *LinkedWalletHandlers) UnlinkWallet(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid linked wallet ID", nil)
+		return
+	}
+
+	wallet, err := h.service.Unlink(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondLinkedWalletError(c, err, "Failed to unlink wallet")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &userID, "wallet_unlinked", "linked_wallet", nil, map[string]interface{}{
+		"linked_wallet_id": wallet.ID.String(),
+		"chain":            string(wallet.Chain),
+		"address":          wallet.Address,
+		"due_wallet_id":    wallet.DueWalletID,
+	})
+	c.Status(http.StatusNoContent)
+}
+
+func (h *LinkedWalletHandlers) respondLinkedWalletError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrLinkedWalletNotFound):
+		respondNotFound(c, "Linked wallet not found")
+	case errors.Is(err, entities.ErrLinkedWalletExists):
+		respondError(c, http.StatusConflict, "WALLET_ALREADY_LINKED", err.Error(), nil)
+	case errors.Is(err, linkedwallets.ErrPlatformWallet):
+		respondError(c, http.StatusConflict, "PLATFORM_WALLET", err.Error(), nil)
+	case errors.Is(err, linkedwallets.ErrUnsupportedChain), errors.Is(err, linkedwallets.ErrInvalidAddress),
+		errors.Is(err, linkedwallets.ErrChallengeInvalid), errors.Is(err, linkedwallets.ErrSignatureInvalid),
+		errors.Is(err, linkedwallets.ErrNoDueAccount):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// LogLevelHandlers let admins turn up logging for one module while
+// investigating, reverting on its own after a while
+type LogLevelHandlers struct {
+	levels          *logger.Levels
+	defaultDuration time.Duration
+	maxDuration     time.Duration
+	auditService    *adapters.AuditService
+	logger          *zap.Logger
+}
+
+// NewLogLevelHandlers creates new log level handlers
+func NewLogLevelHandlers(levels *logger.Levels, defaultDuration, maxDuration time.Duration, auditService *adapters.AuditService, logger *zap.Logger) *LogLevelHandlers {
+	if defaultDuration <= 0 {
+		defaultDuration = 15 * time.Minute
+	}
+	if maxDuration < defaultDuration {
+		maxDuration = defaultDuration
+	}
+	return &LogLevelHandlers{
+		levels:          levels,
+		defaultDuration: defaultDuration,
+		maxDuration:     maxDuration,
+		auditService:    auditService,
+		logger:          logger,
+	}
+}
+
+// ListLogLevels handles GET /api/v1/admin/log-levels
+// @Summary List module log levels
+// @Description Each module's current level, its level from the config file and any temporary override with when it reverts
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.LogLevelListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/log-levels [get]
+func (h *LogLevelHandlers) ListLogLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, LogLevelListResponse{Modules: h.levels.List()})
+}
+
+// SetLogLevel handles PUT /api/v1/admin/log-levels/{module}
+// @Summary Override a module's log level
+// @Description Sets the module's level without a restart, reverting to its configured level after duration_minutes (default and maximum are configured)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param module path string true "Module, e.g. due"
+// @Param request body entities.SetLogLevelRequest true "Level and duration"
+// @Success 200 {object} logger.ModuleLevel
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/log-levels/{module} [put]
+func (h *LogLevelHandlers) SetLogLevel(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	duration := h.defaultDuration
+	if req.DurationMinutes != 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration <= 0 || duration > h.maxDuration {
+		respondBadRequest(c, fmt.Sprintf("duration_minutes must be between 1 and %d", int(h.maxDuration.Minutes())), nil)
+		return
+	}
+
+	module := c.Param("module")
+	updated, err := h.levels.Override(module, level, duration)
+	if err != nil {
+		if errors.Is(err, logger.ErrUnknownModule) {
+			respondNotFound(c, "Unknown log module")
+			return
+		}
+		h.logger.Error("Failed to set log level", zap.Error(err), zap.String("module", module))
+		respondInternalError(c, "Failed to set log level")
+		return
+	}
+	h.logger.Info("Module log level overridden",
+		zap.String("module", module),
+		zap.String("level", updated.Level),
+		zap.Duration("duration", duration),
+		zap.String("admin_id", adminID.String()))
+	h.auditService.LogAction(c.Request.Context(), &adminID, "set_log_level", "log_level", nil, map[string]interface{}{
+		"module":     module,
+		"level":      updated.Level,
+		"expires_at": updated.ExpiresAt,
+	})
+	c.JSON(http.StatusOK, updated)
+}
+
+// ResetLogLevel handles DELETE /api/v1/admin/log-levels/{module}
+// @Summary Revert a module's log level
+// @Description Drops the module's override before it expires, returning it to its configured level
+// @Tags admin
+// @Produce json
+// @Param module path string true "Module, e.g. due"
+// @Success 200 {object} logger.ModuleLevel
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/log-levels/{module} [delete]
+func (h *LogLevelHandlers) ResetLogLevel(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	module := c.Param("module")
+	reverted, err := h.levels.Revert(module)
+	if err != nil {
+		respondNotFound(c, "Unknown log module")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "reset_log_level", "log_level", nil, map[string]interface{}{
+		"module": module,
+		"level":  reverted.Level,
+	})
+	c.JSON(http.StatusOK, reverted)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/marketdata"
+	"go.uber.org/zap"
+)
+
+// MarketDataHandlers stream live quotes to clients over server-sent events
+type MarketDataHandlers struct {
+	service   *marketdata.Service
+	heartbeat time.Duration
+	logger    *zap.Logger
+}
+
+// NewMarketDataHandlers creates a new market data handlers instance
+func NewMarketDataHandlers(service *marketdata.Service, heartbeat time.Duration, logger *zap.Logger) *MarketDataHandlers {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &MarketDataHandlers{
+		service:   service,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// StreamQuotes handles GET /api/v1/market/quotes/stream
+// @Summary Stream live quotes
+// @Description Server-sent events: a quotes.snapshot event with the latest quote per symbol,
+// @Description then quotes events with the symbols that changed, at most once per throttle interval.
+// @Description Without symbols, streams the symbols in the user's basket holdings.
+// @Tags market
+// @Produce text/event-stream
+// @Param symbols query string false "Comma-separated symbols"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/market/quotes/stream [get]
+func (h *MarketDataHandlers) StreamQuotes(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var symbols []string
+	if raw := c.Query("symbols"); raw != "" {
+		symbols = strings.Split(raw, ",")
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.service.Subscribe(ctx, userID, symbols)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidSymbol), errors.Is(err, entities.ErrTooManySymbols):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, entities.ErrNoSymbolsToWatch):
+			respondBadRequest(c, "No symbols given and no holdings to stream", nil)
+		default:
+			h.logger.Error("Failed to subscribe to quotes", zap.Error(err), zap.String("user_id", userID.String()))
+			respondInternalError(c, "Failed to subscribe to quotes")
+		}
+		return
+	}
+	defer sub.Close()
+
+	// The connection outlives the server write timeout by design
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	h.writeEvent(c, "quotes.snapshot", h.service.Snapshot(ctx, sub.Symbols()))
+	c.Writer.Flush()
+
+	for {
+		quotes, err := sub.Next(ctx, h.heartbeat)
+		if err != nil {
+			return
+		}
+		if len(quotes) == 0 {
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		} else {
+			h.writeEvent(c, "quotes", quotes)
+		}
+		c.Writer.Flush()
+	}
+}
+
+func (h *MarketDataHandlers) writeEvent(c *gin.Context, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		h.logger.Warn("Failed to encode quote event", zap.String("event", event), zap.Error(err))
+		return
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, data)
+}
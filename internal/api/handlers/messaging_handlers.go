@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// MessagingHandlers let admins read sandbox-captured email and SMS and manage
+// provider failover
+type MessagingHandlers struct {
+	captures     *repositories.CapturedMessageRepository
+	email        *adapters.EmailService
+	sms          *adapters.SMSService
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewMessagingHandlers creates a new messaging handlers instance. The email
+// and SMS services may be nil when those channels are not configured.
+func NewMessagingHandlers(captures *repositories.CapturedMessageRepository, email *adapters.EmailService, sms *adapters.SMSService, auditService *adapters.AuditService, logger *zap.Logger) *MessagingHandlers {
+	return &MessagingHandlers{
+		captures:     captures,
+		email:        email,
+		sms:          sms,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// CapturedMessageListResponse lists sandbox-captured messages
+type CapturedMessageListResponse struct {
+	Messages []*entities.CapturedMessage `json:"messages"`
+}
+
+// MessagingProviderListResponse lists email and SMS providers in failover order
+type MessagingProviderListResponse struct {
+	Providers []entities.MessagingProviderStatus `json:"providers"`
+}
+
+// ListCapturedMessages handles GET /api/v1/admin/messaging/captures
+// @Summary List sandbox-captured messages
+// @Description Returns email and SMS that sandbox capture stored instead of sending, newest first
+// @Tags admin
+// @Produce json
+// @Param channel query string false "Filter by channel (email, sms)"
+// @Param recipient query string false "Filter by recipient email address or E.164 phone number"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset"
+// @Success 200 {object} handlers.CapturedMessageListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/messaging/captures [get]
+func (h *MessagingHandlers) ListCapturedMessages(c *gin.Context) {
+	filter := entities.CapturedMessageFilter{
+		Channel:   entities.NotificationChannel(c.Query("channel")),
+		Recipient: c.Query("recipient"),
+		Limit:     50,
+	}
+	if filter.Channel != "" && filter.Channel != entities.ChannelEmail && filter.Channel != entities.ChannelSMS {
+		respondBadRequest(c, "Channel must be email or sms", nil)
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 200 {
+			respondBadRequest(c, "Limit must be between 1 and 200", nil)
+			return
+		}
+		filter.Limit = limit
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			respondBadRequest(c, "Invalid offset", nil)
+			return
+		}
+		filter.Offset = offset
+	}
+
+	messages, err := h.captures.ListMessages(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list captured messages", zap.Error(err))
+		respondInternalError(c, "Failed to list captured messages")
+		return
+	}
+	if messages == nil {
+		messages = []*entities.CapturedMessage{}
+	}
+	c.JSON(http.StatusOK, CapturedMessageListResponse{Messages: messages})
+}
+
+// ListProviders handles GET /api/v1/admin/messaging/providers
+// @Summary List email and SMS providers
+// @Description Shows each provider in failover order with its health and whether it is in rotation
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.MessagingProviderListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/messaging/providers [get]
+func (h *MessagingHandlers) ListProviders(c *gin.Context) {
+	providers := []entities.MessagingProviderStatus{}
+	if h.email != nil {
+		providers = append(providers, h.email.ProviderStatuses()...)
+	}
+	if h.sms != nil {
+		providers = append(providers, h.sms.ProviderStatuses()...)
+	}
+	c.JSON(http.StatusOK, MessagingProviderListResponse{Providers: providers})
+}
+
+// SetProviderEnabled handles PUT /api/v1/admin/messaging/providers/:channel/:provider
+// @Summary Take a provider out of rotation or put it back
+// @Description A disabled provider is skipped by failover until re-enabled; the last enabled provider of a channel cannot be disabled. The setting lasts until restart.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel (email, sms)"
+// @Param provider path string true "Provider name as listed, e.g. sendgrid or twilio-2"
+// @Param request body entities.SetMessagingProviderRequest true "Rotation change"
+// @Success 200 {object} handlers.MessagingProviderListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/messaging/providers/{channel}/{provider} [put]
+func (h *MessagingHandlers) SetProviderEnabled(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.SetMessagingProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	channel := entities.NotificationChannel(c.Param("channel"))
+	provider := c.Param("provider")
+	switch {
+	case channel == entities.ChannelEmail && h.email != nil:
+		err = h.email.SetProviderEnabled(provider, req.Enabled)
+	case channel == entities.ChannelSMS && h.sms != nil:
+		err = h.sms.SetProviderEnabled(provider, req.Enabled)
+	default:
+		err = entities.ErrMessagingProviderNotFound
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrMessagingProviderNotFound):
+			respondNotFound(c, "Messaging provider not found")
+		case errors.Is(err, entities.ErrLastMessagingProvider):
+			respondError(c, http.StatusConflict, "LAST_PROVIDER", "The last enabled provider cannot be disabled", nil)
+		default:
+			h.logger.Error("Failed to update messaging provider", zap.Error(err))
+			respondInternalError(c, "Failed to update messaging provider")
+		}
+		return
+	}
+
+	action := "disable_messaging_provider"
+	if req.Enabled {
+		action = "enable_messaging_provider"
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, action, "messaging_provider", nil, map[string]interface{}{
+		"channel":  string(channel),
+		"provider": provider,
+		"reason":   req.Reason,
+	})
+
+	h.logger.Info("Messaging provider rotation changed",
+		zap.String("admin_id", adminID.String()),
+		zap.String("channel", string(channel)),
+		zap.String("provider", provider),
+		zap.Bool("enabled", req.Enabled))
+	h.ListProviders(c)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/news"
+	"go.uber.org/zap"
+)
+
+// NewsHandlers serve market news about the user's holdings
+type NewsHandlers struct {
+	newsService *news.Service
+	logger      *zap.Logger
+}
+
+// NewNewsHandlers creates a new news handlers instance
+func NewNewsHandlers(newsService *news.Service, logger *zap.Logger) *NewsHandlers {
+	return &NewsHandlers{
+		newsService: newsService,
+		logger:      logger,
+	}
+}
+
+// GetPortfolioNews handles GET /api/v1/portfolio/news
+// @Summary Get news about the user's holdings
+// @Description Recent stories about the symbols in the user's baskets, ranked by the share of the portfolio they touch and by recency. Users without holdings get general market news.
+// @Tags portfolio
+// @Produce json
+// @Param limit query int false "Number of stories (default 20, max 50)"
+// @Success 200 {object} entities.PortfolioNews
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 502 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/news [get]
+func (h *NewsHandlers) GetPortfolioNews(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	feed, err := h.newsService.Feed(c.Request.Context(), userID, limit)
+	if err != nil {
+		h.logger.Error("Failed to get portfolio news", zap.Error(err), zap.String("user_id", userID.String()))
+		respondError(c, http.StatusBadGateway, "NEWS_UNAVAILABLE", "News is temporarily unavailable", nil)
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
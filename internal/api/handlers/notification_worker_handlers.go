@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stack-service/stack_service/internal/domain/entities"
@@ -30,31 +32,65 @@ func NewNotificationWorkerHandlers(
 	}
 }
 
+// GetPreferences handles GET /api/v1/notifications/preferences
+// @Summary Get notification preferences
+// @Description Channels, alert types, quiet hours and the daily digest. Quiet hours and the digest hour are in the time zone on the user's profile.
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} entities.UserPreference
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/notifications/preferences [get]
 func (h *NotificationWorkerHandlers) GetPreferences(c *gin.Context) {
-	userID := uuid.MustParse(c.GetString("user_id"))
-
-	prefs := &entities.UserPreference{
-		UserID:             userID,
-		EmailNotifications: true,
-		PushNotifications:  true,
-		DepositAlerts:      true,
-		WithdrawalAlerts:   true,
-		SecurityAlerts:     true,
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
 	}
 
+	prefs, err := h.notificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences", zap.Error(err), zap.String("user_id", userID.String()))
+		respondInternalError(c, "Failed to get notification preferences")
+		return
+	}
 	c.JSON(http.StatusOK, prefs)
 }
 
+// UpdatePreferences handles PUT /api/v1/notifications/preferences
+// @Summary Update notification preferences
+// @Description Changes only the fields given. During quiet hours push and SMS notifications are held until the quiet hours end; with the digest on, low-priority notifications are collected into one daily summary. Critical and security alerts are always sent at once.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body entities.UpdateNotificationPreferencesRequest true "Preferences to change"
+// @Success 200 {object} entities.UserPreference
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/notifications/preferences [put]
 func (h *NotificationWorkerHandlers) UpdatePreferences(c *gin.Context) {
-	var prefs entities.UserPreference
-	if err := c.ShouldBindJSON(&prefs); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
 		return
 	}
 
-	userID := uuid.MustParse(c.GetString("user_id"))
-	prefs.UserID = userID
+	var req entities.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
 
+	prefs, err := h.notificationService.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidPreferences) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to update notification preferences", zap.Error(err), zap.String("user_id", userID.String()))
+		respondInternalError(c, "Failed to update notification preferences")
+		return
+	}
 	c.JSON(http.StatusOK, prefs)
 }
 
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// OnboardingJobHandlers lets admins find onboarding jobs that failed or were
+// abandoned mid-run and put them back on the queue
+type OnboardingJobHandlers struct {
+	service      *services.OnboardingJobService
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewOnboardingJobHandlers creates a new onboarding job handlers instance
+func NewOnboardingJobHandlers(service *services.OnboardingJobService, auditService *adapters.AuditService, logger *zap.Logger) *OnboardingJobHandlers {
+	return &OnboardingJobHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListStuckOnboardingJobs handles GET /api/v1/admin/onboarding/jobs/stuck
+// @Summary List stuck onboarding jobs
+// @Description Returns onboarding jobs that failed after compensation or have been in progress longer than the stuck threshold, oldest first, with per-step status.
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum jobs (default 50, max 200)"
+// @Success 200 {object} handlers.OnboardingJobListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/onboarding/jobs/stuck [get]
+func (h *OnboardingJobHandlers) ListStuckOnboardingJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	jobs, err := h.service.ListStuckJobs(c.Request.Context(), limit)
+	if err != nil {
+		respondInternalError(c, "Failed to list stuck onboarding jobs")
+		return
+	}
+	if jobs == nil {
+		jobs = []*entities.OnboardingJob{}
+	}
+	c.JSON(http.StatusOK, OnboardingJobListResponse{
+		Jobs:       jobs,
+		StuckAfter: h.service.StuckAfter().String(),
+	})
+}
+
+// RequeueOnboardingJob handles POST /api/v1/admin/onboarding/jobs/:id/requeue
+// @Summary Requeue an onboarding job
+// @Description Puts a failed or stuck job back on the queue with a fresh attempt budget. Completed steps are skipped; compensated steps run again.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Onboarding job ID"
+// @Success 200 {object} entities.OnboardingJob
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/onboarding/jobs/{id}/requeue [post]
+func (h *OnboardingJobHandlers) RequeueOnboardingJob(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid job ID", nil)
+		return
+	}
+
+	job, err := h.service.RequeueJob(c.Request.Context(), jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrOnboardingJobNotFound):
+			respondNotFound(c, "Onboarding job not found")
+		case errors.Is(err, services.ErrOnboardingJobNotStuck):
+			respondError(c, http.StatusConflict, "JOB_NOT_STUCK", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to requeue onboarding job", zap.Error(err), zap.String("job_id", jobID.String()))
+			respondInternalError(c, "Failed to requeue onboarding job")
+		}
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "requeue_onboarding_job", "onboarding_job", nil, map[string]interface{}{
+		"job_id":  job.ID.String(),
+		"user_id": job.UserID.String(),
+	})
+	c.JSON(http.StatusOK, job)
+}
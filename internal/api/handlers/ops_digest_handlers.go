@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/opsdigest"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// OpsDigestHandlers serves the daily ops digest to dashboards and lets admins
+// re-send it
+type OpsDigestHandlers struct {
+	service      *opsdigest.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewOpsDigestHandlers creates a new ops digest handlers instance
+func NewOpsDigestHandlers(service *opsdigest.Service, auditService *adapters.AuditService, logger *zap.Logger) *OpsDigestHandlers {
+	return &OpsDigestHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// SendOpsDigestRequest selects the day to send; it defaults to yesterday
+type SendOpsDigestRequest struct {
+	Date string `json:"date"`
+}
+
+// GetOpsDigest handles GET /api/v1/admin/reports/ops-digest
+// @Summary Get the ops digest
+// @Description Returns signups, KYC pass rate, deposit and withdrawal volume, failed jobs, reconciliation exceptions and AI usage for a UTC day. Today's digest covers the day so far.
+// @Tags admin
+// @Produce json
+// @Param date query string false "Day as YYYY-MM-DD (default today)"
+// @Success 200 {object} entities.OpsDigest
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/reports/ops-digest [get]
+func (h *OpsDigestHandlers) GetOpsDigest(c *gin.Context) {
+	date, ok := parseDigestDate(c, c.Query("date"), time.Now())
+	if !ok {
+		return
+	}
+
+	digest, err := h.service.Build(c.Request.Context(), date)
+	if err != nil {
+		h.respondDigestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, digest)
+}
+
+// SendOpsDigest handles POST /api/v1/admin/reports/ops-digest/send
+// @Summary Send the ops digest now
+// @Description Emails the digest for a day to the configured recipients and publishes it to subscribed webhook endpoints. Webhook endpoints receive each day's digest once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body SendOpsDigestRequest false "Day to send (default yesterday)"
+// @Success 200 {object} handlers.OpsDigestSendResponse
+// @Failure 502 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/reports/ops-digest/send [post]
+func (h *OpsDigestHandlers) SendOpsDigest(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req SendOpsDigestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+	date, ok := parseDigestDate(c, req.Date, time.Now().UTC().Add(-24*time.Hour))
+	if !ok {
+		return
+	}
+
+	digest, sent, err := h.service.Send(c.Request.Context(), date)
+	if digest == nil {
+		h.respondDigestError(c, err)
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "send_ops_digest", "ops_digest", nil, map[string]interface{}{
+		"date":   digest.Date,
+		"emails": sent,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadGateway, "DIGEST_DELIVERY_FAILED", err.Error(), map[string]interface{}{"emails_sent": sent})
+		return
+	}
+	c.JSON(http.StatusOK, OpsDigestSendResponse{Digest: digest, EmailsSent: sent})
+}
+
+func parseDigestDate(c *gin.Context, raw string, fallback time.Time) (time.Time, bool) {
+	if raw == "" {
+		return fallback, true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		respondBadRequest(c, "date must be YYYY-MM-DD", nil)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func (h *OpsDigestHandlers) respondDigestError(c *gin.Context, err error) {
+	if errors.Is(err, opsdigest.ErrFutureDate) {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	h.logger.Error("Failed to build ops digest", zap.Error(err))
+	respondInternalError(c, "Failed to build ops digest")
+}
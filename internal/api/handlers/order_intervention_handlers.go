@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/orderops"
+	"go.uber.org/zap"
+)
+
+// OrderInterventionHandlers lets super admins settle orders stuck waiting on
+// the brokerage
+type OrderInterventionHandlers struct {
+	service *orderops.Service
+	logger  *zap.Logger
+}
+
+// NewOrderInterventionHandlers creates a new order intervention handlers instance
+func NewOrderInterventionHandlers(service *orderops.Service, logger *zap.Logger) *OrderInterventionHandlers {
+	return &OrderInterventionHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// OrderInterventionNoteRequest carries the admin's resolution note
+type OrderInterventionNoteRequest struct {
+	Note string `json:"note" binding:"required,max=1000"`
+}
+
+// ListStuckOrders handles GET /api/v1/admin/orders/stuck
+// @Summary List stuck orders
+// @Description Returns live orders still accepted, pending or partially filled with no update for longer than the SLA, longest stuck first.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.StuckOrderListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/orders/stuck [get]
+func (h *OrderInterventionHandlers) ListStuckOrders(c *gin.Context) {
+	orders, err := h.service.ListStuck(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list stuck orders", zap.Error(err))
+		respondInternalError(c, "Failed to list stuck orders")
+		return
+	}
+	if orders == nil {
+		orders = []*entities.StuckOrder{}
+	}
+	c.JSON(http.StatusOK, StuckOrderListResponse{
+		Orders: orders,
+		SLA:    h.service.SLA().String(),
+	})
+}
+
+// ListOrderInterventions handles GET /api/v1/admin/orders/:id/interventions
+// @Summary List interventions on an order
+// @Description Returns every cancel, repair and note recorded on the order with the adjustments it made.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} handlers.OrderInterventionListResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/orders/{id}/interventions [get]
+func (h *OrderInterventionHandlers) ListOrderInterventions(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid order ID", nil)
+		return
+	}
+
+	interventions, err := h.service.Interventions(c.Request.Context(), orderID)
+	if err != nil {
+		h.respondInterventionError(c, orderID, err)
+		return
+	}
+	if interventions == nil {
+		interventions = []*entities.OrderIntervention{}
+	}
+	c.JSON(http.StatusOK, OrderInterventionListResponse{Interventions: interventions})
+}
+
+// CancelStuckOrder handles POST /api/v1/admin/orders/:id/cancel
+// @Summary Cancel a stuck order
+// @Description Cancels the order at the brokerage, books any partial fills to the position and returns a buy's unfilled amount to buying power.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body OrderInterventionNoteRequest true "Resolution note"
+// @Success 200 {object} entities.OrderIntervention
+// @Failure 409 {object} entities.ErrorResponse
+// @Failure 502 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/orders/{id}/cancel [post]
+func (h *OrderInterventionHandlers) CancelStuckOrder(c *gin.Context) {
+	adminID, orderID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	var req OrderInterventionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	intervention, err := h.service.Cancel(c.Request.Context(), adminID, orderID, req.Note)
+	if err != nil {
+		h.respondInterventionError(c, orderID, err)
+		return
+	}
+	c.JSON(http.StatusOK, intervention)
+}
+
+// RepairStuckOrder handles POST /api/v1/admin/orders/:id/repair
+// @Summary Repair a stuck order
+// @Description Moves the order to a final status and applies signed buying power and position deltas as compensating entries.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body entities.OrderRepairRequest true "Final status, adjustments and note"
+// @Success 200 {object} entities.OrderIntervention
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/orders/{id}/repair [post]
+func (h *OrderInterventionHandlers) RepairStuckOrder(c *gin.Context) {
+	adminID, orderID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	var req entities.OrderRepairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	intervention, err := h.service.Repair(c.Request.Context(), adminID, orderID, &req)
+	if err != nil {
+		h.respondInterventionError(c, orderID, err)
+		return
+	}
+	c.JSON(http.StatusOK, intervention)
+}
+
+// AnnotateOrder handles POST /api/v1/admin/orders/:id/notes
+// @Summary Add a resolution note to an order
+// @Description Records a note on the order without changing its status or balances.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body OrderInterventionNoteRequest true "Resolution note"
+// @Success 200 {object} entities.OrderIntervention
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/orders/{id}/notes [post]
+func (h *OrderInterventionHandlers) AnnotateOrder(c *gin.Context) {
+	adminID, orderID, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	var req OrderInterventionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	intervention, err := h.service.Annotate(c.Request.Context(), adminID, orderID, req.Note)
+	if err != nil {
+		h.respondInterventionError(c, orderID, err)
+		return
+	}
+	c.JSON(http.StatusOK, intervention)
+}
+
+func (h *OrderInterventionHandlers) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid order ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return adminID, orderID, true
+}
+
+func (h *OrderInterventionHandlers) respondInterventionError(c *gin.Context, orderID uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, orderops.ErrOrderNotFound):
+		respondNotFound(c, "Order not found")
+	case errors.Is(err, orderops.ErrOrderNotStuck):
+		respondError(c, http.StatusConflict, "ORDER_NOT_STUCK", err.Error(), nil)
+	case errors.Is(err, orderops.ErrOrderMoved):
+		respondError(c, http.StatusConflict, "ORDER_MOVED", err.Error(), nil)
+	case errors.Is(err, orderops.ErrInvalidAdjustment):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, orderops.ErrBrokerCancelFailed):
+		respondError(c, http.StatusBadGateway, "BROKER_CANCEL_FAILED", err.Error(), nil)
+	default:
+		h.logger.Error("Order intervention failed", zap.String("order_id", orderID.String()), zap.Error(err))
+		respondInternalError(c, "Order intervention failed")
+	}
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/outboundwebhook"
+	"go.uber.org/zap"
+)
+
+// OutboundWebhookHandlers exposes partner webhook endpoint management and the delivery log
+type OutboundWebhookHandlers struct {
+	service *outboundwebhook.Service
+	logger  *zap.Logger
+}
+
+// NewOutboundWebhookHandlers creates a new outbound webhook handlers instance
+func NewOutboundWebhookHandlers(service *outboundwebhook.Service, logger *zap.Logger) *OutboundWebhookHandlers {
+	return &OutboundWebhookHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateEndpoint handles POST /api/v1/admin/webhooks/endpoints
+// @Summary Register a partner webhook endpoint
+// @Description Returns the signing secret once; store it to verify the X-Stack-Signature header
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateWebhookEndpointRequest true "Endpoint"
+// @Success 201 {object} entities.WebhookEndpointWithSecret
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints [post]
+func (h *OutboundWebhookHandlers) CreateEndpoint(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(c.Request.Context(), &req, &adminID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to create webhook endpoint")
+		return
+	}
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// ListEndpoints handles GET /api/v1/admin/webhooks/endpoints
+// @Summary List partner webhook endpoints
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.WebhookEndpointListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints [get]
+func (h *OutboundWebhookHandlers) ListEndpoints(c *gin.Context) {
+	endpoints, err := h.service.ListEndpoints(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list webhook endpoints", zap.Error(err))
+		respondInternalError(c, "Failed to list webhook endpoints")
+		return
+	}
+	c.JSON(http.StatusOK, WebhookEndpointListResponse{Endpoints: endpoints})
+}
+
+// GetEndpoint handles GET /api/v1/admin/webhooks/endpoints/:id
+// @Summary Get a partner webhook endpoint
+// @Tags admin
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Success 200 {object} entities.WebhookEndpoint
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints/{id} [get]
+func (h *OutboundWebhookHandlers) GetEndpoint(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+	endpoint, err := h.service.GetEndpoint(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get webhook endpoint")
+		return
+	}
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// UpdateEndpoint handles PATCH /api/v1/admin/webhooks/endpoints/:id
+// @Summary Update a partner webhook endpoint
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Param request body entities.UpdateWebhookEndpointRequest true "Endpoint update"
+// @Success 200 {object} entities.WebhookEndpoint
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints/{id} [patch]
+func (h *OutboundWebhookHandlers) UpdateEndpoint(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+
+	var req entities.UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(c.Request.Context(), id, &req)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to update webhook endpoint")
+		return
+	}
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteEndpoint handles DELETE /api/v1/admin/webhooks/endpoints/:id
+// @Summary Delete a partner webhook endpoint
+// @Tags admin
+// @Param id path string true "Endpoint ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints/{id} [delete]
+func (h *OutboundWebhookHandlers) DeleteEndpoint(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteEndpoint(c.Request.Context(), id); err != nil {
+		h.respondServiceError(c, err, "Failed to delete webhook endpoint")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RotateSecret handles POST /api/v1/admin/webhooks/endpoints/:id/rotate-secret
+// @Summary Rotate a partner webhook signing secret
+// @Tags admin
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Success 200 {object} entities.WebhookEndpointWithSecret
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints/{id}/rotate-secret [post]
+func (h *OutboundWebhookHandlers) RotateSecret(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+	endpoint, err := h.service.RotateSecret(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to rotate webhook secret")
+		return
+	}
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// SendTestEvent handles POST /api/v1/admin/webhooks/endpoints/:id/test
+// @Summary Queue a webhook.ping delivery to an endpoint
+// @Tags admin
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Success 202 {object} entities.WebhookDelivery
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/endpoints/{id}/test [post]
+func (h *OutboundWebhookHandlers) SendTestEvent(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid endpoint ID")
+	if !ok {
+		return
+	}
+	delivery, err := h.service.SendTestEvent(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to queue test event")
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// ListDeliveries handles GET /api/v1/admin/webhooks/deliveries
+// @Summary List webhook deliveries
+// @Tags admin
+// @Produce json
+// @Param endpoint_id query string false "Filter by endpoint"
+// @Param status query string false "Filter by status (pending, succeeded, failed, dead)"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.WebhookDeliveryListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/deliveries [get]
+func (h *OutboundWebhookHandlers) ListDeliveries(c *gin.Context) {
+	var endpointID *uuid.UUID
+	if raw := c.Query("endpoint_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid endpoint ID", nil)
+			return
+		}
+		endpointID = &id
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), endpointID,
+		entities.WebhookDeliveryStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list webhook deliveries", zap.Error(err))
+		respondInternalError(c, "Failed to list webhook deliveries")
+		return
+	}
+	c.JSON(http.StatusOK, WebhookDeliveryListResponse{Deliveries: deliveries})
+}
+
+// GetDelivery handles GET /api/v1/admin/webhooks/deliveries/:id
+// @Summary Get a webhook delivery
+// @Tags admin
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} entities.WebhookDelivery
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/deliveries/{id} [get]
+func (h *OutboundWebhookHandlers) GetDelivery(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+	delivery, err := h.service.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get webhook delivery")
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RetryDelivery handles POST /api/v1/admin/webhooks/deliveries/:id/retry
+// @Summary Retry a failed webhook delivery now
+// @Tags admin
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 202 {object} entities.WebhookDelivery
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhooks/deliveries/{id}/retry [post]
+func (h *OutboundWebhookHandlers) RetryDelivery(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+	delivery, err := h.service.RetryDelivery(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to retry webhook delivery")
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func (h *OutboundWebhookHandlers) respondServiceError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, entities.ErrWebhookEndpointNotFound):
+		respondNotFound(c, "Webhook endpoint not found")
+	case errors.Is(err, entities.ErrWebhookDeliveryNotFound):
+		respondNotFound(c, "Webhook delivery not found")
+	case errors.Is(err, outboundwebhook.ErrInvalidEndpoint), errors.Is(err, outboundwebhook.ErrUnknownEventType):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		respondInternalError(c, msg)
+	}
+}
+
+func parseWebhookID(c *gin.Context, msg string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, msg, nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
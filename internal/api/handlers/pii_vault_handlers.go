@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/piivault"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// PIIVaultHandlers let compliance staff read raw PII behind a vault token,
+// with a stated purpose, and see who else has
+type PIIVaultHandlers struct {
+	vault        *piivault.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewPIIVaultHandlers creates a new PII vault handlers instance
+func NewPIIVaultHandlers(vault *piivault.Service, auditService *adapters.AuditService, logger *zap.Logger) *PIIVaultHandlers {
+	return &PIIVaultHandlers{
+		vault:        vault,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// RevealPIIRecord handles GET /api/v1/admin/pii-vault/records/:token
+// @Summary Reveal a PII vault record
+// @Description Returns the raw data behind a token. The purpose is required and recorded in the vault's access log under the admin's ID.
+// @Tags admin
+// @Produce json
+// @Param token path string true "Vault token"
+// @Param purpose query string true "Why the data is needed, e.g. a case ID"
+// @Success 200 {object} handlers.PIIRecordResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/pii-vault/records/{token} [get]
+func (h *PIIVaultHandlers) RevealPIIRecord(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	token := c.Param("token")
+	purpose := c.Query("purpose")
+
+	var data json.RawMessage
+	accessor := h.vault.Scope("admin:"+adminID.String(), entities.PIIKindKYCIdentity)
+	if err := accessor.Reveal(c.Request.Context(), token, purpose, &data); err != nil {
+		h.respondPIIVaultError(c, err, "Failed to reveal PII record")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "reveal_pii", "pii_vault_record", nil, map[string]interface{}{
+		"token":   token,
+		"purpose": purpose,
+	})
+	c.JSON(http.StatusOK, PIIRecordResponse{Token: token, Data: data})
+}
+
+// ListPIIRecordAccess handles GET /api/v1/admin/pii-vault/records/:token/access
+// @Summary List uses of a PII vault record
+// @Description Returns who stored, read or was refused the record and why, newest first
+// @Tags admin
+// @Produce json
+// @Param token path string true "Vault token"
+// @Param limit query int false "Maximum entries to return (default 100)"
+// @Success 200 {object} handlers.PIIAccessLogResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/pii-vault/records/{token}/access [get]
+func (h *PIIVaultHandlers) ListPIIRecordAccess(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	entries, err := h.vault.AccessLog(c.Request.Context(), c.Param("token"), limit)
+	if err != nil {
+		h.respondPIIVaultError(c, err, "Failed to list PII record access")
+		return
+	}
+	if entries == nil {
+		entries = []*entities.PIIAccessEntry{}
+	}
+	c.JSON(http.StatusOK, PIIAccessLogResponse{Entries: entries})
+}
+
+func (h *PIIVaultHandlers) respondPIIVaultError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrPIIRecordNotFound):
+		respondNotFound(c, "PII record not found")
+	case errors.Is(err, piivault.ErrPurposeRequired):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrPIIAccessDenied):
+		respondError(c, http.StatusForbidden, "PII_ACCESS_DENIED", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/attribution"
+	"github.com/stack-service/stack_service/internal/domain/services/portfolioshare"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// PortfolioShareHandlers let users publish percentage-only snapshots of their
+// performance through signed links, and serve those links publicly
+type PortfolioShareHandlers struct {
+	service      *portfolioshare.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewPortfolioShareHandlers creates new portfolio share handlers
+func NewPortfolioShareHandlers(service *portfolioshare.Service, auditService *adapters.AuditService, logger *zap.Logger) *PortfolioShareHandlers {
+	return &PortfolioShareHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// CreateShare handles POST /api/v1/portfolio/shares
+// @Summary Share portfolio performance
+// @Description Freezes the user's returns over the range, by basket and against the benchmark, and returns a public link to them. The snapshot holds percentages only; amounts and holdings are never shared. Links expire after expires_in_hours, or the configured default.
+// @Tags portfolio
+// @Accept json
+// @Produce json
+// @Param request body entities.CreatePortfolioShareRequest true "Range and expiry"
+// @Success 201 {object} entities.PortfolioShareLink
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/shares [post]
+func (h *PortfolioShareHandlers) CreateShare(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreatePortfolioShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	link, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, portfolioshare.ErrInvalidExpiry), errors.Is(err, attribution.ErrInvalidRange):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, entities.ErrSharingDisabled):
+			respondError(c, http.StatusForbidden, "SHARING_DISABLED", err.Error(), nil)
+		case errors.Is(err, entities.ErrShareLinkLimit):
+			respondError(c, http.StatusConflict, "SHARE_LINK_LIMIT", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to create portfolio share link", zap.String("user_id", userID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to create share link")
+		}
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "create_portfolio_share", "portfolio_share_link", nil, map[string]interface{}{
+		"link_id":    link.ID,
+		"range":      link.Snapshot.Range,
+		"expires_at": link.ExpiresAt,
+	})
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListShares handles GET /api/v1/portfolio/shares
+// @Summary List portfolio share links
+// @Description Returns the user's share links, newest first, with view counts. Only links that can still be viewed carry a URL.
+// @Tags portfolio
+// @Produce json
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.PortfolioShareListResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/shares [get]
+func (h *PortfolioShareHandlers) ListShares(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	links, err := h.service.List(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list portfolio share links", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to list share links")
+		return
+	}
+	if links == nil {
+		links = []*entities.PortfolioShareLink{}
+	}
+	c.JSON(http.StatusOK, PortfolioShareListResponse{Links: links})
+}
+
+// RevokeShare handles DELETE /api/v1/portfolio/shares/:id
+// @Summary Revoke a portfolio share link
+// @Tags portfolio
+// @Produce json
+// @Param id path string true "Link ID"
+// @Success 200 {object} entities.PortfolioShareLink
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/shares/{id} [delete]
+func (h *PortfolioShareHandlers) RevokeShare(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid link ID", nil)
+		return
+	}
+
+	link, err := h.service.Revoke(c.Request.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, entities.ErrShareLinkNotFound) {
+			respondNotFound(c, "Share link not found")
+			return
+		}
+		h.logger.Error("Failed to revoke portfolio share link", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to revoke share link")
+		return
+	}
+	c.JSON(http.StatusOK, link)
+}
+
+// RevokeAllShares handles POST /api/v1/portfolio/shares/revoke-all
+// @Summary Revoke all portfolio share links
+// @Description Permanently stops every link the user has made from being viewed
+// @Tags portfolio
+// @Produce json
+// @Success 200 {object} entities.RevokePortfolioSharesResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/shares/revoke-all [post]
+func (h *PortfolioShareHandlers) RevokeAllShares(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	revoked, err := h.service.RevokeAll(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to revoke portfolio share links", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to revoke share links")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "revoke_all_portfolio_shares", "portfolio_share_link", nil, map[string]interface{}{
+		"revoked": revoked,
+	})
+	c.JSON(http.StatusOK, entities.RevokePortfolioSharesResponse{Revoked: revoked})
+}
+
+// GetSharingSettings handles GET /api/v1/portfolio/shares/settings
+// @Summary Get portfolio sharing settings
+// @Tags portfolio
+// @Produce json
+// @Success 200 {object} entities.PortfolioSharingSettings
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/shares/settings [get]
+func (h *PortfolioShareHandlers) GetSharingSettings(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	settings, err := h.service.Settings(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get portfolio sharing settings", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get sharing settings")
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSharingSettings handles PUT /api/v1/portfolio/shares/settings
+// @Summary Turn portfolio sharing on or off
+// @Description While sharing is off no links can be made and none of the user's links can be viewed. Links that have not expired or been revoked work again when it is turned back on.
+// @Tags portfolio
+// @Accept json
+// @Produce json
+// @Param request body entities.UpdatePortfolioSharingRequest true "Sharing state"
+// @Success 200 {object} entities.PortfolioSharingSettings
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/portfolio/shares/settings [put]
+func (h *PortfolioShareHandlers) UpdateSharingSettings(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.UpdatePortfolioSharingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.SetEnabled(c.Request.Context(), userID, req.Enabled)
+	if err != nil {
+		h.logger.Error("Failed to update portfolio sharing settings", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to update sharing settings")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "update_portfolio_sharing", "portfolio_sharing_settings", nil, map[string]interface{}{
+		"enabled": settings.Enabled,
+	})
+	c.JSON(http.StatusOK, settings)
+}
+
+// ViewSharedPortfolio handles GET /api/v1/shared/portfolio/:token
+// @Summary View a shared portfolio
+// @Description Serves the performance snapshot behind a share link. The signed token authorizes the request, so no bearer token is needed. Links that expired, were revoked or whose owner turned sharing off all return 410.
+// @Tags portfolio
+// @Produce json
+// @Param token path string true "Signed share token"
+// @Success 200 {object} entities.SharedPortfolio
+// @Failure 403 {object} entities.ErrorResponse
+// @Failure 410 {object} entities.ErrorResponse
+// @Router /api/v1/shared/portfolio/{token} [get]
+func (h *PortfolioShareHandlers) ViewSharedPortfolio(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+
+	shared, err := h.service.Open(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrShareLinkInvalid):
+			respondError(c, http.StatusForbidden, "INVALID_LINK", err.Error(), nil)
+		case errors.Is(err, entities.ErrShareLinkUnavailable):
+			respondError(c, http.StatusGone, "LINK_UNAVAILABLE", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to open shared portfolio", zap.Error(err))
+			respondInternalError(c, "Failed to load shared portfolio")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, shared)
+}
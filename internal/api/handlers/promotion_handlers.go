@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/promotions"
+	"go.uber.org/zap"
+)
+
+// PromotionHandlers exposes promotional credit campaigns to admins and each
+// user's promotional credit to the user
+type PromotionHandlers struct {
+	service *promotions.Service
+	logger  *zap.Logger
+}
+
+// NewPromotionHandlers creates a new promotion handlers instance
+func NewPromotionHandlers(service *promotions.Service, logger *zap.Logger) *PromotionHandlers {
+	return &PromotionHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetMyPromotions handles GET /api/v1/promotions
+// @Summary Get promotional credit
+// @Description Returns the user's promotional credit grants. Pending credit is forfeited if the account closes before it vests.
+// @Tags promotions
+// @Produce json
+// @Success 200 {object} entities.PromotionSummary
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/promotions [get]
+func (h *PromotionHandlers) GetMyPromotions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	summary, err := h.service.Summary(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get promotional credit", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get promotional credit")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// ListPromotions handles GET /api/v1/admin/promotions
+// @Summary List promotions
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.PromotionListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/promotions [get]
+func (h *PromotionHandlers) ListPromotions(c *gin.Context) {
+	list, err := h.service.ListPromotions(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list promotions", zap.Error(err))
+		respondInternalError(c, "Failed to list promotions")
+		return
+	}
+	c.JSON(http.StatusOK, PromotionListResponse{Promotions: list})
+}
+
+// CreatePromotion handles POST /api/v1/admin/promotions
+// @Summary Create a promotion
+// @Description Creates a promotional credit campaign. Signup promotions are granted on KYC approval and first_deposit promotions on a user's first credited deposit; manual promotions are granted by an admin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreatePromotionRequest true "Promotion"
+// @Success 201 {object} entities.Promotion
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/promotions [post]
+func (h *PromotionHandlers) CreatePromotion(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	promotion, err := h.service.CreatePromotion(c.Request.Context(), &req, adminID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrPromotionCodeTaken):
+			respondError(c, http.StatusConflict, "PROMOTION_CODE_TAKEN", err.Error(), nil)
+		case errors.Is(err, promotions.ErrInvalidPromotion):
+			respondBadRequest(c, err.Error(), nil)
+		default:
+			h.logger.Error("Failed to create promotion", zap.Error(err))
+			respondInternalError(c, "Failed to create promotion")
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, promotion)
+}
+
+// UpdatePromotion handles PATCH /api/v1/admin/promotions/:id
+// @Summary Pause or resume a promotion
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Param request body entities.UpdatePromotionRequest true "Promotion state"
+// @Success 200 {object} entities.Promotion
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/promotions/{id} [patch]
+func (h *PromotionHandlers) UpdatePromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid promotion ID", nil)
+		return
+	}
+
+	var req entities.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	promotion, err := h.service.SetPromotionActive(c.Request.Context(), id, req.Active)
+	if err != nil {
+		if errors.Is(err, entities.ErrPromotionNotFound) {
+			respondNotFound(c, "Promotion not found")
+			return
+		}
+		h.logger.Error("Failed to update promotion", zap.Error(err))
+		respondInternalError(c, "Failed to update promotion")
+		return
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+// GrantPromotion handles POST /api/v1/admin/promotions/:id/grants
+// @Summary Grant a promotion to a user
+// @Description Funds the promotion's credit from the marketing budget. Fails when the budget cannot cover it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Param request body entities.GrantPromotionRequest true "Recipient"
+// @Success 201 {object} entities.PromotionGrant
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/promotions/{id}/grants [post]
+func (h *PromotionHandlers) GrantPromotion(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid promotion ID", nil)
+		return
+	}
+
+	var req entities.GrantPromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	grant, err := h.service.Grant(c.Request.Context(), id, req.UserID, adminID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrPromotionNotFound):
+			respondNotFound(c, "Promotion not found")
+		case errors.Is(err, entities.ErrPromotionInactive),
+			errors.Is(err, entities.ErrPromotionAlreadyGranted),
+			errors.Is(err, entities.ErrPromotionLimitReached):
+			respondError(c, http.StatusConflict, "PROMOTION_UNAVAILABLE", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to grant promotion",
+				zap.String("promotion_id", id.String()),
+				zap.String("user_id", req.UserID.String()),
+				zap.Error(err))
+			respondInternalError(c, "Failed to grant promotion")
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, grant)
+}
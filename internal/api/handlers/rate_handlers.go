@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/rates"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// RateHandlers expose historical exchange rate lookups and backfills to admins
+type RateHandlers struct {
+	service      *rates.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewRateHandlers creates a new exchange rate handlers instance
+func NewRateHandlers(service *rates.Service, auditService *adapters.AuditService, logger *zap.Logger) *RateHandlers {
+	return &RateHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// RateLookupResponse is a pair's rate at a point in time
+type RateLookupResponse struct {
+	Pair string          `json:"pair"`
+	At   time.Time       `json:"at"`
+	Rate decimal.Decimal `json:"rate"`
+}
+
+// GetRate handles GET /api/v1/admin/rates
+// @Summary Look up a historical exchange rate
+// @Description Returns the rate recorded nearest the given time, loading provider history if none is stored
+// @Tags admin
+// @Produce json
+// @Param pair query string true "Currency pair, e.g. USDC/USD"
+// @Param at query string false "RFC 3339 time; defaults to now"
+// @Success 200 {object} handlers.RateLookupResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rates [get]
+func (h *RateHandlers) GetRate(c *gin.Context) {
+	pair, err := entities.ParseCurrencyPair(c.Query("pair"))
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		if at, err = time.Parse(time.RFC3339, raw); err != nil {
+			respondBadRequest(c, "Invalid at: use RFC 3339", nil)
+			return
+		}
+	}
+
+	rate, err := h.service.RateAt(c.Request.Context(), pair, at)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrRateNotFound):
+			respondNotFound(c, err.Error())
+		case errors.Is(err, entities.ErrInvalidCurrencyPair):
+			respondBadRequest(c, err.Error(), nil)
+		default:
+			h.logger.Error("Failed to look up exchange rate", zap.String("pair", pair.String()), zap.Error(err))
+			respondInternalError(c, "Failed to look up exchange rate")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, RateLookupResponse{Pair: pair.String(), At: at, Rate: rate})
+}
+
+// BackfillRates handles POST /api/v1/admin/rates/backfill
+// @Summary Backfill historical exchange rates
+// @Description Loads the provider's rate history for a pair over a period. Rates already stored are kept.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.RateBackfillRequest true "Pair and period"
+// @Success 200 {object} entities.RateBackfillResult
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rates/backfill [post]
+func (h *RateHandlers) BackfillRates(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.RateBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	pair, err := entities.ParseCurrencyPair(req.Pair)
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+	if !req.From.Before(req.To) {
+		respondBadRequest(c, "from must be before to", nil)
+		return
+	}
+
+	result, err := h.service.Backfill(c.Request.Context(), pair, req.From, req.To)
+	if err != nil {
+		switch {
+		case errors.Is(err, rates.ErrNoProvider):
+			respondError(c, http.StatusServiceUnavailable, "PROVIDER_UNAVAILABLE", err.Error(), nil)
+		case errors.Is(err, entities.ErrInvalidCurrencyPair):
+			respondBadRequest(c, err.Error(), nil)
+		default:
+			h.logger.Error("Failed to backfill exchange rates", zap.String("pair", pair.String()), zap.Error(err))
+			respondInternalError(c, "Failed to backfill exchange rates")
+		}
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "rates_backfill", "exchange_rates", nil, map[string]interface{}{
+		"pair":   result.Pair,
+		"from":   result.From,
+		"to":     result.To,
+		"stored": result.Stored,
+	})
+	c.JSON(http.StatusOK, result)
+}
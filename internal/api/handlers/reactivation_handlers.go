@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/reactivation"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// ReactivationHandlers exposes self-service account reactivation and its
+// admin approval queue
+type ReactivationHandlers struct {
+	service  *reactivation.Service
+	userRepo *repositories.UserRepository
+	logger   *zap.Logger
+}
+
+// NewReactivationHandlers creates a new reactivation handlers instance
+func NewReactivationHandlers(service *reactivation.Service, userRepo *repositories.UserRepository, logger *zap.Logger) *ReactivationHandlers {
+	return &ReactivationHandlers{
+		service:  service,
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// RequestReactivation handles POST /api/v1/auth/reactivate
+// @Summary Request reactivation of a closed account
+// @Description Authenticates a deactivated account and starts identity re-verification. Returns the request in progress if one exists; poll by calling again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body entities.StartReactivationRequest true "Account credentials"
+// @Success 202 {object} entities.ReactivationRequest
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Router /api/v1/auth/reactivate [post]
+func (h *ReactivationHandlers) RequestReactivation(c *gin.Context) {
+	var req entities.StartReactivationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	// Active accounts and wrong passwords get the same answer as a bad login
+	user, err := h.userRepo.GetClosedAccountByEmail(c.Request.Context(), req.Email)
+	if err != nil || !h.userRepo.ValidatePassword(req.Password, user.PasswordHash) {
+		respondError(c, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email or password", nil)
+		return
+	}
+
+	request, err := h.service.Start(c.Request.Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrAccountNotClosed):
+			respondError(c, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email or password", nil)
+		case errors.Is(err, reactivation.ErrVerificationUnavailable):
+			respondError(c, http.StatusServiceUnavailable, "VERIFICATION_UNAVAILABLE", "Identity verification is unavailable. Please contact support.", nil)
+		default:
+			h.logger.Error("Failed to start reactivation", zap.String("user_id", user.ID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to start reactivation")
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, request)
+}
+
+// ListReactivations handles GET /api/v1/admin/reactivations
+// @Summary List account reactivation requests
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status (pending_verification, pending_approval, completed, rejected)"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.ReactivationListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/reactivations [get]
+func (h *ReactivationHandlers) ListReactivations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	list, err := h.service.List(c.Request.Context(), entities.ReactivationStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list reactivation requests", zap.Error(err))
+		respondInternalError(c, "Failed to list reactivation requests")
+		return
+	}
+	c.JSON(http.StatusOK, ReactivationListResponse{Reactivations: list})
+}
+
+// GetReactivation handles GET /api/v1/admin/reactivations/:id
+// @Summary Get an account reactivation request
+// @Tags admin
+// @Produce json
+// @Param id path string true "Reactivation request ID"
+// @Success 200 {object} entities.ReactivationRequest
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/reactivations/{id} [get]
+func (h *ReactivationHandlers) GetReactivation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid reactivation request ID", nil)
+		return
+	}
+
+	request, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondReviewError(c, err, "Failed to get reactivation request")
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveReactivation handles POST /api/v1/admin/reactivations/:id/approve
+// @Summary Approve reactivation of an account closed for cause
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Reactivation request ID"
+// @Param request body entities.ReviewReactivationRequest false "Review notes"
+// @Success 200 {object} entities.ReactivationRequest
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/reactivations/{id}/approve [post]
+func (h *ReactivationHandlers) ApproveReactivation(c *gin.Context) {
+	h.review(c, h.service.Approve, "Failed to approve reactivation")
+}
+
+// RejectReactivation handles POST /api/v1/admin/reactivations/:id/reject
+// @Summary Reject an account reactivation request
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Reactivation request ID"
+// @Param request body entities.ReviewReactivationRequest false "Review notes"
+// @Success 200 {object} entities.ReactivationRequest
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/reactivations/{id}/reject [post]
+func (h *ReactivationHandlers) RejectReactivation(c *gin.Context) {
+	h.review(c, h.service.Reject, "Failed to reject reactivation")
+}
+
+func (h *ReactivationHandlers) review(c *gin.Context, decide func(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.ReactivationRequest, error), failure string) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid reactivation request ID", nil)
+		return
+	}
+
+	var req entities.ReviewReactivationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
+	request, err := decide(c.Request.Context(), id, adminID, req.Notes)
+	if err != nil {
+		h.respondReviewError(c, err, failure)
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+func (h *ReactivationHandlers) respondReviewError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, entities.ErrReactivationNotFound):
+		respondNotFound(c, "Reactivation request not found")
+	case errors.Is(err, entities.ErrReactivationNotPending):
+		respondError(c, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+	default:
+		h.logger.Error(failure, zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/recipients"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// RecipientHandlers serves the withdrawal address book and the admin
+// review of destinations shared across accounts
+type RecipientHandlers struct {
+	service      *recipients.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewRecipientHandlers creates a new recipient handlers instance
+func NewRecipientHandlers(service *recipients.Service, auditService *adapters.AuditService, logger *zap.Logger) *RecipientHandlers {
+	return &RecipientHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListRecipients handles GET /api/v1/recipients
+// @Summary List saved recipients
+// @Description Returns the user's saved withdrawal destinations, most recently used first.
+// @Tags recipients
+// @Produce json
+// @Success 200 {object} handlers.RecipientListResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients [get]
+func (h *RecipientHandlers) ListRecipients(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	list, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list recipients", zap.Error(err), zap.String("user_id", userID.String()))
+		respondInternalError(c, "Failed to list recipients")
+		return
+	}
+	if list == nil {
+		list = []*entities.WithdrawalRecipient{}
+	}
+	c.JSON(http.StatusOK, RecipientListResponse{Recipients: list})
+}
+
+// CreateRecipient handles POST /api/v1/recipients
+// @Summary Save a recipient
+// @Description Saves a crypto address or US bank account. New recipients cannot receive funds until their first-use hold ends.
+// @Tags recipients
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateRecipientRequest true "Recipient details"
+// @Success 201 {object} entities.WithdrawalRecipient
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients [post]
+func (h *RecipientHandlers) CreateRecipient(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	var req entities.CreateRecipientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, recipients.ErrInvalidDestination), errors.Is(err, recipients.ErrTooManyRecipients):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, entities.ErrRecipientExists):
+			respondError(c, http.StatusConflict, "RECIPIENT_EXISTS", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to create recipient", zap.Error(err), zap.String("user_id", userID.String()))
+			respondInternalError(c, "Failed to save recipient")
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, recipient)
+}
+
+// GetRecipient handles GET /api/v1/recipients/:id
+// @Summary Get a saved recipient
+// @Tags recipients
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients/{id} [get]
+func (h *RecipientHandlers) GetRecipient(c *gin.Context) {
+	userID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+
+	recipient, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondRecipientError(c, err, "Failed to get recipient")
+		return
+	}
+	c.JSON(http.StatusOK, recipient)
+}
+
+// UpdateRecipient handles PATCH /api/v1/recipients/:id
+// @Summary Rename a saved recipient
+// @Tags recipients
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Param request body entities.UpdateRecipientRequest true "New label"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients/{id} [patch]
+func (h *RecipientHandlers) UpdateRecipient(c *gin.Context) {
+	userID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+	var req entities.UpdateRecipientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.service.Rename(c.Request.Context(), userID, id, req.Label)
+	if err != nil {
+		h.respondRecipientError(c, err, "Failed to update recipient")
+		return
+	}
+	c.JSON(http.StatusOK, recipient)
+}
+
+// DeleteRecipient handles DELETE /api/v1/recipients/:id
+// @Summary Remove a saved recipient
+// @Tags recipients
+// @Param id path string true "Recipient ID"
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients/{id} [delete]
+func (h *RecipientHandlers) DeleteRecipient(c *gin.Context) {
+	userID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Remove(c.Request.Context(), userID, id); err != nil {
+		h.respondRecipientError(c, err, "Failed to remove recipient")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyRecipientAccount handles POST /api/v1/recipients/:id/verify-account
+// @Summary Verify a saved bank account
+// @Description Checks with the bank-linking provider that the account is open and held in the user's name, and records the result on the recipient. ACH payouts above a configured amount need a verified account.
+// @Tags recipients
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients/{id}/verify-account [post]
+func (h *RecipientHandlers) VerifyRecipientAccount(c *gin.Context) {
+	userID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+
+	recipient, err := h.service.VerifyAccount(c.Request.Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, recipients.ErrNotBankRecipient):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, recipients.ErrAccountCheckUnavailable):
+			respondError(c, http.StatusServiceUnavailable, "ACCOUNT_CHECK_UNAVAILABLE", err.Error(), nil)
+		default:
+			h.respondRecipientError(c, err, "Failed to verify bank account")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, recipient)
+}
+
+// StartAddressCheck handles POST /api/v1/recipients/:id/address-check
+// @Summary Start verifying a saved crypto address
+// @Description Proves the user controls the address before larger withdrawals to it. micro_deposit sends a small random USDC amount to the address for the user to confirm; signature returns address_check_message for the user to sign with the address's key. A micro-deposit cannot be replaced until it expires.
+// @Tags recipients
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Param request body entities.StartAddressCheckRequest true "Verification method"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients/{id}/address-check [post]
+func (h *RecipientHandlers) StartAddressCheck(c *gin.Context) {
+	userID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+	var req entities.StartAddressCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.service.StartAddressCheck(c.Request.Context(), userID, id, req.Method)
+	if err != nil {
+		h.respondAddressCheckError(c, err, "Failed to start address verification")
+		return
+	}
+	c.JSON(http.StatusOK, recipient)
+}
+
+// ConfirmAddressCheck handles POST /api/v1/recipients/:id/address-check/confirm
+// @Summary Confirm a saved crypto address
+// @Description Completes the pending address verification with the exact amount the micro-deposit delivered, or the signature of the verification message. Each answer uses one of a limited number of attempts.
+// @Tags recipients
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Param request body entities.ConfirmAddressCheckRequest true "Amount received or signature"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Failure 422 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/recipients/{id}/address-check/confirm [post]
+func (h *RecipientHandlers) ConfirmAddressCheck(c *gin.Context) {
+	userID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+	var req entities.ConfirmAddressCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.service.ConfirmAddressCheck(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.respondAddressCheckError(c, err, "Failed to confirm address verification")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &userID, "verify_withdrawal_address", "withdrawal_recipient", nil, map[string]interface{}{
+		"recipient_id": id.String(),
+		"method":       string(recipient.AddressCheckMethod),
+	})
+	c.JSON(http.StatusOK, recipient)
+}
+
+// ListSharedDestinations handles GET /api/v1/admin/recipients/shared
+// @Summary List destinations shared across accounts
+// @Description Returns withdrawal destinations saved by several accounts, most widely shared first, with the recipients holding each.
+// @Tags admin
+// @Produce json
+// @Param min_users query int false "Minimum number of accounts (default 2)"
+// @Param limit query int false "Maximum destinations (default 50, max 200)"
+// @Success 200 {object} handlers.SharedDestinationListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/recipients/shared [get]
+func (h *RecipientHandlers) ListSharedDestinations(c *gin.Context) {
+	minUsers, _ := strconv.Atoi(c.DefaultQuery("min_users", "2"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	shared, err := h.service.ListShared(c.Request.Context(), minUsers, limit)
+	if err != nil {
+		h.logger.Error("Failed to list shared destinations", zap.Error(err))
+		respondInternalError(c, "Failed to list shared destinations")
+		return
+	}
+	if shared == nil {
+		shared = []*entities.SharedDestination{}
+	}
+	c.JSON(http.StatusOK, SharedDestinationListResponse{Destinations: shared})
+}
+
+// FlagRecipient handles POST /api/v1/admin/recipients/:id/flag
+// @Summary Block withdrawals to a recipient
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Param request body entities.FlagRecipientRequest true "Reason"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/recipients/{id}/flag [post]
+func (h *RecipientHandlers) FlagRecipient(c *gin.Context) {
+	adminID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+	var req entities.FlagRecipientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.service.Flag(c.Request.Context(), adminID, id, req.Reason)
+	if err != nil {
+		h.respondRecipientError(c, err, "Failed to flag recipient")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "flag_withdrawal_recipient", "withdrawal_recipient", nil, map[string]interface{}{
+		"recipient_id": id.String(),
+		"user_id":      recipient.UserID.String(),
+		"reason":       req.Reason,
+	})
+	c.JSON(http.StatusOK, recipient)
+}
+
+// UnflagRecipient handles POST /api/v1/admin/recipients/:id/unflag
+// @Summary Lift a recipient block
+// @Tags admin
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Success 200 {object} entities.WithdrawalRecipient
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/recipients/{id}/unflag [post]
+func (h *RecipientHandlers) UnflagRecipient(c *gin.Context) {
+	adminID, id, ok := h.userAndRecipientID(c)
+	if !ok {
+		return
+	}
+
+	recipient, err := h.service.Unflag(c.Request.Context(), adminID, id)
+	if err != nil {
+		h.respondRecipientError(c, err, "Failed to unflag recipient")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "unflag_withdrawal_recipient", "withdrawal_recipient", nil, map[string]interface{}{
+		"recipient_id": id.String(),
+		"user_id":      recipient.UserID.String(),
+	})
+	c.JSON(http.StatusOK, recipient)
+}
+
+// userAndRecipientID reads the caller and the :id path parameter, writing
+// the error response when either is missing or malformed
+func (h *RecipientHandlers) userAndRecipientID(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid recipient ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+func (h *RecipientHandlers) respondAddressCheckError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, recipients.ErrNotCryptoRecipient):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, recipients.ErrAddressCheckUnavailable):
+		respondError(c, http.StatusServiceUnavailable, "ADDRESS_CHECK_UNAVAILABLE", err.Error(), nil)
+	case errors.Is(err, recipients.ErrRecipientFlagged):
+		respondError(c, http.StatusForbidden, "RECIPIENT_FLAGGED", err.Error(), nil)
+	case errors.Is(err, recipients.ErrMicroDepositPending):
+		respondError(c, http.StatusConflict, "MICRO_DEPOSIT_PENDING", err.Error(), nil)
+	case errors.Is(err, recipients.ErrAddressCheckNotPending):
+		respondError(c, http.StatusConflict, "ADDRESS_CHECK_NOT_PENDING", err.Error(), nil)
+	case errors.Is(err, recipients.ErrAddressCheckExpired):
+		respondError(c, http.StatusConflict, "ADDRESS_CHECK_EXPIRED", err.Error(), nil)
+	case errors.Is(err, recipients.ErrAddressCheckMismatch):
+		respondError(c, http.StatusUnprocessableEntity, "ADDRESS_CHECK_MISMATCH", err.Error(), nil)
+	case errors.Is(err, recipients.ErrAddressCheckFailed):
+		respondError(c, http.StatusUnprocessableEntity, "ADDRESS_CHECK_FAILED", err.Error(), nil)
+	default:
+		h.respondRecipientError(c, err, message)
+	}
+}
+
+func (h *RecipientHandlers) respondRecipientError(c *gin.Context, err error, message string) {
+	if errors.Is(err, entities.ErrRecipientNotFound) {
+		respondNotFound(c, "Recipient not found")
+		return
+	}
+	h.logger.Error(message, zap.Error(err))
+	respondInternalError(c, message)
+}
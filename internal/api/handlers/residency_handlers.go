@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/residency"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// ResidencyHandlers let admins see and set the region a user's data is kept
+// in and find files stored outside it
+type ResidencyHandlers struct {
+	service      *residency.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewResidencyHandlers creates new data residency handlers
+func NewResidencyHandlers(service *residency.Service, auditService *adapters.AuditService, logger *zap.Logger) *ResidencyHandlers {
+	return &ResidencyHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// GetUserResidency handles GET /api/v1/admin/residency/users/:id
+// @Summary Get a user's data region
+// @Description The region the user's KYC files and statements are kept in. Users not yet tagged show the region their country maps to, with tagged false.
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} entities.UserResidency
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/residency/users/{id} [get]
+func (h *ResidencyHandlers) GetUserResidency(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid user ID", nil)
+		return
+	}
+	residency, err := h.service.Residency(c.Request.Context(), userID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get user residency")
+		return
+	}
+	c.JSON(http.StatusOK, residency)
+}
+
+// SetUserResidency handles PUT /api/v1/admin/residency/users/:id
+// @Summary Pin a user to a data region
+// @Description Tags the user with a region that is kept if their country changes. New uploads and documents follow the region; files already stored elsewhere are listed by the out-of-region report.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body entities.SetResidencyRequest true "Region and reason"
+// @Success 200 {object} entities.UserResidency
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/residency/users/{id} [put]
+func (h *ResidencyHandlers) SetUserResidency(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid user ID", nil)
+		return
+	}
+	var req entities.SetResidencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	residency, err := h.service.SetRegion(c.Request.Context(), userID, &req, adminID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to set user residency")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "set_data_residency", "user", nil, map[string]interface{}{
+		"user_id": userID,
+		"region":  residency.Region,
+		"reason":  residency.Reason,
+	})
+	c.JSON(http.StatusOK, residency)
+}
+
+// ListOutOfRegion handles GET /api/v1/admin/residency/violations
+// @Summary List records stored out of region
+// @Description Uploads and per-user documents stored outside the region their user's data must stay in, newest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.ResidencyViolationListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/residency/violations [get]
+func (h *ResidencyHandlers) ListOutOfRegion(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	violations, err := h.service.OutOfRegion(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list out-of-region records", zap.Error(err))
+		respondInternalError(c, "Failed to list out-of-region records")
+		return
+	}
+	c.JSON(http.StatusOK, ResidencyViolationListResponse{Violations: violations})
+}
+
+func (h *ResidencyHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case isUserNotFoundError(err):
+		respondNotFound(c, "User not found")
+	case errors.Is(err, entities.ErrInvalidResidency):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+	"github.com/stack-service/stack_service/internal/domain/services/apikey"
+	"github.com/stack-service/stack_service/internal/domain/services/restoredrill"
+	"github.com/stack-service/stack_service/internal/domain/services/retention"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Response envelopes for list endpoints. Handlers return these instead of
+// ad-hoc maps so the generated OpenAPI spec and clients match the payloads.
+
+// SubscriptionPlansResponse lists the premium plans and the free tier
+type SubscriptionPlansResponse struct {
+	Plans    []*entities.SubscriptionPlan `json:"plans"`
+	FreeTier entities.Entitlements        `json:"free_tier"`
+}
+
+// InvoiceListResponse is a page of the user's invoices
+type InvoiceListResponse struct {
+	Invoices []*entities.Invoice `json:"invoices"`
+}
+
+// RetentionPoliciesResponse lists the data retention policies
+type RetentionPoliciesResponse struct {
+	Policies []retention.Policy `json:"policies"`
+}
+
+// RetentionRunsResponse lists recent retention runs
+type RetentionRunsResponse struct {
+	Runs []retention.RunRecord `json:"runs"`
+}
+
+// RecipientListResponse lists the user's saved withdrawal recipients
+type RecipientListResponse struct {
+	Recipients []*entities.WithdrawalRecipient `json:"recipients"`
+}
+
+// LinkedWalletListResponse lists the wallets linked to the user's Due account
+type LinkedWalletListResponse struct {
+	Wallets []*entities.LinkedWallet `json:"wallets"`
+}
+
+// DelegateGrantListResponse lists the read-only access the user has granted
+type DelegateGrantListResponse struct {
+	Grants []*entities.DelegateGrant `json:"grants"`
+}
+
+// DelegateAccessLogResponse lists what a delegate viewed, newest first
+type DelegateAccessLogResponse struct {
+	Events []*entities.DelegateAccessEvent `json:"events"`
+}
+
+// SharedDestinationListResponse lists destinations saved by several accounts
+type SharedDestinationListResponse struct {
+	Destinations []*entities.SharedDestination `json:"destinations"`
+}
+
+// WebhookEndpointListResponse lists the outbound webhook endpoints
+type WebhookEndpointListResponse struct {
+	Endpoints []*entities.WebhookEndpoint `json:"endpoints"`
+}
+
+// WebhookDeliveryListResponse is a page of webhook deliveries
+type WebhookDeliveryListResponse struct {
+	Deliveries []*entities.WebhookDelivery `json:"deliveries"`
+}
+
+// StuckOrderListResponse lists orders past the stuck-order SLA
+type StuckOrderListResponse struct {
+	Orders []*entities.StuckOrder `json:"orders"`
+	SLA    string                 `json:"sla"`
+}
+
+// OrderInterventionListResponse lists the admin interventions on an order
+type OrderInterventionListResponse struct {
+	Interventions []*entities.OrderIntervention `json:"interventions"`
+}
+
+// HTTPCaptureListResponse lists captures without their bodies
+type HTTPCaptureListResponse struct {
+	Captures []*entities.HTTPCapture `json:"captures"`
+}
+
+// ShadowRouteListResponse lists the shadowed routes and how their
+// candidates compare
+type ShadowRouteListResponse struct {
+	Routes []entities.ShadowRouteStats `json:"routes"`
+}
+
+// HTTPCaptureTargetListResponse lists the active capture targets
+type HTTPCaptureTargetListResponse struct {
+	Targets []*entities.HTTPCaptureTarget `json:"targets"`
+}
+
+// CustodialAccountListResponse lists custodial accounts
+type CustodialAccountListResponse struct {
+	Accounts []*entities.CustodialAccount `json:"accounts"`
+}
+
+// CircleSubscriptionListResponse lists the Circle notification subscriptions
+type CircleSubscriptionListResponse struct {
+	Subscriptions []entities.CircleNotificationSubscription `json:"subscriptions"`
+}
+
+// WorkerListResponse lists background workers and their state
+type WorkerListResponse struct {
+	Workers []workerstatus.Status `json:"workers"`
+}
+
+// WalletBackfillListResponse lists wallet backfill runs
+type WalletBackfillListResponse struct {
+	Backfills []*entities.WalletBackfill `json:"backfills"`
+}
+
+// RestoreDrillListResponse lists backup restore drill reports
+type RestoreDrillListResponse struct {
+	Drills []restoredrill.Report `json:"drills"`
+}
+
+// ReactivationListResponse is a page of account reactivation requests
+type ReactivationListResponse struct {
+	Reactivations []*entities.ReactivationRequest `json:"reactivations"`
+}
+
+// PromotionListResponse lists promotions
+type PromotionListResponse struct {
+	Promotions []*entities.Promotion `json:"promotions"`
+}
+
+// OpsDigestSendResponse is the digest that was sent and how many emails went out
+type OpsDigestSendResponse struct {
+	Digest     *entities.OpsDigest `json:"digest"`
+	EmailsSent int                 `json:"emails_sent"`
+}
+
+// JurisdictionRulesResponse lists the country rules and the features they gate
+type JurisdictionRulesResponse struct {
+	Rules    []*entities.CountryRule        `json:"rules"`
+	Features []entities.JurisdictionFeature `json:"features"`
+}
+
+// ChainListResponse lists chains from the registry
+type ChainListResponse struct {
+	Chains []entities.ChainInfo `json:"chains"`
+}
+
+// ChainAddressValidationResponse reports whether an address is valid on a
+// chain. Reason, set only for invalid addresses, is checksum, malformed or
+// empty.
+type ChainAddressValidationResponse struct {
+	Chain      string `json:"chain"`
+	Address    string `json:"address"`
+	Valid      bool   `json:"valid"`
+	Normalized string `json:"normalized,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// AuditAnchorListResponse lists recent audit chain anchors
+type AuditAnchorListResponse struct {
+	Anchors []adapters.AuditChainAnchor `json:"anchors"`
+}
+
+// AIArtifactListResponse is a page of the user's stored AI artifacts
+type AIArtifactListResponse struct {
+	Artifacts []aiartifacts.Artifact `json:"artifacts"`
+}
+
+// AdminCaseListResponse is a page of admin cases
+type AdminCaseListResponse struct {
+	Cases []*entities.AdminCase `json:"cases"`
+}
+
+// OnboardingJobListResponse lists onboarding jobs that need an operator
+type OnboardingJobListResponse struct {
+	Jobs       []*entities.OnboardingJob `json:"jobs"`
+	StuckAfter string                    `json:"stuck_after"`
+}
+
+// KYCDocumentListResponse shows each document of the user's latest KYC
+// submission and which ones still have to be replaced
+type KYCDocumentListResponse struct {
+	SubmissionID        string                 `json:"submission_id"`
+	Status              entities.KYCStatus     `json:"status"`
+	Documents           []entities.KYCDocument `json:"documents"`
+	PendingReplacements []string               `json:"pending_replacements"`
+}
+
+// GeoOverrideListResponse lists the geo override list
+type GeoOverrideListResponse struct {
+	Overrides []*entities.GeoOverride `json:"overrides"`
+}
+
+// GeoAccessEventListResponse lists a user's recent signup and login locations
+type GeoAccessEventListResponse struct {
+	Events []*entities.GeoAccessEvent `json:"events"`
+}
+
+// ExperimentListResponse lists onboarding experiments with their results
+type ExperimentListResponse struct {
+	Experiments []*entities.Experiment `json:"experiments"`
+}
+
+// IntegrityRunListResponse lists recent integrity runs
+type IntegrityRunListResponse struct {
+	Runs []*entities.IntegrityReport `json:"runs"`
+}
+
+// TaxLotListResponse lists tax lots
+type TaxLotListResponse struct {
+	Lots []*entities.TaxLot `json:"lots"`
+}
+
+// TaxLotAdjustmentListResponse lists corrections made to a tax lot
+type TaxLotAdjustmentListResponse struct {
+	Adjustments []*entities.TaxLotAdjustment `json:"adjustments"`
+}
+
+// WebhookArchiveListResponse lists archived webhooks matching a search
+type WebhookArchiveListResponse struct {
+	Webhooks []*entities.ArchivedWebhook `json:"webhooks"`
+}
+
+// DeveloperScopeListResponse lists the scopes sandbox keys can be issued with
+type DeveloperScopeListResponse struct {
+	Scopes []string `json:"scopes"`
+}
+
+// DeveloperKeyListResponse lists a developer tenant's API keys
+type DeveloperKeyListResponse struct {
+	Keys []*apikey.APIKey `json:"keys"`
+}
+
+// SandboxIdentityResponse describes the sandbox key a request was made with
+type SandboxIdentityResponse struct {
+	APIKeyID    uuid.UUID `json:"api_key_id"`
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Environment string    `json:"environment"`
+	Scopes      []string  `json:"scopes"`
+}
+
+// PIIRecordResponse is the raw data behind a PII vault token
+type PIIRecordResponse struct {
+	Token string          `json:"token"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// PIIAccessLogResponse lists recent uses of a PII vault record
+type PIIAccessLogResponse struct {
+	Entries []*entities.PIIAccessEntry `json:"entries"`
+}
+
+// SuspenseItemListResponse lists deposits held in suspense
+type SuspenseItemListResponse struct {
+	Items []*entities.SuspenseItem `json:"items"`
+}
+
+// AlertDeliveryListResponse lists alerts sent to on-call channels
+type AlertDeliveryListResponse struct {
+	Deliveries []*entities.AlertDelivery `json:"deliveries"`
+}
+
+// LogLevelListResponse lists the modules whose log level can be changed
+type LogLevelListResponse struct {
+	Modules []logger.ModuleLevel `json:"modules"`
+}
+
+// ResidencyViolationListResponse lists records stored outside their user's data region
+type ResidencyViolationListResponse struct {
+	Violations []*entities.ResidencyViolation `json:"violations"`
+}
+
+// RewardRuleListResponse lists rewards earning rules
+type RewardRuleListResponse struct {
+	Rules []*entities.RewardRule `json:"rules"`
+}
+
+// RewardListResponse lists rewards
+type RewardListResponse struct {
+	Rewards []*entities.Reward `json:"rewards"`
+}
+
+// PortfolioShareListResponse lists a user's portfolio share links
+type PortfolioShareListResponse struct {
+	Links []*entities.PortfolioShareLink `json:"links"`
+}
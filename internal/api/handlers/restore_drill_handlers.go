@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/services/restoredrill"
+	"go.uber.org/zap"
+)
+
+// RestoreDrillHandlers exposes backup restore verification to administrators
+type RestoreDrillHandlers struct {
+	drillService *restoredrill.Service
+	logger       *zap.Logger
+}
+
+// NewRestoreDrillHandlers creates a new restore drill handlers instance
+func NewRestoreDrillHandlers(drillService *restoredrill.Service, logger *zap.Logger) *RestoreDrillHandlers {
+	return &RestoreDrillHandlers{
+		drillService: drillService,
+		logger:       logger,
+	}
+}
+
+// StartRestoreDrill handles POST /api/v1/admin/backups/restore-drills
+// @Summary Start a backup restore drill
+// @Description Restores the latest backup into the scratch database in the background, then checks ledger invariants and row counts against production
+// @Tags admin
+// @Produce json
+// @Success 202 {object} restoredrill.Report
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/backups/restore-drills [post]
+func (h *RestoreDrillHandlers) StartRestoreDrill(c *gin.Context) {
+	report, err := h.drillService.Start(c.Request.Context())
+	if err != nil {
+		h.logger.Warn("Restore drill not started", zap.Error(err))
+		respondError(c, http.StatusConflict, "RESTORE_DRILL_UNAVAILABLE", err.Error(), nil)
+		return
+	}
+	c.JSON(http.StatusAccepted, report)
+}
+
+// ListRestoreDrills handles GET /api/v1/admin/backups/restore-drills
+// @Summary List recent restore drills
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum drills to return"
+// @Success 200 {object} handlers.RestoreDrillListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/backups/restore-drills [get]
+func (h *RestoreDrillHandlers) ListRestoreDrills(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	reports, err := h.drillService.ListReports(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list restore drills", zap.Error(err))
+		respondInternalError(c, "Failed to list restore drills")
+		return
+	}
+	c.JSON(http.StatusOK, RestoreDrillListResponse{Drills: reports})
+}
+
+// GetRestoreDrill handles GET /api/v1/admin/backups/restore-drills/:id
+// @Summary Get a restore drill report
+// @Tags admin
+// @Produce json
+// @Param id path string true "Drill ID"
+// @Success 200 {object} restoredrill.Report
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/backups/restore-drills/{id} [get]
+func (h *RestoreDrillHandlers) GetRestoreDrill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid drill ID", nil)
+		return
+	}
+	report, err := h.drillService.GetReport(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get restore drill", zap.Error(err))
+		respondInternalError(c, "Failed to get restore drill")
+		return
+	}
+	if report == nil {
+		respondNotFound(c, "Restore drill not found")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/retention"
+	"go.uber.org/zap"
+)
+
+// RetentionHandlers exposes data retention policies and runs to administrators
+type RetentionHandlers struct {
+	retentionService *retention.Service
+	logger           *zap.Logger
+}
+
+// NewRetentionHandlers creates a new retention handlers instance
+func NewRetentionHandlers(retentionService *retention.Service, logger *zap.Logger) *RetentionHandlers {
+	return &RetentionHandlers{
+		retentionService: retentionService,
+		logger:           logger,
+	}
+}
+
+// ListPolicies handles GET /api/v1/admin/retention/policies
+// @Summary List data retention policies
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.RetentionPoliciesResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/retention/policies [get]
+func (h *RetentionHandlers) ListPolicies(c *gin.Context) {
+	c.JSON(http.StatusOK, RetentionPoliciesResponse{Policies: h.retentionService.Policies()})
+}
+
+// RunRetention handles POST /api/v1/admin/retention/run
+// @Summary Run data retention policies
+// @Description Applies every retention policy once. Defaults to a dry run that only reports eligible rows.
+// @Tags admin
+// @Produce json
+// @Param dry_run query bool false "Report without purging (default true)"
+// @Success 200 {object} retention.Report
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/retention/run [post]
+func (h *RetentionHandlers) RunRetention(c *gin.Context) {
+	dryRun := true
+	if raw := c.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondBadRequest(c, "dry_run must be a boolean", nil)
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.retentionService.Run(c.Request.Context(), dryRun)
+	if err != nil {
+		h.logger.Warn("Retention run rejected", zap.Error(err))
+		respondError(c, http.StatusConflict, "RETENTION_IN_PROGRESS", err.Error(), nil)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ListRuns handles GET /api/v1/admin/retention/runs
+// @Summary List recent retention runs
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum runs to return"
+// @Success 200 {object} handlers.RetentionRunsResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/retention/runs [get]
+func (h *RetentionHandlers) ListRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	runs, err := h.retentionService.ListRuns(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list retention runs", zap.Error(err))
+		respondInternalError(c, "Failed to list retention runs")
+		return
+	}
+	c.JSON(http.StatusOK, RetentionRunsResponse{Runs: runs})
+}
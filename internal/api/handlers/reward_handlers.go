@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/rewards"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// RewardHandlers expose each user's rewards and their redemption, and the
+// earning rules and risk review queue to admins
+type RewardHandlers struct {
+	service      *rewards.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewRewardHandlers creates new rewards handlers
+func NewRewardHandlers(service *rewards.Service, auditService *adapters.AuditService, logger *zap.Logger) *RewardHandlers {
+	return &RewardHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// GetMyRewards handles GET /api/v1/rewards
+// @Summary Get rewards
+// @Description Returns the user's rewards balance and recent rewards and redemptions. Pending rewards become available when their hold period ends and are forfeited if the basket is sold before then.
+// @Tags rewards
+// @Produce json
+// @Success 200 {object} entities.RewardSummary
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/rewards [get]
+func (h *RewardHandlers) GetMyRewards(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	summary, err := h.service.Summary(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get rewards", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get rewards")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// RedeemRewards handles POST /api/v1/rewards/redemptions
+// @Summary Invest rewards in a basket
+// @Description Moves available rewards to the user's balance and places a buy order for the amount in the basket. Users in paper mode buy on paper and keep their rewards.
+// @Tags rewards
+// @Accept json
+// @Produce json
+// @Param request body entities.RedeemRewardsRequest true "Basket and amount"
+// @Success 201 {object} entities.RewardRedemption
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 403 {object} entities.ErrorResponse "Trading unavailable in the user's country or consents outstanding"
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/rewards/redemptions [post]
+func (h *RewardHandlers) RedeemRewards(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.RedeemRewardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	redemption, err := h.service.Redeem(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, rewards.ErrInvalidRedemption):
+			respondBadRequest(c, err.Error(), nil)
+		case errors.Is(err, entities.ErrInsufficientRewards):
+			respondError(c, http.StatusConflict, "INSUFFICIENT_REWARDS", err.Error(), nil)
+		case errors.Is(err, investing.ErrInvalidAmount):
+			respondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Invalid order amount", nil)
+		case errors.Is(err, entities.ErrSpendingLimitReached):
+			respondError(c, http.StatusForbidden, "SPENDING_LIMIT_REACHED", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to redeem rewards", zap.String("user_id", userID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to redeem rewards")
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, redemption)
+}
+
+// ListRules handles GET /api/v1/admin/rewards/rules
+// @Summary List reward earning rules
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.RewardRuleListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rewards/rules [get]
+func (h *RewardHandlers) ListRules(c *gin.Context) {
+	rules, err := h.service.ListRules(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list reward rules", zap.Error(err))
+		respondInternalError(c, "Failed to list reward rules")
+		return
+	}
+	c.JSON(http.StatusOK, RewardRuleListResponse{Rules: rules})
+}
+
+// CreateRule handles POST /api/v1/admin/rewards/rules
+// @Summary Create a reward earning rule
+// @Description Earns rate times the order amount back on each filled buy order; recurring_investment rules only earn on auto-sweep orders. Rewards are capped per order by max_per_order and per user per month by config.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateRewardRuleRequest true "Rule"
+// @Success 201 {object} entities.RewardRule
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rewards/rules [post]
+func (h *RewardHandlers) CreateRule(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateRewardRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.CreateRule(c.Request.Context(), &req, adminID)
+	if err != nil {
+		if errors.Is(err, rewards.ErrInvalidRewardRule) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to create reward rule", zap.Error(err))
+		respondInternalError(c, "Failed to create reward rule")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "create_reward_rule", "reward_rule", nil, map[string]interface{}{
+		"rule_id": rule.ID,
+		"trigger": rule.Trigger,
+		"unit":    rule.Unit,
+		"rate":    rule.Rate.String(),
+	})
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule handles PATCH /api/v1/admin/rewards/rules/:id
+// @Summary Pause or resume a reward earning rule
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body entities.UpdateRewardRuleRequest true "Rule state"
+// @Success 200 {object} entities.RewardRule
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rewards/rules/{id} [patch]
+func (h *RewardHandlers) UpdateRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid rule ID", nil)
+		return
+	}
+
+	var req entities.UpdateRewardRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.SetRuleActive(c.Request.Context(), id, req.Active)
+	if err != nil {
+		if errors.Is(err, entities.ErrRewardRuleNotFound) {
+			respondNotFound(c, "Reward rule not found")
+			return
+		}
+		h.logger.Error("Failed to update reward rule", zap.Error(err))
+		respondInternalError(c, "Failed to update reward rule")
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// ListHeldRewards handles GET /api/v1/admin/rewards/held
+// @Summary List rewards held for review
+// @Description Rewards the risk engine scored at or above the configured threshold, oldest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.RewardListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rewards/held [get]
+func (h *RewardHandlers) ListHeldRewards(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	held, err := h.service.ListHeld(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list held rewards", zap.Error(err))
+		respondInternalError(c, "Failed to list held rewards")
+		return
+	}
+	if held == nil {
+		held = []*entities.Reward{}
+	}
+	c.JSON(http.StatusOK, RewardListResponse{Rewards: held})
+}
+
+// ReviewReward handles POST /api/v1/admin/rewards/:id/review
+// @Summary Release or forfeit a held reward
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Reward ID"
+// @Param request body entities.ReviewRewardRequest true "Decision"
+// @Success 200 {object} entities.Reward
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/rewards/{id}/review [post]
+func (h *RewardHandlers) ReviewReward(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid reward ID", nil)
+		return
+	}
+
+	var req entities.ReviewRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	reward, err := h.service.Review(c.Request.Context(), id, &req, adminID)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrRewardNotFound):
+			respondNotFound(c, "Reward not found")
+		case errors.Is(err, entities.ErrRewardNotHeld):
+			respondError(c, http.StatusConflict, "REWARD_NOT_HELD", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to review reward", zap.Error(err))
+			respondInternalError(c, "Failed to review reward")
+		}
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "review_reward", "reward", nil, map[string]interface{}{
+		"reward_id": reward.ID,
+		"approve":   req.Approve,
+		"reason":    req.Reason,
+		"user_id":   reward.UserID,
+		"amount":    reward.Amount.String(),
+	})
+	c.JSON(http.StatusOK, reward)
+}
@@ -71,8 +71,7 @@ func NewSecurityAdminHandlers(
 }
 
 func (h *SecurityAdminHandlers) CreateAdmin(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	var req entities.CreateAdminRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -141,7 +140,7 @@ func (h *SecurityAdminHandlers) CreateAdmin(c *gin.Context) {
 
 	exists, err := h.emailExistsHelper(ctx, req.Email)
 	if err != nil {
-		h.logger.Error("failed to check email existence", zap.Error(err), zap.String("email", req.Email))
+		h.logger.Error("failed to check email existence", zap.Error(err), logger.Email(req.Email))
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "INTERNAL_ERROR",
 			"message": "Failed to process request",
@@ -253,8 +252,7 @@ func (h *SecurityAdminHandlers) CreateAdmin(c *gin.Context) {
 }
 
 func (h *SecurityAdminHandlers) GetAllUsers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	limit := 50
 	if v := strings.TrimSpace(c.DefaultQuery("limit", "50")); v != "" {
@@ -367,8 +365,7 @@ func (h *SecurityAdminHandlers) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	query := `
 		SELECT id, email, role, is_active, onboarding_status, kyc_status, last_login_at, created_at, updated_at
@@ -433,19 +430,27 @@ func (h *SecurityAdminHandlers) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
+
+	closureReason := req.ClosureReason
+	if closureReason == "" {
+		closureReason = entities.ClosureAdmin
+	}
 
+	// Activating clears any closure; suspending records why
 	query := `
 		UPDATE users
-		SET is_active = $1, updated_at = $2
+		SET is_active = $1, updated_at = $2,
+			closed_at = CASE WHEN $1 THEN NULL WHEN is_active THEN $2 ELSE closed_at END,
+			closure_reason = CASE WHEN $1 THEN NULL ELSE $4 END,
+			closure_notes = CASE WHEN $1 THEN NULL ELSE $5 END
 		WHERE id = $3
 		RETURNING id, email, role, is_active, onboarding_status, kyc_status, last_login_at, created_at, updated_at`
 
 	var resp entities.AdminUserResponse
 	var lastLogin sql.NullTime
 
-	err = h.db.QueryRowContext(ctx, query, req.IsActive, time.Now().UTC(), userID).Scan(
+	err = h.db.QueryRowContext(ctx, query, req.IsActive, time.Now().UTC(), userID, string(closureReason), req.ClosureNotes).Scan(
 		&resp.ID,
 		&resp.Email,
 		&resp.Role,
@@ -481,8 +486,7 @@ func (h *SecurityAdminHandlers) UpdateUserStatus(c *gin.Context) {
 }
 
 func (h *SecurityAdminHandlers) GetAllTransactions(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	limit := 50
 	if v := strings.TrimSpace(c.DefaultQuery("limit", "50")); v != "" {
@@ -556,16 +560,15 @@ func (h *SecurityAdminHandlers) GetAllTransactions(c *gin.Context) {
 }
 
 func (h *SecurityAdminHandlers) GetSystemAnalytics(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	query := `
 		SELECT
 			(SELECT COUNT(*) FROM users) AS total_users,
 			(SELECT COUNT(*) FROM users WHERE is_active = true) AS active_users,
 			(SELECT COUNT(*) FROM users WHERE role IN ('admin','super_admin')) AS total_admins,
-			COALESCE((SELECT SUM(amount) FROM deposits WHERE status = 'confirmed'), 0) AS total_deposits,
-			(SELECT COUNT(*) FROM deposits WHERE status = 'pending') AS pending_deposits,
+			COALESCE((SELECT SUM(amount) FROM deposits WHERE status IN ('confirmed', 'credited')), 0) AS total_deposits,
+			(SELECT COUNT(*) FROM deposits WHERE status IN ('pending', 'detected')) AS pending_deposits,
 			COALESCE((SELECT COUNT(*) FROM wallets), 0) AS total_wallets`
 
 	var analytics entities.SystemAnalytics
@@ -595,8 +598,7 @@ func (h *SecurityAdminHandlers) GetSystemAnalytics(c *gin.Context) {
 }
 
 func (h *SecurityAdminHandlers) CreateCuratedBasket(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	var req entities.CuratedBasketRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -724,8 +726,7 @@ func (h *adminHandler) updateCuratedBasket(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	query := `
 		UPDATE baskets
@@ -977,8 +978,7 @@ func GetAdminWallets(db *sql.DB, cfg *config.Config, log *logger.Logger) gin.Han
 }
 
 func (h *adminHandler) createWalletSet(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	var req entities.CreateWalletSetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1043,8 +1043,7 @@ func (h *adminHandler) createWalletSet(c *gin.Context) {
 }
 
 func (h *adminHandler) getWalletSets(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	limit := 50
 	if v := strings.TrimSpace(c.DefaultQuery("limit", "50")); v != "" {
@@ -1115,8 +1114,7 @@ func (h *adminHandler) getWalletSetByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	query := `
 		SELECT id, name, circle_wallet_set_id, status, created_at, updated_at
@@ -1153,8 +1151,7 @@ func (h *adminHandler) getWalletSetByID(c *gin.Context) {
 }
 
 func (h *adminHandler) getAdminWallets(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	limit := 50
 	if v := strings.TrimSpace(c.DefaultQuery("limit", "50")); v != "" {
@@ -1949,6 +1946,113 @@ func (h *EnhancedSecurityHandlers) AdminRevokeAPIKey(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
 }
 
+// Admin Request Signing Key Handlers
+
+// AdminListSigningKeys handles GET /api/v1/admin/signing-keys
+// @Summary List request signing keys
+// @Description Lists the HMAC keys internal services sign their requests with, optionally for one tenant. Secrets are never returned.
+// @Tags admin
+// @Produce json
+// @Param tenant query string false "Tenant"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/v1/admin/signing-keys [get]
+func (h *EnhancedSecurityHandlers) AdminListSigningKeys(c *gin.Context) {
+	keys, err := h.apikeyService.ListSigningKeys(c.Request.Context(), c.Query("tenant"))
+	if err != nil {
+		h.logger.Error("Failed to list signing keys", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list signing keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"signing_keys": keys})
+}
+
+// AdminCreateSigningKey handles POST /api/v1/admin/signing-keys
+// @Summary Create a request signing key
+// @Description Issues a signing key for a tenant's service in one environment. The secret is returned only in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body apikey.CreateSigningKeyRequest true "Signing key"
+// @Success 201 {object} apikey.CreateSigningKeyResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/v1/admin/signing-keys [post]
+func (h *EnhancedSecurityHandlers) AdminCreateSigningKey(c *gin.Context) {
+	var req apikey.CreateSigningKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	response, err := h.apikeyService.CreateSigningKey(c.Request.Context(), &req)
+	switch {
+	case errors.Is(err, apikey.ErrInvalidSigningRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, apikey.ErrSigningUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		h.logger.Error("Failed to create signing key", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create signing key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// AdminRevokeSigningKey handles DELETE /api/v1/admin/signing-keys/{id}
+// @Summary Revoke a request signing key
+// @Description Deactivates a signing key; requests signed with it are rejected from then on.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Signing key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/v1/admin/signing-keys/{id} [delete]
+func (h *EnhancedSecurityHandlers) AdminRevokeSigningKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key ID"})
+		return
+	}
+
+	err = h.apikeyService.RevokeSigningKey(c.Request.Context(), id)
+	if errors.Is(err, apikey.ErrSigningKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to revoke signing key", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke signing key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signing key revoked"})
+}
+
+// VerifySignedRequest handles GET /api/v1/internal/auth/verify
+// @Summary Verify a request signature
+// @Description Echoes the key and tenant a signed request authenticated as, so internal services can check their signing setup.
+// @Tags internal
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Security RequestSignature
+// @Router /api/v1/internal/auth/verify [get]
+func (h *EnhancedSecurityHandlers) VerifySignedRequest(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"key_id": c.GetString("signing_key_id"),
+		"tenant": c.GetString("service_tenant"),
+		"scopes": c.GetStringSlice("api_key_scopes"),
+	})
+}
+
 type ComplianceHandler struct {
 	auditService *adapters.AuditService
 	logger       *zap.Logger
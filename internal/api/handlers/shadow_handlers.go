@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/services/shadow"
+)
+
+// ShadowHandlers report how shadowed candidate implementations compare with
+// the live handlers
+type ShadowHandlers struct {
+	service *shadow.Service
+}
+
+// NewShadowHandlers creates a new shadow handlers instance
+func NewShadowHandlers(service *shadow.Service) *ShadowHandlers {
+	return &ShadowHandlers{service: service}
+}
+
+// ListShadowRoutes handles GET /api/v1/admin/shadow/routes
+// @Summary List shadowed routes
+// @Description Returns each route replayed against a candidate implementation with counts of matching, diverging and failed replays since the server started. Divergence details are in the logs.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.ShadowRouteListResponse
+// @Failure 401 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/shadow/routes [get]
+func (h *ShadowHandlers) ListShadowRoutes(c *gin.Context) {
+	c.JSON(http.StatusOK, ShadowRouteListResponse{Routes: h.service.Stats()})
+}
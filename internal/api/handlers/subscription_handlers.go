@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/subscription"
+	"go.uber.org/zap"
+)
+
+// SubscriptionHandlers exposes premium plans, billing and invoices
+type SubscriptionHandlers struct {
+	service *subscription.Service
+	logger  *zap.Logger
+}
+
+// NewSubscriptionHandlers creates a new subscription handlers instance
+func NewSubscriptionHandlers(service *subscription.Service, logger *zap.Logger) *SubscriptionHandlers {
+	return &SubscriptionHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListPlans handles GET /api/v1/subscriptions/plans
+// @Summary List subscription plans
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} handlers.SubscriptionPlansResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/plans [get]
+func (h *SubscriptionHandlers) ListPlans(c *gin.Context) {
+	plans, err := h.service.ListPlans(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list subscription plans", zap.Error(err))
+		respondInternalError(c, "Failed to list subscription plans")
+		return
+	}
+	c.JSON(http.StatusOK, SubscriptionPlansResponse{
+		Plans:    plans,
+		FreeTier: entities.FreeTierEntitlements(),
+	})
+}
+
+// GetSubscription handles GET /api/v1/subscriptions
+// @Summary Get the user's subscription and entitlements
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} entities.SubscriptionOverview
+// @Security BearerAuth
+// @Router /api/v1/subscriptions [get]
+func (h *SubscriptionHandlers) GetSubscription(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	overview, err := h.service.Overview(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get subscription", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get subscription")
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// Subscribe handles POST /api/v1/subscriptions
+// @Summary Subscribe or change plan
+// @Description Starts a plan and charges its first period, or switches plan with the rest of the current period prorated. Fees are paid from the cash balance unless payment_method is card.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body entities.SubscribeRequest true "Plan"
+// @Success 200 {object} entities.SubscriptionOverview
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 402 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions [post]
+func (h *SubscriptionHandlers) Subscribe(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	overview, err := h.service.Subscribe(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondBillingError(c, err, "Failed to update subscription")
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// CancelSubscription handles POST /api/v1/subscriptions/cancel
+// @Summary Cancel the subscription
+// @Description Stops renewal; the plan stays active until the end of the paid period.
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} entities.Subscription
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/cancel [post]
+func (h *SubscriptionHandlers) CancelSubscription(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	sub, err := h.service.Cancel(c.Request.Context(), userID)
+	if err != nil {
+		h.respondBillingError(c, err, "Failed to cancel subscription")
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// ListInvoices handles GET /api/v1/subscriptions/invoices
+// @Summary List invoices
+// @Tags subscriptions
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.InvoiceListResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/invoices [get]
+func (h *SubscriptionHandlers) ListInvoices(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	invoices, err := h.service.ListInvoices(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list invoices", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to list invoices")
+		return
+	}
+	c.JSON(http.StatusOK, InvoiceListResponse{Invoices: invoices})
+}
+
+// GetInvoice handles GET /api/v1/subscriptions/invoices/:id
+// @Summary Get an invoice
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} entities.Invoice
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/invoices/{id} [get]
+func (h *SubscriptionHandlers) GetInvoice(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid invoice ID", nil)
+		return
+	}
+
+	invoice, err := h.service.GetInvoice(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondBillingError(c, err, "Failed to get invoice")
+		return
+	}
+	c.JSON(http.StatusOK, invoice)
+}
+
+// PayInvoice handles POST /api/v1/subscriptions/invoices/:id/pay
+// @Summary Pay an open invoice now
+// @Description Retries payment of an invoice in dunning without waiting for the next scheduled attempt.
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} entities.Invoice
+// @Failure 402 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/subscriptions/invoices/{id}/pay [post]
+func (h *SubscriptionHandlers) PayInvoice(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid invoice ID", nil)
+		return
+	}
+
+	invoice, err := h.service.PayInvoice(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondBillingError(c, err, "Failed to pay invoice")
+		return
+	}
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *SubscriptionHandlers) respondBillingError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, entities.ErrPlanNotFound):
+		respondNotFound(c, "Subscription plan not found")
+	case errors.Is(err, entities.ErrSubscriptionNotFound):
+		respondNotFound(c, "No active subscription")
+	case errors.Is(err, entities.ErrInvoiceNotFound):
+		respondNotFound(c, "Invoice not found")
+	case errors.Is(err, entities.ErrPaymentMethodUnavailable):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrPaymentFailed):
+		respondError(c, http.StatusPaymentRequired, "PAYMENT_FAILED", err.Error(), nil)
+	case errors.Is(err, entities.ErrAlreadySubscribed),
+		errors.Is(err, entities.ErrSubscriptionPastDue),
+		errors.Is(err, entities.ErrInvoiceNotPayable):
+		respondError(c, http.StatusConflict, "INVALID_STATE", err.Error(), nil)
+	default:
+		h.logger.Error(failure, zap.Error(err))
+		respondInternalError(c, failure)
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/suspense"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// SuspenseHandlers run the admin queue of deposits held in suspense because
+// they could not be attributed to a user
+type SuspenseHandlers struct {
+	service      *suspense.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewSuspenseHandlers creates new suspense handlers
+func NewSuspenseHandlers(service *suspense.Service, auditService *adapters.AuditService, logger *zap.Logger) *SuspenseHandlers {
+	return &SuspenseHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListSuspenseItems handles GET /api/v1/admin/suspense
+// @Summary List suspense items
+// @Description Deposits held in suspense, oldest first. Open items include suggestions of who they may belong to.
+// @Tags admin
+// @Produce json
+// @Param status query string false "open (default), assigned or refunded"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} handlers.SuspenseItemListResponse
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/suspense [get]
+func (h *SuspenseHandlers) ListSuspenseItems(c *gin.Context) {
+	status := entities.SuspenseStatus(c.DefaultQuery("status", string(entities.SuspenseStatusOpen)))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	items, err := h.service.List(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		h.respondSuspenseError(c, err, "Failed to list suspense items")
+		return
+	}
+	if items == nil {
+		items = []*entities.SuspenseItem{}
+	}
+	c.JSON(http.StatusOK, SuspenseItemListResponse{Items: items})
+}
+
+// GetSuspenseAging handles GET /api/v1/admin/suspense/aging
+// @Summary Get suspense aging
+// @Description Open items per source and how many are older than the aging alert threshold
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entities.SuspenseAgingReport
+// @Failure 500 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/suspense/aging [get]
+func (h *SuspenseHandlers) GetSuspenseAging(c *gin.Context) {
+	report, err := h.service.CheckAging(c.Request.Context())
+	if err != nil {
+		h.respondSuspenseError(c, err, "Failed to get suspense aging")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSuspenseItem handles GET /api/v1/admin/suspense/:id
+// @Summary Get a suspense item
+// @Tags admin
+// @Produce json
+// @Param id path string true "Suspense item ID"
+// @Success 200 {object} entities.SuspenseItem
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/suspense/{id} [get]
+func (h *SuspenseHandlers) GetSuspenseItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid suspense item ID", nil)
+		return
+	}
+	item, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondSuspenseError(c, err, "Failed to get suspense item")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// AssignSuspenseItem handles POST /api/v1/admin/suspense/:id/assign
+// @Summary Assign a suspense item to a user
+// @Description Credits the held deposit to the user and releases it from suspense. The note should say how the user was identified.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Suspense item ID"
+// @Param request body entities.AssignSuspenseItemRequest true "User and note"
+// @Success 200 {object} entities.SuspenseItem
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/suspense/{id}/assign [post]
+func (h *SuspenseHandlers) AssignSuspenseItem(c *gin.Context) {
+	adminID, id, ok := h.adminAndItemID(c)
+	if !ok {
+		return
+	}
+	var req entities.AssignSuspenseItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if req.UserID == uuid.Nil {
+		respondBadRequest(c, "user_id is required", nil)
+		return
+	}
+
+	item, err := h.service.Assign(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		h.respondSuspenseError(c, err, "Failed to assign suspense item")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "assign_suspense_item", "suspense_item", nil, map[string]interface{}{
+		"suspense_item_id": id.String(),
+		"user_id":          req.UserID.String(),
+		"note":             req.Note,
+	})
+	c.JSON(http.StatusOK, item)
+}
+
+// RefundSuspenseItem handles POST /api/v1/admin/suspense/:id/refund
+// @Summary Refund a suspense item
+// @Description Records that the held deposit was sent back to its sender and releases it from suspense
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Suspense item ID"
+// @Param request body entities.RefundSuspenseItemRequest true "Note"
+// @Success 200 {object} entities.SuspenseItem
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/suspense/{id}/refund [post]
+func (h *SuspenseHandlers) RefundSuspenseItem(c *gin.Context) {
+	adminID, id, ok := h.adminAndItemID(c)
+	if !ok {
+		return
+	}
+	var req entities.RefundSuspenseItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	item, err := h.service.Refund(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		h.respondSuspenseError(c, err, "Failed to refund suspense item")
+		return
+	}
+	h.auditService.LogAction(c.Request.Context(), &adminID, "refund_suspense_item", "suspense_item", nil, map[string]interface{}{
+		"suspense_item_id": id.String(),
+		"note":             req.Note,
+	})
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *SuspenseHandlers) adminAndItemID(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid suspense item ID", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return adminID, id, true
+}
+
+func (h *SuspenseHandlers) respondSuspenseError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrSuspenseItemNotFound), errors.Is(err, entities.ErrBankCreditNotFound):
+		respondNotFound(c, "Suspense item not found")
+	case errors.Is(err, entities.ErrSuspenseItemResolved), errors.Is(err, entities.ErrBankCreditNotUnmatched):
+		respondError(c, http.StatusConflict, "SUSPENSE_ITEM_RESOLVED", "Suspense item is already resolved", nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/sweep"
+	"go.uber.org/zap"
+)
+
+// SweepHandlers manage a user's auto-sweep of idle buying power
+type SweepHandlers struct {
+	service *sweep.Service
+	logger  *zap.Logger
+}
+
+// NewSweepHandlers creates a new auto-sweep handlers instance
+func NewSweepHandlers(service *sweep.Service, logger *zap.Logger) *SweepHandlers {
+	return &SweepHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetSweep handles GET /api/v1/sweep
+// @Summary Get auto-sweep
+// @Description Returns the user's auto-sweep rule, if set up, and its 20 most recent runs
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.SweepOverview
+// @Security BearerAuth
+// @Router /api/v1/sweep [get]
+func (h *SweepHandlers) GetSweep(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	overview, err := h.service.Overview(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, userID, "Failed to get auto-sweep", err)
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// SaveSweep handles PUT /api/v1/sweep
+// @Summary Set up auto-sweep
+// @Description Turns on auto-sweep: at each run of the cadence, buying power above the floor is invested into the basket, or the default cash basket when none is given
+// @Tags investing
+// @Accept json
+// @Produce json
+// @Param request body entities.SaveSweepRuleRequest true "Sweep rule"
+// @Success 200 {object} entities.SweepRule
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/sweep [put]
+func (h *SweepHandlers) SaveSweep(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.SaveSweepRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.Save(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, userID, "Failed to save auto-sweep", err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DisableSweep handles POST /api/v1/sweep/disable
+// @Summary Turn off auto-sweep
+// @Description Stops future sweeps and keeps the settings for when it is turned back on
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.SweepRule
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/sweep/disable [post]
+func (h *SweepHandlers) DisableSweep(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	rule, err := h.service.Disable(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, userID, "Failed to turn off auto-sweep", err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *SweepHandlers) handleError(c *gin.Context, userID uuid.UUID, message string, err error) {
+	switch {
+	case errors.Is(err, entities.ErrInvalidSweepRule):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrSweepRuleNotFound):
+		respondNotFound(c, err.Error())
+	default:
+		h.logger.Error(message, zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/papertrading"
+	"go.uber.org/zap"
+)
+
+// TradingModeHandlers lets users move between paper and live trading
+type TradingModeHandlers struct {
+	service *papertrading.Service
+	logger  *zap.Logger
+}
+
+// NewTradingModeHandlers creates a new trading mode handlers instance
+func NewTradingModeHandlers(service *papertrading.Service, logger *zap.Logger) *TradingModeHandlers {
+	return &TradingModeHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetTradingMode handles GET /api/v1/investing/mode
+// @Summary Get trading mode
+// @Description Returns whether the user trades live or on paper, their paper buying power, and what, if anything, blocks switching to live trading (kyc or jurisdiction).
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.TradingModeStatus
+// @Security BearerAuth
+// @Router /api/v1/investing/mode [get]
+func (h *TradingModeHandlers) GetTradingMode(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get trading mode", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to get trading mode")
+		return
+	}
+	h.respond(c, status)
+}
+
+// EnablePaperTrading handles POST /api/v1/investing/mode/paper
+// @Summary Switch to paper trading
+// @Description Moves the user to paper trading. Orders then fill against live quotes with simulated buying power, funded with the starting balance the first time.
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.TradingModeStatus
+// @Security BearerAuth
+// @Router /api/v1/investing/mode/paper [post]
+func (h *TradingModeHandlers) EnablePaperTrading(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	status, err := h.service.EnablePaper(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to enable paper trading", zap.String("user_id", userID.String()), zap.Error(err))
+		respondInternalError(c, "Failed to enable paper trading")
+		return
+	}
+	h.respond(c, status)
+}
+
+// SwitchToLive handles POST /api/v1/investing/mode/live
+// @Summary Switch to live trading
+// @Description Moves the user from paper to live trading. Requires approved KYC and live trading to be available in the user's country. The paper account is kept.
+// @Tags investing
+// @Produce json
+// @Success 200 {object} entities.TradingModeStatus
+// @Failure 403 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/investing/mode/live [post]
+func (h *TradingModeHandlers) SwitchToLive(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	status, err := h.service.SwitchToLive(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, papertrading.ErrKYCRequired):
+			respondError(c, http.StatusForbidden, "KYC_REQUIRED", err.Error(), nil)
+		case errors.Is(err, papertrading.ErrLiveTradingUnavailable):
+			respondError(c, http.StatusForbidden, "LIVE_TRADING_UNAVAILABLE", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to switch to live trading", zap.String("user_id", userID.String()), zap.Error(err))
+			respondInternalError(c, "Failed to switch to live trading")
+		}
+		return
+	}
+	h.respond(c, status)
+}
+
+func (h *TradingModeHandlers) respond(c *gin.Context, status *entities.TradingModeStatus) {
+	c.Header(entities.TradingModeHeader, string(status.Mode))
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/trustedcontact"
+	"go.uber.org/zap"
+)
+
+// TrustedContactHandlers lets users manage their trusted contact
+type TrustedContactHandlers struct {
+	trustedContactService *trustedcontact.Service
+	logger                *zap.Logger
+}
+
+// NewTrustedContactHandlers creates a new trusted contact handlers instance
+func NewTrustedContactHandlers(trustedContactService *trustedcontact.Service, logger *zap.Logger) *TrustedContactHandlers {
+	return &TrustedContactHandlers{
+		trustedContactService: trustedContactService,
+		logger:                logger,
+	}
+}
+
+// GetTrustedContact handles GET /api/v1/users/me/trusted-contact
+// @Summary Get the user's trusted contact
+// @Tags users
+// @Produce json
+// @Success 200 {object} entities.TrustedContact
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/users/me/trusted-contact [get]
+func (h *TrustedContactHandlers) GetTrustedContact(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	contact, err := h.trustedContactService.Get(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, entities.ErrTrustedContactNotFound) {
+			respondNotFound(c, "No trusted contact on file")
+			return
+		}
+		h.logger.Error("Failed to get trusted contact", zap.Error(err))
+		respondInternalError(c, "Failed to get trusted contact")
+		return
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// SetTrustedContact handles PUT /api/v1/users/me/trusted-contact
+// @Summary Set the user's trusted contact
+// @Description A trusted contact may be reached if we cannot contact the user, for example when the account becomes dormant.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entities.UpsertTrustedContactRequest true "Trusted contact"
+// @Success 200 {object} entities.TrustedContact
+// @Failure 400 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/users/me/trusted-contact [put]
+func (h *TrustedContactHandlers) SetTrustedContact(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.UpsertTrustedContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	contact, err := h.trustedContactService.Set(c.Request.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, trustedcontact.ErrUnreachable) {
+			respondBadRequest(c, err.Error(), nil)
+			return
+		}
+		h.logger.Error("Failed to set trusted contact", zap.Error(err))
+		respondInternalError(c, "Failed to set trusted contact")
+		return
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// DeleteTrustedContact handles DELETE /api/v1/users/me/trusted-contact
+// @Summary Remove the user's trusted contact
+// @Tags users
+// @Success 204
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/users/me/trusted-contact [delete]
+func (h *TrustedContactHandlers) DeleteTrustedContact(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	if err := h.trustedContactService.Remove(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, entities.ErrTrustedContactNotFound) {
+			respondNotFound(c, "No trusted contact on file")
+			return
+		}
+		h.logger.Error("Failed to remove trusted contact", zap.Error(err))
+		respondInternalError(c, "Failed to remove trusted contact")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
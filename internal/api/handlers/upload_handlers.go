@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/upload"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// UploadHandlers issue pre-signed upload URLs for documents and let
+// reviewers download what was uploaded
+type UploadHandlers struct {
+	service      *upload.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewUploadHandlers creates a new upload handlers instance
+func NewUploadHandlers(service *upload.Service, auditService *adapters.AuditService, logger *zap.Logger) *UploadHandlers {
+	return &UploadHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// CreateUpload handles POST /api/v1/uploads
+// @Summary Start a document upload
+// @Description Declares a file and returns a pre-signed URL to PUT it to, with the headers the request must carry. Complete the upload once the file is sent, then reference it by ID in KYC, EDD or KYB documents.
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param request body entities.CreateUploadRequest true "File to upload"
+// @Success 201 {object} entities.UploadTicket
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/uploads [post]
+func (h *UploadHandlers) CreateUpload(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	ticket, err := h.service.CreateUpload(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to start upload")
+		return
+	}
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// CompleteUpload handles POST /api/v1/uploads/{id}/complete
+// @Summary Complete a document upload
+// @Description Checks the uploaded file's size and type against what was declared and virus scans it. A file that fails is deleted and the upload rejected.
+// @Tags uploads
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} entities.Upload
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse "File not uploaded yet"
+// @Failure 422 {object} entities.ErrorResponse "File rejected"
+// @Security BearerAuth
+// @Router /api/v1/uploads/{id}/complete [post]
+func (h *UploadHandlers) CompleteUpload(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid upload ID", nil)
+		return
+	}
+
+	completed, err := h.service.Complete(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to complete upload")
+		return
+	}
+	c.JSON(http.StatusOK, completed)
+}
+
+// GetUpload handles GET /api/v1/uploads/{id}
+// @Summary Get a document upload
+// @Tags uploads
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} entities.Upload
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/uploads/{id} [get]
+func (h *UploadHandlers) GetUpload(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid upload ID", nil)
+		return
+	}
+
+	found, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get upload")
+		return
+	}
+	c.JSON(http.StatusOK, found)
+}
+
+// DownloadUpload handles GET /api/v1/admin/uploads/{id}/download
+// @Summary Download an uploaded document
+// @Description Returns a short-lived link to a vetted upload for review
+// @Tags admin
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} entities.UploadDownload
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/uploads/{id}/download [get]
+func (h *UploadHandlers) DownloadUpload(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid upload ID", nil)
+		return
+	}
+
+	found, download, err := h.service.DownloadURL(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to sign download link")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "download_upload", "upload", nil, map[string]interface{}{
+		"upload_id": found.ID,
+		"user_id":   found.UserID,
+		"purpose":   found.Purpose,
+	})
+	c.JSON(http.StatusOK, download)
+}
+
+func (h *UploadHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	if errors.Is(err, entities.ErrUploadNotFound) {
+		respondNotFound(c, "Upload not found")
+		return
+	}
+	if respondUploadReferenceError(c, err) {
+		return
+	}
+	h.logger.Error(message, zap.Error(err))
+	respondInternalError(c, message)
+}
+
+// respondUploadReferenceError writes the response for upload errors, which
+// also surface from document submissions that reference uploads. It reports
+// whether err was one of them.
+func respondUploadReferenceError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, entities.ErrInvalidUpload):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrUploadNotReady):
+		respondError(c, http.StatusConflict, "UPLOAD_NOT_READY", err.Error(), nil)
+	case errors.Is(err, entities.ErrUploadRejected):
+		respondError(c, http.StatusUnprocessableEntity, "UPLOAD_REJECTED", err.Error(), nil)
+	case errors.Is(err, entities.ErrUploadsUnavailable):
+		respondError(c, http.StatusServiceUnavailable, "UPLOADS_UNAVAILABLE", err.Error(), nil)
+	case errors.Is(err, entities.ErrOutOfRegion):
+		respondError(c, http.StatusConflict, "OUT_OF_REGION", err.Error(), nil)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/walletbackfill"
+	"go.uber.org/zap"
+)
+
+// WalletBackfillHandlers lets administrators provision wallets for existing users on newly added chains
+type WalletBackfillHandlers struct {
+	service *walletbackfill.Service
+	logger  *zap.Logger
+}
+
+// NewWalletBackfillHandlers creates a new wallet backfill handlers instance
+func NewWalletBackfillHandlers(service *walletbackfill.Service, logger *zap.Logger) *WalletBackfillHandlers {
+	return &WalletBackfillHandlers{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// StartBackfill handles POST /api/v1/admin/wallet/backfills
+// @Summary Start a wallet provisioning backfill
+// @Description Enqueues provisioning jobs in rate-limited batches for active users missing a wallet on the given chains. Use dry_run to count eligible users only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.StartWalletBackfillRequest true "Backfill"
+// @Success 202 {object} entities.WalletBackfill
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/wallet/backfills [post]
+func (h *WalletBackfillHandlers) StartBackfill(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.StartWalletBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	backfill, err := h.service.Start(c.Request.Context(), &req, &adminID)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to start wallet backfill")
+		return
+	}
+	c.JSON(http.StatusAccepted, backfill)
+}
+
+// ListBackfills handles GET /api/v1/admin/wallet/backfills
+// @Summary List recent wallet backfills
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum backfills to return"
+// @Success 200 {object} handlers.WalletBackfillListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/wallet/backfills [get]
+func (h *WalletBackfillHandlers) ListBackfills(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	backfills, err := h.service.List(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list wallet backfills", zap.Error(err))
+		respondInternalError(c, "Failed to list wallet backfills")
+		return
+	}
+	c.JSON(http.StatusOK, WalletBackfillListResponse{Backfills: backfills})
+}
+
+// GetBackfill handles GET /api/v1/admin/wallet/backfills/:id
+// @Summary Get wallet backfill progress
+// @Description Includes the backfill's provisioning jobs broken down by status
+// @Tags admin
+// @Produce json
+// @Param id path string true "Backfill ID"
+// @Success 200 {object} entities.WalletBackfill
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/wallet/backfills/{id} [get]
+func (h *WalletBackfillHandlers) GetBackfill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid backfill ID", nil)
+		return
+	}
+	backfill, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get wallet backfill")
+		return
+	}
+	c.JSON(http.StatusOK, backfill)
+}
+
+// CancelBackfill handles POST /api/v1/admin/wallet/backfills/:id/cancel
+// @Summary Cancel a running wallet backfill
+// @Description Stops enqueueing further batches; jobs already enqueued still run
+// @Tags admin
+// @Produce json
+// @Param id path string true "Backfill ID"
+// @Success 200 {object} entities.WalletBackfill
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/wallet/backfills/{id}/cancel [post]
+func (h *WalletBackfillHandlers) CancelBackfill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid backfill ID", nil)
+		return
+	}
+	backfill, err := h.service.Cancel(c.Request.Context(), id)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to cancel wallet backfill")
+		return
+	}
+	c.JSON(http.StatusOK, backfill)
+}
+
+func (h *WalletBackfillHandlers) respondServiceError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, entities.ErrWalletBackfillNotFound):
+		respondNotFound(c, "Wallet backfill not found")
+	case errors.Is(err, entities.ErrWalletBackfillRunning):
+		respondError(c, http.StatusConflict, "BACKFILL_RUNNING", err.Error(), nil)
+	case errors.Is(err, walletbackfill.ErrUnsupportedChain):
+		respondBadRequest(c, err.Error(), nil)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		respondInternalError(c, msg)
+	}
+}
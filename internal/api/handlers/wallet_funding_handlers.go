@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -10,6 +11,7 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/domain/services/funding"
 	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/recipients"
 	"github.com/stack-service/stack_service/internal/domain/services/wallet"
 	"github.com/stack-service/stack_service/internal/infrastructure/config"
 	"github.com/stack-service/stack_service/pkg/logger"
@@ -27,6 +29,9 @@ type WalletFundingHandlers struct {
 	fundingService    *funding.Service
 	withdrawalService FundingWithdrawalService
 	investingService  *investing.Service
+	paperInvesting    *investing.Service
+	tradingModes      TradingModeResolver
+	hotCache          HotCache
 	validator         *validator.Validate
 	logger            *logger.Logger
 }
@@ -49,6 +54,51 @@ func NewWalletFundingHandlers(
 	}
 }
 
+// TradingModeResolver decides whether a user trades live or on paper
+type TradingModeResolver interface {
+	Mode(ctx context.Context, userID uuid.UUID) (entities.TradingMode, error)
+}
+
+// HotCache serves balances and live portfolios from Redis
+type HotCache interface {
+	Balances(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error)
+	Portfolio(ctx context.Context, userID uuid.UUID) (*entities.Portfolio, error)
+	Forget(ctx context.Context, userID uuid.UUID)
+}
+
+// SetHotCache serves balance and live portfolio reads through a cache, which
+// is dropped when the user refreshes balances or places an order
+func (h *WalletFundingHandlers) SetHotCache(hotCache HotCache) {
+	h.hotCache = hotCache
+}
+
+// SetPaperTrading routes the orders and portfolio of users in paper mode to
+// the paper investing service
+func (h *WalletFundingHandlers) SetPaperTrading(modes TradingModeResolver, paperInvesting *investing.Service) {
+	h.tradingModes = modes
+	h.paperInvesting = paperInvesting
+}
+
+// investingFor returns the investing service for the user's trading mode and
+// flags the response with the mode. It responds with an error and returns
+// false if the mode cannot be resolved.
+func (h *WalletFundingHandlers) investingFor(c *gin.Context, userID uuid.UUID) (*investing.Service, bool) {
+	if h.tradingModes == nil {
+		return h.investingService, true
+	}
+	mode, err := h.tradingModes.Mode(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to resolve trading mode", "error", err, "user_id", userID)
+		respondInternalError(c, "Failed to resolve trading mode")
+		return nil, false
+	}
+	c.Header(entities.TradingModeHeader, string(mode))
+	if mode == entities.TradingModePaper {
+		return h.paperInvesting, true
+	}
+	return h.investingService, true
+}
+
 
 // Request/Response models
 
@@ -152,7 +202,7 @@ func (h *WalletFundingHandlers) GetWalletAddresses(c *gin.Context) {
 				Message: "Invalid blockchain network",
 				Details: map[string]interface{}{
 					"chain":            chainQuery,
-					"supported_chains": []string{"ETH", "ETH-SEPOLIA", "SOL", "SOL-DEVNET", "APTOS", "APTOS-TESTNET"},
+					"supported_chains": entities.Chains().EnabledIDs(),
 				},
 			})
 			return
@@ -314,7 +364,7 @@ func (h *WalletFundingHandlers) CreateWalletsForUser(c *gin.Context) {
 				Message: "Invalid blockchain network",
 				Details: map[string]interface{}{
 					"chain":            chainStr,
-					"supported_chains": []string{"ETH", "ETH-SEPOLIA", "SOL", "SOL-DEVNET", "APTOS", "APTOS-TESTNET"},
+					"supported_chains": entities.Chains().EnabledIDs(),
 				},
 			})
 			return
@@ -517,7 +567,7 @@ func (h *WalletFundingHandlers) InitiateWalletCreation(c *gin.Context) {
 				Message: "Invalid blockchain network",
 				Details: map[string]interface{}{
 					"chain":            chainStr,
-					"supported_chains": []string{"SOL-DEVNET"},
+					"supported_chains": entities.Chains().EnabledIDs(),
 				},
 			})
 			return
@@ -528,10 +578,10 @@ func (h *WalletFundingHandlers) InitiateWalletCreation(c *gin.Context) {
 			h.logger.Warn("Mainnet chain not supported for wallet creation", zap.String("chain", chainStr))
 			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
 				Code:    "MAINNET_NOT_SUPPORTED",
-				Message: "Only SOL-DEVNET is supported at this time",
+				Message: "Only testnet chains are supported at this time",
 				Details: map[string]interface{}{
 					"requested_chain":  chainStr,
-					"supported_chains": []string{"SOL-DEVNET"},
+					"supported_chains": entities.GetTestnetChains(),
 				},
 			})
 			return
@@ -921,7 +971,12 @@ func (h *WalletFundingHandlers) GetBalances(c *gin.Context) {
 		return
 	}
 
-	balances, err := h.fundingService.GetBalance(c.Request.Context(), userUUID)
+	var balances *entities.BalancesResponse
+	if h.hotCache != nil {
+		balances, err = h.hotCache.Balances(c.Request.Context(), userUUID)
+	} else {
+		balances, err = h.fundingService.GetBalance(c.Request.Context(), userUUID)
+	}
 	if err != nil {
 		h.logger.Error("Failed to get balances", "error", err, "user_id", userUUID)
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
@@ -934,6 +989,47 @@ func (h *WalletFundingHandlers) GetBalances(c *gin.Context) {
 	c.JSON(http.StatusOK, balances)
 }
 
+// RefreshBalances re-reads the user's wallet balances from Circle
+// @Summary Refresh user balances
+// @Description Bypass the balance cache and read the authenticated user's wallets from Circle. Limited per user.
+// @Tags funding
+// @Produce json
+// @Success 200 {object} entities.BalancesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/balances/refresh [post]
+func (h *WalletFundingHandlers) RefreshBalances(c *gin.Context) {
+	userUUID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("Failed to get user ID", "error", err)
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	balances, err := h.fundingService.RefreshBalance(c.Request.Context(), userUUID)
+	if err != nil {
+		if errors.Is(err, entities.ErrBalanceRefreshRateLimited) {
+			c.JSON(http.StatusTooManyRequests, entities.ErrorResponse{
+				Code:    "TOO_MANY_REQUESTS",
+				Message: "Balances were refreshed recently. Please try again shortly.",
+			})
+			return
+		}
+		h.logger.Error("Failed to refresh balances", "error", err, "user_id", userUUID)
+		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
+			Code:    "BALANCES_ERROR",
+			Message: "Failed to refresh balances",
+		})
+		return
+	}
+	if h.hotCache != nil {
+		h.hotCache.Forget(c.Request.Context(), userUUID)
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
 // CreateVirtualAccount creates a virtual account linked to an Alpaca brokerage account
 // @Summary Create virtual account
 // @Description Create a virtual account for funding a brokerage account with stablecoins
@@ -1013,14 +1109,21 @@ func (h *WalletFundingHandlers) CreateVirtualAccount(c *gin.Context) {
 
 // GetBaskets lists all available investment baskets
 // @Summary Get investment baskets
-// @Description Retrieve all available curated investment baskets
+// @Description Retrieve the curated investment baskets offered in the user's country
 // @Tags investing
 // @Produce json
 // @Success 200 {array} entities.Basket
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/investing/baskets [get]
 func (h *WalletFundingHandlers) GetBaskets(c *gin.Context) {
-	baskets, err := h.investingService.ListBaskets(c.Request.Context())
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	baskets, err := h.investingService.ListBasketsForUser(c.Request.Context(), userID)
 	if err != nil {
 		h.logger.Error("Failed to get baskets", "error", err)
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
@@ -1035,12 +1138,13 @@ func (h *WalletFundingHandlers) GetBaskets(c *gin.Context) {
 
 // GetBasket returns details of a specific basket
 // @Summary Get basket details
-// @Description Retrieve details of a specific investment basket
+// @Description Retrieve details of a specific investment basket. Baskets not offered in the user's country are not found.
 // @Tags investing
 // @Produce json
 // @Param basketId path string true "Basket ID"
 // @Success 200 {object} entities.Basket
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/investing/baskets/{basketId} [get]
@@ -1055,9 +1159,15 @@ func (h *WalletFundingHandlers) GetBasket(c *gin.Context) {
 		return
 	}
 
-	basket, err := h.investingService.GetBasket(c.Request.Context(), basketID)
+	userID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	basket, err := h.investingService.GetBasketForUser(c.Request.Context(), userID, basketID)
 	if err != nil {
-		if err == investing.ErrBasketNotFound {
+		if errors.Is(err, investing.ErrBasketNotFound) {
 			c.JSON(http.StatusNotFound, entities.ErrorResponse{
 				Code:    "BASKET_NOT_FOUND",
 				Message: "Basket not found",
@@ -1077,7 +1187,7 @@ func (h *WalletFundingHandlers) GetBasket(c *gin.Context) {
 
 // CreateOrder creates a new investment order
 // @Summary Create investment order
-// @Description Place a buy or sell order for a basket
+// @Description Place a buy or sell order for a basket. Market orders execute right away; limit orders rest until the synthetic basket price (sum of component weight times quote) reaches limitPrice, expiring at the session close (day) or after the configured maximum (gtc).
 // @Tags investing
 // @Accept json
 // @Produce json
@@ -1102,7 +1212,11 @@ func (h *WalletFundingHandlers) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.investingService.CreateOrder(c.Request.Context(), userUUID, &req)
+	service, ok := h.investingFor(c, userUUID)
+	if !ok {
+		return
+	}
+	order, err := service.CreateOrder(c.Request.Context(), userUUID, &req)
 	if err != nil {
 		switch err {
 		case investing.ErrBasketNotFound:
@@ -1129,6 +1243,12 @@ func (h *WalletFundingHandlers) CreateOrder(c *gin.Context) {
 				Message: "Insufficient position for sell order",
 			})
 			return
+		case investing.ErrInvalidOrderType, investing.ErrInvalidLimitPrice, investing.ErrInvalidTimeInForce:
+			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+				Code:    "INVALID_ORDER_TERMS",
+				Message: err.Error(),
+			})
+			return
 		default:
 			h.logger.Error("Failed to create order", "error", err, "user_id", userUUID)
 			c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
@@ -1138,10 +1258,116 @@ func (h *WalletFundingHandlers) CreateOrder(c *gin.Context) {
 			return
 		}
 	}
+	if h.hotCache != nil {
+		h.hotCache.Forget(c.Request.Context(), userUUID)
+	}
 
 	c.JSON(http.StatusCreated, order)
 }
 
+// PreviewOrder estimates an investment order without placing it
+// @Summary Preview investment order
+// @Description Estimate per-component shares at live prices, fees, the execution window and the cash left after a basket order, for the confirm screen
+// @Tags investing
+// @Accept json
+// @Produce json
+// @Param request body entities.OrderCreateRequest true "Order to preview"
+// @Success 200 {object} entities.OrderPreview
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/investing/orders/preview [post]
+func (h *WalletFundingHandlers) PreviewOrder(c *gin.Context) {
+	var req entities.OrderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request format", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	userUUID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("Failed to get user ID", "error", err)
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	service, ok := h.investingFor(c, userUUID)
+	if !ok {
+		return
+	}
+	preview, err := service.PreviewOrder(c.Request.Context(), userUUID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, investing.ErrBasketNotFound):
+			respondError(c, http.StatusBadRequest, "BASKET_NOT_FOUND", "Specified basket does not exist", nil)
+		case errors.Is(err, investing.ErrInvalidAmount):
+			respondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Invalid order amount", nil)
+		case errors.Is(err, investing.ErrInsufficientFunds):
+			respondError(c, http.StatusForbidden, "INSUFFICIENT_FUNDS", "Insufficient buying power for this order", nil)
+		case errors.Is(err, investing.ErrInsufficientPosition):
+			respondError(c, http.StatusForbidden, "INSUFFICIENT_POSITION", "Insufficient position for sell order", nil)
+		case errors.Is(err, entities.ErrSpendingLimitReached):
+			respondError(c, http.StatusForbidden, "SPENDING_LIMIT_REACHED", err.Error(), nil)
+		case errors.Is(err, investing.ErrQuotesUnavailable):
+			h.logger.Warn("Order preview without quotes", "error", err, "basket_id", req.BasketID)
+			respondError(c, http.StatusServiceUnavailable, "QUOTES_UNAVAILABLE", "Live prices are unavailable, try again shortly", nil)
+		default:
+			h.logger.Error("Failed to preview order", "error", err, "user_id", userUUID)
+			respondInternalError(c, "Failed to preview order")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// CancelOrder cancels a resting limit order
+// @Summary Cancel limit order
+// @Description Cancel an open limit order that has not been triggered, releasing the buying power it reserved
+// @Tags investing
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} entities.Order
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/investing/orders/{id}/cancel [post]
+func (h *WalletFundingHandlers) CancelOrder(c *gin.Context) {
+	userUUID, err := getUserID(c)
+	if err != nil {
+		h.logger.Error("Failed to get user ID", "error", err)
+		respondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid order ID", nil)
+		return
+	}
+
+	service, ok := h.investingFor(c, userUUID)
+	if !ok {
+		return
+	}
+	order, err := service.CancelOrder(c.Request.Context(), userUUID, orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, investing.ErrOrderNotFound):
+			respondNotFound(c, "Order not found")
+		case errors.Is(err, investing.ErrOrderNotCancelable):
+			respondError(c, http.StatusConflict, "ORDER_NOT_CANCELABLE", err.Error(), nil)
+		default:
+			h.logger.Error("Failed to cancel order", "error", err, "order_id", orderID)
+			respondInternalError(c, "Failed to cancel order")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
 // GetOrders lists user's orders
 // @Summary Get user orders
 // @Description Retrieve orders for the authenticated user
@@ -1172,7 +1398,11 @@ func (h *WalletFundingHandlers) GetOrders(c *gin.Context) {
 		statusFilter = &status
 	}
 
-	orders, err := h.investingService.ListOrders(c.Request.Context(), userUUID, limit, offset, statusFilter)
+	service, ok := h.investingFor(c, userUUID)
+	if !ok {
+		return
+	}
+	orders, err := service.ListOrders(c.Request.Context(), userUUID, limit, offset, statusFilter)
 	if err != nil {
 		h.logger.Error("Failed to get orders", "error", err, "user_id", userUUID)
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
@@ -1215,7 +1445,11 @@ func (h *WalletFundingHandlers) GetOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.investingService.GetOrder(c.Request.Context(), userUUID, orderID)
+	service, ok := h.investingFor(c, userUUID)
+	if !ok {
+		return
+	}
+	order, err := service.GetOrder(c.Request.Context(), userUUID, orderID)
 	if err != nil {
 		if err == investing.ErrOrderNotFound {
 			c.JSON(http.StatusNotFound, entities.ErrorResponse{
@@ -1252,7 +1486,17 @@ func (h *WalletFundingHandlers) GetPortfolio(c *gin.Context) {
 		return
 	}
 
-	portfolio, err := h.investingService.GetPortfolio(c.Request.Context(), userUUID)
+	service, ok := h.investingFor(c, userUUID)
+	if !ok {
+		return
+	}
+	// Only live portfolios are cached; paper ones change with every simulated fill
+	var portfolio *entities.Portfolio
+	if h.hotCache != nil && service == h.investingService {
+		portfolio, err = h.hotCache.Portfolio(c.Request.Context(), userUUID)
+	} else {
+		portfolio, err = service.GetPortfolio(c.Request.Context(), userUUID)
+	}
 	if err != nil {
 		h.logger.Error("Failed to get portfolio", "error", err, "user_id", userUUID)
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
@@ -1269,7 +1513,7 @@ func (h *WalletFundingHandlers) GetPortfolio(c *gin.Context) {
 
 // ChainDepositWebhook handles incoming chain deposit confirmations
 // @Summary Chain deposit webhook
-// @Description Handle blockchain deposit confirmations
+// @Description Handle blockchain deposit confirmations. Senders re-notify as confirmations grow and set removed when a re-org drops the transaction.
 // @Tags webhooks
 // @Accept json
 // @Produce json
@@ -1295,13 +1539,25 @@ func (h *WalletFundingHandlers) ChainDepositWebhook(c *gin.Context) {
 		return
 	}
 	
-	if webhook.Amount == "" || webhook.Amount == "0" {
+	if !webhook.Removed && (webhook.Amount == "" || webhook.Amount == "0") {
 		c.JSON(http.StatusBadRequest, entities.ErrorResponse{
 			Code:    "INVALID_WEBHOOK",
 			Message: "Invalid amount",
 		})
 		return
 	}
+	if chain, ok := entities.Chains().Lookup(string(webhook.Chain)); ok && !chain.ValidAddress(webhook.Address) {
+		c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+			Code:    "INVALID_WEBHOOK",
+			Message: fmt.Sprintf("Deposit address is not a valid %s address", chain.Name),
+		})
+		return
+	}
+	eventType := "deposit"
+	if webhook.Removed {
+		eventType = "deposit_removed"
+	}
+	markWebhookVerified(c, eventType, webhook.TxHash)
 
 	// Process webhook with retry logic for resilience
 	retryConfig := retry.RetryConfig{
@@ -1369,7 +1625,8 @@ func (h *WalletFundingHandlers) BrokerageFillWebhook(c *gin.Context) {
 	}
 
 	// TODO: Verify webhook signature for security
-	
+	markWebhookVerified(c, "order_"+string(webhook.Status), webhook.OrderID.String())
+
 	if err := h.investingService.ProcessBrokerageFill(c.Request.Context(), &webhook); err != nil {
 		h.logger.Error("Failed to process brokerage fill webhook", "error", err, "order_id", webhook.OrderID)
 		c.JSON(http.StatusInternalServerError, entities.ErrorResponse{
@@ -1429,20 +1686,40 @@ func (h *WalletFundingHandlers) InitiateWithdrawal(c *gin.Context) {
 		return
 	}
 
-	if req.DestinationAddress == "" {
-		c.JSON(http.StatusBadRequest, entities.ErrorResponse{
-			Code:  "INVALID_ADDRESS",
-			Message: "Destination address is required",
-		})
-		return
-	}
+	// A saved recipient was validated when it was added to the address book
+	if req.RecipientID == nil {
+		if req.DestinationAddress == "" {
+			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+				Code:  "INVALID_ADDRESS",
+				Message: "Destination address is required",
+			})
+			return
+		}
 
-	if req.DestinationChain == "" {
-		c.JSON(http.StatusBadRequest, entities.ErrorResponse{
-			Code:  "INVALID_CHAIN",
-			Message: "Destination chain is required",
-		})
-		return
+		if req.DestinationChain == "" {
+			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+				Code:  "INVALID_CHAIN",
+				Message: "Destination chain is required",
+			})
+			return
+		}
+
+		chain, ok := entities.Chains().Lookup(req.DestinationChain)
+		if !ok || !chain.Enabled {
+			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+				Code:    "INVALID_CHAIN",
+				Message: "Destination chain is not supported",
+				Details: map[string]interface{}{"supported_chains": entities.Chains().EnabledIDs()},
+			})
+			return
+		}
+		if !chain.ValidAddress(req.DestinationAddress) {
+			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
+				Code:    "INVALID_ADDRESS",
+				Message: fmt.Sprintf("Destination address is not a valid %s address", chain.Name),
+			})
+			return
+		}
 	}
 
 	response, err := h.withdrawalService.InitiateWithdrawal(c.Request.Context(), &req)
@@ -1450,7 +1727,28 @@ func (h *WalletFundingHandlers) InitiateWithdrawal(c *gin.Context) {
 		h.logger.Error("Failed to initiate withdrawal",
 			"error", err,
 			"user_id", userUUID,
-			"amount", req.Amount.String())
+			"amount", logger.RedactAmount(req.Amount))
+
+		switch {
+		case errors.Is(err, entities.ErrRecipientNotFound):
+			c.JSON(http.StatusNotFound, entities.ErrorResponse{
+				Code:    "RECIPIENT_NOT_FOUND",
+				Message: "Saved recipient not found",
+			})
+			return
+		case errors.Is(err, recipients.ErrRecipientFlagged):
+			c.JSON(http.StatusForbidden, entities.ErrorResponse{
+				Code:    "RECIPIENT_BLOCKED",
+				Message: err.Error(),
+			})
+			return
+		case errors.Is(err, recipients.ErrRecipientOnHold):
+			c.JSON(http.StatusConflict, entities.ErrorResponse{
+				Code:    "RECIPIENT_ON_HOLD",
+				Message: err.Error(),
+			})
+			return
+		}
 
 		if strings.Contains(err.Error(), "insufficient") {
 			c.JSON(http.StatusBadRequest, entities.ErrorResponse{
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/warehouse"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// WarehouseHandlers let admins watch the business event export to the data
+// warehouse and schedule backfills
+type WarehouseHandlers struct {
+	service      *warehouse.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewWarehouseHandlers creates a new warehouse handlers instance
+func NewWarehouseHandlers(service *warehouse.Service, auditService *adapters.AuditService, logger *zap.Logger) *WarehouseHandlers {
+	return &WarehouseHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// WarehouseBackfillResponse lists the streams whose cursors were moved back
+type WarehouseBackfillResponse struct {
+	Streams []entities.WarehouseStream `json:"streams"`
+	From    string                     `json:"from"`
+}
+
+// GetStatus handles GET /api/v1/admin/warehouse
+// @Summary Get warehouse export status
+// @Description Shows how far each event stream has been exported, its last error, and the schema versions events are written with
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entities.WarehouseStatus
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/warehouse [get]
+func (h *WarehouseHandlers) GetStatus(c *gin.Context) {
+	status, err := h.service.Status(c.Request.Context())
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to get warehouse export status")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Backfill handles POST /api/v1/admin/warehouse/backfill
+// @Summary Backfill warehouse events
+// @Description Moves the streams' export cursors back to a date so the worker exports their events again. Events already in the warehouse are deduplicated on event_id.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entities.WarehouseBackfillRequest true "Streams and start date"
+// @Success 202 {object} handlers.WarehouseBackfillResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/warehouse/backfill [post]
+func (h *WarehouseHandlers) Backfill(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req entities.WarehouseBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request body", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	from, err := h.service.ParseDay(req.From)
+	if err != nil {
+		respondBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	streams, err := h.service.Backfill(c.Request.Context(), req.Streams, from)
+	if err != nil {
+		h.respondServiceError(c, err, "Failed to schedule warehouse backfill")
+		return
+	}
+
+	h.auditService.LogAction(c.Request.Context(), &adminID, "backfill_warehouse_events", "warehouse_export", nil, map[string]interface{}{
+		"streams": streams,
+		"from":    req.From,
+		"reason":  req.Reason,
+	})
+	c.JSON(http.StatusAccepted, WarehouseBackfillResponse{Streams: streams, From: req.From})
+}
+
+func (h *WarehouseHandlers) respondServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrInvalidWarehouseBackfill):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, entities.ErrWarehouseExportUnavailable):
+		respondError(c, http.StatusServiceUnavailable, "EXPORT_UNAVAILABLE", "Warehouse export is not configured", nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/webhookarchive"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"go.uber.org/zap"
+)
+
+// WebhookArchiveHandlers let admins search the raw provider webhooks kept for
+// dispute investigation
+type WebhookArchiveHandlers struct {
+	service      *webhookarchive.Service
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewWebhookArchiveHandlers creates a new webhook archive handlers instance.
+// service is nil when the archive is disabled.
+func NewWebhookArchiveHandlers(service *webhookarchive.Service, auditService *adapters.AuditService, logger *zap.Logger) *WebhookArchiveHandlers {
+	return &WebhookArchiveHandlers{
+		service:      service,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// SearchWebhookArchive handles GET /api/v1/admin/webhook-archive
+// @Summary Search archived webhooks
+// @Description Lists webhooks received from providers, newest first, without their bodies. subject_type and subject_id find the webhooks linked to a deposit, withdrawal, order or user.
+// @Tags admin
+// @Produce json
+// @Param provider query string false "Provider (chain, brokerage, kyc)"
+// @Param event_type query string false "Event type"
+// @Param reference query string false "Provider reference, such as a transaction hash"
+// @Param user_id query string false "User the webhook was linked to"
+// @Param subject_type query string false "Linked record type (deposit, withdrawal, order, user)"
+// @Param subject_id query string false "Linked record ID"
+// @Param from query string false "Received at or after (RFC 3339)"
+// @Param to query string false "Received before (RFC 3339)"
+// @Param limit query int false "Webhooks to return (default 50, max 200)"
+// @Param offset query int false "Webhooks to skip"
+// @Success 200 {object} handlers.WebhookArchiveListResponse
+// @Failure 400 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhook-archive [get]
+func (h *WebhookArchiveHandlers) SearchWebhookArchive(c *gin.Context) {
+	if h.service == nil {
+		respondError(c, http.StatusServiceUnavailable, "WEBHOOK_ARCHIVE_DISABLED", "The webhook archive is not enabled", nil)
+		return
+	}
+
+	filter := entities.WebhookArchiveFilter{
+		Provider:    c.Query("provider"),
+		EventType:   c.Query("event_type"),
+		Reference:   c.Query("reference"),
+		SubjectType: c.Query("subject_type"),
+	}
+	for param, target := range map[string]**uuid.UUID{"user_id": &filter.UserID, "subject_id": &filter.SubjectID} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid "+param, nil)
+			return
+		}
+		*target = &id
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondBadRequest(c, "Invalid "+param+" time, expected RFC 3339", nil)
+			return
+		}
+		*target = &parsed
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	webhooks, err := h.service.Search(c.Request.Context(), filter)
+	if err != nil {
+		h.respondWebhookArchiveError(c, err, "Failed to search webhook archive")
+		return
+	}
+	if webhooks == nil {
+		webhooks = []*entities.ArchivedWebhook{}
+	}
+	c.JSON(http.StatusOK, WebhookArchiveListResponse{Webhooks: webhooks})
+}
+
+// GetArchivedWebhook handles GET /api/v1/admin/webhook-archive/:id
+// @Summary Get an archived webhook
+// @Description Returns a webhook exactly as the provider sent it, with the records it was linked to. The body is checked against the checksum taken on receipt.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Archived webhook ID"
+// @Success 200 {object} entities.ArchivedWebhook
+// @Failure 404 {object} entities.ErrorResponse
+// @Failure 409 {object} entities.ErrorResponse
+// @Failure 503 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/webhook-archive/{id} [get]
+func (h *WebhookArchiveHandlers) GetArchivedWebhook(c *gin.Context) {
+	if h.service == nil {
+		respondError(c, http.StatusServiceUnavailable, "WEBHOOK_ARCHIVE_DISABLED", "The webhook archive is not enabled", nil)
+		return
+	}
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid webhook ID", nil)
+		return
+	}
+
+	webhook, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		h.respondWebhookArchiveError(c, err, "Failed to get archived webhook")
+		return
+	}
+	// Payloads carry customer data, so each view is recorded
+	h.auditService.LogAction(c.Request.Context(), &adminID, "view_archived_webhook", "webhook_archive", nil, map[string]interface{}{
+		"webhook_id": webhook.ID.String(),
+		"provider":   webhook.Provider,
+		"reference":  webhook.Reference,
+	})
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookArchiveHandlers) respondWebhookArchiveError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entities.ErrArchivedWebhookNotFound):
+		respondNotFound(c, "Archived webhook not found")
+	case errors.Is(err, webhookarchive.ErrInvalidFilter):
+		respondBadRequest(c, err.Error(), nil)
+	case errors.Is(err, webhookarchive.ErrChecksumMismatch):
+		respondError(c, http.StatusConflict, "WEBHOOK_CHECKSUM_MISMATCH", err.Error(), nil)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondInternalError(c, message)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+	"go.uber.org/zap"
+)
+
+// WorkerHandlers lets administrators inspect background workers and pause
+// them during incidents
+type WorkerHandlers struct {
+	registry     *workerstatus.Registry
+	auditService *adapters.AuditService
+	logger       *zap.Logger
+}
+
+// NewWorkerHandlers creates a new worker handlers instance
+func NewWorkerHandlers(registry *workerstatus.Registry, auditService *adapters.AuditService, logger *zap.Logger) *WorkerHandlers {
+	return &WorkerHandlers{
+		registry:     registry,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// PauseWorkerRequest carries the operator's reason for pausing a worker
+type PauseWorkerRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ListWorkers handles GET /api/v1/admin/system/workers
+// @Summary List background workers
+// @Description Returns each worker's state, last and next run, error counts and backlog depth. Status is for the instance serving the request.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} handlers.WorkerListResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/system/workers [get]
+func (h *WorkerHandlers) ListWorkers(c *gin.Context) {
+	c.JSON(http.StatusOK, WorkerListResponse{Workers: h.registry.Snapshot(c.Request.Context())})
+}
+
+// PauseWorker handles POST /api/v1/admin/system/workers/:name/pause
+// @Summary Pause a background worker
+// @Description Skips the worker's scheduled runs until it is resumed. A run in progress finishes normally.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Worker name"
+// @Param request body PauseWorkerRequest true "Reason"
+// @Success 200 {object} workerstatus.Status
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/system/workers/{name}/pause [post]
+func (h *WorkerHandlers) PauseWorker(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req PauseWorkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request payload", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	tracker, err := h.registry.Pause(c.Param("name"), adminID.String(), req.Reason)
+	if err != nil {
+		h.respondWorkerError(c, err)
+		return
+	}
+
+	h.logger.Warn("Background worker paused",
+		zap.String("worker", tracker.Name()),
+		zap.String("admin_id", adminID.String()),
+		zap.String("reason", req.Reason))
+	h.auditService.LogAction(c.Request.Context(), &adminID, "pause_worker", "background_worker", nil, map[string]interface{}{
+		"worker": tracker.Name(),
+		"reason": req.Reason,
+	})
+
+	c.JSON(http.StatusOK, tracker.Status(c.Request.Context()))
+}
+
+// ResumeWorker handles POST /api/v1/admin/system/workers/:name/resume
+// @Summary Resume a paused background worker
+// @Description The worker runs again from its next scheduled tick.
+// @Tags admin
+// @Produce json
+// @Param name path string true "Worker name"
+// @Success 200 {object} workerstatus.Status
+// @Failure 404 {object} entities.ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/system/workers/{name}/resume [post]
+func (h *WorkerHandlers) ResumeWorker(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondUnauthorized(c, "Authentication required")
+		return
+	}
+
+	tracker, err := h.registry.Resume(c.Param("name"))
+	if err != nil {
+		h.respondWorkerError(c, err)
+		return
+	}
+
+	h.logger.Info("Background worker resumed",
+		zap.String("worker", tracker.Name()),
+		zap.String("admin_id", adminID.String()))
+	h.auditService.LogAction(c.Request.Context(), &adminID, "resume_worker", "background_worker", nil, map[string]interface{}{
+		"worker": tracker.Name(),
+	})
+
+	c.JSON(http.StatusOK, tracker.Status(c.Request.Context()))
+}
+
+func (h *WorkerHandlers) respondWorkerError(c *gin.Context, err error) {
+	if errors.Is(err, workerstatus.ErrWorkerNotFound) {
+		respondNotFound(c, "Worker not found")
+		return
+	}
+	h.logger.Error("Failed to update worker", zap.Error(err))
+	respondInternalError(c, "Failed to update worker")
+}
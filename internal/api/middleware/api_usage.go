@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/internal/domain/services/apiusage"
+)
+
+// APIUsage counts every request by user, API key and route, with its status
+// and latency, for the API usage analytics. Register it outside Recovery so
+// panics are counted as server errors.
+func APIUsage(usage *apiusage.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if usage == nil || !usage.Enabled() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "(unmatched)"
+		}
+		if keyID, ok := c.Value("api_key_id").(uuid.UUID); ok {
+			usage.RecordAPIKey(keyID, getUserIDFromContext(c), c.Request.Method, route, c.Writer.Status(), time.Since(start))
+			return
+		}
+		usage.Record(getUserIDFromContext(c), c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// ConsentGate exposes the mandatory agreements a user has yet to accept
+type ConsentGate interface {
+	PendingMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.AgreementVersion, error)
+}
+
+// RequireConsents blocks the request until the authenticated user has
+// accepted every mandatory agreement version
+func RequireConsents(gate ConsentGate, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if gate == nil {
+			c.Next()
+			return
+		}
+
+		userIDValue, exists := c.Get("user_id")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Authentication required",
+			})
+			return
+		}
+
+		userID, ok := userIDValue.(uuid.UUID)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Unable to parse user identity",
+			})
+			return
+		}
+
+		pending, err := gate.PendingMandatory(c.Request.Context(), userID)
+		if err != nil {
+			log.Error("Failed to check user consents",
+				zap.Error(err),
+				zap.String("user_id", userID.String()),
+				zap.String("request_id", c.GetString("request_id")))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    "CONSENT_CHECK_ERROR",
+				"message": "Unable to verify accepted agreements at this time",
+			})
+			return
+		}
+
+		if len(pending) > 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":       "CONSENT_REQUIRED",
+				"message":    "Please accept the updated agreements to continue trading",
+				"agreements": pending,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/pkg/auth"
+)
+
+// Auth modes recorded on the request context by Authentication
+const (
+	AuthModeBearer = "bearer"
+	AuthModeCookie = "cookie"
+)
+
+// refreshCookiePath limits the refresh cookie to the endpoints that use it
+const refreshCookiePath = "/api/v1/auth"
+
+// CookieSessions issues httpOnly session cookies to browser clients and
+// derives each session's CSRF token. The token is an HMAC of the session ID,
+// so any instance can check it and it changes with every new login.
+type CookieSessions struct {
+	cfg        config.WebSessionConfig
+	jwtSecret  string
+	csrfKey    []byte
+	sameSite   http.SameSite
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewCookieSessions creates cookie session support from the app configuration
+func NewCookieSessions(cfg *config.Config) *CookieSessions {
+	secret := cfg.WebSession.CSRFSecret
+	if secret == "" {
+		secret = "csrf:" + cfg.JWT.Secret
+	}
+	key := sha256.Sum256([]byte(secret))
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(cfg.WebSession.SameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	return &CookieSessions{
+		cfg:        cfg.WebSession,
+		jwtSecret:  cfg.JWT.Secret,
+		csrfKey:    key[:],
+		sameSite:   sameSite,
+		accessTTL:  time.Duration(cfg.JWT.AccessTTL) * time.Second,
+		refreshTTL: time.Duration(cfg.JWT.RefreshTTL) * time.Second,
+	}
+}
+
+func (s *CookieSessions) modeEnabled(mode string) bool {
+	for _, enabled := range s.cfg.AuthModes {
+		if enabled == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// CookieEnabled reports whether browser clients may sign in with cookies
+func (s *CookieSessions) CookieEnabled() bool {
+	return s.modeEnabled(AuthModeCookie)
+}
+
+// BearerEnabled reports whether Authorization: Bearer tokens are accepted.
+// An empty mode list keeps the bearer-only behavior.
+func (s *CookieSessions) BearerEnabled() bool {
+	return len(s.cfg.AuthModes) == 0 || s.modeEnabled(AuthModeBearer)
+}
+
+// CSRFToken returns the CSRF token for a session
+func (s *CookieSessions) CSRFToken(sessionID string) string {
+	mac := hmac.New(sha256.New, s.csrfKey)
+	mac.Write([]byte(sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCSRF reports whether token is the session's CSRF token
+func (s *CookieSessions) ValidCSRF(sessionID, token string) bool {
+	if sessionID == "" || token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(s.CSRFToken(sessionID)))
+}
+
+// AccessToken returns the access token cookie, if any
+func (s *CookieSessions) AccessToken(c *gin.Context) string {
+	value, _ := c.Cookie(s.cfg.AccessCookieName)
+	return value
+}
+
+// RefreshToken returns the refresh token cookie, if any
+func (s *CookieSessions) RefreshToken(c *gin.Context) string {
+	value, _ := c.Cookie(s.cfg.RefreshCookieName)
+	return value
+}
+
+// SessionID returns the session of a request signed in with cookies. It
+// reads the access cookie, or the refresh cookie on the auth endpoints.
+func (s *CookieSessions) SessionID(c *gin.Context) (string, bool) {
+	if !s.CookieEnabled() || c.GetHeader("Authorization") != "" {
+		return "", false
+	}
+	for _, token := range []string{s.AccessToken(c), s.RefreshToken(c)} {
+		if token == "" {
+			continue
+		}
+		if sessionID, err := auth.SessionID(token, s.jwtSecret); err == nil {
+			return sessionID, true
+		}
+	}
+	return "", false
+}
+
+// Issue sets the session cookies for a token pair and returns the session's
+// CSRF token, which is also sent in the X-CSRF-Token header and in a cookie
+// the web app can read
+func (s *CookieSessions) Issue(c *gin.Context, pair *auth.TokenPair) string {
+	csrfToken := s.CSRFToken(pair.SessionID)
+	s.setCookie(c, s.cfg.AccessCookieName, pair.AccessToken, "/", s.accessTTL, true)
+	if pair.RefreshToken != "" {
+		s.setCookie(c, s.cfg.RefreshCookieName, pair.RefreshToken, refreshCookiePath, s.refreshTTL, true)
+	}
+	s.setCookie(c, s.cfg.CSRFCookieName, csrfToken, "/", s.refreshTTL, false)
+	c.Header("X-CSRF-Token", csrfToken)
+	return csrfToken
+}
+
+// Clear expires the session cookies
+func (s *CookieSessions) Clear(c *gin.Context) {
+	s.setCookie(c, s.cfg.AccessCookieName, "", "/", -1, true)
+	s.setCookie(c, s.cfg.RefreshCookieName, "", refreshCookiePath, -1, true)
+	s.setCookie(c, s.cfg.CSRFCookieName, "", "/", -1, false)
+}
+
+func (s *CookieSessions) setCookie(c *gin.Context, name, value, path string, ttl time.Duration, httpOnly bool) {
+	maxAge := int(ttl / time.Second)
+	if ttl < 0 {
+		maxAge = -1
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   s.cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   s.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: s.sameSite,
+	})
+}
@@ -11,8 +11,9 @@ import (
 )
 
 type CSRFStore struct {
-	tokens map[string]time.Time
-	mu     sync.RWMutex
+	tokens   map[string]time.Time
+	mu       sync.RWMutex
+	sessions *CookieSessions
 }
 
 func NewCSRFStore() *CSRFStore {
@@ -23,6 +24,29 @@ func NewCSRFStore() *CSRFStore {
 	return store
 }
 
+// SetCookieSessions checks requests signed in with cookies against their
+// session's CSRF token instead of the shared token store
+func (s *CSRFStore) SetCookieSessions(sessions *CookieSessions) {
+	s.sessions = sessions
+}
+
+// cookieSessionID returns the session of a cookie-authenticated request.
+// Authentication records it on protected routes; on the auth endpoints it
+// is read from the session cookies.
+func (s *CSRFStore) cookieSessionID(c *gin.Context) (string, bool) {
+	if s.sessions == nil {
+		return "", false
+	}
+	switch c.GetString("auth_mode") {
+	case AuthModeCookie:
+		return c.GetString("auth_session_id"), true
+	case AuthModeBearer:
+		return "", false
+	default:
+		return s.sessions.SessionID(c)
+	}
+}
+
 func (s *CSRFStore) cleanup() {
 	ticker := time.NewTicker(10 * time.Minute)
 	defer ticker.Stop()
@@ -72,6 +96,19 @@ func CSRFProtection(store *CSRFStore) gin.HandlerFunc {
 		}
 
 		token := c.GetHeader("X-CSRF-Token")
+		if sessionID, ok := store.cookieSessionID(c); ok {
+			if !store.sessions.ValidCSRF(sessionID, token) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "CSRF token validation failed",
+					"request_id": c.GetString("request_id"),
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
 		if token == "" {
 			token = c.PostForm("csrf_token")
 		}
@@ -89,9 +126,16 @@ func CSRFProtection(store *CSRFStore) gin.HandlerFunc {
 	}
 }
 
+// CSRFToken sends a CSRF token in the X-CSRF-Token header of every response.
+// Cookie sessions get their session's token.
 func CSRFToken(store *CSRFStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token := store.Generate()
+		var token string
+		if sessionID, ok := store.cookieSessionID(c); ok {
+			token = store.sessions.CSRFToken(sessionID)
+		} else {
+			token = store.Generate()
+		}
 		c.Header("X-CSRF-Token", token)
 		c.Set("csrf_token", token)
 		c.Next()
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// DelegateGrantKey is the context key holding the authenticated delegate's grant
+const DelegateGrantKey = "delegate_grant"
+
+// DelegateAuthenticator resolves a delegate session token to its grant
+type DelegateAuthenticator interface {
+	Authenticate(ctx context.Context, sessionToken string) (*entities.DelegateGrant, error)
+}
+
+// DelegateAuthentication admits requests carrying a delegate session token.
+// It is the only way into the delegate endpoints, and delegate tokens are
+// not accepted anywhere else: no user identity is set, so handlers behind
+// Authentication never see a delegate.
+func DelegateAuthentication(authenticator DelegateAuthenticator, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Delegate session token required",
+			})
+			return
+		}
+
+		grant, err := authenticator.Authenticate(c.Request.Context(), tokenParts[1])
+		if err != nil {
+			if !errors.Is(err, entities.ErrDelegateSessionInvalid) {
+				log.Error("Failed to validate delegate session",
+					zap.Error(err),
+					zap.String("request_id", c.GetString("request_id")))
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Delegate session invalid or expired",
+			})
+			return
+		}
+
+		c.Set(DelegateGrantKey, grant)
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// EntitlementChecker exposes the feature limits of the user's subscription plan
+type EntitlementChecker interface {
+	Entitlements(ctx context.Context, userID uuid.UUID) (entities.Entitlements, error)
+}
+
+// RequireEntitlement enforces that the authenticated user's plan includes a
+// feature. The plan's entitlements are stored in the context as
+// "entitlements" so metered handlers can compare usage against the limit.
+func RequireEntitlement(checker EntitlementChecker, feature string, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.Next()
+			return
+		}
+
+		userIDValue, exists := c.Get("user_id")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Authentication required",
+			})
+			return
+		}
+
+		userID, ok := userIDValue.(uuid.UUID)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Unable to parse user identity",
+			})
+			return
+		}
+
+		entitlements, err := checker.Entitlements(c.Request.Context(), userID)
+		if err != nil {
+			log.Error("Failed to load plan entitlements",
+				zap.Error(err),
+				zap.String("user_id", userID.String()),
+				zap.String("feature", feature),
+				zap.String("request_id", c.GetString("request_id")))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    "ENTITLEMENT_CHECK_ERROR",
+				"message": "Unable to verify your plan at this time",
+			})
+			return
+		}
+
+		if entitlements.Limit(feature) == 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    "PLAN_UPGRADE_REQUIRED",
+				"message": "This feature is not included in your plan",
+				"feature": feature,
+			})
+			return
+		}
+
+		c.Set("entitlements", entitlements)
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/httpcapture"
+)
+
+// captureWriter copies up to limit bytes of the response body as it is written
+type captureWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) keep(data []byte) {
+	room := w.limit - w.body.Len()
+	if len(data) > room {
+		data = data[:room]
+		w.overflow = true
+	}
+	w.body.Write(data)
+}
+
+// HTTPCapture records sanitized copies of sampled requests, and of every
+// request from users or to routes an admin has flagged, for debugging.
+// Bodies are only buffered while capturing is active.
+func HTTPCapture(capture *httpcapture.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if capture == nil || !capture.Active() {
+			c.Next()
+			return
+		}
+
+		limit := capture.MaxBodyBytes()
+		start := time.Now()
+
+		var requestBody []byte
+		requestOverflow := false
+		if c.Request.Body != nil {
+			original := c.Request.Body
+			requestBody, _ = io.ReadAll(io.LimitReader(original, int64(limit)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), original), original}
+			if len(requestBody) > limit {
+				requestBody = requestBody[:limit]
+				requestOverflow = true
+			}
+		}
+
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+
+		c.Next()
+
+		userID := getUserIDFromContext(c)
+		route := c.FullPath()
+		reason, ok := capture.Decide(userID, route)
+		if !ok {
+			return
+		}
+		if route == "" {
+			route = "(unmatched)"
+		}
+
+		capture.Record(&httpcapture.Exchange{
+			Capture: entities.HTTPCapture{
+				RequestID:  c.GetString("request_id"),
+				UserID:     userID,
+				Method:     c.Request.Method,
+				Route:      route,
+				Path:       c.Request.URL.Path,
+				Query:      c.Request.URL.RawQuery,
+				StatusCode: writer.Status(),
+				DurationMs: time.Since(start).Milliseconds(),
+				ClientIP:   c.ClientIP(),
+				UserAgent:  c.Request.UserAgent(),
+				Reason:     reason,
+				CreatedAt:  start,
+			},
+			Header:              c.Request.Header,
+			RequestBody:         requestBody,
+			RequestContentType:  c.ContentType(),
+			RequestOverflow:     requestOverflow,
+			ResponseBody:        writer.body.Bytes(),
+			ResponseContentType: writer.Header().Get("Content-Type"),
+			ResponseOverflow:    writer.overflow,
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// FeatureGate exposes the minimal method needed to gate features by jurisdiction
+type FeatureGate interface {
+	IsFeatureAllowed(ctx context.Context, userID uuid.UUID, feature entities.JurisdictionFeature) (bool, string, error)
+}
+
+// RequireFeature enforces that a feature is enabled in the authenticated user's country
+func RequireFeature(gate FeatureGate, feature entities.JurisdictionFeature, log *zap.Logger) gin.HandlerFunc {
+	return RequireAnyFeature(gate, []entities.JurisdictionFeature{feature}, log)
+}
+
+// RequireAnyFeature enforces that at least one of features is enabled in the
+// authenticated user's country, e.g. live or paper trading. Rejections report
+// the first feature.
+func RequireAnyFeature(gate FeatureGate, features []entities.JurisdictionFeature, log *zap.Logger) gin.HandlerFunc {
+	feature := features[0]
+	return func(c *gin.Context) {
+		if gate == nil {
+			c.Next()
+			return
+		}
+
+		userIDValue, exists := c.Get("user_id")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Authentication required",
+			})
+			return
+		}
+
+		userID, ok := userIDValue.(uuid.UUID)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    "INTERNAL_ERROR",
+				"message": "Unable to parse user identity",
+			})
+			return
+		}
+
+		var allowed bool
+		var country string
+		var err error
+		for _, candidate := range features {
+			allowed, country, err = gate.IsFeatureAllowed(c.Request.Context(), userID, candidate)
+			if err != nil || allowed {
+				break
+			}
+		}
+		if err != nil {
+			log.Error("Failed to evaluate jurisdiction for feature gating",
+				zap.Error(err),
+				zap.String("user_id", userID.String()),
+				zap.String("feature", string(feature)),
+				zap.String("request_id", c.GetString("request_id")))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code":    "JURISDICTION_CHECK_ERROR",
+				"message": "Unable to verify feature availability at this time",
+			})
+			return
+		}
+
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    "FEATURE_NOT_AVAILABLE",
+				"message": "This feature is not available in your country",
+				"feature": feature,
+				"country": country,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
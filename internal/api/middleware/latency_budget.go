@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// LatencyBudgetConfig sets how long requests may run and when the server
+// turns new requests away
+type LatencyBudgetConfig struct {
+	DefaultTimeout time.Duration  // Budget for routes without their own; zero leaves them unbounded
+	Routes         []RouteTimeout // Per-route budgets, longest matching path wins
+	MaxInFlight    int            // Requests served at once before new ones are shed; zero disables
+	MaxGoroutines  int            // Goroutine count above which new requests are shed; zero disables
+	RetryAfter     time.Duration  // Sent to shed clients in Retry-After
+	ShedExempt     []string       // Path prefixes that are never shed, e.g. health checks
+}
+
+// RouteTimeout is the budget for requests whose route starts with Path. An
+// empty Method matches every method.
+type RouteTimeout struct {
+	Method  string
+	Path    string
+	Timeout time.Duration
+}
+
+// LatencyBudget gives every request a deadline from its route's budget, so
+// database and provider calls made with the request context stop when the
+// budget runs out, and sheds new requests with 503 while the server is
+// saturated. A handler that runs out of budget without responding gets a
+// 504 written for it.
+func LatencyBudget(config LatencyBudgetConfig, logger *zap.Logger) gin.HandlerFunc {
+	var inFlight atomic.Int64
+	retryAfter := strconv.Itoa(int((config.RetryAfter + time.Second - 1) / time.Second))
+	if config.RetryAfter <= 0 {
+		retryAfter = "1"
+	}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		current := inFlight.Add(1)
+		metrics.HTTPRequestsInFlight.Set(float64(current))
+		defer func() {
+			metrics.HTTPRequestsInFlight.Set(float64(inFlight.Add(-1)))
+		}()
+
+		if reason := shedReason(config, current, route); reason != "" {
+			metrics.HTTPRequestsShedTotal.WithLabelValues(reason).Inc()
+			logger.Warn("Shedding request",
+				zap.String("reason", reason),
+				zap.String("method", c.Request.Method),
+				zap.String("route", route),
+				zap.Int64("in_flight", current))
+			c.Header("Retry-After", retryAfter)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"code":       "SERVER_BUSY",
+				"message":    "The server is busy, please retry shortly",
+				"request_id": c.GetString("request_id"),
+			})
+			return
+		}
+
+		timeout := routeTimeout(config, c.Request.Method, route)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+		metrics.HTTPRequestTimeoutsTotal.WithLabelValues(c.Request.Method, route).Inc()
+		logger.Warn("Request exceeded its latency budget",
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.Duration("budget", timeout))
+		if !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"code":       "REQUEST_TIMEOUT",
+				"message":    "Request processing timeout",
+				"request_id": c.GetString("request_id"),
+			})
+		}
+	}
+}
+
+func shedReason(config LatencyBudgetConfig, inFlight int64, route string) string {
+	for _, prefix := range config.ShedExempt {
+		if strings.HasPrefix(route, prefix) {
+			return ""
+		}
+	}
+	if config.MaxInFlight > 0 && inFlight > int64(config.MaxInFlight) {
+		return "in_flight"
+	}
+	if config.MaxGoroutines > 0 && runtime.NumGoroutine() > config.MaxGoroutines {
+		return "goroutines"
+	}
+	return ""
+}
+
+func routeTimeout(config LatencyBudgetConfig, method, route string) time.Duration {
+	timeout := config.DefaultTimeout
+	best := -1
+	for _, rt := range config.Routes {
+		if !strings.HasPrefix(route, rt.Path) {
+			continue
+		}
+		// A longer path wins; on the same path a method-specific budget wins
+		score := 2 * len(rt.Path)
+		if rt.Method != "" {
+			if !strings.EqualFold(rt.Method, method) {
+				continue
+			}
+			score++
+		}
+		if score > best {
+			timeout = rt.Timeout
+			best = score
+		}
+	}
+	return timeout
+}
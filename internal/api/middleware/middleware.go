@@ -36,9 +36,11 @@ type SessionInfo struct {
 
 // APIKeyInfo represents API key information
 type APIKeyInfo struct {
-	ID     uuid.UUID
-	UserID *uuid.UUID
-	Scopes []string
+	ID          uuid.UUID
+	UserID      *uuid.UUID
+	Scopes      []string
+	TenantID    *uuid.UUID // developer tenant that issued the key
+	Environment string
 }
 
 const (
@@ -174,9 +176,9 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
 
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Request-ID")
-		c.Header("Access-Control-Expose-Headers", "X-Request-ID")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Request-ID, X-CSRF-Token, X-Auth-Mode")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-CSRF-Token")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "3600")
 
@@ -255,11 +257,20 @@ func SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
-// Authentication validates JWT tokens with session management
+// Authentication validates JWT tokens with session management. Tokens come
+// from the Authorization header or, for browser clients, the access cookie,
+// depending on the enabled auth modes.
 func Authentication(cfg *config.Config, log *logger.Logger, sessionService SessionValidator) gin.HandlerFunc {
+	cookies := NewCookieSessions(cfg)
 	return func(c *gin.Context) {
+		authMode := AuthModeBearer
+		tokenString := ""
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if authHeader == "" && cookies.CookieEnabled() {
+			tokenString = cookies.AccessToken(c)
+			authMode = AuthModeCookie
+		}
+		if authHeader == "" && tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":      "Authorization header required",
 				"request_id": c.GetString("request_id"),
@@ -268,18 +279,29 @@ func Authentication(cfg *config.Config, log *logger.Logger, sessionService Sessi
 			return
 		}
 
-		// Extract token from "Bearer <token>"
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":      "Invalid authorization format",
-				"request_id": c.GetString("request_id"),
-			})
-			c.Abort()
-			return
+		if authMode == AuthModeBearer {
+			if !cookies.BearerEnabled() {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":      "Bearer tokens are not accepted; sign in with a session cookie",
+					"request_id": c.GetString("request_id"),
+				})
+				c.Abort()
+				return
+			}
+
+			// Extract token from "Bearer <token>"
+			tokenParts := strings.Split(authHeader, " ")
+			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error":      "Invalid authorization format",
+					"request_id": c.GetString("request_id"),
+				})
+				c.Abort()
+				return
+			}
+			tokenString = tokenParts[1]
 		}
 
-		tokenString := tokenParts[1]
 		claims, err := auth.ValidateToken(tokenString, cfg.JWT.Secret)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -308,6 +330,8 @@ func Authentication(cfg *config.Config, log *logger.Logger, sessionService Sessi
 		c.Set("user_id", claims.UserID)
 		c.Set("user_role", claims.Role)
 		c.Set("user_email", claims.Email)
+		c.Set("auth_mode", authMode)
+		c.Set("auth_session_id", claims.ID)
 
 		c.Next()
 	}
@@ -329,6 +353,22 @@ func AdminAuth(db *sql.DB, log *logger.Logger) gin.HandlerFunc {
 	}
 }
 
+// SuperAdminAuth restricts a route to super admins. It runs after AdminAuth
+// on routes that move money or alter orders by hand.
+func SuperAdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("user_role") != "super_admin" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":      "Super admin access required",
+				"request_id": c.GetString("request_id"),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // ValidateAPIKey validates API keys using the API key service
 func ValidateAPIKey(apikeyService APIKeyValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -355,6 +395,10 @@ func ValidateAPIKey(apikeyService APIKeyValidator) gin.HandlerFunc {
 		// Add API key info to context
 		c.Set("api_key_id", keyInfo.ID)
 		c.Set("api_key_scopes", keyInfo.Scopes)
+		c.Set("api_key_environment", keyInfo.Environment)
+		if keyInfo.TenantID != nil {
+			c.Set("developer_tenant_id", *keyInfo.TenantID)
+		}
 		if keyInfo.UserID != nil {
 			c.Set("user_id", *keyInfo.UserID)
 		}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/pkg/auth"
+)
+
+// SigningKeyResolver looks up the keys internal services sign requests with
+type SigningKeyResolver interface {
+	// ResolveSigningKey returns an active key and its secret
+	ResolveSigningKey(ctx context.Context, keyID string) (*SigningKeyInfo, error)
+	// UseNonce records a nonce until expiresAt, returning false if the key
+	// already used it
+	UseNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// SigningKeyInfo is a resolved signing key
+type SigningKeyInfo struct {
+	ID          uuid.UUID
+	KeyID       string
+	Tenant      string
+	Environment string
+	Scopes      []string
+	Secret      string
+}
+
+// RequireSignedRequest authenticates internal service calls by their HMAC
+// signature rather than a user JWT. The key must belong to this environment,
+// the timestamp must be within tolerance of now and each nonce is accepted
+// once, so a captured request cannot be replayed.
+func RequireSignedRequest(resolver SigningKeyResolver, environment string, tolerance time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reject := func(message string) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":      message,
+				"request_id": c.GetString("request_id"),
+			})
+			c.Abort()
+		}
+
+		keyID := strings.TrimSpace(c.GetHeader(auth.SigningKeyIDHeader))
+		timestampHeader := c.GetHeader(auth.SigningTimestampHeader)
+		nonce := c.GetHeader(auth.SigningNonceHeader)
+		signature := c.GetHeader(auth.SignatureHeader)
+		if keyID == "" || timestampHeader == "" || nonce == "" || signature == "" {
+			reject("Request signature required")
+			return
+		}
+		if len(nonce) > 64 {
+			reject("Invalid request signature")
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+		if err != nil {
+			reject("Invalid request signature")
+			return
+		}
+		signedAt := time.Unix(timestamp, 0)
+		if skew := time.Since(signedAt); skew > tolerance || skew < -tolerance {
+			reject("Request signature expired")
+			return
+		}
+
+		key, err := resolver.ResolveSigningKey(c.Request.Context(), keyID)
+		if err != nil || key.Environment != environment {
+			reject("Invalid request signature")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, MaxRequestSize))
+		if err != nil {
+			reject("Invalid request signature")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		canonical := auth.CanonicalRequest(c.Request.Method, c.Request.URL.EscapedPath(), c.Request.URL.RawQuery, timestamp, nonce, body)
+		if !auth.VerifySignature(key.Secret, canonical, signature) {
+			reject("Invalid request signature")
+			return
+		}
+
+		// Checked after the signature so unsigned requests cannot fill the
+		// nonce table
+		fresh, err := resolver.UseNonce(c.Request.Context(), keyID, nonce, signedAt.Add(tolerance))
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":      "Unable to verify request signature",
+				"request_id": c.GetString("request_id"),
+			})
+			c.Abort()
+			return
+		}
+		if !fresh {
+			reject("Request already processed")
+			return
+		}
+
+		c.Set("signing_key_id", key.KeyID)
+		c.Set("service_tenant", key.Tenant)
+		c.Set("api_key_scopes", key.Scopes)
+		c.Next()
+	}
+}
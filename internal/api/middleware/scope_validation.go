@@ -53,6 +53,28 @@ func RequireScope(requiredScope string) gin.HandlerFunc {
 	}
 }
 
+// RequireAPIKeyEnvironment rejects API keys issued for another environment,
+// so a developer's sandbox key cannot reach live accounts. Keys without an
+// environment are live keys.
+func RequireAPIKeyEnvironment(environment string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyEnvironment := c.GetString("api_key_environment")
+		if keyEnvironment == "" {
+			keyEnvironment = "live"
+		}
+		if keyEnvironment != environment {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":      "API key is not valid in the " + environment + " environment",
+				"request_id": c.GetString("request_id"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // Require2FA validates that the user has completed 2FA verification
 func Require2FA(twofaService TwoFAValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stack-service/stack_service/internal/domain/services/shadow"
+)
+
+// Shadow replays sampled requests to shadowed routes against their candidate
+// implementation once the live handler has responded. The user always gets
+// the live response; the candidate runs later on the shadow service's
+// workers. Requests with bodies too large to buffer are not shadowed.
+func Shadow(service *shadow.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if service == nil || !service.Shadowing(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		limit := service.MaxBodyBytes()
+		var requestBody []byte
+		if c.Request.Body != nil {
+			original := c.Request.Body
+			requestBody, _ = io.ReadAll(io.LimitReader(original, int64(limit)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), original), original}
+			if len(requestBody) > limit {
+				c.Next()
+				return
+			}
+		}
+
+		// The clone is taken before the handlers run so it carries the
+		// request as the client sent it
+		replay := c.Request.Clone(context.Background())
+		replay.Body = io.NopCloser(bytes.NewReader(requestBody))
+		replay.ContentLength = int64(len(requestBody))
+		replay.RequestURI = ""
+		replay.Header.Set(shadow.Header, "true")
+
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+
+		c.Next()
+
+		service.Submit(c.Request.Method, c.FullPath(), &shadow.Exchange{
+			RequestID: c.GetString("request_id"),
+			Request:   replay,
+			Status:    writer.Status(),
+			Body:      writer.body.Bytes(),
+			Truncated: writer.overflow,
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/services/webhookarchive"
+)
+
+// Context keys a webhook handler sets once the request has passed
+// verification, naming what the provider sent
+const (
+	WebhookEventTypeKey = "webhook_event_type"
+	WebhookReferenceKey = "webhook_reference"
+)
+
+// ArchiveWebhook keeps the raw body of each webhook from the provider that
+// its handler marks verified, whether or not processing then succeeds.
+// Webhooks that were processed are linked to the records they touched.
+// Archiving never changes the response the provider gets.
+func ArchiveWebhook(archive *webhookarchive.Service, provider string, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if archive == nil || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		receivedAt := time.Now()
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"code":    "INVALID_REQUEST",
+				"message": "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		c.Next()
+
+		eventType := c.GetString(WebhookEventTypeKey)
+		if eventType == "" {
+			return
+		}
+		webhook, err := archive.Archive(c.Request.Context(), &webhookarchive.ArchiveRequest{
+			Provider:       provider,
+			EventType:      eventType,
+			Reference:      c.GetString(WebhookReferenceKey),
+			ContentType:    c.ContentType(),
+			Payload:        body,
+			ResponseStatus: c.Writer.Status(),
+			ReceivedAt:     receivedAt,
+		})
+		if err != nil {
+			log.Error("Failed to archive webhook",
+				zap.Error(err),
+				zap.String("provider", provider),
+				zap.String("event_type", eventType),
+				zap.String("request_id", c.GetString("request_id")))
+			return
+		}
+		if c.Writer.Status() >= http.StatusMultipleChoices {
+			return
+		}
+		if err := archive.Link(c.Request.Context(), webhook); err != nil {
+			log.Warn("Failed to link archived webhook",
+				zap.Error(err),
+				zap.String("webhook_id", webhook.ID.String()))
+		}
+	}
+}
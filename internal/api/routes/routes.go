@@ -2,12 +2,17 @@ package routes
 
 import (
 	"context"
+	"time"
 
 	"github.com/stack-service/stack_service/internal/api/handlers"
 	"github.com/stack-service/stack_service/internal/api/middleware"
+	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/internal/domain/services/apikey"
 	"github.com/stack-service/stack_service/internal/domain/services/session"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
 	"github.com/stack-service/stack_service/internal/infrastructure/di"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"github.com/stack-service/stack_service/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
@@ -42,9 +47,13 @@ func SetupRoutes(container *di.Container) *gin.Engine {
 	router.Use(tracing.HTTPMiddleware()) // Tracing should be early in the chain
 	router.Use(middleware.RequestID())
 	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.LatencyBudget(latencyBudgetConfig(container.Config.LatencyBudget), container.ZapLog))
 	router.Use(middleware.RequestSizeLimit())
 	router.Use(middleware.InputValidation())
 	router.Use(middleware.Logger(container.Logger))
+	router.Use(middleware.HTTPCapture(container.GetHTTPCaptureService()))
+	router.Use(middleware.Shadow(container.GetShadowService()))
+	router.Use(middleware.APIUsage(container.GetAPIUsageService()))
 	router.Use(middleware.Recovery(container.Logger))
 	router.Use(middleware.CORS(container.Config.Server.AllowedOrigins))
 	router.Use(middleware.RateLimit(container.Config.Server.RateLimitPerMin))
@@ -52,12 +61,21 @@ func SetupRoutes(container *di.Container) *gin.Engine {
 	router.Use(middleware.APIVersionMiddleware(container.Config.Server.SupportedVersions))
 	router.Use(middleware.PaginationMiddleware())
 
-	// CSRF protection
+	// CSRF protection. Requests signed in with cookies are checked against
+	// their session's token; bearer clients keep using the shared store.
+	cookieSessions := middleware.NewCookieSessions(container.Config)
 	csrfStore := middleware.NewCSRFStore()
+	csrfStore.SetCookieSessions(cookieSessions)
 	router.Use(middleware.CSRFToken(csrfStore))
 
 	// Initialize handlers with services from DI container
 	coreHandlers := handlers.NewCoreHandlers(container.DB, container.Logger)
+	coreHandlers.AddReadinessCheck(container.GetCircleSubscriptionService())
+	if container.Startup != nil {
+		for _, checker := range container.Startup.Checkers() {
+			coreHandlers.AddReadinessCheck(checker)
+		}
+	}
 	allocationHandlers := handlers.NewAllocationHandlers(
 		container.GetAllocationService(),
 		container.Logger,
@@ -92,6 +110,12 @@ func SetupRoutes(container *di.Container) *gin.Engine {
 		container.EmailService,
 		container.KYCProvider,
 	)
+	authHandlers.SetCookieSessions(cookieSessions)
+	authHandlers.SetPasswordPolicy(container.GetPasswordPolicyService())
+	geoService := container.GetGeoIPService()
+	if geoService != nil {
+		authHandlers.SetGeoAccess(geoService)
+	}
 	securityHandlers := handlers.NewSecurityHandlers(
 		container.GetPasscodeService(),
 		container.GetOnboardingService(),
@@ -108,6 +132,76 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 	container.Logger,
 )
 
+	auditHandlers := handlers.NewAuditHandlers(container.AuditService, container.ZapLog)
+	retentionHandlers := handlers.NewRetentionHandlers(container.GetRetentionService(), container.ZapLog)
+	piiVaultHandlers := handlers.NewPIIVaultHandlers(container.GetPIIVaultService(), container.AuditService, container.ZapLog)
+	depositReferenceHandlers := handlers.NewDepositReferenceHandlers(container.GetDepositReferenceService(),
+		container.Config.Deposits.BankWebhookSecret, container.ZapLog)
+	suspenseHandlers := handlers.NewSuspenseHandlers(container.GetSuspenseService(), container.AuditService, container.ZapLog)
+	alertingHandlers := handlers.NewAlertingHandlers(container.GetAlertingService(), container.AuditService, container.ZapLog)
+	residencyHandlers := handlers.NewResidencyHandlers(container.GetResidencyService(), container.AuditService, container.ZapLog)
+	cacheWarmHandlers := handlers.NewCacheWarmHandlers(container.GetHotCacheService(), container.AuditService, container.ZapLog)
+	logLevelHandlers := handlers.NewLogLevelHandlers(logger.ModuleLevels(),
+		time.Duration(container.Config.Logging.OverrideMinutes)*time.Minute,
+		time.Duration(container.Config.Logging.MaxOverrideMinutes)*time.Minute,
+		container.AuditService, container.ZapLog)
+	restoreDrillHandlers := handlers.NewRestoreDrillHandlers(container.GetRestoreDrillService(), container.ZapLog)
+	jurisdictionHandlers := handlers.NewJurisdictionHandlers(container.GetJurisdictionService(), container.ZapLog)
+	jurisdictionGate := container.GetJurisdictionService()
+	custodialHandlers := handlers.NewCustodialHandlers(container.GetCustodialService(), container.ZapLog)
+	trustedContactHandlers := handlers.NewTrustedContactHandlers(container.GetTrustedContactService(), container.ZapLog)
+	adminCaseHandlers := handlers.NewAdminCaseHandlers(container.GetCaseService(), container.GetInactivityService(), container.ZapLog)
+	reactivationHandlers := handlers.NewReactivationHandlers(container.GetReactivationService(), container.UserRepo, container.ZapLog)
+	eddHandlers := handlers.NewEDDHandlers(container.GetEDDService(), container.AuditService, container.ZapLog)
+	kybHandlers := handlers.NewKYBHandlers(container.GetKYBService(), container.AuditService, container.ZapLog)
+	rateHandlers := handlers.NewRateHandlers(container.GetRateService(), container.AuditService, container.ZapLog)
+	consentHandlers := handlers.NewConsentHandlers(container.GetConsentService(), container.AuditService, container.ZapLog)
+	consentGate := container.GetConsentService()
+	approvalHandlers := handlers.NewApprovalHandlers(container.GetApprovalService(), container.BasketDeletion, container.AuditService, container.ZapLog)
+	basketAdminHandlers := handlers.NewBasketAdminHandlers(container.BasketLocalization, container.AuditService, container.ZapLog)
+	balanceHoldHandlers := handlers.NewBalanceHoldHandlers(container.GetBalanceHoldService(), container.ZapLog)
+	balanceProjectionHandlers := handlers.NewBalanceProjectionHandlers(container.GetProjectionService(), container.ZapLog)
+	ledgerAdjustmentHandlers := handlers.NewLedgerAdjustmentHandlers(container.GetLedgerAdjustmentService(), container.AuditService, container.ZapLog)
+	promotionHandlers := handlers.NewPromotionHandlers(container.GetPromotionService(), container.ZapLog)
+	rewardHandlers := handlers.NewRewardHandlers(container.GetRewardService(), container.AuditService, container.ZapLog)
+	subscriptionHandlers := handlers.NewSubscriptionHandlers(container.GetSubscriptionService(), container.ZapLog)
+	aiArtifactHandlers := handlers.NewAIArtifactHandlers(container.GetAIArtifactService(), container.ZapLog)
+	outboundWebhookHandlers := handlers.NewOutboundWebhookHandlers(container.GetOutboundWebhookService(), container.ZapLog)
+	walletBackfillHandlers := handlers.NewWalletBackfillHandlers(container.GetWalletBackfillService(), container.ZapLog)
+	circleSubscriptionHandlers := handlers.NewCircleSubscriptionHandlers(container.GetCircleSubscriptionService(), container.ZapLog)
+	opsDigestHandlers := handlers.NewOpsDigestHandlers(container.GetOpsDigestService(), container.AuditService, container.ZapLog)
+	chainHandlers := handlers.NewChainHandlers(entities.Chains())
+	httpCaptureHandlers := handlers.NewHTTPCaptureHandlers(container.GetHTTPCaptureService(), container.AuditService, container.ZapLog)
+	shadowHandlers := handlers.NewShadowHandlers(container.GetShadowService())
+	faultInjectionHandlers := handlers.NewFaultInjectionHandlers(container.GetFaultInjectionService(), container.AuditService, container.ZapLog)
+	messagingHandlers := handlers.NewMessagingHandlers(container.CapturedMessageRepo, container.EmailService, container.SMSService, container.AuditService, container.ZapLog)
+	bulkOperationHandlers := handlers.NewBulkOperationHandlers(container.GetBulkOpsService(), container.AuditService, container.ZapLog)
+	accountingHandlers := handlers.NewAccountingHandlers(container.GetAccountingService(), container.AuditService, container.ZapLog)
+	warehouseHandlers := handlers.NewWarehouseHandlers(container.GetWarehouseService(), container.AuditService, container.ZapLog)
+	blotterHandlers := handlers.NewBlotterHandlers(container.GetBlotterService(), container.AuditService, container.ZapLog)
+	uploadHandlers := handlers.NewUploadHandlers(container.GetUploadService(), container.AuditService, container.ZapLog)
+	documentHandlers := handlers.NewDocumentHandlers(container.GetDocumentService(), container.AuditService, container.ZapLog)
+	notificationHandlers := handlers.NewNotificationWorkerHandlers(container.NotificationService, container.WalletProvisioningScheduler, container.ZapLog)
+	apiUsageHandlers := handlers.NewAPIUsageHandlers(container.GetAPIUsageService(), container.ZapLog)
+	recipientHandlers := handlers.NewRecipientHandlers(container.GetRecipientService(), container.AuditService, container.ZapLog)
+	linkedWalletHandlers := handlers.NewLinkedWalletHandlers(container.GetLinkedWalletService(), container.AuditService, container.ZapLog)
+	experimentHandlers := handlers.NewExperimentHandlers(container.GetExperimentService(), container.AuditService, container.ZapLog)
+	delegateHandlers := handlers.NewDelegateHandlers(container.GetDelegateService(), container.AuditService, container.ZapLog)
+	integrityHandlers := handlers.NewIntegrityHandlers(container.GetIntegrityService(), container.AuditService, container.ZapLog)
+	costBasisHandlers := handlers.NewCostBasisHandlers(container.GetCostBasisService(), container.AuditService, container.ZapLog)
+	developerHandlers := handlers.NewDeveloperHandlers(container.GetDeveloperService(), container.AuditService, container.ZapLog)
+	webhookArchiveHandlers := handlers.NewWebhookArchiveHandlers(container.GetWebhookArchiveService(), container.AuditService, container.ZapLog)
+	orderInterventionHandlers := handlers.NewOrderInterventionHandlers(container.GetOrderOpsService(), container.ZapLog)
+	workerHandlers := handlers.NewWorkerHandlers(container.GetWorkerRegistry(), container.AuditService, container.ZapLog)
+	kycDocumentHandlers := handlers.NewKYCDocumentHandlers(container.GetOnboardingService(), container.ZapLog)
+	onboardingJobHandlers := handlers.NewOnboardingJobHandlers(container.GetOnboardingJobService(), container.AuditService, container.ZapLog)
+	fundingReplayHandlers := handlers.NewFundingReplayHandlers(container.GetFundingService(), container.AuditService, container.ZapLog)
+	attributionHandlers := handlers.NewAttributionHandlers(container.GetAttributionService(), container.ZapLog)
+	portfolioShareHandlers := handlers.NewPortfolioShareHandlers(container.GetPortfolioShareService(), container.AuditService, container.ZapLog)
+	newsHandlers := handlers.NewNewsHandlers(container.GetNewsService(), container.ZapLog)
+	eventStreamHandlers := handlers.NewEventStreamHandlers(container.GetEventStreamService(),
+		time.Duration(container.Config.EventStream.HeartbeatSeconds)*time.Second, container.ZapLog)
+
 	// Create session validator adapter
 	sessionValidator := NewSessionValidatorAdapter(container.GetSessionService())
 
@@ -125,6 +219,7 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 			auth.POST("/forgot-password", authHandlers.ForgotPassword)
 			auth.POST("/reset-password", authHandlers.ResetPassword)
 			auth.POST("/verify-email", authHandlers.VerifyEmail)
+			auth.POST("/reactivate", reactivationHandlers.RequestReactivation)
 		}
 
 		// Onboarding routes - OpenAPI spec compliant
@@ -142,10 +237,46 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 			}
 		}
 
+		// Chain registry (public)
+		v1.GET("/chains", chainHandlers.ListChains)
+		v1.GET("/chains/:chain/validate-address", chainHandlers.ValidateChainAddress)
+
+		// Signed AI artifact downloads (the link's token authorizes the request)
+		v1.GET("/aicfo/artifacts/download", aiArtifactHandlers.DownloadArtifact)
+
+		// Shared portfolio snapshots (the link's token authorizes the request)
+		v1.GET("/shared/portfolio/:token", portfolioShareHandlers.ViewSharedPortfolio)
+
+		// Read-only surface for delegates. They sign in with a grant token and
+		// their session tokens are accepted nowhere else.
+		delegate := v1.Group("/delegate")
+		{
+			delegate.POST("/login", delegateHandlers.DelegateLogin)
+
+			delegateViews := delegate.Group("/")
+			delegateViews.Use(middleware.DelegateAuthentication(container.GetDelegateService(), container.ZapLog))
+			{
+				delegateViews.GET("/portfolio", delegateHandlers.GetDelegatePortfolio)
+				delegateViews.GET("/statements", delegateHandlers.ListDelegateStatements)
+				delegateViews.GET("/statements/:id", delegateHandlers.GetDelegateStatement)
+			}
+		}
+
+		// Partner integrations test their sandbox keys here. Live keys are
+		// rejected, as sandbox keys are on the live external API.
+		sandbox := v1.Group("/developer/sandbox")
+		sandbox.Use(middleware.ValidateAPIKey(NewAPIKeyValidatorAdapter(container.GetAPIKeyService())))
+		sandbox.Use(middleware.RequireAPIKeyEnvironment(apikey.EnvironmentSandbox))
+		{
+			sandbox.GET("/whoami", developerHandlers.GetSandboxIdentity)
+		}
+
 		// KYC provider webhooks (no auth required for external callbacks)
 		kyc := v1.Group("/kyc")
 		{
-			kyc.POST("/callback/:provider_ref", authHandlers.ProcessKYCCallback)
+			kyc.POST("/callback/:provider_ref",
+				middleware.ArchiveWebhook(container.GetWebhookArchiveService(), entities.WebhookProviderKYC, container.ZapLog),
+				authHandlers.ProcessKYCCallback)
 		}
 
 		// Protected routes (auth required)
@@ -162,12 +293,85 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 				users.DELETE("/me", authHandlers.DeleteAccount)
 				users.POST("/me/enable-2fa", authHandlers.Enable2FA)
 				users.POST("/me/disable-2fa", authHandlers.Disable2FA)
+				users.GET("/me/trusted-contact", trustedContactHandlers.GetTrustedContact)
+				users.PUT("/me/trusted-contact", trustedContactHandlers.SetTrustedContact)
+				users.DELETE("/me/trusted-contact", trustedContactHandlers.DeleteTrustedContact)
+			}
+
+			// Read-only access granted to advisors and family members
+			delegateGrants := protected.Group("/delegates")
+			{
+				delegateGrants.GET("", delegateHandlers.ListDelegateGrants)
+				delegateGrants.POST("", delegateHandlers.CreateDelegateGrant)
+				delegateGrants.DELETE("/:id", delegateHandlers.RevokeDelegateGrant)
+				delegateGrants.GET("/:id/access-log", delegateHandlers.GetDelegateAccessLog)
+			}
+
+			// Developer portal: sandbox tenant, self-serve keys with their
+			// usage, and test webhook endpoints
+			developerPortal := protected.Group("/developer")
+			{
+				developerPortal.POST("/tenant", developerHandlers.CreateDeveloperTenant)
+				developerPortal.GET("/tenant", developerHandlers.GetDeveloperTenant)
+				developerPortal.GET("/scopes", developerHandlers.ListDeveloperScopes)
+				developerPortal.GET("/keys", developerHandlers.ListDeveloperKeys)
+				developerPortal.POST("/keys", developerHandlers.IssueDeveloperKey)
+				developerPortal.DELETE("/keys/:id", developerHandlers.RevokeDeveloperKey)
+				developerPortal.GET("/keys/:id/usage", developerHandlers.GetDeveloperKeyUsage)
+				developerPortal.GET("/webhooks", developerHandlers.ListDeveloperWebhooks)
+				developerPortal.POST("/webhooks", developerHandlers.RegisterDeveloperWebhook)
+				developerPortal.DELETE("/webhooks/:id", developerHandlers.DeleteDeveloperWebhook)
+				developerPortal.POST("/webhooks/:id/test", developerHandlers.TestDeveloperWebhook)
+			}
+
+			// Agreement versions the user has accepted
+			protected.GET("/consents", consentHandlers.GetConsents)
+			protected.POST("/consents/:id/accept", consentHandlers.AcceptAgreement)
+
+			// Notification channels, quiet hours and daily digest
+			protected.GET("/notifications/preferences", notificationHandlers.GetPreferences)
+			protected.PUT("/notifications/preferences", notificationHandlers.UpdatePreferences)
+
+			// Live order and deposit progress over server-sent events
+			protected.GET("/events/stream", eventStreamHandlers.Stream)
+
+			// Document uploads through pre-signed URLs (auth required but no KYC gate)
+			uploadRoutes := protected.Group("/uploads")
+			{
+				uploadRoutes.POST("", uploadHandlers.CreateUpload)
+				uploadRoutes.GET("/:id", uploadHandlers.GetUpload)
+				uploadRoutes.POST("/:id/complete", uploadHandlers.CompleteUpload)
+			}
+
+			// Document center: confirmations, disclosures and agreements
+			documentRoutes := protected.Group("/documents")
+			{
+				documentRoutes.GET("", documentHandlers.ListDocuments)
+				documentRoutes.GET("/pending", documentHandlers.ListPendingDocuments)
+				documentRoutes.GET("/:id", documentHandlers.GetDocument)
+				documentRoutes.POST("/:id/read", documentHandlers.MarkDocumentRead)
 			}
 
 			// KYC status utilities (auth required but no KYC gate)
 			kycProtected := protected.Group("/kyc")
 			{
 				kycProtected.GET("/status", authHandlers.GetKYCStatus)
+				kycProtected.GET("/documents", kycDocumentHandlers.ListKYCDocuments)
+				kycProtected.PUT("/documents/:type", kycDocumentHandlers.ReplaceKYCDocument)
+				kycProtected.DELETE("/documents/:type", kycDocumentHandlers.DeleteKYCDocument)
+				kycProtected.GET("/edd", eddHandlers.GetEDDStatus)
+				kycProtected.POST("/edd", eddHandlers.SubmitEDD)
+			}
+
+			// Business account onboarding (auth required but no KYC gate)
+			kybProtected := protected.Group("/kyb")
+			{
+				kybProtected.GET("", kybHandlers.GetKYBStatus)
+				kybProtected.PUT("/company", kybHandlers.SaveCompany)
+				kybProtected.POST("/documents", kybHandlers.AddDocuments)
+				kybProtected.POST("/owners", kybHandlers.AddOwner)
+				kybProtected.DELETE("/owners/:ownerId", kybHandlers.RemoveOwner)
+				kybProtected.POST("/submit", kybHandlers.SubmitKYB)
 			}
 
 			// Security routes for passcode management
@@ -183,13 +387,69 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 			// Funding routes (OpenAPI spec compliant)
 			funding := protected.Group("/funding")
 			{
-				funding.POST("/deposit/address", walletFundingHandlers.CreateDepositAddress)
+				funding.POST("/deposit/address",
+					middleware.RequireFeature(jurisdictionGate, entities.FeatureCryptoDeposits, container.ZapLog),
+					walletFundingHandlers.CreateDepositAddress)
 				funding.GET("/confirmations", walletFundingHandlers.GetFundingConfirmations)
-				funding.POST("/virtual-account", walletFundingHandlers.CreateVirtualAccount)
+				funding.GET("/instructions", depositReferenceHandlers.GetFundingInstructions)
+				funding.POST("/virtual-account",
+					middleware.RequireFeature(jurisdictionGate, entities.FeatureVirtualAccounts, container.ZapLog),
+					walletFundingHandlers.CreateVirtualAccount)
+			}
+
+			// Custodial accounts for minors, managed by a guardian until majority
+			custodialAccounts := protected.Group("/custodial-accounts")
+			{
+				custodialAccounts.POST("", custodialHandlers.CreateCustodialAccount)
+				custodialAccounts.GET("", custodialHandlers.ListCustodialAccounts)
+				custodialAccounts.GET("/claims", custodialHandlers.ListPendingClaims)
+				custodialAccounts.GET("/:id", custodialHandlers.GetCustodialAccount)
+				custodialAccounts.POST("/:id/transfer", custodialHandlers.InitiateTransfer)
+				custodialAccounts.POST("/:id/claim", custodialHandlers.ClaimCustodialAccount)
+			}
+
+			// Withdrawal address book
+			recipientRoutes := protected.Group("/recipients")
+			{
+				recipientRoutes.GET("", recipientHandlers.ListRecipients)
+				recipientRoutes.POST("", recipientHandlers.CreateRecipient)
+				recipientRoutes.GET("/:id", recipientHandlers.GetRecipient)
+				recipientRoutes.PATCH("/:id", recipientHandlers.UpdateRecipient)
+				recipientRoutes.DELETE("/:id", recipientHandlers.DeleteRecipient)
+				recipientRoutes.POST("/:id/verify-account", recipientHandlers.VerifyRecipientAccount)
+				recipientRoutes.POST("/:id/address-check", recipientHandlers.StartAddressCheck)
+				recipientRoutes.POST("/:id/address-check/confirm", recipientHandlers.ConfirmAddressCheck)
 			}
 
 			// Balance routes (part of funding but separate for clarity)
 			protected.GET("/balances", walletFundingHandlers.GetBalances)
+			protected.POST("/balances/refresh", walletFundingHandlers.RefreshBalances)
+			protected.GET("/balances/holds", balanceHoldHandlers.ListActiveHolds)
+			protected.GET("/balances/adjustments", ledgerAdjustmentHandlers.ListMyAdjustments)
+			protected.GET("/balance/projection", balanceProjectionHandlers.GetBalanceProjection)
+			protected.GET("/promotions", promotionHandlers.GetMyPromotions)
+
+			// Rewards earned on investing, redeemed into baskets
+			protected.GET("/rewards", rewardHandlers.GetMyRewards)
+
+			// Premium subscription, billing and invoices
+			subscriptions := protected.Group("/subscriptions")
+			{
+				subscriptions.GET("", subscriptionHandlers.GetSubscription)
+				subscriptions.POST("", subscriptionHandlers.Subscribe)
+				subscriptions.POST("/cancel", subscriptionHandlers.CancelSubscription)
+				subscriptions.GET("/plans", subscriptionHandlers.ListPlans)
+				subscriptions.GET("/invoices", subscriptionHandlers.ListInvoices)
+				subscriptions.GET("/invoices/:id", subscriptionHandlers.GetInvoice)
+				subscriptions.POST("/invoices/:id/pay", subscriptionHandlers.PayInvoice)
+			}
+
+			// Stored AI summaries and analyses, and their personal data export
+			aicfo := protected.Group("/aicfo")
+			{
+				aicfo.GET("/artifacts", aiArtifactHandlers.ListArtifacts)
+				aicfo.GET("/artifacts/export", aiArtifactHandlers.ExportArtifacts)
+			}
 
 			// Investment routes
 			basketExecutor := container.InitializeBasketExecutor()
@@ -212,12 +472,107 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 				wallets.POST("/initiate", walletFundingHandlers.InitiateWalletCreation)
 				wallets.POST("/provision", walletFundingHandlers.ProvisionWallets)
 				wallets.GET("/:chain/address", walletFundingHandlers.GetWalletByChain)
+
+				// Wallets linked to the user's Due account
+				wallets.GET("/linked", linkedWalletHandlers.ListLinkedWallets)
+				wallets.POST("/linked", linkedWalletHandlers.LinkWallet)
+				wallets.POST("/linked/challenge", linkedWalletHandlers.CreateWalletChallenge)
+				wallets.DELETE("/linked/:id", linkedWalletHandlers.UnlinkWallet)
 			}
 
 			// Portfolio endpoints (STACK MVP spec compliant)
 			portfolio := protected.Group("/portfolio")
 			{
 				portfolio.GET("/overview", walletFundingHandlers.GetPortfolio)
+				portfolio.GET("/attribution", attributionHandlers.GetAttribution)
+				portfolio.GET("/news", newsHandlers.GetPortfolioNews)
+
+				// Public links to percentage-only performance snapshots
+				portfolio.POST("/shares", portfolioShareHandlers.CreateShare)
+				portfolio.GET("/shares", portfolioShareHandlers.ListShares)
+				portfolio.DELETE("/shares/:id", portfolioShareHandlers.RevokeShare)
+				portfolio.POST("/shares/revoke-all", portfolioShareHandlers.RevokeAllShares)
+				portfolio.GET("/shares/settings", portfolioShareHandlers.GetSharingSettings)
+				portfolio.PUT("/shares/settings", portfolioShareHandlers.UpdateSharingSettings)
+
+				// Cost basis of positions transferred in, reviewed before it is recorded
+				portfolio.POST("/cost-basis/imports", costBasisHandlers.ImportCostBasis)
+				portfolio.GET("/cost-basis/imports/:id", costBasisHandlers.GetCostBasisImport)
+				portfolio.POST("/cost-basis/imports/:id/confirm", costBasisHandlers.ConfirmCostBasisImport)
+				portfolio.DELETE("/cost-basis/imports/:id", costBasisHandlers.CancelCostBasisImport)
+				portfolio.GET("/cost-basis/lots", costBasisHandlers.ListTaxLots)
+			}
+
+			// Basket orders, including resting limit orders and their cancellation.
+			// Users in paper mode are served by the simulated broker, which
+			// pre-approval countries allow without live trading.
+			tradingFeatures := []entities.JurisdictionFeature{entities.FeatureTrading}
+			paperTrading := container.GetPaperTradingService()
+			if paperTrading != nil {
+				walletFundingHandlers.SetPaperTrading(paperTrading, container.PaperInvestingService)
+				tradingFeatures = append(tradingFeatures, entities.FeaturePaperTrading)
+			}
+			investingRoutes := protected.Group("/investing")
+			investingRoutes.Use(middleware.RequireAnyFeature(jurisdictionGate, tradingFeatures, container.ZapLog))
+			investingRoutes.Use(middleware.RequireConsents(consentGate, container.ZapLog))
+			{
+				investingRoutes.GET("/baskets", walletFundingHandlers.GetBaskets)
+				investingRoutes.GET("/baskets/:basketId", walletFundingHandlers.GetBasket)
+				investingRoutes.POST("/orders", walletFundingHandlers.CreateOrder)
+				investingRoutes.GET("/orders", walletFundingHandlers.GetOrders)
+				investingRoutes.GET("/orders/:id", walletFundingHandlers.GetOrder)
+				investingRoutes.POST("/orders/preview", walletFundingHandlers.PreviewOrder)
+				investingRoutes.POST("/orders/:id/cancel", walletFundingHandlers.CancelOrder)
+
+				// Basket buys queued and placed together in one checkout
+				investingRoutes.GET("/cart", walletFundingHandlers.GetCart)
+				investingRoutes.PUT("/cart/items/:basketId", walletFundingHandlers.PutCartItem)
+				investingRoutes.DELETE("/cart/items/:basketId", walletFundingHandlers.DeleteCartItem)
+				investingRoutes.POST("/cart/preview", walletFundingHandlers.PreviewCart)
+				investingRoutes.POST("/cart/checkout", walletFundingHandlers.CheckoutCart)
+
+				if paperTrading != nil {
+					tradingModeHandlers := handlers.NewTradingModeHandlers(paperTrading, container.ZapLog)
+					investingRoutes.GET("/mode", tradingModeHandlers.GetTradingMode)
+					investingRoutes.POST("/mode/paper", tradingModeHandlers.EnablePaperTrading)
+					investingRoutes.POST("/mode/live", tradingModeHandlers.SwitchToLive)
+				}
+			}
+
+			// Redeeming rewards places a basket buy, so it is gated like the
+			// investing routes
+			protected.POST("/rewards/redemptions",
+				middleware.RequireAnyFeature(jurisdictionGate, tradingFeatures, container.ZapLog),
+				middleware.RequireConsents(consentGate, container.ZapLog),
+				rewardHandlers.RedeemRewards)
+
+			// Live quotes during market hours, replacing client-side polling
+			if marketData := container.GetMarketDataService(); marketData != nil {
+				marketDataHandlers := handlers.NewMarketDataHandlers(marketData,
+					time.Duration(container.Config.MarketData.HeartbeatSeconds)*time.Second, container.ZapLog)
+				protected.GET("/market/quotes/stream", marketDataHandlers.StreamQuotes)
+			}
+
+			// Investment goals with contribution plans and progress
+			goalHandlers := handlers.NewGoalHandlers(container.GetGoalService(), container.ZapLog)
+			goalRoutes := protected.Group("/goals")
+			{
+				goalRoutes.POST("", goalHandlers.CreateGoal)
+				goalRoutes.GET("", goalHandlers.ListGoals)
+				goalRoutes.GET("/:id", goalHandlers.GetGoal)
+				goalRoutes.PUT("/:id", goalHandlers.UpdateGoal)
+				goalRoutes.DELETE("/:id", goalHandlers.CancelGoal)
+			}
+
+			// Auto-sweep of idle buying power into a basket
+			sweepHandlers := handlers.NewSweepHandlers(container.GetSweepService(), container.ZapLog)
+			sweepRoutes := protected.Group("/sweep")
+			sweepRoutes.Use(middleware.RequireAnyFeature(jurisdictionGate, tradingFeatures, container.ZapLog))
+			sweepRoutes.Use(middleware.RequireConsents(consentGate, container.ZapLog))
+			{
+				sweepRoutes.GET("", sweepHandlers.GetSweep)
+				sweepRoutes.PUT("", sweepHandlers.SaveSweep)
+				sweepRoutes.POST("/disable", sweepHandlers.DisableSweep)
 			}
 
 			// Alpaca Assets - Tradable stocks and ETFs
@@ -229,6 +584,8 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 
 			// Allocation routes - 70/30 Smart Allocation Mode
 			allocation := protected.Group("/user/:id/allocation")
+			allocation.Use(middleware.RequireFeature(jurisdictionGate, entities.FeatureTrading, container.ZapLog))
+			allocation.Use(middleware.RequireConsents(consentGate, container.ZapLog))
 			{
 				allocation.POST("/enable", allocationHandlers.EnableAllocationMode)
 				allocation.POST("/pause", allocationHandlers.PauseAllocationMode)
@@ -251,6 +608,252 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 			admin.POST("/wallet/create", walletFundingHandlers.CreateWalletsForUser)
 			admin.POST("/wallet/retry-provisioning", walletFundingHandlers.RetryWalletProvisioning)
 			admin.GET("/wallet/health", walletFundingHandlers.HealthCheck)
+			admin.POST("/wallet/backfills", walletBackfillHandlers.StartBackfill)
+			admin.GET("/wallet/backfills", walletBackfillHandlers.ListBackfills)
+			admin.GET("/wallet/backfills/:id", walletBackfillHandlers.GetBackfill)
+			admin.POST("/wallet/backfills/:id/cancel", walletBackfillHandlers.CancelBackfill)
+
+			// Circle notification subscription management
+			admin.GET("/circle/subscriptions", circleSubscriptionHandlers.ListSubscriptions)
+			admin.GET("/circle/subscriptions/status", circleSubscriptionHandlers.GetStatus)
+			admin.POST("/circle/subscriptions/sync", circleSubscriptionHandlers.SyncSubscription)
+			admin.DELETE("/circle/subscriptions/:id", circleSubscriptionHandlers.DeleteSubscription)
+
+			// Audit chain integrity (SOC 2 evidence)
+			admin.GET("/audit/verify", auditHandlers.VerifyAuditChain)
+			admin.POST("/audit/anchor", auditHandlers.AnchorAuditChain)
+			admin.GET("/audit/anchors", auditHandlers.ListAuditAnchors)
+
+			// Data retention
+			admin.GET("/retention/policies", retentionHandlers.ListPolicies)
+			admin.POST("/retention/run", retentionHandlers.RunRetention)
+			admin.GET("/retention/runs", retentionHandlers.ListRuns)
+
+			// Raw KYC data behind PII vault tokens
+			admin.GET("/pii-vault/records/:token", piiVaultHandlers.RevealPIIRecord)
+			admin.GET("/pii-vault/records/:token/access", piiVaultHandlers.ListPIIRecordAccess)
+
+			// Deposits held in suspense because they could not be attributed to a user
+			admin.GET("/suspense", suspenseHandlers.ListSuspenseItems)
+			admin.GET("/suspense/aging", suspenseHandlers.GetSuspenseAging)
+			admin.GET("/suspense/:id", suspenseHandlers.GetSuspenseItem)
+			admin.POST("/suspense/:id/assign", suspenseHandlers.AssignSuspenseItem)
+			admin.POST("/suspense/:id/refund", suspenseHandlers.RefundSuspenseItem)
+
+			// Alerts routed to PagerDuty, Slack and the fallback webhook
+			admin.GET("/alerts/deliveries", alertingHandlers.ListAlertDeliveries)
+			admin.POST("/alerts/test", alertingHandlers.SendTestAlert)
+
+			// Hot endpoint cache warm-up after deploys
+			admin.POST("/cache/warm", cacheWarmHandlers.WarmCache)
+			admin.GET("/cache/warm", cacheWarmHandlers.GetCacheWarm)
+
+			// Temporary per-module log levels
+			admin.GET("/log-levels", logLevelHandlers.ListLogLevels)
+			admin.PUT("/log-levels/:module", logLevelHandlers.SetLogLevel)
+			admin.DELETE("/log-levels/:module", logLevelHandlers.ResetLogLevel)
+
+			// Data residency tagging and out-of-region reporting
+			admin.GET("/residency/users/:id", residencyHandlers.GetUserResidency)
+			admin.PUT("/residency/users/:id", residencyHandlers.SetUserResidency)
+			admin.GET("/residency/violations", residencyHandlers.ListOutOfRegion)
+
+			// Backup restore verification
+			admin.POST("/backups/restore-drills", restoreDrillHandlers.StartRestoreDrill)
+			admin.GET("/backups/restore-drills", restoreDrillHandlers.ListRestoreDrills)
+			admin.GET("/backups/restore-drills/:id", restoreDrillHandlers.GetRestoreDrill)
+
+			// Jurisdiction rules
+			admin.GET("/jurisdictions", jurisdictionHandlers.ListCountryRules)
+			admin.GET("/jurisdictions/:country", jurisdictionHandlers.GetCountryRule)
+			admin.PUT("/jurisdictions/:country", jurisdictionHandlers.UpsertCountryRule)
+			admin.DELETE("/jurisdictions/:country", jurisdictionHandlers.DeleteCountryRule)
+
+			// Signup and login geolocation
+			if geoService != nil {
+				geoAccessHandlers := handlers.NewGeoAccessHandlers(geoService, container.ZapLog)
+				admin.GET("/geo/overrides", geoAccessHandlers.ListGeoOverrides)
+				admin.POST("/geo/overrides", geoAccessHandlers.CreateGeoOverride)
+				admin.DELETE("/geo/overrides/:id", geoAccessHandlers.DeleteGeoOverride)
+				admin.GET("/geo/users/:id/events", geoAccessHandlers.ListUserGeoEvents)
+			}
+
+			// Admin case queue and dormant account monitor
+			admin.GET("/cases", adminCaseHandlers.ListCases)
+			admin.GET("/cases/:id", adminCaseHandlers.GetCase)
+			admin.PATCH("/cases/:id", adminCaseHandlers.UpdateCase)
+			admin.POST("/inactivity/scan", adminCaseHandlers.RunInactivityScan)
+
+			// Closed account reactivation
+			admin.GET("/reactivations", reactivationHandlers.ListReactivations)
+			admin.GET("/reactivations/:id", reactivationHandlers.GetReactivation)
+			admin.POST("/reactivations/:id/approve", reactivationHandlers.ApproveReactivation)
+			admin.POST("/reactivations/:id/reject", reactivationHandlers.RejectReactivation)
+
+			// Enhanced due diligence for higher limits
+			admin.GET("/edd", eddHandlers.ListEDDSubmissions)
+			admin.GET("/edd/:id", eddHandlers.GetEDDSubmission)
+			admin.POST("/edd/:id/approve", eddHandlers.ApproveEDD)
+			admin.POST("/edd/:id/reject", eddHandlers.RejectEDD)
+			admin.GET("/kyb", kybHandlers.ListKYBApplications)
+			admin.GET("/kyb/:id", kybHandlers.GetKYBApplication)
+			admin.POST("/kyb/:id/approve", kybHandlers.ApproveKYB)
+			admin.POST("/kyb/:id/reject", kybHandlers.RejectKYB)
+
+			// Maker-checker approvals for large withdrawals and sensitive admin actions
+			admin.GET("/approvals", approvalHandlers.ListApprovals)
+			admin.GET("/approvals/:id", approvalHandlers.GetApproval)
+			admin.POST("/approvals/:id/approve", approvalHandlers.ApproveRequest)
+			admin.POST("/approvals/:id/reject", approvalHandlers.RejectRequest)
+			admin.DELETE("/baskets/:id", approvalHandlers.RequestBasketDeletion)
+
+			// Regional basket variants and their shared analytics
+			admin.POST("/baskets/:id/clone", basketAdminHandlers.CloneBasket)
+			admin.PUT("/baskets/:id/regions", basketAdminHandlers.SetBasketRegions)
+			admin.GET("/baskets/:id/analytics", basketAdminHandlers.GetBasketAnalytics)
+
+			// Manual ledger adjustments, posted once approved through the approvals queue
+			admin.POST("/ledger/adjustments", ledgerAdjustmentHandlers.RequestLedgerAdjustment)
+			admin.GET("/ledger/adjustments", ledgerAdjustmentHandlers.ListLedgerAdjustments)
+			admin.GET("/ledger/adjustments/:id", ledgerAdjustmentHandlers.GetLedgerAdjustment)
+
+			// Historical exchange rates for statements and tax reporting
+			admin.GET("/rates", rateHandlers.GetRate)
+			admin.POST("/rates/backfill", rateHandlers.BackfillRates)
+
+			// Versioned terms, privacy policy and brokerage agreements
+			admin.GET("/agreements", consentHandlers.ListAgreements)
+			admin.POST("/agreements", consentHandlers.PublishAgreement)
+
+			// Promotional credit campaigns
+			admin.GET("/promotions", promotionHandlers.ListPromotions)
+			admin.POST("/promotions", promotionHandlers.CreatePromotion)
+			admin.PATCH("/promotions/:id", promotionHandlers.UpdatePromotion)
+			admin.POST("/promotions/:id/grants", promotionHandlers.GrantPromotion)
+
+			// Rewards earning rules and the risk review queue
+			admin.GET("/rewards/rules", rewardHandlers.ListRules)
+			admin.POST("/rewards/rules", rewardHandlers.CreateRule)
+			admin.PATCH("/rewards/rules/:id", rewardHandlers.UpdateRule)
+			admin.GET("/rewards/held", rewardHandlers.ListHeldRewards)
+			admin.POST("/rewards/:id/review", rewardHandlers.ReviewReward)
+
+			// Partner webhook endpoints and delivery log
+			admin.POST("/webhooks/endpoints", outboundWebhookHandlers.CreateEndpoint)
+			admin.GET("/webhooks/endpoints", outboundWebhookHandlers.ListEndpoints)
+			admin.GET("/webhooks/endpoints/:id", outboundWebhookHandlers.GetEndpoint)
+			admin.PATCH("/webhooks/endpoints/:id", outboundWebhookHandlers.UpdateEndpoint)
+			admin.DELETE("/webhooks/endpoints/:id", outboundWebhookHandlers.DeleteEndpoint)
+			admin.POST("/webhooks/endpoints/:id/rotate-secret", outboundWebhookHandlers.RotateSecret)
+			admin.POST("/webhooks/endpoints/:id/test", outboundWebhookHandlers.SendTestEvent)
+			admin.GET("/webhooks/deliveries", outboundWebhookHandlers.ListDeliveries)
+			admin.GET("/webhooks/deliveries/:id", outboundWebhookHandlers.GetDelivery)
+			admin.POST("/webhooks/deliveries/:id/retry", outboundWebhookHandlers.RetryDelivery)
+
+			// Background worker status and incident controls
+			admin.GET("/system/workers", workerHandlers.ListWorkers)
+			admin.POST("/system/workers/:name/pause", workerHandlers.PauseWorker)
+			admin.POST("/system/workers/:name/resume", workerHandlers.ResumeWorker)
+
+			// Failed and abandoned onboarding jobs
+			admin.GET("/onboarding/jobs/stuck", onboardingJobHandlers.ListStuckOnboardingJobs)
+			admin.POST("/onboarding/jobs/:id/requeue", onboardingJobHandlers.RequeueOnboardingJob)
+
+			// Onboarding A/B experiments
+			admin.GET("/experiments", experimentHandlers.ListExperiments)
+			admin.POST("/experiments", experimentHandlers.CreateExperiment)
+			admin.GET("/experiments/:id", experimentHandlers.GetExperiment)
+			admin.POST("/experiments/:id/conclude", experimentHandlers.ConcludeExperiment)
+
+			// Re-drive a user's deposits after a funding fix
+			admin.POST("/users/:id/funding/replay", fundingReplayHandlers.ReplayUserFunding)
+
+			// Daily ops digest
+			admin.GET("/reports/ops-digest", opsDigestHandlers.GetOpsDigest)
+			admin.POST("/reports/ops-digest/send", opsDigestHandlers.SendOpsDigest)
+
+			// Cross-entity data integrity checks
+			admin.GET("/integrity/runs", integrityHandlers.ListIntegrityRuns)
+			admin.POST("/integrity/runs", integrityHandlers.RunIntegrityCheck)
+			admin.GET("/integrity/runs/:id", integrityHandlers.GetIntegrityRun)
+
+			// Tax lot corrections once a transfer's final figures arrive
+			admin.GET("/users/:id/tax-lots", costBasisHandlers.AdminListTaxLots)
+			admin.PATCH("/tax-lots/:id", costBasisHandlers.AdjustTaxLot)
+			admin.GET("/tax-lots/:id/adjustments", costBasisHandlers.ListTaxLotAdjustments)
+
+			// Raw provider webhooks kept for disputes
+			admin.GET("/webhook-archive", webhookArchiveHandlers.SearchWebhookArchive)
+			admin.GET("/webhook-archive/:id", webhookArchiveHandlers.GetArchivedWebhook)
+
+			// Debug request capture
+			admin.GET("/debug/captures", httpCaptureHandlers.ListCaptures)
+			admin.GET("/debug/captures/:id", httpCaptureHandlers.GetCapture)
+			admin.GET("/debug/capture-targets", httpCaptureHandlers.ListCaptureTargets)
+			admin.POST("/debug/capture-targets", httpCaptureHandlers.CreateCaptureTarget)
+			admin.DELETE("/debug/capture-targets/:id", httpCaptureHandlers.DeleteCaptureTarget)
+
+			// Traffic shadowing of rewritten services
+			admin.GET("/shadow/routes", shadowHandlers.ListShadowRoutes)
+
+			// Fault injection into provider calls, outside production only
+			admin.GET("/faults", faultInjectionHandlers.ListFaultRules)
+			admin.POST("/faults", faultInjectionHandlers.CreateFaultRule)
+			admin.DELETE("/faults/:id", faultInjectionHandlers.DeleteFaultRule)
+
+			// Email and SMS provider failover and sandbox-captured messages
+			admin.GET("/messaging/captures", messagingHandlers.ListCapturedMessages)
+			admin.GET("/messaging/providers", messagingHandlers.ListProviders)
+			admin.PUT("/messaging/providers/:channel/:provider", messagingHandlers.SetProviderEnabled)
+
+			// Bulk user operations with per-user results
+			admin.POST("/bulk-operations", bulkOperationHandlers.CreateBulkOperation)
+			admin.GET("/bulk-operations", bulkOperationHandlers.ListBulkOperations)
+			admin.GET("/bulk-operations/:id", bulkOperationHandlers.GetBulkOperation)
+			admin.GET("/bulk-operations/:id/items", bulkOperationHandlers.ListBulkOperationItems)
+			admin.GET("/bulk-operations/:id/results.csv", bulkOperationHandlers.DownloadBulkOperationResults)
+			admin.POST("/bulk-operations/:id/cancel", bulkOperationHandlers.CancelBulkOperation)
+
+			// General ledger export to the accounting system
+			admin.GET("/accounting/journal", accountingHandlers.GetJournal)
+			admin.GET("/accounting/exports", accountingHandlers.ListExports)
+			admin.POST("/accounting/exports/reexport", accountingHandlers.Reexport)
+
+			// Business event export to the data warehouse
+			admin.GET("/warehouse", warehouseHandlers.GetStatus)
+			admin.POST("/warehouse/backfill", warehouseHandlers.Backfill)
+
+			// Daily order blotter of record for regulatory reporting
+			admin.GET("/regulatory/blotters", blotterHandlers.ListBlotters)
+			admin.POST("/regulatory/blotters", blotterHandlers.GenerateBlotter)
+			admin.GET("/regulatory/blotters/:id", blotterHandlers.DownloadBlotter)
+
+			// Uploaded KYC, EDD and KYB documents for review
+			admin.GET("/uploads/:id/download", uploadHandlers.DownloadUpload)
+
+			// Disclosures published to the document center, and who has read them
+			admin.GET("/documents", documentHandlers.ListPublishedDocuments)
+			admin.POST("/documents", documentHandlers.PublishDocument)
+			admin.GET("/documents/:id/receipts", documentHandlers.ListDocumentReceipts)
+
+			// API usage analytics per user and endpoint
+			admin.GET("/analytics/api-usage", apiUsageHandlers.GetAPIUsage)
+
+			// Withdrawal destinations shared across accounts
+			admin.GET("/recipients/shared", recipientHandlers.ListSharedDestinations)
+			admin.POST("/recipients/:id/flag", recipientHandlers.FlagRecipient)
+			admin.POST("/recipients/:id/unflag", recipientHandlers.UnflagRecipient)
+
+			// Stuck order intervention (super admin only)
+			orderOps := admin.Group("/orders")
+			orderOps.Use(middleware.SuperAdminAuth())
+			{
+				orderOps.GET("/stuck", orderInterventionHandlers.ListStuckOrders)
+				orderOps.GET("/:id/interventions", orderInterventionHandlers.ListOrderInterventions)
+				orderOps.POST("/:id/cancel", orderInterventionHandlers.CancelStuckOrder)
+				orderOps.POST("/:id/repair", orderInterventionHandlers.RepairStuckOrder)
+				orderOps.POST("/:id/notes", orderInterventionHandlers.AnnotateOrder)
+			}
 		}
 
 		// Due API routes (protected)
@@ -263,12 +866,42 @@ integrationHandlers := handlers.NewIntegrationHandlers(
 		// Webhooks (external systems) - OpenAPI spec compliant
 		webhooks := v1.Group("/webhooks")
 		{
-			webhooks.POST("/chain-deposit", walletFundingHandlers.ChainDepositWebhook)
-			webhooks.POST("/brokerage-fill", walletFundingHandlers.BrokerageFillWebhook)
+			webhooks.POST("/chain-deposit",
+				middleware.ArchiveWebhook(container.GetWebhookArchiveService(), entities.WebhookProviderChain, container.ZapLog),
+				walletFundingHandlers.ChainDepositWebhook)
+			webhooks.POST("/brokerage-fill",
+				middleware.ArchiveWebhook(container.GetWebhookArchiveService(), entities.WebhookProviderBrokerage, container.ZapLog),
+				walletFundingHandlers.BrokerageFillWebhook)
+			webhooks.POST("/bank-credit",
+				middleware.ArchiveWebhook(container.GetWebhookArchiveService(), entities.WebhookProviderBank, container.ZapLog),
+				depositReferenceHandlers.BankCreditWebhook)
 		}
 	}
 
 	// ZeroG and dedicated AI-CFO HTTP routes have been removed.
 
+	// Security routes share the CSRF store, since their sessions can also be
+	// authenticated with the access cookie
+	SetupSecurityRoutes(router, container.Config, container.DB, container.ZapLog, csrfStore)
+
 	return router
 }
+
+// latencyBudgetConfig converts the configured budgets, given in milliseconds
+func latencyBudgetConfig(cfg config.LatencyBudgetConfig) middleware.LatencyBudgetConfig {
+	budget := middleware.LatencyBudgetConfig{
+		DefaultTimeout: time.Duration(cfg.DefaultTimeoutMs) * time.Millisecond,
+		MaxInFlight:    cfg.MaxInFlight,
+		MaxGoroutines:  cfg.MaxGoroutines,
+		RetryAfter:     time.Duration(cfg.RetryAfterSeconds) * time.Second,
+		ShedExempt:     cfg.ShedExempt,
+	}
+	for _, route := range cfg.Routes {
+		budget.Routes = append(budget.Routes, middleware.RouteTimeout{
+			Method:  route.Method,
+			Path:    route.Path,
+			Timeout: time.Duration(route.TimeoutMs) * time.Millisecond,
+		})
+	}
+	return budget
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -30,26 +31,60 @@ func (a *APIKeyValidatorAdapter) ValidateAPIKey(ctx context.Context, key string)
 		return nil, err
 	}
 	return &middleware.APIKeyInfo{
-		ID:     keyInfo.ID,
-		UserID: keyInfo.UserID,
-		Scopes: keyInfo.Scopes,
+		ID:          keyInfo.ID,
+		UserID:      keyInfo.UserID,
+		Scopes:      keyInfo.Scopes,
+		TenantID:    keyInfo.TenantID,
+		Environment: keyInfo.Environment,
 	}, nil
 }
 
+// SigningKeyResolverAdapter resolves request signing keys through the API key
+// service for the signed request middleware
+type SigningKeyResolverAdapter struct {
+	svc *apikey.Service
+}
+
+func NewSigningKeyResolverAdapter(svc *apikey.Service) *SigningKeyResolverAdapter {
+	return &SigningKeyResolverAdapter{svc: svc}
+}
+
+func (a *SigningKeyResolverAdapter) ResolveSigningKey(ctx context.Context, keyID string) (*middleware.SigningKeyInfo, error) {
+	key, secret, err := a.svc.ResolveSigningKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &middleware.SigningKeyInfo{
+		ID:          key.ID,
+		KeyID:       key.KeyID,
+		Tenant:      key.Tenant,
+		Environment: key.Environment,
+		Scopes:      key.Scopes,
+		Secret:      secret,
+	}, nil
+}
+
+func (a *SigningKeyResolverAdapter) UseNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error) {
+	return a.svc.UseNonce(ctx, keyID, nonce, expiresAt)
+}
+
 func SetupSecurityRoutes(
 	router *gin.Engine,
 	cfg *config.Config,
 	db *sql.DB,
 	zapLog *zap.Logger,
+	csrfStore *middleware.CSRFStore,
 ) {
 	// Initialize services
 	sessionService := session.NewService(db, zapLog)
 	twofaService := twofa.NewService(db, zapLog, cfg.Security.EncryptionKey)
 	apikeyService := apikey.NewService(db, zapLog)
+	apikeyService.SetEncryptionKey(cfg.Security.EncryptionKey)
 
 	// Create adapters
 	sessionValidator := NewSessionValidatorAdapter(sessionService)
 	apikeyValidator := NewAPIKeyValidatorAdapter(apikeyService)
+	signingKeyResolver := NewSigningKeyResolverAdapter(apikeyService)
 
 	// Wrap zap logger to logger.Logger
 	log := logger.NewLogger(zapLog)
@@ -75,6 +110,7 @@ func SetupSecurityRoutes(
 		auth := v1.Group("")
 		auth.Use(middleware.Authentication(cfg, log, sessionValidator))
 		auth.Use(userRateLimiter.UserRateLimit(60)) // 60 requests per minute per user
+		auth.Use(middleware.CSRFProtection(csrfStore))
 		{
 			// 2FA Management
 			twofa := auth.Group("/2fa")
@@ -110,15 +146,22 @@ func SetupSecurityRoutes(
 		admin.Use(middleware.Authentication(cfg, log, sessionValidator))
 		admin.Use(middleware.AdminAuth(db, log))
 		admin.Use(userRateLimiter.UserRateLimit(120)) // Higher limit for admins
+		admin.Use(middleware.CSRFProtection(csrfStore))
 		{
 			// Admin API key management
 			admin.GET("/api-keys", securityHandlers.AdminListAPIKeys)
 			admin.DELETE("/api-keys/:id", securityHandlers.AdminRevokeAPIKey)
+
+			// Request signing keys for internal services
+			admin.GET("/signing-keys", securityHandlers.AdminListSigningKeys)
+			admin.POST("/signing-keys", securityHandlers.AdminCreateSigningKey)
+			admin.DELETE("/signing-keys/:id", securityHandlers.AdminRevokeSigningKey)
 		}
 
 		// API key authenticated routes
 		api := v1.Group("/external")
 		api.Use(middleware.ValidateAPIKey(apikeyValidator))
+		api.Use(middleware.RequireAPIKeyEnvironment(apikey.EnvironmentLive))
 		{
 			// Webhook endpoints
 			webhooks := api.Group("/webhooks")
@@ -128,5 +171,13 @@ func SetupSecurityRoutes(
 				})
 			}
 		}
+
+		// Internal service routes, authenticated by HMAC request signature
+		internal := v1.Group("/internal")
+		internal.Use(middleware.RequireSignedRequest(signingKeyResolver, cfg.Environment,
+			time.Duration(cfg.Security.RequestSigningTolerance)*time.Second))
+		{
+			internal.GET("/auth/verify", securityHandlers.VerifySignedRequest)
+		}
 	}
 }
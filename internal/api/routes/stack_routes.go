@@ -86,8 +86,10 @@ func SetupStackRoutes(db *sql.DB, cfg *config.Config, log *logger.Logger, zapLog
 		orders.Use(middleware.CSRFProtection(csrfStore))
 		{
 			orders.POST("", walletFundingHandlers.CreateOrder)
+			orders.POST("/preview", walletFundingHandlers.PreviewOrder)
 			orders.GET("", walletFundingHandlers.GetOrders)
 			orders.GET("/:id", walletFundingHandlers.GetOrder)
+			orders.POST("/:id/cancel", walletFundingHandlers.CancelOrder)
 		}
 
 		portfolio := v1.Group("/portfolio")
@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trusted contact and case errors
+var (
+	ErrTrustedContactNotFound = errors.New("trusted contact not found")
+	ErrAdminCaseNotFound      = errors.New("admin case not found")
+)
+
+// TrustedContact is a person the firm may contact about a customer's account,
+// for example when the account has gone dormant or exploitation is suspected.
+type TrustedContact struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	FirstName    string    `json:"first_name" db:"first_name"`
+	LastName     string    `json:"last_name" db:"last_name"`
+	Relationship string    `json:"relationship" db:"relationship"`
+	Email        *string   `json:"email,omitempty" db:"email"`
+	Phone        *string   `json:"phone,omitempty" db:"phone"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpsertTrustedContactRequest sets the user's trusted contact
+type UpsertTrustedContactRequest struct {
+	FirstName    string  `json:"firstName" binding:"required,max=100"`
+	LastName     string  `json:"lastName" binding:"required,max=100"`
+	Relationship string  `json:"relationship" binding:"required,max=64"`
+	Email        *string `json:"email,omitempty" binding:"omitempty,email"`
+	Phone        *string `json:"phone,omitempty" binding:"omitempty,e164"`
+}
+
+// AdminCaseType categorizes cases in the admin review queue
+type AdminCaseType string
+
+const (
+	AdminCaseDormantAccount       AdminCaseType = "dormant_account"
+	AdminCaseAccountReactivation  AdminCaseType = "account_reactivation"
+	AdminCaseEnhancedDueDiligence AdminCaseType = "enhanced_due_diligence"
+	AdminCaseBusinessVerification AdminCaseType = "business_verification"
+)
+
+// AdminCaseStatus tracks a case through review
+type AdminCaseStatus string
+
+const (
+	AdminCaseOpen     AdminCaseStatus = "open"
+	AdminCaseInReview AdminCaseStatus = "in_review"
+	AdminCaseResolved AdminCaseStatus = "resolved"
+)
+
+// AdminCase is an item in the admin review queue
+type AdminCase struct {
+	ID              uuid.UUID              `json:"id" db:"id"`
+	UserID          uuid.UUID              `json:"user_id" db:"user_id"`
+	CaseType        AdminCaseType          `json:"case_type" db:"case_type"`
+	Status          AdminCaseStatus        `json:"status" db:"status"`
+	Summary         string                 `json:"summary" db:"summary"`
+	Details         map[string]interface{} `json:"details" db:"details"`
+	AssignedTo      *uuid.UUID             `json:"assigned_to,omitempty" db:"assigned_to"`
+	ResolutionNotes *string                `json:"resolution_notes,omitempty" db:"resolution_notes"`
+	ResolvedBy      *uuid.UUID             `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt      *time.Time             `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateAdminCaseRequest changes the status or assignment of a case
+type UpdateAdminCaseRequest struct {
+	Status          AdminCaseStatus `json:"status" binding:"required,oneof=open in_review resolved"`
+	AssignedTo      *uuid.UUID      `json:"assignedTo,omitempty"`
+	ResolutionNotes *string         `json:"resolutionNotes,omitempty"`
+}
+
+// AccountInactivity is the escalation state of a dormant funded account
+type AccountInactivity struct {
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Stage          int        `json:"stage" db:"stage"`
+	LastActivityAt time.Time  `json:"last_activity_at" db:"last_activity_at"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty" db:"last_notified_at"`
+	CaseID         *uuid.UUID `json:"case_id,omitempty" db:"case_id"`
+}
+
+// DormantAccount is a funded account with no recent activity
+type DormantAccount struct {
+	UserID         uuid.UUID          `json:"user_id"`
+	Email          string             `json:"email"`
+	LastActivityAt time.Time          `json:"last_activity_at"`
+	BuyingPower    string             `json:"buying_power"`
+	State          *AccountInactivity `json:"state,omitempty"`
+}
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Accounting export errors
+var (
+	ErrUnmappedLedgerAccount       = errors.New("ledger account type has no general ledger mapping")
+	ErrAccountingExportInProgress  = errors.New("journal export already in progress")
+	ErrInvalidAccountingDateRange  = errors.New("invalid accounting date range")
+	ErrAccountingExportUnavailable = errors.New("accounting export is not configured")
+)
+
+// AccountingExportStatus is the outcome of exporting one day's journal
+type AccountingExportStatus string
+
+const (
+	AccountingExportExporting AccountingExportStatus = "exporting" // claimed by a worker or an admin re-export
+	AccountingExportExported  AccountingExportStatus = "exported"
+	AccountingExportEmpty     AccountingExportStatus = "empty" // no ledger activity that day; nothing was sent
+	AccountingExportFailed    AccountingExportStatus = "failed"
+)
+
+// LedgerActivity is the ledger entries of one account type, transaction type
+// and side summed over a period
+type LedgerActivity struct {
+	AccountType     AccountType     `db:"account_type"`
+	TransactionType TransactionType `db:"transaction_type"`
+	EntryType       EntryType       `db:"entry_type"`
+	Currency        string          `db:"currency"`
+	Amount          decimal.Decimal `db:"amount"`
+}
+
+// JournalLine is one debit or credit to a general ledger account
+type JournalLine struct {
+	GLAccount       string          `json:"gl_account"`
+	AccountType     AccountType     `json:"account_type,omitempty"` // Empty for the rounding line
+	TransactionType TransactionType `json:"transaction_type,omitempty"`
+	PostingType     EntryType       `json:"posting_type"`
+	Currency        string          `json:"currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	Description     string          `json:"description"`
+}
+
+// AccountingJournal is the summary journal entry for one business day
+type AccountingJournal struct {
+	Date         time.Time       `json:"date"`
+	Reference    string          `json:"reference"` // Stable document number, e.g. STACK-20260302
+	Lines        []JournalLine   `json:"lines"`
+	TotalDebits  decimal.Decimal `json:"total_debits"`
+	TotalCredits decimal.Decimal `json:"total_credits"`
+	Checksum     string          `json:"checksum"` // Hash of the lines, to tell whether a re-export changed anything
+}
+
+// AccountingExport tracks the export of one day's journal to a destination
+type AccountingExport struct {
+	ID          uuid.UUID              `json:"id" db:"id"`
+	JournalDate time.Time              `json:"journal_date" db:"journal_date"`
+	Destination string                 `json:"destination" db:"destination"`
+	Status      AccountingExportStatus `json:"status" db:"status"`
+	ExternalRef string                 `json:"external_ref,omitempty" db:"external_ref"` // ID of the journal entry or file at the destination
+	Checksum    string                 `json:"checksum,omitempty" db:"checksum"`
+	LineCount   int                    `json:"line_count" db:"line_count"`
+	TotalDebits decimal.Decimal        `json:"total_debits" db:"total_debits"`
+	Attempts    int                    `json:"attempts" db:"attempts"`
+	LastError   string                 `json:"last_error,omitempty" db:"last_error"`
+	ExportedAt  *time.Time             `json:"exported_at,omitempty" db:"exported_at"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// ReexportAccountingRequest exports a date range again, replacing what the
+// destination holds for those days
+type ReexportAccountingRequest struct {
+	From   string `json:"from" binding:"required"` // YYYY-MM-DD
+	To     string `json:"to" binding:"required"`   // YYYY-MM-DD, inclusive
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -97,6 +97,10 @@ type AdminCreationResponse struct {
 // UpdateUserStatusRequest represents the payload to activate or suspend a user
 type UpdateUserStatusRequest struct {
 	IsActive bool `json:"isActive"`
+	// ClosureReason applies when suspending; defaults to admin. Accounts closed
+	// for_cause need admin approval to be reactivated.
+	ClosureReason AccountClosureReason `json:"closureReason,omitempty" binding:"omitempty,oneof=admin for_cause"`
+	ClosureNotes  *string              `json:"closureNotes,omitempty"`
 }
 
 // AdminTransaction represents a transaction surfaced in admin endpoints
@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertSeverity decides which on-call channels an alert is routed to
+type AlertSeverity string
+
+const (
+	AlertSeverityCritical AlertSeverity = "critical" // Pages on-call at any hour
+	AlertSeverityWarning  AlertSeverity = "warning"  // Needs a look, but can wait for someone at a desk
+	AlertSeverityInfo     AlertSeverity = "info"
+)
+
+// AlertChannel is where an alert is delivered
+type AlertChannel string
+
+const (
+	AlertChannelPagerDuty AlertChannel = "pagerduty" // PagerDuty Events API v2
+	AlertChannelSlack     AlertChannel = "slack"     // Slack incoming webhook
+	AlertChannelWebhook   AlertChannel = "webhook"   // Plain JSON POST, used when no other channel is configured
+)
+
+// Alert is something operations should know about, raised by a subsystem
+// such as reconciliation. Alerts sharing a DedupKey are delivered to a
+// channel at most once per dedup window.
+type Alert struct {
+	Source     string            `json:"source"`
+	Severity   AlertSeverity     `json:"severity"`
+	DedupKey   string            `json:"dedup_key"`
+	Summary    string            `json:"summary"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// AlertDeliveryStatus is the outcome of sending an alert to one channel
+type AlertDeliveryStatus string
+
+const (
+	AlertDeliveryDelivered AlertDeliveryStatus = "delivered" // The channel acknowledged the alert
+	AlertDeliveryFailed    AlertDeliveryStatus = "failed"    // Every attempt failed or was rejected
+)
+
+// AlertDelivery records an alert sent to a channel and whether the channel
+// confirmed it. ProviderRef is what the channel returned to identify it,
+// such as the PagerDuty incident dedup key.
+type AlertDelivery struct {
+	ID             uuid.UUID           `json:"id"`
+	Source         string              `json:"source"`
+	Severity       AlertSeverity       `json:"severity"`
+	DedupKey       string              `json:"dedup_key"`
+	Summary        string              `json:"summary"`
+	Channel        AlertChannel        `json:"channel"`
+	Status         AlertDeliveryStatus `json:"status"`
+	BusinessHours  bool                `json:"business_hours"`
+	Attempts       int                 `json:"attempts"`
+	ResponseStatus *int                `json:"response_status,omitempty"`
+	ProviderRef    *string             `json:"provider_ref,omitempty"`
+	LastError      *string             `json:"last_error,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`
+}
+
+// SendTestAlertRequest is an admin request to send a test alert through routing
+type SendTestAlertRequest struct {
+	Severity AlertSeverity `json:"severity" binding:"required"`
+	Summary  string        `json:"summary"`
+}
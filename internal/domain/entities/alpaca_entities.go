@@ -328,6 +328,44 @@ type AlpacaNewsResponse struct {
 	NextPageToken string              `json:"next_page_token,omitempty"`
 }
 
+// AlpacaLatestQuote is the latest NBBO quote for a symbol
+type AlpacaLatestQuote struct {
+	AskPrice  decimal.Decimal `json:"ap"`
+	AskSize   decimal.Decimal `json:"as"`
+	BidPrice  decimal.Decimal `json:"bp"`
+	BidSize   decimal.Decimal `json:"bs"`
+	Timestamp time.Time       `json:"t"`
+}
+
+// AlpacaLatestQuotesResponse maps symbols to their latest quotes
+type AlpacaLatestQuotesResponse struct {
+	Quotes map[string]AlpacaLatestQuote `json:"quotes"`
+}
+
+// AlpacaBar is one OHLCV bar for a symbol
+type AlpacaBar struct {
+	Timestamp time.Time       `json:"t"`
+	Open      decimal.Decimal `json:"o"`
+	High      decimal.Decimal `json:"h"`
+	Low       decimal.Decimal `json:"l"`
+	Close     decimal.Decimal `json:"c"`
+	Volume    decimal.Decimal `json:"v"`
+}
+
+// AlpacaBarsResponse maps symbols to their bars, oldest first
+type AlpacaBarsResponse struct {
+	Bars          map[string][]AlpacaBar `json:"bars"`
+	NextPageToken string                 `json:"next_page_token,omitempty"`
+}
+
+// AlpacaCalendarDay is one trading session. Open and close are "15:04" in
+// America/New_York; an early close ends before 16:00.
+type AlpacaCalendarDay struct {
+	Date  string `json:"date"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
 // Alpaca Error Response
 
 // Alpaca Funding Entities
@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAPIUsageFilter is returned for a malformed API usage report request
+var ErrInvalidAPIUsageFilter = errors.New("invalid api usage filter")
+
+// APIUsageRollup is the request activity of one user on one endpoint during
+// one rollup bucket
+type APIUsageRollup struct {
+	BucketStart      time.Time
+	UserID           uuid.UUID // uuid.Nil for unauthenticated requests
+	APIKeyID         uuid.UUID // uuid.Nil for requests not made with an API key
+	Method           string
+	Route            string
+	RequestCount     int64
+	ClientErrorCount int64
+	ServerErrorCount int64
+	TotalDurationMs  int64
+	LatencyBuckets   []int64 // request counts per latency bucket
+}
+
+// APIUsageGrouping selects how usage is aggregated
+type APIUsageGrouping string
+
+const (
+	// APIUsageByUserEndpoint keeps each user's use of each endpoint apart
+	APIUsageByUserEndpoint APIUsageGrouping = "user_endpoint"
+	// APIUsageByEndpoint sums every user's use of each endpoint
+	APIUsageByEndpoint APIUsageGrouping = "endpoint"
+)
+
+// APIUsageFilter narrows an API usage report
+type APIUsageFilter struct {
+	UserID   *uuid.UUID
+	APIKeyID *uuid.UUID
+	Route    string
+	Since    time.Time
+	Until    time.Time
+	GroupBy  APIUsageGrouping
+	Limit    int
+}
+
+// APIUsageStat is aggregated request analytics for an endpoint, per user
+// unless grouped by endpoint
+type APIUsageStat struct {
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	Method           string     `json:"method"`
+	Route            string     `json:"route"`
+	RequestCount     int64      `json:"request_count"`
+	ClientErrorCount int64      `json:"client_error_count"`
+	ServerErrorCount int64      `json:"server_error_count"`
+	ErrorRate        float64    `json:"error_rate"` // share of requests answered 4xx or 5xx
+	AvgLatencyMs     float64    `json:"avg_latency_ms"`
+	P95LatencyMs     int64      `json:"p95_latency_ms"` // upper bound of the latency bucket holding the 95th percentile
+}
+
+// APIUsageReport is an API usage report over a time window
+type APIUsageReport struct {
+	Since   time.Time        `json:"since"`
+	Until   time.Time        `json:"until"`
+	GroupBy APIUsageGrouping `json:"group_by"`
+	Stats   []*APIUsageStat  `json:"stats"`
+}
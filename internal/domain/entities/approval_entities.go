@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Approval errors
+var (
+	ErrApprovalNotFound       = errors.New("approval request not found")
+	ErrApprovalNotPending     = errors.New("approval request is not pending")
+	ErrApprovalSelfReview     = errors.New("the requester cannot review their own request")
+	ErrApprovalNotApprover    = errors.New("admin is not an approver for this action")
+	ErrApprovalAlreadyDecided = errors.New("admin has already reviewed this request")
+	ErrApprovalUnknownAction  = errors.New("no approval policy for this action")
+)
+
+// ApprovalAction names an operation that needs maker-checker approval
+type ApprovalAction string
+
+const (
+	// ApprovalActionWithdrawal is a withdrawal above the review threshold
+	ApprovalActionWithdrawal ApprovalAction = "withdrawal"
+	// ApprovalActionBasketDelete removes a curated basket
+	ApprovalActionBasketDelete ApprovalAction = "basket_delete"
+	// ApprovalActionLedgerAdjustment posts a manual correcting ledger entry
+	ApprovalActionLedgerAdjustment ApprovalAction = "ledger_adjustment"
+)
+
+// ApprovalStatus tracks an approval request
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"
+	ApprovalApproved ApprovalStatus = "approved"
+	ApprovalRejected ApprovalStatus = "rejected"
+	// ApprovalFailed requests were approved but the action could not be carried out
+	ApprovalFailed ApprovalStatus = "failed"
+)
+
+// ApprovalDecision is one reviewer's vote on a request
+type ApprovalDecision struct {
+	AdminID   uuid.UUID `json:"admin_id" db:"admin_id"`
+	Approved  bool      `json:"approved" db:"approved"`
+	Notes     *string   `json:"notes,omitempty" db:"notes"`
+	DecidedAt time.Time `json:"decided_at" db:"created_at"`
+}
+
+// ApprovalRequest holds a sensitive action until enough reviewers other than
+// the requester approve it. One rejection rejects it.
+type ApprovalRequest struct {
+	ID                uuid.UUID              `json:"id" db:"id"`
+	Action            ApprovalAction         `json:"action" db:"action"`
+	ResourceID        string                 `json:"resource_id" db:"resource_id"`
+	RequestedBy       *uuid.UUID             `json:"requested_by,omitempty" db:"requested_by"`
+	Summary           string                 `json:"summary" db:"summary"`
+	Payload           map[string]interface{} `json:"payload,omitempty" db:"payload"`
+	RequiredApprovals int                    `json:"required_approvals" db:"required_approvals"`
+	Status            ApprovalStatus         `json:"status" db:"status"`
+	ExecutionError    *string                `json:"execution_error,omitempty" db:"execution_error"`
+	Decisions         []ApprovalDecision     `json:"decisions"`
+	CreatedAt         time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at" db:"updated_at"`
+	ResolvedAt        *time.Time             `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// Approvals counts the approving decisions on the request
+func (r *ApprovalRequest) Approvals() int {
+	count := 0
+	for _, decision := range r.Decisions {
+		if decision.Approved {
+			count++
+		}
+	}
+	return count
+}
+
+// SubmitApprovalRequest asks for approval of an action on a resource
+type SubmitApprovalRequest struct {
+	Action      ApprovalAction
+	ResourceID  string
+	RequestedBy *uuid.UUID // nil when a user, not an admin, started the action
+	Summary     string
+	Payload     map[string]interface{}
+}
+
+// ReviewApprovalRequest is a reviewer's decision on an approval request
+type ReviewApprovalRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
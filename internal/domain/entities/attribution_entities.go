@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// AttributionRange is the look-back window of a performance attribution
+type AttributionRange string
+
+const (
+	AttributionRange1W  AttributionRange = "1W"
+	AttributionRange1M  AttributionRange = "1M"
+	AttributionRange3M  AttributionRange = "3M"
+	AttributionRange6M  AttributionRange = "6M"
+	AttributionRange1Y  AttributionRange = "1Y"
+	AttributionRangeYTD AttributionRange = "YTD"
+)
+
+// ComponentAttribution is one underlying symbol's share of a basket's return.
+// Weight is the component's target weight within its basket; PortfolioWeight
+// is its share of the whole portfolio at the start of the period.
+type ComponentAttribution struct {
+	Symbol          string          `json:"symbol"`
+	Weight          decimal.Decimal `json:"weight"`
+	PortfolioWeight decimal.Decimal `json:"portfolio_weight"`
+	Return          decimal.Decimal `json:"return"`
+	Contribution    decimal.Decimal `json:"contribution"`
+	SelectionEffect decimal.Decimal `json:"selection_effect"`
+	Priced          bool            `json:"priced"`
+}
+
+// BasketAttribution is one basket's share of the portfolio return. The
+// contribution splits into a market effect, what the basket's weight would
+// have earned in the benchmark, and a selection effect, what its holdings
+// earned above or below it.
+type BasketAttribution struct {
+	BasketID        uuid.UUID               `json:"basket_id"`
+	BasketName      string                  `json:"basket_name"`
+	Weight          decimal.Decimal         `json:"weight"`
+	Return          decimal.Decimal         `json:"return"`
+	Contribution    decimal.Decimal         `json:"contribution"`
+	MarketEffect    decimal.Decimal         `json:"market_effect"`
+	SelectionEffect decimal.Decimal         `json:"selection_effect"`
+	StartValue      Money                   `json:"start_value"`
+	EndValue        Money                   `json:"end_value"`
+	Gain            Money                   `json:"gain"`
+	Components      []*ComponentAttribution `json:"components"`
+}
+
+// PerformanceAttribution breaks a user's return over a period down by basket
+// and by component. Returns, weights and effects are ratios (0.042 is 4.2%).
+// ReportedReturn comes from the recorded NAV history and includes deposits,
+// withdrawals and trades, which the holdings-based figures do not; the gap
+// is reported as Unexplained.
+type PerformanceAttribution struct {
+	UserID          uuid.UUID            `json:"user_id"`
+	Range           AttributionRange     `json:"range"`
+	Start           time.Time            `json:"start"`
+	End             time.Time            `json:"end"`
+	Benchmark       string               `json:"benchmark"`
+	BenchmarkReturn decimal.Decimal      `json:"benchmark_return"`
+	PortfolioReturn decimal.Decimal      `json:"portfolio_return"`
+	ActiveReturn    decimal.Decimal      `json:"active_return"`
+	ReportedReturn  *decimal.Decimal     `json:"reported_return,omitempty"`
+	Unexplained     *decimal.Decimal     `json:"unexplained,omitempty"`
+	StartValue      Money                `json:"start_value"`
+	EndValue        Money                `json:"end_value"`
+	Baskets         []*BasketAttribution `json:"baskets"`
+	Highlights      []string             `json:"highlights"`
+	GeneratedAt     time.Time            `json:"generated_at"`
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,9 +25,12 @@ type LoginRequest struct {
 // AuthResponse represents the response after successful authentication
 type AuthResponse struct {
 	User         *UserInfo `json:"user"`
-	AccessToken  string    `json:"accessToken"`
-	RefreshToken string    `json:"refreshToken"`
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
 	ExpiresAt    time.Time `json:"expiresAt"`
+	CSRFToken    string    `json:"csrfToken,omitempty"` // cookie sessions only; send as X-CSRF-Token
+	// Set when the password signed in with no longer meets the password policy
+	PasswordNotice *PasswordNotice `json:"passwordNotice,omitempty"`
 }
 
 // UserInfo represents basic user information returned in auth responses
@@ -38,6 +42,7 @@ type UserInfo struct {
 	PhoneVerified    bool             `json:"phoneVerified"`
 	OnboardingStatus OnboardingStatus `json:"onboardingStatus"`
 	KYCStatus        string           `json:"kycStatus"`
+	Timezone         string           `json:"timezone,omitempty"`
 	CreatedAt        time.Time        `json:"createdAt"`
 }
 
@@ -112,9 +117,10 @@ type SignUpResponse struct {
 // VerifyCodeResponse represents the response after successful verification
 type VerifyCodeResponse struct {
 	User         *UserInfo `json:"user"`
-	AccessToken  string    `json:"accessToken"`
-	RefreshToken string    `json:"refreshToken"`
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
 	ExpiresAt    time.Time `json:"expiresAt"`
+	CSRFToken    string    `json:"csrfToken,omitempty"` // cookie sessions only; send as X-CSRF-Token
 }
 
 // User represents a complete user entity for database operations
@@ -135,6 +141,7 @@ type User struct {
 	DueAccountID       *string          `json:"dueAccountId" db:"due_account_id"`
 	DueKYCStatus       *string          `json:"dueKycStatus" db:"due_kyc_status"`
 	DueKYCLink         *string          `json:"dueKycLink" db:"due_kyc_link"`
+	Timezone           string           `json:"timezone" db:"timezone"`
 	Role               string           `json:"role" db:"role"`
 	IsActive           bool             `json:"isActive" db:"is_active"`
 	LastLoginAt        *time.Time       `json:"lastLoginAt" db:"last_login_at"`
@@ -142,6 +149,33 @@ type User struct {
 	UpdatedAt          time.Time        `json:"updatedAt" db:"updated_at"`
 }
 
+// DefaultTimezone is the time zone of users who have not set one
+const DefaultTimezone = "UTC"
+
+// ErrInvalidTimezone is returned for a time zone that is not an IANA name
+var ErrInvalidTimezone = errors.New("timezone must be an IANA name such as America/New_York")
+
+// ValidateTimezone checks that name is a known IANA time zone
+func ValidateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// UserLocation returns the location a user's local times are computed in,
+// UTC when their time zone is unset or unknown
+func UserLocation(timezone string) *time.Location {
+	if ValidateTimezone(timezone) != nil {
+		return time.UTC
+	}
+	loc, _ := time.LoadLocation(timezone)
+	return loc
+}
+
 // ToUserInfo converts User to UserInfo for public responses
 func (u *User) ToUserInfo() *UserInfo {
 	return &UserInfo{
@@ -152,6 +186,7 @@ func (u *User) ToUserInfo() *UserInfo {
 		PhoneVerified:    u.PhoneVerified,
 		OnboardingStatus: u.OnboardingStatus,
 		KYCStatus:        u.KYCStatus,
+		Timezone:         u.Timezone,
 		CreatedAt:        u.CreatedAt,
 	}
 }
@@ -171,6 +206,7 @@ func (u *User) ToUserProfile() *UserProfile {
 		KYCSubmittedAt:     u.KYCSubmittedAt,
 		KYCApprovedAt:      u.KYCApprovedAt,
 		KYCRejectionReason: u.KYCRejectionReason,
+		Timezone:           u.Timezone,
 		IsActive:           u.IsActive,
 		CreatedAt:          u.CreatedAt,
 		UpdatedAt:          u.UpdatedAt,
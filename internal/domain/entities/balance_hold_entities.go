@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Balance hold errors
+var (
+	ErrBalanceHoldNotFound     = errors.New("balance hold not found")
+	ErrBalanceHoldSettled      = errors.New("balance hold is already settled")
+	ErrInsufficientBuyingPower = errors.New("insufficient buying power")
+)
+
+// HoldKind is the kind of operation a balance hold reserves funds for
+type HoldKind string
+
+const (
+	HoldKindOrder      HoldKind = "order"
+	HoldKindWithdrawal HoldKind = "withdrawal"
+)
+
+// HoldStatus tracks a balance hold from placement to settlement
+type HoldStatus string
+
+const (
+	HoldStatusActive HoldStatus = "active"
+	// HoldStatusCaptured holds were spent by the operation they reserved for
+	HoldStatusCaptured HoldStatus = "captured"
+	// HoldStatusReleased holds were returned to buying power
+	HoldStatusReleased HoldStatus = "released"
+)
+
+// BalanceHold is a lien on a user's buying power for a pending order or
+// withdrawal. Placing it deducts the amount atomically, so concurrent
+// operations cannot spend the same balance; settlement either captures the
+// amount or releases it back.
+type BalanceHold struct {
+	ID                  uuid.UUID       `json:"id" db:"id"`
+	UserID              uuid.UUID       `json:"user_id" db:"user_id"`
+	Kind                HoldKind        `json:"kind" db:"kind"`
+	ReferenceID         uuid.UUID       `json:"reference_id" db:"reference_id"`
+	Amount              decimal.Decimal `json:"amount" db:"amount"`
+	Status              HoldStatus      `json:"status" db:"status"`
+	LedgerTransactionID *uuid.UUID      `json:"-" db:"ledger_transaction_id"`
+	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
+	SettledAt           *time.Time      `json:"settled_at,omitempty" db:"settled_at"`
+}
+
+// ActiveHolds lists the funds currently held against a user's buying power
+type ActiveHolds struct {
+	Holds     []*BalanceHold  `json:"holds"`
+	TotalHeld decimal.Decimal `json:"total_held"`
+}
@@ -0,0 +1,156 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidRegion is returned for a region tag that is neither a country
+	// code nor a known region group
+	ErrInvalidRegion = errors.New("invalid region")
+
+	// ErrInvalidComposition is returned when a basket's components are empty,
+	// repeat a symbol, or their weights do not add up to 1
+	ErrInvalidComposition = errors.New("invalid basket composition")
+)
+
+// RegionGroups expands region tags that cover several countries
+var RegionGroups = map[string][]string{
+	"EU": {
+		"AT", "BE", "BG", "HR", "CY", "CZ", "DK", "EE", "FI", "FR", "DE", "GR", "HU", "IE",
+		"IT", "LV", "LT", "LU", "MT", "NL", "PL", "PT", "RO", "SK", "SI", "ES", "SE",
+	},
+}
+
+// NormalizeRegions upper-cases and de-duplicates region tags, rejecting any
+// that are not an ISO 3166-1 alpha-2 code or a region group
+func NormalizeRegions(regions []string) ([]string, error) {
+	out := make([]string, 0, len(regions))
+	seen := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		region = NormalizeCountryCode(region)
+		if _, group := RegionGroups[region]; !group && !isCountryCode(region) {
+			return nil, ErrInvalidRegion
+		}
+		if !seen[region] {
+			seen[region] = true
+			out = append(out, region)
+		}
+	}
+	return out, nil
+}
+
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// AvailableIn reports whether the basket is offered to users in a country.
+// Untagged baskets are offered everywhere; tagged baskets are hidden from
+// users whose country is unknown.
+func (b *Basket) AvailableIn(countryCode string) bool {
+	if len(b.Regions) == 0 {
+		return true
+	}
+	countryCode = NormalizeCountryCode(countryCode)
+	if countryCode == "" {
+		return false
+	}
+	for _, region := range b.Regions {
+		if region == countryCode {
+			return true
+		}
+		for _, member := range RegionGroups[region] {
+			if member == countryCode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FamilyID is the basket the analytics for this basket roll up to: its parent
+// for a regional variant, otherwise itself
+func (b *Basket) FamilyID() uuid.UUID {
+	if b.ParentBasketID != nil {
+		return *b.ParentBasketID
+	}
+	return b.ID
+}
+
+// ValidateComposition checks components name distinct symbols with positive
+// weights adding up to 1
+func ValidateComposition(components []BasketComponent) error {
+	if len(components) == 0 {
+		return ErrInvalidComposition
+	}
+	total := decimal.Zero
+	seen := make(map[string]bool, len(components))
+	for _, component := range components {
+		if component.Symbol == "" || seen[component.Symbol] || !component.Weight.IsPositive() {
+			return ErrInvalidComposition
+		}
+		seen[component.Symbol] = true
+		total = total.Add(component.Weight)
+	}
+	if !total.Equal(decimal.NewFromInt(1)) {
+		return ErrInvalidComposition
+	}
+	return nil
+}
+
+// SymbolReplacement swaps one holding for another in a cloned basket, e.g. a
+// US-listed ETF for its UCITS equivalent
+type SymbolReplacement struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// CloneBasketRequest creates a regional variant of a curated basket. The
+// clone starts from the source composition with Replacements applied, unless
+// Composition replaces it outright.
+type CloneBasketRequest struct {
+	Name         string              `json:"name" binding:"required"`
+	Description  *string             `json:"description,omitempty"`
+	RiskLevel    *RiskLevel          `json:"risk_level,omitempty"`
+	Regions      []string            `json:"regions"`
+	Replacements []SymbolReplacement `json:"replacements,omitempty"`
+	Composition  []BasketComponent   `json:"composition,omitempty"`
+}
+
+// SetBasketRegionsRequest replaces the regions a basket is offered in
+type SetBasketRegionsRequest struct {
+	Regions []string `json:"regions"`
+}
+
+// BasketVariantStats is order and holding activity for one basket
+type BasketVariantStats struct {
+	BasketID      uuid.UUID       `json:"basket_id"`
+	Name          string          `json:"name"`
+	Regions       []string        `json:"regions"`
+	Orders        int64           `json:"orders"`
+	AmountOrdered decimal.Decimal `json:"amount_ordered"`
+	Holders       int64           `json:"holders"`
+}
+
+// BasketFamilyAnalytics totals activity across a basket and its regional
+// variants, with the breakdown per variant
+type BasketFamilyAnalytics struct {
+	ParentBasketID uuid.UUID            `json:"parent_basket_id"`
+	Orders         int64                `json:"orders"`
+	AmountOrdered  decimal.Decimal      `json:"amount_ordered"`
+	Holders        int64                `json:"holders"`
+	Variants       []BasketVariantStats `json:"variants"`
+	GeneratedAt    time.Time            `json:"generated_at"`
+}
@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Order blotter errors
+var (
+	ErrBlotterNotFound         = errors.New("order blotter not found")
+	ErrBlotterVersionConflict  = errors.New("order blotter version was stored by another generator")
+	ErrInvalidBlotterDateRange = errors.New("invalid order blotter date range")
+	ErrInvalidBlotterFormat    = errors.New("unsupported order blotter format")
+)
+
+// BlotterFormat is the file layout a blotter is rendered in
+type BlotterFormat string
+
+const (
+	BlotterFormatCSV BlotterFormat = "csv"
+	BlotterFormatFIX BlotterFormat = "fix" // FIX tag=value execution reports, one per line
+)
+
+// IsValid reports whether the format can be rendered
+func (f BlotterFormat) IsValid() bool {
+	return f == BlotterFormatCSV || f == BlotterFormatFIX
+}
+
+// BlotterEventType is what happened to an order in a blotter row
+type BlotterEventType string
+
+const (
+	BlotterEventNew    BlotterEventType = "new"    // order received from the user
+	BlotterEventStatus BlotterEventType = "status" // order reached its current status, e.g. filled or canceled
+	BlotterEventAmend  BlotterEventType = "amend"  // admin intervention on the order
+)
+
+// BlotterRecord is one order event on the blotter of record
+type BlotterRecord struct {
+	EventID        string           `json:"event_id"`
+	EventType      BlotterEventType `json:"event_type"`
+	EventTime      time.Time        `json:"event_time"`
+	OrderID        uuid.UUID        `json:"order_id"`
+	UserID         uuid.UUID        `json:"user_id"`
+	BasketID       uuid.UUID        `json:"basket_id"`
+	Side           OrderSide        `json:"side"`
+	OrderType      OrderType        `json:"order_type"`
+	TimeInForce    TimeInForce      `json:"time_in_force"`
+	LimitPrice     *decimal.Decimal `json:"limit_price,omitempty"`
+	Amount         decimal.Decimal  `json:"amount"`
+	Status         string           `json:"status"`
+	PreviousStatus string           `json:"previous_status,omitempty"`
+	Venue          string           `json:"venue"`
+	BrokerageRef   string           `json:"brokerage_ref,omitempty"`
+	OrderCreatedAt time.Time        `json:"order_created_at"`
+	ActorID        *uuid.UUID       `json:"actor_id,omitempty"` // admin who amended the order
+	Action         string           `json:"action,omitempty"`   // cancel, repair or annotate for amendments
+}
+
+// OrderBlotter is the stored blotter for one business day. Stored blotters
+// are never changed; generating a day again stores a new version, and the
+// earlier versions stay retrievable.
+type OrderBlotter struct {
+	ID           uuid.UUID     `json:"id" db:"id"`
+	BusinessDate time.Time     `json:"business_date" db:"business_date"`
+	Version      int           `json:"version" db:"version"`
+	Format       BlotterFormat `json:"format" db:"format"`
+	RecordCount  int           `json:"record_count" db:"record_count"`
+	Checksum     string        `json:"checksum" db:"checksum"` // SHA-256 of Content
+	Content      []byte        `json:"-" db:"content"`
+	Reason       string        `json:"reason,omitempty" db:"reason"`
+	GeneratedBy  *uuid.UUID    `json:"generated_by,omitempty" db:"generated_by"` // nil for the daily worker
+	GeneratedAt  time.Time     `json:"generated_at" db:"generated_at"`
+}
+
+// GenerateBlotterRequest generates a day's blotter again, for example after
+// an order was corrected
+type GenerateBlotterRequest struct {
+	Date   string        `json:"date" binding:"required"` // YYYY-MM-DD
+	Format BlotterFormat `json:"format,omitempty"`        // Defaults to the configured format
+	Reason string        `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk operation errors
+var (
+	ErrBulkOperationNotFound = errors.New("bulk operation not found")
+	ErrInvalidBulkOperation  = errors.New("invalid bulk operation")
+)
+
+// BulkOperationType is the action applied to each user in a bulk operation
+type BulkOperationType string
+
+const (
+	BulkDeactivateUsers    BulkOperationType = "deactivate_users"
+	BulkReactivateUsers    BulkOperationType = "reactivate_users"
+	BulkKYCRecheck         BulkOperationType = "kyc_recheck"               // expire approved KYC so the user verifies again
+	BulkResendVerification BulkOperationType = "resend_verification_email" // users whose email is not yet verified
+)
+
+// IsValid reports whether t is a known operation type
+func (t BulkOperationType) IsValid() bool {
+	switch t {
+	case BulkDeactivateUsers, BulkReactivateUsers, BulkKYCRecheck, BulkResendVerification:
+		return true
+	}
+	return false
+}
+
+// BulkOperationStatus tracks an operation while its items are worked
+type BulkOperationStatus string
+
+const (
+	BulkOperationRunning   BulkOperationStatus = "running"
+	BulkOperationCompleted BulkOperationStatus = "completed" // every item has an outcome
+	BulkOperationCancelled BulkOperationStatus = "cancelled" // remaining items were skipped
+)
+
+// BulkItemStatus is the outcome for one user
+type BulkItemStatus string
+
+const (
+	BulkItemPending   BulkItemStatus = "pending"
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	BulkItemSkipped   BulkItemStatus = "skipped" // nothing to do, e.g. the user is already inactive
+	BulkItemFailed    BulkItemStatus = "failed"
+)
+
+// BulkOperation applies one admin action to a list of users in the background
+type BulkOperation struct {
+	ID          uuid.UUID           `json:"id" db:"id"`
+	Type        BulkOperationType   `json:"type" db:"type"`
+	Status      BulkOperationStatus `json:"status" db:"status"`
+	Reason      string              `json:"reason" db:"reason"`
+	RequestedBy uuid.UUID           `json:"requested_by" db:"requested_by"`
+	Total       int                 `json:"total" db:"total"`
+	Succeeded   int                 `json:"succeeded" db:"succeeded"`
+	Skipped     int                 `json:"skipped" db:"skipped"`
+	Failed      int                 `json:"failed" db:"failed"`
+	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// Pending is the number of users still waiting for an outcome
+func (o *BulkOperation) Pending() int {
+	return o.Total - o.Succeeded - o.Skipped - o.Failed
+}
+
+// BulkOperationItem is one user's part of a bulk operation
+type BulkOperationItem struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	OperationID uuid.UUID      `json:"operation_id" db:"operation_id"`
+	UserID      uuid.UUID      `json:"user_id" db:"user_id"`
+	Status      BulkItemStatus `json:"status" db:"status"`
+	Message     string         `json:"message,omitempty" db:"message"`
+	Attempts    int            `json:"attempts" db:"attempts"`
+	ProcessedAt *time.Time     `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+// ClaimedBulkItem is a leased item with the operation it belongs to
+type ClaimedBulkItem struct {
+	BulkOperationItem
+	Type   BulkOperationType
+	Reason string
+}
+
+// CreateBulkOperationRequest starts a bulk operation
+type CreateBulkOperationRequest struct {
+	Type    BulkOperationType `json:"type" binding:"required"`
+	UserIDs []uuid.UUID       `json:"user_ids" binding:"required,min=1"`
+	Reason  string            `json:"reason" binding:"required,max=500"`
+}
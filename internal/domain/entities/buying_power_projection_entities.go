@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ProjectedFlowKind is what moves buying power on a projected day
+type ProjectedFlowKind string
+
+const (
+	ProjectedFlowDeposit    ProjectedFlowKind = "pending_deposit"      // Deposit not yet credited
+	ProjectedFlowOrderHold  ProjectedFlowKind = "order_hold"           // Funds reserved for an open order
+	ProjectedFlowWithdrawal ProjectedFlowKind = "withdrawal"           // Withdrawal reserved and awaiting payout
+	ProjectedFlowRecurring  ProjectedFlowKind = "recurring_investment" // Auto-sweep run into a basket
+)
+
+// ProjectedFlow is one expected movement of buying power. Outflows are
+// negative. Estimated flows have an ExpectedAt or amount that is a guess,
+// such as a deposit still waiting on a bank or an idle-cash sweep.
+type ProjectedFlow struct {
+	Kind        ProjectedFlowKind `json:"kind"`
+	ReferenceID uuid.UUID         `json:"reference_id"`
+	Amount      decimal.Decimal   `json:"amount"`
+	ExpectedAt  time.Time         `json:"expected_at"`
+	Estimated   bool              `json:"estimated"`
+	Description string            `json:"description"`
+}
+
+// ProjectedDay is projected buying power at the end of one UTC day
+type ProjectedDay struct {
+	Date        string          `json:"date"` // YYYY-MM-DD
+	Inflows     decimal.Decimal `json:"inflows"`
+	Outflows    decimal.Decimal `json:"outflows"`
+	BuyingPower decimal.Decimal `json:"buying_power"`
+}
+
+// BuyingPowerProjection is a day-by-day curve of a user's buying power. It
+// starts from settled cash, takes off what active holds have reserved, and
+// applies pending deposits and scheduled investments on the days they are
+// expected.
+type BuyingPowerProjection struct {
+	AsOf        time.Time       `json:"as_of"`
+	Days        int             `json:"days"`
+	SettledCash decimal.Decimal `json:"settled_cash"`
+	BuyingPower decimal.Decimal `json:"buying_power"` // Available now, after holds
+	Flows       []ProjectedFlow `json:"flows"`
+	Curve       []ProjectedDay  `json:"curve"`
+}
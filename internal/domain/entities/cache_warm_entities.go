@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCacheWarmRunning is returned when a warm-up is requested while one is in progress
+var ErrCacheWarmRunning = errors.New("cache warm-up already running")
+
+// CacheWarmTrigger is what started a warm-up
+type CacheWarmTrigger string
+
+const (
+	CacheWarmTriggerStartup CacheWarmTrigger = "startup"
+	CacheWarmTriggerAdmin   CacheWarmTrigger = "admin"
+)
+
+// CacheWarmReport describes the last cache warm-up. Users and Baskets count
+// what was loaded into Redis; Failed counts loads that returned an error and
+// were left for the first request to fill.
+type CacheWarmReport struct {
+	Trigger    CacheWarmTrigger `json:"trigger"`
+	Running    bool             `json:"running"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Users      int              `json:"users"`
+	Baskets    int              `json:"baskets"`
+	Failed     int              `json:"failed"`
+}
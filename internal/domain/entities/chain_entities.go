@@ -0,0 +1,282 @@
+package entities
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/stack-service/stack_service/pkg/chainaddr"
+)
+
+// ChainFamily groups chains that share an address format and tooling
+type ChainFamily string
+
+const (
+	ChainFamilySolana ChainFamily = "Solana"
+	ChainFamilyEVM    ChainFamily = "EVM"
+	ChainFamilyAptos  ChainFamily = "Aptos"
+)
+
+// AddressFormat is the address format the family's chains share
+func (f ChainFamily) AddressFormat() chainaddr.Format {
+	switch f {
+	case ChainFamilySolana:
+		return chainaddr.FormatSolana
+	case ChainFamilyEVM:
+		return chainaddr.FormatEVM
+	case ChainFamilyAptos:
+		return chainaddr.FormatAptos
+	default:
+		return ""
+	}
+}
+
+// DueSchema is the address schema Due expects for wallets on the family
+func (f ChainFamily) DueSchema() string {
+	switch f {
+	case ChainFamilySolana:
+		return "solana"
+	case ChainFamilyEVM:
+		return "evm"
+	default:
+		return ""
+	}
+}
+
+const (
+	solanaAddressPattern = `^[1-9A-HJ-NP-Za-km-z]{32,44}$`
+	evmAddressPattern    = `^0x[0-9a-fA-F]{40}$`
+)
+
+// ChainInfo describes a chain the platform can hold USDC on. Enabled chains
+// are the ones wallets may be created and deposits accepted on.
+type ChainInfo struct {
+	ID               WalletChain `json:"id" mapstructure:"id"`
+	Name             string      `json:"name" mapstructure:"name"`
+	Family           ChainFamily `json:"family" mapstructure:"family"`
+	Testnet          bool        `json:"testnet" mapstructure:"testnet"`
+	Enabled          bool        `json:"enabled" mapstructure:"enabled"`
+	NativeCurrency   string      `json:"native_currency" mapstructure:"native_currency"`
+	USDCTokenAddress string      `json:"usdc_token_address" mapstructure:"usdc_token_address"`
+	ExplorerURL      string      `json:"explorer_url" mapstructure:"explorer_url"` // transaction URL with %s for the hash
+	Confirmations    int         `json:"confirmations" mapstructure:"confirmations"`
+	AddressPattern   string      `json:"address_pattern" mapstructure:"address_pattern"`
+	Aliases          []string    `json:"-" mapstructure:"aliases"` // names Circle and webhooks use for the chain
+
+	addressRegexp *regexp.Regexp
+}
+
+// Validate checks that a chain definition is complete and well formed
+func (c *ChainInfo) Validate() error {
+	if c.ID == "" || string(c.ID) != strings.ToUpper(string(c.ID)) {
+		return fmt.Errorf("chain id %q must be non-empty and upper case", c.ID)
+	}
+	if c.Name == "" {
+		return fmt.Errorf("chain %s: name is required", c.ID)
+	}
+	if c.Family.AddressFormat() == "" {
+		return fmt.Errorf("chain %s: unknown family %q", c.ID, c.Family)
+	}
+	if c.NativeCurrency == "" {
+		return fmt.Errorf("chain %s: native currency is required", c.ID)
+	}
+	if c.USDCTokenAddress == "" {
+		return fmt.Errorf("chain %s: USDC token address is required", c.ID)
+	}
+	if c.Confirmations < 1 {
+		return fmt.Errorf("chain %s: confirmations must be at least 1", c.ID)
+	}
+	if strings.Count(c.ExplorerURL, "%s") != 1 {
+		return fmt.Errorf("chain %s: explorer URL must contain one %%s for the transaction hash", c.ID)
+	}
+	if parsed, err := url.Parse(strings.Replace(c.ExplorerURL, "%s", "x", 1)); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("chain %s: explorer URL must be an https URL", c.ID)
+	}
+	pattern, err := regexp.Compile(c.AddressPattern)
+	if c.AddressPattern == "" || err != nil {
+		return fmt.Errorf("chain %s: invalid address pattern %q", c.ID, c.AddressPattern)
+	}
+	if !pattern.MatchString(c.USDCTokenAddress) {
+		return fmt.Errorf("chain %s: USDC token address does not match the address pattern", c.ID)
+	}
+	c.addressRegexp = pattern
+	if _, err := c.CheckAddress(c.USDCTokenAddress); err != nil {
+		return fmt.Errorf("chain %s: USDC token address: %w", c.ID, err)
+	}
+	return nil
+}
+
+// CheckAddress validates address against the chain's pattern and its
+// family's format, returning it in the family's canonical form
+func (c ChainInfo) CheckAddress(address string) (string, error) {
+	if c.addressRegexp == nil {
+		return "", fmt.Errorf("chain %s has no address pattern", c.ID)
+	}
+	if address != "" && !c.addressRegexp.MatchString(address) {
+		return "", fmt.Errorf("%w: not a %s address", chainaddr.ErrMalformed, c.Name)
+	}
+	return chainaddr.Validate(c.Family.AddressFormat(), address)
+}
+
+// ValidAddress reports whether address is well formed on the chain
+func (c ChainInfo) ValidAddress(address string) bool {
+	_, err := c.CheckAddress(address)
+	return err == nil
+}
+
+// ExplorerTxURL links to a transaction in the chain's block explorer
+func (c ChainInfo) ExplorerTxURL(txHash string) string {
+	return fmt.Sprintf(c.ExplorerURL, url.PathEscape(txHash))
+}
+
+// DefaultChains is the built-in chain catalog. Only SOL-DEVNET is enabled;
+// the others are enabled through configuration.
+func DefaultChains() []ChainInfo {
+	return []ChainInfo{
+		{
+			ID: ChainSOLDevnet, Name: "Solana Devnet", Family: ChainFamilySolana, Testnet: true, Enabled: true,
+			NativeCurrency: "SOL", USDCTokenAddress: USDCTokenAddressSOLDevnet,
+			ExplorerURL: "https://explorer.solana.com/tx/%s?cluster=devnet", Confirmations: 1,
+			AddressPattern: solanaAddressPattern, Aliases: []string{"sol_devnet"},
+		},
+		{
+			ID: "SOL", Name: "Solana", Family: ChainFamilySolana,
+			NativeCurrency: "SOL", USDCTokenAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+			ExplorerURL: "https://explorer.solana.com/tx/%s", Confirmations: 32,
+			AddressPattern: solanaAddressPattern, Aliases: []string{"solana"},
+		},
+		{
+			ID: "ETH", Name: "Ethereum", Family: ChainFamilyEVM,
+			NativeCurrency: "ETH", USDCTokenAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			ExplorerURL: "https://etherscan.io/tx/%s", Confirmations: 12,
+			AddressPattern: evmAddressPattern, Aliases: []string{"ethereum"},
+		},
+		{
+			ID: "ETH-SEPOLIA", Name: "Ethereum Sepolia", Family: ChainFamilyEVM, Testnet: true,
+			NativeCurrency: "ETH", USDCTokenAddress: "0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238",
+			ExplorerURL: "https://sepolia.etherscan.io/tx/%s", Confirmations: 3,
+			AddressPattern: evmAddressPattern,
+		},
+		{
+			ID: "MATIC", Name: "Polygon PoS", Family: ChainFamilyEVM,
+			NativeCurrency: "POL", USDCTokenAddress: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359",
+			ExplorerURL: "https://polygonscan.com/tx/%s", Confirmations: 128,
+			AddressPattern: evmAddressPattern, Aliases: []string{"polygon"},
+		},
+		{
+			ID: "MATIC-AMOY", Name: "Polygon Amoy", Family: ChainFamilyEVM, Testnet: true,
+			NativeCurrency: "POL", USDCTokenAddress: "0x41E94Eb019C0762f9Bfcf9Fb1E58725BfB0e7582",
+			ExplorerURL: "https://amoy.polygonscan.com/tx/%s", Confirmations: 3,
+			AddressPattern: evmAddressPattern,
+		},
+		{
+			ID: "BASE", Name: "Base", Family: ChainFamilyEVM,
+			NativeCurrency: "ETH", USDCTokenAddress: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			ExplorerURL: "https://basescan.org/tx/%s", Confirmations: 12,
+			AddressPattern: evmAddressPattern,
+		},
+		{
+			ID: "BASE-SEPOLIA", Name: "Base Sepolia", Family: ChainFamilyEVM, Testnet: true,
+			NativeCurrency: "ETH", USDCTokenAddress: "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+			ExplorerURL: "https://sepolia.basescan.org/tx/%s", Confirmations: 3,
+			AddressPattern: evmAddressPattern,
+		},
+	}
+}
+
+// ChainRegistry is the validated set of chains, in catalog order
+type ChainRegistry struct {
+	chains []ChainInfo
+	byName map[string]int // upper-cased ID or alias -> index into chains
+}
+
+// NewChainRegistry validates chain definitions and indexes them by ID and alias
+func NewChainRegistry(chains []ChainInfo) (*ChainRegistry, error) {
+	registry := &ChainRegistry{
+		chains: make([]ChainInfo, 0, len(chains)),
+		byName: make(map[string]int, len(chains)),
+	}
+	for _, chain := range chains {
+		chain.Aliases = append([]string(nil), chain.Aliases...)
+		if err := chain.Validate(); err != nil {
+			return nil, err
+		}
+		index := len(registry.chains)
+		for _, name := range append([]string{string(chain.ID)}, chain.Aliases...) {
+			key := strings.ToUpper(strings.TrimSpace(name))
+			if _, taken := registry.byName[key]; taken {
+				return nil, fmt.Errorf("chain %s: name %q is already used by another chain", chain.ID, name)
+			}
+			registry.byName[key] = index
+		}
+		registry.chains = append(registry.chains, chain)
+	}
+	return registry, nil
+}
+
+// Lookup finds a chain by ID or alias, case-insensitively
+func (r *ChainRegistry) Lookup(name string) (ChainInfo, bool) {
+	index, ok := r.byName[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return ChainInfo{}, false
+	}
+	return r.chains[index], true
+}
+
+// All returns every chain in the registry, enabled or not
+func (r *ChainRegistry) All() []ChainInfo {
+	return append([]ChainInfo(nil), r.chains...)
+}
+
+// Enabled returns the enabled chains
+func (r *ChainRegistry) Enabled() []ChainInfo {
+	var enabled []ChainInfo
+	for _, chain := range r.chains {
+		if chain.Enabled {
+			enabled = append(enabled, chain)
+		}
+	}
+	return enabled
+}
+
+// EnabledIDs returns the IDs of the enabled chains
+func (r *ChainRegistry) EnabledIDs() []string {
+	var ids []string
+	for _, chain := range r.Enabled() {
+		ids = append(ids, string(chain.ID))
+	}
+	return ids
+}
+
+var chainRegistry atomic.Pointer[ChainRegistry]
+
+func init() {
+	registry, err := NewChainRegistry(DefaultChains())
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in chain catalog: %v", err))
+	}
+	chainRegistry.Store(registry)
+}
+
+// Chains returns the process-wide chain registry
+func Chains() *ChainRegistry {
+	return chainRegistry.Load()
+}
+
+// SetChainRegistry replaces the process-wide chain registry, normally once at
+// startup with the configured chains
+func SetChainRegistry(registry *ChainRegistry) {
+	chainRegistry.Store(registry)
+}
+
+// Info returns the chain's registry entry. Wallet chains are matched on ID
+// only, not aliases.
+func (c WalletChain) Info() (ChainInfo, bool) {
+	info, ok := Chains().Lookup(string(c))
+	if !ok || info.ID != c {
+		return ChainInfo{}, false
+	}
+	return info, true
+}
@@ -0,0 +1,48 @@
+package entities
+
+import "time"
+
+// CircleNotificationSubscription is a Circle webhook subscription
+type CircleNotificationSubscription struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name,omitempty"`
+	Endpoint          string    `json:"endpoint"`
+	Enabled           bool      `json:"enabled"`
+	NotificationTypes []string  `json:"notificationTypes,omitempty"`
+	Restricted        bool      `json:"restricted"`
+	CreateDate        time.Time `json:"createDate"`
+	UpdateDate        time.Time `json:"updateDate"`
+}
+
+// CircleCreateSubscriptionRequest registers a callback URL with Circle.
+// Omitting NotificationTypes subscribes to every type.
+type CircleCreateSubscriptionRequest struct {
+	Endpoint          string   `json:"endpoint"`
+	NotificationTypes []string `json:"notificationTypes,omitempty"`
+}
+
+// CircleNotificationPublicKey verifies signatures on Circle notifications
+type CircleNotificationPublicKey struct {
+	ID         string    `json:"id"`
+	Algorithm  string    `json:"algorithm"`
+	PublicKey  string    `json:"publicKey"`
+	CreateDate time.Time `json:"createDate"`
+}
+
+// CircleSubscriptionRegistration records a subscription this service created,
+// so it can be removed when the callback URL changes
+type CircleSubscriptionRegistration struct {
+	SubscriptionID string     `json:"subscription_id" db:"subscription_id"`
+	Endpoint       string     `json:"endpoint" db:"endpoint"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	VerifiedAt     *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+}
+
+// CircleSubscriptionStatus is the outcome of the latest subscription check
+type CircleSubscriptionStatus struct {
+	CallbackURL    string    `json:"callback_url"`
+	SubscriptionID string    `json:"subscription_id,omitempty"`
+	Healthy        bool      `json:"healthy"`
+	Message        string    `json:"message"`
+	CheckedAt      time.Time `json:"checked_at"`
+}
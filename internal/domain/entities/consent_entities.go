@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Consent errors
+var (
+	ErrAgreementNotFound      = errors.New("agreement version not found")
+	ErrAgreementVersionExists = errors.New("agreement version already published")
+	ErrAgreementSuperseded    = errors.New("agreement version has been superseded")
+	ErrInvalidAgreement       = errors.New("invalid agreement version")
+)
+
+// AgreementType names a legal document users accept
+type AgreementType string
+
+const (
+	AgreementTermsOfService AgreementType = "terms_of_service"
+	AgreementPrivacyPolicy  AgreementType = "privacy_policy"
+	// AgreementBrokerage is the brokerage customer agreement trading is done under
+	AgreementBrokerage AgreementType = "brokerage_agreement"
+)
+
+// KnownAgreementTypes lists the agreements that can be published
+var KnownAgreementTypes = []AgreementType{
+	AgreementTermsOfService,
+	AgreementPrivacyPolicy,
+	AgreementBrokerage,
+}
+
+// IsValid reports whether the agreement type is known
+func (t AgreementType) IsValid() bool {
+	for _, known := range KnownAgreementTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// AgreementVersion is one published revision of an agreement. Users must
+// re-accept a mandatory version before they can trade again.
+type AgreementVersion struct {
+	ID            uuid.UUID     `json:"id" db:"id"`
+	AgreementType AgreementType `json:"agreement_type" db:"agreement_type"`
+	Version       string        `json:"version" db:"version"`
+	Title         string        `json:"title" db:"title"`
+	DocumentURL   string        `json:"document_url" db:"document_url"`
+	Mandatory     bool          `json:"mandatory" db:"mandatory"`
+	PublishedBy   *uuid.UUID    `json:"published_by,omitempty" db:"published_by"`
+	PublishedAt   time.Time     `json:"published_at" db:"published_at"`
+}
+
+// UserConsent records a user accepting an agreement version
+type UserConsent struct {
+	ID                 uuid.UUID     `json:"id" db:"id"`
+	UserID             uuid.UUID     `json:"user_id" db:"user_id"`
+	AgreementVersionID uuid.UUID     `json:"agreement_version_id" db:"agreement_version_id"`
+	AgreementType      AgreementType `json:"agreement_type" db:"agreement_type"`
+	Version            string        `json:"version" db:"version"`
+	IPAddress          *string       `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent          *string       `json:"user_agent,omitempty" db:"user_agent"`
+	AcceptedAt         time.Time     `json:"accepted_at" db:"accepted_at"`
+}
+
+// ConsentStatus is where a user stands on the current version of an agreement
+type ConsentStatus struct {
+	Current  *AgreementVersion `json:"current"`
+	Accepted *UserConsent      `json:"accepted,omitempty"`
+	// RequiresAcceptance is set when a mandatory version published after
+	// the user's last acceptance is outstanding
+	RequiresAcceptance bool `json:"requires_acceptance"`
+}
+
+// PublishAgreementRequest publishes a new agreement version
+type PublishAgreementRequest struct {
+	AgreementType AgreementType `json:"agreement_type" binding:"required"`
+	Version       string        `json:"version" binding:"required"`
+	Title         string        `json:"title" binding:"required"`
+	DocumentURL   string        `json:"document_url" binding:"required,url"`
+	Mandatory     bool          `json:"mandatory"`
+}
@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Cost basis errors
+var (
+	ErrCostBasisImportNotFound = errors.New("cost basis import not found")
+	ErrTaxLotNotFound          = errors.New("tax lot not found")
+)
+
+// TaxLotSource is how a tax lot's cost basis was recorded
+type TaxLotSource string
+
+const (
+	// TaxLotSourceTransferIn lots came over from another broker, with the
+	// cost basis from the user's export of that broker's records
+	TaxLotSourceTransferIn TaxLotSource = "transfer_in"
+)
+
+// TaxLot is a quantity of a security acquired together, with what it cost
+type TaxLot struct {
+	ID         uuid.UUID       `json:"id"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Symbol     string          `json:"symbol"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	CostBasis  decimal.Decimal `json:"cost_basis"` // total, not per share
+	AcquiredAt time.Time       `json:"acquired_at"`
+	Source     TaxLotSource    `json:"source"`
+	ImportID   *uuid.UUID      `json:"import_id,omitempty"`
+	// Adjusted is set once an admin has corrected the lot, typically to the
+	// custodian's final figures after the transfer settles
+	Adjusted  bool      `json:"adjusted"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaxLotAdjustment records an admin's correction of a tax lot
+type TaxLotAdjustment struct {
+	ID        uuid.UUID `json:"id"`
+	LotID     uuid.UUID `json:"lot_id"`
+	AdminID   uuid.UUID `json:"admin_id"`
+	Before    TaxLot    `json:"before"`
+	After     TaxLot    `json:"after"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CostBasisImportStatus is where an import is in review
+type CostBasisImportStatus string
+
+const (
+	CostBasisImportPendingReview CostBasisImportStatus = "pending_review"
+	CostBasisImportConfirmed     CostBasisImportStatus = "confirmed"
+	CostBasisImportCancelled     CostBasisImportStatus = "cancelled"
+	CostBasisImportExpired       CostBasisImportStatus = "expired" // derived from ExpiresAt, never stored
+)
+
+// CostBasisImport is a broker CSV export parsed into lots and held for the
+// user to review. Nothing is recorded until the user confirms it.
+type CostBasisImport struct {
+	ID          uuid.UUID             `json:"id"`
+	UserID      uuid.UUID             `json:"-"`
+	Filename    string                `json:"filename"`
+	Status      CostBasisImportStatus `json:"status"`
+	Lots        []ImportedLot         `json:"lots"`
+	Errors      []CostBasisRowError   `json:"errors"`
+	ExpiresAt   time.Time             `json:"expires_at"`
+	ConfirmedAt *time.Time            `json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+// State returns the import's status, reporting an unconfirmed import past
+// its review window as expired
+func (i *CostBasisImport) State(now time.Time) CostBasisImportStatus {
+	if i.Status == CostBasisImportPendingReview && !now.Before(i.ExpiresAt) {
+		return CostBasisImportExpired
+	}
+	return i.Status
+}
+
+// ImportedLot is a lot read from one row of an import
+type ImportedLot struct {
+	Row        int             `json:"row"`
+	Symbol     string          `json:"symbol"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	CostBasis  decimal.Decimal `json:"cost_basis"`
+	AcquiredAt time.Time       `json:"acquired_at"`
+}
+
+// CostBasisRowError explains why a row of an import was not read. Row 0
+// is the header.
+type CostBasisRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// AdjustTaxLotRequest corrects a tax lot. Unset fields keep their value.
+type AdjustTaxLotRequest struct {
+	Quantity   *decimal.Decimal `json:"quantity,omitempty"`
+	CostBasis  *decimal.Decimal `json:"cost_basis,omitempty"`
+	AcquiredAt *time.Time       `json:"acquired_at,omitempty"`
+	Reason     string           `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MinimumAdultAge is the age at which a user may hold a self-directed account
+const MinimumAdultAge = 18
+
+// Age verification errors
+var (
+	ErrDateOfBirthRequired = errors.New("date of birth is required")
+	ErrUnderage            = errors.New("user is under the minimum age for a self-directed account")
+	ErrCustodialNotFound   = errors.New("custodial account not found")
+	ErrNotYetMajority      = errors.New("beneficiary has not reached the age of majority")
+)
+
+// AgeOn returns a person's age in whole years on the given date
+func AgeOn(dateOfBirth, on time.Time) int {
+	dob := dateOfBirth.UTC()
+	on = on.UTC()
+	age := on.Year() - dob.Year()
+	if on.Month() < dob.Month() || (on.Month() == dob.Month() && on.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+// IsAdult reports whether a person is at least MinimumAdultAge on the given date
+func IsAdult(dateOfBirth, on time.Time) bool {
+	return AgeOn(dateOfBirth, on) >= MinimumAdultAge
+}
+
+// MajorityDate returns the date a person reaches MinimumAdultAge
+func MajorityDate(dateOfBirth time.Time) time.Time {
+	dob := dateOfBirth.UTC()
+	return time.Date(dob.Year()+MinimumAdultAge, dob.Month(), dob.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CustodialAccountStatus tracks a custodial account through to transfer at majority
+type CustodialAccountStatus string
+
+const (
+	CustodialStatusActive          CustodialAccountStatus = "active"
+	CustodialStatusTransferPending CustodialAccountStatus = "transfer_pending"
+	CustodialStatusTransferred     CustodialAccountStatus = "transferred"
+	CustodialStatusClosed          CustodialAccountStatus = "closed"
+)
+
+// CustodialAccount is an account held by a KYC-verified guardian for a minor.
+// The guardian manages it until the beneficiary reaches majority and claims it.
+type CustodialAccount struct {
+	ID                   uuid.UUID              `json:"id" db:"id"`
+	GuardianUserID       uuid.UUID              `json:"guardian_user_id" db:"guardian_user_id"`
+	BeneficiaryFirstName string                 `json:"beneficiary_first_name" db:"beneficiary_first_name"`
+	BeneficiaryLastName  string                 `json:"beneficiary_last_name" db:"beneficiary_last_name"`
+	BeneficiaryDOB       time.Time              `json:"beneficiary_date_of_birth" db:"beneficiary_date_of_birth"`
+	Relationship         string                 `json:"relationship" db:"relationship"`
+	Status               CustodialAccountStatus `json:"status" db:"status"`
+	MajorityDate         time.Time              `json:"majority_date" db:"majority_date"`
+	TransferEmail        *string                `json:"transfer_email,omitempty" db:"transfer_email"`
+	TransferInitiatedAt  *time.Time             `json:"transfer_initiated_at,omitempty" db:"transfer_initiated_at"`
+	BeneficiaryUserID    *uuid.UUID             `json:"beneficiary_user_id,omitempty" db:"beneficiary_user_id"`
+	TransferredAt        *time.Time             `json:"transferred_at,omitempty" db:"transferred_at"`
+	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCustodialAccountRequest opens a custodial account for a minor
+type CreateCustodialAccountRequest struct {
+	BeneficiaryFirstName string    `json:"beneficiaryFirstName" binding:"required"`
+	BeneficiaryLastName  string    `json:"beneficiaryLastName" binding:"required"`
+	BeneficiaryDOB       time.Time `json:"beneficiaryDateOfBirth" binding:"required"`
+	Relationship         string    `json:"relationship" binding:"required,oneof=parent legal_guardian"`
+}
+
+// InitiateCustodialTransferRequest starts transfer of ownership to the beneficiary
+type InitiateCustodialTransferRequest struct {
+	BeneficiaryEmail string `json:"beneficiaryEmail" binding:"required,email"`
+}
@@ -0,0 +1,142 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delegated access errors
+var (
+	ErrDelegateGrantNotFound = errors.New("delegate access grant not found")
+	// ErrDelegateSessionInvalid is returned for an unknown or expired
+	// delegate session, or one whose grant has been revoked
+	ErrDelegateSessionInvalid = errors.New("delegate session invalid or expired")
+)
+
+// DelegateScope is an area of the account a delegate may view
+type DelegateScope string
+
+const (
+	DelegateScopePortfolio  DelegateScope = "portfolio"  // holdings and their value
+	DelegateScopeStatements DelegateScope = "statements" // trade confirmations
+)
+
+// IsValid reports whether the scope is known
+func (s DelegateScope) IsValid() bool {
+	return s == DelegateScopePortfolio || s == DelegateScopeStatements
+}
+
+// DelegateRelationship is who the delegate is to the account holder
+type DelegateRelationship string
+
+const (
+	DelegateRelationshipAdvisor DelegateRelationship = "advisor"
+	DelegateRelationshipFamily  DelegateRelationship = "family"
+	DelegateRelationshipOther   DelegateRelationship = "other"
+)
+
+// DelegateGrantStatus is the state of a delegate access grant
+type DelegateGrantStatus string
+
+const (
+	DelegateGrantActive  DelegateGrantStatus = "active"
+	DelegateGrantRevoked DelegateGrantStatus = "revoked"
+	DelegateGrantExpired DelegateGrantStatus = "expired" // derived from ExpiresAt, never stored
+)
+
+// DelegateGrant gives someone outside the account read-only access to parts
+// of it. The delegate signs in with the grant's token, which is shown once
+// when the grant is created and stored only as a hash.
+type DelegateGrant struct {
+	ID            uuid.UUID            `json:"id"`
+	UserID        uuid.UUID            `json:"-"`
+	DelegateName  string               `json:"delegate_name"`
+	DelegateEmail string               `json:"delegate_email"`
+	Relationship  DelegateRelationship `json:"relationship"`
+	Scopes        []DelegateScope      `json:"scopes"`
+	TokenHash     string               `json:"-"`
+	TokenPrefix   string               `json:"token_prefix"`
+	Status        DelegateGrantStatus  `json:"status"`
+	ExpiresAt     time.Time            `json:"expires_at"`
+	LastUsedAt    *time.Time           `json:"last_used_at,omitempty"`
+	RevokedAt     *time.Time           `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+// State returns the grant's status at now, reporting an active grant past
+// its expiry as expired
+func (g *DelegateGrant) State(now time.Time) DelegateGrantStatus {
+	if g.Status == DelegateGrantActive && !now.Before(g.ExpiresAt) {
+		return DelegateGrantExpired
+	}
+	return g.Status
+}
+
+// Allows reports whether the grant covers the scope
+func (g *DelegateGrant) Allows(scope DelegateScope) bool {
+	for _, granted := range g.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// DelegateSession is a delegate's short-lived sign-in under a grant
+type DelegateSession struct {
+	ID        uuid.UUID `json:"-"`
+	GrantID   uuid.UUID `json:"-"`
+	TokenHash string    `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"-"`
+}
+
+// DelegateAccessEvent records a delegate viewing part of the account
+type DelegateAccessEvent struct {
+	ID         uuid.UUID     `json:"id"`
+	GrantID    uuid.UUID     `json:"grant_id"`
+	UserID     uuid.UUID     `json:"-"`
+	Scope      DelegateScope `json:"scope"`
+	Resource   string        `json:"resource"`
+	ResourceID *string       `json:"resource_id,omitempty"`
+	IPAddress  string        `json:"ip_address,omitempty"`
+	UserAgent  string        `json:"user_agent,omitempty"`
+	ViewedAt   time.Time     `json:"viewed_at"`
+}
+
+// CreateDelegateGrantRequest grants read-only access to a delegate
+type CreateDelegateGrantRequest struct {
+	DelegateName  string               `json:"delegate_name" binding:"required,max=100"`
+	DelegateEmail string               `json:"delegate_email" binding:"required,email"`
+	Relationship  DelegateRelationship `json:"relationship" binding:"required,oneof=advisor family other"`
+	Scopes        []DelegateScope      `json:"scopes" binding:"required,min=1"`
+	// ExpiresInDays defaults to, and may not exceed, the configured maximum
+	ExpiresInDays int `json:"expires_in_days,omitempty" binding:"omitempty,min=1"`
+}
+
+// CreateDelegateGrantResponse carries the new grant and its token, which is
+// not shown again
+type CreateDelegateGrantResponse struct {
+	Grant *DelegateGrant `json:"grant"`
+	Token string         `json:"token"`
+}
+
+// DelegateLoginRequest signs a delegate in with the grant token they were
+// given and the email it was issued to
+type DelegateLoginRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Token string `json:"token" binding:"required"`
+}
+
+// DelegateLoginResponse carries a delegate session token, accepted only by
+// the delegate endpoints
+type DelegateLoginResponse struct {
+	AccessToken  string          `json:"access_token"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	Scopes       []DelegateScope `json:"scopes"`
+	GrantExpires time.Time       `json:"grant_expires_at"`
+}
@@ -0,0 +1,97 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Deposit reference errors
+var (
+	ErrNoSharedDepositAccount = errors.New("no shared deposit account for currency")
+	ErrBankCreditNotFound     = errors.New("bank credit not found")
+	ErrBankCreditNotUnmatched = errors.New("bank credit is not awaiting a match")
+)
+
+// BankCreditStatus is where a credit into a shared account is in attribution
+type BankCreditStatus string
+
+const (
+	BankCreditStatusUnmatched BankCreditStatus = "unmatched"
+	BankCreditStatusCrediting BankCreditStatus = "crediting" // Claimed while the user is credited
+	BankCreditStatusCredited  BankCreditStatus = "credited"
+	BankCreditStatusReturned  BankCreditStatus = "returned" // Sent back to the sender outside the platform
+)
+
+// How a credit was attributed to its user
+const (
+	BankCreditMatchReference = "reference"
+	BankCreditMatchManual    = "manual"
+)
+
+// Why a credit could not be attributed automatically
+const (
+	BankCreditNoReference       = "no_reference"        // The memo has nothing that looks like a reference
+	BankCreditInvalidReference  = "invalid_reference"   // A reference-like code failed its check character
+	BankCreditUnknownReference  = "unknown_reference"   // A valid code that was never issued
+	BankCreditMultipleReference = "multiple_references" // Codes of more than one user
+	BankCreditUnknownAccount    = "unknown_account"     // Not a configured shared account
+)
+
+// DepositReference is the code a user puts in the memo of transfers into a
+// shared account. Each user has one, for every currency.
+type DepositReference struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FundingInstructions tell a user how to send a bank transfer. When
+// ReferenceRequired is set the account is shared and the transfer is only
+// credited automatically if Reference is in its memo.
+type FundingInstructions struct {
+	Currency          string `json:"currency"`
+	BankName          string `json:"bank_name"`
+	AccountName       string `json:"account_name"`
+	AccountNumber     string `json:"account_number"`
+	RoutingNumber     string `json:"routing_number,omitempty"`
+	Reference         string `json:"reference"`
+	ReferenceRequired bool   `json:"reference_required"`
+}
+
+// BankCredit is one incoming transfer into a shared account
+type BankCredit struct {
+	ID                  uuid.UUID        `json:"id"`
+	ProviderRef         string           `json:"provider_ref"`
+	AccountNumber       string           `json:"account_number"`
+	Currency            string           `json:"currency"`
+	Amount              decimal.Decimal  `json:"amount"`
+	Memo                string           `json:"memo"`
+	SenderName          string           `json:"sender_name"`
+	Status              BankCreditStatus `json:"status"`
+	UserID              *uuid.UUID       `json:"user_id,omitempty"`
+	Reference           *string          `json:"reference,omitempty"`
+	MatchMethod         *string          `json:"match_method,omitempty"`
+	UnmatchedReason     *string          `json:"unmatched_reason,omitempty"`
+	MatchedBy           *uuid.UUID       `json:"matched_by,omitempty"`
+	MatchedAt           *time.Time       `json:"matched_at,omitempty"`
+	Note                *string          `json:"note,omitempty"`
+	LedgerTransactionID *uuid.UUID       `json:"ledger_transaction_id,omitempty"`
+	ReceivedAt          time.Time        `json:"received_at"`
+	CreatedAt           time.Time        `json:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at"`
+}
+
+// BankCreditWebhook is the notification of a credit into a shared account
+type BankCreditWebhook struct {
+	ProviderRef   string          `json:"provider_ref" binding:"required"`
+	AccountNumber string          `json:"account_number" binding:"required"`
+	Currency      string          `json:"currency" binding:"required"`
+	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	Memo          string          `json:"memo"`
+	SenderName    string          `json:"sender_name"`
+	ReceivedAt    *time.Time      `json:"received_at,omitempty"`
+}
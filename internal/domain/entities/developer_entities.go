@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Developer program errors
+var (
+	ErrDeveloperTenantNotFound = errors.New("developer tenant not found")
+	ErrDeveloperTenantExists   = errors.New("developer tenant already exists")
+)
+
+// DeveloperEnvironmentSandbox is the only environment partner developers can
+// create keys for themselves; live keys are still issued by us
+const DeveloperEnvironmentSandbox = "sandbox"
+
+// DeveloperTenant is a partner developer's account in the developer program.
+// It owns the API keys and webhook endpoints they create.
+type DeveloperTenant struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateDeveloperTenantRequest joins the developer program
+type CreateDeveloperTenantRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// IssueDeveloperKeyRequest asks for a sandbox API key with the given scopes
+type IssueDeveloperKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
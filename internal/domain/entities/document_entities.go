@@ -0,0 +1,95 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Document center errors
+var (
+	ErrDocumentNotFound = errors.New("document not found")
+	ErrInvalidDocument  = errors.New("invalid document")
+	// ErrDocumentExists is returned when an order already has its trade
+	// confirmation
+	ErrDocumentExists = errors.New("document already exists")
+)
+
+// DocumentCategory groups documents in the document center
+type DocumentCategory string
+
+const (
+	DocumentCategoryTradeConfirmation DocumentCategory = "trade_confirmation"
+	DocumentCategoryProspectus        DocumentCategory = "prospectus"
+	DocumentCategoryFeeDisclosure     DocumentCategory = "fee_disclosure"
+	DocumentCategoryAgreement         DocumentCategory = "agreement"
+	DocumentCategoryDisclosure        DocumentCategory = "disclosure"
+)
+
+// IsValid reports whether the category is known
+func (c DocumentCategory) IsValid() bool {
+	switch c {
+	case DocumentCategoryTradeConfirmation, DocumentCategoryProspectus, DocumentCategoryFeeDisclosure,
+		DocumentCategoryAgreement, DocumentCategoryDisclosure:
+		return true
+	}
+	return false
+}
+
+// IsPublishable reports whether admins publish documents of the category.
+// Trade confirmations are generated from fills, and agreements come from
+// the versions published through consents.
+func (c DocumentCategory) IsPublishable() bool {
+	return c == DocumentCategoryProspectus || c == DocumentCategoryFeeDisclosure || c == DocumentCategoryDisclosure
+}
+
+// Document is an item in a user's document center: either a link, such as a
+// prospectus, or a generated body, such as a trade confirmation
+type Document struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      *uuid.UUID       `json:"user_id,omitempty"` // nil for documents every user sees
+	Category    DocumentCategory `json:"category"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	URL         string           `json:"url,omitempty"`
+	Body        string           `json:"body,omitempty"` // left out of listings
+	ContentType string           `json:"content_type"`
+	Mandatory   bool             `json:"mandatory"` // the user must open it
+	OrderID     *uuid.UUID       `json:"order_id,omitempty"`
+	PublishedBy *uuid.UUID       `json:"published_by,omitempty"`
+	PublishedAt time.Time        `json:"published_at"`
+	// StorageRegion is where the document is stored; empty for documents
+	// from before residency tagging, which are in the home region
+	StorageRegion ResidencyRegion `json:"storage_region,omitempty"`
+	// ReadAt is when the requesting user first opened the document, or
+	// accepted the agreement version
+	ReadAt *time.Time `json:"read_at,omitempty"`
+}
+
+// DocumentReceipt records a user first opening a document
+type DocumentReceipt struct {
+	DocumentID uuid.UUID `json:"document_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	ReadAt     time.Time `json:"read_at"`
+}
+
+// DocumentFilter narrows a document listing
+type DocumentFilter struct {
+	Category DocumentCategory
+	Limit    int
+	Offset   int
+}
+
+// PublishDocumentRequest publishes a linked document, to every user or to
+// one user
+type PublishDocumentRequest struct {
+	Category    DocumentCategory `json:"category" binding:"required"`
+	Title       string           `json:"title" binding:"required,max=255"`
+	Description string           `json:"description"`
+	URL         string           `json:"url" binding:"required,url"`
+	Mandatory   bool             `json:"mandatory"`
+	UserID      *uuid.UUID       `json:"user_id,omitempty"`
+}
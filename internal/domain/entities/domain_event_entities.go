@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event bus topics. Payloads are the structs below; publishers key messages
+// by user ID so each user's events are consumed in order.
+const (
+	TopicDepositConfirmed      = "funding.deposit_confirmed"
+	TopicDepositReversed       = "funding.deposit_reversed"
+	TopicNotificationRequested = "notifications.requested"
+	TopicOnboardingChanged     = "onboarding.status_changed"
+)
+
+// DepositConfirmedEvent is published once a chain deposit is credited
+type DepositConfirmedEvent struct {
+	DepositID   uuid.UUID  `json:"deposit_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Chain       Chain      `json:"chain"`
+	Token       Stablecoin `json:"token"`
+	Amount      string     `json:"amount"`
+	USDAmount   string     `json:"usd_amount"`
+	TxHash      string     `json:"tx_hash"`
+	Status      string     `json:"status"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+}
+
+// DepositReversedEvent is published when a credited chain deposit is reversed
+// because its transaction was dropped
+type DepositReversedEvent struct {
+	DepositID  uuid.UUID  `json:"deposit_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Chain      Chain      `json:"chain"`
+	Token      Stablecoin `json:"token"`
+	Amount     string     `json:"amount"`
+	TxHash     string     `json:"tx_hash"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason"`
+	ReversedAt time.Time  `json:"reversed_at"`
+}
+
+// NotificationRequest asks the dispatcher to deliver a notification
+type NotificationRequest struct {
+	Notification *Notification   `json:"notification"`
+	Preferences  *UserPreference `json:"preferences,omitempty"`
+}
+
+// OnboardingChangeKind is the part of a user's onboarding state that changed
+type OnboardingChangeKind string
+
+const (
+	OnboardingChangeOnboarding OnboardingChangeKind = "onboarding"
+	OnboardingChangeKYC        OnboardingChangeKind = "kyc"
+	OnboardingChangeWallet     OnboardingChangeKind = "wallet"
+)
+
+// OnboardingChangedEvent is published for every onboarding, KYC and wallet
+// status change, read from the onboarding outbox. EventID is the outbox row
+// ID and stays the same if the relay publishes the row again, so consumers
+// dedupe on it. Sequence increases with every change; a consumer ignores an
+// event whose sequence is lower than the last one it applied for the user.
+type OnboardingChangedEvent struct {
+	EventID    uuid.UUID            `json:"event_id"`
+	Sequence   int64                `json:"sequence"`
+	UserID     uuid.UUID            `json:"user_id"`
+	Kind       OnboardingChangeKind `json:"kind"`
+	From       string               `json:"from,omitempty"`
+	To         string               `json:"to"`
+	Chain      string               `json:"chain,omitempty"`
+	OccurredAt time.Time            `json:"occurred_at"`
+}
+
+// OnboardingOutboxEntry is a recorded state change waiting to be published
+type OnboardingOutboxEntry struct {
+	ID            uuid.UUID            `json:"id" db:"id"`
+	Sequence      int64                `json:"sequence" db:"sequence"`
+	UserID        uuid.UUID            `json:"user_id" db:"user_id"`
+	Kind          OnboardingChangeKind `json:"kind" db:"kind"`
+	FromStatus    *string              `json:"from_status,omitempty" db:"from_status"`
+	ToStatus      string               `json:"to_status" db:"to_status"`
+	Chain         *string              `json:"chain,omitempty" db:"chain"`
+	OccurredAt    time.Time            `json:"occurred_at" db:"occurred_at"`
+	Attempts      int                  `json:"attempts" db:"attempts"`
+	LastError     *string              `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time            `json:"next_attempt_at" db:"next_attempt_at"`
+	PublishedAt   *time.Time           `json:"published_at,omitempty" db:"published_at"`
+}
+
+// Event returns the bus payload for the entry
+func (e *OnboardingOutboxEntry) Event() OnboardingChangedEvent {
+	event := OnboardingChangedEvent{
+		EventID:    e.ID,
+		Sequence:   e.Sequence,
+		UserID:     e.UserID,
+		Kind:       e.Kind,
+		To:         e.ToStatus,
+		OccurredAt: e.OccurredAt,
+	}
+	if e.FromStatus != nil {
+		event.From = *e.FromStatus
+	}
+	if e.Chain != nil {
+		event.Chain = *e.Chain
+	}
+	return event
+}
@@ -0,0 +1,112 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Enhanced due diligence errors
+var (
+	ErrEDDSubmissionNotFound = errors.New("enhanced due diligence submission not found")
+	ErrEDDUnavailable        = errors.New("higher limits are not available for this account yet")
+	ErrEDDRequiresKYC        = errors.New("identity verification must be approved before requesting higher limits")
+	ErrEDDAlreadyEnhanced    = errors.New("account already has enhanced limits")
+	ErrEDDNotPending         = errors.New("enhanced due diligence submission is not awaiting review")
+	ErrInvalidEDDDocument    = errors.New("invalid enhanced due diligence document")
+	ErrEDDProofOfFunds       = errors.New("a proof of funds document is required")
+	// ErrEDDProviderUnsupported is returned by KYC providers that cannot run
+	// enhanced checks
+	ErrEDDProviderUnsupported = errors.New("kyc provider does not support enhanced due diligence")
+)
+
+// KYCTier is the verification level a user's limits are set by
+type KYCTier string
+
+const (
+	KYCTierStandard KYCTier = "standard"
+	// KYCTierEnhanced accounts passed enhanced due diligence
+	KYCTierEnhanced KYCTier = "enhanced"
+)
+
+// Enhanced due diligence document types
+const (
+	EDDDocumentProofOfFunds   = "proof_of_funds"
+	EDDDocumentBankStatement  = "bank_statement"
+	EDDDocumentSourceOfWealth = "source_of_wealth"
+)
+
+// IsEDDDocumentType reports whether a document type is accepted for enhanced
+// due diligence
+func IsEDDDocumentType(docType string) bool {
+	switch docType {
+	case EDDDocumentProofOfFunds, EDDDocumentBankStatement, EDDDocumentSourceOfWealth:
+		return true
+	default:
+		return false
+	}
+}
+
+// TierLimit is one transaction limit granted by a KYC tier
+type TierLimit struct {
+	LimitType LimitType       `json:"limit_type"`
+	Period    LimitPeriod     `json:"period"`
+	MaxAmount decimal.Decimal `json:"max_amount"`
+}
+
+// AccountKYCTier is the KYC approval state and tier of a user account
+type AccountKYCTier struct {
+	UserID         uuid.UUID `json:"user_id" db:"id"`
+	KYCStatus      KYCStatus `json:"kyc_status" db:"kyc_status"`
+	KYCProviderRef *string   `json:"kyc_provider_ref,omitempty" db:"kyc_provider_ref"`
+	Tier           KYCTier   `json:"tier" db:"kyc_tier"`
+}
+
+// EDDStatus tracks an enhanced due diligence submission
+type EDDStatus string
+
+const (
+	EDDPendingReview EDDStatus = "pending_review"
+	EDDApproved      EDDStatus = "approved"
+	EDDRejected      EDDStatus = "rejected"
+)
+
+// EDDSubmission is a user's request for higher limits. The proof-of-funds
+// documents are forwarded to the KYC provider when it supports enhanced
+// checks, and compliance decides through the admin case queue.
+type EDDSubmission struct {
+	ID              uuid.UUID           `json:"id" db:"id"`
+	UserID          uuid.UUID           `json:"user_id" db:"user_id"`
+	Status          EDDStatus           `json:"status" db:"status"`
+	SourceOfFunds   string              `json:"source_of_funds" db:"source_of_funds"`
+	Documents       []KYCDocumentUpload `json:"documents" db:"documents"`
+	CaseID          *uuid.UUID          `json:"case_id,omitempty" db:"case_id"`
+	ProviderRef     *string             `json:"provider_ref,omitempty" db:"provider_ref"`
+	RejectionReason *string             `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	ReviewedBy      *uuid.UUID          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNotes     *string             `json:"review_notes,omitempty" db:"review_notes"`
+	ReviewedAt      *time.Time          `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// EDDOverview is what a user sees about higher limits: whether they can
+// apply, their current tier and their latest submission
+type EDDOverview struct {
+	Available  bool           `json:"available"`
+	Tier       KYCTier        `json:"tier"`
+	Submission *EDDSubmission `json:"submission,omitempty"`
+}
+
+// SubmitEDDRequest asks for higher limits with supporting documents
+type SubmitEDDRequest struct {
+	SourceOfFunds string              `json:"sourceOfFunds" binding:"required,max=1000"`
+	Documents     []KYCDocumentUpload `json:"documents" binding:"required,min=1,max=10"`
+}
+
+// ReviewEDDRequest is compliance's decision on a submission
+type ReviewEDDRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreamEventType identifies a progress event pushed to a user's live event stream
+type StreamEventType string
+
+const (
+	StreamEventOrderUpdated   StreamEventType = "order.updated"
+	StreamEventDepositUpdated StreamEventType = "deposit.updated"
+	StreamEventOnboarding     StreamEventType = "onboarding.updated"
+)
+
+// StreamEvent is one entry in a user's live event stream. ID is the stream
+// cursor clients echo back as Last-Event-ID to resume after a disconnect.
+type StreamEvent struct {
+	ID        string          `json:"id"`
+	Type      StreamEventType `json:"type"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// OrderProgressEvent is the payload of order.updated
+type OrderProgressEvent struct {
+	OrderID  uuid.UUID       `json:"order_id"`
+	BasketID uuid.UUID       `json:"basket_id"`
+	Side     OrderSide       `json:"side"`
+	Amount   string          `json:"amount"`
+	Status   OrderStatus     `json:"status"`
+	Fills    []BrokerageFill `json:"fills,omitempty"`
+}
+
+// DepositProgressEvent is the payload of deposit.updated
+type DepositProgressEvent struct {
+	DepositID uuid.UUID  `json:"deposit_id"`
+	Chain     Chain      `json:"chain"`
+	Token     Stablecoin `json:"token"`
+	Amount    string     `json:"amount"`
+	Status    string     `json:"status"`
+	TxHash    string     `json:"tx_hash"`
+}
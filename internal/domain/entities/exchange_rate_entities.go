@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Exchange rate errors
+var (
+	ErrRateNotFound        = errors.New("no exchange rate recorded near that time")
+	ErrInvalidCurrencyPair = errors.New("invalid currency pair")
+)
+
+// Exchange rate sources
+const (
+	RateSourceDeposit    = "deposit"    // Rate applied when a deposit was credited
+	RateSourceConversion = "conversion" // Rate a treasury conversion settled at
+	RateSourceBackfill   = "backfill"   // Historical rate loaded from the rate provider
+)
+
+// CurrencyPair names the price of one unit of Base in Quote, e.g. USDC/USD
+type CurrencyPair struct {
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+}
+
+// ParseCurrencyPair parses a pair written as BASE/QUOTE
+func ParseCurrencyPair(value string) (CurrencyPair, error) {
+	base, quote, ok := strings.Cut(value, "/")
+	pair := NewCurrencyPair(base, quote)
+	if !ok || pair.Base == "" || pair.Quote == "" {
+		return CurrencyPair{}, fmt.Errorf("%w: %q", ErrInvalidCurrencyPair, value)
+	}
+	return pair, nil
+}
+
+// NewCurrencyPair returns the pair with normalized currency codes
+func NewCurrencyPair(base, quote string) CurrencyPair {
+	return CurrencyPair{
+		Base:  strings.ToUpper(strings.TrimSpace(base)),
+		Quote: strings.ToUpper(strings.TrimSpace(quote)),
+	}
+}
+
+// Inverse returns the pair priced the other way round
+func (p CurrencyPair) Inverse() CurrencyPair {
+	return CurrencyPair{Base: p.Quote, Quote: p.Base}
+}
+
+func (p CurrencyPair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// ExchangeRate is the price of a currency pair observed at a point in time
+type ExchangeRate struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	Pair          CurrencyPair    `json:"pair"`
+	Rate          decimal.Decimal `json:"rate" db:"rate"`
+	ObservedAt    time.Time       `json:"observed_at" db:"observed_at"`
+	Source        string          `json:"source" db:"source"`
+	ReferenceType *string         `json:"reference_type,omitempty" db:"reference_type"`
+	ReferenceID   *uuid.UUID      `json:"reference_id,omitempty" db:"reference_id"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// RateBackfillRequest loads historical rates for a pair over a period
+type RateBackfillRequest struct {
+	Pair string    `json:"pair" binding:"required"` // BASE/QUOTE, e.g. USDC/USD
+	From time.Time `json:"from" binding:"required"`
+	To   time.Time `json:"to" binding:"required"`
+}
+
+// RateBackfillResult reports how many historical rates a backfill stored
+type RateBackfillResult struct {
+	Pair    string    `json:"pair"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Fetched int       `json:"fetched"`
+	Stored  int       `json:"stored"`
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Experiment errors
+var (
+	ErrExperimentNotFound  = errors.New("experiment not found")
+	ErrExperimentExists    = errors.New("an experiment with this key already exists")
+	ErrExperimentConcluded = errors.New("experiment has already been concluded")
+)
+
+// ExperimentStatus is the lifecycle state of an experiment
+type ExperimentStatus string
+
+const (
+	ExperimentStatusRunning   ExperimentStatus = "running"   // new users are bucketed across the variants
+	ExperimentStatusConcluded ExperimentStatus = "concluded" // assignment is frozen and new users get the winner
+)
+
+// ExperimentEventKind is what happened to a user in an experiment
+type ExperimentEventKind string
+
+const (
+	ExperimentEventExposure   ExperimentEventKind = "exposure"   // the user was shown their variant
+	ExperimentEventConversion ExperimentEventKind = "conversion" // the user reached a goal
+)
+
+// ExperimentGoalOnboardingCompleted is the conversion goal of onboarding experiments
+const ExperimentGoalOnboardingCompleted = "onboarding_completed"
+
+// ExperimentVariant is one arm of an experiment. Weight is its share of new
+// users relative to the other variants.
+type ExperimentVariant struct {
+	Key    string `json:"key" binding:"required,max=100"`
+	Weight int    `json:"weight" binding:"min=0"`
+}
+
+// Experiment is a product experiment on the onboarding flow, such as KYC-first
+// against wallet-first
+type Experiment struct {
+	ID               uuid.UUID           `json:"id" db:"id"`
+	Key              string              `json:"key" db:"key"`
+	Description      string              `json:"description" db:"description"`
+	Variants         []ExperimentVariant `json:"variants" db:"variants"`
+	Status           ExperimentStatus    `json:"status" db:"status"`
+	WinningVariant   *string             `json:"winning_variant,omitempty" db:"winning_variant"`
+	ConclusionReason *string             `json:"conclusion_reason,omitempty" db:"conclusion_reason"`
+	CreatedBy        *uuid.UUID          `json:"created_by,omitempty" db:"created_by"`
+	ConcludedBy      *uuid.UUID          `json:"concluded_by,omitempty" db:"concluded_by"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	ConcludedAt      *time.Time          `json:"concluded_at,omitempty" db:"concluded_at"`
+	// Results are the per-variant counts, filled in for admins
+	Results []*ExperimentVariantResult `json:"results,omitempty" db:"-"`
+}
+
+// HasVariant reports whether key is one of the experiment's variants
+func (e *Experiment) HasVariant(key string) bool {
+	for _, variant := range e.Variants {
+		if variant.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ExperimentVariantResult counts the users assigned to a variant, how many
+// were exposed to it and how many reached each goal
+type ExperimentVariantResult struct {
+	Variant     string           `json:"variant"`
+	Assigned    int64            `json:"assigned"`
+	Exposed     int64            `json:"exposed"`
+	Conversions map[string]int64 `json:"conversions"`
+}
+
+// ExperimentAssignment is the variant a user was bucketed into
+type ExperimentAssignment struct {
+	ExperimentID uuid.UUID `json:"experiment_id" db:"experiment_id"`
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	Variant      string    `json:"variant" db:"variant"`
+	AssignedAt   time.Time `json:"assigned_at" db:"assigned_at"`
+}
+
+// CreateExperimentRequest starts an experiment
+type CreateExperimentRequest struct {
+	Key         string              `json:"key" binding:"required,max=100"`
+	Description string              `json:"description" binding:"max=1000"`
+	Variants    []ExperimentVariant `json:"variants" binding:"required,min=2,dive"`
+}
+
+// ConcludeExperimentRequest ends an experiment. Users already in it keep
+// their variant; everyone after gets the winner.
+type ConcludeExperimentRequest struct {
+	WinningVariant string `json:"winning_variant" binding:"required"`
+	Reason         string `json:"reason" binding:"required,max=500"`
+}
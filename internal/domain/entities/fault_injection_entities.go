@@ -0,0 +1,81 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FaultProvider is an external API whose outbound calls can have faults
+// injected
+type FaultProvider string
+
+const (
+	FaultProviderCircle FaultProvider = "circle"
+	FaultProviderDue    FaultProvider = "due"
+	FaultProviderAlpaca FaultProvider = "alpaca"
+)
+
+// IsValid reports whether the provider's client supports fault injection
+func (p FaultProvider) IsValid() bool {
+	switch p {
+	case FaultProviderCircle, FaultProviderDue, FaultProviderAlpaca:
+		return true
+	}
+	return false
+}
+
+// FaultType is what happens to a matching outbound call
+type FaultType string
+
+const (
+	// FaultLatency delays the call before sending it on
+	FaultLatency FaultType = "latency"
+	// FaultError answers with StatusCode without calling the provider, or
+	// fails as a network error when StatusCode is 0
+	FaultError FaultType = "error"
+	// FaultMalformed sends the call and truncates the provider's response
+	// body so it no longer decodes
+	FaultMalformed FaultType = "malformed"
+)
+
+// IsValid reports whether the fault type is known
+func (f FaultType) IsValid() bool {
+	switch f {
+	case FaultLatency, FaultError, FaultMalformed:
+		return true
+	}
+	return false
+}
+
+// FaultRule injects a fault into a share of one provider's calls until it
+// expires. Method and PathPrefix narrow it to an endpoint; empty matches
+// every call.
+type FaultRule struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	Provider   FaultProvider `json:"provider" db:"provider"`
+	Method     string        `json:"method,omitempty" db:"method"`
+	PathPrefix string        `json:"path_prefix,omitempty" db:"path_prefix"`
+	Fault      FaultType     `json:"fault" db:"fault"`
+	LatencyMs  int           `json:"latency_ms,omitempty" db:"latency_ms"`
+	StatusCode int           `json:"status_code,omitempty" db:"status_code"`
+	Percent    float64       `json:"percent" db:"percent"` // Share of matching calls affected, 0-100
+	Note       string        `json:"note" db:"note"`
+	CreatedBy  uuid.UUID     `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	ExpiresAt  time.Time     `json:"expires_at" db:"expires_at"`
+}
+
+// CreateFaultRuleRequest starts injecting a fault. Percent defaults to 100
+// and TTLMinutes to the configured default.
+type CreateFaultRuleRequest struct {
+	Provider   FaultProvider `json:"provider" binding:"required"`
+	Method     string        `json:"method" binding:"omitempty,oneof=GET POST PUT PATCH DELETE"`
+	PathPrefix string        `json:"path_prefix" binding:"max=200"`
+	Fault      FaultType     `json:"fault" binding:"required"`
+	LatencyMs  int           `json:"latency_ms" binding:"omitempty,min=1,max=120000"`
+	StatusCode int           `json:"status_code" binding:"omitempty,min=400,max=599"`
+	Percent    float64       `json:"percent" binding:"omitempty,gt=0,max=100"`
+	Note       string        `json:"note" binding:"required,max=500"`
+	TTLMinutes int           `json:"ttl_minutes" binding:"omitempty,min=1,max=1440"`
+}
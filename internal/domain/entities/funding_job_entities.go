@@ -33,6 +33,7 @@ const (
 type FundingEventJob struct {
 	ID           uuid.UUID             `json:"id" db:"id"`
 	TxHash       string                `json:"tx_hash" db:"tx_hash"`
+	LogIndex     int                   `json:"log_index" db:"log_index"`
 	Chain        Chain                 `json:"chain" db:"chain"`
 	Token        Stablecoin            `json:"token" db:"token"`
 	Amount       decimal.Decimal       `json:"amount" db:"amount"`
@@ -53,6 +54,10 @@ type FundingEventJob struct {
 	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
 	MovedToDLQAt  *time.Time `json:"moved_to_dlq_at,omitempty" db:"moved_to_dlq_at"`
 
+	// Lease held by the worker processing the job, renewed by its heartbeat
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty" db:"lease_expires_at"`
+	HeartbeatAt    *time.Time `json:"heartbeat_at,omitempty" db:"heartbeat_at"`
+
 	// Metadata
 	WebhookPayload map[string]interface{} `json:"webhook_payload,omitempty" db:"webhook_payload"`
 	ProcessingLogs []ProcessingLogEntry   `json:"processing_logs,omitempty" db:"processing_logs"`
@@ -100,6 +105,7 @@ func (j *FundingEventJob) MarkCompleted() {
 	j.Status = JobStatusCompleted
 	j.CompletedAt = &now
 	j.NextRetryAt = nil
+	j.LeaseExpiresAt = nil
 	j.UpdatedAt = now
 }
 
@@ -111,6 +117,7 @@ func (j *FundingEventJob) MarkFailed(err error, errorType FundingEventErrorType,
 	j.Status = JobStatusFailed
 	j.LastError = &errMsg
 	j.ErrorType = &errorType
+	j.LeaseExpiresAt = nil
 	j.UpdatedAt = now
 
 	// Calculate next retry time if eligible
@@ -129,6 +136,15 @@ func (j *FundingEventJob) MarkFailed(err error, errorType FundingEventErrorType,
 	}
 }
 
+// TakeLease claims the job for ttl; the worker must renew the lease before it
+// runs out or the job janitor will assume the worker died
+func (j *FundingEventJob) TakeLease(ttl time.Duration) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	j.HeartbeatAt = &now
+	j.LeaseExpiresAt = &expiresAt
+}
+
 // AddProcessingLog adds a log entry for the current attempt
 func (j *FundingEventJob) AddProcessingLog(entry ProcessingLogEntry) {
 	if j.ProcessingLogs == nil {
@@ -206,6 +222,7 @@ type ReconciliationCandidate struct {
 	DepositID       uuid.UUID       `json:"deposit_id" db:"deposit_id"`
 	UserID          uuid.UUID       `json:"user_id" db:"user_id"`
 	TxHash          string          `json:"tx_hash" db:"tx_hash"`
+	LogIndex        int             `json:"log_index" db:"log_index"`
 	Chain           Chain           `json:"chain" db:"chain"`
 	Token           Stablecoin      `json:"token" db:"token"`
 	Amount          decimal.Decimal `json:"amount" db:"amount"`
@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FundingReplayMode selects whether a funding replay only reports or also
+// applies the changes it finds
+type FundingReplayMode string
+
+const (
+	FundingReplayDryRun FundingReplayMode = "dry_run"
+	FundingReplayApply  FundingReplayMode = "apply"
+)
+
+// What a replay does with one deposit
+const (
+	FundingReplayUnchanged    = "unchanged"
+	FundingReplayCredit       = "credit"
+	FundingReplayManualReview = "manual_review"
+	FundingReplaySkipped      = "skipped"
+)
+
+// FundingReplayRequest is the body of an admin funding replay
+type FundingReplayRequest struct {
+	Mode FundingReplayMode `json:"mode" binding:"required,oneof=dry_run apply"`
+}
+
+// FundingReplayEntry is the outcome of replaying one deposit. Credited amounts
+// are in USD.
+type FundingReplayEntry struct {
+	DepositID      uuid.UUID       `json:"deposit_id"`
+	Chain          Chain           `json:"chain,omitempty"`
+	TxHash         string          `json:"tx_hash,omitempty"`
+	LogIndex       int             `json:"log_index"`
+	Action         string          `json:"action"`
+	StatusBefore   string          `json:"status_before"`
+	StatusAfter    string          `json:"status_after"`
+	CreditedBefore decimal.Decimal `json:"credited_before"`
+	CreditedAfter  decimal.Decimal `json:"credited_after"`
+	Change         decimal.Decimal `json:"change"`
+	Note           string          `json:"note,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// FundingReplayReport is the diff produced by replaying a user's deposits
+// through the current pipeline. NetChange covers only changes the replay
+// makes itself; manual review entries carry their own change.
+type FundingReplayReport struct {
+	UserID            uuid.UUID             `json:"user_id"`
+	Mode              FundingReplayMode     `json:"mode"`
+	BuyingPowerBefore decimal.Decimal       `json:"buying_power_before"`
+	BuyingPowerAfter  decimal.Decimal       `json:"buying_power_after"`
+	NetChange         decimal.Decimal       `json:"net_change"`
+	Entries           []*FundingReplayEntry `json:"entries"`
+	GeneratedAt       time.Time             `json:"generated_at"`
+}
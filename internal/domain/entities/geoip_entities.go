@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Geo-IP errors
+var (
+	ErrSignupLocationBlocked = errors.New("signup location not permitted")
+	ErrGeoOverrideNotFound   = errors.New("geo override not found")
+)
+
+// GeoAccessEventType is the kind of request a geo-IP lookup was made for
+type GeoAccessEventType string
+
+const (
+	GeoAccessSignup GeoAccessEventType = "signup"
+	GeoAccessLogin  GeoAccessEventType = "login"
+)
+
+// GeoIPResult is what the geo-IP provider knows about an address
+type GeoIPResult struct {
+	IP          string `json:"ip"`
+	CountryCode string `json:"country_code"`
+	Region      string `json:"region,omitempty"`
+	City        string `json:"city,omitempty"`
+	ISP         string `json:"isp,omitempty"`
+	IsVPN       bool   `json:"is_vpn"`
+	IsProxy     bool   `json:"is_proxy"`
+	IsTor       bool   `json:"is_tor"`
+	IsHosting   bool   `json:"is_hosting"`
+	RiskScore   int    `json:"risk_score"` // 0-100, higher is riskier
+}
+
+// Masked reports whether the address hides the user's real location
+func (r *GeoIPResult) Masked() bool {
+	return r.IsVPN || r.IsProxy || r.IsTor
+}
+
+// GeoAccessEvent records where a signup or login came from
+type GeoAccessEvent struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	UserID      *uuid.UUID         `json:"user_id,omitempty" db:"user_id"` // Nil for blocked signups
+	EventType   GeoAccessEventType `json:"event_type" db:"event_type"`
+	IPAddress   string             `json:"ip_address" db:"ip_address"`
+	CountryCode string             `json:"country_code,omitempty" db:"country_code"`
+	Region      string             `json:"region,omitempty" db:"region"`
+	City        string             `json:"city,omitempty" db:"city"`
+	ISP         string             `json:"isp,omitempty" db:"isp"`
+	IsVPN       bool               `json:"is_vpn" db:"is_vpn"`
+	IsProxy     bool               `json:"is_proxy" db:"is_proxy"`
+	IsTor       bool               `json:"is_tor" db:"is_tor"`
+	IsHosting   bool               `json:"is_hosting" db:"is_hosting"`
+	RiskScore   int                `json:"risk_score" db:"risk_score"`
+	Flagged     bool               `json:"flagged" db:"flagged"`       // A masked, high-risk session
+	Blocked     bool               `json:"blocked" db:"blocked"`       // Signup refused by the country rules
+	Overridden  bool               `json:"overridden" db:"overridden"` // An admin override let the request through
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+}
+
+// Apply copies a geo-IP lookup onto the event
+func (e *GeoAccessEvent) Apply(result *GeoIPResult) {
+	e.CountryCode = NormalizeCountryCode(result.CountryCode)
+	e.Region = result.Region
+	e.City = result.City
+	e.ISP = result.ISP
+	e.IsVPN = result.IsVPN
+	e.IsProxy = result.IsProxy
+	e.IsTor = result.IsTor
+	e.IsHosting = result.IsHosting
+	e.RiskScore = result.RiskScore
+}
+
+// GeoOverrideKind is what an admin override matches on
+type GeoOverrideKind string
+
+const (
+	GeoOverrideIP   GeoOverrideKind = "ip"   // A single address or a CIDR range
+	GeoOverrideUser GeoOverrideKind = "user" // A user ID
+)
+
+// GeoOverride exempts an address or a user from geo blocking and VPN flagging,
+// e.g. a corporate egress range or a customer travelling with a known VPN
+type GeoOverride struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	Kind      GeoOverrideKind `json:"kind" db:"kind"`
+	Value     string          `json:"value" db:"value"`
+	Reason    string          `json:"reason" db:"reason"`
+	CreatedBy uuid.UUID       `json:"created_by" db:"created_by"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Active reports whether the override still applies
+func (o *GeoOverride) Active(now time.Time) bool {
+	return o.ExpiresAt == nil || now.Before(*o.ExpiresAt)
+}
+
+// CreateGeoOverrideRequest adds an entry to the geo override list
+type CreateGeoOverrideRequest struct {
+	Kind      GeoOverrideKind `json:"kind" binding:"required,oneof=ip user"`
+	Value     string          `json:"value" binding:"required"`
+	Reason    string          `json:"reason" binding:"required"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
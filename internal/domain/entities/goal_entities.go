@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Goal errors
+var (
+	ErrGoalNotFound = errors.New("goal not found")
+	ErrInvalidGoal  = errors.New("invalid goal")
+)
+
+// GoalStatus tracks an investment goal
+type GoalStatus string
+
+const (
+	GoalStatusActive    GoalStatus = "active"
+	GoalStatusAchieved  GoalStatus = "achieved"
+	GoalStatusCancelled GoalStatus = "cancelled"
+)
+
+// Goal is a target portfolio value a user wants to reach by a date. The plan
+// is fixed when the goal is set or changed: the portfolio value at that time
+// (the baseline) and the monthly contribution needed from there.
+type Goal struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	UserID         uuid.UUID       `json:"user_id" db:"user_id"`
+	Name           string          `json:"name" db:"name"`
+	TargetAmount   decimal.Decimal `json:"target_amount" db:"target_amount"`
+	TargetDate     time.Time       `json:"target_date" db:"target_date"`
+	RiskLevel      RiskLevel       `json:"risk_level" db:"risk_level"`
+	BaselineValue  decimal.Decimal `json:"baseline_value" db:"baseline_value"`
+	BaselineAt     time.Time       `json:"baseline_at" db:"baseline_at"`
+	PlannedMonthly decimal.Decimal `json:"planned_monthly" db:"planned_monthly"`
+	Status         GoalStatus      `json:"status" db:"status"`
+	LastNudgedAt   *time.Time      `json:"last_nudged_at,omitempty" db:"last_nudged_at"`
+	AchievedAt     *time.Time      `json:"achieved_at,omitempty" db:"achieved_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// GoalProgress compares a goal's plan with the portfolio as it stands
+type GoalProgress struct {
+	Goal *Goal `json:"goal"`
+	// ExpectedAnnualReturn is the return assumed for the goal's risk level
+	ExpectedAnnualReturn decimal.Decimal `json:"expected_annual_return"`
+	CurrentValue         decimal.Decimal `json:"current_value"`
+	// PlannedValue is where the portfolio should be today on the plan
+	PlannedValue    decimal.Decimal `json:"planned_value"`
+	ProgressPercent decimal.Decimal `json:"progress_percent"`
+	// RequiredMonthly is the contribution needed from today to reach the target
+	RequiredMonthly decimal.Decimal `json:"required_monthly"`
+	ProjectedValue  decimal.Decimal `json:"projected_value"` // At the target date if RequiredMonthly is contributed
+	MonthsRemaining int             `json:"months_remaining"`
+	OnTrack         bool            `json:"on_track"`
+}
+
+// CreateGoalRequest sets a new goal
+type CreateGoalRequest struct {
+	Name         string          `json:"name" binding:"required"`
+	TargetAmount decimal.Decimal `json:"target_amount" binding:"required"`
+	TargetDate   time.Time       `json:"target_date" binding:"required"`
+	RiskLevel    RiskLevel       `json:"risk_level"` // Defaults to balanced
+}
+
+// UpdateGoalRequest changes a goal; omitted fields are kept. Changing the
+// target or risk level re-plans the goal from the current portfolio value.
+type UpdateGoalRequest struct {
+	Name         *string          `json:"name,omitempty"`
+	TargetAmount *decimal.Decimal `json:"target_amount,omitempty"`
+	TargetDate   *time.Time       `json:"target_date,omitempty"`
+	RiskLevel    *RiskLevel       `json:"risk_level,omitempty"`
+}
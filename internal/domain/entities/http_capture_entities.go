@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HTTPCaptureReason is why a request was captured
+type HTTPCaptureReason string
+
+const (
+	HTTPCaptureSampled HTTPCaptureReason = "sampled" // picked by the sample rate
+	HTTPCaptureUser    HTTPCaptureReason = "user"    // the user is flagged by an admin
+	HTTPCaptureRoute   HTTPCaptureReason = "route"   // the route is flagged by an admin
+)
+
+// HTTPCapture is a sanitized copy of one request and its response
+type HTTPCapture struct {
+	ID             uuid.UUID         `json:"id" db:"id"`
+	RequestID      string            `json:"request_id" db:"request_id"`
+	UserID         *uuid.UUID        `json:"user_id,omitempty" db:"user_id"`
+	Method         string            `json:"method" db:"method"`
+	Route          string            `json:"route" db:"route"`
+	Path           string            `json:"path" db:"path"`
+	Query          string            `json:"query,omitempty" db:"query"`
+	StatusCode     int               `json:"status_code" db:"status_code"`
+	DurationMs     int64             `json:"duration_ms" db:"duration_ms"`
+	ClientIP       string            `json:"client_ip,omitempty" db:"client_ip"`
+	UserAgent      string            `json:"user_agent,omitempty" db:"user_agent"`
+	RequestHeaders map[string]string `json:"request_headers" db:"request_headers"`
+	RequestBody    string            `json:"request_body,omitempty" db:"request_body"`
+	ResponseBody   string            `json:"response_body,omitempty" db:"response_body"`
+	Truncated      bool              `json:"truncated" db:"truncated"`
+	Reason         HTTPCaptureReason `json:"reason" db:"reason"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	ExpiresAt      time.Time         `json:"expires_at" db:"expires_at"`
+}
+
+// HTTPCaptureFilter narrows a capture listing
+type HTTPCaptureFilter struct {
+	UserID     *uuid.UUID
+	Route      string
+	RequestID  string
+	StatusCode int
+	Limit      int
+}
+
+// HTTPCaptureTarget flags a user or route for full capture until it expires
+type HTTPCaptureTarget struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Route     string     `json:"route,omitempty" db:"route"`
+	Note      string     `json:"note" db:"note"`
+	CreatedBy uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+}
+
+// CreateHTTPCaptureTargetRequest flags a user or a route (as registered,
+// e.g. /api/v1/funding/deposits/:id) for capture
+type CreateHTTPCaptureTargetRequest struct {
+	UserID     *uuid.UUID `json:"user_id"`
+	Route      string     `json:"route" binding:"max=200"`
+	Note       string     `json:"note" binding:"required,max=500"`
+	TTLMinutes int        `json:"ttl_minutes" binding:"omitempty,min=1,max=10080"`
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInstantAdvanceNotFound is returned when a deposit has no buying power advance
+var ErrInstantAdvanceNotFound = errors.New("instant funding advance not found")
+
+// InstantAdvanceStatus tracks buying power advanced against a pending deposit
+type InstantAdvanceStatus string
+
+const (
+	// InstantAdvancePending advances are recorded while the Alpaca instant
+	// funding transfer is requested
+	InstantAdvancePending InstantAdvanceStatus = "pending"
+	// InstantAdvanceActive advances are usable buying power awaiting the deposit
+	InstantAdvanceActive InstantAdvanceStatus = "active"
+	// InstantAdvanceRepaid advances were netted out of the credited deposit
+	InstantAdvanceRepaid InstantAdvanceStatus = "repaid"
+	// InstantAdvanceReversed advances were clawed back after the deposit failed
+	InstantAdvanceReversed InstantAdvanceStatus = "reversed"
+	// InstantAdvanceFailed advances never became buying power
+	InstantAdvanceFailed InstantAdvanceStatus = "failed"
+)
+
+// InstantFundingAdvance is buying power extended through Alpaca instant
+// funding while a detected deposit waits for its confirmations
+type InstantFundingAdvance struct {
+	ID               uuid.UUID            `json:"id" db:"id"`
+	DepositID        uuid.UUID            `json:"deposit_id" db:"deposit_id"`
+	UserID           uuid.UUID            `json:"user_id" db:"user_id"`
+	Amount           decimal.Decimal      `json:"amount" db:"amount"`
+	Status           InstantAdvanceStatus `json:"status" db:"status"`
+	AlpacaTransferID *string              `json:"alpaca_transfer_id,omitempty" db:"alpaca_transfer_id"`
+	Shortfall        decimal.Decimal      `json:"shortfall" db:"shortfall"` // clawback the user's buying power could not cover
+	FailureReason    *string              `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	SettledAt        *time.Time           `json:"settled_at,omitempty" db:"settled_at"`
+}
@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Integrity check errors
+var (
+	ErrIntegrityRunNotFound = errors.New("integrity run not found")
+)
+
+// IntegrityAnomalyClass is a kind of cross-entity inconsistency the integrity
+// checker looks for
+type IntegrityAnomalyClass string
+
+const (
+	IntegrityCompletedWithoutWallet IntegrityAnomalyClass = "completed_without_wallet"  // onboarding completed but no live wallet
+	IntegrityOrderWithoutLedger     IntegrityAnomalyClass = "order_without_ledger"      // filled order never posted to the ledger
+	IntegrityKYCApprovedStuck       IntegrityAnomalyClass = "kyc_approved_stuck"        // KYC approved but onboarding did not move on
+	IntegrityWalletWithoutWalletSet IntegrityAnomalyClass = "wallet_without_wallet_set" // live wallet whose wallet set is gone or inactive
+)
+
+// IntegrityAnomalyClasses lists every class in the order they are checked
+func IntegrityAnomalyClasses() []IntegrityAnomalyClass {
+	return []IntegrityAnomalyClass{
+		IntegrityCompletedWithoutWallet,
+		IntegrityOrderWithoutLedger,
+		IntegrityKYCApprovedStuck,
+		IntegrityWalletWithoutWalletSet,
+	}
+}
+
+// Description explains the class to the admin reading a report or case
+func (c IntegrityAnomalyClass) Description() string {
+	switch c {
+	case IntegrityCompletedWithoutWallet:
+		return "User completed onboarding but has no live wallet"
+	case IntegrityOrderWithoutLedger:
+		return "Filled order has no ledger transaction"
+	case IntegrityKYCApprovedStuck:
+		return "KYC was approved but onboarding has not moved past KYC"
+	case IntegrityWalletWithoutWalletSet:
+		return "Live wallet's wallet set is missing or not active"
+	default:
+		return string(c)
+	}
+}
+
+// CaseType is the admin case type anomalies of the class are filed under
+func (c IntegrityAnomalyClass) CaseType() AdminCaseType {
+	return AdminCaseType("integrity_" + string(c))
+}
+
+// IntegrityRunTrigger is what started an integrity run
+type IntegrityRunTrigger string
+
+const (
+	IntegrityRunScheduled IntegrityRunTrigger = "scheduled"
+	IntegrityRunManual    IntegrityRunTrigger = "manual"
+)
+
+// IntegrityAnomaly is one record that failed a check. SubjectID is the record
+// that is inconsistent: the user, order or wallet.
+type IntegrityAnomaly struct {
+	Class       IntegrityAnomalyClass `json:"class"`
+	UserID      uuid.UUID             `json:"user_id"`
+	SubjectType string                `json:"subject_type"`
+	SubjectID   string                `json:"subject_id"`
+	Since       time.Time             `json:"since"`
+}
+
+// IntegrityCheckResult is the outcome of one class's check. Truncated means
+// more anomalies were found than a run reports.
+type IntegrityCheckResult struct {
+	Class       IntegrityAnomalyClass `json:"class"`
+	Description string                `json:"description"`
+	Count       int                   `json:"count"`
+	Truncated   bool                  `json:"truncated"`
+	Cases       int                   `json:"cases"`
+	Anomalies   []*IntegrityAnomaly   `json:"anomalies,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// IntegrityReport is the result of an integrity run
+type IntegrityReport struct {
+	ID          uuid.UUID               `json:"id"`
+	RunDate     *string                 `json:"run_date,omitempty"` // YYYY-MM-DD of scheduled runs
+	Trigger     IntegrityRunTrigger     `json:"trigger"`
+	TriggeredBy *uuid.UUID              `json:"triggered_by,omitempty"`
+	Anomalies   int                     `json:"anomalies"`
+	Checks      []*IntegrityCheckResult `json:"checks,omitempty"`
+	Error       *string                 `json:"error,omitempty"`
+	StartedAt   time.Time               `json:"started_at"`
+	FinishedAt  *time.Time              `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrCartEmpty is returned when checking out a cart with no items
+	ErrCartEmpty = errors.New("cart is empty")
+
+	// ErrCartFull is returned when adding a basket to a cart at its item limit
+	ErrCartFull = errors.New("cart is full")
+
+	// ErrCartItemNotFound is returned when removing a basket that is not in the cart
+	ErrCartItemNotFound = errors.New("basket is not in the cart")
+
+	// ErrStepUpRequired is returned when a checkout carries neither a passcode
+	// session nor a 2FA code
+	ErrStepUpRequired = errors.New("passcode or 2FA verification required")
+
+	// ErrStepUpFailed is returned when the passcode session or 2FA code does
+	// not verify
+	ErrStepUpFailed = errors.New("passcode or 2FA verification failed")
+)
+
+// CartItem is a basket buy queued in the user's investing cart
+type CartItem struct {
+	BasketID   uuid.UUID       `json:"basketId"`
+	BasketName string          `json:"basketName,omitempty"`
+	Amount     decimal.Decimal `json:"amount"`
+	AddedAt    time.Time       `json:"addedAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// Cart is the user's queued basket buys
+type Cart struct {
+	Items []*CartItem `json:"items"`
+	Total string      `json:"total"`
+}
+
+// CartItemRequest sets the amount to buy of a basket in the cart
+type CartItemRequest struct {
+	Amount string `json:"amount" binding:"required"`
+}
+
+// CartItemError explains why a cart item cannot be or was not placed
+type CartItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// CartItemPreview is one item's estimated order, or why it cannot be placed
+type CartItemPreview struct {
+	BasketID uuid.UUID      `json:"basketId"`
+	Amount   string         `json:"amount"`
+	Preview  *OrderPreview  `json:"preview,omitempty"`
+	Error    *CartItemError `json:"error,omitempty"`
+}
+
+// CartPreview is the combined estimate for checking out the whole cart
+type CartPreview struct {
+	Items            []CartItemPreview `json:"items"`
+	TotalAmount      string            `json:"totalAmount"`
+	TotalFees        string            `json:"totalFees"`
+	NetAmount        string            `json:"netAmount"` // invested across all baskets after fees
+	BuyingPower      string            `json:"buyingPower"`
+	BuyingPowerAfter string            `json:"buyingPowerAfter"`
+	// Ready is true when every item previewed and the cart fits the user's
+	// buying power and spending limit; Error says why not otherwise
+	Ready bool           `json:"ready"`
+	Error *CartItemError `json:"error,omitempty"`
+	Paper bool           `json:"paper"`
+}
+
+// CartCheckoutRequest carries the step-up for placing the whole cart: a
+// passcode session from passcode verification, or a 2FA code
+type CartCheckoutRequest struct {
+	PasscodeSessionToken string `json:"passcodeSessionToken,omitempty"`
+	TwoFactorCode        string `json:"twoFactorCode,omitempty"`
+}
+
+// CartItemStatus is the outcome of placing one cart item
+type CartItemStatus string
+
+const (
+	CartItemPlaced CartItemStatus = "placed"
+	CartItemFailed CartItemStatus = "failed"
+)
+
+// CartCheckoutStatus summarises a checkout across its items
+type CartCheckoutStatus string
+
+const (
+	CartCheckoutCompleted CartCheckoutStatus = "completed" // every item was placed
+	CartCheckoutPartial   CartCheckoutStatus = "partial"   // some items failed and are still in the cart
+	CartCheckoutFailed    CartCheckoutStatus = "failed"    // no item was placed
+)
+
+// CartItemResult is the outcome of placing one cart item
+type CartItemResult struct {
+	BasketID uuid.UUID      `json:"basketId"`
+	Amount   string         `json:"amount"`
+	Status   CartItemStatus `json:"status"`
+	Order    *Order         `json:"order,omitempty"`
+	Error    *CartItemError `json:"error,omitempty"`
+}
+
+// CartCheckoutResult reports each item of a checkout. Placed items leave the
+// cart; failed items stay so they can be retried.
+type CartCheckoutResult struct {
+	Status      CartCheckoutStatus `json:"status"`
+	Items       []CartItemResult   `json:"items"`
+	TotalPlaced string             `json:"totalPlaced"`
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jurisdiction errors
+var (
+	ErrJurisdictionNotSupported = errors.New("jurisdiction not supported")
+)
+
+// DefaultCountryCode identifies the rule applied to countries without their own rule
+const DefaultCountryCode = "*"
+
+// JurisdictionFeature is a product capability that can be enabled per country
+type JurisdictionFeature string
+
+const (
+	FeatureTrading           JurisdictionFeature = "trading"
+	FeatureCryptoDeposits    JurisdictionFeature = "crypto_deposits"
+	FeatureCryptoWithdrawals JurisdictionFeature = "crypto_withdrawals"
+	FeatureCards             JurisdictionFeature = "cards"
+	FeatureVirtualAccounts   JurisdictionFeature = "virtual_accounts"
+	// FeaturePaperTrading lets users trade simulated balances. Countries that
+	// allow it without trading are pre-approval: everyone there trades on paper.
+	FeaturePaperTrading JurisdictionFeature = "paper_trading"
+)
+
+// KnownJurisdictionFeatures lists every feature a country rule may enable
+var KnownJurisdictionFeatures = []JurisdictionFeature{
+	FeatureTrading,
+	FeatureCryptoDeposits,
+	FeatureCryptoWithdrawals,
+	FeatureCards,
+	FeatureVirtualAccounts,
+	FeaturePaperTrading,
+}
+
+// KYCLevel is the depth of identity verification a jurisdiction requires
+type KYCLevel string
+
+const (
+	KYCLevelBasic    KYCLevel = "basic"
+	KYCLevelStandard KYCLevel = "standard"
+	KYCLevelEnhanced KYCLevel = "enhanced"
+)
+
+// CountryRule is the regulatory configuration for a single country
+type CountryRule struct {
+	CountryCode         string                `json:"country_code" db:"country_code"`
+	OnboardingAllowed   bool                  `json:"onboarding_allowed" db:"onboarding_allowed"`
+	AllowedFeatures     []JurisdictionFeature `json:"allowed_features" db:"allowed_features"`
+	RequiredDisclosures []string              `json:"required_disclosures" db:"required_disclosures"`
+	KYCLevel            KYCLevel              `json:"kyc_level" db:"kyc_level"`
+	Notes               *string               `json:"notes,omitempty" db:"notes"`
+	UpdatedBy           *uuid.UUID            `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt           time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// Allows reports whether the rule enables a feature
+func (r *CountryRule) Allows(feature JurisdictionFeature) bool {
+	if !r.OnboardingAllowed {
+		return false
+	}
+	for _, f := range r.AllowedFeatures {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// JurisdictionDecision is the result of evaluating a country at onboarding
+type JurisdictionDecision struct {
+	CountryCode         string                `json:"country_code"`
+	Allowed             bool                  `json:"allowed"`
+	AllowedFeatures     []JurisdictionFeature `json:"allowed_features"`
+	RequiredDisclosures []string              `json:"required_disclosures"`
+	KYCLevel            KYCLevel              `json:"kyc_level"`
+}
+
+// UpsertCountryRuleRequest updates the rule for a country
+type UpsertCountryRuleRequest struct {
+	OnboardingAllowed   bool                  `json:"onboarding_allowed"`
+	AllowedFeatures     []JurisdictionFeature `json:"allowed_features"`
+	RequiredDisclosures []string              `json:"required_disclosures"`
+	KYCLevel            KYCLevel              `json:"kyc_level" binding:"required,oneof=basic standard enhanced"`
+	Notes               *string               `json:"notes,omitempty"`
+}
+
+// NormalizeCountryCode upper-cases an ISO 3166-1 alpha-2 code
+func NormalizeCountryCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
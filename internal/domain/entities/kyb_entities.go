@@ -0,0 +1,195 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Business verification errors
+var (
+	ErrKYBApplicationNotFound = errors.New("business verification application not found")
+	ErrKYBOwnerNotFound       = errors.New("beneficial owner not found")
+	ErrKYBUnavailable         = errors.New("business accounts are not available yet")
+	ErrKYBAlreadyBusiness     = errors.New("account is already a verified business account")
+	ErrKYBNotEditable         = errors.New("business verification application can no longer be changed")
+	ErrKYBNotPending          = errors.New("business verification application is not awaiting review")
+	ErrKYBIncomplete          = errors.New("business verification application is incomplete")
+	ErrInvalidKYBDocument     = errors.New("invalid business verification document")
+	ErrInvalidBeneficialOwner = errors.New("invalid beneficial owner")
+	// ErrKYBOwnersUnverified is returned when approving an application whose
+	// owners have not all passed identity verification
+	ErrKYBOwnersUnverified = errors.New("every beneficial owner must pass identity verification")
+)
+
+// UserAccountType distinguishes personal accounts from verified businesses.
+// Limits follow it, and statements and tax reporting read it to tell a
+// company's activity from an individual's.
+type UserAccountType string
+
+const (
+	UserAccountIndividual UserAccountType = "individual"
+	UserAccountBusiness   UserAccountType = "business"
+)
+
+// BusinessEntityType is the legal form of a company
+type BusinessEntityType string
+
+const (
+	BusinessEntityLLC                BusinessEntityType = "llc"
+	BusinessEntityCorporation        BusinessEntityType = "corporation"
+	BusinessEntityPartnership        BusinessEntityType = "partnership"
+	BusinessEntitySoleProprietorship BusinessEntityType = "sole_proprietorship"
+	BusinessEntityNonProfit          BusinessEntityType = "non_profit"
+)
+
+// IsValid reports whether the entity type is supported
+func (t BusinessEntityType) IsValid() bool {
+	switch t {
+	case BusinessEntityLLC, BusinessEntityCorporation, BusinessEntityPartnership,
+		BusinessEntitySoleProprietorship, BusinessEntityNonProfit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Business verification document types
+const (
+	KYBDocumentCertificateOfIncorporation = "certificate_of_incorporation"
+	KYBDocumentProofOfAddress             = "proof_of_address"
+	KYBDocumentOwnershipStructure         = "ownership_structure"
+	KYBDocumentOperatingAgreement         = "operating_agreement"
+	KYBDocumentTaxRegistration            = "tax_registration"
+)
+
+// IsKYBDocumentType reports whether a document type is accepted for business
+// verification
+func IsKYBDocumentType(docType string) bool {
+	switch docType {
+	case KYBDocumentCertificateOfIncorporation, KYBDocumentProofOfAddress, KYBDocumentOwnershipStructure,
+		KYBDocumentOperatingAgreement, KYBDocumentTaxRegistration:
+		return true
+	default:
+		return false
+	}
+}
+
+// KYBStatus tracks a business verification application
+type KYBStatus string
+
+const (
+	// KYBDraft applications are still being filled in by the user
+	KYBDraft         KYBStatus = "draft"
+	KYBPendingReview KYBStatus = "pending_review"
+	KYBApproved      KYBStatus = "approved"
+	KYBRejected      KYBStatus = "rejected"
+)
+
+// KYBApplication is a user's request to verify the company they operate the
+// account for. The tax ID is encrypted at rest and only its last four
+// digits leave the service.
+type KYBApplication struct {
+	ID                   uuid.UUID           `json:"id" db:"id"`
+	UserID               uuid.UUID           `json:"user_id" db:"user_id"`
+	Status               KYBStatus           `json:"status" db:"status"`
+	LegalName            string              `json:"legal_name" db:"legal_name"`
+	TradeName            *string             `json:"trade_name,omitempty" db:"trade_name"`
+	EntityType           BusinessEntityType  `json:"entity_type" db:"entity_type"`
+	RegistrationNumber   string              `json:"registration_number" db:"registration_number"`
+	TaxID                string              `json:"-" db:"tax_id"`
+	TaxIDLast4           string              `json:"tax_id_last4" db:"tax_id_last4"`
+	IncorporationCountry string              `json:"incorporation_country" db:"incorporation_country"`
+	IncorporationState   *string             `json:"incorporation_state,omitempty" db:"incorporation_state"`
+	IncorporationDate    *time.Time          `json:"incorporation_date,omitempty" db:"incorporation_date"`
+	Address              Address             `json:"address" db:"address"`
+	Website              *string             `json:"website,omitempty" db:"website"`
+	BusinessActivity     string              `json:"business_activity" db:"business_activity"`
+	Documents            []KYCDocumentUpload `json:"documents" db:"documents"`
+	Owners               []*BeneficialOwner  `json:"owners" db:"-"`
+	CaseID               *uuid.UUID          `json:"case_id,omitempty" db:"case_id"`
+	RejectionReason      *string             `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	ReviewedBy           *uuid.UUID          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNotes          *string             `json:"review_notes,omitempty" db:"review_notes"`
+	ReviewedAt           *time.Time          `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	SubmittedAt          *time.Time          `json:"submitted_at,omitempty" db:"submitted_at"`
+	CreatedAt            time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// Editable reports whether the user can still change the application
+func (a *KYBApplication) Editable() bool {
+	return a.Status == KYBDraft
+}
+
+// BeneficialOwner is a person who owns part of, or controls, a business
+// under review. Each one goes through individual identity verification with
+// the KYC provider.
+type BeneficialOwner struct {
+	ID               uuid.UUID           `json:"id" db:"id"`
+	ApplicationID    uuid.UUID           `json:"application_id" db:"application_id"`
+	FirstName        string              `json:"first_name" db:"first_name"`
+	LastName         string              `json:"last_name" db:"last_name"`
+	Email            string              `json:"email" db:"email"`
+	Title            *string             `json:"title,omitempty" db:"title"`
+	OwnershipPercent decimal.Decimal     `json:"ownership_percent" db:"ownership_percent"`
+	IsController     bool                `json:"is_controller" db:"is_controller"`
+	Country          string              `json:"country" db:"country"`
+	Documents        []KYCDocumentUpload `json:"documents" db:"documents"`
+	KYCStatus        KYCStatus           `json:"kyc_status" db:"kyc_status"`
+	KYCProviderRef   *string             `json:"kyc_provider_ref,omitempty" db:"kyc_provider_ref"`
+	KYCCheckedAt     *time.Time          `json:"kyc_checked_at,omitempty" db:"kyc_checked_at"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// KYBOverview is what a user sees about business accounts: whether they can
+// apply, their account type and their latest application
+type KYBOverview struct {
+	Available   bool            `json:"available"`
+	AccountType UserAccountType `json:"account_type"`
+	Application *KYBApplication `json:"application,omitempty"`
+}
+
+// SaveKYBCompanyRequest captures or updates the company details of a draft
+// application
+type SaveKYBCompanyRequest struct {
+	LegalName            string             `json:"legalName" binding:"required,max=255"`
+	TradeName            *string            `json:"tradeName,omitempty" binding:"omitempty,max=255"`
+	EntityType           BusinessEntityType `json:"entityType" binding:"required"`
+	RegistrationNumber   string             `json:"registrationNumber" binding:"required,max=64"`
+	TaxID                string             `json:"taxId" binding:"required,min=4,max=32"`
+	IncorporationCountry string             `json:"incorporationCountry" binding:"required,len=2"`
+	IncorporationState   *string            `json:"incorporationState,omitempty" binding:"omitempty,max=64"`
+	IncorporationDate    *time.Time         `json:"incorporationDate,omitempty"`
+	Address              Address            `json:"address" binding:"required"`
+	Website              *string            `json:"website,omitempty" binding:"omitempty,url"`
+	BusinessActivity     string             `json:"businessActivity" binding:"required,max=1000"`
+}
+
+// AddBeneficialOwnerRequest adds an owner or controller to a draft
+// application together with the identity documents used to verify them
+type AddBeneficialOwnerRequest struct {
+	FirstName        string              `json:"firstName" binding:"required,max=100"`
+	LastName         string              `json:"lastName" binding:"required,max=100"`
+	Email            string              `json:"email" binding:"required,email"`
+	Title            *string             `json:"title,omitempty" binding:"omitempty,max=100"`
+	OwnershipPercent decimal.Decimal     `json:"ownershipPercent"`
+	IsController     bool                `json:"isController"`
+	DateOfBirth      *time.Time          `json:"dateOfBirth,omitempty"`
+	Country          string              `json:"country" binding:"required,len=2"`
+	Address          *Address            `json:"address,omitempty"`
+	Documents        []KYCDocumentUpload `json:"documents" binding:"required,min=1,max=5"`
+}
+
+// AddKYBDocumentsRequest attaches company documents to a draft application
+type AddKYBDocumentsRequest struct {
+	Documents []KYCDocumentUpload `json:"documents" binding:"required,min=1,max=10"`
+}
+
+// ReviewKYBRequest is compliance's decision on an application
+type ReviewKYBRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
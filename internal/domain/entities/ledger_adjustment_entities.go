@@ -0,0 +1,156 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Ledger adjustment errors
+var (
+	ErrLedgerAdjustmentNotFound = errors.New("ledger adjustment not found")
+	ErrInvalidLedgerAdjustment  = errors.New("invalid ledger adjustment")
+)
+
+// AdjustmentReasonCode classifies why ops posted a manual correcting entry
+type AdjustmentReasonCode string
+
+const (
+	AdjustmentReasonDuplicatePosting    AdjustmentReasonCode = "duplicate_posting"    // an event was posted twice
+	AdjustmentReasonMissedPosting       AdjustmentReasonCode = "missed_posting"       // an event was never posted
+	AdjustmentReasonIncorrectAmount     AdjustmentReasonCode = "incorrect_amount"     // an event was posted for the wrong amount
+	AdjustmentReasonProviderDiscrepancy AdjustmentReasonCode = "provider_discrepancy" // a provider's records disagree with ours
+	AdjustmentReasonFeeRefund           AdjustmentReasonCode = "fee_refund"
+	AdjustmentReasonGoodwillCredit      AdjustmentReasonCode = "goodwill_credit"
+)
+
+// IsValid reports whether the reason code is known
+func (c AdjustmentReasonCode) IsValid() bool {
+	switch c {
+	case AdjustmentReasonDuplicatePosting, AdjustmentReasonMissedPosting, AdjustmentReasonIncorrectAmount,
+		AdjustmentReasonProviderDiscrepancy, AdjustmentReasonFeeRefund, AdjustmentReasonGoodwillCredit:
+		return true
+	}
+	return false
+}
+
+// Label is the reason shown to the user on their statement
+func (c AdjustmentReasonCode) Label() string {
+	switch c {
+	case AdjustmentReasonDuplicatePosting:
+		return "Correction of a duplicate transaction"
+	case AdjustmentReasonMissedPosting:
+		return "Correction of a missing transaction"
+	case AdjustmentReasonIncorrectAmount:
+		return "Correction of a transaction amount"
+	case AdjustmentReasonProviderDiscrepancy:
+		return "Correction after a partner review"
+	case AdjustmentReasonFeeRefund:
+		return "Fee refund"
+	case AdjustmentReasonGoodwillCredit:
+		return "Goodwill credit"
+	}
+	return "Balance adjustment"
+}
+
+// AdjustmentDirection says whether an adjustment raises or lowers the user's
+// account. The offset system account moves the other way.
+type AdjustmentDirection string
+
+const (
+	AdjustmentIncrease AdjustmentDirection = "increase"
+	AdjustmentDecrease AdjustmentDirection = "decrease"
+)
+
+// LedgerAdjustmentStatus tracks an adjustment through dual control
+type LedgerAdjustmentStatus string
+
+const (
+	LedgerAdjustmentPendingApproval LedgerAdjustmentStatus = "pending_approval"
+	LedgerAdjustmentPosted          LedgerAdjustmentStatus = "posted"
+	LedgerAdjustmentRejected        LedgerAdjustmentStatus = "rejected"
+	// LedgerAdjustmentFailed adjustments were approved but could not be
+	// posted, for example because the account would go negative
+	LedgerAdjustmentFailed LedgerAdjustmentStatus = "failed"
+)
+
+// SupportingDocument references evidence for an adjustment, such as a
+// provider statement or a support ticket
+type SupportingDocument struct {
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required,url"`
+}
+
+// LedgerAdjustment is a manual correcting entry between a user's account
+// and a system account. It is posted as one balanced ledger transaction of
+// type adjustment once an admin other than the requester approves it.
+type LedgerAdjustment struct {
+	ID                  uuid.UUID              `json:"id"`
+	UserID              uuid.UUID              `json:"user_id"`
+	AccountType         AccountType            `json:"account_type"`
+	OffsetAccountType   AccountType            `json:"offset_account_type"`
+	Direction           AdjustmentDirection    `json:"direction"`
+	Amount              decimal.Decimal        `json:"amount"`
+	Currency            string                 `json:"currency"`
+	ReasonCode          AdjustmentReasonCode   `json:"reason_code"`
+	Memo                string                 `json:"memo"`
+	Documents           []SupportingDocument   `json:"documents"`
+	Status              LedgerAdjustmentStatus `json:"status"`
+	RequestedBy         uuid.UUID              `json:"requested_by"`
+	ApprovalRequestID   *uuid.UUID             `json:"approval_request_id,omitempty"`
+	LedgerTransactionID *uuid.UUID             `json:"ledger_transaction_id,omitempty"`
+	FailureReason       *string                `json:"failure_reason,omitempty"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+	PostedAt            *time.Time             `json:"posted_at,omitempty"`
+}
+
+// StatementLine is how the adjustment appears on the user's statement,
+// without the internal memo, evidence or reviewers
+func (a *LedgerAdjustment) StatementLine() AdjustmentStatementLine {
+	line := AdjustmentStatementLine{
+		ID:          a.ID,
+		AccountType: a.AccountType,
+		Direction:   a.Direction,
+		Amount:      a.Amount,
+		Currency:    a.Currency,
+		Description: a.ReasonCode.Label(),
+	}
+	if a.PostedAt != nil {
+		line.PostedAt = *a.PostedAt
+	}
+	return line
+}
+
+// AdjustmentStatementLine is a posted adjustment on a user's statement
+type AdjustmentStatementLine struct {
+	ID          uuid.UUID           `json:"id"`
+	AccountType AccountType         `json:"account_type"`
+	Direction   AdjustmentDirection `json:"direction"`
+	Amount      decimal.Decimal     `json:"amount"`
+	Currency    string              `json:"currency"`
+	Description string              `json:"description"`
+	PostedAt    time.Time           `json:"posted_at"`
+}
+
+// CreateLedgerAdjustmentRequest asks for a manual correcting entry
+type CreateLedgerAdjustmentRequest struct {
+	UserID            uuid.UUID            `json:"user_id" binding:"required"`
+	AccountType       AccountType          `json:"account_type" binding:"required"`
+	OffsetAccountType AccountType          `json:"offset_account_type" binding:"required"`
+	Direction         AdjustmentDirection  `json:"direction" binding:"required,oneof=increase decrease"`
+	Amount            decimal.Decimal      `json:"amount" binding:"required"`
+	ReasonCode        AdjustmentReasonCode `json:"reason_code" binding:"required"`
+	Memo              string               `json:"memo" binding:"required"`
+	Documents         []SupportingDocument `json:"documents" binding:"required,min=1,dive"`
+}
+
+// LedgerAdjustmentFilter narrows an adjustment listing
+type LedgerAdjustmentFilter struct {
+	UserID *uuid.UUID
+	Status LedgerAdjustmentStatus
+	Limit  int
+	Offset int
+}
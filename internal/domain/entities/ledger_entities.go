@@ -16,11 +16,26 @@ const (
 	AccountTypeUSDCBalance       AccountType = "usdc_balance"       // User's available USDC (legacy, pre-allocation mode)
 	AccountTypeFiatExposure      AccountType = "fiat_exposure"      // User's buying power at Alpaca (USD)
 	AccountTypePendingInvestment AccountType = "pending_investment" // User's reserved funds for in-flight trades
+	AccountTypeHeldBalance       AccountType = "held_balance"       // User's funds held for pending orders and withdrawals
 
 	// Smart Allocation Mode account types
 	AccountTypeSpendingBalance AccountType = "spending_balance" // User's 70% spending balance (available for payments)
 	AccountTypeStashBalance    AccountType = "stash_balance"    // User's 30% stash balance (locked savings)
 
+	// Promotions account types
+	AccountTypePromotionalCredit AccountType = "promotional_credit" // User's unvested promotional credit
+	AccountTypeSystemPromotions  AccountType = "system_promotions"  // Marketing budget that funds promotional credit
+
+	// Rewards account types
+	AccountTypeRewardsBalance AccountType = "rewards_balance" // User's earned rewards not yet redeemed
+	AccountTypeSystemRewards  AccountType = "system_rewards"  // Budget that funds rewards
+
+	// Billing account types
+	AccountTypeSystemFeeRevenue AccountType = "system_fee_revenue" // Subscription fees collected from users
+
+	// Suspense account types
+	AccountTypeSystemSuspense AccountType = "system_suspense" // Funds received that are not yet attributed to a user
+
 	// System account types
 	AccountTypeSystemBufferUSDC  AccountType = "system_buffer_usdc" // System on-chain USDC reserve
 	AccountTypeSystemBufferFiat  AccountType = "system_buffer_fiat" // System operational USD buffer
@@ -32,15 +47,22 @@ func (a AccountType) IsUserAccountType() bool {
 	return a == AccountTypeUSDCBalance ||
 		a == AccountTypeFiatExposure ||
 		a == AccountTypePendingInvestment ||
+		a == AccountTypeHeldBalance ||
 		a == AccountTypeSpendingBalance ||
-		a == AccountTypeStashBalance
+		a == AccountTypeStashBalance ||
+		a == AccountTypePromotionalCredit ||
+		a == AccountTypeRewardsBalance
 }
 
 // IsSystemAccountType returns true if the account type is system-level
 func (a AccountType) IsSystemAccountType() bool {
 	return a == AccountTypeSystemBufferUSDC ||
 		a == AccountTypeSystemBufferFiat ||
-		a == AccountTypeBrokerOperational
+		a == AccountTypeBrokerOperational ||
+		a == AccountTypeSystemPromotions ||
+		a == AccountTypeSystemFeeRevenue ||
+		a == AccountTypeSystemSuspense ||
+		a == AccountTypeSystemRewards
 }
 
 // IsSystemAccount is an alias for IsSystemAccountType
@@ -56,9 +78,11 @@ func (a AccountType) IsValid() bool {
 // Validate checks if the account type is valid
 func (a AccountType) Validate() error {
 	switch a {
-	case AccountTypeUSDCBalance, AccountTypeFiatExposure, AccountTypePendingInvestment,
-		AccountTypeSpendingBalance, AccountTypeStashBalance,
-		AccountTypeSystemBufferUSDC, AccountTypeSystemBufferFiat, AccountTypeBrokerOperational:
+	case AccountTypeUSDCBalance, AccountTypeFiatExposure, AccountTypePendingInvestment, AccountTypeHeldBalance,
+		AccountTypeSpendingBalance, AccountTypeStashBalance, AccountTypePromotionalCredit,
+		AccountTypeSystemBufferUSDC, AccountTypeSystemBufferFiat, AccountTypeBrokerOperational,
+		AccountTypeSystemPromotions, AccountTypeSystemFeeRevenue, AccountTypeSystemSuspense,
+		AccountTypeRewardsBalance, AccountTypeSystemRewards:
 		return nil
 	default:
 		return fmt.Errorf("invalid account type: %s", a)
@@ -76,6 +100,13 @@ const (
 	TransactionTypeInternalTransfer    TransactionType = "internal_transfer"
 	TransactionTypeBufferReplenishment TransactionType = "buffer_replenishment"
 	TransactionTypeReversal            TransactionType = "reversal"
+	TransactionTypePromotion           TransactionType = "promotion"          // Promotional credit granted or vested
+	TransactionTypePromotionClawback   TransactionType = "promotion_clawback" // Unvested promotional credit forfeited
+	TransactionTypeSubscriptionFee     TransactionType = "subscription_fee"   // Subscription invoice paid from cash balance
+	TransactionTypeBalanceHold         TransactionType = "balance_hold"       // Funds held, captured or released for a pending operation
+	TransactionTypeAdjustment          TransactionType = "adjustment"         // Manual correcting entry posted under dual control
+	TransactionTypeReward              TransactionType = "reward"             // Reward earned or redeemed into an investment
+	TransactionTypeRewardClawback      TransactionType = "reward_clawback"    // Unredeemed reward forfeited back to the budget
 )
 
 // Validate checks if the transaction type is valid
@@ -83,7 +114,10 @@ func (t TransactionType) Validate() error {
 	switch t {
 	case TransactionTypeDeposit, TransactionTypeWithdrawal, TransactionTypeInvestment,
 		TransactionTypeConversion, TransactionTypeInternalTransfer,
-		TransactionTypeBufferReplenishment, TransactionTypeReversal:
+		TransactionTypeBufferReplenishment, TransactionTypeReversal,
+		TransactionTypePromotion, TransactionTypePromotionClawback,
+		TransactionTypeSubscriptionFee, TransactionTypeBalanceHold, TransactionTypeAdjustment,
+		TransactionTypeReward, TransactionTypeRewardClawback:
 		return nil
 	default:
 		return fmt.Errorf("invalid transaction type: %s", t)
@@ -285,6 +319,8 @@ type UserBalances struct {
 	USDCBalance        decimal.Decimal `json:"usdc_balance"`
 	FiatExposure       decimal.Decimal `json:"fiat_exposure"`
 	PendingInvestment  decimal.Decimal `json:"pending_investment"`
+	HeldBalance        decimal.Decimal `json:"held_balance"`
+	PromotionalCredit  decimal.Decimal `json:"promotional_credit"` // unvested, excluded from the total
 	TotalUSDEquivalent decimal.Decimal `json:"total_usd_equivalent"`
 	UpdatedAt          time.Time       `json:"updated_at"`
 }
@@ -296,7 +332,7 @@ func (b *UserBalances) TotalValue() decimal.Decimal {
 // CalculateTotalUSD calculates total balance in USD equivalent
 // Assumes 1 USDC = 1 USD for simplicity
 func (b *UserBalances) CalculateTotalUSD() decimal.Decimal {
-	return b.USDCBalance.Add(b.FiatExposure).Add(b.PendingInvestment)
+	return b.USDCBalance.Add(b.FiatExposure).Add(b.PendingInvestment).Add(b.HeldBalance)
 }
 
 // SystemBuffers represents the operational buffer balances
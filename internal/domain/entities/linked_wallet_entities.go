@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Linked wallet errors
+var (
+	ErrLinkedWalletNotFound = errors.New("linked wallet not found")
+	ErrLinkedWalletExists   = errors.New("this address is already linked to your account")
+)
+
+// LinkedWalletSource is how a wallet came to be linked to the user's Due account
+type LinkedWalletSource string
+
+const (
+	LinkedWalletSourcePlatform    LinkedWalletSource = "platform"     // a custodial wallet we created for the user
+	LinkedWalletSourceSelfCustody LinkedWalletSource = "self_custody" // an address the user proved they control
+)
+
+// LinkedWallet is a wallet linked to the user's Due account. The table is a
+// mirror of Due's wallet list for the account, refreshed when it is listed.
+type LinkedWallet struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	UserID      uuid.UUID          `json:"-" db:"user_id"`
+	Chain       WalletChain        `json:"chain" db:"chain"`
+	Address     string             `json:"address" db:"address"`
+	DueWalletID string             `json:"due_wallet_id" db:"due_wallet_id"`
+	Source      LinkedWalletSource `json:"source" db:"source"`
+	Label       *string            `json:"label,omitempty" db:"label"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// WalletChallengeRequest asks for a message to sign to prove ownership of an address
+type WalletChallengeRequest struct {
+	Chain   string `json:"chain" binding:"required"`
+	Address string `json:"address" binding:"required"`
+}
+
+// WalletChallenge is the message the user signs with the wallet being linked
+type WalletChallenge struct {
+	Chain     WalletChain `json:"chain"`
+	Address   string      `json:"address"`
+	Message   string      `json:"message"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// LinkExternalWalletRequest links a self-custody address. Message is the
+// challenge as issued and Signature is the wallet's signature of it: a hex
+// personal_sign signature on EVM chains, base58 ed25519 on Solana.
+type LinkExternalWalletRequest struct {
+	Chain     string  `json:"chain" binding:"required"`
+	Address   string  `json:"address" binding:"required"`
+	Message   string  `json:"message" binding:"required"`
+	Signature string  `json:"signature" binding:"required"`
+	Label     *string `json:"label,omitempty" binding:"omitempty,max=100"`
+}
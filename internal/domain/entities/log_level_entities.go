@@ -0,0 +1,9 @@
+package entities
+
+// SetLogLevelRequest raises or lowers one module's log level for a while
+type SetLogLevelRequest struct {
+	Level string `json:"level" binding:"required"` // debug, info, warn or error
+	// DurationMinutes is how long the level lasts before reverting; zero uses
+	// the configured default
+	DurationMinutes int `json:"duration_minutes"`
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// MarketSession is one US equities trading day. Date is the session's date in
+// New York; OpenAt and CloseAt are instants, and CloseAt is earlier than
+// 16:00 New York time on early-close days.
+type MarketSession struct {
+	Date       string    `json:"date"`
+	OpenAt     time.Time `json:"openAt"`
+	CloseAt    time.Time `json:"closeAt"`
+	EarlyClose bool      `json:"earlyClose"`
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Market data errors
+var (
+	ErrInvalidSymbol    = errors.New("invalid symbol")
+	ErrTooManySymbols   = errors.New("too many symbols for one connection")
+	ErrNoSymbolsToWatch = errors.New("no symbols to stream")
+)
+
+// MarketQuote is the latest NBBO quote for a symbol
+type MarketQuote struct {
+	Symbol    string          `json:"symbol"`
+	BidPrice  decimal.Decimal `json:"bid_price"`
+	BidSize   decimal.Decimal `json:"bid_size"`
+	AskPrice  decimal.Decimal `json:"ask_price"`
+	AskSize   decimal.Decimal `json:"ask_size"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// QuoteSnapshot is the first event on a quote stream: the latest quote for
+// each subscribed symbol and whether live updates follow
+type QuoteSnapshot struct {
+	Symbols    []string       `json:"symbols"`
+	Quotes     []*MarketQuote `json:"quotes"`
+	MarketOpen bool           `json:"market_open"`
+	// NextOpen is when live updates resume while the market is closed
+	NextOpen *time.Time `json:"next_open,omitempty"`
+}
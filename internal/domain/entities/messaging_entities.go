@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Messaging provider errors
+var (
+	ErrMessagingProviderNotFound = errors.New("messaging provider not found")
+	ErrLastMessagingProvider     = errors.New("cannot disable the last enabled provider")
+)
+
+// CapturedMessage is an outbound email or SMS stored by sandbox capture
+// instead of being sent
+type CapturedMessage struct {
+	ID          uuid.UUID           `json:"id" db:"id"`
+	Channel     NotificationChannel `json:"channel" db:"channel"`
+	Recipient   string              `json:"recipient" db:"recipient"`
+	Subject     string              `json:"subject,omitempty" db:"subject"`
+	TextBody    string              `json:"text_body,omitempty" db:"text_body"`
+	HTMLBody    string              `json:"html_body,omitempty" db:"html_body"`
+	Environment string              `json:"environment" db:"environment"`
+	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
+}
+
+// CapturedMessageFilter narrows a captured message listing
+type CapturedMessageFilter struct {
+	Channel   NotificationChannel
+	Recipient string
+	Limit     int
+	Offset    int
+}
+
+// MessagingProviderStatus reports the failover state of one email or SMS
+// provider
+type MessagingProviderStatus struct {
+	Channel  NotificationChannel `json:"channel"`
+	Provider string              `json:"provider"`
+	Priority int                 `json:"priority"` // Position in the failover order, 0 first
+	Enabled  bool                `json:"enabled"`  // Cleared by an admin to take the provider out of rotation
+	Healthy  bool                `json:"healthy"`
+	// ConsecutiveFailures counts sends that failed since the last success
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	UnhealthyUntil      *time.Time `json:"unhealthy_until,omitempty"`
+}
+
+// SetMessagingProviderRequest takes a provider out of, or back into, rotation
+type SetMessagingProviderRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason" binding:"required,max=500"`
+}
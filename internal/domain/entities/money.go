@@ -0,0 +1,172 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Currency is an ISO 4217 code or a stablecoin symbol
+type Currency string
+
+const (
+	CurrencyUSD  Currency = "USD"
+	CurrencyUSDC Currency = "USDC"
+)
+
+// minorUnits is the number of decimal places each currency settles in
+var minorUnits = map[Currency]int32{
+	CurrencyUSD:  2,
+	CurrencyUSDC: 6,
+}
+
+var (
+	// ErrCurrencyMismatch is returned when arithmetic mixes two currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrInvalidMoney is returned for an unparseable amount or missing currency
+	ErrInvalidMoney = errors.New("invalid money amount")
+)
+
+// Money is an exact amount in one currency. Adding, subtracting or comparing
+// amounts in different currencies fails instead of mixing them; moving between
+// currencies takes an explicit rate through Convert.
+type Money struct {
+	Amount   decimal.Decimal
+	Currency Currency
+}
+
+// NewMoney creates an amount in a currency
+func NewMoney(amount decimal.Decimal, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ZeroMoney returns zero in a currency
+func ZeroMoney(currency Currency) Money {
+	return Money{Amount: decimal.Zero, Currency: currency}
+}
+
+// ParseMoney parses a decimal string, such as a webhook or request amount,
+// without going through float64
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	if currency == "" {
+		return Money{}, fmt.Errorf("%w: currency is required", ErrInvalidMoney)
+	}
+	value, err := decimal.NewFromString(strings.TrimSpace(amount))
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, amount)
+	}
+	return Money{Amount: value, Currency: currency}, nil
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+// Cmp compares m with other, returning -1, 0 or +1
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+// Mul scales the amount, e.g. by a quantity or a fee rate
+func (m Money) Mul(factor decimal.Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Convert returns the amount in another currency at the given rate
+func (m Money) Convert(to Currency, rate decimal.Decimal) Money {
+	return Money{Amount: m.Amount.Mul(rate), Currency: to}
+}
+
+// Round rounds to the currency's minor units; unknown currencies are unchanged
+func (m Money) Round() Money {
+	places, ok := minorUnits[m.Currency]
+	if !ok {
+		return m
+	}
+	return Money{Amount: m.Amount.Round(places), Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool { return m.Amount.IsZero() }
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool { return m.Amount.IsPositive() }
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool { return m.Amount.IsNegative() }
+
+// String formats the amount with its currency, e.g. "12.5 USD"
+func (m Money) String() string {
+	return m.Amount.String() + " " + string(m.Currency)
+}
+
+type moneyJSON struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a string so clients never round-trip it
+// through a float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Amount.String(), Currency: m.Currency})
+}
+
+// UnmarshalJSON accepts the amount as a string or a JSON number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency Currency        `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var amount string
+	decoder := json.NewDecoder(bytes.NewReader(raw.Amount))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidMoney, string(raw.Amount))
+	}
+	switch v := value.(type) {
+	case string:
+		amount = v
+	case json.Number:
+		amount = v.String()
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidMoney, string(raw.Amount))
+	}
+
+	parsed, err := ParseMoney(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
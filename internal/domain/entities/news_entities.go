@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// NewsArticle is a market news story from the news provider
+type NewsArticle struct {
+	ID          string    `json:"id"`
+	Headline    string    `json:"headline"`
+	Summary     string    `json:"summary,omitempty"`
+	Source      string    `json:"source"`
+	Author      string    `json:"author,omitempty"`
+	URL         string    `json:"url"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	Symbols     []string  `json:"symbols"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// PortfolioNewsItem is a story ranked for a user's holdings
+type PortfolioNewsItem struct {
+	NewsArticle
+	// MatchedSymbols are the story's symbols the user holds, heaviest first
+	MatchedSymbols []string `json:"matched_symbols"`
+	// Exposure is the share of the portfolio in the matched symbols
+	Exposure decimal.Decimal `json:"exposure"`
+	// Relevance orders the feed: exposure discounted by the story's age
+	Relevance decimal.Decimal `json:"relevance"`
+}
+
+// PortfolioNews is the news feed for a user's holdings. A user without
+// holdings gets general market news, with Personalized unset.
+type PortfolioNews struct {
+	Articles     []*PortfolioNewsItem `json:"articles"`
+	Personalized bool                 `json:"personalized"`
+	GeneratedAt  time.Time            `json:"generated_at"`
+}
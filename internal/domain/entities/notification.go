@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,8 @@ const (
 	NotificationTypeSecurity    NotificationType = "security"
 	NotificationTypePortfolio   NotificationType = "portfolio"
 	NotificationTypeAllocation  NotificationType = "allocation"
+	NotificationTypeBilling     NotificationType = "billing"
+	NotificationTypeDigest      NotificationType = "digest"
 
 	ChannelEmail    NotificationChannel = "email"
 	ChannelPush     NotificationChannel = "push"
@@ -57,6 +60,75 @@ type UserPreference struct {
 	SecurityAlerts        bool      `json:"security_alerts" db:"security_alerts"`
 	PortfolioUpdates      bool      `json:"portfolio_updates" db:"portfolio_updates"`
 	MarketingEmails       bool      `json:"marketing_emails" db:"marketing_emails"`
+	// QuietHoursEnabled holds push and SMS between QuietHoursStart and
+	// QuietHoursEnd, as HH:MM in the user's profile time zone
+	QuietHoursEnabled     bool      `json:"quiet_hours_enabled" db:"quiet_hours_enabled"`
+	QuietHoursStart       string    `json:"quiet_hours_start" db:"quiet_hours_start"`
+	QuietHoursEnd         string    `json:"quiet_hours_end" db:"quiet_hours_end"`
+	// DigestLowPriority collects low-priority notifications into one daily
+	// summary sent at DigestHour local time
+	DigestLowPriority     bool      `json:"digest_low_priority" db:"digest_low_priority"`
+	DigestHour            int       `json:"digest_hour" db:"digest_hour"`
+	Timezone              string    `json:"timezone" db:"-"` // from the user's profile
 	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
 	CreatedAt             time.Time `json:"created_at" db:"created_at"`
 }
+
+// ErrInvalidPreferences is returned for notification preferences that fail validation
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+
+// DefaultUserPreference returns the preferences of a user who has not saved
+// any, matching the user_preferences column defaults
+func DefaultUserPreference(userID uuid.UUID) *UserPreference {
+	return &UserPreference{
+		UserID:             userID,
+		EmailNotifications: true,
+		PushNotifications:  true,
+		DepositAlerts:      true,
+		WithdrawalAlerts:   true,
+		TradeAlerts:        true,
+		SecurityAlerts:     true,
+		PortfolioUpdates:   true,
+		QuietHoursStart:    "22:00",
+		QuietHoursEnd:      "07:00",
+		DigestHour:         8,
+		Timezone:           DefaultTimezone,
+	}
+}
+
+// UpdateNotificationPreferencesRequest changes the fields that are set
+type UpdateNotificationPreferencesRequest struct {
+	EmailNotifications *bool   `json:"email_notifications,omitempty"`
+	PushNotifications  *bool   `json:"push_notifications,omitempty"`
+	SMSNotifications   *bool   `json:"sms_notifications,omitempty"`
+	DepositAlerts      *bool   `json:"deposit_alerts,omitempty"`
+	WithdrawalAlerts   *bool   `json:"withdrawal_alerts,omitempty"`
+	TradeAlerts        *bool   `json:"trade_alerts,omitempty"`
+	SecurityAlerts     *bool   `json:"security_alerts,omitempty"`
+	PortfolioUpdates   *bool   `json:"portfolio_updates,omitempty"`
+	MarketingEmails    *bool   `json:"marketing_emails,omitempty"`
+	QuietHoursEnabled  *bool   `json:"quiet_hours_enabled,omitempty"`
+	QuietHoursStart    *string `json:"quiet_hours_start,omitempty" example:"22:00"`
+	QuietHoursEnd      *string `json:"quiet_hours_end,omitempty" example:"07:00"`
+	DigestLowPriority  *bool   `json:"digest_low_priority,omitempty"`
+	DigestHour         *int    `json:"digest_hour,omitempty"`
+}
+
+// NotificationHoldReason is why a notification was held back
+type NotificationHoldReason string
+
+const (
+	NotificationHoldQuietHours NotificationHoldReason = "quiet_hours"
+	NotificationHoldDigest     NotificationHoldReason = "digest"
+)
+
+// HeldNotification is a notification waiting for the end of the user's
+// quiet hours or for their daily digest
+type HeldNotification struct {
+	ID           uuid.UUID              `json:"id"`
+	UserID       uuid.UUID              `json:"user_id"`
+	Reason       NotificationHoldReason `json:"reason"`
+	Notification *Notification          `json:"notification"`
+	DeliverAfter time.Time              `json:"deliver_after"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -76,6 +77,52 @@ func (s KYCStatus) IsValid() bool {
 	}
 }
 
+// KYCSubStatus says where a review in progress stands with the provider, or
+// that a rejection can be fixed by sending new documents. Final decisions
+// have none.
+type KYCSubStatus string
+
+const (
+	KYCSubStatusNone                 KYCSubStatus = ""
+	KYCSubStatusDocumentsRequested   KYCSubStatus = "documents_requested"   // the provider is waiting for documents
+	KYCSubStatusUnderReview          KYCSubStatus = "under_review"          // documents are in, the provider is checking them
+	KYCSubStatusOnHold               KYCSubStatus = "on_hold"               // held for manual checks by the provider
+	KYCSubStatusResubmissionRequired KYCSubStatus = "resubmission_required" // rejected, but new documents may be sent
+)
+
+// KYCProviderUpdate is a KYC provider webhook mapped onto our statuses
+type KYCProviderUpdate struct {
+	Event            string // the provider's event type, e.g. applicantOnHold
+	Status           KYCStatus
+	SubStatus        KYCSubStatus
+	RejectionReasons []string
+}
+
+// KYCProgressMessage tells the user where their verification stands
+func KYCProgressMessage(status KYCStatus, subStatus KYCSubStatus) string {
+	switch subStatus {
+	case KYCSubStatusDocumentsRequested:
+		return "We need a few documents to verify your identity. Upload them to continue."
+	case KYCSubStatusUnderReview:
+		return "We're checking your documents. This usually takes a few minutes."
+	case KYCSubStatusOnHold:
+		return "Your verification needs a closer look by our compliance team. There's nothing you need to do; we'll let you know when it's done."
+	case KYCSubStatusResubmissionRequired:
+		return "Some of your documents couldn't be verified. Upload new copies to continue."
+	}
+	switch status {
+	case KYCStatusApproved:
+		return "Your identity is verified."
+	case KYCStatusRejected:
+		return "We couldn't verify your identity."
+	case KYCStatusExpired:
+		return "Your verification has expired. Submit your documents again to renew it."
+	case KYCStatusProcessing:
+		return "Your verification is in progress."
+	}
+	return "Verify your identity to unlock all features."
+}
+
 // OnboardingStepType represents different steps in the onboarding flow
 type OnboardingStepType string
 
@@ -118,8 +165,10 @@ type UserProfile struct {
 	KYCSubmittedAt     *time.Time       `json:"kyc_submitted_at" db:"kyc_submitted_at"`
 	KYCApprovedAt      *time.Time       `json:"kyc_approved_at" db:"kyc_approved_at"`
 	KYCRejectionReason *string          `json:"kyc_rejection_reason" db:"kyc_rejection_reason"`
+	KYCPIIToken        *string          `json:"kyc_pii_token,omitempty" db:"kyc_pii_token"`
 	DueAccountID       *string          `json:"due_account_id" db:"due_account_id"`
 	AlpacaAccountID    *string          `json:"alpaca_account_id" db:"alpaca_account_id"`
+	Timezone           string           `json:"timezone" db:"timezone"`
 	IsActive           bool             `json:"is_active" db:"is_active"`
 	CreatedAt          time.Time        `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time        `json:"updated_at" db:"updated_at"`
@@ -224,11 +273,7 @@ func (u *UserProfile) GetAge() *int {
 	if u.DateOfBirth == nil {
 		return nil
 	}
-	now := time.Now()
-	age := now.Year() - u.DateOfBirth.Year()
-	if now.YearDay() < u.DateOfBirth.YearDay() {
-		age--
-	}
+	age := AgeOn(*u.DateOfBirth, time.Now())
 	return &age
 }
 
@@ -281,11 +326,14 @@ type KYCSubmission struct {
 	ProviderRef      string         `json:"provider_ref" db:"provider_ref"`
 	SubmissionType   string         `json:"submission_type" db:"submission_type"`
 	Status           KYCStatus      `json:"status" db:"status"`
+	SubStatus        KYCSubStatus   `json:"sub_status,omitempty" db:"sub_status"`
 	VerificationData map[string]any `json:"verification_data" db:"verification_data"`
 	RejectionReasons []string       `json:"rejection_reasons" db:"rejection_reasons"`
 	SubmittedAt      time.Time      `json:"submitted_at" db:"submitted_at"`
 	ReviewedAt       *time.Time     `json:"reviewed_at" db:"reviewed_at"`
 	ExpiresAt        *time.Time     `json:"expires_at" db:"expires_at"`
+	Documents        []KYCDocument  `json:"documents" db:"documents"`
+	PIIToken         *string        `json:"pii_token,omitempty" db:"pii_token"` // personal info and document links in the PII vault
 	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
 }
@@ -312,6 +360,149 @@ func (k *KYCSubmission) MarkReviewed(status KYCStatus, rejectionReasons []string
 	}
 }
 
+// KYCDocumentStatus is the review state of one document in a submission
+type KYCDocumentStatus string
+
+const (
+	KYCDocumentStatusSubmitted KYCDocumentStatus = "submitted"
+	KYCDocumentStatusAccepted  KYCDocumentStatus = "accepted"
+	KYCDocumentStatusRejected  KYCDocumentStatus = "rejected"
+	// KYCDocumentStatusDeleted marks a rejected document the user removed
+	// before uploading its replacement
+	KYCDocumentStatusDeleted KYCDocumentStatus = "deleted"
+)
+
+var (
+	ErrKYCSubmissionNotFound = errors.New("KYC submission not found")
+	ErrKYCDocumentNotFound   = errors.New("KYC document not found")
+	// ErrKYCDocumentNotRejected is returned when a user tries to remove or
+	// replace a document that has not been rejected
+	ErrKYCDocumentNotRejected = errors.New("only rejected KYC documents can be removed or replaced")
+	// ErrPartialKYCResubmissionUnsupported is returned by KYC providers that
+	// can only review a complete new submission
+	ErrPartialKYCResubmissionUnsupported = errors.New("KYC provider does not support partial resubmission")
+)
+
+// KYCDocument is one uploaded document and its review outcome. A submission
+// holds at most one document per type; replacing it bumps the revision.
+type KYCDocument struct {
+	Type            string            `json:"type"`
+	UploadID        *uuid.UUID        `json:"upload_id,omitempty"`
+	FileURL         string            `json:"file_url,omitempty"`
+	ContentType     string            `json:"content_type"`
+	Status          KYCDocumentStatus `json:"status"`
+	RejectionReason *string           `json:"rejection_reason,omitempty"`
+	Revision        int               `json:"revision"`
+	SubmittedAt     time.Time         `json:"submitted_at"`
+	ReviewedAt      *time.Time        `json:"reviewed_at,omitempty"`
+}
+
+// Upload returns the document in the form sent to KYC providers
+func (d *KYCDocument) Upload() KYCDocumentUpload {
+	return KYCDocumentUpload{Type: d.Type, UploadID: d.UploadID, FileURL: d.FileURL, ContentType: d.ContentType}
+}
+
+// NeedsReplacement reports whether the document must be uploaded again
+// before the submission can go back for review
+func (d *KYCDocument) NeedsReplacement() bool {
+	return d.Status == KYCDocumentStatusRejected || d.Status == KYCDocumentStatusDeleted
+}
+
+// KYCDocumentReview is a provider's verdict on a single document
+type KYCDocumentReview struct {
+	Type     string
+	Accepted bool
+	Reason   string
+}
+
+// NewKYCDocuments records the uploads of a new submission
+func NewKYCDocuments(uploads []KYCDocumentUpload, submittedAt time.Time) []KYCDocument {
+	documents := make([]KYCDocument, 0, len(uploads))
+	for _, upload := range uploads {
+		documents = append(documents, KYCDocument{
+			Type:        upload.Type,
+			UploadID:    upload.UploadID,
+			FileURL:     upload.FileURL,
+			ContentType: upload.ContentType,
+			Status:      KYCDocumentStatusSubmitted,
+			Revision:    1,
+			SubmittedAt: submittedAt,
+		})
+	}
+	return documents
+}
+
+// Document returns the submission's document of the given type
+func (k *KYCSubmission) Document(docType string) *KYCDocument {
+	for i := range k.Documents {
+		if strings.EqualFold(k.Documents[i].Type, docType) {
+			return &k.Documents[i]
+		}
+	}
+	return nil
+}
+
+// PendingReplacements returns the types of documents that still have to be
+// uploaded again
+func (k *KYCSubmission) PendingReplacements() []string {
+	var types []string
+	for _, doc := range k.Documents {
+		if doc.NeedsReplacement() {
+			types = append(types, doc.Type)
+		}
+	}
+	return types
+}
+
+// ApplyDocumentReviews sets document statuses after the submission was
+// reviewed. An approval accepts every document. A rejection applies the
+// provider's per-document verdicts; documents the provider did not mention
+// are accepted, and without any verdicts every document is rejected.
+func (k *KYCSubmission) ApplyDocumentReviews(status KYCStatus, reviews []KYCDocumentReview, reviewedAt time.Time) {
+	switch status {
+	case KYCStatusApproved, KYCStatusRejected:
+	default:
+		return
+	}
+
+	byType := make(map[string]KYCDocumentReview, len(reviews))
+	for _, review := range reviews {
+		byType[strings.ToLower(review.Type)] = review
+	}
+
+	for i := range k.Documents {
+		doc := &k.Documents[i]
+		if doc.Status != KYCDocumentStatusSubmitted {
+			continue
+		}
+		doc.ReviewedAt = &reviewedAt
+		doc.RejectionReason = nil
+
+		if status == KYCStatusApproved {
+			doc.Status = KYCDocumentStatusAccepted
+			continue
+		}
+
+		review, ok := byType[strings.ToLower(doc.Type)]
+		switch {
+		case len(reviews) == 0:
+			doc.Status = KYCDocumentStatusRejected
+			if len(k.RejectionReasons) > 0 {
+				reason := strings.Join(k.RejectionReasons, "; ")
+				doc.RejectionReason = &reason
+			}
+		case ok && !review.Accepted:
+			doc.Status = KYCDocumentStatusRejected
+			if review.Reason != "" {
+				reason := review.Reason
+				doc.RejectionReason = &reason
+			}
+		default:
+			doc.Status = KYCDocumentStatusAccepted
+		}
+	}
+}
+
 // === API Request/Response Models ===
 
 // OnboardingStartRequest represents the request to start onboarding
@@ -338,6 +529,8 @@ type OnboardingStatusResponse struct {
 	WalletStatus     *WalletStatusSummary `json:"walletStatus,omitempty"`
 	CanProceed       bool                 `json:"canProceed"`
 	RequiredActions  []string             `json:"requiredActions,omitempty"`
+	// Experiments maps each onboarding experiment the user is in to their variant
+	Experiments map[string]string `json:"experiments,omitempty"`
 }
 
 // WalletStatusSummary provides a summary of wallet provisioning status
@@ -363,6 +556,9 @@ type KYCStatusResponse struct {
 	RejectionReason   *string    `json:"rejectionReason,omitempty"`
 	ProviderReference *string    `json:"providerReference,omitempty"`
 	NextSteps         []string   `json:"nextSteps,omitempty"`
+	// Progress of the latest submission with the provider
+	SubStatus       KYCSubStatus `json:"subStatus,omitempty"`
+	ProgressMessage string       `json:"progressMessage"`
 }
 
 // KYCSubmitRequest represents KYC submission request
@@ -373,11 +569,28 @@ type KYCSubmitRequest struct {
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// KYCDocumentUpload represents a document uploaded for KYC
+// KYCDocumentUpload represents a document uploaded for KYC. A document is
+// either a completed upload referenced by UploadID or a file URL; uploads
+// are resolved to a fetchable URL before they reach the provider.
 type KYCDocumentUpload struct {
-	Type        string `json:"type" validate:"required"` // passport, drivers_license, etc.
-	FileURL     string `json:"fileUrl" validate:"required,url"`
-	ContentType string `json:"contentType" validate:"required"`
+	Type        string     `json:"type" validate:"required"` // passport, drivers_license, etc.
+	UploadID    *uuid.UUID `json:"uploadId,omitempty"`
+	FileURL     string     `json:"fileUrl,omitempty" validate:"required_without=UploadID,omitempty,url"`
+	ContentType string     `json:"contentType,omitempty" validate:"required_without=UploadID"`
+}
+
+// StoredDocuments returns documents as they are persisted: a document that
+// references an upload keeps the reference and drops the expiring link it
+// was resolved to
+func StoredDocuments(documents []KYCDocumentUpload) []KYCDocumentUpload {
+	stored := make([]KYCDocumentUpload, len(documents))
+	for i, doc := range documents {
+		if doc.UploadID != nil {
+			doc.FileURL = ""
+		}
+		stored[i] = doc
+	}
+	return stored
 }
 
 // KYCPersonalInfo represents personal information for KYC
@@ -416,6 +629,11 @@ type OnboardingCompleteResponse struct {
 	AlpacaAccountID string    `json:"alpacaAccountId"`
 	Message         string    `json:"message"`
 	NextSteps       []string  `json:"nextSteps"`
+
+	// Jurisdiction requirements for the user's country
+	RequiredDisclosures []string              `json:"requiredDisclosures,omitempty"`
+	KYCLevel            string                `json:"kycLevel,omitempty"`
+	AllowedFeatures     []JurisdictionFeature `json:"allowedFeatures,omitempty"`
 }
 
 
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +28,32 @@ const (
 	OnboardingJobTypeWalletOnly     OnboardingJobType = "wallet_only"
 )
 
+// OnboardingStepStatus represents the status of one saga step in a job
+type OnboardingStepStatus string
+
+const (
+	OnboardingStepStatusPending     OnboardingStepStatus = "pending"
+	OnboardingStepStatusCompleted   OnboardingStepStatus = "completed"
+	OnboardingStepStatusFailed      OnboardingStepStatus = "failed"
+	OnboardingStepStatusCompensated OnboardingStepStatus = "compensated"
+)
+
+// ErrOnboardingJobNotFound is returned when an onboarding job does not exist
+var ErrOnboardingJobNotFound = errors.New("onboarding job not found")
+
+// OnboardingJobStep records the progress of one saga step. Data holds what
+// the step needs to undo itself, such as the status it replaced.
+type OnboardingJobStep struct {
+	Name          string               `json:"name"`
+	Status        OnboardingStepStatus `json:"status"`
+	Attempts      int                  `json:"attempts"`
+	Error         *string              `json:"error,omitempty"`
+	Data          map[string]string    `json:"data,omitempty"`
+	StartedAt     *time.Time           `json:"startedAt,omitempty"`
+	CompletedAt   *time.Time           `json:"completedAt,omitempty"`
+	CompensatedAt *time.Time           `json:"compensatedAt,omitempty"`
+}
+
 // OnboardingJob represents an async onboarding job
 type OnboardingJob struct {
 	ID           uuid.UUID              `json:"id" db:"id"`
@@ -34,6 +61,7 @@ type OnboardingJob struct {
 	Status       OnboardingJobStatus    `json:"status" db:"status"`
 	JobType      OnboardingJobType      `json:"jobType" db:"job_type"`
 	Payload      map[string]interface{} `json:"payload" db:"payload"`
+	Steps        []OnboardingJobStep    `json:"steps" db:"steps"`
 	AttemptCount int                    `json:"attemptCount" db:"attempt_count"`
 	MaxAttempts  int                    `json:"maxAttempts" db:"max_attempts"`
 	NextRetryAt  *time.Time             `json:"nextRetryAt" db:"next_retry_at"`
@@ -79,6 +107,28 @@ func (j *OnboardingJob) MarkFailed(errorMsg string, retryDelay time.Duration) {
 	}
 }
 
+// Step returns the named step, adding it as pending when the job has not
+// reached it yet
+func (j *OnboardingJob) Step(name string) *OnboardingJobStep {
+	for i := range j.Steps {
+		if j.Steps[i].Name == name {
+			return &j.Steps[i]
+		}
+	}
+	j.Steps = append(j.Steps, OnboardingJobStep{Name: name, Status: OnboardingStepStatusPending})
+	return &j.Steps[len(j.Steps)-1]
+}
+
+// Requeue puts a failed or abandoned job back on the queue with a fresh
+// attempt budget. Completed steps are kept and will be skipped.
+func (j *OnboardingJob) Requeue() {
+	j.Status = OnboardingJobStatusQueued
+	j.AttemptCount = 0
+	j.NextRetryAt = nil
+	j.ErrorMessage = nil
+	j.UpdatedAt = time.Now()
+}
+
 // IsRetryable checks if the job can be retried
 func (j *OnboardingJob) IsRetryable() bool {
 	return j.Status == OnboardingJobStatusRetry &&
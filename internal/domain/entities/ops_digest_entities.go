@@ -0,0 +1,55 @@
+package entities
+
+import "time"
+
+// OpsDigest summarizes one UTC day of platform activity for administrators
+type OpsDigest struct {
+	Date           string                  `json:"date"` // YYYY-MM-DD, UTC
+	From           time.Time               `json:"from"`
+	To             time.Time               `json:"to"`
+	Signups        int                     `json:"signups"`
+	KYC            OpsDigestKYC            `json:"kyc"`
+	Deposits       OpsDigestVolume         `json:"deposits"`
+	Withdrawals    OpsDigestVolume         `json:"withdrawals"`
+	FailedJobs     OpsDigestFailedJobs     `json:"failed_jobs"`
+	Reconciliation OpsDigestReconciliation `json:"reconciliation"`
+	AIUsage        OpsDigestAIUsage        `json:"ai_usage"`
+	GeneratedAt    time.Time               `json:"generated_at"`
+}
+
+// OpsDigestKYC counts KYC decisions reviewed during the day
+type OpsDigestKYC struct {
+	Approved int     `json:"approved"`
+	Rejected int     `json:"rejected"`
+	PassRate float64 `json:"pass_rate"` // approved / (approved + rejected), 0 when nothing was reviewed
+}
+
+// OpsDigestVolume counts money movements created during the day
+type OpsDigestVolume struct {
+	Count  int    `json:"count"`
+	Volume string `json:"volume"` // USD, excluding failed movements
+	Failed int    `json:"failed"`
+}
+
+// OpsDigestFailedJobs counts background jobs that failed for good during the day
+type OpsDigestFailedJobs struct {
+	Onboarding         int `json:"onboarding"`
+	WalletProvisioning int `json:"wallet_provisioning"`
+	FundingEvents      int `json:"funding_events"`
+	WebhookDeliveries  int `json:"webhook_deliveries"`
+	Total              int `json:"total"`
+}
+
+// OpsDigestReconciliation counts reconciliation exceptions raised during the day
+type OpsDigestReconciliation struct {
+	Exceptions int            `json:"exceptions"`
+	Unresolved int            `json:"unresolved"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// OpsDigestAIUsage counts AI assistant activity during the day
+type OpsDigestAIUsage struct {
+	ChatMessages    int `json:"chat_messages"` // assistant replies
+	ActiveChatUsers int `json:"active_chat_users"`
+	Summaries       int `json:"summaries"`
+}
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OrderInterventionAction is what an administrator did to a stuck order
+type OrderInterventionAction string
+
+const (
+	OrderInterventionCancel   OrderInterventionAction = "cancel"
+	OrderInterventionRepair   OrderInterventionAction = "repair"
+	OrderInterventionAnnotate OrderInterventionAction = "annotate"
+)
+
+// IsInFlight reports whether the order is waiting on the brokerage
+func (s OrderStatus) IsInFlight() bool {
+	return s == OrderStatusAccepted || s == OrderStatusPending || s == OrderStatusPartiallyFilled
+}
+
+// StuckOrder is an in-flight order that has not moved within the SLA, with
+// the latest resolution note left on it
+type StuckOrder struct {
+	Order
+	StuckFor       string     `json:"stuck_for"`
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// OrderIntervention records one administrator action on an order and the
+// compensating balance and position changes it made
+type OrderIntervention struct {
+	ID                    uuid.UUID               `json:"id" db:"id"`
+	OrderID               uuid.UUID               `json:"order_id" db:"order_id"`
+	AdminID               uuid.UUID               `json:"admin_id" db:"admin_id"`
+	Action                OrderInterventionAction `json:"action" db:"action"`
+	PreviousStatus        OrderStatus             `json:"previous_status" db:"previous_status"`
+	NewStatus             OrderStatus             `json:"new_status" db:"new_status"`
+	BuyingPowerDelta      decimal.Decimal         `json:"buying_power_delta" db:"buying_power_delta"`
+	PositionQuantityDelta decimal.Decimal         `json:"position_quantity_delta" db:"position_quantity_delta"`
+	PositionValueDelta    decimal.Decimal         `json:"position_value_delta" db:"position_value_delta"`
+	Note                  string                  `json:"note" db:"note"`
+	CreatedAt             time.Time               `json:"created_at" db:"created_at"`
+}
+
+// OrderRepairRequest describes the compensating entries that settle a stuck
+// order. Deltas are signed; the order moves to Status once they are applied.
+type OrderRepairRequest struct {
+	Status                OrderStatus `json:"status" binding:"required,oneof=filled failed canceled"`
+	BuyingPowerDelta      string      `json:"buying_power_delta"`
+	PositionQuantityDelta string      `json:"position_quantity_delta"`
+	PositionValueDelta    string      `json:"position_value_delta"`
+	Note                  string      `json:"note" binding:"required,max=1000"`
+}
@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbound webhook errors
+var (
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookEventType identifies an event partners can subscribe to
+type WebhookEventType string
+
+const (
+	WebhookEventKYCApproved      WebhookEventType = "user.kyc_approved"
+	WebhookEventOnboardingChange WebhookEventType = "user.onboarding_changed"
+	WebhookEventDepositConfirmed WebhookEventType = "deposit.confirmed"
+	WebhookEventOrderFilled      WebhookEventType = "order.filled"
+	WebhookEventOpsDigest        WebhookEventType = "ops.daily_digest"
+	WebhookEventPing             WebhookEventType = "webhook.ping"
+)
+
+// SubscribableWebhookEvents lists the events an endpoint may subscribe to
+var SubscribableWebhookEvents = []WebhookEventType{
+	WebhookEventKYCApproved,
+	WebhookEventOnboardingChange,
+	WebhookEventDepositConfirmed,
+	WebhookEventOrderFilled,
+	WebhookEventOpsDigest,
+}
+
+// WebhookDeliveryStatus tracks a delivery through its retry schedule
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // failed attempt, retry scheduled
+	WebhookDeliveryDead      WebhookDeliveryStatus = "dead"   // retries exhausted
+)
+
+// WebhookEndpoint is a partner URL subscribed to our events
+type WebhookEndpoint struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	URL         string             `json:"url" db:"url"`
+	Description string             `json:"description" db:"description"`
+	EventTypes  []WebhookEventType `json:"event_types" db:"event_types"`
+	Secret      string             `json:"-" db:"secret"`
+	IsActive    bool               `json:"is_active" db:"is_active"`
+	CreatedBy   *uuid.UUID         `json:"created_by,omitempty" db:"created_by"`
+	// TenantID is set on endpoints a developer registered for their sandbox.
+	// They only receive test events.
+	TenantID  *uuid.UUID `json:"tenant_id,omitempty" db:"tenant_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Subscribes reports whether the endpoint receives an event type
+func (e *WebhookEndpoint) Subscribes(eventType WebhookEventType) bool {
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpointWithSecret is returned once, when an endpoint is created or its
+// secret is rotated
+type WebhookEndpointWithSecret struct {
+	*WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// WebhookEvent is the envelope posted to partner endpoints
+type WebhookEvent struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      interface{}      `json:"data"`
+}
+
+// WebhookDelivery is one attempt schedule for delivering an event to an endpoint
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id" db:"id"`
+	EndpointID     uuid.UUID             `json:"endpoint_id" db:"endpoint_id"`
+	EventID        uuid.UUID             `json:"event_id" db:"event_id"`
+	EventType      WebhookEventType      `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	ResponseStatus *int                  `json:"response_status,omitempty" db:"response_status"`
+	ResponseBody   *string               `json:"response_body,omitempty" db:"response_body"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+}
+
+// CreateWebhookEndpointRequest registers a partner endpoint
+type CreateWebhookEndpointRequest struct {
+	URL         string             `json:"url" binding:"required,url"`
+	Description string             `json:"description" binding:"max=255"`
+	EventTypes  []WebhookEventType `json:"event_types" binding:"required,min=1"`
+}
+
+// UpdateWebhookEndpointRequest changes an endpoint's subscription
+type UpdateWebhookEndpointRequest struct {
+	URL         *string            `json:"url,omitempty" binding:"omitempty,url"`
+	Description *string            `json:"description,omitempty" binding:"omitempty,max=255"`
+	EventTypes  []WebhookEventType `json:"event_types,omitempty"`
+	IsActive    *bool              `json:"is_active,omitempty"`
+}
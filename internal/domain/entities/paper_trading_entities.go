@@ -0,0 +1,33 @@
+package entities
+
+import "github.com/google/uuid"
+
+// TradingMode selects whether a user's orders reach the brokerage
+type TradingMode string
+
+const (
+	TradingModeLive  TradingMode = "live"
+	TradingModePaper TradingMode = "paper"
+)
+
+// TradingModeHeader flags every investing response with the mode it was served in
+const TradingModeHeader = "X-Trading-Mode"
+
+// TradingProfile is what decides a user's trading mode
+type TradingProfile struct {
+	Mode      TradingMode
+	KYCStatus string
+}
+
+// TradingModeStatus describes a user's trading mode and whether they can
+// switch to live trading
+type TradingModeStatus struct {
+	UserID           uuid.UUID   `json:"userId"`
+	Mode             TradingMode `json:"mode"`
+	Paper            bool        `json:"paper"`
+	PaperOnly        bool        `json:"paperOnly"` // live trading is not yet approved in the user's country
+	CanSwitchToLive  bool        `json:"canSwitchToLive"`
+	LiveBlockedBy    string      `json:"liveBlockedBy,omitempty"` // kyc or jurisdiction
+	PaperBuyingPower *string     `json:"paperBuyingPower,omitempty"`
+	StartingBalance  *string     `json:"startingBalance,omitempty"`
+}
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrWeakPassword is wrapped by every password policy rejection
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// PasswordViolation names one password policy rule a password breaks
+type PasswordViolation string
+
+const (
+	PasswordTooShort     PasswordViolation = "too_short"
+	PasswordTooLong      PasswordViolation = "too_long"
+	PasswordTooSimple    PasswordViolation = "too_few_character_types" // not enough of lowercase, uppercase, digits and symbols
+	PasswordPersonalInfo PasswordViolation = "contains_personal_info"  // derived from the user's email or phone
+	PasswordBreached     PasswordViolation = "breached"                // seen in a known data breach
+)
+
+// PasswordPolicyError lists every rule a rejected password breaks
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	names := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		names[i] = string(v)
+	}
+	return fmt.Sprintf("%s: %s", ErrWeakPassword, strings.Join(names, ", "))
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// PasswordNotice asks a user who signed in with a password that no longer
+// meets the policy to change it. Until ChangeBy the prompt can be dismissed;
+// after it clients must require the change before continuing.
+type PasswordNotice struct {
+	Violations []PasswordViolation `json:"violations"`
+	ChangeBy   time.Time           `json:"changeBy"`
+	Required   bool                `json:"required"`
+}
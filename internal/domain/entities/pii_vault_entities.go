@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PII vault errors
+var (
+	ErrPIIRecordNotFound = errors.New("PII record not found")
+	ErrPIIAccessDenied   = errors.New("PII access denied")
+)
+
+// PIIKind is the kind of personal data held in a vault record. Accessors are
+// granted kinds, not individual records.
+type PIIKind string
+
+const (
+	// PIIKindKYCIdentity is the personal info and document references a user
+	// submitted for KYC, stored as a KYCVaultRecord
+	PIIKindKYCIdentity PIIKind = "kyc_identity"
+)
+
+// Vault access log actions
+const (
+	PIIAccessStore  = "store"
+	PIIAccessUpdate = "update"
+	PIIAccessReveal = "reveal"
+	PIIAccessDenied = "denied"
+)
+
+// PIIVaultRecord is one encrypted value in the PII vault, referenced from the
+// main database by its token
+type PIIVaultRecord struct {
+	Token      string    `json:"token"`
+	SubjectID  uuid.UUID `json:"subject_id"`
+	Kind       PIIKind   `json:"kind"`
+	Ciphertext string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// PIIAccessEntry records one use of a vault record
+type PIIAccessEntry struct {
+	ID         uuid.UUID  `json:"id"`
+	Token      string     `json:"token"`
+	SubjectID  *uuid.UUID `json:"subject_id,omitempty"`
+	Kind       PIIKind    `json:"kind,omitempty"`
+	Accessor   string     `json:"accessor"`
+	Action     string     `json:"action"`
+	Purpose    string     `json:"purpose"`
+	AccessedAt time.Time  `json:"accessed_at"`
+}
+
+// KYCVaultRecord is the raw KYC data kept out of kyc_submissions: the
+// personal info sent to the provider and the URLs of documents that were not
+// uploaded through us, by document type
+type KYCVaultRecord struct {
+	PersonalInfo *KYCPersonalInfo  `json:"personal_info,omitempty"`
+	DocumentURLs map[string]string `json:"document_urls,omitempty"`
+}
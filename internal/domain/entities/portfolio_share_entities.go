@@ -0,0 +1,92 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Portfolio sharing errors
+var (
+	ErrSharingDisabled      = errors.New("portfolio sharing is turned off")
+	ErrShareLinkNotFound    = errors.New("share link not found")
+	ErrShareLinkLimit       = errors.New("too many active share links")
+	ErrShareLinkInvalid     = errors.New("share link is invalid")
+	ErrShareLinkUnavailable = errors.New("share link has expired or was revoked")
+)
+
+// PortfolioShareSnapshot is a user's performance frozen when a share link is
+// made. It holds only ratios (0.042 is 4.2%) and basket names: no amounts,
+// quantities or anything that identifies the user.
+type PortfolioShareSnapshot struct {
+	Range           AttributionRange       `json:"range"`
+	Start           time.Time              `json:"start"`
+	End             time.Time              `json:"end"`
+	Benchmark       string                 `json:"benchmark"`
+	BenchmarkReturn decimal.Decimal        `json:"benchmark_return"`
+	PortfolioReturn decimal.Decimal        `json:"portfolio_return"`
+	ActiveReturn    decimal.Decimal        `json:"active_return"`
+	Baskets         []PortfolioShareBasket `json:"baskets"`
+	GeneratedAt     time.Time              `json:"generated_at"`
+}
+
+// PortfolioShareBasket is one basket's weight, return and contribution to the
+// shared portfolio return
+type PortfolioShareBasket struct {
+	Name         string          `json:"name"`
+	Weight       decimal.Decimal `json:"weight"`
+	Return       decimal.Decimal `json:"return"`
+	Contribution decimal.Decimal `json:"contribution"`
+}
+
+// PortfolioShareLink is a public link to one snapshot
+type PortfolioShareLink struct {
+	ID           uuid.UUID              `json:"id"`
+	UserID       uuid.UUID              `json:"-"`
+	Snapshot     PortfolioShareSnapshot `json:"snapshot"`
+	ExpiresAt    time.Time              `json:"expires_at"`
+	RevokedAt    *time.Time             `json:"revoked_at,omitempty"`
+	ViewCount    int                    `json:"view_count"`
+	LastViewedAt *time.Time             `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	// URL is the signed public link; it is derived, not stored
+	URL string `json:"url,omitempty"`
+}
+
+// IsLive reports whether the link can be viewed at t
+func (l *PortfolioShareLink) IsLive(t time.Time) bool {
+	return l.RevokedAt == nil && t.Before(l.ExpiresAt)
+}
+
+// PortfolioSharingSettings are a user's controls over their share links
+type PortfolioSharingSettings struct {
+	UserID uuid.UUID `json:"user_id"`
+	// Enabled false stops every link from being viewed and new ones from
+	// being made; unexpired links work again if sharing is turned back on
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreatePortfolioShareRequest makes a share link
+type CreatePortfolioShareRequest struct {
+	Range          AttributionRange `json:"range" binding:"required,oneof=1W 1M 3M 6M 1Y YTD"`
+	ExpiresInHours int              `json:"expires_in_hours" binding:"min=0"` // 0 uses the default
+}
+
+// UpdatePortfolioSharingRequest turns sharing on or off
+type UpdatePortfolioSharingRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SharedPortfolio is what the public link shows
+type SharedPortfolio struct {
+	Snapshot  PortfolioShareSnapshot `json:"snapshot"`
+	ExpiresAt time.Time              `json:"expires_at"`
+}
+
+// RevokePortfolioSharesResponse reports how many links were revoked
+type RevokePortfolioSharesResponse struct {
+	Revoked int `json:"revoked"`
+}
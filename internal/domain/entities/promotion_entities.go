@@ -0,0 +1,123 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Promotion errors
+var (
+	ErrPromotionNotFound       = errors.New("promotion not found")
+	ErrPromotionInactive       = errors.New("promotion is not active")
+	ErrPromotionAlreadyGranted = errors.New("promotion already granted to user")
+	ErrPromotionLimitReached   = errors.New("promotion grant limit reached")
+	ErrPromotionCodeTaken      = errors.New("promotion code already exists")
+)
+
+// PromotionTrigger decides how a promotion is granted
+type PromotionTrigger string
+
+const (
+	// PromotionTriggerManual promotions are only granted by an admin
+	PromotionTriggerManual PromotionTrigger = "manual"
+	// PromotionTriggerSignup promotions are granted when a user's KYC is approved
+	PromotionTriggerSignup PromotionTrigger = "signup"
+	// PromotionTriggerFirstDeposit promotions are granted on the user's first
+	// credited deposit during the campaign
+	PromotionTriggerFirstDeposit PromotionTrigger = "first_deposit"
+)
+
+// Promotion is a marketing campaign that grants promotional credit
+type Promotion struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	Code        string           `json:"code" db:"code"`
+	Name        string           `json:"name" db:"name"`
+	Description *string          `json:"description,omitempty" db:"description"`
+	Amount      decimal.Decimal  `json:"amount" db:"amount"`
+	Trigger     PromotionTrigger `json:"trigger" db:"trigger"`
+	// VestingDays is how long the account must stay open before the credit
+	// becomes available; closing the account earlier forfeits it
+	VestingDays int        `json:"vesting_days" db:"vesting_days"`
+	MaxGrants   *int       `json:"max_grants,omitempty" db:"max_grants"`
+	GrantCount  int        `json:"grant_count" db:"grant_count"`
+	Active      bool       `json:"active" db:"active"`
+	StartsAt    *time.Time `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty" db:"ends_at"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsRunning reports whether the promotion can be granted at t
+func (p *Promotion) IsRunning(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// PromotionGrantStatus tracks promotional credit from grant to settlement
+type PromotionGrantStatus string
+
+const (
+	PromotionGrantUnvested  PromotionGrantStatus = "unvested"
+	PromotionGrantVested    PromotionGrantStatus = "vested"
+	PromotionGrantForfeited PromotionGrantStatus = "forfeited"
+)
+
+// PromotionGrant is promotional credit granted to one user
+type PromotionGrant struct {
+	ID               uuid.UUID            `json:"id" db:"id"`
+	PromotionID      uuid.UUID            `json:"promotion_id" db:"promotion_id"`
+	PromotionCode    string               `json:"promotion_code" db:"promotion_code"`
+	UserID           uuid.UUID            `json:"user_id" db:"user_id"`
+	Amount           decimal.Decimal      `json:"amount" db:"amount"`
+	Status           PromotionGrantStatus `json:"status" db:"status"`
+	VestsAt          time.Time            `json:"vests_at" db:"vests_at"`
+	GrantedBy        *uuid.UUID           `json:"granted_by,omitempty" db:"granted_by"`
+	GrantLedgerTxID  *uuid.UUID           `json:"grant_ledger_tx_id,omitempty" db:"grant_ledger_tx_id"`
+	SettleLedgerTxID *uuid.UUID           `json:"settle_ledger_tx_id,omitempty" db:"settle_ledger_tx_id"`
+	ForfeitReason    *string              `json:"forfeit_reason,omitempty" db:"forfeit_reason"`
+	SettledAt        *time.Time           `json:"settled_at,omitempty" db:"settled_at"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// PromotionSummary is a user's promotional credit, kept apart from cash balances
+type PromotionSummary struct {
+	Unvested decimal.Decimal   `json:"unvested"`
+	Vested   decimal.Decimal   `json:"vested"`
+	Grants   []*PromotionGrant `json:"grants"`
+}
+
+// CreatePromotionRequest defines a new promotion
+type CreatePromotionRequest struct {
+	Code        string           `json:"code" binding:"required,max=64"`
+	Name        string           `json:"name" binding:"required,max=200"`
+	Description *string          `json:"description,omitempty"`
+	Amount      decimal.Decimal  `json:"amount"`
+	Trigger     PromotionTrigger `json:"trigger" binding:"required,oneof=manual signup first_deposit"`
+	VestingDays int              `json:"vesting_days" binding:"min=0,max=3650"`
+	MaxGrants   *int             `json:"max_grants,omitempty" binding:"omitempty,min=1"`
+	StartsAt    *time.Time       `json:"starts_at,omitempty"`
+	EndsAt      *time.Time       `json:"ends_at,omitempty"`
+}
+
+// UpdatePromotionRequest pauses or resumes a promotion
+type UpdatePromotionRequest struct {
+	Active bool `json:"active"`
+}
+
+// GrantPromotionRequest grants a promotion to a user by hand
+type GrantPromotionRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Reactivation errors
+var (
+	ErrReactivationNotFound   = errors.New("reactivation request not found")
+	ErrAccountNotClosed       = errors.New("account is not closed")
+	ErrReactivationNotPending = errors.New("reactivation request is not awaiting this step")
+)
+
+// AccountClosureReason records why an account was deactivated
+type AccountClosureReason string
+
+const (
+	ClosureUserRequest AccountClosureReason = "user_request"
+	ClosureAdmin       AccountClosureReason = "admin"
+	// ClosureForCause marks accounts closed for fraud, abuse or compliance
+	// reasons; reopening them needs an admin decision
+	ClosureForCause AccountClosureReason = "for_cause"
+)
+
+// AccountClosure is the closure state of a user account
+type AccountClosure struct {
+	UserID   uuid.UUID            `json:"user_id" db:"id"`
+	Email    string               `json:"email" db:"email"`
+	IsActive bool                 `json:"is_active" db:"is_active"`
+	ClosedAt *time.Time           `json:"closed_at,omitempty" db:"closed_at"`
+	Reason   AccountClosureReason `json:"reason,omitempty" db:"closure_reason"`
+	Notes    *string              `json:"notes,omitempty" db:"closure_notes"`
+}
+
+// ReactivationStatus tracks a reactivation request
+type ReactivationStatus string
+
+const (
+	ReactivationPendingVerification ReactivationStatus = "pending_verification"
+	ReactivationPendingApproval     ReactivationStatus = "pending_approval"
+	ReactivationCompleted           ReactivationStatus = "completed"
+	ReactivationRejected            ReactivationStatus = "rejected"
+)
+
+// AccountHoldings is a snapshot of the balances and positions held on an account
+type AccountHoldings struct {
+	BuyingPower    decimal.Decimal `json:"buying_power"`
+	PositionCount  int             `json:"position_count"`
+	PositionsValue decimal.Decimal `json:"positions_value"`
+	CapturedAt     time.Time       `json:"captured_at"`
+}
+
+// Matches reports whether two snapshots hold the same balances and positions
+func (h *AccountHoldings) Matches(other *AccountHoldings) bool {
+	return h.BuyingPower.Equal(other.BuyingPower) &&
+		h.PositionCount == other.PositionCount &&
+		h.PositionsValue.Equal(other.PositionsValue)
+}
+
+// ReactivationRequest is a closed account's request to be reopened. Identity
+// re-verification and compliance re-screening run first; accounts closed for
+// cause then wait for an admin decision.
+type ReactivationRequest struct {
+	ID               uuid.UUID            `json:"id" db:"id"`
+	UserID           uuid.UUID            `json:"user_id" db:"user_id"`
+	Status           ReactivationStatus   `json:"status" db:"status"`
+	ClosureReason    AccountClosureReason `json:"closure_reason" db:"closure_reason"`
+	RequiresApproval bool                 `json:"requires_approval" db:"requires_approval"`
+	VerificationURL  *string              `json:"verification_url,omitempty" db:"verification_url"`
+	ScreenedCountry  *string              `json:"screened_country,omitempty" db:"screened_country"`
+	CaseID           *uuid.UUID           `json:"case_id,omitempty" db:"case_id"`
+	PriorHoldings    *AccountHoldings     `json:"prior_holdings,omitempty" db:"prior_holdings"`
+	RestoredHoldings *AccountHoldings     `json:"restored_holdings,omitempty" db:"restored_holdings"`
+	RejectionReason  *string              `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	ReviewedBy       *uuid.UUID           `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNotes      *string              `json:"review_notes,omitempty" db:"review_notes"`
+	CompletedAt      *time.Time           `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// IsOpen reports whether the request is still in progress
+func (r *ReactivationRequest) IsOpen() bool {
+	return r.Status == ReactivationPendingVerification || r.Status == ReactivationPendingApproval
+}
+
+// StartReactivationRequest authenticates a closed account asking to be reopened
+type StartReactivationRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ReviewReactivationRequest is an admin's decision on a for-cause reactivation
+type ReviewReactivationRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
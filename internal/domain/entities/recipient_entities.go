@@ -0,0 +1,204 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Withdrawal recipient errors
+var (
+	ErrRecipientNotFound = errors.New("withdrawal recipient not found")
+	ErrRecipientExists   = errors.New("this destination is already in your address book")
+)
+
+// RecipientKind is the type of destination a saved recipient holds
+type RecipientKind string
+
+const (
+	RecipientKindCrypto RecipientKind = "crypto"
+	RecipientKindBank   RecipientKind = "bank"
+)
+
+// RecipientStatus is the verification state of a saved recipient
+type RecipientStatus string
+
+const (
+	RecipientStatusUnverified RecipientStatus = "unverified" // saved, not yet used
+	RecipientStatusVerified   RecipientStatus = "verified"   // used for a withdrawal after its hold
+	RecipientStatusFlagged    RecipientStatus = "flagged"    // blocked by an admin
+)
+
+// AccountCheckStatus is the result of checking a bank recipient with the
+// bank-linking provider before ACH payouts
+type AccountCheckStatus string
+
+const (
+	AccountCheckUnchecked AccountCheckStatus = "unchecked"
+	AccountCheckVerified  AccountCheckStatus = "verified" // open and held in the user's name
+	AccountCheckFailed    AccountCheckStatus = "failed"   // see AccountCheckReason
+)
+
+// Reasons a bank account check fails. Paying out to these accounts would
+// come back as an ACH return.
+const (
+	AccountCheckReasonNotFound      = "account_not_found" // R03
+	AccountCheckReasonClosed        = "account_closed"    // R02
+	AccountCheckReasonOwnerMismatch = "owner_mismatch"
+)
+
+// AddressCheckStatus is how far a crypto recipient is through proving the
+// user controls the address
+type AddressCheckStatus string
+
+const (
+	AddressCheckUnchecked AddressCheckStatus = "unchecked"
+	AddressCheckPending   AddressCheckStatus = "pending"  // waiting for the amount received or the signature
+	AddressCheckVerified  AddressCheckStatus = "verified" // the user proved control of the address
+	AddressCheckFailed    AddressCheckStatus = "failed"   // too many wrong answers; a new check can be started
+	AddressCheckExpired   AddressCheckStatus = "expired"  // not confirmed in time; a new check can be started
+)
+
+// AddressCheckMethod is how the user proves control of a crypto address
+type AddressCheckMethod string
+
+const (
+	// AddressCheckMicroDeposit sends a small random amount to the address
+	// that the user confirms to the last digit
+	AddressCheckMicroDeposit AddressCheckMethod = "micro_deposit"
+	// AddressCheckSignature has the user sign a message with the address's key
+	AddressCheckSignature AddressCheckMethod = "signature"
+)
+
+// BankAccountCheck is what the bank-linking provider knows about an account
+type BankAccountCheck struct {
+	Found      bool
+	Open       bool
+	OwnerNames []string
+	Reference  string // provider request ID
+}
+
+// WithdrawalRecipient is a saved withdrawal destination. New recipients are
+// held until HoldUntil before they can receive funds.
+type WithdrawalRecipient struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	UserID            uuid.UUID       `json:"user_id" db:"user_id"`
+	Kind              RecipientKind   `json:"kind" db:"kind"`
+	Label             string          `json:"label" db:"label"`
+	Chain             string          `json:"chain,omitempty" db:"chain"`
+	Address           string          `json:"address,omitempty" db:"address"`
+	BankName          string          `json:"bank_name,omitempty" db:"bank_name"`
+	AccountHolderName string          `json:"account_holder_name,omitempty" db:"account_holder_name"`
+	RoutingNumber     string          `json:"routing_number,omitempty" db:"routing_number"`
+	AccountNumber     string          `json:"-" db:"account_number"`
+	AccountLast4      string          `json:"account_last4,omitempty" db:"account_last4"`
+	Fingerprint       string          `json:"-" db:"fingerprint"`
+	Status            RecipientStatus `json:"status" db:"status"`
+	HoldUntil         time.Time       `json:"hold_until" db:"hold_until"`
+	FirstUsedAt       *time.Time      `json:"first_used_at,omitempty" db:"first_used_at"`
+	LastUsedAt        *time.Time      `json:"last_used_at,omitempty" db:"last_used_at"`
+	FlagReason        *string         `json:"flag_reason,omitempty" db:"flag_reason"`
+	FlaggedBy         *uuid.UUID      `json:"flagged_by,omitempty" db:"flagged_by"`
+	FlaggedAt         *time.Time      `json:"flagged_at,omitempty" db:"flagged_at"`
+	// Bank recipients only: whether the account was confirmed open and owned
+	// by the user, which ACH payouts above a configured amount require
+	AccountCheckStatus AccountCheckStatus `json:"account_check_status,omitempty" db:"account_check_status"`
+	AccountCheckReason *string            `json:"account_check_reason,omitempty" db:"account_check_reason"`
+	AccountCheckedAt   *time.Time         `json:"account_checked_at,omitempty" db:"account_checked_at"`
+	// Crypto recipients only: whether the user proved control of the
+	// address, which larger withdrawals to a new address require
+	AddressCheckStatus    AddressCheckStatus  `json:"address_check_status,omitempty" db:"address_check_status"`
+	AddressCheckMethod    AddressCheckMethod  `json:"address_check_method,omitempty" db:"address_check_method"`
+	AddressCheckAmount    decimal.NullDecimal `json:"-" db:"address_check_amount"` // micro-deposit the user must confirm
+	AddressCheckMessage   string              `json:"address_check_message,omitempty" db:"address_check_message"`
+	AddressCheckReference *string             `json:"address_check_reference,omitempty" db:"address_check_reference"` // micro-deposit transfer
+	AddressCheckAttempts  int                 `json:"address_check_attempts,omitempty" db:"address_check_attempts"`
+	AddressCheckExpiresAt *time.Time          `json:"address_check_expires_at,omitempty" db:"address_check_expires_at"`
+	AddressCheckedAt      *time.Time          `json:"address_checked_at,omitempty" db:"address_checked_at"`
+	CreatedAt             time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// OnHold reports whether the recipient's first-use hold is still running
+func (r *WithdrawalRecipient) OnHold(now time.Time) bool {
+	return now.Before(r.HoldUntil)
+}
+
+// AddressCheckState returns the address check status as of now, reporting
+// a pending check past its expiry as expired
+func (r *WithdrawalRecipient) AddressCheckState(now time.Time) AddressCheckStatus {
+	if r.AddressCheckStatus == AddressCheckPending && r.AddressCheckExpiresAt != nil && !now.Before(*r.AddressCheckExpiresAt) {
+		return AddressCheckExpired
+	}
+	return r.AddressCheckStatus
+}
+
+// DestinationKey is the normalized destination the recipient pays out to.
+// Two recipients with the same key send funds to the same place, whichever
+// account saved them. EVM addresses are case-insensitive and chains of one
+// family share addresses, so crypto keys are per family.
+func (r *WithdrawalRecipient) DestinationKey() string {
+	if r.Kind == RecipientKindBank {
+		return "bank:" + r.RoutingNumber + ":" + r.AccountNumber
+	}
+	family := ChainFamily("")
+	if info, ok := Chains().Lookup(r.Chain); ok {
+		family = info.Family
+	}
+	address := r.Address
+	if family == ChainFamilyEVM {
+		address = strings.ToLower(address)
+	}
+	return "crypto:" + string(family) + ":" + address
+}
+
+// CreateRecipientRequest saves a crypto address (chain and address) or a US
+// bank account (routing and account number)
+type CreateRecipientRequest struct {
+	Kind              RecipientKind `json:"kind" binding:"required,oneof=crypto bank"`
+	Label             string        `json:"label" binding:"required,max=100"`
+	Chain             string        `json:"chain" binding:"max=30"`
+	Address           string        `json:"address" binding:"max=200"`
+	BankName          string        `json:"bank_name" binding:"max=100"`
+	AccountHolderName string        `json:"account_holder_name" binding:"max=200"`
+	RoutingNumber     string        `json:"routing_number" binding:"max=20"`
+	AccountNumber     string        `json:"account_number" binding:"max=34"`
+}
+
+// UpdateRecipientRequest renames a saved recipient
+type UpdateRecipientRequest struct {
+	Label string `json:"label" binding:"required,max=100"`
+}
+
+// StartAddressCheckRequest starts proving control of a crypto recipient
+type StartAddressCheckRequest struct {
+	Method AddressCheckMethod `json:"method" binding:"required,oneof=micro_deposit signature"`
+}
+
+// ConfirmAddressCheckRequest completes an address check with the exact
+// amount received for a micro-deposit, or the signature of the check's
+// message
+type ConfirmAddressCheckRequest struct {
+	Amount    string `json:"amount" binding:"max=40"`
+	Signature string `json:"signature" binding:"max=200"`
+}
+
+// FlagRecipientRequest carries an admin's reason for blocking a recipient
+type FlagRecipientRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// SharedDestination is a withdrawal destination saved by more than one
+// account, a common marker of account takeover and money mule rings
+type SharedDestination struct {
+	Kind       RecipientKind          `json:"kind"`
+	Chain      string                 `json:"chain,omitempty"`
+	Address    string                 `json:"address,omitempty"`
+	AccountRef string                 `json:"account_ref,omitempty"` // routing number and account last4
+	Users      int                    `json:"users"`
+	Flagged    bool                   `json:"flagged"`
+	Recipients []*WithdrawalRecipient `json:"recipients"`
+}
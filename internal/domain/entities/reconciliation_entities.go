@@ -188,3 +188,24 @@ func (e *ReconciliationException) MarkResolved(resolvedBy, notes string) {
 	e.ResolvedBy = resolvedBy
 	e.ResolutionNotes = notes
 }
+
+// MaxSkippedIDs caps the account IDs listed in a BalanceAggregate
+const MaxSkippedIDs = 20
+
+// BalanceAggregate is a provider balance summed across many accounts.
+// Skipped counts accounts whose balance could not be read during the run, so
+// a discrepancy can be told apart from missing data.
+type BalanceAggregate struct {
+	Total      decimal.Decimal `json:"total"`
+	Counted    int             `json:"counted"`
+	Skipped    int             `json:"skipped"`
+	SkippedIDs []string        `json:"skipped_ids,omitempty"`
+}
+
+// Skip records an account that could not be read
+func (a *BalanceAggregate) Skip(id string) {
+	a.Skipped++
+	if len(a.SkippedIDs) < MaxSkippedIDs {
+		a.SkippedIDs = append(a.SkippedIDs, id)
+	}
+}
@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidResidency is returned for an unknown residency region
+	ErrInvalidResidency = errors.New("invalid data residency")
+	// ErrOutOfRegion is returned when a user's files or documents would be
+	// stored outside the region their data must stay in
+	ErrOutOfRegion = errors.New("storage is outside the user's data region")
+)
+
+// ResidencyRegion is where a user's KYC files and statements must be stored
+type ResidencyRegion string
+
+const (
+	ResidencyRegionUS ResidencyRegion = "us"
+	ResidencyRegionEU ResidencyRegion = "eu"
+)
+
+// IsValid reports whether the region is known
+func (r ResidencyRegion) IsValid() bool {
+	return r == ResidencyRegionUS || r == ResidencyRegionEU
+}
+
+// ResidencySource records how a user's region was decided
+type ResidencySource string
+
+const (
+	ResidencySourceCountry ResidencySource = "country" // derived from the country the user onboarded from
+	ResidencySourceAdmin   ResidencySource = "admin"   // set by an admin, and kept if the country changes
+)
+
+// EEACountryCodes lists the EU and EEA countries whose residents' data is
+// kept in the EU region
+var EEACountryCodes = []string{
+	"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI", "FR", "GR", "HR", "HU",
+	"IE", "IT", "LT", "LU", "LV", "MT", "NL", "PL", "PT", "RO", "SE", "SI", "SK",
+	"IS", "LI", "NO",
+}
+
+// ResidencyRegionForCountry returns the region a resident of the country is
+// kept in; countries outside the EEA, and unknown ones, use home
+func ResidencyRegionForCountry(countryCode string, home ResidencyRegion) ResidencyRegion {
+	code := NormalizeCountryCode(countryCode)
+	for _, eea := range EEACountryCodes {
+		if code == eea {
+			return ResidencyRegionEU
+		}
+	}
+	return home
+}
+
+// UserResidency is the region a user's data is tagged with
+type UserResidency struct {
+	UserID      uuid.UUID       `json:"user_id"`
+	Region      ResidencyRegion `json:"region"`
+	Source      ResidencySource `json:"source"`
+	CountryCode string          `json:"country_code,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+	SetBy       *uuid.UUID      `json:"set_by,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	// Tagged is false for a region derived on the fly and not yet stored
+	Tagged bool `json:"tagged"`
+}
+
+// SetResidencyRequest is an admin pinning a user to a region
+type SetResidencyRequest struct {
+	Region ResidencyRegion `json:"region" binding:"required"`
+	Reason string          `json:"reason" binding:"required,max=500"`
+}
+
+// ResidencyRecordType names the kind of record in an out-of-region report
+type ResidencyRecordType string
+
+const (
+	ResidencyRecordUpload   ResidencyRecordType = "upload"
+	ResidencyRecordDocument ResidencyRecordType = "document"
+)
+
+// ResidencyViolation is a user's upload or document stored outside the
+// region their data must stay in
+type ResidencyViolation struct {
+	RecordType   ResidencyRecordType `json:"record_type"`
+	RecordID     uuid.UUID           `json:"record_id"`
+	UserID       uuid.UUID           `json:"user_id"`
+	UserRegion   ResidencyRegion     `json:"user_region"`
+	StoredRegion ResidencyRegion     `json:"stored_region"`
+	Kind         string              `json:"kind"` // upload purpose or document category
+	CreatedAt    time.Time           `json:"created_at"`
+}
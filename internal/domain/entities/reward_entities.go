@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Reward errors
+var (
+	ErrRewardRuleNotFound  = errors.New("reward rule not found")
+	ErrRewardNotFound      = errors.New("reward not found")
+	ErrRewardAlreadyEarned = errors.New("reward already earned on this order")
+	ErrRewardCapReached    = errors.New("monthly reward cap reached")
+	ErrRewardNotHeld       = errors.New("reward is not held for review")
+	ErrInsufficientRewards = errors.New("insufficient available rewards")
+	ErrRedemptionNotFound  = errors.New("reward redemption not found")
+)
+
+// RewardTrigger decides which filled orders earn under a rule
+type RewardTrigger string
+
+const (
+	// RewardTriggerInvestment rules earn on every filled buy order
+	RewardTriggerInvestment RewardTrigger = "investment"
+	// RewardTriggerRecurringInvestment rules earn only on buy orders placed by
+	// the user's auto-sweep
+	RewardTriggerRecurringInvestment RewardTrigger = "recurring_investment"
+)
+
+// RewardUnit is how a rule's rewards are shown to the user. Both accrue the
+// same dollar value in the ledger; points rules also record the points.
+type RewardUnit string
+
+const (
+	RewardUnitCash   RewardUnit = "cash"
+	RewardUnitPoints RewardUnit = "points"
+)
+
+// RewardRule earns a share of each qualifying order back as rewards
+type RewardRule struct {
+	ID          uuid.UUID     `json:"id" db:"id"`
+	Name        string        `json:"name" db:"name"`
+	Description *string       `json:"description,omitempty" db:"description"`
+	Trigger     RewardTrigger `json:"trigger" db:"trigger"`
+	Unit        RewardUnit    `json:"unit" db:"unit"`
+	// Rate is the fraction of the order amount earned, 0.01 for 1% back
+	Rate           decimal.Decimal  `json:"rate" db:"rate"`
+	MinOrderAmount decimal.Decimal  `json:"min_order_amount" db:"min_order_amount"`
+	MaxPerOrder    *decimal.Decimal `json:"max_per_order,omitempty" db:"max_per_order"`
+	// HoldDays is how long a reward stays pending before it can be redeemed;
+	// selling the basket in that time forfeits it
+	HoldDays  int        `json:"hold_days" db:"hold_days"`
+	Active    bool       `json:"active" db:"active"`
+	StartsAt  *time.Time `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty" db:"ends_at"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsRunning reports whether the rule earns at t
+func (r *RewardRule) IsRunning(t time.Time) bool {
+	if !r.Active {
+		return false
+	}
+	if r.StartsAt != nil && t.Before(*r.StartsAt) {
+		return false
+	}
+	if r.EndsAt != nil && !t.Before(*r.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// RewardStatus tracks a reward from accrual to forfeit
+type RewardStatus string
+
+const (
+	// RewardAccrued rewards are in the user's rewards balance and redeemable
+	// once their hold period passes
+	RewardAccrued RewardStatus = "accrued"
+	// RewardHeld rewards scored high by the risk engine wait for an admin
+	RewardHeld RewardStatus = "held"
+	// RewardForfeited rewards went back to the rewards budget
+	RewardForfeited RewardStatus = "forfeited"
+)
+
+// Reward is what one order earned under one rule
+type Reward struct {
+	ID                 uuid.UUID       `json:"id" db:"id"`
+	RuleID             uuid.UUID       `json:"rule_id" db:"rule_id"`
+	RuleName           string          `json:"rule_name" db:"rule_name"`
+	UserID             uuid.UUID       `json:"user_id" db:"user_id"`
+	OrderID            uuid.UUID       `json:"order_id" db:"order_id"`
+	BasketID           uuid.UUID       `json:"basket_id" db:"basket_id"`
+	Unit               RewardUnit      `json:"unit" db:"unit"`
+	Amount             decimal.Decimal `json:"amount" db:"amount"` // USD value
+	Points             *int64          `json:"points,omitempty" db:"points"`
+	Status             RewardStatus    `json:"status" db:"status"`
+	AvailableAt        time.Time       `json:"available_at" db:"available_at"`
+	RiskScore          decimal.Decimal `json:"risk_score" db:"risk_score"`
+	HoldReason         *string         `json:"hold_reason,omitempty" db:"hold_reason"`
+	LedgerTxID         *uuid.UUID      `json:"ledger_tx_id,omitempty" db:"ledger_tx_id"`
+	ClawbackLedgerTxID *uuid.UUID      `json:"clawback_ledger_tx_id,omitempty" db:"clawback_ledger_tx_id"`
+	ForfeitReason      *string         `json:"forfeit_reason,omitempty" db:"forfeit_reason"`
+	ReviewedBy         *uuid.UUID      `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	SettledAt          *time.Time      `json:"settled_at,omitempty" db:"settled_at"`
+	CreatedAt          time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// IsAvailable reports whether the reward can be redeemed at t
+func (r *Reward) IsAvailable(t time.Time) bool {
+	return r.Status == RewardAccrued && !r.AvailableAt.After(t)
+}
+
+// RewardRedemptionStatus tracks a redemption until its order is placed
+type RewardRedemptionStatus string
+
+const (
+	RewardRedemptionPending   RewardRedemptionStatus = "pending"
+	RewardRedemptionCompleted RewardRedemptionStatus = "completed"
+	RewardRedemptionFailed    RewardRedemptionStatus = "failed"
+)
+
+// RewardRedemption converts available rewards into a basket buy order
+type RewardRedemption struct {
+	ID            uuid.UUID              `json:"id" db:"id"`
+	UserID        uuid.UUID              `json:"user_id" db:"user_id"`
+	BasketID      uuid.UUID              `json:"basket_id" db:"basket_id"`
+	Amount        decimal.Decimal        `json:"amount" db:"amount"`
+	Status        RewardRedemptionStatus `json:"status" db:"status"`
+	OrderID       *uuid.UUID             `json:"order_id,omitempty" db:"order_id"`
+	LedgerTxID    *uuid.UUID             `json:"ledger_tx_id,omitempty" db:"ledger_tx_id"`
+	FailureReason *string                `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// RewardBalance is a user's rewards by state, in USD
+type RewardBalance struct {
+	Available   decimal.Decimal `json:"available"`
+	Pending     decimal.Decimal `json:"pending"`      // accrued but still in the hold period
+	UnderReview decimal.Decimal `json:"under_review"` // held by the risk engine
+	Redeemed    decimal.Decimal `json:"redeemed"`
+}
+
+// RewardSummary is a user's rewards balance with their recent activity
+type RewardSummary struct {
+	RewardBalance
+	Rewards     []*Reward           `json:"rewards"`
+	Redemptions []*RewardRedemption `json:"redemptions"`
+}
+
+// CreateRewardRuleRequest defines a new earning rule
+type CreateRewardRuleRequest struct {
+	Name           string           `json:"name" binding:"required,max=200"`
+	Description    *string          `json:"description,omitempty"`
+	Trigger        RewardTrigger    `json:"trigger" binding:"required,oneof=investment recurring_investment"`
+	Unit           RewardUnit       `json:"unit" binding:"required,oneof=cash points"`
+	Rate           decimal.Decimal  `json:"rate"`
+	MinOrderAmount decimal.Decimal  `json:"min_order_amount"`
+	MaxPerOrder    *decimal.Decimal `json:"max_per_order,omitempty"`
+	HoldDays       int              `json:"hold_days" binding:"min=0,max=365"`
+	StartsAt       *time.Time       `json:"starts_at,omitempty"`
+	EndsAt         *time.Time       `json:"ends_at,omitempty"`
+}
+
+// UpdateRewardRuleRequest pauses or resumes a rule
+type UpdateRewardRuleRequest struct {
+	Active bool `json:"active"`
+}
+
+// ReviewRewardRequest releases or forfeits a reward held for review
+type ReviewRewardRequest struct {
+	Approve bool   `json:"approve"`
+	Reason  string `json:"reason" binding:"required,max=500"`
+}
+
+// RedeemRewardsRequest invests available rewards in a basket
+type RedeemRewardsRequest struct {
+	BasketID uuid.UUID       `json:"basket_id" binding:"required"`
+	Amount   decimal.Decimal `json:"amount"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// ShadowRouteStats counts how a route's candidate implementation compared
+// with the live handler since the process started
+type ShadowRouteStats struct {
+	Method        string     `json:"method"`
+	Route         string     `json:"route"`
+	SamplePercent float64    `json:"sample_percent"`
+	AllowWrites   bool       `json:"allow_writes"`
+	Shadowed      int64      `json:"shadowed"` // requests replayed against the candidate
+	Matched       int64      `json:"matched"`  // candidate gave the same status and body
+	Diverged      int64      `json:"diverged"` // candidate differed from the live response
+	Failed        int64      `json:"failed"`   // candidate timed out or panicked
+	Dropped       int64      `json:"dropped"`  // skipped because the queue was full
+	LastDiverged  *time.Time `json:"last_diverged,omitempty"`
+	LastDiffPaths []string   `json:"last_diff_paths,omitempty"` // where the last divergence differed, never values
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -36,12 +37,33 @@ const (
 type OrderStatus string
 
 const (
+	OrderStatusOpen            OrderStatus = "open" // limit order resting until its price is reached
 	OrderStatusAccepted        OrderStatus = "accepted"
 	OrderStatusPending         OrderStatus = "pending"
 	OrderStatusPartiallyFilled OrderStatus = "partially_filled"
 	OrderStatusFilled          OrderStatus = "filled"
 	OrderStatusFailed          OrderStatus = "failed"
 	OrderStatusCanceled        OrderStatus = "canceled"
+	OrderStatusExpired         OrderStatus = "expired" // limit order that was never triggered
+)
+
+// OrderType is how an order is priced
+type OrderType string
+
+const (
+	OrderTypeMarket OrderType = "market"
+	// OrderTypeLimit executes only once the synthetic basket price, the sum of
+	// each component's weight times its quote, is at or below the limit for a
+	// buy, or at or above it for a sell
+	OrderTypeLimit OrderType = "limit"
+)
+
+// TimeInForce is how long an untriggered limit order rests
+type TimeInForce string
+
+const (
+	TimeInForceDay TimeInForce = "day" // expires at the close of its first trading session
+	TimeInForceGTC TimeInForce = "gtc" // good till cancelled, up to the configured maximum
 )
 
 // RiskLevel represents basket risk levels
@@ -77,23 +99,50 @@ type Wallet struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Chain deposit lifecycle. A deposit is detected when first reported, confirmed
+// once it has the confirmations its chain requires, and credited once buying
+// power and ledger entries are posted. A credited deposit whose transaction
+// is dropped by a re-org is reversed.
+const (
+	DepositStatusDetected  = "detected"
+	DepositStatusConfirmed = "confirmed"
+	DepositStatusCredited  = "credited"
+	DepositStatusReversed  = "reversed"
+)
+
+// ErrDepositNotReversible is returned when a dropped deposit has already moved
+// past crediting (off-ramped or broker funded) and must be unwound manually
+var ErrDepositNotReversible = errors.New("deposit can no longer be reversed automatically")
+
+// ErrDuplicateDeposit is returned when a deposit is recorded for a transfer
+// (chain, transaction hash and log index) or Due transaction that already has one
+var ErrDuplicateDeposit = errors.New("deposit already recorded")
+
 // Deposit represents a stablecoin deposit
 type Deposit struct {
-	ID                   uuid.UUID       `json:"id" db:"id"`
-	UserID               uuid.UUID       `json:"user_id" db:"user_id"`
-	Chain                Chain           `json:"chain" db:"chain"`
-	TxHash               string          `json:"tx_hash" db:"tx_hash"`
-	Token                Stablecoin      `json:"token" db:"token"`
-	Amount               decimal.Decimal `json:"amount" db:"amount"`
-	Status               string          `json:"status" db:"status"` // pending, confirmed, failed, off_ramp_initiated, off_ramp_completed, broker_funded
-	ConfirmedAt          *time.Time      `json:"confirmed_at" db:"confirmed_at"`
-	OffRampTxID          *string         `json:"off_ramp_tx_id" db:"off_ramp_tx_id"`
-	OffRampInitiatedAt   *time.Time      `json:"off_ramp_initiated_at" db:"off_ramp_initiated_at"`
-	OffRampCompletedAt   *time.Time      `json:"off_ramp_completed_at" db:"off_ramp_completed_at"`
-	AlpacaFundingTxID    *string         `json:"alpaca_funding_tx_id" db:"alpaca_funding_tx_id"`
-	AlpacaFundedAt       *time.Time      `json:"alpaca_funded_at" db:"alpaca_funded_at"`
-	VirtualAccountID     *uuid.UUID      `json:"virtual_account_id" db:"virtual_account_id"`
-	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
+	ID                    uuid.UUID        `json:"id" db:"id"`
+	UserID                uuid.UUID        `json:"user_id" db:"user_id"`
+	Chain                 Chain            `json:"chain" db:"chain"`
+	TxHash                string           `json:"tx_hash" db:"tx_hash"`
+	LogIndex              int              `json:"log_index" db:"log_index"`
+	Token                 Stablecoin       `json:"token" db:"token"`
+	Amount                decimal.Decimal  `json:"amount" db:"amount"`
+	Status                string           `json:"status" db:"status"` // detected, confirmed, credited, reversed, failed, off_ramp_initiated, off_ramp_completed, broker_funded
+	ConfirmedAt           *time.Time       `json:"confirmed_at" db:"confirmed_at"`
+	OffRampTxID           *string          `json:"off_ramp_tx_id" db:"off_ramp_tx_id"`
+	OffRampInitiatedAt    *time.Time       `json:"off_ramp_initiated_at" db:"off_ramp_initiated_at"`
+	OffRampCompletedAt    *time.Time       `json:"off_ramp_completed_at" db:"off_ramp_completed_at"`
+	AlpacaFundingTxID     *string          `json:"alpaca_funding_tx_id" db:"alpaca_funding_tx_id"`
+	AlpacaFundedAt        *time.Time       `json:"alpaca_funded_at" db:"alpaca_funded_at"`
+	VirtualAccountID      *uuid.UUID       `json:"virtual_account_id" db:"virtual_account_id"`
+	Confirmations         int              `json:"confirmations" db:"confirmations"`
+	RequiredConfirmations int              `json:"required_confirmations" db:"required_confirmations"`
+	CreditedAmount        *decimal.Decimal `json:"credited_amount,omitempty" db:"credited_amount"`
+	CreditedAt            *time.Time       `json:"credited_at,omitempty" db:"credited_at"`
+	LedgerTransactionID   *uuid.UUID       `json:"ledger_transaction_id,omitempty" db:"ledger_transaction_id"`
+	ReversedAt            *time.Time       `json:"reversed_at,omitempty" db:"reversed_at"`
+	ReversalReason        *string          `json:"reversal_reason,omitempty" db:"reversal_reason"`
+	CreatedAt             time.Time        `json:"created_at" db:"created_at"`
 }
 
 // Balance represents user's buying power and pending deposits
@@ -112,8 +161,11 @@ type Basket struct {
 	Description string            `json:"description" db:"description"`
 	RiskLevel   RiskLevel         `json:"risk_level" db:"risk_level"`
 	Composition []BasketComponent `json:"composition"` // Stored as JSON in DB
-	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+	// ParentBasketID is set on regional variants to the basket they were cloned from
+	ParentBasketID *uuid.UUID `json:"parent_basket_id,omitempty" db:"parent_basket_id"`
+	Regions        []string   `json:"regions" db:"regions"` // empty means offered everywhere
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // BasketComponent represents a component within a basket
@@ -124,15 +176,21 @@ type BasketComponent struct {
 
 // Order represents a basket investment order
 type Order struct {
-	ID           uuid.UUID       `json:"id" db:"id"`
-	UserID       uuid.UUID       `json:"user_id" db:"user_id"`
-	BasketID     uuid.UUID       `json:"basket_id" db:"basket_id"`
-	Side         OrderSide       `json:"side" db:"side"`
-	Amount       decimal.Decimal `json:"amount" db:"amount"`
-	Status       OrderStatus     `json:"status" db:"status"`
-	BrokerageRef *string         `json:"brokerage_ref" db:"brokerage_ref"`
-	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
+	ID           uuid.UUID        `json:"id" db:"id"`
+	UserID       uuid.UUID        `json:"user_id" db:"user_id"`
+	BasketID     uuid.UUID        `json:"basket_id" db:"basket_id"`
+	Side         OrderSide        `json:"side" db:"side"`
+	Amount       decimal.Decimal  `json:"amount" db:"amount"`
+	Status       OrderStatus      `json:"status" db:"status"`
+	BrokerageRef *string          `json:"brokerage_ref" db:"brokerage_ref"`
+	Type         OrderType        `json:"order_type" db:"order_type"`
+	LimitPrice   *decimal.Decimal `json:"limit_price,omitempty" db:"limit_price"`
+	TimeInForce  TimeInForce      `json:"time_in_force" db:"time_in_force"`
+	ExpiresAt    *time.Time       `json:"expires_at,omitempty" db:"expires_at"`
+	TriggeredAt  *time.Time       `json:"triggered_at,omitempty" db:"triggered_at"`
+	Paper        bool             `json:"paper" db:"paper"` // simulated, never sent to the brokerage
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at" db:"updated_at"`
 }
 
 // Position represents a user's position in a basket
@@ -197,9 +255,20 @@ type FundingConfirmationsPage struct {
 
 // BalancesResponse represents user balances
 type BalancesResponse struct {
-	BuyingPower     string `json:"buyingPower"`
-	PendingDeposits string `json:"pendingDeposits"`
-	Currency        string `json:"currency"`
+	BuyingPower     string     `json:"buyingPower"`
+	PendingDeposits string     `json:"pendingDeposits"`
+	Currency        string     `json:"currency"`
+	LastSyncedAt    *time.Time `json:"lastSyncedAt,omitempty"` // oldest wallet sync behind BuyingPower
+	Stale           bool       `json:"stale,omitempty"`
+	// PromotionalCredit is reported apart from cash; it is omitted for users
+	// without promotional grants
+	PromotionalCredit *PromotionalCreditBalance `json:"promotionalCredit,omitempty"`
+}
+
+// PromotionalCreditBalance is a user's promotional credit by vesting state
+type PromotionalCreditBalance struct {
+	Pending string `json:"pending"` // forfeited if the account closes before it vests
+	Vested  string `json:"vested"`  // already added to trading buying power
 }
 
 // OrderCreateRequest represents order creation request
@@ -208,6 +277,50 @@ type OrderCreateRequest struct {
 	Side           OrderSide `json:"side" validate:"required"`
 	Amount         string    `json:"amount" validate:"required"`
 	IdempotencyKey *string   `json:"idempotencyKey,omitempty"`
+	// OrderType defaults to market. Limit orders need LimitPrice, a synthetic
+	// basket price, and default to day orders.
+	OrderType   OrderType   `json:"orderType,omitempty"`
+	LimitPrice  *string     `json:"limitPrice,omitempty"`
+	TimeInForce TimeInForce `json:"timeInForce,omitempty"`
+}
+
+// OrderPreview is the estimated outcome of an order, shown on the confirm
+// screen before it is placed
+type OrderPreview struct {
+	BasketID        uuid.UUID               `json:"basketId"`
+	Side            OrderSide               `json:"side"`
+	Amount          string                  `json:"amount"`
+	Components      []OrderPreviewComponent `json:"components"`
+	Fees            OrderFeeEstimate        `json:"fees"`
+	NetAmount       string                  `json:"netAmount"`   // invested after fees on buys, proceeds after fees on sells
+	BuyingPower     string                  `json:"buyingPower"` // before the order
+	CashAfter       string                  `json:"cashAfter"`   // buying power once the order settles
+	ExecutionWindow ExecutionWindow         `json:"executionWindow"`
+	QuotedAt        time.Time               `json:"quotedAt"`
+	Paper           bool                    `json:"paper"`
+}
+
+// OrderPreviewComponent is the estimated trade in one basket component
+type OrderPreviewComponent struct {
+	Symbol          string `json:"symbol"`
+	Weight          string `json:"weight"`
+	Price           string `json:"price"`
+	Notional        string `json:"notional"`
+	EstimatedShares string `json:"estimatedShares"`
+}
+
+// OrderFeeEstimate breaks down the fees charged on an order
+type OrderFeeEstimate struct {
+	Commission string `json:"commission"`
+	Regulatory string `json:"regulatory"` // SEC and FINRA fees, charged on sells
+	Total      string `json:"total"`
+}
+
+// ExecutionWindow is when an order is expected to execute
+type ExecutionWindow struct {
+	MarketOpen bool      `json:"marketOpen"`
+	EarliestAt time.Time `json:"earliestAt"`
+	LatestAt   time.Time `json:"latestAt"`
 }
 
 // Portfolio represents a user's complete portfolio
@@ -215,6 +328,7 @@ type Portfolio struct {
 	Currency   string             `json:"currency"`
 	Positions  []PositionResponse `json:"positions"`
 	TotalValue string             `json:"totalValue"`
+	Paper      bool               `json:"paper"`
 }
 
 // PortfolioOverview represents complete portfolio overview with balance and performance
@@ -246,6 +360,14 @@ type ChainDepositWebhook struct {
 	TxHash    string     `json:"txHash"`
 	BlockTime time.Time  `json:"blockTime"`
 	Signature string     `json:"signature"`
+	// LogIndex tells apart transfers to our addresses within one transaction
+	LogIndex int `json:"logIndex"`
+	// Confirmations is the block depth at the time of the notification. The
+	// sender re-notifies as it grows; the deposit is credited once it reaches
+	// the chain's threshold.
+	Confirmations int `json:"confirmations"`
+	// Removed marks a transaction dropped from the canonical chain by a re-org
+	Removed bool `json:"removed"`
 }
 
 // DueVirtualAccountDepositWebhook represents Due virtual account deposit event
@@ -298,6 +420,18 @@ type BrokerageFill struct {
 	Price    string `json:"price"`
 }
 
+// ExecutionFees are the fees charged on a filled order, as printed on its
+// trade confirmation
+type ExecutionFees struct {
+	Commission decimal.Decimal `json:"commission"`
+	Regulatory decimal.Decimal `json:"regulatory"` // SEC and FINRA fees, charged on sells
+}
+
+// Total returns the commission and regulatory fees together
+func (f ExecutionFees) Total() decimal.Decimal {
+	return f.Commission.Add(f.Regulatory)
+}
+
 // ErrorResponse represents API error responses
 type StackErrorResponse struct {
 	Code    string                 `json:"code"`
@@ -0,0 +1,166 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Subscription errors
+var (
+	ErrPlanNotFound             = errors.New("subscription plan not found")
+	ErrSubscriptionNotFound     = errors.New("subscription not found")
+	ErrAlreadySubscribed        = errors.New("user already has an active subscription")
+	ErrInvoiceNotFound          = errors.New("invoice not found")
+	ErrPaymentMethodUnavailable = errors.New("payment method is not available")
+	ErrSubscriptionPastDue      = errors.New("subscription has an unpaid invoice")
+	ErrInvoiceNotPayable        = errors.New("invoice is not open")
+	ErrPaymentFailed            = errors.New("payment failed")
+)
+
+// Feature names used in plan entitlements
+const (
+	FeatureAIAnalyses      = "ai_analyses"      // AI portfolio analyses per billing month
+	FeaturePriceAlerts     = "price_alerts"     // Active price alerts
+	FeaturePrioritySupport = "priority_support" // 1 when included
+)
+
+// UnlimitedEntitlement marks a feature without a usage limit
+const UnlimitedEntitlement = -1
+
+// Entitlements maps feature names to usage limits. A missing feature is not
+// included; UnlimitedEntitlement means no limit.
+type Entitlements map[string]int
+
+// Limit returns the feature's limit, or 0 when it is not included
+func (e Entitlements) Limit(feature string) int {
+	return e[feature]
+}
+
+// Allows reports whether usage of the feature is still within its limit
+func (e Entitlements) Allows(feature string, used int) bool {
+	limit := e.Limit(feature)
+	return limit == UnlimitedEntitlement || used < limit
+}
+
+// FreeTierEntitlements apply to users without a paid subscription
+func FreeTierEntitlements() Entitlements {
+	return Entitlements{
+		FeatureAIAnalyses:  3,
+		FeaturePriceAlerts: 5,
+	}
+}
+
+// SubscriptionPlan is a paid tier users can subscribe to
+type SubscriptionPlan struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	Code           string          `json:"code" db:"code"`
+	Name           string          `json:"name" db:"name"`
+	Description    *string         `json:"description,omitempty" db:"description"`
+	Price          decimal.Decimal `json:"price" db:"price"`
+	Currency       string          `json:"currency" db:"currency"`
+	IntervalMonths int             `json:"interval_months" db:"interval_months"`
+	Entitlements   Entitlements    `json:"entitlements" db:"entitlements"`
+	Active         bool            `json:"active" db:"active"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// SubscriptionStatus tracks a subscription through billing
+type SubscriptionStatus string
+
+const (
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionPastDue  SubscriptionStatus = "past_due" // renewal unpaid; dunning in progress
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+)
+
+// PaymentMethod is how a subscription is billed
+type PaymentMethod string
+
+const (
+	PaymentMethodCashBalance PaymentMethod = "cash_balance"
+	PaymentMethodCard        PaymentMethod = "card"
+)
+
+// Subscription is a user's paid plan
+type Subscription struct {
+	ID                 uuid.UUID          `json:"id" db:"id"`
+	UserID             uuid.UUID          `json:"user_id" db:"user_id"`
+	PlanID             uuid.UUID          `json:"plan_id" db:"plan_id"`
+	PlanCode           string             `json:"plan_code" db:"plan_code"`
+	Status             SubscriptionStatus `json:"status" db:"status"`
+	PaymentMethod      PaymentMethod      `json:"payment_method" db:"payment_method"`
+	CurrentPeriodStart time.Time          `json:"current_period_start" db:"current_period_start"`
+	CurrentPeriodEnd   time.Time          `json:"current_period_end" db:"current_period_end"`
+	CancelAtPeriodEnd  bool               `json:"cancel_at_period_end" db:"cancel_at_period_end"`
+	// CreditBalance is owed to the user from a downgrade and applied to the
+	// next invoice
+	CreditBalance decimal.Decimal `json:"credit_balance" db:"credit_balance"`
+	CanceledAt    *time.Time      `json:"canceled_at,omitempty" db:"canceled_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// GrantsEntitlements reports whether the plan's entitlements apply. Past due
+// subscriptions keep them during dunning.
+func (s *Subscription) GrantsEntitlements() bool {
+	return s.Status == SubscriptionActive || s.Status == SubscriptionPastDue
+}
+
+// InvoiceStatus tracks an invoice from issue to settlement
+type InvoiceStatus string
+
+const (
+	InvoiceOpen          InvoiceStatus = "open"
+	InvoicePaid          InvoiceStatus = "paid"
+	InvoiceVoid          InvoiceStatus = "void"
+	InvoiceUncollectible InvoiceStatus = "uncollectible" // dunning exhausted
+)
+
+// InvoiceLineItem is one charge or credit on an invoice
+type InvoiceLineItem struct {
+	Description string          `json:"description"`
+	Amount      decimal.Decimal `json:"amount"` // negative for credits
+	PeriodStart *time.Time      `json:"period_start,omitempty"`
+	PeriodEnd   *time.Time      `json:"period_end,omitempty"`
+}
+
+// Invoice bills a subscription period or plan change
+type Invoice struct {
+	ID             uuid.UUID         `json:"id" db:"id"`
+	Number         string            `json:"number" db:"number"`
+	SubscriptionID uuid.UUID         `json:"subscription_id" db:"subscription_id"`
+	UserID         uuid.UUID         `json:"user_id" db:"user_id"`
+	Status         InvoiceStatus     `json:"status" db:"status"`
+	Subtotal       decimal.Decimal   `json:"subtotal" db:"subtotal"`
+	CreditApplied  decimal.Decimal   `json:"credit_applied" db:"credit_applied"`
+	Total          decimal.Decimal   `json:"total" db:"total"`
+	Currency       string            `json:"currency" db:"currency"`
+	PeriodStart    time.Time         `json:"period_start" db:"period_start"`
+	PeriodEnd      time.Time         `json:"period_end" db:"period_end"`
+	LineItems      []InvoiceLineItem `json:"line_items" db:"line_items"`
+	AttemptCount   int               `json:"attempt_count" db:"attempt_count"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	FailureReason  *string           `json:"failure_reason,omitempty" db:"failure_reason"`
+	LedgerTxID     *uuid.UUID        `json:"ledger_tx_id,omitempty" db:"ledger_tx_id"`
+	PaymentRef     *string           `json:"payment_ref,omitempty" db:"payment_ref"`
+	PaidAt         *time.Time        `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// SubscribeRequest starts or changes a user's plan
+type SubscribeRequest struct {
+	PlanCode      string        `json:"plan_code" binding:"required"`
+	PaymentMethod PaymentMethod `json:"payment_method" binding:"omitempty,oneof=cash_balance card"`
+}
+
+// SubscriptionOverview is a user's plan and what it entitles them to
+type SubscriptionOverview struct {
+	Subscription *Subscription     `json:"subscription,omitempty"`
+	Plan         *SubscriptionPlan `json:"plan,omitempty"`
+	Entitlements Entitlements      `json:"entitlements"`
+}
@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Suspense errors
+var (
+	ErrSuspenseItemNotFound = errors.New("suspense item not found")
+	ErrSuspenseItemResolved = errors.New("suspense item is already resolved")
+)
+
+// SuspenseSource is the rail unattributed funds arrived on
+type SuspenseSource string
+
+const (
+	SuspenseSourceBankCredit   SuspenseSource = "bank_credit"   // Transfer into a shared deposit account
+	SuspenseSourceChainDeposit SuspenseSource = "chain_deposit" // On-chain transfer to an address no wallet has
+)
+
+// SuspenseStatus is where an item is in the suspense workflow
+type SuspenseStatus string
+
+const (
+	SuspenseStatusOpen      SuspenseStatus = "open"
+	SuspenseStatusResolving SuspenseStatus = "resolving" // Claimed while it is assigned or refunded
+	SuspenseStatusAssigned  SuspenseStatus = "assigned"
+	SuspenseStatusRefunded  SuspenseStatus = "refunded"
+)
+
+// SuspenseReasonUnknownAddress is the reason a chain deposit is held: the
+// address does not belong to any wallet. Bank credits are held for the
+// BankCredit* reasons.
+const SuspenseReasonUnknownAddress = "unknown_address"
+
+// Why a user is suggested for a suspense item
+const (
+	SuspenseSuggestionSimilarReference = "similar_reference" // One character away from the user's reference
+	SuspenseSuggestionSenderName       = "sender_name"       // The sender's name contains the user's name
+	SuspenseSuggestionSameAddress      = "same_address"      // A wallet of the user has this address apart from letter case
+)
+
+// SuspenseItem is an amount held in the suspense account until an admin
+// assigns it to a user or refunds it. Details carry what the source needs to
+// credit or return it, such as the memo or the transaction hash.
+type SuspenseItem struct {
+	ID                         uuid.UUID            `json:"id"`
+	Source                     SuspenseSource       `json:"source"`
+	SourceRef                  string               `json:"source_ref"`
+	Currency                   string               `json:"currency"`
+	Amount                     decimal.Decimal      `json:"amount"`
+	USDAmount                  decimal.Decimal      `json:"usd_amount"`
+	Reason                     string               `json:"reason"`
+	Details                    map[string]string    `json:"details"`
+	Status                     SuspenseStatus       `json:"status"`
+	UserID                     *uuid.UUID           `json:"user_id,omitempty"`
+	ResolvedBy                 *uuid.UUID           `json:"resolved_by,omitempty"`
+	ResolvedAt                 *time.Time           `json:"resolved_at,omitempty"`
+	Note                       *string              `json:"note,omitempty"`
+	HoldLedgerTransactionID    *uuid.UUID           `json:"hold_ledger_transaction_id,omitempty"`
+	ReleaseLedgerTransactionID *uuid.UUID           `json:"release_ledger_transaction_id,omitempty"`
+	ReceivedAt                 time.Time            `json:"received_at"`
+	CreatedAt                  time.Time            `json:"created_at"`
+	UpdatedAt                  time.Time            `json:"updated_at"`
+	Suggestions                []SuspenseSuggestion `json:"suggestions,omitempty"`
+}
+
+// SuspenseSuggestion is a user an open item may belong to
+type SuspenseSuggestion struct {
+	UserID uuid.UUID `json:"user_id"`
+	Reason string    `json:"reason"`
+	Detail string    `json:"detail"`
+}
+
+// SuspenseAging summarizes the open items of one source
+type SuspenseAging struct {
+	Source        SuspenseSource  `json:"source"`
+	Open          int             `json:"open"`
+	Aged          int             `json:"aged"`
+	AgedUSDAmount decimal.Decimal `json:"aged_usd_amount"`
+	OldestAt      *time.Time      `json:"oldest_at,omitempty"`
+}
+
+// SuspenseAgingReport lists open items per source and those older than the
+// alerting threshold
+type SuspenseAgingReport struct {
+	AgedAfterDays int             `json:"aged_after_days"`
+	Sources       []SuspenseAging `json:"sources"`
+}
+
+// AssignSuspenseItemRequest credits a held item to a user
+type AssignSuspenseItemRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Note   string    `json:"note" binding:"required"`
+}
+
+// RefundSuspenseItemRequest records that a held item was sent back
+type RefundSuspenseItemRequest struct {
+	Note string `json:"note" binding:"required"`
+}
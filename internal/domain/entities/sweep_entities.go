@@ -0,0 +1,112 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Auto-sweep errors
+var (
+	ErrSweepRuleNotFound = errors.New("auto-sweep is not set up")
+	ErrInvalidSweepRule  = errors.New("invalid auto-sweep rule")
+)
+
+// SweepCadence is how often idle buying power is checked and swept
+type SweepCadence string
+
+const (
+	SweepCadenceDaily   SweepCadence = "daily"
+	SweepCadenceWeekly  SweepCadence = "weekly"
+	SweepCadenceMonthly SweepCadence = "monthly"
+)
+
+// IsValid reports whether the cadence is supported
+func (c SweepCadence) IsValid() bool {
+	switch c {
+	case SweepCadenceDaily, SweepCadenceWeekly, SweepCadenceMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next returns the first run after now on the cadence, counting from the
+// previous scheduled run so the time of day is kept and missed runs are not
+// made up
+func (c SweepCadence) Next(scheduled, now time.Time) time.Time {
+	next := scheduled
+	for !next.After(now) {
+		switch c {
+		case SweepCadenceWeekly:
+			next = next.AddDate(0, 0, 7)
+		case SweepCadenceMonthly:
+			next = next.AddDate(0, 1, 0)
+		default:
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+// SweepRule is a user's opt-in instruction to invest buying power above a
+// floor into a basket. Unlike a recurring investment of a fixed amount, the
+// amount swept depends on how much cash is sitting idle at each run.
+type SweepRule struct {
+	ID       uuid.UUID       `json:"id" db:"id"`
+	UserID   uuid.UUID       `json:"user_id" db:"user_id"`
+	BasketID uuid.UUID       `json:"basket_id" db:"basket_id"`
+	Floor    decimal.Decimal `json:"floor" db:"floor"` // Buying power always left uninvested
+	// MinAmount skips runs that would sweep less; MaxAmount caps one run
+	MinAmount           decimal.Decimal  `json:"min_amount" db:"min_amount"`
+	MaxAmount           *decimal.Decimal `json:"max_amount,omitempty" db:"max_amount"`
+	Cadence             SweepCadence     `json:"cadence" db:"cadence"`
+	Enabled             bool             `json:"enabled" db:"enabled"`
+	NextRunAt           time.Time        `json:"next_run_at" db:"next_run_at"`
+	LastRunAt           *time.Time       `json:"last_run_at,omitempty" db:"last_run_at"`
+	ConsecutiveFailures int              `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledReason      *string          `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	CreatedAt           time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// SweepRunStatus is the outcome of one sweep
+type SweepRunStatus string
+
+const (
+	SweepRunInvested SweepRunStatus = "invested"
+	SweepRunSkipped  SweepRunStatus = "skipped"
+	SweepRunFailed   SweepRunStatus = "failed"
+)
+
+// SweepRun records one sweep of a user's idle buying power
+type SweepRun struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	RuleID      uuid.UUID       `json:"rule_id" db:"rule_id"`
+	UserID      uuid.UUID       `json:"user_id" db:"user_id"`
+	BasketID    uuid.UUID       `json:"basket_id" db:"basket_id"`
+	Status      SweepRunStatus  `json:"status" db:"status"`
+	BuyingPower decimal.Decimal `json:"buying_power" db:"buying_power"`
+	Amount      decimal.Decimal `json:"amount" db:"amount"`
+	OrderID     *uuid.UUID      `json:"order_id,omitempty" db:"order_id"`
+	Reason      *string         `json:"reason,omitempty" db:"reason"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// SweepOverview is a user's auto-sweep rule and its recent runs
+type SweepOverview struct {
+	Rule *SweepRule  `json:"rule,omitempty"`
+	Runs []*SweepRun `json:"runs"`
+}
+
+// SaveSweepRuleRequest sets up or changes auto-sweep and turns it on. The
+// platform's default cash basket is used when no basket is given.
+type SaveSweepRuleRequest struct {
+	BasketID  *uuid.UUID       `json:"basket_id,omitempty"`
+	Floor     decimal.Decimal  `json:"floor"`
+	MinAmount *decimal.Decimal `json:"min_amount,omitempty"`
+	MaxAmount *decimal.Decimal `json:"max_amount,omitempty"`
+	Cadence   SweepCadence     `json:"cadence" binding:"required"`
+}
@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload errors
+var (
+	ErrUploadNotFound     = errors.New("upload not found")
+	ErrInvalidUpload      = errors.New("invalid upload")
+	ErrUploadNotReady     = errors.New("upload has not finished")
+	ErrUploadRejected     = errors.New("upload was rejected")
+	ErrUploadsUnavailable = errors.New("file uploads are unavailable")
+)
+
+// UploadPurpose is what an uploaded file will be attached to
+type UploadPurpose string
+
+const (
+	UploadPurposeKYC     UploadPurpose = "kyc"
+	UploadPurposeEDD     UploadPurpose = "edd"
+	UploadPurposeKYB     UploadPurpose = "kyb"
+	UploadPurposeSupport UploadPurpose = "support"
+)
+
+// IsValid reports whether the purpose is known
+func (p UploadPurpose) IsValid() bool {
+	switch p {
+	case UploadPurposeKYC, UploadPurposeEDD, UploadPurposeKYB, UploadPurposeSupport:
+		return true
+	}
+	return false
+}
+
+// UploadStatus tracks an upload from URL issue to use
+type UploadStatus string
+
+const (
+	UploadStatusPending   UploadStatus = "pending"   // URL issued, file not yet confirmed
+	UploadStatusAvailable UploadStatus = "available" // validated and scanned clean
+	UploadStatusRejected  UploadStatus = "rejected"  // failed validation or the virus scan; the file is deleted
+)
+
+// Upload is a file a user put in object storage through a pre-signed URL
+type Upload struct {
+	ID              uuid.UUID       `json:"id"`
+	UserID          uuid.UUID       `json:"userId"`
+	Purpose         UploadPurpose   `json:"purpose"`
+	FileName        string          `json:"fileName"`
+	ContentType     string          `json:"contentType"`
+	SizeBytes       int64           `json:"sizeBytes"`
+	StorageKey      string          `json:"-"`
+	StorageRegion   ResidencyRegion `json:"storageRegion,omitempty"` // empty for uploads from before residency tagging, kept in the home region
+	Status          UploadStatus    `json:"status"`
+	ScanResult      string          `json:"-"` // clean, skipped or the signature found
+	RejectionReason *string         `json:"rejectionReason,omitempty"`
+	ExpiresAt       time.Time       `json:"expiresAt"` // pending uploads not completed by then are purged
+	CompletedAt     *time.Time      `json:"completedAt,omitempty"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+}
+
+// CreateUploadRequest declares the file a user is about to upload
+type CreateUploadRequest struct {
+	Purpose     UploadPurpose `json:"purpose" binding:"required"`
+	FileName    string        `json:"fileName" binding:"required,max=255"`
+	ContentType string        `json:"contentType" binding:"required"`
+	SizeBytes   int64         `json:"sizeBytes" binding:"required,gt=0"`
+}
+
+// UploadTicket tells the client where and how to send the file. The request
+// must use the method and headers given, or storage refuses the signature.
+type UploadTicket struct {
+	Upload    *Upload           `json:"upload"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// UploadDownload is a short-lived link to an uploaded file
+type UploadDownload struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Wallet backfill errors
+var (
+	ErrWalletBackfillNotFound = errors.New("wallet backfill not found")
+	ErrWalletBackfillRunning  = errors.New("a wallet backfill is already running")
+)
+
+// WalletBackfillStatus tracks a backfill while it enqueues jobs
+type WalletBackfillStatus string
+
+const (
+	WalletBackfillRunning   WalletBackfillStatus = "running"
+	WalletBackfillCompleted WalletBackfillStatus = "completed" // every eligible user has a job
+	WalletBackfillFailed    WalletBackfillStatus = "failed"
+	WalletBackfillCancelled WalletBackfillStatus = "cancelled"
+)
+
+// IsTerminal reports whether the backfill has stopped enqueueing
+func (s WalletBackfillStatus) IsTerminal() bool {
+	return s != WalletBackfillRunning
+}
+
+// WalletBackfill enqueues wallet provisioning jobs for existing users that are
+// missing a wallet on one of Chains, a batch at a time
+type WalletBackfill struct {
+	ID                   uuid.UUID                `json:"id" db:"id"`
+	Chains               []string                 `json:"chains" db:"chains"`
+	Status               WalletBackfillStatus     `json:"status" db:"status"`
+	DryRun               bool                     `json:"dry_run" db:"dry_run"`
+	BatchSize            int                      `json:"batch_size" db:"batch_size"`
+	BatchIntervalSeconds int                      `json:"batch_interval_seconds" db:"batch_interval_seconds"`
+	MaxInFlight          int                      `json:"max_in_flight" db:"max_in_flight"`
+	EligibleUsers        int                      `json:"eligible_users" db:"eligible_users"`
+	EnqueuedJobs         int                      `json:"enqueued_jobs" db:"enqueued_jobs"`
+	CursorUserID         *uuid.UUID               `json:"-" db:"cursor_user_id"`
+	ErrorMessage         *string                  `json:"error_message,omitempty" db:"error_message"`
+	RequestedBy          *uuid.UUID               `json:"requested_by,omitempty" db:"requested_by"`
+	StartedAt            time.Time                `json:"started_at" db:"started_at"`
+	CompletedAt          *time.Time               `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt            time.Time                `json:"updated_at" db:"updated_at"`
+	Jobs                 *WalletBackfillJobCounts `json:"jobs,omitempty" db:"-"`
+}
+
+// WalletBackfillJobCounts breaks a backfill's provisioning jobs down by status
+type WalletBackfillJobCounts struct {
+	Queued     int `json:"queued"`
+	InProgress int `json:"in_progress"`
+	Retry      int `json:"retry"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+}
+
+// Pending is the number of jobs the provisioning scheduler has yet to finish
+func (c WalletBackfillJobCounts) Pending() int {
+	return c.Queued + c.InProgress + c.Retry
+}
+
+// WalletBackfillCandidate is a user missing wallets on some backfill chains
+type WalletBackfillCandidate struct {
+	UserID        uuid.UUID
+	MissingChains []string
+}
+
+// StartWalletBackfillRequest starts a backfill. Zero values fall back to the
+// configured batch defaults.
+type StartWalletBackfillRequest struct {
+	Chains               []string `json:"chains" binding:"required,min=1"`
+	BatchSize            int      `json:"batch_size,omitempty"`
+	BatchIntervalSeconds int      `json:"batch_interval_seconds,omitempty"`
+	MaxInFlight          int      `json:"max_in_flight,omitempty"`
+	DryRun               bool     `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ErrBalanceRefreshRateLimited is returned when a user forces refreshes too often
+var ErrBalanceRefreshRateLimited = errors.New("balance refresh rate limit exceeded")
+
+// WalletBalanceSnapshot is the cached Circle balance of one managed wallet.
+// LastSyncedAt is nil until the first successful read.
+type WalletBalanceSnapshot struct {
+	WalletID       uuid.UUID       `json:"wallet_id" db:"managed_wallet_id"`
+	UserID         uuid.UUID       `json:"user_id" db:"user_id"`
+	CircleWalletID string          `json:"circle_wallet_id" db:"circle_wallet_id"`
+	Chain          WalletChain     `json:"chain" db:"chain"`
+	USDCBalance    decimal.Decimal `json:"usdc_balance" db:"usdc_balance"`
+	LastSyncedAt   *time.Time      `json:"last_synced_at,omitempty" db:"last_synced_at"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+}
+
+// UserWalletBalances aggregates a user's cached wallet balances. LastSyncedAt
+// is the oldest sync among the wallets, so it bounds how stale the total is.
+type UserWalletBalances struct {
+	UserID       uuid.UUID                `json:"user_id"`
+	Wallets      []*WalletBalanceSnapshot `json:"wallets"`
+	TotalUSDC    decimal.Decimal          `json:"total_usdc"`
+	LastSyncedAt *time.Time               `json:"last_synced_at,omitempty"`
+	Stale        bool                     `json:"stale"`
+}
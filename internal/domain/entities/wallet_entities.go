@@ -13,64 +13,66 @@ import (
 type WalletChain string
 
 const (
-	// Solana - Only SOL-DEVNET is currently supported
+	// Solana devnet, the chain enabled by default. Other chains are defined
+	// in the chain registry (see DefaultChains).
 	ChainSOLDevnet WalletChain = "SOL-DEVNET"
-	
+
 	// USDC Token Addresses by Chain
 	// SOL-DEVNET USDC token address
 	USDCTokenAddressSOLDevnet = "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
 )
 
-// GetMainnetChains returns production chains (currently empty)
+// GetMainnetChains returns the enabled production chains
 func GetMainnetChains() []WalletChain {
-	return []WalletChain{}
+	return enabledChains(false)
 }
 
-// GetTestnetChains returns testnet chains
+// GetTestnetChains returns the enabled testnet chains
 func GetTestnetChains() []WalletChain {
-	return []WalletChain{ChainSOLDevnet}
+	return enabledChains(true)
 }
 
-// IsValid checks if the chain is supported
-func (c WalletChain) IsValid() bool {
-	validChains := append(GetMainnetChains(), GetTestnetChains()...)
-	for _, chain := range validChains {
-		if chain == c {
-			return true
+func enabledChains(testnet bool) []WalletChain {
+	chains := []WalletChain{}
+	for _, info := range Chains().Enabled() {
+		if info.Testnet == testnet {
+			chains = append(chains, info.ID)
 		}
 	}
-	return false
+	return chains
+}
+
+// IsValid checks if the chain is enabled in the chain registry
+func (c WalletChain) IsValid() bool {
+	info, ok := c.Info()
+	return ok && info.Enabled
 }
 
-// IsTestnet checks if the chain is a testnet
+// IsTestnet checks if the chain is an enabled testnet
 func (c WalletChain) IsTestnet() bool {
-	testnets := GetTestnetChains()
-	for _, testnet := range testnets {
-		if testnet == c {
-			return true
-		}
-	}
-	return false
+	info, ok := c.Info()
+	return ok && info.Enabled && info.Testnet
 }
 
-// GetChainFamily returns the chain family (Solana only)
+// GetChainFamily returns the chain family ("Solana" or "EVM")
 func (c WalletChain) GetChainFamily() string {
-	switch c {
-	case ChainSOLDevnet:
-		return "Solana"
-	default:
+	info, ok := c.Info()
+	if !ok {
 		return "Unknown"
 	}
+	return string(info.Family)
 }
 
 // GetUSDCTokenAddress returns the USDC token address for the chain
 func (c WalletChain) GetUSDCTokenAddress() string {
-	switch c {
-	case ChainSOLDevnet:
-		return USDCTokenAddressSOLDevnet
-	default:
-		return ""
-	}
+	info, _ := c.Info()
+	return info.USDCTokenAddress
+}
+
+// ValidAddress reports whether address is well formed on the chain
+func (c WalletChain) ValidAddress(address string) bool {
+	info, ok := c.Info()
+	return ok && info.ValidAddress(address)
 }
 
 // WalletAccountType represents the type of wallet account
@@ -234,6 +236,8 @@ type WalletProvisioningJob struct {
 	NextRetryAt    *time.Time                  `json:"next_retry_at" db:"next_retry_at"`
 	StartedAt      *time.Time                  `json:"started_at" db:"started_at"`
 	CompletedAt    *time.Time                  `json:"completed_at" db:"completed_at"`
+	LeaseExpiresAt *time.Time                  `json:"lease_expires_at,omitempty" db:"lease_expires_at"`
+	HeartbeatAt    *time.Time                  `json:"heartbeat_at,omitempty" db:"heartbeat_at"`
 	CreatedAt      time.Time                   `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time                   `json:"updated_at" db:"updated_at"`
 }
@@ -258,6 +262,7 @@ func (job *WalletProvisioningJob) MarkCompleted() {
 	now := time.Now()
 	job.Status = ProvisioningStatusCompleted
 	job.CompletedAt = &now
+	job.LeaseExpiresAt = nil
 	job.UpdatedAt = now
 }
 
@@ -266,6 +271,7 @@ func (job *WalletProvisioningJob) MarkFailed(errorMsg string, retryDelay time.Du
 	now := time.Now()
 	job.Status = ProvisioningStatusFailed
 	job.ErrorMessage = &errorMsg
+	job.LeaseExpiresAt = nil
 	job.UpdatedAt = now
 
 	if job.CanRetry() {
@@ -275,6 +281,15 @@ func (job *WalletProvisioningJob) MarkFailed(errorMsg string, retryDelay time.Du
 	}
 }
 
+// TakeLease claims the job for ttl. The worker renews the lease while it is
+// provisioning; once it lapses the job janitor treats the job as abandoned.
+func (job *WalletProvisioningJob) TakeLease(ttl time.Duration) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	job.HeartbeatAt = &now
+	job.LeaseExpiresAt = &expiresAt
+}
+
 // AddCircleRequest adds a Circle API request/response to the log
 func (job *WalletProvisioningJob) AddCircleRequest(operation string, request, response any) {
 	if job.CircleRequests == nil {
@@ -671,7 +686,9 @@ type CircleTransferRequest struct {
 	IDempotencyKey         string   `json:"idempotencyKey"`
 	EntitySecretCiphertext string   `json:"entitySecretCiphertext"`
 	WalletID               string   `json:"walletId"`
-	TokenID                string   `json:"tokenId"`
+	TokenID                string   `json:"tokenId,omitempty"`
+	TokenAddress           string   `json:"tokenAddress,omitempty"` // With Blockchain, in place of TokenID
+	Blockchain             string   `json:"blockchain,omitempty"`
 	Amounts                []string `json:"amounts"`
 	DestinationAddress     string   `json:"destinationAddress,omitempty"`
 	DestinationWalletID    string   `json:"destinationWalletId,omitempty"`
@@ -761,6 +778,13 @@ func (r *CircleWalletBalancesResponse) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// CircleWalletBalances is one wallet from Circle's bulk balances endpoint
+type CircleWalletBalances struct {
+	ID            string               `json:"id"`
+	Blockchain    string               `json:"blockchain"`
+	TokenBalances []CircleTokenBalance `json:"tokenBalances"`
+}
+
 // GetUSDCBalance extracts USDC balance from token balances
 func (r *CircleWalletBalancesResponse) GetUSDCBalance() string {
 	for _, balance := range r.TokenBalances {
@@ -0,0 +1,134 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Warehouse export errors
+var (
+	ErrWarehouseExportUnavailable = errors.New("warehouse export is not configured")
+	ErrWarehouseStreamBusy        = errors.New("warehouse stream is being exported by another worker")
+	ErrWarehouseCursorMoved       = errors.New("warehouse cursor was moved by a backfill")
+	ErrInvalidWarehouseBackfill   = errors.New("invalid warehouse backfill")
+)
+
+// WarehouseStream is a kind of business event exported to the data warehouse.
+// Each stream is read from the table that records it.
+type WarehouseStream string
+
+const (
+	WarehouseStreamSignups     WarehouseStream = "signups"      // users
+	WarehouseStreamDeposits    WarehouseStream = "deposits"     // credited deposits
+	WarehouseStreamTrades      WarehouseStream = "trades"       // filled live orders
+	WarehouseStreamChurnSignal WarehouseStream = "churn_signal" // inactivity stage changes
+	// Onboarding experiment funnel
+	WarehouseStreamExperimentExposures   WarehouseStream = "experiment_exposures"   // users shown a variant
+	WarehouseStreamExperimentConversions WarehouseStream = "experiment_conversions" // users in a variant reaching a goal
+)
+
+// WarehouseSchema describes the properties of a stream's events. Version is
+// bumped whenever a property is removed or changes meaning, so the data team
+// can tell rows written under the old contract from the new; adding a
+// property does not need a new version.
+type WarehouseSchema struct {
+	Stream     WarehouseStream `json:"stream"`
+	EventType  string          `json:"event_type"`
+	Version    int             `json:"version"`
+	Properties []string        `json:"properties"`
+}
+
+// WarehouseSchemas is the current contract for every stream
+var WarehouseSchemas = map[WarehouseStream]WarehouseSchema{
+	WarehouseStreamSignups: {
+		Stream: WarehouseStreamSignups, EventType: "user_signed_up", Version: 1,
+		Properties: []string{"account_type", "onboarding_status", "kyc_status"},
+	},
+	WarehouseStreamDeposits: {
+		Stream: WarehouseStreamDeposits, EventType: "deposit_credited", Version: 1,
+		Properties: []string{"chain", "token", "amount", "currency"},
+	},
+	WarehouseStreamTrades: {
+		Stream: WarehouseStreamTrades, EventType: "trade_filled", Version: 1,
+		Properties: []string{"basket_id", "side", "order_type", "amount", "currency"},
+	},
+	WarehouseStreamChurnSignal: {
+		Stream: WarehouseStreamChurnSignal, EventType: "inactivity_stage_reached", Version: 1,
+		Properties: []string{"stage", "last_activity_at"},
+	},
+	WarehouseStreamExperimentExposures: {
+		Stream: WarehouseStreamExperimentExposures, EventType: "experiment_exposed", Version: 1,
+		Properties: []string{"experiment", "variant"},
+	},
+	WarehouseStreamExperimentConversions: {
+		Stream: WarehouseStreamExperimentConversions, EventType: "experiment_converted", Version: 1,
+		Properties: []string{"experiment", "variant", "goal"},
+	},
+}
+
+// IsValid reports whether the stream is exported
+func (s WarehouseStream) IsValid() bool {
+	_, ok := WarehouseSchemas[s]
+	return ok
+}
+
+// AllWarehouseStreams lists every stream in export order
+func AllWarehouseStreams() []WarehouseStream {
+	return []WarehouseStream{
+		WarehouseStreamSignups,
+		WarehouseStreamDeposits,
+		WarehouseStreamTrades,
+		WarehouseStreamChurnSignal,
+		WarehouseStreamExperimentExposures,
+		WarehouseStreamExperimentConversions,
+	}
+}
+
+// WarehouseEvent is one normalized business event. ID is derived from the
+// source row, so an event exported twice, for example during a backfill, has
+// the same ID and the warehouse can drop the duplicate.
+type WarehouseEvent struct {
+	ID            string                 `json:"event_id"`
+	Stream        WarehouseStream        `json:"-"`
+	Type          string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version"`
+	UserID        *uuid.UUID             `json:"user_id,omitempty"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	Properties    map[string]interface{} `json:"properties"`
+	// Position and SourceID place the event in its stream for the export cursor
+	Position time.Time `json:"-"`
+	SourceID string    `json:"-"`
+}
+
+// WarehouseCursor is how far a stream has been exported: every event at or
+// before (Position, LastID) has been written to the warehouse
+type WarehouseCursor struct {
+	Stream        WarehouseStream `json:"stream" db:"stream"`
+	Position      time.Time       `json:"position" db:"position"`
+	LastID        string          `json:"last_id" db:"last_id"`
+	ExportedCount int64           `json:"exported_count" db:"exported_count"`
+	LastError     *string         `json:"last_error,omitempty" db:"last_error"`
+	LastExportAt  *time.Time      `json:"last_export_at,omitempty" db:"last_export_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+	// Generation changes on every backfill, so a run that started before it
+	// cannot save its older position over the reset one
+	Generation int64 `json:"-" db:"generation"`
+}
+
+// WarehouseStatus is the export state of every stream and the schemas they
+// are written with
+type WarehouseStatus struct {
+	Destination string             `json:"destination"`
+	Cursors     []*WarehouseCursor `json:"cursors"`
+	Schemas     []WarehouseSchema  `json:"schemas"`
+}
+
+// WarehouseBackfillRequest exports streams again from a date, for a new
+// warehouse table or after a schema version change
+type WarehouseBackfillRequest struct {
+	Streams []WarehouseStream `json:"streams,omitempty"`       // Defaults to every stream
+	From    string            `json:"from" binding:"required"` // YYYY-MM-DD
+	Reason  string            `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrArchivedWebhookNotFound is returned for an unknown archived webhook
+var ErrArchivedWebhookNotFound = errors.New("archived webhook not found")
+
+// Providers whose webhooks are archived
+const (
+	WebhookProviderChain     = "chain"     // chain deposit notifications
+	WebhookProviderBrokerage = "brokerage" // brokerage order fills
+	WebhookProviderKYC       = "kyc"       // KYC provider callbacks
+	WebhookProviderBank      = "bank"      // credits into shared deposit accounts
+)
+
+// Records an archived webhook can be linked to
+const (
+	WebhookSubjectDeposit    = "deposit"
+	WebhookSubjectWithdrawal = "withdrawal"
+	WebhookSubjectOrder      = "order"
+	WebhookSubjectUser       = "user"
+	WebhookSubjectBankCredit = "bank_credit"
+)
+
+// ArchivedWebhook is a raw provider webhook kept for dispute investigations.
+// The body is in cold storage; the row holds what it is searched by.
+type ArchivedWebhook struct {
+	ID          uuid.UUID  `json:"id"`
+	Provider    string     `json:"provider"`
+	EventType   string     `json:"event_type"`
+	Reference   string     `json:"reference,omitempty"` // the provider's ID for what the webhook is about
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	StorageURI  string     `json:"storage_uri"`
+	SHA256      string     `json:"sha256"`
+	SizeBytes   int        `json:"size_bytes"`
+	ContentType string     `json:"content_type"`
+	// ResponseStatus is the status we answered the provider with
+	ResponseStatus int             `json:"response_status"`
+	ReceivedAt     time.Time       `json:"received_at"`
+	Links          []WebhookLink   `json:"links,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"` // only when one webhook is fetched
+}
+
+// WebhookLink ties an archived webhook to a record it produced or updated
+type WebhookLink struct {
+	SubjectType string    `json:"subject_type"`
+	SubjectID   uuid.UUID `json:"subject_id"`
+}
+
+// WebhookArchiveFilter narrows a search of the archive
+type WebhookArchiveFilter struct {
+	Provider    string
+	EventType   string
+	Reference   string
+	UserID      *uuid.UUID
+	SubjectType string
+	SubjectID   *uuid.UUID
+	From        *time.Time
+	To          *time.Time
+	Limit       int
+	Offset      int
+}
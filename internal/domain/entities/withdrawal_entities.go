@@ -12,6 +12,7 @@ type WithdrawalStatus string
 
 const (
 	WithdrawalStatusPending         WithdrawalStatus = "pending"
+	WithdrawalStatusPendingApproval WithdrawalStatus = "pending_approval"
 	WithdrawalStatusAlpacaDebited   WithdrawalStatus = "alpaca_debited"
 	WithdrawalStatusDueProcessing   WithdrawalStatus = "due_processing"
 	WithdrawalStatusOnChainTransfer WithdrawalStatus = "onchain_transfer"
@@ -27,6 +28,7 @@ type Withdrawal struct {
 	Amount               decimal.Decimal  `json:"amount" db:"amount"`
 	DestinationChain     string           `json:"destination_chain" db:"destination_chain"`
 	DestinationAddress   string           `json:"destination_address" db:"destination_address"`
+	RecipientID          *uuid.UUID       `json:"recipient_id,omitempty" db:"recipient_id"`
 	Status               WithdrawalStatus `json:"status" db:"status"`
 	AlpacaJournalID      *string          `json:"alpaca_journal_id,omitempty" db:"alpaca_journal_id"`
 	DueTransferID        *string          `json:"due_transfer_id,omitempty" db:"due_transfer_id"`
@@ -45,6 +47,7 @@ type InitiateWithdrawalRequest struct {
 	Amount             decimal.Decimal `json:"amount"`
 	DestinationChain   string          `json:"destination_chain"`
 	DestinationAddress string          `json:"destination_address"`
+	RecipientID        *uuid.UUID      `json:"recipient_id,omitempty"` // saved recipient used instead of chain and address
 }
 
 // InitiateWithdrawalResponse represents the response to a withdrawal request
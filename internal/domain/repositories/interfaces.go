@@ -27,6 +27,8 @@ type AISummaryRepository interface {
 	ListByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*AISummary, error)
 	Update(ctx context.Context, summary *AISummary) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	ListByArtifactScheme(ctx context.Context, scheme string, after uuid.UUID, limit int) ([]*AISummary, error)
+	ReplaceArtifactURI(ctx context.Context, id uuid.UUID, from, to string) (bool, error)
 }
 
 // PortfolioRepository defines the interface for portfolio data access
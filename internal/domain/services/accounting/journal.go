@@ -0,0 +1,157 @@
+package accounting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Mapping assigns platform ledger account types to general ledger accounts
+type Mapping struct {
+	Accounts map[entities.AccountType]string
+	// SuspenseAccount receives activity on account types missing from
+	// Accounts. When empty, unmapped activity fails the export instead.
+	SuspenseAccount string
+	// RoundingAccount absorbs the difference left by rounding each line to
+	// cents. Defaults to SuspenseAccount.
+	RoundingAccount string
+}
+
+func (m Mapping) glAccount(accountType entities.AccountType) (string, bool) {
+	if account, ok := m.Accounts[accountType]; ok && account != "" {
+		return account, true
+	}
+	if m.SuspenseAccount != "" {
+		return m.SuspenseAccount, true
+	}
+	return "", false
+}
+
+func (m Mapping) roundingAccount() string {
+	if m.RoundingAccount != "" {
+		return m.RoundingAccount
+	}
+	return m.SuspenseAccount
+}
+
+// JournalReference is the document number a day's journal is exported under.
+// It is stable so destinations can recognise a re-export of the same day.
+func JournalReference(date time.Time) string {
+	return "STACK-" + date.Format("20060102")
+}
+
+// BuildJournal summarises a day's ledger activity as one balanced journal
+// entry. Activity is grouped per general ledger account, transaction type,
+// currency and side, and rounded to cents; USDC is booked at par with USD.
+// Any rounding difference is posted to the mapping's rounding account.
+func BuildJournal(date time.Time, activity []entities.LedgerActivity, mapping Mapping) (*entities.AccountingJournal, error) {
+	type lineKey struct {
+		glAccount       string
+		accountType     entities.AccountType
+		transactionType entities.TransactionType
+		postingType     entities.EntryType
+		currency        string
+	}
+
+	sums := make(map[lineKey]decimal.Decimal)
+	var unmapped []string
+	for _, a := range activity {
+		glAccount, ok := mapping.glAccount(a.AccountType)
+		if !ok {
+			unmapped = append(unmapped, string(a.AccountType))
+			continue
+		}
+		key := lineKey{glAccount, a.AccountType, a.TransactionType, a.EntryType, a.Currency}
+		sums[key] = sums[key].Add(a.Amount)
+	}
+	if len(unmapped) > 0 {
+		sort.Strings(unmapped)
+		return nil, fmt.Errorf("%w: %v", entities.ErrUnmappedLedgerAccount, dedupe(unmapped))
+	}
+
+	journal := &entities.AccountingJournal{
+		Date:         date,
+		Reference:    JournalReference(date),
+		Lines:        []entities.JournalLine{},
+		TotalDebits:  decimal.Zero,
+		TotalCredits: decimal.Zero,
+	}
+	for key, amount := range sums {
+		amount = amount.Round(2)
+		if amount.IsZero() {
+			continue
+		}
+		journal.Lines = append(journal.Lines, entities.JournalLine{
+			GLAccount:       key.glAccount,
+			AccountType:     key.accountType,
+			TransactionType: key.transactionType,
+			PostingType:     key.postingType,
+			Currency:        key.currency,
+			Amount:          amount,
+			Description:     fmt.Sprintf("%s %s (%s)", key.transactionType, key.accountType, key.currency),
+		})
+		if key.postingType == entities.EntryTypeDebit {
+			journal.TotalDebits = journal.TotalDebits.Add(amount)
+		} else {
+			journal.TotalCredits = journal.TotalCredits.Add(amount)
+		}
+	}
+
+	if diff := journal.TotalDebits.Sub(journal.TotalCredits); !diff.IsZero() {
+		rounding := mapping.roundingAccount()
+		if rounding == "" {
+			return nil, fmt.Errorf("journal is off by %s after rounding and no rounding account is mapped", diff.StringFixed(2))
+		}
+		line := entities.JournalLine{
+			GLAccount:   rounding,
+			PostingType: entities.EntryTypeCredit,
+			Currency:    "USD",
+			Amount:      diff.Abs(),
+			Description: "rounding",
+		}
+		if diff.IsNegative() {
+			line.PostingType = entities.EntryTypeDebit
+			journal.TotalDebits = journal.TotalDebits.Add(line.Amount)
+		} else {
+			journal.TotalCredits = journal.TotalCredits.Add(line.Amount)
+		}
+		journal.Lines = append(journal.Lines, line)
+	}
+
+	sort.Slice(journal.Lines, func(i, j int) bool {
+		a, b := journal.Lines[i], journal.Lines[j]
+		if a.GLAccount != b.GLAccount {
+			return a.GLAccount < b.GLAccount
+		}
+		if a.PostingType != b.PostingType {
+			return a.PostingType == entities.EntryTypeDebit
+		}
+		return a.Description < b.Description
+	})
+	journal.Checksum = checksum(journal.Lines)
+	return journal, nil
+}
+
+func checksum(lines []entities.JournalLine) string {
+	h := sha256.New()
+	for _, line := range lines {
+		fmt.Fprintf(h, "%s|%s|%s|%s|%s\n", line.GLAccount, line.PostingType, line.Currency, line.Description, line.Amount.StringFixed(2))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func dedupe(sorted []string) []string {
+	out := sorted[:0]
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			out = append(out, value)
+		}
+	}
+	return out
+}
@@ -0,0 +1,336 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+const dayFormat = "2006-01-02"
+
+// Repository reads ledger activity and tracks journal exports
+type Repository interface {
+	// LedgerActivity sums completed and reversed ledger entries created in [from, to)
+	LedgerActivity(ctx context.Context, from, to time.Time) ([]entities.LedgerActivity, error)
+	// ClaimExport locks the export of one day to a destination, creating its
+	// record if needed. Days already exported are only claimed when force is
+	// set. Returns ErrAccountingExportInProgress when the day is locked or,
+	// without force, already exported.
+	ClaimExport(ctx context.Context, date time.Time, destination string, force bool, now time.Time, lease time.Duration) (*entities.AccountingExport, error)
+	// FinishExport stores the outcome and releases the lock
+	FinishExport(ctx context.Context, export *entities.AccountingExport) error
+	ListExports(ctx context.Context, destination string, from, to time.Time) ([]*entities.AccountingExport, error)
+}
+
+// Exporter delivers a journal to the accounting system
+type Exporter interface {
+	// Name identifies the destination in export records, e.g. csv or quickbooks
+	Name() string
+	// ExportJournal sends the journal and returns its reference at the
+	// destination. previousRef is set when the day was exported before, so
+	// the destination can replace that entry rather than add a second one.
+	ExportJournal(ctx context.Context, journal *entities.AccountingJournal, previousRef string) (string, error)
+}
+
+// Config controls the account mapping and when days are exported
+type Config struct {
+	Mapping      Mapping
+	Location     *time.Location // Business day boundaries; defaults to UTC
+	LookbackDays int            // Past days checked for missing exports on each run
+	SettleDelay  time.Duration  // Wait after midnight before a day is exported, for late postings
+	MaxRangeDays int            // Longest range one re-export may cover
+	Lease        time.Duration  // How long a claimed day is locked against other exporters
+	Interval     time.Duration
+}
+
+// DefaultConfig exports each UTC day two hours after it ends and catches up
+// on the past week
+func DefaultConfig() Config {
+	return Config{
+		Location:     time.UTC,
+		LookbackDays: 7,
+		SettleDelay:  2 * time.Hour,
+		MaxRangeDays: 31,
+		Lease:        10 * time.Minute,
+		Interval:     time.Hour,
+	}
+}
+
+// ReexportResult reports a re-export of a date range
+type ReexportResult struct {
+	Exports    []*entities.AccountingExport `json:"exports"`
+	InProgress []string                     `json:"in_progress,omitempty"` // Days skipped because another export held them
+}
+
+// Service exports the platform ledger to the finance team's general ledger
+// as one summary journal entry per business day. Each day is exported once
+// per destination; an admin re-export replaces the destination's copy.
+type Service struct {
+	repo     Repository
+	exporter Exporter
+	config   Config
+	logger   *zap.Logger
+	tracker  *workerstatus.Tracker
+	now      func() time.Time
+}
+
+// NewService creates a new accounting export service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.Location == nil {
+		config.Location = defaults.Location
+	}
+	if config.LookbackDays <= 0 {
+		config.LookbackDays = defaults.LookbackDays
+	}
+	if config.SettleDelay < 0 {
+		config.SettleDelay = defaults.SettleDelay
+	}
+	if config.MaxRangeDays <= 0 {
+		config.MaxRangeDays = defaults.MaxRangeDays
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetExporter sets the accounting system journals are sent to. Without one
+// journals can be previewed but not exported.
+func (s *Service) SetExporter(exporter Exporter) {
+	s.exporter = exporter
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports runs to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often missing days are exported
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// ParseDay parses a YYYY-MM-DD business day
+func (s *Service) ParseDay(value string) (time.Time, error) {
+	day, err := time.ParseInLocation(dayFormat, value, s.config.Location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", entities.ErrInvalidAccountingDateRange, value)
+	}
+	return day, nil
+}
+
+// Journal builds the journal for a day without exporting it
+func (s *Service) Journal(ctx context.Context, day time.Time) (*entities.AccountingJournal, error) {
+	day = s.startOfDay(day)
+	activity, err := s.repo.LedgerActivity(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger activity: %w", err)
+	}
+	return BuildJournal(day, activity, s.config.Mapping)
+}
+
+// ListExports returns export records for days in [from, to]
+func (s *Service) ListExports(ctx context.Context, from, to time.Time) ([]*entities.AccountingExport, error) {
+	if s.exporter == nil {
+		return nil, entities.ErrAccountingExportUnavailable
+	}
+	return s.repo.ListExports(ctx, s.exporter.Name(), s.startOfDay(from), s.startOfDay(to))
+}
+
+// Start exports settled days that have not been exported on every tick
+// until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.ExportPending(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Accounting export run failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// ExportPending exports each settled day in the lookback window that has no
+// successful export yet, returning how many days were exported
+func (s *Service) ExportPending(ctx context.Context) (int, error) {
+	if s.exporter == nil {
+		return 0, entities.ErrAccountingExportUnavailable
+	}
+
+	last := s.lastSettledDay()
+	first := last.AddDate(0, 0, 1-s.config.LookbackDays)
+
+	existing, err := s.repo.ListExports(ctx, s.exporter.Name(), first, last)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounting exports: %w", err)
+	}
+	done := make(map[string]bool, len(existing))
+	for _, export := range existing {
+		if export.Status == entities.AccountingExportExported || export.Status == entities.AccountingExportEmpty {
+			done[export.JournalDate.Format(dayFormat)] = true
+		}
+	}
+
+	exported := 0
+	var errs []error
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if done[day.Format(dayFormat)] {
+			continue
+		}
+		export, err := s.exportDay(ctx, day, false)
+		if errors.Is(err, entities.ErrAccountingExportInProgress) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", day.Format(dayFormat), err))
+			continue
+		}
+		if export.Status == entities.AccountingExportExported {
+			exported++
+		}
+	}
+	return exported, errors.Join(errs...)
+}
+
+// Reexport exports every day in [from, to] again, replacing what the
+// destination holds. Days locked by another export are reported, not waited
+// for.
+func (s *Service) Reexport(ctx context.Context, from, to time.Time) (*ReexportResult, error) {
+	if s.exporter == nil {
+		return nil, entities.ErrAccountingExportUnavailable
+	}
+
+	from, to = s.startOfDay(from), s.startOfDay(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from is after to", entities.ErrInvalidAccountingDateRange)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > s.config.MaxRangeDays {
+		return nil, fmt.Errorf("%w: at most %d days per re-export", entities.ErrInvalidAccountingDateRange, s.config.MaxRangeDays)
+	}
+	if to.After(s.lastSettledDay()) {
+		return nil, fmt.Errorf("%w: days can be exported once they have ended and settled", entities.ErrInvalidAccountingDateRange)
+	}
+
+	result := &ReexportResult{Exports: []*entities.AccountingExport{}}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		export, err := s.exportDay(ctx, day, true)
+		if errors.Is(err, entities.ErrAccountingExportInProgress) {
+			result.InProgress = append(result.InProgress, day.Format(dayFormat))
+			continue
+		}
+		if err != nil && export == nil {
+			return nil, err
+		}
+		result.Exports = append(result.Exports, export)
+	}
+	return result, nil
+}
+
+// exportDay claims a day and exports its journal. A failed export is
+// recorded on the returned export and also returned as the error.
+func (s *Service) exportDay(ctx context.Context, day time.Time, force bool) (*entities.AccountingExport, error) {
+	destination := s.exporter.Name()
+	export, err := s.repo.ClaimExport(ctx, day, destination, force, s.now().UTC(), s.config.Lease)
+	if err != nil {
+		return nil, err
+	}
+	export.Attempts++
+
+	exportErr := s.export(ctx, day, export)
+	if exportErr != nil {
+		export.Status = entities.AccountingExportFailed
+		export.LastError = exportErr.Error()
+		s.logger.Error("Accounting export failed",
+			zap.String("date", day.Format(dayFormat)),
+			zap.String("destination", destination),
+			zap.Error(exportErr))
+	}
+	export.UpdatedAt = s.now().UTC()
+
+	if err := s.repo.FinishExport(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to record accounting export: %w", err)
+	}
+	return export, exportErr
+}
+
+func (s *Service) export(ctx context.Context, day time.Time, export *entities.AccountingExport) error {
+	journal, err := s.Journal(ctx, day)
+	if err != nil {
+		return err
+	}
+
+	export.LastError = ""
+	export.Checksum = journal.Checksum
+	export.LineCount = len(journal.Lines)
+	export.TotalDebits = journal.TotalDebits
+	if len(journal.Lines) == 0 {
+		export.Status = entities.AccountingExportEmpty
+		return nil
+	}
+
+	ref, err := s.exporter.ExportJournal(ctx, journal, export.ExternalRef)
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	export.Status = entities.AccountingExportExported
+	export.ExternalRef = ref
+	export.ExportedAt = &now
+
+	s.logger.Info("Accounting journal exported",
+		zap.String("date", day.Format(dayFormat)),
+		zap.String("destination", export.Destination),
+		zap.String("external_ref", ref),
+		zap.Int("lines", export.LineCount),
+		zap.String("total_debits", journal.TotalDebits.StringFixed(2)))
+	return nil
+}
+
+// lastSettledDay returns the most recent business day whose settle delay has
+// passed
+func (s *Service) lastSettledDay() time.Time {
+	today := s.startOfDay(s.now())
+	last := today.AddDate(0, 0, -1)
+	if s.now().Before(today.Add(s.config.SettleDelay)) {
+		last = last.AddDate(0, 0, -1)
+	}
+	return last
+}
+
+func (s *Service) startOfDay(t time.Time) time.Time {
+	t = t.In(s.config.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.config.Location)
+}
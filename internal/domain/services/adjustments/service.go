@@ -0,0 +1,335 @@
+package adjustments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+)
+
+// Repository persists ledger adjustments
+type Repository interface {
+	Create(ctx context.Context, adjustment *entities.LedgerAdjustment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.LedgerAdjustment, error)
+	List(ctx context.Context, filter entities.LedgerAdjustmentFilter) ([]*entities.LedgerAdjustment, error)
+	SetApprovalRequest(ctx context.Context, id, approvalRequestID uuid.UUID) error
+	// Resolve moves a pending adjustment to adjustment.Status, recording its
+	// ledger transaction, failure reason and posting time, and reports
+	// whether it was still pending
+	Resolve(ctx context.Context, adjustment *entities.LedgerAdjustment) (bool, error)
+}
+
+// Ledger posts adjustment transactions
+type Ledger interface {
+	GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+}
+
+// Approvals queues adjustments for a second admin's review
+type Approvals interface {
+	Submit(ctx context.Context, req *entities.SubmitApprovalRequest) (*entities.ApprovalRequest, error)
+}
+
+// Service lets ops post manual correcting entries under dual control. An
+// adjustment is requested with a reason code and supporting documents, held
+// until an admin other than the requester approves it, and then posted as a
+// single balanced transaction between the user's account and a system
+// account. It implements approvals.Handler for ApprovalActionLedgerAdjustment.
+type Service struct {
+	repo      Repository
+	ledger    Ledger
+	approvals Approvals
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates a ledger adjustment service
+func NewService(repo Repository, ledger Ledger, approvals Approvals, logger *zap.Logger) *Service {
+	return &Service{
+		repo:      repo,
+		ledger:    ledger,
+		approvals: approvals,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Request records an adjustment and queues it for approval. Nothing is
+// posted until a second admin approves it.
+func (s *Service) Request(ctx context.Context, adminID uuid.UUID, req *entities.CreateLedgerAdjustmentRequest) (*entities.LedgerAdjustment, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	// The accounts are resolved up front so a currency mismatch is caught
+	// before anyone is asked to review the adjustment
+	account, offset, err := s.accounts(ctx, req.UserID, req.AccountType, req.OffsetAccountType)
+	if err != nil {
+		return nil, err
+	}
+	if account.Currency != offset.Currency {
+		return nil, fmt.Errorf("%w: %s is held in %s but %s is held in %s", entities.ErrInvalidLedgerAdjustment,
+			req.AccountType, account.Currency, req.OffsetAccountType, offset.Currency)
+	}
+
+	now := s.now().UTC()
+	adjustment := &entities.LedgerAdjustment{
+		ID:                uuid.New(),
+		UserID:            req.UserID,
+		AccountType:       req.AccountType,
+		OffsetAccountType: req.OffsetAccountType,
+		Direction:         req.Direction,
+		Amount:            req.Amount,
+		Currency:          account.Currency,
+		ReasonCode:        req.ReasonCode,
+		Memo:              strings.TrimSpace(req.Memo),
+		Documents:         req.Documents,
+		Status:            entities.LedgerAdjustmentPendingApproval,
+		RequestedBy:       adminID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := s.repo.Create(ctx, adjustment); err != nil {
+		return nil, fmt.Errorf("failed to create ledger adjustment: %w", err)
+	}
+
+	request, err := s.approvals.Submit(ctx, &entities.SubmitApprovalRequest{
+		Action:      entities.ApprovalActionLedgerAdjustment,
+		ResourceID:  adjustment.ID.String(),
+		RequestedBy: &adminID,
+		Summary: fmt.Sprintf("%s %s of user %s by %s %s (%s)",
+			titleDirection(adjustment.Direction), adjustment.AccountType, adjustment.UserID,
+			adjustment.Amount.String(), adjustment.Currency, adjustment.ReasonCode),
+		Payload: map[string]interface{}{
+			"user_id":             adjustment.UserID.String(),
+			"account_type":        string(adjustment.AccountType),
+			"offset_account_type": string(adjustment.OffsetAccountType),
+			"direction":           string(adjustment.Direction),
+			"amount":              adjustment.Amount.String(),
+			"currency":            adjustment.Currency,
+			"reason_code":         string(adjustment.ReasonCode),
+			"memo":                adjustment.Memo,
+			"documents":           len(adjustment.Documents),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request approval: %w", err)
+	}
+	if err := s.repo.SetApprovalRequest(ctx, adjustment.ID, request.ID); err != nil {
+		return nil, fmt.Errorf("failed to link approval request: %w", err)
+	}
+	adjustment.ApprovalRequestID = &request.ID
+
+	s.logger.Info("Ledger adjustment requested",
+		zap.String("adjustment_id", adjustment.ID.String()),
+		zap.String("user_id", adjustment.UserID.String()),
+		zap.String("reason_code", string(adjustment.ReasonCode)),
+		zap.String("approval_request_id", request.ID.String()))
+	return adjustment, nil
+}
+
+// Get returns an adjustment
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.LedgerAdjustment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns adjustments, newest first
+func (s *Service) List(ctx context.Context, filter entities.LedgerAdjustmentFilter) ([]*entities.LedgerAdjustment, error) {
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Statement returns the posted adjustments on a user's accounts as they
+// appear on the user's statement
+func (s *Service) Statement(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entities.AdjustmentStatementLine, error) {
+	adjustments, err := s.List(ctx, entities.LedgerAdjustmentFilter{
+		UserID: &userID,
+		Status: entities.LedgerAdjustmentPosted,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]entities.AdjustmentStatementLine, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		lines = append(lines, adjustment.StatementLine())
+	}
+	return lines, nil
+}
+
+// Approved posts the adjustment. A posting the ledger refuses, such as one
+// that would take an account negative, marks the adjustment failed and is
+// returned so the approval request is marked failed too.
+func (s *Service) Approved(ctx context.Context, request *entities.ApprovalRequest) error {
+	adjustment, err := s.adjustmentFor(ctx, request)
+	if err != nil {
+		return err
+	}
+	if adjustment.Status != entities.LedgerAdjustmentPendingApproval {
+		return fmt.Errorf("%w: adjustment is %s", entities.ErrInvalidLedgerAdjustment, adjustment.Status)
+	}
+
+	tx, postErr := s.post(ctx, adjustment, request)
+	now := s.now().UTC()
+	adjustment.UpdatedAt = now
+	if postErr != nil {
+		message := postErr.Error()
+		adjustment.Status = entities.LedgerAdjustmentFailed
+		adjustment.FailureReason = &message
+	} else {
+		adjustment.Status = entities.LedgerAdjustmentPosted
+		adjustment.LedgerTransactionID = &tx.ID
+		adjustment.PostedAt = &now
+	}
+	if _, err := s.repo.Resolve(ctx, adjustment); err != nil {
+		s.logger.Error("Failed to record ledger adjustment outcome",
+			zap.String("adjustment_id", adjustment.ID.String()),
+			zap.String("status", string(adjustment.Status)),
+			zap.Error(err))
+	}
+	if postErr != nil {
+		return postErr
+	}
+
+	s.logger.Info("Ledger adjustment posted",
+		zap.String("adjustment_id", adjustment.ID.String()),
+		zap.String("ledger_transaction_id", tx.ID.String()))
+	return nil
+}
+
+// Rejected closes the adjustment without posting it
+func (s *Service) Rejected(ctx context.Context, request *entities.ApprovalRequest) error {
+	adjustment, err := s.adjustmentFor(ctx, request)
+	if err != nil {
+		return err
+	}
+	adjustment.Status = entities.LedgerAdjustmentRejected
+	adjustment.UpdatedAt = s.now().UTC()
+	if _, err := s.repo.Resolve(ctx, adjustment); err != nil {
+		return fmt.Errorf("failed to reject ledger adjustment: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) adjustmentFor(ctx context.Context, request *entities.ApprovalRequest) (*entities.LedgerAdjustment, error) {
+	id, err := uuid.Parse(request.ResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ledger adjustment id %q: %w", request.ResourceID, err)
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// post writes the adjustment's transaction. The idempotency key is the
+// adjustment's, so a retried approval cannot post it twice.
+func (s *Service) post(ctx context.Context, adjustment *entities.LedgerAdjustment, request *entities.ApprovalRequest) (*entities.LedgerTransaction, error) {
+	account, offset, err := s.accounts(ctx, adjustment.UserID, adjustment.AccountType, adjustment.OffsetAccountType)
+	if err != nil {
+		return nil, err
+	}
+
+	description := adjustment.ReasonCode.Label()
+	entries := ledger.NewEntryBuilder()
+	if adjustment.Direction == entities.AdjustmentIncrease {
+		entries.AddDebit(account.ID, adjustment.Amount, adjustment.Currency, &description).
+			AddCredit(offset.ID, adjustment.Amount, adjustment.Currency, &description)
+	} else {
+		entries.AddCredit(account.ID, adjustment.Amount, adjustment.Currency, &description).
+			AddDebit(offset.ID, adjustment.Amount, adjustment.Currency, &description)
+	}
+
+	var approvers []string
+	for _, decision := range request.Decisions {
+		if decision.Approved {
+			approvers = append(approvers, decision.AdminID.String())
+		}
+	}
+
+	txRequest, err := ledger.NewTransactionRequestBuilder().
+		WithUser(adjustment.UserID).
+		WithType(entities.TransactionTypeAdjustment).
+		WithReference(adjustment.ID, "ledger_adjustment").
+		WithIdempotencyKey("ledger-adjustment-" + adjustment.ID.String()).
+		WithDescription(description).
+		WithMetadata(map[string]any{
+			"adjustment_id":       adjustment.ID.String(),
+			"reason_code":         string(adjustment.ReasonCode),
+			"requested_by":        adjustment.RequestedBy.String(),
+			"approved_by":         approvers,
+			"approval_request_id": request.ID.String(),
+		}).
+		WithEntries(entries.Build()).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ledger adjustment transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, txRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post ledger adjustment: %w", err)
+	}
+	return tx, nil
+}
+
+func (s *Service) accounts(ctx context.Context, userID uuid.UUID, accountType, offsetType entities.AccountType) (*entities.LedgerAccount, *entities.LedgerAccount, error) {
+	account, err := s.ledger.GetOrCreateUserAccount(ctx, userID, accountType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get %s account: %w", accountType, err)
+	}
+	offset, err := s.ledger.GetSystemAccount(ctx, offsetType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get %s account: %w", offsetType, err)
+	}
+	return account, offset, nil
+}
+
+func validate(req *entities.CreateLedgerAdjustmentRequest) error {
+	switch {
+	case req.UserID == uuid.Nil:
+		return fmt.Errorf("%w: user_id is required", entities.ErrInvalidLedgerAdjustment)
+	case !req.AccountType.IsUserAccountType():
+		return fmt.Errorf("%w: %s is not a user account", entities.ErrInvalidLedgerAdjustment, req.AccountType)
+	case !req.OffsetAccountType.IsSystemAccountType():
+		return fmt.Errorf("%w: %s is not a system account", entities.ErrInvalidLedgerAdjustment, req.OffsetAccountType)
+	case req.Direction != entities.AdjustmentIncrease && req.Direction != entities.AdjustmentDecrease:
+		return fmt.Errorf("%w: direction must be increase or decrease", entities.ErrInvalidLedgerAdjustment)
+	case !req.Amount.IsPositive():
+		return fmt.Errorf("%w: amount must be positive", entities.ErrInvalidLedgerAdjustment)
+	case !req.ReasonCode.IsValid():
+		return fmt.Errorf("%w: unknown reason code %q", entities.ErrInvalidLedgerAdjustment, req.ReasonCode)
+	case strings.TrimSpace(req.Memo) == "":
+		return fmt.Errorf("%w: memo is required", entities.ErrInvalidLedgerAdjustment)
+	case len(req.Documents) == 0:
+		return fmt.Errorf("%w: at least one supporting document is required", entities.ErrInvalidLedgerAdjustment)
+	}
+	for _, document := range req.Documents {
+		if strings.TrimSpace(document.Name) == "" || strings.TrimSpace(document.URL) == "" {
+			return fmt.Errorf("%w: supporting documents need a name and URL", entities.ErrInvalidLedgerAdjustment)
+		}
+	}
+	return nil
+}
+
+func titleDirection(direction entities.AdjustmentDirection) string {
+	if direction == entities.AdjustmentIncrease {
+		return "Increase"
+	}
+	return "Decrease"
+}
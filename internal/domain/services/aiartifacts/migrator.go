@@ -0,0 +1,194 @@
+package aiartifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainrepos "github.com/stack-service/stack_service/internal/domain/repositories"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// MigrationRepository finds artifacts by backend and repoints them
+type MigrationRepository interface {
+	// ListByArtifactScheme returns summaries whose artifact URI has the
+	// scheme, ordered by ID and starting after the given ID
+	ListByArtifactScheme(ctx context.Context, scheme string, after uuid.UUID, limit int) ([]*domainrepos.AISummary, error)
+	// ReplaceArtifactURI points the summary at its new URI, returning false
+	// when its URI is no longer from
+	ReplaceArtifactURI(ctx context.Context, id uuid.UUID, from, to string) (bool, error)
+}
+
+// MigrationConfig controls how artifacts are moved between stores
+type MigrationConfig struct {
+	KeyPrefix    string        // Prepended to the keys artifacts are stored under
+	BatchSize    int           // Artifacts read per query
+	Interval     time.Duration // Time between passes of the background worker
+	DeleteSource bool          // Remove the old copy once the artifact points at the new one
+}
+
+// DefaultMigrationConfig keeps source copies and runs hourly
+func DefaultMigrationConfig() MigrationConfig {
+	return MigrationConfig{
+		KeyPrefix: "ai-summaries/",
+		BatchSize: 100,
+		Interval:  time.Hour,
+	}
+}
+
+// MigrationResult counts what one pass did
+type MigrationResult struct {
+	Scanned  int `json:"scanned"`
+	Migrated int `json:"migrated"`
+	Failed   int `json:"failed"`
+}
+
+// Migrator copies artifact bodies from the source stores to the target and
+// repoints each summary at its new URI. A summary is only repointed while it
+// still has the URI that was copied, so one rewritten mid-copy keeps its new
+// body. Artifacts that fail are retried on the next pass.
+type Migrator struct {
+	repo    MigrationRepository
+	sources []ArtifactStore
+	target  ArtifactStore
+	config  MigrationConfig
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+}
+
+// NewMigrator creates a migrator moving artifacts from sources to target
+func NewMigrator(repo MigrationRepository, sources []ArtifactStore, target ArtifactStore, config MigrationConfig, logger *zap.Logger) (*Migrator, error) {
+	if target == nil {
+		return nil, errors.New("a target artifact store is required")
+	}
+	for _, source := range sources {
+		if source.Scheme() == target.Scheme() {
+			return nil, fmt.Errorf("cannot migrate %s artifacts to themselves", source.Scheme())
+		}
+	}
+	defaults := DefaultMigrationConfig()
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Migrator{
+		repo:    repo,
+		sources: sources,
+		target:  target,
+		config:  config,
+		logger:  logger,
+	}, nil
+}
+
+// SetTracker reports passes to the worker registry, which can pause them
+func (m *Migrator) SetTracker(tracker *workerstatus.Tracker) {
+	m.tracker = tracker
+}
+
+// Interval returns the time between background passes
+func (m *Migrator) Interval() time.Duration {
+	return m.config.Interval
+}
+
+// Start runs a pass on every tick until ctx is cancelled
+func (m *Migrator) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := m.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := m.Run(ctx)
+				finish(err)
+				if err != nil {
+					m.logger.Error("Artifact migration failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Run moves every artifact currently in the source stores. It stops early
+// only when ctx is cancelled or the artifacts cannot be listed.
+func (m *Migrator) Run(ctx context.Context) (*MigrationResult, error) {
+	result := &MigrationResult{}
+	for _, source := range m.sources {
+		after := uuid.Nil
+		for {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			summaries, err := m.repo.ListByArtifactScheme(ctx, source.Scheme(), after, m.config.BatchSize)
+			if err != nil {
+				return result, err
+			}
+			for _, summary := range summaries {
+				result.Scanned++
+				if err := m.migrate(ctx, source, summary); err != nil {
+					result.Failed++
+					m.logger.Warn("Failed to migrate artifact",
+						zap.String("artifact_id", summary.ID.String()),
+						zap.String("uri", summary.ArtifactURI),
+						zap.Error(err))
+					continue
+				}
+				result.Migrated++
+			}
+			if len(summaries) < m.config.BatchSize {
+				break
+			}
+			after = summaries[len(summaries)-1].ID
+		}
+	}
+
+	if result.Scanned > 0 {
+		m.logger.Info("Artifact migration pass finished",
+			zap.String("target", m.target.Scheme()),
+			zap.Int("migrated", result.Migrated),
+			zap.Int("failed", result.Failed))
+	}
+	return result, nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, source ArtifactStore, summary *domainrepos.AISummary) error {
+	body, err := source.Retrieve(ctx, summary.ArtifactURI)
+	if err != nil {
+		return fmt.Errorf("failed to read from %s: %w", source.Scheme(), err)
+	}
+	key := path.Join(m.config.KeyPrefix, summary.UserID.String(), summary.ID.String()+".md")
+	uri, err := m.target.Put(ctx, key, "text/markdown", body)
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", m.target.Scheme(), err)
+	}
+
+	replaced, err := m.repo.ReplaceArtifactURI(ctx, summary.ID, summary.ArtifactURI, uri)
+	if err != nil || !replaced {
+		// Leave nothing behind that no summary points at
+		if deleteErr := m.target.Delete(ctx, uri); deleteErr != nil {
+			m.logger.Warn("Failed to remove unused artifact copy", zap.String("uri", uri), zap.Error(deleteErr))
+		}
+		return err
+	}
+
+	if m.config.DeleteSource {
+		if err := source.Delete(ctx, summary.ArtifactURI); err != nil {
+			m.logger.Warn("Failed to delete migrated artifact from source",
+				zap.String("uri", summary.ArtifactURI), zap.Error(err))
+		}
+	}
+	return nil
+}
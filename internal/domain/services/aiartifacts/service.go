@@ -0,0 +1,336 @@
+package aiartifacts
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainrepos "github.com/stack-service/stack_service/internal/domain/repositories"
+)
+
+var (
+	ErrArtifactNotFound = errors.New("artifact not found")
+	ErrInvalidLink      = errors.New("download link is invalid")
+	ErrLinkExpired      = errors.New("download link has expired")
+)
+
+const (
+	// TypeWeeklySummary is the weekly AI-CFO summary
+	TypeWeeklySummary = "weekly_summary"
+
+	// StorageZeroG marks artifacts whose body lives in 0G storage
+	StorageZeroG = "0g"
+	// StorageS3 marks artifacts whose body lives in an S3 bucket
+	StorageS3 = "s3"
+	// StorageLocal marks artifacts whose body lives on the server's disk
+	StorageLocal = "local"
+	// StorageDatabase marks artifacts only kept in the ai_summaries table
+	StorageDatabase = "database"
+)
+
+// Repository reads the AI summaries stored about a user
+type Repository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domainrepos.AISummary, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domainrepos.AISummary, error)
+}
+
+// ErrStoreReadOnly is returned by stores that cannot take new artifacts
+var ErrStoreReadOnly = errors.New("artifact store is read-only")
+
+// ArtifactStore keeps artifact bodies in one storage backend. Each backend
+// issues URIs with its own scheme, such as 0g://, s3:// or local://, which
+// is how a stored URI is routed back to the store holding the body.
+type ArtifactStore interface {
+	// Scheme is the URI scheme of the store's artifacts and the storage
+	// name reported for them
+	Scheme() string
+	// Put stores body under key and returns the artifact's URI
+	Put(ctx context.Context, key, contentType string, body []byte) (string, error)
+	Retrieve(ctx context.Context, uri string) ([]byte, error)
+	Delete(ctx context.Context, uri string) error
+}
+
+// Config controls download links and exports
+type Config struct {
+	SigningKey  []byte        // HMAC key for download links
+	LinkTTL     time.Duration // How long a download link stays valid
+	DownloadURL string        // Path download tokens are appended to
+	PageSize    int           // Summaries read per query while exporting
+}
+
+// DefaultConfig returns the default artifact configuration
+func DefaultConfig() Config {
+	return Config{
+		LinkTTL:     15 * time.Minute,
+		DownloadURL: "/api/v1/aicfo/artifacts/download",
+		PageSize:    100,
+	}
+}
+
+// Artifact describes a stored AI artifact without its body
+type Artifact struct {
+	ID          uuid.UUID  `json:"id"`
+	Type        string     `json:"type"`
+	WeekStart   time.Time  `json:"week_start"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Storage     string     `json:"storage"`
+	URI         string     `json:"uri,omitempty"`
+	ContentType string     `json:"content_type"`
+	SizeBytes   int        `json:"size_bytes"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// Content is an artifact with its body
+type Content struct {
+	Artifact
+	Body []byte
+}
+
+// ExportedArtifact is an artifact's metadata and body in a data export
+type ExportedArtifact struct {
+	Artifact
+	Content        string `json:"content"`
+	RetrievalError string `json:"retrieval_error,omitempty"`
+}
+
+// Export is the AI section of a user's data export
+type Export struct {
+	UserID      uuid.UUID          `json:"user_id"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Artifacts   []ExportedArtifact `json:"artifacts"`
+}
+
+// Service lists, serves and exports the AI-generated summaries and analyses
+// stored about a user. Bodies are read from the store matching the
+// artifact's URI when one is configured, falling back to the copy kept in
+// the database.
+type Service struct {
+	repo   Repository
+	stores map[string]ArtifactStore
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new AI artifact service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.LinkTTL <= 0 {
+		config.LinkTTL = defaults.LinkTTL
+	}
+	if config.DownloadURL == "" {
+		config.DownloadURL = defaults.DownloadURL
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = defaults.PageSize
+	}
+	return &Service{
+		repo:   repo,
+		stores: map[string]ArtifactStore{},
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetStore enables retrieval of the artifacts whose URI has the store's
+// scheme. Several stores can be set while artifacts move between backends.
+func (s *Service) SetStore(store ArtifactStore) {
+	s.stores[store.Scheme()] = store
+}
+
+// List returns a page of the user's artifacts, newest first, each with a
+// signed download link
+func (s *Service) List(ctx context.Context, userID uuid.UUID, limit, offset int) ([]Artifact, error) {
+	if limit <= 0 || limit > s.config.PageSize {
+		limit = s.config.PageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	summaries, err := s.repo.ListByUserID(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AI artifacts: %w", err)
+	}
+
+	expiresAt := s.now().Add(s.config.LinkTTL).UTC()
+	artifacts := make([]Artifact, 0, len(summaries))
+	for _, summary := range summaries {
+		artifact := describe(summary)
+		artifact.DownloadURL = s.config.DownloadURL + "?token=" + s.sign(userID, summary.ID, expiresAt)
+		artifact.ExpiresAt = &expiresAt
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+// Open verifies a download token and returns the artifact it grants
+func (s *Service) Open(ctx context.Context, token string) (*Content, error) {
+	userID, artifactID, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.get(ctx, userID, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.body(ctx, summary)
+	if err != nil {
+		return nil, err
+	}
+	return &Content{Artifact: describe(summary), Body: body}, nil
+}
+
+// Export collects every artifact stored about the user with its body. An
+// artifact whose body cannot be retrieved is still listed, with the error.
+func (s *Service) Export(ctx context.Context, userID uuid.UUID) (*Export, error) {
+	export := &Export{
+		UserID:      userID,
+		GeneratedAt: s.now().UTC(),
+		Artifacts:   []ExportedArtifact{},
+	}
+	for offset := 0; ; offset += s.config.PageSize {
+		summaries, err := s.repo.ListByUserID(ctx, userID, s.config.PageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list AI artifacts: %w", err)
+		}
+		for _, summary := range summaries {
+			exported := ExportedArtifact{Artifact: describe(summary)}
+			body, err := s.body(ctx, summary)
+			if err != nil {
+				exported.RetrievalError = err.Error()
+			} else {
+				exported.Content = string(body)
+			}
+			export.Artifacts = append(export.Artifacts, exported)
+		}
+		if len(summaries) < s.config.PageSize {
+			break
+		}
+	}
+
+	s.logger.Info("Exported AI artifacts",
+		zap.String("user_id", userID.String()),
+		zap.Int("artifacts", len(export.Artifacts)))
+	return export, nil
+}
+
+func (s *Service) get(ctx context.Context, userID, artifactID uuid.UUID) (*domainrepos.AISummary, error) {
+	summary, err := s.repo.GetByID(ctx, artifactID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AI artifact: %w", err)
+	}
+	if summary.UserID != userID {
+		return nil, ErrArtifactNotFound
+	}
+	return summary, nil
+}
+
+// body reads the artifact from its store when possible and from the
+// database copy otherwise
+func (s *Service) body(ctx context.Context, summary *domainrepos.AISummary) ([]byte, error) {
+	if summary.ArtifactURI == "" {
+		return []byte(summary.SummaryMD), nil
+	}
+	scheme := StorageOf(summary.ArtifactURI)
+	store, ok := s.stores[scheme]
+	if !ok {
+		return []byte(summary.SummaryMD), nil
+	}
+	body, err := store.Retrieve(ctx, summary.ArtifactURI)
+	if err == nil {
+		return body, nil
+	}
+	if summary.SummaryMD != "" {
+		s.logger.Warn("Artifact retrieval failed, serving stored copy",
+			zap.String("artifact_id", summary.ID.String()),
+			zap.String("uri", summary.ArtifactURI),
+			zap.Error(err))
+		return []byte(summary.SummaryMD), nil
+	}
+	return nil, fmt.Errorf("failed to retrieve artifact from %s: %w", strings.ToUpper(scheme), err)
+}
+
+// StorageOf returns the storage holding the artifact at uri. URIs written
+// before stores were pluggable have no scheme and are in 0G.
+func StorageOf(uri string) string {
+	if uri == "" {
+		return StorageDatabase
+	}
+	scheme, _, ok := strings.Cut(uri, "://")
+	if !ok || scheme == "" {
+		return StorageZeroG
+	}
+	return scheme
+}
+
+// sign returns a token of the form payload.signature, where payload encodes
+// the user, artifact and expiry
+func (s *Service) sign(userID, artifactID uuid.UUID, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(
+		userID.String() + ":" + artifactID.String() + ":" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + s.mac(payload)
+}
+
+func (s *Service) verify(token string) (uuid.UUID, uuid.UUID, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.mac(payload))) {
+		return uuid.Nil, uuid.Nil, ErrInvalidLink
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidLink
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return uuid.Nil, uuid.Nil, ErrInvalidLink
+	}
+	userID, userErr := uuid.Parse(parts[0])
+	artifactID, artifactErr := uuid.Parse(parts[1])
+	expires, expiresErr := strconv.ParseInt(parts[2], 10, 64)
+	if userErr != nil || artifactErr != nil || expiresErr != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidLink
+	}
+	if !s.now().Before(time.Unix(expires, 0)) {
+		return uuid.Nil, uuid.Nil, ErrLinkExpired
+	}
+	return userID, artifactID, nil
+}
+
+func (s *Service) mac(payload string) string {
+	mac := hmac.New(sha256.New, s.config.SigningKey)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func describe(summary *domainrepos.AISummary) Artifact {
+	artifact := Artifact{
+		ID:          summary.ID,
+		Type:        TypeWeeklySummary,
+		WeekStart:   summary.WeekStart,
+		CreatedAt:   summary.CreatedAt,
+		Storage:     StorageDatabase,
+		ContentType: "text/markdown",
+		SizeBytes:   len(summary.SummaryMD),
+	}
+	if summary.ArtifactURI != "" {
+		artifact.Storage = StorageOf(summary.ArtifactURI)
+		artifact.URI = summary.ArtifactURI
+	}
+	return artifact
+}
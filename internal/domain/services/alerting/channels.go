@@ -0,0 +1,168 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 enqueue endpoint
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxErrorBody caps how much of a rejected response is kept as the delivery error
+const maxErrorBody = 512
+
+// PagerDutySender triggers PagerDuty incidents. The alert's dedup key is
+// passed through, so repeats update the open incident instead of paging again.
+type PagerDutySender struct {
+	eventsURL  string
+	routingKey string
+	client     *http.Client
+}
+
+// NewPagerDutySender creates a sender for a PagerDuty service integration key
+func NewPagerDutySender(eventsURL, routingKey string, timeout time.Duration) *PagerDutySender {
+	if eventsURL == "" {
+		eventsURL = DefaultPagerDutyEventsURL
+	}
+	return &PagerDutySender{eventsURL: eventsURL, routingKey: routingKey, client: &http.Client{Timeout: timeout}}
+}
+
+// Send triggers an incident and returns the dedup key PagerDuty confirmed
+func (p *PagerDutySender) Send(ctx context.Context, alert *entities.Alert) (*Receipt, error) {
+	event := map[string]interface{}{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    alert.DedupKey,
+		"payload": map[string]interface{}{
+			"summary":        alert.Summary,
+			"source":         alert.Source,
+			"severity":       string(alert.Severity),
+			"timestamp":      alert.OccurredAt.UTC().Format(time.RFC3339),
+			"custom_details": alert.Details,
+		},
+	}
+	status, body, err := post(ctx, p.client, p.eventsURL, event)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusAccepted {
+		return nil, &SendError{StatusCode: status, Body: truncate(body)}
+	}
+
+	var accepted struct {
+		Status   string `json:"status"`
+		DedupKey string `json:"dedup_key"`
+	}
+	if err := json.Unmarshal(body, &accepted); err != nil || accepted.Status != "success" {
+		return nil, &SendError{StatusCode: status, Body: truncate(body)}
+	}
+	return &Receipt{StatusCode: status, Ref: accepted.DedupKey}, nil
+}
+
+// SlackSender posts alerts to a Slack incoming webhook
+type SlackSender struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackSender creates a sender for a Slack incoming webhook URL
+func NewSlackSender(webhookURL string, timeout time.Duration) *SlackSender {
+	return &SlackSender{webhookURL: webhookURL, client: &http.Client{Timeout: timeout}}
+}
+
+// Send posts the alert. Slack confirms with a 200 and a body of "ok".
+func (s *SlackSender) Send(ctx context.Context, alert *entities.Alert) (*Receipt, error) {
+	status, body, err := post(ctx, s.client, s.webhookURL, map[string]string{"text": slackText(alert)})
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || strings.TrimSpace(string(body)) != "ok" {
+		return nil, &SendError{StatusCode: status, Body: truncate(body)}
+	}
+	return &Receipt{StatusCode: status}, nil
+}
+
+func slackText(alert *entities.Alert) string {
+	icon := ":information_source:"
+	switch alert.Severity {
+	case entities.AlertSeverityCritical:
+		icon = ":rotating_light:"
+	case entities.AlertSeverityWarning:
+		icon = ":warning:"
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s *[%s] %s*: %s", icon, strings.ToUpper(string(alert.Severity)), alert.Source, alert.Summary)
+	keys := make([]string, 0, len(alert.Details))
+	for key := range alert.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&text, "\n• %s: %s", key, alert.Details[key])
+	}
+	return text.String()
+}
+
+// WebhookSender posts the alert as JSON to a generic endpoint. Any 2xx
+// response confirms it.
+type WebhookSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSender creates a sender for a plain webhook URL
+func NewWebhookSender(url string, timeout time.Duration) *WebhookSender {
+	return &WebhookSender{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Send posts the alert
+func (w *WebhookSender) Send(ctx context.Context, alert *entities.Alert) (*Receipt, error) {
+	status, body, err := post(ctx, w.client, w.url, alert)
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, &SendError{StatusCode: status, Body: truncate(body)}
+	}
+	return &Receipt{StatusCode: status}, nil
+}
+
+func post(ctx context.Context, client *http.Client, url string, payload interface{}) (int, []byte, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(encoded))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read alert response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+func truncate(body []byte) string {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return string(body)
+}
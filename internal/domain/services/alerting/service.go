@@ -0,0 +1,360 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// ErrUnknownSeverity is returned for an alert whose severity has no route
+var ErrUnknownSeverity = errors.New("unknown alert severity")
+
+// Sender delivers an alert to one channel
+type Sender interface {
+	Send(ctx context.Context, alert *entities.Alert) (*Receipt, error)
+}
+
+// Receipt is a channel's confirmation that it accepted an alert. Ref is
+// whatever the channel returned to identify it, and may be empty.
+type Receipt struct {
+	StatusCode int
+	Ref        string
+}
+
+// SendError is returned by a Sender when the channel answered but refused the
+// alert. Rejections other than rate limiting and server errors are not retried.
+type SendError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("alert channel returned status %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *SendError) retryable() bool {
+	return e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// Repository records deliveries
+type Repository interface {
+	Create(ctx context.Context, delivery *entities.AlertDelivery) error
+	// DeliveredSince reports whether an alert with the key was confirmed by
+	// the channel at or after since
+	DeliveredSince(ctx context.Context, dedupKey string, channel entities.AlertChannel, since time.Time) (bool, error)
+	List(ctx context.Context, status entities.AlertDeliveryStatus, channel entities.AlertChannel, limit, offset int) ([]*entities.AlertDelivery, error)
+}
+
+// Route lists the channels an alert of one severity goes to. Outside business
+// hours AfterHours is used instead; leaving it empty holds those alerts back.
+type Route struct {
+	BusinessHours []entities.AlertChannel
+	AfterHours    []entities.AlertChannel
+}
+
+// Schedule is when the support team is at their desks
+type Schedule struct {
+	Location  *time.Location
+	Days      []time.Weekday
+	StartHour int // First hour of the working day, local time
+	EndHour   int // Hour the working day ends, exclusive
+}
+
+// InBusinessHours reports whether t falls in the working day
+func (s Schedule) InBusinessHours(t time.Time) bool {
+	location := s.Location
+	if location == nil {
+		location = time.UTC
+	}
+	local := t.In(location)
+	for _, day := range s.Days {
+		if local.Weekday() == day {
+			return local.Hour() >= s.StartHour && local.Hour() < s.EndHour
+		}
+	}
+	return false
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseSchedule builds a schedule from an IANA time zone and three-letter day
+// names such as "mon"
+func ParseSchedule(timezone string, days []string, startHour, endHour int) (Schedule, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid business hours time zone %q: %w", timezone, err)
+	}
+	if startHour < 0 || endHour > 24 || startHour >= endHour {
+		return Schedule{}, fmt.Errorf("business hours must start before they end, got %d-%d", startHour, endHour)
+	}
+	schedule := Schedule{Location: location, StartHour: startHour, EndHour: endHour}
+	for _, name := range days {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return Schedule{}, fmt.Errorf("unknown business day %q", name)
+		}
+		schedule.Days = append(schedule.Days, day)
+	}
+	return schedule, nil
+}
+
+// Config controls routing and delivery
+type Config struct {
+	Routes       map[entities.AlertSeverity]Route
+	Schedule     Schedule
+	DedupWindow  time.Duration // Repeats of a key within this window are not delivered again; 0 disables
+	MaxAttempts  int
+	RetryBackoff time.Duration // Wait before the second attempt, doubling after each failure
+}
+
+// DefaultConfig pages for critical alerts at any hour and posts everything
+// else to Slack during a 09:00-18:00 UTC working week
+func DefaultConfig() Config {
+	return Config{
+		Routes: map[entities.AlertSeverity]Route{
+			entities.AlertSeverityCritical: {
+				BusinessHours: []entities.AlertChannel{entities.AlertChannelPagerDuty, entities.AlertChannelSlack},
+				AfterHours:    []entities.AlertChannel{entities.AlertChannelPagerDuty},
+			},
+			entities.AlertSeverityWarning: {
+				BusinessHours: []entities.AlertChannel{entities.AlertChannelSlack},
+				AfterHours:    []entities.AlertChannel{entities.AlertChannelSlack},
+			},
+			entities.AlertSeverityInfo: {
+				BusinessHours: []entities.AlertChannel{entities.AlertChannelSlack},
+			},
+		},
+		Schedule: Schedule{
+			Location:  time.UTC,
+			Days:      []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			StartHour: 9,
+			EndHour:   18,
+		},
+		DedupWindow:  4 * time.Hour,
+		MaxAttempts:  3,
+		RetryBackoff: 2 * time.Second,
+	}
+}
+
+// Service routes alerts to on-call channels by severity and time of day and
+// records whether each channel confirmed them
+type Service struct {
+	repo     Repository
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+	mu       sync.RWMutex
+	channels map[entities.AlertChannel]Sender
+}
+
+// NewService creates an alert routing service. No channel is registered
+// until RegisterChannel is called.
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.Routes == nil {
+		config.Routes = defaults.Routes
+	}
+	if len(config.Schedule.Days) == 0 {
+		config.Schedule = defaults.Schedule
+	}
+	if config.DedupWindow < 0 {
+		config.DedupWindow = 0
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+	return &Service{
+		repo:     repo,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+		channels: map[entities.AlertChannel]Sender{},
+	}
+}
+
+// RegisterChannel makes a channel available to routes
+func (s *Service) RegisterChannel(channel entities.AlertChannel, sender Sender) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel] = sender
+}
+
+// SetClock replaces the clock used for business hours and deduplication
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Notify delivers an alert to the channels its severity is routed to at the
+// current time. When none of them is registered it falls back to the plain
+// webhook channel. Each attempted channel yields a delivery record; channels
+// that already confirmed the same key within the dedup window are skipped.
+func (s *Service) Notify(ctx context.Context, alert *entities.Alert) ([]*entities.AlertDelivery, error) {
+	route, ok := s.config.Routes[alert.Severity]
+	if !ok {
+		return nil, ErrUnknownSeverity
+	}
+	now := s.now()
+	if alert.OccurredAt.IsZero() {
+		alert.OccurredAt = now
+	}
+	if alert.DedupKey == "" {
+		alert.DedupKey = alert.Source + ":" + alert.Summary
+	}
+
+	business := s.config.Schedule.InBusinessHours(now)
+	channels := route.AfterHours
+	if business {
+		channels = route.BusinessHours
+	}
+	if len(channels) == 0 {
+		s.logger.Info("Alert not routed to any channel at this hour",
+			zap.String("source", alert.Source),
+			zap.String("severity", string(alert.Severity)),
+			zap.String("summary", alert.Summary))
+		return nil, nil
+	}
+
+	var deliveries []*entities.AlertDelivery
+	for _, t := range s.resolve(channels) {
+		if s.config.DedupWindow > 0 {
+			seen, err := s.repo.DeliveredSince(ctx, alert.DedupKey, t.channel, now.Add(-s.config.DedupWindow))
+			if err != nil {
+				return deliveries, err
+			}
+			if seen {
+				metrics.AlertDeliveriesTotal.WithLabelValues(string(t.channel), string(alert.Severity), "suppressed").Inc()
+				continue
+			}
+		}
+
+		delivery := s.deliver(ctx, t.channel, t.sender, alert)
+		delivery.BusinessHours = business
+		if err := s.repo.Create(ctx, delivery); err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// ListDeliveries returns deliveries, newest first, optionally filtered by
+// status and channel
+func (s *Service) ListDeliveries(ctx context.Context, status entities.AlertDeliveryStatus, channel entities.AlertChannel, limit, offset int) ([]*entities.AlertDelivery, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, channel, limit, offset)
+}
+
+// target is a registered channel an alert is sent to
+type target struct {
+	channel entities.AlertChannel
+	sender  Sender
+}
+
+// resolve returns the registered senders for channels, in route order, or
+// the webhook fallback when none of them is registered
+func (s *Service) resolve(channels []entities.AlertChannel) []target {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var targets []target
+	for _, channel := range channels {
+		if sender, ok := s.channels[channel]; ok {
+			targets = append(targets, target{channel: channel, sender: sender})
+		}
+	}
+	if len(targets) == 0 {
+		if sender, ok := s.channels[entities.AlertChannelWebhook]; ok {
+			targets = append(targets, target{channel: entities.AlertChannelWebhook, sender: sender})
+		}
+	}
+	return targets
+}
+
+// deliver sends with retries and returns the outcome
+func (s *Service) deliver(ctx context.Context, channel entities.AlertChannel, sender Sender, alert *entities.Alert) *entities.AlertDelivery {
+	delivery := &entities.AlertDelivery{
+		ID:        uuid.New(),
+		Source:    alert.Source,
+		Severity:  alert.Severity,
+		DedupKey:  alert.DedupKey,
+		Summary:   alert.Summary,
+		Channel:   channel,
+		Status:    entities.AlertDeliveryFailed,
+		CreatedAt: s.now(),
+	}
+
+	backoff := s.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		delivery.Attempts = attempt
+		receipt, err := sender.Send(ctx, alert)
+		if err == nil {
+			delivery.Status = entities.AlertDeliveryDelivered
+			delivery.ResponseStatus = &receipt.StatusCode
+			if receipt.Ref != "" {
+				delivery.ProviderRef = &receipt.Ref
+			}
+			deliveredAt := s.now()
+			delivery.DeliveredAt = &deliveredAt
+			delivery.LastError = nil
+			break
+		}
+
+		message := err.Error()
+		delivery.LastError = &message
+		var sendErr *SendError
+		if errors.As(err, &sendErr) {
+			delivery.ResponseStatus = &sendErr.StatusCode
+			if !sendErr.retryable() {
+				break
+			}
+		}
+		if attempt == s.config.MaxAttempts || !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	metrics.AlertDeliveriesTotal.WithLabelValues(string(channel), string(alert.Severity), string(delivery.Status)).Inc()
+	if delivery.Status == entities.AlertDeliveryFailed {
+		s.logger.Error("Alert delivery failed",
+			zap.String("channel", string(channel)),
+			zap.String("source", alert.Source),
+			zap.String("severity", string(alert.Severity)),
+			zap.String("summary", alert.Summary),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Stringp("error", delivery.LastError))
+	}
+	return delivery
+}
+
+// sleep waits for d and reports false if ctx ended first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
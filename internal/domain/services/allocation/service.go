@@ -44,6 +44,7 @@ type Service struct {
 	allocationRepo AllocationRepository
 	ledgerService  *ledger.Service
 	logger         *logger.Logger
+	now            func() time.Time
 }
 
 // NewService creates a new allocation service
@@ -56,9 +57,15 @@ func NewService(
 		allocationRepo: allocationRepo,
 		ledgerService:  ledgerService,
 		logger:         logger,
+		now:            time.Now,
 	}
 }
 
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
 // ============================================================================
 // Mode Management
 // ============================================================================
@@ -110,7 +117,7 @@ func (s *Service) EnableMode(ctx context.Context, userID uuid.UUID, ratios entit
 		existingMode.Active = true
 		existingMode.RatioSpending = ratios.SpendingRatio
 		existingMode.RatioStash = ratios.StashRatio
-		now := time.Now()
+		now := s.now()
 		existingMode.ResumedAt = &now
 		existingMode.PausedAt = nil
 
@@ -124,7 +131,7 @@ func (s *Service) EnableMode(ctx context.Context, userID uuid.UUID, ratios entit
 	}
 
 	// Create new mode
-	now := time.Now()
+	now := s.now()
 	mode := &entities.SmartAllocationMode{
 		UserID:        userID,
 		Active:        true,
@@ -312,7 +319,7 @@ func (s *Service) ProcessIncomingFunds(ctx context.Context, req *entities.Incomi
 		EventType:      req.EventType,
 		SourceTxID:     req.SourceTxID,
 		Metadata:       req.Metadata,
-		CreatedAt:      time.Now(),
+		CreatedAt:      s.now(),
 	}
 
 	if err := s.allocationRepo.CreateEvent(ctx, event); err != nil {
@@ -407,7 +414,7 @@ func (s *Service) GetBalances(ctx context.Context, userID uuid.UUID) (*entities.
 			SpendingRemaining: decimal.Zero,
 			TotalBalance:      decimal.Zero,
 			ModeActive:        false,
-			UpdatedAt:         time.Now(),
+			UpdatedAt:         s.now(),
 		}, nil
 	}
 
@@ -435,7 +442,7 @@ func (s *Service) GetBalances(ctx context.Context, userID uuid.UUID) (*entities.
 		StashBalance:    stashBalance,
 		SpendingUsed:    spendingUsed,
 		ModeActive:      mode.Active,
-		UpdatedAt:       time.Now(),
+		UpdatedAt:       s.now(),
 	}
 
 	// Calculate derived values
@@ -10,12 +10,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+// API key environments. Live keys reach real accounts; sandbox keys are
+// issued to partner developers and only work against the sandbox.
+const (
+	EnvironmentLive    = "live"
+	EnvironmentSandbox = "sandbox"
+)
+
 type Service struct {
 	db     *sql.DB
 	logger *zap.Logger
+	// encryptionKey encrypts request signing secrets at rest
+	encryptionKey string
 }
 
 type APIKey struct {
@@ -28,6 +38,9 @@ type APIKey struct {
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
+	// TenantID is the developer tenant that issued the key, if any
+	TenantID    *uuid.UUID `json:"tenant_id,omitempty"`
+	Environment string     `json:"environment"`
 }
 
 type CreateAPIKeyRequest struct {
@@ -35,6 +48,10 @@ type CreateAPIKeyRequest struct {
 	UserID    *uuid.UUID `json:"user_id,omitempty"`
 	Scopes    []string   `json:"scopes"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Set by the developer program, never from the request body. An empty
+	// environment issues a live key.
+	TenantID    *uuid.UUID `json:"-"`
+	Environment string     `json:"-"`
 }
 
 type CreateAPIKeyResponse struct {
@@ -51,32 +68,39 @@ func NewService(db *sql.DB, logger *zap.Logger) *Service {
 
 // CreateAPIKey creates a new API key
 func (s *Service) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	environment := req.Environment
+	if environment == "" {
+		environment = EnvironmentLive
+	}
+
 	// Generate API key
-	key, keyPrefix, keyHash, err := s.generateAPIKey()
+	key, keyPrefix, keyHash, err := s.generateAPIKey(environment)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate API key: %w", err)
 	}
 
 	apiKey := &APIKey{
-		ID:        uuid.New(),
-		Name:      req.Name,
-		KeyPrefix: keyPrefix,
-		UserID:    req.UserID,
-		Scopes:    req.Scopes,
-		IsActive:  true,
-		ExpiresAt: req.ExpiresAt,
-		CreatedAt: time.Now(),
+		ID:          uuid.New(),
+		Name:        req.Name,
+		KeyPrefix:   keyPrefix,
+		UserID:      req.UserID,
+		Scopes:      req.Scopes,
+		IsActive:    true,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   time.Now(),
+		TenantID:    req.TenantID,
+		Environment: environment,
 	}
 
 	// Store in database
 	query := `
-		INSERT INTO api_keys (id, name, key_hash, key_prefix, user_id, scopes, is_active, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		INSERT INTO api_keys (id, name, key_hash, key_prefix, user_id, scopes, is_active, expires_at, created_at, tenant_id, environment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	_, err = s.db.ExecContext(ctx, query,
 		apiKey.ID, apiKey.Name, keyHash, apiKey.KeyPrefix,
-		apiKey.UserID, apiKey.Scopes, apiKey.IsActive,
-		apiKey.ExpiresAt, apiKey.CreatedAt)
+		apiKey.UserID, pq.Array(apiKey.Scopes), apiKey.IsActive,
+		apiKey.ExpiresAt, apiKey.CreatedAt, apiKey.TenantID, apiKey.Environment)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to store API key: %w", err)
@@ -98,16 +122,11 @@ func (s *Service) ValidateAPIKey(ctx context.Context, key string) (*APIKey, erro
 	keyHash := s.hashKey(key)
 
 	query := `
-		SELECT id, name, key_prefix, user_id, scopes, is_active, last_used_at, expires_at, created_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys 
 		WHERE key_hash = $1 AND is_active = true AND (expires_at IS NULL OR expires_at > NOW())`
 
-	apiKey := &APIKey{}
-	err := s.db.QueryRowContext(ctx, query, keyHash).Scan(
-		&apiKey.ID, &apiKey.Name, &apiKey.KeyPrefix, &apiKey.UserID,
-		&apiKey.Scopes, &apiKey.IsActive, &apiKey.LastUsedAt,
-		&apiKey.ExpiresAt, &apiKey.CreatedAt)
-
+	apiKey, err := scanAPIKey(s.db.QueryRowContext(ctx, query, keyHash))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("invalid or expired API key")
@@ -128,18 +147,32 @@ func (s *Service) ListAPIKeys(ctx context.Context, userID *uuid.UUID) ([]*APIKey
 
 	if userID != nil {
 		query = `
-			SELECT id, name, key_prefix, user_id, scopes, is_active, last_used_at, expires_at, created_at
+			SELECT ` + apiKeyColumns + `
 			FROM api_keys 
 			WHERE user_id = $1
 			ORDER BY created_at DESC`
 		args = []interface{}{*userID}
 	} else {
 		query = `
-			SELECT id, name, key_prefix, user_id, scopes, is_active, last_used_at, expires_at, created_at
+			SELECT ` + apiKeyColumns + `
 			FROM api_keys 
 			ORDER BY created_at DESC`
 	}
 
+	return s.queryAPIKeys(ctx, query, args...)
+}
+
+// ListTenantAPIKeys returns the keys a developer tenant has issued, newest
+// first
+func (s *Service) ListTenantAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*APIKey, error) {
+	return s.queryAPIKeys(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC`, tenantID)
+}
+
+func (s *Service) queryAPIKeys(ctx context.Context, query string, args ...interface{}) ([]*APIKey, error) {
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list API keys: %w", err)
@@ -148,18 +181,32 @@ func (s *Service) ListAPIKeys(ctx context.Context, userID *uuid.UUID) ([]*APIKey
 
 	var apiKeys []*APIKey
 	for rows.Next() {
-		apiKey := &APIKey{}
-		err := rows.Scan(
-			&apiKey.ID, &apiKey.Name, &apiKey.KeyPrefix, &apiKey.UserID,
-			&apiKey.Scopes, &apiKey.IsActive, &apiKey.LastUsedAt,
-			&apiKey.ExpiresAt, &apiKey.CreatedAt)
+		apiKey, err := scanAPIKey(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key: %w", err)
 		}
 		apiKeys = append(apiKeys, apiKey)
 	}
 
-	return apiKeys, nil
+	return apiKeys, rows.Err()
+}
+
+const apiKeyColumns = `id, name, key_prefix, user_id, scopes, is_active, last_used_at, expires_at, created_at, tenant_id, environment`
+
+type apiKeyScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row apiKeyScanner) (*APIKey, error) {
+	apiKey := &APIKey{}
+	err := row.Scan(
+		&apiKey.ID, &apiKey.Name, &apiKey.KeyPrefix, &apiKey.UserID,
+		pq.Array(&apiKey.Scopes), &apiKey.IsActive, &apiKey.LastUsedAt,
+		&apiKey.ExpiresAt, &apiKey.CreatedAt, &apiKey.TenantID, &apiKey.Environment)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
 }
 
 // RevokeAPIKey revokes an API key
@@ -196,10 +243,10 @@ func (s *Service) UpdateAPIKey(ctx context.Context, keyID uuid.UUID, name string
 
 	if userID != nil {
 		query = "UPDATE api_keys SET name = $1, scopes = $2, updated_at = NOW() WHERE id = $3 AND user_id = $4"
-		args = []interface{}{name, scopes, keyID, *userID}
+		args = []interface{}{name, pq.Array(scopes), keyID, *userID}
 	} else {
 		query = "UPDATE api_keys SET name = $1, scopes = $2, updated_at = NOW() WHERE id = $3"
-		args = []interface{}{name, scopes, keyID}
+		args = []interface{}{name, pq.Array(scopes), keyID}
 	}
 
 	result, err := s.db.ExecContext(ctx, query, args...)
@@ -240,17 +287,20 @@ func (s *Service) HasScope(apiKey *APIKey, requiredScope string) bool {
 	return false
 }
 
-func (s *Service) generateAPIKey() (key, prefix, hash string, err error) {
+func (s *Service) generateAPIKey(environment string) (key, prefix, hash string, err error) {
 	// Generate 32 random bytes
 	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", "", "", err
 	}
 
-	// Create key with prefix
+	// Create key with prefix; sandbox keys are recognisable at a glance
 	keyData := hex.EncodeToString(bytes)
 	key = fmt.Sprintf("sk_%s", keyData)
-	prefix = key[:12] // First 12 characters for display
+	if environment == EnvironmentSandbox {
+		key = fmt.Sprintf("sk_test_%s", keyData)
+	}
+	prefix = key[:len(key)-len(keyData)+9] // Marker and first 9 characters for display
 	hash = s.hashKey(key)
 
 	return key, prefix, hash, nil
@@ -0,0 +1,244 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// Request signing errors
+var (
+	ErrSigningKeyNotFound    = errors.New("signing key not found")
+	ErrSigningUnavailable    = errors.New("request signing requires an encryption key")
+	ErrInvalidSigningRequest = errors.New("invalid signing key request")
+)
+
+// SigningKey is an HMAC key an internal service signs its requests with. It
+// is scoped to one tenant and environment, so a key issued for staging is
+// rejected by production.
+type SigningKey struct {
+	ID          uuid.UUID  `json:"id"`
+	KeyID       string     `json:"key_id"`
+	Name        string     `json:"name"`
+	Tenant      string     `json:"tenant"`
+	Environment string     `json:"environment"`
+	Scopes      []string   `json:"scopes"`
+	IsActive    bool       `json:"is_active"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateSigningKeyRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	Tenant      string     `json:"tenant" binding:"required,max=100"`
+	Environment string     `json:"environment" binding:"required,max=20"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateSigningKeyResponse carries the secret, which is only shown once
+type CreateSigningKeyResponse struct {
+	SigningKey *SigningKey `json:"signing_key"`
+	Secret     string      `json:"secret"`
+}
+
+// SetEncryptionKey sets the key signing secrets are encrypted with at rest.
+// Without one, signing keys cannot be created or verified.
+func (s *Service) SetEncryptionKey(key string) {
+	s.encryptionKey = key
+}
+
+// CreateSigningKey issues a signing key for a tenant's service in an
+// environment
+func (s *Service) CreateSigningKey(ctx context.Context, req *CreateSigningKeyRequest) (*CreateSigningKeyResponse, error) {
+	if s.encryptionKey == "" {
+		return nil, ErrSigningUnavailable
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Tenant = strings.TrimSpace(req.Tenant)
+	req.Environment = strings.ToLower(strings.TrimSpace(req.Environment))
+	if req.Name == "" || req.Tenant == "" || req.Environment == "" {
+		return nil, fmt.Errorf("%w: name, tenant and environment are required", ErrInvalidSigningRequest)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidSigningRequest)
+	}
+
+	keyID, err := randomToken("sig_", 12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key ID: %w", err)
+	}
+	secret, err := randomToken("sks_", 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+	encrypted, err := crypto.Encrypt(secret, s.encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing secret: %w", err)
+	}
+
+	key := &SigningKey{
+		ID:          uuid.New(),
+		KeyID:       keyID,
+		Name:        req.Name,
+		Tenant:      req.Tenant,
+		Environment: req.Environment,
+		Scopes:      req.Scopes,
+		IsActive:    true,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   time.Now(),
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO api_signing_keys (id, key_id, name, tenant, environment, secret_encrypted, scopes, is_active, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`,
+		key.ID, key.KeyID, key.Name, key.Tenant, key.Environment, encrypted,
+		pq.Array(key.Scopes), key.IsActive, key.ExpiresAt, key.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	s.logger.Info("Signing key created",
+		zap.String("key_id", key.KeyID),
+		zap.String("tenant", key.Tenant),
+		zap.String("environment", key.Environment))
+
+	return &CreateSigningKeyResponse{SigningKey: key, Secret: secret}, nil
+}
+
+const signingKeyColumns = `
+	id, key_id, name, tenant, environment, scopes, is_active, last_used_at, expires_at, created_at`
+
+// ListSigningKeys returns signing keys, optionally for one tenant
+func (s *Service) ListSigningKeys(ctx context.Context, tenant string) ([]*SigningKey, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+signingKeyColumns+`
+		FROM api_signing_keys
+		WHERE $1 = '' OR tenant = $1
+		ORDER BY created_at DESC`, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*SigningKey{}
+	for rows.Next() {
+		key, err := scanSigningKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeSigningKey deactivates a signing key; requests signed with it are
+// rejected from then on
+func (s *Service) RevokeSigningKey(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE api_signing_keys SET is_active = false, updated_at = NOW() WHERE id = $1 AND is_active = true", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke signing key: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrSigningKeyNotFound
+	}
+
+	s.logger.Info("Signing key revoked", zap.String("id", id.String()))
+	return nil
+}
+
+// ResolveSigningKey returns an active, unexpired signing key and its
+// decrypted secret for verifying a request signed with it
+func (s *Service) ResolveSigningKey(ctx context.Context, keyID string) (*SigningKey, string, error) {
+	if s.encryptionKey == "" {
+		return nil, "", ErrSigningUnavailable
+	}
+
+	var encrypted string
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+signingKeyColumns+`, secret_encrypted
+		FROM api_signing_keys
+		WHERE key_id = $1 AND is_active = true AND (expires_at IS NULL OR expires_at > NOW())`, keyID)
+	key, err := scanSigningKey(row, &encrypted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrSigningKeyNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load signing key: %w", err)
+	}
+	secret, err := crypto.Decrypt(encrypted, s.encryptionKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt signing secret: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		"UPDATE api_signing_keys SET last_used_at = NOW() WHERE id = $1", key.ID); err != nil {
+		s.logger.Warn("Failed to update signing key last used", zap.Error(err))
+	}
+	return key, secret, nil
+}
+
+// UseNonce records a signed request's nonce until expiresAt. It returns false
+// when the key already used the nonce, meaning the request is a replay.
+func (s *Service) UseNonce(ctx context.Context, keyID, nonce string, expiresAt time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_signing_nonces (key_id, nonce, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key_id, nonce) DO NOTHING`, keyID, nonce, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record signing nonce: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected == 1, nil
+}
+
+// CleanupExpiredNonces removes nonces whose requests are outside the
+// tolerance window and could no longer be replayed anyway
+func (s *Service) CleanupExpiredNonces(ctx context.Context) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM api_signing_nonces WHERE expires_at < NOW()`)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup signing nonces: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	s.logger.Info("Cleaned up expired signing nonces", zap.Int64("rows_affected", rowsAffected))
+	return nil
+}
+
+type signingKeyScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSigningKey(row signingKeyScanner, extra ...interface{}) (*SigningKey, error) {
+	key := &SigningKey{}
+	dest := append([]interface{}{
+		&key.ID, &key.KeyID, &key.Name, &key.Tenant, &key.Environment,
+		pq.Array(&key.Scopes), &key.IsActive, &key.LastUsedAt, &key.ExpiresAt, &key.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func randomToken(prefix string, size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,344 @@
+package apiusage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// LatencyBoundsMs are the upper bounds of the latency histogram kept for each
+// rollup. Requests slower than the last bound fall into one overflow bucket.
+var LatencyBoundsMs = []int64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Repository persists API usage rollups
+type Repository interface {
+	// Upsert adds the rollups to any already stored for the same bucket,
+	// user, API key, method and route
+	Upsert(ctx context.Context, rollups []*entities.APIUsageRollup) error
+	// Query sums rollups matching the filter. UserID is uuid.Nil on every
+	// result when grouping by endpoint.
+	Query(ctx context.Context, filter entities.APIUsageFilter) ([]*entities.APIUsageRollup, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Config configures aggregation and retention of API usage
+type Config struct {
+	Enabled       bool
+	FlushInterval time.Duration // How often in-memory counts are written to the rollup table
+	BucketSize    time.Duration // Width of each rollup bucket
+	Retention     time.Duration // How long rollups are kept
+}
+
+// DefaultConfig flushes every minute into hourly buckets kept for 30 days
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       true,
+		FlushInterval: time.Minute,
+		BucketSize:    time.Hour,
+		Retention:     30 * 24 * time.Hour,
+	}
+}
+
+type rollupKey struct {
+	bucket   time.Time
+	userID   uuid.UUID
+	apiKeyID uuid.UUID
+	method   string
+	route    string
+}
+
+// Service aggregates requests seen by the HTTP middleware in memory and
+// periodically adds them to the rollup table, so recording never touches the
+// database on the request path
+type Service struct {
+	repo   Repository
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[rollupKey]*entities.APIUsageRollup
+}
+
+// NewService creates an API usage service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.BucketSize <= 0 {
+		config.BucketSize = defaults.BucketSize
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	return &Service{
+		repo:    repo,
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+		pending: make(map[rollupKey]*entities.APIUsageRollup),
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Enabled reports whether requests are being recorded
+func (s *Service) Enabled() bool {
+	return s.config.Enabled
+}
+
+// Start flushes pending counts and prunes expired rollups until ctx is
+// cancelled, then flushes once more
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		flush := time.NewTicker(s.config.FlushInterval)
+		defer flush.Stop()
+		prune := time.NewTicker(time.Hour)
+		defer prune.Stop()
+
+		s.prune(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				// ctx is already cancelled, so the last flush gets its own deadline
+				flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := s.Flush(flushCtx); err != nil {
+					s.logger.Warn("Failed to flush API usage on shutdown", zap.Error(err))
+				}
+				cancel()
+				s.logger.Info("API usage aggregation stopped")
+				return
+			case <-flush.C:
+				if err := s.Flush(ctx); err != nil {
+					s.logger.Warn("Failed to flush API usage", zap.Error(err))
+				}
+			case <-prune.C:
+				s.prune(ctx)
+			}
+		}
+	}()
+}
+
+// Record counts one request. userID is nil for unauthenticated requests.
+func (s *Service) Record(userID *uuid.UUID, method, route string, status int, duration time.Duration) {
+	s.RecordAPIKey(uuid.Nil, userID, method, route, status, duration)
+}
+
+// RecordAPIKey counts one request made with an API key, so usage can also be
+// reported per key
+func (s *Service) RecordAPIKey(apiKeyID uuid.UUID, userID *uuid.UUID, method, route string, status int, duration time.Duration) {
+	if !s.config.Enabled {
+		return
+	}
+
+	key := rollupKey{
+		bucket:   s.now().UTC().Truncate(s.config.BucketSize),
+		apiKeyID: apiKeyID,
+		method:   method,
+		route:    route,
+	}
+	if userID != nil {
+		key.userID = *userID
+	}
+	ms := duration.Milliseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rollup, ok := s.pending[key]
+	if !ok {
+		rollup = &entities.APIUsageRollup{
+			BucketStart:    key.bucket,
+			UserID:         key.userID,
+			APIKeyID:       key.apiKeyID,
+			Method:         method,
+			Route:          route,
+			LatencyBuckets: make([]int64, len(LatencyBoundsMs)+1),
+		}
+		s.pending[key] = rollup
+	}
+	rollup.RequestCount++
+	switch {
+	case status >= 500:
+		rollup.ServerErrorCount++
+	case status >= 400:
+		rollup.ClientErrorCount++
+	}
+	rollup.TotalDurationMs += ms
+	rollup.LatencyBuckets[latencyBucket(ms)]++
+}
+
+// Flush writes pending counts to the rollup table. Counts that fail to write
+// are kept and retried on the next flush.
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[rollupKey]*entities.APIUsageRollup)
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	rollups := make([]*entities.APIUsageRollup, 0, len(batch))
+	for _, rollup := range batch {
+		rollups = append(rollups, rollup)
+	}
+	if err := s.repo.Upsert(ctx, rollups); err != nil {
+		s.restore(batch)
+		return fmt.Errorf("failed to write api usage: %w", err)
+	}
+	return nil
+}
+
+// Pending returns how many rollups are waiting to be flushed
+func (s *Service) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// restore merges an unwritten batch back into the pending counts
+func (s *Service) restore(batch map[rollupKey]*entities.APIUsageRollup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, unwritten := range batch {
+		rollup, ok := s.pending[key]
+		if !ok {
+			s.pending[key] = unwritten
+			continue
+		}
+		rollup.RequestCount += unwritten.RequestCount
+		rollup.ClientErrorCount += unwritten.ClientErrorCount
+		rollup.ServerErrorCount += unwritten.ServerErrorCount
+		rollup.TotalDurationMs += unwritten.TotalDurationMs
+		for i, count := range unwritten.LatencyBuckets {
+			rollup.LatencyBuckets[i] += count
+		}
+	}
+}
+
+func (s *Service) prune(ctx context.Context) {
+	deleted, err := s.repo.DeleteBefore(ctx, s.now().Add(-s.config.Retention))
+	if err != nil {
+		s.logger.Warn("Failed to prune API usage rollups", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		s.logger.Info("Pruned API usage rollups", zap.Int64("deleted", deleted))
+	}
+}
+
+// Report returns request counts, error rates and latency per endpoint, and
+// per user unless grouped by endpoint, busiest first. Counts still waiting to
+// be flushed are not included. The window defaults to the last 24 hours.
+func (s *Service) Report(ctx context.Context, filter entities.APIUsageFilter) (*entities.APIUsageReport, error) {
+	if filter.Until.IsZero() {
+		filter.Until = s.now()
+	}
+	if filter.Since.IsZero() {
+		filter.Since = filter.Until.Add(-24 * time.Hour)
+	}
+	if !filter.Since.Before(filter.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", entities.ErrInvalidAPIUsageFilter)
+	}
+	switch filter.GroupBy {
+	case "":
+		filter.GroupBy = entities.APIUsageByUserEndpoint
+	case entities.APIUsageByUserEndpoint, entities.APIUsageByEndpoint:
+	default:
+		return nil, fmt.Errorf("%w: unknown group_by %q", entities.ErrInvalidAPIUsageFilter, filter.GroupBy)
+	}
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	// A bucket is reported if any part of it falls in the window
+	filter.Since = filter.Since.UTC().Truncate(s.config.BucketSize)
+
+	rollups, err := s.repo.Query(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api usage: %w", err)
+	}
+
+	stats := make([]*entities.APIUsageStat, 0, len(rollups))
+	for _, rollup := range rollups {
+		stats = append(stats, toStat(rollup, filter.GroupBy))
+	}
+	return &entities.APIUsageReport{
+		Since:   filter.Since,
+		Until:   filter.Until,
+		GroupBy: filter.GroupBy,
+		Stats:   stats,
+	}, nil
+}
+
+func toStat(rollup *entities.APIUsageRollup, groupBy entities.APIUsageGrouping) *entities.APIUsageStat {
+	stat := &entities.APIUsageStat{
+		Method:           rollup.Method,
+		Route:            rollup.Route,
+		RequestCount:     rollup.RequestCount,
+		ClientErrorCount: rollup.ClientErrorCount,
+		ServerErrorCount: rollup.ServerErrorCount,
+		P95LatencyMs:     Percentile(rollup.LatencyBuckets, 0.95),
+	}
+	if groupBy == entities.APIUsageByUserEndpoint {
+		userID := rollup.UserID
+		stat.UserID = &userID
+	}
+	if rollup.RequestCount > 0 {
+		requests := float64(rollup.RequestCount)
+		stat.ErrorRate = float64(rollup.ClientErrorCount+rollup.ServerErrorCount) / requests
+		stat.AvgLatencyMs = float64(rollup.TotalDurationMs) / requests
+	}
+	return stat
+}
+
+// Percentile returns the upper bound in milliseconds of the latency bucket
+// holding the given quantile. Quantiles in the overflow bucket report the
+// last bound.
+func Percentile(buckets []int64, quantile float64) int64 {
+	var total int64
+	for _, count := range buckets {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(quantile * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, count := range buckets {
+		seen += count
+		if seen >= rank {
+			if i >= len(LatencyBoundsMs) {
+				break
+			}
+			return LatencyBoundsMs[i]
+		}
+	}
+	return LatencyBoundsMs[len(LatencyBoundsMs)-1]
+}
+
+func latencyBucket(ms int64) int {
+	for i, bound := range LatencyBoundsMs {
+		if ms <= bound {
+			return i
+		}
+	}
+	return len(LatencyBoundsMs)
+}
@@ -0,0 +1,254 @@
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Repository persists approval requests and their decisions
+type Repository interface {
+	// Create inserts a request unless one is already pending for the same
+	// action and resource, in which case that one is returned with created=false
+	Create(ctx context.Context, request *entities.ApprovalRequest) (*entities.ApprovalRequest, bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.ApprovalRequest, error)
+	List(ctx context.Context, status entities.ApprovalStatus, action entities.ApprovalAction, limit, offset int) ([]*entities.ApprovalRequest, error)
+	// AddDecision records a reviewer's vote, reporting false if they already voted
+	AddDecision(ctx context.Context, requestID uuid.UUID, decision entities.ApprovalDecision) (bool, error)
+	// Resolve moves a request out of status from, reporting whether it was
+	// still in it
+	Resolve(ctx context.Context, id uuid.UUID, from, to entities.ApprovalStatus, executionError *string, at time.Time) (bool, error)
+}
+
+// Handler carries out an action once its request is decided
+type Handler interface {
+	Approved(ctx context.Context, request *entities.ApprovalRequest) error
+	Rejected(ctx context.Context, request *entities.ApprovalRequest) error
+}
+
+// Policy is an N-of-M rule: RequiredApprovals distinct reviewers, other than
+// the requester, drawn from Approvers. An empty Approvers list lets any admin
+// review.
+type Policy struct {
+	RequiredApprovals int
+	Approvers         []uuid.UUID
+}
+
+func (p Policy) allows(adminID uuid.UUID) bool {
+	if len(p.Approvers) == 0 {
+		return true
+	}
+	for _, approver := range p.Approvers {
+		if approver == adminID {
+			return true
+		}
+	}
+	return false
+}
+
+type registration struct {
+	policy  Policy
+	handler Handler
+}
+
+// Service queues sensitive actions for maker-checker review and hands them
+// to the action's handler once decided
+type Service struct {
+	repo    Repository
+	actions map[entities.ApprovalAction]registration
+	logger  *zap.Logger
+}
+
+// NewService creates an approvals service
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		actions: make(map[entities.ApprovalAction]registration),
+		logger:  logger,
+	}
+}
+
+// Register sets the policy and handler of an action. Register every action
+// before serving requests.
+func (s *Service) Register(action entities.ApprovalAction, policy Policy, handler Handler) {
+	if policy.RequiredApprovals < 1 {
+		policy.RequiredApprovals = 1
+	}
+	if len(policy.Approvers) > 0 && policy.RequiredApprovals > len(policy.Approvers) {
+		s.logger.Warn("Approval policy needs more approvers than it lists",
+			zap.String("action", string(action)),
+			zap.Int("required", policy.RequiredApprovals),
+			zap.Int("approvers", len(policy.Approvers)))
+	}
+	s.actions[action] = registration{policy: policy, handler: handler}
+}
+
+// Submit queues an action for review. A request already pending for the
+// same action and resource is returned instead of a new one.
+func (s *Service) Submit(ctx context.Context, req *entities.SubmitApprovalRequest) (*entities.ApprovalRequest, error) {
+	registered, ok := s.actions[req.Action]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", entities.ErrApprovalUnknownAction, req.Action)
+	}
+
+	now := time.Now()
+	request, created, err := s.repo.Create(ctx, &entities.ApprovalRequest{
+		ID:                uuid.New(),
+		Action:            req.Action,
+		ResourceID:        req.ResourceID,
+		RequestedBy:       req.RequestedBy,
+		Summary:           req.Summary,
+		Payload:           req.Payload,
+		RequiredApprovals: registered.policy.RequiredApprovals,
+		Status:            entities.ApprovalPending,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approval request: %w", err)
+	}
+	if created {
+		s.logger.Info("Approval requested",
+			zap.String("request_id", request.ID.String()),
+			zap.String("action", string(request.Action)),
+			zap.String("resource_id", request.ResourceID),
+			zap.Int("required_approvals", request.RequiredApprovals))
+	}
+	return request, nil
+}
+
+// Get returns an approval request with its decisions
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.ApprovalRequest, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns approval requests, optionally filtered, oldest first
+func (s *Service) List(ctx context.Context, status entities.ApprovalStatus, action entities.ApprovalAction, limit, offset int) ([]*entities.ApprovalRequest, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, action, limit, offset)
+}
+
+// Approve records an admin's approval. The request is approved and its action
+// carried out once it has the approvals its policy requires; if the action
+// fails the request is marked failed with the error.
+func (s *Service) Approve(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.ApprovalRequest, error) {
+	request, registered, err := s.review(ctx, id, adminID, true, notes)
+	if err != nil {
+		return nil, err
+	}
+	if request.Approvals() < request.RequiredApprovals {
+		return request, nil
+	}
+
+	now := time.Now()
+	won, err := s.repo.Resolve(ctx, id, entities.ApprovalPending, entities.ApprovalApproved, nil, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve request: %w", err)
+	}
+	if !won {
+		// Another reviewer's approval completed it concurrently
+		return s.repo.GetByID(ctx, id)
+	}
+	request.Status = entities.ApprovalApproved
+	request.ResolvedAt = &now
+
+	if err := registered.handler.Approved(ctx, request); err != nil {
+		s.logger.Error("Approved action failed",
+			zap.String("request_id", id.String()),
+			zap.String("action", string(request.Action)),
+			zap.Error(err))
+		message := err.Error()
+		if _, resolveErr := s.repo.Resolve(ctx, id, entities.ApprovalApproved, entities.ApprovalFailed, &message, time.Now()); resolveErr != nil {
+			s.logger.Error("Failed to record approval failure", zap.String("request_id", id.String()), zap.Error(resolveErr))
+		}
+		request.Status = entities.ApprovalFailed
+		request.ExecutionError = &message
+		return request, nil
+	}
+
+	s.logger.Info("Approved action carried out",
+		zap.String("request_id", id.String()),
+		zap.String("action", string(request.Action)),
+		zap.String("resource_id", request.ResourceID))
+	return request, nil
+}
+
+// Reject records an admin's rejection, which rejects the request outright
+func (s *Service) Reject(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.ApprovalRequest, error) {
+	request, registered, err := s.review(ctx, id, adminID, false, notes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	won, err := s.repo.Resolve(ctx, id, entities.ApprovalPending, entities.ApprovalRejected, nil, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject request: %w", err)
+	}
+	if !won {
+		return s.repo.GetByID(ctx, id)
+	}
+	request.Status = entities.ApprovalRejected
+	request.ResolvedAt = &now
+
+	if err := registered.handler.Rejected(ctx, request); err != nil {
+		// The rejection stands; the handler's cleanup is retried by hand
+		s.logger.Error("Failed to unwind rejected action",
+			zap.String("request_id", id.String()),
+			zap.String("action", string(request.Action)),
+			zap.Error(err))
+	}
+	return request, nil
+}
+
+// review checks the admin may decide the request and records their vote,
+// returning the request with the vote included
+func (s *Service) review(ctx context.Context, id, adminID uuid.UUID, approved bool, notes *string) (*entities.ApprovalRequest, registration, error) {
+	request, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, registration{}, err
+	}
+	if request.Status != entities.ApprovalPending {
+		return nil, registration{}, entities.ErrApprovalNotPending
+	}
+	registered, ok := s.actions[request.Action]
+	if !ok {
+		return nil, registration{}, fmt.Errorf("%w: %s", entities.ErrApprovalUnknownAction, request.Action)
+	}
+	if request.RequestedBy != nil && *request.RequestedBy == adminID {
+		return nil, registration{}, entities.ErrApprovalSelfReview
+	}
+	if !registered.policy.allows(adminID) {
+		return nil, registration{}, entities.ErrApprovalNotApprover
+	}
+
+	decision := entities.ApprovalDecision{
+		AdminID:   adminID,
+		Approved:  approved,
+		Notes:     notes,
+		DecidedAt: time.Now(),
+	}
+	added, err := s.repo.AddDecision(ctx, id, decision)
+	if err != nil {
+		return nil, registration{}, fmt.Errorf("failed to record decision: %w", err)
+	}
+	if !added {
+		return nil, registration{}, entities.ErrApprovalAlreadyDecided
+	}
+
+	request, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, registration{}, err
+	}
+	return request, registered, nil
+}
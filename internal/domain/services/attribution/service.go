@@ -0,0 +1,360 @@
+package attribution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrInvalidRange is returned for a range other than 1W, 1M, 3M, 6M, 1Y or YTD
+var ErrInvalidRange = errors.New("range must be one of 1W, 1M, 3M, 6M, 1Y or YTD")
+
+// ratioPlaces is how many decimal places returns, weights and effects keep
+const ratioPlaces = 6
+
+// PositionRepository lists a user's basket positions
+type PositionRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error)
+}
+
+// BasketRepository loads basket compositions
+type BasketRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
+}
+
+// PriceChange is a symbol's closing price at the start and end of a period
+type PriceChange struct {
+	Start decimal.Decimal
+	End   decimal.Decimal
+}
+
+// PriceHistory returns closing prices at both ends of a period. Symbols it
+// cannot price are left out of the result.
+type PriceHistory interface {
+	GetPriceChanges(ctx context.Context, symbols []string, start, end time.Time) (map[string]PriceChange, error)
+}
+
+// PerformanceHistory returns a user's recorded daily NAV
+type PerformanceHistory interface {
+	GetPortfolioPerformance(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entities.PerformancePoint, error)
+}
+
+// Config configures attribution
+type Config struct {
+	Benchmark string // Symbol the market and selection effects are measured against
+}
+
+// DefaultConfig measures against the S&P 500
+func DefaultConfig() Config {
+	return Config{Benchmark: "SPY"}
+}
+
+// Service attributes a user's return to the baskets they hold and to each
+// basket's components. Holdings are taken as they stand now and each basket
+// as held at its target composition, so a basket's return is the weighted
+// return of its components and its starting value is backed out of its
+// current market value. Effects are measured Brinson-style against a single
+// benchmark: every basket's contribution splits into the benchmark return on
+// its weight plus a selection effect, and selection effects sum to the
+// portfolio's active return.
+type Service struct {
+	positions   PositionRepository
+	baskets     BasketRepository
+	prices      PriceHistory
+	performance PerformanceHistory
+	config      Config
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewService creates an attribution service
+func NewService(positions PositionRepository, baskets BasketRepository, prices PriceHistory, config Config, logger *zap.Logger) *Service {
+	if config.Benchmark == "" {
+		config.Benchmark = DefaultConfig().Benchmark
+	}
+	return &Service{
+		positions: positions,
+		baskets:   baskets,
+		prices:    prices,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetPerformanceHistory enables comparing the attributed return with the
+// return recorded in the user's NAV history
+func (s *Service) SetPerformanceHistory(performance PerformanceHistory) {
+	s.performance = performance
+}
+
+// RangeStart returns when a range begins for a period ending at end
+func RangeStart(r entities.AttributionRange, end time.Time) (time.Time, error) {
+	switch r {
+	case entities.AttributionRange1W:
+		return end.AddDate(0, 0, -7), nil
+	case entities.AttributionRange1M:
+		return end.AddDate(0, -1, 0), nil
+	case entities.AttributionRange3M:
+		return end.AddDate(0, -3, 0), nil
+	case entities.AttributionRange6M:
+		return end.AddDate(0, -6, 0), nil
+	case entities.AttributionRange1Y:
+		return end.AddDate(-1, 0, 0), nil
+	case entities.AttributionRangeYTD:
+		return time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, end.Location()), nil
+	default:
+		return time.Time{}, ErrInvalidRange
+	}
+}
+
+// heldBasket is a position with its composition, before weights are known
+type heldBasket struct {
+	basket     *entities.Basket
+	endValue   decimal.Decimal
+	startValue decimal.Decimal
+	ret        decimal.Decimal
+	components []*entities.ComponentAttribution
+}
+
+// Attribute breaks down the user's return over the range
+func (s *Service) Attribute(ctx context.Context, userID uuid.UUID, r entities.AttributionRange) (*entities.PerformanceAttribution, error) {
+	end := s.now().UTC()
+	start, err := RangeStart(r, end)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := s.positions.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	held := make([]*heldBasket, 0, len(positions))
+	symbols := map[string]bool{s.config.Benchmark: true}
+	for _, position := range positions {
+		if !position.MarketValue.IsPositive() {
+			continue
+		}
+		basket, err := s.baskets.GetByID(ctx, position.BasketID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get basket %s: %w", position.BasketID, err)
+		}
+		for _, component := range basket.Composition {
+			symbols[component.Symbol] = true
+		}
+		held = append(held, &heldBasket{basket: basket, endValue: position.MarketValue})
+	}
+
+	prices := map[string]PriceChange{}
+	if len(held) > 0 {
+		list := make([]string, 0, len(symbols))
+		for symbol := range symbols {
+			list = append(list, symbol)
+		}
+		sort.Strings(list)
+		prices, err = s.prices.GetPriceChanges(ctx, list, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price history: %w", err)
+		}
+	}
+	benchmarkReturn, _ := priceReturn(prices, s.config.Benchmark)
+
+	totalStart, totalEnd := decimal.Zero, decimal.Zero
+	for _, h := range held {
+		s.priceBasket(h, prices)
+		totalStart = totalStart.Add(h.startValue)
+		totalEnd = totalEnd.Add(h.endValue)
+	}
+
+	report := &entities.PerformanceAttribution{
+		UserID:          userID,
+		Range:           r,
+		Start:           start,
+		End:             end,
+		Benchmark:       s.config.Benchmark,
+		BenchmarkReturn: benchmarkReturn.Round(ratioPlaces),
+		PortfolioReturn: decimal.Zero,
+		ActiveReturn:    decimal.Zero,
+		StartValue:      entities.NewMoney(totalStart, entities.CurrencyUSD).Round(),
+		EndValue:        entities.NewMoney(totalEnd, entities.CurrencyUSD).Round(),
+		Baskets:         []*entities.BasketAttribution{},
+		GeneratedAt:     end,
+	}
+
+	portfolioReturn := decimal.Zero
+	for _, h := range held {
+		weight := decimal.Zero
+		if totalStart.IsPositive() {
+			weight = h.startValue.Div(totalStart)
+		}
+		contribution := weight.Mul(h.ret)
+		portfolioReturn = portfolioReturn.Add(contribution)
+
+		for _, component := range h.components {
+			componentWeight := weight.Mul(component.Weight)
+			component.PortfolioWeight = componentWeight.Round(ratioPlaces)
+			component.Contribution = componentWeight.Mul(component.Return).Round(ratioPlaces)
+			component.SelectionEffect = componentWeight.Mul(component.Return.Sub(benchmarkReturn)).Round(ratioPlaces)
+			component.Weight = component.Weight.Round(ratioPlaces)
+			component.Return = component.Return.Round(ratioPlaces)
+		}
+		sort.SliceStable(h.components, func(i, j int) bool {
+			return h.components[i].Contribution.GreaterThan(h.components[j].Contribution)
+		})
+
+		report.Baskets = append(report.Baskets, &entities.BasketAttribution{
+			BasketID:        h.basket.ID,
+			BasketName:      h.basket.Name,
+			Weight:          weight.Round(ratioPlaces),
+			Return:          h.ret.Round(ratioPlaces),
+			Contribution:    contribution.Round(ratioPlaces),
+			MarketEffect:    weight.Mul(benchmarkReturn).Round(ratioPlaces),
+			SelectionEffect: weight.Mul(h.ret.Sub(benchmarkReturn)).Round(ratioPlaces),
+			StartValue:      entities.NewMoney(h.startValue, entities.CurrencyUSD).Round(),
+			EndValue:        entities.NewMoney(h.endValue, entities.CurrencyUSD).Round(),
+			Gain:            entities.NewMoney(h.endValue.Sub(h.startValue), entities.CurrencyUSD).Round(),
+			Components:      h.components,
+		})
+	}
+	sort.SliceStable(report.Baskets, func(i, j int) bool {
+		return report.Baskets[i].Contribution.GreaterThan(report.Baskets[j].Contribution)
+	})
+
+	report.PortfolioReturn = portfolioReturn.Round(ratioPlaces)
+	if len(held) > 0 {
+		report.ActiveReturn = portfolioReturn.Sub(benchmarkReturn).Round(ratioPlaces)
+	}
+	s.compareReported(ctx, report, portfolioReturn)
+	report.Highlights = Highlights(report)
+
+	s.logger.Info("Attributed portfolio performance",
+		zap.String("user_id", userID.String()),
+		zap.String("range", string(r)),
+		zap.Int("baskets", len(report.Baskets)),
+		zap.String("portfolio_return", report.PortfolioReturn.String()))
+	return report, nil
+}
+
+// priceBasket works out a basket's return from its components' returns and
+// backs its starting value out of its current value. Components without a
+// price are reported with a zero return.
+func (s *Service) priceBasket(h *heldBasket, prices map[string]PriceChange) {
+	totalWeight := decimal.Zero
+	for _, component := range h.basket.Composition {
+		totalWeight = totalWeight.Add(component.Weight)
+	}
+
+	h.ret = decimal.Zero
+	h.components = make([]*entities.ComponentAttribution, 0, len(h.basket.Composition))
+	for _, component := range h.basket.Composition {
+		weight := decimal.Zero
+		if totalWeight.IsPositive() {
+			weight = component.Weight.Div(totalWeight)
+		}
+		ret, priced := priceReturn(prices, component.Symbol)
+		h.ret = h.ret.Add(weight.Mul(ret))
+		h.components = append(h.components, &entities.ComponentAttribution{
+			Symbol: component.Symbol,
+			Weight: weight,
+			Return: ret,
+			Priced: priced,
+		})
+	}
+
+	growth := decimal.NewFromInt(1).Add(h.ret)
+	h.startValue = h.endValue
+	if growth.IsPositive() {
+		h.startValue = h.endValue.Div(growth)
+	}
+}
+
+// compareReported adds the return recorded in the user's NAV history, when
+// there is enough of it, and how far the holdings-based return is from it
+func (s *Service) compareReported(ctx context.Context, report *entities.PerformanceAttribution, portfolioReturn decimal.Decimal) {
+	if s.performance == nil {
+		return
+	}
+	points, err := s.performance.GetPortfolioPerformance(ctx, report.UserID, report.Start, report.End)
+	if err != nil {
+		s.logger.Warn("Failed to load performance history for attribution",
+			zap.String("user_id", report.UserID.String()), zap.Error(err))
+		return
+	}
+	if len(points) < 2 || !points[0].Value.IsPositive() {
+		return
+	}
+
+	reported := points[len(points)-1].Value.Amount.Div(points[0].Value.Amount).Sub(decimal.NewFromInt(1))
+	unexplained := reported.Sub(portfolioReturn).Round(ratioPlaces)
+	reported = reported.Round(ratioPlaces)
+	report.ReportedReturn = &reported
+	report.Unexplained = &unexplained
+}
+
+// priceReturn is a symbol's return over the period, and whether it was priced
+func priceReturn(prices map[string]PriceChange, symbol string) (decimal.Decimal, bool) {
+	change, ok := prices[symbol]
+	if !ok || !change.Start.IsPositive() || !change.End.IsPositive() {
+		return decimal.Zero, false
+	}
+	return change.End.Div(change.Start).Sub(decimal.NewFromInt(1)), true
+}
+
+// Highlights summarises an attribution in a few plain sentences: the overall
+// return against the benchmark, the biggest contributor and detractor and the
+// best component. They are returned with the report and are meant to be
+// dropped into AI summaries as-is.
+func Highlights(report *entities.PerformanceAttribution) []string {
+	if len(report.Baskets) == 0 {
+		return []string{"No invested positions over this period."}
+	}
+
+	highlights := []string{fmt.Sprintf("Your portfolio returned %s over %s against %s for %s.",
+		percent(report.PortfolioReturn), report.Range, percent(report.BenchmarkReturn), report.Benchmark)}
+
+	best := report.Baskets[0]
+	highlights = append(highlights, fmt.Sprintf("%s contributed the most, %s of the return (%s selection vs %s).",
+		best.BasketName, percent(best.Contribution), percent(best.SelectionEffect), report.Benchmark))
+
+	if worst := report.Baskets[len(report.Baskets)-1]; worst != best && worst.Contribution.IsNegative() {
+		highlights = append(highlights, fmt.Sprintf("%s was the biggest detractor at %s.",
+			worst.BasketName, percent(worst.Contribution)))
+	}
+
+	var top *entities.ComponentAttribution
+	for _, basket := range report.Baskets {
+		for _, component := range basket.Components {
+			if top == nil || component.Contribution.GreaterThan(top.Contribution) {
+				top = component
+			}
+		}
+	}
+	if top != nil && top.Contribution.IsPositive() {
+		highlights = append(highlights, fmt.Sprintf("The best component was %s, up %s and adding %s.",
+			top.Symbol, percent(top.Return), percent(top.Contribution)))
+	}
+
+	if report.Unexplained != nil && report.Unexplained.Abs().GreaterThan(decimal.NewFromFloat(0.005)) {
+		highlights = append(highlights, fmt.Sprintf("Deposits, withdrawals and trades moved the recorded return by a further %s.",
+			percent(*report.Unexplained)))
+	}
+	return highlights
+}
+
+// percent formats a ratio as a signed percentage, e.g. "+4.20%"
+func percent(ratio decimal.Decimal) string {
+	value := ratio.Mul(decimal.NewFromInt(100)).StringFixed(2)
+	if !ratio.IsNegative() {
+		value = "+" + value
+	}
+	return value + "%"
+}
@@ -36,14 +36,14 @@ func NewBalanceService(balanceRepo *repositories.BalanceRepository, alpacaAdapte
 func (s *BalanceService) UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
 	s.logger.Info("Updating buying power",
 		"user_id", userID.String(),
-		"amount", amount.String())
+		"amount", logger.RedactAmount(amount))
 
 	if err := s.balanceRepo.UpdateBuyingPower(ctx, userID, amount); err != nil {
 		s.logger.Error("Failed to update buying power", "error", err, "user_id", userID.String())
 		return fmt.Errorf("update buying power: %w", err)
 	}
 
-	s.logger.Info("Successfully updated buying power", "user_id", userID.String(), "amount", amount.String())
+	s.logger.Info("Successfully updated buying power", "user_id", userID.String(), "amount", logger.RedactAmount(amount))
 	return nil
 }
 
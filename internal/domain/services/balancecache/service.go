@@ -0,0 +1,382 @@
+package balancecache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository stores the last known balance of each live wallet
+type Repository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WalletBalanceSnapshot, error)
+	ListStale(ctx context.Context, syncedBefore time.Time, limit int) ([]*entities.WalletBalanceSnapshot, error)
+	Save(ctx context.Context, snapshot *entities.WalletBalanceSnapshot) error
+	RecordError(ctx context.Context, walletID, userID uuid.UUID, message string) error
+	SaveBulk(ctx context.Context, balances map[string]decimal.Decimal, syncedAt time.Time) (int, error)
+	Sum(ctx context.Context, syncedSince time.Time) (*entities.BalanceAggregate, error)
+}
+
+// CircleClient reads wallet balances from Circle
+type CircleClient interface {
+	GetWalletBalances(ctx context.Context, walletID string, tokenAddress ...string) (*entities.CircleWalletBalancesResponse, error)
+}
+
+// BulkCircleClient reads many wallet balances per request. TotalUSDC uses it
+// when the client supports it and falls back to per-wallet reads.
+type BulkCircleClient interface {
+	ListWalletBalances(ctx context.Context, pageAfter string, pageSize int) ([]entities.CircleWalletBalances, error)
+}
+
+// Counter backs the per-user forced refresh limit
+type Counter interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+}
+
+// Config controls how fresh cached balances are kept
+type Config struct {
+	RefreshInterval    time.Duration
+	MaxAge             time.Duration
+	BatchSize          int
+	Concurrency        int
+	ForceRefreshLimit  int
+	ForceRefreshWindow time.Duration
+}
+
+// Service serves wallet balances from a cache instead of calling Circle on
+// every request. A worker refreshes wallets older than MaxAge, deposit events
+// refresh the depositing user, and users may force a refresh a few times per
+// window. Responses carry the sync time so clients can show staleness.
+type Service struct {
+	repo    Repository
+	client  CircleClient
+	counter Counter
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+}
+
+// NewService creates a new balance cache service. counter may be nil, in
+// which case forced refreshes are not limited.
+func NewService(repo Repository, client CircleClient, counter Counter, config Config, logger *zap.Logger) *Service {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Minute
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 5 * time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 200
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+	if config.ForceRefreshLimit <= 0 {
+		config.ForceRefreshLimit = 3
+	}
+	if config.ForceRefreshWindow <= 0 {
+		config.ForceRefreshWindow = time.Minute
+	}
+	return &Service{
+		repo:    repo,
+		client:  client,
+		counter: counter,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// GetUserBalances returns a user's cached balances. Wallets that have never
+// been synced are read from Circle first so new wallets do not show zero.
+func (s *Service) GetUserBalances(ctx context.Context, userID uuid.UUID) (*entities.UserWalletBalances, error) {
+	snapshots, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var unsynced []*entities.WalletBalanceSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.LastSyncedAt == nil {
+			unsynced = append(unsynced, snapshot)
+		}
+	}
+	s.refresh(ctx, unsynced)
+
+	return s.aggregate(userID, snapshots), nil
+}
+
+// ForceRefresh reads every wallet of the user from Circle, subject to the
+// per-user limit
+func (s *Service) ForceRefresh(ctx context.Context, userID uuid.UUID) (*entities.UserWalletBalances, error) {
+	if err := s.allowForceRefresh(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.RefreshUser(ctx, userID)
+}
+
+// RefreshUser reads every wallet of the user from Circle
+func (s *Service) RefreshUser(ctx context.Context, userID uuid.UUID) (*entities.UserWalletBalances, error) {
+	snapshots, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.refresh(ctx, snapshots)
+	return s.aggregate(userID, snapshots), nil
+}
+
+// RefreshStale refreshes one batch of wallets older than MaxAge and returns
+// how many were read successfully
+func (s *Service) RefreshStale(ctx context.Context) (int, error) {
+	refreshed, _, err := s.refreshStaleBatch(ctx)
+	return refreshed, err
+}
+
+// TotalUSDC brings every wallet up to date and returns the cached total
+// across all live wallets. Balances are read with the bulk endpoint when the
+// client supports it, then wallets it missed are read individually by a
+// bounded worker pool. Wallets that still could not be read are reported as
+// skipped and count at their last known balance.
+func (s *Service) TotalUSDC(ctx context.Context) (*entities.BalanceAggregate, error) {
+	if bulk, ok := s.client.(BulkCircleClient); ok {
+		if err := s.refreshBulk(ctx, bulk); err != nil {
+			s.logger.Warn("Bulk balance read failed, reading wallets individually", zap.Error(err))
+		}
+	}
+
+	for {
+		refreshed, listed, err := s.refreshStaleBatch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Stop after a short batch, or once a batch holds only wallets that
+		// keep failing and would be listed again
+		if listed < s.config.BatchSize || refreshed == 0 {
+			break
+		}
+	}
+
+	cutoff := time.Now().UTC().Add(-s.config.MaxAge)
+	aggregate, err := s.repo.Sum(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if aggregate.Skipped > 0 {
+		skipped, err := s.repo.ListStale(ctx, cutoff, entities.MaxSkippedIDs)
+		if err == nil {
+			for _, snapshot := range skipped {
+				aggregate.SkippedIDs = append(aggregate.SkippedIDs, snapshot.CircleWalletID)
+			}
+		}
+		s.logger.Warn("Circle balance total includes wallets that could not be read",
+			zap.Int("skipped", aggregate.Skipped),
+			zap.Int("counted", aggregate.Counted))
+	}
+	return aggregate, nil
+}
+
+// refreshBulk pages through every wallet on the Circle account
+func (s *Service) refreshBulk(ctx context.Context, client BulkCircleClient) error {
+	syncedAt := time.Now().UTC()
+	pageAfter := ""
+	saved := 0
+	for {
+		wallets, err := client.ListWalletBalances(ctx, pageAfter, 0)
+		if err != nil {
+			return err
+		}
+		if len(wallets) == 0 {
+			break
+		}
+
+		balances := make(map[string]decimal.Decimal, len(wallets))
+		for _, wallet := range wallets {
+			resp := entities.CircleWalletBalancesResponse{TokenBalances: wallet.TokenBalances}
+			balance, err := decimal.NewFromString(resp.GetUSDCBalance())
+			if err != nil {
+				continue // left stale; the per-wallet pass retries it
+			}
+			balances[wallet.ID] = balance
+		}
+		n, err := s.repo.SaveBulk(ctx, balances, syncedAt)
+		if err != nil {
+			return err
+		}
+		saved += n
+		pageAfter = wallets[len(wallets)-1].ID
+	}
+	s.logger.Debug("Bulk wallet balances saved", zap.Int("wallets", saved))
+	return nil
+}
+
+// SetTracker reports refresh passes to the worker registry; while paused,
+// cached balances are not refreshed in the background
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Start refreshes stale wallets on every refresh interval until ctx is done
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				refreshed, err := s.RefreshStale(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Error("Balance cache refresh failed", zap.Error(err))
+					continue
+				}
+				if refreshed > 0 {
+					s.logger.Debug("Balance cache refreshed", zap.Int("wallets", refreshed))
+				}
+			}
+		}
+	}()
+}
+
+// refreshStaleBatch returns how many wallets were refreshed and how many were listed
+func (s *Service) refreshStaleBatch(ctx context.Context) (int, int, error) {
+	stale, err := s.repo.ListStale(ctx, time.Now().UTC().Add(-s.config.MaxAge), s.config.BatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.refresh(ctx, stale), len(stale), nil
+}
+
+func (s *Service) allowForceRefresh(ctx context.Context, userID uuid.UUID) error {
+	if s.counter == nil {
+		return nil
+	}
+	key := fmt.Sprintf("balance_refresh:%s", userID)
+	count, err := s.counter.Incr(ctx, key)
+	if err != nil {
+		// Fail open: a cache outage should not block balance reads
+		s.logger.Warn("Failed to check balance refresh limit", zap.Error(err))
+		return nil
+	}
+	if count == 1 {
+		if err := s.counter.Expire(ctx, key, s.config.ForceRefreshWindow); err != nil {
+			s.logger.Warn("Failed to set balance refresh limit window", zap.Error(err))
+		}
+	}
+	if count > int64(s.config.ForceRefreshLimit) {
+		return entities.ErrBalanceRefreshRateLimited
+	}
+	return nil
+}
+
+// refresh reads the given wallets from Circle in parallel, updating each
+// snapshot in place, and returns how many succeeded
+func (s *Service) refresh(ctx context.Context, snapshots []*entities.WalletBalanceSnapshot) int {
+	if len(snapshots) == 0 {
+		return 0
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		refreshed int
+	)
+	sem := make(chan struct{}, s.config.Concurrency)
+	for _, snapshot := range snapshots {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(snapshot *entities.WalletBalanceSnapshot) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if s.refreshWallet(ctx, snapshot) {
+				mu.Lock()
+				refreshed++
+				mu.Unlock()
+			}
+		}(snapshot)
+	}
+	wg.Wait()
+	return refreshed
+}
+
+func (s *Service) refreshWallet(ctx context.Context, snapshot *entities.WalletBalanceSnapshot) bool {
+	balance, err := s.readUSDC(ctx, snapshot.CircleWalletID)
+	if err != nil {
+		s.logger.Warn("Failed to refresh wallet balance",
+			zap.String("circle_wallet_id", snapshot.CircleWalletID),
+			zap.Error(err))
+		message := err.Error()
+		snapshot.LastError = &message
+		if recErr := s.repo.RecordError(ctx, snapshot.WalletID, snapshot.UserID, message); recErr != nil {
+			s.logger.Warn("Failed to record wallet balance error", zap.Error(recErr))
+		}
+		return false
+	}
+
+	now := time.Now().UTC()
+	snapshot.USDCBalance = balance
+	snapshot.LastSyncedAt = &now
+	snapshot.LastError = nil
+	if err := s.repo.Save(ctx, snapshot); err != nil {
+		s.logger.Warn("Failed to save wallet balance",
+			zap.String("circle_wallet_id", snapshot.CircleWalletID),
+			zap.Error(err))
+	}
+	return true
+}
+
+func (s *Service) readUSDC(ctx context.Context, circleWalletID string) (decimal.Decimal, error) {
+	resp, err := s.client.GetWalletBalances(ctx, circleWalletID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	balance, err := decimal.NewFromString(resp.GetUSDCBalance())
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid USDC balance: %w", err)
+	}
+	return balance, nil
+}
+
+func (s *Service) aggregate(userID uuid.UUID, snapshots []*entities.WalletBalanceSnapshot) *entities.UserWalletBalances {
+	result := &entities.UserWalletBalances{
+		UserID:    userID,
+		Wallets:   snapshots,
+		TotalUSDC: decimal.Zero,
+	}
+	if result.Wallets == nil {
+		result.Wallets = []*entities.WalletBalanceSnapshot{}
+	}
+
+	cutoff := time.Now().UTC().Add(-s.config.MaxAge)
+	synced := true
+	for _, snapshot := range snapshots {
+		result.TotalUSDC = result.TotalUSDC.Add(snapshot.USDCBalance)
+		if snapshot.LastSyncedAt == nil {
+			synced = false
+			result.Stale = true
+			continue
+		}
+		if snapshot.LastSyncedAt.Before(cutoff) {
+			result.Stale = true
+		}
+		if result.LastSyncedAt == nil || snapshot.LastSyncedAt.Before(*result.LastSyncedAt) {
+			result.LastSyncedAt = snapshot.LastSyncedAt
+		}
+	}
+	if !synced {
+		result.LastSyncedAt = nil
+	}
+	return result
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/adapters/alpaca"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -39,7 +40,7 @@ func (s *BasketExecutor) ExecuteBasket(
 ) ([]*entities.AlpacaOrderResponse, error) {
 	s.logger.Info("Executing basket orders",
 		zap.String("alpaca_account_id", alpacaAccountID),
-		zap.String("total_amount", totalAmount.String()),
+		logger.Amount("total_amount", totalAmount),
 		zap.Int("allocations", len(allocations)))
 
 	orders := make([]*entities.AlpacaOrderResponse, 0, len(allocations))
@@ -61,7 +62,7 @@ func (s *BasketExecutor) ExecuteBasket(
 		if err != nil {
 			s.logger.Error("Failed to place basket order",
 				zap.String("symbol", allocation.Symbol),
-				zap.String("amount", allocationAmount.String()),
+				logger.Amount("amount", allocationAmount),
 				zap.Error(err))
 			// Continue with other orders even if one fails
 			continue
@@ -70,7 +71,7 @@ func (s *BasketExecutor) ExecuteBasket(
 		s.logger.Info("Basket order placed",
 			zap.String("symbol", allocation.Symbol),
 			zap.String("order_id", order.ID),
-			zap.String("amount", allocationAmount.String()))
+			logger.Amount("amount", allocationAmount))
 
 		orders = append(orders, order)
 	}
@@ -0,0 +1,191 @@
+package blotter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var csvHeader = []string{
+	"event_id", "event_time", "event_type", "order_id", "user_id", "basket_id",
+	"side", "order_type", "time_in_force", "limit_price", "amount", "status",
+	"previous_status", "venue", "brokerage_ref", "order_created_at", "actor_id", "action",
+}
+
+// Render renders records in the format and returns the content and its
+// SHA-256 checksum
+func Render(format entities.BlotterFormat, records []*entities.BlotterRecord, delimiter string) ([]byte, string, error) {
+	var content []byte
+	var err error
+	switch format {
+	case entities.BlotterFormatCSV:
+		content, err = RenderCSV(records)
+	case entities.BlotterFormatFIX:
+		content = RenderFIX(records, delimiter)
+	default:
+		return nil, "", fmt.Errorf("%w: %q", entities.ErrInvalidBlotterFormat, format)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(content)
+	return content, hex.EncodeToString(sum[:]), nil
+}
+
+// RenderCSV renders one row per record with a header row. Times are UTC
+// RFC 3339 with milliseconds.
+func RenderCSV(records []*entities.BlotterRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		limitPrice, actorID := "", ""
+		if r.LimitPrice != nil {
+			limitPrice = r.LimitPrice.String()
+		}
+		if r.ActorID != nil {
+			actorID = r.ActorID.String()
+		}
+		if err := w.Write([]string{
+			r.EventID,
+			r.EventTime.UTC().Format(csvTimeFormat),
+			string(r.EventType),
+			r.OrderID.String(),
+			r.UserID.String(),
+			r.BasketID.String(),
+			string(r.Side),
+			string(r.OrderType),
+			string(r.TimeInForce),
+			limitPrice,
+			r.Amount.String(),
+			r.Status,
+			r.PreviousStatus,
+			r.Venue,
+			r.BrokerageRef,
+			r.OrderCreatedAt.UTC().Format(csvTimeFormat),
+			actorID,
+			r.Action,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render blotter csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	csvTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+	fixTimeFormat = "20060102-15:04:05.000"
+)
+
+// RenderFIX renders each record as a FIX 4.4 execution report (35=8) in
+// tag=value form, one message per line. delimiter separates fields; FIX uses
+// SOH (\x01), which compliance tooling often replaces with a pipe.
+func RenderFIX(records []*entities.BlotterRecord, delimiter string) []byte {
+	var buf bytes.Buffer
+	for _, r := range records {
+		fields := []string{
+			"8=FIX.4.4",
+			"35=8",
+			"17=" + r.EventID,
+			"37=" + r.OrderID.String(),
+			"1=" + r.UserID.String(),
+			"55=" + r.BasketID.String(),
+			"54=" + fixSide(r.Side),
+			"40=" + fixOrderType(r.OrderType),
+			"59=" + fixTimeInForce(r.TimeInForce),
+			"152=" + r.Amount.String(),
+			"150=" + fixExecType(r),
+			"39=" + fixOrdStatus(r.Status),
+			"30=" + r.Venue,
+			"60=" + r.EventTime.UTC().Format(fixTimeFormat),
+		}
+		if r.LimitPrice != nil {
+			fields = append(fields, "44="+r.LimitPrice.String())
+		}
+		if r.BrokerageRef != "" {
+			fields = append(fields, "198="+r.BrokerageRef)
+		}
+		if r.ActorID != nil {
+			fields = append(fields, "523="+r.ActorID.String())
+		}
+		if r.Action != "" {
+			fields = append(fields, "58="+r.Action)
+		}
+		buf.WriteString(strings.Join(fields, delimiter))
+		buf.WriteString(delimiter)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func fixSide(side entities.OrderSide) string {
+	if side == entities.OrderSideSell {
+		return "2"
+	}
+	return "1"
+}
+
+func fixOrderType(orderType entities.OrderType) string {
+	if orderType == entities.OrderTypeLimit {
+		return "2"
+	}
+	return "1"
+}
+
+func fixTimeInForce(tif entities.TimeInForce) string {
+	if tif == entities.TimeInForceGTC {
+		return "1"
+	}
+	return "0"
+}
+
+// fixExecType maps an event to ExecType: new, replaced for amendments, and
+// the status reached otherwise
+func fixExecType(r *entities.BlotterRecord) string {
+	switch r.EventType {
+	case entities.BlotterEventNew:
+		return "0"
+	case entities.BlotterEventAmend:
+		return "5"
+	}
+	switch entities.OrderStatus(r.Status) {
+	case entities.OrderStatusFilled, entities.OrderStatusPartiallyFilled:
+		return "F"
+	case entities.OrderStatusCanceled:
+		return "4"
+	case entities.OrderStatusExpired:
+		return "C"
+	case entities.OrderStatusFailed:
+		return "8"
+	}
+	return "I"
+}
+
+func fixOrdStatus(status string) string {
+	switch entities.OrderStatus(status) {
+	case entities.OrderStatusPartiallyFilled:
+		return "1"
+	case entities.OrderStatusFilled:
+		return "2"
+	case entities.OrderStatusCanceled:
+		return "4"
+	case entities.OrderStatusExpired:
+		return "C"
+	case entities.OrderStatusFailed:
+		return "8"
+	case entities.OrderStatusPending:
+		return "A"
+	}
+	return "0"
+}
@@ -0,0 +1,296 @@
+package blotter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+const dayFormat = "2006-01-02"
+
+// Repository reads order events and stores generated blotters
+type Repository interface {
+	// OrderEvents returns every live order event in [from, to): orders
+	// received, orders reaching their current status and admin amendments,
+	// in event time order
+	OrderEvents(ctx context.Context, from, to time.Time) ([]*entities.BlotterRecord, error)
+	// CreateBlotter stores the blotter as the day's next version, setting its
+	// ID and Version. With firstOnly, it returns ErrBlotterVersionConflict
+	// instead when the day already has a blotter.
+	CreateBlotter(ctx context.Context, blotter *entities.OrderBlotter, firstOnly bool) error
+	// ListBlotters returns stored blotters for days in [from, to] without
+	// their content
+	ListBlotters(ctx context.Context, from, to time.Time) ([]*entities.OrderBlotter, error)
+	// GetBlotter returns a stored blotter with its content
+	GetBlotter(ctx context.Context, id uuid.UUID) (*entities.OrderBlotter, error)
+}
+
+// Config controls how blotters are rendered and when days are generated
+type Config struct {
+	Format       entities.BlotterFormat
+	FIXDelimiter string         // Field separator in FIX output
+	Venue        string         // Venue reported for orders routed to the brokerage
+	Location     *time.Location // Business day boundaries
+	LookbackDays int            // Past days checked for a missing blotter on each run
+	SettleDelay  time.Duration  // Wait after midnight before a day is generated, for late fills
+	MaxRangeDays int            // Longest range one listing may cover
+	Interval     time.Duration
+}
+
+// DefaultConfig generates a CSV blotter for each New York business day two
+// hours after it ends
+func DefaultConfig() Config {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		location = time.UTC
+	}
+	return Config{
+		Format:       entities.BlotterFormatCSV,
+		FIXDelimiter: "|",
+		Venue:        "ALPACA",
+		Location:     location,
+		LookbackDays: 7,
+		SettleDelay:  2 * time.Hour,
+		MaxRangeDays: 92,
+		Interval:     time.Hour,
+	}
+}
+
+// Service produces the daily order blotter of record for regulatory
+// reporting: every live order received, every status it reached and every
+// admin amendment, with timestamps and venue. Each business day is rendered
+// once by the worker and stored immutably; generating a day again stores a
+// new version alongside the earlier ones.
+type Service struct {
+	repo    Repository
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+	now     func() time.Time
+}
+
+// NewService creates a new order blotter service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if !config.Format.IsValid() {
+		config.Format = defaults.Format
+	}
+	if config.FIXDelimiter == "" {
+		config.FIXDelimiter = defaults.FIXDelimiter
+	}
+	if config.Venue == "" {
+		config.Venue = defaults.Venue
+	}
+	if config.Location == nil {
+		config.Location = defaults.Location
+	}
+	if config.LookbackDays <= 0 {
+		config.LookbackDays = defaults.LookbackDays
+	}
+	if config.SettleDelay < 0 {
+		config.SettleDelay = defaults.SettleDelay
+	}
+	if config.MaxRangeDays <= 0 {
+		config.MaxRangeDays = defaults.MaxRangeDays
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports runs to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often missing days are generated
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// ParseDay parses a YYYY-MM-DD business day
+func (s *Service) ParseDay(value string) (time.Time, error) {
+	day, err := time.ParseInLocation(dayFormat, value, s.config.Location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", entities.ErrInvalidBlotterDateRange, value)
+	}
+	return day, nil
+}
+
+// Start generates settled days that have no blotter on every tick until ctx
+// is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.GeneratePending(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Order blotter run failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// GeneratePending generates each settled day in the lookback window that has
+// no blotter yet, returning how many were stored. A day another instance
+// generated first is skipped.
+func (s *Service) GeneratePending(ctx context.Context) (int, error) {
+	last := s.lastSettledDay()
+	first := last.AddDate(0, 0, 1-s.config.LookbackDays)
+
+	existing, err := s.repo.ListBlotters(ctx, first, last)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list order blotters: %w", err)
+	}
+	done := make(map[string]bool, len(existing))
+	for _, blotter := range existing {
+		done[blotter.BusinessDate.Format(dayFormat)] = true
+	}
+
+	generated := 0
+	var errs []error
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if done[day.Format(dayFormat)] {
+			continue
+		}
+		_, err := s.generate(ctx, day, s.config.Format, "", nil, true)
+		if errors.Is(err, entities.ErrBlotterVersionConflict) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", day.Format(dayFormat), err))
+			continue
+		}
+		generated++
+	}
+	return generated, errors.Join(errs...)
+}
+
+// Generate stores a new version of a settled day's blotter, for example
+// after an order on that day was corrected or in another format. Earlier
+// versions are kept.
+func (s *Service) Generate(ctx context.Context, day time.Time, format entities.BlotterFormat, reason string, adminID uuid.UUID) (*entities.OrderBlotter, error) {
+	if format == "" {
+		format = s.config.Format
+	}
+	if !format.IsValid() {
+		return nil, fmt.Errorf("%w: %q", entities.ErrInvalidBlotterFormat, format)
+	}
+	day = s.startOfDay(day)
+	if day.After(s.lastSettledDay()) {
+		return nil, fmt.Errorf("%w: a day's blotter can be generated once it has ended and settled", entities.ErrInvalidBlotterDateRange)
+	}
+	return s.generate(ctx, day, format, strings.TrimSpace(reason), &adminID, false)
+}
+
+func (s *Service) generate(ctx context.Context, day time.Time, format entities.BlotterFormat, reason string, generatedBy *uuid.UUID, firstOnly bool) (*entities.OrderBlotter, error) {
+	records, err := s.repo.OrderEvents(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read order events: %w", err)
+	}
+	for _, record := range records {
+		record.Venue = "INTERNAL"
+		if record.BrokerageRef != "" {
+			record.Venue = s.config.Venue
+		}
+	}
+
+	content, checksum, err := Render(format, records, s.config.FIXDelimiter)
+	if err != nil {
+		return nil, err
+	}
+	blotter := &entities.OrderBlotter{
+		BusinessDate: day,
+		Format:       format,
+		RecordCount:  len(records),
+		Checksum:     checksum,
+		Content:      content,
+		Reason:       reason,
+		GeneratedBy:  generatedBy,
+		GeneratedAt:  s.now().UTC(),
+	}
+	if err := s.repo.CreateBlotter(ctx, blotter, firstOnly); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Order blotter generated",
+		zap.String("date", day.Format(dayFormat)),
+		zap.Int("version", blotter.Version),
+		zap.String("format", string(format)),
+		zap.Int("records", blotter.RecordCount),
+		zap.String("checksum", checksum))
+	return blotter, nil
+}
+
+// List returns every stored blotter version for days in [from, to]
+func (s *Service) List(ctx context.Context, from, to time.Time) ([]*entities.OrderBlotter, error) {
+	from, to = s.startOfDay(from), s.startOfDay(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from is after to", entities.ErrInvalidBlotterDateRange)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > s.config.MaxRangeDays {
+		return nil, fmt.Errorf("%w: at most %d days per listing", entities.ErrInvalidBlotterDateRange, s.config.MaxRangeDays)
+	}
+	return s.repo.ListBlotters(ctx, from, to)
+}
+
+// Get returns a stored blotter with its content
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.OrderBlotter, error) {
+	return s.repo.GetBlotter(ctx, id)
+}
+
+// FileName is the download name of a stored blotter
+func FileName(blotter *entities.OrderBlotter) string {
+	ext := "csv"
+	if blotter.Format == entities.BlotterFormatFIX {
+		ext = "fix"
+	}
+	return fmt.Sprintf("order-blotter-%s-v%d.%s", blotter.BusinessDate.Format(dayFormat), blotter.Version, ext)
+}
+
+// lastSettledDay returns the most recent business day whose settle delay has
+// passed
+func (s *Service) lastSettledDay() time.Time {
+	today := s.startOfDay(s.now())
+	last := today.AddDate(0, 0, -1)
+	if s.now().Before(today.Add(s.config.SettleDelay)) {
+		last = last.AddDate(0, 0, -1)
+	}
+	return last
+}
+
+func (s *Service) startOfDay(t time.Time) time.Time {
+	t = t.In(s.config.Location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.config.Location)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stack-service/stack_service/internal/adapters/alpaca"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -27,7 +28,7 @@ func NewBrokerageOnboardingService(alpacaService *alpaca.Service, logger *zap.Lo
 func (s *BrokerageOnboardingService) CreateBrokerageAccount(ctx context.Context, user *entities.User, kyc *entities.KYCSubmission) (*entities.AlpacaAccountResponse, error) {
 	s.logger.Info("Creating Alpaca brokerage account",
 		zap.String("user_id", user.ID.String()),
-		zap.String("email", user.Email))
+		logger.Email(user.Email))
 
 	// Extract KYC data from verification_data map
 	verificationData := kyc.VerificationData
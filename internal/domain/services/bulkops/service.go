@@ -0,0 +1,415 @@
+package bulkops
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository persists bulk operations and leases their items to workers
+type Repository interface {
+	// Create stores the operation with one pending item per user
+	Create(ctx context.Context, op *entities.BulkOperation, userIDs []uuid.UUID) error
+	Get(ctx context.Context, id uuid.UUID) (*entities.BulkOperation, error)
+	List(ctx context.Context, limit, offset int) ([]*entities.BulkOperation, error)
+	ListItems(ctx context.Context, operationID uuid.UUID, status entities.BulkItemStatus) ([]*entities.BulkOperationItem, error)
+	// ClaimItems leases up to limit pending items of running operations by
+	// pushing their lease forward, so concurrent workers skip them
+	ClaimItems(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.ClaimedBulkItem, error)
+	// RecordItem stores an item's outcome; a pending item is retried once
+	// retryAt passes
+	RecordItem(ctx context.Context, item *entities.BulkOperationItem, retryAt time.Time) error
+	// Refresh recounts an operation's outcomes and completes it once no item
+	// is pending
+	Refresh(ctx context.Context, id uuid.UUID, now time.Time) (*entities.BulkOperation, error)
+	// Cancel skips an operation's pending items and marks it cancelled
+	Cancel(ctx context.Context, id uuid.UUID, now time.Time) error
+}
+
+// UserStore reads and changes the users an operation applies to
+type UserStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error)
+	SetActiveStatus(ctx context.Context, userID uuid.UUID, active bool, reason entities.AccountClosureReason, notes string) error
+	UpdateKYCStatus(ctx context.Context, userID uuid.UUID, status string, approvedAt *time.Time, rejectionReason *string) error
+}
+
+// VerificationSender sends a fresh email verification code
+type VerificationSender interface {
+	GenerateAndSendCode(ctx context.Context, identifierType, identifier string) (string, error)
+}
+
+// Mailer tells users their identity must be verified again
+type Mailer interface {
+	SendCustomEmail(ctx context.Context, to, subject, htmlContent, textContent string) error
+}
+
+// Config holds the size limits and worker pacing
+type Config struct {
+	MaxUsers     int           // Users one operation may name
+	BatchSize    int           // Items claimed per poll
+	MaxAttempts  int           // Tries before an item is recorded as failed
+	Lease        time.Duration // How long a claimed item is hidden from other workers
+	RetryBackoff time.Duration // Delay before a failed attempt is retried
+	Interval     time.Duration
+}
+
+// DefaultConfig allows 5,000 users per operation and works 50 items every
+// five seconds
+func DefaultConfig() Config {
+	return Config{
+		MaxUsers:     5000,
+		BatchSize:    50,
+		MaxAttempts:  3,
+		Lease:        5 * time.Minute,
+		RetryBackoff: time.Minute,
+		Interval:     5 * time.Second,
+	}
+}
+
+// Service runs admin actions over lists of users. An operation is stored with
+// one item per user and worked in the background by Start, so large cohorts
+// survive restarts and every user gets an outcome that can be downloaded.
+type Service struct {
+	repo         Repository
+	users        UserStore
+	verification VerificationSender
+	mailer       Mailer
+	config       Config
+	logger       *zap.Logger
+	tracker      *workerstatus.Tracker
+	now          func() time.Time
+}
+
+// NewService creates a new bulk operations service
+func NewService(repo Repository, users UserStore, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxUsers <= 0 {
+		config.MaxUsers = defaults.MaxUsers
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:   repo,
+		users:  users,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetVerificationSender enables resend_verification_email operations
+func (s *Service) SetVerificationSender(verification VerificationSender) {
+	s.verification = verification
+}
+
+// SetMailer lets kyc_recheck operations email the affected users
+func (s *Service) SetMailer(mailer Mailer) {
+	s.mailer = mailer
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports polls to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often pending items are polled
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Create validates and stores an operation; its items are worked in the
+// background
+func (s *Service) Create(ctx context.Context, req *entities.CreateBulkOperationRequest, requestedBy uuid.UUID) (*entities.BulkOperation, error) {
+	if !req.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown type %q", entities.ErrInvalidBulkOperation, req.Type)
+	}
+	if req.Type == entities.BulkResendVerification && s.verification == nil {
+		return nil, fmt.Errorf("%w: email verification is not configured", entities.ErrInvalidBulkOperation)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.UserIDs))
+	userIDs := make([]uuid.UUID, 0, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		userIDs = append(userIDs, id)
+	}
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one user is required", entities.ErrInvalidBulkOperation)
+	}
+	if len(userIDs) > s.config.MaxUsers {
+		return nil, fmt.Errorf("%w: at most %d users per operation", entities.ErrInvalidBulkOperation, s.config.MaxUsers)
+	}
+
+	op := &entities.BulkOperation{
+		ID:          uuid.New(),
+		Type:        req.Type,
+		Status:      entities.BulkOperationRunning,
+		Reason:      req.Reason,
+		RequestedBy: requestedBy,
+		Total:       len(userIDs),
+		CreatedAt:   s.now().UTC(),
+	}
+	if err := s.repo.Create(ctx, op, userIDs); err != nil {
+		return nil, fmt.Errorf("failed to create bulk operation: %w", err)
+	}
+
+	s.logger.Info("Bulk operation created",
+		zap.String("operation_id", op.ID.String()),
+		zap.String("type", string(op.Type)),
+		zap.Int("users", op.Total),
+		zap.String("requested_by", requestedBy.String()))
+	return op, nil
+}
+
+// Get returns an operation with its outcome counts
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.BulkOperation, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List returns recent operations, newest first
+func (s *Service) List(ctx context.Context, limit, offset int) ([]*entities.BulkOperation, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, limit, offset)
+}
+
+// Items returns an operation's per-user outcomes, optionally filtered by status
+func (s *Service) Items(ctx context.Context, id uuid.UUID, status entities.BulkItemStatus) ([]*entities.BulkOperationItem, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListItems(ctx, id, status)
+}
+
+// Cancel skips the operation's remaining users. Users already processed keep
+// their outcome.
+func (s *Service) Cancel(ctx context.Context, id uuid.UUID) (*entities.BulkOperation, error) {
+	op, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if op.Status != entities.BulkOperationRunning {
+		return op, nil
+	}
+	if err := s.repo.Cancel(ctx, id, s.now().UTC()); err != nil {
+		return nil, fmt.Errorf("failed to cancel bulk operation: %w", err)
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// WriteResultsCSV writes one row per user with the outcome of the operation
+func (s *Service) WriteResultsCSV(ctx context.Context, id uuid.UUID, w io.Writer) error {
+	items, err := s.Items(ctx, id, "")
+	if err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"user_id", "status", "message", "attempts", "processed_at"}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		processedAt := ""
+		if item.ProcessedAt != nil {
+			processedAt = item.ProcessedAt.UTC().Format(time.RFC3339)
+		}
+		if err := out.Write([]string{
+			item.UserID.String(),
+			string(item.Status),
+			item.Message,
+			strconv.Itoa(item.Attempts),
+			processedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// Start works pending items on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.ProcessBatch(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Bulk operation batch failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// ProcessBatch claims and works one batch of items, returning how many were
+// claimed. Operations touched by the batch are completed once their last
+// item has an outcome.
+func (s *Service) ProcessBatch(ctx context.Context) (int, error) {
+	items, err := s.repo.ClaimItems(ctx, s.now().UTC(), s.config.Lease, s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim bulk operation items: %w", err)
+	}
+
+	touched := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		touched[item.OperationID] = true
+		s.process(ctx, item)
+	}
+
+	for id := range touched {
+		op, err := s.repo.Refresh(ctx, id, s.now().UTC())
+		if err != nil {
+			s.logger.Warn("Failed to refresh bulk operation", zap.String("operation_id", id.String()), zap.Error(err))
+			continue
+		}
+		if op.Status == entities.BulkOperationCompleted {
+			s.logger.Info("Bulk operation completed",
+				zap.String("operation_id", op.ID.String()),
+				zap.String("type", string(op.Type)),
+				zap.Int("succeeded", op.Succeeded),
+				zap.Int("skipped", op.Skipped),
+				zap.Int("failed", op.Failed))
+		}
+	}
+	return len(items), nil
+}
+
+func (s *Service) process(ctx context.Context, claimed *entities.ClaimedBulkItem) {
+	item := &claimed.BulkOperationItem
+	item.Attempts++
+
+	status, message, err := s.apply(ctx, claimed)
+	now := s.now().UTC()
+	retryAt := now
+	switch {
+	case err == nil:
+		item.Status = status
+		item.Message = message
+		item.ProcessedAt = &now
+	case item.Attempts >= s.config.MaxAttempts:
+		item.Status = entities.BulkItemFailed
+		item.Message = err.Error()
+		item.ProcessedAt = &now
+	default:
+		// Leave it pending for another worker pass after the backoff
+		item.Message = err.Error()
+		retryAt = now.Add(s.config.RetryBackoff)
+	}
+
+	if err := s.repo.RecordItem(ctx, item, retryAt); err != nil {
+		s.logger.Error("Failed to record bulk operation item",
+			zap.String("operation_id", item.OperationID.String()),
+			zap.String("user_id", item.UserID.String()),
+			zap.Error(err))
+	}
+}
+
+// apply runs the operation for one user. An error is retried; a skip or a
+// permanent failure is returned as a status with a message.
+func (s *Service) apply(ctx context.Context, item *entities.ClaimedBulkItem) (entities.BulkItemStatus, string, error) {
+	user, err := s.users.GetByID(ctx, item.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return entities.BulkItemFailed, "user not found", nil
+		}
+		return "", "", err
+	}
+
+	switch item.Type {
+	case entities.BulkDeactivateUsers:
+		if !user.IsActive {
+			return entities.BulkItemSkipped, "already inactive", nil
+		}
+		if err := s.users.SetActiveStatus(ctx, user.ID, false, entities.ClosureAdmin, item.Reason); err != nil {
+			return "", "", err
+		}
+		return entities.BulkItemSucceeded, "deactivated", nil
+
+	case entities.BulkReactivateUsers:
+		if user.IsActive {
+			return entities.BulkItemSkipped, "already active", nil
+		}
+		if err := s.users.SetActiveStatus(ctx, user.ID, true, "", ""); err != nil {
+			return "", "", err
+		}
+		return entities.BulkItemSucceeded, "reactivated", nil
+
+	case entities.BulkKYCRecheck:
+		if user.KYCStatus != string(entities.KYCStatusApproved) {
+			return entities.BulkItemSkipped, fmt.Sprintf("kyc is %s, not approved", user.KYCStatus), nil
+		}
+		if err := s.users.UpdateKYCStatus(ctx, user.ID, string(entities.KYCStatusExpired), nil, nil); err != nil {
+			return "", "", err
+		}
+		if s.mailer != nil && user.IsActive {
+			if err := s.mailer.SendCustomEmail(ctx, user.Email, "Please verify your identity again",
+				"<p>To keep your account in good standing we need you to verify your identity again. Open the Stack app to get started.</p>",
+				"To keep your account in good standing we need you to verify your identity again. Open the Stack app to get started."); err != nil {
+				// The status change stands; only the heads-up was lost
+				return entities.BulkItemSucceeded, "kyc expired; notification email failed", nil
+			}
+		}
+		return entities.BulkItemSucceeded, "kyc expired", nil
+
+	case entities.BulkResendVerification:
+		if user.EmailVerified {
+			return entities.BulkItemSkipped, "email already verified", nil
+		}
+		if !user.IsActive {
+			return entities.BulkItemSkipped, "user is inactive", nil
+		}
+		if s.verification == nil {
+			return entities.BulkItemFailed, "email verification is not configured", nil
+		}
+		if _, err := s.verification.GenerateAndSendCode(ctx, "email", user.Email); err != nil {
+			return "", "", err
+		}
+		return entities.BulkItemSucceeded, "verification email sent", nil
+	}
+	return entities.BulkItemFailed, fmt.Sprintf("unknown operation type %q", item.Type), nil
+}
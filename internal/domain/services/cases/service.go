@@ -0,0 +1,115 @@
+package cases
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Repository persists admin cases
+type Repository interface {
+	Open(ctx context.Context, c *entities.AdminCase) (*entities.AdminCase, bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.AdminCase, error)
+	List(ctx context.Context, status entities.AdminCaseStatus, caseType entities.AdminCaseType, limit, offset int) ([]*entities.AdminCase, error)
+	Update(ctx context.Context, c *entities.AdminCase) error
+}
+
+// Service manages the admin review queue. Other services open cases when an
+// account needs a human decision, e.g. before dormant funds are escheated.
+type Service struct {
+	repo   Repository
+	logger *zap.Logger
+}
+
+// NewService creates a new admin case service
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Open opens a case for a user, or returns the unresolved case of the same type
+// if one already exists
+func (s *Service) Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error) {
+	now := time.Now()
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	c, created, err := s.repo.Open(ctx, &entities.AdminCase{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CaseType:  caseType,
+		Status:    entities.AdminCaseOpen,
+		Summary:   summary,
+		Details:   details,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.logger.Info("Admin case opened",
+			zap.String("case_id", c.ID.String()),
+			zap.String("case_type", string(caseType)),
+			zap.String("user_id", userID.String()))
+	}
+	return c, nil
+}
+
+// Get returns a case
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.AdminCase, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns cases filtered by optional status and type
+func (s *Service) List(ctx context.Context, status entities.AdminCaseStatus, caseType entities.AdminCaseType, limit, offset int) ([]*entities.AdminCase, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, caseType, limit, offset)
+}
+
+// Update changes a case's status and assignment. Resolving a case records the
+// resolving admin and time.
+func (s *Service) Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.Status = req.Status
+	if req.AssignedTo != nil {
+		c.AssignedTo = req.AssignedTo
+	}
+	if req.ResolutionNotes != nil {
+		c.ResolutionNotes = req.ResolutionNotes
+	}
+	if req.Status == entities.AdminCaseResolved {
+		c.ResolvedBy = &adminID
+		c.ResolvedAt = &now
+	} else {
+		c.ResolvedBy = nil
+		c.ResolvedAt = nil
+	}
+	c.UpdatedAt = now
+
+	if err := s.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Admin case updated",
+		zap.String("case_id", c.ID.String()),
+		zap.String("status", string(c.Status)),
+		zap.String("admin_id", adminID.String()))
+	return c, nil
+}
@@ -0,0 +1,308 @@
+package circlesubscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/health"
+	"github.com/stack-service/stack_service/pkg/webhook"
+)
+
+// ErrNotConfigured is returned when no notification callback URL is configured
+var ErrNotConfigured = errors.New("circle notification callback url is not configured")
+
+// Client is the subset of the Circle API used to manage notifications
+type Client interface {
+	CreateNotificationSubscription(ctx context.Context, req entities.CircleCreateSubscriptionRequest) (*entities.CircleNotificationSubscription, error)
+	ListNotificationSubscriptions(ctx context.Context) ([]entities.CircleNotificationSubscription, error)
+	DeleteNotificationSubscription(ctx context.Context, subscriptionID string) error
+	GetNotificationPublicKey(ctx context.Context, keyID string) (*entities.CircleNotificationPublicKey, error)
+}
+
+// Repository records the subscriptions this service created
+type Repository interface {
+	List(ctx context.Context) ([]*entities.CircleSubscriptionRegistration, error)
+	Save(ctx context.Context, reg *entities.CircleSubscriptionRegistration) error
+	Delete(ctx context.Context, subscriptionID string) error
+}
+
+// Config controls subscription management
+type Config struct {
+	CallbackURL       string
+	NotificationTypes []string
+	CheckInterval     time.Duration
+}
+
+// Service keeps a Circle notification subscription pointed at the configured
+// callback URL, mirroring what DueService.CreateWebhookEndpoint does for Due,
+// and verifies notification signatures against Circle's published keys
+type Service struct {
+	client Client
+	repo   Repository
+	config Config
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	status *entities.CircleSubscriptionStatus
+	keys   map[string]string
+}
+
+// NewService creates a new Circle subscription service
+func NewService(client Client, repo Repository, config Config, logger *zap.Logger) *Service {
+	config.CallbackURL = strings.TrimSpace(config.CallbackURL)
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = 15 * time.Minute
+	}
+	return &Service{
+		client: client,
+		repo:   repo,
+		config: config,
+		logger: logger,
+		keys:   make(map[string]string),
+	}
+}
+
+// Enabled reports whether a callback URL is configured
+func (s *Service) Enabled() bool {
+	return s.config.CallbackURL != ""
+}
+
+// Sync makes sure an enabled subscription exists for the configured callback
+// URL, creating one if needed, and deletes subscriptions this service created
+// for a previous callback URL
+func (s *Service) Sync(ctx context.Context) (*entities.CircleSubscriptionStatus, error) {
+	if !s.Enabled() {
+		return nil, ErrNotConfigured
+	}
+
+	subscription, err := s.ensureSubscription(ctx)
+	if err != nil {
+		s.setStatus("", false, err.Error())
+		return s.Status(), err
+	}
+
+	if err := s.retireStale(ctx, subscription.ID); err != nil {
+		s.logger.Warn("Failed to remove stale Circle subscriptions", zap.Error(err))
+	}
+
+	s.setStatus(subscription.ID, true, "subscription enabled")
+	return s.Status(), nil
+}
+
+// Start verifies the subscription now and then on every check interval
+func (s *Service) Start(ctx context.Context) {
+	if !s.Enabled() {
+		s.logger.Info("Circle notification callback URL not configured; subscription management disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.config.CheckInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.Sync(ctx); err != nil {
+				s.logger.Error("Circle subscription check failed", zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Status returns the outcome of the latest check, or nil before the first one
+func (s *Service) Status() *entities.CircleSubscriptionStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.status == nil {
+		return nil
+	}
+	status := *s.status
+	return &status
+}
+
+// List returns every subscription registered on the Circle account
+func (s *Service) List(ctx context.Context) ([]entities.CircleNotificationSubscription, error) {
+	return s.client.ListNotificationSubscriptions(ctx)
+}
+
+// Delete removes a subscription from Circle. Deleting the active subscription
+// leaves the service unsubscribed until the next Sync.
+func (s *Service) Delete(ctx context.Context, subscriptionID string) error {
+	if err := s.client.DeleteNotificationSubscription(ctx, subscriptionID); err != nil && !isNotFound(err) {
+		return err
+	}
+	if err := s.repo.Delete(ctx, subscriptionID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.status != nil && s.status.SubscriptionID == subscriptionID {
+		s.status.SubscriptionID = ""
+		s.status.Healthy = false
+		s.status.Message = "subscription deleted"
+		s.status.CheckedAt = time.Now().UTC()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// VerifySignature checks a notification body against the Circle key it names.
+// Keys are fetched once and cached; Circle rotates by issuing new key IDs.
+func (s *Service) VerifySignature(ctx context.Context, keyID, signature string, body []byte) error {
+	if keyID == "" {
+		return fmt.Errorf("missing key id")
+	}
+
+	s.mu.RLock()
+	publicKey, ok := s.keys[keyID]
+	s.mu.RUnlock()
+
+	if !ok {
+		key, err := s.client.GetNotificationPublicKey(ctx, keyID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch circle signing key: %w", err)
+		}
+		publicKey = key.PublicKey
+		s.mu.Lock()
+		s.keys[keyID] = publicKey
+		s.mu.Unlock()
+	}
+
+	return webhook.ValidateCircleSignature(body, signature, publicKey)
+}
+
+// Name identifies the readiness check
+func (s *Service) Name() string {
+	return "circle_notifications"
+}
+
+// Check reports the latest subscription check without calling Circle. A
+// missing or disabled subscription is degraded rather than unhealthy: the API
+// still serves traffic, but deposit notifications are not arriving.
+func (s *Service) Check(ctx context.Context) health.CheckResult {
+	if !s.Enabled() {
+		return health.NewHealthyResult(s.Name(), "subscription management disabled")
+	}
+	status := s.Status()
+	if status == nil {
+		return health.NewDegradedResult(s.Name(), "subscription not checked yet")
+	}
+	result := health.NewHealthyResult(s.Name(), status.Message)
+	if !status.Healthy {
+		result = health.NewDegradedResult(s.Name(), status.Message)
+	}
+	return result.
+		WithMetadata("subscription_id", status.SubscriptionID).
+		WithMetadata("checked_at", status.CheckedAt)
+}
+
+func (s *Service) ensureSubscription(ctx context.Context) (*entities.CircleNotificationSubscription, error) {
+	subscriptions, err := s.client.ListNotificationSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list circle subscriptions: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !sameEndpoint(subscription.Endpoint, s.config.CallbackURL) {
+			continue
+		}
+		if !subscription.Enabled {
+			// Circle disables a subscription after repeated delivery failures;
+			// replacing it is the only way to turn delivery back on
+			s.logger.Warn("Circle subscription disabled; re-registering",
+				zap.String("subscription_id", subscription.ID))
+			if err := s.client.DeleteNotificationSubscription(ctx, subscription.ID); err != nil && !isNotFound(err) {
+				return nil, fmt.Errorf("failed to delete disabled subscription: %w", err)
+			}
+			_ = s.repo.Delete(ctx, subscription.ID)
+			continue
+		}
+
+		if err := s.repo.Save(ctx, &entities.CircleSubscriptionRegistration{
+			SubscriptionID: subscription.ID,
+			Endpoint:       s.config.CallbackURL,
+			CreatedAt:      subscription.CreateDate,
+			VerifiedAt:     &now,
+		}); err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	}
+
+	subscription, err := s.client.CreateNotificationSubscription(ctx, entities.CircleCreateSubscriptionRequest{
+		Endpoint:          s.config.CallbackURL,
+		NotificationTypes: s.config.NotificationTypes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register circle subscription: %w", err)
+	}
+	if err := s.repo.Save(ctx, &entities.CircleSubscriptionRegistration{
+		SubscriptionID: subscription.ID,
+		Endpoint:       s.config.CallbackURL,
+		CreatedAt:      now,
+		VerifiedAt:     &now,
+	}); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Registered Circle notification subscription",
+		zap.String("subscription_id", subscription.ID),
+		zap.String("endpoint", s.config.CallbackURL))
+	return subscription, nil
+}
+
+// retireStale deletes subscriptions this service created for other callback URLs
+func (s *Service) retireStale(ctx context.Context, activeID string) error {
+	registrations, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, reg := range registrations {
+		if reg.SubscriptionID == activeID || sameEndpoint(reg.Endpoint, s.config.CallbackURL) {
+			continue
+		}
+		if err := s.client.DeleteNotificationSubscription(ctx, reg.SubscriptionID); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete subscription %s: %w", reg.SubscriptionID, err)
+		}
+		if err := s.repo.Delete(ctx, reg.SubscriptionID); err != nil {
+			return err
+		}
+		s.logger.Info("Removed Circle subscription for previous callback URL",
+			zap.String("subscription_id", reg.SubscriptionID),
+			zap.String("endpoint", reg.Endpoint))
+	}
+	return nil
+}
+
+func (s *Service) setStatus(subscriptionID string, healthy bool, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = &entities.CircleSubscriptionStatus{
+		CallbackURL:    s.config.CallbackURL,
+		SubscriptionID: subscriptionID,
+		Healthy:        healthy,
+		Message:        message,
+		CheckedAt:      time.Now().UTC(),
+	}
+}
+
+func sameEndpoint(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+func isNotFound(err error) bool {
+	var apiErr entities.CircleAPIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
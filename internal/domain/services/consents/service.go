@@ -0,0 +1,273 @@
+package consents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// defaultCacheTTL bounds how long a newly published version takes to reach every instance
+const defaultCacheTTL = time.Minute
+
+// Repository persists agreement versions and the versions users accepted
+type Repository interface {
+	ListVersions(ctx context.Context) ([]*entities.AgreementVersion, error)
+	CreateVersion(ctx context.Context, version *entities.AgreementVersion) error
+	// LatestConsents returns the user's most recent acceptance of each agreement type
+	LatestConsents(ctx context.Context, userID uuid.UUID) ([]*entities.UserConsent, error)
+	RecordConsent(ctx context.Context, consent *entities.UserConsent) error
+}
+
+// Service tracks which agreement versions each user accepted. Published
+// versions are cached in memory since every trading request checks them.
+type Service struct {
+	repo     Repository
+	logger   *zap.Logger
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	versions map[entities.AgreementType][]*entities.AgreementVersion
+	loadedAt time.Time
+}
+
+// NewService creates a new consents service
+func NewService(repo Repository, cacheTTL time.Duration, logger *zap.Logger) *Service {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	return &Service{
+		repo:     repo,
+		logger:   logger,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Publish adds a new version of an agreement. It becomes the current version
+// straight away; a mandatory one blocks trading for users until they accept it.
+func (s *Service) Publish(ctx context.Context, req *entities.PublishAgreementRequest, adminID uuid.UUID) (*entities.AgreementVersion, error) {
+	if !req.AgreementType.IsValid() {
+		return nil, fmt.Errorf("%w: unknown agreement type %q", entities.ErrInvalidAgreement, req.AgreementType)
+	}
+	version := strings.TrimSpace(req.Version)
+	if version == "" {
+		return nil, fmt.Errorf("%w: version is required", entities.ErrInvalidAgreement)
+	}
+
+	agreement := &entities.AgreementVersion{
+		ID:            uuid.New(),
+		AgreementType: req.AgreementType,
+		Version:       version,
+		Title:         strings.TrimSpace(req.Title),
+		DocumentURL:   strings.TrimSpace(req.DocumentURL),
+		Mandatory:     req.Mandatory,
+		PublishedBy:   &adminID,
+		PublishedAt:   s.now().UTC(),
+	}
+	if err := s.repo.CreateVersion(ctx, agreement); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	s.logger.Info("Published agreement version",
+		zap.String("agreement_type", string(agreement.AgreementType)),
+		zap.String("version", agreement.Version),
+		zap.Bool("mandatory", agreement.Mandatory),
+		zap.String("admin_id", adminID.String()))
+	return agreement, nil
+}
+
+// ListVersions returns every published version, oldest first, optionally for
+// one agreement type
+func (s *Service) ListVersions(ctx context.Context, agreementType entities.AgreementType) ([]*entities.AgreementVersion, error) {
+	versions, err := s.loadVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*entities.AgreementVersion{}
+	for _, known := range entities.KnownAgreementTypes {
+		if agreementType == "" || agreementType == known {
+			result = append(result, versions[known]...)
+		}
+	}
+	return result, nil
+}
+
+// Status returns the user's standing on each agreement that has a published version
+func (s *Service) Status(ctx context.Context, userID uuid.UUID) ([]*entities.ConsentStatus, error) {
+	versions, err := s.loadVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	consents, err := s.repo.LatestConsents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user consents: %w", err)
+	}
+	accepted := make(map[entities.AgreementType]*entities.UserConsent, len(consents))
+	for _, consent := range consents {
+		accepted[consent.AgreementType] = consent
+	}
+
+	statuses := []*entities.ConsentStatus{}
+	for _, agreementType := range entities.KnownAgreementTypes {
+		history := versions[agreementType]
+		if len(history) == 0 {
+			continue
+		}
+		consent := accepted[agreementType]
+		statuses = append(statuses, &entities.ConsentStatus{
+			Current:            history[len(history)-1],
+			Accepted:           consent,
+			RequiresAcceptance: requiresAcceptance(history, consent),
+		})
+	}
+	return statuses, nil
+}
+
+// PendingMandatory returns the current version of each agreement the user
+// must accept before trading
+func (s *Service) PendingMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.AgreementVersion, error) {
+	statuses, err := s.Status(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*entities.AgreementVersion
+	for _, status := range statuses {
+		if status.RequiresAcceptance {
+			pending = append(pending, status.Current)
+		}
+	}
+	return pending, nil
+}
+
+// Accept records the user accepting an agreement version. Only the current
+// version of an agreement can be accepted; accepting it again is a no-op.
+func (s *Service) Accept(ctx context.Context, userID, versionID uuid.UUID, ipAddress, userAgent string) (*entities.UserConsent, error) {
+	version, current, err := s.findVersion(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if !current {
+		return nil, entities.ErrAgreementSuperseded
+	}
+
+	consent := &entities.UserConsent{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		AgreementVersionID: version.ID,
+		AgreementType:      version.AgreementType,
+		Version:            version.Version,
+		IPAddress:          optionalString(ipAddress),
+		UserAgent:          optionalString(userAgent),
+		AcceptedAt:         s.now().UTC(),
+	}
+	if err := s.repo.RecordConsent(ctx, consent); err != nil {
+		return nil, fmt.Errorf("failed to record consent: %w", err)
+	}
+
+	s.logger.Info("User accepted agreement",
+		zap.String("user_id", userID.String()),
+		zap.String("agreement_type", string(version.AgreementType)),
+		zap.String("version", version.Version))
+	return consent, nil
+}
+
+// requiresAcceptance reports whether a mandatory version was published after
+// the one the user last accepted. history is ordered oldest first.
+func requiresAcceptance(history []*entities.AgreementVersion, consent *entities.UserConsent) bool {
+	acceptedAt := -1
+	mandatoryAt := -1
+	for i, version := range history {
+		if version.Mandatory {
+			mandatoryAt = i
+		}
+		if consent != nil && version.ID == consent.AgreementVersionID {
+			acceptedAt = i
+		}
+	}
+	return mandatoryAt > acceptedAt
+}
+
+// findVersion looks a version up in the cache, reloading once in case it was
+// published on another instance, and reports whether it is the current one
+func (s *Service) findVersion(ctx context.Context, versionID uuid.UUID) (*entities.AgreementVersion, bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			s.invalidate()
+		}
+		versions, err := s.loadVersions(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, history := range versions {
+			for i, version := range history {
+				if version.ID == versionID {
+					return version, i == len(history)-1, nil
+				}
+			}
+		}
+	}
+	return nil, false, entities.ErrAgreementNotFound
+}
+
+func (s *Service) loadVersions(ctx context.Context) (map[entities.AgreementType][]*entities.AgreementVersion, error) {
+	s.mu.RLock()
+	if s.versions != nil && time.Since(s.loadedAt) < s.cacheTTL {
+		versions := s.versions
+		s.mu.RUnlock()
+		return versions, nil
+	}
+	s.mu.RUnlock()
+
+	list, err := s.repo.ListVersions(ctx)
+	if err != nil {
+		s.mu.RLock()
+		stale := s.versions
+		s.mu.RUnlock()
+		if stale != nil {
+			s.logger.Warn("Failed to refresh agreement versions, serving cached copy", zap.Error(err))
+			return stale, nil
+		}
+		return nil, fmt.Errorf("failed to load agreement versions: %w", err)
+	}
+
+	versions := make(map[entities.AgreementType][]*entities.AgreementVersion)
+	for _, version := range list {
+		versions[version.AgreementType] = append(versions[version.AgreementType], version)
+	}
+
+	s.mu.Lock()
+	s.versions = versions
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return versions, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,222 @@
+package costbasis
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Column names brokers use, compared with case, spaces and punctuation
+// removed. A total cost column is preferred to a per-share one.
+var (
+	symbolColumns    = []string{"symbol", "ticker", "securitysymbol"}
+	quantityColumns  = []string{"quantity", "qty", "shares", "sharesquantity"}
+	totalCostColumns = []string{"costbasis", "totalcost", "costbasistotal", "totalcostbasis", "cost"}
+	unitCostColumns  = []string{"costpershare", "unitcost", "costbasispershare", "averagecost", "avgcost", "pricepaid", "purchaseprice"}
+	acquiredColumns  = []string{"dateacquired", "acquired", "acquireddate", "acquisitiondate", "opendate", "purchasedate", "tradedate"}
+)
+
+// Exports often start with a few lines describing the account
+const maxPreambleLines = 10
+
+var (
+	symbolPattern = regexp.MustCompile(`^[A-Z][A-Z0-9.]{0,9}$`)
+	dateLayouts   = []string{"2006-01-02", "01/02/2006", "1/2/2006", "01/02/06", "1/2/06", "2006/01/02", "Jan 2, 2006", "Jan 02 2006"}
+	earliestDate  = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type columns struct {
+	symbol, quantity, totalCost, unitCost, acquired int
+}
+
+// parseCSV reads one lot per row of a broker's cost basis export. Rows that
+// cannot be read are reported rather than failing the file; the error is
+// only for a file that is not a usable export at all.
+func parseCSV(r io.Reader, maxRows int, today time.Time) ([]entities.ImportedLot, []entities.CostBasisRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var cols *columns
+	for line := 0; cols == nil; line++ {
+		if line == maxPreambleLines {
+			return nil, nil, fmt.Errorf("%w: no header row with symbol, quantity, cost and acquired date columns", ErrInvalidFile)
+		}
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil, fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		cols = matchHeader(record)
+	}
+
+	var lots []entities.ImportedLot
+	var rowErrors []entities.CostBasisRowError
+	for rows := 0; ; {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+			}
+			rowErrors = append(rowErrors, entities.CostBasisRowError{Row: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		row, _ := reader.FieldPos(0)
+		if summaryRow(record, cols) {
+			continue
+		}
+		if rows++; rows > maxRows {
+			return nil, nil, fmt.Errorf("%w: more than %d lots", ErrInvalidFile, maxRows)
+		}
+
+		lot, err := parseRow(record, cols, today)
+		if err != nil {
+			rowErrors = append(rowErrors, entities.CostBasisRowError{Row: row, Message: err.Error()})
+			continue
+		}
+		lot.Row = row
+		lots = append(lots, lot)
+	}
+	return lots, rowErrors, nil
+}
+
+func matchHeader(record []string) *columns {
+	index := make(map[string]int, len(record))
+	for i, name := range record {
+		key := normalizeColumn(name)
+		if _, seen := index[key]; !seen {
+			index[key] = i
+		}
+	}
+	find := func(names []string) int {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	cols := &columns{
+		symbol:    find(symbolColumns),
+		quantity:  find(quantityColumns),
+		totalCost: find(totalCostColumns),
+		unitCost:  find(unitCostColumns),
+		acquired:  find(acquiredColumns),
+	}
+	if cols.symbol < 0 || cols.quantity < 0 || cols.acquired < 0 || (cols.totalCost < 0 && cols.unitCost < 0) {
+		return nil
+	}
+	return cols
+}
+
+func normalizeColumn(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// summaryRow reports whether the row has no lot in it: blank lines and the
+// section headings and totals brokers put between lots
+func summaryRow(record []string, cols *columns) bool {
+	return field(record, cols.symbol) == "" ||
+		field(record, cols.quantity) == "" && field(record, cols.acquired) == ""
+}
+
+func parseRow(record []string, cols *columns, today time.Time) (entities.ImportedLot, error) {
+	var lot entities.ImportedLot
+
+	lot.Symbol = strings.NewReplacer("/", ".", "-", ".", " ", "").Replace(strings.ToUpper(field(record, cols.symbol)))
+	if !symbolPattern.MatchString(lot.Symbol) {
+		return lot, fmt.Errorf("%q is not a symbol", field(record, cols.symbol))
+	}
+
+	quantity, err := parseAmount(field(record, cols.quantity))
+	if err != nil {
+		return lot, fmt.Errorf("quantity: %w", err)
+	}
+	if !quantity.IsPositive() {
+		return lot, errors.New("quantity must be positive")
+	}
+	lot.Quantity = quantity
+
+	if raw := field(record, cols.totalCost); raw != "" {
+		lot.CostBasis, err = parseAmount(raw)
+		if err != nil {
+			return lot, fmt.Errorf("cost basis: %w", err)
+		}
+	} else {
+		unitCost, err := parseAmount(field(record, cols.unitCost))
+		if err != nil {
+			return lot, fmt.Errorf("cost per share: %w", err)
+		}
+		lot.CostBasis = unitCost.Mul(quantity).Round(2)
+	}
+	if lot.CostBasis.IsNegative() {
+		return lot, errors.New("cost basis cannot be negative")
+	}
+
+	lot.AcquiredAt, err = parseDate(field(record, cols.acquired))
+	if err != nil {
+		return lot, err
+	}
+	if lot.AcquiredAt.After(today) || lot.AcquiredAt.Before(earliestDate) {
+		return lot, fmt.Errorf("acquired date %s is out of range", lot.AcquiredAt.Format("2006-01-02"))
+	}
+	return lot, nil
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseAmount reads a number as brokers format it, with currency symbols,
+// thousands separators and parentheses for negatives
+func parseAmount(raw string) (decimal.Decimal, error) {
+	cleaned := strings.NewReplacer("$", "", ",", "", " ", "").Replace(raw)
+	if strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")") {
+		cleaned = "-" + strings.Trim(cleaned, "()")
+	}
+	if cleaned == "" || cleaned == "--" {
+		return decimal.Zero, errors.New("missing")
+	}
+	value, err := decimal.NewFromString(cleaned)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%q is not a number", raw)
+	}
+	return value, nil
+}
+
+func parseDate(raw string) (time.Time, error) {
+	if strings.EqualFold(raw, "various") {
+		return time.Time{}, errors.New("acquired date is \"Various\"; export lot details so each lot has its own date")
+	}
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an acquired date", raw)
+}
@@ -0,0 +1,281 @@
+package costbasis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrInvalidFile is returned for an upload that is not a CSV export
+	// with the columns lots are read from
+	ErrInvalidFile = errors.New("invalid cost basis file")
+	// ErrImportHasErrors is returned when confirming an import with rows
+	// that could not be read. The corrected file is imported again instead,
+	// so no lot is silently left out.
+	ErrImportHasErrors = errors.New("cost basis import has rows with errors")
+	// ErrNoLots is returned when confirming an import without any lots
+	ErrNoLots = errors.New("cost basis import has no lots")
+	// ErrImportNotPending is returned when confirming or cancelling an
+	// import that was already confirmed, cancelled or has expired
+	ErrImportNotPending = errors.New("cost basis import is no longer awaiting review")
+	// ErrInvalidAdjustment is returned for a tax lot correction that would
+	// leave the lot invalid
+	ErrInvalidAdjustment = errors.New("invalid tax lot adjustment")
+)
+
+// Repository stores imports awaiting review and the tax lots they record
+type Repository interface {
+	CreateImport(ctx context.Context, imp *entities.CostBasisImport) error
+	GetImport(ctx context.Context, userID, id uuid.UUID) (*entities.CostBasisImport, error)
+	// ConfirmImport records the lots and marks the import confirmed, in one
+	// transaction, if it is still pending review before at. It returns false
+	// when it is not.
+	ConfirmImport(ctx context.Context, imp *entities.CostBasisImport, lots []*entities.TaxLot, at time.Time) (bool, error)
+	// CancelImport returns false when the import is not pending review
+	CancelImport(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	ListLots(ctx context.Context, userID uuid.UUID) ([]*entities.TaxLot, error)
+	GetLot(ctx context.Context, id uuid.UUID) (*entities.TaxLot, error)
+	// AdjustLot stores the corrected lot together with the adjustment
+	AdjustLot(ctx context.Context, lot *entities.TaxLot, adjustment *entities.TaxLotAdjustment) error
+	ListAdjustments(ctx context.Context, lotID uuid.UUID) ([]*entities.TaxLotAdjustment, error)
+}
+
+// Config limits imports
+type Config struct {
+	MaxFileBytes int64         // Largest CSV accepted
+	MaxRows      int           // Most lots one import may hold
+	ReviewWindow time.Duration // How long an import waits for confirmation
+}
+
+// DefaultConfig accepts files up to 1MB and keeps imports for review for a day
+func DefaultConfig() Config {
+	return Config{
+		MaxFileBytes: 1 << 20,
+		MaxRows:      2000,
+		ReviewWindow: 24 * time.Hour,
+	}
+}
+
+// Service records the cost basis of positions transferred in from another
+// broker. Users upload that broker's CSV export, review the lots read from
+// it and confirm them; admins correct lots once the custodian's final
+// figures arrive.
+type Service struct {
+	repo   Repository
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a cost basis service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxFileBytes <= 0 {
+		config.MaxFileBytes = defaults.MaxFileBytes
+	}
+	if config.MaxRows <= 0 {
+		config.MaxRows = defaults.MaxRows
+	}
+	if config.ReviewWindow <= 0 {
+		config.ReviewWindow = defaults.ReviewWindow
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Import reads lots from a broker's CSV export and holds them for review.
+// Rows that cannot be read are listed on the import with the reason.
+func (s *Service) Import(ctx context.Context, userID uuid.UUID, filename string, file io.Reader) (*entities.CostBasisImport, error) {
+	body, err := io.ReadAll(io.LimitReader(file, s.config.MaxFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cost basis file: %w", err)
+	}
+	if int64(len(body)) > s.config.MaxFileBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidFile, s.config.MaxFileBytes)
+	}
+
+	now := s.now().UTC()
+	lots, rowErrors, err := parseCSV(bytes.NewReader(body), s.config.MaxRows, now)
+	if err != nil {
+		return nil, err
+	}
+	if lots == nil {
+		lots = []entities.ImportedLot{}
+	}
+	if rowErrors == nil {
+		rowErrors = []entities.CostBasisRowError{}
+	}
+
+	imp := &entities.CostBasisImport{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Filename:  filename,
+		Status:    entities.CostBasisImportPendingReview,
+		Lots:      lots,
+		Errors:    rowErrors,
+		ExpiresAt: now.Add(s.config.ReviewWindow),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateImport(ctx, imp); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Cost basis file imported for review",
+		zap.String("user_id", userID.String()),
+		zap.String("import_id", imp.ID.String()),
+		zap.Int("lots", len(lots)),
+		zap.Int("errors", len(rowErrors)))
+	return imp, nil
+}
+
+// GetImport returns one of the user's imports
+func (s *Service) GetImport(ctx context.Context, userID, id uuid.UUID) (*entities.CostBasisImport, error) {
+	imp, err := s.repo.GetImport(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	imp.Status = imp.State(s.now())
+	return imp, nil
+}
+
+// Confirm records the import's lots as the user's tax lots
+func (s *Service) Confirm(ctx context.Context, userID, id uuid.UUID) ([]*entities.TaxLot, error) {
+	imp, err := s.repo.GetImport(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if imp.State(now) != entities.CostBasisImportPendingReview {
+		return nil, ErrImportNotPending
+	}
+	if len(imp.Errors) > 0 {
+		return nil, ErrImportHasErrors
+	}
+	if len(imp.Lots) == 0 {
+		return nil, ErrNoLots
+	}
+
+	lots := make([]*entities.TaxLot, 0, len(imp.Lots))
+	for _, imported := range imp.Lots {
+		lots = append(lots, &entities.TaxLot{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Symbol:     imported.Symbol,
+			Quantity:   imported.Quantity,
+			CostBasis:  imported.CostBasis,
+			AcquiredAt: imported.AcquiredAt,
+			Source:     entities.TaxLotSourceTransferIn,
+			ImportID:   &imp.ID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+	confirmed, err := s.repo.ConfirmImport(ctx, imp, lots, now)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, ErrImportNotPending
+	}
+	s.logger.Info("Cost basis import confirmed",
+		zap.String("user_id", userID.String()),
+		zap.String("import_id", id.String()),
+		zap.Int("lots", len(lots)))
+	return lots, nil
+}
+
+// Cancel discards an import awaiting review
+func (s *Service) Cancel(ctx context.Context, userID, id uuid.UUID) error {
+	cancelled, err := s.repo.CancelImport(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		if _, err := s.repo.GetImport(ctx, userID, id); err != nil {
+			return err
+		}
+		return ErrImportNotPending
+	}
+	return nil
+}
+
+// ListLots returns the user's tax lots, ordered by symbol and acquired date
+func (s *Service) ListLots(ctx context.Context, userID uuid.UUID) ([]*entities.TaxLot, error) {
+	return s.repo.ListLots(ctx, userID)
+}
+
+// AdjustLot corrects a lot on an admin's behalf, keeping what it was before
+func (s *Service) AdjustLot(ctx context.Context, adminID, lotID uuid.UUID, req *entities.AdjustTaxLotRequest) (*entities.TaxLot, error) {
+	if req.Reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidAdjustment)
+	}
+	if req.Quantity == nil && req.CostBasis == nil && req.AcquiredAt == nil {
+		return nil, fmt.Errorf("%w: nothing to change", ErrInvalidAdjustment)
+	}
+	lot, err := s.repo.GetLot(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+	before := *lot
+
+	now := s.now().UTC()
+	if req.Quantity != nil {
+		if !req.Quantity.IsPositive() {
+			return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidAdjustment)
+		}
+		lot.Quantity = *req.Quantity
+	}
+	if req.CostBasis != nil {
+		if req.CostBasis.IsNegative() {
+			return nil, fmt.Errorf("%w: cost basis cannot be negative", ErrInvalidAdjustment)
+		}
+		lot.CostBasis = *req.CostBasis
+	}
+	if req.AcquiredAt != nil {
+		if req.AcquiredAt.After(now) || req.AcquiredAt.Before(earliestDate) {
+			return nil, fmt.Errorf("%w: acquired date is out of range", ErrInvalidAdjustment)
+		}
+		lot.AcquiredAt = req.AcquiredAt.UTC()
+	}
+	lot.Adjusted = true
+	lot.UpdatedAt = now
+
+	adjustment := &entities.TaxLotAdjustment{
+		ID:        uuid.New(),
+		LotID:     lot.ID,
+		AdminID:   adminID,
+		Before:    before,
+		After:     *lot,
+		Reason:    req.Reason,
+		CreatedAt: now,
+	}
+	if err := s.repo.AdjustLot(ctx, lot, adjustment); err != nil {
+		return nil, err
+	}
+	return lot, nil
+}
+
+// ListAdjustments returns a lot's corrections, oldest first
+func (s *Service) ListAdjustments(ctx context.Context, lotID uuid.UUID) ([]*entities.TaxLotAdjustment, error) {
+	if _, err := s.repo.GetLot(ctx, lotID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAdjustments(ctx, lotID)
+}
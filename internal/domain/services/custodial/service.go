@@ -0,0 +1,231 @@
+package custodial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Custodial account errors
+var (
+	ErrNotPermitted = errors.New("not permitted to manage this custodial account")
+	ErrIneligible   = errors.New("custodial request is not eligible")
+)
+
+// Repository persists custodial accounts
+type Repository interface {
+	Create(ctx context.Context, account *entities.CustodialAccount) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.CustodialAccount, error)
+	ListByGuardian(ctx context.Context, guardianUserID uuid.UUID) ([]*entities.CustodialAccount, error)
+	ListPendingTransferByEmail(ctx context.Context, email string) ([]*entities.CustodialAccount, error)
+	MarkTransferPending(ctx context.Context, id uuid.UUID, email string, initiatedAt time.Time) error
+	MarkTransferred(ctx context.Context, id, beneficiaryUserID uuid.UUID, transferredAt time.Time) error
+}
+
+// UserRepository provides the profile checks custodial flows depend on
+type UserRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error)
+}
+
+// AuditService records custodial lifecycle events
+type AuditService interface {
+	LogOnboardingEvent(ctx context.Context, userID uuid.UUID, action, entity string, before, after interface{}) error
+}
+
+// Service manages custodial accounts: a KYC-verified adult guardian opens and
+// manages the account for a minor, and ownership passes to the beneficiary once
+// they reach majority and complete their own KYC.
+type Service struct {
+	repo         Repository
+	userRepo     UserRepository
+	auditService AuditService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewService creates a new custodial account service
+func NewService(repo Repository, userRepo UserRepository, auditService AuditService, logger *zap.Logger) *Service {
+	return &Service{
+		repo:         repo,
+		userRepo:     userRepo,
+		auditService: auditService,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Create opens a custodial account for a minor under the guardian's control
+func (s *Service) Create(ctx context.Context, guardianID uuid.UUID, req *entities.CreateCustodialAccountRequest) (*entities.CustodialAccount, error) {
+	guardian, err := s.userRepo.GetByID(ctx, guardianID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guardian: %w", err)
+	}
+	if err := s.checkVerifiedAdult(guardian); err != nil {
+		return nil, fmt.Errorf("%w: guardian must be a KYC-verified adult: %w", ErrIneligible, err)
+	}
+
+	now := s.now()
+	if req.BeneficiaryDOB.After(now) {
+		return nil, fmt.Errorf("%w: beneficiary date of birth cannot be in the future", ErrIneligible)
+	}
+	if entities.IsAdult(req.BeneficiaryDOB, now) {
+		return nil, fmt.Errorf("%w: beneficiary is an adult and should open a self-directed account", ErrIneligible)
+	}
+
+	account := &entities.CustodialAccount{
+		ID:                   uuid.New(),
+		GuardianUserID:       guardianID,
+		BeneficiaryFirstName: strings.TrimSpace(req.BeneficiaryFirstName),
+		BeneficiaryLastName:  strings.TrimSpace(req.BeneficiaryLastName),
+		BeneficiaryDOB:       req.BeneficiaryDOB.UTC(),
+		Relationship:         req.Relationship,
+		Status:               entities.CustodialStatusActive,
+		MajorityDate:         entities.MajorityDate(req.BeneficiaryDOB),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	if err := s.repo.Create(ctx, account); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, guardianID, "custodial_account_opened", nil, account)
+	s.logger.Info("Custodial account opened",
+		zap.String("custodial_account_id", account.ID.String()),
+		zap.String("guardian_user_id", guardianID.String()))
+
+	return account, nil
+}
+
+// ListForGuardian returns the custodial accounts a guardian manages
+func (s *Service) ListForGuardian(ctx context.Context, guardianID uuid.UUID) ([]*entities.CustodialAccount, error) {
+	return s.repo.ListByGuardian(ctx, guardianID)
+}
+
+// Get returns a custodial account the user manages or has received
+func (s *Service) Get(ctx context.Context, userID, accountID uuid.UUID) (*entities.CustodialAccount, error) {
+	account, err := s.repo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.GuardianUserID != userID && (account.BeneficiaryUserID == nil || *account.BeneficiaryUserID != userID) {
+		return nil, entities.ErrCustodialNotFound
+	}
+	return account, nil
+}
+
+// InitiateTransfer starts transfer of ownership once the beneficiary reaches
+// majority. The beneficiary then claims the account from their own login.
+func (s *Service) InitiateTransfer(ctx context.Context, guardianID, accountID uuid.UUID, beneficiaryEmail string) (*entities.CustodialAccount, error) {
+	account, err := s.Get(ctx, guardianID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.GuardianUserID != guardianID {
+		return nil, ErrNotPermitted
+	}
+	now := s.now()
+	if !entities.IsAdult(account.BeneficiaryDOB, now) {
+		return nil, entities.ErrNotYetMajority
+	}
+
+	email := strings.ToLower(strings.TrimSpace(beneficiaryEmail))
+	if err := s.repo.MarkTransferPending(ctx, account.ID, email, now); err != nil {
+		return nil, err
+	}
+
+	before := *account
+	account.Status = entities.CustodialStatusTransferPending
+	account.TransferEmail = &email
+	account.TransferInitiatedAt = &now
+	account.UpdatedAt = now
+
+	s.audit(ctx, guardianID, "custodial_transfer_initiated", before, account)
+	return account, nil
+}
+
+// PendingClaims lists accounts waiting to be claimed by the user
+func (s *Service) PendingClaims(ctx context.Context, userID uuid.UUID) ([]*entities.CustodialAccount, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.repo.ListPendingTransferByEmail(ctx, user.Email)
+}
+
+// CompleteTransfer lets the beneficiary claim the account. The claimant must
+// be the invited email, an adult with approved KYC, and share the
+// beneficiary's date of birth.
+func (s *Service) CompleteTransfer(ctx context.Context, beneficiaryID, accountID uuid.UUID) (*entities.CustodialAccount, error) {
+	account, err := s.repo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != entities.CustodialStatusTransferPending || account.TransferEmail == nil {
+		return nil, fmt.Errorf("%w: custodial account has no pending transfer", ErrIneligible)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, beneficiaryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get beneficiary: %w", err)
+	}
+	if !strings.EqualFold(user.Email, *account.TransferEmail) {
+		return nil, ErrNotPermitted
+	}
+	if err := s.checkVerifiedAdult(user); err != nil {
+		return nil, err
+	}
+	if user.DateOfBirth.UTC().Format("2006-01-02") != account.BeneficiaryDOB.UTC().Format("2006-01-02") {
+		s.logger.Warn("Custodial claim rejected: date of birth mismatch",
+			zap.String("custodial_account_id", account.ID.String()),
+			zap.String("user_id", beneficiaryID.String()))
+		return nil, ErrNotPermitted
+	}
+
+	now := s.now()
+	if err := s.repo.MarkTransferred(ctx, account.ID, beneficiaryID, now); err != nil {
+		return nil, err
+	}
+
+	before := *account
+	account.Status = entities.CustodialStatusTransferred
+	account.BeneficiaryUserID = &beneficiaryID
+	account.TransferredAt = &now
+	account.UpdatedAt = now
+
+	s.audit(ctx, beneficiaryID, "custodial_transfer_completed", before, account)
+	s.logger.Info("Custodial account transferred to beneficiary",
+		zap.String("custodial_account_id", account.ID.String()),
+		zap.String("guardian_user_id", account.GuardianUserID.String()),
+		zap.String("beneficiary_user_id", beneficiaryID.String()))
+
+	return account, nil
+}
+
+func (s *Service) checkVerifiedAdult(user *entities.UserProfile) error {
+	if user.DateOfBirth == nil {
+		return entities.ErrDateOfBirthRequired
+	}
+	if !entities.IsAdult(*user.DateOfBirth, s.now()) {
+		return entities.ErrUnderage
+	}
+	if user.KYCStatus != string(entities.KYCStatusApproved) {
+		return fmt.Errorf("%w: KYC must be approved", ErrIneligible)
+	}
+	return nil
+}
+
+func (s *Service) audit(ctx context.Context, userID uuid.UUID, action string, before, after interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	if err := s.auditService.LogOnboardingEvent(ctx, userID, action, "custodial_account", before, after); err != nil {
+		s.logger.Warn("Failed to log audit event", zap.Error(err), zap.String("action", action))
+	}
+}
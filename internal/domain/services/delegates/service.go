@@ -0,0 +1,415 @@
+package delegates
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrInvalidScope is returned when a grant names an unknown scope
+	ErrInvalidScope = errors.New("unknown delegate access scope")
+	// ErrTooManyGrants is returned when the user already has the maximum
+	// number of active grants
+	ErrTooManyGrants = errors.New("too many active delegate access grants")
+	// ErrGrantNotActive is returned when revoking a revoked or expired grant
+	ErrGrantNotActive = errors.New("delegate access grant is no longer active")
+	// ErrInvalidCredentials is returned for any failed delegate sign-in, so
+	// callers cannot tell an unknown token from a revoked one
+	ErrInvalidCredentials = errors.New("invalid delegate email or token")
+	// ErrScopeNotGranted is returned when a delegate views outside their grant
+	ErrScopeNotGranted = errors.New("this area of the account is not shared with you")
+)
+
+const (
+	grantTokenPrefix   = "dlg_"
+	sessionTokenPrefix = "dls_"
+)
+
+// Repository persists grants, delegate sessions and the access log
+type Repository interface {
+	CreateGrant(ctx context.Context, grant *entities.DelegateGrant) error
+	GetGrant(ctx context.Context, userID, id uuid.UUID) (*entities.DelegateGrant, error)
+	GetGrantByTokenHash(ctx context.Context, tokenHash string) (*entities.DelegateGrant, error)
+	ListGrants(ctx context.Context, userID uuid.UUID) ([]*entities.DelegateGrant, error)
+	CountActiveGrants(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	// RevokeGrant marks an active grant revoked and ends its sessions
+	RevokeGrant(ctx context.Context, userID, id uuid.UUID, at time.Time) error
+	CreateSession(ctx context.Context, session *entities.DelegateSession) error
+	// GetSessionGrant returns the grant of an unexpired session, or
+	// ErrDelegateSessionInvalid
+	GetSessionGrant(ctx context.Context, tokenHash string, now time.Time) (*entities.DelegateGrant, error)
+	TouchGrant(ctx context.Context, id uuid.UUID, at time.Time) error
+	RecordAccess(ctx context.Context, event *entities.DelegateAccessEvent) error
+	ListAccess(ctx context.Context, userID, grantID uuid.UUID, limit int) ([]*entities.DelegateAccessEvent, error)
+}
+
+// PortfolioReader returns a user's live holdings
+type PortfolioReader interface {
+	GetPortfolio(ctx context.Context, userID uuid.UUID) (*entities.Portfolio, error)
+}
+
+// StatementReader returns documents from a user's document center
+type StatementReader interface {
+	List(ctx context.Context, userID uuid.UUID, filter entities.DocumentFilter) ([]*entities.Document, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*entities.Document, error)
+}
+
+// Viewer describes the request a delegate viewed the account from
+type Viewer struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Config limits grants and delegate sessions
+type Config struct {
+	MaxGrantTTL time.Duration // Longest a grant may last, and its default
+	SessionTTL  time.Duration // How long a delegate stays signed in
+	MaxGrants   int           // Active grants per user
+}
+
+// DefaultConfig lets grants last a year and delegates stay signed in an hour
+func DefaultConfig() Config {
+	return Config{
+		MaxGrantTTL: 365 * 24 * time.Hour,
+		SessionTTL:  time.Hour,
+		MaxGrants:   5,
+	}
+}
+
+// Service lets users share a read-only view of their portfolio and
+// statements. Delegates never see profile details or anything that moves
+// money, and every view is recorded for the account holder.
+type Service struct {
+	repo       Repository
+	portfolio  PortfolioReader
+	statements StatementReader
+	config     Config
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewService creates a delegated access service
+func NewService(repo Repository, portfolio PortfolioReader, statements StatementReader, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxGrantTTL <= 0 {
+		config.MaxGrantTTL = defaults.MaxGrantTTL
+	}
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = defaults.SessionTTL
+	}
+	if config.MaxGrants <= 0 {
+		config.MaxGrants = defaults.MaxGrants
+	}
+	return &Service{
+		repo:       repo,
+		portfolio:  portfolio,
+		statements: statements,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Grant gives a delegate read-only access to the scopes requested. The
+// returned token is the delegate's credential and is not stored.
+func (s *Service) Grant(ctx context.Context, userID uuid.UUID, req *entities.CreateDelegateGrantRequest) (*entities.CreateDelegateGrantResponse, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	active, err := s.repo.CountActiveGrants(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if active >= s.config.MaxGrants {
+		return nil, fmt.Errorf("%w (limit %d)", ErrTooManyGrants, s.config.MaxGrants)
+	}
+
+	ttl := s.config.MaxGrantTTL
+	if req.ExpiresInDays > 0 && time.Duration(req.ExpiresInDays)*24*time.Hour < ttl {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	token, hash, err := newToken(grantTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	grant := &entities.DelegateGrant{
+		ID:            uuid.New(),
+		UserID:        userID,
+		DelegateName:  strings.TrimSpace(req.DelegateName),
+		DelegateEmail: strings.ToLower(strings.TrimSpace(req.DelegateEmail)),
+		Relationship:  req.Relationship,
+		Scopes:        scopes,
+		TokenHash:     hash,
+		TokenPrefix:   token[:len(grantTokenPrefix)+8],
+		Status:        entities.DelegateGrantActive,
+		ExpiresAt:     now.Add(ttl),
+		CreatedAt:     now,
+	}
+	if err := s.repo.CreateGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Delegate access granted",
+		zap.String("grant_id", grant.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("relationship", string(grant.Relationship)))
+	return &entities.CreateDelegateGrantResponse{Grant: s.withState(grant), Token: token}, nil
+}
+
+// List returns the user's grants, newest first
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*entities.DelegateGrant, error) {
+	grants, err := s.repo.ListGrants(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range grants {
+		s.withState(grant)
+	}
+	return grants, nil
+}
+
+// Revoke ends a grant and signs its delegate out
+func (s *Service) Revoke(ctx context.Context, userID, id uuid.UUID) (*entities.DelegateGrant, error) {
+	grant, err := s.repo.GetGrant(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if grant.State(now) != entities.DelegateGrantActive {
+		return nil, ErrGrantNotActive
+	}
+	if err := s.repo.RevokeGrant(ctx, userID, id, now); err != nil {
+		return nil, err
+	}
+	grant.Status = entities.DelegateGrantRevoked
+	grant.RevokedAt = &now
+
+	s.logger.Info("Delegate access revoked",
+		zap.String("grant_id", grant.ID.String()),
+		zap.String("user_id", userID.String()))
+	return grant, nil
+}
+
+// AccessLog returns what the grant's delegate viewed, newest first
+func (s *Service) AccessLog(ctx context.Context, userID, grantID uuid.UUID, limit int) ([]*entities.DelegateAccessEvent, error) {
+	if _, err := s.repo.GetGrant(ctx, userID, grantID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListAccess(ctx, userID, grantID, limit)
+}
+
+// Login signs a delegate in with their grant token and the email it was
+// issued to, returning a session token for the delegate endpoints
+func (s *Service) Login(ctx context.Context, req *entities.DelegateLoginRequest, viewer Viewer) (*entities.DelegateLoginResponse, error) {
+	token := strings.TrimSpace(req.Token)
+	if !strings.HasPrefix(token, grantTokenPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+	grant, err := s.repo.GetGrantByTokenHash(ctx, hashToken(token))
+	if errors.Is(err, entities.ErrDelegateGrantNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if grant.State(now) != entities.DelegateGrantActive ||
+		!strings.EqualFold(strings.TrimSpace(req.Email), grant.DelegateEmail) {
+		s.logger.Warn("Delegate sign-in refused",
+			zap.String("grant_id", grant.ID.String()),
+			zap.String("status", string(grant.State(now))))
+		return nil, ErrInvalidCredentials
+	}
+
+	sessionToken, hash, err := newToken(sessionTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	// A session never outlives its grant
+	expiresAt := now.Add(s.config.SessionTTL)
+	if grant.ExpiresAt.Before(expiresAt) {
+		expiresAt = grant.ExpiresAt
+	}
+	session := &entities.DelegateSession{
+		ID:        uuid.New(),
+		GrantID:   grant.ID,
+		TokenHash: hash,
+		IPAddress: viewer.IPAddress,
+		UserAgent: viewer.UserAgent,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	if err := s.repo.TouchGrant(ctx, grant.ID, now); err != nil {
+		s.logger.Warn("Failed to record delegate sign-in", zap.Error(err), zap.String("grant_id", grant.ID.String()))
+	}
+	if err := s.record(ctx, grant, "", "sign_in", nil, viewer); err != nil {
+		return nil, err
+	}
+
+	return &entities.DelegateLoginResponse{
+		AccessToken:  sessionToken,
+		ExpiresAt:    expiresAt,
+		Scopes:       grant.Scopes,
+		GrantExpires: grant.ExpiresAt,
+	}, nil
+}
+
+// Authenticate returns the grant behind a delegate session token
+func (s *Service) Authenticate(ctx context.Context, sessionToken string) (*entities.DelegateGrant, error) {
+	if !strings.HasPrefix(sessionToken, sessionTokenPrefix) {
+		return nil, entities.ErrDelegateSessionInvalid
+	}
+	grant, err := s.repo.GetSessionGrant(ctx, hashToken(sessionToken), s.now())
+	if err != nil {
+		return nil, err
+	}
+	if grant.State(s.now()) != entities.DelegateGrantActive {
+		return nil, entities.ErrDelegateSessionInvalid
+	}
+	return grant, nil
+}
+
+// Portfolio returns the account holder's live holdings to a delegate
+func (s *Service) Portfolio(ctx context.Context, grant *entities.DelegateGrant, viewer Viewer) (*entities.Portfolio, error) {
+	if !grant.Allows(entities.DelegateScopePortfolio) {
+		return nil, ErrScopeNotGranted
+	}
+	portfolio, err := s.portfolio.GetPortfolio(ctx, grant.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, grant, entities.DelegateScopePortfolio, "portfolio", nil, viewer); err != nil {
+		return nil, err
+	}
+	return portfolio, nil
+}
+
+// Statements lists the account holder's trade confirmations for a delegate
+func (s *Service) Statements(ctx context.Context, grant *entities.DelegateGrant, limit, offset int, viewer Viewer) ([]*entities.Document, error) {
+	if !grant.Allows(entities.DelegateScopeStatements) {
+		return nil, ErrScopeNotGranted
+	}
+	documents, err := s.statements.List(ctx, grant.UserID, entities.DocumentFilter{
+		Category: entities.DocumentCategoryTradeConfirmation,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, grant, entities.DelegateScopeStatements, "statement_list", nil, viewer); err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		document.ReadAt = nil
+	}
+	return documents, nil
+}
+
+// Statement returns one of the account holder's trade confirmations. Other
+// documents are reported as not found.
+func (s *Service) Statement(ctx context.Context, grant *entities.DelegateGrant, id uuid.UUID, viewer Viewer) (*entities.Document, error) {
+	if !grant.Allows(entities.DelegateScopeStatements) {
+		return nil, ErrScopeNotGranted
+	}
+	document, err := s.statements.Get(ctx, grant.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.Category != entities.DocumentCategoryTradeConfirmation {
+		return nil, entities.ErrDocumentNotFound
+	}
+	resourceID := id.String()
+	if err := s.record(ctx, grant, entities.DelegateScopeStatements, "statement", &resourceID, viewer); err != nil {
+		return nil, err
+	}
+	document.ReadAt = nil
+	return document, nil
+}
+
+// record logs a view. Nothing is shown to a delegate unless the view is
+// recorded.
+func (s *Service) record(ctx context.Context, grant *entities.DelegateGrant, scope entities.DelegateScope, resource string, resourceID *string, viewer Viewer) error {
+	event := &entities.DelegateAccessEvent{
+		ID:         uuid.New(),
+		GrantID:    grant.ID,
+		UserID:     grant.UserID,
+		Scope:      scope,
+		Resource:   resource,
+		ResourceID: resourceID,
+		IPAddress:  viewer.IPAddress,
+		UserAgent:  viewer.UserAgent,
+		ViewedAt:   s.now(),
+	}
+	if err := s.repo.RecordAccess(ctx, event); err != nil {
+		s.logger.Error("Failed to record delegate access",
+			zap.Error(err),
+			zap.String("grant_id", grant.ID.String()),
+			zap.String("resource", resource))
+		return fmt.Errorf("failed to record delegate access: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) withState(grant *entities.DelegateGrant) *entities.DelegateGrant {
+	grant.Status = grant.State(s.now())
+	return grant
+}
+
+func normalizeScopes(requested []entities.DelegateScope) ([]entities.DelegateScope, error) {
+	var scopes []entities.DelegateScope
+	seen := map[entities.DelegateScope]bool{}
+	for _, scope := range requested {
+		scope = entities.DelegateScope(strings.ToLower(strings.TrimSpace(string(scope))))
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w %q", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	return scopes, nil
+}
+
+// newToken returns a random token with the prefix and its hash
+func newToken(prefix string) (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate delegate token: %w", err)
+	}
+	token := prefix + hex.EncodeToString(raw)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package depositref
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Reference codes are "STK", seven random Crockford base32 characters and a
+// Luhn mod 32 check character. Crockford's alphabet has no I, L, O or U, so
+// codes read aloud or retyped from a banking app survive the usual mix-ups,
+// and the check character catches any single wrong character and most
+// swapped pairs before a credit goes to the wrong user.
+const (
+	referencePrefix = "STK"
+	referenceBody   = 7
+	referenceLength = len(referencePrefix) + referenceBody + 1
+	crockford       = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// newReferenceCode returns a random reference code in canonical form
+func newReferenceCode() (string, error) {
+	body := make([]byte, referenceBody)
+	for i := range body {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(crockford))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate deposit reference: %w", err)
+		}
+		body[i] = crockford[n.Int64()]
+	}
+	return referencePrefix + string(body) + string(checkCharacter(string(body))), nil
+}
+
+// checkCharacter computes the Luhn mod 32 check character of a code body
+func checkCharacter(body string) byte {
+	const n = len(crockford)
+	factor, sum := 2, 0
+	for i := len(body) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(crockford, body[i])
+		factor = 3 - factor
+		sum += addend/n + addend%n
+	}
+	return crockford[(n-sum%n)%n]
+}
+
+// FormatReference renders a canonical code the way users are shown it,
+// e.g. STK-4F7K-2M9Q
+func FormatReference(code string) string {
+	if len(code) != referenceLength {
+		return code
+	}
+	body := code[len(referencePrefix):]
+	return referencePrefix + "-" + body[:4] + "-" + body[4:]
+}
+
+// ParseReferences finds the valid reference codes in a transfer memo, in
+// canonical form. Case, separators and the characters Crockford's alphabet
+// reads as digits are ignored. found reports whether the memo had anything
+// starting with the prefix, valid or not.
+func ParseReferences(memo string) (codes []string, found bool) {
+	candidates, found := referenceCandidates(memo)
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if validReference(candidate) && !seen[candidate] {
+			seen[candidate] = true
+			codes = append(codes, candidate)
+		}
+	}
+	return codes, found
+}
+
+// referenceCandidates returns everything in a memo shaped like a reference,
+// whether or not its check character matches
+func referenceCandidates(memo string) (candidates []string, found bool) {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(memo) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	text := b.String()
+
+	for i := strings.Index(text, referencePrefix); i >= 0; {
+		found = true
+		if candidate, ok := canonicalCandidate(text[i+len(referencePrefix):]); ok {
+			candidates = append(candidates, candidate)
+		}
+		next := strings.Index(text[i+1:], referencePrefix)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return candidates, found
+}
+
+// canonicalCandidate maps the characters following a prefix into the
+// alphabet, failing if any is not in it
+func canonicalCandidate(rest string) (string, bool) {
+	if len(rest) < referenceBody+1 {
+		return "", false
+	}
+	chars := []byte(rest[:referenceBody+1])
+	for i, c := range chars {
+		switch c {
+		case 'O':
+			chars[i] = '0'
+		case 'I', 'L':
+			chars[i] = '1'
+		}
+		if strings.IndexByte(crockford, chars[i]) < 0 {
+			return "", false
+		}
+	}
+	return referencePrefix + string(chars), true
+}
+
+// validReference reports whether a canonical candidate's check character matches
+func validReference(candidate string) bool {
+	body := candidate[len(referencePrefix) : len(referencePrefix)+referenceBody]
+	return checkCharacter(body) == candidate[len(candidate)-1]
+}
+
+// similarReferences returns the valid codes one mistyped character or one
+// swapped pair away from a candidate, the usual ways a reference gets mangled
+func similarReferences(candidate string) []string {
+	chars := []byte(candidate[len(referencePrefix):])
+	var similar []string
+	try := func(variant []byte) {
+		code := referencePrefix + string(variant)
+		if code != candidate && validReference(code) {
+			similar = append(similar, code)
+		}
+	}
+
+	for i := range chars {
+		for j := 0; j < len(crockford); j++ {
+			variant := append([]byte(nil), chars...)
+			variant[i] = crockford[j]
+			try(variant)
+		}
+	}
+	for i := 0; i+1 < len(chars); i++ {
+		if chars[i] != chars[i+1] {
+			variant := append([]byte(nil), chars...)
+			variant[i], variant[i+1] = variant[i+1], variant[i]
+			try(variant)
+		}
+	}
+	return similar
+}
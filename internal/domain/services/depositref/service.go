@@ -0,0 +1,446 @@
+package depositref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// ErrInvalidAmount is returned for a credit notification without a positive amount
+var ErrInvalidAmount = errors.New("bank credit amount must be positive")
+
+// Repository persists reference codes and credits into shared accounts
+type Repository interface {
+	CreateReference(ctx context.Context, ref *entities.DepositReference) (bool, error)
+	GetReferenceByUser(ctx context.Context, userID uuid.UUID) (*entities.DepositReference, error)
+	GetReferenceByCode(ctx context.Context, code string) (*entities.DepositReference, error)
+	CreateCredit(ctx context.Context, credit *entities.BankCredit) (*entities.BankCredit, bool, error)
+	GetCredit(ctx context.Context, id uuid.UUID) (*entities.BankCredit, error)
+	ListReferencesByCodes(ctx context.Context, codes []string) ([]*entities.DepositReference, error)
+	SuggestUsersBySenderName(ctx context.Context, senderName string, limit int) ([]entities.SuspenseSuggestion, error)
+	ClaimCredit(ctx context.Context, credit *entities.BankCredit) (bool, error)
+	ReleaseCredit(ctx context.Context, id uuid.UUID, at time.Time) error
+	MarkCredited(ctx context.Context, id uuid.UUID, ledgerTxID *uuid.UUID, at time.Time) error
+	MarkReturned(ctx context.Context, id, returnedBy uuid.UUID, note string, at time.Time) (bool, error)
+}
+
+// Crediter posts an attributed credit to the user's ledger and buying power
+type Crediter interface {
+	CreditBankTransfer(ctx context.Context, credit *entities.BankCredit, usdAmount decimal.Decimal) (*uuid.UUID, error)
+}
+
+// Converter converts credits in other currencies to USD at the time received
+type Converter interface {
+	Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error)
+}
+
+// Suspense holds credits that cannot be attributed until an admin assigns or
+// refunds them
+type Suspense interface {
+	Hold(ctx context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, error)
+}
+
+// SharedAccount is a bank account that receives every user's transfers in a
+// currency
+type SharedAccount struct {
+	Currency      string
+	BankName      string
+	AccountName   string
+	AccountNumber string
+	RoutingNumber string
+}
+
+// Service attributes transfers into shared accounts. Where users cannot each
+// be given their own virtual account, they are all given the same account and
+// a reference code of their own to put in the transfer memo. Credits are
+// matched to users by that code; those that cannot be are held for an admin
+// to match by hand or return, in suspense when it is configured.
+type Service struct {
+	repo      Repository
+	crediter  Crediter
+	converter Converter
+	suspense  Suspense
+	accounts  []SharedAccount
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates the deposit reference service for the given shared accounts
+func NewService(repo Repository, crediter Crediter, accounts []SharedAccount, logger *zap.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		crediter: crediter,
+		accounts: accounts,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetConverter enables crediting shared accounts in currencies other than USD
+func (s *Service) SetConverter(converter Converter) {
+	s.converter = converter
+}
+
+// SetSuspense holds unattributed credits in the suspense account
+func (s *Service) SetSuspense(suspense Suspense) {
+	s.suspense = suspense
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// GetFundingInstructions returns the shared account for a currency along with
+// the user's reference code, issuing the code on first use
+func (s *Service) GetFundingInstructions(ctx context.Context, userID uuid.UUID, currency string) (*entities.FundingInstructions, error) {
+	account, ok := s.accountForCurrency(currency)
+	if !ok {
+		return nil, entities.ErrNoSharedDepositAccount
+	}
+	ref, err := s.ReferenceFor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.FundingInstructions{
+		Currency:          strings.ToUpper(account.Currency),
+		BankName:          account.BankName,
+		AccountName:       account.AccountName,
+		AccountNumber:     account.AccountNumber,
+		RoutingNumber:     account.RoutingNumber,
+		Reference:         FormatReference(ref.Code),
+		ReferenceRequired: true,
+	}, nil
+}
+
+// ReferenceFor returns the user's reference code, issuing one if needed
+func (s *Service) ReferenceFor(ctx context.Context, userID uuid.UUID) (*entities.DepositReference, error) {
+	existing, err := s.repo.GetReferenceByUser(ctx, userID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := newReferenceCode()
+		if err != nil {
+			return nil, err
+		}
+		ref := &entities.DepositReference{ID: uuid.New(), UserID: userID, Code: code, CreatedAt: s.now()}
+		created, err := s.repo.CreateReference(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return ref, nil
+		}
+		// Either a concurrent request issued the user's code or this code is taken
+		existing, err := s.repo.GetReferenceByUser(ctx, userID)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+	}
+	return nil, fmt.Errorf("failed to issue a unique deposit reference for user %s", userID)
+}
+
+// ReceiveCredit records a credit into a shared account and credits the user
+// named by the reference in its memo. Credits that cannot be attributed are
+// left unmatched with the reason and held in suspense. Notifications are
+// idempotent on the provider reference; a redelivered credit whose crediting
+// failed is attributed again.
+func (s *Service) ReceiveCredit(ctx context.Context, notice *entities.BankCreditWebhook) (*entities.BankCredit, error) {
+	if !notice.Amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	now := s.now()
+	receivedAt := now
+	if notice.ReceivedAt != nil {
+		receivedAt = *notice.ReceivedAt
+	}
+	reference, userID, reason, err := s.attribute(ctx, notice.AccountNumber, notice.Memo)
+	if err != nil {
+		return nil, err
+	}
+
+	credit, created, err := s.repo.CreateCredit(ctx, &entities.BankCredit{
+		ID:              uuid.New(),
+		ProviderRef:     notice.ProviderRef,
+		AccountNumber:   notice.AccountNumber,
+		Currency:        strings.ToUpper(notice.Currency),
+		Amount:          notice.Amount,
+		Memo:            notice.Memo,
+		SenderName:      notice.SenderName,
+		Status:          entities.BankCreditStatusUnmatched,
+		Reference:       reference,
+		UnmatchedReason: reason,
+		ReceivedAt:      receivedAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		s.logger.Info("Bank credit already received",
+			zap.String("provider_ref", credit.ProviderRef),
+			zap.String("status", string(credit.Status)))
+	}
+	if credit.Status != entities.BankCreditStatusUnmatched {
+		return credit, nil
+	}
+	// Credits already found unattributable stay with the admins; holding
+	// them again finishes a hold that failed on an earlier delivery
+	if credit.UnmatchedReason != nil {
+		return credit, s.hold(ctx, credit, *credit.UnmatchedReason)
+	}
+	if userID == nil {
+		return credit, s.hold(ctx, credit, *reason)
+	}
+
+	credited, err := s.credit(ctx, credit, *userID, entities.BankCreditMatchReference, nil, nil)
+	if errors.Is(err, entities.ErrBankCreditNotUnmatched) {
+		// Matched concurrently by a redelivery or an admin
+		return s.repo.GetCredit(ctx, credit.ID)
+	}
+	return credited, err
+}
+
+// hold places an unattributed credit in suspense
+func (s *Service) hold(ctx context.Context, credit *entities.BankCredit, reason string) error {
+	if s.suspense == nil {
+		s.logger.Warn("Bank credit held for manual matching",
+			zap.String("bank_credit_id", credit.ID.String()),
+			zap.String("reason", reason))
+		return nil
+	}
+	usdAmount, err := s.toUSD(ctx, credit)
+	if err != nil {
+		return fmt.Errorf("failed to value bank credit for suspense: %w", err)
+	}
+	_, err = s.suspense.Hold(ctx, &entities.SuspenseItem{
+		Source:    entities.SuspenseSourceBankCredit,
+		SourceRef: credit.ID.String(),
+		Currency:  credit.Currency,
+		Amount:    credit.Amount,
+		USDAmount: usdAmount,
+		Reason:    reason,
+		Details: map[string]string{
+			"provider_ref":   credit.ProviderRef,
+			"account_number": credit.AccountNumber,
+			"memo":           credit.Memo,
+			"sender_name":    credit.SenderName,
+		},
+		ReceivedAt: credit.ReceivedAt,
+	})
+	return err
+}
+
+// SuspenseSuggestions suggests owners for a held credit: users whose
+// reference is one typo away from something in the memo, and users whose
+// name appears in the sender's name
+func (s *Service) SuspenseSuggestions(ctx context.Context, item *entities.SuspenseItem) ([]entities.SuspenseSuggestion, error) {
+	credit, err := s.suspendedCredit(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []entities.SuspenseSuggestion
+	seen := make(map[uuid.UUID]bool)
+	candidates, _ := referenceCandidates(credit.Memo)
+	for _, candidate := range candidates {
+		similar := similarReferences(candidate)
+		if len(similar) == 0 {
+			continue
+		}
+		refs, err := s.repo.ListReferencesByCodes(ctx, similar)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			if seen[ref.UserID] {
+				continue
+			}
+			seen[ref.UserID] = true
+			suggestions = append(suggestions, entities.SuspenseSuggestion{
+				UserID: ref.UserID,
+				Reason: entities.SuspenseSuggestionSimilarReference,
+				Detail: fmt.Sprintf("memo has %s, user's reference is %s", FormatReference(candidate), FormatReference(ref.Code)),
+			})
+		}
+	}
+
+	if strings.TrimSpace(credit.SenderName) != "" {
+		named, err := s.repo.SuggestUsersBySenderName(ctx, credit.SenderName, 5)
+		if err != nil {
+			return nil, err
+		}
+		for _, suggestion := range named {
+			if !seen[suggestion.UserID] {
+				seen[suggestion.UserID] = true
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+	return suggestions, nil
+}
+
+// AssignSuspended credits a held credit to the user an admin identified
+func (s *Service) AssignSuspended(ctx context.Context, item *entities.SuspenseItem, userID, adminID uuid.UUID, note string) error {
+	credit, err := s.suspendedCredit(ctx, item)
+	if err != nil {
+		return err
+	}
+	if credit.Status != entities.BankCreditStatusUnmatched {
+		return entities.ErrBankCreditNotUnmatched
+	}
+	_, err = s.credit(ctx, credit, userID, entities.BankCreditMatchManual, &adminID, &note)
+	return err
+}
+
+// RefundSuspended records that an admin sent a held credit back to its sender
+func (s *Service) RefundSuspended(ctx context.Context, item *entities.SuspenseItem, adminID uuid.UUID, note string) error {
+	credit, err := s.suspendedCredit(ctx, item)
+	if err != nil {
+		return err
+	}
+	returned, err := s.repo.MarkReturned(ctx, credit.ID, adminID, note, s.now())
+	if err != nil {
+		return err
+	}
+	if !returned {
+		return entities.ErrBankCreditNotUnmatched
+	}
+	return nil
+}
+
+func (s *Service) suspendedCredit(ctx context.Context, item *entities.SuspenseItem) (*entities.BankCredit, error) {
+	id, err := uuid.Parse(item.SourceRef)
+	if err != nil {
+		return nil, fmt.Errorf("suspense item %s has an invalid bank credit reference: %w", item.ID, err)
+	}
+	return s.repo.GetCredit(ctx, id)
+}
+
+// attribute finds the user a credit belongs to from the account it was paid
+// into and its memo. When no user is found the reason is returned instead.
+func (s *Service) attribute(ctx context.Context, accountNumber, memo string) (reference *string, userID *uuid.UUID, reason *string, err error) {
+	unmatched := func(why string) (*string, *uuid.UUID, *string, error) {
+		return reference, nil, &why, nil
+	}
+
+	if !s.isSharedAccount(accountNumber) {
+		return unmatched(entities.BankCreditUnknownAccount)
+	}
+	codes, found := ParseReferences(memo)
+	if len(codes) == 0 {
+		if found {
+			return unmatched(entities.BankCreditInvalidReference)
+		}
+		return unmatched(entities.BankCreditNoReference)
+	}
+
+	reference = &codes[0]
+	for _, code := range codes {
+		ref, err := s.repo.GetReferenceByCode(ctx, code)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if ref == nil {
+			continue
+		}
+		if userID != nil && *userID != ref.UserID {
+			return unmatched(entities.BankCreditMultipleReference)
+		}
+		reference, userID = &code, &ref.UserID
+	}
+	if userID == nil {
+		return unmatched(entities.BankCreditUnknownReference)
+	}
+	return reference, userID, nil, nil
+}
+
+// credit claims an unmatched credit for a user and posts it. If posting
+// fails the claim is released so the credit can be matched again.
+func (s *Service) credit(ctx context.Context, credit *entities.BankCredit, userID uuid.UUID, method string, matchedBy *uuid.UUID, note *string) (*entities.BankCredit, error) {
+	now := s.now()
+	credit.UserID = &userID
+	credit.MatchMethod = &method
+	credit.MatchedBy = matchedBy
+	credit.MatchedAt = &now
+	credit.Note = note
+
+	claimed, err := s.repo.ClaimCredit(ctx, credit)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, entities.ErrBankCreditNotUnmatched
+	}
+
+	usdAmount, err := s.toUSD(ctx, credit)
+	var ledgerTxID *uuid.UUID
+	if err == nil {
+		ledgerTxID, err = s.crediter.CreditBankTransfer(ctx, credit, usdAmount)
+	}
+	if err != nil {
+		if releaseErr := s.repo.ReleaseCredit(ctx, credit.ID, s.now()); releaseErr != nil {
+			s.logger.Error("Failed to release bank credit", zap.Error(releaseErr), zap.String("bank_credit_id", credit.ID.String()))
+		}
+		return nil, fmt.Errorf("failed to credit bank transfer: %w", err)
+	}
+
+	// The user has been credited; if this fails the credit stays claimed
+	// rather than returning to the queue to be credited twice
+	if err := s.repo.MarkCredited(ctx, credit.ID, ledgerTxID, s.now()); err != nil {
+		s.logger.Error("Bank credit posted but not marked credited", zap.Error(err), zap.String("bank_credit_id", credit.ID.String()))
+		return nil, err
+	}
+	credit.Status = entities.BankCreditStatusCredited
+	credit.LedgerTransactionID = ledgerTxID
+
+	s.logger.Info("Bank credit matched",
+		zap.String("bank_credit_id", credit.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("match_method", method),
+		logger.Amount("amount", credit.Amount),
+		zap.String("currency", credit.Currency))
+	return credit, nil
+}
+
+func (s *Service) toUSD(ctx context.Context, credit *entities.BankCredit) (decimal.Decimal, error) {
+	if credit.Currency == "USD" {
+		return credit.Amount, nil
+	}
+	if s.converter == nil {
+		return decimal.Zero, fmt.Errorf("no exchange rates to convert %s", credit.Currency)
+	}
+	return s.converter.Convert(ctx, credit.Amount, credit.Currency, "USD", credit.ReceivedAt)
+}
+
+func (s *Service) accountForCurrency(currency string) (SharedAccount, bool) {
+	for _, account := range s.accounts {
+		if strings.EqualFold(account.Currency, currency) {
+			return account, true
+		}
+	}
+	return SharedAccount{}, false
+}
+
+func (s *Service) isSharedAccount(accountNumber string) bool {
+	normalized := strings.ReplaceAll(accountNumber, " ", "")
+	for _, account := range s.accounts {
+		if strings.ReplaceAll(account.AccountNumber, " ", "") == normalized {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+package developer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/apikey"
+)
+
+var (
+	// ErrScopeNotAllowed is returned when a developer asks for a scope that
+	// is not offered to self-serve sandbox keys
+	ErrScopeNotAllowed = errors.New("scope not available to developer keys")
+	// ErrKeyLimitReached is returned when a tenant already has the most
+	// active keys allowed
+	ErrKeyLimitReached = errors.New("developer key limit reached")
+	// ErrWebhookLimitReached is returned when a tenant already has the most
+	// webhook endpoints allowed
+	ErrWebhookLimitReached = errors.New("developer webhook endpoint limit reached")
+	// ErrKeyNotFound is returned for a key the tenant did not issue
+	ErrKeyNotFound = errors.New("developer key not found")
+	// ErrInvalidKeyRequest is returned for a key request with a missing name
+	// or an expiry in the past
+	ErrInvalidKeyRequest = errors.New("invalid developer key request")
+)
+
+// Scopes lists what a self-serve sandbox key may be granted. Write access
+// beyond placing sandbox orders, and the "*" scope, are never self-serve.
+var Scopes = []string{
+	"accounts:read",
+	"portfolio:read",
+	"orders:read",
+	"orders:write",
+	"market_data:read",
+}
+
+// TenantRepository stores developer tenants
+type TenantRepository interface {
+	// CreateTenant returns entities.ErrDeveloperTenantExists when the user
+	// already has one
+	CreateTenant(ctx context.Context, tenant *entities.DeveloperTenant) error
+	GetTenantByUser(ctx context.Context, userID uuid.UUID) (*entities.DeveloperTenant, error)
+}
+
+// KeyIssuer issues and revokes API keys; the apikey service
+type KeyIssuer interface {
+	CreateAPIKey(ctx context.Context, req *apikey.CreateAPIKeyRequest) (*apikey.CreateAPIKeyResponse, error)
+	ListTenantAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]*apikey.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID uuid.UUID, userID *uuid.UUID) error
+}
+
+// UsageReporter reports API usage; the apiusage service
+type UsageReporter interface {
+	Report(ctx context.Context, filter entities.APIUsageFilter) (*entities.APIUsageReport, error)
+}
+
+// WebhookRegistry registers webhook endpoints; the outbound webhook service
+type WebhookRegistry interface {
+	CreateTenantEndpoint(ctx context.Context, tenantID uuid.UUID, req *entities.CreateWebhookEndpointRequest, createdBy *uuid.UUID) (*entities.WebhookEndpointWithSecret, error)
+	ListTenantEndpoints(ctx context.Context, tenantID uuid.UUID) ([]*entities.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*entities.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	SendTestEvent(ctx context.Context, endpointID uuid.UUID) (*entities.WebhookDelivery, error)
+}
+
+// Config limits what each tenant can create
+type Config struct {
+	MaxActiveKeys       int
+	MaxWebhookEndpoints int
+}
+
+// DefaultConfig allows ten active keys and five webhook endpoints per tenant
+func DefaultConfig() Config {
+	return Config{
+		MaxActiveKeys:       10,
+		MaxWebhookEndpoints: 5,
+	}
+}
+
+// Service runs the developer program for partners integrating with the
+// public API. Each developer gets a sandbox tenant, issues scoped sandbox
+// keys for it, follows each key's usage and registers webhook endpoints
+// that receive test events.
+type Service struct {
+	tenants  TenantRepository
+	keys     KeyIssuer
+	usage    UsageReporter
+	webhooks WebhookRegistry
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewService creates a developer program service
+func NewService(tenants TenantRepository, keys KeyIssuer, usage UsageReporter, webhooks WebhookRegistry, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxActiveKeys <= 0 {
+		config.MaxActiveKeys = defaults.MaxActiveKeys
+	}
+	if config.MaxWebhookEndpoints <= 0 {
+		config.MaxWebhookEndpoints = defaults.MaxWebhookEndpoints
+	}
+	return &Service{
+		tenants:  tenants,
+		keys:     keys,
+		usage:    usage,
+		webhooks: webhooks,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// CreateTenant enrols the user in the developer program with a sandbox
+// tenant
+func (s *Service) CreateTenant(ctx context.Context, userID uuid.UUID, req *entities.CreateDeveloperTenantRequest) (*entities.DeveloperTenant, error) {
+	tenant := &entities.DeveloperTenant{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Environment: entities.DeveloperEnvironmentSandbox,
+		CreatedAt:   s.now(),
+	}
+	if err := s.tenants.CreateTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Developer tenant created",
+		zap.String("tenant_id", tenant.ID.String()),
+		zap.String("user_id", userID.String()))
+	return tenant, nil
+}
+
+// GetTenant returns the user's tenant
+func (s *Service) GetTenant(ctx context.Context, userID uuid.UUID) (*entities.DeveloperTenant, error) {
+	return s.tenants.GetTenantByUser(ctx, userID)
+}
+
+// IssueKey issues a sandbox API key for the user's tenant. The key itself
+// is only returned here.
+func (s *Service) IssueKey(ctx context.Context, userID uuid.UUID, req *entities.IssueDeveloperKeyRequest) (*apikey.CreateAPIKeyResponse, error) {
+	tenant, err := s.tenants.GetTenantByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidKeyRequest)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidKeyRequest)
+	}
+	scopes, err := allowedScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.keys.ListTenantAPIKeys(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, key := range existing {
+		if key.IsActive && (key.ExpiresAt == nil || key.ExpiresAt.After(s.now())) {
+			active++
+		}
+	}
+	if active >= s.config.MaxActiveKeys {
+		return nil, fmt.Errorf("%w: revoke a key before issuing another (limit %d)", ErrKeyLimitReached, s.config.MaxActiveKeys)
+	}
+
+	return s.keys.CreateAPIKey(ctx, &apikey.CreateAPIKeyRequest{
+		Name:        name,
+		UserID:      &userID,
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
+		TenantID:    &tenant.ID,
+		Environment: tenant.Environment,
+	})
+}
+
+// ListKeys returns the tenant's keys, newest first
+func (s *Service) ListKeys(ctx context.Context, userID uuid.UUID) ([]*apikey.APIKey, error) {
+	tenant, err := s.tenants.GetTenantByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.keys.ListTenantAPIKeys(ctx, tenant.ID)
+}
+
+// RevokeKey revokes one of the tenant's keys
+func (s *Service) RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	if _, err := s.tenantKey(ctx, userID, keyID); err != nil {
+		return err
+	}
+	return s.keys.RevokeAPIKey(ctx, keyID, &userID)
+}
+
+// KeyUsage reports one key's requests per endpoint over the window, which
+// defaults to the last 24 hours
+func (s *Service) KeyUsage(ctx context.Context, userID, keyID uuid.UUID, since, until time.Time) (*entities.APIUsageReport, error) {
+	if _, err := s.tenantKey(ctx, userID, keyID); err != nil {
+		return nil, err
+	}
+	return s.usage.Report(ctx, entities.APIUsageFilter{
+		APIKeyID: &keyID,
+		Since:    since,
+		Until:    until,
+		GroupBy:  entities.APIUsageByEndpoint,
+		Limit:    200,
+	})
+}
+
+// RegisterWebhook registers a webhook endpoint for the tenant's sandbox and
+// returns its signing secret
+func (s *Service) RegisterWebhook(ctx context.Context, userID uuid.UUID, req *entities.CreateWebhookEndpointRequest) (*entities.WebhookEndpointWithSecret, error) {
+	tenant, err := s.tenants.GetTenantByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.webhooks.ListTenantEndpoints(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= s.config.MaxWebhookEndpoints {
+		return nil, fmt.Errorf("%w: delete an endpoint before registering another (limit %d)", ErrWebhookLimitReached, s.config.MaxWebhookEndpoints)
+	}
+	return s.webhooks.CreateTenantEndpoint(ctx, tenant.ID, req, &userID)
+}
+
+// ListWebhooks returns the tenant's webhook endpoints
+func (s *Service) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]*entities.WebhookEndpoint, error) {
+	tenant, err := s.tenants.GetTenantByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhooks.ListTenantEndpoints(ctx, tenant.ID)
+}
+
+// DeleteWebhook removes one of the tenant's webhook endpoints
+func (s *Service) DeleteWebhook(ctx context.Context, userID, endpointID uuid.UUID) error {
+	if _, err := s.tenantEndpoint(ctx, userID, endpointID); err != nil {
+		return err
+	}
+	return s.webhooks.DeleteEndpoint(ctx, endpointID)
+}
+
+// TestWebhook queues a ping to one of the tenant's webhook endpoints
+func (s *Service) TestWebhook(ctx context.Context, userID, endpointID uuid.UUID) (*entities.WebhookDelivery, error) {
+	if _, err := s.tenantEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+	return s.webhooks.SendTestEvent(ctx, endpointID)
+}
+
+func (s *Service) tenantKey(ctx context.Context, userID, keyID uuid.UUID) (*apikey.APIKey, error) {
+	keys, err := s.ListKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.ID == keyID {
+			return key, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// tenantEndpoint returns the endpoint if it belongs to the user's tenant.
+// Other endpoints are reported as not found rather than forbidden.
+func (s *Service) tenantEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*entities.WebhookEndpoint, error) {
+	tenant, err := s.tenants.GetTenantByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := s.webhooks.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.TenantID == nil || *endpoint.TenantID != tenant.ID {
+		return nil, entities.ErrWebhookEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+// allowedScopes checks every requested scope is self-serve and drops
+// duplicates
+func allowedScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidKeyRequest)
+	}
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !isSelfServe(scope) {
+			return nil, fmt.Errorf("%w: %q", ErrScopeNotAllowed, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func isSelfServe(scope string) bool {
+	for _, allowed := range Scopes {
+		if scope == allowed {
+			return true
+		}
+	}
+	return false
+}
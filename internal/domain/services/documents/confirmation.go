@@ -0,0 +1,107 @@
+package documents
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ConfirmationTitle names the confirmation of an order in listings
+func ConfirmationTitle(order *entities.Order, executedAt time.Time) string {
+	return fmt.Sprintf("Trade confirmation: %s $%s on %s",
+		order.Side, order.Amount.StringFixed(2), executedAt.Format("2006-01-02"))
+}
+
+// Capacity is the capacity the broker acted in on every order. Whole shares
+// are routed to the market as agent; the fractional remainder is filled from
+// the broker's own inventory as riskless principal.
+const Capacity = "Agent (fractional shares as riskless principal)"
+
+// confirmationDisclosures are printed at the foot of every confirmation, as
+// Rule 10b-10 requires
+var confirmationDisclosures = []string{
+	"Prices are the average execution price for each security; the time of each execution is available on request.",
+	"Regulatory fees are the SEC Section 31 fee and the FINRA Trading Activity Fee, charged on sales only.",
+	"Payment for order flow may be received for routing orders; the source and nature of any payment is available on request.",
+	"Please review this confirmation and report any discrepancy within 10 days.",
+}
+
+// RenderTradeConfirmation writes the plain-text confirmation of a filled
+// order: its terms, the capacity the broker acted in, every fill with the
+// total filled value, the fees charged and the required disclosures. Fills
+// whose quantity or price do not parse are listed as reported and left out
+// of the total.
+func RenderTradeConfirmation(order *entities.Order, fills []entities.BrokerageFill, fees entities.ExecutionFees, executedAt time.Time) string {
+	var b strings.Builder
+	b.WriteString("TRADE CONFIRMATION\n\n")
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Order ID:\t%s\n", order.ID)
+	fmt.Fprintf(w, "Basket ID:\t%s\n", order.BasketID)
+	fmt.Fprintf(w, "Side:\t%s\n", order.Side)
+	orderType := string(order.Type)
+	if order.Type == entities.OrderTypeLimit && order.LimitPrice != nil {
+		orderType += " @ " + order.LimitPrice.StringFixed(2)
+	}
+	fmt.Fprintf(w, "Order type:\t%s\n", orderType)
+	if order.TimeInForce != "" {
+		fmt.Fprintf(w, "Time in force:\t%s\n", strings.ToUpper(string(order.TimeInForce)))
+	}
+	fmt.Fprintf(w, "Order amount:\t$%s\n", order.Amount.StringFixed(2))
+	fmt.Fprintf(w, "Executed at:\t%s\n", executedAt.UTC().Format(time.RFC1123))
+	fmt.Fprintf(w, "Capacity:\t%s\n", Capacity)
+	w.Flush()
+
+	total := decimal.Zero
+	if len(fills) > 0 {
+		b.WriteString("\nFILLS\n\n")
+		w = tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "Symbol\tQuantity\tPrice\tValue\t")
+		for _, fill := range fills {
+			quantity, qerr := decimal.NewFromString(fill.Quantity)
+			price, perr := decimal.NewFromString(fill.Price)
+			if qerr != nil || perr != nil {
+				fmt.Fprintf(w, "%s\t%s\t%s\t-\t\n", fill.Symbol, fill.Quantity, fill.Price)
+				continue
+			}
+			value := quantity.Mul(price)
+			total = total.Add(value)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", fill.Symbol, quantity.String(), price.StringFixed(2), value.StringFixed(2))
+		}
+		fmt.Fprintf(w, "Total\t\t\t%s\t\n", total.StringFixed(2))
+		w.Flush()
+	}
+
+	b.WriteString("\nFEES\n\n")
+	w = tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Commission:\t$%s\n", fees.Commission.StringFixed(2))
+	fmt.Fprintf(w, "Regulatory fees:\t$%s\n", fees.Regulatory.StringFixed(2))
+	if order.Side == entities.OrderSideSell {
+		fmt.Fprintf(w, "Net proceeds:\t$%s\n", total.Sub(fees.Total()).StringFixed(2))
+	} else {
+		fmt.Fprintf(w, "Total cost:\t$%s\n", total.Add(fees.Total()).StringFixed(2))
+	}
+	w.Flush()
+
+	b.WriteString("\nDISCLOSURES\n\n")
+	for _, disclosure := range confirmationDisclosures {
+		b.WriteString(disclosure)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ConfirmationSummary is the one-line description of a fill sent by push
+func ConfirmationSummary(order *entities.Order, fees entities.ExecutionFees, executedAt time.Time) string {
+	verb := "Bought"
+	if order.Side == entities.OrderSideSell {
+		verb = "Sold"
+	}
+	return fmt.Sprintf("%s $%s of your basket at %s UTC, fees $%s. Your trade confirmation is in your documents.",
+		verb, order.Amount.StringFixed(2), executedAt.UTC().Format("15:04 Jan 2"), fees.Total().StringFixed(2))
+}
@@ -0,0 +1,380 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// Repository persists documents and read receipts
+type Repository interface {
+	// Create stores a document, returning ErrDocumentExists when its order
+	// already has a trade confirmation
+	Create(ctx context.Context, document *entities.Document) error
+	// List returns the documents the user can see, newest first, without
+	// their body and with the user's read time
+	List(ctx context.Context, userID uuid.UUID, filter entities.DocumentFilter) ([]*entities.Document, error)
+	// Get returns a document the user can see, with its body
+	Get(ctx context.Context, userID, id uuid.UUID) (*entities.Document, error)
+	// ListUnreadMandatory returns mandatory documents the user has not opened
+	ListUnreadMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.Document, error)
+	// RecordReceipt stores the first read of a document and returns it; later
+	// reads return the first receipt unchanged
+	RecordReceipt(ctx context.Context, receipt *entities.DocumentReceipt) (*entities.DocumentReceipt, error)
+	// ListBroadcast returns documents shown to every user, newest first
+	ListBroadcast(ctx context.Context, filter entities.DocumentFilter) ([]*entities.Document, error)
+	ListReceipts(ctx context.Context, documentID uuid.UUID, limit, offset int) ([]*entities.DocumentReceipt, error)
+}
+
+// AgreementSource reports the user's standing on each agreement
+type AgreementSource interface {
+	Status(ctx context.Context, userID uuid.UUID) ([]*entities.ConsentStatus, error)
+}
+
+// RegionResolver returns the region a user's documents must be stored in
+type RegionResolver interface {
+	Region(ctx context.Context, userID uuid.UUID) (entities.ResidencyRegion, error)
+	HomeRegion() entities.ResidencyRegion
+}
+
+// Notifier delivers notifications through the notification dispatcher
+type Notifier interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// Service runs the in-app document center. Admins publish prospectus links
+// and disclosures, to every user or to one; trade confirmations are
+// generated as orders fill; agreements are shown from their published
+// versions. Opening a document records a read receipt, and mandatory
+// documents stay pending until the user has opened them.
+type Service struct {
+	repo       Repository
+	agreements AgreementSource
+	notifier   Notifier
+	residency  RegionResolver
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewService creates a new document center service
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetAgreementSource lists agreement versions alongside other documents
+func (s *Service) SetAgreementSource(agreements AgreementSource) {
+	s.agreements = agreements
+}
+
+// SetNotifier sends each trade confirmation to the user by email and push
+// once it is filed
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetResidency tags documents with the region they are stored in and keeps
+// per-user documents out of the store when it is outside the user's region
+func (s *Service) SetResidency(residency RegionResolver) {
+	s.residency = residency
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Publish adds a linked document to the document center
+func (s *Service) Publish(ctx context.Context, req *entities.PublishDocumentRequest, adminID uuid.UUID) (*entities.Document, error) {
+	if !req.Category.IsPublishable() {
+		return nil, fmt.Errorf("%w: %q documents cannot be published", entities.ErrInvalidDocument, req.Category)
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: title is required", entities.ErrInvalidDocument)
+	}
+	url := strings.TrimSpace(req.URL)
+	if url == "" {
+		return nil, fmt.Errorf("%w: url is required", entities.ErrInvalidDocument)
+	}
+
+	document := &entities.Document{
+		ID:          uuid.New(),
+		UserID:      req.UserID,
+		Category:    req.Category,
+		Title:       title,
+		Description: strings.TrimSpace(req.Description),
+		URL:         url,
+		ContentType: "text/html",
+		Mandatory:   req.Mandatory,
+		PublishedBy: &adminID,
+		PublishedAt: s.now().UTC(),
+	}
+	if err := s.placeDocument(ctx, document); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, document); err != nil {
+		return nil, fmt.Errorf("failed to publish document: %w", err)
+	}
+
+	s.logger.Info("Document published",
+		zap.String("document_id", document.ID.String()),
+		zap.String("category", string(document.Category)),
+		zap.Bool("mandatory", document.Mandatory),
+		zap.Bool("broadcast", document.UserID == nil))
+	return document, nil
+}
+
+// List returns the user's documents, newest first. The first page of an
+// unfiltered or agreement listing starts with the current agreements.
+func (s *Service) List(ctx context.Context, userID uuid.UUID, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	if filter.Category != "" && !filter.Category.IsValid() {
+		return nil, fmt.Errorf("%w: unknown category %q", entities.ErrInvalidDocument, filter.Category)
+	}
+	filter.Limit, filter.Offset = page(filter.Limit, filter.Offset)
+
+	var documents []*entities.Document
+	if filter.Offset == 0 && (filter.Category == "" || filter.Category == entities.DocumentCategoryAgreement) {
+		agreements, err := s.agreementDocuments(ctx, userID, false)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, agreements...)
+	}
+
+	stored, err := s.repo.List(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	documents = append(documents, stored...)
+	if documents == nil {
+		documents = []*entities.Document{}
+	}
+	return documents, nil
+}
+
+// Get returns one of the user's documents with its body
+func (s *Service) Get(ctx context.Context, userID, id uuid.UUID) (*entities.Document, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+// MarkRead records that the user opened a document. Agreements are
+// acknowledged by accepting them through consents instead.
+func (s *Service) MarkRead(ctx context.Context, userID, id uuid.UUID, ipAddress, userAgent string) (*entities.DocumentReceipt, error) {
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	receipt, err := s.repo.RecordReceipt(ctx, &entities.DocumentReceipt{
+		DocumentID: id,
+		UserID:     userID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		ReadAt:     s.now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record read receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// Pending returns the mandatory documents the user has not opened and the
+// agreement versions waiting for acceptance
+func (s *Service) Pending(ctx context.Context, userID uuid.UUID) ([]*entities.Document, error) {
+	pending, err := s.agreementDocuments(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.ListUnreadMandatory(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unread documents: %w", err)
+	}
+	pending = append(pending, unread...)
+	if pending == nil {
+		pending = []*entities.Document{}
+	}
+	return pending, nil
+}
+
+// ListPublished returns documents shown to every user, for admins
+func (s *Service) ListPublished(ctx context.Context, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	if filter.Category != "" && !filter.Category.IsValid() {
+		return nil, fmt.Errorf("%w: unknown category %q", entities.ErrInvalidDocument, filter.Category)
+	}
+	filter.Limit, filter.Offset = page(filter.Limit, filter.Offset)
+	return s.repo.ListBroadcast(ctx, filter)
+}
+
+// ListReceipts returns who has opened a document, for admins
+func (s *Service) ListReceipts(ctx context.Context, documentID uuid.UUID, limit, offset int) ([]*entities.DocumentReceipt, error) {
+	limit, offset = page(limit, offset)
+	return s.repo.ListReceipts(ctx, documentID, limit, offset)
+}
+
+// GenerateTradeConfirmation files the confirmation of a filled order in the
+// user's document center and sends it to the user. A confirmation is
+// generated once per order, so a redelivered fill is a no-op.
+func (s *Service) GenerateTradeConfirmation(ctx context.Context, order *entities.Order, fills []entities.BrokerageFill, fees entities.ExecutionFees) error {
+	if order.Paper {
+		return nil
+	}
+	executedAt := s.now().UTC()
+	userID, orderID := order.UserID, order.ID
+	document := &entities.Document{
+		ID:          uuid.New(),
+		UserID:      &userID,
+		Category:    entities.DocumentCategoryTradeConfirmation,
+		Title:       ConfirmationTitle(order, executedAt),
+		Body:        RenderTradeConfirmation(order, fills, fees, executedAt),
+		ContentType: "text/plain",
+		OrderID:     &orderID,
+		PublishedAt: executedAt,
+	}
+	// A confirmation must be delivered, so one that cannot be stored in the
+	// user's region is filed anyway and left for the out-of-region report
+	if err := s.placeDocument(ctx, document); errors.Is(err, entities.ErrOutOfRegion) {
+		s.logger.Warn("Trade confirmation stored outside the user's data region",
+			zap.String("order_id", order.ID.String()),
+			zap.Error(err))
+	} else if err != nil {
+		return err
+	}
+	err := s.repo.Create(ctx, document)
+	if errors.Is(err, entities.ErrDocumentExists) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store trade confirmation: %w", err)
+	}
+
+	s.logger.Info("Trade confirmation generated",
+		zap.String("order_id", order.ID.String()),
+		zap.String("document_id", document.ID.String()))
+	s.sendTradeConfirmation(ctx, order, document, fees)
+	return nil
+}
+
+// sendTradeConfirmation emails the full confirmation and pushes a summary.
+// The email is critical so it goes out whatever the user's email settings,
+// as the confirmation must be delivered; the push follows their settings.
+// A failed send is logged, as the confirmation is already filed.
+func (s *Service) sendTradeConfirmation(ctx context.Context, order *entities.Order, document *entities.Document, fees entities.ExecutionFees) {
+	if s.notifier == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"order_id":    order.ID.String(),
+		"document_id": document.ID.String(),
+	}
+	notifications := []*entities.Notification{
+		{
+			Channel:  entities.ChannelEmail,
+			Priority: entities.PriorityCritical,
+			Message:  document.Body,
+		},
+		{
+			Channel:  entities.ChannelPush,
+			Priority: entities.PriorityMedium,
+			Message:  ConfirmationSummary(order, fees, document.PublishedAt),
+		},
+	}
+	for _, notification := range notifications {
+		notification.ID = uuid.New()
+		notification.UserID = order.UserID
+		notification.Type = entities.NotificationTypeTrade
+		notification.Title = document.Title
+		notification.Data = data
+		notification.CreatedAt = document.PublishedAt
+		if err := s.notifier.Send(ctx, notification, nil); err != nil {
+			s.logger.Warn("Failed to send trade confirmation",
+				zap.String("order_id", order.ID.String()),
+				zap.String("channel", string(notification.Channel)),
+				zap.Error(err))
+		}
+	}
+}
+
+// placeDocument tags the document with the region it is stored in. A
+// per-user document for a user whose data must stay in another region gets
+// ErrOutOfRegion; documents every user sees have no region to keep to.
+func (s *Service) placeDocument(ctx context.Context, document *entities.Document) error {
+	if s.residency == nil {
+		return nil
+	}
+	document.StorageRegion = s.residency.HomeRegion()
+	if document.UserID == nil {
+		return nil
+	}
+	region, err := s.residency.Region(ctx, *document.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user data region: %w", err)
+	}
+	if region != document.StorageRegion {
+		return fmt.Errorf("%w: documents are stored in %s, the user's data must stay in %s", entities.ErrOutOfRegion, document.StorageRegion, region)
+	}
+	return nil
+}
+
+// agreementDocuments shows the current version of each agreement as a
+// document, read once the user accepted that version
+func (s *Service) agreementDocuments(ctx context.Context, userID uuid.UUID, pendingOnly bool) ([]*entities.Document, error) {
+	if s.agreements == nil {
+		return nil, nil
+	}
+	statuses, err := s.agreements.Status(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agreement status: %w", err)
+	}
+
+	var documents []*entities.Document
+	for _, status := range statuses {
+		if pendingOnly && !status.RequiresAcceptance {
+			continue
+		}
+		current := status.Current
+		document := &entities.Document{
+			ID:          current.ID,
+			Category:    entities.DocumentCategoryAgreement,
+			Title:       current.Title,
+			Description: fmt.Sprintf("Version %s", current.Version),
+			URL:         current.DocumentURL,
+			ContentType: "text/html",
+			Mandatory:   current.Mandatory,
+			PublishedBy: current.PublishedBy,
+			PublishedAt: current.PublishedAt,
+		}
+		if status.Accepted != nil && status.Accepted.AgreementVersionID == current.ID {
+			acceptedAt := status.Accepted.AcceptedAt
+			document.ReadAt = &acceptedAt
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+func page(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
 )
 
 // DueService handles Due API integration
@@ -80,15 +82,14 @@ func (s *DueService) LinkCircleWallet(ctx context.Context, walletAddress, chain
 	s.logger.Info("Linking Circle wallet to Due", "address", walletAddress, "chain", chain)
 
 	// Format address according to Due requirements
-	var formattedAddress string
-	switch chain {
-	case "SOL-DEVNET", "SOL":
-		formattedAddress = fmt.Sprintf("solana:%s", walletAddress)
-	case "MATIC", "MATIC-AMOY", "ETH", "ETH-SEPOLIA", "BASE", "BASE-SEPOLIA":
-		formattedAddress = fmt.Sprintf("evm:%s", walletAddress)
-	default:
+	info, ok := entities.WalletChain(chain).Info()
+	if !ok {
 		return fmt.Errorf("unsupported chain: %s", chain)
 	}
+	if _, err := info.CheckAddress(walletAddress); err != nil {
+		return fmt.Errorf("invalid wallet address: %w", err)
+	}
+	formattedAddress := fmt.Sprintf("%s:%s", info.Family.DueSchema(), walletAddress)
 
 	req := &due.LinkWalletRequest{
 		Address: formattedAddress,
@@ -134,15 +135,11 @@ func (s *DueService) CreateUSDCToUSDVirtualAccount(ctx context.Context, userID u
 	reference := fmt.Sprintf("user_%s_%s_usdc_usd", userID.String()[:8], chain)
 
 	// Determine schema based on chain
-	var schemaIn string
-	switch chain {
-	case "SOL-DEVNET", "SOL":
-		schemaIn = "solana"
-	case "MATIC", "MATIC-AMOY":
-		schemaIn = "evm"
-	default:
+	info, ok := entities.WalletChain(chain).Info()
+	if !ok {
 		return nil, fmt.Errorf("unsupported chain for virtual account: %s", chain)
 	}
+	schemaIn := info.Family.DueSchema()
 
 	req := &due.CreateVirtualAccountRequest{
 		Destination: recipientID,
@@ -417,6 +414,7 @@ func (s *DueService) HandleVirtualAccountDeposit(ctx context.Context, virtualAcc
 	// Check if deposit already exists by off-ramp transaction ID
 	existingDeposit, err := s.depositRepo.GetByOffRampTxID(ctx, transactionID)
 	if err == nil && existingDeposit != nil {
+		metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceDue).Inc()
 		s.logger.Info("Deposit already processed", "transaction_id", transactionID)
 		return nil
 	}
@@ -452,6 +450,12 @@ func (s *DueService) HandleVirtualAccountDeposit(ctx context.Context, virtualAcc
 	}
 
 	if err := s.depositRepo.Create(ctx, deposit); err != nil {
+		if errors.Is(err, entities.ErrDuplicateDeposit) {
+			// A replay of the same webhook recorded it first and credits it
+			metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceDue).Inc()
+			s.logger.Info("Deposit already processed", "transaction_id", transactionID)
+			return nil
+		}
 		s.logger.Error("Failed to create deposit record", "error", err)
 		return fmt.Errorf("create deposit: %w", err)
 	}
@@ -465,6 +469,6 @@ func (s *DueService) HandleVirtualAccountDeposit(ctx context.Context, virtualAcc
 	s.logger.Info("Virtual account deposit handled successfully",
 		"transaction_id", transactionID,
 		"user_id", userID.String(),
-		"amount", depositAmount.String())
+		"amount", logger.RedactAmount(depositAmount))
 	return nil
 }
@@ -0,0 +1,365 @@
+package edd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Repository persists submissions and the KYC tier of user accounts
+type Repository interface {
+	GetAccount(ctx context.Context, userID uuid.UUID) (*entities.AccountKYCTier, error)
+	// ApplyTier sets the user's tier and replaces the matching transaction limits
+	ApplyTier(ctx context.Context, userID uuid.UUID, tier entities.KYCTier, limits []entities.TierLimit) error
+	// Create inserts a submission unless the user already has one awaiting
+	// review, in which case the existing submission is returned with created=false
+	Create(ctx context.Context, submission *entities.EDDSubmission) (*entities.EDDSubmission, bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.EDDSubmission, error)
+	GetLatestByUser(ctx context.Context, userID uuid.UUID) (*entities.EDDSubmission, error)
+	List(ctx context.Context, status entities.EDDStatus, limit, offset int) ([]*entities.EDDSubmission, error)
+	Update(ctx context.Context, submission *entities.EDDSubmission) error
+}
+
+// CaseService opens and resolves admin review cases
+type CaseService interface {
+	Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error)
+	Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error)
+}
+
+// Provider forwards proof-of-funds documents to the KYC provider's enhanced
+// verification level for an applicant that already passed basic KYC.
+// Providers without one return entities.ErrEDDProviderUnsupported and
+// compliance reviews the documents by hand.
+type Provider interface {
+	SubmitEnhancedDueDiligence(ctx context.Context, providerRef string, documents []entities.KYCDocumentUpload) error
+}
+
+// DocumentResolver turns references to secure uploads into links the KYC
+// provider can fetch
+type DocumentResolver interface {
+	ResolveDocuments(ctx context.Context, userID uuid.UUID, purpose entities.UploadPurpose, documents []entities.KYCDocumentUpload) ([]entities.KYCDocumentUpload, error)
+}
+
+// Config configures the enhanced due diligence rollout
+type Config struct {
+	Enabled        bool                 // Accept submissions at all
+	RolloutPercent int                  // Share of users, 0-100, who can apply
+	Limits         []entities.TierLimit // Limits granted by the enhanced tier
+}
+
+// DefaultConfig keeps the flow off and grants enhanced limits of $50,000 a
+// day in deposits and trades and $25,000 a day in withdrawals once enabled
+func DefaultConfig() Config {
+	return Config{
+		Enabled:        false,
+		RolloutPercent: 0,
+		Limits:         DefaultLimits(50000, 25000, 50000),
+	}
+}
+
+// DefaultLimits builds the daily deposit, withdrawal and trade limits of the
+// enhanced tier
+func DefaultLimits(deposit, withdrawal, trade float64) []entities.TierLimit {
+	return []entities.TierLimit{
+		{LimitType: entities.LimitTypeDeposit, Period: entities.LimitPeriodDaily, MaxAmount: decimal.NewFromFloat(deposit)},
+		{LimitType: entities.LimitTypeWithdrawal, Period: entities.LimitPeriodDaily, MaxAmount: decimal.NewFromFloat(withdrawal)},
+		{LimitType: entities.LimitTypeTrade, Period: entities.LimitPeriodDaily, MaxAmount: decimal.NewFromFloat(trade)},
+	}
+}
+
+// Service runs enhanced due diligence for users who want higher limits. A
+// verified user submits proof-of-funds documents, which are forwarded to the
+// KYC provider and routed to the compliance case queue. Approval moves the
+// user to the enhanced tier and raises their transaction limits. The flow is
+// rolled out to a stable percentage of users, bucketed by user ID, so it can
+// be widened without users flickering in and out.
+type Service struct {
+	repo     Repository
+	cases    CaseService
+	provider Provider
+	resolver DocumentResolver
+	config   Config
+	logger   *zap.Logger
+}
+
+// NewService creates a new enhanced due diligence service
+func NewService(repo Repository, cases CaseService, config Config, logger *zap.Logger) *Service {
+	if config.RolloutPercent < 0 {
+		config.RolloutPercent = 0
+	}
+	if config.RolloutPercent > 100 {
+		config.RolloutPercent = 100
+	}
+	return &Service{
+		repo:   repo,
+		cases:  cases,
+		config: config,
+		logger: logger,
+	}
+}
+
+// SetProvider forwards submissions to the KYC provider
+func (s *Service) SetProvider(provider Provider) {
+	s.provider = provider
+}
+
+// SetDocumentResolver lets proof-of-funds documents reference secure uploads
+func (s *Service) SetDocumentResolver(resolver DocumentResolver) {
+	s.resolver = resolver
+}
+
+// Available reports whether the user is in the rollout
+func (s *Service) Available(userID uuid.UUID) bool {
+	if !s.config.Enabled {
+		return false
+	}
+	return rolloutBucket(userID) < s.config.RolloutPercent
+}
+
+// Overview returns whether the user can apply, their tier and their latest
+// submission
+func (s *Service) Overview(ctx context.Context, userID uuid.UUID) (*entities.EDDOverview, error) {
+	account, err := s.repo.GetAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	overview := &entities.EDDOverview{
+		Available: s.Available(userID) && account.KYCStatus == entities.KYCStatusApproved,
+		Tier:      account.Tier,
+	}
+
+	latest, err := s.repo.GetLatestByUser(ctx, userID)
+	if err != nil && !errors.Is(err, entities.ErrEDDSubmissionNotFound) {
+		return nil, err
+	}
+	overview.Submission = latest
+	return overview, nil
+}
+
+// Submit records a request for higher limits and queues it for compliance,
+// or returns the submission already awaiting review
+func (s *Service) Submit(ctx context.Context, userID uuid.UUID, req *entities.SubmitEDDRequest) (*entities.EDDSubmission, error) {
+	if !s.Available(userID) {
+		return nil, entities.ErrEDDUnavailable
+	}
+	documents := req.Documents
+	if s.resolver != nil {
+		resolved, err := s.resolver.ResolveDocuments(ctx, userID, entities.UploadPurposeEDD, documents)
+		if err != nil {
+			return nil, err
+		}
+		documents = resolved
+	}
+	if err := validateDocuments(documents); err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account.KYCStatus != entities.KYCStatusApproved {
+		return nil, entities.ErrEDDRequiresKYC
+	}
+	if account.Tier == entities.KYCTierEnhanced {
+		return nil, entities.ErrEDDAlreadyEnhanced
+	}
+
+	now := time.Now()
+	submission, created, err := s.repo.Create(ctx, &entities.EDDSubmission{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Status:        entities.EDDPendingReview,
+		SourceOfFunds: req.SourceOfFunds,
+		Documents:     entities.StoredDocuments(documents),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.logger.Info("Enhanced due diligence submitted",
+			zap.String("submission_id", submission.ID.String()),
+			zap.String("user_id", userID.String()),
+			zap.Int("document_count", len(submission.Documents)))
+		s.forward(ctx, submission, account, documents)
+	}
+
+	// A submission left without a case by an earlier failure is queued on retry
+	if submission.CaseID == nil {
+		if err := s.openCase(ctx, submission); err != nil {
+			return nil, err
+		}
+	}
+	return submission, nil
+}
+
+// Approve moves the user to the enhanced tier and raises their limits
+func (s *Service) Approve(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.EDDSubmission, error) {
+	submission, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ApplyTier(ctx, submission.UserID, entities.KYCTierEnhanced, s.config.Limits); err != nil {
+		return nil, fmt.Errorf("failed to apply enhanced tier: %w", err)
+	}
+	if err := s.review(ctx, submission, entities.EDDApproved, adminID, notes, nil); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Enhanced due diligence approved",
+		zap.String("submission_id", submission.ID.String()),
+		zap.String("user_id", submission.UserID.String()),
+		zap.String("admin_id", adminID.String()))
+	s.resolveCase(ctx, submission, adminID, "Enhanced limits approved")
+	return submission, nil
+}
+
+// Reject declines a submission; the user keeps their current limits and may
+// apply again
+func (s *Service) Reject(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.EDDSubmission, error) {
+	submission, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "The documents provided did not support the requested limits"
+	if err := s.review(ctx, submission, entities.EDDRejected, adminID, notes, &reason); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Enhanced due diligence rejected",
+		zap.String("submission_id", submission.ID.String()),
+		zap.String("user_id", submission.UserID.String()),
+		zap.String("admin_id", adminID.String()))
+	s.resolveCase(ctx, submission, adminID, "Enhanced limits rejected")
+	return submission, nil
+}
+
+// Get returns a submission
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.EDDSubmission, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns submissions filtered by optional status
+func (s *Service) List(ctx context.Context, status entities.EDDStatus, limit, offset int) ([]*entities.EDDSubmission, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+func (s *Service) pending(ctx context.Context, id uuid.UUID) (*entities.EDDSubmission, error) {
+	submission, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if submission.Status != entities.EDDPendingReview {
+		return nil, entities.ErrEDDNotPending
+	}
+	return submission, nil
+}
+
+func (s *Service) review(ctx context.Context, submission *entities.EDDSubmission, status entities.EDDStatus, adminID uuid.UUID, notes, reason *string) error {
+	now := time.Now()
+	submission.Status = status
+	submission.RejectionReason = reason
+	submission.ReviewedBy = &adminID
+	submission.ReviewNotes = notes
+	submission.ReviewedAt = &now
+	submission.UpdatedAt = now
+	return s.repo.Update(ctx, submission)
+}
+
+// forward sends the resolved documents to the KYC provider. Failures are
+// logged and left to compliance, who see the documents on the case either way.
+func (s *Service) forward(ctx context.Context, submission *entities.EDDSubmission, account *entities.AccountKYCTier, documents []entities.KYCDocumentUpload) {
+	if s.provider == nil || account.KYCProviderRef == nil {
+		return
+	}
+	err := s.provider.SubmitEnhancedDueDiligence(ctx, *account.KYCProviderRef, documents)
+	if err != nil {
+		if !errors.Is(err, entities.ErrEDDProviderUnsupported) {
+			s.logger.Warn("Failed to forward enhanced due diligence to KYC provider",
+				zap.String("submission_id", submission.ID.String()), zap.Error(err))
+		}
+		return
+	}
+	submission.ProviderRef = account.KYCProviderRef
+}
+
+func (s *Service) openCase(ctx context.Context, submission *entities.EDDSubmission) error {
+	documentTypes := make([]string, 0, len(submission.Documents))
+	for _, doc := range submission.Documents {
+		documentTypes = append(documentTypes, doc.Type)
+	}
+	details := map[string]interface{}{
+		"edd_submission_id": submission.ID.String(),
+		"source_of_funds":   submission.SourceOfFunds,
+		"document_types":    documentTypes,
+	}
+	if submission.ProviderRef != nil {
+		details["provider_ref"] = *submission.ProviderRef
+	}
+
+	adminCase, err := s.cases.Open(ctx, submission.UserID, entities.AdminCaseEnhancedDueDiligence,
+		"User requested higher limits with proof of funds", details)
+	if err != nil {
+		return fmt.Errorf("failed to open enhanced due diligence case: %w", err)
+	}
+	submission.CaseID = &adminCase.ID
+	submission.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, submission)
+}
+
+func (s *Service) resolveCase(ctx context.Context, submission *entities.EDDSubmission, adminID uuid.UUID, resolution string) {
+	if submission.CaseID == nil {
+		return
+	}
+	if _, err := s.cases.Update(ctx, *submission.CaseID, &entities.UpdateAdminCaseRequest{
+		Status:          entities.AdminCaseResolved,
+		ResolutionNotes: &resolution,
+	}, adminID); err != nil {
+		s.logger.Warn("Failed to resolve enhanced due diligence case", zap.String("case_id", submission.CaseID.String()), zap.Error(err))
+	}
+}
+
+// validateDocuments requires known document types and at least one proof of funds
+func validateDocuments(documents []entities.KYCDocumentUpload) error {
+	hasProofOfFunds := false
+	for _, doc := range documents {
+		if !entities.IsEDDDocumentType(doc.Type) {
+			return fmt.Errorf("%w: unsupported type %q", entities.ErrInvalidEDDDocument, doc.Type)
+		}
+		if doc.FileURL == "" || doc.ContentType == "" {
+			return fmt.Errorf("%w: %s needs a file URL and content type", entities.ErrInvalidEDDDocument, doc.Type)
+		}
+		if doc.Type == entities.EDDDocumentProofOfFunds {
+			hasProofOfFunds = true
+		}
+	}
+	if !hasProofOfFunds {
+		return entities.ErrEDDProofOfFunds
+	}
+	return nil
+}
+
+// rolloutBucket places a user in one of 100 stable buckets
+func rolloutBucket(userID uuid.UUID) int {
+	h := fnv.New32a()
+	h.Write(userID[:])
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,81 @@
+package entitysecret
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Sealer encrypts the entity secret at rest with keys from the secrets
+// manager. crypto.FieldEncryptor satisfies it.
+type Sealer interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+type sealedFile struct {
+	SealedSecret string    `json:"sealed_secret"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WriteSealedFile writes the entity secret to path encrypted by sealer. An
+// existing file is never overwritten, since replacing a registered secret
+// locks the entity out of Circle.
+func WriteSealedFile(path string, secret []byte, sealer Sealer) error {
+	sealed, err := sealer.Encrypt(hex.EncodeToString(secret))
+	if err != nil {
+		return fmt.Errorf("failed to seal entity secret: %w", err)
+	}
+	data, err := json.MarshalIndent(sealedFile{SealedSecret: sealed, CreatedAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sealed entity secret: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create sealed entity secret file: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write sealed entity secret file: %w", err)
+	}
+	return file.Close()
+}
+
+// ReadSealedFile reads and decrypts an entity secret written by WriteSealedFile
+func ReadSealedFile(path string, sealer Sealer) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sealed entity secret file: %w", err)
+	}
+	var file sealedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode sealed entity secret file: %w", err)
+	}
+
+	plaintext, err := sealer.Decrypt(file.SealedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal entity secret: %w", err)
+	}
+	// Decrypt passes unencrypted values through; a plaintext secret in the
+	// file is rejected rather than trusted
+	if plaintext == file.SealedSecret {
+		return nil, errors.New("entity secret file is not encrypted")
+	}
+	return ParseSecret(plaintext)
+}
+
+// LoadSealedFile loads the entity secret from a sealed file
+func (s *Service) LoadSealedFile(path string, sealer Sealer) error {
+	secret, err := ReadSealedFile(path, sealer)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret = secret
+	return nil
+}
@@ -11,13 +11,34 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 
 	"go.uber.org/zap"
 )
 
-// Service handles entity secret encryption for Circle API requests
+// SecretSize is the length in bytes of a Circle entity secret
+const SecretSize = 32
+
+// ErrSecretNotConfigured is returned when ciphertext is requested before an
+// entity secret has been loaded
+var ErrSecretNotConfigured = errors.New("circle entity secret is not configured")
+
+// PublicKeySource fetches the entity public key from Circle
+type PublicKeySource interface {
+	GetEntityPublicKey(ctx context.Context) (string, error)
+}
+
+// Service handles entity secret encryption for Circle API requests. Circle
+// expects a fresh RSA-OAEP encryption of the registered entity secret on every
+// request, so the secret is held in memory and re-encrypted each time. The
+// secret is loaded from the secrets manager at startup and never logged.
 type Service struct {
-	logger *zap.Logger
+	secret    []byte
+	publicKey *rsa.PublicKey
+	keySource PublicKeySource
+	mu        sync.Mutex
+	logger    *zap.Logger
 }
 
 // NewService creates a new EntitySecretService
@@ -27,60 +48,125 @@ func NewService(logger *zap.Logger) *Service {
 	}
 }
 
-const (
-	publicKeyPEM = `-----BEGIN PUBLIC KEY-----
-MIICIjANBgkqhkiG9w0BAQEFAAOCAg8AMIICCgKCAgEAxQsczKCXuMCgyGYff2tZ
-xR+ZUW8MBvgwmbFkGTmyoenSC6X/5o5BPPkPZTIZs/oC8ouOdAKijOYsUP3+qdc+
-mzjx2lIHnQN1TtNQ2Vm93Hk+G6vEFHDsYsb0nchk+7V5Pbki3ynOnfsV6LRbaFCf
-cgTGxHSSmKbnItW3qAiVluPPoPBx4WbQNyeS5TREv0R1NC1U311rxLGbxl+bjb73
-fFzlvSkGe2UyPs8tJnAYhqpvFOQv1SdXDvGbfwM5lBfqjCGMlkHkYYwsgLYl4R/R
-x01ncZvYjgYwXAungJMRpD9aUBSt8f4pDDlUxoXq294y7hCSi6aNGoDPqDyAaqoN
-2rSYbswGZmCz5ivJLHZNFP9qCwoKeL1l9+VlDrKs+nhRmrhCoXG0OOUdTbpkU4Ff
-oUjh4SKR8YPq7TfSGyBe9q5VAF7bEici1FkH9I7+wf41YSq47dU3UOryjbF34fXZ
-dQJ9xBEk1thTDUK8ZmIY8SQwqolSQIAKxsxOf2XoNdk3PiaXJHDTtfEiTtZFybKR
-rWFG4h0GeRPLCy52KAe+nfJmpODKeGmrGgvlA0IVeHDpqv7WNsG/o3G4JBL3odWs
-6qKoMrDhL1W/32EMPObdtUPTtAyTO3HxfXWsUavJ5KLHApoiwDx9Vn7aW5ytBvAV
-6aAk60U2+xWaJJqFlWAx6a8CAwEAAQ==
------END PUBLIC KEY-----`
-	hexEncodedEntitySecret = "dcd90b5d7bfd4f17222283d14ac0e2ce0d814df1d4f030a37065868113437fdc"
-)
+// GenerateSecret returns a new random entity secret
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate entity secret: %w", err)
+	}
+	return secret, nil
+}
 
-// GenerateEntitySecretCiphertext generates entity secret ciphertext using the static entity secret
-func (s *Service) GenerateEntitySecretCiphertext(ctx context.Context) (string, error) {
-	// Decode the static hex-encoded 32-byte secret
-	entitySecret, err := hex.DecodeString(hexEncodedEntitySecret)
+// ParseSecret decodes a hex-encoded entity secret
+func ParseSecret(hexSecret string) ([]byte, error) {
+	secret, err := hex.DecodeString(strings.TrimSpace(hexSecret))
 	if err != nil {
-		return "", fmt.Errorf("failed to decode entity secret: %w", err)
+		return nil, fmt.Errorf("failed to decode entity secret: %w", err)
 	}
-	if len(entitySecret) != 32 {
-		return "", fmt.Errorf("invalid entity secret length; must be 32 bytes")
+	if len(secret) != SecretSize {
+		return nil, fmt.Errorf("invalid entity secret length; must be %d bytes", SecretSize)
 	}
+	return secret, nil
+}
 
-	// Parse the static public key
-	pubKey, err := s.parseRSAPublicKeyFromPEM([]byte(publicKeyPEM))
+// SetSecret loads the hex-encoded entity secret registered with Circle
+func (s *Service) SetSecret(hexSecret string) error {
+	secret, err := ParseSecret(hexSecret)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse public key: %w", err)
+		return err
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret = secret
+	return nil
+}
 
-	// Encrypt the entity secret
-	cipher, err := s.encryptOAEP(pubKey, entitySecret)
+// Configured reports whether an entity secret has been loaded
+func (s *Service) Configured() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secret != nil
+}
+
+// SetPublicKey pins the entity public key instead of fetching it from Circle
+func (s *Service) SetPublicKey(publicKeyPEM string) error {
+	publicKey, err := ParsePublicKey(publicKeyPEM)
 	if err != nil {
-		return "", fmt.Errorf("failed to encrypt entity secret: %w", err)
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publicKey = publicKey
+	return nil
+}
+
+// SetPublicKeySource fetches the entity public key from Circle on first use
+// when none is pinned
+func (s *Service) SetPublicKeySource(source PublicKeySource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keySource = source
+}
+
+// GenerateEntitySecretCiphertext encrypts the configured entity secret for a
+// Circle API request
+func (s *Service) GenerateEntitySecretCiphertext(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	secret := s.secret
+	s.mu.Unlock()
+	if secret == nil {
+		return "", ErrSecretNotConfigured
 	}
 
-	// Return base64 encoded ciphertext
-	ciphertext := base64.StdEncoding.EncodeToString(cipher)
+	publicKey, err := s.resolvePublicKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	return EncryptSecret(publicKey, secret)
+}
+
+// resolvePublicKey returns the pinned public key, fetching and caching it
+// from Circle if needed
+func (s *Service) resolvePublicKey(ctx context.Context) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	publicKey, source := s.publicKey, s.keySource
+	s.mu.Unlock()
+	if publicKey != nil {
+		return publicKey, nil
+	}
+	if source == nil {
+		return nil, errors.New("circle entity public key is not configured")
+	}
 
-	s.logger.Debug("Generated entity secret ciphertext using static secret",
-		zap.String("entity_secret_hex", hexEncodedEntitySecret),
-		zap.String("ciphertext_length", fmt.Sprintf("%d", len(ciphertext))))
+	publicKeyPEM, err := source.GetEntityPublicKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch entity public key: %w", err)
+	}
+	publicKey, err = ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, err
+	}
 
-	return ciphertext, nil
+	s.mu.Lock()
+	s.publicKey = publicKey
+	s.mu.Unlock()
+	s.logger.Info("Fetched Circle entity public key")
+	return publicKey, nil
 }
 
-// parseRSAPublicKeyFromPEM parses an RSA public key from PEM format.
-func (s *Service) parseRSAPublicKeyFromPEM(pubPEM []byte) (*rsa.PublicKey, error) {
-	block, _ := pem.Decode(pubPEM)
+// EncryptSecret encrypts an entity secret with Circle's public key and returns
+// the base64 ciphertext Circle expects
+func EncryptSecret(publicKey *rsa.PublicKey, secret []byte) (string, error) {
+	cipher, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, secret, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt entity secret: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(cipher), nil
+}
+
+// ParsePublicKey parses an RSA public key from PEM format
+func ParsePublicKey(publicKeyPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
 	if block == nil {
 		return nil, errors.New("failed to parse PEM block containing the key")
 	}
@@ -94,13 +180,3 @@ func (s *Service) parseRSAPublicKeyFromPEM(pubPEM []byte) (*rsa.PublicKey, error
 	}
 	return rsaPub, nil
 }
-
-// encryptOAEP performs RSA-OAEP encryption using SHA-256.
-func (s *Service) encryptOAEP(pubKey *rsa.PublicKey, message []byte) ([]byte, error) {
-	random := rand.Reader
-	ciphertext, err := rsa.EncryptOAEP(sha256.New(), random, pubKey, message, nil)
-	if err != nil {
-		return nil, fmt.Errorf("rsa.EncryptOAEP failed: %w", err)
-	}
-	return ciphertext, nil
-}
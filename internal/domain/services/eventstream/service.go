@@ -0,0 +1,135 @@
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/cache"
+)
+
+// ErrInvalidCursor is returned when a Last-Event-ID is not a stream entry ID
+var ErrInvalidCursor = errors.New("invalid event cursor")
+
+var cursorPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// Stream is the subset of the Redis client used to store per-user event streams
+type Stream interface {
+	StreamAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	StreamRead(ctx context.Context, stream, afterID string, count int64, block time.Duration) ([]cache.StreamMessage, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+}
+
+// Config controls stream retention
+type Config struct {
+	MaxLen    int64         // Approximate number of events kept per user for resume
+	Retention time.Duration // Idle streams expire after this long
+}
+
+// Service is the internal event bus for per-user progress events. Events are
+// appended to a Redis stream per user, so any API instance can serve a user's
+// live connection and clients can resume from the last event they saw.
+type Service struct {
+	stream Stream
+	config Config
+	logger *zap.Logger
+}
+
+// NewService creates a new event stream service
+func NewService(stream Stream, config Config, logger *zap.Logger) *Service {
+	if config.MaxLen <= 0 {
+		config.MaxLen = 500
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+	return &Service{
+		stream: stream,
+		config: config,
+		logger: logger,
+	}
+}
+
+// PublishToUser appends an event to the user's stream
+func (s *Service) PublishToUser(ctx context.Context, userID uuid.UUID, eventType entities.StreamEventType, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+
+	key := streamKey(userID)
+	_, err = s.stream.StreamAdd(ctx, key, s.config.MaxLen, map[string]interface{}{
+		"type":       string(eventType),
+		"data":       string(payload),
+		"created_at": time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish stream event: %w", err)
+	}
+	if err := s.stream.Expire(ctx, key, s.config.Retention); err != nil {
+		s.logger.Warn("Failed to set event stream expiry", zap.Error(err), zap.String("user_id", userID.String()))
+	}
+	return nil
+}
+
+// Read returns the user's events after cursor, waiting up to wait for new ones.
+// Events whose type is not in types are skipped when types is non-empty; the
+// returned cursor still advances past them.
+func (s *Service) Read(ctx context.Context, userID uuid.UUID, cursor string, types map[entities.StreamEventType]bool, wait time.Duration) ([]*entities.StreamEvent, string, error) {
+	if !ValidCursor(cursor) {
+		return nil, cursor, ErrInvalidCursor
+	}
+
+	messages, err := s.stream.StreamRead(ctx, streamKey(userID), cursor, 100, wait)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	events := make([]*entities.StreamEvent, 0, len(messages))
+	for _, msg := range messages {
+		cursor = msg.ID
+		event := decodeEvent(userID, msg)
+		if len(types) > 0 && !types[event.Type] {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, cursor, nil
+}
+
+// StartCursor returns a cursor positioned at now, for clients connecting
+// without a Last-Event-ID that only want new events.
+func StartCursor(now time.Time) string {
+	return strconv.FormatInt(now.UnixMilli(), 10) + "-0"
+}
+
+// ValidCursor reports whether cursor is a Redis stream entry ID
+func ValidCursor(cursor string) bool {
+	return cursorPattern.MatchString(cursor)
+}
+
+func decodeEvent(userID uuid.UUID, msg cache.StreamMessage) *entities.StreamEvent {
+	event := &entities.StreamEvent{ID: msg.ID, UserID: userID}
+	if v, ok := msg.Values["type"].(string); ok {
+		event.Type = entities.StreamEventType(v)
+	}
+	if v, ok := msg.Values["data"].(string); ok {
+		event.Data = json.RawMessage(v)
+	}
+	if v, ok := msg.Values["created_at"].(string); ok {
+		event.CreatedAt, _ = time.Parse(time.RFC3339Nano, v)
+	}
+	return event
+}
+
+func streamKey(userID uuid.UUID) string {
+	return "events:user:" + userID.String()
+}
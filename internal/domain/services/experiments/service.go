@@ -0,0 +1,286 @@
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrInvalidExperiment is returned for experiments or conclusions that are
+// not well formed
+var ErrInvalidExperiment = errors.New("invalid experiment")
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-]*$`)
+
+// Repository persists experiments, assignments and their events
+type Repository interface {
+	Create(ctx context.Context, experiment *entities.Experiment) error
+	Get(ctx context.Context, id uuid.UUID) (*entities.Experiment, error)
+	List(ctx context.Context) ([]*entities.Experiment, error)
+	Conclude(ctx context.Context, id uuid.UUID, winningVariant, reason string, adminID uuid.UUID, at time.Time) (*entities.Experiment, error)
+	ListAssignments(ctx context.Context, userID uuid.UUID) ([]*entities.ExperimentAssignment, error)
+	// AssignAndExpose stores the assignment and its exposure event unless the
+	// user already has one, and returns the variant that is stored
+	AssignAndExpose(ctx context.Context, assignment *entities.ExperimentAssignment) (string, error)
+	// RecordConversion records the goal for every running experiment the
+	// user is assigned to, once per experiment
+	RecordConversion(ctx context.Context, userID uuid.UUID, goal string, at time.Time) (int64, error)
+	Results(ctx context.Context, id uuid.UUID) ([]*entities.ExperimentVariantResult, error)
+}
+
+// Service runs onboarding experiments: it buckets users into variants,
+// records exposures and conversions, and lets product conclude experiments
+type Service struct {
+	repo   Repository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new experiments service
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Create starts an experiment
+func (s *Service) Create(ctx context.Context, adminID uuid.UUID, req *entities.CreateExperimentRequest) (*entities.Experiment, error) {
+	key := strings.ToLower(strings.TrimSpace(req.Key))
+	if !keyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: key must be lowercase letters, digits, '_' or '-'", ErrInvalidExperiment)
+	}
+
+	seen := make(map[string]bool, len(req.Variants))
+	totalWeight := 0
+	variants := make([]entities.ExperimentVariant, 0, len(req.Variants))
+	for _, variant := range req.Variants {
+		variantKey := strings.ToLower(strings.TrimSpace(variant.Key))
+		if !keyPattern.MatchString(variantKey) {
+			return nil, fmt.Errorf("%w: variant %q must be lowercase letters, digits, '_' or '-'", ErrInvalidExperiment, variant.Key)
+		}
+		if seen[variantKey] {
+			return nil, fmt.Errorf("%w: variant %q is listed twice", ErrInvalidExperiment, variantKey)
+		}
+		if variant.Weight < 0 {
+			return nil, fmt.Errorf("%w: variant %q has a negative weight", ErrInvalidExperiment, variantKey)
+		}
+		seen[variantKey] = true
+		totalWeight += variant.Weight
+		variants = append(variants, entities.ExperimentVariant{Key: variantKey, Weight: variant.Weight})
+	}
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("%w: at least two variants are needed", ErrInvalidExperiment)
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("%w: at least one variant needs a positive weight", ErrInvalidExperiment)
+	}
+
+	experiment := &entities.Experiment{
+		ID:          uuid.New(),
+		Key:         key,
+		Description: strings.TrimSpace(req.Description),
+		Variants:    variants,
+		Status:      entities.ExperimentStatusRunning,
+		CreatedBy:   &adminID,
+		CreatedAt:   s.now().UTC(),
+	}
+	if err := s.repo.Create(ctx, experiment); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Experiment started",
+		zap.String("experiment", experiment.Key),
+		zap.String("admin_id", adminID.String()))
+	return experiment, nil
+}
+
+// List returns every experiment with its per-variant results
+func (s *Service) List(ctx context.Context) ([]*entities.Experiment, error) {
+	experiments, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, experiment := range experiments {
+		if err := s.loadResults(ctx, experiment); err != nil {
+			return nil, err
+		}
+	}
+	return experiments, nil
+}
+
+// Get returns an experiment with its per-variant results
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.Experiment, error) {
+	experiment, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadResults(ctx, experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+// Conclude ends an experiment. Assignment is frozen from then on: users
+// already in the experiment keep their variant, new users get the winner,
+// and no further exposures or conversions are recorded, so the results stay
+// as they were when product made the call.
+func (s *Service) Conclude(ctx context.Context, adminID, id uuid.UUID, req *entities.ConcludeExperimentRequest) (*entities.Experiment, error) {
+	experiment, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status == entities.ExperimentStatusConcluded {
+		return nil, entities.ErrExperimentConcluded
+	}
+	winner := strings.ToLower(strings.TrimSpace(req.WinningVariant))
+	if !experiment.HasVariant(winner) {
+		return nil, fmt.Errorf("%w: %q is not a variant of this experiment", ErrInvalidExperiment, req.WinningVariant)
+	}
+
+	concluded, err := s.repo.Conclude(ctx, id, winner, strings.TrimSpace(req.Reason), adminID, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadResults(ctx, concluded); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Experiment concluded",
+		zap.String("experiment", concluded.Key),
+		zap.String("winning_variant", winner),
+		zap.String("admin_id", adminID.String()))
+	return concluded, nil
+}
+
+// Assign returns the user's variant in each experiment, keyed by experiment.
+// Users keep the variant they were first given. When enroll is set, a user
+// new to a running experiment is bucketed into it and exposed; a concluded
+// experiment gives new users its winner without enrolling them.
+func (s *Service) Assign(ctx context.Context, userID uuid.UUID, enroll bool) (map[string]string, error) {
+	experiments, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(experiments) == 0 {
+		return nil, nil
+	}
+	assignments, err := s.repo.ListAssignments(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[uuid.UUID]string, len(assignments))
+	for _, assignment := range assignments {
+		assigned[assignment.ExperimentID] = assignment.Variant
+	}
+
+	variants := make(map[string]string, len(experiments))
+	for _, experiment := range experiments {
+		if variant, ok := assigned[experiment.ID]; ok {
+			variants[experiment.Key] = variant
+			continue
+		}
+		if !enroll {
+			continue
+		}
+		if experiment.Status == entities.ExperimentStatusConcluded {
+			if experiment.WinningVariant != nil {
+				variants[experiment.Key] = *experiment.WinningVariant
+			}
+			continue
+		}
+
+		variant, err := s.repo.AssignAndExpose(ctx, &entities.ExperimentAssignment{
+			ExperimentID: experiment.ID,
+			UserID:       userID,
+			Variant:      bucket(experiment, userID),
+			AssignedAt:   s.now().UTC(),
+		})
+		if err != nil {
+			// Leave the user out of this experiment rather than show a
+			// variant that was not recorded
+			s.logger.Warn("Failed to assign experiment variant",
+				zap.String("experiment", experiment.Key),
+				zap.String("user_id", userID.String()),
+				zap.Error(err))
+			continue
+		}
+		variants[experiment.Key] = variant
+	}
+	return variants, nil
+}
+
+// RecordConversion records that the user reached a goal in every running
+// experiment they are in
+func (s *Service) RecordConversion(ctx context.Context, userID uuid.UUID, goal string) error {
+	recorded, err := s.repo.RecordConversion(ctx, userID, goal, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if recorded > 0 {
+		s.logger.Debug("Recorded experiment conversions",
+			zap.String("user_id", userID.String()),
+			zap.String("goal", goal),
+			zap.Int64("experiments", recorded))
+	}
+	return nil
+}
+
+// loadResults fills in the experiment's results in variant order, with zero
+// counts for variants nobody has been assigned to yet
+func (s *Service) loadResults(ctx context.Context, experiment *entities.Experiment) error {
+	results, err := s.repo.Results(ctx, experiment.ID)
+	if err != nil {
+		return err
+	}
+	byVariant := make(map[string]*entities.ExperimentVariantResult, len(results))
+	for _, result := range results {
+		byVariant[result.Variant] = result
+	}
+	experiment.Results = make([]*entities.ExperimentVariantResult, 0, len(experiment.Variants))
+	for _, variant := range experiment.Variants {
+		result, ok := byVariant[variant.Key]
+		if !ok {
+			result = &entities.ExperimentVariantResult{Variant: variant.Key}
+		}
+		if result.Conversions == nil {
+			result.Conversions = map[string]int64{}
+		}
+		experiment.Results = append(experiment.Results, result)
+	}
+	return nil
+}
+
+// bucket picks the user's variant from a hash of the experiment key and user
+// ID, so the choice is stable and independent between experiments
+func bucket(experiment *entities.Experiment, userID uuid.UUID) string {
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+	sum := sha256.Sum256([]byte(experiment.Key + ":" + userID.String()))
+	point := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for _, variant := range experiment.Variants {
+		if point < variant.Weight {
+			return variant.Key
+		}
+		point -= variant.Weight
+	}
+	return experiment.Variants[len(experiment.Variants)-1].Key
+}
@@ -0,0 +1,236 @@
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrDisabled is returned when fault injection is off, which it always is
+	// in production
+	ErrDisabled = errors.New("fault injection is disabled")
+	// ErrRuleNotFound is returned for an unknown or expired rule
+	ErrRuleNotFound = errors.New("fault rule not found")
+	// ErrInvalidRule is returned for a rule that cannot be applied
+	ErrInvalidRule = errors.New("invalid fault rule")
+	// ErrInjectedFault is the error an injected network failure returns
+	ErrInjectedFault = errors.New("injected fault")
+)
+
+// Store persists fault rules so every instance applies them
+type Store interface {
+	CreateRule(ctx context.Context, rule *entities.FaultRule) error
+	DeleteRule(ctx context.Context, id uuid.UUID) (bool, error)
+	ListActiveRules(ctx context.Context) ([]*entities.FaultRule, error)
+}
+
+// Config controls whether faults can be injected and for how long
+type Config struct {
+	Enabled      bool
+	DefaultTTL   time.Duration // Lifetime of a rule created without a TTL
+	RefreshEvery time.Duration // How often rules created on other instances are picked up
+}
+
+// DefaultConfig leaves fault injection off
+func DefaultConfig() Config {
+	return Config{
+		DefaultTTL:   30 * time.Minute,
+		RefreshEvery: 15 * time.Second,
+	}
+}
+
+// Service holds the active fault rules and applies them to outbound calls
+// through the transports it hands to the provider clients. Rules expire on
+// their own so a forgotten rehearsal cannot keep breaking an environment.
+type Service struct {
+	store  Store
+	config Config
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	rules  []*entities.FaultRule
+	now    func() time.Time
+	random func() float64
+}
+
+// NewService creates a fault injection service
+func NewService(store Store, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = defaults.DefaultTTL
+	}
+	if config.RefreshEvery <= 0 {
+		config.RefreshEvery = defaults.RefreshEvery
+	}
+	return &Service{
+		store:  store,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+		random: rand.Float64,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetRandom overrides the source that decides which share of calls a rule
+// affects, for tests
+func (s *Service) SetRandom(random func() float64) {
+	s.random = random
+}
+
+// Enabled reports whether faults can be injected
+func (s *Service) Enabled() bool {
+	return s.config.Enabled
+}
+
+// Start loads the active rules and keeps them fresh until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	if !s.config.Enabled {
+		return
+	}
+	s.refresh(ctx)
+	go func() {
+		ticker := time.NewTicker(s.config.RefreshEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.refresh(ctx)
+			}
+		}
+	}()
+}
+
+// ListRules returns the unexpired rules
+func (s *Service) ListRules(ctx context.Context) ([]*entities.FaultRule, error) {
+	if !s.config.Enabled {
+		return nil, ErrDisabled
+	}
+	return s.store.ListActiveRules(ctx)
+}
+
+// AddRule starts injecting a fault. It applies on this instance at once and
+// on others at their next refresh.
+func (s *Service) AddRule(ctx context.Context, adminID uuid.UUID, req *entities.CreateFaultRuleRequest) (*entities.FaultRule, error) {
+	if !s.config.Enabled {
+		return nil, ErrDisabled
+	}
+	if !req.Provider.IsValid() {
+		return nil, fmt.Errorf("%w: unknown provider %q", ErrInvalidRule, req.Provider)
+	}
+	if !req.Fault.IsValid() {
+		return nil, fmt.Errorf("%w: unknown fault %q", ErrInvalidRule, req.Fault)
+	}
+	if req.Fault == entities.FaultLatency && req.LatencyMs <= 0 {
+		return nil, fmt.Errorf("%w: latency faults need latency_ms", ErrInvalidRule)
+	}
+	pathPrefix := strings.TrimSpace(req.PathPrefix)
+	if pathPrefix != "" && !strings.HasPrefix(pathPrefix, "/") {
+		return nil, fmt.Errorf("%w: path_prefix must start with /", ErrInvalidRule)
+	}
+	percent := req.Percent
+	if percent <= 0 {
+		percent = 100
+	}
+	ttl := s.config.DefaultTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	now := s.now()
+	rule := &entities.FaultRule{
+		ID:         uuid.New(),
+		Provider:   req.Provider,
+		Method:     strings.ToUpper(req.Method),
+		PathPrefix: pathPrefix,
+		Fault:      req.Fault,
+		LatencyMs:  req.LatencyMs,
+		StatusCode: req.StatusCode,
+		Percent:    percent,
+		Note:       strings.TrimSpace(req.Note),
+		CreatedBy:  adminID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := s.store.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.rules = append([]*entities.FaultRule{rule}, s.rules...)
+	s.mu.Unlock()
+	s.logger.Warn("Fault injection rule added",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("provider", string(rule.Provider)),
+		zap.String("fault", string(rule.Fault)),
+		zap.String("path_prefix", rule.PathPrefix),
+		zap.Time("expires_at", rule.ExpiresAt))
+	return rule, nil
+}
+
+// RemoveRule stops injecting a fault
+func (s *Service) RemoveRule(ctx context.Context, id uuid.UUID) error {
+	if !s.config.Enabled {
+		return ErrDisabled
+	}
+	deleted, err := s.store.DeleteRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrRuleNotFound
+	}
+	s.refresh(ctx)
+	return nil
+}
+
+func (s *Service) refresh(ctx context.Context) {
+	rules, err := s.store.ListActiveRules(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to refresh fault injection rules", zap.Error(err))
+		return
+	}
+	s.mu.Lock()
+	s.rules = rules
+	s.mu.Unlock()
+}
+
+// match returns the rule to apply to a call, if any. Rules are tried newest
+// first, each rolling for its own share of calls.
+func (s *Service) match(provider entities.FaultProvider, method, path string) *entities.FaultRule {
+	now := s.now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rule := range s.rules {
+		if rule.Provider != provider || !now.Before(rule.ExpiresAt) {
+			continue
+		}
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if !strings.HasPrefix(path, rule.PathPrefix) {
+			continue
+		}
+		if s.random()*100 < rule.Percent {
+			return rule
+		}
+	}
+	return nil
+}
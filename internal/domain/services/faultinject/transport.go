@@ -0,0 +1,131 @@
+package faultinject
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// faultHeader marks responses an injected fault produced or altered
+const faultHeader = "X-Fault-Injected"
+
+// Transport wraps a provider client's transport so active rules for the
+// provider apply to its calls. When fault injection is disabled next is
+// returned unchanged and calls pay nothing for it.
+func (s *Service) Transport(provider entities.FaultProvider, next http.RoundTripper) http.RoundTripper {
+	if !s.config.Enabled {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{service: s, provider: provider, next: next}
+}
+
+// Wrapper returns Transport bound to a provider, in the form the provider
+// clients accept
+func (s *Service) Wrapper(provider entities.FaultProvider) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return s.Transport(provider, next)
+	}
+}
+
+type transport struct {
+	service  *Service
+	provider entities.FaultProvider
+	next     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rule := t.service.match(t.provider, req.Method, req.URL.Path)
+	if rule == nil {
+		return t.next.RoundTrip(req)
+	}
+	metrics.RecordFaultInjected(string(t.provider), string(rule.Fault))
+	t.service.logger.Debug("Injecting fault",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("provider", string(t.provider)),
+		zap.String("fault", string(rule.Fault)),
+		zap.String("method", req.Method),
+		zap.String("path", req.URL.Path))
+
+	switch rule.Fault {
+	case entities.FaultLatency:
+		timer := time.NewTimer(time.Duration(rule.LatencyMs) * time.Millisecond)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		return t.next.RoundTrip(req)
+	case entities.FaultError:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		if rule.StatusCode == 0 {
+			return nil, fmt.Errorf("%w: connection to %s failed", ErrInjectedFault, t.provider)
+		}
+		return errorResponse(req, rule), nil
+	case entities.FaultMalformed:
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		return malform(resp, rule)
+	}
+	return t.next.RoundTrip(req)
+}
+
+func errorResponse(req *http.Request, rule *entities.FaultRule) *http.Response {
+	body := fmt.Sprintf(`{"error":"injected fault","rule_id":%q}`, rule.ID.String())
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(faultHeader, rule.ID.String())
+	return &http.Response{
+		Status:        strconv.Itoa(rule.StatusCode) + " " + http.StatusText(rule.StatusCode),
+		StatusCode:    rule.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// malform keeps the provider's status and headers and cuts the body in half,
+// which breaks any JSON document. Event streams never end, so they are left
+// alone.
+func malform(resp *http.Response, rule *entities.FaultRule) (*http.Response, error) {
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = body[:len(body)/2]
+	if len(body) == 0 {
+		body = []byte("{")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	resp.Header.Set(faultHeader, rule.ID.String())
+	return resp, nil
+}
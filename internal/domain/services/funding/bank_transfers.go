@@ -0,0 +1,79 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// CreditBankTransfer posts the ledger transaction and buying power of a bank
+// transfer into a shared account once it is attributed to a user. usdAmount
+// is the credit converted to USD. The ledger transaction is idempotent on the
+// credit ID, so a failed credit can be retried.
+func (s *Service) CreditBankTransfer(ctx context.Context, credit *entities.BankCredit, usdAmount decimal.Decimal) (*uuid.UUID, error) {
+	if credit.UserID == nil {
+		return nil, fmt.Errorf("bank credit %s has no user", credit.ID)
+	}
+	userID := *credit.UserID
+
+	ledgerTxID, err := s.postBankTransferLedger(ctx, credit, userID, usdAmount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.balanceRepo.UpdateBuyingPower(ctx, userID, usdAmount); err != nil {
+		return nil, fmt.Errorf("failed to update buying power: %w", err)
+	}
+
+	s.logger.Info("Bank transfer credited",
+		"user_id", userID,
+		"bank_credit_id", credit.ID,
+		"amount", logger.RedactAmount(credit.Amount),
+		"currency", credit.Currency,
+		"usd_amount", logger.RedactAmount(usdAmount),
+	)
+	return ledgerTxID, nil
+}
+
+func (s *Service) postBankTransferLedger(ctx context.Context, credit *entities.BankCredit, userID uuid.UUID, usdAmount decimal.Decimal) (*uuid.UUID, error) {
+	if s.ledger == nil {
+		return nil, nil
+	}
+
+	userAccount, err := s.ledger.GetOrCreateUserAccount(ctx, userID, entities.AccountTypeFiatExposure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ledger account: %w", err)
+	}
+	systemAccount, err := s.ledger.GetSystemAccount(ctx, entities.AccountTypeSystemBufferFiat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system ledger account: %w", err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithUser(userID).
+		WithType(entities.TransactionTypeDeposit).
+		WithReference(credit.ID, "bank_credit").
+		WithIdempotencyKey(fmt.Sprintf("bank-credit-%s", credit.ID)).
+		WithDescription(fmt.Sprintf("Bank transfer: %s %s (Ref: %s)", credit.Amount, credit.Currency, credit.ProviderRef)).
+		WithMetadata(map[string]any{
+			"bank_credit_id": credit.ID.String(),
+			"provider_ref":   credit.ProviderRef,
+			"currency":       credit.Currency,
+			"amount":         credit.Amount.String(),
+		}).
+		WithEntries(ledger.CreateBankDepositEntries(userAccount.ID, systemAccount.ID, usdAmount)).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bank transfer ledger transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post bank transfer ledger transaction: %w", err)
+	}
+	return &tx.ID, nil
+}
@@ -0,0 +1,295 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// ConfirmationThresholds is the number of block confirmations a deposit needs
+// before it is credited. Chains are matched case-insensitively; chains not
+// listed take the threshold from Registry, and chains it does not know use
+// Default.
+type ConfirmationThresholds struct {
+	Default  int
+	Chains   map[string]int
+	Registry *entities.ChainRegistry
+}
+
+// Required returns the confirmations required on a chain
+func (t ConfirmationThresholds) Required(chain entities.Chain) int {
+	for name, required := range t.Chains {
+		if strings.EqualFold(name, string(chain)) {
+			return required
+		}
+	}
+	if t.Registry != nil {
+		if info, ok := t.Registry.Lookup(string(chain)); ok {
+			return info.Confirmations
+		}
+	}
+	return t.Default
+}
+
+// DepositLedger posts and reverses the ledger transaction of a deposit
+type DepositLedger interface {
+	GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+	ReverseTransaction(ctx context.Context, originalTxID uuid.UUID, reason string) error
+}
+
+// advanceDeposit records a new confirmation count and credits the deposit once
+// it reaches the threshold fixed when it was detected
+func (s *Service) advanceDeposit(ctx context.Context, deposit *entities.Deposit, confirmations int) error {
+	if confirmations < deposit.RequiredConfirmations {
+		if confirmations > deposit.Confirmations {
+			if err := s.depositRepo.UpdateConfirmations(ctx, deposit.ID, confirmations); err != nil {
+				return err
+			}
+		}
+		s.logger.Info("Deposit awaiting confirmations",
+			"deposit_id", deposit.ID,
+			"tx_hash", deposit.TxHash,
+			"confirmations", confirmations,
+			"required", deposit.RequiredConfirmations)
+		return nil
+	}
+
+	now := time.Now()
+	if deposit.Status == entities.DepositStatusDetected {
+		if err := s.depositRepo.MarkConfirmed(ctx, deposit.ID, confirmations, now); err != nil {
+			return err
+		}
+		deposit.Status = entities.DepositStatusConfirmed
+		deposit.Confirmations = confirmations
+		deposit.ConfirmedAt = &now
+	}
+
+	return s.creditDeposit(ctx, deposit)
+}
+
+// creditDeposit posts the ledger transaction and buying power of a confirmed
+// deposit. Marking it credited is conditional, so only one caller credits.
+func (s *Service) creditDeposit(ctx context.Context, deposit *entities.Deposit) error {
+	usdAmount, err := s.circleAPI.ConvertToUSD(ctx, deposit.Amount, deposit.Token)
+	if err != nil {
+		return fmt.Errorf("failed to convert to USD: %w", err)
+	}
+
+	ledgerTxID, err := s.postDepositLedger(ctx, deposit)
+	if err != nil {
+		return err
+	}
+
+	// Buying power already advanced against the deposit is not credited again
+	advance, err := s.outstandingAdvance(ctx, deposit)
+	if err != nil {
+		return err
+	}
+	credit := usdAmount
+	if advance != nil {
+		credit = decimal.Max(usdAmount.Sub(advance.Amount), decimal.Zero)
+	}
+
+	now := time.Now()
+	credited, err := s.depositRepo.MarkCredited(ctx, deposit.ID, usdAmount, ledgerTxID, now)
+	if err != nil {
+		return err
+	}
+	if !credited {
+		s.logger.Info("Deposit already credited", "deposit_id", deposit.ID, "tx_hash", deposit.TxHash)
+		return nil
+	}
+
+	if err := s.balanceRepo.UpdateBuyingPower(ctx, deposit.UserID, credit); err != nil {
+		// Release the claim so the next notification retries the credit; the
+		// ledger transaction is idempotent on the deposit ID
+		if releaseErr := s.depositRepo.UpdateStatus(ctx, deposit.ID, entities.DepositStatusConfirmed, nil); releaseErr != nil {
+			s.logger.Error("Failed to release deposit credit", "deposit_id", deposit.ID, "error", releaseErr)
+		}
+		return fmt.Errorf("failed to update buying power: %w", err)
+	}
+	if advance != nil {
+		s.repayAdvance(ctx, advance)
+	}
+	s.recordDepositRate(ctx, deposit, usdAmount, now)
+
+	deposit.Status = entities.DepositStatusCredited
+	s.logger.Info("Deposit processed successfully",
+		"user_id", deposit.UserID,
+		"amount", logger.RedactAmount(deposit.Amount),
+		"usd_amount", logger.RedactAmount(usdAmount),
+		"tx_hash", deposit.TxHash,
+		"confirmations", deposit.Confirmations,
+	)
+
+	if s.bus != nil {
+		if err := s.bus.Publish(ctx, entities.TopicDepositConfirmed, deposit.UserID.String(), entities.DepositConfirmedEvent{
+			DepositID:   deposit.ID,
+			UserID:      deposit.UserID,
+			Chain:       deposit.Chain,
+			Token:       deposit.Token,
+			Amount:      deposit.Amount.String(),
+			USDAmount:   usdAmount.String(),
+			TxHash:      deposit.TxHash,
+			Status:      deposit.Status,
+			ConfirmedAt: deposit.ConfirmedAt,
+		}); err != nil {
+			s.logger.Warn("Failed to publish deposit confirmed event", "deposit_id", deposit.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// postDepositLedger credits the user's USDC balance against the system buffer
+func (s *Service) postDepositLedger(ctx context.Context, deposit *entities.Deposit) (*uuid.UUID, error) {
+	if s.ledger == nil {
+		return nil, nil
+	}
+
+	userAccount, err := s.ledger.GetOrCreateUserAccount(ctx, deposit.UserID, entities.AccountTypeUSDCBalance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ledger account: %w", err)
+	}
+	systemAccount, err := s.ledger.GetSystemAccount(ctx, entities.AccountTypeSystemBufferUSDC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system ledger account: %w", err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithUser(deposit.UserID).
+		WithType(entities.TransactionTypeDeposit).
+		WithReference(deposit.ID, "deposit").
+		WithIdempotencyKey(fmt.Sprintf("deposit-%s", deposit.ID)).
+		WithDescription(fmt.Sprintf("Deposit: %s %s on %s (Tx: %s)", deposit.Amount, deposit.Token, deposit.Chain, deposit.TxHash)).
+		WithMetadata(map[string]any{
+			"deposit_id":    deposit.ID.String(),
+			"tx_hash":       deposit.TxHash,
+			"chain":         deposit.Chain,
+			"confirmations": deposit.Confirmations,
+		}).
+		WithEntries(ledger.CreateDepositEntries(userAccount.ID, systemAccount.ID, deposit.Amount)).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build deposit ledger transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post deposit ledger transaction: %w", err)
+	}
+	return &tx.ID, nil
+}
+
+// ReverseChainDeposit handles a deposit whose transaction was dropped. A
+// deposit not yet credited is simply marked reversed; a credited deposit has
+// its buying power and ledger transaction reversed. Deposits already
+// off-ramped or broker funded return ErrDepositNotReversible.
+func (s *Service) ReverseChainDeposit(ctx context.Context, txHash, reason string) error {
+	deposit, err := s.depositRepo.GetByTxHash(ctx, txHash)
+	if err != nil {
+		if err.Error() == "deposit not found" {
+			s.logger.Info("Dropped transaction has no deposit", "tx_hash", txHash)
+			return nil
+		}
+		return fmt.Errorf("failed to get deposit: %w", err)
+	}
+	return s.reverseDeposit(ctx, deposit, reason)
+}
+
+// reverseDeposit reverses one dropped deposit according to its status
+func (s *Service) reverseDeposit(ctx context.Context, deposit *entities.Deposit, reason string) error {
+	switch deposit.Status {
+	case entities.DepositStatusReversed:
+		return nil
+	case entities.DepositStatusDetected, entities.DepositStatusConfirmed:
+		reversed, err := s.depositRepo.MarkReversed(ctx, deposit.ID, deposit.Status, reason, time.Now())
+		if err != nil {
+			return err
+		}
+		s.logger.Warn("Uncredited deposit dropped", "deposit_id", deposit.ID, "tx_hash", deposit.TxHash, "reason", reason)
+		if !reversed {
+			return nil
+		}
+		return s.clawBackAdvance(ctx, deposit, reason)
+	case entities.DepositStatusCredited:
+		return s.reverseCredit(ctx, deposit, reason)
+	default:
+		s.logger.Error("Dropped deposit has already settled",
+			"deposit_id", deposit.ID,
+			"tx_hash", deposit.TxHash,
+			"status", deposit.Status)
+		return fmt.Errorf("deposit %s in status %s: %w", deposit.ID, deposit.Status, entities.ErrDepositNotReversible)
+	}
+}
+
+// reverseCredit claims the credited deposit, then reverses its ledger
+// transaction and buying power
+func (s *Service) reverseCredit(ctx context.Context, deposit *entities.Deposit, reason string) error {
+	now := time.Now()
+	claimed, err := s.depositRepo.MarkReversed(ctx, deposit.ID, entities.DepositStatusCredited, reason, now)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	if s.ledger != nil && deposit.LedgerTransactionID != nil {
+		if err := s.ledger.ReverseTransaction(ctx, *deposit.LedgerTransactionID, reason); err != nil {
+			// Put the deposit back so the next removal notice retries
+			if restoreErr := s.depositRepo.UpdateStatus(ctx, deposit.ID, entities.DepositStatusCredited, nil); restoreErr != nil {
+				s.logger.Error("Failed to restore deposit after ledger reversal failure", "deposit_id", deposit.ID, "error", restoreErr)
+			}
+			return fmt.Errorf("failed to reverse deposit ledger transaction: %w", err)
+		}
+	}
+
+	amount := deposit.Amount
+	if deposit.CreditedAmount != nil {
+		amount = *deposit.CreditedAmount
+	}
+	if err := s.balanceRepo.UpdateBuyingPower(ctx, deposit.UserID, amount.Neg()); err != nil {
+		// The ledger is already reversed; reconciliation flags the buying power
+		s.logger.Error("Failed to reverse deposit buying power",
+			"deposit_id", deposit.ID,
+			"user_id", deposit.UserID,
+			"amount", logger.RedactAmount(amount),
+			"error", err)
+		return fmt.Errorf("failed to reverse buying power: %w", err)
+	}
+
+	s.logger.Warn("Credited deposit reversed",
+		"deposit_id", deposit.ID,
+		"user_id", deposit.UserID,
+		"amount", logger.RedactAmount(amount),
+		"tx_hash", deposit.TxHash,
+		"reason", reason)
+
+	if s.bus != nil {
+		if err := s.bus.Publish(ctx, entities.TopicDepositReversed, deposit.UserID.String(), entities.DepositReversedEvent{
+			DepositID:  deposit.ID,
+			UserID:     deposit.UserID,
+			Chain:      deposit.Chain,
+			Token:      deposit.Token,
+			Amount:     deposit.Amount.String(),
+			TxHash:     deposit.TxHash,
+			Status:     entities.DepositStatusReversed,
+			Reason:     reason,
+			ReversedAt: now,
+		}); err != nil {
+			s.logger.Warn("Failed to publish deposit reversed event", "deposit_id", deposit.ID, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package funding
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// RateRecorder keeps the exchange rates transactions settled at
+type RateRecorder interface {
+	Record(ctx context.Context, rate *entities.ExchangeRate) error
+}
+
+// SetRates records the USD rate each deposit is credited at, so statements
+// can value it as of the deposit rather than today
+func (s *Service) SetRates(rates RateRecorder) {
+	s.rates = rates
+}
+
+// recordDepositRate stores the rate a deposit was credited at. The deposit is
+// already credited, so a failure is only logged.
+func (s *Service) recordDepositRate(ctx context.Context, deposit *entities.Deposit, usdAmount decimal.Decimal, creditedAt time.Time) {
+	if s.rates == nil || !deposit.Amount.IsPositive() || !usdAmount.IsPositive() {
+		return
+	}
+
+	referenceType := "deposit"
+	err := s.rates.Record(ctx, &entities.ExchangeRate{
+		Pair:          entities.NewCurrencyPair(string(deposit.Token), "USD"),
+		Rate:          usdAmount.DivRound(deposit.Amount, 18),
+		ObservedAt:    creditedAt,
+		Source:        entities.RateSourceDeposit,
+		ReferenceType: &referenceType,
+		ReferenceID:   &deposit.ID,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to record deposit exchange rate", "deposit_id", deposit.ID, "error", err)
+	}
+}
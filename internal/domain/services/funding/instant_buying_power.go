@@ -0,0 +1,270 @@
+package funding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// InstantBuyingPower configures buying power advanced against chain deposits
+// that are detected but still waiting for confirmations
+type InstantBuyingPower struct {
+	Percent         decimal.Decimal // Share of the deposit's USD value advanced, 0-100
+	MaxAmount       decimal.Decimal // Largest advance per deposit; zero means no cap
+	SourceAccountNo string          // Alpaca instant funding source account
+}
+
+// AdvanceRepository persists instant funding advances
+type AdvanceRepository interface {
+	// Create records an advance unless its deposit already has one
+	Create(ctx context.Context, advance *entities.InstantFundingAdvance) (bool, error)
+	GetByDepositID(ctx context.Context, depositID uuid.UUID) (*entities.InstantFundingAdvance, error)
+	// Transition saves the advance's status and outcome if it is still in
+	// status from, reporting whether it was
+	Transition(ctx context.Context, advance *entities.InstantFundingAdvance, from entities.InstantAdvanceStatus) (bool, error)
+}
+
+// SetInstantBuyingPower advances part of each detected deposit as buying
+// power through Alpaca instant funding. The advance is netted out of the
+// deposit when it is credited and clawed back if the deposit is dropped.
+func (s *Service) SetInstantBuyingPower(advances AdvanceRepository, config InstantBuyingPower) {
+	if config.SourceAccountNo == "" {
+		config.SourceAccountNo = "SI"
+	}
+	s.advances = advances
+	s.instant = config
+}
+
+// advanceBuyingPower extends buying power against a deposit still awaiting
+// confirmations. Failures only cost the user the advance, so they are logged
+// and the deposit carries on.
+func (s *Service) advanceBuyingPower(ctx context.Context, deposit *entities.Deposit) {
+	if s.advances == nil || !s.instant.Percent.IsPositive() {
+		return
+	}
+
+	usdAmount, err := s.circleAPI.ConvertToUSD(ctx, deposit.Amount, deposit.Token)
+	if err != nil {
+		s.logger.Warn("Failed to price deposit for instant buying power", "deposit_id", deposit.ID, "error", err)
+		return
+	}
+	amount := usdAmount.Mul(s.instant.Percent).Div(decimal.NewFromInt(100)).Truncate(2)
+	if s.instant.MaxAmount.IsPositive() && amount.GreaterThan(s.instant.MaxAmount) {
+		amount = s.instant.MaxAmount
+	}
+	if !amount.IsPositive() {
+		return
+	}
+
+	advance := &entities.InstantFundingAdvance{
+		ID:        uuid.New(),
+		DepositID: deposit.ID,
+		UserID:    deposit.UserID,
+		Amount:    amount,
+		Status:    entities.InstantAdvancePending,
+		CreatedAt: time.Now(),
+	}
+	created, err := s.advances.Create(ctx, advance)
+	if err != nil {
+		s.logger.Warn("Failed to record instant buying power advance", "deposit_id", deposit.ID, "error", err)
+		return
+	}
+	if !created {
+		return
+	}
+
+	transfer, err := s.requestInstantFunding(ctx, deposit.UserID, amount)
+	if err != nil {
+		s.failAdvance(ctx, advance, entities.InstantAdvancePending, err.Error())
+		return
+	}
+
+	advance.Status = entities.InstantAdvanceActive
+	advance.AlpacaTransferID = &transfer.ID
+	activated, err := s.advances.Transition(ctx, advance, entities.InstantAdvancePending)
+	if err != nil || !activated {
+		// The deposit was credited or dropped meanwhile, so the advance is not
+		// granted; Alpaca settles the transfer with the broker funding
+		s.logger.Warn("Instant buying power advance not activated",
+			"deposit_id", deposit.ID,
+			"transfer_id", transfer.ID,
+			"error", err)
+		return
+	}
+
+	if err := s.balanceRepo.UpdateBuyingPower(ctx, deposit.UserID, amount); err != nil {
+		s.failAdvance(ctx, advance, entities.InstantAdvanceActive, err.Error())
+		return
+	}
+
+	s.logger.Info("Instant buying power advanced",
+		"deposit_id", deposit.ID,
+		"user_id", deposit.UserID,
+		"amount", logger.RedactAmount(amount),
+		"transfer_id", transfer.ID)
+}
+
+// requestInstantFunding asks Alpaca to extend buying power on the user's
+// brokerage account ahead of settlement
+func (s *Service) requestInstantFunding(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) (*entities.AlpacaInstantFundingResponse, error) {
+	if s.virtualAccountRepo == nil || s.alpacaAPI == nil {
+		return nil, fmt.Errorf("instant funding is not configured")
+	}
+	virtualAccounts, err := s.virtualAccountRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual account: %w", err)
+	}
+	if len(virtualAccounts) == 0 || virtualAccounts[0].AlpacaAccountID == "" {
+		return nil, fmt.Errorf("user has no Alpaca account")
+	}
+
+	account, err := s.alpacaAPI.GetAccount(ctx, virtualAccounts[0].AlpacaAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Alpaca account: %w", err)
+	}
+	if account.Status != entities.AlpacaAccountStatusActive {
+		return nil, fmt.Errorf("Alpaca account not active: %s", account.Status)
+	}
+
+	transfer, err := s.alpacaAPI.InitiateInstantFunding(ctx, &entities.AlpacaInstantFundingRequest{
+		AccountNo:       account.AccountNumber,
+		SourceAccountNo: s.instant.SourceAccountNo,
+		Amount:          amount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate instant funding: %w", err)
+	}
+	return transfer, nil
+}
+
+func (s *Service) failAdvance(ctx context.Context, advance *entities.InstantFundingAdvance, from entities.InstantAdvanceStatus, reason string) {
+	now := time.Now()
+	advance.Status = entities.InstantAdvanceFailed
+	advance.FailureReason = &reason
+	advance.SettledAt = &now
+	failed, err := s.advances.Transition(ctx, advance, from)
+	if err != nil {
+		s.logger.Error("Failed to mark instant buying power advance failed", "advance_id", advance.ID, "error", err)
+		return
+	}
+	if !failed {
+		return
+	}
+	s.logger.Warn("Instant buying power advance failed",
+		"deposit_id", advance.DepositID,
+		"user_id", advance.UserID,
+		"reason", reason)
+}
+
+// outstandingAdvance returns the active advance on a deposit, or nil. A
+// pending advance is failed first so its transfer, once it completes, does
+// not also grant buying power.
+func (s *Service) outstandingAdvance(ctx context.Context, deposit *entities.Deposit) (*entities.InstantFundingAdvance, error) {
+	if s.advances == nil {
+		return nil, nil
+	}
+	advance, err := s.advances.GetByDepositID(ctx, deposit.ID)
+	if err != nil {
+		if errors.Is(err, entities.ErrInstantAdvanceNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get instant funding advance: %w", err)
+	}
+
+	switch advance.Status {
+	case entities.InstantAdvanceActive:
+		return advance, nil
+	case entities.InstantAdvancePending:
+		s.failAdvance(ctx, advance, entities.InstantAdvancePending, "deposit settled before the advance completed")
+		// The advance may have become active before it could be failed
+		latest, err := s.advances.GetByDepositID(ctx, deposit.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get instant funding advance: %w", err)
+		}
+		if latest.Status == entities.InstantAdvanceActive {
+			return latest, nil
+		}
+	}
+	return nil, nil
+}
+
+// repayAdvance marks an advance netted out of its credited deposit
+func (s *Service) repayAdvance(ctx context.Context, advance *entities.InstantFundingAdvance) {
+	now := time.Now()
+	advance.Status = entities.InstantAdvanceRepaid
+	advance.SettledAt = &now
+	repaid, err := s.advances.Transition(ctx, advance, entities.InstantAdvanceActive)
+	if err != nil || !repaid {
+		// The buying power is already netted; only the record is stale
+		s.logger.Error("Failed to mark instant buying power advance repaid",
+			"advance_id", advance.ID,
+			"deposit_id", advance.DepositID,
+			"error", err)
+		return
+	}
+	s.logger.Info("Instant buying power advance repaid",
+		"deposit_id", advance.DepositID,
+		"user_id", advance.UserID,
+		"amount", logger.RedactAmount(advance.Amount))
+}
+
+// clawBackAdvance takes back the advance on a deposit dropped before it was
+// credited. Buying power the user already spent leaves their balance negative
+// and is recorded as the advance's shortfall for collection.
+func (s *Service) clawBackAdvance(ctx context.Context, deposit *entities.Deposit, reason string) error {
+	advance, err := s.outstandingAdvance(ctx, deposit)
+	if err != nil || advance == nil {
+		return err
+	}
+
+	balance, err := s.balanceRepo.Get(ctx, deposit.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	shortfall := advance.Amount.Sub(decimal.Max(balance.BuyingPower, decimal.Zero))
+	if shortfall.IsNegative() {
+		shortfall = decimal.Zero
+	}
+
+	now := time.Now()
+	advance.Status = entities.InstantAdvanceReversed
+	advance.Shortfall = shortfall
+	advance.FailureReason = &reason
+	advance.SettledAt = &now
+	claimed, err := s.advances.Transition(ctx, advance, entities.InstantAdvanceActive)
+	if err != nil {
+		return fmt.Errorf("failed to reverse instant funding advance: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := s.balanceRepo.UpdateBuyingPower(ctx, deposit.UserID, advance.Amount.Neg()); err != nil {
+		s.logger.Error("Failed to claw back instant buying power",
+			"deposit_id", deposit.ID,
+			"user_id", deposit.UserID,
+			"amount", logger.RedactAmount(advance.Amount),
+			"error", err)
+		return fmt.Errorf("failed to claw back instant buying power: %w", err)
+	}
+
+	if shortfall.IsPositive() {
+		s.logger.Error("Clawed-back instant buying power was already spent",
+			"deposit_id", deposit.ID,
+			"user_id", deposit.UserID,
+			"amount", logger.RedactAmount(advance.Amount),
+			"shortfall", shortfall.String())
+	} else {
+		s.logger.Warn("Instant buying power clawed back",
+			"deposit_id", deposit.ID,
+			"user_id", deposit.UserID,
+			"amount", logger.RedactAmount(advance.Amount))
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package funding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrInvalidReplayMode is returned for a replay mode other than dry run or apply
+var ErrInvalidReplayMode = errors.New("replay mode must be dry_run or apply")
+
+// replayPageSize is how many deposits a replay loads at a time
+const replayPageSize = 100
+
+// ReplayUserDeposits runs a user's recorded chain deposits through the current
+// confirmation and crediting rules. A dry run only reports; apply mode credits
+// deposits that have their confirmations but were never credited. A credited
+// deposit the current rules would price differently is reported for manual
+// review and never re-posted.
+func (s *Service) ReplayUserDeposits(ctx context.Context, userID uuid.UUID, mode entities.FundingReplayMode) (*entities.FundingReplayReport, error) {
+	if mode != entities.FundingReplayDryRun && mode != entities.FundingReplayApply {
+		return nil, ErrInvalidReplayMode
+	}
+
+	before, err := s.buyingPower(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entities.FundingReplayReport{
+		UserID:            userID,
+		Mode:              mode,
+		BuyingPowerBefore: before,
+		NetChange:         decimal.Zero,
+		Entries:           []*entities.FundingReplayEntry{},
+		GeneratedAt:       time.Now(),
+	}
+
+	for offset := 0; ; offset += replayPageSize {
+		deposits, err := s.depositRepo.GetByUserID(ctx, userID, replayPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deposits: %w", err)
+		}
+
+		for _, deposit := range deposits {
+			entry := s.planReplay(ctx, deposit)
+			if mode == entities.FundingReplayApply && entry.Action == entities.FundingReplayCredit {
+				s.applyReplay(ctx, deposit, entry)
+			}
+			if entry.Action == entities.FundingReplayCredit && entry.Error == "" {
+				report.NetChange = report.NetChange.Add(entry.Change)
+			}
+			report.Entries = append(report.Entries, entry)
+		}
+
+		if len(deposits) < replayPageSize {
+			break
+		}
+	}
+
+	report.BuyingPowerAfter = before.Add(report.NetChange)
+	if mode == entities.FundingReplayApply {
+		after, err := s.buyingPower(ctx, userID)
+		if err != nil {
+			s.logger.Warn("Failed to read buying power after replay", "error", err, "user_id", userID.String())
+		} else {
+			report.BuyingPowerAfter = after
+		}
+	}
+
+	s.logger.Info("Replayed user deposits",
+		"user_id", userID.String(),
+		"mode", mode,
+		"deposits", len(report.Entries),
+		"net_change", report.NetChange.String())
+	return report, nil
+}
+
+// planReplay works out what the current pipeline would do with a deposit
+func (s *Service) planReplay(ctx context.Context, deposit *entities.Deposit) *entities.FundingReplayEntry {
+	credited := decimal.Zero
+	if deposit.CreditedAmount != nil {
+		credited = *deposit.CreditedAmount
+	}
+	entry := &entities.FundingReplayEntry{
+		DepositID:      deposit.ID,
+		Chain:          deposit.Chain,
+		TxHash:         deposit.TxHash,
+		LogIndex:       deposit.LogIndex,
+		Action:         entities.FundingReplayUnchanged,
+		StatusBefore:   deposit.Status,
+		StatusAfter:    deposit.Status,
+		CreditedBefore: credited,
+		CreditedAfter:  credited,
+		Change:         decimal.Zero,
+	}
+
+	// The confirmation pipeline fixes a threshold when it detects a deposit.
+	// Fiat deposits and Circle transfers are credited on arrival elsewhere.
+	if deposit.TxHash == "" || deposit.RequiredConfirmations == 0 {
+		entry.Action = entities.FundingReplaySkipped
+		entry.Note = "not recorded by the chain confirmation pipeline"
+		return entry
+	}
+
+	switch deposit.Status {
+	case entities.DepositStatusDetected, entities.DepositStatusConfirmed:
+		if deposit.Confirmations < deposit.RequiredConfirmations {
+			entry.Note = "awaiting confirmations"
+			return entry
+		}
+		usdAmount, err := s.circleAPI.ConvertToUSD(ctx, deposit.Amount, deposit.Token)
+		if err != nil {
+			entry.Error = fmt.Sprintf("failed to convert to USD: %v", err)
+			return entry
+		}
+		entry.Action = entities.FundingReplayCredit
+		entry.StatusAfter = entities.DepositStatusCredited
+		entry.CreditedAfter = usdAmount
+		entry.Change = usdAmount
+	case entities.DepositStatusCredited:
+		usdAmount, err := s.circleAPI.ConvertToUSD(ctx, deposit.Amount, deposit.Token)
+		if err != nil {
+			entry.Error = fmt.Sprintf("failed to convert to USD: %v", err)
+			return entry
+		}
+		if !usdAmount.Equal(credited) {
+			entry.Action = entities.FundingReplayManualReview
+			entry.CreditedAfter = usdAmount
+			entry.Change = usdAmount.Sub(credited)
+			entry.Note = "credited amount differs from the current conversion"
+		}
+	}
+	return entry
+}
+
+// applyReplay credits a deposit the plan found uncredited and records what
+// actually happened on the entry
+func (s *Service) applyReplay(ctx context.Context, deposit *entities.Deposit, entry *entities.FundingReplayEntry) {
+	if err := s.advanceDeposit(ctx, deposit, deposit.Confirmations); err != nil {
+		entry.Error = err.Error()
+		entry.StatusAfter = deposit.Status
+		entry.CreditedAfter = entry.CreditedBefore
+		entry.Change = decimal.Zero
+		return
+	}
+	if deposit.Status != entities.DepositStatusCredited {
+		// A notification credited it between planning and applying
+		entry.Action = entities.FundingReplayUnchanged
+		entry.StatusAfter = entities.DepositStatusCredited
+		entry.Change = decimal.Zero
+		entry.Note = "credited concurrently"
+	}
+}
+
+// buyingPower returns a user's buying power, zero when they have no balance
+func (s *Service) buyingPower(ctx context.Context, userID uuid.UUID) (decimal.Decimal, error) {
+	balance, err := s.balanceRepo.Get(ctx, userID)
+	if err != nil {
+		if err.Error() == "balance not found" {
+			return decimal.Zero, nil
+		}
+		return decimal.Zero, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance.BuyingPower, nil
+}
@@ -2,14 +2,15 @@ package funding
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
 )
 
 // Service handles funding operations - deposit addresses, confirmations, balance conversion
@@ -22,15 +23,45 @@ type Service struct {
 	circleAPI           CircleAdapter
 	dueAPI              DueAdapter
 	alpacaAPI           AlpacaAdapter
+	bus                 EventBus
+	balances            BalanceCache
+	ledger              DepositLedger
+	confirmations       ConfirmationThresholds
+	promotions          PromotionSummaries
+	advances            AdvanceRepository
+	instant             InstantBuyingPower
+	rates               RateRecorder
+	suspense            Suspense
 	logger              *logger.Logger
 }
 
+// PromotionSummaries reports a user's promotional credit
+type PromotionSummaries interface {
+	Summary(ctx context.Context, userID uuid.UUID) (*entities.PromotionSummary, error)
+}
+
+// BalanceCache serves cached Circle wallet balances
+type BalanceCache interface {
+	GetUserBalances(ctx context.Context, userID uuid.UUID) (*entities.UserWalletBalances, error)
+	ForceRefresh(ctx context.Context, userID uuid.UUID) (*entities.UserWalletBalances, error)
+}
+
+// EventBus publishes domain events to asynchronous consumers
+type EventBus interface {
+	Publish(ctx context.Context, topic, key string, payload interface{}) error
+}
+
 // DepositRepository interface for deposit persistence
 type DepositRepository interface {
 	Create(ctx context.Context, deposit *entities.Deposit) error
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Deposit, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string, confirmedAt *time.Time) error
 	GetByTxHash(ctx context.Context, txHash string) (*entities.Deposit, error)
+	GetByTransfer(ctx context.Context, chain entities.Chain, txHash string, logIndex int) (*entities.Deposit, error)
+	UpdateConfirmations(ctx context.Context, id uuid.UUID, confirmations int) error
+	MarkConfirmed(ctx context.Context, id uuid.UUID, confirmations int, confirmedAt time.Time) error
+	MarkCredited(ctx context.Context, id uuid.UUID, amount decimal.Decimal, ledgerTxID *uuid.UUID, creditedAt time.Time) (bool, error)
+	MarkReversed(ctx context.Context, id uuid.UUID, fromStatus, reason string, reversedAt time.Time) (bool, error)
 }
 
 // BalanceRepository interface for balance management
@@ -110,6 +141,35 @@ func NewService(
 	}
 }
 
+// SetEventBus enables publishing of funding events. Partner webhooks, live
+// progress and notifications subscribe to these instead of being called inline.
+func (s *Service) SetEventBus(bus EventBus) {
+	s.bus = bus
+}
+
+// SetLedger posts double-entry ledger transactions for credited chain
+// deposits, so a re-org reversal can unwind them
+func (s *Service) SetLedger(ledger DepositLedger) {
+	s.ledger = ledger
+}
+
+// SetConfirmationThresholds sets the confirmations each chain requires before
+// a deposit is credited. Without thresholds deposits are credited on detection.
+func (s *Service) SetConfirmationThresholds(thresholds ConfirmationThresholds) {
+	s.confirmations = thresholds
+}
+
+// SetBalanceCache serves balances from the wallet balance cache instead of
+// reading every wallet from Circle on each request
+func (s *Service) SetBalanceCache(balances BalanceCache) {
+	s.balances = balances
+}
+
+// SetPromotions reports promotional credit alongside balances
+func (s *Service) SetPromotions(promotions PromotionSummaries) {
+	s.promotions = promotions
+}
+
 // CreateDepositAddress generates or retrieves deposit address for a chain
 func (s *Service) CreateDepositAddress(ctx context.Context, userID uuid.UUID, chain entities.Chain) (*entities.DepositAddressResponse, error) {
 	// Check if user already has a wallet for this chain
@@ -184,8 +244,39 @@ func (s *Service) GetFundingConfirmations(ctx context.Context, userID uuid.UUID,
 	return confirmations, nil
 }
 
-// GetBalance returns user's current balance with real-time Circle wallet balances
+// GetBalance returns user's current balance from the balance cache when set,
+// otherwise with real-time Circle wallet balances. Promotional credit is
+// reported separately and never added to BuyingPower.
 func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
+	balance, err := s.getBalance(ctx, userID)
+	if err != nil || s.promotions == nil {
+		return balance, err
+	}
+
+	summary, err := s.promotions.Summary(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to get promotional credit", "error", err, "user_id", userID.String())
+		return balance, nil
+	}
+	if len(summary.Grants) > 0 {
+		balance.PromotionalCredit = &entities.PromotionalCreditBalance{
+			Pending: summary.Unvested.StringFixed(2),
+			Vested:  summary.Vested.StringFixed(2),
+		}
+	}
+	return balance, nil
+}
+
+func (s *Service) getBalance(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
+	if s.balances != nil {
+		cached, err := s.balances.GetUserBalances(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get cached balances", "error", err, "user_id", userID.String())
+			return s.getDatabaseBalance(ctx, userID)
+		}
+		return s.cachedBalanceResponse(ctx, userID, cached), nil
+	}
+
 	s.logger.Info("Fetching user balance with real-time Circle wallet data", "user_id", userID.String())
 
 	// Get user's managed wallets
@@ -273,6 +364,36 @@ func (s *Service) GetBalance(ctx context.Context, userID uuid.UUID) (*entities.B
 	}, nil
 }
 
+// RefreshBalance re-reads the user's wallets from Circle. Returns
+// entities.ErrBalanceRefreshRateLimited when called too often.
+func (s *Service) RefreshBalance(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
+	if s.balances == nil {
+		return s.GetBalance(ctx, userID)
+	}
+	refreshed, err := s.balances.ForceRefresh(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.cachedBalanceResponse(ctx, userID, refreshed), nil
+}
+
+// cachedBalanceResponse combines cached wallet balances with pending deposits
+func (s *Service) cachedBalanceResponse(ctx context.Context, userID uuid.UUID, cached *entities.UserWalletBalances) *entities.BalancesResponse {
+	pendingDeposits := decimal.Zero
+	if dbBalance, err := s.balanceRepo.Get(ctx, userID); err == nil {
+		pendingDeposits = dbBalance.PendingDeposits
+	}
+
+	// USDC is 1:1 with USD, so buying power = USDC balance
+	return &entities.BalancesResponse{
+		BuyingPower:     cached.TotalUSDC.String(),
+		PendingDeposits: pendingDeposits.String(),
+		Currency:        "USD",
+		LastSyncedAt:    cached.LastSyncedAt,
+		Stale:           cached.Stale,
+	}
+}
+
 // getDatabaseBalance retrieves balance from database as fallback
 func (s *Service) getDatabaseBalance(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
 	balance, err := s.balanceRepo.Get(ctx, userID)
@@ -295,17 +416,36 @@ func (s *Service) getDatabaseBalance(ctx context.Context, userID uuid.UUID) (*en
 	}, nil
 }
 
-// ProcessChainDeposit processes incoming chain deposit webhook
+// ProcessChainDeposit processes incoming chain deposit webhook. The sender
+// notifies again as confirmations grow; the deposit is credited once its chain's
+// threshold is reached, and reversed if the transaction is reported removed.
 func (s *Service) ProcessChainDeposit(ctx context.Context, webhook *entities.ChainDepositWebhook) error {
-	s.logger.Info("Processing chain deposit", "chain", webhook.Chain, "tx_hash", webhook.TxHash, "amount", webhook.Amount)
+	s.logger.Info("Processing chain deposit", "chain", webhook.Chain, "tx_hash", webhook.TxHash, "amount", webhook.Amount,
+		"confirmations", webhook.Confirmations, "removed", webhook.Removed)
+
+	if webhook.Removed {
+		deposit, err := s.findTransfer(ctx, webhook)
+		if err != nil {
+			return err
+		}
+		if deposit == nil {
+			s.logger.Info("Dropped transaction has no deposit", "tx_hash", webhook.TxHash, "log_index", webhook.LogIndex)
+			return nil
+		}
+		return s.reverseDeposit(ctx, deposit, "transaction removed from canonical chain")
+	}
 
-	// Validate the deposit with Circle
-	amountFloat, err := strconv.ParseFloat(webhook.Amount, 64)
+	// Validate the deposit with Circle. Events without a token are USDC, the
+	// only stablecoin accepted.
+	currency := entities.Currency(webhook.Token)
+	if currency == "" {
+		currency = entities.CurrencyUSDC
+	}
+	amount, err := entities.ParseMoney(webhook.Amount, currency)
 	if err != nil {
 		return fmt.Errorf("invalid deposit amount %q: %w", webhook.Amount, err)
 	}
-	amount := decimal.NewFromFloat(amountFloat)
-	isValid, err := s.circleAPI.ValidateDeposit(ctx, webhook.TxHash, amount)
+	isValid, err := s.circleAPI.ValidateDeposit(ctx, webhook.TxHash, amount.Amount)
 	if err != nil {
 		return fmt.Errorf("failed to validate deposit: %w", err)
 	}
@@ -315,59 +455,96 @@ func (s *Service) ProcessChainDeposit(ctx context.Context, webhook *entities.Cha
 		return fmt.Errorf("invalid deposit signature or amount")
 	}
 
-	// Check if deposit already exists (idempotency check)
-	existingDeposit, err := s.depositRepo.GetByTxHash(ctx, webhook.TxHash)
-	if err != nil && err.Error() != "deposit not found" {
-		return fmt.Errorf("failed to check existing deposit: %w", err)
+	// A transfer is recorded once, keyed by chain, transaction hash and log index
+	existingDeposit, err := s.findTransfer(ctx, webhook)
+	if err != nil {
+		return err
 	}
-
 	if existingDeposit != nil {
-		s.logger.Info("Deposit already processed", "tx_hash", webhook.TxHash)
-		return nil
+		return s.replayDeposit(ctx, existingDeposit, webhook.Confirmations)
 	}
 
 	// Find the wallet to get user ID
 	wallet, err := s.walletRepo.GetByAddress(ctx, webhook.Address)
 	if err != nil {
+		if s.suspense != nil && err.Error() == "wallet not found" {
+			return s.holdUnknownAddress(ctx, webhook, amount.Amount)
+		}
 		return fmt.Errorf("failed to find wallet for address %s: %w", webhook.Address, err)
 	}
 
-	// Convert stablecoin to USD buying power
-	usdAmount, err := s.circleAPI.ConvertToUSD(ctx, amount, webhook.Token)
-	if err != nil {
-		return fmt.Errorf("failed to convert to USD: %w", err)
-	}
-
-	// Create deposit record
-	now := time.Now()
+	// Record the deposit as detected; it is credited once confirmed
 	deposit := &entities.Deposit{
-		ID:          uuid.New(),
-		UserID:      wallet.UserID,
-		Chain:       webhook.Chain,
-		TxHash:      webhook.TxHash,
-		Token:       webhook.Token,
-		Amount:      amount,
-		Status:      "confirmed",
-		ConfirmedAt: &now,
-		CreatedAt:   now,
+		ID:                    uuid.New(),
+		UserID:                wallet.UserID,
+		Chain:                 webhook.Chain,
+		TxHash:                webhook.TxHash,
+		LogIndex:              webhook.LogIndex,
+		Token:                 webhook.Token,
+		Amount:                amount.Amount,
+		Status:                entities.DepositStatusDetected,
+		Confirmations:         webhook.Confirmations,
+		RequiredConfirmations: s.confirmations.Required(webhook.Chain),
+		CreatedAt:             time.Now(),
 	}
 
 	if err := s.depositRepo.Create(ctx, deposit); err != nil {
-		return fmt.Errorf("failed to create deposit record: %w", err)
+		if !errors.Is(err, entities.ErrDuplicateDeposit) {
+			return fmt.Errorf("failed to create deposit record: %w", err)
+		}
+		// A concurrent notification recorded the transfer first
+		existingDeposit, err = s.findTransfer(ctx, webhook)
+		if err != nil {
+			return err
+		}
+		if existingDeposit == nil {
+			return fmt.Errorf("duplicate deposit %s/%d not found", webhook.TxHash, webhook.LogIndex)
+		}
+		return s.replayDeposit(ctx, existingDeposit, webhook.Confirmations)
+	}
+
+	if webhook.Confirmations < deposit.RequiredConfirmations {
+		s.advanceBuyingPower(ctx, deposit)
 	}
 
-	// Update user's buying power
-	if err := s.balanceRepo.UpdateBuyingPower(ctx, wallet.UserID, usdAmount); err != nil {
-		return fmt.Errorf("failed to update buying power: %w", err)
+	return s.advanceDeposit(ctx, deposit, webhook.Confirmations)
+}
+
+// IsTransferProcessed reports whether the transfer's deposit is past the point
+// where another notification could change it: credited, settled or reversed
+func (s *Service) IsTransferProcessed(ctx context.Context, chain entities.Chain, txHash string, logIndex int) (bool, error) {
+	deposit, err := s.findTransfer(ctx, &entities.ChainDepositWebhook{Chain: chain, TxHash: txHash, LogIndex: logIndex})
+	if err != nil || deposit == nil {
+		return false, err
+	}
+	switch deposit.Status {
+	case entities.DepositStatusDetected, entities.DepositStatusConfirmed:
+		return false, nil
 	}
+	return true, nil
+}
 
-	s.logger.Info("Deposit processed successfully",
-		"user_id", wallet.UserID,
-		"amount", webhook.Amount,
-		"usd_amount", usdAmount.String(),
-		"tx_hash", webhook.TxHash,
-	)
+// findTransfer returns the deposit recorded for the webhook's transfer, or nil
+func (s *Service) findTransfer(ctx context.Context, webhook *entities.ChainDepositWebhook) (*entities.Deposit, error) {
+	deposit, err := s.depositRepo.GetByTransfer(ctx, webhook.Chain, webhook.TxHash, webhook.LogIndex)
+	if err != nil {
+		if err.Error() == "deposit not found" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check existing deposit: %w", err)
+	}
+	return deposit, nil
+}
 
+// replayDeposit handles a repeated notification for a recorded transfer. Only
+// deposits still awaiting confirmations move; anything later is a duplicate.
+func (s *Service) replayDeposit(ctx context.Context, deposit *entities.Deposit, confirmations int) error {
+	switch deposit.Status {
+	case entities.DepositStatusDetected, entities.DepositStatusConfirmed:
+		return s.advanceDeposit(ctx, deposit, confirmations)
+	}
+	metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceChainWatcher).Inc()
+	s.logger.Info("Deposit already processed", "tx_hash", deposit.TxHash, "log_index", deposit.LogIndex, "status", deposit.Status)
 	return nil
 }
 
@@ -423,7 +600,7 @@ func (s *Service) InitiateBrokerFunding(ctx context.Context, depositID uuid.UUID
 	s.logger.Info("Initiating broker funding",
 		"deposit_id", depositID.String(),
 		"alpaca_account_id", alpacaAccountID,
-		"amount", amount.String())
+		"amount", logger.RedactAmount(amount))
 
 	// Verify Alpaca account is active
 	alpacaAccount, err := s.alpacaAPI.GetAccount(ctx, alpacaAccountID)
@@ -451,7 +628,7 @@ func (s *Service) InitiateBrokerFunding(ctx context.Context, depositID uuid.UUID
 		s.logger.Error("Failed to initiate instant funding",
 			"error", err,
 			"alpaca_account_id", alpacaAccountID,
-			"amount", amount.String())
+			"amount", logger.RedactAmount(amount))
 		return fmt.Errorf("failed to initiate instant funding: %w", err)
 	}
 
@@ -0,0 +1,129 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Suspense holds chain deposits to addresses no wallet has until an admin
+// assigns or refunds them
+type Suspense interface {
+	Hold(ctx context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, error)
+}
+
+// SetSuspense holds deposits to unknown addresses in the suspense account
+// instead of failing them
+func (s *Service) SetSuspense(suspense Suspense) {
+	s.suspense = suspense
+}
+
+// holdUnknownAddress places a confirmed transfer to an address no wallet has
+// in suspense. Transfers are held only once confirmed, so a re-org cannot
+// leave a held amount that never arrived.
+func (s *Service) holdUnknownAddress(ctx context.Context, webhook *entities.ChainDepositWebhook, amount decimal.Decimal) error {
+	if webhook.Confirmations < s.confirmations.Required(webhook.Chain) {
+		s.logger.Info("Deposit to unknown address awaiting confirmations before suspense",
+			"chain", webhook.Chain, "address", webhook.Address, "tx_hash", webhook.TxHash)
+		return nil
+	}
+
+	token := webhook.Token
+	if token == "" {
+		token = entities.StablecoinUSDC
+	}
+	usdAmount, err := s.circleAPI.ConvertToUSD(ctx, amount, token)
+	if err != nil {
+		return fmt.Errorf("failed to value deposit for suspense: %w", err)
+	}
+
+	receivedAt := webhook.BlockTime
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	_, err = s.suspense.Hold(ctx, &entities.SuspenseItem{
+		Source:    entities.SuspenseSourceChainDeposit,
+		SourceRef: fmt.Sprintf("%s:%s:%d", webhook.Chain, webhook.TxHash, webhook.LogIndex),
+		Currency:  string(token),
+		Amount:    amount,
+		USDAmount: usdAmount,
+		Reason:    entities.SuspenseReasonUnknownAddress,
+		Details: map[string]string{
+			"chain":     string(webhook.Chain),
+			"address":   webhook.Address,
+			"token":     string(token),
+			"tx_hash":   webhook.TxHash,
+			"log_index": strconv.Itoa(webhook.LogIndex),
+		},
+		ReceivedAt: receivedAt,
+	})
+	return err
+}
+
+// SuspenseSuggestions suggests the owner of a wallet whose address matches the
+// held deposit's apart from letter case, as when a sender lowercases an EVM
+// checksum address
+func (s *Service) SuspenseSuggestions(ctx context.Context, item *entities.SuspenseItem) ([]entities.SuspenseSuggestion, error) {
+	address := item.Details["address"]
+	for _, candidate := range []string{strings.ToLower(address), strings.ToUpper(address)} {
+		if candidate == address {
+			continue
+		}
+		wallet, err := s.walletRepo.GetByAddress(ctx, candidate)
+		if err != nil {
+			continue
+		}
+		return []entities.SuspenseSuggestion{{
+			UserID: wallet.UserID,
+			Reason: entities.SuspenseSuggestionSameAddress,
+			Detail: fmt.Sprintf("user's %s wallet is %s", wallet.Chain, wallet.Address),
+		}}, nil
+	}
+	return nil, nil
+}
+
+// AssignSuspended records a held transfer as the user's deposit and credits it
+// the way a confirmed deposit is credited
+func (s *Service) AssignSuspended(ctx context.Context, item *entities.SuspenseItem, userID, adminID uuid.UUID, note string) error {
+	logIndex, err := strconv.Atoi(item.Details["log_index"])
+	if err != nil {
+		return fmt.Errorf("suspense item %s has an invalid log index: %w", item.ID, err)
+	}
+	chain := entities.Chain(item.Details["chain"])
+	required := s.confirmations.Required(chain)
+
+	deposit := &entities.Deposit{
+		ID:                    uuid.New(),
+		UserID:                userID,
+		Chain:                 chain,
+		TxHash:                item.Details["tx_hash"],
+		LogIndex:              logIndex,
+		Token:                 entities.Stablecoin(item.Details["token"]),
+		Amount:                item.Amount,
+		Status:                entities.DepositStatusDetected,
+		Confirmations:         required,
+		RequiredConfirmations: required,
+		CreatedAt:             time.Now(),
+	}
+	if err := s.depositRepo.Create(ctx, deposit); err != nil {
+		return fmt.Errorf("failed to create deposit for suspense item: %w", err)
+	}
+	s.logger.Info("Suspended deposit assigned", "suspense_item_id", item.ID, "deposit_id", deposit.ID,
+		"user_id", userID, "admin_id", adminID)
+	return s.advanceDeposit(ctx, deposit, required)
+}
+
+// RefundSuspended acknowledges that a held transfer was sent back on-chain.
+// Nothing was recorded for it outside suspense, so there is nothing to undo.
+func (s *Service) RefundSuspended(ctx context.Context, item *entities.SuspenseItem, adminID uuid.UUID, note string) error {
+	s.logger.Info("Suspended deposit refunded", "suspense_item_id", item.ID, "tx_hash", item.Details["tx_hash"],
+		"admin_id", adminID)
+	return nil
+}
@@ -0,0 +1,365 @@
+// Package geoip locates signups and logins by IP address. Signups from
+// countries whose rule does not allow onboarding are refused, and logins
+// through a VPN, proxy or Tor exit with a high risk score are flagged so the
+// fraud scorer treats the session as riskier. Admins can exempt addresses,
+// CIDR ranges and users through an override list.
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrInvalidOverride is returned when an admin submits a malformed override
+var ErrInvalidOverride = errors.New("invalid geo override")
+
+// Provider looks up the location and anonymity of an IP address
+type Provider interface {
+	Lookup(ctx context.Context, ip string) (*entities.GeoIPResult, error)
+}
+
+// JurisdictionEvaluator applies the country rules to a signup location
+type JurisdictionEvaluator interface {
+	EvaluateOnboarding(ctx context.Context, countryCode string) (*entities.JurisdictionDecision, error)
+}
+
+// Repository persists geo access events and overrides
+type Repository interface {
+	RecordEvent(ctx context.Context, event *entities.GeoAccessEvent) error
+	LatestFlaggedLogin(ctx context.Context, userID uuid.UUID, since time.Time) (*entities.GeoAccessEvent, error)
+	ListEventsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.GeoAccessEvent, error)
+	ListOverrides(ctx context.Context) ([]*entities.GeoOverride, error)
+	UpsertOverride(ctx context.Context, override *entities.GeoOverride) error
+	DeleteOverride(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// Config controls when sessions are flagged
+type Config struct {
+	HighRiskScore    int           // Provider risk score at or above which a masked session is flagged
+	FlagTTL          time.Duration // How long a flagged login raises the user's fraud score
+	OverrideCacheTTL time.Duration // How long the override list is cached
+}
+
+// DefaultConfig returns the default geo-IP configuration
+func DefaultConfig() Config {
+	return Config{
+		HighRiskScore:    75,
+		FlagTTL:          24 * time.Hour,
+		OverrideCacheTTL: time.Minute,
+	}
+}
+
+// Service checks signups and logins against their geo-IP lookup
+type Service struct {
+	provider     Provider
+	jurisdiction JurisdictionEvaluator
+	repo         Repository
+	config       Config
+	logger       *zap.Logger
+	now          func() time.Time
+
+	mu        sync.RWMutex
+	overrides []*entities.GeoOverride
+	loadedAt  time.Time
+}
+
+// NewService creates a new geo-IP service
+func NewService(provider Provider, jurisdiction JurisdictionEvaluator, repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.HighRiskScore <= 0 {
+		config.HighRiskScore = defaults.HighRiskScore
+	}
+	if config.FlagTTL <= 0 {
+		config.FlagTTL = defaults.FlagTTL
+	}
+	if config.OverrideCacheTTL <= 0 {
+		config.OverrideCacheTTL = defaults.OverrideCacheTTL
+	}
+	return &Service{
+		provider:     provider,
+		jurisdiction: jurisdiction,
+		repo:         repo,
+		config:       config,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// CheckSignup looks up a signup's address and refuses it with
+// ErrSignupLocationBlocked when the country rules do not allow onboarding from
+// there. Refused attempts are recorded here; an allowed event is recorded with
+// RecordSignup once the account exists. Addresses that cannot be located are
+// let through so a provider outage does not stop signups.
+func (s *Service) CheckSignup(ctx context.Context, ip string) (*entities.GeoAccessEvent, error) {
+	event, result := s.locate(ctx, entities.GeoAccessSignup, ip)
+	if result == nil {
+		return event, nil
+	}
+
+	overridden := s.overridden(ctx, ip, nil)
+	s.flag(event, result, overridden)
+	if event.CountryCode == "" {
+		return event, nil
+	}
+
+	decision, err := s.jurisdiction.EvaluateOnboarding(ctx, event.CountryCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate signup country: %w", err)
+	}
+	if decision.Allowed {
+		return event, nil
+	}
+	if overridden {
+		event.Overridden = true
+		s.logger.Info("Signup from prohibited country allowed by override",
+			zap.String("ip", ip),
+			zap.String("country_code", event.CountryCode))
+		return event, nil
+	}
+
+	event.Blocked = true
+	if err := s.repo.RecordEvent(ctx, event); err != nil {
+		s.logger.Warn("Failed to record blocked signup", zap.Error(err))
+	}
+	s.logger.Warn("Signup blocked by country rules",
+		zap.String("ip", ip),
+		zap.String("country_code", event.CountryCode),
+		zap.Bool("masked", result.Masked()))
+	return event, entities.ErrSignupLocationBlocked
+}
+
+// RecordSignup stores an allowed signup's geo data against the new user
+func (s *Service) RecordSignup(ctx context.Context, userID uuid.UUID, event *entities.GeoAccessEvent) error {
+	event.UserID = &userID
+	return s.repo.RecordEvent(ctx, event)
+}
+
+// RecordLogin looks up and stores a login's address, flagging the session
+// when it comes through a VPN, proxy or Tor exit with a high risk score
+func (s *Service) RecordLogin(ctx context.Context, userID uuid.UUID, ip string) (*entities.GeoAccessEvent, error) {
+	event, result := s.locate(ctx, entities.GeoAccessLogin, ip)
+	event.UserID = &userID
+	if result != nil {
+		s.flag(event, result, s.overridden(ctx, ip, &userID))
+	}
+
+	if err := s.repo.RecordEvent(ctx, event); err != nil {
+		return event, err
+	}
+	if event.Flagged {
+		s.logger.Warn("High-risk masked login flagged",
+			zap.String("user_id", userID.String()),
+			zap.String("ip", ip),
+			zap.String("country_code", event.CountryCode),
+			zap.Int("risk_score", event.RiskScore))
+	}
+	return event, nil
+}
+
+// SessionRisk returns the user's most recent flagged login within the flag
+// TTL, or nil when their recent sessions are not flagged
+func (s *Service) SessionRisk(ctx context.Context, userID uuid.UUID) (*entities.GeoAccessEvent, error) {
+	return s.repo.LatestFlaggedLogin(ctx, userID, s.now().Add(-s.config.FlagTTL))
+}
+
+// UserEvents returns a user's most recent signup and login locations
+func (s *Service) UserEvents(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.GeoAccessEvent, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.repo.ListEventsByUser(ctx, userID, limit)
+}
+
+// ListOverrides returns the admin override list
+func (s *Service) ListOverrides(ctx context.Context) ([]*entities.GeoOverride, error) {
+	return s.repo.ListOverrides(ctx)
+}
+
+// CreateOverride adds an address, CIDR range or user to the override list
+func (s *Service) CreateOverride(ctx context.Context, req *entities.CreateGeoOverrideRequest, createdBy uuid.UUID) (*entities.GeoOverride, error) {
+	value, err := normalizeOverrideValue(req.Kind, req.Value)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidOverride)
+	}
+	now := s.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expiry must be in the future", ErrInvalidOverride)
+	}
+
+	override := &entities.GeoOverride{
+		ID:        uuid.New(),
+		Kind:      req.Kind,
+		Value:     value,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if err := s.repo.UpsertOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	s.logger.Info("Geo override saved",
+		zap.String("kind", string(override.Kind)),
+		zap.String("value", override.Value),
+		zap.String("created_by", createdBy.String()))
+	return override, nil
+}
+
+// DeleteOverride removes an entry from the override list
+func (s *Service) DeleteOverride(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteOverride(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return entities.ErrGeoOverrideNotFound
+	}
+	s.invalidate()
+	return nil
+}
+
+// locate builds an event for the address and looks it up. The result is nil
+// for private addresses and when the lookup fails.
+func (s *Service) locate(ctx context.Context, eventType entities.GeoAccessEventType, ip string) (*entities.GeoAccessEvent, *entities.GeoIPResult) {
+	event := &entities.GeoAccessEvent{
+		ID:        uuid.New(),
+		EventType: eventType,
+		IPAddress: ip,
+		CreatedAt: s.now(),
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() {
+		return event, nil
+	}
+
+	result, err := s.provider.Lookup(ctx, ip)
+	if err != nil {
+		s.logger.Warn("Geo-IP lookup failed",
+			zap.String("ip", ip),
+			zap.String("event_type", string(eventType)),
+			zap.Error(err))
+		return event, nil
+	}
+	event.Apply(result)
+	return event, result
+}
+
+func (s *Service) flag(event *entities.GeoAccessEvent, result *entities.GeoIPResult, overridden bool) {
+	if !result.Masked() || result.RiskScore < s.config.HighRiskScore {
+		return
+	}
+	if overridden {
+		event.Overridden = true
+		return
+	}
+	event.Flagged = true
+}
+
+// overridden reports whether the address or user is on the override list. A
+// list that cannot be loaded is treated as empty.
+func (s *Service) overridden(ctx context.Context, ip string, userID *uuid.UUID) bool {
+	overrides, err := s.loadOverrides(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load geo overrides", zap.Error(err))
+		return false
+	}
+
+	now := s.now()
+	addr := net.ParseIP(ip)
+	for _, override := range overrides {
+		if !override.Active(now) {
+			continue
+		}
+		switch override.Kind {
+		case entities.GeoOverrideUser:
+			if userID != nil && override.Value == userID.String() {
+				return true
+			}
+		case entities.GeoOverrideIP:
+			if addr != nil && matchesAddress(override.Value, addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Service) loadOverrides(ctx context.Context) ([]*entities.GeoOverride, error) {
+	s.mu.RLock()
+	if s.overrides != nil && s.now().Sub(s.loadedAt) < s.config.OverrideCacheTTL {
+		overrides := s.overrides
+		s.mu.RUnlock()
+		return overrides, nil
+	}
+	s.mu.RUnlock()
+
+	overrides, err := s.repo.ListOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if overrides == nil {
+		overrides = []*entities.GeoOverride{}
+	}
+
+	s.mu.Lock()
+	s.overrides = overrides
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+	return overrides, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func matchesAddress(value string, addr net.IP) bool {
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network.Contains(addr)
+	}
+	if other := net.ParseIP(value); other != nil {
+		return other.Equal(addr)
+	}
+	return false
+}
+
+// normalizeOverrideValue validates an override's value and returns it in the
+// form it is matched against
+func normalizeOverrideValue(kind entities.GeoOverrideKind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case entities.GeoOverrideIP:
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			return network.String(), nil
+		}
+		if addr := net.ParseIP(value); addr != nil {
+			return addr.String(), nil
+		}
+		return "", fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidOverride, value)
+	case entities.GeoOverrideUser:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q is not a user ID", ErrInvalidOverride, value)
+		}
+		return id.String(), nil
+	default:
+		return "", fmt.Errorf("%w: unknown kind %q", ErrInvalidOverride, kind)
+	}
+}
@@ -0,0 +1,66 @@
+package goals
+
+import (
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// daysPerMonth converts elapsed time to fractional months
+const daysPerMonth = 365.25 / 12
+
+// monthlyRate converts an expected annual return to the equivalent monthly
+// compounding rate
+func monthlyRate(annual decimal.Decimal) float64 {
+	return math.Pow(1+annual.InexactFloat64(), 1.0/12) - 1
+}
+
+// futureValue grows present by rate for months while contributing monthly at
+// the end of each month
+func futureValue(present, monthly decimal.Decimal, rate, months float64) decimal.Decimal {
+	growth := math.Pow(1+rate, months)
+	value := present.InexactFloat64() * growth
+	if rate == 0 {
+		value += monthly.InexactFloat64() * months
+	} else {
+		value += monthly.InexactFloat64() * (growth - 1) / rate
+	}
+	return decimal.NewFromFloat(value).Round(2)
+}
+
+// requiredMonthly is the monthly contribution that grows present to target
+// over months; zero when growth alone gets there
+func requiredMonthly(present, target decimal.Decimal, rate float64, months int) decimal.Decimal {
+	if months < 1 {
+		months = 1
+	}
+	n := float64(months)
+	growth := math.Pow(1+rate, n)
+	gap := target.InexactFloat64() - present.InexactFloat64()*growth
+
+	var payment float64
+	if rate == 0 {
+		payment = gap / n
+	} else {
+		payment = gap * rate / (growth - 1)
+	}
+	if payment <= 0 {
+		return decimal.Zero
+	}
+	// Round up so the plan never falls short by a cent
+	return decimal.NewFromFloat(payment).RoundUp(2)
+}
+
+// monthsBetween counts the whole months from from until to, rounding up so a
+// part month still gets a contribution
+func monthsBetween(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	return int(math.Ceil(elapsedMonths(from, to)))
+}
+
+func elapsedMonths(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / daysPerMonth
+}
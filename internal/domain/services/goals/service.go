@@ -0,0 +1,479 @@
+package goals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository persists investment goals
+type Repository interface {
+	Create(ctx context.Context, goal *entities.Goal) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Goal, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Goal, error)
+	Update(ctx context.Context, goal *entities.Goal) error
+	// ListActive pages through active goals ordered by ID
+	ListActive(ctx context.Context, afterID uuid.UUID, limit int) ([]*entities.Goal, error)
+}
+
+// PositionRepository lists a user's basket positions
+type PositionRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error)
+}
+
+// Notifier delivers nudges
+type Notifier interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// Config holds the return assumptions and nudge policy
+type Config struct {
+	// ExpectedReturns are the annual returns assumed per risk level, e.g. 0.06
+	ExpectedReturns map[entities.RiskLevel]decimal.Decimal
+	// BehindTolerance is how far below the planned value, as a fraction of
+	// it, a portfolio may fall before the user is nudged
+	BehindTolerance decimal.Decimal
+	NudgeCooldown   time.Duration // Minimum gap between nudges for one goal
+	BatchSize       int
+	Interval        time.Duration
+}
+
+// DefaultConfig assumes 4%, 6% and 8% a year for conservative, balanced and
+// growth goals and nudges at most weekly once 5% behind plan
+func DefaultConfig() Config {
+	return Config{
+		ExpectedReturns: map[entities.RiskLevel]decimal.Decimal{
+			entities.RiskLevelConservative: decimal.RequireFromString("0.04"),
+			entities.RiskLevelBalanced:     decimal.RequireFromString("0.06"),
+			entities.RiskLevelGrowth:       decimal.RequireFromString("0.08"),
+		},
+		BehindTolerance: decimal.RequireFromString("0.05"),
+		NudgeCooldown:   7 * 24 * time.Hour,
+		BatchSize:       200,
+		Interval:        24 * time.Hour,
+	}
+}
+
+// SweepReport summarizes a progress sweep
+type SweepReport struct {
+	Checked  int `json:"checked"`
+	Achieved int `json:"achieved"`
+	Nudged   int `json:"nudged"`
+	Failed   int `json:"failed"`
+}
+
+// Service plans investment goals and tracks them against the user's
+// portfolio. A goal's plan projects its baseline value forward at the
+// expected return for its risk level plus the planned monthly contribution;
+// the user is nudged when the portfolio falls behind that path.
+type Service struct {
+	repo      Repository
+	positions PositionRepository
+	notifier  Notifier
+	config    Config
+	logger    *zap.Logger
+	tracker   *workerstatus.Tracker
+	now       func() time.Time
+}
+
+// NewService creates a new goals service
+func NewService(repo Repository, positions PositionRepository, notifier Notifier, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if len(config.ExpectedReturns) == 0 {
+		config.ExpectedReturns = defaults.ExpectedReturns
+	}
+	if !config.BehindTolerance.IsPositive() {
+		config.BehindTolerance = defaults.BehindTolerance
+	}
+	if config.NudgeCooldown <= 0 {
+		config.NudgeCooldown = defaults.NudgeCooldown
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:      repo,
+		positions: positions,
+		notifier:  notifier,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports sweeps to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often progress is swept
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Create sets a goal and plans it from the user's current portfolio value
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, req *entities.CreateGoalRequest) (*entities.GoalProgress, error) {
+	riskLevel := req.RiskLevel
+	if riskLevel == "" {
+		riskLevel = entities.RiskLevelBalanced
+	}
+	now := s.now().UTC()
+	goal := &entities.Goal{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Name:         strings.TrimSpace(req.Name),
+		TargetAmount: req.TargetAmount,
+		TargetDate:   req.TargetDate.UTC(),
+		RiskLevel:    riskLevel,
+		Status:       entities.GoalStatusActive,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.validate(goal, now, true); err != nil {
+		return nil, err
+	}
+
+	value, err := s.portfolioValue(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.plan(goal, value, now)
+	if err := s.repo.Create(ctx, goal); err != nil {
+		return nil, fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	s.logger.Info("Investment goal created",
+		zap.String("goal_id", goal.ID.String()),
+		zap.String("user_id", userID.String()),
+		logger.Amount("target_amount", goal.TargetAmount),
+		zap.String("planned_monthly", goal.PlannedMonthly.String()))
+	return s.progress(goal, value, now), nil
+}
+
+// Get returns one of the user's goals with its progress
+func (s *Service) Get(ctx context.Context, userID, goalID uuid.UUID) (*entities.GoalProgress, error) {
+	goal, err := s.owned(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.portfolioValue(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(goal, value, s.now().UTC()), nil
+}
+
+// List returns the user's goals with their progress
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*entities.GoalProgress, error) {
+	goals, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+	result := make([]*entities.GoalProgress, 0, len(goals))
+	if len(goals) == 0 {
+		return result, nil
+	}
+
+	value, err := s.portfolioValue(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	for _, goal := range goals {
+		result = append(result, s.progress(goal, value, now))
+	}
+	return result, nil
+}
+
+// Update changes an active goal. A new target, date or risk level re-plans
+// it from the current portfolio value.
+func (s *Service) Update(ctx context.Context, userID, goalID uuid.UUID, req *entities.UpdateGoalRequest) (*entities.GoalProgress, error) {
+	goal, err := s.owned(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status != entities.GoalStatusActive {
+		return nil, fmt.Errorf("%w: only active goals can be changed", entities.ErrInvalidGoal)
+	}
+
+	replan := false
+	if req.Name != nil {
+		goal.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.TargetAmount != nil {
+		goal.TargetAmount = *req.TargetAmount
+		replan = true
+	}
+	if req.TargetDate != nil {
+		goal.TargetDate = req.TargetDate.UTC()
+		replan = true
+	}
+	if req.RiskLevel != nil {
+		goal.RiskLevel = *req.RiskLevel
+		replan = true
+	}
+	now := s.now().UTC()
+	if err := s.validate(goal, now, req.TargetDate != nil); err != nil {
+		return nil, err
+	}
+
+	value, err := s.portfolioValue(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if replan {
+		s.plan(goal, value, now)
+	}
+	goal.UpdatedAt = now
+	if err := s.repo.Update(ctx, goal); err != nil {
+		return nil, fmt.Errorf("failed to update goal: %w", err)
+	}
+	return s.progress(goal, value, now), nil
+}
+
+// Cancel stops tracking a goal
+func (s *Service) Cancel(ctx context.Context, userID, goalID uuid.UUID) error {
+	goal, err := s.owned(ctx, userID, goalID)
+	if err != nil {
+		return err
+	}
+	if goal.Status == entities.GoalStatusCancelled {
+		return nil
+	}
+	goal.Status = entities.GoalStatusCancelled
+	goal.UpdatedAt = s.now().UTC()
+	if err := s.repo.Update(ctx, goal); err != nil {
+		return fmt.Errorf("failed to cancel goal: %w", err)
+	}
+	return nil
+}
+
+// Start runs a progress sweep on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				report, err := s.Sweep(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Goal progress sweep failed", zap.Error(err))
+					continue
+				}
+				s.logger.Info("Goal progress sweep completed",
+					zap.Int("checked", report.Checked),
+					zap.Int("achieved", report.Achieved),
+					zap.Int("nudged", report.Nudged))
+			}
+		}
+	}()
+}
+
+// Sweep checks every active goal: goals whose target is reached are marked
+// achieved, and users behind plan are nudged unless nudged recently
+func (s *Service) Sweep(ctx context.Context) (*SweepReport, error) {
+	report := &SweepReport{}
+	values := make(map[uuid.UUID]decimal.Decimal)
+	afterID := uuid.Nil
+
+	for {
+		batch, err := s.repo.ListActive(ctx, afterID, s.config.BatchSize)
+		if err != nil {
+			return report, fmt.Errorf("failed to list active goals: %w", err)
+		}
+		for _, goal := range batch {
+			afterID = goal.ID
+			report.Checked++
+			if err := s.check(ctx, goal, values, report); err != nil {
+				report.Failed++
+				s.logger.Warn("Failed to check goal progress",
+					zap.String("goal_id", goal.ID.String()),
+					zap.Error(err))
+			}
+		}
+		if len(batch) < s.config.BatchSize {
+			return report, nil
+		}
+	}
+}
+
+func (s *Service) check(ctx context.Context, goal *entities.Goal, values map[uuid.UUID]decimal.Decimal, report *SweepReport) error {
+	value, ok := values[goal.UserID]
+	if !ok {
+		var err error
+		if value, err = s.portfolioValue(ctx, goal.UserID); err != nil {
+			return err
+		}
+		values[goal.UserID] = value
+	}
+
+	now := s.now().UTC()
+	progress := s.progress(goal, value, now)
+	switch {
+	case value.GreaterThanOrEqual(goal.TargetAmount):
+		goal.Status = entities.GoalStatusAchieved
+		goal.AchievedAt = &now
+		goal.UpdatedAt = now
+		if err := s.repo.Update(ctx, goal); err != nil {
+			return err
+		}
+		report.Achieved++
+		s.notify(ctx, goal, "Goal reached",
+			fmt.Sprintf("Your portfolio has reached the %s target for %q.", goal.TargetAmount.StringFixed(2), goal.Name), progress)
+	case !progress.OnTrack && s.nudgeDue(goal, now):
+		goal.LastNudgedAt = &now
+		goal.UpdatedAt = now
+		if err := s.repo.Update(ctx, goal); err != nil {
+			return err
+		}
+		report.Nudged++
+		s.notify(ctx, goal, "Your goal needs attention",
+			fmt.Sprintf("You're behind plan for %q. Investing %s a month would get you back on track.",
+				goal.Name, progress.RequiredMonthly.StringFixed(2)), progress)
+	}
+	return nil
+}
+
+func (s *Service) nudgeDue(goal *entities.Goal, now time.Time) bool {
+	return goal.LastNudgedAt == nil || now.Sub(*goal.LastNudgedAt) >= s.config.NudgeCooldown
+}
+
+func (s *Service) notify(ctx context.Context, goal *entities.Goal, title, message string, progress *entities.GoalProgress) {
+	if s.notifier == nil {
+		return
+	}
+	notification := &entities.Notification{
+		ID:       uuid.New(),
+		UserID:   goal.UserID,
+		Type:     entities.NotificationTypePortfolio,
+		Channel:  entities.ChannelInApp,
+		Priority: entities.PriorityMedium,
+		Title:    title,
+		Message:  message,
+		Data: map[string]interface{}{
+			"goal_id":          goal.ID.String(),
+			"current_value":    progress.CurrentValue.String(),
+			"planned_value":    progress.PlannedValue.String(),
+			"required_monthly": progress.RequiredMonthly.String(),
+		},
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.notifier.Send(ctx, notification, &entities.UserPreference{UserID: goal.UserID}); err != nil {
+		s.logger.Warn("Failed to send goal notification", zap.String("goal_id", goal.ID.String()), zap.Error(err))
+	}
+}
+
+// plan fixes the goal's baseline and the monthly contribution that reaches
+// the target from it
+func (s *Service) plan(goal *entities.Goal, value decimal.Decimal, now time.Time) {
+	goal.BaselineValue = value
+	goal.BaselineAt = now
+	goal.PlannedMonthly = requiredMonthly(value, goal.TargetAmount,
+		monthlyRate(s.expectedReturn(goal.RiskLevel)), monthsBetween(now, goal.TargetDate))
+}
+
+func (s *Service) progress(goal *entities.Goal, value decimal.Decimal, now time.Time) *entities.GoalProgress {
+	annual := s.expectedReturn(goal.RiskLevel)
+	rate := monthlyRate(annual)
+
+	// The plan stops at the target date; past it the target itself is the bar
+	planAt := now
+	if planAt.After(goal.TargetDate) {
+		planAt = goal.TargetDate
+	}
+	planned := futureValue(goal.BaselineValue, goal.PlannedMonthly, rate, elapsedMonths(goal.BaselineAt, planAt))
+	months := monthsBetween(now, goal.TargetDate)
+	required := requiredMonthly(value, goal.TargetAmount, rate, months)
+
+	progress := &entities.GoalProgress{
+		Goal:                 goal,
+		ExpectedAnnualReturn: annual,
+		CurrentValue:         value,
+		PlannedValue:         planned,
+		ProgressPercent:      decimal.Zero,
+		RequiredMonthly:      required,
+		ProjectedValue:       futureValue(value, required, rate, float64(months)),
+		MonthsRemaining:      months,
+	}
+	if goal.TargetAmount.IsPositive() {
+		progress.ProgressPercent = value.Div(goal.TargetAmount).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+	floor := planned.Mul(decimal.NewFromInt(1).Sub(s.config.BehindTolerance))
+	progress.OnTrack = goal.Status != entities.GoalStatusActive || value.GreaterThanOrEqual(floor)
+	return progress
+}
+
+func (s *Service) expectedReturn(riskLevel entities.RiskLevel) decimal.Decimal {
+	if annual, ok := s.config.ExpectedReturns[riskLevel]; ok {
+		return annual
+	}
+	return s.config.ExpectedReturns[entities.RiskLevelBalanced]
+}
+
+// validate checks a goal's fields. The target date is only checked when it is
+// being set, so an existing goal near its date can still be renamed.
+func (s *Service) validate(goal *entities.Goal, now time.Time, checkDate bool) error {
+	if goal.Name == "" {
+		return fmt.Errorf("%w: name is required", entities.ErrInvalidGoal)
+	}
+	if !goal.TargetAmount.IsPositive() {
+		return fmt.Errorf("%w: target amount must be positive", entities.ErrInvalidGoal)
+	}
+	if checkDate && goal.TargetDate.Before(now.AddDate(0, 1, 0)) {
+		return fmt.Errorf("%w: target date must be at least a month away", entities.ErrInvalidGoal)
+	}
+	if _, ok := s.config.ExpectedReturns[goal.RiskLevel]; !ok {
+		return fmt.Errorf("%w: unknown risk level %q", entities.ErrInvalidGoal, goal.RiskLevel)
+	}
+	return nil
+}
+
+func (s *Service) owned(ctx context.Context, userID, goalID uuid.UUID) (*entities.Goal, error) {
+	goal, err := s.repo.GetByID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.UserID != userID {
+		return nil, entities.ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+// portfolioValue is the market value of the user's basket positions
+func (s *Service) portfolioValue(ctx context.Context, userID uuid.UUID) (decimal.Decimal, error) {
+	positions, err := s.positions.GetByUserID(ctx, userID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to load positions: %w", err)
+	}
+	total := decimal.Zero
+	for _, position := range positions {
+		total = total.Add(position.MarketValue)
+	}
+	return total, nil
+}
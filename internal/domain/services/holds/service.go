@@ -0,0 +1,227 @@
+package holds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// Repository persists holds and applies them to buying power atomically
+type Repository interface {
+	// Place inserts a hold and deducts it from buying power, failing with
+	// entities.ErrInsufficientBuyingPower when it is not covered. A hold that
+	// already exists for the operation is returned with created=false.
+	Place(ctx context.Context, hold *entities.BalanceHold) (*entities.BalanceHold, bool, error)
+	// Settle captures or releases an active hold, returning released amounts
+	// to buying power. Settled holds are returned with settled=false.
+	Settle(ctx context.Context, id uuid.UUID, status entities.HoldStatus, at time.Time) (*entities.BalanceHold, bool, error)
+	GetByReference(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error)
+	ListActive(ctx context.Context, userID uuid.UUID) ([]*entities.BalanceHold, error)
+	SetLedgerTransaction(ctx context.Context, id, transactionID uuid.UUID) error
+}
+
+// Ledger posts the double-entry mirror of holds
+type Ledger interface {
+	GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+}
+
+// Service places holds on buying power for pending orders and withdrawals
+// and settles them when the operation completes. Placing a hold is the only
+// check against buying power, so two requests racing for the same balance
+// cannot both succeed. When a ledger is set, holds are mirrored from the
+// user's USDC balance into a held balance account and captured into the
+// account the operation spends into.
+type Service struct {
+	repo   Repository
+	ledger Ledger
+	logger *zap.Logger
+}
+
+// NewService creates a new balance hold service
+func NewService(repo Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// SetLedger enables posting holds to the ledger
+func (s *Service) SetLedger(l Ledger) {
+	s.ledger = l
+}
+
+// Place holds amount of the user's buying power for an operation. Placing a
+// hold for an operation that already has one returns the existing hold.
+func (s *Service) Place(ctx context.Context, userID uuid.UUID, kind entities.HoldKind, referenceID uuid.UUID, amount decimal.Decimal) (*entities.BalanceHold, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("hold amount must be positive")
+	}
+
+	hold, created, err := s.repo.Place(ctx, &entities.BalanceHold{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Kind:        kind,
+		ReferenceID: referenceID,
+		Amount:      amount,
+		Status:      entities.HoldStatusActive,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return hold, nil
+	}
+
+	s.logger.Info("Balance hold placed",
+		zap.String("hold_id", hold.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("kind", string(kind)),
+		logger.Amount("amount", amount))
+
+	if s.ledger != nil {
+		txID, err := s.post(ctx, hold, "place", "Hold funds for pending "+string(kind),
+			entities.AccountTypeUSDCBalance, func(heldID, usdcID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+				return ledger.CreateHoldEntries(usdcID, heldID, amount)
+			})
+		if err != nil {
+			s.logger.Warn("Failed to post balance hold to ledger", zap.String("hold_id", hold.ID.String()), zap.Error(err))
+		} else if err := s.repo.SetLedgerTransaction(ctx, hold.ID, txID); err != nil {
+			s.logger.Warn("Failed to record balance hold ledger transaction", zap.String("hold_id", hold.ID.String()), zap.Error(err))
+		} else {
+			hold.LedgerTransactionID = &txID
+		}
+	}
+	return hold, nil
+}
+
+// Capture settles the operation's hold as spent. Capturing a captured hold
+// is a no-op; capturing a released one fails with entities.ErrBalanceHoldSettled.
+func (s *Service) Capture(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	return s.settle(ctx, kind, referenceID, entities.HoldStatusCaptured)
+}
+
+// Release returns the operation's hold to buying power. Releasing a released
+// hold is a no-op; releasing a captured one fails with entities.ErrBalanceHoldSettled.
+func (s *Service) Release(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	return s.settle(ctx, kind, referenceID, entities.HoldStatusReleased)
+}
+
+// ListActive returns the user's active holds and their total
+func (s *Service) ListActive(ctx context.Context, userID uuid.UUID) (*entities.ActiveHolds, error) {
+	holds, err := s.repo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := decimal.Zero
+	for _, hold := range holds {
+		total = total.Add(hold.Amount)
+	}
+	if holds == nil {
+		holds = []*entities.BalanceHold{}
+	}
+	return &entities.ActiveHolds{Holds: holds, TotalHeld: total}, nil
+}
+
+func (s *Service) settle(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID, status entities.HoldStatus) (*entities.BalanceHold, error) {
+	hold, err := s.repo.GetByReference(ctx, kind, referenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, settled, err := s.repo.Settle(ctx, hold.ID, status, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !settled {
+		if hold.Status != status {
+			return nil, entities.ErrBalanceHoldSettled
+		}
+		return hold, nil
+	}
+
+	s.logger.Info("Balance hold settled",
+		zap.String("hold_id", hold.ID.String()),
+		zap.String("user_id", hold.UserID.String()),
+		zap.String("status", string(status)))
+
+	// Only holds that reached the ledger are settled there
+	if s.ledger == nil || hold.LedgerTransactionID == nil {
+		return hold, nil
+	}
+
+	var postErr error
+	switch {
+	case status == entities.HoldStatusReleased:
+		_, postErr = s.post(ctx, hold, "release", "Release held funds",
+			entities.AccountTypeUSDCBalance, ledger.CreateHoldReleaseEntries)
+	case hold.Kind == entities.HoldKindWithdrawal:
+		_, postErr = s.post(ctx, hold, "capture", "Held funds withdrawn",
+			entities.AccountTypeSystemBufferUSDC, ledger.CreateWithdrawalEntries)
+	default:
+		_, postErr = s.post(ctx, hold, "capture", "Held funds invested",
+			entities.AccountTypeFiatExposure, ledger.CreateInvestmentEntries)
+	}
+	if postErr != nil {
+		s.logger.Warn("Failed to post balance hold settlement to ledger", zap.String("hold_id", hold.ID.String()), zap.Error(postErr))
+	}
+	return hold, nil
+}
+
+// post writes one ledger transaction between the user's held balance and
+// counterparty, keyed on the hold and step so retries return the original
+// transaction
+func (s *Service) post(
+	ctx context.Context,
+	hold *entities.BalanceHold,
+	step, description string,
+	counterparty entities.AccountType,
+	entries func(heldBalanceID, counterpartyID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest,
+) (uuid.UUID, error) {
+	held, err := s.ledger.GetOrCreateUserAccount(ctx, hold.UserID, entities.AccountTypeHeldBalance)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get held balance account: %w", err)
+	}
+	var other *entities.LedgerAccount
+	if counterparty.IsSystemAccountType() {
+		other, err = s.ledger.GetSystemAccount(ctx, counterparty)
+	} else {
+		other, err = s.ledger.GetOrCreateUserAccount(ctx, hold.UserID, counterparty)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get %s account: %w", counterparty, err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithUser(hold.UserID).
+		WithType(entities.TransactionTypeBalanceHold).
+		WithReference(hold.ReferenceID, string(hold.Kind)).
+		WithIdempotencyKey(fmt.Sprintf("hold-%s-%s", step, hold.ID)).
+		WithDescription(description).
+		WithMetadata(map[string]any{
+			"hold_id": hold.ID.String(),
+			"step":    step,
+		}).
+		WithEntries(entries(held.ID, other.ID, hold.Amount)).
+		Build()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to build balance hold ledger transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to post balance hold ledger transaction: %w", err)
+	}
+	return tx.ID, nil
+}
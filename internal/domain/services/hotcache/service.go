@@ -0,0 +1,239 @@
+package hotcache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// Store is the subset of the Redis client the cache uses
+type Store interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	Keys(ctx context.Context, pattern string) ([]string, error)
+}
+
+// BalanceLoader reads a user's balances
+type BalanceLoader interface {
+	GetBalance(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error)
+}
+
+// PortfolioLoader reads a user's live portfolio
+type PortfolioLoader interface {
+	GetPortfolio(ctx context.Context, userID uuid.UUID) (*entities.Portfolio, error)
+}
+
+// BasketLoader reads a basket from the database
+type BasketLoader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
+}
+
+// ActiveUsers lists the users most likely to be back soon
+type ActiveUsers interface {
+	MostActiveUsers(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error)
+}
+
+// Config controls cache lifetimes and how much a warm-up loads
+type Config struct {
+	UserTTL      time.Duration // Balances and portfolios; they are also dropped when a deposit lands or an order is placed
+	BasketTTL    time.Duration // Baskets; admin edits show after at most this long
+	ViewWindow   time.Duration // How long basket views count towards the most viewed
+	ActiveWindow time.Duration // Users seen within this window are warmed
+	MaxUsers     int
+	MaxBaskets   int
+	Concurrency  int // Loads in flight during a warm-up
+}
+
+// DefaultConfig returns the default cache configuration
+func DefaultConfig() Config {
+	return Config{
+		UserTTL:      2 * time.Minute,
+		BasketTTL:    5 * time.Minute,
+		ViewWindow:   7 * 24 * time.Hour,
+		ActiveWindow: time.Hour,
+		MaxUsers:     500,
+		MaxBaskets:   50,
+		Concurrency:  8,
+	}
+}
+
+// Service serves the busiest read endpoints - balances, the portfolio
+// overview and basket detail - from Redis, loading on a miss. After a deploy
+// it can be warmed for recently active users and the most viewed baskets so
+// their first requests do not pay for the load.
+type Service struct {
+	store      Store
+	balances   BalanceLoader
+	portfolios PortfolioLoader
+	baskets    BasketLoader
+	users      ActiveUsers
+	config     Config
+	logger     *zap.Logger
+	now        func() time.Time
+
+	mu   sync.Mutex
+	last *entities.CacheWarmReport
+}
+
+// NewService creates a hot endpoint cache
+func NewService(store Store, balances BalanceLoader, portfolios PortfolioLoader, baskets BasketLoader, users ActiveUsers, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.UserTTL <= 0 {
+		config.UserTTL = defaults.UserTTL
+	}
+	if config.BasketTTL <= 0 {
+		config.BasketTTL = defaults.BasketTTL
+	}
+	if config.ViewWindow <= 0 {
+		config.ViewWindow = defaults.ViewWindow
+	}
+	if config.ActiveWindow <= 0 {
+		config.ActiveWindow = defaults.ActiveWindow
+	}
+	if config.MaxUsers < 0 {
+		config.MaxUsers = 0
+	}
+	if config.MaxBaskets < 0 {
+		config.MaxBaskets = 0
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	return &Service{
+		store:      store,
+		balances:   balances,
+		portfolios: portfolios,
+		baskets:    baskets,
+		users:      users,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// SetClock replaces the clock used to pick active users
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Balances returns the user's balances
+func (s *Service) Balances(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
+	var balances entities.BalancesResponse
+	if s.cached(ctx, "balances", balanceKey(userID), &balances) {
+		return &balances, nil
+	}
+	return s.loadBalances(ctx, userID)
+}
+
+// Portfolio returns the user's live portfolio
+func (s *Service) Portfolio(ctx context.Context, userID uuid.UUID) (*entities.Portfolio, error) {
+	var portfolio entities.Portfolio
+	if s.cached(ctx, "portfolio", portfolioKey(userID), &portfolio) {
+		return &portfolio, nil
+	}
+	return s.loadPortfolio(ctx, userID)
+}
+
+// Basket returns a basket, or nil when it does not exist, and counts the view
+func (s *Service) Basket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error) {
+	s.countView(ctx, basketID)
+
+	var basket entities.Basket
+	if s.cached(ctx, "basket", basketKey(basketID), &basket) {
+		return &basket, nil
+	}
+	return s.loadBasket(ctx, basketID)
+}
+
+// Forget drops a user's cached balances and portfolio
+func (s *Service) Forget(ctx context.Context, userID uuid.UUID) {
+	for _, key := range []string{balanceKey(userID), portfolioKey(userID)} {
+		if err := s.store.Del(ctx, key); err != nil {
+			s.logger.Warn("Failed to drop cached user data", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// cached reads key into dest. Redis errors, including misses, fall through
+// to the loader.
+func (s *Service) cached(ctx context.Context, kind, key string, dest interface{}) bool {
+	if err := s.store.Get(ctx, key, dest); err != nil {
+		metrics.HotCacheRequestsTotal.WithLabelValues(kind, "miss").Inc()
+		return false
+	}
+	metrics.HotCacheRequestsTotal.WithLabelValues(kind, "hit").Inc()
+	return true
+}
+
+func (s *Service) loadBalances(ctx context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
+	balances, err := s.balances.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.put(ctx, balanceKey(userID), balances, s.config.UserTTL)
+	return balances, nil
+}
+
+func (s *Service) loadPortfolio(ctx context.Context, userID uuid.UUID) (*entities.Portfolio, error) {
+	portfolio, err := s.portfolios.GetPortfolio(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.put(ctx, portfolioKey(userID), portfolio, s.config.UserTTL)
+	return portfolio, nil
+}
+
+func (s *Service) loadBasket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error) {
+	basket, err := s.baskets.GetByID(ctx, basketID)
+	if err != nil || basket == nil {
+		return basket, err
+	}
+	s.put(ctx, basketKey(basketID), basket, s.config.BasketTTL)
+	return basket, nil
+}
+
+func (s *Service) put(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if err := s.store.Set(ctx, key, value, ttl); err != nil {
+		s.logger.Warn("Failed to cache response", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (s *Service) countView(ctx context.Context, basketID uuid.UUID) {
+	key := basketViewsKey(basketID)
+	views, err := s.store.Incr(ctx, key)
+	if err != nil {
+		return
+	}
+	if views == 1 {
+		_ = s.store.Expire(ctx, key, s.config.ViewWindow)
+	}
+}
+
+// The user keys fall under the patterns CacheInvalidator.InvalidateUser drops
+func balanceKey(userID uuid.UUID) string {
+	return fmt.Sprintf("balance:%s:summary", userID)
+}
+
+func portfolioKey(userID uuid.UUID) string {
+	return fmt.Sprintf("portfolio:%s:overview", userID)
+}
+
+func basketKey(basketID uuid.UUID) string {
+	return fmt.Sprintf("basket:%s", basketID)
+}
+
+const basketViewsPrefix = "basket_views:"
+
+func basketViewsKey(basketID uuid.UUID) string {
+	return basketViewsPrefix + basketID.String()
+}
@@ -0,0 +1,190 @@
+package hotcache
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// warmTimeout bounds a background warm-up so a slow dependency cannot hold it open
+const warmTimeout = 5 * time.Minute
+
+// Warm loads balances and portfolios for recently active users and the most
+// viewed baskets into Redis, with at most Config.Concurrency loads in flight.
+// A load that fails is counted and skipped; the first request fills it as
+// usual. Only one warm-up runs at a time.
+func (s *Service) Warm(ctx context.Context, trigger entities.CacheWarmTrigger) (*entities.CacheWarmReport, error) {
+	report, err := s.begin(trigger)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, report), nil
+}
+
+// WarmInBackground starts a warm-up that outlives the caller, returning
+// ErrCacheWarmRunning straight away if one is already in progress
+func (s *Service) WarmInBackground(ctx context.Context, trigger entities.CacheWarmTrigger) error {
+	report, err := s.begin(trigger)
+	if err != nil {
+		return err
+	}
+	go func() {
+		warmCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), warmTimeout)
+		defer cancel()
+		s.run(warmCtx, report)
+	}()
+	return nil
+}
+
+// LastWarm returns the running or most recent warm-up, or nil if none has run
+func (s *Service) LastWarm() *entities.CacheWarmReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		return nil
+	}
+	report := *s.last
+	return &report
+}
+
+func (s *Service) begin(trigger entities.CacheWarmTrigger) (*entities.CacheWarmReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last != nil && s.last.Running {
+		return nil, entities.ErrCacheWarmRunning
+	}
+	s.last = &entities.CacheWarmReport{Trigger: trigger, Running: true, StartedAt: s.now()}
+	return s.last, nil
+}
+
+func (s *Service) run(ctx context.Context, report *entities.CacheWarmReport) *entities.CacheWarmReport {
+	var (
+		mu                     sync.Mutex
+		wg                     sync.WaitGroup
+		sem                    = make(chan struct{}, s.config.Concurrency)
+		users, baskets, failed int
+	)
+	load := func(fn func() error, loaded *int) {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := fn()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+			} else if loaded != nil {
+				*loaded++
+			}
+		}()
+	}
+
+	userIDs, err := s.activeUsers(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to list active users for cache warm-up", zap.Error(err))
+	}
+	for _, userID := range userIDs {
+		load(func() error {
+			_, err := s.loadBalances(ctx, userID)
+			return err
+		}, &users)
+		load(func() error {
+			_, err := s.loadPortfolio(ctx, userID)
+			return err
+		}, nil)
+	}
+
+	basketIDs, err := s.mostViewedBaskets(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to list most viewed baskets for cache warm-up", zap.Error(err))
+	}
+	for _, basketID := range basketIDs {
+		load(func() error {
+			_, err := s.loadBasket(ctx, basketID)
+			return err
+		}, &baskets)
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := s.now()
+	report.Running = false
+	report.FinishedAt = &finished
+	report.Users = users
+	report.Baskets = baskets
+	report.Failed = failed
+
+	s.logger.Info("Cache warm-up finished",
+		zap.String("trigger", string(report.Trigger)),
+		zap.Int("users", users),
+		zap.Int("baskets", baskets),
+		zap.Int("failed", failed),
+		zap.Duration("took", finished.Sub(report.StartedAt)))
+
+	done := *report
+	return &done
+}
+
+func (s *Service) activeUsers(ctx context.Context) ([]uuid.UUID, error) {
+	if s.users == nil || s.config.MaxUsers == 0 {
+		return nil, nil
+	}
+	return s.users.MostActiveUsers(ctx, s.now().Add(-s.config.ActiveWindow), s.config.MaxUsers)
+}
+
+// mostViewedBaskets ranks baskets by the view counters Basket keeps
+func (s *Service) mostViewedBaskets(ctx context.Context) ([]uuid.UUID, error) {
+	if s.config.MaxBaskets == 0 {
+		return nil, nil
+	}
+	keys, err := s.store.Keys(ctx, basketViewsPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	type viewed struct {
+		id    uuid.UUID
+		views int64
+	}
+	ranked := make([]viewed, 0, len(keys))
+	for _, key := range keys {
+		id, err := uuid.Parse(strings.TrimPrefix(key, basketViewsPrefix))
+		if err != nil {
+			continue
+		}
+		var views int64
+		if err := s.store.Get(ctx, key, &views); err != nil {
+			continue
+		}
+		ranked = append(ranked, viewed{id: id, views: views})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].views != ranked[j].views {
+			return ranked[i].views > ranked[j].views
+		}
+		return ranked[i].id.String() < ranked[j].id.String()
+	})
+
+	if len(ranked) > s.config.MaxBaskets {
+		ranked = ranked[:s.config.MaxBaskets]
+	}
+	ids := make([]uuid.UUID, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.id
+	}
+	return ids, nil
+}
@@ -0,0 +1,164 @@
+package httpcapture
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeyParts mark a header or JSON field as a credential wherever they
+// appear in its normalized name
+var secretKeyParts = []string{"password", "passcode", "secret", "token", "apikey", "privatekey", "mnemonic", "seedphrase", "signature", "cookie", "authorization", "cvv"}
+
+// personalKeys are JSON fields holding identity or account data, matched on
+// the whole normalized name
+var personalKeys = map[string]bool{
+	"pin": true, "newpin": true, "currentpin": true,
+	"otp": true, "otpcode": true, "code": true, "verificationcode": true, "smscode": true, "mfacode": true, "totp": true,
+	"ssn": true, "taxid": true, "tin": true, "dob": true, "dateofbirth": true,
+	"firstname": true, "lastname": true, "middlename": true, "fullname": true, "legalname": true,
+	"cardnumber": true, "accountnumber": true, "routingnumber": true, "iban": true,
+	"documentnumber": true, "idnumber": true,
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.NewReplacer("_", "", "-", "", " ", "").Replace(key)
+	return key
+}
+
+func isSecretKey(key string) bool {
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeHeaders keeps the first value of each request header, masking
+// credentials and session material
+func SanitizeHeaders(header http.Header) map[string]string {
+	sanitized := make(map[string]string, len(header))
+	for name, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		normalized := normalizeKey(name)
+		if isSecretKey(normalized) {
+			sanitized[name] = redacted
+			continue
+		}
+		sanitized[name] = values[0]
+	}
+	return sanitized
+}
+
+// SanitizeQuery masks credential and personal parameters in a raw query
+// string. Queries that do not parse are dropped.
+func SanitizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+	for name, params := range values {
+		key := normalizeKey(name)
+		for i, param := range params {
+			if masked, ok := sanitizeValue(key, param).(string); ok {
+				params[i] = masked
+			}
+		}
+		values[name] = params
+	}
+	return values.Encode()
+}
+
+// SanitizeBody returns a JSON body with credentials and personal data masked.
+// Bodies that are not JSON, or were cut off at the capture limit and no
+// longer parse, are described rather than stored, so nothing unmasked is
+// ever kept.
+func SanitizeBody(contentType string, body []byte, overflow bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	trimmed := strings.TrimSpace(string(body))
+	isJSON := strings.Contains(strings.ToLower(contentType), "json") ||
+		strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+	if !isJSON {
+		return fmt.Sprintf("[%s body, %d bytes]", describeType(contentType), len(body))
+	}
+	if overflow {
+		return fmt.Sprintf("[JSON body over capture limit, %d+ bytes]", len(body))
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("[unparseable JSON body, %d bytes]", len(body))
+	}
+	encoded, err := json.Marshal(sanitizeValue("", value))
+	if err != nil {
+		return fmt.Sprintf("[unencodable JSON body, %d bytes]", len(body))
+	}
+	return string(encoded)
+}
+
+func describeType(contentType string) string {
+	if contentType == "" {
+		return "untyped"
+	}
+	if semi := strings.Index(contentType, ";"); semi >= 0 {
+		contentType = contentType[:semi]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+func sanitizeValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, nested := range v {
+			v[field] = sanitizeValue(normalizeKey(field), nested)
+		}
+		return v
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = sanitizeValue(key, nested)
+		}
+		return v
+	case nil:
+		return nil
+	}
+
+	switch {
+	case key == "":
+		return value
+	case isSecretKey(key) || personalKeys[key]:
+		return redacted
+	}
+	text, ok := value.(string)
+	if !ok {
+		return value
+	}
+	switch {
+	case strings.Contains(key, "email"):
+		return logger.RedactEmail(text)
+	case strings.Contains(key, "phone"):
+		return logger.RedactPhone(text)
+	case strings.Contains(key, "address"):
+		// Wallet addresses keep their ends for correlation; postal addresses
+		// are removed entirely
+		if strings.ContainsAny(text, " ,") {
+			return redacted
+		}
+		return logger.RedactWalletAddr(text)
+	}
+	return value
+}
@@ -0,0 +1,312 @@
+package httpcapture
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrCaptureNotFound is returned for an unknown or expired capture
+	ErrCaptureNotFound = errors.New("capture not found")
+	// ErrTargetNotFound is returned for an unknown capture target
+	ErrTargetNotFound = errors.New("capture target not found")
+	// ErrInvalidTarget is returned when a target names neither a user nor a route
+	ErrInvalidTarget = errors.New("capture target needs a user_id or a route")
+)
+
+// Store persists captures and capture targets
+type Store interface {
+	SaveCapture(ctx context.Context, capture *entities.HTTPCapture) error
+	GetCapture(ctx context.Context, id uuid.UUID) (*entities.HTTPCapture, error)
+	ListCaptures(ctx context.Context, filter entities.HTTPCaptureFilter) ([]*entities.HTTPCapture, error)
+	CreateTarget(ctx context.Context, target *entities.HTTPCaptureTarget) error
+	DeleteTarget(ctx context.Context, id uuid.UUID) (bool, error)
+	ListActiveTargets(ctx context.Context) ([]*entities.HTTPCaptureTarget, error)
+}
+
+// Config configures sampling and retention of captures
+type Config struct {
+	Enabled       bool
+	SamplePercent float64       // Share of all requests captured, 0-100
+	TTL           time.Duration // How long captures are kept
+	TargetTTL     time.Duration // Default lifetime of an admin-flagged target
+	MaxBodyBytes  int           // Bytes of each body buffered and kept
+	RefreshEvery  time.Duration // How often targets flagged on other instances are picked up
+	QueueSize     int           // Captures waiting to be written before new ones are dropped
+}
+
+// DefaultConfig captures nothing until sampling is configured or an admin
+// flags a user or route
+func DefaultConfig() Config {
+	return Config{
+		Enabled:      true,
+		TTL:          72 * time.Hour,
+		TargetTTL:    24 * time.Hour,
+		MaxBodyBytes: 64 * 1024,
+		RefreshEvery: 30 * time.Second,
+		QueueSize:    1000,
+	}
+}
+
+// Exchange is one request and response as seen by the capture middleware,
+// before sanitization
+type Exchange struct {
+	Capture             entities.HTTPCapture // request metadata; bodies and headers are filled by Record
+	Header              http.Header
+	RequestBody         []byte
+	RequestContentType  string
+	RequestOverflow     bool // the request body was longer than MaxBodyBytes
+	ResponseBody        []byte
+	ResponseContentType string
+	ResponseOverflow    bool
+}
+
+// Service decides which requests to capture, sanitizes them and writes them
+// to the debug store off the request path
+type Service struct {
+	store  Store
+	config Config
+	logger *zap.Logger
+	queue  chan *entities.HTTPCapture
+
+	mu     sync.RWMutex
+	users  map[uuid.UUID]time.Time // flagged user -> target expiry
+	routes map[string]time.Time    // flagged route -> target expiry
+	sample func() float64
+}
+
+// NewService creates a capture service
+func NewService(store Store, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.TargetTTL <= 0 {
+		config.TargetTTL = defaults.TargetTTL
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaults.MaxBodyBytes
+	}
+	if config.RefreshEvery <= 0 {
+		config.RefreshEvery = defaults.RefreshEvery
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	return &Service{
+		store:  store,
+		config: config,
+		logger: logger,
+		queue:  make(chan *entities.HTTPCapture, config.QueueSize),
+		users:  make(map[uuid.UUID]time.Time),
+		routes: make(map[string]time.Time),
+		sample: rand.Float64,
+	}
+}
+
+// Start loads the active targets, then writes queued captures and refreshes
+// targets until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	s.refreshTargets(ctx)
+	go func() {
+		ticker := time.NewTicker(s.config.RefreshEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				s.logger.Info("HTTP capture writer stopped")
+				return
+			case capture := <-s.queue:
+				s.write(ctx, capture)
+			case <-ticker.C:
+				s.refreshTargets(ctx)
+			}
+		}
+	}()
+}
+
+// Active reports whether any request could currently be captured. The
+// middleware skips buffering bodies entirely when it is false.
+func (s *Service) Active() bool {
+	if !s.config.Enabled {
+		return false
+	}
+	if s.config.SamplePercent > 0 {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users) > 0 || len(s.routes) > 0
+}
+
+// MaxBodyBytes is how much of each body the middleware should buffer
+func (s *Service) MaxBodyBytes() int {
+	return s.config.MaxBodyBytes
+}
+
+// Decide reports whether a request by userID (nil when unauthenticated) to
+// route should be captured, and why. Flagged users and routes always are.
+func (s *Service) Decide(userID *uuid.UUID, route string) (entities.HTTPCaptureReason, bool) {
+	if !s.config.Enabled {
+		return "", false
+	}
+	now := time.Now()
+	s.mu.RLock()
+	var userUntil, routeUntil time.Time
+	if userID != nil {
+		userUntil = s.users[*userID]
+	}
+	if route != "" {
+		routeUntil = s.routes[route]
+	}
+	s.mu.RUnlock()
+
+	switch {
+	case now.Before(userUntil):
+		return entities.HTTPCaptureUser, true
+	case now.Before(routeUntil):
+		return entities.HTTPCaptureRoute, true
+	case s.config.SamplePercent > 0 && s.sample()*100 < s.config.SamplePercent:
+		return entities.HTTPCaptureSampled, true
+	}
+	return "", false
+}
+
+// Record sanitizes an exchange and queues it for writing. Captures are
+// dropped rather than slowing requests when the queue is full.
+func (s *Service) Record(exchange *Exchange) {
+	capture := exchange.Capture
+	capture.ID = uuid.New()
+	if capture.CreatedAt.IsZero() {
+		capture.CreatedAt = time.Now()
+	}
+	capture.ExpiresAt = capture.CreatedAt.Add(s.config.TTL)
+	capture.Query = SanitizeQuery(capture.Query)
+	capture.RequestHeaders = SanitizeHeaders(exchange.Header)
+	capture.RequestBody = SanitizeBody(exchange.RequestContentType, exchange.RequestBody, exchange.RequestOverflow)
+	capture.ResponseBody = SanitizeBody(exchange.ResponseContentType, exchange.ResponseBody, exchange.ResponseOverflow)
+	capture.Truncated = exchange.RequestOverflow || exchange.ResponseOverflow
+
+	select {
+	case s.queue <- &capture:
+	default:
+		s.logger.Debug("HTTP capture queue full, dropping capture", zap.String("route", capture.Route))
+	}
+}
+
+func (s *Service) write(ctx context.Context, capture *entities.HTTPCapture) {
+	writeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := s.store.SaveCapture(writeCtx, capture); err != nil {
+		s.logger.Warn("Failed to save HTTP capture", zap.String("route", capture.Route), zap.Error(err))
+	}
+}
+
+// GetCapture returns an unexpired capture
+func (s *Service) GetCapture(ctx context.Context, id uuid.UUID) (*entities.HTTPCapture, error) {
+	capture, err := s.store.GetCapture(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if capture == nil {
+		return nil, ErrCaptureNotFound
+	}
+	return capture, nil
+}
+
+// ListCaptures returns unexpired captures, newest first
+func (s *Service) ListCaptures(ctx context.Context, filter entities.HTTPCaptureFilter) ([]*entities.HTTPCapture, error) {
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	return s.store.ListCaptures(ctx, filter)
+}
+
+// ListTargets returns the users and routes currently flagged for capture
+func (s *Service) ListTargets(ctx context.Context) ([]*entities.HTTPCaptureTarget, error) {
+	return s.store.ListActiveTargets(ctx)
+}
+
+// AddTarget flags a user or route for capture. It takes effect on this
+// instance at once and on others at their next refresh.
+func (s *Service) AddTarget(ctx context.Context, adminID uuid.UUID, req *entities.CreateHTTPCaptureTargetRequest) (*entities.HTTPCaptureTarget, error) {
+	route := strings.TrimSpace(req.Route)
+	if req.UserID == nil && route == "" {
+		return nil, ErrInvalidTarget
+	}
+	ttl := s.config.TargetTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	now := time.Now()
+	target := &entities.HTTPCaptureTarget{
+		ID:        uuid.New(),
+		UserID:    req.UserID,
+		Route:     route,
+		Note:      strings.TrimSpace(req.Note),
+		CreatedBy: adminID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := s.store.CreateTarget(ctx, target); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.addTargetLocked(target)
+	s.mu.Unlock()
+	s.logger.Info("HTTP capture target added",
+		zap.String("target_id", target.ID.String()),
+		zap.String("route", target.Route),
+		zap.Time("expires_at", target.ExpiresAt))
+	return target, nil
+}
+
+// RemoveTarget stops capturing for a target
+func (s *Service) RemoveTarget(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.store.DeleteTarget(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTargetNotFound
+	}
+	s.refreshTargets(ctx)
+	return nil
+}
+
+func (s *Service) refreshTargets(ctx context.Context) {
+	targets, err := s.store.ListActiveTargets(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to refresh HTTP capture targets", zap.Error(err))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = make(map[uuid.UUID]time.Time, len(targets))
+	s.routes = make(map[string]time.Time, len(targets))
+	for _, target := range targets {
+		s.addTargetLocked(target)
+	}
+}
+
+func (s *Service) addTargetLocked(target *entities.HTTPCaptureTarget) {
+	if target.UserID != nil && target.ExpiresAt.After(s.users[*target.UserID]) {
+		s.users[*target.UserID] = target.ExpiresAt
+	}
+	if target.Route != "" && target.ExpiresAt.After(s.routes[target.Route]) {
+		s.routes[target.Route] = target.ExpiresAt
+	}
+}
@@ -0,0 +1,341 @@
+package inactivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrScanInProgress is returned when a scan is requested while another is running
+var ErrScanInProgress = errors.New("inactivity scan already in progress")
+
+// Repository finds dormant funded accounts and stores escalation state
+type Repository interface {
+	ListDormantFunded(ctx context.Context, cutoff time.Time, afterID uuid.UUID, limit int) ([]*entities.DormantAccount, error)
+	SaveState(ctx context.Context, state *entities.AccountInactivity) error
+}
+
+// TrustedContactLookup returns a user's trusted contact, if any
+type TrustedContactLookup interface {
+	Get(ctx context.Context, userID uuid.UUID) (*entities.TrustedContact, error)
+}
+
+// CaseOpener opens admin review cases
+type CaseOpener interface {
+	Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error)
+}
+
+// Mailer sends plain account servicing emails
+type Mailer interface {
+	SendCustomEmail(ctx context.Context, to, subject, htmlContent, textContent string) error
+}
+
+// Notifier delivers in-app notifications
+type Notifier interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// Config holds the dormancy thresholds
+type Config struct {
+	// ReminderMonths are the inactivity ages at which escalating reminders go out
+	ReminderMonths []int
+	// CaseAfterMonths is the inactivity age at which an admin case is opened,
+	// ahead of any state unclaimed property (escheatment) deadline
+	CaseAfterMonths int
+	BatchSize       int
+	Interval        time.Duration
+}
+
+// DefaultConfig returns reminders at 6, 9 and 11 months and a case at 12 months
+func DefaultConfig() Config {
+	return Config{
+		ReminderMonths:  []int{6, 9, 11},
+		CaseAfterMonths: 12,
+		BatchSize:       500,
+		Interval:        24 * time.Hour,
+	}
+}
+
+// AccountAction describes what a scan did (or would do) for one account
+type AccountAction struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	MonthsInactive int        `json:"months_inactive"`
+	ReminderStage  int        `json:"reminder_stage,omitempty"`
+	ContactedTrust bool       `json:"contacted_trusted_contact,omitempty"`
+	CaseID         *uuid.UUID `json:"case_id,omitempty"`
+	OpenedCase     bool       `json:"opened_case,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// Report summarizes a scan
+type Report struct {
+	DryRun      bool            `json:"dry_run"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt time.Time       `json:"completed_at"`
+	Scanned     int             `json:"scanned"`
+	Reminders   int             `json:"reminders"`
+	CasesOpened int             `json:"cases_opened"`
+	Actions     []AccountAction `json:"actions"`
+}
+
+// Service detects dormant funded accounts, sends escalating reminders to the
+// owner (and the trusted contact at the final stage), and opens an admin case
+// once the account passes the escalation threshold.
+type Service struct {
+	repo     Repository
+	contacts TrustedContactLookup
+	cases    CaseOpener
+	mailer   Mailer
+	notifier Notifier
+	config   Config
+	logger   *zap.Logger
+	mu       sync.Mutex
+	now      func() time.Time
+}
+
+// NewService creates a new inactivity monitor service
+func NewService(repo Repository, contacts TrustedContactLookup, cases CaseOpener, mailer Mailer, notifier Notifier, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if len(config.ReminderMonths) == 0 {
+		config.ReminderMonths = defaults.ReminderMonths
+	}
+	if config.CaseAfterMonths <= 0 {
+		config.CaseAfterMonths = defaults.CaseAfterMonths
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:     repo,
+		contacts: contacts,
+		cases:    cases,
+		mailer:   mailer,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Interval returns how often the monitor should run
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Run scans every dormant funded account once. In dry-run mode nothing is sent
+// or persisted and the report lists the actions that would be taken.
+func (s *Service) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	if !s.mu.TryLock() {
+		return nil, ErrScanInProgress
+	}
+	defer s.mu.Unlock()
+
+	now := s.now()
+	report := &Report{DryRun: dryRun, StartedAt: now, Actions: []AccountAction{}}
+	cutoff := now.AddDate(0, -s.firstThreshold(), 0)
+
+	afterID := uuid.Nil
+	for {
+		accounts, err := s.repo.ListDormantFunded(ctx, cutoff, afterID, s.config.BatchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(accounts) == 0 {
+			break
+		}
+		for _, account := range accounts {
+			afterID = account.UserID
+			report.Scanned++
+
+			action, changed := s.process(ctx, account, now, dryRun)
+			if action.ReminderStage > 0 {
+				report.Reminders++
+			}
+			if action.OpenedCase {
+				report.CasesOpened++
+			}
+			if changed || action.Error != "" {
+				report.Actions = append(report.Actions, action)
+			}
+		}
+	}
+
+	report.CompletedAt = s.now()
+	s.logger.Info("Inactivity scan completed",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("scanned", report.Scanned),
+		zap.Int("reminders", report.Reminders),
+		zap.Int("cases_opened", report.CasesOpened))
+	return report, nil
+}
+
+// process escalates one account and reports whether anything changed
+func (s *Service) process(ctx context.Context, account *entities.DormantAccount, now time.Time, dryRun bool) (AccountAction, bool) {
+	months := MonthsBetween(account.LastActivityAt, now)
+	action := AccountAction{UserID: account.UserID, MonthsInactive: months}
+
+	// Activity since the last escalation restarts the ladder
+	state := account.State
+	if state == nil || account.LastActivityAt.After(state.LastActivityAt) {
+		state = &entities.AccountInactivity{UserID: account.UserID, LastActivityAt: account.LastActivityAt}
+	}
+
+	changed := false
+	due := DueStage(months, s.config.ReminderMonths)
+	if due > state.Stage && state.Stage < len(s.config.ReminderMonths) {
+		action.ReminderStage = due
+		final := due == len(s.config.ReminderMonths)
+		if !dryRun {
+			contacted, err := s.remind(ctx, account, due, final, months)
+			if err != nil {
+				action.Error = err.Error()
+				return action, false
+			}
+			action.ContactedTrust = contacted
+			state.LastNotifiedAt = &now
+		}
+		state.Stage = due
+		changed = true
+	}
+
+	if months >= s.config.CaseAfterMonths && state.CaseID == nil {
+		if !dryRun {
+			c, err := s.cases.Open(ctx, account.UserID, entities.AdminCaseDormantAccount,
+				fmt.Sprintf("Funded account inactive for %d months", months),
+				map[string]interface{}{
+					"last_activity_at": account.LastActivityAt,
+					"months_inactive":  months,
+					"buying_power":     account.BuyingPower,
+					"reminders_sent":   state.Stage,
+				})
+			if err != nil {
+				action.Error = err.Error()
+				return action, changed
+			}
+			state.CaseID = &c.ID
+			action.CaseID = &c.ID
+		}
+		action.OpenedCase = true
+		changed = true
+	}
+
+	if changed && !dryRun {
+		if err := s.repo.SaveState(ctx, state); err != nil {
+			action.Error = err.Error()
+		}
+	}
+	return action, changed
+}
+
+// remind notifies the owner and, on the final reminder, the trusted contact
+func (s *Service) remind(ctx context.Context, account *entities.DormantAccount, stage int, final bool, months int) (bool, error) {
+	priority := entities.PriorityMedium
+	if final {
+		priority = entities.PriorityCritical
+	} else if stage > 1 {
+		priority = entities.PriorityHigh
+	}
+
+	subject := "We haven't seen you in a while"
+	body := fmt.Sprintf("Your Stack account has had no activity for %d months and still holds a balance. "+
+		"Log in to keep your account active.", months)
+	if final {
+		subject = "Action required: your Stack account is dormant"
+		body += " If your account stays inactive, we may be required by law to report and transfer " +
+			"your balance to the state as unclaimed property."
+	}
+
+	if s.mailer != nil {
+		if err := s.mailer.SendCustomEmail(ctx, account.Email, subject, "<p>"+body+"</p>", body); err != nil {
+			return false, fmt.Errorf("failed to email account owner: %w", err)
+		}
+	}
+	if s.notifier != nil {
+		notification := &entities.Notification{
+			ID:        uuid.New(),
+			UserID:    account.UserID,
+			Type:      entities.NotificationTypeSecurity,
+			Channel:   entities.ChannelInApp,
+			Priority:  priority,
+			Title:     subject,
+			Message:   body,
+			Data:      map[string]interface{}{"reminder_stage": stage, "months_inactive": months},
+			CreatedAt: s.now(),
+		}
+		if err := s.notifier.Send(ctx, notification, &entities.UserPreference{UserID: account.UserID}); err != nil {
+			s.logger.Warn("Failed to send inactivity notification", zap.Error(err), zap.String("user_id", account.UserID.String()))
+		}
+	}
+
+	if !final || s.contacts == nil || s.mailer == nil {
+		return false, nil
+	}
+	contact, err := s.contacts.Get(ctx, account.UserID)
+	if err != nil {
+		if !errors.Is(err, entities.ErrTrustedContactNotFound) {
+			s.logger.Warn("Failed to load trusted contact", zap.Error(err), zap.String("user_id", account.UserID.String()))
+		}
+		return false, nil
+	}
+	if contact.Email == nil {
+		return false, nil
+	}
+	// The trusted contact is told only that we are trying to reach the customer;
+	// no account details are disclosed.
+	contactBody := fmt.Sprintf("Hello %s, you are listed as a trusted contact for a Stack customer. "+
+		"We have been unable to reach them about their account. Please ask them to contact Stack support.",
+		contact.FirstName)
+	if err := s.mailer.SendCustomEmail(ctx, *contact.Email, "Please help us reach a Stack customer",
+		"<p>"+contactBody+"</p>", contactBody); err != nil {
+		s.logger.Warn("Failed to email trusted contact", zap.Error(err), zap.String("user_id", account.UserID.String()))
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s *Service) firstThreshold() int {
+	first := s.config.CaseAfterMonths
+	for _, m := range s.config.ReminderMonths {
+		if m < first {
+			first = m
+		}
+	}
+	return first
+}
+
+// DueStage returns how many reminder thresholds an account has passed
+func DueStage(monthsInactive int, reminderMonths []int) int {
+	due := 0
+	for i, threshold := range reminderMonths {
+		if monthsInactive >= threshold {
+			due = i + 1
+		}
+	}
+	return due
+}
+
+// MonthsBetween returns the number of whole calendar months from start to end
+func MonthsBetween(start, end time.Time) int {
+	start, end = start.UTC(), end.UTC()
+	if end.Before(start) {
+		return 0
+	}
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/adapters/alpaca"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -42,7 +43,7 @@ func NewInstantFundingService(
 func (s *InstantFundingService) FundBrokerageAccount(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
 	s.logger.Info("Initiating instant brokerage funding",
 		zap.String("user_id", userID.String()),
-		zap.String("amount", amount.String()))
+		logger.Amount("amount", amount))
 
 	virtualAccounts, err := s.virtualAccountRepo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -75,7 +76,7 @@ func (s *InstantFundingService) FundBrokerageAccount(ctx context.Context, userID
 
 	s.logger.Info("Instant funding completed",
 		zap.String("user_id", userID.String()),
-		zap.String("amount", amount.String()),
+		logger.Amount("amount", amount),
 		zap.String("journal_id", journal.ID))
 
 	return nil
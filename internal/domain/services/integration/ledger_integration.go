@@ -240,7 +240,7 @@ func (i *LedgerIntegration) ReserveForInvestment(
 	userID uuid.UUID,
 	amount decimal.Decimal,
 ) error {
-	return i.ledgerService.ReserveForInvestment(ctx, userID, amount)
+	return i.ledgerService.ReserveForInvestment(ctx, userID, entities.NewMoney(amount, entities.CurrencyUSDC))
 }
 
 // ReleaseReservation releases reserved funds
@@ -249,7 +249,7 @@ func (i *LedgerIntegration) ReleaseReservation(
 	userID uuid.UUID,
 	amount decimal.Decimal,
 ) error {
-	return i.ledgerService.ReleaseReservation(ctx, userID, amount)
+	return i.ledgerService.ReleaseReservation(ctx, userID, entities.NewMoney(amount, entities.CurrencyUSDC))
 }
 
 // ExecuteInvestment executes investment (moves from pending to fiat exposure)
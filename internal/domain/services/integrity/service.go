@@ -0,0 +1,284 @@
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// ErrRunInProgress is returned when a manual run is requested while another
+// run on this instance has not finished
+var ErrRunInProgress = errors.New("an integrity run is already in progress")
+
+// Repository finds anomalies and records runs
+type Repository interface {
+	// FindAnomalies returns up to limit anomalies of the class that have
+	// persisted since before the cutoff
+	FindAnomalies(ctx context.Context, class entities.IntegrityAnomalyClass, before time.Time, limit int) ([]*entities.IntegrityAnomaly, error)
+	// StartRun records a run. A scheduled run for a date that already has
+	// one is not recorded and false is returned.
+	StartRun(ctx context.Context, report *entities.IntegrityReport) (bool, error)
+	FinishRun(ctx context.Context, report *entities.IntegrityReport) error
+	GetRun(ctx context.Context, id uuid.UUID) (*entities.IntegrityReport, error)
+	ListRuns(ctx context.Context, limit int) ([]*entities.IntegrityReport, error)
+}
+
+// CaseOpener opens admin cases, returning the unresolved case of the same
+// type when the user already has one
+type CaseOpener interface {
+	Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error)
+}
+
+// Config controls when the checker runs and what it reports
+type Config struct {
+	RunHour     int           // UTC hour after which the nightly run starts
+	Interval    time.Duration // How often the worker checks whether the nightly run is due
+	Grace       time.Duration // How long a record may be inconsistent before it is an anomaly
+	MaxPerCheck int           // Anomalies reported, and cases opened, per class per run
+	OpenCases   bool          // Open an admin case for each user with anomalies
+}
+
+// DefaultConfig runs at 03:00 UTC and ignores records younger than a day,
+// which are usually still being processed
+func DefaultConfig() Config {
+	return Config{
+		RunHour:     3,
+		Interval:    15 * time.Minute,
+		Grace:       24 * time.Hour,
+		MaxPerCheck: 500,
+		OpenCases:   true,
+	}
+}
+
+// Service checks that records agree across aggregates, reports what it finds
+// and opens cases so each anomaly gets looked at
+type Service struct {
+	repo    Repository
+	cases   CaseOpener
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+	now     func() time.Time
+	running sync.Mutex
+}
+
+// NewService creates an integrity checker. cases may be nil, in which case
+// anomalies are only reported.
+func NewService(repo Repository, cases CaseOpener, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.RunHour < 0 || config.RunHour > 23 {
+		config.RunHour = defaults.RunHour
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Grace < 0 {
+		config.Grace = defaults.Grace
+	}
+	if config.MaxPerCheck <= 0 {
+		config.MaxPerCheck = defaults.MaxPerCheck
+	}
+	return &Service{
+		repo:   repo,
+		cases:  cases,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports runs to the worker registry, which can pause them
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often the worker checks for a due run
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Start checks for a due nightly run on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.RunDue(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Error("Integrity run failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RunDue runs today's checks once the run hour has passed. Instances claim
+// the date before running, so only one runs each night. It returns nil when
+// no run was due.
+func (s *Service) RunDue(ctx context.Context) (*entities.IntegrityReport, error) {
+	now := s.now().UTC()
+	if now.Hour() < s.config.RunHour {
+		return nil, nil
+	}
+	date := now.Format("2006-01-02")
+	report := &entities.IntegrityReport{
+		ID:        uuid.New(),
+		RunDate:   &date,
+		Trigger:   entities.IntegrityRunScheduled,
+		StartedAt: now,
+	}
+	return s.run(ctx, report)
+}
+
+// RunNow runs the checks immediately on behalf of an admin
+func (s *Service) RunNow(ctx context.Context, adminID uuid.UUID) (*entities.IntegrityReport, error) {
+	report := &entities.IntegrityReport{
+		ID:          uuid.New(),
+		Trigger:     entities.IntegrityRunManual,
+		TriggeredBy: &adminID,
+		StartedAt:   s.now().UTC(),
+	}
+	return s.run(ctx, report)
+}
+
+// Get returns a run's report
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.IntegrityReport, error) {
+	return s.repo.GetRun(ctx, id)
+}
+
+// List returns the most recent runs, without their anomaly lists
+func (s *Service) List(ctx context.Context, limit int) ([]*entities.IntegrityReport, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 30
+	}
+	reports, err := s.repo.ListRuns(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, report := range reports {
+		for _, check := range report.Checks {
+			check.Anomalies = nil
+		}
+	}
+	return reports, nil
+}
+
+func (s *Service) run(ctx context.Context, report *entities.IntegrityReport) (*entities.IntegrityReport, error) {
+	if !s.running.TryLock() {
+		if report.Trigger == entities.IntegrityRunScheduled {
+			return nil, nil
+		}
+		return nil, ErrRunInProgress
+	}
+	defer s.running.Unlock()
+
+	started, err := s.repo.StartRun(ctx, report)
+	if err != nil || !started {
+		return nil, err
+	}
+
+	before := report.StartedAt.Add(-s.config.Grace)
+	var failed []error
+	for _, class := range entities.IntegrityAnomalyClasses() {
+		check := s.check(ctx, class, before, report.StartedAt)
+		if check.Error != "" {
+			failed = append(failed, fmt.Errorf("%s: %s", class, check.Error))
+		}
+		report.Checks = append(report.Checks, check)
+		report.Anomalies += check.Count
+	}
+
+	finished := s.now().UTC()
+	report.FinishedAt = &finished
+	if runErr := errors.Join(failed...); runErr != nil {
+		message := runErr.Error()
+		report.Error = &message
+	}
+	if err := s.repo.FinishRun(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Integrity run finished",
+		zap.String("run_id", report.ID.String()),
+		zap.String("trigger", string(report.Trigger)),
+		zap.Int("anomalies", report.Anomalies),
+		zap.Duration("duration", finished.Sub(report.StartedAt)))
+	if report.Error != nil {
+		return report, errors.New(*report.Error)
+	}
+	return report, nil
+}
+
+// check runs one class's check and opens a case for each user it flags. A
+// failed check is recorded in the report and does not stop the others.
+func (s *Service) check(ctx context.Context, class entities.IntegrityAnomalyClass, before, detectedAt time.Time) *entities.IntegrityCheckResult {
+	result := &entities.IntegrityCheckResult{Class: class, Description: class.Description()}
+
+	anomalies, err := s.repo.FindAnomalies(ctx, class, before, s.config.MaxPerCheck+1)
+	if err != nil {
+		s.logger.Error("Integrity check failed", zap.String("class", string(class)), zap.Error(err))
+		result.Error = err.Error()
+		return result
+	}
+	if len(anomalies) > s.config.MaxPerCheck {
+		anomalies = anomalies[:s.config.MaxPerCheck]
+		result.Truncated = true
+	}
+	result.Anomalies = anomalies
+	result.Count = len(anomalies)
+	metrics.SetIntegrityAnomalies(string(class), result.Count)
+
+	if s.cases == nil || !s.config.OpenCases {
+		return result
+	}
+
+	// One case per user and class, listing every record of theirs that failed
+	var users []uuid.UUID
+	subjects := map[uuid.UUID][]string{}
+	for _, anomaly := range anomalies {
+		if _, seen := subjects[anomaly.UserID]; !seen {
+			users = append(users, anomaly.UserID)
+		}
+		subjects[anomaly.UserID] = append(subjects[anomaly.UserID], anomaly.SubjectID)
+	}
+	for _, userID := range users {
+		_, err := s.cases.Open(ctx, userID, class.CaseType(), class.Description(), map[string]interface{}{
+			"class":       string(class),
+			"subjects":    subjects[userID],
+			"detected_at": detectedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			s.logger.Warn("Failed to open integrity case",
+				zap.String("class", string(class)),
+				zap.String("user_id", userID.String()),
+				zap.Error(err))
+			continue
+		}
+		result.Cases++
+	}
+	return result
+}
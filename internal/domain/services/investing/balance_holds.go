@@ -0,0 +1,71 @@
+package investing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// BalanceHolds reserves buying power for buy orders until they settle
+type BalanceHolds interface {
+	Place(ctx context.Context, userID uuid.UUID, kind entities.HoldKind, referenceID uuid.UUID, amount decimal.Decimal) (*entities.BalanceHold, error)
+	Capture(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error)
+	Release(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error)
+}
+
+// SetBalanceHolds reserves buy orders' buying power with holds that are
+// captured on fill and released when the order fails, is canceled or expires.
+// Without holds buying power is deducted and refunded directly.
+func (s *Service) SetBalanceHolds(holds BalanceHolds) {
+	s.holds = holds
+}
+
+// reserveBuyingPower takes a buy order's amount out of buying power
+func (s *Service) reserveBuyingPower(ctx context.Context, order *entities.Order) error {
+	if s.holds == nil {
+		if err := s.balanceRepo.DeductBuyingPower(ctx, order.UserID, order.Amount); err != nil {
+			return fmt.Errorf("failed to reserve buying power: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := s.holds.Place(ctx, order.UserID, entities.HoldKindOrder, order.ID, order.Amount); err != nil {
+		if errors.Is(err, entities.ErrInsufficientBuyingPower) {
+			return ErrInsufficientFunds
+		}
+		return fmt.Errorf("failed to hold buying power: %w", err)
+	}
+	return nil
+}
+
+// releaseBuyingPower returns a buy order's reserved amount to buying power
+func (s *Service) releaseBuyingPower(ctx context.Context, order *entities.Order) {
+	if s.holds != nil {
+		_, err := s.holds.Release(ctx, entities.HoldKindOrder, order.ID)
+		if err == nil {
+			return
+		}
+		// Orders placed before holds were enabled were deducted directly
+		if !errors.Is(err, entities.ErrBalanceHoldNotFound) {
+			s.logger.Error("Failed to release buying power hold", "order_id", order.ID, "error", err)
+			return
+		}
+	}
+	if err := s.balanceRepo.AddBuyingPower(ctx, order.UserID, order.Amount); err != nil {
+		s.logger.Error("Failed to refund buying power", "order_id", order.ID, "error", err)
+	}
+}
+
+// captureBuyingPower settles a filled buy order's hold as spent
+func (s *Service) captureBuyingPower(ctx context.Context, order *entities.Order) {
+	if s.holds == nil {
+		return
+	}
+	if _, err := s.holds.Capture(ctx, entities.HoldKindOrder, order.ID); err != nil && !errors.Is(err, entities.ErrBalanceHoldNotFound) {
+		s.logger.Error("Failed to capture buying power hold", "order_id", order.ID, "error", err)
+	}
+}
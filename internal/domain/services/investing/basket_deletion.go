@@ -0,0 +1,82 @@
+package investing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// BasketDeletionRepository looks up and removes baskets
+type BasketDeletionRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
+	// CountReferences counts the orders and open positions that use a basket
+	CountReferences(ctx context.Context, id uuid.UUID) (int, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// BasketDeletion removes curated baskets once an approval request for the
+// deletion is approved. Baskets with any orders or open positions are kept,
+// since deleting them would cascade to users' order history and holdings.
+type BasketDeletion struct {
+	baskets BasketDeletionRepository
+	logger  *logger.Logger
+}
+
+// NewBasketDeletion creates the basket deletion handler
+func NewBasketDeletion(baskets BasketDeletionRepository, logger *logger.Logger) *BasketDeletion {
+	return &BasketDeletion{
+		baskets: baskets,
+		logger:  logger,
+	}
+}
+
+// Check returns the basket if it exists and can be deleted
+func (d *BasketDeletion) Check(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error) {
+	basket, err := d.baskets.GetByID(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+
+	references, err := d.baskets.CountReferences(ctx, basketID)
+	if err != nil {
+		return nil, err
+	}
+	if references > 0 {
+		return nil, ErrBasketInUse
+	}
+	return basket, nil
+}
+
+// Approved deletes the basket, re-checking it is unused since it may have
+// been ordered while the request was pending
+func (d *BasketDeletion) Approved(ctx context.Context, request *entities.ApprovalRequest) error {
+	basketID, err := uuid.Parse(request.ResourceID)
+	if err != nil {
+		return fmt.Errorf("invalid basket id %q: %w", request.ResourceID, err)
+	}
+	if _, err := d.Check(ctx, basketID); err != nil {
+		return err
+	}
+
+	deleted, err := d.baskets.Delete(ctx, basketID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBasketNotFound
+	}
+
+	d.logger.Info("Basket deleted", "basket_id", basketID, "approval_request_id", request.ID)
+	return nil
+}
+
+// Rejected leaves the basket in place
+func (d *BasketDeletion) Rejected(ctx context.Context, request *entities.ApprovalRequest) error {
+	return nil
+}
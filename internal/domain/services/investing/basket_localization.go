@@ -0,0 +1,262 @@
+package investing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// UserCountryResolver looks up the country a user onboarded from
+type UserCountryResolver interface {
+	UserCountry(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// SetRegionResolver limits the baskets a user can see and order to those
+// offered in their country
+func (s *Service) SetRegionResolver(regions UserCountryResolver) {
+	s.regions = regions
+}
+
+// ListBasketsForUser returns the baskets offered in the user's country
+func (s *Service) ListBasketsForUser(ctx context.Context, userID uuid.UUID) ([]*entities.Basket, error) {
+	baskets, err := s.ListBaskets(ctx)
+	if err != nil || s.regions == nil {
+		return baskets, err
+	}
+	country, err := s.regions.UserCountry(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user country: %w", err)
+	}
+
+	available := make([]*entities.Basket, 0, len(baskets))
+	for _, basket := range baskets {
+		if basket.AvailableIn(country) {
+			available = append(available, basket)
+		}
+	}
+	return available, nil
+}
+
+// GetBasketForUser returns a basket if it is offered in the user's country.
+// Baskets for other regions are reported as not found.
+func (s *Service) GetBasketForUser(ctx context.Context, userID, basketID uuid.UUID) (*entities.Basket, error) {
+	var (
+		basket *entities.Basket
+		err    error
+	)
+	if s.basketCache != nil {
+		basket, err = s.basketCache.Basket(ctx, basketID)
+		if err != nil {
+			err = fmt.Errorf("failed to get basket: %w", err)
+		}
+	} else {
+		basket, err = s.GetBasket(ctx, basketID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+	if err := s.checkBasketRegion(ctx, userID, basket); err != nil {
+		return nil, err
+	}
+	return basket, nil
+}
+
+// checkBasketRegion returns ErrBasketNotFound when the basket is not offered
+// in the user's country, so regional variants stay invisible elsewhere
+func (s *Service) checkBasketRegion(ctx context.Context, userID uuid.UUID, basket *entities.Basket) error {
+	if s.regions == nil || len(basket.Regions) == 0 {
+		return nil
+	}
+	country, err := s.regions.UserCountry(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user country: %w", err)
+	}
+	if !basket.AvailableIn(country) {
+		return ErrBasketNotFound
+	}
+	return nil
+}
+
+// BasketLocalizationRepository stores curated baskets and their regional variants
+type BasketLocalizationRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
+	Create(ctx context.Context, basket *entities.Basket) error
+	// UpdateRegions returns false if the basket does not exist
+	UpdateRegions(ctx context.Context, id uuid.UUID, regions []string) (bool, error)
+	// GetFamilyStats returns activity per basket in the family and the number
+	// of distinct users holding any of them
+	GetFamilyStats(ctx context.Context, parentID uuid.UUID) ([]entities.BasketVariantStats, int64, error)
+}
+
+// BasketLocalization lets admins clone a curated basket for a region, swapping
+// holdings that are not available there, and tag baskets with the regions
+// they are offered in. Clones link to the original basket so its analytics
+// cover every variant.
+type BasketLocalization struct {
+	baskets BasketLocalizationRepository
+	logger  *logger.Logger
+}
+
+// NewBasketLocalization creates the basket localization service
+func NewBasketLocalization(baskets BasketLocalizationRepository, logger *logger.Logger) *BasketLocalization {
+	return &BasketLocalization{
+		baskets: baskets,
+		logger:  logger,
+	}
+}
+
+// Clone creates a regional variant of a basket. Cloning a variant links the
+// new basket to the original, keeping each family one level deep.
+func (l *BasketLocalization) Clone(ctx context.Context, sourceID uuid.UUID, req *entities.CloneBasketRequest) (*entities.Basket, error) {
+	source, err := l.baskets.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if source == nil {
+		return nil, ErrBasketNotFound
+	}
+
+	regions, err := entities.NormalizeRegions(req.Regions)
+	if err != nil {
+		return nil, err
+	}
+	composition := req.Composition
+	if len(composition) == 0 {
+		composition, err = replaceSymbols(source.Composition, req.Replacements)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := range composition {
+		composition[i].Symbol = strings.ToUpper(strings.TrimSpace(composition[i].Symbol))
+	}
+	if err := entities.ValidateComposition(composition); err != nil {
+		return nil, err
+	}
+
+	parentID := source.FamilyID()
+	now := time.Now()
+	clone := &entities.Basket{
+		ID:             uuid.New(),
+		Name:           strings.TrimSpace(req.Name),
+		Description:    source.Description,
+		RiskLevel:      source.RiskLevel,
+		Composition:    composition,
+		ParentBasketID: &parentID,
+		Regions:        regions,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if req.Description != nil {
+		clone.Description = *req.Description
+	}
+	if req.RiskLevel != nil {
+		clone.RiskLevel = *req.RiskLevel
+	}
+
+	if err := l.baskets.Create(ctx, clone); err != nil {
+		return nil, err
+	}
+	l.logger.Info("Basket cloned", "basket_id", clone.ID, "source_basket_id", source.ID, "parent_basket_id", parentID, "regions", regions)
+	return clone, nil
+}
+
+// SetRegions replaces the regions a basket is offered in; an empty list
+// offers it everywhere
+func (l *BasketLocalization) SetRegions(ctx context.Context, basketID uuid.UUID, regions []string) (*entities.Basket, error) {
+	normalized, err := entities.NormalizeRegions(regions)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := l.baskets.UpdateRegions(ctx, basketID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrBasketNotFound
+	}
+	basket, err := l.baskets.GetByID(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+	return basket, nil
+}
+
+// FamilyAnalytics reports orders and holders across a basket's family: the
+// original basket and all of its regional variants
+func (l *BasketLocalization) FamilyAnalytics(ctx context.Context, basketID uuid.UUID) (*entities.BasketFamilyAnalytics, error) {
+	basket, err := l.baskets.GetByID(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+
+	parentID := basket.FamilyID()
+	variants, holders, err := l.baskets.GetFamilyStats(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	analytics := &entities.BasketFamilyAnalytics{
+		ParentBasketID: parentID,
+		AmountOrdered:  decimal.Zero,
+		Holders:        holders,
+		Variants:       variants,
+		GeneratedAt:    time.Now(),
+	}
+	for _, variant := range variants {
+		analytics.Orders += variant.Orders
+		analytics.AmountOrdered = analytics.AmountOrdered.Add(variant.AmountOrdered)
+	}
+	return analytics, nil
+}
+
+// replaceSymbols applies replacements to a copy of a composition. A symbol
+// replaced by one already in the basket merges their weights.
+func replaceSymbols(components []entities.BasketComponent, replacements []entities.SymbolReplacement) ([]entities.BasketComponent, error) {
+	swap := make(map[string]string, len(replacements))
+	for _, r := range replacements {
+		from := strings.ToUpper(strings.TrimSpace(r.From))
+		to := strings.ToUpper(strings.TrimSpace(r.To))
+		if from == "" || to == "" {
+			return nil, entities.ErrInvalidComposition
+		}
+		swap[from] = to
+	}
+
+	out := make([]entities.BasketComponent, 0, len(components))
+	index := make(map[string]int, len(components))
+	used := make(map[string]bool, len(swap))
+	for _, component := range components {
+		symbol := component.Symbol
+		if to, ok := swap[symbol]; ok {
+			used[symbol] = true
+			symbol = to
+		}
+		if i, ok := index[symbol]; ok {
+			out[i].Weight = out[i].Weight.Add(component.Weight)
+			continue
+		}
+		index[symbol] = len(out)
+		out = append(out, entities.BasketComponent{Symbol: symbol, Weight: component.Weight})
+	}
+	for from := range swap {
+		if !used[from] {
+			return nil, fmt.Errorf("%w: %s is not in the basket", entities.ErrInvalidComposition, from)
+		}
+	}
+	return out, nil
+}
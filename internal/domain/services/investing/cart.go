@@ -0,0 +1,357 @@
+package investing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// maxCartItems caps how many baskets one checkout places
+const maxCartItems = 20
+
+// CartRepository stores the basket buys a user has queued for checkout
+type CartRepository interface {
+	ListItems(ctx context.Context, userID uuid.UUID) ([]*entities.CartItem, error)
+	UpsertItem(ctx context.Context, userID, basketID uuid.UUID, amount decimal.Decimal) error
+	// RemoveItem deletes a basket from the cart, returning false if it was
+	// not there
+	RemoveItem(ctx context.Context, userID, basketID uuid.UUID) (bool, error)
+}
+
+// PasscodeSessions validates the short-lived tokens issued on passcode
+// verification
+type PasscodeSessions interface {
+	ValidateSession(ctx context.Context, userID uuid.UUID, token string) (bool, error)
+	InvalidateSession(ctx context.Context, userID uuid.UUID, token string) error
+}
+
+// TwoFactorVerifier checks a TOTP or backup code
+type TwoFactorVerifier interface {
+	Verify(ctx context.Context, userID uuid.UUID, code string) (bool, error)
+}
+
+// SetCart enables the investing cart
+func (s *Service) SetCart(cart CartRepository) {
+	s.cart = cart
+}
+
+// SetStepUp sets how cart checkouts are re-authenticated: with a passcode
+// session or a 2FA code. Either may be nil.
+func (s *Service) SetStepUp(passcodes PasscodeSessions, twoFactor TwoFactorVerifier) {
+	s.passcodes = passcodes
+	s.twoFactor = twoFactor
+}
+
+// GetCart returns the user's queued basket buys with their total
+func (s *Service) GetCart(ctx context.Context, userID uuid.UUID) (*entities.Cart, error) {
+	items, err := s.cartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := decimal.Zero
+	for _, item := range items {
+		if basket, err := s.basketRepo.GetByID(ctx, item.BasketID); err == nil && basket != nil {
+			item.BasketName = basket.Name
+		}
+		total = total.Add(item.Amount)
+	}
+	return &entities.Cart{Items: items, Total: total.StringFixed(2)}, nil
+}
+
+// AddToCart queues a buy of amount in a basket, replacing the amount if the
+// basket is already in the cart
+func (s *Service) AddToCart(ctx context.Context, userID, basketID uuid.UUID, amount string) (*entities.Cart, error) {
+	if s.cart == nil {
+		return nil, ErrCartUnavailable
+	}
+	basket, err := s.basketRepo.GetByID(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+
+	money, err := entities.ParseMoney(amount, entities.CurrencyUSD)
+	if err != nil || !money.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	items, err := s.cartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) >= maxCartItems && !cartHas(items, basketID) {
+		return nil, entities.ErrCartFull
+	}
+
+	if err := s.cart.UpsertItem(ctx, userID, basketID, money.Amount.Round(2)); err != nil {
+		return nil, fmt.Errorf("failed to add basket to cart: %w", err)
+	}
+	return s.GetCart(ctx, userID)
+}
+
+// RemoveFromCart drops a basket from the cart
+func (s *Service) RemoveFromCart(ctx context.Context, userID, basketID uuid.UUID) (*entities.Cart, error) {
+	if s.cart == nil {
+		return nil, entities.ErrCartItemNotFound
+	}
+	removed, err := s.cart.RemoveItem(ctx, userID, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove basket from cart: %w", err)
+	}
+	if !removed {
+		return nil, entities.ErrCartItemNotFound
+	}
+	return s.GetCart(ctx, userID)
+}
+
+// PreviewCart previews every item in the cart and totals them. Items are
+// previewed one by one, so the combined cost is then checked against buying
+// power and the spending limit as a whole.
+func (s *Service) PreviewCart(ctx context.Context, userID uuid.UUID) (*entities.CartPreview, error) {
+	items, err := s.cartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, entities.ErrCartEmpty
+	}
+
+	balance, err := s.balanceRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user balance: %w", err)
+	}
+
+	preview := &entities.CartPreview{
+		Items:       make([]entities.CartItemPreview, 0, len(items)),
+		BuyingPower: balance.BuyingPower.StringFixed(2),
+		Ready:       true,
+		Paper:       s.paper,
+	}
+	total, fees, net := decimal.Zero, decimal.Zero, decimal.Zero
+	for _, item := range items {
+		total = total.Add(item.Amount)
+		line := entities.CartItemPreview{BasketID: item.BasketID, Amount: item.Amount.StringFixed(2)}
+
+		order, err := s.PreviewOrder(ctx, userID, &entities.OrderCreateRequest{
+			BasketID: item.BasketID,
+			Side:     entities.OrderSideBuy,
+			Amount:   item.Amount.String(),
+		})
+		if err != nil {
+			line.Error = cartItemError(err)
+			preview.Ready = false
+		} else {
+			line.Preview = order
+			fees = fees.Add(decimal.RequireFromString(order.Fees.Total))
+			net = net.Add(decimal.RequireFromString(order.NetAmount))
+		}
+		preview.Items = append(preview.Items, line)
+	}
+
+	preview.TotalAmount = total.StringFixed(2)
+	preview.TotalFees = fees.StringFixed(2)
+	preview.NetAmount = net.StringFixed(2)
+	preview.BuyingPowerAfter = balance.BuyingPower.Sub(total).StringFixed(2)
+	if err := s.checkCartSpend(ctx, userID, total, balance.BuyingPower); err != nil {
+		preview.Ready = false
+		preview.Error = cartItemError(err)
+	}
+	return preview, nil
+}
+
+// CheckoutCart places every item in the cart as a market buy after a single
+// passcode or 2FA step-up. The cart is validated as a whole first: if any
+// basket is gone or the total exceeds buying power or the spending limit,
+// nothing is placed. Items are then placed one by one; one the brokerage or a
+// competing order rejects is reported as failed and kept in the cart, without
+// affecting the others.
+func (s *Service) CheckoutCart(ctx context.Context, userID uuid.UUID, req *entities.CartCheckoutRequest) (*entities.CartCheckoutResult, error) {
+	items, err := s.cartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, entities.ErrCartEmpty
+	}
+
+	if err := s.verifyStepUp(ctx, userID, req); err != nil {
+		return nil, err
+	}
+
+	result := &entities.CartCheckoutResult{Items: make([]entities.CartItemResult, len(items))}
+	total := decimal.Zero
+	invalid := false
+	for i, item := range items {
+		total = total.Add(item.Amount)
+		result.Items[i] = entities.CartItemResult{BasketID: item.BasketID, Amount: item.Amount.StringFixed(2), Status: entities.CartItemFailed}
+		basket, err := s.basketRepo.GetByID(ctx, item.BasketID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get basket: %w", err)
+		}
+		if basket == nil {
+			result.Items[i].Error = cartItemError(ErrBasketNotFound)
+			invalid = true
+		}
+	}
+	if invalid {
+		for i := range result.Items {
+			if result.Items[i].Error == nil {
+				result.Items[i].Error = &entities.CartItemError{
+					Code:    "NOT_PLACED",
+					Message: "Not placed because another item in the cart is invalid",
+				}
+			}
+		}
+		result.Status = entities.CartCheckoutFailed
+		result.TotalPlaced = decimal.Zero.StringFixed(2)
+		return result, nil
+	}
+
+	balance, err := s.balanceRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user balance: %w", err)
+	}
+	if err := s.checkCartSpend(ctx, userID, total, balance.BuyingPower); err != nil {
+		return nil, err
+	}
+
+	placed := decimal.Zero
+	for i, item := range items {
+		line := &result.Items[i]
+
+		// Taking the item out of the cart first claims it, so a concurrent
+		// checkout of the same cart cannot place it a second time
+		claimed, err := s.cart.RemoveItem(ctx, userID, item.BasketID)
+		if err != nil || !claimed {
+			line.Error = &entities.CartItemError{Code: "NOT_PLACED", Message: "Item was already checked out or removed"}
+			continue
+		}
+
+		order, err := s.CreateOrder(ctx, userID, &entities.OrderCreateRequest{
+			BasketID: item.BasketID,
+			Side:     entities.OrderSideBuy,
+			Amount:   item.Amount.String(),
+		})
+		if err != nil {
+			s.logger.Warn("Cart item not placed", "user_id", userID, "basket_id", item.BasketID, "error", err)
+			line.Error = cartItemError(err)
+			if err := s.cart.UpsertItem(ctx, userID, item.BasketID, item.Amount); err != nil {
+				s.logger.Error("Failed to return unplaced item to cart", "user_id", userID, "basket_id", item.BasketID, "error", err)
+			}
+			continue
+		}
+
+		line.Status = entities.CartItemPlaced
+		line.Order = order
+		placed = placed.Add(item.Amount)
+	}
+
+	result.TotalPlaced = placed.StringFixed(2)
+	switch {
+	case placed.Equal(total):
+		result.Status = entities.CartCheckoutCompleted
+	case placed.IsZero():
+		result.Status = entities.CartCheckoutFailed
+	default:
+		result.Status = entities.CartCheckoutPartial
+	}
+	s.logger.Info("Cart checked out", "user_id", userID, "items", len(items), "status", result.Status, "total_placed", result.TotalPlaced)
+	return result, nil
+}
+
+// cartItems lists the cart, treating a service without one as empty
+func (s *Service) cartItems(ctx context.Context, userID uuid.UUID) ([]*entities.CartItem, error) {
+	if s.cart == nil {
+		return []*entities.CartItem{}, nil
+	}
+	items, err := s.cart.ListItems(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if items == nil {
+		items = []*entities.CartItem{}
+	}
+	return items, nil
+}
+
+// checkCartSpend applies the buy checks CreateOrder makes per order to the
+// cart's total
+func (s *Service) checkCartSpend(ctx context.Context, userID uuid.UUID, total, buyingPower decimal.Decimal) error {
+	if s.allocationService != nil {
+		canSpend, err := s.allocationService.CanSpend(ctx, userID, total)
+		if err != nil {
+			return fmt.Errorf("failed to check spending limit: %w", err)
+		}
+		if !canSpend {
+			return entities.ErrSpendingLimitReached
+		}
+	}
+	if buyingPower.LessThan(total) {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// verifyStepUp checks the checkout's passcode session, which is then spent,
+// or its 2FA code
+func (s *Service) verifyStepUp(ctx context.Context, userID uuid.UUID, req *entities.CartCheckoutRequest) error {
+	switch {
+	case req.PasscodeSessionToken != "" && s.passcodes != nil:
+		valid, err := s.passcodes.ValidateSession(ctx, userID, req.PasscodeSessionToken)
+		if err != nil {
+			return fmt.Errorf("failed to verify passcode session: %w", err)
+		}
+		if !valid {
+			return entities.ErrStepUpFailed
+		}
+		if err := s.passcodes.InvalidateSession(ctx, userID, req.PasscodeSessionToken); err != nil {
+			s.logger.Warn("Failed to spend passcode session", "user_id", userID, "error", err)
+		}
+		return nil
+	case req.TwoFactorCode != "" && s.twoFactor != nil:
+		valid, err := s.twoFactor.Verify(ctx, userID, req.TwoFactorCode)
+		if err != nil {
+			return fmt.Errorf("%w: %v", entities.ErrStepUpFailed, err)
+		}
+		if !valid {
+			return entities.ErrStepUpFailed
+		}
+		return nil
+	}
+	return entities.ErrStepUpRequired
+}
+
+// cartItemError describes why an order cannot be or was not placed, with the
+// codes the single-order endpoints use
+func cartItemError(err error) *entities.CartItemError {
+	switch {
+	case errors.Is(err, ErrBasketNotFound):
+		return &entities.CartItemError{Code: "BASKET_NOT_FOUND", Message: "Basket no longer exists"}
+	case errors.Is(err, ErrInvalidAmount):
+		return &entities.CartItemError{Code: "INVALID_AMOUNT", Message: "Invalid order amount"}
+	case errors.Is(err, ErrInsufficientFunds):
+		return &entities.CartItemError{Code: "INSUFFICIENT_FUNDS", Message: "Insufficient buying power for this order"}
+	case errors.Is(err, entities.ErrSpendingLimitReached):
+		return &entities.CartItemError{Code: "SPENDING_LIMIT_REACHED", Message: err.Error()}
+	case errors.Is(err, ErrQuotesUnavailable):
+		return &entities.CartItemError{Code: "QUOTES_UNAVAILABLE", Message: "Live prices are unavailable, try again shortly"}
+	default:
+		return &entities.CartItemError{Code: "ORDER_FAILED", Message: "Order could not be placed"}
+	}
+}
+
+func cartHas(items []*entities.CartItem, basketID uuid.UUID) bool {
+	for _, item := range items {
+		if item.BasketID == basketID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package investing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// LimitOrderConfig controls resting basket limit orders
+type LimitOrderConfig struct {
+	Interval  time.Duration // How often open orders are checked against quotes
+	BatchSize int           // Open orders checked per pass
+	MaxGTC    time.Duration // How long a good-till-cancelled order rests before expiring
+}
+
+// DefaultLimitOrderConfig checks every 30 seconds and keeps GTC orders for 90 days
+func DefaultLimitOrderConfig() LimitOrderConfig {
+	return LimitOrderConfig{
+		Interval:  30 * time.Second,
+		BatchSize: 500,
+		MaxGTC:    90 * 24 * time.Hour,
+	}
+}
+
+// LimitOrderReport summarizes a limit order monitoring pass
+type LimitOrderReport struct {
+	Checked   int `json:"checked"`
+	Triggered int `json:"triggered"`
+	Expired   int `json:"expired"`
+	Failed    int `json:"failed"`
+}
+
+// SetLimitOrderConfig overrides the limit order defaults; zero fields keep them
+func (s *Service) SetLimitOrderConfig(config LimitOrderConfig) {
+	defaults := DefaultLimitOrderConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxGTC <= 0 {
+		config.MaxGTC = defaults.MaxGTC
+	}
+	s.limitOrders = config
+}
+
+// SetLimitOrderTracker reports limit order monitoring passes to the worker
+// status registry
+func (s *Service) SetLimitOrderTracker(tracker *workerstatus.Tracker) {
+	s.limitTracker = tracker
+}
+
+// LimitOrderInterval returns how often open limit orders are checked
+func (s *Service) LimitOrderInterval() time.Duration {
+	return s.limitOrders.Interval
+}
+
+// SyntheticBasketPrice prices a basket as the sum of each component's weight
+// times its quote
+func SyntheticBasketPrice(basket *entities.Basket, quotes map[string]Quote) (decimal.Decimal, error) {
+	price := decimal.Zero
+	for _, component := range basket.Composition {
+		quote, ok := quotes[component.Symbol]
+		if !ok || !quote.Price.IsPositive() {
+			return decimal.Zero, fmt.Errorf("%w: no price for %s", ErrQuotesUnavailable, component.Symbol)
+		}
+		price = price.Add(component.Weight.Mul(quote.Price))
+	}
+	return price, nil
+}
+
+// applyLimitTerms turns a new order into a resting limit order
+func (s *Service) applyLimitTerms(ctx context.Context, order *entities.Order, req *entities.OrderCreateRequest, now time.Time) error {
+	if req.LimitPrice == nil {
+		return ErrInvalidLimitPrice
+	}
+	limitPrice, err := decimal.NewFromString(*req.LimitPrice)
+	if err != nil || !limitPrice.IsPositive() {
+		return ErrInvalidLimitPrice
+	}
+
+	timeInForce := req.TimeInForce
+	if timeInForce == "" {
+		timeInForce = entities.TimeInForceDay
+	}
+	var expiresAt time.Time
+	switch timeInForce {
+	case entities.TimeInForceDay:
+		expiresAt = s.sessionClose(ctx, now)
+	case entities.TimeInForceGTC:
+		expiresAt = now.Add(s.limitOrders.MaxGTC)
+	default:
+		return ErrInvalidTimeInForce
+	}
+
+	order.Type = entities.OrderTypeLimit
+	order.LimitPrice = &limitPrice
+	order.TimeInForce = timeInForce
+	order.ExpiresAt = &expiresAt
+	order.Status = entities.OrderStatusOpen
+	return nil
+}
+
+// sessionClose returns the close of the first session an order placed at now
+// can execute in
+func (s *Service) sessionClose(ctx context.Context, now time.Time) time.Time {
+	if session := s.nextSession(ctx, now); session != nil {
+		return session.CloseAt
+	}
+	open := ExecutionWindowAt(now).EarliestAt.In(marketTimezone)
+	return time.Date(open.Year(), open.Month(), open.Day(), 16, 0, 0, 0, marketTimezone).UTC()
+}
+
+// limitReached reports whether a basket price satisfies an order's limit
+func limitReached(order *entities.Order, price decimal.Decimal) bool {
+	if order.Side == entities.OrderSideSell {
+		return price.GreaterThanOrEqual(*order.LimitPrice)
+	}
+	return price.LessThanOrEqual(*order.LimitPrice)
+}
+
+// StartLimitOrderMonitor checks open limit orders against live quotes until
+// ctx is cancelled
+func (s *Service) StartLimitOrderMonitor(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.limitOrders.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.limitTracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.RunLimitOrders(ctx, time.Now())
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Limit order pass failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// RunLimitOrders expires open orders past their expiry and, while the market
+// is open, submits those whose basket price has crossed the limit
+func (s *Service) RunLimitOrders(ctx context.Context, now time.Time) (*LimitOrderReport, error) {
+	orders, err := s.orderRepo.ListOpenLimitOrders(ctx, s.limitOrders.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open limit orders: %w", err)
+	}
+
+	report := &LimitOrderReport{}
+	marketOpen := s.executionWindow(ctx, now).MarketOpen
+	type priceKey struct {
+		basketID uuid.UUID
+		side     entities.OrderSide
+	}
+	prices := make(map[priceKey]decimal.Decimal)
+
+	for _, order := range orders {
+		report.Checked++
+
+		if order.ExpiresAt != nil && !now.Before(*order.ExpiresAt) {
+			if _, err := s.closeOpenOrder(ctx, order, entities.OrderStatusExpired); err != nil {
+				s.logger.Error("Failed to expire limit order", "order_id", order.ID, "error", err)
+				report.Failed++
+				continue
+			}
+			report.Expired++
+			continue
+		}
+		if !marketOpen || s.quotes == nil || order.LimitPrice == nil {
+			continue
+		}
+
+		key := priceKey{basketID: order.BasketID, side: order.Side}
+		price, ok := prices[key]
+		if !ok {
+			price, err = s.basketPrice(ctx, order.BasketID, order.Side)
+			if err != nil {
+				s.logger.Warn("Failed to price basket for limit order", "order_id", order.ID, "basket_id", order.BasketID, "error", err)
+				report.Failed++
+				continue
+			}
+			prices[key] = price
+		}
+		if !limitReached(order, price) {
+			continue
+		}
+
+		triggered, err := s.orderRepo.TriggerLimitOrder(ctx, order.ID)
+		if err != nil {
+			s.logger.Error("Failed to trigger limit order", "order_id", order.ID, "error", err)
+			report.Failed++
+			continue
+		}
+		if !triggered {
+			continue // canceled while this pass was running
+		}
+		order.Status = entities.OrderStatusAccepted
+		order.TriggeredAt = &now
+		s.logger.Info("Limit order triggered", "order_id", order.ID, "basket_price", price, "limit_price", order.LimitPrice)
+		s.publishOrderProgress(ctx, order, order.Status, nil)
+		s.submitToBrokerage(ctx, order)
+		report.Triggered++
+	}
+
+	if report.Triggered > 0 || report.Expired > 0 {
+		s.logger.Info("Limit order pass complete",
+			"checked", report.Checked,
+			"triggered", report.Triggered,
+			"expired", report.Expired,
+			"failed", report.Failed)
+	}
+	return report, nil
+}
+
+// CancelOrder cancels one of the user's open limit orders and releases the
+// buying power it reserved
+func (s *Service) CancelOrder(ctx context.Context, userID, orderID uuid.UUID) (*entities.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil || order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	if order.Status != entities.OrderStatusOpen {
+		return nil, ErrOrderNotCancelable
+	}
+
+	closed, err := s.closeOpenOrder(ctx, order, entities.OrderStatusCanceled)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, ErrOrderNotCancelable
+	}
+	s.logger.Info("Limit order canceled", "order_id", order.ID, "user_id", userID)
+	return order, nil
+}
+
+// closeOpenOrder cancels or expires an open order, refunding a buy's reserved
+// buying power. It returns false if the order had already left the open state.
+func (s *Service) closeOpenOrder(ctx context.Context, order *entities.Order, status entities.OrderStatus) (bool, error) {
+	closed, err := s.orderRepo.CloseOpenOrder(ctx, order.ID, status)
+	if err != nil || !closed {
+		return false, err
+	}
+	if order.Side == entities.OrderSideBuy {
+		s.releaseBuyingPower(ctx, order)
+	}
+	order.Status = status
+	s.publishOrderProgress(ctx, order, status, nil)
+	return true, nil
+}
+
+func (s *Service) basketPrice(ctx context.Context, basketID uuid.UUID, side entities.OrderSide) (decimal.Decimal, error) {
+	basket, err := s.basketRepo.GetByID(ctx, basketID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return decimal.Zero, ErrBasketNotFound
+	}
+	symbols := make([]string, 0, len(basket.Composition))
+	for _, component := range basket.Composition {
+		symbols = append(symbols, component.Symbol)
+	}
+	quotes, err := s.quotes.GetQuotes(ctx, symbols, side)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%w: %v", ErrQuotesUnavailable, err)
+	}
+	return SyntheticBasketPrice(basket, quotes)
+}
@@ -0,0 +1,115 @@
+package investing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// NewPaperService creates an investing service for paper trading. It runs the
+// same order flow as the live service over the paper repositories, but fills
+// orders through a PaperBrokerage at live quotes instead of the brokerage, and
+// leaves out real wallet balances and allocation limits.
+func NewPaperService(
+	basketRepo BasketRepository,
+	orderRepo OrderRepository,
+	positionRepo PositionRepository,
+	balanceRepo BalanceRepository,
+	quotes QuoteProvider,
+	logger *logger.Logger,
+) *Service {
+	s := NewService(basketRepo, orderRepo, positionRepo, balanceRepo, NewPaperBrokerage(basketRepo, quotes),
+		nil, nil, nil, nil, logger)
+	s.quotes = quotes
+	s.paper = true
+	return s
+}
+
+// Paper reports whether the service trades simulated balances
+func (s *Service) Paper() bool {
+	return s.paper
+}
+
+// PaperBrokerage is a simulated brokerage that fills basket orders in full at
+// the current synthetic basket price: the ask for buys and the bid for sells.
+// Outside market hours it fills at the last available quote.
+type PaperBrokerage struct {
+	baskets BasketRepository
+	quotes  QuoteProvider
+
+	mu    sync.Mutex
+	fills map[string]*BrokerageOrderStatus
+}
+
+// NewPaperBrokerage creates a simulated brokerage priced by quotes
+func NewPaperBrokerage(baskets BasketRepository, quotes QuoteProvider) *PaperBrokerage {
+	return &PaperBrokerage{
+		baskets: baskets,
+		quotes:  quotes,
+		fills:   make(map[string]*BrokerageOrderStatus),
+	}
+}
+
+// PlaceOrder fills the order immediately as a single basket-level fill
+func (b *PaperBrokerage) PlaceOrder(ctx context.Context, basketID uuid.UUID, side entities.OrderSide, amount decimal.Decimal) (*BrokerageOrderResponse, error) {
+	if b.quotes == nil {
+		return nil, ErrQuotesUnavailable
+	}
+	basket, err := b.baskets.GetByID(ctx, basketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+
+	symbols := make([]string, 0, len(basket.Composition))
+	for _, component := range basket.Composition {
+		symbols = append(symbols, component.Symbol)
+	}
+	quotes, err := b.quotes.GetQuotes(ctx, symbols, side)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrQuotesUnavailable, err)
+	}
+	price, err := SyntheticBasketPrice(basket, quotes)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := "PAPER-" + uuid.NewString()
+	b.mu.Lock()
+	b.fills[ref] = &BrokerageOrderStatus{
+		Status: entities.OrderStatusFilled,
+		Fills: []entities.BrokerageFill{{
+			Symbol:   basket.Name,
+			Quantity: amount.Div(price).Truncate(8).String(),
+			Price:    price.String(),
+		}},
+	}
+	b.mu.Unlock()
+
+	return &BrokerageOrderResponse{OrderRef: ref, Status: entities.OrderStatusFilled}, nil
+}
+
+// GetOrderStatus hands over a filled order's fills. Fills are delivered once,
+// as a fill webhook would be, and then forgotten.
+func (b *PaperBrokerage) GetOrderStatus(ctx context.Context, brokerageRef string) (*BrokerageOrderStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status, ok := b.fills[brokerageRef]
+	if !ok {
+		return nil, fmt.Errorf("paper order %s not found", brokerageRef)
+	}
+	delete(b.fills, brokerageRef)
+	return status, nil
+}
+
+// CancelOrder always fails: paper orders fill as soon as they are placed
+func (b *PaperBrokerage) CancelOrder(ctx context.Context, brokerageRef string) error {
+	return fmt.Errorf("paper order %s is already filled", brokerageRef)
+}
@@ -0,0 +1,274 @@
+package investing
+
+import (
+	"context"
+	"fmt"
+	"time"
+	_ "time/tzdata" // market hours are evaluated in America/New_York
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Quote is a symbol's latest executable price
+type Quote struct {
+	Price    decimal.Decimal
+	QuotedAt time.Time
+}
+
+// QuoteProvider returns live quotes, priced at the ask for buys and the bid
+// for sells
+type QuoteProvider interface {
+	GetQuotes(ctx context.Context, symbols []string, side entities.OrderSide) (map[string]Quote, error)
+}
+
+// FeeSchedule prices the fees charged on basket orders
+type FeeSchedule struct {
+	CommissionBps     decimal.Decimal // Commission in basis points of the order amount
+	MinimumCommission decimal.Decimal // Floor applied when a commission is charged
+	SECFeeRate        decimal.Decimal // SEC Section 31 fee per dollar sold
+	TAFPerShare       decimal.Decimal // FINRA trading activity fee per share sold
+	TAFMaximum        decimal.Decimal // TAF cap per component trade
+}
+
+// DefaultFeeSchedule is commission free with the current regulatory fee rates
+func DefaultFeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		CommissionBps:     decimal.Zero,
+		MinimumCommission: decimal.Zero,
+		SECFeeRate:        decimal.RequireFromString("0.0000278"),
+		TAFPerShare:       decimal.RequireFromString("0.000166"),
+		TAFMaximum:        decimal.RequireFromString("8.30"),
+	}
+}
+
+// Commission returns the commission on an order amount
+func (f FeeSchedule) Commission(amount decimal.Decimal) decimal.Decimal {
+	if f.CommissionBps.IsZero() {
+		return decimal.Zero
+	}
+	commission := amount.Mul(f.CommissionBps).Div(decimal.NewFromInt(10000)).RoundUp(2)
+	if commission.LessThan(f.MinimumCommission) {
+		commission = f.MinimumCommission
+	}
+	return commission
+}
+
+// Regulatory returns the SEC and FINRA fees on selling shares worth notional.
+// Both are rounded up to the cent, as the brokerage passes them through.
+func (f FeeSchedule) Regulatory(notional, shares decimal.Decimal) decimal.Decimal {
+	sec := notional.Mul(f.SECFeeRate).RoundUp(2)
+	taf := shares.Mul(f.TAFPerShare).RoundUp(2)
+	if taf.GreaterThan(f.TAFMaximum) {
+		taf = f.TAFMaximum
+	}
+	return sec.Add(taf)
+}
+
+const (
+	marketOpenMinute  = 9*60 + 30
+	marketCloseMinute = 16 * 60
+
+	// executionSpread is how long after the window opens basket component
+	// orders are expected to have filled
+	executionSpread = 15 * time.Minute
+)
+
+var marketTimezone = mustLoadLocation("America/New_York")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// ExecutionWindowAt estimates when an order placed at now executes: right away
+// during regular trading hours, otherwise at the next weekday open. Exchange
+// holidays are not accounted for; it is the fallback when no market calendar
+// is available.
+func ExecutionWindowAt(now time.Time) entities.ExecutionWindow {
+	local := now.In(marketTimezone)
+	minute := local.Hour()*60 + local.Minute()
+	weekday := local.Weekday() != time.Saturday && local.Weekday() != time.Sunday
+
+	if weekday && minute >= marketOpenMinute && minute < marketCloseMinute {
+		closeAt := time.Date(local.Year(), local.Month(), local.Day(), 16, 0, 0, 0, marketTimezone)
+		latest := now.Add(executionSpread)
+		if latest.After(closeAt) {
+			latest = closeAt
+		}
+		return entities.ExecutionWindow{MarketOpen: true, EarliestAt: now.UTC(), LatestAt: latest.UTC()}
+	}
+
+	openAt := time.Date(local.Year(), local.Month(), local.Day(), 9, 30, 0, 0, marketTimezone)
+	if !weekday || minute >= marketOpenMinute {
+		openAt = openAt.AddDate(0, 0, 1)
+	}
+	for openAt.Weekday() == time.Saturday || openAt.Weekday() == time.Sunday {
+		openAt = openAt.AddDate(0, 0, 1)
+	}
+	return entities.ExecutionWindow{EarliestAt: openAt.UTC(), LatestAt: openAt.Add(executionSpread).UTC()}
+}
+
+// MarketCalendar returns trading sessions from the exchange calendar
+type MarketCalendar interface {
+	NextSession(ctx context.Context, after time.Time) (*entities.MarketSession, error)
+}
+
+// executionWindow is ExecutionWindowAt with holidays and early closes taken
+// from the market calendar when one is configured
+func (s *Service) executionWindow(ctx context.Context, now time.Time) entities.ExecutionWindow {
+	session := s.nextSession(ctx, now)
+	if session == nil {
+		return ExecutionWindowAt(now)
+	}
+	if now.Before(session.OpenAt) {
+		return entities.ExecutionWindow{EarliestAt: session.OpenAt, LatestAt: session.OpenAt.Add(executionSpread)}
+	}
+	latest := now.Add(executionSpread)
+	if latest.After(session.CloseAt) {
+		latest = session.CloseAt
+	}
+	return entities.ExecutionWindow{MarketOpen: true, EarliestAt: now.UTC(), LatestAt: latest.UTC()}
+}
+
+// nextSession returns the session in progress or next to open, or nil when
+// no calendar is configured or it cannot be read
+func (s *Service) nextSession(ctx context.Context, now time.Time) *entities.MarketSession {
+	if s.calendar == nil {
+		return nil
+	}
+	session, err := s.calendar.NextSession(ctx, now)
+	if err != nil {
+		s.logger.Warn("Failed to read market calendar, assuming regular weekday hours", "error", err)
+		return nil
+	}
+	return session
+}
+
+// PreviewOrder estimates an order without placing it: per-component shares at
+// live prices, fees, the execution window and the cash left afterwards. It
+// applies the same checks as CreateOrder, so a preview that succeeds can be
+// placed as long as prices and balances do not move.
+func (s *Service) PreviewOrder(ctx context.Context, userID uuid.UUID, req *entities.OrderCreateRequest) (*entities.OrderPreview, error) {
+	if s.quotes == nil {
+		return nil, ErrQuotesUnavailable
+	}
+
+	basket, err := s.basketRepo.GetByID(ctx, req.BasketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket: %w", err)
+	}
+	if basket == nil {
+		return nil, ErrBasketNotFound
+	}
+	if err := s.checkBasketRegion(ctx, userID, basket); err != nil {
+		return nil, err
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidAmount
+	}
+
+	buyingPower := decimal.Zero
+	if balance, err := s.balanceRepo.Get(ctx, userID); err == nil {
+		buyingPower = balance.BuyingPower
+	} else if req.Side == entities.OrderSideBuy {
+		return nil, fmt.Errorf("failed to get user balance: %w", err)
+	}
+
+	switch req.Side {
+	case entities.OrderSideBuy:
+		if s.allocationService != nil {
+			canSpend, err := s.allocationService.CanSpend(ctx, userID, amount)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check spending limit: %w", err)
+			}
+			if !canSpend {
+				return nil, entities.ErrSpendingLimitReached
+			}
+		}
+		if buyingPower.LessThan(amount) {
+			return nil, ErrInsufficientFunds
+		}
+	case entities.OrderSideSell:
+		position, err := s.positionRepo.GetByUserAndBasket(ctx, userID, req.BasketID)
+		if err != nil && err != ErrPositionNotFound {
+			return nil, fmt.Errorf("failed to check position: %w", err)
+		}
+		if position == nil || position.MarketValue.LessThan(amount) {
+			return nil, ErrInsufficientPosition
+		}
+	default:
+		return nil, fmt.Errorf("unsupported order side %q", req.Side)
+	}
+
+	symbols := make([]string, 0, len(basket.Composition))
+	for _, component := range basket.Composition {
+		symbols = append(symbols, component.Symbol)
+	}
+	quotes, err := s.quotes.GetQuotes(ctx, symbols, req.Side)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrQuotesUnavailable, err)
+	}
+
+	// Buy commissions come out of the order amount, so less is invested
+	commission := s.fees.Commission(amount)
+	invested := amount
+	if req.Side == entities.OrderSideBuy {
+		invested = amount.Sub(commission)
+	}
+
+	preview := &entities.OrderPreview{
+		BasketID:    req.BasketID,
+		Side:        req.Side,
+		Amount:      amount.StringFixed(2),
+		Components:  make([]entities.OrderPreviewComponent, 0, len(basket.Composition)),
+		BuyingPower: buyingPower.StringFixed(2),
+		Paper:       s.paper,
+	}
+	regulatory := decimal.Zero
+	for _, component := range basket.Composition {
+		quote, ok := quotes[component.Symbol]
+		if !ok || !quote.Price.IsPositive() {
+			return nil, fmt.Errorf("%w: no price for %s", ErrQuotesUnavailable, component.Symbol)
+		}
+		notional := invested.Mul(component.Weight).RoundDown(2)
+		shares := notional.Div(quote.Price).Truncate(6)
+		if req.Side == entities.OrderSideSell {
+			regulatory = regulatory.Add(s.fees.Regulatory(notional, shares))
+		}
+		if quote.QuotedAt.After(preview.QuotedAt) {
+			preview.QuotedAt = quote.QuotedAt
+		}
+		preview.Components = append(preview.Components, entities.OrderPreviewComponent{
+			Symbol:          component.Symbol,
+			Weight:          component.Weight.String(),
+			Price:           quote.Price.StringFixed(2),
+			Notional:        notional.StringFixed(2),
+			EstimatedShares: shares.String(),
+		})
+	}
+
+	totalFees := commission.Add(regulatory)
+	preview.Fees = entities.OrderFeeEstimate{
+		Commission: commission.StringFixed(2),
+		Regulatory: regulatory.StringFixed(2),
+		Total:      totalFees.StringFixed(2),
+	}
+	if req.Side == entities.OrderSideBuy {
+		preview.NetAmount = invested.StringFixed(2)
+		preview.CashAfter = buyingPower.Sub(amount).StringFixed(2)
+	} else {
+		proceeds := amount.Sub(totalFees)
+		preview.NetAmount = proceeds.StringFixed(2)
+		preview.CashAfter = buyingPower.Add(proceeds).StringFixed(2)
+	}
+	preview.ExecutionWindow = s.executionWindow(ctx, time.Now())
+
+	s.logger.Debug("Previewed order", "user_id", userID, "basket_id", req.BasketID, "side", req.Side, "amount", amount)
+	return preview, nil
+}
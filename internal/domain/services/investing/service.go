@@ -9,6 +9,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // WalletBalanceProvider interface for fetching real-time wallet balances
@@ -44,21 +45,70 @@ type Service struct {
 	circleClient       CircleClient
 	allocationService  AllocationService
 	allocationNotifier AllocationNotificationManager
+	events             EventPublisher
+	progress           ProgressPublisher
+	confirmations      TradeConfirmations
+	rewards            RewardEarner
+	quotes             QuoteProvider
+	calendar           MarketCalendar
+	fees               FeeSchedule
+	holds              BalanceHolds
+	cart               CartRepository
+	passcodes          PasscodeSessions
+	twoFactor          TwoFactorVerifier
+	regions            UserCountryResolver
+	limitOrders        LimitOrderConfig
+	limitTracker       *workerstatus.Tracker
+	basketCache        BasketCache
+	paper              bool
 	logger             *logger.Logger
 }
 
+// EventPublisher queues domain events for partner webhooks
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType entities.WebhookEventType, data interface{}) error
+}
+
+// ProgressPublisher pushes progress events to the user's live event stream
+type ProgressPublisher interface {
+	PublishToUser(ctx context.Context, userID uuid.UUID, eventType entities.StreamEventType, data interface{}) error
+}
+
+// TradeConfirmations files a confirmation for each filled order
+type TradeConfirmations interface {
+	GenerateTradeConfirmation(ctx context.Context, order *entities.Order, fills []entities.BrokerageFill, fees entities.ExecutionFees) error
+}
+
+// RewardEarner earns rewards on filled buys and claws them back on sells
+type RewardEarner interface {
+	HandleOrderFilled(ctx context.Context, order *entities.Order) error
+}
+
 // BasketRepository interface for basket operations
 type BasketRepository interface {
 	GetAll(ctx context.Context) ([]*entities.Basket, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
 }
 
+// BasketCache serves basket detail from Redis and counts each view, so the
+// most viewed baskets can be warmed after a deploy
+type BasketCache interface {
+	Basket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error)
+}
+
 // OrderRepository interface for order management
 type OrderRepository interface {
 	Create(ctx context.Context, order *entities.Order) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Order, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int, status *entities.OrderStatus) ([]*entities.Order, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status entities.OrderStatus, brokerageRef *string) error
+	ListOpenLimitOrders(ctx context.Context, limit int) ([]*entities.Order, error)
+	// TriggerLimitOrder moves an open order to accepted, returning false if it
+	// is no longer open
+	TriggerLimitOrder(ctx context.Context, id uuid.UUID) (bool, error)
+	// CloseOpenOrder cancels or expires an open order, returning false if it
+	// is no longer open
+	CloseOpenOrder(ctx context.Context, id uuid.UUID, status entities.OrderStatus) (bool, error)
 }
 
 // PositionRepository interface for position tracking
@@ -117,10 +167,53 @@ func NewService(
 		circleClient:       circleClient,
 		allocationService:  allocationService,
 		allocationNotifier: allocationNotifier,
+		fees:               DefaultFeeSchedule(),
+		limitOrders:        DefaultLimitOrderConfig(),
 		logger:             logger,
 	}
 }
 
+// SetEventPublisher enables partner webhooks for order events
+func (s *Service) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// SetProgressPublisher enables live order progress events
+func (s *Service) SetProgressPublisher(progress ProgressPublisher) {
+	s.progress = progress
+}
+
+// SetTradeConfirmations generates a trade confirmation for each filled order
+func (s *Service) SetTradeConfirmations(confirmations TradeConfirmations) {
+	s.confirmations = confirmations
+}
+
+// SetRewards earns rewards on filled orders
+func (s *Service) SetRewards(rewards RewardEarner) {
+	s.rewards = rewards
+}
+
+// SetQuoteProvider enables order previews priced at live quotes
+func (s *Service) SetQuoteProvider(quotes QuoteProvider) {
+	s.quotes = quotes
+}
+
+// SetMarketCalendar makes execution windows and day order expiry follow the
+// exchange calendar, including holidays and early closes
+func (s *Service) SetMarketCalendar(calendar MarketCalendar) {
+	s.calendar = calendar
+}
+
+// SetFeeSchedule replaces the default commission-free fee schedule
+func (s *Service) SetFeeSchedule(fees FeeSchedule) {
+	s.fees = fees
+}
+
+// SetBasketCache serves basket detail requests through a cache
+func (s *Service) SetBasketCache(cache BasketCache) {
+	s.basketCache = cache
+}
+
 // ListBaskets returns all available curated baskets
 func (s *Service) ListBaskets(ctx context.Context) ([]*entities.Basket, error) {
 	baskets, err := s.basketRepo.GetAll(ctx)
@@ -155,16 +248,20 @@ func (s *Service) CreateOrder(ctx context.Context, userID uuid.UUID, req *entiti
 	if basket == nil {
 		return nil, ErrBasketNotFound
 	}
+	if err := s.checkBasketRegion(ctx, userID, basket); err != nil {
+		return nil, err
+	}
 
-	// Parse and validate amount
-	amount, err := decimal.NewFromString(req.Amount)
+	// Parse and validate amount; orders are placed in USD
+	orderAmount, err := entities.ParseMoney(req.Amount, entities.CurrencyUSD)
 	if err != nil {
 		return nil, fmt.Errorf("invalid amount format: %w", err)
 	}
 
-	if amount.LessThanOrEqual(decimal.Zero) {
+	if !orderAmount.IsPositive() {
 		return nil, ErrInvalidAmount
 	}
+	amount := orderAmount.Amount
 
 	// Check 70/30 allocation mode spending limit for buy orders
 	if req.Side == entities.OrderSideBuy {
@@ -225,38 +322,92 @@ func (s *Service) CreateOrder(ctx context.Context, userID uuid.UUID, req *entiti
 		Amount:       amount,
 		Status:       entities.OrderStatusAccepted,
 		BrokerageRef: nil,
+		Type:         entities.OrderTypeMarket,
+		TimeInForce:  entities.TimeInForceDay,
+		Paper:        s.paper,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
+	// Limit orders rest until the monitor sees the basket price cross the limit
+	if req.OrderType == entities.OrderTypeLimit {
+		if err := s.applyLimitTerms(ctx, order, req, order.CreatedAt); err != nil {
+			return nil, err
+		}
+	} else if req.OrderType != "" && req.OrderType != entities.OrderTypeMarket {
+		return nil, ErrInvalidOrderType
+	}
+
+	// Reserve buying power for buy orders before saving, so an order that
+	// loses a race for the same balance is never recorded
+	if req.Side == entities.OrderSideBuy {
+		if err := s.reserveBuyingPower(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
 	// Save order to database
 	if err := s.orderRepo.Create(ctx, order); err != nil {
+		if req.Side == entities.OrderSideBuy {
+			s.releaseBuyingPower(ctx, order)
+		}
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	s.publishOrderProgress(ctx, order, order.Status, nil)
 
-	// Reserve buying power for buy orders
-	if req.Side == entities.OrderSideBuy {
-		if err := s.balanceRepo.DeductBuyingPower(ctx, userID, amount); err != nil {
-			return nil, fmt.Errorf("failed to reserve buying power: %w", err)
+	if order.Status == entities.OrderStatusOpen {
+		s.logger.Info("Limit order resting", "order_id", order.ID, "limit_price", order.LimitPrice, "time_in_force", order.TimeInForce)
+		return order, nil
+	}
+
+	// Paper orders fill on submission, so they are answered with the fill
+	if s.paper {
+		s.submitToBrokerage(ctx, order)
+		if filled, err := s.orderRepo.GetByID(ctx, order.ID); err == nil && filled != nil {
+			return filled, nil
 		}
+		return order, nil
 	}
 
 	// Submit order to brokerage asynchronously
-	go func() {
-		brokerageResp, err := s.brokerageAPI.PlaceOrder(ctx, req.BasketID, req.Side, amount)
-		if err != nil {
-			s.logger.Error("Failed to submit order to brokerage", "order_id", order.ID, "error", err)
-			// Update order status to failed
-			s.orderRepo.UpdateStatus(ctx, order.ID, entities.OrderStatusFailed, nil)
-			return
+	go s.submitToBrokerage(ctx, order)
+
+	return order, nil
+}
+
+// submitToBrokerage places an accepted order with the brokerage and records
+// its reference, or marks it failed
+func (s *Service) submitToBrokerage(ctx context.Context, order *entities.Order) {
+	brokerageResp, err := s.brokerageAPI.PlaceOrder(ctx, order.BasketID, order.Side, order.Amount)
+	if err != nil {
+		s.logger.Error("Failed to submit order to brokerage", "order_id", order.ID, "error", err)
+		// Update order status to failed
+		s.orderRepo.UpdateStatus(ctx, order.ID, entities.OrderStatusFailed, nil)
+		if order.Side == entities.OrderSideBuy {
+			s.releaseBuyingPower(ctx, order)
 		}
+		return
+	}
 
-		// Update order with brokerage reference
-		s.orderRepo.UpdateStatus(ctx, order.ID, brokerageResp.Status, &brokerageResp.OrderRef)
-		s.logger.Info("Order submitted to brokerage", "order_id", order.ID, "brokerage_ref", brokerageResp.OrderRef)
-	}()
+	// Update order with brokerage reference
+	s.orderRepo.UpdateStatus(ctx, order.ID, brokerageResp.Status, &brokerageResp.OrderRef)
+	s.logger.Info("Order submitted to brokerage", "order_id", order.ID, "brokerage_ref", brokerageResp.OrderRef, "paper", s.paper)
 
-	return order, nil
+	// Orders filled on submission, as paper orders are, get no fill webhook
+	if brokerageResp.Status == entities.OrderStatusFilled {
+		status, err := s.brokerageAPI.GetOrderStatus(ctx, brokerageResp.OrderRef)
+		if err != nil {
+			s.logger.Error("Failed to get fills for filled order", "order_id", order.ID, "error", err)
+			return
+		}
+		if err := s.ProcessBrokerageFill(ctx, &entities.BrokerageFillWebhook{
+			OrderID: order.ID,
+			Status:  status.Status,
+			Fills:   status.Fills,
+		}); err != nil {
+			s.logger.Error("Failed to apply fills", "order_id", order.ID, "error", err)
+		}
+	}
 }
 
 // ListOrders returns orders for a user
@@ -276,7 +427,7 @@ func (s *Service) GetOrder(ctx context.Context, userID, orderID uuid.UUID) (*ent
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	if order.UserID != userID {
+	if order == nil || order.UserID != userID {
 		return nil, ErrOrderNotFound
 	}
 
@@ -307,6 +458,7 @@ func (s *Service) GetPortfolio(ctx context.Context, userID uuid.UUID) (*entities
 		Currency:   "USD",
 		Positions:  portfolioPositions,
 		TotalValue: totalValue.String(),
+		Paper:      s.paper,
 	}, nil
 }
 
@@ -405,7 +557,12 @@ func (s *Service) GetPortfolioOverview(ctx context.Context, userID uuid.UUID) (*
 	
 	// Add Circle USDC balance to database buying power
 	// (USDC is 1:1 with USD buying power)
-	buyingPower := balance.BuyingPower.Add(totalUSDCBalance)
+	usdcAsUSD := entities.NewMoney(totalUSDCBalance, entities.CurrencyUSDC).Convert(entities.CurrencyUSD, decimal.NewFromInt(1))
+	buyingPowerUSD, err := entities.NewMoney(balance.BuyingPower, entities.CurrencyUSD).Add(usdcAsUSD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total buying power: %w", err)
+	}
+	buyingPower := buyingPowerUSD.Amount
 
 	// Calculate total portfolio (positions + buying power)
 	totalPortfolio := positionsValue.Add(buyingPower)
@@ -448,29 +605,134 @@ func (s *Service) ProcessBrokerageFill(ctx context.Context, webhook *entities.Br
 	if err != nil {
 		return fmt.Errorf("failed to get order: %w", err)
 	}
+	if order == nil {
+		return ErrOrderNotFound
+	}
 
 	// Update order status
 	if err := s.orderRepo.UpdateStatus(ctx, order.ID, webhook.Status, order.BrokerageRef); err != nil {
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
+	s.publishOrderProgress(ctx, order, webhook.Status, webhook.Fills)
+
 	// If order is filled, update positions
 	if webhook.Status == entities.OrderStatusFilled {
 		if err := s.updatePositions(ctx, order, webhook.Fills); err != nil {
 			return fmt.Errorf("failed to update positions: %w", err)
 		}
+		// Live sale proceeds arrive with the brokerage account sync; paper
+		// accounts have no brokerage, so credit them here
+		if s.paper && order.Side == entities.OrderSideSell {
+			if err := s.balanceRepo.AddBuyingPower(ctx, order.UserID, fillValue(webhook.Fills)); err != nil {
+				s.logger.Error("Failed to credit paper sale proceeds", "order_id", order.ID, "error", err)
+			}
+		}
+		if order.Side == entities.OrderSideBuy {
+			s.captureBuyingPower(ctx, order)
+		}
+		s.publishOrderFilled(ctx, order, webhook.Fills)
+		s.generateTradeConfirmation(ctx, order, webhook.Fills)
+		s.handleRewards(ctx, order)
 	}
 
 	// If order failed, refund buying power for buy orders
 	if webhook.Status == entities.OrderStatusFailed && order.Side == entities.OrderSideBuy {
-		if err := s.balanceRepo.AddBuyingPower(ctx, order.UserID, order.Amount); err != nil {
-			s.logger.Error("Failed to refund buying power", "order_id", order.ID, "error", err)
-		}
+		s.releaseBuyingPower(ctx, order)
 	}
 
 	return nil
 }
 
+// publishOrderFilled notifies webhook subscribers that an order filled
+func (s *Service) publishOrderFilled(ctx context.Context, order *entities.Order, fills []entities.BrokerageFill) {
+	if s.events == nil {
+		return
+	}
+	if err := s.events.Publish(ctx, entities.WebhookEventOrderFilled, map[string]interface{}{
+		"order_id":  order.ID,
+		"user_id":   order.UserID,
+		"basket_id": order.BasketID,
+		"side":      order.Side,
+		"amount":    order.Amount.String(),
+		"fills":     fills,
+	}); err != nil {
+		s.logger.Warn("Failed to publish order filled event", "order_id", order.ID, "error", err)
+	}
+}
+
+// generateTradeConfirmation files the order's confirmation in the user's
+// document center. A failure is logged and does not fail the fill.
+func (s *Service) generateTradeConfirmation(ctx context.Context, order *entities.Order, fills []entities.BrokerageFill) {
+	if s.confirmations == nil {
+		return
+	}
+	if err := s.confirmations.GenerateTradeConfirmation(ctx, order, fills, s.ExecutionFees(order, fills)); err != nil {
+		s.logger.Warn("Failed to generate trade confirmation", "order_id", order.ID, "error", err)
+	}
+}
+
+// handleRewards passes the filled order to the rewards program. A failure is
+// logged and does not fail the fill.
+func (s *Service) handleRewards(ctx context.Context, order *entities.Order) {
+	if s.rewards == nil {
+		return
+	}
+	if err := s.rewards.HandleOrderFilled(ctx, order); err != nil {
+		s.logger.Warn("Failed to handle rewards for filled order", "order_id", order.ID, "error", err)
+	}
+}
+
+// ExecutionFees prices a filled order on the fee schedule: the commission on
+// the order amount and, on sells, the regulatory fees on each fill. Fills
+// whose quantity or price do not parse carry no regulatory fee.
+func (s *Service) ExecutionFees(order *entities.Order, fills []entities.BrokerageFill) entities.ExecutionFees {
+	fees := entities.ExecutionFees{
+		Commission: s.fees.Commission(order.Amount),
+		Regulatory: decimal.Zero,
+	}
+	if order.Side != entities.OrderSideSell {
+		return fees
+	}
+	for _, fill := range fills {
+		quantity, qerr := decimal.NewFromString(fill.Quantity)
+		price, perr := decimal.NewFromString(fill.Price)
+		if qerr != nil || perr != nil {
+			continue
+		}
+		fees.Regulatory = fees.Regulatory.Add(s.fees.Regulatory(quantity.Mul(price).Round(2), quantity))
+	}
+	return fees
+}
+
+// publishOrderProgress pushes an order status change to the user's event stream
+func (s *Service) publishOrderProgress(ctx context.Context, order *entities.Order, status entities.OrderStatus, fills []entities.BrokerageFill) {
+	if s.progress == nil {
+		return
+	}
+	if err := s.progress.PublishToUser(ctx, order.UserID, entities.StreamEventOrderUpdated, entities.OrderProgressEvent{
+		OrderID:  order.ID,
+		BasketID: order.BasketID,
+		Side:     order.Side,
+		Amount:   order.Amount.String(),
+		Status:   status,
+		Fills:    fills,
+	}); err != nil {
+		s.logger.Warn("Failed to publish order progress", "order_id", order.ID, "error", err)
+	}
+}
+
+// fillValue sums quantity times price across fills
+func fillValue(fills []entities.BrokerageFill) decimal.Decimal {
+	total := decimal.Zero
+	for _, fill := range fills {
+		quantity, _ := decimal.NewFromString(fill.Quantity)
+		price, _ := decimal.NewFromString(fill.Price)
+		total = total.Add(quantity.Mul(price))
+	}
+	return total
+}
+
 // updatePositions updates user positions based on fills
 func (s *Service) updatePositions(ctx context.Context, order *entities.Order, fills []entities.BrokerageFill) error {
 	// Get or create position for this basket
@@ -532,4 +794,11 @@ var (
 	ErrInvalidAmount        = fmt.Errorf("invalid amount")
 	ErrInsufficientFunds    = fmt.Errorf("insufficient buying power")
 	ErrInsufficientPosition = fmt.Errorf("insufficient position")
+	ErrQuotesUnavailable    = fmt.Errorf("live quotes unavailable")
+	ErrInvalidOrderType     = fmt.Errorf("invalid order type")
+	ErrInvalidLimitPrice    = fmt.Errorf("limit orders need a positive limit price")
+	ErrInvalidTimeInForce   = fmt.Errorf("invalid time in force")
+	ErrOrderNotCancelable   = fmt.Errorf("only open limit orders can be canceled")
+	ErrBasketInUse          = fmt.Errorf("basket has orders or positions")
+	ErrCartUnavailable      = fmt.Errorf("investing cart is not enabled")
 )
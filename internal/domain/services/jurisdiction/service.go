@@ -0,0 +1,241 @@
+package jurisdiction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// defaultCacheTTL bounds how long an admin rule change takes to reach every instance
+const defaultCacheTTL = time.Minute
+
+// ErrInvalidRule is returned when an admin submits a malformed country rule
+var ErrInvalidRule = errors.New("invalid country rule")
+
+// Repository persists country rules and the country recorded for each user
+type Repository interface {
+	ListRules(ctx context.Context) ([]*entities.CountryRule, error)
+	UpsertRule(ctx context.Context, rule *entities.CountryRule) error
+	DeleteRule(ctx context.Context, countryCode string) (bool, error)
+	GetUserCountry(ctx context.Context, userID uuid.UUID) (string, error)
+	SetUserCountry(ctx context.Context, userID uuid.UUID, countryCode string) error
+}
+
+// Service evaluates per-country regulatory rules. Rules are cached in memory and
+// reloaded from the database periodically so updates apply without a redeploy.
+type Service struct {
+	repo     Repository
+	logger   *zap.Logger
+	cacheTTL time.Duration
+
+	mu       sync.RWMutex
+	rules    map[string]*entities.CountryRule
+	loadedAt time.Time
+}
+
+// NewService creates a new jurisdiction service
+func NewService(repo Repository, cacheTTL time.Duration, logger *zap.Logger) *Service {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	return &Service{
+		repo:     repo,
+		logger:   logger,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// ListRules returns every configured country rule
+func (s *Service) ListRules(ctx context.Context) ([]*entities.CountryRule, error) {
+	return s.repo.ListRules(ctx)
+}
+
+// Rule returns the rule for a country, falling back to the default rule. A
+// missing default rule denies everything so misconfiguration fails closed.
+func (s *Service) Rule(ctx context.Context, countryCode string) (*entities.CountryRule, error) {
+	rules, err := s.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	code := entities.NormalizeCountryCode(countryCode)
+	if rule, ok := rules[code]; ok {
+		return rule, nil
+	}
+	if rule, ok := rules[entities.DefaultCountryCode]; ok {
+		return rule, nil
+	}
+	return &entities.CountryRule{CountryCode: code, KYCLevel: entities.KYCLevelEnhanced}, nil
+}
+
+// EvaluateOnboarding decides whether a user from a country may onboard and
+// which features, disclosures and KYC level apply to them.
+func (s *Service) EvaluateOnboarding(ctx context.Context, countryCode string) (*entities.JurisdictionDecision, error) {
+	code := entities.NormalizeCountryCode(countryCode)
+	rule, err := s.Rule(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &entities.JurisdictionDecision{
+		CountryCode:         code,
+		Allowed:             rule.OnboardingAllowed,
+		AllowedFeatures:     []entities.JurisdictionFeature{},
+		RequiredDisclosures: rule.RequiredDisclosures,
+		KYCLevel:            rule.KYCLevel,
+	}
+	if rule.OnboardingAllowed {
+		decision.AllowedFeatures = rule.AllowedFeatures
+	}
+	return decision, nil
+}
+
+// RecordUserCountry stores the country a user onboarded from
+func (s *Service) RecordUserCountry(ctx context.Context, userID uuid.UUID, countryCode string) error {
+	return s.repo.SetUserCountry(ctx, userID, entities.NormalizeCountryCode(countryCode))
+}
+
+// UserCountry returns the country a user onboarded from, or an empty string
+// for users onboarded before country capture
+func (s *Service) UserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	return s.repo.GetUserCountry(ctx, userID)
+}
+
+// IsFeatureAllowed reports whether a feature is enabled in the user's country.
+// Users onboarded before country capture are evaluated against the default rule.
+func (s *Service) IsFeatureAllowed(ctx context.Context, userID uuid.UUID, feature entities.JurisdictionFeature) (bool, string, error) {
+	country, err := s.repo.GetUserCountry(ctx, userID)
+	if err != nil {
+		return false, "", err
+	}
+	rule, err := s.Rule(ctx, country)
+	if err != nil {
+		return false, country, err
+	}
+	return rule.Allows(feature), country, nil
+}
+
+// ScreenUser re-evaluates an existing user against the current rule for the
+// country they onboarded from, e.g. before a closed account is reopened
+func (s *Service) ScreenUser(ctx context.Context, userID uuid.UUID) (*entities.JurisdictionDecision, error) {
+	country, err := s.repo.GetUserCountry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.EvaluateOnboarding(ctx, country)
+}
+
+// UpsertRule creates or replaces a country rule and refreshes the cache
+func (s *Service) UpsertRule(ctx context.Context, countryCode string, req *entities.UpsertCountryRuleRequest, updatedBy uuid.UUID) (*entities.CountryRule, error) {
+	code := entities.NormalizeCountryCode(countryCode)
+	if code != entities.DefaultCountryCode && len(code) != 2 {
+		return nil, fmt.Errorf("%w: invalid country code %q", ErrInvalidRule, countryCode)
+	}
+	for _, feature := range req.AllowedFeatures {
+		if !isKnownFeature(feature) {
+			return nil, fmt.Errorf("%w: unknown feature %q", ErrInvalidRule, feature)
+		}
+	}
+
+	disclosures := req.RequiredDisclosures
+	if disclosures == nil {
+		disclosures = []string{}
+	}
+	features := req.AllowedFeatures
+	if features == nil {
+		features = []entities.JurisdictionFeature{}
+	}
+
+	rule := &entities.CountryRule{
+		CountryCode:         code,
+		OnboardingAllowed:   req.OnboardingAllowed,
+		AllowedFeatures:     features,
+		RequiredDisclosures: disclosures,
+		KYCLevel:            req.KYCLevel,
+		Notes:               req.Notes,
+		UpdatedBy:           &updatedBy,
+		UpdatedAt:           time.Now(),
+	}
+	if err := s.repo.UpsertRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	s.logger.Info("Country rule updated",
+		zap.String("country_code", code),
+		zap.Bool("onboarding_allowed", rule.OnboardingAllowed),
+		zap.String("kyc_level", string(rule.KYCLevel)),
+		zap.String("updated_by", updatedBy.String()))
+
+	return rule, nil
+}
+
+// DeleteRule removes a country rule so the default rule applies again
+func (s *Service) DeleteRule(ctx context.Context, countryCode string) (bool, error) {
+	code := entities.NormalizeCountryCode(countryCode)
+	if code == entities.DefaultCountryCode {
+		return false, fmt.Errorf("%w: the default rule cannot be deleted", ErrInvalidRule)
+	}
+	deleted, err := s.repo.DeleteRule(ctx, code)
+	if err != nil {
+		return false, err
+	}
+	s.invalidate()
+	return deleted, nil
+}
+
+func (s *Service) loadRules(ctx context.Context) (map[string]*entities.CountryRule, error) {
+	s.mu.RLock()
+	if s.rules != nil && time.Since(s.loadedAt) < s.cacheTTL {
+		rules := s.rules
+		s.mu.RUnlock()
+		return rules, nil
+	}
+	s.mu.RUnlock()
+
+	list, err := s.repo.ListRules(ctx)
+	if err != nil {
+		s.mu.RLock()
+		stale := s.rules
+		s.mu.RUnlock()
+		if stale != nil {
+			s.logger.Warn("Failed to refresh country rules, serving cached copy", zap.Error(err))
+			return stale, nil
+		}
+		return nil, fmt.Errorf("failed to load country rules: %w", err)
+	}
+
+	rules := make(map[string]*entities.CountryRule, len(list))
+	for _, rule := range list {
+		rules[rule.CountryCode] = rule
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return rules, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func isKnownFeature(feature entities.JurisdictionFeature) bool {
+	for _, known := range entities.KnownJurisdictionFeatures {
+		if known == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,645 @@
+package kyb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Repository persists applications, their beneficial owners and the account
+// type of users
+type Repository interface {
+	GetAccountType(ctx context.Context, userID uuid.UUID) (entities.UserAccountType, error)
+	// ApplyBusinessAccount marks the user as a business and replaces the
+	// matching transaction limits
+	ApplyBusinessAccount(ctx context.Context, userID uuid.UUID, limits []entities.TierLimit) error
+	// Create inserts an application unless the user already has a draft or
+	// one awaiting review, in which case that one is returned with created=false
+	Create(ctx context.Context, application *entities.KYBApplication) (*entities.KYBApplication, bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.KYBApplication, error)
+	GetLatestByUser(ctx context.Context, userID uuid.UUID) (*entities.KYBApplication, error)
+	List(ctx context.Context, status entities.KYBStatus, limit, offset int) ([]*entities.KYBApplication, error)
+	Update(ctx context.Context, application *entities.KYBApplication) error
+	AddOwner(ctx context.Context, owner *entities.BeneficialOwner) error
+	UpdateOwner(ctx context.Context, owner *entities.BeneficialOwner) error
+	DeleteOwner(ctx context.Context, applicationID, ownerID uuid.UUID) error
+}
+
+// CaseService opens and resolves admin review cases
+type CaseService interface {
+	Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error)
+	Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error)
+}
+
+// OwnerVerifier runs individual identity verification for a beneficial
+// owner with whichever KYC provider the platform uses. The owner's ID is the
+// applicant ID at the provider.
+type OwnerVerifier interface {
+	SubmitKYC(ctx context.Context, applicantID uuid.UUID, documents []entities.KYCDocumentUpload, personalInfo *entities.KYCPersonalInfo) (string, error)
+	GetKYCStatus(ctx context.Context, providerRef string) (*entities.KYCSubmission, error)
+}
+
+// DocumentResolver turns references to secure uploads into links the KYC
+// provider can fetch
+type DocumentResolver interface {
+	ResolveDocuments(ctx context.Context, userID uuid.UUID, purpose entities.UploadPurpose, documents []entities.KYCDocumentUpload) ([]entities.KYCDocumentUpload, error)
+}
+
+// Config configures business onboarding
+type Config struct {
+	Enabled bool                 // Accept business applications
+	Limits  []entities.TierLimit // Limits granted to verified business accounts
+}
+
+// DefaultConfig keeps business onboarding off and grants verified businesses
+// $250,000 a day in deposits and trades and $100,000 a day in withdrawals
+// once enabled
+func DefaultConfig() Config {
+	return Config{
+		Enabled: false,
+		Limits:  DefaultLimits(250000, 100000, 250000),
+	}
+}
+
+// DefaultLimits builds the daily deposit, withdrawal and trade limits of a
+// business account
+func DefaultLimits(deposit, withdrawal, trade float64) []entities.TierLimit {
+	return []entities.TierLimit{
+		{LimitType: entities.LimitTypeDeposit, Period: entities.LimitPeriodDaily, MaxAmount: decimal.NewFromFloat(deposit)},
+		{LimitType: entities.LimitTypeWithdrawal, Period: entities.LimitPeriodDaily, MaxAmount: decimal.NewFromFloat(withdrawal)},
+		{LimitType: entities.LimitTypeTrade, Period: entities.LimitPeriodDaily, MaxAmount: decimal.NewFromFloat(trade)},
+	}
+}
+
+// SignificantOwnership is the stake at or above which an owner must be
+// declared and verified
+var SignificantOwnership = decimal.NewFromInt(25)
+
+var hundred = decimal.NewFromInt(100)
+
+// Service runs business (KYB) onboarding. A user fills in a draft
+// application with company details, company documents and the business's
+// beneficial owners; each owner is verified individually through the KYC
+// provider. Submitted applications go to compliance as business
+// verification cases, kept apart from personal KYC and EDD reviews, and
+// approval turns the account into a business account with business limits.
+type Service struct {
+	repo     Repository
+	cases    CaseService
+	verifier OwnerVerifier
+	resolver DocumentResolver
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewService creates a new business onboarding service
+func NewService(repo Repository, cases CaseService, config Config, logger *zap.Logger) *Service {
+	if len(config.Limits) == 0 {
+		config.Limits = DefaultConfig().Limits
+	}
+	return &Service{
+		repo:   repo,
+		cases:  cases,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetVerifier sends beneficial owners to the KYC provider. Without one,
+// compliance checks owner documents by hand.
+func (s *Service) SetVerifier(verifier OwnerVerifier) {
+	s.verifier = verifier
+}
+
+// SetDocumentResolver lets company and owner documents reference secure
+// uploads
+func (s *Service) SetDocumentResolver(resolver DocumentResolver) {
+	s.resolver = resolver
+}
+
+// resolveDocuments returns the documents with upload references replaced by
+// fetchable links
+func (s *Service) resolveDocuments(ctx context.Context, userID uuid.UUID, documents []entities.KYCDocumentUpload) ([]entities.KYCDocumentUpload, error) {
+	if s.resolver == nil {
+		return documents, nil
+	}
+	return s.resolver.ResolveDocuments(ctx, userID, entities.UploadPurposeKYB, documents)
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Overview returns whether the user can apply, their account type and their
+// latest application with current owner verification results
+func (s *Service) Overview(ctx context.Context, userID uuid.UUID) (*entities.KYBOverview, error) {
+	accountType, err := s.repo.GetAccountType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	overview := &entities.KYBOverview{
+		Available:   s.config.Enabled && accountType != entities.UserAccountBusiness,
+		AccountType: accountType,
+	}
+
+	latest, err := s.repo.GetLatestByUser(ctx, userID)
+	if err != nil && !errors.Is(err, entities.ErrKYBApplicationNotFound) {
+		return nil, err
+	}
+	if latest != nil {
+		s.refreshOwners(ctx, latest)
+	}
+	overview.Application = latest
+	return overview, nil
+}
+
+// SaveCompany records the company details, starting a draft application if
+// the user has none open
+func (s *Service) SaveCompany(ctx context.Context, userID uuid.UUID, req *entities.SaveKYBCompanyRequest) (*entities.KYBApplication, error) {
+	if !req.EntityType.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported entity type %q", entities.ErrKYBIncomplete, req.EntityType)
+	}
+	taxID := normalizeTaxID(req.TaxID)
+	if len(taxID) < 4 {
+		return nil, fmt.Errorf("%w: tax ID is too short", entities.ErrKYBIncomplete)
+	}
+
+	application, err := s.openDraft(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	application.LegalName = strings.TrimSpace(req.LegalName)
+	application.TradeName = req.TradeName
+	application.EntityType = req.EntityType
+	application.RegistrationNumber = strings.TrimSpace(req.RegistrationNumber)
+	application.TaxID = taxID
+	application.TaxIDLast4 = taxID[len(taxID)-4:]
+	application.IncorporationCountry = strings.ToUpper(req.IncorporationCountry)
+	application.IncorporationState = req.IncorporationState
+	application.IncorporationDate = req.IncorporationDate
+	application.Address = req.Address
+	application.Website = req.Website
+	application.BusinessActivity = req.BusinessActivity
+	application.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, application); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// AddDocuments attaches company documents to the user's draft. A document
+// of a type already attached replaces it.
+func (s *Service) AddDocuments(ctx context.Context, userID uuid.UUID, documents []entities.KYCDocumentUpload) (*entities.KYBApplication, error) {
+	documents, err := s.resolveDocuments(ctx, userID, documents)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDocuments(documents); err != nil {
+		return nil, err
+	}
+	documents = entities.StoredDocuments(documents)
+	application, err := s.draft(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range documents {
+		replaced := false
+		for i := range application.Documents {
+			if application.Documents[i].Type == doc.Type {
+				application.Documents[i] = doc
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			application.Documents = append(application.Documents, doc)
+		}
+	}
+	application.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, application); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// AddOwner declares a beneficial owner on the user's draft and starts their
+// identity verification
+func (s *Service) AddOwner(ctx context.Context, userID uuid.UUID, req *entities.AddBeneficialOwnerRequest) (*entities.BeneficialOwner, error) {
+	documents, err := s.resolveDocuments(ctx, userID, req.Documents)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateOwner(req, documents); err != nil {
+		return nil, err
+	}
+	application, err := s.draft(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := req.OwnershipPercent
+	for _, owner := range application.Owners {
+		if strings.EqualFold(owner.Email, req.Email) {
+			return nil, fmt.Errorf("%w: %s is already listed", entities.ErrInvalidBeneficialOwner, req.Email)
+		}
+		total = total.Add(owner.OwnershipPercent)
+	}
+	if total.GreaterThan(hundred) {
+		return nil, fmt.Errorf("%w: ownership adds up to more than 100%%", entities.ErrInvalidBeneficialOwner)
+	}
+
+	now := s.now()
+	owner := &entities.BeneficialOwner{
+		ID:               uuid.New(),
+		ApplicationID:    application.ID,
+		FirstName:        req.FirstName,
+		LastName:         req.LastName,
+		Email:            strings.ToLower(req.Email),
+		Title:            req.Title,
+		OwnershipPercent: req.OwnershipPercent,
+		IsController:     req.IsController,
+		Country:          strings.ToUpper(req.Country),
+		Documents:        entities.StoredDocuments(documents),
+		KYCStatus:        entities.KYCStatusPending,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if s.verifier != nil {
+		ref, err := s.verifier.SubmitKYC(ctx, owner.ID, documents, &entities.KYCPersonalInfo{
+			FirstName:   req.FirstName,
+			LastName:    req.LastName,
+			DateOfBirth: req.DateOfBirth,
+			Country:     owner.Country,
+			Address:     req.Address,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to submit beneficial owner for verification: %w", err)
+		}
+		owner.KYCProviderRef = &ref
+		owner.KYCStatus = entities.KYCStatusProcessing
+		owner.KYCCheckedAt = &now
+	}
+
+	if err := s.repo.AddOwner(ctx, owner); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Beneficial owner added",
+		zap.String("application_id", application.ID.String()),
+		zap.String("owner_id", owner.ID.String()),
+		zap.Bool("provider_verification", owner.KYCProviderRef != nil))
+	return owner, nil
+}
+
+// RemoveOwner takes an owner off the user's draft
+func (s *Service) RemoveOwner(ctx context.Context, userID, ownerID uuid.UUID) error {
+	application, err := s.draft(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteOwner(ctx, application.ID, ownerID)
+}
+
+// Submit sends the user's draft to compliance once it is complete
+func (s *Service) Submit(ctx context.Context, userID uuid.UUID) (*entities.KYBApplication, error) {
+	application, err := s.repo.GetLatestByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// A submission left without a case by an earlier failure is queued on retry
+	if application.Status == entities.KYBPendingReview && application.CaseID == nil {
+		if err := s.openCase(ctx, application); err != nil {
+			return nil, err
+		}
+		return application, nil
+	}
+	if !application.Editable() {
+		return nil, entities.ErrKYBNotEditable
+	}
+
+	s.refreshOwners(ctx, application)
+	if err := checkComplete(application); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	application.Status = entities.KYBPendingReview
+	application.SubmittedAt = &now
+	application.UpdatedAt = now
+	if err := s.repo.Update(ctx, application); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Business verification submitted",
+		zap.String("application_id", application.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.Int("owner_count", len(application.Owners)))
+
+	if err := s.openCase(ctx, application); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// Approve turns the account into a business account with business limits.
+// Owners verified through the provider must all have passed.
+func (s *Service) Approve(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.KYBApplication, error) {
+	application, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshOwners(ctx, application)
+	for _, owner := range application.Owners {
+		if owner.KYCProviderRef != nil && owner.KYCStatus != entities.KYCStatusApproved {
+			return nil, fmt.Errorf("%w: %s %s is %s", entities.ErrKYBOwnersUnverified, owner.FirstName, owner.LastName, owner.KYCStatus)
+		}
+	}
+
+	if err := s.repo.ApplyBusinessAccount(ctx, application.UserID, s.config.Limits); err != nil {
+		return nil, fmt.Errorf("failed to apply business account: %w", err)
+	}
+	if err := s.review(ctx, application, entities.KYBApproved, adminID, notes, nil); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Business verification approved",
+		zap.String("application_id", application.ID.String()),
+		zap.String("user_id", application.UserID.String()),
+		zap.String("admin_id", adminID.String()))
+	s.resolveCase(ctx, application, adminID, "Business account approved")
+	return application, nil
+}
+
+// Reject declines an application; the user keeps a personal account and may
+// apply again
+func (s *Service) Reject(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.KYBApplication, error) {
+	application, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "We could not verify the business with the information provided"
+	if err := s.review(ctx, application, entities.KYBRejected, adminID, notes, &reason); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Business verification rejected",
+		zap.String("application_id", application.ID.String()),
+		zap.String("user_id", application.UserID.String()),
+		zap.String("admin_id", adminID.String()))
+	s.resolveCase(ctx, application, adminID, "Business account rejected")
+	return application, nil
+}
+
+// Get returns an application with current owner verification results
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.KYBApplication, error) {
+	application, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshOwners(ctx, application)
+	return application, nil
+}
+
+// List returns applications filtered by optional status
+func (s *Service) List(ctx context.Context, status entities.KYBStatus, limit, offset int) ([]*entities.KYBApplication, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+// openDraft returns the user's open application, creating a draft when
+// there is none
+func (s *Service) openDraft(ctx context.Context, userID uuid.UUID) (*entities.KYBApplication, error) {
+	if !s.config.Enabled {
+		return nil, entities.ErrKYBUnavailable
+	}
+	accountType, err := s.repo.GetAccountType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if accountType == entities.UserAccountBusiness {
+		return nil, entities.ErrKYBAlreadyBusiness
+	}
+
+	now := s.now()
+	application, _, err := s.repo.Create(ctx, &entities.KYBApplication{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    entities.KYBDraft,
+		Documents: []entities.KYCDocumentUpload{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !application.Editable() {
+		return nil, entities.ErrKYBNotEditable
+	}
+	return application, nil
+}
+
+// draft returns the user's application when it can still be changed
+func (s *Service) draft(ctx context.Context, userID uuid.UUID) (*entities.KYBApplication, error) {
+	application, err := s.repo.GetLatestByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !application.Editable() {
+		return nil, entities.ErrKYBNotEditable
+	}
+	return application, nil
+}
+
+func (s *Service) pending(ctx context.Context, id uuid.UUID) (*entities.KYBApplication, error) {
+	application, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != entities.KYBPendingReview {
+		return nil, entities.ErrKYBNotPending
+	}
+	return application, nil
+}
+
+func (s *Service) review(ctx context.Context, application *entities.KYBApplication, status entities.KYBStatus, adminID uuid.UUID, notes, reason *string) error {
+	now := s.now()
+	application.Status = status
+	application.RejectionReason = reason
+	application.ReviewedBy = &adminID
+	application.ReviewNotes = notes
+	application.ReviewedAt = &now
+	application.UpdatedAt = now
+	return s.repo.Update(ctx, application)
+}
+
+// refreshOwners updates the verification result of owners still being
+// checked by the provider. Lookup failures are logged; the stored status
+// stands until the next refresh.
+func (s *Service) refreshOwners(ctx context.Context, application *entities.KYBApplication) {
+	if s.verifier == nil {
+		return
+	}
+	for _, owner := range application.Owners {
+		if owner.KYCProviderRef == nil || owner.KYCStatus == entities.KYCStatusApproved || owner.KYCStatus == entities.KYCStatusRejected {
+			continue
+		}
+		result, err := s.verifier.GetKYCStatus(ctx, *owner.KYCProviderRef)
+		if err != nil {
+			s.logger.Warn("Failed to check beneficial owner verification",
+				zap.String("owner_id", owner.ID.String()), zap.Error(err))
+			continue
+		}
+		now := s.now()
+		owner.KYCCheckedAt = &now
+		if result.Status == owner.KYCStatus {
+			continue
+		}
+		owner.KYCStatus = result.Status
+		owner.UpdatedAt = now
+		if err := s.repo.UpdateOwner(ctx, owner); err != nil {
+			s.logger.Warn("Failed to record beneficial owner verification",
+				zap.String("owner_id", owner.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+func (s *Service) openCase(ctx context.Context, application *entities.KYBApplication) error {
+	owners := make([]map[string]interface{}, 0, len(application.Owners))
+	for _, owner := range application.Owners {
+		owners = append(owners, map[string]interface{}{
+			"owner_id":          owner.ID.String(),
+			"name":              owner.FirstName + " " + owner.LastName,
+			"ownership_percent": owner.OwnershipPercent.String(),
+			"is_controller":     owner.IsController,
+			"kyc_status":        string(owner.KYCStatus),
+		})
+	}
+	documentTypes := make([]string, 0, len(application.Documents))
+	for _, doc := range application.Documents {
+		documentTypes = append(documentTypes, doc.Type)
+	}
+
+	adminCase, err := s.cases.Open(ctx, application.UserID, entities.AdminCaseBusinessVerification,
+		"Business account application for "+application.LegalName, map[string]interface{}{
+			"kyb_application_id":    application.ID.String(),
+			"legal_name":            application.LegalName,
+			"entity_type":           string(application.EntityType),
+			"incorporation_country": application.IncorporationCountry,
+			"document_types":        documentTypes,
+			"owners":                owners,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to open business verification case: %w", err)
+	}
+	application.CaseID = &adminCase.ID
+	application.UpdatedAt = s.now()
+	return s.repo.Update(ctx, application)
+}
+
+func (s *Service) resolveCase(ctx context.Context, application *entities.KYBApplication, adminID uuid.UUID, resolution string) {
+	if application.CaseID == nil {
+		return
+	}
+	if _, err := s.cases.Update(ctx, *application.CaseID, &entities.UpdateAdminCaseRequest{
+		Status:          entities.AdminCaseResolved,
+		ResolutionNotes: &resolution,
+	}, adminID); err != nil {
+		s.logger.Warn("Failed to resolve business verification case", zap.String("case_id", application.CaseID.String()), zap.Error(err))
+	}
+}
+
+// checkComplete requires company details, a certificate of incorporation, a
+// controller among the owners and no owner who failed verification
+func checkComplete(application *entities.KYBApplication) error {
+	if application.LegalName == "" || application.TaxID == "" {
+		return fmt.Errorf("%w: company details are missing", entities.ErrKYBIncomplete)
+	}
+	hasCertificate := false
+	for _, doc := range application.Documents {
+		if doc.Type == entities.KYBDocumentCertificateOfIncorporation {
+			hasCertificate = true
+		}
+	}
+	if !hasCertificate {
+		return fmt.Errorf("%w: a certificate of incorporation is required", entities.ErrKYBIncomplete)
+	}
+	if len(application.Owners) == 0 {
+		return fmt.Errorf("%w: at least one beneficial owner is required", entities.ErrKYBIncomplete)
+	}
+
+	hasController := false
+	for _, owner := range application.Owners {
+		if owner.KYCStatus == entities.KYCStatusRejected || owner.KYCStatus == entities.KYCStatusExpired {
+			return fmt.Errorf("%w: %s %s did not pass identity verification; remove and add them again",
+				entities.ErrKYBIncomplete, owner.FirstName, owner.LastName)
+		}
+		if owner.IsController {
+			hasController = true
+		}
+	}
+	if !hasController {
+		return fmt.Errorf("%w: one owner must be named as the person who controls the business", entities.ErrKYBIncomplete)
+	}
+	return nil
+}
+
+// validateOwner requires a stake between 0 and 100, that every owner either
+// holds a significant stake or controls the business, and that the resolved
+// identity documents can be fetched
+func validateOwner(req *entities.AddBeneficialOwnerRequest, documents []entities.KYCDocumentUpload) error {
+	if req.OwnershipPercent.IsNegative() || req.OwnershipPercent.GreaterThan(hundred) {
+		return fmt.Errorf("%w: ownership must be between 0 and 100", entities.ErrInvalidBeneficialOwner)
+	}
+	if req.OwnershipPercent.LessThan(SignificantOwnership) && !req.IsController {
+		return fmt.Errorf("%w: owners below %s%% are only listed when they control the business",
+			entities.ErrInvalidBeneficialOwner, SignificantOwnership.String())
+	}
+	for _, doc := range documents {
+		if doc.FileURL == "" || doc.ContentType == "" {
+			return fmt.Errorf("%w: %s needs a file URL and content type", entities.ErrInvalidBeneficialOwner, doc.Type)
+		}
+	}
+	return nil
+}
+
+// validateDocuments requires known company document types
+func validateDocuments(documents []entities.KYCDocumentUpload) error {
+	for _, doc := range documents {
+		if !entities.IsKYBDocumentType(doc.Type) {
+			return fmt.Errorf("%w: unsupported type %q", entities.ErrInvalidKYBDocument, doc.Type)
+		}
+		if doc.FileURL == "" || doc.ContentType == "" {
+			return fmt.Errorf("%w: %s needs a file URL and content type", entities.ErrInvalidKYBDocument, doc.Type)
+		}
+	}
+	return nil
+}
+
+// normalizeTaxID keeps the letters and digits of a tax ID
+func normalizeTaxID(taxID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(taxID) {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -91,6 +91,16 @@ func CreateWithdrawalEntries(userAccountID, systemBufferID uuid.UUID, amount dec
 		Build()
 }
 
+// CreateBankDepositEntries creates entries for a bank transfer into a shared
+// account. User's fiat exposure increases, system fiat buffer decreases
+func CreateBankDepositEntries(fiatExposureID, fiatBufferID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Bank transfer deposit"
+	return NewEntryBuilder().
+		AddDebit(fiatExposureID, amount, "USD", &desc).
+		AddCredit(fiatBufferID, amount, "USD", &desc).
+		Build()
+}
+
 // CreateConversionUSDCToUSDEntries creates entries for USDC → USD conversion
 // System USDC buffer decreases, system fiat buffer increases
 func CreateConversionUSDCToUSDEntries(usdcBufferID, fiatBufferID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
@@ -171,6 +181,116 @@ func CreateBufferReplenishmentEntries(sourceBufferID, destBufferID uuid.UUID, am
 		Build()
 }
 
+// CreateSuspenseHoldEntries creates entries for funds received that cannot be
+// attributed to a user. Suspense increases, system buffer decreases
+func CreateSuspenseHoldEntries(suspenseID, systemBufferID uuid.UUID, amount decimal.Decimal, currency string) []entities.CreateEntryRequest {
+	desc := "Unattributed funds held in suspense"
+	return NewEntryBuilder().
+		AddDebit(suspenseID, amount, currency, &desc).
+		AddCredit(systemBufferID, amount, currency, &desc).
+		Build()
+}
+
+// CreateSuspenseReleaseEntries creates entries for funds leaving suspense,
+// assigned to a user or refunded. Suspense decreases, system buffer increases
+func CreateSuspenseReleaseEntries(suspenseID, systemBufferID uuid.UUID, amount decimal.Decimal, currency string) []entities.CreateEntryRequest {
+	desc := "Funds released from suspense"
+	return NewEntryBuilder().
+		AddCredit(suspenseID, amount, currency, &desc).
+		AddDebit(systemBufferID, amount, currency, &desc).
+		Build()
+}
+
+// CreatePromotionGrantEntries creates entries for granting promotional credit
+// Marketing budget decreases, user's promotional credit increases
+func CreatePromotionGrantEntries(promotionalCreditID, promotionsBudgetID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Promotional credit granted"
+	return NewEntryBuilder().
+		AddDebit(promotionalCreditID, amount, "USDC", &desc).
+		AddCredit(promotionsBudgetID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreatePromotionVestEntries creates entries for vesting promotional credit
+// User's promotional credit decreases, USDC balance increases
+func CreatePromotionVestEntries(promotionalCreditID, usdcBalanceID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Promotional credit vested"
+	return NewEntryBuilder().
+		AddCredit(promotionalCreditID, amount, "USDC", &desc).
+		AddDebit(usdcBalanceID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreatePromotionClawbackEntries creates entries for forfeiting unvested credit
+// User's promotional credit decreases, marketing budget increases
+func CreatePromotionClawbackEntries(promotionalCreditID, promotionsBudgetID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Promotional credit forfeited"
+	return NewEntryBuilder().
+		AddCredit(promotionalCreditID, amount, "USDC", &desc).
+		AddDebit(promotionsBudgetID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreateRewardEarnEntries creates entries for a reward earned on an order
+// Rewards budget decreases, user's rewards balance increases
+func CreateRewardEarnEntries(rewardsBalanceID, rewardsBudgetID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Reward earned"
+	return NewEntryBuilder().
+		AddDebit(rewardsBalanceID, amount, "USDC", &desc).
+		AddCredit(rewardsBudgetID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreateRewardRedeemEntries creates entries for redeeming rewards into an
+// investment. User's rewards balance decreases, USDC balance increases
+func CreateRewardRedeemEntries(rewardsBalanceID, usdcBalanceID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Rewards redeemed"
+	return NewEntryBuilder().
+		AddCredit(rewardsBalanceID, amount, "USDC", &desc).
+		AddDebit(usdcBalanceID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreateRewardClawbackEntries creates entries for forfeiting a reward
+// User's rewards balance decreases, rewards budget increases
+func CreateRewardClawbackEntries(rewardsBalanceID, rewardsBudgetID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Reward forfeited"
+	return NewEntryBuilder().
+		AddCredit(rewardsBalanceID, amount, "USDC", &desc).
+		AddDebit(rewardsBudgetID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreateSubscriptionFeeEntries creates entries for a subscription fee paid
+// from cash. User's USDC balance decreases, fee revenue increases
+func CreateSubscriptionFeeEntries(usdcBalanceID, feeRevenueID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Subscription fee"
+	return NewEntryBuilder().
+		AddCredit(usdcBalanceID, amount, "USDC", &desc).
+		AddDebit(feeRevenueID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreateHoldEntries creates entries for holding funds for a pending order or
+// withdrawal. User's USDC balance decreases, held balance increases
+func CreateHoldEntries(usdcBalanceID, heldBalanceID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Funds held for pending operation"
+	return NewEntryBuilder().
+		AddCredit(usdcBalanceID, amount, "USDC", &desc).
+		AddDebit(heldBalanceID, amount, "USDC", &desc).
+		Build()
+}
+
+// CreateHoldReleaseEntries creates entries for releasing held funds
+// User's held balance decreases, USDC balance increases
+func CreateHoldReleaseEntries(heldBalanceID, usdcBalanceID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest {
+	desc := "Held funds released"
+	return NewEntryBuilder().
+		AddCredit(heldBalanceID, amount, "USDC", &desc).
+		AddDebit(usdcBalanceID, amount, "USDC", &desc).
+		Build()
+}
+
 // TransactionRequestBuilder helps construct complete transaction requests
 type TransactionRequestBuilder struct {
 	req *entities.CreateTransactionRequest
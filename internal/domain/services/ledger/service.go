@@ -1,15 +1,17 @@
 package ledger
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
 	"github.com/stack-service/stack_service/pkg/logger"
 )
@@ -19,6 +21,12 @@ type Service struct {
 	ledgerRepo *repositories.LedgerRepository
 	db         *sqlx.DB
 	logger     *logger.Logger
+	rates      RateLookup
+}
+
+// RateLookup values currencies as of a point in time
+type RateLookup interface {
+	RateAt(ctx context.Context, pair entities.CurrencyPair, at time.Time) (decimal.Decimal, error)
 }
 
 // NewService creates a new ledger service
@@ -34,6 +42,12 @@ func NewService(
 	}
 }
 
+// SetRateLookup stamps each non-USD entry with its USD rate at posting, so
+// reports can value entries as of the transaction
+func (s *Service) SetRateLookup(rates RateLookup) {
+	s.rates = rates
+}
+
 // CreateTransaction creates a new ledger transaction with entries atomically
 // This is the core operation that ensures double-entry bookkeeping integrity
 func (s *Service) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
@@ -42,26 +56,66 @@ func (s *Service) CreateTransaction(ctx context.Context, req *entities.CreateTra
 		return nil, fmt.Errorf("validate request: %w", err)
 	}
 
-	// Check for idempotency
-	existing, err := s.ledgerRepo.GetTransactionByIdempotencyKey(ctx, req.IdempotencyKey)
+	// Rates are looked up before the database transaction, which they may
+	// otherwise hold open while the rate provider is called
+	usdRates := s.usdRates(ctx, req.Entries, time.Now())
+
+	// The idempotency check runs inside the transaction, so a concurrent post
+	// with the same key that wins the race is found when this one retries
+	var ledgerTx *entities.LedgerTransaction
+	var existing bool
+	post := func(txCtx context.Context) error {
+		found, err := s.ledgerRepo.GetTransactionByIdempotencyKey(txCtx, req.IdempotencyKey)
+		if err != nil {
+			return fmt.Errorf("check idempotency: %w", err)
+		}
+		if found != nil {
+			ledgerTx, existing = found, true
+			return nil
+		}
+		ledgerTx, err = s.postTransaction(txCtx, req, usdRates)
+		return err
+	}
+
+	// A posting made as part of another ledger operation joins its transaction
+	var err error
+	if _, ok := repositories.LedgerTxFromContext(ctx); ok {
+		err = post(ctx)
+	} else {
+		err = database.WithSerializableTransactionx(ctx, s.db, "ledger_create_transaction", func(tx *sqlx.Tx) error {
+			return post(repositories.WithLedgerTx(ctx, tx))
+		})
+	}
 	if err != nil {
-		return nil, fmt.Errorf("check idempotency: %w", err)
+		return nil, err
 	}
-	if existing != nil {
+
+	if existing {
 		s.logger.Info("Transaction already exists (idempotent)",
 			"idempotency_key", req.IdempotencyKey,
-			"transaction_id", existing.ID)
-		return existing, nil
+			"transaction_id", ledgerTx.ID)
+		return ledgerTx, nil
 	}
 
-	// Begin database transaction
-	tx, err := s.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		return nil, fmt.Errorf("begin transaction: %w", err)
+	s.logger.Info("Ledger transaction created successfully",
+		"transaction_id", ledgerTx.ID,
+		"type", ledgerTx.TransactionType,
+		"user_id", ledgerTx.UserID)
+
+	return ledgerTx, nil
+}
+
+// postTransaction writes the transaction, its entries and the account
+// balances they move. ctx must carry the ledger transaction; it may run more
+// than once when a serialization conflict is retried.
+func (s *Service) postTransaction(ctx context.Context, req *entities.CreateTransactionRequest, usdRates map[string]decimal.Decimal) (*entities.LedgerTransaction, error) {
+	// Lock every account in a fixed order before moving balances, so two
+	// postings touching the same accounts wait on each other instead of
+	// deadlocking
+	if err := s.lockAccounts(ctx, req.Entries); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	// Create ledger transaction record
 	now := time.Now()
 	ledgerTx := &entities.LedgerTransaction{
 		ID:              uuid.New(),
@@ -76,10 +130,7 @@ func (s *Service) CreateTransaction(ctx context.Context, req *entities.CreateTra
 		CreatedAt:       now,
 	}
 
-	// Use transaction context for all operations
-	txCtx := context.WithValue(ctx, "db_tx", tx)
-
-	if err := s.ledgerRepo.CreateTransaction(txCtx, ledgerTx); err != nil {
+	if err := s.ledgerRepo.CreateTransaction(ctx, ledgerTx); err != nil {
 		return nil, fmt.Errorf("create transaction: %w", err)
 	}
 
@@ -96,40 +147,91 @@ func (s *Service) CreateTransaction(ctx context.Context, req *entities.CreateTra
 			Metadata:      entryReq.Metadata,
 			CreatedAt:     now,
 		}
+		if rate, ok := usdRates[entryReq.Currency]; ok {
+			entry.Metadata = withUSDRate(entryReq.Metadata, rate)
+		}
 
-		if err := s.ledgerRepo.CreateEntry(txCtx, entry); err != nil {
+		if err := s.ledgerRepo.CreateEntry(ctx, entry); err != nil {
 			return nil, fmt.Errorf("create entry: %w", err)
 		}
 
 		// Update account balance
-		if err := s.updateAccountBalanceInTx(txCtx, entryReq.AccountID, entryReq.EntryType, entryReq.Amount); err != nil {
+		if err := s.updateAccountBalanceInTx(ctx, entryReq.AccountID, entryReq.EntryType, entryReq.Amount); err != nil {
 			return nil, fmt.Errorf("update account balance: %w", err)
 		}
 	}
 
 	// Mark transaction as completed
 	ledgerTx.MarkCompleted()
-	if err := s.ledgerRepo.UpdateTransactionStatus(txCtx, ledgerTx.ID, entities.TransactionStatusCompleted); err != nil {
+	if err := s.ledgerRepo.UpdateTransactionStatus(ctx, ledgerTx.ID, entities.TransactionStatusCompleted); err != nil {
 		return nil, fmt.Errorf("update transaction status: %w", err)
 	}
 
-	// Commit database transaction
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("commit transaction: %w", err)
+	return ledgerTx, nil
+}
+
+// lockAccounts takes the row lock on each account the entries post to, in
+// account ID order
+func (s *Service) lockAccounts(ctx context.Context, entries []entities.CreateEntryRequest) error {
+	ids := make([]uuid.UUID, 0, len(entries))
+	seen := make(map[uuid.UUID]bool, len(entries))
+	for _, entry := range entries {
+		if !seen[entry.AccountID] {
+			seen[entry.AccountID] = true
+			ids = append(ids, entry.AccountID)
+		}
 	}
+	sort.Slice(ids, func(i, j int) bool {
+		return bytes.Compare(ids[i][:], ids[j][:]) < 0
+	})
 
-	s.logger.Info("Ledger transaction created successfully",
-		"transaction_id", ledgerTx.ID,
-		"type", ledgerTx.TransactionType,
-		"user_id", ledgerTx.UserID)
+	for _, id := range ids {
+		if _, err := s.ledgerRepo.GetAccountBalanceForUpdate(ctx, id); err != nil {
+			return fmt.Errorf("lock account %s: %w", id, err)
+		}
+	}
+	return nil
+}
 
-	return ledgerTx, nil
+// usdRates looks up the USD rate of each non-USD currency in the entries.
+// Currencies without a rate are left unstamped rather than failing the post.
+func (s *Service) usdRates(ctx context.Context, entries []entities.CreateEntryRequest, at time.Time) map[string]decimal.Decimal {
+	if s.rates == nil {
+		return nil
+	}
+	rates := make(map[string]decimal.Decimal)
+	for _, entry := range entries {
+		if entry.Currency == "" || entry.Currency == "USD" {
+			continue
+		}
+		if _, seen := rates[entry.Currency]; seen {
+			continue
+		}
+		rate, err := s.rates.RateAt(ctx, entities.NewCurrencyPair(entry.Currency, "USD"), at)
+		if err != nil {
+			s.logger.Warn("No USD rate for ledger entry", "currency", entry.Currency, "error", err)
+			continue
+		}
+		rates[entry.Currency] = rate
+	}
+	return rates
 }
 
-// updateAccountBalanceInTx updates an account balance within a database transaction
+func withUSDRate(metadata map[string]any, rate decimal.Decimal) map[string]any {
+	stamped := make(map[string]any, len(metadata)+1)
+	for key, value := range metadata {
+		stamped[key] = value
+	}
+	stamped["usd_rate"] = rate.String()
+	return stamped
+}
+
+// updateAccountBalanceInTx updates an account balance within a database
+// transaction. The balance is read under the account's row lock so a
+// concurrent posting cannot overwrite it between the read and the write.
 func (s *Service) updateAccountBalanceInTx(ctx context.Context, accountID uuid.UUID, entryType entities.EntryType, amount decimal.Decimal) error {
 	// Get current balance
-	currentBalance, err := s.ledgerRepo.GetAccountBalance(ctx, accountID)
+	currentBalance, err := s.ledgerRepo.GetAccountBalanceForUpdate(ctx, accountID)
 	if err != nil {
 		return fmt.Errorf("get account balance: %w", err)
 	}
@@ -192,9 +294,9 @@ func (s *Service) GetSystemBuffers(ctx context.Context) (*entities.SystemBuffers
 // GetOrCreateUserAccount ensures a user account exists
 func (s *Service) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
 	// Determine currency based on account type
-	currency := "USDC"
+	currency := string(entities.CurrencyUSDC)
 	if accountType == entities.AccountTypeFiatExposure {
-		currency = "USD"
+		currency = string(entities.CurrencyUSD)
 	}
 
 	account, err := s.ledgerRepo.GetOrCreateUserAccount(ctx, userID, accountType, currency)
@@ -215,8 +317,14 @@ func (s *Service) GetSystemAccount(ctx context.Context, accountType entities.Acc
 	return account, nil
 }
 
-// ReserveForInvestment reserves funds for an investment by moving from usdc_balance to pending_investment
-func (s *Service) ReserveForInvestment(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+// ReserveForInvestment reserves funds for an investment by moving from usdc_balance to pending_investment.
+// The amount must be in USDC.
+func (s *Service) ReserveForInvestment(ctx context.Context, userID uuid.UUID, reserve entities.Money) error {
+	if reserve.Currency != entities.CurrencyUSDC {
+		return fmt.Errorf("reserve %s: %w", reserve, entities.ErrCurrencyMismatch)
+	}
+	amount := reserve.Amount
+
 	// Get user accounts
 	usdcAccount, err := s.GetOrCreateUserAccount(ctx, userID, entities.AccountTypeUSDCBalance)
 	if err != nil {
@@ -229,7 +337,10 @@ func (s *Service) ReserveForInvestment(ctx context.Context, userID uuid.UUID, am
 	}
 
 	// Check sufficient balance
-	if usdcAccount.Balance.LessThan(amount) {
+	available := entities.NewMoney(usdcAccount.Balance, entities.Currency(usdcAccount.Currency))
+	if cmp, err := available.Cmp(reserve); err != nil {
+		return fmt.Errorf("check usdc balance: %w", err)
+	} else if cmp < 0 {
 		return fmt.Errorf("insufficient USDC balance: have %s, need %s",
 			usdcAccount.Balance.String(), amount.String())
 	}
@@ -248,14 +359,14 @@ func (s *Service) ReserveForInvestment(ctx context.Context, userID uuid.UUID, am
 				AccountID:   usdcAccount.ID,
 				EntryType:   entities.EntryTypeCredit,
 				Amount:      amount,
-				Currency:    "USDC",
+				Currency:    string(entities.CurrencyUSDC),
 				Description: &desc,
 			},
 			{
 				AccountID:   pendingAccount.ID,
 				EntryType:   entities.EntryTypeDebit,
 				Amount:      amount,
-				Currency:    "USDC",
+				Currency:    string(entities.CurrencyUSDC),
 				Description: &desc,
 			},
 		},
@@ -268,13 +379,19 @@ func (s *Service) ReserveForInvestment(ctx context.Context, userID uuid.UUID, am
 
 	s.logger.Info("Funds reserved for investment",
 		"user_id", userID,
-		"amount", amount.String())
+		"amount", logger.RedactAmount(amount))
 
 	return nil
 }
 
-// ReleaseReservation releases reserved funds back to usdc_balance (e.g., on trade cancellation)
-func (s *Service) ReleaseReservation(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+// ReleaseReservation releases reserved funds back to usdc_balance (e.g., on trade cancellation).
+// The amount must be in USDC.
+func (s *Service) ReleaseReservation(ctx context.Context, userID uuid.UUID, release entities.Money) error {
+	if release.Currency != entities.CurrencyUSDC {
+		return fmt.Errorf("release %s: %w", release, entities.ErrCurrencyMismatch)
+	}
+	amount := release.Amount
+
 	// Get user accounts
 	usdcAccount, err := s.GetOrCreateUserAccount(ctx, userID, entities.AccountTypeUSDCBalance)
 	if err != nil {
@@ -287,7 +404,10 @@ func (s *Service) ReleaseReservation(ctx context.Context, userID uuid.UUID, amou
 	}
 
 	// Check sufficient pending balance
-	if pendingAccount.Balance.LessThan(amount) {
+	pending := entities.NewMoney(pendingAccount.Balance, entities.Currency(pendingAccount.Currency))
+	if cmp, err := pending.Cmp(release); err != nil {
+		return fmt.Errorf("check pending balance: %w", err)
+	} else if cmp < 0 {
 		return fmt.Errorf("insufficient pending balance: have %s, need %s",
 			pendingAccount.Balance.String(), amount.String())
 	}
@@ -306,14 +426,14 @@ func (s *Service) ReleaseReservation(ctx context.Context, userID uuid.UUID, amou
 				AccountID:   pendingAccount.ID,
 				EntryType:   entities.EntryTypeCredit,
 				Amount:      amount,
-				Currency:    "USDC",
+				Currency:    string(entities.CurrencyUSDC),
 				Description: &desc,
 			},
 			{
 				AccountID:   usdcAccount.ID,
 				EntryType:   entities.EntryTypeDebit,
 				Amount:      amount,
-				Currency:    "USDC",
+				Currency:    string(entities.CurrencyUSDC),
 				Description: &desc,
 			},
 		},
@@ -326,7 +446,7 @@ func (s *Service) ReleaseReservation(ctx context.Context, userID uuid.UUID, amou
 
 	s.logger.Info("Reserved funds released",
 		"user_id", userID,
-		"amount", amount.String())
+		"amount", logger.RedactAmount(amount))
 
 	return nil
 }
@@ -349,20 +469,6 @@ func (s *Service) ReverseTransaction(ctx context.Context, originalTxID uuid.UUID
 		return fmt.Errorf("get original entries: %w", err)
 	}
 
-	// Begin database transaction for atomicity
-	tx, err := s.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	txCtx := context.WithValue(ctx, "db_tx", tx)
-
-	// Mark original transaction as reversed first
-	if err := s.ledgerRepo.UpdateTransactionStatus(txCtx, originalTxID, entities.TransactionStatusReversed); err != nil {
-		return fmt.Errorf("update original transaction status: %w", err)
-	}
-
 	// Create reversal entries (flip debit/credit)
 	reversalEntries := make([]entities.CreateEntryRequest, len(entries))
 	for i, entry := range entries {
@@ -383,7 +489,7 @@ func (s *Service) ReverseTransaction(ctx context.Context, originalTxID uuid.UUID
 		}
 	}
 
-	// Create reversal transaction within same db transaction
+	// Create reversal transaction
 	idempotencyKey := fmt.Sprintf("reversal-%s", originalTxID.String())
 	desc := fmt.Sprintf("Reversal: %s", reason)
 
@@ -396,15 +502,23 @@ func (s *Service) ReverseTransaction(ctx context.Context, originalTxID uuid.UUID
 		Entries:         reversalEntries,
 	}
 
-	// Note: CreateTransaction will use the existing tx from context
-	_, err = s.CreateTransaction(txCtx, req)
-	if err != nil {
-		return fmt.Errorf("create reversal transaction: %w", err)
-	}
+	// Marking the original reversed and posting the reversal commit together
+	err = database.WithSerializableTransactionx(ctx, s.db, "ledger_reverse_transaction", func(tx *sqlx.Tx) error {
+		txCtx := repositories.WithLedgerTx(ctx, tx)
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit reversal: %w", err)
+		// Mark original transaction as reversed first
+		if err := s.ledgerRepo.UpdateTransactionStatus(txCtx, originalTxID, entities.TransactionStatusReversed); err != nil {
+			return fmt.Errorf("update original transaction status: %w", err)
+		}
+
+		// CreateTransaction joins the transaction carried by txCtx
+		if _, err := s.CreateTransaction(txCtx, req); err != nil {
+			return fmt.Errorf("create reversal transaction: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	s.logger.Info("Transaction reversed",
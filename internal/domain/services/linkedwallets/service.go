@@ -0,0 +1,366 @@
+package linkedwallets
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/adapters/due"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+var (
+	// ErrNoDueAccount is returned when the user has no Due account to link wallets to
+	ErrNoDueAccount = errors.New("your account is not set up for linked wallets yet")
+	// ErrUnsupportedChain is returned for chains wallets cannot be linked on
+	ErrUnsupportedChain = errors.New("wallets cannot be linked on this chain")
+	// ErrInvalidAddress is returned for addresses malformed on their chain
+	ErrInvalidAddress = errors.New("address is not valid on this chain")
+	// ErrChallengeInvalid is returned when the signed message is not a
+	// current challenge issued to this user for this address
+	ErrChallengeInvalid = errors.New("the ownership challenge is invalid or has expired; request a new one")
+	// ErrSignatureInvalid is returned when the signature was not made by the address
+	ErrSignatureInvalid = errors.New("the signature does not prove ownership of this address")
+	// ErrPlatformWallet is returned when unlinking a wallet we created for the user
+	ErrPlatformWallet = errors.New("wallets created for you by the platform cannot be unlinked")
+)
+
+// Repository persists the mirror of linked wallets
+type Repository interface {
+	Create(ctx context.Context, wallet *entities.LinkedWallet) error
+	Get(ctx context.Context, userID, id uuid.UUID) (*entities.LinkedWallet, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.LinkedWallet, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	Sync(ctx context.Context, userID uuid.UUID, wallets []*entities.LinkedWallet) error
+}
+
+// DueWallets manages the wallets on a user's Due account
+type DueWallets interface {
+	ListAccountWallets(ctx context.Context, accountID string) (*due.ListWalletsResponse, error)
+	LinkAccountWallet(ctx context.Context, accountID string, req *due.LinkWalletRequest) (*due.LinkWalletResponse, error)
+	UnlinkAccountWallet(ctx context.Context, accountID, walletID string) error
+}
+
+// Users looks up the user's Due account
+type Users interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error)
+}
+
+// Config holds linked wallet settings
+type Config struct {
+	ChallengeSecret []byte        // Keys the challenge nonce so challenges need no storage
+	ChallengeTTL    time.Duration // How long a challenge may be signed and submitted
+}
+
+// Service lists, links and unlinks the wallets on users' Due accounts
+type Service struct {
+	repo   Repository
+	due    DueWallets
+	users  Users
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new linked wallet service
+func NewService(repo Repository, dueWallets DueWallets, users Users, config Config, logger *zap.Logger) *Service {
+	if config.ChallengeTTL <= 0 {
+		config.ChallengeTTL = 10 * time.Minute
+	}
+	return &Service{
+		repo:   repo,
+		due:    dueWallets,
+		users:  users,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// List returns the user's linked wallets, first bringing the mirror up to
+// date with Due. If Due cannot be reached the last known list is returned.
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*entities.LinkedWallet, error) {
+	accountID, err := s.dueAccount(ctx, userID)
+	if errors.Is(err, ErrNoDueAccount) {
+		return s.repo.ListByUser(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.due.ListAccountWallets(ctx, accountID)
+	if err != nil {
+		s.logger.Warn("Failed to list Due wallets, returning stored list",
+			zap.String("user_id", userID.String()), zap.Error(err))
+		return known, nil
+	}
+
+	if err := s.repo.Sync(ctx, userID, s.reconcile(userID, known, remote.Data)); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// reconcile builds the mirror from Due's list, keeping what we know about
+// wallets already stored and inferring the chain of ones linked elsewhere
+func (s *Service) reconcile(userID uuid.UUID, known []*entities.LinkedWallet, remote []due.LinkWalletResponse) []*entities.LinkedWallet {
+	byDueID := make(map[string]*entities.LinkedWallet, len(known))
+	byAddress := make(map[string]*entities.LinkedWallet, len(known))
+	for _, wallet := range known {
+		byDueID[wallet.DueWalletID] = wallet
+		byAddress[strings.ToLower(wallet.Address)] = wallet
+	}
+
+	now := s.now()
+	wallets := make([]*entities.LinkedWallet, 0, len(remote))
+	for _, r := range remote {
+		schema, address, _ := strings.Cut(r.Address, ":")
+		if address == "" {
+			schema, address = "", r.Address
+		}
+
+		wallet := byDueID[r.ID]
+		if wallet == nil {
+			wallet = byAddress[strings.ToLower(address)]
+		}
+		if wallet != nil {
+			synced := *wallet
+			synced.DueWalletID = r.ID
+			synced.UpdatedAt = now
+			wallets = append(wallets, &synced)
+			continue
+		}
+
+		chain, ok := chainForDueWallet(r.Blockchain, schema)
+		if !ok {
+			s.logger.Warn("Skipping Due wallet on an unknown chain",
+				zap.String("user_id", userID.String()),
+				zap.String("due_wallet_id", r.ID),
+				zap.String("blockchain", r.Blockchain))
+			continue
+		}
+		created := r.CreatedAt
+		if created.IsZero() {
+			created = now
+		}
+		wallets = append(wallets, &entities.LinkedWallet{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Chain:       chain,
+			Address:     address,
+			DueWalletID: r.ID,
+			Source:      entities.LinkedWalletSourcePlatform,
+			CreatedAt:   created,
+			UpdatedAt:   now,
+		})
+	}
+	return wallets
+}
+
+// chainForDueWallet maps the chain Due reports to one of ours, falling back
+// to the first enabled chain of the address schema's family
+func chainForDueWallet(blockchain, schema string) (entities.WalletChain, bool) {
+	if info, ok := entities.Chains().Lookup(blockchain); ok {
+		return info.ID, true
+	}
+	for _, info := range entities.Chains().Enabled() {
+		if schema != "" && info.Family.DueSchema() == schema {
+			return info.ID, true
+		}
+	}
+	return "", false
+}
+
+// Challenge issues the message the user signs with the wallet to prove they
+// control the address
+func (s *Service) Challenge(ctx context.Context, userID uuid.UUID, req *entities.WalletChallengeRequest) (*entities.WalletChallenge, error) {
+	info, err := linkableChain(req.Chain, req.Address)
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := s.now().UTC().Truncate(time.Second)
+	return &entities.WalletChallenge{
+		Chain:     info.ID,
+		Address:   req.Address,
+		Message:   s.challengeMessage(userID, info.ID, req.Address, issuedAt),
+		ExpiresAt: issuedAt.Add(s.config.ChallengeTTL),
+	}, nil
+}
+
+// Link links a self-custody address to the user's Due account once the
+// signature of a current challenge proves the user controls it
+func (s *Service) Link(ctx context.Context, userID uuid.UUID, req *entities.LinkExternalWalletRequest) (*entities.LinkedWallet, error) {
+	info, err := linkableChain(req.Chain, req.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkChallenge(userID, info.ID, req.Address, req.Message); err != nil {
+		return nil, err
+	}
+	if !verifySignature(info.Family, req.Address, req.Message, req.Signature) {
+		return nil, ErrSignatureInvalid
+	}
+
+	accountID, err := s.dueAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range existing {
+		if strings.EqualFold(wallet.Address, req.Address) {
+			return nil, entities.ErrLinkedWalletExists
+		}
+	}
+
+	linked, err := s.due.LinkAccountWallet(ctx, accountID, &due.LinkWalletRequest{
+		Address: fmt.Sprintf("%s:%s", info.Family.DueSchema(), req.Address),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link wallet with Due: %w", err)
+	}
+
+	now := s.now()
+	wallet := &entities.LinkedWallet{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Chain:       info.ID,
+		Address:     req.Address,
+		DueWalletID: linked.ID,
+		Source:      entities.LinkedWalletSourceSelfCustody,
+		Label:       req.Label,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, wallet); err != nil {
+		// Keep Due and the mirror in step: undo the link we could not record
+		if unlinkErr := s.due.UnlinkAccountWallet(ctx, accountID, linked.ID); unlinkErr != nil {
+			s.logger.Error("Failed to roll back Due wallet link",
+				zap.String("user_id", userID.String()),
+				zap.String("due_wallet_id", linked.ID),
+				zap.Error(unlinkErr))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Linked self-custody wallet",
+		zap.String("user_id", userID.String()),
+		zap.String("chain", string(info.ID)),
+		zap.String("due_wallet_id", linked.ID))
+	return wallet, nil
+}
+
+// Unlink removes a self-custody wallet from the user's Due account
+func (s *Service) Unlink(ctx context.Context, userID, id uuid.UUID) (*entities.LinkedWallet, error) {
+	wallet, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Source == entities.LinkedWalletSourcePlatform {
+		return nil, ErrPlatformWallet
+	}
+	accountID, err := s.dueAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.due.UnlinkAccountWallet(ctx, accountID, wallet.DueWalletID); err != nil {
+		return nil, fmt.Errorf("failed to unlink wallet with Due: %w", err)
+	}
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func (s *Service) dueAccount(ctx context.Context, userID uuid.UUID) (string, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.DueAccountID == nil || *user.DueAccountID == "" {
+		return "", ErrNoDueAccount
+	}
+	return *user.DueAccountID, nil
+}
+
+func linkableChain(chain, address string) (entities.ChainInfo, error) {
+	info, ok := entities.Chains().Lookup(chain)
+	if !ok || !info.Enabled || info.Family.DueSchema() == "" {
+		return entities.ChainInfo{}, ErrUnsupportedChain
+	}
+	if !info.ValidAddress(address) {
+		return entities.ChainInfo{}, ErrInvalidAddress
+	}
+	return info, nil
+}
+
+const challengeIssuedPrefix = "Issued At: "
+
+// challengeMessage is the text the wallet signs. The nonce is keyed with the
+// challenge secret over everything else in the message, so a challenge can
+// be checked without having been stored.
+func (s *Service) challengeMessage(userID uuid.UUID, chain entities.WalletChain, address string, issuedAt time.Time) string {
+	issued := issuedAt.UTC().Format(time.RFC3339)
+	mac := hmac.New(sha256.New, s.config.ChallengeSecret)
+	fmt.Fprintf(mac, "%s|%s|%s|%s", userID, chain, strings.ToLower(address), issued)
+	return fmt.Sprintf("Sign this message to link your wallet to your Stack account.\n\n"+
+		"Chain: %s\nAddress: %s\nUser: %s\n%s%s\nNonce: %s",
+		chain, address, userID, challengeIssuedPrefix, issued, hex.EncodeToString(mac.Sum(nil))[:32])
+}
+
+func (s *Service) checkChallenge(userID uuid.UUID, chain entities.WalletChain, address, message string) error {
+	var issuedAt time.Time
+	for _, line := range strings.Split(message, "\n") {
+		if value, ok := strings.CutPrefix(line, challengeIssuedPrefix); ok {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return ErrChallengeInvalid
+			}
+			issuedAt = parsed
+		}
+	}
+	if issuedAt.IsZero() {
+		return ErrChallengeInvalid
+	}
+
+	expected := s.challengeMessage(userID, chain, address, issuedAt)
+	if !hmac.Equal([]byte(expected), []byte(message)) {
+		return ErrChallengeInvalid
+	}
+	now := s.now()
+	if now.After(issuedAt.Add(s.config.ChallengeTTL)) || issuedAt.After(now.Add(time.Minute)) {
+		return ErrChallengeInvalid
+	}
+	return nil
+}
+
+func verifySignature(family entities.ChainFamily, address, message, signature string) bool {
+	switch family {
+	case entities.ChainFamilyEVM:
+		return crypto.VerifyEVMSignature(address, message, signature)
+	case entities.ChainFamilySolana:
+		return crypto.VerifySolanaSignature(address, message, signature)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,233 @@
+package marketcalendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	_ "time/tzdata" // sessions are dated in America/New_York
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrNoSession is returned when no trading session is found within the
+// look-ahead window, which only happens with a broken calendar
+var ErrNoSession = errors.New("no market session found in the next two weeks")
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+
+	regularOpen  = "09:30"
+	regularClose = "16:00"
+
+	// lookahead bounds the search for the next session or scheduled run
+	lookahead = 14
+)
+
+var marketTimezone = mustLoadLocation("America/New_York")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// Source lists the trading sessions between two dates, inclusive
+type Source interface {
+	GetSessions(ctx context.Context, start, end time.Time) ([]entities.MarketSession, error)
+}
+
+// Config configures the market calendar
+type Config struct {
+	RefreshInterval time.Duration // How long a loaded year of sessions is reused
+}
+
+// DefaultConfig reloads the calendar daily
+func DefaultConfig() Config {
+	return Config{RefreshInterval: 24 * time.Hour}
+}
+
+// yearSessions is one calendar year of sessions keyed by date
+type yearSessions struct {
+	sessions map[string]entities.MarketSession
+	loadedAt time.Time
+}
+
+// Service answers when the US equities market trades: which days are
+// sessions, when each opens and closes, and when a user's scheduled feature
+// should next run in their own time zone. Sessions are loaded a year at a
+// time from the source. When the source fails the last loaded year is kept,
+// and without one every weekday is treated as a regular session.
+type Service struct {
+	source Source
+	config Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	years map[int]*yearSessions
+}
+
+// NewService creates a market calendar service
+func NewService(source Source, config Config, logger *zap.Logger) *Service {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultConfig().RefreshInterval
+	}
+	return &Service{
+		source: source,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+		years:  make(map[int]*yearSessions),
+	}
+}
+
+// NewSession builds a session from a New York date and "15:04" open and close
+// times, as exchange calendars publish them
+func NewSession(date, open, close string) (entities.MarketSession, error) {
+	day, err := time.ParseInLocation(dateLayout, date, marketTimezone)
+	if err != nil {
+		return entities.MarketSession{}, fmt.Errorf("invalid session date %q: %w", date, err)
+	}
+	openAt, err := sessionTime(day, open)
+	if err != nil {
+		return entities.MarketSession{}, err
+	}
+	closeAt, err := sessionTime(day, close)
+	if err != nil {
+		return entities.MarketSession{}, err
+	}
+	regularCloseAt, _ := sessionTime(day, regularClose)
+	return entities.MarketSession{
+		Date:       date,
+		OpenAt:     openAt.UTC(),
+		CloseAt:    closeAt.UTC(),
+		EarlyClose: closeAt.Before(regularCloseAt),
+	}, nil
+}
+
+func sessionTime(day time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid session time %q: %w", clock, err)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, marketTimezone), nil
+}
+
+// Session returns the session on the New York date containing day, or nil
+// when the market is closed that day
+func (s *Service) Session(ctx context.Context, day time.Time) (*entities.MarketSession, error) {
+	local := day.In(marketTimezone)
+	sessions, err := s.year(ctx, local.Year())
+	if err != nil {
+		return nil, err
+	}
+	session, ok := sessions[local.Format(dateLayout)]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// IsTradingDay reports whether the market has a session on day's New York date
+func (s *Service) IsTradingDay(ctx context.Context, day time.Time) (bool, error) {
+	session, err := s.Session(ctx, day)
+	if err != nil {
+		return false, err
+	}
+	return session != nil, nil
+}
+
+// NextSession returns the session in progress at after, or the next one to
+// open
+func (s *Service) NextSession(ctx context.Context, after time.Time) (*entities.MarketSession, error) {
+	local := after.In(marketTimezone)
+	for i := 0; i < lookahead; i++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+i, 12, 0, 0, 0, marketTimezone)
+		session, err := s.Session(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil && after.Before(session.CloseAt) {
+			return session, nil
+		}
+	}
+	return nil, ErrNoSession
+}
+
+// NextLocalRun returns the first time after after that is hour:minute on the
+// clock in loc and falls on a trading day, for features such as recurring
+// investments, weekly summaries and alerts that should reach users at a
+// sensible local time and never on a market holiday. The trading day is
+// matched by calendar date, so a user ahead of New York is never scheduled on
+// the local date of a holiday.
+func (s *Service) NextLocalRun(ctx context.Context, loc *time.Location, hour, minute int, after time.Time) (time.Time, error) {
+	local := after.In(loc)
+	for i := 0; i <= lookahead; i++ {
+		run := time.Date(local.Year(), local.Month(), local.Day()+i, hour, minute, 0, 0, loc)
+		if !run.After(after) {
+			continue
+		}
+		trading, err := s.IsTradingDay(ctx, time.Date(run.Year(), run.Month(), run.Day(), 12, 0, 0, 0, marketTimezone))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if trading {
+			return run.UTC(), nil
+		}
+	}
+	return time.Time{}, ErrNoSession
+}
+
+// year returns the sessions of a calendar year, loading them when missing or
+// older than the refresh interval
+func (s *Service) year(ctx context.Context, year int) (map[string]entities.MarketSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, ok := s.years[year]
+	if ok && s.now().Sub(cached.loadedAt) < s.config.RefreshInterval {
+		return cached.sessions, nil
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, marketTimezone)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, marketTimezone)
+	loaded, err := s.source.GetSessions(ctx, start, end)
+	if err != nil {
+		if ok {
+			s.logger.Warn("Failed to refresh market calendar, keeping the loaded one",
+				zap.Int("year", year), zap.Error(err))
+			return cached.sessions, nil
+		}
+		s.logger.Warn("Failed to load market calendar, assuming regular weekday sessions",
+			zap.Int("year", year), zap.Error(err))
+		return weekdaySessions(year), nil
+	}
+
+	sessions := make(map[string]entities.MarketSession, len(loaded))
+	for _, session := range loaded {
+		sessions[session.Date] = session
+	}
+	s.years[year] = &yearSessions{sessions: sessions, loadedAt: s.now()}
+	return sessions, nil
+}
+
+// weekdaySessions treats every weekday of a year as a regular session
+func weekdaySessions(year int) map[string]entities.MarketSession {
+	sessions := make(map[string]entities.MarketSession, 262)
+	for day := time.Date(year, time.January, 1, 12, 0, 0, 0, marketTimezone); day.Year() == year; day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		date := day.Format(dateLayout)
+		session, _ := NewSession(date, regularOpen, regularClose)
+		sessions[date] = session
+	}
+	return sessions
+}
@@ -0,0 +1,324 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var symbolPattern = regexp.MustCompile(`^[A-Z][A-Z0-9.]{0,9}$`)
+
+// Feed is an upstream streaming quote source. Run blocks, reconnecting as
+// needed, until ctx is done and returns an error only when streaming cannot
+// continue. SetSymbols replaces the subscribed symbols and must not block.
+type Feed interface {
+	Run(ctx context.Context, onQuote func(*entities.MarketQuote)) error
+	SetSymbols(symbols []string)
+}
+
+// Snapshots returns the latest quote for each symbol
+type Snapshots interface {
+	LatestQuotes(ctx context.Context, symbols []string) (map[string]*entities.MarketQuote, error)
+}
+
+// MarketHours returns the session in progress or the next one to open
+type MarketHours interface {
+	NextSession(ctx context.Context, after time.Time) (*entities.MarketSession, error)
+}
+
+// PositionRepository lists a user's basket positions
+type PositionRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error)
+}
+
+// BasketRepository loads basket compositions
+type BasketRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
+}
+
+// Config configures quote fan-out
+type Config struct {
+	MaxSymbols int           // Symbols one connection may subscribe to
+	Throttle   time.Duration // Minimum gap between updates sent to one connection
+	// RetryInterval is how long to wait after the feed fails, or to recheck
+	// the calendar when it cannot be read
+	RetryInterval time.Duration
+}
+
+// DefaultConfig returns the default fan-out configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxSymbols:    50,
+		Throttle:      time.Second,
+		RetryInterval: time.Minute,
+	}
+}
+
+// Service fans streaming quotes out to connected clients. The upstream feed
+// runs only during market sessions and is subscribed to the union of the
+// symbols clients are watching; each client receives the latest quote per
+// symbol at most once per throttle interval.
+type Service struct {
+	feed      Feed
+	snapshots Snapshots
+	hours     MarketHours
+	positions PositionRepository
+	baskets   BasketRepository
+	config    Config
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu       sync.RWMutex
+	watchers map[string]map[*Subscription]struct{}
+}
+
+// NewService creates a new market data service
+func NewService(feed Feed, positions PositionRepository, baskets BasketRepository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxSymbols <= 0 {
+		config.MaxSymbols = defaults.MaxSymbols
+	}
+	if config.Throttle <= 0 {
+		config.Throttle = defaults.Throttle
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaults.RetryInterval
+	}
+	return &Service{
+		feed:      feed,
+		positions: positions,
+		baskets:   baskets,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+		watchers:  make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// SetSnapshots sets the source of the quotes sent when a client connects
+func (s *Service) SetSnapshots(snapshots Snapshots) {
+	s.snapshots = snapshots
+}
+
+// SetMarketHours limits the upstream feed to market sessions
+func (s *Service) SetMarketHours(hours MarketHours) {
+	s.hours = hours
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Start runs the upstream feed in the background until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+// Subscribe registers a connection for quotes on symbols, or on the symbols
+// in the user's basket holdings when none are given. Close the subscription
+// when the connection ends.
+func (s *Service) Subscribe(ctx context.Context, userID uuid.UUID, symbols []string) (*Subscription, error) {
+	if len(symbols) == 0 {
+		held, err := s.holdings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		symbols = held
+	}
+	symbols, err := s.normalize(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := newSubscription(s, symbols, s.config.Throttle)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added := false
+	for _, symbol := range symbols {
+		if s.watchers[symbol] == nil {
+			s.watchers[symbol] = make(map[*Subscription]struct{})
+			added = true
+		}
+		s.watchers[symbol][sub] = struct{}{}
+	}
+	if added {
+		s.feed.SetSymbols(s.watchedLocked())
+	}
+	return sub, nil
+}
+
+// Snapshot returns the latest quotes for symbols and whether the market is
+// open. Snapshot failures leave the quotes empty rather than failing the
+// stream, since live updates follow.
+func (s *Service) Snapshot(ctx context.Context, symbols []string) *entities.QuoteSnapshot {
+	snapshot := &entities.QuoteSnapshot{Symbols: symbols, Quotes: []*entities.MarketQuote{}, MarketOpen: true}
+
+	if s.snapshots != nil {
+		latest, err := s.snapshots.LatestQuotes(ctx, symbols)
+		if err != nil {
+			s.logger.Warn("Failed to load quote snapshot", zap.Strings("symbols", symbols), zap.Error(err))
+		}
+		for _, symbol := range symbols {
+			if quote, ok := latest[symbol]; ok {
+				snapshot.Quotes = append(snapshot.Quotes, quote)
+			}
+		}
+	}
+
+	if s.hours != nil {
+		now := s.now()
+		session, err := s.hours.NextSession(ctx, now)
+		if err != nil {
+			s.logger.Warn("Failed to check market hours for quote snapshot", zap.Error(err))
+		} else if now.Before(session.OpenAt) {
+			snapshot.MarketOpen = false
+			snapshot.NextOpen = &session.OpenAt
+		}
+	}
+	return snapshot
+}
+
+func (s *Service) unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := false
+	for _, symbol := range sub.symbols {
+		delete(s.watchers[symbol], sub)
+		if len(s.watchers[symbol]) == 0 {
+			delete(s.watchers, symbol)
+			removed = true
+		}
+	}
+	if removed {
+		s.feed.SetSymbols(s.watchedLocked())
+	}
+}
+
+// publish hands a quote to every connection watching its symbol
+func (s *Service) publish(quote *entities.MarketQuote) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.watchers[quote.Symbol] {
+		sub.offer(quote)
+	}
+}
+
+func (s *Service) watchedLocked() []string {
+	symbols := make([]string, 0, len(s.watchers))
+	for symbol := range s.watchers {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+func (s *Service) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		until, wait := s.window(ctx)
+		if wait > 0 {
+			s.logger.Info("Market closed, pausing quote stream", zap.Duration("resumes_in", wait))
+			if !sleep(ctx, wait) {
+				return
+			}
+			continue
+		}
+
+		runCtx, cancel := context.WithDeadline(ctx, until)
+		err := s.feed.Run(runCtx, s.publish)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("Quote stream stopped", zap.Error(err), zap.Duration("retry_in", s.config.RetryInterval))
+			if !sleep(ctx, s.config.RetryInterval) {
+				return
+			}
+		}
+	}
+}
+
+// window returns when the feed should next stop, or how long to wait for
+// the market to open. When the calendar cannot be read the feed runs and
+// the calendar is checked again after the retry interval.
+func (s *Service) window(ctx context.Context) (time.Time, time.Duration) {
+	now := s.now()
+	if s.hours == nil {
+		return now.Add(24 * time.Hour), 0
+	}
+	session, err := s.hours.NextSession(ctx, now)
+	if err != nil {
+		s.logger.Warn("Failed to read market calendar, streaming quotes anyway", zap.Error(err))
+		return now.Add(s.config.RetryInterval), 0
+	}
+	if now.Before(session.OpenAt) {
+		return time.Time{}, session.OpenAt.Sub(now)
+	}
+	return session.CloseAt, 0
+}
+
+func (s *Service) holdings(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	positions, err := s.positions.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load positions: %w", err)
+	}
+
+	var symbols []string
+	for _, position := range positions {
+		if !position.Quantity.IsPositive() {
+			continue
+		}
+		basket, err := s.baskets.GetByID(ctx, position.BasketID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load basket %s: %w", position.BasketID, err)
+		}
+		for _, component := range basket.Composition {
+			symbols = append(symbols, component.Symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// normalize upper-cases and de-duplicates symbols, keeping their order
+func (s *Service) normalize(symbols []string) ([]string, error) {
+	seen := make(map[string]bool, len(symbols))
+	normalized := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		if !symbolPattern.MatchString(symbol) {
+			return nil, fmt.Errorf("%w: %q", entities.ErrInvalidSymbol, symbol)
+		}
+		seen[symbol] = true
+		normalized = append(normalized, symbol)
+	}
+	if len(normalized) == 0 {
+		return nil, entities.ErrNoSymbolsToWatch
+	}
+	if len(normalized) > s.config.MaxSymbols {
+		return nil, fmt.Errorf("%w: limit is %d", entities.ErrTooManySymbols, s.config.MaxSymbols)
+	}
+	return normalized, nil
+}
+
+// sleep waits for d and reports false if ctx ended first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
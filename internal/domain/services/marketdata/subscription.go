@@ -0,0 +1,97 @@
+package marketdata
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Subscription is one client connection's view of the quote stream. Quotes
+// are conflated per symbol, so a slow client receives the latest quote for
+// each symbol rather than a growing backlog.
+type Subscription struct {
+	service  *Service
+	symbols  []string
+	throttle time.Duration
+
+	mu       sync.Mutex
+	pending  map[string]*entities.MarketQuote
+	ready    chan struct{}
+	lastSent time.Time
+	closed   sync.Once
+}
+
+func newSubscription(service *Service, symbols []string, throttle time.Duration) *Subscription {
+	return &Subscription{
+		service:  service,
+		symbols:  symbols,
+		throttle: throttle,
+		pending:  make(map[string]*entities.MarketQuote),
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+// Symbols returns the symbols the connection receives quotes for
+func (s *Subscription) Symbols() []string {
+	return s.symbols
+}
+
+// Next waits up to wait for quotes and returns the latest one per symbol
+// received since the previous call, sorted by symbol. Calls are spaced at
+// least the throttle interval apart; an empty result means wait elapsed.
+func (s *Subscription) Next(ctx context.Context, wait time.Duration) ([]*entities.MarketQuote, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, nil
+	case <-s.ready:
+	}
+
+	// Hold back until the throttle interval has passed; quotes arriving
+	// meanwhile replace the pending ones
+	if delay := s.throttle - time.Since(s.lastSent); delay > 0 {
+		if !sleep(ctx, delay) {
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case <-s.ready:
+	default:
+	}
+	s.mu.Lock()
+	quotes := make([]*entities.MarketQuote, 0, len(s.pending))
+	for _, quote := range s.pending {
+		quotes = append(quotes, quote)
+	}
+	s.pending = make(map[string]*entities.MarketQuote, len(quotes))
+	s.mu.Unlock()
+
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].Symbol < quotes[j].Symbol })
+	s.lastSent = time.Now()
+	return quotes, nil
+}
+
+// Close stops the subscription and releases its symbols
+func (s *Subscription) Close() {
+	s.closed.Do(func() {
+		s.service.unsubscribe(s)
+	})
+}
+
+func (s *Subscription) offer(quote *entities.MarketQuote) {
+	s.mu.Lock()
+	s.pending[quote.Symbol] = quote
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,316 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// relevancePlaces is how many decimal places exposure and relevance keep
+const relevancePlaces = 6
+
+// Provider serves market news. With no symbols it returns general market news.
+type Provider interface {
+	GetNews(ctx context.Context, symbols []string, since time.Time, limit int) ([]entities.NewsArticle, error)
+}
+
+// PositionRepository lists a user's basket positions
+type PositionRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error)
+}
+
+// BasketRepository loads basket compositions
+type BasketRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error)
+}
+
+// Config controls what the feed fetches and how long it is cached
+type Config struct {
+	Lookback        time.Duration // How far back stories are fetched
+	FetchLimit      int           // Stories requested from the provider per symbol set
+	MaxSymbols      int           // Heaviest holdings the feed is built from
+	CacheTTL        time.Duration // How long fetched stories are served before refetching
+	RecencyHalfLife time.Duration // Age at which a story's relevance halves
+	DefaultLimit    int
+	MaxLimit        int
+}
+
+// DefaultConfig fetches three days of news, cached for five minutes
+func DefaultConfig() Config {
+	return Config{
+		Lookback:        72 * time.Hour,
+		FetchLimit:      50,
+		MaxSymbols:      50,
+		CacheTTL:        5 * time.Minute,
+		RecencyHalfLife: 24 * time.Hour,
+		DefaultLimit:    20,
+		MaxLimit:        50,
+	}
+}
+
+type cacheEntry struct {
+	articles  []entities.NewsArticle
+	fetchedAt time.Time
+}
+
+// Service builds a news feed from the symbols in a user's baskets. Each
+// symbol is weighted by the share of the portfolio held in it through its
+// baskets, and a story ranks by the weight of the held symbols it mentions,
+// halved for every RecencyHalfLife of age. Stories are cached per symbol
+// set, so users holding the same curated baskets share one fetch; when the
+// provider fails, the last fetch is served until it is refreshed.
+type Service struct {
+	provider  Provider
+	positions PositionRepository
+	baskets   BasketRepository
+	config    Config
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewService creates a portfolio news service
+func NewService(provider Provider, positions PositionRepository, baskets BasketRepository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.Lookback <= 0 {
+		config.Lookback = defaults.Lookback
+	}
+	if config.FetchLimit <= 0 {
+		config.FetchLimit = defaults.FetchLimit
+	}
+	if config.MaxSymbols <= 0 {
+		config.MaxSymbols = defaults.MaxSymbols
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaults.CacheTTL
+	}
+	if config.RecencyHalfLife <= 0 {
+		config.RecencyHalfLife = defaults.RecencyHalfLife
+	}
+	if config.DefaultLimit <= 0 {
+		config.DefaultLimit = defaults.DefaultLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = defaults.MaxLimit
+	}
+	return &Service{
+		provider:  provider,
+		positions: positions,
+		baskets:   baskets,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+		cache:     map[string]cacheEntry{},
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Feed returns up to limit stories about the user's holdings, most relevant
+// first
+func (s *Service) Feed(ctx context.Context, userID uuid.UUID, limit int) (*entities.PortfolioNews, error) {
+	if limit <= 0 {
+		limit = s.config.DefaultLimit
+	}
+	if limit > s.config.MaxLimit {
+		limit = s.config.MaxLimit
+	}
+	now := s.now().UTC()
+
+	weights, err := s.SymbolWeights(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	symbols := heaviest(weights, s.config.MaxSymbols)
+
+	articles, err := s.fetch(ctx, symbols, now)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &entities.PortfolioNews{
+		Articles:     make([]*entities.PortfolioNewsItem, 0, len(articles)),
+		Personalized: len(symbols) > 0,
+		GeneratedAt:  now,
+	}
+	for _, article := range articles {
+		item := &entities.PortfolioNewsItem{NewsArticle: article, MatchedSymbols: []string{}, Exposure: decimal.Zero}
+		for _, symbol := range article.Symbols {
+			if weight, ok := weights[strings.ToUpper(symbol)]; ok {
+				item.MatchedSymbols = append(item.MatchedSymbols, strings.ToUpper(symbol))
+				item.Exposure = item.Exposure.Add(weight)
+			}
+		}
+		if feed.Personalized && len(item.MatchedSymbols) == 0 {
+			continue
+		}
+		sort.SliceStable(item.MatchedSymbols, func(i, j int) bool {
+			return weights[item.MatchedSymbols[i]].GreaterThan(weights[item.MatchedSymbols[j]])
+		})
+		item.Relevance = item.Exposure.Mul(s.decay(now.Sub(article.PublishedAt))).Round(relevancePlaces)
+		item.Exposure = item.Exposure.Round(relevancePlaces)
+		feed.Articles = append(feed.Articles, item)
+	}
+
+	sort.SliceStable(feed.Articles, func(i, j int) bool {
+		a, b := feed.Articles[i], feed.Articles[j]
+		if !a.Relevance.Equal(b.Relevance) {
+			return a.Relevance.GreaterThan(b.Relevance)
+		}
+		return a.PublishedAt.After(b.PublishedAt)
+	})
+	if len(feed.Articles) > limit {
+		feed.Articles = feed.Articles[:limit]
+	}
+	return feed, nil
+}
+
+// MarketContext renders the top stories about the user's holdings as a
+// markdown section for AI summaries. It is empty when there is no news.
+func (s *Service) MarketContext(ctx context.Context, userID uuid.UUID, limit int) (string, error) {
+	feed, err := s.Feed(ctx, userID, limit)
+	if err != nil {
+		return "", err
+	}
+	if len(feed.Articles) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("## Market context\n\n")
+	for _, item := range feed.Articles {
+		b.WriteString("- ")
+		if len(item.MatchedSymbols) > 0 {
+			fmt.Fprintf(&b, "**%s** ", strings.Join(item.MatchedSymbols, ", "))
+		}
+		fmt.Fprintf(&b, "%s (%s, %s)", item.Headline, item.Source, item.PublishedAt.Format("Jan 2"))
+		if item.Exposure.IsPositive() {
+			fmt.Fprintf(&b, ", %s%% of portfolio", item.Exposure.Mul(decimal.NewFromInt(100)).StringFixed(1))
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// SymbolWeights returns the share of the user's portfolio held in each
+// symbol: each basket position's share of market value spread over its
+// components by target weight
+func (s *Service) SymbolWeights(ctx context.Context, userID uuid.UUID) (map[string]decimal.Decimal, error) {
+	positions, err := s.positions.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	total := decimal.Zero
+	for _, position := range positions {
+		if position.MarketValue.IsPositive() {
+			total = total.Add(position.MarketValue)
+		}
+	}
+
+	weights := map[string]decimal.Decimal{}
+	if !total.IsPositive() {
+		return weights, nil
+	}
+	for _, position := range positions {
+		if !position.MarketValue.IsPositive() {
+			continue
+		}
+		basket, err := s.baskets.GetByID(ctx, position.BasketID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get basket %s: %w", position.BasketID, err)
+		}
+		componentTotal := decimal.Zero
+		for _, component := range basket.Composition {
+			componentTotal = componentTotal.Add(component.Weight)
+		}
+		if !componentTotal.IsPositive() {
+			continue
+		}
+		share := position.MarketValue.Div(total)
+		for _, component := range basket.Composition {
+			symbol := strings.ToUpper(component.Symbol)
+			weights[symbol] = weights[symbol].Add(share.Mul(component.Weight).Div(componentTotal))
+		}
+	}
+	return weights, nil
+}
+
+// fetch returns the stories for a symbol set, from the cache while fresh
+func (s *Service) fetch(ctx context.Context, symbols []string, now time.Time) ([]entities.NewsArticle, error) {
+	key := strings.Join(symbols, ",")
+
+	s.mu.Lock()
+	entry, cached := s.cache[key]
+	s.mu.Unlock()
+	if cached && now.Sub(entry.fetchedAt) < s.config.CacheTTL {
+		return entry.articles, nil
+	}
+
+	articles, err := s.provider.GetNews(ctx, symbols, now.Add(-s.config.Lookback), s.config.FetchLimit)
+	if err != nil {
+		if cached {
+			s.logger.Warn("News provider failed, serving cached stories",
+				zap.Error(err),
+				zap.Duration("age", now.Sub(entry.fetchedAt)))
+			return entry.articles, nil
+		}
+		return nil, fmt.Errorf("failed to fetch news: %w", err)
+	}
+
+	s.mu.Lock()
+	for k, e := range s.cache {
+		if now.Sub(e.fetchedAt) >= s.config.CacheTTL {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = cacheEntry{articles: articles, fetchedAt: now}
+	s.mu.Unlock()
+	return articles, nil
+}
+
+// decay is the factor a story's relevance keeps at the given age
+func (s *Service) decay(age time.Duration) decimal.Decimal {
+	if age <= 0 {
+		return decimal.NewFromInt(1)
+	}
+	halvings := float64(age) / float64(s.config.RecencyHalfLife)
+	return decimal.NewFromFloat(math.Pow(0.5, halvings))
+}
+
+// heaviest returns up to n symbols by descending weight, sorted by name so
+// the same holdings always give the same cache key
+func heaviest(weights map[string]decimal.Decimal, n int) []string {
+	symbols := make([]string, 0, len(weights))
+	for symbol, weight := range weights {
+		if weight.IsPositive() {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if !weights[symbols[i]].Equal(weights[symbols[j]]) {
+			return weights[symbols[i]].GreaterThan(weights[symbols[j]])
+		}
+		return symbols[i] < symbols[j]
+	})
+	if len(symbols) > n {
+		symbols = symbols[:n]
+	}
+	sort.Strings(symbols)
+	return symbols
+}
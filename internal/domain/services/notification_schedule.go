@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// NotificationPreferenceStore loads and saves users' notification preferences
+type NotificationPreferenceStore interface {
+	// Get returns the user's preferences, or the defaults if they saved none
+	Get(ctx context.Context, userID uuid.UUID) (*entities.UserPreference, error)
+	Save(ctx context.Context, prefs *entities.UserPreference) error
+}
+
+// HeldNotificationStore keeps notifications held for quiet hours or the
+// daily digest
+type HeldNotificationStore interface {
+	Hold(ctx context.Context, held *entities.HeldNotification) error
+	// ListDue returns held notifications due by before, grouped by user
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*entities.HeldNotification, error)
+	Delete(ctx context.Context, ids []uuid.UUID) error
+}
+
+// NotificationHoldConfig controls the release of held notifications
+type NotificationHoldConfig struct {
+	Interval  time.Duration // Time between checks for due notifications
+	BatchSize int           // Held notifications released per check
+}
+
+// DefaultNotificationHoldConfig checks every minute
+func DefaultNotificationHoldConfig() NotificationHoldConfig {
+	return NotificationHoldConfig{
+		Interval:  time.Minute,
+		BatchSize: 500,
+	}
+}
+
+// SetPreferenceStore makes delivery follow each user's saved preferences
+// instead of the ones passed with the notification
+func (s *NotificationService) SetPreferenceStore(store NotificationPreferenceStore) {
+	s.preferences = store
+}
+
+// SetHeldNotifications enables quiet hours and the daily digest. Without
+// it every notification is sent immediately.
+func (s *NotificationService) SetHeldNotifications(store HeldNotificationStore, config NotificationHoldConfig) {
+	defaults := DefaultNotificationHoldConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	s.held = store
+	s.holdConfig = config
+}
+
+// SetClock overrides the time source, for tests
+func (s *NotificationService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports releases of held notifications to the worker registry
+func (s *NotificationService) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often held notifications are checked
+func (s *NotificationService) Interval() time.Duration {
+	return s.holdConfig.Interval
+}
+
+// Start releases due notifications on every tick until ctx is cancelled
+func (s *NotificationService) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.holdConfig.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.ReleaseHeld(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Held notification release failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// GetPreferences returns the user's notification preferences
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*entities.UserPreference, error) {
+	if s.preferences == nil {
+		return entities.DefaultUserPreference(userID), nil
+	}
+	return s.preferences.Get(ctx, userID)
+}
+
+// UpdatePreferences changes the fields set in req and saves the result
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *entities.UpdateNotificationPreferencesRequest) (*entities.UserPreference, error) {
+	if s.preferences == nil {
+		return nil, fmt.Errorf("notification preferences are not configured")
+	}
+	prefs, err := s.preferences.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		dst *bool
+		src *bool
+	}{
+		{&prefs.EmailNotifications, req.EmailNotifications},
+		{&prefs.PushNotifications, req.PushNotifications},
+		{&prefs.SMSNotifications, req.SMSNotifications},
+		{&prefs.DepositAlerts, req.DepositAlerts},
+		{&prefs.WithdrawalAlerts, req.WithdrawalAlerts},
+		{&prefs.TradeAlerts, req.TradeAlerts},
+		{&prefs.SecurityAlerts, req.SecurityAlerts},
+		{&prefs.PortfolioUpdates, req.PortfolioUpdates},
+		{&prefs.MarketingEmails, req.MarketingEmails},
+		{&prefs.QuietHoursEnabled, req.QuietHoursEnabled},
+		{&prefs.DigestLowPriority, req.DigestLowPriority},
+	} {
+		if field.src != nil {
+			*field.dst = *field.src
+		}
+	}
+	if req.QuietHoursStart != nil {
+		prefs.QuietHoursStart = strings.TrimSpace(*req.QuietHoursStart)
+	}
+	if req.QuietHoursEnd != nil {
+		prefs.QuietHoursEnd = strings.TrimSpace(*req.QuietHoursEnd)
+	}
+	if req.DigestHour != nil {
+		prefs.DigestHour = *req.DigestHour
+	}
+
+	start, err := parseClock(prefs.QuietHoursStart)
+	if err != nil {
+		return nil, fmt.Errorf("%w: quiet_hours_start %v", entities.ErrInvalidPreferences, err)
+	}
+	end, err := parseClock(prefs.QuietHoursEnd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: quiet_hours_end %v", entities.ErrInvalidPreferences, err)
+	}
+	if prefs.QuietHoursEnabled && start == end {
+		return nil, fmt.Errorf("%w: quiet hours must start and end at different times", entities.ErrInvalidPreferences)
+	}
+	if prefs.DigestHour < 0 || prefs.DigestHour > 23 {
+		return nil, fmt.Errorf("%w: digest_hour must be between 0 and 23", entities.ErrInvalidPreferences)
+	}
+
+	now := s.now().UTC()
+	if prefs.ID == uuid.Nil {
+		prefs.ID = uuid.New()
+		prefs.CreatedAt = now
+	}
+	prefs.UpdatedAt = now
+	if err := s.preferences.Save(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// BypassesSchedule reports whether a notification is sent at once whatever
+// the user's quiet hours and digest settings: critical and security alerts
+func BypassesSchedule(notification *entities.Notification) bool {
+	return notification.Priority == entities.PriorityCritical || notification.Type == entities.NotificationTypeSecurity
+}
+
+// QuietHoursEnd reports whether now falls in the user's quiet hours and,
+// if so, when they end. Quiet hours that start later in the day than they
+// end run overnight.
+func QuietHoursEnd(prefs *entities.UserPreference, now time.Time) (time.Time, bool) {
+	if prefs == nil || !prefs.QuietHoursEnabled {
+		return time.Time{}, false
+	}
+	start, err := parseClock(prefs.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(prefs.QuietHoursEnd)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(entities.UserLocation(prefs.Timezone))
+	minute := local.Hour()*60 + local.Minute()
+	switch {
+	case start < end && minute >= start && minute < end:
+		return localClock(local, 0, end), true
+	case start > end && minute >= start:
+		return localClock(local, 1, end), true
+	case start > end && minute < end:
+		return localClock(local, 0, end), true
+	}
+	return time.Time{}, false
+}
+
+// NextDigest returns when the user's next daily digest is due
+func NextDigest(prefs *entities.UserPreference, now time.Time) time.Time {
+	local := now.In(entities.UserLocation(prefs.Timezone))
+	next := localClock(local, 0, prefs.DigestHour*60)
+	if !next.After(now) {
+		next = localClock(local, 1, prefs.DigestHour*60)
+	}
+	return next
+}
+
+// ReleaseHeld sends the notifications whose quiet hours have ended and the
+// digests that are due, and returns how many notifications went out. A
+// notification that fails to send stays held and is retried on the next
+// check.
+func (s *NotificationService) ReleaseHeld(ctx context.Context) (int, error) {
+	if s.held == nil {
+		return 0, nil
+	}
+	due, err := s.held.ListDue(ctx, s.now(), s.holdConfig.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var released []uuid.UUID
+	sent := 0
+	for start := 0; start < len(due); {
+		end := start
+		for end < len(due) && due[end].UserID == due[start].UserID {
+			end++
+		}
+		userID := due[start].UserID
+
+		var digest []*entities.HeldNotification
+		for _, item := range due[start:end] {
+			if item.Reason == entities.NotificationHoldDigest {
+				digest = append(digest, item)
+				continue
+			}
+			if err := s.dispatch(ctx, item.Notification); err != nil {
+				s.logger.Warn("Failed to send held notification", zap.Error(err),
+					zap.String("user_id", userID.String()), zap.String("held_id", item.ID.String()))
+				continue
+			}
+			released = append(released, item.ID)
+			sent++
+		}
+		if len(digest) > 0 {
+			if err := s.sendDigest(ctx, userID, digest); err != nil {
+				s.logger.Warn("Failed to send notification digest", zap.Error(err), zap.String("user_id", userID.String()))
+			} else {
+				for _, item := range digest {
+					released = append(released, item.ID)
+				}
+				sent++
+			}
+		}
+		start = end
+	}
+
+	if err := s.held.Delete(ctx, released); err != nil {
+		return sent, err
+	}
+	if len(released) > 0 {
+		s.logger.Info("Released held notifications", zap.Int("notifications", len(released)), zap.Int("sent", sent))
+	}
+	return sent, nil
+}
+
+// hold stores a notification for later when the user's quiet hours or
+// digest apply to it, and reports whether it did
+func (s *NotificationService) hold(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) (bool, error) {
+	if s.held == nil || prefs == nil || BypassesSchedule(notification) {
+		return false, nil
+	}
+
+	now := s.now()
+	held := &entities.HeldNotification{
+		ID:           uuid.New(),
+		UserID:       notification.UserID,
+		Notification: notification,
+		CreatedAt:    now.UTC(),
+	}
+	if prefs.DigestLowPriority && notification.Priority == entities.PriorityLow {
+		held.Reason = entities.NotificationHoldDigest
+		held.DeliverAfter = NextDigest(prefs, now)
+	} else if notification.Channel != entities.ChannelPush && notification.Channel != entities.ChannelSMS {
+		return false, nil
+	} else if end, quiet := QuietHoursEnd(prefs, now); quiet {
+		held.Reason = entities.NotificationHoldQuietHours
+		held.DeliverAfter = end
+	} else {
+		return false, nil
+	}
+
+	if err := s.held.Hold(ctx, held); err != nil {
+		return false, err
+	}
+	s.logger.Debug("Notification held",
+		zap.String("user_id", notification.UserID.String()),
+		zap.String("reason", string(held.Reason)),
+		zap.Time("deliver_after", held.DeliverAfter))
+	return true, nil
+}
+
+// sendDigest sends one summary of the user's held low-priority
+// notifications, by email when they receive email and in the app otherwise
+func (s *NotificationService) sendDigest(ctx context.Context, userID uuid.UUID, items []*entities.HeldNotification) error {
+	channel := entities.ChannelInApp
+	if prefs, err := s.GetPreferences(ctx, userID); err == nil && prefs.EmailNotifications {
+		channel = entities.ChannelEmail
+	}
+
+	var message strings.Builder
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		titles = append(titles, item.Notification.Title)
+		fmt.Fprintf(&message, "- %s", item.Notification.Title)
+		if item.Notification.Message != "" {
+			fmt.Fprintf(&message, ": %s", item.Notification.Message)
+		}
+		message.WriteString("\n")
+	}
+	title := "Your daily summary: 1 update"
+	if len(items) != 1 {
+		title = fmt.Sprintf("Your daily summary: %d updates", len(items))
+	}
+
+	return s.dispatch(ctx, &entities.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      entities.NotificationTypeDigest,
+		Channel:   channel,
+		Priority:  entities.PriorityLow,
+		Title:     title,
+		Message:   message.String(),
+		Data:      map[string]interface{}{"titles": titles},
+		CreatedAt: s.now().UTC(),
+	})
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("must be a 24-hour time such as 22:00")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// localClock returns minute-of-day on the day offset days after local's
+func localClock(local time.Time, days, minute int) time.Time {
+	return time.Date(local.Year(), local.Month(), local.Day()+days, minute/60, minute%60, 0, 0, local.Location())
+}
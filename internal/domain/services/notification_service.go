@@ -8,23 +8,74 @@ import (
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 	"go.uber.org/zap"
 )
 
 type NotificationService struct {
-	logger *zap.Logger
+	bus         NotificationBus
+	preferences NotificationPreferenceStore
+	held        HeldNotificationStore
+	holdConfig  NotificationHoldConfig
+	tracker     *workerstatus.Tracker
+	now         func() time.Time
+	logger      *zap.Logger
+}
+
+// NotificationBus queues notifications for asynchronous dispatch
+type NotificationBus interface {
+	Publish(ctx context.Context, topic, key string, payload interface{}) error
 }
 
 func NewNotificationService(logger *zap.Logger) *NotificationService {
-	return &NotificationService{logger: logger}
+	return &NotificationService{
+		holdConfig: DefaultNotificationHoldConfig(),
+		now:        time.Now,
+		logger:     logger,
+	}
 }
 
+// SetEventBus routes Send through the event bus; a dispatcher consumer calls Deliver
+func (s *NotificationService) SetEventBus(bus NotificationBus) {
+	s.bus = bus
+}
+
+// Send queues a notification for dispatch, or delivers it inline when no bus is configured
 func (s *NotificationService) Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error {
+	if s.bus == nil {
+		return s.Deliver(ctx, notification, prefs)
+	}
+	return s.bus.Publish(ctx, entities.TopicNotificationRequested, notification.UserID.String(), entities.NotificationRequest{
+		Notification: notification,
+		Preferences:  prefs,
+	})
+}
+
+// Deliver sends a notification on its channel, honouring user preferences.
+// With stored preferences, those replace the ones given, and notifications
+// that do not bypass the schedule are held for quiet hours or the daily
+// digest.
+func (s *NotificationService) Deliver(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error {
+	if s.preferences != nil {
+		stored, err := s.preferences.Get(ctx, notification.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to load notification preferences: %w", err)
+		}
+		prefs = stored
+	}
 	if !s.shouldSend(notification, prefs) {
 		s.logger.Debug("Notification skipped due to user preferences", zap.String("type", string(notification.Type)))
 		return nil
 	}
+	if held, err := s.hold(ctx, notification, prefs); held || err != nil {
+		return err
+	}
+	return s.dispatch(ctx, notification)
+}
 
+// dispatch sends a notification on its channel
+func (s *NotificationService) dispatch(ctx context.Context, notification *entities.Notification) error {
 	switch notification.Channel {
 	case entities.ChannelEmail:
 		return s.sendEmail(ctx, notification)
@@ -40,7 +91,7 @@ func (s *NotificationService) Send(ctx context.Context, notification *entities.N
 }
 
 func (s *NotificationService) shouldSend(notification *entities.Notification, prefs *entities.UserPreference) bool {
-	if notification.Priority == entities.PriorityCritical {
+	if notification.Priority == entities.PriorityCritical || prefs == nil {
 		return true
 	}
 
@@ -84,9 +135,10 @@ func (s *NotificationService) SendWeeklySummary(ctx context.Context, userID uuid
 }
 
 func (s *NotificationService) NotifyOffRampSuccess(ctx context.Context, userID uuid.UUID, amount string) error {
+	value, _ := decimal.NewFromString(amount)
 	s.logger.Info("Sending off-ramp success notification",
 		zap.String("user_id", userID.String()),
-		zap.String("amount", amount))
+		logger.Amount("amount", value))
 	return nil
 }
 
@@ -100,7 +152,7 @@ func (s *NotificationService) NotifyOffRampFailure(ctx context.Context, userID u
 func (s *NotificationService) NotifyTransactionDeclined(ctx context.Context, userID uuid.UUID, amount decimal.Decimal, transactionType string) error {
 	s.logger.Info("Sending transaction declined notification",
 		zap.String("user_id", userID.String()),
-		zap.String("amount", amount.String()),
+		logger.Amount("amount", amount),
 		zap.String("type", transactionType))
 	return nil
 }
@@ -213,7 +213,7 @@ func (s *OffRampService) HandleTransferCompleted(ctx context.Context, transferID
 func (s *OffRampService) fundAlpacaAccount(ctx context.Context, deposit *entities.Deposit, amount decimal.Decimal) error {
 	s.logger.Info("Funding Alpaca account",
 		"deposit_id", deposit.ID.String(),
-		"amount", amount.String())
+		"amount", logger.RedactAmount(amount))
 
 	// Get virtual account to get Alpaca account ID
 	virtualAccount, err := s.virtualAccountRepo.GetByID(ctx, *deposit.VirtualAccountID)
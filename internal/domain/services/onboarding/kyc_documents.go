@@ -0,0 +1,253 @@
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// GetKYCDocuments returns the user's latest KYC submission with the review
+// status of each document
+func (s *Service) GetKYCDocuments(ctx context.Context, userID uuid.UUID) (*entities.KYCSubmission, error) {
+	submission, err := s.kycSubmissionRepo.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC submission: %w", err)
+	}
+	return submission, nil
+}
+
+// DeleteKYCDocument removes a rejected document from the user's latest
+// submission. The document must be replaced before the submission goes back
+// for review; accepted documents and those under review cannot be deleted.
+func (s *Service) DeleteKYCDocument(ctx context.Context, userID uuid.UUID, docType string) (*entities.KYCSubmission, error) {
+	submission, doc, err := s.rejectedKYCDocument(ctx, userID, docType)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *doc
+	doc.Status = entities.KYCDocumentStatusDeleted
+	doc.FileURL = ""
+	submission.UpdatedAt = time.Now()
+	if err := s.storeKYCDocumentLink(ctx, submission, doc); err != nil {
+		return nil, err
+	}
+
+	if err := s.kycSubmissionRepo.Update(ctx, submission); err != nil {
+		return nil, fmt.Errorf("failed to update KYC submission: %w", err)
+	}
+
+	if err := s.auditService.LogOnboardingEvent(ctx, userID, "kyc_document_deleted", "kyc_document", before, *doc); err != nil {
+		s.logger.Warn("Failed to log audit event", zap.Error(err))
+	}
+
+	return submission, nil
+}
+
+// ReplaceKYCDocument swaps a rejected document for a new upload. Once no
+// rejected documents remain, the submission goes back for review: only the
+// replaced documents are sent when the provider supports partial
+// resubmission, otherwise every document is submitted as a new application.
+func (s *Service) ReplaceKYCDocument(ctx context.Context, userID uuid.UUID, upload entities.KYCDocumentUpload) (*entities.KYCSubmission, error) {
+	submission, doc, err := s.rejectedKYCDocument(ctx, userID, upload.Type)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := s.resolveDocuments(ctx, userID, []entities.KYCDocumentUpload{upload})
+	if err != nil {
+		return nil, err
+	}
+	upload = entities.StoredDocuments(resolved)[0]
+
+	now := time.Now()
+	before := *doc
+	doc.UploadID = upload.UploadID
+	doc.FileURL = upload.FileURL
+	doc.ContentType = upload.ContentType
+	doc.Status = entities.KYCDocumentStatusSubmitted
+	doc.RejectionReason = nil
+	doc.ReviewedAt = nil
+	doc.Revision++
+	doc.SubmittedAt = now
+	submission.UpdatedAt = now
+
+	if remaining := submission.PendingReplacements(); len(remaining) > 0 {
+		s.logger.Info("KYC document replaced, waiting for remaining documents",
+			zap.String("userId", userID.String()),
+			zap.String("documentType", doc.Type),
+			zap.Strings("remaining", remaining))
+	} else if err := s.resubmitKYC(ctx, submission); err != nil {
+		return nil, err
+	}
+	if err := s.storeKYCDocumentLink(ctx, submission, doc); err != nil {
+		return nil, err
+	}
+
+	if err := s.kycSubmissionRepo.Update(ctx, submission); err != nil {
+		return nil, fmt.Errorf("failed to update KYC submission: %w", err)
+	}
+
+	if submission.Status == entities.KYCStatusProcessing {
+		if err := s.markKYCResubmitted(ctx, userID, submission); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.auditService.LogOnboardingEvent(ctx, userID, "kyc_document_replaced", "kyc_document", before, *doc); err != nil {
+		s.logger.Warn("Failed to log audit event", zap.Error(err))
+	}
+
+	return submission, nil
+}
+
+// rejectedKYCDocument loads the user's latest submission and the named
+// document, which must be waiting for a replacement
+func (s *Service) rejectedKYCDocument(ctx context.Context, userID uuid.UUID, docType string) (*entities.KYCSubmission, *entities.KYCDocument, error) {
+	submission, err := s.kycSubmissionRepo.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get KYC submission: %w", err)
+	}
+
+	doc := submission.Document(strings.TrimSpace(docType))
+	if doc == nil {
+		return nil, nil, entities.ErrKYCDocumentNotFound
+	}
+	if submission.Status != entities.KYCStatusRejected || !doc.NeedsReplacement() {
+		return nil, nil, entities.ErrKYCDocumentNotRejected
+	}
+	return submission, doc, nil
+}
+
+// resubmitKYC sends the submission's replaced documents back to the provider
+// and moves the submission to processing
+func (s *Service) resubmitKYC(ctx context.Context, submission *entities.KYCSubmission) error {
+	record, err := s.kycVaultRecord(ctx, submission, "resubmit KYC to provider")
+	if err != nil {
+		return err
+	}
+	personalInfo := record.PersonalInfo
+
+	var replaced []entities.KYCDocumentUpload
+	for i := range submission.Documents {
+		if doc := &submission.Documents[i]; doc.Status == entities.KYCDocumentStatusSubmitted {
+			replaced = append(replaced, vaultedUpload(doc, record))
+		}
+	}
+	replaced, err = s.resolveDocuments(ctx, submission.UserID, replaced)
+	if err != nil {
+		return err
+	}
+
+	partial := false
+	if s.kycDocuments != nil {
+		err := s.kycDocuments.ResubmitDocuments(ctx, submission.ProviderRef, replaced, personalInfo)
+		switch {
+		case err == nil:
+			partial = true
+		case !errors.Is(err, entities.ErrPartialKYCResubmissionUnsupported):
+			return fmt.Errorf("failed to resubmit KYC documents: %w", err)
+		}
+	}
+
+	if !partial {
+		uploads := make([]entities.KYCDocumentUpload, 0, len(submission.Documents))
+		for i := range submission.Documents {
+			doc := &submission.Documents[i]
+			doc.Status = entities.KYCDocumentStatusSubmitted
+			doc.ReviewedAt = nil
+			uploads = append(uploads, vaultedUpload(doc, record))
+		}
+		uploads, err := s.resolveDocuments(ctx, submission.UserID, uploads)
+		if err != nil {
+			return err
+		}
+
+		providerRef, err := s.kycProvider.SubmitKYC(ctx, submission.UserID, uploads, personalInfo)
+		if err != nil {
+			return fmt.Errorf("failed to submit KYC to provider: %w", err)
+		}
+		submission.ProviderRef = providerRef
+	}
+
+	now := time.Now()
+	submission.Status = entities.KYCStatusProcessing
+	submission.ReviewedAt = nil
+	submission.RejectionReasons = nil
+	submission.SubmittedAt = now
+	submission.UpdatedAt = now
+
+	s.logger.Info("KYC documents resubmitted",
+		zap.String("userId", submission.UserID.String()),
+		zap.String("providerRef", submission.ProviderRef),
+		zap.Bool("partial", partial),
+		zap.Int("replacedCount", len(replaced)))
+
+	return nil
+}
+
+// markKYCResubmitted puts the user back in KYC review after a resubmission
+func (s *Service) markKYCResubmitted(ctx context.Context, userID uuid.UUID, submission *entities.KYCSubmission) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	providerRef := submission.ProviderRef
+	user.KYCStatus = string(entities.KYCStatusProcessing)
+	user.KYCProviderRef = &providerRef
+	user.KYCSubmittedAt = &submission.SubmittedAt
+	user.KYCRejectionReason = nil
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user KYC status: %w", err)
+	}
+	return nil
+}
+
+// reviewKYCDocuments records per-document results for a reviewed submission.
+// Providers without per-document results leave every document rejected.
+func (s *Service) reviewKYCDocuments(ctx context.Context, submission *entities.KYCSubmission, status entities.KYCStatus) {
+	var reviews []entities.KYCDocumentReview
+	if status == entities.KYCStatusRejected && s.kycDocuments != nil && len(submission.Documents) > 0 {
+		var err error
+		reviews, err = s.kycDocuments.GetDocumentReviews(ctx, submission.ProviderRef, submission.Documents)
+		if err != nil && !errors.Is(err, entities.ErrPartialKYCResubmissionUnsupported) {
+			s.logger.Warn("Failed to get per-document KYC review",
+				zap.String("providerRef", submission.ProviderRef),
+				zap.Error(err))
+		}
+	}
+	submission.ApplyDocumentReviews(status, reviews, time.Now())
+}
+
+// submissionPersonalInfo reads back the personal info stored with a
+// submission. It is a struct when the submission was just created and a map
+// once it has been loaded from the database.
+func submissionPersonalInfo(submission *entities.KYCSubmission) *entities.KYCPersonalInfo {
+	raw, ok := submission.VerificationData["personal_info"]
+	if !ok || raw == nil {
+		return nil
+	}
+	if info, ok := raw.(*entities.KYCPersonalInfo); ok {
+		return info
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var info entities.KYCPersonalInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil
+	}
+	return &info
+}
@@ -0,0 +1,90 @@
+package onboarding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// tokenizeKYCSubmission moves a new submission's personal info and document
+// links into the PII vault, leaving the token on the submission
+func (s *Service) tokenizeKYCSubmission(ctx context.Context, submission *entities.KYCSubmission) error {
+	if s.piiVault == nil {
+		return nil
+	}
+
+	record := entities.KYCVaultRecord{
+		PersonalInfo: submissionPersonalInfo(submission),
+		DocumentURLs: make(map[string]string),
+	}
+	for i := range submission.Documents {
+		doc := &submission.Documents[i]
+		if doc.FileURL != "" {
+			record.DocumentURLs[doc.Type] = doc.FileURL
+			doc.FileURL = ""
+		}
+	}
+
+	token, err := s.piiVault.Tokenize(ctx, submission.UserID, entities.PIIKindKYCIdentity, record)
+	if err != nil {
+		return fmt.Errorf("failed to store KYC data in the PII vault: %w", err)
+	}
+	// The document list duplicates Documents, with links
+	delete(submission.VerificationData, "personal_info")
+	delete(submission.VerificationData, "documents")
+	submission.PIIToken = &token
+	return nil
+}
+
+// kycVaultRecord returns the raw data behind a submission: from the vault
+// when it was tokenized, otherwise from the submission itself
+func (s *Service) kycVaultRecord(ctx context.Context, submission *entities.KYCSubmission, purpose string) (*entities.KYCVaultRecord, error) {
+	if submission.PIIToken == nil {
+		return &entities.KYCVaultRecord{PersonalInfo: submissionPersonalInfo(submission)}, nil
+	}
+	if s.piiVault == nil {
+		return nil, fmt.Errorf("KYC submission %s is tokenized but the PII vault is not configured", submission.ID)
+	}
+	var record entities.KYCVaultRecord
+	if err := s.piiVault.Reveal(ctx, *submission.PIIToken, purpose, &record); err != nil {
+		return nil, fmt.Errorf("failed to read KYC data from the PII vault: %w", err)
+	}
+	return &record, nil
+}
+
+// storeKYCDocumentLink records a replaced or deleted document's link in the
+// vault and clears it from the submission
+func (s *Service) storeKYCDocumentLink(ctx context.Context, submission *entities.KYCSubmission, doc *entities.KYCDocument) error {
+	if submission.PIIToken == nil {
+		return nil
+	}
+	record, err := s.kycVaultRecord(ctx, submission, "update KYC document link")
+	if err != nil {
+		return err
+	}
+	if record.DocumentURLs == nil {
+		record.DocumentURLs = make(map[string]string)
+	}
+	if doc.FileURL != "" {
+		record.DocumentURLs[doc.Type] = doc.FileURL
+	} else {
+		delete(record.DocumentURLs, doc.Type)
+	}
+
+	if err := s.piiVault.Update(ctx, *submission.PIIToken, record); err != nil {
+		return fmt.Errorf("failed to update KYC data in the PII vault: %w", err)
+	}
+	doc.FileURL = ""
+	return nil
+}
+
+// vaultedUpload returns the document as sent to the provider, with its link
+// restored from the vault record when it was tokenized
+func vaultedUpload(doc *entities.KYCDocument, record *entities.KYCVaultRecord) entities.KYCDocumentUpload {
+	upload := doc.Upload()
+	if upload.FileURL == "" && upload.UploadID == nil {
+		upload.FileURL = record.DocumentURLs[doc.Type]
+	}
+	return upload
+}
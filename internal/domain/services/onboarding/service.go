@@ -2,12 +2,14 @@ package onboarding
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -20,12 +22,20 @@ type Service struct {
 	kycSubmissionRepo   KYCSubmissionRepository
 	walletService       WalletService
 	kycProvider         KYCProvider
+	kycDocuments        KYCDocumentProvider
+	documentResolver    DocumentResolver
+	piiVault            PIIVault
 	emailService        EmailService
 	auditService        AuditService
 	dueAdapter          DueAdapter
 	alpacaAdapter       AlpacaAdapter
 	logger              *zap.Logger
 	defaultWalletChains []entities.WalletChain
+	jurisdiction        JurisdictionEvaluator
+	events              EventPublisher
+	kycObservers        []KYCReviewObserver
+	notifier            Notifier
+	experiments         ExperimentAssigner
 }
 
 // Repository interfaces
@@ -67,6 +77,20 @@ type KYCProvider interface {
 	GenerateKYCURL(ctx context.Context, userID uuid.UUID) (string, error)
 }
 
+// KYCDocumentProvider reports per-document review results and accepts
+// replacement documents for an existing application. Providers that cannot
+// return entities.ErrPartialKYCResubmissionUnsupported.
+type KYCDocumentProvider interface {
+	GetDocumentReviews(ctx context.Context, providerRef string, documents []entities.KYCDocument) ([]entities.KYCDocumentReview, error)
+	ResubmitDocuments(ctx context.Context, providerRef string, documents []entities.KYCDocumentUpload, personalInfo *entities.KYCPersonalInfo) error
+}
+
+// DocumentResolver turns references to secure uploads into links the KYC
+// provider can fetch and checks they belong to the user
+type DocumentResolver interface {
+	ResolveDocuments(ctx context.Context, userID uuid.UUID, purpose entities.UploadPurpose, documents []entities.KYCDocumentUpload) ([]entities.KYCDocumentUpload, error)
+}
+
 type EmailService interface {
 	SendVerificationEmail(ctx context.Context, email, verificationToken string) error
 	SendKYCStatusEmail(ctx context.Context, email string, status entities.KYCStatus, rejectionReasons []string) error
@@ -81,6 +105,42 @@ type DueAdapter interface {
 	CreateAccount(ctx context.Context, req *entities.CreateAccountRequest) (*entities.CreateAccountResponse, error)
 }
 
+// JurisdictionEvaluator decides whether a country may onboard
+type JurisdictionEvaluator interface {
+	EvaluateOnboarding(ctx context.Context, countryCode string) (*entities.JurisdictionDecision, error)
+	RecordUserCountry(ctx context.Context, userID uuid.UUID, countryCode string) error
+}
+
+// PIIVault keeps raw personal data out of the main database, behind a token
+type PIIVault interface {
+	Tokenize(ctx context.Context, subjectID uuid.UUID, kind entities.PIIKind, value any) (string, error)
+	Update(ctx context.Context, token string, value any) error
+	Reveal(ctx context.Context, token, purpose string, dest any) error
+}
+
+// EventPublisher queues domain events for partner webhooks
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType entities.WebhookEventType, data interface{}) error
+}
+
+// KYCReviewObserver is told about every KYC decision, e.g. to finish the
+// re-verification of a closed account or grant a signup bonus
+type KYCReviewObserver interface {
+	HandleKYCReview(ctx context.Context, userID uuid.UUID, status entities.KYCStatus, rejectionReasons []string) error
+}
+
+// Notifier sends push and in-app notifications
+type Notifier interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// ExperimentAssigner buckets users into onboarding experiments and records
+// their conversions for analysis
+type ExperimentAssigner interface {
+	Assign(ctx context.Context, userID uuid.UUID, enroll bool) (map[string]string, error)
+	RecordConversion(ctx context.Context, userID uuid.UUID, goal string) error
+}
+
 type AlpacaAdapter interface {
 	CreateAccount(ctx context.Context, req *entities.AlpacaCreateAccountRequest) (*entities.AlpacaAccountResponse, error)
 }
@@ -116,6 +176,78 @@ func NewService(
 	}
 }
 
+// checkSelfDirectedAge rejects missing or under-age dates of birth
+func checkSelfDirectedAge(dateOfBirth *time.Time) error {
+	if dateOfBirth == nil || dateOfBirth.IsZero() {
+		return entities.ErrDateOfBirthRequired
+	}
+	if dateOfBirth.After(time.Now()) {
+		return fmt.Errorf("date of birth cannot be in the future")
+	}
+	if !entities.IsAdult(*dateOfBirth, time.Now()) {
+		return entities.ErrUnderage
+	}
+	return nil
+}
+
+// SetJurisdictionService enables country-based onboarding checks
+func (s *Service) SetJurisdictionService(jurisdiction JurisdictionEvaluator) {
+	s.jurisdiction = jurisdiction
+}
+
+// SetEventPublisher enables partner webhooks for onboarding events
+func (s *Service) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// SetNotifier notifies users as their KYC review moves through the
+// provider's steps
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetExperiments enables onboarding experiments: the status response carries
+// the user's variants and completing onboarding counts as a conversion
+func (s *Service) SetExperiments(experiments ExperimentAssigner) {
+	s.experiments = experiments
+}
+
+// SetKYCDocumentProvider enables per-document review results and partial
+// resubmission of rejected documents
+func (s *Service) SetKYCDocumentProvider(provider KYCDocumentProvider) {
+	s.kycDocuments = provider
+}
+
+// SetDocumentResolver lets KYC documents reference secure uploads
+func (s *Service) SetDocumentResolver(resolver DocumentResolver) {
+	s.documentResolver = resolver
+}
+
+// SetPIIVault keeps KYC personal info and document links in the PII vault
+// rather than on the submission
+func (s *Service) SetPIIVault(vault PIIVault) {
+	s.piiVault = vault
+}
+
+// resolveDocuments returns the documents as sent to the KYC provider, with
+// upload references replaced by fetchable links
+func (s *Service) resolveDocuments(ctx context.Context, userID uuid.UUID, documents []entities.KYCDocumentUpload) ([]entities.KYCDocumentUpload, error) {
+	if s.documentResolver == nil {
+		for _, doc := range documents {
+			if doc.UploadID != nil {
+				return nil, entities.ErrUploadsUnavailable
+			}
+		}
+		return documents, nil
+	}
+	return s.documentResolver.ResolveDocuments(ctx, userID, entities.UploadPurposeKYC, documents)
+}
+
+// AddKYCReviewObserver forwards KYC decisions to observer
+func (s *Service) AddKYCReviewObserver(observer KYCReviewObserver) {
+	s.kycObservers = append(s.kycObservers, observer)
+}
+
 func normalizeDefaultWalletChains(chains []entities.WalletChain, logger *zap.Logger) []entities.WalletChain {
 	if len(chains) == 0 {
 		logger.Warn("No default wallet chains configured; falling back to SOL-DEVNET")
@@ -151,13 +283,13 @@ func normalizeDefaultWalletChains(chains []entities.WalletChain, logger *zap.Log
 
 // StartOnboarding initiates the onboarding process for a new user
 func (s *Service) StartOnboarding(ctx context.Context, req *entities.OnboardingStartRequest) (*entities.OnboardingStartResponse, error) {
-	s.logger.Info("Starting onboarding process", zap.String("email", req.Email))
+	s.logger.Info("Starting onboarding process", logger.Email(req.Email))
 
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
 		s.logger.Info("User already exists, returning existing onboarding status",
-			zap.String("email", req.Email),
+			logger.Email(req.Email),
 			zap.String("userId", existingUser.ID.String()),
 			zap.String("status", string(existingUser.OnboardingStatus)))
 
@@ -186,7 +318,7 @@ func (s *Service) StartOnboarding(ctx context.Context, req *entities.OnboardingS
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		s.logger.Error("Failed to create user", zap.Error(err), zap.String("email", req.Email))
+		s.logger.Error("Failed to create user", zap.Error(err), logger.Email(req.Email))
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -198,7 +330,7 @@ func (s *Service) StartOnboarding(ctx context.Context, req *entities.OnboardingS
 
 	// Send verification email
 	if err := s.emailService.SendVerificationEmail(ctx, user.Email, user.ID.String()); err != nil {
-		s.logger.Warn("Failed to send verification email", zap.Error(err), zap.String("email", user.Email))
+		s.logger.Warn("Failed to send verification email", zap.Error(err), logger.Email(user.Email))
 		// Don't fail onboarding start if email fails
 	}
 
@@ -209,7 +341,7 @@ func (s *Service) StartOnboarding(ctx context.Context, req *entities.OnboardingS
 
 	s.logger.Info("Onboarding started successfully",
 		zap.String("userId", user.ID.String()),
-		zap.String("email", user.Email))
+		logger.Email(user.Email))
 
 	return &entities.OnboardingStartResponse{
 		UserID:           user.ID,
@@ -253,7 +385,7 @@ func (s *Service) GetOnboardingStatus(ctx context.Context, userID uuid.UUID) (*e
 				CreatedWallets:  walletStatusResp.ReadyWallets,
 				PendingWallets:  walletStatusResp.PendingWallets,
 				FailedWallets:   walletStatusResp.FailedWallets,
-				SupportedChains: []string{"ETH", "SOL", "APTOS"},
+				SupportedChains: entities.Chains().EnabledIDs(),
 				WalletsByChain:  make(map[string]string),
 			}
 
@@ -268,6 +400,17 @@ func (s *Service) GetOnboardingStatus(ctx context.Context, userID uuid.UUID) (*e
 	requiredActions := s.determineRequiredActions(user, completedSteps)
 	canProceed := s.canProceed(user, completedSteps)
 
+	// Only users still onboarding are enrolled in new experiments; the ones
+	// already through keep any variant they were given
+	var experiments map[string]string
+	if s.experiments != nil {
+		experiments, err = s.experiments.Assign(ctx, userID, user.OnboardingStatus != entities.OnboardingStatusCompleted)
+		if err != nil {
+			s.logger.Warn("Failed to assign onboarding experiments", zap.Error(err), zap.String("userId", userID.String()))
+			experiments = nil
+		}
+	}
+
 	return &entities.OnboardingStatusResponse{
 		UserID:           user.ID,
 		OnboardingStatus: user.OnboardingStatus,
@@ -277,6 +420,7 @@ func (s *Service) GetOnboardingStatus(ctx context.Context, userID uuid.UUID) (*e
 		WalletStatus:     walletStatus,
 		CanProceed:       canProceed,
 		RequiredActions:  requiredActions,
+		Experiments:      experiments,
 	}, nil
 }
 
@@ -320,6 +464,29 @@ func (s *Service) CompleteOnboarding(ctx context.Context, req *entities.Onboardi
 		return nil, fmt.Errorf("email must be verified before completing onboarding")
 	}
 
+	if err := checkSelfDirectedAge(req.DateOfBirth); err != nil {
+		s.logger.Warn("Onboarding blocked by age check", zap.String("user_id", req.UserID.String()), zap.Error(err))
+		return nil, err
+	}
+
+	// Check the country is supported before creating any external accounts
+	var decision *entities.JurisdictionDecision
+	if s.jurisdiction != nil {
+		decision, err = s.jurisdiction.EvaluateOnboarding(ctx, req.Country)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate jurisdiction: %w", err)
+		}
+		if !decision.Allowed {
+			s.logger.Warn("Onboarding blocked by jurisdiction rules",
+				zap.String("user_id", req.UserID.String()),
+				zap.String("country", decision.CountryCode))
+			return nil, fmt.Errorf("onboarding from %s: %w", decision.CountryCode, entities.ErrJurisdictionNotSupported)
+		}
+		if err := s.jurisdiction.RecordUserCountry(ctx, req.UserID, decision.CountryCode); err != nil {
+			return nil, fmt.Errorf("failed to record user country: %w", err)
+		}
+	}
+
 	// Update user with personal information
 	user.FirstName = &req.FirstName
 	user.LastName = &req.LastName
@@ -402,13 +569,20 @@ func (s *Service) CompleteOnboarding(ctx context.Context, req *entities.Onboardi
 		zap.String("due_account_id", dueResp.AccountID),
 		zap.String("alpaca_account_id", alpacaResp.ID))
 
-	return &entities.OnboardingCompleteResponse{
+	response := &entities.OnboardingCompleteResponse{
 		UserID:          req.UserID,
 		DueAccountID:    dueResp.AccountID,
 		AlpacaAccountID: alpacaResp.ID,
 		Message:         "Accounts created successfully. Please create your passcode to continue.",
 		NextSteps:       []string{"create_passcode"},
-	}, nil
+	}
+	if decision != nil {
+		response.RequiredDisclosures = decision.RequiredDisclosures
+		response.KYCLevel = string(decision.KYCLevel)
+		response.AllowedFeatures = decision.AllowedFeatures
+	}
+
+	return response, nil
 }
 
 // CompletePasscodeCreation handles passcode creation completion and triggers wallet creation
@@ -463,8 +637,23 @@ func (s *Service) SubmitKYC(ctx context.Context, userID uuid.UUID, req *entities
 		return fmt.Errorf("user cannot start KYC process")
 	}
 
+	// Self-directed accounts require an adult; minors must use a custodial account
+	if req.PersonalInfo != nil && req.PersonalInfo.DateOfBirth != nil {
+		user.DateOfBirth = req.PersonalInfo.DateOfBirth
+	}
+	if err := checkSelfDirectedAge(user.DateOfBirth); err != nil {
+		s.logger.Warn("KYC submission rejected by age check", zap.String("userId", userID.String()), zap.Error(err))
+		return err
+	}
+
+	// Uploaded documents are stored by reference; only the provider gets links
+	documents, err := s.resolveDocuments(ctx, userID, req.Documents)
+	if err != nil {
+		return err
+	}
+
 	// Submit to KYC provider
-	providerRef, err := s.kycProvider.SubmitKYC(ctx, userID, req.Documents, req.PersonalInfo)
+	providerRef, err := s.kycProvider.SubmitKYC(ctx, userID, documents, req.PersonalInfo)
 	if err != nil {
 		return fmt.Errorf("failed to submit KYC to provider: %w", err)
 	}
@@ -479,14 +668,18 @@ func (s *Service) SubmitKYC(ctx context.Context, userID uuid.UUID, req *entities
 		Status:         entities.KYCStatusProcessing,
 		VerificationData: map[string]any{
 			"document_type": req.DocumentType,
-			"documents":     req.Documents,
+			"documents":     entities.StoredDocuments(documents),
 			"personal_info": req.PersonalInfo,
 			"metadata":      req.Metadata,
 		},
+		Documents:   entities.NewKYCDocuments(entities.StoredDocuments(documents), time.Now()),
 		SubmittedAt: time.Now(),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if err := s.tokenizeKYCSubmission(ctx, submission); err != nil {
+		return err
+	}
 
 	if err := s.kycSubmissionRepo.Create(ctx, submission); err != nil {
 		return fmt.Errorf("failed to create KYC submission record: %w", err)
@@ -497,6 +690,7 @@ func (s *Service) SubmitKYC(ctx context.Context, userID uuid.UUID, req *entities
 	user.KYCStatus = string(entities.KYCStatusProcessing)
 	user.KYCProviderRef = &providerRef
 	user.KYCSubmittedAt = &now
+	user.KYCPIIToken = submission.PIIToken
 	user.UpdatedAt = now
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
@@ -524,10 +718,13 @@ func (s *Service) SubmitKYC(ctx context.Context, userID uuid.UUID, req *entities
 }
 
 // ProcessKYCCallback processes KYC provider callbacks
-func (s *Service) ProcessKYCCallback(ctx context.Context, providerRef string, status entities.KYCStatus, rejectionReasons []string) error {
+func (s *Service) ProcessKYCCallback(ctx context.Context, providerRef string, update entities.KYCProviderUpdate) error {
+	status, rejectionReasons := update.Status, update.RejectionReasons
 	s.logger.Info("Processing KYC callback",
 		zap.String("providerRef", providerRef),
-		zap.String("status", string(status)))
+		zap.String("event", update.Event),
+		zap.String("status", string(status)),
+		zap.String("subStatus", string(update.SubStatus)))
 
 	// Get KYC submission
 	submission, err := s.kycSubmissionRepo.GetByProviderRef(ctx, providerRef)
@@ -541,8 +738,33 @@ func (s *Service) ProcessKYCCallback(ctx context.Context, providerRef string, st
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	before := map[string]any{"status": string(submission.Status), "sub_status": string(submission.SubStatus)}
+
+	// Intermediate steps only move the submission along; providers repeat
+	// them, so a step already recorded is not announced again
+	if status == entities.KYCStatusProcessing {
+		if submission.Status == status && submission.SubStatus == update.SubStatus {
+			s.logger.Info("KYC callback repeats the current step", zap.String("providerRef", providerRef))
+			return nil
+		}
+		submission.Status = status
+		submission.SubStatus = update.SubStatus
+		submission.UpdatedAt = time.Now()
+		if err := s.kycSubmissionRepo.Update(ctx, submission); err != nil {
+			return fmt.Errorf("failed to update KYC submission: %w", err)
+		}
+		s.notifyKYCProgress(ctx, user.ID, status, update.SubStatus)
+		if err := s.auditService.LogOnboardingEvent(ctx, user.ID, "kyc_progress", "kyc_submission", before,
+			map[string]any{"status": string(status), "sub_status": string(update.SubStatus), "event": update.Event}); err != nil {
+			s.logger.Warn("Failed to log audit event", zap.Error(err))
+		}
+		return nil
+	}
+
 	// Update submission
 	submission.MarkReviewed(status, rejectionReasons)
+	submission.SubStatus = update.SubStatus
+	s.reviewKYCDocuments(ctx, submission, status)
 	if err := s.kycSubmissionRepo.Update(ctx, submission); err != nil {
 		return fmt.Errorf("failed to update KYC submission: %w", err)
 	}
@@ -576,8 +798,7 @@ func (s *Service) ProcessKYCCallback(ctx context.Context, providerRef string, st
 		}
 
 	default:
-		// For processing status, no onboarding status change
-		s.logger.Info("KYC still processing", zap.String("status", string(status)))
+		s.logger.Info("KYC callback with no decision", zap.String("status", string(status)))
 		return nil
 	}
 
@@ -591,10 +812,26 @@ func (s *Service) ProcessKYCCallback(ctx context.Context, providerRef string, st
 		s.logger.Warn("Failed to send KYC status email", zap.Error(err))
 	}
 
+	if status == entities.KYCStatusApproved && s.events != nil {
+		if err := s.events.Publish(ctx, entities.WebhookEventKYCApproved, map[string]any{
+			"user_id":     user.ID,
+			"approved_at": kycApprovedAt,
+		}); err != nil {
+			s.logger.Warn("Failed to publish KYC approved event", zap.Error(err))
+		}
+	}
+
+	for _, observer := range s.kycObservers {
+		if err := observer.HandleKYCReview(ctx, user.ID, status, rejectionReasons); err != nil {
+			s.logger.Warn("KYC review observer failed", zap.String("userId", user.ID.String()), zap.Error(err))
+		}
+	}
+
+	s.notifyKYCProgress(ctx, user.ID, status, update.SubStatus)
+
 	// Log audit event
-	if err := s.auditService.LogOnboardingEvent(ctx, user.ID, "kyc_reviewed", "kyc_submission",
-		map[string]any{"status": "processing"},
-		map[string]any{"status": string(status), "rejection_reasons": rejectionReasons}); err != nil {
+	if err := s.auditService.LogOnboardingEvent(ctx, user.ID, "kyc_reviewed", "kyc_submission", before,
+		map[string]any{"status": string(status), "sub_status": string(update.SubStatus), "rejection_reasons": rejectionReasons}); err != nil {
 		s.logger.Warn("Failed to log audit event", zap.Error(err))
 	}
 
@@ -605,6 +842,53 @@ func (s *Service) ProcessKYCCallback(ctx context.Context, providerRef string, st
 	return nil
 }
 
+// notifyKYCProgress tells the user their KYC review moved to a new step.
+// Steps that need the user to act are sent as push notifications.
+func (s *Service) notifyKYCProgress(ctx context.Context, userID uuid.UUID, status entities.KYCStatus, subStatus entities.KYCSubStatus) {
+	if s.notifier == nil {
+		return
+	}
+
+	channel, priority := entities.ChannelPush, entities.PriorityHigh
+	var title string
+	switch {
+	case subStatus == entities.KYCSubStatusDocumentsRequested:
+		title = "Documents needed"
+	case subStatus == entities.KYCSubStatusUnderReview:
+		title = "Verification in progress"
+		channel, priority = entities.ChannelInApp, entities.PriorityLow
+	case subStatus == entities.KYCSubStatusOnHold:
+		title = "Verification on hold"
+		priority = entities.PriorityMedium
+	case subStatus == entities.KYCSubStatusResubmissionRequired:
+		title = "New documents needed"
+	case status == entities.KYCStatusApproved:
+		title = "Identity verified"
+	case status == entities.KYCStatusRejected:
+		title = "Verification unsuccessful"
+	default:
+		return
+	}
+
+	notification := &entities.Notification{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Type:     entities.NotificationTypeKYC,
+		Channel:  channel,
+		Priority: priority,
+		Title:    title,
+		Message:  entities.KYCProgressMessage(status, subStatus),
+		Data: map[string]interface{}{
+			"kyc_status":     string(status),
+			"kyc_sub_status": string(subStatus),
+		},
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.notifier.Send(ctx, notification, &entities.UserPreference{UserID: userID}); err != nil {
+		s.logger.Warn("Failed to send KYC progress notification", zap.String("userId", userID.String()), zap.Error(err))
+	}
+}
+
 // GetKYCStatus returns an aggregate view of the user's KYC standing
 func (s *Service) GetKYCStatus(ctx context.Context, userID uuid.UUID) (*entities.KYCStatusResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -636,6 +920,23 @@ func (s *Service) GetKYCStatus(ctx context.Context, userID uuid.UUID) (*entities
 		nextSteps = append(nextSteps, "Resubmit your KYC documents to refresh your verification")
 	}
 
+	subStatus := entities.KYCSubStatusNone
+	if submission, err := s.kycSubmissionRepo.GetLatestByUserID(ctx, userID); err == nil {
+		if submission.Status == kycStatus {
+			subStatus = submission.SubStatus
+		}
+	} else if !errors.Is(err, entities.ErrKYCSubmissionNotFound) {
+		s.logger.Warn("Failed to get latest KYC submission", zap.String("userId", userID.String()), zap.Error(err))
+	}
+	switch subStatus {
+	case entities.KYCSubStatusDocumentsRequested:
+		nextSteps = []string{"Upload the documents requested by our verification partner"}
+	case entities.KYCSubStatusOnHold:
+		nextSteps = []string{"No action needed; your verification is being checked manually"}
+	case entities.KYCSubStatusResubmissionRequired:
+		nextSteps = []string{"Replace the rejected documents to send your verification back for review"}
+	}
+
 	response := &entities.KYCStatusResponse{
 		UserID:            user.ID,
 		Status:            status,
@@ -648,6 +949,8 @@ func (s *Service) GetKYCStatus(ctx context.Context, userID uuid.UUID) (*entities
 		RejectionReason:   user.KYCRejectionReason,
 		ProviderReference: user.KYCProviderRef,
 		NextSteps:         nextSteps,
+		SubStatus:         subStatus,
+		ProgressMessage:   entities.KYCProgressMessage(kycStatus, subStatus),
 	}
 
 	return response, nil
@@ -688,6 +991,12 @@ func (s *Service) ProcessWalletCreationComplete(ctx context.Context, userID uuid
 		return fmt.Errorf("failed to update onboarding status: %w", err)
 	}
 
+	if s.experiments != nil {
+		if err := s.experiments.RecordConversion(ctx, userID, entities.ExperimentGoalOnboardingCompleted); err != nil {
+			s.logger.Warn("Failed to record onboarding experiment conversion", zap.Error(err), zap.String("userId", userID.String()))
+		}
+	}
+
 	// Send welcome email
 	if err := s.emailService.SendWelcomeEmail(ctx, user.Email); err != nil {
 		s.logger.Warn("Failed to send welcome email", zap.Error(err))
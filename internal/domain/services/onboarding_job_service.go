@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,10 +12,19 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 )
 
+// ErrOnboardingJobNotStuck is returned when requeueing a job that is queued,
+// completed, or still within its in-progress window
+var ErrOnboardingJobNotStuck = errors.New("onboarding job is not failed or stuck")
+
+// DefaultOnboardingJobStuckAfter is how long a job may stay in progress
+// before it is shown as stuck
+const DefaultOnboardingJobStuckAfter = 30 * time.Minute
+
 // OnboardingJobService handles onboarding job business logic
 type OnboardingJobService struct {
-	jobRepo OnboardingJobRepository
-	logger  *zap.Logger
+	jobRepo    OnboardingJobRepository
+	logger     *zap.Logger
+	stuckAfter time.Duration
 }
 
 // OnboardingJobRepository interface for dependency injection
@@ -26,16 +36,31 @@ type OnboardingJobRepository interface {
 	Update(ctx context.Context, job *entities.OnboardingJob) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetJobStats(ctx context.Context) (map[string]int, error)
+	ListStuck(ctx context.Context, inProgressBefore time.Time, limit int) ([]*entities.OnboardingJob, error)
 }
 
 // NewOnboardingJobService creates a new onboarding job service
 func NewOnboardingJobService(jobRepo OnboardingJobRepository, logger *zap.Logger) *OnboardingJobService {
 	return &OnboardingJobService{
-		jobRepo: jobRepo,
-		logger:  logger,
+		jobRepo:    jobRepo,
+		logger:     logger,
+		stuckAfter: DefaultOnboardingJobStuckAfter,
 	}
 }
 
+// SetStuckAfter sets how long a job may stay in progress before it is
+// treated as abandoned
+func (s *OnboardingJobService) SetStuckAfter(d time.Duration) {
+	if d > 0 {
+		s.stuckAfter = d
+	}
+}
+
+// StuckAfter returns how long a job may stay in progress before it is stuck
+func (s *OnboardingJobService) StuckAfter() time.Duration {
+	return s.stuckAfter
+}
+
 // CreateOnboardingJob creates a new onboarding job for a user
 func (s *OnboardingJobService) CreateOnboardingJob(ctx context.Context, userID uuid.UUID, userEmail, userPhone string) (*entities.OnboardingJob, error) {
 	s.logger.Info("Creating onboarding job",
@@ -203,6 +228,47 @@ func (s *OnboardingJobService) GetJobStats(ctx context.Context) (map[string]int,
 	return stats, nil
 }
 
+// ListStuckJobs returns failed jobs and jobs left in progress past the stuck
+// threshold, oldest first
+func (s *OnboardingJobService) ListStuckJobs(ctx context.Context, limit int) ([]*entities.OnboardingJob, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	jobs, err := s.jobRepo.ListStuck(ctx, time.Now().Add(-s.stuckAfter), limit)
+	if err != nil {
+		s.logger.Error("Failed to list stuck onboarding jobs", zap.Error(err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// RequeueJob puts a failed or stuck job back on the queue. Steps that
+// completed are skipped when it runs again; compensated steps are redone.
+func (s *OnboardingJobService) RequeueJob(ctx context.Context, jobID uuid.UUID) (*entities.OnboardingJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	stuck := job.Status == entities.OnboardingJobStatusInProgress &&
+		time.Since(job.UpdatedAt) > s.stuckAfter
+	if job.Status != entities.OnboardingJobStatusFailed && !stuck {
+		return nil, ErrOnboardingJobNotStuck
+	}
+
+	previous := job.Status
+	job.Requeue()
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to requeue onboarding job: %w", err)
+	}
+
+	s.logger.Info("Requeued onboarding job",
+		zap.String("job_id", job.ID.String()),
+		zap.String("user_id", job.UserID.String()),
+		zap.String("previous_status", string(previous)))
+	return job, nil
+}
+
 // maskEmail masks email for logging
 func (s *OnboardingJobService) maskEmail(email string) string {
 	if len(email) < 5 {
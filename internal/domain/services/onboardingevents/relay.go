@@ -0,0 +1,187 @@
+// Package onboardingevents publishes onboarding, KYC and wallet status
+// changes from the onboarding outbox to the event bus, so the mobile BFF and
+// partner webhooks learn about them without polling /onboarding/status.
+//
+// Changes are written to the outbox by database triggers in the same
+// transaction as the change. The relay publishes each entry at least once,
+// always with the entry's ID as the event ID, and marks it published; a
+// publish that is repeated after a crash carries the same ID, so consumers
+// that dedupe on it (webhook deliveries do) see every change exactly once.
+package onboardingevents
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/retry"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository reads and updates the onboarding outbox
+type Repository interface {
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.OnboardingOutboxEntry, error)
+	MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// Publisher is the subset of the event bus the relay uses
+type Publisher interface {
+	Publish(ctx context.Context, topic, key string, payload interface{}) error
+}
+
+// Config controls the relay
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	Lease          time.Duration // How long a claimed batch is hidden from other relays
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Retention      time.Duration // Published entries are deleted after this long
+}
+
+// DefaultConfig returns the default relay configuration
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:   5 * time.Second,
+		BatchSize:      100,
+		Lease:          time.Minute,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Retention:      72 * time.Hour,
+	}
+}
+
+// Relay moves outbox entries onto the event bus
+type Relay struct {
+	repo    Repository
+	bus     Publisher
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+	now     func() time.Time
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(repo Repository, bus Publisher, config Config, logger *zap.Logger) *Relay {
+	defaults := DefaultConfig()
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+	if config.Retention <= 0 {
+		config.Retention = defaults.Retention
+	}
+	return &Relay{
+		repo:   repo,
+		bus:    bus,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetTracker reports relay passes to the worker registry, which can pause them
+func (r *Relay) SetTracker(tracker *workerstatus.Tracker) {
+	r.tracker = tracker
+}
+
+// Interval returns the time between relay passes
+func (r *Relay) Interval() time.Duration {
+	return r.config.PollInterval
+}
+
+// Start relays due entries on every tick until ctx is cancelled
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := r.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := r.RelayDue(ctx)
+				finish(err)
+				if err != nil {
+					r.logger.Warn("Onboarding outbox relay pass failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RelayDue publishes one batch of due entries and returns how many were
+// published. When an entry fails to publish, the user's later entries in the
+// batch are held back so they are not published ahead of it.
+func (r *Relay) RelayDue(ctx context.Context) (int, error) {
+	now := r.now()
+	entries, err := r.repo.ClaimDue(ctx, now, r.config.Lease, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[uuid.UUID]bool)
+	for _, entry := range entries {
+		if blocked[entry.UserID] {
+			continue
+		}
+
+		if err := r.bus.Publish(ctx, entities.TopicOnboardingChanged, entry.UserID.String(), entry.Event()); err != nil {
+			blocked[entry.UserID] = true
+			next := now.Add(retry.CalculateExponential(r.config.InitialBackoff, 2, entry.Attempts+1, r.config.MaxBackoff))
+			r.logger.Warn("Failed to publish onboarding change",
+				zap.String("entry_id", entry.ID.String()),
+				zap.String("user_id", entry.UserID.String()),
+				zap.Int("attempts", entry.Attempts+1),
+				zap.Error(err))
+			if markErr := r.repo.MarkFailed(ctx, entry.ID, err.Error(), next); markErr != nil {
+				r.logger.Error("Failed to record onboarding outbox failure", zap.Error(markErr))
+			}
+			continue
+		}
+
+		if err := r.repo.MarkPublished(ctx, entry.ID, r.now()); err != nil {
+			// The entry is published again when its lease expires, with the
+			// same event ID, so consumers still see it once
+			blocked[entry.UserID] = true
+			r.logger.Error("Failed to mark onboarding outbox entry published",
+				zap.String("entry_id", entry.ID.String()),
+				zap.Error(err))
+			continue
+		}
+		published++
+	}
+
+	if len(entries) < r.config.BatchSize {
+		// Only prune once the backlog is drained, off the hot path
+		if deleted, err := r.repo.DeletePublishedBefore(ctx, now.Add(-r.config.Retention)); err != nil {
+			r.logger.Warn("Failed to prune onboarding outbox", zap.Error(err))
+		} else if deleted > 0 {
+			r.logger.Debug("Pruned onboarding outbox", zap.Int64("deleted", deleted))
+		}
+	}
+
+	return published, nil
+}
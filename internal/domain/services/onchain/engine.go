@@ -2,6 +2,7 @@ package onchain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/stack-service/stack_service/internal/infrastructure/circle"
 
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
 )
 
 // AllocationService defines the interface for allocation operations
@@ -142,6 +144,7 @@ func (e *Engine) ProcessDeposit(ctx context.Context, req *DepositRequest) error
 	// Check if deposit already exists (idempotency)
 	existing, err := e.depositRepo.GetByTxHash(ctx, req.TxHash)
 	if err == nil && existing != nil {
+		metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceCircle).Inc()
 		e.logger.Info("Deposit already processed (idempotent)",
 			"deposit_id", existing.ID,
 			"status", existing.Status)
@@ -179,6 +182,12 @@ func (e *Engine) ProcessDeposit(ctx context.Context, req *DepositRequest) error
 	}
 
 	if err := e.depositRepo.Create(ctx, deposit); err != nil {
+		if errors.Is(err, entities.ErrDuplicateDeposit) {
+			// A replayed webhook won the race; it posts the ledger entries
+			metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceCircle).Inc()
+			e.logger.Info("Deposit already processed (idempotent)", "tx_hash", req.TxHash)
+			return nil
+		}
 		return fmt.Errorf("failed to create deposit record: %w", err)
 	}
 
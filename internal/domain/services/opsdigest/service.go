@@ -0,0 +1,227 @@
+package opsdigest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrFutureDate is returned when a digest is requested for a day that has not started
+var ErrFutureDate = errors.New("digest date is in the future")
+
+// Repository aggregates a day of activity and records deliveries
+type Repository interface {
+	Collect(ctx context.Context, digest *entities.OpsDigest) error
+	ClaimDelivery(ctx context.Context, date time.Time) (bool, error)
+	CompleteDelivery(ctx context.Context, date time.Time, recipients int, deliveryErr error) error
+}
+
+// Mailer sends the digest email
+type Mailer interface {
+	SendCustomEmail(ctx context.Context, to, subject, htmlContent, textContent string) error
+}
+
+// Publisher delivers the digest to subscribed webhook endpoints
+type Publisher interface {
+	PublishEvent(ctx context.Context, eventID uuid.UUID, eventType entities.WebhookEventType, data interface{}) error
+}
+
+// Config configures who receives the digest and when
+type Config struct {
+	Recipients []string      // Admin email addresses
+	SendHour   int           // UTC hour after which the previous day's digest is sent
+	Interval   time.Duration // How often the worker checks whether a digest is due
+}
+
+// DefaultConfig sends at 06:00 UTC, checking every 15 minutes
+func DefaultConfig() Config {
+	return Config{SendHour: 6, Interval: 15 * time.Minute}
+}
+
+// digestNamespace derives stable webhook event IDs from digest dates, so a
+// re-sent digest is not delivered twice to the same endpoint
+var digestNamespace = uuid.MustParse("6f1c2b9e-3d4a-5e6f-8a7b-9c0d1e2f3a4b")
+
+// Service builds the daily ops digest and delivers it by email and webhook
+type Service struct {
+	repo      Repository
+	mailer    Mailer
+	publisher Publisher
+	config    Config
+	logger    *zap.Logger
+}
+
+// NewService creates an ops digest service. mailer and publisher may be nil,
+// in which case that channel is skipped.
+func NewService(repo Repository, mailer Mailer, publisher Publisher, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.SendHour < 0 || config.SendHour > 23 {
+		config.SendHour = defaults.SendHour
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:      repo,
+		mailer:    mailer,
+		publisher: publisher,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Interval returns how often the worker should check for a due digest
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Build returns the digest for the UTC day containing date. Today's digest
+// covers the day so far.
+func (s *Service) Build(ctx context.Context, date time.Time) (*entities.OpsDigest, error) {
+	now := time.Now().UTC()
+	from := startOfDay(date)
+	if from.After(now) {
+		return nil, ErrFutureDate
+	}
+	to := from.Add(24 * time.Hour)
+	if to.After(now) {
+		to = now
+	}
+
+	digest := &entities.OpsDigest{
+		Date:        from.Format("2006-01-02"),
+		From:        from,
+		To:          to,
+		GeneratedAt: now,
+	}
+	if err := s.repo.Collect(ctx, digest); err != nil {
+		return nil, err
+	}
+	if reviewed := digest.KYC.Approved + digest.KYC.Rejected; reviewed > 0 {
+		digest.KYC.PassRate = float64(digest.KYC.Approved) / float64(reviewed)
+	}
+	jobs := &digest.FailedJobs
+	jobs.Total = jobs.Onboarding + jobs.WalletProvisioning + jobs.FundingEvents + jobs.WebhookDeliveries
+	return digest, nil
+}
+
+// RunDue sends the digest for the day before now once the send hour has
+// passed. Instances claim each day before sending, so only one delivers it.
+func (s *Service) RunDue(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	if now.Hour() < s.config.SendHour {
+		return nil
+	}
+	yesterday := startOfDay(now).Add(-24 * time.Hour)
+
+	claimed, err := s.repo.ClaimDelivery(ctx, yesterday)
+	if err != nil || !claimed {
+		return err
+	}
+
+	_, recipients, sendErr := s.Send(ctx, yesterday)
+	if err := s.repo.CompleteDelivery(ctx, yesterday, recipients, sendErr); err != nil {
+		s.logger.Warn("Failed to record ops digest delivery", zap.Error(err))
+	}
+	return sendErr
+}
+
+// Send builds the digest for the given day and delivers it to every configured
+// recipient and subscribed webhook endpoint. It returns the number of emails
+// sent; an error means at least one channel failed.
+func (s *Service) Send(ctx context.Context, date time.Time) (*entities.OpsDigest, int, error) {
+	digest, err := s.Build(ctx, date)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var errs []error
+	sent := 0
+	if s.mailer != nil && len(s.config.Recipients) > 0 {
+		subject := fmt.Sprintf("Ops digest for %s", digest.Date)
+		htmlBody, textBody := renderHTML(digest), renderText(digest)
+		for _, recipient := range s.config.Recipients {
+			if err := s.mailer.SendCustomEmail(ctx, recipient, subject, htmlBody, textBody); err != nil {
+				s.logger.Error("Failed to email ops digest", zap.String("date", digest.Date), zap.Error(err))
+				errs = append(errs, fmt.Errorf("email: %w", err))
+				continue
+			}
+			sent++
+		}
+	}
+	if s.publisher != nil {
+		eventID := uuid.NewSHA1(digestNamespace, []byte(digest.Date))
+		if err := s.publisher.PublishEvent(ctx, eventID, entities.WebhookEventOpsDigest, digest); err != nil {
+			s.logger.Error("Failed to publish ops digest", zap.String("date", digest.Date), zap.Error(err))
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+
+	s.logger.Info("Ops digest sent",
+		zap.String("date", digest.Date),
+		zap.Int("emails", sent),
+		zap.Int("signups", digest.Signups),
+		zap.Int("failed_jobs", digest.FailedJobs.Total))
+	return digest, sent, errors.Join(errs...)
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// digestLines is the digest as label/value pairs, shared by both email bodies
+func digestLines(d *entities.OpsDigest) [][2]string {
+	lines := [][2]string{
+		{"New signups", fmt.Sprint(d.Signups)},
+		{"KYC pass rate", fmt.Sprintf("%.1f%% (%d approved, %d rejected)", d.KYC.PassRate*100, d.KYC.Approved, d.KYC.Rejected)},
+		{"Deposits", fmt.Sprintf("%d totalling $%s, %d failed", d.Deposits.Count, d.Deposits.Volume, d.Deposits.Failed)},
+		{"Withdrawals", fmt.Sprintf("%d totalling $%s, %d failed", d.Withdrawals.Count, d.Withdrawals.Volume, d.Withdrawals.Failed)},
+		{"Failed jobs", fmt.Sprintf("%d (onboarding %d, wallet provisioning %d, funding events %d, webhook deliveries %d)",
+			d.FailedJobs.Total, d.FailedJobs.Onboarding, d.FailedJobs.WalletProvisioning, d.FailedJobs.FundingEvents, d.FailedJobs.WebhookDeliveries)},
+	}
+
+	recon := fmt.Sprintf("%d, %d unresolved", d.Reconciliation.Exceptions, d.Reconciliation.Unresolved)
+	if len(d.Reconciliation.BySeverity) > 0 {
+		severities := make([]string, 0, len(d.Reconciliation.BySeverity))
+		for severity, count := range d.Reconciliation.BySeverity {
+			severities = append(severities, fmt.Sprintf("%s %d", severity, count))
+		}
+		sort.Strings(severities)
+		recon += " (" + strings.Join(severities, ", ") + ")"
+	}
+	lines = append(lines,
+		[2]string{"Reconciliation exceptions", recon},
+		[2]string{"AI usage", fmt.Sprintf("%d assistant replies to %d users, %d summaries",
+			d.AIUsage.ChatMessages, d.AIUsage.ActiveChatUsers, d.AIUsage.Summaries)},
+	)
+	return lines
+}
+
+func renderText(d *entities.OpsDigest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ops digest for %s (UTC)\n\n", d.Date)
+	for _, line := range digestLines(d) {
+		fmt.Fprintf(&b, "%s: %s\n", line[0], line[1])
+	}
+	return b.String()
+}
+
+func renderHTML(d *entities.OpsDigest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<h2>Ops digest for %s (UTC)</h2><table>", html.EscapeString(d.Date))
+	for _, line := range digestLines(d) {
+		fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>", html.EscapeString(line[0]), html.EscapeString(line[1]))
+	}
+	b.WriteString("</table>")
+	return b.String()
+}
@@ -0,0 +1,381 @@
+package orderops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+)
+
+var (
+	// ErrOrderNotFound is returned for an unknown order
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderNotStuck is returned when intervening on an order that is not in
+	// flight or has not yet exceeded the SLA
+	ErrOrderNotStuck = errors.New("order is not stuck in flight")
+	// ErrOrderMoved is returned when the order changed status while the
+	// intervention was in progress
+	ErrOrderMoved = errors.New("order changed status during the intervention")
+	// ErrBrokerCancelFailed is returned when the brokerage refuses to cancel
+	ErrBrokerCancelFailed = errors.New("brokerage did not cancel the order")
+	// ErrInvalidAdjustment is returned for a compensating entry that cannot be applied
+	ErrInvalidAdjustment = errors.New("invalid compensating adjustment")
+)
+
+// Repository reads stuck orders and records interventions on them
+type Repository interface {
+	ListStuck(ctx context.Context, before time.Time, limit int) ([]*entities.StuckOrder, error)
+	GetOrder(ctx context.Context, id uuid.UUID) (*entities.StuckOrder, error)
+	TransitionInFlight(ctx context.Context, id uuid.UUID, from, to entities.OrderStatus) (bool, error)
+	Record(ctx context.Context, intervention *entities.OrderIntervention) error
+	ListInterventions(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderIntervention, error)
+}
+
+// Brokerage cancels orders and reports their fills
+type Brokerage interface {
+	GetOrderStatus(ctx context.Context, brokerageRef string) (*investing.BrokerageOrderStatus, error)
+	CancelOrder(ctx context.Context, brokerageRef string) error
+}
+
+// Balances adjusts users' buying power
+type Balances interface {
+	DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+	AddBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+}
+
+// Positions reads and writes users' basket positions
+type Positions interface {
+	GetByUserAndBasket(ctx context.Context, userID, basketID uuid.UUID) (*entities.Position, error)
+	CreateOrUpdate(ctx context.Context, position *entities.Position) error
+}
+
+// AuditService records every intervention against the acting administrator
+type AuditService interface {
+	LogAction(ctx context.Context, userID *uuid.UUID, action, entity string, before, after interface{}) error
+}
+
+// Config configures when an order counts as stuck
+type Config struct {
+	SLA       time.Duration // How long an order may sit in flight without an update
+	ListLimit int           // Stuck orders returned per listing
+}
+
+// DefaultConfig flags orders in flight for more than 30 minutes
+func DefaultConfig() Config {
+	return Config{SLA: 30 * time.Minute, ListLimit: 200}
+}
+
+// Service lets super admins settle orders stuck waiting on the brokerage. A
+// cancel or repair first moves the order out of its in-flight status, so a
+// late fill cannot apply on top of it, then makes the compensating buying
+// power and position entries and records them with the admin's note.
+type Service struct {
+	repo      Repository
+	brokerage Brokerage
+	balances  Balances
+	positions Positions
+	audit     AuditService
+	config    Config
+	logger    *zap.Logger
+}
+
+// NewService creates an order intervention service
+func NewService(repo Repository, brokerage Brokerage, balances Balances, positions Positions, audit AuditService, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.SLA <= 0 {
+		config.SLA = defaults.SLA
+	}
+	if config.ListLimit <= 0 {
+		config.ListLimit = defaults.ListLimit
+	}
+	return &Service{
+		repo:      repo,
+		brokerage: brokerage,
+		balances:  balances,
+		positions: positions,
+		audit:     audit,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// SLA returns how long an order may sit in flight before it is listed
+func (s *Service) SLA() time.Duration {
+	return s.config.SLA
+}
+
+// ListStuck returns in-flight orders that have not moved within the SLA,
+// longest stuck first
+func (s *Service) ListStuck(ctx context.Context) ([]*entities.StuckOrder, error) {
+	now := time.Now()
+	orders, err := s.repo.ListStuck(ctx, now.Add(-s.config.SLA), s.config.ListLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		order.StuckFor = now.Sub(order.UpdatedAt).Truncate(time.Second).String()
+	}
+	return orders, nil
+}
+
+// Interventions returns the actions taken on an order
+func (s *Service) Interventions(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderIntervention, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.repo.ListInterventions(ctx, orderID)
+}
+
+// Cancel cancels a stuck order at the brokerage and settles what it had done:
+// fills the brokerage reports for a partially filled order are booked to the
+// position, and a buy's unfilled reservation is returned to buying power.
+func (s *Service) Cancel(ctx context.Context, adminID, orderID uuid.UUID, note string) (*entities.OrderIntervention, error) {
+	order, err := s.getStuck(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fills []entities.BrokerageFill
+	if order.BrokerageRef != nil {
+		if order.Status == entities.OrderStatusPartiallyFilled {
+			status, err := s.brokerage.GetOrderStatus(ctx, *order.BrokerageRef)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get fills from brokerage: %w", err)
+			}
+			fills = status.Fills
+		}
+		if err := s.brokerage.CancelOrder(ctx, *order.BrokerageRef); err != nil {
+			s.logger.Error("Brokerage refused to cancel stuck order", zap.String("order_id", orderID.String()), zap.Error(err))
+			return nil, fmt.Errorf("%w: %v", ErrBrokerCancelFailed, err)
+		}
+	}
+
+	filledQuantity, filledValue := sumFills(fills)
+	intervention := s.newIntervention(adminID, &order.Order, entities.OrderInterventionCancel, entities.OrderStatusCanceled, note)
+	if order.Side == entities.OrderSideBuy {
+		intervention.BuyingPowerDelta = decimal.Max(order.Amount.Sub(filledValue), decimal.Zero)
+		intervention.PositionQuantityDelta = filledQuantity
+		intervention.PositionValueDelta = filledValue
+	} else {
+		intervention.PositionQuantityDelta = filledQuantity.Neg()
+		intervention.PositionValueDelta = filledValue.Neg()
+	}
+
+	if err := s.settle(ctx, &order.Order, intervention); err != nil {
+		return nil, err
+	}
+	return intervention, nil
+}
+
+// Repair settles a stuck order by hand: it moves the order to the given final
+// status and applies the signed buying power and position deltas as
+// compensating entries
+func (s *Service) Repair(ctx context.Context, adminID, orderID uuid.UUID, req *entities.OrderRepairRequest) (*entities.OrderIntervention, error) {
+	switch req.Status {
+	case entities.OrderStatusFilled, entities.OrderStatusFailed, entities.OrderStatusCanceled:
+	default:
+		return nil, fmt.Errorf("%w: final status must be filled, failed or canceled", ErrInvalidAdjustment)
+	}
+	buyingPower, err := parseDelta("buying_power_delta", req.BuyingPowerDelta)
+	if err != nil {
+		return nil, err
+	}
+	quantity, err := parseDelta("position_quantity_delta", req.PositionQuantityDelta)
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseDelta("position_value_delta", req.PositionValueDelta)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.getStuck(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	intervention := s.newIntervention(adminID, &order.Order, entities.OrderInterventionRepair, req.Status, req.Note)
+	intervention.BuyingPowerDelta = buyingPower
+	intervention.PositionQuantityDelta = quantity
+	intervention.PositionValueDelta = value
+
+	if err := s.settle(ctx, &order.Order, intervention); err != nil {
+		return nil, err
+	}
+	return intervention, nil
+}
+
+// Annotate leaves a resolution note on an order without changing it
+func (s *Service) Annotate(ctx context.Context, adminID, orderID uuid.UUID, note string) (*entities.OrderIntervention, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	intervention := s.newIntervention(adminID, &order.Order, entities.OrderInterventionAnnotate, order.Status, note)
+	if err := s.record(ctx, intervention); err != nil {
+		return nil, err
+	}
+	return intervention, nil
+}
+
+// getStuck loads an order that is in flight and past the SLA
+func (s *Service) getStuck(ctx context.Context, orderID uuid.UUID) (*entities.StuckOrder, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if !order.Status.IsInFlight() || time.Since(order.UpdatedAt) < s.config.SLA {
+		return nil, ErrOrderNotStuck
+	}
+	return order, nil
+}
+
+// settle moves the order to its final status, then applies the intervention's
+// compensating entries and records it. The position is checked before the
+// order is touched so a bad adjustment leaves the order stuck.
+func (s *Service) settle(ctx context.Context, order *entities.Order, intervention *entities.OrderIntervention) error {
+	position, err := s.adjustedPosition(ctx, order, intervention.PositionQuantityDelta, intervention.PositionValueDelta)
+	if err != nil {
+		return err
+	}
+
+	moved, err := s.repo.TransitionInFlight(ctx, order.ID, order.Status, intervention.NewStatus)
+	if err != nil {
+		return err
+	}
+	if !moved {
+		return ErrOrderMoved
+	}
+
+	// The order has left flight, so failures from here are logged against the
+	// intervention rather than undoing it
+	switch delta := intervention.BuyingPowerDelta; {
+	case delta.IsPositive():
+		err = s.balances.AddBuyingPower(ctx, order.UserID, delta)
+	case delta.IsNegative():
+		err = s.balances.DeductBuyingPower(ctx, order.UserID, delta.Neg())
+	}
+	if err != nil {
+		s.logger.Error("Failed to apply buying power adjustment",
+			zap.String("order_id", order.ID.String()),
+			zap.String("delta", intervention.BuyingPowerDelta.String()),
+			zap.Error(err))
+		return fmt.Errorf("order %s moved to %s but buying power was not adjusted: %w", order.ID, intervention.NewStatus, err)
+	}
+	if position != nil {
+		if err := s.positions.CreateOrUpdate(ctx, position); err != nil {
+			s.logger.Error("Failed to apply position adjustment", zap.String("order_id", order.ID.String()), zap.Error(err))
+			return fmt.Errorf("order %s moved to %s but the position was not adjusted: %w", order.ID, intervention.NewStatus, err)
+		}
+	}
+
+	return s.record(ctx, intervention)
+}
+
+func (s *Service) record(ctx context.Context, intervention *entities.OrderIntervention) error {
+	if err := s.repo.Record(ctx, intervention); err != nil {
+		return err
+	}
+
+	s.logger.Warn("Order intervention applied",
+		zap.String("order_id", intervention.OrderID.String()),
+		zap.String("admin_id", intervention.AdminID.String()),
+		zap.String("action", string(intervention.Action)),
+		zap.String("previous_status", string(intervention.PreviousStatus)),
+		zap.String("new_status", string(intervention.NewStatus)))
+	if s.audit != nil {
+		if err := s.audit.LogAction(ctx, &intervention.AdminID, "order_"+string(intervention.Action), "order",
+			map[string]interface{}{"order_id": intervention.OrderID, "status": intervention.PreviousStatus},
+			intervention); err != nil {
+			s.logger.Warn("Failed to audit order intervention", zap.String("order_id", intervention.OrderID.String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *Service) newIntervention(adminID uuid.UUID, order *entities.Order, action entities.OrderInterventionAction, status entities.OrderStatus, note string) *entities.OrderIntervention {
+	return &entities.OrderIntervention{
+		ID:             uuid.New(),
+		OrderID:        order.ID,
+		AdminID:        adminID,
+		Action:         action,
+		PreviousStatus: order.Status,
+		NewStatus:      status,
+		Note:           strings.TrimSpace(note),
+		CreatedAt:      time.Now(),
+	}
+}
+
+// adjustedPosition returns the order's basket position with the deltas
+// applied, or nil if there is nothing to change
+func (s *Service) adjustedPosition(ctx context.Context, order *entities.Order, quantity, value decimal.Decimal) (*entities.Position, error) {
+	if quantity.IsZero() && value.IsZero() {
+		return nil, nil
+	}
+	position, err := s.positions.GetByUserAndBasket(ctx, order.UserID, order.BasketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position: %w", err)
+	}
+	if position == nil {
+		position = &entities.Position{
+			ID:       uuid.New(),
+			UserID:   order.UserID,
+			BasketID: order.BasketID,
+		}
+	}
+
+	adjusted := *position
+	adjusted.Quantity = position.Quantity.Add(quantity)
+	adjusted.MarketValue = position.MarketValue.Add(value)
+	if adjusted.Quantity.IsNegative() || adjusted.MarketValue.IsNegative() {
+		return nil, fmt.Errorf("%w: position would go negative", ErrInvalidAdjustment)
+	}
+	if adjusted.Quantity.IsPositive() {
+		adjusted.AvgPrice = adjusted.MarketValue.Div(adjusted.Quantity)
+	} else {
+		adjusted.AvgPrice = decimal.Zero
+	}
+	adjusted.UpdatedAt = time.Now()
+	return &adjusted, nil
+}
+
+func parseDelta(field, raw string) (decimal.Decimal, error) {
+	if strings.TrimSpace(raw) == "" {
+		return decimal.Zero, nil
+	}
+	delta, err := decimal.NewFromString(strings.TrimSpace(raw))
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%w: %s is not a number", ErrInvalidAdjustment, field)
+	}
+	return delta, nil
+}
+
+func sumFills(fills []entities.BrokerageFill) (decimal.Decimal, decimal.Decimal) {
+	quantity, value := decimal.Zero, decimal.Zero
+	for _, fill := range fills {
+		q, _ := decimal.NewFromString(fill.Quantity)
+		p, _ := decimal.NewFromString(fill.Price)
+		quantity = quantity.Add(q)
+		value = value.Add(q.Mul(p))
+	}
+	return quantity, value
+}
@@ -0,0 +1,533 @@
+package outboundwebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/retry"
+	"github.com/stack-service/stack_service/pkg/webhook"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Sentinel errors returned to the admin API
+var (
+	ErrInvalidEndpoint  = errors.New("invalid webhook endpoint")
+	ErrUnknownEventType = errors.New("unknown webhook event type")
+)
+
+// maxStoredResponseBody caps how much of a partner's response is kept in the delivery log
+const maxStoredResponseBody = 2048
+
+// Repository persists endpoints and deliveries
+type Repository interface {
+	CreateEndpoint(ctx context.Context, endpoint *entities.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*entities.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]*entities.WebhookEndpoint, error)
+	ListTenantEndpoints(ctx context.Context, tenantID uuid.UUID) ([]*entities.WebhookEndpoint, error)
+	// ListSubscribedEndpoints excludes developer tenant endpoints, which
+	// never receive production events
+	ListSubscribedEndpoints(ctx context.Context, eventType entities.WebhookEventType) ([]*entities.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *entities.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	CreateDelivery(ctx context.Context, d *entities.WebhookDelivery) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, d *entities.WebhookDelivery) error
+	Reschedule(ctx context.Context, id uuid.UUID, at time.Time) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*entities.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, endpointID *uuid.UUID, status entities.WebhookDeliveryStatus, limit, offset int) ([]*entities.WebhookDelivery, error)
+}
+
+// Config controls delivery behaviour
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	AllowHTTP      bool // permit plain http endpoints, for local development only
+}
+
+// DefaultConfig returns the default delivery configuration. Twelve attempts
+// with a 30s backoff doubling up to a 6h cap spread retries over about 15 hours.
+func DefaultConfig() Config {
+	return Config{
+		PollInterval:   10 * time.Second,
+		BatchSize:      50,
+		MaxAttempts:    12,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     6 * time.Hour,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// Service registers partner endpoints and delivers signed events to them
+type Service struct {
+	repo       Repository
+	httpClient *http.Client
+	config     Config
+	logger     *zap.Logger
+	tracker    *workerstatus.Tracker
+}
+
+// NewService creates a new outbound webhook service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	return &Service{
+		repo:       repo,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// CreateEndpoint registers a partner endpoint and returns it with its signing
+// secret. The secret is only ever returned here and from RotateSecret.
+func (s *Service) CreateEndpoint(ctx context.Context, req *entities.CreateWebhookEndpointRequest, createdBy *uuid.UUID) (*entities.WebhookEndpointWithSecret, error) {
+	return s.createEndpoint(ctx, req, nil, createdBy)
+}
+
+// CreateTenantEndpoint registers an endpoint for a developer tenant's
+// sandbox. It only receives test events sent with SendTestEvent.
+func (s *Service) CreateTenantEndpoint(ctx context.Context, tenantID uuid.UUID, req *entities.CreateWebhookEndpointRequest, createdBy *uuid.UUID) (*entities.WebhookEndpointWithSecret, error) {
+	return s.createEndpoint(ctx, req, &tenantID, createdBy)
+}
+
+func (s *Service) createEndpoint(ctx context.Context, req *entities.CreateWebhookEndpointRequest, tenantID, createdBy *uuid.UUID) (*entities.WebhookEndpointWithSecret, error) {
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	endpoint := &entities.WebhookEndpoint{
+		ID:          uuid.New(),
+		URL:         req.URL,
+		Description: strings.TrimSpace(req.Description),
+		EventTypes:  dedupeEventTypes(req.EventTypes),
+		Secret:      secret,
+		IsActive:    true,
+		CreatedBy:   createdBy,
+		TenantID:    tenantID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Webhook endpoint registered",
+		zap.String("endpoint_id", endpoint.ID.String()),
+		zap.String("url", endpoint.URL))
+	return &entities.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// ListEndpoints returns all registered endpoints
+func (s *Service) ListEndpoints(ctx context.Context) ([]*entities.WebhookEndpoint, error) {
+	return s.repo.ListEndpoints(ctx)
+}
+
+// ListTenantEndpoints returns the endpoints a developer tenant registered
+func (s *Service) ListTenantEndpoints(ctx context.Context, tenantID uuid.UUID) ([]*entities.WebhookEndpoint, error) {
+	return s.repo.ListTenantEndpoints(ctx, tenantID)
+}
+
+// GetEndpoint returns a registered endpoint
+func (s *Service) GetEndpoint(ctx context.Context, id uuid.UUID) (*entities.WebhookEndpoint, error) {
+	return s.repo.GetEndpoint(ctx, id)
+}
+
+// UpdateEndpoint changes an endpoint's URL, subscription or active flag
+func (s *Service) UpdateEndpoint(ctx context.Context, id uuid.UUID, req *entities.UpdateWebhookEndpointRequest) (*entities.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := s.validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.EventTypes != nil {
+		if err := validateEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = dedupeEventTypes(req.EventTypes)
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+	endpoint.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// RotateSecret replaces an endpoint's signing secret. Deliveries already in
+// flight are signed with the new secret on their next attempt.
+func (s *Service) RotateSecret(ctx context.Context, id uuid.UUID) (*entities.WebhookEndpointWithSecret, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	endpoint.UpdatedAt = time.Now()
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Webhook endpoint secret rotated", zap.String("endpoint_id", id.String()))
+	return &entities.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log
+func (s *Service) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteEndpoint(ctx, id)
+}
+
+// Publish queues an event for every active endpoint subscribed to it. Delivery
+// happens asynchronously, so callers are never blocked on partner endpoints.
+func (s *Service) Publish(ctx context.Context, eventType entities.WebhookEventType, data interface{}) error {
+	return s.PublishEvent(ctx, uuid.New(), eventType, data)
+}
+
+// PublishEvent is Publish with a caller-supplied event ID. Re-publishing the
+// same ID is a no-op per endpoint, which makes redelivered bus messages safe.
+func (s *Service) PublishEvent(ctx context.Context, eventID uuid.UUID, eventType entities.WebhookEventType, data interface{}) error {
+	endpoints, err := s.repo.ListSubscribedEndpoints(ctx, eventType)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	event := entities.WebhookEvent{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	var errs []error
+	for _, endpoint := range endpoints {
+		if err := s.repo.CreateDelivery(ctx, newDelivery(endpoint.ID, event, payload)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendTestEvent queues a ping to a single endpoint regardless of its subscription
+func (s *Service) SendTestEvent(ctx context.Context, endpointID uuid.UUID) (*entities.WebhookDelivery, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := entities.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      entities.WebhookEventPing,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]interface{}{"endpoint_id": endpoint.ID},
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	delivery := newDelivery(endpoint.ID, event, payload)
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ListDeliveries returns the delivery log
+func (s *Service) ListDeliveries(ctx context.Context, endpointID *uuid.UUID, status entities.WebhookDeliveryStatus, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListDeliveries(ctx, endpointID, status, limit, offset)
+}
+
+// GetDelivery returns one delivery log entry
+func (s *Service) GetDelivery(ctx context.Context, id uuid.UUID) (*entities.WebhookDelivery, error) {
+	return s.repo.GetDelivery(ctx, id)
+}
+
+// RetryDelivery makes a failed or dead delivery due immediately
+func (s *Service) RetryDelivery(ctx context.Context, id uuid.UUID) (*entities.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == entities.WebhookDeliverySucceeded {
+		return delivery, nil
+	}
+	if err := s.repo.Reschedule(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDelivery(ctx, id)
+}
+
+// SetTracker reports delivery passes to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Start polls for due deliveries until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.DeliverDue(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Webhook delivery pass failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many were attempted
+func (s *Service) DeliverDue(ctx context.Context) (int, error) {
+	// The lease must outlive a full batch of timed-out requests
+	lease := s.config.Timeout*time.Duration(s.config.BatchSize) + time.Minute
+	deliveries, err := s.repo.ClaimDue(ctx, time.Now(), lease, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoints := make(map[uuid.UUID]*entities.WebhookEndpoint)
+	for _, delivery := range deliveries {
+		endpoint, ok := endpoints[delivery.EndpointID]
+		if !ok {
+			endpoint, err = s.repo.GetEndpoint(ctx, delivery.EndpointID)
+			if err != nil {
+				s.logger.Warn("Failed to load webhook endpoint", zap.Error(err),
+					zap.String("endpoint_id", delivery.EndpointID.String()))
+				continue
+			}
+			endpoints[delivery.EndpointID] = endpoint
+		}
+		s.attempt(ctx, endpoint, delivery)
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and records the outcome and the next retry
+func (s *Service) attempt(ctx context.Context, endpoint *entities.WebhookEndpoint, delivery *entities.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = nil
+	delivery.LastError = nil
+
+	var sendErr error
+	if !endpoint.IsActive {
+		sendErr = fmt.Errorf("endpoint is disabled")
+	} else {
+		sendErr = s.send(ctx, endpoint, delivery)
+	}
+
+	if sendErr == nil {
+		delivery.Status = entities.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	} else {
+		msg := sendErr.Error()
+		delivery.LastError = &msg
+		if delivery.Attempts >= s.config.MaxAttempts {
+			delivery.Status = entities.WebhookDeliveryDead
+			delivery.NextAttemptAt = nil
+			s.logger.Warn("Webhook delivery exhausted retries",
+				zap.String("delivery_id", delivery.ID.String()),
+				zap.String("endpoint_id", endpoint.ID.String()),
+				zap.Int("attempts", delivery.Attempts),
+				zap.Error(sendErr))
+		} else {
+			delivery.Status = entities.WebhookDeliveryFailed
+			next := now.Add(NextBackoff(s.config, delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := s.repo.RecordAttempt(ctx, delivery); err != nil {
+		s.logger.Error("Failed to record webhook attempt", zap.Error(err),
+			zap.String("delivery_id", delivery.ID.String()))
+	}
+}
+
+func (s *Service) send(ctx context.Context, endpoint *entities.WebhookEndpoint, delivery *entities.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Stack-Webhooks/1.0")
+	req.Header.Set("X-Stack-Event", string(delivery.EventType))
+	req.Header.Set("X-Stack-Event-Id", delivery.EventID.String())
+	req.Header.Set("X-Stack-Delivery-Id", delivery.ID.String())
+	req.Header.Set(webhook.OutboundSignatureHeader, webhook.SignOutbound(delivery.Payload, endpoint.Secret, time.Now()))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStoredResponseBody))
+	status := resp.StatusCode
+	delivery.ResponseStatus = &status
+	if len(body) > 0 {
+		text := string(body)
+		delivery.ResponseBody = &text
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", status)
+	}
+	return nil
+}
+
+func (s *Service) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: url must be absolute", ErrInvalidEndpoint)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if s.config.AllowHTTP {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: url must use https", ErrInvalidEndpoint)
+}
+
+// NextBackoff returns the delay before retrying after the given number of attempts
+func NextBackoff(config Config, attempts int) time.Duration {
+	return retry.CalculateExponential(config.InitialBackoff, 2, attempts, config.MaxBackoff)
+}
+
+func newDelivery(endpointID uuid.UUID, event entities.WebhookEvent, payload []byte) *entities.WebhookDelivery {
+	now := time.Now()
+	return &entities.WebhookDelivery{
+		ID:            uuid.New(),
+		EndpointID:    endpointID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       payload,
+		Status:        entities.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+}
+
+func validateEventTypes(types []entities.WebhookEventType) error {
+	if len(types) == 0 {
+		return fmt.Errorf("%w: at least one event type is required", ErrUnknownEventType)
+	}
+	for _, t := range types {
+		known := false
+		for _, s := range entities.SubscribableWebhookEvents {
+			if t == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: %s", ErrUnknownEventType, t)
+		}
+	}
+	return nil
+}
+
+func dedupeEventTypes(types []entities.WebhookEventType) []entities.WebhookEventType {
+	seen := make(map[entities.WebhookEventType]bool, len(types))
+	out := make([]entities.WebhookEventType, 0, len(types))
+	for _, t := range types {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
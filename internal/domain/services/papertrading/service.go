@@ -0,0 +1,223 @@
+package papertrading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrKYCRequired is returned when a user switches to live trading before
+	// their identity verification is approved
+	ErrKYCRequired = errors.New("identity verification must be approved before live trading")
+	// ErrLiveTradingUnavailable is returned when live trading is not yet
+	// approved in the user's country
+	ErrLiveTradingUnavailable = errors.New("live trading is not yet available in your country")
+)
+
+// Live trading is blocked by one of these until the user can switch
+const (
+	BlockedByKYC          = "kyc"
+	BlockedByJurisdiction = "jurisdiction"
+)
+
+// Repository persists trading modes and paper accounts
+type Repository interface {
+	GetTradingProfile(ctx context.Context, userID uuid.UUID) (*entities.TradingProfile, error)
+	SetTradingMode(ctx context.Context, userID uuid.UUID, mode entities.TradingMode) error
+	OpenAccount(ctx context.Context, userID uuid.UUID, startingBalance decimal.Decimal) error
+	GetAccount(ctx context.Context, userID uuid.UUID) (*entities.Balance, decimal.Decimal, error)
+}
+
+// FeatureGate reports which features the user's country allows
+type FeatureGate interface {
+	IsFeatureAllowed(ctx context.Context, userID uuid.UUID, feature entities.JurisdictionFeature) (bool, string, error)
+}
+
+// Config configures paper accounts
+type Config struct {
+	StartingBalance decimal.Decimal // Simulated cash a new paper account is funded with
+}
+
+// DefaultConfig funds paper accounts with $10,000
+func DefaultConfig() Config {
+	return Config{StartingBalance: decimal.NewFromInt(10000)}
+}
+
+// Service decides whether each user trades live or on paper. Any user may opt
+// into paper trading; users in countries where trading is still pre-approval
+// (paper_trading allowed, trading not) are placed in paper mode automatically
+// and cannot switch to live until the country is approved and their KYC is.
+type Service struct {
+	repo   Repository
+	gate   FeatureGate
+	config Config
+	logger *zap.Logger
+}
+
+// NewService creates a paper trading service. gate may be nil, in which case
+// no country is paper-only.
+func NewService(repo Repository, gate FeatureGate, config Config, logger *zap.Logger) *Service {
+	if !config.StartingBalance.IsPositive() {
+		config.StartingBalance = DefaultConfig().StartingBalance
+	}
+	return &Service{
+		repo:   repo,
+		gate:   gate,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Mode returns the mode the user's orders are placed in
+func (s *Service) Mode(ctx context.Context, userID uuid.UUID) (entities.TradingMode, error) {
+	profile, err := s.repo.GetTradingProfile(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return s.resolveMode(ctx, userID, profile)
+}
+
+func (s *Service) resolveMode(ctx context.Context, userID uuid.UUID, profile *entities.TradingProfile) (entities.TradingMode, error) {
+	if profile.Mode == entities.TradingModePaper {
+		return entities.TradingModePaper, nil
+	}
+
+	paperOnly, err := s.paperOnly(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if !paperOnly {
+		return entities.TradingModeLive, nil
+	}
+	// The user stays on paper once their country is approved, until they
+	// switch to live themselves
+	if err := s.enablePaper(ctx, userID); err != nil {
+		return "", err
+	}
+	s.logger.Info("Placed user in paper trading for pre-approval country", zap.String("user_id", userID.String()))
+	return entities.TradingModePaper, nil
+}
+
+// Status describes the user's trading mode, paper account and whether they can
+// switch to live trading
+func (s *Service) Status(ctx context.Context, userID uuid.UUID) (*entities.TradingModeStatus, error) {
+	profile, err := s.repo.GetTradingProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := s.resolveMode(ctx, userID, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &entities.TradingModeStatus{
+		UserID: userID,
+		Mode:   mode,
+		Paper:  mode == entities.TradingModePaper,
+	}
+	blockedBy, err := s.liveBlockedBy(ctx, userID, profile)
+	if err != nil {
+		return nil, err
+	}
+	status.PaperOnly = blockedBy == BlockedByJurisdiction
+	status.CanSwitchToLive = status.Paper && blockedBy == ""
+	status.LiveBlockedBy = blockedBy
+
+	if status.Paper {
+		balance, startingBalance, err := s.repo.GetAccount(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		buyingPower := balance.BuyingPower.StringFixed(2)
+		starting := startingBalance.StringFixed(2)
+		status.PaperBuyingPower = &buyingPower
+		status.StartingBalance = &starting
+	}
+	return status, nil
+}
+
+// EnablePaper moves the user to paper trading, funding a paper account the
+// first time
+func (s *Service) EnablePaper(ctx context.Context, userID uuid.UUID) (*entities.TradingModeStatus, error) {
+	if err := s.enablePaper(ctx, userID); err != nil {
+		return nil, err
+	}
+	s.logger.Info("User switched to paper trading", zap.String("user_id", userID.String()))
+	return s.Status(ctx, userID)
+}
+
+// SwitchToLive moves the user back to live trading once their KYC is approved
+// and trading is allowed in their country. The paper account is kept.
+func (s *Service) SwitchToLive(ctx context.Context, userID uuid.UUID) (*entities.TradingModeStatus, error) {
+	profile, err := s.repo.GetTradingProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch blockedBy, err := s.liveBlockedBy(ctx, userID, profile); {
+	case err != nil:
+		return nil, err
+	case blockedBy == BlockedByJurisdiction:
+		return nil, ErrLiveTradingUnavailable
+	case blockedBy == BlockedByKYC:
+		return nil, ErrKYCRequired
+	}
+
+	if err := s.repo.SetTradingMode(ctx, userID, entities.TradingModeLive); err != nil {
+		return nil, err
+	}
+	s.logger.Info("User switched to live trading", zap.String("user_id", userID.String()))
+	return s.Status(ctx, userID)
+}
+
+func (s *Service) enablePaper(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.OpenAccount(ctx, userID, s.config.StartingBalance); err != nil {
+		return err
+	}
+	return s.repo.SetTradingMode(ctx, userID, entities.TradingModePaper)
+}
+
+// liveBlockedBy returns what keeps the user from live trading, or "" if
+// nothing does. The country is checked first, as approving KYC alone does not
+// unblock it.
+func (s *Service) liveBlockedBy(ctx context.Context, userID uuid.UUID, profile *entities.TradingProfile) (string, error) {
+	if s.gate != nil {
+		allowed, _, err := s.gate.IsFeatureAllowed(ctx, userID, entities.FeatureTrading)
+		if err != nil {
+			return "", fmt.Errorf("failed to check trading availability: %w", err)
+		}
+		if !allowed {
+			return BlockedByJurisdiction, nil
+		}
+	}
+	if entities.KYCStatus(profile.KYCStatus) != entities.KYCStatusApproved {
+		return BlockedByKYC, nil
+	}
+	return "", nil
+}
+
+// paperOnly reports whether the user's country allows paper trading but not
+// yet live trading
+func (s *Service) paperOnly(ctx context.Context, userID uuid.UUID) (bool, error) {
+	if s.gate == nil {
+		return false, nil
+	}
+	trading, _, err := s.gate.IsFeatureAllowed(ctx, userID, entities.FeatureTrading)
+	if err != nil {
+		return false, fmt.Errorf("failed to check trading availability: %w", err)
+	}
+	if trading {
+		return false, nil
+	}
+	paper, _, err := s.gate.IsFeatureAllowed(ctx, userID, entities.FeaturePaperTrading)
+	if err != nil {
+		return false, fmt.Errorf("failed to check paper trading availability: %w", err)
+	}
+	return paper, nil
+}
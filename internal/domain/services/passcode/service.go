@@ -57,6 +57,7 @@ type Service struct {
 	maxAttempts  int
 	lockDuration time.Duration
 	sessionTTL   time.Duration
+	now          func() time.Time
 }
 
 // NewService constructs a new passcode service with defaults
@@ -72,9 +73,16 @@ func NewService(
 		maxAttempts:  defaultMaxAttempts,
 		lockDuration: defaultLockDuration,
 		sessionTTL:   defaultSessionTTL,
+		now:          time.Now,
 	}
 }
 
+// SetClock overrides the time source lockouts and sessions are measured
+// against, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
 // GetStatus returns the current passcode configuration for a user
 func (s *Service) GetStatus(ctx context.Context, userID uuid.UUID) (*entities.PasscodeStatusResponse, error) {
 	meta, err := s.userRepo.GetPasscodeMetadata(ctx, userID)
@@ -83,7 +91,7 @@ func (s *Service) GetStatus(ctx context.Context, userID uuid.UUID) (*entities.Pa
 	}
 
 	enabled := meta.HashedPasscode != nil && *meta.HashedPasscode != ""
-	locked := isLocked(meta)
+	locked := s.isLocked(meta)
 
 	status := &entities.PasscodeStatusResponse{
 		Enabled:           enabled,
@@ -116,7 +124,7 @@ func (s *Service) SetPasscode(ctx context.Context, userID uuid.UUID, passcode st
 		return nil, fmt.Errorf("failed to hash passcode: %w", err)
 	}
 
-	now := time.Now()
+	now := s.now()
 	if err := s.userRepo.UpdatePasscodeHash(ctx, userID, hash, now); err != nil {
 		return nil, fmt.Errorf("failed to persist passcode: %w", err)
 	}
@@ -140,7 +148,7 @@ func (s *Service) UpdatePasscode(ctx context.Context, userID uuid.UUID, current,
 		return nil, ErrPasscodeNotSet
 	}
 
-	if isLocked(meta) {
+	if s.isLocked(meta) {
 		return nil, ErrPasscodeLocked
 	}
 
@@ -160,7 +168,7 @@ func (s *Service) UpdatePasscode(ctx context.Context, userID uuid.UUID, current,
 		return nil, fmt.Errorf("failed to hash new passcode: %w", err)
 	}
 
-	now := time.Now()
+	now := s.now()
 	if err := s.userRepo.UpdatePasscodeHash(ctx, userID, hash, now); err != nil {
 		return nil, fmt.Errorf("failed to update passcode: %w", err)
 	}
@@ -186,7 +194,7 @@ func (s *Service) VerifyPasscode(ctx context.Context, userID uuid.UUID, passcode
 		return "", time.Time{}, ErrPasscodeNotSet
 	}
 
-	if isLocked(meta) {
+	if s.isLocked(meta) {
 		return "", time.Time{}, ErrPasscodeLocked
 	}
 
@@ -196,7 +204,7 @@ func (s *Service) VerifyPasscode(ctx context.Context, userID uuid.UUID, passcode
 			s.logger.Warn("Failed to record passcode failure during verification",
 				zap.Error(incErr),
 				zap.String("user_id", userID.String()))
-		} else if isLocked(newMeta) {
+		} else if s.isLocked(newMeta) {
 			return "", time.Time{}, ErrPasscodeLocked
 		}
 		return "", time.Time{}, ErrPasscodeMismatch
@@ -232,7 +240,7 @@ func (s *Service) RemovePasscode(ctx context.Context, userID uuid.UUID, passcode
 		return nil, ErrPasscodeNotSet
 	}
 
-	if isLocked(meta) {
+	if s.isLocked(meta) {
 		return nil, ErrPasscodeLocked
 	}
 
@@ -281,7 +289,7 @@ func (s *Service) recordFailedAttempt(ctx context.Context, userID uuid.UUID, met
 	var lockUntil *time.Time
 
 	if nextAttempt >= s.maxAttempts {
-		lock := time.Now().Add(s.lockDuration)
+		lock := s.now().Add(s.lockDuration)
 		lockUntil = &lock
 	}
 
@@ -299,7 +307,7 @@ func (s *Service) createSession(ctx context.Context, userID uuid.UUID) (*entitie
 		return nil, "", err
 	}
 
-	now := time.Now()
+	now := s.now()
 	session := &entities.PasscodeSession{
 		UserID:    userID,
 		IssuedAt:  now,
@@ -341,9 +349,9 @@ func validatePasscode(passcode string) error {
 	return nil
 }
 
-func isLocked(meta *entities.PasscodeMetadata) bool {
+func (s *Service) isLocked(meta *entities.PasscodeMetadata) bool {
 	if meta == nil || meta.LockedUntil == nil {
 		return false
 	}
-	return meta.LockedUntil.After(time.Now())
+	return meta.LockedUntil.After(s.now())
 }
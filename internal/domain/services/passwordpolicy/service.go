@@ -0,0 +1,214 @@
+package passwordpolicy
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// BreachChecker reports how often a password appears in known breaches
+type BreachChecker interface {
+	BreachCount(ctx context.Context, password string) (int, error)
+}
+
+// WeakPasswordStore remembers when a user was first seen signing in with a
+// password that breaks the policy. Changing the password clears it.
+type WeakPasswordStore interface {
+	// MarkPasswordWeak records at unless a time is already recorded, and
+	// returns the recorded time
+	MarkPasswordWeak(ctx context.Context, userID uuid.UUID, at time.Time) (time.Time, error)
+}
+
+// Config is the password policy
+type Config struct {
+	MinLength        int
+	MaxLength        int // bcrypt ignores anything past 72 bytes
+	MinCharClasses   int // Of lowercase, uppercase, digits and symbols
+	PassphraseLength int // Passwords this long are exempt from MinCharClasses
+	DisallowPersonal bool
+	BreachCheck      bool
+	GracePeriod      time.Duration // How long existing weak passwords may be kept after the first prompt
+}
+
+// DefaultConfig requires eight characters of three kinds, or a passphrase
+func DefaultConfig() Config {
+	return Config{
+		MinLength:        8,
+		MaxLength:        72,
+		MinCharClasses:   3,
+		PassphraseLength: 20,
+		DisallowPersonal: true,
+		BreachCheck:      true,
+		GracePeriod:      30 * 24 * time.Hour,
+	}
+}
+
+// Service checks new passwords against the policy at signup, change and
+// reset, and reviews the password a user signs in with so weak passwords set
+// before the policy was tightened are prompted for a change. The breach
+// check fails open: when the lookup is unavailable the other rules still
+// apply and the password is accepted.
+type Service struct {
+	breaches BreachChecker
+	weak     WeakPasswordStore
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewService creates a password policy service. breaches may be nil to skip
+// the breach check.
+func NewService(breaches BreachChecker, weak WeakPasswordStore, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MinLength <= 0 {
+		config.MinLength = defaults.MinLength
+	}
+	if config.MaxLength <= 0 || config.MaxLength > defaults.MaxLength {
+		config.MaxLength = defaults.MaxLength
+	}
+	if config.PassphraseLength <= 0 {
+		config.PassphraseLength = defaults.PassphraseLength
+	}
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = defaults.GracePeriod
+	}
+	return &Service{
+		breaches: breaches,
+		weak:     weak,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// MinLength is the shortest password the policy accepts
+func (s *Service) MinLength() int {
+	return s.config.MinLength
+}
+
+// Check returns a *entities.PasswordPolicyError listing every rule the
+// password breaks, or nil. personal holds the user's email and phone; the
+// password may not be built from them.
+func (s *Service) Check(ctx context.Context, password string, personal ...string) error {
+	var violations []entities.PasswordViolation
+
+	length := len([]rune(password))
+	if length < s.config.MinLength {
+		violations = append(violations, entities.PasswordTooShort)
+	}
+	if len(password) > s.config.MaxLength {
+		violations = append(violations, entities.PasswordTooLong)
+	}
+	if length < s.config.PassphraseLength && charClasses(password) < s.config.MinCharClasses {
+		violations = append(violations, entities.PasswordTooSimple)
+	}
+	if s.config.DisallowPersonal && containsPersonal(password, personal) {
+		violations = append(violations, entities.PasswordPersonalInfo)
+	}
+	if s.config.BreachCheck && s.breaches != nil {
+		count, err := s.breaches.BreachCount(ctx, password)
+		if err != nil {
+			s.logger.Warn("Breached password check unavailable", zap.Error(err))
+		} else if count > 0 {
+			violations = append(violations, entities.PasswordBreached)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &entities.PasswordPolicyError{Violations: violations}
+}
+
+// Review checks the password a user just signed in with. It returns nil when
+// the password meets the policy, and otherwise a notice with the deadline
+// for changing it, counted from the first sign-in it was flagged at.
+func (s *Service) Review(ctx context.Context, userID uuid.UUID, password string, personal ...string) *entities.PasswordNotice {
+	err := s.Check(ctx, password, personal...)
+	policyErr, ok := err.(*entities.PasswordPolicyError)
+	if !ok {
+		return nil
+	}
+
+	now := s.now()
+	since := now
+	if s.weak != nil {
+		marked, err := s.weak.MarkPasswordWeak(ctx, userID, now)
+		if err != nil {
+			s.logger.Warn("Failed to record weak password", zap.Error(err), zap.String("user_id", userID.String()))
+		} else {
+			since = marked
+		}
+	}
+	changeBy := since.Add(s.config.GracePeriod)
+	return &entities.PasswordNotice{
+		Violations: policyErr.Violations,
+		ChangeBy:   changeBy,
+		Required:   !now.Before(changeBy),
+	}
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// minPersonalToken is the shortest word of an email address that counts as
+// personal information inside a password
+const minPersonalToken = 4
+
+// containsPersonal reports whether the password contains the local part of
+// an email, a word of it such as a name, or the last digits of a phone number
+func containsPersonal(password string, personal []string) bool {
+	lowered := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if local, _, isEmail := strings.Cut(value, "@"); isEmail {
+			tokens := strings.FieldsFunc(local, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			})
+			for _, token := range append(tokens, local) {
+				if len(token) >= minPersonalToken && strings.Contains(lowered, token) {
+					return true
+				}
+			}
+			continue
+		}
+		digits := strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, value)
+		if len(digits) >= 7 && strings.Contains(lowered, digits[len(digits)-7:]) {
+			return true
+		}
+	}
+	return false
+}
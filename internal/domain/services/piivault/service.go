@@ -0,0 +1,216 @@
+package piivault
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// tokenPrefix marks vault tokens so they are recognizable wherever they are stored
+const tokenPrefix = "pii_"
+
+// ErrPurposeRequired is returned when raw PII is requested without saying why
+var ErrPurposeRequired = errors.New("a purpose is required to reveal PII")
+
+// Repository stores vault records and their access log, in a schema of their own
+type Repository interface {
+	CreateRecord(ctx context.Context, record *entities.PIIVaultRecord) error
+	GetRecord(ctx context.Context, token string) (*entities.PIIVaultRecord, error)
+	UpdateRecord(ctx context.Context, token, ciphertext string, updatedAt time.Time) error
+	LogAccess(ctx context.Context, entry *entities.PIIAccessEntry) error
+	ListAccess(ctx context.Context, token string, limit int) ([]*entities.PIIAccessEntry, error)
+}
+
+// Service is the PII vault. Raw personal data is encrypted under the vault's
+// own keys and replaced in the main database by an opaque token. Callers
+// never use the Service directly to read data: they are handed an Accessor
+// limited to the kinds of data they need, and every store and reveal is
+// logged against the accessor's name.
+type Service struct {
+	repo   Repository
+	enc    *crypto.FieldEncryptor
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService creates the PII vault. enc must use keys separate from the
+// main database's field encryption keys.
+func NewService(repo Repository, enc *crypto.FieldEncryptor, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		enc:    enc,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Scope returns an accessor named accessor that can store and reveal only the
+// given kinds of data
+func (s *Service) Scope(accessor string, kinds ...entities.PIIKind) *Accessor {
+	allowed := make(map[entities.PIIKind]bool, len(kinds))
+	for _, kind := range kinds {
+		allowed[kind] = true
+	}
+	return &Accessor{vault: s, name: accessor, kinds: allowed}
+}
+
+// AccessLog returns the most recent uses of a record, newest first
+func (s *Service) AccessLog(ctx context.Context, token string, limit int) ([]*entities.PIIAccessEntry, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListAccess(ctx, token, limit)
+}
+
+func (s *Service) seal(value any) (string, error) {
+	plain, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal PII: %w", err)
+	}
+	sealed, err := s.enc.Encrypt(string(plain))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt PII: %w", err)
+	}
+	return sealed, nil
+}
+
+func (s *Service) log(ctx context.Context, accessor, action, purpose string, token string, record *entities.PIIVaultRecord) {
+	entry := &entities.PIIAccessEntry{
+		ID:         uuid.New(),
+		Token:      token,
+		Accessor:   accessor,
+		Action:     action,
+		Purpose:    purpose,
+		AccessedAt: s.now(),
+	}
+	if record != nil {
+		entry.SubjectID = &record.SubjectID
+		entry.Kind = record.Kind
+	}
+	if err := s.repo.LogAccess(ctx, entry); err != nil {
+		s.logger.Error("Failed to log PII vault access",
+			zap.String("accessor", accessor),
+			zap.String("action", action),
+			zap.Error(err))
+	}
+}
+
+// Accessor is a named caller's view of the vault, limited to the kinds of
+// data it was granted
+type Accessor struct {
+	vault *Service
+	name  string
+	kinds map[entities.PIIKind]bool
+}
+
+// Name returns the name the accessor's uses are logged under
+func (a *Accessor) Name() string {
+	return a.name
+}
+
+// Tokenize stores value for the subject and returns the token to keep in its place
+func (a *Accessor) Tokenize(ctx context.Context, subjectID uuid.UUID, kind entities.PIIKind, value any) (string, error) {
+	if !a.kinds[kind] {
+		a.vault.log(ctx, a.name, entities.PIIAccessDenied, "store "+string(kind), "", nil)
+		return "", entities.ErrPIIAccessDenied
+	}
+	sealed, err := a.vault.seal(value)
+	if err != nil {
+		return "", err
+	}
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := a.vault.now()
+	record := &entities.PIIVaultRecord{
+		Token:      token,
+		SubjectID:  subjectID,
+		Kind:       kind,
+		Ciphertext: sealed,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := a.vault.repo.CreateRecord(ctx, record); err != nil {
+		return "", fmt.Errorf("failed to store PII: %w", err)
+	}
+	a.vault.log(ctx, a.name, entities.PIIAccessStore, "", token, record)
+	return token, nil
+}
+
+// Update replaces the value behind a token
+func (a *Accessor) Update(ctx context.Context, token string, value any) error {
+	record, err := a.record(ctx, token, "update")
+	if err != nil {
+		return err
+	}
+	sealed, err := a.vault.seal(value)
+	if err != nil {
+		return err
+	}
+	if err := a.vault.repo.UpdateRecord(ctx, token, sealed, a.vault.now()); err != nil {
+		return fmt.Errorf("failed to update PII: %w", err)
+	}
+	a.vault.log(ctx, a.name, entities.PIIAccessUpdate, "", token, record)
+	return nil
+}
+
+// Reveal decrypts the value behind a token into dest. purpose says why the
+// raw data is needed and is kept in the access log.
+func (a *Accessor) Reveal(ctx context.Context, token, purpose string, dest any) error {
+	purpose = strings.TrimSpace(purpose)
+	if purpose == "" {
+		return ErrPurposeRequired
+	}
+	record, err := a.record(ctx, token, purpose)
+	if err != nil {
+		return err
+	}
+	plain, err := a.vault.enc.Decrypt(record.Ciphertext)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt PII: %w", err)
+	}
+	if err := json.Unmarshal([]byte(plain), dest); err != nil {
+		return fmt.Errorf("failed to unmarshal PII: %w", err)
+	}
+	a.vault.log(ctx, a.name, entities.PIIAccessReveal, purpose, token, record)
+	return nil
+}
+
+// record loads a record the accessor may use, logging the attempt when it may not
+func (a *Accessor) record(ctx context.Context, token, purpose string) (*entities.PIIVaultRecord, error) {
+	record, err := a.vault.repo.GetRecord(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !a.kinds[record.Kind] {
+		a.vault.log(ctx, a.name, entities.PIIAccessDenied, purpose, token, record)
+		return nil, entities.ErrPIIAccessDenied
+	}
+	return record, nil
+}
+
+func newToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate PII token: %w", err)
+	}
+	return tokenPrefix + hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,325 @@
+package portfolioshare
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrInvalidExpiry is returned when a link is asked to outlive the maximum TTL
+var ErrInvalidExpiry = errors.New("share link expiry is longer than allowed")
+
+// Repository persists share links and sharing settings
+type Repository interface {
+	CreateLink(ctx context.Context, link *entities.PortfolioShareLink) error
+	GetLink(ctx context.Context, id uuid.UUID) (*entities.PortfolioShareLink, error)
+	ListLinks(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.PortfolioShareLink, error)
+	CountLive(ctx context.Context, userID uuid.UUID, at time.Time) (int, error)
+	RevokeLink(ctx context.Context, userID, id uuid.UUID, at time.Time) (*entities.PortfolioShareLink, error)
+	RevokeAll(ctx context.Context, userID uuid.UUID, at time.Time) (int, error)
+	RecordView(ctx context.Context, id uuid.UUID, at time.Time) error
+	GetSettings(ctx context.Context, userID uuid.UUID) (*entities.PortfolioSharingSettings, error)
+	SaveSettings(ctx context.Context, settings *entities.PortfolioSharingSettings) error
+}
+
+// Attributor measures a user's performance over a range
+type Attributor interface {
+	Attribute(ctx context.Context, userID uuid.UUID, r entities.AttributionRange) (*entities.PerformanceAttribution, error)
+}
+
+// Config controls share links
+type Config struct {
+	SigningKey     []byte        // HMAC key for share tokens
+	DefaultTTL     time.Duration // Link lifetime when the user does not pick one
+	MaxTTL         time.Duration // Longest lifetime a user can pick
+	MaxLiveLinks   int           // Unexpired, unrevoked links a user may hold
+	PublicURL      string        // Path share tokens are appended to
+	RatioPrecision int32         // Decimal places ratios are rounded to in snapshots
+}
+
+// DefaultConfig returns the default share link configuration
+func DefaultConfig() Config {
+	return Config{
+		DefaultTTL:     7 * 24 * time.Hour,
+		MaxTTL:         30 * 24 * time.Hour,
+		MaxLiveLinks:   20,
+		PublicURL:      "/api/v1/shared/portfolio/",
+		RatioPrecision: 4,
+	}
+}
+
+// Service makes public links to snapshots of a user's performance. The
+// snapshot is frozen when the link is made and carries percentages only, so
+// a link never shows amounts or the user's later activity. The user can
+// revoke links one at a time or all at once, and turning sharing off stops
+// every link from being viewed.
+type Service struct {
+	repo       Repository
+	attributor Attributor
+	config     Config
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewService creates a new portfolio share service
+func NewService(repo Repository, attributor Attributor, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = defaults.DefaultTTL
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = defaults.MaxTTL
+	}
+	if config.DefaultTTL > config.MaxTTL {
+		config.DefaultTTL = config.MaxTTL
+	}
+	if config.MaxLiveLinks <= 0 {
+		config.MaxLiveLinks = defaults.MaxLiveLinks
+	}
+	if config.PublicURL == "" {
+		config.PublicURL = defaults.PublicURL
+	}
+	if config.RatioPrecision <= 0 {
+		config.RatioPrecision = defaults.RatioPrecision
+	}
+	return &Service{
+		repo:       repo,
+		attributor: attributor,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// SetClock replaces the service clock, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Create snapshots the user's performance over the range and returns a
+// signed link to it
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, req *entities.CreatePortfolioShareRequest) (*entities.PortfolioShareLink, error) {
+	ttl := s.config.DefaultTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if ttl > s.config.MaxTTL {
+		return nil, ErrInvalidExpiry
+	}
+
+	settings, err := s.repo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, entities.ErrSharingDisabled
+	}
+
+	now := s.now()
+	live, err := s.repo.CountLive(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if live >= s.config.MaxLiveLinks {
+		return nil, entities.ErrShareLinkLimit
+	}
+
+	report, err := s.attributor.Attribute(ctx, userID, req.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &entities.PortfolioShareLink{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Snapshot:  s.scrub(report),
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateLink(ctx, link); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Portfolio share link created",
+		zap.String("user_id", userID.String()),
+		zap.String("link_id", link.ID.String()),
+		zap.Time("expires_at", link.ExpiresAt))
+
+	s.withURL(link)
+	return link, nil
+}
+
+// List returns the user's share links with the URLs of those still live
+func (s *Service) List(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.PortfolioShareLink, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	links, err := s.repo.ListLinks(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		s.withURL(link)
+	}
+	return links, nil
+}
+
+// Revoke stops one of the user's links from being viewed
+func (s *Service) Revoke(ctx context.Context, userID, linkID uuid.UUID) (*entities.PortfolioShareLink, error) {
+	return s.repo.RevokeLink(ctx, userID, linkID, s.now())
+}
+
+// RevokeAll stops every link the user has made from being viewed and
+// returns how many were revoked
+func (s *Service) RevokeAll(ctx context.Context, userID uuid.UUID) (int, error) {
+	revoked, err := s.repo.RevokeAll(ctx, userID, s.now())
+	if err != nil {
+		return 0, err
+	}
+	s.logger.Info("Portfolio share links revoked",
+		zap.String("user_id", userID.String()),
+		zap.Int("revoked", revoked))
+	return revoked, nil
+}
+
+// Settings returns the user's sharing settings
+func (s *Service) Settings(ctx context.Context, userID uuid.UUID) (*entities.PortfolioSharingSettings, error) {
+	return s.repo.GetSettings(ctx, userID)
+}
+
+// SetEnabled turns sharing on or off for the user. While it is off no links
+// can be made or viewed; links that have not expired or been revoked work
+// again once it is back on.
+func (s *Service) SetEnabled(ctx context.Context, userID uuid.UUID, enabled bool) (*entities.PortfolioSharingSettings, error) {
+	settings := &entities.PortfolioSharingSettings{
+		UserID:    userID,
+		Enabled:   enabled,
+		UpdatedAt: s.now(),
+	}
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Open verifies a share token and returns the snapshot it links to. Links
+// that were revoked, have expired or belong to a user who turned sharing off
+// all return ErrShareLinkUnavailable so a viewer cannot tell them apart.
+func (s *Service) Open(ctx context.Context, token string) (*entities.SharedPortfolio, error) {
+	linkID, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.repo.GetLink(ctx, linkID)
+	if errors.Is(err, entities.ErrShareLinkNotFound) {
+		return nil, entities.ErrShareLinkUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !link.IsLive(now) {
+		return nil, entities.ErrShareLinkUnavailable
+	}
+	settings, err := s.repo.GetSettings(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, entities.ErrShareLinkUnavailable
+	}
+
+	if err := s.repo.RecordView(ctx, link.ID, now); err != nil {
+		s.logger.Warn("Failed to record portfolio share view",
+			zap.String("link_id", link.ID.String()),
+			zap.Error(err))
+	}
+	return &entities.SharedPortfolio{
+		Snapshot:  link.Snapshot,
+		ExpiresAt: link.ExpiresAt,
+	}, nil
+}
+
+// scrub copies the ratios of an attribution into a snapshot, leaving out
+// values, gains, components and highlights, which quote amounts
+func (s *Service) scrub(report *entities.PerformanceAttribution) entities.PortfolioShareSnapshot {
+	places := s.config.RatioPrecision
+	snapshot := entities.PortfolioShareSnapshot{
+		Range:           report.Range,
+		Start:           report.Start,
+		End:             report.End,
+		Benchmark:       report.Benchmark,
+		BenchmarkReturn: report.BenchmarkReturn.Round(places),
+		PortfolioReturn: report.PortfolioReturn.Round(places),
+		ActiveReturn:    report.ActiveReturn.Round(places),
+		Baskets:         make([]entities.PortfolioShareBasket, 0, len(report.Baskets)),
+		GeneratedAt:     report.GeneratedAt,
+	}
+	for _, basket := range report.Baskets {
+		snapshot.Baskets = append(snapshot.Baskets, entities.PortfolioShareBasket{
+			Name:         basket.BasketName,
+			Weight:       basket.Weight.Round(places),
+			Return:       basket.Return.Round(places),
+			Contribution: basket.Contribution.Round(places),
+		})
+	}
+	return snapshot
+}
+
+// withURL sets the public URL of a live link
+func (s *Service) withURL(link *entities.PortfolioShareLink) {
+	if link.IsLive(s.now()) {
+		link.URL = s.config.PublicURL + s.sign(link.ID, link.ExpiresAt)
+	}
+}
+
+// sign returns a token of the form payload.signature, where payload encodes
+// the link and its expiry
+func (s *Service) sign(linkID uuid.UUID, expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(
+		linkID.String() + ":" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + s.mac(payload)
+}
+
+func (s *Service) verify(token string) (uuid.UUID, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.mac(payload))) {
+		return uuid.Nil, entities.ErrShareLinkInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return uuid.Nil, entities.ErrShareLinkInvalid
+	}
+	id, expires, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return uuid.Nil, entities.ErrShareLinkInvalid
+	}
+	linkID, idErr := uuid.Parse(id)
+	expiresAt, expiresErr := strconv.ParseInt(expires, 10, 64)
+	if idErr != nil || expiresErr != nil {
+		return uuid.Nil, entities.ErrShareLinkInvalid
+	}
+	if !s.now().Before(time.Unix(expiresAt, 0)) {
+		return uuid.Nil, entities.ErrShareLinkUnavailable
+	}
+	return linkID, nil
+}
+
+func (s *Service) mac(payload string) string {
+	mac := hmac.New(sha256.New, s.config.SigningKey)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
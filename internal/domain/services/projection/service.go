@@ -0,0 +1,363 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// BalanceReader reads a user's buying power
+type BalanceReader interface {
+	Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error)
+}
+
+// DepositReader lists a user's deposits, newest first
+type DepositReader interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Deposit, error)
+}
+
+// HoldReader lists the holds reserving a user's buying power
+type HoldReader interface {
+	ListActive(ctx context.Context, userID uuid.UUID) (*entities.ActiveHolds, error)
+}
+
+// SweepRuleReader reads a user's auto-sweep rule
+type SweepRuleReader interface {
+	GetByUser(ctx context.Context, userID uuid.UUID) (*entities.SweepRule, error)
+}
+
+// AdvanceReader reads the instant buying power advanced against a deposit
+type AdvanceReader interface {
+	GetByDepositID(ctx context.Context, depositID uuid.UUID) (*entities.InstantFundingAdvance, error)
+}
+
+// Pricer values stablecoin deposits in USD
+type Pricer interface {
+	ConvertToUSD(ctx context.Context, amount decimal.Decimal, token entities.Stablecoin) (decimal.Decimal, error)
+}
+
+// Config controls how far ahead buying power is projected and how long
+// deposits are expected to take
+type Config struct {
+	Days int // Days projected, including today
+	// ChainSettlement is how long a detected chain deposit is expected to
+	// take to reach its confirmations
+	ChainSettlement time.Duration
+	// OffRampBusinessDays is how many business days a virtual account
+	// deposit takes from off-ramp to the brokerage
+	OffRampBusinessDays int
+	DepositScan         int // Most recent deposits checked for pending ones
+}
+
+// DefaultConfig projects 30 days, with chain deposits expected within the
+// hour and off-ramps the next business day
+func DefaultConfig() Config {
+	return Config{
+		Days:                30,
+		ChainSettlement:     time.Hour,
+		OffRampBusinessDays: 1,
+		DepositScan:         100,
+	}
+}
+
+// Service projects a user's buying power day by day from what is already
+// known: settled cash, holds, deposits in flight and scheduled auto-sweeps
+type Service struct {
+	balances BalanceReader
+	deposits DepositReader
+	holds    HoldReader
+	sweeps   SweepRuleReader
+	advances AdvanceReader
+	pricer   Pricer
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewService creates a buying power projection service
+func NewService(balances BalanceReader, deposits DepositReader, holds HoldReader, sweeps SweepRuleReader, pricer Pricer, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.Days <= 0 {
+		config.Days = defaults.Days
+	}
+	if config.ChainSettlement <= 0 {
+		config.ChainSettlement = defaults.ChainSettlement
+	}
+	if config.OffRampBusinessDays <= 0 {
+		config.OffRampBusinessDays = defaults.OffRampBusinessDays
+	}
+	if config.DepositScan <= 0 {
+		config.DepositScan = defaults.DepositScan
+	}
+	return &Service{
+		balances: balances,
+		deposits: deposits,
+		holds:    holds,
+		sweeps:   sweeps,
+		pricer:   pricer,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetAdvances nets instant buying power already advanced out of pending deposits
+func (s *Service) SetAdvances(advances AdvanceReader) {
+	s.advances = advances
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Project returns the user's projected buying power for each of the next days
+func (s *Service) Project(ctx context.Context, userID uuid.UUID) (*entities.BuyingPowerProjection, error) {
+	now := s.now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, s.config.Days)
+
+	balance, err := s.balances.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buying power: %w", err)
+	}
+	active, err := s.holds.ListActive(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balance holds: %w", err)
+	}
+
+	flows := holdFlows(active, now)
+	deposits, err := s.depositFlows(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, deposits...)
+	sortFlows(flows)
+
+	sweeps, err := s.sweepFlows(ctx, userID, balance.BuyingPower, flows, now, end)
+	if err != nil {
+		return nil, err
+	}
+	flows = append(flows, sweeps...)
+	sortFlows(flows)
+
+	projection := &entities.BuyingPowerProjection{
+		AsOf:        now,
+		Days:        s.config.Days,
+		SettledCash: balance.BuyingPower.Add(active.TotalHeld),
+		BuyingPower: balance.BuyingPower,
+		Flows:       make([]entities.ProjectedFlow, 0, len(flows)),
+	}
+	for _, flow := range flows {
+		if flow.ExpectedAt.Before(end) {
+			projection.Flows = append(projection.Flows, flow)
+		}
+	}
+	projection.Curve = curve(projection.SettledCash, projection.Flows, start, s.config.Days)
+	return projection, nil
+}
+
+// holdFlows takes reserved amounts off settled cash now; they are already
+// out of buying power and stay out whether the operation completes or not
+func holdFlows(active *entities.ActiveHolds, now time.Time) []entities.ProjectedFlow {
+	flows := make([]entities.ProjectedFlow, 0, len(active.Holds))
+	for _, hold := range active.Holds {
+		flow := entities.ProjectedFlow{
+			Kind:        entities.ProjectedFlowOrderHold,
+			ReferenceID: hold.ReferenceID,
+			Amount:      hold.Amount.Neg(),
+			ExpectedAt:  now,
+			Description: "Reserved for an open order",
+		}
+		if hold.Kind == entities.HoldKindWithdrawal {
+			flow.Kind = entities.ProjectedFlowWithdrawal
+			flow.Description = "Reserved for a withdrawal"
+		}
+		flows = append(flows, flow)
+	}
+	return flows
+}
+
+// depositFlows projects deposits that have not been credited yet on the day
+// they are expected to arrive, less any instant buying power already given
+func (s *Service) depositFlows(ctx context.Context, userID uuid.UUID, now time.Time) ([]entities.ProjectedFlow, error) {
+	deposits, err := s.deposits.GetByUserID(ctx, userID, s.config.DepositScan, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deposits: %w", err)
+	}
+
+	var flows []entities.ProjectedFlow
+	for _, deposit := range deposits {
+		var expected time.Time
+		var description string
+		switch deposit.Status {
+		case entities.DepositStatusDetected, entities.DepositStatusConfirmed:
+			expected = deposit.CreatedAt.Add(s.config.ChainSettlement)
+			description = fmt.Sprintf("%s deposit awaiting confirmations (%d of %d)", deposit.Chain,
+				deposit.Confirmations, deposit.RequiredConfirmations)
+		case "off_ramp_initiated", "off_ramp_completed":
+			initiated := deposit.CreatedAt
+			if deposit.OffRampInitiatedAt != nil {
+				initiated = *deposit.OffRampInitiatedAt
+			}
+			expected = addBusinessDays(initiated, s.config.OffRampBusinessDays)
+			description = "Bank deposit on its way to your brokerage account"
+		default:
+			continue
+		}
+		// A late deposit is still expected, just not in the past
+		if expected.Before(now) {
+			expected = now
+		}
+
+		amount, err := s.depositValue(ctx, deposit)
+		if err != nil {
+			return nil, err
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+		flows = append(flows, entities.ProjectedFlow{
+			Kind:        entities.ProjectedFlowDeposit,
+			ReferenceID: deposit.ID,
+			Amount:      amount,
+			ExpectedAt:  expected,
+			Estimated:   true,
+			Description: description,
+		})
+	}
+	return flows, nil
+}
+
+// depositValue is the buying power a deposit will still add when credited
+func (s *Service) depositValue(ctx context.Context, deposit *entities.Deposit) (decimal.Decimal, error) {
+	amount := deposit.Amount
+	if deposit.VirtualAccountID == nil && s.pricer != nil {
+		token := deposit.Token
+		if token == "" {
+			token = entities.StablecoinUSDC
+		}
+		usd, err := s.pricer.ConvertToUSD(ctx, deposit.Amount, token)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to value deposit %s: %w", deposit.ID, err)
+		}
+		amount = usd
+	}
+
+	if s.advances != nil {
+		advance, err := s.advances.GetByDepositID(ctx, deposit.ID)
+		switch {
+		case err == nil && advance.Status == entities.InstantAdvanceActive:
+			amount = amount.Sub(advance.Amount)
+		case err != nil && !errors.Is(err, entities.ErrInstantAdvanceNotFound):
+			return decimal.Zero, fmt.Errorf("failed to read instant advance: %w", err)
+		}
+	}
+	return amount.Round(2), nil
+}
+
+// sweepFlows plays the user's auto-sweep rule forward over the projected
+// flows. Each run invests what is above the floor at that point, as the
+// sweep itself does.
+func (s *Service) sweepFlows(ctx context.Context, userID uuid.UUID, buyingPower decimal.Decimal, flows []entities.ProjectedFlow, now, end time.Time) ([]entities.ProjectedFlow, error) {
+	if s.sweeps == nil {
+		return nil, nil
+	}
+	rule, err := s.sweeps.GetByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entities.ErrSweepRuleNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read auto-sweep rule: %w", err)
+	}
+	if !rule.Enabled {
+		return nil, nil
+	}
+
+	var sweeps []entities.ProjectedFlow
+	running := buyingPower
+	next := 0
+	for scheduled := rule.NextRunAt; scheduled.Before(end); scheduled = rule.Cadence.Next(scheduled, scheduled) {
+		// An overdue run goes out on the sweeper's next pass
+		run := scheduled
+		if run.Before(now) {
+			run = now
+		}
+		// Holds are already out of buying power; deposits landing by the run
+		// are swept with it
+		for ; next < len(flows) && !flows[next].ExpectedAt.After(run); next++ {
+			if flows[next].Kind == entities.ProjectedFlowDeposit {
+				running = running.Add(flows[next].Amount)
+			}
+		}
+
+		amount := running.Sub(rule.Floor).RoundDown(2)
+		if rule.MaxAmount != nil && amount.GreaterThan(*rule.MaxAmount) {
+			amount = *rule.MaxAmount
+		}
+		if !amount.IsPositive() || amount.LessThan(rule.MinAmount) {
+			continue
+		}
+		running = running.Sub(amount)
+		sweeps = append(sweeps, entities.ProjectedFlow{
+			Kind:        entities.ProjectedFlowRecurring,
+			ReferenceID: rule.ID,
+			Amount:      amount.Neg(),
+			ExpectedAt:  run,
+			Estimated:   true,
+			Description: fmt.Sprintf("%s auto-sweep of cash above $%s", rule.Cadence, rule.Floor.StringFixed(2)),
+		})
+	}
+	return sweeps, nil
+}
+
+// curve sums the flows into end-of-day buying power, starting from settled cash
+func curve(settledCash decimal.Decimal, flows []entities.ProjectedFlow, start time.Time, days int) []entities.ProjectedDay {
+	curve := make([]entities.ProjectedDay, days)
+	running := settledCash
+	next := 0
+	for i := range curve {
+		dayEnd := start.AddDate(0, 0, i+1)
+		day := entities.ProjectedDay{
+			Date:     start.AddDate(0, 0, i).Format("2006-01-02"),
+			Inflows:  decimal.Zero,
+			Outflows: decimal.Zero,
+		}
+		for ; next < len(flows) && flows[next].ExpectedAt.Before(dayEnd); next++ {
+			if flows[next].Amount.IsNegative() {
+				day.Outflows = day.Outflows.Add(flows[next].Amount.Neg())
+			} else {
+				day.Inflows = day.Inflows.Add(flows[next].Amount)
+			}
+		}
+		running = running.Add(day.Inflows).Sub(day.Outflows)
+		day.BuyingPower = running
+		curve[i] = day
+	}
+	return curve
+}
+
+func sortFlows(flows []entities.ProjectedFlow) {
+	sort.SliceStable(flows, func(i, j int) bool {
+		return flows[i].ExpectedAt.Before(flows[j].ExpectedAt)
+	})
+}
+
+// addBusinessDays moves t forward by days weekdays
+func addBusinessDays(t time.Time, days int) time.Time {
+	for days > 0 {
+		t = t.AddDate(0, 0, 1)
+		if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return t
+}
@@ -0,0 +1,488 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// ErrInvalidPromotion is returned for promotion definitions the service rejects
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
+// Repository persists promotions and their grants
+type Repository interface {
+	CreatePromotion(ctx context.Context, promotion *entities.Promotion) error
+	GetPromotion(ctx context.Context, id uuid.UUID) (*entities.Promotion, error)
+	ListPromotions(ctx context.Context) ([]*entities.Promotion, error)
+	ListRunning(ctx context.Context, trigger entities.PromotionTrigger, at time.Time) ([]*entities.Promotion, error)
+	SetPromotionActive(ctx context.Context, id uuid.UUID, active bool) (*entities.Promotion, error)
+	// ClaimGrant inserts a grant and counts it against the promotion's limit.
+	// It returns ErrPromotionAlreadyGranted or ErrPromotionLimitReached.
+	ClaimGrant(ctx context.Context, grant *entities.PromotionGrant) error
+	// ReleaseGrant deletes a grant whose ledger posting failed and frees its slot
+	ReleaseGrant(ctx context.Context, grant *entities.PromotionGrant) error
+	SetGrantLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error
+	// SettleGrant moves an unvested grant to grant.Status and reports whether
+	// this caller did so
+	SettleGrant(ctx context.Context, grant *entities.PromotionGrant) (bool, error)
+	// UnsettleGrant returns a settled grant to unvested so the next pass retries
+	UnsettleGrant(ctx context.Context, id uuid.UUID) error
+	SetSettleLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.PromotionGrant, error)
+	// ListVestable returns unvested grants past their vesting date on open accounts
+	ListVestable(ctx context.Context, now time.Time, limit int) ([]*entities.PromotionGrant, error)
+	// ListForfeitable returns unvested grants on closed accounts
+	ListForfeitable(ctx context.Context, limit int) ([]*entities.PromotionGrant, error)
+}
+
+// Ledger posts promotional credit transactions
+type Ledger interface {
+	GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+}
+
+// BuyingPowerUpdater credits vested promotional credit to buying power
+type BuyingPowerUpdater interface {
+	UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+}
+
+// Config controls the vesting sweep
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// DefaultConfig returns an hourly sweep of up to 200 grants
+func DefaultConfig() Config {
+	return Config{
+		Interval:  time.Hour,
+		BatchSize: 200,
+	}
+}
+
+// SweepReport summarizes a vesting sweep
+type SweepReport struct {
+	Vested    int `json:"vested"`
+	Forfeited int `json:"forfeited"`
+	Failed    int `json:"failed"`
+}
+
+// Service grants promotional credit. Credit is funded from the marketing
+// budget account and held in the user's promotional_credit ledger account,
+// apart from their cash, until its vesting period passes; it then moves to
+// the user's balance and buying power. Closing the account before then
+// forfeits the credit back to the budget.
+type Service struct {
+	repo        Repository
+	ledger      Ledger
+	buyingPower BuyingPowerUpdater
+	config      Config
+	logger      *zap.Logger
+	mu          sync.Mutex
+	now         func() time.Time
+	tracker     *workerstatus.Tracker
+}
+
+// NewService creates a new promotions service
+func NewService(repo Repository, ledger Ledger, buyingPower BuyingPowerUpdater, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	return &Service{
+		repo:        repo,
+		ledger:      ledger,
+		buyingPower: buyingPower,
+		config:      config,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// CreatePromotion defines a new promotion
+func (s *Service) CreatePromotion(ctx context.Context, req *entities.CreatePromotionRequest, createdBy uuid.UUID) (*entities.Promotion, error) {
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidPromotion)
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+
+	now := s.now()
+	promotion := &entities.Promotion{
+		ID:          uuid.New(),
+		Code:        strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:        req.Name,
+		Description: req.Description,
+		Amount:      req.Amount,
+		Trigger:     req.Trigger,
+		VestingDays: req.VestingDays,
+		MaxGrants:   req.MaxGrants,
+		Active:      true,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		CreatedBy:   &createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreatePromotion(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Promotion created",
+		zap.String("promotion_id", promotion.ID.String()),
+		zap.String("code", promotion.Code),
+		zap.String("trigger", string(promotion.Trigger)),
+		logger.Amount("amount", promotion.Amount))
+	return promotion, nil
+}
+
+// ListPromotions returns every promotion
+func (s *Service) ListPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	return s.repo.ListPromotions(ctx)
+}
+
+// SetPromotionActive pauses or resumes a promotion
+func (s *Service) SetPromotionActive(ctx context.Context, id uuid.UUID, active bool) (*entities.Promotion, error) {
+	return s.repo.SetPromotionActive(ctx, id, active)
+}
+
+// Grant grants a running promotion to a user by hand
+func (s *Service) Grant(ctx context.Context, promotionID, userID, grantedBy uuid.UUID) (*entities.PromotionGrant, error) {
+	promotion, err := s.repo.GetPromotion(ctx, promotionID)
+	if err != nil {
+		return nil, err
+	}
+	if !promotion.IsRunning(s.now()) {
+		return nil, entities.ErrPromotionInactive
+	}
+	return s.grant(ctx, promotion, userID, &grantedBy)
+}
+
+// HandleKYCReview grants signup promotions once a user's KYC is approved
+func (s *Service) HandleKYCReview(ctx context.Context, userID uuid.UUID, status entities.KYCStatus, rejectionReasons []string) error {
+	if status != entities.KYCStatusApproved {
+		return nil
+	}
+	return s.grantTriggered(ctx, entities.PromotionTriggerSignup, userID)
+}
+
+// HandleDepositCredited grants first deposit promotions
+func (s *Service) HandleDepositCredited(ctx context.Context, userID uuid.UUID) error {
+	return s.grantTriggered(ctx, entities.PromotionTriggerFirstDeposit, userID)
+}
+
+// Summary returns a user's promotional credit
+func (s *Service) Summary(ctx context.Context, userID uuid.UUID) (*entities.PromotionSummary, error) {
+	grants, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &entities.PromotionSummary{Grants: grants}
+	if summary.Grants == nil {
+		summary.Grants = []*entities.PromotionGrant{}
+	}
+	for _, grant := range grants {
+		switch grant.Status {
+		case entities.PromotionGrantUnvested:
+			summary.Unvested = summary.Unvested.Add(grant.Amount)
+		case entities.PromotionGrantVested:
+			summary.Vested = summary.Vested.Add(grant.Amount)
+		}
+	}
+	return summary, nil
+}
+
+// Interval returns the time between vesting sweeps
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// SetTracker reports vesting sweeps to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Start runs a vesting sweep on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.Sweep(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Promotion vesting sweep failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Sweep forfeits unvested credit on closed accounts, then vests credit whose
+// vesting period has passed. Each pass handles up to one batch of each.
+func (s *Service) Sweep(ctx context.Context) (*SweepReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &SweepReport{}
+
+	forfeitable, err := s.repo.ListForfeitable(ctx, s.config.BatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, grant := range forfeitable {
+		if err := s.forfeit(ctx, grant, "Account closed before promotional credit vested"); err != nil {
+			s.logger.Error("Failed to forfeit promotional credit", zap.String("grant_id", grant.ID.String()), zap.Error(err))
+			report.Failed++
+			continue
+		}
+		report.Forfeited++
+	}
+
+	vestable, err := s.repo.ListVestable(ctx, s.now(), s.config.BatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, grant := range vestable {
+		if err := s.vest(ctx, grant); err != nil {
+			s.logger.Error("Failed to vest promotional credit", zap.String("grant_id", grant.ID.String()), zap.Error(err))
+			report.Failed++
+			continue
+		}
+		report.Vested++
+	}
+
+	if report.Vested+report.Forfeited+report.Failed > 0 {
+		s.logger.Info("Promotion vesting sweep complete",
+			zap.Int("vested", report.Vested),
+			zap.Int("forfeited", report.Forfeited),
+			zap.Int("failed", report.Failed))
+	}
+	return report, nil
+}
+
+// grantTriggered grants every running promotion with the trigger. Promotions
+// the user already holds or that are fully granted are skipped.
+func (s *Service) grantTriggered(ctx context.Context, trigger entities.PromotionTrigger, userID uuid.UUID) error {
+	promotions, err := s.repo.ListRunning(ctx, trigger, s.now())
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, promotion := range promotions {
+		_, err := s.grant(ctx, promotion, userID, nil)
+		if err == nil || errors.Is(err, entities.ErrPromotionAlreadyGranted) || errors.Is(err, entities.ErrPromotionLimitReached) {
+			continue
+		}
+		s.logger.Error("Failed to grant promotion",
+			zap.String("promotion_id", promotion.ID.String()),
+			zap.String("user_id", userID.String()),
+			zap.Error(err))
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// grant claims a grant, then funds it from the marketing budget. A grant whose
+// ledger posting fails is released so the trigger can retry it.
+func (s *Service) grant(ctx context.Context, promotion *entities.Promotion, userID uuid.UUID, grantedBy *uuid.UUID) (*entities.PromotionGrant, error) {
+	now := s.now()
+	grant := &entities.PromotionGrant{
+		ID:            uuid.New(),
+		PromotionID:   promotion.ID,
+		PromotionCode: promotion.Code,
+		UserID:        userID,
+		Amount:        promotion.Amount,
+		Status:        entities.PromotionGrantUnvested,
+		VestsAt:       now.AddDate(0, 0, promotion.VestingDays),
+		GrantedBy:     grantedBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.ClaimGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	txID, err := s.post(ctx, grant, entities.TransactionTypePromotion, "grant",
+		fmt.Sprintf("Promotional credit: %s", promotion.Name),
+		entities.AccountTypeSystemPromotions, ledger.CreatePromotionGrantEntries)
+	if err != nil {
+		if releaseErr := s.repo.ReleaseGrant(ctx, grant); releaseErr != nil {
+			s.logger.Error("Failed to release promotion grant", zap.String("grant_id", grant.ID.String()), zap.Error(releaseErr))
+		}
+		return nil, err
+	}
+	grant.GrantLedgerTxID = &txID
+	if err := s.repo.SetGrantLedgerTx(ctx, grant.ID, txID); err != nil {
+		s.logger.Warn("Failed to record promotion grant ledger transaction", zap.String("grant_id", grant.ID.String()), zap.Error(err))
+	}
+
+	s.logger.Info("Promotional credit granted",
+		zap.String("grant_id", grant.ID.String()),
+		zap.String("promotion", promotion.Code),
+		zap.String("user_id", userID.String()),
+		logger.Amount("amount", grant.Amount),
+		zap.Time("vests_at", grant.VestsAt))
+
+	if !grant.VestsAt.After(now) {
+		if err := s.vest(ctx, grant); err != nil {
+			// The sweep retries vesting
+			s.logger.Warn("Failed to vest promotional credit at grant", zap.String("grant_id", grant.ID.String()), zap.Error(err))
+		}
+	}
+	return grant, nil
+}
+
+// vest moves credit from the promotional account to the user's balance and
+// buying power
+func (s *Service) vest(ctx context.Context, grant *entities.PromotionGrant) error {
+	settled := *grant
+	now := s.now()
+	settled.Status = entities.PromotionGrantVested
+	settled.SettledAt = &now
+	settled.UpdatedAt = now
+	claimed, err := s.repo.SettleGrant(ctx, &settled)
+	if err != nil || !claimed {
+		return err
+	}
+
+	txID, err := s.post(ctx, grant, entities.TransactionTypePromotion, "vest",
+		fmt.Sprintf("Promotional credit vested: %s", grant.PromotionCode),
+		entities.AccountTypeUSDCBalance, ledger.CreatePromotionVestEntries)
+	if err == nil {
+		err = s.buyingPower.UpdateBuyingPower(ctx, grant.UserID, grant.Amount)
+	}
+	if err != nil {
+		// The ledger posting is idempotent on the grant, so the retry is safe
+		if revertErr := s.repo.UnsettleGrant(ctx, grant.ID); revertErr != nil {
+			s.logger.Error("Failed to revert promotion vesting", zap.String("grant_id", grant.ID.String()), zap.Error(revertErr))
+		}
+		return err
+	}
+	if err := s.repo.SetSettleLedgerTx(ctx, grant.ID, txID); err != nil {
+		s.logger.Warn("Failed to record promotion vesting ledger transaction", zap.String("grant_id", grant.ID.String()), zap.Error(err))
+	}
+
+	*grant = settled
+	grant.SettleLedgerTxID = &txID
+	s.logger.Info("Promotional credit vested",
+		zap.String("grant_id", grant.ID.String()),
+		zap.String("user_id", grant.UserID.String()),
+		logger.Amount("amount", grant.Amount))
+	return nil
+}
+
+// forfeit claws unvested credit back to the marketing budget
+func (s *Service) forfeit(ctx context.Context, grant *entities.PromotionGrant, reason string) error {
+	settled := *grant
+	now := s.now()
+	settled.Status = entities.PromotionGrantForfeited
+	settled.ForfeitReason = &reason
+	settled.SettledAt = &now
+	settled.UpdatedAt = now
+	claimed, err := s.repo.SettleGrant(ctx, &settled)
+	if err != nil || !claimed {
+		return err
+	}
+
+	txID, err := s.post(ctx, grant, entities.TransactionTypePromotionClawback, "clawback",
+		fmt.Sprintf("Promotional credit forfeited: %s", grant.PromotionCode),
+		entities.AccountTypeSystemPromotions, ledger.CreatePromotionClawbackEntries)
+	if err != nil {
+		if revertErr := s.repo.UnsettleGrant(ctx, grant.ID); revertErr != nil {
+			s.logger.Error("Failed to revert promotion forfeit", zap.String("grant_id", grant.ID.String()), zap.Error(revertErr))
+		}
+		return err
+	}
+	if err := s.repo.SetSettleLedgerTx(ctx, grant.ID, txID); err != nil {
+		s.logger.Warn("Failed to record promotion forfeit ledger transaction", zap.String("grant_id", grant.ID.String()), zap.Error(err))
+	}
+
+	*grant = settled
+	grant.SettleLedgerTxID = &txID
+	s.logger.Info("Promotional credit forfeited",
+		zap.String("grant_id", grant.ID.String()),
+		zap.String("user_id", grant.UserID.String()),
+		logger.Amount("amount", grant.Amount),
+		zap.String("reason", reason))
+	return nil
+}
+
+// post writes one ledger transaction between the user's promotional credit
+// account and counterparty, keyed on the grant and step so retries return the
+// original transaction
+func (s *Service) post(
+	ctx context.Context,
+	grant *entities.PromotionGrant,
+	txType entities.TransactionType,
+	step, description string,
+	counterparty entities.AccountType,
+	entries func(promotionalCreditID, counterpartyID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest,
+) (uuid.UUID, error) {
+	promotionalCredit, err := s.ledger.GetOrCreateUserAccount(ctx, grant.UserID, entities.AccountTypePromotionalCredit)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get promotional credit account: %w", err)
+	}
+	var other *entities.LedgerAccount
+	if counterparty.IsSystemAccountType() {
+		other, err = s.ledger.GetSystemAccount(ctx, counterparty)
+	} else {
+		other, err = s.ledger.GetOrCreateUserAccount(ctx, grant.UserID, counterparty)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get %s account: %w", counterparty, err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithUser(grant.UserID).
+		WithType(txType).
+		WithReference(grant.ID, "promotion_grant").
+		WithIdempotencyKey(fmt.Sprintf("promotion-%s-%s", step, grant.ID)).
+		WithDescription(description).
+		WithMetadata(map[string]any{
+			"promotion_id":   grant.PromotionID.String(),
+			"promotion_code": grant.PromotionCode,
+			"grant_id":       grant.ID.String(),
+		}).
+		WithEntries(entries(promotionalCredit.ID, other.ID, grant.Amount)).
+		Build()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to build promotion ledger transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to post promotion ledger transaction: %w", err)
+	}
+	return tx.ID, nil
+}
@@ -0,0 +1,237 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Repository persists observed exchange rates
+type Repository interface {
+	// Insert stores rates, skipping any already recorded for the same pair,
+	// time and source, and returns how many were new
+	Insert(ctx context.Context, rates []*entities.ExchangeRate) (int, error)
+	// Nearest returns the rate for the pair observed closest to at, no more
+	// than window either side of it, or entities.ErrRateNotFound
+	Nearest(ctx context.Context, pair entities.CurrencyPair, at time.Time, window time.Duration) (*entities.ExchangeRate, error)
+}
+
+// Provider serves historical rates
+type Provider interface {
+	// History returns the rates observed for the pair between from and to
+	History(ctx context.Context, pair entities.CurrencyPair, from, to time.Time) ([]*entities.ExchangeRate, error)
+}
+
+// Config controls rate lookups and backfills
+type Config struct {
+	MaxSkew       time.Duration // Furthest a stored rate may be from the time it is looked up for
+	BackfillChunk time.Duration // Span of history requested from the provider at once
+}
+
+// DefaultConfig accepts rates up to a day away and backfills a month per request
+func DefaultConfig() Config {
+	return Config{
+		MaxSkew:       24 * time.Hour,
+		BackfillChunk: 30 * 24 * time.Hour,
+	}
+}
+
+// ErrNoProvider is returned by backfills when no rate provider is configured
+var ErrNoProvider = errors.New("no exchange rate provider configured")
+
+// Service keeps a history of exchange rates so statements, tax documents and
+// the ledger can value transactions at the rate in force when they happened.
+// Rates are recorded as deposits and conversions settle and backfilled from
+// the provider for periods without them.
+type Service struct {
+	repo     Repository
+	provider Provider
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewService creates an exchange rate service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = defaults.MaxSkew
+	}
+	if config.BackfillChunk <= 0 {
+		config.BackfillChunk = defaults.BackfillChunk
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetProvider enables backfills, and lookups of times with no stored rate,
+// from a historical rate provider
+func (s *Service) SetProvider(provider Provider) {
+	s.provider = provider
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Record stores a rate observed when a transaction settled
+func (s *Service) Record(ctx context.Context, rate *entities.ExchangeRate) error {
+	if !rate.Rate.IsPositive() {
+		return fmt.Errorf("exchange rate must be positive, got %s", rate.Rate.String())
+	}
+	rate.Pair = entities.NewCurrencyPair(rate.Pair.Base, rate.Pair.Quote)
+	if rate.ID == uuid.Nil {
+		rate.ID = uuid.New()
+	}
+	if rate.CreatedAt.IsZero() {
+		rate.CreatedAt = s.now()
+	}
+	if _, err := s.repo.Insert(ctx, []*entities.ExchangeRate{rate}); err != nil {
+		return fmt.Errorf("failed to record exchange rate: %w", err)
+	}
+	return nil
+}
+
+// RateAt returns the price of one unit of the pair's base in its quote at the
+// given time. The nearest stored rate is used, then the inverse pair's; if
+// neither is close enough the provider's history around that time is loaded.
+func (s *Service) RateAt(ctx context.Context, pair entities.CurrencyPair, at time.Time) (decimal.Decimal, error) {
+	pair = entities.NewCurrencyPair(pair.Base, pair.Quote)
+	if pair.Base == "" || pair.Quote == "" {
+		return decimal.Zero, fmt.Errorf("%w: %q", entities.ErrInvalidCurrencyPair, pair.String())
+	}
+	if pair.Base == pair.Quote {
+		return decimal.NewFromInt(1), nil
+	}
+
+	rate, err := s.stored(ctx, pair, at)
+	if err == nil || !errors.Is(err, entities.ErrRateNotFound) || s.provider == nil {
+		return rate, err
+	}
+
+	if _, err := s.load(ctx, pair, at.Add(-s.config.MaxSkew), at.Add(s.config.MaxSkew)); err != nil {
+		s.logger.Warn("Failed to load exchange rate history",
+			zap.String("pair", pair.String()),
+			zap.Time("at", at),
+			zap.Error(err))
+		return decimal.Zero, fmt.Errorf("%w: %s at %s", entities.ErrRateNotFound, pair.String(), at.Format(time.RFC3339))
+	}
+	return s.stored(ctx, pair, at)
+}
+
+// Convert values an amount of one currency in another at the given time
+func (s *Service) Convert(ctx context.Context, amount decimal.Decimal, from, to string, at time.Time) (decimal.Decimal, error) {
+	rate, err := s.RateAt(ctx, entities.NewCurrencyPair(from, to), at)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(rate), nil
+}
+
+// Backfill loads the provider's history for a pair over a period. Rates
+// already stored are kept, so overlapping backfills are safe.
+func (s *Service) Backfill(ctx context.Context, pair entities.CurrencyPair, from, to time.Time) (*entities.RateBackfillResult, error) {
+	if s.provider == nil {
+		return nil, ErrNoProvider
+	}
+	pair = entities.NewCurrencyPair(pair.Base, pair.Quote)
+	if pair.Base == "" || pair.Quote == "" || pair.Base == pair.Quote {
+		return nil, fmt.Errorf("%w: %q", entities.ErrInvalidCurrencyPair, pair.String())
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("backfill start must be before its end")
+	}
+	if now := s.now(); to.After(now) {
+		to = now
+	}
+
+	result := &entities.RateBackfillResult{Pair: pair.String(), From: from, To: to}
+	for start := from; start.Before(to); start = start.Add(s.config.BackfillChunk) {
+		end := start.Add(s.config.BackfillChunk)
+		if end.After(to) {
+			end = to
+		}
+		loaded, err := s.load(ctx, pair, start, end)
+		result.Fetched += loaded.fetched
+		result.Stored += loaded.stored
+		if err != nil {
+			return result, err
+		}
+	}
+
+	s.logger.Info("Exchange rates backfilled",
+		zap.String("pair", pair.String()),
+		zap.Time("from", from),
+		zap.Time("to", to),
+		zap.Int("fetched", result.Fetched),
+		zap.Int("stored", result.Stored))
+	return result, nil
+}
+
+// stored returns the nearest stored rate for the pair, falling back to the
+// inverse of the nearest rate for the inverse pair
+func (s *Service) stored(ctx context.Context, pair entities.CurrencyPair, at time.Time) (decimal.Decimal, error) {
+	rate, err := s.repo.Nearest(ctx, pair, at, s.config.MaxSkew)
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !errors.Is(err, entities.ErrRateNotFound) {
+		return decimal.Zero, fmt.Errorf("failed to look up exchange rate: %w", err)
+	}
+
+	inverse, err := s.repo.Nearest(ctx, pair.Inverse(), at, s.config.MaxSkew)
+	if err == nil {
+		return decimal.NewFromInt(1).DivRound(inverse.Rate, 18), nil
+	}
+	if !errors.Is(err, entities.ErrRateNotFound) {
+		return decimal.Zero, fmt.Errorf("failed to look up exchange rate: %w", err)
+	}
+	return decimal.Zero, fmt.Errorf("%w: %s at %s", entities.ErrRateNotFound, pair.String(), at.Format(time.RFC3339))
+}
+
+type loadResult struct {
+	fetched int
+	stored  int
+}
+
+// load stores the provider's rates for the pair between from and to
+func (s *Service) load(ctx context.Context, pair entities.CurrencyPair, from, to time.Time) (loadResult, error) {
+	history, err := s.provider.History(ctx, pair, from, to)
+	if err != nil {
+		return loadResult{}, fmt.Errorf("failed to fetch %s history: %w", pair.String(), err)
+	}
+
+	now := s.now()
+	rates := make([]*entities.ExchangeRate, 0, len(history))
+	for _, rate := range history {
+		if !rate.Rate.IsPositive() {
+			continue
+		}
+		rate.ID = uuid.New()
+		rate.Pair = pair
+		rate.Source = entities.RateSourceBackfill
+		rate.CreatedAt = now
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		return loadResult{fetched: len(history)}, nil
+	}
+
+	stored, err := s.repo.Insert(ctx, rates)
+	if err != nil {
+		return loadResult{fetched: len(history)}, fmt.Errorf("failed to store %s history: %w", pair.String(), err)
+	}
+	return loadResult{fetched: len(history), stored: stored}, nil
+}
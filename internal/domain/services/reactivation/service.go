@@ -0,0 +1,389 @@
+package reactivation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrVerificationUnavailable is returned when no KYC provider is configured to
+// re-verify the account holder
+var ErrVerificationUnavailable = errors.New("identity verification is unavailable")
+
+// Repository persists reactivation requests and account closure state
+type Repository interface {
+	GetClosure(ctx context.Context, userID uuid.UUID) (*entities.AccountClosure, error)
+	// Create inserts a request unless the user already has one in progress, in
+	// which case the existing request is returned with created=false
+	Create(ctx context.Context, req *entities.ReactivationRequest) (*entities.ReactivationRequest, bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.ReactivationRequest, error)
+	GetOpenByUser(ctx context.Context, userID uuid.UUID) (*entities.ReactivationRequest, error)
+	List(ctx context.Context, status entities.ReactivationStatus, limit, offset int) ([]*entities.ReactivationRequest, error)
+	Update(ctx context.Context, req *entities.ReactivationRequest) error
+	// ReopenAccount sets the user active again and clears the closure
+	ReopenAccount(ctx context.Context, userID uuid.UUID) error
+}
+
+// IdentityVerifier starts a hosted KYC re-verification for a user
+type IdentityVerifier interface {
+	GenerateKYCURL(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// ComplianceScreener re-evaluates a user against current country rules
+type ComplianceScreener interface {
+	ScreenUser(ctx context.Context, userID uuid.UUID) (*entities.JurisdictionDecision, error)
+}
+
+// CaseService opens and resolves admin review cases
+type CaseService interface {
+	Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error)
+	Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error)
+}
+
+// BalanceReader returns a user's cash balance
+type BalanceReader interface {
+	Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error)
+}
+
+// PositionReader returns a user's positions
+type PositionReader interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error)
+}
+
+// Mailer sends plain account servicing emails
+type Mailer interface {
+	SendCustomEmail(ctx context.Context, to, subject, htmlContent, textContent string) error
+}
+
+// Service reopens closed accounts. A request first re-verifies the holder's
+// identity through the KYC provider, then re-screens them against current
+// jurisdiction rules. Accounts closed at the user's or an admin's request
+// are reopened automatically; accounts closed for cause wait for an admin.
+// Balances and positions are held through closure, so reopening restores
+// them; both snapshots are kept on the request for reconciliation.
+type Service struct {
+	repo      Repository
+	verifier  IdentityVerifier
+	screener  ComplianceScreener
+	cases     CaseService
+	balances  BalanceReader
+	positions PositionReader
+	mailer    Mailer
+	logger    *zap.Logger
+}
+
+// NewService creates a new reactivation service. verifier and mailer may be nil.
+func NewService(
+	repo Repository,
+	verifier IdentityVerifier,
+	screener ComplianceScreener,
+	cases CaseService,
+	balances BalanceReader,
+	positions PositionReader,
+	mailer Mailer,
+	logger *zap.Logger,
+) *Service {
+	return &Service{
+		repo:      repo,
+		verifier:  verifier,
+		screener:  screener,
+		cases:     cases,
+		balances:  balances,
+		positions: positions,
+		mailer:    mailer,
+		logger:    logger,
+	}
+}
+
+// Start opens a reactivation request for a closed account, or returns the
+// request already in progress
+func (s *Service) Start(ctx context.Context, userID uuid.UUID) (*entities.ReactivationRequest, error) {
+	closure, err := s.repo.GetClosure(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if closure.IsActive {
+		return nil, entities.ErrAccountNotClosed
+	}
+
+	existing, err := s.repo.GetOpenByUser(ctx, userID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, entities.ErrReactivationNotFound) {
+		return nil, err
+	}
+
+	if s.verifier == nil {
+		return nil, ErrVerificationUnavailable
+	}
+	url, err := s.verifier.GenerateKYCURL(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start identity re-verification: %w", err)
+	}
+
+	holdings, err := s.snapshot(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := closure.Reason
+	if reason == "" {
+		reason = entities.ClosureAdmin
+	}
+	now := time.Now()
+	req, created, err := s.repo.Create(ctx, &entities.ReactivationRequest{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Status:           entities.ReactivationPendingVerification,
+		ClosureReason:    reason,
+		RequiresApproval: reason == entities.ClosureForCause,
+		VerificationURL:  &url,
+		PriorHoldings:    holdings,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.logger.Info("Account reactivation requested",
+			zap.String("request_id", req.ID.String()),
+			zap.String("user_id", userID.String()),
+			zap.String("closure_reason", string(reason)))
+	}
+	return req, nil
+}
+
+// HandleKYCReview resumes a reactivation waiting on identity re-verification.
+// Users without a request in progress are ignored.
+func (s *Service) HandleKYCReview(ctx context.Context, userID uuid.UUID, status entities.KYCStatus, rejectionReasons []string) error {
+	req, err := s.repo.GetOpenByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entities.ErrReactivationNotFound) {
+			return nil
+		}
+		return err
+	}
+	if req.Status != entities.ReactivationPendingVerification {
+		return nil
+	}
+
+	switch status {
+	case entities.KYCStatusRejected:
+		reason := "Identity re-verification failed"
+		if len(rejectionReasons) > 0 {
+			reason = fmt.Sprintf("%s: %s", reason, strings.Join(rejectionReasons, ", "))
+		}
+		return s.reject(ctx, req, reason, nil, nil)
+	case entities.KYCStatusApproved:
+		return s.screen(ctx, req)
+	default:
+		return nil
+	}
+}
+
+// screen re-screens a verified user and either reopens the account or queues
+// it for admin approval
+func (s *Service) screen(ctx context.Context, req *entities.ReactivationRequest) error {
+	decision, err := s.screener.ScreenUser(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to re-screen user: %w", err)
+	}
+	req.ScreenedCountry = &decision.CountryCode
+	if !decision.Allowed {
+		return s.reject(ctx, req, fmt.Sprintf("Accounts are no longer supported in %s", decision.CountryCode), nil, nil)
+	}
+
+	if !req.RequiresApproval {
+		return s.complete(ctx, req)
+	}
+
+	adminCase, err := s.cases.Open(ctx, req.UserID, entities.AdminCaseAccountReactivation,
+		"Account closed for cause requested reactivation",
+		map[string]interface{}{
+			"reactivation_id":  req.ID.String(),
+			"closure_reason":   string(req.ClosureReason),
+			"screened_country": decision.CountryCode,
+			"prior_holdings":   req.PriorHoldings,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to open reactivation case: %w", err)
+	}
+	req.CaseID = &adminCase.ID
+	req.Status = entities.ReactivationPendingApproval
+	req.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, req); err != nil {
+		return err
+	}
+	s.logger.Info("Account reactivation awaiting admin approval",
+		zap.String("request_id", req.ID.String()),
+		zap.String("user_id", req.UserID.String()),
+		zap.String("case_id", adminCase.ID.String()))
+	return nil
+}
+
+// Approve reopens an account closed for cause after admin review
+func (s *Service) Approve(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.ReactivationRequest, error) {
+	req, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != entities.ReactivationPendingApproval {
+		return nil, entities.ErrReactivationNotPending
+	}
+
+	req.ReviewedBy = &adminID
+	req.ReviewNotes = notes
+	if err := s.complete(ctx, req); err != nil {
+		return nil, err
+	}
+	s.resolveCase(ctx, req, adminID, "Reactivation approved")
+	return req, nil
+}
+
+// Reject declines a reactivation request still in progress
+func (s *Service) Reject(ctx context.Context, id, adminID uuid.UUID, notes *string) (*entities.ReactivationRequest, error) {
+	req, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !req.IsOpen() {
+		return nil, entities.ErrReactivationNotPending
+	}
+
+	if err := s.reject(ctx, req, "Reactivation declined after review", &adminID, notes); err != nil {
+		return nil, err
+	}
+	s.resolveCase(ctx, req, adminID, "Reactivation rejected")
+	return req, nil
+}
+
+// Get returns a reactivation request
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.ReactivationRequest, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns reactivation requests filtered by optional status
+func (s *Service) List(ctx context.Context, status entities.ReactivationStatus, limit, offset int) ([]*entities.ReactivationRequest, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+// complete reopens the account and records the holdings it was restored with
+func (s *Service) complete(ctx context.Context, req *entities.ReactivationRequest) error {
+	if err := s.repo.ReopenAccount(ctx, req.UserID); err != nil {
+		return err
+	}
+
+	restored, err := s.snapshot(ctx, req.UserID)
+	if err != nil {
+		// The account is already open; the snapshot is only for reconciliation
+		s.logger.Warn("Failed to snapshot restored holdings", zap.String("user_id", req.UserID.String()), zap.Error(err))
+	}
+	if restored != nil && req.PriorHoldings != nil && !restored.Matches(req.PriorHoldings) {
+		s.logger.Warn("Holdings changed while account was closed",
+			zap.String("request_id", req.ID.String()),
+			zap.String("user_id", req.UserID.String()),
+			zap.String("prior_buying_power", req.PriorHoldings.BuyingPower.String()),
+			zap.String("restored_buying_power", restored.BuyingPower.String()))
+	}
+
+	now := time.Now()
+	req.Status = entities.ReactivationCompleted
+	req.RestoredHoldings = restored
+	req.CompletedAt = &now
+	req.UpdatedAt = now
+	if err := s.repo.Update(ctx, req); err != nil {
+		return err
+	}
+
+	s.logger.Info("Account reactivated",
+		zap.String("request_id", req.ID.String()),
+		zap.String("user_id", req.UserID.String()))
+	s.notify(ctx, req, "Your account has been reactivated",
+		"Your account has been reopened and your balances and positions are available again. You can sign in as usual.")
+	return nil
+}
+
+func (s *Service) reject(ctx context.Context, req *entities.ReactivationRequest, reason string, adminID *uuid.UUID, notes *string) error {
+	req.Status = entities.ReactivationRejected
+	req.RejectionReason = &reason
+	req.ReviewedBy = adminID
+	req.ReviewNotes = notes
+	req.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, req); err != nil {
+		return err
+	}
+
+	s.logger.Info("Account reactivation rejected",
+		zap.String("request_id", req.ID.String()),
+		zap.String("user_id", req.UserID.String()),
+		zap.String("reason", reason))
+	s.notify(ctx, req, "Your account reactivation request",
+		"We were unable to reopen your account. Please contact support for more information.")
+	return nil
+}
+
+func (s *Service) resolveCase(ctx context.Context, req *entities.ReactivationRequest, adminID uuid.UUID, resolution string) {
+	if req.CaseID == nil {
+		return
+	}
+	if _, err := s.cases.Update(ctx, *req.CaseID, &entities.UpdateAdminCaseRequest{
+		Status:          entities.AdminCaseResolved,
+		ResolutionNotes: &resolution,
+	}, adminID); err != nil {
+		s.logger.Warn("Failed to resolve reactivation case", zap.String("case_id", req.CaseID.String()), zap.Error(err))
+	}
+}
+
+// snapshot captures the balances and positions currently held by a user
+func (s *Service) snapshot(ctx context.Context, userID uuid.UUID) (*entities.AccountHoldings, error) {
+	holdings := &entities.AccountHoldings{CapturedAt: time.Now()}
+
+	balance, err := s.balances.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance: %w", err)
+	}
+	if balance != nil {
+		holdings.BuyingPower = balance.BuyingPower
+	}
+
+	positions, err := s.positions.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read positions: %w", err)
+	}
+	holdings.PositionCount = len(positions)
+	holdings.PositionsValue = decimal.Zero
+	for _, position := range positions {
+		holdings.PositionsValue = holdings.PositionsValue.Add(position.MarketValue)
+	}
+	return holdings, nil
+}
+
+func (s *Service) notify(ctx context.Context, req *entities.ReactivationRequest, subject, body string) {
+	if s.mailer == nil {
+		return
+	}
+	closure, err := s.repo.GetClosure(ctx, req.UserID)
+	if err != nil {
+		s.logger.Warn("Failed to look up email for reactivation notice", zap.String("user_id", req.UserID.String()), zap.Error(err))
+		return
+	}
+	if err := s.mailer.SendCustomEmail(ctx, closure.Email, subject, "<p>"+body+"</p>", body); err != nil {
+		s.logger.Warn("Failed to send reactivation notice", zap.String("user_id", req.UserID.String()), zap.Error(err))
+	}
+}
@@ -0,0 +1,185 @@
+package recipients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrAccountCheckUnavailable is returned when no bank-linking provider is configured
+	ErrAccountCheckUnavailable = errors.New("bank account verification is not available")
+	// ErrNotBankRecipient is returned when an ACH payout or account check
+	// targets a crypto recipient
+	ErrNotBankRecipient = errors.New("recipient is not a bank account")
+	// ErrAccountCheckRequired is returned for an ACH payout above the
+	// verification threshold to a bank account that has not been verified
+	ErrAccountCheckRequired = errors.New("this bank account must be verified before larger payouts")
+	// ErrAccountCheckFailed is returned for an ACH payout to a bank account
+	// the provider reported as closed, unknown or held by someone else
+	ErrAccountCheckFailed = errors.New("this bank account could not be verified; payouts to it are blocked")
+)
+
+// BankAccountVerifier looks an account up with the bank-linking provider
+type BankAccountVerifier interface {
+	CheckAccount(ctx context.Context, routingNumber, accountNumber string) (*entities.BankAccountCheck, error)
+}
+
+// UserProfiles supplies the name the account owner must match
+type UserProfiles interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error)
+}
+
+// AccountCheckConfig controls when ACH payouts need a verified account
+type AccountCheckConfig struct {
+	// RequiredAbove is the payout amount above which the destination must
+	// be verified; zero requires it for every payout
+	RequiredAbove decimal.Decimal
+}
+
+// SetAccountVerifier enables bank account checks. New bank recipients are
+// checked when saved, and ACH payouts above the configured amount need a
+// verified account.
+func (s *Service) SetAccountVerifier(verifier BankAccountVerifier, users UserProfiles, config AccountCheckConfig) {
+	s.verifier = verifier
+	s.users = users
+	s.accountCheck = config
+}
+
+// VerifyAccount checks a bank recipient with the bank-linking provider and
+// records whether it is open and held in the user's name
+func (s *Service) VerifyAccount(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	if s.verifier == nil {
+		return nil, ErrAccountCheckUnavailable
+	}
+	recipient, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Kind != entities.RecipientKindBank {
+		return nil, ErrNotBankRecipient
+	}
+	if err := s.checkAccount(ctx, recipient); err != nil {
+		return nil, err
+	}
+	return recipient, nil
+}
+
+// ResolveForACHPayout returns a bank recipient the user may be paid out to
+// now. Accounts that failed their check are always refused; unchecked
+// accounts are checked first when the amount is above the threshold.
+func (s *Service) ResolveForACHPayout(ctx context.Context, userID, id uuid.UUID, amount decimal.Decimal) (*entities.WithdrawalRecipient, error) {
+	recipient, err := s.ResolveForWithdrawal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Kind != entities.RecipientKindBank {
+		return nil, ErrNotBankRecipient
+	}
+	if recipient.AccountCheckStatus == entities.AccountCheckFailed {
+		return nil, ErrAccountCheckFailed
+	}
+	if s.verifier == nil || recipient.AccountCheckStatus == entities.AccountCheckVerified ||
+		!amount.GreaterThan(s.accountCheck.RequiredAbove) {
+		return recipient, nil
+	}
+
+	if err := s.checkAccount(ctx, recipient); err != nil {
+		s.logger.Warn("Bank account check before payout failed",
+			zap.String("recipient_id", recipient.ID.String()), zap.Error(err))
+		return nil, ErrAccountCheckRequired
+	}
+	if recipient.AccountCheckStatus != entities.AccountCheckVerified {
+		return nil, ErrAccountCheckFailed
+	}
+	return recipient, nil
+}
+
+// checkAccount runs the provider check and stores the outcome on the recipient
+func (s *Service) checkAccount(ctx context.Context, recipient *entities.WithdrawalRecipient) error {
+	check, err := s.verifier.CheckAccount(ctx, recipient.RoutingNumber, recipient.AccountNumber)
+	if err != nil {
+		return fmt.Errorf("bank account check failed: %w", err)
+	}
+
+	owner := recipient.AccountHolderName
+	if s.users != nil {
+		profile, err := s.users.GetByID(ctx, recipient.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user profile: %w", err)
+		}
+		if name := profile.GetFullName(); name != "" {
+			owner = name
+		}
+	}
+
+	status := entities.AccountCheckVerified
+	var reason *string
+	fail := func(r string) {
+		status = entities.AccountCheckFailed
+		reason = &r
+	}
+	switch {
+	case !check.Found:
+		fail(entities.AccountCheckReasonNotFound)
+	case !check.Open:
+		fail(entities.AccountCheckReasonClosed)
+	case !ownerMatches(owner, check.OwnerNames):
+		fail(entities.AccountCheckReasonOwnerMismatch)
+	}
+
+	now := time.Now()
+	if err := s.repo.SetAccountCheck(ctx, recipient.ID, status, reason, now); err != nil {
+		return err
+	}
+	recipient.AccountCheckStatus = status
+	recipient.AccountCheckReason = reason
+	recipient.AccountCheckedAt = &now
+
+	s.logger.Info("Bank recipient checked",
+		zap.String("recipient_id", recipient.ID.String()),
+		zap.String("status", string(status)),
+		zap.String("provider_reference", check.Reference))
+	return nil
+}
+
+// ownerMatches reports whether any owner name on the account contains every
+// part of the user's name, ignoring case, punctuation and word order
+func ownerMatches(name string, owners []string) bool {
+	want := nameParts(name)
+	if len(want) == 0 {
+		return false
+	}
+	for _, owner := range owners {
+		have := make(map[string]bool)
+		for _, part := range nameParts(owner) {
+			have[part] = true
+		}
+		matched := true
+		for _, part := range want {
+			if !have[part] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func nameParts(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
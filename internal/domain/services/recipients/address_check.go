@@ -0,0 +1,319 @@
+package recipients
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+var (
+	// ErrAddressCheckUnavailable is returned when address checks, or the
+	// chosen method, are not configured
+	ErrAddressCheckUnavailable = errors.New("address verification is not available")
+	// ErrNotCryptoRecipient is returned when an address check or crypto
+	// withdrawal targets a bank recipient
+	ErrNotCryptoRecipient = errors.New("recipient is not a crypto address")
+	// ErrAddressCheckRequired is returned for a withdrawal above the
+	// verification threshold to a new address that has not been verified
+	ErrAddressCheckRequired = errors.New("verify this address before sending larger amounts to it")
+	// ErrMicroDepositPending is returned when a check is started while the
+	// last micro-deposit's confirmation window is still open
+	ErrMicroDepositPending = errors.New("a verification deposit was already sent to this address; confirm it or wait for it to expire")
+	// ErrAddressCheckNotPending is returned when confirming with no check started
+	ErrAddressCheckNotPending = errors.New("no address verification is in progress for this recipient")
+	// ErrAddressCheckExpired is returned when confirming a check after its expiry
+	ErrAddressCheckExpired = errors.New("the address verification has expired; start a new one")
+	// ErrAddressCheckMismatch is returned for a wrong amount or signature
+	ErrAddressCheckMismatch = errors.New("the amount or signature does not match")
+	// ErrAddressCheckFailed is returned once a check has run out of attempts
+	ErrAddressCheckFailed = errors.New("too many incorrect answers; start a new address verification")
+)
+
+// MicroDepositSender sends the small verification amount to an address
+// from the platform's wallet and returns the transfer's reference
+type MicroDepositSender interface {
+	SendMicroDeposit(ctx context.Context, chain, address string, amount decimal.Decimal, idempotencyKey string) (string, error)
+}
+
+// AddressCheckConfig controls when crypto withdrawals need a verified
+// address and how long checks stay open
+type AddressCheckConfig struct {
+	// RequiredAbove is the withdrawal amount above which a new address
+	// must be verified; zero requires it for every withdrawal
+	RequiredAbove decimal.Decimal
+	// NewFor is how long after being saved an address counts as new; zero
+	// keeps every unverified address new
+	NewFor             time.Duration
+	MicroDepositTTL    time.Duration // How long the user has to confirm a micro-deposit
+	SignatureTTL       time.Duration // How long a message may be signed and submitted
+	MaxAttempts        int           // Answers allowed per check
+	ChallengeStatement string        // First line of the message to sign
+}
+
+// DefaultAddressCheckConfig gives micro-deposits three days and signatures
+// fifteen minutes, with three answers each
+func DefaultAddressCheckConfig() AddressCheckConfig {
+	return AddressCheckConfig{
+		RequiredAbove:      decimal.NewFromInt(1000),
+		NewFor:             30 * 24 * time.Hour,
+		MicroDepositTTL:    72 * time.Hour,
+		SignatureTTL:       15 * time.Minute,
+		MaxAttempts:        3,
+		ChallengeStatement: "Sign this message to verify you control this withdrawal address on your Stack account.",
+	}
+}
+
+// SetAddressChecks enables address verification for crypto recipients.
+// sender may be nil, in which case only signed messages can verify an
+// address.
+func (s *Service) SetAddressChecks(sender MicroDepositSender, config AddressCheckConfig) {
+	defaults := DefaultAddressCheckConfig()
+	if config.RequiredAbove.IsNegative() {
+		config.RequiredAbove = defaults.RequiredAbove
+	}
+	if config.MicroDepositTTL <= 0 {
+		config.MicroDepositTTL = defaults.MicroDepositTTL
+	}
+	if config.SignatureTTL <= 0 {
+		config.SignatureTTL = defaults.SignatureTTL
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.ChallengeStatement == "" {
+		config.ChallengeStatement = defaults.ChallengeStatement
+	}
+	s.sender = sender
+	s.addressCheck = &config
+}
+
+// ResolveForCryptoWithdrawal returns a crypto recipient the user may send
+// amount to now. Amounts above the threshold need a verified address while
+// the address is new.
+func (s *Service) ResolveForCryptoWithdrawal(ctx context.Context, userID, id uuid.UUID, amount decimal.Decimal) (*entities.WithdrawalRecipient, error) {
+	recipient, err := s.ResolveForWithdrawal(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Kind != entities.RecipientKindCrypto {
+		return nil, ErrNotCryptoRecipient
+	}
+	if s.addressCheck == nil || recipient.AddressCheckStatus == entities.AddressCheckVerified ||
+		!amount.GreaterThan(s.addressCheck.RequiredAbove) {
+		return recipient, nil
+	}
+	if s.addressCheck.NewFor > 0 && s.now().Sub(recipient.CreatedAt) >= s.addressCheck.NewFor {
+		return recipient, nil
+	}
+	return nil, ErrAddressCheckRequired
+}
+
+// StartAddressCheck begins verifying a crypto recipient. A micro-deposit
+// sends a small random amount the user must confirm; a signature check
+// returns the message to sign. Starting again replaces a pending signature
+// check; a micro-deposit cannot be replaced until its window ends.
+func (s *Service) StartAddressCheck(ctx context.Context, userID, id uuid.UUID, method entities.AddressCheckMethod) (*entities.WithdrawalRecipient, error) {
+	if s.addressCheck == nil {
+		return nil, ErrAddressCheckUnavailable
+	}
+	recipient, err := s.cryptoRecipient(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.AddressCheckStatus == entities.AddressCheckVerified {
+		return recipient, nil
+	}
+
+	// A micro-deposit stays the check until its window ends, so restarting
+	// cannot be used to send deposit after deposit
+	now := s.now()
+	if recipient.AddressCheckMethod == entities.AddressCheckMicroDeposit &&
+		recipient.AddressCheckExpiresAt != nil && now.Before(*recipient.AddressCheckExpiresAt) {
+		return nil, ErrMicroDepositPending
+	}
+
+	switch method {
+	case entities.AddressCheckMicroDeposit:
+		if s.sender == nil {
+			return nil, ErrAddressCheckUnavailable
+		}
+		amount, err := microDepositAmount()
+		if err != nil {
+			return nil, err
+		}
+		reference, err := s.sender.SendMicroDeposit(ctx, recipient.Chain, recipient.Address, amount, uuid.NewString())
+		if err != nil {
+			return nil, fmt.Errorf("failed to send verification deposit: %w", err)
+		}
+		expiresAt := now.Add(s.addressCheck.MicroDepositTTL)
+		recipient.AddressCheckAmount = decimal.NewNullDecimal(amount)
+		recipient.AddressCheckMessage = ""
+		recipient.AddressCheckReference = &reference
+		recipient.AddressCheckExpiresAt = &expiresAt
+	case entities.AddressCheckSignature:
+		message, err := s.signatureMessage(recipient, now)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := now.Add(s.addressCheck.SignatureTTL)
+		recipient.AddressCheckAmount = decimal.NullDecimal{}
+		recipient.AddressCheckMessage = message
+		recipient.AddressCheckReference = nil
+		recipient.AddressCheckExpiresAt = &expiresAt
+	default:
+		return nil, fmt.Errorf("%w: unknown method %q", ErrAddressCheckUnavailable, method)
+	}
+	recipient.AddressCheckStatus = entities.AddressCheckPending
+	recipient.AddressCheckMethod = method
+	recipient.AddressCheckAttempts = 0
+	if err := s.repo.SetAddressCheck(ctx, recipient); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Address check started",
+		zap.String("recipient_id", recipient.ID.String()),
+		zap.String("method", string(method)))
+	return recipient, nil
+}
+
+// ConfirmAddressCheck completes a pending check with the exact amount the
+// micro-deposit delivered or the signature of the check's message. Each
+// answer uses an attempt; the check fails when they run out.
+func (s *Service) ConfirmAddressCheck(ctx context.Context, userID, id uuid.UUID, req *entities.ConfirmAddressCheckRequest) (*entities.WithdrawalRecipient, error) {
+	if s.addressCheck == nil {
+		return nil, ErrAddressCheckUnavailable
+	}
+	recipient, err := s.cryptoRecipient(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch recipient.AddressCheckState(s.now()) {
+	case entities.AddressCheckPending:
+	case entities.AddressCheckExpired:
+		if recipient.AddressCheckStatus != entities.AddressCheckExpired {
+			recipient.AddressCheckStatus = entities.AddressCheckExpired
+			if err := s.repo.SetAddressCheck(ctx, recipient); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrAddressCheckExpired
+	case entities.AddressCheckFailed:
+		return nil, ErrAddressCheckFailed
+	default:
+		return nil, ErrAddressCheckNotPending
+	}
+
+	attempts, err := s.repo.UseAddressCheckAttempt(ctx, recipient.ID)
+	if errors.Is(err, entities.ErrRecipientNotFound) {
+		// Another answer closed the check first
+		return nil, ErrAddressCheckNotPending
+	}
+	if err != nil {
+		return nil, err
+	}
+	recipient.AddressCheckAttempts = attempts
+	if attempts > s.addressCheck.MaxAttempts {
+		return nil, s.failAddressCheck(ctx, recipient)
+	}
+
+	if !s.addressCheckAnswered(recipient, req) {
+		if attempts >= s.addressCheck.MaxAttempts {
+			return nil, s.failAddressCheck(ctx, recipient)
+		}
+		remaining := s.addressCheck.MaxAttempts - attempts
+		return nil, fmt.Errorf("%w; %d attempts left", ErrAddressCheckMismatch, remaining)
+	}
+
+	now := s.now()
+	recipient.AddressCheckStatus = entities.AddressCheckVerified
+	recipient.AddressCheckedAt = &now
+	if err := s.repo.SetAddressCheck(ctx, recipient); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Address check passed",
+		zap.String("recipient_id", recipient.ID.String()),
+		zap.String("method", string(recipient.AddressCheckMethod)))
+	return recipient, nil
+}
+
+func (s *Service) cryptoRecipient(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	recipient, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Kind != entities.RecipientKindCrypto {
+		return nil, ErrNotCryptoRecipient
+	}
+	if recipient.Status == entities.RecipientStatusFlagged {
+		return nil, ErrRecipientFlagged
+	}
+	return recipient, nil
+}
+
+func (s *Service) addressCheckAnswered(recipient *entities.WithdrawalRecipient, req *entities.ConfirmAddressCheckRequest) bool {
+	switch recipient.AddressCheckMethod {
+	case entities.AddressCheckMicroDeposit:
+		amount, err := decimal.NewFromString(strings.TrimSpace(req.Amount))
+		return err == nil && recipient.AddressCheckAmount.Valid && amount.Equal(recipient.AddressCheckAmount.Decimal)
+	case entities.AddressCheckSignature:
+		info, ok := entities.Chains().Lookup(recipient.Chain)
+		if !ok || req.Signature == "" {
+			return false
+		}
+		switch info.Family {
+		case entities.ChainFamilyEVM:
+			return crypto.VerifyEVMSignature(recipient.Address, recipient.AddressCheckMessage, req.Signature)
+		case entities.ChainFamilySolana:
+			return crypto.VerifySolanaSignature(recipient.Address, recipient.AddressCheckMessage, req.Signature)
+		}
+	}
+	return false
+}
+
+func (s *Service) failAddressCheck(ctx context.Context, recipient *entities.WithdrawalRecipient) error {
+	recipient.AddressCheckStatus = entities.AddressCheckFailed
+	if err := s.repo.SetAddressCheck(ctx, recipient); err != nil {
+		return err
+	}
+	s.logger.Warn("Address check failed",
+		zap.String("recipient_id", recipient.ID.String()),
+		zap.String("user_id", recipient.UserID.String()),
+		zap.String("method", string(recipient.AddressCheckMethod)))
+	return ErrAddressCheckFailed
+}
+
+// signatureMessage is the message the user signs with the address's key.
+// Its random nonce makes each check's message unique.
+func (s *Service) signatureMessage(recipient *entities.WithdrawalRecipient, issuedAt time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate address check nonce: %w", err)
+	}
+	return fmt.Sprintf("%s\n\nChain: %s\nAddress: %s\nRecipient: %s\nIssued At: %s\nNonce: %s",
+		s.addressCheck.ChallengeStatement, recipient.Chain, recipient.Address, recipient.ID,
+		issuedAt.UTC().Format(time.RFC3339), hex.EncodeToString(nonce)), nil
+}
+
+// microDepositAmount picks a random amount from 0.0100 to 0.0999, small
+// enough to cost little and with enough digits that it cannot be guessed in
+// a few attempts
+func microDepositAmount() (decimal.Decimal, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(900))
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to pick verification amount: %w", err)
+	}
+	return decimal.New(n.Int64()+100, -4), nil
+}
@@ -0,0 +1,265 @@
+package recipients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrInvalidDestination is returned for a malformed address or bank account
+	ErrInvalidDestination = errors.New("invalid withdrawal destination")
+	// ErrTooManyRecipients is returned when the address book is full
+	ErrTooManyRecipients = errors.New("address book is full")
+	// ErrRecipientFlagged is returned when withdrawing to a blocked recipient
+	ErrRecipientFlagged = errors.New("this recipient has been blocked; contact support")
+	// ErrRecipientOnHold is returned when withdrawing to a recipient still in its first-use hold
+	ErrRecipientOnHold = errors.New("new recipients can receive funds once their hold ends")
+)
+
+// Repository persists saved recipients
+type Repository interface {
+	Create(ctx context.Context, recipient *entities.WithdrawalRecipient) error
+	Get(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.WithdrawalRecipient, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WithdrawalRecipient, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int, error)
+	UpdateLabel(ctx context.Context, userID, id uuid.UUID, label string) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+	SetFlag(ctx context.Context, id uuid.UUID, flagged bool, reason string, adminID uuid.UUID, at time.Time) error
+	SetAccountCheck(ctx context.Context, id uuid.UUID, status entities.AccountCheckStatus, reason *string, at time.Time) error
+	SetAddressCheck(ctx context.Context, recipient *entities.WithdrawalRecipient) error
+	// UseAddressCheckAttempt counts an answer to the pending address check
+	// and returns the total, or ErrRecipientNotFound when none is pending
+	UseAddressCheckAttempt(ctx context.Context, id uuid.UUID) (int, error)
+	CountOtherUsers(ctx context.Context, recipient *entities.WithdrawalRecipient) (int, error)
+	ListShared(ctx context.Context, minUsers, limit int) ([]*entities.SharedDestination, error)
+}
+
+// Config controls first-use holds and address book size
+type Config struct {
+	FirstUseHold time.Duration // Wait after saving before a recipient can receive funds
+	MaxPerUser   int
+}
+
+// DefaultConfig returns the default address book configuration
+func DefaultConfig() Config {
+	return Config{
+		FirstUseHold: 24 * time.Hour,
+		MaxPerUser:   50,
+	}
+}
+
+// Service manages each user's saved withdrawal recipients
+type Service struct {
+	repo         Repository
+	config       Config
+	verifier     BankAccountVerifier
+	users        UserProfiles
+	accountCheck AccountCheckConfig
+	sender       MicroDepositSender
+	addressCheck *AddressCheckConfig
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewService creates a new recipients service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	if config.MaxPerUser <= 0 {
+		config.MaxPerUser = DefaultConfig().MaxPerUser
+	}
+	if config.FirstUseHold < 0 {
+		config.FirstUseHold = 0
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Create saves a recipient after validating its details. It starts
+// unverified and on hold for the configured first-use period.
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, req *entities.CreateRecipientRequest) (*entities.WithdrawalRecipient, error) {
+	now := time.Now()
+	recipient := &entities.WithdrawalRecipient{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Kind:      req.Kind,
+		Label:     strings.TrimSpace(req.Label),
+		Status:    entities.RecipientStatusUnverified,
+		HoldUntil: now.Add(s.config.FirstUseHold),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	switch req.Kind {
+	case entities.RecipientKindCrypto:
+		chain, ok := entities.Chains().Lookup(req.Chain)
+		if !ok || !chain.Enabled {
+			return nil, fmt.Errorf("%w: chain %q is not supported", ErrInvalidDestination, req.Chain)
+		}
+		address := strings.TrimSpace(req.Address)
+		if !chain.ValidAddress(address) {
+			return nil, fmt.Errorf("%w: not a valid %s address", ErrInvalidDestination, chain.Name)
+		}
+		recipient.Chain = string(chain.ID)
+		recipient.Address = address
+		recipient.AddressCheckStatus = entities.AddressCheckUnchecked
+	case entities.RecipientKindBank:
+		routing := strings.TrimSpace(req.RoutingNumber)
+		account := strings.TrimSpace(req.AccountNumber)
+		if !ValidRoutingNumber(routing) {
+			return nil, fmt.Errorf("%w: invalid routing number", ErrInvalidDestination)
+		}
+		if len(account) < 4 || len(account) > 17 || strings.Trim(account, "0123456789") != "" {
+			return nil, fmt.Errorf("%w: account number must be 4 to 17 digits", ErrInvalidDestination)
+		}
+		holder := strings.TrimSpace(req.AccountHolderName)
+		if holder == "" {
+			return nil, fmt.Errorf("%w: account holder name is required", ErrInvalidDestination)
+		}
+		recipient.BankName = strings.TrimSpace(req.BankName)
+		recipient.AccountHolderName = holder
+		recipient.RoutingNumber = routing
+		recipient.AccountNumber = account
+		recipient.AccountLast4 = account[len(account)-4:]
+		recipient.AccountCheckStatus = entities.AccountCheckUnchecked
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidDestination, req.Kind)
+	}
+
+	count, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= s.config.MaxPerUser {
+		return nil, ErrTooManyRecipients
+	}
+
+	if err := s.repo.Create(ctx, recipient); err != nil {
+		return nil, err
+	}
+
+	// Bank accounts are checked up front so the user learns of a problem
+	// before asking for a payout; a provider outage leaves them unchecked
+	if recipient.Kind == entities.RecipientKindBank && s.verifier != nil {
+		if err := s.checkAccount(ctx, recipient); err != nil {
+			s.logger.Warn("Failed to check saved bank recipient",
+				zap.String("recipient_id", recipient.ID.String()), zap.Error(err))
+		}
+	}
+
+	// Destinations already saved by other accounts are left for admins to
+	// review through the shared destinations report
+	if others, err := s.repo.CountOtherUsers(ctx, recipient); err != nil {
+		s.logger.Warn("Failed to check recipient for shared destinations", zap.Error(err))
+	} else if others > 0 {
+		s.logger.Warn("Saved recipient matches other accounts",
+			zap.String("recipient_id", recipient.ID.String()),
+			zap.String("user_id", userID.String()),
+			zap.Int("other_accounts", others))
+	}
+	return recipient, nil
+}
+
+// List returns the user's saved recipients
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*entities.WithdrawalRecipient, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Get returns one of the user's saved recipients
+func (s *Service) Get(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+// Rename changes a recipient's label
+func (s *Service) Rename(ctx context.Context, userID, id uuid.UUID, label string) (*entities.WithdrawalRecipient, error) {
+	if err := s.repo.UpdateLabel(ctx, userID, id, strings.TrimSpace(label)); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, userID, id)
+}
+
+// Remove deletes a recipient from the user's address book
+func (s *Service) Remove(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// ResolveForWithdrawal returns a recipient the user may withdraw to now
+func (s *Service) ResolveForWithdrawal(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	recipient, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.Status == entities.RecipientStatusFlagged {
+		return nil, ErrRecipientFlagged
+	}
+	if recipient.OnHold(time.Now()) {
+		return nil, fmt.Errorf("%w (until %s)", ErrRecipientOnHold, recipient.HoldUntil.UTC().Format(time.RFC3339))
+	}
+	return recipient, nil
+}
+
+// MarkUsed records a withdrawal to the recipient. The first one verifies it.
+func (s *Service) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	return s.repo.MarkUsed(ctx, id, time.Now())
+}
+
+// ListShared returns destinations saved by at least minUsers accounts
+func (s *Service) ListShared(ctx context.Context, minUsers, limit int) ([]*entities.SharedDestination, error) {
+	if minUsers < 2 {
+		minUsers = 2
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.ListShared(ctx, minUsers, limit)
+}
+
+// Flag blocks withdrawals to a recipient
+func (s *Service) Flag(ctx context.Context, adminID, id uuid.UUID, reason string) (*entities.WithdrawalRecipient, error) {
+	if err := s.repo.SetFlag(ctx, id, true, strings.TrimSpace(reason), adminID, time.Now()); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Withdrawal recipient flagged",
+		zap.String("recipient_id", id.String()),
+		zap.String("admin_id", adminID.String()))
+	return s.repo.GetByID(ctx, id)
+}
+
+// Unflag lifts an admin block. The recipient returns to unverified and is
+// verified again by its next withdrawal.
+func (s *Service) Unflag(ctx context.Context, adminID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	if err := s.repo.SetFlag(ctx, id, false, "", adminID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// ValidRoutingNumber checks an ABA routing number's length and checksum
+func ValidRoutingNumber(routing string) bool {
+	if len(routing) != 9 || strings.Trim(routing, "0123456789") != "" {
+		return false
+	}
+	weights := [9]int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i, digit := range routing {
+		sum += int(digit-'0') * weights[i]
+	}
+	return sum%10 == 0
+}
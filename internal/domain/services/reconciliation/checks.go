@@ -96,6 +96,16 @@ func (s *Service) CheckLedgerConsistency(ctx context.Context, reportID uuid.UUID
 		result.Exceptions = append(result.Exceptions, *exception)
 	}
 
+	// 4. Report manual adjustments apart from system postings, so reviewers
+	// can tell corrections from drift
+	adjustmentCount, adjustmentTotal, err := s.ledgerRepo.SummarizeAdjustments(ctx, startTime.Add(-24*time.Hour))
+	if err != nil {
+		s.logger.Error("Failed to summarize ledger adjustments", "error", err)
+	} else {
+		result.Metadata["manual_adjustments_24h"] = adjustmentCount
+		result.Metadata["manual_adjustment_total_24h"] = adjustmentTotal.String()
+	}
+
 	result.Passed = len(result.Exceptions) == 0
 	result.ExecutionTime = time.Since(startTime)
 
@@ -129,13 +139,15 @@ func (s *Service) CheckCircleBalance(ctx context.Context, reportID uuid.UUID) (*
 	}
 
 	// Get Circle wallet balances
-	circleBalance, err := s.circleClient.GetTotalUSDCBalance(ctx)
+	circleAggregate, err := s.circleClient.GetTotalUSDCBalance(ctx)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to get Circle balance: %v", err)
 		result.ExecutionTime = time.Since(startTime)
 		span.RecordError(err)
 		return result, err
 	}
+	circleBalance := circleAggregate.Total
+	recordAggregate(result, "wallets", circleAggregate)
 
 	result.ExpectedValue = ledgerBalance
 	result.ActualValue = circleBalance
@@ -158,6 +170,7 @@ func (s *Service) CheckCircleBalance(ctx context.Context, reportID uuid.UUID) (*
 		exception.Metadata["ledger_balance"] = ledgerBalance.String()
 		exception.Metadata["circle_balance"] = circleBalance.String()
 		exception.Metadata["tolerance"] = tolerance.String()
+		exception.Metadata["wallets_skipped"] = circleAggregate.Skipped
 		result.Exceptions = append(result.Exceptions, *exception)
 	}
 
@@ -195,13 +208,15 @@ func (s *Service) CheckAlpacaBalance(ctx context.Context, reportID uuid.UUID) (*
 	}
 
 	// Get total buying power from Alpaca
-	alpacaBuyingPower, err := s.alpacaClient.GetTotalBuyingPower(ctx)
+	alpacaAggregate, err := s.alpacaClient.GetTotalBuyingPower(ctx)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to get Alpaca buying power: %v", err)
 		result.ExecutionTime = time.Since(startTime)
 		span.RecordError(err)
 		return result, err
 	}
+	alpacaBuyingPower := alpacaAggregate.Total
+	recordAggregate(result, "accounts", alpacaAggregate)
 
 	result.ExpectedValue = totalFiatExposure
 	result.ActualValue = alpacaBuyingPower
@@ -224,6 +239,7 @@ func (s *Service) CheckAlpacaBalance(ctx context.Context, reportID uuid.UUID) (*
 		exception.Metadata["ledger_fiat_exposure"] = totalFiatExposure.String()
 		exception.Metadata["alpaca_buying_power"] = alpacaBuyingPower.String()
 		exception.Metadata["tolerance"] = tolerance.String()
+		exception.Metadata["accounts_skipped"] = alpacaAggregate.Skipped
 		result.Exceptions = append(result.Exceptions, *exception)
 	}
 
@@ -422,3 +438,13 @@ func (s *Service) CheckWithdrawals(ctx context.Context, reportID uuid.UUID) (*en
 
 	return result, nil
 }
+
+// recordAggregate notes how many provider accounts were summed and which were
+// skipped, so a failed check can be told apart from incomplete data
+func recordAggregate(result *entities.ReconciliationCheckResult, unit string, aggregate *entities.BalanceAggregate) {
+	result.Metadata[unit+"_counted"] = aggregate.Counted
+	result.Metadata[unit+"_skipped"] = aggregate.Skipped
+	if len(aggregate.SkippedIDs) > 0 {
+		result.Metadata["skipped_ids"] = aggregate.SkippedIDs
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"time"
 
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // Scheduler handles automated reconciliation runs
 type Scheduler struct {
 	service *Service
 	logger  *logger.Logger
+	tracker *workerstatus.Tracker
 
 	// Cron intervals
 	hourlyInterval time.Duration
@@ -49,6 +51,12 @@ func NewScheduler(service *Service, logger *logger.Logger, config *SchedulerConf
 	}
 }
 
+// SetTracker reports scheduled runs to the worker registry. Pausing the
+// tracker skips scheduled runs; manual runs are unaffected.
+func (s *Scheduler) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
 // Start begins the reconciliation scheduler
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -180,6 +188,12 @@ func (s *Scheduler) calculateNextDailyRun(from time.Time) time.Time {
 
 // executeReconciliation safely executes a reconciliation run
 func (s *Scheduler) executeReconciliation(ctx context.Context, runType string) {
+	finish, ok := s.tracker.Begin()
+	if !ok {
+		s.logger.Info("Reconciliation paused, skipping scheduled run", "run_type", runType)
+		return
+	}
+
 	s.logger.Info("Starting scheduled reconciliation", "run_type", runType)
 
 	// Create a timeout context for the reconciliation
@@ -189,6 +203,7 @@ func (s *Scheduler) executeReconciliation(ctx context.Context, runType string) {
 	startTime := time.Now()
 
 	report, err := s.service.RunReconciliation(reconciliationCtx, runType)
+	finish(err)
 	if err != nil {
 		s.logger.Error("Scheduled reconciliation failed",
 			"run_type", runType,
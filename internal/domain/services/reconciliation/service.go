@@ -32,6 +32,7 @@ type Service struct {
 	// Observability
 	logger         *logger.Logger
 	metricsService MetricsService
+	alerter        Alerter
 
 	// Configuration
 	config *Config
@@ -43,7 +44,6 @@ type Config struct {
 	ToleranceCircle        decimal.Decimal
 	ToleranceAlpaca        decimal.Decimal
 	EnableAlerting         bool
-	AlertWebhookURL        string
 }
 
 // LedgerService interface for ledger operations
@@ -54,12 +54,12 @@ type LedgerService interface {
 
 // CircleClient interface for Circle API operations
 type CircleClient interface {
-	GetTotalUSDCBalance(ctx context.Context) (decimal.Decimal, error)
+	GetTotalUSDCBalance(ctx context.Context) (*entities.BalanceAggregate, error)
 }
 
 // AlpacaClient interface for Alpaca API operations
 type AlpacaClient interface {
-	GetTotalBuyingPower(ctx context.Context) (decimal.Decimal, error)
+	GetTotalBuyingPower(ctx context.Context) (*entities.BalanceAggregate, error)
 }
 
 // MetricsService interface for metrics operations
@@ -72,6 +72,11 @@ type MetricsService interface {
 	RecordDiscrepancyAmount(checkType string, amount decimal.Decimal)
 }
 
+// Alerter routes alerts to on-call channels
+type Alerter interface {
+	Notify(ctx context.Context, alert *entities.Alert) ([]*entities.AlertDelivery, error)
+}
+
 // NewService creates a new reconciliation service
 func NewService(
 	reconciliationRepo repositories.ReconciliationRepository,
@@ -101,6 +106,11 @@ func NewService(
 	}
 }
 
+// SetAlerter routes high and critical exceptions to on-call channels
+func (s *Service) SetAlerter(alerter Alerter) {
+	s.alerter = alerter
+}
+
 // RunReconciliation executes a full reconciliation run
 func (s *Service) RunReconciliation(ctx context.Context, runType string) (*entities.ReconciliationReport, error) {
 	ctx, span := otel.Tracer("reconciliation.service").Start(ctx, "RunReconciliation")
@@ -297,12 +307,9 @@ func (s *Service) sendAlerts(ctx context.Context, exceptions []*entities.Reconci
 		)
 	}
 
-	// Send webhook notification (if configured)
-	if s.config.AlertWebhookURL != "" {
-		go s.sendWebhookAlert(ctx, highPriorityExceptions)
+	if s.alerter != nil {
+		go s.routeAlerts(context.WithoutCancel(ctx), highPriorityExceptions)
 	}
-
-	// TODO: Integrate with PagerDuty, Slack, or other alerting systems
 }
 
 // filterHighPriorityExceptions filters exceptions by severity
@@ -316,10 +323,34 @@ func (s *Service) filterHighPriorityExceptions(exceptions []*entities.Reconcilia
 	return highPriority
 }
 
-// sendWebhookAlert sends a webhook notification for exceptions
-func (s *Service) sendWebhookAlert(ctx context.Context, exceptions []*entities.ReconciliationException) {
-	// TODO: Implement webhook alerting
-	s.logger.Info("Would send webhook alert", "exceptions_count", len(exceptions))
+// routeAlerts raises one alert per exception. Critical exceptions page
+// on-call; high ones are warnings. The key includes the severity, so an
+// exception that escalates is alerted again inside the dedup window.
+func (s *Service) routeAlerts(ctx context.Context, exceptions []*entities.ReconciliationException) {
+	for _, exception := range exceptions {
+		severity := entities.AlertSeverityWarning
+		if exception.Severity == entities.ExceptionSeverityCritical {
+			severity = entities.AlertSeverityCritical
+		}
+		alert := &entities.Alert{
+			Source:   "reconciliation",
+			Severity: severity,
+			DedupKey: fmt.Sprintf("reconciliation:%s:%s", exception.CheckType, exception.Severity),
+			Summary:  exception.Description,
+			Details: map[string]string{
+				"check_type": string(exception.CheckType),
+				"expected":   exception.ExpectedValue.String(),
+				"actual":     exception.ActualValue.String(),
+				"difference": exception.Difference.String(),
+				"currency":   exception.Currency,
+				"report_id":  exception.ReportID.String(),
+			},
+			OccurredAt: exception.CreatedAt,
+		}
+		if _, err := s.alerter.Notify(ctx, alert); err != nil {
+			s.logger.Error("Failed to route reconciliation alert", "check_type", exception.CheckType, "error", err)
+		}
+	}
 }
 
 // recordMetrics records reconciliation metrics
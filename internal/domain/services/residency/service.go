@@ -0,0 +1,157 @@
+package residency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 500
+)
+
+// Repository persists residency tags and finds records stored out of region
+type Repository interface {
+	// Get returns nil when the user has no tag
+	Get(ctx context.Context, userID uuid.UUID) (*entities.UserResidency, error)
+	Upsert(ctx context.Context, residency *entities.UserResidency) error
+	ListOutOfRegion(ctx context.Context, home entities.ResidencyRegion, limit, offset int) ([]*entities.ResidencyViolation, error)
+}
+
+// CountrySource returns the country a user onboarded from
+type CountrySource interface {
+	UserCountry(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// Service tags each user with the region their KYC files and statements must
+// be stored in. A user is tagged from their onboarding country the first
+// time their region is needed, and keeps that tag if the country is edited
+// later; admins can pin a user to a region instead. The home region is where
+// the database and the default upload bucket are.
+type Service struct {
+	repo      Repository
+	countries CountrySource
+	home      entities.ResidencyRegion
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates a residency service for a deployment whose own storage
+// is in home
+func NewService(repo Repository, countries CountrySource, home entities.ResidencyRegion, logger *zap.Logger) *Service {
+	if !home.IsValid() {
+		home = entities.ResidencyRegionUS
+	}
+	return &Service{
+		repo:      repo,
+		countries: countries,
+		home:      home,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// HomeRegion returns the region of the deployment's own storage
+func (s *Service) HomeRegion() entities.ResidencyRegion {
+	return s.home
+}
+
+// Region returns the region the user's data must be stored in
+func (s *Service) Region(ctx context.Context, userID uuid.UUID) (entities.ResidencyRegion, error) {
+	residency, err := s.Residency(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if !residency.Tagged && residency.CountryCode != "" {
+		residency.Tagged = true
+		if err := s.repo.Upsert(ctx, residency); err != nil {
+			return "", err
+		}
+	}
+	return residency.Region, nil
+}
+
+// Residency returns the user's tag, or the region their country maps to
+// when they have none yet
+func (s *Service) Residency(ctx context.Context, userID uuid.UUID) (*entities.UserResidency, error) {
+	residency, err := s.repo.Get(ctx, userID)
+	if err != nil || residency != nil {
+		return residency, err
+	}
+	country, err := s.countries.UserCountry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	country = entities.NormalizeCountryCode(country)
+	return &entities.UserResidency{
+		UserID:      userID,
+		Region:      entities.ResidencyRegionForCountry(country, s.home),
+		Source:      entities.ResidencySourceCountry,
+		CountryCode: country,
+		UpdatedAt:   s.now().UTC(),
+	}, nil
+}
+
+// SetRegion pins a user to a region. Files already stored elsewhere are not
+// moved; they show in OutOfRegion until they are.
+func (s *Service) SetRegion(ctx context.Context, userID uuid.UUID, req *entities.SetResidencyRequest, adminID uuid.UUID) (*entities.UserResidency, error) {
+	if !req.Region.IsValid() {
+		return nil, fmt.Errorf("%w: unknown region %q", entities.ErrInvalidResidency, req.Region)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", entities.ErrInvalidResidency)
+	}
+	current, err := s.Residency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	residency := &entities.UserResidency{
+		UserID:      userID,
+		Region:      req.Region,
+		Source:      entities.ResidencySourceAdmin,
+		CountryCode: current.CountryCode,
+		Reason:      reason,
+		SetBy:       &adminID,
+		UpdatedAt:   s.now().UTC(),
+		Tagged:      true,
+	}
+	if err := s.repo.Upsert(ctx, residency); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User data residency set",
+		zap.String("user_id", userID.String()),
+		zap.String("from_region", string(current.Region)),
+		zap.String("to_region", string(residency.Region)),
+		zap.String("admin_id", adminID.String()))
+	return residency, nil
+}
+
+// OutOfRegion reports uploads and documents stored outside their user's
+// region, newest first
+func (s *Service) OutOfRegion(ctx context.Context, limit, offset int) ([]*entities.ResidencyViolation, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListOutOfRegion(ctx, s.home, limit, offset)
+}
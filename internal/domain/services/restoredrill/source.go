@@ -0,0 +1,98 @@
+package restoredrill
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Backup identifies a database backup artifact
+type Backup struct {
+	Path      string    `json:"path"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupSource locates the most recent backup
+type BackupSource interface {
+	Latest(ctx context.Context) (*Backup, error)
+}
+
+// Restorer restores a backup into the scratch database
+type Restorer interface {
+	Restore(ctx context.Context, backup *Backup) error
+}
+
+// DirectoryBackupSource picks the newest file matching Pattern in Dir, which is
+// where the scheduled pg_dump job writes custom-format archives
+type DirectoryBackupSource struct {
+	Dir     string
+	Pattern string
+}
+
+// Latest returns the newest backup in the directory
+func (s DirectoryBackupSource) Latest(ctx context.Context) (*Backup, error) {
+	pattern := s.Pattern
+	if pattern == "" {
+		pattern = "*.dump"
+	}
+	matches, err := filepath.Glob(filepath.Join(s.Dir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup pattern: %w", err)
+	}
+
+	var latest *Backup
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if latest == nil || info.ModTime().After(latest.CreatedAt) {
+			latest = &Backup{Path: path, SizeBytes: info.Size(), CreatedAt: info.ModTime().UTC()}
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no backups matching %s found in %s", pattern, s.Dir)
+	}
+	return latest, nil
+}
+
+// PgRestoreRestorer restores custom-format archives with pg_restore. The target
+// must be a dedicated scratch database: existing objects are dropped first.
+type PgRestoreRestorer struct {
+	TargetURL string
+	Binary    string
+}
+
+// Restore replaces the scratch database contents with the backup
+func (r PgRestoreRestorer) Restore(ctx context.Context, backup *Backup) error {
+	if r.TargetURL == "" {
+		return fmt.Errorf("scratch database URL is not configured")
+	}
+	binary := r.Binary
+	if binary == "" {
+		binary = "pg_restore"
+	}
+
+	cmd := exec.CommandContext(ctx, binary,
+		"--clean", "--if-exists",
+		"--no-owner", "--no-privileges",
+		"--exit-on-error",
+		"--dbname", r.TargetURL,
+		backup.Path,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("pg_restore failed: %w: %s", err, truncate(string(output), 2000))
+	}
+	return nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,142 @@
+package retention
+
+import "time"
+
+// Action determines what happens to rows past their retention period
+type Action string
+
+const (
+	// ActionPurge deletes expired rows
+	ActionPurge Action = "purge"
+	// ActionAnonymize strips sensitive columns but keeps the row for reporting
+	ActionAnonymize Action = "anonymize"
+	// ActionAuditPrune removes audit entries through the hash-chain aware pruner
+	ActionAuditPrune Action = "audit_prune"
+)
+
+// Policy describes how long rows in a table are kept
+type Policy struct {
+	Name            string        `json:"name"`
+	Table           string        `json:"table"`
+	TimestampColumn string        `json:"timestamp_column"`
+	Retention       time.Duration `json:"retention"`
+	// RetentionYears is used instead of Retention for calendar-based periods
+	RetentionYears int    `json:"retention_years,omitempty"`
+	Action         Action `json:"action"`
+	// Condition is an additional SQL predicate rows must match to be eligible
+	Condition string `json:"condition,omitempty"`
+	// AnonymizeSet is the SET clause applied by ActionAnonymize; Condition must
+	// exclude rows that were already anonymized so batches terminate
+	AnonymizeSet string `json:"anonymize_set,omitempty"`
+	Description  string `json:"description"`
+}
+
+const day = 24 * time.Hour
+
+// Cutoff returns the timestamp before which rows are eligible. Calendar periods
+// get an extra day of margin so clock skew with the database never brings the
+// cutoff inside the window enforced by the audit log trigger.
+func (p Policy) Cutoff(now time.Time) time.Time {
+	if p.RetentionYears > 0 {
+		return now.AddDate(-p.RetentionYears, 0, -1)
+	}
+	return now.Add(-p.Retention)
+}
+
+// DefaultPolicies encodes the platform's retention requirements. Verification
+// codes live in Redis with a 10 minute TTL and need no database policy.
+func DefaultPolicies() []Policy {
+	return []Policy{
+		{
+			Name:            "password_reset_tokens",
+			Table:           "password_reset_tokens",
+			TimestampColumn: "expires_at",
+			Retention:       day,
+			Action:          ActionPurge,
+			Description:     "Password reset tokens are deleted 24 hours after expiry",
+		},
+		{
+			Name:            "expired_sessions",
+			Table:           "sessions",
+			TimestampColumn: "expires_at",
+			Retention:       30 * day,
+			Action:          ActionPurge,
+			Description:     "Sessions are deleted 30 days after expiry",
+		},
+		{
+			Name:            "idempotency_keys",
+			Table:           "idempotency_keys",
+			TimestampColumn: "expires_at",
+			Retention:       day,
+			Action:          ActionPurge,
+			Description:     "Idempotency keys are deleted 24 hours after expiry",
+		},
+		{
+			Name:            "user_rate_limits",
+			Table:           "user_rate_limits",
+			TimestampColumn: "updated_at",
+			Retention:       7 * day,
+			Action:          ActionPurge,
+			Description:     "Rate limit counters idle for 7 days are deleted",
+		},
+		{
+			Name:            "due_webhook_payloads",
+			Table:           "due_webhook_events",
+			TimestampColumn: "created_at",
+			Retention:       90 * day,
+			Action:          ActionPurge,
+			Condition:       "processed = TRUE",
+			Description:     "Processed Due webhook payloads are deleted after 90 days",
+		},
+		{
+			Name:            "funding_webhook_payloads",
+			Table:           "funding_event_jobs",
+			TimestampColumn: "completed_at",
+			Retention:       90 * day,
+			Action:          ActionAnonymize,
+			Condition:       "status = 'completed' AND webhook_payload IS NOT NULL",
+			AnonymizeSet:    "webhook_payload = NULL",
+			Description:     "Raw webhook payloads on completed funding jobs are removed after 90 days",
+		},
+		{
+			Name:            "notifications",
+			Table:           "notifications",
+			TimestampColumn: "created_at",
+			Retention:       365 * day,
+			Action:          ActionPurge,
+			Description:     "Notifications are deleted after one year",
+		},
+		{
+			Name:            "http_captures",
+			Table:           "http_captures",
+			TimestampColumn: "expires_at",
+			Retention:       time.Hour,
+			Action:          ActionPurge,
+			Description:     "Debug request captures are deleted once their TTL has passed",
+		},
+		{
+			Name:            "http_capture_targets",
+			Table:           "http_capture_targets",
+			TimestampColumn: "expires_at",
+			Retention:       day,
+			Action:          ActionPurge,
+			Description:     "Expired debug capture targets are deleted after a day",
+		},
+		{
+			Name:            "captured_messages",
+			Table:           "captured_messages",
+			TimestampColumn: "created_at",
+			Retention:       30 * day,
+			Action:          ActionPurge,
+			Description:     "Sandbox-captured emails and SMS are deleted after 30 days",
+		},
+		{
+			Name:            "audit_logs",
+			Table:           "audit_logs",
+			TimestampColumn: "at",
+			RetentionYears:  7,
+			Action:          ActionAuditPrune,
+			Description:     "Audit logs are kept for 7 years, then pruned with a chain checkpoint",
+		},
+	}
+}
@@ -0,0 +1,278 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// AuditPruner removes audit entries without breaking the audit hash chain
+type AuditPruner interface {
+	PruneChain(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error)
+}
+
+// Config holds retention engine configuration
+type Config struct {
+	DryRun    bool
+	BatchSize int
+	Interval  time.Duration
+}
+
+// PolicyResult is the outcome of applying a single policy
+type PolicyResult struct {
+	Policy       string    `json:"policy"`
+	Table        string    `json:"table"`
+	Action       Action    `json:"action"`
+	Cutoff       time.Time `json:"cutoff"`
+	RowsAffected int64     `json:"rows_affected"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Report summarizes a retention run. In dry-run mode RowsAffected is the number
+// of rows that would have been purged or anonymized.
+type Report struct {
+	DryRun      bool           `json:"dry_run"`
+	StartedAt   time.Time      `json:"started_at"`
+	CompletedAt time.Time      `json:"completed_at"`
+	Results     []PolicyResult `json:"results"`
+}
+
+// RunRecord is a persisted policy execution
+type RunRecord struct {
+	ID           uuid.UUID `json:"id"`
+	PolicyName   string    `json:"policy_name"`
+	TableName    string    `json:"table_name"`
+	Action       string    `json:"action"`
+	DryRun       bool      `json:"dry_run"`
+	Cutoff       time.Time `json:"cutoff"`
+	RowsAffected int64     `json:"rows_affected"`
+	ErrorMessage *string   `json:"error_message,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	CompletedAt  time.Time `json:"completed_at"`
+}
+
+// Service enforces retention policies by purging or anonymizing expired rows
+type Service struct {
+	db          *sql.DB
+	policies    []Policy
+	auditPruner AuditPruner
+	config      Config
+	logger      *logger.Logger
+	mu          sync.Mutex
+	tracker     *workerstatus.Tracker
+}
+
+// NewService creates a new retention service
+func NewService(db *sql.DB, policies []Policy, auditPruner AuditPruner, config Config, logger *logger.Logger) *Service {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	if config.Interval <= 0 {
+		config.Interval = 24 * time.Hour
+	}
+	return &Service{
+		db:          db,
+		policies:    policies,
+		auditPruner: auditPruner,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// Policies returns the configured retention policies
+func (s *Service) Policies() []Policy {
+	return s.policies
+}
+
+// Run applies every policy once. A failing policy is recorded in the report and
+// does not stop the remaining policies.
+func (s *Service) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	if !s.mu.TryLock() {
+		return nil, fmt.Errorf("retention run already in progress")
+	}
+	defer s.mu.Unlock()
+
+	report := &Report{DryRun: dryRun, StartedAt: time.Now().UTC()}
+	for _, policy := range s.policies {
+		started := time.Now().UTC()
+		cutoff := policy.Cutoff(started)
+
+		affected, err := s.apply(ctx, policy, cutoff, dryRun)
+		result := PolicyResult{
+			Policy:       policy.Name,
+			Table:        policy.Table,
+			Action:       policy.Action,
+			Cutoff:       cutoff,
+			RowsAffected: affected,
+		}
+		if err != nil {
+			result.Error = err.Error()
+			s.logger.Error("Retention policy failed", "policy", policy.Name, "error", err)
+		} else if affected > 0 {
+			s.logger.Info("Retention policy applied",
+				"policy", policy.Name,
+				"action", policy.Action,
+				"dry_run", dryRun,
+				"rows", affected,
+			)
+		}
+		report.Results = append(report.Results, result)
+
+		// Record the outcome even when the run was cancelled, so a policy cut
+		// off partway still shows up in the run history
+		if err := s.recordRun(context.WithoutCancel(ctx), policy, result, dryRun, started); err != nil {
+			s.logger.Warn("Failed to record retention run", "policy", policy.Name, "error", err)
+		}
+	}
+	report.CompletedAt = time.Now().UTC()
+	return report, nil
+}
+
+// SetTracker lets operators observe and pause scheduled retention runs
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Start runs the retention engine on the configured interval until the context is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.Run(ctx, s.config.DryRun)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Scheduled retention run skipped", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// ListRuns returns recent retention executions, newest first
+func (s *Service) ListRuns(ctx context.Context, limit int) ([]RunRecord, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, policy_name, table_name, action, dry_run, cutoff,
+		       rows_affected, error_message, started_at, completed_at
+		FROM data_retention_runs
+		ORDER BY started_at DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []RunRecord
+	for rows.Next() {
+		var run RunRecord
+		if err := rows.Scan(&run.ID, &run.PolicyName, &run.TableName, &run.Action, &run.DryRun,
+			&run.Cutoff, &run.RowsAffected, &run.ErrorMessage, &run.StartedAt, &run.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retention run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (s *Service) apply(ctx context.Context, policy Policy, cutoff time.Time, dryRun bool) (int64, error) {
+	switch policy.Action {
+	case ActionAuditPrune:
+		if s.auditPruner == nil {
+			return 0, fmt.Errorf("audit pruner not configured")
+		}
+		return s.auditPruner.PruneChain(ctx, cutoff, dryRun)
+	case ActionPurge, ActionAnonymize:
+		if dryRun {
+			return s.countEligible(ctx, policy, cutoff)
+		}
+		return s.applyInBatches(ctx, policy, cutoff)
+	default:
+		return 0, fmt.Errorf("unknown retention action %q", policy.Action)
+	}
+}
+
+func (s *Service) countEligible(ctx context.Context, policy Policy, cutoff time.Time) (int64, error) {
+	var count int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, policy.Table, eligibility(policy))
+	if err := s.db.QueryRowContext(ctx, query, cutoff).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count eligible rows in %s: %w", policy.Table, err)
+	}
+	return count, nil
+}
+
+// applyInBatches purges or anonymizes eligible rows in bounded batches so a large
+// backlog does not hold long locks on hot tables
+func (s *Service) applyInBatches(ctx context.Context, policy Policy, cutoff time.Time) (int64, error) {
+	var query string
+	selection := fmt.Sprintf(`SELECT ctid FROM %s WHERE %s LIMIT $2`, policy.Table, eligibility(policy))
+	if policy.Action == ActionPurge {
+		query = fmt.Sprintf(`DELETE FROM %s WHERE ctid IN (%s)`, policy.Table, selection)
+	} else {
+		if policy.AnonymizeSet == "" {
+			return 0, fmt.Errorf("anonymize policy %s has no SET clause", policy.Name)
+		}
+		query = fmt.Sprintf(`UPDATE %s SET %s WHERE ctid IN (%s)`, policy.Table, policy.AnonymizeSet, selection)
+	}
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		result, err := s.db.ExecContext(ctx, query, cutoff, s.config.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to apply retention to %s: %w", policy.Table, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to read affected rows for %s: %w", policy.Table, err)
+		}
+		total += affected
+		if affected < int64(s.config.BatchSize) {
+			return total, nil
+		}
+	}
+}
+
+func (s *Service) recordRun(ctx context.Context, policy Policy, result PolicyResult, dryRun bool, started time.Time) error {
+	var errMsg *string
+	if result.Error != "" {
+		errMsg = &result.Error
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO data_retention_runs (
+			id, policy_name, table_name, action, dry_run, cutoff,
+			rows_affected, error_message, started_at, completed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		uuid.New(), policy.Name, policy.Table, string(policy.Action), dryRun, result.Cutoff,
+		result.RowsAffected, errMsg, started, time.Now().UTC())
+	return err
+}
+
+// eligibility builds the WHERE clause for a policy; $1 is the cutoff
+func eligibility(policy Policy) string {
+	clause := fmt.Sprintf("%s < $1", policy.TimestampColumn)
+	if policy.Condition != "" {
+		clause += " AND (" + policy.Condition + ")"
+	}
+	return clause
+}
@@ -0,0 +1,733 @@
+package rewards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+var (
+	// ErrInvalidRewardRule is returned for rule definitions the service rejects
+	ErrInvalidRewardRule = errors.New("invalid reward rule")
+	// ErrInvalidRedemption is returned for redemptions the service rejects
+	ErrInvalidRedemption = errors.New("invalid reward redemption")
+)
+
+const (
+	summaryRewards     = 50
+	summaryRedemptions = 20
+	defaultPageSize    = 100
+	maxPageSize        = 500
+)
+
+// Repository persists earning rules, rewards and redemptions
+type Repository interface {
+	CreateRule(ctx context.Context, rule *entities.RewardRule) error
+	ListRules(ctx context.Context) ([]*entities.RewardRule, error)
+	ListRunning(ctx context.Context, trigger entities.RewardTrigger, at time.Time) ([]*entities.RewardRule, error)
+	SetRuleActive(ctx context.Context, id uuid.UUID, active bool) (*entities.RewardRule, error)
+	EarnedSince(ctx context.Context, userID uuid.UUID, since time.Time) (decimal.Decimal, error)
+	// ClaimReward inserts a reward unless it takes the user's earnings since
+	// since past limit. It returns ErrRewardAlreadyEarned or ErrRewardCapReached.
+	ClaimReward(ctx context.Context, reward *entities.Reward, since time.Time, limit decimal.Decimal) error
+	// ReleaseReward deletes a reward whose ledger posting failed
+	ReleaseReward(ctx context.Context, id uuid.UUID) error
+	SetRewardLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error
+	GetReward(ctx context.Context, id uuid.UUID) (*entities.Reward, error)
+	// UpdateRewardStatus moves a reward from one status to reward.Status and
+	// reports whether this caller did so
+	UpdateRewardStatus(ctx context.Context, reward *entities.Reward, from entities.RewardStatus) (bool, error)
+	SetClawbackLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error
+	ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.Reward, error)
+	ListHeld(ctx context.Context, limit, offset int) ([]*entities.Reward, error)
+	// ListUnsettledForBasket returns the user's rewards on a basket that are
+	// held or still in their hold period
+	ListUnsettledForBasket(ctx context.Context, userID, basketID uuid.UUID, now time.Time) ([]*entities.Reward, error)
+	Balance(ctx context.Context, userID uuid.UUID, now time.Time) (*entities.RewardBalance, error)
+	// CreateRedemption inserts a pending redemption, or returns
+	// ErrInsufficientRewards when the user's available rewards fall short
+	CreateRedemption(ctx context.Context, redemption *entities.RewardRedemption) error
+	UpdateRedemption(ctx context.Context, redemption *entities.RewardRedemption) error
+	ListRedemptions(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.RewardRedemption, error)
+	IsSweepOrder(ctx context.Context, orderID uuid.UUID) (bool, error)
+	IsRedemptionOrder(ctx context.Context, orderID uuid.UUID) (bool, error)
+}
+
+// Ledger posts reward transactions
+type Ledger interface {
+	GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+	ReverseTransaction(ctx context.Context, originalTxID uuid.UUID, reason string) error
+}
+
+// BuyingPowerUpdater funds the buy orders redemptions place
+type BuyingPowerUpdater interface {
+	UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+}
+
+// Investor looks up baskets and places the buy orders redemptions make
+type Investor interface {
+	GetBasket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error)
+	CreateOrder(ctx context.Context, userID uuid.UUID, req *entities.OrderCreateRequest) (*entities.Order, error)
+}
+
+// TradingModes resolves whether a user trades live or on paper
+type TradingModes interface {
+	Mode(ctx context.Context, userID uuid.UUID) (entities.TradingMode, error)
+}
+
+// PaperBuyingPower funds the paper buy orders paper-mode redemptions place
+type PaperBuyingPower interface {
+	AddBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+	DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+}
+
+// RiskScorer is the risk engine's fraud score for a user and amount
+type RiskScorer interface {
+	CalculateFraudScore(ctx context.Context, userID uuid.UUID, amount decimal.Decimal, txType string) (decimal.Decimal, map[string]interface{})
+}
+
+// Config sets the limits rewards are earned and redeemed under
+type Config struct {
+	PointsPerDollar int
+	MonthlyCap      decimal.Decimal // Most one user earns per calendar month
+	RiskThreshold   decimal.Decimal // Fraud score at which rewards are held for review
+	MinRedemption   decimal.Decimal
+}
+
+// DefaultConfig shows 100 points per dollar, caps earnings at $100 a month,
+// holds rewards scoring 0.5 or more and redeems from $1
+func DefaultConfig() Config {
+	return Config{
+		PointsPerDollar: 100,
+		MonthlyCap:      decimal.NewFromInt(100),
+		RiskThreshold:   decimal.NewFromFloat(0.5),
+		MinRedemption:   decimal.NewFromInt(1),
+	}
+}
+
+// Service runs the rewards program. Filled buy orders earn a share back under
+// the running rules for their trigger; the reward is funded from the rewards
+// budget into the user's rewards_balance ledger account, apart from their
+// cash. It can be redeemed once its rule's hold period passes, which moves it
+// to the user's balance and places a buy order in the basket they pick.
+//
+// Against gaming: rewards are capped per order and per month, orders below a
+// rule's minimum and orders paid for with redeemed rewards earn nothing, a
+// sell of the basket during the hold period forfeits what the basket earned,
+// and rewards the risk engine scores at or above the threshold are held until
+// an admin reviews them.
+type Service struct {
+	repo        Repository
+	ledger      Ledger
+	buyingPower BuyingPowerUpdater
+	investor    Investor
+	risk        RiskScorer
+	modes       TradingModes
+	paper       Investor
+	paperFunds  PaperBuyingPower
+	config      Config
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewService creates a new rewards service
+func NewService(repo Repository, ledger Ledger, buyingPower BuyingPowerUpdater, investor Investor, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.PointsPerDollar <= 0 {
+		config.PointsPerDollar = defaults.PointsPerDollar
+	}
+	if !config.MonthlyCap.IsPositive() {
+		config.MonthlyCap = defaults.MonthlyCap
+	}
+	if !config.RiskThreshold.IsPositive() {
+		config.RiskThreshold = defaults.RiskThreshold
+	}
+	if !config.MinRedemption.IsPositive() {
+		config.MinRedemption = defaults.MinRedemption
+	}
+	return &Service{
+		repo:        repo,
+		ledger:      ledger,
+		buyingPower: buyingPower,
+		investor:    investor,
+		config:      config,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetRiskScorer holds rewards the risk engine scores at or above the
+// configured threshold for review
+func (s *Service) SetRiskScorer(risk RiskScorer) {
+	s.risk = risk
+}
+
+// SetPaperTrading simulates the redemptions of users in paper mode on the
+// paper investing service, leaving their real rewards untouched
+func (s *Service) SetPaperTrading(modes TradingModes, paper Investor, paperFunds PaperBuyingPower) {
+	s.modes = modes
+	s.paper = paper
+	s.paperFunds = paperFunds
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// CreateRule defines a new earning rule
+func (s *Service) CreateRule(ctx context.Context, req *entities.CreateRewardRuleRequest, createdBy uuid.UUID) (*entities.RewardRule, error) {
+	if !req.Rate.IsPositive() || req.Rate.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, fmt.Errorf("%w: rate must be above 0 and at most 1", ErrInvalidRewardRule)
+	}
+	if req.MinOrderAmount.IsNegative() {
+		return nil, fmt.Errorf("%w: min_order_amount cannot be negative", ErrInvalidRewardRule)
+	}
+	if req.MaxPerOrder != nil && !req.MaxPerOrder.IsPositive() {
+		return nil, fmt.Errorf("%w: max_per_order must be positive", ErrInvalidRewardRule)
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidRewardRule)
+	}
+
+	now := s.now()
+	rule := &entities.RewardRule{
+		ID:             uuid.New(),
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Trigger:        req.Trigger,
+		Unit:           req.Unit,
+		Rate:           req.Rate,
+		MinOrderAmount: req.MinOrderAmount,
+		MaxPerOrder:    req.MaxPerOrder,
+		HoldDays:       req.HoldDays,
+		Active:         true,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		CreatedBy:      &createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Reward rule created",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("trigger", string(rule.Trigger)),
+		zap.String("unit", string(rule.Unit)),
+		zap.String("rate", rule.Rate.String()))
+	return rule, nil
+}
+
+// ListRules returns every earning rule
+func (s *Service) ListRules(ctx context.Context) ([]*entities.RewardRule, error) {
+	return s.repo.ListRules(ctx)
+}
+
+// SetRuleActive pauses or resumes a rule. Rewards already earned are kept.
+func (s *Service) SetRuleActive(ctx context.Context, id uuid.UUID, active bool) (*entities.RewardRule, error) {
+	return s.repo.SetRuleActive(ctx, id, active)
+}
+
+// HandleOrderFilled earns rewards on a filled buy order, and forfeits the
+// basket's rewards still in their hold period when a sell fills. Paper orders
+// are ignored.
+func (s *Service) HandleOrderFilled(ctx context.Context, order *entities.Order) error {
+	if order.Paper {
+		return nil
+	}
+	if order.Side == entities.OrderSideSell {
+		return s.clawBackBasket(ctx, order)
+	}
+
+	redemption, err := s.repo.IsRedemptionOrder(ctx, order.ID)
+	if err != nil || redemption {
+		return err
+	}
+	rules, err := s.repo.ListRunning(ctx, entities.RewardTriggerInvestment, s.now())
+	if err != nil {
+		return err
+	}
+	recurring, err := s.repo.IsSweepOrder(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if recurring {
+		recurringRules, err := s.repo.ListRunning(ctx, entities.RewardTriggerRecurringInvestment, s.now())
+		if err != nil {
+			return err
+		}
+		rules = append(rules, recurringRules...)
+	}
+
+	var firstErr error
+	for _, rule := range rules {
+		_, err := s.earn(ctx, rule, order)
+		if err == nil || errors.Is(err, entities.ErrRewardAlreadyEarned) || errors.Is(err, entities.ErrRewardCapReached) {
+			continue
+		}
+		s.logger.Error("Failed to earn reward",
+			zap.String("rule_id", rule.ID.String()),
+			zap.String("order_id", order.ID.String()),
+			zap.Error(err))
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Summary returns a user's rewards balance and recent activity
+func (s *Service) Summary(ctx context.Context, userID uuid.UUID) (*entities.RewardSummary, error) {
+	balance, err := s.repo.Balance(ctx, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	rewards, err := s.repo.ListByUser(ctx, userID, summaryRewards)
+	if err != nil {
+		return nil, err
+	}
+	redemptions, err := s.repo.ListRedemptions(ctx, userID, summaryRedemptions)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &entities.RewardSummary{RewardBalance: *balance, Rewards: rewards, Redemptions: redemptions}
+	if summary.Rewards == nil {
+		summary.Rewards = []*entities.Reward{}
+	}
+	if summary.Redemptions == nil {
+		summary.Redemptions = []*entities.RewardRedemption{}
+	}
+	return summary, nil
+}
+
+// Redeem invests available rewards in a basket. The rewards move to the
+// user's balance and buying power and a buy order is placed for the amount;
+// if the order cannot be placed the move is reversed and the redemption is
+// marked failed.
+func (s *Service) Redeem(ctx context.Context, userID uuid.UUID, req *entities.RedeemRewardsRequest) (*entities.RewardRedemption, error) {
+	if req.Amount.LessThan(s.config.MinRedemption) {
+		return nil, fmt.Errorf("%w: amount must be at least %s", ErrInvalidRedemption, s.config.MinRedemption.StringFixed(2))
+	}
+	if !req.Amount.Equal(req.Amount.Round(2)) {
+		return nil, fmt.Errorf("%w: amount has more than two decimal places", ErrInvalidRedemption)
+	}
+	basket, err := s.investor.GetBasket(ctx, req.BasketID)
+	if err != nil && !errors.Is(err, investing.ErrBasketNotFound) {
+		return nil, err
+	}
+	if basket == nil {
+		return nil, fmt.Errorf("%w: basket not found", ErrInvalidRedemption)
+	}
+
+	now := s.now()
+	redemption := &entities.RewardRedemption{
+		ID:        uuid.New(),
+		UserID:    userID,
+		BasketID:  basket.ID,
+		Amount:    req.Amount,
+		Status:    entities.RewardRedemptionPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if s.modes != nil {
+		mode, err := s.modes.Mode(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve trading mode: %w", err)
+		}
+		if mode == entities.TradingModePaper {
+			return s.redeemPaper(ctx, redemption, basket)
+		}
+	}
+	if err := s.repo.CreateRedemption(ctx, redemption); err != nil {
+		return nil, err
+	}
+
+	txID, err := s.post(ctx, userID, redemption.ID, "reward_redemption", entities.TransactionTypeReward,
+		fmt.Sprintf("reward-redeem-%s", redemption.ID), fmt.Sprintf("Rewards invested in %s", basket.Name),
+		map[string]any{"redemption_id": redemption.ID.String(), "basket_id": basket.ID.String()},
+		entities.AccountTypeUSDCBalance, redemption.Amount, ledger.CreateRewardRedeemEntries)
+	if err != nil {
+		return nil, s.failRedemption(ctx, redemption, nil, false, err)
+	}
+	redemption.LedgerTxID = &txID
+	if err := s.buyingPower.UpdateBuyingPower(ctx, userID, redemption.Amount); err != nil {
+		return nil, s.failRedemption(ctx, redemption, &txID, false, err)
+	}
+
+	order, err := s.investor.CreateOrder(ctx, userID, &entities.OrderCreateRequest{
+		BasketID: basket.ID,
+		Side:     entities.OrderSideBuy,
+		Amount:   redemption.Amount.StringFixed(2),
+	})
+	if err != nil {
+		return nil, s.failRedemption(ctx, redemption, &txID, true, err)
+	}
+
+	redemption.Status = entities.RewardRedemptionCompleted
+	redemption.OrderID = &order.ID
+	redemption.UpdatedAt = s.now()
+	if err := s.repo.UpdateRedemption(ctx, redemption); err != nil {
+		// The order is placed; only the link to it is missing
+		s.logger.Error("Failed to record reward redemption order",
+			zap.String("redemption_id", redemption.ID.String()),
+			zap.String("order_id", order.ID.String()),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Rewards redeemed",
+		zap.String("redemption_id", redemption.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("basket_id", basket.ID.String()),
+		logger.Amount("amount", redemption.Amount),
+		zap.String("order_id", order.ID.String()))
+	return redemption, nil
+}
+
+// redeemPaper simulates a redemption for a user in paper mode: it checks their
+// available rewards, credits the amount to paper buying power and places the
+// buy on the paper investing service. Nothing is reserved, posted or
+// recorded, so the rewards stay available for live trading.
+func (s *Service) redeemPaper(ctx context.Context, redemption *entities.RewardRedemption, basket *entities.Basket) (*entities.RewardRedemption, error) {
+	balance, err := s.repo.Balance(ctx, redemption.UserID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if balance.Available.LessThan(redemption.Amount) {
+		return nil, entities.ErrInsufficientRewards
+	}
+	if err := s.paperFunds.AddBuyingPower(ctx, redemption.UserID, redemption.Amount); err != nil {
+		return nil, err
+	}
+
+	order, err := s.paper.CreateOrder(ctx, redemption.UserID, &entities.OrderCreateRequest{
+		BasketID: basket.ID,
+		Side:     entities.OrderSideBuy,
+		Amount:   redemption.Amount.StringFixed(2),
+	})
+	if err != nil {
+		if deductErr := s.paperFunds.DeductBuyingPower(ctx, redemption.UserID, redemption.Amount); deductErr != nil {
+			s.logger.Error("Failed to take back paper buying power for failed redemption",
+				zap.String("redemption_id", redemption.ID.String()),
+				zap.String("user_id", redemption.UserID.String()),
+				zap.Error(deductErr))
+		}
+		return nil, err
+	}
+
+	redemption.Status = entities.RewardRedemptionCompleted
+	redemption.OrderID = &order.ID
+	redemption.UpdatedAt = s.now()
+	s.logger.Info("Rewards redeemed on paper",
+		zap.String("redemption_id", redemption.ID.String()),
+		zap.String("user_id", redemption.UserID.String()),
+		zap.String("basket_id", basket.ID.String()),
+		logger.Amount("amount", redemption.Amount),
+		zap.String("order_id", order.ID.String()))
+	return redemption, nil
+}
+
+// ListHeld returns rewards held by the risk engine, oldest first
+func (s *Service) ListHeld(ctx context.Context, limit, offset int) ([]*entities.Reward, error) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListHeld(ctx, limit, offset)
+}
+
+// Review releases a held reward to the user or forfeits it to the budget. A
+// released reward keeps its hold period.
+func (s *Service) Review(ctx context.Context, id uuid.UUID, req *entities.ReviewRewardRequest, adminID uuid.UUID) (*entities.Reward, error) {
+	reward, err := s.repo.GetReward(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reward.Status != entities.RewardHeld {
+		return nil, entities.ErrRewardNotHeld
+	}
+
+	if !req.Approve {
+		if err := s.forfeit(ctx, reward, req.Reason, &adminID); err != nil {
+			return nil, err
+		}
+		return reward, nil
+	}
+
+	released := *reward
+	released.Status = entities.RewardAccrued
+	released.ReviewedBy = &adminID
+	released.UpdatedAt = s.now()
+	claimed, err := s.repo.UpdateRewardStatus(ctx, &released, entities.RewardHeld)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, entities.ErrRewardNotHeld
+	}
+	s.logger.Info("Held reward released",
+		zap.String("reward_id", reward.ID.String()),
+		zap.String("user_id", reward.UserID.String()),
+		zap.String("admin_id", adminID.String()))
+	return &released, nil
+}
+
+// earn accrues one rule's reward on an order, after the rule's minimum, the
+// per-order and monthly caps and the risk check
+func (s *Service) earn(ctx context.Context, rule *entities.RewardRule, order *entities.Order) (*entities.Reward, error) {
+	if order.Amount.LessThan(rule.MinOrderAmount) {
+		return nil, nil
+	}
+	amount := order.Amount.Mul(rule.Rate).RoundDown(2)
+	if rule.MaxPerOrder != nil && amount.GreaterThan(*rule.MaxPerOrder) {
+		amount = *rule.MaxPerOrder
+	}
+
+	now := s.now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	earned, err := s.repo.EarnedSince(ctx, order.UserID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+	if remaining := s.config.MonthlyCap.Sub(earned); amount.GreaterThan(remaining) {
+		amount = remaining
+	}
+	if !amount.IsPositive() {
+		return nil, entities.ErrRewardCapReached
+	}
+
+	reward := &entities.Reward{
+		ID:          uuid.New(),
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+		BasketID:    order.BasketID,
+		Unit:        rule.Unit,
+		Amount:      amount,
+		Status:      entities.RewardAccrued,
+		AvailableAt: now.AddDate(0, 0, rule.HoldDays),
+		RiskScore:   decimal.Zero,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if rule.Unit == entities.RewardUnitPoints {
+		points := amount.Mul(decimal.NewFromInt(int64(s.config.PointsPerDollar))).IntPart()
+		reward.Points = &points
+	}
+	if s.risk != nil {
+		score, factors := s.risk.CalculateFraudScore(ctx, order.UserID, amount, "reward")
+		reward.RiskScore = score
+		if score.GreaterThanOrEqual(s.config.RiskThreshold) {
+			reason := holdReason(score, factors)
+			reward.Status = entities.RewardHeld
+			reward.HoldReason = &reason
+		}
+	}
+	if err := s.repo.ClaimReward(ctx, reward, monthStart, s.config.MonthlyCap); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]any{
+		"reward_id": reward.ID.String(),
+		"rule_id":   rule.ID.String(),
+		"order_id":  order.ID.String(),
+		"unit":      string(rule.Unit),
+	}
+	if reward.Points != nil {
+		metadata["points"] = *reward.Points
+	}
+	txID, err := s.post(ctx, reward.UserID, reward.ID, "reward", entities.TransactionTypeReward,
+		fmt.Sprintf("reward-earn-%s", reward.ID), fmt.Sprintf("Reward earned: %s", rule.Name), metadata,
+		entities.AccountTypeSystemRewards, reward.Amount, ledger.CreateRewardEarnEntries)
+	if err != nil {
+		if releaseErr := s.repo.ReleaseReward(ctx, reward.ID); releaseErr != nil {
+			s.logger.Error("Failed to release reward", zap.String("reward_id", reward.ID.String()), zap.Error(releaseErr))
+		}
+		return nil, err
+	}
+	reward.LedgerTxID = &txID
+	if err := s.repo.SetRewardLedgerTx(ctx, reward.ID, txID); err != nil {
+		s.logger.Warn("Failed to record reward ledger transaction", zap.String("reward_id", reward.ID.String()), zap.Error(err))
+	}
+
+	s.logger.Info("Reward earned",
+		zap.String("reward_id", reward.ID.String()),
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("user_id", reward.UserID.String()),
+		zap.String("order_id", order.ID.String()),
+		logger.Amount("amount", reward.Amount),
+		zap.String("status", string(reward.Status)))
+	return reward, nil
+}
+
+// clawBackBasket forfeits the rewards a basket earned that are still held or
+// in their hold period when the user sells it
+func (s *Service) clawBackBasket(ctx context.Context, order *entities.Order) error {
+	rewards, err := s.repo.ListUnsettledForBasket(ctx, order.UserID, order.BasketID, s.now())
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, reward := range rewards {
+		if err := s.forfeit(ctx, reward, "Basket sold during the reward hold period", nil); err != nil {
+			s.logger.Error("Failed to forfeit reward", zap.String("reward_id", reward.ID.String()), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// forfeit returns an unredeemed reward to the rewards budget
+func (s *Service) forfeit(ctx context.Context, reward *entities.Reward, reason string, reviewedBy *uuid.UUID) error {
+	from := reward.Status
+	settled := *reward
+	now := s.now()
+	settled.Status = entities.RewardForfeited
+	settled.ForfeitReason = &reason
+	settled.ReviewedBy = reviewedBy
+	settled.SettledAt = &now
+	settled.UpdatedAt = now
+	claimed, err := s.repo.UpdateRewardStatus(ctx, &settled, from)
+	if err != nil || !claimed {
+		return err
+	}
+
+	txID, err := s.post(ctx, reward.UserID, reward.ID, "reward", entities.TransactionTypeRewardClawback,
+		fmt.Sprintf("reward-clawback-%s", reward.ID), fmt.Sprintf("Reward forfeited: %s", reward.RuleName),
+		map[string]any{"reward_id": reward.ID.String(), "reason": reason},
+		entities.AccountTypeSystemRewards, reward.Amount, ledger.CreateRewardClawbackEntries)
+	if err != nil {
+		// The ledger posting is idempotent on the reward, so a retry is safe
+		revert := *reward
+		revert.UpdatedAt = s.now()
+		if _, revertErr := s.repo.UpdateRewardStatus(ctx, &revert, entities.RewardForfeited); revertErr != nil {
+			s.logger.Error("Failed to revert reward forfeit", zap.String("reward_id", reward.ID.String()), zap.Error(revertErr))
+		}
+		return err
+	}
+	if err := s.repo.SetClawbackLedgerTx(ctx, reward.ID, txID); err != nil {
+		s.logger.Warn("Failed to record reward clawback ledger transaction", zap.String("reward_id", reward.ID.String()), zap.Error(err))
+	}
+
+	*reward = settled
+	reward.ClawbackLedgerTxID = &txID
+	s.logger.Info("Reward forfeited",
+		zap.String("reward_id", reward.ID.String()),
+		zap.String("user_id", reward.UserID.String()),
+		logger.Amount("amount", reward.Amount),
+		zap.String("reason", reason))
+	return nil
+}
+
+// failRedemption undoes what a redemption moved and marks it failed, then
+// returns cause
+func (s *Service) failRedemption(ctx context.Context, redemption *entities.RewardRedemption, ledgerTxID *uuid.UUID, fundedBuyingPower bool, cause error) error {
+	if fundedBuyingPower {
+		if err := s.buyingPower.UpdateBuyingPower(ctx, redemption.UserID, redemption.Amount.Neg()); err != nil {
+			s.logger.Error("Failed to take back redemption buying power",
+				zap.String("redemption_id", redemption.ID.String()), zap.Error(err))
+		}
+	}
+	if ledgerTxID != nil {
+		if err := s.ledger.ReverseTransaction(ctx, *ledgerTxID, "Reward redemption order failed"); err != nil {
+			s.logger.Error("Failed to reverse reward redemption",
+				zap.String("redemption_id", redemption.ID.String()), zap.Error(err))
+		}
+	}
+
+	reason := cause.Error()
+	redemption.Status = entities.RewardRedemptionFailed
+	redemption.FailureReason = &reason
+	redemption.UpdatedAt = s.now()
+	if err := s.repo.UpdateRedemption(ctx, redemption); err != nil {
+		s.logger.Error("Failed to mark reward redemption failed",
+			zap.String("redemption_id", redemption.ID.String()), zap.Error(err))
+	}
+	return cause
+}
+
+// post writes one ledger transaction between the user's rewards balance and
+// counterparty. The idempotency key makes a retry return the original.
+func (s *Service) post(
+	ctx context.Context,
+	userID, referenceID uuid.UUID,
+	referenceType string,
+	txType entities.TransactionType,
+	idempotencyKey, description string,
+	metadata map[string]any,
+	counterparty entities.AccountType,
+	amount decimal.Decimal,
+	entries func(rewardsBalanceID, counterpartyID uuid.UUID, amount decimal.Decimal) []entities.CreateEntryRequest,
+) (uuid.UUID, error) {
+	rewardsBalance, err := s.ledger.GetOrCreateUserAccount(ctx, userID, entities.AccountTypeRewardsBalance)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get rewards balance account: %w", err)
+	}
+	var other *entities.LedgerAccount
+	if counterparty.IsSystemAccountType() {
+		other, err = s.ledger.GetSystemAccount(ctx, counterparty)
+	} else {
+		other, err = s.ledger.GetOrCreateUserAccount(ctx, userID, counterparty)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get %s account: %w", counterparty, err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithUser(userID).
+		WithType(txType).
+		WithReference(referenceID, referenceType).
+		WithIdempotencyKey(idempotencyKey).
+		WithDescription(description).
+		WithMetadata(metadata).
+		WithEntries(entries(rewardsBalance.ID, other.ID, amount)).
+		Build()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to build reward ledger transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to post reward ledger transaction: %w", err)
+	}
+	return tx.ID, nil
+}
+
+// holdReason describes why the risk engine held a reward
+func holdReason(score decimal.Decimal, factors map[string]interface{}) string {
+	var flagged []string
+	for name, value := range factors {
+		if set, ok := value.(bool); ok && set {
+			flagged = append(flagged, name)
+		}
+	}
+	sort.Strings(flagged)
+	if len(flagged) == 0 {
+		return fmt.Sprintf("risk score %s", score.String())
+	}
+	return fmt.Sprintf("risk score %s: %s", score.String(), strings.Join(flagged, ", "))
+}
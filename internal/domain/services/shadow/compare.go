@@ -0,0 +1,118 @@
+package shadow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// maxDiffPaths caps how many differing paths one comparison reports
+const maxDiffPaths = 10
+
+// Compare returns the paths at which two responses differ, or nil when they
+// match. JSON bodies are compared structurally, skipping any object key in
+// ignore at any depth, so key order, whitespace and volatile fields such as
+// request IDs and timestamps do not count. Other bodies must be identical.
+// Only paths are reported so divergence logs never carry response values.
+func Compare(liveStatus int, liveBody []byte, candidateStatus int, candidateBody []byte, ignore map[string]bool) []string {
+	var diffs []string
+	if liveStatus != candidateStatus {
+		diffs = append(diffs, "status")
+	}
+
+	live, liveErr := decode(liveBody)
+	candidate, candidateErr := decode(candidateBody)
+	if liveErr != nil || candidateErr != nil {
+		if !bytes.Equal(bytes.TrimSpace(liveBody), bytes.TrimSpace(candidateBody)) {
+			diffs = append(diffs, "body")
+		}
+		return diffs
+	}
+	return diffValues("body", live, candidate, ignore, diffs)
+}
+
+func decode(body []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diffValues(path string, live, candidate interface{}, ignore map[string]bool, diffs []string) []string {
+	if len(diffs) >= maxDiffPaths {
+		return diffs
+	}
+	switch l := live.(type) {
+	case map[string]interface{}:
+		c, ok := candidate.(map[string]interface{})
+		if !ok {
+			return append(diffs, path)
+		}
+		keys := make(map[string]bool, len(l)+len(c))
+		for key := range l {
+			keys[key] = true
+		}
+		for key := range c {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			if !ignore[key] {
+				sorted = append(sorted, key)
+			}
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			lv, lok := l[key]
+			cv, cok := c[key]
+			if lok != cok {
+				diffs = append(diffs, path+"."+key)
+			} else {
+				diffs = diffValues(path+"."+key, lv, cv, ignore, diffs)
+			}
+			if len(diffs) >= maxDiffPaths {
+				return diffs[:maxDiffPaths]
+			}
+		}
+		return diffs
+	case []interface{}:
+		c, ok := candidate.([]interface{})
+		if !ok || len(l) != len(c) {
+			return append(diffs, path)
+		}
+		for i := range l {
+			diffs = diffValues(fmt.Sprintf("%s[%d]", path, i), l[i], c[i], ignore, diffs)
+			if len(diffs) >= maxDiffPaths {
+				return diffs[:maxDiffPaths]
+			}
+		}
+		return diffs
+	case json.Number:
+		// 1.50 and 1.5 are the same amount
+		c, ok := candidate.(json.Number)
+		if !ok {
+			return append(diffs, path)
+		}
+		if l.String() == c.String() {
+			return diffs
+		}
+		lf, lerr := l.Float64()
+		cf, cerr := c.Float64()
+		if lerr != nil || cerr != nil || lf != cf {
+			return append(diffs, path)
+		}
+		return diffs
+	default:
+		if live != candidate {
+			return append(diffs, path)
+		}
+		return diffs
+	}
+}
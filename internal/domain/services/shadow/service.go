@@ -0,0 +1,388 @@
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Header marks requests replayed against a candidate, so candidates and
+// anything they call can tell shadow traffic from real traffic
+const Header = "X-Shadow-Request"
+
+// Route shadows one registered API route to a candidate implementation,
+// usually the rewrite of the service behind it
+type Route struct {
+	Method        string
+	Path          string       // Route as registered with gin, e.g. /api/v1/funding/deposits/:id
+	Candidate     http.Handler // In-process handler or NewProxyCandidate for a separate deployment
+	SamplePercent float64      // Share of the route's requests shadowed, 0-100
+	// AllowWrites shadows POST, PUT, PATCH and DELETE requests too. Only set
+	// it for candidates that run in dry-run mode: the request is replayed in
+	// full, so a candidate with real side effects would repeat them.
+	AllowWrites  bool
+	IgnoreFields []string // JSON keys skipped when comparing, on top of Config.IgnoreFields
+}
+
+// Config controls how much traffic is shadowed and how candidates are run
+type Config struct {
+	Enabled      bool
+	MaxBodyBytes int           // Requests with larger bodies are not shadowed
+	QueueSize    int           // Exchanges waiting for a worker before new ones are dropped
+	Workers      int           // Candidates run concurrently
+	Timeout      time.Duration // How long a candidate may take to respond
+	IgnoreFields []string      // JSON keys skipped on every route, e.g. request_id, timestamp
+}
+
+// DefaultConfig shadows nothing until routes are registered
+func DefaultConfig() Config {
+	return Config{
+		MaxBodyBytes: 256 * 1024,
+		QueueSize:    500,
+		Workers:      4,
+		Timeout:      10 * time.Second,
+		IgnoreFields: []string{"request_id", "timestamp"},
+	}
+}
+
+// Exchange is a live request and the response the user was given, queued
+// for replay against the route's candidate
+type Exchange struct {
+	RequestID string
+	Request   *http.Request // A clone with the full body, detached from the live request's context
+	Status    int
+	Body      []byte
+	Truncated bool // Only part of the live body was kept, so only statuses are compared
+}
+
+type route struct {
+	Route
+	ignore map[string]bool
+
+	stats entities.ShadowRouteStats
+}
+
+type job struct {
+	method, path string
+	exchange     *Exchange
+}
+
+// Service replays sampled requests against candidate implementations after
+// the live handler has responded, and logs where the candidate's response
+// differs. Candidates run on a bounded worker pool from their own queue, so a
+// slow, failing or panicking candidate never delays or alters what the user
+// gets; when the queue is full exchanges are dropped and counted.
+type Service struct {
+	config Config
+	logger *zap.Logger
+	queue  chan job
+
+	mu     sync.Mutex
+	routes map[string]*route // method + " " + path
+	sample func() float64
+}
+
+// NewService creates a shadowing service
+func NewService(config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaults.MaxBodyBytes
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.IgnoreFields == nil {
+		config.IgnoreFields = defaults.IgnoreFields
+	}
+	return &Service{
+		config: config,
+		logger: logger,
+		queue:  make(chan job, config.QueueSize),
+		routes: make(map[string]*route),
+		sample: rand.Float64,
+	}
+}
+
+// SetSampler overrides the random source used for sampling, for tests
+func (s *Service) SetSampler(sample func() float64) {
+	s.sample = sample
+}
+
+// NewProxyCandidate forwards shadowed requests to a separately deployed
+// candidate at target, keeping their path and query
+func NewProxyCandidate(target string) (http.Handler, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid shadow target %q", target)
+	}
+	return httputil.NewSingleHostReverseProxy(parsed), nil
+}
+
+// Register shadows a route, replacing any candidate already registered for it
+func (s *Service) Register(r Route) error {
+	r.Method = strings.ToUpper(r.Method)
+	if r.Method == "" || !strings.HasPrefix(r.Path, "/") {
+		return fmt.Errorf("shadow route needs a method and a path, got %q %q", r.Method, r.Path)
+	}
+	if r.Candidate == nil {
+		return fmt.Errorf("shadow route %s %s has no candidate", r.Method, r.Path)
+	}
+	if r.SamplePercent < 0 || r.SamplePercent > 100 {
+		return fmt.Errorf("shadow route %s %s: sample percent must be 0-100", r.Method, r.Path)
+	}
+	if !safeMethod(r.Method) && !r.AllowWrites {
+		return fmt.Errorf("shadow route %s %s changes state; set AllowWrites only for a dry-run candidate", r.Method, r.Path)
+	}
+
+	ignore := make(map[string]bool, len(s.config.IgnoreFields)+len(r.IgnoreFields))
+	for _, field := range s.config.IgnoreFields {
+		ignore[field] = true
+	}
+	for _, field := range r.IgnoreFields {
+		ignore[field] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[r.Method+" "+r.Path] = &route{
+		Route:  r,
+		ignore: ignore,
+		stats: entities.ShadowRouteStats{
+			Method:        r.Method,
+			Route:         r.Path,
+			SamplePercent: r.SamplePercent,
+			AllowWrites:   r.AllowWrites,
+		},
+	}
+	s.logger.Info("Shadowing route",
+		zap.String("method", r.Method),
+		zap.String("route", r.Path),
+		zap.Float64("sample_percent", r.SamplePercent))
+	return nil
+}
+
+// Deregister stops shadowing a route
+func (s *Service) Deregister(method, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.routes, strings.ToUpper(method)+" "+path)
+}
+
+// Shadowing reports whether a request to the route should be replayed. The
+// middleware only buffers bodies for requests it picks.
+func (s *Service) Shadowing(method, path string) bool {
+	if !s.config.Enabled || path == "" {
+		return false
+	}
+	s.mu.Lock()
+	r, ok := s.routes[method+" "+path]
+	s.mu.Unlock()
+	return ok && r.SamplePercent > 0 && s.sample()*100 < r.SamplePercent
+}
+
+// MaxBodyBytes is the largest request body the middleware may buffer
+func (s *Service) MaxBodyBytes() int {
+	return s.config.MaxBodyBytes
+}
+
+// Submit queues an exchange for replay without blocking the request
+func (s *Service) Submit(method, path string, exchange *Exchange) {
+	select {
+	case s.queue <- job{method: method, path: path, exchange: exchange}:
+	default:
+		s.mu.Lock()
+		if r, ok := s.routes[method+" "+path]; ok {
+			r.stats.Dropped++
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Start runs the candidate workers until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	for i := 0; i < s.config.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-s.queue:
+					s.Replay(ctx, j.method, j.path, j.exchange)
+				}
+			}
+		}()
+	}
+	s.logger.Info("Shadow workers started", zap.Int("workers", s.config.Workers))
+}
+
+// Replay runs an exchange's request against the route's candidate and
+// records whether it matched. Workers call it for queued exchanges.
+func (s *Service) Replay(ctx context.Context, method, path string, exchange *Exchange) {
+	s.mu.Lock()
+	r, ok := s.routes[method+" "+path]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	status, body, err := s.run(ctx, r.Candidate, exchange.Request)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r.stats.Shadowed++
+	if err != nil {
+		r.stats.Failed++
+		s.logger.Warn("Shadow candidate failed",
+			zap.String("method", method),
+			zap.String("route", path),
+			zap.String("request_id", exchange.RequestID),
+			zap.Error(err))
+		return
+	}
+
+	var diffs []string
+	if exchange.Truncated {
+		if status != exchange.Status {
+			diffs = []string{"status"}
+		}
+	} else {
+		diffs = Compare(exchange.Status, exchange.Body, status, body, r.ignore)
+	}
+	if len(diffs) == 0 {
+		r.stats.Matched++
+		return
+	}
+
+	now := time.Now()
+	r.stats.Diverged++
+	r.stats.LastDiverged = &now
+	r.stats.LastDiffPaths = diffs
+	s.logger.Warn("Shadow response diverged",
+		zap.String("method", method),
+		zap.String("route", path),
+		zap.String("request_id", exchange.RequestID),
+		zap.Int("live_status", exchange.Status),
+		zap.Int("candidate_status", status),
+		zap.Strings("paths", diffs))
+}
+
+// Stats returns the counters of every shadowed route
+func (s *Service) Stats() []entities.ShadowRouteStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]entities.ShadowRouteStats, 0, len(s.routes))
+	for _, r := range s.routes {
+		stat := r.stats
+		stat.LastDiffPaths = append([]string(nil), r.stats.LastDiffPaths...)
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Route != stats[j].Route {
+			return stats[i].Route < stats[j].Route
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
+
+// run calls the candidate with a timeout, recovering from panics. A handler
+// that outlives the timeout keeps running but its response is discarded.
+func (s *Service) run(ctx context.Context, candidate http.Handler, req *http.Request) (status int, body []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	recorder := newRecorder(s.config.MaxBodyBytes)
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("candidate panicked: %v", p)
+			}
+		}()
+		candidate.ServeHTTP(recorder, req.WithContext(ctx))
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return 0, nil, err
+		}
+		status, body := recorder.result()
+		return status, body, nil
+	case <-ctx.Done():
+		return 0, nil, fmt.Errorf("candidate did not respond: %w", ctx.Err())
+	}
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// recorder keeps a candidate's status and up to limit bytes of its body
+type recorder struct {
+	mu     sync.Mutex
+	header http.Header
+	status int
+	body   []byte
+	limit  int
+}
+
+func newRecorder(limit int) *recorder {
+	return &recorder{header: http.Header{}, limit: limit}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if room := r.limit - len(r.body); room > 0 {
+		if len(data) > room {
+			r.body = append(r.body, data[:room]...)
+		} else {
+			r.body = append(r.body, data...)
+		}
+	}
+	return len(data), nil
+}
+
+func (r *recorder) result() (int, []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return status, append([]byte(nil), r.body...)
+}
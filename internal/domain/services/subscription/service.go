@@ -0,0 +1,798 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository persists plans, subscriptions and invoices
+type Repository interface {
+	GetPlanByCode(ctx context.Context, code string) (*entities.SubscriptionPlan, error)
+	GetPlan(ctx context.Context, id uuid.UUID) (*entities.SubscriptionPlan, error)
+	ListPlans(ctx context.Context) ([]*entities.SubscriptionPlan, error)
+	// CreateSubscription returns ErrAlreadySubscribed if the user has a live one
+	CreateSubscription(ctx context.Context, sub *entities.Subscription) error
+	// GetLiveByUser returns the user's active or past due subscription
+	GetLiveByUser(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *entities.Subscription) error
+	// ListDueForRenewal returns active subscriptions whose period has ended
+	ListDueForRenewal(ctx context.Context, now time.Time, limit int) ([]*entities.Subscription, error)
+	// CreateInvoice inserts an invoice and sets its number
+	CreateInvoice(ctx context.Context, invoice *entities.Invoice) error
+	UpdateInvoice(ctx context.Context, invoice *entities.Invoice) error
+	GetInvoice(ctx context.Context, id uuid.UUID) (*entities.Invoice, error)
+	ListInvoicesByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Invoice, error)
+	// ListDunning returns open invoices whose next payment attempt is due
+	ListDunning(ctx context.Context, now time.Time, limit int) ([]*entities.Invoice, error)
+}
+
+// Ledger records fees paid from cash
+type Ledger interface {
+	GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+}
+
+// CashBalance debits fees from the user's buying power
+type CashBalance interface {
+	DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+	UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error
+}
+
+// CardCharger charges the user's card on file and returns the processor's
+// payment reference
+type CardCharger interface {
+	Charge(ctx context.Context, userID uuid.UUID, amount decimal.Decimal, currency, reference string) (string, error)
+}
+
+// Notifier delivers in-app notifications
+type Notifier interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// Config controls renewal and dunning
+type Config struct {
+	// DunningSchedule is the wait before each retry of a failed renewal. The
+	// subscription is canceled when the last retry fails.
+	DunningSchedule []time.Duration
+	Interval        time.Duration
+	BatchSize       int
+}
+
+// DefaultConfig retries failed renewals after 1, 3 and 7 days
+func DefaultConfig() Config {
+	return Config{
+		DunningSchedule: []time.Duration{24 * time.Hour, 72 * time.Hour, 168 * time.Hour},
+		Interval:        time.Hour,
+		BatchSize:       100,
+	}
+}
+
+// BillingReport summarizes a billing pass
+type BillingReport struct {
+	Renewed  int `json:"renewed"`
+	Canceled int `json:"canceled"`
+	Failed   int `json:"failed"`
+	Retried  int `json:"retried"`
+}
+
+// Service bills premium subscriptions and answers entitlement checks. Fees are
+// paid from the user's cash balance, recorded in the ledger as fee revenue, or
+// from a card on file when a card processor is configured.
+type Service struct {
+	repo     Repository
+	ledger   Ledger
+	cash     CashBalance
+	cards    CardCharger
+	notifier Notifier
+	config   Config
+	logger   *zap.Logger
+	mu       sync.Mutex
+	now      func() time.Time
+	tracker  *workerstatus.Tracker
+}
+
+// NewService creates a new subscription service
+func NewService(repo Repository, ledger Ledger, cash CashBalance, notifier Notifier, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if len(config.DunningSchedule) == 0 {
+		config.DunningSchedule = defaults.DunningSchedule
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	return &Service{
+		repo:     repo,
+		ledger:   ledger,
+		cash:     cash,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetCardCharger enables billing to a card on file
+func (s *Service) SetCardCharger(cards CardCharger) {
+	s.cards = cards
+}
+
+// ListPlans returns the plans users can subscribe to
+func (s *Service) ListPlans(ctx context.Context) ([]*entities.SubscriptionPlan, error) {
+	return s.repo.ListPlans(ctx)
+}
+
+// Overview returns the user's subscription, if any, and their entitlements
+func (s *Service) Overview(ctx context.Context, userID uuid.UUID) (*entities.SubscriptionOverview, error) {
+	overview := &entities.SubscriptionOverview{Entitlements: entities.FreeTierEntitlements()}
+
+	sub, err := s.repo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, entities.ErrSubscriptionNotFound) {
+			return overview, nil
+		}
+		return nil, err
+	}
+	plan, err := s.repo.GetPlan(ctx, sub.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview.Subscription = sub
+	overview.Plan = plan
+	if sub.GrantsEntitlements() {
+		for feature, limit := range plan.Entitlements {
+			overview.Entitlements[feature] = limit
+		}
+	}
+	return overview, nil
+}
+
+// Entitlements returns the feature limits that apply to the user
+func (s *Service) Entitlements(ctx context.Context, userID uuid.UUID) (entities.Entitlements, error) {
+	overview, err := s.Overview(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return overview.Entitlements, nil
+}
+
+// IsEntitled reports whether the user may use feature again given their usage
+// so far. Callers count their own usage; the free tier applies on error.
+func (s *Service) IsEntitled(ctx context.Context, userID uuid.UUID, feature string, used int) bool {
+	entitlements, err := s.Entitlements(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to load entitlements, applying free tier",
+			zap.String("user_id", userID.String()), zap.Error(err))
+		entitlements = entities.FreeTierEntitlements()
+	}
+	return entitlements.Allows(feature, used)
+}
+
+// Subscribe starts a plan, or changes the user's current plan with the
+// unused part of the current period prorated. Subscribing again to a plan
+// set to cancel resumes it.
+func (s *Service) Subscribe(ctx context.Context, userID uuid.UUID, req *entities.SubscribeRequest) (*entities.SubscriptionOverview, error) {
+	plan, err := s.repo.GetPlanByCode(ctx, req.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Active {
+		return nil, entities.ErrPlanNotFound
+	}
+	method := req.PaymentMethod
+	if method == "" {
+		method = entities.PaymentMethodCashBalance
+	}
+	if method == entities.PaymentMethodCard && s.cards == nil {
+		return nil, entities.ErrPaymentMethodUnavailable
+	}
+
+	current, err := s.repo.GetLiveByUser(ctx, userID)
+	switch {
+	case err == nil:
+		if err := s.changePlan(ctx, current, plan, method); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, entities.ErrSubscriptionNotFound):
+		if err := s.start(ctx, userID, plan, method); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	return s.Overview(ctx, userID)
+}
+
+// Cancel stops the subscription renewing. Entitlements last until the end of
+// the paid period.
+func (s *Service) Cancel(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error) {
+	sub, err := s.repo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == entities.SubscriptionPastDue {
+		// Nothing was paid for the current period, so it ends now
+		return sub, s.end(ctx, sub, "Subscription canceled with an unpaid invoice")
+	}
+
+	sub.CancelAtPeriodEnd = true
+	sub.UpdatedAt = s.now()
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Subscription set to cancel",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.Time("period_end", sub.CurrentPeriodEnd))
+	return sub, nil
+}
+
+// ListInvoices returns the user's invoices, newest first
+func (s *Service) ListInvoices(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Invoice, error) {
+	return s.repo.ListInvoicesByUser(ctx, userID, limit, offset)
+}
+
+// GetInvoice returns one of the user's invoices
+func (s *Service) GetInvoice(ctx context.Context, userID, id uuid.UUID) (*entities.Invoice, error) {
+	invoice, err := s.repo.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.UserID != userID {
+		return nil, entities.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// PayInvoice retries payment of an open invoice now instead of waiting for
+// the next dunning attempt. A failed attempt here does not advance dunning.
+func (s *Service) PayInvoice(ctx context.Context, userID, id uuid.UUID) (*entities.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invoice, err := s.GetInvoice(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != entities.InvoiceOpen {
+		return nil, entities.ErrInvoiceNotPayable
+	}
+	sub, err := s.repo.GetLiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.ID != invoice.SubscriptionID {
+		return nil, entities.ErrInvoiceNotPayable
+	}
+	if err := s.pay(ctx, sub, invoice); err != nil {
+		return nil, err
+	}
+	if err := s.recover(ctx, sub, invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Interval returns the time between billing passes
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// SetTracker reports billing passes to the worker registry so operators can
+// see and pause them
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Start runs a billing pass on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.RunBilling(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Subscription billing pass failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// RunBilling renews subscriptions whose period has ended and retries failed
+// payments that are due
+func (s *Service) RunBilling(ctx context.Context) (*BillingReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &BillingReport{}
+	now := s.now()
+
+	due, err := s.repo.ListDueForRenewal(ctx, now, s.config.BatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, sub := range due {
+		if sub.CancelAtPeriodEnd {
+			if err := s.end(ctx, sub, "Subscription ended at the end of the billing period"); err != nil {
+				s.logger.Error("Failed to end subscription", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+				continue
+			}
+			report.Canceled++
+			continue
+		}
+		paid, err := s.renew(ctx, sub)
+		if err != nil {
+			s.logger.Error("Failed to renew subscription", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+			continue
+		}
+		if paid {
+			report.Renewed++
+		} else {
+			report.Failed++
+		}
+	}
+
+	dunning, err := s.repo.ListDunning(ctx, now, s.config.BatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, invoice := range dunning {
+		sub, err := s.repo.GetLiveByUser(ctx, invoice.UserID)
+		if err != nil || sub.ID != invoice.SubscriptionID {
+			s.voidInvoice(ctx, invoice, "Subscription is no longer active")
+			continue
+		}
+		report.Retried++
+		if err := s.collect(ctx, sub, invoice); err != nil {
+			if invoice.Status == entities.InvoiceUncollectible {
+				report.Canceled++
+			}
+			continue
+		}
+		report.Renewed++
+	}
+
+	if report.Renewed+report.Canceled+report.Failed+report.Retried > 0 {
+		s.logger.Info("Subscription billing pass complete",
+			zap.Int("renewed", report.Renewed),
+			zap.Int("canceled", report.Canceled),
+			zap.Int("failed", report.Failed),
+			zap.Int("retried", report.Retried))
+	}
+	return report, nil
+}
+
+// start creates a subscription and charges its first period. A subscription
+// whose first payment fails is not kept.
+func (s *Service) start(ctx context.Context, userID uuid.UUID, plan *entities.SubscriptionPlan, method entities.PaymentMethod) error {
+	now := s.now()
+	sub := &entities.Subscription{
+		ID:                 uuid.New(),
+		UserID:             userID,
+		PlanID:             plan.ID,
+		PlanCode:           plan.Code,
+		Status:             entities.SubscriptionActive,
+		PaymentMethod:      method,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now.AddDate(0, plan.IntervalMonths, 0),
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	invoice := s.newInvoice(sub, []entities.InvoiceLineItem{
+		periodLine(plan, sub.CurrentPeriodStart, sub.CurrentPeriodEnd),
+	})
+	if err := s.repo.CreateInvoice(ctx, invoice); err != nil {
+		return err
+	}
+	if err := s.pay(ctx, sub, invoice); err != nil {
+		s.voidInvoice(ctx, invoice, err.Error())
+		sub.Status = entities.SubscriptionCanceled
+		sub.CanceledAt = &now
+		sub.UpdatedAt = now
+		if updateErr := s.repo.UpdateSubscription(ctx, sub); updateErr != nil {
+			s.logger.Error("Failed to cancel unpaid subscription", zap.String("subscription_id", sub.ID.String()), zap.Error(updateErr))
+		}
+		return err
+	}
+
+	s.logger.Info("Subscription started",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("plan", plan.Code))
+	return nil
+}
+
+// changePlan moves a subscription to another plan. The unused part of the
+// current plan is credited and the rest of the period on the new plan is
+// charged now; a net credit is carried to the next invoice.
+func (s *Service) changePlan(ctx context.Context, sub *entities.Subscription, plan *entities.SubscriptionPlan, method entities.PaymentMethod) error {
+	if sub.Status == entities.SubscriptionPastDue {
+		return entities.ErrSubscriptionPastDue
+	}
+	now := s.now()
+	if sub.PlanID == plan.ID {
+		if !sub.CancelAtPeriodEnd && sub.PaymentMethod == method {
+			return entities.ErrAlreadySubscribed
+		}
+		sub.CancelAtPeriodEnd = false
+		sub.PaymentMethod = method
+		sub.UpdatedAt = now
+		return s.repo.UpdateSubscription(ctx, sub)
+	}
+
+	current, err := s.repo.GetPlan(ctx, sub.PlanID)
+	if err != nil {
+		return err
+	}
+
+	remaining := Prorate(now, sub.CurrentPeriodStart, sub.CurrentPeriodEnd)
+	unused := current.Price.Mul(remaining).Round(2)
+	charge := plan.Price.Mul(remaining).Round(2)
+	periodStart := now
+	lines := []entities.InvoiceLineItem{
+		{
+			Description: fmt.Sprintf("Unused time on %s", current.Name),
+			Amount:      unused.Neg(),
+			PeriodStart: &periodStart,
+			PeriodEnd:   &sub.CurrentPeriodEnd,
+		},
+		{
+			Description: fmt.Sprintf("Remaining time on %s", plan.Name),
+			Amount:      charge,
+			PeriodStart: &periodStart,
+			PeriodEnd:   &sub.CurrentPeriodEnd,
+		},
+	}
+
+	previous := *sub
+	sub.PlanID = plan.ID
+	sub.PlanCode = plan.Code
+	sub.PaymentMethod = method
+	sub.CancelAtPeriodEnd = false
+	sub.UpdatedAt = now
+
+	invoice := s.newInvoice(sub, lines)
+	invoice.PeriodStart = now
+	if err := s.repo.CreateInvoice(ctx, invoice); err != nil {
+		return err
+	}
+	if err := s.pay(ctx, sub, invoice); err != nil {
+		s.voidInvoice(ctx, invoice, err.Error())
+		*sub = previous
+		return err
+	}
+
+	// A downgrade leaves credit for the next renewal
+	if net := charge.Sub(unused); net.IsNegative() {
+		sub.CreditBalance = sub.CreditBalance.Add(net.Neg())
+	}
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	s.logger.Info("Subscription plan changed",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("from_plan", current.Code),
+		zap.String("to_plan", plan.Code),
+		zap.String("charged", invoice.Total.String()))
+	return nil
+}
+
+// renew starts the next period and bills it. It reports whether the invoice
+// was paid; an unpaid renewal puts the subscription into dunning.
+func (s *Service) renew(ctx context.Context, sub *entities.Subscription) (bool, error) {
+	plan, err := s.repo.GetPlan(ctx, sub.PlanID)
+	if err != nil {
+		return false, err
+	}
+
+	sub.CurrentPeriodStart = sub.CurrentPeriodEnd
+	sub.CurrentPeriodEnd = sub.CurrentPeriodStart.AddDate(0, plan.IntervalMonths, 0)
+	sub.UpdatedAt = s.now()
+
+	invoice := s.newInvoice(sub, []entities.InvoiceLineItem{
+		periodLine(plan, sub.CurrentPeriodStart, sub.CurrentPeriodEnd),
+	})
+	if sub.CreditBalance.IsPositive() {
+		applied := decimal.Min(sub.CreditBalance, invoice.Subtotal)
+		invoice.CreditApplied = applied
+		invoice.Total = invoice.Subtotal.Sub(applied)
+		sub.CreditBalance = sub.CreditBalance.Sub(applied)
+	}
+	if err := s.repo.CreateInvoice(ctx, invoice); err != nil {
+		return false, err
+	}
+	// The period is advanced before payment so a failed charge is not billed
+	// again as a new renewal; dunning retries the invoice instead
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return false, err
+	}
+
+	if err := s.collect(ctx, sub, invoice); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// collect attempts payment of an open invoice and advances dunning on failure
+func (s *Service) collect(ctx context.Context, sub *entities.Subscription, invoice *entities.Invoice) error {
+	err := s.pay(ctx, sub, invoice)
+	if err == nil {
+		return s.recover(ctx, sub, invoice)
+	}
+
+	now := s.now()
+	reason := err.Error()
+	invoice.AttemptCount++
+	invoice.FailureReason = &reason
+	invoice.UpdatedAt = now
+
+	if invoice.AttemptCount > len(s.config.DunningSchedule) {
+		invoice.Status = entities.InvoiceUncollectible
+		invoice.NextAttemptAt = nil
+		if updateErr := s.repo.UpdateInvoice(ctx, invoice); updateErr != nil {
+			return updateErr
+		}
+		if endErr := s.end(ctx, sub, fmt.Sprintf("We could not collect payment for invoice %s", invoice.Number)); endErr != nil {
+			return endErr
+		}
+		return err
+	}
+
+	next := now.Add(s.config.DunningSchedule[invoice.AttemptCount-1])
+	invoice.NextAttemptAt = &next
+	if updateErr := s.repo.UpdateInvoice(ctx, invoice); updateErr != nil {
+		return updateErr
+	}
+	if sub.Status != entities.SubscriptionPastDue {
+		sub.Status = entities.SubscriptionPastDue
+		sub.UpdatedAt = now
+		if updateErr := s.repo.UpdateSubscription(ctx, sub); updateErr != nil {
+			return updateErr
+		}
+	}
+
+	s.logger.Warn("Subscription payment failed",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.Int("attempt", invoice.AttemptCount),
+		zap.Time("next_attempt_at", next),
+		zap.Error(err))
+	s.notify(ctx, sub.UserID, entities.PriorityHigh, "Subscription payment failed",
+		fmt.Sprintf("We could not collect %s %s for invoice %s. We'll try again on %s; add funds to keep your subscription.",
+			invoice.Total.StringFixed(2), invoice.Currency, invoice.Number, next.Format("January 2")))
+	return err
+}
+
+// recover returns a past due subscription to active once its invoice is paid
+func (s *Service) recover(ctx context.Context, sub *entities.Subscription, invoice *entities.Invoice) error {
+	if sub.Status != entities.SubscriptionPastDue {
+		return nil
+	}
+	sub.Status = entities.SubscriptionActive
+	sub.UpdatedAt = s.now()
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return err
+	}
+	s.notify(ctx, sub.UserID, entities.PriorityMedium, "Payment received",
+		fmt.Sprintf("Invoice %s is paid and your subscription is active again.", invoice.Number))
+	return nil
+}
+
+// pay charges an invoice's total and marks it paid
+func (s *Service) pay(ctx context.Context, sub *entities.Subscription, invoice *entities.Invoice) error {
+	now := s.now()
+	if invoice.Total.IsPositive() {
+		var err error
+		switch sub.PaymentMethod {
+		case entities.PaymentMethodCard:
+			err = s.chargeCard(ctx, invoice)
+		default:
+			err = s.chargeCash(ctx, invoice)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	invoice.Status = entities.InvoicePaid
+	invoice.PaidAt = &now
+	invoice.NextAttemptAt = nil
+	invoice.FailureReason = nil
+	invoice.UpdatedAt = now
+	if err := s.repo.UpdateInvoice(ctx, invoice); err != nil {
+		return err
+	}
+	s.logger.Info("Invoice paid",
+		zap.String("invoice", invoice.Number),
+		zap.String("user_id", invoice.UserID.String()),
+		zap.String("total", invoice.Total.String()))
+	return nil
+}
+
+// chargeCash deducts the total from buying power and records it as fee
+// revenue in the ledger, restoring buying power if the ledger rejects it
+func (s *Service) chargeCash(ctx context.Context, invoice *entities.Invoice) error {
+	if err := s.cash.DeductBuyingPower(ctx, invoice.UserID, invoice.Total); err != nil {
+		return fmt.Errorf("%w: %v", entities.ErrPaymentFailed, err)
+	}
+
+	txID, err := s.postFee(ctx, invoice)
+	if err != nil {
+		if restoreErr := s.cash.UpdateBuyingPower(ctx, invoice.UserID, invoice.Total); restoreErr != nil {
+			s.logger.Error("Failed to restore buying power after fee posting failed",
+				zap.String("invoice_id", invoice.ID.String()), zap.Error(restoreErr))
+		}
+		return fmt.Errorf("%w: %v", entities.ErrPaymentFailed, err)
+	}
+	invoice.LedgerTxID = &txID
+	return nil
+}
+
+func (s *Service) chargeCard(ctx context.Context, invoice *entities.Invoice) error {
+	if s.cards == nil {
+		return entities.ErrPaymentMethodUnavailable
+	}
+	ref, err := s.cards.Charge(ctx, invoice.UserID, invoice.Total, invoice.Currency, invoice.ID.String())
+	if err != nil {
+		return fmt.Errorf("%w: %v", entities.ErrPaymentFailed, err)
+	}
+	invoice.PaymentRef = &ref
+	return nil
+}
+
+func (s *Service) postFee(ctx context.Context, invoice *entities.Invoice) (uuid.UUID, error) {
+	usdc, err := s.ledger.GetOrCreateUserAccount(ctx, invoice.UserID, entities.AccountTypeUSDCBalance)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get user balance account: %w", err)
+	}
+	revenue, err := s.ledger.GetSystemAccount(ctx, entities.AccountTypeSystemFeeRevenue)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get fee revenue account: %w", err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithUser(invoice.UserID).
+		WithType(entities.TransactionTypeSubscriptionFee).
+		WithReference(invoice.ID, "invoice").
+		WithIdempotencyKey(fmt.Sprintf("invoice-%s-%d", invoice.ID, invoice.AttemptCount)).
+		WithDescription(fmt.Sprintf("Subscription invoice %s", invoice.Number)).
+		WithMetadata(map[string]any{
+			"invoice_id":      invoice.ID.String(),
+			"invoice_number":  invoice.Number,
+			"subscription_id": invoice.SubscriptionID.String(),
+		}).
+		WithEntries(ledger.CreateSubscriptionFeeEntries(usdc.ID, revenue.ID, invoice.Total)).
+		Build()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to build fee transaction: %w", err)
+	}
+
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to post fee transaction: %w", err)
+	}
+	return tx.ID, nil
+}
+
+// end cancels a subscription now
+func (s *Service) end(ctx context.Context, sub *entities.Subscription, reason string) error {
+	now := s.now()
+	sub.Status = entities.SubscriptionCanceled
+	sub.CanceledAt = &now
+	sub.UpdatedAt = now
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return err
+	}
+	s.logger.Info("Subscription canceled",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("user_id", sub.UserID.String()),
+		zap.String("reason", reason))
+	s.notify(ctx, sub.UserID, entities.PriorityMedium, "Your subscription has ended",
+		reason+". You're now on the free plan.")
+	return nil
+}
+
+func (s *Service) voidInvoice(ctx context.Context, invoice *entities.Invoice, reason string) {
+	invoice.Status = entities.InvoiceVoid
+	invoice.FailureReason = &reason
+	invoice.NextAttemptAt = nil
+	invoice.UpdatedAt = s.now()
+	if err := s.repo.UpdateInvoice(ctx, invoice); err != nil {
+		s.logger.Error("Failed to void invoice", zap.String("invoice_id", invoice.ID.String()), zap.Error(err))
+	}
+}
+
+func (s *Service) newInvoice(sub *entities.Subscription, lines []entities.InvoiceLineItem) *entities.Invoice {
+	subtotal := decimal.Zero
+	for _, line := range lines {
+		subtotal = subtotal.Add(line.Amount)
+	}
+	total := subtotal
+	if total.IsNegative() {
+		total = decimal.Zero
+	}
+	now := s.now()
+	return &entities.Invoice{
+		ID:             uuid.New(),
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		Status:         entities.InvoiceOpen,
+		Subtotal:       subtotal,
+		Total:          total,
+		Currency:       "USD",
+		PeriodStart:    sub.CurrentPeriodStart,
+		PeriodEnd:      sub.CurrentPeriodEnd,
+		LineItems:      lines,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func (s *Service) notify(ctx context.Context, userID uuid.UUID, priority entities.NotificationPriority, title, message string) {
+	if s.notifier == nil {
+		return
+	}
+	notification := &entities.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      entities.NotificationTypeBilling,
+		Channel:   entities.ChannelInApp,
+		Priority:  priority,
+		Title:     title,
+		Message:   message,
+		CreatedAt: s.now(),
+	}
+	if err := s.notifier.Send(ctx, notification, &entities.UserPreference{UserID: userID}); err != nil {
+		s.logger.Warn("Failed to send billing notification", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+func periodLine(plan *entities.SubscriptionPlan, start, end time.Time) entities.InvoiceLineItem {
+	return entities.InvoiceLineItem{
+		Description: plan.Name,
+		Amount:      plan.Price,
+		PeriodStart: &start,
+		PeriodEnd:   &end,
+	}
+}
+
+// Prorate returns the fraction of the period [start, end) remaining at now
+func Prorate(now, start, end time.Time) decimal.Decimal {
+	total := end.Sub(start)
+	if total <= 0 || !now.Before(end) {
+		return decimal.Zero
+	}
+	if now.Before(start) {
+		return decimal.NewFromInt(1)
+	}
+	return decimal.NewFromInt(int64(end.Sub(now))).Div(decimal.NewFromInt(int64(total)))
+}
@@ -0,0 +1,108 @@
+package suspense
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// Start runs an aging check on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				report, err := s.CheckAging(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Suspense aging check failed", zap.Error(err))
+					continue
+				}
+				s.alertAged(ctx, report)
+			}
+		}
+	}()
+}
+
+// CheckAging counts open items per source and those older than the aging
+// threshold, publishing both as gauges for alerting
+func (s *Service) CheckAging(ctx context.Context) (*entities.SuspenseAgingReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources, err := s.repo.Aging(ctx, s.now().Add(-s.config.AgedAfter))
+	if err != nil {
+		return nil, err
+	}
+	report := &entities.SuspenseAgingReport{
+		AgedAfterDays: int(s.config.AgedAfter.Hours() / 24),
+		Sources:       sources,
+	}
+
+	// Sources without open items are reported as zero, not left at their last value
+	for source := range sourceLedgers {
+		metrics.SuspenseItemsOpen.WithLabelValues(string(source)).Set(0)
+		metrics.SuspenseItemsAged.WithLabelValues(string(source)).Set(0)
+		metrics.SuspenseAgedUSD.WithLabelValues(string(source)).Set(0)
+	}
+	for _, aging := range sources {
+		source := string(aging.Source)
+		metrics.SuspenseItemsOpen.WithLabelValues(source).Set(float64(aging.Open))
+		metrics.SuspenseItemsAged.WithLabelValues(source).Set(float64(aging.Aged))
+		metrics.SuspenseAgedUSD.WithLabelValues(source).Set(aging.AgedUSDAmount.InexactFloat64())
+		if aging.Aged > 0 {
+			s.logger.Warn("Suspense items past the aging threshold",
+				zap.String("source", source),
+				zap.Int("aged", aging.Aged),
+				logger.Amount("aged_usd_amount", aging.AgedUSDAmount),
+				zap.Int("aged_after_days", report.AgedAfterDays))
+		}
+	}
+	return report, nil
+}
+
+// alertAged raises a warning per source with aged items. The key carries the
+// day, so an unworked backlog is raised again daily rather than every check.
+func (s *Service) alertAged(ctx context.Context, report *entities.SuspenseAgingReport) {
+	if s.alerter == nil {
+		return
+	}
+	day := s.now().UTC().Format("2006-01-02")
+	for _, aging := range report.Sources {
+		if aging.Aged == 0 {
+			continue
+		}
+		alert := &entities.Alert{
+			Source:   "suspense",
+			Severity: entities.AlertSeverityWarning,
+			DedupKey: fmt.Sprintf("suspense_aging:%s:%s", aging.Source, day),
+			Summary: fmt.Sprintf("%d %s suspense items open for more than %d days",
+				aging.Aged, aging.Source, report.AgedAfterDays),
+			Details: map[string]string{
+				"source":          string(aging.Source),
+				"open":            strconv.Itoa(aging.Open),
+				"aged":            strconv.Itoa(aging.Aged),
+				"aged_usd_amount": aging.AgedUSDAmount.String(),
+			},
+		}
+		if _, err := s.alerter.Notify(ctx, alert); err != nil {
+			s.logger.Warn("Failed to route suspense aging alert", zap.String("source", string(aging.Source)), zap.Error(err))
+		}
+	}
+}
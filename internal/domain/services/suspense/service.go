@@ -0,0 +1,354 @@
+package suspense
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository persists suspense items
+type Repository interface {
+	// Create inserts an item unless one exists for the same source transfer,
+	// in which case that one is returned with created=false
+	Create(ctx context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, bool, error)
+	Get(ctx context.Context, id uuid.UUID) (*entities.SuspenseItem, error)
+	List(ctx context.Context, status entities.SuspenseStatus, limit, offset int) ([]*entities.SuspenseItem, error)
+	SetHoldTransaction(ctx context.Context, id, ledgerTxID uuid.UUID) error
+	Claim(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	Reopen(ctx context.Context, id uuid.UUID, at time.Time) error
+	Resolve(ctx context.Context, item *entities.SuspenseItem) error
+	Aging(ctx context.Context, agedBefore time.Time) ([]entities.SuspenseAging, error)
+}
+
+// Ledger posts transfers into and out of the suspense account
+type Ledger interface {
+	GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error)
+	CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error)
+}
+
+// Source settles the items of one rail: it credits the assigned user or
+// returns the transfer, and knows which users an item might belong to
+type Source interface {
+	SuspenseSuggestions(ctx context.Context, item *entities.SuspenseItem) ([]entities.SuspenseSuggestion, error)
+	AssignSuspended(ctx context.Context, item *entities.SuspenseItem, userID, adminID uuid.UUID, note string) error
+	RefundSuspended(ctx context.Context, item *entities.SuspenseItem, adminID uuid.UUID, note string) error
+}
+
+// Alerter routes alerts to on-call channels
+type Alerter interface {
+	Notify(ctx context.Context, alert *entities.Alert) ([]*entities.AlertDelivery, error)
+}
+
+// Config controls the aging check
+type Config struct {
+	Interval  time.Duration // Time between aging checks
+	AgedAfter time.Duration // Open items older than this are reported as aged
+}
+
+// sourceLedger is the buffer and currency a source's transfers are booked in
+type sourceLedger struct {
+	buffer   entities.AccountType
+	currency string
+	amount   func(item *entities.SuspenseItem) decimal.Decimal
+}
+
+var sourceLedgers = map[entities.SuspenseSource]sourceLedger{
+	entities.SuspenseSourceBankCredit: {
+		buffer:   entities.AccountTypeSystemBufferFiat,
+		currency: "USD",
+		amount:   func(item *entities.SuspenseItem) decimal.Decimal { return item.USDAmount },
+	},
+	entities.SuspenseSourceChainDeposit: {
+		buffer:   entities.AccountTypeSystemBufferUSDC,
+		currency: "USDC",
+		amount:   func(item *entities.SuspenseItem) decimal.Decimal { return item.Amount },
+	},
+}
+
+// Service is the suspense workflow. Funds that arrive but cannot be
+// attributed to a user are booked into the suspense ledger account and
+// queued for an admin, who assigns them to a user or refunds them. The rail
+// the funds came on does the crediting or returning through its Source.
+type Service struct {
+	repo    Repository
+	ledger  Ledger
+	sources map[entities.SuspenseSource]Source
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+	alerter Alerter
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// NewService creates the suspense service. ledger may be nil, in which case
+// items are queued without ledger postings.
+func NewService(repo Repository, ledger Ledger, config Config, logger *zap.Logger) *Service {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.AgedAfter <= 0 {
+		config.AgedAfter = 7 * 24 * time.Hour
+	}
+	return &Service{
+		repo:    repo,
+		ledger:  ledger,
+		sources: make(map[entities.SuspenseSource]Source),
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// SetAlerter raises a warning for each source with aged items after the
+// periodic aging check
+func (s *Service) SetAlerter(alerter Alerter) {
+	s.alerter = alerter
+}
+
+// RegisterSource sets the handler of a source's items
+func (s *Service) RegisterSource(source entities.SuspenseSource, handler Source) {
+	s.sources[source] = handler
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports aging checks to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns the time between aging checks
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Hold books an unattributed transfer into suspense and queues it. Holding a
+// transfer that is already held returns the existing item.
+func (s *Service) Hold(ctx context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, error) {
+	now := s.now()
+	item.ID = uuid.New()
+	item.Status = entities.SuspenseStatusOpen
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	if item.ReceivedAt.IsZero() {
+		item.ReceivedAt = now
+	}
+	if item.Details == nil {
+		item.Details = map[string]string{}
+	}
+
+	held, created, err := s.repo.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		metrics.SuspenseItemsHeld.WithLabelValues(string(held.Source), held.Reason).Inc()
+		s.logger.Warn("Unattributed funds held in suspense",
+			zap.String("suspense_item_id", held.ID.String()),
+			zap.String("source", string(held.Source)),
+			zap.String("source_ref", held.SourceRef),
+			zap.String("reason", held.Reason),
+			logger.Amount("amount", held.Amount),
+			zap.String("currency", held.Currency))
+	}
+
+	// A redelivery finishes a hold whose posting failed
+	if held.HoldLedgerTransactionID == nil {
+		txID, err := s.post(ctx, held, "hold", ledger.CreateSuspenseHoldEntries)
+		if err != nil {
+			return nil, err
+		}
+		if txID != nil {
+			if err := s.repo.SetHoldTransaction(ctx, held.ID, *txID); err != nil {
+				return nil, err
+			}
+			held.HoldLedgerTransactionID = txID
+		}
+	}
+	return held, nil
+}
+
+// List returns items by status, oldest first. Open items come with
+// suggestions of who they may belong to.
+func (s *Service) List(ctx context.Context, status entities.SuspenseStatus, limit, offset int) ([]*entities.SuspenseItem, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	items, err := s.repo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		s.suggest(ctx, item)
+	}
+	return items, nil
+}
+
+// Get returns an item, with suggestions while it is open
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.SuspenseItem, error) {
+	item, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.suggest(ctx, item)
+	return item, nil
+}
+
+// Assign credits an open item to a user and releases it from suspense
+func (s *Service) Assign(ctx context.Context, id, adminID uuid.UUID, req *entities.AssignSuspenseItemRequest) (*entities.SuspenseItem, error) {
+	note := strings.TrimSpace(req.Note)
+	return s.resolve(ctx, id, adminID, entities.SuspenseStatusAssigned, &req.UserID, note,
+		func(source Source, item *entities.SuspenseItem) error {
+			return source.AssignSuspended(ctx, item, req.UserID, adminID, note)
+		})
+}
+
+// Refund records that an open item was sent back to its sender and releases
+// it from suspense
+func (s *Service) Refund(ctx context.Context, id, adminID uuid.UUID, req *entities.RefundSuspenseItemRequest) (*entities.SuspenseItem, error) {
+	note := strings.TrimSpace(req.Note)
+	return s.resolve(ctx, id, adminID, entities.SuspenseStatusRefunded, nil, note,
+		func(source Source, item *entities.SuspenseItem) error {
+			return source.RefundSuspended(ctx, item, adminID, note)
+		})
+}
+
+// resolve claims an open item, settles it through its source and releases it
+// from suspense. If the source fails the item is reopened.
+func (s *Service) resolve(ctx context.Context, id, adminID uuid.UUID, status entities.SuspenseStatus, userID *uuid.UUID, note string,
+	settle func(source Source, item *entities.SuspenseItem) error) (*entities.SuspenseItem, error) {
+	item, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != entities.SuspenseStatusOpen {
+		return nil, entities.ErrSuspenseItemResolved
+	}
+	source, ok := s.sources[item.Source]
+	if !ok {
+		return nil, fmt.Errorf("no handler for %s suspense items", item.Source)
+	}
+
+	claimed, err := s.repo.Claim(ctx, id, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, entities.ErrSuspenseItemResolved
+	}
+	if err := settle(source, item); err != nil {
+		if reopenErr := s.repo.Reopen(ctx, id, s.now()); reopenErr != nil {
+			s.logger.Error("Failed to reopen suspense item", zap.Error(reopenErr), zap.String("suspense_item_id", id.String()))
+		}
+		return nil, err
+	}
+
+	// The source has settled, so the item is resolved even if the release
+	// cannot be posted; the posting is idempotent and can be retried by hand
+	releaseTxID, err := s.post(ctx, item, "release", ledger.CreateSuspenseReleaseEntries)
+	if err != nil {
+		s.logger.Error("Suspense item settled but not released in the ledger", zap.Error(err), zap.String("suspense_item_id", id.String()))
+	}
+
+	now := s.now()
+	item.Status = status
+	item.UserID = userID
+	item.ResolvedBy = &adminID
+	item.ResolvedAt = &now
+	item.Note = &note
+	item.ReleaseLedgerTransactionID = releaseTxID
+	item.UpdatedAt = now
+	if err := s.repo.Resolve(ctx, item); err != nil {
+		s.logger.Error("Suspense item settled but not marked resolved", zap.Error(err), zap.String("suspense_item_id", id.String()))
+		return nil, err
+	}
+
+	s.logger.Info("Suspense item resolved",
+		zap.String("suspense_item_id", id.String()),
+		zap.String("status", string(status)),
+		zap.String("admin_id", adminID.String()))
+	return item, nil
+}
+
+// suggest fills an open item's suggestions. Failures are logged; the item is
+// still usable without them.
+func (s *Service) suggest(ctx context.Context, item *entities.SuspenseItem) {
+	if item.Status != entities.SuspenseStatusOpen {
+		return
+	}
+	source, ok := s.sources[item.Source]
+	if !ok {
+		return
+	}
+	suggestions, err := source.SuspenseSuggestions(ctx, item)
+	if err != nil {
+		s.logger.Warn("Failed to suggest owners for suspense item", zap.Error(err), zap.String("suspense_item_id", item.ID.String()))
+		return
+	}
+	item.Suggestions = suggestions
+}
+
+// post books an item into or out of suspense, returning nil when there is no
+// ledger or nothing to post
+func (s *Service) post(ctx context.Context, item *entities.SuspenseItem, kind string,
+	entries func(suspenseID, bufferID uuid.UUID, amount decimal.Decimal, currency string) []entities.CreateEntryRequest) (*uuid.UUID, error) {
+	book, ok := sourceLedgers[item.Source]
+	if s.ledger == nil || !ok {
+		return nil, nil
+	}
+	amount := book.amount(item)
+	if !amount.IsPositive() {
+		s.logger.Warn("Suspense item has no value to post", zap.String("suspense_item_id", item.ID.String()), zap.String("kind", kind))
+		return nil, nil
+	}
+
+	suspenseAccount, err := s.ledger.GetSystemAccount(ctx, entities.AccountTypeSystemSuspense)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suspense ledger account: %w", err)
+	}
+	bufferAccount, err := s.ledger.GetSystemAccount(ctx, book.buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system ledger account: %w", err)
+	}
+
+	req, err := ledger.NewTransactionRequestBuilder().
+		WithType(entities.TransactionTypeInternalTransfer).
+		WithReference(item.ID, "suspense_item").
+		WithIdempotencyKey(fmt.Sprintf("suspense-%s-%s", kind, item.ID)).
+		WithDescription(fmt.Sprintf("Suspense %s: %s %s (%s %s)", kind, item.Amount, item.Currency, item.Source, item.SourceRef)).
+		WithMetadata(map[string]any{
+			"suspense_item_id": item.ID.String(),
+			"source":           string(item.Source),
+			"source_ref":       item.SourceRef,
+			"reason":           item.Reason,
+		}).
+		WithEntries(entries(suspenseAccount.ID, bufferAccount.ID, amount, book.currency)).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build suspense %s transaction: %w", kind, err)
+	}
+	tx, err := s.ledger.CreateTransaction(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post suspense %s transaction: %w", kind, err)
+	}
+	return &tx.ID, nil
+}
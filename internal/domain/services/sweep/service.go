@@ -0,0 +1,516 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository persists sweep rules and their runs
+type Repository interface {
+	GetByUser(ctx context.Context, userID uuid.UUID) (*entities.SweepRule, error)
+	// Save creates the user's rule or replaces its settings
+	Save(ctx context.Context, rule *entities.SweepRule) error
+	// ClaimDue locks up to limit enabled rules whose next run is at or before
+	// now until now+lease
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.SweepRule, error)
+	// FinishRun records a run, schedules the rule's next one and releases it.
+	// disableReason turns the rule off when set.
+	FinishRun(ctx context.Context, rule *entities.SweepRule, run *entities.SweepRun, disableReason *string) error
+	ListRuns(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.SweepRun, error)
+}
+
+// BalanceReader reads a user's buying power
+type BalanceReader interface {
+	Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error)
+}
+
+// Investor looks up baskets and places the buy orders sweeps make
+type Investor interface {
+	GetBasket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error)
+	CreateOrder(ctx context.Context, userID uuid.UUID, req *entities.OrderCreateRequest) (*entities.Order, error)
+}
+
+// FeatureGate reports whether a feature is enabled in a user's country
+type FeatureGate interface {
+	IsFeatureAllowed(ctx context.Context, userID uuid.UUID, feature entities.JurisdictionFeature) (bool, string, error)
+}
+
+// ConsentGate lists the mandatory agreements a user has yet to accept
+type ConsentGate interface {
+	PendingMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.AgreementVersion, error)
+}
+
+// TradingModes resolves whether a user trades live or on paper
+type TradingModes interface {
+	Mode(ctx context.Context, userID uuid.UUID) (entities.TradingMode, error)
+}
+
+// Notifier tells users what a sweep did
+type Notifier interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// Config configures auto-sweep
+type Config struct {
+	// DefaultBasketID is the low-risk cash basket used when a user does not
+	// pick one; uuid.Nil makes a basket choice mandatory
+	DefaultBasketID uuid.UUID
+	MinAmount       decimal.Decimal // Smallest sweep unless a user sets a higher one
+	MaxFailures     int             // Consecutive failed runs before a rule is turned off
+	BatchSize       int
+	Lease           time.Duration // How long a claimed rule stays locked to one worker
+	Interval        time.Duration
+}
+
+// DefaultConfig sweeps at least $10, turns a rule off after three failed runs
+// in a row and looks for due rules every 15 minutes
+func DefaultConfig() Config {
+	return Config{
+		MinAmount:   decimal.NewFromInt(10),
+		MaxFailures: 3,
+		BatchSize:   100,
+		Lease:       5 * time.Minute,
+		Interval:    15 * time.Minute,
+	}
+}
+
+// RunReport summarizes one pass over due rules
+type RunReport struct {
+	Invested int `json:"invested"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+}
+
+// Service invests idle buying power for users who opt in. At each run of a
+// rule's cadence, buying power above the user's floor is placed as a buy
+// order into their chosen basket; runs that would sweep less than the
+// minimum are skipped. Every sweep is notified, and a rule that keeps failing
+// turns itself off and says so.
+type Service struct {
+	repo     Repository
+	balances BalanceReader
+	investor Investor
+	notifier Notifier
+	features FeatureGate
+	consents ConsentGate
+	modes    TradingModes
+	config   Config
+	logger   *zap.Logger
+	tracker  *workerstatus.Tracker
+	now      func() time.Time
+}
+
+// NewService creates a new auto-sweep service
+func NewService(repo Repository, balances BalanceReader, investor Investor, notifier Notifier, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if !config.MinAmount.IsPositive() {
+		config.MinAmount = defaults.MinAmount
+	}
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = defaults.MaxFailures
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:     repo,
+		balances: balances,
+		investor: investor,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetGates skips sweeps for users whose country does not allow live trading
+// or who have mandatory agreements to accept, as the investing routes would
+// refuse them
+func (s *Service) SetGates(features FeatureGate, consents ConsentGate) {
+	s.features = features
+	s.consents = consents
+}
+
+// SetTradingModes skips sweeps for users in paper mode, whose live cash is
+// not being traded
+func (s *Service) SetTradingModes(modes TradingModes) {
+	s.modes = modes
+}
+
+// SetTracker reports sweep runs to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often due rules are looked for
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Overview returns the user's rule, if any, and its recent runs
+func (s *Service) Overview(ctx context.Context, userID uuid.UUID) (*entities.SweepOverview, error) {
+	overview := &entities.SweepOverview{Runs: []*entities.SweepRun{}}
+	rule, err := s.repo.GetByUser(ctx, userID)
+	if errors.Is(err, entities.ErrSweepRuleNotFound) {
+		return overview, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	overview.Rule = rule
+
+	runs, err := s.repo.ListRuns(ctx, userID, 20)
+	if err != nil {
+		return nil, err
+	}
+	if runs != nil {
+		overview.Runs = runs
+	}
+	return overview, nil
+}
+
+// Save sets up or changes the user's rule and turns it on. A rule that was
+// off, or is new, runs at the next pass; otherwise its schedule is kept.
+func (s *Service) Save(ctx context.Context, userID uuid.UUID, req *entities.SaveSweepRuleRequest) (*entities.SweepRule, error) {
+	if !req.Cadence.IsValid() {
+		return nil, fmt.Errorf("%w: cadence must be daily, weekly or monthly", entities.ErrInvalidSweepRule)
+	}
+	if req.Floor.IsNegative() {
+		return nil, fmt.Errorf("%w: floor cannot be negative", entities.ErrInvalidSweepRule)
+	}
+	minAmount := s.config.MinAmount
+	if req.MinAmount != nil {
+		if req.MinAmount.LessThan(s.config.MinAmount) {
+			return nil, fmt.Errorf("%w: minimum sweep is $%s", entities.ErrInvalidSweepRule, s.config.MinAmount.StringFixed(2))
+		}
+		minAmount = *req.MinAmount
+	}
+	if req.MaxAmount != nil && req.MaxAmount.LessThan(minAmount) {
+		return nil, fmt.Errorf("%w: maximum cannot be below the minimum", entities.ErrInvalidSweepRule)
+	}
+
+	basketID := s.config.DefaultBasketID
+	if req.BasketID != nil {
+		basketID = *req.BasketID
+	}
+	if basketID == uuid.Nil {
+		return nil, fmt.Errorf("%w: choose a basket to sweep into", entities.ErrInvalidSweepRule)
+	}
+	basket, err := s.investor.GetBasket(ctx, basketID)
+	if err != nil && !errors.Is(err, investing.ErrBasketNotFound) {
+		return nil, err
+	}
+	if basket == nil {
+		return nil, fmt.Errorf("%w: basket not found", entities.ErrInvalidSweepRule)
+	}
+
+	now := s.now().UTC()
+	rule, err := s.repo.GetByUser(ctx, userID)
+	if errors.Is(err, entities.ErrSweepRuleNotFound) {
+		rule = &entities.SweepRule{ID: uuid.New(), UserID: userID, CreatedAt: now}
+	} else if err != nil {
+		return nil, err
+	}
+	if !rule.Enabled {
+		rule.Enabled = true
+		rule.NextRunAt = now
+		rule.ConsecutiveFailures = 0
+		rule.DisabledReason = nil
+	}
+	rule.BasketID = basketID
+	rule.Floor = req.Floor
+	rule.MinAmount = minAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.Cadence = req.Cadence
+	rule.UpdatedAt = now
+
+	if err := s.repo.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Auto-sweep saved",
+		zap.String("user_id", userID.String()),
+		zap.String("basket_id", basketID.String()),
+		zap.String("cadence", string(rule.Cadence)))
+	return rule, nil
+}
+
+// Disable turns the user's rule off; its settings are kept for next time
+func (s *Service) Disable(ctx context.Context, userID uuid.UUID) (*entities.SweepRule, error) {
+	rule, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Enabled {
+		return rule, nil
+	}
+	reason := "Turned off by you"
+	rule.Enabled = false
+	rule.DisabledReason = &reason
+	rule.UpdatedAt = s.now().UTC()
+	if err := s.repo.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Auto-sweep turned off", zap.String("user_id", userID.String()))
+	return rule, nil
+}
+
+// Start runs due sweeps on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				report, err := s.RunDue(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Auto-sweep run failed", zap.Error(err))
+					continue
+				}
+				if report.Invested+report.Skipped+report.Failed > 0 {
+					s.logger.Info("Auto-sweep run completed",
+						zap.Int("invested", report.Invested),
+						zap.Int("skipped", report.Skipped),
+						zap.Int("failed", report.Failed))
+				}
+			}
+		}
+	}()
+}
+
+// RunDue sweeps every rule whose next run has come
+func (s *Service) RunDue(ctx context.Context) (*RunReport, error) {
+	report := &RunReport{}
+	for {
+		now := s.now().UTC()
+		rules, err := s.repo.ClaimDue(ctx, now, s.config.Lease, s.config.BatchSize)
+		if err != nil {
+			return report, fmt.Errorf("failed to claim due sweeps: %w", err)
+		}
+		for _, rule := range rules {
+			run, err := s.sweep(ctx, rule, now)
+			if err != nil {
+				s.logger.Warn("Failed to record auto-sweep", zap.String("rule_id", rule.ID.String()), zap.Error(err))
+			}
+			switch run.Status {
+			case entities.SweepRunInvested:
+				report.Invested++
+			case entities.SweepRunSkipped:
+				report.Skipped++
+			default:
+				report.Failed++
+			}
+		}
+		if len(rules) < s.config.BatchSize {
+			return report, nil
+		}
+	}
+}
+
+// sweep runs one rule: it invests the idle amount, records the run and
+// schedules the next one
+func (s *Service) sweep(ctx context.Context, rule *entities.SweepRule, now time.Time) (*entities.SweepRun, error) {
+	run := &entities.SweepRun{
+		ID:        uuid.New(),
+		RuleID:    rule.ID,
+		UserID:    rule.UserID,
+		BasketID:  rule.BasketID,
+		Amount:    decimal.Zero,
+		CreatedAt: now,
+	}
+
+	order, err := s.invest(ctx, rule, run)
+	switch {
+	case err == nil && order != nil:
+		run.Status = entities.SweepRunInvested
+		run.OrderID = &order.ID
+		rule.ConsecutiveFailures = 0
+	case err == nil:
+		run.Status = entities.SweepRunSkipped
+	case errors.Is(err, investing.ErrInsufficientFunds), errors.Is(err, entities.ErrSpendingLimitReached):
+		// Cash moved, or allocation mode holds it back; nothing to retry
+		run.Status = entities.SweepRunSkipped
+		reason := err.Error()
+		run.Reason = &reason
+	default:
+		run.Status = entities.SweepRunFailed
+		reason := err.Error()
+		run.Reason = &reason
+		rule.ConsecutiveFailures++
+	}
+
+	var disableReason *string
+	if run.Status == entities.SweepRunFailed && rule.ConsecutiveFailures >= s.config.MaxFailures {
+		reason := fmt.Sprintf("Turned off after %d failed sweeps in a row", rule.ConsecutiveFailures)
+		disableReason = &reason
+	}
+	rule.LastRunAt = &now
+	rule.NextRunAt = rule.Cadence.Next(rule.NextRunAt, now)
+	rule.UpdatedAt = now
+
+	if err := s.repo.FinishRun(ctx, rule, run, disableReason); err != nil {
+		return run, err
+	}
+	s.notifyRun(ctx, rule, run, disableReason)
+	return run, nil
+}
+
+// invest places the sweep's order, or returns a nil order when the idle
+// amount is below the rule's minimum
+func (s *Service) invest(ctx context.Context, rule *entities.SweepRule, run *entities.SweepRun) (*entities.Order, error) {
+	reason, err := s.ineligible(ctx, rule.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		run.Reason = &reason
+		return nil, nil
+	}
+
+	balance, err := s.balances.Get(ctx, rule.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buying power: %w", err)
+	}
+	run.BuyingPower = balance.BuyingPower
+
+	amount := balance.BuyingPower.Sub(rule.Floor).RoundDown(2)
+	if rule.MaxAmount != nil && amount.GreaterThan(*rule.MaxAmount) {
+		amount = *rule.MaxAmount
+	}
+	if amount.LessThan(rule.MinAmount) {
+		reason := fmt.Sprintf("Idle cash above your floor was below the $%s minimum", rule.MinAmount.StringFixed(2))
+		run.Reason = &reason
+		return nil, nil
+	}
+	run.Amount = amount
+
+	idempotencyKey := "sweep-" + run.ID.String()
+	order, err := s.investor.CreateOrder(ctx, rule.UserID, &entities.OrderCreateRequest{
+		BasketID:       rule.BasketID,
+		Side:           entities.OrderSideBuy,
+		Amount:         amount.StringFixed(2),
+		IdempotencyKey: &idempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Idle buying power swept",
+		zap.String("user_id", rule.UserID.String()),
+		zap.String("order_id", order.ID.String()),
+		logger.Amount("amount", amount))
+	return order, nil
+}
+
+// ineligible re-checks, at run time, what the investing routes check when
+// the rule is saved. It returns why the user cannot be swept now, or "".
+func (s *Service) ineligible(ctx context.Context, userID uuid.UUID) (string, error) {
+	if s.features != nil {
+		allowed, _, err := s.features.IsFeatureAllowed(ctx, userID, entities.FeatureTrading)
+		if err != nil {
+			return "", fmt.Errorf("failed to check trading availability: %w", err)
+		}
+		if !allowed {
+			return "Live trading is not available in your country", nil
+		}
+	}
+	if s.consents != nil {
+		pending, err := s.consents.PendingMandatory(ctx, userID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check consents: %w", err)
+		}
+		if len(pending) > 0 {
+			return "Updated agreements need accepting before we can invest for you", nil
+		}
+	}
+	if s.modes != nil {
+		mode, err := s.modes.Mode(ctx, userID)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve trading mode: %w", err)
+		}
+		if mode == entities.TradingModePaper {
+			return "Auto-sweep is paused while you paper trade", nil
+		}
+	}
+	return "", nil
+}
+
+// notifyRun tells the user about sweeps that invested or failed. Skipped
+// runs are quiet so a user sitting below their floor is not messaged daily.
+func (s *Service) notifyRun(ctx context.Context, rule *entities.SweepRule, run *entities.SweepRun, disableReason *string) {
+	if s.notifier == nil {
+		return
+	}
+
+	var title, message string
+	priority := entities.PriorityMedium
+	switch {
+	case disableReason != nil:
+		title = "Auto-sweep turned off"
+		message = fmt.Sprintf("We couldn't sweep your idle cash %d times in a row, so auto-sweep is off. You can turn it back on in settings.", rule.ConsecutiveFailures)
+		priority = entities.PriorityHigh
+	case run.Status == entities.SweepRunInvested:
+		title = "Idle cash invested"
+		message = fmt.Sprintf("Auto-sweep invested $%s of buying power above your $%s floor. You can change or turn off auto-sweep in settings.",
+			run.Amount.StringFixed(2), rule.Floor.StringFixed(2))
+	case run.Status == entities.SweepRunFailed:
+		title = "Auto-sweep didn't go through"
+		message = "We couldn't invest your idle cash this time and will try again at the next sweep."
+	default:
+		return
+	}
+
+	data := map[string]interface{}{
+		"sweep_rule_id": rule.ID.String(),
+		"sweep_run_id":  run.ID.String(),
+		"basket_id":     rule.BasketID.String(),
+		"amount":        run.Amount.StringFixed(2),
+		"status":        string(run.Status),
+	}
+	if run.OrderID != nil {
+		data["order_id"] = run.OrderID.String()
+	}
+	notification := &entities.Notification{
+		ID:        uuid.New(),
+		UserID:    rule.UserID,
+		Type:      entities.NotificationTypeTrade,
+		Channel:   entities.ChannelInApp,
+		Priority:  priority,
+		Title:     title,
+		Message:   message,
+		Data:      data,
+		CreatedAt: run.CreatedAt,
+	}
+	if err := s.notifier.Send(ctx, notification, &entities.UserPreference{UserID: rule.UserID}); err != nil {
+		s.logger.Warn("Failed to send auto-sweep notification", zap.String("rule_id", rule.ID.String()), zap.Error(err))
+	}
+}
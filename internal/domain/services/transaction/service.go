@@ -12,6 +12,7 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/infrastructure/database"
 	"github.com/stack-service/stack_service/pkg/errors"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"github.com/stack-service/stack_service/pkg/metrics"
 	"go.uber.org/zap"
 )
@@ -162,7 +163,7 @@ func (s *Service) ProcessTransaction(ctx context.Context, tx *Transaction) (*Tra
 	s.logger.Info("Transaction processed successfully",
 		zap.String("transaction_id", tx.ID.String()),
 		zap.String("type", string(tx.Type)),
-		zap.String("amount", tx.Amount.String()),
+		logger.Amount("amount", tx.Amount),
 		zap.String("currency", tx.Currency),
 	)
 
@@ -267,7 +268,7 @@ func (s *Service) processWithdrawal(ctx context.Context, tx *sql.Tx, transaction
 		if !canSpend {
 			s.logger.Warn("Withdrawal declined - spending limit reached",
 				zap.String("user_id", transaction.UserID.String()),
-				zap.String("amount", transaction.Amount.String()))
+				logger.Amount("amount", transaction.Amount))
 			
 			// Log declined spending event
 			_ = s.allocationService.LogDeclinedSpending(ctx, transaction.UserID, transaction.Amount, "withdrawal")
@@ -321,7 +322,7 @@ func (s *Service) processInvestment(ctx context.Context, tx *sql.Tx, transaction
 		if !canSpend {
 			s.logger.Warn("Investment declined - spending limit reached",
 				zap.String("user_id", transaction.UserID.String()),
-				zap.String("amount", transaction.Amount.String()))
+				logger.Amount("amount", transaction.Amount))
 			
 			// Log declined spending event
 			_ = s.allocationService.LogDeclinedSpending(ctx, transaction.UserID, transaction.Amount, "investment")
@@ -12,13 +12,25 @@ import (
 )
 
 type TransactionControlService struct {
-	logger *zap.Logger
+	logger      *zap.Logger
+	sessionRisk SessionRiskSource
+}
+
+// SessionRiskSource reports whether a user's recent sessions were flagged,
+// e.g. a login through a high-risk VPN
+type SessionRiskSource interface {
+	SessionRisk(ctx context.Context, userID uuid.UUID) (*entities.GeoAccessEvent, error)
 }
 
 func NewTransactionControlService(logger *zap.Logger) *TransactionControlService {
 	return &TransactionControlService{logger: logger}
 }
 
+// SetSessionRiskSource adds flagged sessions to the fraud score
+func (s *TransactionControlService) SetSessionRiskSource(source SessionRiskSource) {
+	s.sessionRisk = source
+}
+
 func (s *TransactionControlService) CheckLimit(ctx context.Context, userID uuid.UUID, limitType entities.LimitType, amount decimal.Decimal, limit *entities.TransactionLimit) error {
 	if limit.ResetAt.Before(time.Now()) {
 		limit.UsedAmount = decimal.Zero
@@ -56,6 +68,18 @@ func (s *TransactionControlService) CalculateFraudScore(ctx context.Context, use
 		factors["high_amount"] = true
 	}
 
+	if s.sessionRisk != nil {
+		flagged, err := s.sessionRisk.SessionRisk(ctx, userID)
+		if err != nil {
+			s.logger.Warn("Failed to check session risk", zap.String("user_id", userID.String()), zap.Error(err))
+		} else if flagged != nil {
+			score = score.Add(decimal.NewFromFloat(0.5))
+			factors["masked_session"] = true
+			factors["session_country"] = flagged.CountryCode
+			factors["session_risk_score"] = flagged.RiskScore
+		}
+	}
+
 	factors["tx_type"] = txType
 	
 	return score, factors
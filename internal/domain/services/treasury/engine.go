@@ -24,6 +24,12 @@ type Engine struct {
 	db              *sqlx.DB
 	logger          *logger.Logger
 	config          *EngineConfig
+	rates           RateRecorder
+}
+
+// RateRecorder keeps the exchange rates conversions settle at
+type RateRecorder interface {
+	Record(ctx context.Context, rate *entities.ExchangeRate) error
 }
 
 // EngineConfig holds treasury engine configuration
@@ -62,6 +68,11 @@ func NewEngine(
 	}
 }
 
+// SetRateRecorder records the rate each completed conversion settled at
+func (e *Engine) SetRateRecorder(rates RateRecorder) {
+	e.rates = rates
+}
+
 // DefaultEngineConfig returns default configuration
 func DefaultEngineConfig() *EngineConfig {
 	return &EngineConfig{
@@ -603,6 +614,7 @@ func (e *Engine) PostLedgerEntries(ctx context.Context, job *entities.Conversion
 			"error", err)
 		// Don't fail - transaction is already posted
 	}
+	e.recordConversionRate(ctx, job, sourceCurrency, destCurrency, sourceAmount, destAmount, statusResp.ExchangeRate)
 
 	e.logger.Info("Ledger entries posted for conversion",
 		"job_id", job.ID,
@@ -613,6 +625,30 @@ func (e *Engine) PostLedgerEntries(ctx context.Context, job *entities.Conversion
 	return nil
 }
 
+// recordConversionRate stores the rate a conversion settled at, preferring the
+// provider's quoted rate over the one implied by the settled amounts
+func (e *Engine) recordConversionRate(ctx context.Context, job *entities.ConversionJob, sourceCurrency, destCurrency string, sourceAmount, destAmount decimal.Decimal, quoted *decimal.Decimal) {
+	if e.rates == nil || !sourceAmount.IsPositive() {
+		return
+	}
+	rate := destAmount.DivRound(sourceAmount, 18)
+	if quoted != nil && quoted.IsPositive() {
+		rate = *quoted
+	}
+
+	err := e.rates.Record(ctx, &entities.ExchangeRate{
+		Pair:          entities.NewCurrencyPair(sourceCurrency, destCurrency),
+		Rate:          rate,
+		ObservedAt:    time.Now(),
+		Source:        entities.RateSourceConversion,
+		ReferenceType: stringPtr("conversion_job"),
+		ReferenceID:   &job.ID,
+	})
+	if err != nil {
+		e.logger.Warn("Failed to record conversion exchange rate", "job_id", job.ID, "error", err)
+	}
+}
+
 // ProcessStaleJobs handles jobs that have been stuck in processing for too long
 func (e *Engine) ProcessStaleJobs(ctx context.Context) error {
 	staleThreshold := time.Now().Add(-e.config.ConversionTimeout)
@@ -0,0 +1,105 @@
+package trustedcontact
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrUnreachable is returned when a trusted contact has neither email nor phone
+var ErrUnreachable = errors.New("trusted contact needs an email or phone number")
+
+// Repository persists trusted contacts
+type Repository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.TrustedContact, error)
+	Upsert(ctx context.Context, contact *entities.TrustedContact) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+// AuditService records changes to a user's trusted contact
+type AuditService interface {
+	LogOnboardingEvent(ctx context.Context, userID uuid.UUID, action, entity string, before, after interface{}) error
+}
+
+// Service manages the trusted contact on a user's account
+type Service struct {
+	repo         Repository
+	auditService AuditService
+	logger       *zap.Logger
+}
+
+// NewService creates a new trusted contact service
+func NewService(repo Repository, auditService AuditService, logger *zap.Logger) *Service {
+	return &Service{
+		repo:         repo,
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// Get returns the user's trusted contact
+func (s *Service) Get(ctx context.Context, userID uuid.UUID) (*entities.TrustedContact, error) {
+	return s.repo.GetByUserID(ctx, userID)
+}
+
+// Set creates or replaces the user's trusted contact
+func (s *Service) Set(ctx context.Context, userID uuid.UUID, req *entities.UpsertTrustedContactRequest) (*entities.TrustedContact, error) {
+	email := trimOptional(req.Email)
+	phone := trimOptional(req.Phone)
+	if email == nil && phone == nil {
+		return nil, ErrUnreachable
+	}
+
+	contact := &entities.TrustedContact{
+		ID:           uuid.New(),
+		UserID:       userID,
+		FirstName:    strings.TrimSpace(req.FirstName),
+		LastName:     strings.TrimSpace(req.LastName),
+		Relationship: strings.TrimSpace(req.Relationship),
+		Email:        email,
+		Phone:        phone,
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.repo.Upsert(ctx, contact); err != nil {
+		return nil, err
+	}
+
+	// Contact details are deliberately left out of the audit record
+	s.audit(ctx, userID, "trusted_contact_updated", map[string]interface{}{"relationship": contact.Relationship})
+	return contact, nil
+}
+
+// Remove deletes the user's trusted contact
+func (s *Service) Remove(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return err
+	}
+	s.audit(ctx, userID, "trusted_contact_removed", nil)
+	return nil
+}
+
+func (s *Service) audit(ctx context.Context, userID uuid.UUID, action string, after interface{}) {
+	if s.auditService == nil {
+		return
+	}
+	if err := s.auditService.LogOnboardingEvent(ctx, userID, action, "trusted_contact", nil, after); err != nil {
+		s.logger.Warn("Failed to log audit event", zap.Error(err), zap.String("action", action))
+	}
+}
+
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,587 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository persists upload records
+type Repository interface {
+	Create(ctx context.Context, upload *entities.Upload) error
+	Get(ctx context.Context, id uuid.UUID) (*entities.Upload, error)
+	Update(ctx context.Context, upload *entities.Upload) error
+	// ListExpiredPending returns pending uploads whose URL expired before the
+	// given time, oldest first
+	ListExpiredPending(ctx context.Context, before time.Time, limit int) ([]*entities.Upload, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// Storage is the object store files are uploaded to. Clients talk to it
+// directly through pre-signed URLs; the service only reads files back to
+// validate and scan them.
+type Storage interface {
+	// PresignPut returns a URL accepting exactly one object of the given type
+	// and size, and the headers the client must send with it
+	PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, map[string]string, error)
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// Stat returns ErrObjectNotFound when nothing was uploaded to key
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrObjectNotFound is returned by Storage for keys with no object
+var ErrObjectNotFound = errors.New("object not found")
+
+// ScanResult is the verdict of a virus scan
+type ScanResult struct {
+	Clean     bool
+	Signature string // What was found when not clean
+}
+
+// Scanner checks file content for malware
+type Scanner interface {
+	Scan(ctx context.Context, content io.Reader) (*ScanResult, error)
+}
+
+// RegionResolver returns the region a user's files must be stored in
+type RegionResolver interface {
+	Region(ctx context.Context, userID uuid.UUID) (entities.ResidencyRegion, error)
+}
+
+// Config controls which files are accepted and how long links stay valid
+type Config struct {
+	MaxSizeBytes     int64
+	AllowedTypes     []string                 // Accepted MIME types
+	UploadURLTTL     time.Duration            // How long a client has to send the file
+	DownloadURLTTL   time.Duration            // Admin download links
+	ProviderURLTTL   time.Duration            // Links handed to KYC providers, which may fetch later
+	RequireScan      bool                     // Refuse to complete uploads while no scanner is configured
+	RequireReference bool                     // Refuse documents given as external URLs instead of uploads
+	HomeRegion       entities.ResidencyRegion // Region of the default storage
+	PurgeBatchSize   int
+	Interval         time.Duration
+}
+
+// DefaultConfig accepts images and PDFs up to 10 MB
+func DefaultConfig() Config {
+	return Config{
+		MaxSizeBytes:   10 << 20,
+		AllowedTypes:   []string{"image/jpeg", "image/png", "image/webp", "application/pdf"},
+		UploadURLTTL:   15 * time.Minute,
+		DownloadURLTTL: 5 * time.Minute,
+		ProviderURLTTL: 24 * time.Hour,
+		HomeRegion:     entities.ResidencyRegionUS,
+		PurgeBatchSize: 100,
+		Interval:       time.Hour,
+	}
+}
+
+// sniffLength is how much of a file http.DetectContentType looks at
+const sniffLength = 512
+
+// Service issues pre-signed upload URLs and vets what comes back. A client
+// declares the file it is about to send, uploads it straight to storage and
+// then completes the upload, at which point the size and type are checked
+// against the stored object and its content is virus scanned. Only uploads
+// that pass can be attached to KYC, EDD, KYB and support documents; files
+// that fail are deleted. With residency set, each user's files go to the
+// bucket for their data region and are refused when no bucket is there.
+type Service struct {
+	repo      Repository
+	storage   Storage
+	regional  map[entities.ResidencyRegion]Storage
+	residency RegionResolver
+	scanner   Scanner
+	config    Config
+	allowed   map[string]bool
+	logger    *zap.Logger
+	tracker   *workerstatus.Tracker
+	now       func() time.Time
+}
+
+// NewService creates a new upload service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.MaxSizeBytes <= 0 {
+		config.MaxSizeBytes = defaults.MaxSizeBytes
+	}
+	if len(config.AllowedTypes) == 0 {
+		config.AllowedTypes = defaults.AllowedTypes
+	}
+	if config.UploadURLTTL <= 0 {
+		config.UploadURLTTL = defaults.UploadURLTTL
+	}
+	if config.DownloadURLTTL <= 0 {
+		config.DownloadURLTTL = defaults.DownloadURLTTL
+	}
+	if config.ProviderURLTTL <= 0 {
+		config.ProviderURLTTL = defaults.ProviderURLTTL
+	}
+	if !config.HomeRegion.IsValid() {
+		config.HomeRegion = defaults.HomeRegion
+	}
+	if config.PurgeBatchSize <= 0 {
+		config.PurgeBatchSize = defaults.PurgeBatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	allowed := make(map[string]bool, len(config.AllowedTypes))
+	for _, contentType := range config.AllowedTypes {
+		allowed[normalizeContentType(contentType)] = true
+	}
+	return &Service{
+		repo:     repo,
+		regional: map[entities.ResidencyRegion]Storage{},
+		config:   config,
+		allowed:  allowed,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetStorage enables uploads to the object store
+func (s *Service) SetStorage(storage Storage) {
+	s.storage = storage
+}
+
+// SetRegionalStorage keeps the files of users whose data must stay in region
+// in a store there. The storage given to SetStorage serves the home region.
+func (s *Service) SetRegionalStorage(region entities.ResidencyRegion, storage Storage) {
+	s.regional[region] = storage
+}
+
+// SetResidency stores each user's files in their data region; without it
+// every file goes to the home region
+func (s *Service) SetResidency(residency RegionResolver) {
+	s.residency = residency
+}
+
+// SetScanner virus scans uploads before they can be used
+func (s *Service) SetScanner(scanner Scanner) {
+	s.scanner = scanner
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports runs to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often abandoned uploads are purged
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Start purges abandoned uploads on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := s.PurgeExpired(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Upload purge failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// CreateUpload records the declared file and returns a pre-signed URL the
+// client uploads it to
+func (s *Service) CreateUpload(ctx context.Context, userID uuid.UUID, req *entities.CreateUploadRequest) (*entities.UploadTicket, error) {
+	if s.storage == nil {
+		return nil, entities.ErrUploadsUnavailable
+	}
+	if !req.Purpose.IsValid() {
+		return nil, fmt.Errorf("%w: unknown purpose %q", entities.ErrInvalidUpload, req.Purpose)
+	}
+	contentType := normalizeContentType(req.ContentType)
+	if !s.allowed[contentType] {
+		return nil, fmt.Errorf("%w: %s files are not accepted", entities.ErrInvalidUpload, req.ContentType)
+	}
+	if req.SizeBytes <= 0 || req.SizeBytes > s.config.MaxSizeBytes {
+		return nil, fmt.Errorf("%w: files must be between 1 byte and %d bytes", entities.ErrInvalidUpload, s.config.MaxSizeBytes)
+	}
+
+	region, err := s.userRegion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := s.storageIn(region)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	id := uuid.New()
+	upload := &entities.Upload{
+		ID:            id,
+		UserID:        userID,
+		Purpose:       req.Purpose,
+		FileName:      cleanFileName(req.FileName),
+		ContentType:   contentType,
+		SizeBytes:     req.SizeBytes,
+		StorageKey:    fmt.Sprintf("%s/%s/%s", req.Purpose, userID, id),
+		StorageRegion: region,
+		Status:        entities.UploadStatusPending,
+		ExpiresAt:     now.Add(s.config.UploadURLTTL),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	url, headers, err := storage.PresignPut(ctx, upload.StorageKey, upload.ContentType, upload.SizeBytes, s.config.UploadURLTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign upload URL: %w", err)
+	}
+	if err := s.repo.Create(ctx, upload); err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	return &entities.UploadTicket{
+		Upload:    upload,
+		URL:       url,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// Complete vets a file the client has finished uploading. The stored object
+// must match the declared size, its content must look like the declared
+// type and the virus scan must come back clean. A file that fails is deleted
+// and the upload rejected with the reason.
+func (s *Service) Complete(ctx context.Context, userID, id uuid.UUID) (*entities.Upload, error) {
+	if s.storage == nil {
+		return nil, entities.ErrUploadsUnavailable
+	}
+	upload, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch upload.Status {
+	case entities.UploadStatusAvailable:
+		return upload, nil
+	case entities.UploadStatusRejected:
+		return nil, rejection(upload)
+	}
+	if s.now().After(upload.ExpiresAt) {
+		return nil, fmt.Errorf("%w: the upload URL has expired, start a new upload", entities.ErrInvalidUpload)
+	}
+	if s.scanner == nil && s.config.RequireScan {
+		return nil, fmt.Errorf("%w: virus scanning is not configured", entities.ErrUploadsUnavailable)
+	}
+	storage, err := s.storageIn(s.storedIn(upload))
+	if err != nil {
+		return nil, err
+	}
+	// The user may have been moved to another region since the upload began
+	if err := s.checkRegion(ctx, upload); err != nil {
+		if errors.Is(err, entities.ErrOutOfRegion) {
+			return nil, s.reject(ctx, storage, upload, "file was stored outside your data region, upload it again")
+		}
+		return nil, err
+	}
+
+	info, err := storage.Stat(ctx, upload.StorageKey)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: no file has been uploaded yet", entities.ErrUploadNotReady)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat upload: %w", err)
+	}
+	if info.Size != upload.SizeBytes {
+		return nil, s.reject(ctx, storage, upload, fmt.Sprintf("file is %d bytes, %d were declared", info.Size, upload.SizeBytes))
+	}
+
+	content, err := storage.Open(ctx, upload.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer content.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	head = head[:n]
+	if detected := normalizeContentType(http.DetectContentType(head)); detected != upload.ContentType {
+		return nil, s.reject(ctx, storage, upload, fmt.Sprintf("file content is %s, %s was declared", detected, upload.ContentType))
+	}
+
+	upload.ScanResult = "skipped"
+	if s.scanner != nil {
+		result, err := s.scanner.Scan(ctx, io.MultiReader(bytes.NewReader(head), content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload: %w", err)
+		}
+		if !result.Clean {
+			upload.ScanResult = result.Signature
+			s.logger.Warn("Malware found in upload",
+				zap.String("upload_id", upload.ID.String()),
+				zap.String("user_id", upload.UserID.String()),
+				zap.String("signature", result.Signature))
+			return nil, s.reject(ctx, storage, upload, "file failed the virus scan")
+		}
+		upload.ScanResult = "clean"
+	}
+
+	now := s.now().UTC()
+	upload.Status = entities.UploadStatusAvailable
+	upload.CompletedAt = &now
+	upload.UpdatedAt = now
+	if err := s.repo.Update(ctx, upload); err != nil {
+		return nil, fmt.Errorf("failed to update upload: %w", err)
+	}
+	return upload, nil
+}
+
+// reject deletes the file and records why, returning ErrUploadRejected with
+// the reason
+func (s *Service) reject(ctx context.Context, storage Storage, upload *entities.Upload, reason string) error {
+	if err := storage.Delete(ctx, upload.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete rejected upload: %w", err)
+	}
+	upload.Status = entities.UploadStatusRejected
+	upload.RejectionReason = &reason
+	upload.UpdatedAt = s.now().UTC()
+	if err := s.repo.Update(ctx, upload); err != nil {
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+	return rejection(upload)
+}
+
+func rejection(upload *entities.Upload) error {
+	reason := "file failed validation"
+	if upload.RejectionReason != nil {
+		reason = *upload.RejectionReason
+	}
+	return fmt.Errorf("%w: %s", entities.ErrUploadRejected, reason)
+}
+
+// Get returns one of the user's uploads
+func (s *Service) Get(ctx context.Context, userID, id uuid.UUID) (*entities.Upload, error) {
+	upload, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userID {
+		return nil, entities.ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// DownloadURL returns a short-lived link to a vetted upload, for reviewers
+func (s *Service) DownloadURL(ctx context.Context, id uuid.UUID) (*entities.Upload, *entities.UploadDownload, error) {
+	if s.storage == nil {
+		return nil, nil, entities.ErrUploadsUnavailable
+	}
+	upload, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upload.Status != entities.UploadStatusAvailable {
+		return nil, nil, fmt.Errorf("%w: upload is %s", entities.ErrUploadNotReady, upload.Status)
+	}
+	storage, err := s.storageIn(s.storedIn(upload))
+	if err != nil {
+		return nil, nil, err
+	}
+	url, err := storage.PresignGet(ctx, upload.StorageKey, s.config.DownloadURLTTL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign download URL: %w", err)
+	}
+	return upload, &entities.UploadDownload{URL: url, ExpiresAt: s.now().UTC().Add(s.config.DownloadURLTTL)}, nil
+}
+
+// ResolveDocuments returns the documents with each upload reference replaced
+// by a link the KYC provider can fetch. Referenced uploads must belong to the
+// user, be vetted and have been made for purpose. Documents given as a URL
+// pass through unless references are required.
+func (s *Service) ResolveDocuments(ctx context.Context, userID uuid.UUID, purpose entities.UploadPurpose, documents []entities.KYCDocumentUpload) ([]entities.KYCDocumentUpload, error) {
+	resolved := make([]entities.KYCDocumentUpload, 0, len(documents))
+	for _, doc := range documents {
+		if doc.UploadID == nil {
+			if s.config.RequireReference {
+				return nil, fmt.Errorf("%w: %s must be uploaded through /api/v1/uploads", entities.ErrInvalidUpload, doc.Type)
+			}
+			resolved = append(resolved, doc)
+			continue
+		}
+		if s.storage == nil {
+			return nil, entities.ErrUploadsUnavailable
+		}
+
+		upload, err := s.Get(ctx, userID, *doc.UploadID)
+		if errors.Is(err, entities.ErrUploadNotFound) {
+			return nil, fmt.Errorf("%w: %s references an unknown upload", entities.ErrInvalidUpload, doc.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if upload.Purpose != purpose {
+			return nil, fmt.Errorf("%w: %s was uploaded for %s, not %s", entities.ErrInvalidUpload, doc.Type, upload.Purpose, purpose)
+		}
+		switch upload.Status {
+		case entities.UploadStatusPending:
+			return nil, fmt.Errorf("%w: complete the upload for %s first", entities.ErrUploadNotReady, doc.Type)
+		case entities.UploadStatusRejected:
+			return nil, rejection(upload)
+		}
+		if err := s.checkRegion(ctx, upload); err != nil {
+			return nil, err
+		}
+		storage, err := s.storageIn(s.storedIn(upload))
+		if err != nil {
+			return nil, err
+		}
+
+		url, err := storage.PresignGet(ctx, upload.StorageKey, s.config.ProviderURLTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign document URL: %w", err)
+		}
+		doc.FileURL = url
+		doc.ContentType = upload.ContentType
+		resolved = append(resolved, doc)
+	}
+	return resolved, nil
+}
+
+// PurgeExpired deletes pending uploads whose URL expired without the upload
+// being completed, returning how many were removed
+func (s *Service) PurgeExpired(ctx context.Context) (int, error) {
+	if s.storage == nil {
+		return 0, nil
+	}
+	expired, err := s.repo.ListExpiredPending(ctx, s.now().UTC(), s.config.PurgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired uploads: %w", err)
+	}
+
+	purged := 0
+	var errs []error
+	for _, upload := range expired {
+		storage, err := s.storageIn(s.storedIn(upload))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", upload.ID, err))
+			continue
+		}
+		if err := storage.Delete(ctx, upload.StorageKey); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", upload.ID, err))
+			continue
+		}
+		if err := s.repo.Delete(ctx, upload.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", upload.ID, err))
+			continue
+		}
+		purged++
+	}
+	if purged > 0 {
+		s.logger.Info("Purged abandoned uploads", zap.Int("count", purged))
+	}
+	return purged, errors.Join(errs...)
+}
+
+// userRegion returns the region the user's files must be stored in
+func (s *Service) userRegion(ctx context.Context, userID uuid.UUID) (entities.ResidencyRegion, error) {
+	if s.residency == nil {
+		return s.config.HomeRegion, nil
+	}
+	region, err := s.residency.Region(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user data region: %w", err)
+	}
+	return region, nil
+}
+
+// storedIn returns the region an upload's file is in; uploads from before
+// residency tagging are in the home region
+func (s *Service) storedIn(upload *entities.Upload) entities.ResidencyRegion {
+	if upload.StorageRegion == "" {
+		return s.config.HomeRegion
+	}
+	return upload.StorageRegion
+}
+
+// storageIn returns the store for a region, or ErrOutOfRegion when there is
+// none, as falling back to another region's store would move the data
+func (s *Service) storageIn(region entities.ResidencyRegion) (Storage, error) {
+	if region == s.config.HomeRegion {
+		return s.storage, nil
+	}
+	if storage, ok := s.regional[region]; ok {
+		return storage, nil
+	}
+	return nil, fmt.Errorf("%w: uploads cannot be stored in %s yet", entities.ErrOutOfRegion, region)
+}
+
+// checkRegion returns ErrOutOfRegion for an upload stored outside its
+// user's current region
+func (s *Service) checkRegion(ctx context.Context, upload *entities.Upload) error {
+	region, err := s.userRegion(ctx, upload.UserID)
+	if err != nil {
+		return err
+	}
+	if stored := s.storedIn(upload); stored != region {
+		return fmt.Errorf("%w: upload %s is stored in %s, the user's data must stay in %s", entities.ErrOutOfRegion, upload.ID, stored, region)
+	}
+	return nil
+}
+
+// normalizeContentType drops parameters and case from a MIME type
+func normalizeContentType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// cleanFileName keeps the base name of what the client called the file; it
+// is only shown back to people and never used as a storage path
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
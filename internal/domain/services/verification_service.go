@@ -12,6 +12,7 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/infrastructure/cache"
 	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/pkg/clock"
 )
 
 const (
@@ -45,22 +46,30 @@ type verificationService struct {
 	smsSender   VerificationSMSSender
 	logger      *zap.Logger
 	config      *config.Config
+	clock       clock.Clock
 }
 
-// NewVerificationService creates a new VerificationService
+// NewVerificationService creates a new VerificationService. Codes expire
+// by clk as well as by their Redis TTL, so expiry can be tested with a fake
+// clock.
 func NewVerificationService(
 	redisClient cache.RedisClient,
 	emailSender VerificationEmailSender,
 	smsSender VerificationSMSSender,
 	logger *zap.Logger,
 	cfg *config.Config,
+	clk clock.Clock,
 ) VerificationService {
+	if clk == nil {
+		clk = clock.System()
+	}
 	return &verificationService{
 		redisClient: redisClient,
 		emailSender: emailSender,
 		smsSender:   smsSender,
 		logger:      logger,
 		config:      cfg,
+		clock:       clk,
 	}
 }
 
@@ -90,11 +99,12 @@ func (s *verificationService) GenerateAndSendCode(ctx context.Context, identifie
 		return "", fmt.Errorf("failed to generate verification code: %w", err)
 	}
 
+	now := s.clock.Now()
 	verificationData := entities.VerificationCodeData{
 		Code:      code,
 		Attempts:  0,
-		ExpiresAt: time.Now().Add(verificationCodeTTL),
-		CreatedAt: time.Now(),
+		ExpiresAt: now.Add(verificationCodeTTL),
+		CreatedAt: now,
 	}
 
 	key := fmt.Sprintf("verification:%s:%s", identifierType, identifier)
@@ -141,9 +151,16 @@ func (s *verificationService) VerifyCode(ctx context.Context, identifierType, id
 		return false, fmt.Errorf("failed to retrieve verification code: %w", err)
 	}
 
+	remaining := storedData.ExpiresAt.Sub(s.clock.Now())
+	if remaining <= 0 {
+		s.logger.Warn("Verification code expired", zap.String("identifier", identifier))
+		s.redisClient.Del(ctx, key)
+		return false, fmt.Errorf("verification code not found or expired")
+	}
+
 	// Increment attempt count
 	storedData.Attempts++
-	if err := s.redisClient.Set(ctx, key, storedData, storedData.ExpiresAt.Sub(time.Now())); err != nil {
+	if err := s.redisClient.Set(ctx, key, storedData, remaining); err != nil {
 		s.logger.Error("Failed to update verification code attempts in Redis", zap.Error(err), zap.String("key", key))
 		// Non-critical error, continue with verification
 	}
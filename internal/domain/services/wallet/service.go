@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -565,7 +566,7 @@ func (s *Service) createWalletForChain(ctx context.Context, userID uuid.UUID, ch
 		s.logger.Info("Wallet already exists for chain",
 			zap.String("userID", userID.String()),
 			zap.String("chain", string(chain)),
-			zap.String("address", existingWallet.Address))
+			logger.WalletAddr(existingWallet.Address))
 		return nil
 	}
 
@@ -652,7 +653,7 @@ func (s *Service) createWalletForChain(ctx context.Context, userID uuid.UUID, ch
 	s.logger.Info("Created developer-controlled wallet successfully",
 		zap.String("userID", userID.String()),
 		zap.String("chain", string(chain)),
-		zap.String("address", address),
+		logger.WalletAddr(address),
 		zap.String("circleWalletID", circleResp.Wallet.ID))
 
 	return nil
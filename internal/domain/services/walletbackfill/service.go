@@ -0,0 +1,340 @@
+package walletbackfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ErrUnsupportedChain is returned when a backfill names a chain wallets cannot be provisioned on
+var ErrUnsupportedChain = errors.New("unsupported wallet chain")
+
+// Repository persists backfills and enqueues their provisioning jobs
+type Repository interface {
+	Create(ctx context.Context, b *entities.WalletBackfill) error
+	Get(ctx context.Context, id uuid.UUID) (*entities.WalletBackfill, error)
+	List(ctx context.Context, limit int) ([]*entities.WalletBackfill, error)
+	RecordProgress(ctx context.Context, id uuid.UUID, enqueued int, cursor *uuid.UUID) error
+	Finish(ctx context.Context, id uuid.UUID, status entities.WalletBackfillStatus, errorMessage *string) (bool, error)
+	CountEligibleUsers(ctx context.Context, chains []string) (int, error)
+	ListCandidates(ctx context.Context, chains []string, after *uuid.UUID, limit int) ([]entities.WalletBackfillCandidate, error)
+	EnqueueJobs(ctx context.Context, backfillID uuid.UUID, candidates []entities.WalletBackfillCandidate, maxAttempts int) error
+	CountJobs(ctx context.Context, backfillID uuid.UUID) (entities.WalletBackfillJobCounts, error)
+}
+
+// Config holds batch defaults. BatchSize per BatchInterval bounds the rate jobs
+// become due, and MaxInFlight bounds how many are outstanding at once, which
+// together keep provisioning under the Circle API quota.
+type Config struct {
+	BatchSize       int
+	BatchInterval   time.Duration
+	MaxInFlight     int
+	MaxAttempts     int
+	SupportedChains []entities.WalletChain
+}
+
+// Service enqueues wallet provisioning jobs for existing users after a chain
+// is added. The provisioning scheduler does the Circle calls; a backfill only
+// feeds it. Restarting a backfill is safe: users that already have the wallet
+// or a job in flight are skipped.
+type Service struct {
+	repo   Repository
+	config Config
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	cancels map[uuid.UUID]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewService creates a new wallet backfill service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.BatchInterval <= 0 {
+		config.BatchInterval = time.Minute
+	}
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = 200
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	return &Service{
+		repo:    repo,
+		config:  config,
+		logger:  logger,
+		cancels: make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// Start records a backfill and enqueues its batches in the background
+func (s *Service) Start(ctx context.Context, req *entities.StartWalletBackfillRequest, requestedBy *uuid.UUID) (*entities.WalletBackfill, error) {
+	backfill, err := s.create(ctx, req, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	if backfill.Status.IsTerminal() {
+		return backfill, nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancels[backfill.ID] = cancel
+	s.mu.Unlock()
+
+	pending := *backfill
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		s.execute(runCtx, backfill)
+	}()
+	return &pending, nil
+}
+
+// Run records a backfill and enqueues every batch before returning. Cancelling
+// ctx stops the backfill after the current batch.
+func (s *Service) Run(ctx context.Context, req *entities.StartWalletBackfillRequest, requestedBy *uuid.UUID) (*entities.WalletBackfill, error) {
+	backfill, err := s.create(ctx, req, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !backfill.Status.IsTerminal() {
+		s.execute(ctx, backfill)
+	}
+	return s.Get(context.Background(), backfill.ID)
+}
+
+// Get returns a backfill with its provisioning jobs broken down by status
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.WalletBackfill, error) {
+	backfill, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountJobs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	backfill.Jobs = &counts
+	return backfill, nil
+}
+
+// List returns recent backfills, newest first
+func (s *Service) List(ctx context.Context, limit int) ([]*entities.WalletBackfill, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.List(ctx, limit)
+}
+
+// Cancel stops a running backfill. Jobs already enqueued still run.
+func (s *Service) Cancel(ctx context.Context, id uuid.UUID) (*entities.WalletBackfill, error) {
+	if _, err := s.repo.Finish(ctx, id, entities.WalletBackfillCancelled, nil); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+	return s.Get(ctx, id)
+}
+
+// Stop cancels backfills running in this process and waits for them to exit
+func (s *Service) Stop() {
+	s.mu.Lock()
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Service) create(ctx context.Context, req *entities.StartWalletBackfillRequest, requestedBy *uuid.UUID) (*entities.WalletBackfill, error) {
+	chains, err := s.validateChains(req.Chains)
+	if err != nil {
+		return nil, err
+	}
+
+	backfill := &entities.WalletBackfill{
+		ID:                   uuid.New(),
+		Chains:               chains,
+		Status:               entities.WalletBackfillRunning,
+		DryRun:               req.DryRun,
+		BatchSize:            positiveOr(req.BatchSize, s.config.BatchSize),
+		BatchIntervalSeconds: positiveOr(req.BatchIntervalSeconds, int(s.config.BatchInterval/time.Second)),
+		MaxInFlight:          positiveOr(req.MaxInFlight, s.config.MaxInFlight),
+		RequestedBy:          requestedBy,
+		StartedAt:            time.Now().UTC(),
+	}
+	if backfill.MaxInFlight < backfill.BatchSize {
+		backfill.MaxInFlight = backfill.BatchSize
+	}
+
+	eligible, err := s.repo.CountEligibleUsers(ctx, chains)
+	if err != nil {
+		return nil, err
+	}
+	backfill.EligibleUsers = eligible
+
+	if err := s.repo.Create(ctx, backfill); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Wallet backfill started",
+		zap.String("backfill_id", backfill.ID.String()),
+		zap.Strings("chains", chains),
+		zap.Int("eligible_users", eligible),
+		zap.Bool("dry_run", backfill.DryRun))
+
+	if backfill.DryRun {
+		if _, err := s.repo.Finish(ctx, backfill.ID, entities.WalletBackfillCompleted, nil); err != nil {
+			return nil, err
+		}
+		backfill.Status = entities.WalletBackfillCompleted
+	}
+	return backfill, nil
+}
+
+func (s *Service) validateChains(requested []string) ([]string, error) {
+	supported := make(map[entities.WalletChain]bool, len(s.config.SupportedChains))
+	for _, chain := range s.config.SupportedChains {
+		supported[chain] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	chains := make([]string, 0, len(requested))
+	for _, raw := range requested {
+		chain := entities.WalletChain(raw)
+		if !chain.IsValid() || (len(supported) > 0 && !supported[chain]) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedChain, raw)
+		}
+		if !seen[raw] {
+			seen[raw] = true
+			chains = append(chains, raw)
+		}
+	}
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("%w: at least one chain is required", ErrUnsupportedChain)
+	}
+	return chains, nil
+}
+
+// execute enqueues batches until no eligible user is left. Each pass waits for
+// the backfill's outstanding jobs to drop below MaxInFlight, so a slow or
+// throttled provider pauses the backfill rather than growing the queue.
+func (s *Service) execute(ctx context.Context, backfill *entities.WalletBackfill) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.cancels, backfill.ID)
+		s.mu.Unlock()
+	}()
+
+	interval := time.Duration(backfill.BatchIntervalSeconds) * time.Second
+	cursor := backfill.CursorUserID
+	enqueued := backfill.EnqueuedJobs
+
+	for {
+		if ctx.Err() != nil {
+			s.finish(backfill.ID, entities.WalletBackfillCancelled, "stopped before completion")
+			return
+		}
+
+		current, err := s.repo.Get(ctx, backfill.ID)
+		if err == nil && current.Status.IsTerminal() {
+			s.logger.Info("Wallet backfill stopped", zap.String("backfill_id", backfill.ID.String()),
+				zap.String("status", string(current.Status)))
+			return
+		}
+
+		candidates, err := s.repo.ListCandidates(ctx, backfill.Chains, cursor, backfill.BatchSize)
+		if err != nil {
+			s.fail(ctx, backfill.ID, err)
+			return
+		}
+		if len(candidates) == 0 {
+			s.finish(backfill.ID, entities.WalletBackfillCompleted, "")
+			s.logger.Info("Wallet backfill completed",
+				zap.String("backfill_id", backfill.ID.String()),
+				zap.Int("enqueued_jobs", enqueued))
+			return
+		}
+
+		counts, err := s.repo.CountJobs(ctx, backfill.ID)
+		if err != nil {
+			s.fail(ctx, backfill.ID, err)
+			return
+		}
+		room := backfill.MaxInFlight - counts.Pending()
+		if room <= 0 {
+			s.wait(ctx, interval)
+			continue
+		}
+		if room < len(candidates) {
+			candidates = candidates[:room]
+		}
+
+		if err := s.repo.EnqueueJobs(ctx, backfill.ID, candidates, s.config.MaxAttempts); err != nil {
+			s.fail(ctx, backfill.ID, err)
+			return
+		}
+		enqueued += len(candidates)
+		last := candidates[len(candidates)-1].UserID
+		cursor = &last
+		if err := s.repo.RecordProgress(ctx, backfill.ID, enqueued, cursor); err != nil {
+			s.logger.Warn("Failed to record wallet backfill progress",
+				zap.String("backfill_id", backfill.ID.String()), zap.Error(err))
+		}
+		s.logger.Info("Wallet backfill batch enqueued",
+			zap.String("backfill_id", backfill.ID.String()),
+			zap.Int("batch", len(candidates)),
+			zap.Int("enqueued_jobs", enqueued),
+			zap.Int("eligible_users", backfill.EligibleUsers))
+
+		s.wait(ctx, interval)
+	}
+}
+
+func (s *Service) wait(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+func (s *Service) fail(ctx context.Context, id uuid.UUID, err error) {
+	if ctx.Err() != nil {
+		s.finish(id, entities.WalletBackfillCancelled, "stopped before completion")
+		return
+	}
+	s.logger.Error("Wallet backfill failed", zap.String("backfill_id", id.String()), zap.Error(err))
+	s.finish(id, entities.WalletBackfillFailed, err.Error())
+}
+
+func (s *Service) finish(id uuid.UUID, status entities.WalletBackfillStatus, message string) {
+	var errorMessage *string
+	if message != "" {
+		errorMessage = &message
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.repo.Finish(ctx, id, status, errorMessage); err != nil {
+		s.logger.Error("Failed to record wallet backfill status",
+			zap.String("backfill_id", id.String()), zap.String("status", string(status)), zap.Error(err))
+	}
+}
+
+func positiveOr(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,333 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Repository reads business events from their source tables and tracks how
+// far each stream has been exported
+type Repository interface {
+	// ClaimStream locks a stream's cursor until now+lease, creating it at the
+	// beginning of time if the stream was never exported. Returns
+	// ErrWarehouseStreamBusy when another worker holds it.
+	ClaimStream(ctx context.Context, stream entities.WarehouseStream, now time.Time, lease time.Duration) (*entities.WarehouseCursor, error)
+	// ReadEvents returns up to limit events after the cursor whose position
+	// is at or before until, oldest first
+	ReadEvents(ctx context.Context, cursor *entities.WarehouseCursor, until time.Time, limit int) ([]*entities.WarehouseEvent, error)
+	// SaveCursor stores the cursor's progress and keeps the lock. Returns
+	// ErrWarehouseCursorMoved when a backfill reset the stream since it was
+	// claimed.
+	SaveCursor(ctx context.Context, cursor *entities.WarehouseCursor) error
+	// ReleaseStream unlocks a stream, recording why its last run stopped
+	ReleaseStream(ctx context.Context, stream entities.WarehouseStream, lastError *string) error
+	// ResetStreams moves the streams' cursors back to from
+	ResetStreams(ctx context.Context, streams []entities.WarehouseStream, from time.Time) error
+	ListCursors(ctx context.Context) ([]*entities.WarehouseCursor, error)
+}
+
+// Sink writes events to a data warehouse
+type Sink interface {
+	// Name identifies the warehouse, e.g. bigquery or snowflake
+	Name() string
+	// Write stores a batch. It must be safe to write the same event twice:
+	// sinks drop events whose ID is already stored.
+	Write(ctx context.Context, events []*entities.WarehouseEvent) error
+}
+
+// Config controls which streams are exported and how
+type Config struct {
+	Streams          []entities.WarehouseStream // Defaults to every stream
+	BatchSize        int                        // Events per write to the sink
+	MaxBatchesPerRun int                        // Batches one stream may write per run, so a backfill does not starve the others
+	// SettleDelay holds back events this recent, so rows committed late with
+	// an earlier timestamp are not skipped by the cursor
+	SettleDelay time.Duration
+	Lease       time.Duration // How long a claimed stream stays locked to one worker
+	Interval    time.Duration
+}
+
+// DefaultConfig exports every stream every five minutes in batches of 500
+func DefaultConfig() Config {
+	return Config{
+		Streams:          entities.AllWarehouseStreams(),
+		BatchSize:        500,
+		MaxBatchesPerRun: 20,
+		SettleDelay:      2 * time.Minute,
+		Lease:            10 * time.Minute,
+		Interval:         5 * time.Minute,
+	}
+}
+
+// StreamReport is what one run exported from a stream
+type StreamReport struct {
+	Stream   entities.WarehouseStream `json:"stream"`
+	Exported int                      `json:"exported"`
+	Busy     bool                     `json:"busy,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+// Service streams normalized business events (signups, deposits, trades and
+// churn signals) from the production tables into the data warehouse, so
+// analytics no longer query the production database. Each stream keeps a
+// cursor over its source table; moving a cursor back backfills history, and
+// because event IDs come from the source rows the warehouse ends up with
+// each event once.
+type Service struct {
+	repo    Repository
+	sink    Sink
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+	now     func() time.Time
+}
+
+// NewService creates a new warehouse export service
+func NewService(repo Repository, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if len(config.Streams) == 0 {
+		config.Streams = defaults.Streams
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxBatchesPerRun <= 0 {
+		config.MaxBatchesPerRun = defaults.MaxBatchesPerRun
+	}
+	if config.SettleDelay <= 0 {
+		config.SettleDelay = defaults.SettleDelay
+	}
+	if config.Lease <= 0 {
+		config.Lease = defaults.Lease
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	return &Service{
+		repo:   repo,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetSink sets the warehouse events are written to. Without one nothing is
+// exported.
+func (s *Service) SetSink(sink Sink) {
+	s.sink = sink
+}
+
+// SetClock overrides the time source, for tests
+func (s *Service) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetTracker reports runs to the worker registry
+func (s *Service) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
+// Interval returns how often new events are exported
+func (s *Service) Interval() time.Duration {
+	return s.config.Interval
+}
+
+// Status returns every stream's cursor and the schemas events are written with
+func (s *Service) Status(ctx context.Context) (*entities.WarehouseStatus, error) {
+	if s.sink == nil {
+		return nil, entities.ErrWarehouseExportUnavailable
+	}
+	cursors, err := s.repo.ListCursors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouse cursors: %w", err)
+	}
+	if cursors == nil {
+		cursors = []*entities.WarehouseCursor{}
+	}
+	schemas := make([]entities.WarehouseSchema, 0, len(s.config.Streams))
+	for _, stream := range s.config.Streams {
+		schemas = append(schemas, entities.WarehouseSchemas[stream])
+	}
+	return &entities.WarehouseStatus{Destination: s.sink.Name(), Cursors: cursors, Schemas: schemas}, nil
+}
+
+// Start exports new events on every tick until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				finish, ok := s.tracker.Begin()
+				if !ok {
+					continue
+				}
+				reports, err := s.Export(ctx)
+				finish(err)
+				if err != nil {
+					s.logger.Warn("Warehouse export run failed", zap.Error(err))
+					continue
+				}
+				for _, report := range reports {
+					if report.Exported > 0 {
+						s.logger.Info("Warehouse events exported",
+							zap.String("stream", string(report.Stream)),
+							zap.Int("events", report.Exported))
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Export writes each stream's events that are newer than its cursor. A
+// stream the sink rejects keeps its cursor and is retried next run; the
+// other streams carry on. The returned error reports the streams that failed.
+func (s *Service) Export(ctx context.Context) ([]*StreamReport, error) {
+	if s.sink == nil {
+		return nil, entities.ErrWarehouseExportUnavailable
+	}
+
+	reports := make([]*StreamReport, 0, len(s.config.Streams))
+	var failed []string
+	for _, stream := range s.config.Streams {
+		report := s.exportStream(ctx, stream)
+		if report.Error != "" {
+			failed = append(failed, string(stream))
+		}
+		reports = append(reports, report)
+	}
+	if len(failed) > 0 {
+		return reports, fmt.Errorf("warehouse export failed for %v", failed)
+	}
+	return reports, nil
+}
+
+func (s *Service) exportStream(ctx context.Context, stream entities.WarehouseStream) *StreamReport {
+	report := &StreamReport{Stream: stream}
+	now := s.now().UTC()
+	cursor, err := s.repo.ClaimStream(ctx, stream, now, s.config.Lease)
+	if errors.Is(err, entities.ErrWarehouseStreamBusy) {
+		report.Busy = true
+		return report
+	}
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to claim stream: %v", err)
+		return report
+	}
+
+	until := now.Add(-s.config.SettleDelay)
+	exportErr := s.exportBatches(ctx, cursor, until, report)
+
+	var lastError *string
+	if exportErr != nil {
+		report.Error = exportErr.Error()
+		lastError = &report.Error
+		s.logger.Warn("Warehouse stream export failed",
+			zap.String("stream", string(stream)),
+			zap.String("sink", s.sink.Name()),
+			zap.Error(exportErr))
+	}
+	if err := s.repo.ReleaseStream(ctx, stream, lastError); err != nil {
+		s.logger.Warn("Failed to release warehouse stream", zap.String("stream", string(stream)), zap.Error(err))
+	}
+	return report
+}
+
+// exportBatches writes batches until the stream is caught up or the run's
+// batch budget is spent, saving the cursor after every batch the sink accepts
+func (s *Service) exportBatches(ctx context.Context, cursor *entities.WarehouseCursor, until time.Time, report *StreamReport) error {
+	for i := 0; i < s.config.MaxBatchesPerRun; i++ {
+		events, err := s.repo.ReadEvents(ctx, cursor, until, s.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read events: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		if err := s.sink.Write(ctx, events); err != nil {
+			return fmt.Errorf("%s rejected batch: %w", s.sink.Name(), err)
+		}
+
+		last := events[len(events)-1]
+		exportedAt := s.now().UTC()
+		cursor.Position = last.Position
+		cursor.LastID = last.SourceID
+		cursor.ExportedCount += int64(len(events))
+		cursor.LastExportAt = &exportedAt
+		cursor.UpdatedAt = exportedAt
+		err = s.repo.SaveCursor(ctx, cursor)
+		if errors.Is(err, entities.ErrWarehouseCursorMoved) {
+			// The batch was written; the backfill writes it again with the
+			// same IDs from the reset position next run
+			report.Exported += len(events)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save cursor: %w", err)
+		}
+		report.Exported += len(events)
+
+		if len(events) < s.config.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ParseDay parses a YYYY-MM-DD date in UTC
+func (s *Service) ParseDay(value string) (time.Time, error) {
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q is not a YYYY-MM-DD date", entities.ErrInvalidWarehouseBackfill, value)
+	}
+	return day, nil
+}
+
+// Backfill moves the streams' cursors back to from, so the worker exports
+// their events again from that date. Events already in the warehouse are
+// written again with the same IDs and dropped there as duplicates.
+func (s *Service) Backfill(ctx context.Context, streams []entities.WarehouseStream, from time.Time) ([]entities.WarehouseStream, error) {
+	if s.sink == nil {
+		return nil, entities.ErrWarehouseExportUnavailable
+	}
+	if from.After(s.now()) {
+		return nil, fmt.Errorf("%w: from is in the future", entities.ErrInvalidWarehouseBackfill)
+	}
+	if len(streams) == 0 {
+		streams = s.config.Streams
+	}
+	for _, stream := range streams {
+		if !s.exports(stream) {
+			return nil, fmt.Errorf("%w: %q is not an exported stream", entities.ErrInvalidWarehouseBackfill, stream)
+		}
+	}
+
+	if err := s.repo.ResetStreams(ctx, streams, from); err != nil {
+		return nil, fmt.Errorf("failed to reset warehouse cursors: %w", err)
+	}
+	s.logger.Info("Warehouse backfill scheduled",
+		zap.Any("streams", streams),
+		zap.Time("from", from))
+	return streams, nil
+}
+
+func (s *Service) exports(stream entities.WarehouseStream) bool {
+	for _, configured := range s.config.Streams {
+		if configured == stream {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package webhookarchive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+var (
+	// ErrChecksumMismatch is returned when an archived body no longer
+	// matches the checksum recorded when it was received
+	ErrChecksumMismatch = errors.New("archived payload does not match its checksum")
+	// ErrInvalidFilter is returned for a search that cannot be run
+	ErrInvalidFilter = errors.New("invalid webhook archive search")
+)
+
+// Store keeps webhook bodies in cold storage
+type Store interface {
+	// Put stores body under key and returns the URI it can be read from
+	Put(ctx context.Context, key, contentType string, body []byte) (string, error)
+	Retrieve(ctx context.Context, uri string) ([]byte, error)
+}
+
+// Repository stores the searchable metadata of archived webhooks
+type Repository interface {
+	Create(ctx context.Context, webhook *entities.ArchivedWebhook) error
+	// Get returns an archived webhook with its links
+	Get(ctx context.Context, id uuid.UUID) (*entities.ArchivedWebhook, error)
+	Search(ctx context.Context, filter entities.WebhookArchiveFilter) ([]*entities.ArchivedWebhook, error)
+	// ResolveSubjects finds the records a provider's reference identifies,
+	// and the user they belong to
+	ResolveSubjects(ctx context.Context, provider, reference string) ([]entities.WebhookLink, *uuid.UUID, error)
+	AddLinks(ctx context.Context, id uuid.UUID, userID *uuid.UUID, links []entities.WebhookLink) error
+}
+
+// ArchiveRequest is a webhook that passed verification
+type ArchiveRequest struct {
+	Provider       string
+	EventType      string
+	Reference      string
+	ContentType    string
+	Payload        []byte
+	ResponseStatus int
+	ReceivedAt     time.Time
+}
+
+// Config controls where bodies are stored and how searches page
+type Config struct {
+	KeyPrefix       string // Prepended to the keys bodies are stored under
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+// DefaultConfig stores bodies under webhooks/ and pages by 50
+func DefaultConfig() Config {
+	return Config{
+		KeyPrefix:       "webhooks/",
+		DefaultPageSize: 50,
+		MaxPageSize:     200,
+	}
+}
+
+// Service archives raw provider webhooks once they pass verification and
+// links them to the deposits, withdrawals, orders and users they touched,
+// so a dispute can be traced back to exactly what the provider sent
+type Service struct {
+	repo   Repository
+	store  Store
+	config Config
+	logger *zap.Logger
+}
+
+// NewService creates a webhook archive writing bodies to store
+func NewService(repo Repository, store Store, config Config, logger *zap.Logger) *Service {
+	defaults := DefaultConfig()
+	if config.DefaultPageSize <= 0 {
+		config.DefaultPageSize = defaults.DefaultPageSize
+	}
+	if config.MaxPageSize < config.DefaultPageSize {
+		config.MaxPageSize = defaults.MaxPageSize
+	}
+	return &Service{
+		repo:   repo,
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Archive writes the webhook's body to cold storage and records it
+func (s *Service) Archive(ctx context.Context, req *ArchiveRequest) (*entities.ArchivedWebhook, error) {
+	if len(req.Payload) == 0 {
+		return nil, errors.New("webhook payload is empty")
+	}
+	receivedAt := req.ReceivedAt.UTC()
+	if receivedAt.IsZero() {
+		receivedAt = time.Now().UTC()
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	id := uuid.New()
+	key := path.Join(s.config.KeyPrefix, req.Provider, receivedAt.Format("2006/01/02"), id.String()+".json")
+	uri, err := s.store.Put(ctx, key, contentType, req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store webhook payload: %w", err)
+	}
+
+	sum := sha256.Sum256(req.Payload)
+	webhook := &entities.ArchivedWebhook{
+		ID:             id,
+		Provider:       req.Provider,
+		EventType:      req.EventType,
+		Reference:      req.Reference,
+		StorageURI:     uri,
+		SHA256:         hex.EncodeToString(sum[:]),
+		SizeBytes:      len(req.Payload),
+		ContentType:    contentType,
+		ResponseStatus: req.ResponseStatus,
+		ReceivedAt:     receivedAt,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// Link ties the archived webhook to the records its reference identifies.
+// It is called once the webhook has been processed, when those records
+// exist.
+func (s *Service) Link(ctx context.Context, webhook *entities.ArchivedWebhook) error {
+	if webhook.Reference == "" {
+		return nil
+	}
+	links, userID, err := s.repo.ResolveSubjects(ctx, webhook.Provider, webhook.Reference)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 && userID == nil {
+		return nil
+	}
+	if err := s.repo.AddLinks(ctx, webhook.ID, userID, links); err != nil {
+		return err
+	}
+	webhook.Links = links
+	webhook.UserID = userID
+	return nil
+}
+
+// Search returns archived webhooks matching the filter, newest first,
+// without their bodies
+func (s *Service) Search(ctx context.Context, filter entities.WebhookArchiveFilter) ([]*entities.ArchivedWebhook, error) {
+	if (filter.SubjectType == "") != (filter.SubjectID == nil) {
+		return nil, fmt.Errorf("%w: subject_type and subject_id go together", ErrInvalidFilter)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = s.config.DefaultPageSize
+	}
+	if filter.Limit > s.config.MaxPageSize {
+		filter.Limit = s.config.MaxPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.Search(ctx, filter)
+}
+
+// Get returns an archived webhook with its body, read back from cold
+// storage and checked against the checksum taken on receipt
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*entities.ArchivedWebhook, error) {
+	webhook, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.store.Retrieve(ctx, webhook.StorageURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook payload: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != webhook.SHA256 {
+		s.logger.Error("Archived webhook payload changed since receipt",
+			zap.String("webhook_id", id.String()),
+			zap.String("uri", webhook.StorageURI))
+		return nil, ErrChecksumMismatch
+	}
+
+	if json.Valid(body) {
+		webhook.Payload = body
+	} else {
+		// Shown as a JSON string so the response stays valid
+		quoted, _ := json.Marshal(string(body))
+		webhook.Payload = quoted
+	}
+	return webhook, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/queue"
+)
+
+// WithdrawalApprovals queues large withdrawals for admin review
+type WithdrawalApprovals interface {
+	Submit(ctx context.Context, req *entities.SubmitApprovalRequest) (*entities.ApprovalRequest, error)
+}
+
+// SetApprovals holds withdrawals above threshold for maker-checker review.
+// They keep their balance hold while pending_approval and are only processed
+// once approved; a rejection fails them and releases the hold. Register
+// ApprovalHandler for entities.ApprovalActionWithdrawal to act on decisions.
+func (s *WithdrawalService) SetApprovals(approvals WithdrawalApprovals, threshold decimal.Decimal) {
+	s.approvals = approvals
+	s.approvalThreshold = threshold
+}
+
+func (s *WithdrawalService) requiresApproval(amount decimal.Decimal) bool {
+	return s.approvals != nil && amount.GreaterThan(s.approvalThreshold)
+}
+
+// submitForApproval queues a newly created withdrawal for review instead of
+// processing it
+func (s *WithdrawalService) submitForApproval(ctx context.Context, withdrawal *entities.Withdrawal) (*entities.InitiateWithdrawalResponse, error) {
+	_, err := s.approvals.Submit(ctx, &entities.SubmitApprovalRequest{
+		Action:     entities.ApprovalActionWithdrawal,
+		ResourceID: withdrawal.ID.String(),
+		Summary:    fmt.Sprintf("Withdrawal of %s USD to %s", withdrawal.Amount.String(), withdrawal.DestinationChain),
+		Payload: map[string]interface{}{
+			"user_id":             withdrawal.UserID.String(),
+			"amount":              withdrawal.Amount.String(),
+			"destination_chain":   withdrawal.DestinationChain,
+			"destination_address": withdrawal.DestinationAddress,
+		},
+	})
+	if err != nil {
+		s.logger.Error("Failed to submit withdrawal for approval", "error", err, "withdrawal_id", withdrawal.ID.String())
+		_ = s.withdrawalRepo.MarkFailed(ctx, withdrawal.ID, "failed to submit for approval")
+		s.releaseHold(ctx, withdrawal)
+		return nil, fmt.Errorf("failed to submit withdrawal for approval: %w", err)
+	}
+
+	s.logger.Info("Withdrawal awaiting approval",
+		"withdrawal_id", withdrawal.ID.String(),
+		"amount", logger.RedactAmount(withdrawal.Amount))
+
+	return &entities.InitiateWithdrawalResponse{
+		WithdrawalID: withdrawal.ID,
+		Status:       withdrawal.Status,
+		Message:      "Withdrawal is awaiting review",
+	}, nil
+}
+
+// ApprovalHandler returns the handler that processes approved withdrawals and
+// fails rejected ones
+func (s *WithdrawalService) ApprovalHandler() *WithdrawalApprovalHandler {
+	return &WithdrawalApprovalHandler{service: s}
+}
+
+// WithdrawalApprovalHandler acts on review decisions for held withdrawals
+type WithdrawalApprovalHandler struct {
+	service *WithdrawalService
+}
+
+// Approved releases the withdrawal to normal processing
+func (h *WithdrawalApprovalHandler) Approved(ctx context.Context, request *entities.ApprovalRequest) error {
+	s := h.service
+	withdrawal, err := h.awaitingApproval(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if err := s.withdrawalRepo.UpdateStatus(ctx, withdrawal.ID, entities.WithdrawalStatusPending); err != nil {
+		return fmt.Errorf("failed to release withdrawal: %w", err)
+	}
+	msg := queue.WithdrawalMessage{
+		WithdrawalID: withdrawal.ID.String(),
+		Step:         "debit_alpaca",
+	}
+	if err := s.queuePublisher.Publish(ctx, "withdrawal-processing", msg); err != nil {
+		s.logger.Error("Failed to enqueue approved withdrawal", "error", err, "withdrawal_id", withdrawal.ID.String())
+		_ = s.withdrawalRepo.MarkFailed(ctx, withdrawal.ID, "failed to enqueue processing")
+		s.releaseHold(ctx, withdrawal)
+		return fmt.Errorf("failed to enqueue withdrawal: %w", err)
+	}
+
+	s.logger.Info("Approved withdrawal released for processing", "withdrawal_id", withdrawal.ID.String())
+	return nil
+}
+
+// Rejected fails the withdrawal and returns its hold to buying power
+func (h *WithdrawalApprovalHandler) Rejected(ctx context.Context, request *entities.ApprovalRequest) error {
+	s := h.service
+	withdrawal, err := h.awaitingApproval(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if err := s.withdrawalRepo.MarkFailed(ctx, withdrawal.ID, "rejected in review"); err != nil {
+		return fmt.Errorf("failed to fail rejected withdrawal: %w", err)
+	}
+	s.releaseHold(ctx, withdrawal)
+
+	s.logger.Info("Rejected withdrawal failed", "withdrawal_id", withdrawal.ID.String())
+	return nil
+}
+
+func (h *WithdrawalApprovalHandler) awaitingApproval(ctx context.Context, request *entities.ApprovalRequest) (*entities.Withdrawal, error) {
+	id, err := uuid.Parse(request.ResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid withdrawal id %q: %w", request.ResourceID, err)
+	}
+	withdrawal, err := h.service.withdrawalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal: %w", err)
+	}
+	if withdrawal.Status != entities.WithdrawalStatusPendingApproval {
+		return nil, fmt.Errorf("withdrawal is %s, not awaiting approval", withdrawal.Status)
+	}
+	return withdrawal, nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/pkg/circuitbreaker"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
 	"github.com/stack-service/stack_service/pkg/queue"
 )
 
@@ -36,6 +38,25 @@ type WithdrawalService struct {
 	alpacaBreaker         *circuitbreaker.CircuitBreaker
 	dueBreaker            *circuitbreaker.CircuitBreaker
 	queuePublisher        queue.Publisher
+	recipients            RecipientResolver
+	holds                 BalanceHolds
+	approvals             WithdrawalApprovals
+	approvalThreshold     decimal.Decimal
+}
+
+// BalanceHolds holds a withdrawal's amount against buying power until it settles
+type BalanceHolds interface {
+	Place(ctx context.Context, userID uuid.UUID, kind entities.HoldKind, referenceID uuid.UUID, amount decimal.Decimal) (*entities.BalanceHold, error)
+	Capture(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error)
+	Release(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error)
+}
+
+// RecipientResolver looks up saved withdrawal recipients
+type RecipientResolver interface {
+	// ResolveForCryptoWithdrawal returns the crypto recipient if amount may
+	// be sent to it now
+	ResolveForCryptoWithdrawal(ctx context.Context, userID, id uuid.UUID, amount decimal.Decimal) (*entities.WithdrawalRecipient, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) error
 }
 
 // WithdrawalRepository interface for withdrawal persistence
@@ -89,12 +110,18 @@ func NewWithdrawalService(
 	logger *logger.Logger,
 	queuePublisher queue.Publisher,
 ) *WithdrawalService {
-	cfg := circuitbreaker.Config{
-		MaxRequests:      10,
-		Interval:         60 * time.Second,
-		Timeout:          60 * time.Second,
-		FailureThreshold: 5,
-		SuccessThreshold: 2,
+	breakerConfig := func(provider string) circuitbreaker.Config {
+		metrics.SetProviderCircuitState(provider, "withdrawals", circuitbreaker.StateClosed.String())
+		return circuitbreaker.Config{
+			MaxRequests:      10,
+			Interval:         60 * time.Second,
+			Timeout:          60 * time.Second,
+			FailureThreshold: 5,
+			SuccessThreshold: 2,
+			OnStateChange: func(_, to circuitbreaker.State) {
+				metrics.SetProviderCircuitState(provider, "withdrawals", to.String())
+			},
+		}
 	}
 	if queuePublisher == nil {
 		queuePublisher = queue.NewMockPublisher()
@@ -106,17 +133,42 @@ func NewWithdrawalService(
 		allocationService:  allocationService,
 		allocationNotifier: allocationNotifier,
 		logger:             logger,
-		alpacaBreaker:      circuitbreaker.New(cfg),
-		dueBreaker:         circuitbreaker.New(cfg),
+		alpacaBreaker:      circuitbreaker.New(breakerConfig(metrics.ProviderAlpaca)),
+		dueBreaker:         circuitbreaker.New(breakerConfig(metrics.ProviderDue)),
 		queuePublisher:     queuePublisher,
 	}
 }
 
+// SetRecipientResolver lets withdrawals pay out to a saved recipient
+func (s *WithdrawalService) SetRecipientResolver(resolver RecipientResolver) {
+	s.recipients = resolver
+}
+
+// SetBalanceHolds holds each withdrawal's amount against buying power from
+// initiation, so it cannot also be spent on orders or other withdrawals. The
+// hold is captured when the withdrawal completes and released if it fails.
+func (s *WithdrawalService) SetBalanceHolds(holds BalanceHolds) {
+	s.holds = holds
+}
+
 // InitiateWithdrawal initiates a USD to USDC withdrawal
 func (s *WithdrawalService) InitiateWithdrawal(ctx context.Context, req *entities.InitiateWithdrawalRequest) (*entities.InitiateWithdrawalResponse, error) {
+	// A saved recipient replaces the raw destination details
+	if req.RecipientID != nil {
+		if s.recipients == nil {
+			return nil, fmt.Errorf("saved recipients are not available")
+		}
+		recipient, err := s.recipients.ResolveForCryptoWithdrawal(ctx, req.UserID, *req.RecipientID, req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		req.DestinationChain = recipient.Chain
+		req.DestinationAddress = recipient.Address
+	}
+
 	s.logger.Info("Initiating withdrawal",
 		"user_id", req.UserID.String(),
-		"amount", req.Amount.String(),
+		"amount", logger.RedactAmount(req.Amount),
 		"chain", req.DestinationChain,
 		"address", req.DestinationAddress)
 
@@ -131,7 +183,7 @@ func (s *WithdrawalService) InitiateWithdrawal(ctx context.Context, req *entitie
 		if !canSpend {
 			s.logger.Warn("Withdrawal declined - spending limit reached",
 				"user_id", req.UserID.String(),
-				"amount", req.Amount.String())
+				"amount", logger.RedactAmount(req.Amount))
 			
 			// Log declined spending event
 			_ = s.allocationService.LogDeclinedSpending(ctx, req.UserID, req.Amount, "withdrawal")
@@ -173,15 +225,36 @@ func (s *WithdrawalService) InitiateWithdrawal(ctx context.Context, req *entitie
 		Amount:             req.Amount,
 		DestinationChain:   req.DestinationChain,
 		DestinationAddress: req.DestinationAddress,
+		RecipientID:        req.RecipientID,
 		Status:             entities.WithdrawalStatusPending,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
+	if s.requiresApproval(req.Amount) {
+		withdrawal.Status = entities.WithdrawalStatusPendingApproval
+	}
+
+	if s.holds != nil {
+		if _, err := s.holds.Place(ctx, req.UserID, entities.HoldKindWithdrawal, withdrawal.ID, req.Amount); err != nil {
+			s.logger.Warn("Failed to hold withdrawal amount", "error", err, "user_id", req.UserID.String())
+			return nil, fmt.Errorf("failed to hold withdrawal amount: %w", err)
+		}
+	}
 
 	if err := s.withdrawalRepo.Create(ctx, withdrawal); err != nil {
 		s.logger.Error("Failed to create withdrawal record", "error", err, "user_id", req.UserID.String())
+		s.releaseHold(ctx, withdrawal)
 		return nil, fmt.Errorf("failed to create withdrawal record: %w", err)
 	}
+	if withdrawal.RecipientID != nil {
+		if err := s.recipients.MarkUsed(ctx, *withdrawal.RecipientID); err != nil {
+			s.logger.Warn("Failed to mark recipient used", "error", err, "recipient_id", withdrawal.RecipientID.String())
+		}
+	}
+
+	if withdrawal.Status == entities.WithdrawalStatusPendingApproval {
+		return s.submitForApproval(ctx, withdrawal)
+	}
 
 	// Step 4: Enqueue withdrawal processing to SQS
 	msg := queue.WithdrawalMessage{
@@ -191,6 +264,7 @@ func (s *WithdrawalService) InitiateWithdrawal(ctx context.Context, req *entitie
 	if err := s.queuePublisher.Publish(ctx, "withdrawal-processing", msg); err != nil {
 		s.logger.Error("Failed to enqueue withdrawal", "error", err)
 		_ = s.withdrawalRepo.MarkFailed(ctx, withdrawal.ID, "failed to enqueue processing")
+		s.releaseHold(ctx, withdrawal)
 		return nil, fmt.Errorf("failed to enqueue withdrawal: %w", err)
 	}
 
@@ -213,6 +287,7 @@ func (s *WithdrawalService) processWithdrawalAsync(ctx context.Context, withdraw
 	if err := s.debitAlpacaAccount(ctx, withdrawal); err != nil {
 		s.logger.Error("Failed to debit Alpaca account", "error", err, "withdrawal_id", withdrawal.ID.String())
 		_ = s.withdrawalRepo.MarkFailed(ctx, withdrawal.ID, err.Error())
+		s.releaseHold(ctx, withdrawal)
 		return
 	}
 
@@ -223,6 +298,8 @@ func (s *WithdrawalService) processWithdrawalAsync(ctx context.Context, withdraw
 		// Compensation: Credit back Alpaca account
 		if compErr := s.compensateAlpacaDebit(ctx, withdrawal); compErr != nil {
 			s.logger.Error("Compensation failed", "error", compErr, "withdrawal_id", withdrawal.ID.String())
+		} else {
+			s.releaseHold(ctx, withdrawal)
 		}
 		return
 	}
@@ -242,7 +319,7 @@ func (s *WithdrawalService) debitAlpacaAccount(ctx context.Context, withdrawal *
 	s.logger.Info("Debiting Alpaca account",
 		"withdrawal_id", withdrawal.ID.String(),
 		"alpaca_account_id", withdrawal.AlpacaAccountID,
-		"amount", withdrawal.Amount.String())
+		"amount", logger.RedactAmount(withdrawal.Amount))
 
 	// Create journal entry to debit USD from user's account to virtual account
 	journalReq := &entities.AlpacaJournalRequest{
@@ -279,7 +356,7 @@ func (s *WithdrawalService) debitAlpacaAccount(ctx context.Context, withdrawal *
 func (s *WithdrawalService) processDueOnRamp(ctx context.Context, withdrawal *entities.Withdrawal) error {
 	s.logger.Info("Processing Due on-ramp",
 		"withdrawal_id", withdrawal.ID.String(),
-		"amount", withdrawal.Amount.String())
+		"amount", logger.RedactAmount(withdrawal.Amount))
 
 	req := &entities.InitiateWithdrawalRequest{
 		UserID:             withdrawal.UserID,
@@ -354,6 +431,7 @@ func (s *WithdrawalService) monitorTransferCompletion(ctx context.Context, withd
 			if err := s.withdrawalRepo.MarkCompleted(ctx, withdrawal.ID); err != nil {
 				return fmt.Errorf("failed to mark completed: %w", err)
 			}
+			s.captureHold(ctx, withdrawal)
 			return nil
 
 		case "failed":
@@ -411,3 +489,23 @@ func (s *WithdrawalService) compensateAlpacaDebit(ctx context.Context, withdrawa
 
 	return nil
 }
+
+// releaseHold returns a failed withdrawal's held amount to buying power
+func (s *WithdrawalService) releaseHold(ctx context.Context, withdrawal *entities.Withdrawal) {
+	if s.holds == nil {
+		return
+	}
+	if _, err := s.holds.Release(ctx, entities.HoldKindWithdrawal, withdrawal.ID); err != nil && !errors.Is(err, entities.ErrBalanceHoldNotFound) {
+		s.logger.Error("Failed to release withdrawal hold", "error", err, "withdrawal_id", withdrawal.ID.String())
+	}
+}
+
+// captureHold settles a completed withdrawal's held amount as spent
+func (s *WithdrawalService) captureHold(ctx context.Context, withdrawal *entities.Withdrawal) {
+	if s.holds == nil {
+		return
+	}
+	if _, err := s.holds.Capture(ctx, entities.HoldKindWithdrawal, withdrawal.ID); err != nil && !errors.Is(err, entities.ErrBalanceHoldNotFound) {
+		s.logger.Error("Failed to capture withdrawal hold", "error", err, "withdrawal_id", withdrawal.ID.String())
+	}
+}
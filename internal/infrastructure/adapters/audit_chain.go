@@ -0,0 +1,713 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// auditChainLockKey is the advisory lock that serializes appends to the audit hash chain
+const auditChainLockKey = 72_616_001
+
+// auditGenesisHash is the previous hash of the first chained audit entry
+var auditGenesisHash = strings.Repeat("0", 64)
+
+// auditHashVersion is the entry hash scheme new entries are written with.
+// Version 1 entries, written before every column was covered, still verify
+// under the scheme they were written with.
+const auditHashVersion = 2
+
+// AuditAnchorStore persists chain head anchors outside the primary database
+// (e.g. 0G storage) and reads them back for verification
+type AuditAnchorStore interface {
+	StoreAnchor(ctx context.Context, key string, payload []byte) (string, error)
+	LoadAnchor(ctx context.Context, uri string) ([]byte, error)
+}
+
+// AuditChainAnchor records a chain head that was anchored to external storage
+type AuditChainAnchor struct {
+	ID         uuid.UUID `json:"id"`
+	ChainSeq   int64     `json:"chain_seq"`
+	HeadHash   string    `json:"head_hash"`
+	StorageURI *string   `json:"storage_uri,omitempty"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// AuditChainBreak describes a single integrity violation found during verification
+type AuditChainBreak struct {
+	ChainSeq int64     `json:"chain_seq"`
+	EntryID  uuid.UUID `json:"entry_id"`
+	Reason   string    `json:"reason"`
+	Expected string    `json:"expected,omitempty"`
+	Actual   string    `json:"actual,omitempty"`
+}
+
+// AuditChainReport is the result of verifying the audit hash chain
+type AuditChainReport struct {
+	Valid                  bool              `json:"valid"`
+	EntriesChecked         int64             `json:"entries_checked"`
+	HeadSeq                int64             `json:"head_seq"`
+	HeadHash               string            `json:"head_hash"`
+	AnchorsChecked         int               `json:"anchors_checked"`
+	ExternalAnchorsChecked int               `json:"external_anchors_checked"`
+	PrunedThrough          int64             `json:"pruned_through_seq,omitempty"`
+	Breaks                 []AuditChainBreak `json:"breaks,omitempty"`
+	VerifiedAt             time.Time         `json:"verified_at"`
+	VerificationDur        string            `json:"verification_duration"`
+}
+
+// AuditChainEntry is a chained audit row. Version 2 hashes cover every stored
+// column, including the chain position and previous hash.
+type AuditChainEntry struct {
+	ChainSeq     int64
+	PrevHash     string
+	EntryHash    string
+	HashVersion  int
+	ID           uuid.UUID
+	UserID       *uuid.UUID
+	Actor        *string
+	Action       string
+	ResourceType string
+	ResourceID   *string
+	Entity       *string
+	Before       []byte
+	After        []byte
+	Changes      []byte
+	Status       string
+	ErrorMessage *string
+	IPAddress    *string
+	UserAgent    *string
+	Amount       *decimal.Decimal
+	Currency     *string
+	Signature    string
+	At           time.Time
+}
+
+// Link places the entry after the chain head at headSeq with headHash and
+// sets its hash. JSON columns and the IP address are put into the form the
+// database returns them in, so the hash survives the round trip.
+func (e *AuditChainEntry) Link(headSeq int64, headHash string) error {
+	if err := e.normalize(); err != nil {
+		return err
+	}
+	e.ChainSeq = headSeq + 1
+	e.PrevHash = headHash
+	e.HashVersion = auditHashVersion
+	e.EntryHash = e.Hash()
+	return nil
+}
+
+// Hash recomputes the entry hash under the entry's hash version
+func (e *AuditChainEntry) Hash() string {
+	if e.HashVersion <= 1 {
+		return legacyAuditEntryHash(e.PrevHash, e)
+	}
+	return computeAuditEntryHash(e)
+}
+
+func (e *AuditChainEntry) normalize() error {
+	var err error
+	if e.Changes, err = canonicalJSON(e.Changes); err != nil {
+		return fmt.Errorf("failed to canonicalize changes: %w", err)
+	}
+	if e.Before, err = canonicalNullableJSON(e.Before); err != nil {
+		return fmt.Errorf("failed to canonicalize before state: %w", err)
+	}
+	if e.After, err = canonicalNullableJSON(e.After); err != nil {
+		return fmt.Errorf("failed to canonicalize after state: %w", err)
+	}
+	if e.IPAddress != nil {
+		if ip := net.ParseIP(*e.IPAddress); ip != nil {
+			normalized := ip.String()
+			e.IPAddress = &normalized
+		}
+	}
+	e.At = e.At.UTC().Truncate(time.Microsecond)
+	return nil
+}
+
+// SetAnchorStore configures the external store used for chain head anchoring
+func (a *AuditService) SetAnchorStore(store AuditAnchorStore) {
+	a.anchorStore = store
+}
+
+// appendChained inserts an audit entry linked to the current chain head.
+// Appends are serialized with a transaction-scoped advisory lock so sequence numbers never fork.
+func (a *AuditService) appendChained(ctx context.Context, entry AuditChainEntry) error {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var headSeq int64
+	headHash := auditGenesisHash
+	err = tx.QueryRowContext(ctx, `
+		SELECT chain_seq, entry_hash FROM audit_logs
+		WHERE chain_seq IS NOT NULL
+		ORDER BY chain_seq DESC
+		LIMIT 1`).Scan(&headSeq, &headHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	if err := entry.Link(headSeq, headHash); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_logs (
+			id, user_id, actor, action, resource_type, resource_id, entity,
+			before, after, changes, status, error_message, ip_address,
+			user_agent, amount, currency, signature, at,
+			chain_seq, prev_hash, entry_hash, hash_version
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7,
+			$8, $9, $10, $11, $12, $13,
+			$14, $15, $16, $17, $18,
+			$19, $20, $21, $22
+		)`
+
+	_, err = tx.ExecContext(ctx, query,
+		entry.ID, entry.UserID, entry.Actor, entry.Action, entry.ResourceType, entry.ResourceID, entry.Entity,
+		nullableJSON(entry.Before), nullableJSON(entry.After), entry.Changes, entry.Status, entry.ErrorMessage, entry.IPAddress,
+		entry.UserAgent, entry.Amount, entry.Currency, entry.Signature, entry.At,
+		entry.ChainSeq, entry.PrevHash, entry.EntryHash, entry.HashVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert chained audit log: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit log: %w", err)
+	}
+	return nil
+}
+
+// VerifyChain walks the full audit chain and recomputes every entry hash,
+// then checks that each recorded anchor, and the copy of it in the anchor
+// store, still matches the chain.
+func (a *AuditService) VerifyChain(ctx context.Context) (*AuditChainReport, error) {
+	started := time.Now()
+
+	// Entries removed by retention are replaced by a checkpoint holding the hash
+	// of the last pruned entry, so verification resumes from there.
+	checkpointSeq, checkpointHash, err := a.latestPruneCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	verifier := NewAuditChainVerifier(checkpointSeq, checkpointHash)
+
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT chain_seq, prev_hash, entry_hash, hash_version,
+		       id, user_id, actor, action, resource_type, resource_id, entity,
+		       before, after, changes, status, error_message, ip_address,
+		       user_agent, amount, currency, signature, at
+		FROM audit_logs
+		WHERE chain_seq IS NOT NULL
+		ORDER BY chain_seq ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			entry  AuditChainEntry
+			amount decimal.NullDecimal
+		)
+		if err := rows.Scan(&entry.ChainSeq, &entry.PrevHash, &entry.EntryHash, &entry.HashVersion,
+			&entry.ID, &entry.UserID, &entry.Actor, &entry.Action, &entry.ResourceType, &entry.ResourceID, &entry.Entity,
+			&entry.Before, &entry.After, &entry.Changes, &entry.Status, &entry.ErrorMessage, &entry.IPAddress,
+			&entry.UserAgent, &amount, &entry.Currency, &entry.Signature, &entry.At); err != nil {
+			return nil, fmt.Errorf("failed to scan audit chain entry: %w", err)
+		}
+		if amount.Valid {
+			entry.Amount = &amount.Decimal
+		}
+		if err := entry.normalize(); err != nil {
+			return nil, fmt.Errorf("failed to normalize audit chain entry %d: %w", entry.ChainSeq, err)
+		}
+		verifier.Add(&entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit chain: %w", err)
+	}
+
+	anchors, err := a.ListChainAnchors(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, anchor := range anchors {
+		verifier.CheckAnchor(ctx, anchor, a.anchorStore)
+	}
+
+	report := verifier.Report()
+	report.VerifiedAt = time.Now().UTC()
+	report.VerificationDur = time.Since(started).String()
+
+	if !report.Valid {
+		a.logger.Error("Audit chain verification failed",
+			zap.Int("breaks", len(report.Breaks)),
+			zap.Int64("entries_checked", report.EntriesChecked))
+	}
+
+	return report, nil
+}
+
+// AuditChainVerifier checks chained entries fed to it in sequence order, then
+// the anchors taken of the chain
+type AuditChainVerifier struct {
+	report        *AuditChainReport
+	hashes        map[int64]string
+	expectedPrev  string
+	expectedSeq   int64
+	checkpointSeq int64
+}
+
+// NewAuditChainVerifier starts verification after the retention checkpoint
+// at checkpointSeq, or from the genesis hash when checkpointSeq is zero
+func NewAuditChainVerifier(checkpointSeq int64, checkpointHash string) *AuditChainVerifier {
+	v := &AuditChainVerifier{
+		report:       &AuditChainReport{Valid: true, HeadHash: auditGenesisHash},
+		hashes:       make(map[int64]string),
+		expectedPrev: auditGenesisHash,
+		expectedSeq:  1,
+	}
+	if checkpointSeq > 0 {
+		v.expectedPrev = checkpointHash
+		v.expectedSeq = checkpointSeq + 1
+		v.checkpointSeq = checkpointSeq
+		v.report.PrunedThrough = checkpointSeq
+	}
+	return v
+}
+
+// Add checks the next entry's position, link and hash
+func (v *AuditChainVerifier) Add(entry *AuditChainEntry) {
+	seq := entry.ChainSeq
+	if seq != v.expectedSeq {
+		v.report.addBreak(seq, entry.ID, "sequence gap", fmt.Sprintf("%d", v.expectedSeq), fmt.Sprintf("%d", seq))
+	}
+	if entry.PrevHash != v.expectedPrev {
+		v.report.addBreak(seq, entry.ID, "previous hash mismatch", v.expectedPrev, entry.PrevHash)
+	}
+	if recomputed := entry.Hash(); recomputed != entry.EntryHash {
+		v.report.addBreak(seq, entry.ID, "entry hash mismatch", recomputed, entry.EntryHash)
+	}
+
+	v.hashes[seq] = entry.EntryHash
+	v.expectedPrev = entry.EntryHash
+	v.expectedSeq = seq + 1
+	v.report.EntriesChecked++
+	v.report.HeadSeq = seq
+	v.report.HeadHash = entry.EntryHash
+}
+
+// CheckAnchor compares an anchor with the entry it was taken of. When the
+// anchor was mirrored to external storage and store is set, the external
+// copy is read back and compared too, since the database row can be
+// rewritten along with the chain.
+func (v *AuditChainVerifier) CheckAnchor(ctx context.Context, anchor AuditChainAnchor, store AuditAnchorStore) {
+	if anchor.ChainSeq <= v.checkpointSeq {
+		return
+	}
+	v.report.AnchorsChecked++
+	actual, ok := v.hashes[anchor.ChainSeq]
+	if !ok {
+		v.report.addBreak(anchor.ChainSeq, uuid.Nil, "anchored entry missing", anchor.HeadHash, "")
+		return
+	}
+	if actual != anchor.HeadHash {
+		v.report.addBreak(anchor.ChainSeq, uuid.Nil, "anchor hash mismatch", anchor.HeadHash, actual)
+	}
+
+	if anchor.StorageURI == nil || store == nil {
+		return
+	}
+	v.report.ExternalAnchorsChecked++
+	payload, err := store.LoadAnchor(ctx, *anchor.StorageURI)
+	if err != nil {
+		v.report.addBreak(anchor.ChainSeq, uuid.Nil, "external anchor unreadable", *anchor.StorageURI, err.Error())
+		return
+	}
+	var external AuditChainAnchor
+	if err := json.Unmarshal(payload, &external); err != nil {
+		v.report.addBreak(anchor.ChainSeq, uuid.Nil, "external anchor unreadable", *anchor.StorageURI, err.Error())
+		return
+	}
+	if external.ChainSeq != anchor.ChainSeq || external.HeadHash != actual {
+		v.report.addBreak(anchor.ChainSeq, uuid.Nil, "external anchor mismatch",
+			fmt.Sprintf("%d:%s", external.ChainSeq, external.HeadHash), fmt.Sprintf("%d:%s", anchor.ChainSeq, actual))
+	}
+}
+
+// Report returns the verification result so far
+func (v *AuditChainVerifier) Report() *AuditChainReport {
+	return v.report
+}
+
+// AnchorChainHead records the current chain head and, when an anchor store is configured,
+// writes it to external storage so that rewriting the database alone cannot hide tampering.
+func (a *AuditService) AnchorChainHead(ctx context.Context) (*AuditChainAnchor, error) {
+	var seq int64
+	var headHash string
+	err := a.db.QueryRowContext(ctx, `
+		SELECT chain_seq, entry_hash FROM audit_logs
+		WHERE chain_seq IS NOT NULL
+		ORDER BY chain_seq DESC
+		LIMIT 1`).Scan(&seq, &headHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	anchor := &AuditChainAnchor{
+		ID:         uuid.New(),
+		ChainSeq:   seq,
+		HeadHash:   headHash,
+		AnchoredAt: time.Now().UTC(),
+	}
+
+	if a.anchorStore != nil {
+		payload, err := json.Marshal(anchor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal anchor: %w", err)
+		}
+		uri, err := a.anchorStore.StoreAnchor(ctx, fmt.Sprintf("audit-anchors/%020d.json", seq), payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store audit anchor: %w", err)
+		}
+		anchor.StorageURI = &uri
+	} else {
+		a.logger.Warn("Audit anchor store not configured; anchoring chain head in database only")
+	}
+
+	_, err = a.db.ExecContext(ctx, `
+		INSERT INTO audit_chain_anchors (id, chain_seq, head_hash, storage_uri, anchored_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (chain_seq) DO NOTHING`,
+		anchor.ID, anchor.ChainSeq, anchor.HeadHash, anchor.StorageURI, anchor.AnchoredAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record audit anchor: %w", err)
+	}
+
+	// The URI is logged as well so the external copy can be found even if
+	// the anchor row is later deleted
+	a.logger.Info("Audit chain head anchored",
+		zap.Int64("chain_seq", anchor.ChainSeq),
+		zap.String("head_hash", anchor.HeadHash),
+		zap.String("storage_uri", derefString(anchor.StorageURI)))
+
+	return anchor, nil
+}
+
+// ListChainAnchors returns recorded anchors, newest first. A limit of 0 returns all anchors.
+func (a *AuditService) ListChainAnchors(ctx context.Context, limit int) ([]AuditChainAnchor, error) {
+	query := `
+		SELECT id, chain_seq, head_hash, storage_uri, anchored_at
+		FROM audit_chain_anchors
+		ORDER BY chain_seq DESC`
+	args := []interface{}{}
+	if limit > 0 {
+		query += " LIMIT $1"
+		args = append(args, limit)
+	}
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit anchors: %w", err)
+	}
+	defer rows.Close()
+
+	var anchors []AuditChainAnchor
+	for rows.Next() {
+		var anchor AuditChainAnchor
+		if err := rows.Scan(&anchor.ID, &anchor.ChainSeq, &anchor.HeadHash, &anchor.StorageURI, &anchor.AnchoredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit anchor: %w", err)
+		}
+		anchors = append(anchors, anchor)
+	}
+	return anchors, rows.Err()
+}
+
+// StartAnchoring anchors the chain head on a fixed interval until the context is cancelled
+func (a *AuditService) StartAnchoring(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := a.AnchorChainHead(ctx); err != nil {
+					a.logger.Error("Failed to anchor audit chain head", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// PruneChain removes chained entries recorded before the cutoff under the
+// retention policy. Only a contiguous prefix of the chain is removed and the head
+// entry is always kept; a checkpoint with the hash of the last pruned entry lets
+// VerifyChain keep validating the remainder. The database trigger independently
+// refuses to delete entries younger than seven years.
+func (a *AuditService) PruneChain(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin audit prune transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return 0, fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var headSeq, firstKept sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		SELECT MAX(chain_seq),
+		       (SELECT MIN(chain_seq) FROM audit_logs WHERE chain_seq IS NOT NULL AND at >= $1)
+		FROM audit_logs WHERE chain_seq IS NOT NULL`, cutoff).Scan(&headSeq, &firstKept)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find audit prune boundary: %w", err)
+	}
+	if !headSeq.Valid {
+		return 0, nil
+	}
+	pruneThrough := headSeq.Int64 - 1
+	if firstKept.Valid {
+		pruneThrough = firstKept.Int64 - 1
+	}
+
+	var eligible int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM audit_logs
+		WHERE (chain_seq IS NOT NULL AND chain_seq <= $1)
+		   OR (chain_seq IS NULL AND at < $2)`, pruneThrough, cutoff).Scan(&eligible)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count prunable audit entries: %w", err)
+	}
+	if dryRun || eligible == 0 {
+		return eligible, nil
+	}
+
+	if pruneThrough > 0 {
+		var boundaryHash string
+		err = tx.QueryRowContext(ctx, `SELECT entry_hash FROM audit_logs WHERE chain_seq = $1`, pruneThrough).Scan(&boundaryHash)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to read audit prune boundary: %w", err)
+		}
+		if err == nil {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO audit_chain_checkpoints (chain_seq, head_hash, pruned_before)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (chain_seq) DO NOTHING`, pruneThrough, boundaryHash, cutoff); err != nil {
+				return 0, fmt.Errorf("failed to record audit prune checkpoint: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `SET LOCAL stack.audit_retention_purge = 'on'`); err != nil {
+		return 0, fmt.Errorf("failed to enable audit retention purge: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM audit_logs
+		WHERE (chain_seq IS NOT NULL AND chain_seq <= $1)
+		   OR (chain_seq IS NULL AND at < $2)`, pruneThrough, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit entries: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit audit prune: %w", err)
+	}
+
+	a.logger.Info("Pruned audit chain entries past retention",
+		zap.Int64("pruned_through_seq", pruneThrough),
+		zap.Int64("deleted", deleted))
+
+	return deleted, nil
+}
+
+// latestPruneCheckpoint returns the newest retention checkpoint, or zero when the chain is intact
+func (a *AuditService) latestPruneCheckpoint(ctx context.Context) (int64, string, error) {
+	var seq int64
+	var hash string
+	err := a.db.QueryRowContext(ctx, `
+		SELECT chain_seq, head_hash FROM audit_chain_checkpoints
+		ORDER BY chain_seq DESC
+		LIMIT 1`).Scan(&seq, &hash)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read audit prune checkpoint: %w", err)
+	}
+	return seq, hash, nil
+}
+
+func (r *AuditChainReport) addBreak(seq int64, id uuid.UUID, reason, expected, actual string) {
+	r.Valid = false
+	r.Breaks = append(r.Breaks, AuditChainBreak{
+		ChainSeq: seq,
+		EntryID:  id,
+		Reason:   reason,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// computeAuditEntryHash hashes every stored column of the entry. Each field
+// is written as its length and value, with NULL distinct from empty, so no
+// two different rows encode to the same input.
+func computeAuditEntryHash(entry *AuditChainEntry) string {
+	var userID, amount *string
+	if entry.UserID != nil {
+		value := entry.UserID.String()
+		userID = &value
+	}
+	if entry.Amount != nil {
+		value := entry.Amount.String()
+		amount = &value
+	}
+	id := entry.ID.String()
+	seq := strconv.FormatInt(entry.ChainSeq, 10)
+	at := entry.At.UTC().Format(time.RFC3339Nano)
+
+	var buf bytes.Buffer
+	for _, field := range []*string{
+		stringPtr("v2"),
+		&entry.PrevHash,
+		&seq,
+		&id,
+		userID,
+		entry.Actor,
+		&entry.Action,
+		&entry.ResourceType,
+		entry.ResourceID,
+		entry.Entity,
+		bytesPtr(entry.Before),
+		bytesPtr(entry.After),
+		bytesPtr(entry.Changes),
+		&entry.Status,
+		entry.ErrorMessage,
+		entry.IPAddress,
+		entry.UserAgent,
+		amount,
+		entry.Currency,
+		&entry.Signature,
+		&at,
+	} {
+		if field == nil {
+			buf.WriteString("-;")
+			continue
+		}
+		buf.WriteString(strconv.Itoa(len(*field)))
+		buf.WriteByte(':')
+		buf.WriteString(*field)
+		buf.WriteByte(';')
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// legacyAuditEntryHash is the version 1 hash, kept to verify entries written
+// before every column was covered
+func legacyAuditEntryHash(prevHash string, entry *AuditChainEntry) string {
+	userID := ""
+	if entry.UserID != nil {
+		userID = entry.UserID.String()
+	}
+	amount := ""
+	if entry.Amount != nil {
+		amount = entry.Amount.String()
+	}
+
+	payload := strings.Join([]string{
+		prevHash,
+		entry.ID.String(),
+		userID,
+		entry.Action,
+		entry.ResourceType,
+		derefString(entry.ResourceID),
+		string(entry.Changes),
+		entry.Status,
+		amount,
+		derefString(entry.Currency),
+		entry.At.UTC().Format(time.RFC3339Nano),
+	}, "|")
+
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON re-encodes JSON with sorted object keys so the hash is stable across JSONB round-trips
+func canonicalJSON(data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []byte("{}"), nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// canonicalNullableJSON is canonicalJSON for nullable columns, keeping NULL as nil
+func canonicalNullableJSON(data []byte) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+	return canonicalJSON(data)
+}
+
+func nullableJSON(data []byte) interface{} {
+	if data == nil {
+		return nil
+	}
+	return data
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func bytesPtr(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
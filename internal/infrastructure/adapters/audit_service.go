@@ -36,9 +36,10 @@ type AuditLog struct {
 
 // AuditService implements the audit service interface with database persistence
 type AuditService struct {
-	db        *sql.DB
-	logger    *zap.Logger
-	secretKey string
+	db          *sql.DB
+	logger      *zap.Logger
+	secretKey   string
+	anchorStore AuditAnchorStore
 }
 
 // NewAuditService creates a new audit service
@@ -185,26 +186,30 @@ func (a *AuditService) generateSignature(log AuditLog) (string, error) {
 	return signature, nil
 }
 
-// insertAuditLog inserts the audit log into the database
+// insertAuditLog appends the audit log to the hash chain
 func (a *AuditService) insertAuditLog(ctx context.Context, log AuditLog) error {
 	changesJSON, err := json.Marshal(log.Changes)
 	if err != nil {
 		return fmt.Errorf("failed to marshal changes: %w", err)
 	}
 
-	query := `
-		INSERT INTO audit_logs (
-			id, user_id, actor, action, resource_type, resource_id, changes, 
-			status, error_message, ip_address, user_agent, amount, currency, 
-			signature, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
-
-	_, err = a.db.ExecContext(ctx, query,
-		log.ID, log.UserID, log.Actor, log.Action, log.ResourceType, log.ResourceID,
-		changesJSON, log.Status, log.ErrorMessage, log.IPAddress, log.UserAgent,
-		log.Amount, log.Currency, log.Signature, log.CreatedAt,
-	)
-
+	err = a.appendChained(ctx, AuditChainEntry{
+		ID:           log.ID,
+		UserID:       log.UserID,
+		Actor:        &log.Actor,
+		Action:       log.Action,
+		ResourceType: log.ResourceType,
+		ResourceID:   log.ResourceID,
+		Changes:      changesJSON,
+		Status:       log.Status,
+		ErrorMessage: log.ErrorMessage,
+		IPAddress:    log.IPAddress,
+		UserAgent:    log.UserAgent,
+		Amount:       log.Amount,
+		Currency:     log.Currency,
+		Signature:    log.Signature,
+		At:           log.CreatedAt,
+	})
 	if err != nil {
 		a.logger.Error("Failed to insert audit log",
 			zap.Error(err),
@@ -238,12 +243,12 @@ func (a *AuditService) VerifyLogIntegrity(log AuditLog) bool {
 // GetUserAuditTrail retrieves audit trail for a specific user
 func (a *AuditService) GetUserAuditTrail(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]AuditLog, error) {
 	query := `
-		SELECT id, user_id, actor, action, resource_type, resource_id, changes,
+		SELECT id, user_id, COALESCE(actor, ''), action, resource_type, resource_id, changes,
 			   status, error_message, ip_address, user_agent, amount, currency,
-			   signature, created_at
+			   signature, at
 		FROM audit_logs 
 		WHERE user_id = $1 
-		ORDER BY created_at DESC 
+		ORDER BY at DESC 
 		LIMIT $2 OFFSET $3`
 
 	rows, err := a.db.QueryContext(ctx, query, userID, limit, offset)
@@ -336,29 +341,6 @@ func (a *AuditService) logEventWithNullableUser(
 		}
 	}
 
-	// Insert audit log
-	query := `
-		INSERT INTO audit_logs (
-			id,
-			user_id,
-			action,
-			resource_type,
-			resource_id,
-			entity,
-			before,
-			after,
-			changes,
-			status,
-			error_message,
-			ip_address,
-			user_agent,
-			at
-		) VALUES (
-			$1, $2, $3, $4, $5,
-			$6, $7, $8, $9, $10,
-			$11, $12, $13, $14
-		)`
-
 	auditID := uuid.New()
 	var beforeJSON []byte
 	if before != nil {
@@ -380,32 +362,23 @@ func (a *AuditService) logEventWithNullableUser(
 		}
 	}
 
-	var beforeParam interface{}
-	if beforeJSON != nil {
-		beforeParam = beforeJSON
-	}
-
-	var afterParam interface{}
-	if afterJSON != nil {
-		afterParam = afterJSON
-	}
-
-	_, err = a.db.ExecContext(ctx, query,
-		auditID,
-		userID, // This can be nil
-		fmt.Sprintf("%s:%s", actor, action), // Combine actor and action
-		entity,
-		resourceID,
-		entity,
-		beforeParam,
-		afterParam,
-		changesJSON,
-		status,
-		errorMsg,
-		ipAddress,
-		userAgent,
-		time.Now().UTC(),
-	)
+	err = a.appendChained(ctx, AuditChainEntry{
+		ID:           auditID,
+		UserID:       userID,
+		Actor:        &actor,
+		Action:       fmt.Sprintf("%s:%s", actor, action), // Combine actor and action
+		ResourceType: entity,
+		ResourceID:   resourceID,
+		Entity:       &entity,
+		Before:       beforeJSON,
+		After:        afterJSON,
+		Changes:      changesJSON,
+		Status:       status,
+		ErrorMessage: errorMsg,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		At:           time.Now().UTC(),
+	})
 
 	if err != nil {
 		userIDStr := "nil"
@@ -483,29 +456,6 @@ func (a *AuditService) logEventWithResource(
 		}
 	}
 
-	// Insert audit log
-	query := `
-		INSERT INTO audit_logs (
-			id,
-			user_id,
-			action,
-			resource_type,
-			resource_id,
-			entity,
-			before,
-			after,
-			changes,
-			status,
-			error_message,
-			ip_address,
-			user_agent,
-			at
-		) VALUES (
-			$1, $2, $3, $4, $5,
-			$6, $7, $8, $9, $10,
-			$11, $12, $13, $14
-		)`
-
 	auditID := uuid.New()
 	var beforeJSON []byte
 	if before != nil {
@@ -527,32 +477,23 @@ func (a *AuditService) logEventWithResource(
 		}
 	}
 
-	var beforeParam interface{}
-	if beforeJSON != nil {
-		beforeParam = beforeJSON
-	}
-
-	var afterParam interface{}
-	if afterJSON != nil {
-		afterParam = afterJSON
-	}
-
-	_, err = a.db.ExecContext(ctx, query,
-		auditID,
-		&userID,
-		fmt.Sprintf("%s:%s", actor, action), // Combine actor and action
-		entity,
-		resourceID,
-		entity,
-		beforeParam,
-		afterParam,
-		changesJSON,
-		status,
-		errorMsg,
-		ipAddress,
-		userAgent,
-		time.Now().UTC(),
-	)
+	err = a.appendChained(ctx, AuditChainEntry{
+		ID:           auditID,
+		UserID:       &userID,
+		Actor:        &actor,
+		Action:       fmt.Sprintf("%s:%s", actor, action), // Combine actor and action
+		ResourceType: entity,
+		ResourceID:   resourceID,
+		Entity:       &entity,
+		Before:       beforeJSON,
+		After:        afterJSON,
+		Changes:      changesJSON,
+		Status:       status,
+		ErrorMessage: errorMsg,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		At:           time.Now().UTC(),
+	})
 
 	if err != nil {
 		a.logger.Error("Failed to persist audit log",
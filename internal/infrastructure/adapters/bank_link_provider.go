@@ -0,0 +1,116 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// BankLinkConfig holds bank-linking provider configuration
+type BankLinkConfig struct {
+	BaseURL  string
+	ClientID string
+	Secret   string
+	Timeout  time.Duration
+}
+
+// BankLinkClient looks up bank accounts with a Plaid-style account
+// verification API: given a routing and account number it reports whether
+// the account exists, whether it is open, and the names of its owners.
+type BankLinkClient struct {
+	config     BankLinkConfig
+	httpClient *http.Client
+}
+
+// NewBankLinkClient creates a new bank-linking provider client
+func NewBankLinkClient(config BankLinkConfig) *BankLinkClient {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &BankLinkClient{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+type bankLinkVerifyRequest struct {
+	ClientID      string `json:"client_id"`
+	Secret        string `json:"secret"`
+	RoutingNumber string `json:"routing_number"`
+	AccountNumber string `json:"account_number"`
+}
+
+type bankLinkVerifyResponse struct {
+	RequestID string `json:"request_id"`
+	Account   *struct {
+		Status string `json:"status"` // "open" or "closed"
+		Owners []struct {
+			Names []string `json:"names"`
+		} `json:"owners"`
+	} `json:"account"`
+	Error *struct {
+		Code    string `json:"error_code"`
+		Message string `json:"error_message"`
+	} `json:"error"`
+}
+
+// CheckAccount looks up an account. An account the provider does not know
+// is reported as not found rather than as an error.
+func (c *BankLinkClient) CheckAccount(ctx context.Context, routingNumber, accountNumber string) (*entities.BankAccountCheck, error) {
+	payload, err := json.Marshal(bankLinkVerifyRequest{
+		ClientID:      c.config.ClientID,
+		Secret:        c.config.Secret,
+		RoutingNumber: routingNumber,
+		AccountNumber: accountNumber,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode account check request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(c.config.BaseURL, "/")+"/accounts/verify", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build account check request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("account check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account check response: %w", err)
+	}
+	var decoded bankLinkVerifyResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode account check response (status %d): %w", resp.StatusCode, err)
+	}
+
+	check := &entities.BankAccountCheck{Reference: decoded.RequestID}
+	if decoded.Error != nil && decoded.Error.Code == "ACCOUNT_NOT_FOUND" {
+		return check, nil
+	}
+	if resp.StatusCode != http.StatusOK || decoded.Account == nil {
+		if decoded.Error != nil {
+			return nil, fmt.Errorf("account check returned %s: %s", decoded.Error.Code, decoded.Error.Message)
+		}
+		return nil, fmt.Errorf("account check returned status %d", resp.StatusCode)
+	}
+
+	check.Found = true
+	check.Open = decoded.Account.Status == "open"
+	for _, owner := range decoded.Account.Owners {
+		check.OwnerNames = append(check.OwnerNames, owner.Names...)
+	}
+	return check, nil
+}
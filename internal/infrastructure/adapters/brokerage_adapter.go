@@ -3,12 +3,16 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/adapters/alpaca"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/attribution"
 	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/marketcalendar"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -35,7 +39,7 @@ func (a *BrokerageAdapter) PlaceOrder(ctx context.Context, basketID uuid.UUID, s
 	a.logger.Info("PlaceOrder called",
 		zap.String("basket_id", basketID.String()),
 		zap.String("side", string(side)),
-		zap.String("amount", amount.String()),
+		logger.Amount("amount", amount),
 	)
 
 	// For now, return a placeholder response
@@ -81,3 +85,137 @@ func (a *BrokerageAdapter) CancelOrder(ctx context.Context, brokerageRef string)
 	// TODO: Implement proper order cancellation logic
 	return nil
 }
+
+// GetQuotes prices symbols from Alpaca's latest quotes: buys at the ask and
+// sells at the bid, falling back to the other side when one is empty
+func (a *BrokerageAdapter) GetQuotes(ctx context.Context, symbols []string, side entities.OrderSide) (map[string]investing.Quote, error) {
+	latest, err := a.alpacaClient.GetLatestQuotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]investing.Quote, len(latest))
+	for symbol, quote := range latest {
+		price, fallback := quote.AskPrice, quote.BidPrice
+		if side == entities.OrderSideSell {
+			price, fallback = quote.BidPrice, quote.AskPrice
+		}
+		if !price.IsPositive() {
+			price = fallback
+		}
+		quotes[symbol] = investing.Quote{Price: price, QuotedAt: quote.Timestamp}
+	}
+	return quotes, nil
+}
+
+// LatestQuotes returns Alpaca's latest NBBO quote for each symbol
+func (a *BrokerageAdapter) LatestQuotes(ctx context.Context, symbols []string) (map[string]*entities.MarketQuote, error) {
+	latest, err := a.alpacaClient.GetLatestQuotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]*entities.MarketQuote, len(latest))
+	for symbol, quote := range latest {
+		quotes[symbol] = &entities.MarketQuote{
+			Symbol:    symbol,
+			BidPrice:  quote.BidPrice,
+			BidSize:   quote.BidSize,
+			AskPrice:  quote.AskPrice,
+			AskSize:   quote.AskSize,
+			Timestamp: quote.Timestamp,
+		}
+	}
+	return quotes, nil
+}
+
+// priceLookback is how far before a period GetPriceChanges looks for a close,
+// so a period starting on a weekend or holiday uses the previous session
+const priceLookback = 7 * 24 * time.Hour
+
+// GetPriceChanges prices symbols from Alpaca's daily bars: the last close at
+// or before start and the last close up to end
+func (a *BrokerageAdapter) GetPriceChanges(ctx context.Context, symbols []string, start, end time.Time) (map[string]attribution.PriceChange, error) {
+	bars, err := a.alpacaClient.GetDailyBars(ctx, symbols, start.Add(-priceLookback), end)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]attribution.PriceChange, len(bars))
+	for symbol, symbolBars := range bars {
+		if len(symbolBars) == 0 {
+			continue
+		}
+		change := attribution.PriceChange{
+			Start: symbolBars[0].Close,
+			End:   symbolBars[len(symbolBars)-1].Close,
+		}
+		for _, bar := range symbolBars {
+			if bar.Timestamp.After(start) {
+				break
+			}
+			change.Start = bar.Close
+		}
+		changes[symbol] = change
+	}
+	return changes, nil
+}
+
+// GetSessions lists trading sessions from Alpaca's market calendar
+func (a *BrokerageAdapter) GetSessions(ctx context.Context, start, end time.Time) ([]entities.MarketSession, error) {
+	days, err := a.alpacaClient.GetCalendar(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]entities.MarketSession, 0, len(days))
+	for _, day := range days {
+		session, err := marketcalendar.NewSession(day.Date, day.Open, day.Close)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// maxNewsPage is the most stories Alpaca returns per news request
+const maxNewsPage = 50
+
+// GetNews returns Alpaca news since the given time, newest first, about any
+// of the symbols, or general market news when there are none
+func (a *BrokerageAdapter) GetNews(ctx context.Context, symbols []string, since time.Time, limit int) ([]entities.NewsArticle, error) {
+	if limit <= 0 || limit > maxNewsPage {
+		limit = maxNewsPage
+	}
+	resp, err := a.alpacaClient.GetNews(ctx, &entities.AlpacaNewsRequest{
+		Symbols: symbols,
+		Start:   &since,
+		Limit:   limit,
+		Sort:    "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	articles := make([]entities.NewsArticle, 0, len(resp.News))
+	for _, story := range resp.News {
+		article := entities.NewsArticle{
+			ID:          fmt.Sprintf("alpaca:%d", story.ID),
+			Headline:    story.Headline,
+			Summary:     story.Summary,
+			Source:      story.Source,
+			Author:      story.Author,
+			URL:         story.URL,
+			Symbols:     story.Symbols,
+			PublishedAt: story.CreatedAt,
+		}
+		for _, image := range story.Images {
+			if image.Size == "small" || (image.Size == "thumb" && article.ImageURL == "") {
+				article.ImageURL = image.URL
+			}
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -11,8 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
@@ -41,20 +44,43 @@ type EmailServiceConfig struct {
 	Environment string // "development", "staging", "production"
 	BaseURL     string // For verification links
 	ReplyTo     string
+	// Fallbacks are tried in order when the primary provider fails
+	Fallbacks []EmailProviderConfig
+	Failover  FailoverConfig
+	// CaptureOnly allows no provider at all; messages go to the store set
+	// with SetCapture
+	CaptureOnly bool
+}
+
+// EmailProviderConfig is a fallback email provider
+type EmailProviderConfig struct {
+	Provider string // "sendgrid", "resend"
+	APIKey   string
 }
 
 // EmailService implements the email service interface
 type EmailService struct {
-	logger     *zap.Logger
-	config     EmailServiceConfig
+	logger    *zap.Logger
+	config    EmailServiceConfig
+	providers []*emailProvider
+	pool      *providerPool
+	capture   MessageCapture
+}
+
+// emailProvider is one configured provider account
+type emailProvider struct {
+	name       string
+	apiKey     string
 	client     *sendgrid.Client
 	httpClient *http.Client
 }
 
 // NewEmailService creates a new email service
 func NewEmailService(logger *zap.Logger, config EmailServiceConfig) (*EmailService, error) {
-	provider := strings.ToLower(strings.TrimSpace(config.Provider))
-	if provider == "" {
+	configs := config.Fallbacks
+	if strings.TrimSpace(config.Provider) != "" {
+		configs = append([]EmailProviderConfig{{Provider: config.Provider, APIKey: config.APIKey}}, configs...)
+	} else if !config.CaptureOnly {
 		return nil, fmt.Errorf("email provider is required")
 	}
 
@@ -62,54 +88,193 @@ func NewEmailService(logger *zap.Logger, config EmailServiceConfig) (*EmailServi
 		return nil, fmt.Errorf("email from address is required")
 	}
 
-	var (
-		client     *sendgrid.Client
-		httpClient *http.Client
-	)
+	providers := make([]*emailProvider, 0, len(configs))
+	names := make([]string, 0, len(configs))
+	for _, providerConfig := range configs {
+		provider, err := newEmailProvider(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+		names = append(names, provider.name)
+	}
 
-	switch provider {
+	return &EmailService{
+		logger:    logger,
+		config:    config,
+		providers: providers,
+		pool:      newProviderPool(entities.ChannelEmail, names, config.Failover),
+	}, nil
+}
+
+func newEmailProvider(config EmailProviderConfig) (*emailProvider, error) {
+	provider := &emailProvider{
+		name:   strings.ToLower(strings.TrimSpace(config.Provider)),
+		apiKey: config.APIKey,
+	}
+
+	switch provider.name {
 	case "sendgrid":
 		if strings.TrimSpace(config.APIKey) == "" {
 			return nil, fmt.Errorf("sendgrid api key is required")
 		}
-		client = sendgrid.NewSendClient(config.APIKey)
+		provider.client = sendgrid.NewSendClient(config.APIKey)
 	case "resend":
 		if strings.TrimSpace(config.APIKey) == "" {
 			return nil, fmt.Errorf("resend api key is required")
 		}
-		httpClient = &http.Client{Timeout: 30 * time.Second}
+		provider.httpClient = &http.Client{Timeout: 30 * time.Second}
 	default:
-		return nil, fmt.Errorf("unsupported email provider: %s", provider)
+		return nil, fmt.Errorf("unsupported email provider: %s", provider.name)
 	}
+	return provider, nil
+}
 
-	return &EmailService{
-		logger:     logger,
-		config:     config,
-		client:     client,
-		httpClient: httpClient,
-	}, nil
+// SetCapture switches the service to sandbox capture: messages are stored
+// instead of sent, so non-production environments never reach real users
+func (e *EmailService) SetCapture(capture MessageCapture) {
+	e.capture = capture
 }
 
-// sendEmail is a helper method to send emails via the configured provider
-func (e *EmailService) sendEmail(ctx context.Context, to, subject, htmlContent, textContent string) error {
-	provider := strings.ToLower(e.config.Provider)
+// ProviderStatuses reports the failover state of each configured provider
+func (e *EmailService) ProviderStatuses() []entities.MessagingProviderStatus {
+	return e.pool.statuses()
+}
+
+// SetProviderEnabled takes a provider out of the failover rotation or puts it
+// back
+func (e *EmailService) SetProviderEnabled(name string, enabled bool) error {
+	return e.pool.setEnabled(name, enabled)
+}
+
+// Ping checks that a provider's API is reachable. Client errors such as a
+// send-only key being refused the endpoint still count as reachable. With
+// fallbacks configured, one reachable provider is enough; under sandbox
+// capture no provider is used.
+func (e *EmailService) Ping(ctx context.Context) error {
+	if e.capture != nil {
+		return nil
+	}
+
+	var errs []error
+	for _, provider := range e.providers {
+		err := provider.ping(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.name, err))
+	}
+	return errors.Join(errs...)
+}
+
+func (p *emailProvider) ping(ctx context.Context) error {
+	var endpoint string
+	switch p.name {
+	case "resend":
+		endpoint = resendAPIBaseURL + "/domains"
+	case "sendgrid":
+		endpoint = "https://api.sendgrid.com/v3/scopes"
+	default:
+		return fmt.Errorf("unsupported email provider: %s", p.name)
+	}
 
-	// Add timeout to context
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	switch provider {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build email provider request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	client := p.httpClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("email provider unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("email provider unavailable: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sendEmail sends through the first provider that accepts the message,
+// failing over down the configured list, or captures it in sandbox mode
+func (e *EmailService) sendEmail(ctx context.Context, to, subject, htmlContent, textContent string) error {
+	if e.capture != nil {
+		return e.captureEmail(ctx, to, subject, htmlContent, textContent)
+	}
+
+	var lastErr error
+	for _, i := range e.pool.order() {
+		provider := e.providers[i]
+
+		// Each attempt gets its own timeout so a hung provider leaves time
+		// for the next one
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := e.sendVia(ctxWithTimeout, provider, to, subject, htmlContent, textContent)
+		cancel()
+		if err == nil {
+			e.pool.succeeded(i)
+			return nil
+		}
+
+		lastErr = err
+		if e.pool.failed(i, err) {
+			e.logger.Warn("Email provider marked unhealthy",
+				zap.String("provider", e.pool.name(i)),
+				zap.Error(err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return fmt.Errorf("no email provider configured")
+	}
+	return lastErr
+}
+
+func (e *EmailService) sendVia(ctx context.Context, provider *emailProvider, to, subject, htmlContent, textContent string) error {
+	switch provider.name {
 	case "resend":
-		return e.sendViaResend(ctxWithTimeout, to, subject, htmlContent, textContent)
+		return e.sendViaResend(ctx, provider, to, subject, htmlContent, textContent)
 	case "sendgrid":
-		return e.sendViaSendgrid(ctxWithTimeout, to, subject, htmlContent, textContent)
+		return e.sendViaSendgrid(ctx, provider, to, subject, htmlContent, textContent)
 	default:
-		return fmt.Errorf("unsupported email provider: %s", provider)
+		return fmt.Errorf("unsupported email provider: %s", provider.name)
 	}
 }
 
-func (e *EmailService) sendViaSendgrid(ctx context.Context, to, subject, htmlContent, textContent string) error {
-	if e.client == nil {
+func (e *EmailService) captureEmail(ctx context.Context, to, subject, htmlContent, textContent string) error {
+	message := &entities.CapturedMessage{
+		ID:          uuid.New(),
+		Channel:     entities.ChannelEmail,
+		Recipient:   to,
+		Subject:     subject,
+		TextBody:    textContent,
+		HTMLBody:    htmlContent,
+		Environment: e.config.Environment,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := e.capture.CaptureMessage(ctx, message); err != nil {
+		return fmt.Errorf("failed to capture email: %w", err)
+	}
+
+	e.logger.Info("Email captured instead of sent",
+		logger.Email(to),
+		zap.String("subject", subject),
+		zap.String("capture_id", message.ID.String()))
+	return nil
+}
+
+func (e *EmailService) sendViaSendgrid(ctx context.Context, provider *emailProvider, to, subject, htmlContent, textContent string) error {
+	if provider.client == nil {
 		return fmt.Errorf("sendgrid client not configured")
 	}
 
@@ -121,11 +286,11 @@ func (e *EmailService) sendViaSendgrid(ctx context.Context, to, subject, htmlCon
 		message.SetReplyTo(mail.NewEmail(e.config.FromName, e.config.ReplyTo))
 	}
 
-	response, err := e.client.SendWithContext(ctx, message)
+	response, err := provider.client.SendWithContext(ctx, message)
 	if err != nil {
 		e.logger.Error("Failed to send email",
 			zap.String("provider", "sendgrid"),
-			zap.String("to", to),
+			logger.EmailKey("to", to),
 			zap.String("subject", subject),
 			zap.Error(err))
 		return fmt.Errorf("failed to send email: %w", err)
@@ -134,7 +299,7 @@ func (e *EmailService) sendViaSendgrid(ctx context.Context, to, subject, htmlCon
 	if response.StatusCode >= 400 {
 		e.logger.Error("Email service returned error",
 			zap.String("provider", "sendgrid"),
-			zap.String("to", to),
+			logger.EmailKey("to", to),
 			zap.String("subject", subject),
 			zap.Int("status_code", response.StatusCode),
 			zap.String("response_body", response.Body))
@@ -143,15 +308,15 @@ func (e *EmailService) sendViaSendgrid(ctx context.Context, to, subject, htmlCon
 
 	e.logger.Info("Email sent successfully",
 		zap.String("provider", "sendgrid"),
-		zap.String("to", to),
+		logger.EmailKey("to", to),
 		zap.String("subject", subject),
 		zap.Int("status_code", response.StatusCode))
 
 	return nil
 }
 
-func (e *EmailService) sendViaResend(ctx context.Context, to, subject, htmlContent, textContent string) error {
-	if e.httpClient == nil {
+func (e *EmailService) sendViaResend(ctx context.Context, provider *emailProvider, to, subject, htmlContent, textContent string) error {
+	if provider.httpClient == nil {
 		return fmt.Errorf("resend client not configured")
 	}
 
@@ -213,13 +378,13 @@ func (e *EmailService) sendViaResend(ctx context.Context, to, subject, htmlConte
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+e.config.APIKey)
+	req.Header.Set("Authorization", "Bearer "+provider.apiKey)
 
-	resp, err := e.httpClient.Do(req)
+	resp, err := provider.httpClient.Do(req)
 	if err != nil {
 		e.logger.Error("Failed to send email via Resend",
 			zap.String("provider", "resend"),
-			zap.String("to", to),
+			logger.EmailKey("to", to),
 			zap.String("subject", subject),
 			zap.Error(err))
 		return fmt.Errorf("resend send request failed: %w", err)
@@ -230,7 +395,7 @@ func (e *EmailService) sendViaResend(ctx context.Context, to, subject, htmlConte
 	if resp.StatusCode >= 400 {
 		logFields := []zap.Field{
 			zap.String("provider", "resend"),
-			zap.String("to", to),
+			logger.EmailKey("to", to),
 			zap.String("subject", subject),
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("environment", e.config.Environment),
@@ -248,7 +413,7 @@ func (e *EmailService) sendViaResend(ctx context.Context, to, subject, htmlConte
 
 	e.logger.Info("Email sent successfully",
 		zap.String("provider", "resend"),
-		zap.String("to", to),
+		logger.EmailKey("to", to),
 		zap.String("subject", subject),
 		zap.Int("status_code", resp.StatusCode))
 
@@ -267,8 +432,8 @@ func isNonProductionEnv(env string) bool {
 // SendVerificationEmail sends an email verification message
 func (e *EmailService) SendVerificationEmail(ctx context.Context, email, verificationToken string) error {
 	e.logger.Info("Sending verification email",
-		zap.String("email", email),
-		zap.String("token", verificationToken))
+		logger.Email(email),
+		logger.Token("token", verificationToken))
 
 	verificationURL := fmt.Sprintf("%s/verify-email?token=%s", e.config.BaseURL, verificationToken)
 
@@ -326,7 +491,7 @@ The Stack Service Team
 // SendKYCStatusEmail sends a KYC status update email
 func (e *EmailService) SendKYCStatusEmail(ctx context.Context, email string, status entities.KYCStatus, rejectionReasons []string) error {
 	e.logger.Info("Sending KYC status email",
-		zap.String("email", email),
+		logger.Email(email),
 		zap.String("status", string(status)),
 		zap.Strings("rejection_reasons", rejectionReasons))
 
@@ -360,7 +525,7 @@ func (e *EmailService) SendKYCStatusEmail(ctx context.Context, email string, sta
 // SendWelcomeEmail sends a welcome email to a new user
 func (e *EmailService) SendWelcomeEmail(ctx context.Context, email string) error {
 	e.logger.Info("Sending welcome email",
-		zap.String("email", email))
+		logger.Email(email))
 
 	subject := "🎉 Welcome to Stack Service!"
 
@@ -486,7 +651,7 @@ If this wasn't you, please reset your password immediately and contact support.
 `, strings.TrimSpace(details.IP), forwarded, location, userAgent, loginTime)
 
 	e.logger.Info("Sending login alert email",
-		zap.String("email", email),
+		logger.Email(email),
 		zap.String("ip", strings.TrimSpace(details.IP)))
 
 	return e.sendEmail(ctx, email, subject, htmlContent, textContent)
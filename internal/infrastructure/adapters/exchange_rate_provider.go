@@ -0,0 +1,134 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ExchangeRateProviderConfig holds historical rate provider configuration
+type ExchangeRateProviderConfig struct {
+	APIKey  string
+	BaseURL string
+	Timeout time.Duration
+}
+
+const defaultExchangeRateBaseURL = "https://api.coingecko.com/api/v3"
+
+// coinGeckoIDs maps the currencies we hold to CoinGecko coin IDs
+var coinGeckoIDs = map[string]string{
+	"USDC":  "usd-coin",
+	"EURC":  "euro-coin",
+	"USDT":  "tether",
+	"BTC":   "bitcoin",
+	"ETH":   "ethereum",
+	"SOL":   "solana",
+	"MATIC": "matic-network",
+	"AVAX":  "avalanche-2",
+}
+
+// ExchangeRateProvider serves historical crypto prices from the CoinGecko
+// market chart API. Pairs are quoted in a fiat currency, e.g. USDC/USD.
+type ExchangeRateProvider struct {
+	logger     *zap.Logger
+	config     ExchangeRateProviderConfig
+	httpClient *http.Client
+}
+
+// NewExchangeRateProvider creates a new historical rate provider
+func NewExchangeRateProvider(logger *zap.Logger, config ExchangeRateProviderConfig) *ExchangeRateProvider {
+	if strings.TrimSpace(config.BaseURL) == "" {
+		config.BaseURL = defaultExchangeRateBaseURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &ExchangeRateProvider{
+		logger:     logger,
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+type coinGeckoMarketChart struct {
+	Prices [][2]json.Number `json:"prices"`
+}
+
+// History returns the prices observed for the pair between from and to. The
+// provider returns hourly points for ranges up to 90 days and daily points
+// beyond that.
+func (p *ExchangeRateProvider) History(ctx context.Context, pair entities.CurrencyPair, from, to time.Time) ([]*entities.ExchangeRate, error) {
+	coinID, ok := coinGeckoIDs[pair.Base]
+	if !ok {
+		return nil, fmt.Errorf("%w: no price history for %s", entities.ErrInvalidCurrencyPair, pair.Base)
+	}
+
+	query := url.Values{}
+	query.Set("vs_currency", strings.ToLower(pair.Quote))
+	query.Set("from", strconv.FormatInt(from.Unix(), 10))
+	query.Set("to", strconv.FormatInt(to.Unix(), 10))
+	endpoint := fmt.Sprintf("%s/coins/%s/market_chart/range?%s",
+		strings.TrimRight(p.config.BaseURL, "/"), url.PathEscape(coinID), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate history request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.config.APIKey != "" {
+		req.Header.Set("x-cg-pro-api-key", p.config.APIKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rate history request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate history response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rate history returned status %d", resp.StatusCode)
+	}
+
+	var chart coinGeckoMarketChart
+	if err := json.Unmarshal(body, &chart); err != nil {
+		return nil, fmt.Errorf("failed to decode rate history response: %w", err)
+	}
+
+	rates := make([]*entities.ExchangeRate, 0, len(chart.Prices))
+	for _, point := range chart.Prices {
+		millis, err := point[0].Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate history timestamp %q", point[0])
+		}
+		rate, err := decimal.NewFromString(point[1].String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate history price %q", point[1])
+		}
+		rates = append(rates, &entities.ExchangeRate{
+			Pair:       pair,
+			Rate:       rate,
+			ObservedAt: time.UnixMilli(millis).UTC(),
+		})
+	}
+
+	p.logger.Debug("Fetched rate history",
+		zap.String("pair", pair.String()),
+		zap.Int("points", len(rates)))
+	return rates, nil
+}
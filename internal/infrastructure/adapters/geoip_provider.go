@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// GeoIPProviderConfig holds geo-IP provider configuration
+type GeoIPProviderConfig struct {
+	APIKey     string
+	BaseURL    string
+	Strictness int // 0-3; higher catches more proxies at the cost of false positives
+	Timeout    time.Duration
+}
+
+const defaultGeoIPBaseURL = "https://ipqualityscore.com/api/json/ip"
+
+// GeoIPProvider looks up IP addresses with the IPQualityScore proxy detection API
+type GeoIPProvider struct {
+	logger     *zap.Logger
+	config     GeoIPProviderConfig
+	httpClient *http.Client
+}
+
+// NewGeoIPProvider creates a new geo-IP provider
+func NewGeoIPProvider(logger *zap.Logger, config GeoIPProviderConfig) (*GeoIPProvider, error) {
+	if strings.TrimSpace(config.APIKey) == "" {
+		return nil, fmt.Errorf("geoip api key is required")
+	}
+	if strings.TrimSpace(config.BaseURL) == "" {
+		config.BaseURL = defaultGeoIPBaseURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+
+	return &GeoIPProvider{
+		logger:     logger,
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+type ipqsLookupResponse struct {
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	FraudScore     int    `json:"fraud_score"`
+	CountryCode    string `json:"country_code"`
+	Region         string `json:"region"`
+	City           string `json:"city"`
+	ISP            string `json:"ISP"`
+	Proxy          bool   `json:"proxy"`
+	VPN            bool   `json:"vpn"`
+	Tor            bool   `json:"tor"`
+	ActiveVPN      bool   `json:"active_vpn"`
+	ActiveTor      bool   `json:"active_tor"`
+	ConnectionType string `json:"connection_type"`
+}
+
+// Lookup returns the location and anonymity of an IP address
+func (p *GeoIPProvider) Lookup(ctx context.Context, ip string) (*entities.GeoIPResult, error) {
+	endpoint := fmt.Sprintf("%s/%s/%s", strings.TrimRight(p.config.BaseURL, "/"),
+		url.PathEscape(p.config.APIKey), url.PathEscape(ip))
+	query := url.Values{}
+	query.Set("strictness", strconv.Itoa(p.config.Strictness))
+	query.Set("allow_public_access_points", "true")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create geoip request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		// The key is part of the URL, so the error is not wrapped verbatim
+		return nil, fmt.Errorf("geoip request failed: %s", redactKey(err.Error(), p.config.APIKey))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geoip response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geoip lookup returned status %d", resp.StatusCode)
+	}
+
+	var result ipqsLookupResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode geoip response: %w", err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("geoip lookup failed: %s", result.Message)
+	}
+
+	return &entities.GeoIPResult{
+		IP:          ip,
+		CountryCode: result.CountryCode,
+		Region:      result.Region,
+		City:        result.City,
+		ISP:         result.ISP,
+		IsVPN:       result.VPN || result.ActiveVPN,
+		IsProxy:     result.Proxy,
+		IsTor:       result.Tor || result.ActiveTor,
+		IsHosting:   strings.EqualFold(result.ConnectionType, "Data Center"),
+		RiskScore:   result.FraudScore,
+	}, nil
+}
+
+func redactKey(message, key string) string {
+	if key == "" {
+		return message
+	}
+	return strings.ReplaceAll(message, url.PathEscape(key), "[REDACTED]")
+}
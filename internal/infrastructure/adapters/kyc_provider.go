@@ -22,6 +22,7 @@ import (
 	"golang.org/x/text/language"
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
 )
 
 // KYCProviderConfig holds KYC provider configuration
@@ -34,6 +35,9 @@ type KYCProviderConfig struct {
 	CallbackURL string
 	UserAgent   string
 	LevelName   string
+	// EDDLevelName is the Sumsub level applicants are moved to for enhanced
+	// due diligence; empty leaves enhanced checks to manual review
+	EDDLevelName string
 }
 
 const (
@@ -84,7 +88,9 @@ func NewKYCProvider(logger *zap.Logger, config KYCProviderConfig) (*KYCProvider,
 		return nil, fmt.Errorf("unsupported kyc provider: %s", provider)
 	}
 
-	httpClient := &http.Client{Timeout: 30 * time.Second}
+	// Vendor calls are labelled with the vendor so each one's SLA is
+	// tracked; document downloads are from our own storage and are not
+	httpClient := &http.Client{Timeout: 30 * time.Second, Transport: metrics.InstrumentProvider(provider, nil)}
 	fileClient := &http.Client{Timeout: 30 * time.Second}
 
 	return &KYCProvider{
@@ -183,6 +189,17 @@ type sumsubStatusResponse struct {
 	} `json:"reviewResult"`
 }
 
+// sumsubDocStatus is one entry of the requiredIdDocsStatus response, keyed by
+// document set (IDENTITY, SELFIE, ...)
+type sumsubDocStatus struct {
+	IDDocType    string `json:"idDocType"`
+	ReviewResult *struct {
+		ReviewAnswer      string   `json:"reviewAnswer"`
+		ModerationComment string   `json:"moderationComment"`
+		RejectLabels      []string `json:"rejectLabels"`
+	} `json:"reviewResult"`
+}
+
 type sumsubAccessTokenResponse struct {
 	Token string `json:"token"`
 }
@@ -774,6 +791,130 @@ func (k *KYCProvider) getSumsubKYCStatus(ctx context.Context, providerRef string
 	return submission, nil
 }
 
+// GetDocumentReviews returns the provider's verdict on each of documents.
+// Only Sumsub reports per-document results; Jumio reviews the workflow as a
+// whole and returns entities.ErrPartialKYCResubmissionUnsupported.
+func (k *KYCProvider) GetDocumentReviews(ctx context.Context, providerRef string, documents []entities.KYCDocument) ([]entities.KYCDocumentReview, error) {
+	if k.provider != "sumsub" {
+		return nil, entities.ErrPartialKYCResubmissionUnsupported
+	}
+
+	endpoint := fmt.Sprintf("/resources/applicants/%s/requiredIdDocsStatus", providerRef)
+	resp, err := k.makeSumsubRequest(ctx, http.MethodGet, endpoint, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sumsub document status response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		k.logger.Error("Sumsub document status request failed",
+			zap.String("provider_ref", providerRef),
+			zap.Int("status_code", resp.StatusCode),
+			zap.String("response_body", string(respBody)))
+		return nil, fmt.Errorf("sumsub document status request failed: status %d", resp.StatusCode)
+	}
+
+	var docSets map[string]*sumsubDocStatus
+	if err := json.Unmarshal(respBody, &docSets); err != nil {
+		return nil, fmt.Errorf("failed to parse sumsub document status response: %w", err)
+	}
+
+	byDocType := make(map[string]*sumsubDocStatus, len(docSets))
+	for _, docSet := range docSets {
+		if docSet != nil && docSet.ReviewResult != nil {
+			byDocType[strings.ToUpper(docSet.IDDocType)] = docSet
+		}
+	}
+
+	reviews := make([]entities.KYCDocumentReview, 0, len(documents))
+	for _, doc := range documents {
+		docSet, ok := byDocType[mapSumsubDocType(doc.Type)]
+		if !ok {
+			continue
+		}
+		review := entities.KYCDocumentReview{
+			Type:     doc.Type,
+			Accepted: !strings.EqualFold(docSet.ReviewResult.ReviewAnswer, "RED"),
+		}
+		if !review.Accepted {
+			review.Reason = docSet.ReviewResult.ModerationComment
+			if review.Reason == "" {
+				review.Reason = strings.Join(docSet.ReviewResult.RejectLabels, ", ")
+			}
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// ResubmitDocuments uploads replacements for rejected documents to an
+// existing Sumsub applicant and asks for the applicant to be checked again.
+// Jumio cannot amend a finished workflow and returns
+// entities.ErrPartialKYCResubmissionUnsupported.
+func (k *KYCProvider) ResubmitDocuments(ctx context.Context, providerRef string, documents []entities.KYCDocumentUpload, personalInfo *entities.KYCPersonalInfo) error {
+	if k.provider != "sumsub" {
+		return entities.ErrPartialKYCResubmissionUnsupported
+	}
+
+	k.logger.Info("Resubmitting KYC documents",
+		zap.String("provider_ref", providerRef),
+		zap.Int("document_count", len(documents)))
+
+	for _, doc := range documents {
+		if err := k.uploadSumsubDocument(ctx, providerRef, doc, personalInfo); err != nil {
+			return err
+		}
+	}
+
+	return k.markSumsubApplicantPending(ctx, providerRef)
+}
+
+// SubmitEnhancedDueDiligence moves a verified Sumsub applicant to the
+// enhanced due diligence level and uploads the proof-of-funds documents for
+// review. Jumio has no equivalent step and returns
+// entities.ErrEDDProviderUnsupported, as does Sumsub without an EDD level.
+func (k *KYCProvider) SubmitEnhancedDueDiligence(ctx context.Context, providerRef string, documents []entities.KYCDocumentUpload) error {
+	levelName := strings.TrimSpace(k.config.EDDLevelName)
+	if k.provider != "sumsub" || levelName == "" {
+		return entities.ErrEDDProviderUnsupported
+	}
+
+	k.logger.Info("Submitting enhanced due diligence",
+		zap.String("provider_ref", providerRef),
+		zap.String("level_name", levelName),
+		zap.Int("document_count", len(documents)))
+
+	endpoint := fmt.Sprintf("/resources/applicants/%s/moveToLevel?name=%s", providerRef, url.QueryEscape(levelName))
+	resp, err := k.makeSumsubRequest(ctx, http.MethodPost, endpoint, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		k.logger.Error("Failed to move Sumsub applicant to EDD level",
+			zap.String("provider_ref", providerRef),
+			zap.Int("status_code", resp.StatusCode),
+			zap.String("response_body", string(respBody)))
+		return fmt.Errorf("sumsub level change failed: status %d", resp.StatusCode)
+	}
+
+	for _, doc := range documents {
+		if err := k.uploadSumsubDocument(ctx, providerRef, doc, nil); err != nil {
+			return err
+		}
+	}
+
+	return k.markSumsubApplicantPending(ctx, providerRef)
+}
+
 // GenerateKYCURL generates a URL for users to complete KYC verification
 func (k *KYCProvider) GenerateKYCURL(ctx context.Context, userID uuid.UUID) (string, error) {
 	k.logger.Info("Generating KYC URL",
@@ -933,6 +1074,8 @@ func mapSumsubDocType(docType string) string {
 		return "DRIVERS_LICENSE"
 	case "id_card", "national_id":
 		return "ID_CARD"
+	case entities.EDDDocumentProofOfFunds, entities.EDDDocumentBankStatement, entities.EDDDocumentSourceOfWealth:
+		return "INCOME_SOURCE"
 	default:
 		return "OTHER"
 	}
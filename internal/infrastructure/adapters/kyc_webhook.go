@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"strings"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// MapKYCWebhook maps a KYC provider webhook onto our statuses. Sumsub sends
+// an event type with every step of an applicant's review, and a review
+// answer with the decision: RED with a RETRY reject type lets the applicant
+// fix their documents, while FINAL does not. Other providers post a plain
+// status, which may also name one of our sub-statuses. Anything unrecognized
+// is treated as a review still in progress.
+func MapKYCWebhook(payload map[string]interface{}) entities.KYCProviderUpdate {
+	update := entities.KYCProviderUpdate{Status: entities.KYCStatusProcessing}
+	update.Event, _ = payload["type"].(string)
+
+	review := sumsubReviewResult(payload)
+	if answer, ok := review["reviewAnswer"].(string); ok {
+		switch strings.ToUpper(strings.TrimSpace(answer)) {
+		case "GREEN":
+			update.Status = entities.KYCStatusApproved
+			return update
+		case "RED":
+			update.Status = entities.KYCStatusRejected
+			update.RejectionReasons = sumsubRejectLabels(review)
+			if rejectType, _ := review["reviewRejectType"].(string); strings.EqualFold(rejectType, "RETRY") {
+				update.SubStatus = entities.KYCSubStatusResubmissionRequired
+			}
+			return update
+		}
+	}
+
+	switch update.Event {
+	case "applicantCreated", "applicantReset", "applicantActionPending":
+		update.SubStatus = entities.KYCSubStatusDocumentsRequested
+		return update
+	case "applicantPending", "applicantPrechecked":
+		update.SubStatus = entities.KYCSubStatusUnderReview
+		return update
+	case "applicantOnHold":
+		update.SubStatus = entities.KYCSubStatusOnHold
+		return update
+	}
+
+	if reviewStatus, ok := payload["reviewStatus"].(string); ok {
+		switch strings.ToLower(reviewStatus) {
+		case "init":
+			update.SubStatus = entities.KYCSubStatusDocumentsRequested
+		case "pending", "queued", "prechecked":
+			update.SubStatus = entities.KYCSubStatusUnderReview
+		case "onhold":
+			update.SubStatus = entities.KYCSubStatusOnHold
+		}
+		if update.SubStatus != entities.KYCSubStatusNone {
+			return update
+		}
+	}
+
+	status, _ := payload["status"].(string)
+	switch strings.ToLower(status) {
+	case "approved", "passed":
+		update.Status = entities.KYCStatusApproved
+	case "rejected", "failed":
+		update.Status = entities.KYCStatusRejected
+		update.RejectionReasons = stringList(payload["rejection_reasons"])
+	case "resubmission_required":
+		update.Status = entities.KYCStatusRejected
+		update.SubStatus = entities.KYCSubStatusResubmissionRequired
+		update.RejectionReasons = stringList(payload["rejection_reasons"])
+	case "documents_requested":
+		update.SubStatus = entities.KYCSubStatusDocumentsRequested
+	case "on_hold":
+		update.SubStatus = entities.KYCSubStatusOnHold
+	case "under_review", "processing", "pending":
+		update.SubStatus = entities.KYCSubStatusUnderReview
+	}
+	return update
+}
+
+// sumsubReviewResult finds the review result at the top level or inside a
+// payload envelope
+func sumsubReviewResult(payload map[string]interface{}) map[string]interface{} {
+	if review, ok := payload["reviewResult"].(map[string]interface{}); ok {
+		return review
+	}
+	if inner, ok := payload["payload"].(map[string]interface{}); ok {
+		if review, ok := inner["reviewResult"].(map[string]interface{}); ok {
+			return review
+		}
+	}
+	return nil
+}
+
+// sumsubRejectLabels prefers the moderation comment shown to the applicant,
+// then the labels' descriptions or codes
+func sumsubRejectLabels(review map[string]interface{}) []string {
+	var reasons []string
+	if comment, ok := review["moderationComment"].(string); ok && strings.TrimSpace(comment) != "" {
+		reasons = append(reasons, strings.TrimSpace(comment))
+	}
+	labels, _ := review["rejectLabels"].([]interface{})
+	for _, label := range labels {
+		switch v := label.(type) {
+		case map[string]interface{}:
+			if desc, ok := v["description"].(string); ok && desc != "" {
+				reasons = append(reasons, desc)
+			} else if code, ok := v["code"].(string); ok && code != "" {
+				reasons = append(reasons, code)
+			}
+		case string:
+			if strings.TrimSpace(v) != "" {
+				reasons = append(reasons, strings.TrimSpace(v))
+			}
+		}
+	}
+	return reasons
+}
+
+func stringList(raw interface{}) []string {
+	items, _ := raw.([]interface{})
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
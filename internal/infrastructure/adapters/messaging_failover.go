@@ -0,0 +1,182 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// MessageCapture stores outbound messages in place of sending them
+type MessageCapture interface {
+	CaptureMessage(ctx context.Context, message *entities.CapturedMessage) error
+}
+
+// FailoverConfig sets when an email or SMS provider is skipped
+type FailoverConfig struct {
+	FailureThreshold int           // Consecutive failed sends before a provider is skipped
+	Cooldown         time.Duration // How long a failing provider is skipped before it is tried again
+}
+
+func (c FailoverConfig) withDefaults() FailoverConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 3
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = 5 * time.Minute
+	}
+	return c
+}
+
+// providerPool picks the order a channel's providers are tried in. Healthy
+// providers go first in configured order; providers in their cooldown are
+// still tried last, so a send is only refused once every provider has failed.
+type providerPool struct {
+	mu      sync.Mutex
+	channel entities.NotificationChannel
+	config  FailoverConfig
+	states  []*providerState
+	now     func() time.Time
+}
+
+type providerState struct {
+	name           string
+	enabled        bool
+	failures       int
+	lastError      string
+	lastFailureAt  time.Time
+	unhealthyUntil time.Time
+}
+
+// newProviderPool tracks providers by name. A provider configured more than
+// once, such as a second Twilio account, is numbered from its second entry.
+func newProviderPool(channel entities.NotificationChannel, providers []string, config FailoverConfig) *providerPool {
+	seen := make(map[string]int)
+	states := make([]*providerState, 0, len(providers))
+	for _, provider := range providers {
+		seen[provider]++
+		name := provider
+		if seen[provider] > 1 {
+			name = fmt.Sprintf("%s-%d", provider, seen[provider])
+		}
+		states = append(states, &providerState{name: name, enabled: true})
+	}
+	return &providerPool{
+		channel: channel,
+		config:  config.withDefaults(),
+		states:  states,
+		now:     time.Now,
+	}
+}
+
+// order returns the indexes of enabled providers in the order to try them
+func (p *providerPool) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	healthy := make([]int, 0, len(p.states))
+	var cooling []int
+	for i, state := range p.states {
+		if !state.enabled {
+			continue
+		}
+		if now.Before(state.unhealthyUntil) {
+			cooling = append(cooling, i)
+			continue
+		}
+		healthy = append(healthy, i)
+	}
+	return append(healthy, cooling...)
+}
+
+func (p *providerPool) name(i int) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.states[i].name
+}
+
+func (p *providerPool) succeeded(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.states[i]
+	state.failures = 0
+	state.unhealthyUntil = time.Time{}
+}
+
+// failed records a failed send and reports whether it tipped the provider
+// into its cooldown
+func (p *providerPool) failed(i int, err error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	state := p.states[i]
+	state.failures++
+	state.lastError = err.Error()
+	state.lastFailureAt = p.now()
+	if state.failures >= p.config.FailureThreshold {
+		wasHealthy := !state.lastFailureAt.Before(state.unhealthyUntil)
+		state.unhealthyUntil = state.lastFailureAt.Add(p.config.Cooldown)
+		return wasHealthy
+	}
+	return false
+}
+
+func (p *providerPool) statuses() []entities.MessagingProviderStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	statuses := make([]entities.MessagingProviderStatus, 0, len(p.states))
+	for i, state := range p.states {
+		status := entities.MessagingProviderStatus{
+			Channel:             p.channel,
+			Provider:            state.name,
+			Priority:            i,
+			Enabled:             state.enabled,
+			Healthy:             !now.Before(state.unhealthyUntil),
+			ConsecutiveFailures: state.failures,
+			LastError:           state.lastError,
+		}
+		if !state.lastFailureAt.IsZero() {
+			lastFailureAt := state.lastFailureAt
+			status.LastFailureAt = &lastFailureAt
+		}
+		if !status.Healthy {
+			unhealthyUntil := state.unhealthyUntil
+			status.UnhealthyUntil = &unhealthyUntil
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// setEnabled takes a provider out of rotation or puts it back. Re-enabling
+// clears its failure history so it is tried in its configured position.
+func (p *providerPool) setEnabled(name string, enabled bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var target *providerState
+	remaining := 0
+	for _, state := range p.states {
+		if state.name == name {
+			target = state
+		} else if state.enabled {
+			remaining++
+		}
+	}
+	if target == nil {
+		return entities.ErrMessagingProviderNotFound
+	}
+	if !enabled && remaining == 0 {
+		return entities.ErrLastMessagingProvider
+	}
+	if enabled && !target.enabled {
+		target.failures = 0
+		target.unhealthyUntil = time.Time{}
+	}
+	target.enabled = enabled
+	return nil
+}
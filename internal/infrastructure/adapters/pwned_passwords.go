@@ -0,0 +1,84 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPwnedPasswordsBaseURL = "https://api.pwnedpasswords.com"
+
+// PwnedPasswordsConfig holds breached-password range API configuration
+type PwnedPasswordsConfig struct {
+	BaseURL string
+	Timeout time.Duration
+}
+
+// PwnedPasswordsClient checks passwords against the Have I Been Pwned range
+// API. Only the first five hex characters of the password's SHA-1 leave the
+// service (k-anonymity); the match against the returned suffixes is local,
+// and responses are padded so their size gives nothing away either.
+type PwnedPasswordsClient struct {
+	config     PwnedPasswordsConfig
+	httpClient *http.Client
+}
+
+// NewPwnedPasswordsClient creates a new breached-password client
+func NewPwnedPasswordsClient(config PwnedPasswordsConfig) *PwnedPasswordsClient {
+	if strings.TrimSpace(config.BaseURL) == "" {
+		config.BaseURL = defaultPwnedPasswordsBaseURL
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+	return &PwnedPasswordsClient{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// BreachCount returns how many times the password appears in known breaches
+func (p *PwnedPasswordsClient) BreachCount(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.config.BaseURL, "/")+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build breached password request: %w", err)
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("breached password lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("breached password lookup returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		// Padding entries have a count of zero
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected breached password count %q", count)
+		}
+		return n, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read breached password response: %w", err)
+	}
+	return 0, nil
+}
@@ -2,6 +2,8 @@ package adapters
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,54 +11,140 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+const (
+	twilioAPIBaseURL = "https://api.twilio.com"
+	vonageAPIBaseURL = "https://rest.nexmo.com"
 )
 
 // SMSConfig holds SMS service configuration
 type SMSConfig struct {
-	Provider    string // "twilio"
+	Provider    string // "twilio", "vonage"
 	APIKey      string
 	APISecret   string
 	FromNumber  string
+	BaseURL     string // Overrides the API base URL, e.g. a Twilio regional edge
 	Environment string // "development", "staging", "production"
+	// Fallbacks are tried in order when the primary provider fails
+	Fallbacks []SMSProviderConfig
+	Failover  FailoverConfig
+	// CaptureOnly allows no provider at all; messages go to the store set
+	// with SetCapture
+	CaptureOnly bool
+}
+
+// SMSProviderConfig is one SMS provider account. For Twilio the key and
+// secret are the account SID and auth token.
+type SMSProviderConfig struct {
+	Provider   string
+	APIKey     string
+	APISecret  string
+	FromNumber string
+	BaseURL    string // Overrides the API base URL, e.g. a Twilio regional edge
 }
 
 // SMSService implements SMS delivery interface
 type SMSService struct {
 	logger     *zap.Logger
 	config     SMSConfig
+	providers  []SMSProviderConfig
+	pool       *providerPool
+	capture    MessageCapture
 	httpClient *http.Client
 }
 
 // NewSMSService creates a new SMS service
 func NewSMSService(logger *zap.Logger, config SMSConfig) (*SMSService, error) {
-	provider := strings.ToLower(strings.TrimSpace(config.Provider))
-	if provider == "" {
+	configs := config.Fallbacks
+	if strings.TrimSpace(config.Provider) != "" {
+		configs = append([]SMSProviderConfig{{
+			Provider:   config.Provider,
+			APIKey:     config.APIKey,
+			APISecret:  config.APISecret,
+			FromNumber: config.FromNumber,
+			BaseURL:    config.BaseURL,
+		}}, configs...)
+	} else if !config.CaptureOnly {
 		return nil, fmt.Errorf("sms provider is required")
 	}
 
-	switch provider {
-	case "twilio":
-		if strings.TrimSpace(config.APIKey) == "" {
-			return nil, fmt.Errorf("twilio account sid is required")
-		}
-		if strings.TrimSpace(config.APISecret) == "" {
-			return nil, fmt.Errorf("twilio auth token is required")
+	providers := make([]SMSProviderConfig, 0, len(configs))
+	names := make([]string, 0, len(configs))
+	for _, provider := range configs {
+		provider.Provider = strings.ToLower(strings.TrimSpace(provider.Provider))
+		if err := validateSMSProvider(provider); err != nil {
+			return nil, err
 		}
-		if strings.TrimSpace(config.FromNumber) == "" {
-			return nil, fmt.Errorf("twilio from number is required")
-		}
-	default:
-		return nil, fmt.Errorf("unsupported sms provider: %s", provider)
+		providers = append(providers, provider)
+		names = append(names, provider.Provider)
 	}
 
 	return &SMSService{
 		logger:     logger,
 		config:     config,
+		providers:  providers,
+		pool:       newProviderPool(entities.ChannelSMS, names, config.Failover),
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}, nil
 }
 
+func validateSMSProvider(provider SMSProviderConfig) error {
+	switch provider.Provider {
+	case "twilio":
+		if strings.TrimSpace(provider.APIKey) == "" {
+			return fmt.Errorf("twilio account sid is required")
+		}
+		if strings.TrimSpace(provider.APISecret) == "" {
+			return fmt.Errorf("twilio auth token is required")
+		}
+	case "vonage":
+		if strings.TrimSpace(provider.APIKey) == "" {
+			return fmt.Errorf("vonage api key is required")
+		}
+		if strings.TrimSpace(provider.APISecret) == "" {
+			return fmt.Errorf("vonage api secret is required")
+		}
+	default:
+		return fmt.Errorf("unsupported sms provider: %s", provider.Provider)
+	}
+	if strings.TrimSpace(provider.FromNumber) == "" {
+		return fmt.Errorf("%s from number is required", provider.Provider)
+	}
+	return nil
+}
+
+func (p SMSProviderConfig) baseURL() string {
+	if p.BaseURL != "" {
+		return strings.TrimRight(p.BaseURL, "/")
+	}
+	if p.Provider == "vonage" {
+		return vonageAPIBaseURL
+	}
+	return twilioAPIBaseURL
+}
+
+// SetCapture switches the service to sandbox capture: messages are stored
+// instead of sent, so non-production environments never text real users
+func (s *SMSService) SetCapture(capture MessageCapture) {
+	s.capture = capture
+}
+
+// ProviderStatuses reports the failover state of each configured provider
+func (s *SMSService) ProviderStatuses() []entities.MessagingProviderStatus {
+	return s.pool.statuses()
+}
+
+// SetProviderEnabled takes a provider out of the failover rotation or puts it
+// back
+func (s *SMSService) SetProviderEnabled(name string, enabled bool) error {
+	return s.pool.setEnabled(name, enabled)
+}
+
 // SendVerificationSMS sends a verification code via SMS
 func (s *SMSService) SendVerificationSMS(ctx context.Context, phone, code string) error {
 	s.logger.Info("Sending verification SMS",
@@ -164,29 +252,77 @@ func (s *SMSService) maskPhone(phone string) string {
 	return phone[:3] + "****" + phone[len(phone)-3:]
 }
 
+// sendSMS sends through the first provider that accepts the message,
+// failing over down the configured list, or captures it in sandbox mode
 func (s *SMSService) sendSMS(ctx context.Context, phone, message string) error {
-	provider := strings.ToLower(strings.TrimSpace(s.config.Provider))
-	switch provider {
-	case "twilio":
-		return s.sendTwilioSMS(ctx, phone, message)
-	default:
-		return fmt.Errorf("unsupported sms provider: %s", provider)
-	}
-}
-
-func (s *SMSService) sendTwilioSMS(ctx context.Context, phone, message string) error {
-	accountSID := strings.TrimSpace(s.config.APIKey)
-	authToken := strings.TrimSpace(s.config.APISecret)
 	normalized := s.NormalizePhoneNumber(phone)
-
 	if err := s.ValidatePhoneNumber(normalized); err != nil {
 		return fmt.Errorf("invalid phone number: %w", err)
 	}
+	if s.capture != nil {
+		return s.captureSMS(ctx, normalized, message)
+	}
+
+	var lastErr error
+	for _, i := range s.pool.order() {
+		provider := s.providers[i]
+		var err error
+		switch provider.Provider {
+		case "twilio":
+			err = s.sendTwilioSMS(ctx, provider, normalized, message)
+		case "vonage":
+			err = s.sendVonageSMS(ctx, provider, normalized, message)
+		default:
+			err = fmt.Errorf("unsupported sms provider: %s", provider.Provider)
+		}
+		if err == nil {
+			s.pool.succeeded(i)
+			return nil
+		}
+
+		lastErr = err
+		if s.pool.failed(i, err) {
+			s.logger.Warn("SMS provider marked unhealthy",
+				zap.String("provider", s.pool.name(i)),
+				zap.Error(err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		return fmt.Errorf("no sms provider configured")
+	}
+	return lastErr
+}
+
+func (s *SMSService) captureSMS(ctx context.Context, phone, message string) error {
+	captured := &entities.CapturedMessage{
+		ID:          uuid.New(),
+		Channel:     entities.ChannelSMS,
+		Recipient:   phone,
+		TextBody:    message,
+		Environment: s.config.Environment,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.capture.CaptureMessage(ctx, captured); err != nil {
+		return fmt.Errorf("failed to capture sms: %w", err)
+	}
+
+	s.logger.Info("SMS captured instead of sent",
+		zap.String("to", s.maskPhone(phone)),
+		zap.String("capture_id", captured.ID.String()))
+	return nil
+}
 
-	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", accountSID)
+func (s *SMSService) sendTwilioSMS(ctx context.Context, provider SMSProviderConfig, normalized, message string) error {
+	accountSID := strings.TrimSpace(provider.APIKey)
+	authToken := strings.TrimSpace(provider.APISecret)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", provider.baseURL(), accountSID)
 	form := url.Values{}
 	form.Set("To", normalized)
-	form.Set("From", s.config.FromNumber)
+	form.Set("From", provider.FromNumber)
 	form.Set("Body", message)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
@@ -221,24 +357,101 @@ func (s *SMSService) sendTwilioSMS(ctx context.Context, phone, message string) e
 	return nil
 }
 
-// HealthCheck checks SMS service health
+// vonageSMSResponse is the SMS API's reply; a status other than "0" on any
+// message part is a failure even though the HTTP status is 200
+type vonageSMSResponse struct {
+	Messages []struct {
+		Status    string `json:"status"`
+		ErrorText string `json:"error-text"`
+	} `json:"messages"`
+}
+
+func (s *SMSService) sendVonageSMS(ctx context.Context, provider SMSProviderConfig, normalized, message string) error {
+	form := url.Values{}
+	form.Set("api_key", strings.TrimSpace(provider.APIKey))
+	form.Set("api_secret", strings.TrimSpace(provider.APISecret))
+	form.Set("from", strings.TrimPrefix(provider.FromNumber, "+"))
+	form.Set("to", strings.TrimPrefix(normalized, "+"))
+	form.Set("text", message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.baseURL()+"/sms/json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to build vonage request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send vonage sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 400 {
+		s.logger.Error("Vonage SMS send failed",
+			zap.String("provider", "vonage"),
+			zap.String("to", s.maskPhone(normalized)),
+			zap.Int("status_code", resp.StatusCode),
+			zap.String("response_body", string(bodyBytes)))
+		return fmt.Errorf("vonage sms send failed: status %d", resp.StatusCode)
+	}
+
+	var result vonageSMSResponse
+	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+		return fmt.Errorf("failed to decode vonage response: %w", err)
+	}
+	for _, part := range result.Messages {
+		if part.Status != "0" {
+			s.logger.Error("Vonage SMS send failed",
+				zap.String("provider", "vonage"),
+				zap.String("to", s.maskPhone(normalized)),
+				zap.String("status", part.Status),
+				zap.String("error_text", part.ErrorText))
+			return fmt.Errorf("vonage sms send failed: status %s: %s", part.Status, part.ErrorText)
+		}
+	}
+
+	s.logger.Info("SMS sent successfully",
+		zap.String("provider", "vonage"),
+		zap.String("to", s.maskPhone(normalized)),
+		zap.Int("parts", len(result.Messages)))
+
+	return nil
+}
+
+// HealthCheck checks SMS service health. With fallbacks configured, one
+// healthy provider is enough; under sandbox capture no provider is used.
 func (s *SMSService) HealthCheck(ctx context.Context) error {
+	if s.capture != nil {
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	provider := strings.ToLower(strings.TrimSpace(s.config.Provider))
-	switch provider {
-	case "twilio":
-		return s.twilioHealthCheck(ctx)
-	default:
-		return fmt.Errorf("unsupported sms provider: %s", provider)
+	var errs []error
+	for _, provider := range s.providers {
+		var err error
+		switch provider.Provider {
+		case "twilio":
+			err = s.twilioHealthCheck(ctx, provider)
+		case "vonage":
+			err = s.vonageHealthCheck(ctx, provider)
+		default:
+			err = fmt.Errorf("unsupported sms provider: %s", provider.Provider)
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
 	}
+	return errors.Join(errs...)
 }
 
-func (s *SMSService) twilioHealthCheck(ctx context.Context) error {
-	accountSID := strings.TrimSpace(s.config.APIKey)
-	authToken := strings.TrimSpace(s.config.APISecret)
-	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s.json", accountSID)
+func (s *SMSService) twilioHealthCheck(ctx context.Context, provider SMSProviderConfig) error {
+	accountSID := strings.TrimSpace(provider.APIKey)
+	authToken := strings.TrimSpace(provider.APISecret)
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s.json", provider.baseURL(), accountSID)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -259,3 +472,26 @@ func (s *SMSService) twilioHealthCheck(ctx context.Context) error {
 
 	return nil
 }
+
+func (s *SMSService) vonageHealthCheck(ctx context.Context, provider SMSProviderConfig) error {
+	query := url.Values{}
+	query.Set("api_key", strings.TrimSpace(provider.APIKey))
+	query.Set("api_secret", strings.TrimSpace(provider.APISecret))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.baseURL()+"/account/get-balance?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build vonage health request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vonage health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("vonage health check error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
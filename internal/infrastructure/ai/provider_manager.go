@@ -113,8 +113,8 @@ func (m *ProviderManager) ChatCompletionWithTools(ctx context.Context, req *Chat
 		// If we have more providers to try, continue
 		if i < len(providers)-1 {
 			m.logger.Info("Failing over to next provider",
-				zap.String("from", providerName),
-				zap.String("to", providers[i+1].Name()),
+				zap.String("from_provider", providerName),
+				zap.String("to_provider", providers[i+1].Name()),
 			)
 			continue
 		}
@@ -23,6 +23,14 @@ type RedisClient interface {
 	Keys(ctx context.Context, pattern string) ([]string, error)
 	Ping(ctx context.Context) error
 	Close() error
+	StreamAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	StreamRead(ctx context.Context, stream, afterID string, count int64, block time.Duration) ([]StreamMessage, error)
+}
+
+// StreamMessage is one entry read from a Redis stream
+type StreamMessage struct {
+	ID     string
+	Values map[string]interface{}
 }
 
 // redisClient implements RedisClient using go-redis
@@ -32,29 +40,36 @@ type redisClient struct {
 	config *config.RedisConfig
 }
 
-// NewRedisClient creates a new Redis client
+// NewRedisClient creates a new Redis client and verifies the connection
 func NewRedisClient(cfg *config.RedisConfig, logger *zap.Logger) (RedisClient, error) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Password: cfg.Password, // no password set
-		DB:       cfg.DB,       // use default DB
-	})
+	client := OpenRedisClient(cfg, logger)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := rdb.Ping(ctx).Result()
-	if err != nil {
+	if err := client.Ping(ctx); err != nil {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
 	logger.Info("Connected to Redis successfully", zap.String("host", cfg.Host), zap.Int("port", cfg.Port))
+	return client, nil
+}
+
+// OpenRedisClient creates a Redis client without contacting the server.
+// Connections are made on first use and re-established after failures, so
+// the client can be handed out while Redis is still unreachable.
+func OpenRedisClient(cfg *config.RedisConfig, logger *zap.Logger) RedisClient {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password: cfg.Password, // no password set
+		DB:       cfg.DB,       // use default DB
+	})
 
 	return &redisClient{
 		client: rdb,
 		logger: logger,
 		config: cfg,
-	}, nil
+	}
 }
 
 // Set sets a key-value pair with an expiration
@@ -111,6 +126,43 @@ func (r *redisClient) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
+// StreamAdd appends an entry to a stream, trimming it to roughly maxLen entries
+func (r *redisClient) StreamAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+}
+
+// StreamRead returns entries after afterID, waiting up to block for new ones.
+// A negative block returns immediately. An empty result means nothing arrived.
+func (r *redisClient) StreamRead(ctx context.Context, stream, afterID string, count int64, block time.Duration) ([]StreamMessage, error) {
+	if block == 0 {
+		block = -1 // zero would block forever
+	}
+	streams, err := r.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{stream, afterID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream '%s': %w", stream, err)
+	}
+
+	var messages []StreamMessage
+	for _, s := range streams {
+		for _, m := range s.Messages {
+			messages = append(messages, StreamMessage{ID: m.ID, Values: m.Values})
+		}
+	}
+	return messages, nil
+}
+
 // Close closes the Redis client
 func (r *redisClient) Close() error {
 	return r.client.Close()
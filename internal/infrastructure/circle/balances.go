@@ -0,0 +1,45 @@
+package circle
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// maxBalancesPageSize is the largest page Circle returns from the bulk balances endpoint
+const maxBalancesPageSize = 50
+
+// ListWalletBalances returns one page of wallets with their token balances.
+// Pass the last wallet ID of the previous page as pageAfter to continue; an
+// empty result means there are no more wallets.
+func (c *Client) ListWalletBalances(ctx context.Context, pageAfter string, pageSize int) ([]entities.CircleWalletBalances, error) {
+	if pageSize <= 0 || pageSize > maxBalancesPageSize {
+		pageSize = maxBalancesPageSize
+	}
+	params := url.Values{}
+	params.Set("pageSize", strconv.Itoa(pageSize))
+	if pageAfter != "" {
+		params.Set("pageAfter", pageAfter)
+	}
+	endpoint := fmt.Sprintf("%s/balances?%s", c.config.WalletsEndpoint, params.Encode())
+
+	var response struct {
+		Data struct {
+			Wallets []entities.CircleWalletBalances `json:"wallets"`
+		} `json:"data"`
+	}
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return &response, c.doRequestWithRetry(ctx, "GET", endpoint, nil, &response)
+	})
+	if err != nil {
+		c.logger.Error("Failed to list wallet balances",
+			zap.String("pageAfter", pageAfter),
+			zap.Error(err))
+		return nil, fmt.Errorf("list wallet balances failed: %w", err)
+	}
+	return response.Data.Wallets, nil
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/sony/gobreaker"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	entitysecret "github.com/stack-service/stack_service/internal/domain/services/entity_secret"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
+	"github.com/stack-service/stack_service/pkg/retry"
 	"go.uber.org/zap"
 )
 
@@ -47,6 +50,8 @@ type Config struct {
 	PublicKeyEndpoint      string        `json:"public_key_endpoint"`
 	BalancesEndpoint       string        `json:"balances_endpoint"`
 	TransferEndpoint       string        `json:"transfer_endpoint"`
+	SubscriptionsEndpoint  string        `json:"subscriptions_endpoint"`
+	SigningKeyEndpoint     string        `json:"signing_key_endpoint"`
 	EntitySecretCiphertext string        `json:"entity_secret_ciphertext"` // Pre-registered ciphertext from Circle Dashboard
 }
 
@@ -57,6 +62,7 @@ type Client struct {
 	circuitBreaker      *gobreaker.CircuitBreaker
 	logger              *zap.Logger
 	entitySecretService *entitysecret.Service
+	retryBudget         *retry.Budget
 }
 
 // NewClient creates a new Circle API client
@@ -91,17 +97,23 @@ func NewClient(config Config, logger *zap.Logger) *Client {
 	if config.TransferEndpoint == "" {
 		config.TransferEndpoint = "/v1/w3s/developer/transactions/transfer"
 	}
+	if config.SubscriptionsEndpoint == "" {
+		config.SubscriptionsEndpoint = "/v2/notifications/subscriptions"
+	}
+	if config.SigningKeyEndpoint == "" {
+		config.SigningKeyEndpoint = "/v2/notifications/publicKey"
+	}
 
 	httpClient := &http.Client{
 		Timeout: config.Timeout,
-		Transport: &http.Transport{
+		Transport: metrics.InstrumentProvider(metrics.ProviderCircle, &http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
 			},
 			MaxIdleConns:        100,
 			MaxIdleConnsPerHost: 10,
 			IdleConnTimeout:     90 * time.Second,
-		},
+		}),
 	}
 
 	st := gobreaker.Settings{
@@ -115,12 +127,14 @@ func NewClient(config Config, logger *zap.Logger) *Client {
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Info("Circuit breaker state changed",
 				zap.String("name", name),
-				zap.String("from", from.String()),
-				zap.String("to", to.String()))
+				zap.String("from_state", from.String()),
+				zap.String("to_state", to.String()))
+			metrics.SetProviderCircuitState(metrics.ProviderCircle, name, to.String())
 		},
 	}
 
 	circuitBreaker := gobreaker.NewCircuitBreaker(st)
+	metrics.SetProviderCircuitState(metrics.ProviderCircle, st.Name, circuitBreaker.State().String())
 
 	// Initialize entity secret service for dynamic ciphertext generation (fallback only)
 	entitySecretService := entitysecret.NewService(logger)
@@ -140,6 +154,24 @@ func NewClient(config Config, logger *zap.Logger) *Client {
 	}
 }
 
+// SetEntitySecretService replaces the client's unconfigured entity secret
+// service with one holding the registered secret
+func (c *Client) SetEntitySecretService(service *entitysecret.Service) {
+	c.entitySecretService = service
+}
+
+// SetRetryBudget makes retries of failed calls draw on a budget shared with
+// the other provider clients, so a Circle outage is not amplified by retries
+func (c *Client) SetRetryBudget(budget *retry.Budget) {
+	c.retryBudget = budget
+}
+
+// WrapTransport routes the client's calls through wrap, which receives the
+// current transport, e.g. to inject faults in test environments
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // CreateWalletSet creates a new developer-controlled wallet set using pre-registered Entity Secret Ciphertext
 func (c *Client) CreateWalletSet(ctx context.Context, name string, _ string) (*entities.CircleWalletSetResponse, error) {
 
@@ -332,6 +364,8 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, endpoint string
 		}
 
 		err := c.doRequest(ctx, method, endpoint, requestBody, responseBody, requestID)
+		retryable := err != nil && c.shouldRetry(err)
+		c.retryBudget.Record(metrics.ProviderCircle, !retryable)
 		if err == nil {
 			return nil
 		}
@@ -339,7 +373,7 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, endpoint string
 		lastErr = err
 
 		// Check if error is retryable
-		if !c.shouldRetry(err) {
+		if !retryable {
 			c.logger.Warn("Not retrying Circle API request due to error type",
 				zap.String("request_id", requestID),
 				zap.Error(err),
@@ -347,6 +381,15 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, endpoint string
 				zap.String("endpoint", endpoint))
 			break
 		}
+		if attempt < maxRetries && !c.retryBudget.AllowRetry(metrics.ProviderCircle) {
+			c.logger.Warn("Not retrying Circle API request, retry budget exhausted",
+				zap.String("request_id", requestID),
+				zap.Error(err),
+				zap.Int("attempt", attempt+1),
+				zap.String("method", method),
+				zap.String("endpoint", endpoint))
+			return fmt.Errorf("request failed after %d attempts, retries suppressed: %w", attempt+1, lastErr)
+		}
 
 		c.logger.Warn("Circle API request failed, will retry",
 			zap.String("request_id", requestID),
@@ -523,7 +566,7 @@ func (c *Client) GenerateDepositAddress(ctx context.Context, chain entities.Wall
 func (c *Client) ValidateDeposit(ctx context.Context, txHash string, amount decimal.Decimal) (bool, error) {
 	c.logger.Info("Validating deposit",
 		zap.String("tx_hash", txHash),
-		zap.String("amount", amount.String()))
+		logger.Amount("amount", amount))
 
 	// For MVP, we'll simulate validation
 	// In production, this would call Circle's transaction validation API
@@ -532,7 +575,7 @@ func (c *Client) ValidateDeposit(ctx context.Context, txHash string, amount deci
 	if amount.IsZero() || amount.IsNegative() {
 		c.logger.Warn("Invalid deposit amount",
 			zap.String("tx_hash", txHash),
-			zap.String("amount", amount.String()))
+			logger.Amount("amount", amount))
 		return false, nil
 	}
 
@@ -549,7 +592,7 @@ func (c *Client) ValidateDeposit(ctx context.Context, txHash string, amount deci
 
 	c.logger.Info("Deposit validation successful",
 		zap.String("tx_hash", txHash),
-		zap.String("amount", amount.String()))
+		logger.Amount("amount", amount))
 
 	return true, nil
 }
@@ -557,8 +600,8 @@ func (c *Client) ValidateDeposit(ctx context.Context, txHash string, amount deci
 // ConvertToUSD converts stablecoin amount to USD buying power
 func (c *Client) ConvertToUSD(ctx context.Context, amount decimal.Decimal, token entities.Stablecoin) (decimal.Decimal, error) {
 	c.logger.Info("Converting to USD",
-		zap.String("amount", amount.String()),
-		zap.String("token", string(token)))
+		logger.Amount("amount", amount),
+		zap.String("stablecoin", string(token)))
 
 	// For MVP, we'll use fixed conversion rates
 	// In production, this would call Circle's price oracle or conversion API
@@ -575,9 +618,9 @@ func (c *Client) ConvertToUSD(ctx context.Context, amount decimal.Decimal, token
 	usdAmount := amount.Mul(conversionRate)
 
 	c.logger.Info("Conversion to USD completed",
-		zap.String("original_amount", amount.String()),
-		zap.String("token", string(token)),
-		zap.String("usd_amount", usdAmount.String()),
+		logger.Amount("original_amount", amount),
+		zap.String("stablecoin", string(token)),
+		logger.Amount("usd_amount", usdAmount),
 		zap.String("conversion_rate", conversionRate.String()))
 
 	return usdAmount, nil
@@ -650,6 +693,14 @@ func (c *Client) GetWalletBalances(ctx context.Context, walletID string, tokenAd
 
 // TransferFunds transfers funds between accounts using developer-controlled wallets
 func (c *Client) TransferFunds(ctx context.Context, req entities.CircleTransferRequest) (map[string]interface{}, error) {
+	if req.DestinationAddress != "" {
+		if chain, ok := entities.Chains().Lookup(req.Blockchain); ok {
+			if _, err := chain.CheckAddress(req.DestinationAddress); err != nil {
+				return nil, fmt.Errorf("invalid destination address: %w", err)
+			}
+		}
+	}
+
 	// Generate a new unique entity secret ciphertext for this request
 	entitySecretCiphertext, err := c.entitySecretService.GenerateEntitySecretCiphertext(ctx)
 	if err != nil {
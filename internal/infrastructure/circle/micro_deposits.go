@@ -0,0 +1,55 @@
+package circle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// MicroDepositSender sends address verification deposits in USDC from the
+// platform's developer-controlled wallet on each chain
+type MicroDepositSender struct {
+	client        *Client
+	sourceWallets map[string]string // Chain ID to the wallet deposits are sent from
+}
+
+// NewMicroDepositSender creates a sender drawing on the given wallet per chain
+func NewMicroDepositSender(client *Client, sourceWallets map[string]string) *MicroDepositSender {
+	return &MicroDepositSender{client: client, sourceWallets: sourceWallets}
+}
+
+// SendMicroDeposit transfers amount of USDC to address and returns Circle's
+// transaction ID
+func (m *MicroDepositSender) SendMicroDeposit(ctx context.Context, chain, address string, amount decimal.Decimal, idempotencyKey string) (string, error) {
+	info, ok := entities.Chains().Lookup(chain)
+	if !ok || info.USDCTokenAddress == "" {
+		return "", fmt.Errorf("no USDC token configured for chain %s", chain)
+	}
+	walletID := m.sourceWallets[string(info.ID)]
+	if walletID == "" {
+		return "", fmt.Errorf("no verification deposit wallet configured for chain %s", info.ID)
+	}
+
+	response, err := m.client.TransferFunds(ctx, entities.CircleTransferRequest{
+		IDempotencyKey:     idempotencyKey,
+		WalletID:           walletID,
+		Blockchain:         string(info.ID),
+		TokenAddress:       info.USDCTokenAddress,
+		Amounts:            []string{amount.String()},
+		DestinationAddress: address,
+		FeeLevel:           "MEDIUM",
+		RefID:              "address-check:" + idempotencyKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	if data, ok := response["data"].(map[string]interface{}); ok {
+		if id, ok := data["id"].(string); ok && id != "" {
+			return id, nil
+		}
+	}
+	return idempotencyKey, nil
+}
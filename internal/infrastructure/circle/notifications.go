@@ -0,0 +1,79 @@
+package circle
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"go.uber.org/zap"
+)
+
+// CreateNotificationSubscription registers a callback URL for Circle notifications
+func (c *Client) CreateNotificationSubscription(ctx context.Context, req entities.CircleCreateSubscriptionRequest) (*entities.CircleNotificationSubscription, error) {
+	var response struct {
+		Data entities.CircleNotificationSubscription `json:"data"`
+	}
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return &response, c.doRequestWithRetry(ctx, "POST", c.config.SubscriptionsEndpoint, req, &response)
+	})
+	if err != nil {
+		c.logger.Error("Failed to create notification subscription",
+			zap.String("endpoint", req.Endpoint),
+			zap.Error(err))
+		return nil, fmt.Errorf("create notification subscription failed: %w", err)
+	}
+
+	c.logger.Info("Created Circle notification subscription",
+		zap.String("subscriptionId", response.Data.ID),
+		zap.String("endpoint", response.Data.Endpoint))
+	return &response.Data, nil
+}
+
+// ListNotificationSubscriptions returns every notification subscription on the account
+func (c *Client) ListNotificationSubscriptions(ctx context.Context) ([]entities.CircleNotificationSubscription, error) {
+	var response struct {
+		Data []entities.CircleNotificationSubscription `json:"data"`
+	}
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return &response, c.doRequestWithRetry(ctx, "GET", c.config.SubscriptionsEndpoint, nil, &response)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list notification subscriptions failed: %w", err)
+	}
+	return response.Data, nil
+}
+
+// DeleteNotificationSubscription removes a notification subscription
+func (c *Client) DeleteNotificationSubscription(ctx context.Context, subscriptionID string) error {
+	endpoint := fmt.Sprintf("%s/%s", c.config.SubscriptionsEndpoint, url.PathEscape(subscriptionID))
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return nil, c.doRequestWithRetry(ctx, "DELETE", endpoint, nil, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("delete notification subscription failed: %w", err)
+	}
+
+	c.logger.Info("Deleted Circle notification subscription", zap.String("subscriptionId", subscriptionID))
+	return nil
+}
+
+// GetNotificationPublicKey retrieves the key Circle signed notifications with,
+// identified by the X-Circle-Key-Id header
+func (c *Client) GetNotificationPublicKey(ctx context.Context, keyID string) (*entities.CircleNotificationPublicKey, error) {
+	endpoint := fmt.Sprintf("%s/%s", c.config.SigningKeyEndpoint, url.PathEscape(keyID))
+
+	var response struct {
+		Data entities.CircleNotificationPublicKey `json:"data"`
+	}
+	_, err := c.circuitBreaker.Execute(func() (interface{}, error) {
+		return &response, c.doRequestWithRetry(ctx, "GET", endpoint, nil, &response)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get notification public key failed: %w", err)
+	}
+	if response.Data.PublicKey == "" {
+		return nil, fmt.Errorf("public key not found in response")
+	}
+	return &response.Data, nil
+}
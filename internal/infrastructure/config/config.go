@@ -30,6 +30,64 @@ type Config struct {
 	Due            DueConfig            `mapstructure:"due"`
 	Workers        WorkerConfig         `mapstructure:"workers"`
 	Reconciliation ReconciliationConfig `mapstructure:"reconciliation"`
+	Audit          AuditConfig          `mapstructure:"audit"`
+	Retention      RetentionConfig      `mapstructure:"retention"`
+	BackupVerify   BackupVerifyConfig   `mapstructure:"backup_verify"`
+	Inactivity     InactivityConfig     `mapstructure:"inactivity"`
+	Webhooks       OutboundWebhookConfig `mapstructure:"webhooks"`
+	EventStream    EventStreamConfig     `mapstructure:"event_stream"`
+	EventBus       EventBusConfig        `mapstructure:"event_bus"`
+	WalletBackfill WalletBackfillConfig  `mapstructure:"wallet_backfill"`
+	BalanceCache   BalanceCacheConfig    `mapstructure:"balance_cache"`
+	Deposits       DepositConfig         `mapstructure:"deposits"`
+	Suspense       SuspenseConfig        `mapstructure:"suspense"`
+	Promotions     PromotionsConfig      `mapstructure:"promotions"`
+	Billing        BillingConfig         `mapstructure:"billing"`
+	Startup        StartupConfig         `mapstructure:"startup"`
+	AIArtifacts    AIArtifactsConfig     `mapstructure:"ai_artifacts"`
+	TradingFees    TradingFeesConfig     `mapstructure:"trading_fees"`
+	LimitOrders    LimitOrdersConfig     `mapstructure:"limit_orders"`
+	PaperTrading   PaperTradingConfig    `mapstructure:"paper_trading"`
+	StuckOrders    StuckOrdersConfig     `mapstructure:"stuck_orders"`
+	OpsDigest      OpsDigestConfig       `mapstructure:"ops_digest"`
+	HTTPCapture    HTTPCaptureConfig     `mapstructure:"http_capture"`
+	APIUsage       APIUsageConfig        `mapstructure:"api_usage"`
+	Chains         ChainsConfig          `mapstructure:"chains"`
+	Recipients     RecipientsConfig      `mapstructure:"recipients"`
+	WebSession     WebSessionConfig      `mapstructure:"web_session"`
+	OnboardingJobs OnboardingJobsConfig  `mapstructure:"onboarding_jobs"`
+	OnboardingEvents OnboardingEventsConfig `mapstructure:"onboarding_events"`
+	GeoIP            GeoIPConfig            `mapstructure:"geoip"`
+	EDD              EDDConfig              `mapstructure:"edd"`
+	Approvals        ApprovalsConfig        `mapstructure:"approvals"`
+	Rates            RatesConfig            `mapstructure:"rates"`
+	MarketData       MarketDataConfig       `mapstructure:"market_data"`
+	Goals            GoalsConfig            `mapstructure:"goals"`
+	Messaging        MessagingConfig        `mapstructure:"messaging"`
+	BulkOps          BulkOpsConfig          `mapstructure:"bulk_ops"`
+	Accounting       AccountingConfig       `mapstructure:"accounting"`
+	KYB              KYBConfig              `mapstructure:"kyb"`
+	Sweep            SweepConfig            `mapstructure:"sweep"`
+	Warehouse        WarehouseConfig        `mapstructure:"warehouse"`
+	FaultInjection   FaultInjectionConfig   `mapstructure:"fault_injection"`
+	OrderBlotter     OrderBlotterConfig     `mapstructure:"order_blotter"`
+	Uploads          UploadsConfig          `mapstructure:"uploads"`
+	Notifications    NotificationsConfig    `mapstructure:"notifications"`
+	Shadow           ShadowConfig           `mapstructure:"shadow"`
+	PasswordPolicy   PasswordPolicyConfig   `mapstructure:"password_policy"`
+	JobJanitor       JobJanitorConfig       `mapstructure:"job_janitor"`
+	BankVerification BankVerificationConfig `mapstructure:"bank_verification"`
+	LatencyBudget    LatencyBudgetConfig    `mapstructure:"latency_budget"`
+	Integrity        IntegrityConfig        `mapstructure:"integrity"`
+	Delegates        DelegatesConfig        `mapstructure:"delegates"`
+	WebhookArchive   WebhookArchiveConfig   `mapstructure:"webhook_archive"`
+	Alerting         AlertingConfig         `mapstructure:"alerting"`
+	CacheWarm        CacheWarmConfig        `mapstructure:"cache_warm"`
+	Logging          LoggingConfig          `mapstructure:"logging"`
+	Residency        ResidencyConfig        `mapstructure:"residency"`
+	Rewards          RewardsConfig          `mapstructure:"rewards"`
+	PortfolioSharing PortfolioSharingConfig `mapstructure:"portfolio_sharing"`
+	ZeroG            ZeroGConfig            `mapstructure:"zerog"`
 }
 
 type ServerConfig struct {
@@ -130,6 +188,20 @@ type SecurityConfig struct {
 	RequireMFA        bool     `mapstructure:"require_mfa"`
 	PasswordMinLength int      `mapstructure:"password_min_length"`
 	SessionTimeout    int      `mapstructure:"session_timeout"`
+
+	// Field-level PII encryption. Keys are sourced from the secrets manager as
+	// "1:<secret>,2:<secret>"; the active version encrypts new writes.
+	FieldEncryptionKeys          string `mapstructure:"field_encryption_keys"`
+	FieldEncryptionActiveVersion int    `mapstructure:"field_encryption_active_version"`
+	FieldIndexKey                string `mapstructure:"field_index_key"`
+
+	// PII vault keys, kept separate from the field keys so the main database's
+	// keys cannot decrypt vault records. Same "1:<secret>" format.
+	PIIVaultKeys          string `mapstructure:"pii_vault_keys"`
+	PIIVaultActiveVersion int    `mapstructure:"pii_vault_active_version"`
+
+	// How far a signed internal request's timestamp may be from now, in seconds
+	RequestSigningTolerance int `mapstructure:"request_signing_tolerance"`
 }
 
 type CircleConfig struct {
@@ -137,9 +209,19 @@ type CircleConfig struct {
 	Environment            string   `mapstructure:"environment"` // sandbox or production
 	BaseURL                string   `mapstructure:"base_url"`
 	EntitySecretCiphertext string   `mapstructure:"entity_secret_ciphertext"` // Pre-registered ciphertext from Circle Dashboard
+	// Entity secret from the secrets manager, either hex-encoded or as a file
+	// sealed by the entity-secret command. The public key is fetched from
+	// Circle unless pinned.
+	EntitySecret     string `mapstructure:"entity_secret"`
+	EntitySecretFile string `mapstructure:"entity_secret_file"`
+	EntityPublicKey  string `mapstructure:"entity_public_key"`
 	DefaultWalletSetID     string   `mapstructure:"default_wallet_set_id"`
 	DefaultWalletSetName   string   `mapstructure:"default_wallet_set_name"`
 	SupportedChains        []string `mapstructure:"supported_chains"`
+	// Notification subscription management; empty callback URL disables it
+	NotificationCallbackURL  string   `mapstructure:"notification_callback_url"`
+	NotificationTypes        []string `mapstructure:"notification_types"` // empty subscribes to every type
+	SubscriptionCheckMinutes int      `mapstructure:"subscription_check_minutes"`
 }
 
 type KYCConfig struct {
@@ -151,6 +233,8 @@ type KYCConfig struct {
 	Environment string `mapstructure:"environment"` // "development", "sandbox", "production"
 	UserAgent   string `mapstructure:"user_agent"`
 	LevelName   string `mapstructure:"level_name"`
+	// EDDLevelName is the provider level for enhanced due diligence checks
+	EDDLevelName string `mapstructure:"edd_level_name"`
 }
 
 type EmailConfig struct {
@@ -161,14 +245,99 @@ type EmailConfig struct {
 	BaseURL     string `mapstructure:"base_url"`    // For verification links
 	Environment string `mapstructure:"environment"` // "development", "staging", "production"
 	ReplyTo     string `mapstructure:"reply_to"`
+	// Fallbacks are tried in order when the primary provider fails
+	Fallbacks []EmailProviderConfig `mapstructure:"fallbacks"`
+}
+
+// EmailProviderConfig is a fallback email provider account
+type EmailProviderConfig struct {
+	Provider string `mapstructure:"provider"` // "sendgrid", "resend"
+	APIKey   string `mapstructure:"api_key"`
 }
 
 type SMSConfig struct {
-	Provider    string `mapstructure:"provider"` // "twilio"
+	Provider    string `mapstructure:"provider"` // "twilio", "vonage"
 	APIKey      string `mapstructure:"api_key"`
 	APISecret   string `mapstructure:"api_secret"`
 	FromNumber  string `mapstructure:"from_number"`
+	BaseURL     string `mapstructure:"base_url"`    // Overrides the API base URL, e.g. a Twilio regional edge
 	Environment string `mapstructure:"environment"` // "development", "staging", "production"
+	// Fallbacks are tried in order when the primary provider fails
+	Fallbacks []SMSProviderConfig `mapstructure:"fallbacks"`
+}
+
+// SMSProviderConfig is a fallback SMS provider account
+type SMSProviderConfig struct {
+	Provider   string `mapstructure:"provider"` // "twilio", "vonage"
+	APIKey     string `mapstructure:"api_key"`
+	APISecret  string `mapstructure:"api_secret"`
+	FromNumber string `mapstructure:"from_number"`
+	BaseURL    string `mapstructure:"base_url"` // Overrides the API base URL, e.g. a Twilio regional edge
+}
+
+// MessagingConfig controls email and SMS failover and sandbox capture
+type MessagingConfig struct {
+	SandboxCapture   bool `mapstructure:"sandbox_capture"`   // Store outbound email and SMS for admins to read instead of sending; for staging
+	FailureThreshold int  `mapstructure:"failure_threshold"` // Consecutive failed sends before a provider is skipped
+	CooldownSeconds  int  `mapstructure:"cooldown_seconds"`  // How long a failing provider is skipped before it is retried
+}
+
+// BulkOpsConfig limits admin bulk user operations and paces the worker that
+// applies them
+type BulkOpsConfig struct {
+	Enabled             bool `mapstructure:"enabled"`               // Run the worker; operations can still be created while it is off
+	MaxUsers            int  `mapstructure:"max_users"`             // Users one operation may name
+	BatchSize           int  `mapstructure:"batch_size"`            // Users processed per poll
+	MaxAttempts         int  `mapstructure:"max_attempts"`          // Tries before a user's outcome is recorded as failed
+	PollIntervalSeconds int  `mapstructure:"poll_interval_seconds"` // Time between polls for pending users
+}
+
+// AccountingConfig maps the platform ledger onto the finance team's general
+// ledger and chooses where daily journals are exported
+type AccountingConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`          // Export each settled day automatically
+	Destination     string `mapstructure:"destination"`      // "csv" or "quickbooks"
+	Timezone        string `mapstructure:"timezone"`         // Business day boundaries, e.g. America/New_York
+	LookbackDays    int    `mapstructure:"lookback_days"`    // Past days checked for missing exports on each run
+	SettleHours     int    `mapstructure:"settle_hours"`     // Hours after midnight before a day is exported
+	IntervalMinutes int    `mapstructure:"interval_minutes"` // Time between export runs
+	// AccountMapping maps ledger account types, e.g. system_fee_revenue, to
+	// general ledger account codes or QuickBooks account IDs
+	AccountMapping  map[string]string          `mapstructure:"account_mapping"`
+	SuspenseAccount string                     `mapstructure:"suspense_account"` // Receives unmapped activity; unset fails the export instead
+	RoundingAccount string                     `mapstructure:"rounding_account"` // Absorbs sub-cent rounding; defaults to the suspense account
+	CSVDir          string                     `mapstructure:"csv_dir"`          // Local outbox for CSV journals when SFTP is not configured
+	SFTP            AccountingSFTPConfig       `mapstructure:"sftp"`
+	QuickBooks      AccountingQuickBooksConfig `mapstructure:"quickbooks"`
+}
+
+// AccountingSFTPConfig is the SFTP drop CSV journals are uploaded to
+type AccountingSFTPConfig struct {
+	Host       string `mapstructure:"host"`
+	Port       int    `mapstructure:"port"`
+	User       string `mapstructure:"user"`
+	Password   string `mapstructure:"password"`
+	PrivateKey string `mapstructure:"private_key"` // PEM; preferred over password
+	HostKey    string `mapstructure:"host_key"`    // Server public key in authorized_keys format
+	Dir        string `mapstructure:"dir"`
+}
+
+// AccountingQuickBooksConfig holds the QuickBooks Online app and company
+type AccountingQuickBooksConfig struct {
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	RefreshToken string `mapstructure:"refresh_token"`
+	RealmID      string `mapstructure:"realm_id"`
+	Environment  string `mapstructure:"environment"` // "sandbox" or "production"
+}
+
+// KYBConfig controls business account onboarding and the limits verified
+// businesses receive
+type KYBConfig struct {
+	Enabled              bool    `mapstructure:"enabled"`                // Accept business applications
+	DailyDepositLimit    float64 `mapstructure:"daily_deposit_limit"`    // Business daily deposit limit in USD
+	DailyWithdrawalLimit float64 `mapstructure:"daily_withdrawal_limit"` // Business daily withdrawal limit in USD
+	DailyTradeLimit      float64 `mapstructure:"daily_trade_limit"`      // Business daily trade limit in USD
 }
 
 type VerificationConfig struct {
@@ -207,7 +376,590 @@ type ReconciliationConfig struct {
 	HourlyInterval       int    `mapstructure:"hourly_interval"`        // Interval in minutes for hourly runs
 	DailyRunTime         string `mapstructure:"daily_run_time"`         // Time of day for daily run (HH:MM format)
 	AutoCorrectLowSeverity bool `mapstructure:"auto_correct_low_severity"` // Auto-correct <$1 discrepancies
-	AlertWebhookURL      string `mapstructure:"alert_webhook_url"`      // Webhook URL for alerts
+	AlertWebhookURL      string `mapstructure:"alert_webhook_url"`      // Deprecated: fallback for alerting.webhook_url
+	AggregationConcurrency int  `mapstructure:"aggregation_concurrency"` // Parallel provider reads when summing balances
+}
+
+// AuditConfig contains audit log integrity configuration
+type AuditConfig struct {
+	AnchorEnabled         bool   `mapstructure:"anchor_enabled"`          // Periodically anchor the audit chain head
+	AnchorIntervalMinutes int    `mapstructure:"anchor_interval_minutes"` // Minutes between chain head anchors
+	AnchorStorage         string `mapstructure:"anchor_storage"`          // "0g" mirrors anchors to 0G storage; empty keeps them in the database only
+	AnchorUploadCommand   string `mapstructure:"anchor_upload_command"`   // 0g-storage-client binary that uploads anchors
+}
+
+type RetentionConfig struct {
+	Enabled       bool `mapstructure:"enabled"`        // Run the scheduled retention engine
+	DryRun        bool `mapstructure:"dry_run"`        // Report eligible rows without purging
+	IntervalHours int  `mapstructure:"interval_hours"` // Hours between scheduled runs
+	BatchSize     int  `mapstructure:"batch_size"`     // Rows purged per statement
+}
+
+type BackupVerifyConfig struct {
+	BackupDir          string  `mapstructure:"backup_dir"`            // Directory the pg_dump job writes archives to
+	BackupPattern      string  `mapstructure:"backup_pattern"`        // Glob for backup archives
+	ScratchDatabaseURL string  `mapstructure:"scratch_database_url"`  // Dedicated database the drill restores into
+	PgRestorePath      string  `mapstructure:"pg_restore_path"`       // pg_restore binary
+	MaxBackupAgeHours  int     `mapstructure:"max_backup_age_hours"`  // Oldest acceptable latest backup
+	MaxRowDriftPercent float64 `mapstructure:"max_row_drift_percent"` // Allowed row-count drift per table
+}
+
+type InactivityConfig struct {
+	Enabled         bool  `mapstructure:"enabled"`           // Run the dormant account monitor
+	IntervalHours   int   `mapstructure:"interval_hours"`    // Hours between scans
+	ReminderMonths  []int `mapstructure:"reminder_months"`   // Months of inactivity at which reminders escalate
+	CaseAfterMonths int   `mapstructure:"case_after_months"` // Months of inactivity before an admin case is opened
+}
+
+type OutboundWebhookConfig struct {
+	Enabled             bool `mapstructure:"enabled"`               // Deliver queued partner webhooks
+	PollIntervalSeconds int  `mapstructure:"poll_interval_seconds"` // Seconds between delivery passes
+	MaxAttempts         int  `mapstructure:"max_attempts"`          // Attempts before a delivery is marked dead
+	TimeoutSeconds      int  `mapstructure:"timeout_seconds"`       // Per-request timeout
+	AllowHTTP           bool `mapstructure:"allow_http"`            // Accept plain http endpoints (local development only)
+}
+
+type EventStreamConfig struct {
+	HeartbeatSeconds int `mapstructure:"heartbeat_seconds"` // Idle interval before an SSE heartbeat comment
+	MaxLen           int `mapstructure:"max_len"`           // Events kept per user for Last-Event-ID resume
+	RetentionHours   int `mapstructure:"retention_hours"`   // Idle user streams expire after this long
+}
+
+type EventBusConfig struct {
+	Driver       string `mapstructure:"driver"`         // inprocess, nats or kafka
+	NATSURL      string `mapstructure:"nats_url"`       // nats://[user:pass@]host:port
+	KafkaRESTURL string `mapstructure:"kafka_rest_url"` // Kafka REST proxy base URL
+	TopicPrefix  string `mapstructure:"topic_prefix"`   // Prepended to topics and consumer groups
+	MaxAttempts  int    `mapstructure:"max_attempts"`   // Handler attempts before a message is dropped
+}
+
+type WalletBackfillConfig struct {
+	BatchSize            int `mapstructure:"batch_size"`             // Provisioning jobs enqueued per batch
+	BatchIntervalSeconds int `mapstructure:"batch_interval_seconds"` // Pause between batches
+	MaxInFlight          int `mapstructure:"max_in_flight"`          // Outstanding backfill jobs before enqueueing pauses
+}
+
+type BalanceCacheConfig struct {
+	RefreshIntervalSeconds    int `mapstructure:"refresh_interval_seconds"`     // How often the worker refreshes stale wallets
+	MaxAgeSeconds             int `mapstructure:"max_age_seconds"`              // Balances older than this are reported stale
+	BatchSize                 int `mapstructure:"batch_size"`                   // Wallets refreshed per worker pass
+	Concurrency               int `mapstructure:"concurrency"`                  // Parallel Circle balance reads
+	ForceRefreshLimit         int `mapstructure:"force_refresh_limit"`          // Forced refreshes allowed per user per window
+	ForceRefreshWindowSeconds int `mapstructure:"force_refresh_window_seconds"` // Window for ForceRefreshLimit
+}
+
+type DepositConfig struct {
+	DefaultConfirmations int            `mapstructure:"default_confirmations"` // Required on chains without an entry below
+	Confirmations        map[string]int `mapstructure:"confirmations"`         // Block confirmations required before crediting, by chain
+	InstantPercent       float64        `mapstructure:"instant_percent"`       // Share of a detected deposit, 0-100, advanced as buying power before it is credited
+	InstantMaxAmount     float64        `mapstructure:"instant_max_amount"`    // Largest advance per deposit in USD; 0 for no cap
+
+	SharedAccounts    []SharedDepositAccountConfig `mapstructure:"shared_accounts"`     // Pooled bank accounts credited by memo reference, one per currency
+	BankWebhookSecret string                       `mapstructure:"bank_webhook_secret"` // HMAC key of shared account credit notifications; unsigned when empty
+}
+
+// SharedDepositAccountConfig is a bank account that receives every user's
+// transfers in a currency where per-user virtual accounts are not available
+type SharedDepositAccountConfig struct {
+	Currency      string `mapstructure:"currency"`
+	BankName      string `mapstructure:"bank_name"`
+	AccountName   string `mapstructure:"account_name"`
+	AccountNumber string `mapstructure:"account_number"`
+	RoutingNumber string `mapstructure:"routing_number"` // Sort code, IBAN or local bank code, as the region uses
+}
+
+type SuspenseConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // Run the suspense aging check
+	IntervalMinutes int  `mapstructure:"interval_minutes"` // Minutes between aging checks
+	AgingAlertDays  int  `mapstructure:"aging_alert_days"` // Open items older than this are reported as aged
+}
+
+type PromotionsConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // Run the promotional credit vesting sweep
+	IntervalMinutes int  `mapstructure:"interval_minutes"` // Minutes between sweeps
+	BatchSize       int  `mapstructure:"batch_size"`       // Grants vested and forfeited per sweep, each
+}
+
+type BillingConfig struct {
+	Enabled           bool  `mapstructure:"enabled"`            // Run subscription renewal and dunning
+	IntervalMinutes   int   `mapstructure:"interval_minutes"`   // Minutes between billing passes
+	DunningRetryHours []int `mapstructure:"dunning_retry_hours"` // Wait before each retry of a failed renewal
+}
+
+type StartupConfig struct {
+	Required                []string `mapstructure:"required"`                   // Optional components (redis, email) that must be up to boot
+	RetryIntervalSeconds    int      `mapstructure:"retry_interval_seconds"`     // First reconnect delay for degraded components
+	MaxRetryIntervalSeconds int      `mapstructure:"max_retry_interval_seconds"` // Reconnect backoff cap
+}
+
+type AIArtifactsConfig struct {
+	SigningKey     string `mapstructure:"signing_key"`      // HMAC key for download links; the JWT secret when empty
+	LinkTTLMinutes int    `mapstructure:"link_ttl_minutes"` // How long a download link stays valid
+	ExportPageSize int    `mapstructure:"export_page_size"` // Summaries read per query while exporting
+
+	Storage                  string          `mapstructure:"storage"`                    // "0g", "s3" or "local"; empty serves database copies only
+	LocalDir                 string          `mapstructure:"local_dir"`                  // Directory of the local store
+	S3                       UploadsS3Config `mapstructure:"s3"`                         // Bucket of the s3 store
+	MigrateFrom              []string        `mapstructure:"migrate_from"`               // Stores whose artifacts are moved to Storage in the background
+	MigrationIntervalMinutes int             `mapstructure:"migration_interval_minutes"` // Time between migration passes
+	MigrationBatchSize       int             `mapstructure:"migration_batch_size"`       // Artifacts read per query while migrating
+	DeleteMigratedSource     bool            `mapstructure:"delete_migrated_source"`     // Remove the old copy once an artifact has moved
+}
+
+type TradingFeesConfig struct {
+	CommissionBps     float64 `mapstructure:"commission_bps"`     // Commission in basis points of the order amount
+	MinimumCommission float64 `mapstructure:"minimum_commission"` // Floor when a commission is charged
+	SECFeeRate        float64 `mapstructure:"sec_fee_rate"`       // SEC Section 31 fee per dollar sold
+	TAFPerShare       float64 `mapstructure:"taf_per_share"`      // FINRA trading activity fee per share sold
+	TAFMaximum        float64 `mapstructure:"taf_maximum"`        // TAF cap per trade
+}
+
+type LimitOrdersConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // Run the limit order monitor
+	IntervalSeconds int  `mapstructure:"interval_seconds"` // Seconds between checks of open orders
+	BatchSize       int  `mapstructure:"batch_size"`       // Open orders checked per pass
+	MaxGTCDays      int  `mapstructure:"max_gtc_days"`     // Days before a good-till-cancelled order expires
+}
+
+type PaperTradingConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`          // Offer paper trading and route paper users to the simulated broker
+	StartingBalance float64 `mapstructure:"starting_balance"` // Simulated USD a new paper account starts with
+}
+
+type StuckOrdersConfig struct {
+	SLAMinutes int `mapstructure:"sla_minutes"` // Minutes an order may sit in flight before admins can intervene
+	ListLimit  int `mapstructure:"list_limit"`  // Stuck orders returned per listing
+}
+
+type OpsDigestConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`          // Send the daily ops digest
+	Recipients      []string `mapstructure:"recipients"`       // Admin emails that receive the digest
+	SendHourUTC     int      `mapstructure:"send_hour_utc"`    // Hour after which yesterday's digest goes out
+	IntervalMinutes int      `mapstructure:"interval_minutes"` // Minutes between checks for a due digest
+}
+
+type ChainsConfig struct {
+	Enabled     []string                `mapstructure:"enabled"`     // Chain IDs wallets and deposits are allowed on
+	Definitions []ChainDefinitionConfig `mapstructure:"definitions"` // New chains, or overrides of built-in ones by ID
+}
+
+// ChainDefinitionConfig defines or overrides a chain. When the ID names a
+// built-in chain only the non-empty fields replace the built-in values.
+type ChainDefinitionConfig struct {
+	ID               string   `mapstructure:"id"`
+	Name             string   `mapstructure:"name"`
+	Family           string   `mapstructure:"family"` // Solana or EVM
+	Testnet          bool     `mapstructure:"testnet"`
+	NativeCurrency   string   `mapstructure:"native_currency"`
+	USDCTokenAddress string   `mapstructure:"usdc_token_address"`
+	ExplorerURL      string   `mapstructure:"explorer_url"` // Transaction URL with %s for the hash
+	Confirmations    int      `mapstructure:"confirmations"`
+	AddressPattern   string   `mapstructure:"address_pattern"`
+	Aliases          []string `mapstructure:"aliases"`
+}
+
+type HTTPCaptureConfig struct {
+	Enabled              bool    `mapstructure:"enabled"`                // Allow request capture for debugging
+	SamplePercent        float64 `mapstructure:"sample_percent"`         // Share of all requests captured, 0-100
+	TTLHours             int     `mapstructure:"ttl_hours"`              // Hours captures are kept
+	MaxBodyBytes         int     `mapstructure:"max_body_bytes"`         // Bytes of each body kept
+	TargetRefreshSeconds int     `mapstructure:"target_refresh_seconds"` // Seconds between reloads of admin-flagged targets
+	QueueSize            int     `mapstructure:"queue_size"`             // Captures buffered for writing before new ones are dropped
+}
+
+// APIUsageConfig controls request analytics per user and endpoint, rolled up
+// hourly from the HTTP middleware
+type APIUsageConfig struct {
+	Enabled       bool `mapstructure:"enabled"`        // Record API usage
+	FlushSeconds  int  `mapstructure:"flush_seconds"`  // Seconds between writes of in-memory counts
+	RetentionDays int  `mapstructure:"retention_days"` // Days rollups are kept
+}
+
+type RecipientsConfig struct {
+	FirstUseHoldHours int                         `mapstructure:"first_use_hold_hours"` // Hours before a newly saved recipient can receive funds
+	MaxPerUser        int                         `mapstructure:"max_per_user"`         // Saved recipients allowed per user
+	AddressCheck      RecipientAddressCheckConfig `mapstructure:"address_check"`
+}
+
+// RecipientAddressCheckConfig controls proof of control of saved crypto
+// addresses, by micro-deposit or signed message, before larger withdrawals
+type RecipientAddressCheckConfig struct {
+	Enabled              bool              `mapstructure:"enabled"`
+	RequiredAboveUSD     float64           `mapstructure:"required_above_usd"`      // Withdrawals above this to a new address need a verified one; 0 requires it for all
+	NewForDays           int               `mapstructure:"new_for_days"`            // Days after saving an address counts as new; 0 for always
+	MicroDepositTTLHours int               `mapstructure:"micro_deposit_ttl_hours"` // Time to confirm a micro-deposit
+	SignatureTTLMinutes  int               `mapstructure:"signature_ttl_minutes"`   // Time to sign and submit a message
+	MaxAttempts          int               `mapstructure:"max_attempts"`            // Answers allowed per check
+	MicroDepositWallets  map[string]string `mapstructure:"micro_deposit_wallets"`   // Chain ID to the Circle wallet deposits are sent from; empty allows only signatures
+}
+
+// WebSessionConfig controls cookie sessions for browser clients. Both auth
+// modes can be enabled together while clients migrate from bearer tokens.
+type WebSessionConfig struct {
+	AuthModes         []string `mapstructure:"auth_modes"`          // "bearer", "cookie" or both
+	AccessCookieName  string   `mapstructure:"access_cookie_name"`  // httpOnly access token cookie
+	RefreshCookieName string   `mapstructure:"refresh_cookie_name"` // httpOnly refresh token cookie, sent only to /api/v1/auth
+	CSRFCookieName    string   `mapstructure:"csrf_cookie_name"`    // Script-readable cookie holding the session's CSRF token
+	CookieDomain      string   `mapstructure:"cookie_domain"`       // Empty for a host-only cookie
+	SameSite          string   `mapstructure:"same_site"`           // "lax", "strict" or "none"
+	Secure            bool     `mapstructure:"secure"`              // Send cookies over HTTPS only
+	CSRFSecret        string   `mapstructure:"csrf_secret"`         // HMAC key for CSRF tokens; derived from the JWT secret when empty
+}
+
+type OnboardingJobsConfig struct {
+	Enabled             bool `mapstructure:"enabled"`               // Run queued onboarding jobs
+	PollIntervalSeconds int  `mapstructure:"poll_interval_seconds"` // Seconds between queue polls
+	MaxConcurrentJobs   int  `mapstructure:"max_concurrent_jobs"`   // Jobs processed at once per instance
+	MaxAttempts         int  `mapstructure:"max_attempts"`          // Attempts before a job fails and is compensated
+	StuckAfterMinutes   int  `mapstructure:"stuck_after_minutes"`   // Minutes in progress before a job is listed as stuck
+}
+
+type OnboardingEventsConfig struct {
+	Enabled             bool `mapstructure:"enabled"`               // Relay the onboarding outbox to the event bus
+	PollIntervalSeconds int  `mapstructure:"poll_interval_seconds"` // Seconds between relay passes
+	BatchSize           int  `mapstructure:"batch_size"`            // Outbox entries published per pass
+	RetentionHours      int  `mapstructure:"retention_hours"`       // Hours published entries are kept
+}
+
+// GeoIPConfig controls signup and login geolocation. Signups are checked
+// against the country rules; masked, high-risk logins are flagged for the
+// fraud scorer.
+type GeoIPConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`         // Look up signup and login addresses
+	APIKey         string `mapstructure:"api_key"`         // IPQualityScore API key
+	BaseURL        string `mapstructure:"base_url"`        // Lookup endpoint; the key and IP are appended
+	Strictness     int    `mapstructure:"strictness"`      // Provider proxy detection strictness, 0-3
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // Lookup timeout
+	HighRiskScore  int    `mapstructure:"high_risk_score"` // Risk score at or above which a VPN, proxy or Tor login is flagged
+	FlagTTLHours   int    `mapstructure:"flag_ttl_hours"`  // Hours a flagged login raises the user's fraud score
+}
+
+// EDDConfig controls enhanced due diligence, where verified users submit
+// proof of funds for higher limits. It is rolled out to a percentage of users.
+type EDDConfig struct {
+	Enabled              bool    `mapstructure:"enabled"`                // Accept enhanced due diligence submissions
+	RolloutPercent       int     `mapstructure:"rollout_percent"`        // Share of users, 0-100, offered higher limits
+	DailyDepositLimit    float64 `mapstructure:"daily_deposit_limit"`    // Enhanced tier daily deposit limit in USD
+	DailyWithdrawalLimit float64 `mapstructure:"daily_withdrawal_limit"` // Enhanced tier daily withdrawal limit in USD
+	DailyTradeLimit      float64 `mapstructure:"daily_trade_limit"`      // Enhanced tier daily trade limit in USD
+}
+
+// ApprovalsConfig sets the maker-checker policies for large withdrawals and
+// sensitive admin actions
+type ApprovalsConfig struct {
+	WithdrawalThreshold               float64  `mapstructure:"withdrawal_threshold"`                 // Withdrawals above this USD amount wait for review
+	WithdrawalRequiredApprovals       int      `mapstructure:"withdrawal_required_approvals"`        // Distinct admins who must approve a held withdrawal
+	BasketDeleteRequiredApprovals     int      `mapstructure:"basket_delete_required_approvals"`     // Admins, besides the requester, who must approve a basket deletion
+	LedgerAdjustmentRequiredApprovals int      `mapstructure:"ledger_adjustment_required_approvals"` // Admins, besides the requester, who must approve a manual ledger adjustment
+	Approvers                         []string `mapstructure:"approvers"`                            // Admin user IDs allowed to review; empty allows any admin
+}
+
+// RatesConfig controls the historical exchange rate store used to value
+// transactions at the time they happened
+type RatesConfig struct {
+	ProviderEnabled   bool   `mapstructure:"provider_enabled"`    // Backfill and fill gaps from the CoinGecko price history API
+	ProviderAPIKey    string `mapstructure:"provider_api_key"`    // CoinGecko Pro API key; empty uses the public API
+	ProviderBaseURL   string `mapstructure:"provider_base_url"`   // Price history API base URL
+	TimeoutSeconds    int    `mapstructure:"timeout_seconds"`     // Provider request timeout
+	MaxSkewHours      int    `mapstructure:"max_skew_hours"`      // Furthest a stored rate may be from the time it values
+	BackfillChunkDays int    `mapstructure:"backfill_chunk_days"` // Days of history requested from the provider at once
+}
+
+// MarketDataConfig controls streaming quotes to connected clients
+type MarketDataConfig struct {
+	StreamEnabled    bool   `mapstructure:"stream_enabled"`    // Consume Alpaca's real-time quote stream during market hours
+	StreamURL        string `mapstructure:"stream_url"`        // Quote stream URL including the feed; empty picks IEX for the Alpaca environment
+	MaxSymbols       int    `mapstructure:"max_symbols"`       // Symbols one client connection may subscribe to
+	ThrottleMillis   int    `mapstructure:"throttle_millis"`   // Minimum gap between quote updates sent to one connection
+	HeartbeatSeconds int    `mapstructure:"heartbeat_seconds"` // Idle interval before an SSE heartbeat comment
+}
+
+// GoalsConfig sets the return assumptions used to plan investment goals and
+// how often progress is checked
+type GoalsConfig struct {
+	Enabled                bool    `mapstructure:"enabled"`                  // Run the daily progress sweep that marks goals achieved and nudges users behind plan
+	ConservativeReturn     float64 `mapstructure:"conservative_return"`      // Expected annual return for conservative goals, e.g. 0.04
+	BalancedReturn         float64 `mapstructure:"balanced_return"`          // Expected annual return for balanced goals
+	GrowthReturn           float64 `mapstructure:"growth_return"`            // Expected annual return for growth goals
+	BehindTolerancePercent float64 `mapstructure:"behind_tolerance_percent"` // Shortfall against plan, 0-100, before a user is nudged
+	NudgeCooldownDays      int     `mapstructure:"nudge_cooldown_days"`      // Minimum days between nudges for one goal
+	IntervalHours          int     `mapstructure:"interval_hours"`           // Time between progress sweeps
+}
+
+// SweepConfig controls auto-sweep, where users opt in to having buying power
+// above a floor invested into a basket on a cadence
+type SweepConfig struct {
+	Enabled         bool    `mapstructure:"enabled"`           // Run the sweep worker
+	DefaultBasketID string  `mapstructure:"default_basket_id"` // Cash basket used when a user does not choose one
+	MinAmount       float64 `mapstructure:"min_amount"`        // Smallest sweep in USD
+	MaxFailures     int     `mapstructure:"max_failures"`      // Failed runs in a row before a rule is turned off
+	IntervalMinutes int     `mapstructure:"interval_minutes"`  // Time between checks for due rules
+}
+
+// WarehouseConfig chooses the data warehouse business events are exported to
+// and which streams are sent
+type WarehouseConfig struct {
+	Enabled         bool                     `mapstructure:"enabled"`          // Run the export worker
+	Destination     string                   `mapstructure:"destination"`      // "bigquery" or "snowflake"
+	Streams         []string                 `mapstructure:"streams"`          // signups, deposits, trades, churn_signal; empty exports all
+	BatchSize       int                      `mapstructure:"batch_size"`       // Events per write to the warehouse
+	IntervalMinutes int                      `mapstructure:"interval_minutes"` // Time between export runs
+	BigQuery        WarehouseBigQueryConfig  `mapstructure:"bigquery"`
+	Snowflake       WarehouseSnowflakeConfig `mapstructure:"snowflake"`
+}
+
+// WarehouseBigQueryConfig locates the BigQuery events table
+type WarehouseBigQueryConfig struct {
+	ProjectID       string `mapstructure:"project_id"`
+	Dataset         string `mapstructure:"dataset"`
+	Table           string `mapstructure:"table"`
+	CredentialsJSON string `mapstructure:"credentials_json"` // Service account key with BigQuery Data Editor on the table
+}
+
+// WarehouseSnowflakeConfig locates the Snowflake events table and the
+// key-pair user that writes to it
+type WarehouseSnowflakeConfig struct {
+	Account    string `mapstructure:"account"`
+	User       string `mapstructure:"user"`
+	PrivateKey string `mapstructure:"private_key"` // PEM
+	Warehouse  string `mapstructure:"warehouse"`
+	Database   string `mapstructure:"database"`
+	Schema     string `mapstructure:"schema"`
+	Role       string `mapstructure:"role"`
+	Table      string `mapstructure:"table"`
+}
+
+// FaultInjectionConfig allows admins to inject faults into outbound provider
+// calls. It has no effect in production.
+type FaultInjectionConfig struct {
+	Enabled            bool `mapstructure:"enabled"`              // Allow fault rules outside production
+	DefaultTTLMinutes  int  `mapstructure:"default_ttl_minutes"`  // Lifetime of a rule created without a TTL
+	RuleRefreshSeconds int  `mapstructure:"rule_refresh_seconds"` // Seconds between reloads of rules created on other instances
+}
+
+// OrderBlotterConfig controls the daily order blotter kept for regulatory
+// reporting
+type OrderBlotterConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`          // Generate each settled day automatically
+	Format          string `mapstructure:"format"`           // "csv" or "fix"
+	FIXDelimiter    string `mapstructure:"fix_delimiter"`    // Field separator in FIX output; "SOH" for the standard \x01
+	Venue           string `mapstructure:"venue"`            // Venue reported for orders routed to the brokerage
+	Timezone        string `mapstructure:"timezone"`         // Business day boundaries
+	LookbackDays    int    `mapstructure:"lookback_days"`    // Past days checked for a missing blotter on each run
+	SettleHours     int    `mapstructure:"settle_hours"`     // Hours after midnight before a day is generated
+	IntervalMinutes int    `mapstructure:"interval_minutes"` // Time between runs
+}
+
+// UploadsConfig controls document uploads through pre-signed URLs
+type UploadsConfig struct {
+	Storage              string              `mapstructure:"storage"` // "s3"; empty turns uploads off
+	MaxSizeMB            int                 `mapstructure:"max_size_mb"`
+	AllowedContentTypes  []string            `mapstructure:"allowed_content_types"`
+	UploadURLMinutes     int                 `mapstructure:"upload_url_minutes"`     // How long a client has to send the file
+	DownloadURLMinutes   int                 `mapstructure:"download_url_minutes"`   // Admin download links
+	ProviderURLHours     int                 `mapstructure:"provider_url_hours"`     // Links handed to KYC providers
+	RequireScan          bool                `mapstructure:"require_scan"`           // Refuse uploads while no scanner is configured
+	RequireUploads       bool                `mapstructure:"require_uploads"`        // Refuse documents given as external URLs
+	PurgeIntervalMinutes int                 `mapstructure:"purge_interval_minutes"` // Time between purges of abandoned uploads
+	S3                   UploadsS3Config     `mapstructure:"s3"`
+	ClamAV               UploadsClamAVConfig `mapstructure:"clamav"`
+
+	// RegionalS3 holds buckets by residency region, e.g. eu, for users whose
+	// files must stay there; S3 serves residency.home_region
+	RegionalS3 map[string]UploadsS3Config `mapstructure:"regional_s3"`
+}
+
+// UploadsS3Config locates the bucket uploads are stored in
+type UploadsS3Config struct {
+	Bucket          string `mapstructure:"bucket"`
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"` // S3-compatible service; empty for AWS
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PathStyle       bool   `mapstructure:"path_style"`
+}
+
+// UploadsClamAVConfig locates the clamd daemon uploads are scanned with
+type UploadsClamAVConfig struct {
+	Address        string `mapstructure:"address"` // host:port; empty skips scanning
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+}
+
+// NotificationsConfig controls notifications held for quiet hours and the
+// daily digest
+type NotificationsConfig struct {
+	ReleaseIntervalSeconds int `mapstructure:"release_interval_seconds"` // Time between checks for held notifications that are due
+	ReleaseBatchSize       int `mapstructure:"release_batch_size"`       // Held notifications released per check
+}
+
+// ShadowConfig replays sampled live traffic against rewritten services
+// and logs where their responses differ
+type ShadowConfig struct {
+	Enabled        bool                `mapstructure:"enabled"`         // Replay traffic to the routes below
+	MaxBodyBytes   int                 `mapstructure:"max_body_bytes"`  // Larger requests are not shadowed
+	QueueSize      int                 `mapstructure:"queue_size"`      // Replays buffered before new ones are dropped
+	Workers        int                 `mapstructure:"workers"`         // Replays run concurrently
+	TimeoutSeconds int                 `mapstructure:"timeout_seconds"` // How long a candidate may take to respond
+	IgnoreFields   []string            `mapstructure:"ignore_fields"`   // JSON keys never compared, e.g. request_id
+	Routes         []ShadowRouteConfig `mapstructure:"routes"`
+}
+
+// ShadowRouteConfig shadows one route to a separately deployed candidate
+type ShadowRouteConfig struct {
+	Method        string   `mapstructure:"method"`
+	Path          string   `mapstructure:"path"`           // Route as registered, e.g. /api/v1/funding/deposits/:id
+	Target        string   `mapstructure:"target"`         // Base URL of the candidate deployment
+	SamplePercent float64  `mapstructure:"sample_percent"` // Share of the route's requests replayed, 0-100
+	AllowWrites   bool     `mapstructure:"allow_writes"`   // Replay state-changing methods; the candidate must be dry-run
+	IgnoreFields  []string `mapstructure:"ignore_fields"`
+}
+
+// PasswordPolicyConfig is the policy for new passwords. The minimum length
+// is security.password_min_length.
+type PasswordPolicyConfig struct {
+	MinCharClasses       int    `mapstructure:"min_char_classes"`       // Of lowercase, uppercase, digits and symbols
+	PassphraseLength     int    `mapstructure:"passphrase_length"`      // Passwords this long need no mix of character types
+	DisallowPersonalInfo bool   `mapstructure:"disallow_personal_info"` // Reject passwords built from the user's email or phone
+	BreachCheck          bool   `mapstructure:"breach_check"`           // Reject passwords seen in known breaches
+	BreachAPIURL         string `mapstructure:"breach_api_url"`         // Pwned Passwords range API base URL
+	BreachTimeoutMs      int    `mapstructure:"breach_timeout_ms"`      // Breach lookups slower than this are skipped
+	GraceDays            int    `mapstructure:"grace_days"`             // Days existing weak passwords may be kept after the first prompt
+}
+
+// JobJanitorConfig sets the leases workers hold on wallet provisioning and
+// funding event jobs, and the janitor that requeues jobs whose lease expired
+type JobJanitorConfig struct {
+	Enabled             bool `mapstructure:"enabled"`
+	IntervalSeconds     int  `mapstructure:"interval_seconds"`
+	BatchSize           int  `mapstructure:"batch_size"`            // Jobs released per queue per sweep
+	AlertThreshold      int  `mapstructure:"alert_threshold"`       // Stuck jobs in one queue that log an alert
+	WalletLeaseSeconds  int  `mapstructure:"wallet_lease_seconds"`  // Lease on a wallet provisioning job, renewed by heartbeat
+	FundingLeaseSeconds int  `mapstructure:"funding_lease_seconds"` // Lease on a funding event job, renewed by heartbeat
+}
+
+// BankVerificationConfig sets up the bank-linking provider that checks saved
+// bank recipients are open and in the user's name before ACH payouts
+type BankVerificationConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	BaseURL          string  `mapstructure:"base_url"`
+	ClientID         string  `mapstructure:"client_id"`
+	Secret           string  `mapstructure:"secret"`
+	TimeoutSeconds   int     `mapstructure:"timeout_seconds"`
+	RequiredAboveUSD float64 `mapstructure:"required_above_usd"` // Payouts above this need a verified account; 0 requires it for all
+}
+
+// LatencyBudgetConfig bounds how long API requests may run and when the
+// server sheds load instead of queueing it
+type LatencyBudgetConfig struct {
+	DefaultTimeoutMs  int                        `mapstructure:"default_timeout_ms"`  // Budget for routes without their own; 0 leaves them unbounded
+	Routes            []LatencyBudgetRouteConfig `mapstructure:"routes"`              // Per-route budgets, longest matching path wins
+	MaxInFlight       int                        `mapstructure:"max_in_flight"`       // Concurrent requests before new ones get 503; 0 disables
+	MaxGoroutines     int                        `mapstructure:"max_goroutines"`      // Goroutines before new requests get 503; 0 disables
+	RetryAfterSeconds int                        `mapstructure:"retry_after_seconds"` // Retry-After sent with 503s
+	ShedExempt        []string                   `mapstructure:"shed_exempt"`         // Path prefixes never shed
+}
+
+// LatencyBudgetRouteConfig overrides the budget for routes under a path
+type LatencyBudgetRouteConfig struct {
+	Method    string `mapstructure:"method"` // Empty matches every method
+	Path      string `mapstructure:"path"`   // Route prefix as registered, e.g. /api/v1/admin
+	TimeoutMs int    `mapstructure:"timeout_ms"`
+}
+
+// IntegrityConfig schedules the nightly data integrity checker
+type IntegrityConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // Run the checker nightly
+	RunHourUTC      int  `mapstructure:"run_hour_utc"`     // Hour after which the nightly run starts
+	IntervalMinutes int  `mapstructure:"interval_minutes"` // Minutes between checks for a due run
+	GraceHours      int  `mapstructure:"grace_hours"`      // Hours a record may be inconsistent before it is reported
+	MaxPerCheck     int  `mapstructure:"max_per_check"`    // Anomalies reported per class per run
+	OpenCases       bool `mapstructure:"open_cases"`       // Open an admin case per user and anomaly class
+}
+
+// DelegatesConfig limits the read-only access users grant to advisors and
+// family members
+type DelegatesConfig struct {
+	MaxGrantDays      int `mapstructure:"max_grant_days"`      // Longest a grant may last, and its default
+	SessionTTLMinutes int `mapstructure:"session_ttl_minutes"` // How long a delegate stays signed in
+	MaxGrantsPerUser  int `mapstructure:"max_grants_per_user"` // Active grants per user
+}
+
+// WebhookArchiveConfig locates the cold storage raw provider webhooks are
+// archived to
+type WebhookArchiveConfig struct {
+	Enabled   bool            `mapstructure:"enabled"`    // Archive verified webhooks
+	Storage   string          `mapstructure:"storage"`    // "s3" or "local"
+	LocalDir  string          `mapstructure:"local_dir"`  // Directory of the local store
+	S3        UploadsS3Config `mapstructure:"s3"`         // Bucket of the s3 store
+	KeyPrefix string          `mapstructure:"key_prefix"` // Prepended to the keys payloads are stored under
+}
+
+// AlertingConfig routes reconciliation and risk alerts to on-call channels
+// by severity and whether the support team is working
+type AlertingConfig struct {
+	PagerDutyRoutingKey string                      `mapstructure:"pagerduty_routing_key"` // Events API v2 integration key; empty disables PagerDuty
+	PagerDutyEventsURL  string                      `mapstructure:"pagerduty_events_url"`
+	SlackWebhookURL     string                      `mapstructure:"slack_webhook_url"` // Incoming webhook; empty disables Slack
+	WebhookURL          string                      `mapstructure:"webhook_url"`       // Receives alerts none of whose routed channels is configured; defaults to reconciliation.alert_webhook_url
+	Timezone            string                      `mapstructure:"timezone"`          // IANA zone the business hours are in
+	BusinessDays        []string                    `mapstructure:"business_days"`     // "mon" to "sun"
+	BusinessStartHour   int                         `mapstructure:"business_start_hour"`
+	BusinessEndHour     int                         `mapstructure:"business_end_hour"`    // Exclusive
+	DedupWindowMinutes  int                         `mapstructure:"dedup_window_minutes"` // Repeats of an alert within this window are not sent again
+	MaxAttempts         int                         `mapstructure:"max_attempts"`         // Sends per channel before a delivery is recorded as failed
+	TimeoutSeconds      int                         `mapstructure:"timeout_seconds"`
+	Routes              map[string]AlertRouteConfig `mapstructure:"routes"` // By severity: critical, warning, info
+}
+
+// AlertRouteConfig lists the channels ("pagerduty", "slack", "webhook") an
+// alert severity goes to inside and outside business hours
+type AlertRouteConfig struct {
+	BusinessHours []string `mapstructure:"business_hours"`
+	AfterHours    []string `mapstructure:"after_hours"` // Empty holds alerts back until business hours
+}
+
+// CacheWarmConfig controls the Redis cache in front of the balance,
+// portfolio and basket endpoints and the warm-up run after each deploy
+type CacheWarmConfig struct {
+	OnStartup         bool `mapstructure:"on_startup"`       // Warm the cache once the server starts
+	UserTTLSeconds    int  `mapstructure:"user_ttl_seconds"` // Balances and portfolios
+	BasketTTLSeconds  int  `mapstructure:"basket_ttl_seconds"`
+	ActiveWindowHours int  `mapstructure:"active_window_hours"` // Users with a session used within this window are warmed
+	ViewWindowDays    int  `mapstructure:"view_window_days"`    // How long basket views count towards the most viewed
+	MaxUsers          int  `mapstructure:"max_users"`
+	MaxBaskets        int  `mapstructure:"max_baskets"`
+	Concurrency       int  `mapstructure:"concurrency"` // Loads in flight during a warm-up
+}
+
+// LoggingConfig sets log levels per module on top of log_level. Changes to
+// modules in the config file apply without a restart.
+type LoggingConfig struct {
+	Modules            map[string]string `mapstructure:"modules"`              // Module name to debug, info, warn or error
+	OverrideMinutes    int               `mapstructure:"override_minutes"`     // How long an admin level change lasts when no duration is given
+	MaxOverrideMinutes int               `mapstructure:"max_override_minutes"` // Longest an admin level change may last
+}
+
+// ResidencyConfig controls where user data is kept
+type ResidencyConfig struct {
+	HomeRegion string `mapstructure:"home_region"` // us or eu; where the database and uploads.s3 are
+}
+
+// RewardsConfig sets the limits rewards are earned and redeemed under. Earning
+// rules themselves are managed through the admin API.
+type RewardsConfig struct {
+	PointsPerDollar int     `mapstructure:"points_per_dollar"` // Points shown for each dollar earned on points rules
+	MonthlyCap      float64 `mapstructure:"monthly_cap"`       // Most a user can earn in a calendar month, in USD
+	RiskThreshold   float64 `mapstructure:"risk_threshold"`    // Fraud score at which a reward is held for review
+	MinRedemption   float64 `mapstructure:"min_redemption"`    // Smallest redemption, in USD
+}
+
+// PortfolioSharingConfig controls the public links users make to snapshots of
+// their performance
+type PortfolioSharingConfig struct {
+	SigningKey      string `mapstructure:"signing_key"`       // HMAC key for share tokens; the JWT secret when empty
+	DefaultTTLHours int    `mapstructure:"default_ttl_hours"` // Link lifetime when the user does not pick one
+	MaxTTLHours     int    `mapstructure:"max_ttl_hours"`     // Longest lifetime a user can pick
+	MaxLiveLinks    int    `mapstructure:"max_live_links"`    // Unexpired, unrevoked links a user may hold
 }
 
 // ZeroGConfig contains configuration for 0G Network integration
@@ -248,6 +1000,7 @@ type ZeroGNamespaces struct {
 	AISummaries  string `mapstructure:"ai_summaries"`  // ai-summaries/ namespace
 	AIArtifacts  string `mapstructure:"ai_artifacts"`  // ai-artifacts/ namespace
 	ModelPrompts string `mapstructure:"model_prompts"` // model-prompts/ namespace
+	AuditAnchors string `mapstructure:"audit_anchors"` // audit-anchors/ namespace
 }
 
 // ZeroGModelConfig contains AI model configuration
@@ -363,6 +1116,9 @@ func setDefaults() {
 	viper.SetDefault("security.lockout_duration", 900) // 15 minutes
 	viper.SetDefault("security.require_mfa", false)
 	viper.SetDefault("security.password_min_length", 8)
+	viper.SetDefault("security.field_encryption_active_version", 1)
+	viper.SetDefault("security.pii_vault_active_version", 1)
+	viper.SetDefault("security.request_signing_tolerance", 300) // 5 minutes
 
 	// Circle defaults
 	viper.SetDefault("circle.environment", "sandbox")
@@ -371,6 +1127,8 @@ func setDefaults() {
 	viper.SetDefault("circle.default_wallet_set_id", "")
 	viper.SetDefault("circle.default_wallet_set_name", "STACK-WalletSet")
 	viper.SetDefault("circle.supported_chains", []string{"SOL-DEVNET"})
+	viper.SetDefault("circle.notification_callback_url", "")
+	viper.SetDefault("circle.subscription_check_minutes", 15)
 
 	// KYC defaults
 	viper.SetDefault("kyc.provider", "")
@@ -378,6 +1136,7 @@ func setDefaults() {
 	viper.SetDefault("kyc.base_url", "https://netverify.com")
 	viper.SetDefault("kyc.user_agent", "Stack-Service/1.0")
 	viper.SetDefault("kyc.level_name", "basic-kyc")
+	viper.SetDefault("kyc.edd_level_name", "")
 
 	// Email defaults
 	viper.SetDefault("email.provider", "")
@@ -415,6 +1174,7 @@ func setDefaults() {
 	viper.SetDefault("zerog.storage.namespaces.ai_summaries", "ai-summaries/")
 	viper.SetDefault("zerog.storage.namespaces.ai_artifacts", "ai-artifacts/")
 	viper.SetDefault("zerog.storage.namespaces.model_prompts", "model-prompts/")
+	viper.SetDefault("zerog.storage.namespaces.audit_anchors", "audit-anchors/")
 
 	// Compute defaults
 	viper.SetDefault("zerog.compute.broker_endpoint", "")
@@ -442,6 +1202,408 @@ func setDefaults() {
 	// Worker defaults
 	viper.SetDefault("workers.count", 10)
 	viper.SetDefault("workers.job_timeout", 300)
+
+	// Audit defaults
+	viper.SetDefault("audit.anchor_enabled", true)
+	viper.SetDefault("audit.anchor_interval_minutes", 60)
+	viper.SetDefault("audit.anchor_storage", "0g")
+	viper.SetDefault("audit.anchor_upload_command", "/usr/local/bin/0g-storage-client")
+
+	// Retention defaults
+	viper.SetDefault("retention.enabled", true)
+	viper.SetDefault("retention.dry_run", false)
+	viper.SetDefault("retention.interval_hours", 24)
+	viper.SetDefault("retention.batch_size", 1000)
+
+	// Backup verification defaults
+	viper.SetDefault("backup_verify.backup_dir", "/var/backups/stack")
+	viper.SetDefault("backup_verify.backup_pattern", "*.dump")
+	viper.SetDefault("backup_verify.pg_restore_path", "pg_restore")
+	viper.SetDefault("backup_verify.max_backup_age_hours", 26)
+	viper.SetDefault("backup_verify.max_row_drift_percent", 5.0)
+
+	// Dormant account monitor defaults
+	viper.SetDefault("inactivity.enabled", true)
+	viper.SetDefault("inactivity.interval_hours", 24)
+	viper.SetDefault("inactivity.reminder_months", []int{6, 9, 11})
+	viper.SetDefault("inactivity.case_after_months", 12)
+
+	// Outbound partner webhook defaults
+	viper.SetDefault("webhooks.enabled", true)
+	viper.SetDefault("webhooks.poll_interval_seconds", 10)
+	viper.SetDefault("webhooks.max_attempts", 12)
+	viper.SetDefault("webhooks.timeout_seconds", 10)
+	viper.SetDefault("webhooks.allow_http", false)
+
+	// Live event stream defaults
+	viper.SetDefault("event_stream.heartbeat_seconds", 15)
+	viper.SetDefault("event_stream.max_len", 500)
+	viper.SetDefault("event_stream.retention_hours", 24)
+
+	viper.SetDefault("event_bus.driver", "inprocess")
+	viper.SetDefault("event_bus.topic_prefix", "stack.")
+	viper.SetDefault("event_bus.max_attempts", 5)
+
+	viper.SetDefault("wallet_backfill.batch_size", 50)
+	viper.SetDefault("wallet_backfill.batch_interval_seconds", 60)
+	viper.SetDefault("wallet_backfill.max_in_flight", 200)
+
+	viper.SetDefault("reconciliation.aggregation_concurrency", 10)
+
+	viper.SetDefault("balance_cache.refresh_interval_seconds", 60)
+	viper.SetDefault("balance_cache.max_age_seconds", 300)
+	viper.SetDefault("balance_cache.batch_size", 200)
+	viper.SetDefault("balance_cache.concurrency", 8)
+	viper.SetDefault("balance_cache.force_refresh_limit", 3)
+	viper.SetDefault("balance_cache.force_refresh_window_seconds", 60)
+
+	// Per-chain confirmation thresholds come from the chain registry; entries
+	// here override it
+	viper.SetDefault("deposits.default_confirmations", 1)
+	viper.SetDefault("deposits.confirmations", map[string]int{})
+	viper.SetDefault("deposits.instant_percent", 0)
+	viper.SetDefault("deposits.instant_max_amount", 1000)
+	viper.SetDefault("deposits.bank_webhook_secret", "")
+
+	viper.SetDefault("chains.enabled", []string{"SOL-DEVNET"})
+
+	viper.SetDefault("suspense.enabled", true)
+	viper.SetDefault("suspense.interval_minutes", 60)
+	viper.SetDefault("suspense.aging_alert_days", 7)
+
+	viper.SetDefault("promotions.enabled", true)
+	viper.SetDefault("promotions.interval_minutes", 60)
+	viper.SetDefault("promotions.batch_size", 200)
+
+	viper.SetDefault("billing.enabled", true)
+	viper.SetDefault("billing.interval_minutes", 60)
+	viper.SetDefault("billing.dunning_retry_hours", []int{24, 72, 168})
+
+	viper.SetDefault("startup.required", []string{})
+	viper.SetDefault("startup.retry_interval_seconds", 5)
+	viper.SetDefault("startup.max_retry_interval_seconds", 300)
+
+	viper.SetDefault("ai_artifacts.link_ttl_minutes", 15)
+	viper.SetDefault("ai_artifacts.export_page_size", 100)
+	viper.SetDefault("ai_artifacts.storage", "")
+	viper.SetDefault("ai_artifacts.local_dir", "data/ai-artifacts")
+	viper.SetDefault("ai_artifacts.migrate_from", []string{})
+	viper.SetDefault("ai_artifacts.migration_interval_minutes", 60)
+	viper.SetDefault("ai_artifacts.migration_batch_size", 100)
+	viper.SetDefault("ai_artifacts.delete_migrated_source", false)
+
+	viper.SetDefault("trading_fees.commission_bps", 0)
+	viper.SetDefault("trading_fees.minimum_commission", 0)
+	viper.SetDefault("trading_fees.sec_fee_rate", 0.0000278)
+	viper.SetDefault("trading_fees.taf_per_share", 0.000166)
+	viper.SetDefault("trading_fees.taf_maximum", 8.30)
+
+	viper.SetDefault("limit_orders.enabled", true)
+	viper.SetDefault("limit_orders.interval_seconds", 30)
+	viper.SetDefault("limit_orders.batch_size", 500)
+	viper.SetDefault("limit_orders.max_gtc_days", 90)
+
+	viper.SetDefault("paper_trading.enabled", true)
+	viper.SetDefault("paper_trading.starting_balance", 10000)
+
+	viper.SetDefault("stuck_orders.sla_minutes", 30)
+	viper.SetDefault("stuck_orders.list_limit", 200)
+
+	viper.SetDefault("ops_digest.enabled", true)
+	viper.SetDefault("ops_digest.recipients", []string{})
+	viper.SetDefault("ops_digest.send_hour_utc", 6)
+	viper.SetDefault("ops_digest.interval_minutes", 15)
+
+	// HTTP capture defaults: nothing is sampled until configured; admins can
+	// still flag users and routes
+	viper.SetDefault("http_capture.enabled", true)
+	viper.SetDefault("http_capture.sample_percent", 0)
+	viper.SetDefault("http_capture.ttl_hours", 72)
+	viper.SetDefault("http_capture.max_body_bytes", 65536)
+	viper.SetDefault("http_capture.target_refresh_seconds", 30)
+	viper.SetDefault("http_capture.queue_size", 1000)
+
+	// API usage analytics defaults
+	viper.SetDefault("api_usage.enabled", true)
+	viper.SetDefault("api_usage.flush_seconds", 60)
+	viper.SetDefault("api_usage.retention_days", 30)
+
+	// Withdrawal address book defaults
+	viper.SetDefault("recipients.first_use_hold_hours", 24)
+	viper.SetDefault("recipients.max_per_user", 50)
+	viper.SetDefault("recipients.address_check.enabled", false)
+	viper.SetDefault("recipients.address_check.required_above_usd", 1000)
+	viper.SetDefault("recipients.address_check.new_for_days", 30)
+	viper.SetDefault("recipients.address_check.micro_deposit_ttl_hours", 72)
+	viper.SetDefault("recipients.address_check.signature_ttl_minutes", 15)
+	viper.SetDefault("recipients.address_check.max_attempts", 3)
+
+	// Web session defaults: bearer tokens only until cookie sessions are enabled
+	viper.SetDefault("web_session.auth_modes", []string{"bearer"})
+	viper.SetDefault("web_session.access_cookie_name", "stack_access")
+	viper.SetDefault("web_session.refresh_cookie_name", "stack_refresh")
+	viper.SetDefault("web_session.csrf_cookie_name", "stack_csrf")
+	viper.SetDefault("web_session.same_site", "lax")
+	viper.SetDefault("web_session.secure", true)
+
+	// Onboarding job worker defaults
+	viper.SetDefault("onboarding_jobs.enabled", true)
+	viper.SetDefault("onboarding_jobs.poll_interval_seconds", 30)
+	viper.SetDefault("onboarding_jobs.max_concurrent_jobs", 5)
+	viper.SetDefault("onboarding_jobs.max_attempts", 5)
+	viper.SetDefault("onboarding_jobs.stuck_after_minutes", 30)
+
+	// Onboarding event outbox relay defaults
+	viper.SetDefault("onboarding_events.enabled", true)
+	viper.SetDefault("onboarding_events.poll_interval_seconds", 5)
+	viper.SetDefault("onboarding_events.batch_size", 100)
+	viper.SetDefault("onboarding_events.retention_hours", 72)
+
+	// Geo-IP defaults: off until an API key is configured
+	viper.SetDefault("geoip.enabled", false)
+	viper.SetDefault("geoip.base_url", "https://ipqualityscore.com/api/json/ip")
+	viper.SetDefault("geoip.strictness", 1)
+	viper.SetDefault("geoip.timeout_seconds", 5)
+	viper.SetDefault("geoip.high_risk_score", 75)
+	viper.SetDefault("geoip.flag_ttl_hours", 24)
+
+	// Enhanced due diligence defaults
+	viper.SetDefault("edd.enabled", false)
+	viper.SetDefault("edd.rollout_percent", 0)
+	viper.SetDefault("edd.daily_deposit_limit", 50000)
+	viper.SetDefault("edd.daily_withdrawal_limit", 25000)
+	viper.SetDefault("edd.daily_trade_limit", 50000)
+
+	// Maker-checker approval defaults
+	viper.SetDefault("approvals.withdrawal_threshold", 10000)
+	viper.SetDefault("approvals.withdrawal_required_approvals", 2)
+	viper.SetDefault("approvals.basket_delete_required_approvals", 1)
+	viper.SetDefault("approvals.ledger_adjustment_required_approvals", 1)
+	viper.SetDefault("approvals.approvers", []string{})
+
+	// Historical exchange rate defaults
+	viper.SetDefault("rates.provider_enabled", false)
+	viper.SetDefault("rates.provider_api_key", "")
+	viper.SetDefault("rates.provider_base_url", "https://api.coingecko.com/api/v3")
+	viper.SetDefault("rates.timeout_seconds", 10)
+	viper.SetDefault("rates.max_skew_hours", 24)
+	viper.SetDefault("rates.backfill_chunk_days", 30)
+
+	// Market data streaming defaults
+	viper.SetDefault("market_data.stream_enabled", false)
+	viper.SetDefault("market_data.stream_url", "")
+	viper.SetDefault("market_data.max_symbols", 50)
+	viper.SetDefault("market_data.throttle_millis", 1000)
+	viper.SetDefault("market_data.heartbeat_seconds", 15)
+
+	// Investment goal defaults
+	viper.SetDefault("goals.enabled", false)
+	viper.SetDefault("goals.conservative_return", 0.04)
+	viper.SetDefault("goals.balanced_return", 0.06)
+	viper.SetDefault("goals.growth_return", 0.08)
+	viper.SetDefault("goals.behind_tolerance_percent", 5.0)
+	viper.SetDefault("goals.nudge_cooldown_days", 7)
+	viper.SetDefault("goals.interval_hours", 24)
+
+	// Email and SMS failover defaults
+	viper.SetDefault("messaging.sandbox_capture", false)
+	viper.SetDefault("messaging.failure_threshold", 3)
+	viper.SetDefault("messaging.cooldown_seconds", 300)
+
+	// Bulk admin operation defaults
+	viper.SetDefault("bulk_ops.enabled", true)
+	viper.SetDefault("bulk_ops.max_users", 5000)
+	viper.SetDefault("bulk_ops.batch_size", 50)
+	viper.SetDefault("bulk_ops.max_attempts", 3)
+	viper.SetDefault("bulk_ops.poll_interval_seconds", 5)
+
+	// General ledger export defaults
+	viper.SetDefault("accounting.enabled", false)
+	viper.SetDefault("accounting.destination", "csv")
+	viper.SetDefault("accounting.timezone", "UTC")
+	viper.SetDefault("accounting.lookback_days", 7)
+	viper.SetDefault("accounting.settle_hours", 2)
+	viper.SetDefault("accounting.interval_minutes", 60)
+	viper.SetDefault("accounting.csv_dir", "exports/accounting")
+	viper.SetDefault("accounting.sftp.port", 22)
+	viper.SetDefault("accounting.quickbooks.environment", "sandbox")
+
+	// Business onboarding defaults
+	viper.SetDefault("kyb.enabled", false)
+	viper.SetDefault("kyb.daily_deposit_limit", 250000)
+	viper.SetDefault("kyb.daily_withdrawal_limit", 100000)
+	viper.SetDefault("kyb.daily_trade_limit", 250000)
+
+	// Auto-sweep defaults
+	viper.SetDefault("sweep.enabled", false)
+	viper.SetDefault("sweep.default_basket_id", "")
+	viper.SetDefault("sweep.min_amount", 10)
+	viper.SetDefault("sweep.max_failures", 3)
+	viper.SetDefault("sweep.interval_minutes", 15)
+
+	// Data warehouse export defaults
+	viper.SetDefault("warehouse.enabled", false)
+	viper.SetDefault("warehouse.destination", "bigquery")
+	viper.SetDefault("warehouse.batch_size", 500)
+	viper.SetDefault("warehouse.interval_minutes", 5)
+	viper.SetDefault("warehouse.bigquery.table", "events")
+	viper.SetDefault("warehouse.snowflake.table", "EVENTS")
+
+	// Fault injection defaults
+	viper.SetDefault("fault_injection.enabled", false)
+	viper.SetDefault("fault_injection.default_ttl_minutes", 30)
+	viper.SetDefault("fault_injection.rule_refresh_seconds", 15)
+
+	// Order blotter defaults
+	viper.SetDefault("order_blotter.enabled", false)
+	viper.SetDefault("order_blotter.format", "csv")
+	viper.SetDefault("order_blotter.fix_delimiter", "|")
+	viper.SetDefault("order_blotter.venue", "ALPACA")
+	viper.SetDefault("order_blotter.timezone", "America/New_York")
+	viper.SetDefault("order_blotter.lookback_days", 7)
+	viper.SetDefault("order_blotter.settle_hours", 2)
+	viper.SetDefault("order_blotter.interval_minutes", 60)
+
+	// Upload defaults
+	viper.SetDefault("uploads.storage", "")
+	viper.SetDefault("uploads.max_size_mb", 10)
+	viper.SetDefault("uploads.allowed_content_types", []string{"image/jpeg", "image/png", "image/webp", "application/pdf"})
+	viper.SetDefault("uploads.upload_url_minutes", 15)
+	viper.SetDefault("uploads.download_url_minutes", 5)
+	viper.SetDefault("uploads.provider_url_hours", 24)
+	viper.SetDefault("uploads.require_scan", false)
+	viper.SetDefault("uploads.require_uploads", false)
+	viper.SetDefault("uploads.purge_interval_minutes", 60)
+	viper.SetDefault("uploads.clamav.timeout_seconds", 60)
+
+	// Notification defaults
+	viper.SetDefault("notifications.release_interval_seconds", 60)
+	viper.SetDefault("notifications.release_batch_size", 500)
+
+	// Traffic shadowing defaults
+	viper.SetDefault("shadow.enabled", false)
+	viper.SetDefault("shadow.max_body_bytes", 262144)
+	viper.SetDefault("shadow.queue_size", 500)
+	viper.SetDefault("shadow.workers", 4)
+	viper.SetDefault("shadow.timeout_seconds", 10)
+	viper.SetDefault("shadow.ignore_fields", []string{"request_id", "timestamp"})
+
+	// Password policy defaults
+	viper.SetDefault("password_policy.min_char_classes", 3)
+	viper.SetDefault("password_policy.passphrase_length", 20)
+	viper.SetDefault("password_policy.disallow_personal_info", true)
+	viper.SetDefault("password_policy.breach_check", true)
+	viper.SetDefault("password_policy.breach_api_url", "https://api.pwnedpasswords.com")
+	viper.SetDefault("password_policy.breach_timeout_ms", 2000)
+	viper.SetDefault("password_policy.grace_days", 30)
+
+	// Job lease and janitor defaults
+	viper.SetDefault("job_janitor.enabled", true)
+	viper.SetDefault("job_janitor.interval_seconds", 60)
+	viper.SetDefault("job_janitor.batch_size", 100)
+	viper.SetDefault("job_janitor.alert_threshold", 10)
+	viper.SetDefault("job_janitor.wallet_lease_seconds", 300)
+	viper.SetDefault("job_janitor.funding_lease_seconds", 120)
+
+	viper.SetDefault("bank_verification.enabled", false)
+	viper.SetDefault("bank_verification.timeout_seconds", 10)
+	viper.SetDefault("bank_verification.required_above_usd", 1000)
+
+	viper.SetDefault("latency_budget.default_timeout_ms", 30000)
+	viper.SetDefault("latency_budget.routes", []map[string]interface{}{
+		{"path": "/health", "timeout_ms": 10000},
+		{"path": "/ready", "timeout_ms": 5000},
+		{"path": "/api/v1/admin", "timeout_ms": 10000},
+		// Admin jobs run inline and would be cut off partway by the admin budget
+		{"method": "POST", "path": "/api/v1/admin/integrity/runs", "timeout_ms": 120000},
+		{"method": "POST", "path": "/api/v1/admin/retention/run", "timeout_ms": 600000},
+		{"method": "POST", "path": "/api/v1/admin/rates/backfill", "timeout_ms": 600000},
+		{"method": "GET", "path": "/api/v1/admin/audit/verify", "timeout_ms": 300000},
+		{"method": "POST", "path": "/api/v1/admin/accounting/exports/reexport", "timeout_ms": 600000},
+		{"method": "POST", "path": "/api/v1/admin/regulatory/blotters", "timeout_ms": 300000},
+		{"method": "POST", "path": "/api/v1/admin/users/:id/funding/replay", "timeout_ms": 300000},
+		// Streams stay open for as long as the client listens
+		{"path": "/api/v1/events/stream", "timeout_ms": 0},
+		{"path": "/api/v1/market/quotes/stream", "timeout_ms": 0},
+	})
+	viper.SetDefault("latency_budget.max_in_flight", 0)
+	viper.SetDefault("latency_budget.max_goroutines", 0)
+	viper.SetDefault("latency_budget.retry_after_seconds", 2)
+	viper.SetDefault("latency_budget.shed_exempt", []string{"/health", "/ready", "/live", "/metrics"})
+
+	viper.SetDefault("integrity.enabled", true)
+	viper.SetDefault("integrity.run_hour_utc", 3)
+	viper.SetDefault("integrity.interval_minutes", 15)
+	viper.SetDefault("integrity.grace_hours", 24)
+	viper.SetDefault("integrity.max_per_check", 500)
+	viper.SetDefault("integrity.open_cases", true)
+
+	// Delegated account access defaults
+	viper.SetDefault("delegates.max_grant_days", 365)
+	viper.SetDefault("delegates.session_ttl_minutes", 60)
+	viper.SetDefault("delegates.max_grants_per_user", 5)
+
+	// Webhook archive defaults
+	viper.SetDefault("webhook_archive.enabled", true)
+	viper.SetDefault("webhook_archive.storage", "local")
+	viper.SetDefault("webhook_archive.local_dir", "data/webhook-archive")
+	viper.SetDefault("webhook_archive.key_prefix", "webhooks/")
+
+	// Alert routing defaults: page for critical alerts at any hour, Slack for the rest
+	viper.SetDefault("alerting.pagerduty_routing_key", "")
+	viper.SetDefault("alerting.pagerduty_events_url", "https://events.pagerduty.com/v2/enqueue")
+	viper.SetDefault("alerting.slack_webhook_url", "")
+	viper.SetDefault("alerting.webhook_url", "")
+	viper.SetDefault("alerting.timezone", "UTC")
+	viper.SetDefault("alerting.business_days", []string{"mon", "tue", "wed", "thu", "fri"})
+	viper.SetDefault("alerting.business_start_hour", 9)
+	viper.SetDefault("alerting.business_end_hour", 18)
+	viper.SetDefault("alerting.dedup_window_minutes", 240)
+	viper.SetDefault("alerting.max_attempts", 3)
+	viper.SetDefault("alerting.timeout_seconds", 10)
+	viper.SetDefault("alerting.routes", map[string]interface{}{
+		"critical": map[string]interface{}{
+			"business_hours": []string{"pagerduty", "slack"},
+			"after_hours":    []string{"pagerduty"},
+		},
+		"warning": map[string]interface{}{
+			"business_hours": []string{"slack"},
+			"after_hours":    []string{"slack"},
+		},
+		"info": map[string]interface{}{
+			"business_hours": []string{"slack"},
+			"after_hours":    []string{},
+		},
+	})
+
+	// Hot endpoint cache defaults
+	viper.SetDefault("cache_warm.on_startup", true)
+	viper.SetDefault("cache_warm.user_ttl_seconds", 120)
+	viper.SetDefault("cache_warm.basket_ttl_seconds", 300)
+	viper.SetDefault("cache_warm.active_window_hours", 1)
+	viper.SetDefault("cache_warm.view_window_days", 7)
+	viper.SetDefault("cache_warm.max_users", 500)
+	viper.SetDefault("cache_warm.max_baskets", 50)
+	viper.SetDefault("cache_warm.concurrency", 8)
+
+	// Module log level defaults
+	viper.SetDefault("logging.modules", map[string]string{})
+	viper.SetDefault("logging.override_minutes", 15)
+	viper.SetDefault("logging.max_override_minutes", 240)
+
+	// Residency defaults
+	viper.SetDefault("residency.home_region", "us")
+
+	// Rewards defaults
+	viper.SetDefault("rewards.points_per_dollar", 100)
+	viper.SetDefault("rewards.monthly_cap", 100)
+	viper.SetDefault("rewards.risk_threshold", 0.5)
+	viper.SetDefault("rewards.min_redemption", 1)
+
+	// Portfolio sharing defaults
+	viper.SetDefault("portfolio_sharing.default_ttl_hours", 168)
+	viper.SetDefault("portfolio_sharing.max_ttl_hours", 720)
+	viper.SetDefault("portfolio_sharing.max_live_links", 20)
 }
 
 func overrideFromEnv() {
@@ -461,11 +1623,37 @@ func overrideFromEnv() {
 	if jwtSecret := os.Getenv("JWT_SECRET"); jwtSecret != "" {
 		viper.Set("jwt.secret", jwtSecret)
 	}
+	if csrfSecret := os.Getenv("CSRF_SECRET"); csrfSecret != "" {
+		viper.Set("web_session.csrf_secret", csrfSecret)
+	}
 
 	// Encryption
 	if encKey := os.Getenv("ENCRYPTION_KEY"); encKey != "" {
 		viper.Set("security.encryption_key", encKey)
 	}
+	if fieldKeys := os.Getenv("FIELD_ENCRYPTION_KEYS"); fieldKeys != "" {
+		viper.Set("security.field_encryption_keys", fieldKeys)
+	}
+	if fieldKeyVersion := os.Getenv("FIELD_ENCRYPTION_ACTIVE_VERSION"); fieldKeyVersion != "" {
+		viper.Set("security.field_encryption_active_version", fieldKeyVersion)
+	}
+	if fieldIndexKey := os.Getenv("FIELD_INDEX_KEY"); fieldIndexKey != "" {
+		viper.Set("security.field_index_key", fieldIndexKey)
+	}
+	if vaultKeys := os.Getenv("PII_VAULT_KEYS"); vaultKeys != "" {
+		viper.Set("security.pii_vault_keys", vaultKeys)
+	}
+	if vaultKeyVersion := os.Getenv("PII_VAULT_ACTIVE_VERSION"); vaultKeyVersion != "" {
+		viper.Set("security.pii_vault_active_version", vaultKeyVersion)
+	}
+	if bankWebhookSecret := os.Getenv("BANK_WEBHOOK_SECRET"); bankWebhookSecret != "" {
+		viper.Set("deposits.bank_webhook_secret", bankWebhookSecret)
+	}
+
+	// Backup verification
+	if scratchURL := os.Getenv("RESTORE_DRILL_DATABASE_URL"); scratchURL != "" {
+		viper.Set("backup_verify.scratch_database_url", scratchURL)
+	}
 
 	// Circle API
 	if circleKey := os.Getenv("CIRCLE_API_KEY"); circleKey != "" {
@@ -478,6 +1666,18 @@ func overrideFromEnv() {
 	if circleEntitySecretCiphertext := os.Getenv("CIRCLE_ENTITY_SECRET_CIPHERTEXT"); circleEntitySecretCiphertext != "" {
 		viper.Set("circle.entity_secret_ciphertext", circleEntitySecretCiphertext)
 	}
+	if circleEntitySecret := os.Getenv("CIRCLE_ENTITY_SECRET"); circleEntitySecret != "" {
+		viper.Set("circle.entity_secret", circleEntitySecret)
+	}
+	if circleEntitySecretFile := os.Getenv("CIRCLE_ENTITY_SECRET_FILE"); circleEntitySecretFile != "" {
+		viper.Set("circle.entity_secret_file", circleEntitySecretFile)
+	}
+	if circleEntityPublicKey := os.Getenv("CIRCLE_ENTITY_PUBLIC_KEY"); circleEntityPublicKey != "" {
+		viper.Set("circle.entity_public_key", circleEntityPublicKey)
+	}
+	if circleCallbackURL := os.Getenv("CIRCLE_NOTIFICATION_CALLBACK_URL"); circleCallbackURL != "" {
+		viper.Set("circle.notification_callback_url", circleCallbackURL)
+	}
 	if circleWalletSetID := os.Getenv("CIRCLE_DEFAULT_WALLET_SET_ID"); circleWalletSetID != "" {
 		viper.Set("circle.default_wallet_set_id", circleWalletSetID)
 	}
@@ -533,6 +1733,27 @@ func overrideFromEnv() {
 	if sumsubLevelName := os.Getenv("SUMSUB_LEVEL_NAME"); sumsubLevelName != "" {
 		viper.Set("kyc.level_name", sumsubLevelName)
 	}
+	if eddLevelName := os.Getenv("KYC_EDD_LEVEL_NAME"); eddLevelName != "" {
+		viper.Set("kyc.edd_level_name", eddLevelName)
+	}
+
+	// Geo-IP
+	if geoIPKey := os.Getenv("GEOIP_API_KEY"); geoIPKey != "" {
+		viper.Set("geoip.api_key", geoIPKey)
+	}
+
+	// Alert routing
+	if pagerDutyKey := os.Getenv("PAGERDUTY_ROUTING_KEY"); pagerDutyKey != "" {
+		viper.Set("alerting.pagerduty_routing_key", pagerDutyKey)
+	}
+	if slackWebhook := os.Getenv("SLACK_ALERT_WEBHOOK_URL"); slackWebhook != "" {
+		viper.Set("alerting.slack_webhook_url", slackWebhook)
+	}
+
+	// Exchange rate provider
+	if ratesKey := os.Getenv("RATES_PROVIDER_API_KEY"); ratesKey != "" {
+		viper.Set("rates.provider_api_key", ratesKey)
+	}
 
 	// Email Service
 	if emailAPIKey := os.Getenv("EMAIL_API_KEY"); emailAPIKey != "" {
@@ -567,6 +1788,57 @@ func overrideFromEnv() {
 		viper.Set("email.reply_to", replyTo)
 	}
 
+	// Sandbox capture keeps staging from emailing and texting real users
+	if sandboxCapture := os.Getenv("MESSAGING_SANDBOX_CAPTURE"); sandboxCapture != "" {
+		if capture, err := strconv.ParseBool(sandboxCapture); err == nil {
+			viper.Set("messaging.sandbox_capture", capture)
+		}
+	}
+
+	// General ledger export credentials
+	if qbSecret := os.Getenv("QUICKBOOKS_CLIENT_SECRET"); qbSecret != "" {
+		viper.Set("accounting.quickbooks.client_secret", qbSecret)
+	}
+	if qbRefreshToken := os.Getenv("QUICKBOOKS_REFRESH_TOKEN"); qbRefreshToken != "" {
+		viper.Set("accounting.quickbooks.refresh_token", qbRefreshToken)
+	}
+	if sftpPassword := os.Getenv("ACCOUNTING_SFTP_PASSWORD"); sftpPassword != "" {
+		viper.Set("accounting.sftp.password", sftpPassword)
+	}
+	if sftpKey := os.Getenv("ACCOUNTING_SFTP_PRIVATE_KEY"); sftpKey != "" {
+		viper.Set("accounting.sftp.private_key", sftpKey)
+	}
+
+	// Document upload bucket credentials
+	if uploadsAccessKey := os.Getenv("UPLOADS_S3_ACCESS_KEY_ID"); uploadsAccessKey != "" {
+		viper.Set("uploads.s3.access_key_id", uploadsAccessKey)
+	}
+	if uploadsSecretKey := os.Getenv("UPLOADS_S3_SECRET_ACCESS_KEY"); uploadsSecretKey != "" {
+		viper.Set("uploads.s3.secret_access_key", uploadsSecretKey)
+	}
+	if euAccessKey := os.Getenv("UPLOADS_EU_S3_ACCESS_KEY_ID"); euAccessKey != "" {
+		viper.Set("uploads.regional_s3.eu.access_key_id", euAccessKey)
+	}
+	if euSecretKey := os.Getenv("UPLOADS_EU_S3_SECRET_ACCESS_KEY"); euSecretKey != "" {
+		viper.Set("uploads.regional_s3.eu.secret_access_key", euSecretKey)
+	}
+
+	// Webhook archive bucket credentials
+	if archiveAccessKey := os.Getenv("WEBHOOK_ARCHIVE_S3_ACCESS_KEY_ID"); archiveAccessKey != "" {
+		viper.Set("webhook_archive.s3.access_key_id", archiveAccessKey)
+	}
+	if archiveSecretKey := os.Getenv("WEBHOOK_ARCHIVE_S3_SECRET_ACCESS_KEY"); archiveSecretKey != "" {
+		viper.Set("webhook_archive.s3.secret_access_key", archiveSecretKey)
+	}
+
+	// AI artifact bucket credentials
+	if artifactsAccessKey := os.Getenv("AI_ARTIFACTS_S3_ACCESS_KEY_ID"); artifactsAccessKey != "" {
+		viper.Set("ai_artifacts.s3.access_key_id", artifactsAccessKey)
+	}
+	if artifactsSecretKey := os.Getenv("AI_ARTIFACTS_S3_SECRET_ACCESS_KEY"); artifactsSecretKey != "" {
+		viper.Set("ai_artifacts.s3.secret_access_key", artifactsSecretKey)
+	}
+
 	// 0G Network
 	// Storage configuration
 	if zeroGStorageRPC := os.Getenv("ZEROG_STORAGE_RPC_ENDPOINT"); zeroGStorageRPC != "" {
@@ -617,6 +1889,14 @@ func overrideFromEnv() {
 	if dueBaseURL := os.Getenv("DUE_BASE_URL"); dueBaseURL != "" {
 		viper.Set("due.base_url", dueBaseURL)
 	}
+
+	// Bank-linking provider
+	if bankLinkClientID := os.Getenv("BANK_LINK_CLIENT_ID"); bankLinkClientID != "" {
+		viper.Set("bank_verification.client_id", bankLinkClientID)
+	}
+	if bankLinkSecret := os.Getenv("BANK_LINK_SECRET"); bankLinkSecret != "" {
+		viper.Set("bank_verification.secret", bankLinkSecret)
+	}
 }
 
 func validate(config *Config) error {
@@ -638,5 +1918,36 @@ func validate(config *Config) error {
 		return fmt.Errorf("circle supported chains configuration is required")
 	}
 
+	if err := validateWebSession(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateWebSession(config *Config) error {
+	ws := config.WebSession
+	if len(ws.AuthModes) == 0 {
+		return fmt.Errorf("web_session.auth_modes must enable bearer, cookie or both")
+	}
+	for _, mode := range ws.AuthModes {
+		if mode != "bearer" && mode != "cookie" {
+			return fmt.Errorf("web_session.auth_modes: unknown mode %q", mode)
+		}
+	}
+	switch strings.ToLower(ws.SameSite) {
+	case "lax", "strict":
+	case "none":
+		if !ws.Secure {
+			return fmt.Errorf("web_session.same_site none requires secure cookies")
+		}
+		for _, origin := range config.Server.AllowedOrigins {
+			if origin == "*" {
+				return fmt.Errorf("web_session.same_site none cannot be combined with a wildcard allowed origin")
+			}
+		}
+	default:
+		return fmt.Errorf("web_session.same_site must be lax, strict or none")
+	}
 	return nil
 }
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// WatchLogLevels calls apply with log_level and logging.modules now and again
+// whenever the config file changes. Without a config file it only applies
+// the current values.
+func WatchLogLevels(apply func(base string, modules map[string]string)) {
+	apply(viper.GetString("log_level"), viper.GetStringMapString("logging.modules"))
+	if viper.ConfigFileUsed() == "" {
+		return
+	}
+	viper.OnConfigChange(func(fsnotify.Event) {
+		apply(viper.GetString("log_level"), viper.GetStringMapString("logging.modules"))
+	})
+	viper.WatchConfig()
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// Serializable transactions are retried this many times in total before the
+// serialization failure is returned to the caller
+const (
+	serializableMaxAttempts = 5
+	serializableBaseDelay   = 10 * time.Millisecond
+)
+
+// Postgres aborts one of two conflicting serializable transactions with
+// serialization_failure, and breaks lock cycles with deadlock_detected. Both
+// succeed when the transaction is run again.
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// IsSerializationFailure reports whether err aborted a transaction only
+// because it conflicted with a concurrent one, so running it again is safe
+func IsSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
+
+// WithSerializableTransaction runs fn in a SERIALIZABLE transaction and
+// commits it, running fn again in a fresh transaction when Postgres aborts it
+// for conflicting with a concurrent one. fn may run more than once, so it must
+// not have effects outside the transaction. operation labels the retry
+// metrics.
+func WithSerializableTransaction(ctx context.Context, db *sql.DB, operation string, fn func(*sql.Tx) error) error {
+	return retrySerializable(ctx, operation, func() error {
+		tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// WithSerializableTransactionx is WithSerializableTransaction for sqlx
+func WithSerializableTransactionx(ctx context.Context, db *sqlx.DB, operation string, fn func(*sqlx.Tx) error) error {
+	return retrySerializable(ctx, operation, func() error {
+		tx, err := db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// retrySerializable runs attempt until it succeeds, fails for another reason
+// or the attempts run out, backing off with jitter between conflicts
+func retrySerializable(ctx context.Context, operation string, attempt func() error) error {
+	var err error
+	for i := 1; i <= serializableMaxAttempts; i++ {
+		err = attempt()
+		if err == nil {
+			metrics.SerializableTransactions.WithLabelValues(operation, metrics.SerializableOutcomeCommitted).Inc()
+			return nil
+		}
+		if !IsSerializationFailure(err) {
+			metrics.SerializableTransactions.WithLabelValues(operation, metrics.SerializableOutcomeFailed).Inc()
+			return err
+		}
+		if i == serializableMaxAttempts {
+			break
+		}
+		metrics.SerializableTransactionRetries.WithLabelValues(operation).Inc()
+
+		delay := serializableBaseDelay << (i - 1)
+		delay += time.Duration(rand.Int63n(int64(delay)))
+		select {
+		case <-ctx.Done():
+			metrics.SerializableTransactions.WithLabelValues(operation, metrics.SerializableOutcomeFailed).Inc()
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	metrics.SerializableTransactions.WithLabelValues(operation, metrics.SerializableOutcomeExhausted).Inc()
+	return fmt.Errorf("%s conflicted with concurrent transactions %d times: %w", operation, serializableMaxAttempts, err)
+}
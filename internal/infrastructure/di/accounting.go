@@ -0,0 +1,83 @@
+package di
+
+import (
+	"time"
+
+	accountingexport "github.com/stack-service/stack_service/internal/adapters/accounting"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/accounting"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// newAccountingService builds the general ledger export from configuration.
+// A misconfigured destination is logged and leaves exports off; journals can
+// still be previewed.
+func (c *Container) newAccountingService() *accounting.Service {
+	cfg := c.Config.Accounting
+
+	location := time.UTC
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			c.ZapLog.Warn("Invalid accounting timezone, using UTC", zap.String("timezone", cfg.Timezone), zap.Error(err))
+		} else {
+			location = loc
+		}
+	}
+
+	mapping := accounting.Mapping{
+		Accounts:        make(map[entities.AccountType]string, len(cfg.AccountMapping)),
+		SuspenseAccount: cfg.SuspenseAccount,
+		RoundingAccount: cfg.RoundingAccount,
+	}
+	for accountType, glAccount := range cfg.AccountMapping {
+		mapping.Accounts[entities.AccountType(accountType)] = glAccount
+	}
+
+	service := accounting.NewService(repositories.NewAccountingExportRepository(c.DB, c.ZapLog), accounting.Config{
+		Mapping:      mapping,
+		Location:     location,
+		LookbackDays: cfg.LookbackDays,
+		SettleDelay:  time.Duration(cfg.SettleHours) * time.Hour,
+		Interval:     time.Duration(cfg.IntervalMinutes) * time.Minute,
+	}, c.ZapLog)
+
+	switch cfg.Destination {
+	case "quickbooks":
+		qb := cfg.QuickBooks
+		if qb.ClientID == "" || qb.ClientSecret == "" || qb.RefreshToken == "" || qb.RealmID == "" {
+			c.ZapLog.Warn("QuickBooks accounting export is missing credentials; exports are off")
+			break
+		}
+		service.SetExporter(accountingexport.NewQuickBooksExporter(accountingexport.QuickBooksConfig{
+			ClientID:     qb.ClientID,
+			ClientSecret: qb.ClientSecret,
+			RefreshToken: qb.RefreshToken,
+			RealmID:      qb.RealmID,
+			Environment:  qb.Environment,
+		}, c.ZapLog))
+	case "csv":
+		var store accountingexport.FileStore = accountingexport.NewLocalDirectory(cfg.CSVDir)
+		if cfg.SFTP.Host != "" {
+			sftp, err := accountingexport.NewSFTPStore(accountingexport.SFTPConfig{
+				Host:       cfg.SFTP.Host,
+				Port:       cfg.SFTP.Port,
+				User:       cfg.SFTP.User,
+				Password:   cfg.SFTP.Password,
+				PrivateKey: cfg.SFTP.PrivateKey,
+				HostKey:    cfg.SFTP.HostKey,
+				Dir:        cfg.SFTP.Dir,
+			})
+			if err != nil {
+				c.ZapLog.Warn("Invalid accounting SFTP configuration; exports are off", zap.Error(err))
+				break
+			}
+			store = sftp
+		}
+		service.SetExporter(accountingexport.NewCSVExporter(store))
+	default:
+		c.ZapLog.Warn("Unknown accounting export destination; exports are off", zap.String("destination", cfg.Destination))
+	}
+	return service
+}
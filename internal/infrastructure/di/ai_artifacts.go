@@ -0,0 +1,115 @@
+package di
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/adapters/artifactstore"
+	uploadstore "github.com/stack-service/stack_service/internal/adapters/upload"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+)
+
+// NewArtifactStore builds the named AI artifact store from configuration
+func NewArtifactStore(cfg *config.Config, name string) (aiartifacts.ArtifactStore, error) {
+	switch name {
+	case aiartifacts.StorageZeroG:
+		return artifactstore.NewZeroGStore(cfg.ZeroG.Storage.IndexerRPC, time.Duration(cfg.ZeroG.Timeout)*time.Second)
+	case aiartifacts.StorageS3:
+		s3 := cfg.AIArtifacts.S3
+		return artifactstore.NewS3Store(uploadstore.S3Config{
+			Bucket:          s3.Bucket,
+			Region:          s3.Region,
+			Endpoint:        s3.Endpoint,
+			AccessKeyID:     s3.AccessKeyID,
+			SecretAccessKey: s3.SecretAccessKey,
+			PathStyle:       s3.PathStyle,
+		})
+	case aiartifacts.StorageLocal:
+		return artifactstore.NewLocalStore(cfg.AIArtifacts.LocalDir)
+	default:
+		return nil, fmt.Errorf("unknown AI artifact storage %q", name)
+	}
+}
+
+// NewArtifactMigrator builds the migrator that moves AI artifacts from the
+// stores in migrate_from, or from to when given, to the configured storage
+func NewArtifactMigrator(cfg *config.Config, db *sql.DB, log *zap.Logger, from ...string) (*aiartifacts.Migrator, error) {
+	if cfg.AIArtifacts.Storage == "" {
+		return nil, fmt.Errorf("ai_artifacts.storage names no store to migrate to")
+	}
+	target, err := NewArtifactStore(cfg, cfg.AIArtifacts.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if len(from) == 0 {
+		from = cfg.AIArtifacts.MigrateFrom
+	}
+	var sources []aiartifacts.ArtifactStore
+	for _, name := range from {
+		source, err := NewArtifactStore(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	migrationConfig := aiartifacts.DefaultMigrationConfig()
+	if prefix := cfg.ZeroG.Storage.Namespaces.AISummaries; prefix != "" {
+		migrationConfig.KeyPrefix = prefix
+	}
+	migrationConfig.BatchSize = cfg.AIArtifacts.MigrationBatchSize
+	migrationConfig.Interval = time.Duration(cfg.AIArtifacts.MigrationIntervalMinutes) * time.Minute
+	migrationConfig.DeleteSource = cfg.AIArtifacts.DeleteMigratedSource
+	return aiartifacts.NewMigrator(repositories.NewAISummaryRepository(db, log), sources, target, migrationConfig, log)
+}
+
+// newAIArtifactService builds per-user listing and export of stored AI
+// summaries. Bodies can be read from the configured store and from every
+// store being migrated away from; a store that cannot be built is left out
+// and its artifacts are served from the database copy.
+func (c *Container) newAIArtifactService() *aiartifacts.Service {
+	cfg := c.Config.AIArtifacts
+	artifactKey := cfg.SigningKey
+	if artifactKey == "" {
+		artifactKey = c.Config.JWT.Secret
+	}
+	service := aiartifacts.NewService(
+		repositories.NewAISummaryRepository(c.DB, c.ZapLog),
+		aiartifacts.Config{
+			SigningKey: []byte(artifactKey),
+			LinkTTL:    time.Duration(cfg.LinkTTLMinutes) * time.Minute,
+			PageSize:   cfg.ExportPageSize,
+		},
+		c.ZapLog,
+	)
+
+	var names []string
+	if cfg.Storage != "" {
+		names = append(names, cfg.Storage)
+	}
+	names = append(names, cfg.MigrateFrom...)
+	for _, name := range names {
+		store, err := NewArtifactStore(c.Config, name)
+		if err != nil {
+			c.ZapLog.Warn("Invalid AI artifact storage configuration; serving stored copies",
+				zap.String("storage", name), zap.Error(err))
+			continue
+		}
+		service.SetStore(store)
+	}
+
+	if cfg.Storage != "" && len(cfg.MigrateFrom) > 0 {
+		migrator, err := NewArtifactMigrator(c.Config, c.DB, c.ZapLog)
+		if err != nil {
+			c.ZapLog.Warn("Invalid AI artifact migration configuration; artifacts stay where they are", zap.Error(err))
+		} else {
+			c.AIArtifactMigrator = migrator
+		}
+	}
+	return service
+}
@@ -20,6 +20,7 @@ func (c *Container) InitializeBrokerageOnboarding() *services.BrokerageOnboardin
 func (c *Container) InitializeInstantFunding(firmAccountNumber string) *services.InstantFundingService {
 	sqlxDB := sqlx.NewDb(c.DB, "postgres")
 	virtualAccountRepo := repositories.NewVirtualAccountRepository(sqlxDB)
+	virtualAccountRepo.SetFieldEncryptor(c.FieldEncryptor)
 	
 	return services.NewInstantFundingService(
 		c.AlpacaService,
@@ -0,0 +1,32 @@
+package di
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/adapters/auditanchor"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+)
+
+// NewAuditAnchorStore builds the external store audit chain anchors are
+// mirrored to and verified against. It returns nil when audit.anchor_storage
+// is empty, leaving anchors in the database only.
+func NewAuditAnchorStore(cfg *config.Config) (adapters.AuditAnchorStore, error) {
+	switch cfg.Audit.AnchorStorage {
+	case "":
+		return nil, nil
+	case "0g":
+		storage := cfg.ZeroG.Storage
+		return auditanchor.NewZeroGStore(auditanchor.ZeroGConfig{
+			UploadCommand: cfg.Audit.AnchorUploadCommand,
+			RPCEndpoint:   storage.RPCEndpoint,
+			IndexerRPC:    storage.IndexerRPC,
+			PrivateKey:    storage.PrivateKey,
+			Namespace:     storage.Namespaces.AuditAnchors,
+			Timeout:       time.Duration(cfg.ZeroG.Timeout) * time.Second,
+		})
+	default:
+		return nil, fmt.Errorf("unknown audit anchor storage %q", cfg.Audit.AnchorStorage)
+	}
+}
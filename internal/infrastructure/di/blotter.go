@@ -0,0 +1,46 @@
+package di
+
+import (
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/blotter"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// newBlotterService builds the regulatory order blotter from configuration.
+// An unknown format or timezone is logged and replaced by the default.
+func (c *Container) newBlotterService() *blotter.Service {
+	cfg := c.Config.OrderBlotter
+
+	var location *time.Location
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			c.ZapLog.Warn("Invalid order blotter timezone, using the default", zap.String("timezone", cfg.Timezone), zap.Error(err))
+		} else {
+			location = loc
+		}
+	}
+
+	format := entities.BlotterFormat(cfg.Format)
+	if cfg.Format != "" && !format.IsValid() {
+		c.ZapLog.Warn("Unknown order blotter format, using csv", zap.String("format", cfg.Format))
+	}
+
+	delimiter := cfg.FIXDelimiter
+	if delimiter == "SOH" {
+		delimiter = "\x01"
+	}
+
+	return blotter.NewService(repositories.NewBlotterRepository(c.DB, c.ZapLog), blotter.Config{
+		Format:       format,
+		FIXDelimiter: delimiter,
+		Venue:        cfg.Venue,
+		Location:     location,
+		LookbackDays: cfg.LookbackDays,
+		SettleDelay:  time.Duration(cfg.SettleHours) * time.Hour,
+		Interval:     time.Duration(cfg.IntervalMinutes) * time.Minute,
+	}, c.ZapLog)
+}
@@ -0,0 +1,70 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+)
+
+// NewChainRegistry builds the chain registry from the built-in catalog, the
+// configured definitions and the list of enabled chains
+func NewChainRegistry(cfg *config.Config) (*entities.ChainRegistry, error) {
+	chains := entities.DefaultChains()
+	index := make(map[entities.WalletChain]int, len(chains))
+	for i, chain := range chains {
+		index[chain.ID] = i
+	}
+
+	for _, def := range cfg.Chains.Definitions {
+		id := entities.WalletChain(strings.ToUpper(strings.TrimSpace(def.ID)))
+		i, builtin := index[id]
+		if !builtin {
+			chains = append(chains, entities.ChainInfo{ID: id, Testnet: def.Testnet})
+			i = len(chains) - 1
+			index[id] = i
+		}
+		chain := &chains[i]
+		if def.Name != "" {
+			chain.Name = def.Name
+		}
+		if def.Family != "" {
+			chain.Family = entities.ChainFamily(def.Family)
+		}
+		if def.NativeCurrency != "" {
+			chain.NativeCurrency = def.NativeCurrency
+		}
+		if def.USDCTokenAddress != "" {
+			chain.USDCTokenAddress = def.USDCTokenAddress
+		}
+		if def.ExplorerURL != "" {
+			chain.ExplorerURL = def.ExplorerURL
+		}
+		if def.Confirmations != 0 {
+			chain.Confirmations = def.Confirmations
+		}
+		if def.AddressPattern != "" {
+			chain.AddressPattern = def.AddressPattern
+		}
+		if len(def.Aliases) > 0 {
+			chain.Aliases = def.Aliases
+		}
+	}
+
+	if len(cfg.Chains.Enabled) > 0 {
+		for i := range chains {
+			chains[i].Enabled = false
+		}
+		for _, raw := range cfg.Chains.Enabled {
+			id := entities.WalletChain(strings.ToUpper(strings.TrimSpace(raw)))
+			i, ok := index[id]
+			if !ok {
+				return nil, fmt.Errorf("enabled chain %s is not defined", id)
+			}
+			chains[i].Enabled = true
+		}
+	}
+
+	return entities.NewChainRegistry(chains)
+}
@@ -0,0 +1,56 @@
+package di
+
+import "time"
+
+// clockSetter is implemented by services whose notion of now can be replaced
+type clockSetter interface {
+	SetClock(now func() time.Time)
+}
+
+// useClock points every schedule-dependent service at the container's clock
+func (c *Container) useClock() {
+	now := func() time.Time { return c.Clock.Now() }
+	services := []clockSetter{
+		c.PasscodeService,
+		c.AllocationService,
+		c.ConsentService,
+		c.RateService,
+		c.GoalService,
+		c.SweepService,
+		c.BulkOpsService,
+		c.AccountingService,
+		c.WarehouseService,
+		c.KYBService,
+		c.APIUsageService,
+		c.FaultInjectionService,
+		c.BlotterService,
+		c.UploadService,
+		c.DocumentService,
+		c.NewsService,
+		c.NotificationService,
+		c.RecipientService,
+		c.PasswordPolicyService,
+		c.LedgerAdjustmentService,
+		c.LinkedWalletService,
+		c.ExperimentService,
+		c.IntegrityService,
+		c.DelegateService,
+		c.CostBasisService,
+		c.DeveloperService,
+		c.PIIVaultService,
+		c.DepositReferenceService,
+		c.SuspenseService,
+		c.ProjectionService,
+		c.AlertingService,
+		c.HotCacheService,
+		c.ResidencyService,
+		c.RewardService,
+		c.PortfolioShareService,
+	}
+	if c.MarketDataService != nil {
+		services = append(services, c.MarketDataService)
+	}
+	for _, service := range services {
+		service.SetClock(now)
+	}
+}
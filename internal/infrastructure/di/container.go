@@ -14,25 +14,94 @@ import (
 	"github.com/stack-service/stack_service/internal/adapters/due"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/internal/domain/services/accounting"
+	"github.com/stack-service/stack_service/internal/domain/services/adjustments"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+	"github.com/stack-service/stack_service/internal/domain/services/alerting"
 	"github.com/stack-service/stack_service/internal/domain/services/allocation"
 	"github.com/stack-service/stack_service/internal/domain/services/apikey"
+	"github.com/stack-service/stack_service/internal/domain/services/apiusage"
+	"github.com/stack-service/stack_service/internal/domain/services/approvals"
+	"github.com/stack-service/stack_service/internal/domain/services/attribution"
+	"github.com/stack-service/stack_service/internal/domain/services/balancecache"
+	"github.com/stack-service/stack_service/internal/domain/services/blotter"
+	"github.com/stack-service/stack_service/internal/domain/services/bulkops"
+	"github.com/stack-service/stack_service/internal/domain/services/cases"
+	"github.com/stack-service/stack_service/internal/domain/services/circlesubscription"
+	"github.com/stack-service/stack_service/internal/domain/services/consents"
+	"github.com/stack-service/stack_service/internal/domain/services/costbasis"
+	"github.com/stack-service/stack_service/internal/domain/services/custodial"
+	"github.com/stack-service/stack_service/internal/domain/services/delegates"
+	"github.com/stack-service/stack_service/internal/domain/services/depositref"
+	"github.com/stack-service/stack_service/internal/domain/services/developer"
+	"github.com/stack-service/stack_service/internal/domain/services/documents"
+	"github.com/stack-service/stack_service/internal/domain/services/edd"
 	entitysecret "github.com/stack-service/stack_service/internal/domain/services/entity_secret"
+	"github.com/stack-service/stack_service/internal/domain/services/eventstream"
+	"github.com/stack-service/stack_service/internal/domain/services/experiments"
+	"github.com/stack-service/stack_service/internal/domain/services/faultinject"
 	"github.com/stack-service/stack_service/internal/domain/services/funding"
+	"github.com/stack-service/stack_service/internal/domain/services/geoip"
+	"github.com/stack-service/stack_service/internal/domain/services/goals"
+	"github.com/stack-service/stack_service/internal/domain/services/holds"
+	"github.com/stack-service/stack_service/internal/domain/services/hotcache"
+	"github.com/stack-service/stack_service/internal/domain/services/httpcapture"
+	"github.com/stack-service/stack_service/internal/domain/services/inactivity"
+	"github.com/stack-service/stack_service/internal/domain/services/integrity"
 	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/jurisdiction"
+	"github.com/stack-service/stack_service/internal/domain/services/kyb"
 	"github.com/stack-service/stack_service/internal/domain/services/ledger"
+	"github.com/stack-service/stack_service/internal/domain/services/linkedwallets"
+	"github.com/stack-service/stack_service/internal/domain/services/marketcalendar"
+	"github.com/stack-service/stack_service/internal/domain/services/marketdata"
+	"github.com/stack-service/stack_service/internal/domain/services/news"
 	"github.com/stack-service/stack_service/internal/domain/services/onboarding"
+	"github.com/stack-service/stack_service/internal/domain/services/onboardingevents"
+	"github.com/stack-service/stack_service/internal/domain/services/opsdigest"
+	"github.com/stack-service/stack_service/internal/domain/services/orderops"
+	"github.com/stack-service/stack_service/internal/domain/services/outboundwebhook"
+	"github.com/stack-service/stack_service/internal/domain/services/papertrading"
 	"github.com/stack-service/stack_service/internal/domain/services/passcode"
+	"github.com/stack-service/stack_service/internal/domain/services/passwordpolicy"
+	"github.com/stack-service/stack_service/internal/domain/services/piivault"
+	"github.com/stack-service/stack_service/internal/domain/services/portfolioshare"
+	"github.com/stack-service/stack_service/internal/domain/services/projection"
+	"github.com/stack-service/stack_service/internal/domain/services/promotions"
+	"github.com/stack-service/stack_service/internal/domain/services/rates"
+	"github.com/stack-service/stack_service/internal/domain/services/reactivation"
+	"github.com/stack-service/stack_service/internal/domain/services/recipients"
 	"github.com/stack-service/stack_service/internal/domain/services/reconciliation"
+	"github.com/stack-service/stack_service/internal/domain/services/residency"
+	"github.com/stack-service/stack_service/internal/domain/services/restoredrill"
+	"github.com/stack-service/stack_service/internal/domain/services/retention"
+	"github.com/stack-service/stack_service/internal/domain/services/rewards"
 	"github.com/stack-service/stack_service/internal/domain/services/session"
+	"github.com/stack-service/stack_service/internal/domain/services/shadow"
+	"github.com/stack-service/stack_service/internal/domain/services/subscription"
+	"github.com/stack-service/stack_service/internal/domain/services/suspense"
+	"github.com/stack-service/stack_service/internal/domain/services/sweep"
+	"github.com/stack-service/stack_service/internal/domain/services/trustedcontact"
 	"github.com/stack-service/stack_service/internal/domain/services/twofa"
+	"github.com/stack-service/stack_service/internal/domain/services/upload"
 	"github.com/stack-service/stack_service/internal/domain/services/wallet"
+	"github.com/stack-service/stack_service/internal/domain/services/walletbackfill"
+	"github.com/stack-service/stack_service/internal/domain/services/warehouse"
+	"github.com/stack-service/stack_service/internal/domain/services/webhookarchive"
 	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
 	"github.com/stack-service/stack_service/internal/infrastructure/cache"
 	"github.com/stack-service/stack_service/internal/infrastructure/circle"
 	"github.com/stack-service/stack_service/internal/infrastructure/config"
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"github.com/stack-service/stack_service/internal/workers/event_fanout"
+	"github.com/stack-service/stack_service/pkg/clock"
 	commonmetrics "github.com/stack-service/stack_service/pkg/common/metrics"
+	"github.com/stack-service/stack_service/pkg/crypto"
+	"github.com/stack-service/stack_service/pkg/eventbus"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/retry"
+	"github.com/stack-service/stack_service/pkg/startup"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 	"go.uber.org/zap"
 )
 
@@ -96,6 +165,14 @@ type Container struct {
 	Logger *logger.Logger
 	ZapLog *zap.Logger
 
+	// FieldEncryptor encrypts PII columns at rest
+	FieldEncryptor *crypto.FieldEncryptor
+
+	// Clock is the time source of schedule-dependent services. Services read
+	// it through the container, so a test that swaps in a clocktest.Fake
+	// moves every one of them.
+	Clock clock.Clock
+
 	// Repositories
 	UserRepo                  *repositories.UserRepository
 	OnboardingFlowRepo        *repositories.OnboardingFlowRepository
@@ -107,9 +184,11 @@ type Container struct {
 	WithdrawalRepo            *repositories.WithdrawalRepository
 	ConversionRepo            *repositories.ConversionRepository
 	BalanceRepo               *repositories.BalanceRepository
+	PaperBalanceRepo          *repositories.PaperBalanceRepository
 	FundingEventJobRepo       *repositories.FundingEventJobRepository
 	LedgerRepo                *repositories.LedgerRepository
 	ReconciliationRepo        repositories.ReconciliationRepository
+	CapturedMessageRepo       *repositories.CapturedMessageRepository
 
 	// External Services
 	CircleClient  *circle.Client
@@ -132,21 +211,93 @@ type Container struct {
 	WalletService           *wallet.Service
 	FundingService          *funding.Service
 	InvestingService        *investing.Service
+	PaperInvestingService   *investing.Service
+	PaperTradingService     *papertrading.Service
+	AttributionService      *attribution.Service
+	PortfolioShareService   *portfolioshare.Service
+	MarketCalendarService   *marketcalendar.Service
+	MarketDataService       *marketdata.Service
+	GoalService             *goals.Service
+	SweepService            *sweep.Service
+	ProjectionService       *projection.Service
+	BulkOpsService          *bulkops.Service
+	AccountingService       *accounting.Service
+	WarehouseService        *warehouse.Service
+	FaultInjectionService   *faultinject.Service
+	RetryBudget             *retry.Budget
+	BlotterService          *blotter.Service
+	UploadService           *upload.Service
+	DocumentService         *documents.Service
+	NewsService             *news.Service
+	ShadowService           *shadow.Service
+	PasswordPolicyService   *passwordpolicy.Service
+	LedgerAdjustmentService *adjustments.Service
+	OrderOpsService         *orderops.Service
+	OpsDigestService        *opsdigest.Service
+	HTTPCaptureService      *httpcapture.Service
+	APIUsageService         *apiusage.Service
+	RecipientService        *recipients.Service
+	LinkedWalletService     *linkedwallets.Service
+	ExperimentService       *experiments.Service
+	IntegrityService        *integrity.Service
+	DelegateService         *delegates.Service
+	WebhookArchiveService   *webhookarchive.Service
+	CostBasisService        *costbasis.Service
+	DeveloperService        *developer.Service
+	PIIVaultService         *piivault.Service
+	DepositReferenceService *depositref.Service
+	SuspenseService         *suspense.Service
+	AlertingService         *alerting.Service
+	HotCacheService         *hotcache.Service
+	ResidencyService        *residency.Service
 	DueService              *services.DueService
 	BalanceService          *services.BalanceService
 	EntitySecretService     *entitysecret.Service
 	LedgerService           *ledger.Service
 	ReconciliationService   *reconciliation.Service
 	ReconciliationScheduler *reconciliation.Scheduler
+	RetentionService        *retention.Service
+	RestoreDrillService     *restoredrill.Service
+	WalletBackfillService   *walletbackfill.Service
+	CircleSubscriptions     *circlesubscription.Service
+	BalanceCacheService     *balancecache.Service
+	JurisdictionService     *jurisdiction.Service
+	GeoIPService            *geoip.Service
+	TransactionControl      *services.TransactionControlService
+	CustodialService        *custodial.Service
+	TrustedContactService   *trustedcontact.Service
+	CaseService             *cases.Service
+	InactivityService       *inactivity.Service
+	ReactivationService     *reactivation.Service
+	EDDService              *edd.Service
+	KYBService              *kyb.Service
+	ApprovalService         *approvals.Service
+	RateService             *rates.Service
+	ConsentService          *consents.Service
+	BasketDeletion          *investing.BasketDeletion
+	BasketLocalization      *investing.BasketLocalization
+	BalanceHoldService      *holds.Service
+	PromotionService        *promotions.Service
+	RewardService           *rewards.Service
+	SubscriptionService     *subscription.Service
+	AIArtifactService       *aiartifacts.Service
+	AIArtifactMigrator      *aiartifacts.Migrator
+	OutboundWebhookService  *outboundwebhook.Service
+	EventStreamService      *eventstream.Service
+	EventBus                eventbus.Bus
+	OnboardingEventRelay    *onboardingevents.Relay
 	AllocationService       *allocation.Service
 	NotificationService     *services.NotificationService
 
 	// Additional Repositories
-	OnboardingJobRepo *repositories.OnboardingJobRepository
+	OnboardingJobRepo    *repositories.OnboardingJobRepository
+	OnboardingOutboxRepo *repositories.OnboardingOutboxRepository
 
 	// Workers
 	WalletProvisioningScheduler interface{} // Type interface{} to avoid circular dependency, will be set at runtime
 	FundingWebhookManager       interface{} // Type interface{} to avoid circular dependency, will be set at runtime
+	WorkerRegistry              *workerstatus.Registry
+	Startup                     *startup.Supervisor // Set by main; reports which dependencies came up
 
 	// Cache & Queue
 	CacheInvalidator *cache.CacheInvalidator
@@ -161,8 +312,22 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 	// Wrap sql.DB with sqlx for repositories that need it
 	sqlxDB := sqlx.NewDb(db, "postgres")
 
+	fieldEncryptor, err := NewFieldEncryptor(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize field encryption: %w", err)
+	}
+
+	// Every module reads chain metadata from the registry, so it is
+	// configured before anything else
+	chainRegistry, err := NewChainRegistry(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chain configuration: %w", err)
+	}
+	entities.SetChainRegistry(chainRegistry)
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db, zapLog)
+	userRepo.SetFieldEncryptor(fieldEncryptor)
 	onboardingFlowRepo := repositories.NewOnboardingFlowRepository(db, zapLog)
 	kycSubmissionRepo := repositories.NewKYCSubmissionRepository(db, zapLog)
 	walletRepo := repositories.NewWalletRepository(db, zapLog)
@@ -177,6 +342,9 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 	reconciliationRepo := repositories.NewPostgresReconciliationRepository(db)
 	onboardingJobRepo := repositories.NewOnboardingJobRepository(db, zapLog)
 
+	// Faults rehearsed against the provider clients; a no-op in production
+	faultInjectionService := NewFaultInjectionService(cfg, db, zapLog)
+
 	// Initialize external services
 	circleConfig := circle.Config{
 		APIKey:                 cfg.Circle.APIKey,
@@ -184,7 +352,12 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		BaseURL:                cfg.Circle.BaseURL,
 		EntitySecretCiphertext: cfg.Circle.EntitySecretCiphertext,
 	}
-	circleClient := circle.NewClient(circleConfig, zapLog)
+	// Retries to every provider draw on one budget so an outage is not amplified by retry storms
+	retryBudget := retry.NewBudget(retry.DefaultBudgetConfig())
+
+	circleClient := circle.NewClient(circleConfig, logger.ForModule(zapLog, "circle"))
+	circleClient.WrapTransport(faultInjectionService.Wrapper(entities.FaultProviderCircle))
+	circleClient.SetRetryBudget(retryBudget)
 
 	// Initialize Alpaca service
 	alpacaConfig := alpaca.Config{
@@ -195,22 +368,23 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		Environment: cfg.Alpaca.Environment,
 		Timeout:     time.Duration(cfg.Alpaca.Timeout) * time.Second,
 	}
-	alpacaClient := alpaca.NewClient(alpacaConfig, zapLog)
+	alpacaClient := alpaca.NewClient(alpacaConfig, logger.ForModule(zapLog, "alpaca"))
+	alpacaClient.WrapTransport(faultInjectionService.Wrapper(entities.FaultProviderAlpaca))
 	alpacaService := alpaca.NewService(alpacaClient, zapLog)
 
 	// Initialize KYC provider with full configuration
 	kycProviderConfig := adapters.KYCProviderConfig{
-		Provider:    cfg.KYC.Provider,
-		APIKey:      cfg.KYC.APIKey,
-		APISecret:   cfg.KYC.APISecret,
-		BaseURL:     cfg.KYC.BaseURL,
-		Environment: cfg.KYC.Environment,
-		CallbackURL: cfg.KYC.CallbackURL,
-		UserAgent:   cfg.KYC.UserAgent,
-		LevelName:   cfg.KYC.LevelName,
+		Provider:     cfg.KYC.Provider,
+		APIKey:       cfg.KYC.APIKey,
+		APISecret:    cfg.KYC.APISecret,
+		BaseURL:      cfg.KYC.BaseURL,
+		Environment:  cfg.KYC.Environment,
+		CallbackURL:  cfg.KYC.CallbackURL,
+		UserAgent:    cfg.KYC.UserAgent,
+		LevelName:    cfg.KYC.LevelName,
+		EDDLevelName: cfg.KYC.EDDLevelName,
 	}
 	var kycProvider *adapters.KYCProvider
-	var err error
 	if strings.TrimSpace(cfg.KYC.Provider) != "" {
 		kycProvider, err = adapters.NewKYCProvider(zapLog, kycProviderConfig)
 		if err != nil {
@@ -220,7 +394,21 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		zapLog.Warn("KYC provider not configured; KYC features disabled")
 	}
 
-	// Initialize email service with full configuration
+	// Initialize email and SMS with provider failover. Sandbox capture stores
+	// outbound messages for admins to read instead of sending them.
+	failover := adapters.FailoverConfig{
+		FailureThreshold: cfg.Messaging.FailureThreshold,
+		Cooldown:         time.Duration(cfg.Messaging.CooldownSeconds) * time.Second,
+	}
+	capturedMessageRepo := repositories.NewCapturedMessageRepository(db, zapLog)
+
+	emailFallbacks := make([]adapters.EmailProviderConfig, 0, len(cfg.Email.Fallbacks))
+	for _, fallback := range cfg.Email.Fallbacks {
+		emailFallbacks = append(emailFallbacks, adapters.EmailProviderConfig{
+			Provider: fallback.Provider,
+			APIKey:   fallback.APIKey,
+		})
+	}
 	emailServiceConfig := adapters.EmailServiceConfig{
 		Provider:    cfg.Email.Provider,
 		APIKey:      cfg.Email.APIKey,
@@ -229,47 +417,80 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		Environment: cfg.Email.Environment,
 		BaseURL:     cfg.Email.BaseURL,
 		ReplyTo:     cfg.Email.ReplyTo,
+		Fallbacks:   emailFallbacks,
+		Failover:    failover,
+		CaptureOnly: cfg.Messaging.SandboxCapture,
 	}
 	var emailService *adapters.EmailService
-	if strings.TrimSpace(cfg.Email.Provider) != "" {
+	if strings.TrimSpace(cfg.Email.Provider) != "" || cfg.Messaging.SandboxCapture {
 		emailService, err = adapters.NewEmailService(zapLog, emailServiceConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize email service: %w", err)
 		}
+		if cfg.Messaging.SandboxCapture {
+			emailService.SetCapture(capturedMessageRepo)
+		}
 	} else {
 		zapLog.Warn("Email provider not configured; email notifications disabled")
 	}
 
 	// Initialize SMS service
 	var smsService *adapters.SMSService
-	if strings.TrimSpace(cfg.SMS.Provider) != "" {
+	if strings.TrimSpace(cfg.SMS.Provider) != "" || cfg.Messaging.SandboxCapture {
+		smsFallbacks := make([]adapters.SMSProviderConfig, 0, len(cfg.SMS.Fallbacks))
+		for _, fallback := range cfg.SMS.Fallbacks {
+			smsFallbacks = append(smsFallbacks, adapters.SMSProviderConfig{
+				Provider:   fallback.Provider,
+				APIKey:     fallback.APIKey,
+				APISecret:  fallback.APISecret,
+				FromNumber: fallback.FromNumber,
+				BaseURL:    fallback.BaseURL,
+			})
+		}
 		smsService, err = adapters.NewSMSService(zapLog, adapters.SMSConfig{
 			Provider:    cfg.SMS.Provider,
 			APIKey:      cfg.SMS.APIKey,
 			APISecret:   cfg.SMS.APISecret,
 			FromNumber:  cfg.SMS.FromNumber,
+			BaseURL:     cfg.SMS.BaseURL,
 			Environment: cfg.SMS.Environment,
+			Fallbacks:   smsFallbacks,
+			Failover:    failover,
+			CaptureOnly: cfg.Messaging.SandboxCapture,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize SMS service: %w", err)
 		}
+		if cfg.Messaging.SandboxCapture {
+			smsService.SetCapture(capturedMessageRepo)
+		}
 	} else {
 		zapLog.Warn("SMS provider not configured; SMS notifications disabled")
 	}
-
-	// Initialize Redis client
-	redisClient, err := cache.NewRedisClient(&cfg.Redis, zapLog)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Redis client: %w", err)
+	if cfg.Messaging.SandboxCapture {
+		zapLog.Warn("Messaging sandbox capture enabled; email and SMS are stored, not sent")
 	}
 
+	// Initialize Redis client; reachability is checked by the startup
+	// supervisor so an outage at boot degrades rather than aborts
+	redisClient := cache.OpenRedisClient(&cfg.Redis, zapLog)
+
 	auditService := adapters.NewAuditService(db, zapLog)
+	if anchorStore, err := NewAuditAnchorStore(cfg); err != nil {
+		zapLog.Warn("Invalid audit anchor storage configuration; anchoring chain head in database only", zap.Error(err))
+	} else if anchorStore != nil {
+		auditService.SetAnchorStore(anchorStore)
+	}
 
 	// Initialize cache invalidator
 	cacheInvalidator := cache.NewCacheInvalidator(redisClient, zapLog, cache.InvalidateImmediate)
 
-	// Initialize entity secret service
-	entitySecretService := entitysecret.NewService(zapLog)
+	// Initialize entity secret service from the secrets manager
+	entitySecretService, err := NewEntitySecretService(cfg, fieldEncryptor, circleClient, zapLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load circle entity secret: %w", err)
+	}
+	circleClient.SetEntitySecretService(entitySecretService)
 
 	container := &Container{
 		Config: cfg,
@@ -277,6 +498,9 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		Logger: log,
 		ZapLog: zapLog,
 
+		FieldEncryptor: fieldEncryptor,
+		Clock:          clock.System(),
+
 		// Repositories
 		UserRepo:                  userRepo,
 		OnboardingFlowRepo:        onboardingFlowRepo,
@@ -292,6 +516,7 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		LedgerRepo:                ledgerRepo,
 		ReconciliationRepo:        reconciliationRepo,
 		OnboardingJobRepo:         onboardingJobRepo,
+		CapturedMessageRepo:       capturedMessageRepo,
 
 		// External Services
 		CircleClient:  circleClient,
@@ -306,8 +531,14 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		// Entity Secret Service
 		EntitySecretService: entitySecretService,
 
+		// Fault Injection
+		FaultInjectionService: faultInjectionService,
+		RetryBudget:           retryBudget,
+
 		// Cache & Queue
 		CacheInvalidator: cacheInvalidator,
+
+		WorkerRegistry: workerstatus.NewRegistry(),
 	}
 
 	// Initialize domain services with their dependencies
@@ -322,9 +553,13 @@ func NewContainer(cfg *config.Config, db *sql.DB, log *logger.Logger) (*Containe
 		container.SMSService,
 		container.ZapLog,
 		container.Config,
+		container.Clock,
 	)
 
 	container.OnboardingJobService = services.NewOnboardingJobService(container.OnboardingJobRepo, container.ZapLog)
+	container.OnboardingJobService.SetStuckAfter(time.Duration(cfg.OnboardingJobs.StuckAfterMinutes) * time.Minute)
+
+	container.useClock()
 
 	return container, nil
 }
@@ -352,13 +587,16 @@ func (c *Container) initializeDomainServices() error {
 	)
 
 	// Initialize Due client and adapter
+	dueLog := c.Logger.Module("due")
 	dueClient := due.NewClient(due.Config{
 		APIKey:    c.Config.Due.APIKey,
 		AccountID: c.Config.Due.AccountID,
 		BaseURL:   c.Config.Due.BaseURL,
 		Timeout:   30 * time.Second,
-	}, c.Logger)
-	dueAdapter := due.NewAdapter(dueClient, c.Logger)
+	}, dueLog)
+	dueClient.WrapTransport(c.FaultInjectionService.Wrapper(entities.FaultProviderDue))
+	dueClient.SetRetryBudget(c.RetryBudget)
+	dueAdapter := due.NewAdapter(dueClient, dueLog)
 
 	// Initialize Alpaca adapter
 	alpacaAdapter := alpaca.NewAdapter(c.AlpacaClient, c.Logger)
@@ -377,6 +615,87 @@ func (c *Container) initializeDomainServices() error {
 		c.ZapLog,
 		append([]entities.WalletChain(nil), walletServiceConfig.SupportedChains...),
 	)
+	if c.KYCProvider != nil {
+		c.OnboardingService.SetKYCDocumentProvider(c.KYCProvider)
+	}
+
+	// Raw KYC personal info and document links are kept in the PII vault;
+	// onboarding is the only service that can read them back
+	vaultEncryptor, err := NewPIIVaultEncryptor(c.Config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize PII vault encryption: %w", err)
+	}
+	c.PIIVaultService = piivault.NewService(repositories.NewPIIVaultRepository(c.DB, c.ZapLog), vaultEncryptor, c.ZapLog)
+	c.OnboardingService.SetPIIVault(c.PIIVaultService.Scope("onboarding", entities.PIIKindKYCIdentity))
+
+	// Initialize jurisdiction engine for country-based product gating
+	c.JurisdictionService = jurisdiction.NewService(
+		repositories.NewJurisdictionRepository(c.DB, c.ZapLog),
+		0,
+		c.ZapLog,
+	)
+	c.OnboardingService.SetJurisdictionService(c.JurisdictionService)
+
+	// Bucket onboarding users into product experiments
+	c.ExperimentService = experiments.NewService(repositories.NewExperimentRepository(c.DB, c.ZapLog), c.ZapLog)
+	c.OnboardingService.SetExperiments(c.ExperimentService)
+
+	// Track accepted agreement versions; outstanding mandatory ones block trading
+	c.ConsentService = consents.NewService(
+		repositories.NewConsentRepository(c.DB, c.ZapLog),
+		0,
+		c.ZapLog,
+	)
+
+	// Geolocate signups and logins; flagged sessions feed the fraud score
+	c.TransactionControl = services.NewTransactionControlService(c.ZapLog)
+	if c.Config.GeoIP.Enabled {
+		geoProvider, err := adapters.NewGeoIPProvider(c.ZapLog, adapters.GeoIPProviderConfig{
+			APIKey:     c.Config.GeoIP.APIKey,
+			BaseURL:    c.Config.GeoIP.BaseURL,
+			Strictness: c.Config.GeoIP.Strictness,
+			Timeout:    time.Duration(c.Config.GeoIP.TimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize geo-IP provider: %w", err)
+		}
+		c.GeoIPService = geoip.NewService(
+			geoProvider,
+			c.JurisdictionService,
+			repositories.NewGeoAccessRepository(c.DB, c.ZapLog),
+			geoip.Config{
+				HighRiskScore: c.Config.GeoIP.HighRiskScore,
+				FlagTTL:       time.Duration(c.Config.GeoIP.FlagTTLHours) * time.Hour,
+			},
+			c.ZapLog,
+		)
+		c.TransactionControl.SetSessionRiskSource(c.GeoIPService)
+	}
+
+	// Check new passwords against the policy and known breaches
+	var breachChecker passwordpolicy.BreachChecker
+	if c.Config.PasswordPolicy.BreachCheck {
+		breachChecker = adapters.NewPwnedPasswordsClient(adapters.PwnedPasswordsConfig{
+			BaseURL: c.Config.PasswordPolicy.BreachAPIURL,
+			Timeout: time.Duration(c.Config.PasswordPolicy.BreachTimeoutMs) * time.Millisecond,
+		})
+	}
+	c.PasswordPolicyService = passwordpolicy.NewService(breachChecker, c.UserRepo, passwordpolicy.Config{
+		MinLength:        c.Config.Security.PasswordMinLength,
+		MinCharClasses:   c.Config.PasswordPolicy.MinCharClasses,
+		PassphraseLength: c.Config.PasswordPolicy.PassphraseLength,
+		DisallowPersonal: c.Config.PasswordPolicy.DisallowPersonalInfo,
+		BreachCheck:      c.Config.PasswordPolicy.BreachCheck,
+		GracePeriod:      time.Duration(c.Config.PasswordPolicy.GraceDays) * 24 * time.Hour,
+	}, c.ZapLog)
+
+	// Initialize custodial accounts for minors
+	c.CustodialService = custodial.NewService(
+		repositories.NewCustodialAccountRepository(c.DB, c.ZapLog),
+		c.UserRepo,
+		c.AuditService,
+		c.ZapLog,
+	)
 
 	// Inject onboarding service back into wallet service to complete circular dependency
 	c.WalletService.SetOnboardingService(c.OnboardingService)
@@ -392,6 +711,7 @@ func (c *Container) initializeDomainServices() error {
 	c.SessionService = session.NewService(c.DB, c.ZapLog)
 	c.TwoFAService = twofa.NewService(c.DB, c.ZapLog, c.Config.Security.EncryptionKey)
 	c.APIKeyService = apikey.NewService(c.DB, c.ZapLog)
+	c.APIKeyService.SetEncryptionKey(c.Config.Security.EncryptionKey)
 
 	// Initialize simple wallet repository for funding service
 	simpleWalletRepo := repositories.NewSimpleWalletRepository(c.DB, c.Logger)
@@ -399,6 +719,7 @@ func (c *Container) initializeDomainServices() error {
 	// Initialize virtual account repository
 	sqlxDB := sqlx.NewDb(c.DB, "postgres")
 	virtualAccountRepo := repositories.NewVirtualAccountRepository(sqlxDB)
+	virtualAccountRepo.SetFieldEncryptor(c.FieldEncryptor)
 
 	// Initialize Due service with deposit and balance repositories
 	c.DueService = services.NewDueService(dueClient, c.DepositRepo, c.BalanceRepo, c.Logger)
@@ -409,10 +730,28 @@ func (c *Container) initializeDomainServices() error {
 	// Initialize ledger service
 	c.LedgerService = ledger.NewService(c.LedgerRepo, sqlxDB, c.Logger)
 
+	// Initialize historical exchange rates for valuing transactions when they happened
+	c.RateService = rates.NewService(repositories.NewExchangeRateRepository(c.DB, c.ZapLog), rates.Config{
+		MaxSkew:       time.Duration(c.Config.Rates.MaxSkewHours) * time.Hour,
+		BackfillChunk: time.Duration(c.Config.Rates.BackfillChunkDays) * 24 * time.Hour,
+	}, c.ZapLog)
+	if c.Config.Rates.ProviderEnabled {
+		c.RateService.SetProvider(adapters.NewExchangeRateProvider(c.ZapLog, adapters.ExchangeRateProviderConfig{
+			APIKey:  c.Config.Rates.ProviderAPIKey,
+			BaseURL: c.Config.Rates.ProviderBaseURL,
+			Timeout: time.Duration(c.Config.Rates.TimeoutSeconds) * time.Second,
+		}))
+	}
+	c.LedgerService.SetRateLookup(c.RateService)
+
 	// Initialize standalone Balance service with Alpaca adapter
 	alpacaBalanceAdapter := &AlpacaFundingAdapter{adapter: alpacaFundingAdapter, client: c.AlpacaClient}
 	c.BalanceService = services.NewBalanceService(c.BalanceRepo, alpacaBalanceAdapter, c.Logger)
 
+	// Initialize holds on buying power for pending orders and withdrawals
+	c.BalanceHoldService = holds.NewService(repositories.NewBalanceHoldRepository(c.DB, c.ZapLog), c.ZapLog)
+	c.BalanceHoldService.SetLedger(c.LedgerService)
+
 	// Initialize funding service with dependencies
 	circleAdapter := &CircleAdapter{client: c.CircleClient}
 	c.FundingService = funding.NewService(
@@ -426,6 +765,80 @@ func (c *Container) initializeDomainServices() error {
 		&AlpacaFundingAdapter{adapter: alpacaFundingAdapter, client: c.AlpacaClient},
 		c.Logger,
 	)
+	c.FundingService.SetLedger(c.LedgerService)
+	c.FundingService.SetRates(c.RateService)
+	c.FundingService.SetConfirmationThresholds(funding.ConfirmationThresholds{
+		Default:  c.Config.Deposits.DefaultConfirmations,
+		Chains:   c.Config.Deposits.Confirmations,
+		Registry: entities.Chains(),
+	})
+	if c.Config.Deposits.InstantPercent > 0 {
+		c.FundingService.SetInstantBuyingPower(
+			repositories.NewInstantFundingAdvanceRepository(c.DB, c.ZapLog),
+			funding.InstantBuyingPower{
+				Percent:   decimal.NewFromFloat(c.Config.Deposits.InstantPercent),
+				MaxAmount: decimal.NewFromFloat(c.Config.Deposits.InstantMaxAmount),
+			},
+		)
+	}
+
+	// Initialize reference-code attribution for shared deposit accounts
+	sharedAccounts := make([]depositref.SharedAccount, 0, len(c.Config.Deposits.SharedAccounts))
+	for _, account := range c.Config.Deposits.SharedAccounts {
+		sharedAccounts = append(sharedAccounts, depositref.SharedAccount{
+			Currency:      account.Currency,
+			BankName:      account.BankName,
+			AccountName:   account.AccountName,
+			AccountNumber: account.AccountNumber,
+			RoutingNumber: account.RoutingNumber,
+		})
+	}
+	c.DepositReferenceService = depositref.NewService(
+		repositories.NewDepositReferenceRepository(c.DB, c.ZapLog),
+		c.FundingService,
+		sharedAccounts,
+		c.ZapLog,
+	)
+	c.DepositReferenceService.SetConverter(c.RateService)
+
+	// Route reconciliation and risk alerts to on-call channels
+	if err := c.initializeAlerting(); err != nil {
+		return fmt.Errorf("failed to initialize alert routing: %w", err)
+	}
+
+	// Initialize the suspense account for deposits that cannot be attributed
+	c.SuspenseService = suspense.NewService(
+		repositories.NewSuspenseRepository(c.DB, c.ZapLog),
+		c.LedgerService,
+		suspense.Config{
+			Interval:  time.Duration(c.Config.Suspense.IntervalMinutes) * time.Minute,
+			AgedAfter: time.Duration(c.Config.Suspense.AgingAlertDays) * 24 * time.Hour,
+		},
+		c.ZapLog,
+	)
+	c.SuspenseService.RegisterSource(entities.SuspenseSourceBankCredit, c.DepositReferenceService)
+	c.SuspenseService.RegisterSource(entities.SuspenseSourceChainDeposit, c.FundingService)
+	c.DepositReferenceService.SetSuspense(c.SuspenseService)
+	c.FundingService.SetSuspense(c.SuspenseService)
+	c.SuspenseService.SetAlerter(c.AlertingService)
+
+	// Initialize wallet balance cache
+	balanceCacheCfg := c.Config.BalanceCache
+	c.BalanceCacheService = balancecache.NewService(
+		repositories.NewWalletBalanceCacheRepository(c.DB, c.ZapLog),
+		c.CircleClient,
+		c.RedisClient,
+		balancecache.Config{
+			RefreshInterval:    time.Duration(balanceCacheCfg.RefreshIntervalSeconds) * time.Second,
+			MaxAge:             time.Duration(balanceCacheCfg.MaxAgeSeconds) * time.Second,
+			BatchSize:          balanceCacheCfg.BatchSize,
+			Concurrency:        balanceCacheCfg.Concurrency,
+			ForceRefreshLimit:  balanceCacheCfg.ForceRefreshLimit,
+			ForceRefreshWindow: time.Duration(balanceCacheCfg.ForceRefreshWindowSeconds) * time.Second,
+		},
+		c.ZapLog,
+	)
+	c.FundingService.SetBalanceCache(c.BalanceCacheService)
 
 	// Initialize allocation service
 	allocationRepo := repositories.NewAllocationRepository(sqlxDB, c.Logger)
@@ -448,6 +861,11 @@ func (c *Container) initializeDomainServices() error {
 
 	// Initialize notification service
 	c.NotificationService = services.NewNotificationService(c.ZapLog)
+	c.NotificationService.SetPreferenceStore(repositories.NewNotificationPreferenceRepository(c.DB, c.ZapLog))
+	c.NotificationService.SetHeldNotifications(repositories.NewHeldNotificationRepository(c.DB, c.ZapLog), services.NotificationHoldConfig{
+		Interval:  time.Duration(c.Config.Notifications.ReleaseIntervalSeconds) * time.Second,
+		BatchSize: c.Config.Notifications.ReleaseBatchSize,
+	})
 
 	c.InvestingService = investing.NewService(
 		basketRepo,
@@ -461,12 +879,618 @@ func (c *Container) initializeDomainServices() error {
 		c.NotificationService,
 		c.Logger,
 	)
+	c.InvestingService.SetQuoteProvider(brokerageAdapter)
+	c.InvestingService.SetBalanceHolds(c.BalanceHoldService)
+	c.MarketCalendarService = marketcalendar.NewService(brokerageAdapter, marketcalendar.DefaultConfig(), c.ZapLog)
+	c.InvestingService.SetMarketCalendar(c.MarketCalendarService)
+	feeSchedule := investing.FeeSchedule{
+		CommissionBps:     decimal.NewFromFloat(c.Config.TradingFees.CommissionBps),
+		MinimumCommission: decimal.NewFromFloat(c.Config.TradingFees.MinimumCommission),
+		SECFeeRate:        decimal.NewFromFloat(c.Config.TradingFees.SECFeeRate),
+		TAFPerShare:       decimal.NewFromFloat(c.Config.TradingFees.TAFPerShare),
+		TAFMaximum:        decimal.NewFromFloat(c.Config.TradingFees.TAFMaximum),
+	}
+	c.InvestingService.SetFeeSchedule(feeSchedule)
+	limitOrderConfig := investing.LimitOrderConfig{
+		Interval:  time.Duration(c.Config.LimitOrders.IntervalSeconds) * time.Second,
+		BatchSize: c.Config.LimitOrders.BatchSize,
+		MaxGTC:    time.Duration(c.Config.LimitOrders.MaxGTCDays) * 24 * time.Hour,
+	}
+	c.InvestingService.SetLimitOrderConfig(limitOrderConfig)
+	// Queued basket buys are checked out together behind one passcode or 2FA step-up
+	cartRepo := repositories.NewInvestingCartRepository(c.DB, c.ZapLog)
+	c.InvestingService.SetCart(cartRepo)
+	// Regional basket variants are only listed and ordered in their regions
+	c.InvestingService.SetRegionResolver(c.JurisdictionService)
+	c.BasketLocalization = investing.NewBasketLocalization(basketRepo, c.Logger)
+	c.InvestingService.SetStepUp(c.PasscodeService, c.TwoFAService)
+
+	// Serve balances, live portfolios and basket detail from Redis, warmed after each deploy
+	cacheWarm := c.Config.CacheWarm
+	c.HotCacheService = hotcache.NewService(
+		c.RedisClient,
+		c.FundingService,
+		c.InvestingService,
+		basketRepo,
+		repositories.NewActiveUserRepository(c.DB, c.ZapLog),
+		hotcache.Config{
+			UserTTL:      time.Duration(cacheWarm.UserTTLSeconds) * time.Second,
+			BasketTTL:    time.Duration(cacheWarm.BasketTTLSeconds) * time.Second,
+			ViewWindow:   time.Duration(cacheWarm.ViewWindowDays) * 24 * time.Hour,
+			ActiveWindow: time.Duration(cacheWarm.ActiveWindowHours) * time.Hour,
+			MaxUsers:     cacheWarm.MaxUsers,
+			MaxBaskets:   cacheWarm.MaxBaskets,
+			Concurrency:  cacheWarm.Concurrency,
+		},
+		c.ZapLog,
+	)
+	c.InvestingService.SetBasketCache(c.HotCacheService)
+
+	// Stream live quotes for held and requested symbols during market hours
+	if c.Config.MarketData.StreamEnabled {
+		quoteStream := alpaca.NewQuoteStream(alpaca.StreamConfig{
+			URL:         c.Config.MarketData.StreamURL,
+			ClientID:    c.Config.Alpaca.ClientID,
+			SecretKey:   c.Config.Alpaca.SecretKey,
+			Environment: c.Config.Alpaca.Environment,
+		}, c.ZapLog)
+		c.MarketDataService = marketdata.NewService(quoteStream, positionRepo, basketRepo, marketdata.Config{
+			MaxSymbols: c.Config.MarketData.MaxSymbols,
+			Throttle:   time.Duration(c.Config.MarketData.ThrottleMillis) * time.Millisecond,
+		}, c.ZapLog)
+		c.MarketDataService.SetSnapshots(brokerageAdapter)
+		c.MarketDataService.SetMarketHours(c.MarketCalendarService)
+	}
+
+	// Plan investment goals against the user's basket portfolio
+	c.GoalService = goals.NewService(repositories.NewGoalRepository(c.DB, c.ZapLog), positionRepo, c.NotificationService, goals.Config{
+		ExpectedReturns: map[entities.RiskLevel]decimal.Decimal{
+			entities.RiskLevelConservative: decimal.NewFromFloat(c.Config.Goals.ConservativeReturn),
+			entities.RiskLevelBalanced:     decimal.NewFromFloat(c.Config.Goals.BalancedReturn),
+			entities.RiskLevelGrowth:       decimal.NewFromFloat(c.Config.Goals.GrowthReturn),
+		},
+		BehindTolerance: decimal.NewFromFloat(c.Config.Goals.BehindTolerancePercent).Div(decimal.NewFromInt(100)),
+		NudgeCooldown:   time.Duration(c.Config.Goals.NudgeCooldownDays) * 24 * time.Hour,
+		Interval:        time.Duration(c.Config.Goals.IntervalHours) * time.Hour,
+	}, c.ZapLog)
+
+	// Invest idle buying power for users who opt in to auto-sweep
+	var sweepBasketID uuid.UUID
+	if c.Config.Sweep.DefaultBasketID != "" {
+		parsed, parseErr := uuid.Parse(c.Config.Sweep.DefaultBasketID)
+		if parseErr != nil {
+			c.ZapLog.Warn("Invalid default auto-sweep basket; users must choose one", zap.Error(parseErr))
+		}
+		sweepBasketID = parsed
+	}
+	c.SweepService = sweep.NewService(repositories.NewSweepRepository(c.DB, c.ZapLog), c.BalanceRepo, c.InvestingService, c.NotificationService, sweep.Config{
+		DefaultBasketID: sweepBasketID,
+		MinAmount:       decimal.NewFromFloat(c.Config.Sweep.MinAmount),
+		MaxFailures:     c.Config.Sweep.MaxFailures,
+		Interval:        time.Duration(c.Config.Sweep.IntervalMinutes) * time.Minute,
+	}, c.ZapLog)
+	c.SweepService.SetGates(c.JurisdictionService, c.ConsentService)
+
+	// Initialize the buying power projection
+	c.ProjectionService = projection.NewService(
+		c.BalanceRepo,
+		c.DepositRepo,
+		c.BalanceHoldService,
+		repositories.NewSweepRepository(c.DB, c.ZapLog),
+		&CircleAdapter{client: c.CircleClient},
+		projection.DefaultConfig(),
+		c.ZapLog,
+	)
+	c.ProjectionService.SetAdvances(repositories.NewInstantFundingAdvanceRepository(c.DB, c.ZapLog))
+
+	// Initialize admin bulk user operations
+	c.BulkOpsService = bulkops.NewService(repositories.NewBulkOperationRepository(c.DB, c.ZapLog), c.UserRepo, bulkops.Config{
+		MaxUsers:    c.Config.BulkOps.MaxUsers,
+		BatchSize:   c.Config.BulkOps.BatchSize,
+		MaxAttempts: c.Config.BulkOps.MaxAttempts,
+		Interval:    time.Duration(c.Config.BulkOps.PollIntervalSeconds) * time.Second,
+	}, c.ZapLog)
+	if c.VerificationService != nil {
+		c.BulkOpsService.SetVerificationSender(c.VerificationService)
+	}
+	if c.EmailService != nil {
+		c.BulkOpsService.SetMailer(c.EmailService)
+	}
+
+	// Initialize the daily general ledger export
+	c.AccountingService = c.newAccountingService()
+
+	// Initialize the business event export to the data warehouse
+	c.WarehouseService = c.newWarehouseService()
+
+	// Initialize the daily order blotter for regulatory reporting
+	c.BlotterService = c.newBlotterService()
+
+	// Initialize performance attribution over live positions and Alpaca daily bars
+	c.AttributionService = attribution.NewService(positionRepo, basketRepo, brokerageAdapter, attribution.DefaultConfig(), c.ZapLog)
+	c.AttributionService.SetPerformanceHistory(repositories.NewPortfolioRepository(c.DB, c.ZapLog))
+
+	// Initialize public links to percentage-only snapshots of that attribution
+	shareKey := c.Config.PortfolioSharing.SigningKey
+	if shareKey == "" {
+		shareKey = c.Config.JWT.Secret
+	}
+	c.PortfolioShareService = portfolioshare.NewService(
+		repositories.NewPortfolioShareRepository(c.DB, c.ZapLog),
+		c.AttributionService,
+		portfolioshare.Config{
+			SigningKey:   []byte(shareKey),
+			DefaultTTL:   time.Duration(c.Config.PortfolioSharing.DefaultTTLHours) * time.Hour,
+			MaxTTL:       time.Duration(c.Config.PortfolioSharing.MaxTTLHours) * time.Hour,
+			MaxLiveLinks: c.Config.PortfolioSharing.MaxLiveLinks,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize the portfolio news feed from Alpaca news about held symbols
+	c.NewsService = news.NewService(brokerageAdapter, positionRepo, basketRepo, news.DefaultConfig(), c.ZapLog)
+
+	// Initialize admin intervention on live orders stuck at the brokerage
+	c.OrderOpsService = orderops.NewService(
+		repositories.NewOrderInterventionRepository(c.DB, c.ZapLog),
+		brokerageAdapter,
+		c.BalanceRepo,
+		positionRepo,
+		c.AuditService,
+		orderops.Config{
+			SLA:       time.Duration(c.Config.StuckOrders.SLAMinutes) * time.Minute,
+			ListLimit: c.Config.StuckOrders.ListLimit,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize paper trading: the same order flow over the paper tables,
+	// filled at live quotes by a simulated broker
+	if c.Config.PaperTrading.Enabled {
+		paperRepo := repositories.NewPaperTradingRepository(c.DB, c.ZapLog)
+		c.PaperBalanceRepo = paperRepo.PaperBalances()
+		c.PaperInvestingService = investing.NewPaperService(
+			basketRepo,
+			repositories.NewPaperOrderRepository(c.DB, c.ZapLog),
+			repositories.NewPaperPositionRepository(c.DB, c.ZapLog),
+			c.PaperBalanceRepo,
+			brokerageAdapter,
+			c.Logger,
+		)
+		c.PaperInvestingService.SetFeeSchedule(feeSchedule)
+		c.PaperInvestingService.SetMarketCalendar(c.MarketCalendarService)
+		c.PaperInvestingService.SetLimitOrderConfig(limitOrderConfig)
+		c.PaperInvestingService.SetCart(cartRepo)
+		c.PaperInvestingService.SetStepUp(c.PasscodeService, c.TwoFAService)
+		c.PaperInvestingService.SetRegionResolver(c.JurisdictionService)
+		c.PaperTradingService = papertrading.NewService(paperRepo, c.JurisdictionService, papertrading.Config{
+			StartingBalance: decimal.NewFromFloat(c.Config.PaperTrading.StartingBalance),
+		}, c.ZapLog)
+		c.SweepService.SetTradingModes(c.PaperTradingService)
+	}
 
 	// Initialize reconciliation service
 	if err := c.initializeReconciliationService(); err != nil {
 		return fmt.Errorf("failed to initialize reconciliation service: %w", err)
 	}
 
+	// Initialize data retention engine
+	c.RetentionService = retention.NewService(
+		c.DB,
+		retention.DefaultPolicies(),
+		c.AuditService,
+		retention.Config{
+			DryRun:    c.Config.Retention.DryRun,
+			BatchSize: c.Config.Retention.BatchSize,
+			Interval:  time.Duration(c.Config.Retention.IntervalHours) * time.Hour,
+		},
+		c.Logger,
+	)
+
+	// Initialize backup restore drill
+	c.RestoreDrillService = NewRestoreDrillService(c.Config, c.DB, c.Logger)
+
+	// Initialize wallet provisioning backfill
+	c.WalletBackfillService = NewWalletBackfillService(c.Config, c.DB, c.ZapLog)
+
+	// Initialize Circle notification subscription management
+	c.CircleSubscriptions = circlesubscription.NewService(
+		c.CircleClient,
+		repositories.NewCircleSubscriptionRepository(c.DB, c.ZapLog),
+		circlesubscription.Config{
+			CallbackURL:       c.Config.Circle.NotificationCallbackURL,
+			NotificationTypes: c.Config.Circle.NotificationTypes,
+			CheckInterval:     time.Duration(c.Config.Circle.SubscriptionCheckMinutes) * time.Minute,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize trusted contacts, admin case queue and dormant account monitor
+	trustedContactRepo := repositories.NewTrustedContactRepository(c.DB, c.ZapLog)
+	trustedContactRepo.SetFieldEncryptor(c.FieldEncryptor)
+	c.TrustedContactService = trustedcontact.NewService(trustedContactRepo, c.AuditService, c.ZapLog)
+	c.CaseService = cases.NewService(repositories.NewAdminCaseRepository(c.DB, c.ZapLog), c.ZapLog)
+
+	// Initialize the nightly cross-entity integrity checker; each anomaly
+	// opens a case in the admin queue
+	c.IntegrityService = integrity.NewService(
+		repositories.NewIntegrityRepository(c.DB, c.ZapLog),
+		c.CaseService,
+		integrity.Config{
+			RunHour:     c.Config.Integrity.RunHourUTC,
+			Interval:    time.Duration(c.Config.Integrity.IntervalMinutes) * time.Minute,
+			Grace:       time.Duration(c.Config.Integrity.GraceHours) * time.Hour,
+			MaxPerCheck: c.Config.Integrity.MaxPerCheck,
+			OpenCases:   c.Config.Integrity.OpenCases,
+		},
+		c.ZapLog,
+	)
+	var inactivityMailer inactivity.Mailer
+	if c.EmailService != nil {
+		inactivityMailer = c.EmailService
+	}
+	c.InactivityService = inactivity.NewService(
+		repositories.NewAccountInactivityRepository(c.DB, c.ZapLog),
+		c.TrustedContactService,
+		c.CaseService,
+		inactivityMailer,
+		c.NotificationService,
+		inactivity.Config{
+			ReminderMonths:  c.Config.Inactivity.ReminderMonths,
+			CaseAfterMonths: c.Config.Inactivity.CaseAfterMonths,
+			Interval:        time.Duration(c.Config.Inactivity.IntervalHours) * time.Hour,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize closed account reactivation, resumed by KYC decisions
+	var reactivationVerifier reactivation.IdentityVerifier
+	if c.KYCProvider != nil {
+		reactivationVerifier = c.KYCProvider
+	}
+	var reactivationMailer reactivation.Mailer
+	if c.EmailService != nil {
+		reactivationMailer = c.EmailService
+	}
+	c.ReactivationService = reactivation.NewService(
+		repositories.NewAccountReactivationRepository(c.DB, c.ZapLog),
+		reactivationVerifier,
+		c.JurisdictionService,
+		c.CaseService,
+		c.BalanceRepo,
+		positionRepo,
+		reactivationMailer,
+		c.ZapLog,
+	)
+	c.OnboardingService.AddKYCReviewObserver(c.ReactivationService)
+
+	// Initialize enhanced due diligence for higher limits, reviewed as admin cases
+	c.EDDService = edd.NewService(
+		repositories.NewEDDRepository(c.DB, c.ZapLog),
+		c.CaseService,
+		edd.Config{
+			Enabled:        c.Config.EDD.Enabled,
+			RolloutPercent: c.Config.EDD.RolloutPercent,
+			Limits:         edd.DefaultLimits(c.Config.EDD.DailyDepositLimit, c.Config.EDD.DailyWithdrawalLimit, c.Config.EDD.DailyTradeLimit),
+		},
+		c.ZapLog,
+	)
+	if c.KYCProvider != nil {
+		c.EDDService.SetProvider(c.KYCProvider)
+	}
+
+	// Initialize business onboarding, reviewed as business verification cases
+	kybRepo := repositories.NewKYBRepository(c.DB, c.ZapLog)
+	kybRepo.SetFieldEncryptor(c.FieldEncryptor)
+	c.KYBService = kyb.NewService(
+		kybRepo,
+		c.CaseService,
+		kyb.Config{
+			Enabled: c.Config.KYB.Enabled,
+			Limits:  kyb.DefaultLimits(c.Config.KYB.DailyDepositLimit, c.Config.KYB.DailyWithdrawalLimit, c.Config.KYB.DailyTradeLimit),
+		},
+		c.ZapLog,
+	)
+	if c.KYCProvider != nil {
+		c.KYBService.SetVerifier(c.KYCProvider)
+	}
+
+	// Initialize data residency, which keeps EU users' files and statements in region
+	c.ResidencyService = residency.NewService(
+		repositories.NewResidencyRepository(c.DB, c.ZapLog),
+		c.JurisdictionService,
+		entities.ResidencyRegion(c.Config.Residency.HomeRegion),
+		c.ZapLog,
+	)
+
+	// Initialize secure document uploads, which KYC, EDD and KYB documents reference
+	c.UploadService = c.newUploadService()
+	c.OnboardingService.SetDocumentResolver(c.UploadService)
+	c.EDDService.SetDocumentResolver(c.UploadService)
+	c.KYBService.SetDocumentResolver(c.UploadService)
+
+	// Initialize the document center, which files a confirmation for every filled order
+	c.DocumentService = documents.NewService(repositories.NewDocumentRepository(c.DB, c.ZapLog), c.ZapLog)
+	c.DocumentService.SetAgreementSource(c.ConsentService)
+	c.DocumentService.SetNotifier(c.NotificationService)
+	c.DocumentService.SetResidency(c.ResidencyService)
+	c.InvestingService.SetTradeConfirmations(c.DocumentService)
+
+	// Initialize maker-checker approvals for large withdrawals and basket deletion
+	c.ApprovalService = approvals.NewService(repositories.NewApprovalRepository(c.DB, c.ZapLog), c.ZapLog)
+	c.BasketDeletion = investing.NewBasketDeletion(basketRepo, c.Logger)
+	c.ApprovalService.Register(entities.ApprovalActionBasketDelete, approvals.Policy{
+		RequiredApprovals: c.Config.Approvals.BasketDeleteRequiredApprovals,
+		Approvers:         c.approvers(),
+	}, c.BasketDeletion)
+
+	// Initialize manual ledger adjustments, posted only once a second admin approves
+	c.LedgerAdjustmentService = adjustments.NewService(
+		repositories.NewLedgerAdjustmentRepository(c.DB, c.ZapLog), c.LedgerService, c.ApprovalService, c.ZapLog)
+	c.ApprovalService.Register(entities.ApprovalActionLedgerAdjustment, approvals.Policy{
+		RequiredApprovals: c.Config.Approvals.LedgerAdjustmentRequiredApprovals,
+		Approvers:         c.approvers(),
+	}, c.LedgerAdjustmentService)
+
+	// Initialize promotional credits, granted on KYC approval and first deposit
+	c.PromotionService = promotions.NewService(
+		repositories.NewPromotionRepository(c.DB, c.ZapLog),
+		c.LedgerService,
+		c.BalanceRepo,
+		promotions.Config{
+			Interval:  time.Duration(c.Config.Promotions.IntervalMinutes) * time.Minute,
+			BatchSize: c.Config.Promotions.BatchSize,
+		},
+		c.ZapLog,
+	)
+	c.OnboardingService.AddKYCReviewObserver(c.PromotionService)
+	c.FundingService.SetPromotions(c.PromotionService)
+
+	// Initialize the rewards program, earned on filled buy orders
+	c.RewardService = rewards.NewService(
+		repositories.NewRewardRepository(c.DB, c.ZapLog),
+		c.LedgerService,
+		c.BalanceRepo,
+		c.InvestingService,
+		rewards.Config{
+			PointsPerDollar: c.Config.Rewards.PointsPerDollar,
+			MonthlyCap:      decimal.NewFromFloat(c.Config.Rewards.MonthlyCap),
+			RiskThreshold:   decimal.NewFromFloat(c.Config.Rewards.RiskThreshold),
+			MinRedemption:   decimal.NewFromFloat(c.Config.Rewards.MinRedemption),
+		},
+		c.ZapLog,
+	)
+	c.RewardService.SetRiskScorer(c.TransactionControl)
+	if c.PaperTradingService != nil {
+		c.RewardService.SetPaperTrading(c.PaperTradingService, c.PaperInvestingService, c.PaperBalanceRepo)
+	}
+	c.InvestingService.SetRewards(c.RewardService)
+
+	// Initialize premium subscription billing, paid from the cash balance
+	billingConfig := subscription.Config{
+		Interval: time.Duration(c.Config.Billing.IntervalMinutes) * time.Minute,
+	}
+	for _, hours := range c.Config.Billing.DunningRetryHours {
+		billingConfig.DunningSchedule = append(billingConfig.DunningSchedule, time.Duration(hours)*time.Hour)
+	}
+	c.SubscriptionService = subscription.NewService(
+		repositories.NewSubscriptionRepository(c.DB, c.ZapLog),
+		c.LedgerService,
+		c.BalanceRepo,
+		c.NotificationService,
+		billingConfig,
+		c.ZapLog,
+	)
+
+	// Initialize per-user listing and export of stored AI summaries
+	c.AIArtifactService = c.newAIArtifactService()
+	c.WebhookArchiveService = c.newWebhookArchiveService()
+
+	// Initialize outbound partner webhooks and publish domain events to them
+	outboundWebhookRepo := repositories.NewOutboundWebhookRepository(c.DB, c.ZapLog)
+	outboundWebhookRepo.SetFieldEncryptor(c.FieldEncryptor)
+	webhookConfig := outboundwebhook.DefaultConfig()
+	webhookConfig.MaxAttempts = c.Config.Webhooks.MaxAttempts
+	webhookConfig.PollInterval = time.Duration(c.Config.Webhooks.PollIntervalSeconds) * time.Second
+	webhookConfig.Timeout = time.Duration(c.Config.Webhooks.TimeoutSeconds) * time.Second
+	webhookConfig.AllowHTTP = c.Config.Webhooks.AllowHTTP
+	c.OutboundWebhookService = outboundwebhook.NewService(outboundWebhookRepo, webhookConfig, c.ZapLog)
+	c.OnboardingService.SetEventPublisher(c.OutboundWebhookService)
+	c.OnboardingService.SetNotifier(c.NotificationService)
+	c.InvestingService.SetEventPublisher(c.OutboundWebhookService)
+
+	// Initialize the daily ops digest, emailed to admins and published to
+	// endpoints subscribed to ops.daily_digest
+	var digestMailer opsdigest.Mailer
+	if c.EmailService != nil {
+		digestMailer = c.EmailService
+	}
+	c.OpsDigestService = opsdigest.NewService(
+		repositories.NewOpsDigestRepository(c.DB, c.ZapLog),
+		digestMailer,
+		c.OutboundWebhookService,
+		opsdigest.Config{
+			Recipients: c.Config.OpsDigest.Recipients,
+			SendHour:   c.Config.OpsDigest.SendHourUTC,
+			Interval:   time.Duration(c.Config.OpsDigest.IntervalMinutes) * time.Minute,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize sampled request capture for debugging
+	c.HTTPCaptureService = httpcapture.NewService(
+		repositories.NewHTTPCaptureRepository(c.DB, c.ZapLog),
+		httpcapture.Config{
+			Enabled:       c.Config.HTTPCapture.Enabled,
+			SamplePercent: c.Config.HTTPCapture.SamplePercent,
+			TTL:           time.Duration(c.Config.HTTPCapture.TTLHours) * time.Hour,
+			MaxBodyBytes:  c.Config.HTTPCapture.MaxBodyBytes,
+			RefreshEvery:  time.Duration(c.Config.HTTPCapture.TargetRefreshSeconds) * time.Second,
+			QueueSize:     c.Config.HTTPCapture.QueueSize,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize traffic shadowing for soft-launching rewritten services
+	c.ShadowService = shadow.NewService(shadow.Config{
+		Enabled:      c.Config.Shadow.Enabled,
+		MaxBodyBytes: c.Config.Shadow.MaxBodyBytes,
+		QueueSize:    c.Config.Shadow.QueueSize,
+		Workers:      c.Config.Shadow.Workers,
+		Timeout:      time.Duration(c.Config.Shadow.TimeoutSeconds) * time.Second,
+		IgnoreFields: c.Config.Shadow.IgnoreFields,
+	}, c.ZapLog)
+	for _, route := range c.Config.Shadow.Routes {
+		candidate, err := shadow.NewProxyCandidate(route.Target)
+		if err == nil {
+			err = c.ShadowService.Register(shadow.Route{
+				Method:        route.Method,
+				Path:          route.Path,
+				Candidate:     candidate,
+				SamplePercent: route.SamplePercent,
+				AllowWrites:   route.AllowWrites,
+				IgnoreFields:  route.IgnoreFields,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to configure shadow route: %w", err)
+		}
+	}
+
+	// Initialize per-user API usage rollups fed by the HTTP middleware
+	c.APIUsageService = apiusage.NewService(
+		repositories.NewAPIUsageRepository(c.DB, c.ZapLog),
+		apiusage.Config{
+			Enabled:       c.Config.APIUsage.Enabled,
+			FlushInterval: time.Duration(c.Config.APIUsage.FlushSeconds) * time.Second,
+			Retention:     time.Duration(c.Config.APIUsage.RetentionDays) * 24 * time.Hour,
+		},
+		c.ZapLog,
+	)
+
+	// Initialize the withdrawal address book
+	recipientRepo := repositories.NewRecipientRepository(c.DB, c.ZapLog)
+	recipientRepo.SetFieldEncryptor(c.FieldEncryptor)
+	c.RecipientService = recipients.NewService(recipientRepo, recipients.Config{
+		FirstUseHold: time.Duration(c.Config.Recipients.FirstUseHoldHours) * time.Hour,
+		MaxPerUser:   c.Config.Recipients.MaxPerUser,
+	}, c.ZapLog)
+	if bank := c.Config.BankVerification; bank.Enabled && bank.BaseURL != "" {
+		c.RecipientService.SetAccountVerifier(adapters.NewBankLinkClient(adapters.BankLinkConfig{
+			BaseURL:  bank.BaseURL,
+			ClientID: bank.ClientID,
+			Secret:   bank.Secret,
+			Timeout:  time.Duration(bank.TimeoutSeconds) * time.Second,
+		}), c.UserRepo, recipients.AccountCheckConfig{
+			RequiredAbove: decimal.NewFromFloat(bank.RequiredAboveUSD),
+		})
+	}
+	if check := c.Config.Recipients.AddressCheck; check.Enabled {
+		// Config keys arrive lower-cased; chain IDs are upper case
+		wallets := make(map[string]string, len(check.MicroDepositWallets))
+		for chain, walletID := range check.MicroDepositWallets {
+			wallets[strings.ToUpper(chain)] = walletID
+		}
+		var sender recipients.MicroDepositSender
+		if len(wallets) > 0 {
+			sender = circle.NewMicroDepositSender(c.CircleClient, wallets)
+		}
+		c.RecipientService.SetAddressChecks(sender, recipients.AddressCheckConfig{
+			RequiredAbove:   decimal.NewFromFloat(check.RequiredAboveUSD),
+			NewFor:          time.Duration(check.NewForDays) * 24 * time.Hour,
+			MicroDepositTTL: time.Duration(check.MicroDepositTTLHours) * time.Hour,
+			SignatureTTL:    time.Duration(check.SignatureTTLMinutes) * time.Minute,
+			MaxAttempts:     check.MaxAttempts,
+		})
+	}
+
+	// Read-only access users grant to advisors and family members, limited to
+	// live holdings and trade confirmations
+	c.DelegateService = delegates.NewService(
+		repositories.NewDelegateRepository(c.DB, c.ZapLog),
+		c.InvestingService,
+		c.DocumentService,
+		delegates.Config{
+			MaxGrantTTL: time.Duration(c.Config.Delegates.MaxGrantDays) * 24 * time.Hour,
+			SessionTTL:  time.Duration(c.Config.Delegates.SessionTTLMinutes) * time.Minute,
+			MaxGrants:   c.Config.Delegates.MaxGrantsPerUser,
+		},
+		c.ZapLog,
+	)
+
+	// Cost basis of positions transferred in, imported from the previous
+	// broker's CSV export
+	c.CostBasisService = costbasis.NewService(
+		repositories.NewCostBasisRepository(c.DB, c.ZapLog),
+		costbasis.DefaultConfig(),
+		c.ZapLog,
+	)
+
+	// Developer program: sandbox tenants with self-serve keys, per-key usage
+	// and test webhook endpoints
+	c.DeveloperService = developer.NewService(
+		repositories.NewDeveloperRepository(c.DB, c.ZapLog),
+		c.APIKeyService,
+		c.APIUsageService,
+		c.OutboundWebhookService,
+		developer.DefaultConfig(),
+		c.ZapLog,
+	)
+
+	// Wallets linked to users' Due accounts, including self-custody addresses
+	c.LinkedWalletService = linkedwallets.NewService(
+		repositories.NewLinkedWalletRepository(c.DB, c.ZapLog),
+		dueClient,
+		c.UserRepo,
+		linkedwallets.Config{ChallengeSecret: []byte(c.Config.JWT.Secret)},
+		c.ZapLog,
+	)
+
+	// Initialize per-user live event streams for order and deposit progress
+	c.EventStreamService = eventstream.NewService(c.RedisClient, eventstream.Config{
+		MaxLen:    int64(c.Config.EventStream.MaxLen),
+		Retention: time.Duration(c.Config.EventStream.RetentionHours) * time.Hour,
+	}, c.ZapLog)
+	c.InvestingService.SetProgressPublisher(c.EventStreamService)
+	if c.PaperInvestingService != nil {
+		c.PaperInvestingService.SetProgressPublisher(c.EventStreamService)
+	}
+
+	// Route deposit fan-out and notification dispatch through the event bus
+	bus, err := eventbus.New(eventbus.Config{
+		Driver:       c.Config.EventBus.Driver,
+		NATSURL:      c.Config.EventBus.NATSURL,
+		KafkaRESTURL: c.Config.EventBus.KafkaRESTURL,
+		TopicPrefix:  c.Config.EventBus.TopicPrefix,
+		MaxAttempts:  c.Config.EventBus.MaxAttempts,
+	}, c.ZapLog)
+	if err != nil {
+		return fmt.Errorf("failed to initialize event bus: %w", err)
+	}
+	c.EventBus = bus
+	consumers := event_fanout.NewConsumers(c.OutboundWebhookService, c.EventStreamService, c.NotificationService, c.ZapLog)
+	consumers.SetBalanceRefresher(c.BalanceCacheService)
+	consumers.SetPromotionGranter(c.PromotionService)
+	consumers.SetHotCache(c.HotCacheService)
+	if err := consumers.Register(bus); err != nil {
+		return err
+	}
+	c.FundingService.SetEventBus(bus)
+	c.NotificationService.SetEventBus(bus)
+
+	// Relay onboarding, KYC and wallet status changes from the outbox to the bus
+	c.OnboardingOutboxRepo = repositories.NewOnboardingOutboxRepository(c.DB, c.ZapLog)
+	c.OnboardingEventRelay = onboardingevents.NewRelay(
+		c.OnboardingOutboxRepo,
+		bus,
+		onboardingevents.Config{
+			PollInterval: time.Duration(c.Config.OnboardingEvents.PollIntervalSeconds) * time.Second,
+			BatchSize:    c.Config.OnboardingEvents.BatchSize,
+			Retention:    time.Duration(c.Config.OnboardingEvents.RetentionHours) * time.Hour,
+		},
+		c.ZapLog,
+	)
+
 	return nil
 }
 
@@ -540,6 +1564,400 @@ func (c *Container) GetAllocationService() *allocation.Service {
 	return c.AllocationService
 }
 
+// GetRetentionService returns the data retention service
+func (c *Container) GetRetentionService() *retention.Service {
+	return c.RetentionService
+}
+
+// GetWalletBackfillService returns the wallet provisioning backfill service
+func (c *Container) GetWalletBackfillService() *walletbackfill.Service {
+	return c.WalletBackfillService
+}
+
+// GetCircleSubscriptionService returns the Circle notification subscription service
+func (c *Container) GetCircleSubscriptionService() *circlesubscription.Service {
+	return c.CircleSubscriptions
+}
+
+// GetBalanceCacheService returns the wallet balance cache
+func (c *Container) GetBalanceCacheService() *balancecache.Service {
+	return c.BalanceCacheService
+}
+
+// GetRestoreDrillService returns the backup restore drill service
+func (c *Container) GetRestoreDrillService() *restoredrill.Service {
+	return c.RestoreDrillService
+}
+
+// GetJurisdictionService returns the country rules service
+func (c *Container) GetJurisdictionService() *jurisdiction.Service {
+	return c.JurisdictionService
+}
+
+// GetGeoIPService returns the signup and login geolocation service, or nil
+// when geo-IP lookups are disabled
+func (c *Container) GetGeoIPService() *geoip.Service {
+	return c.GeoIPService
+}
+
+// GetCustodialService returns the custodial account service
+func (c *Container) GetCustodialService() *custodial.Service {
+	return c.CustodialService
+}
+
+// GetTrustedContactService returns the trusted contact service
+func (c *Container) GetTrustedContactService() *trustedcontact.Service {
+	return c.TrustedContactService
+}
+
+// GetCaseService returns the admin case service
+func (c *Container) GetCaseService() *cases.Service {
+	return c.CaseService
+}
+
+// GetInactivityService returns the dormant account monitor
+func (c *Container) GetInactivityService() *inactivity.Service {
+	return c.InactivityService
+}
+
+// GetReactivationService returns the closed account reactivation service
+func (c *Container) GetReactivationService() *reactivation.Service {
+	return c.ReactivationService
+}
+
+// GetEDDService returns the enhanced due diligence service
+func (c *Container) GetEDDService() *edd.Service {
+	return c.EDDService
+}
+
+// GetKYBService returns the business onboarding service
+func (c *Container) GetKYBService() *kyb.Service {
+	return c.KYBService
+}
+
+// GetApprovalService returns the maker-checker approval service
+func (c *Container) GetApprovalService() *approvals.Service {
+	return c.ApprovalService
+}
+
+// GetRateService returns the historical exchange rate service
+func (c *Container) GetRateService() *rates.Service {
+	return c.RateService
+}
+
+// GetConsentService returns the agreement consents service
+func (c *Container) GetConsentService() *consents.Service {
+	return c.ConsentService
+}
+
+// EnableWithdrawalApprovals holds the withdrawal service's withdrawals above
+// the configured threshold for review under the withdrawal approval policy
+func (c *Container) EnableWithdrawalApprovals(withdrawals *services.WithdrawalService) {
+	c.ApprovalService.Register(entities.ApprovalActionWithdrawal, approvals.Policy{
+		RequiredApprovals: c.Config.Approvals.WithdrawalRequiredApprovals,
+		Approvers:         c.approvers(),
+	}, withdrawals.ApprovalHandler())
+	withdrawals.SetApprovals(c.ApprovalService, decimal.NewFromFloat(c.Config.Approvals.WithdrawalThreshold))
+}
+
+// approvers parses the configured approver IDs, skipping invalid ones
+func (c *Container) approvers() []uuid.UUID {
+	var approvers []uuid.UUID
+	for _, raw := range c.Config.Approvals.Approvers {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.ZapLog.Warn("Ignoring invalid approver ID", zap.String("approver", raw))
+			continue
+		}
+		approvers = append(approvers, id)
+	}
+	return approvers
+}
+
+// GetBalanceHoldService returns the buying power hold service
+func (c *Container) GetBalanceHoldService() *holds.Service {
+	return c.BalanceHoldService
+}
+
+// GetPromotionService returns the promotional credit service
+func (c *Container) GetPromotionService() *promotions.Service {
+	return c.PromotionService
+}
+
+// GetSubscriptionService returns the premium subscription billing service
+func (c *Container) GetSubscriptionService() *subscription.Service {
+	return c.SubscriptionService
+}
+
+// GetOpsDigestService returns the daily ops digest service
+func (c *Container) GetOpsDigestService() *opsdigest.Service {
+	return c.OpsDigestService
+}
+
+// GetHTTPCaptureService returns the debug request capture service
+func (c *Container) GetHTTPCaptureService() *httpcapture.Service {
+	return c.HTTPCaptureService
+}
+
+// GetAPIUsageService returns the API usage analytics service
+func (c *Container) GetAPIUsageService() *apiusage.Service {
+	return c.APIUsageService
+}
+
+// GetRecipientService returns the withdrawal address book service
+func (c *Container) GetRecipientService() *recipients.Service {
+	return c.RecipientService
+}
+
+// GetLinkedWalletService returns the linked wallet service
+func (c *Container) GetLinkedWalletService() *linkedwallets.Service {
+	return c.LinkedWalletService
+}
+
+// GetExperimentService returns the onboarding experiments service
+func (c *Container) GetExperimentService() *experiments.Service {
+	return c.ExperimentService
+}
+
+// GetDelegateService returns the delegated account access service
+func (c *Container) GetDelegateService() *delegates.Service {
+	return c.DelegateService
+}
+
+// GetCostBasisService returns the cost basis import and tax lot service
+func (c *Container) GetCostBasisService() *costbasis.Service {
+	return c.CostBasisService
+}
+
+// GetDeveloperService returns the developer program service
+func (c *Container) GetDeveloperService() *developer.Service {
+	return c.DeveloperService
+}
+
+// GetPIIVaultService returns the PII vault
+func (c *Container) GetPIIVaultService() *piivault.Service {
+	return c.PIIVaultService
+}
+
+// GetDepositReferenceService returns the shared deposit account service
+func (c *Container) GetDepositReferenceService() *depositref.Service {
+	return c.DepositReferenceService
+}
+
+// GetSuspenseService returns the suspense account service
+func (c *Container) GetSuspenseService() *suspense.Service {
+	return c.SuspenseService
+}
+
+// GetAlertingService returns the alert routing service
+func (c *Container) GetAlertingService() *alerting.Service {
+	return c.AlertingService
+}
+
+// GetHotCacheService returns the hot endpoint cache
+func (c *Container) GetHotCacheService() *hotcache.Service {
+	return c.HotCacheService
+}
+
+// GetResidencyService returns the data residency service
+func (c *Container) GetResidencyService() *residency.Service {
+	return c.ResidencyService
+}
+
+// GetRewardService returns the rewards program service
+func (c *Container) GetRewardService() *rewards.Service {
+	return c.RewardService
+}
+
+// GetWebhookArchiveService returns the provider webhook archive, or nil
+// when archiving is disabled
+func (c *Container) GetWebhookArchiveService() *webhookarchive.Service {
+	return c.WebhookArchiveService
+}
+
+// GetIntegrityService returns the data integrity checker
+func (c *Container) GetIntegrityService() *integrity.Service {
+	return c.IntegrityService
+}
+
+// GetOrderOpsService returns the stuck order intervention service
+func (c *Container) GetOrderOpsService() *orderops.Service {
+	return c.OrderOpsService
+}
+
+// GetPaperTradingService returns the trading mode service, or nil when paper
+// trading is disabled
+func (c *Container) GetPaperTradingService() *papertrading.Service {
+	return c.PaperTradingService
+}
+
+// GetAttributionService returns the portfolio performance attribution service
+func (c *Container) GetAttributionService() *attribution.Service {
+	return c.AttributionService
+}
+
+// GetPortfolioShareService returns the portfolio share link service
+func (c *Container) GetPortfolioShareService() *portfolioshare.Service {
+	return c.PortfolioShareService
+}
+
+// GetMarketCalendarService returns the exchange trading calendar
+func (c *Container) GetMarketCalendarService() *marketcalendar.Service {
+	return c.MarketCalendarService
+}
+
+// GetAIArtifactService returns the AI artifact listing and export service
+func (c *Container) GetAIArtifactService() *aiartifacts.Service {
+	return c.AIArtifactService
+}
+
+// GetOutboundWebhookService returns the partner webhook service
+func (c *Container) GetOutboundWebhookService() *outboundwebhook.Service {
+	return c.OutboundWebhookService
+}
+
+// GetMarketDataService returns the streaming quote service, or nil when
+// quote streaming is disabled
+func (c *Container) GetMarketDataService() *marketdata.Service {
+	return c.MarketDataService
+}
+
+// GetGoalService returns the investment goals service
+func (c *Container) GetGoalService() *goals.Service {
+	return c.GoalService
+}
+
+// GetProjectionService returns the buying power projection service
+func (c *Container) GetProjectionService() *projection.Service {
+	return c.ProjectionService
+}
+
+// GetSweepService returns the auto-sweep service
+func (c *Container) GetSweepService() *sweep.Service {
+	return c.SweepService
+}
+
+// GetAccountingService returns the general ledger export service
+func (c *Container) GetAccountingService() *accounting.Service {
+	return c.AccountingService
+}
+
+// GetWarehouseService returns the data warehouse export service
+func (c *Container) GetWarehouseService() *warehouse.Service {
+	return c.WarehouseService
+}
+
+// GetFaultInjectionService returns the outbound fault injection service
+func (c *Container) GetFaultInjectionService() *faultinject.Service {
+	return c.FaultInjectionService
+}
+
+// GetBlotterService returns the regulatory order blotter service
+func (c *Container) GetBlotterService() *blotter.Service {
+	return c.BlotterService
+}
+
+// GetUploadService returns the secure document upload service
+func (c *Container) GetUploadService() *upload.Service {
+	return c.UploadService
+}
+
+// GetDocumentService returns the in-app document center service
+func (c *Container) GetDocumentService() *documents.Service {
+	return c.DocumentService
+}
+
+// GetNewsService returns the portfolio news feed service
+func (c *Container) GetNewsService() *news.Service {
+	return c.NewsService
+}
+
+// GetShadowService returns the traffic shadowing service
+func (c *Container) GetShadowService() *shadow.Service {
+	return c.ShadowService
+}
+
+// GetPasswordPolicyService returns the password policy service
+func (c *Container) GetPasswordPolicyService() *passwordpolicy.Service {
+	return c.PasswordPolicyService
+}
+
+// GetLedgerAdjustmentService returns the manual ledger adjustment service
+func (c *Container) GetLedgerAdjustmentService() *adjustments.Service {
+	return c.LedgerAdjustmentService
+}
+
+// GetBulkOpsService returns the admin bulk user operations service
+func (c *Container) GetBulkOpsService() *bulkops.Service {
+	return c.BulkOpsService
+}
+
+// GetEventStreamService returns the live event stream service
+func (c *Container) GetEventStreamService() *eventstream.Service {
+	return c.EventStreamService
+}
+
+// GetWorkerRegistry returns the registry of background workers started in this process
+func (c *Container) GetWorkerRegistry() *workerstatus.Registry {
+	return c.WorkerRegistry
+}
+
+// initializeAlerting builds alert routes from config and registers the
+// channels that have credentials
+func (c *Container) initializeAlerting() error {
+	cfg := c.Config.Alerting
+	schedule, err := alerting.ParseSchedule(cfg.Timezone, cfg.BusinessDays, cfg.BusinessStartHour, cfg.BusinessEndHour)
+	if err != nil {
+		return err
+	}
+	var routes map[entities.AlertSeverity]alerting.Route
+	if len(cfg.Routes) > 0 {
+		routes = make(map[entities.AlertSeverity]alerting.Route, len(cfg.Routes))
+		for severity, route := range cfg.Routes {
+			routes[entities.AlertSeverity(severity)] = alerting.Route{
+				BusinessHours: alertChannels(route.BusinessHours),
+				AfterHours:    alertChannels(route.AfterHours),
+			}
+		}
+	}
+
+	c.AlertingService = alerting.NewService(
+		repositories.NewAlertDeliveryRepository(c.DB, c.ZapLog),
+		alerting.Config{
+			Routes:      routes,
+			Schedule:    schedule,
+			DedupWindow: time.Duration(cfg.DedupWindowMinutes) * time.Minute,
+			MaxAttempts: cfg.MaxAttempts,
+		},
+		c.ZapLog,
+	)
+
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if cfg.PagerDutyRoutingKey != "" {
+		c.AlertingService.RegisterChannel(entities.AlertChannelPagerDuty,
+			alerting.NewPagerDutySender(cfg.PagerDutyEventsURL, cfg.PagerDutyRoutingKey, timeout))
+	}
+	if cfg.SlackWebhookURL != "" {
+		c.AlertingService.RegisterChannel(entities.AlertChannelSlack, alerting.NewSlackSender(cfg.SlackWebhookURL, timeout))
+	}
+	webhookURL := cfg.WebhookURL
+	if webhookURL == "" {
+		webhookURL = c.Config.Reconciliation.AlertWebhookURL
+	}
+	if webhookURL != "" {
+		c.AlertingService.RegisterChannel(entities.AlertChannelWebhook, alerting.NewWebhookSender(webhookURL, timeout))
+	}
+	return nil
+}
+
+func alertChannels(names []string) []entities.AlertChannel {
+	channels := make([]entities.AlertChannel, 0, len(names))
+	for _, name := range names {
+		channels = append(channels, entities.AlertChannel(name))
+	}
+	return channels
+}
+
 // initializeReconciliationService initializes the reconciliation service and scheduler
 func (c *Container) initializeReconciliationService() error {
 	// Initialize metrics service (placeholder - extend pkg/metrics/reconciliation_metrics.go)
@@ -551,7 +1969,6 @@ func (c *Container) initializeReconciliationService() error {
 		ToleranceCircle:        decimal.NewFromFloat(10.0),
 		ToleranceAlpaca:        decimal.NewFromFloat(100.0),
 		EnableAlerting:         true,
-		AlertWebhookURL:        c.Config.Reconciliation.AlertWebhookURL,
 	}
 
 	// Initialize reconciliation service with all dependencies
@@ -562,19 +1979,17 @@ func (c *Container) initializeReconciliationService() error {
 		c.WithdrawalRepo,
 		c.ConversionRepo,
 		c.LedgerService,
-		&circleClientAdapter{
-			client:     c.CircleClient,
-			walletRepo: c.WalletRepo,
-		},
+		&circleClientAdapter{balances: c.BalanceCacheService},
 		&alpacaClientAdapter{
-			client:  c.AlpacaClient,
-			service: c.AlpacaService,
-			db:      c.DB,
+			service:     c.AlpacaService,
+			db:          c.DB,
+			concurrency: c.Config.Reconciliation.AggregationConcurrency,
 		},
 		c.Logger,
 		metricsService,
 		reconciliationConfig,
 	)
+	c.ReconciliationService.SetAlerter(c.AlertingService)
 
 	// Initialize reconciliation scheduler
 	schedulerConfig := &reconciliation.SchedulerConfig{
@@ -593,67 +2008,32 @@ func (c *Container) initializeReconciliationService() error {
 
 // Adapters for reconciliation service
 type circleClientAdapter struct {
-	client     *circle.Client
-	walletRepo *repositories.WalletRepository
+	balances *balancecache.Service
 }
 
-func (a *circleClientAdapter) GetTotalUSDCBalance(ctx context.Context) (decimal.Decimal, error) {
-	// Query all active wallets from the database
-	filters := repositories.WalletListFilters{
-		Status: (*entities.WalletStatus)(ptrOf(entities.WalletStatusLive)),
-		Limit:  10000, // High limit to get all wallets
-		Offset: 0,
-	}
-
-	wallets, _, err := a.walletRepo.ListWithFilters(ctx, filters)
-	if err != nil {
-		return decimal.Zero, fmt.Errorf("failed to list wallets: %w", err)
-	}
-
-	// Aggregate USDC balances from all wallets
-	totalBalance := decimal.Zero
-	for _, wallet := range wallets {
-		if wallet.CircleWalletID == "" {
-			continue // Skip wallets without Circle wallet ID
-		}
-
-		// Get balance for this wallet
-		balanceResp, err := a.client.GetWalletBalances(ctx, wallet.CircleWalletID)
-		if err != nil {
-			// Log error but continue with other wallets
-			continue
-		}
-
-		// Parse USDC balance
-		usdcBalanceStr := balanceResp.GetUSDCBalance()
-		if usdcBalanceStr != "0" {
-			usdcBalance, err := decimal.NewFromString(usdcBalanceStr)
-			if err == nil {
-				totalBalance = totalBalance.Add(usdcBalance)
-			}
-		}
-	}
-
-	return totalBalance, nil
+// GetTotalUSDCBalance sums cached wallet balances after bringing them up to
+// date with Circle's bulk balances endpoint and a bounded per-wallet pool
+func (a *circleClientAdapter) GetTotalUSDCBalance(ctx context.Context) (*entities.BalanceAggregate, error) {
+	return a.balances.TotalUSDC(ctx)
 }
 
 type alpacaClientAdapter struct {
-	client  *alpaca.Client
-	service *alpaca.Service
-	db      *sql.DB
+	service     *alpaca.Service
+	db          *sql.DB
+	concurrency int
 }
 
-func (a *alpacaClientAdapter) GetTotalBuyingPower(ctx context.Context) (decimal.Decimal, error) {
+func (a *alpacaClientAdapter) GetTotalBuyingPower(ctx context.Context) (*entities.BalanceAggregate, error) {
 	// Query all users from database who have Alpaca accounts
 	query := `
-		SELECT alpaca_account_id 
-		FROM users 
+		SELECT alpaca_account_id
+		FROM users
 		WHERE alpaca_account_id IS NOT NULL AND alpaca_account_id != '' AND is_active = true
 	`
 
 	rows, err := a.db.QueryContext(ctx, query)
 	if err != nil {
-		return decimal.Zero, fmt.Errorf("failed to query users with Alpaca accounts: %w", err)
+		return nil, fmt.Errorf("failed to query users with Alpaca accounts: %w", err)
 	}
 	defer rows.Close()
 
@@ -661,27 +2041,15 @@ func (a *alpacaClientAdapter) GetTotalBuyingPower(ctx context.Context) (decimal.
 	for rows.Next() {
 		var accountID string
 		if err := rows.Scan(&accountID); err != nil {
-			continue
+			return nil, fmt.Errorf("failed to scan Alpaca account: %w", err)
 		}
 		accountIDs = append(accountIDs, accountID)
 	}
-
-	// Aggregate buying power from all accounts
-	totalBuyingPower := decimal.Zero
-	for _, accountID := range accountIDs {
-		account, err := a.service.GetAccount(ctx, accountID)
-		if err != nil {
-			// Log error but continue with other accounts
-			continue
-		}
-
-		// Add buying power (already decimal.Decimal)
-		if !account.BuyingPower.IsZero() {
-			totalBuyingPower = totalBuyingPower.Add(account.BuyingPower)
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate Alpaca accounts: %w", err)
 	}
 
-	return totalBuyingPower, nil
+	return a.service.SumBuyingPower(ctx, accountIDs, a.concurrency), nil
 }
 
 // Real metrics service using Prometheus metrics from pkg/common/metrics
@@ -728,11 +2096,6 @@ func (m *reconciliationMetricsService) RecordReconciliationAlert(checkType, seve
 	commonmetrics.ReconciliationAlertsTotal.WithLabelValues(checkType, severity).Inc()
 }
 
-// Helper function to create pointer to value
-func ptrOf[T any](v T) *T {
-	return &v
-}
-
 func convertWalletChains(raw []string, logger *zap.Logger) []entities.WalletChain {
 	if len(raw) == 0 {
 		logger.Warn("circle.supported_chains not configured; defaulting to SOL-DEVNET")
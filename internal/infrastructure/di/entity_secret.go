@@ -0,0 +1,39 @@
+package di
+
+import (
+	"go.uber.org/zap"
+
+	entitysecret "github.com/stack-service/stack_service/internal/domain/services/entity_secret"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// NewEntitySecretService loads the Circle entity secret provided by the
+// secrets manager, either hex-encoded or as a file sealed with the field
+// encryption keyring. The entity public key is pinned from configuration or
+// fetched from Circle on first use. Without a secret, requests that need
+// entity secret ciphertext fail until one is configured.
+func NewEntitySecretService(cfg *config.Config, sealer *crypto.FieldEncryptor, keySource entitysecret.PublicKeySource, logger *zap.Logger) (*entitysecret.Service, error) {
+	service := entitysecret.NewService(logger)
+	service.SetPublicKeySource(keySource)
+
+	if cfg.Circle.EntityPublicKey != "" {
+		if err := service.SetPublicKey(cfg.Circle.EntityPublicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case cfg.Circle.EntitySecret != "":
+		if err := service.SetSecret(cfg.Circle.EntitySecret); err != nil {
+			return nil, err
+		}
+	case cfg.Circle.EntitySecretFile != "":
+		if err := service.LoadSealedFile(cfg.Circle.EntitySecretFile, sealer); err != nil {
+			return nil, err
+		}
+	default:
+		logger.Warn("Circle entity secret not configured; wallet creation and transfers are disabled")
+	}
+	return service, nil
+}
@@ -0,0 +1,27 @@
+package di
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/services/faultinject"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// NewFaultInjectionService builds the fault injector the provider clients are
+// wrapped with. It is built before the clients and stays disabled in
+// production whatever the configuration says.
+func NewFaultInjectionService(cfg *config.Config, db *sql.DB, logger *zap.Logger) *faultinject.Service {
+	enabled := cfg.FaultInjection.Enabled
+	if enabled && cfg.Environment == "production" {
+		logger.Warn("Fault injection is not available in production; ignoring fault_injection.enabled")
+		enabled = false
+	}
+	return faultinject.NewService(repositories.NewFaultInjectionRepository(db, logger), faultinject.Config{
+		Enabled:      enabled,
+		DefaultTTL:   time.Duration(cfg.FaultInjection.DefaultTTLMinutes) * time.Minute,
+		RefreshEvery: time.Duration(cfg.FaultInjection.RuleRefreshSeconds) * time.Second,
+	}, logger)
+}
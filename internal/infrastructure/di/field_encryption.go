@@ -0,0 +1,32 @@
+package di
+
+import (
+	"fmt"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// NewFieldEncryptor builds the PII field encryptor from the keyring provided by
+// the secrets manager. When no keyring is configured the service encryption key
+// is used as key version 1 so deployments without rotation still encrypt at rest.
+func NewFieldEncryptor(cfg *config.Config) (*crypto.FieldEncryptor, error) {
+	source := crypto.StaticFieldKeySource{ActiveVersion: cfg.Security.FieldEncryptionActiveVersion}
+	if cfg.Security.FieldEncryptionKeys != "" {
+		keys, err := crypto.ParseFieldKeys(cfg.Security.FieldEncryptionKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field encryption keyring: %w", err)
+		}
+		source.Keys = keys
+	} else {
+		source.Keys = map[int][]byte{1: crypto.DeriveFieldKey(cfg.Security.EncryptionKey)}
+		source.ActiveVersion = 1
+	}
+
+	indexSecret := cfg.Security.FieldIndexKey
+	if indexSecret == "" {
+		indexSecret = cfg.Security.EncryptionKey + ":blind-index"
+	}
+
+	return crypto.NewFieldEncryptor(source, crypto.DeriveFieldKey(indexSecret))
+}
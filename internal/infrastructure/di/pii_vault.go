@@ -0,0 +1,28 @@
+package di
+
+import (
+	"fmt"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// NewPIIVaultEncryptor builds the PII vault's encryptor from its own keyring.
+// Without one, the vault key is derived from the service encryption key under
+// a different label, so it still differs from the field encryption key.
+func NewPIIVaultEncryptor(cfg *config.Config) (*crypto.FieldEncryptor, error) {
+	source := crypto.StaticFieldKeySource{ActiveVersion: cfg.Security.PIIVaultActiveVersion}
+	if cfg.Security.PIIVaultKeys != "" {
+		keys, err := crypto.ParseFieldKeys(cfg.Security.PIIVaultKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PII vault keyring: %w", err)
+		}
+		source.Keys = keys
+	} else {
+		source.Keys = map[int][]byte{1: crypto.DeriveFieldKey(cfg.Security.EncryptionKey + ":pii-vault")}
+		source.ActiveVersion = 1
+	}
+
+	// Vault records are looked up by token, never by a blind index
+	return crypto.NewFieldEncryptor(source, crypto.DeriveFieldKey(cfg.Security.EncryptionKey+":pii-vault-index"))
+}
@@ -0,0 +1,35 @@
+package di
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/stack-service/stack_service/internal/domain/services/restoredrill"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+// NewRestoreDrillService builds the backup restore drill from configuration
+func NewRestoreDrillService(cfg *config.Config, db *sql.DB, log *logger.Logger) *restoredrill.Service {
+	verify := cfg.BackupVerify
+	openScratch := func() (*sql.DB, error) {
+		scratchCfg := cfg.Database
+		scratchCfg.URL = verify.ScratchDatabaseURL
+		scratchCfg.MaxOpenConns = 2
+		scratchCfg.MaxIdleConns = 1
+		return database.NewConnection(scratchCfg)
+	}
+
+	return restoredrill.NewService(
+		db,
+		openScratch,
+		restoredrill.DirectoryBackupSource{Dir: verify.BackupDir, Pattern: verify.BackupPattern},
+		restoredrill.PgRestoreRestorer{TargetURL: verify.ScratchDatabaseURL, Binary: verify.PgRestorePath},
+		restoredrill.Config{
+			MaxBackupAge:       time.Duration(verify.MaxBackupAgeHours) * time.Hour,
+			MaxRowDriftPercent: verify.MaxRowDriftPercent,
+		},
+		log,
+	)
+}
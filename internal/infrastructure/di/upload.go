@@ -0,0 +1,81 @@
+package di
+
+import (
+	"time"
+
+	uploadstore "github.com/stack-service/stack_service/internal/adapters/upload"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/upload"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// newUploadService builds secure document uploads from configuration. A
+// misconfigured store leaves uploads off; production always requires a
+// virus scan before an upload can be used.
+func (c *Container) newUploadService() *upload.Service {
+	cfg := c.Config.Uploads
+
+	service := upload.NewService(repositories.NewUploadRepository(c.DB, c.ZapLog), upload.Config{
+		MaxSizeBytes:     int64(cfg.MaxSizeMB) << 20,
+		AllowedTypes:     cfg.AllowedContentTypes,
+		UploadURLTTL:     time.Duration(cfg.UploadURLMinutes) * time.Minute,
+		DownloadURLTTL:   time.Duration(cfg.DownloadURLMinutes) * time.Minute,
+		ProviderURLTTL:   time.Duration(cfg.ProviderURLHours) * time.Hour,
+		RequireScan:      cfg.RequireScan || c.Config.Environment == "production",
+		RequireReference: cfg.RequireUploads,
+		HomeRegion:       c.ResidencyService.HomeRegion(),
+		Interval:         time.Duration(cfg.PurgeIntervalMinutes) * time.Minute,
+	}, c.ZapLog)
+
+	switch cfg.Storage {
+	case "":
+	case "s3":
+		store, err := newUploadS3Store(cfg.S3)
+		if err != nil {
+			c.ZapLog.Warn("Invalid S3 upload configuration; uploads are off", zap.Error(err))
+			break
+		}
+		service.SetStorage(store)
+		service.SetResidency(c.ResidencyService)
+		// Users kept in a region without a bucket cannot upload, rather than
+		// having their files stored out of region
+		for name, s3 := range cfg.RegionalS3 {
+			region := entities.ResidencyRegion(name)
+			if !region.IsValid() {
+				c.ZapLog.Warn("Unknown residency region for uploads", zap.String("region", name))
+				continue
+			}
+			regional, err := newUploadS3Store(s3)
+			if err != nil {
+				c.ZapLog.Warn("Invalid regional S3 upload configuration", zap.String("region", name), zap.Error(err))
+				continue
+			}
+			service.SetRegionalStorage(region, regional)
+		}
+	default:
+		c.ZapLog.Warn("Unknown upload storage; uploads are off", zap.String("storage", cfg.Storage))
+	}
+
+	if cfg.ClamAV.Address != "" {
+		scanner, err := uploadstore.NewClamAVScanner(cfg.ClamAV.Address, time.Duration(cfg.ClamAV.TimeoutSeconds)*time.Second)
+		if err != nil {
+			c.ZapLog.Warn("Invalid ClamAV configuration; uploads are not scanned", zap.Error(err))
+		} else {
+			service.SetScanner(scanner)
+		}
+	}
+	return service
+}
+
+func newUploadS3Store(cfg config.UploadsS3Config) (*uploadstore.S3Store, error) {
+	return uploadstore.NewS3Store(uploadstore.S3Config{
+		Bucket:          cfg.Bucket,
+		Region:          cfg.Region,
+		Endpoint:        cfg.Endpoint,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		PathStyle:       cfg.PathStyle,
+	})
+}
@@ -0,0 +1,26 @@
+package di
+
+import (
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/services/walletbackfill"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+)
+
+// NewWalletBackfillService builds the wallet provisioning backfill from configuration
+func NewWalletBackfillService(cfg *config.Config, db *sql.DB, log *zap.Logger) *walletbackfill.Service {
+	return walletbackfill.NewService(
+		repositories.NewWalletBackfillRepository(db, log),
+		walletbackfill.Config{
+			BatchSize:       cfg.WalletBackfill.BatchSize,
+			BatchInterval:   time.Duration(cfg.WalletBackfill.BatchIntervalSeconds) * time.Second,
+			MaxInFlight:     cfg.WalletBackfill.MaxInFlight,
+			SupportedChains: convertWalletChains(cfg.Circle.SupportedChains, log),
+		},
+		log,
+	)
+}
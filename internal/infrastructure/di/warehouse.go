@@ -0,0 +1,70 @@
+package di
+
+import (
+	"time"
+
+	warehousesink "github.com/stack-service/stack_service/internal/adapters/warehouse"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/warehouse"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+	"go.uber.org/zap"
+)
+
+// newWarehouseService builds the business event export from configuration.
+// Unknown streams are dropped, and a misconfigured destination leaves the
+// service without a sink so it exports nothing.
+func (c *Container) newWarehouseService() *warehouse.Service {
+	cfg := c.Config.Warehouse
+
+	var streams []entities.WarehouseStream
+	for _, name := range cfg.Streams {
+		stream := entities.WarehouseStream(name)
+		if !stream.IsValid() {
+			c.ZapLog.Warn("Ignoring unknown warehouse stream", zap.String("stream", name))
+			continue
+		}
+		streams = append(streams, stream)
+	}
+
+	service := warehouse.NewService(repositories.NewWarehouseRepository(c.DB, c.ZapLog), warehouse.Config{
+		Streams:   streams,
+		BatchSize: cfg.BatchSize,
+		Interval:  time.Duration(cfg.IntervalMinutes) * time.Minute,
+	}, c.ZapLog)
+
+	switch cfg.Destination {
+	case "bigquery":
+		bq := cfg.BigQuery
+		sink, err := warehousesink.NewBigQuerySink(warehousesink.BigQueryConfig{
+			ProjectID:       bq.ProjectID,
+			Dataset:         bq.Dataset,
+			Table:           bq.Table,
+			CredentialsJSON: bq.CredentialsJSON,
+		})
+		if err != nil {
+			c.ZapLog.Warn("Invalid BigQuery warehouse configuration; exports are off", zap.Error(err))
+			break
+		}
+		service.SetSink(sink)
+	case "snowflake":
+		sf := cfg.Snowflake
+		sink, err := warehousesink.NewSnowflakeSink(warehousesink.SnowflakeConfig{
+			Account:    sf.Account,
+			User:       sf.User,
+			PrivateKey: sf.PrivateKey,
+			Warehouse:  sf.Warehouse,
+			Database:   sf.Database,
+			Schema:     sf.Schema,
+			Role:       sf.Role,
+			Table:      sf.Table,
+		})
+		if err != nil {
+			c.ZapLog.Warn("Invalid Snowflake warehouse configuration; exports are off", zap.Error(err))
+			break
+		}
+		service.SetSink(sink)
+	default:
+		c.ZapLog.Warn("Unknown warehouse export destination; exports are off", zap.String("destination", cfg.Destination))
+	}
+	return service
+}
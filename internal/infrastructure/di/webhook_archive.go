@@ -0,0 +1,52 @@
+package di
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/adapters/artifactstore"
+	uploadstore "github.com/stack-service/stack_service/internal/adapters/upload"
+	"github.com/stack-service/stack_service/internal/domain/services/webhookarchive"
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+)
+
+// newWebhookArchiveService builds the archive of raw provider webhooks. It
+// returns nil when archiving is disabled or its store cannot be built, and
+// webhooks are then processed without being archived.
+func (c *Container) newWebhookArchiveService() *webhookarchive.Service {
+	cfg := c.Config.WebhookArchive
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var store webhookarchive.Store
+	var err error
+	switch cfg.Storage {
+	case "s3":
+		store, err = artifactstore.NewS3Store(uploadstore.S3Config{
+			Bucket:          cfg.S3.Bucket,
+			Region:          cfg.S3.Region,
+			Endpoint:        cfg.S3.Endpoint,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			PathStyle:       cfg.S3.PathStyle,
+		})
+	case "local":
+		store, err = artifactstore.NewLocalStore(cfg.LocalDir)
+	default:
+		err = fmt.Errorf("unknown webhook archive storage %q", cfg.Storage)
+	}
+	if err != nil {
+		c.ZapLog.Warn("Invalid webhook archive configuration; webhooks will not be archived", zap.Error(err))
+		return nil
+	}
+
+	archiveConfig := webhookarchive.DefaultConfig()
+	if cfg.KeyPrefix != "" {
+		archiveConfig.KeyPrefix = cfg.KeyPrefix
+	}
+	repo := repositories.NewWebhookArchiveRepository(c.DB, c.ZapLog)
+	repo.SetFieldEncryptor(c.FieldEncryptor)
+	return webhookarchive.NewService(repo, store, archiveConfig, c.ZapLog)
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// AccountInactivityRepository finds dormant funded accounts and tracks their
+// escalation state
+type AccountInactivityRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewAccountInactivityRepository creates a new account inactivity repository
+func NewAccountInactivityRepository(db *sql.DB, logger *zap.Logger) *AccountInactivityRepository {
+	return &AccountInactivityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListDormantFunded returns active users holding a balance whose most recent
+// login, deposit, order or withdrawal is older than the cutoff. Results are
+// ordered by user ID; pass the last ID seen as afterID to fetch the next page.
+func (r *AccountInactivityRepository) ListDormantFunded(ctx context.Context, cutoff time.Time, afterID uuid.UUID, limit int) ([]*entities.DormantAccount, error) {
+	query := `
+		SELECT u.id, u.email, act.last_activity_at, b.buying_power::text,
+		       ai.stage, ai.last_activity_at, ai.last_notified_at, ai.case_id
+		FROM users u
+		JOIN balances b ON b.user_id = u.id AND (b.buying_power > 0 OR b.pending_deposits > 0)
+		CROSS JOIN LATERAL (
+			SELECT GREATEST(
+				COALESCE(u.last_login_at, u.created_at),
+				(SELECT MAX(d.created_at) FROM deposits d WHERE d.user_id = u.id),
+				(SELECT MAX(o.created_at) FROM orders o WHERE o.user_id = u.id),
+				(SELECT MAX(w.created_at) FROM withdrawals w WHERE w.user_id = u.id)
+			) AS last_activity_at
+		) act
+		LEFT JOIN account_inactivity ai ON ai.user_id = u.id
+		WHERE u.is_active = true
+		  AND act.last_activity_at < $1
+		  AND u.id > $2
+		ORDER BY u.id
+		LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dormant accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*entities.DormantAccount
+	for rows.Next() {
+		account := &entities.DormantAccount{}
+		var stage sql.NullInt64
+		var stateActivity, lastNotified sql.NullTime
+		var caseID uuid.NullUUID
+
+		if err := rows.Scan(
+			&account.UserID,
+			&account.Email,
+			&account.LastActivityAt,
+			&account.BuyingPower,
+			&stage,
+			&stateActivity,
+			&lastNotified,
+			&caseID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan dormant account: %w", err)
+		}
+
+		if stage.Valid {
+			account.State = &entities.AccountInactivity{
+				UserID:         account.UserID,
+				Stage:          int(stage.Int64),
+				LastActivityAt: stateActivity.Time,
+			}
+			if lastNotified.Valid {
+				account.State.LastNotifiedAt = &lastNotified.Time
+			}
+			if caseID.Valid {
+				account.State.CaseID = &caseID.UUID
+			}
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate dormant accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// SaveState upserts the escalation state for a user
+func (r *AccountInactivityRepository) SaveState(ctx context.Context, state *entities.AccountInactivity) error {
+	query := `
+		INSERT INTO account_inactivity (user_id, stage, last_activity_at, last_notified_at, case_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			stage = EXCLUDED.stage,
+			last_activity_at = EXCLUDED.last_activity_at,
+			last_notified_at = EXCLUDED.last_notified_at,
+			case_id = EXCLUDED.case_id,
+			updated_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, query,
+		state.UserID, state.Stage, state.LastActivityAt, state.LastNotifiedAt, state.CaseID)
+	if err != nil {
+		r.logger.Error("Failed to save inactivity state", zap.Error(err), zap.String("user_id", state.UserID.String()))
+		return fmt.Errorf("failed to save inactivity state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// AccountReactivationRepository persists reactivation requests and the
+// closure state of user accounts
+type AccountReactivationRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewAccountReactivationRepository creates a new account reactivation repository
+func NewAccountReactivationRepository(db *sql.DB, logger *zap.Logger) *AccountReactivationRepository {
+	return &AccountReactivationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const reactivationColumns = `
+	id, user_id, status, closure_reason, requires_approval, verification_url,
+	screened_country, case_id, prior_holdings, restored_holdings, rejection_reason,
+	reviewed_by, review_notes, completed_at, created_at, updated_at`
+
+// GetClosure returns whether a user is active and why the account was closed
+func (r *AccountReactivationRepository) GetClosure(ctx context.Context, userID uuid.UUID) (*entities.AccountClosure, error) {
+	closure := &entities.AccountClosure{}
+	var closedAt sql.NullTime
+	var reason, notes sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, email, is_active, closed_at, closure_reason, closure_notes
+		FROM users WHERE id = $1`, userID).Scan(
+		&closure.UserID,
+		&closure.Email,
+		&closure.IsActive,
+		&closedAt,
+		&reason,
+		&notes,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get account closure: %w", err)
+	}
+
+	if closedAt.Valid {
+		closure.ClosedAt = &closedAt.Time
+	}
+	closure.Reason = entities.AccountClosureReason(reason.String)
+	if notes.Valid {
+		closure.Notes = &notes.String
+	}
+	return closure, nil
+}
+
+// ReopenAccount sets the user active again and clears the closure
+func (r *AccountReactivationRepository) ReopenAccount(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users SET
+			is_active = true, closed_at = NULL, closure_reason = NULL,
+			closure_notes = NULL, updated_at = $2
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, userID, time.Now())
+	if err != nil {
+		r.logger.Error("Failed to reopen account", zap.Error(err), zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to reopen account: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// Create inserts a request unless the user already has one in progress, in
+// which case the existing request is returned with created=false
+func (r *AccountReactivationRepository) Create(ctx context.Context, req *entities.ReactivationRequest) (*entities.ReactivationRequest, bool, error) {
+	priorHoldings, err := marshalHoldings(req.PriorHoldings)
+	if err != nil {
+		return nil, false, err
+	}
+
+	query := `
+		INSERT INTO account_reactivations (
+			id, user_id, status, closure_reason, requires_approval,
+			verification_url, prior_holdings, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (user_id) WHERE status IN ('pending_verification', 'pending_approval') DO NOTHING
+		RETURNING ` + reactivationColumns
+
+	created, err := scanReactivation(r.db.QueryRowContext(ctx, query,
+		req.ID, req.UserID, string(req.Status), string(req.ClosureReason), req.RequiresApproval,
+		req.VerificationURL, priorHoldings, req.CreatedAt))
+	if err == nil {
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to create reactivation request", zap.Error(err), zap.String("user_id", req.UserID.String()))
+		return nil, false, fmt.Errorf("failed to create reactivation request: %w", err)
+	}
+
+	existing, err := r.GetOpenByUser(ctx, req.UserID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load existing reactivation request: %w", err)
+	}
+	return existing, false, nil
+}
+
+// GetByID retrieves a reactivation request
+func (r *AccountReactivationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.ReactivationRequest, error) {
+	req, err := scanReactivation(r.db.QueryRowContext(ctx,
+		`SELECT `+reactivationColumns+` FROM account_reactivations WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrReactivationNotFound
+		}
+		return nil, fmt.Errorf("failed to get reactivation request: %w", err)
+	}
+	return req, nil
+}
+
+// GetOpenByUser retrieves the user's request in progress
+func (r *AccountReactivationRepository) GetOpenByUser(ctx context.Context, userID uuid.UUID) (*entities.ReactivationRequest, error) {
+	req, err := scanReactivation(r.db.QueryRowContext(ctx, `
+		SELECT `+reactivationColumns+` FROM account_reactivations
+		WHERE user_id = $1 AND status IN ('pending_verification', 'pending_approval')`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrReactivationNotFound
+		}
+		return nil, fmt.Errorf("failed to get reactivation request: %w", err)
+	}
+	return req, nil
+}
+
+// List returns requests filtered by optional status, newest first
+func (r *AccountReactivationRepository) List(ctx context.Context, status entities.ReactivationStatus, limit, offset int) ([]*entities.ReactivationRequest, error) {
+	query := `
+		SELECT ` + reactivationColumns + `
+		FROM account_reactivations
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reactivation requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*entities.ReactivationRequest
+	for rows.Next() {
+		req, err := scanReactivation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reactivation request: %w", err)
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reactivation requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Update persists the progress and outcome of a request
+func (r *AccountReactivationRepository) Update(ctx context.Context, req *entities.ReactivationRequest) error {
+	restoredHoldings, err := marshalHoldings(req.RestoredHoldings)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE account_reactivations SET
+			status = $2, screened_country = $3, case_id = $4, restored_holdings = $5,
+			rejection_reason = $6, reviewed_by = $7, review_notes = $8,
+			completed_at = $9, updated_at = $10
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		req.ID, string(req.Status), req.ScreenedCountry, req.CaseID, restoredHoldings,
+		req.RejectionReason, req.ReviewedBy, req.ReviewNotes, req.CompletedAt, req.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update reactivation request: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrReactivationNotFound
+	}
+	return nil
+}
+
+func marshalHoldings(holdings *entities.AccountHoldings) ([]byte, error) {
+	if holdings == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(holdings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal holdings: %w", err)
+	}
+	return data, nil
+}
+
+func scanReactivation(row adminCaseScanner) (*entities.ReactivationRequest, error) {
+	req := &entities.ReactivationRequest{}
+	var status, closureReason string
+	var verificationURL, screenedCountry, rejectionReason, reviewNotes sql.NullString
+	var caseID, reviewedBy uuid.NullUUID
+	var priorHoldings, restoredHoldings []byte
+	var completedAt sql.NullTime
+
+	if err := row.Scan(
+		&req.ID,
+		&req.UserID,
+		&status,
+		&closureReason,
+		&req.RequiresApproval,
+		&verificationURL,
+		&screenedCountry,
+		&caseID,
+		&priorHoldings,
+		&restoredHoldings,
+		&rejectionReason,
+		&reviewedBy,
+		&reviewNotes,
+		&completedAt,
+		&req.CreatedAt,
+		&req.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	req.Status = entities.ReactivationStatus(status)
+	req.ClosureReason = entities.AccountClosureReason(closureReason)
+	if len(priorHoldings) > 0 {
+		req.PriorHoldings = &entities.AccountHoldings{}
+		if err := json.Unmarshal(priorHoldings, req.PriorHoldings); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal prior holdings: %w", err)
+		}
+	}
+	if len(restoredHoldings) > 0 {
+		req.RestoredHoldings = &entities.AccountHoldings{}
+		if err := json.Unmarshal(restoredHoldings, req.RestoredHoldings); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal restored holdings: %w", err)
+		}
+	}
+	if verificationURL.Valid {
+		req.VerificationURL = &verificationURL.String
+	}
+	if screenedCountry.Valid {
+		req.ScreenedCountry = &screenedCountry.String
+	}
+	if caseID.Valid {
+		req.CaseID = &caseID.UUID
+	}
+	if rejectionReason.Valid {
+		req.RejectionReason = &rejectionReason.String
+	}
+	if reviewedBy.Valid {
+		req.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewNotes.Valid {
+		req.ReviewNotes = &reviewNotes.String
+	}
+	if completedAt.Valid {
+		req.CompletedAt = &completedAt.Time
+	}
+	return req, nil
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// AccountingExportRepository reads ledger activity for the general ledger
+// export and tracks which days have been exported
+type AccountingExportRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewAccountingExportRepository creates a new accounting export repository
+func NewAccountingExportRepository(db *sql.DB, logger *zap.Logger) *AccountingExportRepository {
+	return &AccountingExportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// LedgerActivity sums the entries of completed and reversed ledger
+// transactions created in [from, to) per account type, transaction type,
+// side and currency. Reversals post their own compensating transaction, so
+// both halves are included.
+func (r *AccountingExportRepository) LedgerActivity(ctx context.Context, from, to time.Time) ([]entities.LedgerActivity, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT a.account_type, t.transaction_type, e.entry_type, e.currency, SUM(e.amount)
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		JOIN ledger_accounts a ON a.id = e.account_id
+		WHERE e.created_at >= $1 AND e.created_at < $2
+			AND t.status IN ('completed', 'reversed')
+		GROUP BY a.account_type, t.transaction_type, e.entry_type, e.currency`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum ledger activity: %w", err)
+	}
+	defer rows.Close()
+
+	var activity []entities.LedgerActivity
+	for rows.Next() {
+		var a entities.LedgerActivity
+		if err := rows.Scan(&a.AccountType, &a.TransactionType, &a.EntryType, &a.Currency, &a.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger activity: %w", err)
+		}
+		activity = append(activity, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ledger activity: %w", err)
+	}
+	return activity, nil
+}
+
+// ClaimExport locks a day's export until now+lease, creating the record on
+// first use. A day that is locked, or already exported when force is unset,
+// is not claimed.
+func (r *AccountingExportRepository) ClaimExport(ctx context.Context, date time.Time, destination string, force bool, now time.Time, lease time.Duration) (*entities.AccountingExport, error) {
+	export, err := scanAccountingExport(r.db.QueryRowContext(ctx, `
+		INSERT INTO accounting_exports (id, journal_date, destination, status, locked_until, created_at, updated_at)
+		VALUES ($1, $2::date, $3, 'exporting', $5, $4, $4)
+		ON CONFLICT (journal_date, destination) DO UPDATE SET
+			status = 'exporting', locked_until = EXCLUDED.locked_until, updated_at = EXCLUDED.updated_at
+		WHERE (accounting_exports.locked_until IS NULL OR accounting_exports.locked_until < $4)
+			AND ($6 OR accounting_exports.status NOT IN ('exported', 'empty'))
+		RETURNING `+accountingExportColumns,
+		uuid.New(), date.Format("2006-01-02"), destination, now, now.Add(lease), force))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrAccountingExportInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim accounting export: %w", err)
+	}
+	return export, nil
+}
+
+// FinishExport stores the outcome of an export and releases its lock
+func (r *AccountingExportRepository) FinishExport(ctx context.Context, export *entities.AccountingExport) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE accounting_exports SET
+			status = $2, external_ref = $3, checksum = $4, line_count = $5, total_debits = $6,
+			attempts = $7, last_error = $8, exported_at = $9, updated_at = $10, locked_until = NULL
+		WHERE id = $1`,
+		export.ID, string(export.Status), nullString(export.ExternalRef), nullString(export.Checksum),
+		export.LineCount, export.TotalDebits, export.Attempts, nullString(export.LastError),
+		export.ExportedAt, export.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to record accounting export", zap.Error(err),
+			zap.String("journal_date", export.JournalDate.Format("2006-01-02")))
+		return fmt.Errorf("failed to record accounting export: %w", err)
+	}
+	return nil
+}
+
+// ListExports returns a destination's export records for days in [from, to]
+func (r *AccountingExportRepository) ListExports(ctx context.Context, destination string, from, to time.Time) ([]*entities.AccountingExport, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+accountingExportColumns+` FROM accounting_exports
+		WHERE destination = $1 AND journal_date BETWEEN $2::date AND $3::date
+		ORDER BY journal_date`,
+		destination, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounting exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []*entities.AccountingExport{}
+	for rows.Next() {
+		export, err := scanAccountingExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan accounting export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate accounting exports: %w", err)
+	}
+	return exports, nil
+}
+
+const accountingExportColumns = `
+	id, journal_date, destination, status, external_ref, checksum, line_count, total_debits,
+	attempts, last_error, exported_at, created_at, updated_at`
+
+type accountingExportScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccountingExport(row accountingExportScanner) (*entities.AccountingExport, error) {
+	export := &entities.AccountingExport{}
+	var externalRef, checksum, lastError sql.NullString
+	var exportedAt sql.NullTime
+	if err := row.Scan(
+		&export.ID,
+		&export.JournalDate,
+		&export.Destination,
+		&export.Status,
+		&externalRef,
+		&checksum,
+		&export.LineCount,
+		&export.TotalDebits,
+		&export.Attempts,
+		&lastError,
+		&exportedAt,
+		&export.CreatedAt,
+		&export.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	export.ExternalRef = externalRef.String
+	export.Checksum = checksum.String
+	export.LastError = lastError.String
+	if exportedAt.Valid {
+		export.ExportedAt = &exportedAt.Time
+	}
+	return export, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ActiveUserRepository finds recently active users from their sessions
+type ActiveUserRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewActiveUserRepository creates a new active user repository
+func NewActiveUserRepository(db *sql.DB, logger *zap.Logger) *ActiveUserRepository {
+	return &ActiveUserRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// MostActiveUsers returns users with a live session used since the given
+// time, most recently used first
+func (r *ActiveUserRepository) MostActiveUsers(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id
+		FROM sessions
+		WHERE is_active = true
+		  AND expires_at > NOW()
+		  AND COALESCE(last_used_at, created_at) >= $1
+		GROUP BY user_id
+		ORDER BY MAX(COALESCE(last_used_at, created_at)) DESC
+		LIMIT $2`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan active user: %w", err)
+		}
+		users = append(users, id)
+	}
+	return users, rows.Err()
+}
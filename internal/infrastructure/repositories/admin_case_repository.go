@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// AdminCaseRepository persists the admin review queue
+type AdminCaseRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewAdminCaseRepository creates a new admin case repository
+func NewAdminCaseRepository(db *sql.DB, logger *zap.Logger) *AdminCaseRepository {
+	return &AdminCaseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const adminCaseColumns = `
+	id, user_id, case_type, status, summary, details, assigned_to,
+	resolution_notes, resolved_by, resolved_at, created_at, updated_at`
+
+// Open inserts a case unless the user already has an unresolved case of the
+// same type, in which case the existing case is returned with created=false.
+func (r *AdminCaseRepository) Open(ctx context.Context, c *entities.AdminCase) (*entities.AdminCase, bool, error) {
+	details, err := json.Marshal(c.Details)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal case details: %w", err)
+	}
+
+	query := `
+		INSERT INTO admin_cases (id, user_id, case_type, status, summary, details, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (user_id, case_type) WHERE status <> 'resolved' DO NOTHING
+		RETURNING ` + adminCaseColumns
+
+	created, err := scanAdminCase(r.db.QueryRowContext(ctx, query,
+		c.ID, c.UserID, string(c.CaseType), string(c.Status), c.Summary, details, c.CreatedAt))
+	if err == nil {
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to open admin case", zap.Error(err), zap.String("user_id", c.UserID.String()))
+		return nil, false, fmt.Errorf("failed to open admin case: %w", err)
+	}
+
+	existing, err := scanAdminCase(r.db.QueryRowContext(ctx, `
+		SELECT `+adminCaseColumns+` FROM admin_cases
+		WHERE user_id = $1 AND case_type = $2 AND status <> 'resolved'`,
+		c.UserID, string(c.CaseType)))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load existing admin case: %w", err)
+	}
+	return existing, false, nil
+}
+
+// GetByID retrieves a case
+func (r *AdminCaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.AdminCase, error) {
+	c, err := scanAdminCase(r.db.QueryRowContext(ctx,
+		`SELECT `+adminCaseColumns+` FROM admin_cases WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrAdminCaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get admin case: %w", err)
+	}
+	return c, nil
+}
+
+// List returns cases filtered by optional status and type, newest first
+func (r *AdminCaseRepository) List(ctx context.Context, status entities.AdminCaseStatus, caseType entities.AdminCaseType, limit, offset int) ([]*entities.AdminCase, error) {
+	query := `
+		SELECT ` + adminCaseColumns + `
+		FROM admin_cases
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR case_type = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), string(caseType), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin cases: %w", err)
+	}
+	defer rows.Close()
+
+	var cases []*entities.AdminCase
+	for rows.Next() {
+		c, err := scanAdminCase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan admin case: %w", err)
+		}
+		cases = append(cases, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate admin cases: %w", err)
+	}
+	return cases, nil
+}
+
+// Update persists status, assignment and resolution fields
+func (r *AdminCaseRepository) Update(ctx context.Context, c *entities.AdminCase) error {
+	query := `
+		UPDATE admin_cases SET
+			status = $2, assigned_to = $3, resolution_notes = $4,
+			resolved_by = $5, resolved_at = $6, updated_at = $7
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		c.ID, string(c.Status), c.AssignedTo, c.ResolutionNotes, c.ResolvedBy, c.ResolvedAt, c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update admin case: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrAdminCaseNotFound
+	}
+	return nil
+}
+
+type adminCaseScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAdminCase(row adminCaseScanner) (*entities.AdminCase, error) {
+	c := &entities.AdminCase{}
+	var caseType, status string
+	var details []byte
+	var assignedTo, resolvedBy uuid.NullUUID
+	var resolutionNotes sql.NullString
+	var resolvedAt sql.NullTime
+
+	if err := row.Scan(
+		&c.ID,
+		&c.UserID,
+		&caseType,
+		&status,
+		&c.Summary,
+		&details,
+		&assignedTo,
+		&resolutionNotes,
+		&resolvedBy,
+		&resolvedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	c.CaseType = entities.AdminCaseType(caseType)
+	c.Status = entities.AdminCaseStatus(status)
+	if len(details) > 0 {
+		if err := json.Unmarshal(details, &c.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal case details: %w", err)
+		}
+	}
+	if assignedTo.Valid {
+		c.AssignedTo = &assignedTo.UUID
+	}
+	if resolutionNotes.Valid {
+		c.ResolutionNotes = &resolutionNotes.String
+	}
+	if resolvedBy.Valid {
+		c.ResolvedBy = &resolvedBy.UUID
+	}
+	if resolvedAt.Valid {
+		c.ResolvedAt = &resolvedAt.Time
+	}
+	return c, nil
+}
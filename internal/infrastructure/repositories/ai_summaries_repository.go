@@ -377,3 +377,72 @@ func (r *AISummaryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+
+// ListByArtifactScheme returns summaries whose artifact is in the storage
+// backend with the given URI scheme, ordered by ID and starting after the
+// given ID. URIs without a scheme predate pluggable stores and are in 0G.
+func (r *AISummaryRepository) ListByArtifactScheme(ctx context.Context, scheme string, after uuid.UUID, limit int) ([]*domainrepos.AISummary, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_ai_summaries_by_artifact_scheme", trace.WithAttributes(
+		attribute.String("scheme", scheme),
+		attribute.Int("limit", limit),
+	))
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, week_start, summary_md, artifact_uri, created_at
+		FROM ai_summaries
+		WHERE id > $2
+			AND (artifact_uri LIKE $1 || '://%'
+				OR ($1 = '0g' AND artifact_uri <> '' AND artifact_uri NOT LIKE '%://%'))
+		ORDER BY id
+		LIMIT $3`, scheme, after, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list AI summaries by artifact storage: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*domainrepos.AISummary
+	for rows.Next() {
+		summary := &domainrepos.AISummary{}
+		if err := rows.Scan(
+			&summary.ID,
+			&summary.UserID,
+			&summary.WeekStart,
+			&summary.SummaryMD,
+			&summary.ArtifactURI,
+			&summary.CreatedAt,
+		); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan AI summary: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to iterate AI summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// ReplaceArtifactURI points a summary at a new copy of its artifact, as
+// long as it still references the copy that was migrated
+func (r *AISummaryRepository) ReplaceArtifactURI(ctx context.Context, id uuid.UUID, from, to string) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.replace_ai_summary_artifact_uri", trace.WithAttributes(
+		attribute.String("summary_id", id.String()),
+	))
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE ai_summaries SET artifact_uri = $3
+		WHERE id = $1 AND artifact_uri = $2`, id, from, to)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to replace AI summary artifact URI: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// AlertDeliveryRepository persists alerts sent to on-call channels
+type AlertDeliveryRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewAlertDeliveryRepository creates a new alert delivery repository
+func NewAlertDeliveryRepository(db *sql.DB, logger *zap.Logger) *AlertDeliveryRepository {
+	return &AlertDeliveryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const alertDeliveryColumns = `
+	id, source, severity, dedup_key, summary, channel, status, business_hours,
+	attempts, response_status, provider_ref, last_error, created_at, delivered_at`
+
+// Create records a delivery
+func (r *AlertDeliveryRepository) Create(ctx context.Context, d *entities.AlertDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_deliveries (`+alertDeliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		d.ID, d.Source, string(d.Severity), d.DedupKey, d.Summary, string(d.Channel), string(d.Status),
+		d.BusinessHours, d.Attempts, d.ResponseStatus, d.ProviderRef, d.LastError, d.CreatedAt, d.DeliveredAt)
+	if err != nil {
+		r.logger.Error("Failed to record alert delivery", zap.Error(err), zap.String("dedup_key", d.DedupKey))
+		return fmt.Errorf("failed to record alert delivery: %w", err)
+	}
+	return nil
+}
+
+// DeliveredSince reports whether the channel confirmed an alert with the key
+// at or after since
+func (r *AlertDeliveryRepository) DeliveredSince(ctx context.Context, dedupKey string, channel entities.AlertChannel, since time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM alert_deliveries
+			WHERE dedup_key = $1 AND channel = $2 AND status = 'delivered' AND delivered_at >= $3
+		)`, dedupKey, string(channel), since).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check recent alert deliveries: %w", err)
+	}
+	return exists, nil
+}
+
+// List returns deliveries newest first, filtered by status and channel when
+// they are not empty
+func (r *AlertDeliveryRepository) List(ctx context.Context, status entities.AlertDeliveryStatus, channel entities.AlertChannel, limit, offset int) ([]*entities.AlertDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+alertDeliveryColumns+`
+		FROM alert_deliveries
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR channel = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`, string(status), string(channel), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*entities.AlertDelivery
+	for rows.Next() {
+		d := &entities.AlertDelivery{}
+		var severity, channelName, deliveryStatus string
+		var responseStatus sql.NullInt64
+		var providerRef, lastError sql.NullString
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.Source, &severity, &d.DedupKey, &d.Summary, &channelName, &deliveryStatus,
+			&d.BusinessHours, &d.Attempts, &responseStatus, &providerRef, &lastError, &d.CreatedAt, &deliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert delivery: %w", err)
+		}
+		d.Severity = entities.AlertSeverity(severity)
+		d.Channel = entities.AlertChannel(channelName)
+		d.Status = entities.AlertDeliveryStatus(deliveryStatus)
+		if responseStatus.Valid {
+			code := int(responseStatus.Int64)
+			d.ResponseStatus = &code
+		}
+		if providerRef.Valid {
+			d.ProviderRef = &providerRef.String
+		}
+		if lastError.Valid {
+			d.LastError = &lastError.String
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate alert deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// APIUsageRepository persists per-user, per-key, per-endpoint request rollups
+type APIUsageRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewAPIUsageRepository creates a new API usage repository
+func NewAPIUsageRepository(db *sql.DB, logger *zap.Logger) *APIUsageRepository {
+	return &APIUsageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Upsert adds each rollup to the stored one for the same bucket, user, API
+// key, method and route in one transaction. Latency histograms are added element-wise.
+func (r *APIUsageRepository) Upsert(ctx context.Context, rollups []*entities.APIUsageRollup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO api_usage_rollups (
+			bucket_start, user_id, api_key_id, method, route, request_count, client_error_count,
+			server_error_count, total_duration_ms, latency_buckets, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (bucket_start, user_id, api_key_id, method, route) DO UPDATE SET
+			request_count = api_usage_rollups.request_count + EXCLUDED.request_count,
+			client_error_count = api_usage_rollups.client_error_count + EXCLUDED.client_error_count,
+			server_error_count = api_usage_rollups.server_error_count + EXCLUDED.server_error_count,
+			total_duration_ms = api_usage_rollups.total_duration_ms + EXCLUDED.total_duration_ms,
+			latency_buckets = ARRAY(
+				SELECT COALESCE(stored, 0) + COALESCE(added, 0)
+				FROM unnest(api_usage_rollups.latency_buckets, EXCLUDED.latency_buckets) AS b(stored, added)
+			),
+			updated_at = NOW()`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare api usage upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rollup := range rollups {
+		if _, err := stmt.ExecContext(ctx,
+			rollup.BucketStart, rollup.UserID, rollup.APIKeyID, rollup.Method, rollup.Route, rollup.RequestCount,
+			rollup.ClientErrorCount, rollup.ServerErrorCount, rollup.TotalDurationMs,
+			pq.Array(rollup.LatencyBuckets)); err != nil {
+			r.logger.Error("Failed to upsert API usage rollup", zap.Error(err),
+				zap.String("route", rollup.Route),
+				zap.String("user_id", rollup.UserID.String()))
+			return fmt.Errorf("failed to upsert api usage rollup: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit api usage rollups: %w", err)
+	}
+	return nil
+}
+
+// Query sums the rollups in [Since, Until) matching the filter, grouped by
+// user and endpoint or by endpoint alone, busiest first
+func (r *APIUsageRepository) Query(ctx context.Context, filter entities.APIUsageFilter) ([]*entities.APIUsageRollup, error) {
+	byUser := filter.GroupBy != entities.APIUsageByEndpoint
+	query := `
+		WITH filtered AS (
+			SELECT * FROM api_usage_rollups
+			WHERE bucket_start >= $1 AND bucket_start < $2
+				AND ($3::uuid IS NULL OR user_id = $3)
+				AND ($4 = '' OR route = $4)
+				AND ($7::uuid IS NULL OR api_key_id = $7)
+		), grouped AS (
+			SELECT CASE WHEN $5::boolean THEN user_id END AS user_id, method, route,
+				SUM(request_count)::bigint AS request_count,
+				SUM(client_error_count)::bigint AS client_error_count,
+				SUM(server_error_count)::bigint AS server_error_count,
+				SUM(total_duration_ms)::bigint AS total_duration_ms
+			FROM filtered
+			GROUP BY 1, method, route
+			ORDER BY request_count DESC
+			LIMIT $6
+		)
+		SELECT g.user_id, g.method, g.route, g.request_count, g.client_error_count,
+			g.server_error_count, g.total_duration_ms,
+			ARRAY(
+				SELECT SUM(b.n)::bigint
+				FROM filtered f, unnest(f.latency_buckets) WITH ORDINALITY AS b(n, i)
+				WHERE f.method = g.method AND f.route = g.route
+					AND (NOT $5::boolean OR f.user_id = g.user_id)
+				GROUP BY b.i
+				ORDER BY b.i
+			) AS latency_buckets
+		FROM grouped g
+		ORDER BY g.request_count DESC, g.route`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		filter.Since, filter.Until, filter.UserID, filter.Route, byUser, filter.Limit, filter.APIKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api usage: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []*entities.APIUsageRollup
+	for rows.Next() {
+		rollup := &entities.APIUsageRollup{}
+		var userID uuid.NullUUID
+		var buckets pq.Int64Array
+		if err := rows.Scan(
+			&userID,
+			&rollup.Method,
+			&rollup.Route,
+			&rollup.RequestCount,
+			&rollup.ClientErrorCount,
+			&rollup.ServerErrorCount,
+			&rollup.TotalDurationMs,
+			&buckets,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan api usage: %w", err)
+		}
+		if userID.Valid {
+			rollup.UserID = userID.UUID
+		}
+		rollup.LatencyBuckets = buckets
+		rollups = append(rollups, rollup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate api usage: %w", err)
+	}
+	return rollups, nil
+}
+
+// DeleteBefore removes rollups for buckets that started before the cutoff
+func (r *APIUsageRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_usage_rollups WHERE bucket_start < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete api usage rollups: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
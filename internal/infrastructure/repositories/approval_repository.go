@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ApprovalRepository persists maker-checker approval requests and decisions
+type ApprovalRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewApprovalRepository creates a new approval repository
+func NewApprovalRepository(db *sql.DB, logger *zap.Logger) *ApprovalRepository {
+	return &ApprovalRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const approvalRequestColumns = `
+	id, action, resource_id, requested_by, summary, payload, required_approvals,
+	status, execution_error, created_at, updated_at, resolved_at`
+
+// Create inserts a request unless one is already pending for the same action
+// and resource, in which case the pending one is returned with created=false
+func (r *ApprovalRepository) Create(ctx context.Context, request *entities.ApprovalRequest) (*entities.ApprovalRequest, bool, error) {
+	payload, err := json.Marshal(request.Payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	if request.Payload == nil {
+		payload = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO approval_requests (
+			id, action, resource_id, requested_by, summary, payload, required_approvals,
+			status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (action, resource_id) WHERE status = 'pending' DO NOTHING
+		RETURNING ` + approvalRequestColumns
+
+	created, err := scanApprovalRequest(r.db.QueryRowContext(ctx, query,
+		request.ID, string(request.Action), request.ResourceID, request.RequestedBy, request.Summary,
+		payload, request.RequiredApprovals, string(request.Status), request.CreatedAt))
+	if err == nil {
+		created.Decisions = []entities.ApprovalDecision{}
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to create approval request", zap.Error(err),
+			zap.String("action", string(request.Action)),
+			zap.String("resource_id", request.ResourceID))
+		return nil, false, fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	existing, err := scanApprovalRequest(r.db.QueryRowContext(ctx, `
+		SELECT `+approvalRequestColumns+` FROM approval_requests
+		WHERE action = $1 AND resource_id = $2 AND status = 'pending'`,
+		string(request.Action), request.ResourceID))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load pending approval request: %w", err)
+	}
+	return existing, false, r.loadDecisions(ctx, existing)
+}
+
+// GetByID retrieves a request with its decisions
+func (r *ApprovalRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.ApprovalRequest, error) {
+	request, err := scanApprovalRequest(r.db.QueryRowContext(ctx,
+		`SELECT `+approvalRequestColumns+` FROM approval_requests WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrApprovalNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval request: %w", err)
+	}
+	return request, r.loadDecisions(ctx, request)
+}
+
+// List returns requests filtered by optional status and action, oldest first
+// so the queue is worked in order. Decisions are not loaded.
+func (r *ApprovalRepository) List(ctx context.Context, status entities.ApprovalStatus, action entities.ApprovalAction, limit, offset int) ([]*entities.ApprovalRequest, error) {
+	query := `
+		SELECT ` + approvalRequestColumns + `
+		FROM approval_requests
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR action = $2)
+		ORDER BY created_at ASC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), string(action), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*entities.ApprovalRequest
+	for rows.Next() {
+		request, err := scanApprovalRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan approval request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate approval requests: %w", err)
+	}
+	return requests, nil
+}
+
+// AddDecision records a reviewer's vote, reporting false if they already voted
+func (r *ApprovalRepository) AddDecision(ctx context.Context, requestID uuid.UUID, decision entities.ApprovalDecision) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO approval_decisions (request_id, admin_id, approved, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (request_id, admin_id) DO NOTHING`,
+		requestID, decision.AdminID, decision.Approved, decision.Notes, decision.DecidedAt)
+	if err != nil {
+		r.logger.Error("Failed to record approval decision", zap.Error(err),
+			zap.String("request_id", requestID.String()),
+			zap.String("admin_id", decision.AdminID.String()))
+		return false, fmt.Errorf("failed to record approval decision: %w", err)
+	}
+	added, _ := result.RowsAffected()
+	return added > 0, nil
+}
+
+// Resolve moves a request from one status to another, reporting whether it
+// was still in status from
+func (r *ApprovalRepository) Resolve(ctx context.Context, id uuid.UUID, from, to entities.ApprovalStatus, executionError *string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE approval_requests SET
+			status = $3, execution_error = COALESCE($4, execution_error),
+			resolved_at = COALESCE(resolved_at, $5), updated_at = $5
+		WHERE id = $1 AND status = $2`,
+		id, string(from), string(to), executionError, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve approval request: %w", err)
+	}
+	resolved, _ := result.RowsAffected()
+	return resolved > 0, nil
+}
+
+func (r *ApprovalRepository) loadDecisions(ctx context.Context, request *entities.ApprovalRequest) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT admin_id, approved, notes, created_at
+		FROM approval_decisions
+		WHERE request_id = $1
+		ORDER BY created_at ASC`, request.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get approval decisions: %w", err)
+	}
+	defer rows.Close()
+
+	request.Decisions = []entities.ApprovalDecision{}
+	for rows.Next() {
+		var decision entities.ApprovalDecision
+		var notes sql.NullString
+		if err := rows.Scan(&decision.AdminID, &decision.Approved, &notes, &decision.DecidedAt); err != nil {
+			return fmt.Errorf("failed to scan approval decision: %w", err)
+		}
+		if notes.Valid {
+			decision.Notes = &notes.String
+		}
+		request.Decisions = append(request.Decisions, decision)
+	}
+	return rows.Err()
+}
+
+func scanApprovalRequest(row adminCaseScanner) (*entities.ApprovalRequest, error) {
+	request := &entities.ApprovalRequest{}
+	var action, status string
+	var payload []byte
+	var requestedBy uuid.NullUUID
+	var executionError sql.NullString
+	var resolvedAt sql.NullTime
+
+	if err := row.Scan(
+		&request.ID,
+		&action,
+		&request.ResourceID,
+		&requestedBy,
+		&request.Summary,
+		&payload,
+		&request.RequiredApprovals,
+		&status,
+		&executionError,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+		&resolvedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	request.Action = entities.ApprovalAction(action)
+	request.Status = entities.ApprovalStatus(status)
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &request.Payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+	}
+	if requestedBy.Valid {
+		request.RequestedBy = &requestedBy.UUID
+	}
+	if executionError.Valid {
+		request.ExecutionError = &executionError.String
+	}
+	if resolvedAt.Valid {
+		request.ResolvedAt = &resolvedAt.Time
+	}
+	return request, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
@@ -81,16 +82,16 @@ func (r *AuthRepository) CreateUser(ctx context.Context, req *entities.RegisterR
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			r.logger.Warn("User registration failed - email already exists", zap.String("email", req.Email))
+			r.logger.Warn("User registration failed - email already exists", logger.Email(req.Email))
 			return nil, fmt.Errorf("user with email already exists")
 		}
-		r.logger.Error("Failed to create user", zap.Error(err), zap.String("email", req.Email))
+		r.logger.Error("Failed to create user", zap.Error(err), logger.Email(req.Email))
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	r.logger.Info("User created successfully",
 		zap.String("user_id", user.ID.String()),
-		zap.String("email", user.Email))
+		logger.Email(user.Email))
 
 	return user, nil
 }
@@ -134,7 +135,7 @@ func (r *AuthRepository) GetUserByEmailForLogin(ctx context.Context, email strin
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
 		}
-		r.logger.Error("Failed to get user by email for login", zap.Error(err), zap.String("email", email))
+		r.logger.Error("Failed to get user by email for login", zap.Error(err), logger.Email(email))
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
@@ -246,7 +247,7 @@ func (r *AuthRepository) EmailExists(ctx context.Context, email string) (bool, e
 
 	err := r.db.QueryRowContext(ctx, query, email).Scan(&count)
 	if err != nil {
-		r.logger.Error("Failed to check email existence", zap.Error(err), zap.String("email", email))
+		r.logger.Error("Failed to check email existence", zap.Error(err), logger.Email(email))
 		return false, fmt.Errorf("failed to check email: %w", err)
 	}
 
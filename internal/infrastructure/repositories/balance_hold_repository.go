@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+)
+
+// BalanceHoldRepository persists holds on buying power and moves the held
+// amounts in and out of the balances table in the same transaction
+type BalanceHoldRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewBalanceHoldRepository creates a new balance hold repository
+func NewBalanceHoldRepository(db *sql.DB, logger *zap.Logger) *BalanceHoldRepository {
+	return &BalanceHoldRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const balanceHoldColumns = `
+	id, user_id, kind, reference_id, amount, status, ledger_transaction_id, created_at, settled_at`
+
+// Place inserts a hold and deducts its amount from buying power. It runs
+// serializably with the user's balance row locked first, and the deduct only
+// succeeds while buying power covers the amount, so concurrent holds, orders
+// and withdrawals cannot spend the same cash. A hold that already exists for
+// the operation is returned with created=false.
+func (r *BalanceHoldRepository) Place(ctx context.Context, hold *entities.BalanceHold) (*entities.BalanceHold, bool, error) {
+	var placed *entities.BalanceHold
+	err := database.WithSerializableTransaction(ctx, r.db, "balance_hold_place", func(tx *sql.Tx) error {
+		placed = nil
+		if err := lockBalance(ctx, tx, hold.UserID); err != nil {
+			return err
+		}
+
+		inserted, err := scanBalanceHold(tx.QueryRowContext(ctx, `
+			INSERT INTO balance_holds (id, user_id, kind, reference_id, amount, status, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (kind, reference_id) DO NOTHING
+			RETURNING `+balanceHoldColumns,
+			hold.ID, hold.UserID, string(hold.Kind), hold.ReferenceID, hold.Amount,
+			string(entities.HoldStatusActive), hold.CreatedAt))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			r.logger.Error("Failed to insert balance hold", zap.Error(err), zap.String("user_id", hold.UserID.String()))
+			return fmt.Errorf("failed to insert balance hold: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE balances SET buying_power = buying_power - $2, updated_at = $3
+			WHERE user_id = $1 AND buying_power >= $2`,
+			hold.UserID, hold.Amount, hold.CreatedAt)
+		if err != nil {
+			r.logger.Error("Failed to hold buying power", zap.Error(err), zap.String("user_id", hold.UserID.String()))
+			return fmt.Errorf("failed to hold buying power: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return entities.ErrInsufficientBuyingPower
+		}
+		placed = inserted
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if placed == nil {
+		existing, err := r.GetByReference(ctx, hold.Kind, hold.ReferenceID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load existing balance hold: %w", err)
+		}
+		return existing, false, nil
+	}
+	return placed, true, nil
+}
+
+// Settle moves an active hold to captured or released. Released amounts are
+// returned to buying power, under the same balance lock and isolation as
+// Place. A hold that had already left the active state is returned unchanged
+// with settled=false.
+func (r *BalanceHoldRepository) Settle(ctx context.Context, id uuid.UUID, status entities.HoldStatus, at time.Time) (*entities.BalanceHold, bool, error) {
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var hold *entities.BalanceHold
+	err = database.WithSerializableTransaction(ctx, r.db, "balance_hold_settle", func(tx *sql.Tx) error {
+		hold = nil
+		if err := lockBalance(ctx, tx, current.UserID); err != nil {
+			return err
+		}
+
+		settled, err := scanBalanceHold(tx.QueryRowContext(ctx, `
+			UPDATE balance_holds SET status = $2, settled_at = $3
+			WHERE id = $1 AND status = 'active'
+			RETURNING `+balanceHoldColumns,
+			id, string(status), at))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to settle balance hold: %w", err)
+		}
+
+		if status == entities.HoldStatusReleased {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE balances SET buying_power = buying_power + $2, updated_at = $3
+				WHERE user_id = $1`,
+				settled.UserID, settled.Amount, at); err != nil {
+				r.logger.Error("Failed to release held buying power", zap.Error(err),
+					zap.String("hold_id", id.String()),
+					zap.String("user_id", settled.UserID.String()))
+				return fmt.Errorf("failed to release held buying power: %w", err)
+			}
+		}
+		hold = settled
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if hold == nil {
+		existing, err := r.GetByID(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+	return hold, true, nil
+}
+
+// GetByID retrieves a hold
+func (r *BalanceHoldRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.BalanceHold, error) {
+	hold, err := scanBalanceHold(r.db.QueryRowContext(ctx,
+		`SELECT `+balanceHoldColumns+` FROM balance_holds WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrBalanceHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to get balance hold: %w", err)
+	}
+	return hold, nil
+}
+
+// GetByReference retrieves the hold placed for an order or withdrawal
+func (r *BalanceHoldRepository) GetByReference(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	hold, err := scanBalanceHold(r.db.QueryRowContext(ctx,
+		`SELECT `+balanceHoldColumns+` FROM balance_holds WHERE kind = $1 AND reference_id = $2`,
+		string(kind), referenceID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrBalanceHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to get balance hold: %w", err)
+	}
+	return hold, nil
+}
+
+// ListActive returns a user's unsettled holds, newest first
+func (r *BalanceHoldRepository) ListActive(ctx context.Context, userID uuid.UUID) ([]*entities.BalanceHold, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+balanceHoldColumns+` FROM balance_holds
+		WHERE user_id = $1 AND status = 'active'
+		ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balance holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []*entities.BalanceHold
+	for rows.Next() {
+		hold, err := scanBalanceHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan balance hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate balance holds: %w", err)
+	}
+	return holds, nil
+}
+
+// SetLedgerTransaction records the ledger transaction that mirrored placing
+// the hold
+func (r *BalanceHoldRepository) SetLedgerTransaction(ctx context.Context, id, transactionID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE balance_holds SET ledger_transaction_id = $2 WHERE id = $1`, id, transactionID); err != nil {
+		return fmt.Errorf("failed to set balance hold ledger transaction: %w", err)
+	}
+	return nil
+}
+
+func scanBalanceHold(row adminCaseScanner) (*entities.BalanceHold, error) {
+	hold := &entities.BalanceHold{}
+	var kind, status string
+	var amount decimal.Decimal
+	var ledgerTransactionID uuid.NullUUID
+	var settledAt sql.NullTime
+
+	if err := row.Scan(
+		&hold.ID,
+		&hold.UserID,
+		&kind,
+		&hold.ReferenceID,
+		&amount,
+		&status,
+		&ledgerTransactionID,
+		&hold.CreatedAt,
+		&settledAt,
+	); err != nil {
+		return nil, err
+	}
+
+	hold.Kind = entities.HoldKind(kind)
+	hold.Status = entities.HoldStatus(status)
+	hold.Amount = amount
+	if ledgerTransactionID.Valid {
+		hold.LedgerTransactionID = &ledgerTransactionID.UUID
+	}
+	if settledAt.Valid {
+		hold.SettledAt = &settledAt.Time
+	}
+	return hold, nil
+}
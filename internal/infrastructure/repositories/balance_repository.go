@@ -9,6 +9,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -74,7 +76,7 @@ func (r *BalanceRepository) UpdateBuyingPower(ctx context.Context, userID uuid.U
 		r.logger.Error("failed to update buying power",
 			zap.Error(err),
 			zap.String("user_id", userID.String()),
-			zap.String("amount", amount.String()),
+			logger.Amount("amount", amount),
 		)
 		return fmt.Errorf("failed to update buying power: %w", err)
 	}
@@ -86,7 +88,7 @@ func (r *BalanceRepository) UpdateBuyingPower(ctx context.Context, userID uuid.U
 
 	r.logger.Info("buying power updated",
 		zap.String("user_id", userID.String()),
-		zap.String("amount_added", amount.String()),
+		logger.Amount("amount_added", amount),
 		zap.Int64("rows_affected", rowsAffected),
 	)
 
@@ -110,7 +112,7 @@ func (r *BalanceRepository) UpdatePendingDeposits(ctx context.Context, userID uu
 		r.logger.Error("failed to update pending deposits",
 			zap.Error(err),
 			zap.String("user_id", userID.String()),
-			zap.String("amount", amount.String()),
+			logger.Amount("amount", amount),
 		)
 		return fmt.Errorf("failed to update pending deposits: %w", err)
 	}
@@ -122,7 +124,7 @@ func (r *BalanceRepository) UpdatePendingDeposits(ctx context.Context, userID uu
 
 	r.logger.Info("pending deposits updated",
 		zap.String("user_id", userID.String()),
-		zap.String("amount_added", amount.String()),
+		logger.Amount("amount_added", amount),
 		zap.Int64("rows_affected", rowsAffected),
 	)
 
@@ -201,7 +203,7 @@ func (r *BalanceRepository) DeductBuyingPower(ctx context.Context, userID uuid.U
 		r.logger.Error("failed to deduct buying power",
 			zap.Error(err),
 			zap.String("user_id", userID.String()),
-			zap.String("amount", amount.String()),
+			logger.Amount("amount", amount),
 		)
 		return fmt.Errorf("failed to deduct buying power: %w", err)
 	}
@@ -217,7 +219,7 @@ func (r *BalanceRepository) DeductBuyingPower(ctx context.Context, userID uuid.U
 
 	r.logger.Info("buying power deducted",
 		zap.String("user_id", userID.String()),
-		zap.String("amount", amount.String()),
+		logger.Amount("amount", amount),
 	)
 
 	return nil
@@ -228,51 +230,65 @@ func (r *BalanceRepository) AddBuyingPower(ctx context.Context, userID uuid.UUID
 	return r.UpdateBuyingPower(ctx, userID, amount)
 }
 
-// TransferFromPendingToBuyingPower atomically moves amount from pending to buying power
+// TransferFromPendingToBuyingPower atomically moves amount from pending to
+// buying power, serializably and with the balance row locked like the other
+// funding paths that move buying power
 func (r *BalanceRepository) TransferFromPendingToBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
+	err := database.WithSerializableTransaction(ctx, r.db, "balance_transfer_pending", func(tx *sql.Tx) error {
+		if err := lockBalance(ctx, tx, userID); err != nil {
+			return err
+		}
 
-	// Update balances atomically
-	query := `
-		UPDATE balances 
-		SET 
-			buying_power = buying_power + $2,
-			pending_deposits = pending_deposits - $2,
-			updated_at = $3
-		WHERE user_id = $1 AND pending_deposits >= $2
-	`
+		query := `
+			UPDATE balances
+			SET
+				buying_power = buying_power + $2,
+				pending_deposits = pending_deposits - $2,
+				updated_at = $3
+			WHERE user_id = $1 AND pending_deposits >= $2
+		`
+
+		result, err := tx.ExecContext(ctx, query, userID, amount, time.Now())
+		if err != nil {
+			r.logger.Error("failed to transfer from pending to buying power",
+				zap.Error(err),
+				zap.String("user_id", userID.String()),
+				logger.Amount("amount", amount),
+			)
+			return fmt.Errorf("failed to transfer balance: %w", err)
+		}
 
-	result, err := tx.ExecContext(ctx, query, userID, amount, time.Now())
-	if err != nil {
-		r.logger.Error("failed to transfer from pending to buying power",
-			zap.Error(err),
-			zap.String("user_id", userID.String()),
-			zap.String("amount", amount.String()),
-		)
-		return fmt.Errorf("failed to transfer balance: %w", err)
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		if rowsAffected == 0 {
+			return fmt.Errorf("insufficient pending deposits or user not found")
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("insufficient pending deposits or user not found")
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return err
 	}
 
 	r.logger.Info("transferred from pending to buying power",
 		zap.String("user_id", userID.String()),
-		zap.String("amount", amount.String()),
+		logger.Amount("amount", amount),
 	)
 
 	return nil
 }
+
+// lockBalance takes the row lock on a user's balance. Funding paths that
+// move buying power in a transaction take it before touching other rows, so
+// they queue on the balance instead of deadlocking. A user without a balance
+// row has nothing to lock.
+func lockBalance(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	var locked uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT user_id FROM balances WHERE user_id = $1 FOR UPDATE`, userID).Scan(&locked)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to lock balance: %w", err)
+	}
+	return nil
+}
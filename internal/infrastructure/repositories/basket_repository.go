@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"go.uber.org/zap"
 )
@@ -28,7 +29,7 @@ func NewBasketRepository(db *sql.DB, logger *zap.Logger) *BasketRepository {
 // GetAll retrieves all available baskets
 func (r *BasketRepository) GetAll(ctx context.Context) ([]*entities.Basket, error) {
 	query := `
-		SELECT id, name, description, risk_level, composition_json, created_at, updated_at
+		SELECT id, name, description, risk_level, composition_json, parent_basket_id, regions, created_at, updated_at
 		FROM baskets
 		ORDER BY name ASC
 	`
@@ -50,6 +51,8 @@ func (r *BasketRepository) GetAll(ctx context.Context) ([]*entities.Basket, erro
 			&basket.Description,
 			&basket.RiskLevel,
 			&compositionJSON,
+			&basket.ParentBasketID,
+			pq.Array(&basket.Regions),
 			&basket.CreatedAt,
 			&basket.UpdatedAt,
 		); err != nil {
@@ -78,7 +81,7 @@ func (r *BasketRepository) GetAll(ctx context.Context) ([]*entities.Basket, erro
 // GetByID retrieves a specific basket by ID
 func (r *BasketRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error) {
 	query := `
-		SELECT id, name, description, risk_level, composition_json, created_at, updated_at
+		SELECT id, name, description, risk_level, composition_json, parent_basket_id, regions, created_at, updated_at
 		FROM baskets
 		WHERE id = $1
 	`
@@ -91,6 +94,8 @@ func (r *BasketRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities
 		&basket.Description,
 		&basket.RiskLevel,
 		&compositionJSON,
+		&basket.ParentBasketID,
+		pq.Array(&basket.Regions),
 		&basket.CreatedAt,
 		&basket.UpdatedAt,
 	)
@@ -114,3 +119,112 @@ func (r *BasketRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities
 	r.logger.Debug("Retrieved basket", zap.String("basket_id", id.String()))
 	return basket, nil
 }
+
+// CountReferences counts the orders and open positions that use a basket
+func (r *BasketRepository) CountReferences(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM orders WHERE basket_id = $1) +
+			(SELECT COUNT(*) FROM positions WHERE basket_id = $1 AND quantity <> 0)
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&count); err != nil {
+		r.logger.Error("Failed to count basket references", zap.Error(err), zap.String("basket_id", id.String()))
+		return 0, fmt.Errorf("failed to count basket references: %w", err)
+	}
+	return count, nil
+}
+
+// Delete removes a basket, reporting false if it did not exist
+func (r *BasketRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM baskets WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to delete basket", zap.Error(err), zap.String("basket_id", id.String()))
+		return false, fmt.Errorf("failed to delete basket: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
+// Create inserts a basket
+func (r *BasketRepository) Create(ctx context.Context, basket *entities.Basket) error {
+	compositionJSON, err := json.Marshal(basket.Composition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal basket composition: %w", err)
+	}
+
+	query := `
+		INSERT INTO baskets (id, name, description, risk_level, composition_json, parent_basket_id, regions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = r.db.ExecContext(ctx, query,
+		basket.ID, basket.Name, basket.Description, basket.RiskLevel, compositionJSON,
+		basket.ParentBasketID, pq.Array(basket.Regions), basket.CreatedAt, basket.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create basket", zap.Error(err), zap.String("basket_id", basket.ID.String()))
+		return fmt.Errorf("failed to create basket: %w", err)
+	}
+	return nil
+}
+
+// UpdateRegions replaces the regions a basket is offered in, reporting false
+// if it does not exist
+func (r *BasketRepository) UpdateRegions(ctx context.Context, id uuid.UUID, regions []string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE baskets SET regions = $2, updated_at = NOW() WHERE id = $1`, id, pq.Array(regions))
+	if err != nil {
+		r.logger.Error("Failed to update basket regions", zap.Error(err), zap.String("basket_id", id.String()))
+		return false, fmt.Errorf("failed to update basket regions: %w", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+// GetFamilyStats returns order and holding activity for a basket and each of
+// its regional variants, along with the number of distinct users holding any
+// of them. Failed, canceled and expired orders are not counted.
+func (r *BasketRepository) GetFamilyStats(ctx context.Context, parentID uuid.UUID) ([]entities.BasketVariantStats, int64, error) {
+	query := `
+		SELECT b.id, b.name, b.regions,
+			(SELECT COUNT(*) FROM orders o
+				WHERE o.basket_id = b.id AND o.status NOT IN ('failed', 'canceled', 'expired')),
+			(SELECT COALESCE(SUM(o.amount), 0) FROM orders o
+				WHERE o.basket_id = b.id AND o.status NOT IN ('failed', 'canceled', 'expired')),
+			(SELECT COUNT(DISTINCT p.user_id) FROM positions p
+				WHERE p.basket_id = b.id AND p.quantity <> 0)
+		FROM baskets b
+		WHERE b.id = $1 OR b.parent_basket_id = $1
+		ORDER BY b.parent_basket_id NULLS FIRST, b.name ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, parentID)
+	if err != nil {
+		r.logger.Error("Failed to query basket family stats", zap.Error(err), zap.String("basket_id", parentID.String()))
+		return nil, 0, fmt.Errorf("failed to query basket family stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []entities.BasketVariantStats
+	for rows.Next() {
+		var s entities.BasketVariantStats
+		if err := rows.Scan(&s.BasketID, &s.Name, pq.Array(&s.Regions), &s.Orders, &s.AmountOrdered, &s.Holders); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan basket family stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating basket family stats: %w", err)
+	}
+
+	var holders int64
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT p.user_id)
+		FROM positions p
+		JOIN baskets b ON b.id = p.basket_id
+		WHERE (b.id = $1 OR b.parent_basket_id = $1) AND p.quantity <> 0
+	`, parentID).Scan(&holders)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count basket family holders: %w", err)
+	}
+	return stats, holders, nil
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// BlotterRepository reads live order events for the regulatory blotter and
+// stores the generated blotters
+type BlotterRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewBlotterRepository creates a new order blotter repository
+func NewBlotterRepository(db *sql.DB, logger *zap.Logger) *BlotterRepository {
+	return &BlotterRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// OrderEvents returns the orders received in [from, to), the orders whose
+// current status was reached in it and the admin interventions made in it.
+// A status event reports the order's status when the blotter is generated.
+func (r *BlotterRepository) OrderEvents(ctx context.Context, from, to time.Time) ([]*entities.BlotterRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT 'new:' || o.id::text, 'new', o.created_at, o.id, o.user_id, o.basket_id, o.side,
+			o.order_type, o.time_in_force, o.limit_price, o.amount, 'new', '',
+			COALESCE(o.brokerage_ref, ''), o.created_at, NULL::uuid, ''
+		FROM orders o
+		WHERE NOT o.paper AND o.created_at >= $1 AND o.created_at < $2
+		UNION ALL
+		SELECT 'status:' || o.id::text || ':' || o.status, 'status', o.updated_at, o.id, o.user_id, o.basket_id, o.side,
+			o.order_type, o.time_in_force, o.limit_price, o.amount, o.status, '',
+			COALESCE(o.brokerage_ref, ''), o.created_at, NULL::uuid, ''
+		FROM orders o
+		WHERE NOT o.paper AND o.status NOT IN ('open', 'accepted')
+			AND o.updated_at >= $1 AND o.updated_at < $2
+		UNION ALL
+		SELECT 'amend:' || i.id::text, 'amend', i.created_at, o.id, o.user_id, o.basket_id, o.side,
+			o.order_type, o.time_in_force, o.limit_price, o.amount, i.new_status, i.previous_status,
+			COALESCE(o.brokerage_ref, ''), o.created_at, i.admin_id, i.action
+		FROM order_interventions i
+		JOIN orders o ON o.id = i.order_id
+		WHERE NOT o.paper AND i.created_at >= $1 AND i.created_at < $2
+		ORDER BY 3, 1`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read order events: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*entities.BlotterRecord{}
+	for rows.Next() {
+		record := &entities.BlotterRecord{}
+		var eventType string
+		var limitPrice decimal.NullDecimal
+		var actorID uuid.NullUUID
+		if err := rows.Scan(
+			&record.EventID, &eventType, &record.EventTime, &record.OrderID, &record.UserID,
+			&record.BasketID, &record.Side, &record.OrderType, &record.TimeInForce, &limitPrice,
+			&record.Amount, &record.Status, &record.PreviousStatus, &record.BrokerageRef,
+			&record.OrderCreatedAt, &actorID, &record.Action,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+		record.EventType = entities.BlotterEventType(eventType)
+		if limitPrice.Valid {
+			record.LimitPrice = &limitPrice.Decimal
+		}
+		if actorID.Valid {
+			record.ActorID = &actorID.UUID
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate order events: %w", err)
+	}
+	return records, nil
+}
+
+// CreateBlotter stores the blotter as the next version of its day. Two
+// generators racing for the same version are told apart by the unique
+// (business_date, version) constraint.
+func (r *BlotterRepository) CreateBlotter(ctx context.Context, blotter *entities.OrderBlotter, firstOnly bool) error {
+	versionExpr := `COALESCE((SELECT MAX(version) FROM order_blotters WHERE business_date = $1::date), 0) + 1`
+	if firstOnly {
+		versionExpr = `1`
+	}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO order_blotters (business_date, version, format, record_count, checksum, content, reason, generated_by, generated_at)
+		VALUES ($1::date, `+versionExpr+`, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version`,
+		blotter.BusinessDate.Format("2006-01-02"), string(blotter.Format), blotter.RecordCount, blotter.Checksum,
+		blotter.Content, blotter.Reason, blotter.GeneratedBy, blotter.GeneratedAt,
+	).Scan(&blotter.ID, &blotter.Version)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return entities.ErrBlotterVersionConflict
+		}
+		r.logger.Error("Failed to store order blotter", zap.Error(err))
+		return fmt.Errorf("failed to store order blotter: %w", err)
+	}
+	return nil
+}
+
+const orderBlotterColumns = `
+	id, business_date, version, format, record_count, checksum, reason, generated_by, generated_at`
+
+// ListBlotters returns every version stored for days in [from, to], newest
+// version of each day first
+func (r *BlotterRepository) ListBlotters(ctx context.Context, from, to time.Time) ([]*entities.OrderBlotter, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+orderBlotterColumns+` FROM order_blotters
+		WHERE business_date BETWEEN $1::date AND $2::date
+		ORDER BY business_date, version DESC`,
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order blotters: %w", err)
+	}
+	defer rows.Close()
+
+	blotters := []*entities.OrderBlotter{}
+	for rows.Next() {
+		blotter, err := scanOrderBlotter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order blotter: %w", err)
+		}
+		blotters = append(blotters, blotter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate order blotters: %w", err)
+	}
+	return blotters, nil
+}
+
+// GetBlotter returns a stored blotter with its content
+func (r *BlotterRepository) GetBlotter(ctx context.Context, id uuid.UUID) (*entities.OrderBlotter, error) {
+	var content []byte
+	blotter, err := scanOrderBlotter(r.db.QueryRowContext(ctx, `
+		SELECT `+orderBlotterColumns+`, content FROM order_blotters WHERE id = $1`, id), &content)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrBlotterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order blotter: %w", err)
+	}
+	blotter.Content = content
+	return blotter, nil
+}
+
+type orderBlotterScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrderBlotter(row orderBlotterScanner, extra ...interface{}) (*entities.OrderBlotter, error) {
+	blotter := &entities.OrderBlotter{}
+	var format string
+	var generatedBy uuid.NullUUID
+	dest := append([]interface{}{
+		&blotter.ID,
+		&blotter.BusinessDate,
+		&blotter.Version,
+		&format,
+		&blotter.RecordCount,
+		&blotter.Checksum,
+		&blotter.Reason,
+		&generatedBy,
+		&blotter.GeneratedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	blotter.Format = entities.BlotterFormat(format)
+	if generatedBy.Valid {
+		blotter.GeneratedBy = &generatedBy.UUID
+	}
+	return blotter, nil
+}
@@ -0,0 +1,324 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// BulkOperationRepository stores admin bulk operations and their per-user
+// items
+type BulkOperationRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewBulkOperationRepository creates a new bulk operation repository
+func NewBulkOperationRepository(db *sql.DB, logger *zap.Logger) *BulkOperationRepository {
+	return &BulkOperationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create inserts the operation and a pending item per user in one transaction
+func (r *BulkOperationRepository) Create(ctx context.Context, op *entities.BulkOperation, userIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO bulk_operations (id, type, status, reason, requested_by, total, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		op.ID, string(op.Type), string(op.Status), op.Reason, op.RequestedBy, op.Total, op.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create bulk operation", zap.Error(err), zap.String("type", string(op.Type)))
+		return fmt.Errorf("failed to create bulk operation: %w", err)
+	}
+
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO bulk_operation_items (operation_id, user_id, status, next_attempt_at)
+		SELECT $1, u, 'pending', $3
+		FROM unnest($2::uuid[]) AS u
+		ON CONFLICT (operation_id, user_id) DO NOTHING`,
+		op.ID, pq.Array(ids), op.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create bulk operation items", zap.Error(err), zap.String("operation_id", op.ID.String()))
+		return fmt.Errorf("failed to create bulk operation items: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit bulk operation: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an operation by ID
+func (r *BulkOperationRepository) Get(ctx context.Context, id uuid.UUID) (*entities.BulkOperation, error) {
+	op, err := scanBulkOperation(r.db.QueryRowContext(ctx,
+		`SELECT `+bulkOperationColumns+` FROM bulk_operations WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrBulkOperationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bulk operation: %w", err)
+	}
+	return op, nil
+}
+
+// List returns operations newest first
+func (r *BulkOperationRepository) List(ctx context.Context, limit, offset int) ([]*entities.BulkOperation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+bulkOperationColumns+` FROM bulk_operations
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bulk operations: %w", err)
+	}
+	defer rows.Close()
+
+	ops := []*entities.BulkOperation{}
+	for rows.Next() {
+		op, err := scanBulkOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bulk operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bulk operations: %w", err)
+	}
+	return ops, nil
+}
+
+// ListItems returns an operation's items, optionally only those in status
+func (r *BulkOperationRepository) ListItems(ctx context.Context, operationID uuid.UUID, status entities.BulkItemStatus) ([]*entities.BulkOperationItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+bulkItemColumns+` FROM bulk_operation_items
+		WHERE operation_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY user_id`, operationID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bulk operation items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*entities.BulkOperationItem{}
+	for rows.Next() {
+		item := &entities.BulkOperationItem{}
+		if err := scanBulkItem(rows, item); err != nil {
+			return nil, fmt.Errorf("failed to scan bulk operation item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bulk operation items: %w", err)
+	}
+	return items, nil
+}
+
+// ClaimItems leases up to limit due items of running operations by pushing
+// their next_attempt_at forward, so concurrent workers do not apply the same
+// item twice. A crashed worker's lease simply expires.
+func (r *BulkOperationRepository) ClaimItems(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.ClaimedBulkItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE bulk_operation_items i SET next_attempt_at = $2
+		FROM bulk_operations o
+		WHERE o.id = i.operation_id AND i.id IN (
+			SELECT bi.id FROM bulk_operation_items bi
+			JOIN bulk_operations bo ON bo.id = bi.operation_id
+			WHERE bi.status = 'pending' AND bo.status = 'running' AND bi.next_attempt_at <= $1
+			ORDER BY bi.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF bi SKIP LOCKED
+		)
+		RETURNING i.id, i.operation_id, i.user_id, i.status, i.message, i.attempts, i.processed_at, o.type, o.reason`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim bulk operation items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*entities.ClaimedBulkItem
+	for rows.Next() {
+		item := &entities.ClaimedBulkItem{}
+		var message sql.NullString
+		var processedAt sql.NullTime
+		if err := rows.Scan(
+			&item.ID,
+			&item.OperationID,
+			&item.UserID,
+			&item.Status,
+			&message,
+			&item.Attempts,
+			&processedAt,
+			&item.Type,
+			&item.Reason,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan claimed bulk operation item: %w", err)
+		}
+		item.Message = message.String
+		if processedAt.Valid {
+			item.ProcessedAt = &processedAt.Time
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate claimed bulk operation items: %w", err)
+	}
+	return items, nil
+}
+
+// RecordItem stores an item's outcome. A still-pending item becomes due
+// again at retryAt.
+func (r *BulkOperationRepository) RecordItem(ctx context.Context, item *entities.BulkOperationItem, retryAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE bulk_operation_items
+		SET status = $2, message = $3, attempts = $4, processed_at = $5, next_attempt_at = $6
+		WHERE id = $1`,
+		item.ID, string(item.Status), nullString(item.Message), item.Attempts, item.ProcessedAt, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to record bulk operation item: %w", err)
+	}
+	return nil
+}
+
+// Refresh recounts an operation's outcomes and marks it completed once none
+// of its items is pending
+func (r *BulkOperationRepository) Refresh(ctx context.Context, id uuid.UUID, now time.Time) (*entities.BulkOperation, error) {
+	op, err := scanBulkOperation(r.db.QueryRowContext(ctx, `
+		UPDATE bulk_operations o SET
+			succeeded = c.succeeded, skipped = c.skipped, failed = c.failed,
+			status = CASE WHEN o.status = 'running' AND c.pending = 0 THEN 'completed' ELSE o.status END,
+			completed_at = CASE WHEN o.status = 'running' AND c.pending = 0 THEN $2 ELSE o.completed_at END
+		FROM (
+			SELECT
+				COUNT(*) FILTER (WHERE status = 'succeeded') AS succeeded,
+				COUNT(*) FILTER (WHERE status = 'skipped') AS skipped,
+				COUNT(*) FILTER (WHERE status = 'failed') AS failed,
+				COUNT(*) FILTER (WHERE status = 'pending') AS pending
+			FROM bulk_operation_items WHERE operation_id = $1
+		) c
+		WHERE o.id = $1
+		RETURNING `+qualifiedBulkOperationColumns, id, now))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrBulkOperationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh bulk operation: %w", err)
+	}
+	return op, nil
+}
+
+// Cancel skips the pending items of a running operation and marks it
+// cancelled
+func (r *BulkOperationRepository) Cancel(ctx context.Context, id uuid.UUID, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE bulk_operations SET status = 'cancelled', completed_at = $2
+		WHERE id = $1 AND status = 'running'`, id, now)
+	if err != nil {
+		return fmt.Errorf("failed to cancel bulk operation: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE bulk_operation_items SET status = 'skipped', message = 'cancelled', processed_at = $2
+		WHERE operation_id = $1 AND status = 'pending'`, id, now)
+	if err != nil {
+		return fmt.Errorf("failed to skip bulk operation items: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE bulk_operations o SET
+			succeeded = (SELECT COUNT(*) FROM bulk_operation_items WHERE operation_id = o.id AND status = 'succeeded'),
+			skipped = (SELECT COUNT(*) FROM bulk_operation_items WHERE operation_id = o.id AND status = 'skipped'),
+			failed = (SELECT COUNT(*) FROM bulk_operation_items WHERE operation_id = o.id AND status = 'failed')
+		WHERE o.id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to count bulk operation items: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit bulk operation cancel: %w", err)
+	}
+	return nil
+}
+
+const bulkOperationColumns = `
+	id, type, status, reason, requested_by, total, succeeded, skipped, failed, created_at, completed_at`
+
+const qualifiedBulkOperationColumns = `
+	o.id, o.type, o.status, o.reason, o.requested_by, o.total, o.succeeded, o.skipped, o.failed, o.created_at, o.completed_at`
+
+const bulkItemColumns = `
+	id, operation_id, user_id, status, message, attempts, processed_at`
+
+type bulkOperationScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBulkOperation(row bulkOperationScanner) (*entities.BulkOperation, error) {
+	op := &entities.BulkOperation{}
+	var completedAt sql.NullTime
+	if err := row.Scan(
+		&op.ID,
+		&op.Type,
+		&op.Status,
+		&op.Reason,
+		&op.RequestedBy,
+		&op.Total,
+		&op.Succeeded,
+		&op.Skipped,
+		&op.Failed,
+		&op.CreatedAt,
+		&completedAt,
+	); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		op.CompletedAt = &completedAt.Time
+	}
+	return op, nil
+}
+
+func scanBulkItem(row bulkOperationScanner, item *entities.BulkOperationItem) error {
+	var message sql.NullString
+	var processedAt sql.NullTime
+	if err := row.Scan(
+		&item.ID,
+		&item.OperationID,
+		&item.UserID,
+		&item.Status,
+		&message,
+		&item.Attempts,
+		&processedAt,
+	); err != nil {
+		return err
+	}
+	item.Message = message.String
+	if processedAt.Valid {
+		item.ProcessedAt = &processedAt.Time
+	}
+	return nil
+}
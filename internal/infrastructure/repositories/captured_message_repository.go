@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// CapturedMessageRepository stores outbound email and SMS held back by
+// sandbox capture. Rows are purged by the retention engine.
+type CapturedMessageRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewCapturedMessageRepository creates a new captured message repository
+func NewCapturedMessageRepository(db *sql.DB, logger *zap.Logger) *CapturedMessageRepository {
+	return &CapturedMessageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CaptureMessage inserts a captured message
+func (r *CapturedMessageRepository) CaptureMessage(ctx context.Context, message *entities.CapturedMessage) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO captured_messages (id, channel, recipient, subject, text_body, html_body, environment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		message.ID, string(message.Channel), message.Recipient, nullString(message.Subject),
+		nullString(message.TextBody), nullString(message.HTMLBody), nullString(message.Environment), message.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to capture message", zap.Error(err),
+			zap.String("channel", string(message.Channel)))
+		return fmt.Errorf("failed to capture message: %w", err)
+	}
+	return nil
+}
+
+// ListMessages returns captured messages matching the filter, newest first
+func (r *CapturedMessageRepository) ListMessages(ctx context.Context, filter entities.CapturedMessageFilter) ([]*entities.CapturedMessage, error) {
+	query := `
+		SELECT id, channel, recipient, subject, text_body, html_body, environment, created_at
+		FROM captured_messages
+		WHERE ($1 = '' OR channel = $1)
+			AND ($2 = '' OR recipient = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, string(filter.Channel), filter.Recipient, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list captured messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*entities.CapturedMessage
+	for rows.Next() {
+		message := &entities.CapturedMessage{}
+		var subject, textBody, htmlBody, environment sql.NullString
+		if err := rows.Scan(
+			&message.ID,
+			&message.Channel,
+			&message.Recipient,
+			&subject,
+			&textBody,
+			&htmlBody,
+			&environment,
+			&message.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan captured message: %w", err)
+		}
+		message.Subject = subject.String
+		message.TextBody = textBody.String
+		message.HTMLBody = htmlBody.String
+		message.Environment = environment.String
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate captured messages: %w", err)
+	}
+	return messages, nil
+}
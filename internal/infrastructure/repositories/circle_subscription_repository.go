@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// CircleSubscriptionRepository records the Circle notification subscriptions
+// this service registered
+type CircleSubscriptionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewCircleSubscriptionRepository creates a new Circle subscription repository
+func NewCircleSubscriptionRepository(db *sql.DB, logger *zap.Logger) *CircleSubscriptionRepository {
+	return &CircleSubscriptionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// List returns every recorded registration, oldest first
+func (r *CircleSubscriptionRepository) List(ctx context.Context) ([]*entities.CircleSubscriptionRegistration, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subscription_id, endpoint, created_at, verified_at
+		FROM circle_notification_subscriptions
+		ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list circle subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var registrations []*entities.CircleSubscriptionRegistration
+	for rows.Next() {
+		reg := &entities.CircleSubscriptionRegistration{}
+		var verifiedAt sql.NullTime
+		if err := rows.Scan(&reg.SubscriptionID, &reg.Endpoint, &reg.CreatedAt, &verifiedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan circle subscription: %w", err)
+		}
+		if verifiedAt.Valid {
+			reg.VerifiedAt = &verifiedAt.Time
+		}
+		registrations = append(registrations, reg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate circle subscriptions: %w", err)
+	}
+	return registrations, nil
+}
+
+// Save records a registration, or marks an existing one verified
+func (r *CircleSubscriptionRepository) Save(ctx context.Context, reg *entities.CircleSubscriptionRegistration) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO circle_notification_subscriptions (subscription_id, endpoint, created_at, verified_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subscription_id) DO UPDATE SET
+			endpoint = EXCLUDED.endpoint,
+			verified_at = EXCLUDED.verified_at`,
+		reg.SubscriptionID, reg.Endpoint, reg.CreatedAt, reg.VerifiedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save circle subscription: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a registration
+func (r *CircleSubscriptionRepository) Delete(ctx context.Context, subscriptionID string) error {
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM circle_notification_subscriptions WHERE subscription_id = $1`, subscriptionID); err != nil {
+		return fmt.Errorf("failed to delete circle subscription: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ConsentRepository persists agreement versions and user consents
+type ConsentRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewConsentRepository creates a new consent repository
+func NewConsentRepository(db *sql.DB, logger *zap.Logger) *ConsentRepository {
+	return &ConsentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListVersions returns every published agreement version, oldest first
+func (r *ConsentRepository) ListVersions(ctx context.Context) ([]*entities.AgreementVersion, error) {
+	query := `
+		SELECT id, agreement_type, version, title, document_url, mandatory, published_by, published_at
+		FROM agreement_versions
+		ORDER BY published_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agreement versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*entities.AgreementVersion
+	for rows.Next() {
+		version := &entities.AgreementVersion{}
+		var publishedBy uuid.NullUUID
+		if err := rows.Scan(
+			&version.ID,
+			&version.AgreementType,
+			&version.Version,
+			&version.Title,
+			&version.DocumentURL,
+			&version.Mandatory,
+			&publishedBy,
+			&version.PublishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan agreement version: %w", err)
+		}
+		if publishedBy.Valid {
+			version.PublishedBy = &publishedBy.UUID
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate agreement versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// CreateVersion stores a newly published agreement version
+func (r *ConsentRepository) CreateVersion(ctx context.Context, version *entities.AgreementVersion) error {
+	query := `
+		INSERT INTO agreement_versions (
+			id, agreement_type, version, title, document_url, mandatory, published_by, published_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		version.ID, version.AgreementType, version.Version, version.Title, version.DocumentURL,
+		version.Mandatory, version.PublishedBy, version.PublishedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrAgreementVersionExists
+		}
+		r.logger.Error("Failed to create agreement version", zap.Error(err),
+			zap.String("agreement_type", string(version.AgreementType)),
+			zap.String("version", version.Version))
+		return fmt.Errorf("failed to create agreement version: %w", err)
+	}
+	return nil
+}
+
+// LatestConsents returns the user's most recent acceptance of each agreement type
+func (r *ConsentRepository) LatestConsents(ctx context.Context, userID uuid.UUID) ([]*entities.UserConsent, error) {
+	query := `
+		SELECT DISTINCT ON (agreement_type)
+			id, user_id, agreement_version_id, agreement_type, version, ip_address, user_agent, accepted_at
+		FROM user_consents
+		WHERE user_id = $1
+		ORDER BY agreement_type, accepted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user consents: %w", err)
+	}
+	defer rows.Close()
+
+	var consents []*entities.UserConsent
+	for rows.Next() {
+		consent := &entities.UserConsent{}
+		var ipAddress, userAgent sql.NullString
+		if err := rows.Scan(
+			&consent.ID,
+			&consent.UserID,
+			&consent.AgreementVersionID,
+			&consent.AgreementType,
+			&consent.Version,
+			&ipAddress,
+			&userAgent,
+			&consent.AcceptedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user consent: %w", err)
+		}
+		if ipAddress.Valid {
+			consent.IPAddress = &ipAddress.String
+		}
+		if userAgent.Valid {
+			consent.UserAgent = &userAgent.String
+		}
+		consents = append(consents, consent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate user consents: %w", err)
+	}
+
+	return consents, nil
+}
+
+// RecordConsent stores a user's acceptance. Accepting the same version twice
+// keeps the original record.
+func (r *ConsentRepository) RecordConsent(ctx context.Context, consent *entities.UserConsent) error {
+	query := `
+		INSERT INTO user_consents (
+			id, user_id, agreement_version_id, agreement_type, version, ip_address, user_agent, accepted_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, agreement_version_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query,
+		consent.ID, consent.UserID, consent.AgreementVersionID, consent.AgreementType, consent.Version,
+		consent.IPAddress, consent.UserAgent, consent.AcceptedAt)
+	if err != nil {
+		r.logger.Error("Failed to record user consent", zap.Error(err),
+			zap.String("user_id", consent.UserID.String()),
+			zap.String("agreement_version_id", consent.AgreementVersionID.String()))
+		return fmt.Errorf("failed to record user consent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// CostBasisRepository stores cost basis imports and the tax lots they record
+type CostBasisRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewCostBasisRepository creates a new cost basis repository
+func NewCostBasisRepository(db *sql.DB, logger *zap.Logger) *CostBasisRepository {
+	return &CostBasisRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateImport stores an import awaiting review
+func (r *CostBasisRepository) CreateImport(ctx context.Context, imp *entities.CostBasisImport) error {
+	lots, err := json.Marshal(imp.Lots)
+	if err != nil {
+		return fmt.Errorf("failed to marshal imported lots: %w", err)
+	}
+	rowErrors, err := json.Marshal(imp.Errors)
+	if err != nil {
+		return fmt.Errorf("failed to marshal import errors: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO cost_basis_imports (id, user_id, filename, status, lots, errors, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		imp.ID, imp.UserID, imp.Filename, imp.Status, lots, rowErrors, imp.ExpiresAt, imp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cost basis import: %w", err)
+	}
+	return nil
+}
+
+// GetImport returns one of the user's imports
+func (r *CostBasisRepository) GetImport(ctx context.Context, userID, id uuid.UUID) (*entities.CostBasisImport, error) {
+	imp := entities.CostBasisImport{UserID: userID}
+	var lots, rowErrors []byte
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, filename, status, lots, errors, expires_at, confirmed_at, created_at
+		FROM cost_basis_imports
+		WHERE id = $1 AND user_id = $2`, id, userID).Scan(
+		&imp.ID, &imp.Filename, &imp.Status, &lots, &rowErrors, &imp.ExpiresAt, &confirmedAt, &imp.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrCostBasisImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get cost basis import: %w", err)
+	}
+	if err := json.Unmarshal(lots, &imp.Lots); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal imported lots: %w", err)
+	}
+	if err := json.Unmarshal(rowErrors, &imp.Errors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal import errors: %w", err)
+	}
+	if confirmedAt.Valid {
+		imp.ConfirmedAt = &confirmedAt.Time
+	}
+	return &imp, nil
+}
+
+// ConfirmImport marks the import confirmed and records its lots together
+func (r *CostBasisRepository) ConfirmImport(ctx context.Context, imp *entities.CostBasisImport, lots []*entities.TaxLot, at time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE cost_basis_imports SET status = 'confirmed', confirmed_at = $3
+		WHERE id = $1 AND user_id = $2 AND status = 'pending_review' AND expires_at > $3`,
+		imp.ID, imp.UserID, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm cost basis import: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	for _, lot := range lots {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO tax_lots (id, user_id, symbol, quantity, cost_basis, acquired_at, source, import_id, adjusted, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			lot.ID, lot.UserID, lot.Symbol, lot.Quantity, lot.CostBasis, lot.AcquiredAt, lot.Source,
+			lot.ImportID, lot.Adjusted, lot.CreatedAt, lot.UpdatedAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to record tax lot: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit cost basis import: %w", err)
+	}
+	return true, nil
+}
+
+// CancelImport discards an import that is still awaiting review
+func (r *CostBasisRepository) CancelImport(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE cost_basis_imports SET status = 'cancelled'
+		WHERE id = $1 AND user_id = $2 AND status = 'pending_review' AND expires_at > NOW()`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel cost basis import: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+const taxLotColumns = `id, user_id, symbol, quantity, cost_basis, acquired_at, source, import_id, adjusted, created_at, updated_at`
+
+// ListLots returns the user's tax lots by symbol, oldest first
+func (r *CostBasisRepository) ListLots(ctx context.Context, userID uuid.UUID) ([]*entities.TaxLot, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+taxLotColumns+` FROM tax_lots
+		WHERE user_id = $1
+		ORDER BY symbol, acquired_at, created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tax lots: %w", err)
+	}
+	defer rows.Close()
+
+	var lots []*entities.TaxLot
+	for rows.Next() {
+		lot, err := scanTaxLot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tax lot: %w", err)
+		}
+		lots = append(lots, lot)
+	}
+	return lots, rows.Err()
+}
+
+// GetLot returns a tax lot
+func (r *CostBasisRepository) GetLot(ctx context.Context, id uuid.UUID) (*entities.TaxLot, error) {
+	lot, err := scanTaxLot(r.db.QueryRowContext(ctx, `
+		SELECT `+taxLotColumns+` FROM tax_lots WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrTaxLotNotFound
+		}
+		return nil, fmt.Errorf("failed to get tax lot: %w", err)
+	}
+	return lot, nil
+}
+
+// AdjustLot updates the lot and records the adjustment in one transaction
+func (r *CostBasisRepository) AdjustLot(ctx context.Context, lot *entities.TaxLot, adjustment *entities.TaxLotAdjustment) error {
+	before, err := json.Marshal(adjustment.Before)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tax lot: %w", err)
+	}
+	after, err := json.Marshal(adjustment.After)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tax lot: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tax_lots SET quantity = $2, cost_basis = $3, acquired_at = $4, adjusted = TRUE, updated_at = $5
+		WHERE id = $1`,
+		lot.ID, lot.Quantity, lot.CostBasis, lot.AcquiredAt, lot.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to adjust tax lot: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return entities.ErrTaxLotNotFound
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tax_lot_adjustments (id, lot_id, admin_id, before, after, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		adjustment.ID, adjustment.LotID, adjustment.AdminID, before, after, adjustment.Reason, adjustment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record tax lot adjustment: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListAdjustments returns a lot's adjustments, oldest first
+func (r *CostBasisRepository) ListAdjustments(ctx context.Context, lotID uuid.UUID) ([]*entities.TaxLotAdjustment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, lot_id, admin_id, before, after, reason, created_at
+		FROM tax_lot_adjustments
+		WHERE lot_id = $1
+		ORDER BY created_at`, lotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tax lot adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*entities.TaxLotAdjustment
+	for rows.Next() {
+		var adjustment entities.TaxLotAdjustment
+		var before, after []byte
+		if err := rows.Scan(&adjustment.ID, &adjustment.LotID, &adjustment.AdminID, &before, &after,
+			&adjustment.Reason, &adjustment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tax lot adjustment: %w", err)
+		}
+		if err := json.Unmarshal(before, &adjustment.Before); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tax lot: %w", err)
+		}
+		if err := json.Unmarshal(after, &adjustment.After); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tax lot: %w", err)
+		}
+		adjustments = append(adjustments, &adjustment)
+	}
+	return adjustments, rows.Err()
+}
+
+type taxLotScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTaxLot(row taxLotScanner) (*entities.TaxLot, error) {
+	var lot entities.TaxLot
+	var importID uuid.NullUUID
+	if err := row.Scan(&lot.ID, &lot.UserID, &lot.Symbol, &lot.Quantity, &lot.CostBasis, &lot.AcquiredAt,
+		&lot.Source, &importID, &lot.Adjusted, &lot.CreatedAt, &lot.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if importID.Valid {
+		lot.ImportID = &importID.UUID
+	}
+	return &lot, nil
+}
@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// CustodialAccountRepository persists guardian-held accounts for minors
+type CustodialAccountRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewCustodialAccountRepository creates a new custodial account repository
+func NewCustodialAccountRepository(db *sql.DB, logger *zap.Logger) *CustodialAccountRepository {
+	return &CustodialAccountRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const custodialAccountColumns = `
+	id, guardian_user_id, beneficiary_first_name, beneficiary_last_name,
+	beneficiary_date_of_birth, relationship, status, majority_date,
+	transfer_email, transfer_initiated_at, beneficiary_user_id, transferred_at,
+	created_at, updated_at`
+
+// Create inserts a new custodial account
+func (r *CustodialAccountRepository) Create(ctx context.Context, account *entities.CustodialAccount) error {
+	query := `
+		INSERT INTO custodial_accounts (
+			id, guardian_user_id, beneficiary_first_name, beneficiary_last_name,
+			beneficiary_date_of_birth, relationship, status, majority_date,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		account.ID,
+		account.GuardianUserID,
+		account.BeneficiaryFirstName,
+		account.BeneficiaryLastName,
+		account.BeneficiaryDOB,
+		account.Relationship,
+		string(account.Status),
+		account.MajorityDate,
+		account.CreatedAt,
+		account.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to create custodial account", zap.Error(err), zap.String("guardian_user_id", account.GuardianUserID.String()))
+		return fmt.Errorf("failed to create custodial account: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a custodial account
+func (r *CustodialAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.CustodialAccount, error) {
+	query := `SELECT ` + custodialAccountColumns + ` FROM custodial_accounts WHERE id = $1`
+
+	account, err := scanCustodialAccount(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrCustodialNotFound
+		}
+		return nil, fmt.Errorf("failed to get custodial account: %w", err)
+	}
+	return account, nil
+}
+
+// ListByGuardian returns every custodial account a guardian holds
+func (r *CustodialAccountRepository) ListByGuardian(ctx context.Context, guardianUserID uuid.UUID) ([]*entities.CustodialAccount, error) {
+	query := `SELECT ` + custodialAccountColumns + `
+		FROM custodial_accounts
+		WHERE guardian_user_id = $1
+		ORDER BY created_at DESC`
+
+	return r.list(ctx, query, guardianUserID)
+}
+
+// ListPendingTransferByEmail returns accounts awaiting a claim by the given email
+func (r *CustodialAccountRepository) ListPendingTransferByEmail(ctx context.Context, email string) ([]*entities.CustodialAccount, error) {
+	query := `SELECT ` + custodialAccountColumns + `
+		FROM custodial_accounts
+		WHERE status = 'transfer_pending' AND lower(transfer_email) = lower($1)
+		ORDER BY transfer_initiated_at`
+
+	return r.list(ctx, query, email)
+}
+
+// MarkTransferPending records that the guardian has started transfer of ownership
+func (r *CustodialAccountRepository) MarkTransferPending(ctx context.Context, id uuid.UUID, email string, initiatedAt time.Time) error {
+	query := `
+		UPDATE custodial_accounts
+		SET status = 'transfer_pending', transfer_email = $2, transfer_initiated_at = $3, updated_at = $3
+		WHERE id = $1 AND status IN ('active', 'transfer_pending')`
+
+	return r.expectOne(ctx, "mark custodial transfer pending", query, id, email, initiatedAt)
+}
+
+// MarkTransferred hands the account to the beneficiary's own user
+func (r *CustodialAccountRepository) MarkTransferred(ctx context.Context, id, beneficiaryUserID uuid.UUID, transferredAt time.Time) error {
+	query := `
+		UPDATE custodial_accounts
+		SET status = 'transferred', beneficiary_user_id = $2, transferred_at = $3, updated_at = $3
+		WHERE id = $1 AND status = 'transfer_pending'`
+
+	return r.expectOne(ctx, "complete custodial transfer", query, id, beneficiaryUserID, transferredAt)
+}
+
+func (r *CustodialAccountRepository) expectOne(ctx context.Context, action, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("failed to %s: account is not in a transferable state", action)
+	}
+	return nil
+}
+
+func (r *CustodialAccountRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entities.CustodialAccount, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custodial accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*entities.CustodialAccount
+	for rows.Next() {
+		account, err := scanCustodialAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custodial account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate custodial accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+type custodialScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCustodialAccount(row custodialScanner) (*entities.CustodialAccount, error) {
+	account := &entities.CustodialAccount{}
+	var status string
+	var transferEmail sql.NullString
+	var transferInitiatedAt, transferredAt sql.NullTime
+	var beneficiaryUserID uuid.NullUUID
+
+	if err := row.Scan(
+		&account.ID,
+		&account.GuardianUserID,
+		&account.BeneficiaryFirstName,
+		&account.BeneficiaryLastName,
+		&account.BeneficiaryDOB,
+		&account.Relationship,
+		&status,
+		&account.MajorityDate,
+		&transferEmail,
+		&transferInitiatedAt,
+		&beneficiaryUserID,
+		&transferredAt,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	account.Status = entities.CustodialAccountStatus(status)
+	if transferEmail.Valid {
+		account.TransferEmail = &transferEmail.String
+	}
+	if transferInitiatedAt.Valid {
+		account.TransferInitiatedAt = &transferInitiatedAt.Time
+	}
+	if beneficiaryUserID.Valid {
+		account.BeneficiaryUserID = &beneficiaryUserID.UUID
+	}
+	if transferredAt.Valid {
+		account.TransferredAt = &transferredAt.Time
+	}
+	return account, nil
+}
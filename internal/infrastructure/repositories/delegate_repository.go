@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// DelegateRepository persists delegate access grants, sessions and the log
+// of what delegates viewed
+type DelegateRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewDelegateRepository creates a new delegate repository
+func NewDelegateRepository(db *sql.DB, logger *zap.Logger) *DelegateRepository {
+	return &DelegateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const delegateGrantColumns = `id, user_id, delegate_name, delegate_email, relationship, scopes, token_hash,
+	token_prefix, status, expires_at, last_used_at, revoked_at, created_at`
+
+// CreateGrant records a new grant
+func (r *DelegateRepository) CreateGrant(ctx context.Context, grant *entities.DelegateGrant) error {
+	scopes := make([]string, len(grant.Scopes))
+	for i, scope := range grant.Scopes {
+		scopes[i] = string(scope)
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO delegate_grants (`+delegateGrantColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		grant.ID, grant.UserID, grant.DelegateName, grant.DelegateEmail, grant.Relationship,
+		pq.Array(scopes), grant.TokenHash, grant.TokenPrefix, grant.Status, grant.ExpiresAt,
+		grant.LastUsedAt, grant.RevokedAt, grant.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create delegate grant: %w", err)
+	}
+	return nil
+}
+
+// GetGrant returns one of the user's grants
+func (r *DelegateRepository) GetGrant(ctx context.Context, userID, id uuid.UUID) (*entities.DelegateGrant, error) {
+	grant, err := scanDelegateGrant(r.db.QueryRowContext(ctx, `
+		SELECT `+delegateGrantColumns+` FROM delegate_grants WHERE id = $1 AND user_id = $2`, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrDelegateGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to get delegate grant: %w", err)
+	}
+	return grant, nil
+}
+
+// GetGrantByTokenHash returns the grant issued with the token
+func (r *DelegateRepository) GetGrantByTokenHash(ctx context.Context, tokenHash string) (*entities.DelegateGrant, error) {
+	grant, err := scanDelegateGrant(r.db.QueryRowContext(ctx, `
+		SELECT `+delegateGrantColumns+` FROM delegate_grants WHERE token_hash = $1`, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrDelegateGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to get delegate grant: %w", err)
+	}
+	return grant, nil
+}
+
+// ListGrants returns the user's grants, newest first
+func (r *DelegateRepository) ListGrants(ctx context.Context, userID uuid.UUID) ([]*entities.DelegateGrant, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+delegateGrantColumns+` FROM delegate_grants
+		WHERE user_id = $1
+		ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegate grants: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []*entities.DelegateGrant{}
+	for rows.Next() {
+		grant, err := scanDelegateGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delegate grant: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+// CountActiveGrants counts the user's grants that are neither revoked nor expired
+func (r *DelegateRepository) CountActiveGrants(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM delegate_grants
+		WHERE user_id = $1 AND status = 'active' AND expires_at > $2`, userID, now).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count delegate grants: %w", err)
+	}
+	return count, nil
+}
+
+// RevokeGrant marks an active grant revoked and deletes its sessions
+func (r *DelegateRepository) RevokeGrant(ctx context.Context, userID, id uuid.UUID, at time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE delegate_grants SET status = 'revoked', revoked_at = $3
+		WHERE id = $1 AND user_id = $2 AND status = 'active'`, id, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke delegate grant: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return entities.ErrDelegateGrantNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM delegate_sessions WHERE grant_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to end delegate sessions: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CreateSession records a delegate sign-in
+func (r *DelegateRepository) CreateSession(ctx context.Context, session *entities.DelegateSession) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO delegate_sessions (id, grant_id, token_hash, ip_address, user_agent, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		session.ID, session.GrantID, session.TokenHash, session.IPAddress, session.UserAgent,
+		session.ExpiresAt, session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create delegate session: %w", err)
+	}
+	return nil
+}
+
+// GetSessionGrant returns the active grant of an unexpired session
+func (r *DelegateRepository) GetSessionGrant(ctx context.Context, tokenHash string, now time.Time) (*entities.DelegateGrant, error) {
+	grant, err := scanDelegateGrant(r.db.QueryRowContext(ctx, `
+		SELECT g.id, g.user_id, g.delegate_name, g.delegate_email, g.relationship, g.scopes, g.token_hash,
+			g.token_prefix, g.status, g.expires_at, g.last_used_at, g.revoked_at, g.created_at
+		FROM delegate_sessions s
+		JOIN delegate_grants g ON g.id = s.grant_id
+		WHERE s.token_hash = $1 AND s.expires_at > $2 AND g.status = 'active' AND g.expires_at > $2`,
+		tokenHash, now))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrDelegateSessionInvalid
+		}
+		return nil, fmt.Errorf("failed to get delegate session: %w", err)
+	}
+	return grant, nil
+}
+
+// TouchGrant records when the grant was last used to sign in
+func (r *DelegateRepository) TouchGrant(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE delegate_grants SET last_used_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to update delegate grant: %w", err)
+	}
+	return nil
+}
+
+// RecordAccess appends to the delegate access log
+func (r *DelegateRepository) RecordAccess(ctx context.Context, event *entities.DelegateAccessEvent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO delegate_access_log (id, grant_id, user_id, scope, resource, resource_id, ip_address, user_agent, viewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		event.ID, event.GrantID, event.UserID, event.Scope, event.Resource, event.ResourceID,
+		event.IPAddress, event.UserAgent, event.ViewedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record delegate access: %w", err)
+	}
+	return nil
+}
+
+// ListAccess returns what a grant's delegate viewed, newest first
+func (r *DelegateRepository) ListAccess(ctx context.Context, userID, grantID uuid.UUID, limit int) ([]*entities.DelegateAccessEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, grant_id, user_id, scope, resource, resource_id, ip_address, user_agent, viewed_at
+		FROM delegate_access_log
+		WHERE user_id = $1 AND grant_id = $2
+		ORDER BY viewed_at DESC
+		LIMIT $3`, userID, grantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegate access: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*entities.DelegateAccessEvent{}
+	for rows.Next() {
+		event := &entities.DelegateAccessEvent{}
+		var ipAddress, userAgent sql.NullString
+		if err := rows.Scan(&event.ID, &event.GrantID, &event.UserID, &event.Scope, &event.Resource,
+			&event.ResourceID, &ipAddress, &userAgent, &event.ViewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan delegate access: %w", err)
+		}
+		event.IPAddress = ipAddress.String
+		event.UserAgent = userAgent.String
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+type delegateGrantScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDelegateGrant(row delegateGrantScanner) (*entities.DelegateGrant, error) {
+	var grant entities.DelegateGrant
+	var scopes []string
+	if err := row.Scan(&grant.ID, &grant.UserID, &grant.DelegateName, &grant.DelegateEmail, &grant.Relationship,
+		pq.Array(&scopes), &grant.TokenHash, &grant.TokenPrefix, &grant.Status, &grant.ExpiresAt,
+		&grant.LastUsedAt, &grant.RevokedAt, &grant.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		grant.Scopes = append(grant.Scopes, entities.DelegateScope(scope))
+	}
+	return &grant, nil
+}
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// DepositReferenceRepository persists users' deposit reference codes and the
+// credits received into shared accounts
+type DepositReferenceRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewDepositReferenceRepository creates a new deposit reference repository
+func NewDepositReferenceRepository(db *sql.DB, logger *zap.Logger) *DepositReferenceRepository {
+	return &DepositReferenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateReference inserts a reference code. It returns false, without an
+// error, when the user already has a code or the code is taken.
+func (r *DepositReferenceRepository) CreateReference(ctx context.Context, ref *entities.DepositReference) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO deposit_references (id, user_id, code, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`,
+		ref.ID, ref.UserID, ref.Code, ref.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create deposit reference: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// GetReferenceByUser returns a user's reference code, or nil if none was issued
+func (r *DepositReferenceRepository) GetReferenceByUser(ctx context.Context, userID uuid.UUID) (*entities.DepositReference, error) {
+	return r.getReference(ctx, "user_id", userID)
+}
+
+// GetReferenceByCode returns the reference with a code, or nil if it was never issued
+func (r *DepositReferenceRepository) GetReferenceByCode(ctx context.Context, code string) (*entities.DepositReference, error) {
+	return r.getReference(ctx, "code", code)
+}
+
+func (r *DepositReferenceRepository) getReference(ctx context.Context, column string, value interface{}) (*entities.DepositReference, error) {
+	var ref entities.DepositReference
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, code, created_at
+		FROM deposit_references
+		WHERE `+column+` = $1`, value).Scan(&ref.ID, &ref.UserID, &ref.Code, &ref.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deposit reference: %w", err)
+	}
+	return &ref, nil
+}
+
+const bankCreditColumns = `
+	id, provider_ref, account_number, currency, amount, memo, sender_name, status,
+	user_id, reference, match_method, unmatched_reason, matched_by, matched_at,
+	note, ledger_transaction_id, received_at, created_at, updated_at`
+
+// CreateCredit records a credit unless one with the same provider reference
+// exists, in which case the existing credit is returned with created=false
+func (r *DepositReferenceRepository) CreateCredit(ctx context.Context, credit *entities.BankCredit) (*entities.BankCredit, bool, error) {
+	created, err := scanBankCredit(r.db.QueryRowContext(ctx, `
+		INSERT INTO bank_credits (id, provider_ref, account_number, currency, amount, memo, sender_name,
+			status, reference, unmatched_reason, received_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+		ON CONFLICT (provider_ref) DO NOTHING
+		RETURNING `+bankCreditColumns,
+		credit.ID, credit.ProviderRef, credit.AccountNumber, credit.Currency, credit.Amount, credit.Memo,
+		credit.SenderName, string(credit.Status), credit.Reference, credit.UnmatchedReason,
+		credit.ReceivedAt, credit.CreatedAt))
+	if err == nil {
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to create bank credit", zap.Error(err), zap.String("provider_ref", credit.ProviderRef))
+		return nil, false, fmt.Errorf("failed to create bank credit: %w", err)
+	}
+
+	existing, err := scanBankCredit(r.db.QueryRowContext(ctx,
+		`SELECT `+bankCreditColumns+` FROM bank_credits WHERE provider_ref = $1`, credit.ProviderRef))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load existing bank credit: %w", err)
+	}
+	return existing, false, nil
+}
+
+// GetCredit retrieves a credit
+func (r *DepositReferenceRepository) GetCredit(ctx context.Context, id uuid.UUID) (*entities.BankCredit, error) {
+	credit, err := scanBankCredit(r.db.QueryRowContext(ctx,
+		`SELECT `+bankCreditColumns+` FROM bank_credits WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrBankCreditNotFound
+		}
+		return nil, fmt.Errorf("failed to get bank credit: %w", err)
+	}
+	return credit, nil
+}
+
+// ListReferencesByCodes returns the references with any of the given codes
+func (r *DepositReferenceRepository) ListReferencesByCodes(ctx context.Context, codes []string) ([]*entities.DepositReference, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, code, created_at
+		FROM deposit_references
+		WHERE code = ANY($1)`, pq.Array(codes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deposit references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []*entities.DepositReference
+	for rows.Next() {
+		var ref entities.DepositReference
+		if err := rows.Scan(&ref.ID, &ref.UserID, &ref.Code, &ref.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deposit reference: %w", err)
+		}
+		refs = append(refs, &ref)
+	}
+	return refs, rows.Err()
+}
+
+// SuggestUsersBySenderName returns users whose first and last names both
+// appear in a bank sender name
+func (r *DepositReferenceRepository) SuggestUsersBySenderName(ctx context.Context, senderName string, limit int) ([]entities.SuspenseSuggestion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, first_name || ' ' || last_name
+		FROM users
+		WHERE COALESCE(first_name, '') <> '' AND COALESCE(last_name, '') <> ''
+			AND $1 ILIKE '%' || first_name || '%'
+			AND $1 ILIKE '%' || last_name || '%'
+		ORDER BY created_at ASC
+		LIMIT $2`, senderName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match sender name to users: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []entities.SuspenseSuggestion
+	for rows.Next() {
+		var userID uuid.UUID
+		var name string
+		if err := rows.Scan(&userID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		suggestions = append(suggestions, entities.SuspenseSuggestion{
+			UserID: userID,
+			Reason: entities.SuspenseSuggestionSenderName,
+			Detail: fmt.Sprintf("sender %q matches user %q", senderName, name),
+		})
+	}
+	return suggestions, rows.Err()
+}
+
+// ClaimCredit attributes an unmatched credit to a user and marks it crediting.
+// It returns false when the credit was no longer unmatched.
+func (r *DepositReferenceRepository) ClaimCredit(ctx context.Context, credit *entities.BankCredit) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE bank_credits SET
+			status = 'crediting', user_id = $2, reference = $3, match_method = $4,
+			matched_by = $5, matched_at = $6, note = $7, updated_at = $6
+		WHERE id = $1 AND status = 'unmatched'`,
+		credit.ID, credit.UserID, credit.Reference, credit.MatchMethod, credit.MatchedBy, credit.MatchedAt, credit.Note)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim bank credit: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ReleaseCredit returns a claimed credit to the unmatched queue after its
+// user could not be credited
+func (r *DepositReferenceRepository) ReleaseCredit(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE bank_credits SET
+			status = 'unmatched', user_id = NULL, match_method = NULL,
+			matched_by = NULL, matched_at = NULL, note = NULL, updated_at = $2
+		WHERE id = $1 AND status = 'crediting'`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to release bank credit: %w", err)
+	}
+	return nil
+}
+
+// MarkCredited completes a claimed credit
+func (r *DepositReferenceRepository) MarkCredited(ctx context.Context, id uuid.UUID, ledgerTxID *uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE bank_credits SET status = 'credited', ledger_transaction_id = $2, updated_at = $3
+		WHERE id = $1 AND status = 'crediting'`, id, ledgerTxID, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark bank credit credited: %w", err)
+	}
+	return nil
+}
+
+// MarkReturned records that an unmatched credit was sent back. It returns
+// false when the credit was no longer unmatched.
+func (r *DepositReferenceRepository) MarkReturned(ctx context.Context, id, returnedBy uuid.UUID, note string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE bank_credits SET status = 'returned', matched_by = $2, matched_at = $4, note = $3, updated_at = $4
+		WHERE id = $1 AND status = 'unmatched'`, id, returnedBy, note, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark bank credit returned: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+type bankCreditScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBankCredit(row bankCreditScanner) (*entities.BankCredit, error) {
+	credit := &entities.BankCredit{}
+	var status string
+	var userID, matchedBy, ledgerTxID uuid.NullUUID
+	var reference, matchMethod, unmatchedReason, note sql.NullString
+	var matchedAt sql.NullTime
+
+	if err := row.Scan(
+		&credit.ID,
+		&credit.ProviderRef,
+		&credit.AccountNumber,
+		&credit.Currency,
+		&credit.Amount,
+		&credit.Memo,
+		&credit.SenderName,
+		&status,
+		&userID,
+		&reference,
+		&matchMethod,
+		&unmatchedReason,
+		&matchedBy,
+		&matchedAt,
+		&note,
+		&ledgerTxID,
+		&credit.ReceivedAt,
+		&credit.CreatedAt,
+		&credit.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	credit.Status = entities.BankCreditStatus(status)
+	if userID.Valid {
+		credit.UserID = &userID.UUID
+	}
+	if reference.Valid {
+		credit.Reference = &reference.String
+	}
+	if matchMethod.Valid {
+		credit.MatchMethod = &matchMethod.String
+	}
+	if unmatchedReason.Valid {
+		credit.UnmatchedReason = &unmatchedReason.String
+	}
+	if matchedBy.Valid {
+		credit.MatchedBy = &matchedBy.UUID
+	}
+	if matchedAt.Valid {
+		credit.MatchedAt = &matchedAt.Time
+	}
+	if note.Valid {
+		credit.Note = &note.String
+	}
+	if ledgerTxID.Valid {
+		credit.LedgerTransactionID = &ledgerTxID.UUID
+	}
+	return credit, nil
+}
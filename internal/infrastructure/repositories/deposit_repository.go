@@ -3,11 +3,13 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 )
@@ -28,9 +30,10 @@ func (r *DepositRepository) Create(ctx context.Context, deposit *entities.Deposi
 		INSERT INTO deposits (
 			id, user_id, virtual_account_id, amount, status,
 			tx_hash, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
-			alpaca_funding_tx_id, alpaca_funded_at, created_at
+			alpaca_funding_tx_id, alpaca_funded_at, created_at,
+			confirmations, required_confirmations, confirmed_at, log_index
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 		)
 	`
 
@@ -48,9 +51,17 @@ func (r *DepositRepository) Create(ctx context.Context, deposit *entities.Deposi
 		deposit.AlpacaFundingTxID,
 		deposit.AlpacaFundedAt,
 		deposit.CreatedAt,
+		deposit.Confirmations,
+		deposit.RequiredConfirmations,
+		deposit.ConfirmedAt,
+		deposit.LogIndex,
 	)
 
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrDuplicateDeposit
+		}
 		return fmt.Errorf("failed to create deposit: %w", err)
 	}
 
@@ -61,8 +72,9 @@ func (r *DepositRepository) Create(ctx context.Context, deposit *entities.Deposi
 func (r *DepositRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Deposit, error) {
 	query := `
 		SELECT id, user_id, virtual_account_id, amount, status,
-			   tx_hash, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
-			   alpaca_funding_tx_id, alpaca_funded_at, created_at
+			   tx_hash, log_index, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
+			   alpaca_funding_tx_id, alpaca_funded_at, confirmed_at, confirmations, required_confirmations,
+			   credited_amount, credited_at, ledger_transaction_id, reversed_at, reversal_reason, created_at
 		FROM deposits
 		WHERE id = $1
 	`
@@ -83,8 +95,9 @@ func (r *DepositRepository) GetByID(ctx context.Context, id uuid.UUID) (*entitie
 func (r *DepositRepository) GetByOffRampTxID(ctx context.Context, txID string) (*entities.Deposit, error) {
 	query := `
 		SELECT id, user_id, virtual_account_id, amount, status,
-			   tx_hash, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
-			   alpaca_funding_tx_id, alpaca_funded_at, created_at
+			   tx_hash, log_index, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
+			   alpaca_funding_tx_id, alpaca_funded_at, confirmed_at, confirmations, required_confirmations,
+			   credited_amount, credited_at, ledger_transaction_id, reversed_at, reversal_reason, created_at
 		FROM deposits
 		WHERE off_ramp_tx_id = $1
 	`
@@ -143,8 +156,9 @@ func (r *DepositRepository) Update(ctx context.Context, deposit *entities.Deposi
 func (r *DepositRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Deposit, error) {
 	query := `
 		SELECT id, user_id, virtual_account_id, amount, status,
-			   tx_hash, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
-			   alpaca_funding_tx_id, alpaca_funded_at, created_at
+			   tx_hash, log_index, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
+			   alpaca_funding_tx_id, alpaca_funded_at, confirmed_at, confirmations, required_confirmations,
+			   credited_amount, credited_at, ledger_transaction_id, reversed_at, reversal_reason, created_at
 		FROM deposits
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -163,8 +177,9 @@ func (r *DepositRepository) ListByUserID(ctx context.Context, userID uuid.UUID)
 func (r *DepositRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Deposit, error) {
 	query := `
 		SELECT id, user_id, virtual_account_id, amount, status,
-			   tx_hash, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
-			   alpaca_funding_tx_id, alpaca_funded_at, created_at
+			   tx_hash, log_index, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
+			   alpaca_funding_tx_id, alpaca_funded_at, confirmed_at, confirmations, required_confirmations,
+			   credited_amount, credited_at, ledger_transaction_id, reversed_at, reversal_reason, created_at
 		FROM deposits
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -184,8 +199,9 @@ func (r *DepositRepository) GetByUserID(ctx context.Context, userID uuid.UUID, l
 func (r *DepositRepository) GetByTxHash(ctx context.Context, txHash string) (*entities.Deposit, error) {
 	query := `
 		SELECT id, user_id, virtual_account_id, amount, status,
-			   tx_hash, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
-			   alpaca_funding_tx_id, alpaca_funded_at, created_at
+			   tx_hash, log_index, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
+			   alpaca_funding_tx_id, alpaca_funded_at, confirmed_at, confirmations, required_confirmations,
+			   credited_amount, credited_at, ledger_transaction_id, reversed_at, reversal_reason, created_at
 		FROM deposits
 		WHERE tx_hash = $1
 	`
@@ -202,6 +218,30 @@ func (r *DepositRepository) GetByTxHash(ctx context.Context, txHash string) (*en
 	return &deposit, nil
 }
 
+// GetByTransfer retrieves the deposit of one transfer, identified by chain,
+// transaction hash and log index
+func (r *DepositRepository) GetByTransfer(ctx context.Context, chain entities.Chain, txHash string, logIndex int) (*entities.Deposit, error) {
+	query := `
+		SELECT id, user_id, virtual_account_id, amount, status,
+			   tx_hash, log_index, chain, off_ramp_tx_id, off_ramp_initiated_at, off_ramp_completed_at,
+			   alpaca_funding_tx_id, alpaca_funded_at, confirmed_at, confirmations, required_confirmations,
+			   credited_amount, credited_at, ledger_transaction_id, reversed_at, reversal_reason, created_at
+		FROM deposits
+		WHERE chain = $1 AND tx_hash = $2 AND log_index = $3
+	`
+
+	var deposit entities.Deposit
+	err := r.db.GetContext(ctx, &deposit, query, chain, txHash, logIndex)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deposit not found")
+		}
+		return nil, fmt.Errorf("failed to get deposit: %w", err)
+	}
+
+	return &deposit, nil
+}
+
 // UpdateStatus updates the status of a deposit
 func (r *DepositRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string, confirmedAt *time.Time) error {
 	query := `
@@ -218,6 +258,82 @@ func (r *DepositRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 	return nil
 }
 
+// UpdateConfirmations records the latest confirmation count of a deposit
+// still waiting for its threshold
+func (r *DepositRepository) UpdateConfirmations(ctx context.Context, id uuid.UUID, confirmations int) error {
+	query := `
+		UPDATE deposits
+		SET confirmations = GREATEST(confirmations, $2)
+		WHERE id = $1 AND status = 'detected'
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, confirmations)
+	if err != nil {
+		return fmt.Errorf("failed to update deposit confirmations: %w", err)
+	}
+
+	return nil
+}
+
+// MarkConfirmed moves a detected deposit to confirmed once its confirmation
+// threshold is reached
+func (r *DepositRepository) MarkConfirmed(ctx context.Context, id uuid.UUID, confirmations int, confirmedAt time.Time) error {
+	query := `
+		UPDATE deposits
+		SET status = 'confirmed', confirmations = GREATEST(confirmations, $2), confirmed_at = $3
+		WHERE id = $1 AND status = 'detected'
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, confirmations, confirmedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark deposit confirmed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkCredited moves a confirmed deposit to credited. It reports false when
+// the deposit was no longer confirmed, so concurrent notifications credit once.
+func (r *DepositRepository) MarkCredited(ctx context.Context, id uuid.UUID, amount decimal.Decimal, ledgerTxID *uuid.UUID, creditedAt time.Time) (bool, error) {
+	query := `
+		UPDATE deposits
+		SET status = 'credited', credited_amount = $2, ledger_transaction_id = $3, credited_at = $4
+		WHERE id = $1 AND status = 'confirmed'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, amount, ledgerTxID, creditedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark deposit credited: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark deposit credited: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
+// MarkReversed moves a deposit in the given status to reversed. It reports
+// false when the deposit had already moved on.
+func (r *DepositRepository) MarkReversed(ctx context.Context, id uuid.UUID, fromStatus, reason string, reversedAt time.Time) (bool, error) {
+	query := `
+		UPDATE deposits
+		SET status = 'reversed', reversal_reason = $3, reversed_at = $4
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, fromStatus, reason, reversedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark deposit reversed: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark deposit reversed: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
 // GetTotalCompletedDeposits returns the sum of all completed deposits
 func (r *DepositRepository) GetTotalCompletedDeposits(ctx context.Context) (decimal.Decimal, error) {
 	query := `
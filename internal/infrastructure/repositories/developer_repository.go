@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// DeveloperRepository stores developer program tenants
+type DeveloperRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewDeveloperRepository creates a new developer repository
+func NewDeveloperRepository(db *sql.DB, logger *zap.Logger) *DeveloperRepository {
+	return &DeveloperRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateTenant stores a tenant. A user has at most one.
+func (r *DeveloperRepository) CreateTenant(ctx context.Context, tenant *entities.DeveloperTenant) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO developer_tenants (id, user_id, name, environment, created_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		tenant.ID, tenant.UserID, tenant.Name, tenant.Environment, tenant.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrDeveloperTenantExists
+		}
+		return fmt.Errorf("failed to create developer tenant: %w", err)
+	}
+	return nil
+}
+
+// GetTenantByUser returns the user's tenant
+func (r *DeveloperRepository) GetTenantByUser(ctx context.Context, userID uuid.UUID) (*entities.DeveloperTenant, error) {
+	var tenant entities.DeveloperTenant
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, name, environment, created_at
+		FROM developer_tenants
+		WHERE user_id = $1`, userID).Scan(
+		&tenant.ID, &tenant.UserID, &tenant.Name, &tenant.Environment, &tenant.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrDeveloperTenantNotFound
+		}
+		return nil, fmt.Errorf("failed to get developer tenant: %w", err)
+	}
+	return &tenant, nil
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// DocumentRepository persists document center entries and read receipts
+type DocumentRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewDocumentRepository creates a new document repository
+func NewDocumentRepository(db *sql.DB, logger *zap.Logger) *DocumentRepository {
+	return &DocumentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// documentListColumns leave out the body, which only Get returns. The
+// receipt join is on the requesting user, bound as $1.
+const documentListColumns = `
+	d.id, d.user_id, d.category, d.title, d.description, d.url, '' AS body, d.content_type,
+	d.mandatory, d.order_id, d.published_by, d.published_at, r.read_at`
+
+const documentVisibleFrom = `
+	FROM documents d
+	LEFT JOIN document_receipts r ON r.document_id = d.id AND r.user_id = $1
+	WHERE (d.user_id = $1 OR d.user_id IS NULL)`
+
+// Create inserts a document
+func (r *DocumentRepository) Create(ctx context.Context, document *entities.Document) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO documents (
+			id, user_id, category, title, description, url, body, content_type,
+			mandatory, order_id, published_by, published_at, storage_region
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, ''))`,
+		document.ID, document.UserID, string(document.Category), document.Title, document.Description,
+		document.URL, document.Body, document.ContentType, document.Mandatory, document.OrderID,
+		document.PublishedBy, document.PublishedAt, string(document.StorageRegion),
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return entities.ErrDocumentExists
+		}
+		r.logger.Error("Failed to create document", zap.Error(err),
+			zap.String("category", string(document.Category)))
+		return fmt.Errorf("failed to create document: %w", err)
+	}
+	return nil
+}
+
+// List returns the documents the user can see, newest first
+func (r *DocumentRepository) List(ctx context.Context, userID uuid.UUID, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+documentListColumns+documentVisibleFrom+`
+		  AND ($2 = '' OR d.category = $2)
+		ORDER BY d.published_at DESC, d.id
+		LIMIT $3 OFFSET $4`,
+		userID, string(filter.Category), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	return scanDocuments(rows)
+}
+
+// Get returns a document the user can see, with its body
+func (r *DocumentRepository) Get(ctx context.Context, userID, id uuid.UUID) (*entities.Document, error) {
+	document, err := scanDocument(r.db.QueryRowContext(ctx, `
+		SELECT d.id, d.user_id, d.category, d.title, d.description, d.url, d.body, d.content_type,
+			d.mandatory, d.order_id, d.published_by, d.published_at, r.read_at`+documentVisibleFrom+`
+		  AND d.id = $2`,
+		userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	return document, nil
+}
+
+// ListUnreadMandatory returns the mandatory documents the user has not
+// opened, oldest first
+func (r *DocumentRepository) ListUnreadMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.Document, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+documentListColumns+documentVisibleFrom+`
+		  AND d.mandatory AND r.read_at IS NULL
+		ORDER BY d.published_at, d.id`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unread documents: %w", err)
+	}
+	return scanDocuments(rows)
+}
+
+// RecordReceipt stores the user's first read of a document and returns the
+// receipt on record
+func (r *DocumentRepository) RecordReceipt(ctx context.Context, receipt *entities.DocumentReceipt) (*entities.DocumentReceipt, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO document_receipts (document_id, user_id, ip_address, user_agent, read_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+		ON CONFLICT (document_id, user_id) DO NOTHING`,
+		receipt.DocumentID, receipt.UserID, receipt.IPAddress, receipt.UserAgent, receipt.ReadAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record document receipt: %w", err)
+	}
+
+	stored, err := scanReceipt(r.db.QueryRowContext(ctx, `
+		SELECT document_id, user_id, ip_address, user_agent, read_at
+		FROM document_receipts
+		WHERE document_id = $1 AND user_id = $2`,
+		receipt.DocumentID, receipt.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document receipt: %w", err)
+	}
+	return stored, nil
+}
+
+// ListBroadcast returns the documents shown to every user, newest first
+func (r *DocumentRepository) ListBroadcast(ctx context.Context, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, category, title, description, url, '' AS body, content_type,
+			mandatory, order_id, published_by, published_at, NULL::timestamptz
+		FROM documents
+		WHERE user_id IS NULL AND ($1 = '' OR category = $1)
+		ORDER BY published_at DESC, id
+		LIMIT $2 OFFSET $3`,
+		string(filter.Category), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published documents: %w", err)
+	}
+	return scanDocuments(rows)
+}
+
+// ListReceipts returns a document's read receipts, most recent first
+func (r *DocumentRepository) ListReceipts(ctx context.Context, documentID uuid.UUID, limit, offset int) ([]*entities.DocumentReceipt, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT document_id, user_id, ip_address, user_agent, read_at
+		FROM document_receipts
+		WHERE document_id = $1
+		ORDER BY read_at DESC, user_id
+		LIMIT $2 OFFSET $3`,
+		documentID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document receipts: %w", err)
+	}
+	defer rows.Close()
+
+	receipts := []*entities.DocumentReceipt{}
+	for rows.Next() {
+		receipt, err := scanReceipt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document receipt: %w", err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate document receipts: %w", err)
+	}
+	return receipts, nil
+}
+
+type documentScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDocuments(rows *sql.Rows) ([]*entities.Document, error) {
+	defer rows.Close()
+
+	documents := []*entities.Document{}
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate documents: %w", err)
+	}
+	return documents, nil
+}
+
+func scanDocument(row documentScanner) (*entities.Document, error) {
+	document := &entities.Document{}
+	var category string
+	var userID, orderID, publishedBy uuid.NullUUID
+	var readAt sql.NullTime
+	if err := row.Scan(
+		&document.ID,
+		&userID,
+		&category,
+		&document.Title,
+		&document.Description,
+		&document.URL,
+		&document.Body,
+		&document.ContentType,
+		&document.Mandatory,
+		&orderID,
+		&publishedBy,
+		&document.PublishedAt,
+		&readAt,
+	); err != nil {
+		return nil, err
+	}
+	document.Category = entities.DocumentCategory(category)
+	if userID.Valid {
+		document.UserID = &userID.UUID
+	}
+	if orderID.Valid {
+		document.OrderID = &orderID.UUID
+	}
+	if publishedBy.Valid {
+		document.PublishedBy = &publishedBy.UUID
+	}
+	if readAt.Valid {
+		document.ReadAt = &readAt.Time
+	}
+	return document, nil
+}
+
+func scanReceipt(row documentScanner) (*entities.DocumentReceipt, error) {
+	receipt := &entities.DocumentReceipt{}
+	var ipAddress, userAgent sql.NullString
+	if err := row.Scan(&receipt.DocumentID, &receipt.UserID, &ipAddress, &userAgent, &receipt.ReadAt); err != nil {
+		return nil, err
+	}
+	receipt.IPAddress = ipAddress.String
+	receipt.UserAgent = userAgent.String
+	return receipt, nil
+}
@@ -0,0 +1,281 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// EDDRepository persists enhanced due diligence submissions and the KYC tier
+// and transaction limits of user accounts
+type EDDRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewEDDRepository creates a new enhanced due diligence repository
+func NewEDDRepository(db *sql.DB, logger *zap.Logger) *EDDRepository {
+	return &EDDRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const eddSubmissionColumns = `
+	id, user_id, status, source_of_funds, documents, case_id, provider_ref,
+	rejection_reason, reviewed_by, review_notes, reviewed_at, created_at, updated_at`
+
+// GetAccount returns a user's KYC status, provider applicant and tier
+func (r *EDDRepository) GetAccount(ctx context.Context, userID uuid.UUID) (*entities.AccountKYCTier, error) {
+	account := &entities.AccountKYCTier{}
+	var tier string
+	var kycStatus, providerRef sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, kyc_status, kyc_provider_ref, kyc_tier
+		FROM users WHERE id = $1`, userID).Scan(
+		&account.UserID,
+		&kycStatus,
+		&providerRef,
+		&tier,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get account tier: %w", err)
+	}
+
+	account.KYCStatus = entities.KYCStatus(kycStatus.String)
+	account.Tier = entities.KYCTier(tier)
+	if providerRef.Valid {
+		account.KYCProviderRef = &providerRef.String
+	}
+	return account, nil
+}
+
+// ApplyTier sets the user's tier and replaces the matching transaction limits
+// in one transaction. Usage already counted in the current period is kept.
+func (r *EDDRepository) ApplyTier(ctx context.Context, userID uuid.UUID, tier entities.KYCTier, limits []entities.TierLimit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `UPDATE users SET kyc_tier = $2, updated_at = $3 WHERE id = $1`, userID, string(tier), now)
+	if err != nil {
+		r.logger.Error("Failed to set KYC tier", zap.Error(err), zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to set kyc tier: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	for _, limit := range limits {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO transaction_limits (user_id, limit_type, period, max_amount, used_amount, reset_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, 0, $5, $6, $6)
+			ON CONFLICT (user_id, limit_type, period) DO UPDATE SET
+				max_amount = EXCLUDED.max_amount, updated_at = EXCLUDED.updated_at`,
+			userID, string(limit.LimitType), string(limit.Period), limit.MaxAmount, nextLimitReset(limit.Period, now), now)
+		if err != nil {
+			r.logger.Error("Failed to set transaction limit", zap.Error(err),
+				zap.String("user_id", userID.String()),
+				zap.String("limit_type", string(limit.LimitType)))
+			return fmt.Errorf("failed to set transaction limit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit kyc tier: %w", err)
+	}
+	return nil
+}
+
+// Create inserts a submission unless the user already has one awaiting
+// review, in which case the existing submission is returned with created=false
+func (r *EDDRepository) Create(ctx context.Context, submission *entities.EDDSubmission) (*entities.EDDSubmission, bool, error) {
+	documents, err := json.Marshal(submission.Documents)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal documents: %w", err)
+	}
+
+	query := `
+		INSERT INTO edd_submissions (
+			id, user_id, status, source_of_funds, documents, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (user_id) WHERE status = 'pending_review' DO NOTHING
+		RETURNING ` + eddSubmissionColumns
+
+	created, err := scanEDDSubmission(r.db.QueryRowContext(ctx, query,
+		submission.ID, submission.UserID, string(submission.Status), submission.SourceOfFunds,
+		documents, submission.CreatedAt))
+	if err == nil {
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to create EDD submission", zap.Error(err), zap.String("user_id", submission.UserID.String()))
+		return nil, false, fmt.Errorf("failed to create edd submission: %w", err)
+	}
+
+	existing, err := r.GetLatestByUser(ctx, submission.UserID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load pending edd submission: %w", err)
+	}
+	return existing, false, nil
+}
+
+// GetByID retrieves a submission
+func (r *EDDRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.EDDSubmission, error) {
+	submission, err := scanEDDSubmission(r.db.QueryRowContext(ctx,
+		`SELECT `+eddSubmissionColumns+` FROM edd_submissions WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrEDDSubmissionNotFound
+		}
+		return nil, fmt.Errorf("failed to get edd submission: %w", err)
+	}
+	return submission, nil
+}
+
+// GetLatestByUser retrieves the user's most recent submission
+func (r *EDDRepository) GetLatestByUser(ctx context.Context, userID uuid.UUID) (*entities.EDDSubmission, error) {
+	submission, err := scanEDDSubmission(r.db.QueryRowContext(ctx, `
+		SELECT `+eddSubmissionColumns+` FROM edd_submissions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrEDDSubmissionNotFound
+		}
+		return nil, fmt.Errorf("failed to get edd submission: %w", err)
+	}
+	return submission, nil
+}
+
+// List returns submissions filtered by optional status, oldest first so the
+// queue is worked in order
+func (r *EDDRepository) List(ctx context.Context, status entities.EDDStatus, limit, offset int) ([]*entities.EDDSubmission, error) {
+	query := `
+		SELECT ` + eddSubmissionColumns + `
+		FROM edd_submissions
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edd submissions: %w", err)
+	}
+	defer rows.Close()
+
+	var submissions []*entities.EDDSubmission
+	for rows.Next() {
+		submission, err := scanEDDSubmission(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan edd submission: %w", err)
+		}
+		submissions = append(submissions, submission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate edd submissions: %w", err)
+	}
+	return submissions, nil
+}
+
+// Update persists the routing and outcome of a submission
+func (r *EDDRepository) Update(ctx context.Context, submission *entities.EDDSubmission) error {
+	query := `
+		UPDATE edd_submissions SET
+			status = $2, case_id = $3, provider_ref = $4, rejection_reason = $5,
+			reviewed_by = $6, review_notes = $7, reviewed_at = $8, updated_at = $9
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		submission.ID, string(submission.Status), submission.CaseID, submission.ProviderRef,
+		submission.RejectionReason, submission.ReviewedBy, submission.ReviewNotes,
+		submission.ReviewedAt, submission.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update edd submission: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrEDDSubmissionNotFound
+	}
+	return nil
+}
+
+// nextLimitReset returns when a limit period that includes now ends
+func nextLimitReset(period entities.LimitPeriod, now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case entities.LimitPeriodWeekly:
+		return day.AddDate(0, 0, 7)
+	case entities.LimitPeriodMonthly:
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return day.AddDate(0, 0, 1)
+	}
+}
+
+func scanEDDSubmission(row adminCaseScanner) (*entities.EDDSubmission, error) {
+	submission := &entities.EDDSubmission{}
+	var status string
+	var documents []byte
+	var providerRef, rejectionReason, reviewNotes sql.NullString
+	var caseID, reviewedBy uuid.NullUUID
+	var reviewedAt sql.NullTime
+
+	if err := row.Scan(
+		&submission.ID,
+		&submission.UserID,
+		&status,
+		&submission.SourceOfFunds,
+		&documents,
+		&caseID,
+		&providerRef,
+		&rejectionReason,
+		&reviewedBy,
+		&reviewNotes,
+		&reviewedAt,
+		&submission.CreatedAt,
+		&submission.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	submission.Status = entities.EDDStatus(status)
+	if len(documents) > 0 {
+		if err := json.Unmarshal(documents, &submission.Documents); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal documents: %w", err)
+		}
+	}
+	if caseID.Valid {
+		submission.CaseID = &caseID.UUID
+	}
+	if providerRef.Valid {
+		submission.ProviderRef = &providerRef.String
+	}
+	if rejectionReason.Valid {
+		submission.RejectionReason = &rejectionReason.String
+	}
+	if reviewedBy.Valid {
+		submission.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewNotes.Valid {
+		submission.ReviewNotes = &reviewNotes.String
+	}
+	if reviewedAt.Valid {
+		submission.ReviewedAt = &reviewedAt.Time
+	}
+	return submission, nil
+}
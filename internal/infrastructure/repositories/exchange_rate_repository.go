@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ExchangeRateRepository persists historical exchange rates
+type ExchangeRateRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewExchangeRateRepository creates a new exchange rate repository
+func NewExchangeRateRepository(db *sql.DB, logger *zap.Logger) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Insert stores rates in one transaction, skipping any already recorded for
+// the same pair, time and source, and returns how many were new
+func (r *ExchangeRateRepository) Insert(ctx context.Context, rates []*entities.ExchangeRate) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO exchange_rates (
+			id, base_currency, quote_currency, rate, observed_at, source,
+			reference_type, reference_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (base_currency, quote_currency, observed_at, source) DO NOTHING`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare exchange rate insert: %w", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, rate := range rates {
+		result, err := stmt.ExecContext(ctx,
+			rate.ID, rate.Pair.Base, rate.Pair.Quote, rate.Rate, rate.ObservedAt, rate.Source,
+			rate.ReferenceType, rate.ReferenceID, rate.CreatedAt)
+		if err != nil {
+			r.logger.Error("Failed to insert exchange rate", zap.Error(err),
+				zap.String("pair", rate.Pair.String()),
+				zap.Time("observed_at", rate.ObservedAt))
+			return 0, fmt.Errorf("failed to insert exchange rate: %w", err)
+		}
+		added, _ := result.RowsAffected()
+		inserted += int(added)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit exchange rates: %w", err)
+	}
+	return inserted, nil
+}
+
+// Nearest returns the rate for the pair observed closest to at, no more than
+// window either side of it
+func (r *ExchangeRateRepository) Nearest(ctx context.Context, pair entities.CurrencyPair, at time.Time, window time.Duration) (*entities.ExchangeRate, error) {
+	query := `
+		SELECT id, base_currency, quote_currency, rate, observed_at, source,
+			reference_type, reference_id, created_at
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2
+			AND observed_at BETWEEN $3 AND $4
+		ORDER BY ABS(EXTRACT(EPOCH FROM (observed_at - $5::timestamptz))) ASC, observed_at DESC
+		LIMIT 1`
+
+	rate := &entities.ExchangeRate{}
+	var referenceType sql.NullString
+	var referenceID uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, query,
+		pair.Base, pair.Quote, at.Add(-window), at.Add(window), at).Scan(
+		&rate.ID,
+		&rate.Pair.Base,
+		&rate.Pair.Quote,
+		&rate.Rate,
+		&rate.ObservedAt,
+		&rate.Source,
+		&referenceType,
+		&referenceID,
+		&rate.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrRateNotFound
+		}
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+
+	if referenceType.Valid {
+		rate.ReferenceType = &referenceType.String
+	}
+	if referenceID.Valid {
+		rate.ReferenceID = &referenceID.UUID
+	}
+	return rate, nil
+}
@@ -0,0 +1,292 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ExperimentRepository persists onboarding experiments, user assignments and
+// the exposure and conversion events exported to the warehouse
+type ExperimentRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewExperimentRepository creates a new experiment repository
+func NewExperimentRepository(db *sql.DB, logger *zap.Logger) *ExperimentRepository {
+	return &ExperimentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const experimentColumns = `id, key, description, variants, status, winning_variant, conclusion_reason,
+	created_by, concluded_by, created_at, concluded_at`
+
+// Create stores a new experiment, returning ErrExperimentExists when the key
+// is taken
+func (r *ExperimentRepository) Create(ctx context.Context, experiment *entities.Experiment) error {
+	variants, err := json.Marshal(experiment.Variants)
+	if err != nil {
+		return fmt.Errorf("failed to marshal experiment variants: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO experiments (id, key, description, variants, status, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		experiment.ID, experiment.Key, experiment.Description, variants, experiment.Status,
+		experiment.CreatedBy, experiment.CreatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrExperimentExists
+		}
+		return fmt.Errorf("failed to create experiment: %w", err)
+	}
+	return nil
+}
+
+// Get returns an experiment by ID
+func (r *ExperimentRepository) Get(ctx context.Context, id uuid.UUID) (*entities.Experiment, error) {
+	experiment, err := scanExperiment(r.db.QueryRowContext(ctx, `
+		SELECT `+experimentColumns+` FROM experiments WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrExperimentNotFound
+		}
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+	return experiment, nil
+}
+
+// List returns every experiment, oldest first
+func (r *ExperimentRepository) List(ctx context.Context) ([]*entities.Experiment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+experimentColumns+` FROM experiments ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiments: %w", err)
+	}
+	defer rows.Close()
+
+	var experiments []*entities.Experiment
+	for rows.Next() {
+		experiment, err := scanExperiment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan experiment: %w", err)
+		}
+		experiments = append(experiments, experiment)
+	}
+	return experiments, rows.Err()
+}
+
+// Conclude marks a running experiment concluded with its winner
+func (r *ExperimentRepository) Conclude(ctx context.Context, id uuid.UUID, winningVariant, reason string, adminID uuid.UUID, at time.Time) (*entities.Experiment, error) {
+	experiment, err := scanExperiment(r.db.QueryRowContext(ctx, `
+		UPDATE experiments
+		SET status = 'concluded', winning_variant = $2, conclusion_reason = $3, concluded_by = $4, concluded_at = $5
+		WHERE id = $1 AND status = 'running'
+		RETURNING `+experimentColumns,
+		id, winningVariant, reason, adminID, at))
+	if err == nil {
+		return experiment, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to conclude experiment: %w", err)
+	}
+	if _, getErr := r.Get(ctx, id); getErr != nil {
+		return nil, getErr
+	}
+	return nil, entities.ErrExperimentConcluded
+}
+
+// ListAssignments returns the user's variant in every experiment they are in
+func (r *ExperimentRepository) ListAssignments(ctx context.Context, userID uuid.UUID) ([]*entities.ExperimentAssignment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT experiment_id, user_id, variant, assigned_at
+		FROM experiment_assignments
+		WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiment assignments: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []*entities.ExperimentAssignment
+	for rows.Next() {
+		var assignment entities.ExperimentAssignment
+		if err := rows.Scan(&assignment.ExperimentID, &assignment.UserID, &assignment.Variant, &assignment.AssignedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan experiment assignment: %w", err)
+		}
+		assignments = append(assignments, &assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// AssignAndExpose stores the assignment with its exposure event. If a
+// concurrent request assigned the user first, their variant is kept and
+// returned.
+func (r *ExperimentRepository) AssignAndExpose(ctx context.Context, assignment *entities.ExperimentAssignment) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO experiment_assignments (experiment_id, user_id, variant, assigned_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (experiment_id, user_id) DO NOTHING`,
+		assignment.ExperimentID, assignment.UserID, assignment.Variant, assignment.AssignedAt,
+	); err != nil {
+		return "", fmt.Errorf("failed to store experiment assignment: %w", err)
+	}
+
+	var variant string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT variant FROM experiment_assignments WHERE experiment_id = $1 AND user_id = $2`,
+		assignment.ExperimentID, assignment.UserID,
+	).Scan(&variant); err != nil {
+		return "", fmt.Errorf("failed to read experiment assignment: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO experiment_events (experiment_id, user_id, variant, kind, goal, created_at)
+		VALUES ($1, $2, $3, 'exposure', '', $4)
+		ON CONFLICT (experiment_id, user_id, kind, goal) DO NOTHING`,
+		assignment.ExperimentID, assignment.UserID, variant, assignment.AssignedAt,
+	); err != nil {
+		return "", fmt.Errorf("failed to record experiment exposure: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit experiment assignment: %w", err)
+	}
+	return variant, nil
+}
+
+// RecordConversion records the goal in each running experiment the user is
+// assigned to and returns how many were recorded
+func (r *ExperimentRepository) RecordConversion(ctx context.Context, userID uuid.UUID, goal string, at time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO experiment_events (experiment_id, user_id, variant, kind, goal, created_at)
+		SELECT a.experiment_id, a.user_id, a.variant, 'conversion', $2, $3
+		FROM experiment_assignments a
+		JOIN experiments e ON e.id = a.experiment_id
+		WHERE a.user_id = $1 AND e.status = 'running'
+		ON CONFLICT (experiment_id, user_id, kind, goal) DO NOTHING`,
+		userID, goal, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record experiment conversion: %w", err)
+	}
+	recorded, _ := result.RowsAffected()
+	return recorded, nil
+}
+
+// Results counts assignments, exposures and conversions per variant
+func (r *ExperimentRepository) Results(ctx context.Context, id uuid.UUID) ([]*entities.ExperimentVariantResult, error) {
+	byVariant := map[string]*entities.ExperimentVariantResult{}
+	result := func(variant string) *entities.ExperimentVariantResult {
+		if byVariant[variant] == nil {
+			byVariant[variant] = &entities.ExperimentVariantResult{Variant: variant, Conversions: map[string]int64{}}
+		}
+		return byVariant[variant]
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT variant, COUNT(*) FROM experiment_assignments
+		WHERE experiment_id = $1
+		GROUP BY variant`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count experiment assignments: %w", err)
+	}
+	for rows.Next() {
+		var variant string
+		var count int64
+		if err := rows.Scan(&variant, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan experiment assignments: %w", err)
+		}
+		result(variant).Assigned = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count experiment assignments: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT variant, kind, goal, COUNT(*) FROM experiment_events
+		WHERE experiment_id = $1
+		GROUP BY variant, kind, goal`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count experiment events: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var variant, kind, goal string
+		var count int64
+		if err := rows.Scan(&variant, &kind, &goal, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan experiment events: %w", err)
+		}
+		switch entities.ExperimentEventKind(kind) {
+		case entities.ExperimentEventExposure:
+			result(variant).Exposed = count
+		case entities.ExperimentEventConversion:
+			result(variant).Conversions[goal] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count experiment events: %w", err)
+	}
+
+	results := make([]*entities.ExperimentVariantResult, 0, len(byVariant))
+	for _, variantResult := range byVariant {
+		results = append(results, variantResult)
+	}
+	return results, nil
+}
+
+type experimentScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExperiment(row experimentScanner) (*entities.Experiment, error) {
+	var experiment entities.Experiment
+	var variants []byte
+	var winningVariant, conclusionReason sql.NullString
+	var createdBy, concludedBy uuid.NullUUID
+	var concludedAt sql.NullTime
+	if err := row.Scan(
+		&experiment.ID, &experiment.Key, &experiment.Description, &variants, &experiment.Status,
+		&winningVariant, &conclusionReason, &createdBy, &concludedBy, &experiment.CreatedAt, &concludedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variants, &experiment.Variants); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal experiment variants: %w", err)
+	}
+	if winningVariant.Valid {
+		experiment.WinningVariant = &winningVariant.String
+	}
+	if conclusionReason.Valid {
+		experiment.ConclusionReason = &conclusionReason.String
+	}
+	if createdBy.Valid {
+		experiment.CreatedBy = &createdBy.UUID
+	}
+	if concludedBy.Valid {
+		experiment.ConcludedBy = &concludedBy.UUID
+	}
+	if concludedAt.Valid {
+		experiment.ConcludedAt = &concludedAt.Time
+	}
+	return &experiment, nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// FaultInjectionRepository stores the faults admins inject into outbound
+// provider calls. Expired rules are ignored.
+type FaultInjectionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewFaultInjectionRepository creates a new fault injection repository
+func NewFaultInjectionRepository(db *sql.DB, logger *zap.Logger) *FaultInjectionRepository {
+	return &FaultInjectionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateRule inserts a fault rule
+func (r *FaultInjectionRepository) CreateRule(ctx context.Context, rule *entities.FaultRule) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO fault_injection_rules (
+			id, provider, method, path_prefix, fault, latency_ms, status_code, percent,
+			note, created_by, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		rule.ID, string(rule.Provider), rule.Method, rule.PathPrefix, string(rule.Fault), rule.LatencyMs,
+		rule.StatusCode, rule.Percent, rule.Note, rule.CreatedBy, rule.CreatedAt, rule.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create fault rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule removes a fault rule, reporting whether it existed
+func (r *FaultInjectionRepository) DeleteRule(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM fault_injection_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete fault rule: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// ListActiveRules returns unexpired fault rules, newest first
+func (r *FaultInjectionRepository) ListActiveRules(ctx context.Context) ([]*entities.FaultRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, provider, method, path_prefix, fault, latency_ms, status_code, percent,
+			note, created_by, created_at, expires_at
+		FROM fault_injection_rules
+		WHERE expires_at > NOW()
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fault rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*entities.FaultRule
+	for rows.Next() {
+		rule := &entities.FaultRule{}
+		var provider, fault string
+		if err := rows.Scan(&rule.ID, &provider, &rule.Method, &rule.PathPrefix, &fault, &rule.LatencyMs,
+			&rule.StatusCode, &rule.Percent, &rule.Note, &rule.CreatedBy, &rule.CreatedAt, &rule.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fault rule: %w", err)
+		}
+		rule.Provider = entities.FaultProvider(provider)
+		rule.Fault = entities.FaultType(fault)
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate fault rules: %w", err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// fieldCipher wraps an optional FieldEncryptor so repositories keep storing
+// plaintext when field-level encryption has not been configured.
+type fieldCipher struct {
+	enc *crypto.FieldEncryptor
+}
+
+// fieldColumn is where an encrypted value is stored. Values are sealed to
+// their column and row, so a ciphertext copied elsewhere fails to decrypt.
+type fieldColumn struct {
+	table  string
+	column string
+}
+
+func (f fieldCipher) seal(col fieldColumn, rowID uuid.UUID, value string) (string, error) {
+	if f.enc == nil {
+		return value, nil
+	}
+	return f.enc.EncryptField(value, col.table, col.column, rowID.String())
+}
+
+func (f fieldCipher) sealPtr(col fieldColumn, rowID uuid.UUID, value *string) (*string, error) {
+	if value == nil || f.enc == nil {
+		return value, nil
+	}
+	sealed, err := f.seal(col, rowID, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+func (f fieldCipher) open(col fieldColumn, rowID uuid.UUID, value string) (string, error) {
+	if f.enc == nil {
+		return value, nil
+	}
+	return f.enc.DecryptField(value, col.table, col.column, rowID.String())
+}
+
+// openPtr decrypts a nullable column in place
+func (f fieldCipher) openPtr(col fieldColumn, rowID uuid.UUID, value *string) error {
+	if value == nil || f.enc == nil {
+		return nil
+	}
+	plain, err := f.open(col, rowID, *value)
+	if err != nil {
+		return err
+	}
+	*value = plain
+	return nil
+}
+
+// index returns the blind index for an encrypted lookup column
+func (f fieldCipher) index(value *string) sql.NullString {
+	if value == nil || *value == "" || f.enc == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: f.enc.BlindIndex(*value), Valid: true}
+}
+
+// FieldRotationResult summarizes a re-encryption pass over one table
+type FieldRotationResult struct {
+	Table   string `json:"table"`
+	Scanned int    `json:"scanned"`
+	Rotated int    `json:"rotated"`
+	Failed  int    `json:"failed"`
+}
+
+// encryptedColumn describes a column rewritten by the rotation job. indexColumn
+// is set when the column also carries a blind index for lookups.
+type encryptedColumn struct {
+	name        string
+	indexColumn string
+}
+
+// rotateTableFields walks a table by primary key and re-encrypts every listed
+// column that is still plaintext, not yet bound to its row, or sealed under a
+// retired key version. Rows that fail to decrypt are counted and skipped so
+// one bad row cannot stall the job.
+func rotateTableFields(ctx context.Context, db *sql.DB, enc *crypto.FieldEncryptor, table string, columns []encryptedColumn, batchSize int) (FieldRotationResult, error) {
+	result := FieldRotationResult{Table: table}
+	if enc == nil {
+		return result, fmt.Errorf("field encryption is not configured")
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	selectCols := []string{"id::text"}
+	setClauses := []string{}
+	for _, col := range columns {
+		selectCols = append(selectCols, col.name)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", col.name, len(setClauses)+2))
+		if col.indexColumn != "" {
+			selectCols = append(selectCols, col.indexColumn)
+			setClauses = append(setClauses, fmt.Sprintf("%s = $%d", col.indexColumn, len(setClauses)+2))
+		}
+	}
+	selectQuery := fmt.Sprintf(`SELECT %s FROM %s WHERE id::text > $1 ORDER BY id::text LIMIT $2`,
+		strings.Join(selectCols, ", "), table)
+	updateQuery := fmt.Sprintf(`UPDATE %s SET %s WHERE id::text = $1`, table, strings.Join(setClauses, ", "))
+
+	cursor := ""
+	for {
+		rows, err := db.QueryContext(ctx, selectQuery, cursor, batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to scan %s for rotation: %w", table, err)
+		}
+
+		type pending struct {
+			id     string
+			values []sql.NullString
+		}
+		var batch []pending
+		for rows.Next() {
+			values := make([]sql.NullString, len(selectCols)-1)
+			dest := make([]interface{}, 0, len(selectCols))
+			var id string
+			dest = append(dest, &id)
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return result, fmt.Errorf("failed to read %s row: %w", table, err)
+			}
+			batch = append(batch, pending{id: id, values: values})
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed to iterate %s rows: %w", table, err)
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		for _, row := range batch {
+			cursor = row.id
+			result.Scanned++
+
+			args, changed, err := rotatedRowArgs(enc, table, row.id, columns, row.values)
+			if err != nil {
+				result.Failed++
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if _, err := db.ExecContext(ctx, updateQuery, append([]interface{}{row.id}, args...)...); err != nil {
+				return result, fmt.Errorf("failed to re-encrypt %s row: %w", table, err)
+			}
+			result.Rotated++
+		}
+	}
+}
+
+// rotatedRowArgs returns the update arguments for a row and whether anything
+// changed. Values are resealed bound to their table, column and row.
+func rotatedRowArgs(enc *crypto.FieldEncryptor, table, rowID string, columns []encryptedColumn, values []sql.NullString) ([]interface{}, bool, error) {
+	args := make([]interface{}, 0, len(values))
+	changed := false
+	pos := 0
+	for _, col := range columns {
+		current := values[pos]
+		pos++
+
+		next := current
+		var plain string
+		if current.Valid && current.String != "" {
+			var err error
+			plain, err = enc.DecryptField(current.String, table, col.name, rowID)
+			if err != nil {
+				return nil, false, err
+			}
+			if enc.NeedsRotation(current.String) {
+				sealed, err := enc.EncryptField(plain, table, col.name, rowID)
+				if err != nil {
+					return nil, false, err
+				}
+				next = sql.NullString{String: sealed, Valid: true}
+				changed = true
+			}
+		}
+		args = append(args, next)
+
+		if col.indexColumn != "" {
+			currentIndex := values[pos]
+			pos++
+			nextIndex := sql.NullString{}
+			if plain != "" {
+				nextIndex = sql.NullString{String: enc.BlindIndex(plain), Valid: true}
+			}
+			if nextIndex != currentIndex {
+				changed = true
+			}
+			args = append(args, nextIndex)
+		}
+	}
+	return args, changed, nil
+}
@@ -42,18 +42,19 @@ func (r *FundingEventJobRepository) Enqueue(ctx context.Context, job *entities.F
 
 	query := `
 		INSERT INTO funding_event_jobs (
-			id, tx_hash, chain, token, amount, to_address, status,
+			id, tx_hash, log_index, chain, token, amount, to_address, status,
 			attempt_count, max_attempts, first_seen_at, webhook_payload,
 			processing_logs, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 		)
-		ON CONFLICT (tx_hash, chain) DO NOTHING
+		ON CONFLICT (chain, tx_hash, log_index) DO NOTHING
 		RETURNING id`
 
 	err = r.db.QueryRowContext(ctx, query,
 		job.ID,
 		job.TxHash,
+		job.LogIndex,
 		string(job.Chain),
 		string(job.Token),
 		job.Amount,
@@ -70,7 +71,7 @@ func (r *FundingEventJobRepository) Enqueue(ctx context.Context, job *entities.F
 
 	if err == sql.ErrNoRows {
 		// Job already exists, this is ok (idempotency)
-		r.logger.Debug("Job already exists, skipping", "tx_hash", job.TxHash, "chain", job.Chain)
+		r.logger.Debug("Job already exists, skipping", "tx_hash", job.TxHash, "log_index", job.LogIndex, "chain", job.Chain)
 		return nil
 	}
 
@@ -87,7 +88,7 @@ func (r *FundingEventJobRepository) Enqueue(ctx context.Context, job *entities.F
 func (r *FundingEventJobRepository) GetNextPendingJobs(ctx context.Context, limit int) ([]*entities.FundingEventJob, error) {
 	query := `
 		SELECT 
-			id, tx_hash, chain, token, amount, to_address, status,
+			id, tx_hash, log_index, chain, token, amount, to_address, status,
 			attempt_count, max_attempts, last_error, error_type, failure_reason,
 			first_seen_at, last_attempt_at, next_retry_at, completed_at, moved_to_dlq_at,
 			webhook_payload, processing_logs, created_at, updated_at
@@ -118,6 +119,22 @@ func (r *FundingEventJobRepository) GetNextPendingJobs(ctx context.Context, limi
 	return jobs, nil
 }
 
+// CountBacklog returns how many jobs are waiting to be processed, including
+// failed jobs whose retry is due
+func (r *FundingEventJobRepository) CountBacklog(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM funding_event_jobs
+		WHERE status = 'pending'
+		   OR (status = 'failed' AND next_retry_at IS NOT NULL AND next_retry_at <= NOW())`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending jobs: %w", err)
+	}
+	return count, nil
+}
+
 // Update updates a job's status and metadata
 func (r *FundingEventJobRepository) Update(ctx context.Context, job *entities.FundingEventJob) error {
 	logsJSON, err := json.Marshal(job.ProcessingLogs)
@@ -138,7 +155,9 @@ func (r *FundingEventJobRepository) Update(ctx context.Context, job *entities.Fu
 			completed_at = $8,
 			moved_to_dlq_at = $9,
 			processing_logs = $10,
-			updated_at = $11
+			updated_at = $11,
+			lease_expires_at = $13,
+			heartbeat_at = $14
 		WHERE id = $12`
 
 	var errorType *string
@@ -160,6 +179,8 @@ func (r *FundingEventJobRepository) Update(ctx context.Context, job *entities.Fu
 		logsJSON,
 		job.UpdatedAt,
 		job.ID,
+		job.LeaseExpiresAt,
+		job.HeartbeatAt,
 	)
 
 	if err != nil {
@@ -179,11 +200,110 @@ func (r *FundingEventJobRepository) Update(ctx context.Context, job *entities.Fu
 	return nil
 }
 
+// RenewLease extends the lease on a job that is still processing
+func (r *FundingEventJobRepository) RenewLease(ctx context.Context, id uuid.UUID, until time.Time) error {
+	query := `
+		UPDATE funding_event_jobs
+		SET lease_expires_at = $2, heartbeat_at = NOW()
+		WHERE id = $1 AND status = 'processing'`
+
+	if _, err := r.db.ExecContext(ctx, query, id, until); err != nil {
+		return fmt.Errorf("failed to renew job lease: %w", err)
+	}
+	return nil
+}
+
+// CountExpiredLeases returns how many processing jobs have outlived their lease
+func (r *FundingEventJobRepository) CountExpiredLeases(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM funding_event_jobs
+		WHERE status = 'processing' AND lease_expires_at < NOW()`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count expired job leases: %w", err)
+	}
+	return count, nil
+}
+
+// GetExpiredLeases returns processing jobs whose lease has run out, oldest
+// lease first
+func (r *FundingEventJobRepository) GetExpiredLeases(ctx context.Context, limit int) ([]*entities.FundingEventJob, error) {
+	query := `
+		SELECT 
+			id, tx_hash, log_index, chain, token, amount, to_address, status,
+			attempt_count, max_attempts, last_error, error_type, failure_reason,
+			first_seen_at, last_attempt_at, next_retry_at, completed_at, moved_to_dlq_at,
+			webhook_payload, processing_logs, created_at, updated_at
+		FROM funding_event_jobs
+		WHERE status = 'processing' AND lease_expires_at < NOW()
+		ORDER BY lease_expires_at ASC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired job leases: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*entities.FundingEventJob
+	for rows.Next() {
+		job, err := r.scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ReleaseExpiredLease writes the janitor's decision for an abandoned job,
+// reporting false when the job renewed its lease or finished in the meantime
+func (r *FundingEventJobRepository) ReleaseExpiredLease(ctx context.Context, job *entities.FundingEventJob) (bool, error) {
+	query := `
+		UPDATE funding_event_jobs
+		SET 
+			status = $2,
+			last_error = $3,
+			error_type = $4,
+			failure_reason = $5,
+			next_retry_at = $6,
+			moved_to_dlq_at = $7,
+			lease_expires_at = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'processing' AND lease_expires_at < NOW()`
+
+	var errorType *string
+	if job.ErrorType != nil {
+		et := string(*job.ErrorType)
+		errorType = &et
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		string(job.Status),
+		job.LastError,
+		errorType,
+		job.FailureReason,
+		job.NextRetryAt,
+		job.MovedToDLQAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to release job lease: %w", err)
+	}
+	released, _ := result.RowsAffected()
+	return released > 0, nil
+}
+
 // GetByID retrieves a job by ID
 func (r *FundingEventJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.FundingEventJob, error) {
 	query := `
 		SELECT 
-			id, tx_hash, chain, token, amount, to_address, status,
+			id, tx_hash, log_index, chain, token, amount, to_address, status,
 			attempt_count, max_attempts, last_error, error_type, failure_reason,
 			first_seen_at, last_attempt_at, next_retry_at, completed_at, moved_to_dlq_at,
 			webhook_payload, processing_logs, created_at, updated_at
@@ -207,7 +327,7 @@ func (r *FundingEventJobRepository) GetByID(ctx context.Context, id uuid.UUID) (
 func (r *FundingEventJobRepository) GetByTxHash(ctx context.Context, txHash string, chain entities.Chain) (*entities.FundingEventJob, error) {
 	query := `
 		SELECT 
-			id, tx_hash, chain, token, amount, to_address, status,
+			id, tx_hash, log_index, chain, token, amount, to_address, status,
 			attempt_count, max_attempts, last_error, error_type, failure_reason,
 			first_seen_at, last_attempt_at, next_retry_at, completed_at, moved_to_dlq_at,
 			webhook_payload, processing_logs, created_at, updated_at
@@ -231,7 +351,7 @@ func (r *FundingEventJobRepository) GetByTxHash(ctx context.Context, txHash stri
 func (r *FundingEventJobRepository) GetDLQJobs(ctx context.Context, limit int, offset int) ([]*entities.FundingEventJob, error) {
 	query := `
 		SELECT 
-			id, tx_hash, chain, token, amount, to_address, status,
+			id, tx_hash, log_index, chain, token, amount, to_address, status,
 			attempt_count, max_attempts, last_error, error_type, failure_reason,
 			first_seen_at, last_attempt_at, next_retry_at, completed_at, moved_to_dlq_at,
 			webhook_payload, processing_logs, created_at, updated_at
@@ -269,6 +389,7 @@ func (r *FundingEventJobRepository) GetPendingDepositsForReconciliation(ctx cont
 			d.id as deposit_id,
 			d.user_id,
 			d.tx_hash,
+			d.log_index,
 			d.chain,
 			d.token,
 			d.amount,
@@ -299,6 +420,7 @@ func (r *FundingEventJobRepository) GetPendingDepositsForReconciliation(ctx cont
 			&c.DepositID,
 			&c.UserID,
 			&c.TxHash,
+			&c.LogIndex,
 			&c.Chain,
 			&c.Token,
 			&c.Amount,
@@ -370,6 +492,7 @@ func (r *FundingEventJobRepository) scanJob(scanner interface {
 	err := scanner.Scan(
 		&job.ID,
 		&job.TxHash,
+		&job.LogIndex,
 		&chain,
 		&token,
 		&job.Amount,
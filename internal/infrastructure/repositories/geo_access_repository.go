@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// GeoAccessRepository persists signup and login geo data and the admin
+// override list
+type GeoAccessRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewGeoAccessRepository creates a new geo access repository
+func NewGeoAccessRepository(db *sql.DB, logger *zap.Logger) *GeoAccessRepository {
+	return &GeoAccessRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const geoAccessEventColumns = `
+	id, user_id, event_type, ip_address, country_code, region, city, isp,
+	is_vpn, is_proxy, is_tor, is_hosting, risk_score, flagged, blocked, overridden, created_at`
+
+// RecordEvent stores a signup or login lookup
+func (r *GeoAccessRepository) RecordEvent(ctx context.Context, event *entities.GeoAccessEvent) error {
+	query := `
+		INSERT INTO geo_access_events (` + geoAccessEventColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID,
+		event.UserID,
+		event.EventType,
+		event.IPAddress,
+		nullString(event.CountryCode),
+		nullString(event.Region),
+		nullString(event.City),
+		nullString(event.ISP),
+		event.IsVPN,
+		event.IsProxy,
+		event.IsTor,
+		event.IsHosting,
+		event.RiskScore,
+		event.Flagged,
+		event.Blocked,
+		event.Overridden,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record geo access event: %w", err)
+	}
+	return nil
+}
+
+// LatestFlaggedLogin returns the user's most recent flagged login since the
+// given time, or nil when there is none
+func (r *GeoAccessRepository) LatestFlaggedLogin(ctx context.Context, userID uuid.UUID, since time.Time) (*entities.GeoAccessEvent, error) {
+	query := `
+		SELECT ` + geoAccessEventColumns + `
+		FROM geo_access_events
+		WHERE user_id = $1 AND event_type = 'login' AND flagged AND created_at >= $2
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	event, err := scanGeoAccessEvent(r.db.QueryRowContext(ctx, query, userID, since))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flagged login: %w", err)
+	}
+	return event, nil
+}
+
+// ListEventsByUser returns the user's most recent signup and login events
+func (r *GeoAccessRepository) ListEventsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.GeoAccessEvent, error) {
+	query := `
+		SELECT ` + geoAccessEventColumns + `
+		FROM geo_access_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list geo access events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*entities.GeoAccessEvent
+	for rows.Next() {
+		event, err := scanGeoAccessEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan geo access event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate geo access events: %w", err)
+	}
+	return events, nil
+}
+
+// ListOverrides returns every override, newest first
+func (r *GeoAccessRepository) ListOverrides(ctx context.Context) ([]*entities.GeoOverride, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, kind, value, reason, created_by, expires_at, created_at
+		FROM geo_overrides
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list geo overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*entities.GeoOverride
+	for rows.Next() {
+		override := &entities.GeoOverride{}
+		if err := rows.Scan(
+			&override.ID,
+			&override.Kind,
+			&override.Value,
+			&override.Reason,
+			&override.CreatedBy,
+			&override.ExpiresAt,
+			&override.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan geo override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate geo overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// UpsertOverride creates an override, replacing the reason and expiry of an
+// existing override for the same address or user
+func (r *GeoAccessRepository) UpsertOverride(ctx context.Context, override *entities.GeoOverride) error {
+	query := `
+		INSERT INTO geo_overrides (id, kind, value, reason, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (kind, value) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			created_by = EXCLUDED.created_by,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		override.ID,
+		override.Kind,
+		override.Value,
+		override.Reason,
+		override.CreatedBy,
+		override.ExpiresAt,
+		override.CreatedAt,
+	).Scan(&override.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save geo override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride removes an override and reports whether it existed
+func (r *GeoAccessRepository) DeleteOverride(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM geo_overrides WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete geo override: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
+type geoAccessEventScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGeoAccessEvent(row geoAccessEventScanner) (*entities.GeoAccessEvent, error) {
+	event := &entities.GeoAccessEvent{}
+	var countryCode, region, city, isp sql.NullString
+
+	if err := row.Scan(
+		&event.ID,
+		&event.UserID,
+		&event.EventType,
+		&event.IPAddress,
+		&countryCode,
+		&region,
+		&city,
+		&isp,
+		&event.IsVPN,
+		&event.IsProxy,
+		&event.IsTor,
+		&event.IsHosting,
+		&event.RiskScore,
+		&event.Flagged,
+		&event.Blocked,
+		&event.Overridden,
+		&event.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	event.CountryCode = countryCode.String
+	event.Region = region.String
+	event.City = city.String
+	event.ISP = isp.String
+	return event, nil
+}
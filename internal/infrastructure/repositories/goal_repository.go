@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// GoalRepository persists investment goals
+type GoalRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewGoalRepository creates a new goal repository
+func NewGoalRepository(db *sql.DB, logger *zap.Logger) *GoalRepository {
+	return &GoalRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const goalColumns = `
+	id, user_id, name, target_amount, target_date, risk_level, baseline_value, baseline_at,
+	planned_monthly, status, last_nudged_at, achieved_at, created_at, updated_at`
+
+// Create stores a new goal
+func (r *GoalRepository) Create(ctx context.Context, goal *entities.Goal) error {
+	query := `
+		INSERT INTO investment_goals (` + goalColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		goal.ID, goal.UserID, goal.Name, goal.TargetAmount, goal.TargetDate, goal.RiskLevel,
+		goal.BaselineValue, goal.BaselineAt, goal.PlannedMonthly, goal.Status,
+		goal.LastNudgedAt, goal.AchievedAt, goal.CreatedAt, goal.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create goal", zap.Error(err),
+			zap.String("user_id", goal.UserID.String()))
+		return fmt.Errorf("failed to create goal: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a goal
+func (r *GoalRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM investment_goals WHERE id = $1`
+
+	goal, err := scanGoal(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrGoalNotFound
+		}
+		return nil, fmt.Errorf("failed to get goal: %w", err)
+	}
+	return goal, nil
+}
+
+// ListByUser returns the user's goals, newest first
+func (r *GoalRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM investment_goals
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	return r.list(ctx, query, userID)
+}
+
+// ListActive pages through active goals ordered by ID
+func (r *GoalRepository) ListActive(ctx context.Context, afterID uuid.UUID, limit int) ([]*entities.Goal, error) {
+	query := `
+		SELECT ` + goalColumns + `
+		FROM investment_goals
+		WHERE status = 'active' AND id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	return r.list(ctx, query, afterID, limit)
+}
+
+// Update saves a goal's plan, status and nudge time
+func (r *GoalRepository) Update(ctx context.Context, goal *entities.Goal) error {
+	query := `
+		UPDATE investment_goals
+		SET name = $2, target_amount = $3, target_date = $4, risk_level = $5,
+			baseline_value = $6, baseline_at = $7, planned_monthly = $8, status = $9,
+			last_nudged_at = $10, achieved_at = $11, updated_at = $12
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		goal.ID, goal.Name, goal.TargetAmount, goal.TargetDate, goal.RiskLevel,
+		goal.BaselineValue, goal.BaselineAt, goal.PlannedMonthly, goal.Status,
+		goal.LastNudgedAt, goal.AchievedAt, goal.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to update goal", zap.Error(err),
+			zap.String("goal_id", goal.ID.String()))
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return entities.ErrGoalNotFound
+	}
+	return nil
+}
+
+func (r *GoalRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entities.Goal, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []*entities.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate goals: %w", err)
+	}
+	return goals, nil
+}
+
+type goalScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGoal(row goalScanner) (*entities.Goal, error) {
+	goal := &entities.Goal{}
+	var lastNudgedAt, achievedAt sql.NullTime
+	if err := row.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.Name,
+		&goal.TargetAmount,
+		&goal.TargetDate,
+		&goal.RiskLevel,
+		&goal.BaselineValue,
+		&goal.BaselineAt,
+		&goal.PlannedMonthly,
+		&goal.Status,
+		&lastNudgedAt,
+		&achievedAt,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastNudgedAt.Valid {
+		goal.LastNudgedAt = &lastNudgedAt.Time
+	}
+	if achievedAt.Valid {
+		goal.AchievedAt = &achievedAt.Time
+	}
+	return goal, nil
+}
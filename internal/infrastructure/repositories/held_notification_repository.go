@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// HeldNotificationRepository persists notifications held for quiet hours or
+// the daily digest
+type HeldNotificationRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewHeldNotificationRepository creates a new held notification repository
+func NewHeldNotificationRepository(db *sql.DB, logger *zap.Logger) *HeldNotificationRepository {
+	return &HeldNotificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Hold stores a notification until its delivery time
+func (r *HeldNotificationRepository) Hold(ctx context.Context, held *entities.HeldNotification) error {
+	payload, err := json.Marshal(held.Notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode held notification: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO held_notifications (id, user_id, reason, notification, deliver_after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		held.ID, held.UserID, string(held.Reason), payload, held.DeliverAfter, held.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to hold notification", zap.Error(err), zap.String("user_id", held.UserID.String()))
+		return fmt.Errorf("failed to hold notification: %w", err)
+	}
+	return nil
+}
+
+// ListDue returns notifications whose delivery time has passed, oldest
+// first, grouped by user
+func (r *HeldNotificationRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*entities.HeldNotification, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, reason, notification, deliver_after, created_at
+		FROM held_notifications
+		WHERE deliver_after <= $1
+		ORDER BY user_id, created_at
+		LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list held notifications: %w", err)
+	}
+	defer rows.Close()
+
+	held := []*entities.HeldNotification{}
+	for rows.Next() {
+		item := &entities.HeldNotification{}
+		var reason string
+		var payload []byte
+		if err := rows.Scan(&item.ID, &item.UserID, &reason, &payload, &item.DeliverAfter, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan held notification: %w", err)
+		}
+		item.Reason = entities.NotificationHoldReason(reason)
+		if err := json.Unmarshal(payload, &item.Notification); err != nil {
+			return nil, fmt.Errorf("failed to decode held notification %s: %w", item.ID, err)
+		}
+		held = append(held, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate held notifications: %w", err)
+	}
+	return held, nil
+}
+
+// Delete removes delivered notifications
+func (r *HeldNotificationRepository) Delete(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM held_notifications WHERE id = ANY($1::uuid[])`, pq.Array(values)); err != nil {
+		return fmt.Errorf("failed to delete held notifications: %w", err)
+	}
+	return nil
+}
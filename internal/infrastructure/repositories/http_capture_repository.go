@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// HTTPCaptureRepository stores debug captures and the targets that trigger
+// them. Expired rows are invisible here and purged by the retention engine.
+type HTTPCaptureRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewHTTPCaptureRepository creates a new HTTP capture repository
+func NewHTTPCaptureRepository(db *sql.DB, logger *zap.Logger) *HTTPCaptureRepository {
+	return &HTTPCaptureRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const httpCaptureColumns = `
+	id, request_id, user_id, method, route, path, query, status_code, duration_ms,
+	client_ip, user_agent, request_headers, request_body, response_body, truncated,
+	reason, created_at, expires_at`
+
+// SaveCapture inserts a capture
+func (r *HTTPCaptureRepository) SaveCapture(ctx context.Context, capture *entities.HTTPCapture) error {
+	headers, err := json.Marshal(capture.RequestHeaders)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capture headers: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO http_captures (`+httpCaptureColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		capture.ID, nullString(capture.RequestID), capture.UserID, capture.Method, capture.Route, capture.Path,
+		nullString(capture.Query), capture.StatusCode, capture.DurationMs, nullString(capture.ClientIP),
+		nullString(capture.UserAgent), headers, nullString(capture.RequestBody), nullString(capture.ResponseBody),
+		capture.Truncated, string(capture.Reason), capture.CreatedAt, capture.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save http capture: %w", err)
+	}
+	return nil
+}
+
+// GetCapture returns an unexpired capture, or nil when there is none
+func (r *HTTPCaptureRepository) GetCapture(ctx context.Context, id uuid.UUID) (*entities.HTTPCapture, error) {
+	capture, err := scanHTTPCapture(r.db.QueryRowContext(ctx,
+		`SELECT `+httpCaptureColumns+` FROM http_captures WHERE id = $1 AND expires_at > NOW()`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get http capture: %w", err)
+	}
+	return capture, nil
+}
+
+// ListCaptures returns unexpired captures matching the filter, newest first
+func (r *HTTPCaptureRepository) ListCaptures(ctx context.Context, filter entities.HTTPCaptureFilter) ([]*entities.HTTPCapture, error) {
+	query := `
+		SELECT ` + httpCaptureColumns + `
+		FROM http_captures
+		WHERE expires_at > NOW()
+			AND ($1::uuid IS NULL OR user_id = $1)
+			AND ($2 = '' OR route = $2)
+			AND ($3 = '' OR request_id = $3)
+			AND ($4 = 0 OR status_code = $4)
+		ORDER BY created_at DESC
+		LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.UserID, filter.Route, filter.RequestID, filter.StatusCode, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list http captures: %w", err)
+	}
+	defer rows.Close()
+
+	var captures []*entities.HTTPCapture
+	for rows.Next() {
+		capture, err := scanHTTPCapture(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan http capture: %w", err)
+		}
+		captures = append(captures, capture)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate http captures: %w", err)
+	}
+	return captures, nil
+}
+
+// CreateTarget inserts a capture target
+func (r *HTTPCaptureRepository) CreateTarget(ctx context.Context, target *entities.HTTPCaptureTarget) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO http_capture_targets (id, user_id, route, note, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		target.ID, target.UserID, nullString(target.Route), target.Note, target.CreatedBy, target.CreatedAt, target.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create http capture target: %w", err)
+	}
+	return nil
+}
+
+// DeleteTarget removes a capture target, reporting whether it existed
+func (r *HTTPCaptureRepository) DeleteTarget(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM http_capture_targets WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete http capture target: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// ListActiveTargets returns unexpired capture targets, newest first
+func (r *HTTPCaptureRepository) ListActiveTargets(ctx context.Context) ([]*entities.HTTPCaptureTarget, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, route, note, created_by, created_at, expires_at
+		FROM http_capture_targets
+		WHERE expires_at > NOW()
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list http capture targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []*entities.HTTPCaptureTarget
+	for rows.Next() {
+		target := &entities.HTTPCaptureTarget{}
+		var userID uuid.NullUUID
+		var route sql.NullString
+		if err := rows.Scan(&target.ID, &userID, &route, &target.Note, &target.CreatedBy, &target.CreatedAt, &target.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan http capture target: %w", err)
+		}
+		if userID.Valid {
+			target.UserID = &userID.UUID
+		}
+		target.Route = route.String
+		targets = append(targets, target)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate http capture targets: %w", err)
+	}
+	return targets, nil
+}
+
+type httpCaptureScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanHTTPCapture(row httpCaptureScanner) (*entities.HTTPCapture, error) {
+	capture := &entities.HTTPCapture{}
+	var userID uuid.NullUUID
+	var requestID, query, clientIP, userAgent, requestBody, responseBody sql.NullString
+	var headers []byte
+	var reason string
+
+	if err := row.Scan(
+		&capture.ID, &requestID, &userID, &capture.Method, &capture.Route, &capture.Path, &query,
+		&capture.StatusCode, &capture.DurationMs, &clientIP, &userAgent, &headers, &requestBody,
+		&responseBody, &capture.Truncated, &reason, &capture.CreatedAt, &capture.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if userID.Valid {
+		capture.UserID = &userID.UUID
+	}
+	capture.RequestID = requestID.String
+	capture.Query = query.String
+	capture.ClientIP = clientIP.String
+	capture.UserAgent = userAgent.String
+	capture.RequestBody = requestBody.String
+	capture.ResponseBody = responseBody.String
+	capture.Reason = entities.HTTPCaptureReason(reason)
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &capture.RequestHeaders); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal capture headers: %w", err)
+		}
+	}
+	return capture, nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// InstantFundingAdvanceRepository persists buying power advanced against
+// pending deposits
+type InstantFundingAdvanceRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewInstantFundingAdvanceRepository creates a new instant funding advance repository
+func NewInstantFundingAdvanceRepository(db *sql.DB, logger *zap.Logger) *InstantFundingAdvanceRepository {
+	return &InstantFundingAdvanceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const instantFundingAdvanceColumns = `
+	id, deposit_id, user_id, amount, status, alpaca_transfer_id, shortfall,
+	failure_reason, created_at, settled_at`
+
+// Create records an advance unless its deposit already has one
+func (r *InstantFundingAdvanceRepository) Create(ctx context.Context, advance *entities.InstantFundingAdvance) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO instant_funding_advances (id, deposit_id, user_id, amount, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (deposit_id) DO NOTHING`,
+		advance.ID, advance.DepositID, advance.UserID, advance.Amount, string(advance.Status), advance.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create instant funding advance", zap.Error(err),
+			zap.String("deposit_id", advance.DepositID.String()))
+		return false, fmt.Errorf("failed to create instant funding advance: %w", err)
+	}
+	created, _ := result.RowsAffected()
+	return created > 0, nil
+}
+
+// GetByDepositID retrieves the advance made against a deposit
+func (r *InstantFundingAdvanceRepository) GetByDepositID(ctx context.Context, depositID uuid.UUID) (*entities.InstantFundingAdvance, error) {
+	advance, err := scanInstantFundingAdvance(r.db.QueryRowContext(ctx,
+		`SELECT `+instantFundingAdvanceColumns+` FROM instant_funding_advances WHERE deposit_id = $1`, depositID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrInstantAdvanceNotFound
+		}
+		return nil, fmt.Errorf("failed to get instant funding advance: %w", err)
+	}
+	return advance, nil
+}
+
+// Transition saves the advance's status and outcome if it is still in status
+// from, so concurrent credits and reversals settle it once
+func (r *InstantFundingAdvanceRepository) Transition(ctx context.Context, advance *entities.InstantFundingAdvance, from entities.InstantAdvanceStatus) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE instant_funding_advances SET
+			status = $3, alpaca_transfer_id = $4, shortfall = $5, failure_reason = $6, settled_at = $7
+		WHERE id = $1 AND status = $2`,
+		advance.ID, string(from), string(advance.Status), advance.AlpacaTransferID, advance.Shortfall,
+		advance.FailureReason, advance.SettledAt)
+	if err != nil {
+		r.logger.Error("Failed to update instant funding advance", zap.Error(err),
+			zap.String("advance_id", advance.ID.String()),
+			zap.String("status", string(advance.Status)))
+		return false, fmt.Errorf("failed to update instant funding advance: %w", err)
+	}
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+func scanInstantFundingAdvance(row adminCaseScanner) (*entities.InstantFundingAdvance, error) {
+	advance := &entities.InstantFundingAdvance{}
+	var status string
+	var transferID, failureReason sql.NullString
+	var settledAt sql.NullTime
+
+	if err := row.Scan(
+		&advance.ID,
+		&advance.DepositID,
+		&advance.UserID,
+		&advance.Amount,
+		&status,
+		&transferID,
+		&advance.Shortfall,
+		&failureReason,
+		&advance.CreatedAt,
+		&settledAt,
+	); err != nil {
+		return nil, err
+	}
+
+	advance.Status = entities.InstantAdvanceStatus(status)
+	if transferID.Valid {
+		advance.AlpacaTransferID = &transferID.String
+	}
+	if failureReason.Valid {
+		advance.FailureReason = &failureReason.String
+	}
+	if settledAt.Valid {
+		advance.SettledAt = &settledAt.Time
+	}
+	return advance, nil
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// IntegrityRepository runs the integrity checker's queries and stores its runs
+type IntegrityRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewIntegrityRepository creates a new integrity repository
+func NewIntegrityRepository(db *sql.DB, logger *zap.Logger) *IntegrityRepository {
+	return &IntegrityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Each check selects (user id, subject type, subject id, since) for records
+// inconsistent since before $1, limited to $2
+var integrityCheckQueries = map[entities.IntegrityAnomalyClass]string{
+	entities.IntegrityCompletedWithoutWallet: `
+		SELECT u.id, 'user', u.id::text, COALESCE(u.updated_at, u.created_at)
+		FROM users u
+		WHERE u.onboarding_status = 'completed'
+			AND COALESCE(u.updated_at, u.created_at) < $1
+			AND NOT EXISTS (SELECT 1 FROM managed_wallets w WHERE w.user_id = u.id AND w.status = 'live')
+		ORDER BY COALESCE(u.updated_at, u.created_at), u.id
+		LIMIT $2`,
+	entities.IntegrityOrderWithoutLedger: `
+		SELECT o.user_id, 'order', o.id::text, o.updated_at
+		FROM orders o
+		WHERE o.status = 'filled' AND NOT o.paper
+			AND o.updated_at < $1
+			AND NOT EXISTS (
+				SELECT 1 FROM ledger_transactions lt
+				WHERE lt.reference_id = o.id AND lt.reference_type = 'order'
+			)
+		ORDER BY o.updated_at, o.id
+		LIMIT $2`,
+	entities.IntegrityKYCApprovedStuck: `
+		SELECT u.id, 'user', u.id::text, COALESCE(u.kyc_approved_at, u.updated_at, u.created_at)
+		FROM users u
+		WHERE u.kyc_status = 'approved'
+			AND u.onboarding_status IN ('started', 'kyc_pending', 'kyc_approved')
+			AND COALESCE(u.kyc_approved_at, u.updated_at, u.created_at) < $1
+		ORDER BY COALESCE(u.kyc_approved_at, u.updated_at, u.created_at), u.id
+		LIMIT $2`,
+	entities.IntegrityWalletWithoutWalletSet: `
+		SELECT w.user_id, 'wallet', w.id::text, COALESCE(w.updated_at, w.created_at)
+		FROM managed_wallets w
+		LEFT JOIN wallet_sets ws ON ws.id = w.wallet_set_id
+		WHERE w.status = 'live'
+			AND (ws.id IS NULL OR ws.status <> 'active')
+			AND COALESCE(w.updated_at, w.created_at) < $1
+		ORDER BY COALESCE(w.updated_at, w.created_at), w.id
+		LIMIT $2`,
+}
+
+// FindAnomalies runs the class's check
+func (r *IntegrityRepository) FindAnomalies(ctx context.Context, class entities.IntegrityAnomalyClass, before time.Time, limit int) ([]*entities.IntegrityAnomaly, error) {
+	query, ok := integrityCheckQueries[class]
+	if !ok {
+		return nil, fmt.Errorf("unknown integrity check %q", class)
+	}
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s check: %w", class, err)
+	}
+	defer rows.Close()
+
+	var anomalies []*entities.IntegrityAnomaly
+	for rows.Next() {
+		anomaly := &entities.IntegrityAnomaly{Class: class}
+		if err := rows.Scan(&anomaly.UserID, &anomaly.SubjectType, &anomaly.SubjectID, &anomaly.Since); err != nil {
+			return nil, fmt.Errorf("failed to scan %s anomaly: %w", class, err)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate %s anomalies: %w", class, err)
+	}
+	return anomalies, nil
+}
+
+// StartRun records a run, claiming its date if it is scheduled
+func (r *IntegrityRepository) StartRun(ctx context.Context, report *entities.IntegrityReport) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO integrity_runs (id, run_date, trigger, triggered_by, started_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (run_date) DO NOTHING`,
+		report.ID, report.RunDate, report.Trigger, report.TriggeredBy, report.StartedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to start integrity run: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// FinishRun stores the run's report
+func (r *IntegrityRepository) FinishRun(ctx context.Context, report *entities.IntegrityReport) error {
+	checks, err := json.Marshal(report.Checks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal integrity report: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE integrity_runs
+		SET anomalies = $2, report = $3, error_message = $4, finished_at = $5
+		WHERE id = $1`,
+		report.ID, report.Anomalies, checks, report.Error, report.FinishedAt)
+	if err != nil {
+		r.logger.Error("Failed to record integrity run", zap.Error(err), zap.String("run_id", report.ID.String()))
+		return fmt.Errorf("failed to record integrity run: %w", err)
+	}
+	return nil
+}
+
+const integrityRunColumns = `id, run_date, trigger, triggered_by, anomalies, report, error_message, started_at, finished_at`
+
+// GetRun returns a run with its full report
+func (r *IntegrityRepository) GetRun(ctx context.Context, id uuid.UUID) (*entities.IntegrityReport, error) {
+	report, err := scanIntegrityRun(r.db.QueryRowContext(ctx, `
+		SELECT `+integrityRunColumns+` FROM integrity_runs WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrIntegrityRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get integrity run: %w", err)
+	}
+	return report, nil
+}
+
+// ListRuns returns the most recent runs, newest first
+func (r *IntegrityRepository) ListRuns(ctx context.Context, limit int) ([]*entities.IntegrityReport, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+integrityRunColumns+` FROM integrity_runs
+		ORDER BY started_at DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list integrity runs: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*entities.IntegrityReport
+	for rows.Next() {
+		report, err := scanIntegrityRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan integrity run: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+type integrityRunScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanIntegrityRun(row integrityRunScanner) (*entities.IntegrityReport, error) {
+	var report entities.IntegrityReport
+	var runDate sql.NullTime
+	var triggeredBy uuid.NullUUID
+	var checks []byte
+	var errorMessage sql.NullString
+	var finishedAt sql.NullTime
+	if err := row.Scan(&report.ID, &runDate, &report.Trigger, &triggeredBy, &report.Anomalies,
+		&checks, &errorMessage, &report.StartedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	if runDate.Valid {
+		date := runDate.Time.Format("2006-01-02")
+		report.RunDate = &date
+	}
+	if triggeredBy.Valid {
+		report.TriggeredBy = &triggeredBy.UUID
+	}
+	if len(checks) > 0 {
+		if err := json.Unmarshal(checks, &report.Checks); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal integrity report: %w", err)
+		}
+	}
+	if errorMessage.Valid {
+		report.Error = &errorMessage.String
+	}
+	if finishedAt.Valid {
+		report.FinishedAt = &finishedAt.Time
+	}
+	return &report, nil
+}
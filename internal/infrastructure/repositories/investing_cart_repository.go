@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// InvestingCartRepository persists the basket buys users queue for checkout
+type InvestingCartRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewInvestingCartRepository creates a new investing cart repository
+func NewInvestingCartRepository(db *sql.DB, logger *zap.Logger) *InvestingCartRepository {
+	return &InvestingCartRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListItems returns a user's cart in the order baskets were added
+func (r *InvestingCartRepository) ListItems(ctx context.Context, userID uuid.UUID) ([]*entities.CartItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT basket_id, amount, created_at, updated_at
+		FROM investing_cart_items
+		WHERE user_id = $1
+		ORDER BY created_at ASC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cart items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*entities.CartItem{}
+	for rows.Next() {
+		item := &entities.CartItem{}
+		if err := rows.Scan(&item.BasketID, &item.Amount, &item.AddedAt, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cart items: %w", err)
+	}
+	return items, nil
+}
+
+// UpsertItem adds a basket to the cart or replaces its amount
+func (r *InvestingCartRepository) UpsertItem(ctx context.Context, userID, basketID uuid.UUID, amount decimal.Decimal) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO investing_cart_items (user_id, basket_id, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, basket_id) DO UPDATE SET amount = EXCLUDED.amount, updated_at = NOW()`,
+		userID, basketID, amount)
+	if err != nil {
+		r.logger.Error("Failed to upsert cart item", zap.Error(err),
+			zap.String("user_id", userID.String()), zap.String("basket_id", basketID.String()))
+		return fmt.Errorf("failed to upsert cart item: %w", err)
+	}
+	return nil
+}
+
+// RemoveItem deletes a basket from the cart, reporting whether it was there
+func (r *InvestingCartRepository) RemoveItem(ctx context.Context, userID, basketID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM investing_cart_items WHERE user_id = $1 AND basket_id = $2`, userID, basketID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove cart item: %w", err)
+	}
+	removed, _ := result.RowsAffected()
+	return removed > 0, nil
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// JurisdictionRepository persists per-country regulatory rules
+type JurisdictionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewJurisdictionRepository creates a new jurisdiction repository
+func NewJurisdictionRepository(db *sql.DB, logger *zap.Logger) *JurisdictionRepository {
+	return &JurisdictionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListRules returns every country rule, including the default rule
+func (r *JurisdictionRepository) ListRules(ctx context.Context) ([]*entities.CountryRule, error) {
+	query := `
+		SELECT country_code, onboarding_allowed, allowed_features, required_disclosures,
+		       kyc_level, notes, updated_by, created_at, updated_at
+		FROM country_rules
+		ORDER BY country_code`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list country rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*entities.CountryRule
+	for rows.Next() {
+		rule := &entities.CountryRule{}
+		var features, disclosures pq.StringArray
+		if err := rows.Scan(
+			&rule.CountryCode,
+			&rule.OnboardingAllowed,
+			&features,
+			&disclosures,
+			&rule.KYCLevel,
+			&rule.Notes,
+			&rule.UpdatedBy,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan country rule: %w", err)
+		}
+		rule.AllowedFeatures = make([]entities.JurisdictionFeature, 0, len(features))
+		for _, f := range features {
+			rule.AllowedFeatures = append(rule.AllowedFeatures, entities.JurisdictionFeature(f))
+		}
+		rule.RequiredDisclosures = []string(disclosures)
+		if rule.RequiredDisclosures == nil {
+			rule.RequiredDisclosures = []string{}
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate country rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpsertRule creates or replaces the rule for a country
+func (r *JurisdictionRepository) UpsertRule(ctx context.Context, rule *entities.CountryRule) error {
+	features := make([]string, 0, len(rule.AllowedFeatures))
+	for _, f := range rule.AllowedFeatures {
+		features = append(features, string(f))
+	}
+
+	query := `
+		INSERT INTO country_rules (
+			country_code, onboarding_allowed, allowed_features, required_disclosures,
+			kyc_level, notes, updated_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (country_code) DO UPDATE SET
+			onboarding_allowed = EXCLUDED.onboarding_allowed,
+			allowed_features = EXCLUDED.allowed_features,
+			required_disclosures = EXCLUDED.required_disclosures,
+			kyc_level = EXCLUDED.kyc_level,
+			notes = EXCLUDED.notes,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.CountryCode,
+		rule.OnboardingAllowed,
+		pq.Array(features),
+		pq.Array(rule.RequiredDisclosures),
+		string(rule.KYCLevel),
+		rule.Notes,
+		rule.UpdatedBy,
+		rule.UpdatedAt,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to upsert country rule", zap.Error(err), zap.String("country_code", rule.CountryCode))
+		return fmt.Errorf("failed to upsert country rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule removes a country rule so the default rule applies again
+func (r *JurisdictionRepository) DeleteRule(ctx context.Context, countryCode string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM country_rules WHERE country_code = $1`, countryCode)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete country rule: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// GetUserCountry returns the country recorded for a user, or an empty string
+func (r *JurisdictionRepository) GetUserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	var country sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT country FROM users WHERE id = $1`, userID).Scan(&country)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("failed to get user country: %w", err)
+	}
+	return country.String, nil
+}
+
+// SetUserCountry records the country a user onboarded from
+func (r *JurisdictionRepository) SetUserCountry(ctx context.Context, userID uuid.UUID, countryCode string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET country = $2, updated_at = $3 WHERE id = $1`,
+		userID, countryCode, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set user country: %w", err)
+	}
+	return nil
+}
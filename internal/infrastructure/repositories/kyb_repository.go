@@ -0,0 +1,489 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// KYBRepository persists business verification applications, their
+// beneficial owners and the account type of users
+type KYBRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+	fields fieldCipher
+}
+
+// NewKYBRepository creates a new business verification repository
+func NewKYBRepository(db *sql.DB, logger *zap.Logger) *KYBRepository {
+	return &KYBRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetFieldEncryptor enables encryption of company tax IDs and beneficial
+// owners' KYC provider references at rest
+func (r *KYBRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+// RotateFieldEncryption re-encrypts company tax IDs under the active key version
+func (r *KYBRepository) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, r.db, r.fields.enc, "kyb_applications", []encryptedColumn{
+		{name: "tax_id"},
+	}, batchSize)
+}
+
+// OwnerFields returns the rotator for beneficial owner columns, which are
+// kept in their own table and reported separately
+func (r *KYBRepository) OwnerFields() *KYBOwnerFields {
+	return &KYBOwnerFields{repo: r}
+}
+
+// KYBOwnerFields rotates the encrypted columns of beneficial owners
+type KYBOwnerFields struct {
+	repo *KYBRepository
+}
+
+// RotateFieldEncryption re-encrypts owners' KYC provider references under the
+// active key version
+func (f *KYBOwnerFields) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, f.repo.db, f.repo.fields.enc, "kyb_beneficial_owners", []encryptedColumn{
+		{name: "kyc_provider_ref"},
+	}, batchSize)
+}
+
+// Encrypted KYB columns
+var (
+	kybTaxIDField    = fieldColumn{table: "kyb_applications", column: "tax_id"}
+	kybOwnerRefField = fieldColumn{table: "kyb_beneficial_owners", column: "kyc_provider_ref"}
+)
+
+const kybApplicationColumns = `
+	id, user_id, status, legal_name, trade_name, entity_type, registration_number,
+	tax_id, tax_id_last4, incorporation_country, incorporation_state, incorporation_date,
+	address, website, business_activity, documents, case_id, rejection_reason,
+	reviewed_by, review_notes, reviewed_at, submitted_at, created_at, updated_at`
+
+const beneficialOwnerColumns = `
+	id, application_id, first_name, last_name, email, title, ownership_percent, is_controller,
+	country, documents, kyc_status, kyc_provider_ref, kyc_checked_at, created_at, updated_at`
+
+// GetAccountType returns whether the user holds a personal or business account
+func (r *KYBRepository) GetAccountType(ctx context.Context, userID uuid.UUID) (entities.UserAccountType, error) {
+	var accountType string
+	err := r.db.QueryRowContext(ctx, `SELECT account_type FROM users WHERE id = $1`, userID).Scan(&accountType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("failed to get account type: %w", err)
+	}
+	return entities.UserAccountType(accountType), nil
+}
+
+// ApplyBusinessAccount marks the user as a business and replaces the
+// matching transaction limits in one transaction. Usage already counted in
+// the current period is kept.
+func (r *KYBRepository) ApplyBusinessAccount(ctx context.Context, userID uuid.UUID, limits []entities.TierLimit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `UPDATE users SET account_type = $2, updated_at = $3 WHERE id = $1`,
+		userID, string(entities.UserAccountBusiness), now)
+	if err != nil {
+		r.logger.Error("Failed to set account type", zap.Error(err), zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to set account type: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	for _, limit := range limits {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO transaction_limits (user_id, limit_type, period, max_amount, used_amount, reset_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, 0, $5, $6, $6)
+			ON CONFLICT (user_id, limit_type, period) DO UPDATE SET
+				max_amount = EXCLUDED.max_amount, updated_at = EXCLUDED.updated_at`,
+			userID, string(limit.LimitType), string(limit.Period), limit.MaxAmount, nextLimitReset(limit.Period, now), now)
+		if err != nil {
+			r.logger.Error("Failed to set transaction limit", zap.Error(err),
+				zap.String("user_id", userID.String()),
+				zap.String("limit_type", string(limit.LimitType)))
+			return fmt.Errorf("failed to set transaction limit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit business account: %w", err)
+	}
+	return nil
+}
+
+// Create inserts an application unless the user already has a draft or one
+// awaiting review, in which case that one is returned with created=false
+func (r *KYBRepository) Create(ctx context.Context, application *entities.KYBApplication) (*entities.KYBApplication, bool, error) {
+	documents, err := json.Marshal(application.Documents)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal documents: %w", err)
+	}
+
+	query := `
+		INSERT INTO kyb_applications (id, user_id, status, documents, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (user_id) WHERE status IN ('draft', 'pending_review') DO NOTHING
+		RETURNING ` + kybApplicationColumns
+
+	created, err := r.scanApplication(r.db.QueryRowContext(ctx, query,
+		application.ID, application.UserID, string(application.Status), documents, application.CreatedAt))
+	if err == nil {
+		created.Owners = []*entities.BeneficialOwner{}
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to create KYB application", zap.Error(err), zap.String("user_id", application.UserID.String()))
+		return nil, false, fmt.Errorf("failed to create kyb application: %w", err)
+	}
+
+	existing, err := r.GetLatestByUser(ctx, application.UserID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load open kyb application: %w", err)
+	}
+	return existing, false, nil
+}
+
+// GetByID retrieves an application with its owners
+func (r *KYBRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.KYBApplication, error) {
+	return r.getOne(ctx, `SELECT `+kybApplicationColumns+` FROM kyb_applications WHERE id = $1`, id)
+}
+
+// GetLatestByUser retrieves the user's most recent application with its owners
+func (r *KYBRepository) GetLatestByUser(ctx context.Context, userID uuid.UUID) (*entities.KYBApplication, error) {
+	return r.getOne(ctx, `
+		SELECT `+kybApplicationColumns+` FROM kyb_applications
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1`, userID)
+}
+
+func (r *KYBRepository) getOne(ctx context.Context, query string, arg uuid.UUID) (*entities.KYBApplication, error) {
+	application, err := r.scanApplication(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrKYBApplicationNotFound
+		}
+		return nil, fmt.Errorf("failed to get kyb application: %w", err)
+	}
+	if application.Owners, err = r.listOwners(ctx, application.ID); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// List returns submitted applications filtered by optional status, oldest
+// submission first so the queue is worked in order. Drafts are listed only
+// when asked for by status.
+func (r *KYBRepository) List(ctx context.Context, status entities.KYBStatus, limit, offset int) ([]*entities.KYBApplication, error) {
+	query := `
+		SELECT ` + kybApplicationColumns + `
+		FROM kyb_applications
+		WHERE ($1 = '' AND status <> 'draft') OR status = $1
+		ORDER BY submitted_at ASC NULLS LAST, created_at ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kyb applications: %w", err)
+	}
+	defer rows.Close()
+
+	var applications []*entities.KYBApplication
+	for rows.Next() {
+		application, err := r.scanApplication(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan kyb application: %w", err)
+		}
+		applications = append(applications, application)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate kyb applications: %w", err)
+	}
+	return applications, nil
+}
+
+// Update persists the company details, documents, routing and outcome of an
+// application
+func (r *KYBRepository) Update(ctx context.Context, application *entities.KYBApplication) error {
+	address, err := json.Marshal(application.Address)
+	if err != nil {
+		return fmt.Errorf("failed to marshal address: %w", err)
+	}
+	documents, err := json.Marshal(application.Documents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal documents: %w", err)
+	}
+	taxID, err := r.fields.seal(kybTaxIDField, application.ID, application.TaxID)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt tax id: %w", err)
+	}
+
+	query := `
+		UPDATE kyb_applications SET
+			status = $2, legal_name = $3, trade_name = $4, entity_type = $5, registration_number = $6,
+			tax_id = $7, tax_id_last4 = $8, incorporation_country = $9, incorporation_state = $10,
+			incorporation_date = $11, address = $12, website = $13, business_activity = $14,
+			documents = $15, case_id = $16, rejection_reason = $17, reviewed_by = $18,
+			review_notes = $19, reviewed_at = $20, submitted_at = $21, updated_at = $22
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		application.ID, string(application.Status), application.LegalName, application.TradeName,
+		string(application.EntityType), application.RegistrationNumber, taxID, application.TaxIDLast4,
+		application.IncorporationCountry, application.IncorporationState, application.IncorporationDate,
+		address, application.Website, application.BusinessActivity, documents, application.CaseID,
+		application.RejectionReason, application.ReviewedBy, application.ReviewNotes,
+		application.ReviewedAt, application.SubmittedAt, application.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update kyb application: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrKYBApplicationNotFound
+	}
+	return nil
+}
+
+// AddOwner inserts a beneficial owner
+func (r *KYBRepository) AddOwner(ctx context.Context, owner *entities.BeneficialOwner) error {
+	documents, err := json.Marshal(owner.Documents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal documents: %w", err)
+	}
+	providerRef, err := r.fields.sealPtr(kybOwnerRefField, owner.ID, owner.KYCProviderRef)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt KYC provider reference: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO kyb_beneficial_owners (
+			id, application_id, first_name, last_name, email, title, ownership_percent, is_controller,
+			country, documents, kyc_status, kyc_provider_ref, kyc_checked_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)`,
+		owner.ID, owner.ApplicationID, owner.FirstName, owner.LastName, owner.Email, owner.Title,
+		owner.OwnershipPercent, owner.IsController, owner.Country, documents, string(owner.KYCStatus),
+		providerRef, owner.KYCCheckedAt, owner.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to add beneficial owner", zap.Error(err), zap.String("application_id", owner.ApplicationID.String()))
+		return fmt.Errorf("failed to add beneficial owner: %w", err)
+	}
+	return nil
+}
+
+// UpdateOwner records an owner's verification result
+func (r *KYBRepository) UpdateOwner(ctx context.Context, owner *entities.BeneficialOwner) error {
+	providerRef, err := r.fields.sealPtr(kybOwnerRefField, owner.ID, owner.KYCProviderRef)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt KYC provider reference: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE kyb_beneficial_owners SET
+			kyc_status = $2, kyc_provider_ref = $3, kyc_checked_at = $4, updated_at = $5
+		WHERE id = $1`,
+		owner.ID, string(owner.KYCStatus), providerRef, owner.KYCCheckedAt, owner.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update beneficial owner: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrKYBOwnerNotFound
+	}
+	return nil
+}
+
+// DeleteOwner removes an owner from an application
+func (r *KYBRepository) DeleteOwner(ctx context.Context, applicationID, ownerID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM kyb_beneficial_owners WHERE id = $1 AND application_id = $2`, ownerID, applicationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete beneficial owner: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrKYBOwnerNotFound
+	}
+	return nil
+}
+
+func (r *KYBRepository) listOwners(ctx context.Context, applicationID uuid.UUID) ([]*entities.BeneficialOwner, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+beneficialOwnerColumns+` FROM kyb_beneficial_owners
+		WHERE application_id = $1
+		ORDER BY created_at ASC`, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beneficial owners: %w", err)
+	}
+	defer rows.Close()
+
+	owners := []*entities.BeneficialOwner{}
+	for rows.Next() {
+		owner, err := r.scanBeneficialOwner(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan beneficial owner: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate beneficial owners: %w", err)
+	}
+	return owners, nil
+}
+
+type kybScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *KYBRepository) scanApplication(row kybScanner) (*entities.KYBApplication, error) {
+	application := &entities.KYBApplication{}
+	var status, entityType string
+	var address, documents []byte
+	var tradeName, incorporationState, website, rejectionReason, reviewNotes sql.NullString
+	var caseID, reviewedBy uuid.NullUUID
+	var incorporationDate, reviewedAt, submittedAt sql.NullTime
+
+	if err := row.Scan(
+		&application.ID,
+		&application.UserID,
+		&status,
+		&application.LegalName,
+		&tradeName,
+		&entityType,
+		&application.RegistrationNumber,
+		&application.TaxID,
+		&application.TaxIDLast4,
+		&application.IncorporationCountry,
+		&incorporationState,
+		&incorporationDate,
+		&address,
+		&website,
+		&application.BusinessActivity,
+		&documents,
+		&caseID,
+		&rejectionReason,
+		&reviewedBy,
+		&reviewNotes,
+		&reviewedAt,
+		&submittedAt,
+		&application.CreatedAt,
+		&application.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	application.Status = entities.KYBStatus(status)
+	application.EntityType = entities.BusinessEntityType(entityType)
+	taxID, err := r.fields.open(kybTaxIDField, application.ID, application.TaxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt tax id: %w", err)
+	}
+	application.TaxID = taxID
+	if len(address) > 0 {
+		if err := json.Unmarshal(address, &application.Address); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal address: %w", err)
+		}
+	}
+	if len(documents) > 0 {
+		if err := json.Unmarshal(documents, &application.Documents); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal documents: %w", err)
+		}
+	}
+	if tradeName.Valid {
+		application.TradeName = &tradeName.String
+	}
+	if incorporationState.Valid {
+		application.IncorporationState = &incorporationState.String
+	}
+	if incorporationDate.Valid {
+		application.IncorporationDate = &incorporationDate.Time
+	}
+	if website.Valid {
+		application.Website = &website.String
+	}
+	if caseID.Valid {
+		application.CaseID = &caseID.UUID
+	}
+	if rejectionReason.Valid {
+		application.RejectionReason = &rejectionReason.String
+	}
+	if reviewedBy.Valid {
+		application.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewNotes.Valid {
+		application.ReviewNotes = &reviewNotes.String
+	}
+	if reviewedAt.Valid {
+		application.ReviewedAt = &reviewedAt.Time
+	}
+	if submittedAt.Valid {
+		application.SubmittedAt = &submittedAt.Time
+	}
+	return application, nil
+}
+
+func (r *KYBRepository) scanBeneficialOwner(row kybScanner) (*entities.BeneficialOwner, error) {
+	owner := &entities.BeneficialOwner{}
+	var kycStatus string
+	var documents []byte
+	var title, providerRef sql.NullString
+	var checkedAt sql.NullTime
+
+	if err := row.Scan(
+		&owner.ID,
+		&owner.ApplicationID,
+		&owner.FirstName,
+		&owner.LastName,
+		&owner.Email,
+		&title,
+		&owner.OwnershipPercent,
+		&owner.IsController,
+		&owner.Country,
+		&documents,
+		&kycStatus,
+		&providerRef,
+		&checkedAt,
+		&owner.CreatedAt,
+		&owner.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	owner.KYCStatus = entities.KYCStatus(kycStatus)
+	if len(documents) > 0 {
+		if err := json.Unmarshal(documents, &owner.Documents); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal documents: %w", err)
+		}
+	}
+	if title.Valid {
+		owner.Title = &title.String
+	}
+	if providerRef.Valid {
+		owner.KYCProviderRef = &providerRef.String
+		if err := r.fields.openPtr(kybOwnerRefField, owner.ID, owner.KYCProviderRef); err != nil {
+			return nil, fmt.Errorf("failed to decrypt KYC provider reference: %w", err)
+		}
+	}
+	if checkedAt.Valid {
+		owner.KYCCheckedAt = &checkedAt.Time
+	}
+	return owner, nil
+}
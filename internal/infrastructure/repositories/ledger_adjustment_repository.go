@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// LedgerAdjustmentRepository persists manual correcting ledger entries
+type LedgerAdjustmentRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewLedgerAdjustmentRepository creates a new ledger adjustment repository
+func NewLedgerAdjustmentRepository(db *sql.DB, logger *zap.Logger) *LedgerAdjustmentRepository {
+	return &LedgerAdjustmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const ledgerAdjustmentColumns = `
+	id, user_id, account_type, offset_account_type, direction, amount, currency,
+	reason_code, memo, documents, status, requested_by, approval_request_id,
+	ledger_transaction_id, failure_reason, created_at, updated_at, posted_at`
+
+// Create inserts an adjustment
+func (r *LedgerAdjustmentRepository) Create(ctx context.Context, adjustment *entities.LedgerAdjustment) error {
+	documents, err := json.Marshal(adjustment.Documents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal supporting documents: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO ledger_adjustments (
+			id, user_id, account_type, offset_account_type, direction, amount, currency,
+			reason_code, memo, documents, status, requested_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		adjustment.ID, adjustment.UserID, string(adjustment.AccountType), string(adjustment.OffsetAccountType),
+		string(adjustment.Direction), adjustment.Amount, adjustment.Currency, string(adjustment.ReasonCode),
+		adjustment.Memo, documents, string(adjustment.Status), adjustment.RequestedBy,
+		adjustment.CreatedAt, adjustment.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create ledger adjustment", zap.Error(err),
+			zap.String("adjustment_id", adjustment.ID.String()))
+		return fmt.Errorf("failed to create ledger adjustment: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an adjustment
+func (r *LedgerAdjustmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.LedgerAdjustment, error) {
+	adjustment, err := scanLedgerAdjustment(r.db.QueryRowContext(ctx,
+		`SELECT `+ledgerAdjustmentColumns+` FROM ledger_adjustments WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrLedgerAdjustmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger adjustment: %w", err)
+	}
+	return adjustment, nil
+}
+
+// List returns adjustments filtered by optional user and status, newest first
+func (r *LedgerAdjustmentRepository) List(ctx context.Context, filter entities.LedgerAdjustmentFilter) ([]*entities.LedgerAdjustment, error) {
+	var userID uuid.NullUUID
+	if filter.UserID != nil {
+		userID = uuid.NullUUID{UUID: *filter.UserID, Valid: true}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+ledgerAdjustmentColumns+`
+		FROM ledger_adjustments
+		WHERE ($1::uuid IS NULL OR user_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`,
+		userID, string(filter.Status), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*entities.LedgerAdjustment
+	for rows.Next() {
+		adjustment, err := scanLedgerAdjustment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger adjustment: %w", err)
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ledger adjustments: %w", err)
+	}
+	return adjustments, nil
+}
+
+// SetApprovalRequest links an adjustment to the request reviewing it
+func (r *LedgerAdjustmentRepository) SetApprovalRequest(ctx context.Context, id, approvalRequestID uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE ledger_adjustments SET approval_request_id = $2, updated_at = NOW() WHERE id = $1`,
+		id, approvalRequestID); err != nil {
+		return fmt.Errorf("failed to link approval request: %w", err)
+	}
+	return nil
+}
+
+// Resolve moves a pending adjustment to its decided status, reporting whether
+// it was still pending
+func (r *LedgerAdjustmentRepository) Resolve(ctx context.Context, adjustment *entities.LedgerAdjustment) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE ledger_adjustments SET
+			status = $2, ledger_transaction_id = $3, failure_reason = $4,
+			posted_at = $5, updated_at = $6
+		WHERE id = $1 AND status = 'pending_approval'`,
+		adjustment.ID, string(adjustment.Status), adjustment.LedgerTransactionID,
+		adjustment.FailureReason, adjustment.PostedAt, adjustment.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve ledger adjustment: %w", err)
+	}
+	resolved, _ := result.RowsAffected()
+	return resolved > 0, nil
+}
+
+func scanLedgerAdjustment(row adminCaseScanner) (*entities.LedgerAdjustment, error) {
+	adjustment := &entities.LedgerAdjustment{}
+	var accountType, offsetType, direction, reasonCode, status string
+	var documents []byte
+	var approvalRequestID, ledgerTxID uuid.NullUUID
+	var failureReason sql.NullString
+	var postedAt sql.NullTime
+
+	if err := row.Scan(
+		&adjustment.ID,
+		&adjustment.UserID,
+		&accountType,
+		&offsetType,
+		&direction,
+		&adjustment.Amount,
+		&adjustment.Currency,
+		&reasonCode,
+		&adjustment.Memo,
+		&documents,
+		&status,
+		&adjustment.RequestedBy,
+		&approvalRequestID,
+		&ledgerTxID,
+		&failureReason,
+		&adjustment.CreatedAt,
+		&adjustment.UpdatedAt,
+		&postedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	adjustment.AccountType = entities.AccountType(accountType)
+	adjustment.OffsetAccountType = entities.AccountType(offsetType)
+	adjustment.Direction = entities.AdjustmentDirection(direction)
+	adjustment.ReasonCode = entities.AdjustmentReasonCode(reasonCode)
+	adjustment.Status = entities.LedgerAdjustmentStatus(status)
+	if err := json.Unmarshal(documents, &adjustment.Documents); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal supporting documents: %w", err)
+	}
+	if approvalRequestID.Valid {
+		adjustment.ApprovalRequestID = &approvalRequestID.UUID
+	}
+	if ledgerTxID.Valid {
+		adjustment.LedgerTransactionID = &ledgerTxID.UUID
+	}
+	if failureReason.Valid {
+		adjustment.FailureReason = &failureReason.String
+	}
+	if postedAt.Valid {
+		adjustment.PostedAt = &postedAt.Time
+	}
+	return adjustment, nil
+}
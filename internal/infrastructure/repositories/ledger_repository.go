@@ -24,6 +24,35 @@ func NewLedgerRepository(db *sqlx.DB) *LedgerRepository {
 	return &LedgerRepository{db: db}
 }
 
+// ledgerQuerier is the part of sqlx.DB and sqlx.Tx the repository queries through
+type ledgerQuerier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+type ledgerTxKey struct{}
+
+// WithLedgerTx returns a context whose ledger queries run in tx, so the
+// ledger service can make several repository calls one atomic unit
+func WithLedgerTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, ledgerTxKey{}, tx)
+}
+
+// LedgerTxFromContext returns the transaction set by WithLedgerTx, if any
+func LedgerTxFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(ledgerTxKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// conn returns the transaction carried by ctx, or the pool outside one
+func (r *LedgerRepository) conn(ctx context.Context) ledgerQuerier {
+	if tx, ok := LedgerTxFromContext(ctx); ok {
+		return tx
+	}
+	return r.db
+}
+
 // ===== Account Operations =====
 
 // CreateAccount creates a new ledger account
@@ -42,7 +71,7 @@ func (r *LedgerRepository) CreateAccount(ctx context.Context, account *entities.
 	account.CreatedAt = now
 	account.UpdatedAt = now
 
-	err := r.db.QueryRowxContext(
+	err := r.conn(ctx).QueryRowxContext(
 		ctx,
 		query,
 		account.ID,
@@ -75,7 +104,7 @@ func (r *LedgerRepository) GetAccountByID(ctx context.Context, accountID uuid.UU
 	`
 
 	var account entities.LedgerAccount
-	err := r.db.GetContext(ctx, &account, query, accountID)
+	err := r.conn(ctx).GetContext(ctx, &account, query, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("account not found: %w", err)
@@ -95,7 +124,7 @@ func (r *LedgerRepository) GetAccountByUserAndType(ctx context.Context, userID u
 	`
 
 	var account entities.LedgerAccount
-	err := r.db.GetContext(ctx, &account, query, userID, accountType)
+	err := r.conn(ctx).GetContext(ctx, &account, query, userID, accountType)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("account not found: %w", err)
@@ -115,7 +144,7 @@ func (r *LedgerRepository) GetSystemAccount(ctx context.Context, accountType ent
 	`
 
 	var account entities.LedgerAccount
-	err := r.db.GetContext(ctx, &account, query, accountType)
+	err := r.conn(ctx).GetContext(ctx, &account, query, accountType)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("system account not found: %w", err)
@@ -136,7 +165,7 @@ func (r *LedgerRepository) GetUserAccounts(ctx context.Context, userID uuid.UUID
 	`
 
 	var accounts []*entities.LedgerAccount
-	err := r.db.SelectContext(ctx, &accounts, query, userID)
+	err := r.conn(ctx).SelectContext(ctx, &accounts, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("get user accounts: %w", err)
 	}
@@ -181,7 +210,7 @@ func (r *LedgerRepository) UpdateAccountBalance(ctx context.Context, accountID u
 		WHERE id = $3
 	`
 
-	result, err := r.db.ExecContext(ctx, query, newBalance, time.Now(), accountID)
+	result, err := r.conn(ctx).ExecContext(ctx, query, newBalance, time.Now(), accountID)
 	if err != nil {
 		return fmt.Errorf("update account balance: %w", err)
 	}
@@ -220,7 +249,7 @@ func (r *LedgerRepository) CreateTransaction(ctx context.Context, tx *entities.L
 		RETURNING created_at
 	`
 
-	err = r.db.QueryRowxContext(
+	err = r.conn(ctx).QueryRowxContext(
 		ctx,
 		query,
 		tx.ID,
@@ -259,7 +288,7 @@ func (r *LedgerRepository) GetTransactionByID(ctx context.Context, txID uuid.UUI
 	var tx entities.LedgerTransaction
 	var metadataJSON []byte
 
-	err := r.db.QueryRowxContext(ctx, query, txID).Scan(
+	err := r.conn(ctx).QueryRowxContext(ctx, query, txID).Scan(
 		&tx.ID,
 		&tx.UserID,
 		&tx.TransactionType,
@@ -301,7 +330,7 @@ func (r *LedgerRepository) GetTransactionByIdempotencyKey(ctx context.Context, k
 	var tx entities.LedgerTransaction
 	var metadataJSON []byte
 
-	err := r.db.QueryRowxContext(ctx, query, key).Scan(
+	err := r.conn(ctx).QueryRowxContext(ctx, query, key).Scan(
 		&tx.ID,
 		&tx.UserID,
 		&tx.TransactionType,
@@ -345,7 +374,7 @@ func (r *LedgerRepository) UpdateTransactionStatus(ctx context.Context, txID uui
 		WHERE id = $3
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, completedAt, txID)
+	result, err := r.conn(ctx).ExecContext(ctx, query, status, completedAt, txID)
 	if err != nil {
 		return fmt.Errorf("update transaction status: %w", err)
 	}
@@ -384,7 +413,7 @@ func (r *LedgerRepository) CreateEntry(ctx context.Context, entry *entities.Ledg
 		RETURNING created_at
 	`
 
-	err = r.db.QueryRowxContext(
+	err = r.conn(ctx).QueryRowxContext(
 		ctx,
 		query,
 		entry.ID,
@@ -415,7 +444,7 @@ func (r *LedgerRepository) GetEntriesByTransactionID(ctx context.Context, txID u
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.QueryxContext(ctx, query, txID)
+	rows, err := r.conn(ctx).QueryxContext(ctx, query, txID)
 	if err != nil {
 		return nil, fmt.Errorf("query entries: %w", err)
 	}
@@ -468,7 +497,7 @@ func (r *LedgerRepository) GetEntriesByAccountID(ctx context.Context, accountID
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.QueryxContext(ctx, query, accountID, limit, offset)
+	rows, err := r.conn(ctx).QueryxContext(ctx, query, accountID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("query entries: %w", err)
 	}
@@ -517,7 +546,7 @@ func (r *LedgerRepository) GetAccountBalance(ctx context.Context, accountID uuid
 	query := `SELECT balance FROM ledger_accounts WHERE id = $1`
 
 	var balance decimal.Decimal
-	err := r.db.QueryRowxContext(ctx, query, accountID).Scan(&balance)
+	err := r.conn(ctx).QueryRowxContext(ctx, query, accountID).Scan(&balance)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return decimal.Zero, fmt.Errorf("account not found")
@@ -528,6 +557,28 @@ func (r *LedgerRepository) GetAccountBalance(ctx context.Context, accountID uuid
 	return balance, nil
 }
 
+// GetAccountBalanceForUpdate retrieves an account balance and locks the
+// account row until the caller's transaction ends, so concurrent postings to
+// the same account apply one after the other instead of overwriting each
+// other. ctx must carry a transaction from WithLedgerTx.
+func (r *LedgerRepository) GetAccountBalanceForUpdate(ctx context.Context, accountID uuid.UUID) (decimal.Decimal, error) {
+	tx, ok := LedgerTxFromContext(ctx)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("lock account balance: no transaction in context")
+	}
+
+	var balance decimal.Decimal
+	err := tx.QueryRowxContext(ctx, `SELECT balance FROM ledger_accounts WHERE id = $1 FOR UPDATE`, accountID).Scan(&balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return decimal.Zero, fmt.Errorf("account not found")
+		}
+		return decimal.Zero, fmt.Errorf("lock account balance: %w", err)
+	}
+
+	return balance, nil
+}
+
 // GetUserBalances retrieves all balances for a user
 func (r *LedgerRepository) GetUserBalances(ctx context.Context, userID uuid.UUID) (*entities.UserBalances, error) {
 	query := `
@@ -536,7 +587,7 @@ func (r *LedgerRepository) GetUserBalances(ctx context.Context, userID uuid.UUID
 		WHERE user_id = $1
 	`
 
-	rows, err := r.db.QueryxContext(ctx, query, userID)
+	rows, err := r.conn(ctx).QueryxContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("query user balances: %w", err)
 	}
@@ -547,6 +598,7 @@ func (r *LedgerRepository) GetUserBalances(ctx context.Context, userID uuid.UUID
 		USDCBalance:       decimal.Zero,
 		FiatExposure:      decimal.Zero,
 		PendingInvestment: decimal.Zero,
+		PromotionalCredit: decimal.Zero,
 	}
 
 	var latestUpdate time.Time
@@ -566,6 +618,10 @@ func (r *LedgerRepository) GetUserBalances(ctx context.Context, userID uuid.UUID
 			balances.FiatExposure = balance
 		case entities.AccountTypePendingInvestment:
 			balances.PendingInvestment = balance
+		case entities.AccountTypeHeldBalance:
+			balances.HeldBalance = balance
+		case entities.AccountTypePromotionalCredit:
+			balances.PromotionalCredit = balance
 		}
 
 		if updatedAt.After(latestUpdate) {
@@ -592,7 +648,7 @@ func (r *LedgerRepository) GetSystemBuffers(ctx context.Context) (*entities.Syst
 		  AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational')
 	`
 
-	rows, err := r.db.QueryxContext(ctx, query)
+	rows, err := r.conn(ctx).QueryxContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query system buffers: %w", err)
 	}
@@ -649,7 +705,7 @@ func (r *LedgerRepository) GetTotalDebitsAndCredits(ctx context.Context) (totalD
 	`
 
 	var debitsStr, creditsStr string
-	err = r.db.QueryRowxContext(ctx, query).Scan(&debitsStr, &creditsStr)
+	err = r.conn(ctx).QueryRowxContext(ctx, query).Scan(&debitsStr, &creditsStr)
 	if err != nil {
 		return decimal.Zero, decimal.Zero, fmt.Errorf("get total debits and credits: %w", err)
 	}
@@ -676,7 +732,7 @@ func (r *LedgerRepository) CountOrphanedEntries(ctx context.Context) (int, error
 	`
 
 	var count int
-	err := r.db.QueryRowxContext(ctx, query).Scan(&count)
+	err := r.conn(ctx).QueryRowxContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count orphaned entries: %w", err)
 	}
@@ -698,7 +754,7 @@ func (r *LedgerRepository) CountInvalidTransactions(ctx context.Context) (int, e
 	`
 
 	var count int
-	err := r.db.QueryRowxContext(ctx, query).Scan(&count)
+	err := r.conn(ctx).QueryRowxContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count invalid transactions: %w", err)
 	}
@@ -717,7 +773,7 @@ func (r *LedgerRepository) GetTotalDepositEntries(ctx context.Context) (decimal.
 	`
 
 	var totalStr string
-	err := r.db.QueryRowxContext(ctx, query).Scan(&totalStr)
+	err := r.conn(ctx).QueryRowxContext(ctx, query).Scan(&totalStr)
 	if err != nil {
 		return decimal.Zero, fmt.Errorf("get total deposit entries: %w", err)
 	}
@@ -741,7 +797,7 @@ func (r *LedgerRepository) GetTotalWithdrawalEntries(ctx context.Context) (decim
 	`
 
 	var totalStr string
-	err := r.db.QueryRowxContext(ctx, query).Scan(&totalStr)
+	err := r.conn(ctx).QueryRowxContext(ctx, query).Scan(&totalStr)
 	if err != nil {
 		return decimal.Zero, fmt.Errorf("get total withdrawal entries: %w", err)
 	}
@@ -753,3 +809,29 @@ func (r *LedgerRepository) GetTotalWithdrawalEntries(ctx context.Context) (decim
 
 	return total, nil
 }
+
+// SummarizeAdjustments counts the manual adjustment transactions posted since
+// a time and sums their amounts, so reconciliation can report them apart from
+// system postings
+func (r *LedgerRepository) SummarizeAdjustments(ctx context.Context, since time.Time) (int, decimal.Decimal, error) {
+	query := `
+		SELECT COUNT(DISTINCT lt.id), COALESCE(SUM(le.amount), 0)
+		FROM ledger_transactions lt
+		JOIN ledger_entries le ON le.transaction_id = lt.id AND le.entry_type = 'debit'
+		WHERE lt.transaction_type = 'adjustment'
+		  AND lt.created_at >= $1
+	`
+
+	var count int
+	var totalStr string
+	if err := r.conn(ctx).QueryRowxContext(ctx, query, since).Scan(&count, &totalStr); err != nil {
+		return 0, decimal.Zero, fmt.Errorf("summarize adjustments: %w", err)
+	}
+
+	total, err := decimal.NewFromString(totalStr)
+	if err != nil {
+		return 0, decimal.Zero, fmt.Errorf("parse total: %w", err)
+	}
+
+	return count, total, nil
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// LinkedWalletRepository persists the mirror of wallets linked to users' Due accounts
+type LinkedWalletRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewLinkedWalletRepository creates a new linked wallet repository
+func NewLinkedWalletRepository(db *sql.DB, logger *zap.Logger) *LinkedWalletRepository {
+	return &LinkedWalletRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const linkedWalletColumns = `id, user_id, chain, address, due_wallet_id, source, label, created_at, updated_at`
+
+// Create records a newly linked wallet, returning ErrLinkedWalletExists when
+// the user already has the address linked
+func (r *LinkedWalletRepository) Create(ctx context.Context, wallet *entities.LinkedWallet) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO linked_wallets (`+linkedWalletColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		wallet.ID, wallet.UserID, wallet.Chain, wallet.Address, wallet.DueWalletID,
+		wallet.Source, wallet.Label, wallet.CreatedAt, wallet.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrLinkedWalletExists
+		}
+		return fmt.Errorf("failed to create linked wallet: %w", err)
+	}
+	return nil
+}
+
+// Get returns one of the user's linked wallets
+func (r *LinkedWalletRepository) Get(ctx context.Context, userID, id uuid.UUID) (*entities.LinkedWallet, error) {
+	wallet, err := scanLinkedWallet(r.db.QueryRowContext(ctx, `
+		SELECT `+linkedWalletColumns+` FROM linked_wallets WHERE id = $1 AND user_id = $2`, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrLinkedWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to get linked wallet: %w", err)
+	}
+	return wallet, nil
+}
+
+// ListByUser returns the user's linked wallets, oldest first
+func (r *LinkedWalletRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.LinkedWallet, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+linkedWalletColumns+` FROM linked_wallets
+		WHERE user_id = $1
+		ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked wallets: %w", err)
+	}
+	defer rows.Close()
+
+	var wallets []*entities.LinkedWallet
+	for rows.Next() {
+		wallet, err := scanLinkedWallet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan linked wallet: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, rows.Err()
+}
+
+// Delete removes one of the user's linked wallets
+func (r *LinkedWalletRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM linked_wallets WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete linked wallet: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrLinkedWalletNotFound
+	}
+	return nil
+}
+
+// Sync makes the user's rows match the wallets Due has linked: missing
+// wallets are added, known ones take Due's wallet ID, and rows for wallets
+// Due no longer has are removed
+func (r *LinkedWalletRepository) Sync(ctx context.Context, userID uuid.UUID, wallets []*entities.LinkedWallet) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	dueIDs := make([]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		dueIDs = append(dueIDs, wallet.DueWalletID)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO linked_wallets (`+linkedWalletColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (user_id, chain, address) DO UPDATE SET
+				due_wallet_id = EXCLUDED.due_wallet_id,
+				updated_at = EXCLUDED.updated_at
+			WHERE linked_wallets.due_wallet_id <> EXCLUDED.due_wallet_id`,
+			wallet.ID, userID, wallet.Chain, wallet.Address, wallet.DueWalletID,
+			wallet.Source, wallet.Label, wallet.CreatedAt, wallet.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to upsert linked wallet: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM linked_wallets WHERE user_id = $1 AND NOT (due_wallet_id = ANY($2))`,
+		userID, pq.Array(dueIDs),
+	); err != nil {
+		return fmt.Errorf("failed to remove unlinked wallets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit linked wallet sync: %w", err)
+	}
+	return nil
+}
+
+type linkedWalletScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLinkedWallet(row linkedWalletScanner) (*entities.LinkedWallet, error) {
+	var wallet entities.LinkedWallet
+	err := row.Scan(&wallet.ID, &wallet.UserID, &wallet.Chain, &wallet.Address, &wallet.DueWalletID,
+		&wallet.Source, &wallet.Label, &wallet.CreatedAt, &wallet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
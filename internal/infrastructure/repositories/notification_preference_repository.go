@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// NotificationPreferenceRepository persists users' notification preferences
+type NotificationPreferenceRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewNotificationPreferenceRepository creates a new notification preference repository
+func NewNotificationPreferenceRepository(db *sql.DB, logger *zap.Logger) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Get returns the user's preferences, or the defaults when they have saved
+// none, with the time zone from their profile
+func (r *NotificationPreferenceRepository) Get(ctx context.Context, userID uuid.UUID) (*entities.UserPreference, error) {
+	var (
+		id                                                     uuid.NullUUID
+		email, push, sms, deposit, withdrawal, trade, security sql.NullBool
+		portfolio, marketing, quietEnabled, digest             sql.NullBool
+		quietStart, quietEnd, timezone                         sql.NullString
+		digestHour                                             sql.NullInt32
+		createdAt, updatedAt                                   sql.NullTime
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.id, p.email_notifications, p.push_notifications, p.sms_notifications,
+			p.deposit_alerts, p.withdrawal_alerts, p.trade_alerts, p.security_alerts,
+			p.portfolio_updates, p.marketing_emails, p.quiet_hours_enabled, p.quiet_hours_start,
+			p.quiet_hours_end, p.digest_low_priority, p.digest_hour, p.created_at, p.updated_at,
+			u.timezone
+		FROM users u
+		LEFT JOIN user_preferences p ON p.user_id = u.id
+		WHERE u.id = $1`, userID).Scan(
+		&id, &email, &push, &sms, &deposit, &withdrawal, &trade, &security,
+		&portfolio, &marketing, &quietEnabled, &quietStart, &quietEnd, &digest, &digestHour,
+		&createdAt, &updatedAt, &timezone,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.DefaultUserPreference(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	prefs := entities.DefaultUserPreference(userID)
+	if timezone.Valid && timezone.String != "" {
+		prefs.Timezone = timezone.String
+	}
+	if !id.Valid {
+		return prefs, nil
+	}
+	prefs.ID = id.UUID
+	// Columns from before quiet hours may be NULL; they keep their defaults
+	setBool := func(dst *bool, v sql.NullBool) {
+		if v.Valid {
+			*dst = v.Bool
+		}
+	}
+	setBool(&prefs.EmailNotifications, email)
+	setBool(&prefs.PushNotifications, push)
+	setBool(&prefs.SMSNotifications, sms)
+	setBool(&prefs.DepositAlerts, deposit)
+	setBool(&prefs.WithdrawalAlerts, withdrawal)
+	setBool(&prefs.TradeAlerts, trade)
+	setBool(&prefs.SecurityAlerts, security)
+	setBool(&prefs.PortfolioUpdates, portfolio)
+	setBool(&prefs.MarketingEmails, marketing)
+	setBool(&prefs.QuietHoursEnabled, quietEnabled)
+	setBool(&prefs.DigestLowPriority, digest)
+	prefs.QuietHoursStart = quietStart.String
+	prefs.QuietHoursEnd = quietEnd.String
+	prefs.DigestHour = int(digestHour.Int32)
+	prefs.CreatedAt = createdAt.Time
+	prefs.UpdatedAt = updatedAt.Time
+	return prefs, nil
+}
+
+// Save creates or replaces the user's preferences
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, prefs *entities.UserPreference) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_preferences (
+			id, user_id, email_notifications, push_notifications, sms_notifications,
+			deposit_alerts, withdrawal_alerts, trade_alerts, security_alerts, portfolio_updates,
+			marketing_emails, quiet_hours_enabled, quiet_hours_start, quiet_hours_end,
+			digest_low_priority, digest_hour, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $17)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email_notifications = EXCLUDED.email_notifications,
+			push_notifications = EXCLUDED.push_notifications,
+			sms_notifications = EXCLUDED.sms_notifications,
+			deposit_alerts = EXCLUDED.deposit_alerts,
+			withdrawal_alerts = EXCLUDED.withdrawal_alerts,
+			trade_alerts = EXCLUDED.trade_alerts,
+			security_alerts = EXCLUDED.security_alerts,
+			portfolio_updates = EXCLUDED.portfolio_updates,
+			marketing_emails = EXCLUDED.marketing_emails,
+			quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			digest_low_priority = EXCLUDED.digest_low_priority,
+			digest_hour = EXCLUDED.digest_hour,
+			updated_at = EXCLUDED.updated_at`,
+		prefs.ID, prefs.UserID, prefs.EmailNotifications, prefs.PushNotifications, prefs.SMSNotifications,
+		prefs.DepositAlerts, prefs.WithdrawalAlerts, prefs.TradeAlerts, prefs.SecurityAlerts, prefs.PortfolioUpdates,
+		prefs.MarketingEmails, prefs.QuietHoursEnabled, prefs.QuietHoursStart, prefs.QuietHoursEnd,
+		prefs.DigestLowPriority, prefs.DigestHour, prefs.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to save notification preferences", zap.Error(err), zap.String("user_id", prefs.UserID.String()))
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -32,9 +33,9 @@ func (r *OnboardingJobRepository) Create(ctx context.Context, job *entities.Onbo
 		INSERT INTO onboarding_jobs (
 			id, user_id, status, job_type, payload, attempt_count, 
 			max_attempts, next_retry_at, error_message, started_at, 
-			completed_at, created_at, updated_at
+			completed_at, created_at, updated_at, steps
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)`
 
 	// Convert payload map to JSON for PostgreSQL JSONB storage
@@ -45,6 +46,10 @@ func (r *OnboardingJobRepository) Create(ctx context.Context, job *entities.Onbo
 			zap.Error(err))
 		return fmt.Errorf("failed to marshal job payload: %w", err)
 	}
+	stepsJSON, err := marshalOnboardingSteps(job.Steps)
+	if err != nil {
+		return err
+	}
 
 	_, err = r.db.ExecContext(ctx, query,
 		job.ID,
@@ -60,6 +65,7 @@ func (r *OnboardingJobRepository) Create(ctx context.Context, job *entities.Onbo
 		job.CompletedAt,
 		job.CreatedAt,
 		job.UpdatedAt,
+		stepsJSON,
 	)
 
 	if err != nil {
@@ -76,26 +82,29 @@ func (r *OnboardingJobRepository) Create(ctx context.Context, job *entities.Onbo
 	return nil
 }
 
-// GetByID retrieves an onboarding job by ID
-func (r *OnboardingJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.OnboardingJob, error) {
-	query := `
-		SELECT id, user_id, status, job_type, payload, attempt_count,
-		       max_attempts, next_retry_at, error_message, started_at,
-		       completed_at, created_at, updated_at
-		FROM onboarding_jobs 
-		WHERE id = $1`
+// onboardingJobColumns is the column list read by scanOnboardingJob
+const onboardingJobColumns = `
+		id, user_id, status, job_type, payload, attempt_count,
+		max_attempts, next_retry_at, error_message, started_at,
+		completed_at, created_at, updated_at, steps`
+
+type onboardingJobScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+func scanOnboardingJob(row onboardingJobScanner) (*entities.OnboardingJob, error) {
 	job := &entities.OnboardingJob{}
 	var statusStr, jobTypeStr string
+	var payload, steps []byte
 	var nextRetryAt, startedAt, completedAt sql.NullTime
 	var errorMessage sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	if err := row.Scan(
 		&job.ID,
 		&job.UserID,
 		&statusStr,
 		&jobTypeStr,
-		&job.Payload,
+		&payload,
 		&job.AttemptCount,
 		&job.MaxAttempts,
 		&nextRetryAt,
@@ -104,22 +113,26 @@ func (r *OnboardingJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*e
 		&completedAt,
 		&job.CreatedAt,
 		&job.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("onboarding job not found")
-		}
-		r.logger.Error("Failed to get onboarding job by ID",
-			zap.String("job_id", id.String()),
-			zap.Error(err))
-		return nil, fmt.Errorf("failed to get onboarding job: %w", err)
+		&steps,
+	); err != nil {
+		return nil, err
 	}
 
 	// Convert string fields to enums
 	job.Status = entities.OnboardingJobStatus(statusStr)
 	job.JobType = entities.OnboardingJobType(jobTypeStr)
 
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &job.Payload); err != nil {
+			return nil, fmt.Errorf("failed to decode job payload: %w", err)
+		}
+	}
+	if len(steps) > 0 {
+		if err := json.Unmarshal(steps, &job.Steps); err != nil {
+			return nil, fmt.Errorf("failed to decode job steps: %w", err)
+		}
+	}
+
 	// Handle nullable fields
 	if nextRetryAt.Valid {
 		job.NextRetryAt = &nextRetryAt.Time
@@ -137,38 +150,46 @@ func (r *OnboardingJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*e
 	return job, nil
 }
 
+func marshalOnboardingSteps(steps []entities.OnboardingJobStep) ([]byte, error) {
+	if steps == nil {
+		steps = []entities.OnboardingJobStep{}
+	}
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job steps: %w", err)
+	}
+	return data, nil
+}
+
+// GetByID retrieves an onboarding job by ID
+func (r *OnboardingJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.OnboardingJob, error) {
+	query := `SELECT` + onboardingJobColumns + `
+		FROM onboarding_jobs 
+		WHERE id = $1`
+
+	job, err := scanOnboardingJob(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrOnboardingJobNotFound
+		}
+		r.logger.Error("Failed to get onboarding job by ID",
+			zap.String("job_id", id.String()),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to get onboarding job: %w", err)
+	}
+
+	return job, nil
+}
+
 // GetByUserID retrieves an onboarding job by user ID
 func (r *OnboardingJobRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.OnboardingJob, error) {
-	query := `
-		SELECT id, user_id, status, job_type, payload, attempt_count,
-		       max_attempts, next_retry_at, error_message, started_at,
-		       completed_at, created_at, updated_at
+	query := `SELECT` + onboardingJobColumns + `
 		FROM onboarding_jobs 
 		WHERE user_id = $1
 		ORDER BY created_at DESC
 		LIMIT 1`
 
-	job := &entities.OnboardingJob{}
-	var statusStr, jobTypeStr string
-	var nextRetryAt, startedAt, completedAt sql.NullTime
-	var errorMessage sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&job.ID,
-		&job.UserID,
-		&statusStr,
-		&jobTypeStr,
-		&job.Payload,
-		&job.AttemptCount,
-		&job.MaxAttempts,
-		&nextRetryAt,
-		&errorMessage,
-		&startedAt,
-		&completedAt,
-		&job.CreatedAt,
-		&job.UpdatedAt,
-	)
-
+	job, err := scanOnboardingJob(r.db.QueryRowContext(ctx, query, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("onboarding job not found for user")
@@ -179,101 +200,107 @@ func (r *OnboardingJobRepository) GetByUserID(ctx context.Context, userID uuid.U
 		return nil, fmt.Errorf("failed to get onboarding job: %w", err)
 	}
 
-	// Convert string fields to enums
-	job.Status = entities.OnboardingJobStatus(statusStr)
-	job.JobType = entities.OnboardingJobType(jobTypeStr)
-
-	// Handle nullable fields
-	if nextRetryAt.Valid {
-		job.NextRetryAt = &nextRetryAt.Time
-	}
-	if startedAt.Valid {
-		job.StartedAt = &startedAt.Time
-	}
-	if completedAt.Valid {
-		job.CompletedAt = &completedAt.Time
-	}
-	if errorMessage.Valid {
-		job.ErrorMessage = &errorMessage.String
-	}
-
 	return job, nil
 }
 
 // GetPendingJobs retrieves jobs that are eligible for processing
 func (r *OnboardingJobRepository) GetPendingJobs(ctx context.Context, limit int) ([]*entities.OnboardingJob, error) {
-	query := `
-		SELECT id, user_id, status, job_type, payload, attempt_count,
-		       max_attempts, next_retry_at, error_message, started_at,
-		       completed_at, created_at, updated_at
+	query := `SELECT` + onboardingJobColumns + `
 		FROM onboarding_jobs 
 		WHERE (status = 'queued' OR (status = 'retry' AND next_retry_at <= NOW()))
 		ORDER BY created_at ASC
 		LIMIT $1`
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	jobs, err := r.queryJobs(ctx, query, limit)
 	if err != nil {
 		r.logger.Error("Failed to get pending onboarding jobs", zap.Error(err))
 		return nil, fmt.Errorf("failed to get pending jobs: %w", err)
 	}
+
+	r.logger.Debug("Retrieved pending onboarding jobs",
+		zap.Int("count", len(jobs)))
+
+	return jobs, nil
+}
+
+// ListStuck returns jobs that need an operator: those still in progress
+// since before the cutoff, which a crashed worker left behind, and those
+// that failed for good. Oldest first.
+func (r *OnboardingJobRepository) ListStuck(ctx context.Context, inProgressBefore time.Time, limit int) ([]*entities.OnboardingJob, error) {
+	query := `SELECT` + onboardingJobColumns + `
+		FROM onboarding_jobs
+		WHERE (status = 'in_progress' AND updated_at < $1) OR status = 'failed'
+		ORDER BY updated_at ASC
+		LIMIT $2`
+
+	jobs, err := r.queryJobs(ctx, query, inProgressBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stuck onboarding jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Claim moves a queued or due retry job to in progress and reports whether
+// this caller won it, so two workers never run the same saga at once
+func (r *OnboardingJobRepository) Claim(ctx context.Context, job *entities.OnboardingJob) (bool, error) {
+	query := `
+		UPDATE onboarding_jobs SET
+			status = 'in_progress',
+			attempt_count = attempt_count + 1,
+			started_at = $2,
+			updated_at = $2
+		WHERE id = $1
+		  AND (status = 'queued' OR (status = 'retry' AND next_retry_at <= $2))
+		RETURNING attempt_count`
+
+	now := time.Now()
+	var attempts int
+	err := r.db.QueryRowContext(ctx, query, job.ID, now).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim onboarding job: %w", err)
+	}
+
+	job.Status = entities.OnboardingJobStatusInProgress
+	job.AttemptCount = attempts
+	job.StartedAt = &now
+	job.UpdatedAt = now
+	return true, nil
+}
+
+// CountBacklog returns the number of jobs waiting to run
+func (r *OnboardingJobRepository) CountBacklog(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM onboarding_jobs
+		WHERE status = 'queued' OR (status = 'retry' AND next_retry_at <= NOW())`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count onboarding backlog: %w", err)
+	}
+	return count, nil
+}
+
+func (r *OnboardingJobRepository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*entities.OnboardingJob, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var jobs []*entities.OnboardingJob
 	for rows.Next() {
-		job := &entities.OnboardingJob{}
-		var statusStr, jobTypeStr string
-		var nextRetryAt, startedAt, completedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		err := rows.Scan(
-			&job.ID,
-			&job.UserID,
-			&statusStr,
-			&jobTypeStr,
-			&job.Payload,
-			&job.AttemptCount,
-			&job.MaxAttempts,
-			&nextRetryAt,
-			&errorMessage,
-			&startedAt,
-			&completedAt,
-			&job.CreatedAt,
-			&job.UpdatedAt,
-		)
+		job, err := scanOnboardingJob(rows)
 		if err != nil {
 			r.logger.Error("Failed to scan onboarding job", zap.Error(err))
 			continue
 		}
-
-		// Convert string fields to enums
-		job.Status = entities.OnboardingJobStatus(statusStr)
-		job.JobType = entities.OnboardingJobType(jobTypeStr)
-
-		// Handle nullable fields
-		if nextRetryAt.Valid {
-			job.NextRetryAt = &nextRetryAt.Time
-		}
-		if startedAt.Valid {
-			job.StartedAt = &startedAt.Time
-		}
-		if completedAt.Valid {
-			job.CompletedAt = &completedAt.Time
-		}
-		if errorMessage.Valid {
-			job.ErrorMessage = &errorMessage.String
-		}
-
 		jobs = append(jobs, job)
 	}
-
 	if err := rows.Err(); err != nil {
-		r.logger.Error("Error iterating over onboarding jobs", zap.Error(err))
 		return nil, fmt.Errorf("error iterating over jobs: %w", err)
 	}
-
-	r.logger.Debug("Retrieved pending onboarding jobs",
-		zap.Int("count", len(jobs)))
-
 	return jobs, nil
 }
 
@@ -290,14 +317,24 @@ func (r *OnboardingJobRepository) Update(ctx context.Context, job *entities.Onbo
 			error_message = $8,
 			started_at = $9,
 			completed_at = $10,
-			updated_at = $11
+			updated_at = $11,
+			steps = $12
 		WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query,
+	payloadJSON, err := json.Marshal(job.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+	stepsJSON, err := marshalOnboardingSteps(job.Steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
 		job.ID,
 		string(job.Status),
 		string(job.JobType),
-		job.Payload,
+		payloadJSON,
 		job.AttemptCount,
 		job.MaxAttempts,
 		job.NextRetryAt,
@@ -305,6 +342,7 @@ func (r *OnboardingJobRepository) Update(ctx context.Context, job *entities.Onbo
 		job.StartedAt,
 		job.CompletedAt,
 		job.UpdatedAt,
+		stepsJSON,
 	)
 
 	if err != nil {
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// OnboardingOutboxRepository reads the onboarding outbox. Rows are inserted
+// by database triggers on users and managed_wallets, never from Go.
+type OnboardingOutboxRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewOnboardingOutboxRepository creates a new onboarding outbox repository
+func NewOnboardingOutboxRepository(db *sql.DB, logger *zap.Logger) *OnboardingOutboxRepository {
+	return &OnboardingOutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const onboardingOutboxColumns = `
+	id, sequence, user_id, kind, from_status, to_status, chain, occurred_at,
+	attempts, last_error, next_attempt_at, published_at`
+
+// ClaimDue leases up to limit unpublished entries, oldest first, so other
+// relays skip them until the lease expires. An entry is not claimed while an
+// earlier entry for the same user is waiting out a retry backoff or is leased
+// elsewhere, which keeps each user's events in order.
+func (r *OnboardingOutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.OnboardingOutboxEntry, error) {
+	query := `
+		UPDATE onboarding_outbox SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT o.id FROM onboarding_outbox o
+			WHERE o.published_at IS NULL AND o.next_attempt_at <= $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM onboarding_outbox earlier
+				WHERE earlier.user_id = o.user_id
+				  AND earlier.published_at IS NULL
+				  AND earlier.sequence < o.sequence
+				  AND earlier.next_attempt_at > $1
+			  )
+			ORDER BY o.sequence
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + onboardingOutboxColumns
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim onboarding outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*entities.OnboardingOutboxEntry
+	for rows.Next() {
+		entry, err := scanOnboardingOutboxEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan onboarding outbox entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate onboarding outbox entries: %w", err)
+	}
+	return entries, nil
+}
+
+// MarkPublished records that an entry reached the event bus
+func (r *OnboardingOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE onboarding_outbox SET published_at = $2, attempts = attempts + 1, last_error = NULL
+		WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark onboarding outbox entry published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed publish and when to try again
+func (r *OnboardingOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE onboarding_outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1`, id, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to record onboarding outbox failure: %w", err)
+	}
+	return nil
+}
+
+// DeletePublishedBefore removes entries published before cutoff
+func (r *OnboardingOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM onboarding_outbox WHERE published_at IS NOT NULL AND published_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published onboarding outbox entries: %w", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
+
+// CountBacklog returns the number of unpublished entries
+func (r *OnboardingOutboxRepository) CountBacklog(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM onboarding_outbox WHERE published_at IS NULL`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count onboarding outbox backlog: %w", err)
+	}
+	return count, nil
+}
+
+type onboardingOutboxScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOnboardingOutboxEntry(row onboardingOutboxScanner) (*entities.OnboardingOutboxEntry, error) {
+	entry := &entities.OnboardingOutboxEntry{}
+	var fromStatus, chain, lastError sql.NullString
+	var publishedAt sql.NullTime
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.Sequence,
+		&entry.UserID,
+		&entry.Kind,
+		&fromStatus,
+		&entry.ToStatus,
+		&chain,
+		&entry.OccurredAt,
+		&entry.Attempts,
+		&lastError,
+		&entry.NextAttemptAt,
+		&publishedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if fromStatus.Valid {
+		entry.FromStatus = &fromStatus.String
+	}
+	if chain.Valid {
+		entry.Chain = &chain.String
+	}
+	if lastError.Valid {
+		entry.LastError = &lastError.String
+	}
+	if publishedAt.Valid {
+		entry.PublishedAt = &publishedAt.Time
+	}
+	return entry, nil
+}
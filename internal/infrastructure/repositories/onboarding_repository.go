@@ -225,19 +225,30 @@ func NewKYCSubmissionRepository(db *sql.DB, logger *zap.Logger) *KYCSubmissionRe
 	}
 }
 
+const kycSubmissionColumns = `id, user_id, provider_ref, status, submitted_at,
+		       reviewed_at, rejection_reasons, metadata, documents, created_at, updated_at, sub_status, pii_token`
+
 // Create creates a new KYC submission
 func (r *KYCSubmissionRepository) Create(ctx context.Context, submission *entities.KYCSubmission) error {
 	query := `
 		INSERT INTO kyc_submissions (
 			id, user_id, provider_ref, status, submitted_at, 
-			reviewed_at, rejection_reasons, metadata, created_at, updated_at
+			reviewed_at, rejection_reasons, metadata, documents, created_at, updated_at, sub_status, pii_token
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 		)`
 
 	rejectionReasonsJSON, _ := stringSliceToJSON(submission.RejectionReasons)
+	metadataJSON, err := json.Marshal(submission.VerificationData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal KYC submission metadata: %w", err)
+	}
+	documentsJSON, err := marshalKYCDocuments(submission.Documents)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		submission.ID,
 		submission.UserID,
 		submission.ProviderRef,
@@ -245,9 +256,12 @@ func (r *KYCSubmissionRepository) Create(ctx context.Context, submission *entiti
 		submission.SubmittedAt,
 		submission.ReviewedAt,
 		rejectionReasonsJSON,
-		submission.VerificationData,
+		metadataJSON,
+		documentsJSON,
 		submission.CreatedAt,
 		submission.UpdatedAt,
+		string(submission.SubStatus),
+		submission.PIIToken,
 	)
 
 	if err != nil {
@@ -262,8 +276,7 @@ func (r *KYCSubmissionRepository) Create(ctx context.Context, submission *entiti
 // GetByUserID retrieves all KYC submissions for a user
 func (r *KYCSubmissionRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.KYCSubmission, error) {
 	query := `
-		SELECT id, user_id, provider_ref, status, submitted_at, 
-		       reviewed_at, rejection_reasons, metadata, created_at, updated_at
+		SELECT ` + kycSubmissionColumns + `
 		FROM kyc_submissions 
 		WHERE user_id = $1
 		ORDER BY created_at DESC`
@@ -277,35 +290,11 @@ func (r *KYCSubmissionRepository) GetByUserID(ctx context.Context, userID uuid.U
 
 	var submissions []*entities.KYCSubmission
 	for rows.Next() {
-		submission := &entities.KYCSubmission{}
-		var reviewedAt sql.NullTime
-		var rejectionReasonsJSON sql.NullString
-
-		err := rows.Scan(
-			&submission.ID,
-			&submission.UserID,
-			&submission.ProviderRef,
-			&submission.Status,
-			&submission.SubmittedAt,
-			&reviewedAt,
-			&rejectionReasonsJSON,
-			&submission.VerificationData,
-			&submission.CreatedAt,
-			&submission.UpdatedAt,
-		)
+		submission, err := scanKYCSubmission(rows)
 		if err != nil {
 			r.logger.Error("Failed to scan KYC submission", zap.Error(err))
 			return nil, fmt.Errorf("failed to scan KYC submission: %w", err)
 		}
-
-		if reviewedAt.Valid {
-			submission.ReviewedAt = &reviewedAt.Time
-		}
-
-		if rejectionReasonsJSON.Valid {
-			submission.RejectionReasons, _ = jsonToStringSlice(rejectionReasonsJSON.String)
-		}
-
 		submissions = append(submissions, submission)
 	}
 
@@ -315,64 +304,55 @@ func (r *KYCSubmissionRepository) GetByUserID(ctx context.Context, userID uuid.U
 // GetByProviderRef retrieves a KYC submission by provider reference
 func (r *KYCSubmissionRepository) GetByProviderRef(ctx context.Context, providerRef string) (*entities.KYCSubmission, error) {
 	query := `
-		SELECT id, user_id, provider_ref, status, submitted_at, 
-		       reviewed_at, rejection_reasons, metadata, created_at, updated_at
+		SELECT ` + kycSubmissionColumns + `
 		FROM kyc_submissions 
 		WHERE provider_ref = $1`
 
-	submission := &entities.KYCSubmission{}
-	var reviewedAt sql.NullTime
-	var rejectionReasonsJSON sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, providerRef).Scan(
-		&submission.ID,
-		&submission.UserID,
-		&submission.ProviderRef,
-		&submission.Status,
-		&submission.SubmittedAt,
-		&reviewedAt,
-		&rejectionReasonsJSON,
-		&submission.VerificationData,
-		&submission.CreatedAt,
-		&submission.UpdatedAt,
-	)
-
+	submission, err := scanKYCSubmission(r.db.QueryRowContext(ctx, query, providerRef))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("KYC submission not found")
+			return nil, entities.ErrKYCSubmissionNotFound
 		}
 		r.logger.Error("Failed to get KYC submission by provider ref", zap.Error(err), zap.String("provider_ref", providerRef))
 		return nil, fmt.Errorf("failed to get KYC submission: %w", err)
 	}
 
-	if reviewedAt.Valid {
-		submission.ReviewedAt = &reviewedAt.Time
-	}
-
-	if rejectionReasonsJSON.Valid {
-		submission.RejectionReasons, _ = jsonToStringSlice(rejectionReasonsJSON.String)
-	}
-
 	return submission, nil
 }
 
-// Update updates a KYC submission
+// Update updates a KYC submission. The provider reference changes when
+// rejected documents are resubmitted as a new application.
 func (r *KYCSubmissionRepository) Update(ctx context.Context, submission *entities.KYCSubmission) error {
 	rejectionReasonsJSON, _ := stringSliceToJSON(submission.RejectionReasons)
+	metadataJSON, err := json.Marshal(submission.VerificationData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal KYC submission metadata: %w", err)
+	}
+	documentsJSON, err := marshalKYCDocuments(submission.Documents)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		UPDATE kyc_submissions SET 
 			status = $2, reviewed_at = $3, rejection_reasons = $4, 
-			metadata = $5, updated_at = $6
+			metadata = $5, documents = $6, provider_ref = $7,
+			submitted_at = $8, updated_at = $9, sub_status = $10,
+			pii_token = COALESCE($11, pii_token)
 		WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		submission.ID,
 		string(submission.Status),
 		submission.ReviewedAt,
 		rejectionReasonsJSON,
-		submission.VerificationData,
+		metadataJSON,
+		documentsJSON,
+		submission.ProviderRef,
+		submission.SubmittedAt,
 		time.Now(),
+		string(submission.SubStatus),
+		submission.PIIToken,
 	)
 
 	if err != nil {
@@ -387,18 +367,35 @@ func (r *KYCSubmissionRepository) Update(ctx context.Context, submission *entiti
 // GetLatestByUserID retrieves the most recent KYC submission for a user
 func (r *KYCSubmissionRepository) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entities.KYCSubmission, error) {
 	query := `
-		SELECT id, user_id, provider_ref, status, submitted_at, 
-		       reviewed_at, rejection_reasons, metadata, created_at, updated_at
+		SELECT ` + kycSubmissionColumns + `
 		FROM kyc_submissions 
 		WHERE user_id = $1
 		ORDER BY created_at DESC
 		LIMIT 1`
 
+	submission, err := scanKYCSubmission(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrKYCSubmissionNotFound
+		}
+		r.logger.Error("Failed to get latest KYC submission by user ID", zap.Error(err), zap.String("user_id", userID.String()))
+		return nil, fmt.Errorf("failed to get KYC submission: %w", err)
+	}
+
+	return submission, nil
+}
+
+type kycSubmissionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKYCSubmission(row kycSubmissionScanner) (*entities.KYCSubmission, error) {
 	submission := &entities.KYCSubmission{}
 	var reviewedAt sql.NullTime
 	var rejectionReasonsJSON sql.NullString
+	var metadataJSON, documentsJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+	err := row.Scan(
 		&submission.ID,
 		&submission.UserID,
 		&submission.ProviderRef,
@@ -406,17 +403,15 @@ func (r *KYCSubmissionRepository) GetLatestByUserID(ctx context.Context, userID
 		&submission.SubmittedAt,
 		&reviewedAt,
 		&rejectionReasonsJSON,
-		&submission.VerificationData,
+		&metadataJSON,
+		&documentsJSON,
 		&submission.CreatedAt,
 		&submission.UpdatedAt,
+		&submission.SubStatus,
+		&submission.PIIToken,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("KYC submission not found")
-		}
-		r.logger.Error("Failed to get latest KYC submission by user ID", zap.Error(err), zap.String("user_id", userID.String()))
-		return nil, fmt.Errorf("failed to get KYC submission: %w", err)
+		return nil, err
 	}
 
 	if reviewedAt.Valid {
@@ -427,9 +422,32 @@ func (r *KYCSubmissionRepository) GetLatestByUserID(ctx context.Context, userID
 		submission.RejectionReasons, _ = jsonToStringSlice(rejectionReasonsJSON.String)
 	}
 
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &submission.VerificationData); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal KYC submission metadata: %w", err)
+		}
+	}
+
+	if len(documentsJSON) > 0 {
+		if err := json.Unmarshal(documentsJSON, &submission.Documents); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal KYC documents: %w", err)
+		}
+	}
+
 	return submission, nil
 }
 
+func marshalKYCDocuments(documents []entities.KYCDocument) ([]byte, error) {
+	if documents == nil {
+		documents = []entities.KYCDocument{}
+	}
+	documentsJSON, err := json.Marshal(documents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal KYC documents: %w", err)
+	}
+	return documentsJSON, nil
+}
+
 // WalletProvisioningJobRepository implements the wallet provisioning job repository interface using PostgreSQL
 type WalletProvisioningJobRepository struct {
 	db     *sql.DB
@@ -567,21 +585,24 @@ func (r *WalletProvisioningJobRepository) GetByUserID(ctx context.Context, userI
 	return job, nil
 }
 
-// GetRetryableJobs retrieves wallet provisioning jobs that can be retried
+// GetRetryableJobs retrieves wallet provisioning jobs that can be retried, plus
+// queued jobs scheduled for background processing (next_retry_at set, as
+// backfills do). Queued jobs without a schedule are processed inline on creation.
 func (r *WalletProvisioningJobRepository) GetRetryableJobs(ctx context.Context, limit int) ([]*entities.WalletProvisioningJob, error) {
 	query := `
 		SELECT id, user_id, chains, status, attempt_count, max_attempts,
 		       error_message, next_retry_at, created_at, updated_at
 		FROM wallet_provisioning_jobs 
-		WHERE status IN ($1, $2)
+		WHERE (status IN ($1, $2) OR (status = $3 AND next_retry_at IS NOT NULL))
 		   AND attempt_count < max_attempts 
 		   AND (next_retry_at IS NULL OR next_retry_at <= NOW())
 		ORDER BY created_at ASC
-		LIMIT $3`
+		LIMIT $4`
 
 	rows, err := r.db.QueryContext(ctx, query,
 		string(entities.ProvisioningStatusFailed),
 		string(entities.ProvisioningStatusRetry),
+		string(entities.ProvisioningStatusQueued),
 		limit)
 	if err != nil {
 		r.logger.Error("Failed to get retryable jobs", zap.Error(err))
@@ -624,6 +645,28 @@ func (r *WalletProvisioningJobRepository) GetRetryableJobs(ctx context.Context,
 	return jobs, nil
 }
 
+// CountRetryableJobs returns how many jobs GetRetryableJobs would pick up
+// without a limit
+func (r *WalletProvisioningJobRepository) CountRetryableJobs(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM wallet_provisioning_jobs
+		WHERE (status IN ($1, $2) OR (status = $3 AND next_retry_at IS NOT NULL))
+		   AND attempt_count < max_attempts
+		   AND (next_retry_at IS NULL OR next_retry_at <= NOW())`
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, query,
+		string(entities.ProvisioningStatusFailed),
+		string(entities.ProvisioningStatusRetry),
+		string(entities.ProvisioningStatusQueued),
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count retryable jobs: %w", err)
+	}
+	return count, nil
+}
+
 // Update updates a wallet provisioning job
 func (r *WalletProvisioningJobRepository) Update(ctx context.Context, job *entities.WalletProvisioningJob) error {
 	chainsArray := pq.StringArray(job.Chains)
@@ -634,7 +677,8 @@ func (r *WalletProvisioningJobRepository) Update(ctx context.Context, job *entit
 	query := `
 		UPDATE wallet_provisioning_jobs SET 
 			chains = $2, status = $3, attempt_count = $4, max_attempts = $5,
-			error_message = $6, next_retry_at = $7, updated_at = $8
+			error_message = $6, next_retry_at = $7, updated_at = $8,
+			lease_expires_at = $9, heartbeat_at = $10
 		WHERE id = $1`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -646,6 +690,8 @@ func (r *WalletProvisioningJobRepository) Update(ctx context.Context, job *entit
 		job.ErrorMessage,
 		job.NextRetryAt,
 		time.Now(),
+		job.LeaseExpiresAt,
+		job.HeartbeatAt,
 	)
 
 	if err != nil {
@@ -657,6 +703,115 @@ func (r *WalletProvisioningJobRepository) Update(ctx context.Context, job *entit
 	return nil
 }
 
+// RenewLease extends the lease on a job that is still in progress
+func (r *WalletProvisioningJobRepository) RenewLease(ctx context.Context, id uuid.UUID, until time.Time) error {
+	query := `
+		UPDATE wallet_provisioning_jobs
+		SET lease_expires_at = $2, heartbeat_at = NOW()
+		WHERE id = $1 AND status = $3`
+
+	if _, err := r.db.ExecContext(ctx, query, id, until, string(entities.ProvisioningStatusInProgress)); err != nil {
+		return fmt.Errorf("failed to renew wallet provisioning job lease: %w", err)
+	}
+	return nil
+}
+
+// CountExpiredLeases returns how many in-progress jobs have outlived their lease
+func (r *WalletProvisioningJobRepository) CountExpiredLeases(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM wallet_provisioning_jobs
+		WHERE status = $1 AND lease_expires_at < NOW()`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, string(entities.ProvisioningStatusInProgress)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count expired wallet provisioning leases: %w", err)
+	}
+	return count, nil
+}
+
+// GetExpiredLeases returns in-progress jobs whose lease has run out, oldest
+// lease first
+func (r *WalletProvisioningJobRepository) GetExpiredLeases(ctx context.Context, limit int) ([]*entities.WalletProvisioningJob, error) {
+	query := `
+		SELECT id, user_id, chains, status, attempt_count, max_attempts,
+		       error_message, next_retry_at, lease_expires_at, heartbeat_at,
+		       created_at, updated_at
+		FROM wallet_provisioning_jobs
+		WHERE status = $1 AND lease_expires_at < NOW()
+		ORDER BY lease_expires_at ASC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, string(entities.ProvisioningStatusInProgress), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired wallet provisioning leases: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*entities.WalletProvisioningJob
+	for rows.Next() {
+		job := &entities.WalletProvisioningJob{}
+		var chains pq.StringArray
+		var nextRetryAt, leaseExpiresAt, heartbeatAt sql.NullTime
+
+		if err := rows.Scan(
+			&job.ID,
+			&job.UserID,
+			&chains,
+			&job.Status,
+			&job.AttemptCount,
+			&job.MaxAttempts,
+			&job.ErrorMessage,
+			&nextRetryAt,
+			&leaseExpiresAt,
+			&heartbeatAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet provisioning job: %w", err)
+		}
+
+		job.Chains = append([]string(nil), chains...)
+		if nextRetryAt.Valid {
+			job.NextRetryAt = &nextRetryAt.Time
+		}
+		if leaseExpiresAt.Valid {
+			job.LeaseExpiresAt = &leaseExpiresAt.Time
+		}
+		if heartbeatAt.Valid {
+			job.HeartbeatAt = &heartbeatAt.Time
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate wallet provisioning jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ReleaseExpiredLease writes the janitor's decision for an abandoned job,
+// reporting false when the job renewed its lease or finished in the meantime
+func (r *WalletProvisioningJobRepository) ReleaseExpiredLease(ctx context.Context, job *entities.WalletProvisioningJob) (bool, error) {
+	query := `
+		UPDATE wallet_provisioning_jobs SET
+			status = $2, error_message = $3, next_retry_at = $4,
+			lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = $5 AND lease_expires_at < NOW()`
+
+	result, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		string(job.Status),
+		job.ErrorMessage,
+		job.NextRetryAt,
+		string(entities.ProvisioningStatusInProgress),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to release wallet provisioning job lease: %w", err)
+	}
+	released, _ := result.RowsAffected()
+	return released > 0, nil
+}
+
 // JSON utility functions
 func stringSliceToJSON(slice []string) (string, error) {
 	if slice == nil {
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// OpsDigestRepository aggregates a day of platform activity for the ops digest
+// and records which days have been delivered
+type OpsDigestRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewOpsDigestRepository creates a new ops digest repository
+func NewOpsDigestRepository(db *sql.DB, logger *zap.Logger) *OpsDigestRepository {
+	return &OpsDigestRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Collect fills the digest's counters for activity in [digest.From, digest.To)
+func (r *OpsDigestRepository) Collect(ctx context.Context, digest *entities.OpsDigest) error {
+	from, to := digest.From, digest.To
+
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM users WHERE created_at >= $1 AND created_at < $2`, from, to,
+	).Scan(&digest.Signups); err != nil {
+		return fmt.Errorf("failed to count signups: %w", err)
+	}
+
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE status = 'approved'), COUNT(*) FILTER (WHERE status = 'rejected')
+		FROM kyc_submissions
+		WHERE reviewed_at >= $1 AND reviewed_at < $2
+	`, from, to).Scan(&digest.KYC.Approved, &digest.KYC.Rejected); err != nil {
+		return fmt.Errorf("failed to count kyc decisions: %w", err)
+	}
+
+	var err error
+	if digest.Deposits, err = r.volume(ctx, "deposits", from, to); err != nil {
+		return err
+	}
+	if digest.Withdrawals, err = r.volume(ctx, "withdrawals", from, to); err != nil {
+		return err
+	}
+
+	jobs := &digest.FailedJobs
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM onboarding_jobs WHERE status = 'failed' AND updated_at >= $1 AND updated_at < $2),
+			(SELECT COUNT(*) FROM wallet_provisioning_jobs WHERE status = 'failed' AND updated_at >= $1 AND updated_at < $2),
+			(SELECT COUNT(*) FROM funding_event_jobs WHERE status IN ('failed', 'dlq') AND updated_at >= $1 AND updated_at < $2),
+			(SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'dead' AND last_attempt_at >= $1 AND last_attempt_at < $2)
+	`, from, to).Scan(&jobs.Onboarding, &jobs.WalletProvisioning, &jobs.FundingEvents, &jobs.WebhookDeliveries); err != nil {
+		return fmt.Errorf("failed to count failed jobs: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT severity, COUNT(*), COUNT(*) FILTER (WHERE resolved_at IS NULL AND NOT auto_corrected)
+		FROM reconciliation_exceptions
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY severity
+	`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to count reconciliation exceptions: %w", err)
+	}
+	defer rows.Close()
+	digest.Reconciliation.BySeverity = make(map[string]int)
+	for rows.Next() {
+		var severity string
+		var count, unresolved int
+		if err := rows.Scan(&severity, &count, &unresolved); err != nil {
+			return fmt.Errorf("failed to scan reconciliation exceptions: %w", err)
+		}
+		digest.Reconciliation.BySeverity[severity] = count
+		digest.Reconciliation.Exceptions += count
+		digest.Reconciliation.Unresolved += unresolved
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reconciliation exceptions: %w", err)
+	}
+
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM ai_chat_messages WHERE role = 'assistant' AND created_at >= $1 AND created_at < $2),
+			(SELECT COUNT(DISTINCT user_id) FROM ai_chat_sessions WHERE last_message_at >= $1 AND last_message_at < $2),
+			(SELECT COUNT(*) FROM ai_summaries WHERE created_at >= $1 AND created_at < $2)
+	`, from, to).Scan(&digest.AIUsage.ChatMessages, &digest.AIUsage.ActiveChatUsers, &digest.AIUsage.Summaries); err != nil {
+		return fmt.Errorf("failed to count ai usage: %w", err)
+	}
+
+	return nil
+}
+
+// volume counts deposits or withdrawals created in the window. The volume
+// leaves out failed movements, which are counted separately.
+func (r *OpsDigestRepository) volume(ctx context.Context, table string, from, to time.Time) (entities.OpsDigestVolume, error) {
+	var result entities.OpsDigestVolume
+	var volume decimal.Decimal
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(amount) FILTER (WHERE status <> 'failed'), 0), COUNT(*) FILTER (WHERE status = 'failed')
+		FROM `+table+`
+		WHERE created_at >= $1 AND created_at < $2
+	`, from, to).Scan(&result.Count, &volume, &result.Failed)
+	if err != nil {
+		return result, fmt.Errorf("failed to sum %s: %w", table, err)
+	}
+	result.Volume = volume.StringFixed(2)
+	return result, nil
+}
+
+// ClaimDelivery reserves a day's digest for this instance. It returns false if
+// another instance has already claimed it.
+func (r *OpsDigestRepository) ClaimDelivery(ctx context.Context, date time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO ops_digest_runs (digest_date, claimed_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (digest_date) DO NOTHING
+	`, date.Format("2006-01-02"))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim ops digest: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// CompleteDelivery records how a claimed digest was delivered
+func (r *OpsDigestRepository) CompleteDelivery(ctx context.Context, date time.Time, recipients int, deliveryErr error) error {
+	var message sql.NullString
+	if deliveryErr != nil {
+		message = sql.NullString{String: deliveryErr.Error(), Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE ops_digest_runs SET sent_at = NOW(), recipients = $2, error_message = $3 WHERE digest_date = $1
+	`, date.Format("2006-01-02"), recipients, message)
+	if err != nil {
+		r.logger.Error("Failed to record ops digest delivery", zap.Error(err))
+		return fmt.Errorf("failed to record ops digest delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// OrderInterventionRepository finds live orders stuck in flight and records
+// the administrator actions taken on them
+type OrderInterventionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewOrderInterventionRepository creates a new order intervention repository
+func NewOrderInterventionRepository(db *sql.DB, logger *zap.Logger) *OrderInterventionRepository {
+	return &OrderInterventionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const stuckOrderColumns = orderColumns + `, resolution_note, resolved_by, resolved_at`
+
+// ListStuck returns in-flight orders not updated since before, oldest first
+func (r *OrderInterventionRepository) ListStuck(ctx context.Context, before time.Time, limit int) ([]*entities.StuckOrder, error) {
+	query := `
+		SELECT ` + stuckOrderColumns + `
+		FROM orders
+		WHERE status IN ('accepted', 'pending', 'partially_filled') AND updated_at < $1
+		ORDER BY updated_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		r.logger.Error("Failed to query stuck orders", zap.Error(err))
+		return nil, fmt.Errorf("failed to query stuck orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*entities.StuckOrder
+	for rows.Next() {
+		order, err := scanStuckOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stuck order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stuck orders: %w", err)
+	}
+	return orders, nil
+}
+
+// GetOrder returns an order with its resolution note, or nil if there is none
+func (r *OrderInterventionRepository) GetOrder(ctx context.Context, id uuid.UUID) (*entities.StuckOrder, error) {
+	query := `SELECT ` + stuckOrderColumns + ` FROM orders WHERE id = $1`
+
+	order, err := scanStuckOrder(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return order, nil
+}
+
+// TransitionInFlight moves an order from the in-flight status it was read in
+// to status. It returns false if the order has moved on since, so a fill
+// racing the intervention is not overwritten.
+func (r *OrderInterventionRepository) TransitionInFlight(ctx context.Context, id uuid.UUID, from, to entities.OrderStatus) (bool, error) {
+	query := `
+		UPDATE orders
+		SET status = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $2 AND status IN ('accepted', 'pending', 'partially_filled')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, from, to)
+	if err != nil {
+		r.logger.Error("Failed to transition stuck order", zap.Error(err), zap.String("order_id", id.String()))
+		return false, fmt.Errorf("failed to update order: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// Record stores an intervention and makes its note the order's resolution note
+func (r *OrderInterventionRepository) Record(ctx context.Context, intervention *entities.OrderIntervention) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO order_interventions (id, order_id, admin_id, action, previous_status, new_status,
+			buying_power_delta, position_quantity_delta, position_value_delta, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		intervention.ID,
+		intervention.OrderID,
+		intervention.AdminID,
+		intervention.Action,
+		intervention.PreviousStatus,
+		intervention.NewStatus,
+		intervention.BuyingPowerDelta,
+		intervention.PositionQuantityDelta,
+		intervention.PositionValueDelta,
+		intervention.Note,
+		intervention.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to record order intervention", zap.Error(err), zap.String("order_id", intervention.OrderID.String()))
+		return fmt.Errorf("failed to record order intervention: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE orders SET resolution_note = $2, resolved_by = $3, resolved_at = $4 WHERE id = $1
+	`, intervention.OrderID, intervention.Note, intervention.AdminID, intervention.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set resolution note: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order intervention: %w", err)
+	}
+	return nil
+}
+
+// ListInterventions returns the actions taken on an order, oldest first
+func (r *OrderInterventionRepository) ListInterventions(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderIntervention, error) {
+	query := `
+		SELECT id, order_id, admin_id, action, previous_status, new_status,
+			buying_power_delta, position_quantity_delta, position_value_delta, note, created_at
+		FROM order_interventions
+		WHERE order_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order interventions: %w", err)
+	}
+	defer rows.Close()
+
+	var interventions []*entities.OrderIntervention
+	for rows.Next() {
+		intervention := &entities.OrderIntervention{}
+		if err := rows.Scan(
+			&intervention.ID,
+			&intervention.OrderID,
+			&intervention.AdminID,
+			&intervention.Action,
+			&intervention.PreviousStatus,
+			&intervention.NewStatus,
+			&intervention.BuyingPowerDelta,
+			&intervention.PositionQuantityDelta,
+			&intervention.PositionValueDelta,
+			&intervention.Note,
+			&intervention.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order intervention: %w", err)
+		}
+		interventions = append(interventions, intervention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order interventions: %w", err)
+	}
+	return interventions, nil
+}
+
+func scanStuckOrder(row orderScanner) (*entities.StuckOrder, error) {
+	stuck := &entities.StuckOrder{}
+	var note sql.NullString
+	var resolvedBy uuid.NullUUID
+	var resolvedAt sql.NullTime
+	order, err := scanOrder(&stuckOrderScanner{row: row, extra: []interface{}{&note, &resolvedBy, &resolvedAt}})
+	if err != nil {
+		return nil, err
+	}
+	stuck.Order = *order
+	if note.Valid {
+		stuck.ResolutionNote = &note.String
+	}
+	if resolvedBy.Valid {
+		stuck.ResolvedBy = &resolvedBy.UUID
+	}
+	if resolvedAt.Valid {
+		stuck.ResolvedAt = &resolvedAt.Time
+	}
+	return stuck, nil
+}
+
+// stuckOrderScanner appends the resolution columns to the order columns
+// scanOrder reads
+type stuckOrderScanner struct {
+	row   orderScanner
+	extra []interface{}
+}
+
+func (s *stuckOrderScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
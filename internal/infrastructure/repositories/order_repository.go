@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"go.uber.org/zap"
 )
@@ -13,13 +14,28 @@ import (
 // OrderRepository handles order database operations
 type OrderRepository struct {
 	db     *sql.DB
+	table  string
 	logger *zap.Logger
 }
 
+const orderColumns = `id, user_id, basket_id, side, amount, status, brokerage_ref,
+	order_type, limit_price, time_in_force, expires_at, triggered_at, paper, created_at, updated_at`
+
 // NewOrderRepository creates a new order repository instance
 func NewOrderRepository(db *sql.DB, logger *zap.Logger) *OrderRepository {
 	return &OrderRepository{
 		db:     db,
+		table:  "orders",
+		logger: logger,
+	}
+}
+
+// NewPaperOrderRepository creates an order repository over simulated paper
+// trading orders, which live apart from brokerage orders
+func NewPaperOrderRepository(db *sql.DB, logger *zap.Logger) *OrderRepository {
+	return &OrderRepository{
+		db:     db,
+		table:  "paper_orders",
 		logger: logger,
 	}
 }
@@ -27,10 +43,20 @@ func NewOrderRepository(db *sql.DB, logger *zap.Logger) *OrderRepository {
 // Create creates a new order record
 func (r *OrderRepository) Create(ctx context.Context, order *entities.Order) error {
 	query := `
-		INSERT INTO orders (id, user_id, basket_id, side, amount, status, brokerage_ref, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO ` + r.table + ` (id, user_id, basket_id, side, amount, status, brokerage_ref,
+			order_type, limit_price, time_in_force, expires_at, paper, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
+	orderType := order.Type
+	if orderType == "" {
+		orderType = entities.OrderTypeMarket
+	}
+	timeInForce := order.TimeInForce
+	if timeInForce == "" {
+		timeInForce = entities.TimeInForceDay
+	}
+
 	_, err := r.db.ExecContext(ctx, query,
 		order.ID,
 		order.UserID,
@@ -39,6 +65,11 @@ func (r *OrderRepository) Create(ctx context.Context, order *entities.Order) err
 		order.Amount,
 		order.Status,
 		order.BrokerageRef,
+		orderType,
+		order.LimitPrice,
+		timeInForce,
+		order.ExpiresAt,
+		order.Paper,
 		order.CreatedAt,
 		order.UpdatedAt,
 	)
@@ -63,23 +94,12 @@ func (r *OrderRepository) Create(ctx context.Context, order *entities.Order) err
 // GetByID retrieves an order by ID
 func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Order, error) {
 	query := `
-		SELECT id, user_id, basket_id, side, amount, status, brokerage_ref, created_at, updated_at
-		FROM orders
+		SELECT ` + orderColumns + `
+		FROM ` + r.table + `
 		WHERE id = $1
 	`
 
-	order := &entities.Order{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&order.ID,
-		&order.UserID,
-		&order.BasketID,
-		&order.Side,
-		&order.Amount,
-		&order.Status,
-		&order.BrokerageRef,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-	)
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		r.logger.Debug("Order not found", zap.String("order_id", id.String()))
@@ -101,8 +121,8 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, lim
 
 	if status != nil {
 		query = `
-			SELECT id, user_id, basket_id, side, amount, status, brokerage_ref, created_at, updated_at
-			FROM orders
+			SELECT ` + orderColumns + `
+			FROM ` + r.table + `
 			WHERE user_id = $1 AND status = $2
 			ORDER BY created_at DESC
 			LIMIT $3 OFFSET $4
@@ -110,8 +130,8 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, lim
 		args = []interface{}{userID, *status, limit, offset}
 	} else {
 		query = `
-			SELECT id, user_id, basket_id, side, amount, status, brokerage_ref, created_at, updated_at
-			FROM orders
+			SELECT ` + orderColumns + `
+			FROM ` + r.table + `
 			WHERE user_id = $1
 			ORDER BY created_at DESC
 			LIMIT $2 OFFSET $3
@@ -126,29 +146,10 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, lim
 	}
 	defer rows.Close()
 
-	var orders []*entities.Order
-	for rows.Next() {
-		order := &entities.Order{}
-		if err := rows.Scan(
-			&order.ID,
-			&order.UserID,
-			&order.BasketID,
-			&order.Side,
-			&order.Amount,
-			&order.Status,
-			&order.BrokerageRef,
-			&order.CreatedAt,
-			&order.UpdatedAt,
-		); err != nil {
-			r.logger.Error("Failed to scan order row", zap.Error(err))
-			return nil, fmt.Errorf("failed to scan order: %w", err)
-		}
-		orders = append(orders, order)
-	}
-
-	if err := rows.Err(); err != nil {
-		r.logger.Error("Error iterating order rows", zap.Error(err))
-		return nil, fmt.Errorf("error iterating orders: %w", err)
+	orders, err := scanOrders(rows)
+	if err != nil {
+		r.logger.Error("Failed to scan order rows", zap.Error(err))
+		return nil, err
 	}
 
 	r.logger.Debug("Retrieved orders", zap.String("user_id", userID.String()), zap.Int("count", len(orders)))
@@ -158,7 +159,7 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, lim
 // UpdateStatus updates an order's status and optionally sets the brokerage reference
 func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.OrderStatus, brokerageRef *string) error {
 	query := `
-		UPDATE orders
+		UPDATE ` + r.table + `
 		SET status = $1, brokerage_ref = $2, updated_at = NOW()
 		WHERE id = $3
 	`
@@ -189,3 +190,115 @@ func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 	)
 	return nil
 }
+
+// ListOpenLimitOrders returns resting limit orders, oldest first
+func (r *OrderRepository) ListOpenLimitOrders(ctx context.Context, limit int) ([]*entities.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM ` + r.table + `
+		WHERE status = 'open'
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open limit orders: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOrders(rows)
+}
+
+// TriggerLimitOrder moves a resting limit order to accepted for submission.
+// It returns false if the order is no longer open, so a cancellation racing
+// the monitor cannot also be executed.
+func (r *OrderRepository) TriggerLimitOrder(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE ` + r.table + `
+		SET status = 'accepted', triggered_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`
+
+	return r.execTransition(ctx, query, id)
+}
+
+// CloseOpenOrder cancels or expires a resting limit order. It returns false
+// if the order is no longer open.
+func (r *OrderRepository) CloseOpenOrder(ctx context.Context, id uuid.UUID, status entities.OrderStatus) (bool, error) {
+	query := `
+		UPDATE ` + r.table + `
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`
+
+	return r.execTransition(ctx, query, id, status)
+}
+
+func (r *OrderRepository) execTransition(ctx context.Context, query string, id uuid.UUID, args ...interface{}) (bool, error) {
+	result, err := r.db.ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		r.logger.Error("Failed to transition open order", zap.Error(err), zap.String("order_id", id.String()))
+		return false, fmt.Errorf("failed to update open order: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+type orderScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(row orderScanner) (*entities.Order, error) {
+	order := &entities.Order{}
+	var limitPrice decimal.NullDecimal
+	var expiresAt, triggeredAt sql.NullTime
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.BasketID,
+		&order.Side,
+		&order.Amount,
+		&order.Status,
+		&order.BrokerageRef,
+		&order.Type,
+		&limitPrice,
+		&order.TimeInForce,
+		&expiresAt,
+		&triggeredAt,
+		&order.Paper,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if limitPrice.Valid {
+		order.LimitPrice = &limitPrice.Decimal
+	}
+	if expiresAt.Valid {
+		order.ExpiresAt = &expiresAt.Time
+	}
+	if triggeredAt.Valid {
+		order.TriggeredAt = &triggeredAt.Time
+	}
+	return order, nil
+}
+
+func scanOrders(rows *sql.Rows) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+	return orders, nil
+}
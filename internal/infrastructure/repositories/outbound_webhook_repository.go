@@ -0,0 +1,397 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// OutboundWebhookRepository persists partner webhook endpoints and the
+// delivery log. Endpoint signing secrets are sealed with the field encryptor
+// when one is configured.
+type OutboundWebhookRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+	fields fieldCipher
+}
+
+// NewOutboundWebhookRepository creates a new outbound webhook repository
+func NewOutboundWebhookRepository(db *sql.DB, logger *zap.Logger) *OutboundWebhookRepository {
+	return &OutboundWebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetFieldEncryptor enables encryption of endpoint secrets at rest
+func (r *OutboundWebhookRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+// RotateFieldEncryption re-encrypts endpoint secrets under the active key version
+func (r *OutboundWebhookRepository) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, r.db, r.fields.enc, "webhook_endpoints", []encryptedColumn{
+		{name: "secret"},
+	}, batchSize)
+}
+
+var webhookSecretField = fieldColumn{table: "webhook_endpoints", column: "secret"}
+
+const webhookEndpointColumns = `
+	id, url, description, event_types, secret, is_active, created_by, tenant_id, created_at, updated_at`
+
+// CreateEndpoint inserts a new endpoint
+func (r *OutboundWebhookRepository) CreateEndpoint(ctx context.Context, endpoint *entities.WebhookEndpoint) error {
+	secret, err := r.fields.seal(webhookSecretField, endpoint.ID, endpoint.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_endpoints (id, url, description, event_types, secret, is_active, created_by, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		endpoint.ID,
+		endpoint.URL,
+		endpoint.Description,
+		pq.Array(webhookEventStrings(endpoint.EventTypes)),
+		secret,
+		endpoint.IsActive,
+		endpoint.CreatedBy,
+		endpoint.TenantID,
+		endpoint.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to create webhook endpoint", zap.Error(err), zap.String("url", endpoint.URL))
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// GetEndpoint retrieves an endpoint including its decrypted secret
+func (r *OutboundWebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*entities.WebhookEndpoint, error) {
+	endpoint, err := r.scanEndpoint(r.db.QueryRowContext(ctx,
+		`SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrWebhookEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+// ListEndpoints returns all endpoints, newest first
+func (r *OutboundWebhookRepository) ListEndpoints(ctx context.Context) ([]*entities.WebhookEndpoint, error) {
+	return r.queryEndpoints(ctx,
+		`SELECT `+webhookEndpointColumns+` FROM webhook_endpoints ORDER BY created_at DESC`)
+}
+
+// ListTenantEndpoints returns a developer tenant's endpoints, newest first
+func (r *OutboundWebhookRepository) ListTenantEndpoints(ctx context.Context, tenantID uuid.UUID) ([]*entities.WebhookEndpoint, error) {
+	return r.queryEndpoints(ctx, `
+		SELECT `+webhookEndpointColumns+` FROM webhook_endpoints
+		WHERE tenant_id = $1 ORDER BY created_at DESC`, tenantID)
+}
+
+// ListSubscribedEndpoints returns active partner endpoints subscribed to an
+// event type. Developer tenant endpoints are sandbox-only and never included.
+func (r *OutboundWebhookRepository) ListSubscribedEndpoints(ctx context.Context, eventType entities.WebhookEventType) ([]*entities.WebhookEndpoint, error) {
+	return r.queryEndpoints(ctx, `
+		SELECT `+webhookEndpointColumns+` FROM webhook_endpoints
+		WHERE is_active AND tenant_id IS NULL AND $1 = ANY(event_types)`, string(eventType))
+}
+
+// UpdateEndpoint persists url, subscription, status and secret changes
+func (r *OutboundWebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *entities.WebhookEndpoint) error {
+	secret, err := r.fields.seal(webhookSecretField, endpoint.ID, endpoint.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	query := `
+		UPDATE webhook_endpoints SET
+			url = $2, description = $3, event_types = $4, secret = $5, is_active = $6, updated_at = $7
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		endpoint.ID,
+		endpoint.URL,
+		endpoint.Description,
+		pq.Array(webhookEventStrings(endpoint.EventTypes)),
+		secret,
+		endpoint.IsActive,
+		endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log
+func (r *OutboundWebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+// CreateDelivery queues a delivery. Publishing the same event to an endpoint
+// twice is a no-op.
+func (r *OutboundWebhookRepository) CreateDelivery(ctx context.Context, d *entities.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID, d.EndpointID, d.EventID, string(d.EventType), []byte(d.Payload), string(d.Status), d.NextAttemptAt, d.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to queue webhook delivery", zap.Error(err),
+			zap.String("endpoint_id", d.EndpointID.String()), zap.String("event_type", string(d.EventType)))
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue leases up to limit deliveries whose next attempt is due by pushing
+// their next_attempt_at forward, so concurrent instances do not send the same
+// delivery twice. A crashed sender's lease simply expires.
+func (r *OutboundWebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN ('pending', 'failed') AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+
+	return r.queryDeliveries(ctx, query, now, now.Add(lease), limit)
+}
+
+// RecordAttempt stores the outcome of a delivery attempt
+func (r *OutboundWebhookRepository) RecordAttempt(ctx context.Context, d *entities.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries SET
+			status = $2, attempts = $3, next_attempt_at = $4, last_attempt_at = $5,
+			response_status = $6, response_body = $7, last_error = $8, delivered_at = $9
+		WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query,
+		d.ID, string(d.Status), d.Attempts, d.NextAttemptAt, d.LastAttemptAt,
+		d.ResponseStatus, d.ResponseBody, d.LastError, d.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// Reschedule makes a delivery due again immediately, keeping its attempt history
+func (r *OutboundWebhookRepository) Reschedule(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries SET status = 'pending', next_attempt_at = $2
+		WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule webhook delivery: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `
+	id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_attempt_at, response_status, response_body, last_error, delivered_at, created_at`
+
+// GetDelivery retrieves a delivery log entry
+func (r *OutboundWebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*entities.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(r.db.QueryRowContext(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return d, nil
+}
+
+// ListDeliveries returns the delivery log filtered by optional endpoint and status, newest first
+func (r *OutboundWebhookRepository) ListDeliveries(ctx context.Context, endpointID *uuid.UUID, status entities.WebhookDeliveryStatus, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE ($1::uuid IS NULL OR endpoint_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	var endpoint uuid.NullUUID
+	if endpointID != nil {
+		endpoint = uuid.NullUUID{UUID: *endpointID, Valid: true}
+	}
+	return r.queryDeliveries(ctx, query, endpoint, string(status), limit, offset)
+}
+
+func (r *OutboundWebhookRepository) queryEndpoints(ctx context.Context, query string, args ...interface{}) ([]*entities.WebhookEndpoint, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*entities.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := r.scanEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+func (r *OutboundWebhookRepository) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]*entities.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*entities.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+type webhookRowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *OutboundWebhookRepository) scanEndpoint(row webhookRowScanner) (*entities.WebhookEndpoint, error) {
+	endpoint := &entities.WebhookEndpoint{}
+	var eventTypes pq.StringArray
+	var createdBy, tenantID uuid.NullUUID
+
+	if err := row.Scan(
+		&endpoint.ID,
+		&endpoint.URL,
+		&endpoint.Description,
+		&eventTypes,
+		&endpoint.Secret,
+		&endpoint.IsActive,
+		&createdBy,
+		&tenantID,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	secret, err := r.fields.open(webhookSecretField, endpoint.ID, endpoint.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	endpoint.Secret = secret
+	for _, t := range eventTypes {
+		endpoint.EventTypes = append(endpoint.EventTypes, entities.WebhookEventType(t))
+	}
+	if createdBy.Valid {
+		endpoint.CreatedBy = &createdBy.UUID
+	}
+	if tenantID.Valid {
+		endpoint.TenantID = &tenantID.UUID
+	}
+	return endpoint, nil
+}
+
+func scanWebhookDelivery(row webhookRowScanner) (*entities.WebhookDelivery, error) {
+	d := &entities.WebhookDelivery{}
+	var eventType, status string
+	var payload []byte
+	var nextAttemptAt, lastAttemptAt, deliveredAt sql.NullTime
+	var responseStatus sql.NullInt64
+	var responseBody, lastError sql.NullString
+
+	if err := row.Scan(
+		&d.ID,
+		&d.EndpointID,
+		&d.EventID,
+		&eventType,
+		&payload,
+		&status,
+		&d.Attempts,
+		&nextAttemptAt,
+		&lastAttemptAt,
+		&responseStatus,
+		&responseBody,
+		&lastError,
+		&deliveredAt,
+		&d.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	d.EventType = entities.WebhookEventType(eventType)
+	d.Status = entities.WebhookDeliveryStatus(status)
+	d.Payload = payload
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if lastAttemptAt.Valid {
+		d.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if responseStatus.Valid {
+		code := int(responseStatus.Int64)
+		d.ResponseStatus = &code
+	}
+	if responseBody.Valid {
+		d.ResponseBody = &responseBody.String
+	}
+	if lastError.Valid {
+		d.LastError = &lastError.String
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+func webhookEventStrings(types []entities.WebhookEventType) []string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = string(t)
+	}
+	return out
+}
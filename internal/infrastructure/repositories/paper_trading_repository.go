@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// PaperTradingRepository persists users' trading modes and their simulated
+// paper balances
+type PaperTradingRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPaperTradingRepository creates a new paper trading repository
+func NewPaperTradingRepository(db *sql.DB, logger *zap.Logger) *PaperTradingRepository {
+	return &PaperTradingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetTradingProfile returns a user's trading mode and KYC status
+func (r *PaperTradingRepository) GetTradingProfile(ctx context.Context, userID uuid.UUID) (*entities.TradingProfile, error) {
+	profile := &entities.TradingProfile{}
+	var kycStatus sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT trading_mode, kyc_status FROM users WHERE id = $1`, userID).
+		Scan(&profile.Mode, &kycStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get trading profile: %w", err)
+	}
+	profile.KYCStatus = kycStatus.String
+	return profile, nil
+}
+
+// SetTradingMode records whether a user trades live or on paper
+func (r *PaperTradingRepository) SetTradingMode(ctx context.Context, userID uuid.UUID, mode entities.TradingMode) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET trading_mode = $2, updated_at = $3 WHERE id = $1`,
+		userID, mode, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set trading mode: %w", err)
+	}
+	return nil
+}
+
+// OpenAccount funds a paper account with its starting balance. Existing
+// accounts are left as they are, so re-enabling paper mode keeps history.
+func (r *PaperTradingRepository) OpenAccount(ctx context.Context, userID uuid.UUID, startingBalance decimal.Decimal) error {
+	query := `
+		INSERT INTO paper_balances (user_id, buying_power, starting_balance, currency, created_at, updated_at)
+		VALUES ($1, $2, $2, 'USD', $3, $3)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, startingBalance, time.Now()); err != nil {
+		r.logger.Error("Failed to open paper account", zap.Error(err), zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to open paper account: %w", err)
+	}
+	return nil
+}
+
+// GetAccount returns a user's paper buying power and starting balance
+func (r *PaperTradingRepository) GetAccount(ctx context.Context, userID uuid.UUID) (*entities.Balance, decimal.Decimal, error) {
+	query := `
+		SELECT user_id, buying_power, starting_balance, currency, updated_at
+		FROM paper_balances
+		WHERE user_id = $1
+	`
+
+	balance := &entities.Balance{}
+	var startingBalance decimal.Decimal
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&balance.UserID,
+		&balance.BuyingPower,
+		&startingBalance,
+		&balance.Currency,
+		&balance.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, decimal.Zero, fmt.Errorf("paper balance not found")
+		}
+		return nil, decimal.Zero, fmt.Errorf("failed to get paper balance: %w", err)
+	}
+	return balance, startingBalance, nil
+}
+
+// PaperBalances adapts the paper accounts to the investing service's balance
+// repository, so paper orders reserve and release simulated buying power
+func (r *PaperTradingRepository) PaperBalances() *PaperBalanceRepository {
+	return &PaperBalanceRepository{repo: r}
+}
+
+// PaperBalanceRepository exposes paper buying power to the investing service
+type PaperBalanceRepository struct {
+	repo *PaperTradingRepository
+}
+
+// Get returns the user's paper buying power
+func (r *PaperBalanceRepository) Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error) {
+	balance, _, err := r.repo.GetAccount(ctx, userID)
+	return balance, err
+}
+
+// DeductBuyingPower reserves paper buying power for an order
+func (r *PaperBalanceRepository) DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	query := `
+		UPDATE paper_balances
+		SET buying_power = buying_power - $2, updated_at = $3
+		WHERE user_id = $1 AND buying_power >= $2
+	`
+
+	result, err := r.repo.db.ExecContext(ctx, query, userID, amount, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to deduct paper buying power: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("insufficient paper buying power or account not found")
+	}
+	return nil
+}
+
+// AddBuyingPower credits sale proceeds and refunds to paper buying power
+func (r *PaperBalanceRepository) AddBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	query := `
+		UPDATE paper_balances
+		SET buying_power = buying_power + $2, updated_at = $3
+		WHERE user_id = $1
+	`
+
+	if _, err := r.repo.db.ExecContext(ctx, query, userID, amount, time.Now()); err != nil {
+		return fmt.Errorf("failed to add paper buying power: %w", err)
+	}
+	return nil
+}
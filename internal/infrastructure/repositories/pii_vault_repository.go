@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// PIIVaultRepository stores the PII vault's records and access log in the
+// pii_vault schema
+type PIIVaultRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPIIVaultRepository creates a new PII vault repository
+func NewPIIVaultRepository(db *sql.DB, logger *zap.Logger) *PIIVaultRepository {
+	return &PIIVaultRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateRecord stores a new vault record
+func (r *PIIVaultRepository) CreateRecord(ctx context.Context, record *entities.PIIVaultRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO pii_vault.records (token, subject_id, kind, ciphertext, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		record.Token, record.SubjectID, string(record.Kind), record.Ciphertext, record.CreatedAt, record.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create PII vault record: %w", err)
+	}
+	return nil
+}
+
+// GetRecord returns the record behind a token
+func (r *PIIVaultRepository) GetRecord(ctx context.Context, token string) (*entities.PIIVaultRecord, error) {
+	var record entities.PIIVaultRecord
+	err := r.db.QueryRowContext(ctx, `
+		SELECT token, subject_id, kind, ciphertext, created_at, updated_at
+		FROM pii_vault.records
+		WHERE token = $1`, token).Scan(
+		&record.Token, &record.SubjectID, &record.Kind, &record.Ciphertext, &record.CreatedAt, &record.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrPIIRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get PII vault record: %w", err)
+	}
+	return &record, nil
+}
+
+// UpdateRecord replaces a record's ciphertext
+func (r *PIIVaultRepository) UpdateRecord(ctx context.Context, token, ciphertext string, updatedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE pii_vault.records SET ciphertext = $2, updated_at = $3
+		WHERE token = $1`, token, ciphertext, updatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update PII vault record: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return entities.ErrPIIRecordNotFound
+	}
+	return nil
+}
+
+// LogAccess appends to the vault's access log
+func (r *PIIVaultRepository) LogAccess(ctx context.Context, entry *entities.PIIAccessEntry) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO pii_vault.access_log (id, token, subject_id, kind, accessor, action, purpose, accessed_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)`,
+		entry.ID, entry.Token, entry.SubjectID, string(entry.Kind), entry.Accessor, entry.Action, entry.Purpose, entry.AccessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to log PII vault access: %w", err)
+	}
+	return nil
+}
+
+// ListAccess returns a record's most recent access log entries, newest first
+func (r *PIIVaultRepository) ListAccess(ctx context.Context, token string, limit int) ([]*entities.PIIAccessEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, token, subject_id, COALESCE(kind, ''), accessor, action, purpose, accessed_at
+		FROM pii_vault.access_log
+		WHERE token = $1
+		ORDER BY accessed_at DESC
+		LIMIT $2`, token, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PII vault access: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*entities.PIIAccessEntry
+	for rows.Next() {
+		var entry entities.PIIAccessEntry
+		var subjectID uuid.NullUUID
+		if err := rows.Scan(&entry.ID, &entry.Token, &subjectID, &entry.Kind, &entry.Accessor,
+			&entry.Action, &entry.Purpose, &entry.AccessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan PII vault access: %w", err)
+		}
+		if subjectID.Valid {
+			entry.SubjectID = &subjectID.UUID
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
@@ -69,13 +69,10 @@ func (r *PortfolioRepository) GetPortfolioPerformance(ctx context.Context, userI
 			return nil, fmt.Errorf("failed to scan performance row: %w", err)
 		}
 
-		navFloat, _ := nav.Float64()
-		pnlFloat, _ := pnl.Float64()
-
 		points = append(points, &entities.PerformancePoint{
 			Date:  date,
-			Value: navFloat,
-			PnL:   pnlFloat,
+			Value: entities.NewMoney(nav, entities.CurrencyUSD),
+			PnL:   entities.NewMoney(pnl, entities.CurrencyUSD),
 		})
 	}
 
@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// PortfolioShareRepository persists portfolio share links and each user's
+// sharing settings
+type PortfolioShareRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPortfolioShareRepository creates a new portfolio share repository
+func NewPortfolioShareRepository(db *sql.DB, logger *zap.Logger) *PortfolioShareRepository {
+	return &PortfolioShareRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const portfolioShareLinkColumns = `
+	id, user_id, snapshot, expires_at, revoked_at, view_count, last_viewed_at, created_at`
+
+// CreateLink inserts a share link with its snapshot
+func (r *PortfolioShareRepository) CreateLink(ctx context.Context, link *entities.PortfolioShareLink) error {
+	snapshot, err := json.Marshal(link.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO portfolio_share_links (id, user_id, range, snapshot, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		link.ID, link.UserID, string(link.Snapshot.Range), snapshot, link.ExpiresAt, link.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create portfolio share link", zap.Error(err), zap.String("user_id", link.UserID.String()))
+		return fmt.Errorf("failed to create portfolio share link: %w", err)
+	}
+	return nil
+}
+
+// GetLink returns a share link by ID
+func (r *PortfolioShareRepository) GetLink(ctx context.Context, id uuid.UUID) (*entities.PortfolioShareLink, error) {
+	link, err := scanPortfolioShareLink(r.db.QueryRowContext(ctx, `
+		SELECT `+portfolioShareLinkColumns+`
+		FROM portfolio_share_links
+		WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrShareLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get portfolio share link: %w", err)
+	}
+	return link, nil
+}
+
+// ListLinks returns a user's share links, newest first
+func (r *PortfolioShareRepository) ListLinks(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.PortfolioShareLink, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+portfolioShareLinkColumns+`
+		FROM portfolio_share_links
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portfolio share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*entities.PortfolioShareLink
+	for rows.Next() {
+		link, err := scanPortfolioShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio share link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// CountLive counts a user's share links that are neither revoked nor expired
+func (r *PortfolioShareRepository) CountLive(ctx context.Context, userID uuid.UUID, at time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM portfolio_share_links
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2`, userID, at).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count portfolio share links: %w", err)
+	}
+	return count, nil
+}
+
+// RevokeLink revokes one of a user's share links. Revoking a revoked link
+// leaves its original revocation time.
+func (r *PortfolioShareRepository) RevokeLink(ctx context.Context, userID, id uuid.UUID, at time.Time) (*entities.PortfolioShareLink, error) {
+	link, err := scanPortfolioShareLink(r.db.QueryRowContext(ctx, `
+		UPDATE portfolio_share_links
+		SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+portfolioShareLinkColumns, id, userID, at))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrShareLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke portfolio share link: %w", err)
+	}
+	return link, nil
+}
+
+// RevokeAll revokes every unrevoked share link of a user and returns how
+// many it revoked
+func (r *PortfolioShareRepository) RevokeAll(ctx context.Context, userID uuid.UUID, at time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE portfolio_share_links
+		SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke portfolio share links: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke portfolio share links: %w", err)
+	}
+	return int(revoked), nil
+}
+
+// RecordView counts a view of a share link
+func (r *PortfolioShareRepository) RecordView(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE portfolio_share_links
+		SET view_count = view_count + 1, last_viewed_at = $2
+		WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to record portfolio share view: %w", err)
+	}
+	return nil
+}
+
+// GetSettings returns a user's sharing settings; users who never changed
+// them have sharing turned on
+func (r *PortfolioShareRepository) GetSettings(ctx context.Context, userID uuid.UUID) (*entities.PortfolioSharingSettings, error) {
+	settings := &entities.PortfolioSharingSettings{UserID: userID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT enabled, updated_at
+		FROM portfolio_sharing_settings
+		WHERE user_id = $1`, userID).Scan(&settings.Enabled, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		settings.Enabled = true
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio sharing settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveSettings stores a user's sharing settings
+func (r *PortfolioShareRepository) SaveSettings(ctx context.Context, settings *entities.PortfolioSharingSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO portfolio_sharing_settings (user_id, enabled, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at`,
+		settings.UserID, settings.Enabled, settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save portfolio sharing settings: %w", err)
+	}
+	return nil
+}
+
+func scanPortfolioShareLink(row adminCaseScanner) (*entities.PortfolioShareLink, error) {
+	var link entities.PortfolioShareLink
+	var snapshot []byte
+	var revokedAt, lastViewedAt sql.NullTime
+	if err := row.Scan(&link.ID, &link.UserID, &snapshot, &link.ExpiresAt, &revokedAt,
+		&link.ViewCount, &lastViewedAt, &link.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(snapshot, &link.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	if lastViewedAt.Valid {
+		link.LastViewedAt = &lastViewedAt.Time
+	}
+	return &link, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"go.uber.org/zap"
 )
@@ -13,6 +14,7 @@ import (
 // PositionRepository handles position database operations
 type PositionRepository struct {
 	db     *sql.DB
+	table  string
 	logger *zap.Logger
 }
 
@@ -20,6 +22,17 @@ type PositionRepository struct {
 func NewPositionRepository(db *sql.DB, logger *zap.Logger) *PositionRepository {
 	return &PositionRepository{
 		db:     db,
+		table:  "positions",
+		logger: logger,
+	}
+}
+
+// NewPaperPositionRepository creates a position repository over holdings
+// built by paper trading
+func NewPaperPositionRepository(db *sql.DB, logger *zap.Logger) *PositionRepository {
+	return &PositionRepository{
+		db:     db,
+		table:  "paper_positions",
 		logger: logger,
 	}
 }
@@ -28,7 +41,7 @@ func NewPositionRepository(db *sql.DB, logger *zap.Logger) *PositionRepository {
 func (r *PositionRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
 	query := `
 		SELECT id, user_id, basket_id, quantity, avg_price, market_value, updated_at
-		FROM positions
+		FROM ` + r.table + `
 		WHERE user_id = $1
 		ORDER BY market_value DESC
 	`
@@ -71,7 +84,7 @@ func (r *PositionRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 func (r *PositionRepository) GetByUserAndBasket(ctx context.Context, userID, basketID uuid.UUID) (*entities.Position, error) {
 	query := `
 		SELECT id, user_id, basket_id, quantity, avg_price, market_value, updated_at
-		FROM positions
+		FROM ` + r.table + `
 		WHERE user_id = $1 AND basket_id = $2
 	`
 
@@ -109,7 +122,7 @@ func (r *PositionRepository) GetByUserAndBasket(ctx context.Context, userID, bas
 // CreateOrUpdate creates a new position or updates an existing one
 func (r *PositionRepository) CreateOrUpdate(ctx context.Context, position *entities.Position) error {
 	query := `
-		INSERT INTO positions (id, user_id, basket_id, quantity, avg_price, market_value, updated_at)
+		INSERT INTO ` + r.table + ` (id, user_id, basket_id, quantity, avg_price, market_value, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (user_id, basket_id)
 		DO UPDATE SET
@@ -162,7 +175,7 @@ func (r *PositionRepository) GetPositionMetrics(ctx context.Context, userID uuid
 			p.avg_price,
 			p.market_value,
 			p.updated_at
-		FROM positions p
+		FROM ` + r.table + ` p
 		INNER JOIN baskets b ON p.basket_id = b.id
 		WHERE p.user_id = $1
 		ORDER BY p.market_value DESC
@@ -206,28 +219,24 @@ func (r *PositionRepository) GetPositionMetrics(ctx context.Context, userID uuid
 	}
 
 	// Calculate aggregate metrics
-	var totalValue float64
+	totalValue := decimal.Zero
 	allocationByBasket := make(map[string]float64)
 	positionMetrics := make([]entities.PositionMetrics, 0, len(positions))
 
 	for _, pos := range positions {
-		marketValue, _ := pos.MarketValue.Float64()
-		totalValue += marketValue
+		totalValue = totalValue.Add(pos.MarketValue)
 	}
 
 	for _, pos := range positions {
-		quantity, _ := pos.Quantity.Float64()
-		avgPrice, _ := pos.AvgPrice.Float64()
-		marketValue, _ := pos.MarketValue.Float64()
-		costBasis := quantity * avgPrice
-		unrealizedPL := marketValue - costBasis
+		costBasis := pos.Quantity.Mul(pos.AvgPrice)
+		unrealizedPL := pos.MarketValue.Sub(costBasis)
 		unrealizedPLPct := 0.0
-		if costBasis > 0 {
-			unrealizedPLPct = (unrealizedPL / costBasis) * 100
+		if costBasis.IsPositive() {
+			unrealizedPLPct = unrealizedPL.Div(costBasis).Mul(decimal.NewFromInt(100)).InexactFloat64()
 		}
 		weight := 0.0
-		if totalValue > 0 {
-			weight = marketValue / totalValue
+		if totalValue.IsPositive() {
+			weight = pos.MarketValue.Div(totalValue).InexactFloat64()
 		}
 
 		basketName := basketNames[pos.BasketID]
@@ -236,10 +245,10 @@ func (r *PositionRepository) GetPositionMetrics(ctx context.Context, userID uuid
 		positionMetrics = append(positionMetrics, entities.PositionMetrics{
 			BasketID:        pos.BasketID,
 			BasketName:      basketName,
-			Quantity:        quantity,
-			AvgPrice:        avgPrice,
-			CurrentValue:    marketValue,
-			UnrealizedPL:    unrealizedPL,
+			Quantity:        pos.Quantity,
+			AvgPrice:        entities.NewMoney(pos.AvgPrice, entities.CurrencyUSD),
+			CurrentValue:    entities.NewMoney(pos.MarketValue, entities.CurrencyUSD),
+			UnrealizedPL:    entities.NewMoney(unrealizedPL, entities.CurrencyUSD),
 			UnrealizedPLPct: unrealizedPLPct,
 			Weight:          weight,
 		})
@@ -248,12 +257,12 @@ func (r *PositionRepository) GetPositionMetrics(ctx context.Context, userID uuid
 	r.logger.Debug("Retrieved position metrics",
 		zap.String("user_id", userID.String()),
 		zap.Int("positions_count", len(positionMetrics)),
-		zap.Float64("total_value", totalValue),
+		zap.String("total_value", totalValue.String()),
 	)
 
 	// Return portfolio metrics with positions and allocations
 	return &entities.PortfolioMetrics{
-		TotalValue:         totalValue,
+		TotalValue:         entities.NewMoney(totalValue, entities.CurrencyUSD),
 		Positions:          positionMetrics,
 		AllocationByBasket: allocationByBasket,
 		// Performance history and risk metrics will be populated by the service
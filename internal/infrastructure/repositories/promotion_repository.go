@@ -0,0 +1,400 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// PromotionRepository persists promotions and the credit granted under them
+type PromotionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *sql.DB, logger *zap.Logger) *PromotionRepository {
+	return &PromotionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const promotionColumns = `
+	id, code, name, description, amount, trigger, vesting_days, max_grants,
+	grant_count, active, starts_at, ends_at, created_by, created_at, updated_at`
+
+const promotionGrantColumns = `
+	g.id, g.promotion_id, p.code, g.user_id, g.amount, g.status, g.vests_at,
+	g.granted_by, g.grant_ledger_tx_id, g.settle_ledger_tx_id, g.forfeit_reason,
+	g.settled_at, g.created_at, g.updated_at`
+
+// CreatePromotion inserts a promotion
+func (r *PromotionRepository) CreatePromotion(ctx context.Context, promotion *entities.Promotion) error {
+	query := `
+		INSERT INTO promotions (
+			id, code, name, description, amount, trigger, vesting_days, max_grants,
+			active, starts_at, ends_at, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		promotion.ID, promotion.Code, promotion.Name, promotion.Description, promotion.Amount,
+		string(promotion.Trigger), promotion.VestingDays, promotion.MaxGrants, promotion.Active,
+		promotion.StartsAt, promotion.EndsAt, promotion.CreatedBy, promotion.CreatedAt, promotion.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrPromotionCodeTaken
+		}
+		r.logger.Error("Failed to create promotion", zap.Error(err), zap.String("code", promotion.Code))
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+	return nil
+}
+
+// GetPromotion retrieves a promotion
+func (r *PromotionRepository) GetPromotion(ctx context.Context, id uuid.UUID) (*entities.Promotion, error) {
+	promotion, err := scanPromotion(r.db.QueryRowContext(ctx,
+		`SELECT `+promotionColumns+` FROM promotions WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+// ListPromotions returns every promotion, newest first
+func (r *PromotionRepository) ListPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	return r.queryPromotions(ctx, `SELECT `+promotionColumns+` FROM promotions ORDER BY created_at DESC`)
+}
+
+// ListRunning returns active promotions with the trigger whose window includes at
+func (r *PromotionRepository) ListRunning(ctx context.Context, trigger entities.PromotionTrigger, at time.Time) ([]*entities.Promotion, error) {
+	query := `
+		SELECT ` + promotionColumns + `
+		FROM promotions
+		WHERE trigger = $1 AND active = true
+		  AND (starts_at IS NULL OR starts_at <= $2)
+		  AND (ends_at IS NULL OR ends_at > $2)
+		ORDER BY created_at`
+	return r.queryPromotions(ctx, query, string(trigger), at)
+}
+
+// SetPromotionActive pauses or resumes a promotion
+func (r *PromotionRepository) SetPromotionActive(ctx context.Context, id uuid.UUID, active bool) (*entities.Promotion, error) {
+	promotion, err := scanPromotion(r.db.QueryRowContext(ctx, `
+		UPDATE promotions SET active = $2, updated_at = $3
+		WHERE id = $1
+		RETURNING `+promotionColumns, id, active, time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrPromotionNotFound
+		}
+		return nil, fmt.Errorf("failed to update promotion: %w", err)
+	}
+	return promotion, nil
+}
+
+// ClaimGrant inserts a grant and counts it against the promotion's limit in
+// one transaction
+func (r *PromotionRepository) ClaimGrant(ctx context.Context, grant *entities.PromotionGrant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE promotions SET grant_count = grant_count + 1, updated_at = $2
+		WHERE id = $1 AND (max_grants IS NULL OR grant_count < max_grants)`,
+		grant.PromotionID, grant.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to count promotion grant: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrPromotionLimitReached
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO promotion_grants (
+			id, promotion_id, user_id, amount, status, vests_at, granted_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)`,
+		grant.ID, grant.PromotionID, grant.UserID, grant.Amount, string(grant.Status),
+		grant.VestsAt, grant.GrantedBy, grant.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrPromotionAlreadyGranted
+		}
+		return fmt.Errorf("failed to create promotion grant: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit promotion grant: %w", err)
+	}
+	return nil
+}
+
+// ReleaseGrant deletes an unfunded grant and returns its slot to the promotion
+func (r *PromotionRepository) ReleaseGrant(ctx context.Context, grant *entities.PromotionGrant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM promotion_grants WHERE id = $1 AND grant_ledger_tx_id IS NULL`, grant.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete promotion grant: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE promotions SET grant_count = grant_count - 1, updated_at = $2
+		WHERE id = $1 AND grant_count > 0`, grant.PromotionID, time.Now()); err != nil {
+		return fmt.Errorf("failed to release promotion grant: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit promotion grant release: %w", err)
+	}
+	return nil
+}
+
+// SetGrantLedgerTx records the ledger transaction that funded a grant
+func (r *PromotionRepository) SetGrantLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE promotion_grants SET grant_ledger_tx_id = $2, updated_at = $3 WHERE id = $1`,
+		id, ledgerTxID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record promotion grant ledger transaction: %w", err)
+	}
+	return nil
+}
+
+// SettleGrant moves an unvested grant to its final status. It reports false
+// when the grant was already settled by another sweep.
+func (r *PromotionRepository) SettleGrant(ctx context.Context, grant *entities.PromotionGrant) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE promotion_grants SET
+			status = $2, forfeit_reason = $3, settled_at = $4, updated_at = $5
+		WHERE id = $1 AND status = 'unvested'`,
+		grant.ID, string(grant.Status), grant.ForfeitReason, grant.SettledAt, grant.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to settle promotion grant: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// UnsettleGrant returns a grant to unvested after its settlement failed
+func (r *PromotionRepository) UnsettleGrant(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE promotion_grants SET
+			status = 'unvested', forfeit_reason = NULL, settled_at = NULL, updated_at = $2
+		WHERE id = $1`, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to revert promotion grant: %w", err)
+	}
+	return nil
+}
+
+// SetSettleLedgerTx records the ledger transaction that vested or forfeited a grant
+func (r *PromotionRepository) SetSettleLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE promotion_grants SET settle_ledger_tx_id = $2, updated_at = $3 WHERE id = $1`,
+		id, ledgerTxID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record promotion settlement ledger transaction: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns a user's grants, newest first
+func (r *PromotionRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.PromotionGrant, error) {
+	query := `
+		SELECT ` + promotionGrantColumns + `
+		FROM promotion_grants g
+		JOIN promotions p ON p.id = g.promotion_id
+		WHERE g.user_id = $1 AND g.grant_ledger_tx_id IS NOT NULL
+		ORDER BY g.created_at DESC`
+	return r.queryGrants(ctx, query, userID)
+}
+
+// ListVestable returns funded, unvested grants past their vesting date whose
+// account was still open on that date
+func (r *PromotionRepository) ListVestable(ctx context.Context, now time.Time, limit int) ([]*entities.PromotionGrant, error) {
+	query := `
+		SELECT ` + promotionGrantColumns + `
+		FROM promotion_grants g
+		JOIN promotions p ON p.id = g.promotion_id
+		JOIN users u ON u.id = g.user_id
+		WHERE g.status = 'unvested' AND g.grant_ledger_tx_id IS NOT NULL AND g.vests_at <= $1
+		  AND (u.is_active = true OR u.closed_at >= g.vests_at)
+		ORDER BY g.vests_at
+		LIMIT $2`
+	return r.queryGrants(ctx, query, now, limit)
+}
+
+// ListForfeitable returns funded, unvested grants whose account was closed
+// before they vested
+func (r *PromotionRepository) ListForfeitable(ctx context.Context, limit int) ([]*entities.PromotionGrant, error) {
+	query := `
+		SELECT ` + promotionGrantColumns + `
+		FROM promotion_grants g
+		JOIN promotions p ON p.id = g.promotion_id
+		JOIN users u ON u.id = g.user_id
+		WHERE g.status = 'unvested' AND g.grant_ledger_tx_id IS NOT NULL
+		  AND u.is_active = false AND COALESCE(u.closed_at, NOW()) < g.vests_at
+		ORDER BY g.created_at
+		LIMIT $1`
+	return r.queryGrants(ctx, query, limit)
+}
+
+func (r *PromotionRepository) queryPromotions(ctx context.Context, query string, args ...interface{}) ([]*entities.Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var promotions []*entities.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promotion: %w", err)
+		}
+		promotions = append(promotions, promotion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate promotions: %w", err)
+	}
+	return promotions, nil
+}
+
+func (r *PromotionRepository) queryGrants(ctx context.Context, query string, args ...interface{}) ([]*entities.PromotionGrant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promotion grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []*entities.PromotionGrant
+	for rows.Next() {
+		grant, err := scanPromotionGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promotion grant: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate promotion grants: %w", err)
+	}
+	return grants, nil
+}
+
+func scanPromotion(row adminCaseScanner) (*entities.Promotion, error) {
+	promotion := &entities.Promotion{}
+	var trigger string
+	var description sql.NullString
+	var maxGrants sql.NullInt64
+	var startsAt, endsAt sql.NullTime
+	var createdBy uuid.NullUUID
+
+	if err := row.Scan(
+		&promotion.ID,
+		&promotion.Code,
+		&promotion.Name,
+		&description,
+		&promotion.Amount,
+		&trigger,
+		&promotion.VestingDays,
+		&maxGrants,
+		&promotion.GrantCount,
+		&promotion.Active,
+		&startsAt,
+		&endsAt,
+		&createdBy,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	promotion.Trigger = entities.PromotionTrigger(trigger)
+	if description.Valid {
+		promotion.Description = &description.String
+	}
+	if maxGrants.Valid {
+		max := int(maxGrants.Int64)
+		promotion.MaxGrants = &max
+	}
+	if startsAt.Valid {
+		promotion.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		promotion.EndsAt = &endsAt.Time
+	}
+	if createdBy.Valid {
+		promotion.CreatedBy = &createdBy.UUID
+	}
+	return promotion, nil
+}
+
+func scanPromotionGrant(row adminCaseScanner) (*entities.PromotionGrant, error) {
+	grant := &entities.PromotionGrant{}
+	var status string
+	var grantedBy, grantLedgerTxID, settleLedgerTxID uuid.NullUUID
+	var forfeitReason sql.NullString
+	var settledAt sql.NullTime
+
+	if err := row.Scan(
+		&grant.ID,
+		&grant.PromotionID,
+		&grant.PromotionCode,
+		&grant.UserID,
+		&grant.Amount,
+		&status,
+		&grant.VestsAt,
+		&grantedBy,
+		&grantLedgerTxID,
+		&settleLedgerTxID,
+		&forfeitReason,
+		&settledAt,
+		&grant.CreatedAt,
+		&grant.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	grant.Status = entities.PromotionGrantStatus(status)
+	if grantedBy.Valid {
+		grant.GrantedBy = &grantedBy.UUID
+	}
+	if grantLedgerTxID.Valid {
+		grant.GrantLedgerTxID = &grantLedgerTxID.UUID
+	}
+	if settleLedgerTxID.Valid {
+		grant.SettleLedgerTxID = &settleLedgerTxID.UUID
+	}
+	if forfeitReason.Valid {
+		grant.ForfeitReason = &forfeitReason.String
+	}
+	if settledAt.Valid {
+		grant.SettledAt = &settledAt.Time
+	}
+	return grant, nil
+}
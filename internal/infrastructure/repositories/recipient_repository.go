@@ -0,0 +1,433 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// RecipientRepository persists saved withdrawal recipients. Bank account
+// numbers are sealed with the field encryptor when one is configured.
+type RecipientRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+	fields fieldCipher
+}
+
+// NewRecipientRepository creates a new withdrawal recipient repository
+func NewRecipientRepository(db *sql.DB, logger *zap.Logger) *RecipientRepository {
+	return &RecipientRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetFieldEncryptor enables encryption of bank account numbers at rest
+func (r *RecipientRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+// RotateFieldEncryption re-encrypts account numbers under the active key version
+func (r *RecipientRepository) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, r.db, r.fields.enc, "withdrawal_recipients", []encryptedColumn{
+		{name: "account_number"},
+	}, batchSize)
+}
+
+// fingerprint hashes the recipient's destination so matching destinations
+// can be found without storing account numbers in the clear. It is keyed
+// with the blind index key when field encryption is configured.
+func (r *RecipientRepository) fingerprint(recipient *entities.WithdrawalRecipient) string {
+	key := recipient.DestinationKey()
+	if r.fields.enc != nil {
+		return r.fields.enc.BlindIndex(key)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+var recipientAccountField = fieldColumn{table: "withdrawal_recipients", column: "account_number"}
+
+const recipientColumns = `id, user_id, kind, label, chain, address, bank_name, account_holder_name,
+		routing_number, account_number, account_last4, fingerprint, status, hold_until,
+		first_used_at, last_used_at, flag_reason, flagged_by, flagged_at,
+		account_check_status, account_check_reason, account_checked_at,
+		address_check_status, address_check_method, address_check_amount, address_check_message,
+		address_check_reference, address_check_attempts, address_check_expires_at, address_checked_at,
+		created_at, updated_at`
+
+// Create saves a new recipient, returning ErrRecipientExists when the user
+// already has the same destination saved
+func (r *RecipientRepository) Create(ctx context.Context, recipient *entities.WithdrawalRecipient) error {
+	recipient.Fingerprint = r.fingerprint(recipient)
+
+	var sealedAccount *string
+	if recipient.AccountNumber != "" {
+		sealed, err := r.fields.seal(recipientAccountField, recipient.ID, recipient.AccountNumber)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt recipient account number: %w", err)
+		}
+		sealedAccount = &sealed
+	}
+
+	query := `
+		INSERT INTO withdrawal_recipients (
+			id, user_id, kind, label, chain, address, bank_name, account_holder_name,
+			routing_number, account_number, account_last4, fingerprint, status, hold_until,
+			account_check_status, address_check_status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		recipient.ID,
+		recipient.UserID,
+		recipient.Kind,
+		recipient.Label,
+		nullString(recipient.Chain),
+		nullString(recipient.Address),
+		nullString(recipient.BankName),
+		nullString(recipient.AccountHolderName),
+		nullString(recipient.RoutingNumber),
+		sealedAccount,
+		nullString(recipient.AccountLast4),
+		recipient.Fingerprint,
+		recipient.Status,
+		recipient.HoldUntil,
+		nullString(string(recipient.AccountCheckStatus)),
+		nullString(string(recipient.AddressCheckStatus)),
+		recipient.CreatedAt,
+		recipient.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrRecipientExists
+		}
+		r.logger.Error("Failed to create withdrawal recipient", zap.Error(err), zap.String("user_id", recipient.UserID.String()))
+		return fmt.Errorf("failed to create withdrawal recipient: %w", err)
+	}
+	return nil
+}
+
+// Get returns one of the user's saved recipients
+func (r *RecipientRepository) Get(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	query := `SELECT ` + recipientColumns + `
+		FROM withdrawal_recipients
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	return r.getOne(ctx, query, id, userID)
+}
+
+// GetByID returns a recipient regardless of owner, for admin review
+func (r *RecipientRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	query := `SELECT ` + recipientColumns + ` FROM withdrawal_recipients WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+func (r *RecipientRepository) getOne(ctx context.Context, query string, args ...interface{}) (*entities.WithdrawalRecipient, error) {
+	recipient, err := r.scanRecipient(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrRecipientNotFound
+		}
+		return nil, fmt.Errorf("failed to get withdrawal recipient: %w", err)
+	}
+	return recipient, nil
+}
+
+// ListByUser returns the user's saved recipients, most recently used first
+func (r *RecipientRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WithdrawalRecipient, error) {
+	query := `SELECT ` + recipientColumns + `
+		FROM withdrawal_recipients
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY last_used_at DESC NULLS LAST, created_at DESC`
+	return r.list(ctx, query, userID)
+}
+
+func (r *RecipientRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entities.WithdrawalRecipient, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list withdrawal recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []*entities.WithdrawalRecipient
+	for rows.Next() {
+		recipient, err := r.scanRecipient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal recipient: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+// CountByUser counts the user's saved recipients
+func (r *RecipientRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM withdrawal_recipients WHERE user_id = $1 AND deleted_at IS NULL`, userID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count withdrawal recipients: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateLabel renames one of the user's recipients
+func (r *RecipientRepository) UpdateLabel(ctx context.Context, userID, id uuid.UUID, label string) error {
+	query := `
+		UPDATE withdrawal_recipients SET label = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	return r.execOne(ctx, "rename", query, id, userID, label)
+}
+
+// Delete soft-deletes one of the user's recipients. Withdrawals keep their
+// reference to it.
+func (r *RecipientRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		UPDATE withdrawal_recipients SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	return r.execOne(ctx, "delete", query, id, userID)
+}
+
+// MarkUsed records a withdrawal to the recipient and verifies it on first use
+func (r *RecipientRepository) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE withdrawal_recipients SET
+			first_used_at = COALESCE(first_used_at, $2),
+			last_used_at = $2,
+			status = CASE WHEN status = 'unverified' THEN 'verified' ELSE status END,
+			updated_at = $2
+		WHERE id = $1`
+	return r.execOne(ctx, "mark used", query, id, at)
+}
+
+// SetFlag blocks or unblocks a recipient. Unblocking returns it to unverified.
+func (r *RecipientRepository) SetFlag(ctx context.Context, id uuid.UUID, flagged bool, reason string, adminID uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE withdrawal_recipients SET
+			status = 'unverified', flag_reason = NULL, flagged_by = NULL, flagged_at = NULL, updated_at = $2
+		WHERE id = $1`
+	args := []interface{}{id, at}
+	if flagged {
+		query = `
+			UPDATE withdrawal_recipients SET
+				status = 'flagged', flag_reason = $3, flagged_by = $4, flagged_at = $2, updated_at = $2
+			WHERE id = $1`
+		args = append(args, reason, adminID)
+	}
+	return r.execOne(ctx, "flag", query, args...)
+}
+
+// SetAccountCheck records the outcome of a bank-linking provider check
+func (r *RecipientRepository) SetAccountCheck(ctx context.Context, id uuid.UUID, status entities.AccountCheckStatus, reason *string, at time.Time) error {
+	query := `
+		UPDATE withdrawal_recipients SET
+			account_check_status = $2, account_check_reason = $3, account_checked_at = $4, updated_at = $4
+		WHERE id = $1 AND kind = 'bank'`
+	return r.execOne(ctx, "record account check for", query, id, status, reason, at)
+}
+
+// SetAddressCheck stores the recipient's address check as it stands
+func (r *RecipientRepository) SetAddressCheck(ctx context.Context, recipient *entities.WithdrawalRecipient) error {
+	query := `
+		UPDATE withdrawal_recipients SET
+			address_check_status = $2, address_check_method = $3, address_check_amount = $4,
+			address_check_message = $5, address_check_reference = $6, address_check_attempts = $7,
+			address_check_expires_at = $8, address_checked_at = $9, updated_at = NOW()
+		WHERE id = $1 AND kind = 'crypto'`
+	return r.execOne(ctx, "record address check for", query,
+		recipient.ID,
+		recipient.AddressCheckStatus,
+		nullString(string(recipient.AddressCheckMethod)),
+		recipient.AddressCheckAmount,
+		nullString(recipient.AddressCheckMessage),
+		recipient.AddressCheckReference,
+		recipient.AddressCheckAttempts,
+		recipient.AddressCheckExpiresAt,
+		recipient.AddressCheckedAt,
+	)
+}
+
+// UseAddressCheckAttempt counts an answer to a pending address check and
+// returns how many have been given. Counting before the answer is compared
+// keeps concurrent guesses within the limit.
+func (r *RecipientRepository) UseAddressCheckAttempt(ctx context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE withdrawal_recipients SET address_check_attempts = address_check_attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND address_check_status = 'pending'
+		RETURNING address_check_attempts`, id,
+	).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, entities.ErrRecipientNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count address check attempt: %w", err)
+	}
+	return attempts, nil
+}
+
+func (r *RecipientRepository) execOne(ctx context.Context, action, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s withdrawal recipient: %w", action, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrRecipientNotFound
+	}
+	return nil
+}
+
+// CountOtherUsers counts other accounts with the same destination saved
+func (r *RecipientRepository) CountOtherUsers(ctx context.Context, recipient *entities.WithdrawalRecipient) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT user_id) FROM withdrawal_recipients
+		WHERE fingerprint = $1 AND user_id <> $2 AND deleted_at IS NULL`,
+		r.fingerprint(recipient), recipient.UserID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count shared withdrawal recipients: %w", err)
+	}
+	return count, nil
+}
+
+// ListShared returns destinations saved by at least minUsers accounts, the
+// most widely shared first, with the recipients that hold each one
+func (r *RecipientRepository) ListShared(ctx context.Context, minUsers, limit int) ([]*entities.SharedDestination, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT fingerprint, COUNT(DISTINCT user_id), BOOL_OR(status = 'flagged')
+		FROM withdrawal_recipients
+		WHERE deleted_at IS NULL
+		GROUP BY fingerprint
+		HAVING COUNT(DISTINCT user_id) >= $1
+		ORDER BY COUNT(DISTINCT user_id) DESC, MAX(created_at) DESC
+		LIMIT $2`, minUsers, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared destinations: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		shared       []*entities.SharedDestination
+		fingerprints []string
+		byPrint      = map[string]*entities.SharedDestination{}
+	)
+	for rows.Next() {
+		var fingerprint string
+		destination := &entities.SharedDestination{}
+		if err := rows.Scan(&fingerprint, &destination.Users, &destination.Flagged); err != nil {
+			return nil, fmt.Errorf("failed to scan shared destination: %w", err)
+		}
+		shared = append(shared, destination)
+		fingerprints = append(fingerprints, fingerprint)
+		byPrint[fingerprint] = destination
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(shared) == 0 {
+		return shared, nil
+	}
+
+	recipients, err := r.list(ctx, `SELECT `+recipientColumns+`
+		FROM withdrawal_recipients
+		WHERE fingerprint = ANY($1) AND deleted_at IS NULL
+		ORDER BY created_at`, pq.Array(fingerprints))
+	if err != nil {
+		return nil, err
+	}
+	for _, recipient := range recipients {
+		destination := byPrint[recipient.Fingerprint]
+		if destination == nil {
+			continue
+		}
+		if len(destination.Recipients) == 0 {
+			destination.Kind = recipient.Kind
+			destination.Chain = recipient.Chain
+			destination.Address = recipient.Address
+			if recipient.Kind == entities.RecipientKindBank {
+				destination.AccountRef = recipient.RoutingNumber + " ****" + recipient.AccountLast4
+			}
+		}
+		destination.Recipients = append(destination.Recipients, recipient)
+	}
+	return shared, nil
+}
+
+type recipientScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *RecipientRepository) scanRecipient(row recipientScanner) (*entities.WithdrawalRecipient, error) {
+	var (
+		recipient                                        entities.WithdrawalRecipient
+		chain, address, bankName, holder, routing, last4 sql.NullString
+		account, accountCheck                            sql.NullString
+		addressCheck, addressMethod, addressMessage      sql.NullString
+	)
+	err := row.Scan(
+		&recipient.ID,
+		&recipient.UserID,
+		&recipient.Kind,
+		&recipient.Label,
+		&chain,
+		&address,
+		&bankName,
+		&holder,
+		&routing,
+		&account,
+		&last4,
+		&recipient.Fingerprint,
+		&recipient.Status,
+		&recipient.HoldUntil,
+		&recipient.FirstUsedAt,
+		&recipient.LastUsedAt,
+		&recipient.FlagReason,
+		&recipient.FlaggedBy,
+		&recipient.FlaggedAt,
+		&accountCheck,
+		&recipient.AccountCheckReason,
+		&recipient.AccountCheckedAt,
+		&addressCheck,
+		&addressMethod,
+		&recipient.AddressCheckAmount,
+		&addressMessage,
+		&recipient.AddressCheckReference,
+		&recipient.AddressCheckAttempts,
+		&recipient.AddressCheckExpiresAt,
+		&recipient.AddressCheckedAt,
+		&recipient.CreatedAt,
+		&recipient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	recipient.Chain = chain.String
+	recipient.Address = address.String
+	recipient.BankName = bankName.String
+	recipient.AccountHolderName = holder.String
+	recipient.RoutingNumber = routing.String
+	recipient.AccountLast4 = last4.String
+	recipient.AccountCheckStatus = entities.AccountCheckStatus(accountCheck.String)
+	recipient.AddressCheckStatus = entities.AddressCheckStatus(addressCheck.String)
+	recipient.AddressCheckMethod = entities.AddressCheckMethod(addressMethod.String)
+	recipient.AddressCheckMessage = addressMessage.String
+	if account.Valid {
+		if recipient.AccountNumber, err = r.fields.open(recipientAccountField, recipient.ID, account.String); err != nil {
+			return nil, fmt.Errorf("failed to decrypt recipient account number: %w", err)
+		}
+	}
+	return &recipient, nil
+}
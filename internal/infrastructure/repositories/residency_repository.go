@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// ResidencyRepository persists the data region users are tagged with
+type ResidencyRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewResidencyRepository creates a new residency repository
+func NewResidencyRepository(db *sql.DB, logger *zap.Logger) *ResidencyRepository {
+	return &ResidencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Get returns a user's residency tag, or nil when the user has none
+func (r *ResidencyRepository) Get(ctx context.Context, userID uuid.UUID) (*entities.UserResidency, error) {
+	residency := &entities.UserResidency{UserID: userID, Tagged: true}
+	var region, source string
+	var setBy uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, `
+		SELECT region, source, country_code, reason, set_by, updated_at
+		FROM user_residency WHERE user_id = $1`, userID,
+	).Scan(&region, &source, &residency.CountryCode, &residency.Reason, &setBy, &residency.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user residency: %w", err)
+	}
+	residency.Region = entities.ResidencyRegion(region)
+	residency.Source = entities.ResidencySource(source)
+	if setBy.Valid {
+		residency.SetBy = &setBy.UUID
+	}
+	return residency, nil
+}
+
+// Upsert tags a user with a region, replacing any earlier tag
+func (r *ResidencyRepository) Upsert(ctx context.Context, residency *entities.UserResidency) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_residency (user_id, region, source, country_code, reason, set_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			region = EXCLUDED.region,
+			source = EXCLUDED.source,
+			country_code = EXCLUDED.country_code,
+			reason = EXCLUDED.reason,
+			set_by = EXCLUDED.set_by,
+			updated_at = EXCLUDED.updated_at`,
+		residency.UserID, string(residency.Region), string(residency.Source), residency.CountryCode,
+		residency.Reason, residency.SetBy, residency.UpdatedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to tag user residency", zap.Error(err),
+			zap.String("user_id", residency.UserID.String()))
+		return fmt.Errorf("failed to tag user residency: %w", err)
+	}
+	return nil
+}
+
+// ListOutOfRegion returns uploads and documents stored outside their user's
+// region, newest first. Users without a tag are placed by country, with EEA
+// countries in the EU region and everyone else in home, which is also where
+// untagged rows were stored. Rejected uploads are left out as their file is
+// already deleted.
+func (r *ResidencyRepository) ListOutOfRegion(ctx context.Context, home entities.ResidencyRegion, limit, offset int) ([]*entities.ResidencyViolation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH stored AS (
+			SELECT 'upload' AS record_type, id, user_id, COALESCE(storage_region, $1) AS stored_region,
+				purpose AS kind, created_at
+			FROM uploads
+			WHERE status <> 'rejected'
+			UNION ALL
+			SELECT 'document', id, user_id, COALESCE(storage_region, $1), category, published_at
+			FROM documents
+			WHERE user_id IS NOT NULL
+		), placed AS (
+			SELECT s.*, COALESCE(ur.region, CASE WHEN UPPER(u.country) = ANY($2) THEN $3 ELSE $1 END) AS user_region
+			FROM stored s
+			JOIN users u ON u.id = s.user_id
+			LEFT JOIN user_residency ur ON ur.user_id = s.user_id
+		)
+		SELECT record_type, id, user_id, user_region, stored_region, kind, created_at
+		FROM placed
+		WHERE stored_region <> user_region
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5`,
+		string(home), pq.Array(entities.EEACountryCodes), string(entities.ResidencyRegionEU), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list out-of-region records: %w", err)
+	}
+	defer rows.Close()
+
+	violations := []*entities.ResidencyViolation{}
+	for rows.Next() {
+		violation := &entities.ResidencyViolation{}
+		var recordType, userRegion, storedRegion string
+		if err := rows.Scan(&recordType, &violation.RecordID, &violation.UserID, &userRegion,
+			&storedRegion, &violation.Kind, &violation.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan out-of-region record: %w", err)
+		}
+		violation.RecordType = entities.ResidencyRecordType(recordType)
+		violation.UserRegion = entities.ResidencyRegion(userRegion)
+		violation.StoredRegion = entities.ResidencyRegion(storedRegion)
+		violations = append(violations, violation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate out-of-region records: %w", err)
+	}
+	return violations, nil
+}
@@ -0,0 +1,571 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// RewardRepository persists earning rules, the rewards earned under them and
+// their redemptions
+type RewardRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewRewardRepository creates a new reward repository
+func NewRewardRepository(db *sql.DB, logger *zap.Logger) *RewardRepository {
+	return &RewardRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const rewardRuleColumns = `
+	id, name, description, trigger, unit, rate, min_order_amount, max_per_order,
+	hold_days, active, starts_at, ends_at, created_by, created_at, updated_at`
+
+const rewardColumns = `
+	w.id, w.rule_id, rr.name, w.user_id, w.order_id, w.basket_id, w.unit, w.amount,
+	w.points, w.status, w.available_at, w.risk_score, w.hold_reason, w.ledger_tx_id,
+	w.clawback_ledger_tx_id, w.forfeit_reason, w.reviewed_by, w.settled_at,
+	w.created_at, w.updated_at`
+
+const rewardRedemptionColumns = `
+	id, user_id, basket_id, amount, status, order_id, ledger_tx_id, failure_reason,
+	created_at, updated_at`
+
+// CreateRule inserts an earning rule
+func (r *RewardRepository) CreateRule(ctx context.Context, rule *entities.RewardRule) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO reward_rules (
+			id, name, description, trigger, unit, rate, min_order_amount, max_per_order,
+			hold_days, active, starts_at, ends_at, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+		rule.ID, rule.Name, rule.Description, string(rule.Trigger), string(rule.Unit), rule.Rate,
+		rule.MinOrderAmount, rule.MaxPerOrder, rule.HoldDays, rule.Active, rule.StartsAt, rule.EndsAt,
+		rule.CreatedBy, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create reward rule", zap.Error(err), zap.String("name", rule.Name))
+		return fmt.Errorf("failed to create reward rule: %w", err)
+	}
+	return nil
+}
+
+// ListRules returns every earning rule, newest first
+func (r *RewardRepository) ListRules(ctx context.Context) ([]*entities.RewardRule, error) {
+	return r.queryRules(ctx, `SELECT `+rewardRuleColumns+` FROM reward_rules ORDER BY created_at DESC`)
+}
+
+// ListRunning returns active rules with the trigger whose window includes at
+func (r *RewardRepository) ListRunning(ctx context.Context, trigger entities.RewardTrigger, at time.Time) ([]*entities.RewardRule, error) {
+	query := `
+		SELECT ` + rewardRuleColumns + `
+		FROM reward_rules
+		WHERE trigger = $1 AND active = true
+		  AND (starts_at IS NULL OR starts_at <= $2)
+		  AND (ends_at IS NULL OR ends_at > $2)
+		ORDER BY created_at`
+	return r.queryRules(ctx, query, string(trigger), at)
+}
+
+// SetRuleActive pauses or resumes a rule
+func (r *RewardRepository) SetRuleActive(ctx context.Context, id uuid.UUID, active bool) (*entities.RewardRule, error) {
+	rule, err := scanRewardRule(r.db.QueryRowContext(ctx, `
+		UPDATE reward_rules SET active = $2, updated_at = $3
+		WHERE id = $1
+		RETURNING `+rewardRuleColumns, id, active, time.Now()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrRewardRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to update reward rule: %w", err)
+	}
+	return rule, nil
+}
+
+// EarnedSince sums the rewards a user earned at or after since that were not
+// forfeited
+func (r *RewardRepository) EarnedSince(ctx context.Context, userID uuid.UUID, since time.Time) (decimal.Decimal, error) {
+	var earned decimal.Decimal
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM rewards
+		WHERE user_id = $1 AND created_at >= $2 AND status <> 'forfeited'`,
+		userID, since).Scan(&earned)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum earned rewards: %w", err)
+	}
+	return earned, nil
+}
+
+// ClaimReward inserts a reward unless it would take the user's earnings since
+// since past limit. The user's row is locked so concurrent fills are counted
+// one at a time.
+func (r *RewardRepository) ClaimReward(ctx context.Context, reward *entities.Reward, since time.Time, limit decimal.Decimal) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, reward.UserID); err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	var earned decimal.Decimal
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM rewards
+		WHERE user_id = $1 AND created_at >= $2 AND status <> 'forfeited'`,
+		reward.UserID, since).Scan(&earned); err != nil {
+		return fmt.Errorf("failed to sum earned rewards: %w", err)
+	}
+	if earned.Add(reward.Amount).GreaterThan(limit) {
+		return entities.ErrRewardCapReached
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rewards (
+			id, rule_id, user_id, order_id, basket_id, unit, amount, points, status,
+			available_at, risk_score, hold_reason, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)`,
+		reward.ID, reward.RuleID, reward.UserID, reward.OrderID, reward.BasketID, string(reward.Unit),
+		reward.Amount, reward.Points, string(reward.Status), reward.AvailableAt, reward.RiskScore,
+		reward.HoldReason, reward.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrRewardAlreadyEarned
+		}
+		return fmt.Errorf("failed to create reward: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reward: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReward deletes a reward whose ledger posting failed
+func (r *RewardRepository) ReleaseReward(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM rewards WHERE id = $1 AND ledger_tx_id IS NULL`, id); err != nil {
+		return fmt.Errorf("failed to delete reward: %w", err)
+	}
+	return nil
+}
+
+// SetRewardLedgerTx records the ledger transaction that accrued a reward
+func (r *RewardRepository) SetRewardLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE rewards SET ledger_tx_id = $2, updated_at = $3 WHERE id = $1`,
+		id, ledgerTxID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record reward ledger transaction: %w", err)
+	}
+	return nil
+}
+
+// GetReward retrieves a reward
+func (r *RewardRepository) GetReward(ctx context.Context, id uuid.UUID) (*entities.Reward, error) {
+	reward, err := scanReward(r.db.QueryRowContext(ctx, `
+		SELECT `+rewardColumns+`
+		FROM rewards w
+		JOIN reward_rules rr ON rr.id = w.rule_id
+		WHERE w.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrRewardNotFound
+		}
+		return nil, fmt.Errorf("failed to get reward: %w", err)
+	}
+	return reward, nil
+}
+
+// UpdateRewardStatus moves a reward from one status to reward.Status. It
+// reports false when the reward was no longer in from.
+func (r *RewardRepository) UpdateRewardStatus(ctx context.Context, reward *entities.Reward, from entities.RewardStatus) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE rewards SET
+			status = $3, available_at = $4, forfeit_reason = $5, reviewed_by = $6,
+			settled_at = $7, updated_at = $8
+		WHERE id = $1 AND status = $2`,
+		reward.ID, string(from), string(reward.Status), reward.AvailableAt, reward.ForfeitReason,
+		reward.ReviewedBy, reward.SettledAt, reward.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to update reward: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// SetClawbackLedgerTx records the ledger transaction that forfeited a reward
+func (r *RewardRepository) SetClawbackLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE rewards SET clawback_ledger_tx_id = $2, updated_at = $3 WHERE id = $1`,
+		id, ledgerTxID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record reward clawback ledger transaction: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns a user's accrued rewards, newest first
+func (r *RewardRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.Reward, error) {
+	query := `
+		SELECT ` + rewardColumns + `
+		FROM rewards w
+		JOIN reward_rules rr ON rr.id = w.rule_id
+		WHERE w.user_id = $1 AND w.ledger_tx_id IS NOT NULL
+		ORDER BY w.created_at DESC
+		LIMIT $2`
+	return r.queryRewards(ctx, query, userID, limit)
+}
+
+// ListHeld returns rewards waiting for review, oldest first
+func (r *RewardRepository) ListHeld(ctx context.Context, limit, offset int) ([]*entities.Reward, error) {
+	query := `
+		SELECT ` + rewardColumns + `
+		FROM rewards w
+		JOIN reward_rules rr ON rr.id = w.rule_id
+		WHERE w.status = 'held' AND w.ledger_tx_id IS NOT NULL
+		ORDER BY w.created_at
+		LIMIT $1 OFFSET $2`
+	return r.queryRewards(ctx, query, limit, offset)
+}
+
+// ListUnsettledForBasket returns the user's rewards on a basket that are
+// still held or in their hold period at now
+func (r *RewardRepository) ListUnsettledForBasket(ctx context.Context, userID, basketID uuid.UUID, now time.Time) ([]*entities.Reward, error) {
+	query := `
+		SELECT ` + rewardColumns + `
+		FROM rewards w
+		JOIN reward_rules rr ON rr.id = w.rule_id
+		WHERE w.user_id = $1 AND w.basket_id = $2 AND w.ledger_tx_id IS NOT NULL
+		  AND (w.status = 'held' OR (w.status = 'accrued' AND w.available_at > $3))
+		ORDER BY w.created_at`
+	return r.queryRewards(ctx, query, userID, basketID, now)
+}
+
+// Balance totals a user's rewards by state at now. Available is what has
+// passed its hold period less what was redeemed or is being redeemed.
+func (r *RewardRepository) Balance(ctx context.Context, userID uuid.UUID, now time.Time) (*entities.RewardBalance, error) {
+	return queryRewardBalance(ctx, r.db, userID, now)
+}
+
+// CreateRedemption inserts a pending redemption if the user has enough
+// available rewards. The user's row is locked so two redemptions cannot spend
+// the same rewards.
+func (r *RewardRepository) CreateRedemption(ctx context.Context, redemption *entities.RewardRedemption) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, redemption.UserID); err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	balance, err := queryRewardBalance(ctx, tx, redemption.UserID, redemption.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if balance.Available.LessThan(redemption.Amount) {
+		return entities.ErrInsufficientRewards
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO reward_redemptions (id, user_id, basket_id, amount, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)`,
+		redemption.ID, redemption.UserID, redemption.BasketID, redemption.Amount,
+		string(redemption.Status), redemption.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reward redemption: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reward redemption: %w", err)
+	}
+	return nil
+}
+
+// UpdateRedemption records a redemption's outcome
+func (r *RewardRepository) UpdateRedemption(ctx context.Context, redemption *entities.RewardRedemption) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE reward_redemptions SET
+			status = $2, order_id = $3, ledger_tx_id = $4, failure_reason = $5, updated_at = $6
+		WHERE id = $1`,
+		redemption.ID, string(redemption.Status), redemption.OrderID, redemption.LedgerTxID,
+		redemption.FailureReason, redemption.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update reward redemption: %w", err)
+	}
+	return nil
+}
+
+// ListRedemptions returns a user's redemptions, newest first
+func (r *RewardRepository) ListRedemptions(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.RewardRedemption, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+rewardRedemptionColumns+`
+		FROM reward_redemptions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reward redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	var redemptions []*entities.RewardRedemption
+	for rows.Next() {
+		redemption, err := scanRewardRedemption(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reward redemption: %w", err)
+		}
+		redemptions = append(redemptions, redemption)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reward redemptions: %w", err)
+	}
+	return redemptions, nil
+}
+
+// IsSweepOrder reports whether the order was placed by an auto-sweep run
+func (r *RewardRepository) IsSweepOrder(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sweep_runs WHERE order_id = $1)`, orderID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up sweep order: %w", err)
+	}
+	return exists, nil
+}
+
+// IsRedemptionOrder reports whether the order was placed by a reward redemption
+func (r *RewardRepository) IsRedemptionOrder(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM reward_redemptions WHERE order_id = $1)`, orderID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up redemption order: %w", err)
+	}
+	return exists, nil
+}
+
+type rewardQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func queryRewardBalance(ctx context.Context, q rewardQuerier, userID uuid.UUID, now time.Time) (*entities.RewardBalance, error) {
+	balance := &entities.RewardBalance{}
+	var matured decimal.Decimal
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE((SELECT SUM(amount) FROM rewards
+				WHERE user_id = $1 AND ledger_tx_id IS NOT NULL AND status = 'accrued' AND available_at <= $2), 0),
+			COALESCE((SELECT SUM(amount) FROM rewards
+				WHERE user_id = $1 AND ledger_tx_id IS NOT NULL AND status = 'accrued' AND available_at > $2), 0),
+			COALESCE((SELECT SUM(amount) FROM rewards
+				WHERE user_id = $1 AND ledger_tx_id IS NOT NULL AND status = 'held'), 0),
+			COALESCE((SELECT SUM(amount) FROM reward_redemptions
+				WHERE user_id = $1 AND status IN ('pending', 'completed')), 0)`,
+		userID, now).Scan(&matured, &balance.Pending, &balance.UnderReview, &balance.Redeemed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total rewards: %w", err)
+	}
+	balance.Available = matured.Sub(balance.Redeemed)
+	if balance.Available.IsNegative() {
+		balance.Available = decimal.Zero
+	}
+	return balance, nil
+}
+
+func (r *RewardRepository) queryRules(ctx context.Context, query string, args ...interface{}) ([]*entities.RewardRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reward rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*entities.RewardRule
+	for rows.Next() {
+		rule, err := scanRewardRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reward rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reward rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (r *RewardRepository) queryRewards(ctx context.Context, query string, args ...interface{}) ([]*entities.Reward, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rewards: %w", err)
+	}
+	defer rows.Close()
+
+	var rewards []*entities.Reward
+	for rows.Next() {
+		reward, err := scanReward(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reward: %w", err)
+		}
+		rewards = append(rewards, reward)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rewards: %w", err)
+	}
+	return rewards, nil
+}
+
+func scanRewardRule(row adminCaseScanner) (*entities.RewardRule, error) {
+	rule := &entities.RewardRule{}
+	var trigger, unit string
+	var description sql.NullString
+	var maxPerOrder decimal.NullDecimal
+	var startsAt, endsAt sql.NullTime
+	var createdBy uuid.NullUUID
+
+	if err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&description,
+		&trigger,
+		&unit,
+		&rule.Rate,
+		&rule.MinOrderAmount,
+		&maxPerOrder,
+		&rule.HoldDays,
+		&rule.Active,
+		&startsAt,
+		&endsAt,
+		&createdBy,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	rule.Trigger = entities.RewardTrigger(trigger)
+	rule.Unit = entities.RewardUnit(unit)
+	if description.Valid {
+		rule.Description = &description.String
+	}
+	if maxPerOrder.Valid {
+		rule.MaxPerOrder = &maxPerOrder.Decimal
+	}
+	if startsAt.Valid {
+		rule.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		rule.EndsAt = &endsAt.Time
+	}
+	if createdBy.Valid {
+		rule.CreatedBy = &createdBy.UUID
+	}
+	return rule, nil
+}
+
+func scanReward(row adminCaseScanner) (*entities.Reward, error) {
+	reward := &entities.Reward{}
+	var unit, status string
+	var points sql.NullInt64
+	var holdReason, forfeitReason sql.NullString
+	var ledgerTxID, clawbackLedgerTxID, reviewedBy uuid.NullUUID
+	var settledAt sql.NullTime
+
+	if err := row.Scan(
+		&reward.ID,
+		&reward.RuleID,
+		&reward.RuleName,
+		&reward.UserID,
+		&reward.OrderID,
+		&reward.BasketID,
+		&unit,
+		&reward.Amount,
+		&points,
+		&status,
+		&reward.AvailableAt,
+		&reward.RiskScore,
+		&holdReason,
+		&ledgerTxID,
+		&clawbackLedgerTxID,
+		&forfeitReason,
+		&reviewedBy,
+		&settledAt,
+		&reward.CreatedAt,
+		&reward.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	reward.Unit = entities.RewardUnit(unit)
+	reward.Status = entities.RewardStatus(status)
+	if points.Valid {
+		reward.Points = &points.Int64
+	}
+	if holdReason.Valid {
+		reward.HoldReason = &holdReason.String
+	}
+	if ledgerTxID.Valid {
+		reward.LedgerTxID = &ledgerTxID.UUID
+	}
+	if clawbackLedgerTxID.Valid {
+		reward.ClawbackLedgerTxID = &clawbackLedgerTxID.UUID
+	}
+	if forfeitReason.Valid {
+		reward.ForfeitReason = &forfeitReason.String
+	}
+	if reviewedBy.Valid {
+		reward.ReviewedBy = &reviewedBy.UUID
+	}
+	if settledAt.Valid {
+		reward.SettledAt = &settledAt.Time
+	}
+	return reward, nil
+}
+
+func scanRewardRedemption(row adminCaseScanner) (*entities.RewardRedemption, error) {
+	redemption := &entities.RewardRedemption{}
+	var status string
+	var orderID, ledgerTxID uuid.NullUUID
+	var failureReason sql.NullString
+
+	if err := row.Scan(
+		&redemption.ID,
+		&redemption.UserID,
+		&redemption.BasketID,
+		&redemption.Amount,
+		&status,
+		&orderID,
+		&ledgerTxID,
+		&failureReason,
+		&redemption.CreatedAt,
+		&redemption.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	redemption.Status = entities.RewardRedemptionStatus(status)
+	if orderID.Valid {
+		redemption.OrderID = &orderID.UUID
+	}
+	if ledgerTxID.Valid {
+		redemption.LedgerTxID = &ledgerTxID.UUID
+	}
+	if failureReason.Valid {
+		redemption.FailureReason = &failureReason.String
+	}
+	return redemption, nil
+}
@@ -0,0 +1,397 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// SubscriptionRepository persists subscription plans, subscriptions and invoices
+type SubscriptionRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSubscriptionRepository creates a new subscription repository
+func NewSubscriptionRepository(db *sql.DB, logger *zap.Logger) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const subscriptionPlanColumns = `
+	id, code, name, description, price, currency, interval_months,
+	entitlements, active, created_at, updated_at`
+
+const subscriptionColumns = `
+	s.id, s.user_id, s.plan_id, p.code, s.status, s.payment_method,
+	s.current_period_start, s.current_period_end, s.cancel_at_period_end,
+	s.credit_balance, s.canceled_at, s.created_at, s.updated_at`
+
+const invoiceColumns = `
+	id, number, subscription_id, user_id, status, subtotal, credit_applied, total,
+	currency, period_start, period_end, line_items, attempt_count, next_attempt_at,
+	failure_reason, ledger_tx_id, payment_ref, paid_at, created_at, updated_at`
+
+// GetPlanByCode retrieves a plan by its code
+func (r *SubscriptionRepository) GetPlanByCode(ctx context.Context, code string) (*entities.SubscriptionPlan, error) {
+	return r.getPlan(ctx, `SELECT `+subscriptionPlanColumns+` FROM subscription_plans WHERE code = $1`, code)
+}
+
+// GetPlan retrieves a plan
+func (r *SubscriptionRepository) GetPlan(ctx context.Context, id uuid.UUID) (*entities.SubscriptionPlan, error) {
+	return r.getPlan(ctx, `SELECT `+subscriptionPlanColumns+` FROM subscription_plans WHERE id = $1`, id)
+}
+
+// ListPlans returns active plans, cheapest first
+func (r *SubscriptionRepository) ListPlans(ctx context.Context) ([]*entities.SubscriptionPlan, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+subscriptionPlanColumns+` FROM subscription_plans WHERE active = true ORDER BY price`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*entities.SubscriptionPlan
+	for rows.Next() {
+		plan, err := scanSubscriptionPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription plan: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate subscription plans: %w", err)
+	}
+	return plans, nil
+}
+
+// CreateSubscription inserts a subscription. The user may only have one
+// active or past due subscription.
+func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, sub *entities.Subscription) error {
+	query := `
+		INSERT INTO subscriptions (
+			id, user_id, plan_id, status, payment_method, current_period_start,
+			current_period_end, cancel_at_period_end, credit_balance, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		sub.ID, sub.UserID, sub.PlanID, string(sub.Status), string(sub.PaymentMethod),
+		sub.CurrentPeriodStart, sub.CurrentPeriodEnd, sub.CancelAtPeriodEnd, sub.CreditBalance,
+		sub.CreatedAt, sub.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entities.ErrAlreadySubscribed
+		}
+		r.logger.Error("Failed to create subscription", zap.Error(err), zap.String("user_id", sub.UserID.String()))
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return nil
+}
+
+// GetLiveByUser retrieves the user's active or past due subscription
+func (r *SubscriptionRepository) GetLiveByUser(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error) {
+	sub, err := scanSubscription(r.db.QueryRowContext(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions s
+		JOIN subscription_plans p ON p.id = s.plan_id
+		WHERE s.user_id = $1 AND s.status IN ('active', 'past_due')`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// UpdateSubscription persists a subscription's plan, status and period
+func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, sub *entities.Subscription) error {
+	query := `
+		UPDATE subscriptions SET
+			plan_id = $2, status = $3, payment_method = $4, current_period_start = $5,
+			current_period_end = $6, cancel_at_period_end = $7, credit_balance = $8,
+			canceled_at = $9, updated_at = $10
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		sub.ID, sub.PlanID, string(sub.Status), string(sub.PaymentMethod), sub.CurrentPeriodStart,
+		sub.CurrentPeriodEnd, sub.CancelAtPeriodEnd, sub.CreditBalance, sub.CanceledAt, sub.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// ListDueForRenewal returns active subscriptions whose period has ended
+func (r *SubscriptionRepository) ListDueForRenewal(ctx context.Context, now time.Time, limit int) ([]*entities.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions s
+		JOIN subscription_plans p ON p.id = s.plan_id
+		WHERE s.status = 'active' AND s.current_period_end <= $1
+		ORDER BY s.current_period_end
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions due for renewal: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*entities.Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// CreateInvoice inserts an invoice and sets its generated number
+func (r *SubscriptionRepository) CreateInvoice(ctx context.Context, invoice *entities.Invoice) error {
+	lineItems, err := json.Marshal(invoice.LineItems)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invoice line items: %w", err)
+	}
+
+	query := `
+		INSERT INTO invoices (
+			id, subscription_id, user_id, status, subtotal, credit_applied, total,
+			currency, period_start, period_end, line_items, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING number`
+
+	err = r.db.QueryRowContext(ctx, query,
+		invoice.ID, invoice.SubscriptionID, invoice.UserID, string(invoice.Status), invoice.Subtotal,
+		invoice.CreditApplied, invoice.Total, invoice.Currency, invoice.PeriodStart, invoice.PeriodEnd,
+		lineItems, invoice.CreatedAt, invoice.UpdatedAt).Scan(&invoice.Number)
+	if err != nil {
+		r.logger.Error("Failed to create invoice", zap.Error(err), zap.String("subscription_id", invoice.SubscriptionID.String()))
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return nil
+}
+
+// UpdateInvoice persists an invoice's payment state
+func (r *SubscriptionRepository) UpdateInvoice(ctx context.Context, invoice *entities.Invoice) error {
+	query := `
+		UPDATE invoices SET
+			status = $2, attempt_count = $3, next_attempt_at = $4, failure_reason = $5,
+			ledger_tx_id = $6, payment_ref = $7, paid_at = $8, updated_at = $9
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		invoice.ID, string(invoice.Status), invoice.AttemptCount, invoice.NextAttemptAt,
+		invoice.FailureReason, invoice.LedgerTxID, invoice.PaymentRef, invoice.PaidAt, invoice.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update invoice: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrInvoiceNotFound
+	}
+	return nil
+}
+
+// GetInvoice retrieves an invoice
+func (r *SubscriptionRepository) GetInvoice(ctx context.Context, id uuid.UUID) (*entities.Invoice, error) {
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// ListInvoicesByUser returns the user's invoices, newest first
+func (r *SubscriptionRepository) ListInvoicesByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Invoice, error) {
+	return r.queryInvoices(ctx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`, userID, limit, offset)
+}
+
+// ListDunning returns open invoices whose next payment attempt is due
+func (r *SubscriptionRepository) ListDunning(ctx context.Context, now time.Time, limit int) ([]*entities.Invoice, error) {
+	return r.queryInvoices(ctx, `
+		SELECT `+invoiceColumns+`
+		FROM invoices
+		WHERE status = 'open' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2`, now, limit)
+}
+
+func (r *SubscriptionRepository) getPlan(ctx context.Context, query string, arg interface{}) (*entities.SubscriptionPlan, error) {
+	plan, err := scanSubscriptionPlan(r.db.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrPlanNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription plan: %w", err)
+	}
+	return plan, nil
+}
+
+func (r *SubscriptionRepository) queryInvoices(ctx context.Context, query string, args ...interface{}) ([]*entities.Invoice, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*entities.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate invoices: %w", err)
+	}
+	return invoices, nil
+}
+
+func scanSubscriptionPlan(row adminCaseScanner) (*entities.SubscriptionPlan, error) {
+	plan := &entities.SubscriptionPlan{}
+	var description sql.NullString
+	var entitlements []byte
+
+	if err := row.Scan(
+		&plan.ID,
+		&plan.Code,
+		&plan.Name,
+		&description,
+		&plan.Price,
+		&plan.Currency,
+		&plan.IntervalMonths,
+		&entitlements,
+		&plan.Active,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		plan.Description = &description.String
+	}
+	plan.Entitlements = entities.Entitlements{}
+	if len(entitlements) > 0 {
+		if err := json.Unmarshal(entitlements, &plan.Entitlements); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entitlements: %w", err)
+		}
+	}
+	return plan, nil
+}
+
+func scanSubscription(row adminCaseScanner) (*entities.Subscription, error) {
+	sub := &entities.Subscription{}
+	var status, paymentMethod string
+	var canceledAt sql.NullTime
+
+	if err := row.Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.PlanID,
+		&sub.PlanCode,
+		&status,
+		&paymentMethod,
+		&sub.CurrentPeriodStart,
+		&sub.CurrentPeriodEnd,
+		&sub.CancelAtPeriodEnd,
+		&sub.CreditBalance,
+		&canceledAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	sub.Status = entities.SubscriptionStatus(status)
+	sub.PaymentMethod = entities.PaymentMethod(paymentMethod)
+	if canceledAt.Valid {
+		sub.CanceledAt = &canceledAt.Time
+	}
+	return sub, nil
+}
+
+func scanInvoice(row adminCaseScanner) (*entities.Invoice, error) {
+	invoice := &entities.Invoice{}
+	var status string
+	var lineItems []byte
+	var nextAttemptAt, paidAt sql.NullTime
+	var failureReason, paymentRef sql.NullString
+	var ledgerTxID uuid.NullUUID
+
+	if err := row.Scan(
+		&invoice.ID,
+		&invoice.Number,
+		&invoice.SubscriptionID,
+		&invoice.UserID,
+		&status,
+		&invoice.Subtotal,
+		&invoice.CreditApplied,
+		&invoice.Total,
+		&invoice.Currency,
+		&invoice.PeriodStart,
+		&invoice.PeriodEnd,
+		&lineItems,
+		&invoice.AttemptCount,
+		&nextAttemptAt,
+		&failureReason,
+		&ledgerTxID,
+		&paymentRef,
+		&paidAt,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	invoice.Status = entities.InvoiceStatus(status)
+	if len(lineItems) > 0 {
+		if err := json.Unmarshal(lineItems, &invoice.LineItems); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal invoice line items: %w", err)
+		}
+	}
+	if nextAttemptAt.Valid {
+		invoice.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if failureReason.Valid {
+		invoice.FailureReason = &failureReason.String
+	}
+	if ledgerTxID.Valid {
+		invoice.LedgerTxID = &ledgerTxID.UUID
+	}
+	if paymentRef.Valid {
+		invoice.PaymentRef = &paymentRef.String
+	}
+	if paidAt.Valid {
+		invoice.PaidAt = &paidAt.Time
+	}
+	return invoice, nil
+}
@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// SuspenseRepository persists the queue of unattributed funds
+type SuspenseRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSuspenseRepository creates a new suspense repository
+func NewSuspenseRepository(db *sql.DB, logger *zap.Logger) *SuspenseRepository {
+	return &SuspenseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const suspenseItemColumns = `
+	id, source, source_ref, currency, amount, usd_amount, reason, details, status,
+	user_id, resolved_by, resolved_at, note, hold_ledger_transaction_id,
+	release_ledger_transaction_id, received_at, created_at, updated_at`
+
+// Create inserts an item unless one exists for the same source transfer, in
+// which case the existing item is returned with created=false
+func (r *SuspenseRepository) Create(ctx context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, bool, error) {
+	details, err := json.Marshal(item.Details)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal suspense item details: %w", err)
+	}
+
+	created, err := scanSuspenseItem(r.db.QueryRowContext(ctx, `
+		INSERT INTO suspense_items (id, source, source_ref, currency, amount, usd_amount, reason, details,
+			status, received_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		ON CONFLICT (source, source_ref) DO NOTHING
+		RETURNING `+suspenseItemColumns,
+		item.ID, string(item.Source), item.SourceRef, item.Currency, item.Amount, item.USDAmount, item.Reason,
+		details, string(item.Status), item.ReceivedAt, item.CreatedAt))
+	if err == nil {
+		return created, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.logger.Error("Failed to create suspense item", zap.Error(err), zap.String("source_ref", item.SourceRef))
+		return nil, false, fmt.Errorf("failed to create suspense item: %w", err)
+	}
+
+	existing, err := scanSuspenseItem(r.db.QueryRowContext(ctx, `
+		SELECT `+suspenseItemColumns+` FROM suspense_items
+		WHERE source = $1 AND source_ref = $2`, string(item.Source), item.SourceRef))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load existing suspense item: %w", err)
+	}
+	return existing, false, nil
+}
+
+// Get retrieves an item
+func (r *SuspenseRepository) Get(ctx context.Context, id uuid.UUID) (*entities.SuspenseItem, error) {
+	item, err := scanSuspenseItem(r.db.QueryRowContext(ctx,
+		`SELECT `+suspenseItemColumns+` FROM suspense_items WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrSuspenseItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get suspense item: %w", err)
+	}
+	return item, nil
+}
+
+// List returns items with a status, or all items when status is empty,
+// oldest first
+func (r *SuspenseRepository) List(ctx context.Context, status entities.SuspenseStatus, limit, offset int) ([]*entities.SuspenseItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+suspenseItemColumns+`
+		FROM suspense_items
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY received_at ASC
+		LIMIT $2 OFFSET $3`, string(status), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suspense items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*entities.SuspenseItem
+	for rows.Next() {
+		item, err := scanSuspenseItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan suspense item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate suspense items: %w", err)
+	}
+	return items, nil
+}
+
+// SetHoldTransaction records the ledger transaction that booked an item into suspense
+func (r *SuspenseRepository) SetHoldTransaction(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE suspense_items SET hold_ledger_transaction_id = $2, updated_at = NOW()
+		WHERE id = $1`, id, ledgerTxID)
+	if err != nil {
+		return fmt.Errorf("failed to record suspense hold transaction: %w", err)
+	}
+	return nil
+}
+
+// Claim marks an open item as being resolved. It returns false when the item
+// was no longer open.
+func (r *SuspenseRepository) Claim(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE suspense_items SET status = 'resolving', updated_at = $2
+		WHERE id = $1 AND status = 'open'`, id, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim suspense item: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// Reopen returns a claimed item to the queue after it could not be resolved
+func (r *SuspenseRepository) Reopen(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE suspense_items SET status = 'open', updated_at = $2
+		WHERE id = $1 AND status = 'resolving'`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to reopen suspense item: %w", err)
+	}
+	return nil
+}
+
+// Resolve persists the outcome of a claimed item
+func (r *SuspenseRepository) Resolve(ctx context.Context, item *entities.SuspenseItem) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE suspense_items SET
+			status = $2, user_id = $3, resolved_by = $4, resolved_at = $5, note = $6,
+			release_ledger_transaction_id = $7, updated_at = $8
+		WHERE id = $1 AND status = 'resolving'`,
+		item.ID, string(item.Status), item.UserID, item.ResolvedBy, item.ResolvedAt, item.Note,
+		item.ReleaseLedgerTransactionID, item.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to resolve suspense item: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return entities.ErrSuspenseItemResolved
+	}
+	return nil
+}
+
+// Aging counts open items per source and those received before agedBefore
+func (r *SuspenseRepository) Aging(ctx context.Context, agedBefore time.Time) ([]entities.SuspenseAging, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT source, COUNT(*),
+			COUNT(*) FILTER (WHERE received_at < $1),
+			COALESCE(SUM(usd_amount) FILTER (WHERE received_at < $1), 0),
+			MIN(received_at)
+		FROM suspense_items
+		WHERE status = 'open'
+		GROUP BY source
+		ORDER BY source`, agedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize suspense aging: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []entities.SuspenseAging
+	for rows.Next() {
+		var aging entities.SuspenseAging
+		var source string
+		var oldest sql.NullTime
+		if err := rows.Scan(&source, &aging.Open, &aging.Aged, &aging.AgedUSDAmount, &oldest); err != nil {
+			return nil, fmt.Errorf("failed to scan suspense aging: %w", err)
+		}
+		aging.Source = entities.SuspenseSource(source)
+		if oldest.Valid {
+			aging.OldestAt = &oldest.Time
+		}
+		sources = append(sources, aging)
+	}
+	return sources, rows.Err()
+}
+
+type suspenseItemScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSuspenseItem(row suspenseItemScanner) (*entities.SuspenseItem, error) {
+	item := &entities.SuspenseItem{}
+	var source, status string
+	var details []byte
+	var userID, resolvedBy, holdTxID, releaseTxID uuid.NullUUID
+	var resolvedAt sql.NullTime
+	var note sql.NullString
+
+	if err := row.Scan(
+		&item.ID,
+		&source,
+		&item.SourceRef,
+		&item.Currency,
+		&item.Amount,
+		&item.USDAmount,
+		&item.Reason,
+		&details,
+		&status,
+		&userID,
+		&resolvedBy,
+		&resolvedAt,
+		&note,
+		&holdTxID,
+		&releaseTxID,
+		&item.ReceivedAt,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	item.Source = entities.SuspenseSource(source)
+	item.Status = entities.SuspenseStatus(status)
+	if len(details) > 0 {
+		if err := json.Unmarshal(details, &item.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal suspense item details: %w", err)
+		}
+	}
+	if userID.Valid {
+		item.UserID = &userID.UUID
+	}
+	if resolvedBy.Valid {
+		item.ResolvedBy = &resolvedBy.UUID
+	}
+	if resolvedAt.Valid {
+		item.ResolvedAt = &resolvedAt.Time
+	}
+	if note.Valid {
+		item.Note = &note.String
+	}
+	if holdTxID.Valid {
+		item.HoldLedgerTransactionID = &holdTxID.UUID
+	}
+	if releaseTxID.Valid {
+		item.ReleaseLedgerTransactionID = &releaseTxID.UUID
+	}
+	return item, nil
+}
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// SweepRepository persists auto-sweep rules and the runs made from them
+type SweepRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewSweepRepository creates a new auto-sweep repository
+func NewSweepRepository(db *sql.DB, logger *zap.Logger) *SweepRepository {
+	return &SweepRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const sweepRuleColumns = `
+	id, user_id, basket_id, floor, min_amount, max_amount, cadence, enabled, next_run_at,
+	last_run_at, consecutive_failures, disabled_reason, created_at, updated_at`
+
+const sweepRunColumns = `
+	id, rule_id, user_id, basket_id, status, buying_power, amount, order_id, reason, created_at`
+
+// GetByUser returns the user's rule
+func (r *SweepRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*entities.SweepRule, error) {
+	rule, err := scanSweepRule(r.db.QueryRowContext(ctx,
+		`SELECT `+sweepRuleColumns+` FROM sweep_rules WHERE user_id = $1`, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrSweepRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sweep rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Save creates the user's rule or replaces its settings
+func (r *SweepRepository) Save(ctx context.Context, rule *entities.SweepRule) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sweep_rules (
+			id, user_id, basket_id, floor, min_amount, max_amount, cadence, enabled, next_run_at,
+			consecutive_failures, disabled_reason, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (user_id) DO UPDATE SET
+			basket_id = EXCLUDED.basket_id, floor = EXCLUDED.floor, min_amount = EXCLUDED.min_amount,
+			max_amount = EXCLUDED.max_amount, cadence = EXCLUDED.cadence, enabled = EXCLUDED.enabled,
+			next_run_at = EXCLUDED.next_run_at, consecutive_failures = EXCLUDED.consecutive_failures,
+			disabled_reason = EXCLUDED.disabled_reason, updated_at = EXCLUDED.updated_at`,
+		rule.ID, rule.UserID, rule.BasketID, rule.Floor, rule.MinAmount, rule.MaxAmount,
+		string(rule.Cadence), rule.Enabled, rule.NextRunAt, rule.ConsecutiveFailures, rule.DisabledReason,
+		rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to save sweep rule", zap.Error(err), zap.String("user_id", rule.UserID.String()))
+		return fmt.Errorf("failed to save sweep rule: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue locks up to limit enabled rules whose next run is at or before
+// now until now+lease
+func (r *SweepRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.SweepRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE sweep_rules SET locked_until = $2
+		WHERE id IN (
+			SELECT id FROM sweep_rules
+			WHERE enabled AND next_run_at <= $1 AND (locked_until IS NULL OR locked_until < $1)
+			ORDER BY next_run_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+sweepRuleColumns, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sweep rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*entities.SweepRule
+	for rows.Next() {
+		rule, err := scanSweepRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sweep rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sweep rules: %w", err)
+	}
+	return rules, nil
+}
+
+// FinishRun records a run, schedules the rule's next one and releases it in
+// one transaction. Settings the user changed meanwhile are kept; a rule the
+// user turned off stays off.
+func (r *SweepRepository) FinishRun(ctx context.Context, rule *entities.SweepRule, run *entities.SweepRun, disableReason *string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO sweep_runs (id, rule_id, user_id, basket_id, status, buying_power, amount, order_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		run.ID, run.RuleID, run.UserID, run.BasketID, string(run.Status), run.BuyingPower, run.Amount,
+		run.OrderID, run.Reason, run.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to record sweep run", zap.Error(err), zap.String("rule_id", rule.ID.String()))
+		return fmt.Errorf("failed to record sweep run: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sweep_rules SET
+			last_run_at = $2, next_run_at = $3, consecutive_failures = $4,
+			enabled = enabled AND $5::text IS NULL,
+			disabled_reason = COALESCE($5, disabled_reason),
+			locked_until = NULL, updated_at = $6
+		WHERE id = $1`,
+		rule.ID, rule.LastRunAt, rule.NextRunAt, rule.ConsecutiveFailures, disableReason, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule sweep rule: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sweep run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the user's most recent runs, newest first
+func (r *SweepRepository) ListRuns(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.SweepRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sweepRunColumns+` FROM sweep_runs
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sweep runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*entities.SweepRun{}
+	for rows.Next() {
+		run := &entities.SweepRun{}
+		var status string
+		var orderID uuid.NullUUID
+		var reason sql.NullString
+		if err := rows.Scan(&run.ID, &run.RuleID, &run.UserID, &run.BasketID, &status, &run.BuyingPower,
+			&run.Amount, &orderID, &reason, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan sweep run: %w", err)
+		}
+		run.Status = entities.SweepRunStatus(status)
+		if orderID.Valid {
+			run.OrderID = &orderID.UUID
+		}
+		if reason.Valid {
+			run.Reason = &reason.String
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate sweep runs: %w", err)
+	}
+	return runs, nil
+}
+
+type sweepRuleScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSweepRule(row sweepRuleScanner) (*entities.SweepRule, error) {
+	rule := &entities.SweepRule{}
+	var cadence string
+	var maxAmount decimal.NullDecimal
+	var lastRunAt sql.NullTime
+	var disabledReason sql.NullString
+	if err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.BasketID,
+		&rule.Floor,
+		&rule.MinAmount,
+		&maxAmount,
+		&cadence,
+		&rule.Enabled,
+		&rule.NextRunAt,
+		&lastRunAt,
+		&rule.ConsecutiveFailures,
+		&disabledReason,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	rule.Cadence = entities.SweepCadence(cadence)
+	if maxAmount.Valid {
+		rule.MaxAmount = &maxAmount.Decimal
+	}
+	if lastRunAt.Valid {
+		rule.LastRunAt = &lastRunAt.Time
+	}
+	if disabledReason.Valid {
+		rule.DisabledReason = &disabledReason.String
+	}
+	return rule, nil
+}
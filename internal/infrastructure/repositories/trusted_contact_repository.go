@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// TrustedContactRepository persists each user's trusted contact. Email and
+// phone are sealed with the field encryptor when one is configured.
+type TrustedContactRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+	fields fieldCipher
+}
+
+// NewTrustedContactRepository creates a new trusted contact repository
+func NewTrustedContactRepository(db *sql.DB, logger *zap.Logger) *TrustedContactRepository {
+	return &TrustedContactRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetFieldEncryptor enables encryption of contact details at rest
+func (r *TrustedContactRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+// RotateFieldEncryption re-encrypts contact details under the active key version
+func (r *TrustedContactRepository) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, r.db, r.fields.enc, "trusted_contacts", []encryptedColumn{
+		{name: "email"},
+		{name: "phone"},
+	}, batchSize)
+}
+
+// Encrypted trusted contact columns
+var (
+	trustedContactEmailField = fieldColumn{table: "trusted_contacts", column: "email"}
+	trustedContactPhoneField = fieldColumn{table: "trusted_contacts", column: "phone"}
+)
+
+// GetByUserID returns the user's trusted contact
+func (r *TrustedContactRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.TrustedContact, error) {
+	query := `
+		SELECT id, user_id, first_name, last_name, relationship, email, phone, created_at, updated_at
+		FROM trusted_contacts
+		WHERE user_id = $1`
+
+	contact := &entities.TrustedContact{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&contact.ID,
+		&contact.UserID,
+		&contact.FirstName,
+		&contact.LastName,
+		&contact.Relationship,
+		&contact.Email,
+		&contact.Phone,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrTrustedContactNotFound
+		}
+		return nil, fmt.Errorf("failed to get trusted contact: %w", err)
+	}
+
+	if err := r.fields.openPtr(trustedContactEmailField, contact.ID, contact.Email); err != nil {
+		return nil, fmt.Errorf("failed to decrypt trusted contact email: %w", err)
+	}
+	if err := r.fields.openPtr(trustedContactPhoneField, contact.ID, contact.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt trusted contact phone: %w", err)
+	}
+	return contact, nil
+}
+
+// Upsert creates or replaces the user's trusted contact
+func (r *TrustedContactRepository) Upsert(ctx context.Context, contact *entities.TrustedContact) error {
+	sealedEmail, err := r.fields.sealPtr(trustedContactEmailField, contact.ID, contact.Email)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt trusted contact email: %w", err)
+	}
+	sealedPhone, err := r.fields.sealPtr(trustedContactPhoneField, contact.ID, contact.Phone)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt trusted contact phone: %w", err)
+	}
+
+	// The replacement takes the new ID because the contact details were
+	// sealed to it
+	query := `
+		INSERT INTO trusted_contacts (
+			id, user_id, first_name, last_name, relationship, email, phone, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			id = EXCLUDED.id,
+			first_name = EXCLUDED.first_name,
+			last_name = EXCLUDED.last_name,
+			relationship = EXCLUDED.relationship,
+			email = EXCLUDED.email,
+			phone = EXCLUDED.phone,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at, updated_at`
+
+	err = r.db.QueryRowContext(ctx, query,
+		contact.ID,
+		contact.UserID,
+		contact.FirstName,
+		contact.LastName,
+		contact.Relationship,
+		sealedEmail,
+		sealedPhone,
+		contact.UpdatedAt,
+	).Scan(&contact.ID, &contact.CreatedAt, &contact.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to save trusted contact", zap.Error(err), zap.String("user_id", contact.UserID.String()))
+		return fmt.Errorf("failed to save trusted contact: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the user's trusted contact
+func (r *TrustedContactRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM trusted_contacts WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete trusted contact: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrTrustedContactNotFound
+	}
+	return nil
+}
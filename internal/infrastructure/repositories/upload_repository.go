@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// UploadRepository persists uploaded file records
+type UploadRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewUploadRepository creates a new upload repository
+func NewUploadRepository(db *sql.DB, logger *zap.Logger) *UploadRepository {
+	return &UploadRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const uploadColumns = `
+	id, user_id, purpose, file_name, content_type, size_bytes, storage_key, status,
+	scan_result, rejection_reason, expires_at, completed_at, created_at, updated_at, storage_region`
+
+// Create inserts a new upload
+func (r *UploadRepository) Create(ctx context.Context, upload *entities.Upload) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO uploads (`+uploadColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''))`,
+		upload.ID, upload.UserID, string(upload.Purpose), upload.FileName, upload.ContentType,
+		upload.SizeBytes, upload.StorageKey, string(upload.Status), upload.ScanResult,
+		upload.RejectionReason, upload.ExpiresAt, upload.CompletedAt, upload.CreatedAt, upload.UpdatedAt,
+		string(upload.StorageRegion),
+	)
+	if err != nil {
+		r.logger.Error("Failed to create upload", zap.Error(err))
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+	return nil
+}
+
+// Get returns an upload by ID
+func (r *UploadRepository) Get(ctx context.Context, id uuid.UUID) (*entities.Upload, error) {
+	upload, err := scanUpload(r.db.QueryRowContext(ctx, `SELECT `+uploadColumns+` FROM uploads WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	return upload, nil
+}
+
+// Update saves an upload's status and scan outcome
+func (r *UploadRepository) Update(ctx context.Context, upload *entities.Upload) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE uploads
+		SET status = $2, scan_result = $3, rejection_reason = $4, completed_at = $5, updated_at = $6
+		WHERE id = $1`,
+		upload.ID, string(upload.Status), upload.ScanResult, upload.RejectionReason, upload.CompletedAt, upload.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return entities.ErrUploadNotFound
+	}
+	return nil
+}
+
+// ListExpiredPending returns pending uploads whose URL expired before the
+// given time, oldest first
+func (r *UploadRepository) ListExpiredPending(ctx context.Context, before time.Time, limit int) ([]*entities.Upload, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+uploadColumns+` FROM uploads
+		WHERE status = 'pending' AND expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2`, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired uploads: %w", err)
+	}
+	defer rows.Close()
+
+	uploads := []*entities.Upload{}
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload: %w", err)
+		}
+		uploads = append(uploads, upload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+// Delete removes an upload record
+func (r *UploadRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM uploads WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+type uploadScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUpload(row uploadScanner) (*entities.Upload, error) {
+	upload := &entities.Upload{}
+	var purpose, status string
+	var rejectionReason, storageRegion sql.NullString
+	var completedAt sql.NullTime
+	if err := row.Scan(
+		&upload.ID,
+		&upload.UserID,
+		&purpose,
+		&upload.FileName,
+		&upload.ContentType,
+		&upload.SizeBytes,
+		&upload.StorageKey,
+		&status,
+		&upload.ScanResult,
+		&rejectionReason,
+		&upload.ExpiresAt,
+		&completedAt,
+		&upload.CreatedAt,
+		&upload.UpdatedAt,
+		&storageRegion,
+	); err != nil {
+		return nil, err
+	}
+	upload.Purpose = entities.UploadPurpose(purpose)
+	upload.Status = entities.UploadStatus(status)
+	if rejectionReason.Valid {
+		upload.RejectionReason = &rejectionReason.String
+	}
+	if completedAt.Valid {
+		upload.CompletedAt = &completedAt.Time
+	}
+	upload.StorageRegion = entities.ResidencyRegion(storageRegion.String)
+	return upload, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
@@ -18,6 +19,7 @@ import (
 type UserRepository struct {
 	db     *sql.DB
 	logger *zap.Logger
+	fields fieldCipher
 }
 
 // NewUserRepository creates a new user repository
@@ -28,21 +30,47 @@ func NewUserRepository(db *sql.DB, logger *zap.Logger) *UserRepository {
 	}
 }
 
+// SetFieldEncryptor enables field-level encryption of phone numbers and KYC
+// provider references. Existing plaintext rows stay readable until rotated.
+func (r *UserRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+// RotateFieldEncryption re-encrypts phone numbers and KYC provider references
+// under the active key version and backfills their blind indexes
+func (r *UserRepository) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, r.db, r.fields.enc, "users", []encryptedColumn{
+		{name: "phone", indexColumn: "phone_hash"},
+		{name: "kyc_provider_ref", indexColumn: "kyc_provider_ref_hash"},
+	}, batchSize)
+}
+
+// Encrypted user columns
+var (
+	userPhoneField  = fieldColumn{table: "users", column: "phone"}
+	userKYCRefField = fieldColumn{table: "users", column: "kyc_provider_ref"}
+)
+
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *entities.UserProfile) error {
+	sealedPhone, err := r.fields.sealPtr(userPhoneField, user.ID, user.Phone)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt phone: %w", err)
+	}
+
 	query := `
 		INSERT INTO users (
 			id, email, phone, first_name, last_name, date_of_birth,
 			auth_provider_id, email_verified, phone_verified, 
-			onboarding_status, kyc_status, created_at, updated_at
+			onboarding_status, kyc_status, created_at, updated_at, phone_hash
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		user.ID,
 		user.Email,
-		user.Phone,
+		sealedPhone,
 		user.FirstName,
 		user.LastName,
 		user.DateOfBirth,
@@ -53,13 +81,14 @@ func (r *UserRepository) Create(ctx context.Context, user *entities.UserProfile)
 		user.KYCStatus,
 		user.CreatedAt,
 		user.UpdatedAt,
+		r.fields.index(user.Phone),
 	)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return fmt.Errorf("user with email already exists: %w", err)
 		}
-		r.logger.Error("Failed to create user", zap.Error(err), zap.String("email", user.Email))
+		r.logger.Error("Failed to create user", zap.Error(err), logger.Email(user.Email))
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -73,7 +102,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.U
         SELECT id, email, phone, first_name, last_name, date_of_birth,
                auth_provider_id, email_verified, phone_verified,
                onboarding_status, kyc_status, kyc_approved_at, kyc_rejection_reason,
-               is_active, created_at, updated_at
+               timezone, is_active, created_at, updated_at, kyc_pii_token
         FROM users 
         WHERE id = $1`
 
@@ -97,9 +126,11 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.U
 		&user.KYCStatus,
 		&kycApprovedAt,
 		&kycRejectionReason,
+		&user.Timezone,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.KYCPIIToken,
 	)
 
 	if err != nil {
@@ -110,13 +141,24 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.U
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if err := r.fields.openPtr(userPhoneField, user.ID, user.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone: %w", err)
+	}
 	if kycApprovedAt.Valid {
 		user.KYCApprovedAt = &kycApprovedAt.Time
 	}
 	if kycRejectionReason.Valid {
 		user.KYCRejectionReason = &kycRejectionReason.String
 	}
-	// firstName, lastName, dateOfBirth fields not yet implemented in UserProfile entity
+	if firstName.Valid {
+		user.FirstName = &firstName.String
+	}
+	if lastName.Valid {
+		user.LastName = &lastName.String
+	}
+	if dateOfBirth.Valid {
+		user.DateOfBirth = &dateOfBirth.Time
+	}
 
 	return user, nil
 }
@@ -160,16 +202,28 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entitie
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
 		}
-		r.logger.Error("Failed to get user by email", zap.Error(err), zap.String("email", email))
+		r.logger.Error("Failed to get user by email", zap.Error(err), logger.Email(email))
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if err := r.fields.openPtr(userPhoneField, user.ID, user.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone: %w", err)
+	}
 	if kycApprovedAt.Valid {
 		user.KYCApprovedAt = &kycApprovedAt.Time
 	}
 	if kycRejectionReason.Valid {
 		user.KYCRejectionReason = &kycRejectionReason.String
 	}
+	if firstName.Valid {
+		user.FirstName = &firstName.String
+	}
+	if lastName.Valid {
+		user.LastName = &lastName.String
+	}
+	if dateOfBirth.Valid {
+		user.DateOfBirth = &dateOfBirth.Time
+	}
 
 	return user, nil
 }
@@ -217,33 +271,51 @@ func (r *UserRepository) GetByAuthProviderID(ctx context.Context, authProviderID
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if err := r.fields.openPtr(userPhoneField, user.ID, user.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone: %w", err)
+	}
 	if kycApprovedAt.Valid {
 		user.KYCApprovedAt = &kycApprovedAt.Time
 	}
 	if kycRejectionReason.Valid {
 		user.KYCRejectionReason = &kycRejectionReason.String
 	}
+	if firstName.Valid {
+		user.FirstName = &firstName.String
+	}
+	if lastName.Valid {
+		user.LastName = &lastName.String
+	}
+	if dateOfBirth.Valid {
+		user.DateOfBirth = &dateOfBirth.Time
+	}
 
 	return user, nil
 }
 
 // Update updates a user
 func (r *UserRepository) Update(ctx context.Context, user *entities.UserProfile) error {
+	sealedPhone, err := r.fields.sealPtr(userPhoneField, user.ID, user.Phone)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt phone: %w", err)
+	}
+
 	query := `
 		UPDATE users SET 
 			email = $2, phone = $3, first_name = $4, last_name = $5, 
 			date_of_birth = $6, auth_provider_id = $7, email_verified = $8, 
 			phone_verified = $9, onboarding_status = $10, kyc_status = $11, 
-			kyc_approved_at = $12, kyc_rejection_reason = $13, updated_at = $14
+			kyc_approved_at = $12, kyc_rejection_reason = $13, updated_at = $14,
+			phone_hash = $15, kyc_pii_token = COALESCE($16, kyc_pii_token)
 		WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		user.ID,
 		user.Email,
-		user.Phone,
-		"",  // first_name - not yet implemented in UserProfile
-		"",  // last_name - not yet implemented in UserProfile
-		nil, // date_of_birth - not yet implemented in UserProfile
+		sealedPhone,
+		user.FirstName,
+		user.LastName,
+		user.DateOfBirth,
 		user.AuthProviderID,
 		user.EmailVerified,
 		user.PhoneVerified,
@@ -252,6 +324,8 @@ func (r *UserRepository) Update(ctx context.Context, user *entities.UserProfile)
 		user.KYCApprovedAt,
 		user.KYCRejectionReason,
 		time.Now(),
+		r.fields.index(user.Phone),
+		user.KYCPIIToken,
 	)
 
 	if err != nil {
@@ -303,10 +377,16 @@ func (r *UserRepository) UpdateKYCProvider(ctx context.Context, userID uuid.UUID
 		UPDATE users SET 
 			kyc_provider_ref = $2,
 			kyc_status = $3,
-			updated_at = $4
+			updated_at = $4,
+			kyc_provider_ref_hash = $5
 		WHERE id = $1`
 
-	_, err := r.db.ExecContext(ctx, query, userID, providerRef, string(status), time.Now())
+	sealedRef, err := r.fields.seal(userKYCRefField, userID, providerRef)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt KYC provider reference: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query, userID, sealedRef, string(status), time.Now(), r.fields.index(&providerRef))
 	if err != nil {
 		r.logger.Error("Failed to update KYC provider reference", zap.Error(err), zap.String("user_id", userID.String()))
 		return fmt.Errorf("failed to update KYC provider reference: %w", err)
@@ -347,20 +427,25 @@ func (r *UserRepository) CreateUserFromAuth(ctx context.Context, req *entities.R
 		UpdatedAt:        time.Now(),
 	}
 
+	sealedPhone, err := r.fields.sealPtr(userPhoneField, user.ID, user.Phone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt phone: %w", err)
+	}
+
 	// Insert into database
 	query := `
 		INSERT INTO users (
 			id, email, phone, password_hash, auth_provider_id, 
 			email_verified, phone_verified, onboarding_status, kyc_status,
-			role, is_active, created_at, updated_at
+			role, is_active, created_at, updated_at, phone_hash
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)`
 
 	_, err = r.db.ExecContext(ctx, query,
 		user.ID,
 		user.Email,
-		user.Phone,
+		sealedPhone,
 		user.PasswordHash,
 		user.AuthProviderID,
 		user.EmailVerified,
@@ -371,20 +456,21 @@ func (r *UserRepository) CreateUserFromAuth(ctx context.Context, req *entities.R
 		user.IsActive,
 		user.CreatedAt,
 		user.UpdatedAt,
+		r.fields.index(user.Phone),
 	)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			r.logger.Warn("User registration failed - email already exists", zap.String("email", req.Email))
+			r.logger.Warn("User registration failed - email already exists", logger.Email(req.Email))
 			return nil, fmt.Errorf("user with email already exists")
 		}
-		r.logger.Error("Failed to create user", zap.Error(err), zap.String("email", req.Email))
+		r.logger.Error("Failed to create user", zap.Error(err), logger.Email(req.Email))
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	r.logger.Info("User created successfully",
 		zap.String("user_id", user.ID.String()),
-		zap.String("email", user.Email))
+		logger.Email(user.Email))
 
 	return user, nil
 }
@@ -428,13 +514,20 @@ func (r *UserRepository) GetUserByEmailForLogin(ctx context.Context, email strin
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
 		}
-		r.logger.Error("Failed to get user by email for login", zap.Error(err), zap.String("email", email))
+		r.logger.Error("Failed to get user by email for login", zap.Error(err), logger.Email(email))
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Handle nullable fields
+	if err := r.fields.openPtr(userPhoneField, user.ID, user.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone: %w", err)
+	}
 	if kycProviderRef.Valid {
-		user.KYCProviderRef = &kycProviderRef.String
+		ref, err := r.fields.open(userKYCRefField, user.ID, kycProviderRef.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt KYC provider reference: %w", err)
+		}
+		user.KYCProviderRef = &ref
 	}
 	if kycSubmittedAt.Valid {
 		user.KYCSubmittedAt = &kycSubmittedAt.Time
@@ -454,16 +547,16 @@ func (r *UserRepository) GetUserByEmailForLogin(ctx context.Context, email strin
 
 // PhoneExists checks if a phone number already exists
 func (r *UserRepository) PhoneExists(ctx context.Context, phone string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE phone = $1 AND is_active = true)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE (phone = $1 OR phone_hash = $2) AND is_active = true)`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, phone).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, phone, r.fields.index(&phone)).Scan(&exists)
 	if err != nil {
-		r.logger.Error("Failed to check phone existence", zap.Error(err), zap.String("phone", phone))
+		r.logger.Error("Failed to check phone existence", zap.Error(err), logger.Phone(phone))
 		return false, fmt.Errorf("failed to check phone existence: %w", err)
 	}
 
-	r.logger.Debug("Checked phone existence", zap.String("phone", phone), zap.Bool("exists", exists))
+	r.logger.Debug("Checked phone existence", logger.Phone(phone), zap.Bool("exists", exists))
 	return exists, nil
 }
 
@@ -475,13 +568,13 @@ func (r *UserRepository) GetUserByPhoneForLogin(ctx context.Context, phone strin
 		       kyc_provider_ref, kyc_submitted_at, kyc_approved_at, kyc_rejection_reason,
 		       role, is_active, last_login_at, created_at, updated_at
 		FROM users 
-		WHERE phone = $1 AND is_active = true`
+		WHERE (phone = $1 OR phone_hash = $2) AND is_active = true`
 
 	user := &entities.User{}
 	var kycSubmittedAt, kycApprovedAt, lastLoginAt sql.NullTime
 	var kycRejectionReason, kycProviderRef sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, phone).Scan(
+	err := r.db.QueryRowContext(ctx, query, phone, r.fields.index(&phone)).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Phone,
@@ -506,13 +599,20 @@ func (r *UserRepository) GetUserByPhoneForLogin(ctx context.Context, phone strin
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
 		}
-		r.logger.Error("Failed to get user by phone for login", zap.Error(err), zap.String("phone", phone))
+		r.logger.Error("Failed to get user by phone for login", zap.Error(err), logger.Phone(phone))
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Handle nullable fields
+	if err := r.fields.openPtr(userPhoneField, user.ID, user.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone: %w", err)
+	}
 	if kycProviderRef.Valid {
-		user.KYCProviderRef = &kycProviderRef.String
+		ref, err := r.fields.open(userKYCRefField, user.ID, kycProviderRef.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt KYC provider reference: %w", err)
+		}
+		user.KYCProviderRef = &ref
 	}
 	if kycSubmittedAt.Valid {
 		user.KYCSubmittedAt = &kycSubmittedAt.Time
@@ -536,7 +636,7 @@ func (r *UserRepository) GetUserEntityByID(ctx context.Context, id uuid.UUID) (*
 		SELECT id, email, phone, auth_provider_id,
 		       email_verified, phone_verified, onboarding_status, kyc_status,
 		       kyc_provider_ref, kyc_submitted_at, kyc_approved_at, kyc_rejection_reason,
-		       role, is_active, last_login_at, created_at, updated_at
+		       role, timezone, is_active, last_login_at, created_at, updated_at
 		FROM users 
 		WHERE id = $1 AND is_active = true`
 
@@ -558,6 +658,7 @@ func (r *UserRepository) GetUserEntityByID(ctx context.Context, id uuid.UUID) (*
 		&kycApprovedAt,
 		&kycRejectionReason,
 		&user.Role,
+		&user.Timezone,
 		&user.IsActive,
 		&lastLoginAt,
 		&user.CreatedAt,
@@ -573,8 +674,15 @@ func (r *UserRepository) GetUserEntityByID(ctx context.Context, id uuid.UUID) (*
 	}
 
 	// Handle nullable fields
+	if err := r.fields.openPtr(userPhoneField, user.ID, user.Phone); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone: %w", err)
+	}
 	if kycProviderRef.Valid {
-		user.KYCProviderRef = &kycProviderRef.String
+		ref, err := r.fields.open(userKYCRefField, user.ID, kycProviderRef.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt KYC provider reference: %w", err)
+		}
+		user.KYCProviderRef = &ref
 	}
 	if kycSubmittedAt.Valid {
 		user.KYCSubmittedAt = &kycSubmittedAt.Time
@@ -618,16 +726,27 @@ func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, e
 
 	err := r.db.QueryRowContext(ctx, query, email).Scan(&count)
 	if err != nil {
-		r.logger.Error("Failed to check email existence", zap.Error(err), zap.String("email", email))
+		r.logger.Error("Failed to check email existence", zap.Error(err), logger.Email(email))
 		return false, fmt.Errorf("failed to check email: %w", err)
 	}
 
 	return count > 0, nil
 }
 
+// UpdateTimezone sets the IANA time zone the user's scheduled features run in
+func (r *UserRepository) UpdateTimezone(ctx context.Context, userID uuid.UUID, timezone string) error {
+	query := `UPDATE users SET timezone = $2, updated_at = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID, timezone, time.Now())
+	if err != nil {
+		r.logger.Error("Failed to update timezone", zap.Error(err), zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to update timezone: %w", err)
+	}
+	return nil
+}
+
 // UpdatePassword updates the user's password hash
 func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, newHash string) error {
-	query := `UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1`
+	query := `UPDATE users SET password_hash = $2, password_weak_since = NULL, updated_at = $3 WHERE id = $1`
 	now := time.Now()
 	_, err := r.db.ExecContext(ctx, query, userID, newHash, now)
 	if err != nil {
@@ -638,9 +757,27 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, n
 	return nil
 }
 
-// DeactivateUser sets is_active to false for the given user
+// MarkPasswordWeak records when the user was first prompted to replace a
+// password that breaks the policy, keeping an earlier time, and returns it
+func (r *UserRepository) MarkPasswordWeak(ctx context.Context, userID uuid.UUID, at time.Time) (time.Time, error) {
+	query := `
+		UPDATE users SET password_weak_since = COALESCE(password_weak_since, $2)
+		WHERE id = $1
+		RETURNING password_weak_since`
+	var since time.Time
+	if err := r.db.QueryRowContext(ctx, query, userID, at).Scan(&since); err != nil {
+		return time.Time{}, fmt.Errorf("failed to mark password weak: %w", err)
+	}
+	return since, nil
+}
+
+// DeactivateUser sets is_active to false for the given user and records the
+// closure as requested by the user
 func (r *UserRepository) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
-	query := `UPDATE users SET is_active = false, updated_at = $2 WHERE id = $1`
+	query := `
+		UPDATE users
+		SET is_active = false, updated_at = $2, closed_at = $2, closure_reason = 'user_request'
+		WHERE id = $1`
 	now := time.Now()
 	_, err := r.db.ExecContext(ctx, query, userID, now)
 	if err != nil {
@@ -651,6 +788,43 @@ func (r *UserRepository) DeactivateUser(ctx context.Context, userID uuid.UUID) e
 	return nil
 }
 
+// SetActiveStatus suspends or reactivates a user on an admin's behalf.
+// Activating clears any closure; suspending records the reason and notes.
+func (r *UserRepository) SetActiveStatus(ctx context.Context, userID uuid.UUID, active bool, reason entities.AccountClosureReason, notes string) error {
+	query := `
+		UPDATE users
+		SET is_active = $1, updated_at = $2,
+			closed_at = CASE WHEN $1 THEN NULL WHEN is_active THEN $2 ELSE closed_at END,
+			closure_reason = CASE WHEN $1 THEN NULL ELSE $4 END,
+			closure_notes = CASE WHEN $1 THEN NULL ELSE $5 END
+		WHERE id = $3`
+	result, err := r.db.ExecContext(ctx, query, active, time.Now().UTC(), userID, string(reason), notes)
+	if err != nil {
+		r.logger.Error("Failed to set user active status", zap.Error(err), zap.String("user_id", userID.String()))
+		return fmt.Errorf("failed to set user active status: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// GetClosedAccountByEmail retrieves the credentials of a deactivated user so
+// they can authenticate a reactivation request
+func (r *UserRepository) GetClosedAccountByEmail(ctx context.Context, email string) (*entities.User, error) {
+	query := `SELECT id, email, password_hash, is_active FROM users WHERE email = $1 AND is_active = false`
+
+	user := &entities.User{}
+	err := r.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.IsActive)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get closed account: %w", err)
+	}
+	return user, nil
+}
+
 // GetPasscodeMetadata retrieves persisted passcode metadata for a user
 func (r *UserRepository) GetPasscodeMetadata(ctx context.Context, userID uuid.UUID) (*entities.PasscodeMetadata, error) {
 	query := `
@@ -8,11 +8,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
 )
 
 // VirtualAccountRepository implements the virtual account repository interface
 type VirtualAccountRepository struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	fields fieldCipher
 }
 
 // NewVirtualAccountRepository creates a new virtual account repository
@@ -20,29 +22,80 @@ func NewVirtualAccountRepository(db *sqlx.DB) *VirtualAccountRepository {
 	return &VirtualAccountRepository{db: db}
 }
 
+// SetFieldEncryptor enables field-level encryption of account and routing numbers
+func (r *VirtualAccountRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+// RotateFieldEncryption re-encrypts bank account details under the active key
+// version and backfills the account number blind index
+func (r *VirtualAccountRepository) RotateFieldEncryption(ctx context.Context, batchSize int) (FieldRotationResult, error) {
+	return rotateTableFields(ctx, r.db.DB, r.fields.enc, "virtual_accounts", []encryptedColumn{
+		{name: "account_number", indexColumn: "account_number_hash"},
+		{name: "routing_number"},
+	}, batchSize)
+}
+
+// Encrypted virtual account columns
+var (
+	virtualAccountNumberField = fieldColumn{table: "virtual_accounts", column: "account_number"}
+	virtualRoutingNumberField = fieldColumn{table: "virtual_accounts", column: "routing_number"}
+)
+
+// sealAccountNumbers returns the stored forms of the account and routing numbers
+func (r *VirtualAccountRepository) sealAccountNumbers(account *entities.VirtualAccount) (string, string, error) {
+	accountNumber, err := r.fields.seal(virtualAccountNumberField, account.ID, account.AccountNumber)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt account number: %w", err)
+	}
+	routingNumber, err := r.fields.seal(virtualRoutingNumberField, account.ID, account.RoutingNumber)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt routing number: %w", err)
+	}
+	return accountNumber, routingNumber, nil
+}
+
+// openAccountNumbers decrypts the account and routing numbers in place
+func (r *VirtualAccountRepository) openAccountNumbers(account *entities.VirtualAccount) error {
+	var err error
+	if account.AccountNumber, err = r.fields.open(virtualAccountNumberField, account.ID, account.AccountNumber); err != nil {
+		return fmt.Errorf("failed to decrypt account number: %w", err)
+	}
+	if account.RoutingNumber, err = r.fields.open(virtualRoutingNumberField, account.ID, account.RoutingNumber); err != nil {
+		return fmt.Errorf("failed to decrypt routing number: %w", err)
+	}
+	return nil
+}
+
 // Create creates a new virtual account
 func (r *VirtualAccountRepository) Create(ctx context.Context, account *entities.VirtualAccount) error {
+	accountNumber, routingNumber, err := r.sealAccountNumbers(account)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO virtual_accounts (
 			id, user_id, due_account_id, alpaca_account_id,
 			account_number, routing_number, status, currency,
-			created_at, updated_at
+			created_at, updated_at, account_number_hash
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		account.ID,
 		account.UserID,
 		account.DueAccountID,
 		account.AlpacaAccountID,
-		account.AccountNumber,
-		account.RoutingNumber,
+		accountNumber,
+		routingNumber,
 		account.Status,
 		account.Currency,
 		account.CreatedAt,
 		account.UpdatedAt,
+		r.fields.index(&account.AccountNumber),
 	)
 
 	if err != nil {
@@ -71,6 +124,10 @@ func (r *VirtualAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 		return nil, fmt.Errorf("failed to get virtual account: %w", err)
 	}
 
+	if err := r.openAccountNumbers(&account); err != nil {
+		return nil, err
+	}
+
 	return &account, nil
 }
 
@@ -93,6 +150,10 @@ func (r *VirtualAccountRepository) GetByDueAccountID(ctx context.Context, dueAcc
 		return nil, fmt.Errorf("failed to get virtual account: %w", err)
 	}
 
+	if err := r.openAccountNumbers(&account); err != nil {
+		return nil, err
+	}
+
 	return &account, nil
 }
 
@@ -113,11 +174,22 @@ func (r *VirtualAccountRepository) GetByUserID(ctx context.Context, userID uuid.
 		return nil, fmt.Errorf("failed to list virtual accounts: %w", err)
 	}
 
+	for _, account := range accounts {
+		if err := r.openAccountNumbers(account); err != nil {
+			return nil, err
+		}
+	}
+
 	return accounts, nil
 }
 
 // Update updates a virtual account
 func (r *VirtualAccountRepository) Update(ctx context.Context, account *entities.VirtualAccount) error {
+	accountNumber, routingNumber, err := r.sealAccountNumbers(account)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE virtual_accounts
 		SET due_account_id = $2,
@@ -126,19 +198,21 @@ func (r *VirtualAccountRepository) Update(ctx context.Context, account *entities
 			routing_number = $5,
 			status = $6,
 			currency = $7,
-			updated_at = $8
+			updated_at = $8,
+			account_number_hash = $9
 		WHERE id = $1
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		account.ID,
 		account.DueAccountID,
 		account.AlpacaAccountID,
-		account.AccountNumber,
-		account.RoutingNumber,
+		accountNumber,
+		routingNumber,
 		account.Status,
 		account.Currency,
 		account.UpdatedAt,
+		r.fields.index(&account.AccountNumber),
 	)
 
 	if err != nil {
@@ -167,6 +241,10 @@ func (r *VirtualAccountRepository) GetByAlpacaAccountID(ctx context.Context, alp
 		return nil, fmt.Errorf("failed to get virtual account: %w", err)
 	}
 
+	if err := r.openAccountNumbers(&account); err != nil {
+		return nil, err
+	}
+
 	return &account, nil
 }
 
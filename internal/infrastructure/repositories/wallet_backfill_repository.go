@@ -0,0 +1,296 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// WalletBackfillRepository persists wallet backfills and the provisioning jobs
+// they enqueue
+type WalletBackfillRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewWalletBackfillRepository creates a new wallet backfill repository
+func NewWalletBackfillRepository(db *sql.DB, logger *zap.Logger) *WalletBackfillRepository {
+	return &WalletBackfillRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const walletBackfillColumns = `
+	id, chains, status, dry_run, batch_size, batch_interval_seconds, max_in_flight,
+	eligible_users, enqueued_jobs, cursor_user_id, error_message, requested_by,
+	started_at, completed_at, updated_at`
+
+// Create inserts a running backfill. Only one backfill may run at a time.
+func (r *WalletBackfillRepository) Create(ctx context.Context, b *entities.WalletBackfill) error {
+	query := `
+		INSERT INTO wallet_backfills (id, chains, status, dry_run, batch_size, batch_interval_seconds,
+			max_in_flight, eligible_users, requested_by, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		b.ID, pq.Array(b.Chains), string(b.Status), b.DryRun, b.BatchSize, b.BatchIntervalSeconds,
+		b.MaxInFlight, b.EligibleUsers, b.RequestedBy, b.StartedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return entities.ErrWalletBackfillRunning
+		}
+		return fmt.Errorf("failed to create wallet backfill: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a backfill
+func (r *WalletBackfillRepository) Get(ctx context.Context, id uuid.UUID) (*entities.WalletBackfill, error) {
+	b, err := scanWalletBackfill(r.db.QueryRowContext(ctx,
+		`SELECT `+walletBackfillColumns+` FROM wallet_backfills WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrWalletBackfillNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet backfill: %w", err)
+	}
+	return b, nil
+}
+
+// List returns recent backfills, newest first
+func (r *WalletBackfillRepository) List(ctx context.Context, limit int) ([]*entities.WalletBackfill, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+walletBackfillColumns+` FROM wallet_backfills ORDER BY started_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet backfills: %w", err)
+	}
+	defer rows.Close()
+
+	var backfills []*entities.WalletBackfill
+	for rows.Next() {
+		b, err := scanWalletBackfill(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wallet backfill: %w", err)
+		}
+		backfills = append(backfills, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate wallet backfills: %w", err)
+	}
+	return backfills, nil
+}
+
+// RecordProgress stores the enqueue count and keyset cursor after a batch
+func (r *WalletBackfillRepository) RecordProgress(ctx context.Context, id uuid.UUID, enqueued int, cursor *uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE wallet_backfills SET enqueued_jobs = $2, cursor_user_id = $3, updated_at = NOW()
+		WHERE id = $1`, id, enqueued, cursor)
+	if err != nil {
+		return fmt.Errorf("failed to record wallet backfill progress: %w", err)
+	}
+	return nil
+}
+
+// Finish moves a running backfill to a terminal status. It returns false when
+// the backfill had already stopped, e.g. because it was cancelled.
+func (r *WalletBackfillRepository) Finish(ctx context.Context, id uuid.UUID, status entities.WalletBackfillStatus, errorMessage *string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE wallet_backfills SET status = $2, error_message = $3, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $4`,
+		id, string(status), errorMessage, string(entities.WalletBackfillRunning))
+	if err != nil {
+		return false, fmt.Errorf("failed to finish wallet backfill: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to finish wallet backfill: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// CountEligibleUsers counts active, KYC-approved users missing a wallet on any of chains
+func (r *WalletBackfillRepository) CountEligibleUsers(ctx context.Context, chains []string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM users u
+		WHERE `+walletBackfillEligibility, pq.Array(chains)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count backfill candidates: %w", err)
+	}
+	return count, nil
+}
+
+// ListCandidates returns the next eligible users after cursor in ID order,
+// with the subset of chains each one is missing
+func (r *WalletBackfillRepository) ListCandidates(ctx context.Context, chains []string, after *uuid.UUID, limit int) ([]entities.WalletBackfillCandidate, error) {
+	cursor := uuid.Nil
+	if after != nil {
+		cursor = *after
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, ARRAY(
+			SELECT c.chain FROM unnest($1::text[]) AS c(chain)
+			WHERE NOT EXISTS (SELECT 1 FROM managed_wallets w WHERE w.user_id = u.id AND w.chain = c.chain)
+		)
+		FROM users u
+		WHERE `+walletBackfillEligibility+` AND u.id > $2
+		ORDER BY u.id
+		LIMIT $3`, pq.Array(chains), cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfill candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []entities.WalletBackfillCandidate
+	for rows.Next() {
+		var candidate entities.WalletBackfillCandidate
+		var missing pq.StringArray
+		if err := rows.Scan(&candidate.UserID, &missing); err != nil {
+			return nil, fmt.Errorf("failed to scan backfill candidate: %w", err)
+		}
+		candidate.MissingChains = append([]string(nil), missing...)
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate backfill candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// walletBackfillEligibility selects users past KYC with no provisioning job in
+// flight who lack a wallet on at least one chain in $1
+const walletBackfillEligibility = `
+	COALESCE(u.is_active, TRUE)
+	AND u.onboarding_status IN ('kyc_approved', 'wallets_pending', 'completed')
+	AND NOT EXISTS (
+		SELECT 1 FROM wallet_provisioning_jobs j
+		WHERE j.user_id = u.id AND j.status IN ('queued', 'in_progress', 'retry')
+	)
+	AND EXISTS (
+		SELECT 1 FROM unnest($1::text[]) AS c(chain)
+		WHERE NOT EXISTS (SELECT 1 FROM managed_wallets w WHERE w.user_id = u.id AND w.chain = c.chain)
+	)`
+
+// EnqueueJobs inserts one queued provisioning job per candidate, due
+// immediately, in a single transaction
+func (r *WalletBackfillRepository) EnqueueJobs(ctx context.Context, backfillID uuid.UUID, candidates []entities.WalletBackfillCandidate, maxAttempts int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin backfill batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO wallet_provisioning_jobs (id, user_id, chains, status, attempt_count, max_attempts,
+			next_retry_at, backfill_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, $7, $6, $6)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare backfill batch: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, candidate := range candidates {
+		if _, err := stmt.ExecContext(ctx, uuid.New(), candidate.UserID, pq.Array(candidate.MissingChains),
+			string(entities.ProvisioningStatusQueued), maxAttempts, now, backfillID); err != nil {
+			return fmt.Errorf("failed to enqueue provisioning job: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit backfill batch: %w", err)
+	}
+	return nil
+}
+
+// CountJobs breaks the backfill's provisioning jobs down by status
+func (r *WalletBackfillRepository) CountJobs(ctx context.Context, backfillID uuid.UUID) (entities.WalletBackfillJobCounts, error) {
+	var counts entities.WalletBackfillJobCounts
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, COUNT(*) FROM wallet_provisioning_jobs
+		WHERE backfill_id = $1
+		GROUP BY status`, backfillID)
+	if err != nil {
+		return counts, fmt.Errorf("failed to count backfill jobs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return counts, fmt.Errorf("failed to scan backfill job count: %w", err)
+		}
+		switch entities.WalletProvisioningJobStatus(status) {
+		case entities.ProvisioningStatusQueued:
+			counts.Queued = count
+		case entities.ProvisioningStatusInProgress:
+			counts.InProgress = count
+		case entities.ProvisioningStatusRetry:
+			counts.Retry = count
+		case entities.ProvisioningStatusCompleted:
+			counts.Completed = count
+		case entities.ProvisioningStatusFailed:
+			counts.Failed = count
+		}
+	}
+	return counts, rows.Err()
+}
+
+type walletBackfillScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWalletBackfill(row walletBackfillScanner) (*entities.WalletBackfill, error) {
+	b := &entities.WalletBackfill{}
+	var chains pq.StringArray
+	var status string
+	var cursor, requestedBy uuid.NullUUID
+	var errorMessage sql.NullString
+	var completedAt sql.NullTime
+
+	if err := row.Scan(
+		&b.ID,
+		&chains,
+		&status,
+		&b.DryRun,
+		&b.BatchSize,
+		&b.BatchIntervalSeconds,
+		&b.MaxInFlight,
+		&b.EligibleUsers,
+		&b.EnqueuedJobs,
+		&cursor,
+		&errorMessage,
+		&requestedBy,
+		&b.StartedAt,
+		&completedAt,
+		&b.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	b.Chains = append([]string(nil), chains...)
+	b.Status = entities.WalletBackfillStatus(status)
+	if cursor.Valid {
+		b.CursorUserID = &cursor.UUID
+	}
+	if errorMessage.Valid {
+		b.ErrorMessage = &errorMessage.String
+	}
+	if requestedBy.Valid {
+		b.RequestedBy = &requestedBy.UUID
+	}
+	if completedAt.Valid {
+		b.CompletedAt = &completedAt.Time
+	}
+	return b, nil
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// WalletBalanceCacheRepository stores the last known Circle balance of each
+// live managed wallet
+type WalletBalanceCacheRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewWalletBalanceCacheRepository creates a new wallet balance cache repository
+func NewWalletBalanceCacheRepository(db *sql.DB, logger *zap.Logger) *WalletBalanceCacheRepository {
+	return &WalletBalanceCacheRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// walletBalanceSelect joins every live Circle wallet to its cache row, so
+// wallets never synced are returned with a nil last_synced_at
+const walletBalanceSelect = `
+	SELECT w.id, w.user_id, w.circle_wallet_id, w.chain,
+		COALESCE(b.usdc_balance, 0), b.last_synced_at, b.last_error
+	FROM managed_wallets w
+	LEFT JOIN wallet_balance_cache b ON b.managed_wallet_id = w.id
+	WHERE w.status = 'live' AND w.circle_wallet_id <> ''`
+
+// ListByUser returns the cached balances of a user's live wallets
+func (r *WalletBalanceCacheRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WalletBalanceSnapshot, error) {
+	return r.query(ctx, walletBalanceSelect+` AND w.user_id = $1 ORDER BY w.chain`, userID)
+}
+
+// ListStale returns wallets never synced or last synced before the cutoff,
+// least recently synced first
+func (r *WalletBalanceCacheRepository) ListStale(ctx context.Context, syncedBefore time.Time, limit int) ([]*entities.WalletBalanceSnapshot, error) {
+	return r.query(ctx, walletBalanceSelect+`
+		AND (b.last_synced_at IS NULL OR b.last_synced_at < $1)
+		ORDER BY b.last_synced_at NULLS FIRST
+		LIMIT $2`, syncedBefore, limit)
+}
+
+// Save records a successful balance read and clears any previous error
+func (r *WalletBalanceCacheRepository) Save(ctx context.Context, snapshot *entities.WalletBalanceSnapshot) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO wallet_balance_cache (managed_wallet_id, user_id, usdc_balance, last_synced_at, last_error, updated_at)
+		VALUES ($1, $2, $3, $4, NULL, NOW())
+		ON CONFLICT (managed_wallet_id) DO UPDATE SET
+			usdc_balance = EXCLUDED.usdc_balance,
+			last_synced_at = EXCLUDED.last_synced_at,
+			last_error = NULL,
+			updated_at = NOW()`,
+		snapshot.WalletID, snapshot.UserID, snapshot.USDCBalance, snapshot.LastSyncedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save wallet balance: %w", err)
+	}
+	return nil
+}
+
+// RecordError records a failed refresh, keeping the last known balance
+func (r *WalletBalanceCacheRepository) RecordError(ctx context.Context, walletID, userID uuid.UUID, message string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO wallet_balance_cache (managed_wallet_id, user_id, last_error, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (managed_wallet_id) DO UPDATE SET
+			last_error = EXCLUDED.last_error,
+			updated_at = NOW()`,
+		walletID, userID, message)
+	if err != nil {
+		return fmt.Errorf("failed to record wallet balance error: %w", err)
+	}
+	return nil
+}
+
+// SaveBulk records balances read in bulk, keyed by Circle wallet ID. Wallets
+// that are not managed wallets are ignored; returns how many were saved.
+func (r *WalletBalanceCacheRepository) SaveBulk(ctx context.Context, balances map[string]decimal.Decimal, syncedAt time.Time) (int, error) {
+	if len(balances) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, 0, len(balances))
+	amounts := make([]string, 0, len(balances))
+	for id, amount := range balances {
+		ids = append(ids, id)
+		amounts = append(amounts, amount.String())
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO wallet_balance_cache (managed_wallet_id, user_id, usdc_balance, last_synced_at, last_error, updated_at)
+		SELECT w.id, w.user_id, v.balance, $3, NULL, NOW()
+		FROM unnest($1::text[], $2::numeric[]) AS v(circle_wallet_id, balance)
+		JOIN managed_wallets w ON w.circle_wallet_id = v.circle_wallet_id
+		ON CONFLICT (managed_wallet_id) DO UPDATE SET
+			usdc_balance = EXCLUDED.usdc_balance,
+			last_synced_at = EXCLUDED.last_synced_at,
+			last_error = NULL,
+			updated_at = NOW()`,
+		pq.Array(ids), pq.Array(amounts), syncedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save wallet balances: %w", err)
+	}
+	saved, _ := result.RowsAffected()
+	return int(saved), nil
+}
+
+// Sum totals the cached USDC balance across live wallets. Wallets not synced
+// since syncedSince are counted as skipped; their last known balance, if any,
+// is still included in the total.
+func (r *WalletBalanceCacheRepository) Sum(ctx context.Context, syncedSince time.Time) (*entities.BalanceAggregate, error) {
+	aggregate := &entities.BalanceAggregate{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(b.usdc_balance), 0),
+			COUNT(*) FILTER (WHERE b.last_synced_at >= $1),
+			COUNT(*) FILTER (WHERE b.last_synced_at IS NULL OR b.last_synced_at < $1)
+		FROM managed_wallets w
+		LEFT JOIN wallet_balance_cache b ON b.managed_wallet_id = w.id
+		WHERE w.status = 'live' AND w.circle_wallet_id <> ''`, syncedSince).
+		Scan(&aggregate.Total, &aggregate.Counted, &aggregate.Skipped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum wallet balances: %w", err)
+	}
+	return aggregate, nil
+}
+
+func (r *WalletBalanceCacheRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entities.WalletBalanceSnapshot, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*entities.WalletBalanceSnapshot
+	for rows.Next() {
+		s := &entities.WalletBalanceSnapshot{}
+		var syncedAt sql.NullTime
+		var lastError sql.NullString
+		if err := rows.Scan(&s.WalletID, &s.UserID, &s.CircleWalletID, &s.Chain,
+			&s.USDCBalance, &syncedAt, &lastError); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet balance: %w", err)
+		}
+		if syncedAt.Valid {
+			s.LastSyncedAt = &syncedAt.Time
+		}
+		if lastError.Valid {
+			s.LastError = &lastError.String
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate wallet balances: %w", err)
+	}
+	return snapshots, nil
+}
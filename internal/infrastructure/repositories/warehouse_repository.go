@@ -0,0 +1,319 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// WarehouseRepository reads business events for the data warehouse from
+// their source tables and stores each stream's export cursor
+type WarehouseRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewWarehouseRepository creates a new warehouse export repository
+func NewWarehouseRepository(db *sql.DB, logger *zap.Logger) *WarehouseRepository {
+	return &WarehouseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const warehouseCursorColumns = `
+	stream, position, last_id, exported_count, generation, last_error, last_export_at, updated_at`
+
+// ClaimStream locks a stream's cursor until now+lease, creating it at the
+// epoch the first time so the whole history is exported
+func (r *WarehouseRepository) ClaimStream(ctx context.Context, stream entities.WarehouseStream, now time.Time, lease time.Duration) (*entities.WarehouseCursor, error) {
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO warehouse_export_cursors (stream) VALUES ($1)
+		ON CONFLICT (stream) DO NOTHING`, string(stream)); err != nil {
+		return nil, fmt.Errorf("failed to create warehouse cursor: %w", err)
+	}
+
+	cursor, err := scanWarehouseCursor(r.db.QueryRowContext(ctx, `
+		UPDATE warehouse_export_cursors SET locked_until = $3
+		WHERE stream = $1 AND (locked_until IS NULL OR locked_until < $2)
+		RETURNING `+warehouseCursorColumns,
+		string(stream), now, now.Add(lease)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrWarehouseStreamBusy
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim warehouse stream: %w", err)
+	}
+	return cursor, nil
+}
+
+// SaveCursor stores the cursor's progress unless a backfill moved it since
+// it was claimed
+func (r *WarehouseRepository) SaveCursor(ctx context.Context, cursor *entities.WarehouseCursor) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE warehouse_export_cursors SET
+			position = $2, last_id = $3, exported_count = $4, last_export_at = $5,
+			last_error = NULL, updated_at = $6
+		WHERE stream = $1 AND generation = $7`,
+		string(cursor.Stream), cursor.Position, cursor.LastID, cursor.ExportedCount,
+		cursor.LastExportAt, cursor.UpdatedAt, cursor.Generation)
+	if err != nil {
+		r.logger.Error("Failed to save warehouse cursor", zap.Error(err), zap.String("stream", string(cursor.Stream)))
+		return fmt.Errorf("failed to save warehouse cursor: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entities.ErrWarehouseCursorMoved
+	}
+	return nil
+}
+
+// ReleaseStream unlocks a stream and records the error that stopped its run,
+// or clears it
+func (r *WarehouseRepository) ReleaseStream(ctx context.Context, stream entities.WarehouseStream, lastError *string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE warehouse_export_cursors SET locked_until = NULL, last_error = $2, updated_at = NOW()
+		WHERE stream = $1`, string(stream), lastError)
+	if err != nil {
+		return fmt.Errorf("failed to release warehouse stream: %w", err)
+	}
+	return nil
+}
+
+// ResetStreams moves the streams' cursors back to from and bumps their
+// generation, so a run in progress cannot overwrite the reset
+func (r *WarehouseRepository) ResetStreams(ctx context.Context, streams []entities.WarehouseStream, from time.Time) error {
+	names := make([]string, len(streams))
+	for i, stream := range streams {
+		names[i] = string(stream)
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO warehouse_export_cursors (stream, position, last_id)
+		SELECT unnest($1::text[]), $2, ''
+		ON CONFLICT (stream) DO UPDATE SET
+			position = LEAST(warehouse_export_cursors.position, EXCLUDED.position),
+			last_id = '', generation = warehouse_export_cursors.generation + 1,
+			last_error = NULL, updated_at = NOW()`,
+		pq.Array(names), from)
+	if err != nil {
+		r.logger.Error("Failed to reset warehouse cursors", zap.Error(err))
+		return fmt.Errorf("failed to reset warehouse cursors: %w", err)
+	}
+	return nil
+}
+
+// ListCursors returns every stream's cursor
+func (r *WarehouseRepository) ListCursors(ctx context.Context) ([]*entities.WarehouseCursor, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+warehouseCursorColumns+` FROM warehouse_export_cursors ORDER BY stream`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouse cursors: %w", err)
+	}
+	defer rows.Close()
+
+	var cursors []*entities.WarehouseCursor
+	for rows.Next() {
+		cursor, err := scanWarehouseCursor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan warehouse cursor: %w", err)
+		}
+		cursors = append(cursors, cursor)
+	}
+	return cursors, rows.Err()
+}
+
+// Each stream selects (position, source id, user id) followed by its own
+// columns, filtered to rows after the cursor ($1, $2) and at or before $3
+var warehouseStreamQueries = map[entities.WarehouseStream]string{
+	entities.WarehouseStreamSignups: `
+		SELECT created_at, id::text, id, account_type, onboarding_status, COALESCE(kyc_status, '')
+		FROM users
+		WHERE created_at IS NOT NULL
+			AND (created_at > $1 OR (created_at = $1 AND id::text > $2)) AND created_at <= $3
+		ORDER BY created_at, id
+		LIMIT $4`,
+	entities.WarehouseStreamDeposits: `
+		SELECT COALESCE(credited_at, confirmed_at) AS position, id::text, user_id,
+			chain, token, COALESCE(credited_amount, amount)
+		FROM deposits
+		WHERE status IN ('confirmed', 'credited', 'off_ramp_initiated', 'off_ramp_completed', 'broker_funded')
+			AND COALESCE(credited_at, confirmed_at) IS NOT NULL
+			AND (COALESCE(credited_at, confirmed_at) > $1 OR (COALESCE(credited_at, confirmed_at) = $1 AND id::text > $2))
+			AND COALESCE(credited_at, confirmed_at) <= $3
+		ORDER BY COALESCE(credited_at, confirmed_at), id
+		LIMIT $4`,
+	entities.WarehouseStreamTrades: `
+		SELECT updated_at, id::text, user_id, basket_id, side, order_type, amount
+		FROM orders
+		WHERE status = 'filled' AND NOT paper
+			AND (updated_at > $1 OR (updated_at = $1 AND id::text > $2)) AND updated_at <= $3
+		ORDER BY updated_at, id
+		LIMIT $4`,
+	entities.WarehouseStreamChurnSignal: `
+		SELECT updated_at, user_id::text, user_id, stage, last_activity_at
+		FROM account_inactivity
+		WHERE stage > 0
+			AND (updated_at > $1 OR (updated_at = $1 AND user_id::text > $2)) AND updated_at <= $3
+		ORDER BY updated_at, user_id
+		LIMIT $4`,
+	entities.WarehouseStreamExperimentExposures: `
+		SELECT ev.created_at, ev.id::text, ev.user_id, x.key, ev.variant, ev.goal
+		FROM experiment_events ev
+		JOIN experiments x ON x.id = ev.experiment_id
+		WHERE ev.kind = 'exposure'
+			AND (ev.created_at > $1 OR (ev.created_at = $1 AND ev.id::text > $2)) AND ev.created_at <= $3
+		ORDER BY ev.created_at, ev.id
+		LIMIT $4`,
+	entities.WarehouseStreamExperimentConversions: `
+		SELECT ev.created_at, ev.id::text, ev.user_id, x.key, ev.variant, ev.goal
+		FROM experiment_events ev
+		JOIN experiments x ON x.id = ev.experiment_id
+		WHERE ev.kind = 'conversion'
+			AND (ev.created_at > $1 OR (ev.created_at = $1 AND ev.id::text > $2)) AND ev.created_at <= $3
+		ORDER BY ev.created_at, ev.id
+		LIMIT $4`,
+}
+
+// ReadEvents reads the stream's next events after the cursor and normalizes
+// them into warehouse events
+func (r *WarehouseRepository) ReadEvents(ctx context.Context, cursor *entities.WarehouseCursor, until time.Time, limit int) ([]*entities.WarehouseEvent, error) {
+	query, ok := warehouseStreamQueries[cursor.Stream]
+	if !ok {
+		return nil, fmt.Errorf("unknown warehouse stream %q", cursor.Stream)
+	}
+	schema := entities.WarehouseSchemas[cursor.Stream]
+
+	rows, err := r.db.QueryContext(ctx, query, cursor.Position, cursor.LastID, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s events: %w", cursor.Stream, err)
+	}
+	defer rows.Close()
+
+	var events []*entities.WarehouseEvent
+	for rows.Next() {
+		event := &entities.WarehouseEvent{
+			Stream:        cursor.Stream,
+			Type:          schema.EventType,
+			SchemaVersion: schema.Version,
+		}
+		var userID uuid.UUID
+		if err := scanWarehouseEvent(rows, cursor.Stream, event, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan %s event: %w", cursor.Stream, err)
+		}
+		event.UserID = &userID
+		event.OccurredAt = event.Position
+		if event.ID == "" {
+			event.ID = fmt.Sprintf("%s:%s", event.Type, event.SourceID)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate %s events: %w", cursor.Stream, err)
+	}
+	return events, nil
+}
+
+func scanWarehouseEvent(rows *sql.Rows, stream entities.WarehouseStream, event *entities.WarehouseEvent, userID *uuid.UUID) error {
+	switch stream {
+	case entities.WarehouseStreamSignups:
+		var accountType, onboardingStatus, kycStatus string
+		if err := rows.Scan(&event.Position, &event.SourceID, userID, &accountType, &onboardingStatus, &kycStatus); err != nil {
+			return err
+		}
+		event.Properties = map[string]interface{}{
+			"account_type":      accountType,
+			"onboarding_status": onboardingStatus,
+			"kyc_status":        kycStatus,
+		}
+	case entities.WarehouseStreamDeposits:
+		var chain, token string
+		var amount decimal.Decimal
+		if err := rows.Scan(&event.Position, &event.SourceID, userID, &chain, &token, &amount); err != nil {
+			return err
+		}
+		event.Properties = map[string]interface{}{
+			"chain":    chain,
+			"token":    token,
+			"amount":   amount.String(),
+			"currency": "USD",
+		}
+	case entities.WarehouseStreamTrades:
+		var basketID uuid.UUID
+		var side, orderType string
+		var amount decimal.Decimal
+		if err := rows.Scan(&event.Position, &event.SourceID, userID, &basketID, &side, &orderType, &amount); err != nil {
+			return err
+		}
+		event.Properties = map[string]interface{}{
+			"basket_id":  basketID.String(),
+			"side":       side,
+			"order_type": orderType,
+			"amount":     amount.String(),
+			"currency":   "USD",
+		}
+	case entities.WarehouseStreamChurnSignal:
+		var stage int
+		var lastActivityAt time.Time
+		if err := rows.Scan(&event.Position, &event.SourceID, userID, &stage, &lastActivityAt); err != nil {
+			return err
+		}
+		// A user who comes back and lapses again reaches the same stage with
+		// a later last activity, which is a new signal
+		event.ID = fmt.Sprintf("%s:%s:%d:%d", event.Type, event.SourceID, stage, lastActivityAt.Unix())
+		event.Properties = map[string]interface{}{
+			"stage":            stage,
+			"last_activity_at": lastActivityAt.UTC().Format(time.RFC3339),
+		}
+	case entities.WarehouseStreamExperimentExposures, entities.WarehouseStreamExperimentConversions:
+		var experiment, variant, goal string
+		if err := rows.Scan(&event.Position, &event.SourceID, userID, &experiment, &variant, &goal); err != nil {
+			return err
+		}
+		event.Properties = map[string]interface{}{
+			"experiment": experiment,
+			"variant":    variant,
+		}
+		if stream == entities.WarehouseStreamExperimentConversions {
+			event.Properties["goal"] = goal
+		}
+	}
+	return nil
+}
+
+type warehouseCursorScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWarehouseCursor(row warehouseCursorScanner) (*entities.WarehouseCursor, error) {
+	cursor := &entities.WarehouseCursor{}
+	var stream string
+	var lastError sql.NullString
+	var lastExportAt sql.NullTime
+	if err := row.Scan(
+		&stream,
+		&cursor.Position,
+		&cursor.LastID,
+		&cursor.ExportedCount,
+		&cursor.Generation,
+		&lastError,
+		&lastExportAt,
+		&cursor.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	cursor.Stream = entities.WarehouseStream(stream)
+	if lastError.Valid {
+		cursor.LastError = &lastError.String
+	}
+	if lastExportAt.Valid {
+		cursor.LastExportAt = &lastExportAt.Time
+	}
+	return cursor, nil
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// WebhookArchiveRepository stores the searchable metadata of archived
+// provider webhooks and the records they are linked to
+type WebhookArchiveRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+	fields fieldCipher
+}
+
+// NewWebhookArchiveRepository creates a new webhook archive repository
+func NewWebhookArchiveRepository(db *sql.DB, logger *zap.Logger) *WebhookArchiveRepository {
+	return &WebhookArchiveRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetFieldEncryptor lets KYC webhooks be matched to users whose provider
+// reference is encrypted, through its blind index
+func (r *WebhookArchiveRepository) SetFieldEncryptor(enc *crypto.FieldEncryptor) {
+	r.fields = fieldCipher{enc: enc}
+}
+
+const webhookArchiveColumns = `w.id, w.provider, w.event_type, w.reference, w.user_id, w.storage_uri, w.sha256,
+	w.size_bytes, w.content_type, w.response_status, w.received_at`
+
+// Each query selects (subject type, subject id, user id) for the records a
+// provider's reference identifies. $1 is the reference and, where a query
+// uses it, $2 its blind index.
+var webhookSubjectQueries = map[string]string{
+	entities.WebhookProviderChain: `
+		SELECT 'deposit', id, user_id FROM deposits WHERE tx_hash = $1
+		UNION ALL
+		SELECT 'withdrawal', id, user_id FROM withdrawals WHERE tx_hash = $1`,
+	entities.WebhookProviderBrokerage: `
+		SELECT 'order', id, user_id FROM orders WHERE id::text = $1`,
+	entities.WebhookProviderKYC: `
+		SELECT 'user', id, id FROM users WHERE kyc_provider_ref = $1 OR kyc_provider_ref_hash = $2`,
+	// Credits still unmatched have no user to file the webhook under
+	entities.WebhookProviderBank: `
+		SELECT 'bank_credit', id, user_id FROM bank_credits WHERE provider_ref = $1 AND user_id IS NOT NULL`,
+}
+
+// Create records an archived webhook
+func (r *WebhookArchiveRepository) Create(ctx context.Context, webhook *entities.ArchivedWebhook) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_archive (id, provider, event_type, reference, user_id, storage_uri, sha256,
+			size_bytes, content_type, response_status, received_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11)`,
+		webhook.ID, webhook.Provider, webhook.EventType, webhook.Reference, webhook.UserID, webhook.StorageURI,
+		webhook.SHA256, webhook.SizeBytes, webhook.ContentType, webhook.ResponseStatus, webhook.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to archive webhook: %w", err)
+	}
+	return nil
+}
+
+// Get returns an archived webhook with its links
+func (r *WebhookArchiveRepository) Get(ctx context.Context, id uuid.UUID) (*entities.ArchivedWebhook, error) {
+	webhook, err := scanArchivedWebhook(r.db.QueryRowContext(ctx, `
+		SELECT `+webhookArchiveColumns+` FROM webhook_archive w WHERE w.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrArchivedWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived webhook: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT subject_type, subject_id FROM webhook_archive_links
+		WHERE webhook_id = $1
+		ORDER BY subject_type, subject_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook links: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var link entities.WebhookLink
+		if err := rows.Scan(&link.SubjectType, &link.SubjectID); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook link: %w", err)
+		}
+		webhook.Links = append(webhook.Links, link)
+	}
+	return webhook, rows.Err()
+}
+
+// Search returns archived webhooks matching the filter, newest first
+func (r *WebhookArchiveRepository) Search(ctx context.Context, filter entities.WebhookArchiveFilter) ([]*entities.ArchivedWebhook, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+webhookArchiveColumns+` FROM webhook_archive w
+		WHERE ($1 = '' OR w.provider = $1)
+		  AND ($2 = '' OR w.event_type = $2)
+		  AND ($3 = '' OR w.reference = $3)
+		  AND ($4::uuid IS NULL OR w.user_id = $4)
+		  AND ($5::timestamptz IS NULL OR w.received_at >= $5)
+		  AND ($6::timestamptz IS NULL OR w.received_at < $6)
+		  AND ($7 = '' OR EXISTS (
+				SELECT 1 FROM webhook_archive_links l
+				WHERE l.webhook_id = w.id AND l.subject_type = $7 AND l.subject_id = $8))
+		ORDER BY w.received_at DESC, w.id
+		LIMIT $9 OFFSET $10`,
+		filter.Provider, filter.EventType, filter.Reference, filter.UserID, filter.From, filter.To,
+		filter.SubjectType, filter.SubjectID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search webhook archive: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*entities.ArchivedWebhook{}
+	for rows.Next() {
+		webhook, err := scanArchivedWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan archived webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// ResolveSubjects finds the records a provider's reference identifies
+func (r *WebhookArchiveRepository) ResolveSubjects(ctx context.Context, provider, reference string) ([]entities.WebhookLink, *uuid.UUID, error) {
+	query, ok := webhookSubjectQueries[provider]
+	if !ok {
+		return nil, nil, nil
+	}
+	args := []interface{}{reference}
+	if strings.Contains(query, "$2") {
+		args = append(args, r.fields.index(&reference))
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve %s webhook subjects: %w", provider, err)
+	}
+	defer rows.Close()
+
+	var links []entities.WebhookLink
+	var userID *uuid.UUID
+	for rows.Next() {
+		var link entities.WebhookLink
+		var owner uuid.UUID
+		if err := rows.Scan(&link.SubjectType, &link.SubjectID, &owner); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan webhook subject: %w", err)
+		}
+		links = append(links, link)
+		if userID == nil {
+			userID = &owner
+		}
+	}
+	return links, userID, rows.Err()
+}
+
+// AddLinks records the webhook's links and the user it concerns
+func (r *WebhookArchiveRepository) AddLinks(ctx context.Context, id uuid.UUID, userID *uuid.UUID, links []entities.WebhookLink) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if userID != nil {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE webhook_archive SET user_id = $2 WHERE id = $1 AND user_id IS NULL`, id, *userID); err != nil {
+			return fmt.Errorf("failed to set webhook user: %w", err)
+		}
+	}
+	for _, link := range links {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO webhook_archive_links (webhook_id, subject_type, subject_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`, id, link.SubjectType, link.SubjectID); err != nil {
+			return fmt.Errorf("failed to link webhook: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+type archivedWebhookScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArchivedWebhook(row archivedWebhookScanner) (*entities.ArchivedWebhook, error) {
+	var webhook entities.ArchivedWebhook
+	var reference sql.NullString
+	if err := row.Scan(&webhook.ID, &webhook.Provider, &webhook.EventType, &reference, &webhook.UserID,
+		&webhook.StorageURI, &webhook.SHA256, &webhook.SizeBytes, &webhook.ContentType,
+		&webhook.ResponseStatus, &webhook.ReceivedAt); err != nil {
+		return nil, err
+	}
+	webhook.Reference = reference.String
+	return &webhook, nil
+}
@@ -27,8 +27,8 @@ func (r *WithdrawalRepository) Create(ctx context.Context, withdrawal *entities.
 	query := `
 		INSERT INTO withdrawals (
 			id, user_id, alpaca_account_id, amount, destination_chain, destination_address,
-			status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			recipient_id, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -38,6 +38,7 @@ func (r *WithdrawalRepository) Create(ctx context.Context, withdrawal *entities.
 		withdrawal.Amount,
 		withdrawal.DestinationChain,
 		withdrawal.DestinationAddress,
+		withdrawal.RecipientID,
 		withdrawal.Status,
 		withdrawal.CreatedAt,
 		withdrawal.UpdatedAt,
@@ -54,7 +55,7 @@ func (r *WithdrawalRepository) Create(ctx context.Context, withdrawal *entities.
 func (r *WithdrawalRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Withdrawal, error) {
 	query := `
 		SELECT id, user_id, alpaca_account_id, amount, destination_chain, destination_address,
-			status, alpaca_journal_id, due_transfer_id, due_recipient_id, tx_hash, error_message,
+			recipient_id, status, alpaca_journal_id, due_transfer_id, due_recipient_id, tx_hash, error_message,
 			created_at, updated_at, completed_at
 		FROM withdrawals
 		WHERE id = $1
@@ -76,7 +77,7 @@ func (r *WithdrawalRepository) GetByID(ctx context.Context, id uuid.UUID) (*enti
 func (r *WithdrawalRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Withdrawal, error) {
 	query := `
 		SELECT id, user_id, alpaca_account_id, amount, destination_chain, destination_address,
-			status, alpaca_journal_id, due_transfer_id, due_recipient_id, tx_hash, error_message,
+			recipient_id, status, alpaca_journal_id, due_transfer_id, due_recipient_id, tx_hash, error_message,
 			created_at, updated_at, completed_at
 		FROM withdrawals
 		WHERE user_id = $1
@@ -0,0 +1,257 @@
+package event_fanout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/eventbus"
+)
+
+// Consumer groups. Each group receives every message on its topic.
+const (
+	GroupOutboundWebhooks     = "outbound-webhooks"
+	GroupEventStream          = "event-stream"
+	GroupDepositNotifications = "deposit-notifications"
+	GroupNotificationDispatch = "notification-dispatch"
+	GroupBalanceCache         = "balance-cache"
+	GroupPromotions           = "promotions"
+	GroupHotCache             = "hot-cache"
+)
+
+// WebhookPublisher queues partner webhooks
+type WebhookPublisher interface {
+	PublishEvent(ctx context.Context, eventID uuid.UUID, eventType entities.WebhookEventType, data interface{}) error
+}
+
+// ProgressPublisher pushes events to a user's live stream
+type ProgressPublisher interface {
+	PublishToUser(ctx context.Context, userID uuid.UUID, eventType entities.StreamEventType, data interface{}) error
+}
+
+// NotificationDispatcher sends notifications on their channel
+type NotificationDispatcher interface {
+	Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+	Deliver(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error
+}
+
+// BalanceRefresher re-reads a user's wallet balances
+type BalanceRefresher interface {
+	RefreshUser(ctx context.Context, userID uuid.UUID) (*entities.UserWalletBalances, error)
+}
+
+// PromotionGranter grants deposit-triggered promotions
+type PromotionGranter interface {
+	HandleDepositCredited(ctx context.Context, userID uuid.UUID) error
+}
+
+// HotCacheEvictor drops a user's cached balances and portfolio
+type HotCacheEvictor interface {
+	Forget(ctx context.Context, userID uuid.UUID)
+}
+
+// Consumers wires domain event topics to the services that react to them
+type Consumers struct {
+	webhooks      WebhookPublisher
+	progress      ProgressPublisher
+	notifications NotificationDispatcher
+	balances      BalanceRefresher
+	promotions    PromotionGranter
+	hotCache      HotCacheEvictor
+	logger        *zap.Logger
+}
+
+// NewConsumers creates the fan-out consumers. Any dependency may be nil, in
+// which case its consumers are not registered.
+func NewConsumers(webhooks WebhookPublisher, progress ProgressPublisher, notifications NotificationDispatcher, logger *zap.Logger) *Consumers {
+	return &Consumers{
+		webhooks:      webhooks,
+		progress:      progress,
+		notifications: notifications,
+		logger:        logger,
+	}
+}
+
+// SetBalanceRefresher refreshes cached wallet balances when a deposit is
+// credited or reversed
+func (c *Consumers) SetBalanceRefresher(balances BalanceRefresher) {
+	c.balances = balances
+}
+
+// SetPromotionGranter grants first deposit promotions when a deposit is credited
+func (c *Consumers) SetPromotionGranter(promotions PromotionGranter) {
+	c.promotions = promotions
+}
+
+// SetHotCache drops cached balance and portfolio responses when a deposit is
+// credited or reversed
+func (c *Consumers) SetHotCache(hotCache HotCacheEvictor) {
+	c.hotCache = hotCache
+}
+
+// Register subscribes every consumer to the bus
+func (c *Consumers) Register(bus eventbus.Bus) error {
+	subscriptions := []struct {
+		enabled bool
+		topic   string
+		group   string
+		handler eventbus.Handler
+	}{
+		{c.webhooks != nil, entities.TopicDepositConfirmed, GroupOutboundWebhooks, c.depositWebhook},
+		{c.progress != nil, entities.TopicDepositConfirmed, GroupEventStream, c.depositProgress},
+		{c.notifications != nil, entities.TopicDepositConfirmed, GroupDepositNotifications, c.depositNotification},
+		{c.notifications != nil, entities.TopicNotificationRequested, GroupNotificationDispatch, c.dispatchNotification},
+		{c.balances != nil, entities.TopicDepositConfirmed, GroupBalanceCache, c.refreshBalances},
+		{c.promotions != nil, entities.TopicDepositConfirmed, GroupPromotions, c.grantDepositPromotions},
+		{c.hotCache != nil, entities.TopicDepositConfirmed, GroupHotCache, c.forgetCachedUser},
+		{c.progress != nil, entities.TopicDepositReversed, GroupEventStream, c.depositReversedProgress},
+		{c.balances != nil, entities.TopicDepositReversed, GroupBalanceCache, c.refreshBalances},
+		{c.hotCache != nil, entities.TopicDepositReversed, GroupHotCache, c.forgetCachedUser},
+		{c.webhooks != nil, entities.TopicOnboardingChanged, GroupOutboundWebhooks, c.onboardingWebhook},
+		{c.progress != nil, entities.TopicOnboardingChanged, GroupEventStream, c.onboardingProgress},
+	}
+
+	for _, sub := range subscriptions {
+		if !sub.enabled {
+			continue
+		}
+		if err := bus.Subscribe(sub.topic, sub.group, sub.handler); err != nil {
+			return fmt.Errorf("failed to subscribe %s to %s: %w", sub.group, sub.topic, err)
+		}
+	}
+	c.logger.Info("Event fan-out consumers registered")
+	return nil
+}
+
+func (c *Consumers) depositWebhook(ctx context.Context, msg *eventbus.Message) error {
+	var event entities.DepositConfirmedEvent
+	if err := msg.Decode(&event); err != nil {
+		return nil // undecodable messages cannot succeed on retry
+	}
+	// The bus message ID is stable across redeliveries, so reuse it as the
+	// webhook event ID and let the delivery table dedupe
+	eventID, err := uuid.Parse(msg.ID)
+	if err != nil {
+		eventID = uuid.NewSHA1(uuid.NameSpaceOID, []byte(msg.ID))
+	}
+	return c.webhooks.PublishEvent(ctx, eventID, entities.WebhookEventDepositConfirmed, event)
+}
+
+func (c *Consumers) depositProgress(ctx context.Context, msg *eventbus.Message) error {
+	var event entities.DepositConfirmedEvent
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	return c.progress.PublishToUser(ctx, event.UserID, entities.StreamEventDepositUpdated, entities.DepositProgressEvent{
+		DepositID: event.DepositID,
+		Chain:     event.Chain,
+		Token:     event.Token,
+		Amount:    event.Amount,
+		Status:    event.Status,
+		TxHash:    event.TxHash,
+	})
+}
+
+func (c *Consumers) depositNotification(ctx context.Context, msg *eventbus.Message) error {
+	var event entities.DepositConfirmedEvent
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	return c.notifications.Send(ctx, &entities.Notification{
+		ID:       uuid.NewSHA1(uuid.NameSpaceOID, []byte("deposit-notification:"+event.DepositID.String())),
+		UserID:   event.UserID,
+		Type:     entities.NotificationTypeDeposit,
+		Channel:  entities.ChannelInApp,
+		Priority: entities.PriorityMedium,
+		Title:    "Deposit received",
+		Message:  fmt.Sprintf("Your deposit of %s %s has been credited.", event.Amount, event.Token),
+		Data: map[string]interface{}{
+			"deposit_id": event.DepositID,
+			"tx_hash":    event.TxHash,
+		},
+		CreatedAt: time.Now(),
+	}, nil)
+}
+
+func (c *Consumers) depositReversedProgress(ctx context.Context, msg *eventbus.Message) error {
+	var event entities.DepositReversedEvent
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	return c.progress.PublishToUser(ctx, event.UserID, entities.StreamEventDepositUpdated, entities.DepositProgressEvent{
+		DepositID: event.DepositID,
+		Chain:     event.Chain,
+		Token:     event.Token,
+		Amount:    event.Amount,
+		Status:    event.Status,
+		TxHash:    event.TxHash,
+	})
+}
+
+// onboardingWebhook forwards onboarding changes to subscribed partners. The
+// outbox entry ID is the webhook event ID, so a change the relay published
+// twice still produces one delivery per endpoint.
+func (c *Consumers) onboardingWebhook(ctx context.Context, msg *eventbus.Message) error {
+	var event entities.OnboardingChangedEvent
+	if err := msg.Decode(&event); err != nil || event.EventID == uuid.Nil {
+		return nil
+	}
+	return c.webhooks.PublishEvent(ctx, event.EventID, entities.WebhookEventOnboardingChange, event)
+}
+
+// onboardingProgress pushes onboarding changes to the user's live stream,
+// where the app and the BFF pick them up instead of polling
+func (c *Consumers) onboardingProgress(ctx context.Context, msg *eventbus.Message) error {
+	var event entities.OnboardingChangedEvent
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	return c.progress.PublishToUser(ctx, event.UserID, entities.StreamEventOnboarding, event)
+}
+
+// refreshBalances handles both confirmed and reversed deposits; only the user
+// ID, common to both payloads, is needed
+func (c *Consumers) refreshBalances(ctx context.Context, msg *eventbus.Message) error {
+	var event struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	_, err := c.balances.RefreshUser(ctx, event.UserID)
+	return err
+}
+
+func (c *Consumers) forgetCachedUser(ctx context.Context, msg *eventbus.Message) error {
+	var event struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	c.hotCache.Forget(ctx, event.UserID)
+	return nil
+}
+
+func (c *Consumers) grantDepositPromotions(ctx context.Context, msg *eventbus.Message) error {
+	var event struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := msg.Decode(&event); err != nil {
+		return nil
+	}
+	return c.promotions.HandleDepositCredited(ctx, event.UserID)
+}
+
+func (c *Consumers) dispatchNotification(ctx context.Context, msg *eventbus.Message) error {
+	var req entities.NotificationRequest
+	if err := msg.Decode(&req); err != nil || req.Notification == nil {
+		c.logger.Warn("Dropping malformed notification request", zap.String("message_id", msg.ID))
+		return nil
+	}
+	return c.notifications.Deliver(ctx, req.Notification, req.Preferences)
+}
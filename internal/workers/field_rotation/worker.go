@@ -0,0 +1,53 @@
+package field_rotation
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
+)
+
+// Rotator re-encrypts the PII columns owned by a repository
+type Rotator interface {
+	RotateFieldEncryption(ctx context.Context, batchSize int) (repositories.FieldRotationResult, error)
+}
+
+// Worker re-encrypts PII columns under the active field key version. It is run
+// after a new key version is activated, and before the retired key is removed
+// from the secrets manager.
+type Worker struct {
+	rotators  []Rotator
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewWorker creates a new field key rotation worker
+func NewWorker(batchSize int, logger *zap.Logger, rotators ...Rotator) *Worker {
+	return &Worker{
+		rotators:  rotators,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Run rotates every registered repository and returns per-table results.
+// Rows that could not be decrypted are reported as failed rather than aborting the run.
+func (w *Worker) Run(ctx context.Context) ([]repositories.FieldRotationResult, error) {
+	results := make([]repositories.FieldRotationResult, 0, len(w.rotators))
+	for _, rotator := range w.rotators {
+		result, err := rotator.RotateFieldEncryption(ctx, w.batchSize)
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("field rotation failed for %s: %w", result.Table, err)
+		}
+
+		w.logger.Info("Field encryption rotation completed",
+			zap.String("table", result.Table),
+			zap.Int("scanned", result.Scanned),
+			zap.Int("rotated", result.Rotated),
+			zap.Int("failed", result.Failed))
+	}
+	return results, nil
+}
@@ -146,7 +146,7 @@ func (o *AlpacaFundingOrchestrator) ProcessOffRampCompletion(ctx context.Context
 		o.logger.Error("Failed to update buying power",
 			"error", err,
 			"user_id", deposit.UserID.String(),
-			"amount", deposit.Amount.String())
+			"amount", logger.RedactAmount(deposit.Amount))
 		return fmt.Errorf("failed to update buying power: %w", err)
 	}
 
@@ -161,7 +161,7 @@ func (o *AlpacaFundingOrchestrator) ProcessOffRampCompletion(ctx context.Context
 	o.logger.Info("Alpaca funding completed successfully",
 		"deposit_id", depositID.String(),
 		"user_id", deposit.UserID.String(),
-		"amount", deposit.Amount.String())
+		"amount", logger.RedactAmount(deposit.Amount))
 
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // Manager coordinates webhook processor and reconciliation worker
@@ -66,6 +67,13 @@ func NewManager(
 	}, nil
 }
 
+// SetTrackers reports processor batches and reconciliation runs to the
+// worker registry. Either tracker may be nil. Must be called before Start.
+func (m *Manager) SetTrackers(processor, reconciler *workerstatus.Tracker) {
+	m.processor.tracker = processor
+	m.reconciler.tracker = reconciler
+}
+
 // Start starts all workers
 func (m *Manager) Start(ctx context.Context) error {
 	if m.isRunning {
@@ -12,11 +12,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/stack-service/stack_service/internal/domain/entities"
 	"github.com/stack-service/stack_service/internal/domain/services/funding"
 	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/metrics"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // ProcessorConfig holds configuration for the webhook processor
@@ -26,6 +30,7 @@ type ProcessorConfig struct {
 	MaxAttempts             int
 	CircuitBreakerThreshold int
 	CircuitBreakerTimeout   time.Duration
+	LeaseDuration           time.Duration // How long a job may go without a heartbeat before the janitor requeues it
 }
 
 // DefaultProcessorConfig returns default configuration
@@ -36,6 +41,7 @@ func DefaultProcessorConfig() ProcessorConfig {
 		MaxAttempts:             5,
 		CircuitBreakerThreshold: 5,
 		CircuitBreakerTimeout:   60 * time.Second,
+		LeaseDuration:           2 * time.Minute,
 	}
 }
 
@@ -50,6 +56,9 @@ type Processor struct {
 	// Circuit breaker state
 	circuitBreaker *CircuitBreaker
 
+	// Reports batches to the worker registry; nil when not tracked
+	tracker *workerstatus.Tracker
+
 	// Metrics
 	meter             metric.Meter
 	processedCounter  metric.Int64Counter
@@ -71,6 +80,9 @@ func NewProcessor(
 	auditSvc *adapters.AuditService,
 	logger *logger.Logger,
 ) (*Processor, error) {
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = DefaultProcessorConfig().LeaseDuration
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 
 	meter := otel.Meter("funding-webhook-processor")
@@ -186,28 +198,33 @@ func (p *Processor) worker(ctx context.Context, workerID int) {
 			p.logger.Info("Worker stopping due to shutdown", "worker_id", workerID)
 			return
 		case <-ticker.C:
-			p.processBatch(ctx, workerID)
+			finish, ok := p.tracker.Begin()
+			if !ok {
+				continue
+			}
+			finish(p.processBatch(ctx, workerID))
 		}
 	}
 }
 
-// processBatch fetches and processes a batch of jobs
-func (p *Processor) processBatch(ctx context.Context, workerID int) {
+// processBatch fetches and processes a batch of jobs. Only a failure to fetch
+// is returned; failed jobs are retried through their own backoff.
+func (p *Processor) processBatch(ctx context.Context, workerID int) error {
 	// Check circuit breaker
 	if !p.circuitBreaker.CanProcess() {
 		p.logger.Warn("Circuit breaker open, skipping batch", "worker_id", workerID)
-		return
+		return nil
 	}
 
 	// Fetch pending jobs
 	jobs, err := p.jobRepo.GetNextPendingJobs(ctx, 10) // Process up to 10 jobs per batch
 	if err != nil {
 		p.logger.Error("Failed to fetch pending jobs", "error", err, "worker_id", workerID)
-		return
+		return err
 	}
 
 	if len(jobs) == 0 {
-		return // No jobs to process
+		return nil // No jobs to process
 	}
 
 	p.logger.Debug("Processing batch", "worker_id", workerID, "job_count", len(jobs))
@@ -216,13 +233,15 @@ func (p *Processor) processBatch(ctx context.Context, workerID int) {
 	for _, job := range jobs {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		case <-p.shutdownCtx.Done():
-			return
+			return nil
 		default:
 			p.processJob(ctx, job)
 		}
 	}
+
+	return nil
 }
 
 // processJob processes a single job with retry logic
@@ -236,8 +255,20 @@ func (p *Processor) processJob(ctx context.Context, job *entities.FundingEventJo
 		"attempt", job.AttemptCount+1,
 	)
 
+	// A replayed event for a transfer that is already credited is completed
+	// without touching the deposit. A failed check falls through, since
+	// ProcessChainDeposit is idempotent as well.
+	processed, err := p.fundingSvc.IsTransferProcessed(ctx, job.Chain, job.TxHash, job.LogIndex)
+	if err != nil {
+		p.logger.Warn("Failed to check for duplicate deposit", "error", err, "job_id", job.ID)
+	} else if processed {
+		p.completeDuplicate(ctx, job)
+		return
+	}
+
 	// Mark job as processing
 	job.MarkProcessing()
+	job.TakeLease(p.config.LeaseDuration)
 	if err := p.jobRepo.Update(ctx, job); err != nil {
 		p.logger.Error("Failed to mark job as processing", "error", err, "job_id", job.ID)
 		return
@@ -255,6 +286,7 @@ func (p *Processor) processJob(ctx context.Context, job *entities.FundingEventJo
 	webhook := &entities.ChainDepositWebhook{
 		Chain:     job.Chain,
 		TxHash:    job.TxHash,
+		LogIndex:  job.LogIndex,
 		Token:     job.Token,
 		Amount:    job.Amount.String(),
 		Address:   job.ToAddress,
@@ -262,7 +294,9 @@ func (p *Processor) processJob(ctx context.Context, job *entities.FundingEventJo
 	}
 
 	// Process the deposit
-	err := p.fundingSvc.ProcessChainDeposit(ctx, webhook)
+	stopHeartbeat := p.startHeartbeat(ctx, job.ID)
+	err = p.fundingSvc.ProcessChainDeposit(ctx, webhook)
+	stopHeartbeat()
 
 	duration := time.Since(startTime)
 
@@ -377,6 +411,67 @@ func (p *Processor) processJob(ctx context.Context, job *entities.FundingEventJo
 	}
 }
 
+// completeDuplicate closes a job whose transfer was already credited
+func (p *Processor) completeDuplicate(ctx context.Context, job *entities.FundingEventJob) {
+	job.AddProcessingLog(entities.ProcessingLogEntry{
+		Timestamp: time.Now(),
+		Attempt:   job.AttemptCount,
+		Status:    "deduplicated",
+		Metadata: map[string]interface{}{
+			"worker_id": "processor",
+		},
+	})
+	job.MarkCompleted()
+
+	p.logger.Info("Skipping duplicate deposit event",
+		"job_id", job.ID,
+		"tx_hash", job.TxHash,
+		"log_index", job.LogIndex,
+	)
+
+	metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceFundingWebhook).Inc()
+	p.processedCounter.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("status", "deduplicated"),
+			attribute.String("chain", string(job.Chain)),
+		),
+	)
+
+	if err := p.jobRepo.Update(ctx, job); err != nil {
+		p.logger.Error("Failed to update job", "error", err, "job_id", job.ID)
+	}
+}
+
+// startHeartbeat renews the job's lease at a third of its duration until the
+// returned stop function is called
+func (p *Processor) startHeartbeat(ctx context.Context, jobID uuid.UUID) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.config.LeaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.jobRepo.RenewLease(ctx, jobID, time.Now().Add(p.config.LeaseDuration)); err != nil {
+					p.logger.Warn("Failed to renew job lease", "error", err, "job_id", jobID)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
 // categorizeError determines if an error is transient or permanent
 func (p *Processor) categorizeError(err error) entities.FundingEventErrorType {
 	if err == nil {
@@ -14,6 +14,7 @@ import (
 	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
 	"github.com/stack-service/stack_service/internal/infrastructure/repositories"
 	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // ReconciliationConfig holds configuration for reconciliation worker
@@ -45,6 +46,7 @@ type Reconciler struct {
 	validator   *ChainValidator
 	auditSvc    *adapters.AuditService
 	logger      *logger.Logger
+	tracker     *workerstatus.Tracker
 
 	// Metrics
 	meter             metric.Meter
@@ -175,7 +177,7 @@ func (r *Reconciler) reconciliationLoop(ctx context.Context) {
 	defer r.wg.Done()
 
 	// Run immediately on start
-	r.runReconciliation(ctx)
+	r.trackedRun(ctx)
 
 	ticker := time.NewTicker(r.config.Interval)
 	defer ticker.Stop()
@@ -189,13 +191,23 @@ func (r *Reconciler) reconciliationLoop(ctx context.Context) {
 			r.logger.Info("Reconciliation worker stopping due to shutdown")
 			return
 		case <-ticker.C:
-			r.runReconciliation(ctx)
+			r.trackedRun(ctx)
 		}
 	}
 }
 
+// trackedRun performs a reconciliation pass unless the worker is paused
+func (r *Reconciler) trackedRun(ctx context.Context) {
+	finish, ok := r.tracker.Begin()
+	if !ok {
+		r.logger.Debug("Reconciliation worker paused, skipping run")
+		return
+	}
+	finish(r.runReconciliation(ctx))
+}
+
 // runReconciliation performs a reconciliation pass
-func (r *Reconciler) runReconciliation(ctx context.Context) {
+func (r *Reconciler) runReconciliation(ctx context.Context) error {
 	startTime := time.Now()
 
 	r.logger.Info("Starting reconciliation run", "threshold", r.config.Threshold)
@@ -221,14 +233,14 @@ func (r *Reconciler) runReconciliation(ctx context.Context) {
 	if err != nil {
 		r.logger.Error("Failed to get reconciliation candidates", "error", err)
 		r.failedCounter.Add(ctx, 1)
-		return
+		return fmt.Errorf("failed to get reconciliation candidates: %w", err)
 	}
 
 	if len(candidates) == 0 {
 		r.logger.Debug("No deposits to reconcile")
 		duration := time.Since(startTime)
 		r.durationHistogram.Record(ctx, duration.Seconds())
-		return
+		return nil
 	}
 
 	r.logger.Info("Found deposits to reconcile", "count", len(candidates))
@@ -289,6 +301,8 @@ func (r *Reconciler) runReconciliation(ctx context.Context) {
 		"recovered":        recoveredCount,
 		"failed":           failedCount,
 	}, nil)
+
+	return nil
 }
 
 // reconcileDeposit attempts to reconcile a single deposit
@@ -322,6 +336,7 @@ func (r *Reconciler) reconcileDeposit(ctx context.Context, candidate *entities.R
 		webhook := &entities.ChainDepositWebhook{
 			Chain:     candidate.Chain,
 			TxHash:    candidate.TxHash,
+			LogIndex:  candidate.LogIndex,
 			Token:     candidate.Token,
 			Amount:    candidate.Amount.String(),
 			Address:   candidate.ToAddress,
@@ -0,0 +1,65 @@
+package inactivity_monitor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/services/inactivity"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Worker periodically runs the dormant account scan
+type Worker struct {
+	service  *inactivity.Service
+	interval time.Duration
+	logger   *zap.Logger
+	tracker  *workerstatus.Tracker
+}
+
+// NewWorker creates a new inactivity monitor worker
+func NewWorker(service *inactivity.Service, logger *zap.Logger) *Worker {
+	return &Worker{
+		service:  service,
+		interval: service.Interval(),
+		logger:   logger,
+	}
+}
+
+// SetTracker reports scans to the worker registry, which can pause them
+func (w *Worker) SetTracker(tracker *workerstatus.Tracker) {
+	w.tracker = tracker
+}
+
+// Start runs a scan on every tick until ctx is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("Inactivity monitor stopped")
+				return
+			case <-ticker.C:
+				finish, ok := w.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := w.service.Run(ctx, false)
+				if errors.Is(err, inactivity.ErrScanInProgress) {
+					finish(nil)
+					w.logger.Debug("Skipping inactivity scan, previous scan still running")
+					continue
+				}
+				finish(err)
+				if err != nil {
+					w.logger.Error("Inactivity scan failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,247 @@
+package job_janitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Queue names used in metrics and logs
+const (
+	QueueWalletProvisioning = "wallet_provisioning"
+	QueueFundingEvents      = "funding_events"
+)
+
+// errLeaseExpired is recorded on jobs whose worker stopped renewing the lease
+var errLeaseExpired = errors.New("worker stopped renewing the job lease")
+
+// WalletJobStore finds and releases wallet provisioning jobs with expired leases
+type WalletJobStore interface {
+	CountExpiredLeases(ctx context.Context) (int64, error)
+	GetExpiredLeases(ctx context.Context, limit int) ([]*entities.WalletProvisioningJob, error)
+	ReleaseExpiredLease(ctx context.Context, job *entities.WalletProvisioningJob) (bool, error)
+}
+
+// FundingJobStore finds and releases funding event jobs with expired leases
+type FundingJobStore interface {
+	CountExpiredLeases(ctx context.Context) (int64, error)
+	GetExpiredLeases(ctx context.Context, limit int) ([]*entities.FundingEventJob, error)
+	ReleaseExpiredLease(ctx context.Context, job *entities.FundingEventJob) (bool, error)
+}
+
+// Config holds janitor configuration
+type Config struct {
+	Interval       time.Duration
+	BatchSize      int           // Jobs released per queue per sweep
+	RetryDelay     time.Duration // Wait before a requeued wallet job is picked up again
+	AlertThreshold int64         // Stuck jobs in one queue that raise an alert
+}
+
+// DefaultConfig returns default janitor configuration
+func DefaultConfig() Config {
+	return Config{
+		Interval:       time.Minute,
+		BatchSize:      100,
+		RetryDelay:     time.Minute,
+		AlertThreshold: 10,
+	}
+}
+
+// QueueResult summarises one sweep of a queue
+type QueueResult struct {
+	Queue        string
+	Stuck        int64
+	Requeued     int
+	DeadLettered int
+}
+
+// Worker requeues jobs whose worker crashed or hung mid-processing. Such jobs
+// keep their in-progress status but stop renewing their lease; once it runs
+// out they go back on the queue, or to the dead letter state when they have
+// no attempts left.
+type Worker struct {
+	wallet  WalletJobStore
+	funding FundingJobStore
+	config  Config
+	logger  *zap.Logger
+	tracker *workerstatus.Tracker
+}
+
+// NewWorker creates a new job janitor
+func NewWorker(wallet WalletJobStore, funding FundingJobStore, config Config, logger *zap.Logger) *Worker {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.AlertThreshold <= 0 {
+		config.AlertThreshold = defaults.AlertThreshold
+	}
+
+	return &Worker{
+		wallet:  wallet,
+		funding: funding,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// SetTracker reports sweeps to the worker registry, which can pause them
+func (w *Worker) SetTracker(tracker *workerstatus.Tracker) {
+	w.tracker = tracker
+}
+
+// Interval returns how often the janitor sweeps
+func (w *Worker) Interval() time.Duration {
+	return w.config.Interval
+}
+
+// CountStuck returns how many jobs across all queues have an expired lease
+func (w *Worker) CountStuck(ctx context.Context) (int64, error) {
+	walletStuck, err := w.wallet.CountExpiredLeases(ctx)
+	if err != nil {
+		return 0, err
+	}
+	fundingStuck, err := w.funding.CountExpiredLeases(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return walletStuck + fundingStuck, nil
+}
+
+// Start sweeps on every tick until ctx is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("Job janitor stopped")
+				return
+			case <-ticker.C:
+				finish, ok := w.tracker.Begin()
+				if !ok {
+					continue
+				}
+				_, err := w.Sweep(ctx)
+				finish(err)
+				if err != nil {
+					w.logger.Error("Job janitor sweep failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Sweep releases up to a batch of expired jobs from each queue. A failure in
+// one queue does not stop the other from being swept.
+func (w *Worker) Sweep(ctx context.Context) ([]QueueResult, error) {
+	wallet, walletErr := w.sweepWalletJobs(ctx)
+	funding, fundingErr := w.sweepFundingJobs(ctx)
+	return []QueueResult{wallet, funding}, errors.Join(walletErr, fundingErr)
+}
+
+func (w *Worker) sweepWalletJobs(ctx context.Context) (QueueResult, error) {
+	result := QueueResult{Queue: QueueWalletProvisioning}
+
+	stuck, err := w.wallet.CountExpiredLeases(ctx)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", result.Queue, err)
+	}
+	result.Stuck = stuck
+	w.report(result.Queue, stuck)
+	if stuck == 0 {
+		return result, nil
+	}
+
+	jobs, err := w.wallet.GetExpiredLeases(ctx, w.config.BatchSize)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", result.Queue, err)
+	}
+	for _, job := range jobs {
+		job.MarkFailed(errLeaseExpired.Error(), w.config.RetryDelay)
+		released, err := w.wallet.ReleaseExpiredLease(ctx, job)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", result.Queue, err)
+		}
+		if !released {
+			continue
+		}
+		w.recordRelease(&result, job.ID.String(), job.Status == entities.ProvisioningStatusFailed, job.AttemptCount)
+	}
+	return result, nil
+}
+
+func (w *Worker) sweepFundingJobs(ctx context.Context) (QueueResult, error) {
+	result := QueueResult{Queue: QueueFundingEvents}
+
+	stuck, err := w.funding.CountExpiredLeases(ctx)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", result.Queue, err)
+	}
+	result.Stuck = stuck
+	w.report(result.Queue, stuck)
+	if stuck == 0 {
+		return result, nil
+	}
+
+	jobs, err := w.funding.GetExpiredLeases(ctx, w.config.BatchSize)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", result.Queue, err)
+	}
+	for _, job := range jobs {
+		job.MarkFailed(errLeaseExpired, entities.ErrorTypeTransient, job.GetRetryDelay())
+		released, err := w.funding.ReleaseExpiredLease(ctx, job)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", result.Queue, err)
+		}
+		if !released {
+			continue
+		}
+		w.recordRelease(&result, job.ID.String(), job.Status == entities.JobStatusDLQ, job.AttemptCount)
+	}
+	return result, nil
+}
+
+// report publishes a queue's stuck-job count and alerts when it crosses the
+// threshold
+func (w *Worker) report(queue string, stuck int64) {
+	metrics.StaleJobs.WithLabelValues(queue).Set(float64(stuck))
+	if stuck >= w.config.AlertThreshold {
+		w.logger.Error("Stuck jobs above alert threshold",
+			zap.String("queue", queue),
+			zap.Int64("stuck", stuck),
+			zap.Int64("threshold", w.config.AlertThreshold))
+	}
+}
+
+func (w *Worker) recordRelease(result *QueueResult, jobID string, deadLettered bool, attempts int) {
+	outcome := metrics.StaleJobRequeued
+	if deadLettered {
+		outcome = metrics.StaleJobDeadLettered
+		result.DeadLettered++
+	} else {
+		result.Requeued++
+	}
+	metrics.StaleJobsReleased.WithLabelValues(result.Queue, outcome).Inc()
+
+	w.logger.Warn("Released job with expired lease",
+		zap.String("queue", result.Queue),
+		zap.String("job_id", jobID),
+		zap.String("outcome", outcome),
+		zap.Int("attempts", attempts))
+}
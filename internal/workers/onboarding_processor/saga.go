@@ -0,0 +1,162 @@
+package onboardingprocessor
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+// Saga step names recorded on onboarding jobs
+const (
+	StepKYC     = "kyc"
+	StepWallets = "wallets"
+)
+
+// Keys in a step's Data used to undo its status transition
+const (
+	dataPreviousStatus = "previous_status"
+	dataSetStatus      = "set_status"
+)
+
+// sagaStep is one unit of onboarding work. Run must be safe to repeat, since
+// a step that failed is run again on the next attempt. Compensate undoes a
+// completed step when the job fails for good; nil means there is nothing to
+// undo.
+type sagaStep struct {
+	Name       string
+	Run        func(ctx context.Context, job *entities.OnboardingJob, state *entities.OnboardingJobStep) error
+	Compensate func(ctx context.Context, job *entities.OnboardingJob, state *entities.OnboardingJobStep) error
+}
+
+// stepsFor returns the saga for a job type. The welcome email is not a step:
+// it goes out when wallet provisioning completes.
+func (w *Worker) stepsFor(jobType entities.OnboardingJobType) ([]sagaStep, error) {
+	kyc := sagaStep{Name: StepKYC, Run: w.startKYC, Compensate: w.restoreOnboardingStatus}
+	wallets := sagaStep{Name: StepWallets, Run: w.provisionWallets, Compensate: w.restoreOnboardingStatus}
+
+	switch jobType {
+	case entities.OnboardingJobTypeFullOnboarding:
+		return []sagaStep{kyc, wallets}, nil
+	case entities.OnboardingJobTypeKYCOnly:
+		return []sagaStep{kyc}, nil
+	case entities.OnboardingJobTypeWalletOnly:
+		return []sagaStep{wallets}, nil
+	default:
+		return nil, fmt.Errorf("unknown job type: %s", jobType)
+	}
+}
+
+// startKYC moves the user to KYC pending unless a review is already under way
+func (w *Worker) startKYC(ctx context.Context, job *entities.OnboardingJob, state *entities.OnboardingJobStep) error {
+	user, err := w.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.KYCStatus == string(entities.KYCStatusApproved) || user.KYCStatus == string(entities.KYCStatusProcessing) {
+		w.logger.Info("KYC already under way",
+			zap.String("user_id", user.ID.String()),
+			zap.String("kyc_status", user.KYCStatus))
+		return nil
+	}
+
+	return w.transitionStatus(ctx, user, state, entities.OnboardingStatusKYCPending)
+}
+
+// provisionWallets moves the user to wallets pending and enqueues wallet
+// provisioning for the chains in the job payload. Provisioned wallets are
+// not removed by compensation; only the status transition is undone.
+func (w *Worker) provisionWallets(ctx context.Context, job *entities.OnboardingJob, state *entities.OnboardingJobStep) error {
+	user, err := w.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.OnboardingStatus != entities.OnboardingStatusCompleted {
+		if err := w.transitionStatus(ctx, user, state, entities.OnboardingStatusWalletsPending); err != nil {
+			return err
+		}
+	}
+
+	// Check if wallets already exist
+	walletStatus, err := w.walletService.GetWalletStatus(ctx, user.ID)
+	if err == nil && walletStatus != nil && len(walletStatus.WalletsByChain) > 0 {
+		w.logger.Info("Wallets already exist",
+			zap.String("user_id", user.ID.String()),
+			zap.Int("wallet_count", len(walletStatus.WalletsByChain)))
+		return nil
+	}
+
+	chains := payloadChains(job)
+	if err := w.walletService.CreateWalletsForUser(ctx, user.ID, chains); err != nil {
+		return fmt.Errorf("failed to create wallets: %w", err)
+	}
+
+	w.logger.Info("Wallet provisioning enqueued",
+		zap.String("user_id", user.ID.String()),
+		zap.Int("chain_count", len(chains)))
+	return nil
+}
+
+// transitionStatus sets the user's onboarding status, recording the status
+// it replaced the first time so a retried step still undoes to the original
+func (w *Worker) transitionStatus(ctx context.Context, user *entities.UserProfile, state *entities.OnboardingJobStep, status entities.OnboardingStatus) error {
+	if user.OnboardingStatus == status {
+		return nil
+	}
+	if _, ok := state.Data[dataPreviousStatus]; !ok {
+		state.Data[dataPreviousStatus] = string(user.OnboardingStatus)
+	}
+	if err := w.userRepo.UpdateOnboardingStatus(ctx, user.ID, status); err != nil {
+		return fmt.Errorf("failed to update onboarding status: %w", err)
+	}
+	state.Data[dataSetStatus] = string(status)
+	return nil
+}
+
+// restoreOnboardingStatus puts back the status a step replaced. It leaves
+// the user alone when something else has moved them on since, such as a KYC
+// decision arriving.
+func (w *Worker) restoreOnboardingStatus(ctx context.Context, job *entities.OnboardingJob, state *entities.OnboardingJobStep) error {
+	previous, ok := state.Data[dataPreviousStatus]
+	set := state.Data[dataSetStatus]
+	if !ok || set == "" {
+		return nil
+	}
+
+	user, err := w.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if string(user.OnboardingStatus) != set {
+		w.logger.Info("Onboarding status moved on, not restoring",
+			zap.String("user_id", job.UserID.String()),
+			zap.String("step", state.Name),
+			zap.String("status", string(user.OnboardingStatus)))
+		return nil
+	}
+
+	if err := w.userRepo.UpdateOnboardingStatus(ctx, job.UserID, entities.OnboardingStatus(previous)); err != nil {
+		return fmt.Errorf("failed to restore onboarding status: %w", err)
+	}
+	return nil
+}
+
+// payloadChains reads wallet_chains from the job payload, defaulting to
+// SOL-DEVNET
+func payloadChains(job *entities.OnboardingJob) []entities.WalletChain {
+	var chains []entities.WalletChain
+	if walletChains, ok := job.Payload["wallet_chains"].([]interface{}); ok {
+		for _, chainStr := range walletChains {
+			if chain, ok := chainStr.(string); ok {
+				chains = append(chains, entities.WalletChain(chain))
+			}
+		}
+	}
+	if len(chains) == 0 {
+		chains = []entities.WalletChain{entities.ChainSOLDevnet}
+	}
+	return chains
+}
@@ -11,29 +11,25 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // Dependencies interfaces for the worker
 type OnboardingJobRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.OnboardingJob, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.OnboardingJob, error)
 	GetPendingJobs(ctx context.Context, limit int) ([]*entities.OnboardingJob, error)
+	Claim(ctx context.Context, job *entities.OnboardingJob) (bool, error)
 	Update(ctx context.Context, job *entities.OnboardingJob) error
 }
 
-type OnboardingService interface {
-	StartOnboarding(ctx context.Context, userID uuid.UUID, userEmail, userPhone string) error
-	GetOnboardingStatus(ctx context.Context, userID uuid.UUID) (*entities.OnboardingStatusResponse, error)
-}
-
 type WalletService interface {
 	CreateWalletsForUser(ctx context.Context, userID uuid.UUID, chains []entities.WalletChain) error
 	GetWalletStatus(ctx context.Context, userID uuid.UUID) (*entities.WalletStatusResponse, error)
 }
 
 type UserRepository interface {
-	GetUserEntityByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
-	Update(ctx context.Context, user *entities.UserProfile) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error)
+	UpdateOnboardingStatus(ctx context.Context, userID uuid.UUID, status entities.OnboardingStatus) error
 }
 
 // Metrics tracks worker performance metrics
@@ -72,12 +68,12 @@ func DefaultConfig() Config {
 // Worker handles onboarding jobs with retries and audit logging
 type Worker struct {
 	jobRepo           OnboardingJobRepository
-	onboardingService OnboardingService
 	walletService     WalletService
 	userRepo          UserRepository
 	config            Config
 	logger            *zap.Logger
 	metrics           *Metrics
+	tracker           *workerstatus.Tracker
 	stopChan          chan struct{}
 	jobProcessingChan chan uuid.UUID
 }
@@ -85,19 +81,17 @@ type Worker struct {
 // NewWorker creates a new onboarding worker
 func NewWorker(
 	jobRepo OnboardingJobRepository,
-	onboardingService OnboardingService,
 	walletService WalletService,
 	userRepo UserRepository,
 	config Config,
 	logger *zap.Logger,
 ) *Worker {
 	return &Worker{
-		jobRepo:           jobRepo,
-		onboardingService: onboardingService,
-		walletService:     walletService,
-		userRepo:          userRepo,
-		config:            config,
-		logger:            logger,
+		jobRepo:       jobRepo,
+		walletService: walletService,
+		userRepo:      userRepo,
+		config:        config,
+		logger:        logger,
 		metrics: &Metrics{
 			ErrorsByType: make(map[string]int64),
 		},
@@ -106,6 +100,11 @@ func NewWorker(
 	}
 }
 
+// SetTracker reports polls to the worker registry, which can pause them
+func (w *Worker) SetTracker(tracker *workerstatus.Tracker) {
+	w.tracker = tracker
+}
+
 // Start starts the worker scheduler
 func (w *Worker) Start(ctx context.Context) {
 	w.logger.Info("Starting onboarding worker",
@@ -151,10 +150,14 @@ func (w *Worker) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 		return nil
 	}
 
-	// Mark job as started
-	job.MarkStarted()
-	if err := w.jobRepo.Update(ctx, job); err != nil {
-		return fmt.Errorf("failed to update job status: %w", err)
+	// Claim the job so no other worker runs the same saga
+	claimed, err := w.jobRepo.Claim(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to claim job: %w", err)
+	}
+	if !claimed {
+		w.logger.Debug("Job claimed by another worker", zap.String("job_id", jobID.String()))
+		return nil
 	}
 
 	// Process the job
@@ -185,151 +188,91 @@ func (w *Worker) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 	return err
 }
 
-// processJobInternal performs the actual onboarding logic
+// processJobInternal runs the job's saga steps in order, skipping steps a
+// previous attempt completed. Each step's outcome is saved as it finishes.
 func (w *Worker) processJobInternal(ctx context.Context, job *entities.OnboardingJob) error {
 	w.logger.Info("Starting onboarding processing for user",
 		zap.String("user_id", job.UserID.String()),
 		zap.String("job_type", string(job.JobType)))
 
-	// Get user details
-	user, err := w.userRepo.GetUserEntityByID(ctx, job.UserID)
+	steps, err := w.stepsFor(job.JobType)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	// Process based on job type
-	switch job.JobType {
-	case entities.OnboardingJobTypeFullOnboarding:
-		return w.processFullOnboarding(ctx, job, user)
-	case entities.OnboardingJobTypeKYCOnly:
-		return w.processKYCOnly(ctx, job, user)
-	case entities.OnboardingJobTypeWalletOnly:
-		return w.processWalletOnly(ctx, job, user)
-	default:
-		return fmt.Errorf("unknown job type: %s", job.JobType)
-	}
-}
-
-// processFullOnboarding processes full onboarding (KYC + wallet)
-func (w *Worker) processFullOnboarding(ctx context.Context, job *entities.OnboardingJob, user *entities.User) error {
-	w.logger.Info("Processing full onboarding",
-		zap.String("user_id", user.ID.String()))
-
-	// Step 1: Start KYC process
-	if err := w.startKYCProcess(ctx, job, user); err != nil {
-		return fmt.Errorf("failed to start KYC process: %w", err)
+		return err
 	}
-
-	// Step 2: Create wallets
-	if err := w.createWallets(ctx, job, user); err != nil {
-		return fmt.Errorf("failed to create wallets: %w", err)
+	// Add every step up front so the job shows the whole plan
+	for _, step := range steps {
+		job.Step(step.Name)
 	}
 
-	w.logger.Info("Full onboarding completed successfully",
-		zap.String("user_id", user.ID.String()))
-
-	return nil
-}
-
-// processKYCOnly processes KYC-only onboarding
-func (w *Worker) processKYCOnly(ctx context.Context, job *entities.OnboardingJob, user *entities.User) error {
-	w.logger.Info("Processing KYC-only onboarding",
-		zap.String("user_id", user.ID.String()))
-
-	return w.startKYCProcess(ctx, job, user)
-}
-
-// processWalletOnly processes wallet-only onboarding
-func (w *Worker) processWalletOnly(ctx context.Context, job *entities.OnboardingJob, user *entities.User) error {
-	w.logger.Info("Processing wallet-only onboarding",
-		zap.String("user_id", user.ID.String()))
-
-	return w.createWallets(ctx, job, user)
-}
-
-// startKYCProcess starts the KYC verification process
-func (w *Worker) startKYCProcess(ctx context.Context, job *entities.OnboardingJob, user *entities.User) error {
-	w.logger.Info("Starting KYC process",
-		zap.String("user_id", user.ID.String()))
-
-	// Check if KYC is already in progress or completed
-	status, err := w.onboardingService.GetOnboardingStatus(ctx, user.ID)
-	if err == nil && status != nil {
-		if status.KYCStatus == string(entities.KYCStatusApproved) {
-			w.logger.Info("KYC already approved",
-				zap.String("user_id", user.ID.String()))
-			return nil
+	for _, step := range steps {
+		state := job.Step(step.Name)
+		if state.Status == entities.OnboardingStepStatusCompleted {
+			continue
 		}
-		if status.KYCStatus == string(entities.KYCStatusProcessing) {
-			w.logger.Info("KYC already in progress",
-				zap.String("user_id", user.ID.String()))
-			return nil
+
+		now := time.Now()
+		state.Attempts++
+		state.StartedAt = &now
+		if state.Data == nil {
+			state.Data = map[string]string{}
 		}
-	}
 
-	// Start KYC process
-	userPhone := ""
-	if user.Phone != nil {
-		userPhone = *user.Phone
-	}
+		if err := step.Run(ctx, job, state); err != nil {
+			msg := err.Error()
+			state.Status = entities.OnboardingStepStatusFailed
+			state.Error = &msg
+			return fmt.Errorf("%s step failed: %w", step.Name, err)
+		}
 
-	if err := w.onboardingService.StartOnboarding(ctx, user.ID, user.Email, userPhone); err != nil {
-		w.logger.Error("Failed to start KYC process",
-			zap.String("user_id", user.ID.String()),
-			zap.Error(err))
-		return fmt.Errorf("failed to start KYC process: %w", err)
+		completedAt := time.Now()
+		state.Status = entities.OnboardingStepStatusCompleted
+		state.Error = nil
+		state.CompletedAt = &completedAt
+		state.CompensatedAt = nil
+		if err := w.jobRepo.Update(ctx, job); err != nil {
+			w.logger.Warn("Failed to save onboarding step",
+				zap.String("job_id", job.ID.String()),
+				zap.String("step", step.Name),
+				zap.Error(err))
+		}
 	}
 
-	w.logger.Info("KYC process started successfully",
-		zap.String("user_id", user.ID.String()))
-
 	return nil
 }
 
-// createWallets creates wallets for the user
-func (w *Worker) createWallets(ctx context.Context, job *entities.OnboardingJob, user *entities.User) error {
-	w.logger.Info("Creating wallets for user",
-		zap.String("user_id", user.ID.String()))
-
-	// Check if wallets already exist
-	walletStatus, err := w.walletService.GetWalletStatus(ctx, user.ID)
-	if err == nil && walletStatus != nil && len(walletStatus.WalletsByChain) > 0 {
-		w.logger.Info("Wallets already exist",
-			zap.String("user_id", user.ID.String()),
-			zap.Int("wallet_count", len(walletStatus.WalletsByChain)))
-		return nil
+// compensate undoes the job's steps in reverse order, including the step
+// that failed, which may have got part way. A step that cannot be undone
+// keeps its status and is logged for an operator.
+func (w *Worker) compensate(ctx context.Context, job *entities.OnboardingJob) {
+	steps, err := w.stepsFor(job.JobType)
+	if err != nil {
+		return
 	}
 
-	// Extract wallet chains from job payload
-	var chains []entities.WalletChain
-	if walletChains, ok := job.Payload["wallet_chains"].([]interface{}); ok {
-		for _, chainStr := range walletChains {
-			if chain, ok := chainStr.(string); ok {
-				chains = append(chains, entities.WalletChain(chain))
-			}
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		state := job.Step(step.Name)
+		done := state.Status == entities.OnboardingStepStatusCompleted || state.Status == entities.OnboardingStepStatusFailed
+		if !done || step.Compensate == nil {
+			continue
 		}
-	}
 
-	// Default to SOL-DEVNET if not specified
-	if len(chains) == 0 {
-		chains = []entities.WalletChain{
-			entities.ChainSOLDevnet,
+		if err := step.Compensate(ctx, job, state); err != nil {
+			w.logger.Error("Failed to compensate onboarding step",
+				zap.String("job_id", job.ID.String()),
+				zap.String("user_id", job.UserID.String()),
+				zap.String("step", step.Name),
+				zap.Error(err))
+			continue
 		}
-	}
 
-	// Create wallets
-	if err := w.walletService.CreateWalletsForUser(ctx, user.ID, chains); err != nil {
-		w.logger.Error("Failed to create wallets",
-			zap.String("user_id", user.ID.String()),
-			zap.Error(err))
-		return fmt.Errorf("failed to create wallets: %w", err)
+		now := time.Now()
+		state.Status = entities.OnboardingStepStatusCompensated
+		state.CompensatedAt = &now
+		w.logger.Info("Compensated onboarding step",
+			zap.String("job_id", job.ID.String()),
+			zap.String("step", step.Name))
 	}
-
-	w.logger.Info("Wallets created successfully",
-		zap.String("user_id", user.ID.String()),
-		zap.Int("chain_count", len(chains)))
-
-	return nil
 }
 
 // handleJobSuccess marks the job as completed
@@ -363,9 +306,10 @@ func (w *Worker) handleJobFailure(ctx context.Context, job *entities.OnboardingJ
 			zap.Duration("retry_in", retryDelay),
 			zap.Error(err))
 	} else {
-		// No more retries or non-retryable error
+		// No more retries or non-retryable error: undo what the saga did
 		job.MarkFailed(errorMsg, 0)
 		job.Status = entities.OnboardingJobStatusFailed
+		w.compensate(ctx, job)
 
 		w.logger.Error("Job failed permanently",
 			zap.String("job_id", job.ID.String()),
@@ -390,21 +334,25 @@ func (w *Worker) scheduler(ctx context.Context) {
 			w.logger.Info("Scheduler stopped")
 			return
 		case <-ticker.C:
-			w.pollForJobs(ctx)
+			finish, ok := w.tracker.Begin()
+			if !ok {
+				continue
+			}
+			finish(w.pollForJobs(ctx))
 		}
 	}
 }
 
 // pollForJobs gets pending jobs and queues them for processing
-func (w *Worker) pollForJobs(ctx context.Context) {
+func (w *Worker) pollForJobs(ctx context.Context) error {
 	jobs, err := w.jobRepo.GetPendingJobs(ctx, w.config.MaxConcurrentJobs)
 	if err != nil {
 		w.logger.Error("Failed to get pending jobs", zap.Error(err))
-		return
+		return err
 	}
 
 	if len(jobs) == 0 {
-		return
+		return nil
 	}
 
 	w.logger.Debug("Found pending jobs", zap.Int("count", len(jobs)))
@@ -417,6 +365,7 @@ func (w *Worker) pollForJobs(ctx context.Context) {
 			w.logger.Warn("Job processing queue is full, skipping job", zap.String("job_id", job.ID.String()))
 		}
 	}
+	return nil
 }
 
 // jobProcessor processes jobs from the queue
@@ -0,0 +1,59 @@
+package ops_digest
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/services/opsdigest"
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+// Worker sends the daily ops digest once it is due
+type Worker struct {
+	service  *opsdigest.Service
+	interval time.Duration
+	logger   *zap.Logger
+	tracker  *workerstatus.Tracker
+}
+
+// NewWorker creates a new ops digest worker
+func NewWorker(service *opsdigest.Service, logger *zap.Logger) *Worker {
+	return &Worker{
+		service:  service,
+		interval: service.Interval(),
+		logger:   logger,
+	}
+}
+
+// SetTracker reports runs to the worker registry, which can pause them
+func (w *Worker) SetTracker(tracker *workerstatus.Tracker) {
+	w.tracker = tracker
+}
+
+// Start checks for a due digest on every tick until ctx is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				w.logger.Info("Ops digest worker stopped")
+				return
+			case now := <-ticker.C:
+				finish, ok := w.tracker.Begin()
+				if !ok {
+					continue
+				}
+				err := w.service.RunDue(ctx, now)
+				finish(err)
+				if err != nil {
+					w.logger.Error("Ops digest delivery failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
@@ -57,6 +57,9 @@ func (w *Worker) Start() error {
 		if err := w.apikeyService.CleanupExpiredKeys(ctx); err != nil {
 			w.logger.Error("Failed to cleanup expired API keys", zap.Error(err))
 		}
+		if err := w.apikeyService.CleanupExpiredNonces(ctx); err != nil {
+			w.logger.Error("Failed to cleanup signing nonces", zap.Error(err))
+		}
 	})
 	if err != nil {
 		return err
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/workerstatus"
 )
 
 // SchedulerConfig holds configuration for the worker scheduler
@@ -36,6 +38,7 @@ type Scheduler struct {
 	jobRepo ProvisioningJobRepository
 	config  SchedulerConfig
 	logger  *zap.Logger
+	tracker *workerstatus.Tracker
 
 	// Concurrency control
 	semaphore chan struct{}
@@ -70,6 +73,11 @@ func NewScheduler(
 	}
 }
 
+// SetTracker reports polls to the worker registry, which can pause them
+func (s *Scheduler) SetTracker(tracker *workerstatus.Tracker) {
+	s.tracker = tracker
+}
+
 // Start begins the scheduler's polling loop
 func (s *Scheduler) Start() error {
 	s.mu.Lock()
@@ -143,7 +151,7 @@ func (s *Scheduler) pollLoop() {
 	defer ticker.Stop()
 
 	// Process jobs immediately on start
-	s.processAvailableJobs()
+	s.poll()
 
 	for {
 		select {
@@ -152,13 +160,23 @@ func (s *Scheduler) pollLoop() {
 			return
 
 		case <-ticker.C:
-			s.processAvailableJobs()
+			s.poll()
 		}
 	}
 }
 
+// poll processes available jobs unless the scheduler has been paused
+func (s *Scheduler) poll() {
+	finish, ok := s.tracker.Begin()
+	if !ok {
+		s.logger.Debug("Wallet provisioning paused, skipping poll")
+		return
+	}
+	finish(s.processAvailableJobs())
+}
+
 // processAvailableJobs fetches and processes available jobs
-func (s *Scheduler) processAvailableJobs() {
+func (s *Scheduler) processAvailableJobs() error {
 	s.logger.Debug("Checking for available jobs")
 
 	// Fetch retryable jobs if enabled
@@ -166,6 +184,7 @@ func (s *Scheduler) processAvailableJobs() {
 		retryJobs, err := s.jobRepo.GetRetryableJobs(s.ctx, s.config.JobBatchSize)
 		if err != nil {
 			s.logger.Error("Failed to fetch retryable jobs", zap.Error(err))
+			return err
 		} else if len(retryJobs) > 0 {
 			s.logger.Info("Found retryable jobs to process", zap.Int("count", len(retryJobs)))
 			for _, job := range retryJobs {
@@ -174,8 +193,9 @@ func (s *Scheduler) processAvailableJobs() {
 		}
 	}
 
-	// Note: For queued jobs, we rely on the immediate processing when they're created
-	// or use a message queue/event bus for real-time processing
+	// Note: Queued jobs created during onboarding are processed immediately when
+	// they're created; backfill jobs are queued with a schedule and returned above
+	return nil
 }
 
 // enqueueJob attempts to process a job, respecting concurrency limits
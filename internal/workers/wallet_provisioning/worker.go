@@ -12,6 +12,7 @@ import (
 	"github.com/stack-service/stack_service/internal/domain/entities"
 
 	// "github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -37,6 +38,12 @@ type ProvisioningJobRepository interface {
 	Update(ctx context.Context, job *entities.WalletProvisioningJob) error
 }
 
+// LeaseRenewer is implemented by job repositories that can extend the lease
+// on an in-progress job. Without it the lease taken at start is never renewed.
+type LeaseRenewer interface {
+	RenewLease(ctx context.Context, id uuid.UUID, until time.Time) error
+}
+
 type CircleClient interface {
 	CreateWalletSet(ctx context.Context, name string, entitySecretCiphertext string) (*entities.CircleWalletSetResponse, error)
 	GetWalletSet(ctx context.Context, walletSetID string) (*entities.CircleWalletSetResponse, error)
@@ -77,6 +84,7 @@ type Config struct {
 	ChainsToProvision   []entities.WalletChain
 	WalletSetNamePrefix string
 	DefaultWalletSetID  string
+	LeaseDuration       time.Duration // How long a job may go without a heartbeat before the janitor requeues it
 }
 
 // DefaultConfig returns default worker configuration
@@ -91,6 +99,7 @@ func DefaultConfig() Config {
 		},
 		WalletSetNamePrefix: "STACK-WalletSet",
 		DefaultWalletSetID:  "",
+		LeaseDuration:       5 * time.Minute,
 	}
 }
 
@@ -124,6 +133,9 @@ func NewWorker(
 		config.WalletSetNamePrefix = "STACK-WalletSet"
 	}
 	config.DefaultWalletSetID = strings.TrimSpace(config.DefaultWalletSetID)
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = DefaultConfig().LeaseDuration
+	}
 
 	return &Worker{
 		walletRepo:    walletRepo,
@@ -166,9 +178,11 @@ func (w *Worker) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 	// Mark job as started
 	beforeJob := *job
 	job.MarkStarted()
+	job.TakeLease(w.config.LeaseDuration)
 	if err := w.jobRepo.Update(ctx, job); err != nil {
 		return fmt.Errorf("failed to update job status: %w", err)
 	}
+	stopHeartbeat := w.startHeartbeat(ctx, job.ID)
 
 	// Log job start audit
 	resourceID := job.ID.String()
@@ -185,6 +199,7 @@ func (w *Worker) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 
 	// Process the job
 	err = w.processJobInternal(ctx, job)
+	stopHeartbeat()
 
 	// Update metrics
 	duration := time.Since(startTime)
@@ -211,6 +226,42 @@ func (w *Worker) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 	return err
 }
 
+// startHeartbeat renews the job's lease at a third of its duration until the
+// returned stop function is called
+func (w *Worker) startHeartbeat(ctx context.Context, jobID uuid.UUID) func() {
+	renewer, ok := w.jobRepo.(LeaseRenewer)
+	if !ok {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.config.LeaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := renewer.RenewLease(ctx, jobID, time.Now().Add(w.config.LeaseDuration)); err != nil {
+					w.logger.Warn("Failed to renew wallet provisioning job lease",
+						zap.String("job_id", jobID.String()), zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
 // processJobInternal performs the actual wallet provisioning logic
 func (w *Worker) processJobInternal(ctx context.Context, job *entities.WalletProvisioningJob) error {
 	w.logger.Info("Starting wallet provisioning for user",
@@ -236,7 +287,7 @@ func (w *Worker) processJobInternal(ctx context.Context, job *entities.WalletPro
 			w.logger.Info("Wallet already exists for chain (idempotent)",
 				zap.String("user_id", job.UserID.String()),
 				zap.String("chain", string(chain)),
-				zap.String("address", existingWallet.Address))
+				logger.WalletAddr(existingWallet.Address))
 			successCount++
 			continue
 		}
@@ -362,7 +413,7 @@ func (w *Worker) createWalletForChain(ctx context.Context, job *entities.WalletP
 		w.logger.Warn("Failed to link wallet to Due account",
 			zap.Error(err),
 			zap.String("user_id", job.UserID.String()),
-			zap.String("wallet_address", address),
+			logger.WalletAddr(address),
 			zap.String("chain", string(chain)))
 		// Don't fail wallet creation if Due linking fails
 	}
@@ -370,7 +421,7 @@ func (w *Worker) createWalletForChain(ctx context.Context, job *entities.WalletP
 	w.logger.Info("Created developer-controlled wallet successfully",
 		zap.String("user_id", job.UserID.String()),
 		zap.String("chain", string(chain)),
-		zap.String("address", address),
+		logger.WalletAddr(address),
 		zap.String("circle_wallet_id", circleResp.Wallet.ID))
 
 	return nil
@@ -694,7 +745,7 @@ func (w *Worker) linkWalletToDue(ctx context.Context, userID uuid.UUID, wallet *
 	w.logger.Info("Linking wallet to Due account",
 		zap.String("user_id", userID.String()),
 		zap.String("due_account_id", *user.DueAccountID),
-		zap.String("wallet_address", wallet.Address),
+		logger.WalletAddr(wallet.Address),
 		zap.String("chain", chainForDue))
 
 	// Link wallet to Due account
@@ -718,24 +769,18 @@ func (w *Worker) linkWalletToDue(ctx context.Context, userID uuid.UUID, wallet *
 	w.logger.Info("Successfully linked wallet to Due account",
 		zap.String("user_id", userID.String()),
 		zap.String("due_account_id", *user.DueAccountID),
-		zap.String("wallet_address", wallet.Address))
+		logger.WalletAddr(wallet.Address))
 
 	return nil
 }
 
-// mapChainForDue maps internal chain names to Due-compatible formats
+// mapChainForDue maps internal chain names to Due-compatible formats. Due
+// uses the same chain IDs, so any enabled chain maps to itself.
 func (w *Worker) mapChainForDue(chain entities.WalletChain) string {
-	switch chain {
-	case entities.ChainSOLDevnet:
-		return "SOL-DEVNET"
-	// Add more chain mappings as needed
-	// case entities.ChainETH:
-	// 	return "ETH"
-	// case entities.ChainMATIC:
-	// 	return "MATIC"
-	default:
+	if !chain.IsValid() {
 		return ""
 	}
+	return string(chain)
 }
 
 // Helper function
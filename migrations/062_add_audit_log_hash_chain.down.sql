@@ -0,0 +1,17 @@
+-- Rollback audit log hash chain
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS prevent_audit_log_mutation();
+
+DROP INDEX IF EXISTS idx_audit_chain_anchors_anchored_at;
+DROP TABLE IF EXISTS audit_chain_anchors;
+
+DROP INDEX IF EXISTS idx_audit_logs_chain_seq;
+
+ALTER TABLE audit_logs
+DROP COLUMN IF EXISTS entry_hash,
+DROP COLUMN IF EXISTS prev_hash,
+DROP COLUMN IF EXISTS chain_seq;
+
+ALTER TABLE audit_logs
+ADD CONSTRAINT audit_logs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
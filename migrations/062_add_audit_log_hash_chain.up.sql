@@ -0,0 +1,42 @@
+-- Append-only, tamper-evident audit log
+-- Each chained entry stores the hash of its predecessor so retroactive edits break the chain
+
+ALTER TABLE audit_logs
+ADD COLUMN IF NOT EXISTS chain_seq BIGINT,
+ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64),
+ADD COLUMN IF NOT EXISTS entry_hash VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_chain_seq ON audit_logs(chain_seq) WHERE chain_seq IS NOT NULL;
+
+-- Audit entries must outlive the users they reference; nulling user_id would break the chain
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_user_id_fkey;
+
+-- Chain head anchors written periodically to external storage (0G)
+CREATE TABLE IF NOT EXISTS audit_chain_anchors (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    chain_seq BIGINT NOT NULL UNIQUE,
+    head_hash VARCHAR(64) NOT NULL,
+    storage_uri TEXT,
+    anchored_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_chain_anchors_anchored_at ON audit_chain_anchors(anchored_at DESC);
+
+-- Reject updates and deletes of chained entries at the database level
+CREATE OR REPLACE FUNCTION prevent_audit_log_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only: chained entry % cannot be modified', OLD.chain_seq;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW
+    WHEN (OLD.chain_seq IS NOT NULL)
+    EXECUTE FUNCTION prevent_audit_log_mutation();
+
+COMMENT ON COLUMN audit_logs.chain_seq IS 'Monotonic position of the entry in the audit hash chain';
+COMMENT ON COLUMN audit_logs.prev_hash IS 'entry_hash of the previous chained entry (zeros for the first entry)';
+COMMENT ON COLUMN audit_logs.entry_hash IS 'SHA-256 over prev_hash and the entry content';
+COMMENT ON TABLE audit_chain_anchors IS 'Periodic snapshots of the audit chain head, mirrored to external storage';
@@ -0,0 +1,8 @@
+-- Encrypted values must be decrypted by the application before rolling back;
+-- column types are left as TEXT so existing ciphertexts are not truncated.
+DROP INDEX IF EXISTS idx_virtual_accounts_account_number_hash;
+ALTER TABLE virtual_accounts DROP COLUMN IF EXISTS account_number_hash;
+ALTER TABLE virtual_accounts ADD CONSTRAINT virtual_accounts_account_number_key UNIQUE (account_number);
+
+DROP INDEX IF EXISTS idx_users_phone_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_hash;
@@ -0,0 +1,19 @@
+-- Field-level encryption for PII at rest.
+-- Encrypted values are stored as "enc:v<key_version>:<base64>" and are longer
+-- than the original column limits; equality lookups use HMAC blind indexes.
+
+ALTER TABLE users ALTER COLUMN phone TYPE TEXT;
+ALTER TABLE users ALTER COLUMN kyc_provider_ref TYPE TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_hash VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_users_phone_hash ON users(phone_hash);
+
+-- Ciphertexts are randomized, so uniqueness moves to the blind index
+ALTER TABLE virtual_accounts DROP CONSTRAINT IF EXISTS virtual_accounts_account_number_key;
+ALTER TABLE virtual_accounts ALTER COLUMN account_number TYPE TEXT;
+ALTER TABLE virtual_accounts ALTER COLUMN routing_number TYPE TEXT;
+ALTER TABLE virtual_accounts ADD COLUMN IF NOT EXISTS account_number_hash VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_virtual_accounts_account_number_hash
+    ON virtual_accounts(account_number_hash) WHERE account_number_hash IS NOT NULL;
+
+COMMENT ON COLUMN users.phone_hash IS 'HMAC-SHA256 blind index of the phone number for encrypted lookups';
+COMMENT ON COLUMN virtual_accounts.account_number_hash IS 'HMAC-SHA256 blind index of the account number';
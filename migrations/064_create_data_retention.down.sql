@@ -0,0 +1,8 @@
+CREATE OR REPLACE FUNCTION prevent_audit_log_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only: chained entry % cannot be modified', OLD.chain_seq;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS audit_chain_checkpoints;
+DROP TABLE IF EXISTS data_retention_runs;
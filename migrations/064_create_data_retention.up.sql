@@ -0,0 +1,41 @@
+-- Data retention: run history and audit chain prune checkpoints
+
+CREATE TABLE IF NOT EXISTS data_retention_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    policy_name VARCHAR(100) NOT NULL,
+    table_name VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('purge', 'anonymize', 'audit_prune')),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    cutoff TIMESTAMP WITH TIME ZONE NOT NULL,
+    rows_affected BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_retention_runs_policy ON data_retention_runs(policy_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_retention_runs_started_at ON data_retention_runs(started_at DESC);
+
+-- Hash of the last audit entry removed by retention; verification resumes from here
+CREATE TABLE IF NOT EXISTS audit_chain_checkpoints (
+    chain_seq BIGINT PRIMARY KEY,
+    head_hash VARCHAR(64) NOT NULL,
+    pruned_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Allow the retention engine, and only the retention engine, to delete chained
+-- audit entries once they are past the seven-year regulatory retention period
+CREATE OR REPLACE FUNCTION prevent_audit_log_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE'
+       AND current_setting('stack.audit_retention_purge', true) = 'on'
+       AND OLD.at < NOW() - INTERVAL '7 years' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_logs is append-only: chained entry % cannot be modified', OLD.chain_seq;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON TABLE data_retention_runs IS 'History of retention policy executions, including dry runs';
+COMMENT ON TABLE audit_chain_checkpoints IS 'Chain hashes preserved when old audit entries are pruned by retention';
@@ -0,0 +1,13 @@
+-- Backup restore drill history
+CREATE TABLE IF NOT EXISTS restore_drill_runs (
+    id UUID PRIMARY KEY,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'failed')),
+    passed BOOLEAN NOT NULL DEFAULT FALSE,
+    report JSONB NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_restore_drill_runs_started_at ON restore_drill_runs(started_at DESC);
+
+COMMENT ON TABLE restore_drill_runs IS 'Results of restoring the latest backup into a scratch database and checking it against production';
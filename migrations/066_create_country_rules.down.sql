@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_country;
+DROP TABLE IF EXISTS country_rules;
+ALTER TABLE users DROP COLUMN IF EXISTS country;
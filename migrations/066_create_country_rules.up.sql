@@ -0,0 +1,29 @@
+-- Country of residence captured at onboarding, used for jurisdiction gating
+ALTER TABLE users ADD COLUMN IF NOT EXISTS country VARCHAR(2);
+
+CREATE TABLE IF NOT EXISTS country_rules (
+    country_code VARCHAR(3) PRIMARY KEY,
+    onboarding_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    allowed_features TEXT[] NOT NULL DEFAULT '{}',
+    required_disclosures TEXT[] NOT NULL DEFAULT '{}',
+    kyc_level VARCHAR(20) NOT NULL DEFAULT 'basic'
+        CHECK (kyc_level IN ('basic', 'standard', 'enhanced')),
+    notes TEXT,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- '*' is the fallback rule for countries without an explicit entry
+INSERT INTO country_rules (country_code, onboarding_allowed, allowed_features, required_disclosures, kyc_level, notes)
+VALUES
+    ('*', TRUE, ARRAY['trading', 'crypto_deposits', 'crypto_withdrawals', 'cards', 'virtual_accounts'], '{}', 'standard', 'Default rule'),
+    ('US', TRUE, ARRAY['trading', 'crypto_deposits', 'crypto_withdrawals', 'cards', 'virtual_accounts'],
+        ARRAY['customer_agreement', 'crypto_risk_disclosure', 'form_crs'], 'standard', NULL),
+    ('CU', FALSE, '{}', '{}', 'enhanced', 'Sanctioned jurisdiction'),
+    ('IR', FALSE, '{}', '{}', 'enhanced', 'Sanctioned jurisdiction'),
+    ('KP', FALSE, '{}', '{}', 'enhanced', 'Sanctioned jurisdiction'),
+    ('SY', FALSE, '{}', '{}', 'enhanced', 'Sanctioned jurisdiction')
+ON CONFLICT (country_code) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_users_country ON users(country);
@@ -0,0 +1,25 @@
+-- Custodial accounts let a KYC-verified guardian hold an account for a minor
+-- until the beneficiary reaches majority and claims it with their own login.
+CREATE TABLE IF NOT EXISTS custodial_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    guardian_user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    beneficiary_first_name TEXT NOT NULL,
+    beneficiary_last_name TEXT NOT NULL,
+    beneficiary_date_of_birth DATE NOT NULL,
+    relationship VARCHAR(32) NOT NULL CHECK (relationship IN ('parent', 'legal_guardian')),
+    status VARCHAR(32) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'transfer_pending', 'transferred', 'closed')),
+    majority_date DATE NOT NULL,
+    transfer_email VARCHAR(255),
+    transfer_initiated_at TIMESTAMP WITH TIME ZONE,
+    beneficiary_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    transferred_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_custodial_accounts_guardian ON custodial_accounts(guardian_user_id);
+CREATE INDEX IF NOT EXISTS idx_custodial_accounts_beneficiary ON custodial_accounts(beneficiary_user_id)
+    WHERE beneficiary_user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_custodial_accounts_majority ON custodial_accounts(majority_date)
+    WHERE status = 'active';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_inactivity;
+DROP TABLE IF EXISTS admin_cases;
+DROP TABLE IF EXISTS trusted_contacts;
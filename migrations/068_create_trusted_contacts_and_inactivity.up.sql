@@ -0,0 +1,47 @@
+-- Trusted contact a firm may reach about a customer's account (FINRA Rule 4512)
+CREATE TABLE IF NOT EXISTS trusted_contacts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    relationship VARCHAR(64) NOT NULL,
+    email TEXT,
+    phone TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT trusted_contacts_reachable CHECK (email IS NOT NULL OR phone IS NOT NULL)
+);
+
+-- Generic admin case queue for accounts that need manual review
+CREATE TABLE IF NOT EXISTS admin_cases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    case_type VARCHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'in_review', 'resolved')),
+    summary TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolution_notes TEXT,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_cases_status ON admin_cases(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_cases_user ON admin_cases(user_id);
+-- At most one unresolved case of each type per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_cases_open_per_type
+    ON admin_cases(user_id, case_type) WHERE status <> 'resolved';
+
+-- Escalation state for dormant funded accounts
+CREATE TABLE IF NOT EXISTS account_inactivity (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    stage INTEGER NOT NULL DEFAULT 0,
+    last_activity_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_notified_at TIMESTAMP WITH TIME ZONE,
+    case_id UUID REFERENCES admin_cases(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
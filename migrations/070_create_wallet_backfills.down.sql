@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wallet_provisioning_jobs_backfill;
+ALTER TABLE wallet_provisioning_jobs DROP COLUMN IF EXISTS backfill_id;
+DROP TABLE IF EXISTS wallet_backfills;
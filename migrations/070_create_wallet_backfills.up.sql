@@ -0,0 +1,32 @@
+-- Admin-triggered wallet provisioning backfills for existing users
+CREATE TABLE IF NOT EXISTS wallet_backfills (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    chains TEXT[] NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'failed', 'cancelled')),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    batch_size INTEGER NOT NULL,
+    batch_interval_seconds INTEGER NOT NULL,
+    max_in_flight INTEGER NOT NULL,
+    eligible_users INTEGER NOT NULL DEFAULT 0,
+    enqueued_jobs INTEGER NOT NULL DEFAULT 0,
+    cursor_user_id UUID,
+    error_message TEXT,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Only one backfill may enqueue at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_backfills_single_running ON wallet_backfills ((TRUE))
+    WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_wallet_backfills_started_at ON wallet_backfills(started_at DESC);
+
+ALTER TABLE wallet_provisioning_jobs
+    ADD COLUMN IF NOT EXISTS backfill_id UUID REFERENCES wallet_backfills(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_provisioning_jobs_backfill ON wallet_provisioning_jobs(backfill_id, status)
+    WHERE backfill_id IS NOT NULL;
+
+COMMENT ON TABLE wallet_backfills IS 'Batches of wallet provisioning jobs enqueued for existing users after a chain is added';
+COMMENT ON COLUMN wallet_provisioning_jobs.backfill_id IS 'Backfill that enqueued the job, NULL for jobs created during onboarding';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS circle_notification_subscriptions;
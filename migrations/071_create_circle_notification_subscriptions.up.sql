@@ -0,0 +1,9 @@
+-- Circle notification subscriptions registered by this service
+CREATE TABLE IF NOT EXISTS circle_notification_subscriptions (
+    subscription_id VARCHAR(255) PRIMARY KEY,
+    endpoint TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    verified_at TIMESTAMP WITH TIME ZONE
+);
+
+COMMENT ON TABLE circle_notification_subscriptions IS 'Circle webhook subscriptions created at startup; rows whose endpoint no longer matches the configured callback URL are deleted from Circle';
@@ -0,0 +1,15 @@
+-- Last known Circle balance per managed wallet
+CREATE TABLE IF NOT EXISTS wallet_balance_cache (
+    managed_wallet_id UUID PRIMARY KEY REFERENCES managed_wallets(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    usdc_balance DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_balance_cache_user ON wallet_balance_cache(user_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_balance_cache_synced ON wallet_balance_cache(last_synced_at);
+
+COMMENT ON TABLE wallet_balance_cache IS 'Circle wallet balances refreshed by the balance cache worker and on deposit events';
+COMMENT ON COLUMN wallet_balance_cache.last_synced_at IS 'Time of the last successful Circle read; a failed refresh keeps the previous balance and sets last_error';
@@ -0,0 +1,17 @@
+-- Deposits still awaiting confirmations fall back to pending; reversed ones to failed
+UPDATE deposits SET status = 'pending' WHERE status = 'detected';
+UPDATE deposits SET status = 'confirmed' WHERE status = 'credited';
+UPDATE deposits SET status = 'failed' WHERE status = 'reversed';
+
+ALTER TABLE deposits DROP CONSTRAINT IF EXISTS deposits_status_check;
+ALTER TABLE deposits ADD CONSTRAINT deposits_status_check
+CHECK (status IN ('pending', 'confirmed', 'failed', 'off_ramp_initiated', 'off_ramp_completed', 'broker_funded'));
+
+ALTER TABLE deposits
+DROP COLUMN IF EXISTS reversal_reason,
+DROP COLUMN IF EXISTS reversed_at,
+DROP COLUMN IF EXISTS ledger_transaction_id,
+DROP COLUMN IF EXISTS credited_at,
+DROP COLUMN IF EXISTS credited_amount,
+DROP COLUMN IF EXISTS required_confirmations,
+DROP COLUMN IF EXISTS confirmations;
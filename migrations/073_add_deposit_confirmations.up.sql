@@ -0,0 +1,18 @@
+-- Track block confirmations so deposits are credited only once their chain's
+-- threshold is reached, and record enough to reverse a credit after a re-org
+ALTER TABLE deposits
+ADD COLUMN IF NOT EXISTS confirmations INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS required_confirmations INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS credited_amount DECIMAL(36, 18),
+ADD COLUMN IF NOT EXISTS credited_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS ledger_transaction_id UUID,
+ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS reversal_reason TEXT;
+
+ALTER TABLE deposits DROP CONSTRAINT IF EXISTS deposits_status_check;
+ALTER TABLE deposits ADD CONSTRAINT deposits_status_check
+CHECK (status IN ('pending', 'detected', 'confirmed', 'credited', 'reversed', 'failed',
+                  'off_ramp_initiated', 'off_ramp_completed', 'broker_funded'));
+
+COMMENT ON COLUMN deposits.required_confirmations IS 'Confirmations the chain required when the deposit was detected';
+COMMENT ON COLUMN deposits.credited_amount IS 'USD buying power credited, reversed if the transaction is dropped';
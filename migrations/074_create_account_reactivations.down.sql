@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS account_reactivations;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS closure_notes,
+DROP COLUMN IF EXISTS closure_reason,
+DROP COLUMN IF EXISTS closed_at;
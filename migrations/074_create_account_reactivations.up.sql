@@ -0,0 +1,37 @@
+-- Record why an account was closed so reactivation can tell self-service
+-- closures apart from accounts closed for cause
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS closure_reason VARCHAR(32)
+    CHECK (closure_reason IN ('user_request', 'admin', 'for_cause')),
+ADD COLUMN IF NOT EXISTS closure_notes TEXT;
+
+-- Accounts deactivated before closure tracking are treated as admin closures
+UPDATE users SET closed_at = updated_at, closure_reason = 'admin'
+WHERE is_active = false AND closure_reason IS NULL;
+
+-- Self-service requests to reopen a closed account
+CREATE TABLE IF NOT EXISTS account_reactivations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending_verification'
+        CHECK (status IN ('pending_verification', 'pending_approval', 'completed', 'rejected')),
+    closure_reason VARCHAR(32) NOT NULL,
+    requires_approval BOOLEAN NOT NULL DEFAULT false,
+    verification_url TEXT,
+    screened_country VARCHAR(2),
+    case_id UUID REFERENCES admin_cases(id) ON DELETE SET NULL,
+    prior_holdings JSONB,
+    restored_holdings JSONB,
+    rejection_reason TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    review_notes TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_reactivations_status ON account_reactivations(status, created_at);
+-- At most one request in progress per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_reactivations_open
+    ON account_reactivations(user_id) WHERE status IN ('pending_verification', 'pending_approval');
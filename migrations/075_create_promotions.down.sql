@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS promotion_grants;
+DROP TABLE IF EXISTS promotions;
+
+DELETE FROM ledger_accounts WHERE user_id IS NULL AND account_type = 'system_promotions' AND balance = 0;
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational');
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit', 'withdrawal', 'investment', 'conversion',
+    'internal_transfer', 'buffer_replenishment', 'reversal'
+));
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance', 'fiat_exposure', 'pending_investment',
+    'system_buffer_usdc', 'system_buffer_fiat', 'broker_operational'
+));
@@ -0,0 +1,74 @@
+-- Promotional credit ledger accounts and transaction types
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance',
+    'fiat_exposure',
+    'pending_investment',
+    'promotional_credit',     -- User's unvested promotional credit
+    'system_buffer_usdc',
+    'system_buffer_fiat',
+    'broker_operational',
+    'system_promotions'       -- Marketing budget funding promotional credit
+));
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions');
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit',
+    'withdrawal',
+    'investment',
+    'conversion',
+    'internal_transfer',
+    'buffer_replenishment',
+    'reversal',
+    'promotion',             -- Promotional credit granted or vested
+    'promotion_clawback'     -- Unvested promotional credit forfeited
+));
+
+-- The budget starts empty; treasury funds it before campaigns go live
+INSERT INTO ledger_accounts (id, user_id, account_type, currency, balance) VALUES
+    (uuid_generate_v4(), NULL, 'system_promotions', 'USDC', 0)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    amount DECIMAL(36, 18) NOT NULL CHECK (amount > 0),
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('manual', 'signup', 'first_deposit')),
+    vesting_days INTEGER NOT NULL DEFAULT 0 CHECK (vesting_days >= 0),
+    max_grants INTEGER CHECK (max_grants > 0),
+    grant_count INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_trigger ON promotions(trigger) WHERE active = true;
+
+CREATE TABLE IF NOT EXISTS promotion_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(36, 18) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'unvested' CHECK (status IN ('unvested', 'vested', 'forfeited')),
+    vests_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    granted_by UUID REFERENCES users(id),
+    grant_ledger_tx_id UUID REFERENCES ledger_transactions(id),
+    settle_ledger_tx_id UUID REFERENCES ledger_transactions(id),
+    forfeit_reason TEXT,
+    settled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (promotion_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_grants_user ON promotion_grants(user_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_grants_unvested ON promotion_grants(vests_at) WHERE status = 'unvested';
@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS invoices;
+DROP SEQUENCE IF EXISTS invoice_number_seq;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS subscription_plans;
+
+DELETE FROM ledger_accounts WHERE user_id IS NULL AND account_type = 'system_fee_revenue' AND balance = 0;
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions');
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit', 'withdrawal', 'investment', 'conversion',
+    'internal_transfer', 'buffer_replenishment', 'reversal',
+    'promotion', 'promotion_clawback'
+));
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance', 'fiat_exposure', 'pending_investment', 'promotional_credit',
+    'system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions'
+));
@@ -0,0 +1,104 @@
+-- Subscription fee revenue ledger account and transaction type
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance',
+    'fiat_exposure',
+    'pending_investment',
+    'promotional_credit',
+    'system_buffer_usdc',
+    'system_buffer_fiat',
+    'broker_operational',
+    'system_promotions',
+    'system_fee_revenue'      -- Subscription fees collected from users
+));
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions', 'system_fee_revenue');
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit',
+    'withdrawal',
+    'investment',
+    'conversion',
+    'internal_transfer',
+    'buffer_replenishment',
+    'reversal',
+    'promotion',
+    'promotion_clawback',
+    'subscription_fee'       -- Subscription invoice paid from cash balance
+));
+
+INSERT INTO ledger_accounts (id, user_id, account_type, currency, balance) VALUES
+    (uuid_generate_v4(), NULL, 'system_fee_revenue', 'USDC', 0)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price DECIMAL(36, 18) NOT NULL CHECK (price >= 0),
+    currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    interval_months INTEGER NOT NULL DEFAULT 1 CHECK (interval_months > 0),
+    -- Feature name to usage limit; -1 is unlimited
+    entitlements JSONB NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO subscription_plans (code, name, description, price, entitlements) VALUES
+    ('premium', 'Premium', 'Unlimited AI analyses, more price alerts and priority support', 9.99,
+     '{"ai_analyses": -1, "price_alerts": 50, "priority_support": 1}')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id UUID NOT NULL REFERENCES subscription_plans(id),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('active', 'past_due', 'canceled')),
+    payment_method VARCHAR(20) NOT NULL DEFAULT 'cash_balance' CHECK (payment_method IN ('cash_balance', 'card')),
+    current_period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    current_period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT false,
+    credit_balance DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    canceled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One live subscription per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_user_live ON subscriptions(user_id)
+    WHERE status IN ('active', 'past_due');
+CREATE INDEX IF NOT EXISTS idx_subscriptions_period_end ON subscriptions(current_period_end)
+    WHERE status = 'active';
+
+CREATE SEQUENCE IF NOT EXISTS invoice_number_seq;
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    number VARCHAR(20) NOT NULL UNIQUE DEFAULT ('INV-' || LPAD(nextval('invoice_number_seq')::text, 8, '0')),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('open', 'paid', 'void', 'uncollectible')),
+    subtotal DECIMAL(36, 18) NOT NULL,
+    credit_applied DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    total DECIMAL(36, 18) NOT NULL,
+    currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    line_items JSONB NOT NULL DEFAULT '[]',
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    failure_reason TEXT,
+    ledger_tx_id UUID REFERENCES ledger_transactions(id),
+    payment_ref VARCHAR(255),
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_user ON invoices(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_invoices_dunning ON invoices(next_attempt_at) WHERE status = 'open';
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_orders_open;
+
+UPDATE orders SET status = 'canceled' WHERE status IN ('open', 'expired');
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_limit_price;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN ('accepted', 'pending', 'partially_filled', 'filled', 'failed', 'canceled'));
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS triggered_at,
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS time_in_force,
+    DROP COLUMN IF EXISTS limit_price,
+    DROP COLUMN IF EXISTS order_type;
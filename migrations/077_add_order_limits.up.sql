@@ -0,0 +1,26 @@
+-- Basket limit orders: an order rests as 'open' until the synthetic basket
+-- price (sum of component weight x quote) crosses limit_price, and is
+-- 'expired' when it reaches expires_at untriggered
+ALTER TABLE orders
+    ADD COLUMN order_type VARCHAR(10) NOT NULL DEFAULT 'market' CHECK (order_type IN ('market', 'limit')),
+    ADD COLUMN limit_price DECIMAL(36, 18) CHECK (limit_price IS NULL OR limit_price > 0),
+    ADD COLUMN time_in_force VARCHAR(10) NOT NULL DEFAULT 'day' CHECK (time_in_force IN ('day', 'gtc')),
+    ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN triggered_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN (
+    'open',
+    'accepted',
+    'pending',
+    'partially_filled',
+    'filled',
+    'failed',
+    'canceled',
+    'expired'
+));
+
+ALTER TABLE orders ADD CONSTRAINT chk_orders_limit_price CHECK (order_type = 'market' OR limit_price IS NOT NULL);
+
+-- The limit order monitor scans resting orders
+CREATE INDEX idx_orders_open ON orders(created_at) WHERE status = 'open';
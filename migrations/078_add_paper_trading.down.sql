@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS paper_balances;
+DROP TABLE IF EXISTS paper_positions;
+DROP TABLE IF EXISTS paper_orders;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS paper;
+ALTER TABLE users DROP COLUMN IF EXISTS trading_mode;
@@ -0,0 +1,29 @@
+-- Paper trading: users in paper mode trade simulated balances against live
+-- quotes. Paper orders and positions mirror the live tables so the same
+-- repositories serve both, and never mix with brokerage-backed records.
+ALTER TABLE users
+    ADD COLUMN trading_mode VARCHAR(10) NOT NULL DEFAULT 'live' CHECK (trading_mode IN ('live', 'paper'));
+
+ALTER TABLE orders ADD COLUMN paper BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE paper_orders (LIKE orders INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING INDEXES);
+ALTER TABLE paper_orders ALTER COLUMN paper SET DEFAULT TRUE;
+ALTER TABLE paper_orders ADD CONSTRAINT chk_paper_orders_paper CHECK (paper);
+ALTER TABLE paper_orders ADD CONSTRAINT fk_paper_orders_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE paper_orders ADD CONSTRAINT fk_paper_orders_basket FOREIGN KEY (basket_id) REFERENCES baskets(id) ON DELETE CASCADE;
+
+CREATE TABLE paper_positions (LIKE positions INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING INDEXES);
+ALTER TABLE paper_positions ADD CONSTRAINT fk_paper_positions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE paper_positions ADD CONSTRAINT fk_paper_positions_basket FOREIGN KEY (basket_id) REFERENCES baskets(id) ON DELETE CASCADE;
+
+-- Simulated cash, funded once with starting_balance when paper mode is enabled
+CREATE TABLE paper_balances (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    buying_power DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    starting_balance DECIMAL(36, 18) NOT NULL,
+    currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_paper_balances_buying_power CHECK (buying_power >= 0)
+);
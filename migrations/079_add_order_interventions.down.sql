@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_orders_in_flight;
+DROP TABLE IF EXISTS order_interventions;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS resolved_at,
+    DROP COLUMN IF EXISTS resolved_by,
+    DROP COLUMN IF EXISTS resolution_note;
@@ -0,0 +1,27 @@
+-- Admin intervention on orders stuck in accepted, pending or partially_filled.
+-- The resolution columns hold the latest note; order_interventions keeps every
+-- cancel, repair and note with the balances and positions it changed.
+ALTER TABLE orders
+    ADD COLUMN resolution_note TEXT,
+    ADD COLUMN resolved_by UUID REFERENCES users(id),
+    ADD COLUMN resolved_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE order_interventions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    admin_id UUID NOT NULL REFERENCES users(id),
+    action VARCHAR(20) NOT NULL,
+    previous_status VARCHAR(20) NOT NULL,
+    new_status VARCHAR(20) NOT NULL,
+    buying_power_delta DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    position_quantity_delta DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    position_value_delta DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    note TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_order_interventions_action CHECK (action IN ('cancel', 'repair', 'annotate'))
+);
+
+CREATE INDEX idx_order_interventions_order ON order_interventions(order_id, created_at);
+
+-- The stuck order listing scans in-flight orders by age
+CREATE INDEX idx_orders_in_flight ON orders(updated_at) WHERE status IN ('accepted', 'pending', 'partially_filled');
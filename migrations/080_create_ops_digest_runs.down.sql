@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ops_digest_runs;
@@ -0,0 +1,9 @@
+-- One row per daily ops digest, claimed before it is sent so that only one
+-- instance delivers each day's digest
+CREATE TABLE ops_digest_runs (
+    digest_date DATE PRIMARY KEY,
+    claimed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE,
+    recipients INT NOT NULL DEFAULT 0,
+    error_message TEXT
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS http_capture_targets;
+DROP TABLE IF EXISTS http_captures;
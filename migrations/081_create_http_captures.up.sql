@@ -0,0 +1,43 @@
+-- Sampled, sanitized HTTP request/response captures for debugging issues
+-- reported from the mobile apps. Rows are hidden once expires_at passes and
+-- deleted by the retention engine.
+CREATE TABLE http_captures (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    request_id VARCHAR(100),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(200) NOT NULL,
+    path TEXT NOT NULL,
+    query TEXT,
+    status_code INT NOT NULL,
+    duration_ms INT NOT NULL,
+    client_ip VARCHAR(64),
+    user_agent TEXT,
+    request_headers JSONB NOT NULL DEFAULT '{}',
+    request_body TEXT,
+    response_body TEXT,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    reason VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT chk_http_captures_reason CHECK (reason IN ('sampled', 'user', 'route'))
+);
+
+CREATE INDEX idx_http_captures_user ON http_captures(user_id, created_at DESC);
+CREATE INDEX idx_http_captures_route ON http_captures(route, created_at DESC);
+CREATE INDEX idx_http_captures_request_id ON http_captures(request_id);
+CREATE INDEX idx_http_captures_expires_at ON http_captures(expires_at);
+
+-- Users and routes an admin has flagged for full capture until expires_at
+CREATE TABLE http_capture_targets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    route VARCHAR(200),
+    note TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT chk_http_capture_targets_subject CHECK (user_id IS NOT NULL OR route IS NOT NULL)
+);
+
+CREATE INDEX idx_http_capture_targets_expires_at ON http_capture_targets(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS recipient_id;
+DROP TABLE IF EXISTS withdrawal_recipients;
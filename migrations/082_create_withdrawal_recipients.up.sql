@@ -0,0 +1,40 @@
+-- Saved withdrawal destinations (address book). Bank account numbers are
+-- sealed by the field encryptor; fingerprint is a keyed hash of the
+-- normalized destination used to find the same destination across accounts.
+CREATE TABLE withdrawal_recipients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    chain VARCHAR(30),
+    address TEXT,
+    bank_name VARCHAR(100),
+    account_holder_name VARCHAR(200),
+    routing_number VARCHAR(20),
+    account_number TEXT,
+    account_last4 VARCHAR(4),
+    fingerprint VARCHAR(128) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'unverified',
+    hold_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    first_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    flag_reason TEXT,
+    flagged_by UUID REFERENCES users(id),
+    flagged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_withdrawal_recipients_kind CHECK (kind IN ('crypto', 'bank')),
+    CONSTRAINT chk_withdrawal_recipients_status CHECK (status IN ('unverified', 'verified', 'flagged')),
+    CONSTRAINT chk_withdrawal_recipients_details CHECK (
+        (kind = 'crypto' AND chain IS NOT NULL AND address IS NOT NULL) OR
+        (kind = 'bank' AND routing_number IS NOT NULL AND account_number IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX idx_withdrawal_recipients_user_fingerprint
+    ON withdrawal_recipients(user_id, fingerprint) WHERE deleted_at IS NULL;
+CREATE INDEX idx_withdrawal_recipients_fingerprint ON withdrawal_recipients(fingerprint);
+CREATE INDEX idx_withdrawal_recipients_user ON withdrawal_recipients(user_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE withdrawals ADD COLUMN recipient_id UUID REFERENCES withdrawal_recipients(id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_onboarding_jobs_in_progress;
+ALTER TABLE onboarding_jobs DROP COLUMN IF EXISTS steps;
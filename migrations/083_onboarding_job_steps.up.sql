@@ -0,0 +1,9 @@
+-- Per-step saga state for onboarding jobs. Completed steps are skipped when
+-- a job is retried and undone in reverse order when it fails for good.
+ALTER TABLE onboarding_jobs ADD COLUMN steps JSONB NOT NULL DEFAULT '[]';
+
+-- Admin view of jobs left in progress by a crashed worker
+CREATE INDEX idx_onboarding_jobs_in_progress ON onboarding_jobs(updated_at)
+WHERE status = 'in_progress';
+
+COMMENT ON COLUMN onboarding_jobs.steps IS 'Saga step status, attempts, errors and compensation data';
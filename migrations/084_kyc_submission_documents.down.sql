@@ -0,0 +1 @@
+ALTER TABLE kyc_submissions DROP COLUMN IF EXISTS documents;
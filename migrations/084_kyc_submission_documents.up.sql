@@ -0,0 +1,24 @@
+-- Per-document review state for KYC submissions, so a user can replace the
+-- one document that was rejected instead of starting over
+ALTER TABLE kyc_submissions ADD COLUMN documents JSONB NOT NULL DEFAULT '[]';
+
+-- Backfill from the uploads recorded with existing submissions
+UPDATE kyc_submissions
+SET documents = (
+    SELECT COALESCE(jsonb_agg(jsonb_build_object(
+        'type', doc->>'type',
+        'file_url', doc->>'fileUrl',
+        'content_type', doc->>'contentType',
+        'status', CASE status
+            WHEN 'approved' THEN 'accepted'
+            WHEN 'rejected' THEN 'rejected'
+            ELSE 'submitted'
+        END,
+        'revision', 1,
+        'submitted_at', submitted_at
+    )), '[]'::jsonb)
+    FROM jsonb_array_elements(verification_data->'documents') AS doc
+)
+WHERE jsonb_typeof(verification_data->'documents') = 'array';
+
+COMMENT ON COLUMN kyc_submissions.documents IS 'Uploaded documents with per-document review status and revision';
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS managed_wallets_onboarding_outbox ON managed_wallets;
+DROP TRIGGER IF EXISTS users_onboarding_outbox ON users;
+DROP FUNCTION IF EXISTS record_wallet_onboarding_change();
+DROP FUNCTION IF EXISTS record_user_onboarding_change();
+DROP TABLE IF EXISTS onboarding_outbox;
@@ -0,0 +1,64 @@
+-- Transactional outbox for onboarding, KYC and wallet state changes. Rows are
+-- written by triggers in the same transaction as the change itself, so an
+-- event exists if and only if the change committed, whichever code path made
+-- it. A relay publishes rows to the event bus and marks them published.
+CREATE TABLE onboarding_outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sequence BIGSERIAL NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    chain TEXT,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_onboarding_outbox_kind CHECK (kind IN ('onboarding', 'kyc', 'wallet'))
+);
+
+CREATE INDEX idx_onboarding_outbox_unpublished ON onboarding_outbox(user_id, sequence)
+WHERE published_at IS NULL;
+CREATE INDEX idx_onboarding_outbox_published_at ON onboarding_outbox(published_at)
+WHERE published_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_user_onboarding_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.onboarding_status IS DISTINCT FROM OLD.onboarding_status THEN
+        INSERT INTO onboarding_outbox (user_id, kind, from_status, to_status)
+        VALUES (NEW.id, 'onboarding', OLD.onboarding_status, NEW.onboarding_status);
+    END IF;
+    IF NEW.kyc_status IS DISTINCT FROM OLD.kyc_status AND NEW.kyc_status IS NOT NULL THEN
+        INSERT INTO onboarding_outbox (user_id, kind, from_status, to_status)
+        VALUES (NEW.id, 'kyc', OLD.kyc_status, NEW.kyc_status);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_wallet_onboarding_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO onboarding_outbox (user_id, kind, to_status, chain)
+        VALUES (NEW.user_id, 'wallet', NEW.status, NEW.chain);
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO onboarding_outbox (user_id, kind, from_status, to_status, chain)
+        VALUES (NEW.user_id, 'wallet', OLD.status, NEW.status, NEW.chain);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_onboarding_outbox
+AFTER UPDATE OF onboarding_status, kyc_status ON users
+FOR EACH ROW EXECUTE FUNCTION record_user_onboarding_change();
+
+CREATE TRIGGER managed_wallets_onboarding_outbox
+AFTER INSERT OR UPDATE OF status ON managed_wallets
+FOR EACH ROW EXECUTE FUNCTION record_wallet_onboarding_change();
+
+COMMENT ON TABLE onboarding_outbox IS 'Onboarding, KYC and wallet state changes awaiting publication to the event bus';
+COMMENT ON COLUMN onboarding_outbox.sequence IS 'Global publish order; consumers ignore events older than the last one applied for a user';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS geo_overrides;
+DROP TABLE IF EXISTS geo_access_events;
@@ -0,0 +1,40 @@
+-- Where each signup and login came from, as reported by the geo-IP provider.
+-- Blocked signups are kept without a user so compliance can see attempts from
+-- prohibited countries.
+CREATE TABLE geo_access_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    country_code TEXT,
+    region TEXT,
+    city TEXT,
+    isp TEXT,
+    is_vpn BOOLEAN NOT NULL DEFAULT FALSE,
+    is_proxy BOOLEAN NOT NULL DEFAULT FALSE,
+    is_tor BOOLEAN NOT NULL DEFAULT FALSE,
+    is_hosting BOOLEAN NOT NULL DEFAULT FALSE,
+    risk_score INTEGER NOT NULL DEFAULT 0,
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    blocked BOOLEAN NOT NULL DEFAULT FALSE,
+    overridden BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_geo_access_events_type CHECK (event_type IN ('signup', 'login'))
+);
+
+CREATE INDEX idx_geo_access_events_user ON geo_access_events(user_id, created_at DESC);
+CREATE INDEX idx_geo_access_events_flagged ON geo_access_events(user_id, created_at DESC)
+WHERE flagged;
+
+-- Addresses, CIDR ranges and users exempt from geo blocking and VPN flagging
+CREATE TABLE geo_overrides (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind TEXT NOT NULL,
+    value TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_geo_overrides_kind CHECK (kind IN ('ip', 'user')),
+    CONSTRAINT uq_geo_overrides_kind_value UNIQUE (kind, value)
+);
@@ -0,0 +1,28 @@
+-- The old job code inserts with ON CONFLICT (tx_hash, chain), which needs the
+-- unique constraint back. Jobs for the extra transfers of a transaction are
+-- dropped first, keeping the most advanced job per transaction; the deposits
+-- they recorded are kept.
+ALTER TABLE funding_event_jobs DROP CONSTRAINT IF EXISTS uq_funding_event_jobs_chain_tx_log;
+DELETE FROM funding_event_jobs
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (
+            PARTITION BY tx_hash, chain
+            ORDER BY (status = 'completed') DESC, created_at, id
+        ) AS job_rank
+        FROM funding_event_jobs
+    ) ranked
+    WHERE job_rank > 1
+);
+ALTER TABLE funding_event_jobs
+ADD CONSTRAINT funding_event_jobs_tx_hash_chain_key UNIQUE (tx_hash, chain);
+ALTER TABLE funding_event_jobs DROP COLUMN IF EXISTS log_index;
+
+DROP INDEX IF EXISTS uq_deposits_off_ramp_tx_id;
+DROP INDEX IF EXISTS uq_deposits_chain_tx_log;
+
+-- deposits_tx_hash_key is not restored: once a transaction has carried several
+-- transfers, or fiat deposits share an empty hash, it cannot be, and deposits
+-- are funds records that must not be deleted to make it fit. Nothing inserts
+-- against it, and idx_deposits_tx_hash still serves lookups by hash.
+ALTER TABLE deposits DROP COLUMN IF EXISTS log_index;
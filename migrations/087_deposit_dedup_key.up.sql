@@ -0,0 +1,22 @@
+-- Deposits are unique per on-chain transfer rather than per transaction: one
+-- transaction can carry several token transfers, told apart by log index.
+-- Fiat deposits have no transaction hash and are unique by Due transaction ID.
+ALTER TABLE deposits
+ADD COLUMN IF NOT EXISTS log_index INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE deposits DROP CONSTRAINT IF EXISTS deposits_tx_hash_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_deposits_chain_tx_log
+ON deposits(chain, tx_hash, log_index) WHERE tx_hash <> '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_deposits_off_ramp_tx_id
+ON deposits(off_ramp_tx_id) WHERE off_ramp_tx_id IS NOT NULL;
+
+ALTER TABLE funding_event_jobs
+ADD COLUMN IF NOT EXISTS log_index INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE funding_event_jobs DROP CONSTRAINT IF EXISTS funding_event_jobs_tx_hash_chain_key;
+ALTER TABLE funding_event_jobs
+ADD CONSTRAINT uq_funding_event_jobs_chain_tx_log UNIQUE (chain, tx_hash, log_index);
+
+COMMENT ON COLUMN deposits.log_index IS 'Position of the transfer log within its transaction';
@@ -0,0 +1,3 @@
+-- IANA time zone that scheduled notifications and summaries are timed in
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
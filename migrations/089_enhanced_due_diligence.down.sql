@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS edd_submissions;
+DROP INDEX IF EXISTS uq_transaction_limits_user_type_period;
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_tier;
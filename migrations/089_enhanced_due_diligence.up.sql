@@ -0,0 +1,35 @@
+-- KYC tier: enhanced accounts passed enhanced due diligence and get higher limits
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS kyc_tier VARCHAR(16) NOT NULL DEFAULT 'standard'
+    CHECK (kyc_tier IN ('standard', 'enhanced'));
+
+-- Tier changes rewrite a user's limits in place, one row per type and period
+DELETE FROM transaction_limits a USING transaction_limits b
+WHERE a.user_id = b.user_id AND a.limit_type = b.limit_type AND a.period = b.period
+  AND a.created_at < b.created_at;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_transaction_limits_user_type_period
+    ON transaction_limits(user_id, limit_type, period);
+
+-- Proof-of-funds submissions from users asking for the enhanced tier
+CREATE TABLE IF NOT EXISTS edd_submissions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending_review'
+        CHECK (status IN ('pending_review', 'approved', 'rejected')),
+    source_of_funds TEXT NOT NULL,
+    documents JSONB NOT NULL DEFAULT '[]',
+    case_id UUID REFERENCES admin_cases(id) ON DELETE SET NULL,
+    provider_ref TEXT,
+    rejection_reason TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    review_notes TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_edd_submissions_status ON edd_submissions(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_edd_submissions_user ON edd_submissions(user_id, created_at DESC);
+-- At most one submission awaiting review per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_edd_submissions_pending
+    ON edd_submissions(user_id) WHERE status = 'pending_review';
@@ -0,0 +1,17 @@
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit', 'withdrawal', 'investment', 'conversion',
+    'internal_transfer', 'buffer_replenishment', 'reversal',
+    'promotion', 'promotion_clawback', 'subscription_fee'
+));
+
+DELETE FROM ledger_accounts WHERE account_type = 'held_balance' AND balance = 0;
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance', 'fiat_exposure', 'pending_investment', 'promotional_credit',
+    'system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions',
+    'system_fee_revenue'
+));
+
+DROP TABLE IF EXISTS balance_holds;
@@ -0,0 +1,46 @@
+-- Holds on buying power for pending orders and withdrawals
+CREATE TABLE IF NOT EXISTS balance_holds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('order', 'withdrawal')),
+    reference_id UUID NOT NULL,
+    amount DECIMAL(36, 18) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'released')),
+    ledger_transaction_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    settled_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One hold per operation, so retried placements are idempotent
+CREATE UNIQUE INDEX IF NOT EXISTS uq_balance_holds_reference ON balance_holds(kind, reference_id);
+CREATE INDEX IF NOT EXISTS idx_balance_holds_user_active ON balance_holds(user_id) WHERE status = 'active';
+
+-- Held balance ledger account and transaction type
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance',
+    'fiat_exposure',
+    'pending_investment',
+    'held_balance',           -- Funds held for pending orders and withdrawals
+    'promotional_credit',
+    'system_buffer_usdc',
+    'system_buffer_fiat',
+    'broker_operational',
+    'system_promotions',
+    'system_fee_revenue'
+));
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit',
+    'withdrawal',
+    'investment',
+    'conversion',
+    'internal_transfer',
+    'buffer_replenishment',
+    'reversal',
+    'promotion',
+    'promotion_clawback',
+    'subscription_fee',
+    'balance_hold'            -- Funds held, captured or released for a pending operation
+));
@@ -0,0 +1,19 @@
+-- Hourly request analytics per user and endpoint, rolled up by the HTTP
+-- middleware. Anonymous requests are recorded under the nil user ID.
+CREATE TABLE IF NOT EXISTS api_usage_rollups (
+    bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id UUID NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    client_error_count BIGINT NOT NULL DEFAULT 0,
+    server_error_count BIGINT NOT NULL DEFAULT 0,
+    total_duration_ms BIGINT NOT NULL DEFAULT 0,
+    -- Request counts per latency bucket; bounds are defined by the service
+    latency_buckets BIGINT[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bucket_start, user_id, method, route)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_rollups_user ON api_usage_rollups(user_id, bucket_start);
+CREATE INDEX IF NOT EXISTS idx_api_usage_rollups_route ON api_usage_rollups(route, bucket_start);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS instant_funding_advances;
@@ -0,0 +1,22 @@
+-- Buying power advanced against chain deposits that are detected but not yet
+-- credited. An advance is repaid out of the deposit when it is credited, and
+-- clawed back if the deposit is dropped first.
+CREATE TABLE IF NOT EXISTS instant_funding_advances (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    deposit_id UUID NOT NULL REFERENCES deposits(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(36, 18) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    alpaca_transfer_id VARCHAR(100),
+    -- Part of a clawed-back advance the user had already spent
+    shortfall DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    settled_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT uq_instant_funding_advances_deposit UNIQUE (deposit_id),
+    CONSTRAINT chk_instant_funding_advances_amount CHECK (amount > 0),
+    CONSTRAINT chk_instant_funding_advances_status CHECK (status IN ('pending', 'active', 'repaid', 'reversed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_instant_funding_advances_user ON instant_funding_advances(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_instant_funding_advances_shortfall ON instant_funding_advances(created_at) WHERE shortfall > 0;
@@ -0,0 +1,32 @@
+-- Maker-checker approvals for large withdrawals and sensitive admin actions
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    action VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(100) NOT NULL,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    summary TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    required_approvals INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    execution_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_approval_requests_status CHECK (status IN ('pending', 'approved', 'rejected', 'failed')),
+    CONSTRAINT chk_approval_requests_required CHECK (required_approvals > 0)
+);
+
+-- One open request per resource and action
+CREATE UNIQUE INDEX IF NOT EXISTS uq_approval_requests_pending
+    ON approval_requests(action, resource_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_approval_requests_status ON approval_requests(status, created_at);
+
+CREATE TABLE IF NOT EXISTS approval_decisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    request_id UUID NOT NULL REFERENCES approval_requests(id) ON DELETE CASCADE,
+    admin_id UUID NOT NULL REFERENCES users(id),
+    approved BOOLEAN NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_approval_decisions_admin UNIQUE (request_id, admin_id)
+);
@@ -0,0 +1,19 @@
+-- Historical FX and crypto rates for valuing transactions at the time they happened
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    base_currency VARCHAR(10) NOT NULL,
+    quote_currency VARCHAR(10) NOT NULL,
+    rate DECIMAL(36, 18) NOT NULL,
+    observed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    source VARCHAR(30) NOT NULL,
+    reference_type VARCHAR(50),
+    reference_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_exchange_rates_rate CHECK (rate > 0),
+    CONSTRAINT uq_exchange_rates_observation UNIQUE (base_currency, quote_currency, observed_at, source)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_time
+    ON exchange_rates(base_currency, quote_currency, observed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_reference
+    ON exchange_rates(reference_type, reference_id) WHERE reference_id IS NOT NULL;
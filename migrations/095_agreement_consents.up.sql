@@ -0,0 +1,29 @@
+-- Versioned legal agreements and the versions each user accepted
+CREATE TABLE IF NOT EXISTS agreement_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agreement_type VARCHAR(50) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    document_url TEXT NOT NULL,
+    mandatory BOOLEAN NOT NULL DEFAULT FALSE,
+    published_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_agreement_versions UNIQUE (agreement_type, version),
+    CONSTRAINT chk_agreement_versions_type CHECK (agreement_type IN ('terms_of_service', 'privacy_policy', 'brokerage_agreement'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_agreement_versions_published ON agreement_versions(agreement_type, published_at);
+
+CREATE TABLE IF NOT EXISTS user_consents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    agreement_version_id UUID NOT NULL REFERENCES agreement_versions(id),
+    agreement_type VARCHAR(50) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    accepted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_user_consents UNIQUE (user_id, agreement_version_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_consents_user ON user_consents(user_id, agreement_type, accepted_at DESC);
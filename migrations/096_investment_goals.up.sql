@@ -0,0 +1,23 @@
+-- Investment goals: a target portfolio value by a date and the plan to get there
+CREATE TABLE IF NOT EXISTS investment_goals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    target_amount DECIMAL(20, 2) NOT NULL,
+    target_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    risk_level VARCHAR(20) NOT NULL,
+    baseline_value DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    baseline_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    planned_monthly DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    last_nudged_at TIMESTAMP WITH TIME ZONE,
+    achieved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_investment_goals_status CHECK (status IN ('active', 'achieved', 'cancelled')),
+    CONSTRAINT chk_investment_goals_risk_level CHECK (risk_level IN ('conservative', 'balanced', 'growth')),
+    CONSTRAINT chk_investment_goals_target CHECK (target_amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_investment_goals_user ON investment_goals(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_investment_goals_active ON investment_goals(id) WHERE status = 'active';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS captured_messages;
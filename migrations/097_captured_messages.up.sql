@@ -0,0 +1,15 @@
+-- Outbound email and SMS stored by sandbox capture instead of being sent
+CREATE TABLE IF NOT EXISTS captured_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    channel VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT,
+    text_body TEXT,
+    html_body TEXT,
+    environment VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_captured_messages_channel CHECK (channel IN ('email', 'sms'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_captured_messages_created ON captured_messages(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_captured_messages_recipient ON captured_messages(recipient, created_at DESC);
@@ -0,0 +1,34 @@
+-- Admin actions applied to a list of users in the background, with one
+-- outcome row per user
+CREATE TABLE IF NOT EXISTS bulk_operations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    reason TEXT NOT NULL,
+    requested_by UUID NOT NULL,
+    total INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_bulk_operations_type CHECK (type IN ('deactivate_users', 'reactivate_users', 'kyc_recheck', 'resend_verification_email')),
+    CONSTRAINT chk_bulk_operations_status CHECK (status IN ('running', 'completed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_operations_created ON bulk_operations(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS bulk_operation_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    operation_id UUID NOT NULL REFERENCES bulk_operations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    message TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT uq_bulk_operation_items_user UNIQUE (operation_id, user_id),
+    CONSTRAINT chk_bulk_operation_items_status CHECK (status IN ('pending', 'succeeded', 'skipped', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_operation_items_pending ON bulk_operation_items(next_attempt_at) WHERE status = 'pending';
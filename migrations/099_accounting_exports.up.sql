@@ -0,0 +1,20 @@
+-- One row per business day and destination the ledger journal is exported to
+CREATE TABLE IF NOT EXISTS accounting_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    journal_date DATE NOT NULL,
+    destination VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    external_ref TEXT,
+    checksum VARCHAR(64),
+    line_count INTEGER NOT NULL DEFAULT 0,
+    total_debits DECIMAL(36, 18) NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    exported_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_accounting_exports_day UNIQUE (journal_date, destination),
+    CONSTRAINT chk_accounting_exports_status CHECK (status IN ('exporting', 'exported', 'empty', 'failed'))
+);
+
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS kyb_beneficial_owners;
+DROP TABLE IF EXISTS kyb_applications;
+ALTER TABLE users DROP COLUMN IF EXISTS account_type;
@@ -0,0 +1,63 @@
+-- Account type: business accounts passed KYB and get business limits
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS account_type VARCHAR(16) NOT NULL DEFAULT 'individual'
+    CONSTRAINT chk_users_account_type CHECK (account_type IN ('individual', 'business'));
+
+-- Business verification applications, reviewed as business_verification cases
+CREATE TABLE IF NOT EXISTS kyb_applications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'draft'
+        CONSTRAINT chk_kyb_applications_status CHECK (status IN ('draft', 'pending_review', 'approved', 'rejected')),
+    legal_name VARCHAR(255) NOT NULL DEFAULT '',
+    trade_name VARCHAR(255),
+    entity_type VARCHAR(32) NOT NULL DEFAULT '',
+    registration_number VARCHAR(64) NOT NULL DEFAULT '',
+    -- Encrypted with the PII field key; the last four digits are kept for display
+    tax_id TEXT NOT NULL DEFAULT '',
+    tax_id_last4 VARCHAR(4) NOT NULL DEFAULT '',
+    incorporation_country VARCHAR(2) NOT NULL DEFAULT '',
+    incorporation_state VARCHAR(64),
+    incorporation_date DATE,
+    address JSONB NOT NULL DEFAULT '{}',
+    website TEXT,
+    business_activity TEXT NOT NULL DEFAULT '',
+    documents JSONB NOT NULL DEFAULT '[]',
+    case_id UUID REFERENCES admin_cases(id) ON DELETE SET NULL,
+    rejection_reason TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    review_notes TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyb_applications_status ON kyb_applications(status, submitted_at);
+CREATE INDEX IF NOT EXISTS idx_kyb_applications_user ON kyb_applications(user_id, created_at DESC);
+-- At most one draft or submitted application per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_kyb_applications_open
+    ON kyb_applications(user_id) WHERE status IN ('draft', 'pending_review');
+
+-- Owners and controllers of the business, each verified individually
+CREATE TABLE IF NOT EXISTS kyb_beneficial_owners (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES kyb_applications(id) ON DELETE CASCADE,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    title VARCHAR(100),
+    ownership_percent NUMERIC(5, 2) NOT NULL DEFAULT 0
+        CONSTRAINT chk_kyb_beneficial_owners_ownership_percent CHECK (ownership_percent >= 0 AND ownership_percent <= 100),
+    is_controller BOOLEAN NOT NULL DEFAULT FALSE,
+    country VARCHAR(2) NOT NULL,
+    documents JSONB NOT NULL DEFAULT '[]',
+    kyc_status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    kyc_provider_ref TEXT,
+    kyc_checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_kyb_beneficial_owners_email UNIQUE (application_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyb_beneficial_owners_application ON kyb_beneficial_owners(application_id);
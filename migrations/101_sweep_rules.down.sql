@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sweep_runs;
+DROP TABLE IF EXISTS sweep_rules;
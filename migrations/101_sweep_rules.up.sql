@@ -0,0 +1,40 @@
+-- Auto-sweep: idle buying power above a floor is invested into a basket
+CREATE TABLE IF NOT EXISTS sweep_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    basket_id UUID NOT NULL REFERENCES baskets(id),
+    floor DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    min_amount DECIMAL(20, 2) NOT NULL,
+    max_amount DECIMAL(20, 2),
+    cadence VARCHAR(16) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_reason TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_sweep_rules_cadence CHECK (cadence IN ('daily', 'weekly', 'monthly')),
+    CONSTRAINT chk_sweep_rules_floor CHECK (floor >= 0),
+    CONSTRAINT chk_sweep_rules_amounts CHECK (min_amount > 0 AND (max_amount IS NULL OR max_amount >= min_amount))
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweep_rules_due ON sweep_rules(next_run_at) WHERE enabled;
+
+-- One row per sweep, including runs skipped for lack of idle cash
+CREATE TABLE IF NOT EXISTS sweep_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rule_id UUID NOT NULL REFERENCES sweep_rules(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    basket_id UUID NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    buying_power DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    amount DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    order_id UUID,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_sweep_runs_status CHECK (status IN ('invested', 'skipped', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweep_runs_user ON sweep_runs(user_id, created_at DESC);
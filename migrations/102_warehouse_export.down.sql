@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_account_inactivity_updated;
+DROP INDEX IF EXISTS idx_orders_warehouse_filled;
+DROP INDEX IF EXISTS idx_deposits_warehouse_credited;
+DROP TABLE IF EXISTS warehouse_export_cursors;
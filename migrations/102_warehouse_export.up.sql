@@ -0,0 +1,21 @@
+-- How far each business event stream has been exported to the data warehouse.
+-- Streams are read from their source tables in (position, id) order.
+CREATE TABLE IF NOT EXISTS warehouse_export_cursors (
+    stream VARCHAR(50) PRIMARY KEY,
+    position TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT 'epoch',
+    last_id TEXT NOT NULL DEFAULT '',
+    exported_count BIGINT NOT NULL DEFAULT 0,
+    generation BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    last_export_at TIMESTAMP WITH TIME ZONE,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Keyset reads of the deposit and trade streams
+CREATE INDEX IF NOT EXISTS idx_deposits_warehouse_credited
+    ON deposits ((COALESCE(credited_at, confirmed_at)), id)
+    WHERE status IN ('confirmed', 'credited', 'off_ramp_initiated', 'off_ramp_completed', 'broker_funded');
+CREATE INDEX IF NOT EXISTS idx_orders_warehouse_filled ON orders(updated_at, id) WHERE status = 'filled' AND NOT paper;
+CREATE INDEX IF NOT EXISTS idx_account_inactivity_updated ON account_inactivity(updated_at, user_id) WHERE stage > 0;
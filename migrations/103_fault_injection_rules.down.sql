@@ -0,0 +1 @@
+DROP TABLE IF EXISTS fault_injection_rules;
@@ -0,0 +1,20 @@
+-- Faults injected into outbound calls to external providers in non-production
+-- environments, so incident response and circuit breakers can be rehearsed
+CREATE TABLE fault_injection_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider VARCHAR(20) NOT NULL,
+    method VARCHAR(10) NOT NULL DEFAULT '',
+    path_prefix VARCHAR(200) NOT NULL DEFAULT '',
+    fault VARCHAR(20) NOT NULL,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    percent NUMERIC(5,2) NOT NULL DEFAULT 100,
+    note TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT chk_fault_injection_rules_fault CHECK (fault IN ('latency', 'error', 'malformed')),
+    CONSTRAINT chk_fault_injection_rules_percent CHECK (percent > 0 AND percent <= 100)
+);
+
+CREATE INDEX idx_fault_injection_rules_expires_at ON fault_injection_rules(expires_at);
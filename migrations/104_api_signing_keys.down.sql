@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS api_signing_nonces;
+DROP TABLE IF EXISTS api_signing_keys;
@@ -0,0 +1,30 @@
+-- HMAC keys internal services sign their requests with. Each key belongs to
+-- one tenant and one environment; the secret is encrypted with the service's
+-- encryption key because the server needs it back to verify signatures.
+CREATE TABLE api_signing_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key_id VARCHAR(40) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    tenant VARCHAR(100) NOT NULL,
+    environment VARCHAR(20) NOT NULL,
+    secret_encrypted TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_signing_keys_tenant ON api_signing_keys(tenant, environment);
+
+-- Nonces of accepted signed requests, kept until their timestamp falls out of
+-- the tolerance window, so a captured request cannot be replayed
+CREATE TABLE api_signing_nonces (
+    key_id VARCHAR(40) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (key_id, nonce)
+);
+
+CREATE INDEX idx_api_signing_nonces_expires_at ON api_signing_nonces(expires_at);
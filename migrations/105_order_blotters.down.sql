@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_order_interventions_created_at;
+DROP INDEX IF EXISTS idx_orders_updated_at;
+
+DROP TRIGGER IF EXISTS order_blotters_append_only ON order_blotters;
+DROP FUNCTION IF EXISTS prevent_order_blotter_mutation();
+DROP TABLE IF EXISTS order_blotters;
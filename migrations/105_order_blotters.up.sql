@@ -0,0 +1,32 @@
+-- Daily order blotter of record for regulatory reporting. A stored blotter is
+-- never changed or deleted; generating a day again adds the next version.
+CREATE TABLE order_blotters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_date DATE NOT NULL,
+    version INTEGER NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    record_count INTEGER NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    content BYTEA NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    generated_by UUID REFERENCES users(id),
+    generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_order_blotters_date_version UNIQUE (business_date, version),
+    CONSTRAINT chk_order_blotters_format CHECK (format IN ('csv', 'fix')),
+    CONSTRAINT chk_order_blotters_version CHECK (version > 0)
+);
+
+CREATE OR REPLACE FUNCTION prevent_order_blotter_mutation() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'order_blotters is append-only: blotter % v% cannot be modified', OLD.business_date, OLD.version;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER order_blotters_append_only
+    BEFORE UPDATE OR DELETE ON order_blotters
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_order_blotter_mutation();
+
+-- The blotter reads the day's status changes and amendments by time
+CREATE INDEX idx_orders_updated_at ON orders(updated_at);
+CREATE INDEX idx_order_interventions_created_at ON order_interventions(created_at);
@@ -0,0 +1,25 @@
+-- Files users upload straight to object storage through pre-signed URLs.
+-- Documents reference an upload by ID instead of carrying the file inline.
+CREATE TABLE uploads (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    scan_result VARCHAR(255) NOT NULL DEFAULT '',
+    rejection_reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_uploads_purpose CHECK (purpose IN ('kyc', 'edd', 'kyb', 'support')),
+    CONSTRAINT chk_uploads_status CHECK (status IN ('pending', 'available', 'rejected')),
+    CONSTRAINT chk_uploads_size_bytes CHECK (size_bytes > 0)
+);
+
+CREATE INDEX idx_uploads_user_id ON uploads(user_id);
+-- The purge worker looks for pending uploads that were never completed
+CREATE INDEX idx_uploads_pending_expires_at ON uploads(expires_at) WHERE status = 'pending';
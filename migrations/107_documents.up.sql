@@ -0,0 +1,35 @@
+-- Document center: disclosures, prospectus links and trade confirmations.
+-- A document without a user_id is shown to every user.
+CREATE TABLE documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(30) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    content_type VARCHAR(100) NOT NULL DEFAULT 'text/plain',
+    mandatory BOOLEAN NOT NULL DEFAULT FALSE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    published_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_documents_category CHECK (category IN ('trade_confirmation', 'prospectus', 'fee_disclosure', 'agreement', 'disclosure')),
+    CONSTRAINT chk_documents_content CHECK (url <> '' OR body <> '')
+);
+
+CREATE INDEX idx_documents_user_published ON documents(user_id, published_at DESC);
+CREATE INDEX idx_documents_broadcast_published ON documents(published_at DESC) WHERE user_id IS NULL;
+-- One confirmation per order, however often its fill is delivered
+CREATE UNIQUE INDEX uq_documents_trade_confirmation ON documents(order_id) WHERE category = 'trade_confirmation';
+
+-- First time each user opened a document
+CREATE TABLE document_receipts (
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    read_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (document_id, user_id)
+);
+
+CREATE INDEX idx_document_receipts_user ON document_receipts(user_id);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS held_notifications;
+
+ALTER TABLE user_preferences
+    DROP CONSTRAINT IF EXISTS chk_user_preferences_digest_hour,
+    DROP CONSTRAINT IF EXISTS chk_user_preferences_quiet_hours_end,
+    DROP CONSTRAINT IF EXISTS chk_user_preferences_quiet_hours_start,
+    DROP COLUMN IF EXISTS digest_hour,
+    DROP COLUMN IF EXISTS digest_low_priority,
+    DROP COLUMN IF EXISTS quiet_hours_end,
+    DROP COLUMN IF EXISTS quiet_hours_start,
+    DROP COLUMN IF EXISTS quiet_hours_enabled;
@@ -0,0 +1,24 @@
+-- Quiet hours and daily digests for notifications. Times are HH:MM in the
+-- user's profile time zone.
+ALTER TABLE user_preferences
+    ADD COLUMN quiet_hours_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN quiet_hours_start VARCHAR(5) NOT NULL DEFAULT '22:00',
+    ADD COLUMN quiet_hours_end VARCHAR(5) NOT NULL DEFAULT '07:00',
+    ADD COLUMN digest_low_priority BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN digest_hour SMALLINT NOT NULL DEFAULT 8,
+    ADD CONSTRAINT chk_user_preferences_quiet_hours_start CHECK (quiet_hours_start ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    ADD CONSTRAINT chk_user_preferences_quiet_hours_end CHECK (quiet_hours_end ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    ADD CONSTRAINT chk_user_preferences_digest_hour CHECK (digest_hour BETWEEN 0 AND 23);
+
+-- Notifications held until quiet hours end or the next daily digest
+CREATE TABLE held_notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL,
+    notification JSONB NOT NULL,
+    deliver_after TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_held_notifications_reason CHECK (reason IN ('quiet_hours', 'digest'))
+);
+
+CREATE INDEX idx_held_notifications_deliver_after ON held_notifications(deliver_after);
@@ -0,0 +1,3 @@
+-- When a user was first prompted to replace a password that breaks the
+-- current password policy; cleared when the password changes
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_weak_since TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+ALTER TABLE kyc_submissions DROP CONSTRAINT IF EXISTS chk_kyc_submissions_sub_status;
+ALTER TABLE kyc_submissions DROP COLUMN IF EXISTS sub_status;
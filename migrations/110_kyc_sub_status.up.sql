@@ -0,0 +1,6 @@
+-- Intermediate provider states for KYC reviews in progress, and whether a
+-- rejection can be fixed by resubmitting documents
+ALTER TABLE kyc_submissions ADD COLUMN sub_status VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE kyc_submissions ADD CONSTRAINT chk_kyc_submissions_sub_status
+    CHECK (sub_status IN ('', 'documents_requested', 'under_review', 'on_hold', 'resubmission_required'));
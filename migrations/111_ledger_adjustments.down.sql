@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS ledger_adjustments;
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit', 'withdrawal', 'investment', 'conversion',
+    'internal_transfer', 'buffer_replenishment', 'reversal',
+    'promotion', 'promotion_clawback', 'subscription_fee', 'balance_hold'
+));
@@ -0,0 +1,45 @@
+-- Manual correcting ledger entries, posted once a second admin approves them
+CREATE TABLE IF NOT EXISTS ledger_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    account_type VARCHAR(50) NOT NULL,
+    offset_account_type VARCHAR(50) NOT NULL,
+    direction VARCHAR(10) NOT NULL,
+    amount DECIMAL(36, 18) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    reason_code VARCHAR(50) NOT NULL,
+    memo TEXT NOT NULL,
+    documents JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_approval',
+    requested_by UUID NOT NULL,
+    approval_request_id UUID REFERENCES approval_requests(id),
+    ledger_transaction_id UUID REFERENCES ledger_transactions(id),
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    posted_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_ledger_adjustments_direction CHECK (direction IN ('increase', 'decrease')),
+    CONSTRAINT chk_ledger_adjustments_amount CHECK (amount > 0),
+    CONSTRAINT chk_ledger_adjustments_status CHECK (status IN ('pending_approval', 'posted', 'rejected', 'failed')),
+    CONSTRAINT chk_ledger_adjustments_documents CHECK (jsonb_array_length(documents) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_adjustments_user ON ledger_adjustments(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_adjustments_status ON ledger_adjustments(status, created_at);
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit',
+    'withdrawal',
+    'investment',
+    'conversion',
+    'internal_transfer',
+    'buffer_replenishment',
+    'reversal',
+    'promotion',
+    'promotion_clawback',
+    'subscription_fee',
+    'balance_hold',
+    'adjustment'              -- Manual correcting entry posted under dual control
+));
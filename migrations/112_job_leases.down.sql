@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_funding_event_jobs_lease;
+DROP INDEX IF EXISTS idx_wallet_provisioning_jobs_lease;
+
+ALTER TABLE funding_event_jobs
+    DROP COLUMN IF EXISTS heartbeat_at,
+    DROP COLUMN IF EXISTS lease_expires_at;
+
+ALTER TABLE wallet_provisioning_jobs
+    DROP COLUMN IF EXISTS heartbeat_at,
+    DROP COLUMN IF EXISTS lease_expires_at;
@@ -0,0 +1,23 @@
+-- Workers hold a lease on the jobs they are processing and renew it with a
+-- heartbeat. A job whose lease has run out belongs to a worker that crashed
+-- or hung, and the job janitor requeues or dead-letters it.
+ALTER TABLE wallet_provisioning_jobs
+    ADD COLUMN lease_expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE funding_event_jobs
+    ADD COLUMN lease_expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+-- Jobs already in flight were started without a lease; give them one from
+-- their last update so the janitor picks them up if they never finish
+UPDATE wallet_provisioning_jobs
+SET lease_expires_at = updated_at + INTERVAL '15 minutes'
+WHERE status = 'in_progress';
+
+UPDATE funding_event_jobs
+SET lease_expires_at = updated_at + INTERVAL '15 minutes'
+WHERE status = 'processing';
+
+CREATE INDEX idx_wallet_provisioning_jobs_lease ON wallet_provisioning_jobs(lease_expires_at) WHERE status = 'in_progress';
+CREATE INDEX idx_funding_event_jobs_lease ON funding_event_jobs(lease_expires_at) WHERE status = 'processing';
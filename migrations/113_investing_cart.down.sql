@@ -0,0 +1 @@
+DROP TABLE IF EXISTS investing_cart_items;
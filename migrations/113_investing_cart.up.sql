@@ -0,0 +1,11 @@
+-- Basket buys a user has queued to place together in one checkout. One line
+-- per basket; adding the same basket again replaces its amount.
+CREATE TABLE investing_cart_items (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    basket_id UUID NOT NULL REFERENCES baskets(id) ON DELETE CASCADE,
+    amount DECIMAL(36, 18) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, basket_id),
+    CONSTRAINT chk_investing_cart_items_amount CHECK (amount > 0)
+);
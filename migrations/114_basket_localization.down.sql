@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_baskets_regions;
+DROP INDEX IF EXISTS idx_baskets_parent_basket_id;
+
+ALTER TABLE baskets
+    DROP COLUMN IF EXISTS regions,
+    DROP COLUMN IF EXISTS parent_basket_id;
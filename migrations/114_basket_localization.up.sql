@@ -0,0 +1,10 @@
+-- Regional variants of curated baskets. A clone points at the basket it was
+-- localized from so analytics can report the family together; clones of
+-- clones point at the original. regions lists the ISO country codes or
+-- region groups (e.g. EU) a basket is offered in; empty means everywhere.
+ALTER TABLE baskets
+    ADD COLUMN parent_basket_id UUID REFERENCES baskets(id) ON DELETE SET NULL,
+    ADD COLUMN regions TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_baskets_parent_basket_id ON baskets(parent_basket_id) WHERE parent_basket_id IS NOT NULL;
+CREATE INDEX idx_baskets_regions ON baskets USING GIN (regions);
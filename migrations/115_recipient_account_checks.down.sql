@@ -0,0 +1,5 @@
+ALTER TABLE withdrawal_recipients
+    DROP CONSTRAINT IF EXISTS chk_withdrawal_recipients_account_check_status,
+    DROP COLUMN IF EXISTS account_checked_at,
+    DROP COLUMN IF EXISTS account_check_reason,
+    DROP COLUMN IF EXISTS account_check_status;
@@ -0,0 +1,11 @@
+-- Bank-linking provider checks on saved bank recipients, run before ACH
+-- payouts to catch closed, unknown and third-party accounts that would be
+-- returned. NULL for crypto recipients.
+ALTER TABLE withdrawal_recipients
+    ADD COLUMN account_check_status VARCHAR(20),
+    ADD COLUMN account_check_reason VARCHAR(50),
+    ADD COLUMN account_checked_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT chk_withdrawal_recipients_account_check_status
+        CHECK (account_check_status IN ('unchecked', 'verified', 'failed'));
+
+UPDATE withdrawal_recipients SET account_check_status = 'unchecked' WHERE kind = 'bank';
@@ -0,0 +1,20 @@
+-- Mirror of the wallets linked to each user's Due account: the platform
+-- wallets the provisioning worker links and self-custody addresses the user
+-- proved they own by signing a challenge
+CREATE TABLE IF NOT EXISTS linked_wallets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chain VARCHAR(50) NOT NULL,
+    address VARCHAR(100) NOT NULL,
+    due_wallet_id VARCHAR(100) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    label VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_linked_wallets_source CHECK (source IN ('platform', 'self_custody')),
+    CONSTRAINT uq_linked_wallets_user_address UNIQUE (user_id, chain, address),
+    CONSTRAINT uq_linked_wallets_due_wallet UNIQUE (user_id, due_wallet_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_linked_wallets_user ON linked_wallets(user_id, created_at);
@@ -0,0 +1,47 @@
+-- Onboarding experiments: product-defined variants of the onboarding flow.
+-- Users are bucketed by a hash of their ID, so the variant is stable before
+-- it is stored; the stored assignment is what analysis joins on.
+CREATE TABLE IF NOT EXISTS experiments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    variants JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    winning_variant VARCHAR(100),
+    conclusion_reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    concluded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    concluded_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_experiments_status CHECK (status IN ('running', 'concluded')),
+    CONSTRAINT uq_experiments_key UNIQUE (key)
+);
+
+CREATE TABLE IF NOT EXISTS experiment_assignments (
+    experiment_id UUID NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    variant VARCHAR(100) NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (experiment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_assignments_user ON experiment_assignments(user_id);
+
+-- Exposure and conversion events, exported to the warehouse. A user is
+-- exposed once per experiment and converts once per goal.
+CREATE TABLE IF NOT EXISTS experiment_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    experiment_id UUID NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    variant VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    goal VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_experiment_events_kind CHECK (kind IN ('exposure', 'conversion')),
+    CONSTRAINT uq_experiment_events UNIQUE (experiment_id, user_id, kind, goal)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_events_warehouse ON experiment_events(kind, created_at, id);
@@ -0,0 +1,20 @@
+-- Runs of the data integrity checker, which scans for records that disagree
+-- across aggregates: completed users without wallets, filled orders without
+-- ledger entries and the like. Scheduled runs claim their date so only one
+-- instance runs each night; manual runs have no date.
+CREATE TABLE IF NOT EXISTS integrity_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    run_date DATE UNIQUE,
+    trigger VARCHAR(20) NOT NULL,
+    triggered_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    anomalies INTEGER NOT NULL DEFAULT 0,
+    report JSONB,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_integrity_runs_trigger CHECK (trigger IN ('scheduled', 'manual'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_integrity_runs_started ON integrity_runs(started_at DESC);
+
@@ -0,0 +1,11 @@
+ALTER TABLE withdrawal_recipients
+    DROP CONSTRAINT IF EXISTS chk_withdrawal_recipients_address_check_method,
+    DROP CONSTRAINT IF EXISTS chk_withdrawal_recipients_address_check_status,
+    DROP COLUMN IF EXISTS address_checked_at,
+    DROP COLUMN IF EXISTS address_check_expires_at,
+    DROP COLUMN IF EXISTS address_check_attempts,
+    DROP COLUMN IF EXISTS address_check_reference,
+    DROP COLUMN IF EXISTS address_check_message,
+    DROP COLUMN IF EXISTS address_check_amount,
+    DROP COLUMN IF EXISTS address_check_method,
+    DROP COLUMN IF EXISTS address_check_status;
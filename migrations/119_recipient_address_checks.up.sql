@@ -0,0 +1,18 @@
+-- Proof that the user controls a saved crypto address, either by confirming
+-- a micro-deposit sent to it or by signing a message with its key. Larger
+-- withdrawals to a newly saved address require it. NULL for bank recipients.
+ALTER TABLE withdrawal_recipients
+    ADD COLUMN address_check_status VARCHAR(20),
+    ADD COLUMN address_check_method VARCHAR(20),
+    ADD COLUMN address_check_amount NUMERIC(20, 6),
+    ADD COLUMN address_check_message TEXT,
+    ADD COLUMN address_check_reference VARCHAR(100),
+    ADD COLUMN address_check_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN address_check_expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN address_checked_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT chk_withdrawal_recipients_address_check_status
+        CHECK (address_check_status IN ('unchecked', 'pending', 'verified', 'failed', 'expired')),
+    ADD CONSTRAINT chk_withdrawal_recipients_address_check_method
+        CHECK (address_check_method IN ('micro_deposit', 'signature'));
+
+UPDATE withdrawal_recipients SET address_check_status = 'unchecked' WHERE kind = 'crypto';
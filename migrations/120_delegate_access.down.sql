@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS delegate_access_log;
+DROP TABLE IF EXISTS delegate_sessions;
+DROP TABLE IF EXISTS delegate_grants;
@@ -0,0 +1,52 @@
+-- Read-only access a user grants to an advisor or family member. Delegates
+-- sign in with the grant's token on their own endpoints and can only view
+-- the scopes granted; tokens are stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS delegate_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delegate_name VARCHAR(100) NOT NULL,
+    delegate_email VARCHAR(255) NOT NULL,
+    relationship VARCHAR(20) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_delegate_grants_relationship CHECK (relationship IN ('advisor', 'family', 'other')),
+    CONSTRAINT chk_delegate_grants_status CHECK (status IN ('active', 'revoked'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegate_grants_user ON delegate_grants(user_id, created_at DESC);
+
+-- Short-lived sign-ins under a grant. Revoking the grant ends them.
+CREATE TABLE IF NOT EXISTS delegate_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    grant_id UUID NOT NULL REFERENCES delegate_grants(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegate_sessions_expires ON delegate_sessions(expires_at);
+
+-- Everything a delegate viewed, for the account holder to review
+CREATE TABLE IF NOT EXISTS delegate_access_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    grant_id UUID NOT NULL REFERENCES delegate_grants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL,
+    resource VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(100),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegate_access_log_grant ON delegate_access_log(grant_id, viewed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_delegate_access_log_user ON delegate_access_log(user_id, viewed_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS webhook_archive_links;
+DROP TABLE IF EXISTS webhook_archive;
@@ -0,0 +1,33 @@
+-- Raw provider webhooks kept for dispute investigations. Bodies live in cold
+-- storage at storage_uri; these rows are what the archive is searched by.
+CREATE TABLE IF NOT EXISTS webhook_archive (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider VARCHAR(50) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    reference VARCHAR(255),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    storage_uri TEXT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    response_status INTEGER NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_archive_provider ON webhook_archive(provider, event_type, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_archive_reference ON webhook_archive(reference) WHERE reference IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_archive_user ON webhook_archive(user_id, received_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_archive_received ON webhook_archive(received_at DESC);
+
+-- Deposits, withdrawals, orders and users each archived webhook produced or
+-- updated
+CREATE TABLE IF NOT EXISTS webhook_archive_links (
+    webhook_id UUID NOT NULL REFERENCES webhook_archive(id) ON DELETE CASCADE,
+    subject_type VARCHAR(20) NOT NULL,
+    subject_id UUID NOT NULL,
+    PRIMARY KEY (webhook_id, subject_type, subject_id),
+
+    CONSTRAINT chk_webhook_archive_links_subject CHECK (subject_type IN ('deposit', 'withdrawal', 'order', 'user'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_archive_links_subject ON webhook_archive_links(subject_type, subject_id);
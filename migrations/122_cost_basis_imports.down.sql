@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tax_lot_adjustments;
+DROP TABLE IF EXISTS tax_lots;
+DROP TABLE IF EXISTS cost_basis_imports;
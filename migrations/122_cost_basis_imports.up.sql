@@ -0,0 +1,51 @@
+-- Broker CSV exports uploaded to record the cost basis of positions
+-- transferred in. The lots read from the file wait here for the user to
+-- review and confirm them.
+CREATE TABLE IF NOT EXISTS cost_basis_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_review',
+    lots JSONB NOT NULL DEFAULT '[]',
+    errors JSONB NOT NULL DEFAULT '[]',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_cost_basis_imports_status CHECK (status IN ('pending_review', 'confirmed', 'cancelled'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_basis_imports_user ON cost_basis_imports(user_id, created_at DESC);
+
+-- Tax lots with their cost basis
+CREATE TABLE IF NOT EXISTS tax_lots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol VARCHAR(12) NOT NULL,
+    quantity DECIMAL(36, 18) NOT NULL,
+    cost_basis DECIMAL(36, 18) NOT NULL,
+    acquired_at DATE NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    import_id UUID REFERENCES cost_basis_imports(id) ON DELETE SET NULL,
+    adjusted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_tax_lots_quantity CHECK (quantity > 0),
+    CONSTRAINT chk_tax_lots_cost_basis CHECK (cost_basis >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_lots_user ON tax_lots(user_id, symbol, acquired_at);
+
+-- Admin corrections to tax lots, with the lot before and after
+CREATE TABLE IF NOT EXISTS tax_lot_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    lot_id UUID NOT NULL REFERENCES tax_lots(id) ON DELETE CASCADE,
+    admin_id UUID NOT NULL REFERENCES users(id),
+    before JSONB NOT NULL,
+    after JSONB NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_lot_adjustments_lot ON tax_lot_adjustments(lot_id, created_at);
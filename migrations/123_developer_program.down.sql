@@ -0,0 +1,16 @@
+-- Per-key rows would collide under the old primary key
+DELETE FROM api_usage_rollups WHERE api_key_id <> '00000000-0000-0000-0000-000000000000';
+DROP INDEX IF EXISTS idx_api_usage_rollups_api_key;
+ALTER TABLE api_usage_rollups DROP CONSTRAINT IF EXISTS api_usage_rollups_pkey;
+ALTER TABLE api_usage_rollups ADD PRIMARY KEY (bucket_start, user_id, method, route);
+ALTER TABLE api_usage_rollups DROP COLUMN IF EXISTS api_key_id;
+
+DROP INDEX IF EXISTS idx_webhook_endpoints_tenant;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS tenant_id;
+
+DELETE FROM api_keys WHERE tenant_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_api_keys_tenant;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS environment;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS developer_tenants;
@@ -0,0 +1,30 @@
+-- Partner developers building against the public API. Each gets a sandbox
+-- tenant that owns their self-serve API keys and webhook endpoints.
+CREATE TABLE IF NOT EXISTS developer_tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    environment VARCHAR(20) NOT NULL DEFAULT 'sandbox',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Keys issued through the developer portal belong to a tenant and only work
+-- in its environment. Existing keys are live keys.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES developer_tenants(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS environment VARCHAR(20) NOT NULL DEFAULT 'live';
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys(tenant_id) WHERE tenant_id IS NOT NULL;
+
+-- Tenant endpoints only receive test events, never production ones
+ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES developer_tenants(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_tenant ON webhook_endpoints(tenant_id) WHERE tenant_id IS NOT NULL;
+
+-- Usage is kept per API key as well as per user. Requests without a key are
+-- recorded under the nil key ID.
+ALTER TABLE api_usage_rollups ADD COLUMN IF NOT EXISTS api_key_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE api_usage_rollups DROP CONSTRAINT IF EXISTS api_usage_rollups_pkey;
+ALTER TABLE api_usage_rollups ADD PRIMARY KEY (bucket_start, user_id, api_key_id, method, route);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_rollups_api_key ON api_usage_rollups(api_key_id, bucket_start)
+    WHERE api_key_id <> '00000000-0000-0000-0000-000000000000';
@@ -0,0 +1,7 @@
+-- Submissions tokenized while the vault was in use lose their personal info
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_pii_token;
+ALTER TABLE kyc_submissions DROP COLUMN IF EXISTS pii_token;
+
+DROP TABLE IF EXISTS pii_vault.access_log;
+DROP TABLE IF EXISTS pii_vault.records;
+DROP SCHEMA IF EXISTS pii_vault;
@@ -0,0 +1,33 @@
+-- Raw KYC personal info and document references live in their own schema,
+-- encrypted under the vault's keys. The main tables keep only a token.
+-- There are deliberately no foreign keys into the main schema so the vault
+-- can be moved to its own database.
+CREATE SCHEMA IF NOT EXISTS pii_vault;
+
+CREATE TABLE IF NOT EXISTS pii_vault.records (
+    token VARCHAR(64) PRIMARY KEY,
+    subject_id UUID NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    ciphertext TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pii_vault_records_subject ON pii_vault.records(subject_id);
+
+-- Every store, reveal and refused access, with who asked and why
+CREATE TABLE IF NOT EXISTS pii_vault.access_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token VARCHAR(64) NOT NULL,
+    subject_id UUID,
+    kind VARCHAR(50),
+    accessor VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    purpose VARCHAR(200) NOT NULL DEFAULT '',
+    accessed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pii_vault_access_log_token ON pii_vault.access_log(token, accessed_at DESC);
+
+ALTER TABLE kyc_submissions ADD COLUMN IF NOT EXISTS pii_token VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_pii_token VARCHAR(64);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bank_credits;
+DROP TABLE IF EXISTS deposit_references;
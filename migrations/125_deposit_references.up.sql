@@ -0,0 +1,37 @@
+-- Shared virtual accounts receive every user's deposits into one account
+-- number, so credits are attributed by a per-user reference code that the
+-- sender puts in the transfer memo.
+CREATE TABLE IF NOT EXISTS deposit_references (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Credits received into shared accounts. Credits without a usable reference
+-- stay unmatched until an admin attributes or returns them.
+CREATE TABLE IF NOT EXISTS bank_credits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider_ref VARCHAR(255) NOT NULL UNIQUE,
+    account_number VARCHAR(50) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    amount DECIMAL(36, 18) NOT NULL CHECK (amount > 0),
+    memo TEXT NOT NULL DEFAULT '',
+    sender_name VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched'
+        CHECK (status IN ('unmatched', 'crediting', 'credited', 'returned')),
+    user_id UUID REFERENCES users(id),
+    reference VARCHAR(20),
+    match_method VARCHAR(20) CHECK (match_method IN ('reference', 'manual')),
+    unmatched_reason VARCHAR(50),
+    matched_by UUID,
+    matched_at TIMESTAMP WITH TIME ZONE,
+    note TEXT,
+    ledger_transaction_id UUID,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_credits_unmatched ON bank_credits(received_at) WHERE status = 'unmatched';
+CREATE INDEX IF NOT EXISTS idx_bank_credits_user ON bank_credits(user_id, received_at DESC);
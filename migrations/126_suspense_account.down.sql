@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS suspense_items;
+
+DELETE FROM ledger_accounts WHERE account_type = 'system_suspense' AND balance = 0;
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational',
+        'system_promotions', 'system_fee_revenue');
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance', 'fiat_exposure', 'pending_investment', 'held_balance', 'promotional_credit',
+    'system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions',
+    'system_fee_revenue'
+));
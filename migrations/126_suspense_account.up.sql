@@ -0,0 +1,55 @@
+-- Suspense ledger account for funds that arrived but cannot be attributed to
+-- a user: bank credits without a usable reference and chain deposits to
+-- addresses we do not recognise
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance',
+    'fiat_exposure',
+    'pending_investment',
+    'held_balance',
+    'promotional_credit',
+    'system_buffer_usdc',
+    'system_buffer_fiat',
+    'broker_operational',
+    'system_promotions',
+    'system_fee_revenue',
+    'system_suspense'         -- Funds received that are not yet attributed to a user
+));
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational',
+        'system_promotions', 'system_fee_revenue', 'system_suspense');
+
+INSERT INTO ledger_accounts (id, user_id, account_type, currency, balance) VALUES
+    (uuid_generate_v4(), NULL, 'system_suspense', 'USD', 0)
+ON CONFLICT DO NOTHING;
+
+-- The admin queue of unattributed funds, whatever rail they arrived on. Each
+-- item is held in the suspense account until it is assigned to a user or
+-- refunded to the sender.
+CREATE TABLE IF NOT EXISTS suspense_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('bank_credit', 'chain_deposit')),
+    source_ref VARCHAR(255) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    amount DECIMAL(36, 18) NOT NULL CHECK (amount > 0),
+    usd_amount DECIMAL(36, 18) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'resolving', 'assigned', 'refunded')),
+    user_id UUID REFERENCES users(id),
+    resolved_by UUID,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    note TEXT,
+    hold_ledger_transaction_id UUID,
+    release_ledger_transaction_id UUID,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One item per unattributed transfer, so redelivered notifications are idempotent
+CREATE UNIQUE INDEX IF NOT EXISTS uq_suspense_items_source ON suspense_items(source, source_ref);
+CREATE INDEX IF NOT EXISTS idx_suspense_items_open ON suspense_items(received_at) WHERE status = 'open';
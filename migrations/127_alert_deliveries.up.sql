@@ -0,0 +1,23 @@
+-- Alerts routed to on-call channels, one row per channel an alert was sent
+-- to, with whether the channel confirmed it. Recent confirmed rows also
+-- suppress repeats of the same dedup key.
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source VARCHAR(50) NOT NULL,
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('critical', 'warning', 'info')),
+    dedup_key VARCHAR(255) NOT NULL,
+    summary TEXT NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('pagerduty', 'slack', 'webhook')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('delivered', 'failed')),
+    business_hours BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    provider_ref VARCHAR(255),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_dedup ON alert_deliveries(dedup_key, channel, delivered_at)
+    WHERE status = 'delivered';
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_created_at ON alert_deliveries(created_at DESC);
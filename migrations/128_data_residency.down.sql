@@ -0,0 +1,3 @@
+ALTER TABLE documents DROP COLUMN IF EXISTS storage_region;
+ALTER TABLE uploads DROP COLUMN IF EXISTS storage_region;
+DROP TABLE IF EXISTS user_residency;
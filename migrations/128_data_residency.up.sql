@@ -0,0 +1,16 @@
+-- The region each user's KYC files and statements must be stored in. Users
+-- without a row are kept in the region their country maps to.
+CREATE TABLE IF NOT EXISTS user_residency (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    region VARCHAR(16) NOT NULL CHECK (region IN ('us', 'eu')),
+    source VARCHAR(16) NOT NULL CHECK (source IN ('country', 'admin')),
+    country_code VARCHAR(10) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    set_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Where each upload and document is stored. NULL marks rows written before
+-- residency tagging, which are in the deployment's home region.
+ALTER TABLE uploads ADD COLUMN IF NOT EXISTS storage_region VARCHAR(16);
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS storage_region VARCHAR(16);
@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS reward_redemptions;
+DROP TABLE IF EXISTS rewards;
+DROP TABLE IF EXISTS reward_rules;
+
+DELETE FROM ledger_accounts WHERE account_type = 'system_rewards' AND balance = 0;
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit', 'withdrawal', 'investment', 'conversion',
+    'internal_transfer', 'buffer_replenishment', 'reversal',
+    'promotion', 'promotion_clawback', 'subscription_fee', 'balance_hold', 'adjustment'
+));
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational',
+        'system_promotions', 'system_fee_revenue', 'system_suspense');
+
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance', 'fiat_exposure', 'pending_investment', 'held_balance', 'promotional_credit',
+    'system_buffer_usdc', 'system_buffer_fiat', 'broker_operational', 'system_promotions',
+    'system_fee_revenue', 'system_suspense'
+));
@@ -0,0 +1,109 @@
+-- Rewards ledger accounts and transaction types
+ALTER TABLE ledger_accounts DROP CONSTRAINT IF EXISTS chk_account_type;
+ALTER TABLE ledger_accounts ADD CONSTRAINT chk_account_type CHECK (account_type IN (
+    'usdc_balance',
+    'fiat_exposure',
+    'pending_investment',
+    'held_balance',
+    'promotional_credit',
+    'rewards_balance',        -- User's earned rewards not yet redeemed
+    'system_buffer_usdc',
+    'system_buffer_fiat',
+    'broker_operational',
+    'system_promotions',
+    'system_fee_revenue',
+    'system_suspense',
+    'system_rewards'          -- Budget that funds rewards
+));
+
+DROP INDEX IF EXISTS idx_ledger_accounts_system_type;
+CREATE UNIQUE INDEX idx_ledger_accounts_system_type ON ledger_accounts(account_type)
+    WHERE user_id IS NULL AND account_type IN ('system_buffer_usdc', 'system_buffer_fiat', 'broker_operational',
+        'system_promotions', 'system_fee_revenue', 'system_suspense', 'system_rewards');
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE ledger_transactions ADD CONSTRAINT chk_transaction_type CHECK (transaction_type IN (
+    'deposit',
+    'withdrawal',
+    'investment',
+    'conversion',
+    'internal_transfer',
+    'buffer_replenishment',
+    'reversal',
+    'promotion',
+    'promotion_clawback',
+    'subscription_fee',
+    'balance_hold',
+    'adjustment',
+    'reward',                 -- Reward earned or redeemed into an investment
+    'reward_clawback'         -- Unredeemed reward forfeited back to the budget
+));
+
+-- The budget starts empty; treasury funds it before rules go live
+INSERT INTO ledger_accounts (id, user_id, account_type, currency, balance) VALUES
+    (uuid_generate_v4(), NULL, 'system_rewards', 'USDC', 0)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS reward_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    trigger VARCHAR(30) NOT NULL CHECK (trigger IN ('investment', 'recurring_investment')),
+    unit VARCHAR(10) NOT NULL CHECK (unit IN ('cash', 'points')),
+    rate DECIMAL(10, 6) NOT NULL CHECK (rate > 0 AND rate <= 1),
+    min_order_amount DECIMAL(36, 18) NOT NULL DEFAULT 0 CHECK (min_order_amount >= 0),
+    max_per_order DECIMAL(36, 18) CHECK (max_per_order > 0),
+    hold_days INTEGER NOT NULL DEFAULT 0 CHECK (hold_days >= 0),
+    active BOOLEAN NOT NULL DEFAULT true,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_rules_trigger ON reward_rules(trigger) WHERE active = true;
+
+CREATE TABLE IF NOT EXISTS rewards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rule_id UUID NOT NULL REFERENCES reward_rules(id),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL,
+    basket_id UUID NOT NULL,
+    unit VARCHAR(10) NOT NULL,
+    amount DECIMAL(36, 18) NOT NULL CHECK (amount > 0),
+    points BIGINT,
+    status VARCHAR(20) NOT NULL DEFAULT 'accrued' CHECK (status IN ('accrued', 'held', 'forfeited')),
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    risk_score DECIMAL(10, 4) NOT NULL DEFAULT 0,
+    hold_reason TEXT,
+    ledger_tx_id UUID REFERENCES ledger_transactions(id),
+    clawback_ledger_tx_id UUID REFERENCES ledger_transactions(id),
+    forfeit_reason TEXT,
+    reviewed_by UUID REFERENCES users(id),
+    settled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- A redelivered fill earns nothing more
+    UNIQUE (rule_id, order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rewards_user ON rewards(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rewards_basket ON rewards(user_id, basket_id) WHERE status <> 'forfeited';
+CREATE INDEX IF NOT EXISTS idx_rewards_held ON rewards(created_at) WHERE status = 'held';
+
+CREATE TABLE IF NOT EXISTS reward_redemptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    basket_id UUID NOT NULL,
+    amount DECIMAL(36, 18) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    order_id UUID,
+    ledger_tx_id UUID REFERENCES ledger_transactions(id),
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_redemptions_user ON reward_redemptions(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_reward_redemptions_order ON reward_redemptions(order_id) WHERE order_id IS NOT NULL;
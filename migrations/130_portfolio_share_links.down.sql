@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS portfolio_sharing_settings;
+DROP TABLE IF EXISTS portfolio_share_links;
@@ -0,0 +1,22 @@
+-- Public links to frozen, amount-free snapshots of portfolio performance
+CREATE TABLE IF NOT EXISTS portfolio_share_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    range VARCHAR(5) NOT NULL,
+    snapshot JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_share_links_user ON portfolio_share_links(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_portfolio_share_links_live ON portfolio_share_links(user_id, expires_at) WHERE revoked_at IS NULL;
+
+-- Users without a row have sharing turned on
+CREATE TABLE IF NOT EXISTS portfolio_sharing_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
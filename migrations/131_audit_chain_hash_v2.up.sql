@@ -0,0 +1,7 @@
+-- Version 2 entry hashes cover every stored column of an audit entry.
+-- Entries already chained keep version 1 and are verified under it.
+ALTER TABLE audit_logs
+ADD COLUMN IF NOT EXISTS hash_version SMALLINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN audit_logs.hash_version IS 'Scheme entry_hash was computed with: 1 covers selected columns, 2 every column';
+COMMENT ON COLUMN audit_logs.entry_hash IS 'SHA-256 over prev_hash and the entry content, under hash_version';
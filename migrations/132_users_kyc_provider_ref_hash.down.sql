@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_kyc_provider_ref_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_provider_ref_hash;
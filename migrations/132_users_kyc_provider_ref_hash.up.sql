@@ -0,0 +1,8 @@
+-- KYC provider references are encrypted with randomized ciphertexts, so
+-- webhooks that name a reference are matched through a blind index instead.
+-- The field rotation job backfills it for existing rows.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_provider_ref_hash VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_users_kyc_provider_ref_hash
+    ON users(kyc_provider_ref_hash) WHERE kyc_provider_ref_hash IS NOT NULL;
+
+COMMENT ON COLUMN users.kyc_provider_ref_hash IS 'HMAC-SHA256 blind index of the KYC provider reference';
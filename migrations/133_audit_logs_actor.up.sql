@@ -0,0 +1,6 @@
+-- The service that wrote an audit entry, kept apart from its action and
+-- covered by version 2 entry hashes. Entries written before it are NULL.
+ALTER TABLE audit_logs
+ADD COLUMN IF NOT EXISTS actor VARCHAR(100);
+
+COMMENT ON COLUMN audit_logs.actor IS 'Service or worker that recorded the entry';
@@ -16,11 +16,13 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// TokenPair represents access and refresh tokens
+// TokenPair represents access and refresh tokens. Both tokens carry the
+// session ID as their jti, which stays the same across refreshes.
 type TokenPair struct {
 	AccessToken  string    `json:"access_token"`
 	RefreshToken string    `json:"refresh_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	SessionID    string    `json:"-"`
 }
 
 // GenerateTokenPair generates a new JWT token pair
@@ -28,6 +30,7 @@ func GenerateTokenPair(userID uuid.UUID, email, role, secret string, accessTTL,
 	now := time.Now()
 	accessExp := now.Add(time.Duration(accessTTL) * time.Second)
 	refreshExp := now.Add(time.Duration(refreshTTL) * time.Second)
+	sessionID := uuid.New().String()
 
 	// Access token claims
 	accessClaims := Claims{
@@ -40,6 +43,7 @@ func GenerateTokenPair(userID uuid.UUID, email, role, secret string, accessTTL,
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "stack_service",
 			Subject:   userID.String(),
+			ID:        sessionID,
 		},
 	}
 
@@ -50,6 +54,7 @@ func GenerateTokenPair(userID uuid.UUID, email, role, secret string, accessTTL,
 		NotBefore: jwt.NewNumericDate(now),
 		Issuer:    "stack_service",
 		Subject:   userID.String(),
+		ID:        sessionID,
 	}
 
 	// Create access token
@@ -70,6 +75,7 @@ func GenerateTokenPair(userID uuid.UUID, email, role, secret string, accessTTL,
 		AccessToken:  accessTokenString,
 		RefreshToken: refreshTokenString,
 		ExpiresAt:    accessExp,
+		SessionID:    sessionID,
 	}, nil
 }
 
@@ -126,6 +132,7 @@ func RefreshAccessToken(refreshToken, secret string, accessTTL int) (*TokenPair,
 				NotBefore: jwt.NewNumericDate(now),
 				Issuer:    "stack_service",
 				Subject:   userID.String(),
+				ID:        claims.ID,
 			},
 		}
 
@@ -139,12 +146,31 @@ func RefreshAccessToken(refreshToken, secret string, accessTTL int) (*TokenPair,
 			AccessToken:  accessTokenString,
 			RefreshToken: refreshToken, // Return the same refresh token
 			ExpiresAt:    accessExp,
+			SessionID:    claims.ID,
 		}, nil
 	}
 
 	return nil, fmt.Errorf("invalid refresh token")
 }
 
+// SessionID validates an access or refresh token and returns its session ID
+func SessionID(tokenString, secret string) (string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse token: %w", err)
+	}
+	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	if !ok || !token.Valid || claims.ID == "" {
+		return "", fmt.Errorf("token has no session")
+	}
+	return claims.ID, nil
+}
+
 // ExtractUserIDFromToken extracts user ID from token without full validation
 func ExtractUserIDFromToken(tokenString string) (uuid.UUID, error) {
 	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &Claims{})
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers carrying an HMAC request signature. Internal services sign their
+// calls with a key ID and secret issued per tenant and environment instead of
+// presenting a user JWT.
+const (
+	SigningKeyIDHeader     = "X-Signing-Key-Id"
+	SigningTimestampHeader = "X-Signing-Timestamp"
+	SigningNonceHeader     = "X-Signing-Nonce"
+	SignatureHeader        = "X-Signature"
+
+	signatureVersion = "v1"
+)
+
+// CanonicalRequest is the string a request signature covers: the signature
+// version, timestamp, nonce, method, path with query and the SHA-256 of the
+// body, one per line
+func CanonicalRequest(method, path, rawQuery string, timestamp int64, nonce string, body []byte) string {
+	target := path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		signatureVersion,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		strings.ToUpper(method),
+		target,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// ComputeSignature returns the versioned HMAC-SHA256 of a canonical request
+func ComputeSignature(secret, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature is the secret's signature of the
+// canonical request, comparing in constant time
+func VerifySignature(secret, canonical, signature string) bool {
+	return hmac.Equal([]byte(ComputeSignature(secret, canonical)), []byte(signature))
+}
+
+// SignRequest signs an outgoing request with the key, setting the signing
+// headers. The body is read and replaced so the request can still be sent.
+func SignRequest(req *http.Request, keyID, secret string, now time.Time) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	timestamp := now.Unix()
+
+	canonical := CanonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, timestamp, nonce, body)
+	req.Header.Set(SigningKeyIDHeader, keyID)
+	req.Header.Set(SigningTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SigningNonceHeader, nonce)
+	req.Header.Set(SignatureHeader, ComputeSignature(secret, canonical))
+	return nil
+}
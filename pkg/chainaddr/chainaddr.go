@@ -0,0 +1,155 @@
+// Package chainaddr validates and normalizes blockchain addresses. EVM
+// addresses are checked against their EIP-55 checksum when they carry one,
+// Solana addresses must decode to a 32-byte public key and Aptos addresses
+// follow AIP-40.
+package chainaddr
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+// Format is an address format shared by a family of chains
+type Format string
+
+const (
+	FormatEVM    Format = "evm"
+	FormatSolana Format = "solana"
+	FormatAptos  Format = "aptos"
+)
+
+var (
+	// ErrEmpty is returned for a blank address
+	ErrEmpty = errors.New("address is empty")
+	// ErrMalformed is returned for an address that is not in the format
+	ErrMalformed = errors.New("address is malformed")
+	// ErrChecksum is returned for a mixed-case EVM address whose case does
+	// not match its EIP-55 checksum, which usually means a mistyped character
+	ErrChecksum = errors.New("address checksum does not match")
+	// ErrUnknownFormat is returned when asked to validate an unknown format
+	ErrUnknownFormat = errors.New("unknown address format")
+)
+
+// Validate checks address against the format and returns it in canonical
+// form: checksummed for EVM, unchanged for Solana and long-form lower case
+// for Aptos. The returned error wraps one of the package's errors.
+func Validate(format Format, address string) (string, error) {
+	switch format {
+	case FormatEVM:
+		return ValidateEVM(address)
+	case FormatSolana:
+		return ValidateSolana(address)
+	case FormatAptos:
+		return ValidateAptos(address)
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
+
+// Valid reports whether address is well formed in the format
+func Valid(format Format, address string) bool {
+	_, err := Validate(format, address)
+	return err == nil
+}
+
+// ValidateEVM checks a 0x-prefixed 20-byte hex address. All-lower and
+// all-upper case addresses carry no checksum and are accepted as they are;
+// mixed case must match EIP-55.
+func ValidateEVM(address string) (string, error) {
+	if address == "" {
+		return "", ErrEmpty
+	}
+	digits, ok := strings.CutPrefix(address, "0x")
+	if !ok || len(digits) != 40 || !isHex(digits) {
+		return "", fmt.Errorf("%w: expected 0x followed by 40 hex digits", ErrMalformed)
+	}
+	checksummed := ChecksumEVM(address)
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && address != checksummed {
+		return "", ErrChecksum
+	}
+	return checksummed, nil
+}
+
+// ChecksumEVM returns the EIP-55 mixed-case form of a well-formed EVM
+// address. Upper-case hex digits are those whose nibble in the Keccak-256
+// hash of the lower-case address is 8 or more.
+func ChecksumEVM(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(address, "0x"))
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// ValidateSolana checks a base58 address that decodes to an ed25519 public
+// key. Program-derived addresses are off the curve but the same size, so
+// the key itself is not checked.
+func ValidateSolana(address string) (string, error) {
+	if address == "" {
+		return "", ErrEmpty
+	}
+	if len(address) < 32 || len(address) > 44 {
+		return "", fmt.Errorf("%w: expected 32 to 44 base58 characters", ErrMalformed)
+	}
+	key, err := crypto.DecodeBase58(address)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if len(key) != 32 {
+		return "", fmt.Errorf("%w: decodes to %d bytes, expected 32", ErrMalformed, len(key))
+	}
+	return address, nil
+}
+
+// ValidateAptos checks a 0x-prefixed 32-byte hex address. As AIP-40 allows,
+// the special addresses 0x0 to 0xf may be written short; every other
+// address must be written in full.
+func ValidateAptos(address string) (string, error) {
+	if address == "" {
+		return "", ErrEmpty
+	}
+	digits, ok := strings.CutPrefix(address, "0x")
+	if !ok || !isHex(digits) {
+		return "", fmt.Errorf("%w: expected 0x followed by hex digits", ErrMalformed)
+	}
+	digits = strings.ToLower(digits)
+	switch {
+	case len(digits) == 64:
+		if special := strings.TrimLeft(digits, "0"); len(special) <= 1 {
+			if special == "" {
+				special = "0"
+			}
+			return "0x" + special, nil
+		}
+		return "0x" + digits, nil
+	case len(digits) == 1:
+		return "0x" + digits, nil
+	default:
+		return "", fmt.Errorf("%w: expected 0x followed by 64 hex digits", ErrMalformed)
+	}
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -20,6 +20,19 @@ const (
 	StateOpen
 )
 
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
 type Config struct {
 	MaxRequests       uint32
 	Interval          time.Duration
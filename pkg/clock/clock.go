@@ -0,0 +1,22 @@
+// Package clock abstracts the current time so schedule-dependent logic can be
+// tested without waiting for real time to pass.
+package clock
+
+import "time"
+
+// Clock tells the current time. Services take its Now method through their
+// SetClock setters; tests pass a clocktest.Fake instead of the system clock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System returns the clock backed by time.Now
+func System() Clock {
+	return systemClock{}
+}
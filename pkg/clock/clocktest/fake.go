@@ -0,0 +1,42 @@
+// Package clocktest provides a clock tests can set and move forward.
+package clocktest
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a clock that only moves when told to. It is safe for concurrent
+// use, so a service's background goroutine can read it while the test
+// advances it.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake returns a clock stopped at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake's current time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set moves the clock to now, which may be in the past
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance moves the clock forward by d and returns the new time
+func (f *Fake) Advance(d time.Duration) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	return f.now
+}
@@ -0,0 +1,263 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// fieldPrefix marks a value produced by FieldEncryptor. Values without
+	// either prefix are treated as legacy plaintext so existing rows keep
+	// working until rotated.
+	fieldPrefix = "enc:v"
+	// boundFieldPrefix marks a value sealed with the table, column and row it
+	// is stored in as additional data.
+	boundFieldPrefix = "enc:b"
+)
+
+// FieldKeySource supplies versioned data keys for field-level encryption.
+// Implementations are expected to read from the secrets manager; the active
+// version is used for new writes while older versions remain available for reads.
+type FieldKeySource interface {
+	FieldKeys() (keys map[int][]byte, activeVersion int, err error)
+}
+
+// StaticFieldKeySource is a FieldKeySource backed by an in-memory key map
+type StaticFieldKeySource struct {
+	Keys          map[int][]byte
+	ActiveVersion int
+}
+
+// FieldKeys returns the configured keys
+func (s StaticFieldKeySource) FieldKeys() (map[int][]byte, int, error) {
+	return s.Keys, s.ActiveVersion, nil
+}
+
+// ParseFieldKeys parses a keyring of the form "1:<secret>,2:<secret>". Each
+// secret is stretched to 32 bytes with SHA-256 so operators can supply any
+// high-entropy string.
+func ParseFieldKeys(spec string) (map[int][]byte, error) {
+	keys := make(map[int][]byte)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid field key entry %q", entry)
+		}
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid field key version %q", parts[0])
+		}
+		if _, exists := keys[version]; exists {
+			return nil, fmt.Errorf("duplicate field key version %d", version)
+		}
+		keys[version] = DeriveFieldKey(parts[1])
+	}
+	return keys, nil
+}
+
+// DeriveFieldKey stretches a secret into a 32-byte AES-256 key
+func DeriveFieldKey(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// FieldEncryptor encrypts individual column values with AES-GCM. Ciphertexts
+// carry the key version ("enc:b2:<base64>" for values bound to their row,
+// "enc:v2:<base64>" otherwise) so rows written under a retired key can still
+// be decrypted and re-encrypted by the rotation job.
+type FieldEncryptor struct {
+	aeads    map[int]cipher.AEAD
+	active   int
+	indexKey []byte
+}
+
+// NewFieldEncryptor builds an encryptor from a key source. indexKey is used for
+// blind indexes and must stay stable across data key rotations.
+func NewFieldEncryptor(source FieldKeySource, indexKey []byte) (*FieldEncryptor, error) {
+	keys, active, err := source.FieldKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load field encryption keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no field encryption keys configured")
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active field key version %d not found", active)
+	}
+	if len(indexKey) == 0 {
+		return nil, fmt.Errorf("blind index key is required")
+	}
+
+	aeads := make(map[int]cipher.AEAD, len(keys))
+	for version, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher for key v%d: %w", version, err)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCM for key v%d: %w", version, err)
+		}
+		aeads[version] = gcm
+	}
+
+	return &FieldEncryptor{aeads: aeads, active: active, indexKey: indexKey}, nil
+}
+
+// ActiveVersion returns the key version used for new writes
+func (e *FieldEncryptor) ActiveVersion() int {
+	return e.active
+}
+
+// Versions returns all loaded key versions in ascending order
+func (e *FieldEncryptor) Versions() []int {
+	versions := make([]int, 0, len(e.aeads))
+	for v := range e.aeads {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Encrypt encrypts a value with the active key. Empty strings are stored as-is.
+// Values stored in a table row should use EncryptField instead.
+func (e *FieldEncryptor) Encrypt(plaintext string) (string, error) {
+	return e.seal(fieldPrefix, plaintext, nil)
+}
+
+// EncryptField encrypts a value stored in a table column, bound to the table,
+// column and row so the ciphertext cannot be moved to another row or column
+// and still decrypt there.
+func (e *FieldEncryptor) EncryptField(plaintext, table, column, rowID string) (string, error) {
+	return e.seal(boundFieldPrefix, plaintext, FieldAAD(table, column, rowID))
+}
+
+func (e *FieldEncryptor) seal(prefix, plaintext string, aad []byte) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	gcm := e.aeads[e.active]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to create nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), aad)
+	return fmt.Sprintf("%s%d:%s", prefix, e.active, base64.RawStdEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values without the encryption
+// prefix are returned unchanged so legacy plaintext rows remain readable.
+func (e *FieldEncryptor) Decrypt(value string) (string, error) {
+	if strings.HasPrefix(value, boundFieldPrefix) {
+		return "", fmt.Errorf("field value is bound to a table row")
+	}
+	return e.open(value, nil)
+}
+
+// DecryptField decrypts a value produced by EncryptField for the same table,
+// column and row. Values sealed by Encrypt before fields were bound to their
+// rows, and legacy plaintext, are still read until the rotation job rewrites
+// them.
+func (e *FieldEncryptor) DecryptField(value, table, column, rowID string) (string, error) {
+	if !strings.HasPrefix(value, boundFieldPrefix) {
+		return e.open(value, nil)
+	}
+	return e.open(value, FieldAAD(table, column, rowID))
+}
+
+func (e *FieldEncryptor) open(value string, aad []byte) (string, error) {
+	version, payload, ok := splitFieldCiphertext(value)
+	if !ok {
+		return value, nil
+	}
+	gcm, exists := e.aeads[version]
+	if !exists {
+		return "", fmt.Errorf("field key version %d not available", version)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored column value is plaintext, sealed
+// without being bound to its row, or encrypted under a key other than the
+// active one.
+func (e *FieldEncryptor) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	version, _, ok := splitFieldCiphertext(value)
+	return !ok || !strings.HasPrefix(value, boundFieldPrefix) || version != e.active
+}
+
+// FieldAAD is the additional data a column value is sealed with: the table,
+// column and row ID, each length-prefixed so no two locations encode alike
+func FieldAAD(table, column, rowID string) []byte {
+	var aad []byte
+	for _, part := range []string{table, column, rowID} {
+		aad = strconv.AppendInt(aad, int64(len(part)), 10)
+		aad = append(aad, ':')
+		aad = append(aad, part...)
+		aad = append(aad, ';')
+	}
+	return aad
+}
+
+// BlindIndex returns a deterministic keyed hash of a value so encrypted columns
+// can still be looked up by equality.
+func (e *FieldEncryptor) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, e.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsFieldEncrypted reports whether a stored value carries the field encryption prefix
+func IsFieldEncrypted(value string) bool {
+	_, _, ok := splitFieldCiphertext(value)
+	return ok
+}
+
+func splitFieldCiphertext(value string) (int, string, bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(value, fieldPrefix):
+		rest = value[len(fieldPrefix):]
+	case strings.HasPrefix(value, boundFieldPrefix):
+		rest = value[len(boundFieldPrefix):]
+	default:
+		return 0, "", false
+	}
+	sep := strings.IndexByte(rest, ':')
+	if sep <= 0 {
+		return 0, "", false
+	}
+	version, err := strconv.Atoi(rest[:sep])
+	if err != nil {
+		return 0, "", false
+	}
+	return version, rest[sep+1:], true
+}
@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/sha3"
+)
+
+// VerifyEVMSignature reports whether signature is an EIP-191 personal_sign
+// signature of message by the key behind address. The signature is the
+// 65-byte r||s||v value wallets return, hex encoded.
+func VerifyEVMSignature(address, message, signature string) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil || len(sig) != 65 {
+		return false
+	}
+	recovered, err := RecoverEVMAddress(message, sig)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(recovered, address)
+}
+
+// RecoverEVMAddress returns the address that produced a personal_sign
+// signature of message
+func RecoverEVMAddress(message string, sig []byte) (string, error) {
+	if len(sig) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes, got %d", len(sig))
+	}
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", fmt.Errorf("invalid recovery id %d", sig[64])
+	}
+
+	// Compact signatures carry the recovery code first, offset by 27 for an
+	// uncompressed key
+	compact := append([]byte{27 + v}, sig[:64]...)
+	prefixed := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
+	pub, _, err := ecdsa.RecoverCompact(compact, keccak256([]byte(prefixed)))
+	if err != nil {
+		return "", err
+	}
+	// The address is the last 20 bytes of the hash of X||Y, without the 0x04 prefix
+	return "0x" + hex.EncodeToString(keccak256(pub.SerializeUncompressed()[1:])[12:]), nil
+}
+
+// VerifySolanaSignature reports whether signature is an ed25519 signature of
+// message by the base58 address. Wallets return signatures base58 encoded;
+// base64 is accepted as well.
+func VerifySolanaSignature(address, message, signature string) bool {
+	pub, err := DecodeBase58(address)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := DecodeBase58(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(signature); err != nil || len(sig) != ed25519.SignatureSize {
+			return false
+		}
+	}
+	return ed25519.Verify(pub, []byte(message), sig)
+}
+
+// DecodeBase58 decodes a Bitcoin-alphabet base58 string
+func DecodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty base58 string")
+	}
+	return base58.Decode(s)
+}
+
+// EncodeBase58 encodes bytes with the Bitcoin base58 alphabet
+func EncodeBase58(b []byte) string {
+	return base58.Encode(b)
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
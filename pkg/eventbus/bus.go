@@ -0,0 +1,156 @@
+// Package eventbus is the internal publish/subscribe abstraction used to fan
+// domain events out to asynchronous consumers.
+//
+// Delivery semantics, which consumers must be written against:
+//
+//   - At-least-once. A handler may see the same message more than once (a
+//     retry after a handler error, a broker redelivery, a crash before the
+//     offset was committed). Message.ID is stable across redeliveries, so
+//     handlers dedupe on it or are naturally idempotent.
+//   - Ordering is only guaranteed per key. Messages published with the same
+//     key on the same topic reach a given consumer group in publish order;
+//     there is no ordering across keys or topics. Publishers key by the
+//     aggregate that needs ordering, usually the user ID.
+//   - A handler that keeps failing is retried MaxAttempts times with
+//     exponential backoff and then dropped with an error log. Durable state
+//     (deposits, jobs, webhook deliveries) lives in Postgres, so a bus message
+//     is a trigger, never the only record of an event.
+//   - The in-process driver is lost on restart; the NATS driver uses core NATS
+//     and is at-most-once across a broker disconnect. Use the Kafka driver when
+//     consumers must survive restarts without a Postgres fallback.
+//
+// Every distinct group passed to Subscribe receives every message on the
+// topic. Subscribers sharing a group across instances split the work (NATS
+// queue groups, Kafka consumer groups).
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/retry"
+)
+
+// ErrClosed is returned when publishing or subscribing on a closed bus
+var ErrClosed = errors.New("event bus is closed")
+
+// Drivers selectable through Config.Driver
+const (
+	DriverInProcess = "inprocess"
+	DriverNATS      = "nats"
+	DriverKafka     = "kafka"
+)
+
+// Message is the envelope carried on every topic
+type Message struct {
+	ID          string          `json:"id"`
+	Topic       string          `json:"topic"`
+	Key         string          `json:"key"`
+	Payload     json.RawMessage `json:"payload"`
+	PublishedAt time.Time       `json:"published_at"`
+}
+
+// Decode unmarshals the payload into v
+func (m *Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+// Handler consumes one message. Returning an error triggers a retry.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Bus publishes messages to topics and delivers them to subscribed handlers
+type Bus interface {
+	Publish(ctx context.Context, topic, key string, payload interface{}) error
+	Subscribe(topic, group string, handler Handler) error
+	Close() error
+}
+
+// Config selects and tunes a bus implementation
+type Config struct {
+	Driver         string
+	NATSURL        string
+	KafkaRESTURL   string
+	TopicPrefix    string
+	MaxAttempts    int
+	InitialBackoff time.Duration
+}
+
+// New builds the bus selected by cfg.Driver
+func New(cfg Config, logger *zap.Logger) (Bus, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 500 * time.Millisecond
+	}
+
+	switch strings.ToLower(cfg.Driver) {
+	case "", DriverInProcess:
+		return NewInProcessBus(cfg, logger), nil
+	case DriverNATS:
+		return NewNATSBus(cfg, logger)
+	case DriverKafka:
+		return NewKafkaRESTBus(cfg, logger)
+	default:
+		return nil, fmt.Errorf("unknown event bus driver %q", cfg.Driver)
+	}
+}
+
+func newMessage(topic, key string, payload interface{}) (*Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", topic, err)
+	}
+	return &Message{
+		ID:          uuid.New().String(),
+		Topic:       topic,
+		Key:         key,
+		Payload:     data,
+		PublishedAt: time.Now().UTC(),
+	}, nil
+}
+
+// deliver runs handler with retries and panic recovery. It returns the last
+// error once attempts are exhausted so callers can decide whether to commit.
+func deliver(ctx context.Context, cfg Config, logger *zap.Logger, group string, msg *Message, handler Handler) error {
+	var err error
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		err = invoke(ctx, msg, handler)
+		if err == nil {
+			return nil
+		}
+		if attempt == cfg.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry.CalculateExponential(cfg.InitialBackoff, 2, attempt, 30*time.Second)):
+		}
+	}
+
+	logger.Error("Event handler exhausted retries; dropping message",
+		zap.String("topic", msg.Topic),
+		zap.String("group", group),
+		zap.String("message_id", msg.ID),
+		zap.String("key", msg.Key),
+		zap.Int("attempts", cfg.MaxAttempts),
+		zap.Error(err))
+	return err
+}
+
+func invoke(ctx context.Context, msg *Message, handler Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
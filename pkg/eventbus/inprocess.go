@@ -0,0 +1,112 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// inProcessBuffer bounds each subscription's queue; Publish blocks when full
+const inProcessBuffer = 1024
+
+// InProcessBus delivers messages to handlers in the same process. Each
+// subscription drains its own queue on one goroutine, so messages reach a
+// handler in publish order.
+type InProcessBus struct {
+	cfg    Config
+	logger *zap.Logger
+
+	mu     sync.RWMutex
+	subs   map[string][]*inProcessSubscription
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type inProcessSubscription struct {
+	group   string
+	handler Handler
+	queue   chan *Message
+}
+
+// NewInProcessBus creates an in-process bus
+func NewInProcessBus(cfg Config, logger *zap.Logger) *InProcessBus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &InProcessBus{
+		cfg:    cfg,
+		logger: logger,
+		subs:   make(map[string][]*inProcessSubscription),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Publish enqueues a message for every subscription on topic
+func (b *InProcessBus) Publish(ctx context.Context, topic, key string, payload interface{}) error {
+	msg, err := newMessage(topic, key, payload)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	for _, sub := range b.subs[topic] {
+		select {
+		case sub.queue <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe registers handler for topic and starts draining its queue
+func (b *InProcessBus) Subscribe(topic, group string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	sub := &inProcessSubscription{
+		group:   group,
+		handler: handler,
+		queue:   make(chan *Message, inProcessBuffer),
+	}
+	b.subs[topic] = append(b.subs[topic], sub)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for msg := range sub.queue {
+			_ = deliver(b.ctx, b.cfg, b.logger, sub.group, msg, sub.handler)
+		}
+	}()
+	return nil
+}
+
+// Close stops accepting messages and waits for queued ones to be handled
+func (b *InProcessBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, subs := range b.subs {
+		for _, sub := range subs {
+			close(sub.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	b.cancel()
+	return nil
+}
@@ -0,0 +1,237 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const kafkaJSONContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaRESTBus talks to Kafka through a Confluent-compatible REST proxy, so no
+// native client is needed in the service. Each subscription is a consumer
+// instance in the group; offsets are committed only after the handler returns,
+// which gives at-least-once delivery and per-partition (per-key) ordering.
+type KafkaRESTBus struct {
+	cfg    Config
+	base   string
+	client *http.Client
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	consumers []*kafkaConsumer
+	closed    bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type kafkaConsumer struct {
+	topic   string
+	group   string
+	baseURI string
+	handler Handler
+}
+
+type kafkaRecord struct {
+	Topic     string          `json:"topic"`
+	Key       json.RawMessage `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Partition int             `json:"partition"`
+	Offset    int64           `json:"offset"`
+}
+
+// NewKafkaRESTBus creates a bus backed by the REST proxy at cfg.KafkaRESTURL
+func NewKafkaRESTBus(cfg Config, logger *zap.Logger) (*KafkaRESTBus, error) {
+	if cfg.KafkaRESTURL == "" {
+		return nil, fmt.Errorf("kafka rest url is required for the kafka event bus")
+	}
+	if _, err := url.Parse(cfg.KafkaRESTURL); err != nil {
+		return nil, fmt.Errorf("invalid kafka rest url %q", cfg.KafkaRESTURL)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &KafkaRESTBus{
+		cfg:    cfg,
+		base:   strings.TrimRight(cfg.KafkaRESTURL, "/"),
+		client: &http.Client{Timeout: 40 * time.Second},
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Publish produces one record keyed by key
+func (b *KafkaRESTBus) Publish(ctx context.Context, topic, key string, payload interface{}) error {
+	msg, err := newMessage(topic, key, payload)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"records": []map[string]interface{}{{"key": key, "value": msg}},
+	}
+	return b.do(ctx, http.MethodPost, b.base+"/topics/"+url.PathEscape(b.topic(topic)), body, nil)
+}
+
+// Subscribe creates a consumer instance in the group and starts polling it
+func (b *KafkaRESTBus) Subscribe(topic, group string, handler Handler) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.mu.Unlock()
+
+	groupName := b.cfg.TopicPrefix + group
+	hostname, _ := os.Hostname()
+	var created struct {
+		InstanceID string `json:"instance_id"`
+		BaseURI    string `json:"base_uri"`
+	}
+	err := b.do(b.ctx, http.MethodPost, b.base+"/consumers/"+url.PathEscape(groupName), map[string]string{
+		"name":               fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8]),
+		"format":             "json",
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}, &created)
+	if err != nil {
+		return fmt.Errorf("failed to create kafka consumer: %w", err)
+	}
+
+	consumer := &kafkaConsumer{topic: b.topic(topic), group: group, baseURI: created.BaseURI, handler: handler}
+	if err := b.do(b.ctx, http.MethodPost, consumer.baseURI+"/subscription",
+		map[string][]string{"topics": {consumer.topic}}, nil); err != nil {
+		return fmt.Errorf("failed to subscribe kafka consumer: %w", err)
+	}
+
+	b.mu.Lock()
+	b.consumers = append(b.consumers, consumer)
+	b.mu.Unlock()
+
+	b.wg.Add(1)
+	go b.poll(consumer)
+	return nil
+}
+
+// Close stops polling and deletes the consumer instances so the group rebalances promptly
+func (b *KafkaRESTBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	consumers := b.consumers
+	b.mu.Unlock()
+
+	b.cancel()
+	b.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, consumer := range consumers {
+		if err := b.do(ctx, http.MethodDelete, consumer.baseURI, nil, nil); err != nil {
+			b.logger.Warn("Failed to delete kafka consumer", zap.String("group", consumer.group), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (b *KafkaRESTBus) topic(topic string) string {
+	return b.cfg.TopicPrefix + topic
+}
+
+func (b *KafkaRESTBus) poll(consumer *kafkaConsumer) {
+	defer b.wg.Done()
+	failures := 0
+	for b.ctx.Err() == nil {
+		var records []kafkaRecord
+		if err := b.do(b.ctx, http.MethodGet, consumer.baseURI+"/records?timeout=30000", nil, &records); err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			failures++
+			b.logger.Warn("Kafka poll failed", zap.String("group", consumer.group), zap.Error(err))
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoffDelay(failures)):
+			}
+			continue
+		}
+		failures = 0
+
+		for _, record := range records {
+			var msg Message
+			if err := json.Unmarshal(record.Value, &msg); err != nil {
+				b.logger.Warn("Skipping undecodable kafka record",
+					zap.String("topic", record.Topic), zap.Int64("offset", record.Offset), zap.Error(err))
+			} else if err := deliver(b.ctx, b.cfg, b.logger, consumer.group, &msg, consumer.handler); err != nil && b.ctx.Err() != nil {
+				// Shutting down mid-message: leave the offset uncommitted so it is redelivered
+				return
+			}
+			b.commit(consumer, record)
+		}
+	}
+}
+
+func (b *KafkaRESTBus) commit(consumer *kafkaConsumer, record kafkaRecord) {
+	body := map[string]interface{}{
+		"offsets": []map[string]interface{}{{
+			"topic":     record.Topic,
+			"partition": record.Partition,
+			"offset":    record.Offset,
+		}},
+	}
+	if err := b.do(b.ctx, http.MethodPost, consumer.baseURI+"/offsets", body, nil); err != nil {
+		b.logger.Warn("Failed to commit kafka offset",
+			zap.String("group", consumer.group), zap.Int64("offset", record.Offset), zap.Error(err))
+	}
+}
+
+func (b *KafkaRESTBus) do(ctx context.Context, method, target string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", kafkaJSONContentType)
+	}
+	req.Header.Set("Accept", kafkaJSONContentType)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
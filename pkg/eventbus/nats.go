@@ -0,0 +1,322 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// NATSBus publishes over core NATS using the plain text protocol. Subscriptions
+// use queue groups so instances sharing a group split the work, and they are
+// re-established automatically after a reconnect.
+type NATSBus struct {
+	cfg    Config
+	addr   string
+	user   string
+	pass   string
+	logger *zap.Logger
+
+	writeMu sync.Mutex
+	conn    net.Conn
+	writer  *bufio.Writer
+
+	mu     sync.Mutex
+	nextID int
+	subs   map[string]*natsSubscription
+	closed bool
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	readerWG sync.WaitGroup
+	subsWG   sync.WaitGroup
+}
+
+type natsSubscription struct {
+	subject string
+	group   string
+	handler Handler
+	queue   chan *Message
+}
+
+// NewNATSBus connects to the server at cfg.NATSURL (nats://[user:pass@]host:port)
+func NewNATSBus(cfg Config, logger *zap.Logger) (*NATSBus, error) {
+	if cfg.NATSURL == "" {
+		return nil, fmt.Errorf("nats url is required for the nats event bus")
+	}
+	u, err := url.Parse(cfg.NATSURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid nats url %q", cfg.NATSURL)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &NATSBus{
+		cfg:    cfg,
+		addr:   u.Host,
+		logger: logger,
+		subs:   make(map[string]*natsSubscription),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if u.User != nil {
+		b.user = u.User.Username()
+		b.pass, _ = u.User.Password()
+	}
+
+	reader, err := b.connect()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	b.readerWG.Add(1)
+	go b.readLoop(reader)
+	return b, nil
+}
+
+// Publish sends a message on the topic's subject
+func (b *NATSBus) Publish(ctx context.Context, topic, key string, payload interface{}) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	msg, err := newMessage(topic, key, payload)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	return b.write(fmt.Sprintf("PUB %s %d\r\n%s\r\n", b.subject(topic), len(data), data))
+}
+
+// Subscribe joins the queue group for topic
+func (b *NATSBus) Subscribe(topic, group string, handler Handler) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.nextID++
+	sid := strconv.Itoa(b.nextID)
+	sub := &natsSubscription{
+		subject: b.subject(topic),
+		group:   b.cfg.TopicPrefix + group,
+		handler: handler,
+		queue:   make(chan *Message, inProcessBuffer),
+	}
+	b.subs[sid] = sub
+	b.mu.Unlock()
+
+	b.subsWG.Add(1)
+	go func() {
+		defer b.subsWG.Done()
+		for msg := range sub.queue {
+			_ = deliver(b.ctx, b.cfg, b.logger, group, msg, sub.handler)
+		}
+	}()
+
+	return b.write(fmt.Sprintf("SUB %s %s %s\r\n", sub.subject, sub.group, sid))
+}
+
+// Close disconnects and stops the subscription workers. Messages still queued
+// locally are handed to their handlers with a cancelled context.
+func (b *NATSBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	b.cancel()
+	b.writeMu.Lock()
+	err := b.conn.Close()
+	b.writeMu.Unlock()
+	b.readerWG.Wait()
+
+	b.mu.Lock()
+	for _, sub := range b.subs {
+		close(sub.queue)
+	}
+	b.mu.Unlock()
+	b.subsWG.Wait()
+	return err
+}
+
+func (b *NATSBus) subject(topic string) string {
+	return b.cfg.TopicPrefix + topic
+}
+
+// connect dials the server, performs the CONNECT handshake and re-sends
+// existing subscriptions
+func (b *NATSBus) connect() (*bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", b.addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected nats greeting: %q", strings.TrimSpace(info))
+	}
+
+	options := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "stack-service",
+		"lang":     "go",
+		"version":  "1.0.0",
+		"protocol": 1,
+	}
+	if b.user != "" {
+		options["user"] = b.user
+		options["pass"] = b.pass
+	}
+	connectOpts, _ := json.Marshal(options)
+
+	var handshake strings.Builder
+	fmt.Fprintf(&handshake, "CONNECT %s\r\n", connectOpts)
+	b.mu.Lock()
+	for sid, sub := range b.subs {
+		fmt.Fprintf(&handshake, "SUB %s %s %s\r\n", sub.subject, sub.group, sid)
+	}
+	b.mu.Unlock()
+	handshake.WriteString("PING\r\n")
+
+	if _, err := io.WriteString(conn, handshake.String()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send nats handshake: %w", err)
+	}
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read nats handshake reply: %w", err)
+	}
+	if !strings.HasPrefix(reply, "PONG") {
+		conn.Close()
+		return nil, fmt.Errorf("nats handshake rejected: %s", strings.TrimSpace(reply))
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	b.writeMu.Lock()
+	b.conn = conn
+	b.writer = bufio.NewWriter(conn)
+	b.writeMu.Unlock()
+	return reader, nil
+}
+
+func (b *NATSBus) write(data string) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	if _, err := b.writer.WriteString(data); err != nil {
+		return fmt.Errorf("failed to write to nats: %w", err)
+	}
+	if err := b.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write to nats: %w", err)
+	}
+	return nil
+}
+
+// readLoop dispatches incoming messages and reconnects when the connection drops
+func (b *NATSBus) readLoop(reader *bufio.Reader) {
+	defer b.readerWG.Done()
+	for {
+		err := b.readMessages(reader)
+		if b.ctx.Err() != nil {
+			return
+		}
+		b.logger.Warn("NATS connection lost; reconnecting", zap.Error(err))
+
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoffDelay(attempt)):
+			}
+			reader, err = b.connect()
+			if err == nil {
+				b.logger.Info("NATS connection re-established")
+				break
+			}
+			b.logger.Warn("NATS reconnect failed", zap.Int("attempt", attempt), zap.Error(err))
+		}
+	}
+}
+
+func (b *NATSBus) readMessages(reader *bufio.Reader) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				return fmt.Errorf("malformed MSG line %q", line)
+			}
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return fmt.Errorf("malformed MSG size %q", line)
+			}
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return err
+			}
+			b.dispatch(fields[2], buf[:size])
+		case line == "PING":
+			if err := b.write("PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			b.logger.Warn("NATS server error", zap.String("error", line))
+		}
+	}
+}
+
+func (b *NATSBus) dispatch(sid string, data []byte) {
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.logger.Warn("Dropping undecodable NATS message", zap.Error(err))
+		return
+	}
+
+	b.mu.Lock()
+	sub, ok := b.subs[sid]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case sub.queue <- &msg:
+	case <-b.ctx.Done():
+	}
+}
+
+func backoffDelay(attempt int) time.Duration {
+	delay := time.Duration(attempt) * time.Second
+	if delay > 30*time.Second {
+		delay = 30 * time.Second
+	}
+	return delay
+}
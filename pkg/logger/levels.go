@@ -0,0 +1,289 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrUnknownModule is returned when a level is set for a module no logger was built for
+var ErrUnknownModule = errors.New("unknown log module")
+
+// ModuleLevel describes the level a module logs at and why
+type ModuleLevel struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+	// Configured is the level from the config file, if any; Override is a
+	// temporary level set at runtime that reverts at ExpiresAt
+	Configured string     `json:"configured,omitempty"`
+	Override   string     `json:"override,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+type moduleState struct {
+	level      zap.AtomicLevel
+	known      bool // A logger was built for the module
+	configured *zapcore.Level
+	override   *zapcore.Level
+	expiresAt  time.Time
+	timer      *time.Timer
+}
+
+// Levels holds per-module log levels. A module logs at its runtime override
+// while one is set, otherwise at its configured level, otherwise at the base
+// level. Levels are atomics shared with the module loggers, so changes take
+// effect without rebuilding them.
+type Levels struct {
+	mu      sync.Mutex
+	base    zap.AtomicLevel
+	modules map[string]*moduleState
+}
+
+// NewLevels creates a level registry with the given base level
+func NewLevels(base zapcore.Level) *Levels {
+	return &Levels{
+		base:    zap.NewAtomicLevelAt(base),
+		modules: map[string]*moduleState{},
+	}
+}
+
+var defaultLevels = NewLevels(zapcore.InfoLevel)
+
+// ModuleLevels returns the registry used by loggers from New
+func ModuleLevels() *Levels {
+	return defaultLevels
+}
+
+// ForModule returns a logger for a named module whose level can be changed
+// at runtime through ModuleLevels
+func ForModule(log *zap.Logger, module string) *zap.Logger {
+	return defaultLevels.Wrap(log, module)
+}
+
+// Module returns a logger for a named module whose level can be changed at
+// runtime through ModuleLevels
+func (l *Logger) Module(module string) *Logger {
+	return NewLogger(ForModule(l.Zap(), module))
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level %q: must be debug, info, warn or error", level)
+	}
+}
+
+// ParseModuleLevels parses a module to level map, leaving out and reporting
+// modules whose level is invalid
+func ParseModuleLevels(modules map[string]string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level, len(modules))
+	var errs []error
+	for module, name := range modules {
+		level, err := ParseLevel(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", module, err))
+			continue
+		}
+		levels[module] = level
+	}
+	return levels, errors.Join(errs...)
+}
+
+// Wrap returns log filtered at the module's level. The underlying core must
+// let debug entries through for a module to be raised to debug.
+func (l *Levels) Wrap(log *zap.Logger, module string) *zap.Logger {
+	l.mu.Lock()
+	state := l.state(module)
+	state.known = true
+	level := state.level
+	l.mu.Unlock()
+
+	return log.Named(module).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newLevelCore(core, level)
+	}))
+}
+
+// wrapRoot filters log at the base level
+func (l *Levels) wrapRoot(log *zap.Logger) *zap.Logger {
+	return log.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newLevelCore(core, l.base)
+	}))
+}
+
+// SetBase changes the level of modules without a configured or override level
+func (l *Levels) SetBase(level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.base.SetLevel(level)
+	for _, state := range l.modules {
+		l.apply(state)
+	}
+}
+
+// Configure replaces the configured module levels, as read from the config
+// file. Modules left out fall back to the base level; overrides are kept.
+// Levels for modules without a logger yet apply once one is built.
+func (l *Levels) Configure(levels map[string]zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, state := range l.modules {
+		state.configured = nil
+	}
+	for module, level := range levels {
+		l.state(module).configured = &level
+	}
+	for _, state := range l.modules {
+		l.apply(state)
+	}
+}
+
+// Override sets a module's level until ttl passes, then reverts it. Setting
+// it again replaces the earlier override and its timer.
+func (l *Levels) Override(module string, level zapcore.Level, ttl time.Duration) (ModuleLevel, error) {
+	if ttl <= 0 {
+		return ModuleLevel{}, fmt.Errorf("override duration must be positive")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state, ok := l.modules[module]
+	if !ok || !state.known {
+		return ModuleLevel{}, fmt.Errorf("%w: %s", ErrUnknownModule, module)
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+	state.override = &level
+	state.expiresAt = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		// A newer override owns the state now
+		if state.timer != timer {
+			return
+		}
+		l.clearOverride(state)
+	})
+	state.timer = timer
+	l.apply(state)
+	return l.describe(module, state), nil
+}
+
+// Revert drops a module's override straight away
+func (l *Levels) Revert(module string) (ModuleLevel, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state, ok := l.modules[module]
+	if !ok || !state.known {
+		return ModuleLevel{}, fmt.Errorf("%w: %s", ErrUnknownModule, module)
+	}
+	l.clearOverride(state)
+	return l.describe(module, state), nil
+}
+
+// List returns the modules loggers were built for, by name
+func (l *Levels) List() []ModuleLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	levels := make([]ModuleLevel, 0, len(l.modules))
+	for module, state := range l.modules {
+		if state.known {
+			levels = append(levels, l.describe(module, state))
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Module < levels[j].Module })
+	return levels
+}
+
+func (l *Levels) state(module string) *moduleState {
+	state, ok := l.modules[module]
+	if !ok {
+		state = &moduleState{level: zap.NewAtomicLevelAt(l.base.Level())}
+		l.modules[module] = state
+	}
+	return state
+}
+
+func (l *Levels) clearOverride(state *moduleState) {
+	if state.timer != nil {
+		state.timer.Stop()
+		state.timer = nil
+	}
+	state.override = nil
+	state.expiresAt = time.Time{}
+	l.apply(state)
+}
+
+func (l *Levels) apply(state *moduleState) {
+	switch {
+	case state.override != nil:
+		state.level.SetLevel(*state.override)
+	case state.configured != nil:
+		state.level.SetLevel(*state.configured)
+	default:
+		state.level.SetLevel(l.base.Level())
+	}
+}
+
+func (l *Levels) describe(module string, state *moduleState) ModuleLevel {
+	described := ModuleLevel{Module: module, Level: state.level.Level().String()}
+	if state.configured != nil {
+		described.Configured = state.configured.String()
+	}
+	if state.override != nil {
+		expiresAt := state.expiresAt
+		described.Override = state.override.String()
+		described.ExpiresAt = &expiresAt
+	}
+	return described
+}
+
+// levelCore filters entries below a level it shares with the registry.
+// Wrapping a levelCore replaces it rather than nesting, so a module logger
+// derived from the root logger is not held back by the root's level.
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func newLevelCore(core zapcore.Core, level zap.AtomicLevel) zapcore.Core {
+	if wrapped, ok := core.(*levelCore); ok {
+		core = wrapped.Core
+	}
+	return &levelCore{Core: core, level: level}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	return c.level.Level()
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -25,19 +25,17 @@ func New(level, environment string) *Logger {
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
-	// Set log level
-	switch level {
-	case "debug":
-		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	case "info":
-		config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-	case "warn":
-		config.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
-	case "error":
-		config.Level = zap.NewAtomicLevelAt(zap.ErrorLevel)
-	default:
-		config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	// The core lets every level through; the root and module loggers filter
+	// at levels that can be changed at runtime through ModuleLevels
+	config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	baseLevel, err := ParseLevel(level)
+	if err != nil {
+		baseLevel = zap.InfoLevel
 	}
+	defaultLevels.SetBase(baseLevel)
+
+	// Mask PII field helpers outside of local development
+	SetRedaction(RedactionForEnvironment(environment))
 
 	// Build logger
 	logger, err := config.Build()
@@ -46,7 +44,7 @@ func New(level, environment string) *Logger {
 	}
 
 	return &Logger{
-		SugaredLogger: logger.Sugar(),
+		SugaredLogger: defaultLevels.wrapRoot(logger).Sugar(),
 	}
 }
 
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// redactPII controls whether the typed PII field helpers mask their values.
+// It defaults to true so that loggers built outside of New never leak raw PII.
+var redactPII atomic.Bool
+
+func init() {
+	redactPII.Store(true)
+}
+
+// SetRedaction enables or disables PII redaction for the typed field helpers
+func SetRedaction(enabled bool) {
+	redactPII.Store(enabled)
+}
+
+// RedactionEnabled reports whether PII redaction is currently active
+func RedactionEnabled() bool {
+	return redactPII.Load()
+}
+
+// RedactionForEnvironment returns whether PII should be redacted for the given environment.
+// Only local development and test environments log raw values.
+func RedactionForEnvironment(environment string) bool {
+	switch strings.ToLower(strings.TrimSpace(environment)) {
+	case "development", "dev", "local", "test":
+		return false
+	default:
+		return true
+	}
+}
+
+// Email returns a log field for an email address.
+// In redacted environments the local part is masked and a short hash is appended for correlation.
+func Email(email string) zap.Field {
+	return EmailKey("email", email)
+}
+
+// EmailKey returns a log field for an email address under a key other than
+// "email", such as a message's recipient
+func EmailKey(key, email string) zap.Field {
+	return zap.String(key, RedactEmail(email))
+}
+
+// Phone returns a log field for a phone number
+func Phone(phone string) zap.Field {
+	return zap.String("phone", RedactPhone(phone))
+}
+
+// WalletAddr returns a log field for a blockchain wallet address
+func WalletAddr(address string) zap.Field {
+	return zap.String("wallet_address", RedactWalletAddr(address))
+}
+
+// Amount returns a log field for a monetary amount.
+// In redacted environments only the order of magnitude is logged.
+func Amount(key string, amount decimal.Decimal) zap.Field {
+	return zap.String(key, RedactAmount(amount))
+}
+
+// Token returns a log field for a credential such as a verification token.
+// Tokens are never logged raw, in any environment; the short hash lets a
+// token be followed across log lines.
+func Token(key, token string) zap.Field {
+	return zap.String(key, RedactToken(token))
+}
+
+// RedactAmount reduces an amount to its order of magnitude when redaction is
+// enabled (e.g., 1234.56 -> 1K-10K)
+func RedactAmount(amount decimal.Decimal) string {
+	if !RedactionEnabled() {
+		return amount.String()
+	}
+	return amountBucket(amount)
+}
+
+// RedactToken replaces a token with a short hash of it
+func RedactToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "#" + shortHash(token)
+}
+
+// RedactEmail masks an email address when redaction is enabled
+func RedactEmail(email string) string {
+	if !RedactionEnabled() || email == "" {
+		return email
+	}
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***#" + shortHash(email)
+	}
+	return email[:1] + "***" + email[at:] + "#" + shortHash(strings.ToLower(email))
+}
+
+// RedactPhone masks a phone number when redaction is enabled (e.g., +1234567890 -> +1******890)
+func RedactPhone(phone string) string {
+	if !RedactionEnabled() || phone == "" {
+		return phone
+	}
+	if len(phone) <= 5 {
+		return "***#" + shortHash(phone)
+	}
+	return phone[:2] + strings.Repeat("*", len(phone)-5) + phone[len(phone)-3:]
+}
+
+// RedactWalletAddr masks a wallet address when redaction is enabled (e.g., 0x1234...abcd)
+func RedactWalletAddr(address string) string {
+	if !RedactionEnabled() || len(address) <= 10 {
+		return address
+	}
+	return address[:6] + "..." + address[len(address)-4:]
+}
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func amountBucket(amount decimal.Decimal) string {
+	abs := amount.Abs()
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+	}
+	switch {
+	case abs.IsZero():
+		return "0"
+	case abs.LessThan(decimal.NewFromInt(10)):
+		return sign + "<10"
+	case abs.LessThan(decimal.NewFromInt(100)):
+		return sign + "10-100"
+	case abs.LessThan(decimal.NewFromInt(1000)):
+		return sign + "100-1K"
+	case abs.LessThan(decimal.NewFromInt(10000)):
+		return sign + "1K-10K"
+	case abs.LessThan(decimal.NewFromInt(100000)):
+		return sign + "10K-100K"
+	default:
+		return sign + ">=100K"
+	}
+}
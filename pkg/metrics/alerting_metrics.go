@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// AlertDeliveriesTotal counts alerts sent to on-call channels by outcome:
+// delivered, failed, or suppressed as a repeat within the dedup window
+var AlertDeliveriesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "stack_alert_deliveries_total",
+		Help: "Total number of alerts routed to on-call channels, by outcome",
+	},
+	[]string{"channel", "severity", "status"},
+)
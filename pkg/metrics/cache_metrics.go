@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HotCacheRequestsTotal counts reads of the hot endpoint cache by kind
+// (balances, portfolio, basket) and result (hit or miss)
+var HotCacheRequestsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "stack_hot_cache_requests_total",
+		Help: "Total number of hot endpoint cache reads, by kind and result",
+	},
+	[]string{"kind", "result"},
+)
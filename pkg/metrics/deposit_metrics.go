@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Sources of deduplicated deposit events
+const (
+	DepositSourceChainWatcher   = "chain_watcher"
+	DepositSourceCircle         = "circle"
+	DepositSourceDue            = "due"
+	DepositSourceFundingWebhook = "funding_webhook"
+)
+
+var (
+	// DepositsDeduplicated tracks deposit notifications dropped because the
+	// transfer was already recorded or credited
+	DepositsDeduplicated = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "deposits_deduplicated_total",
+			Help: "Total number of replayed deposit events that were not credited again",
+		},
+		[]string{"source"},
+	)
+)
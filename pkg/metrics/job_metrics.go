@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes for jobs released by the job janitor
+const (
+	StaleJobRequeued     = "requeued"
+	StaleJobDeadLettered = "dead_lettered"
+)
+
+var (
+	// StaleJobs is the number of in-flight jobs whose lease expired, as seen
+	// by the janitor's last sweep of each queue
+	StaleJobs = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stack_stale_jobs",
+			Help: "Number of in-flight jobs whose worker stopped renewing the lease",
+		},
+		[]string{"queue"},
+	)
+
+	// StaleJobsReleased counts abandoned jobs the janitor put back on the
+	// queue or gave up on
+	StaleJobsReleased = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_stale_jobs_released_total",
+			Help: "Total number of jobs with an expired lease that were requeued or dead-lettered",
+		},
+		[]string{"queue", "outcome"},
+	)
+)
@@ -24,6 +24,29 @@ var (
 		[]string{"method", "endpoint"},
 	)
 
+	HTTPRequestsInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "stack_http_requests_in_flight",
+			Help: "HTTP requests currently being served",
+		},
+	)
+
+	HTTPRequestsShedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_http_requests_shed_total",
+			Help: "HTTP requests turned away with 503 while the server was saturated",
+		},
+		[]string{"reason"},
+	)
+
+	HTTPRequestTimeoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_http_request_timeouts_total",
+			Help: "HTTP requests that ran past their route's latency budget",
+		},
+		[]string{"method", "endpoint"},
+	)
+
 	// Business metrics
 	TransactionsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -110,6 +133,22 @@ var (
 		[]string{"service"},
 	)
 
+	FaultsInjectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_faults_injected_total",
+			Help: "Total number of outbound calls an injected fault was applied to",
+		},
+		[]string{"provider", "fault"},
+	)
+
+	IntegrityAnomaliesGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stack_integrity_anomalies",
+			Help: "Anomalies found by the last data integrity run, by class",
+		},
+		[]string{"class"},
+	)
+
 	// Security metrics
 	AuthenticationAttemptsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -177,6 +216,17 @@ func UpdateCircuitBreakerState(service string, state float64) {
 	CircuitBreakerStateGauge.WithLabelValues(service).Set(state)
 }
 
+// RecordFaultInjected records a fault injected into an outbound call
+func RecordFaultInjected(provider, fault string) {
+	FaultsInjectedTotal.WithLabelValues(provider, fault).Inc()
+}
+
+// SetIntegrityAnomalies records how many anomalies of a class the last
+// integrity run found
+func SetIntegrityAnomalies(class string, count int) {
+	IntegrityAnomaliesGauge.WithLabelValues(class).Set(float64(count))
+}
+
 // RecordAuthenticationAttempt records authentication attempt
 func RecordAuthenticationAttempt(result string) {
 	AuthenticationAttemptsTotal.WithLabelValues(result).Inc()
@@ -0,0 +1,279 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// External providers whose SLAs are tracked. KYC calls are labelled with
+// the configured KYC vendor instead.
+const (
+	ProviderAlpaca = "alpaca"
+	ProviderCircle = "circle"
+	ProviderDue    = "due"
+)
+
+// Circuit breaker states, ordered by severity so max() over breakers gives
+// the worst one
+const (
+	CircuitClosed   = 0
+	CircuitHalfOpen = 1
+	CircuitOpen     = 2
+)
+
+// Error codes recorded for calls that got no response from the provider
+const (
+	ProviderErrorTimeout   = "timeout"
+	ProviderErrorCanceled  = "canceled"
+	ProviderErrorTransport = "transport"
+)
+
+// maxErrorBodyBytes bounds how much of an error response is read to find
+// the provider's error code
+const maxErrorBodyBytes = 64 << 10
+
+var (
+	// ProviderRequestDuration is each provider call's latency from sending
+	// the request to receiving the response headers
+	ProviderRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "stack_provider_request_duration_seconds",
+			Help:    "External provider request latency in seconds, by endpoint",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+		},
+		[]string{"provider", "method", "endpoint", "status_class"},
+	)
+
+	// ProviderRequests counts every provider call, so error rates can be
+	// taken against it
+	ProviderRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_provider_requests_total",
+			Help: "Total number of external provider requests, by endpoint and status class",
+		},
+		[]string{"provider", "method", "endpoint", "status_class"},
+	)
+
+	// ProviderErrors counts failed provider calls by the error code the
+	// provider returned, or why no response arrived
+	ProviderErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_provider_errors_total",
+			Help: "Total number of failed external provider requests, by provider error code",
+		},
+		[]string{"provider", "method", "endpoint", "code"},
+	)
+
+	// ProviderCircuitBreakerState is the state of each breaker guarding a
+	// provider
+	ProviderCircuitBreakerState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stack_provider_circuit_breaker_state",
+			Help: "External provider circuit breaker state (0=closed, 1=half-open, 2=open)",
+		},
+		[]string{"provider", "breaker"},
+	)
+
+	// ProviderRetries counts retries of failed provider calls the retry
+	// budget allowed
+	ProviderRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_provider_retries_total",
+			Help: "Total number of provider call retries allowed by the retry budget",
+		},
+		[]string{"provider"},
+	)
+
+	// ProviderRetriesSuppressed counts retries the retry budget dropped,
+	// because the provider is failing or retries already fill its budget
+	ProviderRetriesSuppressed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_provider_retries_suppressed_total",
+			Help: "Total number of provider call retries suppressed by the retry budget, by reason",
+		},
+		[]string{"provider", "reason"},
+	)
+)
+
+// RecordProviderCall records one provider call. status is 0 when no
+// response arrived, and code is empty for a successful call.
+func RecordProviderCall(provider, method, endpoint string, status int, code string, duration time.Duration) {
+	class := StatusClass(status)
+	ProviderRequests.WithLabelValues(provider, method, endpoint, class).Inc()
+	ProviderRequestDuration.WithLabelValues(provider, method, endpoint, class).Observe(duration.Seconds())
+	if code != "" {
+		ProviderErrors.WithLabelValues(provider, method, endpoint, code).Inc()
+	}
+}
+
+// RecordProviderRetry records a retry the retry budget allowed
+func RecordProviderRetry(provider string) {
+	ProviderRetries.WithLabelValues(provider).Inc()
+}
+
+// RecordProviderRetrySuppressed records a retry the retry budget dropped
+func RecordProviderRetrySuppressed(provider, reason string) {
+	ProviderRetriesSuppressed.WithLabelValues(provider, reason).Inc()
+}
+
+// SetProviderCircuitState records a breaker's state by its name as the
+// breaker libraries report it: closed, half-open or open
+func SetProviderCircuitState(provider, breaker, state string) {
+	value := CircuitClosed
+	switch strings.ToLower(strings.ReplaceAll(state, "_", "-")) {
+	case "half-open":
+		value = CircuitHalfOpen
+	case "open":
+		value = CircuitOpen
+	}
+	ProviderCircuitBreakerState.WithLabelValues(provider, breaker).Set(float64(value))
+}
+
+// StatusClass groups an HTTP status as 2xx, 4xx and so on, or "error" when
+// no response arrived
+func StatusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+var (
+	versionSegment = regexp.MustCompile(`^v[0-9]+[a-z0-9]*$`)
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	symbolSegment  = regexp.MustCompile(`^[A-Z][A-Z.]*$`)
+	codeCharacters = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+)
+
+// ProviderEndpoint turns a request path into a low-cardinality endpoint
+// label by replacing IDs and symbols with :id, e.g.
+// /v1/trading/accounts/{uuid}/orders becomes /v1/trading/accounts/:id/orders
+func ProviderEndpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if isIDSegment(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func isIDSegment(segment string) bool {
+	if segment == "" || versionSegment.MatchString(segment) {
+		return false
+	}
+	if uuidSegment.MatchString(segment) || symbolSegment.MatchString(segment) {
+		return true
+	}
+	return len(segment) >= 6 && strings.ContainsAny(segment, "0123456789")
+}
+
+// InstrumentProvider wraps a transport so every call through it is
+// recorded against provider. Wrap the provider client's own transport,
+// inside any fault injection, so injected faults do not count against the
+// provider's SLA.
+func InstrumentProvider(provider string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &providerTransport{provider: provider, next: next}
+}
+
+type providerTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *providerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+	endpoint := ProviderEndpoint(req.URL.Path)
+
+	if err != nil {
+		RecordProviderCall(t.provider, req.Method, endpoint, 0, transportErrorCode(req.Context(), err), duration)
+		return nil, err
+	}
+	code := ""
+	if resp.StatusCode >= 400 {
+		code = peekErrorCode(resp)
+	}
+	RecordProviderCall(t.provider, req.Method, endpoint, resp.StatusCode, code, duration)
+	return resp, nil
+}
+
+func transportErrorCode(ctx context.Context, err error) string {
+	if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+		return ProviderErrorCanceled
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return ProviderErrorTimeout
+	}
+	return ProviderErrorTransport
+}
+
+// peekErrorCode reads the provider's error code from the start of an error
+// response, leaving the body for the caller to read in full
+func peekErrorCode(resp *http.Response) string {
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	return ProviderErrorCode(resp.StatusCode, head)
+}
+
+// ProviderErrorCode finds the error code in a provider's JSON error body,
+// where providers put it under errorCode, code or error.code. Without one
+// the HTTP status is used, as http_503.
+func ProviderErrorCode(status int, body []byte) string {
+	var payload map[string]json.RawMessage
+	if json.Unmarshal(body, &payload) == nil {
+		for _, key := range []string{"errorCode", "error_code", "code"} {
+			if code := scalarCode(payload[key]); code != "" {
+				return code
+			}
+		}
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(payload["error"], &nested) == nil {
+			if code := scalarCode(nested["code"]); code != "" {
+				return code
+			}
+		}
+	}
+	return "http_" + strconv.Itoa(status)
+}
+
+func scalarCode(raw json.RawMessage) string {
+	var value interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &value) != nil {
+		return ""
+	}
+	var code string
+	switch v := value.(type) {
+	case string:
+		code = v
+	case float64:
+		code = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+	code = codeCharacters.ReplaceAllString(code, "_")
+	if len(code) > 64 {
+		code = code[:64]
+	}
+	return code
+}
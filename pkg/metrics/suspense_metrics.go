@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// SuspenseItemsHeld counts unattributed transfers placed in suspense
+	SuspenseItemsHeld = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_suspense_items_held_total",
+			Help: "Total number of unattributed transfers held in the suspense account",
+		},
+		[]string{"source", "reason"},
+	)
+
+	// SuspenseItemsOpen is the number of items awaiting an admin, as of the
+	// last aging check
+	SuspenseItemsOpen = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stack_suspense_items_open",
+			Help: "Number of suspense items not yet assigned or refunded",
+		},
+		[]string{"source"},
+	)
+
+	// SuspenseItemsAged is the number of open items older than the aging
+	// threshold, as of the last aging check
+	SuspenseItemsAged = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stack_suspense_items_aged",
+			Help: "Number of suspense items open for longer than the aging threshold",
+		},
+		[]string{"source"},
+	)
+
+	// SuspenseAgedUSD is the USD value of aged open items
+	SuspenseAgedUSD = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stack_suspense_aged_usd",
+			Help: "USD value of suspense items open for longer than the aging threshold",
+		},
+		[]string{"source"},
+	)
+)
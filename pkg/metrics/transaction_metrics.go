@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes of a serializable transaction
+const (
+	SerializableOutcomeCommitted = "committed"
+	SerializableOutcomeFailed    = "failed"
+	SerializableOutcomeExhausted = "exhausted"
+)
+
+var (
+	// SerializableTransactions counts serializable balance transactions by how
+	// they ended. exhausted means every retry hit a serialization conflict.
+	SerializableTransactions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_serializable_transactions_total",
+			Help: "Total number of serializable transactions by operation and outcome",
+		},
+		[]string{"operation", "outcome"},
+	)
+
+	// SerializableTransactionRetries counts transactions run again after
+	// conflicting with a concurrent one. Divided by committed transactions it
+	// gives the retry rate for an operation.
+	SerializableTransactionRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stack_serializable_transaction_retries_total",
+			Help: "Total number of serializable transactions retried after a serialization failure",
+		},
+		[]string{"operation"},
+	)
+)
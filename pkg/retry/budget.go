@@ -0,0 +1,215 @@
+package retry
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+// Reasons a retry is suppressed, as recorded in the suppressed retries metric
+const (
+	// SuppressedThrottled retries were dropped because the provider's recent
+	// success rate is low
+	SuppressedThrottled = "throttled"
+	// SuppressedBudget retries were dropped because retries already make up
+	// the most of the provider's traffic allowed
+	SuppressedBudget = "budget"
+)
+
+// BudgetConfig tunes a retry budget
+type BudgetConfig struct {
+	// Window is how far back attempts are counted
+	Window time.Duration
+	// Buckets is how many slices the window is kept in; older slices are
+	// dropped whole as the window slides
+	Buckets int
+	// MinRequests is how many attempts the window needs before the success
+	// rate is trusted to throttle retries
+	MinRequests int
+	// Overload controls how unhealthy a provider must look before retries
+	// are throttled. Retries are dropped with probability
+	// (attempts - Overload*successes) / (attempts + 1), so with 2 they start
+	// being dropped once fewer than half of attempts succeed, and nearly all
+	// are dropped when none do.
+	Overload float64
+	// RetryRatio caps retries at this share of first attempts in the window
+	RetryRatio float64
+	// MinRetries is allowed in every window regardless of RetryRatio, so
+	// low-traffic providers can still retry
+	MinRetries int
+}
+
+// DefaultBudgetConfig keeps a one-minute window, throttles below a 50%
+// success rate and lets retries add at most 20% to a provider's traffic
+func DefaultBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		Window:      time.Minute,
+		Buckets:     12,
+		MinRequests: 20,
+		Overload:    2,
+		RetryRatio:  0.2,
+		MinRetries:  10,
+	}
+}
+
+// Budget decides whether a failed provider call may be retried, shared by
+// every client of that provider. Independent retry loops each retrying a few
+// times multiply load on a provider that is already failing; the budget
+// tracks each provider's recent success rate and retry volume and drops
+// retries in proportion to how unhealthy the provider looks.
+//
+// A nil *Budget allows every retry.
+type Budget struct {
+	config BudgetConfig
+	now    func() time.Time
+	random func() float64
+
+	mu        sync.Mutex
+	providers map[string]*budgetWindow
+}
+
+// NewBudget creates a retry budget
+func NewBudget(config BudgetConfig) *Budget {
+	defaults := DefaultBudgetConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.Buckets <= 0 {
+		config.Buckets = defaults.Buckets
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaults.MinRequests
+	}
+	if config.Overload < 1 {
+		config.Overload = defaults.Overload
+	}
+	if config.RetryRatio <= 0 {
+		config.RetryRatio = defaults.RetryRatio
+	}
+	if config.MinRetries < 0 {
+		config.MinRetries = defaults.MinRetries
+	}
+	return &Budget{
+		config:    config,
+		now:       time.Now,
+		random:    rand.Float64,
+		providers: make(map[string]*budgetWindow),
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (b *Budget) SetClock(now func() time.Time) {
+	b.now = now
+}
+
+// SetRandom overrides the source of the throttling draw, for tests
+func (b *Budget) SetRandom(random func() float64) {
+	b.random = random
+}
+
+// Record counts one attempt to call a provider, first try or retry.
+// success is false only for failures that would be retried, such as 5xx
+// responses, timeouts and rate limiting; a rejected request still shows the
+// provider is up.
+func (b *Budget) Record(provider string, success bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket := b.window(provider).current(b.now())
+	bucket.requests++
+	if success {
+		bucket.successes++
+	}
+}
+
+// AllowRetry reports whether a failed call to the provider may be retried.
+// An allowed retry is counted against the budget; a suppressed one is
+// counted in the suppressed retries metric.
+func (b *Budget) AllowRetry(provider string) bool {
+	if b == nil {
+		return true
+	}
+	reason := b.allow(provider)
+	if reason != "" {
+		metrics.RecordProviderRetrySuppressed(provider, reason)
+		return false
+	}
+	metrics.RecordProviderRetry(provider)
+	return true
+}
+
+func (b *Budget) allow(provider string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	window := b.window(provider)
+	now := b.now()
+	totals := window.totals(now)
+
+	if totals.requests >= int64(b.config.MinRequests) {
+		reject := (float64(totals.requests) - b.config.Overload*float64(totals.successes)) / float64(totals.requests+1)
+		if reject > 0 && b.random() < reject {
+			return SuppressedThrottled
+		}
+	}
+
+	firstAttempts := totals.requests - totals.retries
+	if float64(totals.retries) >= b.config.RetryRatio*float64(firstAttempts)+float64(b.config.MinRetries) {
+		return SuppressedBudget
+	}
+
+	window.current(now).retries++
+	return ""
+}
+
+func (b *Budget) window(provider string) *budgetWindow {
+	window, ok := b.providers[provider]
+	if !ok {
+		window = &budgetWindow{
+			buckets: make([]budgetBucket, b.config.Buckets),
+			width:   b.config.Window / time.Duration(b.config.Buckets),
+		}
+		b.providers[provider] = window
+	}
+	return window
+}
+
+// budgetWindow counts a provider's attempts in a ring of time slices
+type budgetWindow struct {
+	buckets []budgetBucket
+	width   time.Duration
+}
+
+type budgetBucket struct {
+	slice     int64 // which time slice the counts belong to
+	requests  int64
+	successes int64
+	retries   int64 // allowed retries; each is also counted in requests once attempted
+}
+
+func (w *budgetWindow) current(now time.Time) *budgetBucket {
+	slice := now.UnixNano() / int64(w.width)
+	bucket := &w.buckets[slice%int64(len(w.buckets))]
+	if bucket.slice != slice {
+		*bucket = budgetBucket{slice: slice}
+	}
+	return bucket
+}
+
+func (w *budgetWindow) totals(now time.Time) budgetBucket {
+	oldest := now.UnixNano()/int64(w.width) - int64(len(w.buckets)) + 1
+	var totals budgetBucket
+	for _, bucket := range w.buckets {
+		if bucket.slice >= oldest {
+			totals.requests += bucket.requests
+			totals.successes += bucket.successes
+			totals.retries += bucket.retries
+		}
+	}
+	return totals
+}
@@ -0,0 +1,235 @@
+package startup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/health"
+)
+
+// Component is a dependency brought up at boot
+type Component struct {
+	Name string
+	// Critical components abort startup when they fail. Optional ones start
+	// degraded and keep retrying in the background.
+	Critical bool
+	// Start connects the component. It is retried until it succeeds for
+	// optional components, so it must be safe to call more than once.
+	Start func(ctx context.Context) error
+}
+
+// State describes whether a component is usable
+type State string
+
+const (
+	// StateLive indicates the component started successfully
+	StateLive State = "live"
+
+	// StateDegraded indicates an optional component failed to start and is
+	// being retried in the background
+	StateDegraded State = "degraded"
+)
+
+// ComponentStatus reports the outcome of starting a component
+type ComponentStatus struct {
+	Name      string     `json:"name"`
+	Critical  bool       `json:"critical"`
+	State     State      `json:"state"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	LiveSince *time.Time `json:"live_since,omitempty"`
+}
+
+// Config controls background reconnects of optional components
+type Config struct {
+	RetryInterval    time.Duration // Delay before the first reconnect attempt
+	MaxRetryInterval time.Duration // Backoff cap between attempts
+	Required         []string      // Optional components to treat as critical
+}
+
+// DefaultConfig returns the default supervisor configuration
+func DefaultConfig() Config {
+	return Config{
+		RetryInterval:    5 * time.Second,
+		MaxRetryInterval: 5 * time.Minute,
+	}
+}
+
+// Supervisor starts components in order, failing fast on critical ones and
+// reconnecting optional ones in the background. Its checkers feed the
+// readiness endpoint.
+type Supervisor struct {
+	config   Config
+	required map[string]bool
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	statuses map[string]*ComponentStatus
+}
+
+// NewSupervisor creates a new startup supervisor
+func NewSupervisor(config Config, logger *zap.Logger) *Supervisor {
+	defaults := DefaultConfig()
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaults.RetryInterval
+	}
+	if config.MaxRetryInterval < config.RetryInterval {
+		config.MaxRetryInterval = defaults.MaxRetryInterval
+	}
+	required := make(map[string]bool, len(config.Required))
+	for _, name := range config.Required {
+		required[name] = true
+	}
+	return &Supervisor{
+		config:   config,
+		required: required,
+		logger:   logger,
+		statuses: make(map[string]*ComponentStatus),
+	}
+}
+
+// Start brings a component up. A critical component's error is returned so
+// the caller can abort; an optional component's error is logged and retried
+// with backoff until ctx is cancelled, and Start returns nil.
+func (s *Supervisor) Start(ctx context.Context, component Component) error {
+	critical := component.Critical || s.required[component.Name]
+	status := &ComponentStatus{Name: component.Name, Critical: critical}
+
+	s.mu.Lock()
+	s.statuses[component.Name] = status
+	s.mu.Unlock()
+
+	err := s.attempt(ctx, component)
+	if err == nil {
+		return nil
+	}
+	if critical {
+		return fmt.Errorf("critical component %s failed to start: %w", component.Name, err)
+	}
+
+	s.logger.Warn("Optional component unavailable, starting degraded",
+		zap.String("component", component.Name),
+		zap.Error(err))
+
+	go s.reconnect(ctx, component)
+	return nil
+}
+
+// Statuses returns every component's status ordered by name
+func (s *Supervisor) Statuses() []ComponentStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]ComponentStatus, 0, len(s.statuses))
+	for _, status := range s.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Checkers returns a readiness checker per optional component. Degraded
+// components report degraded rather than unhealthy, so the service stays
+// ready while they reconnect. Critical components are omitted: the process
+// would not be serving if one had failed.
+func (s *Supervisor) Checkers() []health.Checker {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.statuses))
+	for name, status := range s.statuses {
+		if !status.Critical {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	checkers := make([]health.Checker, 0, len(names))
+	for _, name := range names {
+		checkers = append(checkers, &componentChecker{supervisor: s, name: name})
+	}
+	return checkers
+}
+
+func (s *Supervisor) attempt(ctx context.Context, component Component) error {
+	err := component.Start(ctx)
+
+	s.mu.Lock()
+	status := s.statuses[component.Name]
+	status.Attempts++
+	if err != nil {
+		status.State = StateDegraded
+		status.LastError = err.Error()
+		s.mu.Unlock()
+		return err
+	}
+	now := time.Now().UTC()
+	status.State = StateLive
+	status.LastError = ""
+	status.LiveSince = &now
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Supervisor) reconnect(ctx context.Context, component Component) {
+	delay := s.config.RetryInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		err := s.attempt(ctx, component)
+		if err == nil {
+			s.logger.Info("Optional component recovered", zap.String("component", component.Name))
+			return
+		}
+		delay *= 2
+		if delay > s.config.MaxRetryInterval {
+			delay = s.config.MaxRetryInterval
+		}
+		s.logger.Debug("Optional component still unavailable",
+			zap.String("component", component.Name),
+			zap.Duration("retry_in", delay),
+			zap.Error(err))
+	}
+}
+
+// componentChecker reports a supervised component in the readiness endpoint
+type componentChecker struct {
+	supervisor *Supervisor
+	name       string
+}
+
+func (c *componentChecker) Name() string {
+	return c.name
+}
+
+func (c *componentChecker) Check(ctx context.Context) health.CheckResult {
+	c.supervisor.mu.RLock()
+	status := *c.supervisor.statuses[c.name]
+	c.supervisor.mu.RUnlock()
+
+	result := health.CheckResult{
+		Status:    health.StatusHealthy,
+		Component: c.name,
+		Message:   "live",
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			"critical": status.Critical,
+			"attempts": status.Attempts,
+		},
+	}
+	if status.State != StateLive {
+		result.Status = health.StatusDegraded
+		result.Message = "starting in degraded mode; reconnecting in the background"
+		result.Error = status.LastError
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+// Circle notification headers
+const (
+	CircleSignatureHeader = "X-Circle-Signature"
+	CircleKeyIDHeader     = "X-Circle-Key-Id"
+)
+
+// ValidateCircleSignature verifies a Circle notification. Circle signs the raw
+// body with ECDSA over SHA-256; publicKey is the base64 DER key returned by the
+// notifications public key endpoint and signature the base64 header value.
+func ValidateCircleSignature(payload []byte, signature, publicKey string) error {
+	if signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("public key is not an ECDSA key")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	digest := sha256.Sum256(payload)
+	if !ecdsa.VerifyASN1(key, digest[:], sig) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
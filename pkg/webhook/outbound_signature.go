@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutboundSignatureHeader carries the signature on webhooks we send to partners
+const OutboundSignatureHeader = "X-Stack-Signature"
+
+// SignOutbound signs a webhook payload for delivery to a partner endpoint. The
+// header value has the form "t=<unix>,v1=<hex>", where the HMAC-SHA256 covers
+// "<unix>.<payload>" so receivers can reject replays outside a tolerance window.
+func SignOutbound(payload []byte, secret string, timestamp time.Time) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeOutboundSignature(payload, secret, ts))
+}
+
+// VerifyOutbound checks a signature produced by SignOutbound. A zero tolerance
+// disables the timestamp check.
+func VerifyOutbound(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts = kv[1]
+		case "v1":
+			signatures = append(signatures, kv[1])
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return fmt.Errorf("malformed signature header")
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp")
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("signature timestamp outside tolerance")
+		}
+	}
+
+	expected := computeOutboundSignature(payload, secret, ts)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+func computeOutboundSignature(payload []byte, secret, ts string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
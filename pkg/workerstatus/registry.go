@@ -0,0 +1,312 @@
+package workerstatus
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrWorkerNotFound is returned when no worker is registered under a name
+var ErrWorkerNotFound = errors.New("worker not found")
+
+// State describes what a worker is doing right now
+type State string
+
+const (
+	// StateIdle indicates the worker is waiting for its next run
+	StateIdle State = "idle"
+
+	// StateRunning indicates a run is in progress
+	StateRunning State = "running"
+
+	// StatePaused indicates an operator paused the worker; ticks are skipped
+	StatePaused State = "paused"
+)
+
+// BacklogFunc reports how many items are waiting for a worker
+type BacklogFunc func(ctx context.Context) (int64, error)
+
+// Status is a point-in-time view of a registered worker
+type Status struct {
+	Name              string     `json:"name"`
+	State             State      `json:"state"`
+	Interval          string     `json:"interval"`
+	LastRunAt         *time.Time `json:"last_run_at,omitempty"`
+	LastDuration      string     `json:"last_duration,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	LastErrorAt       *time.Time `json:"last_error_at,omitempty"`
+	NextRunAt         *time.Time `json:"next_run_at,omitempty"`
+	Runs              int64      `json:"runs"`
+	Errors            int64      `json:"errors"`
+	ConsecutiveErrors int64      `json:"consecutive_errors"`
+	Backlog           *int64     `json:"backlog,omitempty"`
+	BacklogError      string     `json:"backlog_error,omitempty"`
+	PausedAt          *time.Time `json:"paused_at,omitempty"`
+	PausedBy          string     `json:"paused_by,omitempty"`
+	PauseReason       string     `json:"pause_reason,omitempty"`
+}
+
+// Registry tracks the background workers running in this process. Pausing is
+// in-memory, so it applies to this instance only and is cleared on restart.
+type Registry struct {
+	mu       sync.RWMutex
+	trackers map[string]*Tracker
+	now      func() time.Time
+}
+
+// NewRegistry creates an empty worker registry
+func NewRegistry() *Registry {
+	return &Registry{
+		trackers: make(map[string]*Tracker),
+		now:      time.Now,
+	}
+}
+
+// Register adds a worker that runs every interval and returns the tracker it
+// reports through. backlog may be nil when the worker has no queue to measure.
+// Registering an existing name returns the existing tracker.
+func (r *Registry) Register(name string, interval time.Duration, backlog BacklogFunc) *Tracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.trackers[name]; ok {
+		return t
+	}
+	t := &Tracker{
+		name:         name,
+		interval:     interval,
+		backlog:      backlog,
+		now:          r.now,
+		registeredAt: r.now(),
+	}
+	r.trackers[name] = t
+	return t
+}
+
+// Get returns the tracker registered under name
+func (r *Registry) Get(name string) (*Tracker, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.trackers[name]
+	if !ok {
+		return nil, ErrWorkerNotFound
+	}
+	return t, nil
+}
+
+// Snapshot returns the status of every worker ordered by name, measuring
+// backlogs as it goes
+func (r *Registry) Snapshot(ctx context.Context) []Status {
+	r.mu.RLock()
+	trackers := make([]*Tracker, 0, len(r.trackers))
+	for _, t := range r.trackers {
+		trackers = append(trackers, t)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(trackers, func(i, j int) bool { return trackers[i].name < trackers[j].name })
+
+	statuses := make([]Status, 0, len(trackers))
+	for _, t := range trackers {
+		statuses = append(statuses, t.Status(ctx))
+	}
+	return statuses
+}
+
+// Pause stops the named worker from starting new runs. A run already in
+// progress is allowed to finish.
+func (r *Registry) Pause(name, by, reason string) (*Tracker, error) {
+	t, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	t.pause(by, reason)
+	return t, nil
+}
+
+// Resume lets the named worker start runs again from its next tick
+func (r *Registry) Resume(name string) (*Tracker, error) {
+	t, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	t.resume()
+	return t, nil
+}
+
+// Tracker records the runs of one worker. A nil Tracker is valid and never
+// pauses, so workers can report unconditionally.
+type Tracker struct {
+	name     string
+	interval time.Duration
+	backlog  BacklogFunc
+	now      func() time.Time
+
+	mu                sync.Mutex
+	registeredAt      time.Time
+	lastTick          time.Time
+	nextRun           time.Time
+	running           int
+	lastRunAt         time.Time
+	lastDuration      time.Duration
+	lastError         string
+	lastErrorAt       time.Time
+	runs              int64
+	errors            int64
+	consecutiveErrors int64
+	paused            bool
+	pausedAt          time.Time
+	pausedBy          string
+	pauseReason       string
+}
+
+// Name returns the name the worker was registered under
+func (t *Tracker) Name() string {
+	return t.name
+}
+
+// Begin is called on every tick. It returns false while the worker is paused,
+// in which case the tick must be skipped; otherwise the returned func must be
+// called with the outcome once the run completes.
+func (t *Tracker) Begin() (func(err error), bool) {
+	if t == nil {
+		return func(error) {}, true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	started := t.now()
+	t.lastTick = started
+	if t.paused {
+		return nil, false
+	}
+	t.running++
+
+	return func(err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		finished := t.now()
+		t.running--
+		t.runs++
+		t.lastRunAt = started
+		t.lastDuration = finished.Sub(started)
+		if err != nil {
+			t.errors++
+			t.consecutiveErrors++
+			t.lastError = err.Error()
+			t.lastErrorAt = finished
+			return
+		}
+		t.consecutiveErrors = 0
+	}, true
+}
+
+// ScheduleNext overrides the next run time for workers that do not run on a
+// fixed interval
+func (t *Tracker) ScheduleNext(at time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextRun = at
+}
+
+// Paused reports whether an operator has paused the worker
+func (t *Tracker) Paused() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+// Status returns the worker's current status including its backlog depth
+func (t *Tracker) Status(ctx context.Context) Status {
+	t.mu.Lock()
+	status := Status{
+		Name:              t.name,
+		State:             StateIdle,
+		Interval:          t.interval.String(),
+		Runs:              t.runs,
+		Errors:            t.errors,
+		ConsecutiveErrors: t.consecutiveErrors,
+		LastError:         t.lastError,
+	}
+	switch {
+	case t.paused:
+		status.State = StatePaused
+		status.PausedAt = timePtr(t.pausedAt)
+		status.PausedBy = t.pausedBy
+		status.PauseReason = t.pauseReason
+	case t.running > 0:
+		status.State = StateRunning
+	}
+	if !t.lastRunAt.IsZero() {
+		status.LastRunAt = timePtr(t.lastRunAt)
+		status.LastDuration = t.lastDuration.String()
+	}
+	if !t.lastErrorAt.IsZero() {
+		status.LastErrorAt = timePtr(t.lastErrorAt)
+	}
+	if next := t.nextRunLocked(); !next.IsZero() {
+		status.NextRunAt = timePtr(next)
+	}
+	backlog := t.backlog
+	t.mu.Unlock()
+
+	if backlog != nil {
+		depth, err := backlog(ctx)
+		if err != nil {
+			status.BacklogError = err.Error()
+		} else {
+			status.Backlog = &depth
+		}
+	}
+	return status
+}
+
+func (t *Tracker) nextRunLocked() time.Time {
+	if !t.nextRun.IsZero() {
+		return t.nextRun
+	}
+	if t.interval <= 0 {
+		return time.Time{}
+	}
+	last := t.lastTick
+	if last.IsZero() {
+		last = t.registeredAt
+	}
+	return last.Add(t.interval)
+}
+
+func (t *Tracker) pause(by, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.paused {
+		return
+	}
+	t.paused = true
+	t.pausedAt = t.now()
+	t.pausedBy = by
+	t.pauseReason = reason
+}
+
+func (t *Tracker) resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = false
+	t.pausedAt = time.Time{}
+	t.pausedBy = ""
+	t.pauseReason = ""
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
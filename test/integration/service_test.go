@@ -36,18 +36,18 @@ type MockCircleClient struct {
 	mock.Mock
 }
 
-func (m *MockCircleClient) GetTotalUSDCBalance(ctx context.Context) (decimal.Decimal, error) {
+func (m *MockCircleClient) GetTotalUSDCBalance(ctx context.Context) (*entities.BalanceAggregate, error) {
 	args := m.Called(ctx)
-	return args.Get(0).(decimal.Decimal), args.Error(1)
+	return args.Get(0).(*entities.BalanceAggregate), args.Error(1)
 }
 
 type MockAlpacaClient struct {
 	mock.Mock
 }
 
-func (m *MockAlpacaClient) GetTotalBuyingPower(ctx context.Context) (decimal.Decimal, error) {
+func (m *MockAlpacaClient) GetTotalBuyingPower(ctx context.Context) (*entities.BalanceAggregate, error) {
 	args := m.Called(ctx)
-	return args.Get(0).(decimal.Decimal), args.Error(1)
+	return args.Get(0).(*entities.BalanceAggregate), args.Error(1)
 }
 
 type MockMetricsService struct {
@@ -142,10 +142,10 @@ func TestService_RunReconciliation(t *testing.T) {
 
 				// Mock successful checks (all balanced)
 				ledger.On("GetSystemBufferBalance", mock.Anything, "system_buffer_usdc").Return(decimal.NewFromFloat(10000.0), nil)
-				circle.On("GetTotalUSDCBalance", mock.Anything).Return(decimal.NewFromFloat(10000.0), nil)
+				circle.On("GetTotalUSDCBalance", mock.Anything).Return(&entities.BalanceAggregate{Total: decimal.NewFromFloat(10000.0)}, nil)
 
 				ledger.On("GetTotalUserFiatExposure", mock.Anything).Return(decimal.NewFromFloat(50000.0), nil)
-				alpaca.On("GetTotalBuyingPower", mock.Anything).Return(decimal.NewFromFloat(50000.0), nil)
+				alpaca.On("GetTotalBuyingPower", mock.Anything).Return(&entities.BalanceAggregate{Total: decimal.NewFromFloat(50000.0)}, nil)
 			},
 			expectError:    false,
 			expectedPassed: 6, // All checks pass
@@ -162,10 +162,10 @@ func TestService_RunReconciliation(t *testing.T) {
 
 				// Mock Circle balance discrepancy
 				ledger.On("GetSystemBufferBalance", mock.Anything, "system_buffer_usdc").Return(decimal.NewFromFloat(10000.0), nil)
-				circle.On("GetTotalUSDCBalance", mock.Anything).Return(decimal.NewFromFloat(9900.0), nil) // $100 difference
+				circle.On("GetTotalUSDCBalance", mock.Anything).Return(&entities.BalanceAggregate{Total: decimal.NewFromFloat(9900.0)}, nil) // $100 difference
 
 				ledger.On("GetTotalUserFiatExposure", mock.Anything).Return(decimal.NewFromFloat(50000.0), nil)
-				alpaca.On("GetTotalBuyingPower", mock.Anything).Return(decimal.NewFromFloat(50000.0), nil)
+				alpaca.On("GetTotalBuyingPower", mock.Anything).Return(&entities.BalanceAggregate{Total: decimal.NewFromFloat(50000.0)}, nil)
 			},
 			expectError:    false,
 			expectedPassed: 5,
@@ -251,7 +251,7 @@ func TestService_CheckCircleBalance(t *testing.T) {
 			service, _, ledgerService, circleClient, _ := setupTestService(t)
 
 			ledgerService.On("GetSystemBufferBalance", mock.Anything, "system_buffer_usdc").Return(tt.ledgerBalance, nil)
-			circleClient.On("GetTotalUSDCBalance", mock.Anything).Return(tt.circleBalance, nil)
+			circleClient.On("GetTotalUSDCBalance", mock.Anything).Return(&entities.BalanceAggregate{Total: tt.circleBalance}, nil)
 
 			ctx := context.Background()
 			reportID := uuid.New()
@@ -312,7 +312,7 @@ func TestService_CheckAlpacaBalance(t *testing.T) {
 			service, _, ledgerService, _, alpacaClient := setupTestService(t)
 
 			ledgerService.On("GetTotalUserFiatExposure", mock.Anything).Return(tt.fiatExposure, nil)
-			alpacaClient.On("GetTotalBuyingPower", mock.Anything).Return(tt.alpacaBuyingPower, nil)
+			alpacaClient.On("GetTotalBuyingPower", mock.Anything).Return(&entities.BalanceAggregate{Total: tt.alpacaBuyingPower}, nil)
 
 			ctx := context.Background()
 			reportID := uuid.New()
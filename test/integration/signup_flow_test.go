@@ -116,6 +116,7 @@ func TestSignUpFlow(t *testing.T) {
 		smsStub,
 		container.ZapLog,
 		container.Config,
+		container.Clock,
 	)
 
 	// Setup test router
@@ -0,0 +1,320 @@
+package accounting_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	accountingexport "github.com/stack-service/stack_service/internal/adapters/accounting"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/accounting"
+)
+
+var mapping = accounting.Mapping{
+	Accounts: map[entities.AccountType]string{
+		entities.AccountTypeUSDCBalance:      "1010",
+		entities.AccountTypeSystemBufferUSDC: "1000",
+		entities.AccountTypeSystemFeeRevenue: "4000",
+	},
+	RoundingAccount: "6990",
+}
+
+func activity(accountType entities.AccountType, txType entities.TransactionType, entryType entities.EntryType, amount string) entities.LedgerActivity {
+	return entities.LedgerActivity{
+		AccountType:     accountType,
+		TransactionType: txType,
+		EntryType:       entryType,
+		Currency:        "USDC",
+		Amount:          decimal.RequireFromString(amount),
+	}
+}
+
+func depositDay() []entities.LedgerActivity {
+	return []entities.LedgerActivity{
+		activity(entities.AccountTypeSystemBufferUSDC, entities.TransactionTypeDeposit, entities.EntryTypeDebit, "100.004"),
+		activity(entities.AccountTypeUSDCBalance, entities.TransactionTypeDeposit, entities.EntryTypeCredit, "100.004"),
+		activity(entities.AccountTypeUSDCBalance, entities.TransactionTypeSubscriptionFee, entities.EntryTypeDebit, "9.996"),
+		activity(entities.AccountTypeSystemFeeRevenue, entities.TransactionTypeSubscriptionFee, entities.EntryTypeCredit, "9.996"),
+	}
+}
+
+func TestBuildJournalBalancesAfterRounding(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	journal, err := accounting.BuildJournal(day, depositDay(), mapping)
+	require.NoError(t, err)
+
+	assert.Equal(t, "STACK-20260302", journal.Reference)
+	assert.Len(t, journal.Lines, 4)
+	assert.True(t, journal.TotalDebits.Equal(journal.TotalCredits))
+	assert.Equal(t, "110.00", journal.TotalDebits.StringFixed(2))
+	assert.Equal(t, "1000", journal.Lines[0].GLAccount)
+	assert.NotEmpty(t, journal.Checksum)
+
+	// Sub-cent amounts that round apart are balanced on the rounding account
+	uneven := []entities.LedgerActivity{
+		activity(entities.AccountTypeSystemBufferUSDC, entities.TransactionTypeDeposit, entities.EntryTypeDebit, "0.005"),
+		activity(entities.AccountTypeUSDCBalance, entities.TransactionTypeDeposit, entities.EntryTypeCredit, "0.004"),
+		activity(entities.AccountTypeSystemFeeRevenue, entities.TransactionTypeDeposit, entities.EntryTypeCredit, "0.001"),
+	}
+	journal, err = accounting.BuildJournal(day, uneven, mapping)
+	require.NoError(t, err)
+	assert.True(t, journal.TotalDebits.Equal(journal.TotalCredits))
+	last := journal.Lines[len(journal.Lines)-1]
+	assert.Equal(t, "6990", last.GLAccount)
+	assert.Equal(t, "rounding", last.Description)
+}
+
+func TestBuildJournalRejectsUnmappedAccounts(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	rows := append(depositDay(),
+		activity(entities.AccountTypeStashBalance, entities.TransactionTypeInternalTransfer, entities.EntryTypeCredit, "5"))
+
+	_, err := accounting.BuildJournal(day, rows, mapping)
+	assert.ErrorIs(t, err, entities.ErrUnmappedLedgerAccount)
+	assert.Contains(t, err.Error(), "stash_balance")
+
+	withSuspense := mapping
+	withSuspense.SuspenseAccount = "9999"
+	journal, err := accounting.BuildJournal(day, rows, withSuspense)
+	require.NoError(t, err)
+	assert.Equal(t, "9999", journal.Lines[len(journal.Lines)-1].GLAccount)
+}
+
+type fakeRepo struct {
+	activity map[string][]entities.LedgerActivity
+	exports  map[string]*entities.AccountingExport
+	locked   map[string]bool
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		activity: make(map[string][]entities.LedgerActivity),
+		exports:  make(map[string]*entities.AccountingExport),
+		locked:   make(map[string]bool),
+	}
+}
+
+func (f *fakeRepo) LedgerActivity(ctx context.Context, from, to time.Time) ([]entities.LedgerActivity, error) {
+	return f.activity[from.Format("2006-01-02")], nil
+}
+
+func (f *fakeRepo) ClaimExport(ctx context.Context, date time.Time, destination string, force bool, now time.Time, lease time.Duration) (*entities.AccountingExport, error) {
+	key := date.Format("2006-01-02")
+	export, ok := f.exports[key]
+	if f.locked[key] || (ok && !force && (export.Status == entities.AccountingExportExported || export.Status == entities.AccountingExportEmpty)) {
+		return nil, entities.ErrAccountingExportInProgress
+	}
+	if !ok {
+		export = &entities.AccountingExport{
+			ID:          uuid.New(),
+			JournalDate: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+			Destination: destination,
+		}
+		f.exports[key] = export
+	}
+	export.Status = entities.AccountingExportExporting
+	copied := *export
+	return &copied, nil
+}
+
+func (f *fakeRepo) FinishExport(ctx context.Context, export *entities.AccountingExport) error {
+	copied := *export
+	f.exports[export.JournalDate.Format("2006-01-02")] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ListExports(ctx context.Context, destination string, from, to time.Time) ([]*entities.AccountingExport, error) {
+	var exports []*entities.AccountingExport
+	for _, export := range f.exports {
+		copied := *export
+		exports = append(exports, &copied)
+	}
+	return exports, nil
+}
+
+type fakeExporter struct {
+	calls       []string
+	previousRef []string
+	fail        bool
+}
+
+func (f *fakeExporter) Name() string { return "fake" }
+
+func (f *fakeExporter) ExportJournal(ctx context.Context, journal *entities.AccountingJournal, previousRef string) (string, error) {
+	if f.fail {
+		return "", errors.New("destination unreachable")
+	}
+	f.calls = append(f.calls, journal.Reference)
+	f.previousRef = append(f.previousRef, previousRef)
+	return "ref-" + journal.Reference, nil
+}
+
+func newService(repo *fakeRepo, exporter *fakeExporter, now time.Time) *accounting.Service {
+	svc := accounting.NewService(repo, accounting.Config{Mapping: mapping, LookbackDays: 3, SettleDelay: 2 * time.Hour}, zap.NewNop())
+	svc.SetExporter(exporter)
+	svc.SetClock(func() time.Time { return now })
+	return svc
+}
+
+func TestExportPendingExportsSettledDaysOnce(t *testing.T) {
+	repo := newFakeRepo()
+	repo.activity["2026-03-01"] = depositDay()
+	repo.activity["2026-03-02"] = depositDay()
+	exporter := &fakeExporter{}
+
+	// 01:00 on the 4th: the 3rd has not settled yet
+	svc := newService(repo, exporter, time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC))
+	exported, err := svc.ExportPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, exported)
+	assert.Equal(t, []string{"STACK-20260301", "STACK-20260302"}, exporter.calls)
+	assert.Equal(t, entities.AccountingExportEmpty, repo.exports["2026-02-28"].Status)
+	assert.NotContains(t, repo.exports, "2026-03-03")
+
+	// After the settle delay only the newly settled, empty day is handled
+	svc.SetClock(func() time.Time { return time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC) })
+	exported, err = svc.ExportPending(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, exported)
+	assert.Len(t, exporter.calls, 2)
+	assert.Equal(t, entities.AccountingExportEmpty, repo.exports["2026-03-03"].Status)
+}
+
+func TestFailedExportIsRecordedAndRetried(t *testing.T) {
+	repo := newFakeRepo()
+	repo.activity["2026-03-02"] = depositDay()
+	exporter := &fakeExporter{fail: true}
+	svc := newService(repo, exporter, time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC))
+
+	_, err := svc.ExportPending(context.Background())
+	require.Error(t, err)
+	failed := repo.exports["2026-03-02"]
+	assert.Equal(t, entities.AccountingExportFailed, failed.Status)
+	assert.Equal(t, "destination unreachable", failed.LastError)
+	assert.Equal(t, 1, failed.Attempts)
+
+	exporter.fail = false
+	exported, err := svc.ExportPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, exported)
+	done := repo.exports["2026-03-02"]
+	assert.Equal(t, entities.AccountingExportExported, done.Status)
+	assert.Equal(t, 2, done.Attempts)
+	assert.Empty(t, done.LastError)
+}
+
+func TestReexportReplacesEarlierExport(t *testing.T) {
+	repo := newFakeRepo()
+	repo.activity["2026-03-01"] = depositDay()
+	repo.activity["2026-03-02"] = depositDay()
+	exporter := &fakeExporter{}
+	svc := newService(repo, exporter, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+
+	_, err := svc.ExportPending(context.Background())
+	require.NoError(t, err)
+	repo.locked["2026-03-02"] = true
+
+	from, _ := svc.ParseDay("2026-03-01")
+	to, _ := svc.ParseDay("2026-03-02")
+	result, err := svc.Reexport(context.Background(), from, to)
+	require.NoError(t, err)
+	require.Len(t, result.Exports, 1)
+	assert.Equal(t, []string{"2026-03-02"}, result.InProgress)
+	assert.Equal(t, "ref-STACK-20260301", exporter.previousRef[len(exporter.previousRef)-1])
+
+	_, err = svc.Reexport(context.Background(), to, from)
+	assert.ErrorIs(t, err, entities.ErrInvalidAccountingDateRange)
+	today, _ := svc.ParseDay("2026-03-04")
+	_, err = svc.Reexport(context.Background(), from, today)
+	assert.ErrorIs(t, err, entities.ErrInvalidAccountingDateRange)
+}
+
+func TestRenderJournalCSV(t *testing.T) {
+	journal, err := accounting.BuildJournal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), depositDay(), mapping)
+	require.NoError(t, err)
+
+	content, err := accountingexport.RenderJournalCSV(journal)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "Journal No,Date,Account,Debit,Credit,Currency,Memo", lines[0])
+	assert.Equal(t, "STACK-20260302,2026-03-02,1000,100.00,,USDC,deposit system_buffer_usdc (USDC)", lines[1])
+
+	dir := t.TempDir()
+	ref, err := accountingexport.NewCSVExporter(accountingexport.NewLocalDirectory(dir)).ExportJournal(context.Background(), journal, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(ref, "STACK-20260302.csv"))
+}
+
+func TestQuickBooksCreatesThenUpdatesJournalEntry(t *testing.T) {
+	var created, updated qbEntry
+	var requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			user, _, _ := r.BasicAuth()
+			assert.Equal(t, "client", user)
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "refresh_token": "rotated", "expires_in": 3600})
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/company/realm/journalentry/42":
+			json.NewEncoder(w).Encode(map[string]interface{}{"JournalEntry": map[string]string{"Id": "42", "SyncToken": "3"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v3/company/realm/journalentry":
+			assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+			var entry qbEntry
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+			if entry.ID == "" {
+				created = entry
+				requestID = r.URL.Query().Get("requestid")
+			} else {
+				updated = entry
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"JournalEntry": map[string]string{"Id": "42"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := accountingexport.NewQuickBooksExporter(accountingexport.QuickBooksConfig{
+		ClientID: "client", ClientSecret: "secret", RefreshToken: "refresh", RealmID: "realm",
+		BaseURL: server.URL, TokenURL: server.URL + "/token",
+	}, zap.NewNop())
+	journal, err := accounting.BuildJournal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), depositDay(), mapping)
+	require.NoError(t, err)
+
+	ref, err := exporter.ExportJournal(context.Background(), journal, "")
+	require.NoError(t, err)
+	assert.Equal(t, "42", ref)
+	assert.Equal(t, "STACK-20260302", created.DocNumber)
+	assert.True(t, strings.HasPrefix(requestID, "STACK-20260302-"))
+	require.Len(t, created.Line, 4)
+	assert.Equal(t, "100.00", created.Line[0].Amount.String())
+	assert.Equal(t, "Debit", created.Line[0].Detail.PostingType)
+
+	_, err = exporter.ExportJournal(context.Background(), journal, "42")
+	require.NoError(t, err)
+	assert.Equal(t, "42", updated.ID)
+	assert.Equal(t, "3", updated.SyncToken)
+}
+
+type qbEntry struct {
+	ID        string `json:"Id"`
+	SyncToken string `json:"SyncToken"`
+	DocNumber string `json:"DocNumber"`
+	Line      []struct {
+		Amount json.Number `json:"Amount"`
+		Detail struct {
+			PostingType string `json:"PostingType"`
+		} `json:"JournalEntryLineDetail"`
+	} `json:"Line"`
+}
@@ -0,0 +1,253 @@
+package adjustments_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/adjustments"
+)
+
+type fakeRepo struct {
+	adjustments map[uuid.UUID]*entities.LedgerAdjustment
+}
+
+func (r *fakeRepo) Create(ctx context.Context, adjustment *entities.LedgerAdjustment) error {
+	copied := *adjustment
+	r.adjustments[adjustment.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.LedgerAdjustment, error) {
+	adjustment, ok := r.adjustments[id]
+	if !ok {
+		return nil, entities.ErrLedgerAdjustmentNotFound
+	}
+	copied := *adjustment
+	return &copied, nil
+}
+
+func (r *fakeRepo) List(ctx context.Context, filter entities.LedgerAdjustmentFilter) ([]*entities.LedgerAdjustment, error) {
+	var out []*entities.LedgerAdjustment
+	for _, adjustment := range r.adjustments {
+		if (filter.UserID == nil || adjustment.UserID == *filter.UserID) && (filter.Status == "" || adjustment.Status == filter.Status) {
+			out = append(out, adjustment)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) SetApprovalRequest(ctx context.Context, id, approvalRequestID uuid.UUID) error {
+	r.adjustments[id].ApprovalRequestID = &approvalRequestID
+	return nil
+}
+
+func (r *fakeRepo) Resolve(ctx context.Context, adjustment *entities.LedgerAdjustment) (bool, error) {
+	if r.adjustments[adjustment.ID].Status != entities.LedgerAdjustmentPendingApproval {
+		return false, nil
+	}
+	copied := *adjustment
+	r.adjustments[adjustment.ID] = &copied
+	return true, nil
+}
+
+type fakeLedger struct {
+	posted []*entities.CreateTransactionRequest
+	err    error
+}
+
+func (l *fakeLedger) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	currency := "USDC"
+	if accountType == entities.AccountTypeFiatExposure {
+		currency = "USD"
+	}
+	return &entities.LedgerAccount{ID: uuid.NewSHA1(userID, []byte(accountType)), UserID: &userID, AccountType: accountType, Currency: currency}, nil
+}
+
+func (l *fakeLedger) GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	currency := "USDC"
+	if accountType != entities.AccountTypeSystemBufferUSDC {
+		currency = "USD"
+	}
+	return &entities.LedgerAccount{ID: uuid.NewSHA1(uuid.Nil, []byte(accountType)), AccountType: accountType, Currency: currency}, nil
+}
+
+func (l *fakeLedger) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.posted = append(l.posted, req)
+	return &entities.LedgerTransaction{ID: uuid.New(), TransactionType: req.TransactionType}, nil
+}
+
+type fakeApprovals struct {
+	submitted []*entities.SubmitApprovalRequest
+}
+
+func (a *fakeApprovals) Submit(ctx context.Context, req *entities.SubmitApprovalRequest) (*entities.ApprovalRequest, error) {
+	a.submitted = append(a.submitted, req)
+	return &entities.ApprovalRequest{ID: uuid.New(), Action: req.Action, ResourceID: req.ResourceID, RequestedBy: req.RequestedBy}, nil
+}
+
+type fixture struct {
+	service   *adjustments.Service
+	repo      *fakeRepo
+	ledger    *fakeLedger
+	approvals *fakeApprovals
+	admin     uuid.UUID
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		repo:      &fakeRepo{adjustments: map[uuid.UUID]*entities.LedgerAdjustment{}},
+		ledger:    &fakeLedger{},
+		approvals: &fakeApprovals{},
+		admin:     uuid.New(),
+	}
+	f.service = adjustments.NewService(f.repo, f.ledger, f.approvals, zap.NewNop())
+	return f
+}
+
+func validRequest() *entities.CreateLedgerAdjustmentRequest {
+	return &entities.CreateLedgerAdjustmentRequest{
+		UserID:            uuid.New(),
+		AccountType:       entities.AccountTypeUSDCBalance,
+		OffsetAccountType: entities.AccountTypeSystemBufferUSDC,
+		Direction:         entities.AdjustmentIncrease,
+		Amount:            decimal.RequireFromString("25.50"),
+		ReasonCode:        entities.AdjustmentReasonMissedPosting,
+		Memo:              "Deposit 0xabc confirmed on-chain but never credited",
+		Documents:         []entities.SupportingDocument{{Name: "Explorer", URL: "https://basescan.org/tx/0xabc"}},
+	}
+}
+
+// approve simulates the approvals service handing over a request a second
+// admin approved
+func (f *fixture) approve(t *testing.T, adjustment *entities.LedgerAdjustment) error {
+	t.Helper()
+	require.NotNil(t, adjustment.ApprovalRequestID)
+	return f.service.Approved(context.Background(), &entities.ApprovalRequest{
+		ID:          *adjustment.ApprovalRequestID,
+		Action:      entities.ApprovalActionLedgerAdjustment,
+		ResourceID:  adjustment.ID.String(),
+		RequestedBy: &f.admin,
+		Status:      entities.ApprovalApproved,
+		Decisions:   []entities.ApprovalDecision{{AdminID: uuid.New(), Approved: true}},
+	})
+}
+
+func TestRequestWaitsForApproval(t *testing.T) {
+	f := newFixture()
+
+	adjustment, err := f.service.Request(context.Background(), f.admin, validRequest())
+	require.NoError(t, err)
+	assert.Equal(t, entities.LedgerAdjustmentPendingApproval, adjustment.Status)
+	assert.Equal(t, "USDC", adjustment.Currency)
+	assert.Empty(t, f.ledger.posted, "nothing is posted before approval")
+
+	require.Len(t, f.approvals.submitted, 1)
+	submitted := f.approvals.submitted[0]
+	assert.Equal(t, entities.ApprovalActionLedgerAdjustment, submitted.Action)
+	assert.Equal(t, adjustment.ID.String(), submitted.ResourceID)
+	assert.Equal(t, f.admin, *submitted.RequestedBy)
+}
+
+func TestRequestValidation(t *testing.T) {
+	f := newFixture()
+	cases := map[string]func(*entities.CreateLedgerAdjustmentRequest){
+		"no documents":   func(r *entities.CreateLedgerAdjustmentRequest) { r.Documents = nil },
+		"unknown reason": func(r *entities.CreateLedgerAdjustmentRequest) { r.ReasonCode = "because" },
+		"zero amount":    func(r *entities.CreateLedgerAdjustmentRequest) { r.Amount = decimal.Zero },
+		"system as user": func(r *entities.CreateLedgerAdjustmentRequest) { r.AccountType = entities.AccountTypeSystemBufferFiat },
+		"user as offset": func(r *entities.CreateLedgerAdjustmentRequest) { r.OffsetAccountType = entities.AccountTypeHeldBalance },
+		"blank memo":     func(r *entities.CreateLedgerAdjustmentRequest) { r.Memo = "  " },
+		"currency mismatch": func(r *entities.CreateLedgerAdjustmentRequest) {
+			r.OffsetAccountType = entities.AccountTypeSystemBufferFiat
+		},
+		"unnamed document":     func(r *entities.CreateLedgerAdjustmentRequest) { r.Documents[0].Name = "" },
+		"unknown direction":    func(r *entities.CreateLedgerAdjustmentRequest) { r.Direction = "sideways" },
+		"missing user account": func(r *entities.CreateLedgerAdjustmentRequest) { r.UserID = uuid.Nil },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := validRequest()
+			mutate(req)
+			_, err := f.service.Request(context.Background(), f.admin, req)
+			assert.ErrorIs(t, err, entities.ErrInvalidLedgerAdjustment)
+		})
+	}
+	assert.Empty(t, f.approvals.submitted)
+}
+
+func TestApprovedPostsOneBalancedAdjustment(t *testing.T) {
+	f := newFixture()
+	req := validRequest()
+	req.Direction = entities.AdjustmentDecrease
+	adjustment, err := f.service.Request(context.Background(), f.admin, req)
+	require.NoError(t, err)
+	adjustment, err = f.service.Get(context.Background(), adjustment.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, f.approve(t, adjustment))
+
+	require.Len(t, f.ledger.posted, 1)
+	posted := f.ledger.posted[0]
+	require.NoError(t, posted.Validate())
+	assert.Equal(t, entities.TransactionTypeAdjustment, posted.TransactionType)
+	assert.Equal(t, "ledger-adjustment-"+adjustment.ID.String(), posted.IdempotencyKey)
+	require.Len(t, posted.Entries, 2)
+	user, _ := f.ledger.GetOrCreateUserAccount(context.Background(), req.UserID, req.AccountType)
+	for _, entry := range posted.Entries {
+		if entry.AccountID == user.ID {
+			assert.Equal(t, entities.EntryTypeCredit, entry.EntryType, "a decrease credits the user's asset account")
+		} else {
+			assert.Equal(t, entities.EntryTypeDebit, entry.EntryType)
+		}
+	}
+
+	stored, _ := f.service.Get(context.Background(), adjustment.ID)
+	assert.Equal(t, entities.LedgerAdjustmentPosted, stored.Status)
+	require.NotNil(t, stored.PostedAt)
+
+	lines, err := f.service.Statement(context.Background(), req.UserID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "Correction of a missing transaction", lines[0].Description)
+
+	assert.Error(t, f.approve(t, stored), "a posted adjustment is not posted again")
+	assert.Len(t, f.ledger.posted, 1)
+}
+
+func TestApprovedMarksRefusedPostingFailed(t *testing.T) {
+	f := newFixture()
+	adjustment, err := f.service.Request(context.Background(), f.admin, validRequest())
+	require.NoError(t, err)
+	adjustment, _ = f.service.Get(context.Background(), adjustment.ID)
+	f.ledger.err = errors.New("insufficient balance")
+
+	assert.Error(t, f.approve(t, adjustment))
+	stored, _ := f.service.Get(context.Background(), adjustment.ID)
+	assert.Equal(t, entities.LedgerAdjustmentFailed, stored.Status)
+	require.NotNil(t, stored.FailureReason)
+	assert.Contains(t, *stored.FailureReason, "insufficient balance")
+}
+
+func TestRejectedIsNeverPosted(t *testing.T) {
+	f := newFixture()
+	adjustment, err := f.service.Request(context.Background(), f.admin, validRequest())
+	require.NoError(t, err)
+
+	require.NoError(t, f.service.Rejected(context.Background(), &entities.ApprovalRequest{
+		ResourceID: adjustment.ID.String(),
+	}))
+	stored, _ := f.service.Get(context.Background(), adjustment.ID)
+	assert.Equal(t, entities.LedgerAdjustmentRejected, stored.Status)
+	assert.Empty(t, f.ledger.posted)
+}
@@ -0,0 +1,154 @@
+package aiartifacts_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/adapters/artifactstore"
+	domainrepos "github.com/stack-service/stack_service/internal/domain/repositories"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+)
+
+type fakeMigrationRepo struct {
+	summaries []*domainrepos.AISummary
+	// rewrite, when set, changes a summary's URI between its copy and repoint
+	rewrite func(summary *domainrepos.AISummary)
+}
+
+func (r *fakeMigrationRepo) ListByArtifactScheme(_ context.Context, scheme string, after uuid.UUID, limit int) ([]*domainrepos.AISummary, error) {
+	sorted := append([]*domainrepos.AISummary(nil), r.summaries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID.String() < sorted[j].ID.String() })
+	var found []*domainrepos.AISummary
+	for _, summary := range sorted {
+		if summary.ID.String() <= after.String() || !strings.HasPrefix(summary.ArtifactURI, scheme+"://") {
+			continue
+		}
+		copied := *summary
+		found = append(found, &copied)
+		if len(found) == limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeMigrationRepo) ReplaceArtifactURI(_ context.Context, id uuid.UUID, from, to string) (bool, error) {
+	for _, summary := range r.summaries {
+		if summary.ID != id {
+			continue
+		}
+		if r.rewrite != nil {
+			r.rewrite(summary)
+		}
+		if summary.ArtifactURI != from {
+			return false, nil
+		}
+		summary.ArtifactURI = to
+		return true, nil
+	}
+	return false, nil
+}
+
+func TestMigrator_MovesArtifactsAndKeepsThemReadable(t *testing.T) {
+	userID := uuid.New()
+	source := &fakeStore{objects: map[string][]byte{}}
+	repo := &fakeMigrationRepo{}
+	for week := 0; week < 5; week++ {
+		uri := "0g://ai-summaries/root" + string(rune('a'+week))
+		source.objects[uri] = []byte("# Week body")
+		repo.summaries = append(repo.summaries, summary(userID, week, "", uri))
+	}
+	repo.summaries[2].ArtifactURI = "0g://ai-summaries/lost"
+	repo.summaries = append(repo.summaries, summary(userID, 6, "# Database only", ""))
+
+	target, err := artifactstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	config := aiartifacts.DefaultMigrationConfig()
+	config.BatchSize = 2
+	config.DeleteSource = true
+	migrator, err := aiartifacts.NewMigrator(repo, []aiartifacts.ArtifactStore{source}, target, config, zap.NewNop())
+	require.NoError(t, err)
+
+	result, err := migrator.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, aiartifacts.MigrationResult{Scanned: 5, Migrated: 4, Failed: 1}, *result)
+	assert.Len(t, source.deleted, 4)
+
+	service := newService(&fakeRepo{summaries: repo.summaries}, 10)
+	service.SetStore(source)
+	service.SetStore(target)
+	artifacts, err := service.List(context.Background(), userID, 20, 0)
+	require.NoError(t, err)
+
+	storages := map[string]int{}
+	for _, artifact := range artifacts {
+		storages[artifact.Storage]++
+		if artifact.Storage == aiartifacts.StorageLocal {
+			assert.True(t, strings.HasPrefix(artifact.URI, "local://ai-summaries/"+userID.String()+"/"))
+			content, err := service.Open(context.Background(), token(t, artifact))
+			require.NoError(t, err)
+			assert.Equal(t, "# Week body", string(content.Body))
+		}
+	}
+	assert.Equal(t, map[string]int{aiartifacts.StorageLocal: 4, aiartifacts.StorageZeroG: 1, aiartifacts.StorageDatabase: 1}, storages)
+
+	// The failed artifact is retried on the next pass
+	source.objects["0g://ai-summaries/lost"] = []byte("# Found")
+	result, err = migrator.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Migrated)
+}
+
+func TestMigrator_LeavesRewrittenArtifactsAlone(t *testing.T) {
+	source := &fakeStore{objects: map[string][]byte{"0g://ai-summaries/old": []byte("# Old")}}
+	target := &fakeStore{scheme: aiartifacts.StorageS3}
+	repo := &fakeMigrationRepo{summaries: []*domainrepos.AISummary{summary(uuid.New(), 0, "", "0g://ai-summaries/old")}}
+	repo.rewrite = func(summary *domainrepos.AISummary) { summary.ArtifactURI = "0g://ai-summaries/regenerated" }
+
+	migrator, err := aiartifacts.NewMigrator(repo, []aiartifacts.ArtifactStore{source}, target, aiartifacts.DefaultMigrationConfig(), zap.NewNop())
+	require.NoError(t, err)
+	_, err = migrator.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "0g://ai-summaries/regenerated", repo.summaries[0].ArtifactURI)
+	assert.Empty(t, target.objects, "the unused copy is removed")
+	assert.Empty(t, source.deleted)
+
+	_, err = aiartifacts.NewMigrator(repo, []aiartifacts.ArtifactStore{target}, target, aiartifacts.DefaultMigrationConfig(), zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestLocalStore_RefusesKeysOutsideItsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := artifactstore.NewLocalStore(filepath.Join(dir, "artifacts"))
+	require.NoError(t, err)
+
+	uri, err := store.Put(context.Background(), "ai-summaries/u/a.md", "text/markdown", []byte("# A"))
+	require.NoError(t, err)
+	assert.Equal(t, "local://ai-summaries/u/a.md", uri)
+	body, err := store.Retrieve(context.Background(), uri)
+	require.NoError(t, err)
+	assert.Equal(t, "# A", string(body))
+
+	_, err = store.Put(context.Background(), "../escape.md", "text/markdown", []byte("x"))
+	assert.Error(t, err)
+	_, err = store.Retrieve(context.Background(), "local://../../etc/passwd")
+	assert.Error(t, err)
+	_, statErr := os.Stat(filepath.Join(dir, "escape.md"))
+	assert.True(t, os.IsNotExist(statErr))
+
+	require.NoError(t, store.Delete(context.Background(), uri))
+	require.NoError(t, store.Delete(context.Background(), uri), "deleting twice is not an error")
+	_, err = store.Retrieve(context.Background(), uri)
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package aiartifacts_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainrepos "github.com/stack-service/stack_service/internal/domain/repositories"
+	"github.com/stack-service/stack_service/internal/domain/services/aiartifacts"
+)
+
+type fakeRepo struct {
+	summaries []*domainrepos.AISummary
+}
+
+func (r *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*domainrepos.AISummary, error) {
+	for _, summary := range r.summaries {
+		if summary.ID == id {
+			return summary, nil
+		}
+	}
+	return nil, fmt.Errorf("AI summary not found: %w", sql.ErrNoRows)
+}
+
+func (r *fakeRepo) ListByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*domainrepos.AISummary, error) {
+	var owned []*domainrepos.AISummary
+	for _, summary := range r.summaries {
+		if summary.UserID == userID {
+			owned = append(owned, summary)
+		}
+	}
+	if offset >= len(owned) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(owned) {
+		end = len(owned)
+	}
+	return owned[offset:end], nil
+}
+
+type fakeStore struct {
+	scheme  string
+	objects map[string][]byte
+	deleted []string
+}
+
+func (s *fakeStore) Scheme() string {
+	if s.scheme == "" {
+		return aiartifacts.StorageZeroG
+	}
+	return s.scheme
+}
+
+func (s *fakeStore) Put(ctx context.Context, key, contentType string, body []byte) (string, error) {
+	if s.objects == nil {
+		s.objects = map[string][]byte{}
+	}
+	uri := s.Scheme() + "://" + key
+	s.objects[uri] = body
+	return uri, nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, uri string) error {
+	delete(s.objects, uri)
+	s.deleted = append(s.deleted, uri)
+	return nil
+}
+
+func (s *fakeStore) Retrieve(ctx context.Context, uri string) ([]byte, error) {
+	body, ok := s.objects[uri]
+	if !ok {
+		return nil, errors.New("object not found on storage nodes")
+	}
+	return body, nil
+}
+
+func summary(userID uuid.UUID, week int, md, uri string) *domainrepos.AISummary {
+	weekStart := time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -7*week)
+	return &domainrepos.AISummary{
+		ID:          uuid.New(),
+		UserID:      userID,
+		WeekStart:   weekStart,
+		SummaryMD:   md,
+		ArtifactURI: uri,
+		CreatedAt:   weekStart.Add(time.Hour),
+	}
+}
+
+func newService(repo *fakeRepo, pageSize int) *aiartifacts.Service {
+	return aiartifacts.NewService(repo, aiartifacts.Config{
+		SigningKey: []byte("test-signing-key"),
+		LinkTTL:    time.Minute,
+		PageSize:   pageSize,
+	}, zap.NewNop())
+}
+
+func token(t *testing.T, artifact aiartifacts.Artifact) string {
+	t.Helper()
+	link, err := url.Parse(artifact.DownloadURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/aicfo/artifacts/download", link.Path)
+	return link.Query().Get("token")
+}
+
+func TestList_SignsDownloadLinksThatOpen(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeRepo{summaries: []*domainrepos.AISummary{
+		summary(userID, 0, "# This week", "0g://ai-summaries/abc"),
+		summary(userID, 1, "# Last week", ""),
+	}}
+	service := newService(repo, 10)
+	service.SetStore(&fakeStore{objects: map[string][]byte{"0g://ai-summaries/abc": []byte("# This week (0G)")}})
+
+	artifacts, err := service.List(context.Background(), userID, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, artifacts, 2)
+	assert.Equal(t, aiartifacts.StorageZeroG, artifacts[0].Storage)
+	assert.Equal(t, aiartifacts.StorageDatabase, artifacts[1].Storage)
+	require.NotNil(t, artifacts[0].ExpiresAt)
+
+	content, err := service.Open(context.Background(), token(t, artifacts[0]))
+	require.NoError(t, err)
+	assert.Equal(t, "# This week (0G)", string(content.Body))
+
+	content, err = service.Open(context.Background(), token(t, artifacts[1]))
+	require.NoError(t, err)
+	assert.Equal(t, "# Last week", string(content.Body))
+}
+
+func TestOpen_RejectsTamperedAndForeignLinks(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	repo := &fakeRepo{summaries: []*domainrepos.AISummary{summary(owner, 0, "# Mine", "")}}
+	service := newService(repo, 10)
+
+	artifacts, err := service.List(context.Background(), owner, 20, 0)
+	require.NoError(t, err)
+	valid := token(t, artifacts[0])
+
+	payload, signature, _ := strings.Cut(valid, ".")
+	_, err = service.Open(context.Background(), payload+"."+strings.Repeat("0", len(signature)))
+	assert.ErrorIs(t, err, aiartifacts.ErrInvalidLink)
+	_, err = service.Open(context.Background(), "")
+	assert.ErrorIs(t, err, aiartifacts.ErrInvalidLink)
+
+	// A link signed with another key does not open
+	otherKey := aiartifacts.NewService(repo, aiartifacts.Config{SigningKey: []byte("other")}, zap.NewNop())
+	_, err = otherKey.Open(context.Background(), valid)
+	assert.ErrorIs(t, err, aiartifacts.ErrInvalidLink)
+
+	// Another user's links never reach this user's artifacts
+	repo.summaries[0].UserID = other
+	_, err = service.Open(context.Background(), valid)
+	assert.ErrorIs(t, err, aiartifacts.ErrArtifactNotFound)
+}
+
+func TestOpen_ExpiredLink(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeRepo{summaries: []*domainrepos.AISummary{summary(userID, 0, "# Mine", "")}}
+	service := aiartifacts.NewService(repo, aiartifacts.Config{
+		SigningKey: []byte("test-signing-key"),
+		LinkTTL:    time.Nanosecond,
+	}, zap.NewNop())
+
+	artifacts, err := service.List(context.Background(), userID, 20, 0)
+	require.NoError(t, err)
+
+	_, err = service.Open(context.Background(), token(t, artifacts[0]))
+	assert.ErrorIs(t, err, aiartifacts.ErrLinkExpired)
+}
+
+func TestExport_IncludesEveryArtifactWithContent(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeRepo{}
+	for week := 0; week < 5; week++ {
+		repo.summaries = append(repo.summaries, summary(userID, week, fmt.Sprintf("# Week %d", week), ""))
+	}
+	repo.summaries[0].ArtifactURI = "0g://ai-summaries/missing"
+	repo.summaries[0].SummaryMD = ""
+	repo.summaries[1].ArtifactURI = "0g://ai-summaries/unreachable"
+	repo.summaries = append(repo.summaries, summary(uuid.New(), 0, "# Someone else", ""))
+
+	service := newService(repo, 2)
+	service.SetStore(&fakeStore{objects: map[string][]byte{}})
+
+	export, err := service.Export(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, export.UserID)
+	require.Len(t, export.Artifacts, 5, "every page is exported and other users are excluded")
+
+	assert.Empty(t, export.Artifacts[0].Content)
+	assert.Contains(t, export.Artifacts[0].RetrievalError, "0G")
+	assert.Equal(t, "# Week 1", export.Artifacts[1].Content, "falls back to the stored copy")
+	assert.Empty(t, export.Artifacts[1].RetrievalError)
+	assert.Equal(t, "# Week 4", export.Artifacts[4].Content)
+}
@@ -0,0 +1,200 @@
+package alerting_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/alerting"
+)
+
+type memoryRepo struct {
+	deliveries []*entities.AlertDelivery
+}
+
+func (r *memoryRepo) Create(_ context.Context, delivery *entities.AlertDelivery) error {
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *memoryRepo) DeliveredSince(_ context.Context, dedupKey string, channel entities.AlertChannel, since time.Time) (bool, error) {
+	for _, d := range r.deliveries {
+		if d.DedupKey == dedupKey && d.Channel == channel && d.Status == entities.AlertDeliveryDelivered && !d.DeliveredAt.Before(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRepo) List(_ context.Context, _ entities.AlertDeliveryStatus, _ entities.AlertChannel, _, _ int) ([]*entities.AlertDelivery, error) {
+	return r.deliveries, nil
+}
+
+type recordingSender struct {
+	sent  []*entities.Alert
+	errs  []error
+	calls int
+}
+
+func (s *recordingSender) Send(_ context.Context, alert *entities.Alert) (*alerting.Receipt, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.sent = append(s.sent, alert)
+	return &alerting.Receipt{StatusCode: http.StatusAccepted, Ref: alert.DedupKey}, nil
+}
+
+// Wednesday 14:00 in Lagos, and 23:00 the same day
+var (
+	lagos, _   = time.LoadLocation("Africa/Lagos")
+	workingDay = time.Date(2026, 3, 18, 14, 0, 0, 0, lagos)
+	lateNight  = time.Date(2026, 3, 18, 23, 0, 0, 0, lagos)
+)
+
+func newService(t *testing.T, now *time.Time) (*alerting.Service, *memoryRepo, *recordingSender, *recordingSender) {
+	schedule, err := alerting.ParseSchedule("Africa/Lagos", []string{"mon", "tue", "wed", "thu", "fri"}, 9, 18)
+	require.NoError(t, err)
+	config := alerting.DefaultConfig()
+	config.Schedule = schedule
+	config.RetryBackoff = time.Millisecond
+
+	repo := &memoryRepo{}
+	service := alerting.NewService(repo, config, zap.NewNop())
+	service.SetClock(func() time.Time { return *now })
+	pagerDuty := &recordingSender{}
+	slack := &recordingSender{}
+	service.RegisterChannel(entities.AlertChannelPagerDuty, pagerDuty)
+	service.RegisterChannel(entities.AlertChannelSlack, slack)
+	return service, repo, pagerDuty, slack
+}
+
+func TestNotifyRoutesBySeverityAndBusinessHours(t *testing.T) {
+	now := workingDay
+	service, _, pagerDuty, slack := newService(t, &now)
+	ctx := context.Background()
+
+	deliveries, err := service.Notify(ctx, &entities.Alert{Source: "reconciliation", Severity: entities.AlertSeverityCritical, DedupKey: "a", Summary: "Ledger mismatch"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Len(t, pagerDuty.sent, 1)
+	assert.Len(t, slack.sent, 1)
+	assert.True(t, deliveries[0].BusinessHours)
+
+	_, err = service.Notify(ctx, &entities.Alert{Source: "suspense", Severity: entities.AlertSeverityWarning, DedupKey: "b", Summary: "Aged items"})
+	require.NoError(t, err)
+	assert.Len(t, pagerDuty.sent, 1)
+	assert.Len(t, slack.sent, 2)
+
+	// After hours critical alerts only page, and info alerts wait
+	now = lateNight
+	deliveries, err = service.Notify(ctx, &entities.Alert{Source: "reconciliation", Severity: entities.AlertSeverityCritical, DedupKey: "c", Summary: "Ledger mismatch"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, entities.AlertChannelPagerDuty, deliveries[0].Channel)
+	assert.False(t, deliveries[0].BusinessHours)
+
+	deliveries, err = service.Notify(ctx, &entities.Alert{Source: "reconciliation", Severity: entities.AlertSeverityInfo, DedupKey: "d", Summary: "Run finished"})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.Len(t, slack.sent, 2)
+
+	_, err = service.Notify(ctx, &entities.Alert{Severity: "urgent"})
+	assert.ErrorIs(t, err, alerting.ErrUnknownSeverity)
+}
+
+func TestNotifySuppressesRepeatsWithinDedupWindow(t *testing.T) {
+	now := workingDay
+	service, repo, _, slack := newService(t, &now)
+	ctx := context.Background()
+	alert := func() *entities.Alert {
+		return &entities.Alert{Source: "suspense", Severity: entities.AlertSeverityWarning, DedupKey: "suspense_aging:bank_credit", Summary: "Aged items"}
+	}
+
+	_, err := service.Notify(ctx, alert())
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	deliveries, err := service.Notify(ctx, alert())
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.Len(t, slack.sent, 1)
+
+	now = now.Add(4 * time.Hour)
+	_, err = service.Notify(ctx, alert())
+	require.NoError(t, err)
+	assert.Len(t, slack.sent, 2)
+	assert.Len(t, repo.deliveries, 2)
+}
+
+func TestNotifyRecordsRetriesAndFailures(t *testing.T) {
+	now := workingDay
+	service, repo, pagerDuty, slack := newService(t, &now)
+	ctx := context.Background()
+
+	pagerDuty.errs = []error{&alerting.SendError{StatusCode: http.StatusServiceUnavailable}}
+	slack.errs = []error{&alerting.SendError{StatusCode: http.StatusForbidden, Body: "invalid_token"}}
+	deliveries, err := service.Notify(ctx, &entities.Alert{Source: "reconciliation", Severity: entities.AlertSeverityCritical, DedupKey: "e", Summary: "Buffer short"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	paged := deliveries[0]
+	assert.Equal(t, entities.AlertDeliveryDelivered, paged.Status)
+	assert.Equal(t, 2, paged.Attempts)
+	require.NotNil(t, paged.ProviderRef)
+	assert.Equal(t, "e", *paged.ProviderRef)
+	assert.NotNil(t, paged.DeliveredAt)
+
+	// Rejections are not retried
+	posted := deliveries[1]
+	assert.Equal(t, entities.AlertDeliveryFailed, posted.Status)
+	assert.Equal(t, 1, posted.Attempts)
+	require.NotNil(t, posted.ResponseStatus)
+	assert.Equal(t, http.StatusForbidden, *posted.ResponseStatus)
+	assert.Len(t, repo.deliveries, 2)
+
+	// A failed channel is not suppressed by the dedup window
+	_, err = service.Notify(ctx, &entities.Alert{Source: "reconciliation", Severity: entities.AlertSeverityCritical, DedupKey: "e", Summary: "Buffer short"})
+	require.NoError(t, err)
+	assert.Len(t, slack.sent, 1)
+	assert.Equal(t, 2, pagerDuty.calls)
+}
+
+func TestNotifyFallsBackToWebhookAndPagerDutyConfirms(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success","message":"Event processed","dedup_key":"reconciliation:circle"}`))
+	}))
+	defer server.Close()
+
+	now := lateNight
+	service := alerting.NewService(&memoryRepo{}, alerting.DefaultConfig(), zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	service.RegisterChannel(entities.AlertChannelWebhook, alerting.NewWebhookSender(server.URL, time.Second))
+
+	alert := &entities.Alert{Source: "reconciliation", Severity: entities.AlertSeverityWarning, DedupKey: "reconciliation:circle", Summary: "Circle balance drift"}
+	deliveries, err := service.Notify(context.Background(), alert)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, entities.AlertChannelWebhook, deliveries[0].Channel)
+	assert.Equal(t, "Circle balance drift", received["summary"])
+
+	receipt, err := alerting.NewPagerDutySender(server.URL, "routing-key", time.Second).Send(context.Background(), alert)
+	require.NoError(t, err)
+	assert.Equal(t, "reconciliation:circle", receipt.Ref)
+	assert.Equal(t, "routing-key", received["routing_key"])
+	assert.Equal(t, "trigger", received["event_action"])
+}
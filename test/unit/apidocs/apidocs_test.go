@@ -0,0 +1,76 @@
+package apidocs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/api/apidocs"
+)
+
+const (
+	routesDir   = "../../../internal/api/routes"
+	handlersDir = "../../../internal/api/handlers"
+)
+
+func TestServedRoutesAreDocumented(t *testing.T) {
+	report, err := apidocs.Check(routesDir, handlersDir)
+	require.NoError(t, err)
+
+	for _, route := range report.Undocumented {
+		t.Errorf("%s is served but has no @Router annotation", route)
+	}
+	for _, route := range report.Stale {
+		t.Errorf("%s is listed in undocumented_routes.txt but is documented or no longer served", route)
+	}
+}
+
+func TestRegisteredRoutes_FollowsGroups(t *testing.T) {
+	dir := t.TempDir()
+	src := `package routes
+
+func SetupRoutes(router *gin.Engine) {
+	router.GET("/health", h.Health)
+	router.GET("/swagger/*any", swagger)
+	v1 := router.Group("/api/v1")
+	{
+		protected := v1.Group("/")
+		users := protected.Group("/users")
+		users.GET("", h.List)
+		users.PATCH("/:id", h.Update)
+		users.DELETE(path, h.Delete)
+	}
+}
+
+func unused(router *gin.Engine) {
+	router.GET("/hidden", h.Hidden)
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "routes.go"), []byte(src), 0o600))
+
+	routes, err := apidocs.RegisteredRoutes(dir, "SetupRoutes")
+	require.NoError(t, err)
+	assert.Equal(t, []apidocs.Route{
+		{Method: "GET", Path: "/api/v1/users"},
+		{Method: "PATCH", Path: "/api/v1/users/{id}"},
+		{Method: "GET", Path: "/health"},
+	}, routes)
+}
+
+func TestCompare_ReportsMissingAndStaleRoutes(t *testing.T) {
+	list := apidocs.Route{Method: "GET", Path: "/api/v1/users"}
+	update := apidocs.Route{Method: "PATCH", Path: "/api/v1/users/{id}"}
+	legacy := apidocs.Route{Method: "GET", Path: "/metrics"}
+	removed := apidocs.Route{Method: "GET", Path: "/old"}
+
+	report := apidocs.Compare([]apidocs.Route{list, update, legacy}, []apidocs.Route{list}, []apidocs.Route{legacy, removed})
+	assert.Equal(t, []apidocs.Route{update}, report.Undocumented)
+	assert.Equal(t, []apidocs.Route{removed}, report.Stale)
+	assert.False(t, report.OK())
+
+	report = apidocs.Compare([]apidocs.Route{list, legacy}, []apidocs.Route{list}, []apidocs.Route{legacy})
+	assert.True(t, report.OK())
+}
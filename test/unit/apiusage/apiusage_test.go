@@ -0,0 +1,175 @@
+package apiusage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/apiusage"
+)
+
+type fakeRepo struct {
+	written   []*entities.APIUsageRollup
+	upsertErr error
+	filter    entities.APIUsageFilter
+	results   []*entities.APIUsageRollup
+}
+
+func (f *fakeRepo) Upsert(ctx context.Context, rollups []*entities.APIUsageRollup) error {
+	if f.upsertErr != nil {
+		return f.upsertErr
+	}
+	f.written = append(f.written, rollups...)
+	return nil
+}
+
+func (f *fakeRepo) Query(ctx context.Context, filter entities.APIUsageFilter) ([]*entities.APIUsageRollup, error) {
+	f.filter = filter
+	return f.results, nil
+}
+
+func (f *fakeRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newService(repo *fakeRepo, now time.Time) *apiusage.Service {
+	svc := apiusage.NewService(repo, apiusage.DefaultConfig(), zap.NewNop())
+	svc.SetClock(func() time.Time { return now })
+	return svc
+}
+
+func TestRecord_AggregatesByUserRouteAndHour(t *testing.T) {
+	repo := &fakeRepo{}
+	now := time.Date(2026, 3, 2, 14, 37, 0, 0, time.UTC)
+	svc := newService(repo, now)
+	userID := uuid.New()
+
+	svc.Record(&userID, "GET", "/api/v1/portfolio/overview", 200, 40*time.Millisecond)
+	svc.Record(&userID, "GET", "/api/v1/portfolio/overview", 404, 8*time.Millisecond)
+	svc.Record(&userID, "GET", "/api/v1/portfolio/overview", 503, 3*time.Second)
+	svc.Record(nil, "GET", "/api/v1/portfolio/overview", 401, time.Millisecond)
+	assert.Equal(t, 2, svc.Pending(), "anonymous requests are kept apart")
+
+	require.NoError(t, svc.Flush(context.Background()))
+	assert.Equal(t, 0, svc.Pending())
+	require.Len(t, repo.written, 2)
+
+	var rollup *entities.APIUsageRollup
+	for _, written := range repo.written {
+		if written.UserID == userID {
+			rollup = written
+		} else {
+			assert.Equal(t, uuid.Nil, written.UserID)
+		}
+	}
+	require.NotNil(t, rollup)
+	assert.Equal(t, time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC), rollup.BucketStart)
+	assert.EqualValues(t, 3, rollup.RequestCount)
+	assert.EqualValues(t, 1, rollup.ClientErrorCount)
+	assert.EqualValues(t, 1, rollup.ServerErrorCount)
+	assert.EqualValues(t, 3048, rollup.TotalDurationMs)
+	assert.Len(t, rollup.LatencyBuckets, len(apiusage.LatencyBoundsMs)+1)
+}
+
+func TestRecordAPIKey_KeepsEachKeyApart(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := newService(repo, time.Date(2026, 3, 2, 14, 37, 0, 0, time.UTC))
+	userID := uuid.New()
+	keyID := uuid.New()
+
+	svc.RecordAPIKey(keyID, &userID, "GET", "/api/v1/developer/sandbox/whoami", 200, 5*time.Millisecond)
+	svc.RecordAPIKey(keyID, &userID, "GET", "/api/v1/developer/sandbox/whoami", 200, 5*time.Millisecond)
+	svc.Record(&userID, "GET", "/api/v1/developer/sandbox/whoami", 200, 5*time.Millisecond)
+	assert.Equal(t, 2, svc.Pending(), "the same user's requests without a key are kept apart")
+
+	require.NoError(t, svc.Flush(context.Background()))
+	for _, written := range repo.written {
+		if written.APIKeyID == keyID {
+			assert.EqualValues(t, 2, written.RequestCount)
+		} else {
+			assert.Equal(t, uuid.Nil, written.APIKeyID)
+			assert.EqualValues(t, 1, written.RequestCount)
+		}
+	}
+}
+
+func TestFlush_KeepsCountsWhenWriteFails(t *testing.T) {
+	repo := &fakeRepo{upsertErr: errors.New("database unavailable")}
+	svc := newService(repo, time.Now())
+	userID := uuid.New()
+
+	svc.Record(&userID, "POST", "/api/v1/orders", 201, 20*time.Millisecond)
+	require.Error(t, svc.Flush(context.Background()))
+
+	svc.Record(&userID, "POST", "/api/v1/orders", 201, 20*time.Millisecond)
+	assert.Equal(t, 1, svc.Pending())
+
+	repo.upsertErr = nil
+	require.NoError(t, svc.Flush(context.Background()))
+	require.Len(t, repo.written, 1)
+	assert.EqualValues(t, 2, repo.written[0].RequestCount, "failed counts merge into new ones")
+	assert.EqualValues(t, 40, repo.written[0].TotalDurationMs)
+}
+
+func TestPercentile(t *testing.T) {
+	buckets := make([]int64, len(apiusage.LatencyBoundsMs)+1)
+	assert.EqualValues(t, 0, apiusage.Percentile(buckets, 0.95))
+
+	buckets[3] = 90 // <= 50ms
+	buckets[6] = 10 // <= 500ms
+	assert.EqualValues(t, 500, apiusage.Percentile(buckets, 0.95))
+	assert.EqualValues(t, 50, apiusage.Percentile(buckets, 0.90))
+
+	buckets[len(buckets)-1] = 1000
+	assert.EqualValues(t, 10000, apiusage.Percentile(buckets, 0.95), "overflow reports the last bound")
+}
+
+func TestReport_ComputesRatesAndValidatesFilter(t *testing.T) {
+	userID := uuid.New()
+	buckets := make([]int64, len(apiusage.LatencyBoundsMs)+1)
+	buckets[4] = 20 // <= 100ms
+	repo := &fakeRepo{results: []*entities.APIUsageRollup{{
+		UserID:           userID,
+		Method:           "GET",
+		Route:            "/api/v1/market/quotes",
+		RequestCount:     20,
+		ClientErrorCount: 1,
+		ServerErrorCount: 3,
+		TotalDurationMs:  1200,
+		LatencyBuckets:   buckets,
+	}}}
+	now := time.Date(2026, 3, 2, 14, 37, 0, 0, time.UTC)
+	svc := newService(repo, now)
+	ctx := context.Background()
+
+	report, err := svc.Report(ctx, entities.APIUsageFilter{Limit: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, entities.APIUsageByUserEndpoint, report.GroupBy)
+	assert.Equal(t, 50, repo.filter.Limit)
+	assert.Equal(t, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), repo.filter.Since)
+	assert.Equal(t, now, repo.filter.Until)
+
+	require.Len(t, report.Stats, 1)
+	stat := report.Stats[0]
+	assert.Equal(t, &userID, stat.UserID)
+	assert.InDelta(t, 0.2, stat.ErrorRate, 0.0001)
+	assert.InDelta(t, 60, stat.AvgLatencyMs, 0.0001)
+	assert.EqualValues(t, 100, stat.P95LatencyMs)
+
+	report, err = svc.Report(ctx, entities.APIUsageFilter{GroupBy: entities.APIUsageByEndpoint})
+	require.NoError(t, err)
+	assert.Nil(t, report.Stats[0].UserID)
+
+	_, err = svc.Report(ctx, entities.APIUsageFilter{GroupBy: "device"})
+	assert.ErrorIs(t, err, entities.ErrInvalidAPIUsageFilter)
+
+	_, err = svc.Report(ctx, entities.APIUsageFilter{Since: now, Until: now.Add(-time.Hour)})
+	assert.ErrorIs(t, err, entities.ErrInvalidAPIUsageFilter)
+}
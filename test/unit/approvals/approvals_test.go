@@ -0,0 +1,389 @@
+package approvals_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/internal/domain/services/approvals"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeRepo struct {
+	requests map[uuid.UUID]*entities.ApprovalRequest
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{requests: map[uuid.UUID]*entities.ApprovalRequest{}}
+}
+
+func (f *fakeRepo) Create(ctx context.Context, request *entities.ApprovalRequest) (*entities.ApprovalRequest, bool, error) {
+	for _, existing := range f.requests {
+		if existing.Action == request.Action && existing.ResourceID == request.ResourceID && existing.Status == entities.ApprovalPending {
+			return f.copy(existing), false, nil
+		}
+	}
+	stored := *request
+	f.requests[stored.ID] = &stored
+	return f.copy(&stored), true, nil
+}
+
+func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.ApprovalRequest, error) {
+	request, ok := f.requests[id]
+	if !ok {
+		return nil, entities.ErrApprovalNotFound
+	}
+	return f.copy(request), nil
+}
+
+func (f *fakeRepo) List(ctx context.Context, status entities.ApprovalStatus, action entities.ApprovalAction, limit, offset int) ([]*entities.ApprovalRequest, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) AddDecision(ctx context.Context, requestID uuid.UUID, decision entities.ApprovalDecision) (bool, error) {
+	request := f.requests[requestID]
+	for _, existing := range request.Decisions {
+		if existing.AdminID == decision.AdminID {
+			return false, nil
+		}
+	}
+	request.Decisions = append(request.Decisions, decision)
+	return true, nil
+}
+
+func (f *fakeRepo) Resolve(ctx context.Context, id uuid.UUID, from, to entities.ApprovalStatus, executionError *string, at time.Time) (bool, error) {
+	request := f.requests[id]
+	if request.Status != from {
+		return false, nil
+	}
+	request.Status = to
+	if executionError != nil {
+		request.ExecutionError = executionError
+	}
+	return true, nil
+}
+
+func (f *fakeRepo) copy(request *entities.ApprovalRequest) *entities.ApprovalRequest {
+	copied := *request
+	copied.Decisions = append([]entities.ApprovalDecision(nil), request.Decisions...)
+	return &copied
+}
+
+type fakeHandler struct {
+	approved []string
+	rejected []string
+	err      error
+}
+
+func (h *fakeHandler) Approved(ctx context.Context, request *entities.ApprovalRequest) error {
+	h.approved = append(h.approved, request.ResourceID)
+	return h.err
+}
+
+func (h *fakeHandler) Rejected(ctx context.Context, request *entities.ApprovalRequest) error {
+	h.rejected = append(h.rejected, request.ResourceID)
+	return nil
+}
+
+func newService(t *testing.T, policy approvals.Policy) (*approvals.Service, *fakeRepo, *fakeHandler) {
+	t.Helper()
+	repo := newFakeRepo()
+	handler := &fakeHandler{}
+	svc := approvals.NewService(repo, zap.NewNop())
+	svc.Register(entities.ApprovalActionBasketDelete, policy, handler)
+	return svc, repo, handler
+}
+
+func submit(t *testing.T, svc *approvals.Service, requester *uuid.UUID) *entities.ApprovalRequest {
+	t.Helper()
+	request, err := svc.Submit(context.Background(), &entities.SubmitApprovalRequest{
+		Action:      entities.ApprovalActionBasketDelete,
+		ResourceID:  "basket-1",
+		RequestedBy: requester,
+		Summary:     "Delete basket",
+	})
+	require.NoError(t, err)
+	return request
+}
+
+func TestApprove_WaitsForRequiredApprovals(t *testing.T) {
+	svc, _, handler := newService(t, approvals.Policy{RequiredApprovals: 2})
+	request := submit(t, svc, nil)
+	ctx := context.Background()
+
+	first, err := svc.Approve(ctx, request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApprovalPending, first.Status)
+	assert.Empty(t, handler.approved)
+
+	second, err := svc.Approve(ctx, request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApprovalApproved, second.Status)
+	assert.Equal(t, []string{"basket-1"}, handler.approved)
+
+	_, err = svc.Approve(ctx, request.ID, uuid.New(), nil)
+	assert.ErrorIs(t, err, entities.ErrApprovalNotPending)
+}
+
+func TestSubmit_ReturnsPendingRequestForSameResource(t *testing.T) {
+	svc, _, _ := newService(t, approvals.Policy{RequiredApprovals: 1})
+
+	first := submit(t, svc, nil)
+	second := submit(t, svc, nil)
+
+	assert.Equal(t, first.ID, second.ID)
+}
+
+func TestSubmit_UnknownAction(t *testing.T) {
+	svc, _, _ := newService(t, approvals.Policy{RequiredApprovals: 1})
+
+	_, err := svc.Submit(context.Background(), &entities.SubmitApprovalRequest{
+		Action:     entities.ApprovalActionWithdrawal,
+		ResourceID: uuid.NewString(),
+	})
+
+	assert.ErrorIs(t, err, entities.ErrApprovalUnknownAction)
+}
+
+func TestReview_RejectsRequesterAndRepeatVotes(t *testing.T) {
+	svc, _, _ := newService(t, approvals.Policy{RequiredApprovals: 2})
+	requester := uuid.New()
+	request := submit(t, svc, &requester)
+	ctx := context.Background()
+
+	_, err := svc.Approve(ctx, request.ID, requester, nil)
+	assert.ErrorIs(t, err, entities.ErrApprovalSelfReview)
+
+	reviewer := uuid.New()
+	_, err = svc.Approve(ctx, request.ID, reviewer, nil)
+	require.NoError(t, err)
+	_, err = svc.Approve(ctx, request.ID, reviewer, nil)
+	assert.ErrorIs(t, err, entities.ErrApprovalAlreadyDecided)
+}
+
+func TestReview_OnlyListedApprovers(t *testing.T) {
+	approver := uuid.New()
+	svc, _, _ := newService(t, approvals.Policy{RequiredApprovals: 1, Approvers: []uuid.UUID{approver}})
+	request := submit(t, svc, nil)
+	ctx := context.Background()
+
+	_, err := svc.Approve(ctx, request.ID, uuid.New(), nil)
+	assert.ErrorIs(t, err, entities.ErrApprovalNotApprover)
+
+	approved, err := svc.Approve(ctx, request.ID, approver, nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApprovalApproved, approved.Status)
+}
+
+func TestReject_ResolvesOnFirstRejection(t *testing.T) {
+	svc, _, handler := newService(t, approvals.Policy{RequiredApprovals: 2})
+	request := submit(t, svc, nil)
+	ctx := context.Background()
+
+	_, err := svc.Approve(ctx, request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+	rejected, err := svc.Reject(ctx, request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.ApprovalRejected, rejected.Status)
+	assert.Equal(t, []string{"basket-1"}, handler.rejected)
+	assert.Empty(t, handler.approved)
+}
+
+func TestApprove_HandlerFailureMarksRequestFailed(t *testing.T) {
+	svc, repo, handler := newService(t, approvals.Policy{RequiredApprovals: 1})
+	handler.err = errors.New("basket has orders or positions")
+	request := submit(t, svc, nil)
+
+	result, err := svc.Approve(context.Background(), request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.ApprovalFailed, result.Status)
+	require.NotNil(t, repo.requests[request.ID].ExecutionError)
+	assert.Equal(t, "basket has orders or positions", *repo.requests[request.ID].ExecutionError)
+}
+
+type fakeWithdrawals struct {
+	withdrawals map[uuid.UUID]*entities.Withdrawal
+}
+
+func (f *fakeWithdrawals) Create(ctx context.Context, withdrawal *entities.Withdrawal) error {
+	stored := *withdrawal
+	f.withdrawals[stored.ID] = &stored
+	return nil
+}
+
+func (f *fakeWithdrawals) GetByID(ctx context.Context, id uuid.UUID) (*entities.Withdrawal, error) {
+	withdrawal, ok := f.withdrawals[id]
+	if !ok {
+		return nil, errors.New("withdrawal not found")
+	}
+	copied := *withdrawal
+	return &copied, nil
+}
+
+func (f *fakeWithdrawals) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Withdrawal, error) {
+	return nil, nil
+}
+
+func (f *fakeWithdrawals) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.WithdrawalStatus) error {
+	f.withdrawals[id].Status = status
+	return nil
+}
+
+func (f *fakeWithdrawals) UpdateAlpacaJournal(ctx context.Context, id uuid.UUID, journalID string) error {
+	return nil
+}
+
+func (f *fakeWithdrawals) UpdateDueTransfer(ctx context.Context, id uuid.UUID, transferID, recipientID string) error {
+	return nil
+}
+
+func (f *fakeWithdrawals) UpdateTxHash(ctx context.Context, id uuid.UUID, txHash string) error {
+	return nil
+}
+
+func (f *fakeWithdrawals) MarkCompleted(ctx context.Context, id uuid.UUID) error {
+	f.withdrawals[id].Status = entities.WithdrawalStatusCompleted
+	return nil
+}
+
+func (f *fakeWithdrawals) MarkFailed(ctx context.Context, id uuid.UUID, errorMsg string) error {
+	f.withdrawals[id].Status = entities.WithdrawalStatusFailed
+	f.withdrawals[id].ErrorMessage = &errorMsg
+	return nil
+}
+
+type fakeAlpaca struct{}
+
+func (fakeAlpaca) GetAccount(ctx context.Context, accountID string) (*entities.AlpacaAccountResponse, error) {
+	return &entities.AlpacaAccountResponse{
+		Status:      entities.AlpacaAccountStatusActive,
+		BuyingPower: decimal.NewFromInt(100000),
+	}, nil
+}
+
+func (fakeAlpaca) CreateJournal(ctx context.Context, req *entities.AlpacaJournalRequest) (*entities.AlpacaJournalResponse, error) {
+	return &entities.AlpacaJournalResponse{ID: uuid.NewString()}, nil
+}
+
+type recordingPublisher struct {
+	messages []interface{}
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, queueName string, message interface{}) error {
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+type fakeHolds struct {
+	released []uuid.UUID
+}
+
+func (f *fakeHolds) Place(ctx context.Context, userID uuid.UUID, kind entities.HoldKind, referenceID uuid.UUID, amount decimal.Decimal) (*entities.BalanceHold, error) {
+	return &entities.BalanceHold{ID: uuid.New()}, nil
+}
+
+func (f *fakeHolds) Capture(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	return &entities.BalanceHold{ID: uuid.New()}, nil
+}
+
+func (f *fakeHolds) Release(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	f.released = append(f.released, referenceID)
+	return &entities.BalanceHold{ID: uuid.New()}, nil
+}
+
+type withdrawalFixture struct {
+	approvals   *approvals.Service
+	service     *services.WithdrawalService
+	withdrawals *fakeWithdrawals
+	publisher   *recordingPublisher
+	holds       *fakeHolds
+}
+
+func newWithdrawalFixture() *withdrawalFixture {
+	f := &withdrawalFixture{
+		approvals:   approvals.NewService(newFakeRepo(), zap.NewNop()),
+		withdrawals: &fakeWithdrawals{withdrawals: map[uuid.UUID]*entities.Withdrawal{}},
+		publisher:   &recordingPublisher{},
+		holds:       &fakeHolds{},
+	}
+	f.service = services.NewWithdrawalService(f.withdrawals, fakeAlpaca{}, nil, nil, nil, logger.New("error", "test"), f.publisher)
+	f.service.SetBalanceHolds(f.holds)
+	f.approvals.Register(entities.ApprovalActionWithdrawal, approvals.Policy{RequiredApprovals: 1}, f.service.ApprovalHandler())
+	f.service.SetApprovals(f.approvals, decimal.NewFromInt(10000))
+	return f
+}
+
+func (f *withdrawalFixture) initiate(t *testing.T, amount int64) *entities.InitiateWithdrawalResponse {
+	t.Helper()
+	resp, err := f.service.InitiateWithdrawal(context.Background(), &entities.InitiateWithdrawalRequest{
+		UserID:             uuid.New(),
+		AlpacaAccountID:    "acct-1",
+		Amount:             decimal.NewFromInt(amount),
+		DestinationChain:   "SOL",
+		DestinationAddress: "addr",
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestWithdrawal_BelowThresholdIsProcessed(t *testing.T) {
+	f := newWithdrawalFixture()
+
+	resp := f.initiate(t, 500)
+
+	assert.Equal(t, entities.WithdrawalStatusPending, resp.Status)
+	assert.Len(t, f.publisher.messages, 1)
+}
+
+func TestWithdrawal_AboveThresholdWaitsForApproval(t *testing.T) {
+	f := newWithdrawalFixture()
+	ctx := context.Background()
+
+	resp := f.initiate(t, 25000)
+	assert.Equal(t, entities.WithdrawalStatusPendingApproval, resp.Status)
+	assert.Empty(t, f.publisher.messages)
+
+	request, err := f.approvals.Submit(ctx, &entities.SubmitApprovalRequest{
+		Action:     entities.ApprovalActionWithdrawal,
+		ResourceID: resp.WithdrawalID.String(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "25000", request.Payload["amount"])
+
+	approved, err := f.approvals.Approve(ctx, request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.ApprovalApproved, approved.Status)
+	assert.Equal(t, entities.WithdrawalStatusPending, f.withdrawals.withdrawals[resp.WithdrawalID].Status)
+	assert.Len(t, f.publisher.messages, 1)
+}
+
+func TestWithdrawal_RejectionFailsAndReleasesHold(t *testing.T) {
+	f := newWithdrawalFixture()
+	ctx := context.Background()
+
+	resp := f.initiate(t, 25000)
+	request, err := f.approvals.Submit(ctx, &entities.SubmitApprovalRequest{
+		Action:     entities.ApprovalActionWithdrawal,
+		ResourceID: resp.WithdrawalID.String(),
+	})
+	require.NoError(t, err)
+
+	_, err = f.approvals.Reject(ctx, request.ID, uuid.New(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.WithdrawalStatusFailed, f.withdrawals.withdrawals[resp.WithdrawalID].Status)
+	assert.Equal(t, []uuid.UUID{resp.WithdrawalID}, f.holds.released)
+	assert.Empty(t, f.publisher.messages)
+}
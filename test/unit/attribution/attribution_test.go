@@ -0,0 +1,189 @@
+package attribution_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/attribution"
+)
+
+type fakePositions struct{ positions []*entities.Position }
+
+func (f *fakePositions) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
+	return f.positions, nil
+}
+
+type fakeBaskets struct {
+	baskets map[uuid.UUID]*entities.Basket
+}
+
+func (f *fakeBaskets) GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error) {
+	basket, ok := f.baskets[id]
+	if !ok {
+		return nil, errors.New("basket not found")
+	}
+	return basket, nil
+}
+
+type fakePrices map[string]attribution.PriceChange
+
+func (f fakePrices) GetPriceChanges(ctx context.Context, symbols []string, start, end time.Time) (map[string]attribution.PriceChange, error) {
+	changes := map[string]attribution.PriceChange{}
+	for _, symbol := range symbols {
+		if change, ok := f[symbol]; ok {
+			changes[symbol] = change
+		}
+	}
+	return changes, nil
+}
+
+type fakeHistory struct{ points []*entities.PerformancePoint }
+
+func (f *fakeHistory) GetPortfolioPerformance(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]*entities.PerformancePoint, error) {
+	return f.points, nil
+}
+
+func d(value string) decimal.Decimal { return decimal.RequireFromString(value) }
+
+func change(start, end string) attribution.PriceChange {
+	return attribution.PriceChange{Start: d(start), End: d(end)}
+}
+
+// newService holds a tech basket up 10% (AAA +20%, BBB flat) and a bond
+// basket down 2%, each worth $1,000 at the start, against SPY up 3%
+func newService(prices fakePrices) *attribution.Service {
+	tech := &entities.Basket{ID: uuid.New(), Name: "Tech", Composition: []entities.BasketComponent{
+		{Symbol: "AAA", Weight: d("0.5")}, {Symbol: "BBB", Weight: d("0.5")},
+	}}
+	bonds := &entities.Basket{ID: uuid.New(), Name: "Bonds", Composition: []entities.BasketComponent{
+		{Symbol: "CCC", Weight: d("1")},
+	}}
+	positions := &fakePositions{positions: []*entities.Position{
+		{BasketID: bonds.ID, MarketValue: d("980")},
+		{BasketID: tech.ID, MarketValue: d("1100")},
+	}}
+	baskets := &fakeBaskets{baskets: map[uuid.UUID]*entities.Basket{tech.ID: tech, bonds.ID: bonds}}
+	return attribution.NewService(positions, baskets, prices, attribution.DefaultConfig(), zap.NewNop())
+}
+
+func defaultPrices() fakePrices {
+	return fakePrices{
+		"AAA": change("100", "120"),
+		"BBB": change("100", "100"),
+		"CCC": change("50", "49"),
+		"SPY": change("400", "412"),
+	}
+}
+
+func TestAttribute_SplitsReturnByBasketAndComponent(t *testing.T) {
+	svc := newService(defaultPrices())
+
+	report, err := svc.Attribute(context.Background(), uuid.New(), entities.AttributionRange1M)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.04", report.PortfolioReturn.String())
+	assert.Equal(t, "0.03", report.BenchmarkReturn.String())
+	assert.Equal(t, "0.01", report.ActiveReturn.String())
+	assert.Equal(t, "2000 USD", report.StartValue.String())
+	assert.Equal(t, "2080 USD", report.EndValue.String())
+
+	require.Len(t, report.Baskets, 2)
+	tech, bonds := report.Baskets[0], report.Baskets[1]
+	assert.Equal(t, "Tech", tech.BasketName, "baskets are ordered by contribution")
+	assert.Equal(t, "0.5", tech.Weight.String())
+	assert.Equal(t, "0.1", tech.Return.String())
+	assert.Equal(t, "0.05", tech.Contribution.String())
+	assert.Equal(t, "0.015", tech.MarketEffect.String())
+	assert.Equal(t, "0.035", tech.SelectionEffect.String())
+	assert.Equal(t, "100 USD", tech.Gain.String())
+	assert.Equal(t, "-0.01", bonds.Contribution.String())
+	assert.Equal(t, "-0.025", bonds.SelectionEffect.String())
+
+	assert.True(t, tech.SelectionEffect.Add(bonds.SelectionEffect).Equal(report.ActiveReturn),
+		"selection effects sum to the active return")
+	assert.True(t, tech.MarketEffect.Add(tech.SelectionEffect).Equal(tech.Contribution))
+
+	require.Len(t, tech.Components, 2)
+	top := tech.Components[0]
+	assert.Equal(t, "AAA", top.Symbol)
+	assert.Equal(t, "0.25", top.PortfolioWeight.String())
+	assert.Equal(t, "0.05", top.Contribution.String())
+	assert.Equal(t, "0.0425", top.SelectionEffect.String())
+	assert.True(t, top.Priced)
+
+	assert.Equal(t, []string{
+		"Your portfolio returned +4.00% over 1M against +3.00% for SPY.",
+		"Tech contributed the most, +5.00% of the return (+3.50% selection vs SPY).",
+		"Bonds was the biggest detractor at -1.00%.",
+		"The best component was AAA, up +20.00% and adding +5.00%.",
+	}, report.Highlights)
+}
+
+func TestAttribute_ComparesWithRecordedNAV(t *testing.T) {
+	svc := newService(defaultPrices())
+	svc.SetPerformanceHistory(&fakeHistory{points: []*entities.PerformancePoint{
+		{Value: entities.NewMoney(d("2000"), entities.CurrencyUSD)},
+		{Value: entities.NewMoney(d("2200"), entities.CurrencyUSD)},
+	}})
+
+	report, err := svc.Attribute(context.Background(), uuid.New(), entities.AttributionRange3M)
+	require.NoError(t, err)
+
+	require.NotNil(t, report.ReportedReturn)
+	assert.Equal(t, "0.1", report.ReportedReturn.String())
+	assert.Equal(t, "0.06", report.Unexplained.String())
+	assert.Contains(t, report.Highlights, "Deposits, withdrawals and trades moved the recorded return by a further +6.00%.")
+}
+
+func TestAttribute_UnpricedComponentsEarnNothing(t *testing.T) {
+	prices := defaultPrices()
+	delete(prices, "AAA")
+	svc := newService(prices)
+
+	report, err := svc.Attribute(context.Background(), uuid.New(), entities.AttributionRange1W)
+	require.NoError(t, err)
+
+	for _, basket := range report.Baskets {
+		for _, component := range basket.Components {
+			if component.Symbol == "AAA" {
+				assert.False(t, component.Priced)
+				assert.True(t, component.Return.IsZero())
+			}
+		}
+	}
+}
+
+func TestAttribute_NoPositions(t *testing.T) {
+	svc := attribution.NewService(&fakePositions{}, &fakeBaskets{}, fakePrices{}, attribution.Config{}, zap.NewNop())
+
+	report, err := svc.Attribute(context.Background(), uuid.New(), entities.AttributionRangeYTD)
+	require.NoError(t, err)
+	assert.Empty(t, report.Baskets)
+	assert.True(t, report.PortfolioReturn.IsZero())
+	assert.Equal(t, "SPY", report.Benchmark)
+	assert.Equal(t, []string{"No invested positions over this period."}, report.Highlights)
+}
+
+func TestRangeStart(t *testing.T) {
+	end := time.Date(2026, time.March, 31, 15, 0, 0, 0, time.UTC)
+
+	start, err := attribution.RangeStart(entities.AttributionRangeYTD, end)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), start)
+
+	start, err = attribution.RangeStart(entities.AttributionRange1Y, end)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.March, 31, 15, 0, 0, 0, time.UTC), start)
+
+	_, err = attribution.RangeStart("5Y", end)
+	assert.ErrorIs(t, err, attribution.ErrInvalidRange)
+}
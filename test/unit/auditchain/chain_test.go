@@ -0,0 +1,236 @@
+package auditchain_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/adapters/auditanchor"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+)
+
+var genesis = strings.Repeat("0", 64)
+
+func strPtr(s string) *string { return &s }
+
+func newEntry(action string) adapters.AuditChainEntry {
+	userID := uuid.New()
+	amount := decimal.RequireFromString("125.50")
+	return adapters.AuditChainEntry{
+		ID:           uuid.New(),
+		UserID:       &userID,
+		Actor:        strPtr("funding-service"),
+		Action:       action,
+		ResourceType: "deposit",
+		ResourceID:   strPtr(uuid.NewString()),
+		Entity:       strPtr("deposit"),
+		Before:       []byte(`{"status":"pending"}`),
+		After:        []byte(`{"status": "completed", "amount": "125.50"}`),
+		Changes:      []byte(`{"b":2,"a":1}`),
+		Status:       "success",
+		IPAddress:    strPtr("203.0.113.7"),
+		UserAgent:    strPtr("stack-ios/4.2"),
+		Amount:       &amount,
+		Currency:     strPtr("USD"),
+		Signature:    "sig",
+		At:           time.Date(2026, 3, 2, 15, 4, 5, 123456789, time.UTC),
+	}
+}
+
+// buildChain appends n entries the way the audit service does
+func buildChain(t *testing.T, n int) []*adapters.AuditChainEntry {
+	t.Helper()
+	var chain []*adapters.AuditChainEntry
+	seq, head := int64(0), genesis
+	for i := 0; i < n; i++ {
+		entry := newEntry("deposit_completed")
+		require.NoError(t, entry.Link(seq, head))
+		chain = append(chain, &entry)
+		seq, head = entry.ChainSeq, entry.EntryHash
+	}
+	return chain
+}
+
+func verify(chain []*adapters.AuditChainEntry) *adapters.AuditChainVerifier {
+	verifier := adapters.NewAuditChainVerifier(0, "")
+	for _, entry := range chain {
+		copied := *entry
+		verifier.Add(&copied)
+	}
+	return verifier
+}
+
+func TestChainVerifiesAfterAppend(t *testing.T) {
+	chain := buildChain(t, 3)
+
+	report := verify(chain).Report()
+	assert.True(t, report.Valid, "%+v", report.Breaks)
+	assert.Equal(t, int64(3), report.EntriesChecked)
+	assert.Equal(t, chain[2].EntryHash, report.HeadHash)
+	assert.Equal(t, chain[0].EntryHash, chain[1].PrevHash)
+}
+
+func TestChainDetectsTamperingWithAnyColumn(t *testing.T) {
+	tamper := map[string]func(e *adapters.AuditChainEntry){
+		"actor":         func(e *adapters.AuditChainEntry) { e.Actor = strPtr("admin") },
+		"entity":        func(e *adapters.AuditChainEntry) { e.Entity = strPtr("withdrawal") },
+		"before":        func(e *adapters.AuditChainEntry) { e.Before = []byte(`{"status":"failed"}`) },
+		"after":         func(e *adapters.AuditChainEntry) { e.After = nil },
+		"error_message": func(e *adapters.AuditChainEntry) { e.ErrorMessage = strPtr("") },
+		"ip_address":    func(e *adapters.AuditChainEntry) { e.IPAddress = strPtr("198.51.100.1") },
+		"user_agent":    func(e *adapters.AuditChainEntry) { e.UserAgent = strPtr("curl/8.0") },
+		"signature":     func(e *adapters.AuditChainEntry) { e.Signature = "forged" },
+		"amount":        func(e *adapters.AuditChainEntry) { e.Amount = nil },
+	}
+	for column, change := range tamper {
+		t.Run(column, func(t *testing.T) {
+			chain := buildChain(t, 3)
+			change(chain[1])
+
+			report := verify(chain).Report()
+			require.False(t, report.Valid)
+			require.Len(t, report.Breaks, 1)
+			assert.Equal(t, int64(2), report.Breaks[0].ChainSeq)
+			assert.Equal(t, "entry hash mismatch", report.Breaks[0].Reason)
+		})
+	}
+}
+
+func TestChainHashSeparatesFields(t *testing.T) {
+	first := newEntry("x")
+	second := first
+	first.Action, first.ResourceType = "svc:a|b", "c"
+	second.Action, second.ResourceType = "svc:a", "b|c"
+	require.NoError(t, first.Link(0, genesis))
+	require.NoError(t, second.Link(0, genesis))
+	assert.NotEqual(t, first.EntryHash, second.EntryHash)
+
+	empty, null := newEntry("x"), newEntry("x")
+	empty.ID, empty.ErrorMessage, null.ErrorMessage = null.ID, strPtr(""), nil
+	require.NoError(t, empty.Link(0, genesis))
+	require.NoError(t, null.Link(0, genesis))
+	assert.NotEqual(t, empty.EntryHash, null.EntryHash)
+}
+
+func TestChainVerifiesLegacyEntries(t *testing.T) {
+	legacy := newEntry("x")
+	legacy.ChainSeq, legacy.PrevHash, legacy.HashVersion = 1, genesis, 1
+	legacy.EntryHash = legacy.Hash()
+
+	next := newEntry("y")
+	require.NoError(t, next.Link(legacy.ChainSeq, legacy.EntryHash))
+	assert.Equal(t, 2, next.HashVersion)
+
+	report := verify([]*adapters.AuditChainEntry{&legacy, &next}).Report()
+	assert.True(t, report.Valid, "%+v", report.Breaks)
+}
+
+type fakeAnchorStore struct {
+	anchors map[string][]byte
+	err     error
+}
+
+func (f *fakeAnchorStore) StoreAnchor(ctx context.Context, key string, payload []byte) (string, error) {
+	uri := "0g://audit-anchors/" + key
+	f.anchors[uri] = payload
+	return uri, nil
+}
+
+func (f *fakeAnchorStore) LoadAnchor(ctx context.Context, uri string) ([]byte, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.anchors[uri], nil
+}
+
+func anchorOf(t *testing.T, store *fakeAnchorStore, entry *adapters.AuditChainEntry, headHash string) adapters.AuditChainAnchor {
+	t.Helper()
+	anchor := adapters.AuditChainAnchor{ID: uuid.New(), ChainSeq: entry.ChainSeq, HeadHash: headHash, AnchoredAt: time.Now()}
+	payload, err := json.Marshal(anchor)
+	require.NoError(t, err)
+	uri, err := store.StoreAnchor(context.Background(), anchor.ID.String(), payload)
+	require.NoError(t, err)
+	anchor.StorageURI = &uri
+	return anchor
+}
+
+func TestExternalAnchorMustMatchChain(t *testing.T) {
+	chain := buildChain(t, 3)
+	store := &fakeAnchorStore{anchors: map[string][]byte{}}
+	anchor := anchorOf(t, store, chain[1], chain[1].EntryHash)
+
+	verifier := verify(chain)
+	verifier.CheckAnchor(context.Background(), anchor, store)
+	report := verifier.Report()
+	assert.True(t, report.Valid, "%+v", report.Breaks)
+	assert.Equal(t, 1, report.ExternalAnchorsChecked)
+
+	// The database copy was rewritten along with the chain, but the external
+	// copy still holds the original head
+	rewritten := anchorOf(t, store, chain[1], strings.Repeat("a", 64))
+	rewritten.HeadHash = chain[1].EntryHash
+	verifier = verify(chain)
+	verifier.CheckAnchor(context.Background(), rewritten, store)
+	report = verifier.Report()
+	require.False(t, report.Valid)
+	assert.Equal(t, "external anchor mismatch", report.Breaks[0].Reason)
+
+	store.err = errors.New("indexer unavailable")
+	verifier = verify(chain)
+	verifier.CheckAnchor(context.Background(), anchor, store)
+	report = verifier.Report()
+	require.False(t, report.Valid)
+	assert.Equal(t, "external anchor unreadable", report.Breaks[0].Reason)
+}
+
+func TestZeroGAnchorStoreUploadsAndReadsBack(t *testing.T) {
+	root := "0x" + strings.Repeat("ab", 32)
+	indexer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file" || r.URL.Query().Get("root") != root {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"chain_seq":7}`))
+	}))
+	defer indexer.Close()
+
+	store, err := auditanchor.NewZeroGStore(auditanchor.ZeroGConfig{
+		UploadCommand: "0g-storage-client",
+		RPCEndpoint:   "https://evmrpc.example",
+		IndexerRPC:    indexer.URL,
+		PrivateKey:    "key",
+		Namespace:     "audit-anchors/",
+	})
+	require.NoError(t, err)
+
+	var args []string
+	store.SetRunner(func(ctx context.Context, name string, a ...string) ([]byte, error) {
+		args = a
+		return []byte("level=info msg=\"Data prepared to upload\" root=" + root + "\nlevel=info msg=\"upload took\"\n"), nil
+	})
+	uri, err := store.StoreAnchor(context.Background(), "audit-anchors/7.json", []byte(`{"chain_seq":7}`))
+	require.NoError(t, err)
+	assert.Equal(t, "0g://audit-anchors/"+root, uri)
+	assert.Equal(t, "upload", args[0])
+
+	payload, err := store.LoadAnchor(context.Background(), uri)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chain_seq":7}`, string(payload))
+
+	store.SetRunner(func(ctx context.Context, name string, a ...string) ([]byte, error) {
+		return []byte("level=error msg=\"insufficient funds\""), errors.New("exit status 1")
+	})
+	_, err = store.StoreAnchor(context.Background(), "audit-anchors/8.json", []byte(`{}`))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "key")
+}
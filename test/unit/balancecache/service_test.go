@@ -0,0 +1,284 @@
+package balancecache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/balancecache"
+)
+
+type fakeRepo struct {
+	mu      sync.Mutex
+	wallets map[uuid.UUID]*entities.WalletBalanceSnapshot
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{wallets: map[uuid.UUID]*entities.WalletBalanceSnapshot{}}
+}
+
+func (f *fakeRepo) addWallet(userID uuid.UUID, circleWalletID string, syncedAt *time.Time, balance string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := uuid.New()
+	f.wallets[id] = &entities.WalletBalanceSnapshot{
+		WalletID:       id,
+		UserID:         userID,
+		CircleWalletID: circleWalletID,
+		USDCBalance:    decimal.RequireFromString(balance),
+		LastSyncedAt:   syncedAt,
+	}
+}
+
+func (f *fakeRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WalletBalanceSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*entities.WalletBalanceSnapshot
+	for _, w := range f.wallets {
+		if w.UserID == userID {
+			copied := *w
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) ListStale(ctx context.Context, syncedBefore time.Time, limit int) ([]*entities.WalletBalanceSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*entities.WalletBalanceSnapshot
+	for _, w := range f.wallets {
+		if w.LastSyncedAt == nil || w.LastSyncedAt.Before(syncedBefore) {
+			copied := *w
+			out = append(out, &copied)
+		}
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) Save(ctx context.Context, snapshot *entities.WalletBalanceSnapshot) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *snapshot
+	f.wallets[snapshot.WalletID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) RecordError(ctx context.Context, walletID, userID uuid.UUID, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.wallets[walletID].LastError = &message
+	return nil
+}
+
+func (f *fakeRepo) SaveBulk(ctx context.Context, balances map[string]decimal.Decimal, syncedAt time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	saved := 0
+	for _, w := range f.wallets {
+		if balance, ok := balances[w.CircleWalletID]; ok {
+			at := syncedAt
+			w.USDCBalance = balance
+			w.LastSyncedAt = &at
+			saved++
+		}
+	}
+	return saved, nil
+}
+
+func (f *fakeRepo) Sum(ctx context.Context, syncedSince time.Time) (*entities.BalanceAggregate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	aggregate := &entities.BalanceAggregate{}
+	for _, w := range f.wallets {
+		aggregate.Total = aggregate.Total.Add(w.USDCBalance)
+		if w.LastSyncedAt != nil && !w.LastSyncedAt.Before(syncedSince) {
+			aggregate.Counted++
+		} else {
+			aggregate.Skipped++
+		}
+	}
+	return aggregate, nil
+}
+
+type fakeCircle struct {
+	mu       sync.Mutex
+	balances map[string]string
+	calls    int
+}
+
+func (f *fakeCircle) GetWalletBalances(ctx context.Context, walletID string, tokenAddress ...string) (*entities.CircleWalletBalancesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	amount, ok := f.balances[walletID]
+	if !ok {
+		return nil, errors.New("circle unavailable")
+	}
+	return &entities.CircleWalletBalancesResponse{TokenBalances: []entities.CircleTokenBalance{
+		{Token: entities.CircleTokenInfo{Symbol: "USDC"}, Amount: amount},
+	}}, nil
+}
+
+func (f *fakeCircle) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (f *fakeCounter) Incr(ctx context.Context, key string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[key]++
+	return f.counts[key], nil
+}
+
+func (f *fakeCounter) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return nil
+}
+
+func newService(repo *fakeRepo, circle balancecache.CircleClient) *balancecache.Service {
+	return balancecache.NewService(repo, circle, &fakeCounter{counts: map[string]int64{}}, balancecache.Config{
+		MaxAge:            time.Minute,
+		BatchSize:         2,
+		ForceRefreshLimit: 2,
+	}, zap.NewNop())
+}
+
+func ago(d time.Duration) *time.Time {
+	t := time.Now().UTC().Add(-d)
+	return &t
+}
+
+func TestGetUserBalances_ServesCacheWithoutCallingCircle(t *testing.T) {
+	repo, circle := newFakeRepo(), &fakeCircle{balances: map[string]string{"w1": "99", "w2": "99"}}
+	user := uuid.New()
+	synced := ago(10 * time.Second)
+	repo.addWallet(user, "w1", synced, "10.5")
+	repo.addWallet(user, "w2", ago(5*time.Second), "4.5")
+
+	balances, err := newService(repo, circle).GetUserBalances(context.Background(), user)
+	require.NoError(t, err)
+
+	assert.Equal(t, "15", balances.TotalUSDC.String())
+	assert.False(t, balances.Stale)
+	require.NotNil(t, balances.LastSyncedAt)
+	assert.True(t, balances.LastSyncedAt.Equal(*synced), "reports the oldest wallet sync")
+	assert.Zero(t, circle.callCount())
+}
+
+func TestGetUserBalances_ReadsUnsyncedWalletsAndFlagsStale(t *testing.T) {
+	repo, circle := newFakeRepo(), &fakeCircle{balances: map[string]string{"new": "7"}}
+	user := uuid.New()
+	repo.addWallet(user, "new", nil, "0")
+	repo.addWallet(user, "old", ago(time.Hour), "3")
+
+	balances, err := newService(repo, circle).GetUserBalances(context.Background(), user)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10", balances.TotalUSDC.String())
+	assert.True(t, balances.Stale)
+	assert.Equal(t, 1, circle.callCount(), "only the never-synced wallet is read inline")
+}
+
+func TestForceRefresh_IsRateLimitedPerUser(t *testing.T) {
+	repo, circle := newFakeRepo(), &fakeCircle{balances: map[string]string{"w1": "12"}}
+	user := uuid.New()
+	repo.addWallet(user, "w1", ago(time.Hour), "1")
+	svc := newService(repo, circle)
+
+	for i := 0; i < 2; i++ {
+		balances, err := svc.ForceRefresh(context.Background(), user)
+		require.NoError(t, err)
+		assert.Equal(t, "12", balances.TotalUSDC.String())
+		assert.False(t, balances.Stale)
+	}
+	_, err := svc.ForceRefresh(context.Background(), user)
+	assert.ErrorIs(t, err, entities.ErrBalanceRefreshRateLimited)
+
+	_, err = svc.ForceRefresh(context.Background(), uuid.New())
+	assert.NoError(t, err, "the limit is per user")
+}
+
+func TestTotalUSDC_RefreshesStaleWalletsAndKeepsLastKnownOnFailure(t *testing.T) {
+	repo := newFakeRepo()
+	circle := &fakeCircle{balances: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"}}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		repo.addWallet(uuid.New(), id, nil, "0")
+	}
+	repo.addWallet(uuid.New(), "down", ago(time.Hour), "100")
+
+	aggregate, err := newService(repo, circle).TotalUSDC(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "110", aggregate.Total.String())
+	assert.Equal(t, 4, aggregate.Counted)
+	assert.Equal(t, 1, aggregate.Skipped)
+	assert.Equal(t, []string{"down"}, aggregate.SkippedIDs)
+}
+
+// bulkCircle serves balances a page at a time and fails individual reads
+type bulkCircle struct {
+	fakeCircle
+	pages [][]entities.CircleWalletBalances
+}
+
+func (b *bulkCircle) ListWalletBalances(ctx context.Context, pageAfter string, pageSize int) ([]entities.CircleWalletBalances, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if pageAfter == "" {
+		return b.pages[0], nil
+	}
+	for i, page := range b.pages {
+		if page[len(page)-1].ID == pageAfter && i+1 < len(b.pages) {
+			return b.pages[i+1], nil
+		}
+	}
+	return nil, nil
+}
+
+func usdc(id, amount string) entities.CircleWalletBalances {
+	return entities.CircleWalletBalances{ID: id, TokenBalances: []entities.CircleTokenBalance{
+		{Token: entities.CircleTokenInfo{Symbol: "USDC"}, Amount: amount},
+	}}
+}
+
+func TestTotalUSDC_UsesBulkEndpointWhenSupported(t *testing.T) {
+	repo := newFakeRepo()
+	for _, id := range []string{"a", "b", "c"} {
+		repo.addWallet(uuid.New(), id, nil, "0")
+	}
+	circle := &bulkCircle{
+		fakeCircle: fakeCircle{balances: map[string]string{}},
+		pages: [][]entities.CircleWalletBalances{
+			{usdc("a", "1.25"), usdc("b", "2")},
+			{usdc("c", "3"), usdc("treasury", "1000")},
+		},
+	}
+
+	aggregate, err := newService(repo, &circle.fakeCircle).TotalUSDC(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, aggregate.Skipped, "per-wallet client without bulk support fails every read")
+
+	aggregate, err = newService(repo, circle).TotalUSDC(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "6.25", aggregate.Total.String(), "wallets outside managed_wallets are ignored")
+	assert.Equal(t, 3, aggregate.Counted)
+	assert.Zero(t, aggregate.Skipped)
+}
@@ -0,0 +1,209 @@
+package blotter_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/blotter"
+	"github.com/stack-service/stack_service/pkg/clock/clocktest"
+)
+
+type memoryRepo struct {
+	events   map[string][]*entities.BlotterRecord
+	blotters []*entities.OrderBlotter
+	reads    []time.Time
+}
+
+func newRepo() *memoryRepo {
+	return &memoryRepo{events: map[string][]*entities.BlotterRecord{}}
+}
+
+func (r *memoryRepo) OrderEvents(ctx context.Context, from, to time.Time) ([]*entities.BlotterRecord, error) {
+	r.reads = append(r.reads, from)
+	return r.events[from.Format("2006-01-02")], nil
+}
+
+func (r *memoryRepo) CreateBlotter(ctx context.Context, b *entities.OrderBlotter, firstOnly bool) error {
+	version := 0
+	for _, existing := range r.blotters {
+		if existing.BusinessDate.Equal(b.BusinessDate) && existing.Version > version {
+			version = existing.Version
+		}
+	}
+	if firstOnly && version > 0 {
+		return entities.ErrBlotterVersionConflict
+	}
+	b.ID = uuid.New()
+	b.Version = version + 1
+	r.blotters = append(r.blotters, b)
+	return nil
+}
+
+func (r *memoryRepo) ListBlotters(ctx context.Context, from, to time.Time) ([]*entities.OrderBlotter, error) {
+	var out []*entities.OrderBlotter
+	for _, b := range r.blotters {
+		if !b.BusinessDate.Before(from) && !b.BusinessDate.After(to) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) GetBlotter(ctx context.Context, id uuid.UUID) (*entities.OrderBlotter, error) {
+	for _, b := range r.blotters {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return nil, entities.ErrBlotterNotFound
+}
+
+func newService(repo *memoryRepo, now time.Time, format entities.BlotterFormat) *blotter.Service {
+	service := blotter.NewService(repo, blotter.Config{
+		Format:       format,
+		Location:     time.UTC,
+		LookbackDays: 3,
+		SettleDelay:  2 * time.Hour,
+	}, zap.NewNop())
+	service.SetClock(clocktest.NewFake(now).Now)
+	return service
+}
+
+func filledOrder(day time.Time) []*entities.BlotterRecord {
+	orderID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	userID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	basketID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	adminID := uuid.MustParse("44444444-4444-4444-4444-444444444444")
+	limit := decimal.RequireFromString("101.5")
+	base := entities.BlotterRecord{
+		OrderID: orderID, UserID: userID, BasketID: basketID,
+		Side: entities.OrderSideBuy, OrderType: entities.OrderTypeLimit, TimeInForce: entities.TimeInForceDay,
+		LimitPrice: &limit, Amount: decimal.RequireFromString("250"), BrokerageRef: "alp-1",
+		OrderCreatedAt: day.Add(14 * time.Hour),
+	}
+	received, filled, amended := base, base, base
+	received.EventID, received.EventType, received.EventTime, received.Status = "new:"+orderID.String(), entities.BlotterEventNew, day.Add(14*time.Hour), "new"
+	amended.EventID, amended.EventType, amended.EventTime = "amend:1", entities.BlotterEventAmend, day.Add(15*time.Hour)
+	amended.Status, amended.PreviousStatus, amended.ActorID, amended.Action = "pending", "pending", &adminID, "annotate"
+	filled.EventID, filled.EventType, filled.EventTime, filled.Status = "status:"+orderID.String()+":filled", entities.BlotterEventStatus, day.Add(16*time.Hour), "filled"
+	return []*entities.BlotterRecord{&received, &amended, &filled}
+}
+
+func TestGeneratePending_StoresEachSettledDayOnce(t *testing.T) {
+	repo := newRepo()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	repo.events["2026-03-02"] = filledOrder(day)
+	// 01:00 on the 4th: the 3rd has not settled yet
+	service := newService(repo, time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC), entities.BlotterFormatCSV)
+
+	generated, err := service.GeneratePending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, generated) // Feb 28, Mar 1 and Mar 2, empty days included
+	require.Len(t, repo.blotters, 3)
+	last := repo.blotters[2]
+	assert.Equal(t, "2026-03-02", last.BusinessDate.Format("2006-01-02"))
+	assert.Equal(t, 1, last.Version)
+	assert.Equal(t, 3, last.RecordCount)
+	sum := sha256.Sum256(last.Content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), last.Checksum)
+	assert.Nil(t, last.GeneratedBy)
+
+	generated, err = service.GeneratePending(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, generated)
+	assert.Len(t, repo.blotters, 3)
+}
+
+func TestGenerate_AddsVersionAndKeepsEarlierOnes(t *testing.T) {
+	repo := newRepo()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	repo.events["2026-03-02"] = filledOrder(day)
+	service := newService(repo, time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC), entities.BlotterFormatCSV)
+	_, err := service.GeneratePending(context.Background())
+	require.NoError(t, err)
+
+	adminID := uuid.New()
+	stored, err := service.Generate(context.Background(), day, entities.BlotterFormatFIX, " order repaired ", adminID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Version)
+	assert.Equal(t, entities.BlotterFormatFIX, stored.Format)
+	assert.Equal(t, "order repaired", stored.Reason)
+	assert.Equal(t, &adminID, stored.GeneratedBy)
+
+	versions, err := service.List(context.Background(), day, day)
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+	fetched, err := service.Get(context.Background(), stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "order-blotter-2026-03-02-v2.fix", blotter.FileName(fetched))
+}
+
+func TestGenerate_RejectsUnsettledDaysAndUnknownFormats(t *testing.T) {
+	service := newService(newRepo(), time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC), entities.BlotterFormatCSV)
+
+	_, err := service.Generate(context.Background(), time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), "", "late fill", uuid.New())
+	assert.ErrorIs(t, err, entities.ErrInvalidBlotterDateRange)
+
+	_, err = service.Generate(context.Background(), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "xml", "late fill", uuid.New())
+	assert.ErrorIs(t, err, entities.ErrInvalidBlotterFormat)
+
+	_, err = service.List(context.Background(), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, entities.ErrInvalidBlotterDateRange)
+}
+
+func TestGenerate_ReportsVenueByRouting(t *testing.T) {
+	repo := newRepo()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	records := filledOrder(day)
+	records[0].BrokerageRef = "" // not yet routed when received
+	repo.events["2026-03-02"] = records
+	service := newService(repo, time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC), entities.BlotterFormatCSV)
+
+	_, err := service.GeneratePending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "INTERNAL", records[0].Venue)
+	assert.Equal(t, "ALPACA", records[2].Venue)
+}
+
+func TestRenderCSV_OneRowPerEvent(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	content, err := blotter.RenderCSV(filledOrder(day))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "event_id,event_time,event_type,order_id"))
+	assert.Contains(t, lines[1], ",2026-03-02T14:00:00.000Z,new,")
+	assert.Contains(t, lines[2], ",44444444-4444-4444-4444-444444444444,annotate")
+	assert.Contains(t, lines[3], ",101.5,250,filled,")
+}
+
+func TestRenderFIX_MapsOrderFieldsToTags(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	records := filledOrder(day)
+	for _, r := range records {
+		r.Venue = "ALPACA"
+	}
+	lines := strings.Split(strings.TrimSpace(string(blotter.RenderFIX(records, "|"))), "\n")
+	require.Len(t, lines, 3)
+
+	assert.True(t, strings.HasPrefix(lines[0], "8=FIX.4.4|35=8|"))
+	assert.Contains(t, lines[0], "|150=0|39=0|")
+	assert.Contains(t, lines[1], "|150=5|")
+	assert.Contains(t, lines[1], "|58=annotate|")
+	filled := lines[2]
+	for _, tag := range []string{"54=1", "40=2", "59=0", "152=250", "150=F", "39=2", "30=ALPACA", "60=20260302-16:00:00.000", "44=101.5", "198=alp-1"} {
+		assert.Contains(t, filled, "|"+tag+"|")
+	}
+}
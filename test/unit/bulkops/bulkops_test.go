@@ -0,0 +1,359 @@
+package bulkops_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/bulkops"
+)
+
+type fakeRepo struct {
+	ops     map[uuid.UUID]*entities.BulkOperation
+	items   map[uuid.UUID]*entities.BulkOperationItem
+	retryAt map[uuid.UUID]time.Time
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		ops:     make(map[uuid.UUID]*entities.BulkOperation),
+		items:   make(map[uuid.UUID]*entities.BulkOperationItem),
+		retryAt: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (f *fakeRepo) Create(ctx context.Context, op *entities.BulkOperation, userIDs []uuid.UUID) error {
+	copied := *op
+	f.ops[op.ID] = &copied
+	for _, userID := range userIDs {
+		item := &entities.BulkOperationItem{ID: uuid.New(), OperationID: op.ID, UserID: userID, Status: entities.BulkItemPending}
+		f.items[item.ID] = item
+	}
+	return nil
+}
+
+func (f *fakeRepo) Get(ctx context.Context, id uuid.UUID) (*entities.BulkOperation, error) {
+	op, ok := f.ops[id]
+	if !ok {
+		return nil, entities.ErrBulkOperationNotFound
+	}
+	copied := *op
+	return &copied, nil
+}
+
+func (f *fakeRepo) List(ctx context.Context, limit, offset int) ([]*entities.BulkOperation, error) {
+	var ops []*entities.BulkOperation
+	for _, op := range f.ops {
+		copied := *op
+		ops = append(ops, &copied)
+	}
+	return ops, nil
+}
+
+func (f *fakeRepo) ListItems(ctx context.Context, operationID uuid.UUID, status entities.BulkItemStatus) ([]*entities.BulkOperationItem, error) {
+	items := []*entities.BulkOperationItem{}
+	for _, item := range f.items {
+		if item.OperationID == operationID && (status == "" || item.Status == status) {
+			copied := *item
+			items = append(items, &copied)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].UserID.String() < items[j].UserID.String() })
+	return items, nil
+}
+
+func (f *fakeRepo) ClaimItems(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.ClaimedBulkItem, error) {
+	var claimed []*entities.ClaimedBulkItem
+	for _, item := range f.items {
+		op := f.ops[item.OperationID]
+		if item.Status != entities.BulkItemPending || op.Status != entities.BulkOperationRunning || f.retryAt[item.ID].After(now) {
+			continue
+		}
+		if len(claimed) == limit {
+			break
+		}
+		f.retryAt[item.ID] = now.Add(lease)
+		claimed = append(claimed, &entities.ClaimedBulkItem{BulkOperationItem: *item, Type: op.Type, Reason: op.Reason})
+	}
+	return claimed, nil
+}
+
+func (f *fakeRepo) RecordItem(ctx context.Context, item *entities.BulkOperationItem, retryAt time.Time) error {
+	copied := *item
+	f.items[item.ID] = &copied
+	f.retryAt[item.ID] = retryAt
+	return nil
+}
+
+func (f *fakeRepo) Refresh(ctx context.Context, id uuid.UUID, now time.Time) (*entities.BulkOperation, error) {
+	op := f.ops[id]
+	op.Succeeded, op.Skipped, op.Failed = 0, 0, 0
+	pending := 0
+	for _, item := range f.items {
+		if item.OperationID != id {
+			continue
+		}
+		switch item.Status {
+		case entities.BulkItemSucceeded:
+			op.Succeeded++
+		case entities.BulkItemSkipped:
+			op.Skipped++
+		case entities.BulkItemFailed:
+			op.Failed++
+		default:
+			pending++
+		}
+	}
+	if op.Status == entities.BulkOperationRunning && pending == 0 {
+		op.Status = entities.BulkOperationCompleted
+		op.CompletedAt = &now
+	}
+	copied := *op
+	return &copied, nil
+}
+
+func (f *fakeRepo) Cancel(ctx context.Context, id uuid.UUID, now time.Time) error {
+	for _, item := range f.items {
+		if item.OperationID == id && item.Status == entities.BulkItemPending {
+			item.Status = entities.BulkItemSkipped
+			item.Message = "cancelled"
+		}
+	}
+	f.ops[id].Status = entities.BulkOperationCancelled
+	_, err := f.Refresh(ctx, id, now)
+	return err
+}
+
+type fakeUsers struct {
+	users   map[uuid.UUID]*entities.UserProfile
+	failFor map[uuid.UUID]int
+}
+
+func (f *fakeUsers) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error) {
+	if f.failFor[id] > 0 {
+		f.failFor[id]--
+		return nil, errors.New("connection reset")
+	}
+	user, ok := f.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeUsers) SetActiveStatus(ctx context.Context, userID uuid.UUID, active bool, reason entities.AccountClosureReason, notes string) error {
+	f.users[userID].IsActive = active
+	return nil
+}
+
+func (f *fakeUsers) UpdateKYCStatus(ctx context.Context, userID uuid.UUID, status string, approvedAt *time.Time, rejectionReason *string) error {
+	f.users[userID].KYCStatus = status
+	return nil
+}
+
+type fakeVerification struct {
+	sent []string
+}
+
+func (f *fakeVerification) GenerateAndSendCode(ctx context.Context, identifierType, identifier string) (string, error) {
+	f.sent = append(f.sent, identifier)
+	return "123456", nil
+}
+
+func newUser(active, verified bool, kyc entities.KYCStatus) *entities.UserProfile {
+	id := uuid.New()
+	return &entities.UserProfile{
+		ID:            id,
+		Email:         id.String()[:8] + "@example.com",
+		IsActive:      active,
+		EmailVerified: verified,
+		KYCStatus:     string(kyc),
+	}
+}
+
+func newService(repo *fakeRepo, users *fakeUsers, now *time.Time) *bulkops.Service {
+	svc := bulkops.NewService(repo, users, bulkops.Config{BatchSize: 10, MaxUsers: 5}, zap.NewNop())
+	svc.SetClock(func() time.Time { return *now })
+	return svc
+}
+
+func drain(t *testing.T, svc *bulkops.Service) {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		claimed, err := svc.ProcessBatch(context.Background())
+		require.NoError(t, err)
+		if claimed == 0 {
+			return
+		}
+	}
+}
+
+func TestDeactivateReportsEachUser(t *testing.T) {
+	active := newUser(true, true, entities.KYCStatusApproved)
+	inactive := newUser(false, true, entities.KYCStatusApproved)
+	missing := uuid.New()
+	users := &fakeUsers{users: map[uuid.UUID]*entities.UserProfile{active.ID: active, inactive.ID: inactive}}
+	repo := newFakeRepo()
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	svc := newService(repo, users, &now)
+
+	op, err := svc.Create(context.Background(), &entities.CreateBulkOperationRequest{
+		Type:    entities.BulkDeactivateUsers,
+		UserIDs: []uuid.UUID{active.ID, inactive.ID, missing, active.ID},
+		Reason:  "fraud ring",
+	}, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, 3, op.Total, "duplicate user IDs are collapsed")
+
+	drain(t, svc)
+
+	assert.False(t, users.users[active.ID].IsActive)
+	op, err = svc.Get(context.Background(), op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.BulkOperationCompleted, op.Status)
+	assert.Equal(t, 1, op.Succeeded)
+	assert.Equal(t, 1, op.Skipped)
+	assert.Equal(t, 1, op.Failed)
+	assert.Zero(t, op.Pending())
+}
+
+func TestCreateRejectsInvalidRequests(t *testing.T) {
+	now := time.Now()
+	svc := newService(newFakeRepo(), &fakeUsers{}, &now)
+
+	tooMany := make([]uuid.UUID, 6)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	cases := []*entities.CreateBulkOperationRequest{
+		{Type: "delete_users", UserIDs: []uuid.UUID{uuid.New()}, Reason: "x"},
+		{Type: entities.BulkDeactivateUsers, UserIDs: []uuid.UUID{uuid.Nil}, Reason: "x"},
+		{Type: entities.BulkDeactivateUsers, UserIDs: tooMany, Reason: "x"},
+		// No verification sender is configured
+		{Type: entities.BulkResendVerification, UserIDs: []uuid.UUID{uuid.New()}, Reason: "x"},
+	}
+	for _, req := range cases {
+		_, err := svc.Create(context.Background(), req, uuid.New())
+		assert.ErrorIs(t, err, entities.ErrInvalidBulkOperation)
+	}
+}
+
+func TestKYCRecheckOnlyExpiresApprovedUsers(t *testing.T) {
+	approved := newUser(true, true, entities.KYCStatusApproved)
+	pending := newUser(true, true, entities.KYCStatusPending)
+	users := &fakeUsers{users: map[uuid.UUID]*entities.UserProfile{approved.ID: approved, pending.ID: pending}}
+	now := time.Now()
+	svc := newService(newFakeRepo(), users, &now)
+
+	op, err := svc.Create(context.Background(), &entities.CreateBulkOperationRequest{
+		Type: entities.BulkKYCRecheck, UserIDs: []uuid.UUID{approved.ID, pending.ID}, Reason: "document vendor breach",
+	}, uuid.New())
+	require.NoError(t, err)
+	drain(t, svc)
+
+	assert.Equal(t, string(entities.KYCStatusExpired), users.users[approved.ID].KYCStatus)
+	assert.Equal(t, string(entities.KYCStatusPending), users.users[pending.ID].KYCStatus)
+	skipped, err := svc.Items(context.Background(), op.ID, entities.BulkItemSkipped)
+	require.NoError(t, err)
+	require.Len(t, skipped, 1)
+	assert.Equal(t, pending.ID, skipped[0].UserID)
+}
+
+func TestResendVerificationSkipsVerifiedUsers(t *testing.T) {
+	unverified := newUser(true, false, entities.KYCStatusPending)
+	verified := newUser(true, true, entities.KYCStatusPending)
+	users := &fakeUsers{users: map[uuid.UUID]*entities.UserProfile{unverified.ID: unverified, verified.ID: verified}}
+	now := time.Now()
+	svc := newService(newFakeRepo(), users, &now)
+	sender := &fakeVerification{}
+	svc.SetVerificationSender(sender)
+
+	_, err := svc.Create(context.Background(), &entities.CreateBulkOperationRequest{
+		Type: entities.BulkResendVerification, UserIDs: []uuid.UUID{unverified.ID, verified.ID}, Reason: "email outage",
+	}, uuid.New())
+	require.NoError(t, err)
+	drain(t, svc)
+
+	assert.Equal(t, []string{unverified.Email}, sender.sent)
+}
+
+func TestTransientErrorsAreRetriedThenFailed(t *testing.T) {
+	flaky := newUser(false, true, entities.KYCStatusApproved)
+	broken := newUser(false, true, entities.KYCStatusApproved)
+	users := &fakeUsers{
+		users:   map[uuid.UUID]*entities.UserProfile{flaky.ID: flaky, broken.ID: broken},
+		failFor: map[uuid.UUID]int{flaky.ID: 1, broken.ID: 10},
+	}
+	repo := newFakeRepo()
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	svc := newService(repo, users, &now)
+
+	op, err := svc.Create(context.Background(), &entities.CreateBulkOperationRequest{
+		Type: entities.BulkReactivateUsers, UserIDs: []uuid.UUID{flaky.ID, broken.ID}, Reason: "false positive",
+	}, uuid.New())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.ProcessBatch(context.Background())
+		require.NoError(t, err)
+		now = now.Add(2 * time.Minute)
+	}
+
+	assert.True(t, users.users[flaky.ID].IsActive)
+	op, err = svc.Get(context.Background(), op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.BulkOperationCompleted, op.Status)
+	assert.Equal(t, 1, op.Succeeded)
+	assert.Equal(t, 1, op.Failed)
+
+	failed, err := svc.Items(context.Background(), op.ID, entities.BulkItemFailed)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, 3, failed[0].Attempts)
+	assert.Equal(t, "connection reset", failed[0].Message)
+}
+
+func TestCancelSkipsRemainingUsersAndExportsCSV(t *testing.T) {
+	a := newUser(true, true, entities.KYCStatusApproved)
+	b := newUser(true, true, entities.KYCStatusApproved)
+	users := &fakeUsers{users: map[uuid.UUID]*entities.UserProfile{a.ID: a, b.ID: b}}
+	now := time.Now()
+	svc := newService(newFakeRepo(), users, &now)
+
+	op, err := svc.Create(context.Background(), &entities.CreateBulkOperationRequest{
+		Type: entities.BulkDeactivateUsers, UserIDs: []uuid.UUID{a.ID, b.ID}, Reason: "sanctions hit",
+	}, uuid.New())
+	require.NoError(t, err)
+
+	op, err = svc.Cancel(context.Background(), op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.BulkOperationCancelled, op.Status)
+	assert.Equal(t, 2, op.Skipped)
+
+	claimed, err := svc.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+	assert.True(t, users.users[a.ID].IsActive)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteResultsCSV(context.Background(), op.ID, &buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "user_id,status,message,attempts,processed_at", lines[0])
+	assert.Contains(t, lines[1], ",skipped,cancelled,0,")
+
+	_, err = svc.Items(context.Background(), uuid.New(), "")
+	assert.ErrorIs(t, err, entities.ErrBulkOperationNotFound)
+}
@@ -0,0 +1,90 @@
+package chainaddr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/pkg/chainaddr"
+)
+
+func TestValidateEVM_ChecksumsMixedCase(t *testing.T) {
+	// Vectors from EIP-55
+	for _, address := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		normalized, err := chainaddr.ValidateEVM(address)
+		require.NoError(t, err, address)
+		assert.Equal(t, address, normalized)
+
+		normalized, err = chainaddr.ValidateEVM(strings.ToLower(address))
+		require.NoError(t, err, "lower case carries no checksum")
+		assert.Equal(t, address, normalized, "the checksummed form is returned")
+	}
+
+	_, err := chainaddr.ValidateEVM("0x52908400098527886E0F7030069857D2E4169EE7")
+	assert.NoError(t, err, "upper case carries no checksum")
+
+	// One letter's case flipped, as a mistyped character would change it
+	_, err = chainaddr.ValidateEVM("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD")
+	assert.ErrorIs(t, err, chainaddr.ErrChecksum)
+}
+
+func TestValidate_RejectsMalformedAddresses(t *testing.T) {
+	cases := map[string]struct {
+		format  chainaddr.Format
+		address string
+		want    error
+	}{
+		"evm empty":          {chainaddr.FormatEVM, "", chainaddr.ErrEmpty},
+		"evm no prefix":      {chainaddr.FormatEVM, "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", chainaddr.ErrMalformed},
+		"evm short":          {chainaddr.FormatEVM, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", chainaddr.ErrMalformed},
+		"evm not hex":        {chainaddr.FormatEVM, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg", chainaddr.ErrMalformed},
+		"solana zero digit":  {chainaddr.FormatSolana, "0WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM", chainaddr.ErrMalformed},
+		"solana too short":   {chainaddr.FormatSolana, "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsG", chainaddr.ErrMalformed},
+		"solana wrong size":  {chainaddr.FormatSolana, "zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz", chainaddr.ErrMalformed},
+		"aptos evm length":   {chainaddr.FormatAptos, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", chainaddr.ErrMalformed},
+		"aptos short normal": {chainaddr.FormatAptos, "0x1f", chainaddr.ErrMalformed},
+		"aptos no prefix":    {chainaddr.FormatAptos, strings.Repeat("a", 64), chainaddr.ErrMalformed},
+		"unknown format":     {chainaddr.Format("cosmos"), "cosmos1abc", chainaddr.ErrUnknownFormat},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := chainaddr.Validate(tc.format, tc.address)
+			assert.ErrorIs(t, err, tc.want)
+			assert.False(t, chainaddr.Valid(tc.format, tc.address))
+		})
+	}
+}
+
+func TestValidateSolana_AcceptsThirtyTwoByteKeys(t *testing.T) {
+	for _, address := range []string{
+		"9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		"11111111111111111111111111111111", // the system program, all zeros
+	} {
+		normalized, err := chainaddr.ValidateSolana(address)
+		require.NoError(t, err, address)
+		assert.Equal(t, address, normalized)
+	}
+}
+
+func TestValidateAptos_NormalizesToLongLowerCase(t *testing.T) {
+	long := "0x" + strings.Repeat("0", 4) + "Bae207659db88bea0cbead6da0ed00aac12edcdda169e591cd41c94180b4"
+	normalized, err := chainaddr.ValidateAptos(long)
+	require.NoError(t, err)
+	assert.Equal(t, strings.ToLower(long), normalized)
+
+	normalized, err = chainaddr.ValidateAptos("0x1")
+	require.NoError(t, err)
+	assert.Equal(t, "0x1", normalized)
+
+	normalized, err = chainaddr.ValidateAptos("0x" + strings.Repeat("0", 63) + "A")
+	require.NoError(t, err)
+	assert.Equal(t, "0xa", normalized, "special addresses are written short")
+}
@@ -0,0 +1,95 @@
+package chains_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/funding"
+)
+
+func TestDefaultChains_OnlySolanaDevnetEnabled(t *testing.T) {
+	registry := entities.Chains()
+
+	assert.Equal(t, []string{"SOL-DEVNET"}, registry.EnabledIDs())
+	assert.True(t, entities.ChainSOLDevnet.IsValid())
+	assert.True(t, entities.ChainSOLDevnet.IsTestnet())
+	assert.False(t, entities.WalletChain("ETH").IsValid(), "catalog chains stay off until enabled")
+	assert.Equal(t, "EVM", entities.WalletChain("MATIC").GetChainFamily())
+	assert.Equal(t, entities.USDCTokenAddressSOLDevnet, entities.ChainSOLDevnet.GetUSDCTokenAddress())
+
+	info, ok := registry.Lookup("polygon")
+	require.True(t, ok, "Circle chain names resolve through aliases")
+	assert.Equal(t, entities.WalletChain("MATIC"), info.ID)
+	assert.Equal(t, "https://polygonscan.com/tx/0xabc", info.ExplorerTxURL("0xabc"))
+	_, ok = entities.WalletChain("polygon").Info()
+	assert.False(t, ok, "wallet chains match on ID only")
+}
+
+func TestChainInfo_ValidatesAddresses(t *testing.T) {
+	sol, _ := entities.Chains().Lookup("SOL-DEVNET")
+	assert.True(t, sol.ValidAddress("9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"))
+	assert.False(t, sol.ValidAddress("0x52908400098527886E0F7030069857D2E4169EE7"))
+
+	eth, _ := entities.Chains().Lookup("ETH")
+	assert.True(t, eth.ValidAddress("0x52908400098527886E0F7030069857D2E4169EE7"))
+	assert.False(t, eth.ValidAddress("0x5290"))
+}
+
+func TestNewChainRegistry_RejectsMalformedDefinitions(t *testing.T) {
+	valid := entities.DefaultChains()[2]
+
+	cases := map[string]func(*entities.ChainInfo){
+		"lower case id":       func(c *entities.ChainInfo) { c.ID = "eth" },
+		"unknown family":      func(c *entities.ChainInfo) { c.Family = "Cosmos" },
+		"no confirmations":    func(c *entities.ChainInfo) { c.Confirmations = 0 },
+		"plain http explorer": func(c *entities.ChainInfo) { c.ExplorerURL = "http://etherscan.io/tx/%s" },
+		"explorer no hash":    func(c *entities.ChainInfo) { c.ExplorerURL = "https://etherscan.io/" },
+		"bad pattern":         func(c *entities.ChainInfo) { c.AddressPattern = "([" },
+		"token fails pattern": func(c *entities.ChainInfo) { c.USDCTokenAddress = "not-an-address" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			chain := valid
+			mutate(&chain)
+			_, err := entities.NewChainRegistry([]entities.ChainInfo{chain})
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := entities.NewChainRegistry([]entities.ChainInfo{valid, valid})
+	assert.Error(t, err, "duplicate IDs are rejected")
+}
+
+func TestConfirmationThresholds_FallBackToRegistry(t *testing.T) {
+	thresholds := funding.ConfirmationThresholds{
+		Default:  1,
+		Chains:   map[string]int{"polygon": 64},
+		Registry: entities.Chains(),
+	}
+	assert.Equal(t, 64, thresholds.Required(entities.ChainPolygon), "configured thresholds win")
+	assert.Equal(t, 32, thresholds.Required(entities.ChainSolana))
+	assert.Equal(t, 1, thresholds.Required(entities.ChainAptos))
+}
+
+func TestChainInfo_ChecksAddressFormatBeyondPattern(t *testing.T) {
+	eth, _ := entities.Chains().Lookup("ETH")
+	normalized, err := eth.CheckAddress("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+	require.NoError(t, err)
+	assert.Equal(t, "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", normalized)
+	assert.False(t, eth.ValidAddress("0xFb6916095ca1df60bB79Ce92cE3Ea74c37c5d359"), "bad EIP-55 checksum")
+
+	aptos := entities.ChainInfo{
+		ID: "APTOS", Name: "Aptos", Family: entities.ChainFamilyAptos,
+		NativeCurrency: "APT", USDCTokenAddress: "0xbae207659db88bea0cbead6da0ed00aac12edcdda169e591cd41c94180b46f3b",
+		ExplorerURL: "https://explorer.aptoslabs.com/txn/%s", Confirmations: 1,
+		AddressPattern: `^0x[0-9a-fA-F]{1,64}$`,
+	}
+	registry, err := entities.NewChainRegistry([]entities.ChainInfo{aptos})
+	require.NoError(t, err, "Aptos chains can be configured")
+	info, _ := registry.Lookup("APTOS")
+	assert.True(t, info.ValidAddress("0x1"))
+	assert.False(t, info.ValidAddress("0x52908400098527886E0F7030069857D2E4169EE7"))
+}
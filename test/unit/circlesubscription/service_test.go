@@ -0,0 +1,207 @@
+package circlesubscription_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/circlesubscription"
+	"github.com/stack-service/stack_service/pkg/health"
+)
+
+type fakeClient struct {
+	mu            sync.Mutex
+	subscriptions map[string]*entities.CircleNotificationSubscription
+	deleted       []string
+	keys          map[string]string
+	keyFetches    int
+	nextID        int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		subscriptions: map[string]*entities.CircleNotificationSubscription{},
+		keys:          map[string]string{},
+	}
+}
+
+func (f *fakeClient) CreateNotificationSubscription(ctx context.Context, req entities.CircleCreateSubscriptionRequest) (*entities.CircleNotificationSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	sub := &entities.CircleNotificationSubscription{
+		ID:                fmt.Sprintf("sub-%d", f.nextID),
+		Endpoint:          req.Endpoint,
+		Enabled:           true,
+		NotificationTypes: req.NotificationTypes,
+	}
+	f.subscriptions[sub.ID] = sub
+	copied := *sub
+	return &copied, nil
+}
+
+func (f *fakeClient) ListNotificationSubscriptions(ctx context.Context) ([]entities.CircleNotificationSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []entities.CircleNotificationSubscription
+	for _, sub := range f.subscriptions {
+		out = append(out, *sub)
+	}
+	return out, nil
+}
+
+func (f *fakeClient) DeleteNotificationSubscription(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, id)
+	if _, ok := f.subscriptions[id]; !ok {
+		return entities.CircleAPIError{Code: http.StatusNotFound, Message: "not found"}
+	}
+	delete(f.subscriptions, id)
+	return nil
+}
+
+func (f *fakeClient) GetNotificationPublicKey(ctx context.Context, keyID string) (*entities.CircleNotificationPublicKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keyFetches++
+	key, ok := f.keys[keyID]
+	if !ok {
+		return nil, entities.CircleAPIError{Code: http.StatusNotFound, Message: "not found"}
+	}
+	return &entities.CircleNotificationPublicKey{ID: keyID, Algorithm: "ECDSA_SHA_256", PublicKey: key}, nil
+}
+
+type fakeRepo struct {
+	mu   sync.Mutex
+	regs map[string]*entities.CircleSubscriptionRegistration
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{regs: map[string]*entities.CircleSubscriptionRegistration{}}
+}
+
+func (f *fakeRepo) List(ctx context.Context) ([]*entities.CircleSubscriptionRegistration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*entities.CircleSubscriptionRegistration
+	for _, reg := range f.regs {
+		copied := *reg
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) Save(ctx context.Context, reg *entities.CircleSubscriptionRegistration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *reg
+	f.regs[reg.SubscriptionID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.regs, id)
+	return nil
+}
+
+func newService(client *fakeClient, repo *fakeRepo, callbackURL string) *circlesubscription.Service {
+	return circlesubscription.NewService(client, repo, circlesubscription.Config{CallbackURL: callbackURL}, zap.NewNop())
+}
+
+func TestSync_RegistersMissingSubscription(t *testing.T) {
+	client, repo := newFakeClient(), newFakeRepo()
+	svc := newService(client, repo, "https://api.example.com/webhooks/circle")
+
+	status, err := svc.Sync(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "sub-1", status.SubscriptionID)
+	assert.Len(t, client.subscriptions, 1)
+	assert.Contains(t, repo.regs, "sub-1")
+
+	// A second sync finds the subscription rather than creating another
+	_, err = svc.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, client.subscriptions, 1)
+	assert.Equal(t, health.StatusHealthy, svc.Check(context.Background()).Status)
+}
+
+func TestSync_ReRegistersWhenCallbackURLChanges(t *testing.T) {
+	client, repo := newFakeClient(), newFakeRepo()
+	_, err := newService(client, repo, "https://old.example.com/webhooks/circle").Sync(context.Background())
+	require.NoError(t, err)
+
+	status, err := newService(client, repo, "https://new.example.com/webhooks/circle").Sync(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "sub-2", status.SubscriptionID)
+	assert.Equal(t, []string{"sub-1"}, client.deleted)
+	require.Len(t, client.subscriptions, 1)
+	assert.Equal(t, "https://new.example.com/webhooks/circle", client.subscriptions["sub-2"].Endpoint)
+	assert.NotContains(t, repo.regs, "sub-1")
+}
+
+func TestSync_ReplacesDisabledSubscription(t *testing.T) {
+	client, repo := newFakeClient(), newFakeRepo()
+	client.subscriptions["sub-0"] = &entities.CircleNotificationSubscription{
+		ID: "sub-0", Endpoint: "https://api.example.com/webhooks/circle/", Enabled: false,
+	}
+	svc := newService(client, repo, "https://api.example.com/webhooks/circle")
+
+	status, err := svc.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", status.SubscriptionID)
+	assert.Equal(t, []string{"sub-0"}, client.deleted)
+	assert.True(t, client.subscriptions["sub-1"].Enabled)
+}
+
+func TestCheck_DegradedBeforeFirstSyncAndHealthyWhenDisabled(t *testing.T) {
+	assert.Equal(t, health.StatusDegraded,
+		newService(newFakeClient(), newFakeRepo(), "https://api.example.com/webhooks/circle").Check(context.Background()).Status)
+
+	disabled := newService(newFakeClient(), newFakeRepo(), "")
+	assert.Equal(t, health.StatusHealthy, disabled.Check(context.Background()).Status)
+	_, err := disabled.Sync(context.Background())
+	assert.ErrorIs(t, err, circlesubscription.ErrNotConfigured)
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	client.keys["key-1"] = base64.StdEncoding.EncodeToString(der)
+	svc := newService(client, newFakeRepo(), "https://api.example.com/webhooks/circle")
+
+	body := []byte(`{"notificationType":"transfers"}`)
+	digest := sha256.Sum256(body)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	signature := base64.StdEncoding.EncodeToString(sig)
+
+	require.NoError(t, svc.VerifySignature(context.Background(), "key-1", signature, body))
+	require.NoError(t, svc.VerifySignature(context.Background(), "key-1", signature, body))
+	assert.Equal(t, 1, client.keyFetches, "public keys are cached by ID")
+
+	assert.Error(t, svc.VerifySignature(context.Background(), "key-1", signature, []byte(`{"tampered":true}`)))
+	assert.Error(t, svc.VerifySignature(context.Background(), "unknown", signature, body))
+	assert.Error(t, svc.VerifySignature(context.Background(), "", signature, body))
+}
@@ -0,0 +1,127 @@
+package clock_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/internal/infrastructure/cache"
+	"github.com/stack-service/stack_service/pkg/clock"
+	"github.com/stack-service/stack_service/pkg/clock/clocktest"
+)
+
+func TestFakeClockMovesOnlyWhenTold(t *testing.T) {
+	start := time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)
+	var clk clock.Clock = clocktest.NewFake(start)
+	fake := clk.(*clocktest.Fake)
+
+	assert.Equal(t, start, clk.Now())
+	assert.Equal(t, start.Add(90*time.Minute), fake.Advance(90*time.Minute))
+	assert.Equal(t, start.Add(90*time.Minute), clk.Now())
+
+	fake.Set(start.Add(-time.Hour))
+	assert.Equal(t, start.Add(-time.Hour), clk.Now())
+}
+
+func TestFakeClockIsSafeForConcurrentUse(t *testing.T) {
+	fake := clocktest.NewFake(time.Unix(0, 0))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); fake.Advance(time.Second) }()
+		go func() { defer wg.Done(); _ = fake.Now() }()
+	}
+	wg.Wait()
+	assert.Equal(t, time.Unix(10, 0), fake.Now())
+}
+
+func TestSystemClockFollowsWallTime(t *testing.T) {
+	before := time.Now()
+	now := clock.System().Now()
+	assert.False(t, now.Before(before))
+}
+
+// memoryRedis keeps values forever; only the clock expires verification codes
+type memoryRedis struct {
+	cache.RedisClient
+	values map[string][]byte
+	counts map[string]int64
+}
+
+func (m *memoryRedis) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.values[key] = data
+	return nil
+}
+
+func (m *memoryRedis) Get(_ context.Context, key string, dest interface{}) error {
+	data, ok := m.values[key]
+	if !ok {
+		return fmt.Errorf("key '%s' not found: redis: nil", key)
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (m *memoryRedis) Del(_ context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryRedis) Incr(_ context.Context, key string) (int64, error) {
+	m.counts[key]++
+	return m.counts[key], nil
+}
+
+func (m *memoryRedis) Expire(context.Context, string, time.Duration) error {
+	return nil
+}
+
+type noopEmail struct{}
+
+func (noopEmail) SendVerificationEmail(context.Context, string, string) error { return nil }
+
+func TestVerificationCodeExpiresByClock(t *testing.T) {
+	fake := clocktest.NewFake(time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC))
+	redis := &memoryRedis{values: map[string][]byte{}, counts: map[string]int64{}}
+	svc := services.NewVerificationService(redis, noopEmail{}, nil, zap.NewNop(), nil, fake)
+	ctx := context.Background()
+
+	code, err := svc.GenerateAndSendCode(ctx, "email", "ada@example.com")
+	require.NoError(t, err)
+
+	fake.Advance(9 * time.Minute)
+	ok, err := svc.VerifyCode(ctx, "email", "ada@example.com", "000000x")
+	assert.False(t, ok)
+	assert.EqualError(t, err, "invalid verification code")
+
+	fake.Advance(2 * time.Minute)
+	ok, err = svc.VerifyCode(ctx, "email", "ada@example.com", code)
+	assert.False(t, ok)
+	assert.EqualError(t, err, "verification code not found or expired")
+}
+
+func TestVerificationCodeAcceptedBeforeExpiry(t *testing.T) {
+	fake := clocktest.NewFake(time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC))
+	redis := &memoryRedis{values: map[string][]byte{}, counts: map[string]int64{}}
+	svc := services.NewVerificationService(redis, noopEmail{}, nil, zap.NewNop(), nil, fake)
+	ctx := context.Background()
+
+	code, err := svc.GenerateAndSendCode(ctx, "email", "ada@example.com")
+	require.NoError(t, err)
+
+	fake.Advance(9*time.Minute + 59*time.Second)
+	ok, err := svc.VerifyCode(ctx, "email", "ada@example.com", code)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,242 @@
+package consents_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/consents"
+)
+
+type fakeRepo struct {
+	versions []*entities.AgreementVersion
+	consents []*entities.UserConsent
+	lists    int
+}
+
+func (f *fakeRepo) ListVersions(ctx context.Context) ([]*entities.AgreementVersion, error) {
+	f.lists++
+	list := append([]*entities.AgreementVersion(nil), f.versions...)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].PublishedAt.Before(list[j].PublishedAt) })
+	return list, nil
+}
+
+func (f *fakeRepo) CreateVersion(ctx context.Context, version *entities.AgreementVersion) error {
+	for _, existing := range f.versions {
+		if existing.AgreementType == version.AgreementType && existing.Version == version.Version {
+			return entities.ErrAgreementVersionExists
+		}
+	}
+	f.versions = append(f.versions, version)
+	return nil
+}
+
+func (f *fakeRepo) LatestConsents(ctx context.Context, userID uuid.UUID) ([]*entities.UserConsent, error) {
+	latest := map[entities.AgreementType]*entities.UserConsent{}
+	for _, consent := range f.consents {
+		if consent.UserID != userID {
+			continue
+		}
+		if current, ok := latest[consent.AgreementType]; !ok || consent.AcceptedAt.After(current.AcceptedAt) {
+			latest[consent.AgreementType] = consent
+		}
+	}
+	var result []*entities.UserConsent
+	for _, consent := range latest {
+		result = append(result, consent)
+	}
+	return result, nil
+}
+
+func (f *fakeRepo) RecordConsent(ctx context.Context, consent *entities.UserConsent) error {
+	for _, existing := range f.consents {
+		if existing.UserID == consent.UserID && existing.AgreementVersionID == consent.AgreementVersionID {
+			return nil
+		}
+	}
+	f.consents = append(f.consents, consent)
+	return nil
+}
+
+type harness struct {
+	svc   *consents.Service
+	repo  *fakeRepo
+	now   time.Time
+	admin uuid.UUID
+	user  uuid.UUID
+}
+
+func newHarness() *harness {
+	h := &harness{
+		repo:  &fakeRepo{},
+		now:   time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC),
+		admin: uuid.New(),
+		user:  uuid.New(),
+	}
+	h.svc = consents.NewService(h.repo, time.Hour, zap.NewNop())
+	h.svc.SetClock(func() time.Time { return h.now })
+	return h
+}
+
+func (h *harness) publish(t *testing.T, agreementType entities.AgreementType, version string, mandatory bool) *entities.AgreementVersion {
+	t.Helper()
+	h.now = h.now.Add(time.Hour)
+	published, err := h.svc.Publish(context.Background(), &entities.PublishAgreementRequest{
+		AgreementType: agreementType,
+		Version:       version,
+		Title:         string(agreementType) + " " + version,
+		DocumentURL:   "https://example.com/legal/" + version,
+		Mandatory:     mandatory,
+	}, h.admin)
+	require.NoError(t, err)
+	return published
+}
+
+func (h *harness) accept(t *testing.T, version *entities.AgreementVersion) {
+	t.Helper()
+	h.now = h.now.Add(time.Minute)
+	_, err := h.svc.Accept(context.Background(), h.user, version.ID, "203.0.113.7", "test")
+	require.NoError(t, err)
+}
+
+func TestPendingMandatory_BlocksUntilAccepted(t *testing.T) {
+	h := newHarness()
+	v1 := h.publish(t, entities.AgreementBrokerage, "2026.1", true)
+
+	pending, err := h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, v1.ID, pending[0].ID)
+
+	h.accept(t, v1)
+
+	pending, err = h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPendingMandatory_NewMandatoryVersionRequiresReacceptance(t *testing.T) {
+	h := newHarness()
+	v1 := h.publish(t, entities.AgreementTermsOfService, "1.0", true)
+	h.accept(t, v1)
+
+	v2 := h.publish(t, entities.AgreementTermsOfService, "2.0", true)
+
+	pending, err := h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, v2.ID, pending[0].ID)
+}
+
+func TestPendingMandatory_OptionalVersionDoesNotBlock(t *testing.T) {
+	h := newHarness()
+	v1 := h.publish(t, entities.AgreementPrivacyPolicy, "1.0", true)
+	h.accept(t, v1)
+	h.publish(t, entities.AgreementPrivacyPolicy, "1.1", false)
+
+	pending, err := h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	statuses, err := h.svc.Status(context.Background(), h.user)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "1.1", statuses[0].Current.Version)
+	assert.Equal(t, "1.0", statuses[0].Accepted.Version)
+}
+
+func TestPendingMandatory_OptionalAfterMissedMandatoryStillBlocks(t *testing.T) {
+	h := newHarness()
+	h.publish(t, entities.AgreementTermsOfService, "1.0", true)
+	v2 := h.publish(t, entities.AgreementTermsOfService, "1.1", false)
+
+	pending, err := h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, v2.ID, pending[0].ID, "the current version is the one to accept")
+
+	h.accept(t, v2)
+
+	pending, err = h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestAccept_RejectsSupersededVersion(t *testing.T) {
+	h := newHarness()
+	v1 := h.publish(t, entities.AgreementBrokerage, "1", true)
+	h.publish(t, entities.AgreementBrokerage, "2", true)
+
+	_, err := h.svc.Accept(context.Background(), h.user, v1.ID, "", "")
+
+	assert.ErrorIs(t, err, entities.ErrAgreementSuperseded)
+}
+
+func TestAccept_UnknownVersion(t *testing.T) {
+	h := newHarness()
+
+	_, err := h.svc.Accept(context.Background(), h.user, uuid.New(), "", "")
+
+	assert.ErrorIs(t, err, entities.ErrAgreementNotFound)
+}
+
+func TestAccept_ReloadsVersionsPublishedElsewhere(t *testing.T) {
+	h := newHarness()
+	_, err := h.svc.PendingMandatory(context.Background(), h.user)
+	require.NoError(t, err)
+
+	// Published by another instance, so this one's cache is stale
+	other := &entities.AgreementVersion{
+		ID:            uuid.New(),
+		AgreementType: entities.AgreementTermsOfService,
+		Version:       "3.0",
+		Mandatory:     true,
+		PublishedAt:   h.now,
+	}
+	h.repo.versions = append(h.repo.versions, other)
+
+	consent, err := h.svc.Accept(context.Background(), h.user, other.ID, "203.0.113.7", "")
+	require.NoError(t, err)
+	assert.Equal(t, "3.0", consent.Version)
+	require.NotNil(t, consent.IPAddress)
+	assert.Nil(t, consent.UserAgent)
+}
+
+func TestPublish_Validation(t *testing.T) {
+	h := newHarness()
+
+	_, err := h.svc.Publish(context.Background(), &entities.PublishAgreementRequest{
+		AgreementType: "cookie_policy",
+		Version:       "1",
+	}, h.admin)
+	assert.ErrorIs(t, err, entities.ErrInvalidAgreement)
+
+	h.publish(t, entities.AgreementBrokerage, "1", true)
+	_, err = h.svc.Publish(context.Background(), &entities.PublishAgreementRequest{
+		AgreementType: entities.AgreementBrokerage,
+		Version:       " 1 ",
+	}, h.admin)
+	assert.ErrorIs(t, err, entities.ErrAgreementVersionExists)
+}
+
+func TestListVersions_FiltersByType(t *testing.T) {
+	h := newHarness()
+	h.publish(t, entities.AgreementBrokerage, "1", true)
+	h.publish(t, entities.AgreementPrivacyPolicy, "1", false)
+
+	all, err := h.svc.ListVersions(context.Background(), "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	brokerage, err := h.svc.ListVersions(context.Background(), entities.AgreementBrokerage)
+	require.NoError(t, err)
+	require.Len(t, brokerage, 1)
+	assert.Equal(t, entities.AgreementBrokerage, brokerage[0].AgreementType)
+}
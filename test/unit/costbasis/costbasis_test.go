@@ -0,0 +1,255 @@
+package costbasis_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/costbasis"
+)
+
+type fakeRepo struct {
+	imports     map[uuid.UUID]*entities.CostBasisImport
+	lots        map[uuid.UUID]*entities.TaxLot
+	adjustments []*entities.TaxLotAdjustment
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		imports: map[uuid.UUID]*entities.CostBasisImport{},
+		lots:    map[uuid.UUID]*entities.TaxLot{},
+	}
+}
+
+func (r *fakeRepo) CreateImport(_ context.Context, imp *entities.CostBasisImport) error {
+	copied := *imp
+	r.imports[imp.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) GetImport(_ context.Context, userID, id uuid.UUID) (*entities.CostBasisImport, error) {
+	imp, ok := r.imports[id]
+	if !ok || imp.UserID != userID {
+		return nil, entities.ErrCostBasisImportNotFound
+	}
+	copied := *imp
+	return &copied, nil
+}
+
+func (r *fakeRepo) ConfirmImport(_ context.Context, imp *entities.CostBasisImport, lots []*entities.TaxLot, at time.Time) (bool, error) {
+	stored := r.imports[imp.ID]
+	if stored.Status != entities.CostBasisImportPendingReview || !at.Before(stored.ExpiresAt) {
+		return false, nil
+	}
+	stored.Status = entities.CostBasisImportConfirmed
+	for _, lot := range lots {
+		copied := *lot
+		r.lots[lot.ID] = &copied
+	}
+	return true, nil
+}
+
+func (r *fakeRepo) CancelImport(_ context.Context, userID, id uuid.UUID) (bool, error) {
+	imp, ok := r.imports[id]
+	if !ok || imp.UserID != userID || imp.Status != entities.CostBasisImportPendingReview {
+		return false, nil
+	}
+	imp.Status = entities.CostBasisImportCancelled
+	return true, nil
+}
+
+func (r *fakeRepo) ListLots(_ context.Context, userID uuid.UUID) ([]*entities.TaxLot, error) {
+	var lots []*entities.TaxLot
+	for _, lot := range r.lots {
+		if lot.UserID == userID {
+			lots = append(lots, lot)
+		}
+	}
+	return lots, nil
+}
+
+func (r *fakeRepo) GetLot(_ context.Context, id uuid.UUID) (*entities.TaxLot, error) {
+	lot, ok := r.lots[id]
+	if !ok {
+		return nil, entities.ErrTaxLotNotFound
+	}
+	copied := *lot
+	return &copied, nil
+}
+
+func (r *fakeRepo) AdjustLot(_ context.Context, lot *entities.TaxLot, adjustment *entities.TaxLotAdjustment) error {
+	copied := *lot
+	r.lots[lot.ID] = &copied
+	r.adjustments = append(r.adjustments, adjustment)
+	return nil
+}
+
+func (r *fakeRepo) ListAdjustments(_ context.Context, lotID uuid.UUID) ([]*entities.TaxLotAdjustment, error) {
+	var adjustments []*entities.TaxLotAdjustment
+	for _, adjustment := range r.adjustments {
+		if adjustment.LotID == lotID {
+			adjustments = append(adjustments, adjustment)
+		}
+	}
+	return adjustments, nil
+}
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func newService(repo *fakeRepo) *costbasis.Service {
+	service := costbasis.NewService(repo, costbasis.DefaultConfig(), zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	return service
+}
+
+const brokerExport = `"Realized/Unrealized Lot Details for account ...1234 as of 10/15/2026"
+
+Symbol,Description,Quantity,Date Acquired,Cost Per Share,Total Cost
+AAPL,APPLE INC,10,03/14/2019,$45.12,"$451.20"
+AAPL,APPLE INC,2.5,2021-06-01,,$312.50
+BRK/B,BERKSHIRE HATHAWAY CL B,4,1/5/20,"$1,000.00",
+Account Total,,,,,"$4,763.70"
+`
+
+func TestImportReadsBrokerExportForReview(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+	userID := uuid.New()
+
+	imp, err := service.Import(context.Background(), userID, "lots.csv", strings.NewReader(brokerExport))
+	require.NoError(t, err)
+	assert.Equal(t, entities.CostBasisImportPendingReview, imp.Status)
+	assert.Equal(t, now.Add(24*time.Hour), imp.ExpiresAt)
+	assert.Empty(t, imp.Errors, "the preamble and total line are skipped")
+	require.Len(t, imp.Lots, 3)
+
+	assert.Equal(t, "AAPL", imp.Lots[0].Symbol)
+	assert.Equal(t, 4, imp.Lots[0].Row)
+	assert.True(t, decimal.RequireFromString("451.20").Equal(imp.Lots[0].CostBasis), "the total cost column wins")
+	assert.Equal(t, time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC), imp.Lots[0].AcquiredAt)
+
+	assert.True(t, decimal.RequireFromString("312.50").Equal(imp.Lots[1].CostBasis))
+
+	assert.Equal(t, "BRK.B", imp.Lots[2].Symbol)
+	assert.True(t, decimal.RequireFromString("4000").Equal(imp.Lots[2].CostBasis), "per-share cost times quantity")
+	assert.Equal(t, time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC), imp.Lots[2].AcquiredAt)
+
+	assert.Empty(t, repo.lots, "nothing is recorded before confirmation")
+}
+
+func TestImportReportsBadRowsAndBlocksConfirmation(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+	userID := uuid.New()
+
+	file := "ticker,shares,cost basis,open date\n" +
+		"MSFT,5,1200.00,2022-02-02\n" +
+		"VTI,-3,600.00,2022-02-02\n" +
+		"QQQ,3,900.00,Various\n" +
+		"SPY,1,400.00,2027-01-01\n"
+	imp, err := service.Import(context.Background(), userID, "lots.csv", strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, imp.Lots, 1)
+	require.Len(t, imp.Errors, 3)
+	assert.Equal(t, 3, imp.Errors[0].Row)
+	assert.Contains(t, imp.Errors[0].Message, "quantity")
+	assert.Contains(t, imp.Errors[1].Message, "Various")
+	assert.Contains(t, imp.Errors[2].Message, "out of range")
+
+	_, err = service.Confirm(context.Background(), userID, imp.ID)
+	assert.ErrorIs(t, err, costbasis.ErrImportHasErrors)
+
+	_, err = service.Import(context.Background(), userID, "positions.csv", strings.NewReader("Symbol,Quantity,Price\nAAPL,1,100\n"))
+	assert.ErrorIs(t, err, costbasis.ErrInvalidFile, "cost and acquired date columns are required")
+}
+
+func TestConfirmRecordsLotsOnce(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+	userID := uuid.New()
+
+	imp, err := service.Import(context.Background(), userID, "lots.csv", strings.NewReader(brokerExport))
+	require.NoError(t, err)
+
+	_, err = service.Confirm(context.Background(), uuid.New(), imp.ID)
+	assert.ErrorIs(t, err, entities.ErrCostBasisImportNotFound, "another user's import")
+
+	lots, err := service.Confirm(context.Background(), userID, imp.ID)
+	require.NoError(t, err)
+	require.Len(t, lots, 3)
+	for _, lot := range lots {
+		assert.Equal(t, entities.TaxLotSourceTransferIn, lot.Source)
+		assert.Equal(t, imp.ID, *lot.ImportID)
+	}
+
+	_, err = service.Confirm(context.Background(), userID, imp.ID)
+	assert.ErrorIs(t, err, costbasis.ErrImportNotPending)
+	assert.ErrorIs(t, service.Cancel(context.Background(), userID, imp.ID), costbasis.ErrImportNotPending)
+
+	listed, err := service.ListLots(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Len(t, listed, 3)
+}
+
+func TestExpiredImportCannotBeConfirmed(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+	userID := uuid.New()
+
+	imp, err := service.Import(context.Background(), userID, "lots.csv", strings.NewReader(brokerExport))
+	require.NoError(t, err)
+
+	service.SetClock(func() time.Time { return now.Add(25 * time.Hour) })
+	fetched, err := service.GetImport(context.Background(), userID, imp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.CostBasisImportExpired, fetched.Status)
+
+	_, err = service.Confirm(context.Background(), userID, imp.ID)
+	assert.ErrorIs(t, err, costbasis.ErrImportNotPending)
+}
+
+func TestAdjustLotKeepsBeforeAndAfter(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+	userID := uuid.New()
+	adminID := uuid.New()
+
+	imp, err := service.Import(context.Background(), userID, "lots.csv", strings.NewReader(brokerExport))
+	require.NoError(t, err)
+	lots, err := service.Confirm(context.Background(), userID, imp.ID)
+	require.NoError(t, err)
+	lot := lots[0]
+
+	corrected := decimal.RequireFromString("455.95")
+	_, err = service.AdjustLot(context.Background(), adminID, lot.ID, &entities.AdjustTaxLotRequest{CostBasis: &corrected})
+	assert.ErrorIs(t, err, costbasis.ErrInvalidAdjustment, "a reason is required")
+
+	negative := decimal.RequireFromString("-1")
+	_, err = service.AdjustLot(context.Background(), adminID, lot.ID, &entities.AdjustTaxLotRequest{Quantity: &negative, Reason: "custodian figures"})
+	assert.ErrorIs(t, err, costbasis.ErrInvalidAdjustment)
+
+	adjusted, err := service.AdjustLot(context.Background(), adminID, lot.ID, &entities.AdjustTaxLotRequest{CostBasis: &corrected, Reason: "custodian figures"})
+	require.NoError(t, err)
+	assert.True(t, adjusted.Adjusted)
+	assert.True(t, corrected.Equal(adjusted.CostBasis))
+	assert.True(t, lot.Quantity.Equal(adjusted.Quantity), "unset fields are kept")
+
+	adjustments, err := service.ListAdjustments(context.Background(), lot.ID)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, adminID, adjustments[0].AdminID)
+	assert.True(t, decimal.RequireFromString("451.20").Equal(adjustments[0].Before.CostBasis))
+	assert.False(t, adjustments[0].Before.Adjusted)
+	assert.True(t, corrected.Equal(adjustments[0].After.CostBasis))
+
+	_, err = service.ListAdjustments(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, entities.ErrTaxLotNotFound)
+}
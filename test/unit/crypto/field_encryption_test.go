@@ -0,0 +1,140 @@
+package crypto_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+func newEncryptor(t *testing.T, spec string, active int) *crypto.FieldEncryptor {
+	t.Helper()
+	keys, err := crypto.ParseFieldKeys(spec)
+	require.NoError(t, err)
+	enc, err := crypto.NewFieldEncryptor(crypto.StaticFieldKeySource{Keys: keys, ActiveVersion: active}, []byte("index-key"))
+	require.NoError(t, err)
+	return enc
+}
+
+func TestFieldEncryptor_RoundTrip(t *testing.T) {
+	enc := newEncryptor(t, "1:first-secret", 1)
+
+	sealed, err := enc.Encrypt("+15551234567")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "enc:v1:"))
+	assert.NotContains(t, sealed, "5551234567")
+
+	plain, err := enc.Decrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", plain)
+
+	again, err := enc.Encrypt("+15551234567")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "ciphertexts should use fresh nonces")
+}
+
+func TestFieldEncryptor_LegacyPlaintextPassesThrough(t *testing.T) {
+	enc := newEncryptor(t, "1:first-secret", 1)
+
+	plain, err := enc.Decrypt("021000021")
+	require.NoError(t, err)
+	assert.Equal(t, "021000021", plain)
+	assert.True(t, enc.NeedsRotation("021000021"))
+	assert.False(t, enc.NeedsRotation(""))
+}
+
+func TestFieldEncryptor_KeyRotation(t *testing.T) {
+	oldEnc := newEncryptor(t, "1:first-secret", 1)
+	sealed, err := oldEnc.Encrypt("ref-123")
+	require.NoError(t, err)
+
+	rotated := newEncryptor(t, "1:first-secret,2:second-secret", 2)
+	assert.True(t, rotated.NeedsRotation(sealed))
+
+	plain, err := rotated.Decrypt(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "ref-123", plain)
+
+	resealed, err := rotated.EncryptField(plain, "users", "kyc_provider_ref", "row-1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resealed, "enc:b2:"))
+	assert.False(t, rotated.NeedsRotation(resealed))
+
+	retired := newEncryptor(t, "2:second-secret", 2)
+	_, err = retired.Decrypt(sealed)
+	assert.Error(t, err, "values under a removed key version must not decrypt")
+}
+
+func TestFieldEncryptor_BoundToTableColumnAndRow(t *testing.T) {
+	enc := newEncryptor(t, "1:first-secret", 1)
+	sealed, err := enc.EncryptField("021000021", "virtual_accounts", "account_number", "row-1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "enc:b1:"))
+
+	plain, err := enc.DecryptField(sealed, "virtual_accounts", "account_number", "row-1")
+	require.NoError(t, err)
+	assert.Equal(t, "021000021", plain)
+
+	for name, location := range map[string][3]string{
+		"row":    {"virtual_accounts", "account_number", "row-2"},
+		"column": {"virtual_accounts", "routing_number", "row-1"},
+		"table":  {"withdrawal_recipients", "account_number", "row-1"},
+	} {
+		_, err := enc.DecryptField(sealed, location[0], location[1], location[2])
+		assert.Error(t, err, "value copied to another %s must not decrypt", name)
+	}
+
+	_, err = enc.Decrypt(sealed)
+	assert.Error(t, err, "bound values must not decrypt without their location")
+}
+
+func TestFieldEncryptor_UnboundValuesReadUntilRotated(t *testing.T) {
+	enc := newEncryptor(t, "1:first-secret", 1)
+	legacy, err := enc.Encrypt("+15551234567")
+	require.NoError(t, err)
+	assert.True(t, enc.NeedsRotation(legacy))
+
+	plain, err := enc.DecryptField(legacy, "users", "phone", "row-1")
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", plain)
+
+	plain, err = enc.DecryptField("+15551234567", "users", "phone", "row-1")
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", plain)
+}
+
+func TestFieldEncryptor_BlindIndexStableAcrossRotation(t *testing.T) {
+	v1 := newEncryptor(t, "1:first-secret", 1)
+	v2 := newEncryptor(t, "1:first-secret,2:second-secret", 2)
+
+	assert.Equal(t, v1.BlindIndex("+15551234567"), v2.BlindIndex("+15551234567"))
+	assert.NotEqual(t, v1.BlindIndex("+15551234567"), v1.BlindIndex("+15551234568"))
+}
+
+func TestFieldEncryptor_TamperedCiphertextFails(t *testing.T) {
+	enc := newEncryptor(t, "1:first-secret", 1)
+	sealed, err := enc.Encrypt("123456789")
+	require.NoError(t, err)
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if tampered == sealed {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	_, err = enc.Decrypt(tampered)
+	assert.Error(t, err)
+}
+
+func TestParseFieldKeys_Invalid(t *testing.T) {
+	for _, spec := range []string{"nope", "0:secret", "x:secret", "1:", "1:a,1:b"} {
+		_, err := crypto.ParseFieldKeys(spec)
+		assert.Error(t, err, spec)
+	}
+
+	keys, err := crypto.ParseFieldKeys("1:a")
+	require.NoError(t, err)
+	_, err = crypto.NewFieldEncryptor(crypto.StaticFieldKeySource{Keys: keys, ActiveVersion: 2}, []byte("k"))
+	assert.Error(t, err, "active version must exist in the keyring")
+}
@@ -0,0 +1,143 @@
+package custodial_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/custodial"
+)
+
+func TestAgeOn_HandlesBirthdaysAndLeapDays(t *testing.T) {
+	dob := time.Date(2008, time.March, 15, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 17, entities.AgeOn(dob, time.Date(2026, time.March, 14, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 18, entities.AgeOn(dob, time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)))
+
+	leap := time.Date(2008, time.February, 29, 0, 0, 0, 0, time.UTC)
+	assert.False(t, entities.IsAdult(leap, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, entities.IsAdult(leap, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), entities.MajorityDate(leap))
+}
+
+type fakeRepo struct {
+	accounts map[uuid.UUID]*entities.CustodialAccount
+}
+
+func (f *fakeRepo) Create(ctx context.Context, account *entities.CustodialAccount) error {
+	f.accounts[account.ID] = account
+	return nil
+}
+
+func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.CustodialAccount, error) {
+	account, ok := f.accounts[id]
+	if !ok {
+		return nil, entities.ErrCustodialNotFound
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListByGuardian(ctx context.Context, guardianUserID uuid.UUID) ([]*entities.CustodialAccount, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) ListPendingTransferByEmail(ctx context.Context, email string) ([]*entities.CustodialAccount, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) MarkTransferPending(ctx context.Context, id uuid.UUID, email string, initiatedAt time.Time) error {
+	f.accounts[id].Status = entities.CustodialStatusTransferPending
+	f.accounts[id].TransferEmail = &email
+	return nil
+}
+
+func (f *fakeRepo) MarkTransferred(ctx context.Context, id, beneficiaryUserID uuid.UUID, transferredAt time.Time) error {
+	f.accounts[id].Status = entities.CustodialStatusTransferred
+	f.accounts[id].BeneficiaryUserID = &beneficiaryUserID
+	return nil
+}
+
+type fakeUsers map[uuid.UUID]*entities.UserProfile
+
+func (f fakeUsers) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error) {
+	user, ok := f[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func yearsAgo(years int) time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year()-years, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+}
+
+func verifiedUser(email string, dob time.Time) *entities.UserProfile {
+	return &entities.UserProfile{ID: uuid.New(), Email: email, DateOfBirth: &dob, KYCStatus: string(entities.KYCStatusApproved)}
+}
+
+func TestCreate_RequiresVerifiedAdultGuardianAndMinorBeneficiary(t *testing.T) {
+	guardian := verifiedUser("parent@example.com", yearsAgo(40))
+	minorGuardian := verifiedUser("teen@example.com", yearsAgo(16))
+	users := fakeUsers{guardian.ID: guardian, minorGuardian.ID: minorGuardian}
+	svc := custodial.NewService(&fakeRepo{accounts: map[uuid.UUID]*entities.CustodialAccount{}}, users, nil, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, minorGuardian.ID, &entities.CreateCustodialAccountRequest{
+		BeneficiaryFirstName: "Kid", BeneficiaryLastName: "Doe", BeneficiaryDOB: yearsAgo(10), Relationship: "parent",
+	})
+	assert.True(t, errors.Is(err, entities.ErrUnderage))
+
+	_, err = svc.Create(ctx, guardian.ID, &entities.CreateCustodialAccountRequest{
+		BeneficiaryFirstName: "Grown", BeneficiaryLastName: "Doe", BeneficiaryDOB: yearsAgo(19), Relationship: "parent",
+	})
+	assert.True(t, errors.Is(err, custodial.ErrIneligible))
+
+	account, err := svc.Create(ctx, guardian.ID, &entities.CreateCustodialAccountRequest{
+		BeneficiaryFirstName: "Kid", BeneficiaryLastName: "Doe", BeneficiaryDOB: yearsAgo(10), Relationship: "parent",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.CustodialStatusActive, account.Status)
+
+	_, err = svc.InitiateTransfer(ctx, guardian.ID, account.ID, "kid@example.com")
+	assert.True(t, errors.Is(err, entities.ErrNotYetMajority))
+}
+
+func TestTransfer_ClaimedByMatchingVerifiedBeneficiary(t *testing.T) {
+	guardian := verifiedUser("parent@example.com", yearsAgo(45))
+	dob := yearsAgo(18)
+	beneficiary := verifiedUser("kid@example.com", dob)
+	impostor := verifiedUser("kid2@example.com", dob)
+	users := fakeUsers{guardian.ID: guardian, beneficiary.ID: beneficiary, impostor.ID: impostor}
+
+	accountID := uuid.New()
+	repo := &fakeRepo{accounts: map[uuid.UUID]*entities.CustodialAccount{
+		accountID: {
+			ID: accountID, GuardianUserID: guardian.ID, BeneficiaryDOB: dob,
+			Status: entities.CustodialStatusActive, MajorityDate: entities.MajorityDate(dob),
+		},
+	}}
+	svc := custodial.NewService(repo, users, nil, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.InitiateTransfer(ctx, beneficiary.ID, accountID, "kid@example.com")
+	assert.True(t, errors.Is(err, entities.ErrCustodialNotFound))
+
+	_, err = svc.InitiateTransfer(ctx, guardian.ID, accountID, "Kid@Example.com")
+	require.NoError(t, err)
+
+	_, err = svc.CompleteTransfer(ctx, impostor.ID, accountID)
+	assert.True(t, errors.Is(err, custodial.ErrNotPermitted))
+
+	account, err := svc.CompleteTransfer(ctx, beneficiary.ID, accountID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.CustodialStatusTransferred, account.Status)
+	assert.Equal(t, beneficiary.ID, *account.BeneficiaryUserID)
+}
@@ -0,0 +1,142 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/infrastructure/database"
+)
+
+// commitDriver is a database/sql driver whose commits fail with the queued
+// errors, one per commit, and succeed once the queue is empty
+type commitDriver struct {
+	mu         sync.Mutex
+	failures   []error
+	isolations []sql.IsolationLevel
+	commits    int
+	rollbacks  int
+}
+
+func (d *commitDriver) Open(name string) (driver.Conn, error) { return &commitConn{d: d}, nil }
+
+type commitConn struct{ d *commitDriver }
+
+func (c *commitConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *commitConn) Close() error              { return nil }
+func (c *commitConn) Begin() (driver.Tx, error) { return &commitTx{d: c.d}, nil }
+
+func (c *commitConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.mu.Lock()
+	c.d.isolations = append(c.d.isolations, sql.IsolationLevel(opts.Isolation))
+	c.d.mu.Unlock()
+	return &commitTx{d: c.d}, nil
+}
+
+type commitTx struct{ d *commitDriver }
+
+func (t *commitTx) Commit() error {
+	t.d.mu.Lock()
+	defer t.d.mu.Unlock()
+	if len(t.d.failures) > 0 {
+		err := t.d.failures[0]
+		t.d.failures = t.d.failures[1:]
+		return err
+	}
+	t.d.commits++
+	return nil
+}
+
+func (t *commitTx) Rollback() error {
+	t.d.mu.Lock()
+	defer t.d.mu.Unlock()
+	t.d.rollbacks++
+	return nil
+}
+
+var driverSeq int
+
+func openDB(t *testing.T, failures ...error) (*sql.DB, *commitDriver) {
+	t.Helper()
+	d := &commitDriver{failures: failures}
+	driverSeq++
+	name := fmt.Sprintf("commit-driver-%d", driverSeq)
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func serializationFailure() error {
+	return &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	assert.True(t, database.IsSerializationFailure(serializationFailure()))
+	assert.True(t, database.IsSerializationFailure(&pq.Error{Code: "40P01"}))
+	assert.True(t, database.IsSerializationFailure(fmt.Errorf("update account balance: %w", serializationFailure())))
+	assert.False(t, database.IsSerializationFailure(&pq.Error{Code: "23505"}))
+	assert.False(t, database.IsSerializationFailure(errors.New("insufficient balance")))
+}
+
+func TestSerializableTransactionRetriesConflicts(t *testing.T) {
+	db, d := openDB(t, serializationFailure(), serializationFailure())
+
+	runs := 0
+	err := database.WithSerializableTransaction(context.Background(), db, "test_retry", func(tx *sql.Tx) error {
+		runs++
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, runs)
+	assert.Equal(t, 1, d.commits)
+	for _, isolation := range d.isolations {
+		assert.Equal(t, sql.LevelSerializable, isolation)
+	}
+}
+
+func TestSerializableTransactionGivesUpAfterRepeatedConflicts(t *testing.T) {
+	failures := make([]error, 10)
+	for i := range failures {
+		failures[i] = serializationFailure()
+	}
+	db, d := openDB(t, failures...)
+
+	runs := 0
+	err := database.WithSerializableTransaction(context.Background(), db, "test_exhausted", func(tx *sql.Tx) error {
+		runs++
+		return nil
+	})
+
+	assert.True(t, database.IsSerializationFailure(err))
+	assert.Equal(t, 5, runs)
+	assert.Zero(t, d.commits)
+}
+
+func TestSerializableTransactionDoesNotRetryOtherErrors(t *testing.T) {
+	db, d := openDB(t)
+	insufficient := errors.New("insufficient balance")
+
+	runs := 0
+	err := database.WithSerializableTransaction(context.Background(), db, "test_failed", func(tx *sql.Tx) error {
+		runs++
+		return insufficient
+	})
+
+	assert.ErrorIs(t, err, insufficient)
+	assert.Equal(t, 1, runs)
+	assert.Zero(t, d.commits)
+	assert.Equal(t, 1, d.rollbacks)
+}
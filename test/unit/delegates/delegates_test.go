@@ -0,0 +1,313 @@
+package delegates_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/delegates"
+)
+
+type fakeRepo struct {
+	grants   map[uuid.UUID]*entities.DelegateGrant
+	sessions map[string]*entities.DelegateSession
+	access   []*entities.DelegateAccessEvent
+	failLog  bool
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{grants: map[uuid.UUID]*entities.DelegateGrant{}, sessions: map[string]*entities.DelegateSession{}}
+}
+
+func (r *fakeRepo) CreateGrant(_ context.Context, grant *entities.DelegateGrant) error {
+	copied := *grant
+	r.grants[grant.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) GetGrant(_ context.Context, userID, id uuid.UUID) (*entities.DelegateGrant, error) {
+	grant, ok := r.grants[id]
+	if !ok || grant.UserID != userID {
+		return nil, entities.ErrDelegateGrantNotFound
+	}
+	copied := *grant
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetGrantByTokenHash(_ context.Context, tokenHash string) (*entities.DelegateGrant, error) {
+	for _, grant := range r.grants {
+		if grant.TokenHash == tokenHash {
+			copied := *grant
+			return &copied, nil
+		}
+	}
+	return nil, entities.ErrDelegateGrantNotFound
+}
+
+func (r *fakeRepo) ListGrants(_ context.Context, userID uuid.UUID) ([]*entities.DelegateGrant, error) {
+	var grants []*entities.DelegateGrant
+	for _, grant := range r.grants {
+		if grant.UserID == userID {
+			copied := *grant
+			grants = append(grants, &copied)
+		}
+	}
+	return grants, nil
+}
+
+func (r *fakeRepo) CountActiveGrants(_ context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	count := 0
+	for _, grant := range r.grants {
+		if grant.UserID == userID && grant.State(now) == entities.DelegateGrantActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeRepo) RevokeGrant(_ context.Context, userID, id uuid.UUID, at time.Time) error {
+	grant, ok := r.grants[id]
+	if !ok || grant.UserID != userID {
+		return entities.ErrDelegateGrantNotFound
+	}
+	grant.Status = entities.DelegateGrantRevoked
+	grant.RevokedAt = &at
+	for hash, session := range r.sessions {
+		if session.GrantID == id {
+			delete(r.sessions, hash)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) CreateSession(_ context.Context, session *entities.DelegateSession) error {
+	r.sessions[session.TokenHash] = session
+	return nil
+}
+
+func (r *fakeRepo) GetSessionGrant(_ context.Context, tokenHash string, now time.Time) (*entities.DelegateGrant, error) {
+	session, ok := r.sessions[tokenHash]
+	if !ok || !now.Before(session.ExpiresAt) {
+		return nil, entities.ErrDelegateSessionInvalid
+	}
+	copied := *r.grants[session.GrantID]
+	return &copied, nil
+}
+
+func (r *fakeRepo) TouchGrant(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.grants[id].LastUsedAt = &at
+	return nil
+}
+
+func (r *fakeRepo) RecordAccess(_ context.Context, event *entities.DelegateAccessEvent) error {
+	if r.failLog {
+		return errors.New("database unavailable")
+	}
+	r.access = append(r.access, event)
+	return nil
+}
+
+func (r *fakeRepo) ListAccess(_ context.Context, userID, grantID uuid.UUID, limit int) ([]*entities.DelegateAccessEvent, error) {
+	var events []*entities.DelegateAccessEvent
+	for _, event := range r.access {
+		if event.UserID == userID && event.GrantID == grantID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+type fakePortfolio struct{}
+
+func (fakePortfolio) GetPortfolio(_ context.Context, userID uuid.UUID) (*entities.Portfolio, error) {
+	return &entities.Portfolio{Currency: "USD", TotalValue: "1250.00"}, nil
+}
+
+type fakeStatements struct {
+	documents map[uuid.UUID]*entities.Document
+	filters   []entities.DocumentFilter
+}
+
+func (f *fakeStatements) List(_ context.Context, userID uuid.UUID, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	f.filters = append(f.filters, filter)
+	var list []*entities.Document
+	for _, document := range f.documents {
+		if document.Category == filter.Category {
+			list = append(list, document)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeStatements) Get(_ context.Context, userID, id uuid.UUID) (*entities.Document, error) {
+	document, ok := f.documents[id]
+	if !ok {
+		return nil, entities.ErrDocumentNotFound
+	}
+	return document, nil
+}
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func newService(repo *fakeRepo, statements *fakeStatements) (*delegates.Service, *time.Time) {
+	clock := now
+	service := delegates.NewService(repo, fakePortfolio{}, statements, delegates.DefaultConfig(), zap.NewNop())
+	service.SetClock(func() time.Time { return clock })
+	return service, &clock
+}
+
+func grantRequest(scopes ...entities.DelegateScope) *entities.CreateDelegateGrantRequest {
+	return &entities.CreateDelegateGrantRequest{
+		DelegateName:  "Grace Hopper",
+		DelegateEmail: "Advisor@Example.com",
+		Relationship:  entities.DelegateRelationshipAdvisor,
+		Scopes:        scopes,
+	}
+}
+
+func TestGrantLoginAndViewRecordsEveryView(t *testing.T) {
+	repo := newFakeRepo()
+	confirmation := &entities.Document{ID: uuid.New(), Category: entities.DocumentCategoryTradeConfirmation, Body: "TRADE CONFIRMATION"}
+	agreement := &entities.Document{ID: uuid.New(), Category: entities.DocumentCategoryAgreement}
+	statements := &fakeStatements{documents: map[uuid.UUID]*entities.Document{confirmation.ID: confirmation, agreement.ID: agreement}}
+	service, _ := newService(repo, statements)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	created, err := service.Grant(ctx, userID, grantRequest("Portfolio", entities.DelegateScopeStatements, entities.DelegateScopePortfolio))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, "dlg_"))
+	assert.Equal(t, []entities.DelegateScope{entities.DelegateScopePortfolio, entities.DelegateScopeStatements}, created.Grant.Scopes)
+	assert.Equal(t, now.Add(365*24*time.Hour), created.Grant.ExpiresAt)
+	assert.NotContains(t, repo.grants[created.Grant.ID].TokenHash, created.Token, "only the hash is stored")
+
+	viewer := delegates.Viewer{IPAddress: "203.0.113.9", UserAgent: "test"}
+	session, err := service.Login(ctx, &entities.DelegateLoginRequest{Email: "advisor@example.com", Token: created.Token}, viewer)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), session.ExpiresAt)
+
+	grant, err := service.Authenticate(ctx, session.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, userID, grant.UserID)
+
+	portfolio, err := service.Portfolio(ctx, grant, viewer)
+	require.NoError(t, err)
+	assert.Equal(t, "1250.00", portfolio.TotalValue)
+
+	listed, err := service.Statements(ctx, grant, 0, 0, viewer)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, entities.DocumentCategoryTradeConfirmation, statements.filters[0].Category)
+
+	_, err = service.Statement(ctx, grant, agreement.ID, viewer)
+	assert.ErrorIs(t, err, entities.ErrDocumentNotFound, "only trade confirmations are shared")
+	document, err := service.Statement(ctx, grant, confirmation.ID, viewer)
+	require.NoError(t, err)
+	assert.Equal(t, "TRADE CONFIRMATION", document.Body)
+
+	log, err := service.AccessLog(ctx, userID, created.Grant.ID, 0)
+	require.NoError(t, err)
+	var resources []string
+	for _, event := range log {
+		resources = append(resources, event.Resource)
+		assert.Equal(t, "203.0.113.9", event.IPAddress)
+	}
+	assert.Equal(t, []string{"sign_in", "portfolio", "statement_list", "statement"}, resources)
+	assert.Equal(t, confirmation.ID.String(), *log[3].ResourceID)
+
+	_, err = service.AccessLog(ctx, uuid.New(), created.Grant.ID, 0)
+	assert.ErrorIs(t, err, entities.ErrDelegateGrantNotFound, "grants are private to their owner")
+}
+
+func TestScopesLimitWhatDelegatesSee(t *testing.T) {
+	repo := newFakeRepo()
+	service, _ := newService(repo, &fakeStatements{})
+	ctx := context.Background()
+
+	created, err := service.Grant(ctx, uuid.New(), grantRequest(entities.DelegateScopeStatements))
+	require.NoError(t, err)
+
+	_, err = service.Portfolio(ctx, repo.grants[created.Grant.ID], delegates.Viewer{})
+	assert.ErrorIs(t, err, delegates.ErrScopeNotGranted)
+	assert.Empty(t, repo.access)
+
+	_, err = service.Grant(ctx, uuid.New(), grantRequest("withdrawals"))
+	assert.ErrorIs(t, err, delegates.ErrInvalidScope)
+}
+
+func TestViewsAreRefusedWhenTheyCannotBeRecorded(t *testing.T) {
+	repo := newFakeRepo()
+	service, _ := newService(repo, &fakeStatements{})
+	ctx := context.Background()
+
+	created, err := service.Grant(ctx, uuid.New(), grantRequest(entities.DelegateScopePortfolio))
+	require.NoError(t, err)
+
+	repo.failLog = true
+	_, err = service.Portfolio(ctx, repo.grants[created.Grant.ID], delegates.Viewer{})
+	assert.Error(t, err)
+}
+
+func TestLoginRefusesWrongEmailRevokedAndExpiredGrants(t *testing.T) {
+	repo := newFakeRepo()
+	service, clock := newService(repo, &fakeStatements{})
+	ctx := context.Background()
+	userID := uuid.New()
+
+	req := grantRequest(entities.DelegateScopePortfolio)
+	req.ExpiresInDays = 30
+	created, err := service.Grant(ctx, userID, req)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(30*24*time.Hour), created.Grant.ExpiresAt)
+
+	_, err = service.Login(ctx, &entities.DelegateLoginRequest{Email: "someone@example.com", Token: created.Token}, delegates.Viewer{})
+	assert.ErrorIs(t, err, delegates.ErrInvalidCredentials)
+	_, err = service.Login(ctx, &entities.DelegateLoginRequest{Email: "advisor@example.com", Token: "dlg_unknown"}, delegates.Viewer{})
+	assert.ErrorIs(t, err, delegates.ErrInvalidCredentials)
+
+	session, err := service.Login(ctx, &entities.DelegateLoginRequest{Email: "advisor@example.com", Token: created.Token}, delegates.Viewer{})
+	require.NoError(t, err)
+
+	_, err = service.Revoke(ctx, userID, created.Grant.ID)
+	require.NoError(t, err)
+	_, err = service.Authenticate(ctx, session.AccessToken)
+	assert.ErrorIs(t, err, entities.ErrDelegateSessionInvalid, "revoking signs the delegate out")
+	_, err = service.Login(ctx, &entities.DelegateLoginRequest{Email: "advisor@example.com", Token: created.Token}, delegates.Viewer{})
+	assert.ErrorIs(t, err, delegates.ErrInvalidCredentials)
+	_, err = service.Revoke(ctx, userID, created.Grant.ID)
+	assert.ErrorIs(t, err, delegates.ErrGrantNotActive)
+
+	other, err := service.Grant(ctx, userID, req)
+	require.NoError(t, err)
+	*clock = now.Add(31 * 24 * time.Hour)
+	_, err = service.Login(ctx, &entities.DelegateLoginRequest{Email: "advisor@example.com", Token: other.Token}, delegates.Viewer{})
+	assert.ErrorIs(t, err, delegates.ErrInvalidCredentials)
+
+	grants, err := service.List(ctx, userID)
+	require.NoError(t, err)
+	statuses := map[uuid.UUID]entities.DelegateGrantStatus{}
+	for _, grant := range grants {
+		statuses[grant.ID] = grant.Status
+	}
+	assert.Equal(t, entities.DelegateGrantRevoked, statuses[created.Grant.ID])
+	assert.Equal(t, entities.DelegateGrantExpired, statuses[other.Grant.ID])
+}
+
+func TestGrantEnforcesLimit(t *testing.T) {
+	repo := newFakeRepo()
+	service := delegates.NewService(repo, fakePortfolio{}, &fakeStatements{}, delegates.Config{MaxGrants: 1}, zap.NewNop())
+	userID := uuid.New()
+
+	_, err := service.Grant(context.Background(), userID, grantRequest(entities.DelegateScopePortfolio))
+	require.NoError(t, err)
+	_, err = service.Grant(context.Background(), userID, grantRequest(entities.DelegateScopePortfolio))
+	assert.ErrorIs(t, err, delegates.ErrTooManyGrants)
+}
@@ -0,0 +1,350 @@
+package depositref_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/depositref"
+)
+
+type memoryRepo struct {
+	refs    []*entities.DepositReference
+	credits map[uuid.UUID]*entities.BankCredit
+	names   map[string]uuid.UUID
+}
+
+func (r *memoryRepo) CreateReference(_ context.Context, ref *entities.DepositReference) (bool, error) {
+	for _, existing := range r.refs {
+		if existing.UserID == ref.UserID || existing.Code == ref.Code {
+			return false, nil
+		}
+	}
+	r.refs = append(r.refs, ref)
+	return true, nil
+}
+
+func (r *memoryRepo) GetReferenceByUser(_ context.Context, userID uuid.UUID) (*entities.DepositReference, error) {
+	for _, ref := range r.refs {
+		if ref.UserID == userID {
+			return ref, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepo) GetReferenceByCode(_ context.Context, code string) (*entities.DepositReference, error) {
+	for _, ref := range r.refs {
+		if ref.Code == code {
+			return ref, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepo) CreateCredit(_ context.Context, credit *entities.BankCredit) (*entities.BankCredit, bool, error) {
+	for _, existing := range r.credits {
+		if existing.ProviderRef == credit.ProviderRef {
+			copied := *existing
+			return &copied, false, nil
+		}
+	}
+	copied := *credit
+	r.credits[credit.ID] = &copied
+	return credit, true, nil
+}
+
+func (r *memoryRepo) GetCredit(_ context.Context, id uuid.UUID) (*entities.BankCredit, error) {
+	credit, ok := r.credits[id]
+	if !ok {
+		return nil, entities.ErrBankCreditNotFound
+	}
+	copied := *credit
+	return &copied, nil
+}
+
+func (r *memoryRepo) ListReferencesByCodes(_ context.Context, codes []string) ([]*entities.DepositReference, error) {
+	var refs []*entities.DepositReference
+	for _, ref := range r.refs {
+		for _, code := range codes {
+			if ref.Code == code {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs, nil
+}
+
+func (r *memoryRepo) SuggestUsersBySenderName(_ context.Context, senderName string, _ int) ([]entities.SuspenseSuggestion, error) {
+	var suggestions []entities.SuspenseSuggestion
+	for name, userID := range r.names {
+		if strings.Contains(strings.ToLower(senderName), strings.ToLower(name)) {
+			suggestions = append(suggestions, entities.SuspenseSuggestion{UserID: userID, Reason: entities.SuspenseSuggestionSenderName})
+		}
+	}
+	return suggestions, nil
+}
+
+func (r *memoryRepo) ClaimCredit(_ context.Context, credit *entities.BankCredit) (bool, error) {
+	stored := r.credits[credit.ID]
+	if stored.Status != entities.BankCreditStatusUnmatched {
+		return false, nil
+	}
+	stored.Status = entities.BankCreditStatusCrediting
+	stored.UserID = credit.UserID
+	stored.MatchMethod = credit.MatchMethod
+	stored.MatchedBy = credit.MatchedBy
+	stored.Note = credit.Note
+	return true, nil
+}
+
+func (r *memoryRepo) ReleaseCredit(_ context.Context, id uuid.UUID, _ time.Time) error {
+	stored := r.credits[id]
+	stored.Status = entities.BankCreditStatusUnmatched
+	stored.UserID, stored.MatchMethod, stored.MatchedBy, stored.Note = nil, nil, nil, nil
+	return nil
+}
+
+func (r *memoryRepo) MarkCredited(_ context.Context, id uuid.UUID, ledgerTxID *uuid.UUID, _ time.Time) error {
+	r.credits[id].Status = entities.BankCreditStatusCredited
+	r.credits[id].LedgerTransactionID = ledgerTxID
+	return nil
+}
+
+func (r *memoryRepo) MarkReturned(_ context.Context, id, returnedBy uuid.UUID, note string, _ time.Time) (bool, error) {
+	stored := r.credits[id]
+	if stored.Status != entities.BankCreditStatusUnmatched {
+		return false, nil
+	}
+	stored.Status = entities.BankCreditStatusReturned
+	stored.MatchedBy = &returnedBy
+	stored.Note = &note
+	return true, nil
+}
+
+type fakeCrediter struct {
+	credited map[uuid.UUID]decimal.Decimal
+	fail     error
+}
+
+func (f *fakeCrediter) CreditBankTransfer(_ context.Context, credit *entities.BankCredit, usdAmount decimal.Decimal) (*uuid.UUID, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.credited[*credit.UserID] = f.credited[*credit.UserID].Add(usdAmount)
+	txID := uuid.New()
+	return &txID, nil
+}
+
+type fakeSuspense struct {
+	items map[string]*entities.SuspenseItem
+}
+
+func (f *fakeSuspense) Hold(_ context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, error) {
+	if held, ok := f.items[item.SourceRef]; ok {
+		return held, nil
+	}
+	item.ID = uuid.New()
+	f.items[item.SourceRef] = item
+	return item, nil
+}
+
+const sharedAccountNumber = "0123456789"
+
+func newService() (*depositref.Service, *memoryRepo, *fakeCrediter) {
+	repo := &memoryRepo{credits: map[uuid.UUID]*entities.BankCredit{}, names: map[string]uuid.UUID{}}
+	crediter := &fakeCrediter{credited: map[uuid.UUID]decimal.Decimal{}}
+	service := depositref.NewService(repo, crediter, []depositref.SharedAccount{{
+		Currency:      "USD",
+		BankName:      "Pooled Bank",
+		AccountName:   "Stack Client Funds",
+		AccountNumber: sharedAccountNumber,
+	}}, zap.NewNop())
+	return service, repo, crediter
+}
+
+func notice(ref, memo string) *entities.BankCreditWebhook {
+	return &entities.BankCreditWebhook{
+		ProviderRef:   ref,
+		AccountNumber: sharedAccountNumber,
+		Currency:      "usd",
+		Amount:        decimal.NewFromInt(250),
+		Memo:          memo,
+	}
+}
+
+func TestFundingInstructionsRequireTheUsersReference(t *testing.T) {
+	service, _, _ := newService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	instructions, err := service.GetFundingInstructions(ctx, userID, "usd")
+	require.NoError(t, err)
+	assert.True(t, instructions.ReferenceRequired)
+	assert.Equal(t, sharedAccountNumber, instructions.AccountNumber)
+	assert.Regexp(t, `^STK-[0-9A-Z]{4}-[0-9A-Z]{4}$`, instructions.Reference)
+
+	again, err := service.GetFundingInstructions(ctx, userID, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, instructions.Reference, again.Reference)
+
+	other, err := service.GetFundingInstructions(ctx, uuid.New(), "USD")
+	require.NoError(t, err)
+	assert.NotEqual(t, instructions.Reference, other.Reference)
+
+	_, err = service.GetFundingInstructions(ctx, userID, "NGN")
+	assert.ErrorIs(t, err, entities.ErrNoSharedDepositAccount)
+}
+
+func TestParseReferencesToleratesFormattingButNotTypos(t *testing.T) {
+	service, _, _ := newService()
+	instructions, err := service.GetFundingInstructions(context.Background(), uuid.New(), "USD")
+	require.NoError(t, err)
+	canonical := strings.ReplaceAll(instructions.Reference, "-", "")
+
+	codes, found := depositref.ParseReferences("rent + " + strings.ToLower(instructions.Reference) + " thanks")
+	assert.True(t, found)
+	assert.Equal(t, []string{canonical}, codes)
+
+	spaced := strings.ReplaceAll(strings.ReplaceAll(instructions.Reference, "-", " "), "0", "O")
+	codes, _ = depositref.ParseReferences(spaced)
+	assert.Equal(t, []string{canonical}, codes)
+
+	// Change one character of the body
+	typo := []byte(canonical)
+	if typo[5] == 'A' {
+		typo[5] = 'B'
+	} else {
+		typo[5] = 'A'
+	}
+	codes, found = depositref.ParseReferences(string(typo))
+	assert.True(t, found)
+	assert.Empty(t, codes)
+
+	codes, found = depositref.ParseReferences("invoice 4411")
+	assert.False(t, found)
+	assert.Empty(t, codes)
+}
+
+func TestReceiveCreditMatchesByReferenceOnce(t *testing.T) {
+	service, _, crediter := newService()
+	ctx := context.Background()
+	userID := uuid.New()
+	instructions, err := service.GetFundingInstructions(ctx, userID, "USD")
+	require.NoError(t, err)
+
+	credit, err := service.ReceiveCredit(ctx, notice("bank-1", "Deposit "+instructions.Reference))
+	require.NoError(t, err)
+	assert.Equal(t, entities.BankCreditStatusCredited, credit.Status)
+	assert.Equal(t, userID, *credit.UserID)
+	assert.Equal(t, entities.BankCreditMatchReference, *credit.MatchMethod)
+	assert.Equal(t, "USD", credit.Currency)
+
+	redelivered, err := service.ReceiveCredit(ctx, notice("bank-1", "Deposit "+instructions.Reference))
+	require.NoError(t, err)
+	assert.Equal(t, credit.ID, redelivered.ID)
+	assert.True(t, decimal.NewFromInt(250).Equal(crediter.credited[userID]))
+}
+
+func TestUnattributedCreditsAreHeldInSuspense(t *testing.T) {
+	service, _, crediter := newService()
+	held := &fakeSuspense{items: map[string]*entities.SuspenseItem{}}
+	service.SetSuspense(held)
+	ctx := context.Background()
+	userID := uuid.New()
+	adminID := uuid.New()
+
+	noRef, err := service.ReceiveCredit(ctx, notice("bank-2", "from mum"))
+	require.NoError(t, err)
+	assert.Equal(t, entities.BankCreditStatusUnmatched, noRef.Status)
+	assert.Equal(t, entities.BankCreditNoReference, *noRef.UnmatchedReason)
+
+	wrongAccount := notice("bank-3", "from dad")
+	wrongAccount.AccountNumber = "999"
+	unknown, err := service.ReceiveCredit(ctx, wrongAccount)
+	require.NoError(t, err)
+	assert.Equal(t, entities.BankCreditUnknownAccount, *unknown.UnmatchedReason)
+
+	_, err = service.ReceiveCredit(ctx, notice("bank-2", "from mum"))
+	require.NoError(t, err)
+	require.Len(t, held.items, 2)
+	item := held.items[noRef.ID.String()]
+	assert.Equal(t, entities.SuspenseSourceBankCredit, item.Source)
+	assert.Equal(t, entities.BankCreditNoReference, item.Reason)
+	assert.True(t, decimal.NewFromInt(250).Equal(item.USDAmount))
+	assert.Equal(t, "from mum", item.Details["memo"])
+
+	require.NoError(t, service.AssignSuspended(ctx, item, userID, adminID, "sender name matches KYC"))
+	assert.True(t, decimal.NewFromInt(250).Equal(crediter.credited[userID]))
+	assert.ErrorIs(t, service.AssignSuspended(ctx, item, userID, adminID, "again"), entities.ErrBankCreditNotUnmatched)
+
+	require.NoError(t, service.RefundSuspended(ctx, held.items[unknown.ID.String()], adminID, "sent back to sender"))
+	assert.ErrorIs(t, service.RefundSuspended(ctx, held.items[unknown.ID.String()], adminID, "again"), entities.ErrBankCreditNotUnmatched)
+}
+
+func TestSuspenseSuggestionsFindTyposAndSenderNames(t *testing.T) {
+	service, repo, _ := newService()
+	held := &fakeSuspense{items: map[string]*entities.SuspenseItem{}}
+	service.SetSuspense(held)
+	ctx := context.Background()
+	owner := uuid.New()
+	namesake := uuid.New()
+	repo.names["Ada Obi"] = namesake
+
+	instructions, err := service.GetFundingInstructions(ctx, owner, "USD")
+	require.NoError(t, err)
+	typo := []byte(instructions.Reference)
+	if typo[5] == 'A' {
+		typo[5] = 'B'
+	} else {
+		typo[5] = 'A'
+	}
+
+	credit, err := service.ReceiveCredit(ctx, &entities.BankCreditWebhook{
+		ProviderRef:   "bank-5",
+		AccountNumber: sharedAccountNumber,
+		Currency:      "USD",
+		Amount:        decimal.NewFromInt(40),
+		Memo:          string(typo),
+		SenderName:    "MRS ADA OBI",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.BankCreditInvalidReference, *credit.UnmatchedReason)
+
+	suggestions, err := service.SuspenseSuggestions(ctx, held.items[credit.ID.String()])
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+	assert.Equal(t, owner, suggestions[0].UserID)
+	assert.Equal(t, entities.SuspenseSuggestionSimilarReference, suggestions[0].Reason)
+	assert.Equal(t, namesake, suggestions[1].UserID)
+	assert.Equal(t, entities.SuspenseSuggestionSenderName, suggestions[1].Reason)
+}
+
+func TestFailedCreditReturnsToTheQueue(t *testing.T) {
+	service, repo, crediter := newService()
+	ctx := context.Background()
+	instructions, err := service.GetFundingInstructions(ctx, uuid.New(), "USD")
+	require.NoError(t, err)
+
+	crediter.fail = errors.New("ledger unavailable")
+	_, err = service.ReceiveCredit(ctx, notice("bank-4", instructions.Reference))
+	require.Error(t, err)
+	for _, credit := range repo.credits {
+		assert.Equal(t, entities.BankCreditStatusUnmatched, credit.Status)
+		assert.Nil(t, credit.UserID)
+	}
+
+	crediter.fail = nil
+	credit, err := service.ReceiveCredit(ctx, notice("bank-4", instructions.Reference))
+	require.NoError(t, err)
+	assert.Equal(t, entities.BankCreditStatusCredited, credit.Status)
+}
@@ -0,0 +1,262 @@
+package developer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/apikey"
+	"github.com/stack-service/stack_service/internal/domain/services/developer"
+)
+
+type fakeTenants struct {
+	byUser map[uuid.UUID]*entities.DeveloperTenant
+}
+
+func (f *fakeTenants) CreateTenant(_ context.Context, tenant *entities.DeveloperTenant) error {
+	if _, ok := f.byUser[tenant.UserID]; ok {
+		return entities.ErrDeveloperTenantExists
+	}
+	f.byUser[tenant.UserID] = tenant
+	return nil
+}
+
+func (f *fakeTenants) GetTenantByUser(_ context.Context, userID uuid.UUID) (*entities.DeveloperTenant, error) {
+	tenant, ok := f.byUser[userID]
+	if !ok {
+		return nil, entities.ErrDeveloperTenantNotFound
+	}
+	return tenant, nil
+}
+
+type fakeKeys struct {
+	keys    []*apikey.APIKey
+	revoked []uuid.UUID
+}
+
+func (f *fakeKeys) CreateAPIKey(_ context.Context, req *apikey.CreateAPIKeyRequest) (*apikey.CreateAPIKeyResponse, error) {
+	key := &apikey.APIKey{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		UserID:      req.UserID,
+		Scopes:      req.Scopes,
+		IsActive:    true,
+		ExpiresAt:   req.ExpiresAt,
+		TenantID:    req.TenantID,
+		Environment: req.Environment,
+	}
+	f.keys = append(f.keys, key)
+	return &apikey.CreateAPIKeyResponse{APIKey: key, Key: "sk_test_secret"}, nil
+}
+
+func (f *fakeKeys) ListTenantAPIKeys(_ context.Context, tenantID uuid.UUID) ([]*apikey.APIKey, error) {
+	var keys []*apikey.APIKey
+	for _, key := range f.keys {
+		if key.TenantID != nil && *key.TenantID == tenantID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeKeys) RevokeAPIKey(_ context.Context, keyID uuid.UUID, _ *uuid.UUID) error {
+	f.revoked = append(f.revoked, keyID)
+	for _, key := range f.keys {
+		if key.ID == keyID {
+			key.IsActive = false
+		}
+	}
+	return nil
+}
+
+type fakeUsage struct {
+	filter entities.APIUsageFilter
+}
+
+func (f *fakeUsage) Report(_ context.Context, filter entities.APIUsageFilter) (*entities.APIUsageReport, error) {
+	f.filter = filter
+	return &entities.APIUsageReport{GroupBy: filter.GroupBy}, nil
+}
+
+type fakeWebhooks struct {
+	endpoints map[uuid.UUID]*entities.WebhookEndpoint
+	tested    []uuid.UUID
+}
+
+func (f *fakeWebhooks) CreateTenantEndpoint(_ context.Context, tenantID uuid.UUID, req *entities.CreateWebhookEndpointRequest, createdBy *uuid.UUID) (*entities.WebhookEndpointWithSecret, error) {
+	endpoint := &entities.WebhookEndpoint{ID: uuid.New(), URL: req.URL, EventTypes: req.EventTypes, TenantID: &tenantID, CreatedBy: createdBy}
+	f.endpoints[endpoint.ID] = endpoint
+	return &entities.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: "whsec_test"}, nil
+}
+
+func (f *fakeWebhooks) ListTenantEndpoints(_ context.Context, tenantID uuid.UUID) ([]*entities.WebhookEndpoint, error) {
+	var endpoints []*entities.WebhookEndpoint
+	for _, endpoint := range f.endpoints {
+		if endpoint.TenantID != nil && *endpoint.TenantID == tenantID {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+func (f *fakeWebhooks) GetEndpoint(_ context.Context, id uuid.UUID) (*entities.WebhookEndpoint, error) {
+	endpoint, ok := f.endpoints[id]
+	if !ok {
+		return nil, entities.ErrWebhookEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+func (f *fakeWebhooks) DeleteEndpoint(_ context.Context, id uuid.UUID) error {
+	delete(f.endpoints, id)
+	return nil
+}
+
+func (f *fakeWebhooks) SendTestEvent(_ context.Context, endpointID uuid.UUID) (*entities.WebhookDelivery, error) {
+	f.tested = append(f.tested, endpointID)
+	return &entities.WebhookDelivery{ID: uuid.New(), EndpointID: endpointID, EventType: entities.WebhookEventPing}, nil
+}
+
+type fixture struct {
+	service  *developer.Service
+	keys     *fakeKeys
+	usage    *fakeUsage
+	webhooks *fakeWebhooks
+}
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func newFixture(config developer.Config) *fixture {
+	f := &fixture{
+		keys:     &fakeKeys{},
+		usage:    &fakeUsage{},
+		webhooks: &fakeWebhooks{endpoints: map[uuid.UUID]*entities.WebhookEndpoint{}},
+	}
+	f.service = developer.NewService(&fakeTenants{byUser: map[uuid.UUID]*entities.DeveloperTenant{}},
+		f.keys, f.usage, f.webhooks, config, zap.NewNop())
+	f.service.SetClock(func() time.Time { return now })
+	return f
+}
+
+func (f *fixture) join(t *testing.T) (uuid.UUID, *entities.DeveloperTenant) {
+	userID := uuid.New()
+	tenant, err := f.service.CreateTenant(context.Background(), userID, &entities.CreateDeveloperTenantRequest{Name: " Acme Robo "})
+	require.NoError(t, err)
+	return userID, tenant
+}
+
+func TestIssueKeyGivesSandboxKeyWithSelfServeScopes(t *testing.T) {
+	f := newFixture(developer.DefaultConfig())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := f.service.IssueKey(ctx, userID, &entities.IssueDeveloperKeyRequest{Name: "ci", Scopes: []string{"orders:read"}})
+	assert.ErrorIs(t, err, entities.ErrDeveloperTenantNotFound, "a tenant comes first")
+
+	tenant, err := f.service.CreateTenant(ctx, userID, &entities.CreateDeveloperTenantRequest{Name: " Acme Robo "})
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Robo", tenant.Name)
+	assert.Equal(t, entities.DeveloperEnvironmentSandbox, tenant.Environment)
+	_, err = f.service.CreateTenant(ctx, userID, &entities.CreateDeveloperTenantRequest{Name: "Again"})
+	assert.ErrorIs(t, err, entities.ErrDeveloperTenantExists)
+
+	_, err = f.service.IssueKey(ctx, userID, &entities.IssueDeveloperKeyRequest{Name: "ci", Scopes: []string{"*"}})
+	assert.ErrorIs(t, err, developer.ErrScopeNotAllowed)
+	_, err = f.service.IssueKey(ctx, userID, &entities.IssueDeveloperKeyRequest{Name: "ci", Scopes: []string{"ledger:read"}})
+	assert.ErrorIs(t, err, developer.ErrScopeNotAllowed)
+	past := now.Add(-time.Hour)
+	_, err = f.service.IssueKey(ctx, userID, &entities.IssueDeveloperKeyRequest{Name: "ci", Scopes: []string{"orders:read"}, ExpiresAt: &past})
+	assert.ErrorIs(t, err, developer.ErrInvalidKeyRequest)
+
+	issued, err := f.service.IssueKey(ctx, userID, &entities.IssueDeveloperKeyRequest{
+		Name:   "ci",
+		Scopes: []string{"orders:read", "portfolio:read", "orders:read"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, apikey.EnvironmentSandbox, issued.APIKey.Environment)
+	assert.Equal(t, tenant.ID, *issued.APIKey.TenantID)
+	assert.Equal(t, userID, *issued.APIKey.UserID)
+	assert.Equal(t, []string{"orders:read", "portfolio:read"}, issued.APIKey.Scopes)
+}
+
+func TestIssueKeyEnforcesActiveKeyLimit(t *testing.T) {
+	f := newFixture(developer.Config{MaxActiveKeys: 2})
+	ctx := context.Background()
+	userID, _ := f.join(t)
+	req := &entities.IssueDeveloperKeyRequest{Name: "ci", Scopes: []string{"orders:read"}}
+
+	first, err := f.service.IssueKey(ctx, userID, req)
+	require.NoError(t, err)
+	_, err = f.service.IssueKey(ctx, userID, req)
+	require.NoError(t, err)
+	_, err = f.service.IssueKey(ctx, userID, req)
+	assert.ErrorIs(t, err, developer.ErrKeyLimitReached)
+
+	require.NoError(t, f.service.RevokeKey(ctx, userID, first.APIKey.ID))
+	_, err = f.service.IssueKey(ctx, userID, req)
+	assert.NoError(t, err, "revoked keys do not count")
+}
+
+func TestKeysAreScopedToTheirTenant(t *testing.T) {
+	f := newFixture(developer.DefaultConfig())
+	ctx := context.Background()
+	owner, _ := f.join(t)
+	other, _ := f.join(t)
+
+	issued, err := f.service.IssueKey(ctx, owner, &entities.IssueDeveloperKeyRequest{Name: "ci", Scopes: []string{"orders:read"}})
+	require.NoError(t, err)
+	keyID := issued.APIKey.ID
+
+	assert.ErrorIs(t, f.service.RevokeKey(ctx, other, keyID), developer.ErrKeyNotFound)
+	_, err = f.service.KeyUsage(ctx, other, keyID, time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, developer.ErrKeyNotFound)
+	assert.Empty(t, f.keys.revoked)
+
+	report, err := f.service.KeyUsage(ctx, owner, keyID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, entities.APIUsageByEndpoint, report.GroupBy)
+	require.NotNil(t, f.usage.filter.APIKeyID)
+	assert.Equal(t, keyID, *f.usage.filter.APIKeyID)
+	assert.Nil(t, f.usage.filter.UserID)
+}
+
+func TestWebhooksAreScopedToTheirTenant(t *testing.T) {
+	f := newFixture(developer.Config{MaxWebhookEndpoints: 1})
+	ctx := context.Background()
+	owner, tenant := f.join(t)
+	other, _ := f.join(t)
+	req := &entities.CreateWebhookEndpointRequest{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []entities.WebhookEventType{entities.WebhookEventOrderFilled},
+	}
+
+	registered, err := f.service.RegisterWebhook(ctx, owner, req)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.ID, *registered.TenantID)
+	_, err = f.service.RegisterWebhook(ctx, owner, req)
+	assert.ErrorIs(t, err, developer.ErrWebhookLimitReached)
+
+	_, err = f.service.TestWebhook(ctx, other, registered.ID)
+	assert.ErrorIs(t, err, entities.ErrWebhookEndpointNotFound)
+	assert.ErrorIs(t, f.service.DeleteWebhook(ctx, other, registered.ID), entities.ErrWebhookEndpointNotFound)
+
+	partner := &entities.WebhookEndpoint{ID: uuid.New(), URL: "https://live.example.com/hooks"}
+	f.webhooks.endpoints[partner.ID] = partner
+	assert.ErrorIs(t, f.service.DeleteWebhook(ctx, owner, partner.ID), entities.ErrWebhookEndpointNotFound,
+		"endpoints registered by us are not the tenant's")
+
+	delivery, err := f.service.TestWebhook(ctx, owner, registered.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WebhookEventPing, delivery.EventType)
+	require.NoError(t, f.service.DeleteWebhook(ctx, owner, registered.ID))
+
+	listed, err := f.service.ListWebhooks(ctx, owner)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
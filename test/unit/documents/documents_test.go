@@ -0,0 +1,360 @@
+package documents_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/documents"
+	"github.com/stack-service/stack_service/pkg/clock/clocktest"
+)
+
+type memoryRepo struct {
+	documents []*entities.Document
+	receipts  map[[2]uuid.UUID]*entities.DocumentReceipt
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{receipts: map[[2]uuid.UUID]*entities.DocumentReceipt{}}
+}
+
+func (r *memoryRepo) Create(ctx context.Context, d *entities.Document) error {
+	for _, existing := range r.documents {
+		if d.OrderID != nil && existing.OrderID != nil && *existing.OrderID == *d.OrderID &&
+			existing.Category == entities.DocumentCategoryTradeConfirmation {
+			return entities.ErrDocumentExists
+		}
+	}
+	r.documents = append(r.documents, d)
+	return nil
+}
+
+func (r *memoryRepo) visible(userID uuid.UUID) []*entities.Document {
+	var out []*entities.Document
+	for _, d := range r.documents {
+		if d.UserID != nil && *d.UserID != userID {
+			continue
+		}
+		copied := *d
+		if receipt, ok := r.receipts[[2]uuid.UUID{d.ID, userID}]; ok {
+			copied.ReadAt = &receipt.ReadAt
+		}
+		out = append(out, &copied)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].PublishedAt.After(out[j].PublishedAt) })
+	return out
+}
+
+func (r *memoryRepo) List(ctx context.Context, userID uuid.UUID, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	out := []*entities.Document{}
+	for _, d := range r.visible(userID) {
+		if filter.Category == "" || d.Category == filter.Category {
+			d.Body = ""
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) Get(ctx context.Context, userID, id uuid.UUID) (*entities.Document, error) {
+	for _, d := range r.visible(userID) {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, entities.ErrDocumentNotFound
+}
+
+func (r *memoryRepo) ListUnreadMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.Document, error) {
+	out := []*entities.Document{}
+	for _, d := range r.visible(userID) {
+		if d.Mandatory && d.ReadAt == nil {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) RecordReceipt(ctx context.Context, receipt *entities.DocumentReceipt) (*entities.DocumentReceipt, error) {
+	key := [2]uuid.UUID{receipt.DocumentID, receipt.UserID}
+	if existing, ok := r.receipts[key]; ok {
+		return existing, nil
+	}
+	r.receipts[key] = receipt
+	return receipt, nil
+}
+
+func (r *memoryRepo) ListBroadcast(ctx context.Context, filter entities.DocumentFilter) ([]*entities.Document, error) {
+	out := []*entities.Document{}
+	for _, d := range r.documents {
+		if d.UserID == nil {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) ListReceipts(ctx context.Context, documentID uuid.UUID, limit, offset int) ([]*entities.DocumentReceipt, error) {
+	out := []*entities.DocumentReceipt{}
+	for key, receipt := range r.receipts {
+		if key[0] == documentID {
+			out = append(out, receipt)
+		}
+	}
+	return out, nil
+}
+
+type recordingNotifier struct {
+	sent []*entities.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+type fixedAgreements []*entities.ConsentStatus
+
+func (a fixedAgreements) Status(ctx context.Context, userID uuid.UUID) ([]*entities.ConsentStatus, error) {
+	return a, nil
+}
+
+var start = time.Date(2026, 3, 2, 15, 30, 0, 0, time.UTC)
+
+func newService(repo *memoryRepo) (*documents.Service, *clocktest.Fake) {
+	clock := clocktest.NewFake(start)
+	svc := documents.NewService(repo, zap.NewNop())
+	svc.SetClock(clock.Now)
+	return svc, clock
+}
+
+func filledOrder(userID uuid.UUID) *entities.Order {
+	limit := decimal.RequireFromString("101.5")
+	return &entities.Order{
+		ID:          uuid.New(),
+		UserID:      userID,
+		BasketID:    uuid.New(),
+		Side:        entities.OrderSideBuy,
+		Amount:      decimal.RequireFromString("250"),
+		Type:        entities.OrderTypeLimit,
+		LimitPrice:  &limit,
+		TimeInForce: entities.TimeInForceDay,
+	}
+}
+
+func TestGenerateTradeConfirmationOncePerOrder(t *testing.T) {
+	repo := newMemoryRepo()
+	svc, _ := newService(repo)
+	userID := uuid.New()
+	order := filledOrder(userID)
+	fills := []entities.BrokerageFill{
+		{Symbol: "VTI", Quantity: "1.5", Price: "100"},
+		{Symbol: "BND", Quantity: "1", Price: "100.25"},
+	}
+
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), order, fills, entities.ExecutionFees{}))
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), order, fills, entities.ExecutionFees{}))
+	require.Len(t, repo.documents, 1)
+
+	doc, err := svc.Get(context.Background(), userID, repo.documents[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DocumentCategoryTradeConfirmation, doc.Category)
+	assert.Equal(t, order.ID, *doc.OrderID)
+	assert.Contains(t, doc.Body, order.ID.String())
+	assert.Contains(t, doc.Body, "limit @ 101.50")
+	assert.Contains(t, doc.Body, "DAY")
+	assert.Contains(t, doc.Body, "250.25")
+
+	_, err = svc.Get(context.Background(), uuid.New(), doc.ID)
+	assert.ErrorIs(t, err, entities.ErrDocumentNotFound)
+}
+
+func TestTradeConfirmationCarriesRequiredContentAndIsSent(t *testing.T) {
+	repo := newMemoryRepo()
+	svc, _ := newService(repo)
+	notifier := &recordingNotifier{}
+	svc.SetNotifier(notifier)
+	order := filledOrder(uuid.New())
+	order.Side = entities.OrderSideSell
+	fills := []entities.BrokerageFill{{Symbol: "VTI", Quantity: "2.5", Price: "100"}}
+	fees := entities.ExecutionFees{Commission: decimal.RequireFromString("1"), Regulatory: decimal.RequireFromString("0.02")}
+
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), order, fills, fees))
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), order, fills, fees))
+	require.Len(t, repo.documents, 1)
+	doc := repo.documents[0]
+
+	assert.Contains(t, doc.Body, start.Format(time.RFC1123))
+	assert.Contains(t, doc.Body, "Capacity:")
+	assert.Contains(t, doc.Body, documents.Capacity)
+	assert.Regexp(t, `Commission:\s+\$1\.00`, doc.Body)
+	assert.Regexp(t, `Regulatory fees:\s+\$0\.02`, doc.Body)
+	assert.Regexp(t, `Net proceeds:\s+\$248\.98`, doc.Body)
+	assert.Contains(t, doc.Body, "DISCLOSURES")
+
+	require.Len(t, notifier.sent, 2, "a redelivered fill sends nothing")
+	email, push := notifier.sent[0], notifier.sent[1]
+	assert.Equal(t, entities.ChannelEmail, email.Channel)
+	assert.Equal(t, entities.PriorityCritical, email.Priority, "the email goes out whatever the user's settings")
+	assert.Equal(t, doc.Body, email.Message)
+	assert.Equal(t, entities.ChannelPush, push.Channel)
+	assert.Contains(t, push.Message, "Sold $250.00")
+	assert.Contains(t, push.Message, "fees $1.02")
+	for _, notification := range notifier.sent {
+		assert.Equal(t, order.UserID, notification.UserID)
+		assert.Equal(t, entities.NotificationTypeTrade, notification.Type)
+		assert.Equal(t, doc.ID.String(), notification.Data["document_id"])
+	}
+}
+
+func TestPaperOrdersGetNoConfirmation(t *testing.T) {
+	repo := newMemoryRepo()
+	svc, _ := newService(repo)
+	order := filledOrder(uuid.New())
+	order.Paper = true
+
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), order, nil, entities.ExecutionFees{}))
+	assert.Empty(t, repo.documents)
+}
+
+func TestRenderTradeConfirmationKeepsUnparsableFillsOutOfTotal(t *testing.T) {
+	body := documents.RenderTradeConfirmation(filledOrder(uuid.New()), []entities.BrokerageFill{
+		{Symbol: "VTI", Quantity: "2", Price: "10"},
+		{Symbol: "XYZ", Quantity: "n/a", Price: "5"},
+	}, entities.ExecutionFees{}, start)
+
+	assert.Contains(t, body, "XYZ")
+	assert.Regexp(t, `Total\s+20\.00`, body)
+}
+
+func TestMandatoryDocumentPendingUntilRead(t *testing.T) {
+	repo := newMemoryRepo()
+	svc, clock := newService(repo)
+	userID := uuid.New()
+
+	doc, err := svc.Publish(context.Background(), &entities.PublishDocumentRequest{
+		Category:  entities.DocumentCategoryFeeDisclosure,
+		Title:     "2026 fee schedule",
+		URL:       "https://example.com/fees.pdf",
+		Mandatory: true,
+	}, uuid.New())
+	require.NoError(t, err)
+
+	pending, err := svc.Pending(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, doc.ID, pending[0].ID)
+
+	first, err := svc.MarkRead(context.Background(), userID, doc.ID, "203.0.113.7", "ios/5.2")
+	require.NoError(t, err)
+	clock.Advance(time.Hour)
+	again, err := svc.MarkRead(context.Background(), userID, doc.ID, "198.51.100.1", "web")
+	require.NoError(t, err)
+	assert.Equal(t, first.ReadAt, again.ReadAt)
+	assert.Equal(t, "203.0.113.7", again.IPAddress)
+
+	pending, err = svc.Pending(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestPublishRejectsGeneratedCategories(t *testing.T) {
+	svc, _ := newService(newMemoryRepo())
+	for _, category := range []entities.DocumentCategory{entities.DocumentCategoryTradeConfirmation, entities.DocumentCategoryAgreement} {
+		_, err := svc.Publish(context.Background(), &entities.PublishDocumentRequest{
+			Category: category,
+			Title:    "Nope",
+			URL:      "https://example.com",
+		}, uuid.New())
+		assert.ErrorIs(t, err, entities.ErrInvalidDocument, category)
+	}
+}
+
+func TestListIncludesAgreements(t *testing.T) {
+	repo := newMemoryRepo()
+	svc, _ := newService(repo)
+	userID := uuid.New()
+
+	accepted := &entities.AgreementVersion{ID: uuid.New(), Title: "Terms of Service", Version: "3", DocumentURL: "https://example.com/tos", Mandatory: true}
+	outstanding := &entities.AgreementVersion{ID: uuid.New(), Title: "Brokerage Agreement", Version: "2", DocumentURL: "https://example.com/ba", Mandatory: true}
+	svc.SetAgreementSource(fixedAgreements{
+		{Current: accepted, Accepted: &entities.UserConsent{AgreementVersionID: accepted.ID, AcceptedAt: start}},
+		{Current: outstanding, RequiresAcceptance: true},
+	})
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), filledOrder(userID), nil, entities.ExecutionFees{}))
+
+	list, err := svc.List(context.Background(), userID, entities.DocumentFilter{})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, accepted.ID, list[0].ID)
+	require.NotNil(t, list[0].ReadAt)
+	assert.Nil(t, list[1].ReadAt)
+	assert.Equal(t, entities.DocumentCategoryTradeConfirmation, list[2].Category)
+	assert.Empty(t, list[2].Body)
+
+	later, err := svc.List(context.Background(), userID, entities.DocumentFilter{Offset: 50})
+	require.NoError(t, err)
+	for _, doc := range later {
+		assert.NotEqual(t, entities.DocumentCategoryAgreement, doc.Category)
+	}
+
+	confirmations, err := svc.List(context.Background(), userID, entities.DocumentFilter{Category: entities.DocumentCategoryTradeConfirmation})
+	require.NoError(t, err)
+	assert.Len(t, confirmations, 1)
+
+	pending, err := svc.Pending(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, outstanding.ID, pending[0].ID)
+
+	_, err = svc.List(context.Background(), userID, entities.DocumentFilter{Category: "memo"})
+	assert.ErrorIs(t, err, entities.ErrInvalidDocument)
+}
+
+// regions places users by ID; everyone else is in the home region
+type regions map[uuid.UUID]entities.ResidencyRegion
+
+func (r regions) Region(ctx context.Context, userID uuid.UUID) (entities.ResidencyRegion, error) {
+	if region, ok := r[userID]; ok {
+		return region, nil
+	}
+	return entities.ResidencyRegionUS, nil
+}
+
+func (r regions) HomeRegion() entities.ResidencyRegion {
+	return entities.ResidencyRegionUS
+}
+
+func TestDocumentsKeepToUserRegion(t *testing.T) {
+	repo := newMemoryRepo()
+	svc, _ := newService(repo)
+	euUser, usUser := uuid.New(), uuid.New()
+	svc.SetResidency(regions{euUser: entities.ResidencyRegionEU})
+	publish := func(userID *uuid.UUID) (*entities.Document, error) {
+		return svc.Publish(context.Background(), &entities.PublishDocumentRequest{
+			UserID: userID, Category: entities.DocumentCategoryDisclosure, Title: "Statement", URL: "https://docs.example/s.pdf",
+		}, uuid.New())
+	}
+
+	_, err := publish(&euUser)
+	assert.ErrorIs(t, err, entities.ErrOutOfRegion)
+	doc, err := publish(&usUser)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencyRegionUS, doc.StorageRegion)
+	_, err = publish(nil)
+	require.NoError(t, err)
+	require.Len(t, repo.documents, 2)
+
+	// Confirmations are filed out of region rather than lost
+	require.NoError(t, svc.GenerateTradeConfirmation(context.Background(), filledOrder(euUser), nil, entities.ExecutionFees{}))
+	require.Len(t, repo.documents, 3)
+	assert.Equal(t, entities.ResidencyRegionUS, repo.documents[2].StorageRegion)
+}
@@ -0,0 +1,286 @@
+package edd_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/edd"
+)
+
+type fakeRepo struct {
+	accounts    map[uuid.UUID]*entities.AccountKYCTier
+	submissions map[uuid.UUID]*entities.EDDSubmission
+	applied     map[uuid.UUID][]entities.TierLimit
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		accounts:    map[uuid.UUID]*entities.AccountKYCTier{},
+		submissions: map[uuid.UUID]*entities.EDDSubmission{},
+		applied:     map[uuid.UUID][]entities.TierLimit{},
+	}
+}
+
+func (f *fakeRepo) GetAccount(ctx context.Context, userID uuid.UUID) (*entities.AccountKYCTier, error) {
+	account, ok := f.accounts[userID]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return account, nil
+}
+
+func (f *fakeRepo) ApplyTier(ctx context.Context, userID uuid.UUID, tier entities.KYCTier, limits []entities.TierLimit) error {
+	f.accounts[userID].Tier = tier
+	f.applied[userID] = limits
+	return nil
+}
+
+func (f *fakeRepo) Create(ctx context.Context, submission *entities.EDDSubmission) (*entities.EDDSubmission, bool, error) {
+	for _, existing := range f.submissions {
+		if existing.UserID == submission.UserID && existing.Status == entities.EDDPendingReview {
+			return existing, false, nil
+		}
+	}
+	stored := *submission
+	f.submissions[stored.ID] = &stored
+	return &stored, true, nil
+}
+
+func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.EDDSubmission, error) {
+	submission, ok := f.submissions[id]
+	if !ok {
+		return nil, entities.ErrEDDSubmissionNotFound
+	}
+	return submission, nil
+}
+
+func (f *fakeRepo) GetLatestByUser(ctx context.Context, userID uuid.UUID) (*entities.EDDSubmission, error) {
+	var latest *entities.EDDSubmission
+	for _, submission := range f.submissions {
+		if submission.UserID == userID && (latest == nil || submission.CreatedAt.After(latest.CreatedAt)) {
+			latest = submission
+		}
+	}
+	if latest == nil {
+		return nil, entities.ErrEDDSubmissionNotFound
+	}
+	return latest, nil
+}
+
+func (f *fakeRepo) List(ctx context.Context, status entities.EDDStatus, limit, offset int) ([]*entities.EDDSubmission, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) Update(ctx context.Context, submission *entities.EDDSubmission) error {
+	f.submissions[submission.ID] = submission
+	return nil
+}
+
+type fakeCases struct {
+	opened   []entities.AdminCaseType
+	resolved []uuid.UUID
+	openErr  error
+}
+
+func (f *fakeCases) Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	f.opened = append(f.opened, caseType)
+	return &entities.AdminCase{ID: uuid.New(), UserID: userID, CaseType: caseType}, nil
+}
+
+func (f *fakeCases) Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error) {
+	f.resolved = append(f.resolved, id)
+	return &entities.AdminCase{ID: id, Status: req.Status}, nil
+}
+
+type fakeProvider struct {
+	refs []string
+	err  error
+}
+
+func (f *fakeProvider) SubmitEnhancedDueDiligence(ctx context.Context, providerRef string, documents []entities.KYCDocumentUpload) error {
+	f.refs = append(f.refs, providerRef)
+	return f.err
+}
+
+func rolledOut() edd.Config {
+	config := edd.DefaultConfig()
+	config.Enabled = true
+	config.RolloutPercent = 100
+	return config
+}
+
+func verifiedUser(repo *fakeRepo) uuid.UUID {
+	userID := uuid.New()
+	ref := "applicant-1"
+	repo.accounts[userID] = &entities.AccountKYCTier{
+		UserID:         userID,
+		KYCStatus:      entities.KYCStatusApproved,
+		KYCProviderRef: &ref,
+		Tier:           entities.KYCTierStandard,
+	}
+	return userID
+}
+
+func proofOfFunds() *entities.SubmitEDDRequest {
+	return &entities.SubmitEDDRequest{
+		SourceOfFunds: "Salary and sale of a property",
+		Documents: []entities.KYCDocumentUpload{
+			{Type: entities.EDDDocumentProofOfFunds, FileURL: "https://files.example.com/pof.pdf", ContentType: "application/pdf"},
+			{Type: entities.EDDDocumentBankStatement, FileURL: "https://files.example.com/statement.pdf", ContentType: "application/pdf"},
+		},
+	}
+}
+
+func TestSubmit_RoutesToCaseQueueAndProvider(t *testing.T) {
+	repo, cases, provider := newFakeRepo(), &fakeCases{}, &fakeProvider{}
+	svc := edd.NewService(repo, cases, rolledOut(), zap.NewNop())
+	svc.SetProvider(provider)
+	userID := verifiedUser(repo)
+
+	submission, err := svc.Submit(context.Background(), userID, proofOfFunds())
+	require.NoError(t, err)
+	assert.Equal(t, entities.EDDPendingReview, submission.Status)
+	require.NotNil(t, submission.CaseID)
+	require.NotNil(t, submission.ProviderRef)
+	assert.Equal(t, "applicant-1", *submission.ProviderRef)
+	assert.Equal(t, []entities.AdminCaseType{entities.AdminCaseEnhancedDueDiligence}, cases.opened)
+
+	again, err := svc.Submit(context.Background(), userID, proofOfFunds())
+	require.NoError(t, err)
+	assert.Equal(t, submission.ID, again.ID, "a pending submission is returned")
+	assert.Len(t, provider.refs, 1, "documents are forwarded once")
+	assert.Len(t, cases.opened, 1)
+}
+
+func TestSubmit_ProviderFailureStillQueuesCase(t *testing.T) {
+	repo, cases := newFakeRepo(), &fakeCases{}
+	svc := edd.NewService(repo, cases, rolledOut(), zap.NewNop())
+	svc.SetProvider(&fakeProvider{err: entities.ErrEDDProviderUnsupported})
+	userID := verifiedUser(repo)
+
+	submission, err := svc.Submit(context.Background(), userID, proofOfFunds())
+	require.NoError(t, err)
+	assert.Nil(t, submission.ProviderRef)
+	assert.NotNil(t, submission.CaseID)
+}
+
+func TestSubmit_CaseOpenedOnRetry(t *testing.T) {
+	repo, cases := newFakeRepo(), &fakeCases{openErr: errors.New("database unavailable")}
+	svc := edd.NewService(repo, cases, rolledOut(), zap.NewNop())
+	userID := verifiedUser(repo)
+
+	_, err := svc.Submit(context.Background(), userID, proofOfFunds())
+	require.Error(t, err)
+
+	cases.openErr = nil
+	submission, err := svc.Submit(context.Background(), userID, proofOfFunds())
+	require.NoError(t, err)
+	assert.NotNil(t, submission.CaseID)
+}
+
+func TestSubmit_Validation(t *testing.T) {
+	repo := newFakeRepo()
+	svc := edd.NewService(repo, &fakeCases{}, rolledOut(), zap.NewNop())
+	ctx := context.Background()
+	userID := verifiedUser(repo)
+
+	req := proofOfFunds()
+	req.Documents = req.Documents[1:]
+	_, err := svc.Submit(ctx, userID, req)
+	assert.ErrorIs(t, err, entities.ErrEDDProofOfFunds)
+
+	req = proofOfFunds()
+	req.Documents[1].Type = "passport"
+	_, err = svc.Submit(ctx, userID, req)
+	assert.ErrorIs(t, err, entities.ErrInvalidEDDDocument)
+
+	unverified := verifiedUser(repo)
+	repo.accounts[unverified].KYCStatus = entities.KYCStatusProcessing
+	_, err = svc.Submit(ctx, unverified, proofOfFunds())
+	assert.ErrorIs(t, err, entities.ErrEDDRequiresKYC)
+
+	enhanced := verifiedUser(repo)
+	repo.accounts[enhanced].Tier = entities.KYCTierEnhanced
+	_, err = svc.Submit(ctx, enhanced, proofOfFunds())
+	assert.ErrorIs(t, err, entities.ErrEDDAlreadyEnhanced)
+}
+
+func TestApprove_RaisesTierAndLimits(t *testing.T) {
+	repo, cases := newFakeRepo(), &fakeCases{}
+	svc := edd.NewService(repo, cases, rolledOut(), zap.NewNop())
+	ctx := context.Background()
+	userID := verifiedUser(repo)
+
+	submission, err := svc.Submit(ctx, userID, proofOfFunds())
+	require.NoError(t, err)
+
+	adminID := uuid.New()
+	approved, err := svc.Approve(ctx, submission.ID, adminID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.EDDApproved, approved.Status)
+	assert.Equal(t, &adminID, approved.ReviewedBy)
+	assert.Equal(t, entities.KYCTierEnhanced, repo.accounts[userID].Tier)
+	require.Len(t, repo.applied[userID], 3)
+	assert.Equal(t, entities.LimitTypeDeposit, repo.applied[userID][0].LimitType)
+	assert.Equal(t, "50000", repo.applied[userID][0].MaxAmount.String())
+	assert.Equal(t, []uuid.UUID{*submission.CaseID}, cases.resolved)
+
+	_, err = svc.Reject(ctx, submission.ID, adminID, nil)
+	assert.ErrorIs(t, err, entities.ErrEDDNotPending)
+}
+
+func TestReject_KeepsTier(t *testing.T) {
+	repo := newFakeRepo()
+	svc := edd.NewService(repo, &fakeCases{}, rolledOut(), zap.NewNop())
+	ctx := context.Background()
+	userID := verifiedUser(repo)
+
+	submission, err := svc.Submit(ctx, userID, proofOfFunds())
+	require.NoError(t, err)
+
+	rejected, err := svc.Reject(ctx, submission.ID, uuid.New(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.EDDRejected, rejected.Status)
+	assert.NotNil(t, rejected.RejectionReason)
+	assert.Equal(t, entities.KYCTierStandard, repo.accounts[userID].Tier)
+	assert.Empty(t, repo.applied)
+
+	overview, err := svc.Overview(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, overview.Available, "rejected users may apply again")
+	assert.Equal(t, rejected.ID, overview.Submission.ID)
+}
+
+func TestAvailable_RollsOutToStableShareOfUsers(t *testing.T) {
+	off := edd.NewService(newFakeRepo(), &fakeCases{}, edd.DefaultConfig(), zap.NewNop())
+	assert.False(t, off.Available(uuid.New()), "disabled by default")
+
+	config := rolledOut()
+	config.RolloutPercent = 30
+	svc := edd.NewService(newFakeRepo(), &fakeCases{}, config, zap.NewNop())
+
+	in := 0
+	for i := 0; i < 2000; i++ {
+		userID := uuid.New()
+		available := svc.Available(userID)
+		assert.Equal(t, available, svc.Available(userID), "a user's bucket does not change")
+		if available {
+			in++
+		}
+	}
+	assert.InDelta(t, 600, in, 120)
+
+	_, err := edd.NewService(newFakeRepo(), &fakeCases{}, edd.DefaultConfig(), zap.NewNop()).
+		Submit(context.Background(), uuid.New(), proofOfFunds())
+	assert.ErrorIs(t, err, entities.ErrEDDUnavailable)
+}
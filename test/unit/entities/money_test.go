@@ -0,0 +1,70 @@
+package entities_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+)
+
+func usd(amount string) entities.Money {
+	m, err := entities.ParseMoney(amount, entities.CurrencyUSD)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestMoney_ArithmeticIsExact(t *testing.T) {
+	total, err := usd("0.1").Add(usd("0.2"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.3 USD", total.String())
+
+	diff, err := total.Sub(usd("0.3"))
+	require.NoError(t, err)
+	assert.True(t, diff.IsZero())
+
+	assert.Equal(t, "1.23 USD", usd("1.234567").Round().String())
+	assert.Equal(t, "30 USD", usd("12").Mul(decimal.NewFromFloat(2.5)).String())
+}
+
+func TestMoney_RejectsMixedCurrencies(t *testing.T) {
+	usdc := entities.NewMoney(decimal.NewFromInt(5), entities.CurrencyUSDC)
+
+	_, err := usd("5").Add(usdc)
+	assert.ErrorIs(t, err, entities.ErrCurrencyMismatch)
+	_, err = usd("5").Sub(usdc)
+	assert.ErrorIs(t, err, entities.ErrCurrencyMismatch)
+	_, err = usd("5").Cmp(usdc)
+	assert.ErrorIs(t, err, entities.ErrCurrencyMismatch)
+
+	total, err := usd("5").Add(usdc.Convert(entities.CurrencyUSD, decimal.NewFromInt(1)))
+	require.NoError(t, err)
+	assert.Equal(t, "10 USD", total.String())
+}
+
+func TestParseMoney_Invalid(t *testing.T) {
+	_, err := entities.ParseMoney("12.x", entities.CurrencyUSD)
+	assert.ErrorIs(t, err, entities.ErrInvalidMoney)
+	_, err = entities.ParseMoney("12", "")
+	assert.ErrorIs(t, err, entities.ErrInvalidMoney)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(usd("1234567.891"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"1234567.891","currency":"USD"}`, string(data))
+
+	var fromString, fromNumber entities.Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"0.1","currency":"USDC"}`), &fromString))
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":12345678901234567.89,"currency":"USD"}`), &fromNumber))
+	assert.Equal(t, "0.1 USDC", fromString.String())
+	assert.Equal(t, "12345678901234567.89 USD", fromNumber.String(), "numbers are not decoded through float64")
+
+	var invalid entities.Money
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":true,"currency":"USD"}`), &invalid), entities.ErrInvalidMoney)
+}
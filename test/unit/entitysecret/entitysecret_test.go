@@ -0,0 +1,137 @@
+package entitysecret_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	entitysecret "github.com/stack-service/stack_service/internal/domain/services/entity_secret"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+type fakeKeySource struct {
+	pem   string
+	calls int
+}
+
+func (f *fakeKeySource) GetEntityPublicKey(ctx context.Context) (string, error) {
+	f.calls++
+	return f.pem, nil
+}
+
+func newKeyPair(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func decrypt(t *testing.T, key *rsa.PrivateKey, ciphertext string) []byte {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	require.NoError(t, err)
+	plaintext, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, raw, nil)
+	require.NoError(t, err)
+	return plaintext
+}
+
+func newSealer(t *testing.T) *crypto.FieldEncryptor {
+	t.Helper()
+	sealer, err := crypto.NewFieldEncryptor(crypto.StaticFieldKeySource{
+		Keys:          map[int][]byte{1: crypto.DeriveFieldKey("test-key")},
+		ActiveVersion: 1,
+	}, crypto.DeriveFieldKey("index"))
+	require.NoError(t, err)
+	return sealer
+}
+
+func TestGenerateSecret_IsRandom(t *testing.T) {
+	first, err := entitysecret.GenerateSecret()
+	require.NoError(t, err)
+	second, err := entitysecret.GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, first, entitysecret.SecretSize)
+	assert.NotEqual(t, first, second)
+}
+
+func TestCiphertext_RequiresConfiguredSecret(t *testing.T) {
+	_, publicKeyPEM := newKeyPair(t)
+	svc := entitysecret.NewService(zap.NewNop())
+	require.NoError(t, svc.SetPublicKey(publicKeyPEM))
+
+	_, err := svc.GenerateEntitySecretCiphertext(context.Background())
+	assert.ErrorIs(t, err, entitysecret.ErrSecretNotConfigured)
+	assert.False(t, svc.Configured())
+
+	assert.Error(t, svc.SetSecret("abcd"), "secrets must be 32 bytes")
+}
+
+func TestCiphertext_FetchesPublicKeyOnce(t *testing.T) {
+	key, publicKeyPEM := newKeyPair(t)
+	source := &fakeKeySource{pem: publicKeyPEM}
+	secret, err := entitysecret.GenerateSecret()
+	require.NoError(t, err)
+
+	svc := entitysecret.NewService(zap.NewNop())
+	svc.SetPublicKeySource(source)
+	require.NoError(t, svc.SetSecret(hex.EncodeToString(secret)))
+
+	first, err := svc.GenerateEntitySecretCiphertext(context.Background())
+	require.NoError(t, err)
+	second, err := svc.GenerateEntitySecretCiphertext(context.Background())
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second, "each request gets a fresh encryption")
+	assert.Equal(t, secret, decrypt(t, key, first))
+	assert.Equal(t, secret, decrypt(t, key, second))
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestSealedFile_RoundTrip(t *testing.T) {
+	key, publicKeyPEM := newKeyPair(t)
+	sealer := newSealer(t)
+	path := filepath.Join(t.TempDir(), "entity-secret.sealed")
+	secret, err := entitysecret.GenerateSecret()
+	require.NoError(t, err)
+
+	require.NoError(t, entitysecret.WriteSealedFile(path, secret, sealer))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), hex.EncodeToString(secret), "the file never holds the plaintext")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	assert.Error(t, entitysecret.WriteSealedFile(path, secret, sealer), "existing files are not overwritten")
+
+	svc := entitysecret.NewService(zap.NewNop())
+	require.NoError(t, svc.SetPublicKey(publicKeyPEM))
+	require.NoError(t, svc.LoadSealedFile(path, sealer))
+	ciphertext, err := svc.GenerateEntitySecretCiphertext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, secret, decrypt(t, key, ciphertext))
+}
+
+func TestSealedFile_RejectsPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entity-secret.sealed")
+	secret, err := entitysecret.GenerateSecret()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(`{"sealed_secret":"`+hex.EncodeToString(secret)+`"}`), 0o600))
+
+	_, err = entitysecret.ReadSealedFile(path, newSealer(t))
+	assert.Error(t, err)
+}
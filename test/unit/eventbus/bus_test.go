@@ -0,0 +1,111 @@
+package eventbus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/eventbus"
+)
+
+func newBus(t *testing.T) eventbus.Bus {
+	t.Helper()
+	bus, err := eventbus.New(eventbus.Config{
+		Driver:         eventbus.DriverInProcess,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	return bus
+}
+
+func TestInProcessBus_EveryGroupReceivesInOrder(t *testing.T) {
+	bus := newBus(t)
+
+	var mu sync.Mutex
+	received := map[string][]int{}
+	handler := func(group string) eventbus.Handler {
+		return func(ctx context.Context, msg *eventbus.Message) error {
+			var n int
+			require.NoError(t, msg.Decode(&n))
+			mu.Lock()
+			received[group] = append(received[group], n)
+			mu.Unlock()
+			return nil
+		}
+	}
+	require.NoError(t, bus.Subscribe("deposits", "webhooks", handler("webhooks")))
+	require.NoError(t, bus.Subscribe("deposits", "notifications", handler("notifications")))
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, bus.Publish(context.Background(), "deposits", "user-1", i))
+	}
+	require.NoError(t, bus.Close())
+
+	want := make([]int, 20)
+	for i := range want {
+		want[i] = i
+	}
+	assert.Equal(t, want, received["webhooks"])
+	assert.Equal(t, want, received["notifications"])
+}
+
+func TestInProcessBus_RetriesThenDrops(t *testing.T) {
+	bus := newBus(t)
+
+	var calls int32
+	var delivered []string
+	require.NoError(t, bus.Subscribe("topic", "group", func(ctx context.Context, msg *eventbus.Message) error {
+		var value string
+		_ = msg.Decode(&value)
+		if value == "poison" {
+			atomic.AddInt32(&calls, 1)
+			return errors.New("boom")
+		}
+		delivered = append(delivered, value)
+		return nil
+	}))
+
+	require.NoError(t, bus.Publish(context.Background(), "topic", "k", "poison"))
+	require.NoError(t, bus.Publish(context.Background(), "topic", "k", "ok"))
+	require.NoError(t, bus.Close())
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, []string{"ok"}, delivered)
+}
+
+func TestInProcessBus_RecoversHandlerPanic(t *testing.T) {
+	bus := newBus(t)
+
+	var attempts int32
+	require.NoError(t, bus.Subscribe("topic", "group", func(ctx context.Context, msg *eventbus.Message) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			panic("first attempt")
+		}
+		return nil
+	}))
+	require.NoError(t, bus.Publish(context.Background(), "topic", "k", map[string]string{"a": "b"}))
+	require.NoError(t, bus.Close())
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestBus_ClosedAndUnknownDriver(t *testing.T) {
+	bus := newBus(t)
+	require.NoError(t, bus.Close())
+	assert.ErrorIs(t, bus.Publish(context.Background(), "topic", "k", 1), eventbus.ErrClosed)
+	assert.ErrorIs(t, bus.Subscribe("topic", "g", nil), eventbus.ErrClosed)
+
+	_, err := eventbus.New(eventbus.Config{Driver: "carrier-pigeon"}, zap.NewNop())
+	assert.Error(t, err)
+
+	_, err = eventbus.New(eventbus.Config{Driver: eventbus.DriverKafka}, zap.NewNop())
+	assert.Error(t, err, "kafka driver requires a REST proxy URL")
+}
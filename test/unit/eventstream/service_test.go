@@ -0,0 +1,108 @@
+package eventstream_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/eventstream"
+	"github.com/stack-service/stack_service/internal/infrastructure/cache"
+)
+
+// fakeStream keeps entries in memory with sequential IDs
+type fakeStream struct {
+	entries map[string][]cache.StreamMessage
+	seq     int64
+}
+
+func (f *fakeStream) StreamAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	f.seq++
+	id := fmt.Sprintf("%d-0", f.seq)
+	f.entries[stream] = append(f.entries[stream], cache.StreamMessage{ID: id, Values: values})
+	return id, nil
+}
+
+func (f *fakeStream) StreamRead(ctx context.Context, stream, afterID string, count int64, block time.Duration) ([]cache.StreamMessage, error) {
+	var after int64
+	fmt.Sscanf(afterID, "%d-0", &after)
+	var out []cache.StreamMessage
+	for _, m := range f.entries[stream] {
+		var n int64
+		fmt.Sscanf(m.ID, "%d-0", &n)
+		if n > after {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStream) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return nil
+}
+
+func TestPublishAndResume(t *testing.T) {
+	stream := &fakeStream{entries: map[string][]cache.StreamMessage{}}
+	svc := eventstream.NewService(stream, eventstream.Config{}, zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, svc.PublishToUser(ctx, userID, entities.StreamEventOrderUpdated, entities.OrderProgressEvent{Status: entities.OrderStatusAccepted}))
+	require.NoError(t, svc.PublishToUser(ctx, userID, entities.StreamEventDepositUpdated, entities.DepositProgressEvent{Status: "confirmed"}))
+	require.NoError(t, svc.PublishToUser(ctx, uuid.New(), entities.StreamEventOrderUpdated, entities.OrderProgressEvent{}))
+
+	events, cursor, err := svc.Read(ctx, userID, "0-0", nil, time.Second)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, entities.StreamEventOrderUpdated, events[0].Type)
+	assert.JSONEq(t, `"accepted"`, extractField(t, events[0].Data, "status"))
+	assert.Equal(t, events[1].ID, cursor)
+
+	// Resuming from the first event only returns what came after it
+	events, _, err = svc.Read(ctx, userID, events[0].ID, nil, time.Second)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, entities.StreamEventDepositUpdated, events[0].Type)
+}
+
+func TestReadFiltersByTypeButAdvancesCursor(t *testing.T) {
+	stream := &fakeStream{entries: map[string][]cache.StreamMessage{}}
+	svc := eventstream.NewService(stream, eventstream.Config{}, zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, svc.PublishToUser(ctx, userID, entities.StreamEventOrderUpdated, map[string]string{}))
+	require.NoError(t, svc.PublishToUser(ctx, userID, entities.StreamEventOrderUpdated, map[string]string{}))
+
+	events, cursor, err := svc.Read(ctx, userID, "0-0",
+		map[entities.StreamEventType]bool{entities.StreamEventDepositUpdated: true}, time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, "2-0", cursor)
+}
+
+func TestCursorValidation(t *testing.T) {
+	svc := eventstream.NewService(&fakeStream{entries: map[string][]cache.StreamMessage{}}, eventstream.Config{}, zap.NewNop())
+	_, _, err := svc.Read(context.Background(), uuid.New(), "$", nil, time.Second)
+	assert.ErrorIs(t, err, eventstream.ErrInvalidCursor)
+
+	assert.Equal(t, "1760000000000-0", eventstream.StartCursor(time.UnixMilli(1760000000000)))
+	assert.True(t, eventstream.ValidCursor("1760000000000-3"))
+	assert.False(t, eventstream.ValidCursor("abc"))
+}
+
+func extractField(t *testing.T, data []byte, field string) string {
+	t.Helper()
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &m))
+	out, err := json.Marshal(m[field])
+	require.NoError(t, err)
+	return string(out)
+}
@@ -0,0 +1,327 @@
+package experiments_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/experiments"
+)
+
+type fakeEvent struct {
+	experimentID uuid.UUID
+	userID       uuid.UUID
+	variant      string
+	kind         entities.ExperimentEventKind
+	goal         string
+}
+
+type fakeRepo struct {
+	experiments []*entities.Experiment
+	assignments map[uuid.UUID]map[uuid.UUID]string // user -> experiment -> variant
+	events      []fakeEvent
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{assignments: map[uuid.UUID]map[uuid.UUID]string{}}
+}
+
+func (r *fakeRepo) Create(_ context.Context, experiment *entities.Experiment) error {
+	for _, existing := range r.experiments {
+		if existing.Key == experiment.Key {
+			return entities.ErrExperimentExists
+		}
+	}
+	stored := *experiment
+	r.experiments = append(r.experiments, &stored)
+	return nil
+}
+
+func (r *fakeRepo) Get(_ context.Context, id uuid.UUID) (*entities.Experiment, error) {
+	for _, experiment := range r.experiments {
+		if experiment.ID == id {
+			copied := *experiment
+			return &copied, nil
+		}
+	}
+	return nil, entities.ErrExperimentNotFound
+}
+
+func (r *fakeRepo) List(_ context.Context) ([]*entities.Experiment, error) {
+	list := make([]*entities.Experiment, 0, len(r.experiments))
+	for _, experiment := range r.experiments {
+		copied := *experiment
+		list = append(list, &copied)
+	}
+	return list, nil
+}
+
+func (r *fakeRepo) Conclude(_ context.Context, id uuid.UUID, winningVariant, reason string, adminID uuid.UUID, at time.Time) (*entities.Experiment, error) {
+	for _, experiment := range r.experiments {
+		if experiment.ID != id {
+			continue
+		}
+		if experiment.Status == entities.ExperimentStatusConcluded {
+			return nil, entities.ErrExperimentConcluded
+		}
+		experiment.Status = entities.ExperimentStatusConcluded
+		experiment.WinningVariant = &winningVariant
+		experiment.ConclusionReason = &reason
+		experiment.ConcludedBy = &adminID
+		experiment.ConcludedAt = &at
+		copied := *experiment
+		return &copied, nil
+	}
+	return nil, entities.ErrExperimentNotFound
+}
+
+func (r *fakeRepo) ListAssignments(_ context.Context, userID uuid.UUID) ([]*entities.ExperimentAssignment, error) {
+	var assignments []*entities.ExperimentAssignment
+	for experimentID, variant := range r.assignments[userID] {
+		assignments = append(assignments, &entities.ExperimentAssignment{ExperimentID: experimentID, UserID: userID, Variant: variant})
+	}
+	return assignments, nil
+}
+
+func (r *fakeRepo) AssignAndExpose(_ context.Context, assignment *entities.ExperimentAssignment) (string, error) {
+	if r.assignments[assignment.UserID] == nil {
+		r.assignments[assignment.UserID] = map[uuid.UUID]string{}
+	}
+	if variant, ok := r.assignments[assignment.UserID][assignment.ExperimentID]; ok {
+		return variant, nil
+	}
+	r.assignments[assignment.UserID][assignment.ExperimentID] = assignment.Variant
+	r.events = append(r.events, fakeEvent{assignment.ExperimentID, assignment.UserID, assignment.Variant, entities.ExperimentEventExposure, ""})
+	return assignment.Variant, nil
+}
+
+func (r *fakeRepo) RecordConversion(_ context.Context, userID uuid.UUID, goal string, _ time.Time) (int64, error) {
+	var recorded int64
+	for experimentID, variant := range r.assignments[userID] {
+		experiment, _ := r.Get(context.Background(), experimentID)
+		if experiment.Status != entities.ExperimentStatusRunning || r.hasEvent(experimentID, userID, entities.ExperimentEventConversion, goal) {
+			continue
+		}
+		r.events = append(r.events, fakeEvent{experimentID, userID, variant, entities.ExperimentEventConversion, goal})
+		recorded++
+	}
+	return recorded, nil
+}
+
+func (r *fakeRepo) hasEvent(experimentID, userID uuid.UUID, kind entities.ExperimentEventKind, goal string) bool {
+	for _, event := range r.events {
+		if event.experimentID == experimentID && event.userID == userID && event.kind == kind && event.goal == goal {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeRepo) Results(_ context.Context, id uuid.UUID) ([]*entities.ExperimentVariantResult, error) {
+	byVariant := map[string]*entities.ExperimentVariantResult{}
+	result := func(variant string) *entities.ExperimentVariantResult {
+		if byVariant[variant] == nil {
+			byVariant[variant] = &entities.ExperimentVariantResult{Variant: variant, Conversions: map[string]int64{}}
+		}
+		return byVariant[variant]
+	}
+	for _, experiments := range r.assignments {
+		if variant, ok := experiments[id]; ok {
+			result(variant).Assigned++
+		}
+	}
+	for _, event := range r.events {
+		if event.experimentID != id {
+			continue
+		}
+		if event.kind == entities.ExperimentEventExposure {
+			result(event.variant).Exposed++
+		} else {
+			result(event.variant).Conversions[event.goal]++
+		}
+	}
+	var results []*entities.ExperimentVariantResult
+	for _, variantResult := range byVariant {
+		results = append(results, variantResult)
+	}
+	return results, nil
+}
+
+var (
+	adminID = uuid.MustParse("99999999-9999-9999-9999-999999999999")
+	now     = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+)
+
+func newService() (*experiments.Service, *fakeRepo) {
+	repo := newFakeRepo()
+	service := experiments.NewService(repo, zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	return service, repo
+}
+
+func startOrderExperiment(t *testing.T, service *experiments.Service, walletFirstWeight int) *entities.Experiment {
+	t.Helper()
+	experiment, err := service.Create(context.Background(), adminID, &entities.CreateExperimentRequest{
+		Key:         "Onboarding-Order",
+		Description: "KYC first or wallet first",
+		Variants: []entities.ExperimentVariant{
+			{Key: "kyc_first", Weight: 50},
+			{Key: "wallet_first", Weight: walletFirstWeight},
+		},
+	})
+	require.NoError(t, err)
+	return experiment
+}
+
+func TestCreateValidatesExperiment(t *testing.T) {
+	service, _ := newService()
+	ctx := context.Background()
+
+	cases := map[string]*entities.CreateExperimentRequest{
+		"bad key":     {Key: "onboarding order", Variants: []entities.ExperimentVariant{{Key: "a", Weight: 1}, {Key: "b", Weight: 1}}},
+		"one variant": {Key: "order", Variants: []entities.ExperimentVariant{{Key: "a", Weight: 1}}},
+		"duplicate":   {Key: "order", Variants: []entities.ExperimentVariant{{Key: "a", Weight: 1}, {Key: "A", Weight: 1}}},
+		"no weight":   {Key: "order", Variants: []entities.ExperimentVariant{{Key: "a"}, {Key: "b"}}},
+	}
+	for name, req := range cases {
+		_, err := service.Create(ctx, adminID, req)
+		assert.ErrorIs(t, err, experiments.ErrInvalidExperiment, name)
+	}
+
+	experiment := startOrderExperiment(t, service, 50)
+	assert.Equal(t, "onboarding-order", experiment.Key)
+	assert.Equal(t, entities.ExperimentStatusRunning, experiment.Status)
+	assert.Equal(t, now, experiment.CreatedAt)
+
+	_, err := service.Create(ctx, adminID, &entities.CreateExperimentRequest{
+		Key: "onboarding-order", Variants: []entities.ExperimentVariant{{Key: "a", Weight: 1}, {Key: "b", Weight: 1}},
+	})
+	assert.ErrorIs(t, err, entities.ErrExperimentExists)
+}
+
+func TestAssignBucketsByWeightAndIsStable(t *testing.T) {
+	service, repo := newService()
+	ctx := context.Background()
+	startOrderExperiment(t, service, 50)
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		userID := uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i))
+		variants, err := service.Assign(ctx, userID, true)
+		require.NoError(t, err)
+		counts[variants["onboarding-order"]]++
+
+		again, err := service.Assign(ctx, userID, true)
+		require.NoError(t, err)
+		assert.Equal(t, variants, again)
+	}
+	assert.InDelta(t, 500, counts["kyc_first"], 75)
+	assert.InDelta(t, 500, counts["wallet_first"], 75)
+	assert.Len(t, repo.events, 1000, "each user is exposed once")
+
+	other, _ := newService()
+	startOrderExperiment(t, other, 0)
+	for i := 0; i < 100; i++ {
+		variants, err := other.Assign(ctx, uuid.New(), true)
+		require.NoError(t, err)
+		assert.Equal(t, "kyc_first", variants["onboarding-order"])
+	}
+}
+
+func TestAssignOnlyEnrollsUsersStillOnboarding(t *testing.T) {
+	service, repo := newService()
+	ctx := context.Background()
+	startOrderExperiment(t, service, 50)
+
+	userID := uuid.New()
+	variants, err := service.Assign(ctx, userID, false)
+	require.NoError(t, err)
+	assert.Empty(t, variants)
+	assert.Empty(t, repo.events)
+
+	enrolled, err := service.Assign(ctx, userID, true)
+	require.NoError(t, err)
+	afterOnboarding, err := service.Assign(ctx, userID, false)
+	require.NoError(t, err)
+	assert.Equal(t, enrolled, afterOnboarding, "users keep their variant once onboarded")
+}
+
+func TestConversionsAreRecordedOncePerGoal(t *testing.T) {
+	service, repo := newService()
+	ctx := context.Background()
+	experiment := startOrderExperiment(t, service, 50)
+
+	userID := uuid.New()
+	variants, err := service.Assign(ctx, userID, true)
+	require.NoError(t, err)
+	require.NoError(t, service.RecordConversion(ctx, userID, entities.ExperimentGoalOnboardingCompleted))
+	require.NoError(t, service.RecordConversion(ctx, userID, entities.ExperimentGoalOnboardingCompleted))
+
+	got, err := service.Get(ctx, experiment.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Results, 2)
+	for _, result := range got.Results {
+		if result.Variant == variants["onboarding-order"] {
+			assert.Equal(t, int64(1), result.Assigned)
+			assert.Equal(t, int64(1), result.Exposed)
+			assert.Equal(t, int64(1), result.Conversions[entities.ExperimentGoalOnboardingCompleted])
+		} else {
+			assert.Zero(t, result.Assigned)
+			assert.NotNil(t, result.Conversions)
+		}
+	}
+	assert.Len(t, repo.events, 2)
+}
+
+func TestConcludeFreezesAssignment(t *testing.T) {
+	service, repo := newService()
+	ctx := context.Background()
+	experiment := startOrderExperiment(t, service, 50)
+
+	// Find an existing user bucketed away from the winner
+	var existing uuid.UUID
+	for i := 0; ; i++ {
+		existing = uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i))
+		variants, err := service.Assign(ctx, existing, true)
+		require.NoError(t, err)
+		if variants["onboarding-order"] == "kyc_first" {
+			break
+		}
+	}
+
+	_, err := service.Conclude(ctx, adminID, experiment.ID, &entities.ConcludeExperimentRequest{WinningVariant: "email_first", Reason: "done"})
+	assert.ErrorIs(t, err, experiments.ErrInvalidExperiment)
+
+	concluded, err := service.Conclude(ctx, adminID, experiment.ID, &entities.ConcludeExperimentRequest{WinningVariant: "Wallet_First", Reason: "higher completion"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.ExperimentStatusConcluded, concluded.Status)
+	require.NotNil(t, concluded.WinningVariant)
+	assert.Equal(t, "wallet_first", *concluded.WinningVariant)
+	assert.Equal(t, now, *concluded.ConcludedAt)
+	assert.Len(t, concluded.Results, 2)
+
+	_, err = service.Conclude(ctx, adminID, experiment.ID, &entities.ConcludeExperimentRequest{WinningVariant: "kyc_first", Reason: "again"})
+	assert.ErrorIs(t, err, entities.ErrExperimentConcluded)
+
+	eventsBefore := len(repo.events)
+
+	variants, err := service.Assign(ctx, existing, true)
+	require.NoError(t, err)
+	assert.Equal(t, "kyc_first", variants["onboarding-order"], "existing users keep their variant")
+
+	newUser := uuid.New()
+	variants, err = service.Assign(ctx, newUser, true)
+	require.NoError(t, err)
+	assert.Equal(t, "wallet_first", variants["onboarding-order"], "new users get the winner")
+	assert.Empty(t, repo.assignments[newUser], "new users are not enrolled")
+
+	require.NoError(t, service.RecordConversion(ctx, existing, entities.ExperimentGoalOnboardingCompleted))
+	assert.Len(t, repo.events, eventsBefore, "results are frozen")
+}
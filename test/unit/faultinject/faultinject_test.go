@@ -0,0 +1,236 @@
+package faultinject_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/faultinject"
+)
+
+type memoryStore struct {
+	rules []*entities.FaultRule
+}
+
+func (m *memoryStore) CreateRule(_ context.Context, rule *entities.FaultRule) error {
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+func (m *memoryStore) DeleteRule(_ context.Context, id uuid.UUID) (bool, error) {
+	for i, rule := range m.rules {
+		if rule.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryStore) ListActiveRules(context.Context) ([]*entities.FaultRule, error) {
+	return m.rules, nil
+}
+
+func newProvider(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":"wallet-1","state":"LIVE"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newService(enabled bool) (*faultinject.Service, *memoryStore) {
+	store := &memoryStore{}
+	service := faultinject.NewService(store, faultinject.Config{Enabled: enabled}, zap.NewNop())
+	return service, store
+}
+
+func client(service *faultinject.Service, provider entities.FaultProvider) *http.Client {
+	return &http.Client{Transport: service.Transport(provider, http.DefaultTransport)}
+}
+
+func addRule(t *testing.T, service *faultinject.Service, req entities.CreateFaultRuleRequest) *entities.FaultRule {
+	req.Note = "rehearsal"
+	rule, err := service.AddRule(context.Background(), uuid.New(), &req)
+	require.NoError(t, err)
+	return rule
+}
+
+func TestDisabledServicePassesCallsThrough(t *testing.T) {
+	service, _ := newService(false)
+	assert.Same(t, http.DefaultTransport, service.Transport(entities.FaultProviderCircle, http.DefaultTransport))
+
+	_, err := service.AddRule(context.Background(), uuid.New(), &entities.CreateFaultRuleRequest{
+		Provider: entities.FaultProviderCircle, Fault: entities.FaultError, StatusCode: 503, Note: "x",
+	})
+	assert.ErrorIs(t, err, faultinject.ErrDisabled)
+	_, err = service.ListRules(context.Background())
+	assert.ErrorIs(t, err, faultinject.ErrDisabled)
+}
+
+func TestErrorFaultAnswersWithoutCallingProvider(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	service, _ := newService(true)
+	rule := addRule(t, service, entities.CreateFaultRuleRequest{
+		Provider: entities.FaultProviderCircle, Fault: entities.FaultError, StatusCode: 503,
+	})
+
+	resp, err := client(service, entities.FaultProviderCircle).Get(server.URL + "/v1/w3s/wallets")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, rule.ID.String(), resp.Header.Get("X-Fault-Injected"))
+	assert.False(t, called)
+}
+
+func TestErrorFaultWithoutStatusFailsAsNetworkError(t *testing.T) {
+	server := newProvider(t)
+	service, _ := newService(true)
+	addRule(t, service, entities.CreateFaultRuleRequest{Provider: entities.FaultProviderDue, Fault: entities.FaultError})
+
+	_, err := client(service, entities.FaultProviderDue).Get(server.URL + "/v1/accounts")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, faultinject.ErrInjectedFault))
+}
+
+func TestRulesMatchProviderMethodAndPath(t *testing.T) {
+	server := newProvider(t)
+	service, _ := newService(true)
+	addRule(t, service, entities.CreateFaultRuleRequest{
+		Provider: entities.FaultProviderAlpaca, Method: "post", PathPrefix: "/v1/trading/accounts",
+		Fault: entities.FaultError, StatusCode: 500,
+	})
+
+	alpaca := client(service, entities.FaultProviderAlpaca)
+	resp, err := alpaca.Post(server.URL+"/v1/trading/accounts/abc/orders", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	// Other methods, paths and providers are untouched
+	resp, err = alpaca.Get(server.URL + "/v1/trading/accounts/abc/orders")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = alpaca.Post(server.URL+"/v1/assets", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = client(service, entities.FaultProviderCircle).Post(server.URL+"/v1/trading/accounts", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestPercentLimitsShareOfCalls(t *testing.T) {
+	server := newProvider(t)
+	service, _ := newService(true)
+	roll := 0.0
+	service.SetRandom(func() float64 { return roll })
+	addRule(t, service, entities.CreateFaultRuleRequest{
+		Provider: entities.FaultProviderCircle, Fault: entities.FaultError, StatusCode: 502, Percent: 25,
+	})
+
+	circle := client(service, entities.FaultProviderCircle)
+	roll = 0.2
+	resp, err := circle.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	roll = 0.3
+	resp, err = circle.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestMalformedFaultTruncatesBody(t *testing.T) {
+	server := newProvider(t)
+	service, _ := newService(true)
+	addRule(t, service, entities.CreateFaultRuleRequest{Provider: entities.FaultProviderCircle, Fault: entities.FaultMalformed})
+
+	resp, err := client(service, entities.FaultProviderCircle).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.Error(t, json.Unmarshal(body, &decoded))
+}
+
+func TestLatencyFaultDelaysAndRespectsContext(t *testing.T) {
+	server := newProvider(t)
+	service, _ := newService(true)
+	addRule(t, service, entities.CreateFaultRuleRequest{Provider: entities.FaultProviderDue, Fault: entities.FaultLatency, LatencyMs: 50})
+	due := client(service, entities.FaultProviderDue)
+
+	start := time.Now()
+	resp, err := due.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = due.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestExpiredAndRemovedRulesStopApplying(t *testing.T) {
+	server := newProvider(t)
+	service, _ := newService(true)
+	now := time.Now()
+	service.SetClock(func() time.Time { return now })
+	rule := addRule(t, service, entities.CreateFaultRuleRequest{
+		Provider: entities.FaultProviderCircle, Fault: entities.FaultError, StatusCode: 500, TTLMinutes: 5,
+	})
+	assert.Equal(t, now.Add(5*time.Minute), rule.ExpiresAt)
+	circle := client(service, entities.FaultProviderCircle)
+
+	now = now.Add(6 * time.Minute)
+	resp, err := circle.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, service.RemoveRule(context.Background(), rule.ID))
+	assert.ErrorIs(t, service.RemoveRule(context.Background(), rule.ID), faultinject.ErrRuleNotFound)
+}
+
+func TestAddRuleValidation(t *testing.T) {
+	service, _ := newService(true)
+	cases := []entities.CreateFaultRuleRequest{
+		{Provider: "zerog", Fault: entities.FaultError, Note: "x"},
+		{Provider: entities.FaultProviderCircle, Fault: "timeout", Note: "x"},
+		{Provider: entities.FaultProviderCircle, Fault: entities.FaultLatency, Note: "x"},
+		{Provider: entities.FaultProviderCircle, Fault: entities.FaultError, PathPrefix: "v1/wallets", Note: "x"},
+	}
+	for _, req := range cases {
+		_, err := service.AddRule(context.Background(), uuid.New(), &req)
+		assert.ErrorIs(t, err, faultinject.ErrInvalidRule)
+	}
+}
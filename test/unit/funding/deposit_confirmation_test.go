@@ -0,0 +1,341 @@
+package funding_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/funding"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeDeposits struct {
+	mu         sync.Mutex
+	byTransfer map[string]*entities.Deposit
+	// missLookups hides recorded transfers from that many lookups, as when
+	// a concurrent notification records the transfer in between
+	missLookups int
+}
+
+func transferKey(chain entities.Chain, txHash string, logIndex int) string {
+	return fmt.Sprintf("%s/%s/%d", chain, txHash, logIndex)
+}
+
+func (f *fakeDeposits) find(id uuid.UUID) *entities.Deposit {
+	for _, d := range f.byTransfer {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func (f *fakeDeposits) Create(ctx context.Context, deposit *entities.Deposit) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := transferKey(deposit.Chain, deposit.TxHash, deposit.LogIndex)
+	if _, ok := f.byTransfer[key]; ok {
+		return entities.ErrDuplicateDeposit
+	}
+	copied := *deposit
+	f.byTransfer[key] = &copied
+	return nil
+}
+
+func (f *fakeDeposits) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Deposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deposits []*entities.Deposit
+	for _, d := range f.byTransfer {
+		if d.UserID == userID {
+			copied := *d
+			deposits = append(deposits, &copied)
+		}
+	}
+	sort.Slice(deposits, func(i, j int) bool { return deposits[i].ID.String() < deposits[j].ID.String() })
+	if offset >= len(deposits) {
+		return nil, nil
+	}
+	deposits = deposits[offset:]
+	if len(deposits) > limit {
+		deposits = deposits[:limit]
+	}
+	return deposits, nil
+}
+
+func (f *fakeDeposits) GetByTxHash(ctx context.Context, txHash string) (*entities.Deposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.byTransfer {
+		if d.TxHash == txHash {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("deposit not found")
+}
+
+func (f *fakeDeposits) GetByTransfer(ctx context.Context, chain entities.Chain, txHash string, logIndex int) (*entities.Deposit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.byTransfer[transferKey(chain, txHash, logIndex)]
+	if !ok || f.missLookups > 0 {
+		if f.missLookups > 0 {
+			f.missLookups--
+		}
+		return nil, errors.New("deposit not found")
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (f *fakeDeposits) UpdateStatus(ctx context.Context, id uuid.UUID, status string, confirmedAt *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.find(id).Status = status
+	return nil
+}
+
+func (f *fakeDeposits) UpdateConfirmations(ctx context.Context, id uuid.UUID, confirmations int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.find(id).Confirmations = confirmations
+	return nil
+}
+
+func (f *fakeDeposits) MarkConfirmed(ctx context.Context, id uuid.UUID, confirmations int, confirmedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.find(id)
+	if d.Status == entities.DepositStatusDetected {
+		d.Status = entities.DepositStatusConfirmed
+		d.Confirmations = confirmations
+		d.ConfirmedAt = &confirmedAt
+	}
+	return nil
+}
+
+func (f *fakeDeposits) MarkCredited(ctx context.Context, id uuid.UUID, amount decimal.Decimal, ledgerTxID *uuid.UUID, creditedAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.find(id)
+	if d.Status != entities.DepositStatusConfirmed {
+		return false, nil
+	}
+	d.Status = entities.DepositStatusCredited
+	d.CreditedAmount = &amount
+	d.LedgerTransactionID = ledgerTxID
+	d.CreditedAt = &creditedAt
+	return true, nil
+}
+
+func (f *fakeDeposits) MarkReversed(ctx context.Context, id uuid.UUID, fromStatus, reason string, reversedAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.find(id)
+	if d.Status != fromStatus {
+		return false, nil
+	}
+	d.Status = entities.DepositStatusReversed
+	d.ReversalReason = &reason
+	d.ReversedAt = &reversedAt
+	return true, nil
+}
+
+type fakeBalances struct {
+	buyingPower map[uuid.UUID]decimal.Decimal
+}
+
+func (f *fakeBalances) Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error) {
+	return &entities.Balance{UserID: userID, BuyingPower: f.buyingPower[userID]}, nil
+}
+
+func (f *fakeBalances) UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f.buyingPower[userID] = f.buyingPower[userID].Add(amount)
+	return nil
+}
+
+func (f *fakeBalances) UpdatePendingDeposits(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	return nil
+}
+
+type fakeWallets struct {
+	userID uuid.UUID
+}
+
+func (f *fakeWallets) GetByUserAndChain(ctx context.Context, userID uuid.UUID, chain entities.Chain) (*entities.Wallet, error) {
+	return nil, errors.New("not found")
+}
+
+func (f *fakeWallets) GetByAddress(ctx context.Context, address string) (*entities.Wallet, error) {
+	return &entities.Wallet{ID: uuid.New(), UserID: f.userID, Address: address}, nil
+}
+
+func (f *fakeWallets) Create(ctx context.Context, wallet *entities.Wallet) error {
+	return nil
+}
+
+type fakeCircleAdapter struct{}
+
+func (fakeCircleAdapter) GenerateDepositAddress(ctx context.Context, chain entities.Chain, userID uuid.UUID) (string, error) {
+	return "", nil
+}
+
+func (fakeCircleAdapter) ValidateDeposit(ctx context.Context, txHash string, amount decimal.Decimal) (bool, error) {
+	return true, nil
+}
+
+func (fakeCircleAdapter) ConvertToUSD(ctx context.Context, amount decimal.Decimal, token entities.Stablecoin) (decimal.Decimal, error) {
+	return amount, nil
+}
+
+func (fakeCircleAdapter) GetWalletBalances(ctx context.Context, walletID string, tokenAddress ...string) (*entities.CircleWalletBalancesResponse, error) {
+	return nil, nil
+}
+
+type fakeLedger struct {
+	posted   map[string]*entities.LedgerTransaction
+	reversed []uuid.UUID
+}
+
+func (f *fakeLedger) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return &entities.LedgerAccount{ID: uuid.New()}, nil
+}
+
+func (f *fakeLedger) GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return &entities.LedgerAccount{ID: uuid.New()}, nil
+}
+
+func (f *fakeLedger) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	if tx, ok := f.posted[req.IdempotencyKey]; ok {
+		return tx, nil
+	}
+	tx := &entities.LedgerTransaction{ID: uuid.New(), IdempotencyKey: req.IdempotencyKey}
+	f.posted[req.IdempotencyKey] = tx
+	return tx, nil
+}
+
+func (f *fakeLedger) ReverseTransaction(ctx context.Context, originalTxID uuid.UUID, reason string) error {
+	f.reversed = append(f.reversed, originalTxID)
+	return nil
+}
+
+type depositFixture struct {
+	svc      *funding.Service
+	deposits *fakeDeposits
+	balances *fakeBalances
+	ledger   *fakeLedger
+	userID   uuid.UUID
+}
+
+func newDepositFixture() *depositFixture {
+	f := &depositFixture{
+		deposits: &fakeDeposits{byTransfer: map[string]*entities.Deposit{}},
+		balances: &fakeBalances{buyingPower: map[uuid.UUID]decimal.Decimal{}},
+		ledger:   &fakeLedger{posted: map[string]*entities.LedgerTransaction{}},
+		userID:   uuid.New(),
+	}
+	f.svc = funding.NewService(f.deposits, f.balances, &fakeWallets{userID: f.userID}, nil, nil,
+		fakeCircleAdapter{}, nil, nil, logger.NewLogger(zap.NewNop()))
+	f.svc.SetLedger(f.ledger)
+	f.svc.SetConfirmationThresholds(funding.ConfirmationThresholds{
+		Default: 1,
+		Chains:  map[string]int{"polygon": 12},
+	})
+	return f
+}
+
+func (f *depositFixture) notify(t *testing.T, confirmations int, removed bool) {
+	t.Helper()
+	require.NoError(t, f.svc.ProcessChainDeposit(context.Background(), &entities.ChainDepositWebhook{
+		Chain:         entities.ChainPolygon,
+		Address:       "0xabc",
+		Token:         entities.StablecoinUSDC,
+		Amount:        "25",
+		TxHash:        "0xtx",
+		Confirmations: confirmations,
+		Removed:       removed,
+	}))
+}
+
+func TestConfirmationThresholds_Required(t *testing.T) {
+	thresholds := funding.ConfirmationThresholds{Default: 1, Chains: map[string]int{"polygon": 128, "sol-devnet": 1}}
+	assert.Equal(t, 128, thresholds.Required(entities.ChainPolygon))
+	assert.Equal(t, 1, thresholds.Required(entities.Chain("SOL-DEVNET")), "chains match case-insensitively")
+	assert.Equal(t, 1, thresholds.Required(entities.ChainAptos))
+}
+
+func TestProcessChainDeposit_CreditsOnlyOnceThresholdReached(t *testing.T) {
+	f := newDepositFixture()
+
+	f.notify(t, 3, false)
+	deposit, err := f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DepositStatusDetected, deposit.Status)
+	assert.Equal(t, 12, deposit.RequiredConfirmations)
+	assert.True(t, f.balances.buyingPower[f.userID].IsZero())
+	assert.Empty(t, f.ledger.posted)
+
+	f.notify(t, 12, false)
+	f.notify(t, 13, false)
+	deposit, err = f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DepositStatusCredited, deposit.Status)
+	assert.NotNil(t, deposit.ConfirmedAt)
+	require.NotNil(t, deposit.LedgerTransactionID)
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String(), "credited exactly once")
+	assert.Len(t, f.ledger.posted, 1)
+}
+
+func TestProcessChainDeposit_RemovedReversesCredit(t *testing.T) {
+	f := newDepositFixture()
+	f.notify(t, 12, false)
+	credited, err := f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+
+	f.notify(t, 0, true)
+	f.notify(t, 0, true)
+
+	deposit, err := f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DepositStatusReversed, deposit.Status)
+	assert.True(t, f.balances.buyingPower[f.userID].IsZero())
+	assert.Equal(t, []uuid.UUID{*credited.LedgerTransactionID}, f.ledger.reversed, "reversed exactly once")
+}
+
+func TestProcessChainDeposit_RemovedBeforeCreditPostsNothing(t *testing.T) {
+	f := newDepositFixture()
+	f.notify(t, 2, false)
+	f.notify(t, 0, true)
+	f.notify(t, 20, false)
+
+	deposit, err := f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+	assert.Equal(t, entities.DepositStatusReversed, deposit.Status)
+	assert.True(t, f.balances.buyingPower[f.userID].IsZero())
+	assert.Empty(t, f.ledger.posted)
+	assert.Empty(t, f.ledger.reversed)
+}
+
+func TestReverseChainDeposit_SettledDepositIsNotReversible(t *testing.T) {
+	f := newDepositFixture()
+	f.notify(t, 12, false)
+	deposit, err := f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+	require.NoError(t, f.deposits.UpdateStatus(context.Background(), deposit.ID, "broker_funded", nil))
+
+	err = f.svc.ReverseChainDeposit(context.Background(), "0xtx", "re-org")
+	assert.ErrorIs(t, err, entities.ErrDepositNotReversible)
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String())
+}
@@ -0,0 +1,92 @@
+package funding_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+func (f *depositFixture) notifyTransfer(t *testing.T, logIndex, confirmations int) {
+	t.Helper()
+	require.NoError(t, f.svc.ProcessChainDeposit(context.Background(), &entities.ChainDepositWebhook{
+		Chain:         entities.ChainPolygon,
+		Address:       "0xabc",
+		Token:         entities.StablecoinUSDC,
+		Amount:        "25",
+		TxHash:        "0xtx",
+		LogIndex:      logIndex,
+		Confirmations: confirmations,
+	}))
+}
+
+func deduplicated() float64 {
+	return testutil.ToFloat64(metrics.DepositsDeduplicated.WithLabelValues(metrics.DepositSourceChainWatcher))
+}
+
+func TestProcessChainDeposit_TransfersInOneTransactionAreCreditedSeparately(t *testing.T) {
+	f := newDepositFixture()
+
+	f.notifyTransfer(t, 0, 12)
+	f.notifyTransfer(t, 3, 12)
+
+	assert.Len(t, f.deposits.byTransfer, 2)
+	assert.Equal(t, "50", f.balances.buyingPower[f.userID].String())
+	assert.Len(t, f.ledger.posted, 2)
+}
+
+func TestProcessChainDeposit_ReplaysAfterCreditAreDeduplicated(t *testing.T) {
+	f := newDepositFixture()
+	before := deduplicated()
+
+	f.notifyTransfer(t, 0, 12)
+	f.notifyTransfer(t, 0, 12)
+	f.notifyTransfer(t, 0, 40)
+
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String())
+	assert.Len(t, f.ledger.posted, 1)
+	assert.Equal(t, 2.0, deduplicated()-before)
+}
+
+func TestProcessChainDeposit_ConcurrentlyRecordedTransferIsNotCreditedTwice(t *testing.T) {
+	f := newDepositFixture()
+	f.notifyTransfer(t, 0, 12)
+	before := deduplicated()
+
+	// The lookup misses, so the insert hits the unique key instead
+	f.deposits.missLookups = 1
+	f.notifyTransfer(t, 0, 12)
+
+	assert.Len(t, f.deposits.byTransfer, 1)
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String())
+	assert.Len(t, f.ledger.posted, 1)
+	assert.Equal(t, 1.0, deduplicated()-before)
+}
+
+func TestIsTransferProcessed(t *testing.T) {
+	f := newDepositFixture()
+	ctx := context.Background()
+
+	processed, err := f.svc.IsTransferProcessed(ctx, entities.ChainPolygon, "0xtx", 0)
+	require.NoError(t, err)
+	assert.False(t, processed, "unknown transfer")
+
+	f.notifyTransfer(t, 0, 2)
+	processed, err = f.svc.IsTransferProcessed(ctx, entities.ChainPolygon, "0xtx", 0)
+	require.NoError(t, err)
+	assert.False(t, processed, "awaiting confirmations")
+
+	f.notifyTransfer(t, 0, 12)
+	processed, err = f.svc.IsTransferProcessed(ctx, entities.ChainPolygon, "0xtx", 0)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	processed, err = f.svc.IsTransferProcessed(ctx, entities.ChainPolygon, "0xtx", 1)
+	require.NoError(t, err)
+	assert.False(t, processed, "other transfers in the transaction are independent")
+}
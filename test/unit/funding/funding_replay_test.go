@@ -0,0 +1,110 @@
+package funding_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/funding"
+)
+
+// seed records a deposit directly, as an earlier pipeline run left it
+func (f *depositFixture) seed(deposit entities.Deposit) *entities.Deposit {
+	deposit.ID = uuid.New()
+	deposit.UserID = f.userID
+	deposit.CreatedAt = time.Now()
+	f.deposits.byTransfer[transferKey(deposit.Chain, deposit.TxHash, deposit.LogIndex)] = &deposit
+	return &deposit
+}
+
+// seedHistory leaves one credited deposit, one that reached its threshold but
+// was never credited, one awaiting confirmations and one fiat deposit
+func seedHistory(t *testing.T) (*depositFixture, *entities.Deposit) {
+	f := newDepositFixture()
+	f.notifyTransfer(t, 0, 12)
+	stuck := f.seed(entities.Deposit{
+		Chain: entities.ChainPolygon, TxHash: "0xstuck", Amount: decimal.NewFromInt(40),
+		Status: entities.DepositStatusConfirmed, Confirmations: 12, RequiredConfirmations: 12,
+	})
+	f.notifyTransfer(t, 2, 3)
+	f.seed(entities.Deposit{Amount: decimal.NewFromInt(100), Status: "off_ramp_completed"})
+	return f, stuck
+}
+
+func actions(report *entities.FundingReplayReport) map[string]int {
+	counts := map[string]int{}
+	for _, entry := range report.Entries {
+		counts[entry.Action]++
+	}
+	return counts
+}
+
+func TestReplayUserDeposits_DryRunReportsWithoutCrediting(t *testing.T) {
+	f, _ := seedHistory(t)
+
+	report, err := f.svc.ReplayUserDeposits(context.Background(), f.userID, entities.FundingReplayDryRun)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		entities.FundingReplayUnchanged: 2,
+		entities.FundingReplayCredit:    1,
+		entities.FundingReplaySkipped:   1,
+	}, actions(report))
+	assert.Equal(t, "25", report.BuyingPowerBefore.String())
+	assert.Equal(t, "40", report.NetChange.String())
+	assert.Equal(t, "65", report.BuyingPowerAfter.String())
+
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String(), "dry run leaves balances alone")
+	assert.Len(t, f.ledger.posted, 1)
+}
+
+func TestReplayUserDeposits_ApplyCreditsStuckDepositsOnce(t *testing.T) {
+	f, stuck := seedHistory(t)
+	ctx := context.Background()
+
+	report, err := f.svc.ReplayUserDeposits(ctx, f.userID, entities.FundingReplayApply)
+	require.NoError(t, err)
+	assert.Equal(t, "40", report.NetChange.String())
+	assert.Equal(t, "65", report.BuyingPowerAfter.String())
+	assert.Equal(t, "65", f.balances.buyingPower[f.userID].String())
+
+	deposit, err := f.deposits.GetByTransfer(ctx, stuck.Chain, stuck.TxHash, 0)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DepositStatusCredited, deposit.Status)
+
+	report, err = f.svc.ReplayUserDeposits(ctx, f.userID, entities.FundingReplayApply)
+	require.NoError(t, err)
+	assert.True(t, report.NetChange.IsZero(), "a second replay finds nothing to do")
+	assert.Equal(t, "65", f.balances.buyingPower[f.userID].String())
+}
+
+func TestReplayUserDeposits_MispricedCreditNeedsManualReview(t *testing.T) {
+	f := newDepositFixture()
+	credited := decimal.NewFromInt(20)
+	f.seed(entities.Deposit{
+		Chain: entities.ChainPolygon, TxHash: "0xold", Amount: decimal.NewFromInt(25),
+		Status: entities.DepositStatusCredited, Confirmations: 12, RequiredConfirmations: 12,
+		CreditedAmount: &credited,
+	})
+
+	report, err := f.svc.ReplayUserDeposits(context.Background(), f.userID, entities.FundingReplayApply)
+	require.NoError(t, err)
+	require.Len(t, report.Entries, 1)
+	entry := report.Entries[0]
+	assert.Equal(t, entities.FundingReplayManualReview, entry.Action)
+	assert.Equal(t, "5", entry.Change.String())
+	assert.True(t, report.NetChange.IsZero(), "manual review entries are not applied")
+	assert.True(t, f.balances.buyingPower[f.userID].IsZero())
+}
+
+func TestReplayUserDeposits_RejectsUnknownMode(t *testing.T) {
+	f := newDepositFixture()
+	_, err := f.svc.ReplayUserDeposits(context.Background(), f.userID, "rebuild")
+	assert.ErrorIs(t, err, funding.ErrInvalidReplayMode)
+}
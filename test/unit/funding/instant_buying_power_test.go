@@ -0,0 +1,194 @@
+package funding_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/funding"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeAdvances struct {
+	byDeposit map[uuid.UUID]*entities.InstantFundingAdvance
+}
+
+func (f *fakeAdvances) Create(ctx context.Context, advance *entities.InstantFundingAdvance) (bool, error) {
+	if _, ok := f.byDeposit[advance.DepositID]; ok {
+		return false, nil
+	}
+	stored := *advance
+	f.byDeposit[advance.DepositID] = &stored
+	return true, nil
+}
+
+func (f *fakeAdvances) GetByDepositID(ctx context.Context, depositID uuid.UUID) (*entities.InstantFundingAdvance, error) {
+	advance, ok := f.byDeposit[depositID]
+	if !ok {
+		return nil, entities.ErrInstantAdvanceNotFound
+	}
+	copied := *advance
+	return &copied, nil
+}
+
+func (f *fakeAdvances) Transition(ctx context.Context, advance *entities.InstantFundingAdvance, from entities.InstantAdvanceStatus) (bool, error) {
+	stored, ok := f.byDeposit[advance.DepositID]
+	if !ok || stored.Status != from {
+		return false, nil
+	}
+	*stored = *advance
+	return true, nil
+}
+
+type fakeVirtualAccounts struct{}
+
+func (fakeVirtualAccounts) Create(ctx context.Context, account *entities.VirtualAccount) error {
+	return nil
+}
+
+func (fakeVirtualAccounts) GetByID(ctx context.Context, id uuid.UUID) (*entities.VirtualAccount, error) {
+	return nil, errors.New("not found")
+}
+
+func (fakeVirtualAccounts) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.VirtualAccount, error) {
+	return []*entities.VirtualAccount{{UserID: userID, AlpacaAccountID: "alpaca-1"}}, nil
+}
+
+func (fakeVirtualAccounts) GetByAlpacaAccountID(ctx context.Context, alpacaAccountID string) (*entities.VirtualAccount, error) {
+	return nil, errors.New("not found")
+}
+
+func (fakeVirtualAccounts) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.VirtualAccountStatus) error {
+	return nil
+}
+
+func (fakeVirtualAccounts) ExistsByUserAndAlpacaAccount(ctx context.Context, userID uuid.UUID, alpacaAccountID string) (bool, error) {
+	return true, nil
+}
+
+type fakeInstantFunding struct {
+	requests []*entities.AlpacaInstantFundingRequest
+	err      error
+}
+
+func (f *fakeInstantFunding) GetAccount(ctx context.Context, accountID string) (*entities.AlpacaAccountResponse, error) {
+	return &entities.AlpacaAccountResponse{ID: accountID, AccountNumber: "ACC-1", Status: entities.AlpacaAccountStatusActive}, nil
+}
+
+func (f *fakeInstantFunding) InitiateInstantFunding(ctx context.Context, req *entities.AlpacaInstantFundingRequest) (*entities.AlpacaInstantFundingResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.requests = append(f.requests, req)
+	return &entities.AlpacaInstantFundingResponse{ID: "transfer-1", Amount: req.Amount, Status: "EXECUTED"}, nil
+}
+
+func (f *fakeInstantFunding) GetInstantFundingStatus(ctx context.Context, transferID string) (*entities.AlpacaInstantFundingResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeInstantFunding) GetAccountBalance(ctx context.Context, accountID string) (*entities.AlpacaAccountResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeInstantFunding) CreateJournal(ctx context.Context, req *entities.AlpacaJournalRequest) (*entities.AlpacaJournalResponse, error) {
+	return nil, nil
+}
+
+type instantFixture struct {
+	*depositFixture
+	advances *fakeAdvances
+	alpaca   *fakeInstantFunding
+}
+
+func newInstantFixture() *instantFixture {
+	f := &instantFixture{
+		depositFixture: newDepositFixture(),
+		advances:       &fakeAdvances{byDeposit: map[uuid.UUID]*entities.InstantFundingAdvance{}},
+		alpaca:         &fakeInstantFunding{},
+	}
+	f.svc = funding.NewService(f.deposits, f.balances, &fakeWallets{userID: f.userID}, nil, fakeVirtualAccounts{},
+		fakeCircleAdapter{}, nil, f.alpaca, logger.NewLogger(zap.NewNop()))
+	f.svc.SetLedger(f.ledger)
+	f.svc.SetConfirmationThresholds(funding.ConfirmationThresholds{
+		Default: 1,
+		Chains:  map[string]int{"polygon": 12},
+	})
+	f.svc.SetInstantBuyingPower(f.advances, funding.InstantBuyingPower{
+		Percent:   decimal.NewFromInt(80),
+		MaxAmount: decimal.NewFromInt(15),
+	})
+	return f
+}
+
+func (f *instantFixture) advance(t *testing.T) *entities.InstantFundingAdvance {
+	t.Helper()
+	deposit, err := f.deposits.GetByTxHash(context.Background(), "0xtx")
+	require.NoError(t, err)
+	advance, err := f.advances.GetByDepositID(context.Background(), deposit.ID)
+	require.NoError(t, err)
+	return advance
+}
+
+func TestInstantBuyingPower_AdvanceRepaidOnCredit(t *testing.T) {
+	f := newInstantFixture()
+
+	f.notify(t, 3, false)
+	f.notify(t, 5, false)
+	advance := f.advance(t)
+	assert.Equal(t, entities.InstantAdvanceActive, advance.Status)
+	assert.Equal(t, "15", advance.Amount.String(), "80% of 25 is capped at 15")
+	require.Len(t, f.alpaca.requests, 1, "advanced once per deposit")
+	assert.Equal(t, "ACC-1", f.alpaca.requests[0].AccountNo)
+	assert.Equal(t, "SI", f.alpaca.requests[0].SourceAccountNo)
+	assert.Equal(t, "15", f.balances.buyingPower[f.userID].String())
+
+	f.notify(t, 12, false)
+	assert.Equal(t, entities.InstantAdvanceRepaid, f.advance(t).Status)
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String(), "the deposit is never counted twice")
+}
+
+func TestInstantBuyingPower_ClawedBackWhenDepositDropped(t *testing.T) {
+	f := newInstantFixture()
+
+	f.notify(t, 3, false)
+	f.balances.buyingPower[f.userID] = f.balances.buyingPower[f.userID].Sub(decimal.NewFromInt(10)) // spent on a trade
+	f.notify(t, 0, true)
+
+	advance := f.advance(t)
+	assert.Equal(t, entities.InstantAdvanceReversed, advance.Status)
+	assert.Equal(t, "10", advance.Shortfall.String())
+	assert.Equal(t, "-10", f.balances.buyingPower[f.userID].String())
+
+	f.notify(t, 0, true)
+	assert.Equal(t, "-10", f.balances.buyingPower[f.userID].String(), "clawed back once")
+}
+
+func TestInstantBuyingPower_NoAdvanceWhenInstantFundingFails(t *testing.T) {
+	f := newInstantFixture()
+	f.alpaca.err = errors.New("instant funding limit reached")
+
+	f.notify(t, 3, false)
+	advance := f.advance(t)
+	assert.Equal(t, entities.InstantAdvanceFailed, advance.Status)
+	require.NotNil(t, advance.FailureReason)
+	assert.True(t, f.balances.buyingPower[f.userID].IsZero())
+
+	f.notify(t, 12, false)
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String())
+}
+
+func TestInstantBuyingPower_NotAdvancedWhenAlreadyConfirmed(t *testing.T) {
+	f := newInstantFixture()
+
+	f.notify(t, 12, false)
+	assert.Empty(t, f.advances.byDeposit)
+	assert.Equal(t, "25", f.balances.buyingPower[f.userID].String())
+}
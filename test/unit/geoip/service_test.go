@@ -0,0 +1,217 @@
+package geoip_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/internal/domain/services/geoip"
+)
+
+type fakeProvider struct {
+	results map[string]*entities.GeoIPResult
+	err     error
+	lookups int
+}
+
+func (p *fakeProvider) Lookup(ctx context.Context, ip string) (*entities.GeoIPResult, error) {
+	p.lookups++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.results[ip], nil
+}
+
+type fakeJurisdiction struct {
+	blocked map[string]bool
+}
+
+func (j *fakeJurisdiction) EvaluateOnboarding(ctx context.Context, countryCode string) (*entities.JurisdictionDecision, error) {
+	return &entities.JurisdictionDecision{CountryCode: countryCode, Allowed: !j.blocked[countryCode]}, nil
+}
+
+type fakeRepo struct {
+	events    []*entities.GeoAccessEvent
+	overrides []*entities.GeoOverride
+}
+
+func (r *fakeRepo) RecordEvent(ctx context.Context, event *entities.GeoAccessEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeRepo) LatestFlaggedLogin(ctx context.Context, userID uuid.UUID, since time.Time) (*entities.GeoAccessEvent, error) {
+	for i := len(r.events) - 1; i >= 0; i-- {
+		event := r.events[i]
+		if event.UserID != nil && *event.UserID == userID && event.Flagged && !event.CreatedAt.Before(since) {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepo) ListEventsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.GeoAccessEvent, error) {
+	return r.events, nil
+}
+
+func (r *fakeRepo) ListOverrides(ctx context.Context) ([]*entities.GeoOverride, error) {
+	return r.overrides, nil
+}
+
+func (r *fakeRepo) UpsertOverride(ctx context.Context, override *entities.GeoOverride) error {
+	r.overrides = append(r.overrides, override)
+	return nil
+}
+
+func (r *fakeRepo) DeleteOverride(ctx context.Context, id uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+const (
+	britishIP  = "81.2.69.142"
+	blockedIP  = "175.45.176.1"
+	vpnIP      = "185.220.101.4"
+	officeCIDR = "175.45.176.0/24"
+)
+
+func newService() (*geoip.Service, *fakeProvider, *fakeRepo) {
+	provider := &fakeProvider{results: map[string]*entities.GeoIPResult{
+		britishIP: {IP: britishIP, CountryCode: "gb", City: "London", RiskScore: 10},
+		blockedIP: {IP: blockedIP, CountryCode: "KP", RiskScore: 40},
+		vpnIP:     {IP: vpnIP, CountryCode: "NL", IsVPN: true, RiskScore: 90},
+	}}
+	repo := &fakeRepo{}
+	jurisdiction := &fakeJurisdiction{blocked: map[string]bool{"KP": true}}
+	return geoip.NewService(provider, jurisdiction, repo, geoip.DefaultConfig(), zap.NewNop()), provider, repo
+}
+
+func TestCheckSignup_BlocksProhibitedCountries(t *testing.T) {
+	service, _, repo := newService()
+	ctx := context.Background()
+
+	event, err := service.CheckSignup(ctx, britishIP)
+	require.NoError(t, err)
+	assert.Equal(t, "GB", event.CountryCode)
+	assert.Empty(t, repo.events, "allowed signups are recorded once the account exists")
+
+	userID := uuid.New()
+	require.NoError(t, service.RecordSignup(ctx, userID, event))
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, userID, *repo.events[0].UserID)
+
+	event, err = service.CheckSignup(ctx, blockedIP)
+	assert.ErrorIs(t, err, entities.ErrSignupLocationBlocked)
+	assert.Equal(t, "KP", event.CountryCode)
+	require.Len(t, repo.events, 2)
+	assert.True(t, repo.events[1].Blocked)
+	assert.Nil(t, repo.events[1].UserID)
+}
+
+func TestCheckSignup_OverrideAllowsBlockedRange(t *testing.T) {
+	service, _, _ := newService()
+	ctx := context.Background()
+
+	_, err := service.CreateOverride(ctx, &entities.CreateGeoOverrideRequest{
+		Kind:   entities.GeoOverrideIP,
+		Value:  officeCIDR,
+		Reason: "Partner office egress",
+	}, uuid.New())
+	require.NoError(t, err)
+
+	event, err := service.CheckSignup(ctx, blockedIP)
+	require.NoError(t, err)
+	assert.True(t, event.Overridden)
+	assert.False(t, event.Blocked)
+}
+
+func TestCheckSignup_UnlocatableAddressesAreAllowed(t *testing.T) {
+	service, provider, _ := newService()
+	ctx := context.Background()
+
+	event, err := service.CheckSignup(ctx, "10.0.0.7")
+	require.NoError(t, err)
+	assert.Empty(t, event.CountryCode)
+	assert.Zero(t, provider.lookups, "private addresses are not looked up")
+
+	provider.err = errors.New("provider unavailable")
+	_, err = service.CheckSignup(ctx, blockedIP)
+	assert.NoError(t, err, "a provider outage does not stop signups")
+}
+
+func TestRecordLogin_FlagsMaskedHighRiskSessions(t *testing.T) {
+	service, _, _ := newService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	event, err := service.RecordLogin(ctx, userID, britishIP)
+	require.NoError(t, err)
+	assert.False(t, event.Flagged)
+
+	event, err = service.RecordLogin(ctx, userID, vpnIP)
+	require.NoError(t, err)
+	assert.True(t, event.Flagged)
+
+	control := services.NewTransactionControlService(zap.NewNop())
+	control.SetSessionRiskSource(service)
+	score, factors := control.CalculateFraudScore(ctx, userID, decimal.NewFromInt(20000), "withdrawal")
+	assert.True(t, score.GreaterThan(decimal.NewFromFloat(0.7)), "a flagged session pushes a large transaction over the review threshold")
+	assert.Equal(t, true, factors["masked_session"])
+	assert.Equal(t, "NL", factors["session_country"])
+
+	score, _ = control.CalculateFraudScore(ctx, uuid.New(), decimal.NewFromInt(20000), "withdrawal")
+	assert.True(t, score.Equal(decimal.NewFromFloat(0.3)))
+}
+
+func TestRecordLogin_UserOverrideSuppressesFlag(t *testing.T) {
+	service, _, _ := newService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := service.CreateOverride(ctx, &entities.CreateGeoOverrideRequest{
+		Kind:   entities.GeoOverrideUser,
+		Value:  userID.String(),
+		Reason: "Travelling with a corporate VPN",
+	}, uuid.New())
+	require.NoError(t, err)
+
+	event, err := service.RecordLogin(ctx, userID, vpnIP)
+	require.NoError(t, err)
+	assert.False(t, event.Flagged)
+	assert.True(t, event.Overridden)
+
+	flagged, err := service.SessionRisk(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, flagged)
+}
+
+func TestCreateOverride_Validation(t *testing.T) {
+	service, _, _ := newService()
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+
+	cases := []entities.CreateGeoOverrideRequest{
+		{Kind: entities.GeoOverrideIP, Value: "not-an-ip", Reason: "x"},
+		{Kind: entities.GeoOverrideUser, Value: "42", Reason: "x"},
+		{Kind: entities.GeoOverrideIP, Value: britishIP, Reason: " "},
+		{Kind: entities.GeoOverrideIP, Value: britishIP, Reason: "x", ExpiresAt: &past},
+	}
+	for _, req := range cases {
+		_, err := service.CreateOverride(ctx, &req, uuid.New())
+		assert.ErrorIs(t, err, geoip.ErrInvalidOverride, "%+v", req)
+	}
+
+	override, err := service.CreateOverride(ctx, &entities.CreateGeoOverrideRequest{
+		Kind: entities.GeoOverrideIP, Value: "175.45.176.9/24", Reason: "x",
+	}, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "175.45.176.0/24", override.Value)
+}
@@ -0,0 +1,284 @@
+package goals_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/goals"
+)
+
+type fakeRepo struct {
+	goals map[uuid.UUID]*entities.Goal
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{goals: make(map[uuid.UUID]*entities.Goal)}
+}
+
+func (f *fakeRepo) Create(ctx context.Context, goal *entities.Goal) error {
+	copied := *goal
+	f.goals[goal.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.Goal, error) {
+	goal, ok := f.goals[id]
+	if !ok {
+		return nil, entities.ErrGoalNotFound
+	}
+	copied := *goal
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Goal, error) {
+	var result []*entities.Goal
+	for _, goal := range f.goals {
+		if goal.UserID == userID {
+			copied := *goal
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRepo) Update(ctx context.Context, goal *entities.Goal) error {
+	copied := *goal
+	f.goals[goal.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ListActive(ctx context.Context, afterID uuid.UUID, limit int) ([]*entities.Goal, error) {
+	var result []*entities.Goal
+	for _, goal := range f.goals {
+		if goal.Status == entities.GoalStatusActive && goal.ID.String() > afterID.String() {
+			copied := *goal
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID.String() < result[j].ID.String() })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+type fakePositions struct {
+	values map[uuid.UUID]decimal.Decimal
+}
+
+func (f *fakePositions) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
+	value, ok := f.values[userID]
+	if !ok {
+		return nil, nil
+	}
+	return []*entities.Position{{UserID: userID, MarketValue: value}}, nil
+}
+
+type fakeNotifier struct {
+	sent []*entities.Notification
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error {
+	f.sent = append(f.sent, notification)
+	return nil
+}
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type fixture struct {
+	svc       *goals.Service
+	repo      *fakeRepo
+	positions *fakePositions
+	notifier  *fakeNotifier
+	now       time.Time
+}
+
+func newFixture(config goals.Config) *fixture {
+	f := &fixture{
+		repo:      newFakeRepo(),
+		positions: &fakePositions{values: make(map[uuid.UUID]decimal.Decimal)},
+		notifier:  &fakeNotifier{},
+		now:       start,
+	}
+	f.svc = goals.NewService(f.repo, f.positions, f.notifier, config, zap.NewNop())
+	f.svc.SetClock(func() time.Time { return f.now })
+	return f
+}
+
+func zeroReturns() goals.Config {
+	config := goals.DefaultConfig()
+	config.ExpectedReturns = map[entities.RiskLevel]decimal.Decimal{
+		entities.RiskLevelConservative: decimal.Zero,
+		entities.RiskLevelBalanced:     decimal.Zero,
+		entities.RiskLevelGrowth:       decimal.Zero,
+	}
+	return config
+}
+
+func TestCreate_PlansMonthlyContribution(t *testing.T) {
+	f := newFixture(zeroReturns())
+	user := uuid.New()
+	f.positions.values[user] = decimal.NewFromInt(1000)
+
+	progress, err := f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name:         "House deposit",
+		TargetAmount: decimal.NewFromInt(13000),
+		TargetDate:   start.AddDate(1, 0, 0),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.RiskLevelBalanced, progress.Goal.RiskLevel)
+	assert.Equal(t, 12, progress.MonthsRemaining)
+	assert.Equal(t, "1000", progress.RequiredMonthly.String())
+	assert.Equal(t, "1000", progress.Goal.PlannedMonthly.String())
+	assert.True(t, progress.OnTrack)
+}
+
+func TestCreate_HigherReturnsNeedLessEachMonth(t *testing.T) {
+	f := newFixture(goals.DefaultConfig())
+	user := uuid.New()
+	req := func(risk entities.RiskLevel) *entities.CreateGoalRequest {
+		return &entities.CreateGoalRequest{
+			Name:         "Retirement",
+			TargetAmount: decimal.NewFromInt(100000),
+			TargetDate:   start.AddDate(10, 0, 0),
+			RiskLevel:    risk,
+		}
+	}
+
+	conservative, err := f.svc.Create(context.Background(), user, req(entities.RiskLevelConservative))
+	require.NoError(t, err)
+	growth, err := f.svc.Create(context.Background(), user, req(entities.RiskLevelGrowth))
+	require.NoError(t, err)
+
+	assert.True(t, growth.RequiredMonthly.LessThan(conservative.RequiredMonthly))
+	// Contributing the required amount reaches the target
+	assert.True(t, growth.ProjectedValue.GreaterThanOrEqual(decimal.NewFromInt(100000)))
+}
+
+func TestCreate_Validation(t *testing.T) {
+	f := newFixture(goals.DefaultConfig())
+	user := uuid.New()
+
+	_, err := f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name: "Soon", TargetAmount: decimal.NewFromInt(500), TargetDate: start.AddDate(0, 0, 10),
+	})
+	assert.ErrorIs(t, err, entities.ErrInvalidGoal)
+
+	_, err = f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name: "Nothing", TargetAmount: decimal.Zero, TargetDate: start.AddDate(1, 0, 0),
+	})
+	assert.ErrorIs(t, err, entities.ErrInvalidGoal)
+
+	_, err = f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name: "Yolo", TargetAmount: decimal.NewFromInt(500), TargetDate: start.AddDate(1, 0, 0), RiskLevel: "aggressive",
+	})
+	assert.ErrorIs(t, err, entities.ErrInvalidGoal)
+}
+
+func TestGet_OtherUsersGoalIsNotFound(t *testing.T) {
+	f := newFixture(goals.DefaultConfig())
+	progress, err := f.svc.Create(context.Background(), uuid.New(), &entities.CreateGoalRequest{
+		Name: "Car", TargetAmount: decimal.NewFromInt(20000), TargetDate: start.AddDate(2, 0, 0),
+	})
+	require.NoError(t, err)
+
+	_, err = f.svc.Get(context.Background(), uuid.New(), progress.Goal.ID)
+	assert.ErrorIs(t, err, entities.ErrGoalNotFound)
+}
+
+func TestUpdate_NewTargetReplans(t *testing.T) {
+	f := newFixture(zeroReturns())
+	user := uuid.New()
+	created, err := f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name: "Trip", TargetAmount: decimal.NewFromInt(1200), TargetDate: start.AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+
+	target := decimal.NewFromInt(2400)
+	updated, err := f.svc.Update(context.Background(), user, created.Goal.ID, &entities.UpdateGoalRequest{TargetAmount: &target})
+
+	require.NoError(t, err)
+	assert.Equal(t, "200", updated.Goal.PlannedMonthly.String())
+}
+
+func TestSweep_NudgesUsersBehindPlanOncePerCooldown(t *testing.T) {
+	f := newFixture(zeroReturns())
+	user := uuid.New()
+	_, err := f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name: "Emergency fund", TargetAmount: decimal.NewFromInt(1200), TargetDate: start.AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+
+	// Six months in, the plan expects 600 but nothing has been invested
+	f.now = start.AddDate(0, 6, 0)
+	report, err := f.svc.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Nudged)
+	require.Len(t, f.notifier.sent, 1)
+	assert.Equal(t, entities.NotificationTypePortfolio, f.notifier.sent[0].Type)
+
+	f.now = f.now.Add(24 * time.Hour)
+	report, err = f.svc.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Nudged)
+
+	f.now = f.now.Add(7 * 24 * time.Hour)
+	report, err = f.svc.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Nudged)
+}
+
+func TestSweep_OnTrackUsersAreLeftAlone(t *testing.T) {
+	f := newFixture(zeroReturns())
+	user := uuid.New()
+	_, err := f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+		Name: "Emergency fund", TargetAmount: decimal.NewFromInt(1200), TargetDate: start.AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+
+	f.now = start.AddDate(0, 6, 0)
+	f.positions.values[user] = decimal.NewFromInt(590)
+	report, err := f.svc.Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Equal(t, 0, report.Nudged)
+	assert.Empty(t, f.notifier.sent)
+}
+
+func TestSweep_MarksReachedGoalsAchieved(t *testing.T) {
+	f := newFixture(goals.Config{BatchSize: 1})
+	user := uuid.New()
+	for _, target := range []int64{1000, 5000} {
+		_, err := f.svc.Create(context.Background(), user, &entities.CreateGoalRequest{
+			Name: "Goal", TargetAmount: decimal.NewFromInt(target), TargetDate: start.AddDate(1, 0, 0),
+		})
+		require.NoError(t, err)
+	}
+
+	f.now = start.AddDate(0, 1, 0)
+	f.positions.values[user] = decimal.NewFromInt(1500)
+	report, err := f.svc.Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, 1, report.Achieved)
+	list, err := f.svc.List(context.Background(), user)
+	require.NoError(t, err)
+	statuses := map[string]entities.GoalStatus{}
+	for _, progress := range list {
+		statuses[progress.Goal.TargetAmount.String()] = progress.Goal.Status
+	}
+	assert.Equal(t, entities.GoalStatusAchieved, statuses["1000"])
+	assert.Equal(t, entities.GoalStatusActive, statuses["5000"])
+}
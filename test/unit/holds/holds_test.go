@@ -0,0 +1,288 @@
+package holds_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/holds"
+)
+
+type fakeRepo struct {
+	mu          sync.Mutex
+	buyingPower map[uuid.UUID]decimal.Decimal
+	holds       map[uuid.UUID]*entities.BalanceHold
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		buyingPower: map[uuid.UUID]decimal.Decimal{},
+		holds:       map[uuid.UUID]*entities.BalanceHold{},
+	}
+}
+
+func (f *fakeRepo) Place(ctx context.Context, hold *entities.BalanceHold) (*entities.BalanceHold, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.holds {
+		if existing.Kind == hold.Kind && existing.ReferenceID == hold.ReferenceID {
+			copied := *existing
+			return &copied, false, nil
+		}
+	}
+	if f.buyingPower[hold.UserID].LessThan(hold.Amount) {
+		return nil, false, entities.ErrInsufficientBuyingPower
+	}
+	f.buyingPower[hold.UserID] = f.buyingPower[hold.UserID].Sub(hold.Amount)
+	stored := *hold
+	f.holds[hold.ID] = &stored
+	copied := stored
+	return &copied, true, nil
+}
+
+func (f *fakeRepo) Settle(ctx context.Context, id uuid.UUID, status entities.HoldStatus, at time.Time) (*entities.BalanceHold, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	hold, ok := f.holds[id]
+	if !ok {
+		return nil, false, entities.ErrBalanceHoldNotFound
+	}
+	if hold.Status != entities.HoldStatusActive {
+		copied := *hold
+		return &copied, false, nil
+	}
+	hold.Status = status
+	hold.SettledAt = &at
+	if status == entities.HoldStatusReleased {
+		f.buyingPower[hold.UserID] = f.buyingPower[hold.UserID].Add(hold.Amount)
+	}
+	copied := *hold
+	return &copied, true, nil
+}
+
+func (f *fakeRepo) GetByReference(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, hold := range f.holds {
+		if hold.Kind == kind && hold.ReferenceID == referenceID {
+			copied := *hold
+			return &copied, nil
+		}
+	}
+	return nil, entities.ErrBalanceHoldNotFound
+}
+
+func (f *fakeRepo) ListActive(ctx context.Context, userID uuid.UUID) ([]*entities.BalanceHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var active []*entities.BalanceHold
+	for _, hold := range f.holds {
+		if hold.UserID == userID && hold.Status == entities.HoldStatusActive {
+			copied := *hold
+			active = append(active, &copied)
+		}
+	}
+	return active, nil
+}
+
+func (f *fakeRepo) SetLedgerTransaction(ctx context.Context, id, transactionID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holds[id].LedgerTransactionID = &transactionID
+	return nil
+}
+
+type fakeLedger struct {
+	accounts     map[entities.AccountType]uuid.UUID
+	transactions []*entities.CreateTransactionRequest
+	err          error
+}
+
+func newFakeLedger() *fakeLedger {
+	return &fakeLedger{accounts: map[entities.AccountType]uuid.UUID{}}
+}
+
+func (f *fakeLedger) account(accountType entities.AccountType) *entities.LedgerAccount {
+	id, ok := f.accounts[accountType]
+	if !ok {
+		id = uuid.New()
+		f.accounts[accountType] = id
+	}
+	return &entities.LedgerAccount{ID: id, AccountType: accountType}
+}
+
+func (f *fakeLedger) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return f.account(accountType), nil
+}
+
+func (f *fakeLedger) GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return f.account(accountType), nil
+}
+
+func (f *fakeLedger) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.transactions = append(f.transactions, req)
+	return &entities.LedgerTransaction{ID: uuid.New()}, nil
+}
+
+// debited returns the account each transaction debited
+func (f *fakeLedger) debited() []uuid.UUID {
+	var accounts []uuid.UUID
+	for _, tx := range f.transactions {
+		for _, entry := range tx.Entries {
+			if entry.EntryType == entities.EntryTypeDebit {
+				accounts = append(accounts, entry.AccountID)
+			}
+		}
+	}
+	return accounts
+}
+
+func TestPlace_ConcurrentHoldsCannotOverspend(t *testing.T) {
+	repo := newFakeRepo()
+	svc := holds.NewService(repo, zap.NewNop())
+	userID := uuid.New()
+	repo.buyingPower[userID] = decimal.NewFromInt(100)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	placed, declined := 0, 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Place(context.Background(), userID, entities.HoldKindOrder, uuid.New(), decimal.NewFromInt(30))
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				placed++
+			} else if errors.Is(err, entities.ErrInsufficientBuyingPower) {
+				declined++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, placed)
+	assert.Equal(t, 7, declined)
+	assert.Equal(t, "10", repo.buyingPower[userID].String())
+
+	active, err := svc.ListActive(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Len(t, active.Holds, 3)
+	assert.Equal(t, "90", active.TotalHeld.String())
+}
+
+func TestPlace_IsIdempotentPerOperation(t *testing.T) {
+	repo := newFakeRepo()
+	svc := holds.NewService(repo, zap.NewNop())
+	userID, orderID := uuid.New(), uuid.New()
+	repo.buyingPower[userID] = decimal.NewFromInt(100)
+
+	first, err := svc.Place(context.Background(), userID, entities.HoldKindOrder, orderID, decimal.NewFromInt(40))
+	require.NoError(t, err)
+	again, err := svc.Place(context.Background(), userID, entities.HoldKindOrder, orderID, decimal.NewFromInt(40))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, "60", repo.buyingPower[userID].String())
+
+	_, err = svc.Place(context.Background(), userID, entities.HoldKindOrder, uuid.New(), decimal.Zero)
+	assert.Error(t, err)
+}
+
+func TestSettle_ReleaseRefundsAndCaptureKeeps(t *testing.T) {
+	repo := newFakeRepo()
+	svc := holds.NewService(repo, zap.NewNop())
+	ctx := context.Background()
+	userID, released, captured := uuid.New(), uuid.New(), uuid.New()
+	repo.buyingPower[userID] = decimal.NewFromInt(100)
+
+	_, err := svc.Place(ctx, userID, entities.HoldKindOrder, released, decimal.NewFromInt(30))
+	require.NoError(t, err)
+	_, err = svc.Place(ctx, userID, entities.HoldKindWithdrawal, captured, decimal.NewFromInt(50))
+	require.NoError(t, err)
+
+	hold, err := svc.Release(ctx, entities.HoldKindOrder, released)
+	require.NoError(t, err)
+	assert.Equal(t, entities.HoldStatusReleased, hold.Status)
+	_, err = svc.Release(ctx, entities.HoldKindOrder, released)
+	require.NoError(t, err, "releasing twice is a no-op")
+	assert.Equal(t, "50", repo.buyingPower[userID].String())
+
+	hold, err = svc.Capture(ctx, entities.HoldKindWithdrawal, captured)
+	require.NoError(t, err)
+	assert.Equal(t, entities.HoldStatusCaptured, hold.Status)
+	assert.Equal(t, "50", repo.buyingPower[userID].String())
+
+	_, err = svc.Release(ctx, entities.HoldKindWithdrawal, captured)
+	assert.ErrorIs(t, err, entities.ErrBalanceHoldSettled)
+	_, err = svc.Capture(ctx, entities.HoldKindOrder, uuid.New())
+	assert.ErrorIs(t, err, entities.ErrBalanceHoldNotFound)
+
+	active, err := svc.ListActive(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, active.Holds)
+	assert.True(t, active.TotalHeld.IsZero())
+}
+
+func TestLedger_MirrorsHoldsIntoHeldBalance(t *testing.T) {
+	repo, l := newFakeRepo(), newFakeLedger()
+	svc := holds.NewService(repo, zap.NewNop())
+	svc.SetLedger(l)
+	ctx := context.Background()
+	userID, orderID, withdrawalID := uuid.New(), uuid.New(), uuid.New()
+	repo.buyingPower[userID] = decimal.NewFromInt(100)
+
+	hold, err := svc.Place(ctx, userID, entities.HoldKindOrder, orderID, decimal.NewFromInt(30))
+	require.NoError(t, err)
+	require.NotNil(t, hold.LedgerTransactionID)
+	_, err = svc.Capture(ctx, entities.HoldKindOrder, orderID)
+	require.NoError(t, err)
+
+	_, err = svc.Place(ctx, userID, entities.HoldKindWithdrawal, withdrawalID, decimal.NewFromInt(20))
+	require.NoError(t, err)
+	_, err = svc.Capture(ctx, entities.HoldKindWithdrawal, withdrawalID)
+	require.NoError(t, err)
+
+	require.Len(t, l.transactions, 4)
+	for _, tx := range l.transactions {
+		assert.Equal(t, entities.TransactionTypeBalanceHold, tx.TransactionType)
+	}
+	assert.Equal(t, []uuid.UUID{
+		l.accounts[entities.AccountTypeHeldBalance],
+		l.accounts[entities.AccountTypeFiatExposure],
+		l.accounts[entities.AccountTypeHeldBalance],
+		l.accounts[entities.AccountTypeSystemBufferUSDC],
+	}, l.debited())
+}
+
+func TestLedger_HoldsNotPostedAreNotSettledThere(t *testing.T) {
+	repo, l := newFakeRepo(), newFakeLedger()
+	svc := holds.NewService(repo, zap.NewNop())
+	svc.SetLedger(l)
+	ctx := context.Background()
+	userID, orderID := uuid.New(), uuid.New()
+	repo.buyingPower[userID] = decimal.NewFromInt(100)
+
+	l.err = errors.New("insufficient balance")
+	hold, err := svc.Place(ctx, userID, entities.HoldKindOrder, orderID, decimal.NewFromInt(30))
+	require.NoError(t, err, "the ledger mirror is best effort")
+	assert.Nil(t, hold.LedgerTransactionID)
+
+	l.err = nil
+	_, err = svc.Release(ctx, entities.HoldKindOrder, orderID)
+	require.NoError(t, err)
+	assert.Empty(t, l.transactions)
+	assert.Equal(t, "100", repo.buyingPower[userID].String())
+}
@@ -0,0 +1,275 @@
+package hotcache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/hotcache"
+)
+
+// memoryStore mimics the Redis client: values are stored as JSON and
+// counters as plain integers
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{data: map[string][]byte{}}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.data[key]
+	if !ok {
+		return fmt.Errorf("key '%s' not found", key)
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = raw
+	return nil
+}
+
+func (s *memoryStore) Del(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryStore) Incr(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	if raw, ok := s.data[key]; ok {
+		_ = json.Unmarshal(raw, &n)
+	}
+	n++
+	s.data[key] = []byte(fmt.Sprint(n))
+	return n, nil
+}
+
+func (s *memoryStore) Expire(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func (s *memoryStore) Keys(_ context.Context, pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := strings.TrimSuffix(pattern, "*")
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *memoryStore) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.data[key]
+	return ok
+}
+
+// loaders counts loads and tracks how many run at once
+type loaders struct {
+	balanceLoads atomic.Int32
+	basketLoads  atomic.Int32
+	inFlight     atomic.Int32
+	maxInFlight  atomic.Int32
+	baskets      map[uuid.UUID]*entities.Basket
+	failUser     uuid.UUID
+}
+
+func (l *loaders) enter() func() {
+	n := l.inFlight.Add(1)
+	for {
+		max := l.maxInFlight.Load()
+		if n <= max || l.maxInFlight.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+	return func() { l.inFlight.Add(-1) }
+}
+
+func (l *loaders) GetBalance(_ context.Context, userID uuid.UUID) (*entities.BalancesResponse, error) {
+	defer l.enter()()
+	l.balanceLoads.Add(1)
+	if userID == l.failUser {
+		return nil, errors.New("circle unavailable")
+	}
+	return &entities.BalancesResponse{BuyingPower: "125.50", Currency: "USD"}, nil
+}
+
+func (l *loaders) GetPortfolio(_ context.Context, _ uuid.UUID) (*entities.Portfolio, error) {
+	defer l.enter()()
+	return &entities.Portfolio{Currency: "USD", TotalValue: "300.00"}, nil
+}
+
+func (l *loaders) GetByID(_ context.Context, id uuid.UUID) (*entities.Basket, error) {
+	defer l.enter()()
+	l.basketLoads.Add(1)
+	return l.baskets[id], nil
+}
+
+type activeUsers []uuid.UUID
+
+func (a activeUsers) MostActiveUsers(_ context.Context, _ time.Time, limit int) ([]uuid.UUID, error) {
+	if len(a) > limit {
+		return a[:limit], nil
+	}
+	return a, nil
+}
+
+func TestReadThroughAndForget(t *testing.T) {
+	store := newMemoryStore()
+	l := &loaders{baskets: map[uuid.UUID]*entities.Basket{}}
+	service := hotcache.NewService(store, l, l, l, nil, hotcache.DefaultConfig(), zap.NewNop())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		balances, err := service.Balances(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "125.50", balances.BuyingPower)
+	}
+	assert.EqualValues(t, 1, l.balanceLoads.Load())
+
+	portfolio, err := service.Portfolio(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "300.00", portfolio.TotalValue)
+
+	service.Forget(ctx, userID)
+	_, err = service.Balances(ctx, userID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, l.balanceLoads.Load())
+
+	// Missing baskets are not cached, so a basket created later is found
+	basketID := uuid.New()
+	basket, err := service.Basket(ctx, basketID)
+	require.NoError(t, err)
+	assert.Nil(t, basket)
+	l.baskets[basketID] = &entities.Basket{ID: basketID, Name: "Tech", Composition: []entities.BasketComponent{{Symbol: "AAPL", Weight: decimal.NewFromFloat(1)}}}
+	basket, err = service.Basket(ctx, basketID)
+	require.NoError(t, err)
+	require.NotNil(t, basket)
+	assert.Equal(t, "Tech", basket.Name)
+	_, err = service.Basket(ctx, basketID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, l.basketLoads.Load())
+}
+
+func TestWarmLoadsActiveUsersAndMostViewedBaskets(t *testing.T) {
+	store := newMemoryStore()
+	popular, quiet, unseen := uuid.New(), uuid.New(), uuid.New()
+	l := &loaders{baskets: map[uuid.UUID]*entities.Basket{
+		popular: {ID: popular, Name: "Popular"},
+		quiet:   {ID: quiet, Name: "Quiet"},
+		unseen:  {ID: unseen, Name: "Unseen"},
+	}}
+	users := make(activeUsers, 20)
+	for i := range users {
+		users[i] = uuid.New()
+	}
+	l.failUser = users[3]
+
+	config := hotcache.DefaultConfig()
+	config.MaxBaskets = 1
+	config.MaxUsers = 10
+	config.Concurrency = 3
+	service := hotcache.NewService(store, l, l, l, users, config, zap.NewNop())
+	ctx := context.Background()
+
+	// Views from earlier requests rank the baskets
+	for i := 0; i < 3; i++ {
+		_, err := service.Basket(ctx, popular)
+		require.NoError(t, err)
+	}
+	_, err := service.Basket(ctx, quiet)
+	require.NoError(t, err)
+	for _, id := range []uuid.UUID{popular, quiet} {
+		require.NoError(t, store.Del(ctx, fmt.Sprintf("basket:%s", id)))
+	}
+	l.basketLoads.Store(0)
+
+	assert.Nil(t, service.LastWarm())
+	report, err := service.Warm(ctx, entities.CacheWarmTriggerStartup)
+	require.NoError(t, err)
+	assert.False(t, report.Running)
+	require.NotNil(t, report.FinishedAt)
+	assert.Equal(t, 9, report.Users)
+	assert.Equal(t, 1, report.Baskets)
+	assert.Equal(t, 1, report.Failed)
+	assert.LessOrEqual(t, l.maxInFlight.Load(), int32(3))
+
+	assert.True(t, store.has(fmt.Sprintf("basket:%s", popular)))
+	assert.False(t, store.has(fmt.Sprintf("basket:%s", quiet)))
+	assert.True(t, store.has(fmt.Sprintf("balance:%s:summary", users[0])))
+	assert.True(t, store.has(fmt.Sprintf("portfolio:%s:overview", users[9])))
+	assert.False(t, store.has(fmt.Sprintf("balance:%s:summary", users[10])))
+
+	last := service.LastWarm()
+	require.NotNil(t, last)
+	assert.Equal(t, entities.CacheWarmTriggerStartup, last.Trigger)
+	assert.Equal(t, 9, last.Users)
+}
+
+// blockingUsers holds the warm-up open until released
+type blockingUsers struct {
+	release chan struct{}
+}
+
+func (b blockingUsers) MostActiveUsers(context.Context, time.Time, int) ([]uuid.UUID, error) {
+	<-b.release
+	return nil, nil
+}
+
+func TestWarmRunsOneAtATime(t *testing.T) {
+	users := blockingUsers{release: make(chan struct{})}
+	l := &loaders{}
+	service := hotcache.NewService(newMemoryStore(), l, l, l, users, hotcache.DefaultConfig(), zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, service.WarmInBackground(ctx, entities.CacheWarmTriggerAdmin))
+	running := service.LastWarm()
+	require.NotNil(t, running)
+	assert.True(t, running.Running)
+
+	assert.ErrorIs(t, service.WarmInBackground(ctx, entities.CacheWarmTriggerAdmin), entities.ErrCacheWarmRunning)
+	_, err := service.Warm(ctx, entities.CacheWarmTriggerAdmin)
+	assert.ErrorIs(t, err, entities.ErrCacheWarmRunning)
+
+	close(users.release)
+	require.Eventually(t, func() bool {
+		last := service.LastWarm()
+		return last != nil && !last.Running
+	}, time.Second, 5*time.Millisecond)
+	_, err = service.Warm(ctx, entities.CacheWarmTriggerAdmin)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,204 @@
+package httpcapture_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/httpcapture"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	captures []*entities.HTTPCapture
+	targets  []*entities.HTTPCaptureTarget
+}
+
+func (s *fakeStore) SaveCapture(ctx context.Context, capture *entities.HTTPCapture) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.captures = append(s.captures, capture)
+	return nil
+}
+
+func (s *fakeStore) saved() []*entities.HTTPCapture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*entities.HTTPCapture(nil), s.captures...)
+}
+
+func (s *fakeStore) GetCapture(ctx context.Context, id uuid.UUID) (*entities.HTTPCapture, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) ListCaptures(ctx context.Context, filter entities.HTTPCaptureFilter) ([]*entities.HTTPCapture, error) {
+	return s.saved(), nil
+}
+
+func (s *fakeStore) CreateTarget(ctx context.Context, target *entities.HTTPCaptureTarget) error {
+	s.targets = append(s.targets, target)
+	return nil
+}
+
+func (s *fakeStore) DeleteTarget(ctx context.Context, id uuid.UUID) (bool, error) {
+	for i, target := range s.targets {
+		if target.ID == id {
+			s.targets = append(s.targets[:i], s.targets[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *fakeStore) ListActiveTargets(ctx context.Context) ([]*entities.HTTPCaptureTarget, error) {
+	return s.targets, nil
+}
+
+func TestSanitizeBody_MasksCredentialsAndPersonalData(t *testing.T) {
+	logger.SetRedaction(true)
+	defer logger.SetRedaction(false)
+
+	body := []byte(`{
+		"email": "ada@example.com",
+		"password": "hunter2",
+		"first_name": "Ada",
+		"amount": "25.00",
+		"wallet": {"address": "0x1234567890abcdef1234567890abcdef12345678", "chain": "SOL"},
+		"otp_code": "123456",
+		"legs": [{"symbol": "VTI", "accessToken": "abc"}]
+	}`)
+
+	sanitized := httpcapture.SanitizeBody("application/json", body, false)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(sanitized), &out))
+	assert.Equal(t, "[REDACTED]", out["password"])
+	assert.Equal(t, "[REDACTED]", out["first_name"])
+	assert.Equal(t, "[REDACTED]", out["otp_code"])
+	assert.Equal(t, "25.00", out["amount"])
+	assert.NotContains(t, out["email"], "ada@")
+	wallet := out["wallet"].(map[string]interface{})
+	assert.Equal(t, "0x1234...5678", wallet["address"])
+	assert.Equal(t, "SOL", wallet["chain"])
+	leg := out["legs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "VTI", leg["symbol"])
+	assert.Equal(t, "[REDACTED]", leg["accessToken"])
+}
+
+func TestSanitizeBody_DescribesBodiesItCannotMask(t *testing.T) {
+	assert.Equal(t, "[multipart/form-data body, 5 bytes]",
+		httpcapture.SanitizeBody("multipart/form-data; boundary=x", []byte("abcde"), false))
+	assert.Equal(t, "[JSON body over capture limit, 8+ bytes]",
+		httpcapture.SanitizeBody("application/json", []byte(`{"pin":"`), true))
+	assert.Equal(t, "[unparseable JSON body, 7 bytes]", httpcapture.SanitizeBody("application/json", []byte(`{"pin":`), false))
+	assert.Empty(t, httpcapture.SanitizeBody("application/json", nil, false))
+}
+
+func TestSanitizeHeadersAndQuery(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	header.Set("Cookie", "session=abc")
+	header.Set("X-Api-Key", "sk_live")
+	header.Set("Idempotency-Key", "order-42")
+	header.Set("Content-Type", "application/json")
+
+	sanitized := httpcapture.SanitizeHeaders(header)
+	assert.Equal(t, "[REDACTED]", sanitized["Authorization"])
+	assert.Equal(t, "[REDACTED]", sanitized["Cookie"])
+	assert.Equal(t, "[REDACTED]", sanitized["X-Api-Key"])
+	assert.Equal(t, "order-42", sanitized["Idempotency-Key"])
+	assert.Equal(t, "application/json", sanitized["Content-Type"])
+
+	assert.Equal(t, "limit=10&token=%5BREDACTED%5D", httpcapture.SanitizeQuery("token=abc&limit=10"))
+}
+
+func TestDecide_TargetsAlwaysCaptureAndSamplingIsOffByDefault(t *testing.T) {
+	store := &fakeStore{}
+	service := httpcapture.NewService(store, httpcapture.Config{Enabled: true}, zap.NewNop())
+	userID := uuid.New()
+
+	assert.False(t, service.Active())
+	_, ok := service.Decide(&userID, "/api/v1/orders")
+	assert.False(t, ok)
+
+	_, err := service.AddTarget(context.Background(), uuid.New(), &entities.CreateHTTPCaptureTargetRequest{Note: "no route"})
+	assert.ErrorIs(t, err, httpcapture.ErrInvalidTarget)
+
+	target, err := service.AddTarget(context.Background(), uuid.New(), &entities.CreateHTTPCaptureTargetRequest{
+		UserID: &userID,
+		Note:   "ticket 512: deposits not showing",
+	})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), target.ExpiresAt, time.Minute)
+	assert.True(t, service.Active())
+
+	reason, ok := service.Decide(&userID, "/api/v1/orders")
+	assert.True(t, ok)
+	assert.Equal(t, entities.HTTPCaptureUser, reason)
+	other := uuid.New()
+	_, ok = service.Decide(&other, "/api/v1/orders")
+	assert.False(t, ok)
+
+	_, err = service.AddTarget(context.Background(), uuid.New(), &entities.CreateHTTPCaptureTargetRequest{
+		Route: "/api/v1/funding/deposits/:id",
+		Note:  "webhook lag",
+	})
+	require.NoError(t, err)
+	reason, ok = service.Decide(nil, "/api/v1/funding/deposits/:id")
+	assert.True(t, ok)
+	assert.Equal(t, entities.HTTPCaptureRoute, reason)
+
+	require.NoError(t, service.RemoveTarget(context.Background(), target.ID))
+	_, ok = service.Decide(&userID, "/api/v1/orders")
+	assert.False(t, ok, "removing a target stops capture on this instance at once")
+	assert.ErrorIs(t, service.RemoveTarget(context.Background(), target.ID), httpcapture.ErrTargetNotFound)
+}
+
+func TestRecord_SanitizesAndWritesInBackground(t *testing.T) {
+	store := &fakeStore{}
+	service := httpcapture.NewService(store, httpcapture.Config{
+		Enabled:       true,
+		SamplePercent: 100,
+		TTL:           time.Hour,
+	}, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.Start(ctx)
+
+	reason, ok := service.Decide(nil, "/api/v1/auth/login")
+	require.True(t, ok)
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	service.Record(&httpcapture.Exchange{
+		Capture: entities.HTTPCapture{
+			Method:     http.MethodPost,
+			Route:      "/api/v1/auth/login",
+			Path:       "/api/v1/auth/login",
+			StatusCode: http.StatusOK,
+			Reason:     reason,
+		},
+		Header:              header,
+		RequestBody:         []byte(`{"password":"hunter2"}`),
+		RequestContentType:  "application/json",
+		ResponseBody:        []byte(`{"accessToken":"eyJ","expiresIn":900}`),
+		ResponseContentType: "application/json; charset=utf-8",
+	})
+
+	require.Eventually(t, func() bool { return len(store.saved()) == 1 }, time.Second, 10*time.Millisecond)
+	capture := store.saved()[0]
+	assert.Equal(t, entities.HTTPCaptureSampled, capture.Reason)
+	assert.Equal(t, `{"password":"[REDACTED]"}`, capture.RequestBody)
+	assert.Equal(t, `{"accessToken":"[REDACTED]","expiresIn":900}`, capture.ResponseBody)
+	assert.Equal(t, "[REDACTED]", capture.RequestHeaders["Authorization"])
+	assert.Equal(t, time.Hour, capture.ExpiresAt.Sub(capture.CreatedAt))
+}
@@ -0,0 +1,107 @@
+package inactivity_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/inactivity"
+)
+
+func TestMonthsBetween(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0, inactivity.MonthsBetween(start, time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, inactivity.MonthsBetween(start, time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 12, inactivity.MonthsBetween(start, time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, inactivity.MonthsBetween(start, start.AddDate(0, 0, -5)))
+}
+
+func TestDueStage(t *testing.T) {
+	reminders := []int{6, 9, 11}
+	assert.Equal(t, 0, inactivity.DueStage(5, reminders))
+	assert.Equal(t, 1, inactivity.DueStage(6, reminders))
+	assert.Equal(t, 2, inactivity.DueStage(10, reminders))
+	assert.Equal(t, 3, inactivity.DueStage(30, reminders))
+}
+
+type fakeRepo struct {
+	accounts []*entities.DormantAccount
+	saved    map[uuid.UUID]*entities.AccountInactivity
+}
+
+func (f *fakeRepo) ListDormantFunded(ctx context.Context, cutoff time.Time, afterID uuid.UUID, limit int) ([]*entities.DormantAccount, error) {
+	if afterID != uuid.Nil {
+		return nil, nil
+	}
+	return f.accounts, nil
+}
+
+func (f *fakeRepo) SaveState(ctx context.Context, state *entities.AccountInactivity) error {
+	f.saved[state.UserID] = state
+	return nil
+}
+
+type fakeCases struct{ opened int }
+
+func (f *fakeCases) Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error) {
+	f.opened++
+	return &entities.AdminCase{ID: uuid.New(), UserID: userID, CaseType: caseType}, nil
+}
+
+type fakeContacts struct{ email string }
+
+func (f fakeContacts) Get(ctx context.Context, userID uuid.UUID) (*entities.TrustedContact, error) {
+	return &entities.TrustedContact{UserID: userID, FirstName: "Sam", Email: &f.email}, nil
+}
+
+type fakeMailer struct{ sent []string }
+
+func (f *fakeMailer) SendCustomEmail(ctx context.Context, to, subject, htmlContent, textContent string) error {
+	f.sent = append(f.sent, to)
+	return nil
+}
+
+func TestRun_EscalatesAndOpensCase(t *testing.T) {
+	now := time.Now()
+	recent := &entities.DormantAccount{UserID: uuid.New(), Email: "recent@example.com", LastActivityAt: now.AddDate(0, -7, 0)}
+	dormant := &entities.DormantAccount{UserID: uuid.New(), Email: "dormant@example.com", LastActivityAt: now.AddDate(0, -13, 0)}
+	repo := &fakeRepo{accounts: []*entities.DormantAccount{recent, dormant}, saved: map[uuid.UUID]*entities.AccountInactivity{}}
+	caseOpener := &fakeCases{}
+	mailer := &fakeMailer{}
+
+	svc := inactivity.NewService(repo, fakeContacts{email: "contact@example.com"}, caseOpener, mailer, nil, inactivity.DefaultConfig(), zap.NewNop())
+
+	dry, err := svc.Run(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, dry.Reminders)
+	assert.Equal(t, 1, dry.CasesOpened)
+	assert.Empty(t, mailer.sent)
+	assert.Empty(t, repo.saved)
+	assert.Zero(t, caseOpener.opened)
+
+	report, err := svc.Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Scanned)
+	assert.Equal(t, 1, report.CasesOpened)
+	assert.Equal(t, 1, caseOpener.opened)
+	assert.ElementsMatch(t, []string{"recent@example.com", "dormant@example.com", "contact@example.com"}, mailer.sent)
+	assert.Equal(t, 1, repo.saved[recent.UserID].Stage)
+	assert.Equal(t, 3, repo.saved[dormant.UserID].Stage)
+	assert.NotNil(t, repo.saved[dormant.UserID].CaseID)
+
+	// A second pass with the saved state sends nothing new
+	recent.State = repo.saved[recent.UserID]
+	dormant.State = repo.saved[dormant.UserID]
+	mailer.sent = nil
+	again, err := svc.Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Zero(t, again.Reminders)
+	assert.Zero(t, again.CasesOpened)
+	assert.Empty(t, mailer.sent)
+}
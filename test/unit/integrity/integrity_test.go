@@ -0,0 +1,226 @@
+package integrity_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/integrity"
+)
+
+type fakeRepo struct {
+	anomalies map[entities.IntegrityAnomalyClass][]*entities.IntegrityAnomaly
+	failing   map[entities.IntegrityAnomalyClass]error
+	runs      []*entities.IntegrityReport
+	dates     map[string]bool
+	before    time.Time
+	limits    []int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		anomalies: map[entities.IntegrityAnomalyClass][]*entities.IntegrityAnomaly{},
+		failing:   map[entities.IntegrityAnomalyClass]error{},
+		dates:     map[string]bool{},
+	}
+}
+
+func (r *fakeRepo) FindAnomalies(_ context.Context, class entities.IntegrityAnomalyClass, before time.Time, limit int) ([]*entities.IntegrityAnomaly, error) {
+	r.before = before
+	r.limits = append(r.limits, limit)
+	if err := r.failing[class]; err != nil {
+		return nil, err
+	}
+	found := r.anomalies[class]
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r *fakeRepo) StartRun(_ context.Context, report *entities.IntegrityReport) (bool, error) {
+	if report.RunDate != nil {
+		if r.dates[*report.RunDate] {
+			return false, nil
+		}
+		r.dates[*report.RunDate] = true
+	}
+	r.runs = append(r.runs, report)
+	return true, nil
+}
+
+func (r *fakeRepo) FinishRun(context.Context, *entities.IntegrityReport) error { return nil }
+
+func (r *fakeRepo) GetRun(_ context.Context, id uuid.UUID) (*entities.IntegrityReport, error) {
+	for _, run := range r.runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, entities.ErrIntegrityRunNotFound
+}
+
+func (r *fakeRepo) ListRuns(_ context.Context, limit int) ([]*entities.IntegrityReport, error) {
+	var runs []*entities.IntegrityReport
+	for _, run := range r.runs {
+		copied := *run
+		copied.Checks = nil
+		for _, check := range run.Checks {
+			checkCopy := *check
+			copied.Checks = append(copied.Checks, &checkCopy)
+		}
+		runs = append(runs, &copied)
+	}
+	return runs, nil
+}
+
+type openedCase struct {
+	userID   uuid.UUID
+	caseType entities.AdminCaseType
+	details  map[string]interface{}
+}
+
+type fakeCases struct {
+	opened []openedCase
+}
+
+func (f *fakeCases) Open(_ context.Context, userID uuid.UUID, caseType entities.AdminCaseType, _ string, details map[string]interface{}) (*entities.AdminCase, error) {
+	f.opened = append(f.opened, openedCase{userID, caseType, details})
+	return &entities.AdminCase{ID: uuid.New(), UserID: userID, CaseType: caseType}, nil
+}
+
+var now = time.Date(2026, 10, 16, 3, 30, 0, 0, time.UTC)
+
+func newService(repo *fakeRepo, cases *fakeCases, config integrity.Config) *integrity.Service {
+	var opener integrity.CaseOpener
+	if cases != nil {
+		opener = cases
+	}
+	service := integrity.NewService(repo, opener, config, zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	return service
+}
+
+func anomaly(class entities.IntegrityAnomalyClass, userID uuid.UUID, subjectType, subjectID string) *entities.IntegrityAnomaly {
+	return &entities.IntegrityAnomaly{Class: class, UserID: userID, SubjectType: subjectType, SubjectID: subjectID, Since: now.Add(-72 * time.Hour)}
+}
+
+func TestRunReportsEveryClassAndOpensCasePerUser(t *testing.T) {
+	repo := newFakeRepo()
+	cases := &fakeCases{}
+	service := newService(repo, cases, integrity.DefaultConfig())
+
+	user := uuid.New()
+	other := uuid.New()
+	repo.anomalies[entities.IntegrityOrderWithoutLedger] = []*entities.IntegrityAnomaly{
+		anomaly(entities.IntegrityOrderWithoutLedger, user, "order", "order-1"),
+		anomaly(entities.IntegrityOrderWithoutLedger, user, "order", "order-2"),
+		anomaly(entities.IntegrityOrderWithoutLedger, other, "order", "order-3"),
+	}
+	repo.anomalies[entities.IntegrityCompletedWithoutWallet] = []*entities.IntegrityAnomaly{
+		anomaly(entities.IntegrityCompletedWithoutWallet, other, "user", other.String()),
+	}
+
+	report, err := service.RunNow(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entities.IntegrityRunManual, report.Trigger)
+	assert.Equal(t, 4, report.Anomalies)
+	require.Len(t, report.Checks, len(entities.IntegrityAnomalyClasses()))
+	assert.Equal(t, now.Add(-24*time.Hour), repo.before, "records inside the grace period are not checked")
+
+	byClass := map[entities.IntegrityAnomalyClass]*entities.IntegrityCheckResult{}
+	for _, check := range report.Checks {
+		byClass[check.Class] = check
+	}
+	orders := byClass[entities.IntegrityOrderWithoutLedger]
+	assert.Equal(t, 3, orders.Count)
+	assert.Equal(t, 2, orders.Cases)
+	assert.Equal(t, 1, byClass[entities.IntegrityCompletedWithoutWallet].Cases)
+	assert.Zero(t, byClass[entities.IntegrityKYCApprovedStuck].Count)
+
+	require.Len(t, cases.opened, 3)
+	assert.Equal(t, user, cases.opened[1].userID)
+	assert.Equal(t, entities.AdminCaseType("integrity_order_without_ledger"), cases.opened[1].caseType)
+	assert.Equal(t, []string{"order-1", "order-2"}, cases.opened[1].details["subjects"])
+}
+
+func TestRunTruncatesAndSkipsCasesWhenDisabled(t *testing.T) {
+	repo := newFakeRepo()
+	cases := &fakeCases{}
+	config := integrity.DefaultConfig()
+	config.MaxPerCheck = 2
+	config.OpenCases = false
+	service := newService(repo, cases, config)
+
+	for i := 0; i < 5; i++ {
+		repo.anomalies[entities.IntegrityWalletWithoutWalletSet] = append(repo.anomalies[entities.IntegrityWalletWithoutWalletSet],
+			anomaly(entities.IntegrityWalletWithoutWalletSet, uuid.New(), "wallet", uuid.NewString()))
+	}
+
+	report, err := service.RunNow(context.Background(), uuid.New())
+	require.NoError(t, err)
+	for _, check := range report.Checks {
+		if check.Class == entities.IntegrityWalletWithoutWalletSet {
+			assert.Equal(t, 2, check.Count)
+			assert.True(t, check.Truncated)
+			assert.Len(t, check.Anomalies, 2)
+		}
+	}
+	assert.Contains(t, repo.limits, 3, "one extra row is read to detect truncation")
+	assert.Empty(t, cases.opened)
+}
+
+func TestFailedCheckDoesNotStopTheOthers(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo, nil, integrity.DefaultConfig())
+
+	repo.failing[entities.IntegrityCompletedWithoutWallet] = errors.New("statement timeout")
+	repo.anomalies[entities.IntegrityKYCApprovedStuck] = []*entities.IntegrityAnomaly{
+		anomaly(entities.IntegrityKYCApprovedStuck, uuid.New(), "user", "u"),
+	}
+
+	report, err := service.RunNow(context.Background(), uuid.New())
+	require.Error(t, err)
+	require.NotNil(t, report)
+	require.NotNil(t, report.Error)
+	assert.Contains(t, *report.Error, "statement timeout")
+	assert.Equal(t, 1, report.Anomalies)
+	assert.Equal(t, "statement timeout", report.Checks[0].Error)
+}
+
+func TestRunDueRunsOncePerNightAfterRunHour(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo, nil, integrity.DefaultConfig())
+	clock := time.Date(2026, 10, 16, 2, 45, 0, 0, time.UTC)
+	service.SetClock(func() time.Time { return clock })
+
+	report, err := service.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, report, "not due before the run hour")
+
+	clock = time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	report, err = service.RunDue(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, entities.IntegrityRunScheduled, report.Trigger)
+	assert.Equal(t, "2026-10-16", *report.RunDate)
+
+	clock = time.Date(2026, 10, 16, 3, 15, 0, 0, time.UTC)
+	report, err = service.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, report, "the night's run is claimed")
+
+	listed, err := service.List(context.Background(), 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	for _, check := range listed[0].Checks {
+		assert.Nil(t, check.Anomalies, "listings leave out the anomalies")
+	}
+}
@@ -0,0 +1,161 @@
+package investing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeBasketStore struct {
+	cartBaskets
+	stats   []entities.BasketVariantStats
+	holders int64
+}
+
+func (f *fakeBasketStore) Create(ctx context.Context, basket *entities.Basket) error {
+	f.baskets[basket.ID] = basket
+	return nil
+}
+
+func (f *fakeBasketStore) UpdateRegions(ctx context.Context, id uuid.UUID, regions []string) (bool, error) {
+	basket, ok := f.baskets[id]
+	if !ok {
+		return false, nil
+	}
+	basket.Regions = regions
+	return true, nil
+}
+
+func (f *fakeBasketStore) GetFamilyStats(ctx context.Context, parentID uuid.UUID) ([]entities.BasketVariantStats, int64, error) {
+	return f.stats, f.holders, nil
+}
+
+type fakeCountries map[uuid.UUID]string
+
+func (f fakeCountries) UserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	return f[userID], nil
+}
+
+func newBasketStore() (*fakeBasketStore, *entities.Basket) {
+	core := &entities.Basket{ID: uuid.New(), Name: "Core", Composition: []entities.BasketComponent{
+		{Symbol: "VTI", Weight: decimal.RequireFromString("0.6")},
+		{Symbol: "VXUS", Weight: decimal.RequireFromString("0.2")},
+		{Symbol: "BND", Weight: decimal.RequireFromString("0.2")},
+	}}
+	store := &fakeBasketStore{cartBaskets: cartBaskets{baskets: map[uuid.UUID]*entities.Basket{core.ID: core}}}
+	return store, core
+}
+
+func TestBasketLocalization_CloneAppliesReplacementsAndLinksToOriginal(t *testing.T) {
+	store, core := newBasketStore()
+	localization := investing.NewBasketLocalization(store, logger.NewLogger(zap.NewNop()))
+	ctx := context.Background()
+
+	// VTI and VXUS both map to the same UCITS fund, so their weights merge
+	eu, err := localization.Clone(ctx, core.ID, &entities.CloneBasketRequest{
+		Name:    "Core (EU)",
+		Regions: []string{"eu", "ch", "EU"},
+		Replacements: []entities.SymbolReplacement{
+			{From: "VTI", To: "vwce"},
+			{From: "VXUS", To: "VWCE"},
+			{From: "BND", To: "AGGH"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, eu.ParentBasketID)
+	assert.Equal(t, core.ID, *eu.ParentBasketID)
+	assert.Equal(t, []string{"EU", "CH"}, eu.Regions)
+	require.Len(t, eu.Composition, 2)
+	assert.Equal(t, "VWCE", eu.Composition[0].Symbol)
+	assert.True(t, eu.Composition[0].Weight.Equal(decimal.RequireFromString("0.8")))
+	assert.Equal(t, "AGGH", eu.Composition[1].Symbol)
+	assert.Len(t, core.Composition, 3, "the original basket is unchanged")
+
+	// A clone of a variant joins the original family
+	swiss, err := localization.Clone(ctx, eu.ID, &entities.CloneBasketRequest{Name: "Core (CH)", Regions: []string{"CH"}})
+	require.NoError(t, err)
+	assert.Equal(t, core.ID, *swiss.ParentBasketID)
+}
+
+func TestBasketLocalization_CloneRejectsBadInput(t *testing.T) {
+	store, core := newBasketStore()
+	localization := investing.NewBasketLocalization(store, logger.NewLogger(zap.NewNop()))
+	ctx := context.Background()
+
+	_, err := localization.Clone(ctx, core.ID, &entities.CloneBasketRequest{Name: "X", Regions: []string{"Europe"}})
+	assert.ErrorIs(t, err, entities.ErrInvalidRegion)
+
+	_, err = localization.Clone(ctx, core.ID, &entities.CloneBasketRequest{Name: "X",
+		Replacements: []entities.SymbolReplacement{{From: "QQQ", To: "EQQQ"}}})
+	assert.ErrorIs(t, err, entities.ErrInvalidComposition)
+
+	_, err = localization.Clone(ctx, core.ID, &entities.CloneBasketRequest{Name: "X",
+		Composition: []entities.BasketComponent{{Symbol: "VWCE", Weight: decimal.RequireFromString("0.9")}}})
+	assert.ErrorIs(t, err, entities.ErrInvalidComposition)
+
+	_, err = localization.Clone(ctx, uuid.New(), &entities.CloneBasketRequest{Name: "X"})
+	assert.ErrorIs(t, err, investing.ErrBasketNotFound)
+	assert.Len(t, store.baskets, 1)
+}
+
+func TestBasketLocalization_FamilyAnalyticsTotalsVariants(t *testing.T) {
+	store, core := newBasketStore()
+	variantID := uuid.New()
+	store.baskets[variantID] = &entities.Basket{ID: variantID, ParentBasketID: &core.ID, Regions: []string{"EU"}}
+	store.stats = []entities.BasketVariantStats{
+		{BasketID: core.ID, Orders: 10, AmountOrdered: decimal.NewFromInt(1000), Holders: 4},
+		{BasketID: variantID, Orders: 3, AmountOrdered: decimal.NewFromInt(250), Holders: 2},
+	}
+	store.holders = 5
+	localization := investing.NewBasketLocalization(store, logger.NewLogger(zap.NewNop()))
+
+	analytics, err := localization.FamilyAnalytics(context.Background(), variantID)
+	require.NoError(t, err)
+	assert.Equal(t, core.ID, analytics.ParentBasketID)
+	assert.Equal(t, int64(13), analytics.Orders)
+	assert.True(t, analytics.AmountOrdered.Equal(decimal.NewFromInt(1250)))
+	assert.Equal(t, int64(5), analytics.Holders)
+	assert.Len(t, analytics.Variants, 2)
+}
+
+func TestBaskets_ListedAndOrderedOnlyInTheirRegions(t *testing.T) {
+	f := newPreviewFixture()
+	f.basket.Regions = []string{"EU"}
+	global := &entities.Basket{ID: uuid.New(), Name: "Global", Composition: f.basket.Composition}
+	baskets := &cartBaskets{baskets: map[uuid.UUID]*entities.Basket{f.basket.ID: f.basket, global.ID: global}}
+
+	german, american, unknown := uuid.New(), uuid.New(), uuid.New()
+	service := investing.NewService(baskets, nil, f.positions, f.balances, nil, nil, nil, nil, nil, logger.NewLogger(zap.NewNop()))
+	service.SetQuoteProvider(f.quotes)
+	service.SetRegionResolver(fakeCountries{german: "de", american: "US"})
+	ctx := context.Background()
+
+	listed, err := service.ListBasketsForUser(ctx, german)
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+	for _, userID := range []uuid.UUID{american, unknown} {
+		listed, err = service.ListBasketsForUser(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, global.ID, listed[0].ID)
+	}
+
+	_, err = service.GetBasketForUser(ctx, american, f.basket.ID)
+	assert.ErrorIs(t, err, investing.ErrBasketNotFound)
+	_, err = service.PreviewOrder(ctx, american, &entities.OrderCreateRequest{BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100"})
+	assert.ErrorIs(t, err, investing.ErrBasketNotFound)
+	_, err = service.CreateOrder(ctx, american, &entities.OrderCreateRequest{BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100"})
+	assert.ErrorIs(t, err, investing.ErrBasketNotFound)
+
+	_, err = service.PreviewOrder(ctx, german, &entities.OrderCreateRequest{BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100"})
+	assert.NoError(t, err)
+}
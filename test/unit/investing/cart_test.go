@@ -0,0 +1,216 @@
+package investing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type cartBaskets struct {
+	baskets map[uuid.UUID]*entities.Basket
+}
+
+func (f *cartBaskets) GetAll(ctx context.Context) ([]*entities.Basket, error) {
+	var all []*entities.Basket
+	for _, basket := range f.baskets {
+		all = append(all, basket)
+	}
+	return all, nil
+}
+
+func (f *cartBaskets) GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error) {
+	return f.baskets[id], nil
+}
+
+type fakeCart struct {
+	items []*entities.CartItem
+}
+
+func (f *fakeCart) ListItems(ctx context.Context, userID uuid.UUID) ([]*entities.CartItem, error) {
+	out := make([]*entities.CartItem, 0, len(f.items))
+	for _, item := range f.items {
+		copied := *item
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func (f *fakeCart) UpsertItem(ctx context.Context, userID, basketID uuid.UUID, amount decimal.Decimal) error {
+	for _, item := range f.items {
+		if item.BasketID == basketID {
+			item.Amount = amount
+			return nil
+		}
+	}
+	f.items = append(f.items, &entities.CartItem{BasketID: basketID, Amount: amount, AddedAt: time.Now()})
+	return nil
+}
+
+func (f *fakeCart) RemoveItem(ctx context.Context, userID, basketID uuid.UUID) (bool, error) {
+	for i, item := range f.items {
+		if item.BasketID == basketID {
+			f.items = append(f.items[:i], f.items[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakePasscodes struct {
+	tokens map[string]bool
+}
+
+func (f *fakePasscodes) ValidateSession(ctx context.Context, userID uuid.UUID, token string) (bool, error) {
+	return f.tokens[token], nil
+}
+
+func (f *fakePasscodes) InvalidateSession(ctx context.Context, userID uuid.UUID, token string) error {
+	delete(f.tokens, token)
+	return nil
+}
+
+type cartFixture struct {
+	*previewFixture
+	service *investing.Service
+	cart    *fakeCart
+	orders  *fakeOrders
+	holds   *fakeHolds
+	second  *entities.Basket
+	userID  uuid.UUID
+}
+
+func newCartFixture(t *testing.T) *cartFixture {
+	t.Helper()
+	f := &cartFixture{
+		previewFixture: newPreviewFixture(),
+		cart:           &fakeCart{},
+		orders:         &fakeOrders{orders: make(map[uuid.UUID]*entities.Order)},
+		userID:         uuid.New(),
+	}
+	f.second = &entities.Basket{ID: uuid.New(), Name: "Bonds", Composition: []entities.BasketComponent{
+		{Symbol: "BND", Weight: decimal.NewFromInt(1)},
+	}}
+	f.holds = &fakeHolds{available: f.balances.buyingPower, held: make(map[uuid.UUID]decimal.Decimal)}
+	baskets := &cartBaskets{baskets: map[uuid.UUID]*entities.Basket{f.basket.ID: f.basket, f.second.ID: f.second}}
+
+	f.service = investing.NewPaperService(baskets, f.orders, f.positions, f.balances, f.quotes, logger.NewLogger(zap.NewNop()))
+	f.service.SetBalanceHolds(f.holds)
+	f.service.SetCart(f.cart)
+	f.service.SetStepUp(&fakePasscodes{tokens: map[string]bool{"session": true}}, nil)
+
+	ctx := context.Background()
+	_, err := f.service.AddToCart(ctx, f.userID, f.basket.ID, "100")
+	require.NoError(t, err)
+	_, err = f.service.AddToCart(ctx, f.userID, f.second.ID, "150")
+	require.NoError(t, err)
+	return f
+}
+
+func TestCart_AddReplacesAmountAndTotals(t *testing.T) {
+	f := newCartFixture(t)
+
+	cart, err := f.service.AddToCart(context.Background(), f.userID, f.basket.ID, "120.50")
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 2)
+	assert.Equal(t, "Core", cart.Items[0].BasketName)
+	assert.Equal(t, "270.50", cart.Total)
+
+	_, err = f.service.AddToCart(context.Background(), f.userID, uuid.New(), "10")
+	assert.ErrorIs(t, err, investing.ErrBasketNotFound)
+	_, err = f.service.AddToCart(context.Background(), f.userID, f.basket.ID, "-5")
+	assert.ErrorIs(t, err, investing.ErrInvalidAmount)
+}
+
+func TestCart_PreviewTotalsItemsAgainstBuyingPower(t *testing.T) {
+	f := newCartFixture(t)
+
+	preview, err := f.service.PreviewCart(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.True(t, preview.Ready)
+	require.Len(t, preview.Items, 2)
+	assert.NotNil(t, preview.Items[0].Preview)
+	assert.Equal(t, "250.00", preview.TotalAmount)
+	assert.Equal(t, "500.00", preview.BuyingPower)
+	assert.Equal(t, "250.00", preview.BuyingPowerAfter)
+	assert.True(t, preview.Paper)
+
+	// Each item fits on its own but together they do not
+	_, err = f.service.AddToCart(context.Background(), f.userID, f.second.ID, "450")
+	require.NoError(t, err)
+	preview, err = f.service.PreviewCart(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.False(t, preview.Ready)
+	require.NotNil(t, preview.Error)
+	assert.Equal(t, "INSUFFICIENT_FUNDS", preview.Error.Code)
+	assert.Equal(t, "-50.00", preview.BuyingPowerAfter)
+}
+
+func TestCart_CheckoutNeedsOneStepUp(t *testing.T) {
+	f := newCartFixture(t)
+	ctx := context.Background()
+
+	_, err := f.service.CheckoutCart(ctx, f.userID, &entities.CartCheckoutRequest{})
+	assert.ErrorIs(t, err, entities.ErrStepUpRequired)
+	_, err = f.service.CheckoutCart(ctx, f.userID, &entities.CartCheckoutRequest{PasscodeSessionToken: "stolen"})
+	assert.ErrorIs(t, err, entities.ErrStepUpFailed)
+	assert.Empty(t, f.orders.orders)
+
+	result, err := f.service.CheckoutCart(ctx, f.userID, &entities.CartCheckoutRequest{PasscodeSessionToken: "session"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.CartCheckoutCompleted, result.Status)
+	assert.Equal(t, "250.00", result.TotalPlaced)
+	for _, item := range result.Items {
+		assert.Equal(t, entities.CartItemPlaced, item.Status)
+		require.NotNil(t, item.Order)
+	}
+	assert.Len(t, f.orders.orders, 2)
+	assert.Empty(t, f.cart.items)
+
+	// The passcode session covers one checkout
+	_, err = f.service.AddToCart(ctx, f.userID, f.basket.ID, "10")
+	require.NoError(t, err)
+	_, err = f.service.CheckoutCart(ctx, f.userID, &entities.CartCheckoutRequest{PasscodeSessionToken: "session"})
+	assert.ErrorIs(t, err, entities.ErrStepUpFailed)
+}
+
+func TestCart_CheckoutPlacesNothingWhenCartIsInvalid(t *testing.T) {
+	f := newCartFixture(t)
+	ctx := context.Background()
+
+	_, err := f.service.AddToCart(ctx, f.userID, f.second.ID, "450")
+	require.NoError(t, err)
+	_, err = f.service.CheckoutCart(ctx, f.userID, &entities.CartCheckoutRequest{PasscodeSessionToken: "session"})
+	assert.ErrorIs(t, err, investing.ErrInsufficientFunds)
+	assert.Empty(t, f.orders.orders)
+	assert.Len(t, f.cart.items, 2)
+}
+
+func TestCart_CheckoutReportsPartialFailurePerItem(t *testing.T) {
+	f := newCartFixture(t)
+	// Another order takes most of the buying power after the cart was checked
+	f.holds.available = decimal.NewFromInt(120)
+
+	result, err := f.service.CheckoutCart(context.Background(), f.userID, &entities.CartCheckoutRequest{PasscodeSessionToken: "session"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.CartCheckoutPartial, result.Status)
+	assert.Equal(t, "100.00", result.TotalPlaced)
+
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, entities.CartItemPlaced, result.Items[0].Status)
+	assert.Equal(t, entities.CartItemFailed, result.Items[1].Status)
+	require.NotNil(t, result.Items[1].Error)
+	assert.Equal(t, "INSUFFICIENT_FUNDS", result.Items[1].Error.Code)
+
+	require.Len(t, f.cart.items, 1, "the failed item stays in the cart")
+	assert.Equal(t, f.second.ID, f.cart.items[0].BasketID)
+}
@@ -0,0 +1,355 @@
+package investing_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeOrders struct {
+	mu     sync.Mutex
+	orders map[uuid.UUID]*entities.Order
+}
+
+func (f *fakeOrders) Create(ctx context.Context, order *entities.Order) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *order
+	f.orders[order.ID] = &stored
+	return nil
+}
+
+func (f *fakeOrders) GetByID(ctx context.Context, id uuid.UUID) (*entities.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (f *fakeOrders) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int, status *entities.OrderStatus) ([]*entities.Order, error) {
+	return nil, nil
+}
+
+func (f *fakeOrders) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.OrderStatus, brokerageRef *string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.orders[id].Status = status
+	f.orders[id].BrokerageRef = brokerageRef
+	return nil
+}
+
+func (f *fakeOrders) ListOpenLimitOrders(ctx context.Context, limit int) ([]*entities.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var open []*entities.Order
+	for _, order := range f.orders {
+		if order.Status == entities.OrderStatusOpen {
+			copied := *order
+			open = append(open, &copied)
+		}
+	}
+	return open, nil
+}
+
+func (f *fakeOrders) TriggerLimitOrder(ctx context.Context, id uuid.UUID) (bool, error) {
+	return f.transition(id, entities.OrderStatusAccepted), nil
+}
+
+func (f *fakeOrders) CloseOpenOrder(ctx context.Context, id uuid.UUID, status entities.OrderStatus) (bool, error) {
+	return f.transition(id, status), nil
+}
+
+func (f *fakeOrders) transition(id uuid.UUID, status entities.OrderStatus) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order := f.orders[id]
+	if order.Status != entities.OrderStatusOpen {
+		return false
+	}
+	order.Status = status
+	return true
+}
+
+func (f *fakeOrders) status(id uuid.UUID) entities.OrderStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.orders[id].Status
+}
+
+type fakeBrokerage struct {
+	mu     sync.Mutex
+	placed []decimal.Decimal
+}
+
+func (f *fakeBrokerage) PlaceOrder(ctx context.Context, basketID uuid.UUID, side entities.OrderSide, amount decimal.Decimal) (*investing.BrokerageOrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.placed = append(f.placed, amount)
+	return &investing.BrokerageOrderResponse{OrderRef: "BASKET-1", Status: entities.OrderStatusAccepted}, nil
+}
+
+func (f *fakeBrokerage) GetOrderStatus(ctx context.Context, brokerageRef string) (*investing.BrokerageOrderStatus, error) {
+	return nil, nil
+}
+
+func (f *fakeBrokerage) CancelOrder(ctx context.Context, brokerageRef string) error {
+	return nil
+}
+
+func (f *fakeBrokerage) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.placed)
+}
+
+type limitFixture struct {
+	*previewFixture
+	orders    *fakeOrders
+	brokerage *fakeBrokerage
+}
+
+// Tuesday 13 October 2026, mid-session in New York
+var sessionTime = time.Date(2026, 10, 13, 15, 0, 0, 0, time.UTC)
+
+func newLimitFixture() *limitFixture {
+	f := &limitFixture{
+		previewFixture: newPreviewFixture(),
+		orders:         &fakeOrders{orders: make(map[uuid.UUID]*entities.Order)},
+		brokerage:      &fakeBrokerage{},
+	}
+	f.service = investing.NewService(&fakeBaskets{basket: f.basket}, f.orders, f.positions, f.balances, f.brokerage, nil, nil, nil, nil,
+		logger.NewLogger(zap.NewNop()))
+	f.service.SetQuoteProvider(f.quotes)
+	return f
+}
+
+func (f *limitFixture) placeLimitBuy(t *testing.T, limitPrice string, tif entities.TimeInForce) *entities.Order {
+	t.Helper()
+	order, err := f.service.CreateOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID:    f.basket.ID,
+		Side:        entities.OrderSideBuy,
+		Amount:      "100",
+		OrderType:   entities.OrderTypeLimit,
+		LimitPrice:  &limitPrice,
+		TimeInForce: tif,
+	})
+	require.NoError(t, err)
+	return order
+}
+
+func TestSyntheticBasketPrice(t *testing.T) {
+	f := newPreviewFixture()
+	quotes, err := f.quotes.GetQuotes(context.Background(), []string{"VTI", "BND"}, entities.OrderSideBuy)
+	require.NoError(t, err)
+
+	price, err := investing.SyntheticBasketPrice(f.basket, quotes)
+	require.NoError(t, err)
+	// 0.6 x 250 + 0.4 x 80
+	assert.Equal(t, "182", price.String())
+}
+
+func TestLimitOrder_RestsUntilBasketPriceCrossesLimit(t *testing.T) {
+	f := newLimitFixture()
+	order := f.placeLimitBuy(t, "180", entities.TimeInForceGTC)
+
+	assert.Equal(t, entities.OrderStatusOpen, order.Status)
+	assert.Equal(t, entities.TimeInForceGTC, order.TimeInForce)
+	require.NotNil(t, order.ExpiresAt)
+	assert.True(t, f.balances.deducted, "buying power is reserved while the order rests")
+	assert.Zero(t, f.brokerage.count(), "limit orders are not sent to the brokerage on placement")
+
+	// Basket price 182 is above the 180 limit
+	report, err := f.service.RunLimitOrders(context.Background(), sessionTime)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Zero(t, report.Triggered)
+	assert.Equal(t, entities.OrderStatusOpen, f.orders.status(order.ID))
+
+	// VTI drops to 245: 0.6 x 245 + 0.4 x 80 = 179
+	f.quotes.ask["VTI"] = decimal.NewFromInt(245)
+	report, err = f.service.RunLimitOrders(context.Background(), sessionTime)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Triggered)
+	assert.Equal(t, 1, f.brokerage.count())
+	assert.Equal(t, entities.OrderStatusAccepted, f.orders.status(order.ID))
+}
+
+func TestLimitOrder_NotTriggeredWhileMarketClosed(t *testing.T) {
+	f := newLimitFixture()
+	order := f.placeLimitBuy(t, "200", entities.TimeInForceGTC)
+
+	saturday := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	report, err := f.service.RunLimitOrders(context.Background(), saturday)
+	require.NoError(t, err)
+	assert.Zero(t, report.Triggered)
+	assert.Equal(t, entities.OrderStatusOpen, f.orders.status(order.ID))
+}
+
+func TestLimitOrder_DayOrderExpiresAndReleasesBuyingPower(t *testing.T) {
+	f := newLimitFixture()
+	order := f.placeLimitBuy(t, "100", "")
+	assert.Equal(t, entities.TimeInForceDay, order.TimeInForce)
+	require.NotNil(t, order.ExpiresAt)
+
+	report, err := f.service.RunLimitOrders(context.Background(), order.ExpiresAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Expired)
+	assert.Equal(t, entities.OrderStatusExpired, f.orders.status(order.ID))
+	assert.Equal(t, "100", f.balances.released.String())
+	assert.Zero(t, f.brokerage.count())
+}
+
+func TestCancelOrder(t *testing.T) {
+	f := newLimitFixture()
+	order := f.placeLimitBuy(t, "100", entities.TimeInForceGTC)
+
+	_, err := f.service.CancelOrder(context.Background(), uuid.New(), order.ID)
+	assert.ErrorIs(t, err, investing.ErrOrderNotFound, "other users cannot cancel the order")
+
+	canceled, err := f.service.CancelOrder(context.Background(), order.UserID, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrderStatusCanceled, canceled.Status)
+	assert.Equal(t, "100", f.balances.released.String())
+
+	_, err = f.service.CancelOrder(context.Background(), order.UserID, order.ID)
+	assert.ErrorIs(t, err, investing.ErrOrderNotCancelable)
+
+	report, err := f.service.RunLimitOrders(context.Background(), sessionTime)
+	require.NoError(t, err)
+	assert.Zero(t, report.Checked, "canceled orders are no longer monitored")
+}
+
+func TestCreateOrder_RejectsInvalidLimitTerms(t *testing.T) {
+	f := newLimitFixture()
+	ctx := context.Background()
+	zero := "0"
+	price := "180"
+
+	_, err := f.service.CreateOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100", OrderType: entities.OrderTypeLimit,
+	})
+	assert.ErrorIs(t, err, investing.ErrInvalidLimitPrice)
+
+	_, err = f.service.CreateOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100", OrderType: entities.OrderTypeLimit, LimitPrice: &zero,
+	})
+	assert.ErrorIs(t, err, investing.ErrInvalidLimitPrice)
+
+	_, err = f.service.CreateOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100", OrderType: entities.OrderTypeLimit,
+		LimitPrice: &price, TimeInForce: "ioc",
+	})
+	assert.ErrorIs(t, err, investing.ErrInvalidTimeInForce)
+
+	_, err = f.service.CreateOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100", OrderType: "stop",
+	})
+	assert.ErrorIs(t, err, investing.ErrInvalidOrderType)
+}
+
+type holidayCalendar struct{}
+
+// NextSession treats Thanksgiving, 26 November 2026, as closed and the
+// Friday after as closing at 13:00
+func (holidayCalendar) NextSession(ctx context.Context, after time.Time) (*entities.MarketSession, error) {
+	friday := &entities.MarketSession{
+		Date:       "2026-11-27",
+		OpenAt:     time.Date(2026, 11, 27, 14, 30, 0, 0, time.UTC),
+		CloseAt:    time.Date(2026, 11, 27, 18, 0, 0, 0, time.UTC),
+		EarlyClose: true,
+	}
+	if after.Before(friday.CloseAt) {
+		return friday, nil
+	}
+	return nil, errors.New("no session")
+}
+
+func TestLimitOrder_NotTriggeredOnMarketHoliday(t *testing.T) {
+	f := newLimitFixture()
+	f.service.SetMarketCalendar(holidayCalendar{})
+	order := f.placeLimitBuy(t, "200", entities.TimeInForceGTC)
+
+	thanksgiving := time.Date(2026, 11, 26, 15, 0, 0, 0, time.UTC)
+	report, err := f.service.RunLimitOrders(context.Background(), thanksgiving)
+	require.NoError(t, err)
+	assert.Zero(t, report.Triggered)
+	assert.Equal(t, entities.OrderStatusOpen, f.orders.status(order.ID))
+
+	earlyClose := time.Date(2026, 11, 27, 17, 0, 0, 0, time.UTC)
+	report, err = f.service.RunLimitOrders(context.Background(), earlyClose)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Triggered)
+}
+
+type fakeHolds struct {
+	available decimal.Decimal
+	held      map[uuid.UUID]decimal.Decimal
+	released  []uuid.UUID
+}
+
+func (f *fakeHolds) Place(ctx context.Context, userID uuid.UUID, kind entities.HoldKind, referenceID uuid.UUID, amount decimal.Decimal) (*entities.BalanceHold, error) {
+	if f.available.LessThan(amount) {
+		return nil, entities.ErrInsufficientBuyingPower
+	}
+	f.available = f.available.Sub(amount)
+	f.held[referenceID] = amount
+	return &entities.BalanceHold{ID: uuid.New(), UserID: userID, Kind: kind, ReferenceID: referenceID, Amount: amount}, nil
+}
+
+func (f *fakeHolds) Capture(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	return &entities.BalanceHold{ReferenceID: referenceID, Status: entities.HoldStatusCaptured}, nil
+}
+
+func (f *fakeHolds) Release(ctx context.Context, kind entities.HoldKind, referenceID uuid.UUID) (*entities.BalanceHold, error) {
+	amount, ok := f.held[referenceID]
+	if !ok {
+		return nil, entities.ErrBalanceHoldNotFound
+	}
+	delete(f.held, referenceID)
+	f.available = f.available.Add(amount)
+	f.released = append(f.released, referenceID)
+	return &entities.BalanceHold{ReferenceID: referenceID, Status: entities.HoldStatusReleased}, nil
+}
+
+func TestCreateOrder_HoldsBuyingPowerUntilClosed(t *testing.T) {
+	f := newLimitFixture()
+	holds := &fakeHolds{available: decimal.NewFromInt(150), held: map[uuid.UUID]decimal.Decimal{}}
+	f.service.SetBalanceHolds(holds)
+
+	order := f.placeLimitBuy(t, "100", entities.TimeInForceGTC)
+	assert.Equal(t, "100", holds.held[order.ID].String())
+	assert.False(t, f.balances.deducted, "holds replace the direct deduct")
+
+	limitPrice := "100"
+	_, err := f.service.CreateOrder(context.Background(), order.UserID, &entities.OrderCreateRequest{
+		BasketID:   f.basket.ID,
+		Side:       entities.OrderSideBuy,
+		Amount:     "100",
+		OrderType:  entities.OrderTypeLimit,
+		LimitPrice: &limitPrice,
+	})
+	assert.ErrorIs(t, err, investing.ErrInsufficientFunds)
+	assert.Len(t, f.orders.orders, 1, "an order that loses the race is not recorded")
+
+	_, err = f.service.CancelOrder(context.Background(), order.UserID, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{order.ID}, holds.released)
+	assert.Equal(t, "150", holds.available.String())
+	assert.True(t, f.balances.released.IsZero())
+}
@@ -0,0 +1,113 @@
+package investing_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+func newPaperFixture() (*previewFixture, *fakeOrders) {
+	f := newPreviewFixture()
+	orders := &fakeOrders{orders: make(map[uuid.UUID]*entities.Order)}
+	f.service = investing.NewPaperService(&fakeBaskets{basket: f.basket}, orders, f.positions, f.balances, f.quotes,
+		logger.NewLogger(zap.NewNop()))
+	return f, orders
+}
+
+func TestPaperService_BuyFillsAtLiveAsk(t *testing.T) {
+	f, _ := newPaperFixture()
+	require.True(t, f.service.Paper())
+
+	order, err := f.service.CreateOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID,
+		Side:     entities.OrderSideBuy,
+		Amount:   "100",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, order.Paper)
+	assert.Equal(t, entities.OrderStatusFilled, order.Status, "paper orders are answered with their fill")
+	require.NotNil(t, order.BrokerageRef)
+	assert.True(t, strings.HasPrefix(*order.BrokerageRef, "PAPER-"))
+	assert.True(t, f.balances.deducted)
+
+	// Basket ask is 0.6 x 250 + 0.4 x 80 = 182
+	require.NotNil(t, f.positions.position)
+	assert.Equal(t, "0.54945054", f.positions.position.Quantity.String())
+	assert.Equal(t, "182", f.positions.position.AvgPrice.String())
+}
+
+func TestPaperService_SellCreditsProceeds(t *testing.T) {
+	f, _ := newPaperFixture()
+	f.positions.position = &entities.Position{
+		ID:          uuid.New(),
+		Quantity:    decimal.NewFromInt(1),
+		AvgPrice:    decimal.NewFromInt(150),
+		MarketValue: decimal.NewFromInt(182),
+	}
+
+	order, err := f.service.CreateOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID,
+		Side:     entities.OrderSideSell,
+		Amount:   "90.96",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrderStatusFilled, order.Status)
+
+	// Basket bid is 0.6 x 249.90 + 0.4 x 79.95 = 181.92, so half a unit sells
+	assert.Equal(t, "0.5", f.positions.position.Quantity.String())
+	assert.Equal(t, "90.96", f.balances.released.String())
+}
+
+func TestPaperService_FlagsPreviewAndPortfolio(t *testing.T) {
+	f, _ := newPaperFixture()
+
+	preview, err := f.service.PreviewOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100",
+	})
+	require.NoError(t, err)
+	assert.True(t, preview.Paper)
+
+	portfolio, err := f.service.GetPortfolio(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.True(t, portfolio.Paper)
+
+	live := newPreviewFixture()
+	preview, err = live.service.PreviewOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID: live.basket.ID, Side: entities.OrderSideBuy, Amount: "100",
+	})
+	require.NoError(t, err)
+	assert.False(t, preview.Paper)
+}
+
+func TestPaperBrokerage_DeliversFillsOnce(t *testing.T) {
+	f := newPreviewFixture()
+	broker := investing.NewPaperBrokerage(&fakeBaskets{basket: f.basket}, f.quotes)
+
+	resp, err := broker.PlaceOrder(context.Background(), f.basket.ID, entities.OrderSideBuy, decimal.NewFromInt(364))
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrderStatusFilled, resp.Status)
+
+	status, err := broker.GetOrderStatus(context.Background(), resp.OrderRef)
+	require.NoError(t, err)
+	require.Len(t, status.Fills, 1)
+	assert.Equal(t, "2", status.Fills[0].Quantity)
+	assert.Equal(t, "182", status.Fills[0].Price)
+
+	_, err = broker.GetOrderStatus(context.Background(), resp.OrderRef)
+	assert.Error(t, err)
+	assert.Error(t, broker.CancelOrder(context.Background(), resp.OrderRef))
+
+	_, err = broker.PlaceOrder(context.Background(), uuid.New(), entities.OrderSideBuy, decimal.NewFromInt(10))
+	assert.ErrorIs(t, err, investing.ErrBasketNotFound)
+}
@@ -0,0 +1,251 @@
+package investing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+type fakeBaskets struct {
+	basket *entities.Basket
+}
+
+func (f *fakeBaskets) GetAll(ctx context.Context) ([]*entities.Basket, error) {
+	return []*entities.Basket{f.basket}, nil
+}
+
+func (f *fakeBaskets) GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error) {
+	if id != f.basket.ID {
+		return nil, nil
+	}
+	return f.basket, nil
+}
+
+type fakeBalances struct {
+	buyingPower decimal.Decimal
+	deducted    bool
+	released    decimal.Decimal
+}
+
+func (f *fakeBalances) Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error) {
+	return &entities.Balance{UserID: userID, BuyingPower: f.buyingPower, Currency: "USD"}, nil
+}
+
+func (f *fakeBalances) DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f.deducted = true
+	return nil
+}
+
+func (f *fakeBalances) AddBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f.released = f.released.Add(amount)
+	return nil
+}
+
+type fakePositions struct {
+	position *entities.Position
+}
+
+func (f *fakePositions) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
+	return nil, nil
+}
+
+func (f *fakePositions) CreateOrUpdate(ctx context.Context, position *entities.Position) error {
+	f.position = position
+	return nil
+}
+
+func (f *fakePositions) GetByUserAndBasket(ctx context.Context, userID, basketID uuid.UUID) (*entities.Position, error) {
+	if f.position == nil {
+		return nil, investing.ErrPositionNotFound
+	}
+	return f.position, nil
+}
+
+type fakeQuotes struct {
+	ask, bid map[string]decimal.Decimal
+	err      error
+}
+
+func (f *fakeQuotes) GetQuotes(ctx context.Context, symbols []string, side entities.OrderSide) (map[string]investing.Quote, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	prices := f.ask
+	if side == entities.OrderSideSell {
+		prices = f.bid
+	}
+	quotes := make(map[string]investing.Quote)
+	for _, symbol := range symbols {
+		if price, ok := prices[symbol]; ok {
+			quotes[symbol] = investing.Quote{Price: price, QuotedAt: time.Now()}
+		}
+	}
+	return quotes, nil
+}
+
+type previewFixture struct {
+	service   *investing.Service
+	basket    *entities.Basket
+	balances  *fakeBalances
+	positions *fakePositions
+	quotes    *fakeQuotes
+}
+
+func newPreviewFixture() *previewFixture {
+	basket := &entities.Basket{
+		ID:   uuid.New(),
+		Name: "Core",
+		Composition: []entities.BasketComponent{
+			{Symbol: "VTI", Weight: decimal.RequireFromString("0.6")},
+			{Symbol: "BND", Weight: decimal.RequireFromString("0.4")},
+		},
+	}
+	f := &previewFixture{
+		basket:    basket,
+		balances:  &fakeBalances{buyingPower: decimal.NewFromInt(500)},
+		positions: &fakePositions{},
+		quotes: &fakeQuotes{
+			ask: map[string]decimal.Decimal{"VTI": decimal.NewFromInt(250), "BND": decimal.NewFromInt(80)},
+			bid: map[string]decimal.Decimal{"VTI": decimal.RequireFromString("249.90"), "BND": decimal.RequireFromString("79.95")},
+		},
+	}
+	f.service = investing.NewService(&fakeBaskets{basket: basket}, nil, f.positions, f.balances, nil, nil, nil, nil, nil,
+		logger.NewLogger(zap.NewNop()))
+	f.service.SetQuoteProvider(f.quotes)
+	return f
+}
+
+func TestPreviewOrder_BuyBreaksDownComponentsAndCash(t *testing.T) {
+	f := newPreviewFixture()
+	f.service.SetFeeSchedule(investing.FeeSchedule{
+		CommissionBps:     decimal.NewFromInt(25),
+		MinimumCommission: decimal.NewFromInt(1),
+	})
+
+	preview, err := f.service.PreviewOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID,
+		Side:     entities.OrderSideBuy,
+		Amount:   "100",
+	})
+	require.NoError(t, err)
+
+	// 25 bps of $100 is $0.25, raised to the $1 minimum and taken from the amount
+	assert.Equal(t, "1.00", preview.Fees.Commission)
+	assert.Equal(t, "0.00", preview.Fees.Regulatory)
+	assert.Equal(t, "99.00", preview.NetAmount)
+	assert.Equal(t, "500.00", preview.BuyingPower)
+	assert.Equal(t, "400.00", preview.CashAfter)
+
+	require.Len(t, preview.Components, 2)
+	assert.Equal(t, "VTI", preview.Components[0].Symbol)
+	assert.Equal(t, "59.40", preview.Components[0].Notional)
+	assert.Equal(t, "250.00", preview.Components[0].Price)
+	assert.Equal(t, "0.2376", preview.Components[0].EstimatedShares)
+	assert.Equal(t, "39.60", preview.Components[1].Notional)
+	assert.Equal(t, "0.495", preview.Components[1].EstimatedShares)
+	assert.False(t, f.balances.deducted, "previews never reserve buying power")
+}
+
+func TestPreviewOrder_SellChargesRegulatoryFees(t *testing.T) {
+	f := newPreviewFixture()
+	f.positions.position = &entities.Position{MarketValue: decimal.NewFromInt(1000)}
+
+	preview, err := f.service.PreviewOrder(context.Background(), uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID,
+		Side:     entities.OrderSideSell,
+		Amount:   "1000",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "249.90", preview.Components[0].Price, "sells are priced at the bid")
+	// SEC: $600 and $400 round up to $0.02 each; TAF rounds up to $0.01 each
+	assert.Equal(t, "0.06", preview.Fees.Regulatory)
+	assert.Equal(t, "999.94", preview.NetAmount)
+	assert.Equal(t, "1499.94", preview.CashAfter)
+}
+
+func TestPreviewOrder_AppliesOrderChecks(t *testing.T) {
+	f := newPreviewFixture()
+	ctx := context.Background()
+
+	_, err := f.service.PreviewOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "600",
+	})
+	assert.ErrorIs(t, err, investing.ErrInsufficientFunds)
+
+	_, err = f.service.PreviewOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideSell, Amount: "10",
+	})
+	assert.ErrorIs(t, err, investing.ErrInsufficientPosition)
+
+	_, err = f.service.PreviewOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "-5",
+	})
+	assert.ErrorIs(t, err, investing.ErrInvalidAmount)
+
+	delete(f.quotes.ask, "BND")
+	_, err = f.service.PreviewOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100",
+	})
+	assert.ErrorIs(t, err, investing.ErrQuotesUnavailable)
+
+	f.quotes.err = errors.New("circuit breaker is open")
+	_, err = f.service.PreviewOrder(ctx, uuid.New(), &entities.OrderCreateRequest{
+		BasketID: f.basket.ID, Side: entities.OrderSideBuy, Amount: "100",
+	})
+	assert.ErrorIs(t, err, investing.ErrQuotesUnavailable)
+}
+
+func TestExecutionWindowAt(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Tuesday mid-session executes right away
+	open := time.Date(2026, 10, 13, 11, 0, 0, 0, newYork)
+	window := investing.ExecutionWindowAt(open)
+	assert.True(t, window.MarketOpen)
+	assert.Equal(t, open.UTC(), window.EarliestAt)
+	assert.Equal(t, open.Add(15*time.Minute).UTC(), window.LatestAt)
+
+	// Near the close the window ends at the bell
+	window = investing.ExecutionWindowAt(time.Date(2026, 10, 13, 15, 55, 0, 0, newYork))
+	assert.Equal(t, time.Date(2026, 10, 13, 16, 0, 0, 0, newYork).UTC(), window.LatestAt)
+
+	// Friday evening waits for Monday's open
+	window = investing.ExecutionWindowAt(time.Date(2026, 10, 16, 18, 0, 0, 0, newYork))
+	assert.False(t, window.MarketOpen)
+	assert.Equal(t, time.Date(2026, 10, 19, 9, 30, 0, 0, newYork).UTC(), window.EarliestAt)
+
+	// Before the open on a weekday waits for that morning
+	window = investing.ExecutionWindowAt(time.Date(2026, 10, 14, 7, 0, 0, 0, newYork))
+	assert.Equal(t, time.Date(2026, 10, 14, 9, 30, 0, 0, newYork).UTC(), window.EarliestAt)
+}
+
+func TestExecutionFees_RegulatoryOnSellFillsOnly(t *testing.T) {
+	f := newPreviewFixture()
+	fills := []entities.BrokerageFill{
+		{Symbol: "VTI", Quantity: "10", Price: "250"},
+		{Symbol: "BND", Quantity: "n/a", Price: "70"},
+	}
+	order := &entities.Order{Side: entities.OrderSideBuy, Amount: decimal.NewFromInt(2500)}
+
+	fees := f.service.ExecutionFees(order, fills)
+	assert.True(t, fees.Total().IsZero(), "buys are commission and fee free")
+
+	order.Side = entities.OrderSideSell
+	fees = f.service.ExecutionFees(order, fills)
+	// SEC: 2500 * 0.0000278 = 0.0695 -> 0.07; TAF: 10 * 0.000166 -> 0.01
+	assert.Equal(t, "0.08", fees.Regulatory.StringFixed(2))
+	assert.True(t, fees.Commission.IsZero())
+}
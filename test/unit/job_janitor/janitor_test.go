@@ -0,0 +1,147 @@
+package job_janitor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/workers/job_janitor"
+)
+
+type fakeWalletStore struct {
+	expired  []*entities.WalletProvisioningJob
+	released []*entities.WalletProvisioningJob
+	renewed  map[uuid.UUID]bool // jobs that heartbeat before the janitor writes
+	err      error
+}
+
+func (s *fakeWalletStore) CountExpiredLeases(ctx context.Context) (int64, error) {
+	return int64(len(s.expired)), s.err
+}
+
+func (s *fakeWalletStore) GetExpiredLeases(ctx context.Context, limit int) ([]*entities.WalletProvisioningJob, error) {
+	return s.expired, s.err
+}
+
+func (s *fakeWalletStore) ReleaseExpiredLease(ctx context.Context, job *entities.WalletProvisioningJob) (bool, error) {
+	if s.renewed[job.ID] {
+		return false, nil
+	}
+	s.released = append(s.released, job)
+	return true, nil
+}
+
+type fakeFundingStore struct {
+	expired  []*entities.FundingEventJob
+	released []*entities.FundingEventJob
+	err      error
+}
+
+func (s *fakeFundingStore) CountExpiredLeases(ctx context.Context) (int64, error) {
+	return int64(len(s.expired)), s.err
+}
+
+func (s *fakeFundingStore) GetExpiredLeases(ctx context.Context, limit int) ([]*entities.FundingEventJob, error) {
+	return s.expired, s.err
+}
+
+func (s *fakeFundingStore) ReleaseExpiredLease(ctx context.Context, job *entities.FundingEventJob) (bool, error) {
+	s.released = append(s.released, job)
+	return true, nil
+}
+
+func walletJob(attempts, maxAttempts int) *entities.WalletProvisioningJob {
+	job := &entities.WalletProvisioningJob{ID: uuid.New(), AttemptCount: attempts - 1, MaxAttempts: maxAttempts}
+	job.MarkStarted()
+	job.TakeLease(-time.Minute)
+	return job
+}
+
+func fundingJob(attempts, maxAttempts int) *entities.FundingEventJob {
+	job := &entities.FundingEventJob{ID: uuid.New(), AttemptCount: attempts - 1, MaxAttempts: maxAttempts}
+	job.MarkProcessing()
+	job.TakeLease(-time.Minute)
+	return job
+}
+
+func TestSweepRequeuesJobsWithAttemptsLeft(t *testing.T) {
+	wallet := &fakeWalletStore{expired: []*entities.WalletProvisioningJob{walletJob(1, 5)}}
+	funding := &fakeFundingStore{expired: []*entities.FundingEventJob{fundingJob(2, 5)}}
+	janitor := job_janitor.NewWorker(wallet, funding, job_janitor.DefaultConfig(), zap.NewNop())
+
+	results, err := janitor.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, job_janitor.QueueResult{Queue: job_janitor.QueueWalletProvisioning, Stuck: 1, Requeued: 1}, results[0])
+	assert.Equal(t, job_janitor.QueueResult{Queue: job_janitor.QueueFundingEvents, Stuck: 1, Requeued: 1}, results[1])
+
+	require.Len(t, wallet.released, 1)
+	walletReleased := wallet.released[0]
+	assert.Equal(t, entities.ProvisioningStatusRetry, walletReleased.Status)
+	require.NotNil(t, walletReleased.NextRetryAt)
+	assert.Nil(t, walletReleased.LeaseExpiresAt)
+
+	require.Len(t, funding.released, 1)
+	fundingReleased := funding.released[0]
+	assert.Equal(t, entities.JobStatusFailed, fundingReleased.Status)
+	assert.True(t, fundingReleased.CanRetry())
+	assert.Nil(t, fundingReleased.LeaseExpiresAt)
+}
+
+func TestSweepDeadLettersExhaustedJobs(t *testing.T) {
+	wallet := &fakeWalletStore{expired: []*entities.WalletProvisioningJob{walletJob(5, 5)}}
+	funding := &fakeFundingStore{expired: []*entities.FundingEventJob{fundingJob(5, 5)}}
+	janitor := job_janitor.NewWorker(wallet, funding, job_janitor.DefaultConfig(), zap.NewNop())
+
+	results, err := janitor.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, results[0].DeadLettered)
+	assert.Equal(t, 1, results[1].DeadLettered)
+
+	assert.Equal(t, entities.ProvisioningStatusFailed, wallet.released[0].Status)
+	assert.Nil(t, wallet.released[0].NextRetryAt)
+	assert.Equal(t, entities.JobStatusDLQ, funding.released[0].Status)
+	require.NotNil(t, funding.released[0].MovedToDLQAt)
+}
+
+func TestSweepSkipsJobsThatRenewedTheirLease(t *testing.T) {
+	job := walletJob(1, 5)
+	wallet := &fakeWalletStore{
+		expired: []*entities.WalletProvisioningJob{job},
+		renewed: map[uuid.UUID]bool{job.ID: true},
+	}
+	janitor := job_janitor.NewWorker(wallet, &fakeFundingStore{}, job_janitor.DefaultConfig(), zap.NewNop())
+
+	results, err := janitor.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), results[0].Stuck)
+	assert.Zero(t, results[0].Requeued)
+	assert.Zero(t, results[0].DeadLettered)
+}
+
+func TestSweepContinuesPastAFailingQueue(t *testing.T) {
+	wallet := &fakeWalletStore{err: errors.New("connection reset")}
+	funding := &fakeFundingStore{expired: []*entities.FundingEventJob{fundingJob(1, 5)}}
+	janitor := job_janitor.NewWorker(wallet, funding, job_janitor.DefaultConfig(), zap.NewNop())
+
+	results, err := janitor.Sweep(context.Background())
+	assert.ErrorContains(t, err, job_janitor.QueueWalletProvisioning)
+	assert.Equal(t, 1, results[1].Requeued)
+}
+
+func TestCountStuckAddsQueues(t *testing.T) {
+	wallet := &fakeWalletStore{expired: []*entities.WalletProvisioningJob{walletJob(1, 5), walletJob(1, 5)}}
+	funding := &fakeFundingStore{expired: []*entities.FundingEventJob{fundingJob(1, 5)}}
+	janitor := job_janitor.NewWorker(wallet, funding, job_janitor.DefaultConfig(), zap.NewNop())
+
+	stuck, err := janitor.CountStuck(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stuck)
+}
@@ -0,0 +1,139 @@
+package jurisdiction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/jurisdiction"
+)
+
+type fakeRepo struct {
+	rules     map[string]*entities.CountryRule
+	countries map[uuid.UUID]string
+	listCalls int
+}
+
+func newFakeRepo(rules ...*entities.CountryRule) *fakeRepo {
+	repo := &fakeRepo{rules: map[string]*entities.CountryRule{}, countries: map[uuid.UUID]string{}}
+	for _, rule := range rules {
+		repo.rules[rule.CountryCode] = rule
+	}
+	return repo
+}
+
+func (f *fakeRepo) ListRules(ctx context.Context) ([]*entities.CountryRule, error) {
+	f.listCalls++
+	rules := make([]*entities.CountryRule, 0, len(f.rules))
+	for _, rule := range f.rules {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (f *fakeRepo) UpsertRule(ctx context.Context, rule *entities.CountryRule) error {
+	f.rules[rule.CountryCode] = rule
+	return nil
+}
+
+func (f *fakeRepo) DeleteRule(ctx context.Context, countryCode string) (bool, error) {
+	_, ok := f.rules[countryCode]
+	delete(f.rules, countryCode)
+	return ok, nil
+}
+
+func (f *fakeRepo) GetUserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	return f.countries[userID], nil
+}
+
+func (f *fakeRepo) SetUserCountry(ctx context.Context, userID uuid.UUID, countryCode string) error {
+	f.countries[userID] = countryCode
+	return nil
+}
+
+var allFeatures = entities.KnownJurisdictionFeatures
+
+func TestEvaluateOnboarding_UsesCountryRuleThenDefault(t *testing.T) {
+	repo := newFakeRepo(
+		&entities.CountryRule{CountryCode: "*", OnboardingAllowed: true, AllowedFeatures: allFeatures, KYCLevel: entities.KYCLevelStandard},
+		&entities.CountryRule{CountryCode: "KP", OnboardingAllowed: false, AllowedFeatures: allFeatures, KYCLevel: entities.KYCLevelEnhanced},
+	)
+	svc := jurisdiction.NewService(repo, 0, zap.NewNop())
+
+	blocked, err := svc.EvaluateOnboarding(context.Background(), "kp")
+	require.NoError(t, err)
+	assert.False(t, blocked.Allowed)
+	assert.Equal(t, "KP", blocked.CountryCode)
+	assert.Empty(t, blocked.AllowedFeatures)
+
+	fallback, err := svc.EvaluateOnboarding(context.Background(), "NG")
+	require.NoError(t, err)
+	assert.True(t, fallback.Allowed)
+	assert.Equal(t, entities.KYCLevelStandard, fallback.KYCLevel)
+}
+
+func TestRule_FailsClosedWithoutDefault(t *testing.T) {
+	svc := jurisdiction.NewService(newFakeRepo(), 0, zap.NewNop())
+
+	rule, err := svc.Rule(context.Background(), "GB")
+	require.NoError(t, err)
+	assert.False(t, rule.OnboardingAllowed)
+	assert.False(t, rule.Allows(entities.FeatureTrading))
+}
+
+func TestIsFeatureAllowed_FollowsUserCountry(t *testing.T) {
+	repo := newFakeRepo(
+		&entities.CountryRule{CountryCode: "*", OnboardingAllowed: true, AllowedFeatures: allFeatures},
+		&entities.CountryRule{CountryCode: "GB", OnboardingAllowed: true, AllowedFeatures: []entities.JurisdictionFeature{entities.FeatureTrading}},
+	)
+	svc := jurisdiction.NewService(repo, 0, zap.NewNop())
+	userID := uuid.New()
+	require.NoError(t, svc.RecordUserCountry(context.Background(), userID, "gb"))
+
+	allowed, country, err := svc.IsFeatureAllowed(context.Background(), userID, entities.FeatureTrading)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, "GB", country)
+
+	allowed, _, err = svc.IsFeatureAllowed(context.Background(), userID, entities.FeatureCards)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestUpsertRule_ValidatesAndRefreshesCache(t *testing.T) {
+	repo := newFakeRepo(&entities.CountryRule{CountryCode: "*", OnboardingAllowed: true, AllowedFeatures: allFeatures})
+	svc := jurisdiction.NewService(repo, 0, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := svc.UpsertRule(ctx, "USA", &entities.UpsertCountryRuleRequest{KYCLevel: entities.KYCLevelBasic}, uuid.New())
+	assert.True(t, errors.Is(err, jurisdiction.ErrInvalidRule))
+
+	_, err = svc.UpsertRule(ctx, "FR", &entities.UpsertCountryRuleRequest{
+		AllowedFeatures: []entities.JurisdictionFeature{"margin"},
+		KYCLevel:        entities.KYCLevelBasic,
+	}, uuid.New())
+	assert.True(t, errors.Is(err, jurisdiction.ErrInvalidRule))
+
+	before, err := svc.Rule(ctx, "FR")
+	require.NoError(t, err)
+	assert.True(t, before.Allows(entities.FeatureCards))
+
+	_, err = svc.UpsertRule(ctx, "fr", &entities.UpsertCountryRuleRequest{
+		OnboardingAllowed: true,
+		AllowedFeatures:   []entities.JurisdictionFeature{entities.FeatureTrading},
+		KYCLevel:          entities.KYCLevelEnhanced,
+	}, uuid.New())
+	require.NoError(t, err)
+
+	after, err := svc.Rule(ctx, "FR")
+	require.NoError(t, err)
+	assert.Equal(t, "FR", after.CountryCode)
+	assert.False(t, after.Allows(entities.FeatureCards))
+	assert.Equal(t, entities.KYCLevelEnhanced, after.KYCLevel)
+}
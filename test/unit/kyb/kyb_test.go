@@ -0,0 +1,385 @@
+package kyb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/kyb"
+)
+
+type fakeRepo struct {
+	accountTypes map[uuid.UUID]entities.UserAccountType
+	applications map[uuid.UUID]*entities.KYBApplication
+	applied      map[uuid.UUID][]entities.TierLimit
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		accountTypes: map[uuid.UUID]entities.UserAccountType{},
+		applications: map[uuid.UUID]*entities.KYBApplication{},
+		applied:      map[uuid.UUID][]entities.TierLimit{},
+	}
+}
+
+func (f *fakeRepo) GetAccountType(ctx context.Context, userID uuid.UUID) (entities.UserAccountType, error) {
+	accountType, ok := f.accountTypes[userID]
+	if !ok {
+		return "", errors.New("user not found")
+	}
+	return accountType, nil
+}
+
+func (f *fakeRepo) ApplyBusinessAccount(ctx context.Context, userID uuid.UUID, limits []entities.TierLimit) error {
+	f.accountTypes[userID] = entities.UserAccountBusiness
+	f.applied[userID] = limits
+	return nil
+}
+
+func (f *fakeRepo) Create(ctx context.Context, application *entities.KYBApplication) (*entities.KYBApplication, bool, error) {
+	for _, existing := range f.applications {
+		if existing.UserID == application.UserID && (existing.Status == entities.KYBDraft || existing.Status == entities.KYBPendingReview) {
+			return existing, false, nil
+		}
+	}
+	stored := *application
+	f.applications[stored.ID] = &stored
+	return &stored, true, nil
+}
+
+func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.KYBApplication, error) {
+	application, ok := f.applications[id]
+	if !ok {
+		return nil, entities.ErrKYBApplicationNotFound
+	}
+	return application, nil
+}
+
+func (f *fakeRepo) GetLatestByUser(ctx context.Context, userID uuid.UUID) (*entities.KYBApplication, error) {
+	var latest *entities.KYBApplication
+	for _, application := range f.applications {
+		if application.UserID == userID && (latest == nil || application.CreatedAt.After(latest.CreatedAt)) {
+			latest = application
+		}
+	}
+	if latest == nil {
+		return nil, entities.ErrKYBApplicationNotFound
+	}
+	return latest, nil
+}
+
+func (f *fakeRepo) List(ctx context.Context, status entities.KYBStatus, limit, offset int) ([]*entities.KYBApplication, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) Update(ctx context.Context, application *entities.KYBApplication) error {
+	f.applications[application.ID] = application
+	return nil
+}
+
+func (f *fakeRepo) AddOwner(ctx context.Context, owner *entities.BeneficialOwner) error {
+	application := f.applications[owner.ApplicationID]
+	application.Owners = append(application.Owners, owner)
+	return nil
+}
+
+func (f *fakeRepo) UpdateOwner(ctx context.Context, owner *entities.BeneficialOwner) error {
+	return nil
+}
+
+func (f *fakeRepo) DeleteOwner(ctx context.Context, applicationID, ownerID uuid.UUID) error {
+	application := f.applications[applicationID]
+	for i, owner := range application.Owners {
+		if owner.ID == ownerID {
+			application.Owners = append(application.Owners[:i], application.Owners[i+1:]...)
+			return nil
+		}
+	}
+	return entities.ErrKYBOwnerNotFound
+}
+
+type fakeCases struct {
+	opened   []entities.AdminCaseType
+	resolved []uuid.UUID
+}
+
+func (f *fakeCases) Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error) {
+	f.opened = append(f.opened, caseType)
+	return &entities.AdminCase{ID: uuid.New(), UserID: userID, CaseType: caseType}, nil
+}
+
+func (f *fakeCases) Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error) {
+	f.resolved = append(f.resolved, id)
+	return &entities.AdminCase{ID: id, Status: req.Status}, nil
+}
+
+// fakeVerifier reports the status stored per applicant ref
+type fakeVerifier struct {
+	submitted []uuid.UUID
+	statuses  map[string]entities.KYCStatus
+}
+
+func (f *fakeVerifier) SubmitKYC(ctx context.Context, applicantID uuid.UUID, documents []entities.KYCDocumentUpload, personalInfo *entities.KYCPersonalInfo) (string, error) {
+	f.submitted = append(f.submitted, applicantID)
+	ref := "applicant-" + applicantID.String()
+	f.statuses[ref] = entities.KYCStatusProcessing
+	return ref, nil
+}
+
+func (f *fakeVerifier) GetKYCStatus(ctx context.Context, providerRef string) (*entities.KYCSubmission, error) {
+	return &entities.KYCSubmission{ProviderRef: providerRef, Status: f.statuses[providerRef]}, nil
+}
+
+func (f *fakeVerifier) setAll(status entities.KYCStatus) {
+	for ref := range f.statuses {
+		f.statuses[ref] = status
+	}
+}
+
+type fixture struct {
+	service  *kyb.Service
+	repo     *fakeRepo
+	cases    *fakeCases
+	verifier *fakeVerifier
+	userID   uuid.UUID
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	repo := newFakeRepo()
+	cases := &fakeCases{}
+	verifier := &fakeVerifier{statuses: map[string]entities.KYCStatus{}}
+	config := kyb.DefaultConfig()
+	config.Enabled = true
+	service := kyb.NewService(repo, cases, config, zap.NewNop())
+	service.SetVerifier(verifier)
+
+	userID := uuid.New()
+	repo.accountTypes[userID] = entities.UserAccountIndividual
+	return &fixture{service: service, repo: repo, cases: cases, verifier: verifier, userID: userID}
+}
+
+func companyDetails() *entities.SaveKYBCompanyRequest {
+	return &entities.SaveKYBCompanyRequest{
+		LegalName:            "Acme Widgets LLC",
+		EntityType:           entities.BusinessEntityLLC,
+		RegistrationNumber:   "DE-1234567",
+		TaxID:                "12-3456789",
+		IncorporationCountry: "us",
+		Address:              entities.Address{Street: "1 Main St", City: "Dover", PostalCode: "19901", Country: "US"},
+		BusinessActivity:     "Wholesale of industrial widgets",
+	}
+}
+
+func owner(email string, percent int64, controller bool) *entities.AddBeneficialOwnerRequest {
+	return &entities.AddBeneficialOwnerRequest{
+		FirstName:        "Ada",
+		LastName:         "Owner",
+		Email:            email,
+		OwnershipPercent: decimal.NewFromInt(percent),
+		IsController:     controller,
+		Country:          "US",
+		Documents: []entities.KYCDocumentUpload{
+			{Type: "passport", FileURL: "https://files.example.com/passport.jpg", ContentType: "image/jpeg"},
+		},
+	}
+}
+
+func certificate() []entities.KYCDocumentUpload {
+	return []entities.KYCDocumentUpload{
+		{Type: entities.KYBDocumentCertificateOfIncorporation, FileURL: "https://files.example.com/coi.pdf", ContentType: "application/pdf"},
+	}
+}
+
+// completeDraft fills in everything a submission needs
+func (f *fixture) completeDraft(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := f.service.SaveCompany(ctx, f.userID, companyDetails())
+	require.NoError(t, err)
+	_, err = f.service.AddDocuments(ctx, f.userID, certificate())
+	require.NoError(t, err)
+	_, err = f.service.AddOwner(ctx, f.userID, owner("ada@acme.example", 60, true))
+	require.NoError(t, err)
+}
+
+func TestSaveCompanyKeepsOnlyTaxIDLastFour(t *testing.T) {
+	f := newFixture(t)
+
+	application, err := f.service.SaveCompany(context.Background(), f.userID, companyDetails())
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.KYBDraft, application.Status)
+	assert.Equal(t, "123456789", application.TaxID)
+	assert.Equal(t, "6789", application.TaxIDLast4)
+	assert.Equal(t, "US", application.IncorporationCountry)
+
+	again, err := f.service.SaveCompany(context.Background(), f.userID, companyDetails())
+	require.NoError(t, err)
+	assert.Equal(t, application.ID, again.ID, "the open draft is reused")
+}
+
+func TestSaveCompanyUnavailableWhenDisabled(t *testing.T) {
+	repo := newFakeRepo()
+	userID := uuid.New()
+	repo.accountTypes[userID] = entities.UserAccountIndividual
+	service := kyb.NewService(repo, &fakeCases{}, kyb.DefaultConfig(), zap.NewNop())
+
+	_, err := service.SaveCompany(context.Background(), userID, companyDetails())
+	assert.ErrorIs(t, err, entities.ErrKYBUnavailable)
+}
+
+func TestAddOwnerSubmitsIndividualKYC(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.service.SaveCompany(context.Background(), f.userID, companyDetails())
+	require.NoError(t, err)
+
+	added, err := f.service.AddOwner(context.Background(), f.userID, owner("ada@acme.example", 60, true))
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{added.ID}, f.verifier.submitted)
+	require.NotNil(t, added.KYCProviderRef)
+	assert.Equal(t, entities.KYCStatusProcessing, added.KYCStatus)
+}
+
+func TestAddOwnerValidatesOwnership(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	_, err := f.service.SaveCompany(ctx, f.userID, companyDetails())
+	require.NoError(t, err)
+
+	_, err = f.service.AddOwner(ctx, f.userID, owner("minor@acme.example", 10, false))
+	assert.ErrorIs(t, err, entities.ErrInvalidBeneficialOwner, "small stakes are only listed for controllers")
+
+	_, err = f.service.AddOwner(ctx, f.userID, owner("ada@acme.example", 60, true))
+	require.NoError(t, err)
+	_, err = f.service.AddOwner(ctx, f.userID, owner("bob@acme.example", 50, false))
+	assert.ErrorIs(t, err, entities.ErrInvalidBeneficialOwner, "ownership cannot exceed 100%")
+	_, err = f.service.AddOwner(ctx, f.userID, owner("ADA@acme.example", 30, false))
+	assert.ErrorIs(t, err, entities.ErrInvalidBeneficialOwner, "an owner is listed once")
+
+	_, err = f.service.AddOwner(ctx, f.userID, owner("cfo@acme.example", 5, true))
+	assert.NoError(t, err)
+}
+
+func TestAddDocumentsReplacesSameType(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	_, err := f.service.SaveCompany(ctx, f.userID, companyDetails())
+	require.NoError(t, err)
+
+	_, err = f.service.AddDocuments(ctx, f.userID, certificate())
+	require.NoError(t, err)
+	replacement := certificate()
+	replacement[0].FileURL = "https://files.example.com/coi-v2.pdf"
+	application, err := f.service.AddDocuments(ctx, f.userID, replacement)
+	require.NoError(t, err)
+
+	require.Len(t, application.Documents, 1)
+	assert.Equal(t, "https://files.example.com/coi-v2.pdf", application.Documents[0].FileURL)
+
+	_, err = f.service.AddDocuments(ctx, f.userID, []entities.KYCDocumentUpload{
+		{Type: "selfie", FileURL: "https://files.example.com/me.jpg", ContentType: "image/jpeg"},
+	})
+	assert.ErrorIs(t, err, entities.ErrInvalidKYBDocument)
+}
+
+func TestSubmitRequiresCompleteApplication(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	_, err := f.service.SaveCompany(ctx, f.userID, companyDetails())
+	require.NoError(t, err)
+
+	_, err = f.service.Submit(ctx, f.userID)
+	assert.ErrorIs(t, err, entities.ErrKYBIncomplete, "certificate of incorporation missing")
+
+	_, err = f.service.AddDocuments(ctx, f.userID, certificate())
+	require.NoError(t, err)
+	_, err = f.service.Submit(ctx, f.userID)
+	assert.ErrorIs(t, err, entities.ErrKYBIncomplete, "no owners")
+
+	_, err = f.service.AddOwner(ctx, f.userID, owner("ada@acme.example", 60, false))
+	require.NoError(t, err)
+	_, err = f.service.Submit(ctx, f.userID)
+	assert.ErrorIs(t, err, entities.ErrKYBIncomplete, "no controller")
+	assert.Empty(t, f.cases.opened)
+}
+
+func TestSubmitRejectsFailedOwner(t *testing.T) {
+	f := newFixture(t)
+	f.completeDraft(t)
+	f.verifier.setAll(entities.KYCStatusRejected)
+
+	_, err := f.service.Submit(context.Background(), f.userID)
+	assert.ErrorIs(t, err, entities.ErrKYBIncomplete)
+}
+
+func TestSubmitOpensBusinessVerificationCase(t *testing.T) {
+	f := newFixture(t)
+	f.completeDraft(t)
+
+	application, err := f.service.Submit(context.Background(), f.userID)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.KYBPendingReview, application.Status)
+	assert.NotNil(t, application.SubmittedAt)
+	assert.NotNil(t, application.CaseID)
+	assert.Equal(t, []entities.AdminCaseType{entities.AdminCaseBusinessVerification}, f.cases.opened)
+
+	_, err = f.service.AddDocuments(context.Background(), f.userID, certificate())
+	assert.ErrorIs(t, err, entities.ErrKYBNotEditable)
+}
+
+func TestApproveWaitsForOwnerVerification(t *testing.T) {
+	f := newFixture(t)
+	f.completeDraft(t)
+	application, err := f.service.Submit(context.Background(), f.userID)
+	require.NoError(t, err)
+	adminID := uuid.New()
+
+	_, err = f.service.Approve(context.Background(), application.ID, adminID, nil)
+	assert.ErrorIs(t, err, entities.ErrKYBOwnersUnverified)
+	assert.Equal(t, entities.UserAccountIndividual, f.repo.accountTypes[f.userID])
+
+	f.verifier.setAll(entities.KYCStatusApproved)
+	approved, err := f.service.Approve(context.Background(), application.ID, adminID, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.KYBApproved, approved.Status)
+	assert.Equal(t, entities.UserAccountBusiness, f.repo.accountTypes[f.userID])
+	assert.Equal(t, kyb.DefaultConfig().Limits, f.repo.applied[f.userID])
+	assert.Len(t, f.cases.resolved, 1)
+
+	_, err = f.service.SaveCompany(context.Background(), f.userID, companyDetails())
+	assert.ErrorIs(t, err, entities.ErrKYBAlreadyBusiness)
+}
+
+func TestRejectAllowsNewApplication(t *testing.T) {
+	f := newFixture(t)
+	f.completeDraft(t)
+	application, err := f.service.Submit(context.Background(), f.userID)
+	require.NoError(t, err)
+
+	rejected, err := f.service.Reject(context.Background(), application.ID, uuid.New(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.KYBRejected, rejected.Status)
+	assert.NotNil(t, rejected.RejectionReason)
+
+	_, err = f.service.Reject(context.Background(), application.ID, uuid.New(), nil)
+	assert.ErrorIs(t, err, entities.ErrKYBNotPending)
+
+	later := time.Now().Add(time.Minute)
+	f.service.SetClock(func() time.Time { return later })
+	next, err := f.service.SaveCompany(context.Background(), f.userID, companyDetails())
+	require.NoError(t, err)
+	assert.NotEqual(t, application.ID, next.ID)
+	assert.Equal(t, entities.KYBDraft, next.Status)
+}
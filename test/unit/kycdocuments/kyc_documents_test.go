@@ -0,0 +1,315 @@
+package kycdocuments_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/onboarding"
+)
+
+type fakeUserRepo struct {
+	user *entities.UserProfile
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *entities.UserProfile) error { return nil }
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error) {
+	copied := *r.user
+	return &copied, nil
+}
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*entities.UserProfile, error) {
+	return r.user, nil
+}
+func (r *fakeUserRepo) GetByAuthProviderID(ctx context.Context, authProviderID string) (*entities.UserProfile, error) {
+	return r.user, nil
+}
+func (r *fakeUserRepo) Update(ctx context.Context, user *entities.UserProfile) error {
+	r.user = user
+	return nil
+}
+func (r *fakeUserRepo) UpdateOnboardingStatus(ctx context.Context, userID uuid.UUID, status entities.OnboardingStatus) error {
+	return nil
+}
+func (r *fakeUserRepo) UpdateKYCStatus(ctx context.Context, userID uuid.UUID, status string, approvedAt *time.Time, rejectionReason *string) error {
+	return nil
+}
+
+type fakeSubmissionRepo struct {
+	submission *entities.KYCSubmission
+	updates    int
+}
+
+func (r *fakeSubmissionRepo) Create(ctx context.Context, submission *entities.KYCSubmission) error {
+	return nil
+}
+func (r *fakeSubmissionRepo) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.KYCSubmission, error) {
+	return []*entities.KYCSubmission{r.submission}, nil
+}
+func (r *fakeSubmissionRepo) GetByProviderRef(ctx context.Context, providerRef string) (*entities.KYCSubmission, error) {
+	return r.submission, nil
+}
+func (r *fakeSubmissionRepo) Update(ctx context.Context, submission *entities.KYCSubmission) error {
+	r.updates++
+	return nil
+}
+func (r *fakeSubmissionRepo) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*entities.KYCSubmission, error) {
+	if r.submission == nil {
+		return nil, entities.ErrKYCSubmissionNotFound
+	}
+	return r.submission, nil
+}
+
+type fakeKYCProvider struct {
+	submitted [][]entities.KYCDocumentUpload
+}
+
+func (p *fakeKYCProvider) SubmitKYC(ctx context.Context, userID uuid.UUID, documents []entities.KYCDocumentUpload, personalInfo *entities.KYCPersonalInfo) (string, error) {
+	p.submitted = append(p.submitted, documents)
+	return "workflow-2", nil
+}
+func (p *fakeKYCProvider) GetKYCStatus(ctx context.Context, providerRef string) (*entities.KYCSubmission, error) {
+	return nil, nil
+}
+func (p *fakeKYCProvider) GenerateKYCURL(ctx context.Context, userID uuid.UUID) (string, error) {
+	return "", nil
+}
+
+type fakeDocumentProvider struct {
+	unsupported  bool
+	resubmitted  []entities.KYCDocumentUpload
+	personalInfo *entities.KYCPersonalInfo
+}
+
+func (p *fakeDocumentProvider) GetDocumentReviews(ctx context.Context, providerRef string, documents []entities.KYCDocument) ([]entities.KYCDocumentReview, error) {
+	return nil, entities.ErrPartialKYCResubmissionUnsupported
+}
+func (p *fakeDocumentProvider) ResubmitDocuments(ctx context.Context, providerRef string, documents []entities.KYCDocumentUpload, personalInfo *entities.KYCPersonalInfo) error {
+	if p.unsupported {
+		return entities.ErrPartialKYCResubmissionUnsupported
+	}
+	p.resubmitted = append(p.resubmitted, documents...)
+	p.personalInfo = personalInfo
+	return nil
+}
+
+type fakeAudit struct{}
+
+func (fakeAudit) LogOnboardingEvent(ctx context.Context, userID uuid.UUID, action, entity string, before, after interface{}) error {
+	return nil
+}
+
+type fixture struct {
+	service     *onboarding.Service
+	users       *fakeUserRepo
+	submissions *fakeSubmissionRepo
+	provider    *fakeKYCProvider
+	documents   *fakeDocumentProvider
+}
+
+// newFixture returns a user whose passport was rejected and whose selfie was
+// accepted. The submission's metadata is in the form read from the database.
+func newFixture() *fixture {
+	userID := uuid.New()
+	reason := "Document is blurry"
+	submission := &entities.KYCSubmission{
+		ID:          uuid.New(),
+		UserID:      userID,
+		ProviderRef: "applicant-1",
+		Status:      entities.KYCStatusRejected,
+		VerificationData: map[string]any{
+			"personal_info": map[string]any{"firstName": "Ada", "lastName": "Lovelace", "country": "GB"},
+		},
+		Documents: []entities.KYCDocument{
+			{Type: "passport", FileURL: "https://files.example/passport.jpg", ContentType: "image/jpeg", Status: entities.KYCDocumentStatusRejected, RejectionReason: &reason, Revision: 1},
+			{Type: "selfie", FileURL: "https://files.example/selfie.jpg", ContentType: "image/jpeg", Status: entities.KYCDocumentStatusAccepted, Revision: 1},
+		},
+	}
+
+	f := &fixture{
+		users:       &fakeUserRepo{user: &entities.UserProfile{ID: userID, KYCStatus: string(entities.KYCStatusRejected)}},
+		submissions: &fakeSubmissionRepo{submission: submission},
+		provider:    &fakeKYCProvider{},
+		documents:   &fakeDocumentProvider{},
+	}
+	f.service = onboarding.NewService(f.users, nil, f.submissions, nil, f.provider, nil, fakeAudit{}, nil, nil, zap.NewNop(), nil)
+	f.service.SetKYCDocumentProvider(f.documents)
+	return f
+}
+
+func (f *fixture) userID() uuid.UUID {
+	return f.submissions.submission.UserID
+}
+
+func newPassport() entities.KYCDocumentUpload {
+	return entities.KYCDocumentUpload{Type: "passport", FileURL: "https://files.example/passport-2.jpg", ContentType: "image/jpeg"}
+}
+
+func TestApplyDocumentReviews(t *testing.T) {
+	now := time.Now()
+	submission := &entities.KYCSubmission{
+		Documents:        entities.NewKYCDocuments([]entities.KYCDocumentUpload{{Type: "passport"}, {Type: "selfie"}}, now),
+		RejectionReasons: []string{"BAD_PROOF_OF_IDENTITY"},
+	}
+
+	submission.ApplyDocumentReviews(entities.KYCStatusRejected, []entities.KYCDocumentReview{
+		{Type: "PASSPORT", Accepted: false, Reason: "Document is expired"},
+	}, now)
+	assert.Equal(t, entities.KYCDocumentStatusRejected, submission.Document("passport").Status)
+	require.NotNil(t, submission.Document("passport").RejectionReason)
+	assert.Equal(t, "Document is expired", *submission.Document("passport").RejectionReason)
+	assert.Equal(t, entities.KYCDocumentStatusAccepted, submission.Document("selfie").Status, "documents the provider did not flag are accepted")
+	assert.Equal(t, []string{"passport"}, submission.PendingReplacements())
+
+	whole := &entities.KYCSubmission{
+		Documents:        entities.NewKYCDocuments([]entities.KYCDocumentUpload{{Type: "passport"}, {Type: "selfie"}}, now),
+		RejectionReasons: []string{"BAD_PROOF_OF_IDENTITY"},
+	}
+	whole.ApplyDocumentReviews(entities.KYCStatusRejected, nil, now)
+	assert.Equal(t, []string{"passport", "selfie"}, whole.PendingReplacements(), "without per-document results every document is rejected")
+	assert.Equal(t, "BAD_PROOF_OF_IDENTITY", *whole.Document("selfie").RejectionReason)
+}
+
+func TestReplaceKYCDocument_ResubmitsOnlyTheReplacedDocument(t *testing.T) {
+	f := newFixture()
+
+	submission, err := f.service.ReplaceKYCDocument(context.Background(), f.userID(), newPassport())
+	require.NoError(t, err)
+
+	require.Len(t, f.documents.resubmitted, 1)
+	assert.Equal(t, "https://files.example/passport-2.jpg", f.documents.resubmitted[0].FileURL)
+	require.NotNil(t, f.documents.personalInfo)
+	assert.Equal(t, "Ada", f.documents.personalInfo.FirstName)
+	assert.Empty(t, f.provider.submitted, "no new application is started")
+
+	assert.Equal(t, entities.KYCStatusProcessing, submission.Status)
+	assert.Equal(t, "applicant-1", submission.ProviderRef)
+	assert.Equal(t, 2, submission.Document("passport").Revision)
+	assert.Nil(t, submission.Document("passport").RejectionReason)
+	assert.Equal(t, entities.KYCDocumentStatusAccepted, submission.Document("selfie").Status)
+	assert.Equal(t, string(entities.KYCStatusProcessing), f.users.user.KYCStatus)
+}
+
+func TestReplaceKYCDocument_FallsBackToFullResubmission(t *testing.T) {
+	f := newFixture()
+	f.documents.unsupported = true
+
+	submission, err := f.service.ReplaceKYCDocument(context.Background(), f.userID(), newPassport())
+	require.NoError(t, err)
+
+	require.Len(t, f.provider.submitted, 1)
+	assert.Len(t, f.provider.submitted[0], 2, "every document goes into the new application")
+	assert.Equal(t, "workflow-2", submission.ProviderRef)
+	assert.Equal(t, entities.KYCDocumentStatusSubmitted, submission.Document("selfie").Status)
+	require.NotNil(t, f.users.user.KYCProviderRef)
+	assert.Equal(t, "workflow-2", *f.users.user.KYCProviderRef)
+}
+
+func TestReplaceKYCDocument_WaitsForEveryRejectedDocument(t *testing.T) {
+	f := newFixture()
+	f.submissions.submission.Document("selfie").Status = entities.KYCDocumentStatusRejected
+
+	_, err := f.service.DeleteKYCDocument(context.Background(), f.userID(), "selfie")
+	require.NoError(t, err)
+	assert.Equal(t, entities.KYCDocumentStatusDeleted, f.submissions.submission.Document("selfie").Status)
+	assert.Empty(t, f.submissions.submission.Document("selfie").FileURL)
+
+	submission, err := f.service.ReplaceKYCDocument(context.Background(), f.userID(), newPassport())
+	require.NoError(t, err)
+	assert.Equal(t, entities.KYCStatusRejected, submission.Status)
+	assert.Equal(t, []string{"selfie"}, submission.PendingReplacements())
+	assert.Empty(t, f.documents.resubmitted)
+
+	_, err = f.service.ReplaceKYCDocument(context.Background(), f.userID(), entities.KYCDocumentUpload{
+		Type: "selfie", FileURL: "https://files.example/selfie-2.jpg", ContentType: "image/jpeg",
+	})
+	require.NoError(t, err)
+	assert.Len(t, f.documents.resubmitted, 2)
+	assert.Equal(t, entities.KYCStatusProcessing, f.submissions.submission.Status)
+}
+
+func TestReplaceKYCDocument_RejectsDocumentsThatWereNotRejected(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	_, err := f.service.ReplaceKYCDocument(ctx, f.userID(), entities.KYCDocumentUpload{Type: "selfie", FileURL: "https://files.example/x.jpg"})
+	assert.ErrorIs(t, err, entities.ErrKYCDocumentNotRejected)
+
+	_, err = f.service.DeleteKYCDocument(ctx, f.userID(), "selfie")
+	assert.ErrorIs(t, err, entities.ErrKYCDocumentNotRejected)
+
+	_, err = f.service.ReplaceKYCDocument(ctx, f.userID(), entities.KYCDocumentUpload{Type: "utility_bill"})
+	assert.ErrorIs(t, err, entities.ErrKYCDocumentNotFound)
+
+	assert.Zero(t, f.submissions.updates)
+}
+
+type fakeVault struct {
+	records  map[string][]byte
+	purposes []string
+}
+
+func (v *fakeVault) Tokenize(ctx context.Context, subjectID uuid.UUID, kind entities.PIIKind, value any) (string, error) {
+	token := "pii_" + uuid.NewString()
+	return token, v.Update(ctx, token, value)
+}
+func (v *fakeVault) Update(ctx context.Context, token string, value any) error {
+	data, err := json.Marshal(value)
+	v.records[token] = data
+	return err
+}
+func (v *fakeVault) Reveal(ctx context.Context, token, purpose string, dest any) error {
+	v.purposes = append(v.purposes, purpose)
+	return json.Unmarshal(v.records[token], dest)
+}
+
+func (v *fakeVault) record(t *testing.T, token string) entities.KYCVaultRecord {
+	var record entities.KYCVaultRecord
+	require.NoError(t, json.Unmarshal(v.records[token], &record))
+	return record
+}
+
+func TestReplaceKYCDocument_ReadsTokenizedDataFromVault(t *testing.T) {
+	f := newFixture()
+	vault := &fakeVault{records: map[string][]byte{}}
+	f.service.SetPIIVault(vault)
+
+	token, err := vault.Tokenize(context.Background(), f.userID(), entities.PIIKindKYCIdentity, entities.KYCVaultRecord{
+		PersonalInfo: &entities.KYCPersonalInfo{FirstName: "Ada", LastName: "Lovelace", Country: "GB"},
+		DocumentURLs: map[string]string{"passport": "https://files.example/passport.jpg", "selfie": "https://files.example/selfie.jpg"},
+	})
+	require.NoError(t, err)
+	stored := f.submissions.submission
+	stored.PIIToken = &token
+	stored.VerificationData = map[string]any{"document_type": "passport"}
+	for i := range stored.Documents {
+		stored.Documents[i].FileURL = ""
+	}
+	f.documents.unsupported = true
+
+	submission, err := f.service.ReplaceKYCDocument(context.Background(), f.userID(), newPassport())
+	require.NoError(t, err)
+
+	require.Len(t, f.provider.submitted, 1)
+	urls := map[string]string{}
+	for _, doc := range f.provider.submitted[0] {
+		urls[doc.Type] = doc.FileURL
+	}
+	assert.Equal(t, map[string]string{
+		"passport": "https://files.example/passport-2.jpg",
+		"selfie":   "https://files.example/selfie.jpg",
+	}, urls, "the provider gets links from the vault")
+	assert.NotEmpty(t, vault.purposes)
+
+	assert.Empty(t, submission.Document("passport").FileURL, "the new link is not stored on the submission")
+	assert.NotContains(t, submission.VerificationData, "personal_info")
+	record := vault.record(t, token)
+	assert.Equal(t, "https://files.example/passport-2.jpg", record.DocumentURLs["passport"])
+	assert.Equal(t, "Ada", record.PersonalInfo.FirstName)
+}
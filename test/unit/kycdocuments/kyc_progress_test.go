@@ -0,0 +1,83 @@
+package kycdocuments_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+)
+
+type fakeNotifier struct {
+	sent []*entities.Notification
+}
+
+func (n *fakeNotifier) Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestMapKYCWebhook(t *testing.T) {
+	cases := []struct {
+		name      string
+		payload   map[string]interface{}
+		status    entities.KYCStatus
+		subStatus entities.KYCSubStatus
+	}{
+		{"applicant created", map[string]interface{}{"type": "applicantCreated"}, entities.KYCStatusProcessing, entities.KYCSubStatusDocumentsRequested},
+		{"applicant pending", map[string]interface{}{"type": "applicantPending"}, entities.KYCStatusProcessing, entities.KYCSubStatusUnderReview},
+		{"applicant on hold", map[string]interface{}{"type": "applicantOnHold", "reviewStatus": "onHold"}, entities.KYCStatusProcessing, entities.KYCSubStatusOnHold},
+		{"green answer", map[string]interface{}{"type": "applicantReviewed", "reviewResult": map[string]interface{}{"reviewAnswer": "GREEN"}}, entities.KYCStatusApproved, entities.KYCSubStatusNone},
+		{"final rejection", map[string]interface{}{"type": "applicantReviewed", "reviewResult": map[string]interface{}{"reviewAnswer": "RED", "reviewRejectType": "FINAL"}}, entities.KYCStatusRejected, entities.KYCSubStatusNone},
+		{"retry rejection", map[string]interface{}{"type": "applicantReviewed", "reviewResult": map[string]interface{}{"reviewAnswer": "RED", "reviewRejectType": "RETRY"}}, entities.KYCStatusRejected, entities.KYCSubStatusResubmissionRequired},
+		{"plain status", map[string]interface{}{"status": "approved"}, entities.KYCStatusApproved, entities.KYCSubStatusNone},
+		{"plain sub-status", map[string]interface{}{"status": "documents_requested"}, entities.KYCStatusProcessing, entities.KYCSubStatusDocumentsRequested},
+		{"unknown event", map[string]interface{}{"type": "applicantPersonalInfoChanged"}, entities.KYCStatusProcessing, entities.KYCSubStatusNone},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			update := adapters.MapKYCWebhook(tc.payload)
+			assert.Equal(t, tc.status, update.Status)
+			assert.Equal(t, tc.subStatus, update.SubStatus)
+		})
+	}
+
+	update := adapters.MapKYCWebhook(map[string]interface{}{
+		"type": "applicantReviewed",
+		"reviewResult": map[string]interface{}{
+			"reviewAnswer":      "RED",
+			"reviewRejectType":  "RETRY",
+			"moderationComment": "Your passport photo is blurry",
+			"rejectLabels":      []interface{}{"BAD_PROOF_OF_IDENTITY"},
+		},
+	})
+	assert.Equal(t, []string{"Your passport photo is blurry", "BAD_PROOF_OF_IDENTITY"}, update.RejectionReasons)
+}
+
+func TestProcessKYCCallback_RecordsIntermediateSteps(t *testing.T) {
+	f := newFixture()
+	notifier := &fakeNotifier{}
+	f.service.SetNotifier(notifier)
+	f.submissions.submission.Status = entities.KYCStatusProcessing
+	ctx := context.Background()
+
+	update := entities.KYCProviderUpdate{Event: "applicantActionPending", Status: entities.KYCStatusProcessing, SubStatus: entities.KYCSubStatusDocumentsRequested}
+	require.NoError(t, f.service.ProcessKYCCallback(ctx, "applicant-1", update))
+	assert.Equal(t, entities.KYCSubStatusDocumentsRequested, f.submissions.submission.SubStatus)
+	assert.Nil(t, f.submissions.submission.ReviewedAt, "an intermediate step is not a review decision")
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, entities.ChannelPush, notifier.sent[0].Channel)
+	assert.Equal(t, entities.KYCProgressMessage(entities.KYCStatusProcessing, entities.KYCSubStatusDocumentsRequested), notifier.sent[0].Message)
+
+	require.NoError(t, f.service.ProcessKYCCallback(ctx, "applicant-1", update))
+	assert.Len(t, notifier.sent, 1, "a repeated webhook is not announced again")
+	assert.Equal(t, 1, f.submissions.updates)
+
+	update = entities.KYCProviderUpdate{Event: "applicantPending", Status: entities.KYCStatusProcessing, SubStatus: entities.KYCSubStatusUnderReview}
+	require.NoError(t, f.service.ProcessKYCCallback(ctx, "applicant-1", update))
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, entities.ChannelInApp, notifier.sent[1].Channel)
+}
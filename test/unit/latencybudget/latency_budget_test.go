@@ -0,0 +1,122 @@
+package latencybudget_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/api/middleware"
+)
+
+func newRouter(config middleware.LatencyBudgetConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.LatencyBudget(config, zap.NewNop()))
+	return router
+}
+
+func serve(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestLatencyBudget_AppliesLongestMatchingRouteBudget(t *testing.T) {
+	router := newRouter(middleware.LatencyBudgetConfig{
+		DefaultTimeout: 30 * time.Second,
+		Routes: []middleware.RouteTimeout{
+			{Path: "/api/v1/admin", Timeout: 10 * time.Second},
+			{Path: "/api/v1/admin/reports", Timeout: 20 * time.Second},
+			{Method: http.MethodPost, Path: "/api/v1/admin", Timeout: 5 * time.Second},
+			{Path: "/api/v1/stream", Timeout: 0},
+		},
+	})
+	budget := func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		if !ok {
+			c.String(http.StatusOK, "none")
+			return
+		}
+		c.String(http.StatusOK, time.Until(deadline).Round(time.Second).String())
+	}
+	router.GET("/api/v1/admin/users", budget)
+	router.POST("/api/v1/admin/users", budget)
+	router.GET("/api/v1/admin/reports/:id", budget)
+	router.GET("/api/v1/stream", budget)
+	router.GET("/api/v1/orders", budget)
+
+	assert.Equal(t, "10s", serve(router, http.MethodGet, "/api/v1/admin/users").Body.String())
+	assert.Equal(t, "5s", serve(router, http.MethodPost, "/api/v1/admin/users").Body.String())
+	assert.Equal(t, "20s", serve(router, http.MethodGet, "/api/v1/admin/reports/1").Body.String())
+	assert.Equal(t, "none", serve(router, http.MethodGet, "/api/v1/stream").Body.String())
+	assert.Equal(t, "30s", serve(router, http.MethodGet, "/api/v1/orders").Body.String())
+}
+
+func TestLatencyBudget_WritesGatewayTimeoutWhenHandlerRunsOut(t *testing.T) {
+	router := newRouter(middleware.LatencyBudgetConfig{DefaultTimeout: 20 * time.Millisecond})
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	router.GET("/answered", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": c.Request.Context().Err().Error()})
+	})
+
+	w := serve(router, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "REQUEST_TIMEOUT")
+
+	// A handler that already responded keeps its own response
+	assert.Equal(t, http.StatusInternalServerError, serve(router, http.MethodGet, "/answered").Code)
+}
+
+func TestLatencyBudget_ShedsRequestsBeyondInFlightLimit(t *testing.T) {
+	router := newRouter(middleware.LatencyBudgetConfig{
+		MaxInFlight: 1,
+		RetryAfter:  1500 * time.Millisecond,
+		ShedExempt:  []string{"/health"},
+	})
+	started, release := make(chan struct{}), make(chan struct{})
+	router.GET("/work", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/other", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var first *httptest.ResponseRecorder
+	go func() {
+		defer wg.Done()
+		first = serve(router, http.MethodGet, "/work")
+	}()
+	<-started
+
+	shed := serve(router, http.MethodGet, "/other")
+	assert.Equal(t, http.StatusServiceUnavailable, shed.Code)
+	assert.Equal(t, "2", shed.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/health").Code, "health checks are never shed")
+
+	close(release)
+	wg.Wait()
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/other").Code, "capacity frees up once requests finish")
+}
+
+func TestLatencyBudget_ShedsWhenGoroutinesExceedThreshold(t *testing.T) {
+	router := newRouter(middleware.LatencyBudgetConfig{MaxGoroutines: 1})
+	router.GET("/work", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := serve(router, http.MethodGet, "/work")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
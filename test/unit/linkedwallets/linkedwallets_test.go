@@ -0,0 +1,317 @@
+package linkedwallets_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/adapters/due"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/linkedwallets"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+type fakeRepo struct {
+	wallets map[uuid.UUID]*entities.LinkedWallet
+}
+
+func (r *fakeRepo) Create(ctx context.Context, wallet *entities.LinkedWallet) error {
+	r.wallets[wallet.ID] = wallet
+	return nil
+}
+
+func (r *fakeRepo) Get(ctx context.Context, userID, id uuid.UUID) (*entities.LinkedWallet, error) {
+	wallet, ok := r.wallets[id]
+	if !ok || wallet.UserID != userID {
+		return nil, entities.ErrLinkedWalletNotFound
+	}
+	return wallet, nil
+}
+
+func (r *fakeRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.LinkedWallet, error) {
+	var wallets []*entities.LinkedWallet
+	for _, wallet := range r.wallets {
+		if wallet.UserID == userID {
+			wallets = append(wallets, wallet)
+		}
+	}
+	return wallets, nil
+}
+
+func (r *fakeRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := r.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	delete(r.wallets, id)
+	return nil
+}
+
+func (r *fakeRepo) Sync(ctx context.Context, userID uuid.UUID, wallets []*entities.LinkedWallet) error {
+	for id, wallet := range r.wallets {
+		if wallet.UserID == userID {
+			delete(r.wallets, id)
+		}
+	}
+	for _, wallet := range wallets {
+		wallet.UserID = userID
+		r.wallets[wallet.ID] = wallet
+	}
+	return nil
+}
+
+type fakeDue struct {
+	accountID string
+	wallets   []due.LinkWalletResponse
+	listErr   error
+	linked    []string
+}
+
+func (d *fakeDue) ListAccountWallets(ctx context.Context, accountID string) (*due.ListWalletsResponse, error) {
+	if d.listErr != nil {
+		return nil, d.listErr
+	}
+	d.accountID = accountID
+	return &due.ListWalletsResponse{Data: d.wallets, Total: len(d.wallets)}, nil
+}
+
+func (d *fakeDue) LinkAccountWallet(ctx context.Context, accountID string, req *due.LinkWalletRequest) (*due.LinkWalletResponse, error) {
+	d.accountID = accountID
+	d.linked = append(d.linked, req.Address)
+	wallet := due.LinkWalletResponse{ID: "due-" + uuid.NewString()[:8], Address: req.Address}
+	d.wallets = append(d.wallets, wallet)
+	return &wallet, nil
+}
+
+func (d *fakeDue) UnlinkAccountWallet(ctx context.Context, accountID, walletID string) error {
+	for i, wallet := range d.wallets {
+		if wallet.ID == walletID {
+			d.wallets = append(d.wallets[:i], d.wallets[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("wallet not found")
+}
+
+type fakeUsers map[uuid.UUID]string
+
+func (u fakeUsers) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error) {
+	profile := &entities.UserProfile{ID: id}
+	if account, ok := u[id]; ok {
+		profile.DueAccountID = &account
+	}
+	return profile, nil
+}
+
+var now = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+// enableEVM turns on the EVM catalog chains for the test
+func enableEVM(t *testing.T) {
+	previous := entities.Chains()
+	chains := entities.DefaultChains()
+	for i := range chains {
+		if chains[i].ID == "ETH" {
+			chains[i].Enabled = true
+		}
+	}
+	registry, err := entities.NewChainRegistry(chains)
+	require.NoError(t, err)
+	entities.SetChainRegistry(registry)
+	t.Cleanup(func() { entities.SetChainRegistry(previous) })
+}
+
+func newService(users fakeUsers) (*linkedwallets.Service, *fakeRepo, *fakeDue) {
+	repo := &fakeRepo{wallets: map[uuid.UUID]*entities.LinkedWallet{}}
+	dueWallets := &fakeDue{}
+	service := linkedwallets.NewService(repo, dueWallets, users,
+		linkedwallets.Config{ChallengeSecret: []byte("challenge-secret")}, zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	return service, repo, dueWallets
+}
+
+func solanaWallet(t *testing.T) (string, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return crypto.EncodeBase58(pub), priv
+}
+
+func TestLink_SolanaWalletWithSignedChallenge(t *testing.T) {
+	userID := uuid.New()
+	service, repo, dueWallets := newService(fakeUsers{userID: "acct_1"})
+	address, key := solanaWallet(t)
+	ctx := context.Background()
+
+	challenge, err := service.Challenge(ctx, userID, &entities.WalletChallengeRequest{Chain: "sol-devnet", Address: address})
+	require.NoError(t, err)
+	assert.Equal(t, entities.ChainSOLDevnet, challenge.Chain)
+	assert.Equal(t, now.Add(10*time.Minute), challenge.ExpiresAt)
+	signature := crypto.EncodeBase58(ed25519.Sign(key, []byte(challenge.Message)))
+
+	label := "Phantom"
+	wallet, err := service.Link(ctx, userID, &entities.LinkExternalWalletRequest{
+		Chain: "SOL-DEVNET", Address: address, Message: challenge.Message, Signature: signature, Label: &label,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.LinkedWalletSourceSelfCustody, wallet.Source)
+	assert.Equal(t, "acct_1", dueWallets.accountID, "linked on the user's own Due account")
+	assert.Equal(t, []string{"solana:" + address}, dueWallets.linked)
+	assert.Len(t, repo.wallets, 1)
+
+	_, err = service.Link(ctx, userID, &entities.LinkExternalWalletRequest{
+		Chain: "SOL-DEVNET", Address: address, Message: challenge.Message, Signature: signature,
+	})
+	assert.ErrorIs(t, err, entities.ErrLinkedWalletExists)
+}
+
+func TestLink_EVMWalletWithPersonalSign(t *testing.T) {
+	enableEVM(t)
+	userID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	service, _, dueWallets := newService(fakeUsers{userID: "acct_1"})
+	// Signed with private key 1, whose address is well known
+	address := "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+	signature := "0x0492f31d358518aac46a0fd9a090b96374a74b10fdac4bc6be882bab6d508dd5" +
+		"eb1ffa7b71155adc998253ddcb3ed4633dd6fb5eb1b59a7cb40c3ac1b0da51861b"
+	ctx := context.Background()
+
+	challenge, err := service.Challenge(ctx, userID, &entities.WalletChallengeRequest{Chain: "ethereum", Address: address})
+	require.NoError(t, err)
+	assert.True(t, crypto.VerifyEVMSignature(strings.ToLower(address), challenge.Message, signature))
+	assert.False(t, crypto.VerifyEVMSignature(address, challenge.Message+" ", signature))
+
+	wallet, err := service.Link(ctx, userID, &entities.LinkExternalWalletRequest{
+		Chain: "ETH", Address: address, Message: challenge.Message, Signature: signature,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.WalletChain("ETH"), wallet.Chain)
+	assert.Equal(t, []string{"evm:" + address}, dueWallets.linked)
+}
+
+func TestLink_RejectsUnprovenOwnership(t *testing.T) {
+	userID := uuid.New()
+	service, repo, dueWallets := newService(fakeUsers{userID: "acct_1"})
+	address, key := solanaWallet(t)
+	other, otherKey := solanaWallet(t)
+	ctx := context.Background()
+
+	challenge, err := service.Challenge(ctx, userID, &entities.WalletChallengeRequest{Chain: "SOL-DEVNET", Address: address})
+	require.NoError(t, err)
+	sign := func(k ed25519.PrivateKey, message string) string {
+		return crypto.EncodeBase58(ed25519.Sign(k, []byte(message)))
+	}
+	link := func(req entities.LinkExternalWalletRequest) error {
+		req.Chain = "SOL-DEVNET"
+		_, err := service.Link(ctx, userID, &req)
+		return err
+	}
+
+	// Signed by a different key
+	assert.ErrorIs(t, link(entities.LinkExternalWalletRequest{Address: address, Message: challenge.Message,
+		Signature: sign(otherKey, challenge.Message)}), linkedwallets.ErrSignatureInvalid)
+	// A challenge issued for another address
+	assert.ErrorIs(t, link(entities.LinkExternalWalletRequest{Address: other, Message: challenge.Message,
+		Signature: sign(otherKey, challenge.Message)}), linkedwallets.ErrChallengeInvalid)
+	// A tampered challenge
+	tampered := strings.Replace(challenge.Message, "Issued At: 2026-10-16T09:00:00Z", "Issued At: 2026-10-16T09:05:00Z", 1)
+	assert.ErrorIs(t, link(entities.LinkExternalWalletRequest{Address: address, Message: tampered,
+		Signature: sign(key, tampered)}), linkedwallets.ErrChallengeInvalid)
+	// A challenge issued to another user
+	otherUser, _, _ := newService(nil)
+	foreign, err := otherUser.Challenge(ctx, uuid.New(), &entities.WalletChallengeRequest{Chain: "SOL-DEVNET", Address: address})
+	require.NoError(t, err)
+	assert.ErrorIs(t, link(entities.LinkExternalWalletRequest{Address: address, Message: foreign.Message,
+		Signature: sign(key, foreign.Message)}), linkedwallets.ErrChallengeInvalid)
+
+	// An expired challenge
+	service.SetClock(func() time.Time { return now.Add(11 * time.Minute) })
+	assert.ErrorIs(t, link(entities.LinkExternalWalletRequest{Address: address, Message: challenge.Message,
+		Signature: sign(key, challenge.Message)}), linkedwallets.ErrChallengeInvalid)
+
+	assert.Empty(t, dueWallets.linked)
+	assert.Empty(t, repo.wallets)
+}
+
+func TestChallenge_ValidatesChainAndAddress(t *testing.T) {
+	service, _, _ := newService(nil)
+	ctx := context.Background()
+
+	_, err := service.Challenge(ctx, uuid.New(), &entities.WalletChallengeRequest{Chain: "ETH", Address: "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"})
+	assert.ErrorIs(t, err, linkedwallets.ErrUnsupportedChain, "chains that are not enabled")
+	_, err = service.Challenge(ctx, uuid.New(), &entities.WalletChallengeRequest{Chain: "SOL-DEVNET", Address: "0x7E5F"})
+	assert.ErrorIs(t, err, linkedwallets.ErrInvalidAddress)
+}
+
+func TestList_MirrorsDueAccount(t *testing.T) {
+	userID := uuid.New()
+	service, repo, dueWallets := newService(fakeUsers{userID: "acct_1"})
+	address, key := solanaWallet(t)
+	ctx := context.Background()
+
+	challenge, err := service.Challenge(ctx, userID, &entities.WalletChallengeRequest{Chain: "SOL-DEVNET", Address: address})
+	require.NoError(t, err)
+	linked, err := service.Link(ctx, userID, &entities.LinkExternalWalletRequest{Chain: "SOL-DEVNET", Address: address,
+		Message: challenge.Message, Signature: crypto.EncodeBase58(ed25519.Sign(key, []byte(challenge.Message)))})
+	require.NoError(t, err)
+
+	// The provisioning worker linked our custodial wallet directly with Due
+	platformAddress, _ := solanaWallet(t)
+	dueWallets.wallets = append(dueWallets.wallets, due.LinkWalletResponse{
+		ID: "due-platform", Address: "solana:" + platformAddress, Blockchain: "solana",
+	})
+
+	wallets, err := service.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, wallets, 2)
+	sources := map[string]entities.LinkedWalletSource{}
+	for _, wallet := range wallets {
+		sources[wallet.Address] = wallet.Source
+	}
+	assert.Equal(t, entities.LinkedWalletSourceSelfCustody, sources[address])
+	assert.Equal(t, entities.LinkedWalletSourcePlatform, sources[platformAddress])
+
+	// Platform wallets stay linked
+	for _, wallet := range wallets {
+		if wallet.Source == entities.LinkedWalletSourcePlatform {
+			_, err = service.Unlink(ctx, userID, wallet.ID)
+			assert.ErrorIs(t, err, linkedwallets.ErrPlatformWallet)
+		}
+	}
+
+	// A wallet removed at Due drops out of the mirror
+	dueWallets.wallets = dueWallets.wallets[:1]
+	wallets, err = service.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, wallets, 1)
+	assert.Equal(t, address, wallets[0].Address)
+
+	// With Due unreachable the stored list is served
+	dueWallets.listErr = errors.New("due down")
+	wallets, err = service.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, wallets, 1)
+
+	_, err = service.Unlink(ctx, userID, linked.ID)
+	require.NoError(t, err)
+	assert.Empty(t, repo.wallets)
+	assert.Empty(t, dueWallets.wallets)
+}
+
+func TestLink_RequiresDueAccount(t *testing.T) {
+	userID := uuid.New()
+	service, _, _ := newService(fakeUsers{})
+	address, key := solanaWallet(t)
+	ctx := context.Background()
+
+	challenge, err := service.Challenge(ctx, userID, &entities.WalletChallengeRequest{Chain: "SOL-DEVNET", Address: address})
+	require.NoError(t, err)
+	_, err = service.Link(ctx, userID, &entities.LinkExternalWalletRequest{Chain: "SOL-DEVNET", Address: address,
+		Message: challenge.Message, Signature: crypto.EncodeBase58(ed25519.Sign(key, []byte(challenge.Message)))})
+	assert.ErrorIs(t, err, linkedwallets.ErrNoDueAccount)
+}
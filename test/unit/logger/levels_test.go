@@ -0,0 +1,101 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestModuleLevelOverrideReverts(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	levels := logger.NewLevels(zapcore.InfoLevel)
+	due := levels.Wrap(zap.New(core), "due")
+	circle := levels.Wrap(zap.New(core), "circle")
+
+	due.Debug("hidden")
+	assert.Equal(t, 0, logs.Len())
+
+	updated, err := levels.Override("due", zapcore.DebugLevel, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "debug", updated.Level)
+	require.NotNil(t, updated.ExpiresAt)
+
+	due.Debug("quote request")
+	circle.Debug("still hidden")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "due", logs.All()[0].LoggerName)
+
+	require.Eventually(t, func() bool {
+		return !due.Core().Enabled(zapcore.DebugLevel)
+	}, time.Second, 5*time.Millisecond)
+	due.Debug("hidden again")
+	assert.Equal(t, 1, logs.Len())
+
+	_, err = levels.Override("kyc", zapcore.DebugLevel, time.Minute)
+	assert.ErrorIs(t, err, logger.ErrUnknownModule)
+	_, err = levels.Override("due", zapcore.DebugLevel, 0)
+	assert.Error(t, err)
+}
+
+func TestModuleLevelsFollowConfigAndBase(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	levels := logger.NewLevels(zapcore.InfoLevel)
+
+	// Configured before the logger exists, as the config file is read first
+	levels.Configure(map[string]zapcore.Level{"alpaca": zapcore.WarnLevel})
+	alpaca := levels.Wrap(zap.New(core), "alpaca")
+	due := levels.Wrap(zap.New(core), "due")
+
+	alpaca.Info("hidden")
+	due.Info("shown")
+	assert.Equal(t, 1, logs.Len())
+
+	// An override wins over the configured level and reverts to it
+	_, err := levels.Override("alpaca", zapcore.DebugLevel, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, alpaca.Core().Enabled(zapcore.DebugLevel))
+	reverted, err := levels.Revert("alpaca")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", reverted.Level)
+	assert.Nil(t, reverted.ExpiresAt)
+
+	// Modules without a level of their own follow the base
+	levels.SetBase(zapcore.ErrorLevel)
+	assert.False(t, due.Core().Enabled(zapcore.WarnLevel))
+	assert.True(t, alpaca.Core().Enabled(zapcore.WarnLevel))
+
+	levels.Configure(nil)
+	assert.False(t, alpaca.Core().Enabled(zapcore.WarnLevel))
+
+	listed := levels.List()
+	require.Len(t, listed, 2)
+	assert.Equal(t, "alpaca", listed[0].Module)
+	assert.Equal(t, "error", listed[1].Level)
+}
+
+func TestModuleLoggerDerivedFromModuleLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	levels := logger.NewLevels(zapcore.WarnLevel)
+	adapter := levels.Wrap(zap.New(core), "adapter")
+	client := levels.Wrap(adapter, "client")
+	_, err := levels.Override("client", zapcore.DebugLevel, time.Minute)
+	require.NoError(t, err)
+
+	// The adapter's level does not hold back the client derived from it
+	client.Debug("shown")
+	adapter.Debug("hidden")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "adapter.client", logs.All()[0].LoggerName)
+}
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := logger.ParseModuleLevels(map[string]string{"due": "DEBUG", "circle": "loud"})
+	assert.Error(t, err)
+	assert.Equal(t, map[string]zapcore.Level{"due": zapcore.DebugLevel}, levels)
+}
@@ -0,0 +1,149 @@
+package logger_test
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stack-service/stack_service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactionForEnvironment(t *testing.T) {
+	assert.False(t, logger.RedactionForEnvironment("development"))
+	assert.False(t, logger.RedactionForEnvironment("test"))
+	assert.True(t, logger.RedactionForEnvironment("staging"))
+	assert.True(t, logger.RedactionForEnvironment("production"))
+	assert.True(t, logger.RedactionForEnvironment(""))
+}
+
+func TestPIIFieldHelpers(t *testing.T) {
+	defer logger.SetRedaction(logger.RedactionEnabled())
+
+	t.Run("development keeps raw values", func(t *testing.T) {
+		logger.SetRedaction(false)
+
+		assert.Equal(t, "jane@example.com", logger.Email("jane@example.com").String)
+		assert.Equal(t, "to", logger.EmailKey("to", "jane@example.com").Key)
+		assert.Equal(t, "+15551234567", logger.Phone("+15551234567").String)
+		assert.Equal(t, "0x1234567890abcdef1234", logger.WalletAddr("0x1234567890abcdef1234").String)
+		assert.Equal(t, "1234.56", logger.Amount("amount", decimal.RequireFromString("1234.56")).String)
+	})
+
+	t.Run("production masks values", func(t *testing.T) {
+		logger.SetRedaction(true)
+
+		email := logger.Email("jane@example.com").String
+		assert.True(t, strings.HasPrefix(email, "j***@example.com#"))
+		assert.NotContains(t, email, "jane")
+		assert.Equal(t, email, logger.Email("jane@example.com").String, "hash must be stable for correlation")
+		assert.Equal(t, email, logger.EmailKey("to", "jane@example.com").String)
+
+		assert.Equal(t, "+1*******567", logger.Phone("+15551234567").String)
+		assert.Equal(t, "0x1234...1234", logger.WalletAddr("0x1234567890abcdef1234").String)
+		assert.Equal(t, "1K-10K", logger.Amount("amount", decimal.RequireFromString("1234.56")).String)
+		assert.Equal(t, "-<10", logger.Amount("amount", decimal.RequireFromString("-5")).String)
+	})
+}
+
+// TestNoRawPIILogFields flags direct zap.String usage for PII keys, including
+// the recipients of messages. Use logger.Email, logger.EmailKey, logger.Phone
+// and logger.WalletAddr instead so values are redacted per environment.
+func TestNoRawPIILogFields(t *testing.T) {
+	rawPII := regexp.MustCompile(`zap\.String\("(email|phone|address|wallet_address|to|recipient)",\s*([^)]*)`)
+
+	violations := findLogFields(t, rawPII, func(line string, m []string) bool {
+		// Values already passed through an explicit mask helper are allowed
+		return strings.Contains(strings.ToLower(m[2]), "mask")
+	})
+	assert.Empty(t, violations, "raw PII log fields found; use logger.Email/EmailKey/Phone/WalletAddr:\n%s", strings.Join(violations, "\n"))
+}
+
+// TestNoRawAmountLogFields flags amounts logged as their exact value, either
+// as zap fields or as key/value pairs. Use logger.Amount or
+// logger.RedactAmount instead.
+func TestNoRawAmountLogFields(t *testing.T) {
+	rawAmount := regexp.MustCompile(`(zap\.String\(|^\s*|, )"(\w*amount\w*)",\s*[\w.]+\.(String\(\)|StringFixed\(\d+\))`)
+
+	violations := findLogFields(t, rawAmount, func(line string, m []string) bool {
+		// Span attributes are not logs
+		return strings.Contains(line, "attribute.")
+	})
+	assert.Empty(t, violations, "raw amount log fields found; use logger.Amount/RedactAmount:\n%s", strings.Join(violations, "\n"))
+}
+
+// TestNoRawTokenLogFields flags tokens logged as zap strings. Use
+// logger.Token, which logs only a hash of the token.
+func TestNoRawTokenLogFields(t *testing.T) {
+	rawToken := regexp.MustCompile(`zap\.(String|Any)\("(\w*token)",`)
+
+	violations := findLogFields(t, rawToken, func(string, []string) bool { return false })
+	assert.Empty(t, violations, "raw token log fields found; use logger.Token:\n%s", strings.Join(violations, "\n"))
+}
+
+func TestAmountAndTokenHelpers(t *testing.T) {
+	defer logger.SetRedaction(logger.RedactionEnabled())
+
+	logger.SetRedaction(false)
+	assert.Equal(t, "250.75", logger.RedactAmount(decimal.RequireFromString("250.75")))
+	token := logger.Token("token", "verify-abc123").String
+	assert.NotContains(t, token, "verify-abc123", "tokens are hashed even when redaction is off")
+	assert.Equal(t, token, logger.Token("token", "verify-abc123").String)
+
+	logger.SetRedaction(true)
+	assert.Equal(t, "100-1K", logger.RedactAmount(decimal.RequireFromString("250.75")))
+	assert.Empty(t, logger.RedactToken(""))
+}
+
+// findLogFields returns file:line locations in cmd, internal and pkg whose
+// source matches pattern, except matches allowed reports as allowed
+func findLogFields(t *testing.T, pattern *regexp.Regexp, allowed func(line string, m []string) bool) []string {
+	t.Helper()
+	root, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	require.NoError(t, err)
+
+	var violations []string
+	for _, dir := range []string{"cmd", "internal", "pkg"} {
+		err := filepath.Walk(filepath.Join(root, dir), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			// The helpers themselves are the only place allowed to build these fields
+			if strings.HasSuffix(path, filepath.Join("pkg", "logger", "redact.go")) {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			scanner := bufio.NewScanner(f)
+			line := 0
+			for scanner.Scan() {
+				line++
+				text := scanner.Text()
+				for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+					if allowed(text, m) {
+						continue
+					}
+					rel, _ := filepath.Rel(root, path)
+					violations = append(violations, rel+":"+strconv.Itoa(line)+": "+strings.TrimSpace(m[0]))
+				}
+			}
+			return scanner.Err()
+		})
+		require.NoError(t, err)
+	}
+	return violations
+}
@@ -0,0 +1,126 @@
+package marketcalendar_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/marketcalendar"
+)
+
+type fakeSource struct {
+	sessions []entities.MarketSession
+	err      error
+	calls    int
+}
+
+func (f *fakeSource) GetSessions(ctx context.Context, start, end time.Time) ([]entities.MarketSession, error) {
+	f.calls++
+	return f.sessions, f.err
+}
+
+func session(t *testing.T, date, open, close string) entities.MarketSession {
+	t.Helper()
+	s, err := marketcalendar.NewSession(date, open, close)
+	require.NoError(t, err)
+	return s
+}
+
+// thanksgivingWeek is the week of 23 November 2026: closed Thursday for
+// Thanksgiving and closing at 13:00 on Friday
+func thanksgivingWeek(t *testing.T) *fakeSource {
+	return &fakeSource{sessions: []entities.MarketSession{
+		session(t, "2026-11-23", "09:30", "16:00"),
+		session(t, "2026-11-24", "09:30", "16:00"),
+		session(t, "2026-11-25", "09:30", "16:00"),
+		session(t, "2026-11-27", "09:30", "13:00"),
+		session(t, "2026-11-30", "09:30", "16:00"),
+	}}
+}
+
+func TestNewSession(t *testing.T) {
+	friday := session(t, "2026-11-27", "09:30", "13:00")
+	assert.True(t, friday.EarlyClose)
+	assert.Equal(t, time.Date(2026, 11, 27, 14, 30, 0, 0, time.UTC), friday.OpenAt)
+	assert.Equal(t, time.Date(2026, 11, 27, 18, 0, 0, 0, time.UTC), friday.CloseAt)
+
+	// Daylight saving time moves the UTC open
+	summer := session(t, "2026-07-01", "09:30", "16:00")
+	assert.False(t, summer.EarlyClose)
+	assert.Equal(t, time.Date(2026, 7, 1, 13, 30, 0, 0, time.UTC), summer.OpenAt)
+
+	_, err := marketcalendar.NewSession("2026-11-27", "9.30", "13:00")
+	assert.Error(t, err)
+}
+
+func TestNextSession_SkipsHolidays(t *testing.T) {
+	svc := marketcalendar.NewService(thanksgivingWeek(t), marketcalendar.DefaultConfig(), zap.NewNop())
+	ctx := context.Background()
+
+	during := time.Date(2026, 11, 25, 15, 0, 0, 0, time.UTC)
+	next, err := svc.NextSession(ctx, during)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-11-25", next.Date, "a session in progress is returned")
+
+	afterClose := time.Date(2026, 11, 25, 22, 0, 0, 0, time.UTC)
+	next, err = svc.NextSession(ctx, afterClose)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-11-27", next.Date, "Thanksgiving is skipped")
+	assert.True(t, next.EarlyClose)
+
+	trading, err := svc.IsTradingDay(ctx, time.Date(2026, 11, 26, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, trading)
+}
+
+func TestNextLocalRun_UsesUserTimezoneAndSkipsHolidays(t *testing.T) {
+	svc := marketcalendar.NewService(thanksgivingWeek(t), marketcalendar.DefaultConfig(), zap.NewNop())
+	ctx := context.Background()
+	lagos := entities.UserLocation("Africa/Lagos")
+
+	run, err := svc.NextLocalRun(ctx, lagos, 8, 0, time.Date(2026, 11, 25, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 27, 7, 0, 0, 0, time.UTC), run, "08:00 in Lagos on the Friday, not on Thanksgiving")
+
+	run, err = svc.NextLocalRun(ctx, lagos, 8, 0, run)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 30, 7, 0, 0, 0, time.UTC), run, "the weekend is skipped")
+
+	run, err = svc.NextLocalRun(ctx, entities.UserLocation(""), 8, 0, time.Date(2026, 11, 24, 7, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 24, 8, 0, 0, 0, time.UTC), run, "users without a time zone run in UTC")
+}
+
+func TestCalendar_CachesAndFallsBackToWeekdays(t *testing.T) {
+	ctx := context.Background()
+	thursday := time.Date(2026, 11, 26, 15, 0, 0, 0, time.UTC)
+
+	source := thanksgivingWeek(t)
+	svc := marketcalendar.NewService(source, marketcalendar.DefaultConfig(), zap.NewNop())
+	_, err := svc.IsTradingDay(ctx, thursday)
+	require.NoError(t, err)
+	_, err = svc.NextSession(ctx, thursday)
+	require.NoError(t, err)
+	assert.Equal(t, 1, source.calls, "a year of sessions is loaded once")
+
+	svc = marketcalendar.NewService(&fakeSource{err: errors.New("alpaca unavailable")}, marketcalendar.DefaultConfig(), zap.NewNop())
+	trading, err := svc.IsTradingDay(ctx, thursday)
+	require.NoError(t, err)
+	assert.True(t, trading, "without a calendar every weekday is a session")
+	trading, err = svc.IsTradingDay(ctx, time.Date(2026, 11, 28, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, trading)
+}
+
+func TestValidateTimezone(t *testing.T) {
+	assert.NoError(t, entities.ValidateTimezone("America/New_York"))
+	assert.ErrorIs(t, entities.ValidateTimezone("Mars/Olympus"), entities.ErrInvalidTimezone)
+	assert.ErrorIs(t, entities.ValidateTimezone(""), entities.ErrInvalidTimezone)
+	assert.Equal(t, time.UTC, entities.UserLocation("Mars/Olympus"))
+}
@@ -0,0 +1,269 @@
+package marketdata_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/marketdata"
+)
+
+// fakeFeed hands its onQuote callback to the test once Run starts
+type fakeFeed struct {
+	mu       sync.Mutex
+	symbols  []string
+	running  chan func(*entities.MarketQuote)
+	deadline time.Time
+}
+
+func newFakeFeed() *fakeFeed {
+	return &fakeFeed{running: make(chan func(*entities.MarketQuote), 1)}
+}
+
+func (f *fakeFeed) Run(ctx context.Context, onQuote func(*entities.MarketQuote)) error {
+	f.mu.Lock()
+	f.deadline, _ = ctx.Deadline()
+	f.mu.Unlock()
+	f.running <- onQuote
+	<-ctx.Done()
+	return nil
+}
+
+func (f *fakeFeed) SetSymbols(symbols []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.symbols = symbols
+}
+
+func (f *fakeFeed) Symbols() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.symbols
+}
+
+type fakePositions struct {
+	positions []*entities.Position
+}
+
+func (f *fakePositions) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
+	return f.positions, nil
+}
+
+type fakeBaskets struct {
+	baskets map[uuid.UUID]*entities.Basket
+}
+
+func (f *fakeBaskets) GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error) {
+	basket, ok := f.baskets[id]
+	if !ok {
+		return nil, errors.New("basket not found")
+	}
+	return basket, nil
+}
+
+type fakeHours struct {
+	session *entities.MarketSession
+	err     error
+}
+
+func (f *fakeHours) NextSession(ctx context.Context, after time.Time) (*entities.MarketSession, error) {
+	return f.session, f.err
+}
+
+type fakeSnapshots struct {
+	quotes map[string]*entities.MarketQuote
+}
+
+func (f *fakeSnapshots) LatestQuotes(ctx context.Context, symbols []string) (map[string]*entities.MarketQuote, error) {
+	return f.quotes, nil
+}
+
+func quote(symbol, bid string) *entities.MarketQuote {
+	return &entities.MarketQuote{Symbol: symbol, BidPrice: decimal.RequireFromString(bid), Timestamp: time.Now()}
+}
+
+func newService(feed *fakeFeed, config marketdata.Config) *marketdata.Service {
+	return marketdata.NewService(feed, &fakePositions{}, &fakeBaskets{}, config, zap.NewNop())
+}
+
+// start runs the service with an open market and returns the feed's callback
+func start(t *testing.T, svc *marketdata.Service, feed *fakeFeed) func(*entities.MarketQuote) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc.Start(ctx)
+	select {
+	case onQuote := <-feed.running:
+		return onQuote
+	case <-time.After(time.Second):
+		t.Fatal("feed was not started")
+		return nil
+	}
+}
+
+func TestSubscribe_FeedFollowsWatchedSymbols(t *testing.T) {
+	feed := newFakeFeed()
+	svc := newService(feed, marketdata.DefaultConfig())
+	user := uuid.New()
+
+	first, err := svc.Subscribe(context.Background(), user, []string{"aapl", " MSFT", "AAPL"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, first.Symbols())
+	second, err := svc.Subscribe(context.Background(), user, []string{"MSFT", "VTI"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT", "VTI"}, feed.Symbols())
+
+	first.Close()
+	assert.Equal(t, []string{"MSFT", "VTI"}, feed.Symbols())
+	first.Close()
+	second.Close()
+	assert.Empty(t, feed.Symbols())
+}
+
+func TestSubscribe_DefaultsToHoldings(t *testing.T) {
+	feed := newFakeFeed()
+	growth, closed := uuid.New(), uuid.New()
+	positions := &fakePositions{positions: []*entities.Position{
+		{BasketID: growth, Quantity: decimal.NewFromInt(3)},
+		{BasketID: closed, Quantity: decimal.Zero},
+	}}
+	baskets := &fakeBaskets{baskets: map[uuid.UUID]*entities.Basket{
+		growth: {ID: growth, Composition: []entities.BasketComponent{{Symbol: "QQQ"}, {Symbol: "VTI"}}},
+	}}
+	svc := marketdata.NewService(feed, positions, baskets, marketdata.DefaultConfig(), zap.NewNop())
+
+	sub, err := svc.Subscribe(context.Background(), uuid.New(), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"QQQ", "VTI"}, sub.Symbols())
+}
+
+func TestSubscribe_Validation(t *testing.T) {
+	svc := newService(newFakeFeed(), marketdata.Config{MaxSymbols: 2})
+
+	_, err := svc.Subscribe(context.Background(), uuid.New(), []string{"AAPL", "MSFT", "VTI"})
+	assert.ErrorIs(t, err, entities.ErrTooManySymbols)
+
+	_, err = svc.Subscribe(context.Background(), uuid.New(), []string{"AAPL;DROP"})
+	assert.ErrorIs(t, err, entities.ErrInvalidSymbol)
+
+	_, err = svc.Subscribe(context.Background(), uuid.New(), nil)
+	assert.ErrorIs(t, err, entities.ErrNoSymbolsToWatch)
+}
+
+func TestNext_DeliversLatestQuotePerWatchedSymbol(t *testing.T) {
+	feed := newFakeFeed()
+	svc := newService(feed, marketdata.Config{Throttle: time.Millisecond})
+	onQuote := start(t, svc, feed)
+
+	sub, err := svc.Subscribe(context.Background(), uuid.New(), []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	onQuote(quote("MSFT", "410.10"))
+	onQuote(quote("TSLA", "250"))
+	onQuote(quote("AAPL", "190.00"))
+	onQuote(quote("MSFT", "410.25"))
+
+	quotes, err := sub.Next(context.Background(), time.Second)
+	require.NoError(t, err)
+	require.Len(t, quotes, 2)
+	assert.Equal(t, "AAPL", quotes[0].Symbol)
+	assert.Equal(t, "MSFT", quotes[1].Symbol)
+	assert.Equal(t, "410.25", quotes[1].BidPrice.String())
+}
+
+func TestNext_ThrottlesUpdates(t *testing.T) {
+	feed := newFakeFeed()
+	throttle := 50 * time.Millisecond
+	svc := newService(feed, marketdata.Config{Throttle: throttle})
+	onQuote := start(t, svc, feed)
+
+	sub, err := svc.Subscribe(context.Background(), uuid.New(), []string{"AAPL"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	onQuote(quote("AAPL", "190"))
+	_, err = sub.Next(context.Background(), time.Second)
+	require.NoError(t, err)
+	sent := time.Now()
+
+	onQuote(quote("AAPL", "191"))
+	onQuote(quote("AAPL", "192"))
+	quotes, err := sub.Next(context.Background(), time.Second)
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(sent), throttle-5*time.Millisecond)
+	require.Len(t, quotes, 1)
+	assert.Equal(t, "192", quotes[0].BidPrice.String())
+}
+
+func TestNext_TimesOutWithoutQuotes(t *testing.T) {
+	svc := newService(newFakeFeed(), marketdata.DefaultConfig())
+	sub, err := svc.Subscribe(context.Background(), uuid.New(), []string{"AAPL"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	quotes, err := sub.Next(context.Background(), 10*time.Millisecond)
+
+	require.NoError(t, err)
+	assert.Empty(t, quotes)
+}
+
+func TestStart_StreamsUntilSessionClose(t *testing.T) {
+	feed := newFakeFeed()
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	closeAt := now.Add(6 * time.Hour)
+	svc := newService(feed, marketdata.DefaultConfig())
+	svc.SetClock(func() time.Time { return now })
+	svc.SetMarketHours(&fakeHours{session: &entities.MarketSession{OpenAt: now.Add(-time.Hour), CloseAt: closeAt}})
+
+	start(t, svc, feed)
+
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	assert.Equal(t, closeAt, feed.deadline)
+}
+
+func TestStart_WaitsForMarketOpen(t *testing.T) {
+	feed := newFakeFeed()
+	now := time.Now()
+	svc := newService(feed, marketdata.DefaultConfig())
+	svc.SetMarketHours(&fakeHours{session: &entities.MarketSession{OpenAt: now.Add(time.Hour), CloseAt: now.Add(7 * time.Hour)}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.Start(ctx)
+
+	select {
+	case <-feed.running:
+		t.Fatal("feed started while the market is closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSnapshot_ReportsClosedMarket(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	openAt := time.Date(2026, 3, 16, 13, 30, 0, 0, time.UTC)
+	svc := newService(newFakeFeed(), marketdata.DefaultConfig())
+	svc.SetClock(func() time.Time { return now })
+	svc.SetMarketHours(&fakeHours{session: &entities.MarketSession{OpenAt: openAt, CloseAt: openAt.Add(390 * time.Minute)}})
+	svc.SetSnapshots(&fakeSnapshots{quotes: map[string]*entities.MarketQuote{"AAPL": quote("AAPL", "190")}})
+
+	snapshot := svc.Snapshot(context.Background(), []string{"AAPL", "MSFT"})
+
+	assert.False(t, snapshot.MarketOpen)
+	require.NotNil(t, snapshot.NextOpen)
+	assert.Equal(t, openAt, *snapshot.NextOpen)
+	require.Len(t, snapshot.Quotes, 1)
+	assert.Equal(t, "AAPL", snapshot.Quotes[0].Symbol)
+}
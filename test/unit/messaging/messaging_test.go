@@ -0,0 +1,198 @@
+package messaging_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+)
+
+type fakeCapture struct {
+	mu       sync.Mutex
+	messages []*entities.CapturedMessage
+}
+
+func (f *fakeCapture) CaptureMessage(ctx context.Context, message *entities.CapturedMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+// providerServer counts requests and answers with the current status
+type providerServer struct {
+	*httptest.Server
+	hits   atomic.Int32
+	status atomic.Int32
+	body   string
+}
+
+func newProviderServer(t *testing.T, status int, body string) *providerServer {
+	p := &providerServer{body: body}
+	p.status.Store(int32(status))
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.hits.Add(1)
+		w.WriteHeader(int(p.status.Load()))
+		_, _ = w.Write([]byte(p.body))
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+const vonageOK = `{"message-count":"1","messages":[{"status":"0"}]}`
+
+func newSMSService(t *testing.T, primary, fallback *providerServer) *adapters.SMSService {
+	t.Helper()
+	svc, err := adapters.NewSMSService(zap.NewNop(), adapters.SMSConfig{
+		Provider:   "twilio",
+		APIKey:     "AC123",
+		APISecret:  "secret",
+		FromNumber: "+15550000000",
+		BaseURL:    primary.URL,
+		Fallbacks: []adapters.SMSProviderConfig{{
+			Provider:   "vonage",
+			APIKey:     "key",
+			APISecret:  "secret",
+			FromNumber: "+15550000001",
+			BaseURL:    fallback.URL,
+		}},
+		Failover: adapters.FailoverConfig{FailureThreshold: 2},
+	})
+	require.NoError(t, err)
+	return svc
+}
+
+func TestSMS_FailsOverToNextProvider(t *testing.T) {
+	twilio := newProviderServer(t, http.StatusServiceUnavailable, "")
+	vonage := newProviderServer(t, http.StatusOK, vonageOK)
+	svc := newSMSService(t, twilio, vonage)
+
+	err := svc.SendVerificationSMS(context.Background(), "+15551234567", "123456")
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, twilio.hits.Load())
+	assert.EqualValues(t, 1, vonage.hits.Load())
+}
+
+func TestSMS_SkipsUnhealthyProviderUntilItRecovers(t *testing.T) {
+	twilio := newProviderServer(t, http.StatusServiceUnavailable, "")
+	vonage := newProviderServer(t, http.StatusOK, vonageOK)
+	svc := newSMSService(t, twilio, vonage)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.SendWelcomeSMS(context.Background(), "+15551234567"))
+	}
+
+	// Two failures reach the threshold, so the third send skips Twilio
+	assert.EqualValues(t, 2, twilio.hits.Load())
+	assert.EqualValues(t, 3, vonage.hits.Load())
+	statuses := svc.ProviderStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "twilio", statuses[0].Provider)
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, 2, statuses[0].ConsecutiveFailures)
+	assert.NotNil(t, statuses[0].UnhealthyUntil)
+	assert.True(t, statuses[1].Healthy)
+}
+
+func TestSMS_VonageRejectionIsAFailure(t *testing.T) {
+	twilio := newProviderServer(t, http.StatusBadGateway, "")
+	vonage := newProviderServer(t, http.StatusOK, `{"messages":[{"status":"9","error-text":"Partner quota exceeded"}]}`)
+	svc := newSMSService(t, twilio, vonage)
+
+	err := svc.SendWelcomeSMS(context.Background(), "+15551234567")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Partner quota exceeded")
+}
+
+func TestSMS_DisabledProviderIsSkipped(t *testing.T) {
+	twilio := newProviderServer(t, http.StatusOK, "{}")
+	vonage := newProviderServer(t, http.StatusOK, vonageOK)
+	svc := newSMSService(t, twilio, vonage)
+
+	require.NoError(t, svc.SetProviderEnabled("twilio", false))
+	require.NoError(t, svc.SendWelcomeSMS(context.Background(), "+15551234567"))
+
+	assert.EqualValues(t, 0, twilio.hits.Load())
+	assert.EqualValues(t, 1, vonage.hits.Load())
+	assert.ErrorIs(t, svc.SetProviderEnabled("vonage", false), entities.ErrLastMessagingProvider)
+	assert.ErrorIs(t, svc.SetProviderEnabled("plivo", true), entities.ErrMessagingProviderNotFound)
+}
+
+func TestSMS_SandboxCaptureDoesNotSend(t *testing.T) {
+	capture := &fakeCapture{}
+	svc, err := adapters.NewSMSService(zap.NewNop(), adapters.SMSConfig{CaptureOnly: true, Environment: "staging"})
+	require.NoError(t, err)
+	svc.SetCapture(capture)
+
+	require.NoError(t, svc.SendVerificationSMS(context.Background(), "+1 (555) 123-4567", "654321"))
+
+	require.Len(t, capture.messages, 1)
+	assert.Equal(t, entities.ChannelSMS, capture.messages[0].Channel)
+	assert.Equal(t, "+15551234567", capture.messages[0].Recipient)
+	assert.Contains(t, capture.messages[0].TextBody, "654321")
+	assert.Equal(t, "staging", capture.messages[0].Environment)
+}
+
+func TestEmail_SandboxCaptureDoesNotSend(t *testing.T) {
+	capture := &fakeCapture{}
+	svc, err := adapters.NewEmailService(zap.NewNop(), adapters.EmailServiceConfig{
+		Provider:    "resend",
+		APIKey:      "re_test",
+		FromEmail:   "no-reply@example.com",
+		Environment: "staging",
+		CaptureOnly: true,
+	})
+	require.NoError(t, err)
+	svc.SetCapture(capture)
+
+	require.NoError(t, svc.SendCustomEmail(context.Background(), "user@example.com", "Hello", "<p>Hi</p>", "Hi"))
+	require.NoError(t, svc.Ping(context.Background()))
+
+	require.Len(t, capture.messages, 1)
+	assert.Equal(t, entities.ChannelEmail, capture.messages[0].Channel)
+	assert.Equal(t, "user@example.com", capture.messages[0].Recipient)
+	assert.Equal(t, "Hello", capture.messages[0].Subject)
+	assert.Equal(t, "<p>Hi</p>", capture.messages[0].HTMLBody)
+}
+
+func TestEmail_ProvidersListedInFailoverOrder(t *testing.T) {
+	svc, err := adapters.NewEmailService(zap.NewNop(), adapters.EmailServiceConfig{
+		Provider:  "resend",
+		APIKey:    "re_test",
+		FromEmail: "no-reply@example.com",
+		Fallbacks: []adapters.EmailProviderConfig{
+			{Provider: "sendgrid", APIKey: "SG.one"},
+			{Provider: "sendgrid", APIKey: "SG.two"},
+		},
+	})
+	require.NoError(t, err)
+
+	statuses := svc.ProviderStatuses()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "resend", statuses[0].Provider)
+	assert.Equal(t, "sendgrid", statuses[1].Provider)
+	assert.Equal(t, "sendgrid-2", statuses[2].Provider)
+	assert.Equal(t, 2, statuses[2].Priority)
+}
+
+func TestNewServices_RequireAProviderOutsideCapture(t *testing.T) {
+	_, err := adapters.NewEmailService(zap.NewNop(), adapters.EmailServiceConfig{FromEmail: "no-reply@example.com"})
+	assert.Error(t, err)
+
+	_, err = adapters.NewSMSService(zap.NewNop(), adapters.SMSConfig{})
+	assert.Error(t, err)
+
+	_, err = adapters.NewSMSService(zap.NewNop(), adapters.SMSConfig{Provider: "vonage", APIKey: "key", APISecret: "secret"})
+	assert.Error(t, err, "from number is required")
+}
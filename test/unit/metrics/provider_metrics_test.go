@@ -0,0 +1,84 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/pkg/metrics"
+)
+
+func TestProviderEndpointTemplatesIDs(t *testing.T) {
+	cases := map[string]string{
+		"/v1/trading/accounts/0b9d8a4e-6a4c-4c4e-9a54-1b7a3f0e2d11/orders": "/v1/trading/accounts/:id/orders",
+		"/v1beta1/news":    "/v1beta1/news",
+		"/v1/assets/BRK.B": "/v1/assets/:id",
+		"/resources/applicants/5cb56e8e0a975a35f333cb83/one": "/resources/applicants/:id/one",
+		"/v1/w3s/developer/wallets":                          "/v1/w3s/developer/wallets",
+		"recipients/rcp_8f2k1x":                              "/recipients/:id",
+	}
+	for path, want := range cases {
+		assert.Equal(t, want, metrics.ProviderEndpoint(path), path)
+	}
+}
+
+func TestProviderErrorCode(t *testing.T) {
+	assert.Equal(t, "40010001", metrics.ProviderErrorCode(422, []byte(`{"code":40010001,"message":"insufficient buying power"}`)))
+	assert.Equal(t, "1002", metrics.ProviderErrorCode(400, []byte(`{"code":400,"errorCode":1002,"description":"bad"}`)), "sumsub's errorCode is preferred to the status it repeats")
+	assert.Equal(t, "recipient_not_found", metrics.ProviderErrorCode(404, []byte(`{"code":"recipient_not_found"}`)))
+	assert.Equal(t, "invalid_wallet", metrics.ProviderErrorCode(400, []byte(`{"error":{"code":"invalid wallet"}}`)))
+	assert.Equal(t, "http_502", metrics.ProviderErrorCode(502, []byte("<html>Bad Gateway</html>")))
+}
+
+func TestInstrumentProviderRecordsCallsAndLeavesBodyReadable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/transfers/tr_000123" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"code":"provider_down","message":"maintenance"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: metrics.InstrumentProvider("test_provider", nil)}
+	resp, err := client.Get(server.URL + "/v1/transfers/tr_000123")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.JSONEq(t, `{"code":"provider_down","message":"maintenance"}`, string(body))
+
+	resp, err = client.Get(server.URL + "/v1/transfers")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProviderRequests.WithLabelValues("test_provider", "GET", "/v1/transfers/:id", "5xx")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProviderErrors.WithLabelValues("test_provider", "GET", "/v1/transfers/:id", "provider_down")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProviderRequests.WithLabelValues("test_provider", "GET", "/v1/transfers", "2xx")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/transfers", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	require.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProviderErrors.WithLabelValues("test_provider", "GET", "/v1/transfers", metrics.ProviderErrorTimeout)))
+}
+
+func TestSetProviderCircuitState(t *testing.T) {
+	metrics.SetProviderCircuitState("test_provider", "api", "half-open")
+	assert.Equal(t, float64(metrics.CircuitHalfOpen), testutil.ToFloat64(metrics.ProviderCircuitBreakerState.WithLabelValues("test_provider", "api")))
+	metrics.SetProviderCircuitState("test_provider", "api", "open")
+	assert.Equal(t, float64(metrics.CircuitOpen), testutil.ToFloat64(metrics.ProviderCircuitBreakerState.WithLabelValues("test_provider", "api")))
+	metrics.SetProviderCircuitState("test_provider", "api", "closed")
+	assert.Equal(t, float64(metrics.CircuitClosed), testutil.ToFloat64(metrics.ProviderCircuitBreakerState.WithLabelValues("test_provider", "api")))
+}
@@ -0,0 +1,164 @@
+package news_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/news"
+	"github.com/stack-service/stack_service/pkg/clock/clocktest"
+)
+
+type fakeProvider struct {
+	articles []entities.NewsArticle
+	err      error
+	calls    [][]string
+}
+
+func (p *fakeProvider) GetNews(ctx context.Context, symbols []string, since time.Time, limit int) ([]entities.NewsArticle, error) {
+	p.calls = append(p.calls, symbols)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.articles, nil
+}
+
+type fakePositions map[uuid.UUID][]*entities.Position
+
+func (p fakePositions) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
+	return p[userID], nil
+}
+
+type fakeBaskets map[uuid.UUID]*entities.Basket
+
+func (b fakeBaskets) GetByID(ctx context.Context, id uuid.UUID) (*entities.Basket, error) {
+	return b[id], nil
+}
+
+var now = time.Date(2026, 5, 4, 14, 0, 0, 0, time.UTC)
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+type fixture struct {
+	svc      *news.Service
+	provider *fakeProvider
+	clock    *clocktest.Fake
+	userID   uuid.UUID
+}
+
+// newFixture holds $750 of a 60/40 VTI/BND basket and $250 of an all-AAPL
+// basket, so VTI is 45%, BND 30% and AAPL 25% of the portfolio
+func newFixture() *fixture {
+	userID := uuid.New()
+	core, tech := uuid.New(), uuid.New()
+	baskets := fakeBaskets{
+		core: {ID: core, Composition: []entities.BasketComponent{{Symbol: "VTI", Weight: d("0.6")}, {Symbol: "BND", Weight: d("0.4")}}},
+		tech: {ID: tech, Composition: []entities.BasketComponent{{Symbol: "aapl", Weight: d("1")}}},
+	}
+	positions := fakePositions{userID: {
+		{BasketID: core, MarketValue: d("750")},
+		{BasketID: tech, MarketValue: d("250")},
+	}}
+	provider := &fakeProvider{}
+	clock := clocktest.NewFake(now)
+	svc := news.NewService(provider, positions, baskets, news.DefaultConfig(), zap.NewNop())
+	svc.SetClock(clock.Now)
+	return &fixture{svc: svc, provider: provider, clock: clock, userID: userID}
+}
+
+func TestSymbolWeightsFollowPositionValue(t *testing.T) {
+	f := newFixture()
+	weights, err := f.svc.SymbolWeights(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.True(t, d("0.45").Equal(weights["VTI"]), weights["VTI"].String())
+	assert.True(t, d("0.3").Equal(weights["BND"]), weights["BND"].String())
+	assert.True(t, d("0.25").Equal(weights["AAPL"]), weights["AAPL"].String())
+}
+
+func TestFeedRanksByExposureAndRecency(t *testing.T) {
+	f := newFixture()
+	f.provider.articles = []entities.NewsArticle{
+		{ID: "apple", Headline: "Apple earnings", Symbols: []string{"AAPL"}, PublishedAt: now.Add(-time.Hour)},
+		{ID: "market", Headline: "Index funds", Symbols: []string{"VTI", "BND"}, PublishedAt: now.Add(-time.Hour)},
+		{ID: "old", Headline: "Old VTI story", Symbols: []string{"VTI"}, PublishedAt: now.Add(-48 * time.Hour)},
+		{ID: "other", Headline: "Unrelated", Symbols: []string{"TSLA"}, PublishedAt: now},
+	}
+
+	feed, err := f.svc.Feed(context.Background(), f.userID, 0)
+	require.NoError(t, err)
+	require.True(t, feed.Personalized)
+	assert.Equal(t, []string{"AAPL", "BND", "VTI"}, f.provider.calls[0])
+
+	ids := make([]string, 0, len(feed.Articles))
+	for _, item := range feed.Articles {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []string{"market", "apple", "old"}, ids)
+	assert.Equal(t, []string{"VTI", "BND"}, feed.Articles[0].MatchedSymbols)
+	assert.True(t, d("0.75").Equal(feed.Articles[0].Exposure))
+	// Two half-lives old: a quarter of VTI's 45%
+	assert.True(t, d("0.1125").Equal(feed.Articles[2].Relevance), feed.Articles[2].Relevance.String())
+
+	feed, err = f.svc.Feed(context.Background(), f.userID, 1)
+	require.NoError(t, err)
+	assert.Len(t, feed.Articles, 1)
+}
+
+func TestFeedCachesPerSymbolSetAndServesStaleOnFailure(t *testing.T) {
+	f := newFixture()
+	f.provider.articles = []entities.NewsArticle{{ID: "a", Symbols: []string{"VTI"}, PublishedAt: now}}
+
+	_, err := f.svc.Feed(context.Background(), f.userID, 0)
+	require.NoError(t, err)
+	_, err = f.svc.Feed(context.Background(), f.userID, 0)
+	require.NoError(t, err)
+	assert.Len(t, f.provider.calls, 1)
+
+	f.clock.Advance(10 * time.Minute)
+	f.provider.err = errors.New("provider down")
+	feed, err := f.svc.Feed(context.Background(), f.userID, 0)
+	require.NoError(t, err)
+	assert.Len(t, f.provider.calls, 2)
+	assert.Len(t, feed.Articles, 1)
+
+	_, err = f.svc.Feed(context.Background(), uuid.New(), 0)
+	assert.Error(t, err)
+}
+
+func TestFeedWithoutHoldingsIsGeneralNews(t *testing.T) {
+	f := newFixture()
+	f.provider.articles = []entities.NewsArticle{{ID: "macro", Headline: "Fed holds rates", PublishedAt: now}}
+
+	feed, err := f.svc.Feed(context.Background(), uuid.New(), 0)
+	require.NoError(t, err)
+	assert.False(t, feed.Personalized)
+	assert.Empty(t, f.provider.calls[0])
+	require.Len(t, feed.Articles, 1)
+	assert.True(t, feed.Articles[0].Relevance.IsZero())
+}
+
+func TestMarketContext(t *testing.T) {
+	f := newFixture()
+	f.provider.articles = []entities.NewsArticle{
+		{ID: "apple", Headline: "Apple earnings", Source: "benzinga", Symbols: []string{"AAPL"}, PublishedAt: now},
+	}
+
+	section, err := f.svc.MarketContext(context.Background(), f.userID, 5)
+	require.NoError(t, err)
+	assert.Contains(t, section, "## Market context")
+	assert.Contains(t, section, "**AAPL** Apple earnings (benzinga, May 4), 25.0% of portfolio")
+
+	f.provider.articles = nil
+	f.clock.Advance(time.Hour)
+	section, err = f.svc.MarketContext(context.Background(), f.userID, 5)
+	require.NoError(t, err)
+	assert.Empty(t, section)
+}
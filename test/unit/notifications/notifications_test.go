@@ -0,0 +1,216 @@
+package notifications_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services"
+	"github.com/stack-service/stack_service/pkg/clock/clocktest"
+)
+
+type memoryPreferences map[uuid.UUID]*entities.UserPreference
+
+func (m memoryPreferences) Get(ctx context.Context, userID uuid.UUID) (*entities.UserPreference, error) {
+	if prefs, ok := m[userID]; ok {
+		copied := *prefs
+		return &copied, nil
+	}
+	return entities.DefaultUserPreference(userID), nil
+}
+
+func (m memoryPreferences) Save(ctx context.Context, prefs *entities.UserPreference) error {
+	copied := *prefs
+	m[prefs.UserID] = &copied
+	return nil
+}
+
+type memoryHeld struct {
+	items []*entities.HeldNotification
+}
+
+func (m *memoryHeld) Hold(ctx context.Context, held *entities.HeldNotification) error {
+	m.items = append(m.items, held)
+	return nil
+}
+
+func (m *memoryHeld) ListDue(ctx context.Context, before time.Time, limit int) ([]*entities.HeldNotification, error) {
+	var due []*entities.HeldNotification
+	for _, item := range m.items {
+		if !item.DeliverAfter.After(before) {
+			due = append(due, item)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryHeld) Delete(ctx context.Context, ids []uuid.UUID) error {
+	remove := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		remove[id] = true
+	}
+	kept := m.items[:0]
+	for _, item := range m.items {
+		if !remove[item.ID] {
+			kept = append(kept, item)
+		}
+	}
+	m.items = kept
+	return nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func newService(clock *clocktest.Fake) (*services.NotificationService, memoryPreferences, *memoryHeld) {
+	prefs := memoryPreferences{}
+	held := &memoryHeld{}
+	svc := services.NewNotificationService(zap.NewNop())
+	svc.SetPreferenceStore(prefs)
+	svc.SetHeldNotifications(held, services.DefaultNotificationHoldConfig())
+	svc.SetClock(clock.Now)
+	return svc, prefs, held
+}
+
+func quietPrefs(userID uuid.UUID) *entities.UserPreference {
+	prefs := entities.DefaultUserPreference(userID)
+	prefs.QuietHoursEnabled = true
+	prefs.Timezone = "America/New_York"
+	return prefs
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	prefs := quietPrefs(uuid.New())
+	ny, _ := time.LoadLocation("America/New_York")
+
+	cases := []struct {
+		name  string
+		now   time.Time
+		quiet bool
+		until time.Time
+	}{
+		{"before start", time.Date(2026, 3, 10, 21, 59, 0, 0, ny), false, time.Time{}},
+		{"late evening", time.Date(2026, 3, 10, 23, 30, 0, 0, ny), true, time.Date(2026, 3, 11, 7, 0, 0, 0, ny)},
+		{"after midnight", time.Date(2026, 3, 11, 2, 0, 0, 0, ny), true, time.Date(2026, 3, 11, 7, 0, 0, 0, ny)},
+		{"at end", time.Date(2026, 3, 11, 7, 0, 0, 0, ny), false, time.Time{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			until, quiet := services.QuietHoursEnd(prefs, tc.now.UTC())
+			assert.Equal(t, tc.quiet, quiet)
+			if tc.quiet {
+				assert.True(t, tc.until.Equal(until), until)
+			}
+		})
+	}
+
+	prefs.QuietHoursStart, prefs.QuietHoursEnd = "13:00", "14:00"
+	_, quiet := services.QuietHoursEnd(prefs, time.Date(2026, 3, 10, 13, 30, 0, 0, ny))
+	assert.True(t, quiet)
+
+	prefs.QuietHoursEnabled = false
+	_, quiet = services.QuietHoursEnd(prefs, time.Date(2026, 3, 10, 13, 30, 0, 0, ny))
+	assert.False(t, quiet)
+}
+
+func TestPushHeldDuringQuietHoursAndReleasedAfter(t *testing.T) {
+	// 03:00 UTC is 23:00 in New York
+	clock := clocktest.NewFake(time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC))
+	svc, prefs, held := newService(clock)
+	userID := uuid.New()
+	prefs[userID] = quietPrefs(userID)
+
+	push := &entities.Notification{ID: uuid.New(), UserID: userID, Type: entities.NotificationTypeTrade, Channel: entities.ChannelPush, Priority: entities.PriorityHigh, Title: "Order filled"}
+	require.NoError(t, svc.Deliver(context.Background(), push, nil))
+	require.Len(t, held.items, 1)
+	assert.Equal(t, entities.NotificationHoldQuietHours, held.items[0].Reason)
+	assert.Equal(t, time.Date(2026, 3, 11, 11, 0, 0, 0, time.UTC), held.items[0].DeliverAfter.UTC())
+
+	email := &entities.Notification{UserID: userID, Type: entities.NotificationTypeTrade, Channel: entities.ChannelEmail, Priority: entities.PriorityHigh}
+	require.NoError(t, svc.Deliver(context.Background(), email, nil))
+	assert.Len(t, held.items, 1, "email is not held overnight")
+
+	security := &entities.Notification{UserID: userID, Type: entities.NotificationTypeSecurity, Channel: entities.ChannelSMS, Priority: entities.PriorityHigh}
+	critical := &entities.Notification{UserID: userID, Type: entities.NotificationTypeWithdrawal, Channel: entities.ChannelPush, Priority: entities.PriorityCritical}
+	require.NoError(t, svc.Deliver(context.Background(), security, nil))
+	require.NoError(t, svc.Deliver(context.Background(), critical, nil))
+	assert.Len(t, held.items, 1, "security and critical alerts bypass quiet hours")
+
+	sent, err := svc.ReleaseHeld(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	clock.Set(time.Date(2026, 3, 11, 11, 0, 0, 0, time.UTC))
+	sent, err = svc.ReleaseHeld(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Empty(t, held.items)
+}
+
+func TestLowPriorityCollectedIntoDailyDigest(t *testing.T) {
+	clock := clocktest.NewFake(time.Date(2026, 3, 11, 15, 0, 0, 0, time.UTC))
+	svc, prefs, held := newService(clock)
+	userID := uuid.New()
+	userPrefs := entities.DefaultUserPreference(userID)
+	userPrefs.DigestLowPriority = true
+	userPrefs.DigestHour = 9
+	prefs[userID] = userPrefs
+
+	for _, title := range []string{"Goal progress", "Weekly streak"} {
+		require.NoError(t, svc.Deliver(context.Background(), &entities.Notification{
+			UserID: userID, Type: entities.NotificationTypePortfolio, Channel: entities.ChannelPush, Priority: entities.PriorityLow, Title: title,
+		}, nil))
+	}
+	require.NoError(t, svc.Deliver(context.Background(), &entities.Notification{
+		UserID: userID, Type: entities.NotificationTypeDeposit, Channel: entities.ChannelPush, Priority: entities.PriorityMedium,
+	}, nil))
+
+	require.Len(t, held.items, 2)
+	for _, item := range held.items {
+		assert.Equal(t, entities.NotificationHoldDigest, item.Reason)
+		assert.Equal(t, time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC), item.DeliverAfter.UTC())
+	}
+
+	clock.Set(time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC))
+	sent, err := svc.ReleaseHeld(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent, "both are sent as one digest")
+	assert.Empty(t, held.items)
+}
+
+func TestUpdatePreferencesValidatesAndKeepsUnsetFields(t *testing.T) {
+	clock := clocktest.NewFake(time.Date(2026, 3, 11, 15, 0, 0, 0, time.UTC))
+	svc, _, _ := newService(clock)
+	userID := uuid.New()
+
+	updated, err := svc.UpdatePreferences(context.Background(), userID, &entities.UpdateNotificationPreferencesRequest{
+		QuietHoursEnabled: ptr(true),
+		QuietHoursStart:   ptr("23:30"),
+		SMSNotifications:  ptr(true),
+	})
+	require.NoError(t, err)
+	assert.True(t, updated.QuietHoursEnabled)
+	assert.Equal(t, "23:30", updated.QuietHoursStart)
+	assert.Equal(t, "07:00", updated.QuietHoursEnd)
+	assert.True(t, updated.SMSNotifications)
+	assert.True(t, updated.EmailNotifications)
+
+	stored, err := svc.GetPreferences(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.ID, stored.ID)
+
+	for _, req := range []*entities.UpdateNotificationPreferencesRequest{
+		{QuietHoursStart: ptr("25:00")},
+		{QuietHoursEnd: ptr("7am")},
+		{QuietHoursStart: ptr("07:00")},
+		{DigestHour: ptr(24)},
+	} {
+		_, err := svc.UpdatePreferences(context.Background(), userID, req)
+		assert.ErrorIs(t, err, entities.ErrInvalidPreferences)
+	}
+}
@@ -0,0 +1,167 @@
+package onboardingevents_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/onboardingevents"
+)
+
+type fakeOutbox struct {
+	due       []*entities.OnboardingOutboxEntry
+	published []uuid.UUID
+	failed    map[uuid.UUID]time.Time
+	pruned    int
+	markErr   error
+}
+
+func (r *fakeOutbox) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.OnboardingOutboxEntry, error) {
+	if len(r.due) > limit {
+		return r.due[:limit], nil
+	}
+	return r.due, nil
+}
+
+func (r *fakeOutbox) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if r.markErr != nil {
+		return r.markErr
+	}
+	r.published = append(r.published, id)
+	return nil
+}
+
+func (r *fakeOutbox) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	if r.failed == nil {
+		r.failed = make(map[uuid.UUID]time.Time)
+	}
+	r.failed[id] = nextAttemptAt
+	return nil
+}
+
+func (r *fakeOutbox) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.pruned++
+	return 0, nil
+}
+
+type publishedMessage struct {
+	topic string
+	key   string
+	event entities.OnboardingChangedEvent
+}
+
+type fakeBus struct {
+	messages []publishedMessage
+	failFor  map[uuid.UUID]bool
+}
+
+func (b *fakeBus) Publish(ctx context.Context, topic, key string, payload interface{}) error {
+	event := payload.(entities.OnboardingChangedEvent)
+	if b.failFor[event.EventID] {
+		return errors.New("broker unavailable")
+	}
+	b.messages = append(b.messages, publishedMessage{topic: topic, key: key, event: event})
+	return nil
+}
+
+func entry(userID uuid.UUID, sequence int64, kind entities.OnboardingChangeKind, from, to string) *entities.OnboardingOutboxEntry {
+	e := &entities.OnboardingOutboxEntry{
+		ID:         uuid.New(),
+		Sequence:   sequence,
+		UserID:     userID,
+		Kind:       kind,
+		ToStatus:   to,
+		OccurredAt: time.Now(),
+	}
+	if from != "" {
+		e.FromStatus = &from
+	}
+	return e
+}
+
+func newRelay(repo *fakeOutbox, bus *fakeBus, batchSize int) *onboardingevents.Relay {
+	cfg := onboardingevents.DefaultConfig()
+	cfg.BatchSize = batchSize
+	return onboardingevents.NewRelay(repo, bus, cfg, zap.NewNop())
+}
+
+func TestRelayDue_PublishesEntriesKeyedByUser(t *testing.T) {
+	userID := uuid.New()
+	kyc := entry(userID, 1, entities.OnboardingChangeKYC, "pending", "processing")
+	chain := "SOL-DEVNET"
+	wallet := entry(userID, 2, entities.OnboardingChangeWallet, "", "live")
+	wallet.Chain = &chain
+
+	repo := &fakeOutbox{due: []*entities.OnboardingOutboxEntry{kyc, wallet}}
+	bus := &fakeBus{}
+
+	published, err := newRelay(repo, bus, 100).RelayDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uuid.UUID{kyc.ID, wallet.ID}, repo.published)
+
+	require.Len(t, bus.messages, 2)
+	first := bus.messages[0]
+	assert.Equal(t, entities.TopicOnboardingChanged, first.topic)
+	assert.Equal(t, userID.String(), first.key)
+	assert.Equal(t, kyc.ID, first.event.EventID, "the outbox entry ID is the event ID consumers dedupe on")
+	assert.Equal(t, "pending", first.event.From)
+	assert.Equal(t, "processing", first.event.To)
+	assert.Equal(t, "SOL-DEVNET", bus.messages[1].event.Chain)
+	assert.Empty(t, bus.messages[1].event.From)
+	assert.Equal(t, 1, repo.pruned, "a drained backlog prunes published entries")
+}
+
+func TestRelayDue_FailureHoldsBackTheUsersLaterEntries(t *testing.T) {
+	stuck := uuid.New()
+	other := uuid.New()
+	failing := entry(stuck, 1, entities.OnboardingChangeOnboarding, "started", "kyc_pending")
+	heldBack := entry(stuck, 2, entities.OnboardingChangeKYC, "pending", "processing")
+	unrelated := entry(other, 3, entities.OnboardingChangeOnboarding, "started", "kyc_pending")
+
+	repo := &fakeOutbox{due: []*entities.OnboardingOutboxEntry{failing, heldBack, unrelated}}
+	bus := &fakeBus{failFor: map[uuid.UUID]bool{failing.ID: true}}
+
+	before := time.Now()
+	published, err := newRelay(repo, bus, 100).RelayDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []uuid.UUID{unrelated.ID}, repo.published)
+	require.Contains(t, repo.failed, failing.ID)
+	assert.True(t, repo.failed[failing.ID].After(before), "the failed entry is retried after a backoff")
+	assert.NotContains(t, repo.failed, heldBack.ID, "held back entries wait for their lease to expire")
+}
+
+func TestRelayDue_UnmarkedEntriesAreNotCounted(t *testing.T) {
+	userID := uuid.New()
+	first := entry(userID, 1, entities.OnboardingChangeOnboarding, "started", "kyc_pending")
+	second := entry(userID, 2, entities.OnboardingChangeKYC, "pending", "processing")
+
+	repo := &fakeOutbox{due: []*entities.OnboardingOutboxEntry{first, second}, markErr: errors.New("db down")}
+	bus := &fakeBus{}
+
+	published, err := newRelay(repo, bus, 100).RelayDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Len(t, bus.messages, 1, "later entries wait until the first is recorded as published")
+}
+
+func TestRelayDue_FullBatchSkipsPruning(t *testing.T) {
+	repo := &fakeOutbox{due: []*entities.OnboardingOutboxEntry{
+		entry(uuid.New(), 1, entities.OnboardingChangeOnboarding, "", "started"),
+		entry(uuid.New(), 2, entities.OnboardingChangeOnboarding, "", "started"),
+	}}
+
+	published, err := newRelay(repo, &fakeBus{}, 2).RelayDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Zero(t, repo.pruned)
+}
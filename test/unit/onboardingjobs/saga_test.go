@@ -0,0 +1,174 @@
+package onboardingjobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	onboardingprocessor "github.com/stack-service/stack_service/internal/workers/onboarding_processor"
+)
+
+type fakeJobRepo struct {
+	jobs map[uuid.UUID]*entities.OnboardingJob
+}
+
+func (r *fakeJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.OnboardingJob, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, entities.ErrOnboardingJobNotFound
+	}
+	return job, nil
+}
+
+func (r *fakeJobRepo) GetPendingJobs(ctx context.Context, limit int) ([]*entities.OnboardingJob, error) {
+	return nil, nil
+}
+
+func (r *fakeJobRepo) Claim(ctx context.Context, job *entities.OnboardingJob) (bool, error) {
+	if !job.IsEligibleForProcessing() {
+		return false, nil
+	}
+	job.MarkStarted()
+	return true, nil
+}
+
+func (r *fakeJobRepo) Update(ctx context.Context, job *entities.OnboardingJob) error {
+	return nil
+}
+
+type fakeUserRepo struct {
+	user *entities.UserProfile
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error) {
+	copied := *r.user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) UpdateOnboardingStatus(ctx context.Context, userID uuid.UUID, status entities.OnboardingStatus) error {
+	r.user.OnboardingStatus = status
+	return nil
+}
+
+type fakeWallets struct {
+	failures int
+	created  int
+	onCreate func()
+}
+
+func (w *fakeWallets) CreateWalletsForUser(ctx context.Context, userID uuid.UUID, chains []entities.WalletChain) error {
+	if w.onCreate != nil {
+		w.onCreate()
+	}
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("circle unavailable")
+	}
+	w.created++
+	return nil
+}
+
+func (w *fakeWallets) GetWalletStatus(ctx context.Context, userID uuid.UUID) (*entities.WalletStatusResponse, error) {
+	return nil, errors.New("no wallets")
+}
+
+func newJob(jobType entities.OnboardingJobType, maxAttempts int) *entities.OnboardingJob {
+	return &entities.OnboardingJob{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Status:      entities.OnboardingJobStatusQueued,
+		JobType:     jobType,
+		MaxAttempts: maxAttempts,
+		Payload:     map[string]interface{}{"wallet_chains": []interface{}{"SOL-DEVNET"}},
+	}
+}
+
+func newWorker(job *entities.OnboardingJob, users *fakeUserRepo, wallets *fakeWallets, maxAttempts int) *onboardingprocessor.Worker {
+	cfg := onboardingprocessor.DefaultConfig()
+	cfg.MaxAttempts = maxAttempts
+	repo := &fakeJobRepo{jobs: map[uuid.UUID]*entities.OnboardingJob{job.ID: job}}
+	return onboardingprocessor.NewWorker(repo, wallets, users, cfg, zap.NewNop())
+}
+
+func stepStatus(job *entities.OnboardingJob, name string) entities.OnboardingStepStatus {
+	return job.Step(name).Status
+}
+
+func TestProcessJob_RunsEveryStep(t *testing.T) {
+	job := newJob(entities.OnboardingJobTypeFullOnboarding, 5)
+	users := &fakeUserRepo{user: &entities.UserProfile{ID: job.UserID, OnboardingStatus: entities.OnboardingStatusStarted}}
+	wallets := &fakeWallets{}
+	worker := newWorker(job, users, wallets, 5)
+
+	require.NoError(t, worker.ProcessJob(context.Background(), job.ID))
+
+	assert.Equal(t, entities.OnboardingJobStatusCompleted, job.Status)
+	require.Len(t, job.Steps, 2)
+	assert.Equal(t, onboardingprocessor.StepKYC, job.Steps[0].Name)
+	assert.Equal(t, entities.OnboardingStepStatusCompleted, stepStatus(job, onboardingprocessor.StepKYC))
+	assert.Equal(t, entities.OnboardingStepStatusCompleted, stepStatus(job, onboardingprocessor.StepWallets))
+	assert.Equal(t, entities.OnboardingStatusWalletsPending, users.user.OnboardingStatus)
+	assert.Equal(t, 1, wallets.created)
+}
+
+func TestProcessJob_RetryResumesAtTheFailedStep(t *testing.T) {
+	job := newJob(entities.OnboardingJobTypeFullOnboarding, 5)
+	users := &fakeUserRepo{user: &entities.UserProfile{ID: job.UserID, OnboardingStatus: entities.OnboardingStatusStarted}}
+	wallets := &fakeWallets{failures: 1}
+	worker := newWorker(job, users, wallets, 5)
+
+	assert.Error(t, worker.ProcessJob(context.Background(), job.ID))
+	assert.Equal(t, entities.OnboardingJobStatusRetry, job.Status)
+	assert.Equal(t, entities.OnboardingStepStatusCompleted, stepStatus(job, onboardingprocessor.StepKYC))
+	assert.Equal(t, entities.OnboardingStepStatusFailed, stepStatus(job, onboardingprocessor.StepWallets))
+	require.NotNil(t, job.Step(onboardingprocessor.StepWallets).Error)
+
+	due := time.Now().Add(-time.Second)
+	job.NextRetryAt = &due
+	require.NoError(t, worker.ProcessJob(context.Background(), job.ID))
+
+	assert.Equal(t, entities.OnboardingJobStatusCompleted, job.Status)
+	assert.Equal(t, 1, job.Step(onboardingprocessor.StepKYC).Attempts, "completed steps are not run again")
+	assert.Equal(t, 2, job.Step(onboardingprocessor.StepWallets).Attempts)
+	assert.Nil(t, job.Step(onboardingprocessor.StepWallets).Error)
+}
+
+func TestProcessJob_PermanentFailureCompensatesInReverse(t *testing.T) {
+	job := newJob(entities.OnboardingJobTypeFullOnboarding, 1)
+	users := &fakeUserRepo{user: &entities.UserProfile{ID: job.UserID, OnboardingStatus: entities.OnboardingStatusStarted}}
+	worker := newWorker(job, users, &fakeWallets{failures: 1}, 1)
+
+	assert.Error(t, worker.ProcessJob(context.Background(), job.ID))
+
+	assert.Equal(t, entities.OnboardingJobStatusFailed, job.Status)
+	assert.Equal(t, entities.OnboardingStepStatusCompensated, stepStatus(job, onboardingprocessor.StepKYC))
+	assert.Equal(t, entities.OnboardingStepStatusCompensated, stepStatus(job, onboardingprocessor.StepWallets),
+		"the failed step undoes the status change it made before failing")
+	assert.Equal(t, entities.OnboardingStatusStarted, users.user.OnboardingStatus, "status transitions are rolled back")
+
+	job.Requeue()
+	assert.Equal(t, entities.OnboardingJobStatusQueued, job.Status)
+	assert.Zero(t, job.AttemptCount)
+}
+
+func TestCompensation_LeavesUsersWhoMovedOn(t *testing.T) {
+	job := newJob(entities.OnboardingJobTypeFullOnboarding, 1)
+	users := &fakeUserRepo{user: &entities.UserProfile{ID: job.UserID, OnboardingStatus: entities.OnboardingStatusStarted}}
+	wallets := &fakeWallets{failures: 1}
+	// A KYC decision lands while wallets are being provisioned
+	wallets.onCreate = func() { users.user.OnboardingStatus = entities.OnboardingStatusKYCApproved }
+	worker := newWorker(job, users, wallets, 1)
+
+	assert.Error(t, worker.ProcessJob(context.Background(), job.ID))
+
+	assert.Equal(t, entities.OnboardingJobStatusFailed, job.Status)
+	assert.Equal(t, entities.OnboardingStatusKYCApproved, users.user.OnboardingStatus)
+	assert.Equal(t, "started", job.Step(onboardingprocessor.StepKYC).Data["previous_status"])
+}
@@ -0,0 +1,133 @@
+package opsdigest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/opsdigest"
+)
+
+type fakeRepo struct {
+	claimed   map[string]bool
+	completed map[string]int
+	collected []*entities.OpsDigest
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{claimed: make(map[string]bool), completed: make(map[string]int)}
+}
+
+func (r *fakeRepo) Collect(ctx context.Context, digest *entities.OpsDigest) error {
+	digest.Signups = 12
+	digest.KYC = entities.OpsDigestKYC{Approved: 9, Rejected: 3}
+	digest.Deposits = entities.OpsDigestVolume{Count: 4, Volume: "1250.00", Failed: 1}
+	digest.FailedJobs = entities.OpsDigestFailedJobs{Onboarding: 1, FundingEvents: 2}
+	digest.Reconciliation = entities.OpsDigestReconciliation{Exceptions: 2, Unresolved: 1, BySeverity: map[string]int{"high": 1, "low": 1}}
+	r.collected = append(r.collected, digest)
+	return nil
+}
+
+func (r *fakeRepo) ClaimDelivery(ctx context.Context, date time.Time) (bool, error) {
+	day := date.Format("2006-01-02")
+	if r.claimed[day] {
+		return false, nil
+	}
+	r.claimed[day] = true
+	return true, nil
+}
+
+func (r *fakeRepo) CompleteDelivery(ctx context.Context, date time.Time, recipients int, deliveryErr error) error {
+	r.completed[date.Format("2006-01-02")] = recipients
+	return nil
+}
+
+type fakeMailer struct {
+	sent []string
+	text string
+	fail map[string]bool
+}
+
+func (m *fakeMailer) SendCustomEmail(ctx context.Context, to, subject, htmlContent, textContent string) error {
+	if m.fail[to] {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, to)
+	m.text = textContent
+	return nil
+}
+
+type fakePublisher struct {
+	events []uuid.UUID
+}
+
+func (p *fakePublisher) PublishEvent(ctx context.Context, eventID uuid.UUID, eventType entities.WebhookEventType, data interface{}) error {
+	p.events = append(p.events, eventID)
+	return nil
+}
+
+func newService(repo *fakeRepo, mailer *fakeMailer, publisher *fakePublisher) *opsdigest.Service {
+	return opsdigest.NewService(repo, mailer, publisher, opsdigest.Config{
+		Recipients: []string{"ops@example.com", "cfo@example.com"},
+		SendHour:   6,
+	}, zap.NewNop())
+}
+
+func TestBuild_CoversOneUTCDayAndDerivesTotals(t *testing.T) {
+	service := newService(newFakeRepo(), &fakeMailer{}, &fakePublisher{})
+
+	digest, err := service.Build(context.Background(), time.Date(2026, 10, 14, 17, 30, 0, 0, time.FixedZone("WAT", 3600)))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10-14", digest.Date)
+	assert.Equal(t, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), digest.From)
+	assert.Equal(t, 24*time.Hour, digest.To.Sub(digest.From))
+	assert.InDelta(t, 0.75, digest.KYC.PassRate, 0.0001)
+	assert.Equal(t, 3, digest.FailedJobs.Total)
+
+	_, err = service.Build(context.Background(), time.Now().Add(48*time.Hour))
+	assert.ErrorIs(t, err, opsdigest.ErrFutureDate)
+}
+
+func TestRunDue_SendsYesterdayOnceAfterSendHour(t *testing.T) {
+	repo := newFakeRepo()
+	mailer := &fakeMailer{}
+	publisher := &fakePublisher{}
+	service := newService(repo, mailer, publisher)
+
+	early := time.Date(2026, 10, 15, 5, 45, 0, 0, time.UTC)
+	require.NoError(t, service.RunDue(context.Background(), early))
+	assert.Empty(t, mailer.sent, "nothing is sent before the send hour")
+
+	due := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	require.NoError(t, service.RunDue(context.Background(), due))
+	require.NoError(t, service.RunDue(context.Background(), due.Add(15*time.Minute)))
+
+	assert.Equal(t, []string{"ops@example.com", "cfo@example.com"}, mailer.sent)
+	assert.Len(t, publisher.events, 1)
+	assert.Equal(t, 2, repo.completed["2026-10-14"])
+	assert.Contains(t, mailer.text, "KYC pass rate: 75.0% (9 approved, 3 rejected)")
+	assert.Contains(t, mailer.text, "Reconciliation exceptions: 2, 1 unresolved (high 1, low 1)")
+}
+
+func TestSend_ReportsFailedRecipientsAndReusesWebhookEventID(t *testing.T) {
+	mailer := &fakeMailer{fail: map[string]bool{"cfo@example.com": true}}
+	publisher := &fakePublisher{}
+	service := newService(newFakeRepo(), mailer, publisher)
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+	digest, sent, err := service.Send(context.Background(), day)
+	require.Error(t, err)
+	require.NotNil(t, digest)
+	assert.Equal(t, 1, sent)
+
+	_, _, _ = service.Send(context.Background(), day)
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, publisher.events[0], publisher.events[1], "re-sending a day reuses its webhook event ID")
+}
@@ -0,0 +1,265 @@
+package orderops_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/orderops"
+)
+
+type fakeRepo struct {
+	orders        map[uuid.UUID]*entities.StuckOrder
+	interventions []*entities.OrderIntervention
+}
+
+func (r *fakeRepo) ListStuck(ctx context.Context, before time.Time, limit int) ([]*entities.StuckOrder, error) {
+	var stuck []*entities.StuckOrder
+	for _, order := range r.orders {
+		if order.Status.IsInFlight() && order.UpdatedAt.Before(before) {
+			stuck = append(stuck, order)
+		}
+	}
+	return stuck, nil
+}
+
+func (r *fakeRepo) GetOrder(ctx context.Context, id uuid.UUID) (*entities.StuckOrder, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeRepo) TransitionInFlight(ctx context.Context, id uuid.UUID, from, to entities.OrderStatus) (bool, error) {
+	order := r.orders[id]
+	if order == nil || order.Status != from {
+		return false, nil
+	}
+	order.Status = to
+	return true, nil
+}
+
+func (r *fakeRepo) Record(ctx context.Context, intervention *entities.OrderIntervention) error {
+	r.interventions = append(r.interventions, intervention)
+	order := r.orders[intervention.OrderID]
+	order.ResolutionNote = &intervention.Note
+	order.ResolvedBy = &intervention.AdminID
+	return nil
+}
+
+func (r *fakeRepo) ListInterventions(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderIntervention, error) {
+	return r.interventions, nil
+}
+
+type fakeBrokerage struct {
+	fills     []entities.BrokerageFill
+	cancelErr error
+	canceled  []string
+}
+
+func (b *fakeBrokerage) GetOrderStatus(ctx context.Context, brokerageRef string) (*investing.BrokerageOrderStatus, error) {
+	return &investing.BrokerageOrderStatus{Status: entities.OrderStatusPartiallyFilled, Fills: b.fills}, nil
+}
+
+func (b *fakeBrokerage) CancelOrder(ctx context.Context, brokerageRef string) error {
+	if b.cancelErr != nil {
+		return b.cancelErr
+	}
+	b.canceled = append(b.canceled, brokerageRef)
+	return nil
+}
+
+type fakeBalances struct {
+	buyingPower decimal.Decimal
+}
+
+func (b *fakeBalances) DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	b.buyingPower = b.buyingPower.Sub(amount)
+	return nil
+}
+
+func (b *fakeBalances) AddBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	b.buyingPower = b.buyingPower.Add(amount)
+	return nil
+}
+
+type fakePositions struct {
+	position *entities.Position
+}
+
+func (p *fakePositions) GetByUserAndBasket(ctx context.Context, userID, basketID uuid.UUID) (*entities.Position, error) {
+	return p.position, nil
+}
+
+func (p *fakePositions) CreateOrUpdate(ctx context.Context, position *entities.Position) error {
+	p.position = position
+	return nil
+}
+
+type fakeAudit struct {
+	actions []string
+}
+
+func (a *fakeAudit) LogAction(ctx context.Context, userID *uuid.UUID, action, entity string, before, after interface{}) error {
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+type fixture struct {
+	repo      *fakeRepo
+	brokerage *fakeBrokerage
+	balances  *fakeBalances
+	positions *fakePositions
+	audit     *fakeAudit
+	service   *orderops.Service
+	adminID   uuid.UUID
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		repo:      &fakeRepo{orders: make(map[uuid.UUID]*entities.StuckOrder)},
+		brokerage: &fakeBrokerage{},
+		balances:  &fakeBalances{},
+		positions: &fakePositions{},
+		audit:     &fakeAudit{},
+		adminID:   uuid.New(),
+	}
+	f.service = orderops.NewService(f.repo, f.brokerage, f.balances, f.positions, f.audit,
+		orderops.Config{SLA: 15 * time.Minute}, zap.NewNop())
+	return f
+}
+
+func (f *fixture) addOrder(side entities.OrderSide, status entities.OrderStatus, idleFor time.Duration) *entities.StuckOrder {
+	ref := "ALP-" + uuid.NewString()
+	order := &entities.StuckOrder{Order: entities.Order{
+		ID:           uuid.New(),
+		UserID:       uuid.New(),
+		BasketID:     uuid.New(),
+		Side:         side,
+		Amount:       decimal.NewFromInt(100),
+		Status:       status,
+		BrokerageRef: &ref,
+		UpdatedAt:    time.Now().Add(-idleFor),
+	}}
+	f.repo.orders[order.ID] = order
+	return order
+}
+
+func TestListStuck_OnlyInFlightPastSLA(t *testing.T) {
+	f := newFixture()
+	stuck := f.addOrder(entities.OrderSideBuy, entities.OrderStatusPending, time.Hour)
+	f.addOrder(entities.OrderSideBuy, entities.OrderStatusPending, time.Minute)
+	f.addOrder(entities.OrderSideBuy, entities.OrderStatusFilled, time.Hour)
+
+	orders, err := f.service.ListStuck(context.Background())
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, stuck.ID, orders[0].ID)
+	assert.NotEmpty(t, orders[0].StuckFor)
+}
+
+func TestCancel_BooksPartialFillsAndRefundsRemainder(t *testing.T) {
+	f := newFixture()
+	order := f.addOrder(entities.OrderSideBuy, entities.OrderStatusPartiallyFilled, time.Hour)
+	f.brokerage.fills = []entities.BrokerageFill{{Symbol: "VTI", Quantity: "0.5", Price: "80"}}
+
+	intervention, err := f.service.Cancel(context.Background(), f.adminID, order.ID, "broker stuck on partial fill")
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.OrderStatusCanceled, f.repo.orders[order.ID].Status)
+	assert.Equal(t, []string{*order.BrokerageRef}, f.brokerage.canceled)
+	assert.True(t, intervention.BuyingPowerDelta.Equal(decimal.NewFromInt(60)), "the unfilled $60 is returned")
+	assert.True(t, f.balances.buyingPower.Equal(decimal.NewFromInt(60)))
+	require.NotNil(t, f.positions.position)
+	assert.True(t, f.positions.position.Quantity.Equal(decimal.RequireFromString("0.5")))
+	assert.True(t, f.positions.position.MarketValue.Equal(decimal.NewFromInt(40)))
+	assert.Equal(t, "broker stuck on partial fill", *f.repo.orders[order.ID].ResolutionNote)
+	assert.Equal(t, []string{"order_cancel"}, f.audit.actions)
+}
+
+func TestCancel_RejectsOrdersWithinSLAOrSettled(t *testing.T) {
+	f := newFixture()
+	recent := f.addOrder(entities.OrderSideBuy, entities.OrderStatusAccepted, time.Minute)
+	filled := f.addOrder(entities.OrderSideBuy, entities.OrderStatusFilled, time.Hour)
+
+	_, err := f.service.Cancel(context.Background(), f.adminID, recent.ID, "note")
+	assert.ErrorIs(t, err, orderops.ErrOrderNotStuck)
+	_, err = f.service.Cancel(context.Background(), f.adminID, filled.ID, "note")
+	assert.ErrorIs(t, err, orderops.ErrOrderNotStuck)
+	_, err = f.service.Cancel(context.Background(), f.adminID, uuid.New(), "note")
+	assert.ErrorIs(t, err, orderops.ErrOrderNotFound)
+	assert.Empty(t, f.audit.actions)
+}
+
+func TestCancel_BrokerRefusalLeavesOrderStuck(t *testing.T) {
+	f := newFixture()
+	order := f.addOrder(entities.OrderSideBuy, entities.OrderStatusPending, time.Hour)
+	f.brokerage.cancelErr = errors.New("order already routed")
+
+	_, err := f.service.Cancel(context.Background(), f.adminID, order.ID, "note")
+	assert.ErrorIs(t, err, orderops.ErrBrokerCancelFailed)
+	assert.Equal(t, entities.OrderStatusPending, f.repo.orders[order.ID].Status)
+	assert.True(t, f.balances.buyingPower.IsZero())
+}
+
+func TestRepair_AppliesCompensatingEntries(t *testing.T) {
+	f := newFixture()
+	order := f.addOrder(entities.OrderSideSell, entities.OrderStatusPending, time.Hour)
+	f.positions.position = &entities.Position{
+		UserID:      order.UserID,
+		BasketID:    order.BasketID,
+		Quantity:    decimal.NewFromInt(2),
+		MarketValue: decimal.NewFromInt(200),
+	}
+
+	intervention, err := f.service.Repair(context.Background(), f.adminID, order.ID, &entities.OrderRepairRequest{
+		Status:                entities.OrderStatusFilled,
+		BuyingPowerDelta:      "100",
+		PositionQuantityDelta: "-1",
+		PositionValueDelta:    "-100",
+		Note:                  "filled at broker, webhook lost",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.OrderInterventionRepair, intervention.Action)
+	assert.Equal(t, entities.OrderStatusFilled, f.repo.orders[order.ID].Status)
+	assert.True(t, f.balances.buyingPower.Equal(decimal.NewFromInt(100)))
+	assert.True(t, f.positions.position.Quantity.Equal(decimal.NewFromInt(1)))
+	assert.True(t, f.positions.position.AvgPrice.Equal(decimal.NewFromInt(100)))
+}
+
+func TestRepair_RejectsNegativePositionBeforeTouchingOrder(t *testing.T) {
+	f := newFixture()
+	order := f.addOrder(entities.OrderSideSell, entities.OrderStatusPending, time.Hour)
+
+	_, err := f.service.Repair(context.Background(), f.adminID, order.ID, &entities.OrderRepairRequest{
+		Status:                entities.OrderStatusFilled,
+		PositionQuantityDelta: "-1",
+		Note:                  "note",
+	})
+	assert.ErrorIs(t, err, orderops.ErrInvalidAdjustment)
+	assert.Equal(t, entities.OrderStatusPending, f.repo.orders[order.ID].Status)
+	assert.Empty(t, f.repo.interventions)
+}
+
+func TestAnnotate_RecordsNoteWithoutChangingOrder(t *testing.T) {
+	f := newFixture()
+	order := f.addOrder(entities.OrderSideBuy, entities.OrderStatusFilled, time.Hour)
+
+	intervention, err := f.service.Annotate(context.Background(), f.adminID, order.ID, "  customer contacted  ")
+	require.NoError(t, err)
+	assert.Equal(t, "customer contacted", intervention.Note)
+	assert.Equal(t, entities.OrderStatusFilled, intervention.NewStatus)
+	assert.Equal(t, entities.OrderStatusFilled, f.repo.orders[order.ID].Status)
+	assert.Equal(t, []string{"order_annotate"}, f.audit.actions)
+}
@@ -0,0 +1,236 @@
+package outboundwebhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/outboundwebhook"
+	"github.com/stack-service/stack_service/pkg/webhook"
+)
+
+func TestOutboundSignatureRoundTrip(t *testing.T) {
+	payload := []byte(`{"type":"deposit.confirmed"}`)
+	now := time.Unix(1760000000, 0)
+	header := webhook.SignOutbound(payload, "whsec_test", now)
+
+	require.NoError(t, webhook.VerifyOutbound(payload, header, "whsec_test", 5*time.Minute, now.Add(time.Minute)))
+	assert.Error(t, webhook.VerifyOutbound(payload, header, "whsec_other", 5*time.Minute, now))
+	assert.Error(t, webhook.VerifyOutbound([]byte(`{}`), header, "whsec_test", 5*time.Minute, now))
+	assert.Error(t, webhook.VerifyOutbound(payload, header, "whsec_test", 5*time.Minute, now.Add(10*time.Minute)))
+	assert.Error(t, webhook.VerifyOutbound(payload, "garbage", "whsec_test", 0, now))
+}
+
+func TestNextBackoff(t *testing.T) {
+	cfg := outboundwebhook.DefaultConfig()
+	assert.Equal(t, 30*time.Second, outboundwebhook.NextBackoff(cfg, 1))
+	assert.Equal(t, 60*time.Second, outboundwebhook.NextBackoff(cfg, 2))
+	assert.Equal(t, 6*time.Hour, outboundwebhook.NextBackoff(cfg, 20))
+}
+
+type fakeRepo struct {
+	endpoints  map[uuid.UUID]*entities.WebhookEndpoint
+	deliveries map[uuid.UUID]*entities.WebhookDelivery
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		endpoints:  map[uuid.UUID]*entities.WebhookEndpoint{},
+		deliveries: map[uuid.UUID]*entities.WebhookDelivery{},
+	}
+}
+
+func (f *fakeRepo) CreateEndpoint(ctx context.Context, e *entities.WebhookEndpoint) error {
+	f.endpoints[e.ID] = e
+	return nil
+}
+
+func (f *fakeRepo) GetEndpoint(ctx context.Context, id uuid.UUID) (*entities.WebhookEndpoint, error) {
+	e, ok := f.endpoints[id]
+	if !ok {
+		return nil, entities.ErrWebhookEndpointNotFound
+	}
+	return e, nil
+}
+
+func (f *fakeRepo) ListEndpoints(ctx context.Context) ([]*entities.WebhookEndpoint, error) {
+	var out []*entities.WebhookEndpoint
+	for _, e := range f.endpoints {
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) ListTenantEndpoints(ctx context.Context, tenantID uuid.UUID) ([]*entities.WebhookEndpoint, error) {
+	var out []*entities.WebhookEndpoint
+	for _, e := range f.endpoints {
+		if e.TenantID != nil && *e.TenantID == tenantID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) ListSubscribedEndpoints(ctx context.Context, t entities.WebhookEventType) ([]*entities.WebhookEndpoint, error) {
+	var out []*entities.WebhookEndpoint
+	for _, e := range f.endpoints {
+		if e.IsActive && e.TenantID == nil && e.Subscribes(t) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) UpdateEndpoint(ctx context.Context, e *entities.WebhookEndpoint) error {
+	f.endpoints[e.ID] = e
+	return nil
+}
+
+func (f *fakeRepo) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	delete(f.endpoints, id)
+	return nil
+}
+
+func (f *fakeRepo) CreateDelivery(ctx context.Context, d *entities.WebhookDelivery) error {
+	f.deliveries[d.ID] = d
+	return nil
+}
+
+func (f *fakeRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.WebhookDelivery, error) {
+	var out []*entities.WebhookDelivery
+	for _, d := range f.deliveries {
+		if (d.Status == entities.WebhookDeliveryPending || d.Status == entities.WebhookDeliveryFailed) &&
+			d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			copied := *d
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) RecordAttempt(ctx context.Context, d *entities.WebhookDelivery) error {
+	f.deliveries[d.ID] = d
+	return nil
+}
+
+func (f *fakeRepo) Reschedule(ctx context.Context, id uuid.UUID, at time.Time) error {
+	d := f.deliveries[id]
+	d.Status = entities.WebhookDeliveryPending
+	d.NextAttemptAt = &at
+	return nil
+}
+
+func (f *fakeRepo) GetDelivery(ctx context.Context, id uuid.UUID) (*entities.WebhookDelivery, error) {
+	d, ok := f.deliveries[id]
+	if !ok {
+		return nil, entities.ErrWebhookDeliveryNotFound
+	}
+	return d, nil
+}
+
+func (f *fakeRepo) ListDeliveries(ctx context.Context, endpointID *uuid.UUID, status entities.WebhookDeliveryStatus, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func TestCreateEndpointRequiresHTTPS(t *testing.T) {
+	svc := outboundwebhook.NewService(newFakeRepo(), outboundwebhook.DefaultConfig(), zap.NewNop())
+
+	_, err := svc.CreateEndpoint(context.Background(), &entities.CreateWebhookEndpointRequest{
+		URL:        "http://partner.example.com/hooks",
+		EventTypes: []entities.WebhookEventType{entities.WebhookEventDepositConfirmed},
+	}, nil)
+	assert.ErrorIs(t, err, outboundwebhook.ErrInvalidEndpoint)
+
+	_, err = svc.CreateEndpoint(context.Background(), &entities.CreateWebhookEndpointRequest{
+		URL:        "https://partner.example.com/hooks",
+		EventTypes: []entities.WebhookEventType{"user.deleted"},
+	}, nil)
+	assert.ErrorIs(t, err, outboundwebhook.ErrUnknownEventType)
+}
+
+func TestPublishDeliversSignedPayload(t *testing.T) {
+	var gotBody []byte
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(webhook.OutboundSignatureHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := newFakeRepo()
+	cfg := outboundwebhook.DefaultConfig()
+	cfg.AllowHTTP = true
+	svc := outboundwebhook.NewService(repo, cfg, zap.NewNop())
+	ctx := context.Background()
+
+	created, err := svc.CreateEndpoint(ctx, &entities.CreateWebhookEndpointRequest{
+		URL:        server.URL,
+		EventTypes: []entities.WebhookEventType{entities.WebhookEventOrderFilled},
+	}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Publish(ctx, entities.WebhookEventOrderFilled, map[string]string{"order_id": "abc"}))
+	require.NoError(t, svc.Publish(ctx, entities.WebhookEventKYCApproved, map[string]string{"user_id": "xyz"}))
+	require.Len(t, repo.deliveries, 1)
+
+	attempted, err := svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	require.NoError(t, webhook.VerifyOutbound(gotBody, gotSignature, created.Secret, time.Minute, time.Now()))
+
+	for _, d := range repo.deliveries {
+		assert.Equal(t, entities.WebhookDeliverySucceeded, d.Status)
+		assert.Equal(t, 1, d.Attempts)
+		assert.NotNil(t, d.DeliveredAt)
+	}
+}
+
+func TestFailedDeliveryBacksOffThenDies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repo := newFakeRepo()
+	cfg := outboundwebhook.DefaultConfig()
+	cfg.AllowHTTP = true
+	cfg.MaxAttempts = 2
+	svc := outboundwebhook.NewService(repo, cfg, zap.NewNop())
+	ctx := context.Background()
+
+	created, err := svc.CreateEndpoint(ctx, &entities.CreateWebhookEndpointRequest{
+		URL:        server.URL,
+		EventTypes: []entities.WebhookEventType{entities.WebhookEventDepositConfirmed},
+	}, nil)
+	require.NoError(t, err)
+	delivery, err := svc.SendTestEvent(ctx, created.ID)
+	require.NoError(t, err)
+
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	d := repo.deliveries[delivery.ID]
+	assert.Equal(t, entities.WebhookDeliveryFailed, d.Status)
+	require.NotNil(t, d.ResponseStatus)
+	assert.Equal(t, http.StatusInternalServerError, *d.ResponseStatus)
+	require.NotNil(t, d.NextAttemptAt)
+	assert.True(t, d.NextAttemptAt.After(time.Now().Add(20*time.Second)))
+
+	_, err = svc.RetryDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	d = repo.deliveries[delivery.ID]
+	assert.Equal(t, entities.WebhookDeliveryDead, d.Status)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Nil(t, d.NextAttemptAt)
+}
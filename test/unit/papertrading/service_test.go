@@ -0,0 +1,134 @@
+package papertrading_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/papertrading"
+)
+
+type fakeRepo struct {
+	profile  entities.TradingProfile
+	accounts map[uuid.UUID]decimal.Decimal
+	opened   int
+}
+
+func newFakeRepo(kycStatus string) *fakeRepo {
+	return &fakeRepo{
+		profile:  entities.TradingProfile{Mode: entities.TradingModeLive, KYCStatus: kycStatus},
+		accounts: make(map[uuid.UUID]decimal.Decimal),
+	}
+}
+
+func (r *fakeRepo) GetTradingProfile(ctx context.Context, userID uuid.UUID) (*entities.TradingProfile, error) {
+	profile := r.profile
+	return &profile, nil
+}
+
+func (r *fakeRepo) SetTradingMode(ctx context.Context, userID uuid.UUID, mode entities.TradingMode) error {
+	r.profile.Mode = mode
+	return nil
+}
+
+func (r *fakeRepo) OpenAccount(ctx context.Context, userID uuid.UUID, startingBalance decimal.Decimal) error {
+	if _, ok := r.accounts[userID]; !ok {
+		r.accounts[userID] = startingBalance
+		r.opened++
+	}
+	return nil
+}
+
+func (r *fakeRepo) GetAccount(ctx context.Context, userID uuid.UUID) (*entities.Balance, decimal.Decimal, error) {
+	balance := r.accounts[userID]
+	return &entities.Balance{UserID: userID, BuyingPower: balance, Currency: "USD", UpdatedAt: time.Now()}, balance, nil
+}
+
+type fakeGate struct {
+	features map[entities.JurisdictionFeature]bool
+}
+
+func (g *fakeGate) IsFeatureAllowed(ctx context.Context, userID uuid.UUID, feature entities.JurisdictionFeature) (bool, string, error) {
+	return g.features[feature], "NG", nil
+}
+
+func newService(repo *fakeRepo, gate *fakeGate) *papertrading.Service {
+	return papertrading.NewService(repo, gate, papertrading.Config{StartingBalance: decimal.NewFromInt(25000)}, zap.NewNop())
+}
+
+func TestMode_LiveByDefault(t *testing.T) {
+	repo := newFakeRepo("approved")
+	service := newService(repo, &fakeGate{features: map[entities.JurisdictionFeature]bool{entities.FeatureTrading: true}})
+
+	mode, err := service.Mode(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entities.TradingModeLive, mode)
+	assert.Zero(t, repo.opened)
+}
+
+func TestMode_PreApprovalCountryTradesOnPaper(t *testing.T) {
+	repo := newFakeRepo("approved")
+	gate := &fakeGate{features: map[entities.JurisdictionFeature]bool{entities.FeaturePaperTrading: true}}
+	service := newService(repo, gate)
+	userID := uuid.New()
+
+	status, err := service.Status(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TradingModePaper, status.Mode)
+	assert.True(t, status.PaperOnly)
+	assert.False(t, status.CanSwitchToLive)
+	assert.Equal(t, papertrading.BlockedByJurisdiction, status.LiveBlockedBy)
+	require.NotNil(t, status.PaperBuyingPower)
+	assert.Equal(t, "25000.00", *status.PaperBuyingPower)
+
+	_, err = service.SwitchToLive(context.Background(), userID)
+	assert.ErrorIs(t, err, papertrading.ErrLiveTradingUnavailable)
+
+	// Approving the country does not move the user to live on its own
+	gate.features[entities.FeatureTrading] = true
+	mode, err := service.Mode(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TradingModePaper, mode)
+
+	status, err = service.SwitchToLive(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TradingModeLive, status.Mode)
+	assert.False(t, status.Paper)
+	assert.Equal(t, 1, repo.opened, "the paper account is funded once")
+}
+
+func TestSwitchToLive_RequiresApprovedKYC(t *testing.T) {
+	repo := newFakeRepo("pending")
+	service := newService(repo, &fakeGate{features: map[entities.JurisdictionFeature]bool{entities.FeatureTrading: true}})
+	userID := uuid.New()
+
+	status, err := service.EnablePaper(context.Background(), userID)
+	require.NoError(t, err)
+	assert.True(t, status.Paper)
+	assert.False(t, status.PaperOnly)
+	assert.Equal(t, papertrading.BlockedByKYC, status.LiveBlockedBy)
+
+	_, err = service.SwitchToLive(context.Background(), userID)
+	assert.ErrorIs(t, err, papertrading.ErrKYCRequired)
+
+	repo.profile.KYCStatus = "approved"
+	status, err = service.Status(context.Background(), userID)
+	require.NoError(t, err)
+	assert.True(t, status.CanSwitchToLive)
+
+	status, err = service.SwitchToLive(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.TradingModeLive, status.Mode)
+
+	// Re-enabling paper keeps the existing account
+	_, err = service.EnablePaper(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.opened)
+}
@@ -0,0 +1,137 @@
+package passwordpolicy_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/passwordpolicy"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+	"github.com/stack-service/stack_service/pkg/clock/clocktest"
+)
+
+type fakeBreaches struct {
+	breached map[string]bool
+	err      error
+}
+
+func (f *fakeBreaches) BreachCount(ctx context.Context, password string) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	if f.breached[password] {
+		return 42, nil
+	}
+	return 0, nil
+}
+
+type fakeWeakStore map[uuid.UUID]time.Time
+
+func (f fakeWeakStore) MarkPasswordWeak(ctx context.Context, userID uuid.UUID, at time.Time) (time.Time, error) {
+	if since, ok := f[userID]; ok {
+		return since, nil
+	}
+	f[userID] = at
+	return at, nil
+}
+
+func violations(t *testing.T, err error) []entities.PasswordViolation {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	require.ErrorIs(t, err, entities.ErrWeakPassword)
+	var policyErr *entities.PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr))
+	return policyErr.Violations
+}
+
+func TestCheck(t *testing.T) {
+	breaches := &fakeBreaches{breached: map[string]bool{"Password123!": true}}
+	svc := passwordpolicy.NewService(breaches, nil, passwordpolicy.DefaultConfig(), zap.NewNop())
+	ctx := context.Background()
+	personal := []string{"jane.doe@example.com", "+1 (415) 555-0134"}
+
+	cases := []struct {
+		password string
+		want     []entities.PasswordViolation
+	}{
+		{"Tr1cky-Otter", nil},
+		{"Sh0rt!", []entities.PasswordViolation{entities.PasswordTooShort}},
+		{"alllowercase", []entities.PasswordViolation{entities.PasswordTooSimple}},
+		{"correct horse battery staple", nil},
+		{"Password123!", []entities.PasswordViolation{entities.PasswordBreached}},
+		{"JaneRocks2024", []entities.PasswordViolation{entities.PasswordPersonalInfo}},
+		{"Jane.Doe@2024", []entities.PasswordViolation{entities.PasswordPersonalInfo}},
+		{"Call5550134!", []entities.PasswordViolation{entities.PasswordPersonalInfo}},
+		{strings.Repeat("Ab1!", 19), []entities.PasswordViolation{entities.PasswordTooLong}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.password, func(t *testing.T) {
+			assert.Equal(t, tc.want, violations(t, svc.Check(ctx, tc.password, personal...)))
+		})
+	}
+
+	breaches.err = errors.New("lookup down")
+	assert.NoError(t, svc.Check(ctx, "Password123!"), "breach check fails open")
+}
+
+func TestReviewStartsGracePeriodOnFirstWeakSignIn(t *testing.T) {
+	start := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	clock := clocktest.NewFake(start)
+	store := fakeWeakStore{}
+	svc := passwordpolicy.NewService(nil, store, passwordpolicy.DefaultConfig(), zap.NewNop())
+	svc.SetClock(clock.Now)
+	userID := uuid.New()
+
+	assert.Nil(t, svc.Review(context.Background(), userID, "Tr1cky-Otter"))
+	assert.Empty(t, store)
+
+	notice := svc.Review(context.Background(), userID, "password")
+	require.NotNil(t, notice)
+	assert.False(t, notice.Required)
+	assert.Equal(t, start.Add(30*24*time.Hour), notice.ChangeBy)
+	assert.Contains(t, notice.Violations, entities.PasswordTooSimple)
+
+	clock.Advance(31 * 24 * time.Hour)
+	notice = svc.Review(context.Background(), userID, "password")
+	require.NotNil(t, notice)
+	assert.True(t, notice.Required)
+	assert.Equal(t, start.Add(30*24*time.Hour), notice.ChangeBy, "deadline counts from the first prompt")
+}
+
+func TestPwnedPasswordsClientSendsOnlyHashPrefix(t *testing.T) {
+	sum := sha1.Sum([]byte("hunter2"))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	var path, padding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, padding = r.URL.Path, r.Header.Get("Add-Padding")
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n%s:17\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:0\r\n", digest[5:])
+	}))
+	defer server.Close()
+
+	client := adapters.NewPwnedPasswordsClient(adapters.PwnedPasswordsConfig{BaseURL: server.URL})
+	count, err := client.BreachCount(context.Background(), "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, 17, count)
+	assert.Equal(t, "/range/"+digest[:5], path)
+	assert.Equal(t, "true", padding)
+
+	count, err = client.BreachCount(context.Background(), "Tr1cky-Otter-unlisted")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
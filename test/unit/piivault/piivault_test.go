@@ -0,0 +1,125 @@
+package piivault_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/piivault"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+type memoryRepo struct {
+	records map[string]*entities.PIIVaultRecord
+	access  []*entities.PIIAccessEntry
+}
+
+func (r *memoryRepo) CreateRecord(_ context.Context, record *entities.PIIVaultRecord) error {
+	copied := *record
+	r.records[record.Token] = &copied
+	return nil
+}
+
+func (r *memoryRepo) GetRecord(_ context.Context, token string) (*entities.PIIVaultRecord, error) {
+	record, ok := r.records[token]
+	if !ok {
+		return nil, entities.ErrPIIRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (r *memoryRepo) UpdateRecord(_ context.Context, token, ciphertext string, updatedAt time.Time) error {
+	record, ok := r.records[token]
+	if !ok {
+		return entities.ErrPIIRecordNotFound
+	}
+	record.Ciphertext = ciphertext
+	record.UpdatedAt = updatedAt
+	return nil
+}
+
+func (r *memoryRepo) LogAccess(_ context.Context, entry *entities.PIIAccessEntry) error {
+	r.access = append(r.access, entry)
+	return nil
+}
+
+func (r *memoryRepo) ListAccess(_ context.Context, token string, limit int) ([]*entities.PIIAccessEntry, error) {
+	var entries []*entities.PIIAccessEntry
+	for i := len(r.access) - 1; i >= 0 && len(entries) < limit; i-- {
+		if r.access[i].Token == token {
+			entries = append(entries, r.access[i])
+		}
+	}
+	return entries, nil
+}
+
+func newVault(t *testing.T) (*piivault.Service, *memoryRepo) {
+	enc, err := crypto.NewFieldEncryptor(crypto.StaticFieldKeySource{
+		Keys:          map[int][]byte{1: crypto.DeriveFieldKey("vault-key")},
+		ActiveVersion: 1,
+	}, crypto.DeriveFieldKey("vault-index"))
+	require.NoError(t, err)
+	repo := &memoryRepo{records: map[string]*entities.PIIVaultRecord{}}
+	return piivault.NewService(repo, enc, zap.NewNop()), repo
+}
+
+func TestTokenizeKeepsOnlyCiphertextAndRevealsWithPurpose(t *testing.T) {
+	vault, repo := newVault(t)
+	ctx := context.Background()
+	onboarding := vault.Scope("onboarding", entities.PIIKindKYCIdentity)
+	subject := uuid.New()
+
+	token, err := onboarding.Tokenize(ctx, subject, entities.PIIKindKYCIdentity, entities.KYCVaultRecord{
+		PersonalInfo: &entities.KYCPersonalInfo{FirstName: "Ada", LastName: "Lovelace", Country: "GB"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "pii_"))
+	assert.NotContains(t, repo.records[token].Ciphertext, "Lovelace")
+	assert.Equal(t, subject, repo.records[token].SubjectID)
+
+	var record entities.KYCVaultRecord
+	assert.ErrorIs(t, onboarding.Reveal(ctx, token, " ", &record), piivault.ErrPurposeRequired)
+	require.NoError(t, onboarding.Reveal(ctx, token, "resubmit KYC", &record))
+	assert.Equal(t, "Lovelace", record.PersonalInfo.LastName)
+
+	record.PersonalInfo.LastName = "King"
+	require.NoError(t, onboarding.Update(ctx, token, record))
+	var updated entities.KYCVaultRecord
+	require.NoError(t, onboarding.Reveal(ctx, token, "check", &updated))
+	assert.Equal(t, "King", updated.PersonalInfo.LastName)
+
+	_, err = onboarding.Tokenize(ctx, subject, entities.PIIKind("tax_id"), "123-45-6789")
+	assert.ErrorIs(t, err, entities.ErrPIIAccessDenied)
+}
+
+func TestAccessorsOnlyReachGrantedKinds(t *testing.T) {
+	vault, _ := newVault(t)
+	ctx := context.Background()
+
+	token, err := vault.Scope("onboarding", entities.PIIKindKYCIdentity).
+		Tokenize(ctx, uuid.New(), entities.PIIKindKYCIdentity, entities.KYCVaultRecord{})
+	require.NoError(t, err)
+
+	reporting := vault.Scope("reporting")
+	var record entities.KYCVaultRecord
+	assert.ErrorIs(t, reporting.Reveal(ctx, token, "monthly report", &record), entities.ErrPIIAccessDenied)
+	assert.ErrorIs(t, reporting.Update(ctx, token, record), entities.ErrPIIAccessDenied)
+	assert.ErrorIs(t, reporting.Reveal(ctx, "pii_missing", "monthly report", &record), entities.ErrPIIRecordNotFound)
+
+	entries, err := vault.AccessLog(ctx, token, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "reporting", entries[0].Accessor)
+	assert.Equal(t, entities.PIIAccessDenied, entries[0].Action)
+	assert.Equal(t, "monthly report", entries[1].Purpose)
+	assert.Equal(t, entities.PIIAccessStore, entries[2].Action)
+	assert.Equal(t, "onboarding", entries[2].Accessor)
+}
@@ -0,0 +1,251 @@
+package portfolioshare_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/portfolioshare"
+)
+
+type fakeRepo struct {
+	links    map[uuid.UUID]*entities.PortfolioShareLink
+	settings map[uuid.UUID]*entities.PortfolioSharingSettings
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		links:    map[uuid.UUID]*entities.PortfolioShareLink{},
+		settings: map[uuid.UUID]*entities.PortfolioSharingSettings{},
+	}
+}
+
+func (f *fakeRepo) CreateLink(ctx context.Context, link *entities.PortfolioShareLink) error {
+	copied := *link
+	f.links[link.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetLink(ctx context.Context, id uuid.UUID) (*entities.PortfolioShareLink, error) {
+	link, ok := f.links[id]
+	if !ok {
+		return nil, entities.ErrShareLinkNotFound
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListLinks(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.PortfolioShareLink, error) {
+	var links []*entities.PortfolioShareLink
+	for _, link := range f.links {
+		if link.UserID == userID {
+			copied := *link
+			links = append(links, &copied)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeRepo) CountLive(ctx context.Context, userID uuid.UUID, at time.Time) (int, error) {
+	count := 0
+	for _, link := range f.links {
+		if link.UserID == userID && link.IsLive(at) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeRepo) RevokeLink(ctx context.Context, userID, id uuid.UUID, at time.Time) (*entities.PortfolioShareLink, error) {
+	link, ok := f.links[id]
+	if !ok || link.UserID != userID {
+		return nil, entities.ErrShareLinkNotFound
+	}
+	if link.RevokedAt == nil {
+		link.RevokedAt = &at
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (f *fakeRepo) RevokeAll(ctx context.Context, userID uuid.UUID, at time.Time) (int, error) {
+	revoked := 0
+	for _, link := range f.links {
+		if link.UserID == userID && link.RevokedAt == nil {
+			link.RevokedAt = &at
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (f *fakeRepo) RecordView(ctx context.Context, id uuid.UUID, at time.Time) error {
+	f.links[id].ViewCount++
+	f.links[id].LastViewedAt = &at
+	return nil
+}
+
+func (f *fakeRepo) GetSettings(ctx context.Context, userID uuid.UUID) (*entities.PortfolioSharingSettings, error) {
+	if settings, ok := f.settings[userID]; ok {
+		return settings, nil
+	}
+	return &entities.PortfolioSharingSettings{UserID: userID, Enabled: true}, nil
+}
+
+func (f *fakeRepo) SaveSettings(ctx context.Context, settings *entities.PortfolioSharingSettings) error {
+	f.settings[settings.UserID] = settings
+	return nil
+}
+
+type fakeAttributor struct{}
+
+func (fakeAttributor) Attribute(ctx context.Context, userID uuid.UUID, r entities.AttributionRange) (*entities.PerformanceAttribution, error) {
+	return &entities.PerformanceAttribution{
+		UserID:          userID,
+		Range:           r,
+		Benchmark:       "SPY",
+		BenchmarkReturn: decimal.RequireFromString("0.021"),
+		PortfolioReturn: decimal.RequireFromString("0.04237"),
+		ActiveReturn:    decimal.RequireFromString("0.02137"),
+		StartValue:      entities.NewMoney(decimal.NewFromInt(12345), "USD"),
+		EndValue:        entities.NewMoney(decimal.NewFromInt(12868), "USD"),
+		Baskets: []*entities.BasketAttribution{{
+			BasketID:     uuid.New(),
+			BasketName:   "Tech Leaders",
+			Weight:       decimal.NewFromInt(1),
+			Return:       decimal.RequireFromString("0.04237"),
+			Contribution: decimal.RequireFromString("0.04237"),
+			StartValue:   entities.NewMoney(decimal.NewFromInt(12345), "USD"),
+			Gain:         entities.NewMoney(decimal.NewFromInt(523), "USD"),
+			Components:   []*entities.ComponentAttribution{{Symbol: "AAPL"}},
+		}},
+		Highlights: []string{"You made $523.00"},
+	}, nil
+}
+
+var now = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+func newService(repo *fakeRepo) *portfolioshare.Service {
+	config := portfolioshare.DefaultConfig()
+	config.SigningKey = []byte("test-key")
+	config.MaxLiveLinks = 2
+	service := portfolioshare.NewService(repo, fakeAttributor{}, config, zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	return service
+}
+
+func tokenOf(link *entities.PortfolioShareLink) string {
+	return strings.TrimPrefix(link.URL, portfolioshare.DefaultConfig().PublicURL)
+}
+
+func TestCreateSharesPercentagesOnly(t *testing.T) {
+	service := newService(newFakeRepo())
+
+	link, err := service.Create(context.Background(), uuid.New(), &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1M})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(7*24*time.Hour), link.ExpiresAt)
+	assert.True(t, strings.HasPrefix(link.URL, "/api/v1/shared/portfolio/"))
+
+	shared, err := service.Open(context.Background(), tokenOf(link))
+	require.NoError(t, err)
+	assert.Equal(t, "0.0424", shared.Snapshot.PortfolioReturn.String())
+	require.Len(t, shared.Snapshot.Baskets, 1)
+	assert.Equal(t, "Tech Leaders", shared.Snapshot.Baskets[0].Name)
+
+	body, err := json.Marshal(shared)
+	require.NoError(t, err)
+	for _, leak := range []string{"12345", "523", "AAPL", "user_id", "basket_id"} {
+		assert.NotContains(t, string(body), leak)
+	}
+}
+
+func TestCreateRejectsLongExpiryAndTooManyLinks(t *testing.T) {
+	service := newService(newFakeRepo())
+	userID := uuid.New()
+
+	_, err := service.Create(context.Background(), userID, &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1M, ExpiresInHours: 31 * 24})
+	assert.ErrorIs(t, err, portfolioshare.ErrInvalidExpiry)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Create(context.Background(), userID, &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1M})
+		require.NoError(t, err)
+	}
+	_, err = service.Create(context.Background(), userID, &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1M})
+	assert.ErrorIs(t, err, entities.ErrShareLinkLimit)
+}
+
+func TestOpenRejectsTamperedAndExpiredLinks(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+
+	link, err := service.Create(context.Background(), uuid.New(), &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1W, ExpiresInHours: 1})
+	require.NoError(t, err)
+	token := tokenOf(link)
+
+	tampered := token[:len(token)-1] + "0"
+	if strings.HasSuffix(token, "0") {
+		tampered = token[:len(token)-1] + "1"
+	}
+	_, err = service.Open(context.Background(), tampered)
+	assert.ErrorIs(t, err, entities.ErrShareLinkInvalid)
+	_, err = service.Open(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, entities.ErrShareLinkInvalid)
+
+	service.SetClock(func() time.Time { return now.Add(time.Hour) })
+	_, err = service.Open(context.Background(), token)
+	assert.ErrorIs(t, err, entities.ErrShareLinkUnavailable)
+	assert.Zero(t, repo.links[link.ID].ViewCount)
+}
+
+func TestRevokeAndDisableStopViews(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	first, err := service.Create(ctx, userID, &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1M})
+	require.NoError(t, err)
+	second, err := service.Create(ctx, userID, &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange3M})
+	require.NoError(t, err)
+
+	_, err = service.Open(ctx, tokenOf(first))
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.links[first.ID].ViewCount)
+
+	_, err = service.SetEnabled(ctx, userID, false)
+	require.NoError(t, err)
+	_, err = service.Open(ctx, tokenOf(first))
+	assert.ErrorIs(t, err, entities.ErrShareLinkUnavailable)
+	_, err = service.Create(ctx, userID, &entities.CreatePortfolioShareRequest{Range: entities.AttributionRange1M})
+	assert.ErrorIs(t, err, entities.ErrSharingDisabled)
+
+	_, err = service.SetEnabled(ctx, userID, true)
+	require.NoError(t, err)
+	_, err = service.Open(ctx, tokenOf(first))
+	require.NoError(t, err)
+
+	_, err = service.Revoke(ctx, uuid.New(), first.ID)
+	assert.ErrorIs(t, err, entities.ErrShareLinkNotFound)
+	revoked, err := service.RevokeAll(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+	for _, link := range []*entities.PortfolioShareLink{first, second} {
+		_, err = service.Open(ctx, tokenOf(link))
+		assert.ErrorIs(t, err, entities.ErrShareLinkUnavailable)
+	}
+
+	links, err := service.List(ctx, userID, 20, 0)
+	require.NoError(t, err)
+	for _, link := range links {
+		assert.Empty(t, link.URL)
+	}
+}
@@ -0,0 +1,155 @@
+package projection_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/projection"
+)
+
+type fakeSources struct {
+	balance  decimal.Decimal
+	deposits []*entities.Deposit
+	holds    []*entities.BalanceHold
+	rule     *entities.SweepRule
+	advances map[uuid.UUID]*entities.InstantFundingAdvance
+}
+
+func (f *fakeSources) Get(_ context.Context, userID uuid.UUID) (*entities.Balance, error) {
+	return &entities.Balance{UserID: userID, BuyingPower: f.balance}, nil
+}
+
+func (f *fakeSources) GetByUserID(_ context.Context, _ uuid.UUID, _, _ int) ([]*entities.Deposit, error) {
+	return f.deposits, nil
+}
+
+func (f *fakeSources) ListActive(_ context.Context, _ uuid.UUID) (*entities.ActiveHolds, error) {
+	total := decimal.Zero
+	for _, hold := range f.holds {
+		total = total.Add(hold.Amount)
+	}
+	return &entities.ActiveHolds{Holds: f.holds, TotalHeld: total}, nil
+}
+
+func (f *fakeSources) GetByUser(_ context.Context, _ uuid.UUID) (*entities.SweepRule, error) {
+	if f.rule == nil {
+		return nil, entities.ErrSweepRuleNotFound
+	}
+	return f.rule, nil
+}
+
+func (f *fakeSources) GetByDepositID(_ context.Context, depositID uuid.UUID) (*entities.InstantFundingAdvance, error) {
+	advance, ok := f.advances[depositID]
+	if !ok {
+		return nil, entities.ErrInstantAdvanceNotFound
+	}
+	return advance, nil
+}
+
+type parPricer struct{}
+
+func (parPricer) ConvertToUSD(_ context.Context, amount decimal.Decimal, _ entities.Stablecoin) (decimal.Decimal, error) {
+	return amount, nil
+}
+
+// Wednesday morning
+var now = time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC)
+
+func newService(sources *fakeSources) *projection.Service {
+	service := projection.NewService(sources, sources, sources, sources, parPricer{}, projection.DefaultConfig(), zap.NewNop())
+	service.SetAdvances(sources)
+	service.SetClock(func() time.Time { return now })
+	return service
+}
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func TestProjectionAppliesHoldsAndPendingDeposits(t *testing.T) {
+	chainDeposit := uuid.New()
+	offRamp := uuid.New()
+	virtualAccount := uuid.New()
+	initiated := now.Add(-time.Hour)
+	sources := &fakeSources{
+		balance: dec("400"),
+		holds: []*entities.BalanceHold{
+			{Kind: entities.HoldKindOrder, ReferenceID: uuid.New(), Amount: dec("50")},
+			{Kind: entities.HoldKindWithdrawal, ReferenceID: uuid.New(), Amount: dec("150")},
+		},
+		deposits: []*entities.Deposit{
+			{ID: chainDeposit, Status: entities.DepositStatusDetected, Amount: dec("100"), CreatedAt: now.Add(-5 * time.Minute)},
+			{ID: offRamp, Status: "off_ramp_initiated", Amount: dec("250"), VirtualAccountID: &virtualAccount,
+				OffRampInitiatedAt: &initiated, CreatedAt: initiated},
+			{ID: uuid.New(), Status: entities.DepositStatusCredited, Amount: dec("999"), CreatedAt: now.Add(-48 * time.Hour)},
+		},
+		advances: map[uuid.UUID]*entities.InstantFundingAdvance{
+			chainDeposit: {Amount: dec("30"), Status: entities.InstantAdvanceActive},
+		},
+	}
+
+	projected, err := newService(sources).Project(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.True(t, dec("600").Equal(projected.SettledCash))
+	assert.True(t, dec("400").Equal(projected.BuyingPower))
+	require.Len(t, projected.Curve, 30)
+	require.Len(t, projected.Flows, 4)
+
+	today := projected.Curve[0]
+	assert.Equal(t, "2026-03-18", today.Date)
+	assert.True(t, dec("70").Equal(today.Inflows), today.Inflows.String())
+	assert.True(t, dec("200").Equal(today.Outflows))
+	assert.True(t, dec("470").Equal(today.BuyingPower))
+
+	// The off-ramp lands the next business day
+	assert.True(t, dec("720").Equal(projected.Curve[1].BuyingPower))
+	assert.True(t, dec("720").Equal(projected.Curve[29].BuyingPower))
+}
+
+func TestProjectionPlaysAutoSweepForward(t *testing.T) {
+	maxAmount := dec("500")
+	virtualAccount := uuid.New()
+	sources := &fakeSources{
+		balance: dec("1000"),
+		deposits: []*entities.Deposit{
+			{ID: uuid.New(), Status: "off_ramp_initiated", Amount: dec("300"), VirtualAccountID: &virtualAccount, CreatedAt: now},
+		},
+		rule: &entities.SweepRule{
+			ID:        uuid.New(),
+			Floor:     dec("200"),
+			MinAmount: dec("10"),
+			MaxAmount: &maxAmount,
+			Cadence:   entities.SweepCadenceWeekly,
+			Enabled:   true,
+			NextRunAt: now.Add(-time.Hour),
+		},
+	}
+
+	projected, err := newService(sources).Project(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	var sweeps []entities.ProjectedFlow
+	for _, flow := range projected.Flows {
+		if flow.Kind == entities.ProjectedFlowRecurring {
+			sweeps = append(sweeps, flow)
+		}
+	}
+	// The overdue run goes today and the next week's is capped too, leaving
+	// the week after the rest above the floor; later runs find nothing
+	require.Len(t, sweeps, 3)
+	assert.True(t, dec("-500").Equal(sweeps[0].Amount))
+	assert.Equal(t, now, sweeps[0].ExpectedAt)
+	assert.True(t, dec("-500").Equal(sweeps[1].Amount))
+	assert.True(t, dec("-100").Equal(sweeps[2].Amount))
+	assert.True(t, dec("500").Equal(projected.Curve[0].BuyingPower))
+	assert.True(t, dec("800").Equal(projected.Curve[1].BuyingPower))
+	assert.True(t, dec("200").Equal(projected.Curve[29].BuyingPower))
+}
@@ -0,0 +1,348 @@
+package promotions_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/promotions"
+)
+
+type fakeRepo struct {
+	promotions map[uuid.UUID]*entities.Promotion
+	grants     map[uuid.UUID]*entities.PromotionGrant
+	closed     map[uuid.UUID]bool
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		promotions: map[uuid.UUID]*entities.Promotion{},
+		grants:     map[uuid.UUID]*entities.PromotionGrant{},
+		closed:     map[uuid.UUID]bool{},
+	}
+}
+
+func (f *fakeRepo) CreatePromotion(ctx context.Context, promotion *entities.Promotion) error {
+	copied := *promotion
+	f.promotions[promotion.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetPromotion(ctx context.Context, id uuid.UUID) (*entities.Promotion, error) {
+	promotion, ok := f.promotions[id]
+	if !ok {
+		return nil, entities.ErrPromotionNotFound
+	}
+	copied := *promotion
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) ListRunning(ctx context.Context, trigger entities.PromotionTrigger, at time.Time) ([]*entities.Promotion, error) {
+	var running []*entities.Promotion
+	for _, promotion := range f.promotions {
+		if promotion.Trigger == trigger && promotion.IsRunning(at) {
+			copied := *promotion
+			running = append(running, &copied)
+		}
+	}
+	return running, nil
+}
+
+func (f *fakeRepo) SetPromotionActive(ctx context.Context, id uuid.UUID, active bool) (*entities.Promotion, error) {
+	f.promotions[id].Active = active
+	return f.GetPromotion(ctx, id)
+}
+
+func (f *fakeRepo) ClaimGrant(ctx context.Context, grant *entities.PromotionGrant) error {
+	for _, existing := range f.grants {
+		if existing.PromotionID == grant.PromotionID && existing.UserID == grant.UserID {
+			return entities.ErrPromotionAlreadyGranted
+		}
+	}
+	promotion := f.promotions[grant.PromotionID]
+	if promotion.MaxGrants != nil && promotion.GrantCount >= *promotion.MaxGrants {
+		return entities.ErrPromotionLimitReached
+	}
+	promotion.GrantCount++
+	copied := *grant
+	f.grants[grant.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ReleaseGrant(ctx context.Context, grant *entities.PromotionGrant) error {
+	delete(f.grants, grant.ID)
+	f.promotions[grant.PromotionID].GrantCount--
+	return nil
+}
+
+func (f *fakeRepo) SetGrantLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	f.grants[id].GrantLedgerTxID = &ledgerTxID
+	return nil
+}
+
+func (f *fakeRepo) SettleGrant(ctx context.Context, grant *entities.PromotionGrant) (bool, error) {
+	stored := f.grants[grant.ID]
+	if stored.Status != entities.PromotionGrantUnvested {
+		return false, nil
+	}
+	stored.Status = grant.Status
+	stored.ForfeitReason = grant.ForfeitReason
+	stored.SettledAt = grant.SettledAt
+	return true, nil
+}
+
+func (f *fakeRepo) UnsettleGrant(ctx context.Context, id uuid.UUID) error {
+	stored := f.grants[id]
+	stored.Status = entities.PromotionGrantUnvested
+	stored.ForfeitReason = nil
+	stored.SettledAt = nil
+	return nil
+}
+
+func (f *fakeRepo) SetSettleLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	f.grants[id].SettleLedgerTxID = &ledgerTxID
+	return nil
+}
+
+func (f *fakeRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.PromotionGrant, error) {
+	return f.filter(func(g *entities.PromotionGrant) bool { return g.UserID == userID }), nil
+}
+
+func (f *fakeRepo) ListVestable(ctx context.Context, now time.Time, limit int) ([]*entities.PromotionGrant, error) {
+	return f.filter(func(g *entities.PromotionGrant) bool {
+		return g.Status == entities.PromotionGrantUnvested && !g.VestsAt.After(now) && !f.closed[g.UserID]
+	}), nil
+}
+
+func (f *fakeRepo) ListForfeitable(ctx context.Context, limit int) ([]*entities.PromotionGrant, error) {
+	return f.filter(func(g *entities.PromotionGrant) bool {
+		return g.Status == entities.PromotionGrantUnvested && f.closed[g.UserID]
+	}), nil
+}
+
+func (f *fakeRepo) filter(keep func(*entities.PromotionGrant) bool) []*entities.PromotionGrant {
+	var grants []*entities.PromotionGrant
+	for _, grant := range f.grants {
+		if keep(grant) {
+			copied := *grant
+			grants = append(grants, &copied)
+		}
+	}
+	return grants
+}
+
+// fakeLedger keeps account balances and, like the ledger, refuses to take an
+// account negative
+type fakeLedger struct {
+	accounts map[string]*entities.LedgerAccount
+	posted   []*entities.CreateTransactionRequest
+}
+
+func newFakeLedger(budget decimal.Decimal) *fakeLedger {
+	l := &fakeLedger{accounts: map[string]*entities.LedgerAccount{}}
+	l.account(uuid.Nil, entities.AccountTypeSystemPromotions).Balance = budget
+	return l
+}
+
+func (l *fakeLedger) account(userID uuid.UUID, accountType entities.AccountType) *entities.LedgerAccount {
+	key := userID.String() + "/" + string(accountType)
+	if _, ok := l.accounts[key]; !ok {
+		l.accounts[key] = &entities.LedgerAccount{ID: uuid.New(), AccountType: accountType}
+	}
+	return l.accounts[key]
+}
+
+func (l *fakeLedger) balance(userID uuid.UUID, accountType entities.AccountType) string {
+	return l.account(userID, accountType).Balance.String()
+}
+
+func (l *fakeLedger) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return l.account(userID, accountType), nil
+}
+
+func (l *fakeLedger) GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return l.account(uuid.Nil, accountType), nil
+}
+
+func (l *fakeLedger) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	byID := map[uuid.UUID]*entities.LedgerAccount{}
+	for _, account := range l.accounts {
+		byID[account.ID] = account
+	}
+	for _, entry := range req.Entries {
+		if entry.EntryType == entities.EntryTypeCredit && byID[entry.AccountID].Balance.LessThan(entry.Amount) {
+			return nil, errors.New("insufficient balance")
+		}
+	}
+	for _, entry := range req.Entries {
+		account := byID[entry.AccountID]
+		if entry.EntryType == entities.EntryTypeDebit {
+			account.Balance = account.Balance.Add(entry.Amount)
+		} else {
+			account.Balance = account.Balance.Sub(entry.Amount)
+		}
+	}
+	l.posted = append(l.posted, req)
+	return &entities.LedgerTransaction{ID: uuid.New()}, nil
+}
+
+type fakeBuyingPower map[uuid.UUID]decimal.Decimal
+
+func (f fakeBuyingPower) UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f[userID] = f[userID].Add(amount)
+	return nil
+}
+
+type fixture struct {
+	svc         *promotions.Service
+	repo        *fakeRepo
+	ledger      *fakeLedger
+	buyingPower fakeBuyingPower
+	userID      uuid.UUID
+}
+
+func newFixture(budget string) *fixture {
+	f := &fixture{
+		repo:        newFakeRepo(),
+		ledger:      newFakeLedger(decimal.RequireFromString(budget)),
+		buyingPower: fakeBuyingPower{},
+		userID:      uuid.New(),
+	}
+	f.svc = promotions.NewService(f.repo, f.ledger, f.buyingPower, promotions.Config{}, zap.NewNop())
+	return f
+}
+
+func (f *fixture) createPromotion(t *testing.T, trigger entities.PromotionTrigger, vestingDays int) *entities.Promotion {
+	promotion, err := f.svc.CreatePromotion(context.Background(), &entities.CreatePromotionRequest{
+		Code:        " welcome10 ",
+		Name:        "Welcome bonus",
+		Amount:      decimal.NewFromInt(10),
+		Trigger:     trigger,
+		VestingDays: vestingDays,
+	}, uuid.New())
+	require.NoError(t, err)
+	return promotion
+}
+
+func (f *fixture) onlyGrant(t *testing.T) *entities.PromotionGrant {
+	grants, err := f.repo.ListByUser(context.Background(), f.userID)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	return grants[0]
+}
+
+func TestCreatePromotion_ValidatesAmount(t *testing.T) {
+	f := newFixture("100")
+
+	_, err := f.svc.CreatePromotion(context.Background(), &entities.CreatePromotionRequest{
+		Code: "ZERO", Name: "Zero", Trigger: entities.PromotionTriggerManual,
+	}, uuid.New())
+	assert.ErrorIs(t, err, promotions.ErrInvalidPromotion)
+
+	promotion := f.createPromotion(t, entities.PromotionTriggerManual, 0)
+	assert.Equal(t, "WELCOME10", promotion.Code)
+}
+
+func TestHandleKYCReview_GrantsUnvestedSignupCredit(t *testing.T) {
+	f := newFixture("100")
+	f.createPromotion(t, entities.PromotionTriggerSignup, 60)
+
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusRejected, nil))
+	assert.Empty(t, f.repo.grants)
+
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusApproved, nil))
+	grant := f.onlyGrant(t)
+	assert.Equal(t, entities.PromotionGrantUnvested, grant.Status)
+	assert.NotNil(t, grant.GrantLedgerTxID)
+	assert.Equal(t, "10", f.ledger.balance(f.userID, entities.AccountTypePromotionalCredit))
+	assert.Equal(t, "0", f.ledger.balance(f.userID, entities.AccountTypeUSDCBalance))
+	assert.Equal(t, "90", f.ledger.balance(uuid.Nil, entities.AccountTypeSystemPromotions))
+	assert.Equal(t, entities.TransactionTypePromotion, f.ledger.posted[0].TransactionType)
+	assert.Empty(t, f.buyingPower)
+
+	// A repeated approval does not grant twice
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusApproved, nil))
+	assert.Len(t, f.repo.grants, 1)
+
+	summary, err := f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "10", summary.Unvested.String())
+	assert.True(t, summary.Vested.IsZero())
+}
+
+func TestSweep_VestsCreditAfterVestingPeriod(t *testing.T) {
+	f := newFixture("100")
+	f.createPromotion(t, entities.PromotionTriggerSignup, 60)
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusApproved, nil))
+
+	report, err := f.svc.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.Vested, "not vested before the period ends")
+
+	grant := f.onlyGrant(t)
+	f.repo.grants[grant.ID].VestsAt = time.Now().Add(-time.Minute)
+
+	report, err = f.svc.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Vested)
+	assert.Equal(t, entities.PromotionGrantVested, f.onlyGrant(t).Status)
+	assert.Equal(t, "0", f.ledger.balance(f.userID, entities.AccountTypePromotionalCredit))
+	assert.Equal(t, "10", f.ledger.balance(f.userID, entities.AccountTypeUSDCBalance))
+	assert.Equal(t, "10", f.buyingPower[f.userID].String())
+}
+
+func TestSweep_ForfeitsCreditWhenAccountClosedEarly(t *testing.T) {
+	f := newFixture("100")
+	f.createPromotion(t, entities.PromotionTriggerFirstDeposit, 60)
+	require.NoError(t, f.svc.HandleDepositCredited(context.Background(), f.userID))
+	f.repo.closed[f.userID] = true
+
+	report, err := f.svc.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Forfeited)
+
+	grant := f.onlyGrant(t)
+	assert.Equal(t, entities.PromotionGrantForfeited, grant.Status)
+	require.NotNil(t, grant.ForfeitReason)
+	assert.Equal(t, "0", f.ledger.balance(f.userID, entities.AccountTypePromotionalCredit))
+	assert.Equal(t, "100", f.ledger.balance(uuid.Nil, entities.AccountTypeSystemPromotions))
+	assert.Equal(t, entities.TransactionTypePromotionClawback, f.ledger.posted[1].TransactionType)
+	assert.Empty(t, f.buyingPower)
+}
+
+func TestGrant_ReleasesGrantWhenBudgetExhausted(t *testing.T) {
+	f := newFixture("5")
+	promotion := f.createPromotion(t, entities.PromotionTriggerManual, 0)
+
+	_, err := f.svc.Grant(context.Background(), promotion.ID, f.userID, uuid.New())
+	require.Error(t, err)
+	assert.Empty(t, f.repo.grants)
+	assert.Zero(t, f.repo.promotions[promotion.ID].GrantCount)
+}
+
+func TestGrant_WithoutVestingIsAvailableImmediately(t *testing.T) {
+	f := newFixture("100")
+	promotion := f.createPromotion(t, entities.PromotionTriggerManual, 0)
+
+	grant, err := f.svc.Grant(context.Background(), promotion.ID, f.userID, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entities.PromotionGrantVested, grant.Status)
+	assert.Equal(t, "10", f.buyingPower[f.userID].String())
+
+	_, err = f.svc.SetPromotionActive(context.Background(), promotion.ID, false)
+	require.NoError(t, err)
+	_, err = f.svc.Grant(context.Background(), promotion.ID, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, entities.ErrPromotionInactive)
+}
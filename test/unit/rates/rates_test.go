@@ -0,0 +1,222 @@
+package rates_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/rates"
+)
+
+type storedKey struct {
+	pair   entities.CurrencyPair
+	at     time.Time
+	source string
+}
+
+type fakeRepo struct {
+	rates map[storedKey]*entities.ExchangeRate
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{rates: map[storedKey]*entities.ExchangeRate{}}
+}
+
+func (f *fakeRepo) Insert(ctx context.Context, rates []*entities.ExchangeRate) (int, error) {
+	inserted := 0
+	for _, rate := range rates {
+		key := storedKey{pair: rate.Pair, at: rate.ObservedAt, source: rate.Source}
+		if _, ok := f.rates[key]; ok {
+			continue
+		}
+		f.rates[key] = rate
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (f *fakeRepo) Nearest(ctx context.Context, pair entities.CurrencyPair, at time.Time, window time.Duration) (*entities.ExchangeRate, error) {
+	var nearest *entities.ExchangeRate
+	var distance time.Duration
+	for _, rate := range f.rates {
+		if rate.Pair != pair {
+			continue
+		}
+		d := rate.ObservedAt.Sub(at)
+		if d < 0 {
+			d = -d
+		}
+		if d > window {
+			continue
+		}
+		if nearest == nil || d < distance {
+			nearest, distance = rate, d
+		}
+	}
+	if nearest == nil {
+		return nil, entities.ErrRateNotFound
+	}
+	return nearest, nil
+}
+
+type fakeProvider struct {
+	calls [][2]time.Time
+	step  time.Duration
+	rate  decimal.Decimal
+	err   error
+}
+
+func (p *fakeProvider) History(ctx context.Context, pair entities.CurrencyPair, from, to time.Time) ([]*entities.ExchangeRate, error) {
+	p.calls = append(p.calls, [2]time.Time{from, to})
+	if p.err != nil {
+		return nil, p.err
+	}
+	var history []*entities.ExchangeRate
+	for at := from.Truncate(p.step); !at.After(to); at = at.Add(p.step) {
+		if at.Before(from) {
+			continue
+		}
+		history = append(history, &entities.ExchangeRate{Pair: pair, Rate: p.rate, ObservedAt: at})
+	}
+	return history, nil
+}
+
+var (
+	usdcUSD = entities.NewCurrencyPair("USDC", "USD")
+	base    = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+)
+
+func record(t *testing.T, svc *rates.Service, pair entities.CurrencyPair, rate string, at time.Time) {
+	t.Helper()
+	require.NoError(t, svc.Record(context.Background(), &entities.ExchangeRate{
+		Pair:       pair,
+		Rate:       decimal.RequireFromString(rate),
+		ObservedAt: at,
+		Source:     entities.RateSourceDeposit,
+	}))
+}
+
+func TestRateAt_UsesNearestStoredRate(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.DefaultConfig(), zap.NewNop())
+	record(t, svc, usdcUSD, "0.9990", base.Add(-2*time.Hour))
+	record(t, svc, usdcUSD, "1.0002", base.Add(30*time.Minute))
+
+	rate, err := svc.RateAt(context.Background(), usdcUSD, base)
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0002", rate.String())
+}
+
+func TestRateAt_FallsBackToInversePair(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.DefaultConfig(), zap.NewNop())
+	record(t, svc, entities.NewCurrencyPair("USD", "EURC"), "0.8", base)
+
+	rate, err := svc.RateAt(context.Background(), entities.NewCurrencyPair("eurc", "usd"), base)
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.25", rate.String())
+}
+
+func TestRateAt_SameCurrencyIsOne(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.DefaultConfig(), zap.NewNop())
+
+	rate, err := svc.RateAt(context.Background(), entities.NewCurrencyPair("USD", "USD"), base)
+
+	require.NoError(t, err)
+	assert.True(t, rate.Equal(decimal.NewFromInt(1)))
+}
+
+func TestRateAt_NotFoundBeyondMaxSkew(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.Config{MaxSkew: time.Hour}, zap.NewNop())
+	record(t, svc, usdcUSD, "1", base.Add(-3*time.Hour))
+
+	_, err := svc.RateAt(context.Background(), usdcUSD, base)
+
+	assert.ErrorIs(t, err, entities.ErrRateNotFound)
+}
+
+func TestRateAt_LoadsProviderHistoryForGaps(t *testing.T) {
+	repo := newFakeRepo()
+	provider := &fakeProvider{step: time.Hour, rate: decimal.RequireFromString("0.9997")}
+	svc := rates.NewService(repo, rates.Config{MaxSkew: 2 * time.Hour}, zap.NewNop())
+	svc.SetProvider(provider)
+
+	rate, err := svc.RateAt(context.Background(), usdcUSD, base.Add(10*time.Minute))
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.9997", rate.String())
+	require.Len(t, provider.calls, 1)
+	assert.Equal(t, base.Add(-110*time.Minute), provider.calls[0][0])
+	for _, stored := range repo.rates {
+		assert.Equal(t, entities.RateSourceBackfill, stored.Source)
+	}
+
+	// Stored history answers the next lookup without the provider
+	_, err = svc.RateAt(context.Background(), usdcUSD, base)
+	require.NoError(t, err)
+	assert.Len(t, provider.calls, 1)
+}
+
+func TestRateAt_ProviderFailureIsNotFound(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.DefaultConfig(), zap.NewNop())
+	svc.SetProvider(&fakeProvider{err: errors.New("rate limited")})
+
+	_, err := svc.RateAt(context.Background(), usdcUSD, base)
+
+	assert.ErrorIs(t, err, entities.ErrRateNotFound)
+}
+
+func TestConvert(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.DefaultConfig(), zap.NewNop())
+	record(t, svc, entities.NewCurrencyPair("EURC", "USD"), "1.08", base)
+
+	usd, err := svc.Convert(context.Background(), decimal.NewFromInt(250), "EURC", "USD", base)
+
+	require.NoError(t, err)
+	assert.Equal(t, "270", usd.String())
+}
+
+func TestBackfill_ChunksAndSkipsStoredRates(t *testing.T) {
+	repo := newFakeRepo()
+	provider := &fakeProvider{step: 24 * time.Hour, rate: decimal.NewFromInt(1)}
+	svc := rates.NewService(repo, rates.Config{BackfillChunk: 7 * 24 * time.Hour}, zap.NewNop())
+	svc.SetProvider(provider)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(20 * 24 * time.Hour)
+	svc.SetClock(func() time.Time { return to.Add(time.Hour) })
+
+	first, err := svc.Backfill(context.Background(), usdcUSD, from, to)
+	require.NoError(t, err)
+	assert.Len(t, provider.calls, 3)
+	assert.Equal(t, to, provider.calls[2][1])
+	assert.Equal(t, first.Stored, len(repo.rates))
+	assert.Equal(t, 21, first.Stored)
+
+	second, err := svc.Backfill(context.Background(), usdcUSD, from, to)
+	require.NoError(t, err)
+	assert.Zero(t, second.Stored)
+}
+
+func TestBackfill_RequiresProvider(t *testing.T) {
+	svc := rates.NewService(newFakeRepo(), rates.DefaultConfig(), zap.NewNop())
+
+	_, err := svc.Backfill(context.Background(), usdcUSD, base.Add(-time.Hour), base)
+
+	assert.ErrorIs(t, err, rates.ErrNoProvider)
+}
+
+func TestParseCurrencyPair(t *testing.T) {
+	pair, err := entities.ParseCurrencyPair(" usdc/usd ")
+	require.NoError(t, err)
+	assert.Equal(t, usdcUSD, pair)
+
+	_, err = entities.ParseCurrencyPair("USDC")
+	assert.ErrorIs(t, err, entities.ErrInvalidCurrencyPair)
+}
@@ -0,0 +1,247 @@
+package reactivation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/reactivation"
+)
+
+type fakeRepo struct {
+	closures map[uuid.UUID]*entities.AccountClosure
+	requests map[uuid.UUID]*entities.ReactivationRequest
+}
+
+func (f *fakeRepo) GetClosure(ctx context.Context, userID uuid.UUID) (*entities.AccountClosure, error) {
+	copied := *f.closures[userID]
+	return &copied, nil
+}
+
+func (f *fakeRepo) Create(ctx context.Context, req *entities.ReactivationRequest) (*entities.ReactivationRequest, bool, error) {
+	if existing, err := f.GetOpenByUser(ctx, req.UserID); err == nil {
+		return existing, false, nil
+	}
+	copied := *req
+	f.requests[req.ID] = &copied
+	return req, true, nil
+}
+
+func (f *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.ReactivationRequest, error) {
+	req, ok := f.requests[id]
+	if !ok {
+		return nil, entities.ErrReactivationNotFound
+	}
+	copied := *req
+	return &copied, nil
+}
+
+func (f *fakeRepo) GetOpenByUser(ctx context.Context, userID uuid.UUID) (*entities.ReactivationRequest, error) {
+	for _, req := range f.requests {
+		if req.UserID == userID && req.IsOpen() {
+			copied := *req
+			return &copied, nil
+		}
+	}
+	return nil, entities.ErrReactivationNotFound
+}
+
+func (f *fakeRepo) List(ctx context.Context, status entities.ReactivationStatus, limit, offset int) ([]*entities.ReactivationRequest, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) Update(ctx context.Context, req *entities.ReactivationRequest) error {
+	copied := *req
+	f.requests[req.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ReopenAccount(ctx context.Context, userID uuid.UUID) error {
+	closure := f.closures[userID]
+	closure.IsActive = true
+	closure.Reason = ""
+	closure.ClosedAt = nil
+	return nil
+}
+
+type fakeVerifier struct{}
+
+func (fakeVerifier) GenerateKYCURL(ctx context.Context, userID uuid.UUID) (string, error) {
+	return "https://kyc.example.com/" + userID.String(), nil
+}
+
+type fakeScreener struct {
+	allowed bool
+}
+
+func (f fakeScreener) ScreenUser(ctx context.Context, userID uuid.UUID) (*entities.JurisdictionDecision, error) {
+	return &entities.JurisdictionDecision{CountryCode: "US", Allowed: f.allowed}, nil
+}
+
+type fakeCases struct {
+	opened   []entities.AdminCaseType
+	resolved []uuid.UUID
+}
+
+func (f *fakeCases) Open(ctx context.Context, userID uuid.UUID, caseType entities.AdminCaseType, summary string, details map[string]interface{}) (*entities.AdminCase, error) {
+	f.opened = append(f.opened, caseType)
+	return &entities.AdminCase{ID: uuid.New(), UserID: userID, CaseType: caseType}, nil
+}
+
+func (f *fakeCases) Update(ctx context.Context, id uuid.UUID, req *entities.UpdateAdminCaseRequest, adminID uuid.UUID) (*entities.AdminCase, error) {
+	f.resolved = append(f.resolved, id)
+	return &entities.AdminCase{ID: id, Status: req.Status}, nil
+}
+
+type fakeHoldings struct{}
+
+func (fakeHoldings) Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error) {
+	return &entities.Balance{UserID: userID, BuyingPower: decimal.RequireFromString("120.50")}, nil
+}
+
+func (fakeHoldings) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Position, error) {
+	return []*entities.Position{
+		{UserID: userID, MarketValue: decimal.NewFromInt(300)},
+		{UserID: userID, MarketValue: decimal.NewFromInt(75)},
+	}, nil
+}
+
+type fixture struct {
+	svc    *reactivation.Service
+	repo   *fakeRepo
+	cases  *fakeCases
+	userID uuid.UUID
+}
+
+func newFixture(reason entities.AccountClosureReason, allowed bool) *fixture {
+	userID := uuid.New()
+	f := &fixture{
+		repo: &fakeRepo{
+			closures: map[uuid.UUID]*entities.AccountClosure{
+				userID: {UserID: userID, Email: "closed@example.com", Reason: reason},
+			},
+			requests: map[uuid.UUID]*entities.ReactivationRequest{},
+		},
+		cases:  &fakeCases{},
+		userID: userID,
+	}
+	f.svc = reactivation.NewService(f.repo, fakeVerifier{}, fakeScreener{allowed: allowed}, f.cases,
+		fakeHoldings{}, fakeHoldings{}, nil, zap.NewNop())
+	return f
+}
+
+func (f *fixture) isActive() bool {
+	return f.repo.closures[f.userID].IsActive
+}
+
+func TestStart_SnapshotsHoldingsAndReturnsRequestInProgress(t *testing.T) {
+	f := newFixture(entities.ClosureUserRequest, true)
+
+	req, err := f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReactivationPendingVerification, req.Status)
+	assert.False(t, req.RequiresApproval)
+	require.NotNil(t, req.VerificationURL)
+	require.NotNil(t, req.PriorHoldings)
+	assert.Equal(t, "120.5", req.PriorHoldings.BuyingPower.String())
+	assert.Equal(t, 2, req.PriorHoldings.PositionCount)
+	assert.Equal(t, "375", req.PriorHoldings.PositionsValue.String())
+
+	again, err := f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, req.ID, again.ID)
+}
+
+func TestStart_RejectsActiveAccount(t *testing.T) {
+	f := newFixture(entities.ClosureUserRequest, true)
+	f.repo.closures[f.userID].IsActive = true
+
+	_, err := f.svc.Start(context.Background(), f.userID)
+	assert.ErrorIs(t, err, entities.ErrAccountNotClosed)
+}
+
+func TestHandleKYCReview_ReopensSelfClosedAccount(t *testing.T) {
+	f := newFixture(entities.ClosureUserRequest, true)
+	req, err := f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusProcessing, nil))
+	assert.False(t, f.isActive(), "waits for a final KYC decision")
+
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusApproved, nil))
+	assert.True(t, f.isActive())
+	assert.Empty(t, f.cases.opened)
+
+	done, err := f.svc.Get(context.Background(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReactivationCompleted, done.Status)
+	require.NotNil(t, done.RestoredHoldings)
+	assert.True(t, done.RestoredHoldings.Matches(done.PriorHoldings))
+}
+
+func TestHandleKYCReview_ForCauseWaitsForAdminApproval(t *testing.T) {
+	f := newFixture(entities.ClosureForCause, true)
+	req, err := f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.True(t, req.RequiresApproval)
+
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusApproved, nil))
+	assert.False(t, f.isActive())
+	assert.Equal(t, []entities.AdminCaseType{entities.AdminCaseAccountReactivation}, f.cases.opened)
+
+	pending, err := f.svc.Get(context.Background(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReactivationPendingApproval, pending.Status)
+	require.NotNil(t, pending.CaseID)
+
+	adminID := uuid.New()
+	approved, err := f.svc.Approve(context.Background(), req.ID, adminID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReactivationCompleted, approved.Status)
+	assert.Equal(t, &adminID, approved.ReviewedBy)
+	assert.True(t, f.isActive())
+	assert.Equal(t, []uuid.UUID{*pending.CaseID}, f.cases.resolved)
+
+	_, err = f.svc.Approve(context.Background(), req.ID, adminID, nil)
+	assert.ErrorIs(t, err, entities.ErrReactivationNotPending)
+}
+
+func TestApprove_RequiresVerificationFirst(t *testing.T) {
+	f := newFixture(entities.ClosureForCause, true)
+	req, err := f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+
+	_, err = f.svc.Approve(context.Background(), req.ID, uuid.New(), nil)
+	assert.ErrorIs(t, err, entities.ErrReactivationNotPending)
+	assert.False(t, f.isActive())
+}
+
+func TestHandleKYCReview_RejectsFailedVerificationOrScreening(t *testing.T) {
+	f := newFixture(entities.ClosureUserRequest, true)
+	req, err := f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusRejected, []string{"document expired"}))
+
+	rejected, err := f.svc.Get(context.Background(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReactivationRejected, rejected.Status)
+	assert.Contains(t, *rejected.RejectionReason, "document expired")
+	assert.False(t, f.isActive())
+
+	f = newFixture(entities.ClosureUserRequest, false)
+	req, err = f.svc.Start(context.Background(), f.userID)
+	require.NoError(t, err)
+	require.NoError(t, f.svc.HandleKYCReview(context.Background(), f.userID, entities.KYCStatusApproved, nil))
+
+	rejected, err = f.svc.Get(context.Background(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReactivationRejected, rejected.Status)
+	assert.Equal(t, "US", *rejected.ScreenedCountry)
+	assert.False(t, f.isActive())
+}
@@ -0,0 +1,185 @@
+package recipients_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/recipients"
+	"github.com/stack-service/stack_service/internal/infrastructure/adapters"
+)
+
+type fakeVerifier struct {
+	check *entities.BankAccountCheck
+	err   error
+	calls int
+}
+
+func (v *fakeVerifier) CheckAccount(ctx context.Context, routingNumber, accountNumber string) (*entities.BankAccountCheck, error) {
+	v.calls++
+	return v.check, v.err
+}
+
+type fakeProfiles map[uuid.UUID]string
+
+func (p fakeProfiles) GetByID(ctx context.Context, id uuid.UUID) (*entities.UserProfile, error) {
+	first := p[id]
+	return &entities.UserProfile{ID: id, FirstName: &first}, nil
+}
+
+func openAccount(owners ...string) *entities.BankAccountCheck {
+	return &entities.BankAccountCheck{Found: true, Open: true, OwnerNames: owners, Reference: "req-1"}
+}
+
+func newCheckedService(verifier *fakeVerifier) (*recipients.Service, *fakeRepo) {
+	repo := newFakeRepo()
+	service := recipients.NewService(repo, recipients.Config{MaxPerUser: 10}, zap.NewNop())
+	service.SetAccountVerifier(verifier, nil, recipients.AccountCheckConfig{RequiredAbove: decimal.NewFromInt(1000)})
+	return service, repo
+}
+
+func saveBank(t *testing.T, service *recipients.Service, userID uuid.UUID, holder string) *entities.WithdrawalRecipient {
+	t.Helper()
+	recipient, err := service.Create(context.Background(), userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindBank, Label: "Checking", AccountHolderName: holder,
+		RoutingNumber: "021000021", AccountNumber: "123456789",
+	})
+	require.NoError(t, err)
+	return recipient
+}
+
+func TestAccountCheck_RunsWhenBankRecipientIsSaved(t *testing.T) {
+	verifier := &fakeVerifier{check: openAccount("MS JANE Q. DOE")}
+	service, _ := newCheckedService(verifier)
+
+	recipient := saveBank(t, service, uuid.New(), "Jane Doe")
+	assert.Equal(t, entities.AccountCheckVerified, recipient.AccountCheckStatus)
+	assert.Nil(t, recipient.AccountCheckReason)
+	assert.NotNil(t, recipient.AccountCheckedAt)
+
+	_, err := service.Create(context.Background(), uuid.New(), &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Cold wallet", Chain: "SOL-DEVNET", Address: solanaAddress,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, verifier.calls, "crypto recipients are not checked")
+}
+
+func TestAccountCheck_RecordsWhyAnAccountFailed(t *testing.T) {
+	cases := map[string]*entities.BankAccountCheck{
+		entities.AccountCheckReasonNotFound:      {},
+		entities.AccountCheckReasonClosed:        {Found: true, OwnerNames: []string{"Jane Doe"}},
+		entities.AccountCheckReasonOwnerMismatch: openAccount("John Smith"),
+	}
+	for reason, check := range cases {
+		t.Run(reason, func(t *testing.T) {
+			service, _ := newCheckedService(&fakeVerifier{check: check})
+			recipient := saveBank(t, service, uuid.New(), "Jane Doe")
+			assert.Equal(t, entities.AccountCheckFailed, recipient.AccountCheckStatus)
+			require.NotNil(t, recipient.AccountCheckReason)
+			assert.Equal(t, reason, *recipient.AccountCheckReason)
+		})
+	}
+}
+
+func TestAccountCheck_MatchesTheUsersProfileName(t *testing.T) {
+	userID := uuid.New()
+	verifier := &fakeVerifier{check: openAccount("Jane Doe")}
+	repo := newFakeRepo()
+	service := recipients.NewService(repo, recipients.Config{MaxPerUser: 10}, zap.NewNop())
+	service.SetAccountVerifier(verifier, fakeProfiles{userID: "Mallory"}, recipients.AccountCheckConfig{})
+
+	// The holder name typed by the user matches, but the account is not theirs
+	recipient := saveBank(t, service, userID, "Jane Doe")
+	assert.Equal(t, entities.AccountCheckFailed, recipient.AccountCheckStatus)
+	assert.Equal(t, entities.AccountCheckReasonOwnerMismatch, *recipient.AccountCheckReason)
+}
+
+func TestResolveForACHPayout_RequiresVerificationAboveThreshold(t *testing.T) {
+	userID := uuid.New()
+	verifier := &fakeVerifier{err: errors.New("provider down")}
+	service, _ := newCheckedService(verifier)
+	recipient := saveBank(t, service, userID, "Jane Doe")
+	assert.Equal(t, entities.AccountCheckUnchecked, recipient.AccountCheckStatus, "an outage leaves the account unchecked")
+	ctx := context.Background()
+
+	_, err := service.ResolveForACHPayout(ctx, userID, recipient.ID, decimal.NewFromInt(500))
+	assert.NoError(t, err, "small payouts do not need a verified account")
+	_, err = service.ResolveForACHPayout(ctx, userID, recipient.ID, decimal.NewFromInt(5000))
+	assert.ErrorIs(t, err, recipients.ErrAccountCheckRequired)
+
+	// Once the provider is back the large payout checks the account inline
+	verifier.err = nil
+	verifier.check = openAccount("Jane Doe")
+	resolved, err := service.ResolveForACHPayout(ctx, userID, recipient.ID, decimal.NewFromInt(5000))
+	require.NoError(t, err)
+	assert.Equal(t, entities.AccountCheckVerified, resolved.AccountCheckStatus)
+}
+
+func TestResolveForACHPayout_BlocksFailedAccountsAtAnyAmount(t *testing.T) {
+	userID := uuid.New()
+	service, _ := newCheckedService(&fakeVerifier{check: &entities.BankAccountCheck{}})
+	recipient := saveBank(t, service, userID, "Jane Doe")
+
+	_, err := service.ResolveForACHPayout(context.Background(), userID, recipient.ID, decimal.NewFromInt(10))
+	assert.ErrorIs(t, err, recipients.ErrAccountCheckFailed)
+}
+
+func TestResolveForACHPayout_RejectsCryptoRecipients(t *testing.T) {
+	userID := uuid.New()
+	repo := newFakeRepo()
+	service := recipients.NewService(repo, recipients.Config{MaxPerUser: 10}, zap.NewNop())
+	recipient, err := service.Create(context.Background(), userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Cold wallet", Chain: "SOL-DEVNET", Address: solanaAddress,
+	})
+	require.NoError(t, err)
+
+	_, err = service.ResolveForACHPayout(context.Background(), userID, recipient.ID, decimal.NewFromInt(10))
+	assert.ErrorIs(t, err, recipients.ErrNotBankRecipient)
+	_, err = service.VerifyAccount(context.Background(), userID, recipient.ID)
+	assert.ErrorIs(t, err, recipients.ErrAccountCheckUnavailable)
+}
+
+func TestBankLinkClient_CheckAccount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/verify", r.URL.Path)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "client", body["client_id"])
+
+		switch body["account_number"] {
+		case "404":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"request_id":"r2","error":{"error_code":"ACCOUNT_NOT_FOUND","error_message":"no such account"}}`))
+		case "500":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"request_id":"r3","error":{"error_code":"INTERNAL","error_message":"try later"}}`))
+		default:
+			w.Write([]byte(`{"request_id":"r1","account":{"status":"closed","owners":[{"names":["Jane Doe","J Doe"]}]}}`))
+		}
+	}))
+	defer server.Close()
+	client := adapters.NewBankLinkClient(adapters.BankLinkConfig{BaseURL: server.URL, ClientID: "client", Secret: "secret", Timeout: time.Second})
+	ctx := context.Background()
+
+	check, err := client.CheckAccount(ctx, "021000021", "123")
+	require.NoError(t, err)
+	assert.Equal(t, &entities.BankAccountCheck{Found: true, OwnerNames: []string{"Jane Doe", "J Doe"}, Reference: "r1"}, check)
+
+	check, err = client.CheckAccount(ctx, "021000021", "404")
+	require.NoError(t, err)
+	assert.False(t, check.Found)
+
+	_, err = client.CheckAccount(ctx, "021000021", "500")
+	assert.ErrorContains(t, err, "INTERNAL")
+}
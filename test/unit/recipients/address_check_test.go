@@ -0,0 +1,169 @@
+package recipients_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/recipients"
+	"github.com/stack-service/stack_service/pkg/crypto"
+)
+
+type fakeSender struct {
+	sent []decimal.Decimal
+}
+
+func (f *fakeSender) SendMicroDeposit(ctx context.Context, chain, address string, amount decimal.Decimal, idempotencyKey string) (string, error) {
+	f.sent = append(f.sent, amount)
+	return "transfer-" + idempotencyKey, nil
+}
+
+type addressCheckFixture struct {
+	service   *recipients.Service
+	repo      *fakeRepo
+	sender    *fakeSender
+	userID    uuid.UUID
+	recipient *entities.WithdrawalRecipient
+	clock     time.Time
+}
+
+func newAddressCheckFixture(t *testing.T, address string) *addressCheckFixture {
+	f := &addressCheckFixture{repo: newFakeRepo(), sender: &fakeSender{}, userID: uuid.New(), clock: time.Now()}
+	f.service = recipients.NewService(f.repo, recipients.Config{}, zap.NewNop())
+	f.service.SetClock(func() time.Time { return f.clock })
+	f.service.SetAddressChecks(f.sender, recipients.DefaultAddressCheckConfig())
+
+	recipient, err := f.service.Create(context.Background(), f.userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Wallet", Chain: "SOL-DEVNET", Address: address,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.AddressCheckUnchecked, recipient.AddressCheckStatus)
+	f.recipient = recipient
+	return f
+}
+
+func TestResolveForCryptoWithdrawal_RequiresVerifiedNewAddressAboveThreshold(t *testing.T) {
+	f := newAddressCheckFixture(t, solanaAddress)
+	ctx := context.Background()
+
+	_, err := f.service.ResolveForCryptoWithdrawal(ctx, f.userID, f.recipient.ID, decimal.NewFromInt(1000))
+	assert.NoError(t, err, "amounts up to the threshold need no check")
+
+	_, err = f.service.ResolveForCryptoWithdrawal(ctx, f.userID, f.recipient.ID, decimal.NewFromInt(1001))
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckRequired)
+
+	f.clock = f.clock.Add(31 * 24 * time.Hour)
+	_, err = f.service.ResolveForCryptoWithdrawal(ctx, f.userID, f.recipient.ID, decimal.NewFromInt(1001))
+	assert.NoError(t, err, "established addresses need no check")
+}
+
+func TestMicroDepositCheck_VerifiesExactAmount(t *testing.T) {
+	f := newAddressCheckFixture(t, solanaAddress)
+	ctx := context.Background()
+
+	started, err := f.service.StartAddressCheck(ctx, f.userID, f.recipient.ID, entities.AddressCheckMicroDeposit)
+	require.NoError(t, err)
+	require.Len(t, f.sender.sent, 1)
+	amount := f.sender.sent[0]
+	assert.True(t, amount.GreaterThanOrEqual(decimal.RequireFromString("0.01")) && amount.LessThan(decimal.RequireFromString("0.1")))
+	assert.Equal(t, entities.AddressCheckPending, started.AddressCheckStatus)
+
+	_, err = f.service.StartAddressCheck(ctx, f.userID, f.recipient.ID, entities.AddressCheckSignature)
+	assert.ErrorIs(t, err, recipients.ErrMicroDepositPending, "a sent deposit cannot be replaced")
+	assert.Len(t, f.sender.sent, 1)
+
+	_, err = f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, &entities.ConfirmAddressCheckRequest{Amount: "0.5"})
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckMismatch)
+
+	verified, err := f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, &entities.ConfirmAddressCheckRequest{Amount: amount.StringFixed(6)})
+	require.NoError(t, err, "trailing zeros are the same amount")
+	assert.Equal(t, entities.AddressCheckVerified, verified.AddressCheckStatus)
+	require.NotNil(t, verified.AddressCheckedAt)
+
+	_, err = f.service.ResolveForCryptoWithdrawal(ctx, f.userID, f.recipient.ID, decimal.NewFromInt(50000))
+	assert.NoError(t, err)
+}
+
+func TestMicroDepositCheck_FailsAfterMaxAttemptsAndExpires(t *testing.T) {
+	f := newAddressCheckFixture(t, solanaAddress)
+	ctx := context.Background()
+	wrong := &entities.ConfirmAddressCheckRequest{Amount: "1"}
+
+	_, err := f.service.StartAddressCheck(ctx, f.userID, f.recipient.ID, entities.AddressCheckMicroDeposit)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, wrong)
+		assert.ErrorIs(t, err, recipients.ErrAddressCheckMismatch)
+	}
+	_, err = f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, wrong)
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckFailed)
+	_, err = f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, &entities.ConfirmAddressCheckRequest{Amount: f.sender.sent[0].String()})
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckFailed, "the right amount no longer counts")
+
+	_, err = f.service.StartAddressCheck(ctx, f.userID, f.recipient.ID, entities.AddressCheckMicroDeposit)
+	assert.ErrorIs(t, err, recipients.ErrMicroDepositPending)
+
+	f.clock = f.clock.Add(73 * time.Hour)
+	restarted, err := f.service.StartAddressCheck(ctx, f.userID, f.recipient.ID, entities.AddressCheckMicroDeposit)
+	require.NoError(t, err)
+	assert.Zero(t, restarted.AddressCheckAttempts)
+	assert.Len(t, f.sender.sent, 2)
+
+	f.clock = f.clock.Add(73 * time.Hour)
+	_, err = f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, &entities.ConfirmAddressCheckRequest{Amount: f.sender.sent[1].String()})
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckExpired)
+	assert.Equal(t, entities.AddressCheckExpired, f.repo.recipients[f.recipient.ID].AddressCheckStatus)
+}
+
+func TestSignatureCheck_VerifiesSignedMessage(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	f := newAddressCheckFixture(t, crypto.EncodeBase58(pub))
+	ctx := context.Background()
+
+	started, err := f.service.StartAddressCheck(ctx, f.userID, f.recipient.ID, entities.AddressCheckSignature)
+	require.NoError(t, err)
+	assert.Contains(t, started.AddressCheckMessage, f.recipient.Address)
+	assert.Empty(t, f.sender.sent)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, &entities.ConfirmAddressCheckRequest{
+		Signature: crypto.EncodeBase58(ed25519.Sign(otherKey, []byte(started.AddressCheckMessage))),
+	})
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckMismatch)
+
+	verified, err := f.service.ConfirmAddressCheck(ctx, f.userID, f.recipient.ID, &entities.ConfirmAddressCheckRequest{
+		Signature: crypto.EncodeBase58(ed25519.Sign(key, []byte(started.AddressCheckMessage))),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.AddressCheckVerified, verified.AddressCheckStatus)
+}
+
+func TestAddressCheck_UnavailableUntilConfigured(t *testing.T) {
+	repo := newFakeRepo()
+	service := recipients.NewService(repo, recipients.Config{}, zap.NewNop())
+	userID := uuid.New()
+	recipient, err := service.Create(context.Background(), userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Wallet", Chain: "SOL-DEVNET", Address: solanaAddress,
+	})
+	require.NoError(t, err)
+
+	_, err = service.StartAddressCheck(context.Background(), userID, recipient.ID, entities.AddressCheckSignature)
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckUnavailable)
+	_, err = service.ResolveForCryptoWithdrawal(context.Background(), userID, recipient.ID, decimal.NewFromInt(100000))
+	assert.NoError(t, err)
+
+	service.SetAddressChecks(nil, recipients.DefaultAddressCheckConfig())
+	_, err = service.StartAddressCheck(context.Background(), userID, recipient.ID, entities.AddressCheckMicroDeposit)
+	assert.ErrorIs(t, err, recipients.ErrAddressCheckUnavailable, "micro-deposits need a sender")
+}
@@ -0,0 +1,252 @@
+package recipients_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/recipients"
+)
+
+const solanaAddress = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+
+type fakeRepo struct {
+	recipients map[uuid.UUID]*entities.WithdrawalRecipient
+	used       []uuid.UUID
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{recipients: map[uuid.UUID]*entities.WithdrawalRecipient{}}
+}
+
+func (r *fakeRepo) Create(ctx context.Context, recipient *entities.WithdrawalRecipient) error {
+	recipient.Fingerprint = recipient.DestinationKey()
+	for _, existing := range r.recipients {
+		if existing.UserID == recipient.UserID && existing.Fingerprint == recipient.Fingerprint {
+			return entities.ErrRecipientExists
+		}
+	}
+	r.recipients[recipient.ID] = recipient
+	return nil
+}
+
+func (r *fakeRepo) Get(ctx context.Context, userID, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	recipient, ok := r.recipients[id]
+	if !ok || recipient.UserID != userID {
+		return nil, entities.ErrRecipientNotFound
+	}
+	return recipient, nil
+}
+
+func (r *fakeRepo) GetByID(ctx context.Context, id uuid.UUID) (*entities.WithdrawalRecipient, error) {
+	recipient, ok := r.recipients[id]
+	if !ok {
+		return nil, entities.ErrRecipientNotFound
+	}
+	return recipient, nil
+}
+
+func (r *fakeRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WithdrawalRecipient, error) {
+	var list []*entities.WithdrawalRecipient
+	for _, recipient := range r.recipients {
+		if recipient.UserID == userID {
+			list = append(list, recipient)
+		}
+	}
+	return list, nil
+}
+
+func (r *fakeRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	list, _ := r.ListByUser(ctx, userID)
+	return len(list), nil
+}
+
+func (r *fakeRepo) UpdateLabel(ctx context.Context, userID, id uuid.UUID, label string) error {
+	recipient, err := r.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	recipient.Label = label
+	return nil
+}
+
+func (r *fakeRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := r.Get(ctx, userID, id); err != nil {
+		return err
+	}
+	delete(r.recipients, id)
+	return nil
+}
+
+func (r *fakeRepo) MarkUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.used = append(r.used, id)
+	return nil
+}
+
+func (r *fakeRepo) SetFlag(ctx context.Context, id uuid.UUID, flagged bool, reason string, adminID uuid.UUID, at time.Time) error {
+	recipient, ok := r.recipients[id]
+	if !ok {
+		return entities.ErrRecipientNotFound
+	}
+	recipient.Status = entities.RecipientStatusUnverified
+	if flagged {
+		recipient.Status = entities.RecipientStatusFlagged
+		recipient.FlagReason = &reason
+	}
+	return nil
+}
+
+func (r *fakeRepo) SetAccountCheck(ctx context.Context, id uuid.UUID, status entities.AccountCheckStatus, reason *string, at time.Time) error {
+	recipient, ok := r.recipients[id]
+	if !ok || recipient.Kind != entities.RecipientKindBank {
+		return entities.ErrRecipientNotFound
+	}
+	recipient.AccountCheckStatus = status
+	recipient.AccountCheckReason = reason
+	recipient.AccountCheckedAt = &at
+	return nil
+}
+
+func (r *fakeRepo) SetAddressCheck(ctx context.Context, recipient *entities.WithdrawalRecipient) error {
+	if _, ok := r.recipients[recipient.ID]; !ok || recipient.Kind != entities.RecipientKindCrypto {
+		return entities.ErrRecipientNotFound
+	}
+	r.recipients[recipient.ID] = recipient
+	return nil
+}
+
+func (r *fakeRepo) UseAddressCheckAttempt(ctx context.Context, id uuid.UUID) (int, error) {
+	recipient, ok := r.recipients[id]
+	if !ok || recipient.AddressCheckStatus != entities.AddressCheckPending {
+		return 0, entities.ErrRecipientNotFound
+	}
+	recipient.AddressCheckAttempts++
+	return recipient.AddressCheckAttempts, nil
+}
+
+func (r *fakeRepo) CountOtherUsers(ctx context.Context, recipient *entities.WithdrawalRecipient) (int, error) {
+	users := map[uuid.UUID]bool{}
+	for _, other := range r.recipients {
+		if other.UserID != recipient.UserID && other.DestinationKey() == recipient.DestinationKey() {
+			users[other.UserID] = true
+		}
+	}
+	return len(users), nil
+}
+
+func (r *fakeRepo) ListShared(ctx context.Context, minUsers, limit int) ([]*entities.SharedDestination, error) {
+	return nil, nil
+}
+
+func TestValidRoutingNumber(t *testing.T) {
+	assert.True(t, recipients.ValidRoutingNumber("021000021"))
+	assert.True(t, recipients.ValidRoutingNumber("011000015"))
+	assert.False(t, recipients.ValidRoutingNumber("021000022"), "checksum digit is wrong")
+	assert.False(t, recipients.ValidRoutingNumber("02100002"))
+	assert.False(t, recipients.ValidRoutingNumber("02100002a"))
+}
+
+func TestCreate_ValidatesDestinations(t *testing.T) {
+	service := recipients.NewService(newFakeRepo(), recipients.DefaultConfig(), zap.NewNop())
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Cold wallet", Chain: "DOGE", Address: solanaAddress,
+	})
+	assert.ErrorIs(t, err, recipients.ErrInvalidDestination)
+
+	_, err = service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Cold wallet", Chain: "SOL-DEVNET", Address: "0xabc",
+	})
+	assert.ErrorIs(t, err, recipients.ErrInvalidDestination)
+
+	_, err = service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindBank, Label: "Checking", RoutingNumber: "021000021", AccountNumber: "12",
+		AccountHolderName: "Ada Lovelace",
+	})
+	assert.ErrorIs(t, err, recipients.ErrInvalidDestination)
+
+	bank, err := service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindBank, Label: "Checking", RoutingNumber: "021000021", AccountNumber: "000123456789",
+		AccountHolderName: "Ada Lovelace",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "6789", bank.AccountLast4)
+	assert.Equal(t, entities.RecipientStatusUnverified, bank.Status)
+
+	crypto, err := service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Cold wallet", Chain: "sol_devnet", Address: solanaAddress,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SOL-DEVNET", crypto.Chain, "aliases resolve to the chain ID")
+
+	_, err = service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Same wallet", Chain: "SOL-DEVNET", Address: solanaAddress,
+	})
+	assert.ErrorIs(t, err, entities.ErrRecipientExists)
+}
+
+func TestCreate_EnforcesAddressBookLimit(t *testing.T) {
+	service := recipients.NewService(newFakeRepo(), recipients.Config{FirstUseHold: time.Hour, MaxPerUser: 1}, zap.NewNop())
+	userID := uuid.New()
+
+	_, err := service.Create(context.Background(), userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Wallet", Chain: "SOL-DEVNET", Address: solanaAddress,
+	})
+	require.NoError(t, err)
+	_, err = service.Create(context.Background(), userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindBank, Label: "Checking", RoutingNumber: "021000021", AccountNumber: "123456789",
+		AccountHolderName: "Ada Lovelace",
+	})
+	assert.ErrorIs(t, err, recipients.ErrTooManyRecipients)
+}
+
+func TestResolveForWithdrawal_HonoursHoldAndFlags(t *testing.T) {
+	repo := newFakeRepo()
+	service := recipients.NewService(repo, recipients.Config{FirstUseHold: time.Hour}, zap.NewNop())
+	userID := uuid.New()
+	ctx := context.Background()
+
+	recipient, err := service.Create(ctx, userID, &entities.CreateRecipientRequest{
+		Kind: entities.RecipientKindCrypto, Label: "Wallet", Chain: "SOL-DEVNET", Address: solanaAddress,
+	})
+	require.NoError(t, err)
+
+	_, err = service.ResolveForWithdrawal(ctx, userID, recipient.ID)
+	assert.ErrorIs(t, err, recipients.ErrRecipientOnHold)
+
+	recipient.HoldUntil = time.Now().Add(-time.Minute)
+	resolved, err := service.ResolveForWithdrawal(ctx, userID, recipient.ID)
+	require.NoError(t, err)
+	assert.Equal(t, solanaAddress, resolved.Address)
+
+	_, err = service.ResolveForWithdrawal(ctx, uuid.New(), recipient.ID)
+	assert.ErrorIs(t, err, entities.ErrRecipientNotFound, "recipients are private to their owner")
+
+	_, err = service.Flag(ctx, uuid.New(), recipient.ID, "shared with 4 accounts")
+	require.NoError(t, err)
+	_, err = service.ResolveForWithdrawal(ctx, userID, recipient.ID)
+	assert.ErrorIs(t, err, recipients.ErrRecipientFlagged)
+
+	_, err = service.Unflag(ctx, uuid.New(), recipient.ID)
+	require.NoError(t, err)
+	_, err = service.ResolveForWithdrawal(ctx, userID, recipient.ID)
+	assert.NoError(t, err)
+}
+
+func TestDestinationKey_MatchesAcrossAccountsAndEVMCase(t *testing.T) {
+	a := &entities.WithdrawalRecipient{Kind: entities.RecipientKindCrypto, Chain: "ETH", Address: "0xAbCdEf0123456789aBcDeF0123456789AbCdEf01"}
+	b := &entities.WithdrawalRecipient{Kind: entities.RecipientKindCrypto, Chain: "BASE", Address: "0xabcdef0123456789abcdef0123456789abcdef01"}
+	assert.Equal(t, a.DestinationKey(), b.DestinationKey(), "EVM addresses are the same destination on any EVM chain")
+
+	c := &entities.WithdrawalRecipient{Kind: entities.RecipientKindBank, RoutingNumber: "021000021", AccountNumber: "123"}
+	d := &entities.WithdrawalRecipient{Kind: entities.RecipientKindBank, RoutingNumber: "011000015", AccountNumber: "123"}
+	assert.NotEqual(t, c.DestinationKey(), d.DestinationKey())
+}
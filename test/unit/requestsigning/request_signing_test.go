@@ -0,0 +1,148 @@
+package requestsigning_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/api/middleware"
+	"github.com/stack-service/stack_service/pkg/auth"
+)
+
+const (
+	keyID  = "sig_test"
+	secret = "sks_test-secret"
+)
+
+type memoryResolver struct {
+	mu     sync.Mutex
+	keys   map[string]*middleware.SigningKeyInfo
+	nonces map[string]bool
+}
+
+func newResolver(environment string) *memoryResolver {
+	return &memoryResolver{
+		keys: map[string]*middleware.SigningKeyInfo{
+			keyID: {ID: uuid.New(), KeyID: keyID, Tenant: "ledger", Environment: environment, Scopes: []string{"ledger:read"}, Secret: secret},
+		},
+		nonces: map[string]bool{},
+	}
+}
+
+func (r *memoryResolver) ResolveSigningKey(ctx context.Context, id string) (*middleware.SigningKeyInfo, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, errors.New("signing key not found")
+	}
+	return key, nil
+}
+
+func (r *memoryResolver) UseNonce(ctx context.Context, id, nonce string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.nonces[id+":"+nonce] {
+		return false, nil
+	}
+	r.nonces[id+":"+nonce] = true
+	return true, nil
+}
+
+func newRouter(resolver middleware.SigningKeyResolver) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequireSignedRequest(resolver, "staging", 5*time.Minute))
+	router.POST("/internal/ledger", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant": c.GetString("service_tenant")})
+	})
+	return router
+}
+
+func signedRequest(t *testing.T, body string, signedAt time.Time) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/internal/ledger?dry_run=true", strings.NewReader(body))
+	require.NoError(t, auth.SignRequest(req, keyID, secret, signedAt))
+	return req
+}
+
+func serve(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRequireSignedRequest_AcceptsValidSignature(t *testing.T) {
+	router := newRouter(newResolver("staging"))
+
+	recorder := serve(router, signedRequest(t, `{"amount":"10.00"}`, time.Now()))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"tenant":"ledger"`)
+}
+
+func TestRequireSignedRequest_RejectsTamperedRequest(t *testing.T) {
+	router := newRouter(newResolver("staging"))
+
+	req := signedRequest(t, `{"amount":"10.00"}`, time.Now())
+	tampered := httptest.NewRequest(http.MethodPost, "/internal/ledger?dry_run=true", strings.NewReader(`{"amount":"99.00"}`))
+	tampered.Header = req.Header
+	assert.Equal(t, http.StatusUnauthorized, serve(router, tampered).Code)
+
+	req = signedRequest(t, `{}`, time.Now())
+	req.URL.RawQuery = "dry_run=false"
+	assert.Equal(t, http.StatusUnauthorized, serve(router, req).Code)
+}
+
+func TestRequireSignedRequest_RejectsReplay(t *testing.T) {
+	router := newRouter(newResolver("staging"))
+	req := signedRequest(t, `{}`, time.Now())
+	replay := httptest.NewRequest(http.MethodPost, "/internal/ledger?dry_run=true", strings.NewReader(`{}`))
+	replay.Header = req.Header.Clone()
+
+	assert.Equal(t, http.StatusOK, serve(router, req).Code)
+	recorder := serve(router, replay)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "already processed")
+}
+
+func TestRequireSignedRequest_RejectsStaleTimestamp(t *testing.T) {
+	router := newRouter(newResolver("staging"))
+
+	recorder := serve(router, signedRequest(t, `{}`, time.Now().Add(-10*time.Minute)))
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "expired")
+}
+
+func TestRequireSignedRequest_RejectsKeyFromAnotherEnvironment(t *testing.T) {
+	router := newRouter(newResolver("production"))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(router, signedRequest(t, `{}`, time.Now())).Code)
+}
+
+func TestRequireSignedRequest_RequiresSignatureHeaders(t *testing.T) {
+	router := newRouter(newResolver("staging"))
+	req := signedRequest(t, `{}`, time.Now())
+	req.Header.Del(auth.SignatureHeader)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(router, req).Code)
+}
+
+func TestCanonicalRequest_CoversEverySignedPart(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix()
+	canonical := auth.CanonicalRequest("post", "/internal/ledger", "a=1", ts, "n1", []byte("{}"))
+
+	assert.Equal(t, "v1\n"+strconv.FormatInt(ts, 10)+"\nn1\nPOST\n/internal/ledger?a=1\n"+
+		"44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", canonical)
+	assert.True(t, auth.VerifySignature(secret, canonical, auth.ComputeSignature(secret, canonical)))
+	assert.False(t, auth.VerifySignature("other", canonical, auth.ComputeSignature(secret, canonical)))
+}
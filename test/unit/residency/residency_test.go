@@ -0,0 +1,107 @@
+package residency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/residency"
+)
+
+type memoryRepo struct {
+	tags  map[uuid.UUID]*entities.UserResidency
+	limit int
+}
+
+func (r *memoryRepo) Get(ctx context.Context, userID uuid.UUID) (*entities.UserResidency, error) {
+	return r.tags[userID], nil
+}
+
+func (r *memoryRepo) Upsert(ctx context.Context, residency *entities.UserResidency) error {
+	r.tags[residency.UserID] = residency
+	return nil
+}
+
+func (r *memoryRepo) ListOutOfRegion(ctx context.Context, home entities.ResidencyRegion, limit, offset int) ([]*entities.ResidencyViolation, error) {
+	r.limit = limit
+	return []*entities.ResidencyViolation{}, nil
+}
+
+type countries map[uuid.UUID]string
+
+func (c countries) UserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	country, ok := c[userID]
+	if !ok {
+		return "", errors.New("user not found")
+	}
+	return country, nil
+}
+
+func TestRegionTagsUsersFromCountry(t *testing.T) {
+	repo := &memoryRepo{tags: map[uuid.UUID]*entities.UserResidency{}}
+	german, american, unknown := uuid.New(), uuid.New(), uuid.New()
+	users := countries{german: "de", american: "US", unknown: ""}
+	service := residency.NewService(repo, users, entities.ResidencyRegionUS, zap.NewNop())
+	ctx := context.Background()
+
+	region, err := service.Region(ctx, german)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencyRegionEU, region)
+	require.Contains(t, repo.tags, german)
+	assert.Equal(t, "DE", repo.tags[german].CountryCode)
+
+	// The tag is kept when the country is edited later
+	users[german] = "US"
+	region, err = service.Region(ctx, german)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencyRegionEU, region)
+
+	region, err = service.Region(ctx, american)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencyRegionUS, region)
+
+	// Users without a country use the home region until one is recorded
+	region, err = service.Region(ctx, unknown)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencyRegionUS, region)
+	assert.NotContains(t, repo.tags, unknown)
+
+	_, err = service.Region(ctx, uuid.New())
+	assert.Error(t, err)
+}
+
+func TestSetRegionPinsUser(t *testing.T) {
+	repo := &memoryRepo{tags: map[uuid.UUID]*entities.UserResidency{}}
+	userID, adminID := uuid.New(), uuid.New()
+	service := residency.NewService(repo, countries{userID: "CH"}, entities.ResidencyRegionUS, zap.NewNop())
+	ctx := context.Background()
+
+	before, err := service.Residency(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, before.Tagged)
+	assert.Equal(t, entities.ResidencyRegionUS, before.Region)
+
+	_, err = service.SetRegion(ctx, userID, &entities.SetResidencyRequest{Region: "apac", Reason: "contract"}, adminID)
+	assert.ErrorIs(t, err, entities.ErrInvalidResidency)
+	_, err = service.SetRegion(ctx, userID, &entities.SetResidencyRequest{Region: entities.ResidencyRegionEU, Reason: " "}, adminID)
+	assert.ErrorIs(t, err, entities.ErrInvalidResidency)
+
+	pinned, err := service.SetRegion(ctx, userID, &entities.SetResidencyRequest{Region: entities.ResidencyRegionEU, Reason: "Swiss client agreement"}, adminID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencySourceAdmin, pinned.Source)
+	assert.Equal(t, "CH", pinned.CountryCode)
+	assert.Equal(t, &adminID, pinned.SetBy)
+	region, err := service.Region(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ResidencyRegionEU, region)
+
+	_, err = service.OutOfRegion(ctx, 10000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 500, repo.limit)
+}
@@ -0,0 +1,45 @@
+package restoredrill_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/internal/domain/services/restoredrill"
+)
+
+func TestDirectoryBackupSource_PicksNewestMatchingArchive(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	files := map[string]time.Time{
+		"stack-20300101.dump": now.Add(-48 * time.Hour),
+		"stack-20300102.dump": now.Add(-time.Hour),
+		"notes.txt":           now,
+	}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o600))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	backup, err := restoredrill.DirectoryBackupSource{Dir: dir}.Latest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "stack-20300102.dump"), backup.Path)
+	assert.Equal(t, int64(len("stack-20300102.dump")), backup.SizeBytes)
+}
+
+func TestDirectoryBackupSource_NoBackups(t *testing.T) {
+	_, err := restoredrill.DirectoryBackupSource{Dir: t.TempDir()}.Latest(context.Background())
+	assert.Error(t, err)
+}
+
+func TestPgRestoreRestorer_RequiresScratchDatabase(t *testing.T) {
+	err := restoredrill.PgRestoreRestorer{}.Restore(context.Background(), &restoredrill.Backup{Path: "x.dump"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+package retention_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stack-service/stack_service/internal/domain/services/retention"
+)
+
+func TestDefaultPolicies_AreWellFormed(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, policy := range retention.DefaultPolicies() {
+		assert.False(t, seen[policy.Name], "duplicate policy %s", policy.Name)
+		seen[policy.Name] = true
+
+		assert.NotEmpty(t, policy.Table, policy.Name)
+		assert.NotEmpty(t, policy.TimestampColumn, policy.Name)
+		assert.True(t, policy.Retention > 0 || policy.RetentionYears > 0, "policy %s has no retention period", policy.Name)
+
+		if policy.Action == retention.ActionAnonymize {
+			assert.NotEmpty(t, policy.AnonymizeSet, policy.Name)
+			assert.NotEmpty(t, policy.Condition, "anonymize policy %s must exclude already anonymized rows", policy.Name)
+		}
+	}
+}
+
+func TestAuditPolicy_KeepsSevenYears(t *testing.T) {
+	var audit *retention.Policy
+	for _, policy := range retention.DefaultPolicies() {
+		if policy.Table == "audit_logs" {
+			p := policy
+			audit = &p
+		}
+	}
+	if assert.NotNil(t, audit) {
+		assert.Equal(t, retention.ActionAuditPrune, audit.Action)
+
+		now := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+		cutoff := audit.Cutoff(now)
+		assert.True(t, cutoff.Before(now.AddDate(-7, 0, 0)), "audit cutoff must never fall inside seven years")
+	}
+}
+
+func TestPolicyCutoff_Duration(t *testing.T) {
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	policy := retention.Policy{Retention: 24 * time.Hour}
+	assert.Equal(t, now.Add(-24*time.Hour), policy.Cutoff(now))
+}
@@ -0,0 +1,80 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stack-service/stack_service/pkg/retry"
+)
+
+var start = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func newBudget(config retry.BudgetConfig, random float64) (*retry.Budget, *time.Time) {
+	now := start
+	budget := retry.NewBudget(config)
+	budget.SetClock(func() time.Time { return now })
+	budget.SetRandom(func() float64 { return random })
+	return budget, &now
+}
+
+func record(budget *retry.Budget, provider string, successes, failures int) {
+	for i := 0; i < successes; i++ {
+		budget.Record(provider, true)
+	}
+	for i := 0; i < failures; i++ {
+		budget.Record(provider, false)
+	}
+}
+
+func TestAllowRetryThrottlesUnhealthyProvider(t *testing.T) {
+	config := retry.DefaultBudgetConfig()
+	config.MinRetries = 1000 // leave the cap out of it
+	budget, _ := newBudget(config, 0.5)
+
+	record(budget, "due", 0, 10)
+	assert.True(t, budget.AllowRetry("due"), "too few attempts to judge the success rate")
+
+	record(budget, "due", 0, 30)
+	assert.False(t, budget.AllowRetry("due"), "nothing is succeeding")
+	assert.True(t, budget.AllowRetry("circle"), "providers are tracked apart")
+
+	healthy, _ := newBudget(config, 0.5)
+	record(healthy, "due", 30, 10)
+	assert.True(t, healthy.AllowRetry("due"), "most attempts succeed")
+}
+
+func TestAllowRetryCapsRetriesAtShareOfTraffic(t *testing.T) {
+	budget, _ := newBudget(retry.BudgetConfig{RetryRatio: 0.1, MinRetries: 2}, 1)
+
+	// 100 first attempts allow 0.1*100 + 2 retries
+	record(budget, "circle", 100, 0)
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		if budget.AllowRetry("circle") {
+			allowed++
+			budget.Record("circle", true)
+		}
+	}
+	assert.Equal(t, 12, allowed)
+}
+
+func TestBudgetForgetsAttemptsOutsideWindow(t *testing.T) {
+	budget, now := newBudget(retry.BudgetConfig{Window: time.Minute, Buckets: 6, MinRetries: 1000}, 0.5)
+
+	record(budget, "due", 0, 40)
+	assert.False(t, budget.AllowRetry("due"))
+
+	*now = now.Add(30 * time.Second)
+	assert.False(t, budget.AllowRetry("due"), "failures are still in the window")
+
+	*now = now.Add(31 * time.Second)
+	assert.True(t, budget.AllowRetry("due"))
+}
+
+func TestNilBudgetAllowsEveryRetry(t *testing.T) {
+	var budget *retry.Budget
+	budget.Record("due", false)
+	assert.True(t, budget.AllowRetry("due"))
+}
@@ -0,0 +1,588 @@
+package rewards_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/rewards"
+)
+
+type fakeRepo struct {
+	rules       map[uuid.UUID]*entities.RewardRule
+	rewards     map[uuid.UUID]*entities.Reward
+	redemptions map[uuid.UUID]*entities.RewardRedemption
+	sweepOrders map[uuid.UUID]bool
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		rules:       map[uuid.UUID]*entities.RewardRule{},
+		rewards:     map[uuid.UUID]*entities.Reward{},
+		redemptions: map[uuid.UUID]*entities.RewardRedemption{},
+		sweepOrders: map[uuid.UUID]bool{},
+	}
+}
+
+func (f *fakeRepo) CreateRule(ctx context.Context, rule *entities.RewardRule) error {
+	copied := *rule
+	f.rules[rule.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ListRules(ctx context.Context) ([]*entities.RewardRule, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) ListRunning(ctx context.Context, trigger entities.RewardTrigger, at time.Time) ([]*entities.RewardRule, error) {
+	var running []*entities.RewardRule
+	for _, rule := range f.rules {
+		if rule.Trigger == trigger && rule.IsRunning(at) {
+			copied := *rule
+			running = append(running, &copied)
+		}
+	}
+	return running, nil
+}
+
+func (f *fakeRepo) SetRuleActive(ctx context.Context, id uuid.UUID, active bool) (*entities.RewardRule, error) {
+	rule, ok := f.rules[id]
+	if !ok {
+		return nil, entities.ErrRewardRuleNotFound
+	}
+	rule.Active = active
+	copied := *rule
+	return &copied, nil
+}
+
+func (f *fakeRepo) EarnedSince(ctx context.Context, userID uuid.UUID, since time.Time) (decimal.Decimal, error) {
+	earned := decimal.Zero
+	for _, reward := range f.rewards {
+		if reward.UserID == userID && !reward.CreatedAt.Before(since) && reward.Status != entities.RewardForfeited {
+			earned = earned.Add(reward.Amount)
+		}
+	}
+	return earned, nil
+}
+
+func (f *fakeRepo) ClaimReward(ctx context.Context, reward *entities.Reward, since time.Time, limit decimal.Decimal) error {
+	for _, existing := range f.rewards {
+		if existing.RuleID == reward.RuleID && existing.OrderID == reward.OrderID {
+			return entities.ErrRewardAlreadyEarned
+		}
+	}
+	earned, _ := f.EarnedSince(ctx, reward.UserID, since)
+	if earned.Add(reward.Amount).GreaterThan(limit) {
+		return entities.ErrRewardCapReached
+	}
+	copied := *reward
+	f.rewards[reward.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ReleaseReward(ctx context.Context, id uuid.UUID) error {
+	delete(f.rewards, id)
+	return nil
+}
+
+func (f *fakeRepo) SetRewardLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	f.rewards[id].LedgerTxID = &ledgerTxID
+	return nil
+}
+
+func (f *fakeRepo) GetReward(ctx context.Context, id uuid.UUID) (*entities.Reward, error) {
+	reward, ok := f.rewards[id]
+	if !ok {
+		return nil, entities.ErrRewardNotFound
+	}
+	copied := *reward
+	return &copied, nil
+}
+
+func (f *fakeRepo) UpdateRewardStatus(ctx context.Context, reward *entities.Reward, from entities.RewardStatus) (bool, error) {
+	stored := f.rewards[reward.ID]
+	if stored.Status != from {
+		return false, nil
+	}
+	stored.Status = reward.Status
+	stored.AvailableAt = reward.AvailableAt
+	stored.ForfeitReason = reward.ForfeitReason
+	stored.ReviewedBy = reward.ReviewedBy
+	stored.SettledAt = reward.SettledAt
+	return true, nil
+}
+
+func (f *fakeRepo) SetClawbackLedgerTx(ctx context.Context, id, ledgerTxID uuid.UUID) error {
+	f.rewards[id].ClawbackLedgerTxID = &ledgerTxID
+	return nil
+}
+
+func (f *fakeRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.Reward, error) {
+	return f.filter(func(r *entities.Reward) bool { return r.UserID == userID }), nil
+}
+
+func (f *fakeRepo) ListHeld(ctx context.Context, limit, offset int) ([]*entities.Reward, error) {
+	return f.filter(func(r *entities.Reward) bool { return r.Status == entities.RewardHeld }), nil
+}
+
+func (f *fakeRepo) ListUnsettledForBasket(ctx context.Context, userID, basketID uuid.UUID, now time.Time) ([]*entities.Reward, error) {
+	return f.filter(func(r *entities.Reward) bool {
+		return r.UserID == userID && r.BasketID == basketID &&
+			(r.Status == entities.RewardHeld || (r.Status == entities.RewardAccrued && r.AvailableAt.After(now)))
+	}), nil
+}
+
+func (f *fakeRepo) Balance(ctx context.Context, userID uuid.UUID, now time.Time) (*entities.RewardBalance, error) {
+	balance := &entities.RewardBalance{}
+	matured := decimal.Zero
+	for _, reward := range f.rewards {
+		if reward.UserID != userID || reward.LedgerTxID == nil {
+			continue
+		}
+		switch {
+		case reward.IsAvailable(now):
+			matured = matured.Add(reward.Amount)
+		case reward.Status == entities.RewardAccrued:
+			balance.Pending = balance.Pending.Add(reward.Amount)
+		case reward.Status == entities.RewardHeld:
+			balance.UnderReview = balance.UnderReview.Add(reward.Amount)
+		}
+	}
+	for _, redemption := range f.redemptions {
+		if redemption.UserID == userID && redemption.Status != entities.RewardRedemptionFailed {
+			balance.Redeemed = balance.Redeemed.Add(redemption.Amount)
+		}
+	}
+	balance.Available = matured.Sub(balance.Redeemed)
+	return balance, nil
+}
+
+func (f *fakeRepo) CreateRedemption(ctx context.Context, redemption *entities.RewardRedemption) error {
+	balance, _ := f.Balance(ctx, redemption.UserID, redemption.CreatedAt)
+	if balance.Available.LessThan(redemption.Amount) {
+		return entities.ErrInsufficientRewards
+	}
+	copied := *redemption
+	f.redemptions[redemption.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) UpdateRedemption(ctx context.Context, redemption *entities.RewardRedemption) error {
+	copied := *redemption
+	f.redemptions[redemption.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ListRedemptions(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.RewardRedemption, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) IsSweepOrder(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	return f.sweepOrders[orderID], nil
+}
+
+func (f *fakeRepo) IsRedemptionOrder(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	for _, redemption := range f.redemptions {
+		if redemption.OrderID != nil && *redemption.OrderID == orderID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRepo) filter(keep func(*entities.Reward) bool) []*entities.Reward {
+	var rewards []*entities.Reward
+	for _, reward := range f.rewards {
+		if keep(reward) {
+			copied := *reward
+			rewards = append(rewards, &copied)
+		}
+	}
+	return rewards
+}
+
+// fakeLedger keeps account balances and, like the ledger, refuses to take an
+// account negative
+type fakeLedger struct {
+	accounts map[string]*entities.LedgerAccount
+	posted   map[uuid.UUID]*entities.CreateTransactionRequest
+	reversed []uuid.UUID
+}
+
+func newFakeLedger(budget decimal.Decimal) *fakeLedger {
+	l := &fakeLedger{
+		accounts: map[string]*entities.LedgerAccount{},
+		posted:   map[uuid.UUID]*entities.CreateTransactionRequest{},
+	}
+	l.account(uuid.Nil, entities.AccountTypeSystemRewards).Balance = budget
+	return l
+}
+
+func (l *fakeLedger) account(userID uuid.UUID, accountType entities.AccountType) *entities.LedgerAccount {
+	key := userID.String() + "/" + string(accountType)
+	if _, ok := l.accounts[key]; !ok {
+		l.accounts[key] = &entities.LedgerAccount{ID: uuid.New(), AccountType: accountType}
+	}
+	return l.accounts[key]
+}
+
+func (l *fakeLedger) balance(userID uuid.UUID, accountType entities.AccountType) string {
+	return l.account(userID, accountType).Balance.String()
+}
+
+func (l *fakeLedger) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return l.account(userID, accountType), nil
+}
+
+func (l *fakeLedger) GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return l.account(uuid.Nil, accountType), nil
+}
+
+func (l *fakeLedger) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	if err := l.apply(req.Entries, false); err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	l.posted[id] = req
+	return &entities.LedgerTransaction{ID: id}, nil
+}
+
+func (l *fakeLedger) ReverseTransaction(ctx context.Context, originalTxID uuid.UUID, reason string) error {
+	l.reversed = append(l.reversed, originalTxID)
+	return l.apply(l.posted[originalTxID].Entries, true)
+}
+
+func (l *fakeLedger) apply(entries []entities.CreateEntryRequest, reverse bool) error {
+	byID := map[uuid.UUID]*entities.LedgerAccount{}
+	for _, account := range l.accounts {
+		byID[account.ID] = account
+	}
+	credit := func(entry entities.CreateEntryRequest) bool {
+		return (entry.EntryType == entities.EntryTypeCredit) != reverse
+	}
+	for _, entry := range entries {
+		if credit(entry) && byID[entry.AccountID].Balance.LessThan(entry.Amount) {
+			return errors.New("insufficient balance")
+		}
+	}
+	for _, entry := range entries {
+		account := byID[entry.AccountID]
+		if credit(entry) {
+			account.Balance = account.Balance.Sub(entry.Amount)
+		} else {
+			account.Balance = account.Balance.Add(entry.Amount)
+		}
+	}
+	return nil
+}
+
+type fakeBuyingPower map[uuid.UUID]decimal.Decimal
+
+func (f fakeBuyingPower) UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f[userID] = f[userID].Add(amount)
+	return nil
+}
+
+type fakeInvestor struct {
+	basket *entities.Basket
+	orders []*entities.OrderCreateRequest
+	err    error
+}
+
+func (f *fakeInvestor) GetBasket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error) {
+	if f.basket != nil && f.basket.ID == basketID {
+		return f.basket, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeInvestor) CreateOrder(ctx context.Context, userID uuid.UUID, req *entities.OrderCreateRequest) (*entities.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.orders = append(f.orders, req)
+	return &entities.Order{ID: uuid.New(), UserID: userID, BasketID: req.BasketID, Side: req.Side}, nil
+}
+
+type fakeRisk struct {
+	score decimal.Decimal
+}
+
+func (f *fakeRisk) CalculateFraudScore(ctx context.Context, userID uuid.UUID, amount decimal.Decimal, txType string) (decimal.Decimal, map[string]interface{}) {
+	return f.score, map[string]interface{}{"masked_session": f.score.IsPositive(), "tx_type": txType}
+}
+
+type fixture struct {
+	svc         *rewards.Service
+	repo        *fakeRepo
+	ledger      *fakeLedger
+	buyingPower fakeBuyingPower
+	investor    *fakeInvestor
+	risk        *fakeRisk
+	userID      uuid.UUID
+	basket      *entities.Basket
+	now         time.Time
+}
+
+func newFixture(budget string) *fixture {
+	f := &fixture{
+		repo:        newFakeRepo(),
+		ledger:      newFakeLedger(decimal.RequireFromString(budget)),
+		buyingPower: fakeBuyingPower{},
+		risk:        &fakeRisk{score: decimal.Zero},
+		userID:      uuid.New(),
+		basket:      &entities.Basket{ID: uuid.New(), Name: "Tech Growth"},
+		now:         time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.investor = &fakeInvestor{basket: f.basket}
+	f.svc = rewards.NewService(f.repo, f.ledger, f.buyingPower, f.investor, rewards.Config{
+		MonthlyCap: decimal.NewFromInt(20),
+	}, zap.NewNop())
+	f.svc.SetRiskScorer(f.risk)
+	f.svc.SetClock(func() time.Time { return f.now })
+	return f
+}
+
+func (f *fixture) createRule(t *testing.T, trigger entities.RewardTrigger, unit entities.RewardUnit, rate string, holdDays int) *entities.RewardRule {
+	rule, err := f.svc.CreateRule(context.Background(), &entities.CreateRewardRuleRequest{
+		Name:           "Back on " + string(trigger),
+		Trigger:        trigger,
+		Unit:           unit,
+		Rate:           decimal.RequireFromString(rate),
+		MinOrderAmount: decimal.NewFromInt(10),
+		HoldDays:       holdDays,
+	}, uuid.New())
+	require.NoError(t, err)
+	return rule
+}
+
+func (f *fixture) fill(t *testing.T, side entities.OrderSide, amount string, sweep bool) *entities.Order {
+	order := &entities.Order{
+		ID:       uuid.New(),
+		UserID:   f.userID,
+		BasketID: f.basket.ID,
+		Side:     side,
+		Amount:   decimal.RequireFromString(amount),
+		Status:   entities.OrderStatusFilled,
+	}
+	f.repo.sweepOrders[order.ID] = sweep
+	require.NoError(t, f.svc.HandleOrderFilled(context.Background(), order))
+	return order
+}
+
+func TestCreateRule_ValidatesRate(t *testing.T) {
+	f := newFixture("100")
+
+	_, err := f.svc.CreateRule(context.Background(), &entities.CreateRewardRuleRequest{
+		Name: "Too generous", Trigger: entities.RewardTriggerInvestment, Unit: entities.RewardUnitCash,
+		Rate: decimal.NewFromInt(2),
+	}, uuid.New())
+	assert.ErrorIs(t, err, rewards.ErrInvalidRewardRule)
+}
+
+func TestHandleOrderFilled_EarnsRecurringRewardsOnSweepOrders(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerRecurringInvestment, entities.RewardUnitPoints, "0.01", 0)
+
+	f.fill(t, entities.OrderSideBuy, "250", false)
+	assert.Empty(t, f.repo.rewards, "manual orders do not earn recurring rewards")
+
+	order := f.fill(t, entities.OrderSideBuy, "250", true)
+	require.Len(t, f.repo.rewards, 1)
+	var reward *entities.Reward
+	for _, r := range f.repo.rewards {
+		reward = r
+	}
+	assert.Equal(t, order.ID, reward.OrderID)
+	assert.Equal(t, "2.5", reward.Amount.String())
+	require.NotNil(t, reward.Points)
+	assert.Equal(t, int64(250), *reward.Points)
+	assert.NotNil(t, reward.LedgerTxID)
+	assert.Equal(t, "2.5", f.ledger.balance(f.userID, entities.AccountTypeRewardsBalance))
+	assert.Equal(t, "97.5", f.ledger.balance(uuid.Nil, entities.AccountTypeSystemRewards))
+
+	// A redelivered fill earns nothing more
+	require.NoError(t, f.svc.HandleOrderFilled(context.Background(), order))
+	assert.Len(t, f.repo.rewards, 1)
+
+	// Orders under the rule's minimum earn nothing
+	f.fill(t, entities.OrderSideBuy, "5", true)
+	assert.Len(t, f.repo.rewards, 1)
+}
+
+func TestHandleOrderFilled_CapsMonthlyEarnings(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerInvestment, entities.RewardUnitCash, "0.05", 0)
+
+	f.fill(t, entities.OrderSideBuy, "300", false)
+	f.fill(t, entities.OrderSideBuy, "300", false)
+	f.fill(t, entities.OrderSideBuy, "300", false)
+
+	summary, err := f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "20", summary.Available.String(), "15 then 5 of the 20 cap, then nothing")
+	assert.Len(t, summary.Rewards, 2)
+
+	f.now = f.now.AddDate(0, 1, 0)
+	f.fill(t, entities.OrderSideBuy, "300", false)
+	assert.Len(t, f.repo.rewards, 3, "the cap resets with the month")
+}
+
+func TestHandleOrderFilled_SellDuringHoldForfeitsBasketRewards(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerInvestment, entities.RewardUnitCash, "0.01", 30)
+
+	f.fill(t, entities.OrderSideBuy, "500", false)
+	summary, err := f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "5", summary.Pending.String())
+	assert.True(t, summary.Available.IsZero())
+
+	f.fill(t, entities.OrderSideSell, "500", false)
+	for _, reward := range f.repo.rewards {
+		assert.Equal(t, entities.RewardForfeited, reward.Status)
+		assert.NotNil(t, reward.ClawbackLedgerTxID)
+	}
+	assert.Equal(t, "0", f.ledger.balance(f.userID, entities.AccountTypeRewardsBalance))
+	assert.Equal(t, "100", f.ledger.balance(uuid.Nil, entities.AccountTypeSystemRewards))
+}
+
+func TestHandleOrderFilled_HoldsRiskyRewardsForReview(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerInvestment, entities.RewardUnitCash, "0.01", 0)
+	f.risk.score = decimal.NewFromFloat(0.5)
+
+	f.fill(t, entities.OrderSideBuy, "500", false)
+	held, err := f.svc.ListHeld(context.Background(), 0, 0)
+	require.NoError(t, err)
+	require.Len(t, held, 1)
+	require.NotNil(t, held[0].HoldReason)
+	assert.Contains(t, *held[0].HoldReason, "masked_session")
+
+	summary, err := f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "5", summary.UnderReview.String())
+	assert.True(t, summary.Available.IsZero())
+
+	reward, err := f.svc.Review(context.Background(), held[0].ID, &entities.ReviewRewardRequest{Approve: true, Reason: "Known device"}, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, entities.RewardAccrued, reward.Status)
+
+	_, err = f.svc.Review(context.Background(), held[0].ID, &entities.ReviewRewardRequest{Approve: false, Reason: "again"}, uuid.New())
+	assert.ErrorIs(t, err, entities.ErrRewardNotHeld)
+
+	summary, err = f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "5", summary.Available.String())
+}
+
+func TestRedeem_InvestsAvailableRewards(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerInvestment, entities.RewardUnitCash, "0.02", 0)
+	f.fill(t, entities.OrderSideBuy, "500", false)
+
+	_, err := f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: f.basket.ID, Amount: decimal.NewFromInt(20)})
+	assert.ErrorIs(t, err, entities.ErrInsufficientRewards)
+
+	redemption, err := f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: f.basket.ID, Amount: decimal.NewFromInt(6)})
+	require.NoError(t, err)
+	assert.Equal(t, entities.RewardRedemptionCompleted, redemption.Status)
+	require.NotNil(t, redemption.OrderID)
+	require.Len(t, f.investor.orders, 1)
+	assert.Equal(t, "6.00", f.investor.orders[0].Amount)
+	assert.Equal(t, entities.OrderSideBuy, f.investor.orders[0].Side)
+	assert.Equal(t, "4", f.ledger.balance(f.userID, entities.AccountTypeRewardsBalance))
+	assert.Equal(t, "6", f.ledger.balance(f.userID, entities.AccountTypeUSDCBalance))
+	assert.Equal(t, "6", f.buyingPower[f.userID].String())
+
+	// The order bought with rewards earns none
+	require.NoError(t, f.svc.HandleOrderFilled(context.Background(), &entities.Order{
+		ID: *redemption.OrderID, UserID: f.userID, BasketID: f.basket.ID,
+		Side: entities.OrderSideBuy, Amount: decimal.NewFromInt(6),
+	}))
+	assert.Len(t, f.repo.rewards, 1)
+
+	summary, err := f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "4", summary.Available.String())
+	assert.Equal(t, "6", summary.Redeemed.String())
+}
+
+func TestRedeem_ReversesWhenOrderFails(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerInvestment, entities.RewardUnitCash, "0.02", 0)
+	f.fill(t, entities.OrderSideBuy, "500", false)
+	f.investor.err = errors.New("brokerage unavailable")
+
+	_, err := f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: f.basket.ID, Amount: decimal.NewFromInt(10)})
+	require.Error(t, err)
+	assert.Len(t, f.ledger.reversed, 1)
+	assert.Equal(t, "10", f.ledger.balance(f.userID, entities.AccountTypeRewardsBalance))
+	assert.Equal(t, "0", f.ledger.balance(f.userID, entities.AccountTypeUSDCBalance))
+	assert.True(t, f.buyingPower[f.userID].IsZero())
+	for _, redemption := range f.repo.redemptions {
+		assert.Equal(t, entities.RewardRedemptionFailed, redemption.Status)
+	}
+
+	summary, err := f.svc.Summary(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, "10", summary.Available.String(), "a failed redemption gives the rewards back")
+
+	_, err = f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: uuid.New(), Amount: decimal.NewFromInt(5)})
+	assert.ErrorIs(t, err, rewards.ErrInvalidRedemption)
+}
+
+type fakeModes entities.TradingMode
+
+func (f fakeModes) Mode(ctx context.Context, userID uuid.UUID) (entities.TradingMode, error) {
+	return entities.TradingMode(f), nil
+}
+
+type fakePaperFunds map[uuid.UUID]decimal.Decimal
+
+func (f fakePaperFunds) AddBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f[userID] = f[userID].Add(amount)
+	return nil
+}
+
+func (f fakePaperFunds) DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f[userID] = f[userID].Sub(amount)
+	return nil
+}
+
+func TestRedeem_SimulatesPaperModeRedemptions(t *testing.T) {
+	f := newFixture("100")
+	f.createRule(t, entities.RewardTriggerInvestment, entities.RewardUnitCash, "0.02", 0)
+	f.fill(t, entities.OrderSideBuy, "500", false)
+	paper, paperFunds := &fakeInvestor{basket: f.basket}, fakePaperFunds{}
+	f.svc.SetPaperTrading(fakeModes(entities.TradingModePaper), paper, paperFunds)
+
+	_, err := f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: f.basket.ID, Amount: decimal.NewFromInt(20)})
+	assert.ErrorIs(t, err, entities.ErrInsufficientRewards)
+
+	redemption, err := f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: f.basket.ID, Amount: decimal.NewFromInt(6)})
+	require.NoError(t, err)
+	assert.Equal(t, entities.RewardRedemptionCompleted, redemption.Status)
+	require.Len(t, paper.orders, 1)
+	assert.Equal(t, "6.00", paper.orders[0].Amount)
+	assert.Equal(t, "6", paperFunds[f.userID].String())
+
+	// The live account is untouched
+	assert.Empty(t, f.investor.orders)
+	assert.Empty(t, f.repo.redemptions)
+	assert.True(t, f.buyingPower[f.userID].IsZero())
+	assert.Equal(t, "10", f.ledger.balance(f.userID, entities.AccountTypeRewardsBalance))
+
+	paper.err = errors.New("market closed")
+	_, err = f.svc.Redeem(context.Background(), f.userID, &entities.RedeemRewardsRequest{BasketID: f.basket.ID, Amount: decimal.NewFromInt(4)})
+	require.Error(t, err)
+	assert.Equal(t, "6", paperFunds[f.userID].String(), "a failed paper order takes its funding back")
+}
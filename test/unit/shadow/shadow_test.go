@@ -0,0 +1,155 @@
+package shadow_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/api/middleware"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/shadow"
+)
+
+func TestCompare(t *testing.T) {
+	ignore := map[string]bool{"request_id": true}
+
+	assert.Empty(t, shadow.Compare(200, []byte(`{"a":1.50,"b":[1,2],"request_id":"x"}`),
+		200, []byte(`{"b":[1,2], "a":1.5, "request_id":"y"}`), ignore))
+	assert.Equal(t, []string{"status"}, shadow.Compare(200, []byte(`{}`), 201, []byte(`{}`), ignore))
+	assert.Equal(t, []string{"body.data[1].amount", "body.extra"},
+		shadow.Compare(200, []byte(`{"data":[{"amount":"1"},{"amount":"2"}]}`),
+			200, []byte(`{"data":[{"amount":"1"},{"amount":"3"}],"extra":true}`), ignore))
+	assert.Equal(t, []string{"body.data"}, shadow.Compare(200, []byte(`{"data":[1]}`), 200, []byte(`{"data":[1,2]}`), ignore))
+	assert.Equal(t, []string{"body"}, shadow.Compare(200, []byte("ok"), 200, []byte("OK"), ignore))
+	assert.Empty(t, shadow.Compare(204, nil, 204, []byte(" "), ignore))
+}
+
+func TestRegisterRefusesWritesWithoutOptIn(t *testing.T) {
+	svc := shadow.NewService(shadow.Config{Enabled: true}, zap.NewNop())
+	candidate := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	err := svc.Register(shadow.Route{Method: "POST", Path: "/api/v1/deposits", Candidate: candidate, SamplePercent: 100})
+	assert.Error(t, err)
+	require.NoError(t, svc.Register(shadow.Route{Method: "post", Path: "/api/v1/deposits", Candidate: candidate, SamplePercent: 100, AllowWrites: true}))
+	assert.Error(t, svc.Register(shadow.Route{Method: "GET", Path: "/api/v1/deposits", SamplePercent: 100}))
+
+	_, err = shadow.NewProxyCandidate("not a url")
+	assert.Error(t, err)
+}
+
+// setup serves /items/:id from a live handler that echoes the request body,
+// shadowed to candidate
+func setup(t *testing.T, route shadow.Route) (*gin.Engine, *shadow.Service) {
+	gin.SetMode(gin.TestMode)
+	svc := shadow.NewService(shadow.Config{Enabled: true, Timeout: 100 * time.Millisecond}, zap.NewNop())
+	route.Path = "/items/:id"
+	route.SamplePercent = 100
+	require.NoError(t, svc.Register(route))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc.Start(ctx)
+
+	router := gin.New()
+	router.Use(middleware.Shadow(svc))
+	live := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "body": string(body), "request_id": "live"})
+	}
+	router.GET("/items/:id", live)
+	router.POST("/items/:id", live)
+	router.GET("/other", live)
+	return router, svc
+}
+
+func stats(t *testing.T, svc *shadow.Service) entities.ShadowRouteStats {
+	t.Helper()
+	var stat entities.ShadowRouteStats
+	require.Eventually(t, func() bool {
+		stat = svc.Stats()[0]
+		return stat.Shadowed > 0
+	}, time.Second, 5*time.Millisecond)
+	return stat
+}
+
+func TestMatchingCandidateReceivesReplayedRequest(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	router, svc := setup(t, shadow.Route{Method: "POST", AllowWrites: true, Candidate: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"request_id":"candidate","id":"7","body":"` + string(body) + `"}`))
+		received <- r
+	})})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items/7", strings.NewReader("hello")))
+	assert.JSONEq(t, `{"id":"7","body":"hello","request_id":"live"}`, w.Body.String())
+
+	stat := stats(t, svc)
+	assert.Equal(t, int64(1), stat.Matched)
+	assert.Zero(t, stat.Diverged)
+	r := <-received
+	assert.Equal(t, "/items/7", r.URL.Path)
+	assert.Equal(t, "true", r.Header.Get(shadow.Header))
+}
+
+func TestDivergenceIsRecordedWithoutTouchingResponse(t *testing.T) {
+	router, svc := setup(t, shadow.Route{Method: "GET", Candidate: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"id":"8","body":""}`))
+	})})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/7", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stat := stats(t, svc)
+	assert.Equal(t, int64(1), stat.Diverged)
+	assert.Equal(t, []string{"status", "body.id"}, stat.LastDiffPaths)
+	assert.NotNil(t, stat.LastDiverged)
+}
+
+func TestFailingCandidatesAreIsolated(t *testing.T) {
+	for name, candidate := range map[string]http.HandlerFunc{
+		"panic": func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+		"slow":  func(w http.ResponseWriter, r *http.Request) { time.Sleep(time.Second) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			router, svc := setup(t, shadow.Route{Method: "GET", Candidate: candidate})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"id":"1"`)
+
+			stat := stats(t, svc)
+			assert.Equal(t, int64(1), stat.Failed)
+		})
+	}
+}
+
+func TestUnshadowedRoutesAndMethodsPassThrough(t *testing.T) {
+	router, svc := setup(t, shadow.Route{Method: "GET", Candidate: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("candidate should not be called")
+	})})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/other", nil),
+		httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader("x")),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, svc.Stats()[0].Shadowed)
+}
@@ -0,0 +1,102 @@
+package startup_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/pkg/health"
+	"github.com/stack-service/stack_service/pkg/startup"
+)
+
+var errDown = errors.New("connection refused")
+
+func newSupervisor(required ...string) *startup.Supervisor {
+	return startup.NewSupervisor(startup.Config{
+		RetryInterval:    5 * time.Millisecond,
+		MaxRetryInterval: 20 * time.Millisecond,
+		Required:         required,
+	}, zap.NewNop())
+}
+
+func TestSupervisor_CriticalFailureAbortsStartup(t *testing.T) {
+	supervisor := newSupervisor()
+
+	err := supervisor.Start(context.Background(), startup.Component{
+		Name:     "database",
+		Critical: true,
+		Start:    func(ctx context.Context) error { return errDown },
+	})
+	assert.ErrorIs(t, err, errDown)
+}
+
+func TestSupervisor_OptionalComponentStartsDegradedAndReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	supervisor := newSupervisor()
+
+	var attempts atomic.Int32
+	err := supervisor.Start(ctx, startup.Component{
+		Name: "redis",
+		Start: func(ctx context.Context) error {
+			if attempts.Add(1) < 3 {
+				return errDown
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	checkers := supervisor.Checkers()
+	require.Len(t, checkers, 1)
+	assert.Equal(t, "redis", checkers[0].Name())
+
+	require.Eventually(t, func() bool {
+		return checkers[0].Check(ctx).Status == health.StatusHealthy
+	}, time.Second, 5*time.Millisecond)
+
+	statuses := supervisor.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, startup.StateLive, statuses[0].State)
+	assert.Equal(t, 3, statuses[0].Attempts)
+	assert.Empty(t, statuses[0].LastError)
+}
+
+func TestSupervisor_DegradedComponentReportedInReadiness(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	supervisor := newSupervisor()
+
+	require.NoError(t, supervisor.Start(ctx, startup.Component{
+		Name:     "database",
+		Critical: true,
+		Start:    func(ctx context.Context) error { return nil },
+	}))
+	require.NoError(t, supervisor.Start(ctx, startup.Component{
+		Name:  "email",
+		Start: func(ctx context.Context) error { return errDown },
+	}))
+
+	checkers := supervisor.Checkers()
+	require.Len(t, checkers, 1, "critical components are not repeated in readiness")
+	result := checkers[0].Check(ctx)
+	assert.Equal(t, "email", result.Component)
+	assert.Equal(t, health.StatusDegraded, result.Status)
+	assert.Equal(t, errDown.Error(), result.Error)
+}
+
+func TestSupervisor_RequiredOverridesOptional(t *testing.T) {
+	supervisor := newSupervisor("redis")
+
+	err := supervisor.Start(context.Background(), startup.Component{
+		Name:  "redis",
+		Start: func(ctx context.Context) error { return errDown },
+	})
+	assert.ErrorIs(t, err, errDown)
+}
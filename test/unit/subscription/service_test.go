@@ -0,0 +1,375 @@
+package subscription_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/subscription"
+)
+
+type fakeRepo struct {
+	plans    map[uuid.UUID]*entities.SubscriptionPlan
+	subs     map[uuid.UUID]*entities.Subscription
+	invoices map[uuid.UUID]*entities.Invoice
+	numbers  int
+}
+
+func newFakeRepo(plans ...*entities.SubscriptionPlan) *fakeRepo {
+	f := &fakeRepo{
+		plans:    map[uuid.UUID]*entities.SubscriptionPlan{},
+		subs:     map[uuid.UUID]*entities.Subscription{},
+		invoices: map[uuid.UUID]*entities.Invoice{},
+	}
+	for _, plan := range plans {
+		f.plans[plan.ID] = plan
+	}
+	return f
+}
+
+func (f *fakeRepo) GetPlanByCode(ctx context.Context, code string) (*entities.SubscriptionPlan, error) {
+	for _, plan := range f.plans {
+		if plan.Code == code {
+			return plan, nil
+		}
+	}
+	return nil, entities.ErrPlanNotFound
+}
+
+func (f *fakeRepo) GetPlan(ctx context.Context, id uuid.UUID) (*entities.SubscriptionPlan, error) {
+	plan, ok := f.plans[id]
+	if !ok {
+		return nil, entities.ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+func (f *fakeRepo) ListPlans(ctx context.Context) ([]*entities.SubscriptionPlan, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) CreateSubscription(ctx context.Context, sub *entities.Subscription) error {
+	if _, err := f.GetLiveByUser(ctx, sub.UserID); err == nil {
+		return entities.ErrAlreadySubscribed
+	}
+	copied := *sub
+	f.subs[sub.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetLiveByUser(ctx context.Context, userID uuid.UUID) (*entities.Subscription, error) {
+	for _, sub := range f.subs {
+		if sub.UserID == userID && sub.GrantsEntitlements() {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, entities.ErrSubscriptionNotFound
+}
+
+func (f *fakeRepo) UpdateSubscription(ctx context.Context, sub *entities.Subscription) error {
+	copied := *sub
+	f.subs[sub.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) ListDueForRenewal(ctx context.Context, now time.Time, limit int) ([]*entities.Subscription, error) {
+	var due []*entities.Subscription
+	for _, sub := range f.subs {
+		if sub.Status == entities.SubscriptionActive && !sub.CurrentPeriodEnd.After(now) {
+			copied := *sub
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeRepo) CreateInvoice(ctx context.Context, invoice *entities.Invoice) error {
+	f.numbers++
+	invoice.Number = fmt.Sprintf("INV-%08d", f.numbers)
+	copied := *invoice
+	f.invoices[invoice.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) UpdateInvoice(ctx context.Context, invoice *entities.Invoice) error {
+	copied := *invoice
+	f.invoices[invoice.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) GetInvoice(ctx context.Context, id uuid.UUID) (*entities.Invoice, error) {
+	invoice, ok := f.invoices[id]
+	if !ok {
+		return nil, entities.ErrInvoiceNotFound
+	}
+	copied := *invoice
+	return &copied, nil
+}
+
+func (f *fakeRepo) ListInvoicesByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.Invoice, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) ListDunning(ctx context.Context, now time.Time, limit int) ([]*entities.Invoice, error) {
+	var due []*entities.Invoice
+	for _, invoice := range f.invoices {
+		if invoice.Status == entities.InvoiceOpen && invoice.NextAttemptAt != nil && !invoice.NextAttemptAt.After(now) {
+			copied := *invoice
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeRepo) invoicesWith(status entities.InvoiceStatus) []*entities.Invoice {
+	var matched []*entities.Invoice
+	for _, invoice := range f.invoices {
+		if invoice.Status == status {
+			matched = append(matched, invoice)
+		}
+	}
+	return matched
+}
+
+func (f *fakeRepo) onlySub(t *testing.T) *entities.Subscription {
+	require.Len(t, f.subs, 1)
+	for _, sub := range f.subs {
+		return sub
+	}
+	return nil
+}
+
+type fakeLedger struct {
+	fees []decimal.Decimal
+}
+
+func (l *fakeLedger) GetOrCreateUserAccount(ctx context.Context, userID uuid.UUID, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return &entities.LedgerAccount{ID: uuid.New(), AccountType: accountType}, nil
+}
+
+func (l *fakeLedger) GetSystemAccount(ctx context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return &entities.LedgerAccount{ID: uuid.New(), AccountType: accountType}, nil
+}
+
+func (l *fakeLedger) CreateTransaction(ctx context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	if req.TransactionType != entities.TransactionTypeSubscriptionFee {
+		return nil, errors.New("unexpected transaction type")
+	}
+	l.fees = append(l.fees, req.Entries[0].Amount)
+	return &entities.LedgerTransaction{ID: uuid.New()}, nil
+}
+
+type fakeCash map[uuid.UUID]decimal.Decimal
+
+func (f fakeCash) DeductBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	if f[userID].LessThan(amount) {
+		return errors.New("insufficient buying power or user not found")
+	}
+	f[userID] = f[userID].Sub(amount)
+	return nil
+}
+
+func (f fakeCash) UpdateBuyingPower(ctx context.Context, userID uuid.UUID, amount decimal.Decimal) error {
+	f[userID] = f[userID].Add(amount)
+	return nil
+}
+
+var (
+	premium = &entities.SubscriptionPlan{
+		ID: uuid.New(), Code: "premium", Name: "Premium", Price: decimal.NewFromInt(10),
+		Currency: "USD", IntervalMonths: 1, Active: true,
+		Entitlements: entities.Entitlements{entities.FeatureAIAnalyses: entities.UnlimitedEntitlement},
+	}
+	premiumPlus = &entities.SubscriptionPlan{
+		ID: uuid.New(), Code: "premium_plus", Name: "Premium Plus", Price: decimal.NewFromInt(20),
+		Currency: "USD", IntervalMonths: 1, Active: true,
+		Entitlements: entities.Entitlements{entities.FeatureAIAnalyses: entities.UnlimitedEntitlement, entities.FeaturePrioritySupport: 1},
+	}
+)
+
+type fixture struct {
+	svc    *subscription.Service
+	repo   *fakeRepo
+	ledger *fakeLedger
+	cash   fakeCash
+	userID uuid.UUID
+}
+
+func newFixture(cash string) *fixture {
+	f := &fixture{
+		repo:   newFakeRepo(premium, premiumPlus),
+		ledger: &fakeLedger{},
+		cash:   fakeCash{},
+		userID: uuid.New(),
+	}
+	f.cash[f.userID] = decimal.RequireFromString(cash)
+	f.svc = subscription.NewService(f.repo, f.ledger, f.cash, nil, subscription.Config{
+		DunningSchedule: []time.Duration{time.Hour, time.Hour},
+	}, zap.NewNop())
+	return f
+}
+
+func (f *fixture) subscribe(t *testing.T, code string) *entities.SubscriptionOverview {
+	overview, err := f.svc.Subscribe(context.Background(), f.userID, &entities.SubscribeRequest{PlanCode: code})
+	require.NoError(t, err)
+	return overview
+}
+
+// endPeriod moves the subscription's period into the past so it is due
+func (f *fixture) endPeriod(t *testing.T) {
+	sub := f.repo.onlySub(t)
+	sub.CurrentPeriodStart = time.Now().AddDate(0, -1, 0)
+	sub.CurrentPeriodEnd = time.Now().Add(-time.Minute)
+}
+
+// dueNow makes open invoices due for their next dunning attempt
+func (f *fixture) dueNow() {
+	past := time.Now().Add(-time.Minute)
+	for _, invoice := range f.repo.invoicesWith(entities.InvoiceOpen) {
+		invoice.NextAttemptAt = &past
+	}
+}
+
+func TestSubscribe_ChargesCashBalanceAndGrantsEntitlements(t *testing.T) {
+	f := newFixture("25")
+
+	overview := f.subscribe(t, "premium")
+	require.NotNil(t, overview.Subscription)
+	assert.Equal(t, entities.SubscriptionActive, overview.Subscription.Status)
+	assert.Equal(t, entities.UnlimitedEntitlement, overview.Entitlements.Limit(entities.FeatureAIAnalyses))
+	assert.True(t, f.svc.IsEntitled(context.Background(), f.userID, entities.FeatureAIAnalyses, 1000))
+	assert.Equal(t, "15", f.cash[f.userID].String())
+	require.Len(t, f.ledger.fees, 1)
+	assert.Equal(t, "10", f.ledger.fees[0].String())
+
+	paid := f.repo.invoicesWith(entities.InvoicePaid)
+	require.Len(t, paid, 1)
+	assert.Equal(t, "10", paid[0].Total.String())
+	assert.NotNil(t, paid[0].LedgerTxID)
+
+	_, err := f.svc.Subscribe(context.Background(), f.userID, &entities.SubscribeRequest{PlanCode: "premium"})
+	assert.ErrorIs(t, err, entities.ErrAlreadySubscribed)
+}
+
+func TestSubscribe_FailedFirstPaymentLeavesUserOnFreeTier(t *testing.T) {
+	f := newFixture("5")
+
+	_, err := f.svc.Subscribe(context.Background(), f.userID, &entities.SubscribeRequest{PlanCode: "premium"})
+	assert.ErrorIs(t, err, entities.ErrPaymentFailed)
+	assert.Len(t, f.repo.invoicesWith(entities.InvoiceVoid), 1)
+
+	entitlements, err := f.svc.Entitlements(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.FreeTierEntitlements(), entitlements)
+	assert.False(t, f.svc.IsEntitled(context.Background(), f.userID, entities.FeatureAIAnalyses, 3))
+	assert.Equal(t, "5", f.cash[f.userID].String())
+
+	_, err = f.svc.Subscribe(context.Background(), f.userID, &entities.SubscribeRequest{
+		PlanCode: "premium", PaymentMethod: entities.PaymentMethodCard,
+	})
+	assert.ErrorIs(t, err, entities.ErrPaymentMethodUnavailable)
+}
+
+func TestChangePlan_ProratesRemainingPeriod(t *testing.T) {
+	f := newFixture("100")
+	f.subscribe(t, "premium")
+
+	sub := f.repo.onlySub(t)
+	sub.CurrentPeriodStart = time.Now().Add(-15 * 24 * time.Hour)
+	sub.CurrentPeriodEnd = time.Now().Add(15 * 24 * time.Hour)
+
+	overview := f.subscribe(t, "premium_plus")
+	assert.Equal(t, "premium_plus", overview.Plan.Code)
+	assert.Equal(t, 1, overview.Entitlements.Limit(entities.FeaturePrioritySupport))
+	assert.Equal(t, "5", f.ledger.fees[1].String(), "half a period of the 10 dollar difference")
+
+	// Downgrading back leaves the unused difference as credit for renewal
+	f.subscribe(t, "premium")
+	sub = f.repo.onlySub(t)
+	assert.Equal(t, "5", sub.CreditBalance.String())
+	assert.Len(t, f.ledger.fees, 2)
+
+	f.endPeriod(t)
+	report, err := f.svc.RunBilling(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Renewed)
+	assert.Equal(t, "5", f.ledger.fees[2].String(), "credit applied to the renewal")
+	assert.True(t, f.repo.onlySub(t).CreditBalance.IsZero())
+}
+
+func TestRunBilling_DunningRecoversPaidSubscription(t *testing.T) {
+	f := newFixture("10")
+	f.subscribe(t, "premium")
+	f.endPeriod(t)
+
+	report, err := f.svc.RunBilling(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, entities.SubscriptionPastDue, f.repo.onlySub(t).Status)
+	assert.True(t, f.svc.IsEntitled(context.Background(), f.userID, entities.FeatureAIAnalyses, 100), "entitlements kept during dunning")
+
+	open := f.repo.invoicesWith(entities.InvoiceOpen)
+	require.Len(t, open, 1)
+	assert.Equal(t, 1, open[0].AttemptCount)
+	require.NotNil(t, open[0].NextAttemptAt)
+
+	f.cash[f.userID] = decimal.NewFromInt(10)
+	invoice, err := f.svc.PayInvoice(context.Background(), f.userID, open[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.InvoicePaid, invoice.Status)
+	assert.Equal(t, entities.SubscriptionActive, f.repo.onlySub(t).Status)
+}
+
+func TestRunBilling_CancelsWhenDunningExhausted(t *testing.T) {
+	f := newFixture("10")
+	f.subscribe(t, "premium")
+	f.endPeriod(t)
+
+	_, err := f.svc.RunBilling(context.Background())
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		f.dueNow()
+		_, err = f.svc.RunBilling(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, entities.SubscriptionCanceled, f.repo.onlySub(t).Status)
+	assert.Len(t, f.repo.invoicesWith(entities.InvoiceUncollectible), 1)
+	assert.False(t, f.svc.IsEntitled(context.Background(), f.userID, entities.FeatureAIAnalyses, 3))
+}
+
+func TestCancel_KeepsPlanUntilPeriodEnd(t *testing.T) {
+	f := newFixture("100")
+	f.subscribe(t, "premium")
+
+	sub, err := f.svc.Cancel(context.Background(), f.userID)
+	require.NoError(t, err)
+	assert.True(t, sub.CancelAtPeriodEnd)
+	assert.True(t, f.svc.IsEntitled(context.Background(), f.userID, entities.FeatureAIAnalyses, 100))
+
+	f.endPeriod(t)
+	report, err := f.svc.RunBilling(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Canceled)
+	assert.Equal(t, entities.SubscriptionCanceled, f.repo.onlySub(t).Status)
+	assert.Len(t, f.ledger.fees, 1, "not billed after cancellation")
+}
+
+func TestProrate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(30 * 24 * time.Hour)
+
+	assert.Equal(t, "1", subscription.Prorate(start.Add(-time.Hour), start, end).String())
+	assert.Equal(t, "0.5", subscription.Prorate(start.Add(15*24*time.Hour), start, end).String())
+	assert.True(t, subscription.Prorate(end, start, end).IsZero())
+}
@@ -0,0 +1,268 @@
+package suspense_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/suspense"
+)
+
+type memoryRepo struct {
+	items map[uuid.UUID]*entities.SuspenseItem
+}
+
+func (r *memoryRepo) Create(_ context.Context, item *entities.SuspenseItem) (*entities.SuspenseItem, bool, error) {
+	for _, existing := range r.items {
+		if existing.Source == item.Source && existing.SourceRef == item.SourceRef {
+			copied := *existing
+			return &copied, false, nil
+		}
+	}
+	copied := *item
+	r.items[item.ID] = &copied
+	return item, true, nil
+}
+
+func (r *memoryRepo) Get(_ context.Context, id uuid.UUID) (*entities.SuspenseItem, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, entities.ErrSuspenseItemNotFound
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (r *memoryRepo) List(_ context.Context, status entities.SuspenseStatus, _, _ int) ([]*entities.SuspenseItem, error) {
+	var items []*entities.SuspenseItem
+	for _, item := range r.items {
+		if item.Status == status {
+			copied := *item
+			items = append(items, &copied)
+		}
+	}
+	return items, nil
+}
+
+func (r *memoryRepo) SetHoldTransaction(_ context.Context, id, ledgerTxID uuid.UUID) error {
+	r.items[id].HoldLedgerTransactionID = &ledgerTxID
+	return nil
+}
+
+func (r *memoryRepo) Claim(_ context.Context, id uuid.UUID, _ time.Time) (bool, error) {
+	if r.items[id].Status != entities.SuspenseStatusOpen {
+		return false, nil
+	}
+	r.items[id].Status = entities.SuspenseStatusResolving
+	return true, nil
+}
+
+func (r *memoryRepo) Reopen(_ context.Context, id uuid.UUID, _ time.Time) error {
+	r.items[id].Status = entities.SuspenseStatusOpen
+	return nil
+}
+
+func (r *memoryRepo) Resolve(_ context.Context, item *entities.SuspenseItem) error {
+	copied := *item
+	r.items[item.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepo) Aging(_ context.Context, agedBefore time.Time) ([]entities.SuspenseAging, error) {
+	bySource := map[entities.SuspenseSource]*entities.SuspenseAging{}
+	var sources []entities.SuspenseAging
+	for _, item := range r.items {
+		if item.Status != entities.SuspenseStatusOpen {
+			continue
+		}
+		aging, ok := bySource[item.Source]
+		if !ok {
+			aging = &entities.SuspenseAging{Source: item.Source}
+			bySource[item.Source] = aging
+		}
+		aging.Open++
+		if item.ReceivedAt.Before(agedBefore) {
+			aging.Aged++
+			aging.AgedUSDAmount = aging.AgedUSDAmount.Add(item.USDAmount)
+		}
+	}
+	for _, aging := range bySource {
+		sources = append(sources, *aging)
+	}
+	return sources, nil
+}
+
+type fakeLedger struct {
+	accounts map[entities.AccountType]uuid.UUID
+	balances map[uuid.UUID]decimal.Decimal
+	keys     map[string]bool
+}
+
+func newLedger() *fakeLedger {
+	return &fakeLedger{
+		accounts: map[entities.AccountType]uuid.UUID{
+			entities.AccountTypeSystemSuspense:   uuid.New(),
+			entities.AccountTypeSystemBufferFiat: uuid.New(),
+			entities.AccountTypeSystemBufferUSDC: uuid.New(),
+		},
+		balances: map[uuid.UUID]decimal.Decimal{},
+		keys:     map[string]bool{},
+	}
+}
+
+func (l *fakeLedger) GetSystemAccount(_ context.Context, accountType entities.AccountType) (*entities.LedgerAccount, error) {
+	return &entities.LedgerAccount{ID: l.accounts[accountType], AccountType: accountType}, nil
+}
+
+func (l *fakeLedger) CreateTransaction(_ context.Context, req *entities.CreateTransactionRequest) (*entities.LedgerTransaction, error) {
+	if !l.keys[req.IdempotencyKey] {
+		l.keys[req.IdempotencyKey] = true
+		for _, entry := range req.Entries {
+			if entry.EntryType == entities.EntryTypeDebit {
+				l.balances[entry.AccountID] = l.balances[entry.AccountID].Add(entry.Amount)
+			} else {
+				l.balances[entry.AccountID] = l.balances[entry.AccountID].Sub(entry.Amount)
+			}
+		}
+	}
+	return &entities.LedgerTransaction{ID: uuid.New()}, nil
+}
+
+func (l *fakeLedger) suspenseBalance() decimal.Decimal {
+	return l.balances[l.accounts[entities.AccountTypeSystemSuspense]]
+}
+
+type fakeSource struct {
+	assigned map[uuid.UUID]uuid.UUID
+	refunded map[uuid.UUID]bool
+	fail     error
+}
+
+func (f *fakeSource) SuspenseSuggestions(_ context.Context, item *entities.SuspenseItem) ([]entities.SuspenseSuggestion, error) {
+	return []entities.SuspenseSuggestion{{UserID: uuid.Nil, Reason: entities.SuspenseSuggestionSenderName, Detail: item.Details["sender_name"]}}, nil
+}
+
+func (f *fakeSource) AssignSuspended(_ context.Context, item *entities.SuspenseItem, userID, _ uuid.UUID, _ string) error {
+	if f.fail != nil {
+		return f.fail
+	}
+	f.assigned[item.ID] = userID
+	return nil
+}
+
+func (f *fakeSource) RefundSuspended(_ context.Context, item *entities.SuspenseItem, _ uuid.UUID, _ string) error {
+	if f.fail != nil {
+		return f.fail
+	}
+	f.refunded[item.ID] = true
+	return nil
+}
+
+func newService() (*suspense.Service, *memoryRepo, *fakeLedger, *fakeSource) {
+	repo := &memoryRepo{items: map[uuid.UUID]*entities.SuspenseItem{}}
+	ledger := newLedger()
+	source := &fakeSource{assigned: map[uuid.UUID]uuid.UUID{}, refunded: map[uuid.UUID]bool{}}
+	service := suspense.NewService(repo, ledger, suspense.Config{AgedAfter: 7 * 24 * time.Hour}, zap.NewNop())
+	service.RegisterSource(entities.SuspenseSourceBankCredit, source)
+	return service, repo, ledger, source
+}
+
+func bankItem(ref string, amount int64) *entities.SuspenseItem {
+	return &entities.SuspenseItem{
+		Source:    entities.SuspenseSourceBankCredit,
+		SourceRef: ref,
+		Currency:  "USD",
+		Amount:    decimal.NewFromInt(amount),
+		USDAmount: decimal.NewFromInt(amount),
+		Reason:    entities.BankCreditNoReference,
+		Details:   map[string]string{"sender_name": "ADA OBI"},
+	}
+}
+
+func TestHoldBooksIntoSuspenseOnce(t *testing.T) {
+	service, repo, ledger, _ := newService()
+	ctx := context.Background()
+
+	held, err := service.Hold(ctx, bankItem("credit-1", 120))
+	require.NoError(t, err)
+	assert.Equal(t, entities.SuspenseStatusOpen, held.Status)
+	require.NotNil(t, held.HoldLedgerTransactionID)
+
+	again, err := service.Hold(ctx, bankItem("credit-1", 120))
+	require.NoError(t, err)
+	assert.Equal(t, held.ID, again.ID)
+	assert.Len(t, repo.items, 1)
+	assert.True(t, decimal.NewFromInt(120).Equal(ledger.suspenseBalance()))
+
+	listed, err := service.List(ctx, entities.SuspenseStatusOpen, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Len(t, listed[0].Suggestions, 1)
+	assert.Equal(t, "ADA OBI", listed[0].Suggestions[0].Detail)
+}
+
+func TestAssignAndRefundReleaseFromSuspense(t *testing.T) {
+	service, _, ledger, source := newService()
+	ctx := context.Background()
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	toAssign, err := service.Hold(ctx, bankItem("credit-2", 80))
+	require.NoError(t, err)
+	toRefund, err := service.Hold(ctx, bankItem("credit-3", 20))
+	require.NoError(t, err)
+
+	source.fail = errors.New("provider down")
+	_, err = service.Assign(ctx, toAssign.ID, adminID, &entities.AssignSuspenseItemRequest{UserID: userID, Note: "memo typo"})
+	require.Error(t, err)
+	reopened, err := service.Get(ctx, toAssign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.SuspenseStatusOpen, reopened.Status)
+
+	source.fail = nil
+	assigned, err := service.Assign(ctx, toAssign.ID, adminID, &entities.AssignSuspenseItemRequest{UserID: userID, Note: "memo typo"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.SuspenseStatusAssigned, assigned.Status)
+	assert.Equal(t, userID, *assigned.UserID)
+	assert.NotNil(t, assigned.ReleaseLedgerTransactionID)
+	assert.Equal(t, userID, source.assigned[toAssign.ID])
+
+	_, err = service.Refund(ctx, toAssign.ID, adminID, &entities.RefundSuspenseItemRequest{Note: "too late"})
+	assert.ErrorIs(t, err, entities.ErrSuspenseItemResolved)
+
+	refunded, err := service.Refund(ctx, toRefund.ID, adminID, &entities.RefundSuspenseItemRequest{Note: "returned to sender"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.SuspenseStatusRefunded, refunded.Status)
+	assert.True(t, source.refunded[toRefund.ID])
+	assert.True(t, ledger.suspenseBalance().IsZero())
+}
+
+func TestCheckAgingCountsItemsPastTheThreshold(t *testing.T) {
+	service, _, _, _ := newService()
+	ctx := context.Background()
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	service.SetClock(func() time.Time { return now })
+
+	old := bankItem("credit-4", 300)
+	old.ReceivedAt = now.Add(-10 * 24 * time.Hour)
+	_, err := service.Hold(ctx, old)
+	require.NoError(t, err)
+	_, err = service.Hold(ctx, bankItem("credit-5", 50))
+	require.NoError(t, err)
+
+	report, err := service.CheckAging(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.AgedAfterDays)
+	require.Len(t, report.Sources, 1)
+	assert.Equal(t, 2, report.Sources[0].Open)
+	assert.Equal(t, 1, report.Sources[0].Aged)
+	assert.True(t, decimal.NewFromInt(300).Equal(report.Sources[0].AgedUSDAmount))
+}
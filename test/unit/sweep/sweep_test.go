@@ -0,0 +1,337 @@
+package sweep_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/investing"
+	"github.com/stack-service/stack_service/internal/domain/services/sweep"
+)
+
+type fakeRepo struct {
+	rules    map[uuid.UUID]*entities.SweepRule
+	runs     []*entities.SweepRun
+	disabled map[uuid.UUID]string
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{rules: map[uuid.UUID]*entities.SweepRule{}, disabled: map[uuid.UUID]string{}}
+}
+
+func (f *fakeRepo) GetByUser(ctx context.Context, userID uuid.UUID) (*entities.SweepRule, error) {
+	rule, ok := f.rules[userID]
+	if !ok {
+		return nil, entities.ErrSweepRuleNotFound
+	}
+	stored := *rule
+	return &stored, nil
+}
+
+func (f *fakeRepo) Save(ctx context.Context, rule *entities.SweepRule) error {
+	stored := *rule
+	f.rules[rule.UserID] = &stored
+	return nil
+}
+
+func (f *fakeRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.SweepRule, error) {
+	var due []*entities.SweepRule
+	for _, rule := range f.rules {
+		if rule.Enabled && !rule.NextRunAt.After(now) {
+			stored := *rule
+			due = append(due, &stored)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeRepo) FinishRun(ctx context.Context, rule *entities.SweepRule, run *entities.SweepRun, disableReason *string) error {
+	f.runs = append(f.runs, run)
+	stored := *rule
+	if disableReason != nil {
+		stored.Enabled = false
+		stored.DisabledReason = disableReason
+		f.disabled[rule.UserID] = *disableReason
+	}
+	f.rules[rule.UserID] = &stored
+	return nil
+}
+
+func (f *fakeRepo) ListRuns(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.SweepRun, error) {
+	return f.runs, nil
+}
+
+type fakeBalances struct {
+	buyingPower decimal.Decimal
+}
+
+func (f *fakeBalances) Get(ctx context.Context, userID uuid.UUID) (*entities.Balance, error) {
+	return &entities.Balance{UserID: userID, BuyingPower: f.buyingPower}, nil
+}
+
+type fakeInvestor struct {
+	baskets map[uuid.UUID]bool
+	orders  []*entities.OrderCreateRequest
+	err     error
+}
+
+func (f *fakeInvestor) GetBasket(ctx context.Context, basketID uuid.UUID) (*entities.Basket, error) {
+	if !f.baskets[basketID] {
+		return nil, investing.ErrBasketNotFound
+	}
+	return &entities.Basket{ID: basketID}, nil
+}
+
+func (f *fakeInvestor) CreateOrder(ctx context.Context, userID uuid.UUID, req *entities.OrderCreateRequest) (*entities.Order, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.orders = append(f.orders, req)
+	return &entities.Order{ID: uuid.New(), UserID: userID, BasketID: req.BasketID}, nil
+}
+
+type fakeNotifier struct {
+	sent []*entities.Notification
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, notification *entities.Notification, prefs *entities.UserPreference) error {
+	f.sent = append(f.sent, notification)
+	return nil
+}
+
+type fixture struct {
+	service  *sweep.Service
+	repo     *fakeRepo
+	balances *fakeBalances
+	investor *fakeInvestor
+	notifier *fakeNotifier
+	basketID uuid.UUID
+	userID   uuid.UUID
+	now      time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		repo:     newFakeRepo(),
+		balances: &fakeBalances{},
+		notifier: &fakeNotifier{},
+		basketID: uuid.New(),
+		userID:   uuid.New(),
+		now:      time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+	}
+	f.investor = &fakeInvestor{baskets: map[uuid.UUID]bool{f.basketID: true}}
+	config := sweep.DefaultConfig()
+	config.DefaultBasketID = f.basketID
+	f.service = sweep.NewService(f.repo, f.balances, f.investor, f.notifier, config, zap.NewNop())
+	f.service.SetClock(func() time.Time { return f.now })
+	return f
+}
+
+func (f *fixture) save(t *testing.T, req *entities.SaveSweepRuleRequest) *entities.SweepRule {
+	t.Helper()
+	rule, err := f.service.Save(context.Background(), f.userID, req)
+	require.NoError(t, err)
+	return rule
+}
+
+func dec(value int64) *decimal.Decimal {
+	d := decimal.NewFromInt(value)
+	return &d
+}
+
+func TestSaveUsesDefaultBasketAndRunsNow(t *testing.T) {
+	f := newFixture(t)
+
+	rule := f.save(t, &entities.SaveSweepRuleRequest{Floor: decimal.NewFromInt(500), Cadence: entities.SweepCadenceWeekly})
+
+	assert.Equal(t, f.basketID, rule.BasketID)
+	assert.True(t, rule.Enabled)
+	assert.Equal(t, f.now, rule.NextRunAt)
+	assert.True(t, decimal.NewFromInt(10).Equal(rule.MinAmount))
+}
+
+func TestSaveValidatesRule(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	cases := map[string]*entities.SaveSweepRuleRequest{
+		"cadence":         {Cadence: "hourly"},
+		"negative floor":  {Floor: decimal.NewFromInt(-1), Cadence: entities.SweepCadenceDaily},
+		"minimum too low": {MinAmount: dec(5), Cadence: entities.SweepCadenceDaily},
+		"max below min":   {MinAmount: dec(50), MaxAmount: dec(20), Cadence: entities.SweepCadenceDaily},
+		"unknown basket":  {BasketID: &[]uuid.UUID{uuid.New()}[0], Cadence: entities.SweepCadenceDaily},
+	}
+	for name, req := range cases {
+		_, err := f.service.Save(ctx, f.userID, req)
+		assert.ErrorIs(t, err, entities.ErrInvalidSweepRule, name)
+	}
+}
+
+func TestRunDueInvestsAboveFloorUpToMax(t *testing.T) {
+	f := newFixture(t)
+	f.save(t, &entities.SaveSweepRuleRequest{Floor: decimal.NewFromInt(500), MaxAmount: dec(300), Cadence: entities.SweepCadenceWeekly})
+	f.balances.buyingPower = decimal.RequireFromString("1250.75")
+
+	report, err := f.service.RunDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Invested)
+	require.Len(t, f.investor.orders, 1)
+	assert.Equal(t, "300.00", f.investor.orders[0].Amount)
+	assert.Equal(t, entities.OrderSideBuy, f.investor.orders[0].Side)
+	require.NotNil(t, f.investor.orders[0].IdempotencyKey)
+
+	require.Len(t, f.repo.runs, 1)
+	assert.Equal(t, entities.SweepRunInvested, f.repo.runs[0].Status)
+	assert.Equal(t, f.now.AddDate(0, 0, 7), f.repo.rules[f.userID].NextRunAt)
+	require.Len(t, f.notifier.sent, 1)
+	assert.Equal(t, "Idle cash invested", f.notifier.sent[0].Title)
+}
+
+func TestRunDueSkipsBelowMinimumQuietly(t *testing.T) {
+	f := newFixture(t)
+	f.save(t, &entities.SaveSweepRuleRequest{Floor: decimal.NewFromInt(500), Cadence: entities.SweepCadenceDaily})
+	f.balances.buyingPower = decimal.NewFromInt(505)
+
+	report, err := f.service.RunDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Skipped)
+	assert.Empty(t, f.investor.orders)
+	assert.Empty(t, f.notifier.sent)
+	require.Len(t, f.repo.runs, 1)
+	assert.NotNil(t, f.repo.runs[0].Reason)
+}
+
+func TestInsufficientFundsIsSkippedNotFailed(t *testing.T) {
+	f := newFixture(t)
+	f.save(t, &entities.SaveSweepRuleRequest{Cadence: entities.SweepCadenceDaily})
+	f.balances.buyingPower = decimal.NewFromInt(100)
+	f.investor.err = investing.ErrInsufficientFunds
+
+	report, err := f.service.RunDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Skipped)
+	assert.Zero(t, f.repo.rules[f.userID].ConsecutiveFailures)
+}
+
+func TestRepeatedFailuresDisableRule(t *testing.T) {
+	f := newFixture(t)
+	f.save(t, &entities.SaveSweepRuleRequest{Cadence: entities.SweepCadenceDaily})
+	f.balances.buyingPower = decimal.NewFromInt(100)
+	f.investor.err = errors.New("broker unavailable")
+
+	for i := 0; i < 3; i++ {
+		report, err := f.service.RunDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Failed)
+		f.now = f.now.AddDate(0, 0, 1)
+	}
+
+	rule := f.repo.rules[f.userID]
+	assert.False(t, rule.Enabled)
+	assert.Equal(t, 3, rule.ConsecutiveFailures)
+	assert.Contains(t, f.repo.disabled[f.userID], "3 failed sweeps")
+	require.Len(t, f.notifier.sent, 3)
+	assert.Equal(t, "Auto-sweep turned off", f.notifier.sent[2].Title)
+	assert.Equal(t, entities.PriorityHigh, f.notifier.sent[2].Priority)
+
+	report, err := f.service.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.Invested+report.Skipped+report.Failed)
+
+	f.investor.err = nil
+	rule = f.save(t, &entities.SaveSweepRuleRequest{Cadence: entities.SweepCadenceDaily})
+	assert.True(t, rule.Enabled)
+	assert.Zero(t, rule.ConsecutiveFailures)
+	assert.Nil(t, rule.DisabledReason)
+}
+
+func TestDisableKeepsSettings(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.service.Disable(context.Background(), f.userID)
+	assert.ErrorIs(t, err, entities.ErrSweepRuleNotFound)
+
+	f.save(t, &entities.SaveSweepRuleRequest{Floor: decimal.NewFromInt(250), Cadence: entities.SweepCadenceMonthly})
+	rule, err := f.service.Disable(context.Background(), f.userID)
+	require.NoError(t, err)
+
+	assert.False(t, rule.Enabled)
+	assert.NotNil(t, rule.DisabledReason)
+	assert.True(t, decimal.NewFromInt(250).Equal(f.repo.rules[f.userID].Floor))
+}
+
+func TestCadenceNextSkipsMissedRuns(t *testing.T) {
+	scheduled := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 2, 11, 9, 0, 0, 0, time.UTC), entities.SweepCadenceDaily.Next(scheduled, now))
+	assert.Equal(t, time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC), entities.SweepCadenceWeekly.Next(scheduled, now))
+	assert.True(t, entities.SweepCadenceMonthly.Next(scheduled, now).After(now))
+}
+
+type fakeGates struct {
+	trading bool
+	pending []*entities.AgreementVersion
+	mode    entities.TradingMode
+}
+
+func (f *fakeGates) IsFeatureAllowed(ctx context.Context, userID uuid.UUID, feature entities.JurisdictionFeature) (bool, string, error) {
+	return f.trading, "NG", nil
+}
+
+func (f *fakeGates) PendingMandatory(ctx context.Context, userID uuid.UUID) ([]*entities.AgreementVersion, error) {
+	return f.pending, nil
+}
+
+func (f *fakeGates) Mode(ctx context.Context, userID uuid.UUID) (entities.TradingMode, error) {
+	return f.mode, nil
+}
+
+func TestRunDueRechecksEligibilityBeforeInvesting(t *testing.T) {
+	cases := map[string]*fakeGates{
+		"trading not allowed": {trading: false, mode: entities.TradingModeLive},
+		"consents pending":    {trading: true, pending: []*entities.AgreementVersion{{}}, mode: entities.TradingModeLive},
+		"paper mode":          {trading: true, mode: entities.TradingModePaper},
+	}
+	for name, gates := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			f.service.SetGates(gates, gates)
+			f.service.SetTradingModes(gates)
+			f.save(t, &entities.SaveSweepRuleRequest{Cadence: entities.SweepCadenceDaily})
+			f.balances.buyingPower = decimal.NewFromInt(1000)
+
+			report, err := f.service.RunDue(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, 1, report.Skipped)
+			assert.Empty(t, f.investor.orders)
+			assert.Empty(t, f.notifier.sent)
+			require.Len(t, f.repo.runs, 1)
+			assert.NotNil(t, f.repo.runs[0].Reason)
+			assert.Zero(t, f.repo.rules[f.userID].ConsecutiveFailures)
+		})
+	}
+
+	f := newFixture(t)
+	gates := &fakeGates{trading: true, mode: entities.TradingModeLive}
+	f.service.SetGates(gates, gates)
+	f.service.SetTradingModes(gates)
+	f.save(t, &entities.SaveSweepRuleRequest{Cadence: entities.SweepCadenceDaily})
+	f.balances.buyingPower = decimal.NewFromInt(1000)
+
+	report, err := f.service.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Invested)
+}
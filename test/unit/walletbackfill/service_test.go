@@ -0,0 +1,238 @@
+package walletbackfill_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/walletbackfill"
+)
+
+// fakeRepo models users missing a wallet; enqueued jobs stay pending until
+// completeJobs is called
+type fakeRepo struct {
+	mu        sync.Mutex
+	users     []uuid.UUID
+	backfills map[uuid.UUID]*entities.WalletBackfill
+	jobs      map[uuid.UUID]entities.WalletProvisioningJobStatus // by user
+	batches   []int
+}
+
+func newFakeRepo(users int) *fakeRepo {
+	f := &fakeRepo{backfills: map[uuid.UUID]*entities.WalletBackfill{}, jobs: map[uuid.UUID]entities.WalletProvisioningJobStatus{}}
+	for i := 0; i < users; i++ {
+		f.users = append(f.users, uuid.New())
+	}
+	sort.Slice(f.users, func(i, j int) bool { return f.users[i].String() < f.users[j].String() })
+	return f
+}
+
+func (f *fakeRepo) Create(ctx context.Context, b *entities.WalletBackfill) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.backfills {
+		if existing.Status == entities.WalletBackfillRunning {
+			return entities.ErrWalletBackfillRunning
+		}
+	}
+	copied := *b
+	f.backfills[b.ID] = &copied
+	return nil
+}
+
+func (f *fakeRepo) Get(ctx context.Context, id uuid.UUID) (*entities.WalletBackfill, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.backfills[id]
+	if !ok {
+		return nil, entities.ErrWalletBackfillNotFound
+	}
+	copied := *b
+	return &copied, nil
+}
+
+func (f *fakeRepo) List(ctx context.Context, limit int) ([]*entities.WalletBackfill, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) RecordProgress(ctx context.Context, id uuid.UUID, enqueued int, cursor *uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.backfills[id].EnqueuedJobs = enqueued
+	f.backfills[id].CursorUserID = cursor
+	return nil
+}
+
+func (f *fakeRepo) Finish(ctx context.Context, id uuid.UUID, status entities.WalletBackfillStatus, msg *string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.backfills[id]
+	if !ok || b.Status != entities.WalletBackfillRunning {
+		return false, nil
+	}
+	b.Status = status
+	b.ErrorMessage = msg
+	return true, nil
+}
+
+func (f *fakeRepo) CountEligibleUsers(ctx context.Context, chains []string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.users) - len(f.jobs), nil
+}
+
+func (f *fakeRepo) ListCandidates(ctx context.Context, chains []string, after *uuid.UUID, limit int) ([]entities.WalletBackfillCandidate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []entities.WalletBackfillCandidate
+	for _, user := range f.users {
+		if after != nil && user.String() <= after.String() {
+			continue
+		}
+		if _, ok := f.jobs[user]; ok {
+			continue
+		}
+		out = append(out, entities.WalletBackfillCandidate{UserID: user, MissingChains: chains})
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) EnqueueJobs(ctx context.Context, backfillID uuid.UUID, candidates []entities.WalletBackfillCandidate, maxAttempts int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range candidates {
+		f.jobs[c.UserID] = entities.ProvisioningStatusQueued
+	}
+	f.batches = append(f.batches, len(candidates))
+	return nil
+}
+
+func (f *fakeRepo) CountJobs(ctx context.Context, backfillID uuid.UUID) (entities.WalletBackfillJobCounts, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var counts entities.WalletBackfillJobCounts
+	for _, status := range f.jobs {
+		if status == entities.ProvisioningStatusCompleted {
+			counts.Completed++
+		} else {
+			counts.Queued++
+		}
+	}
+	return counts, nil
+}
+
+func (f *fakeRepo) completeJobs() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for user := range f.jobs {
+		f.jobs[user] = entities.ProvisioningStatusCompleted
+	}
+}
+
+func (f *fakeRepo) batchSizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.batches...)
+}
+
+func newService(repo *fakeRepo, maxInFlight int) *walletbackfill.Service {
+	return walletbackfill.NewService(repo, walletbackfill.Config{
+		BatchSize:       2,
+		BatchInterval:   time.Millisecond, // rounds down to no pause between batches
+		MaxInFlight:     maxInFlight,
+		SupportedChains: []entities.WalletChain{entities.ChainSOLDevnet},
+	}, zap.NewNop())
+}
+
+func TestRun_EnqueuesEveryEligibleUserInBatches(t *testing.T) {
+	repo := newFakeRepo(5)
+	svc := newService(repo, 100)
+
+	backfill, err := svc.Run(context.Background(), &entities.StartWalletBackfillRequest{
+		Chains: []string{string(entities.ChainSOLDevnet)},
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.WalletBackfillCompleted, backfill.Status)
+	assert.Equal(t, 5, backfill.EligibleUsers)
+	assert.Equal(t, 5, backfill.EnqueuedJobs)
+	assert.Equal(t, []int{2, 2, 1}, repo.batchSizes())
+	require.NotNil(t, backfill.Jobs)
+	assert.Equal(t, 5, backfill.Jobs.Pending())
+}
+
+func TestRun_PausesAtMaxInFlight(t *testing.T) {
+	repo := newFakeRepo(4)
+	svc := newService(repo, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan *entities.WalletBackfill, 1)
+	go func() {
+		backfill, _ := svc.Run(ctx, &entities.StartWalletBackfillRequest{
+			Chains: []string{string(entities.ChainSOLDevnet)},
+		}, nil)
+		done <- backfill
+	}()
+
+	require.Eventually(t, func() bool { return len(repo.batchSizes()) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, repo.batchSizes(), 1, "no batch may be enqueued while the first is in flight")
+
+	repo.completeJobs()
+	backfill := <-done
+	require.NotNil(t, backfill)
+	assert.Equal(t, entities.WalletBackfillCompleted, backfill.Status)
+	assert.Equal(t, []int{2, 2}, repo.batchSizes())
+}
+
+func TestRun_DryRunEnqueuesNothing(t *testing.T) {
+	repo := newFakeRepo(3)
+	svc := newService(repo, 100)
+
+	backfill, err := svc.Run(context.Background(), &entities.StartWalletBackfillRequest{
+		Chains: []string{string(entities.ChainSOLDevnet)},
+		DryRun: true,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WalletBackfillCompleted, backfill.Status)
+	assert.Equal(t, 3, backfill.EligibleUsers)
+	assert.Empty(t, repo.batchSizes())
+}
+
+func TestStart_RejectsUnsupportedChain(t *testing.T) {
+	svc := newService(newFakeRepo(1), 100)
+	_, err := svc.Start(context.Background(), &entities.StartWalletBackfillRequest{Chains: []string{"DOGE"}}, nil)
+	assert.ErrorIs(t, err, walletbackfill.ErrUnsupportedChain)
+}
+
+func TestCancel_StopsBackgroundBackfill(t *testing.T) {
+	repo := newFakeRepo(4)
+	svc := newService(repo, 2)
+
+	started, err := svc.Start(context.Background(), &entities.StartWalletBackfillRequest{
+		Chains: []string{string(entities.ChainSOLDevnet)},
+	}, nil)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(repo.batchSizes()) == 1 }, time.Second, time.Millisecond)
+
+	cancelled, err := svc.Cancel(context.Background(), started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.WalletBackfillCancelled, cancelled.Status)
+
+	svc.Stop()
+	repo.completeJobs()
+	assert.Len(t, repo.batchSizes(), 1)
+}
@@ -0,0 +1,291 @@
+package warehouse_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/warehouse"
+)
+
+var now = time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+type fakeRepo struct {
+	events     map[entities.WarehouseStream][]*entities.WarehouseEvent
+	cursors    map[entities.WarehouseStream]*entities.WarehouseCursor
+	busy       map[entities.WarehouseStream]bool
+	moved      bool
+	released   map[entities.WarehouseStream]*string
+	reset      []entities.WarehouseStream
+	resetFrom  time.Time
+	readUntils []time.Time
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		events:   map[entities.WarehouseStream][]*entities.WarehouseEvent{},
+		cursors:  map[entities.WarehouseStream]*entities.WarehouseCursor{},
+		busy:     map[entities.WarehouseStream]bool{},
+		released: map[entities.WarehouseStream]*string{},
+	}
+}
+
+func (r *fakeRepo) ClaimStream(_ context.Context, stream entities.WarehouseStream, _ time.Time, _ time.Duration) (*entities.WarehouseCursor, error) {
+	if r.busy[stream] {
+		return nil, entities.ErrWarehouseStreamBusy
+	}
+	cursor, ok := r.cursors[stream]
+	if !ok {
+		cursor = &entities.WarehouseCursor{Stream: stream}
+		r.cursors[stream] = cursor
+	}
+	copied := *cursor
+	return &copied, nil
+}
+
+func (r *fakeRepo) ReadEvents(_ context.Context, cursor *entities.WarehouseCursor, until time.Time, limit int) ([]*entities.WarehouseEvent, error) {
+	r.readUntils = append(r.readUntils, until)
+	var out []*entities.WarehouseEvent
+	for _, event := range r.events[cursor.Stream] {
+		after := event.Position.After(cursor.Position) ||
+			(event.Position.Equal(cursor.Position) && event.SourceID > cursor.LastID)
+		if after && !event.Position.After(until) {
+			out = append(out, event)
+		}
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) SaveCursor(_ context.Context, cursor *entities.WarehouseCursor) error {
+	if r.moved {
+		return entities.ErrWarehouseCursorMoved
+	}
+	copied := *cursor
+	r.cursors[cursor.Stream] = &copied
+	return nil
+}
+
+func (r *fakeRepo) ReleaseStream(_ context.Context, stream entities.WarehouseStream, lastError *string) error {
+	r.released[stream] = lastError
+	return nil
+}
+
+func (r *fakeRepo) ResetStreams(_ context.Context, streams []entities.WarehouseStream, from time.Time) error {
+	r.reset = streams
+	r.resetFrom = from
+	return nil
+}
+
+func (r *fakeRepo) ListCursors(context.Context) ([]*entities.WarehouseCursor, error) {
+	cursors := make([]*entities.WarehouseCursor, 0, len(r.cursors))
+	for _, cursor := range r.cursors {
+		cursors = append(cursors, cursor)
+	}
+	sort.Slice(cursors, func(i, j int) bool { return cursors[i].Stream < cursors[j].Stream })
+	return cursors, nil
+}
+
+type fakeSink struct {
+	batches [][]*entities.WarehouseEvent
+	err     error
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Write(_ context.Context, events []*entities.WarehouseEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, events)
+	return nil
+}
+
+func deposits(n int) []*entities.WarehouseEvent {
+	events := make([]*entities.WarehouseEvent, n)
+	userID := uuid.New()
+	for i := range events {
+		position := now.Add(-time.Hour).Add(time.Duration(i) * time.Second)
+		sourceID := uuid.New().String()
+		events[i] = &entities.WarehouseEvent{
+			ID:            "deposit_credited:" + sourceID,
+			Stream:        entities.WarehouseStreamDeposits,
+			Type:          "deposit_credited",
+			SchemaVersion: 1,
+			UserID:        &userID,
+			OccurredAt:    position,
+			Position:      position,
+			SourceID:      sourceID,
+		}
+	}
+	return events
+}
+
+func newService(repo *fakeRepo, sink warehouse.Sink, config warehouse.Config) *warehouse.Service {
+	service := warehouse.NewService(repo, config, zap.NewNop())
+	service.SetClock(func() time.Time { return now })
+	if sink != nil {
+		service.SetSink(sink)
+	}
+	return service
+}
+
+func TestExportWritesBatchesAndAdvancesCursor(t *testing.T) {
+	repo := newFakeRepo()
+	events := deposits(5)
+	repo.events[entities.WarehouseStreamDeposits] = events
+	sink := &fakeSink{}
+	service := newService(repo, sink, warehouse.Config{
+		Streams:   []entities.WarehouseStream{entities.WarehouseStreamDeposits},
+		BatchSize: 2,
+	})
+
+	reports, err := service.Export(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, 5, reports[0].Exported)
+	assert.Len(t, sink.batches, 3)
+
+	cursor := repo.cursors[entities.WarehouseStreamDeposits]
+	assert.True(t, cursor.Position.Equal(events[4].Position))
+	assert.Equal(t, events[4].SourceID, cursor.LastID)
+	assert.Equal(t, int64(5), cursor.ExportedCount)
+	assert.Nil(t, repo.released[entities.WarehouseStreamDeposits])
+
+	// Recent rows are held back until they settle
+	assert.True(t, repo.readUntils[0].Equal(now.Add(-2*time.Minute)))
+
+	// A second run finds nothing new
+	reports, err = service.Export(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, reports[0].Exported)
+	assert.Len(t, sink.batches, 3)
+}
+
+func TestExportStopsAtBatchBudget(t *testing.T) {
+	repo := newFakeRepo()
+	repo.events[entities.WarehouseStreamDeposits] = deposits(10)
+	sink := &fakeSink{}
+	service := newService(repo, sink, warehouse.Config{
+		Streams:          []entities.WarehouseStream{entities.WarehouseStreamDeposits},
+		BatchSize:        2,
+		MaxBatchesPerRun: 2,
+	})
+
+	reports, err := service.Export(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, reports[0].Exported)
+	assert.Equal(t, int64(4), repo.cursors[entities.WarehouseStreamDeposits].ExportedCount)
+}
+
+func TestExportKeepsCursorWhenSinkFails(t *testing.T) {
+	repo := newFakeRepo()
+	repo.events[entities.WarehouseStreamDeposits] = deposits(3)
+	sink := &fakeSink{err: errors.New("quota exceeded")}
+	service := newService(repo, sink, warehouse.Config{
+		Streams: []entities.WarehouseStream{entities.WarehouseStreamDeposits, entities.WarehouseStreamSignups},
+	})
+
+	reports, err := service.Export(context.Background())
+	require.Error(t, err)
+	require.Len(t, reports, 2)
+	assert.Contains(t, reports[0].Error, "quota exceeded")
+	assert.Empty(t, reports[1].Error)
+
+	cursor := repo.cursors[entities.WarehouseStreamDeposits]
+	assert.True(t, cursor.Position.IsZero())
+	assert.Zero(t, cursor.ExportedCount)
+	require.NotNil(t, repo.released[entities.WarehouseStreamDeposits])
+	assert.Contains(t, *repo.released[entities.WarehouseStreamDeposits], "quota exceeded")
+}
+
+func TestExportSkipsBusyStream(t *testing.T) {
+	repo := newFakeRepo()
+	repo.events[entities.WarehouseStreamDeposits] = deposits(2)
+	repo.busy[entities.WarehouseStreamDeposits] = true
+	sink := &fakeSink{}
+	service := newService(repo, sink, warehouse.Config{
+		Streams: []entities.WarehouseStream{entities.WarehouseStreamDeposits},
+	})
+
+	reports, err := service.Export(context.Background())
+	require.NoError(t, err)
+	assert.True(t, reports[0].Busy)
+	assert.Empty(t, sink.batches)
+	_, released := repo.released[entities.WarehouseStreamDeposits]
+	assert.False(t, released)
+}
+
+func TestExportStopsWhenBackfillMovesCursor(t *testing.T) {
+	repo := newFakeRepo()
+	repo.events[entities.WarehouseStreamDeposits] = deposits(6)
+	repo.moved = true
+	sink := &fakeSink{}
+	service := newService(repo, sink, warehouse.Config{
+		Streams:   []entities.WarehouseStream{entities.WarehouseStreamDeposits},
+		BatchSize: 2,
+	})
+
+	reports, err := service.Export(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, reports[0].Exported)
+	assert.Len(t, sink.batches, 1)
+}
+
+func TestExportUnavailableWithoutSink(t *testing.T) {
+	service := newService(newFakeRepo(), nil, warehouse.Config{})
+
+	_, err := service.Export(context.Background())
+	assert.ErrorIs(t, err, entities.ErrWarehouseExportUnavailable)
+	_, err = service.Status(context.Background())
+	assert.ErrorIs(t, err, entities.ErrWarehouseExportUnavailable)
+	_, err = service.Backfill(context.Background(), nil, now.Add(-24*time.Hour))
+	assert.ErrorIs(t, err, entities.ErrWarehouseExportUnavailable)
+}
+
+func TestBackfill(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo, &fakeSink{}, warehouse.Config{
+		Streams: []entities.WarehouseStream{entities.WarehouseStreamSignups, entities.WarehouseStreamTrades},
+	})
+	from, err := service.ParseDay("2026-01-01")
+	require.NoError(t, err)
+
+	streams, err := service.Backfill(context.Background(), nil, from)
+	require.NoError(t, err)
+	assert.Equal(t, []entities.WarehouseStream{entities.WarehouseStreamSignups, entities.WarehouseStreamTrades}, streams)
+	assert.Equal(t, streams, repo.reset)
+	assert.True(t, repo.resetFrom.Equal(from))
+
+	_, err = service.Backfill(context.Background(), []entities.WarehouseStream{entities.WarehouseStreamDeposits}, from)
+	assert.ErrorIs(t, err, entities.ErrInvalidWarehouseBackfill)
+
+	_, err = service.Backfill(context.Background(), nil, now.Add(48*time.Hour))
+	assert.ErrorIs(t, err, entities.ErrInvalidWarehouseBackfill)
+
+	_, err = service.ParseDay("01/02/2026")
+	assert.ErrorIs(t, err, entities.ErrInvalidWarehouseBackfill)
+}
+
+func TestStatusListsSchemas(t *testing.T) {
+	repo := newFakeRepo()
+	service := newService(repo, &fakeSink{}, warehouse.Config{})
+
+	status, err := service.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "fake", status.Destination)
+	assert.NotNil(t, status.Cursors)
+	require.Len(t, status.Schemas, 6)
+	assert.Equal(t, "user_signed_up", status.Schemas[0].EventType)
+	assert.Equal(t, 1, status.Schemas[0].Version)
+}
@@ -0,0 +1,222 @@
+package webhookarchive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/api/middleware"
+	"github.com/stack-service/stack_service/internal/domain/entities"
+	"github.com/stack-service/stack_service/internal/domain/services/webhookarchive"
+)
+
+type memoryStore struct {
+	objects map[string][]byte
+	keys    []string
+}
+
+func (s *memoryStore) Put(_ context.Context, key, _ string, body []byte) (string, error) {
+	uri := "mem://" + key
+	s.objects[uri] = append([]byte(nil), body...)
+	s.keys = append(s.keys, key)
+	return uri, nil
+}
+
+func (s *memoryStore) Retrieve(_ context.Context, uri string) ([]byte, error) {
+	body, ok := s.objects[uri]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return body, nil
+}
+
+type fakeRepo struct {
+	webhooks map[uuid.UUID]*entities.ArchivedWebhook
+	subjects map[string][]entities.WebhookLink
+	owners   map[string]uuid.UUID
+	searched *entities.WebhookArchiveFilter
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		webhooks: map[uuid.UUID]*entities.ArchivedWebhook{},
+		subjects: map[string][]entities.WebhookLink{},
+		owners:   map[string]uuid.UUID{},
+	}
+}
+
+func (r *fakeRepo) Create(_ context.Context, webhook *entities.ArchivedWebhook) error {
+	copied := *webhook
+	r.webhooks[webhook.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepo) Get(_ context.Context, id uuid.UUID) (*entities.ArchivedWebhook, error) {
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, entities.ErrArchivedWebhookNotFound
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+func (r *fakeRepo) Search(_ context.Context, filter entities.WebhookArchiveFilter) ([]*entities.ArchivedWebhook, error) {
+	r.searched = &filter
+	return nil, nil
+}
+
+func (r *fakeRepo) ResolveSubjects(_ context.Context, provider, reference string) ([]entities.WebhookLink, *uuid.UUID, error) {
+	key := provider + ":" + reference
+	var userID *uuid.UUID
+	if owner, ok := r.owners[key]; ok {
+		userID = &owner
+	}
+	return r.subjects[key], userID, nil
+}
+
+func (r *fakeRepo) AddLinks(_ context.Context, id uuid.UUID, userID *uuid.UUID, links []entities.WebhookLink) error {
+	webhook := r.webhooks[id]
+	webhook.UserID = userID
+	webhook.Links = append(webhook.Links, links...)
+	return nil
+}
+
+func newService() (*webhookarchive.Service, *fakeRepo, *memoryStore) {
+	repo := newFakeRepo()
+	store := &memoryStore{objects: map[string][]byte{}}
+	return webhookarchive.NewService(repo, store, webhookarchive.DefaultConfig(), zap.NewNop()), repo, store
+}
+
+func TestArchiveStoresBodyAndLinksSubjects(t *testing.T) {
+	service, repo, store := newService()
+	ctx := context.Background()
+
+	depositID := uuid.New()
+	userID := uuid.New()
+	repo.subjects["chain:0xabc"] = []entities.WebhookLink{{SubjectType: entities.WebhookSubjectDeposit, SubjectID: depositID}}
+	repo.owners["chain:0xabc"] = userID
+
+	payload := []byte(`{"txHash":"0xabc","amount":"25.00"}`)
+	webhook, err := service.Archive(ctx, &webhookarchive.ArchiveRequest{
+		Provider:       entities.WebhookProviderChain,
+		EventType:      "deposit",
+		Reference:      "0xabc",
+		Payload:        payload,
+		ResponseStatus: http.StatusOK,
+		ReceivedAt:     time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Len(t, store.keys, 1)
+	assert.Equal(t, "webhooks/chain/2026/10/16/"+webhook.ID.String()+".json", store.keys[0])
+	assert.Len(t, webhook.SHA256, 64)
+	assert.Equal(t, len(payload), webhook.SizeBytes)
+	assert.Equal(t, "application/json", webhook.ContentType)
+
+	require.NoError(t, service.Link(ctx, webhook))
+	fetched, err := service.Get(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(payload), string(fetched.Payload))
+	require.NotNil(t, fetched.UserID)
+	assert.Equal(t, userID, *fetched.UserID)
+	assert.Equal(t, []entities.WebhookLink{{SubjectType: entities.WebhookSubjectDeposit, SubjectID: depositID}}, fetched.Links)
+}
+
+func TestGetRejectsTamperedBodyAndQuotesNonJSON(t *testing.T) {
+	service, _, store := newService()
+	ctx := context.Background()
+
+	webhook, err := service.Archive(ctx, &webhookarchive.ArchiveRequest{
+		Provider:    entities.WebhookProviderKYC,
+		EventType:   "kyc_approved",
+		ContentType: "application/x-www-form-urlencoded",
+		Payload:     []byte("status=approved&ref=abc"),
+	})
+	require.NoError(t, err)
+
+	fetched, err := service.Get(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"status=approved&ref=abc"`, string(fetched.Payload))
+
+	store.objects[webhook.StorageURI] = []byte("status=rejected&ref=abc")
+	_, err = service.Get(ctx, webhook.ID)
+	assert.ErrorIs(t, err, webhookarchive.ErrChecksumMismatch)
+
+	_, err = service.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, entities.ErrArchivedWebhookNotFound)
+}
+
+func TestSearchValidatesSubjectAndClampsPage(t *testing.T) {
+	service, repo, _ := newService()
+	ctx := context.Background()
+
+	_, err := service.Search(ctx, entities.WebhookArchiveFilter{SubjectType: entities.WebhookSubjectOrder})
+	assert.ErrorIs(t, err, webhookarchive.ErrInvalidFilter)
+
+	subjectID := uuid.New()
+	_, err = service.Search(ctx, entities.WebhookArchiveFilter{
+		SubjectType: entities.WebhookSubjectOrder,
+		SubjectID:   &subjectID,
+		Limit:       1000,
+		Offset:      -5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200, repo.searched.Limit)
+	assert.Zero(t, repo.searched.Offset)
+
+	_, err = service.Search(ctx, entities.WebhookArchiveFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 50, repo.searched.Limit)
+}
+
+func TestMiddlewareArchivesOnlyVerifiedWebhooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service, repo, _ := newService()
+	repo.subjects["brokerage:order-1"] = []entities.WebhookLink{{SubjectType: entities.WebhookSubjectOrder, SubjectID: uuid.New()}}
+
+	router := gin.New()
+	router.POST("/fill", middleware.ArchiveWebhook(service, entities.WebhookProviderBrokerage, zap.NewNop()), func(c *gin.Context) {
+		var body struct {
+			OrderID string `json:"orderId"`
+			Valid   bool   `json:"valid"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || !body.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_REQUEST"})
+			return
+		}
+		c.Set(middleware.WebhookEventTypeKey, "order_filled")
+		c.Set(middleware.WebhookReferenceKey, body.OrderID)
+		c.Status(http.StatusUnprocessableEntity)
+	})
+
+	send := func(body string) int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/fill", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, send(`{"orderId":"order-1","valid":false}`))
+	assert.Empty(t, repo.webhooks, "unverified webhooks are not archived")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, send(`{"orderId":"order-1","valid":true}`))
+	require.Len(t, repo.webhooks, 1)
+	for _, webhook := range repo.webhooks {
+		assert.Equal(t, "order_filled", webhook.EventType)
+		assert.Equal(t, "order-1", webhook.Reference)
+		assert.Equal(t, http.StatusUnprocessableEntity, webhook.ResponseStatus)
+		assert.Empty(t, webhook.Links, "failed processing is archived but not linked")
+		assert.True(t, strings.HasPrefix(webhook.StorageURI, "mem://webhooks/brokerage/"))
+	}
+}
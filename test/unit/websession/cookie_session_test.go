@@ -0,0 +1,145 @@
+package websession_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/stack-service/stack_service/internal/api/middleware"
+	"github.com/stack-service/stack_service/internal/infrastructure/config"
+	"github.com/stack-service/stack_service/pkg/auth"
+	"github.com/stack-service/stack_service/pkg/logger"
+)
+
+const jwtSecret = "test-secret"
+
+func testConfig(modes ...string) *config.Config {
+	return &config.Config{
+		JWT: config.JWTConfig{Secret: jwtSecret, AccessTTL: 900, RefreshTTL: 86400},
+		WebSession: config.WebSessionConfig{
+			AuthModes:         modes,
+			AccessCookieName:  "stack_access",
+			RefreshCookieName: "stack_refresh",
+			CSRFCookieName:    "stack_csrf",
+			SameSite:          "lax",
+			Secure:            true,
+		},
+	}
+}
+
+func newPair(t *testing.T) *auth.TokenPair {
+	pair, err := auth.GenerateTokenPair(uuid.New(), "ada@example.com", "user", jwtSecret, 900, 86400)
+	require.NoError(t, err)
+	return pair
+}
+
+// newRouter mounts a mutating route behind the protected route middleware
+func newRouter(cfg *config.Config) (*gin.Engine, *middleware.CSRFStore) {
+	gin.SetMode(gin.TestMode)
+	store := middleware.NewCSRFStore()
+	store.SetCookieSessions(middleware.NewCookieSessions(cfg))
+	router := gin.New()
+	router.Use(middleware.Authentication(cfg, logger.NewLogger(zap.NewNop()), nil))
+	router.Use(middleware.CSRFProtection(store))
+	router.POST("/orders", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"auth_mode": c.GetString("auth_mode")})
+	})
+	return router, store
+}
+
+func TestIssue_SetsHttpOnlyCookiesAndSessionCSRFToken(t *testing.T) {
+	sessions := middleware.NewCookieSessions(testConfig("bearer", "cookie"))
+	pair := newPair(t)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	csrfToken := sessions.Issue(c, pair)
+
+	assert.Equal(t, csrfToken, recorder.Header().Get("X-CSRF-Token"))
+	assert.True(t, sessions.ValidCSRF(pair.SessionID, csrfToken))
+	assert.False(t, sessions.ValidCSRF(newPair(t).SessionID, csrfToken), "tokens are bound to their session")
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range recorder.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	require.Len(t, cookies, 3)
+	assert.True(t, cookies["stack_access"].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies["stack_access"].SameSite)
+	assert.True(t, cookies["stack_access"].Secure)
+	assert.True(t, cookies["stack_refresh"].HttpOnly)
+	assert.Equal(t, "/api/v1/auth", cookies["stack_refresh"].Path)
+	assert.False(t, cookies["stack_csrf"].HttpOnly, "the web app reads the CSRF cookie")
+	assert.Equal(t, csrfToken, cookies["stack_csrf"].Value)
+}
+
+func TestRefresh_KeepsTheSessionAndItsCSRFToken(t *testing.T) {
+	pair := newPair(t)
+	refreshed, err := auth.RefreshAccessToken(pair.RefreshToken, jwtSecret, 900)
+	require.NoError(t, err)
+	assert.Equal(t, pair.SessionID, refreshed.SessionID)
+
+	sessionID, err := auth.SessionID(refreshed.AccessToken, jwtSecret)
+	require.NoError(t, err)
+	assert.Equal(t, pair.SessionID, sessionID)
+}
+
+func TestCookieRequests_RequireTheSessionCSRFToken(t *testing.T) {
+	cfg := testConfig("bearer", "cookie")
+	router, _ := newRouter(cfg)
+	sessions := middleware.NewCookieSessions(cfg)
+	pair := newPair(t)
+
+	send := func(csrfToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.AddCookie(&http.Cookie{Name: "stack_access", Value: pair.AccessToken})
+		if csrfToken != "" {
+			req.Header.Set("X-CSRF-Token", csrfToken)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusForbidden, send("").Code)
+	assert.Equal(t, http.StatusForbidden, send(sessions.CSRFToken(newPair(t).SessionID)).Code)
+
+	recorder := send(sessions.CSRFToken(pair.SessionID))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"auth_mode":"cookie"`)
+}
+
+func TestAuthModes_RunInParallelOrAlone(t *testing.T) {
+	pair := newPair(t)
+	bearer := func(router *gin.Engine, store *middleware.CSRFStore) int {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		req.Header.Set("X-CSRF-Token", store.Generate())
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	cookieOnly := func(router *gin.Engine) int {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.AddCookie(&http.Cookie{Name: "stack_access", Value: pair.AccessToken})
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	router, store := newRouter(testConfig("bearer", "cookie"))
+	assert.Equal(t, http.StatusOK, bearer(router, store), "bearer clients keep working during migration")
+
+	router, store = newRouter(testConfig("bearer"))
+	assert.Equal(t, http.StatusUnauthorized, cookieOnly(router), "cookies are ignored until cookie mode is enabled")
+	assert.Equal(t, http.StatusOK, bearer(router, store))
+
+	router, store = newRouter(testConfig("cookie"))
+	assert.Equal(t, http.StatusUnauthorized, bearer(router, store), "bearer tokens are refused once switched off")
+}
@@ -0,0 +1,99 @@
+package workerstatus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stack-service/stack_service/pkg/workerstatus"
+)
+
+func TestTracker_RecordsRunsAndErrors(t *testing.T) {
+	registry := workerstatus.NewRegistry()
+	tracker := registry.Register("billing", time.Hour, nil)
+
+	finish, ok := tracker.Begin()
+	require.True(t, ok)
+	running := tracker.Status(context.Background())
+	assert.Equal(t, workerstatus.StateRunning, running.State)
+	finish(errors.New("database unavailable"))
+
+	finish, ok = tracker.Begin()
+	require.True(t, ok)
+	finish(errors.New("database unavailable"))
+
+	status := tracker.Status(context.Background())
+	assert.Equal(t, workerstatus.StateIdle, status.State)
+	assert.Equal(t, int64(2), status.Runs)
+	assert.Equal(t, int64(2), status.Errors)
+	assert.Equal(t, int64(2), status.ConsecutiveErrors)
+	assert.Equal(t, "database unavailable", status.LastError)
+	require.NotNil(t, status.LastRunAt)
+	require.NotNil(t, status.NextRunAt)
+	assert.WithinDuration(t, status.LastRunAt.Add(time.Hour), *status.NextRunAt, time.Second)
+
+	finish, _ = tracker.Begin()
+	finish(nil)
+	status = tracker.Status(context.Background())
+	assert.Equal(t, int64(2), status.Errors)
+	assert.Zero(t, status.ConsecutiveErrors)
+}
+
+func TestRegistry_PauseSkipsRunsUntilResumed(t *testing.T) {
+	registry := workerstatus.NewRegistry()
+	tracker := registry.Register("wallet_provisioning", time.Minute, nil)
+
+	_, err := registry.Pause("wallet_provisioning", "admin-1", "Circle outage")
+	require.NoError(t, err)
+
+	_, ok := tracker.Begin()
+	assert.False(t, ok)
+	status := tracker.Status(context.Background())
+	assert.Equal(t, workerstatus.StatePaused, status.State)
+	assert.Equal(t, "admin-1", status.PausedBy)
+	assert.Equal(t, "Circle outage", status.PauseReason)
+	assert.Zero(t, status.Runs)
+
+	_, err = registry.Resume("wallet_provisioning")
+	require.NoError(t, err)
+	finish, ok := tracker.Begin()
+	require.True(t, ok)
+	finish(nil)
+	assert.Equal(t, int64(1), tracker.Status(context.Background()).Runs)
+
+	_, err = registry.Pause("unknown", "admin-1", "n/a")
+	assert.ErrorIs(t, err, workerstatus.ErrWorkerNotFound)
+}
+
+func TestRegistry_SnapshotReportsBacklog(t *testing.T) {
+	registry := workerstatus.NewRegistry()
+	registry.Register("funding_webhook_processor", 5*time.Second, func(ctx context.Context) (int64, error) {
+		return 42, nil
+	})
+	registry.Register("balance_cache", time.Minute, func(ctx context.Context) (int64, error) {
+		return 0, errors.New("query timed out")
+	})
+	registry.Register("audit", time.Hour, nil)
+
+	statuses := registry.Snapshot(context.Background())
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "audit", statuses[0].Name)
+	assert.Nil(t, statuses[0].Backlog)
+	assert.Equal(t, "balance_cache", statuses[1].Name)
+	assert.Equal(t, "query timed out", statuses[1].BacklogError)
+	require.NotNil(t, statuses[2].Backlog)
+	assert.Equal(t, int64(42), *statuses[2].Backlog)
+}
+
+func TestTracker_NilIsAlwaysRunnable(t *testing.T) {
+	var tracker *workerstatus.Tracker
+
+	finish, ok := tracker.Begin()
+	require.True(t, ok)
+	finish(errors.New("ignored"))
+	assert.False(t, tracker.Paused())
+}